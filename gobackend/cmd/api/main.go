@@ -35,11 +35,16 @@ import (
 	"github.com/joho/godotenv"
 	"github.com/rs/zerolog"
 	"github.com/rs/zerolog/log"
+	"net"
+	"net/http"
 	"os"
+	"strconv"
+	"time"
 
 	_ "github.com/yasinhessnawi1/Hideme_Backend/docs"
 
 	"github.com/yasinhessnawi1/Hideme_Backend/internal/config"
+	"github.com/yasinhessnawi1/Hideme_Backend/internal/constants"
 	"github.com/yasinhessnawi1/Hideme_Backend/internal/server"
 	"github.com/yasinhessnawi1/Hideme_Backend/internal/utils"
 )
@@ -75,11 +80,13 @@ func main() {
 	var (
 		configPath  string
 		showVersion bool
+		healthcheck bool
 	)
 
 	// Register command-line flags
 	flag.StringVar(&configPath, "config", "./configs/config.yaml", "Path to configuration file")
 	flag.BoolVar(&showVersion, "version", false, "Show version information")
+	flag.BoolVar(&healthcheck, "healthcheck", false, "Check the readiness of the running server and exit with 0 if it is ready")
 	flag.Parse()
 
 	// If version flag is set, display build information and exit
@@ -100,6 +107,16 @@ func main() {
 		os.Exit(1)
 	}
 
+	// If healthcheck flag is set, probe the server running with this configuration and exit.
+	// This lets container health checks run the binary itself, without curl in the image.
+	if healthcheck {
+		if err := runHealthcheck(healthcheckURL(&cfg.Server), constants.HealthcheckTimeout); err != nil {
+			fmt.Printf("Healthcheck failed: %v\n", err)
+			os.Exit(1)
+		}
+		os.Exit(0)
+	}
+
 	// Override version from build if available (not in dev mode)
 	if version != "dev" {
 		cfg.App.Version = version
@@ -133,3 +150,41 @@ func main() {
 		log.Fatal().Err(err).Msg("Server error")
 	}
 }
+
+// healthcheckURL returns the URL of the readiness probe of the server running with the
+// given settings. A server listening on all interfaces is probed over the loopback interface.
+//
+// Parameters:
+//   - settings: The server settings
+//
+// Returns:
+//   - The URL of the readiness probe
+func healthcheckURL(settings *config.ServerSettings) string {
+	host := settings.Host
+	if host == "" || host == "0.0.0.0" || host == "::" {
+		host = "127.0.0.1"
+	}
+	return "http://" + net.JoinHostPort(host, strconv.Itoa(settings.Port)) + constants.ReadinessPath
+}
+
+// runHealthcheck requests the readiness probe at the given URL.
+//
+// Parameters:
+//   - url: The URL of the readiness probe
+//   - timeout: The time allowed for the request
+//
+// Returns:
+//   - An error if the server cannot be reached or is not ready
+func runHealthcheck(url string, timeout time.Duration) error {
+	client := &http.Client{Timeout: timeout}
+	resp, err := client.Get(url)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("server is not ready: %s", resp.Status)
+	}
+	return nil
+}
@@ -1 +1,59 @@
 package main
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/yasinhessnawi1/Hideme_Backend/internal/config"
+)
+
+func TestHealthcheckURL(t *testing.T) {
+	tests := []struct {
+		name string
+		host string
+		want string
+	}{
+		{"All interfaces", "0.0.0.0", "http://127.0.0.1:8080/readyz"},
+		{"No host", "", "http://127.0.0.1:8080/readyz"},
+		{"All IPv6 interfaces", "::", "http://127.0.0.1:8080/readyz"},
+		{"Specific host", "10.0.0.5", "http://10.0.0.5:8080/readyz"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, healthcheckURL(&config.ServerSettings{Host: tt.host, Port: 8080}))
+		})
+	}
+}
+
+func TestRunHealthcheck(t *testing.T) {
+	t.Run("Ready", func(t *testing.T) {
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.WriteHeader(http.StatusOK)
+		}))
+		defer server.Close()
+
+		assert.NoError(t, runHealthcheck(server.URL, time.Second))
+	})
+
+	t.Run("Not ready", func(t *testing.T) {
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.WriteHeader(http.StatusServiceUnavailable)
+		}))
+		defer server.Close()
+
+		assert.ErrorContains(t, runHealthcheck(server.URL, time.Second), "503")
+	})
+
+	t.Run("Unreachable", func(t *testing.T) {
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+		url := server.URL
+		server.Close()
+
+		assert.Error(t, runHealthcheck(url, time.Second))
+	})
+}
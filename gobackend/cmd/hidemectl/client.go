@@ -0,0 +1,117 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/yasinhessnawi1/Hideme_Backend/internal/constants"
+)
+
+// apiError is an error response of the admin API.
+type apiError struct {
+	// Status is the HTTP status code
+	Status int
+
+	// Code is the error code of the response envelope, such as "not_found"
+	Code string
+
+	// Message is the human-readable error message
+	Message string
+}
+
+// Error implements the error interface.
+func (e *apiError) Error() string {
+	if e.Code == "" {
+		return fmt.Sprintf("request failed with status %d: %s", e.Status, e.Message)
+	}
+	return fmt.Sprintf("%s (%d): %s", e.Code, e.Status, e.Message)
+}
+
+// envelope is the response envelope of the API, see utils.Response.
+type envelope struct {
+	Success bool            `json:"success"`
+	Data    json.RawMessage `json:"data"`
+	Error   *struct {
+		Code    string `json:"code"`
+		Message string `json:"message"`
+	} `json:"error"`
+}
+
+// client talks to the admin API of a HideMe server with an API key.
+type client struct {
+	baseURL    string
+	apiKey     string
+	httpClient *http.Client
+}
+
+// newClient creates a client for the server at baseURL.
+//
+// Parameters:
+//   - baseURL: The server URL without the /api prefix, e.g. http://localhost:8080
+//   - apiKey: The API key of an administrator
+//   - timeout: The timeout of each request
+//
+// Returns:
+//   - A configured client
+func newClient(baseURL, apiKey string, timeout time.Duration) *client {
+	return &client{
+		baseURL:    strings.TrimRight(baseURL, "/"),
+		apiKey:     apiKey,
+		httpClient: &http.Client{Timeout: timeout},
+	}
+}
+
+// do sends a request to the API and returns the response if its status is 2xx.
+// The caller must close the response body.
+func (c *client) do(method, path string, query url.Values) (*http.Response, error) {
+	target := c.baseURL + "/api" + path
+	if len(query) > 0 {
+		target += "?" + query.Encode()
+	}
+
+	req, err := http.NewRequest(method, target, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create request: %w", err)
+	}
+	req.Header.Set(constants.HeaderXAPIKey, c.apiKey)
+
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("request failed: %w", err)
+	}
+
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		defer resp.Body.Close()
+		body, _ := io.ReadAll(resp.Body)
+
+		apiErr := &apiError{Status: resp.StatusCode, Message: strings.TrimSpace(string(body))}
+		var env envelope
+		if json.Unmarshal(body, &env) == nil && env.Error != nil {
+			apiErr.Code = env.Error.Code
+			apiErr.Message = env.Error.Message
+		}
+		return nil, apiErr
+	}
+
+	return resp, nil
+}
+
+// data sends a request to the API and returns the data of the response envelope.
+func (c *client) data(method, path string, query url.Values) (json.RawMessage, error) {
+	resp, err := c.do(method, path, query)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	var env envelope
+	if err := json.NewDecoder(resp.Body).Decode(&env); err != nil {
+		return nil, fmt.Errorf("failed to decode response: %w", err)
+	}
+	return env.Data, nil
+}
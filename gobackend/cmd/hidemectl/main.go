@@ -0,0 +1,411 @@
+// Package main is hidemectl, the command line tool for operators of the HideMe API
+// Server. It covers common admin tasks (user lookup, session revocation, maintenance,
+// billing exports and configuration checks) through the admin API, authenticating
+// with the API key of an administrator.
+//
+// Usage:
+//
+//	hidemectl [flags] <command> <subcommand> [arguments]
+//
+// The server URL and API key are taken from --url and --api-key, or from the
+// HIDEMECTL_URL and HIDEMECTL_API_KEY environment variables. Output is JSON by
+// default, so it can be piped into other tools; --output text prints key-value lines.
+//
+// Exit codes: 0 on success, 1 if the request or the operation failed, 2 on usage errors.
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/yasinhessnawi1/Hideme_Backend/internal/constants"
+)
+
+// Exit codes of hidemectl.
+const (
+	exitOK    = 0
+	exitError = 1
+	exitUsage = 2
+)
+
+// Output formats of hidemectl.
+const (
+	outputJSON = "json"
+	outputText = "text"
+)
+
+// Defaults of the global flags.
+const (
+	defaultURL     = "http://localhost:8080"
+	defaultTimeout = 30 * time.Second
+)
+
+// usage is printed for usage errors and -h.
+const usage = `Usage: hidemectl [flags] <command> <subcommand> [arguments]
+
+Commands:
+  user lookup (--id ID | --username NAME | --email EMAIL)   Look up a user
+  sessions revoke --user ID                                  Sign a user out of every device
+  maintenance list                                           List the maintenance tasks
+  maintenance run TASK                                       Run a maintenance task now
+  export billing [--month YYYY-MM] [--locale TAG]
+                 [--csv-delimiter comma|semicolon] [--out FILE]
+                                                             Export monthly tenant usage as CSV
+  config check                                               Check the server configuration
+
+Flags:
+`
+
+// errUsage marks errors in the command line.
+var errUsage = errors.New("usage error")
+
+// usageErrorf returns a usage error with a message.
+func usageErrorf(format string, args ...interface{}) error {
+	return fmt.Errorf("%w: %s", errUsage, fmt.Sprintf(format, args...))
+}
+
+// command runs a subcommand with its remaining arguments.
+type command func(c *client, out *printer, args []string) error
+
+// commands maps commands and subcommands to their implementation.
+var commands = map[string]map[string]command{
+	"user":        {"lookup": userLookup},
+	"sessions":    {"revoke": sessionsRevoke},
+	"maintenance": {"list": maintenanceList, "run": maintenanceRun},
+	"export":      {"billing": exportBilling},
+	"config":      {"check": configCheck},
+}
+
+func main() {
+	os.Exit(run(os.Args[1:], os.Stdout, os.Stderr))
+}
+
+// run runs hidemectl with the command line arguments and returns the exit code.
+func run(args []string, stdout, stderr io.Writer) int {
+	flags := flag.NewFlagSet("hidemectl", flag.ContinueOnError)
+	flags.SetOutput(stderr)
+	flags.Usage = func() {
+		fmt.Fprint(stderr, usage)
+		flags.PrintDefaults()
+	}
+
+	baseURL := flags.String("url", envOr("HIDEMECTL_URL", defaultURL), "server URL (env HIDEMECTL_URL)")
+	apiKey := flags.String("api-key", os.Getenv("HIDEMECTL_API_KEY"), "API key of an administrator (env HIDEMECTL_API_KEY)")
+	output := flags.String("output", outputJSON, "output format: json or text")
+	timeout := flags.Duration("timeout", defaultTimeout, "timeout of each request")
+
+	if err := flags.Parse(args); err != nil {
+		if errors.Is(err, flag.ErrHelp) {
+			return exitOK
+		}
+		return exitUsage
+	}
+
+	err := dispatch(flags.Args(), *baseURL, *apiKey, *output, *timeout, stdout)
+	switch {
+	case err == nil:
+		return exitOK
+	case errors.Is(err, errUsage):
+		fmt.Fprintf(stderr, "hidemectl: %v\n\n", err)
+		flags.Usage()
+		return exitUsage
+	default:
+		fmt.Fprintf(stderr, "hidemectl: %v\n", err)
+		return exitError
+	}
+}
+
+// dispatch validates the global settings and runs the requested subcommand.
+func dispatch(args []string, baseURL, apiKey, output string, timeout time.Duration, stdout io.Writer) error {
+	if len(args) < 2 {
+		return usageErrorf("missing command")
+	}
+	subcommands, ok := commands[args[0]]
+	if !ok {
+		return usageErrorf("unknown command %q", args[0])
+	}
+	cmd, ok := subcommands[args[1]]
+	if !ok {
+		return usageErrorf("unknown subcommand %q of %s", args[1], args[0])
+	}
+
+	if output != outputJSON && output != outputText {
+		return usageErrorf("unknown output format %q", output)
+	}
+	if apiKey == "" {
+		return usageErrorf("an API key is required, set --api-key or HIDEMECTL_API_KEY")
+	}
+
+	return cmd(newClient(baseURL, apiKey, timeout), &printer{w: stdout, format: output}, args[2:])
+}
+
+// userLookup looks up a user by ID, username or email.
+func userLookup(c *client, out *printer, args []string) error {
+	flags := subcommandFlags("user lookup")
+	id := flags.Int64("id", 0, "user ID")
+	username := flags.String("username", "", "username")
+	email := flags.String("email", "", "email address")
+	if err := parseFlags(flags, args, 0); err != nil {
+		return err
+	}
+
+	query := url.Values{}
+	if *id != 0 {
+		query.Set(constants.ParamID, strconv.FormatInt(*id, 10))
+	}
+	if *username != "" {
+		query.Set(constants.QueryParamUsername, *username)
+	}
+	if *email != "" {
+		query.Set(constants.QueryParamEmail, *email)
+	}
+	if len(query) != 1 {
+		return usageErrorf("exactly one of --id, --username or --email is required")
+	}
+
+	data, err := c.data(http.MethodGet, "/admin/users", query)
+	if err != nil {
+		return err
+	}
+	return out.print(data)
+}
+
+// sessionsRevoke signs a user out of every device.
+func sessionsRevoke(c *client, out *printer, args []string) error {
+	flags := subcommandFlags("sessions revoke")
+	userID := flags.Int64("user", 0, "user ID")
+	if err := parseFlags(flags, args, 0); err != nil {
+		return err
+	}
+	if *userID <= 0 {
+		return usageErrorf("--user is required")
+	}
+
+	data, err := c.data(http.MethodDelete, fmt.Sprintf("/admin/users/%d/sessions", *userID), nil)
+	if err != nil {
+		return err
+	}
+	return out.print(data)
+}
+
+// maintenanceList lists the maintenance tasks.
+func maintenanceList(c *client, out *printer, args []string) error {
+	if err := parseFlags(subcommandFlags("maintenance list"), args, 0); err != nil {
+		return err
+	}
+
+	data, err := c.data(http.MethodGet, "/admin/maintenance", nil)
+	if err != nil {
+		return err
+	}
+	return out.print(data)
+}
+
+// maintenanceRun runs a maintenance task and fails if the task failed.
+func maintenanceRun(c *client, out *printer, args []string) error {
+	flags := subcommandFlags("maintenance run")
+	if err := parseFlags(flags, args, 1); err != nil {
+		return err
+	}
+	task := flags.Arg(0)
+
+	data, err := c.data(http.MethodPost, "/admin/maintenance/"+url.PathEscape(task), nil)
+	if err != nil {
+		return err
+	}
+	if err := out.print(data); err != nil {
+		return err
+	}
+
+	var result struct {
+		Error string `json:"error"`
+	}
+	if err := json.Unmarshal(data, &result); err == nil && result.Error != "" {
+		return fmt.Errorf("task %s failed: %s", task, result.Error)
+	}
+	return nil
+}
+
+// exportBilling writes the billing export CSV to a file or standard output.
+// The CSV is written as is, whatever the output format.
+func exportBilling(c *client, out *printer, args []string) error {
+	flags := subcommandFlags("export billing")
+	month := flags.String("month", "", "month to export in YYYY-MM format (default previous month)")
+	locale := flags.String("locale", "", "regional formatting of dates and numbers, e.g. de-DE")
+	delimiter := flags.String("csv-delimiter", "", "comma or semicolon (default the locale's delimiter)")
+	outFile := flags.String("out", "", "file to write the CSV to (default standard output)")
+	if err := parseFlags(flags, args, 0); err != nil {
+		return err
+	}
+
+	query := url.Values{}
+	if *month != "" {
+		query.Set(constants.QueryParamMonth, *month)
+	}
+	if *locale != "" {
+		query.Set(constants.QueryParamLocale, *locale)
+	}
+	if *delimiter != "" {
+		query.Set(constants.QueryParamCSVDelimiter, *delimiter)
+	}
+
+	resp, err := c.do(http.MethodGet, "/admin/billing-export", query)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if *outFile == "" {
+		_, err := io.Copy(out.w, resp.Body)
+		return err
+	}
+
+	file, err := os.Create(*outFile)
+	if err != nil {
+		return fmt.Errorf("failed to create %s: %w", *outFile, err)
+	}
+	if _, err := io.Copy(file, resp.Body); err != nil {
+		file.Close()
+		return fmt.Errorf("failed to write %s: %w", *outFile, err)
+	}
+	return file.Close()
+}
+
+// configCheck checks the server configuration and fails if it is invalid.
+func configCheck(c *client, out *printer, args []string) error {
+	if err := parseFlags(subcommandFlags("config check"), args, 0); err != nil {
+		return err
+	}
+
+	data, err := c.data(http.MethodGet, "/admin/config", nil)
+	if err != nil {
+		return err
+	}
+	if err := out.print(data); err != nil {
+		return err
+	}
+
+	var result struct {
+		Valid bool `json:"valid"`
+	}
+	if err := json.Unmarshal(data, &result); err != nil {
+		return fmt.Errorf("failed to decode config check: %w", err)
+	}
+	if !result.Valid {
+		return errors.New("configuration is invalid")
+	}
+	return nil
+}
+
+// subcommandFlags creates the flag set of a subcommand. Parse errors are reported by parseFlags.
+func subcommandFlags(name string) *flag.FlagSet {
+	flags := flag.NewFlagSet(name, flag.ContinueOnError)
+	flags.SetOutput(io.Discard)
+	return flags
+}
+
+// parseFlags parses the arguments of a subcommand, which takes exactly positional arguments.
+func parseFlags(flags *flag.FlagSet, args []string, positional int) error {
+	if err := flags.Parse(args); err != nil {
+		return usageErrorf("%s: %v", flags.Name(), err)
+	}
+	if flags.NArg() != positional {
+		return usageErrorf("%s takes %d argument(s), got %d", flags.Name(), positional, flags.NArg())
+	}
+	return nil
+}
+
+// envOr returns the value of an environment variable, or fallback if it is not set.
+func envOr(key, fallback string) string {
+	if value := os.Getenv(key); value != "" {
+		return value
+	}
+	return fallback
+}
+
+// printer writes response data in the selected output format.
+type printer struct {
+	w      io.Writer
+	format string
+}
+
+// print writes the data of a response. JSON is indented; text has one "key: value"
+// line per field, and a blank line between the elements of a list.
+func (p *printer) print(data json.RawMessage) error {
+	if p.format == outputJSON {
+		var buf bytes.Buffer
+		if err := json.Indent(&buf, data, "", "  "); err != nil {
+			return fmt.Errorf("failed to format response: %w", err)
+		}
+		buf.WriteByte('\n')
+		_, err := buf.WriteTo(p.w)
+		return err
+	}
+
+	var value interface{}
+	if err := json.Unmarshal(data, &value); err != nil {
+		return fmt.Errorf("failed to decode response: %w", err)
+	}
+
+	if list, ok := value.([]interface{}); ok {
+		for i, item := range list {
+			if i > 0 {
+				fmt.Fprintln(p.w)
+			}
+			p.printText(item)
+		}
+		return nil
+	}
+	p.printText(value)
+	return nil
+}
+
+// printText writes a decoded JSON value as text.
+func (p *printer) printText(value interface{}) {
+	object, ok := value.(map[string]interface{})
+	if !ok {
+		fmt.Fprintln(p.w, textValue(value))
+		return
+	}
+
+	keys := make([]string, 0, len(object))
+	for key := range object {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	for _, key := range keys {
+		fmt.Fprintf(p.w, "%s: %s\n", key, textValue(object[key]))
+	}
+}
+
+// textValue formats a decoded JSON value on a single line.
+func textValue(value interface{}) string {
+	switch v := value.(type) {
+	case nil:
+		return ""
+	case string:
+		return v
+	case []interface{}:
+		items := make([]string, len(v))
+		for i, item := range v {
+			items[i] = textValue(item)
+		}
+		return strings.Join(items, ", ")
+	case map[string]interface{}:
+		encoded, _ := json.Marshal(v)
+		return string(encoded)
+	default:
+		return fmt.Sprint(v)
+	}
+}
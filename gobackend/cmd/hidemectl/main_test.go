@@ -0,0 +1,197 @@
+package main
+
+import (
+	"bytes"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const testAPIKey = "admin-key"
+
+// newTestServer serves a fake admin API that only accepts testAPIKey.
+func newTestServer(t *testing.T) *httptest.Server {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/api/admin/users", func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Query().Get("username") != "ola" {
+			w.WriteHeader(http.StatusNotFound)
+			w.Write([]byte(`{"success":false,"error":{"code":"not_found","message":"User not found"}}`))
+			return
+		}
+		w.Write([]byte(`{"success":true,"data":{"id":7,"username":"ola","email":"ola@example.com","role":"user"}}`))
+	})
+	mux.HandleFunc("/api/admin/users/7/sessions", func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, http.MethodDelete, r.Method)
+		w.Write([]byte(`{"success":true,"data":{"user_id":7,"revoked":2}}`))
+	})
+	mux.HandleFunc("/api/admin/maintenance", func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`{"success":true,"data":[{"name":"expired_sessions","description":"Delete expired sessions"},{"name":"scheduled_reports","description":"Send the scheduled reports that are due"}]}`))
+	})
+	mux.HandleFunc("/api/admin/maintenance/scheduled_reports", func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, http.MethodPost, r.Method)
+		w.Write([]byte(`{"success":true,"data":{"task":"scheduled_reports","count":0,"error":"smtp unavailable"}}`))
+	})
+	mux.HandleFunc("/api/admin/billing-export", func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "2024-05", r.URL.Query().Get("month"))
+		assert.Equal(t, "de-DE", r.URL.Query().Get("locale"))
+		w.Header().Set("Content-Type", "text/csv")
+		w.Write([]byte("month;user_id\n05.2024;7\n"))
+	})
+	mux.HandleFunc("/api/admin/config", func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`{"success":true,"data":{"valid":true,"errors":[],"warnings":["JWT secret is not set"]}}`))
+	})
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("X-API-Key") != testAPIKey {
+			w.WriteHeader(http.StatusUnauthorized)
+			w.Write([]byte(`{"success":false,"error":{"code":"unauthorized","message":"Invalid API key"}}`))
+			return
+		}
+		mux.ServeHTTP(w, r)
+	}))
+	t.Cleanup(server.Close)
+	return server
+}
+
+// runCLI runs hidemectl against the server and returns the exit code and output.
+func runCLI(server *httptest.Server, args ...string) (int, string, string) {
+	var stdout, stderr bytes.Buffer
+	args = append([]string{"--url", server.URL, "--api-key", testAPIKey}, args...)
+	code := run(args, &stdout, &stderr)
+	return code, stdout.String(), stderr.String()
+}
+
+func TestUserLookup(t *testing.T) {
+	server := newTestServer(t)
+
+	t.Run("JSON output", func(t *testing.T) {
+		code, stdout, _ := runCLI(server, "user", "lookup", "--username", "ola")
+
+		assert.Equal(t, exitOK, code)
+		assert.Contains(t, stdout, `"email": "ola@example.com"`)
+	})
+
+	t.Run("Text output", func(t *testing.T) {
+		code, stdout, _ := runCLI(server, "--output", "text", "user", "lookup", "--username", "ola")
+
+		assert.Equal(t, exitOK, code)
+		assert.Equal(t, "email: ola@example.com\nid: 7\nrole: user\nusername: ola\n", stdout)
+	})
+
+	t.Run("Not found", func(t *testing.T) {
+		code, stdout, stderr := runCLI(server, "user", "lookup", "--username", "nobody")
+
+		assert.Equal(t, exitError, code)
+		assert.Empty(t, stdout)
+		assert.Contains(t, stderr, "not_found (404): User not found")
+	})
+
+	t.Run("Several identifiers", func(t *testing.T) {
+		code, _, stderr := runCLI(server, "user", "lookup", "--id", "7", "--username", "ola")
+
+		assert.Equal(t, exitUsage, code)
+		assert.Contains(t, stderr, "exactly one of --id, --username or --email")
+	})
+}
+
+func TestSessionsRevoke(t *testing.T) {
+	server := newTestServer(t)
+
+	code, stdout, _ := runCLI(server, "sessions", "revoke", "--user", "7")
+
+	assert.Equal(t, exitOK, code)
+	assert.Contains(t, stdout, `"revoked": 2`)
+}
+
+func TestMaintenance(t *testing.T) {
+	server := newTestServer(t)
+
+	t.Run("List", func(t *testing.T) {
+		code, stdout, _ := runCLI(server, "--output", "text", "maintenance", "list")
+
+		assert.Equal(t, exitOK, code)
+		assert.Contains(t, stdout, "name: expired_sessions\n\ndescription: Send the scheduled reports that are due\nname: scheduled_reports\n")
+	})
+
+	t.Run("Failing task", func(t *testing.T) {
+		code, stdout, stderr := runCLI(server, "maintenance", "run", "scheduled_reports")
+
+		assert.Equal(t, exitError, code)
+		assert.Contains(t, stdout, `"task": "scheduled_reports"`)
+		assert.Contains(t, stderr, "task scheduled_reports failed: smtp unavailable")
+	})
+
+	t.Run("Missing task", func(t *testing.T) {
+		code, _, _ := runCLI(server, "maintenance", "run")
+
+		assert.Equal(t, exitUsage, code)
+	})
+}
+
+func TestExportBilling(t *testing.T) {
+	server := newTestServer(t)
+
+	t.Run("Standard output", func(t *testing.T) {
+		code, stdout, _ := runCLI(server, "export", "billing", "--month", "2024-05", "--locale", "de-DE")
+
+		assert.Equal(t, exitOK, code)
+		assert.Equal(t, "month;user_id\n05.2024;7\n", stdout)
+	})
+
+	t.Run("File", func(t *testing.T) {
+		path := filepath.Join(t.TempDir(), "billing.csv")
+		code, stdout, _ := runCLI(server, "export", "billing", "--month", "2024-05", "--locale", "de-DE", "--out", path)
+
+		assert.Equal(t, exitOK, code)
+		assert.Empty(t, stdout)
+		content, err := os.ReadFile(path)
+		require.NoError(t, err)
+		assert.Equal(t, "month;user_id\n05.2024;7\n", string(content))
+	})
+}
+
+func TestConfigCheck(t *testing.T) {
+	server := newTestServer(t)
+
+	code, stdout, _ := runCLI(server, "--output", "text", "config", "check")
+
+	assert.Equal(t, exitOK, code)
+	assert.Contains(t, stdout, "valid: true\nwarnings: JWT secret is not set\n")
+}
+
+func TestUsageErrors(t *testing.T) {
+	server := newTestServer(t)
+
+	tests := []struct {
+		name string
+		args []string
+	}{
+		{name: "No command", args: []string{"--url", server.URL, "--api-key", testAPIKey}},
+		{name: "Unknown command", args: []string{"--url", server.URL, "--api-key", testAPIKey, "tenant", "delete"}},
+		{name: "Unknown output format", args: []string{"--url", server.URL, "--api-key", testAPIKey, "--output", "yaml", "config", "check"}},
+		{name: "Missing API key", args: []string{"--url", server.URL, "--api-key", "", "config", "check"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var stdout, stderr bytes.Buffer
+			code := run(tt.args, &stdout, &stderr)
+
+			assert.Equal(t, exitUsage, code)
+			assert.Contains(t, stderr.String(), "Usage: hidemectl")
+		})
+	}
+
+	t.Run("Wrong API key", func(t *testing.T) {
+		var stdout, stderr bytes.Buffer
+		code := run([]string{"--url", server.URL, "--api-key", "user-key", "config", "check"}, &stdout, &stderr)
+
+		assert.Equal(t, exitError, code)
+		assert.Contains(t, stderr.String(), "unauthorized (401)")
+	})
+}
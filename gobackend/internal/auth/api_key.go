@@ -4,12 +4,12 @@
 package auth
 
 import (
+	"context"
 	"crypto/sha256"
 	"encoding/base64"
 	"encoding/hex"
 	"time"
 
-	"github.com/google/uuid"
 	"github.com/rs/zerolog/log"
 
 	"github.com/yasinhessnawi1/Hideme_Backend/internal/config"
@@ -18,6 +18,38 @@ import (
 	"github.com/yasinhessnawi1/Hideme_Backend/internal/utils"
 )
 
+// APIKeyVerifier resolves an API key to the user who owns it.
+// It is implemented by the auth service and used to authenticate requests made with an API key.
+type APIKeyVerifier interface {
+	// VerifyAPIKey returns the owner of an active API key.
+	//
+	// Parameters:
+	//   - ctx: Context for the operation
+	//   - apiKey: The raw API key sent by the client
+	//
+	// Returns:
+	//   - The user owning the key, with sensitive fields removed
+	//   - An error if the key is unknown, expired or cannot be checked
+	VerifyAPIKey(ctx context.Context, apiKey string) (*models.User, error)
+}
+
+// APIKeyScopeVerifier resolves an API key to its owner and the scopes the key is limited to.
+// APIKeyAuth adds the scopes to the context when the verifier it is given implements it, so
+// RequireScope can be applied after it.
+type APIKeyScopeVerifier interface {
+	// VerifyAPIKeyScopes returns the owner of an active API key and the key's scopes.
+	//
+	// Parameters:
+	//   - ctx: Context for the operation
+	//   - apiKey: The raw API key sent by the client
+	//
+	// Returns:
+	//   - The user owning the key, with sensitive fields removed
+	//   - The constants.APIKeyScope values the key is limited to; empty for full access
+	//   - An error if the key is unknown, expired or cannot be checked
+	VerifyAPIKeyScopes(ctx context.Context, apiKey string) (*models.User, []string, error)
+}
+
 // APIKeyService handles the generation and management of API keys.
 // It provides methods for creating, encrypting, and validating API keys
 // with configurable settings for expiration and security.
@@ -54,8 +86,8 @@ func NewAPIKeyService(config *config.APIKeySettings) *APIKeyService {
 //   - apiKey: The raw API key to be provided to the user (only returned once)
 //   - error: Any error encountered during generation
 func (s *APIKeyService) GenerateAPIKey(userID int64, name string, duration time.Duration) (*models.APIKey, string, error) {
-	// Generate a UUID for internal reference only
-	keyID := uuid.New().String()
+	// Generate a time-sortable ID for internal reference only
+	keyID := utils.NewID()
 
 	// Generate a cryptographically secure random key (32 bytes for AES-256, or 16 for AES-128)
 	keyBytes, err := GenerateRandomBytes(32) // 32 bytes = 256 bits (recommended)
@@ -140,7 +172,7 @@ func HashAPIKey(apiKey string, encryptionKey []byte) string {
 //   - key: The API key itself
 //   - error: Any error encountered during parsing
 func ParseAPIKey(apiKey string) (string, string, error) {
-	keyID := uuid.New().String()
+	keyID := utils.NewID()
 	return keyID, apiKey, nil
 }
 
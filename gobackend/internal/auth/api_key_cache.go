@@ -0,0 +1,241 @@
+// Package auth provides authentication and authorization functionality for the HideMe API.
+// This file implements caching of API key verifications. Verifying a key decrypts or hashes
+// every stored key, which made key-authenticated requests much slower than JWT ones, so
+// successful verifications are reused for a short time. Clients sending invalid keys are
+// made to wait, with the wait doubling on every further invalid key, so the cache cannot
+// be used to guess keys faster.
+package auth
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"sync"
+	"time"
+
+	"github.com/yasinhessnawi1/Hideme_Backend/internal/config"
+	"github.com/yasinhessnawi1/Hideme_Backend/internal/constants"
+	"github.com/yasinhessnawi1/Hideme_Backend/internal/models"
+	"github.com/yasinhessnawi1/Hideme_Backend/internal/utils/clock"
+)
+
+// APIKeyBackoff makes clients that sent invalid API keys wait before they may try again.
+// APIKeyAuth applies it when the verifier it is given implements it.
+type APIKeyBackoff interface {
+	// RetryAfter returns how long a client must still wait before sending another key.
+	//
+	// Parameters:
+	//   - ip: The IP address of the client
+	//
+	// Returns:
+	//   - The remaining wait; 0 if the client may send a key now
+	RetryAfter(ip string) time.Duration
+
+	// RecordFailure registers an invalid key sent by a client.
+	//
+	// Parameters:
+	//   - ip: The IP address of the client
+	//
+	// Returns:
+	//   - How long the client must now wait
+	RecordFailure(ip string) time.Duration
+
+	// RecordSuccess clears the failures of a client after it sent a valid key.
+	//
+	// Parameters:
+	//   - ip: The IP address of the client
+	RecordSuccess(ip string)
+}
+
+// cachedAPIKey is a successful verification kept in the cache.
+type cachedAPIKey struct {
+	user      *models.User
+	scopes    []string
+	expiresAt time.Time
+}
+
+// apiKeyFailures tracks the consecutive invalid keys sent by a client.
+type apiKeyFailures struct {
+	count        int
+	blockedUntil time.Time
+}
+
+// CachingAPIKeyVerifier verifies API keys with another verifier and caches the results.
+// Keys are only kept as SHA-256 digests, so the cache never holds a usable key.
+type CachingAPIKeyVerifier struct {
+	verifier   APIKeyVerifier
+	ttl        time.Duration
+	backoff    time.Duration
+	maxBackoff time.Duration
+	clock      clock.Clock
+
+	mu       sync.Mutex
+	verified map[string]cachedAPIKey
+	failures map[string]*apiKeyFailures
+}
+
+// NewCachingAPIKeyVerifier creates a verifier that caches the verifications of another.
+//
+// Parameters:
+//   - verifier: The verifier checking keys that are not cached
+//   - settings: The cache TTL and failure backoff
+//
+// Returns:
+//   - A properly initialized CachingAPIKeyVerifier
+func NewCachingAPIKeyVerifier(verifier APIKeyVerifier, settings *config.APIKeySettings) *CachingAPIKeyVerifier {
+	return &CachingAPIKeyVerifier{
+		verifier:   verifier,
+		ttl:        settings.CacheTTL,
+		backoff:    settings.FailureBackoff,
+		maxBackoff: settings.MaxFailureBackoff,
+		verified:   make(map[string]cachedAPIKey),
+		failures:   make(map[string]*apiKeyFailures),
+	}
+}
+
+// SetClock sets the clock cached keys and failure backoffs expire by, so
+// tests can check a key at the instant its cache entry expires.
+func (c *CachingAPIKeyVerifier) SetClock(clk clock.Clock) {
+	c.clock = clk
+}
+
+// now returns the current time of the verifier's clock.
+func (c *CachingAPIKeyVerifier) now() time.Time {
+	return clock.Or(c.clock).Now()
+}
+
+// VerifyAPIKey returns the owner of an active API key, from the cache if the key was
+// verified within the TTL. Only successful verifications are cached.
+//
+// Parameters:
+//   - ctx: Context for the operation
+//   - apiKey: The raw API key sent by the client
+//
+// Returns:
+//   - The user owning the key, with sensitive fields removed
+//   - An error if the key is unknown, expired or cannot be checked
+func (c *CachingAPIKeyVerifier) VerifyAPIKey(ctx context.Context, apiKey string) (*models.User, error) {
+	user, _, err := c.VerifyAPIKeyScopes(ctx, apiKey)
+	return user, err
+}
+
+// VerifyAPIKeyScopes returns the owner of an active API key and the key's scopes, from the
+// cache if the key was verified within the TTL. Keys verified by a verifier that does not
+// implement APIKeyScopeVerifier have full access.
+//
+// Parameters:
+//   - ctx: Context for the operation
+//   - apiKey: The raw API key sent by the client
+//
+// Returns:
+//   - The user owning the key, with sensitive fields removed
+//   - The scopes of the key; empty for full access
+//   - An error if the key is unknown, expired or cannot be checked
+func (c *CachingAPIKeyVerifier) VerifyAPIKeyScopes(ctx context.Context, apiKey string) (*models.User, []string, error) {
+	digest := apiKeyDigest(apiKey)
+	now := c.now()
+
+	c.mu.Lock()
+	entry, ok := c.verified[digest]
+	c.mu.Unlock()
+	if ok && now.Before(entry.expiresAt) {
+		// Hand out copies so callers cannot change the cached user or scopes
+		user := *entry.user
+		return &user, append([]string(nil), entry.scopes...), nil
+	}
+
+	var user *models.User
+	var scopes []string
+	var err error
+	if scoped, ok := c.verifier.(APIKeyScopeVerifier); ok {
+		user, scopes, err = scoped.VerifyAPIKeyScopes(ctx, apiKey)
+	} else {
+		user, err = c.verifier.VerifyAPIKey(ctx, apiKey)
+	}
+	if err != nil {
+		return nil, nil, err
+	}
+
+	cached := *user
+	c.mu.Lock()
+	if len(c.verified) >= constants.APIKeyCacheMaxEntries {
+		c.pruneLocked(now)
+	}
+	c.verified[digest] = cachedAPIKey{user: &cached, scopes: append([]string(nil), scopes...), expiresAt: now.Add(c.ttl)}
+	c.mu.Unlock()
+
+	return user, scopes, nil
+}
+
+// RetryAfter returns how long a client must still wait before sending another key.
+func (c *CachingAPIKeyVerifier) RetryAfter(ip string) time.Duration {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	failures, ok := c.failures[ip]
+	if !ok {
+		return 0
+	}
+	if wait := failures.blockedUntil.Sub(c.now()); wait > 0 {
+		return wait
+	}
+	return 0
+}
+
+// RecordFailure registers an invalid key sent by a client. The client must wait the
+// failure backoff, doubled for every earlier consecutive failure and capped at the maximum.
+func (c *CachingAPIKeyVerifier) RecordFailure(ip string) time.Duration {
+	now := c.now()
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	failures, ok := c.failures[ip]
+	if !ok {
+		if len(c.failures) >= constants.APIKeyCacheMaxEntries {
+			c.pruneLocked(now)
+		}
+		failures = &apiKeyFailures{}
+		c.failures[ip] = failures
+	}
+	failures.count++
+
+	wait := c.backoff
+	for i := 1; i < failures.count && wait < c.maxBackoff; i++ {
+		wait *= 2
+	}
+	if wait > c.maxBackoff {
+		wait = c.maxBackoff
+	}
+	failures.blockedUntil = now.Add(wait)
+
+	return wait
+}
+
+// RecordSuccess clears the failures of a client after it sent a valid key.
+func (c *CachingAPIKeyVerifier) RecordSuccess(ip string) {
+	c.mu.Lock()
+	delete(c.failures, ip)
+	c.mu.Unlock()
+}
+
+// pruneLocked removes expired verifications and clients whose wait is over for longer
+// than the maximum backoff. The caller must hold the lock.
+func (c *CachingAPIKeyVerifier) pruneLocked(now time.Time) {
+	for digest, entry := range c.verified {
+		if !now.Before(entry.expiresAt) {
+			delete(c.verified, digest)
+		}
+	}
+	for ip, failures := range c.failures {
+		if now.Sub(failures.blockedUntil) > c.maxBackoff {
+			delete(c.failures, ip)
+		}
+	}
+}
+
+// apiKeyDigest returns the SHA-256 digest under which a key is cached.
+func apiKeyDigest(apiKey string) string {
+	hash := sha256.Sum256([]byte(apiKey))
+	return hex.EncodeToString(hash[:])
+}
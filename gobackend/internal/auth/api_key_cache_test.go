@@ -0,0 +1,169 @@
+package auth_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/yasinhessnawi1/Hideme_Backend/internal/auth"
+	"github.com/yasinhessnawi1/Hideme_Backend/internal/config"
+	"github.com/yasinhessnawi1/Hideme_Backend/internal/models"
+	"github.com/yasinhessnawi1/Hideme_Backend/internal/utils"
+)
+
+// countingVerifier counts the keys it is asked to verify
+type countingVerifier struct {
+	calls int
+	users map[string]*models.User
+	err   error
+}
+
+func (v *countingVerifier) VerifyAPIKey(ctx context.Context, apiKey string) (*models.User, error) {
+	v.calls++
+	if v.err != nil {
+		return nil, v.err
+	}
+	if user, ok := v.users[apiKey]; ok {
+		return user, nil
+	}
+	return nil, utils.NewInvalidTokenError()
+}
+
+func newCacheSettings(ttl time.Duration) *config.APIKeySettings {
+	return &config.APIKeySettings{CacheTTL: ttl, FailureBackoff: time.Second, MaxFailureBackoff: 10 * time.Second}
+}
+
+func TestCachingAPIKeyVerifier_VerifyAPIKey(t *testing.T) {
+	t.Run("Reuses successful verifications", func(t *testing.T) {
+		inner := &countingVerifier{users: map[string]*models.User{"valid": {ID: 7, Username: "operator"}}}
+		verifier := auth.NewCachingAPIKeyVerifier(inner, newCacheSettings(time.Minute))
+
+		for i := 0; i < 3; i++ {
+			user, err := verifier.VerifyAPIKey(context.Background(), "valid")
+			if err != nil {
+				t.Fatalf("VerifyAPIKey() error = %v", err)
+			}
+			if user.ID != 7 {
+				t.Errorf("VerifyAPIKey() user ID = %d, want 7", user.ID)
+			}
+			// Changing the returned user must not change the cached one
+			user.Username = "changed"
+		}
+
+		if inner.calls != 1 {
+			t.Errorf("Expected 1 verification, got %d", inner.calls)
+		}
+		user, _ := verifier.VerifyAPIKey(context.Background(), "valid")
+		if user.Username != "operator" {
+			t.Errorf("Cached username = %s, want operator", user.Username)
+		}
+	})
+
+	t.Run("Verifies again after the TTL", func(t *testing.T) {
+		inner := &countingVerifier{users: map[string]*models.User{"valid": {ID: 7}}}
+		verifier := auth.NewCachingAPIKeyVerifier(inner, newCacheSettings(time.Nanosecond))
+
+		_, _ = verifier.VerifyAPIKey(context.Background(), "valid")
+		time.Sleep(time.Millisecond)
+		_, _ = verifier.VerifyAPIKey(context.Background(), "valid")
+
+		if inner.calls != 2 {
+			t.Errorf("Expected 2 verifications, got %d", inner.calls)
+		}
+	})
+
+	t.Run("Does not cache failures", func(t *testing.T) {
+		inner := &countingVerifier{err: errors.New("database error")}
+		verifier := auth.NewCachingAPIKeyVerifier(inner, newCacheSettings(time.Minute))
+
+		for i := 0; i < 2; i++ {
+			if _, err := verifier.VerifyAPIKey(context.Background(), "valid"); err == nil {
+				t.Error("Expected an error")
+			}
+		}
+
+		if inner.calls != 2 {
+			t.Errorf("Expected 2 verifications, got %d", inner.calls)
+		}
+	})
+}
+
+func TestCachingAPIKeyVerifier_Backoff(t *testing.T) {
+	verifier := auth.NewCachingAPIKeyVerifier(&countingVerifier{}, newCacheSettings(time.Minute))
+
+	if wait := verifier.RetryAfter("192.0.2.1"); wait != 0 {
+		t.Errorf("RetryAfter() before any failure = %v, want 0", wait)
+	}
+
+	// The wait doubles with every failure up to the maximum
+	expected := []time.Duration{time.Second, 2 * time.Second, 4 * time.Second, 8 * time.Second, 10 * time.Second, 10 * time.Second}
+	for i, want := range expected {
+		if got := verifier.RecordFailure("192.0.2.1"); got != want {
+			t.Errorf("RecordFailure() #%d = %v, want %v", i+1, got, want)
+		}
+	}
+
+	if wait := verifier.RetryAfter("192.0.2.1"); wait <= 0 || wait > 10*time.Second {
+		t.Errorf("RetryAfter() = %v, want up to 10s", wait)
+	}
+	if wait := verifier.RetryAfter("192.0.2.2"); wait != 0 {
+		t.Errorf("RetryAfter() for another client = %v, want 0", wait)
+	}
+
+	// A valid key clears the failures
+	verifier.RecordSuccess("192.0.2.1")
+	if wait := verifier.RetryAfter("192.0.2.1"); wait != 0 {
+		t.Errorf("RetryAfter() after success = %v, want 0", wait)
+	}
+	if got := verifier.RecordFailure("192.0.2.1"); got != time.Second {
+		t.Errorf("RecordFailure() after success = %v, want 1s", got)
+	}
+}
+
+// scopedVerifier verifies keys with fixed scopes
+type scopedVerifier struct {
+	countingVerifier
+	scopes []string
+}
+
+func (v *scopedVerifier) VerifyAPIKeyScopes(ctx context.Context, apiKey string) (*models.User, []string, error) {
+	user, err := v.VerifyAPIKey(ctx, apiKey)
+	if err != nil {
+		return nil, nil, err
+	}
+	return user, v.scopes, nil
+}
+
+func TestCachingAPIKeyVerifier_VerifyAPIKeyScopes(t *testing.T) {
+	t.Run("Caches the scopes", func(t *testing.T) {
+		inner := &scopedVerifier{
+			countingVerifier: countingVerifier{users: map[string]*models.User{"valid": {ID: 7}}},
+			scopes:           []string{"settings:read"},
+		}
+		verifier := auth.NewCachingAPIKeyVerifier(inner, newCacheSettings(time.Minute))
+
+		for i := 0; i < 2; i++ {
+			_, scopes, err := verifier.VerifyAPIKeyScopes(context.Background(), "valid")
+			if err != nil {
+				t.Fatalf("VerifyAPIKeyScopes() error = %v", err)
+			}
+			if len(scopes) != 1 || scopes[0] != "settings:read" {
+				t.Errorf("VerifyAPIKeyScopes() scopes = %v, want [settings:read]", scopes)
+			}
+		}
+		if inner.calls != 1 {
+			t.Errorf("Inner verifier called %d times, want 1", inner.calls)
+		}
+	})
+
+	t.Run("Full access without scope support", func(t *testing.T) {
+		inner := &countingVerifier{users: map[string]*models.User{"valid": {ID: 7}}}
+		verifier := auth.NewCachingAPIKeyVerifier(inner, newCacheSettings(time.Minute))
+
+		_, scopes, err := verifier.VerifyAPIKeyScopes(context.Background(), "valid")
+		if err != nil || len(scopes) != 0 {
+			t.Errorf("VerifyAPIKeyScopes() = %v, %v; want no scopes", scopes, err)
+		}
+	})
+}
@@ -2,7 +2,6 @@
 package auth
 
 import (
-	"errors"
 	"fmt"
 	"strconv"
 	"time"
@@ -13,21 +12,24 @@ import (
 	"github.com/yasinhessnawi1/Hideme_Backend/internal/config"
 	"github.com/yasinhessnawi1/Hideme_Backend/internal/constants"
 	"github.com/yasinhessnawi1/Hideme_Backend/internal/utils"
+	"github.com/yasinhessnawi1/Hideme_Backend/internal/utils/clock"
 )
 
 // JWT error definitions provide standardized errors for token-related failures.
+// They are, or wrap, the token errors of the utils package, so callers can test
+// for them with errors.Is and utils.ParseError maps them to a 401 response.
 var (
 	// ErrInvalidToken is returned when a token's format, signature, or payload is invalid.
-	ErrInvalidToken = errors.New(constants.ErrorInvalidToken)
+	ErrInvalidToken = utils.ErrInvalidToken
 
 	// ErrExpiredToken is returned when a token has expired.
-	ErrExpiredToken = errors.New(constants.ErrorExpiredToken)
+	ErrExpiredToken = utils.ErrExpiredToken
 
 	// ErrInvalidSigningMethod is returned when a token uses an unexpected signing method.
-	ErrInvalidSigningMethod = errors.New("invalid signing method")
+	ErrInvalidSigningMethod = utils.NewKindError(utils.ErrInvalidToken, "invalid signing method")
 
 	// ErrInvalidTokenClaims is returned when a token's claims cannot be parsed or are invalid.
-	ErrInvalidTokenClaims = errors.New("invalid token claims")
+	ErrInvalidTokenClaims = utils.NewKindError(utils.ErrInvalidToken, "invalid token claims")
 )
 
 // CustomClaims represents the claims in a JWT token, including both standard
@@ -57,6 +59,9 @@ type CustomClaims struct {
 type JWTService struct {
 	// Config contains configuration settings for token generation and validation.
 	Config *config.JWTSettings
+
+	// clock tells the time tokens are issued at and checked against; the system clock if nil.
+	clock clock.Clock
 }
 
 // NewJWTService creates a new JWTService instance with the provided configuration.
@@ -72,6 +77,17 @@ func NewJWTService(config *config.JWTSettings) *JWTService {
 	}
 }
 
+// SetClock sets the clock tokens are issued at and checked against, so tests can
+// check tokens at the instant they expire.
+func (s *JWTService) SetClock(c clock.Clock) {
+	s.clock = c
+}
+
+// now returns the current time of the service's clock.
+func (s *JWTService) now() time.Time {
+	return clock.Or(s.clock).Now()
+}
+
 // GetConfig returns the JWT settings configuration used by this service.
 // If no configuration was provided, it returns default settings.
 //
@@ -148,7 +164,7 @@ func (s *JWTService) generateToken(userID int64, username, email, role, tokenTyp
 	jwtID := uuid.New().String()
 
 	// Create claims with user information and expiry time
-	now := time.Now()
+	now := s.now()
 	claims := CustomClaims{
 		UserID:    userID,
 		Username:  username,
@@ -188,24 +204,21 @@ func (s *JWTService) generateToken(userID int64, username, email, role, tokenTyp
 //   - A pointer to CustomClaims containing the token's payload if valid
 //   - An error describing why validation failed, or nil if successful
 func (s *JWTService) ValidateToken(tokenString string, expectedType string) (*CustomClaims, error) {
-	// Parse the token with our custom claims type
-	token, err := jwt.ParseWithClaims(tokenString, &CustomClaims{}, func(token *jwt.Token) (interface{}, error) {
+	// Parse the token with our custom claims type. The time claims are checked below
+	// against the service's clock instead of the jwt package's global clock.
+	parser := jwt.NewParser(jwt.WithoutClaimsValidation())
+	token, err := parser.ParseWithClaims(tokenString, &CustomClaims{}, func(token *jwt.Token) (interface{}, error) {
 		// Validate the signing method is HMAC-SHA256
 		if _, ok := token.Method.(*jwt.SigningMethodHMAC); !ok {
 			return nil, ErrInvalidSigningMethod
 		}
 		return []byte(s.Config.Secret), nil
 	})
-
-	// Handle parsing errors with specific error types
 	if err != nil {
-		if errors.Is(err, jwt.ErrTokenExpired) {
-			return nil, utils.NewExpiredTokenError()
-		}
 		return nil, utils.NewInvalidTokenError()
 	}
 
-	// Check if the token is valid (correctly signed and not expired)
+	// Check if the token is valid (correctly signed)
 	if !token.Valid {
 		return nil, utils.NewInvalidTokenError()
 	}
@@ -216,6 +229,15 @@ func (s *JWTService) ValidateToken(tokenString string, expectedType string) (*Cu
 		return nil, utils.NewInvalidTokenError()
 	}
 
+	// Check that the token has not expired and is already valid
+	now := s.now()
+	if !claims.VerifyExpiresAt(now, false) {
+		return nil, utils.NewExpiredTokenError()
+	}
+	if !claims.VerifyIssuedAt(now, false) || !claims.VerifyNotBefore(now, false) {
+		return nil, utils.NewInvalidTokenError()
+	}
+
 	// Validate the token type matches the expected type
 	if claims.TokenType != expectedType {
 		return nil, utils.NewInvalidTokenError()
@@ -8,6 +8,8 @@ import (
 	"github.com/golang-jwt/jwt/v4"
 	"github.com/yasinhessnawi1/Hideme_Backend/internal/auth"
 	"github.com/yasinhessnawi1/Hideme_Backend/internal/config"
+	"github.com/yasinhessnawi1/Hideme_Backend/internal/testsupport/fakeclock"
+	"github.com/yasinhessnawi1/Hideme_Backend/internal/testsupport/fixtures"
 	"github.com/yasinhessnawi1/Hideme_Backend/internal/utils"
 )
 
@@ -369,6 +371,40 @@ func TestValidateToken(t *testing.T) {
 	}
 }
 
+func TestValidateTokenExpiry(t *testing.T) {
+	// Create service with a clock the test moves
+	clock := fakeclock.New(fixtures.Time)
+	service := auth.NewJWTService(&config.JWTSettings{
+		Secret:        "test-secret",
+		Expiry:        15 * time.Minute,
+		RefreshExpiry: 7 * 24 * time.Hour,
+		Issuer:        "test-issuer",
+	})
+	service.SetClock(clock)
+
+	token, _, err := service.GenerateAccessToken(123, "testuser", "test@example.com", "user")
+	if err != nil {
+		t.Fatalf("Failed to generate test token: %v", err)
+	}
+
+	// The token is valid until the instant it expires
+	clock.Advance(15*time.Minute - time.Second)
+	if _, err := service.ValidateToken(token, "access"); err != nil {
+		t.Errorf("Expected the token to be valid a second before it expires, got %v", err)
+	}
+
+	clock.Advance(time.Second)
+	if _, err := service.ValidateToken(token, "access"); !errors.Is(err, utils.ErrExpiredToken) {
+		t.Errorf("Expected the token to be expired when it expires, got %v", err)
+	}
+
+	// A token is not valid before it was issued
+	clock.Set(fixtures.Time.Add(-time.Second))
+	if _, err := service.ValidateToken(token, "access"); !errors.Is(err, utils.ErrInvalidToken) {
+		t.Errorf("Expected the token to be invalid before it was issued, got %v", err)
+	}
+}
+
 func TestParseTokenWithoutValidation(t *testing.T) {
 	// Create config
 	cfg := &config.JWTSettings{
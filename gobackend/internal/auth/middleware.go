@@ -31,6 +31,9 @@ const (
 
 	// RequestIDContextKey is the context key for storing the unique request ID.
 	RequestIDContextKey ContextKey = constants.RequestIDContextKey
+
+	// APIKeyScopesContextKey is the context key for storing the scopes of the API key used.
+	APIKeyScopesContextKey ContextKey = constants.APIKeyScopesContextKey
 )
 
 // AuthProvider defines methods for different authentication mechanisms.
@@ -266,6 +269,20 @@ func GetUserID(r *http.Request) (int64, bool) {
 	return userID, ok
 }
 
+// GetAPIKeyScopes extracts the scopes of the API key the request was authenticated with.
+// It returns the scopes and a boolean indicating if the request was authenticated with an API key.
+//
+// Parameters:
+//   - r: The HTTP request containing the context
+//
+// Returns:
+//   - The scopes of the key; empty for a key with full access
+//   - A boolean indicating if the request was authenticated with an API key
+func GetAPIKeyScopes(r *http.Request) ([]string, bool) {
+	scopes, ok := r.Context().Value(APIKeyScopesContextKey).([]string)
+	return scopes, ok
+}
+
 // GetUsername extracts the username from the request context.
 // It returns the username and a boolean indicating if it was found.
 //
@@ -0,0 +1,119 @@
+package oauth
+
+import (
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+)
+
+// ErrInvalidFlow is returned when a sign-in flow is forged, expired or does not match the callback.
+var ErrInvalidFlow = errors.New("invalid sign-in flow")
+
+// Flow is a sign-in with a provider in progress. It is kept in a signed cookie in the
+// browser that started it, so the callback only completes sign-ins that browser started:
+// the state the provider passes back must match the cookie, which defeats login CSRF.
+type Flow struct {
+	// Provider is the provider the user signs in with
+	Provider string `json:"provider"`
+
+	// State is passed to the provider and must come back with the authorization code
+	State string `json:"state"`
+
+	// Nonce is passed to the provider and must be contained in the ID token
+	Nonce string `json:"nonce"`
+
+	// Verifier is the PKCE code verifier the authorization code is exchanged with
+	Verifier string `json:"verifier"`
+
+	// ExpiresAt is when the user has to have completed the sign-in
+	ExpiresAt time.Time `json:"expires_at"`
+}
+
+// NewFlow starts a sign-in with fresh random state, nonce and code verifier.
+//
+// Parameters:
+//   - provider: The provider the user signs in with
+//   - expiresAt: When the user has to have completed the sign-in
+//
+// Returns:
+//   - The new flow
+//   - An error if random values could not be generated
+func NewFlow(provider string, expiresAt time.Time) (*Flow, error) {
+	values := make([]string, 3)
+	for i := range values {
+		b := make([]byte, 32)
+		if _, err := rand.Read(b); err != nil {
+			return nil, fmt.Errorf("failed to generate sign-in flow: %w", err)
+		}
+		values[i] = base64.RawURLEncoding.EncodeToString(b)
+	}
+
+	return &Flow{
+		Provider:  provider,
+		State:     values[0],
+		Nonce:     values[1],
+		Verifier:  values[2],
+		ExpiresAt: expiresAt,
+	}, nil
+}
+
+// CodeChallenge returns the S256 PKCE code challenge of the verifier.
+func (f *Flow) CodeChallenge() string {
+	sum := sha256.Sum256([]byte(f.Verifier))
+	return base64.RawURLEncoding.EncodeToString(sum[:])
+}
+
+// Encode serializes the flow for the cookie, followed by its HMAC-SHA256.
+//
+// Parameters:
+//   - key: The secret the flow is signed with
+//
+// Returns:
+//   - The signed flow
+func (f *Flow) Encode(key []byte) string {
+	payload, _ := json.Marshal(f)
+	encoded := base64.RawURLEncoding.EncodeToString(payload)
+	return encoded + "." + flowSignature(key, encoded)
+}
+
+// DecodeFlow checks the signature and expiry of a flow read from the cookie.
+//
+// Parameters:
+//   - key: The secret the flow was signed with
+//   - value: The signed flow
+//   - now: The current time
+//
+// Returns:
+//   - The flow
+//   - ErrInvalidFlow if the signature is wrong or the flow expired
+func DecodeFlow(key []byte, value string, now time.Time) (*Flow, error) {
+	encoded, signature, ok := strings.Cut(value, ".")
+	if !ok || !hmac.Equal([]byte(signature), []byte(flowSignature(key, encoded))) {
+		return nil, ErrInvalidFlow
+	}
+
+	payload, err := base64.RawURLEncoding.DecodeString(encoded)
+	if err != nil {
+		return nil, ErrInvalidFlow
+	}
+
+	var flow Flow
+	if err := json.Unmarshal(payload, &flow); err != nil || !now.Before(flow.ExpiresAt) {
+		return nil, ErrInvalidFlow
+	}
+
+	return &flow, nil
+}
+
+// flowSignature returns the HMAC-SHA256 of an encoded flow.
+func flowSignature(key []byte, encoded string) string {
+	mac := hmac.New(sha256.New, key)
+	mac.Write([]byte(encoded))
+	return base64.RawURLEncoding.EncodeToString(mac.Sum(nil))
+}
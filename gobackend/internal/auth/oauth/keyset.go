@@ -0,0 +1,146 @@
+package oauth
+
+import (
+	"context"
+	"crypto/rsa"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math/big"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/golang-jwt/jwt/v4"
+
+	"github.com/yasinhessnawi1/Hideme_Backend/internal/constants"
+	"github.com/yasinhessnawi1/Hideme_Backend/internal/utils/clock"
+)
+
+// errUnknownKey is returned when a token is signed with a key the provider does not publish.
+var errUnknownKey = errors.New("token signed with an unknown key")
+
+// keyFetchError is returned when the signing keys of a provider could not be fetched.
+type keyFetchError struct {
+	err error
+}
+
+func (e *keyFetchError) Error() string { return e.err.Error() }
+
+func (e *keyFetchError) Unwrap() error { return e.err }
+
+// keySet caches the public keys a provider signs ID tokens with, from its JWKS endpoint.
+// Providers rotate their keys, so a token signed with an unknown key fetches the keys
+// again, at most once per OAuthKeySetMinRefresh so forged key IDs cannot flood the provider.
+type keySet struct {
+	url    string
+	client *http.Client
+	clock  clock.Clock
+
+	mu        sync.Mutex
+	keys      map[string]*rsa.PublicKey
+	fetchedAt time.Time
+}
+
+// newKeySet creates an empty key set for a JWKS endpoint.
+func newKeySet(url string, client *http.Client) *keySet {
+	return &keySet{
+		url:    url,
+		client: client,
+	}
+}
+
+// SetClock sets the clock the cached keys are aged by.
+func (k *keySet) SetClock(c clock.Clock) {
+	k.clock = c
+}
+
+// now returns the current time of the key set's clock.
+func (k *keySet) now() time.Time {
+	return clock.Or(k.clock).Now()
+}
+
+// keyFunc returns the jwt.Keyfunc looking up the key a token names in its kid header.
+func (k *keySet) keyFunc(ctx context.Context) jwt.Keyfunc {
+	return func(token *jwt.Token) (interface{}, error) {
+		kid, _ := token.Header["kid"].(string)
+		return k.get(ctx, kid)
+	}
+}
+
+// get returns the key with an ID, fetching the keys if they are stale or do not contain it.
+func (k *keySet) get(ctx context.Context, kid string) (*rsa.PublicKey, error) {
+	k.mu.Lock()
+	defer k.mu.Unlock()
+
+	now := k.now()
+	if key, ok := k.keys[kid]; ok && now.Sub(k.fetchedAt) < constants.OAuthKeySetTTL {
+		return key, nil
+	}
+
+	if k.keys == nil || now.Sub(k.fetchedAt) >= constants.OAuthKeySetMinRefresh {
+		keys, err := k.fetch(ctx)
+		if err != nil {
+			return nil, &keyFetchError{err: err}
+		}
+		k.keys, k.fetchedAt = keys, now
+	}
+
+	if key, ok := k.keys[kid]; ok {
+		return key, nil
+	}
+	return nil, errUnknownKey
+}
+
+// fetch downloads the RSA signing keys from the JWKS endpoint, skipping keys of other types.
+func (k *keySet) fetch(ctx context.Context) (map[string]*rsa.PublicKey, error) {
+	ctx, cancel := context.WithTimeout(ctx, constants.OAuthRequestTimeout)
+	defer cancel()
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, k.url, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create key set request: %w", err)
+	}
+
+	resp, err := k.client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch key set: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("key set request failed with status %d", resp.StatusCode)
+	}
+
+	var body struct {
+		Keys []struct {
+			KeyType string `json:"kty"`
+			KeyID   string `json:"kid"`
+			Use     string `json:"use"`
+			N       string `json:"n"`
+			E       string `json:"e"`
+		} `json:"keys"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&body); err != nil {
+		return nil, fmt.Errorf("failed to decode key set: %w", err)
+	}
+
+	keys := make(map[string]*rsa.PublicKey)
+	for _, jwk := range body.Keys {
+		if jwk.KeyType != "RSA" || (jwk.Use != "" && jwk.Use != "sig") {
+			continue
+		}
+		n, errN := base64.RawURLEncoding.DecodeString(jwk.N)
+		e, errE := base64.RawURLEncoding.DecodeString(jwk.E)
+		if errN != nil || errE != nil || len(e) == 0 || len(e) > 4 {
+			continue
+		}
+		keys[jwk.KeyID] = &rsa.PublicKey{
+			N: new(big.Int).SetBytes(n),
+			E: int(new(big.Int).SetBytes(e).Int64()),
+		}
+	}
+
+	return keys, nil
+}
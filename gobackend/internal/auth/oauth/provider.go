@@ -0,0 +1,322 @@
+// Package oauth implements sign-in with OpenID Connect providers for the HideMe API.
+//
+// Users are sent to the provider with the authorization code flow, protected with PKCE,
+// and the code is exchanged for an ID token whose signature, issuer, audience, expiry
+// and nonce are verified here. Google and Microsoft are supported; their endpoints are
+// fixed, so no discovery document is fetched.
+package oauth
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+
+	"github.com/golang-jwt/jwt/v4"
+
+	"github.com/yasinhessnawi1/Hideme_Backend/internal/constants"
+	"github.com/yasinhessnawi1/Hideme_Backend/internal/utils/clock"
+)
+
+// OAuth error definitions distinguish failures caused by the user or provider from outages.
+var (
+	// ErrCodeRejected is returned when the provider rejects an authorization code,
+	// because it expired, was already used or was issued to another client.
+	ErrCodeRejected = errors.New("authorization code rejected")
+
+	// ErrInvalidIDToken is returned when an ID token fails verification.
+	ErrInvalidIDToken = errors.New("invalid ID token")
+)
+
+// microsoftTenantAliases are the Microsoft tenants admitting accounts of any organization.
+var microsoftTenantAliases = map[string]bool{"common": true, "organizations": true, "consumers": true}
+
+// Identity is the user a provider vouches for in an ID token.
+type Identity struct {
+	// Provider is the provider the user signed in with
+	Provider string
+
+	// Subject identifies the user at the provider; it never changes, unlike the email address
+	Subject string
+
+	// Email is the email address of the user; empty if the provider returned none
+	Email string
+
+	// EmailVerified tells whether the provider verified that the user owns the email address
+	EmailVerified bool
+
+	// Name is the display name of the user
+	Name string
+}
+
+// flexibleBool decodes a claim some providers send as a boolean and others as a string.
+type flexibleBool bool
+
+// UnmarshalJSON accepts true, false, "true" and "false".
+func (b *flexibleBool) UnmarshalJSON(data []byte) error {
+	*b = flexibleBool(strings.Trim(string(data), `"`) == "true")
+	return nil
+}
+
+// idTokenClaims are the claims of an ID token used for signing in.
+type idTokenClaims struct {
+	jwt.RegisteredClaims
+
+	Nonce             string       `json:"nonce"`
+	Email             string       `json:"email"`
+	EmailVerified     flexibleBool `json:"email_verified"`
+	Name              string       `json:"name"`
+	PreferredUsername string       `json:"preferred_username"`
+
+	// TenantID is the Microsoft tenant of the account
+	TenantID string `json:"tid"`
+
+	// EmailDomainOwnerVerified is the optional Microsoft claim that the tenant owns the email domain
+	EmailDomainOwnerVerified flexibleBool `json:"xms_edov"`
+}
+
+// Provider is an OpenID Connect provider users can sign in with.
+type Provider struct {
+	name         string
+	clientID     string
+	clientSecret string
+	authURL      string
+	tokenURL     string
+	authParams   url.Values
+	keys         *keySet
+	client       *http.Client
+
+	// identify checks the provider-specific claims and builds the identity from them
+	identify func(claims *idTokenClaims) (*Identity, error)
+}
+
+// NewGoogleProvider creates the provider for signing in with Google.
+//
+// Parameters:
+//   - clientID: The OAuth client ID
+//   - clientSecret: The OAuth client secret
+//   - client: The HTTP client for requests to Google
+//
+// Returns:
+//   - A configured Provider
+func NewGoogleProvider(clientID, clientSecret string, client *http.Client) *Provider {
+	return &Provider{
+		name:         constants.OAuthProviderGoogle,
+		clientID:     clientID,
+		clientSecret: clientSecret,
+		authURL:      constants.GoogleAuthURL,
+		tokenURL:     constants.GoogleTokenURL,
+		// Let users with several Google accounts choose which one to sign in with
+		authParams: url.Values{"prompt": {"select_account"}},
+		keys:       newKeySet(constants.GoogleJWKSURL, client),
+		client:     client,
+		identify: func(claims *idTokenClaims) (*Identity, error) {
+			// Older Google tokens carry the issuer without the scheme
+			if claims.Issuer != constants.GoogleIssuer && claims.Issuer != strings.TrimPrefix(constants.GoogleIssuer, "https://") {
+				return nil, fmt.Errorf("%w: unexpected issuer %q", ErrInvalidIDToken, claims.Issuer)
+			}
+			return &Identity{
+				Provider:      constants.OAuthProviderGoogle,
+				Subject:       claims.Subject,
+				Email:         claims.Email,
+				EmailVerified: bool(claims.EmailVerified),
+				Name:          claims.Name,
+			}, nil
+		},
+	}
+}
+
+// NewMicrosoftProvider creates the provider for signing in with Microsoft.
+//
+// Microsoft does not verify the email claim, so it is only trusted when the tenant
+// vouches for the domain with the xms_edov claim, or when the server admits a single
+// tenant, whose administrators manage the addresses of its accounts.
+//
+// Parameters:
+//   - clientID: The OAuth client ID
+//   - clientSecret: The OAuth client secret
+//   - tenant: The tenant ID to admit, or common, organizations or consumers
+//   - client: The HTTP client for requests to Microsoft
+//
+// Returns:
+//   - A configured Provider
+func NewMicrosoftProvider(clientID, clientSecret, tenant string, client *http.Client) *Provider {
+	singleTenant := !microsoftTenantAliases[tenant]
+
+	return &Provider{
+		name:         constants.OAuthProviderMicrosoft,
+		clientID:     clientID,
+		clientSecret: clientSecret,
+		authURL:      fmt.Sprintf(constants.MicrosoftAuthURLFormat, tenant),
+		tokenURL:     fmt.Sprintf(constants.MicrosoftTokenURLFormat, tenant),
+		authParams:   url.Values{"prompt": {"select_account"}},
+		keys:         newKeySet(fmt.Sprintf(constants.MicrosoftJWKSURLFormat, tenant), client),
+		client:       client,
+		identify: func(claims *idTokenClaims) (*Identity, error) {
+			// Multi-tenant endpoints issue tokens in the name of the account's own tenant
+			if claims.TenantID == "" || claims.Issuer != fmt.Sprintf(constants.MicrosoftIssuerFormat, claims.TenantID) {
+				return nil, fmt.Errorf("%w: unexpected issuer %q", ErrInvalidIDToken, claims.Issuer)
+			}
+			if singleTenant && !strings.EqualFold(claims.TenantID, tenant) {
+				return nil, fmt.Errorf("%w: unexpected tenant %q", ErrInvalidIDToken, claims.TenantID)
+			}
+
+			email := claims.Email
+			if email == "" && strings.Contains(claims.PreferredUsername, "@") {
+				email = claims.PreferredUsername
+			}
+			return &Identity{
+				Provider:      constants.OAuthProviderMicrosoft,
+				Subject:       claims.Subject,
+				Email:         email,
+				EmailVerified: email != "" && (singleTenant || bool(claims.EmailDomainOwnerVerified)),
+				Name:          claims.Name,
+			}, nil
+		},
+	}
+}
+
+// SetClock sets the clock ID tokens are checked against and the cached signing keys
+// are aged by, so tests can check a token at the instant it expires.
+func (p *Provider) SetClock(c clock.Clock) {
+	p.keys.SetClock(c)
+}
+
+// Name returns the name of the provider, such as google.
+func (p *Provider) Name() string {
+	return p.name
+}
+
+// AuthCodeURL returns the sign-in page of the provider for a flow.
+//
+// Parameters:
+//   - flow: The sign-in flow, whose state, nonce and code challenge are passed on
+//   - redirectURI: Where the provider sends the user back to
+//
+// Returns:
+//   - The URL of the sign-in page
+func (p *Provider) AuthCodeURL(flow *Flow, redirectURI string) string {
+	params := url.Values{}
+	params.Set("client_id", p.clientID)
+	params.Set("redirect_uri", redirectURI)
+	params.Set("response_type", "code")
+	params.Set("scope", constants.OIDCScope)
+	params.Set("state", flow.State)
+	params.Set("nonce", flow.Nonce)
+	params.Set("code_challenge", flow.CodeChallenge())
+	params.Set("code_challenge_method", "S256")
+	for key, values := range p.authParams {
+		params[key] = values
+	}
+
+	return p.authURL + "?" + params.Encode()
+}
+
+// Exchange exchanges an authorization code for an ID token and verifies it.
+//
+// Parameters:
+//   - ctx: Context for cancellation control
+//   - code: The authorization code
+//   - flow: The sign-in flow the code was issued for
+//   - redirectURI: The redirect URI the code was issued for
+//
+// Returns:
+//   - The identity of the user
+//   - ErrCodeRejected if the code is invalid or expired
+//   - ErrInvalidIDToken if the ID token fails verification
+func (p *Provider) Exchange(ctx context.Context, code string, flow *Flow, redirectURI string) (*Identity, error) {
+	rawIDToken, err := p.requestIDToken(ctx, code, flow.Verifier, redirectURI)
+	if err != nil {
+		return nil, err
+	}
+
+	return p.VerifyIDToken(ctx, rawIDToken, flow.Nonce)
+}
+
+// requestIDToken posts the authorization code to the token endpoint.
+func (p *Provider) requestIDToken(ctx context.Context, code, verifier, redirectURI string) (string, error) {
+	ctx, cancel := context.WithTimeout(ctx, constants.OAuthRequestTimeout)
+	defer cancel()
+
+	form := url.Values{
+		"grant_type":    {"authorization_code"},
+		"code":          {code},
+		"redirect_uri":  {redirectURI},
+		"code_verifier": {verifier},
+		"client_id":     {p.clientID},
+		"client_secret": {p.clientSecret},
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, p.tokenURL, strings.NewReader(form.Encode()))
+	if err != nil {
+		return "", fmt.Errorf("failed to create token request: %w", err)
+	}
+	req.Header.Set(constants.HeaderContentType, constants.ContentTypeForm)
+	req.Header.Set(constants.HeaderAccept, constants.ContentTypeJSON)
+
+	resp, err := p.client.Do(req)
+	if err != nil {
+		return "", fmt.Errorf("failed to request token: %w", err)
+	}
+	defer resp.Body.Close()
+
+	var body struct {
+		IDToken string `json:"id_token"`
+		Error   string `json:"error"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&body); err != nil {
+		return "", fmt.Errorf("failed to decode token response (status %d): %w", resp.StatusCode, err)
+	}
+
+	// An invalid grant is an expired or used code, or a verifier that does not match it
+	if body.Error == "invalid_grant" {
+		return "", ErrCodeRejected
+	}
+	if resp.StatusCode != http.StatusOK || body.IDToken == "" {
+		return "", fmt.Errorf("token request failed with status %d: %s", resp.StatusCode, body.Error)
+	}
+
+	return body.IDToken, nil
+}
+
+// VerifyIDToken verifies the signature and claims of an ID token.
+//
+// Parameters:
+//   - ctx: Context for cancellation control of fetching the signing keys
+//   - rawIDToken: The ID token
+//   - nonce: The nonce the token must contain
+//
+// Returns:
+//   - The identity of the user
+//   - ErrInvalidIDToken if the token fails verification
+//   - Other errors if the signing keys could not be fetched
+func (p *Provider) VerifyIDToken(ctx context.Context, rawIDToken, nonce string) (*Identity, error) {
+	claims := &idTokenClaims{}
+	_, err := jwt.ParseWithClaims(rawIDToken, claims, p.keys.keyFunc(ctx), jwt.WithValidMethods([]string{"RS256"}))
+	if err != nil {
+		// Outages fetching the keys are not the token's fault
+		var fetchErr *keyFetchError
+		if errors.As(err, &fetchErr) {
+			return nil, fetchErr
+		}
+		return nil, fmt.Errorf("%w: %v", ErrInvalidIDToken, err)
+	}
+
+	if !claims.VerifyAudience(p.clientID, true) {
+		return nil, fmt.Errorf("%w: issued to another client", ErrInvalidIDToken)
+	}
+	if !claims.VerifyExpiresAt(p.keys.now(), true) {
+		return nil, fmt.Errorf("%w: missing expiry", ErrInvalidIDToken)
+	}
+	if nonce == "" || claims.Nonce != nonce {
+		return nil, fmt.Errorf("%w: nonce does not match", ErrInvalidIDToken)
+	}
+	if claims.Subject == "" {
+		return nil, fmt.Errorf("%w: missing subject", ErrInvalidIDToken)
+	}
+
+	return p.identify(claims)
+}
@@ -0,0 +1,327 @@
+package oauth
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/rsa"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/golang-jwt/jwt/v4"
+
+	"github.com/yasinhessnawi1/Hideme_Backend/internal/constants"
+)
+
+const testTenant = "9b1c2d3e-0000-4000-8000-000000000001"
+
+// testIssuer serves the key set and token endpoint of a provider, signing ID tokens with its key
+type testIssuer struct {
+	t          *testing.T
+	key        *rsa.PrivateKey
+	server     *httptest.Server
+	keyFetches int
+
+	// claims are the claims of the next ID token; nil makes the token endpoint reject the code
+	claims   jwt.MapClaims
+	verifier string
+}
+
+func newTestIssuer(t *testing.T) *testIssuer {
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatalf("Failed to generate key: %v", err)
+	}
+
+	issuer := &testIssuer{t: t, key: key}
+	issuer.server = httptest.NewServer(http.HandlerFunc(issuer.serve))
+	t.Cleanup(issuer.server.Close)
+	return issuer
+}
+
+func (i *testIssuer) serve(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set(constants.HeaderContentType, constants.ContentTypeJSON)
+	switch r.URL.Path {
+	case "/keys":
+		i.keyFetches++
+		_ = json.NewEncoder(w).Encode(map[string]interface{}{"keys": []map[string]string{{
+			"kty": "RSA",
+			"kid": "k1",
+			"use": "sig",
+			"n":   base64.RawURLEncoding.EncodeToString(i.key.N.Bytes()),
+			"e":   base64.RawURLEncoding.EncodeToString(big.NewInt(int64(i.key.E)).Bytes()),
+		}}})
+	case "/token":
+		if err := r.ParseForm(); err != nil {
+			i.t.Fatalf("Invalid form: %v", err)
+		}
+		i.verifier = r.PostForm.Get("code_verifier")
+		if i.claims == nil {
+			w.WriteHeader(http.StatusBadRequest)
+			_, _ = w.Write([]byte(`{"error":"invalid_grant"}`))
+			return
+		}
+		_ = json.NewEncoder(w).Encode(map[string]string{"id_token": i.sign(i.claims, "k1", i.key)})
+	default:
+		http.NotFound(w, r)
+	}
+}
+
+// sign signs claims with RS256
+func (i *testIssuer) sign(claims jwt.MapClaims, kid string, key *rsa.PrivateKey) string {
+	token := jwt.NewWithClaims(jwt.SigningMethodRS256, claims)
+	token.Header["kid"] = kid
+	signed, err := token.SignedString(key)
+	if err != nil {
+		i.t.Fatalf("Failed to sign token: %v", err)
+	}
+	return signed
+}
+
+// provider points a provider at the test issuer
+func (i *testIssuer) provider(p *Provider) *Provider {
+	p.tokenURL = i.server.URL + "/token"
+	p.keys = newKeySet(i.server.URL+"/keys", i.server.Client())
+	p.client = i.server.Client()
+	return p
+}
+
+// googleClaims returns valid claims of a Google ID token for a flow
+func googleClaims(flow *Flow) jwt.MapClaims {
+	return jwt.MapClaims{
+		"iss":            constants.GoogleIssuer,
+		"aud":            "client-id",
+		"sub":            "google-subject",
+		"email":          "ola@example.com",
+		"email_verified": true,
+		"name":           "Ola Nordmann",
+		"nonce":          flow.Nonce,
+		"iat":            time.Now().Unix(),
+		"exp":            time.Now().Add(time.Hour).Unix(),
+	}
+}
+
+func TestProvider_AuthCodeURL(t *testing.T) {
+	flow, err := NewFlow(constants.OAuthProviderGoogle, time.Now().Add(time.Minute))
+	if err != nil {
+		t.Fatalf("NewFlow returned error: %v", err)
+	}
+
+	authURL, err := url.Parse(NewGoogleProvider("client-id", "secret", nil).AuthCodeURL(flow, "https://api.test/callback"))
+	if err != nil {
+		t.Fatalf("Invalid URL: %v", err)
+	}
+
+	query := authURL.Query()
+	if query.Get("state") != flow.State || query.Get("nonce") != flow.Nonce {
+		t.Errorf("Expected the state and nonce of the flow, got %s", authURL)
+	}
+	if query.Get("code_challenge") != flow.CodeChallenge() || query.Get("code_challenge_method") != "S256" {
+		t.Errorf("Expected an S256 code challenge, got %s", authURL)
+	}
+	if query.Get("scope") != constants.OIDCScope || query.Get("redirect_uri") != "https://api.test/callback" {
+		t.Errorf("Unexpected scope or redirect URI: %s", authURL)
+	}
+}
+
+func TestProvider_Exchange(t *testing.T) {
+	issuer := newTestIssuer(t)
+	provider := issuer.provider(NewGoogleProvider("client-id", "secret", nil))
+	flow, _ := NewFlow(constants.OAuthProviderGoogle, time.Now().Add(time.Minute))
+
+	issuer.claims = googleClaims(flow)
+	identity, err := provider.Exchange(context.Background(), "code", flow, "https://api.test/callback")
+	if err != nil {
+		t.Fatalf("Exchange returned error: %v", err)
+	}
+
+	if identity.Subject != "google-subject" || identity.Email != "ola@example.com" || !identity.EmailVerified {
+		t.Errorf("Unexpected identity: %+v", identity)
+	}
+	if issuer.verifier != flow.Verifier {
+		t.Error("Expected the code verifier to be sent with the code")
+	}
+
+	t.Run("Code rejected", func(t *testing.T) {
+		issuer.claims = nil
+		if _, err := provider.Exchange(context.Background(), "used", flow, "https://api.test/callback"); !errors.Is(err, ErrCodeRejected) {
+			t.Errorf("Expected ErrCodeRejected, got %v", err)
+		}
+	})
+}
+
+func TestProvider_VerifyIDToken(t *testing.T) {
+	issuer := newTestIssuer(t)
+	provider := issuer.provider(NewGoogleProvider("client-id", "secret", nil))
+	flow, _ := NewFlow(constants.OAuthProviderGoogle, time.Now().Add(time.Minute))
+	otherKey, _ := rsa.GenerateKey(rand.Reader, 2048)
+
+	// with returns valid claims with one claim changed, or removed if value is nil
+	with := func(name string, value interface{}) jwt.MapClaims {
+		claims := googleClaims(flow)
+		if value == nil {
+			delete(claims, name)
+		} else {
+			claims[name] = value
+		}
+		return claims
+	}
+
+	hmacToken := jwt.NewWithClaims(jwt.SigningMethodHS256, googleClaims(flow))
+	hmacToken.Header["kid"] = "k1"
+	hmacSigned, _ := hmacToken.SignedString([]byte("secret"))
+
+	tests := []struct {
+		name  string
+		token string
+	}{
+		{"Other nonce", issuer.sign(with("nonce", "other"), "k1", issuer.key)},
+		{"Other audience", issuer.sign(with("aud", "other-client"), "k1", issuer.key)},
+		{"Other issuer", issuer.sign(with("iss", "https://issuer.test"), "k1", issuer.key)},
+		{"Expired", issuer.sign(with("exp", time.Now().Add(-time.Minute).Unix()), "k1", issuer.key)},
+		{"No expiry", issuer.sign(with("exp", nil), "k1", issuer.key)},
+		{"No subject", issuer.sign(with("sub", nil), "k1", issuer.key)},
+		{"Other key", issuer.sign(googleClaims(flow), "k1", otherKey)},
+		{"Unknown key", issuer.sign(googleClaims(flow), "k2", issuer.key)},
+		{"HMAC signed", hmacSigned},
+		{"Malformed", "not.a.token"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := provider.VerifyIDToken(context.Background(), tt.token, flow.Nonce); !errors.Is(err, ErrInvalidIDToken) {
+				t.Errorf("Expected ErrInvalidIDToken, got %v", err)
+			}
+		})
+	}
+
+	// Tokens naming unknown keys fetch the keys again only once per minimum refresh interval
+	if issuer.keyFetches != 1 {
+		t.Errorf("Expected the keys to be fetched once, got %d fetches", issuer.keyFetches)
+	}
+
+	t.Run("String email_verified", func(t *testing.T) {
+		identity, err := provider.VerifyIDToken(context.Background(), issuer.sign(with("email_verified", "true"), "k1", issuer.key), flow.Nonce)
+		if err != nil || !identity.EmailVerified {
+			t.Errorf("Expected a verified email, got %+v, %v", identity, err)
+		}
+	})
+
+	t.Run("Key set unavailable", func(t *testing.T) {
+		unavailable := NewGoogleProvider("client-id", "secret", nil)
+		unavailable.keys = newKeySet(issuer.server.URL+"/missing", issuer.server.Client())
+		_, err := unavailable.VerifyIDToken(context.Background(), issuer.sign(googleClaims(flow), "k1", issuer.key), flow.Nonce)
+		if err == nil || errors.Is(err, ErrInvalidIDToken) {
+			t.Errorf("Expected an outage rather than an invalid token, got %v", err)
+		}
+	})
+}
+
+func TestMicrosoftProvider_Identity(t *testing.T) {
+	issuer := newTestIssuer(t)
+	flow, _ := NewFlow(constants.OAuthProviderMicrosoft, time.Now().Add(time.Minute))
+
+	claims := func(tenant string, extra jwt.MapClaims) jwt.MapClaims {
+		c := jwt.MapClaims{
+			"iss":                "https://login.microsoftonline.com/" + tenant + "/v2.0",
+			"tid":                tenant,
+			"aud":                "client-id",
+			"sub":                "microsoft-subject",
+			"preferred_username": "ola@contoso.test",
+			"nonce":              flow.Nonce,
+			"exp":                time.Now().Add(time.Hour).Unix(),
+		}
+		for k, v := range extra {
+			c[k] = v
+		}
+		return c
+	}
+
+	t.Run("Multi-tenant email is not trusted", func(t *testing.T) {
+		provider := issuer.provider(NewMicrosoftProvider("client-id", "secret", "common", nil))
+		identity, err := provider.VerifyIDToken(context.Background(), issuer.sign(claims(testTenant, nil), "k1", issuer.key), flow.Nonce)
+		if err != nil {
+			t.Fatalf("VerifyIDToken returned error: %v", err)
+		}
+		if identity.Email != "ola@contoso.test" || identity.EmailVerified {
+			t.Errorf("Expected an unverified email, got %+v", identity)
+		}
+	})
+
+	t.Run("Domain owner verified", func(t *testing.T) {
+		provider := issuer.provider(NewMicrosoftProvider("client-id", "secret", "organizations", nil))
+		token := issuer.sign(claims(testTenant, jwt.MapClaims{"email": "ola@contoso.test", "xms_edov": true}), "k1", issuer.key)
+		identity, err := provider.VerifyIDToken(context.Background(), token, flow.Nonce)
+		if err != nil || !identity.EmailVerified {
+			t.Errorf("Expected a verified email, got %+v, %v", identity, err)
+		}
+	})
+
+	t.Run("Single tenant", func(t *testing.T) {
+		provider := issuer.provider(NewMicrosoftProvider("client-id", "secret", testTenant, nil))
+		identity, err := provider.VerifyIDToken(context.Background(), issuer.sign(claims(testTenant, nil), "k1", issuer.key), flow.Nonce)
+		if err != nil || !identity.EmailVerified {
+			t.Errorf("Expected the tenant's email to be trusted, got %+v, %v", identity, err)
+		}
+
+		other := issuer.sign(claims("00000000-0000-0000-0000-000000000002", nil), "k1", issuer.key)
+		if _, err := provider.VerifyIDToken(context.Background(), other, flow.Nonce); !errors.Is(err, ErrInvalidIDToken) {
+			t.Errorf("Expected another tenant to be rejected, got %v", err)
+		}
+	})
+
+	t.Run("Issuer of another tenant", func(t *testing.T) {
+		provider := issuer.provider(NewMicrosoftProvider("client-id", "secret", "common", nil))
+		forged := claims(testTenant, jwt.MapClaims{"iss": "https://login.microsoftonline.com/00000000-0000-0000-0000-000000000002/v2.0"})
+		if _, err := provider.VerifyIDToken(context.Background(), issuer.sign(forged, "k1", issuer.key), flow.Nonce); !errors.Is(err, ErrInvalidIDToken) {
+			t.Errorf("Expected ErrInvalidIDToken, got %v", err)
+		}
+	})
+}
+
+func TestFlow_EncodeDecode(t *testing.T) {
+	key := []byte("flow-key")
+	now := time.Now()
+	flow, err := NewFlow(constants.OAuthProviderGoogle, now.Add(time.Minute))
+	if err != nil {
+		t.Fatalf("NewFlow returned error: %v", err)
+	}
+	encoded := flow.Encode(key)
+
+	decoded, err := DecodeFlow(key, encoded, now)
+	if err != nil {
+		t.Fatalf("DecodeFlow returned error: %v", err)
+	}
+	if decoded.State != flow.State || decoded.Nonce != flow.Nonce || decoded.Verifier != flow.Verifier {
+		t.Errorf("Expected the flow back, got %+v", decoded)
+	}
+
+	payload, signature, _ := strings.Cut(encoded, ".")
+	tampered, _ := json.Marshal(&Flow{Provider: constants.OAuthProviderMicrosoft, State: flow.State, ExpiresAt: flow.ExpiresAt})
+
+	tests := []struct {
+		name  string
+		key   []byte
+		value string
+		now   time.Time
+	}{
+		{"Expired", key, encoded, now.Add(time.Hour)},
+		{"Other key", []byte("other-key"), encoded, now},
+		{"Tampered", key, base64.RawURLEncoding.EncodeToString(tampered) + "." + signature, now},
+		{"Unsigned", key, payload, now},
+		{"Empty", key, "", now},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := DecodeFlow(tt.key, tt.value, tt.now); !errors.Is(err, ErrInvalidFlow) {
+				t.Errorf("Expected ErrInvalidFlow, got %v", err)
+			}
+		})
+	}
+}
@@ -0,0 +1,103 @@
+package config
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/yasinhessnawi1/Hideme_Backend/internal/constants"
+)
+
+// CheckResult is the outcome of checking a configuration for administrators.
+// Errors would stop the server from starting; warnings are settings that work but are
+// unsafe or unusual for the environment.
+type CheckResult struct {
+	// Valid is true if there are no errors
+	Valid bool `json:"valid"`
+
+	// Errors lists the validation errors
+	Errors []string `json:"errors"`
+
+	// Warnings lists the settings that should be reviewed
+	Warnings []string `json:"warnings"`
+
+	// Settings summarizes the effective configuration with secrets redacted
+	Settings map[string]interface{} `json:"settings"`
+}
+
+// Check validates a configuration and looks for unsafe settings, without changing it.
+//
+// Parameters:
+//   - config: The configuration to check
+//
+// Returns:
+//   - The check result, including a summary of the settings with secrets redacted
+func Check(config *AppConfig) *CheckResult {
+	result := &CheckResult{
+		Errors:   []string{},
+		Warnings: []string{},
+	}
+
+	// validateConfig normalizes the environment, so it checks a copy
+	checked := *config
+	if err := validateConfig(&checked); err != nil {
+		result.Errors = append(result.Errors, err.Error())
+	}
+
+	if config.JWT.Secret == "" {
+		result.Warnings = append(result.Warnings, "JWT secret is not set")
+	} else if len(config.JWT.Secret) < constants.MinJWTSecretLength {
+		result.Warnings = append(result.Warnings, fmt.Sprintf("JWT secret is shorter than %d bytes", constants.MinJWTSecretLength))
+	}
+	if config.JWT.IdleTimeout > 0 && config.JWT.IdleTimeout < config.JWT.Expiry {
+		result.Warnings = append(result.Warnings, "Session idle timeout is shorter than the access token lifetime, so active users are signed out between refreshes")
+	}
+	if config.APIKey.EncryptionKey == "" {
+		result.Warnings = append(result.Warnings, "API key encryption key is not set")
+	}
+	if (config.Drives.GoogleClientID != "" || config.Drives.MicrosoftClientID != "") && config.Drives.RedirectURL == "" {
+		result.Warnings = append(result.Warnings, "Cloud drive clients are configured without a redirect URL")
+	}
+	if (config.OAuth.GoogleClientID != "" || config.OAuth.MicrosoftClientID != "") && config.OAuth.CallbackBaseURL == "" {
+		result.Warnings = append(result.Warnings, "Sign-in providers are configured without a callback base URL")
+	}
+
+	if checked.App.IsProduction() {
+		for _, origin := range config.CORS.AllowedOrigins {
+			if origin == "*" {
+				result.Warnings = append(result.Warnings, "CORS allows all origins in production")
+				break
+			}
+		}
+		if strings.EqualFold(config.Logging.Level, "debug") {
+			result.Warnings = append(result.Warnings, "Debug logging is enabled in production")
+		}
+	}
+
+	result.Valid = len(result.Errors) == 0
+	result.Settings = map[string]interface{}{
+		"environment":      checked.App.Environment,
+		"version":          config.App.Version,
+		"server":           config.Server.ServerAddress(),
+		"db_host":          config.Database.Host,
+		"db_port":          config.Database.Port,
+		"db_name":          config.Database.Name,
+		"db_password":      redact(config.Database.Password),
+		"jwt_secret":       redact(config.JWT.Secret),
+		"jwt_expiry":       config.JWT.Expiry.String(),
+		"jwt_idle_timeout": config.JWT.IdleTimeout.String(),
+		"api_key_expiry":   config.APIKey.DefaultExpiry.String(),
+		"log_level":        config.Logging.Level,
+		"allowed_origins":  config.CORS.AllowedOrigins,
+		"pii_screening":    config.PIIScreening.Mode,
+	}
+
+	return result
+}
+
+// redact masks a secret, keeping only whether it is set.
+func redact(secret string) string {
+	if secret == "" {
+		return ""
+	}
+	return constants.LogRedactedValue
+}
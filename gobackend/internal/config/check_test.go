@@ -0,0 +1,99 @@
+package config
+
+import (
+	"testing"
+)
+
+func TestCheck(t *testing.T) {
+	t.Run("Safe production config", func(t *testing.T) {
+		cfg := &AppConfig{
+			App:      AppSettings{Environment: "production"},
+			Database: DatabaseSettings{User: "hideme", Password: "db-password"},
+			JWT:      JWTSettings{Secret: "0123456789abcdef0123456789abcdef"},
+			APIKey:   APIKeySettings{EncryptionKey: "encryption-key"},
+			Logging:  LoggingSettings{Level: "info"},
+			CORS:     CORSSettings{AllowedOrigins: []string{"https://hideme.example.com"}},
+		}
+
+		result := Check(cfg)
+
+		if !result.Valid || len(result.Errors) != 0 {
+			t.Errorf("Expected valid config, got errors %v", result.Errors)
+		}
+		if len(result.Warnings) != 0 {
+			t.Errorf("Expected no warnings, got %v", result.Warnings)
+		}
+		if result.Settings["jwt_secret"] != "[REDACTED]" || result.Settings["db_password"] != "[REDACTED]" {
+			t.Errorf("Expected secrets to be redacted, got %v", result.Settings)
+		}
+	})
+
+	t.Run("Unsafe production config", func(t *testing.T) {
+		cfg := &AppConfig{
+			App:      AppSettings{Environment: "production"},
+			Database: DatabaseSettings{User: "hideme"},
+			JWT:      JWTSettings{Secret: "short"},
+			Logging:  LoggingSettings{Level: "debug"},
+			CORS:     CORSSettings{AllowedOrigins: []string{"*"}},
+		}
+
+		result := Check(cfg)
+
+		if !result.Valid {
+			t.Errorf("Expected valid config, got errors %v", result.Errors)
+		}
+		if len(result.Warnings) != 4 {
+			t.Errorf("Expected 4 warnings, got %v", result.Warnings)
+		}
+	})
+
+	t.Run("Cloud drive without redirect URL", func(t *testing.T) {
+		cfg := &AppConfig{
+			App:      AppSettings{Environment: "development"},
+			Database: DatabaseSettings{User: "hideme"},
+			JWT:      JWTSettings{Secret: "0123456789abcdef0123456789abcdef"},
+			APIKey:   APIKeySettings{EncryptionKey: "encryption-key"},
+			Logging:  LoggingSettings{Level: "info"},
+			Drives:   DriveSettings{GoogleClientID: "client-id", GoogleClientSecret: "client-secret"},
+		}
+
+		result := Check(cfg)
+
+		if len(result.Warnings) != 1 {
+			t.Errorf("Expected 1 warning, got %v", result.Warnings)
+		}
+	})
+
+	t.Run("Sign-in provider without callback URL", func(t *testing.T) {
+		cfg := &AppConfig{
+			App:      AppSettings{Environment: "development"},
+			Database: DatabaseSettings{User: "hideme"},
+			JWT:      JWTSettings{Secret: "0123456789abcdef0123456789abcdef"},
+			APIKey:   APIKeySettings{EncryptionKey: "encryption-key"},
+			Logging:  LoggingSettings{Level: "info"},
+			OAuth:    OAuthSettings{MicrosoftClientID: "client-id", MicrosoftClientSecret: "client-secret"},
+		}
+
+		result := Check(cfg)
+
+		if len(result.Warnings) != 1 {
+			t.Errorf("Expected 1 warning, got %v", result.Warnings)
+		}
+	})
+
+	t.Run("Invalid config is not changed", func(t *testing.T) {
+		cfg := &AppConfig{
+			App:     AppSettings{Environment: "staging"},
+			Logging: LoggingSettings{Level: "info"},
+		}
+
+		result := Check(cfg)
+
+		if result.Valid || len(result.Errors) != 1 {
+			t.Errorf("Expected one error, got %v", result.Errors)
+		}
+		if cfg.App.Environment != "staging" {
+			t.Errorf("Expected environment to be unchanged, got %s", cfg.App.Environment)
+		}
+	})
+}
@@ -13,6 +13,8 @@ package config
 
 import (
 	"fmt"
+	"net"
+	"net/url"
 	"os"
 	"strings"
 	"time"
@@ -57,6 +59,72 @@ type AppConfig struct {
 
 	// Security contains settings for rate limiting and IP banning
 	Security SecuritySettings `yaml:"security"`
+
+	// Approvals contains the two-person approval policy for destructive admin actions
+	Approvals ApprovalSettings `yaml:"approvals"`
+
+	// Risk contains the risk scoring policy applied to registrations and logins
+	Risk RiskSettings `yaml:"risk"`
+
+	// Quota contains the detection quota shared with the detection service
+	Quota QuotaSettings `yaml:"quota"`
+
+	// UploadLimits contains the limits checked before a document upload is accepted
+	UploadLimits UploadLimitSettings `yaml:"upload_limits"`
+
+	// DocumentArchive contains when documents are moved to cold storage
+	DocumentArchive DocumentArchiveSettings `yaml:"document_archive"`
+
+	// StaleDocuments contains when unfinished documents are cleaned up
+	StaleDocuments StaleDocumentSettings `yaml:"stale_documents"`
+
+	// LeakAlerts contains when users are alerted about values leaking in many documents
+	LeakAlerts LeakAlertSettings `yaml:"leak_alerts"`
+
+	// DocumentStorage contains where the PDF content of uploaded documents is stored
+	DocumentStorage DocumentStorageSettings `yaml:"document_storage"`
+
+	// Startup contains how long startup waits for the database and cache to become reachable
+	Startup StartupSettings `yaml:"startup"`
+
+	// LoadShedding contains the thresholds for shedding requests while the database is slow
+	LoadShedding LoadSheddingSettings `yaml:"load_shedding"`
+
+	// ShadowTraffic contains the mirroring of read-only requests to a shadow deployment
+	ShadowTraffic ShadowTrafficSettings `yaml:"shadow_traffic"`
+
+	// StatusPage contains the component checks behind the public status page
+	StatusPage StatusPageSettings `yaml:"status_page"`
+
+	// SLO contains the service-level objectives administrators are alerted about
+	SLO SLOSettings `yaml:"slo"`
+
+	// SettingsCache contains the caching of the composed detection configuration
+	SettingsCache SettingsCacheSettings `yaml:"settings_cache"`
+
+	// PIIScreening contains the screening of free-text fields for personal data
+	PIIScreening PIIScreeningSettings `yaml:"pii_screening"`
+
+	// Benchmarks contains the differential privacy of the cross-tenant benchmarks
+	Benchmarks BenchmarkSettings `yaml:"benchmarks"`
+
+	// SecurityHeaders contains the security headers added to every response
+	SecurityHeaders SecurityHeaderSettings `yaml:"security_headers"`
+
+	// IDGeneration contains the generation of IDs for new resources
+	IDGeneration IDGenerationSettings `yaml:"id_generation"`
+
+	// Drives contains the OAuth clients of the cloud drive connectors
+	Drives DriveSettings `yaml:"drives"`
+
+	// Exports contains the delivery of redacted documents to export destinations
+	Exports ExportSettings `yaml:"exports"`
+
+	// Webhooks contains the delivery of account and document events to users' webhooks
+	Webhooks WebhookSettings `yaml:"webhooks"`
+
+	// OAuth contains the OpenID Connect providers users can sign in with
+	OAuth OAuthSettings `yaml:"oauth"`
 }
 
 // GDPRLoggingSettings contains GDPR-compliant logging configuration.
@@ -123,6 +191,15 @@ type DatabaseSettings struct {
 
 	// MinConns is the minimum number of idle connections in the connection pool
 	MinConns int `yaml:"min_conns" env:"DB_MIN_CONNS"`
+
+	// QueryMetrics enables per-query statistics and slow query reporting; it can be toggled at runtime
+	QueryMetrics bool `yaml:"query_metrics" env:"DB_QUERY_METRICS"`
+
+	// ExplainSlowQueries captures the plan of slow queries; it can be toggled at runtime
+	ExplainSlowQueries bool `yaml:"explain_slow_queries" env:"DB_EXPLAIN_SLOW_QUERIES"`
+
+	// SlowQueryThreshold is the duration from which a query is reported as slow
+	SlowQueryThreshold time.Duration `yaml:"slow_query_threshold" env:"DB_SLOW_QUERY_THRESHOLD"`
 }
 
 // ServerSettings contains HTTP server settings.
@@ -137,11 +214,39 @@ type ServerSettings struct {
 	// ReadTimeout is the maximum duration for reading the entire request
 	ReadTimeout time.Duration `yaml:"read_timeout" env:"SERVER_READ_TIMEOUT"`
 
-	// WriteTimeout is the maximum duration for writing the response
+	// WriteTimeout is the maximum duration for writing the response. Long-polling and
+	// streaming endpoints extend it for their own responses.
 	WriteTimeout time.Duration `yaml:"write_timeout" env:"SERVER_WRITE_TIMEOUT"`
 
+	// ReadHeaderTimeout is the maximum duration for reading the request headers
+	ReadHeaderTimeout time.Duration `yaml:"read_header_timeout" env:"SERVER_READ_HEADER_TIMEOUT"`
+
+	// IdleTimeout is how long a keep-alive connection may wait for its next request
+	IdleTimeout time.Duration `yaml:"idle_timeout" env:"SERVER_IDLE_TIMEOUT"`
+
+	// MaxHeaderBytes is the maximum size of the request headers (default: 1 MiB)
+	MaxHeaderBytes int `yaml:"max_header_bytes" env:"SERVER_MAX_HEADER_BYTES"`
+
+	// KeepAlivesDisabled closes each connection after one request
+	KeepAlivesDisabled bool `yaml:"keep_alives_disabled" env:"SERVER_KEEP_ALIVES_DISABLED"`
+
+	// TCPKeepAlive is the interval of TCP keep-alive probes; negative disables them (default: 15s)
+	TCPKeepAlive time.Duration `yaml:"tcp_keep_alive" env:"SERVER_TCP_KEEP_ALIVE"`
+
+	// HTTP2Enabled serves HTTP/2 over cleartext (h2c) next to HTTP/1.1, for load balancers
+	// that speak HTTP/2 to the backend; TLS is terminated in front of the server
+	HTTP2Enabled bool `yaml:"http2_enabled" env:"SERVER_HTTP2_ENABLED"`
+
+	// HTTP2MaxConcurrentStreams is the number of concurrent requests per HTTP/2 connection (default: 250)
+	HTTP2MaxConcurrentStreams int `yaml:"http2_max_concurrent_streams" env:"SERVER_HTTP2_MAX_CONCURRENT_STREAMS"`
+
 	// ShutdownTimeout is the maximum duration to wait for active connections to close during shutdown
 	ShutdownTimeout time.Duration `yaml:"shutdown_timeout" env:"SERVER_SHUTDOWN_TIMEOUT"`
+
+	// TrustedProxies lists the IP addresses and CIDR ranges of the reverse proxies in front of
+	// the server. Only hops these proxies added to X-Forwarded-For are believed; without any,
+	// the client address is the address of the connection (default: none)
+	TrustedProxies []string `yaml:"trusted_proxies" env:"SERVER_TRUSTED_PROXIES"`
 }
 
 // JWTSettings contains JWT authentication settings.
@@ -156,6 +261,10 @@ type JWTSettings struct {
 	// RefreshExpiry is the lifetime of refresh tokens
 	RefreshExpiry time.Duration `yaml:"refresh_expiry" env:"JWT_REFRESH_EXPIRY"`
 
+	// IdleTimeout is how long a session may go without refreshing its tokens before it is
+	// invalidated; each refresh slides the window forward (default: 0, no idle timeout)
+	IdleTimeout time.Duration `yaml:"idle_timeout" env:"JWT_IDLE_TIMEOUT"`
+
 	// Issuer is the JWT issuer claim value
 	Issuer string `yaml:"issuer" env:"JWT_ISSUER"`
 }
@@ -167,6 +276,18 @@ type APIKeySettings struct {
 
 	// EncryptionKey is the key used for API key encryption
 	EncryptionKey string `yaml:"encryption_key" env:"API_KEY_ENCRYPTION_KEY"`
+
+	// RotationOverlap is how long a rotated key stays valid next to its replacement
+	RotationOverlap time.Duration `yaml:"rotation_overlap" env:"API_KEY_ROTATION_OVERLAP"`
+
+	// CacheTTL is how long a successful key verification is reused (default: 30s)
+	CacheTTL time.Duration `yaml:"cache_ttl" env:"API_KEY_CACHE_TTL"`
+
+	// FailureBackoff is how long a client waits after an invalid key, doubling per failure (default: 1s)
+	FailureBackoff time.Duration `yaml:"failure_backoff" env:"API_KEY_FAILURE_BACKOFF"`
+
+	// MaxFailureBackoff caps the wait after repeated invalid keys (default: 5m)
+	MaxFailureBackoff time.Duration `yaml:"max_failure_backoff" env:"API_KEY_MAX_FAILURE_BACKOFF"`
 }
 
 // LoggingSettings contains logging configuration.
@@ -220,6 +341,385 @@ type SecuritySettings struct {
 	IPBanning IPBanSettings `yaml:"ip_banning" env:"IP_BANNING"`
 }
 
+// ApprovalSettings configures the two-person approval policy for destructive admin actions.
+// Actions listed in RequiredActions are queued until a second administrator approves them;
+// all other actions run as soon as they are requested.
+type ApprovalSettings struct {
+	// RequiredActions lists the action types that need a second administrator's approval
+	RequiredActions []string `yaml:"required_actions" env:"APPROVAL_REQUIRED_ACTIONS"`
+
+	// Expiry is how long a pending action waits for approval before it expires
+	Expiry time.Duration `yaml:"expiry" env:"APPROVAL_EXPIRY"`
+}
+
+// RiskSettings configures the risk scoring applied to registrations and logins.
+// Scores from all scorers are added up and compared against the thresholds; the
+// highest threshold reached decides which challenge the client must pass.
+type RiskSettings struct {
+	// CaptchaThreshold is the score from which a CAPTCHA is required
+	CaptchaThreshold int `yaml:"captcha_threshold" env:"RISK_CAPTCHA_THRESHOLD"`
+
+	// EmailVerificationThreshold is the score from which the email address must be verified
+	EmailVerificationThreshold int `yaml:"email_verification_threshold" env:"RISK_EMAIL_VERIFICATION_THRESHOLD"`
+
+	// ManualReviewThreshold is the score from which an administrator must review the account
+	ManualReviewThreshold int `yaml:"manual_review_threshold" env:"RISK_MANUAL_REVIEW_THRESHOLD"`
+
+	// BlockedIPs lists IP addresses and CIDR ranges with a bad reputation
+	BlockedIPs []string `yaml:"blocked_ips" env:"RISK_BLOCKED_IPS"`
+
+	// DisposableEmailDomains lists additional disposable email domains
+	DisposableEmailDomains []string `yaml:"disposable_email_domains" env:"RISK_DISPOSABLE_EMAIL_DOMAINS"`
+}
+
+// QuotaSettings configures the detection quota token bucket.
+// When RedisURL is set the buckets live in Redis and are shared with the detection
+// service; otherwise they are kept in memory and only this instance sees them.
+type QuotaSettings struct {
+	// RedisURL is the Redis server holding the shared buckets (e.g. redis://localhost:6379/0)
+	RedisURL string `yaml:"redis_url" env:"REDIS_URL"`
+
+	// KeyPrefix is the prefix of the Redis keys holding the buckets
+	KeyPrefix string `yaml:"key_prefix" env:"DETECTION_QUOTA_KEY_PREFIX"`
+
+	// Capacity is the number of tokens (pages) in a full bucket
+	Capacity int64 `yaml:"capacity" env:"DETECTION_QUOTA_CAPACITY"`
+
+	// RefillInterval is the time an empty bucket needs to refill completely
+	RefillInterval time.Duration `yaml:"refill_interval" env:"DETECTION_QUOTA_REFILL_INTERVAL"`
+
+	// WarningThresholds are the percentages of the quota used at which the user is warned (e.g. 80,95)
+	WarningThresholds []int `yaml:"warning_thresholds" env:"DETECTION_QUOTA_WARNING_THRESHOLDS"`
+}
+
+// UploadLimitSettings configures the limits checked before a document upload is accepted,
+// so uploads over a limit are refused before their body is read and processed.
+// The same limits apply to every user; a limit of zero or less is not enforced.
+type UploadLimitSettings struct {
+	// MaxDocuments is the number of documents a user may store
+	MaxDocuments int64 `yaml:"max_documents" env:"UPLOAD_MAX_DOCUMENTS"`
+
+	// MaxPages is the number of pages of one uploaded document
+	MaxPages int64 `yaml:"max_pages" env:"UPLOAD_MAX_PAGES"`
+
+	// MaxBytes is the size of one upload request in bytes
+	MaxBytes int64 `yaml:"max_bytes" env:"UPLOAD_MAX_BYTES"`
+}
+
+// DocumentArchiveSettings configures the archiving of old documents. The redaction schema
+// and detected entities of a document that has not changed for AfterDays are moved to cold
+// storage, which is kept out of the tables that are read and written all the time, and are
+// only readable again after the document is restored. A value of zero or less turns
+// archiving off.
+type DocumentArchiveSettings struct {
+	// AfterDays is the number of days without a change after which a document is archived
+	AfterDays int `yaml:"after_days" env:"DOCUMENT_ARCHIVE_AFTER_DAYS"`
+}
+
+// StaleDocumentSettings configures the cleanup of documents left unfinished: uploaded but
+// never processed, or with detections awaiting review. The owner of a document untouched for
+// AfterDays is emailed, and the document is archived or deleted NoticeDays later unless it
+// is worked on or exempted in the meantime. A negative AfterDays turns cleanup off.
+type StaleDocumentSettings struct {
+	// AfterDays is the number of days an unfinished document may stay untouched before its owner is told
+	AfterDays int `yaml:"after_days" env:"STALE_DOCUMENT_AFTER_DAYS"`
+
+	// NoticeDays is the number of days between telling the owner and cleaning up the document
+	NoticeDays int `yaml:"notice_days" env:"STALE_DOCUMENT_NOTICE_DAYS"`
+
+	// Action is what is done with stale documents after the notice, "archive" or "delete"
+	Action string `yaml:"action" env:"STALE_DOCUMENT_ACTION"`
+}
+
+// LeakAlertSettings configures the alerts about systemic leaks. A user is alerted when the
+// same detected value, such as one person's national ID number, is detected in more than
+// Threshold of their documents within WindowDays. A negative threshold turns alerting off.
+type LeakAlertSettings struct {
+	// Threshold is the number of documents a value may be detected in without an alert
+	Threshold int `yaml:"threshold" env:"LEAK_ALERT_THRESHOLD"`
+
+	// WindowDays is the number of days detections are counted over
+	WindowDays int `yaml:"window_days" env:"LEAK_ALERT_WINDOW_DAYS"`
+}
+
+// DocumentStorageSettings configures where the PDF content of uploaded documents is stored.
+// The content is encrypted with the data-encryption key of its owner before it is written,
+// so neither backend sees it in the clear.
+type DocumentStorageSettings struct {
+	// Backend is where the content is stored: local (default) or s3
+	Backend string `yaml:"backend" env:"DOCUMENT_STORAGE_BACKEND"`
+
+	// LocalPath is the directory of the local backend (default: data/documents)
+	LocalPath string `yaml:"local_path" env:"DOCUMENT_STORAGE_PATH"`
+
+	// S3Bucket is the bucket of the s3 backend
+	S3Bucket string `yaml:"s3_bucket" env:"DOCUMENT_STORAGE_S3_BUCKET"`
+
+	// S3Region is the AWS region of the bucket
+	S3Region string `yaml:"s3_region" env:"DOCUMENT_STORAGE_S3_REGION"`
+
+	// S3Endpoint is the HTTPS URL of an S3-compatible store; empty for Amazon S3
+	S3Endpoint string `yaml:"s3_endpoint" env:"DOCUMENT_STORAGE_S3_ENDPOINT"`
+
+	// S3Prefix is prepended to the keys of the stored objects
+	S3Prefix string `yaml:"s3_prefix" env:"DOCUMENT_STORAGE_S3_PREFIX"`
+
+	// S3AccessKeyID is the access key ID requests to the bucket are signed with
+	S3AccessKeyID string `yaml:"s3_access_key_id" env:"DOCUMENT_STORAGE_S3_ACCESS_KEY_ID"`
+
+	// S3SecretAccessKey is the secret access key requests to the bucket are signed with
+	S3SecretAccessKey string `yaml:"s3_secret_access_key" env:"DOCUMENT_STORAGE_S3_SECRET_ACCESS_KEY"`
+}
+
+// StartupSettings configures how startup waits for its dependencies. Connections to the
+// database and cache are retried with exponential backoff, from InitialBackoff up to
+// MaxBackoff between attempts, until MaxWait has passed; only then does startup fail.
+type StartupSettings struct {
+	// FailFast fails startup at the first failed connection, without retrying
+	FailFast bool `yaml:"fail_fast" env:"STARTUP_FAIL_FAST"`
+
+	// MaxWait is how long startup keeps retrying a dependency (default: 2m)
+	MaxWait time.Duration `yaml:"max_wait" env:"STARTUP_MAX_WAIT"`
+
+	// InitialBackoff is the wait after the first failed attempt (default: 500ms)
+	InitialBackoff time.Duration `yaml:"initial_backoff" env:"STARTUP_INITIAL_BACKOFF"`
+
+	// MaxBackoff is the longest wait between two attempts (default: 10s)
+	MaxBackoff time.Duration `yaml:"max_backoff" env:"STARTUP_MAX_BACKOFF"`
+}
+
+// LoadSheddingSettings configures shedding of requests while the database is slow or failing.
+// Each route group has a priority: low-priority groups are shed once the mean query latency
+// reaches DegradedLatency, normal-priority groups once it reaches OverloadedLatency or the
+// error rate reaches ErrorRate. Critical groups are never shed.
+type LoadSheddingSettings struct {
+	// Enabled turns load shedding on or off (default: on in production)
+	Enabled bool `yaml:"enabled" env:"LOAD_SHED_ENABLED"`
+
+	// DegradedLatency is the mean query latency from which low-priority requests are shed
+	DegradedLatency time.Duration `yaml:"degraded_latency" env:"LOAD_SHED_DEGRADED_LATENCY"`
+
+	// OverloadedLatency is the mean query latency from which normal-priority requests are shed too
+	OverloadedLatency time.Duration `yaml:"overloaded_latency" env:"LOAD_SHED_OVERLOADED_LATENCY"`
+
+	// ErrorRate is the share of failing queries (0-1) from which normal-priority requests are shed too
+	ErrorRate float64 `yaml:"error_rate" env:"LOAD_SHED_ERROR_RATE"`
+
+	// MinSamples is the number of recent queries required before anything is shed
+	MinSamples int64 `yaml:"min_samples" env:"LOAD_SHED_MIN_SAMPLES"`
+
+	// RoutePriorities overrides the priority of route groups as "group=priority" pairs
+	// (e.g. "analytics=low,documents=normal"); priorities are low, normal and critical
+	RoutePriorities []string `yaml:"route_priorities" env:"LOAD_SHED_ROUTE_PRIORITIES"`
+}
+
+// ShadowTrafficSettings configures mirroring of read-only requests to a shadow deployment,
+// such as a release candidate, to compare its response codes and latency with this server
+// before cutting over. Mirroring is off unless a target URL is set.
+type ShadowTrafficSettings struct {
+	// TargetURL is the base URL of the shadow deployment (e.g. http://hideme-rc:8080)
+	TargetURL string `yaml:"target_url" env:"SHADOW_TARGET_URL"`
+
+	// Percent is the share of GET and HEAD requests (0-100) that is mirrored (default: 1)
+	Percent float64 `yaml:"percent" env:"SHADOW_PERCENT"`
+
+	// Timeout is the time a mirrored request may take before it counts as failed
+	Timeout time.Duration `yaml:"timeout" env:"SHADOW_TIMEOUT"`
+
+	// LatencyTolerance is how much slower than this server the shadow may answer before
+	// the request counts as a latency regression
+	LatencyTolerance time.Duration `yaml:"latency_tolerance" env:"SHADOW_LATENCY_TOLERANCE"`
+
+	// MaxInFlight is the number of mirrored requests running at once; more are dropped
+	MaxInFlight int `yaml:"max_in_flight" env:"SHADOW_MAX_IN_FLIGHT"`
+
+	// ForwardCredentials forwards the Authorization and X-API-Key headers, for shadows that
+	// share the JWT secret and database; otherwise only public endpoints compare meaningfully
+	ForwardCredentials bool `yaml:"forward_credentials" env:"SHADOW_FORWARD_CREDENTIALS"`
+
+	// ExcludedPaths lists path prefixes that are never mirrored; /api/auth is always excluded
+	ExcludedPaths []string `yaml:"excluded_paths" env:"SHADOW_EXCLUDED_PATHS"`
+}
+
+// StatusPageSettings configures the component checks behind the public status page.
+// Components without a configured endpoint are left off the page.
+type StatusPageSettings struct {
+	// DetectionServiceURL is the health endpoint of the detection service (e.g. http://detection:8000/health)
+	DetectionServiceURL string `yaml:"detection_service_url" env:"STATUS_DETECTION_SERVICE_URL"`
+
+	// CheckInterval is the time between two health checks of the components
+	CheckInterval time.Duration `yaml:"check_interval" env:"STATUS_CHECK_INTERVAL"`
+
+	// CheckTimeout is the maximum time a single component check may take
+	CheckTimeout time.Duration `yaml:"check_timeout" env:"STATUS_CHECK_TIMEOUT"`
+
+	// CacheTTL is the time the public status is served from the cache
+	CacheTTL time.Duration `yaml:"cache_ttl" env:"STATUS_CACHE_TTL"`
+}
+
+// SLOSettings configures the service-level objectives of the API. Their error budgets are
+// watched with multiwindow burn-rate rules on the request metrics kept in memory, and
+// administrators are alerted through notifications and webhooks when a budget burns too
+// fast. A negative target turns that objective off.
+type SLOSettings struct {
+	// Availability is the share of requests that must not fail with a server error (default: 0.995)
+	Availability float64 `yaml:"availability" env:"SLO_AVAILABILITY"`
+
+	// LatencyTarget is the share of requests that must be answered within LatencyThreshold (default: 0.99)
+	LatencyTarget float64 `yaml:"latency_target" env:"SLO_LATENCY_TARGET"`
+
+	// LatencyThreshold is the time within which a request counts as answered in time (default: 1s)
+	LatencyThreshold time.Duration `yaml:"latency_threshold" env:"SLO_LATENCY_THRESHOLD"`
+
+	// EvaluationInterval is the time between two evaluations of the objectives (default: 1m)
+	EvaluationInterval time.Duration `yaml:"evaluation_interval" env:"SLO_EVALUATION_INTERVAL"`
+
+	// MinRequests is the number of requests a rule's long window must hold before it can fire (default: 50)
+	MinRequests int64 `yaml:"min_requests" env:"SLO_MIN_REQUESTS"`
+}
+
+// SettingsCacheSettings configures the in-process cache of the detection configuration
+// composed from each user's settings.
+type SettingsCacheSettings struct {
+	// DetectionConfigTTL is how long a composed detection configuration is reused (default: 30s)
+	DetectionConfigTTL time.Duration `yaml:"detection_config_ttl" env:"DETECTION_CONFIG_CACHE_TTL"`
+}
+
+// PIIScreeningSettings configures the screening of free-text fields for personal data.
+// Fields such as API key names, admin action reasons and status incident notes are stored
+// as entered and never pass through the document redaction pipeline, so they are checked
+// for personal data before they are stored.
+type PIIScreeningSettings struct {
+	// Mode is off, warn (store the field and log a warning) or block (reject the request) (default: warn)
+	Mode string `yaml:"mode" env:"PII_SCREENING_MODE"`
+}
+
+// BenchmarkSettings configures the differential privacy of the cross-tenant benchmarks.
+// Each tenant's entities per page is clipped to MaxEntitiesPerPage and Laplace noise
+// calibrated to Epsilon is added to every published industry average.
+type BenchmarkSettings struct {
+	// Epsilon is the privacy budget spent on each published average (default: 1.0)
+	Epsilon float64 `yaml:"epsilon" env:"BENCHMARK_EPSILON"`
+
+	// MinTenants is the number of consenting tenants an industry needs before it is published (default: 5)
+	MinTenants int `yaml:"min_tenants" env:"BENCHMARK_MIN_TENANTS"`
+
+	// MaxEntitiesPerPage is the bound each tenant's entities per page is clipped to (default: 50)
+	MaxEntitiesPerPage float64 `yaml:"max_entities_per_page" env:"BENCHMARK_MAX_ENTITIES_PER_PAGE"`
+}
+
+// SecurityHeaderSettings configures the security headers added to every response.
+// API responses get ContentSecurityPolicy; responses under HTMLPaths, which render
+// pages in the browser, get HTMLContentSecurityPolicy instead.
+type SecurityHeaderSettings struct {
+	// HSTSEnabled adds Strict-Transport-Security to responses (default: on in production)
+	HSTSEnabled bool `yaml:"hsts_enabled" env:"SECURITY_HSTS_ENABLED"`
+
+	// HSTSMaxAge is how long browsers only reach the host over HTTPS (default: 1 year)
+	HSTSMaxAge time.Duration `yaml:"hsts_max_age" env:"SECURITY_HSTS_MAX_AGE"`
+
+	// HSTSIncludeSubdomains extends the HTTPS-only policy to all subdomains
+	HSTSIncludeSubdomains bool `yaml:"hsts_include_subdomains" env:"SECURITY_HSTS_INCLUDE_SUBDOMAINS"`
+
+	// ReferrerPolicy is the Referrer-Policy of all responses (default: strict-origin-when-cross-origin)
+	ReferrerPolicy string `yaml:"referrer_policy" env:"SECURITY_REFERRER_POLICY"`
+
+	// ContentSecurityPolicy is the Content-Security-Policy of API responses (default: default-src 'self')
+	ContentSecurityPolicy string `yaml:"content_security_policy" env:"SECURITY_CSP"`
+
+	// HTMLContentSecurityPolicy is the Content-Security-Policy of HTML pages such as the Swagger UI
+	HTMLContentSecurityPolicy string `yaml:"html_content_security_policy" env:"SECURITY_HTML_CSP"`
+
+	// HTMLPaths lists the path prefixes serving HTML pages (default: /docs/)
+	HTMLPaths []string `yaml:"html_paths" env:"SECURITY_HTML_PATHS"`
+}
+
+// IDGenerationSettings configures how the IDs of new resources, such as API keys and sessions, are generated.
+type IDGenerationSettings struct {
+	// Strategy is uuidv7 or snowflake (default: uuidv7)
+	Strategy string `yaml:"strategy" env:"ID_STRATEGY"`
+
+	// NodeID identifies this server instance in Snowflake IDs, between 0 and 1023;
+	// instances sharing a database need distinct node IDs
+	NodeID int64 `yaml:"node_id" env:"ID_NODE_ID"`
+}
+
+// DriveSettings configures the cloud drive connectors. A provider is offered to users
+// once its OAuth client ID and secret are set; OneDrive and SharePoint share the
+// Microsoft client.
+type DriveSettings struct {
+	// RedirectURL is the frontend page providers send users back to after authorization;
+	// it passes the code and state on to POST /api/drives/connect (e.g. https://hide-me.no/drives/callback)
+	RedirectURL string `yaml:"redirect_url" env:"DRIVE_REDIRECT_URL"`
+
+	// GoogleClientID is the OAuth client ID for Google Drive
+	GoogleClientID string `yaml:"google_client_id" env:"DRIVE_GOOGLE_CLIENT_ID"`
+
+	// GoogleClientSecret is the OAuth client secret for Google Drive
+	GoogleClientSecret string `yaml:"google_client_secret" env:"DRIVE_GOOGLE_CLIENT_SECRET"`
+
+	// MicrosoftClientID is the OAuth client ID for OneDrive and SharePoint
+	MicrosoftClientID string `yaml:"microsoft_client_id" env:"DRIVE_MICROSOFT_CLIENT_ID"`
+
+	// MicrosoftClientSecret is the OAuth client secret for OneDrive and SharePoint
+	MicrosoftClientSecret string `yaml:"microsoft_client_secret" env:"DRIVE_MICROSOFT_CLIENT_SECRET"`
+
+	// MicrosoftTenant is the Microsoft Entra tenant users sign in to (default: common)
+	MicrosoftTenant string `yaml:"microsoft_tenant" env:"DRIVE_MICROSOFT_TENANT"`
+
+	// MaxImportSize is the size limit in bytes of an imported file (default: 50 MiB)
+	MaxImportSize int64 `yaml:"max_import_size" env:"DRIVE_MAX_IMPORT_SIZE"`
+}
+
+// OAuthSettings configures sign-in with OpenID Connect providers. A provider is offered
+// to users once its OAuth client ID and secret are set.
+type OAuthSettings struct {
+	// CallbackBaseURL is the public URL of this API that providers send users back to;
+	// the redirect URI to register is {base}/api/auth/oauth/{provider}/callback (e.g. https://api.hide-me.no)
+	CallbackBaseURL string `yaml:"callback_base_url" env:"OAUTH_CALLBACK_BASE_URL"`
+
+	// LoginRedirectURL is the frontend page users are sent back to once the sign-in completed; it
+	// obtains an access token from /api/auth/refresh, or shows the message in the error query
+	// parameter if the sign-in failed. The callback responds with JSON instead if it is empty
+	LoginRedirectURL string `yaml:"login_redirect_url" env:"OAUTH_LOGIN_REDIRECT_URL"`
+
+	// GoogleClientID is the OAuth client ID for signing in with Google
+	GoogleClientID string `yaml:"google_client_id" env:"OAUTH_GOOGLE_CLIENT_ID"`
+
+	// GoogleClientSecret is the OAuth client secret for signing in with Google
+	GoogleClientSecret string `yaml:"google_client_secret" env:"OAUTH_GOOGLE_CLIENT_SECRET"`
+
+	// MicrosoftClientID is the OAuth client ID for signing in with Microsoft
+	MicrosoftClientID string `yaml:"microsoft_client_id" env:"OAUTH_MICROSOFT_CLIENT_ID"`
+
+	// MicrosoftClientSecret is the OAuth client secret for signing in with Microsoft
+	MicrosoftClientSecret string `yaml:"microsoft_client_secret" env:"OAUTH_MICROSOFT_CLIENT_SECRET"`
+
+	// MicrosoftTenant is the Microsoft Entra tenant users sign in to (default: common). Set it to
+	// the tenant ID to admit only that organization, whose email addresses are then trusted as verified
+	MicrosoftTenant string `yaml:"microsoft_tenant" env:"OAUTH_MICROSOFT_TENANT"`
+}
+
+// ExportSettings configures the delivery of redacted documents to users' export destinations.
+type ExportSettings struct {
+	// MaxAttempts is the number of times a delivery is attempted before it is given up (default: 3)
+	MaxAttempts int `yaml:"max_attempts" env:"EXPORT_MAX_ATTEMPTS"`
+
+	// AllowPrivateNetworks lets destinations resolve to loopback and private addresses,
+	// for deployments delivering to servers on their own network
+	AllowPrivateNetworks bool `yaml:"allow_private_networks" env:"EXPORT_ALLOW_PRIVATE_NETWORKS"`
+}
+
+// WebhookSettings configures the delivery of events to users' webhooks.
+type WebhookSettings struct {
+	// MaxAttempts is the number of times a delivery is attempted before it is dead-lettered (default: 10)
+	MaxAttempts int `yaml:"max_attempts" env:"WEBHOOK_MAX_ATTEMPTS"`
+
+	// AllowPrivateNetworks lets webhooks use plain HTTP and resolve to loopback and private
+	// addresses, for deployments calling services on their own network
+	AllowPrivateNetworks bool `yaml:"allow_private_networks" env:"WEBHOOK_ALLOW_PRIVATE_NETWORKS"`
+}
+
 // RateLimitSettings configures rate limiting behavior.
 type RateLimitSettings struct {
 	// Enabled determines if rate limiting is active
@@ -242,6 +742,63 @@ type RateLimitSettings struct {
 
 	// APIBurst is the burst limit for API endpoints
 	APIBurst int `yaml:"api_burst" env:"RATE_LIMIT_API_BURST"`
+
+	// UserRate is the rate limit of each authenticated user within a route group
+	UserRate float64 `yaml:"user_rate" env:"RATE_LIMIT_USER_RATE"`
+
+	// UserBurst is the burst limit of each authenticated user within a route group
+	UserBurst int `yaml:"user_burst" env:"RATE_LIMIT_USER_BURST"`
+
+	// Groups overrides the limits of route groups by name, such as "auth" or "documents"
+	Groups map[string]RateLimitGroupSettings `yaml:"groups"`
+}
+
+// RateLimitGroupSettings overrides the limits of one route group.
+// Zero values keep the limits the group has without an override.
+type RateLimitGroupSettings struct {
+	// Rate is the rate limit of each IP address in the group
+	Rate float64 `yaml:"rate"`
+
+	// Burst is the burst limit of each IP address in the group
+	Burst int `yaml:"burst"`
+
+	// UserRate is the rate limit of each authenticated user in the group
+	UserRate float64 `yaml:"user_rate"`
+
+	// UserBurst is the burst limit of each authenticated user in the group
+	UserBurst int `yaml:"user_burst"`
+}
+
+// Group returns the limits of a route group. The auth and api groups start from their
+// own limits and all other groups from the default ones; overrides in Groups apply on top.
+func (s *RateLimitSettings) Group(name string) RateLimitGroupSettings {
+	group := RateLimitGroupSettings{
+		Rate:      s.DefaultRate,
+		Burst:     s.DefaultBurst,
+		UserRate:  s.UserRate,
+		UserBurst: s.UserBurst,
+	}
+	switch name {
+	case constants.RateLimitGroupAuth:
+		group.Rate, group.Burst = s.AuthRate, s.AuthBurst
+	case constants.RateLimitGroupAPI:
+		group.Rate, group.Burst = s.APIRate, s.APIBurst
+	}
+
+	override := s.Groups[name]
+	if override.Rate > 0 {
+		group.Rate = override.Rate
+	}
+	if override.Burst > 0 {
+		group.Burst = override.Burst
+	}
+	if override.UserRate > 0 {
+		group.UserRate = override.UserRate
+	}
+	if override.UserBurst > 0 {
+		group.UserBurst = override.UserBurst
+	}
+	return group
 }
 
 // IPBanSettings configures IP address banning behavior.
@@ -425,6 +982,21 @@ func setDefaults(config *AppConfig) {
 	if config.Server.ShutdownTimeout == 0 {
 		config.Server.ShutdownTimeout = constants.DefaultShutdownTimeout
 	}
+	if config.Server.ReadHeaderTimeout == 0 {
+		config.Server.ReadHeaderTimeout = constants.DefaultReadHeaderTimeout
+	}
+	if config.Server.IdleTimeout == 0 {
+		config.Server.IdleTimeout = constants.DefaultIdleTimeout
+	}
+	if config.Server.MaxHeaderBytes == 0 {
+		config.Server.MaxHeaderBytes = constants.DefaultMaxHeaderBytes
+	}
+	if config.Server.TCPKeepAlive == 0 {
+		config.Server.TCPKeepAlive = constants.DefaultTCPKeepAlive
+	}
+	if config.Server.HTTP2MaxConcurrentStreams == 0 {
+		config.Server.HTTP2MaxConcurrentStreams = constants.DefaultHTTP2MaxConcurrentStreams
+	}
 
 	if config.Database.MaxConns == 0 {
 		config.Database.MaxConns = constants.DefaultDBMaxConnections
@@ -432,6 +1004,9 @@ func setDefaults(config *AppConfig) {
 	if config.Database.MinConns == 0 {
 		config.Database.MinConns = constants.DefaultDBMinConnections
 	}
+	if config.Database.SlowQueryThreshold == 0 {
+		config.Database.SlowQueryThreshold = constants.DefaultSlowQueryThreshold
+	}
 
 	// JWT defaults
 	if config.JWT.Expiry == 0 {
@@ -448,6 +1023,18 @@ func setDefaults(config *AppConfig) {
 	if config.APIKey.DefaultExpiry == 0 {
 		config.APIKey.DefaultExpiry = constants.DefaultAPIKeyExpiry
 	}
+	if config.APIKey.RotationOverlap == 0 {
+		config.APIKey.RotationOverlap = constants.DefaultAPIKeyRotationOverlap
+	}
+	if config.APIKey.CacheTTL == 0 {
+		config.APIKey.CacheTTL = constants.DefaultAPIKeyCacheTTL
+	}
+	if config.APIKey.FailureBackoff == 0 {
+		config.APIKey.FailureBackoff = constants.DefaultAPIKeyFailureBackoff
+	}
+	if config.APIKey.MaxFailureBackoff == 0 {
+		config.APIKey.MaxFailureBackoff = constants.DefaultAPIKeyMaxFailureBackoff
+	}
 
 	// Logging defaults
 	if config.Logging.Level == "" {
@@ -541,6 +1128,14 @@ func setDefaults(config *AppConfig) {
 		config.Security.RateLimiting.APIBurst = 50 // Burst of 50 for API
 	}
 
+	if config.Security.RateLimiting.UserRate == 0 {
+		config.Security.RateLimiting.UserRate = constants.DefaultRateLimitUserRate
+	}
+
+	if config.Security.RateLimiting.UserBurst == 0 {
+		config.Security.RateLimiting.UserBurst = constants.DefaultRateLimitUserBurst
+	}
+
 	// Security defaults - IP Banning
 	if !config.App.IsProduction() {
 		// Enable IP banning by default in production
@@ -567,6 +1162,257 @@ func setDefaults(config *AppConfig) {
 	if config.Security.IPBanning.AutoBanDuration == 0 {
 		config.Security.IPBanning.AutoBanDuration = 3 * time.Hour // Ban for 3 hours
 	}
+
+	// Approval defaults - every destructive action and every new administrator needs a second administrator
+	if len(config.Approvals.RequiredActions) == 0 {
+		config.Approvals.RequiredActions = []string{"user_erasure", "tenant_deletion", "admin_promotion"}
+	}
+
+	if config.Approvals.Expiry == 0 {
+		config.Approvals.Expiry = constants.DefaultAdminActionExpiry
+	}
+
+	// Risk scoring defaults
+	if config.Risk.CaptchaThreshold == 0 {
+		config.Risk.CaptchaThreshold = constants.DefaultRiskCaptchaThreshold
+	}
+
+	if config.Risk.EmailVerificationThreshold == 0 {
+		config.Risk.EmailVerificationThreshold = constants.DefaultRiskEmailVerificationThreshold
+	}
+
+	if config.Risk.ManualReviewThreshold == 0 {
+		config.Risk.ManualReviewThreshold = constants.DefaultRiskManualReviewThreshold
+	}
+
+	// Detection quota defaults
+	if config.Quota.KeyPrefix == "" {
+		config.Quota.KeyPrefix = constants.DefaultQuotaKeyPrefix
+	}
+
+	if config.Quota.Capacity == 0 {
+		config.Quota.Capacity = constants.DefaultDetectionQuotaCapacity
+	}
+
+	if config.Quota.RefillInterval == 0 {
+		config.Quota.RefillInterval = constants.DefaultDetectionQuotaRefillInterval
+	}
+
+	if config.Quota.WarningThresholds == nil {
+		config.Quota.WarningThresholds = []int{constants.DefaultQuotaWarningLowPercent, constants.DefaultQuotaWarningHighPercent}
+	}
+
+	// Upload limit defaults
+	if config.UploadLimits.MaxDocuments == 0 {
+		config.UploadLimits.MaxDocuments = constants.DefaultUploadMaxDocuments
+	}
+
+	if config.UploadLimits.MaxPages == 0 {
+		config.UploadLimits.MaxPages = constants.DefaultUploadMaxPages
+	}
+
+	if config.UploadLimits.MaxBytes == 0 {
+		config.UploadLimits.MaxBytes = constants.DefaultUploadMaxBytes
+	}
+
+	// Document archive defaults
+	if config.DocumentArchive.AfterDays == 0 {
+		config.DocumentArchive.AfterDays = constants.DefaultDocumentArchiveAfterDays
+	}
+
+	// Stale document defaults
+	if config.StaleDocuments.AfterDays == 0 {
+		config.StaleDocuments.AfterDays = constants.DefaultStaleDocumentAfterDays
+	}
+
+	if config.StaleDocuments.NoticeDays == 0 {
+		config.StaleDocuments.NoticeDays = constants.DefaultStaleDocumentNoticeDays
+	}
+
+	if config.StaleDocuments.Action == "" {
+		config.StaleDocuments.Action = constants.DefaultStaleDocumentAction
+	}
+
+	// Leak alert defaults
+	if config.LeakAlerts.Threshold == 0 {
+		config.LeakAlerts.Threshold = constants.DefaultLeakAlertThreshold
+	}
+
+	if config.LeakAlerts.WindowDays <= 0 {
+		config.LeakAlerts.WindowDays = constants.DefaultLeakAlertWindowDays
+	}
+
+	// Startup defaults
+	if config.Startup.MaxWait == 0 {
+		config.Startup.MaxWait = constants.DefaultStartupMaxWait
+	}
+
+	if config.Startup.InitialBackoff == 0 {
+		config.Startup.InitialBackoff = constants.DefaultStartupInitialBackoff
+	}
+
+	if config.Startup.MaxBackoff == 0 {
+		config.Startup.MaxBackoff = constants.DefaultStartupMaxBackoff
+	}
+
+	// Load shedding defaults
+	if !config.LoadShedding.Enabled {
+		// Load shedding is enabled by default in production
+		config.LoadShedding.Enabled = config.App.IsProduction()
+	}
+
+	if config.LoadShedding.DegradedLatency == 0 {
+		config.LoadShedding.DegradedLatency = constants.DefaultLoadShedDegradedLatency
+	}
+
+	if config.LoadShedding.OverloadedLatency == 0 {
+		config.LoadShedding.OverloadedLatency = constants.DefaultLoadShedOverloadedLatency
+	}
+
+	if config.LoadShedding.ErrorRate == 0 {
+		config.LoadShedding.ErrorRate = constants.DefaultLoadShedErrorRate
+	}
+
+	if config.LoadShedding.MinSamples == 0 {
+		config.LoadShedding.MinSamples = constants.DefaultLoadShedMinSamples
+	}
+
+	// Shadow traffic defaults
+	if config.ShadowTraffic.Percent == 0 {
+		config.ShadowTraffic.Percent = constants.DefaultShadowPercent
+	}
+
+	if config.ShadowTraffic.Timeout == 0 {
+		config.ShadowTraffic.Timeout = constants.DefaultShadowTimeout
+	}
+
+	if config.ShadowTraffic.LatencyTolerance == 0 {
+		config.ShadowTraffic.LatencyTolerance = constants.DefaultShadowLatencyTolerance
+	}
+
+	if config.ShadowTraffic.MaxInFlight == 0 {
+		config.ShadowTraffic.MaxInFlight = constants.DefaultShadowMaxInFlight
+	}
+
+	// Status page defaults
+	if config.StatusPage.CheckInterval == 0 {
+		config.StatusPage.CheckInterval = constants.DefaultStatusCheckInterval
+	}
+
+	if config.StatusPage.CheckTimeout == 0 {
+		config.StatusPage.CheckTimeout = constants.DefaultStatusCheckTimeout
+	}
+
+	if config.StatusPage.CacheTTL == 0 {
+		config.StatusPage.CacheTTL = constants.DefaultStatusCacheTTL
+	}
+
+	// SLO defaults
+	if config.SLO.Availability == 0 {
+		config.SLO.Availability = constants.DefaultSLOAvailability
+	}
+
+	if config.SLO.LatencyTarget == 0 {
+		config.SLO.LatencyTarget = constants.DefaultSLOLatencyTarget
+	}
+
+	if config.SLO.LatencyThreshold == 0 {
+		config.SLO.LatencyThreshold = constants.DefaultSLOLatencyThreshold
+	}
+
+	if config.SLO.EvaluationInterval == 0 {
+		config.SLO.EvaluationInterval = constants.DefaultSLOEvaluationInterval
+	}
+
+	if config.SLO.MinRequests == 0 {
+		config.SLO.MinRequests = constants.DefaultSLOMinRequests
+	}
+
+	// Settings cache defaults
+	if config.SettingsCache.DetectionConfigTTL == 0 {
+		config.SettingsCache.DetectionConfigTTL = constants.DefaultDetectionConfigCacheTTL
+	}
+
+	// PII screening defaults - warn until operators opt in to blocking
+	if config.PIIScreening.Mode == "" {
+		config.PIIScreening.Mode = constants.PIIScreeningWarn
+	}
+
+	// Benchmark defaults
+	if config.Benchmarks.Epsilon == 0 {
+		config.Benchmarks.Epsilon = constants.DefaultBenchmarkEpsilon
+	}
+
+	if config.Benchmarks.MinTenants == 0 {
+		config.Benchmarks.MinTenants = constants.DefaultBenchmarkMinTenants
+	}
+
+	if config.Benchmarks.MaxEntitiesPerPage == 0 {
+		config.Benchmarks.MaxEntitiesPerPage = constants.DefaultBenchmarkMaxEntitiesPerPage
+	}
+
+	// Security header defaults - HSTS would lock developers' browsers out of plain HTTP on localhost
+	if !config.SecurityHeaders.HSTSEnabled {
+		config.SecurityHeaders.HSTSEnabled = config.App.IsProduction()
+	}
+
+	if config.SecurityHeaders.HSTSMaxAge == 0 {
+		config.SecurityHeaders.HSTSMaxAge = constants.DefaultHSTSMaxAge
+	}
+
+	if config.SecurityHeaders.ReferrerPolicy == "" {
+		config.SecurityHeaders.ReferrerPolicy = constants.ReferrerPolicyStrictOrigin
+	}
+
+	if config.SecurityHeaders.ContentSecurityPolicy == "" {
+		config.SecurityHeaders.ContentSecurityPolicy = constants.CSPDefaultSrc
+	}
+
+	if config.SecurityHeaders.HTMLContentSecurityPolicy == "" {
+		config.SecurityHeaders.HTMLContentSecurityPolicy = constants.CSPHTMLDefault
+	}
+
+	if len(config.SecurityHeaders.HTMLPaths) == 0 {
+		config.SecurityHeaders.HTMLPaths = []string{"/docs/"}
+	}
+
+	// ID generation defaults
+	if config.IDGeneration.Strategy == "" {
+		config.IDGeneration.Strategy = constants.IDStrategyUUIDv7
+	}
+
+	// Cloud drive defaults
+	if config.Drives.MicrosoftTenant == "" {
+		config.Drives.MicrosoftTenant = constants.DefaultMicrosoftTenant
+	}
+
+	if config.Drives.MaxImportSize == 0 {
+		config.Drives.MaxImportSize = constants.DefaultDriveMaxImportSize
+	}
+
+	// Social login defaults
+	if config.OAuth.MicrosoftTenant == "" {
+		config.OAuth.MicrosoftTenant = constants.DefaultMicrosoftTenant
+	}
+
+	// Export delivery defaults
+	if config.Exports.MaxAttempts == 0 {
+		config.Exports.MaxAttempts = constants.DefaultExportDeliveryMaxAttempts
+	}
+
+	// Webhook delivery defaults
+	if config.Webhooks.MaxAttempts == 0 {
+		config.Webhooks.MaxAttempts = constants.DefaultWebhookMaxAttempts
+	}
+
+	// Document storage defaults
+	if config.DocumentStorage.Backend == "" {
+		config.DocumentStorage.Backend = constants.DocumentStorageLocal
+	}
+
+	if config.DocumentStorage.LocalPath == "" {
+		config.DocumentStorage.LocalPath = constants.DefaultDocumentStoragePath
+	}
 }
 
 // validateConfig validates that the configuration has all required values
@@ -588,6 +1434,11 @@ func validateConfig(config *AppConfig) error {
 		config.App.Environment = constants.EnvDevelopment
 	}
 
+	// Validate server tuning - timeouts must be positive and the header limit sensible
+	if err := validateServerSettings(&config.Server); err != nil {
+		return err
+	}
+
 	// Database validation - connection details required
 	if config.Database.User == "" {
 		return fmt.Errorf("database user must be set")
@@ -607,6 +1458,132 @@ func validateConfig(config *AppConfig) error {
 		return fmt.Errorf("invalid log level: %s", config.Logging.Level)
 	}
 
+	// Validate PII screening mode
+	switch strings.ToLower(config.PIIScreening.Mode) {
+	case "", constants.PIIScreeningOff, constants.PIIScreeningWarn, constants.PIIScreeningBlock:
+	default:
+		return fmt.Errorf("invalid PII screening mode: %s", config.PIIScreening.Mode)
+	}
+
+	// Validate ID generation - Snowflake node IDs must fit their bits
+	switch strings.ToLower(config.IDGeneration.Strategy) {
+	case "", constants.IDStrategyUUIDv7:
+	case constants.IDStrategySnowflake:
+		if config.IDGeneration.NodeID < 0 || config.IDGeneration.NodeID >= 1<<constants.SnowflakeNodeBits {
+			return fmt.Errorf("snowflake node ID must be between 0 and %d: %d", 1<<constants.SnowflakeNodeBits-1, config.IDGeneration.NodeID)
+		}
+	default:
+		return fmt.Errorf("invalid ID generation strategy: %s", config.IDGeneration.Strategy)
+	}
+
+	// Validate the cloud drive import limit
+	if config.Drives.MaxImportSize < 0 {
+		return fmt.Errorf("drive max import size must not be negative: %d", config.Drives.MaxImportSize)
+	}
+
+	// Validate the export delivery attempts
+	if config.Exports.MaxAttempts < 0 {
+		return fmt.Errorf("export max attempts must not be negative: %d", config.Exports.MaxAttempts)
+	}
+
+	// Validate the webhook delivery attempts
+	if config.Webhooks.MaxAttempts < 0 {
+		return fmt.Errorf("webhook max attempts must not be negative: %d", config.Webhooks.MaxAttempts)
+	}
+
+	// Validate the stale document cleanup - owners must be told before their documents go
+	switch config.StaleDocuments.Action {
+	case "", constants.StaleDocumentActionArchive, constants.StaleDocumentActionDelete:
+	default:
+		return fmt.Errorf("stale document action must be %q or %q: %q", constants.StaleDocumentActionArchive, constants.StaleDocumentActionDelete, config.StaleDocuments.Action)
+	}
+
+	if config.StaleDocuments.NoticeDays < 0 {
+		return fmt.Errorf("stale document notice days must not be negative: %d", config.StaleDocuments.NoticeDays)
+	}
+
+	// Validate the SLOs - a target of 1 leaves no error budget to burn
+	if config.SLO.Availability >= 1 || config.SLO.LatencyTarget >= 1 {
+		return fmt.Errorf("SLO targets must be below 1: %g, %g", config.SLO.Availability, config.SLO.LatencyTarget)
+	}
+
+	if config.SLO.LatencyThreshold < 0 || config.SLO.EvaluationInterval < 0 {
+		return fmt.Errorf("SLO latency threshold and evaluation interval must not be negative")
+	}
+
+	// Validate shadow traffic - the target must be an absolute HTTP(S) URL
+	if shadow := config.ShadowTraffic; shadow.TargetURL != "" {
+		target, err := url.Parse(shadow.TargetURL)
+		if err != nil || (target.Scheme != "http" && target.Scheme != "https") || target.Host == "" {
+			return fmt.Errorf("invalid shadow traffic target URL: %s", shadow.TargetURL)
+		}
+		if shadow.Percent < 0 || shadow.Percent > 100 {
+			return fmt.Errorf("shadow traffic percent must be between 0 and 100")
+		}
+	}
+
+	// Validate the document storage - the s3 backend needs its bucket and credentials
+	switch strings.ToLower(config.DocumentStorage.Backend) {
+	case "", constants.DocumentStorageLocal:
+	case constants.DocumentStorageS3:
+		storage := config.DocumentStorage
+		if storage.S3Bucket == "" || storage.S3Region == "" || storage.S3AccessKeyID == "" || storage.S3SecretAccessKey == "" {
+			return fmt.Errorf("document storage s3 requires a bucket, region, access key ID and secret access key")
+		}
+	default:
+		return fmt.Errorf("invalid document storage backend: %s", config.DocumentStorage.Backend)
+	}
+
+	// Validate quota warning thresholds - percentages of the quota used
+	for _, threshold := range config.Quota.WarningThresholds {
+		if threshold <= 0 || threshold >= 100 {
+			return fmt.Errorf("quota warning threshold must be between 1 and 99: %d", threshold)
+		}
+	}
+
+	// Validate benchmark privacy - an industry of a single tenant would publish that tenant's value
+	if config.Benchmarks.Epsilon < 0 {
+		return fmt.Errorf("benchmark epsilon must be positive: %g", config.Benchmarks.Epsilon)
+	}
+
+	if config.Benchmarks.MinTenants != 0 && config.Benchmarks.MinTenants < 2 {
+		return fmt.Errorf("benchmark minimum tenants must be at least 2: %d", config.Benchmarks.MinTenants)
+	}
+
+	if config.Benchmarks.MaxEntitiesPerPage < 0 {
+		return fmt.Errorf("benchmark maximum entities per page must be positive: %g", config.Benchmarks.MaxEntitiesPerPage)
+	}
+
+	return nil
+}
+
+// validateServerSettings validates the timeouts and limits of the HTTP server.
+//
+// Parameters:
+//   - server: The server settings to validate; zero values stand for the defaults
+//
+// Returns:
+//   - An error naming the invalid setting, nil if the settings are valid
+func validateServerSettings(server *ServerSettings) error {
+	if server.ReadTimeout < 0 || server.WriteTimeout < 0 || server.ReadHeaderTimeout < 0 || server.IdleTimeout < 0 || server.ShutdownTimeout < 0 {
+		return fmt.Errorf("server timeouts must not be negative")
+	}
+	// Headers are part of the request, so reading them cannot take longer than the request
+	if server.ReadTimeout > 0 && server.ReadHeaderTimeout > server.ReadTimeout {
+		return fmt.Errorf("server read header timeout (%s) must not exceed the read timeout (%s)", server.ReadHeaderTimeout, server.ReadTimeout)
+	}
+	if server.MaxHeaderBytes != 0 && (server.MaxHeaderBytes < constants.MinMaxHeaderBytes || server.MaxHeaderBytes > constants.MaxMaxHeaderBytes) {
+		return fmt.Errorf("server max header bytes must be between %d and %d", constants.MinMaxHeaderBytes, constants.MaxMaxHeaderBytes)
+	}
+	if server.HTTP2MaxConcurrentStreams < 0 {
+		return fmt.Errorf("server HTTP/2 max concurrent streams must not be negative")
+	}
+	for _, proxy := range server.TrustedProxies {
+		proxy = strings.TrimSpace(proxy)
+		if _, _, err := net.ParseCIDR(proxy); err != nil && net.ParseIP(proxy) == nil {
+			return fmt.Errorf("invalid trusted proxy: %s", proxy)
+		}
+	}
 	return nil
 }
 
@@ -3,6 +3,9 @@ package config
 import (
 	"os"
 	"testing"
+	"time"
+
+	"github.com/yasinhessnawi1/Hideme_Backend/internal/constants"
 )
 
 func TestLoad(t *testing.T) {
@@ -197,6 +200,77 @@ func TestSetDefaults(t *testing.T) {
 
 }
 
+func TestSetDefaults_SecurityHeaders(t *testing.T) {
+	tests := []struct {
+		environment string
+		wantHSTS    bool
+	}{
+		{environment: "production", wantHSTS: true},
+		{environment: "development", wantHSTS: false},
+		{environment: "testing", wantHSTS: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.environment, func(t *testing.T) {
+			config := &AppConfig{App: AppSettings{Environment: tt.environment}}
+
+			setDefaults(config)
+
+			headers := config.SecurityHeaders
+			if headers.HSTSEnabled != tt.wantHSTS {
+				t.Errorf("HSTSEnabled = %v, want %v", headers.HSTSEnabled, tt.wantHSTS)
+			}
+			if headers.HSTSMaxAge != constants.DefaultHSTSMaxAge {
+				t.Errorf("HSTSMaxAge = %v, want %v", headers.HSTSMaxAge, constants.DefaultHSTSMaxAge)
+			}
+			if headers.ContentSecurityPolicy != constants.CSPDefaultSrc {
+				t.Errorf("ContentSecurityPolicy = %q, want %q", headers.ContentSecurityPolicy, constants.CSPDefaultSrc)
+			}
+			if len(headers.HTMLPaths) != 1 || headers.HTMLPaths[0] != "/docs/" {
+				t.Errorf("HTMLPaths = %v, want [/docs/]", headers.HTMLPaths)
+			}
+		})
+	}
+
+	// Configured policies are kept
+	config := &AppConfig{SecurityHeaders: SecurityHeaderSettings{ContentSecurityPolicy: "default-src 'none'"}}
+	setDefaults(config)
+	if config.SecurityHeaders.ContentSecurityPolicy != "default-src 'none'" {
+		t.Errorf("ContentSecurityPolicy = %q, want the configured policy", config.SecurityHeaders.ContentSecurityPolicy)
+	}
+}
+
+func TestRateLimitSettings_Group(t *testing.T) {
+	settings := &RateLimitSettings{
+		DefaultRate: 100, DefaultBurst: 50,
+		AuthRate: 10, AuthBurst: 15,
+		APIRate: 40, APIBurst: 50,
+		UserRate: 20, UserBurst: 40,
+		Groups: map[string]RateLimitGroupSettings{
+			constants.RateLimitGroupAuth: {Burst: 5},
+			"exports":                    {Rate: 1, UserRate: 0.5, UserBurst: 2},
+		},
+	}
+
+	tests := []struct {
+		name  string
+		group string
+		want  RateLimitGroupSettings
+	}{
+		{"Default group", constants.RateLimitGroupDefault, RateLimitGroupSettings{Rate: 100, Burst: 50, UserRate: 20, UserBurst: 40}},
+		{"API group", constants.RateLimitGroupAPI, RateLimitGroupSettings{Rate: 40, Burst: 50, UserRate: 20, UserBurst: 40}},
+		{"Partial override", constants.RateLimitGroupAuth, RateLimitGroupSettings{Rate: 10, Burst: 5, UserRate: 20, UserBurst: 40}},
+		{"Configured group", "exports", RateLimitGroupSettings{Rate: 1, Burst: 50, UserRate: 0.5, UserBurst: 2}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := settings.Group(tt.group); got != tt.want {
+				t.Errorf("Group(%q) = %+v, want %+v", tt.group, got, tt.want)
+			}
+		})
+	}
+}
+
 func TestValidateConfig(t *testing.T) {
 	tests := []struct {
 		name      string
@@ -293,6 +367,245 @@ func TestValidateConfig(t *testing.T) {
 			},
 			shouldErr: true,
 		},
+		{
+			name: "Invalid PII screening mode",
+			config: &AppConfig{
+				App: AppSettings{
+					Environment: "development",
+				},
+				Database: DatabaseSettings{
+					User: "testuser",
+				},
+				JWT: JWTSettings{
+					Secret: "some-secret",
+				},
+				Logging: LoggingSettings{
+					Level: "info",
+				},
+				PIIScreening: PIIScreeningSettings{
+					Mode: "quarantine", // Invalid mode
+				},
+			},
+			shouldErr: true,
+		},
+		{
+			name: "Benchmarks of a single tenant",
+			config: &AppConfig{
+				App: AppSettings{
+					Environment: "development",
+				},
+				Database: DatabaseSettings{
+					User: "testuser",
+				},
+				JWT: JWTSettings{
+					Secret: "some-secret",
+				},
+				Logging: LoggingSettings{
+					Level: "info",
+				},
+				Benchmarks: BenchmarkSettings{
+					MinTenants: 1, // Would publish the tenant's own value
+				},
+			},
+			shouldErr: true,
+		},
+		{
+			name: "Snowflake node ID out of range",
+			config: &AppConfig{
+				App: AppSettings{
+					Environment: "development",
+				},
+				Database: DatabaseSettings{
+					User: "testuser",
+				},
+				JWT: JWTSettings{
+					Secret: "some-secret",
+				},
+				Logging: LoggingSettings{
+					Level: "info",
+				},
+				IDGeneration: IDGenerationSettings{
+					Strategy: "snowflake",
+					NodeID:   1024, // Node IDs have 10 bits
+				},
+			},
+			shouldErr: true,
+		},
+		{
+			name: "S3 document storage without bucket",
+			config: &AppConfig{
+				App: AppSettings{
+					Environment: "development",
+				},
+				Database: DatabaseSettings{
+					User: "testuser",
+				},
+				JWT: JWTSettings{
+					Secret: "some-secret",
+				},
+				Logging: LoggingSettings{
+					Level: "info",
+				},
+				DocumentStorage: DocumentStorageSettings{
+					Backend:  "s3",
+					S3Region: "eu-north-1", // Bucket and credentials missing
+				},
+			},
+			shouldErr: true,
+		},
+		{
+			name: "Server tuned for streaming",
+			config: &AppConfig{
+				App: AppSettings{
+					Environment: "development",
+				},
+				Server: ServerSettings{
+					ReadTimeout:       30 * time.Second,
+					ReadHeaderTimeout: 5 * time.Second,
+					WriteTimeout:      5 * time.Minute,
+					MaxHeaderBytes:    64 << 10,
+					HTTP2Enabled:      true,
+				},
+				Database: DatabaseSettings{
+					User: "testuser",
+				},
+				JWT: JWTSettings{
+					Secret: "some-secret",
+				},
+				Logging: LoggingSettings{
+					Level: "info",
+				},
+			},
+			shouldErr: false,
+		},
+		{
+			name: "Server read header timeout over read timeout",
+			config: &AppConfig{
+				App: AppSettings{
+					Environment: "development",
+				},
+				Server: ServerSettings{
+					ReadTimeout:       5 * time.Second,
+					ReadHeaderTimeout: 10 * time.Second,
+				},
+				Database: DatabaseSettings{
+					User: "testuser",
+				},
+				JWT: JWTSettings{
+					Secret: "some-secret",
+				},
+				Logging: LoggingSettings{
+					Level: "info",
+				},
+			},
+			shouldErr: true,
+		},
+		{
+			name: "Server max header bytes too small",
+			config: &AppConfig{
+				App: AppSettings{
+					Environment: "development",
+				},
+				Server: ServerSettings{
+					MaxHeaderBytes: 512,
+				},
+				Database: DatabaseSettings{
+					User: "testuser",
+				},
+				JWT: JWTSettings{
+					Secret: "some-secret",
+				},
+				Logging: LoggingSettings{
+					Level: "info",
+				},
+			},
+			shouldErr: true,
+		},
+		{
+			name: "Invalid trusted proxy",
+			config: &AppConfig{
+				App: AppSettings{
+					Environment: "development",
+				},
+				Server: ServerSettings{
+					TrustedProxies: []string{"10.0.0.0/8", "proxy.internal"},
+				},
+				Database: DatabaseSettings{
+					User: "testuser",
+				},
+				JWT: JWTSettings{
+					Secret: "some-secret",
+				},
+				Logging: LoggingSettings{
+					Level: "info",
+				},
+			},
+			shouldErr: true,
+		},
+		{
+			name: "Shadow traffic target without scheme",
+			config: &AppConfig{
+				App: AppSettings{
+					Environment: "development",
+				},
+				Database: DatabaseSettings{
+					User: "testuser",
+				},
+				JWT: JWTSettings{
+					Secret: "some-secret",
+				},
+				Logging: LoggingSettings{
+					Level: "info",
+				},
+				ShadowTraffic: ShadowTrafficSettings{
+					TargetURL: "hideme-rc:8080",
+					Percent:   5,
+				},
+			},
+			shouldErr: true,
+		},
+		{
+			name: "Unknown stale document action",
+			config: &AppConfig{
+				App: AppSettings{
+					Environment: "development",
+				},
+				Database: DatabaseSettings{
+					User: "testuser",
+				},
+				JWT: JWTSettings{
+					Secret: "some-secret",
+				},
+				Logging: LoggingSettings{
+					Level: "info",
+				},
+				StaleDocuments: StaleDocumentSettings{
+					Action: "shred",
+				},
+			},
+			shouldErr: true,
+		},
+		{
+			name: "SLO availability of 100 percent",
+			config: &AppConfig{
+				App: AppSettings{
+					Environment: "development",
+				},
+				Database: DatabaseSettings{
+					User: "testuser",
+				},
+				JWT: JWTSettings{
+					Secret: "some-secret",
+				},
+				Logging: LoggingSettings{
+					Level: "info",
+				},
+				SLO: SLOSettings{
+					Availability: 1,
+				},
+			},
+			shouldErr: true,
+		},
 	}
 
 	for _, tt := range tests {
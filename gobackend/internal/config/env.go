@@ -78,6 +78,96 @@ func LoadEnv(config *AppConfig) error {
 		return err
 	}
 
+	// Process ApprovalSettings
+	if err := processStructEnv(&config.Approvals); err != nil {
+		return err
+	}
+
+	// Process RiskSettings
+	if err := processStructEnv(&config.Risk); err != nil {
+		return err
+	}
+
+	// Process QuotaSettings
+	if err := processStructEnv(&config.Quota); err != nil {
+		return err
+	}
+
+	// Process DocumentArchiveSettings
+	if err := processStructEnv(&config.DocumentArchive); err != nil {
+		return err
+	}
+
+	// Process LeakAlertSettings
+	if err := processStructEnv(&config.LeakAlerts); err != nil {
+		return err
+	}
+
+	// Process DocumentStorageSettings
+	if err := processStructEnv(&config.DocumentStorage); err != nil {
+		return err
+	}
+
+	// Process StartupSettings
+	if err := processStructEnv(&config.Startup); err != nil {
+		return err
+	}
+
+	// Process LoadSheddingSettings
+	if err := processStructEnv(&config.LoadShedding); err != nil {
+		return err
+	}
+
+	// Process ShadowTrafficSettings
+	if err := processStructEnv(&config.ShadowTraffic); err != nil {
+		return err
+	}
+
+	// Process StatusPageSettings
+	if err := processStructEnv(&config.StatusPage); err != nil {
+		return err
+	}
+
+	// Process PIIScreeningSettings
+	if err := processStructEnv(&config.PIIScreening); err != nil {
+		return err
+	}
+
+	// Process RateLimitSettings
+	if err := processStructEnv(&config.Security.RateLimiting); err != nil {
+		return err
+	}
+
+	// Process SecurityHeaderSettings
+	if err := processStructEnv(&config.SecurityHeaders); err != nil {
+		return err
+	}
+
+	// Process IDGenerationSettings
+	if err := processStructEnv(&config.IDGeneration); err != nil {
+		return err
+	}
+
+	// Process DriveSettings
+	if err := processStructEnv(&config.Drives); err != nil {
+		return err
+	}
+
+	// Process ExportSettings
+	if err := processStructEnv(&config.Exports); err != nil {
+		return err
+	}
+
+	// Process WebhookSettings
+	if err := processStructEnv(&config.Webhooks); err != nil {
+		return err
+	}
+
+	// Process OAuthSettings
+	if err := processStructEnv(&config.OAuth); err != nil {
+		return err
+	}
+
 	// Log some key environment variables for debugging purposes
 	// Note that sensitive values are not logged here
 	log.Debug().
@@ -169,8 +259,9 @@ func processStructEnv(s interface{}) error {
 			fieldVal.SetFloat(floatValue)
 
 		case reflect.Slice:
-			// Handle slice types (only string slices supported for now)
-			if fieldVal.Type().Elem().Kind() == reflect.String {
+			// Handle slice types (string and int slices supported for now)
+			switch fieldVal.Type().Elem().Kind() {
+			case reflect.String:
 				// Split comma-separated list into slice
 				values := strings.Split(envValue, ",")
 				// Trim whitespace from each value
@@ -178,6 +269,19 @@ func processStructEnv(s interface{}) error {
 					values[i] = strings.TrimSpace(v)
 				}
 				fieldVal.Set(reflect.ValueOf(values))
+
+			case reflect.Int:
+				// Split comma-separated list into integers
+				parts := strings.Split(envValue, ",")
+				values := make([]int, 0, len(parts))
+				for _, part := range parts {
+					value, err := strconv.Atoi(strings.TrimSpace(part))
+					if err != nil {
+						return fmt.Errorf("invalid integer list for %s: %w", envName, err)
+					}
+					values = append(values, value)
+				}
+				fieldVal.Set(reflect.ValueOf(values))
 			}
 
 		default:
@@ -91,6 +91,7 @@ func TestProcessStructEnv(t *testing.T) {
 		DurField    time.Duration `env:"TEST_DURATION"`
 		FloatField  float64       `env:"TEST_FLOAT"`
 		StrSlice    []string      `env:"TEST_SLICE"`
+		IntSlice    []int         `env:"TEST_INT_SLICE"`
 		NoEnvTag    string
 	}
 
@@ -101,6 +102,7 @@ func TestProcessStructEnv(t *testing.T) {
 	os.Setenv("TEST_DURATION", "15m")
 	os.Setenv("TEST_FLOAT", "3.14")
 	os.Setenv("TEST_SLICE", "item1,item2,item3")
+	os.Setenv("TEST_INT_SLICE", "80, 95")
 
 	// Clean up
 	defer func() {
@@ -110,6 +112,7 @@ func TestProcessStructEnv(t *testing.T) {
 		os.Unsetenv("TEST_DURATION")
 		os.Unsetenv("TEST_FLOAT")
 		os.Unsetenv("TEST_SLICE")
+		os.Unsetenv("TEST_INT_SLICE")
 	}()
 
 	// Create struct
@@ -153,6 +156,10 @@ func TestProcessStructEnv(t *testing.T) {
 		}
 	}
 
+	if len(testStruct.IntSlice) != 2 || testStruct.IntSlice[0] != 80 || testStruct.IntSlice[1] != 95 {
+		t.Errorf("Expected IntSlice = [80 95], got %v", testStruct.IntSlice)
+	}
+
 	// Field without env tag should be unchanged
 	if testStruct.NoEnvTag != "" {
 		t.Errorf("Expected NoEnvTag to be empty, got %s", testStruct.NoEnvTag)
@@ -196,6 +203,13 @@ func TestProcessStructEnvErrors(t *testing.T) {
 			fieldType:   "FloatField",
 			shouldError: true,
 		},
+		{
+			name:        "Invalid int slice",
+			envName:     "TEST_INT_SLICE",
+			envValue:    "80,high",
+			fieldType:   "IntSlice",
+			shouldError: true,
+		},
 	}
 
 	for _, tt := range tests {
@@ -219,6 +233,10 @@ func TestProcessStructEnvErrors(t *testing.T) {
 				testStruct = &struct {
 					FloatField float64 `env:"TEST_FLOAT"`
 				}{}
+			case "IntSlice":
+				testStruct = &struct {
+					IntSlice []int `env:"TEST_INT_SLICE"`
+				}{}
 			}
 
 			// Set environment variable
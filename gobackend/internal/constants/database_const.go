@@ -42,6 +42,125 @@ const (
 
 	// TableAPIKeys is the name of the table storing API key information.
 	TableAPIKeys = "api_keys"
+
+	// TableSettingsRevisions is the name of the table storing the settings change history.
+	TableSettingsRevisions = "settings_revisions"
+
+	// TableTenantUsage is the name of the table storing monthly usage rollups per tenant.
+	TableTenantUsage = "tenant_usage"
+
+	// TableAdminActions is the name of the table storing the queue of destructive admin actions.
+	TableAdminActions = "admin_actions"
+
+	// TableAccountHolds is the name of the table storing accounts held back by risk scoring.
+	TableAccountHolds = "account_holds"
+
+	// TableTenantKeys is the name of the table storing the wrapped data-encryption key of each tenant.
+	TableTenantKeys = "tenant_keys"
+
+	// TableReportSubscriptions is the name of the table storing users' subscriptions to scheduled reports.
+	TableReportSubscriptions = "report_subscriptions"
+
+	// TableClassificationRules is the name of the table storing users' document classification rules.
+	TableClassificationRules = "classification_rules"
+
+	// TableBenchmarkConsents is the name of the table storing the tenants sharing their statistics for benchmarks.
+	TableBenchmarkConsents = "benchmark_consents"
+
+	// TableStatusIncidents is the name of the table storing the incident notes shown on the status page.
+	TableStatusIncidents = "status_incidents"
+
+	// TableComponentUptime is the name of the table storing hourly health check rollups per component.
+	TableComponentUptime = "component_uptime"
+
+	// TableAnnouncements is the name of the table storing the announcements published to users.
+	TableAnnouncements = "announcements"
+
+	// TableAnnouncementReads is the name of the table recording which users have read which announcements.
+	TableAnnouncementReads = "announcement_reads"
+
+	// TableDriveConnections is the name of the table storing users' cloud drive authorizations.
+	TableDriveConnections = "drive_connections"
+
+	// TableExportDestinations is the name of the table storing the destinations users deliver redacted documents to.
+	TableExportDestinations = "export_destinations"
+
+	// TableExportDeliveries is the name of the table tracking the delivery of documents to export destinations.
+	TableExportDeliveries = "export_deliveries"
+
+	// TableRuleHits is the name of the table counting the detections produced by ban list words and search patterns.
+	TableRuleHits = "rule_hits"
+
+	// TableProcessingAudits is the name of the table recording documents processed without being stored.
+	TableProcessingAudits = "processing_audits"
+
+	// TableAPIKeyRotationCampaigns is the name of the table storing forced rotations of API keys.
+	TableAPIKeyRotationCampaigns = "api_key_rotation_campaigns"
+
+	// TableAPIKeyPolicy is the name of the single-row table storing the API key expiry policy.
+	TableAPIKeyPolicy = "api_key_policy"
+
+	// TableUserIdentities is the name of the table linking users to their accounts at sign-in providers.
+	TableUserIdentities = "user_identities"
+
+	// TableDocumentArchives is the name of the cold storage table holding the redaction schemas
+	// and detected entities of archived documents.
+	TableDocumentArchives = "document_archives"
+
+	// TableUserDataExports is the name of the table tracking the export jobs of all data kept about users.
+	TableUserDataExports = "user_data_exports"
+
+	// TableUserDataExportChunks is the name of the table holding the encrypted output of user data export jobs.
+	TableUserDataExportChunks = "user_data_export_chunks"
+
+	// TableRegistrationDomains is the name of the table holding the email domains registration is limited to.
+	TableRegistrationDomains = "registration_domains"
+
+	// TableAccountErasures is the name of the table tracking the scheduled erasures of user accounts.
+	TableAccountErasures = "account_erasures"
+
+	// TableDocumentContents is the name of the table linking documents to their encrypted PDF content in storage.
+	TableDocumentContents = "document_contents"
+
+	// TableDocumentShares is the name of the table granting users access to documents of other users.
+	TableDocumentShares = "document_shares"
+
+	// TableEntityHashes is the name of the table storing salted hashes of detected entity values.
+	TableEntityHashes = "entity_hashes"
+
+	// TableLeakAlerts is the name of the table recording alerts about values leaking in many documents.
+	TableLeakAlerts = "leak_alerts"
+
+	// TableDocumentTransfers is the name of the table recording transfers of documents between users.
+	TableDocumentTransfers = "document_transfers"
+
+	// TableAuditorGrants is the name of the table storing time-boxed read-only access grants for external auditors.
+	TableAuditorGrants = "auditor_grants"
+
+	// TableAuditorAccessLog is the name of the table logging every request made with an auditor grant.
+	TableAuditorAccessLog = "auditor_access_log"
+
+	// TableDocumentSchemaVersions is the name of the table keeping the redaction schemas documents had before they were replaced.
+	TableDocumentSchemaVersions = "document_schema_versions"
+
+	// TableMaintenanceTaskSettings is the name of the table storing the settings administrators chose for maintenance tasks.
+	TableMaintenanceTaskSettings = "maintenance_task_settings"
+
+	// TableJobs is the name of the table queuing the detection, redaction and export jobs run in the background.
+	TableJobs = "jobs"
+
+	// TableDomainEvents is the name of the append-only table of the domain changes derived tables are rebuilt from.
+	TableDomainEvents = "domain_events"
+
+	// TableWebhooks is the name of the table storing the URLs users receive account and document events at.
+	TableWebhooks = "webhooks"
+
+	// TableWebhookDeliveries is the name of the table queuing the deliveries of events to webhooks.
+	TableWebhookDeliveries = "webhook_deliveries"
+
+	// TableEntityTypes is the name of the table holding the entity-type taxonomy, which maps the
+	// entity types that can be detected to the detection method detecting them.
+	TableEntityTypes = "entity_types"
 )
 
 // Common Column Names define frequently used database column names.
@@ -86,6 +205,54 @@ const (
 	// ColumnAPIKeyHash is the column name for hashed API key values.
 	ColumnAPIKeyHash = "api_key_hash"
 
+	// ColumnRotatedFrom is the column name for the API key a key replaced on rotation.
+	ColumnRotatedFrom = "rotated_from"
+
+	// ColumnRotatedAt is the column name for when an API key was replaced on rotation.
+	ColumnRotatedAt = "rotated_at"
+
+	// ColumnUsedAfterRotationAt is the column name for the first use of an API key after it was rotated.
+	ColumnUsedAfterRotationAt = "used_after_rotation_at"
+
+	// ColumnDisabledAt is the column name for when an administrator disabled an API key.
+	ColumnDisabledAt = "disabled_at"
+
+	// ColumnRotationCampaignID is the column name for the rotation campaign an API key is part of.
+	ColumnRotationCampaignID = "rotation_campaign_id"
+
+	// ColumnRotationDeadline is the column name for when an API key in a rotation campaign stops working.
+	ColumnRotationDeadline = "rotation_deadline"
+
+	// ColumnScopes is the column name for the scopes an API key is limited to.
+	ColumnScopes = "scopes"
+
+	// ColumnSchemaVersion is the column name for the coordinate system version of a redaction schema.
+	ColumnSchemaVersion = "schema_version"
+
+	// ColumnRuleID is the column name for classification rule identifiers.
+	ColumnRuleID = "rule_id"
+
+	// ColumnRetainUntil is the column name for when a document is deleted under its retention.
+	ColumnRetainUntil = "retain_until"
+
+	// ColumnFilenameScrubbed is the column name for whether a document's filename was scrubbed at upload.
+	ColumnFilenameScrubbed = "filename_scrubbed"
+
+	// ColumnArchivedAt is the column name for when a document was moved to cold storage.
+	ColumnArchivedAt = "archived_at"
+
+	// ColumnEntityCount is the column name for the number of detections in a document's redaction schema.
+	ColumnEntityCount = "entity_count"
+
+	// ColumnEntityTypes is the column name for the entity types detected in a document.
+	ColumnEntityTypes = "entity_types"
+
+	// ColumnLifecycleExempt is the column name for whether a document is exempt from stale document cleanup.
+	ColumnLifecycleExempt = "lifecycle_exempt"
+
+	// ColumnStaleNotifiedAt is the column name for when the owner was told a document is stale.
+	ColumnStaleNotifiedAt = "stale_notified_at"
+
 	// ColumnName is the column name for resource names.
 	ColumnName = "name"
 
@@ -95,6 +262,18 @@ const (
 	// ColumnWord is the column name for ban list words.
 	ColumnWord = "word"
 
+	// ColumnCaseInsensitive is the column name for whether a ban list word matches in any case.
+	ColumnCaseInsensitive = "case_insensitive"
+
+	// ColumnDiacriticInsensitive is the column name for whether a ban list word matches without its diacritics.
+	ColumnDiacriticInsensitive = "diacritic_insensitive"
+
+	// ColumnWholeWord is the column name for whether a ban list word matches whole words only.
+	ColumnWholeWord = "whole_word"
+
+	// ColumnStemming is the column name for the language whose inflections of a ban list word match it.
+	ColumnStemming = "stemming"
+
 	// ColumnPatternType is the column name for search pattern types.
 	ColumnPatternType = "pattern_type"
 
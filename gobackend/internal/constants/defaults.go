@@ -20,6 +20,9 @@ const (
 
 	// MinPageSize is the minimum allowable page size.
 	MinPageSize = 1
+
+	// MaxSettingsChangesPerPage is the maximum number of revisions returned by a single change feed request.
+	MaxSettingsChangesPerPage = 500
 )
 
 // Default Configuration Values define fallback settings when not specified in configuration.
@@ -28,12 +31,25 @@ const (
 	// DefaultServerPort is the default HTTP server port.
 	DefaultServerPort = 8080
 
+	// DefaultMaxHeaderBytes is the default limit of the request header size, the same as the Go default.
+	DefaultMaxHeaderBytes = 1 << 20
+
+	// MinMaxHeaderBytes and MaxMaxHeaderBytes bound the configurable request header size.
+	MinMaxHeaderBytes = 4 << 10
+	MaxMaxHeaderBytes = 16 << 20
+
+	// DefaultHTTP2MaxConcurrentStreams is the default number of concurrent streams per HTTP/2 connection.
+	DefaultHTTP2MaxConcurrentStreams = 250
+
 	// DefaultDBMaxConnections is the default maximum number of database connections.
 	DefaultDBMaxConnections = 20
 
 	// DefaultDBMinConnections is the default minimum number of database connections.
 	DefaultDBMinConnections = 5
 
+	// MaxTrackedQueries is the maximum number of distinct queries the query metrics keep statistics for.
+	MaxTrackedQueries = 500
+
 	// DefaultLogLevel is the default logging verbosity level.
 	DefaultLogLevel = "info"
 
@@ -137,3 +153,1100 @@ const (
 	// APIKeyDurationFormat30Minutes is the string representation of a 30-minute API key duration (for debugging).
 	APIKeyDurationFormat30Minutes = "30m"
 )
+
+// Usage and Billing Defaults define values used for tenant usage rollups and billing exports.
+const (
+	// BillingMonthFormat is the layout used for month query parameters and export file names.
+	BillingMonthFormat = "2006-01"
+
+	// BillingExportFilenamePrefix is the prefix for generated billing export file names.
+	BillingExportFilenamePrefix = "hideme-billing-"
+)
+
+// Document Export Defaults define the formats and file names of single-document exports.
+const (
+	// DocumentExportFilenamePrefix is the prefix for the file names of document exports.
+	DocumentExportFilenamePrefix = "hideme-document-"
+
+	// DocumentExportFormatJSON exports a document record as a single JSON file.
+	DocumentExportFormatJSON = "json"
+
+	// DocumentExportFormatZIP exports a document record as a ZIP archive with one JSON file per section.
+	DocumentExportFormatZIP = "zip"
+
+	// DocumentExportTimelinePageSize is the number of timeline events read per query when exporting a document.
+	DocumentExportTimelinePageSize = 500
+)
+
+// User Data Export Defaults define the file names and job states of the exports of all data
+// kept about a user. They use the formats of document exports.
+const (
+	// UserDataExportFilenamePrefix is the prefix for the file names of user data exports.
+	UserDataExportFilenamePrefix = "hideme-data-export-"
+
+	// UserDataExportPending marks an export job that has not started yet.
+	UserDataExportPending = "pending"
+
+	// UserDataExportRunning marks an export job a server is working on.
+	UserDataExportRunning = "running"
+
+	// UserDataExportInterrupted marks an export job stopped by a shutdown; it resumes from its checkpoint.
+	UserDataExportInterrupted = "interrupted"
+
+	// UserDataExportCompleted marks an export job whose file can be downloaded.
+	UserDataExportCompleted = "completed"
+
+	// UserDataExportFailed marks an export job that stopped with an error.
+	UserDataExportFailed = "failed"
+
+	// UserDataExportBatchSize is the number of documents an export job exports between two checkpoints.
+	UserDataExportBatchSize = 50
+)
+
+// Startup Phases define how far the server got, as reported by the probe endpoints and to systemd.
+const (
+	// PhaseStarting marks a server waiting for the database and cache to become reachable.
+	PhaseStarting = "starting"
+
+	// PhaseMigrating marks a server running the database migrations.
+	PhaseMigrating = "migrating"
+
+	// PhaseReady marks a server serving the API.
+	PhaseReady = "ready"
+
+	// PhaseStopping marks a server shutting down.
+	PhaseStopping = "stopping"
+)
+
+// Job Queue Defaults define the kinds and states of the jobs running detection, redaction and
+// export of documents in the background, for documents too large to process within a request.
+const (
+	// JobKindDetection adds a batch of detected entities to a document.
+	JobKindDetection = "detection"
+
+	// JobKindRedaction applies the redaction schema of a document to its stored PDF.
+	JobKindRedaction = "redaction"
+
+	// JobKindExport assembles the full record of a document.
+	JobKindExport = "export"
+
+	// JobPending marks a job waiting for its first or next attempt.
+	JobPending = "pending"
+
+	// JobRunning marks a job a worker is running.
+	JobRunning = "running"
+
+	// JobCompleted marks a job whose result can be downloaded.
+	JobCompleted = "completed"
+
+	// JobFailed marks a job that failed for good, after its last attempt or with an error retrying cannot fix.
+	JobFailed = "failed"
+
+	// JobMaxAttempts is the number of times a job is attempted before it fails for good.
+	JobMaxAttempts = 5
+
+	// JobWorkers is the number of jobs a server runs at the same time.
+	JobWorkers = 4
+
+	// JobResultFilenamePrefix is the prefix for the file names of detection job results.
+	JobResultFilenamePrefix = "hideme-job-"
+)
+
+// Notification Defaults define the events streamed to the logged-in user at /api/events.
+const (
+	// NotificationJobUpdated reports a background job that was queued, started, retried,
+	// completed or failed; the data is the job.
+	NotificationJobUpdated = "job_updated"
+
+	// NotificationDetectionCompleted reports a detection job whose entities were added to its
+	// document; the data is the job.
+	NotificationDetectionCompleted = "detection_completed"
+
+	// NotificationSettingsChanged reports settings changed on another device; the data holds
+	// the latest revision, from which clients fetch the changes at /api/settings/changes.
+	NotificationSettingsChanged = "settings_changed"
+
+	// NotificationSLOAlert reports to administrators a service-level objective alert that
+	// fired or resolved; the data holds the alert.
+	NotificationSLOAlert = "slo_alert"
+
+	// NotificationChannel is the PostgreSQL channel relaying notifications between servers.
+	NotificationChannel = "hideme_notifications"
+
+	// NotificationMaxRelaySize is the largest notification relayed between servers; PostgreSQL
+	// rejects larger payloads. Larger notifications reach the streams of this server only.
+	NotificationMaxRelaySize = 7900
+
+	// NotificationStreamBuffer is the number of notifications queued for a stream. A stream
+	// whose client falls this far behind is closed; the client reconnects and resyncs.
+	NotificationStreamBuffer = 64
+
+	// MaxNotificationStreamsPerUser is the number of event streams a user may keep open.
+	MaxNotificationStreamsPerUser = 10
+)
+
+// Domain Event Defaults define the domain changes recorded in the event log and the derived
+// tables, or projections, administrators can rebuild by replaying them.
+const (
+	// EventUsageCounted records the pages and API calls of a tenant added to a monthly usage rollup.
+	EventUsageCounted = "usage_counted"
+
+	// EventRuleHitsRecorded records the detections of a saved document counted towards the user's rules.
+	EventRuleHitsRecorded = "rule_hits_recorded"
+
+	// ProjectionTenantUsage is the page and API call counts of the monthly tenant usage rollups.
+	ProjectionTenantUsage = "tenant_usage"
+
+	// ProjectionRuleHits is the detection counts of users' ban list words and search patterns.
+	ProjectionRuleHits = "rule_hits"
+
+	// DomainEventReplayBatchSize is the number of events read at a time while rebuilding a projection.
+	DomainEventReplayBatchSize = 500
+)
+
+// Account Erasure Defaults define the states of the jobs erasing user accounts (GDPR Article 17).
+const (
+	// AccountErasureScheduled marks an erasure waiting for its grace period to pass, or for its next attempt.
+	AccountErasureScheduled = "scheduled"
+
+	// AccountErasureRunning marks an erasure a server is working on.
+	AccountErasureRunning = "running"
+
+	// AccountErasureCompleted marks an erasure whose account and data are gone.
+	AccountErasureCompleted = "completed"
+
+	// AccountErasureCancelled marks an erasure the user cancelled during the grace period.
+	AccountErasureCancelled = "cancelled"
+)
+
+// Risk Scoring Defaults define the thresholds and scores used to assess registrations and logins.
+// Scores range from 0 (no risk) to MaxRiskScore; each threshold is the minimum score
+// that triggers the corresponding challenge.
+const (
+	// MaxRiskScore is the highest possible combined risk score.
+	MaxRiskScore = 100
+
+	// DefaultRiskCaptchaThreshold is the default score from which a CAPTCHA is required.
+	DefaultRiskCaptchaThreshold = 30
+
+	// DefaultRiskEmailVerificationThreshold is the default score from which email verification is required.
+	DefaultRiskEmailVerificationThreshold = 50
+
+	// DefaultRiskManualReviewThreshold is the default score from which an administrator must review the account.
+	DefaultRiskManualReviewThreshold = 80
+
+	// RiskScoreIPReputation is the score added when the client IP is on the reputation list.
+	RiskScoreIPReputation = 40
+
+	// RiskScoreDisposableEmail is the score added when the email uses a disposable email domain.
+	RiskScoreDisposableEmail = 50
+)
+
+// Detection Quota Defaults define the token bucket shared with the detection service.
+// Each page sent for detection costs one token; the bucket refills continuously.
+const (
+	// DefaultDetectionQuotaCapacity is the default number of tokens in a full bucket.
+	DefaultDetectionQuotaCapacity = 500
+
+	// DefaultQuotaKeyPrefix is the default prefix of the Redis keys holding the buckets.
+	// The detection service must use the same prefix to share the buckets.
+	DefaultQuotaKeyPrefix = "quota:detection:"
+
+	// DefaultQuotaWarningLowPercent is the default share of the quota used at which the first warning is sent.
+	DefaultQuotaWarningLowPercent = 80
+
+	// DefaultQuotaWarningHighPercent is the default share of the quota used at which the last warning is sent.
+	DefaultQuotaWarningHighPercent = 95
+
+	// DefaultRedisPoolSize is the default number of idle Redis connections kept open.
+	DefaultRedisPoolSize = 10
+)
+
+// Upload Limits define the limits checked before an upload is accepted, and their defaults.
+// A limit of zero or less is not enforced.
+const (
+	// UploadLimitDocuments names the limit on the number of documents a user stores.
+	UploadLimitDocuments = "documents"
+
+	// UploadLimitPages names the limit on the pages of one uploaded document.
+	UploadLimitPages = "pages"
+
+	// UploadLimitBytes names the limit on the size of one upload request.
+	UploadLimitBytes = "bytes"
+
+	// UploadIntegritySize names the check of an uploaded file against its declared size in bytes.
+	UploadIntegritySize = "size_bytes"
+
+	// UploadIntegritySHA256 names the check of an uploaded file against its declared SHA-256.
+	UploadIntegritySHA256 = "sha256"
+
+	// DefaultUploadMaxDocuments is the default number of documents a user may store.
+	DefaultUploadMaxDocuments = 10000
+
+	// DefaultUploadMaxPages is the default number of pages of one uploaded document.
+	DefaultUploadMaxPages = 1000
+
+	// DefaultUploadMaxBytes is the default size of one upload request (25 MiB). Uploads
+	// with a JSON body are bounded by the request body limit as well; multipart uploads
+	// with the PDF only by this limit.
+	DefaultUploadMaxBytes = 25 * 1024 * 1024
+)
+
+// Admin Search Defaults define the limits of the administrator search across all resources.
+const (
+	// AdminSearchMinQueryLength is the shortest search term, so a search cannot list whole tables.
+	AdminSearchMinQueryLength = 3
+
+	// AdminSearchMaxQueryLength is the longest search term.
+	AdminSearchMaxQueryLength = 255
+
+	// AdminSearchResultLimit is the maximum number of matches returned per resource type.
+	AdminSearchResultLimit = 20
+)
+
+// API Key Cache Defaults define the size of the API key verification cache.
+const (
+	// APIKeyCacheMaxEntries is the number of verified keys and failing clients from which
+	// expired entries are pruned.
+	APIKeyCacheMaxEntries = 10000
+)
+
+// Streaming Defaults define how list endpoints stream newline-delimited JSON.
+const (
+	// StreamFlushRows is the number of rows written before the response is flushed to the client.
+	StreamFlushRows = 100
+)
+
+// Index Advisor Defaults define the heuristics used to suggest missing indexes.
+const (
+	// IndexAdvisorMinTableRows is the number of rows from which sequential scans of a table are reported.
+	// Small tables are scanned faster than they are looked up through an index.
+	IndexAdvisorMinTableRows = 10000
+
+	// IndexAdvisorSeqScanRatio is how many times more sequential than index scans a table may see
+	// before they are reported.
+	IndexAdvisorSeqScanRatio = 1.0
+
+	// IndexAdvisorSlowStatementMs is the mean execution time in milliseconds from which
+	// a statement recorded by pg_stat_statements is reported.
+	IndexAdvisorSlowStatementMs = 100.0
+
+	// IndexAdvisorMaxStatements is the maximum number of statements included in a report.
+	IndexAdvisorMaxStatements = 20
+
+	// SeverityWarning marks index advisor suggestions that likely cause slow requests.
+	SeverityWarning = "warning"
+
+	// SeverityInfo marks index advisor suggestions worth reviewing.
+	SeverityInfo = "info"
+)
+
+// Benchmark Defaults define the differential privacy of the cross-tenant benchmarks.
+const (
+	// DefaultBenchmarkEpsilon is the default privacy budget spent on each published benchmark.
+	// Smaller values add more noise and protect the individual tenants better.
+	DefaultBenchmarkEpsilon = 1.0
+
+	// DefaultBenchmarkMinTenants is the default number of consenting tenants an industry needs
+	// before its benchmark is published.
+	DefaultBenchmarkMinTenants = 5
+
+	// DefaultBenchmarkMaxEntitiesPerPage is the default bound each tenant's entities per page
+	// is clipped to, which limits how much a single tenant can move the average.
+	DefaultBenchmarkMaxEntitiesPerPage = 50.0
+)
+
+// Processing Modes define what is kept of a document processed through the API.
+const (
+	// ProcessingModePersistent stores the document and its detected entities. It is the default.
+	ProcessingModePersistent = "persistent"
+
+	// ProcessingModeEphemeral returns the processed document without storing it or its entities;
+	// only an audit stub with the page and entity counts is kept.
+	ProcessingModeEphemeral = "ephemeral"
+)
+
+// API Key Statuses describe an API key in the administrator listing, in order of precedence.
+const (
+	// APIKeyStatusDisabled is a key an administrator disabled.
+	APIKeyStatusDisabled = "disabled"
+
+	// APIKeyStatusExpired is a key past its expiry or the deadline of its rotation campaign.
+	APIKeyStatusExpired = "expired"
+
+	// APIKeyStatusRotated is a key replaced by a rotation, working until the end of the overlap window.
+	APIKeyStatusRotated = "rotated"
+
+	// APIKeyStatusPendingRotation is a key a rotation campaign requires its owner to rotate.
+	APIKeyStatusPendingRotation = "pending_rotation"
+
+	// APIKeyStatusActive is any other key.
+	APIKeyStatusActive = "active"
+)
+
+// API Key Scopes limit the routes an API key may call. A write scope includes the matching
+// read scope. Keys without scopes may call every route their owner may, as all keys could
+// before scopes were introduced.
+const (
+	// APIKeyScopeSettingsRead allows reading the settings, ban list, patterns and rules.
+	APIKeyScopeSettingsRead = "settings:read"
+
+	// APIKeyScopeSettingsWrite allows changing the settings, ban list, patterns and rules.
+	APIKeyScopeSettingsWrite = "settings:write"
+
+	// APIKeyScopeDocumentsRead allows listing, reading and exporting documents.
+	APIKeyScopeDocumentsRead = "documents:read"
+
+	// APIKeyScopeDocumentsWrite allows uploading, restoring, delivering and deleting documents.
+	APIKeyScopeDocumentsWrite = "documents:write"
+
+	// APIKeyScopeAdmin allows calling the admin routes, if the key's owner is an administrator.
+	APIKeyScopeAdmin = "admin"
+
+	// APIKeyScopeWriteSuffix ends the name of every write scope.
+	APIKeyScopeWriteSuffix = ":write"
+
+	// APIKeyScopeReadSuffix ends the name of every read scope.
+	APIKeyScopeReadSuffix = ":read"
+)
+
+// Redaction Placeholder Defaults define the text that replaces redacted entities.
+const (
+	// DefaultRedactionPlaceholder replaces entities whose type has no configured placeholder.
+	DefaultRedactionPlaceholder = "[REDACTED]"
+)
+
+// Redaction Schema Defaults define how bounding boxes are moved to page-relative coordinates.
+const (
+	// DefaultPageWidthPoints is the width of an A4 page in points, assumed for absolute
+	// bounding boxes on pages of unknown size.
+	DefaultPageWidthPoints = 595.0
+
+	// DefaultPageHeightPoints is the height of an A4 page in points, assumed for absolute
+	// bounding boxes on pages of unknown size.
+	DefaultPageHeightPoints = 842.0
+
+	// SchemaNormalizationBatchSize is the maximum number of documents whose redaction
+	// schemas are converted to page-relative coordinates in one maintenance run.
+	SchemaNormalizationBatchSize = 500
+
+	// EntitySummaryBatchSize is the maximum number of documents stored before document
+	// search whose entity counts and types are computed in one maintenance run.
+	EntitySummaryBatchSize = 500
+
+	// DocumentSearchMaxEntityTypes is the maximum number of entity types a document search filters by.
+	DocumentSearchMaxEntityTypes = 20
+
+	// DefaultOverlayColor is the color of page overlay rectangles whose detection method is unknown.
+	DefaultOverlayColor = "#000000"
+
+	// DetectedEntityBatchMax is the maximum number of detected entities added to a document in one call.
+	DetectedEntityBatchMax = 1000
+
+	// DetectedEntityInsertRows is the number of detected entities inserted by one statement of
+	// a batch; with five parameters per row it stays well below the PostgreSQL parameter limit.
+	DetectedEntityInsertRows = 500
+)
+
+// Document Archive Defaults define when documents are moved to cold storage.
+const (
+	// DefaultDocumentArchiveAfterDays is the default number of days without a change after
+	// which a document is archived.
+	DefaultDocumentArchiveAfterDays = 365
+
+	// DocumentArchiveBatchSize is the maximum number of documents archived in one maintenance run.
+	DocumentArchiveBatchSize = 500
+
+	// StorageTierHot marks a document whose redaction schema and entities are readable.
+	StorageTierHot = "hot"
+
+	// StorageTierArchive marks a document in cold storage, which must be restored before it is read.
+	StorageTierArchive = "archive"
+)
+
+// Stale Document Defaults define when documents left unfinished are cleaned up. A document is
+// stale when it was never processed or its detections have waited for review, untouched, for
+// the stale threshold; its owner is told, and it is archived or deleted after the notice period.
+const (
+	// DefaultStaleDocumentAfterDays is the default number of days an unfinished document may
+	// stay untouched before its owner is told it will be cleaned up.
+	DefaultStaleDocumentAfterDays = 30
+
+	// DefaultStaleDocumentNoticeDays is the default number of days between telling the owner
+	// and cleaning up the document.
+	DefaultStaleDocumentNoticeDays = 7
+
+	// DefaultStaleDocumentAction is what is done with stale documents by default.
+	DefaultStaleDocumentAction = StaleDocumentActionArchive
+
+	// StaleDocumentBatchSize is the maximum number of stale documents notified about and
+	// cleaned up in one maintenance run, each.
+	StaleDocumentBatchSize = 500
+
+	// StaleDocumentActionArchive moves stale documents to cold storage.
+	StaleDocumentActionArchive = "archive"
+
+	// StaleDocumentActionDelete deletes stale documents.
+	StaleDocumentActionDelete = "delete"
+
+	// StaleReasonUnprocessed marks a document without any detections, which was uploaded but never processed.
+	StaleReasonUnprocessed = "unprocessed"
+
+	// StaleReasonPendingReview marks a document with detected entities still awaiting review.
+	StaleReasonPendingReview = "pending_review"
+)
+
+// Document Retention Defaults define the preview of the documents deleted under their retention.
+const (
+	// RetentionPreviewLimit is the maximum number of documents listed by a retention preview;
+	// the counts cover all of them.
+	RetentionPreviewLimit = 500
+
+	// RetentionReasonRule marks a document whose retention, set by a classification rule at upload, has passed.
+	RetentionReasonRule = "classification_rule"
+
+	// RetentionReasonSetting marks a document uploaded longer ago than its owner's document retention setting.
+	RetentionReasonSetting = "retention_setting"
+)
+
+// Scheduled Report Defaults define when and how subscribed reports are generated and delivered.
+const (
+	// ReportDeliveryHourUTC is the hour of the day (UTC) at which scheduled reports are sent.
+	ReportDeliveryHourUTC = 6
+
+	// ReportBatchSize is the maximum number of reports sent by a single maintenance run.
+	ReportBatchSize = 100
+
+	// ReportFilenamePrefix is the prefix for the file names of report attachments.
+	ReportFilenamePrefix = "hideme-"
+
+	// ReportDateFormat is the layout used for dates in report file names and subjects.
+	ReportDateFormat = "2006-01-02"
+)
+
+// Rate Limit Defaults define the route groups requests are rate limited in.
+const (
+	// RateLimitGroupDefault limits every request, and applies to groups without their own limits.
+	RateLimitGroupDefault = "default"
+
+	// RateLimitGroupAuth limits the authentication endpoints, strictly to slow down brute force.
+	RateLimitGroupAuth = "auth"
+
+	// RateLimitGroupAPI limits the settings endpoints.
+	RateLimitGroupAPI = "api"
+
+	// RateLimitGroupDocuments limits the document endpoints.
+	RateLimitGroupDocuments = "documents"
+
+	// RateLimitUserGroupSuffix is appended to a group to name the per-user buckets of the group.
+	RateLimitUserGroupSuffix = ":user"
+
+	// RateLimitUserClientPrefix is prepended to user IDs so they never collide with IP addresses.
+	RateLimitUserClientPrefix = "user:"
+
+	// DefaultRateLimitUserRate is the default number of requests per second allowed per user in a group.
+	DefaultRateLimitUserRate = 20.0
+
+	// DefaultRateLimitUserBurst is the default number of requests a user may send at once in a group.
+	DefaultRateLimitUserBurst = 40
+)
+
+// Load Shedding Defaults define when requests are shed while the database is slow or failing.
+const (
+	// DefaultLoadShedErrorRate is the default share of failing queries (0-1) from which normal-priority requests are shed.
+	DefaultLoadShedErrorRate = 0.25
+
+	// DefaultLoadShedMinSamples is the default number of queries the window must hold before any request is shed,
+	// so that a few slow queries on an idle server don't trigger shedding.
+	DefaultLoadShedMinSamples = 20
+
+	// LoadShedPriorityLow marks route groups that are shed first, such as analytics and exports.
+	LoadShedPriorityLow = "low"
+
+	// LoadShedPriorityNormal marks route groups that are shed once the database is overloaded.
+	LoadShedPriorityNormal = "normal"
+
+	// LoadShedPriorityCritical marks route groups that are never shed, such as authentication.
+	LoadShedPriorityCritical = "critical"
+)
+
+// Shadow Traffic Defaults define how much read-only traffic is mirrored to a shadow deployment.
+const (
+	// DefaultShadowPercent is the default share of read-only requests (0-100) that is mirrored.
+	DefaultShadowPercent = 1.0
+
+	// DefaultShadowMaxInFlight is the default number of mirrored requests running at once;
+	// requests sampled beyond it are dropped rather than queued.
+	DefaultShadowMaxInFlight = 20
+
+	// ShadowExcludedPathPrefix is never mirrored: its GET endpoints consume one-time OAuth codes.
+	ShadowExcludedPathPrefix = "/api/auth"
+
+	// ShadowExcludedStreamPath is never mirrored: its event stream stays open until the client leaves.
+	ShadowExcludedStreamPath = "/api/events"
+
+	// ShadowMaxRoutes bounds the number of routes whose divergences are counted separately.
+	ShadowMaxRoutes = 200
+
+	// ShadowOtherRoute collects the divergences of routes beyond ShadowMaxRoutes.
+	ShadowOtherRoute = "other"
+)
+
+// Status Page Defaults define the components and windows shown on the public status page.
+const (
+	// StatusComponentAPI is the status page component for this API server.
+	StatusComponentAPI = "api"
+
+	// StatusComponentDatabase is the status page component for the PostgreSQL database.
+	StatusComponentDatabase = "database"
+
+	// StatusComponentDetection is the status page component for the detection service.
+	StatusComponentDetection = "detection_service"
+
+	// StatusComponentStorage is the status page component for the Redis store shared with the detection service.
+	StatusComponentStorage = "storage"
+
+	// StatusIncidentListLimit is the maximum number of incidents returned to administrators.
+	StatusIncidentListLimit = 100
+
+	// AnnouncementListLimit is the maximum number of announcements returned to administrators.
+	AnnouncementListLimit = 100
+)
+
+// PII Screening Modes define how personal data found in free-text fields is handled.
+const (
+	// PIIScreeningOff disables the screening of free-text fields.
+	PIIScreeningOff = "off"
+
+	// PIIScreeningWarn stores the field and logs a warning naming the kinds of data found.
+	PIIScreeningWarn = "warn"
+
+	// PIIScreeningBlock rejects the request with a validation error.
+	PIIScreeningBlock = "block"
+)
+
+// ID Generation Strategies define how the IDs of new resources, such as API keys and sessions, are generated.
+// Both strategies are time-sortable and, unlike sequences, don't reveal how many resources exist.
+const (
+	// IDStrategyUUIDv7 generates UUIDv7s: a millisecond timestamp followed by random bits.
+	IDStrategyUUIDv7 = "uuidv7"
+
+	// IDStrategySnowflake generates Snowflake IDs: a millisecond timestamp, a node ID and a sequence.
+	// Every server instance needs its own node ID.
+	IDStrategySnowflake = "snowflake"
+
+	// SnowflakeEpochMillis is the start of Snowflake timestamps (2024-01-01T00:00:00Z), in Unix milliseconds.
+	SnowflakeEpochMillis int64 = 1704067200000
+
+	// SnowflakeNodeBits is the number of bits of the node ID in a Snowflake ID.
+	SnowflakeNodeBits = 10
+
+	// SnowflakeSequenceBits is the number of bits of the per-millisecond sequence in a Snowflake ID.
+	SnowflakeSequenceBits = 12
+)
+
+// Cloud Drive Providers define the cloud drives documents can be imported from.
+// The provider name is also stored as the source of imported documents.
+const (
+	// DriveProviderGoogle imports from Google Drive.
+	DriveProviderGoogle = "google_drive"
+
+	// DriveProviderOneDrive imports from the user's OneDrive.
+	DriveProviderOneDrive = "onedrive"
+
+	// DriveProviderSharePoint imports from the document libraries of SharePoint sites.
+	DriveProviderSharePoint = "sharepoint"
+
+	// DriveListPageSize is the number of files requested per page of a folder listing.
+	DriveListPageSize = 100
+
+	// DefaultDriveMaxImportSize is the default size limit of a file imported from a cloud drive (50 MiB).
+	DefaultDriveMaxImportSize = 50 << 20
+
+	// DefaultMicrosoftTenant lets both work and personal Microsoft accounts authorize.
+	DefaultMicrosoftTenant = "common"
+
+	// GoogleAuthURL is the OAuth authorization endpoint of Google.
+	GoogleAuthURL = "https://accounts.google.com/o/oauth2/v2/auth"
+
+	// GoogleTokenURL is the OAuth token endpoint of Google.
+	GoogleTokenURL = "https://oauth2.googleapis.com/token"
+
+	// GoogleDriveAPIURL is the base URL of the Google Drive API.
+	GoogleDriveAPIURL = "https://www.googleapis.com/drive/v3"
+
+	// GoogleDriveScope grants read-only access to the user's Google Drive.
+	GoogleDriveScope = "https://www.googleapis.com/auth/drive.readonly"
+
+	// GoogleFolderMimeType is the MIME type of Google Drive folders.
+	GoogleFolderMimeType = "application/vnd.google-apps.folder"
+
+	// GoogleAppsMimePrefix starts the MIME types of native Google documents, which are exported as PDF.
+	GoogleAppsMimePrefix = "application/vnd.google-apps."
+
+	// MicrosoftAuthURLFormat is the OAuth authorization endpoint of a Microsoft tenant.
+	MicrosoftAuthURLFormat = "https://login.microsoftonline.com/%s/oauth2/v2.0/authorize"
+
+	// MicrosoftTokenURLFormat is the OAuth token endpoint of a Microsoft tenant.
+	MicrosoftTokenURLFormat = "https://login.microsoftonline.com/%s/oauth2/v2.0/token"
+
+	// MicrosoftGraphURL is the base URL of the Microsoft Graph API.
+	MicrosoftGraphURL = "https://graph.microsoft.com/v1.0"
+
+	// OneDriveScope grants read-only access to the user's OneDrive, with refresh tokens.
+	OneDriveScope = "offline_access Files.Read.All"
+
+	// SharePointScope grants access to the SharePoint sites the user can open, with refresh tokens.
+	// Files are written as well, to deliver redacted documents to SharePoint export destinations.
+	SharePointScope = "offline_access Files.ReadWrite.All Sites.Read.All"
+)
+
+// Social Login Providers define the OpenID Connect providers users can sign in with.
+// The provider name is also stored with the identities linked to user accounts.
+const (
+	// OAuthProviderGoogle signs in with a Google account.
+	OAuthProviderGoogle = "google"
+
+	// OAuthProviderMicrosoft signs in with a Microsoft work, school or personal account.
+	OAuthProviderMicrosoft = "microsoft"
+
+	// OIDCScope requests an ID token with the user's email address and name.
+	OIDCScope = "openid email profile"
+
+	// GoogleIssuer is the issuer of Google ID tokens.
+	GoogleIssuer = "https://accounts.google.com"
+
+	// GoogleJWKSURL is the URL of the keys Google signs ID tokens with.
+	GoogleJWKSURL = "https://www.googleapis.com/oauth2/v3/certs"
+
+	// MicrosoftIssuerFormat is the issuer of the ID tokens of a Microsoft tenant, by tenant ID.
+	MicrosoftIssuerFormat = "https://login.microsoftonline.com/%s/v2.0"
+
+	// MicrosoftJWKSURLFormat is the URL of the keys a Microsoft tenant signs ID tokens with.
+	MicrosoftJWKSURLFormat = "https://login.microsoftonline.com/%s/discovery/v2.0/keys"
+
+	// OAuthCallbackPathFormat is the path of the callback a provider sends users back to, by provider.
+	OAuthCallbackPathFormat = "/api/auth/oauth/%s/callback"
+
+	// OAuthUsernameMaxBase is the length of the username taken from an email address,
+	// leaving room for the suffix added when it is taken.
+	OAuthUsernameMaxBase = 40
+
+	// OAuthUsernameAttempts is the number of usernames tried for a user created on sign-in.
+	OAuthUsernameAttempts = 5
+)
+
+// Export Destinations define where redacted documents are delivered to.
+const (
+	// ExportDestinationS3 delivers to an Amazon S3 bucket, or an S3-compatible store.
+	ExportDestinationS3 = "s3"
+
+	// ExportDestinationSFTP delivers to a directory on an SFTP server.
+	ExportDestinationSFTP = "sftp"
+
+	// ExportDestinationSharePoint delivers to a SharePoint folder through the user's SharePoint connection.
+	ExportDestinationSharePoint = "sharepoint"
+
+	// ExportDeliveryPending marks a delivery that is in progress.
+	ExportDeliveryPending = "pending"
+
+	// ExportDeliveryDelivered marks a delivery that succeeded.
+	ExportDeliveryDelivered = "delivered"
+
+	// ExportDeliveryFailed marks a delivery that failed; it is retried until it has used up its attempts.
+	ExportDeliveryFailed = "failed"
+
+	// DefaultExportDeliveryMaxAttempts is the default number of attempts of a delivery, retries included.
+	DefaultExportDeliveryMaxAttempts = 3
+
+	// ExportDeliveryRetryBatchSize is the maximum number of failed deliveries retried per maintenance run.
+	ExportDeliveryRetryBatchSize = 100
+
+	// DefaultSFTPPort is the port SFTP servers listen on unless configured otherwise.
+	DefaultSFTPPort = 22
+
+	// SFTPChunkSize is the size of the writes sent to SFTP servers, the largest all servers must accept.
+	SFTPChunkSize = 32 * 1024
+
+	// S3EndpointFormat is the virtual-hosted endpoint of an Amazon S3 bucket in a region.
+	S3EndpointFormat = "https://%s.s3.%s.amazonaws.com"
+)
+
+// Webhook Defaults define the events users can subscribe to and the states of their deliveries.
+const (
+	// WebhookEventDocumentProcessed is sent when a processed document is saved.
+	WebhookEventDocumentProcessed = "document.processed"
+
+	// WebhookEventEntityDetected is sent when detected entities are added to a document.
+	WebhookEventEntityDetected = "entity.detected"
+
+	// WebhookEventAPIKeyCreated is sent when an API key is created.
+	WebhookEventAPIKeyCreated = "apikey.created"
+
+	// WebhookEventSLOAlert is sent to administrators when a service-level objective alert
+	// fires or resolves.
+	WebhookEventSLOAlert = "slo.alert"
+
+	// WebhookDeliveryPending marks a delivery waiting for its next attempt.
+	WebhookDeliveryPending = "pending"
+
+	// WebhookDeliveryDelivered marks a delivery the webhook accepted with a 2xx response.
+	WebhookDeliveryDelivered = "delivered"
+
+	// WebhookDeliveryDead marks a delivery that used up its attempts; it is kept until redelivered
+	// or past its retention.
+	WebhookDeliveryDead = "dead"
+
+	// WebhookSignatureVersion is the scheme of the signature in the X-HideMe-Signature header.
+	WebhookSignatureVersion = "v1"
+
+	// DefaultWebhookMaxAttempts is the default number of attempts of a delivery, retries included.
+	DefaultWebhookMaxAttempts = 10
+
+	// WebhookSecretMinLength is the minimum length of the secret deliveries are signed with.
+	WebhookSecretMinLength = 16
+
+	// MaxWebhooksPerUser is the maximum number of webhooks a user can register.
+	MaxWebhooksPerUser = 20
+
+	// WebhookDeliveryListLimit is the number of most recent deliveries listed for a webhook.
+	WebhookDeliveryListLimit = 100
+
+	// WebhookErrorMaxLength is the length the error of a failed attempt is cut to.
+	WebhookErrorMaxLength = 500
+)
+
+// Settings Sync Defaults define how concurrent changes of settings are resolved.
+const (
+	// MaxSettingsUpdateAttempts is the number of times an update without a version is
+	// reapplied when the settings change between reading and writing them.
+	MaxSettingsUpdateAttempts = 3
+)
+
+// Search Pattern Defaults define how search patterns are tried out before they are saved.
+const (
+	// SearchPatternTestMaxMatches is the number of matches reported when a pattern is tried out.
+	SearchPatternTestMaxMatches = 100
+)
+
+// SLO Defaults define the service-level objectives of the API and how their error
+// budgets are watched.
+const (
+	// DefaultSLOAvailability is the default share of requests that must not fail with a
+	// server error.
+	DefaultSLOAvailability = 0.995
+
+	// DefaultSLOLatencyTarget is the default share of requests that must be answered within
+	// the latency threshold.
+	DefaultSLOLatencyTarget = 0.99
+
+	// DefaultSLOMinRequests is the default number of requests the long window of a rule must
+	// hold before it can fire, so that a few failures on an idle server do not page anyone.
+	DefaultSLOMinRequests = 50
+
+	// SLOFastBurnRate is the burn rate of the critical rule: at it, a 30-day error budget is
+	// spent in about two days.
+	SLOFastBurnRate = 14.4
+
+	// SLOSlowBurnRate is the burn rate of the warning rule: at it, a 30-day error budget is
+	// spent in five days.
+	SLOSlowBurnRate = 6.0
+
+	// SLOObjectiveAvailability is the objective on the share of requests without server errors.
+	SLOObjectiveAvailability = "availability"
+
+	// SLOObjectiveLatency is the objective on the share of requests answered in time.
+	SLOObjectiveLatency = "latency"
+
+	// SLOSeverityCritical marks an alert of the fast-burn rule.
+	SLOSeverityCritical = "critical"
+
+	// SLOSeverityWarning marks an alert of the slow-burn rule.
+	SLOSeverityWarning = "warning"
+
+	// SLOAlertFiring marks an alert whose rule started burning the error budget.
+	SLOAlertFiring = "firing"
+
+	// SLOAlertResolved marks an alert whose rule stopped burning the error budget.
+	SLOAlertResolved = "resolved"
+
+	// SLOAlertMaxRecipients is the maximum number of administrators an alert is sent to.
+	SLOAlertMaxRecipients = 100
+)
+
+// Document Sharing Defaults define the access a document can be shared with.
+const (
+	// SharePermissionRead lets the user view the document, its content and redactions.
+	SharePermissionRead = "read"
+
+	// SharePermissionRedact lets the user change the redaction schema as well.
+	SharePermissionRedact = "redact"
+)
+
+// Entity Hash Defaults define how detected entity values are hashed for analytics.
+const (
+	// EntityHashKeyContext derives the hashing key of a tenant from its data-encryption key,
+	// so values cannot be linked across tenants and shredding the key unlinks them.
+	EntityHashKeyContext = "hideme-entity-hash-v1"
+
+	// EntityFingerprintLength is the number of hex characters of a hash shown in reports.
+	EntityFingerprintLength = 12
+
+	// DefaultDuplicateLeakMinDocuments is the number of documents a value must be detected
+	// in to count as a repeated leak.
+	DefaultDuplicateLeakMinDocuments = 2
+
+	// DuplicateLeakReportLimit is the maximum number of repeated values in a report.
+	DuplicateLeakReportLimit = 100
+)
+
+// Leak Alert Defaults define when users are alerted about values leaking in many documents.
+const (
+	// DefaultLeakAlertThreshold is the default number of documents a value must be detected
+	// in, within the alert window, for an alert to be raised when it is detected in one more.
+	DefaultLeakAlertThreshold = 3
+
+	// DefaultLeakAlertWindowDays is the default number of days detections are counted over;
+	// a value is alerted about at most once per window.
+	DefaultLeakAlertWindowDays = 30
+)
+
+// Document Storage Defaults define where and how the PDF content of uploaded documents is stored.
+const (
+	// DocumentStorageLocal stores document content in a directory on the local disk.
+	DocumentStorageLocal = "local"
+
+	// DocumentStorageS3 stores document content in an Amazon S3 bucket, or an S3-compatible store.
+	DocumentStorageS3 = "s3"
+
+	// DefaultDocumentStoragePath is the default directory of the local document storage.
+	DefaultDocumentStoragePath = "data/documents"
+
+	// DocumentContentFormField is the multipart form field holding the PDF of an upload.
+	DocumentContentFormField = "file"
+
+	// DocumentMetadataFormField is the multipart form field holding the JSON metadata of an upload.
+	DocumentMetadataFormField = "metadata"
+
+	// DocumentUploadMemoryLimit is the part of a multipart upload kept in memory; the rest
+	// is buffered in temporary files while the form is parsed.
+	DocumentUploadMemoryLimit = 1 << 20
+
+	// DocumentContentBatchSize is the maximum number of orphaned contents deleted per maintenance run.
+	DocumentContentBatchSize = 100
+
+	// DocumentContentSegmentSize is the size of the segments document content is encrypted in.
+	// Downloads read and decrypt one segment at a time, so this is also what a download
+	// holds in memory.
+	DocumentContentSegmentSize = 64 * 1024
+
+	// PDFSignature is the start of every PDF file.
+	PDFSignature = "%PDF-"
+)
+
+// Rule Effectiveness defines how the detections produced by ban list words and search patterns are tracked.
+const (
+	// RuleTypeBanListWord identifies a word on the user's ban list.
+	RuleTypeBanListWord = "ban_list_word"
+
+	// RuleTypeSearchPattern identifies one of the user's search patterns.
+	RuleTypeSearchPattern = "search_pattern"
+)
+
+// Maintenance Tasks name the periodic maintenance tasks that administrators can also run on demand.
+const (
+	// MaintenanceTaskSessions deletes expired sessions.
+	MaintenanceTaskSessions = "expired_sessions"
+
+	// MaintenanceTaskIdleSessions deletes sessions unused for longer than the idle timeout.
+	MaintenanceTaskIdleSessions = "idle_sessions"
+
+	// MaintenanceTaskAPIKeys deletes expired API keys.
+	MaintenanceTaskAPIKeys = "expired_api_keys"
+
+	// MaintenanceTaskGDPRLogs rotates and removes GDPR logs past their retention.
+	MaintenanceTaskGDPRLogs = "gdpr_logs"
+
+	// MaintenanceTaskUsageRollup persists tenant usage for the billing export.
+	MaintenanceTaskUsageRollup = "usage_rollup"
+
+	// MaintenanceTaskAdminActions expires admin actions that were not approved in time.
+	MaintenanceTaskAdminActions = "expired_admin_actions"
+
+	// MaintenanceTaskIndexAdvisor looks for missing indexes on the largest tables.
+	MaintenanceTaskIndexAdvisor = "index_advisor"
+
+	// MaintenanceTaskReencryption moves documents encrypted with the master key to tenant keys.
+	MaintenanceTaskReencryption = "document_reencryption"
+
+	// MaintenanceTaskSchemaNormalization converts legacy redaction schemas to page-relative coordinates.
+	MaintenanceTaskSchemaNormalization = "schema_normalization"
+
+	// MaintenanceTaskEntitySummaries computes the entity counts and types of documents stored before document search.
+	MaintenanceTaskEntitySummaries = "entity_summaries"
+
+	// MaintenanceTaskDocumentRetention deletes documents whose retention has passed.
+	MaintenanceTaskDocumentRetention = "document_retention"
+
+	// MaintenanceTaskBenchmarks publishes the anonymized cross-tenant benchmarks.
+	MaintenanceTaskBenchmarks = "benchmarks"
+
+	// MaintenanceTaskReports sends the scheduled reports that are due.
+	MaintenanceTaskReports = "scheduled_reports"
+
+	// MaintenanceTaskUptime prunes status page uptime rollups.
+	MaintenanceTaskUptime = "status_uptime"
+
+	// MaintenanceTaskSettingsConsistency repairs users without settings or ban list and orphaned settings rows.
+	MaintenanceTaskSettingsConsistency = "settings_consistency"
+
+	// MaintenanceTaskExportDeliveries retries failed deliveries to export destinations.
+	MaintenanceTaskExportDeliveries = "export_deliveries"
+
+	// MaintenanceTaskDocumentArchival moves documents untouched for too long to cold storage.
+	MaintenanceTaskDocumentArchival = "document_archival"
+
+	// MaintenanceTaskUserDataExports deletes user data export jobs past their retention.
+	MaintenanceTaskUserDataExports = "user_data_exports"
+
+	// MaintenanceTaskAccountErasures erases the accounts whose grace period has passed.
+	MaintenanceTaskAccountErasures = "account_erasures"
+
+	// MaintenanceTaskDocumentContents deletes the stored content of deleted documents.
+	MaintenanceTaskDocumentContents = "document_contents"
+
+	// MaintenanceTaskJobs deletes background jobs past their retention.
+	MaintenanceTaskJobs = "jobs"
+
+	// MaintenanceTaskWebhookDeliveries deletes webhook deliveries past their retention.
+	MaintenanceTaskWebhookDeliveries = "webhook_deliveries"
+
+	// MaintenanceTaskStaleDocuments notifies the owners of stale documents and archives or deletes them after the notice.
+	MaintenanceTaskStaleDocuments = "stale_documents"
+)
+
+// Permission Introspection Defaults define the names reported by GET /api/users/me/permissions,
+// which tells the frontend what the caller may do so it can render its affordances.
+const (
+	// AuthMethodSession is a caller authenticated with an access token from a login.
+	AuthMethodSession = "session"
+
+	// AuthMethodAPIKey is a caller authenticated with an API key.
+	AuthMethodAPIKey = "api_key"
+
+	// CapabilityAdmin allows calling the admin routes.
+	CapabilityAdmin = "admin"
+
+	// CapabilityViewDetectedText allows reading detected entity texts and redaction schemas, which auditors get redacted.
+	CapabilityViewDetectedText = "view_detected_text"
+
+	// CapabilityViewEmailAddresses allows reading the email addresses of users, which support staff get redacted.
+	CapabilityViewEmailAddresses = "view_email_addresses"
+
+	// FeatureOAuthGoogle is signing in with Google.
+	FeatureOAuthGoogle = "oauth_google"
+
+	// FeatureOAuthMicrosoft is signing in with Microsoft.
+	FeatureOAuthMicrosoft = "oauth_microsoft"
+
+	// FeatureDriveGoogle is importing documents from Google Drive.
+	FeatureDriveGoogle = "drive_google"
+
+	// FeatureDriveMicrosoft is importing documents from OneDrive and SharePoint.
+	FeatureDriveMicrosoft = "drive_microsoft"
+
+	// FeatureDocumentArchive is moving old documents to cold storage, from which they have to be restored.
+	FeatureDocumentArchive = "document_archive"
+
+	// FeaturePIIScreening is checking free-text fields for personal data.
+	FeaturePIIScreening = "pii_screening"
+)
+
+// Registration Domain Defaults define how administrators prove they own the email domains
+// registration is limited to. A domain is verified by publishing its verification token in a
+// DNS TXT record at RegistrationDomainRecordPrefix followed by the domain.
+const (
+	// RegistrationDomainRecordPrefix is the label under which the verification TXT record is published.
+	RegistrationDomainRecordPrefix = "_hideme-verification."
+
+	// RegistrationDomainTokenPrefix starts the value of the verification TXT record.
+	RegistrationDomainTokenPrefix = "hideme-domain-verification="
+
+	// RegistrationDomainTokenBytes is the number of random bytes in a verification token.
+	RegistrationDomainTokenBytes = 16
+
+	// RegistrationDomainMaxLength is the maximum length of a domain name.
+	RegistrationDomainMaxLength = 253
+)
+
+// Document Transfer Defaults define how documents are transferred between users.
+const (
+	// DocumentTransferBatchSize is the number of documents listed at a time when all
+	// documents of a user are transferred.
+	DocumentTransferBatchSize = 500
+
+	// DocumentTransferListLimit is the number of transfers listed in the audit trail.
+	DocumentTransferListLimit = 100
+)
+
+// Auditor Grant Defaults define the read-only access grants of external auditors.
+const (
+	// AuditorGrantMaxDays is the longest an auditor access grant can be valid.
+	AuditorGrantMaxDays = 90
+
+	// AuditorGrantListLimit is the number of auditor access grants listed.
+	AuditorGrantListLimit = 100
+
+	// AuditorAccessLogLimit is the number of requests listed in the access log of a grant.
+	AuditorAccessLogLimit = 500
+
+	// AuditorDocumentBatchSize is the number of documents of a covered user listed at a time.
+	AuditorDocumentBatchSize = 500
+
+	// AuditorActionListDocuments is the logged action of an auditor listing the covered documents.
+	AuditorActionListDocuments = "list_documents"
+
+	// AuditorActionViewDocument is the logged action of an auditor reading a document.
+	AuditorActionViewDocument = "view_document"
+
+	// AuditorActionExportDocument is the logged action of an auditor exporting a document.
+	AuditorActionExportDocument = "export_document"
+)
+
+// Redaction Defaults define how the server applies redaction schemas to PDFs.
+const (
+	// RedactionMethodBlackout covers a redaction with a black box.
+	RedactionMethodBlackout = "blackout"
+
+	// RedactionMethodMask covers a redaction with a black box labelled with its entity type.
+	RedactionMethodMask = "mask"
+
+	// RedactionMethodReplace covers a redaction with a white box showing a replacement value.
+	RedactionMethodReplace = "replace"
+
+	// RedactionLabelMinFontSize is the smallest font size in points of the label written over
+	// a masked or replaced redaction; labels that do not fit are left out.
+	RedactionLabelMinFontSize = 4.0
+
+	// RedactedFilenamePrefix is the prefix for the file names of redacted PDFs.
+	RedactedFilenamePrefix = "redacted-"
+)
+
+// Detection Catalog Defaults define the bundles administrators promote the detection
+// methods and entity-type taxonomy between environments with.
+const (
+	// DetectionCatalogBundleVersion is the version of the bundle format exported and imported.
+	DetectionCatalogBundleVersion = 1
+
+	// DetectionCatalogChangeCreate marks an item of a bundle that doesn't exist in the environment yet.
+	DetectionCatalogChangeCreate = "create"
+
+	// DetectionCatalogChangeUpdate marks an item of a bundle that exists in the environment with other values.
+	DetectionCatalogChangeUpdate = "update"
+
+	// DetectionCatalogChangeUnchanged marks an item of a bundle that exists in the environment as is.
+	DetectionCatalogChangeUnchanged = "unchanged"
+
+	// DetectionCatalogChangeKept marks an item of the environment that is not in the bundle; imports never delete.
+	DetectionCatalogChangeKept = "kept"
+)
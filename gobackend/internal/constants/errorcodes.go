@@ -39,6 +39,18 @@ const (
 
 	// ErrorInvalidToken indicates that an authentication token is malformed or invalid.
 	ErrorInvalidToken = "invalid token"
+
+	// ErrorQuotaExceeded indicates that a usage quota has been used up.
+	ErrorQuotaExceeded = "quota exceeded"
+
+	// ErrorUploadLimit indicates that an upload would go over an upload limit.
+	ErrorUploadLimit = "upload limit exceeded"
+
+	// ErrorUploadIntegrity indicates that an uploaded file does not match what the client declared.
+	ErrorUploadIntegrity = "upload integrity check failed"
+
+	// ErrorRequestCanceled indicates that the client went away before the request completed.
+	ErrorRequestCanceled = "request canceled"
 )
 
 // User-Facing Error Messages define standardized messages that can be safely presented to users.
@@ -83,9 +95,6 @@ const (
 	// MsgMethodNotAllowed indicates that the HTTP method is not supported for the endpoint.
 	MsgMethodNotAllowed = "This method is not allowed for this resource"
 
-	// MsgUserDeleted confirms successful account deletion.
-	MsgUserDeleted = "Account successfully deleted"
-
 	// MsgPasswordChanged confirms successful password change.
 	MsgPasswordChanged = "Password successfully changed"
 
@@ -95,6 +104,24 @@ const (
 	// MsgAPIKeyRevoked confirms successful API key revocation.
 	MsgAPIKeyRevoked = "API key successfully revoked"
 
+	// MsgAPIKeyAlreadyRotated indicates that an API key was already replaced and cannot be rotated again.
+	MsgAPIKeyAlreadyRotated = "API key has already been rotated; rotate its replacement instead"
+
+	// MsgAPIKeyDisabled indicates that an administrator disabled an API key.
+	MsgAPIKeyDisabled = "API key has been disabled by an administrator"
+
+	// MsgAPIKeyScopeMissing indicates that an API key lacks the scope a route requires.
+	MsgAPIKeyScopeMissing = "API key does not have the scope required for this resource"
+
+	// MsgSessionIdle indicates that a session was invalidated after going unused for too long.
+	MsgSessionIdle = "Session timed out due to inactivity; please log in again"
+
+	// MsgRotationDeadlinePast indicates that a rotation campaign was started with a deadline that has passed.
+	MsgRotationDeadlinePast = "The rotation deadline must be in the future"
+
+	// MsgAPIKeyStatusInvalid indicates that API keys were filtered by an unknown status.
+	MsgAPIKeyStatusInvalid = "Status must be one of active, pending_rotation, rotated, expired or disabled"
+
 	// MsgLogoutSuccess confirms successful logout.
 	MsgLogoutSuccess = "Successfully logged out"
 
@@ -103,6 +130,282 @@ const (
 
 	// MsgSettingsImported confirms successful settings import.
 	MsgSettingsImported = "Settings imported successfully"
+
+	// MsgCaptchaRequired indicates that a CAPTCHA must be solved before continuing.
+	MsgCaptchaRequired = "Please complete the CAPTCHA to continue"
+
+	// MsgEmailVerificationRequired indicates that the account's email address must be verified first.
+	MsgEmailVerificationRequired = "Please verify your email address to continue"
+
+	// MsgManualReviewRequired indicates that the account is waiting for manual review.
+	MsgManualReviewRequired = "Your account is pending review"
+
+	// MsgQuotaExceeded indicates that the user's detection quota has been used up.
+	MsgQuotaExceeded = "Detection quota exceeded. Please try again later."
+
+	// MsgUploadLimitExceeded indicates that an upload was refused before processing because it would go over a limit.
+	MsgUploadLimitExceeded = "Upload exceeds the %s limit"
+
+	// MsgUploadIntegrityFailed indicates that an uploaded file was corrupted or truncated on its way to the server.
+	MsgUploadIntegrityFailed = "Uploaded file does not match its declared %s"
+
+	// MsgUploadIntegrityRequired indicates that an uploaded file was sent without its SHA-256 and size.
+	MsgUploadIntegrityRequired = "The SHA-256 and size in bytes of the file are required"
+
+	// MsgAdminSearchQueryLength indicates that a search term is too short or too long.
+	MsgAdminSearchQueryLength = "Search term must be between 3 and 255 characters"
+
+	// MsgAPIKeyBackoff indicates that the client sent too many invalid API keys.
+	MsgAPIKeyBackoff = "Too many invalid API keys. Please try again later."
+
+	// MsgEmailVerified confirms successful email verification.
+	MsgEmailVerified = "Email address successfully verified"
+
+	// MsgServiceOverloaded indicates that a request was shed to keep the service responsive.
+	MsgServiceOverloaded = "The service is under heavy load. Please try again later."
+
+	// MsgShadowTrafficDisabled indicates that no shadow deployment is configured.
+	MsgShadowTrafficDisabled = "Shadow traffic mirroring is not enabled"
+
+	// MsgRequestCanceled indicates that a request was abandoned because the client disconnected.
+	MsgRequestCanceled = "The request was canceled"
+
+	// MsgPersonalDataInField indicates that a free-text field was rejected for containing personal data.
+	MsgPersonalDataInField = "Free-text fields must not contain personal data"
+
+	// MsgInvalidLanguage indicates that a document language is not an ISO 639-1 language code.
+	MsgInvalidLanguage = "Language must be an ISO 639-1 language code such as en or nb"
+
+	// MsgInvalidSearchDateRange indicates that a document search ends before it starts.
+	MsgInvalidSearchDateRange = "uploaded_to must not be before uploaded_from"
+
+	// MsgInvalidSearchEntityRange indicates that a document search has a maximum entity count below its minimum.
+	MsgInvalidSearchEntityRange = "max_entities must not be below min_entities"
+
+	// MsgClassificationRuleIncomplete indicates that a classification rule lacks a condition or an action.
+	MsgClassificationRuleIncomplete = "A classification rule needs at least one condition and at least one of tags, folder or retention_days"
+
+	// MsgInvalidFilenamePattern indicates that a classification rule has a malformed filename pattern.
+	MsgInvalidFilenamePattern = "Filename pattern must be a valid glob pattern such as *.pdf or invoice_*"
+
+	// MsgBenchmarkConsentRequired indicates that a tenant asked for benchmarks without sharing its own statistics.
+	MsgBenchmarkConsentRequired = "Benchmarks are only available to tenants that share their own statistics"
+
+	// MsgAnnouncementWindowInvalid indicates that an announcement would end before it starts.
+	MsgAnnouncementWindowInvalid = "An announcement must end after it starts"
+
+	// MsgRegistrationDomainNotAllowed indicates that a user signed up with an email address outside the allowed domains.
+	MsgRegistrationDomainNotAllowed = "Registration is limited to email addresses at the organization's domains"
+
+	// MsgRegistrationDomainInvalid indicates that an administrator entered something that is not a domain name.
+	MsgRegistrationDomainInvalid = "Must be a domain name such as example.com"
+
+	// MsgRegistrationDomainUnverified indicates that the verification record of a domain was not found.
+	MsgRegistrationDomainUnverified = "The verification TXT record was not found; DNS changes can take a while to be visible"
+
+	// MsgMaintenanceTaskNotBatched indicates that a batch size or dry run was set for a maintenance task that ignores them.
+	MsgMaintenanceTaskNotBatched = "This task does not support a batch size or dry runs"
+
+	// MsgRateLimitGroupUnknown indicates that a burst was granted in a route group that is not rate limited.
+	MsgRateLimitGroupUnknown = "Unknown rate limit group"
+
+	// MsgInvalidExportFormat indicates that a document export was requested in an unsupported format.
+	MsgInvalidExportFormat = "Format must be json or zip"
+
+	// MsgUserDataExportActive indicates that a user requested a data export while another one is still running.
+	MsgUserDataExportActive = "A data export is already in progress"
+
+	// MsgUserDataExportNotReady indicates that a data export was downloaded before it completed.
+	MsgUserDataExportNotReady = "The data export has not completed"
+
+	// MsgJobNotReady indicates that the result of a job was requested before the job completed.
+	MsgJobNotReady = "The job has not completed"
+
+	// MsgTooManyNotificationStreams indicates that a user opened more event streams than allowed.
+	MsgTooManyNotificationStreams = "Too many open event streams"
+
+	// MsgServerShuttingDown indicates that a stream was opened while the server shuts down.
+	MsgServerShuttingDown = "The server is shutting down"
+
+	// MsgProjectionRebuilding indicates that a derived table is already being rebuilt.
+	MsgProjectionRebuilding = "The projection is already being rebuilt"
+
+	// MsgAccountErasureScheduled confirms that an account will be erased once the grace period has passed.
+	MsgAccountErasureScheduled = "Account scheduled for deletion"
+
+	// MsgAccountErasureActive indicates that a user requested the erasure of an account already scheduled for erasure.
+	MsgAccountErasureActive = "The account is already scheduled for deletion"
+
+	// MsgAccountErasureStarted indicates that an erasure was cancelled after the grace period, once it had started.
+	MsgAccountErasureStarted = "The account deletion has already started and can no longer be cancelled"
+
+	// MsgDocumentArchived indicates that a document in cold storage was read before being restored.
+	MsgDocumentArchived = "Document is archived; restore it from the archive before reading it"
+
+	// MsgDocumentNotArchived indicates that a document to restore is not in cold storage.
+	MsgDocumentNotArchived = "Document is not archived"
+
+	// MsgDocumentContentNotFound indicates that a document was uploaded without its PDF content.
+	MsgDocumentContentNotFound = "Document has no stored content"
+
+	// MsgDocumentContentNotPDF indicates that the uploaded file of a document is not a PDF.
+	MsgDocumentContentNotPDF = "File must be a PDF document"
+
+	// MsgDocumentNotRedactable indicates that the PDF of a document could not be redacted.
+	MsgDocumentNotRedactable = "The PDF cannot be redacted; it is damaged, encrypted or uses an unsupported compression"
+
+	// MsgInvalidRedactionMethod indicates that a redaction was requested with an unknown method.
+	MsgInvalidRedactionMethod = "Method must be blackout, mask or replace"
+
+	// MsgShareWithSelf indicates that a user tried to share a document with themselves.
+	MsgShareWithSelf = "Documents cannot be shared with their owner"
+
+	// MsgShareUserNotFound indicates that no account has the email a document was shared with.
+	MsgShareUserNotFound = "No account with this email address"
+
+	// MsgDocumentReadOnly indicates that a document shared for reading only was changed.
+	MsgDocumentReadOnly = "Document is shared with you for reading only"
+
+	// MsgDocumentShareNotFound indicates that a document is not shared with the given user.
+	MsgDocumentShareNotFound = "Document is not shared with this user"
+
+	// MsgEntityStatusInvalid indicates that a detected entity was given an unknown review status.
+	MsgEntityStatusInvalid = "Status must be pending, accepted or rejected"
+
+	// MsgTransferToSelf indicates that documents were transferred to the user who owns them.
+	MsgTransferToSelf = "Documents cannot be transferred to their owner"
+
+	// MsgTransferUserNotFound indicates that a user named in a document transfer doesn't exist.
+	MsgTransferUserNotFound = "User not found"
+
+	// MsgAuditorGrantEmpty indicates that an auditor access grant covers neither documents nor users.
+	MsgAuditorGrantEmpty = "An auditor access grant needs at least one document or user"
+
+	// MsgAuditorTokenRequired indicates that an auditor request has no auditor token.
+	MsgAuditorTokenRequired = "Auditor token required"
+
+	// MsgAuditorGrantInvalid indicates that an auditor token is unknown, expired or revoked.
+	MsgAuditorGrantInvalid = "Auditor access grant is invalid, expired or revoked"
+
+	// MsgAuditorGrantNotFound indicates that an auditor access grant doesn't exist.
+	MsgAuditorGrantNotFound = "Auditor access grant not found"
+
+	// MsgUserLookupIdentifier indicates that an admin user lookup did not name exactly one identifier.
+	MsgUserLookupIdentifier = "Exactly one of id, username or email is required"
+
+	// MsgAccountDisabled indicates that an administrator disabled the account.
+	MsgAccountDisabled = "This account has been disabled by an administrator"
+
+	// MsgOwnAccountChange indicates that an administrator tried to disable their own account or change its role.
+	MsgOwnAccountChange = "Administrators cannot disable their own account or change its role"
+
+	// MsgAdminPromotionRequiresApproval indicates that the admin role was set directly instead of through an approved admin action.
+	MsgAdminPromotionRequiresApproval = "The admin role can only be granted through an approved admin_promotion action"
+
+	// MsgUserStatusInvalid indicates that users were filtered by an unknown status.
+	MsgUserStatusInvalid = "Status must be active or disabled"
+
+	// MsgInvalidRole indicates that users were filtered by an unknown role.
+	MsgInvalidRole = "Role must be user, admin, auditor or support"
+
+	// MsgDriveProviderUnknown indicates that a cloud drive provider is not supported.
+	MsgDriveProviderUnknown = "Provider must be google_drive, onedrive or sharepoint"
+
+	// MsgDriveProviderNotConfigured indicates that a cloud drive provider has no OAuth client configured.
+	MsgDriveProviderNotConfigured = "This cloud drive is not available on this server"
+
+	// MsgDriveNotConnected indicates that a user has not connected a cloud drive, or its authorization was revoked.
+	MsgDriveNotConnected = "Connect this cloud drive first"
+
+	// MsgDriveStateInvalid indicates that a cloud drive authorization has a forged or expired state, or one issued to another user.
+	MsgDriveStateInvalid = "Authorization expired or is invalid, please connect the cloud drive again"
+
+	// MsgDriveFileTooLarge indicates that a cloud drive file exceeds the import size limit.
+	MsgDriveFileTooLarge = "File is too large to import"
+
+	// MsgDriveFolderNotFile indicates that a folder was requested for import.
+	MsgDriveFolderNotFile = "Folders cannot be imported, select a file"
+
+	// MsgDriveUploadUnsupported indicates that a cloud drive does not accept uploads.
+	MsgDriveUploadUnsupported = "Files cannot be uploaded to this cloud drive"
+
+	// MsgOAuthProviderUnknown indicates that a sign-in provider is not supported.
+	MsgOAuthProviderUnknown = "Provider must be google or microsoft"
+
+	// MsgOAuthProviderNotConfigured indicates that a sign-in provider has no OAuth client configured.
+	MsgOAuthProviderNotConfigured = "Sign-in with this provider is not available on this server"
+
+	// MsgOAuthStateInvalid indicates that a sign-in has a forged or expired state, or was started in another browser.
+	MsgOAuthStateInvalid = "Sign-in expired or is invalid, please try again"
+
+	// MsgOAuthDenied indicates that the user cancelled signing in at the provider, or the provider refused it.
+	MsgOAuthDenied = "Sign-in was cancelled or refused by the provider"
+
+	// MsgOAuthEmailUnverified indicates that a provider did not verify the email address of an account that already exists.
+	MsgOAuthEmailUnverified = "An account with this email address already exists, but the provider did not verify the address"
+
+	// MsgOAuthEmailMissing indicates that a provider returned no verified email address to create an account with.
+	MsgOAuthEmailMissing = "The provider did not return a verified email address"
+
+	// MsgExportDestinationType indicates that an export destination has an unsupported type.
+	MsgExportDestinationType = "Type must be s3, sftp or sharepoint"
+
+	// MsgExportDestinationPrivate indicates that an export destination resolves to a private network address.
+	MsgExportDestinationPrivate = "Export destinations on private networks are not allowed"
+
+	// MsgExportDeliveryFailed indicates that a document could not be delivered to an export destination.
+	MsgExportDeliveryFailed = "Document could not be delivered to the export destination"
+
+	// MsgWebhookURLInvalid indicates that a webhook URL is not an absolute HTTPS URL.
+	MsgWebhookURLInvalid = "URL must be an absolute https URL"
+
+	// MsgWebhookEventInvalid indicates that a webhook subscribes to an unknown event.
+	MsgWebhookEventInvalid = "Events must be document.processed, entity.detected, apikey.created or slo.alert"
+
+	// MsgWebhookSecretTooShort indicates that the secret of a webhook is too short to sign deliveries with.
+	MsgWebhookSecretTooShort = "Secret must be at least 16 characters"
+
+	// MsgTooManyWebhooks indicates that a user registered the maximum number of webhooks.
+	MsgTooManyWebhooks = "The maximum number of webhooks is registered"
+
+	// MsgWebhookDeliveryNotDead indicates that a delivery was redelivered before it was dead-lettered.
+	MsgWebhookDeliveryNotDead = "Only deliveries that used up their attempts can be redelivered"
+
+	// MsgSettingsVersionConflict indicates that settings were updated since the version an update is based on.
+	MsgSettingsVersionConflict = "Settings were changed on another device since this version"
+
+	// MsgSettingsMergeConflict indicates that merged changes change fields that were changed to other values since.
+	MsgSettingsMergeConflict = "Settings changed here were changed to other values on another device"
+
+	// MsgSettingsBaseVersionInvalid indicates that the base version of a merge is newer than the current settings.
+	MsgSettingsBaseVersionInvalid = "Base version is newer than the current settings"
+
+	// MsgBanListWordEmpty indicates that an empty word was added to or removed from a ban list.
+	MsgBanListWordEmpty = "Word must not be empty"
+
+	// MsgBanListWordNotFound indicates that a word removed from a ban list is not on it.
+	MsgBanListWordNotFound = "Word is not on the ban list"
+
+	// MsgSearchPatternInvalid indicates that the text of a search pattern is not a valid regular expression.
+	MsgSearchPatternInvalid = "Pattern text must be a valid regular expression"
+
+	// MsgSearchPatternUnsafe indicates that a search pattern nests repetitions, which can take exponentially long to match.
+	MsgSearchPatternUnsafe = "Pattern text must not nest repetitions such as (a+)+, which can take exponentially long to match"
+
+	// MsgSearchPatternNotTestable indicates that an AI search pattern was tried out, which only the detection service can match.
+	MsgSearchPatternNotTestable = "AI search patterns are matched by the detection service and cannot be tested"
+
+	// MsgSearchPatternCatalogNotFound indicates that a pattern imported from the catalog is not in it.
+	MsgSearchPatternCatalogNotFound = "Pattern is not in the catalog"
+
+	// MsgDetectionCatalogVersionUnsupported indicates that a detection catalog bundle was exported by an incompatible version.
+	MsgDetectionCatalogVersionUnsupported = "Unsupported detection catalog bundle version"
+
+	// MsgDetectionCatalogDuplicate indicates that a detection catalog bundle lists a method or entity type twice.
+	MsgDetectionCatalogDuplicate = "Listed more than once in the bundle"
+
+	// MsgDetectionCatalogMethodUnknown indicates that an entity type in a bundle references a detection method that exists nowhere.
+	MsgDetectionCatalogMethodUnknown = "Detection method is neither in the bundle nor in this environment"
 )
 
 // Database Error Types define constants for recognizing and handling database-specific errors.
@@ -130,6 +433,18 @@ const (
 	// LogCategoryAuth is the log category for authentication-related events.
 	LogCategoryAuth = "auth"
 
+	// LogCategoryAdmin is the log category for administrative actions.
+	LogCategoryAdmin = "admin"
+
+	// LogCategoryDocument is the log category for reads of document content.
+	LogCategoryDocument = "document"
+
+	// LogCategorySLO is the log category for service-level objective alerts.
+	LogCategorySLO = "slo"
+
+	// LogChannelDBDiagnostics is the log channel for slow queries and their plans.
+	LogChannelDBDiagnostics = "db_diagnostics"
+
 	// LogEventLogin is the log event type for user login.
 	LogEventLogin = "login"
 
@@ -142,6 +457,9 @@ const (
 	// LogEventUserUpdate is the log event type for user profile updates.
 	LogEventUserUpdate = "user_update"
 
+	// LogEventPIIScreening is the log event type for personal data found in free-text fields.
+	LogEventPIIScreening = "pii_screening"
+
 	// LogRedactedValue is used to replace sensitive values in logs.
 	LogRedactedValue = "[REDACTED]"
 )
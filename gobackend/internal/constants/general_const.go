@@ -14,6 +14,34 @@ const (
 
 	// HealthPath is the endpoint for health checks and system status.
 	HealthPath = "/health"
+
+	// LivenessPath is the probe endpoint answering while the process serves requests at all.
+	LivenessPath = "/livez"
+
+	// ReadinessPath is the probe endpoint answering once the server started and its dependencies are reachable.
+	ReadinessPath = "/readyz"
+
+	// StartupPath is the probe endpoint answering once startup, including migrations, has finished.
+	StartupPath = "/startupz"
+)
+
+// API Versions select the shape of responses that changed incompatibly. Clients send the
+// version they were built against in the X-API-Version header; requests without it get
+// the default version, so existing clients keep working until they opt in to a newer one.
+const (
+	// APIVersionLegacy is the version before list responses shared the list envelope.
+	APIVersionLegacy = 1
+
+	// APIVersionListEnvelope is the version in which every list endpoint responds with
+	// items, total and page_info.
+	APIVersionListEnvelope = 2
+
+	// APIVersionCurrent is the newest version a client can ask for.
+	APIVersionCurrent = APIVersionListEnvelope
+
+	// APIVersionDefault is the version served to clients that don't ask for one, or ask
+	// for one this server doesn't know.
+	APIVersionDefault = APIVersionLegacy
 )
 
 // URL Parameters define path parameter names used in route definitions.
@@ -32,11 +60,32 @@ const (
 	// ParamPatternID is the URL parameter for pattern identifiers.
 	ParamPatternID = "patternID"
 
+	// ParamRuleID is the URL parameter for classification rule identifiers.
+	ParamRuleID = "ruleID"
+
 	// ParamTable is the URL parameter for database table names.
 	ParamTable = "table"
 
 	// ParamID is the URL parameter for generic resource identifiers.
 	ParamID = "id"
+
+	// ParamTask is the URL parameter for maintenance task names.
+	ParamTask = "task"
+
+	// ParamProjection is the URL parameter for the names of rebuildable derived tables.
+	ParamProjection = "projection"
+
+	// ParamSchema is the URL parameter for published XML schema names.
+	ParamSchema = "schema"
+
+	// ParamProvider is the URL parameter for cloud drive and sign-in providers.
+	ParamProvider = "provider"
+
+	// ParamFileID is the URL parameter for cloud drive file identifiers.
+	ParamFileID = "fileID"
+
+	// ParamDeliveryID is the URL parameter for webhook delivery identifiers.
+	ParamDeliveryID = "deliveryID"
 )
 
 // Query Parameters define common query string parameter names.
@@ -49,11 +98,116 @@ const (
 	// QueryParamPageSize is the query parameter for pagination page size.
 	QueryParamPageSize = "page_size"
 
+	// QueryParamCursor is the query parameter for a page_info cursor of a list response.
+	QueryParamCursor = "cursor"
+
+	// QueryParamPagination is the query parameter selecting how a list is paged.
+	// PaginationKeyset starts a list paged by keyset cursors instead of page numbers.
+	QueryParamPagination = "pagination"
+	PaginationKeyset     = "keyset"
+
 	// QueryParamUsername is the query parameter for filtering by username.
 	QueryParamUsername = "username"
 
 	// QueryParamEmail is the query parameter for filtering by email.
 	QueryParamEmail = "email"
+
+	// QueryParamSince is the query parameter for the last revision a client has seen.
+	QueryParamSince = "since"
+
+	// QueryParamWait is the query parameter for the long-poll wait time in seconds.
+	QueryParamWait = "wait"
+
+	// QueryParamMonth is the query parameter for selecting a calendar month (YYYY-MM).
+	QueryParamMonth = "month"
+
+	// QueryParamStatus is the query parameter for filtering by status.
+	QueryParamStatus = "status"
+
+	// QueryParamMethodID is the query parameter for filtering detected entities by detection method.
+	QueryParamMethodID = "method_id"
+
+	// QueryParamLocale is the query parameter for the regional formatting of an export (e.g. de-DE).
+	QueryParamLocale = "locale"
+
+	// QueryParamCSVDelimiter is the query parameter overriding the CSV delimiter of an export.
+	QueryParamCSVDelimiter = "csv_delimiter"
+
+	// QueryParamLanguage is the query parameter for filtering documents by language (e.g. nb).
+	QueryParamLanguage = "language"
+
+	// QueryParamUnread is the query parameter for leaving out announcements that were already read.
+	QueryParamUnread = "unread"
+
+	// QueryParamFormat is the query parameter for the file format of an export (e.g. zip).
+	QueryParamFormat = "format"
+
+	// QueryParamRedactionMethod is the query parameter for the default redaction method of a redacted PDF.
+	QueryParamRedactionMethod = "method"
+
+	// QueryParamQuery is the query parameter for search terms.
+	QueryParamQuery = "q"
+
+	// QueryParamFolder is the query parameter for the cloud drive folder to list.
+	QueryParamFolder = "folder"
+
+	// QueryParamMinDocuments is the query parameter for the number of documents a value must leak in.
+	QueryParamMinDocuments = "min_documents"
+
+	// QueryParamPageToken is the query parameter for the next page of a cloud drive listing.
+	QueryParamPageToken = "page_token"
+
+	// QueryParamUserID is the query parameter for filtering by user.
+	QueryParamUserID = "user_id"
+
+	// QueryParamGroup is the query parameter for filtering rate limit buckets by route group.
+	QueryParamGroup = "group"
+
+	// QueryParamIPAddress is the query parameter for filtering by IP address.
+	QueryParamIPAddress = "ip"
+
+	// QueryParamRole is the query parameter for filtering users by role.
+	QueryParamRole = "role"
+
+	// QueryParamName is the query parameter for searching documents by part of their name.
+	QueryParamName = "name"
+
+	// QueryParamUploadedFrom is the query parameter for the earliest upload date of searched documents.
+	QueryParamUploadedFrom = "uploaded_from"
+
+	// QueryParamUploadedTo is the query parameter for the latest upload date of searched documents.
+	QueryParamUploadedTo = "uploaded_to"
+
+	// QueryParamEntityTypes is the query parameter for the comma-separated entity types searched documents must contain.
+	QueryParamEntityTypes = "entity_types"
+
+	// QueryParamMinEntities is the query parameter for the minimum number of detections in searched documents.
+	QueryParamMinEntities = "min_entities"
+
+	// QueryParamMaxEntities is the query parameter for the maximum number of detections in searched documents.
+	QueryParamMaxEntities = "max_entities"
+
+	// QueryParamRetentionDays is the query parameter for the document retention setting to preview.
+	QueryParamRetentionDays = "retention_days"
+)
+
+// XML Schemas name the published XSD files of the endpoints that can respond with XML,
+// and the root elements of XML documents that are not wrapped in a response envelope.
+const (
+	// XMLSchemaDocuments describes the XML rendering of the document list.
+	XMLSchemaDocuments = "documents"
+
+	// XMLSchemaModelEntities describes the XML rendering of a method's model entities.
+	XMLSchemaModelEntities = "model_entities"
+
+	// XMLSchemaSettingsExport describes the XML settings export.
+	XMLSchemaSettingsExport = "settings_export"
+
+	// XMLRootSettingsExport is the root element of the XML settings export.
+	XMLRootSettingsExport = "settings_export"
+
+	// XMLSchemaCacheSeconds is how long clients and proxies may cache a published schema.
+	XMLSchemaCacheSeconds = 86400
 )
 
 const (
@@ -16,6 +16,13 @@ const (
 	// StatusCreated indicates that the request has succeeded and a new resource has been created.
 	StatusCreated = 201
 
+	// StatusAccepted indicates that the request has been accepted and is processed in the background.
+	StatusAccepted = 202
+
+	// StatusMultiStatus indicates that the items of a batch request had different outcomes,
+	// which the response reports one by one.
+	StatusMultiStatus = 207
+
 	// StatusNoContent indicates that the request has succeeded but there is no content to send.
 	StatusNoContent = 204
 
@@ -37,8 +44,18 @@ const (
 	// StatusConflict indicates that the request conflicts with the current state of the server.
 	StatusConflict = 409
 
+	// StatusTooManyRequests indicates that the client has sent too many requests or used up a quota.
+	StatusTooManyRequests = 429
+
+	// StatusClientClosedRequest indicates that the client closed the connection before the response
+	// was sent. It is not a standard status; it follows the nginx convention and only shows up in logs.
+	StatusClientClosedRequest = 499
+
 	// StatusInternalServerError indicates that the server encountered an unexpected condition.
 	StatusInternalServerError = 500
+
+	// StatusServiceUnavailable indicates that the server is temporarily unable to handle the request.
+	StatusServiceUnavailable = 503
 )
 
 // HTTP Response Code Types define application-specific response codes.
@@ -88,6 +105,24 @@ const (
 
 	// CodeAuthenticationFailed indicates a general authentication failure.
 	CodeAuthenticationFailed = "authentication_failed"
+
+	// CodeQuotaExceeded indicates that a usage quota has been used up.
+	CodeQuotaExceeded = "quota_exceeded"
+
+	// CodeUploadLimitExceeded indicates that an upload would go over a document, page or size limit.
+	CodeUploadLimitExceeded = "upload_limit_exceeded"
+
+	// CodeUploadIntegrityFailed indicates that an uploaded file does not match its declared size or checksum.
+	CodeUploadIntegrityFailed = "upload_integrity_failed"
+
+	// CodeTooManyAttempts indicates that the client must wait after too many failed attempts.
+	CodeTooManyAttempts = "too_many_attempts"
+
+	// CodeServiceOverloaded indicates that the request was shed because the service is overloaded.
+	CodeServiceOverloaded = "service_overloaded"
+
+	// CodeRequestCanceled indicates that the client canceled the request before it completed.
+	CodeRequestCanceled = "request_canceled"
 )
 
 // HTTP Header Names define common HTTP headers used in requests and responses.
@@ -102,9 +137,18 @@ const (
 	// HeaderContentDisposition suggests how the content should be displayed.
 	HeaderContentDisposition = "Content-Disposition"
 
+	// HeaderContentLanguage describes the natural language of the entity-body.
+	HeaderContentLanguage = "Content-Language"
+
 	// HeaderCacheControl directs caching behavior for the request/response chain.
 	HeaderCacheControl = "Cache-Control"
 
+	// HeaderETag identifies a version of a resource, for conditional and range requests.
+	HeaderETag = "ETag"
+
+	// HeaderRange requests a part of a resource.
+	HeaderRange = "Range"
+
 	// HeaderPragma provides implementation-specific directives that might apply to any
 	// recipient along the request/response chain.
 	HeaderPragma = "Pragma"
@@ -112,6 +156,9 @@ const (
 	// HeaderExpires specifies the date/time after which the response is considered stale.
 	HeaderExpires = "Expires"
 
+	// HeaderAccept lists the media types the client is able to understand.
+	HeaderAccept = "Accept"
+
 	// HeaderAuthorization provides authentication credentials for HTTP authentication.
 	HeaderAuthorization = "Authorization"
 
@@ -124,6 +171,9 @@ const (
 	// HeaderXAPIKey contains the API key for authentication.
 	HeaderXAPIKey = "X-API-Key"
 
+	// HeaderXAuditorToken contains the token of an auditor access grant.
+	HeaderXAuditorToken = "X-Auditor-Token"
+
 	// HeaderXContentTypeOptions controls MIME type sniffing.
 	HeaderXContentTypeOptions = "X-Content-Type-Options"
 
@@ -138,6 +188,49 @@ const (
 
 	// HeaderContentSecurityPolicy defines content sources which are approved and can be loaded.
 	HeaderContentSecurityPolicy = "Content-Security-Policy"
+
+	// HeaderStrictTransportSecurity tells browsers to only reach the host over HTTPS.
+	HeaderStrictTransportSecurity = "Strict-Transport-Security"
+
+	// HeaderXAccelBuffering controls whether reverse proxies buffer the response.
+	HeaderXAccelBuffering = "X-Accel-Buffering"
+
+	// HeaderRetryAfter tells the client how many seconds to wait before retrying.
+	HeaderRetryAfter = "Retry-After"
+
+	// HeaderXQuotaRemaining tells the client how many detection quota tokens are left.
+	HeaderXQuotaRemaining = "X-Quota-Remaining"
+
+	// HeaderXCache tells the client whether a response was served from a cache ("hit") or not ("miss").
+	HeaderXCache = "X-Cache"
+
+	// HeaderXShadowRequest marks requests mirrored to a shadow deployment.
+	HeaderXShadowRequest = "X-Shadow-Request"
+
+	// HeaderXAPIVersion carries the API version a client was built against, and the version
+	// a response was served in.
+	HeaderXAPIVersion = "X-API-Version"
+
+	// HeaderDeprecation marks responses in a shape that will be removed.
+	HeaderDeprecation = "Deprecation"
+
+	// HeaderSunset carries the date after which a deprecated route may be removed (RFC 8594).
+	HeaderSunset = "Sunset"
+
+	// HeaderLink points clients to related resources, such as the successor of a deprecated route.
+	HeaderLink = "Link"
+
+	// HeaderCookie carries the cookies of a request.
+	HeaderCookie = "Cookie"
+
+	// HeaderXHideMeEvent carries the event type of a webhook delivery.
+	HeaderXHideMeEvent = "X-HideMe-Event"
+
+	// HeaderXHideMeDelivery carries the ID of a webhook delivery, the same on every attempt.
+	HeaderXHideMeDelivery = "X-HideMe-Delivery"
+
+	// HeaderXHideMeSignature carries the timestamp and HMAC-SHA256 signature of a webhook delivery.
+	HeaderXHideMeSignature = "X-HideMe-Signature"
 )
 
 // HTTP Content Types define media types used in the Content-Type header.
@@ -148,6 +241,33 @@ const (
 
 	// ContentTypeOctetStream specifies the content is an arbitrary binary data stream.
 	ContentTypeOctetStream = "application/octet-stream"
+
+	// ContentTypeCSV specifies the content is comma-separated values.
+	ContentTypeCSV = "text/csv; charset=utf-8"
+
+	// ContentTypeEventStream specifies the content is a stream of server-sent events.
+	ContentTypeEventStream = "text/event-stream"
+
+	// ContentTypeNDJSON specifies the content is newline-delimited JSON, one object per line.
+	ContentTypeNDJSON = "application/x-ndjson"
+
+	// ContentTypeZIP specifies the content is a ZIP archive.
+	ContentTypeZIP = "application/zip"
+
+	// ContentTypePDF specifies the content is a PDF document.
+	ContentTypePDF = "application/pdf"
+
+	// ContentTypeForm specifies the content is URL-encoded form data.
+	ContentTypeForm = "application/x-www-form-urlencoded"
+
+	// ContentTypeXML specifies the content is in XML format.
+	ContentTypeXML = "application/xml; charset=utf-8"
+
+	// MediaTypeXML is the media type clients list in the Accept header to request XML.
+	MediaTypeXML = "application/xml"
+
+	// MediaTypeTextXML is the legacy media type for XML, also accepted in the Accept header.
+	MediaTypeTextXML = "text/xml"
 )
 
 // Security Header Values define the values for various security-related HTTP headers.
@@ -168,9 +288,22 @@ const (
 	// CSPDefaultSrc restricts content sources to the same origin by default.
 	CSPDefaultSrc = "default-src 'self'"
 
+	// CSPHTMLDefault is the default policy for HTML pages such as the Swagger UI, which
+	// needs inline scripts and styles.
+	CSPHTMLDefault = "default-src 'self'; script-src 'self' 'unsafe-inline'; style-src 'self' 'unsafe-inline'; img-src 'self' data:; frame-ancestors 'none'"
+
+	// HSTSMaxAgeFormat formats the Strict-Transport-Security policy for a max age in seconds.
+	HSTSMaxAgeFormat = "max-age=%d"
+
+	// HSTSIncludeSubdomains extends the Strict-Transport-Security policy to all subdomains.
+	HSTSIncludeSubdomains = "; includeSubDomains"
+
 	// CacheControlNoStore prevents caching of sensitive information.
 	CacheControlNoStore = "no-cache, no-store, must-revalidate"
 
+	// CacheControlPublicFormat lets public responses be cached for the given number of seconds.
+	CacheControlPublicFormat = "public, max-age=%d"
+
 	// PragmaNoCache prevents caching in HTTP/1.0 caches.
 	PragmaNoCache = "no-cache"
 
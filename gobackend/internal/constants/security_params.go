@@ -21,6 +21,10 @@ const (
 
 	// RequestIDContextKey is the context key for storing the unique request identifier.
 	RequestIDContextKey = "request_id"
+
+	// APIKeyScopesContextKey is the context key for storing the scopes of the API key a request
+	// was authenticated with.
+	APIKeyScopesContextKey = "api_key_scopes"
 )
 
 // Auth Token Types define the different types of authentication tokens used in the system.
@@ -47,6 +51,9 @@ const (
 
 	// MaxEmailLength is the maximum number of characters allowed in an email address.
 	MaxEmailLength = 255
+
+	// MinJWTSecretLength is the shortest JWT signing secret, in bytes, that the config check accepts without a warning.
+	MinJWTSecretLength = 32
 )
 
 // Theme Types define the supported UI themes in the application.
@@ -71,6 +78,12 @@ const (
 	// AuthTokenCookie is the name of the cookie storing the access token.
 	AuthTokenCookie = "auth_token"
 
+	// OAuthFlowCookie is the name of the cookie tying a sign-in with a provider to the browser that started it.
+	OAuthFlowCookie = "oauth_flow"
+
+	// OAuthCookiePath limits the sign-in flow cookie to the sign-in endpoints.
+	OAuthCookiePath = "/api/auth/oauth"
+
 	// CSRFTokenCookie is the name of the cookie storing the CSRF token.
 	CSRFTokenCookie = "csrf_token"
 )
@@ -96,4 +109,35 @@ const (
 
 	// RoleAdmin is the elevated role for administrative users.
 	RoleAdmin = "admin"
+
+	// RoleAuditor is the role for auditors, who see the metadata of detected entities
+	// but not their text.
+	RoleAuditor = "auditor"
+
+	// RoleSupport is the role for support staff, who see user profiles but not email addresses.
+	RoleSupport = "support"
+)
+
+// User Statuses tell administrators whether an account may be used.
+const (
+	// UserStatusActive is an account that may sign in.
+	UserStatusActive = "active"
+
+	// UserStatusDisabled is an account an administrator disabled.
+	UserStatusDisabled = "disabled"
+)
+
+// Tenant Encryption Keys define how tenant data is encrypted with per-tenant data-encryption keys.
+// Deleting a tenant's key makes all of its ciphertexts unrecoverable (crypto-shredding).
+const (
+	// DataKeySize is the size in bytes of a tenant data-encryption key (AES-256).
+	DataKeySize = 32
+
+	// TenantCiphertextPrefix marks values encrypted with a tenant data-encryption key.
+	// Values without it were encrypted with the shared master key before tenant keys existed.
+	TenantCiphertextPrefix = "tk1:"
+
+	// LegacyReencryptBatchSize is the maximum number of rows per table moved from the
+	// master key to tenant keys in one maintenance run.
+	LegacyReencryptBatchSize = 500
 )
@@ -26,6 +26,14 @@ const (
 	// DefaultIdleTimeout is the maximum amount of time to wait for the
 	// next request when keep-alives are enabled.
 	DefaultIdleTimeout = 120 * time.Second
+
+	// DefaultReadHeaderTimeout is the maximum duration for reading the request headers.
+	// It is enforced separately from ReadTimeout, so slow-header clients are cut off early.
+	DefaultReadHeaderTimeout = 5 * time.Second
+
+	// DefaultTCPKeepAlive is the interval of TCP keep-alive probes on accepted connections,
+	// the same as the Go default.
+	DefaultTCPKeepAlive = 15 * time.Second
 )
 
 // Database Timeouts define durations related to database operations and connection management.
@@ -50,6 +58,96 @@ const (
 	// DBMaintenanceInterval is how often database maintenance tasks are performed,
 	// such as pruning expired sessions or cleaning up temporary data.
 	DBMaintenanceInterval = 1 * time.Hour
+
+	// MaintenanceSchedulerTick is how often the scheduler looks for maintenance tasks that
+	// are due; it is the shortest interval a task can run at.
+	MaintenanceSchedulerTick = 1 * time.Minute
+
+	// MaintenanceRunTimeout is the maximum duration of the maintenance tasks run at one tick.
+	MaintenanceRunTimeout = 5 * time.Minute
+
+	// DefaultSlowQueryThreshold is the default duration from which a query is reported as slow.
+	DefaultSlowQueryThreshold = 500 * time.Millisecond
+
+	// SlowQueryExplainTimeout is the maximum time capturing the plan of a slow query may take.
+	SlowQueryExplainTimeout = 5 * time.Second
+
+	// SlowQueryExplainCooldown is the minimum time between two plans captured for the same query,
+	// so that a query that is slow on every call doesn't trigger an EXPLAIN each time.
+	SlowQueryExplainCooldown = 10 * time.Minute
+
+	// IndexAdvisorInterval is how often the index advisor inspects the database statistics.
+	IndexAdvisorInterval = 24 * time.Hour
+
+	// BenchmarkInterval is how often the cross-tenant benchmarks are recomputed.
+	// Publishing rarely keeps the privacy budget spent on each tenant small.
+	BenchmarkInterval = 7 * 24 * time.Hour
+
+	// BenchmarkWindow is the period of activity the cross-tenant benchmarks cover.
+	BenchmarkWindow = 90 * 24 * time.Hour
+
+	// RuleStaleAfter is how long a ban list word or search pattern may go without producing
+	// a detection before it is suggested for removal.
+	RuleStaleAfter = 90 * 24 * time.Hour
+
+	// UserDataExportPollInterval is how often servers look for user data export jobs to run.
+	UserDataExportPollInterval = 10 * time.Second
+
+	// UserDataExportLease is how long a server may go without a checkpoint before another server
+	// takes over its export job, on the assumption that it crashed.
+	UserDataExportLease = 5 * time.Minute
+
+	// UserDataExportRetention is how long finished export jobs and their files are kept.
+	UserDataExportRetention = 7 * 24 * time.Hour
+
+	// AccountErasureGracePeriod is how long users can cancel the erasure of their account.
+	AccountErasureGracePeriod = 7 * 24 * time.Hour
+
+	// AccountErasureLease is how long a server may work on an erasure before another server
+	// takes it over, on the assumption that it crashed.
+	AccountErasureLease = 15 * time.Minute
+
+	// AccountErasureRetryDelay is how long a failed erasure waits before it is attempted again.
+	AccountErasureRetryDelay = time.Hour
+
+	// JobPollInterval is how often idle workers look for queued jobs to run.
+	JobPollInterval = 2 * time.Second
+
+	// JobLease is how long a worker may run a job before another server takes it over, on the
+	// assumption that it crashed.
+	JobLease = 15 * time.Minute
+
+	// JobRetryBaseDelay is how long a job waits after its first failed attempt; the wait
+	// doubles with every further attempt.
+	JobRetryBaseDelay = 10 * time.Second
+
+	// JobRetryMaxDelay caps the wait between two attempts of a job.
+	JobRetryMaxDelay = 10 * time.Minute
+
+	// JobRetention is how long finished jobs and their results are kept.
+	JobRetention = 7 * 24 * time.Hour
+)
+
+// Notification Timeouts define durations used when streaming events to clients.
+const (
+	// NotificationKeepAliveInterval is how often an idle event stream sends a comment, so
+	// proxies and clients don't close it.
+	NotificationKeepAliveInterval = 25 * time.Second
+
+	// NotificationClientRetry is how long clients wait before reconnecting a closed event stream.
+	NotificationClientRetry = 5 * time.Second
+
+	// NotificationListenerMinReconnect is how long the notification relay waits before
+	// reconnecting to the database after losing its connection; the wait doubles up to
+	// NotificationListenerMaxReconnect.
+	NotificationListenerMinReconnect = time.Second
+
+	// NotificationListenerMaxReconnect caps the wait before the notification relay reconnects.
+	NotificationListenerMaxReconnect = time.Minute
+
+	// NotificationListenerPingInterval is how often the notification relay checks its idle
+	// database connection.
+	NotificationListenerPingInterval = 90 * time.Second
 )
 
 // Authentication Timeouts define durations related to authentication tokens and sessions.
@@ -68,6 +166,26 @@ const (
 	// authentication where frequent rotation is more disruptive.
 	DefaultAPIKeyExpiry = 30 * 24 * time.Hour // 90 days
 
+	// DefaultAPIKeyRotationOverlap is how long a rotated API key stays valid next to its
+	// replacement, giving integrations time to switch keys without downtime.
+	DefaultAPIKeyRotationOverlap = 24 * time.Hour
+
+	// DefaultAPIKeyCacheTTL is how long a successful API key verification is reused.
+	// It bounds how long a deleted or rotated-out key keeps working, so it is kept short.
+	DefaultAPIKeyCacheTTL = 30 * time.Second
+
+	// DefaultDetectionConfigCacheTTL is how long a composed detection configuration is reused.
+	// Changes made through this instance invalidate it at once; the TTL bounds how long
+	// changes made through other instances go unnoticed.
+	DefaultDetectionConfigCacheTTL = 30 * time.Second
+
+	// DefaultAPIKeyFailureBackoff is how long a client must wait after its first invalid API key;
+	// the wait doubles with every further invalid key.
+	DefaultAPIKeyFailureBackoff = 1 * time.Second
+
+	// DefaultAPIKeyMaxFailureBackoff caps the wait after repeated invalid API keys.
+	DefaultAPIKeyMaxFailureBackoff = 5 * time.Minute
+
 	// APIKeyDuration30Days defines a 30-day API key validity period.
 	APIKeyDuration30Days = 30 * 24 * time.Hour
 
@@ -86,3 +204,223 @@ const (
 	// APIKeyDuration30Minutes defines a 30-minute API key validity period (for debugging).
 	APIKeyDuration30Minutes = 30 * time.Minute
 )
+
+// Settings Sync Timeouts define durations used by the settings change feed.
+// These values control how long desktop clients may hold a long-poll request open.
+const (
+	// SettingsChangesPollInterval is how often the change feed is re-checked
+	// while a long-poll request is waiting for new revisions.
+	SettingsChangesPollInterval = 1 * time.Second
+
+	// MaxSettingsChangesWait is the maximum time a long-poll request may wait
+	// for new revisions before returning an empty change set.
+	MaxSettingsChangesWait = 30 * time.Second
+)
+
+// Admin Approval Timeouts define durations used by the two-person approval workflow.
+const (
+	// DefaultAdminActionExpiry is how long a destructive admin action waits for a
+	// second administrator's approval before it expires.
+	DefaultAdminActionExpiry = 24 * time.Hour
+)
+
+// Risk Scoring Timeouts define durations used when risk scoring holds back an account.
+const (
+	// EmailVerificationTokenExpiry is how long an email verification link stays valid.
+	EmailVerificationTokenExpiry = 24 * time.Hour
+)
+
+// Detection Quota Timeouts define durations used by the shared detection quota.
+const (
+	// DefaultDetectionQuotaRefillInterval is the default time an empty bucket needs to refill completely.
+	DefaultDetectionQuotaRefillInterval = 24 * time.Hour
+
+	// RedisDialTimeout is the maximum time to wait when connecting to Redis.
+	RedisDialTimeout = 5 * time.Second
+
+	// RedisOperationTimeout is the maximum time a single Redis command may take.
+	RedisOperationTimeout = 2 * time.Second
+)
+
+// Streaming Timeouts define durations used when streaming large responses.
+const (
+	// StreamWriteTimeout is the maximum time a client may take to accept each streamed chunk.
+	// The deadline is extended after every chunk, so slow clients that keep reading are
+	// served completely while stalled clients are disconnected.
+	StreamWriteTimeout = 30 * time.Second
+)
+
+// Route Timeout Classes group the routes by how long writing their response may take.
+// The class of a route is declared with the route in the server's route registry.
+const (
+	// RouteTimeoutStandard routes finish within the server's write timeout.
+	RouteTimeoutStandard = "standard"
+
+	// RouteTimeoutLong routes, such as server-side redaction and exports, may take up to
+	// RouteLongWriteTimeout to write their response.
+	RouteTimeoutLong = "long"
+
+	// RouteTimeoutStream routes stream their response and extend the write deadline themselves,
+	// after every chunk or while long-polling.
+	RouteTimeoutStream = "stream"
+
+	// RouteLongWriteTimeout is the write timeout of routes in the RouteTimeoutLong class.
+	RouteLongWriteTimeout = 2 * time.Minute
+)
+
+// Load Shedding Timeouts define durations used when shedding requests while the database is slow.
+const (
+	// DBHealthWindow is the rolling window over which database latency and errors are averaged.
+	DBHealthWindow = 30 * time.Second
+
+	// DBHealthBucket is the granularity of the rolling window; samples expire one bucket at a time.
+	DBHealthBucket = 1 * time.Second
+
+	// DefaultLoadShedDegradedLatency is the default mean query latency from which low-priority requests are shed.
+	DefaultLoadShedDegradedLatency = 250 * time.Millisecond
+
+	// DefaultLoadShedOverloadedLatency is the default mean query latency from which normal-priority requests are shed too.
+	DefaultLoadShedOverloadedLatency = 1 * time.Second
+
+	// LoadShedRetryAfter is the delay clients are asked to wait before retrying a shed request.
+	LoadShedRetryAfter = 30 * time.Second
+)
+
+// Shadow Traffic Timeouts define durations used when mirroring requests to a shadow deployment.
+const (
+	// DefaultShadowTimeout is the default time a mirrored request may take before it counts as failed.
+	DefaultShadowTimeout = 10 * time.Second
+
+	// DefaultShadowLatencyTolerance is how much slower than the primary a shadow response may be
+	// before it counts as a latency regression.
+	DefaultShadowLatencyTolerance = 250 * time.Millisecond
+)
+
+// Startup Timeouts define how long startup waits for the database and cache to become reachable.
+const (
+	// DefaultStartupMaxWait is the default time startup keeps retrying a dependency before it fails.
+	DefaultStartupMaxWait = 2 * time.Minute
+
+	// DefaultStartupInitialBackoff is the default wait after the first failed connection attempt.
+	DefaultStartupInitialBackoff = 500 * time.Millisecond
+
+	// DefaultStartupMaxBackoff is the default longest wait between two connection attempts.
+	DefaultStartupMaxBackoff = 10 * time.Second
+
+	// ProbeCheckTimeout is the maximum time the readiness probe waits for each dependency.
+	ProbeCheckTimeout = 2 * time.Second
+
+	// HealthcheckTimeout is the maximum time the --healthcheck mode waits for the readiness probe.
+	HealthcheckTimeout = 5 * time.Second
+
+	// StartupRetryAfter is the delay clients are asked to wait while the server is still starting.
+	StartupRetryAfter = 5 * time.Second
+)
+
+// Rate Limit Timeouts define how long rate limited clients are asked to wait.
+const (
+	// RateLimitRetryAfter is the delay clients are asked to wait when their bucket cannot tell
+	// when the next request is allowed.
+	RateLimitRetryAfter = 60 * time.Second
+)
+
+// Status Page Timeouts define how often components are checked for the public status page.
+const (
+	// DefaultStatusCheckInterval is the default time between two health checks of the components.
+	DefaultStatusCheckInterval = 1 * time.Minute
+
+	// DefaultStatusCheckTimeout is the default maximum time a single component check may take.
+	DefaultStatusCheckTimeout = 5 * time.Second
+
+	// DefaultStatusCacheTTL is the default time the public status is served from the cache.
+	DefaultStatusCacheTTL = 30 * time.Second
+
+	// StatusUptimeRetention is how long the hourly uptime rollups are kept.
+	StatusUptimeRetention = 90 * 24 * time.Hour
+)
+
+// Cloud Drive Timeouts define the lifetimes used by the cloud drive connectors.
+const (
+	// DriveStateTTL is how long a user has to complete the authorization of a cloud drive.
+	DriveStateTTL = 10 * time.Minute
+
+	// DriveTokenRefreshMargin is how long before it expires an access token is refreshed.
+	DriveTokenRefreshMargin = 1 * time.Minute
+
+	// DriveRequestTimeout is the maximum time a request to a cloud drive API may take, downloads excepted.
+	DriveRequestTimeout = 30 * time.Second
+)
+
+// Social Login Timeouts define the lifetimes used by sign-in with OpenID Connect providers.
+const (
+	// OAuthFlowTTL is how long a user has to complete signing in with a provider.
+	OAuthFlowTTL = 10 * time.Minute
+
+	// OAuthRequestTimeout is the maximum time a request to a provider's token or key endpoint may take.
+	OAuthRequestTimeout = 15 * time.Second
+
+	// OAuthKeySetTTL is how long the signing keys of a provider are cached.
+	OAuthKeySetTTL = 1 * time.Hour
+
+	// OAuthKeySetMinRefresh is the minimum time between fetches of a provider's signing keys
+	// when a token is signed with an unknown key.
+	OAuthKeySetMinRefresh = 1 * time.Minute
+)
+
+// Export Delivery Timeouts define the limits of deliveries to export destinations.
+const (
+	// ExportDeliveryTimeout is the maximum time a delivery to an export destination may take.
+	ExportDeliveryTimeout = 2 * time.Minute
+)
+
+// Webhook Timeouts define the limits and schedule of deliveries to webhooks.
+const (
+	// WebhookDeliveryTimeout is the maximum time a webhook may take to answer a delivery.
+	WebhookDeliveryTimeout = 10 * time.Second
+
+	// WebhookDeliveryLease is how long a server may hold a delivery before another server
+	// attempts it again.
+	WebhookDeliveryLease = 1 * time.Minute
+
+	// WebhookPollInterval is how often servers look for deliveries that are due.
+	WebhookPollInterval = 5 * time.Second
+
+	// WebhookDeliveryRetention is how long deliveries are kept after they were created.
+	WebhookDeliveryRetention = 30 * 24 * time.Hour
+)
+
+// SLO Timeouts define the windows over which the service-level objectives are evaluated.
+const (
+	// SLOMetricsBucket is the granularity at which request metrics expire.
+	SLOMetricsBucket = 1 * time.Minute
+
+	// SLOMetricsWindow is how long request metrics are kept; the longest window of the
+	// burn-rate rules.
+	SLOMetricsWindow = 6 * time.Hour
+
+	// DefaultSLOEvaluationInterval is the default time between two evaluations of the objectives.
+	DefaultSLOEvaluationInterval = 1 * time.Minute
+
+	// DefaultSLOLatencyThreshold is the default time within which a request counts as answered in time.
+	DefaultSLOLatencyThreshold = 1 * time.Second
+
+	// SLOFastBurnLongWindow and SLOFastBurnShortWindow are the windows of the critical rule.
+	SLOFastBurnLongWindow  = 1 * time.Hour
+	SLOFastBurnShortWindow = 5 * time.Minute
+
+	// SLOSlowBurnLongWindow and SLOSlowBurnShortWindow are the windows of the warning rule.
+	SLOSlowBurnLongWindow  = 6 * time.Hour
+	SLOSlowBurnShortWindow = 30 * time.Minute
+)
+
+// Document Storage Timeouts define the limits of requests to the document storage.
+const (
+	// DocumentStorageRequestTimeout is the maximum time a request to the S3 document storage may take.
+	DocumentStorageRequestTimeout = 2 * time.Minute
+)
+
+// Security Header Timeouts define how long browsers remember the security policies.
+const (
+	// DefaultHSTSMaxAge is the default time browsers only reach the host over HTTPS.
+	DefaultHSTSMaxAge = 365 * 24 * time.Hour
+)
@@ -12,7 +12,7 @@ import (
 	"fmt"
 	"os"
 
-	_ "github.com/lib/pq" // Import PostgreSQL driver
+	"github.com/lib/pq"
 	"github.com/rs/zerolog/log"
 
 	"github.com/yasinhessnawi1/Hideme_Backend/internal/config"
@@ -24,6 +24,9 @@ import (
 // for extension with additional functionality.
 type Pool struct {
 	*sql.DB
+
+	// Monitor collects query metrics; nil if the pool is not instrumented
+	Monitor *QueryMonitor
 }
 
 var (
@@ -47,6 +50,50 @@ func Connect(cfg *config.AppConfig) (*Pool, error) {
 	ctx, cancel := context.WithTimeout(context.Background(), constants.DBConnectionTimeout)
 	defer cancel()
 
+	// PostgreSQL connection string with safety parameters
+	connStr := ConnectionString(cfg)
+
+	// Open a connection to the database
+	// Note: This doesn't actually establish a connection yet, it just validates parameters
+	connector, err := pq.NewConnector(connStr)
+	if err != nil {
+		return nil, fmt.Errorf("failed to connect to database: %w", err)
+	}
+
+	// Report every query to the query monitor so metrics can be switched on at runtime
+	monitor := NewQueryMonitor(cfg.Database.QueryMetrics, cfg.Database.ExplainSlowQueries, cfg.Database.SlowQueryThreshold)
+	db := sql.OpenDB(newInstrumentedConnector(connector, monitor))
+	monitor.setDB(db)
+
+	// Configure connection pool parameters for optimal performance
+	db.SetMaxOpenConns(cfg.Database.MaxConns)          // Maximum number of open connections
+	db.SetMaxIdleConns(cfg.Database.MinConns)          // Minimum number of idle connections
+	db.SetConnMaxLifetime(constants.DBConnMaxLifetime) // Maximum lifetime of a connection
+	db.SetConnMaxIdleTime(constants.DBConnMaxIdleTime) // Maximum idle time of a connection
+
+	// Verify connection with a ping
+	// This ensures we can actually establish a connection before returning
+	if err := db.PingContext(ctx); err != nil {
+		db.Close()
+		return nil, fmt.Errorf("failed to ping database: %w", err)
+	}
+
+	log.Info().Msg("Successfully connected to database")
+
+	// Create and store the global database pool
+	dbPool = &Pool{DB: db, Monitor: monitor}
+	return dbPool, nil
+}
+
+// ConnectionString returns the PostgreSQL connection string for the configured database.
+// Environment variables take precedence over the configuration file.
+//
+// Parameters:
+//   - cfg: The application configuration containing database settings
+//
+// Returns:
+//   - The connection string, including the SSL parameters of the environment
+func ConnectionString(cfg *config.AppConfig) string {
 	// Get connection details from environment variables with fallbacks to config
 	// This allows for runtime configuration overrides
 	db_host := os.Getenv("DB_HOST")
@@ -97,7 +144,7 @@ func Connect(cfg *config.AppConfig) (*Pool, error) {
 		Msg("Connecting to database")
 
 	// PostgreSQL connection string with safety parameters
-	connStr := fmt.Sprintf(
+	return fmt.Sprintf(
 		"host=%s port=%s user=%s password=%s dbname=%s %s",
 		db_host,
 		db_port,
@@ -106,32 +153,6 @@ func Connect(cfg *config.AppConfig) (*Pool, error) {
 		db_name,
 		sslParams,
 	)
-
-	// Open a connection to the database
-	// Note: This doesn't actually establish a connection yet, it just validates parameters
-	db, err := sql.Open("postgres", connStr)
-	if err != nil {
-		return nil, fmt.Errorf("failed to connect to database: %w", err)
-	}
-
-	// Configure connection pool parameters for optimal performance
-	db.SetMaxOpenConns(cfg.Database.MaxConns)          // Maximum number of open connections
-	db.SetMaxIdleConns(cfg.Database.MinConns)          // Minimum number of idle connections
-	db.SetConnMaxLifetime(constants.DBConnMaxLifetime) // Maximum lifetime of a connection
-	db.SetConnMaxIdleTime(constants.DBConnMaxIdleTime) // Maximum idle time of a connection
-
-	// Verify connection with a ping
-	// This ensures we can actually establish a connection before returning
-	if err := db.PingContext(ctx); err != nil {
-		db.Close()
-		return nil, fmt.Errorf("failed to ping database: %w", err)
-	}
-
-	log.Info().Msg("Successfully connected to database")
-
-	// Create and store the global database pool
-	dbPool = &Pool{DB: db}
-	return dbPool, nil
 }
 
 // Get returns the global database connection pool.
@@ -161,6 +182,8 @@ func (p *Pool) Close() {
 // Transaction executes a function within a database transaction.
 // It handles starting the transaction, committing on success, rolling back on error,
 // and properly handling panics to ensure the transaction is always cleaned up.
+// If ctx carries a transaction started by WithTransaction, fn runs in that transaction
+// and its outcome is left to WithTransaction.
 //
 // Parameters:
 //   - ctx: The context for the transaction
@@ -169,6 +192,10 @@ func (p *Pool) Close() {
 // Returns:
 //   - An error if the transaction fails or the function returns an error
 func (p *Pool) Transaction(ctx context.Context, fn func(tx *sql.Tx) error) error {
+	if tx := transactionFrom(ctx); tx != nil {
+		return fn(tx)
+	}
+
 	// Start a transaction
 	tx, err := p.DB.BeginTx(ctx, nil)
 	if err != nil {
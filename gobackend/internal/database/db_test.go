@@ -317,6 +317,72 @@ func TestTransaction(t *testing.T) {
 	})
 }
 
+// TestWithTransaction tests that queries and nested transactions join the transaction carried by the context
+func TestWithTransaction(t *testing.T) {
+	t.Run("Queries and nested transactions share the transaction", func(t *testing.T) {
+		// Create a mock DB
+		mockDB, mock, err := sqlmock.New()
+		if err != nil {
+			t.Fatalf("Error creating mock database: %v", err)
+		}
+		defer mockDB.Close()
+
+		// Create pool
+		pool := &Pool{DB: mockDB}
+
+		// Set up expectations - one transaction around both statements
+		mock.ExpectBegin()
+		mock.ExpectExec("UPDATE user_settings").WillReturnResult(sqlmock.NewResult(0, 1))
+		mock.ExpectExec("INSERT INTO settings_revisions").WillReturnResult(sqlmock.NewResult(1, 1))
+		mock.ExpectCommit()
+
+		err = pool.WithTransaction(context.Background(), func(ctx context.Context) error {
+			if _, err := pool.ExecContext(ctx, "UPDATE user_settings SET version = version + 1"); err != nil {
+				return err
+			}
+			return pool.Transaction(ctx, func(tx *sql.Tx) error {
+				_, err := tx.ExecContext(ctx, "INSERT INTO settings_revisions DEFAULT VALUES")
+				return err
+			})
+		})
+
+		// Verify no error and expectations were met
+		assert.NoError(t, err)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("A failing nested transaction rolls back everything", func(t *testing.T) {
+		// Create a mock DB
+		mockDB, mock, err := sqlmock.New()
+		if err != nil {
+			t.Fatalf("Error creating mock database: %v", err)
+		}
+		defer mockDB.Close()
+
+		// Create pool
+		pool := &Pool{DB: mockDB}
+
+		// Set up expectations
+		mock.ExpectBegin()
+		mock.ExpectExec("UPDATE user_settings").WillReturnResult(sqlmock.NewResult(0, 1))
+		mock.ExpectRollback()
+
+		funcErr := errors.New("function error")
+		err = pool.WithTransaction(context.Background(), func(ctx context.Context) error {
+			if _, err := pool.ExecContext(ctx, "UPDATE user_settings SET version = version + 1"); err != nil {
+				return err
+			}
+			return pool.Transaction(ctx, func(tx *sql.Tx) error {
+				return funcErr
+			})
+		})
+
+		// Verify we get the function error and expectations were met
+		assert.Equal(t, funcErr, err)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+}
+
 // TestHealthCheck tests the HealthCheck function
 func TestHealthCheck(t *testing.T) {
 	t.Run("Successful health check", func(t *testing.T) {
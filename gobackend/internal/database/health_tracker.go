@@ -0,0 +1,125 @@
+// Package database provides database access and management functions for the HideMe API.
+//
+// This file implements the health tracker. It keeps the latency and error rate of
+// recent queries over a short rolling window, so callers can tell when the database
+// is struggling before requests start timing out.
+package database
+
+import (
+	"sync"
+	"time"
+
+	"github.com/yasinhessnawi1/Hideme_Backend/internal/utils/clock"
+)
+
+// HealthStats summarize the queries run during the rolling window.
+type HealthStats struct {
+	// Samples is the number of queries in the window
+	Samples int64 `json:"samples"`
+
+	// Errors is the number of queries in the window that failed
+	Errors int64 `json:"errors"`
+
+	// MeanLatency is the average time per query
+	MeanLatency time.Duration `json:"mean_latency"`
+
+	// ErrorRate is the share of failed queries (0-1)
+	ErrorRate float64 `json:"error_rate"`
+}
+
+// healthBucket holds the queries that finished during one bucket of the window.
+type healthBucket struct {
+	start         time.Time
+	samples       int64
+	errors        int64
+	totalDuration time.Duration
+}
+
+// HealthTracker keeps rolling query latency and error statistics.
+// The window is split into fixed buckets that expire one at a time, so the
+// statistics follow the database closely without keeping every sample.
+// It is safe for concurrent use.
+type HealthTracker struct {
+	buckets []healthBucket
+	bucket  time.Duration
+	mutex   sync.Mutex
+	clock   clock.Clock
+}
+
+// NewHealthTracker creates a new HealthTracker.
+//
+// Parameters:
+//   - window: The duration the statistics cover
+//   - bucket: The granularity at which samples expire
+//
+// Returns:
+//   - A configured HealthTracker
+func NewHealthTracker(window, bucket time.Duration) *HealthTracker {
+	count := int(window / bucket)
+	if count < 1 {
+		count = 1
+	}
+	return &HealthTracker{
+		buckets: make([]healthBucket, count),
+		bucket:  bucket,
+	}
+}
+
+// SetClock sets the clock checks are bucketed by, so tests can move time
+// past a bucket.
+func (t *HealthTracker) SetClock(c clock.Clock) {
+	t.clock = c
+}
+
+// now returns the current time of the tracker's clock.
+func (t *HealthTracker) now() time.Time {
+	return clock.Or(t.clock).Now()
+}
+
+// Observe records a finished query.
+//
+// Parameters:
+//   - duration: The time spent in the query
+//   - err: The error the query failed with, if any
+func (t *HealthTracker) Observe(duration time.Duration, err error) {
+	t.mutex.Lock()
+	defer t.mutex.Unlock()
+
+	start := t.now().Truncate(t.bucket)
+	b := &t.buckets[int(start.UnixNano()/int64(t.bucket))%len(t.buckets)]
+	if !b.start.Equal(start) {
+		*b = healthBucket{start: start}
+	}
+
+	b.samples++
+	b.totalDuration += duration
+	if err != nil {
+		b.errors++
+	}
+}
+
+// Stats returns the statistics of the queries in the window.
+func (t *HealthTracker) Stats() HealthStats {
+	t.mutex.Lock()
+	defer t.mutex.Unlock()
+
+	oldest := t.now().Truncate(t.bucket).Add(-time.Duration(len(t.buckets)-1) * t.bucket)
+
+	var stats HealthStats
+	var total time.Duration
+	for _, b := range t.buckets {
+		if b.start.Before(oldest) {
+			continue
+		}
+		stats.Samples += b.samples
+		stats.Errors += b.errors
+		total += b.totalDuration
+	}
+
+	if stats.Samples > 0 {
+		stats.MeanLatency = total / time.Duration(stats.Samples)
+		stats.ErrorRate = float64(stats.Errors) / float64(stats.Samples)
+	}
+
+	return stats
+}
@@ -0,0 +1,67 @@
+package database
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/yasinhessnawi1/Hideme_Backend/internal/testsupport/fakeclock"
+)
+
+func TestHealthTracker_Stats(t *testing.T) {
+	tracker := NewHealthTracker(10*time.Second, time.Second)
+	now := time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC)
+	clk := fakeclock.New(now)
+	tracker.SetClock(clk)
+
+	tracker.Observe(100*time.Millisecond, nil)
+	tracker.Observe(300*time.Millisecond, errors.New("boom"))
+
+	now = clk.Advance(5 * time.Second)
+	tracker.Observe(200*time.Millisecond, nil)
+	tracker.Observe(200*time.Millisecond, nil)
+
+	stats := tracker.Stats()
+	if stats.Samples != 4 || stats.Errors != 1 {
+		t.Errorf("Samples = %d, Errors = %d; want 4 and 1", stats.Samples, stats.Errors)
+	}
+	if stats.MeanLatency != 200*time.Millisecond {
+		t.Errorf("MeanLatency = %v; want 200ms", stats.MeanLatency)
+	}
+	if stats.ErrorRate != 0.25 {
+		t.Errorf("ErrorRate = %v; want 0.25", stats.ErrorRate)
+	}
+
+	// The first samples leave the window, the later ones remain
+	now = clk.Advance(6 * time.Second)
+	stats = tracker.Stats()
+	if stats.Samples != 2 || stats.Errors != 0 {
+		t.Errorf("After expiry: Samples = %d, Errors = %d; want 2 and 0", stats.Samples, stats.Errors)
+	}
+
+	// A bucket reused after a full rotation starts empty
+	now = clk.Advance(4 * time.Second)
+	tracker.Observe(50*time.Millisecond, nil)
+	stats = tracker.Stats()
+	if stats.Samples != 1 || stats.MeanLatency != 50*time.Millisecond {
+		t.Errorf("After rotation: %+v; want a single 50ms sample", stats)
+	}
+}
+
+func TestQueryMonitor_Health(t *testing.T) {
+	// Health is tracked even while metrics are disabled
+	monitor := NewQueryMonitor(false, false, time.Second)
+
+	monitor.record(context.Background(), "SELECT 1", nil, 20*time.Millisecond, 1, nil)
+	monitor.record(context.Background(), "SELECT 1", nil, 40*time.Millisecond, 0, errors.New("boom"))
+	monitor.record(context.Background(), "SELECT 1", nil, 5*time.Second, 0, context.Canceled)
+
+	health := monitor.Health()
+	if health.Samples != 2 || health.Errors != 1 || health.MeanLatency != 30*time.Millisecond {
+		t.Errorf("Health() = %+v; want 2 samples, 1 error, 30ms mean", health)
+	}
+	if len(monitor.Snapshot()) != 0 {
+		t.Error("Statistics were collected while metrics are disabled")
+	}
+}
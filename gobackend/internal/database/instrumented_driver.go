@@ -0,0 +1,277 @@
+// Package database provides database access and management functions for the HideMe API.
+//
+// This file wraps the PostgreSQL driver so that every query run through the pool,
+// including those inside transactions, is reported to the QueryMonitor. Wrapping the
+// driver instead of the repositories means no repository method can be missed.
+package database
+
+import (
+	"context"
+	"database/sql/driver"
+	"io"
+	"reflect"
+	"time"
+)
+
+// instrumentedConnector opens connections that report their queries to a QueryMonitor.
+type instrumentedConnector struct {
+	driver.Connector
+	monitor *QueryMonitor
+}
+
+// newInstrumentedConnector wraps a connector so that its connections report to monitor.
+func newInstrumentedConnector(connector driver.Connector, monitor *QueryMonitor) driver.Connector {
+	return &instrumentedConnector{Connector: connector, monitor: monitor}
+}
+
+// Connect opens a new instrumented connection.
+func (c *instrumentedConnector) Connect(ctx context.Context) (driver.Conn, error) {
+	conn, err := c.Connector.Connect(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return &instrumentedConn{Conn: conn, monitor: c.monitor}, nil
+}
+
+// instrumentedConn reports the queries run on a driver connection.
+// Optional driver interfaces are forwarded when the wrapped connection implements them.
+type instrumentedConn struct {
+	driver.Conn
+	monitor *QueryMonitor
+}
+
+// QueryContext runs a query and reports it once its rows are closed.
+func (c *instrumentedConn) QueryContext(ctx context.Context, query string, args []driver.NamedValue) (driver.Rows, error) {
+	queryer, ok := c.Conn.(driver.QueryerContext)
+	if !ok {
+		return nil, driver.ErrSkip
+	}
+
+	start := time.Now()
+	rows, err := queryer.QueryContext(ctx, query, args)
+	duration := time.Since(start)
+	if err != nil {
+		if err != driver.ErrSkip {
+			c.monitor.record(ctx, query, args, duration, 0, err)
+		}
+		return nil, err
+	}
+
+	return &instrumentedRows{
+		Rows:     rows,
+		ctx:      ctx,
+		query:    query,
+		args:     args,
+		monitor:  c.monitor,
+		duration: duration,
+	}, nil
+}
+
+// ExecContext runs a statement and reports it with the number of affected rows.
+func (c *instrumentedConn) ExecContext(ctx context.Context, query string, args []driver.NamedValue) (driver.Result, error) {
+	execer, ok := c.Conn.(driver.ExecerContext)
+	if !ok {
+		return nil, driver.ErrSkip
+	}
+
+	start := time.Now()
+	result, err := execer.ExecContext(ctx, query, args)
+	duration := time.Since(start)
+	if err == driver.ErrSkip {
+		return nil, err
+	}
+
+	var affected int64
+	if err == nil {
+		affected, _ = result.RowsAffected()
+	}
+	c.monitor.record(ctx, query, args, duration, affected, err)
+
+	return result, err
+}
+
+// PrepareContext prepares a statement whose executions are reported.
+func (c *instrumentedConn) PrepareContext(ctx context.Context, query string) (driver.Stmt, error) {
+	var stmt driver.Stmt
+	var err error
+	if preparer, ok := c.Conn.(driver.ConnPrepareContext); ok {
+		stmt, err = preparer.PrepareContext(ctx, query)
+	} else {
+		stmt, err = c.Conn.Prepare(query)
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &instrumentedStmt{Stmt: stmt, query: query, monitor: c.monitor}, nil
+}
+
+// Prepare prepares a statement whose executions are reported.
+func (c *instrumentedConn) Prepare(query string) (driver.Stmt, error) {
+	return c.PrepareContext(context.Background(), query)
+}
+
+// BeginTx starts a transaction; queries inside it run on this connection and are reported too.
+func (c *instrumentedConn) BeginTx(ctx context.Context, opts driver.TxOptions) (driver.Tx, error) {
+	if beginner, ok := c.Conn.(driver.ConnBeginTx); ok {
+		return beginner.BeginTx(ctx, opts)
+	}
+	return c.Conn.Begin()
+}
+
+// Ping checks the connection.
+func (c *instrumentedConn) Ping(ctx context.Context) error {
+	if pinger, ok := c.Conn.(driver.Pinger); ok {
+		return pinger.Ping(ctx)
+	}
+	return nil
+}
+
+// ResetSession resets the connection before it is reused.
+func (c *instrumentedConn) ResetSession(ctx context.Context) error {
+	if resetter, ok := c.Conn.(driver.SessionResetter); ok {
+		return resetter.ResetSession(ctx)
+	}
+	return nil
+}
+
+// IsValid reports whether the connection can be reused.
+func (c *instrumentedConn) IsValid() bool {
+	if validator, ok := c.Conn.(driver.Validator); ok {
+		return validator.IsValid()
+	}
+	return true
+}
+
+// instrumentedStmt reports the executions of a prepared statement.
+type instrumentedStmt struct {
+	driver.Stmt
+	query   string
+	monitor *QueryMonitor
+}
+
+// ExecContext executes the statement and reports it with the number of affected rows.
+func (s *instrumentedStmt) ExecContext(ctx context.Context, args []driver.NamedValue) (driver.Result, error) {
+	start := time.Now()
+	var result driver.Result
+	var err error
+	if execer, ok := s.Stmt.(driver.StmtExecContext); ok {
+		result, err = execer.ExecContext(ctx, args)
+	} else {
+		result, err = s.Stmt.Exec(namedValuesToValues(args))
+	}
+	duration := time.Since(start)
+
+	var affected int64
+	if err == nil {
+		affected, _ = result.RowsAffected()
+	}
+	s.monitor.record(ctx, s.query, args, duration, affected, err)
+
+	return result, err
+}
+
+// QueryContext runs the statement and reports it once its rows are closed.
+func (s *instrumentedStmt) QueryContext(ctx context.Context, args []driver.NamedValue) (driver.Rows, error) {
+	start := time.Now()
+	var rows driver.Rows
+	var err error
+	if queryer, ok := s.Stmt.(driver.StmtQueryContext); ok {
+		rows, err = queryer.QueryContext(ctx, args)
+	} else {
+		rows, err = s.Stmt.Query(namedValuesToValues(args))
+	}
+	duration := time.Since(start)
+	if err != nil {
+		s.monitor.record(ctx, s.query, args, duration, 0, err)
+		return nil, err
+	}
+
+	return &instrumentedRows{
+		Rows:     rows,
+		ctx:      ctx,
+		query:    s.query,
+		args:     args,
+		monitor:  s.monitor,
+		duration: duration,
+	}, nil
+}
+
+// instrumentedRows counts the rows of a result and reports the query when closed.
+// Only time spent fetching rows is counted, not time the caller spends between rows.
+type instrumentedRows struct {
+	driver.Rows
+	ctx      context.Context
+	query    string
+	args     []driver.NamedValue
+	monitor  *QueryMonitor
+	duration time.Duration
+	rows     int64
+	err      error
+	reported bool
+}
+
+// Next fetches the next row.
+func (r *instrumentedRows) Next(dest []driver.Value) error {
+	start := time.Now()
+	err := r.Rows.Next(dest)
+	r.duration += time.Since(start)
+
+	switch {
+	case err == nil:
+		r.rows++
+	case err != io.EOF:
+		r.err = err
+	}
+	return err
+}
+
+// Close closes the rows and reports the query.
+func (r *instrumentedRows) Close() error {
+	err := r.Rows.Close()
+	if !r.reported {
+		r.reported = true
+		r.monitor.record(r.ctx, r.query, r.args, r.duration, r.rows, r.err)
+	}
+	return err
+}
+
+// ColumnTypeScanType forwards column type information from the wrapped rows.
+func (r *instrumentedRows) ColumnTypeScanType(index int) reflect.Type {
+	if typed, ok := r.Rows.(driver.RowsColumnTypeScanType); ok {
+		return typed.ColumnTypeScanType(index)
+	}
+	return reflect.TypeOf(new(interface{})).Elem()
+}
+
+// ColumnTypeDatabaseTypeName forwards column type information from the wrapped rows.
+func (r *instrumentedRows) ColumnTypeDatabaseTypeName(index int) string {
+	if typed, ok := r.Rows.(driver.RowsColumnTypeDatabaseTypeName); ok {
+		return typed.ColumnTypeDatabaseTypeName(index)
+	}
+	return ""
+}
+
+// ColumnTypeLength forwards column type information from the wrapped rows.
+func (r *instrumentedRows) ColumnTypeLength(index int) (int64, bool) {
+	if typed, ok := r.Rows.(driver.RowsColumnTypeLength); ok {
+		return typed.ColumnTypeLength(index)
+	}
+	return 0, false
+}
+
+// ColumnTypePrecisionScale forwards column type information from the wrapped rows.
+func (r *instrumentedRows) ColumnTypePrecisionScale(index int) (int64, int64, bool) {
+	if typed, ok := r.Rows.(driver.RowsColumnTypePrecisionScale); ok {
+		return typed.ColumnTypePrecisionScale(index)
+	}
+	return 0, 0, false
+}
+
+// namedValuesToValues converts named arguments for drivers without context support.
+func namedValuesToValues(args []driver.NamedValue) []driver.Value {
+	values := make([]driver.Value, len(args))
+	for i, arg := range args {
+		values[i] = arg.Value
+	}
+	return values
+}
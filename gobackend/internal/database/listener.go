@@ -0,0 +1,50 @@
+// Package database provides database access and management functions for the HideMe API.
+//
+// This file creates listeners for PostgreSQL notifications (LISTEN/NOTIFY), which relay
+// events between the servers sharing the database.
+package database
+
+import (
+	"fmt"
+
+	"github.com/lib/pq"
+	"github.com/rs/zerolog/log"
+
+	"github.com/yasinhessnawi1/Hideme_Backend/internal/config"
+	"github.com/yasinhessnawi1/Hideme_Backend/internal/constants"
+)
+
+// NewListener opens a dedicated connection listening for the notifications on a channel.
+// The listener reconnects by itself after losing its connection; notifications sent in
+// the meantime are lost.
+//
+// Parameters:
+//   - cfg: The application configuration containing database settings
+//   - channel: The channel to listen on
+//
+// Returns:
+//   - The listener; its Notify channel receives the notifications, and nil after a reconnect
+//   - An error if listening on the channel fails
+func NewListener(cfg *config.AppConfig, channel string) (*pq.Listener, error) {
+	listener := pq.NewListener(
+		ConnectionString(cfg),
+		constants.NotificationListenerMinReconnect,
+		constants.NotificationListenerMaxReconnect,
+		func(event pq.ListenerEventType, err error) {
+			switch event {
+			case pq.ListenerEventDisconnected:
+				log.Warn().Err(err).Str("channel", channel).Msg("Notification listener disconnected")
+			case pq.ListenerEventReconnected:
+				log.Info().Str("channel", channel).Msg("Notification listener reconnected")
+			case pq.ListenerEventConnectionAttemptFailed:
+				log.Debug().Err(err).Str("channel", channel).Msg("Notification listener failed to reconnect")
+			}
+		},
+	)
+
+	if err := listener.Listen(channel); err != nil {
+		listener.Close()
+		return nil, fmt.Errorf("failed to listen on channel %s: %w", channel, err)
+	}
+	return listener, nil
+}
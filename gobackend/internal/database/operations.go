@@ -143,7 +143,7 @@ func (c *CRUD) Create(ctx context.Context, model Table) error {
 //   - id: The ID of the record to retrieve
 //
 // Returns:
-//   - An error if the record is not found or the operation fails
+//   - An error wrapping sql.ErrNoRows if the record is not found, or another error if the operation fails
 func (c *CRUD) GetByID(ctx context.Context, model Table, id interface{}) error {
 	// Get model type and value through reflection
 	modelType := reflect.TypeOf(model).Elem()
@@ -208,7 +208,7 @@ func (c *CRUD) GetByID(ctx context.Context, model Table, id interface{}) error {
 	// Scan the result into the model
 	if err := row.Scan(values...); err != nil {
 		if errors.Is(err, sql.ErrNoRows) {
-			return fmt.Errorf("record with ID %v not found in %s", id, model.TableName())
+			return fmt.Errorf("record with ID %v not found in %s: %w", id, model.TableName(), sql.ErrNoRows)
 		}
 		return fmt.Errorf("failed to get record from %s: %w", model.TableName(), err)
 	}
@@ -224,7 +224,7 @@ func (c *CRUD) GetByID(ctx context.Context, model Table, id interface{}) error {
 //   - model: A pointer to a struct implementing the Table interface
 //
 // Returns:
-//   - An error if the record is not found or the operation fails
+//   - An error wrapping sql.ErrNoRows if the record is not found, or another error if the operation fails
 func (c *CRUD) Update(ctx context.Context, model Table) error {
 	// Get model type and value through reflection
 	modelType := reflect.TypeOf(model).Elem()
@@ -294,7 +294,7 @@ func (c *CRUD) Update(ctx context.Context, model Table) error {
 	}
 
 	if rowsAffected == 0 {
-		return fmt.Errorf("record with ID %v not found in %s", idValue, model.TableName())
+		return fmt.Errorf("record with ID %v not found in %s: %w", idValue, model.TableName(), sql.ErrNoRows)
 	}
 
 	return nil
@@ -308,7 +308,7 @@ func (c *CRUD) Update(ctx context.Context, model Table) error {
 //   - id: The ID of the record to delete
 //
 // Returns:
-//   - An error if the record is not found or the operation fails
+//   - An error wrapping sql.ErrNoRows if the record is not found, or another error if the operation fails
 func (c *CRUD) Delete(ctx context.Context, model Table, id interface{}) error {
 	// Determine ID column name
 	modelType := reflect.TypeOf(model).Elem()
@@ -352,7 +352,7 @@ func (c *CRUD) Delete(ctx context.Context, model Table, id interface{}) error {
 	}
 
 	if rowsAffected == 0 {
-		return fmt.Errorf("record with ID %v not found in %s", id, model.TableName())
+		return fmt.Errorf("record with ID %v not found in %s: %w", id, model.TableName(), sql.ErrNoRows)
 	}
 
 	return nil
@@ -0,0 +1,366 @@
+// Package database provides database access and management functions for the HideMe API.
+//
+// This file implements the query monitor. It keeps per-query statistics (calls, duration,
+// rows), reports slow queries to the database diagnostics log channel and, when enabled,
+// captures their EXPLAIN plan. Metrics and plan capture can be toggled at runtime.
+package database
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"errors"
+	"sort"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/rs/zerolog"
+	"github.com/rs/zerolog/log"
+
+	"github.com/yasinhessnawi1/Hideme_Backend/internal/constants"
+)
+
+// QueryMonitorSettings are the runtime settings of the query monitor.
+type QueryMonitorSettings struct {
+	// MetricsEnabled turns per-query statistics and slow query reporting on or off
+	MetricsEnabled bool `json:"metrics_enabled"`
+
+	// ExplainEnabled turns capturing the plan of slow queries on or off; it requires MetricsEnabled
+	ExplainEnabled bool `json:"explain_enabled"`
+
+	// SlowQueryThresholdMs is the duration in milliseconds from which a query is slow
+	SlowQueryThresholdMs int64 `json:"slow_query_threshold_ms"`
+}
+
+// QueryStats are the statistics collected for a single query.
+// Durations only count time spent in the database driver, not time the caller
+// spends processing rows, so streaming a large result doesn't make its query look slow.
+type QueryStats struct {
+	// Query is the query text with whitespace collapsed
+	Query string `json:"query"`
+
+	// Calls is the number of times the query ran
+	Calls int64 `json:"calls"`
+
+	// Errors is the number of calls that failed
+	Errors int64 `json:"errors"`
+
+	// SlowCalls is the number of calls that exceeded the slow query threshold
+	SlowCalls int64 `json:"slow_calls"`
+
+	// Rows is the total number of rows returned or affected
+	Rows int64 `json:"rows"`
+
+	// TotalMs is the total time spent in the query in milliseconds
+	TotalMs float64 `json:"total_ms"`
+
+	// MeanMs is the average time per call in milliseconds
+	MeanMs float64 `json:"mean_ms"`
+
+	// MaxMs is the longest call in milliseconds
+	MaxMs float64 `json:"max_ms"`
+
+	// LastPlan is the most recently captured plan, if any
+	LastPlan string `json:"last_plan,omitempty"`
+
+	// LastPlanAt is when LastPlan was captured
+	LastPlanAt *time.Time `json:"last_plan_at,omitempty"`
+}
+
+// queryStats is the mutable state behind QueryStats.
+type queryStats struct {
+	calls         int64
+	errors        int64
+	slowCalls     int64
+	rows          int64
+	totalDuration time.Duration
+	maxDuration   time.Duration
+	lastPlan      string
+	lastPlanAt    time.Time
+	explaining    bool
+}
+
+// explainContextKey marks queries issued by the monitor itself so they are not monitored.
+type explainContextKey struct{}
+
+// QueryMonitor collects query metrics and captures plans of slow queries.
+// It is safe for concurrent use.
+type QueryMonitor struct {
+	// health tracks recent latency and errors; it is fed even when metrics are disabled
+	health *HealthTracker
+
+	metricsEnabled atomic.Bool
+	explainEnabled atomic.Bool
+	slowThreshold  atomic.Int64
+
+	stats      map[string]*queryStats
+	statsMutex sync.Mutex
+
+	// db runs the EXPLAIN statements; set once the pool is open
+	db *sql.DB
+}
+
+// NewQueryMonitor creates a new QueryMonitor.
+//
+// Parameters:
+//   - metricsEnabled: Whether statistics are collected from the start
+//   - explainEnabled: Whether plans of slow queries are captured from the start
+//   - slowThreshold: The duration from which a query is slow
+//
+// Returns:
+//   - A configured QueryMonitor
+func NewQueryMonitor(metricsEnabled, explainEnabled bool, slowThreshold time.Duration) *QueryMonitor {
+	m := &QueryMonitor{
+		health: NewHealthTracker(constants.DBHealthWindow, constants.DBHealthBucket),
+		stats:  make(map[string]*queryStats),
+	}
+	m.metricsEnabled.Store(metricsEnabled)
+	m.explainEnabled.Store(explainEnabled)
+	m.slowThreshold.Store(int64(slowThreshold))
+	return m
+}
+
+// Settings returns the current runtime settings.
+func (m *QueryMonitor) Settings() QueryMonitorSettings {
+	return QueryMonitorSettings{
+		MetricsEnabled:       m.metricsEnabled.Load(),
+		ExplainEnabled:       m.explainEnabled.Load(),
+		SlowQueryThresholdMs: time.Duration(m.slowThreshold.Load()).Milliseconds(),
+	}
+}
+
+// Configure replaces the runtime settings.
+// Thresholds that are not positive are ignored.
+//
+// Parameters:
+//   - settings: The new settings
+func (m *QueryMonitor) Configure(settings QueryMonitorSettings) {
+	m.metricsEnabled.Store(settings.MetricsEnabled)
+	m.explainEnabled.Store(settings.ExplainEnabled)
+	if settings.SlowQueryThresholdMs > 0 {
+		m.slowThreshold.Store(int64(time.Duration(settings.SlowQueryThresholdMs) * time.Millisecond))
+	}
+}
+
+// Snapshot returns the statistics of all tracked queries, the most expensive first.
+func (m *QueryMonitor) Snapshot() []QueryStats {
+	m.statsMutex.Lock()
+	defer m.statsMutex.Unlock()
+
+	result := make([]QueryStats, 0, len(m.stats))
+	for query, stats := range m.stats {
+		entry := QueryStats{
+			Query:     query,
+			Calls:     stats.calls,
+			Errors:    stats.errors,
+			SlowCalls: stats.slowCalls,
+			Rows:      stats.rows,
+			TotalMs:   durationMs(stats.totalDuration),
+			MaxMs:     durationMs(stats.maxDuration),
+			LastPlan:  stats.lastPlan,
+		}
+		if stats.calls > 0 {
+			entry.MeanMs = entry.TotalMs / float64(stats.calls)
+		}
+		if !stats.lastPlanAt.IsZero() {
+			planAt := stats.lastPlanAt
+			entry.LastPlanAt = &planAt
+		}
+		result = append(result, entry)
+	}
+
+	sort.Slice(result, func(i, j int) bool {
+		return result[i].TotalMs > result[j].TotalMs
+	})
+
+	return result
+}
+
+// Health returns the latency and error rate of the queries run during the rolling window.
+func (m *QueryMonitor) Health() HealthStats {
+	return m.health.Stats()
+}
+
+// Reset discards all collected statistics.
+func (m *QueryMonitor) Reset() {
+	m.statsMutex.Lock()
+	defer m.statsMutex.Unlock()
+
+	m.stats = make(map[string]*queryStats)
+}
+
+// setDB sets the database used to run EXPLAIN statements.
+func (m *QueryMonitor) setDB(db *sql.DB) {
+	m.db = db
+}
+
+// record adds a finished query to the statistics and reports it if it was slow.
+//
+// Parameters:
+//   - ctx: The context the query ran with
+//   - query: The query text
+//   - args: The query arguments, used to capture the plan
+//   - duration: The time spent in the driver
+//   - rows: The number of rows returned or affected
+//   - err: The error the query failed with, if any
+func (m *QueryMonitor) record(ctx context.Context, query string, args []driver.NamedValue, duration time.Duration, rows int64, err error) {
+	if ctx.Value(explainContextKey{}) != nil {
+		return
+	}
+
+	// Queries cancelled by their caller say nothing about the database's health
+	if !errors.Is(err, context.Canceled) {
+		m.health.Observe(duration, err)
+	}
+
+	if !m.metricsEnabled.Load() {
+		return
+	}
+
+	key := normalizeQuery(query)
+	slow := duration >= time.Duration(m.slowThreshold.Load())
+
+	m.statsMutex.Lock()
+	stats, ok := m.stats[key]
+	if !ok && len(m.stats) < constants.MaxTrackedQueries {
+		stats = &queryStats{}
+		m.stats[key] = stats
+	}
+
+	explain := false
+	if stats != nil {
+		stats.calls++
+		stats.rows += rows
+		stats.totalDuration += duration
+		if duration > stats.maxDuration {
+			stats.maxDuration = duration
+		}
+		if err != nil {
+			stats.errors++
+		}
+		if slow {
+			stats.slowCalls++
+			explain = m.shouldExplain(stats, key, err)
+		}
+	}
+	m.statsMutex.Unlock()
+
+	if !slow {
+		return
+	}
+
+	diagnosticsLogger().Warn().
+		Str("query", key).
+		Dur("duration", duration).
+		Int64("rows", rows).
+		AnErr("query_error", err).
+		Msg("Slow query")
+
+	if explain {
+		go m.explain(key, query, args)
+	}
+}
+
+// shouldExplain decides whether the plan of a slow query is captured now and, if so,
+// marks the capture as in progress. The caller must hold statsMutex.
+func (m *QueryMonitor) shouldExplain(stats *queryStats, key string, err error) bool {
+	if !m.explainEnabled.Load() || m.db == nil || err != nil || stats.explaining {
+		return false
+	}
+	if !stats.lastPlanAt.IsZero() && time.Since(stats.lastPlanAt) < constants.SlowQueryExplainCooldown {
+		return false
+	}
+	if !isExplainable(key) {
+		return false
+	}
+
+	stats.explaining = true
+	return true
+}
+
+// explain captures and logs the plan of a slow query.
+// Only EXPLAIN without ANALYZE is used, so the query itself is never executed again.
+func (m *QueryMonitor) explain(key string, query string, args []driver.NamedValue) {
+	ctx, cancel := context.WithTimeout(context.Background(), constants.SlowQueryExplainTimeout)
+	defer cancel()
+	ctx = context.WithValue(ctx, explainContextKey{}, true)
+
+	values := make([]interface{}, len(args))
+	for i, arg := range args {
+		values[i] = arg.Value
+	}
+
+	plan, err := m.queryPlan(ctx, query, values)
+
+	m.statsMutex.Lock()
+	if stats, ok := m.stats[key]; ok {
+		stats.explaining = false
+		if err == nil {
+			stats.lastPlan = plan
+			stats.lastPlanAt = time.Now()
+		}
+	}
+	m.statsMutex.Unlock()
+
+	if err != nil {
+		diagnosticsLogger().Debug().Err(err).Str("query", key).Msg("Failed to capture slow query plan")
+		return
+	}
+
+	diagnosticsLogger().Warn().
+		Str("query", key).
+		Str("plan", plan).
+		Msg("Slow query plan")
+}
+
+// queryPlan runs EXPLAIN for a query and returns the plan as text.
+func (m *QueryMonitor) queryPlan(ctx context.Context, query string, args []interface{}) (string, error) {
+	rows, err := m.db.QueryContext(ctx, "EXPLAIN "+query, args...)
+	if err != nil {
+		return "", err
+	}
+	defer rows.Close()
+
+	var lines []string
+	for rows.Next() {
+		var line string
+		if err := rows.Scan(&line); err != nil {
+			return "", err
+		}
+		lines = append(lines, line)
+	}
+	if err := rows.Err(); err != nil {
+		return "", err
+	}
+
+	return strings.Join(lines, "\n"), nil
+}
+
+// diagnosticsLogger returns the logger of the database diagnostics channel.
+func diagnosticsLogger() *zerolog.Logger {
+	logger := log.With().Str("channel", constants.LogChannelDBDiagnostics).Logger()
+	return &logger
+}
+
+// normalizeQuery collapses whitespace so that the same query is always tracked under one key.
+func normalizeQuery(query string) string {
+	return strings.Join(strings.Fields(query), " ")
+}
+
+// isExplainable reports whether PostgreSQL can EXPLAIN a statement.
+func isExplainable(query string) bool {
+	keyword, _, _ := strings.Cut(query, " ")
+	switch strings.ToUpper(keyword) {
+	case "SELECT", "INSERT", "UPDATE", "DELETE", "WITH":
+		return true
+	default:
+		return false
+	}
+}
+
+// durationMs converts a duration to fractional milliseconds.
+func durationMs(d time.Duration) float64 {
+	return float64(d) / float64(time.Millisecond)
+}
@@ -0,0 +1,175 @@
+package database
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+)
+
+// dsnConnector opens connections of a driver by DSN, like sql.Open does.
+type dsnConnector struct {
+	dsn string
+	drv driver.Driver
+}
+
+func (c dsnConnector) Connect(context.Context) (driver.Conn, error) { return c.drv.Open(c.dsn) }
+func (c dsnConnector) Driver() driver.Driver                        { return c.drv }
+
+func TestQueryMonitor_Record(t *testing.T) {
+	monitor := NewQueryMonitor(true, false, 100*time.Millisecond)
+	ctx := context.Background()
+
+	monitor.record(ctx, "SELECT *\n\t FROM users WHERE id = $1", nil, 10*time.Millisecond, 1, nil)
+	monitor.record(ctx, "SELECT * FROM users WHERE id = $1", nil, 150*time.Millisecond, 0, nil)
+	monitor.record(ctx, "DELETE FROM sessions", nil, 5*time.Millisecond, 0, errors.New("boom"))
+
+	stats := monitor.Snapshot()
+	if len(stats) != 2 {
+		t.Fatalf("Snapshot() returned %d queries; want 2", len(stats))
+	}
+
+	// Whitespace is collapsed and the most expensive query comes first
+	users := stats[0]
+	if users.Query != "SELECT * FROM users WHERE id = $1" {
+		t.Errorf("Query = %q", users.Query)
+	}
+	if users.Calls != 2 || users.SlowCalls != 1 || users.Rows != 1 || users.MaxMs != 150 || users.MeanMs != 80 {
+		t.Errorf("Unexpected stats: %+v", users)
+	}
+	if stats[1].Errors != 1 {
+		t.Errorf("Errors = %d; want 1", stats[1].Errors)
+	}
+
+	monitor.Reset()
+	if len(monitor.Snapshot()) != 0 {
+		t.Error("Reset() did not discard the statistics")
+	}
+}
+
+func TestQueryMonitor_Configure(t *testing.T) {
+	monitor := NewQueryMonitor(false, false, time.Second)
+	ctx := context.Background()
+
+	// Nothing is collected while metrics are disabled
+	monitor.record(ctx, "SELECT 1", nil, time.Millisecond, 1, nil)
+	if len(monitor.Snapshot()) != 0 {
+		t.Error("Statistics were collected while metrics were disabled")
+	}
+
+	monitor.Configure(QueryMonitorSettings{MetricsEnabled: true, ExplainEnabled: true, SlowQueryThresholdMs: 20})
+	want := QueryMonitorSettings{MetricsEnabled: true, ExplainEnabled: true, SlowQueryThresholdMs: 20}
+	if got := monitor.Settings(); got != want {
+		t.Errorf("Settings() = %+v; want %+v", got, want)
+	}
+
+	// Thresholds that are not positive are ignored
+	monitor.Configure(QueryMonitorSettings{MetricsEnabled: true})
+	if got := monitor.Settings().SlowQueryThresholdMs; got != 20 {
+		t.Errorf("SlowQueryThresholdMs = %d; want 20", got)
+	}
+
+	// Queries issued by the monitor itself are not tracked
+	monitor.record(context.WithValue(ctx, explainContextKey{}, true), "EXPLAIN SELECT 1", nil, time.Second, 1, nil)
+	if len(monitor.Snapshot()) != 0 {
+		t.Error("EXPLAIN statements of the monitor were tracked")
+	}
+}
+
+func TestQueryMonitor_ExplainSlowQuery(t *testing.T) {
+	mockDB, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("Failed to create mock: %v", err)
+	}
+	defer mockDB.Close()
+
+	monitor := NewQueryMonitor(true, true, time.Millisecond)
+	monitor.setDB(mockDB)
+
+	mock.ExpectQuery(`EXPLAIN SELECT \* FROM documents WHERE user_id = \$1`).
+		WithArgs(int64(7)).
+		WillReturnRows(sqlmock.NewRows([]string{"QUERY PLAN"}).
+			AddRow("Seq Scan on documents").
+			AddRow("  Filter: (user_id = 7)"))
+
+	args := []driver.NamedValue{{Ordinal: 1, Value: int64(7)}}
+	monitor.record(context.Background(), "SELECT * FROM documents WHERE user_id = $1", args, time.Second, 3, nil)
+
+	// The plan is captured in the background
+	deadline := time.Now().Add(2 * time.Second)
+	for time.Now().Before(deadline) {
+		if stats := monitor.Snapshot(); len(stats) == 1 && stats[0].LastPlan != "" {
+			if stats[0].LastPlan != "Seq Scan on documents\n  Filter: (user_id = 7)" {
+				t.Errorf("LastPlan = %q", stats[0].LastPlan)
+			}
+			if err := mock.ExpectationsWereMet(); err != nil {
+				t.Errorf("Unfulfilled expectations: %v", err)
+			}
+			return
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+	t.Fatal("Plan of the slow query was not captured")
+}
+
+func TestInstrumentedConnector(t *testing.T) {
+	mockDB, mock, err := sqlmock.NewWithDSN("instrumented_connector_test")
+	if err != nil {
+		t.Fatalf("Failed to create mock: %v", err)
+	}
+	defer mockDB.Close()
+
+	monitor := NewQueryMonitor(true, false, time.Hour)
+	db := sql.OpenDB(newInstrumentedConnector(dsnConnector{dsn: "instrumented_connector_test", drv: mockDB.Driver()}, monitor))
+	defer db.Close()
+
+	mock.ExpectQuery("SELECT id FROM users").
+		WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(1).AddRow(2).AddRow(3))
+	mock.ExpectExec("UPDATE users SET role").
+		WillReturnResult(sqlmock.NewResult(0, 2))
+
+	rows, err := db.Query("SELECT id FROM users")
+	if err != nil {
+		t.Fatalf("Query() error = %v", err)
+	}
+	for rows.Next() {
+	}
+	rows.Close()
+
+	if _, err := db.Exec("UPDATE users SET role = 'user'"); err != nil {
+		t.Fatalf("Exec() error = %v", err)
+	}
+
+	rowCounts := make(map[string]int64)
+	for _, stats := range monitor.Snapshot() {
+		if stats.Calls != 1 {
+			t.Errorf("Calls of %q = %d; want 1", stats.Query, stats.Calls)
+		}
+		rowCounts[stats.Query] = stats.Rows
+	}
+	if rowCounts["SELECT id FROM users"] != 3 {
+		t.Errorf("Rows of the query = %d; want 3", rowCounts["SELECT id FROM users"])
+	}
+	if rowCounts["UPDATE users SET role = 'user'"] != 2 {
+		t.Errorf("Rows of the update = %d; want 2", rowCounts["UPDATE users SET role = 'user'"])
+	}
+}
+
+func TestIsExplainable(t *testing.T) {
+	tests := map[string]bool{
+		"SELECT 1":                       true,
+		"with t as (select 1) select *":  true,
+		"UPDATE users SET role = $1":     true,
+		"BEGIN":                          false,
+		"CREATE INDEX idx ON t (column)": false,
+	}
+	for query, want := range tests {
+		if got := isExplainable(query); got != want {
+			t.Errorf("isExplainable(%q) = %v; want %v", query, got, want)
+		}
+	}
+}
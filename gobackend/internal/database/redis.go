@@ -0,0 +1,255 @@
+// Package database provides database access and management functions for the HideMe API.
+//
+// This file implements a small Redis client speaking the RESP protocol. It supports
+// exactly what the application needs from Redis (running commands and Lua scripts)
+// over a pool of reusable connections, without pulling in a full client library.
+package database
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/yasinhessnawi1/Hideme_Backend/internal/constants"
+)
+
+// RedisError is an error reply returned by the Redis server.
+type RedisError string
+
+// Error implements the error interface.
+func (e RedisError) Error() string {
+	return "redis: " + string(e)
+}
+
+// redisConn is a single connection to Redis with its buffered reader.
+type redisConn struct {
+	net.Conn
+	reader *bufio.Reader
+}
+
+// RedisClient is a minimal, concurrency-safe Redis client.
+type RedisClient struct {
+	addr     string
+	password string
+	db       int
+	idle     chan *redisConn
+}
+
+// NewRedisClient creates a Redis client for a redis:// URL.
+// Connections are established lazily, so an unreachable server is only
+// reported once a command is run.
+//
+// Parameters:
+//   - rawURL: The server URL, e.g. redis://:password@localhost:6379/0
+//   - poolSize: The maximum number of idle connections kept open
+//
+// Returns:
+//   - A configured RedisClient
+//   - An error if the URL is invalid
+func NewRedisClient(rawURL string, poolSize int) (*RedisClient, error) {
+	parsed, err := url.Parse(rawURL)
+	if err != nil {
+		return nil, fmt.Errorf("invalid redis URL: %w", err)
+	}
+	if parsed.Scheme != "redis" {
+		return nil, fmt.Errorf("invalid redis URL scheme: %s", parsed.Scheme)
+	}
+
+	client := &RedisClient{
+		addr: parsed.Host,
+		idle: make(chan *redisConn, poolSize),
+	}
+
+	if parsed.Port() == "" {
+		client.addr = net.JoinHostPort(parsed.Hostname(), "6379")
+	}
+
+	if parsed.User != nil {
+		client.password, _ = parsed.User.Password()
+	}
+
+	if dbPath := strings.TrimPrefix(parsed.Path, "/"); dbPath != "" {
+		client.db, err = strconv.Atoi(dbPath)
+		if err != nil {
+			return nil, fmt.Errorf("invalid redis database number: %s", dbPath)
+		}
+	}
+
+	return client, nil
+}
+
+// Do runs a command and returns its reply.
+// Replies are returned as string, int64, []any or nil; error replies are returned as RedisError.
+//
+// Parameters:
+//   - ctx: Context for cancellation; its deadline bounds the command
+//   - args: The command and its arguments
+//
+// Returns:
+//   - The reply value
+//   - An error if the command fails
+func (c *RedisClient) Do(ctx context.Context, args ...string) (any, error) {
+	conn, err := c.get(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	deadline, ok := ctx.Deadline()
+	if !ok {
+		deadline = time.Now().Add(constants.RedisOperationTimeout)
+	}
+	if err := conn.SetDeadline(deadline); err != nil {
+		conn.Close()
+		return nil, fmt.Errorf("failed to set redis deadline: %w", err)
+	}
+
+	reply, err := conn.do(args)
+
+	// Connections are only reused after a clean exchange; error replies leave them usable
+	var redisErr RedisError
+	if err != nil && !errors.As(err, &redisErr) {
+		conn.Close()
+		return nil, err
+	}
+	c.put(conn)
+
+	return reply, err
+}
+
+// Close closes all idle connections.
+func (c *RedisClient) Close() error {
+	for {
+		select {
+		case conn := <-c.idle:
+			conn.Close()
+		default:
+			return nil
+		}
+	}
+}
+
+// get returns an idle connection or dials a new one.
+func (c *RedisClient) get(ctx context.Context) (*redisConn, error) {
+	select {
+	case conn := <-c.idle:
+		return conn, nil
+	default:
+	}
+
+	dialer := net.Dialer{Timeout: constants.RedisDialTimeout}
+	netConn, err := dialer.DialContext(ctx, "tcp", c.addr)
+	if err != nil {
+		return nil, fmt.Errorf("failed to connect to redis: %w", err)
+	}
+
+	conn := &redisConn{Conn: netConn, reader: bufio.NewReader(netConn)}
+	if err := conn.SetDeadline(time.Now().Add(constants.RedisDialTimeout)); err != nil {
+		conn.Close()
+		return nil, fmt.Errorf("failed to set redis deadline: %w", err)
+	}
+
+	if c.password != "" {
+		if _, err := conn.do([]string{"AUTH", c.password}); err != nil {
+			conn.Close()
+			return nil, fmt.Errorf("failed to authenticate with redis: %w", err)
+		}
+	}
+
+	if c.db != 0 {
+		if _, err := conn.do([]string{"SELECT", strconv.Itoa(c.db)}); err != nil {
+			conn.Close()
+			return nil, fmt.Errorf("failed to select redis database: %w", err)
+		}
+	}
+
+	return conn, nil
+}
+
+// put returns a connection to the idle pool, closing it if the pool is full.
+func (c *RedisClient) put(conn *redisConn) {
+	select {
+	case c.idle <- conn:
+	default:
+		conn.Close()
+	}
+}
+
+// do writes a command and reads its reply.
+func (conn *redisConn) do(args []string) (any, error) {
+	var command strings.Builder
+	fmt.Fprintf(&command, "*%d\r\n", len(args))
+	for _, arg := range args {
+		fmt.Fprintf(&command, "$%d\r\n%s\r\n", len(arg), arg)
+	}
+
+	if _, err := io.WriteString(conn, command.String()); err != nil {
+		return nil, fmt.Errorf("failed to write redis command: %w", err)
+	}
+
+	return conn.readReply()
+}
+
+// readReply reads a single RESP reply.
+func (conn *redisConn) readReply() (any, error) {
+	line, err := conn.reader.ReadString('\n')
+	if err != nil {
+		return nil, fmt.Errorf("failed to read redis reply: %w", err)
+	}
+	line = strings.TrimSuffix(line, "\r\n")
+	if line == "" {
+		return nil, errors.New("redis: empty reply")
+	}
+
+	switch line[0] {
+	case '+':
+		return line[1:], nil
+	case '-':
+		return nil, RedisError(line[1:])
+	case ':':
+		return strconv.ParseInt(line[1:], 10, 64)
+	case '$':
+		size, err := strconv.Atoi(line[1:])
+		if err != nil {
+			return nil, fmt.Errorf("redis: invalid bulk length: %w", err)
+		}
+		if size < 0 {
+			return nil, nil
+		}
+		buf := make([]byte, size+2)
+		if _, err := io.ReadFull(conn.reader, buf); err != nil {
+			return nil, fmt.Errorf("failed to read redis reply: %w", err)
+		}
+		return string(buf[:size]), nil
+	case '*':
+		count, err := strconv.Atoi(line[1:])
+		if err != nil {
+			return nil, fmt.Errorf("redis: invalid array length: %w", err)
+		}
+		if count < 0 {
+			return nil, nil
+		}
+		items := make([]any, count)
+		for i := range items {
+			item, err := conn.readReply()
+			if err != nil {
+				// Error replies inside arrays are returned as values
+				var redisErr RedisError
+				if !errors.As(err, &redisErr) {
+					return nil, err
+				}
+				item = redisErr
+			}
+			items[i] = item
+		}
+		return items, nil
+	default:
+		return nil, fmt.Errorf("redis: unexpected reply type %q", line[0])
+	}
+}
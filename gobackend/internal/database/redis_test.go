@@ -0,0 +1,213 @@
+package database
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"io"
+	"net"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+)
+
+// fakeRedis is a TCP server speaking just enough RESP for tests.
+// It records the commands it receives and answers each with the raw reply returned by reply.
+type fakeRedis struct {
+	listener net.Listener
+	reply    func(args []string) string
+
+	mu       sync.Mutex
+	commands [][]string
+	dials    int
+}
+
+func newFakeRedis(t *testing.T, reply func(args []string) string) *fakeRedis {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("Failed to listen: %v", err)
+	}
+
+	server := &fakeRedis{listener: listener, reply: reply}
+	go server.serve()
+	t.Cleanup(func() { listener.Close() })
+
+	return server
+}
+
+func (f *fakeRedis) serve() {
+	for {
+		conn, err := f.listener.Accept()
+		if err != nil {
+			return
+		}
+		f.mu.Lock()
+		f.dials++
+		f.mu.Unlock()
+		go f.handle(conn)
+	}
+}
+
+func (f *fakeRedis) handle(conn net.Conn) {
+	defer conn.Close()
+	reader := bufio.NewReader(conn)
+
+	for {
+		line, err := reader.ReadString('\n')
+		if err != nil {
+			return
+		}
+		count, _ := strconv.Atoi(strings.TrimSpace(line[1:]))
+
+		args := make([]string, count)
+		for i := range args {
+			header, err := reader.ReadString('\n')
+			if err != nil {
+				return
+			}
+			size, _ := strconv.Atoi(strings.TrimSpace(header[1:]))
+			buf := make([]byte, size+2)
+			if _, err := io.ReadFull(reader, buf); err != nil {
+				return
+			}
+			args[i] = string(buf[:size])
+		}
+
+		f.mu.Lock()
+		f.commands = append(f.commands, args)
+		f.mu.Unlock()
+
+		if _, err := io.WriteString(conn, f.reply(args)); err != nil {
+			return
+		}
+	}
+}
+
+func (f *fakeRedis) url() string {
+	return "redis://" + f.listener.Addr().String()
+}
+
+func TestNewRedisClient(t *testing.T) {
+	tests := []struct {
+		name     string
+		url      string
+		addr     string
+		password string
+		db       int
+		wantErr  bool
+	}{
+		{name: "Host and port", url: "redis://cache:6380", addr: "cache:6380"},
+		{name: "Default port", url: "redis://cache", addr: "cache:6379"},
+		{name: "Password and database", url: "redis://:secret@cache:6379/2", addr: "cache:6379", password: "secret", db: 2},
+		{name: "Wrong scheme", url: "http://cache:6379", wantErr: true},
+		{name: "Invalid database", url: "redis://cache:6379/abc", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			client, err := NewRedisClient(tt.url, 1)
+			if tt.wantErr {
+				if err == nil {
+					t.Fatal("Expected an error")
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+			if client.addr != tt.addr || client.password != tt.password || client.db != tt.db {
+				t.Errorf("Got addr=%s password=%s db=%d", client.addr, client.password, client.db)
+			}
+		})
+	}
+}
+
+func TestRedisClient_Do(t *testing.T) {
+	server := newFakeRedis(t, func(args []string) string {
+		switch strings.ToUpper(args[0]) {
+		case "AUTH", "SELECT", "SET":
+			return "+OK\r\n"
+		case "GET":
+			if args[1] == "missing" {
+				return "$-1\r\n"
+			}
+			return "$5\r\nhello\r\n"
+		case "INCR":
+			return ":42\r\n"
+		case "EVAL":
+			return "*2\r\n:1\r\n$3\r\nabc\r\n"
+		default:
+			return "-ERR unknown command\r\n"
+		}
+	})
+
+	client, err := NewRedisClient("redis://:secret@"+server.listener.Addr().String()+"/3", 2)
+	if err != nil {
+		t.Fatalf("Failed to create client: %v", err)
+	}
+	defer client.Close()
+
+	ctx := context.Background()
+
+	reply, err := client.Do(ctx, "SET", "key", "value")
+	if err != nil || reply != "OK" {
+		t.Errorf("SET returned %v, %v", reply, err)
+	}
+
+	reply, err = client.Do(ctx, "GET", "key")
+	if err != nil || reply != "hello" {
+		t.Errorf("GET returned %v, %v", reply, err)
+	}
+
+	reply, err = client.Do(ctx, "GET", "missing")
+	if err != nil || reply != nil {
+		t.Errorf("GET of a missing key returned %v, %v", reply, err)
+	}
+
+	reply, err = client.Do(ctx, "INCR", "counter")
+	if err != nil || reply != int64(42) {
+		t.Errorf("INCR returned %v, %v", reply, err)
+	}
+
+	reply, err = client.Do(ctx, "EVAL", "return 1", "0")
+	items, ok := reply.([]any)
+	if err != nil || !ok || len(items) != 2 || items[0] != int64(1) || items[1] != "abc" {
+		t.Errorf("EVAL returned %v, %v", reply, err)
+	}
+
+	_, err = client.Do(ctx, "BOGUS")
+	var redisErr RedisError
+	if !errors.As(err, &redisErr) {
+		t.Errorf("Expected a RedisError, got %v", err)
+	}
+
+	server.mu.Lock()
+	defer server.mu.Unlock()
+
+	// The connection is reused, so AUTH and SELECT only run once
+	if server.dials != 1 {
+		t.Errorf("Expected 1 connection, got %d", server.dials)
+	}
+	if len(server.commands) < 2 || server.commands[0][0] != "AUTH" || server.commands[1][0] != "SELECT" || server.commands[1][1] != "3" {
+		t.Errorf("Expected AUTH and SELECT on connect, got %v", server.commands)
+	}
+}
+
+func TestRedisClient_Do_Unreachable(t *testing.T) {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("Failed to listen: %v", err)
+	}
+	addr := listener.Addr().String()
+	listener.Close()
+
+	client, err := NewRedisClient("redis://"+addr, 1)
+	if err != nil {
+		t.Fatalf("Failed to create client: %v", err)
+	}
+
+	if _, err := client.Do(context.Background(), "PING"); err == nil {
+		t.Error("Expected an error for an unreachable server")
+	}
+}
@@ -0,0 +1,69 @@
+// Package database provides database access and management functions for the HideMe API.
+// This file lets a transaction be carried by a context, so that changes made through
+// several repositories commit or roll back together.
+package database
+
+import (
+	"context"
+	"database/sql"
+)
+
+// txContextKey is the context key of the transaction started by WithTransaction.
+type txContextKey struct{}
+
+// transactionFrom returns the transaction carried by a context, or nil if there is none.
+func transactionFrom(ctx context.Context) *sql.Tx {
+	tx, _ := ctx.Value(txContextKey{}).(*sql.Tx)
+	return tx
+}
+
+// WithTransaction runs fn in a database transaction carried by the context passed to it.
+// Queries made through the pool with that context, including those in nested calls to
+// Transaction, run in the transaction, which commits once fn returns nil and rolls back
+// otherwise. If ctx already carries a transaction, fn joins it.
+//
+// A transaction runs on one connection, so fn must close the rows of a query before it
+// starts the next one.
+//
+// Parameters:
+//   - ctx: The context for the transaction
+//   - fn: The function to execute within the transaction
+//
+// Returns:
+//   - An error if the transaction fails or the function returns an error
+func (p *Pool) WithTransaction(ctx context.Context, fn func(ctx context.Context) error) error {
+	if transactionFrom(ctx) != nil {
+		return fn(ctx)
+	}
+
+	return p.Transaction(ctx, func(tx *sql.Tx) error {
+		return fn(context.WithValue(ctx, txContextKey{}, tx))
+	})
+}
+
+// ExecContext executes a query without returning any rows, in the transaction carried
+// by ctx if there is one.
+func (p *Pool) ExecContext(ctx context.Context, query string, args ...interface{}) (sql.Result, error) {
+	if tx := transactionFrom(ctx); tx != nil {
+		return tx.ExecContext(ctx, query, args...)
+	}
+	return p.DB.ExecContext(ctx, query, args...)
+}
+
+// QueryContext executes a query that returns rows, in the transaction carried by ctx
+// if there is one.
+func (p *Pool) QueryContext(ctx context.Context, query string, args ...interface{}) (*sql.Rows, error) {
+	if tx := transactionFrom(ctx); tx != nil {
+		return tx.QueryContext(ctx, query, args...)
+	}
+	return p.DB.QueryContext(ctx, query, args...)
+}
+
+// QueryRowContext executes a query that is expected to return at most one row, in the
+// transaction carried by ctx if there is one.
+func (p *Pool) QueryRowContext(ctx context.Context, query string, args ...interface{}) *sql.Row {
+	if tx := transactionFrom(ctx); tx != nil {
+		return tx.QueryRowContext(ctx, query, args...)
+	}
+	return p.DB.QueryRowContext(ctx, query, args...)
+}
@@ -0,0 +1,73 @@
+// Package database provides database access and management functions for the HideMe API.
+//
+// This file implements the wait for dependencies at startup, so the server keeps retrying
+// while the database or cache is still starting instead of exiting at once.
+package database
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/rs/zerolog/log"
+
+	"github.com/yasinhessnawi1/Hideme_Backend/internal/config"
+)
+
+// WaitFor runs connect until it succeeds, so the server can start while its dependencies
+// are still booting. Failed attempts are retried with exponential backoff and logged,
+// until the maximum wait of the settings has passed.
+//
+// Parameters:
+//   - ctx: Context for cancellation of the wait
+//   - name: The name of the dependency, used in logs and errors
+//   - settings: The backoff and maximum wait; with FailFast, connect runs only once
+//   - connect: Connects to the dependency
+//
+// Returns:
+//   - nil once connect succeeds
+//   - The last error of connect if the maximum wait has passed or ctx is done
+func WaitFor(ctx context.Context, name string, settings *config.StartupSettings, connect func(ctx context.Context) error) error {
+	start := time.Now()
+	backoff := settings.InitialBackoff
+	for attempt := 1; ; attempt++ {
+		err := connect(ctx)
+		if err == nil {
+			if attempt > 1 {
+				log.Info().
+					Str("dependency", name).
+					Int("attempts", attempt).
+					Dur("waited", time.Since(start)).
+					Msg("Dependency became reachable")
+			}
+			return nil
+		}
+
+		if settings.FailFast {
+			return err
+		}
+		remaining := settings.MaxWait - time.Since(start)
+		if remaining <= 0 {
+			return fmt.Errorf("%s not reachable after %d attempts in %s: %w", name, attempt, settings.MaxWait, err)
+		}
+
+		wait := min(backoff, remaining)
+		log.Warn().
+			Err(err).
+			Str("dependency", name).
+			Int("attempt", attempt).
+			Dur("retry_in", wait).
+			Dur("remaining", remaining).
+			Msg("Dependency not reachable yet, retrying")
+
+		timer := time.NewTimer(wait)
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return fmt.Errorf("stopped waiting for %s: %w", name, err)
+		case <-timer.C:
+		}
+
+		backoff = min(backoff*2, settings.MaxBackoff)
+	}
+}
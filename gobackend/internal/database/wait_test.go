@@ -0,0 +1,75 @@
+package database
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/yasinhessnawi1/Hideme_Backend/internal/config"
+)
+
+func TestWaitFor(t *testing.T) {
+	settings := &config.StartupSettings{
+		MaxWait:        200 * time.Millisecond,
+		InitialBackoff: time.Millisecond,
+		MaxBackoff:     4 * time.Millisecond,
+	}
+	errNotReady := errors.New("connection refused")
+
+	t.Run("Retries until the dependency is reachable", func(t *testing.T) {
+		attempts := 0
+		err := WaitFor(context.Background(), "database", settings, func(ctx context.Context) error {
+			attempts++
+			if attempts < 4 {
+				return errNotReady
+			}
+			return nil
+		})
+		if err != nil {
+			t.Fatalf("Expected no error, got %v", err)
+		}
+		if attempts != 4 {
+			t.Errorf("Expected 4 attempts, got %d", attempts)
+		}
+	})
+
+	t.Run("Gives up after the maximum wait", func(t *testing.T) {
+		short := *settings
+		short.MaxWait = 20 * time.Millisecond
+		start := time.Now()
+		err := WaitFor(context.Background(), "database", &short, func(ctx context.Context) error {
+			return errNotReady
+		})
+		if !errors.Is(err, errNotReady) {
+			t.Errorf("Expected the last connection error, got %v", err)
+		}
+		if elapsed := time.Since(start); elapsed < short.MaxWait {
+			t.Errorf("Expected to wait at least %s, gave up after %s", short.MaxWait, elapsed)
+		}
+	})
+
+	t.Run("Fail fast", func(t *testing.T) {
+		failFast := *settings
+		failFast.FailFast = true
+		attempts := 0
+		err := WaitFor(context.Background(), "redis", &failFast, func(ctx context.Context) error {
+			attempts++
+			return errNotReady
+		})
+		if !errors.Is(err, errNotReady) || attempts != 1 {
+			t.Errorf("Expected one failed attempt, got %d (err %v)", attempts, err)
+		}
+	})
+
+	t.Run("Stops when the context is done", func(t *testing.T) {
+		ctx, cancel := context.WithCancel(context.Background())
+		err := WaitFor(ctx, "redis", settings, func(ctx context.Context) error {
+			cancel()
+			return errNotReady
+		})
+		if !errors.Is(err, errNotReady) {
+			t.Errorf("Expected the last connection error, got %v", err)
+		}
+	})
+}
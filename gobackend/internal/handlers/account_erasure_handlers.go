@@ -0,0 +1,219 @@
+// Package handlers provides HTTP request handlers for the HideMe API.
+package handlers
+
+import (
+	"context"
+	"net/http"
+
+	"github.com/yasinhessnawi1/Hideme_Backend/internal/auth"
+	"github.com/yasinhessnawi1/Hideme_Backend/internal/constants"
+	"github.com/yasinhessnawi1/Hideme_Backend/internal/models"
+	"github.com/yasinhessnawi1/Hideme_Backend/internal/utils"
+)
+
+// AccountErasureServiceInterface defines the service methods required for erasing accounts.
+type AccountErasureServiceInterface interface {
+	// RequestErasure schedules the erasure of a user's account after the grace period.
+	//
+	// Parameters:
+	//   - ctx: Context for the operation
+	//   - userID: The ID of the user
+	//   - password: The current password of the user, confirming the request
+	//
+	// Returns:
+	//   - The scheduled erasure
+	//   - UnauthorizedError if the password is incorrect
+	//   - A conflict error if the account is already scheduled for erasure
+	RequestErasure(ctx context.Context, userID int64, password string) (*models.AccountErasure, error)
+
+	// GetErasure returns the unfinished erasure of a user's account.
+	//
+	// Parameters:
+	//   - ctx: Context for the operation
+	//   - userID: The ID of the user
+	//
+	// Returns:
+	//   - The scheduled or running erasure
+	//   - NotFoundError if the account is not scheduled for erasure
+	GetErasure(ctx context.Context, userID int64) (*models.AccountErasure, error)
+
+	// CancelErasure cancels the erasure of a user's account during the grace period.
+	//
+	// Parameters:
+	//   - ctx: Context for the operation
+	//   - userID: The ID of the user
+	//
+	// Returns:
+	//   - NotFoundError if the account is not scheduled for erasure
+	//   - A conflict error if the erasure has already started
+	CancelErasure(ctx context.Context, userID int64) error
+}
+
+// AccountErasureHandler handles HTTP requests for erasing the caller's account.
+type AccountErasureHandler struct {
+	erasureService AccountErasureServiceInterface
+}
+
+// NewAccountErasureHandler creates a new AccountErasureHandler with the provided erasure service.
+//
+// Parameters:
+//   - erasureService: Service scheduling and running the erasures
+//
+// Returns:
+//   - A properly initialized AccountErasureHandler
+func NewAccountErasureHandler(erasureService AccountErasureServiceInterface) *AccountErasureHandler {
+	return &AccountErasureHandler{
+		erasureService: erasureService,
+	}
+}
+
+// DeleteAccount schedules the erasure of the current user's account. The account and all
+// data kept about it are erased in the background once the grace period has passed; until
+// then the user can sign in and cancel the erasure.
+//
+// HTTP Method:
+//   - DELETE
+//
+// URL Path:
+//   - /api/users/me
+//
+// Requires:
+//   - Authentication: User must be logged in
+//
+// Request Body:
+//   - JSON object conforming to models.AccountErasureRequest
+//
+// Responses:
+//   - 202 Accepted: Erasure scheduled
+//   - 400 Bad Request: Invalid request body or confirmation
+//   - 401 Unauthorized: User not authenticated or password incorrect
+//   - 409 Conflict: Account already scheduled for erasure
+//   - 500 Internal Server Error: Server-side error
+//
+// Security:
+//   - Requires the current password to verify user identity
+//   - Requires explicit confirmation text to prevent accidental deletion
+//
+// @Summary Delete account
+// @Description Schedules the erasure of the account of the currently authenticated user after a grace period
+// @Tags Users
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param request body models.AccountErasureRequest true "Password and the confirmation DELETE"
+// @Success 202 {object} utils.Response{data=map[string]interface{}} "Erasure scheduled"
+// @Failure 400 {object} utils.Response{error=string} "Invalid request body or confirmation"
+// @Failure 401 {object} utils.Response{error=string} "User not authenticated or password incorrect"
+// @Failure 409 {object} utils.Response{error=string} "Account already scheduled for erasure"
+// @Failure 500 {object} utils.Response{error=string} "Server error"
+// @Router /users/me [delete]
+func (h *AccountErasureHandler) DeleteAccount(w http.ResponseWriter, r *http.Request) {
+	userID, ok := auth.GetUserID(r)
+	if !ok {
+		utils.Unauthorized(w, constants.MsgAuthRequired)
+		return
+	}
+
+	var req models.AccountErasureRequest
+	if err := utils.DecodeAndValidate(r, &req); err != nil {
+		utils.ErrorFromAppError(w, utils.ParseError(err))
+		return
+	}
+
+	erasure, err := h.erasureService.RequestErasure(r.Context(), userID, req.Password)
+	if err != nil {
+		utils.ErrorFromAppError(w, utils.ParseError(err))
+		return
+	}
+
+	utils.JSON(w, constants.StatusAccepted, map[string]interface{}{
+		"message": constants.MsgAccountErasureScheduled,
+		"erasure": erasure,
+	})
+}
+
+// GetMyErasure returns the scheduled erasure of the current user's account.
+//
+// HTTP Method:
+//   - GET
+//
+// URL Path:
+//   - /api/users/me/erasure
+//
+// Requires:
+//   - Authentication: User must be logged in
+//
+// Responses:
+//   - 200 OK: The scheduled erasure
+//   - 401 Unauthorized: User not authenticated
+//   - 404 Not Found: Account not scheduled for erasure
+//   - 500 Internal Server Error: Server-side error
+//
+// @Summary Get account erasure
+// @Description Returns when the account of the currently authenticated user will be erased
+// @Tags Users
+// @Produce json
+// @Security BearerAuth
+// @Success 200 {object} utils.Response{data=models.AccountErasure} "The scheduled erasure"
+// @Failure 401 {object} utils.Response{error=string} "User not authenticated"
+// @Failure 404 {object} utils.Response{error=string} "Account not scheduled for erasure"
+// @Failure 500 {object} utils.Response{error=string} "Server error"
+// @Router /users/me/erasure [get]
+func (h *AccountErasureHandler) GetMyErasure(w http.ResponseWriter, r *http.Request) {
+	userID, ok := auth.GetUserID(r)
+	if !ok {
+		utils.Unauthorized(w, constants.MsgAuthRequired)
+		return
+	}
+
+	erasure, err := h.erasureService.GetErasure(r.Context(), userID)
+	if err != nil {
+		utils.ErrorFromAppError(w, utils.ParseError(err))
+		return
+	}
+
+	utils.JSON(w, constants.StatusOK, erasure)
+}
+
+// CancelMyErasure cancels the erasure of the current user's account during the grace period.
+//
+// HTTP Method:
+//   - DELETE
+//
+// URL Path:
+//   - /api/users/me/erasure
+//
+// Requires:
+//   - Authentication: User must be logged in
+//
+// Responses:
+//   - 204 No Content: Erasure cancelled
+//   - 401 Unauthorized: User not authenticated
+//   - 404 Not Found: Account not scheduled for erasure
+//   - 409 Conflict: The erasure has already started
+//   - 500 Internal Server Error: Server-side error
+//
+// @Summary Cancel account erasure
+// @Description Keeps the account of the currently authenticated user during the grace period
+// @Tags Users
+// @Security BearerAuth
+// @Success 204 "Erasure cancelled"
+// @Failure 401 {object} utils.Response{error=string} "User not authenticated"
+// @Failure 404 {object} utils.Response{error=string} "Account not scheduled for erasure"
+// @Failure 409 {object} utils.Response{error=string} "Erasure already started"
+// @Failure 500 {object} utils.Response{error=string} "Server error"
+// @Router /users/me/erasure [delete]
+func (h *AccountErasureHandler) CancelMyErasure(w http.ResponseWriter, r *http.Request) {
+	userID, ok := auth.GetUserID(r)
+	if !ok {
+		utils.Unauthorized(w, constants.MsgAuthRequired)
+		return
+	}
+
+	if err := h.erasureService.CancelErasure(r.Context(), userID); err != nil {
+		utils.ErrorFromAppError(w, utils.ParseError(err))
+		return
+	}
+
+	utils.NoContent(w)
+}
@@ -0,0 +1,187 @@
+package handlers_test
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+
+	"github.com/yasinhessnawi1/Hideme_Backend/internal/constants"
+	"github.com/yasinhessnawi1/Hideme_Backend/internal/handlers"
+	"github.com/yasinhessnawi1/Hideme_Backend/internal/models"
+	"github.com/yasinhessnawi1/Hideme_Backend/internal/testsupport/fixtures"
+	"github.com/yasinhessnawi1/Hideme_Backend/internal/testsupport/golden"
+	"github.com/yasinhessnawi1/Hideme_Backend/internal/utils"
+)
+
+// MockAccountErasureService is a mock implementation of the AccountErasureService
+type MockAccountErasureService struct {
+	mock.Mock
+}
+
+func (m *MockAccountErasureService) RequestErasure(ctx context.Context, userID int64, password string) (*models.AccountErasure, error) {
+	args := m.Called(ctx, userID, password)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*models.AccountErasure), args.Error(1)
+}
+
+func (m *MockAccountErasureService) GetErasure(ctx context.Context, userID int64) (*models.AccountErasure, error) {
+	args := m.Called(ctx, userID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*models.AccountErasure), args.Error(1)
+}
+
+func (m *MockAccountErasureService) CancelErasure(ctx context.Context, userID int64) error {
+	args := m.Called(ctx, userID)
+	return args.Error(0)
+}
+
+func setupAccountErasureTest() (*chi.Mux, *MockAccountErasureService) {
+	mockService := new(MockAccountErasureService)
+	handler := handlers.NewAccountErasureHandler(mockService)
+
+	router := chi.NewRouter()
+	router.Delete("/api/users/me", handler.DeleteAccount)
+	router.Get("/api/users/me/erasure", handler.GetMyErasure)
+	router.Delete("/api/users/me/erasure", handler.CancelMyErasure)
+	return router, mockService
+}
+
+func newDeleteAccountRequest(t *testing.T, password, confirm string) *http.Request {
+	body, err := json.Marshal(map[string]string{"password": password, "confirm": confirm})
+	require.NoError(t, err)
+
+	req, err := http.NewRequest("DELETE", "/api/users/me", bytes.NewBuffer(body))
+	require.NoError(t, err)
+	req.Header.Set("Content-Type", "application/json")
+	return req.WithContext(createAuthContext(1001))
+}
+
+func TestDeleteAccount(t *testing.T) {
+	router, mockService := setupAccountErasureTest()
+
+	t.Run("Success", func(t *testing.T) {
+		erasure := models.NewAccountErasure(1001, time.Date(2026, 3, 1, 12, 0, 0, 0, time.UTC))
+		erasure.ID = 3
+		mockService.On("RequestErasure", mock.Anything, int64(1001), "password123").Return(erasure, nil).Once()
+
+		rr := httptest.NewRecorder()
+		router.ServeHTTP(rr, newDeleteAccountRequest(t, "password123", "DELETE"))
+
+		golden.AssertResponse(t, rr)
+	})
+
+	t.Run("Invalid Confirmation", func(t *testing.T) {
+		rr := httptest.NewRecorder()
+		router.ServeHTTP(rr, newDeleteAccountRequest(t, "password123", "Wrong"))
+
+		golden.AssertResponse(t, rr)
+	})
+
+	t.Run("Wrong Password", func(t *testing.T) {
+		mockService.On("RequestErasure", mock.Anything, int64(1001), "wrong").
+			Return(nil, utils.NewUnauthorizedError("Current password is incorrect")).Once()
+
+		rr := httptest.NewRecorder()
+		router.ServeHTTP(rr, newDeleteAccountRequest(t, "wrong", "DELETE"))
+
+		assert.Equal(t, http.StatusUnauthorized, rr.Code)
+	})
+
+	t.Run("Already Scheduled", func(t *testing.T) {
+		mockService.On("RequestErasure", mock.Anything, int64(1001), "password123").
+			Return(nil, utils.New(utils.ErrDuplicate, constants.StatusConflict, constants.MsgAccountErasureActive)).Once()
+
+		rr := httptest.NewRecorder()
+		router.ServeHTTP(rr, newDeleteAccountRequest(t, "password123", "DELETE"))
+
+		assert.Equal(t, http.StatusConflict, rr.Code)
+	})
+
+	t.Run("Unauthenticated", func(t *testing.T) {
+		req, err := http.NewRequest("DELETE", "/api/users/me", bytes.NewBufferString(`{"password":"password123","confirm":"DELETE"}`))
+		require.NoError(t, err)
+
+		rr := httptest.NewRecorder()
+		router.ServeHTTP(rr, req)
+
+		assert.Equal(t, http.StatusUnauthorized, rr.Code)
+	})
+
+	mockService.AssertExpectations(t)
+}
+
+func TestGetMyErasure(t *testing.T) {
+	router, mockService := setupAccountErasureTest()
+
+	t.Run("Scheduled", func(t *testing.T) {
+		erasure := models.NewAccountErasure(1001, fixtures.Time)
+		mockService.On("GetErasure", mock.Anything, int64(1001)).Return(erasure, nil).Once()
+
+		req, err := http.NewRequest("GET", "/api/users/me/erasure", nil)
+		require.NoError(t, err)
+		req = req.WithContext(createAuthContext(1001))
+
+		rr := httptest.NewRecorder()
+		router.ServeHTTP(rr, req)
+
+		golden.AssertResponse(t, rr)
+	})
+
+	t.Run("Not Scheduled", func(t *testing.T) {
+		mockService.On("GetErasure", mock.Anything, int64(1001)).
+			Return(nil, utils.NewNotFoundError("AccountErasure", int64(1001))).Once()
+
+		req, err := http.NewRequest("GET", "/api/users/me/erasure", nil)
+		require.NoError(t, err)
+		req = req.WithContext(createAuthContext(1001))
+
+		rr := httptest.NewRecorder()
+		router.ServeHTTP(rr, req)
+
+		assert.Equal(t, http.StatusNotFound, rr.Code)
+	})
+}
+
+func TestCancelMyErasure(t *testing.T) {
+	router, mockService := setupAccountErasureTest()
+
+	t.Run("Success", func(t *testing.T) {
+		mockService.On("CancelErasure", mock.Anything, int64(1001)).Return(nil).Once()
+
+		req, err := http.NewRequest("DELETE", "/api/users/me/erasure", nil)
+		require.NoError(t, err)
+		req = req.WithContext(createAuthContext(1001))
+
+		rr := httptest.NewRecorder()
+		router.ServeHTTP(rr, req)
+
+		assert.Equal(t, http.StatusNoContent, rr.Code)
+	})
+
+	t.Run("Already Started", func(t *testing.T) {
+		mockService.On("CancelErasure", mock.Anything, int64(1001)).
+			Return(utils.New(utils.ErrBadRequest, constants.StatusConflict, constants.MsgAccountErasureStarted)).Once()
+
+		req, err := http.NewRequest("DELETE", "/api/users/me/erasure", nil)
+		require.NoError(t, err)
+		req = req.WithContext(createAuthContext(1001))
+
+		rr := httptest.NewRecorder()
+		router.ServeHTTP(rr, req)
+
+		assert.Equal(t, http.StatusConflict, rr.Code)
+	})
+}
@@ -0,0 +1,95 @@
+// Package handlers provides HTTP request handlers for the HideMe API.
+package handlers
+
+import (
+	"context"
+	"net/http"
+
+	"github.com/yasinhessnawi1/Hideme_Backend/internal/auth"
+	"github.com/yasinhessnawi1/Hideme_Backend/internal/constants"
+	"github.com/yasinhessnawi1/Hideme_Backend/internal/models"
+	"github.com/yasinhessnawi1/Hideme_Backend/internal/utils"
+)
+
+// AdminSearchServiceInterface defines the service methods required for the administrator search.
+type AdminSearchServiceInterface interface {
+	// Search looks up users, documents, API keys and sessions matching a term.
+	//
+	// Parameters:
+	//   - ctx: Context for the operation
+	//   - adminID: The ID of the administrator searching
+	//   - query: The search term
+	//
+	// Returns:
+	//   - The matches, one bucket per resource type
+	//   - ValidationError if the term is too short or too long
+	Search(ctx context.Context, adminID int64, query string) (*models.AdminSearchResults, error)
+}
+
+// AdminSearchHandler handles HTTP requests for the administrator search.
+type AdminSearchHandler struct {
+	searchService AdminSearchServiceInterface
+}
+
+// NewAdminSearchHandler creates a new AdminSearchHandler with the provided search service.
+//
+// Parameters:
+//   - searchService: Service performing the search
+//
+// Returns:
+//   - A properly initialized AdminSearchHandler
+func NewAdminSearchHandler(searchService AdminSearchServiceInterface) *AdminSearchHandler {
+	return &AdminSearchHandler{
+		searchService: searchService,
+	}
+}
+
+// Search finds users, documents, API keys and sessions from a single reference,
+// such as an ID or email address quoted in a support ticket.
+//
+// HTTP Method:
+//   - GET
+//
+// URL Path:
+//   - /api/admin/search
+//
+// Query Parameters:
+//   - q: The search term, 3 to 255 characters (required)
+//
+// Requires:
+//   - Authentication: Admin role
+//
+// Responses:
+//   - 200 OK: Matches grouped by resource type
+//   - 400 Bad Request: Missing, too short or too long search term
+//   - 401 Unauthorized: User not authenticated
+//   - 403 Forbidden: User not authorized (not admin)
+//   - 500 Internal Server Error: Server-side error
+//
+// @Summary Search across resources
+// @Description Matches users by ID, username or email, documents by ID or stored name, and API keys and sessions by ID prefix
+// @Tags Admin/Search
+// @Produce json
+// @Security BearerAuth
+// @Param q query string true "Search term"
+// @Success 200 {object} utils.Response{data=models.AdminSearchResults} "Search results"
+// @Failure 400 {object} utils.Response{error=string} "Invalid search term"
+// @Failure 401 {object} utils.Response{error=string} "User not authenticated"
+// @Failure 403 {object} utils.Response{error=string} "User not authorized"
+// @Failure 500 {object} utils.Response{error=string} "Server error"
+// @Router /admin/search [get]
+func (h *AdminSearchHandler) Search(w http.ResponseWriter, r *http.Request) {
+	adminID, ok := auth.GetUserID(r)
+	if !ok {
+		utils.Unauthorized(w, constants.MsgAuthRequired)
+		return
+	}
+
+	results, err := h.searchService.Search(r.Context(), adminID, r.URL.Query().Get(constants.QueryParamQuery))
+	if err != nil {
+		utils.ErrorFromAppError(w, utils.ParseError(err))
+		return
+	}
+
+	utils.JSON(w, constants.StatusOK, results)
+}
@@ -0,0 +1,87 @@
+package handlers_test
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+
+	"github.com/yasinhessnawi1/Hideme_Backend/internal/constants"
+	"github.com/yasinhessnawi1/Hideme_Backend/internal/handlers"
+	"github.com/yasinhessnawi1/Hideme_Backend/internal/models"
+	"github.com/yasinhessnawi1/Hideme_Backend/internal/testsupport/golden"
+	"github.com/yasinhessnawi1/Hideme_Backend/internal/utils"
+)
+
+// MockAdminSearchService is a mock implementation of the AdminSearchService
+type MockAdminSearchService struct {
+	mock.Mock
+}
+
+func (m *MockAdminSearchService) Search(ctx context.Context, adminID int64, query string) (*models.AdminSearchResults, error) {
+	args := m.Called(ctx, adminID, query)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*models.AdminSearchResults), args.Error(1)
+}
+
+func setupAdminSearchTest() (*chi.Mux, *MockAdminSearchService) {
+	mockService := new(MockAdminSearchService)
+	handler := handlers.NewAdminSearchHandler(mockService)
+
+	router := chi.NewRouter()
+	router.Get("/api/admin/search", handler.Search)
+
+	return router, mockService
+}
+
+func TestAdminSearch(t *testing.T) {
+	router, mockService := setupAdminSearchTest()
+
+	t.Run("Success", func(t *testing.T) {
+		results := models.NewAdminSearchResults("alice@example.com")
+		results.Users = []*models.User{{ID: 3, Username: "alice", Email: "alice@example.com"}}
+		mockService.On("Search", mock.Anything, int64(1), "alice@example.com").Return(results, nil).Once()
+
+		req, err := http.NewRequest("GET", "/api/admin/search?q=alice%40example.com", nil)
+		require.NoError(t, err)
+		req = req.WithContext(createAuthContext(1))
+
+		rr := httptest.NewRecorder()
+		router.ServeHTTP(rr, req)
+
+		golden.AssertResponse(t, rr)
+	})
+
+	t.Run("Invalid Query", func(t *testing.T) {
+		mockService.On("Search", mock.Anything, int64(1), "ab").
+			Return(nil, utils.NewValidationError(constants.QueryParamQuery, constants.MsgAdminSearchQueryLength)).Once()
+
+		req, err := http.NewRequest("GET", "/api/admin/search?q=ab", nil)
+		require.NoError(t, err)
+		req = req.WithContext(createAuthContext(1))
+
+		rr := httptest.NewRecorder()
+		router.ServeHTTP(rr, req)
+
+		assert.Equal(t, http.StatusBadRequest, rr.Code)
+	})
+
+	t.Run("Unauthenticated", func(t *testing.T) {
+		req, err := http.NewRequest("GET", "/api/admin/search?q=alice", nil)
+		require.NoError(t, err)
+
+		rr := httptest.NewRecorder()
+		router.ServeHTTP(rr, req)
+
+		assert.Equal(t, http.StatusUnauthorized, rr.Code)
+	})
+
+	mockService.AssertExpectations(t)
+}
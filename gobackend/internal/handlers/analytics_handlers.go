@@ -0,0 +1,126 @@
+// Package handlers provides HTTP request handlers for the HideMe API.
+package handlers
+
+import (
+	"context"
+	"net/http"
+
+	"github.com/yasinhessnawi1/Hideme_Backend/internal/constants"
+	"github.com/yasinhessnawi1/Hideme_Backend/internal/models"
+	"github.com/yasinhessnawi1/Hideme_Backend/internal/utils"
+)
+
+// IndexAdvisorServiceInterface defines the service methods required for index suggestions.
+type IndexAdvisorServiceInterface interface {
+	// GetReport returns the latest index advisor report.
+	//
+	// Parameters:
+	//   - ctx: Context for the operation
+	//
+	// Returns:
+	//   - The latest report
+	//   - An error if no report exists and one cannot be created
+	GetReport(ctx context.Context) (*models.IndexAdvisorReport, error)
+
+	// Analyze inspects the database statistics and replaces the latest report.
+	//
+	// Parameters:
+	//   - ctx: Context for the operation
+	//
+	// Returns:
+	//   - The new report
+	//   - An error if the statistics cannot be read
+	Analyze(ctx context.Context) (*models.IndexAdvisorReport, error)
+}
+
+// AnalyticsHandler handles HTTP requests of the admin analytics API.
+type AnalyticsHandler struct {
+	indexAdvisorService IndexAdvisorServiceInterface
+}
+
+// NewAnalyticsHandler creates a new AnalyticsHandler with the provided services.
+//
+// Parameters:
+//   - indexAdvisorService: Service reporting missing indexes
+//
+// Returns:
+//   - A properly initialized AnalyticsHandler
+func NewAnalyticsHandler(indexAdvisorService IndexAdvisorServiceInterface) *AnalyticsHandler {
+	return &AnalyticsHandler{
+		indexAdvisorService: indexAdvisorService,
+	}
+}
+
+// GetIndexSuggestions returns the latest report of the index advisor.
+//
+// HTTP Method:
+//   - GET
+//
+// URL Path:
+//   - /api/admin/analytics/index-suggestions
+//
+// Requires:
+//   - Authentication: Admin role
+//
+// Responses:
+//   - 200 OK: Latest index advisor report
+//   - 401 Unauthorized: User not authenticated
+//   - 403 Forbidden: User not authorized (not admin)
+//   - 500 Internal Server Error: Server-side error
+//
+// @Summary Get index suggestions
+// @Description Returns the latest index advisor report for the documents and detected entities tables
+// @Tags Admin/Analytics
+// @Produce json
+// @Security BearerAuth
+// @Success 200 {object} utils.Response{data=models.IndexAdvisorReport} "Latest report"
+// @Failure 401 {object} utils.Response{error=string} "User not authenticated"
+// @Failure 403 {object} utils.Response{error=string} "User not authorized"
+// @Failure 500 {object} utils.Response{error=string} "Server error"
+// @Router /admin/analytics/index-suggestions [get]
+func (h *AnalyticsHandler) GetIndexSuggestions(w http.ResponseWriter, r *http.Request) {
+	report, err := h.indexAdvisorService.GetReport(r.Context())
+	if err != nil {
+		utils.ErrorFromAppError(w, utils.ParseError(err))
+		return
+	}
+
+	utils.JSON(w, constants.StatusOK, report)
+}
+
+// RefreshIndexSuggestions runs the index advisor now instead of waiting for the maintenance task.
+//
+// HTTP Method:
+//   - POST
+//
+// URL Path:
+//   - /api/admin/analytics/index-suggestions/refresh
+//
+// Requires:
+//   - Authentication: Admin role
+//
+// Responses:
+//   - 200 OK: New index advisor report
+//   - 401 Unauthorized: User not authenticated
+//   - 403 Forbidden: User not authorized (not admin)
+//   - 500 Internal Server Error: Server-side error
+//
+// @Summary Refresh index suggestions
+// @Description Inspects the database statistics now and returns the new index advisor report
+// @Tags Admin/Analytics
+// @Produce json
+// @Security BearerAuth
+// @Success 200 {object} utils.Response{data=models.IndexAdvisorReport} "New report"
+// @Failure 401 {object} utils.Response{error=string} "User not authenticated"
+// @Failure 403 {object} utils.Response{error=string} "User not authorized"
+// @Failure 500 {object} utils.Response{error=string} "Server error"
+// @Router /admin/analytics/index-suggestions/refresh [post]
+func (h *AnalyticsHandler) RefreshIndexSuggestions(w http.ResponseWriter, r *http.Request) {
+	report, err := h.indexAdvisorService.Analyze(r.Context())
+	if err != nil {
+		utils.ErrorFromAppError(w, utils.ParseError(err))
+		return
+	}
+
+	utils.JSON(w, constants.StatusOK, report)
+}
@@ -0,0 +1,101 @@
+package handlers_test
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+
+	"github.com/yasinhessnawi1/Hideme_Backend/internal/handlers"
+	"github.com/yasinhessnawi1/Hideme_Backend/internal/models"
+	"github.com/yasinhessnawi1/Hideme_Backend/internal/testsupport/golden"
+)
+
+// MockIndexAdvisorService is a mock implementation of the IndexAdvisorService
+type MockIndexAdvisorService struct {
+	mock.Mock
+}
+
+func (m *MockIndexAdvisorService) GetReport(ctx context.Context) (*models.IndexAdvisorReport, error) {
+	args := m.Called(ctx)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*models.IndexAdvisorReport), args.Error(1)
+}
+
+func (m *MockIndexAdvisorService) Analyze(ctx context.Context) (*models.IndexAdvisorReport, error) {
+	args := m.Called(ctx)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*models.IndexAdvisorReport), args.Error(1)
+}
+
+func setupAnalyticsTest() (*chi.Mux, *MockIndexAdvisorService) {
+	mockService := new(MockIndexAdvisorService)
+	handler := handlers.NewAnalyticsHandler(mockService)
+
+	router := chi.NewRouter()
+	router.Get("/api/admin/analytics/index-suggestions", handler.GetIndexSuggestions)
+	router.Post("/api/admin/analytics/index-suggestions/refresh", handler.RefreshIndexSuggestions)
+
+	return router, mockService
+}
+
+func TestGetIndexSuggestions(t *testing.T) {
+	router, mockService := setupAnalyticsTest()
+
+	t.Run("Success", func(t *testing.T) {
+		report := &models.IndexAdvisorReport{
+			Suggestions: []models.IndexSuggestion{{Table: "documents", Severity: "warning", Reason: "Sequential scans"}},
+		}
+		mockService.On("GetReport", mock.Anything).Return(report, nil).Once()
+
+		req, err := http.NewRequest("GET", "/api/admin/analytics/index-suggestions", nil)
+		require.NoError(t, err)
+		req = req.WithContext(createAuthContext(1))
+
+		rr := httptest.NewRecorder()
+		router.ServeHTTP(rr, req)
+
+		golden.AssertResponse(t, rr)
+	})
+
+	t.Run("Service error", func(t *testing.T) {
+		mockService.On("GetReport", mock.Anything).Return(nil, errors.New("database error")).Once()
+
+		req, err := http.NewRequest("GET", "/api/admin/analytics/index-suggestions", nil)
+		require.NoError(t, err)
+		req = req.WithContext(createAuthContext(1))
+
+		rr := httptest.NewRecorder()
+		router.ServeHTTP(rr, req)
+
+		assert.Equal(t, http.StatusInternalServerError, rr.Code)
+	})
+
+	mockService.AssertExpectations(t)
+}
+
+func TestRefreshIndexSuggestions(t *testing.T) {
+	router, mockService := setupAnalyticsTest()
+
+	mockService.On("Analyze", mock.Anything).Return(&models.IndexAdvisorReport{StatStatementsAvailable: true}, nil).Once()
+
+	req, err := http.NewRequest("POST", "/api/admin/analytics/index-suggestions/refresh", nil)
+	require.NoError(t, err)
+	req = req.WithContext(createAuthContext(1))
+
+	rr := httptest.NewRecorder()
+	router.ServeHTTP(rr, req)
+
+	golden.AssertResponse(t, rr)
+	mockService.AssertExpectations(t)
+}
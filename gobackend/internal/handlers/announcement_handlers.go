@@ -0,0 +1,417 @@
+// Package handlers provides HTTP request handlers for the HideMe API.
+package handlers
+
+import (
+	"context"
+	"net/http"
+	"strconv"
+
+	"github.com/go-chi/chi/v5"
+
+	"github.com/yasinhessnawi1/Hideme_Backend/internal/auth"
+	"github.com/yasinhessnawi1/Hideme_Backend/internal/constants"
+	"github.com/yasinhessnawi1/Hideme_Backend/internal/models"
+	"github.com/yasinhessnawi1/Hideme_Backend/internal/utils"
+)
+
+// AnnouncementServiceInterface defines the service methods required for in-product announcements.
+type AnnouncementServiceInterface interface {
+	// ListForUser returns the announcements currently shown, together with whether the user has read them.
+	//
+	// Parameters:
+	//   - ctx: Context for the operation
+	//   - userID: The ID of the user
+	//   - unreadOnly: Whether to leave out the announcements the user has read
+	//
+	// Returns:
+	//   - The announcements, latest first
+	//   - An error if retrieval fails
+	ListForUser(ctx context.Context, userID int64, unreadOnly bool) ([]*models.UserAnnouncement, error)
+
+	// MarkRead records that a user has read an announcement.
+	//
+	// Parameters:
+	//   - ctx: Context for the operation
+	//   - userID: The ID of the user
+	//   - id: The ID of the announcement
+	//
+	// Returns:
+	//   - NotFoundError if the announcement doesn't exist or is not currently shown
+	MarkRead(ctx context.Context, userID, id int64) error
+
+	// ListAnnouncements returns the most recent announcements, including scheduled and expired ones.
+	//
+	// Parameters:
+	//   - ctx: Context for the operation
+	//
+	// Returns:
+	//   - The announcements, latest start first
+	//   - An error if retrieval fails
+	ListAnnouncements(ctx context.Context) ([]*models.Announcement, error)
+
+	// CreateAnnouncement publishes a new announcement.
+	//
+	// Parameters:
+	//   - ctx: Context for the operation
+	//   - adminID: The ID of the administrator publishing the announcement
+	//   - req: The title, message, category and optional display window of the announcement
+	//
+	// Returns:
+	//   - The created announcement
+	//   - An error if the announcement is invalid or could not be stored
+	CreateAnnouncement(ctx context.Context, adminID int64, req *models.AnnouncementCreate) (*models.Announcement, error)
+
+	// UpdateAnnouncement changes an announcement.
+	//
+	// Parameters:
+	//   - ctx: Context for the operation
+	//   - adminID: The ID of the administrator changing the announcement
+	//   - id: The ID of the announcement
+	//   - req: The fields to change
+	//
+	// Returns:
+	//   - The updated announcement
+	//   - NotFoundError if the announcement doesn't exist
+	UpdateAnnouncement(ctx context.Context, adminID int64, id int64, req *models.AnnouncementUpdate) (*models.Announcement, error)
+
+	// DeleteAnnouncement removes an announcement.
+	//
+	// Parameters:
+	//   - ctx: Context for the operation
+	//   - id: The ID of the announcement
+	//
+	// Returns:
+	//   - NotFoundError if the announcement doesn't exist
+	DeleteAnnouncement(ctx context.Context, id int64) error
+}
+
+// AnnouncementHandler handles HTTP requests for in-product announcements.
+type AnnouncementHandler struct {
+	announcementService AnnouncementServiceInterface
+}
+
+// NewAnnouncementHandler creates a new AnnouncementHandler with the provided announcement service.
+//
+// Parameters:
+//   - announcementService: Service delivering the announcements
+//
+// Returns:
+//   - A properly initialized AnnouncementHandler
+func NewAnnouncementHandler(announcementService AnnouncementServiceInterface) *AnnouncementHandler {
+	return &AnnouncementHandler{
+		announcementService: announcementService,
+	}
+}
+
+// GetAnnouncements returns the announcements currently shown to the authenticated user,
+// such as maintenance windows and new-feature notices, with their read state.
+//
+// HTTP Method:
+//   - GET
+//
+// URL Path:
+//   - /api/announcements
+//
+// Query Parameters:
+//   - unread: true to leave out announcements that were already read (optional)
+//
+// Requires:
+//   - Authentication: User must be logged in
+//
+// Responses:
+//   - 200 OK: List of announcements
+//   - 400 Bad Request: Invalid unread parameter
+//   - 401 Unauthorized: User not authenticated
+//   - 500 Internal Server Error: Server-side error
+//
+// @Summary List announcements
+// @Description Returns the announcements currently shown, latest first, with whether the user has read them
+// @Tags Announcements
+// @Produce json
+// @Security BearerAuth
+// @Param unread query bool false "Only return unread announcements"
+// @Success 200 {object} utils.Response{data=[]models.UserAnnouncement} "List of announcements"
+// @Failure 400 {object} utils.Response{error=string} "Invalid unread parameter"
+// @Failure 401 {object} utils.Response{error=string} "User not authenticated"
+// @Failure 500 {object} utils.Response{error=string} "Server error"
+// @Router /announcements [get]
+func (h *AnnouncementHandler) GetAnnouncements(w http.ResponseWriter, r *http.Request) {
+	userID, ok := auth.GetUserID(r)
+	if !ok {
+		utils.Unauthorized(w, constants.MsgAuthRequired)
+		return
+	}
+
+	var unreadOnly bool
+	if unreadStr := r.URL.Query().Get(constants.QueryParamUnread); unreadStr != "" {
+		parsed, err := strconv.ParseBool(unreadStr)
+		if err != nil {
+			utils.BadRequest(w, "Invalid unread parameter", nil)
+			return
+		}
+		unreadOnly = parsed
+	}
+
+	announcements, err := h.announcementService.ListForUser(r.Context(), userID, unreadOnly)
+	if err != nil {
+		utils.ErrorFromAppError(w, utils.ParseError(err))
+		return
+	}
+
+	utils.ListAll(w, r, announcements)
+}
+
+// MarkAnnouncementRead records that the authenticated user has read an announcement.
+// Marking an announcement again keeps the time it was first read.
+//
+// HTTP Method:
+//   - POST
+//
+// URL Path:
+//   - /api/announcements/{id}/read
+//
+// Requires:
+//   - Authentication: User must be logged in
+//
+// Responses:
+//   - 204 No Content: Announcement marked as read
+//   - 400 Bad Request: Invalid announcement ID
+//   - 401 Unauthorized: User not authenticated
+//   - 404 Not Found: Announcement not found or not currently shown
+//   - 500 Internal Server Error: Server-side error
+//
+// @Summary Mark announcement as read
+// @Description Records that the user has read an announcement
+// @Tags Announcements
+// @Security BearerAuth
+// @Param id path int true "Announcement ID"
+// @Success 204 "Announcement marked as read"
+// @Failure 400 {object} utils.Response{error=string} "Invalid announcement ID"
+// @Failure 401 {object} utils.Response{error=string} "User not authenticated"
+// @Failure 404 {object} utils.Response{error=string} "Announcement not found"
+// @Failure 500 {object} utils.Response{error=string} "Server error"
+// @Router /announcements/{id}/read [post]
+func (h *AnnouncementHandler) MarkAnnouncementRead(w http.ResponseWriter, r *http.Request) {
+	userID, ok := auth.GetUserID(r)
+	if !ok {
+		utils.Unauthorized(w, constants.MsgAuthRequired)
+		return
+	}
+
+	announcementID, err := strconv.ParseInt(chi.URLParam(r, constants.ParamID), 10, 64)
+	if err != nil {
+		utils.BadRequest(w, "Invalid announcement ID", nil)
+		return
+	}
+
+	if err := h.announcementService.MarkRead(r.Context(), userID, announcementID); err != nil {
+		utils.ErrorFromAppError(w, utils.ParseError(err))
+		return
+	}
+
+	utils.NoContent(w)
+}
+
+// ListAnnouncements returns the most recent announcements, including scheduled and expired ones.
+//
+// HTTP Method:
+//   - GET
+//
+// URL Path:
+//   - /api/admin/announcements
+//
+// Requires:
+//   - Authentication: Admin role
+//
+// Responses:
+//   - 200 OK: List of announcements
+//   - 401 Unauthorized: User not authenticated
+//   - 403 Forbidden: User not authorized (not admin)
+//   - 500 Internal Server Error: Server-side error
+//
+// @Summary List all announcements
+// @Description Returns the most recent announcements, including scheduled and expired ones
+// @Tags Admin/Announcements
+// @Produce json
+// @Security BearerAuth
+// @Success 200 {object} utils.Response{data=[]models.Announcement} "List of announcements"
+// @Failure 401 {object} utils.Response{error=string} "User not authenticated"
+// @Failure 403 {object} utils.Response{error=string} "User not authorized"
+// @Failure 500 {object} utils.Response{error=string} "Server error"
+// @Router /admin/announcements [get]
+func (h *AnnouncementHandler) ListAnnouncements(w http.ResponseWriter, r *http.Request) {
+	announcements, err := h.announcementService.ListAnnouncements(r.Context())
+	if err != nil {
+		utils.ErrorFromAppError(w, utils.ParseError(err))
+		return
+	}
+
+	utils.ListAll(w, r, announcements)
+}
+
+// CreateAnnouncement publishes a new announcement to all users.
+//
+// HTTP Method:
+//   - POST
+//
+// URL Path:
+//   - /api/admin/announcements
+//
+// Requires:
+//   - Authentication: Admin role
+//
+// Request Body:
+//   - JSON object conforming to models.AnnouncementCreate
+//
+// Responses:
+//   - 201 Created: Announcement published
+//   - 400 Bad Request: Invalid request body or display window
+//   - 401 Unauthorized: User not authenticated
+//   - 403 Forbidden: User not authorized (not admin)
+//   - 500 Internal Server Error: Server-side error
+//
+// @Summary Create announcement
+// @Description Publishes an announcement, optionally within a display window
+// @Tags Admin/Announcements
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param announcement body models.AnnouncementCreate true "Announcement details"
+// @Success 201 {object} utils.Response{data=models.Announcement} "Announcement published"
+// @Failure 400 {object} utils.Response{error=string} "Invalid request body"
+// @Failure 401 {object} utils.Response{error=string} "User not authenticated"
+// @Failure 403 {object} utils.Response{error=string} "User not authorized"
+// @Failure 500 {object} utils.Response{error=string} "Server error"
+// @Router /admin/announcements [post]
+func (h *AnnouncementHandler) CreateAnnouncement(w http.ResponseWriter, r *http.Request) {
+	adminID, ok := auth.GetUserID(r)
+	if !ok {
+		utils.Unauthorized(w, constants.MsgAuthRequired)
+		return
+	}
+
+	var req models.AnnouncementCreate
+	if err := utils.DecodeAndValidate(r, &req); err != nil {
+		utils.ErrorFromAppError(w, utils.ParseError(err))
+		return
+	}
+
+	announcement, err := h.announcementService.CreateAnnouncement(r.Context(), adminID, &req)
+	if err != nil {
+		utils.ErrorFromAppError(w, utils.ParseError(err))
+		return
+	}
+
+	utils.JSON(w, constants.StatusCreated, announcement)
+}
+
+// UpdateAnnouncement changes an announcement, such as its text or display window.
+//
+// HTTP Method:
+//   - PUT
+//
+// URL Path:
+//   - /api/admin/announcements/{id}
+//
+// Requires:
+//   - Authentication: Admin role
+//
+// Request Body:
+//   - JSON object conforming to models.AnnouncementUpdate
+//
+// Responses:
+//   - 200 OK: Announcement updated
+//   - 400 Bad Request: Invalid announcement ID, request body or display window
+//   - 401 Unauthorized: User not authenticated
+//   - 403 Forbidden: User not authorized (not admin)
+//   - 404 Not Found: Announcement not found
+//   - 500 Internal Server Error: Server-side error
+//
+// @Summary Update announcement
+// @Description Updates the text, category or display window of an announcement
+// @Tags Admin/Announcements
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path int true "Announcement ID"
+// @Param announcement body models.AnnouncementUpdate true "Changes to the announcement"
+// @Success 200 {object} utils.Response{data=models.Announcement} "Announcement updated"
+// @Failure 400 {object} utils.Response{error=string} "Invalid request"
+// @Failure 401 {object} utils.Response{error=string} "User not authenticated"
+// @Failure 403 {object} utils.Response{error=string} "User not authorized"
+// @Failure 404 {object} utils.Response{error=string} "Announcement not found"
+// @Failure 500 {object} utils.Response{error=string} "Server error"
+// @Router /admin/announcements/{id} [put]
+func (h *AnnouncementHandler) UpdateAnnouncement(w http.ResponseWriter, r *http.Request) {
+	adminID, ok := auth.GetUserID(r)
+	if !ok {
+		utils.Unauthorized(w, constants.MsgAuthRequired)
+		return
+	}
+
+	announcementID, err := strconv.ParseInt(chi.URLParam(r, constants.ParamID), 10, 64)
+	if err != nil {
+		utils.BadRequest(w, "Invalid announcement ID", nil)
+		return
+	}
+
+	var req models.AnnouncementUpdate
+	if err := utils.DecodeAndValidate(r, &req); err != nil {
+		utils.ErrorFromAppError(w, utils.ParseError(err))
+		return
+	}
+
+	announcement, err := h.announcementService.UpdateAnnouncement(r.Context(), adminID, announcementID, &req)
+	if err != nil {
+		utils.ErrorFromAppError(w, utils.ParseError(err))
+		return
+	}
+
+	utils.JSON(w, constants.StatusOK, announcement)
+}
+
+// DeleteAnnouncement removes an announcement, e.g. one published by mistake.
+//
+// HTTP Method:
+//   - DELETE
+//
+// URL Path:
+//   - /api/admin/announcements/{id}
+//
+// Requires:
+//   - Authentication: Admin role
+//
+// Responses:
+//   - 204 No Content: Announcement removed
+//   - 400 Bad Request: Invalid announcement ID
+//   - 401 Unauthorized: User not authenticated
+//   - 403 Forbidden: User not authorized (not admin)
+//   - 404 Not Found: Announcement not found
+//   - 500 Internal Server Error: Server-side error
+//
+// @Summary Delete announcement
+// @Description Removes an announcement and its read state
+// @Tags Admin/Announcements
+// @Security BearerAuth
+// @Param id path int true "Announcement ID"
+// @Success 204 "Announcement removed"
+// @Failure 400 {object} utils.Response{error=string} "Invalid announcement ID"
+// @Failure 401 {object} utils.Response{error=string} "User not authenticated"
+// @Failure 403 {object} utils.Response{error=string} "User not authorized"
+// @Failure 404 {object} utils.Response{error=string} "Announcement not found"
+// @Failure 500 {object} utils.Response{error=string} "Server error"
+// @Router /admin/announcements/{id} [delete]
+func (h *AnnouncementHandler) DeleteAnnouncement(w http.ResponseWriter, r *http.Request) {
+	announcementID, err := strconv.ParseInt(chi.URLParam(r, constants.ParamID), 10, 64)
+	if err != nil {
+		utils.BadRequest(w, "Invalid announcement ID", nil)
+		return
+	}
+
+	if err := h.announcementService.DeleteAnnouncement(r.Context(), announcementID); err != nil {
+		utils.ErrorFromAppError(w, utils.ParseError(err))
+		return
+	}
+
+	utils.NoContent(w)
+}
@@ -0,0 +1,299 @@
+package handlers_test
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+
+	"github.com/yasinhessnawi1/Hideme_Backend/internal/constants"
+	"github.com/yasinhessnawi1/Hideme_Backend/internal/handlers"
+	"github.com/yasinhessnawi1/Hideme_Backend/internal/models"
+	"github.com/yasinhessnawi1/Hideme_Backend/internal/testsupport/fixtures"
+	"github.com/yasinhessnawi1/Hideme_Backend/internal/testsupport/golden"
+	"github.com/yasinhessnawi1/Hideme_Backend/internal/utils"
+)
+
+// MockAnnouncementService is a mock implementation of the AnnouncementService
+type MockAnnouncementService struct {
+	mock.Mock
+}
+
+func (m *MockAnnouncementService) ListForUser(ctx context.Context, userID int64, unreadOnly bool) ([]*models.UserAnnouncement, error) {
+	args := m.Called(ctx, userID, unreadOnly)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]*models.UserAnnouncement), args.Error(1)
+}
+
+func (m *MockAnnouncementService) MarkRead(ctx context.Context, userID, id int64) error {
+	args := m.Called(ctx, userID, id)
+	return args.Error(0)
+}
+
+func (m *MockAnnouncementService) ListAnnouncements(ctx context.Context) ([]*models.Announcement, error) {
+	args := m.Called(ctx)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]*models.Announcement), args.Error(1)
+}
+
+func (m *MockAnnouncementService) CreateAnnouncement(ctx context.Context, adminID int64, req *models.AnnouncementCreate) (*models.Announcement, error) {
+	args := m.Called(ctx, adminID, req)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*models.Announcement), args.Error(1)
+}
+
+func (m *MockAnnouncementService) UpdateAnnouncement(ctx context.Context, adminID int64, id int64, req *models.AnnouncementUpdate) (*models.Announcement, error) {
+	args := m.Called(ctx, adminID, id, req)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*models.Announcement), args.Error(1)
+}
+
+func (m *MockAnnouncementService) DeleteAnnouncement(ctx context.Context, id int64) error {
+	args := m.Called(ctx, id)
+	return args.Error(0)
+}
+
+func setupAnnouncementTest() (*chi.Mux, *MockAnnouncementService) {
+	mockService := new(MockAnnouncementService)
+	handler := handlers.NewAnnouncementHandler(mockService)
+
+	router := chi.NewRouter()
+	router.Get("/api/announcements", handler.GetAnnouncements)
+	router.Post("/api/announcements/{id}/read", handler.MarkAnnouncementRead)
+	router.Get("/api/admin/announcements", handler.ListAnnouncements)
+	router.Post("/api/admin/announcements", handler.CreateAnnouncement)
+	router.Put("/api/admin/announcements/{id}", handler.UpdateAnnouncement)
+	router.Delete("/api/admin/announcements/{id}", handler.DeleteAnnouncement)
+
+	return router, mockService
+}
+
+func TestGetAnnouncements(t *testing.T) {
+	router, mockService := setupAnnouncementTest()
+
+	t.Run("Success", func(t *testing.T) {
+		announcements := []*models.UserAnnouncement{
+			{Announcement: models.Announcement{ID: 4, Title: "Maintenance", Category: models.AnnouncementMaintenance}, Read: true},
+		}
+		mockService.On("ListForUser", mock.Anything, int64(1), false).Return(announcements, nil).Once()
+
+		req, err := http.NewRequest("GET", "/api/announcements", nil)
+		require.NoError(t, err)
+		req = req.WithContext(createAuthContext(1))
+
+		rr := httptest.NewRecorder()
+		router.ServeHTTP(rr, req)
+
+		golden.AssertResponse(t, rr)
+	})
+
+	t.Run("Unread Only", func(t *testing.T) {
+		mockService.On("ListForUser", mock.Anything, int64(1), true).Return([]*models.UserAnnouncement{}, nil).Once()
+
+		req, err := http.NewRequest("GET", "/api/announcements?unread=true", nil)
+		require.NoError(t, err)
+		req = req.WithContext(createAuthContext(1))
+
+		rr := httptest.NewRecorder()
+		router.ServeHTTP(rr, req)
+
+		assert.Equal(t, http.StatusOK, rr.Code)
+	})
+
+	t.Run("Invalid Unread", func(t *testing.T) {
+		req, err := http.NewRequest("GET", "/api/announcements?unread=maybe", nil)
+		require.NoError(t, err)
+		req = req.WithContext(createAuthContext(1))
+
+		rr := httptest.NewRecorder()
+		router.ServeHTTP(rr, req)
+
+		assert.Equal(t, http.StatusBadRequest, rr.Code)
+	})
+
+	t.Run("Unauthenticated", func(t *testing.T) {
+		req, err := http.NewRequest("GET", "/api/announcements", nil)
+		require.NoError(t, err)
+
+		rr := httptest.NewRecorder()
+		router.ServeHTTP(rr, req)
+
+		assert.Equal(t, http.StatusUnauthorized, rr.Code)
+	})
+
+	mockService.AssertExpectations(t)
+}
+
+func TestMarkAnnouncementRead(t *testing.T) {
+	router, mockService := setupAnnouncementTest()
+
+	t.Run("Success", func(t *testing.T) {
+		mockService.On("MarkRead", mock.Anything, int64(1), int64(4)).Return(nil).Once()
+
+		req, err := http.NewRequest("POST", "/api/announcements/4/read", nil)
+		require.NoError(t, err)
+		req = req.WithContext(createAuthContext(1))
+
+		rr := httptest.NewRecorder()
+		router.ServeHTTP(rr, req)
+
+		assert.Equal(t, http.StatusNoContent, rr.Code)
+	})
+
+	t.Run("Not Found", func(t *testing.T) {
+		mockService.On("MarkRead", mock.Anything, int64(1), int64(9)).
+			Return(utils.NewNotFoundError("Announcement", int64(9))).Once()
+
+		req, err := http.NewRequest("POST", "/api/announcements/9/read", nil)
+		require.NoError(t, err)
+		req = req.WithContext(createAuthContext(1))
+
+		rr := httptest.NewRecorder()
+		router.ServeHTTP(rr, req)
+
+		assert.Equal(t, http.StatusNotFound, rr.Code)
+	})
+
+	t.Run("Invalid ID", func(t *testing.T) {
+		req, err := http.NewRequest("POST", "/api/announcements/abc/read", nil)
+		require.NoError(t, err)
+		req = req.WithContext(createAuthContext(1))
+
+		rr := httptest.NewRecorder()
+		router.ServeHTTP(rr, req)
+
+		assert.Equal(t, http.StatusBadRequest, rr.Code)
+	})
+}
+
+func TestCreateAnnouncement(t *testing.T) {
+	router, mockService := setupAnnouncementTest()
+
+	t.Run("Success", func(t *testing.T) {
+		expected := &models.AnnouncementCreate{Title: "New export", Message: "Images", Category: models.AnnouncementFeature}
+		announcement := models.NewAnnouncement(1, expected)
+		announcement.ID = 5
+		announcement.StartsAt, announcement.CreatedAt, announcement.UpdatedAt = fixtures.Time, fixtures.Time, fixtures.Time
+		mockService.On("CreateAnnouncement", mock.Anything, int64(1), expected).Return(announcement, nil).Once()
+
+		body, _ := json.Marshal(map[string]interface{}{"title": "New export", "message": "Images", "category": "feature"})
+		req, err := http.NewRequest("POST", "/api/admin/announcements", bytes.NewBuffer(body))
+		require.NoError(t, err)
+		req.Header.Set("Content-Type", "application/json")
+		req = req.WithContext(createAuthContext(1))
+
+		rr := httptest.NewRecorder()
+		router.ServeHTTP(rr, req)
+
+		golden.AssertResponse(t, rr)
+	})
+
+	t.Run("Invalid Category", func(t *testing.T) {
+		body, _ := json.Marshal(map[string]interface{}{"title": "New export", "message": "Images", "category": "sale"})
+		req, err := http.NewRequest("POST", "/api/admin/announcements", bytes.NewBuffer(body))
+		require.NoError(t, err)
+		req.Header.Set("Content-Type", "application/json")
+		req = req.WithContext(createAuthContext(1))
+
+		rr := httptest.NewRecorder()
+		router.ServeHTTP(rr, req)
+
+		assert.Equal(t, http.StatusBadRequest, rr.Code)
+	})
+
+	t.Run("Invalid Window", func(t *testing.T) {
+		mockService.On("CreateAnnouncement", mock.Anything, int64(1), mock.Anything).
+			Return(nil, utils.NewValidationError("ends_at", constants.MsgAnnouncementWindowInvalid)).Once()
+
+		body, _ := json.Marshal(map[string]interface{}{
+			"title": "Maintenance", "message": "Tonight", "category": "maintenance",
+			"starts_at": "2026-03-01T22:00:00Z", "ends_at": "2026-03-01T20:00:00Z",
+		})
+		req, err := http.NewRequest("POST", "/api/admin/announcements", bytes.NewBuffer(body))
+		require.NoError(t, err)
+		req.Header.Set("Content-Type", "application/json")
+		req = req.WithContext(createAuthContext(1))
+
+		rr := httptest.NewRecorder()
+		router.ServeHTTP(rr, req)
+
+		assert.Equal(t, http.StatusBadRequest, rr.Code)
+	})
+}
+
+func TestUpdateAnnouncement(t *testing.T) {
+	router, mockService := setupAnnouncementTest()
+
+	t.Run("Success", func(t *testing.T) {
+		announcement := &models.Announcement{ID: 4, Title: "Maintenance", Message: "Postponed", Category: models.AnnouncementMaintenance}
+		mockService.On("UpdateAnnouncement", mock.Anything, int64(1), int64(4), mock.Anything).Return(announcement, nil).Once()
+
+		body, _ := json.Marshal(map[string]interface{}{"message": "Postponed"})
+		req, err := http.NewRequest("PUT", "/api/admin/announcements/4", bytes.NewBuffer(body))
+		require.NoError(t, err)
+		req.Header.Set("Content-Type", "application/json")
+		req = req.WithContext(createAuthContext(1))
+
+		rr := httptest.NewRecorder()
+		router.ServeHTTP(rr, req)
+
+		golden.AssertResponse(t, rr)
+	})
+
+	t.Run("Invalid ID", func(t *testing.T) {
+		req, err := http.NewRequest("PUT", "/api/admin/announcements/abc", bytes.NewBufferString(`{}`))
+		require.NoError(t, err)
+		req = req.WithContext(createAuthContext(1))
+
+		rr := httptest.NewRecorder()
+		router.ServeHTTP(rr, req)
+
+		assert.Equal(t, http.StatusBadRequest, rr.Code)
+	})
+}
+
+func TestDeleteAnnouncement(t *testing.T) {
+	router, mockService := setupAnnouncementTest()
+
+	t.Run("Success", func(t *testing.T) {
+		mockService.On("DeleteAnnouncement", mock.Anything, int64(4)).Return(nil).Once()
+
+		req, err := http.NewRequest("DELETE", "/api/admin/announcements/4", nil)
+		require.NoError(t, err)
+		req = req.WithContext(createAuthContext(1))
+
+		rr := httptest.NewRecorder()
+		router.ServeHTTP(rr, req)
+
+		assert.Equal(t, http.StatusNoContent, rr.Code)
+	})
+
+	t.Run("Not Found", func(t *testing.T) {
+		mockService.On("DeleteAnnouncement", mock.Anything, int64(9)).
+			Return(utils.NewNotFoundError("Announcement", int64(9))).Once()
+
+		req, err := http.NewRequest("DELETE", "/api/admin/announcements/9", nil)
+		require.NoError(t, err)
+		req = req.WithContext(createAuthContext(1))
+
+		rr := httptest.NewRecorder()
+		router.ServeHTTP(rr, req)
+
+		assert.Equal(t, http.StatusNotFound, rr.Code)
+	})
+}
@@ -0,0 +1,403 @@
+// Package handlers provides HTTP request handlers for the HideMe API.
+package handlers
+
+import (
+	"context"
+	"net/http"
+	"strconv"
+
+	"github.com/go-chi/chi/v5"
+
+	"github.com/yasinhessnawi1/Hideme_Backend/internal/auth"
+	"github.com/yasinhessnawi1/Hideme_Backend/internal/constants"
+	"github.com/yasinhessnawi1/Hideme_Backend/internal/models"
+	"github.com/yasinhessnawi1/Hideme_Backend/internal/utils"
+)
+
+// APIKeyAdminServiceInterface defines the service methods required for administering all users' API keys.
+type APIKeyAdminServiceInterface interface {
+	// ListKeys returns the API keys of all users, or of one user, with their status.
+	//
+	// Parameters:
+	//   - ctx: Context for the operation
+	//   - filter: The user and status to select
+	//
+	// Returns:
+	//   - The keys, newest first, without their hashes
+	//   - ValidationError if the status is unknown
+	ListKeys(ctx context.Context, filter models.APIKeyFilter) ([]*models.AdminAPIKey, error)
+
+	// DisableKey stops an API key from working.
+	//
+	// Parameters:
+	//   - ctx: Context for the operation
+	//   - adminID: The ID of the administrator disabling the key
+	//   - keyID: The ID of the key
+	//
+	// Returns:
+	//   - NotFoundError if the key doesn't exist
+	//   - A conflict error if the key is already disabled
+	DisableKey(ctx context.Context, adminID int64, keyID string) error
+
+	// GetPolicy returns the expiry policy for API keys.
+	//
+	// Parameters:
+	//   - ctx: Context for the operation
+	//
+	// Returns:
+	//   - The policy; without a limit if it was never set
+	//   - An error if retrieval fails
+	GetPolicy(ctx context.Context) (*models.APIKeyPolicy, error)
+
+	// SetPolicy changes the expiry policy for new and existing API keys.
+	//
+	// Parameters:
+	//   - ctx: Context for the operation
+	//   - adminID: The ID of the administrator changing the policy
+	//   - update: The new maximum lifetime
+	//
+	// Returns:
+	//   - The policy now in force and the number of keys whose expiry was brought forward
+	//   - An error if the policy could not be stored
+	SetPolicy(ctx context.Context, adminID int64, update *models.APIKeyPolicyUpdate) (*models.APIKeyPolicyResult, error)
+
+	// StartRotationCampaign requires the owners of the selected API keys to rotate them before a deadline.
+	//
+	// Parameters:
+	//   - ctx: Context for the operation
+	//   - adminID: The ID of the administrator starting the campaign
+	//   - req: The name, deadline and key selection of the campaign
+	//
+	// Returns:
+	//   - The campaign with the number of keys it covers
+	//   - ValidationError if the deadline has passed
+	StartRotationCampaign(ctx context.Context, adminID int64, req *models.RotationCampaignCreate) (*models.RotationCampaign, error)
+
+	// ListCampaigns returns the rotation campaigns with their progress.
+	//
+	// Parameters:
+	//   - ctx: Context for the operation
+	//
+	// Returns:
+	//   - The campaigns, newest first
+	//   - An error if retrieval fails
+	ListCampaigns(ctx context.Context) ([]*models.RotationCampaign, error)
+}
+
+// APIKeyAdminHandler handles HTTP requests for administering all users' API keys.
+type APIKeyAdminHandler struct {
+	apiKeyAdminService APIKeyAdminServiceInterface
+}
+
+// NewAPIKeyAdminHandler creates a new APIKeyAdminHandler with the provided service.
+//
+// Parameters:
+//   - apiKeyAdminService: Service administering the API keys
+//
+// Returns:
+//   - A properly initialized APIKeyAdminHandler
+func NewAPIKeyAdminHandler(apiKeyAdminService APIKeyAdminServiceInterface) *APIKeyAdminHandler {
+	return &APIKeyAdminHandler{
+		apiKeyAdminService: apiKeyAdminService,
+	}
+}
+
+// ListKeys returns the API keys of all users with their owner and status, so administrators
+// can see which keys exist and which are due for rotation.
+//
+// HTTP Method:
+//   - GET
+//
+// URL Path:
+//   - /api/admin/api-keys
+//
+// Query Parameters:
+//   - user_id: Only list the keys of this user (optional)
+//   - status: Only list keys with this status: active, pending_rotation, rotated, expired or disabled (optional)
+//
+// Requires:
+//   - Authentication: Admin role
+//
+// Responses:
+//   - 200 OK: List of API keys
+//   - 400 Bad Request: Invalid user ID or status
+//   - 401 Unauthorized: User not authenticated
+//   - 403 Forbidden: User not authorized (not admin)
+//   - 500 Internal Server Error: Server-side error
+//
+// @Summary List all API keys
+// @Description Returns the API keys of all users with their owner and status, newest first
+// @Tags Admin/APIKeys
+// @Produce json
+// @Security BearerAuth
+// @Param user_id query int false "Only list the keys of this user"
+// @Param status query string false "Only list keys with this status"
+// @Success 200 {object} utils.Response{data=[]models.AdminAPIKey} "List of API keys"
+// @Failure 400 {object} utils.Response{error=string} "Invalid user ID or status"
+// @Failure 401 {object} utils.Response{error=string} "User not authenticated"
+// @Failure 403 {object} utils.Response{error=string} "User not authorized"
+// @Failure 500 {object} utils.Response{error=string} "Server error"
+// @Router /admin/api-keys [get]
+func (h *APIKeyAdminHandler) ListKeys(w http.ResponseWriter, r *http.Request) {
+	filter := models.APIKeyFilter{Status: r.URL.Query().Get(constants.QueryParamStatus)}
+	if userIDStr := r.URL.Query().Get(constants.QueryParamUserID); userIDStr != "" {
+		userID, err := strconv.ParseInt(userIDStr, 10, 64)
+		if err != nil || userID <= 0 {
+			utils.BadRequest(w, "Invalid user ID", nil)
+			return
+		}
+		filter.UserID = userID
+	}
+
+	keys, err := h.apiKeyAdminService.ListKeys(r.Context(), filter)
+	if err != nil {
+		utils.ErrorFromAppError(w, utils.ParseError(err))
+		return
+	}
+
+	utils.ListAll(w, r, keys)
+}
+
+// DisableKey stops an API key from working, such as when it is suspected to be compromised.
+// The key stops being accepted once the verification cache expires, within a minute.
+//
+// HTTP Method:
+//   - POST
+//
+// URL Path:
+//   - /api/admin/api-keys/{keyID}/disable
+//
+// Requires:
+//   - Authentication: Admin role
+//
+// Responses:
+//   - 204 No Content: API key disabled
+//   - 400 Bad Request: Missing key ID
+//   - 401 Unauthorized: User not authenticated
+//   - 403 Forbidden: User not authorized (not admin)
+//   - 404 Not Found: API key not found
+//   - 409 Conflict: API key already disabled
+//   - 500 Internal Server Error: Server-side error
+//
+// @Summary Disable API key
+// @Description Stops any user's API key from working
+// @Tags Admin/APIKeys
+// @Security BearerAuth
+// @Param keyID path string true "API key ID"
+// @Success 204 "API key disabled"
+// @Failure 400 {object} utils.Response{error=string} "Missing key ID"
+// @Failure 401 {object} utils.Response{error=string} "User not authenticated"
+// @Failure 403 {object} utils.Response{error=string} "User not authorized"
+// @Failure 404 {object} utils.Response{error=string} "API key not found"
+// @Failure 409 {object} utils.Response{error=string} "API key already disabled"
+// @Failure 500 {object} utils.Response{error=string} "Server error"
+// @Router /admin/api-keys/{keyID}/disable [post]
+func (h *APIKeyAdminHandler) DisableKey(w http.ResponseWriter, r *http.Request) {
+	adminID, ok := auth.GetUserID(r)
+	if !ok {
+		utils.Unauthorized(w, constants.MsgAuthRequired)
+		return
+	}
+
+	keyID := chi.URLParam(r, constants.ParamKeyID)
+	if keyID == "" {
+		utils.BadRequest(w, "key_id parameter is required", nil)
+		return
+	}
+
+	if err := h.apiKeyAdminService.DisableKey(r.Context(), adminID, keyID); err != nil {
+		utils.ErrorFromAppError(w, utils.ParseError(err))
+		return
+	}
+
+	utils.NoContent(w)
+}
+
+// GetPolicy returns the expiry policy for API keys.
+//
+// HTTP Method:
+//   - GET
+//
+// URL Path:
+//   - /api/admin/api-keys/policy
+//
+// Requires:
+//   - Authentication: Admin role
+//
+// Responses:
+//   - 200 OK: The expiry policy
+//   - 401 Unauthorized: User not authenticated
+//   - 403 Forbidden: User not authorized (not admin)
+//   - 500 Internal Server Error: Server-side error
+//
+// @Summary Get API key policy
+// @Description Returns the maximum lifetime of API keys; 0 for no limit
+// @Tags Admin/APIKeys
+// @Produce json
+// @Security BearerAuth
+// @Success 200 {object} utils.Response{data=models.APIKeyPolicy} "The expiry policy"
+// @Failure 401 {object} utils.Response{error=string} "User not authenticated"
+// @Failure 403 {object} utils.Response{error=string} "User not authorized"
+// @Failure 500 {object} utils.Response{error=string} "Server error"
+// @Router /admin/api-keys/policy [get]
+func (h *APIKeyAdminHandler) GetPolicy(w http.ResponseWriter, r *http.Request) {
+	policy, err := h.apiKeyAdminService.GetPolicy(r.Context())
+	if err != nil {
+		utils.ErrorFromAppError(w, utils.ParseError(err))
+		return
+	}
+
+	utils.JSON(w, constants.StatusOK, policy)
+}
+
+// SetPolicy changes the maximum lifetime of API keys. New keys may not be valid for
+// longer, and existing keys valid for longer now expire at the limit.
+//
+// HTTP Method:
+//   - PUT
+//
+// URL Path:
+//   - /api/admin/api-keys/policy
+//
+// Requires:
+//   - Authentication: Admin role
+//
+// Request Body:
+//   - JSON object conforming to models.APIKeyPolicyUpdate
+//
+// Responses:
+//   - 200 OK: The policy now in force and the number of keys shortened
+//   - 400 Bad Request: Invalid request body
+//   - 401 Unauthorized: User not authenticated
+//   - 403 Forbidden: User not authorized (not admin)
+//   - 500 Internal Server Error: Server-side error
+//
+// @Summary Set API key policy
+// @Description Changes the maximum lifetime of new and existing API keys
+// @Tags Admin/APIKeys
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param policy body models.APIKeyPolicyUpdate true "Maximum lifetime in days"
+// @Success 200 {object} utils.Response{data=models.APIKeyPolicyResult} "The policy now in force"
+// @Failure 400 {object} utils.Response{error=string} "Invalid request body"
+// @Failure 401 {object} utils.Response{error=string} "User not authenticated"
+// @Failure 403 {object} utils.Response{error=string} "User not authorized"
+// @Failure 500 {object} utils.Response{error=string} "Server error"
+// @Router /admin/api-keys/policy [put]
+func (h *APIKeyAdminHandler) SetPolicy(w http.ResponseWriter, r *http.Request) {
+	adminID, ok := auth.GetUserID(r)
+	if !ok {
+		utils.Unauthorized(w, constants.MsgAuthRequired)
+		return
+	}
+
+	var req models.APIKeyPolicyUpdate
+	if err := utils.DecodeAndValidate(r, &req); err != nil {
+		utils.ErrorFromAppError(w, utils.ParseError(err))
+		return
+	}
+
+	result, err := h.apiKeyAdminService.SetPolicy(r.Context(), adminID, &req)
+	if err != nil {
+		utils.ErrorFromAppError(w, utils.ParseError(err))
+		return
+	}
+
+	utils.JSON(w, constants.StatusOK, result)
+}
+
+// ListCampaigns returns the API key rotation campaigns with how many of their keys were rotated.
+//
+// HTTP Method:
+//   - GET
+//
+// URL Path:
+//   - /api/admin/api-keys/rotation-campaigns
+//
+// Requires:
+//   - Authentication: Admin role
+//
+// Responses:
+//   - 200 OK: List of rotation campaigns
+//   - 401 Unauthorized: User not authenticated
+//   - 403 Forbidden: User not authorized (not admin)
+//   - 500 Internal Server Error: Server-side error
+//
+// @Summary List rotation campaigns
+// @Description Returns the API key rotation campaigns with their progress, newest first
+// @Tags Admin/APIKeys
+// @Produce json
+// @Security BearerAuth
+// @Success 200 {object} utils.Response{data=[]models.RotationCampaign} "List of rotation campaigns"
+// @Failure 401 {object} utils.Response{error=string} "User not authenticated"
+// @Failure 403 {object} utils.Response{error=string} "User not authorized"
+// @Failure 500 {object} utils.Response{error=string} "Server error"
+// @Router /admin/api-keys/rotation-campaigns [get]
+func (h *APIKeyAdminHandler) ListCampaigns(w http.ResponseWriter, r *http.Request) {
+	campaigns, err := h.apiKeyAdminService.ListCampaigns(r.Context())
+	if err != nil {
+		utils.ErrorFromAppError(w, utils.ParseError(err))
+		return
+	}
+
+	utils.ListAll(w, r, campaigns)
+}
+
+// StartRotationCampaign requires the owners of the selected API keys to rotate them before a
+// deadline, such as after a suspected leak. The owners are emailed, and keys that aren't
+// rotated stop working at the deadline.
+//
+// HTTP Method:
+//   - POST
+//
+// URL Path:
+//   - /api/admin/api-keys/rotation-campaigns
+//
+// Requires:
+//   - Authentication: Admin role
+//
+// Request Body:
+//   - JSON object conforming to models.RotationCampaignCreate
+//
+// Responses:
+//   - 201 Created: Rotation campaign started
+//   - 400 Bad Request: Invalid request body or past deadline
+//   - 401 Unauthorized: User not authenticated
+//   - 403 Forbidden: User not authorized (not admin)
+//   - 500 Internal Server Error: Server-side error
+//
+// @Summary Start rotation campaign
+// @Description Requires the owners of the selected API keys to rotate them before a deadline
+// @Tags Admin/APIKeys
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param campaign body models.RotationCampaignCreate true "Campaign details"
+// @Success 201 {object} utils.Response{data=models.RotationCampaign} "Rotation campaign started"
+// @Failure 400 {object} utils.Response{error=string} "Invalid request body"
+// @Failure 401 {object} utils.Response{error=string} "User not authenticated"
+// @Failure 403 {object} utils.Response{error=string} "User not authorized"
+// @Failure 500 {object} utils.Response{error=string} "Server error"
+// @Router /admin/api-keys/rotation-campaigns [post]
+func (h *APIKeyAdminHandler) StartRotationCampaign(w http.ResponseWriter, r *http.Request) {
+	adminID, ok := auth.GetUserID(r)
+	if !ok {
+		utils.Unauthorized(w, constants.MsgAuthRequired)
+		return
+	}
+
+	var req models.RotationCampaignCreate
+	if err := utils.DecodeAndValidate(r, &req); err != nil {
+		utils.ErrorFromAppError(w, utils.ParseError(err))
+		return
+	}
+
+	campaign, err := h.apiKeyAdminService.StartRotationCampaign(r.Context(), adminID, &req)
+	if err != nil {
+		utils.ErrorFromAppError(w, utils.ParseError(err))
+		return
+	}
+
+	utils.JSON(w, constants.StatusCreated, campaign)
+}
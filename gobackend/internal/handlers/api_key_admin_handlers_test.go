@@ -0,0 +1,265 @@
+package handlers_test
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+
+	"github.com/yasinhessnawi1/Hideme_Backend/internal/constants"
+	"github.com/yasinhessnawi1/Hideme_Backend/internal/handlers"
+	"github.com/yasinhessnawi1/Hideme_Backend/internal/models"
+	"github.com/yasinhessnawi1/Hideme_Backend/internal/testsupport/fixtures"
+	"github.com/yasinhessnawi1/Hideme_Backend/internal/testsupport/golden"
+	"github.com/yasinhessnawi1/Hideme_Backend/internal/utils"
+)
+
+// MockAPIKeyAdminService is a mock implementation of the APIKeyAdminService
+type MockAPIKeyAdminService struct {
+	mock.Mock
+}
+
+func (m *MockAPIKeyAdminService) ListKeys(ctx context.Context, filter models.APIKeyFilter) ([]*models.AdminAPIKey, error) {
+	args := m.Called(ctx, filter)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]*models.AdminAPIKey), args.Error(1)
+}
+
+func (m *MockAPIKeyAdminService) DisableKey(ctx context.Context, adminID int64, keyID string) error {
+	args := m.Called(ctx, adminID, keyID)
+	return args.Error(0)
+}
+
+func (m *MockAPIKeyAdminService) GetPolicy(ctx context.Context) (*models.APIKeyPolicy, error) {
+	args := m.Called(ctx)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*models.APIKeyPolicy), args.Error(1)
+}
+
+func (m *MockAPIKeyAdminService) SetPolicy(ctx context.Context, adminID int64, update *models.APIKeyPolicyUpdate) (*models.APIKeyPolicyResult, error) {
+	args := m.Called(ctx, adminID, update)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*models.APIKeyPolicyResult), args.Error(1)
+}
+
+func (m *MockAPIKeyAdminService) StartRotationCampaign(ctx context.Context, adminID int64, req *models.RotationCampaignCreate) (*models.RotationCampaign, error) {
+	args := m.Called(ctx, adminID, req)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*models.RotationCampaign), args.Error(1)
+}
+
+func (m *MockAPIKeyAdminService) ListCampaigns(ctx context.Context) ([]*models.RotationCampaign, error) {
+	args := m.Called(ctx)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]*models.RotationCampaign), args.Error(1)
+}
+
+func setupAPIKeyAdminTest() (*chi.Mux, *MockAPIKeyAdminService) {
+	mockService := new(MockAPIKeyAdminService)
+	handler := handlers.NewAPIKeyAdminHandler(mockService)
+
+	router := chi.NewRouter()
+	router.Get("/api/admin/api-keys", handler.ListKeys)
+	router.Post("/api/admin/api-keys/{keyID}/disable", handler.DisableKey)
+	router.Get("/api/admin/api-keys/policy", handler.GetPolicy)
+	router.Put("/api/admin/api-keys/policy", handler.SetPolicy)
+	router.Get("/api/admin/api-keys/rotation-campaigns", handler.ListCampaigns)
+	router.Post("/api/admin/api-keys/rotation-campaigns", handler.StartRotationCampaign)
+
+	return router, mockService
+}
+
+func TestAPIKeyAdminHandler_ListKeys(t *testing.T) {
+	router, mockService := setupAPIKeyAdminTest()
+
+	t.Run("Success", func(t *testing.T) {
+		keys := []*models.AdminAPIKey{
+			{APIKey: models.APIKey{ID: "key-1", UserID: 2, Name: "CI"}, Username: "bob", Status: constants.APIKeyStatusPendingRotation},
+		}
+		filter := models.APIKeyFilter{UserID: 2, Status: constants.APIKeyStatusPendingRotation}
+		mockService.On("ListKeys", mock.Anything, filter).Return(keys, nil).Once()
+
+		req, err := http.NewRequest("GET", "/api/admin/api-keys?user_id=2&status=pending_rotation", nil)
+		require.NoError(t, err)
+		req = req.WithContext(createAuthContext(1))
+
+		rr := httptest.NewRecorder()
+		router.ServeHTTP(rr, req)
+
+		golden.AssertResponse(t, rr)
+	})
+
+	t.Run("Invalid User ID", func(t *testing.T) {
+		req, err := http.NewRequest("GET", "/api/admin/api-keys?user_id=abc", nil)
+		require.NoError(t, err)
+		req = req.WithContext(createAuthContext(1))
+
+		rr := httptest.NewRecorder()
+		router.ServeHTTP(rr, req)
+
+		assert.Equal(t, http.StatusBadRequest, rr.Code)
+	})
+
+	mockService.AssertExpectations(t)
+}
+
+func TestAPIKeyAdminHandler_DisableKey(t *testing.T) {
+	router, mockService := setupAPIKeyAdminTest()
+
+	t.Run("Success", func(t *testing.T) {
+		mockService.On("DisableKey", mock.Anything, int64(1), "key-1").Return(nil).Once()
+
+		req, err := http.NewRequest("POST", "/api/admin/api-keys/key-1/disable", nil)
+		require.NoError(t, err)
+		req = req.WithContext(createAuthContext(1))
+
+		rr := httptest.NewRecorder()
+		router.ServeHTTP(rr, req)
+
+		assert.Equal(t, http.StatusNoContent, rr.Code)
+	})
+
+	t.Run("Already Disabled", func(t *testing.T) {
+		mockService.On("DisableKey", mock.Anything, int64(1), "key-1").
+			Return(utils.New(utils.ErrBadRequest, constants.StatusConflict, "API key is already disabled")).Once()
+
+		req, err := http.NewRequest("POST", "/api/admin/api-keys/key-1/disable", nil)
+		require.NoError(t, err)
+		req = req.WithContext(createAuthContext(1))
+
+		rr := httptest.NewRecorder()
+		router.ServeHTTP(rr, req)
+
+		assert.Equal(t, http.StatusConflict, rr.Code)
+	})
+
+	t.Run("Unauthenticated", func(t *testing.T) {
+		req, err := http.NewRequest("POST", "/api/admin/api-keys/key-1/disable", nil)
+		require.NoError(t, err)
+
+		rr := httptest.NewRecorder()
+		router.ServeHTTP(rr, req)
+
+		assert.Equal(t, http.StatusUnauthorized, rr.Code)
+	})
+
+	mockService.AssertExpectations(t)
+}
+
+func TestAPIKeyAdminHandler_Policy(t *testing.T) {
+	router, mockService := setupAPIKeyAdminTest()
+
+	t.Run("Get", func(t *testing.T) {
+		mockService.On("GetPolicy", mock.Anything).Return(&models.APIKeyPolicy{MaxLifetimeDays: 90}, nil).Once()
+
+		req, err := http.NewRequest("GET", "/api/admin/api-keys/policy", nil)
+		require.NoError(t, err)
+		req = req.WithContext(createAuthContext(1))
+
+		rr := httptest.NewRecorder()
+		router.ServeHTTP(rr, req)
+
+		golden.AssertResponse(t, rr)
+	})
+
+	t.Run("Set", func(t *testing.T) {
+		update := &models.APIKeyPolicyUpdate{MaxLifetimeDays: 30}
+		result := &models.APIKeyPolicyResult{Policy: &models.APIKeyPolicy{MaxLifetimeDays: 30}, KeysShortened: 4}
+		mockService.On("SetPolicy", mock.Anything, int64(1), update).Return(result, nil).Once()
+
+		body, _ := json.Marshal(update)
+		req, err := http.NewRequest("PUT", "/api/admin/api-keys/policy", bytes.NewBuffer(body))
+		require.NoError(t, err)
+		req.Header.Set("Content-Type", "application/json")
+		req = req.WithContext(createAuthContext(1))
+
+		rr := httptest.NewRecorder()
+		router.ServeHTTP(rr, req)
+
+		golden.AssertResponse(t, rr)
+	})
+
+	t.Run("Set Invalid", func(t *testing.T) {
+		req, err := http.NewRequest("PUT", "/api/admin/api-keys/policy", bytes.NewBufferString(`{"max_lifetime_days":-1}`))
+		require.NoError(t, err)
+		req.Header.Set("Content-Type", "application/json")
+		req = req.WithContext(createAuthContext(1))
+
+		rr := httptest.NewRecorder()
+		router.ServeHTTP(rr, req)
+
+		assert.Equal(t, http.StatusBadRequest, rr.Code)
+	})
+
+	mockService.AssertExpectations(t)
+}
+
+func TestAPIKeyAdminHandler_RotationCampaigns(t *testing.T) {
+	router, mockService := setupAPIKeyAdminTest()
+	deadline := fixtures.Time.Add(7 * 24 * time.Hour)
+
+	t.Run("Start", func(t *testing.T) {
+		campaign := &models.RotationCampaign{ID: 5, Name: "Leaked CI logs", Deadline: deadline, KeyCount: 3}
+		mockService.On("StartRotationCampaign", mock.Anything, int64(1), mock.MatchedBy(func(req *models.RotationCampaignCreate) bool {
+			return req.Name == "Leaked CI logs" && req.Deadline.Equal(deadline)
+		})).Return(campaign, nil).Once()
+
+		body, _ := json.Marshal(models.RotationCampaignCreate{Name: "Leaked CI logs", Deadline: deadline})
+		req, err := http.NewRequest("POST", "/api/admin/api-keys/rotation-campaigns", bytes.NewBuffer(body))
+		require.NoError(t, err)
+		req.Header.Set("Content-Type", "application/json")
+		req = req.WithContext(createAuthContext(1))
+
+		rr := httptest.NewRecorder()
+		router.ServeHTTP(rr, req)
+
+		golden.AssertResponse(t, rr)
+	})
+
+	t.Run("Start Missing Name", func(t *testing.T) {
+		body, _ := json.Marshal(models.RotationCampaignCreate{Deadline: deadline})
+		req, err := http.NewRequest("POST", "/api/admin/api-keys/rotation-campaigns", bytes.NewBuffer(body))
+		require.NoError(t, err)
+		req.Header.Set("Content-Type", "application/json")
+		req = req.WithContext(createAuthContext(1))
+
+		rr := httptest.NewRecorder()
+		router.ServeHTTP(rr, req)
+
+		assert.Equal(t, http.StatusBadRequest, rr.Code)
+	})
+
+	t.Run("List", func(t *testing.T) {
+		campaigns := []*models.RotationCampaign{{ID: 5, Name: "Leaked CI logs", KeyCount: 3, RotatedCount: 1}}
+		mockService.On("ListCampaigns", mock.Anything).Return(campaigns, nil).Once()
+
+		req, err := http.NewRequest("GET", "/api/admin/api-keys/rotation-campaigns", nil)
+		require.NoError(t, err)
+		req = req.WithContext(createAuthContext(1))
+
+		rr := httptest.NewRecorder()
+		router.ServeHTTP(rr, req)
+
+		golden.AssertResponse(t, rr)
+	})
+
+	mockService.AssertExpectations(t)
+}
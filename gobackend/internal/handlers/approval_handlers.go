@@ -0,0 +1,308 @@
+// Package handlers provides HTTP request handlers for the HideMe API.
+package handlers
+
+import (
+	"context"
+	"net/http"
+	"strconv"
+
+	"github.com/go-chi/chi/v5"
+
+	"github.com/yasinhessnawi1/Hideme_Backend/internal/auth"
+	"github.com/yasinhessnawi1/Hideme_Backend/internal/constants"
+	"github.com/yasinhessnawi1/Hideme_Backend/internal/models"
+	"github.com/yasinhessnawi1/Hideme_Backend/internal/utils"
+)
+
+// ApprovalServiceInterface defines the service methods required for the admin approval workflow.
+type ApprovalServiceInterface interface {
+	RequestAction(ctx context.Context, requesterID int64, req *models.AdminActionRequest) (*models.AdminAction, error)
+	ApproveAction(ctx context.Context, id int64, reviewerID int64, note string) (*models.AdminAction, error)
+	RejectAction(ctx context.Context, id int64, reviewerID int64, note string) (*models.AdminAction, error)
+	GetAction(ctx context.Context, id int64) (*models.AdminAction, error)
+	ListActions(ctx context.Context, status models.AdminActionStatus) ([]*models.AdminAction, error)
+}
+
+// ApprovalHandler handles HTTP requests for destructive admin actions and their approval.
+type ApprovalHandler struct {
+	approvalService ApprovalServiceInterface
+}
+
+// NewApprovalHandler creates a new ApprovalHandler with the provided approval service.
+//
+// Parameters:
+//   - approvalService: Service handling the approval workflow
+//
+// Returns:
+//   - A properly initialized ApprovalHandler
+func NewApprovalHandler(approvalService ApprovalServiceInterface) *ApprovalHandler {
+	return &ApprovalHandler{
+		approvalService: approvalService,
+	}
+}
+
+// ListActions returns queued and completed admin actions.
+//
+// HTTP Method:
+//   - GET
+//
+// URL Path:
+//   - /api/admin/actions
+//
+// Query Parameters:
+//   - status: Only return actions in this status (optional)
+//
+// Requires:
+//   - Authentication: Admin role
+//
+// Responses:
+//   - 200 OK: List of admin actions
+//   - 401 Unauthorized: User not authenticated
+//   - 403 Forbidden: User not authorized (not admin)
+//   - 500 Internal Server Error: Server-side error
+//
+// @Summary List admin actions
+// @Description Returns destructive admin actions, newest first, optionally filtered by status
+// @Tags Admin/Approvals
+// @Produce json
+// @Security BearerAuth
+// @Param status query string false "Filter by status (pending, approved, executed, failed, rejected, expired)"
+// @Success 200 {object} utils.Response{data=[]models.AdminAction} "List of admin actions"
+// @Failure 401 {object} utils.Response{error=string} "User not authenticated"
+// @Failure 403 {object} utils.Response{error=string} "User not authorized"
+// @Failure 500 {object} utils.Response{error=string} "Server error"
+// @Router /admin/actions [get]
+func (h *ApprovalHandler) ListActions(w http.ResponseWriter, r *http.Request) {
+	status := models.AdminActionStatus(r.URL.Query().Get(constants.QueryParamStatus))
+
+	actions, err := h.approvalService.ListActions(r.Context(), status)
+	if err != nil {
+		utils.ErrorFromAppError(w, utils.ParseError(err))
+		return
+	}
+
+	utils.ListAll(w, r, actions)
+}
+
+// GetAction returns a single admin action.
+//
+// HTTP Method:
+//   - GET
+//
+// URL Path:
+//   - /api/admin/actions/{id}
+//
+// Requires:
+//   - Authentication: Admin role
+//
+// Responses:
+//   - 200 OK: The admin action
+//   - 400 Bad Request: Invalid action ID
+//   - 401 Unauthorized: User not authenticated
+//   - 403 Forbidden: User not authorized (not admin)
+//   - 404 Not Found: Action not found
+//   - 500 Internal Server Error: Server-side error
+//
+// @Summary Get admin action
+// @Description Returns a single destructive admin action and its approval state
+// @Tags Admin/Approvals
+// @Produce json
+// @Security BearerAuth
+// @Param id path int true "Action ID"
+// @Success 200 {object} utils.Response{data=models.AdminAction} "The admin action"
+// @Failure 400 {object} utils.Response{error=string} "Invalid action ID"
+// @Failure 401 {object} utils.Response{error=string} "User not authenticated"
+// @Failure 403 {object} utils.Response{error=string} "User not authorized"
+// @Failure 404 {object} utils.Response{error=string} "Action not found"
+// @Failure 500 {object} utils.Response{error=string} "Server error"
+// @Router /admin/actions/{id} [get]
+func (h *ApprovalHandler) GetAction(w http.ResponseWriter, r *http.Request) {
+	actionID, err := strconv.ParseInt(chi.URLParam(r, constants.ParamID), 10, 64)
+	if err != nil {
+		utils.BadRequest(w, "Invalid action ID", nil)
+		return
+	}
+
+	action, err := h.approvalService.GetAction(r.Context(), actionID)
+	if err != nil {
+		utils.ErrorFromAppError(w, utils.ParseError(err))
+		return
+	}
+
+	utils.JSON(w, constants.StatusOK, action)
+}
+
+// RequestAction requests a destructive admin action.
+// Actions covered by the approval policy are queued until a second administrator approves them.
+//
+// HTTP Method:
+//   - POST
+//
+// URL Path:
+//   - /api/admin/actions
+//
+// Request Body:
+//   - JSON object with "action_type", "target_id" and "reason" fields
+//
+// Requires:
+//   - Authentication: Admin role
+//
+// Responses:
+//   - 201 Created: Action recorded (pending, or executed if no approval is required)
+//   - 400 Bad Request: Invalid request body or unsupported action type
+//   - 401 Unauthorized: User not authenticated
+//   - 403 Forbidden: User not authorized (not admin)
+//   - 500 Internal Server Error: Server-side error
+//
+// @Summary Request admin action
+// @Description Requests a destructive admin action such as user erasure; high-risk actions wait for a second administrator
+// @Tags Admin/Approvals
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param action body models.AdminActionRequest true "Requested action"
+// @Success 201 {object} utils.Response{data=models.AdminAction} "Action recorded"
+// @Failure 400 {object} utils.Response{error=string} "Invalid request"
+// @Failure 401 {object} utils.Response{error=string} "User not authenticated"
+// @Failure 403 {object} utils.Response{error=string} "User not authorized"
+// @Failure 500 {object} utils.Response{error=string} "Server error"
+// @Router /admin/actions [post]
+func (h *ApprovalHandler) RequestAction(w http.ResponseWriter, r *http.Request) {
+	userID, ok := auth.GetUserID(r)
+	if !ok {
+		utils.Unauthorized(w, constants.MsgAuthRequired)
+		return
+	}
+
+	var req models.AdminActionRequest
+	if err := utils.DecodeAndValidate(r, &req); err != nil {
+		utils.ErrorFromAppError(w, utils.ParseError(err))
+		return
+	}
+
+	action, err := h.approvalService.RequestAction(r.Context(), userID, &req)
+	if err != nil {
+		utils.ErrorFromAppError(w, utils.ParseError(err))
+		return
+	}
+
+	utils.JSON(w, constants.StatusCreated, action)
+}
+
+// ApproveAction approves and executes a pending admin action.
+//
+// HTTP Method:
+//   - POST
+//
+// URL Path:
+//   - /api/admin/actions/{id}/approve
+//
+// Request Body:
+//   - Optional JSON object with a "note" field
+//
+// Requires:
+//   - Authentication: Admin role, different from the requesting administrator
+//
+// Responses:
+//   - 200 OK: Action approved and executed
+//   - 400 Bad Request: Invalid action ID or action expired
+//   - 401 Unauthorized: User not authenticated
+//   - 403 Forbidden: User not authorized or approving their own request
+//   - 404 Not Found: Action not found
+//   - 409 Conflict: Action is no longer pending
+//   - 500 Internal Server Error: Server-side error
+//
+// @Summary Approve admin action
+// @Description Approves a pending admin action requested by another administrator and executes it
+// @Tags Admin/Approvals
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path int true "Action ID"
+// @Param review body models.AdminActionReview false "Optional review note"
+// @Success 200 {object} utils.Response{data=models.AdminAction} "Action executed"
+// @Failure 400 {object} utils.Response{error=string} "Invalid action ID or action expired"
+// @Failure 401 {object} utils.Response{error=string} "User not authenticated"
+// @Failure 403 {object} utils.Response{error=string} "User not authorized"
+// @Failure 404 {object} utils.Response{error=string} "Action not found"
+// @Failure 409 {object} utils.Response{error=string} "Action is no longer pending"
+// @Failure 500 {object} utils.Response{error=string} "Server error"
+// @Router /admin/actions/{id}/approve [post]
+func (h *ApprovalHandler) ApproveAction(w http.ResponseWriter, r *http.Request) {
+	h.reviewAction(w, r, h.approvalService.ApproveAction)
+}
+
+// RejectAction rejects a pending admin action.
+//
+// HTTP Method:
+//   - POST
+//
+// URL Path:
+//   - /api/admin/actions/{id}/reject
+//
+// Request Body:
+//   - Optional JSON object with a "note" field
+//
+// Requires:
+//   - Authentication: Admin role
+//
+// Responses:
+//   - 200 OK: Action rejected
+//   - 400 Bad Request: Invalid action ID or action expired
+//   - 401 Unauthorized: User not authenticated
+//   - 403 Forbidden: User not authorized (not admin)
+//   - 404 Not Found: Action not found
+//   - 409 Conflict: Action is no longer pending
+//   - 500 Internal Server Error: Server-side error
+//
+// @Summary Reject admin action
+// @Description Rejects a pending admin action so it will never run
+// @Tags Admin/Approvals
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path int true "Action ID"
+// @Param review body models.AdminActionReview false "Optional review note"
+// @Success 200 {object} utils.Response{data=models.AdminAction} "Action rejected"
+// @Failure 400 {object} utils.Response{error=string} "Invalid action ID or action expired"
+// @Failure 401 {object} utils.Response{error=string} "User not authenticated"
+// @Failure 403 {object} utils.Response{error=string} "User not authorized"
+// @Failure 404 {object} utils.Response{error=string} "Action not found"
+// @Failure 409 {object} utils.Response{error=string} "Action is no longer pending"
+// @Failure 500 {object} utils.Response{error=string} "Server error"
+// @Router /admin/actions/{id}/reject [post]
+func (h *ApprovalHandler) RejectAction(w http.ResponseWriter, r *http.Request) {
+	h.reviewAction(w, r, h.approvalService.RejectAction)
+}
+
+// reviewAction handles the shared request parsing of the approve and reject endpoints.
+func (h *ApprovalHandler) reviewAction(w http.ResponseWriter, r *http.Request, review func(ctx context.Context, id int64, reviewerID int64, note string) (*models.AdminAction, error)) {
+	userID, ok := auth.GetUserID(r)
+	if !ok {
+		utils.Unauthorized(w, constants.MsgAuthRequired)
+		return
+	}
+
+	actionID, err := strconv.ParseInt(chi.URLParam(r, constants.ParamID), 10, 64)
+	if err != nil {
+		utils.BadRequest(w, "Invalid action ID", nil)
+		return
+	}
+
+	// The review note is optional, so an empty body is accepted
+	var req models.AdminActionReview
+	if r.ContentLength != 0 {
+		if err := utils.DecodeAndValidate(r, &req); err != nil {
+			utils.ErrorFromAppError(w, utils.ParseError(err))
+			return
+		}
+	}
+
+	action, err := review(r.Context(), actionID, userID, req.Note)
+	if err != nil {
+		utils.ErrorFromAppError(w, utils.ParseError(err))
+		return
+	}
+
+	utils.JSON(w, constants.StatusOK, action)
+}
@@ -0,0 +1,209 @@
+package handlers_test
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+
+	"github.com/yasinhessnawi1/Hideme_Backend/internal/handlers"
+	"github.com/yasinhessnawi1/Hideme_Backend/internal/models"
+	"github.com/yasinhessnawi1/Hideme_Backend/internal/testsupport/golden"
+	"github.com/yasinhessnawi1/Hideme_Backend/internal/utils"
+)
+
+// MockApprovalService is a mock implementation of the ApprovalService
+type MockApprovalService struct {
+	mock.Mock
+}
+
+func (m *MockApprovalService) RequestAction(ctx context.Context, requesterID int64, req *models.AdminActionRequest) (*models.AdminAction, error) {
+	args := m.Called(ctx, requesterID, req)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*models.AdminAction), args.Error(1)
+}
+
+func (m *MockApprovalService) ApproveAction(ctx context.Context, id int64, reviewerID int64, note string) (*models.AdminAction, error) {
+	args := m.Called(ctx, id, reviewerID, note)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*models.AdminAction), args.Error(1)
+}
+
+func (m *MockApprovalService) RejectAction(ctx context.Context, id int64, reviewerID int64, note string) (*models.AdminAction, error) {
+	args := m.Called(ctx, id, reviewerID, note)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*models.AdminAction), args.Error(1)
+}
+
+func (m *MockApprovalService) GetAction(ctx context.Context, id int64) (*models.AdminAction, error) {
+	args := m.Called(ctx, id)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*models.AdminAction), args.Error(1)
+}
+
+func (m *MockApprovalService) ListActions(ctx context.Context, status models.AdminActionStatus) ([]*models.AdminAction, error) {
+	args := m.Called(ctx, status)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]*models.AdminAction), args.Error(1)
+}
+
+func setupApprovalTest() (*chi.Mux, *MockApprovalService) {
+	mockService := new(MockApprovalService)
+	handler := handlers.NewApprovalHandler(mockService)
+
+	router := chi.NewRouter()
+	router.Get("/api/admin/actions", handler.ListActions)
+	router.Post("/api/admin/actions", handler.RequestAction)
+	router.Get("/api/admin/actions/{id}", handler.GetAction)
+	router.Post("/api/admin/actions/{id}/approve", handler.ApproveAction)
+	router.Post("/api/admin/actions/{id}/reject", handler.RejectAction)
+
+	return router, mockService
+}
+
+func TestListActions(t *testing.T) {
+	router, mockService := setupApprovalTest()
+
+	actions := []*models.AdminAction{{ID: 1, ActionType: models.ActionUserErasure, Status: models.ActionStatusPending}}
+	mockService.On("ListActions", mock.Anything, models.ActionStatusPending).Return(actions, nil).Once()
+
+	req, err := http.NewRequest("GET", "/api/admin/actions?status=pending", nil)
+	require.NoError(t, err)
+	req = req.WithContext(createAuthContext(1))
+
+	rr := httptest.NewRecorder()
+	router.ServeHTTP(rr, req)
+
+	assert.Equal(t, http.StatusOK, rr.Code)
+	mockService.AssertExpectations(t)
+}
+
+func TestRequestAction(t *testing.T) {
+	router, mockService := setupApprovalTest()
+
+	t.Run("Success", func(t *testing.T) {
+		expected := &models.AdminAction{ID: 5, ActionType: models.ActionUserErasure, TargetID: 42, Status: models.ActionStatusPending, RequestedBy: 1}
+		mockService.On("RequestAction", mock.Anything, int64(1), &models.AdminActionRequest{
+			ActionType: models.ActionUserErasure, TargetID: 42, Reason: "GDPR request",
+		}).Return(expected, nil).Once()
+
+		body, _ := json.Marshal(map[string]interface{}{"action_type": "user_erasure", "target_id": 42, "reason": "GDPR request"})
+		req, err := http.NewRequest("POST", "/api/admin/actions", bytes.NewBuffer(body))
+		require.NoError(t, err)
+		req.Header.Set("Content-Type", "application/json")
+		req = req.WithContext(createAuthContext(1))
+
+		rr := httptest.NewRecorder()
+		router.ServeHTTP(rr, req)
+
+		golden.AssertResponse(t, rr)
+		mockService.AssertExpectations(t)
+	})
+
+	t.Run("Missing target", func(t *testing.T) {
+		body, _ := json.Marshal(map[string]interface{}{"action_type": "user_erasure", "reason": "GDPR request"})
+		req, err := http.NewRequest("POST", "/api/admin/actions", bytes.NewBuffer(body))
+		require.NoError(t, err)
+		req.Header.Set("Content-Type", "application/json")
+		req = req.WithContext(createAuthContext(1))
+
+		rr := httptest.NewRecorder()
+		router.ServeHTTP(rr, req)
+
+		assert.Equal(t, http.StatusBadRequest, rr.Code)
+	})
+
+	t.Run("Unauthenticated", func(t *testing.T) {
+		req, err := http.NewRequest("POST", "/api/admin/actions", bytes.NewBufferString("{}"))
+		require.NoError(t, err)
+
+		rr := httptest.NewRecorder()
+		router.ServeHTTP(rr, req)
+
+		assert.Equal(t, http.StatusUnauthorized, rr.Code)
+	})
+}
+
+func TestApproveAction(t *testing.T) {
+	router, mockService := setupApprovalTest()
+
+	t.Run("Success without note", func(t *testing.T) {
+		reviewer := int64(2)
+		executed := &models.AdminAction{ID: 5, Status: models.ActionStatusExecuted, RequestedBy: 1, ReviewedBy: &reviewer}
+		mockService.On("ApproveAction", mock.Anything, int64(5), int64(2), "").Return(executed, nil).Once()
+
+		req, err := http.NewRequest("POST", "/api/admin/actions/5/approve", nil)
+		require.NoError(t, err)
+		req = req.WithContext(createAuthContext(2))
+
+		rr := httptest.NewRecorder()
+		router.ServeHTTP(rr, req)
+
+		assert.Equal(t, http.StatusOK, rr.Code)
+		mockService.AssertExpectations(t)
+	})
+
+	t.Run("Own request", func(t *testing.T) {
+		mockService.On("ApproveAction", mock.Anything, int64(6), int64(1), "looks good").
+			Return(nil, utils.NewForbiddenError("An admin action must be approved by a different administrator")).Once()
+
+		body, _ := json.Marshal(map[string]string{"note": "looks good"})
+		req, err := http.NewRequest("POST", "/api/admin/actions/6/approve", bytes.NewBuffer(body))
+		require.NoError(t, err)
+		req.Header.Set("Content-Type", "application/json")
+		req = req.WithContext(createAuthContext(1))
+
+		rr := httptest.NewRecorder()
+		router.ServeHTTP(rr, req)
+
+		assert.Equal(t, http.StatusForbidden, rr.Code)
+		mockService.AssertExpectations(t)
+	})
+
+	t.Run("Invalid ID", func(t *testing.T) {
+		req, err := http.NewRequest("POST", "/api/admin/actions/abc/approve", nil)
+		require.NoError(t, err)
+		req = req.WithContext(createAuthContext(2))
+
+		rr := httptest.NewRecorder()
+		router.ServeHTTP(rr, req)
+
+		assert.Equal(t, http.StatusBadRequest, rr.Code)
+	})
+}
+
+func TestRejectAction(t *testing.T) {
+	router, mockService := setupApprovalTest()
+
+	rejected := &models.AdminAction{ID: 5, Status: models.ActionStatusRejected}
+	mockService.On("RejectAction", mock.Anything, int64(5), int64(2), "Wrong account").Return(rejected, nil).Once()
+
+	body, _ := json.Marshal(map[string]string{"note": "Wrong account"})
+	req, err := http.NewRequest("POST", "/api/admin/actions/5/reject", bytes.NewBuffer(body))
+	require.NoError(t, err)
+	req.Header.Set("Content-Type", "application/json")
+	req = req.WithContext(createAuthContext(2))
+
+	rr := httptest.NewRecorder()
+	router.ServeHTTP(rr, req)
+
+	assert.Equal(t, http.StatusOK, rr.Code)
+	mockService.AssertExpectations(t)
+}
@@ -0,0 +1,446 @@
+// Package handlers provides HTTP request handlers for the HideMe API.
+package handlers
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/rs/zerolog/log"
+
+	"github.com/yasinhessnawi1/Hideme_Backend/internal/auth"
+	"github.com/yasinhessnawi1/Hideme_Backend/internal/constants"
+	"github.com/yasinhessnawi1/Hideme_Backend/internal/models"
+	"github.com/yasinhessnawi1/Hideme_Backend/internal/service"
+	"github.com/yasinhessnawi1/Hideme_Backend/internal/utils"
+)
+
+// AuditorGrantServiceInterface defines the service methods required for granting external
+// auditors read-only access and serving their requests.
+type AuditorGrantServiceInterface interface {
+	CreateGrant(ctx context.Context, adminID int64, req *models.AuditorGrantRequest) (*models.AuditorGrantCreated, error)
+	ListGrants(ctx context.Context) ([]*models.AuditorGrant, error)
+	RevokeGrant(ctx context.Context, adminID, grantID int64) error
+	ListAccess(ctx context.Context, grantID int64) ([]*models.AuditorAccess, error)
+	Authenticate(ctx context.Context, token string) (*models.AuditorGrant, error)
+	ListDocuments(ctx context.Context, grant *models.AuditorGrant, ipAddress string) ([]*models.Document, error)
+	GetDocument(ctx context.Context, grant *models.AuditorGrant, documentID int64, ipAddress string) (*models.Document, error)
+	ExportDocument(ctx context.Context, grant *models.AuditorGrant, documentID int64, ipAddress string) (*models.DocumentExport, error)
+}
+
+// AuditorGrantHandler handles HTTP requests for administrators managing auditor access grants
+// and for auditors reading the documents granted to them.
+type AuditorGrantHandler struct {
+	grantService AuditorGrantServiceInterface
+}
+
+// NewAuditorGrantHandler creates a new AuditorGrantHandler with the provided service.
+//
+// Parameters:
+//   - grantService: Service managing auditor grants and serving auditor requests
+//
+// Returns:
+//   - A properly initialized AuditorGrantHandler
+func NewAuditorGrantHandler(grantService AuditorGrantServiceInterface) *AuditorGrantHandler {
+	return &AuditorGrantHandler{
+		grantService: grantService,
+	}
+}
+
+// CreateGrant grants an external auditor time-boxed, read-only access to documents.
+// The token is returned only in this response.
+//
+// HTTP Method:
+//   - POST
+//
+// URL Path:
+//   - /api/admin/auditor-grants
+//
+// Request Body:
+//   - JSON object with "auditor_name", "auditor_email", "expires_in_days", "reason" and
+//     "document_ids" and/or "user_ids" fields
+//
+// Requires:
+//   - Authentication: Admin role
+//
+// Responses:
+//   - 201 Created: Grant created, with the auditor token
+//   - 400 Bad Request: Invalid request body or the grant covers nothing
+//   - 401 Unauthorized: User not authenticated
+//   - 403 Forbidden: User not authorized (not admin)
+//   - 500 Internal Server Error: Server-side error
+//
+// @Summary Grant an auditor access
+// @Description Grants an external auditor read-only access to selected documents and/or all documents of selected users until the grant expires or is revoked
+// @Tags Admin/Auditors
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param grant body models.AuditorGrantRequest true "Access to grant"
+// @Success 201 {object} utils.Response{data=models.AuditorGrantCreated} "Grant created"
+// @Failure 400 {object} utils.Response{error=string} "Invalid request"
+// @Failure 401 {object} utils.Response{error=string} "User not authenticated"
+// @Failure 403 {object} utils.Response{error=string} "User not authorized"
+// @Failure 500 {object} utils.Response{error=string} "Server error"
+// @Router /admin/auditor-grants [post]
+func (h *AuditorGrantHandler) CreateGrant(w http.ResponseWriter, r *http.Request) {
+	adminID, ok := auth.GetUserID(r)
+	if !ok {
+		utils.Unauthorized(w, constants.MsgAuthRequired)
+		return
+	}
+
+	var req models.AuditorGrantRequest
+	if err := utils.DecodeAndValidate(r, &req); err != nil {
+		utils.ErrorFromAppError(w, utils.ParseError(err))
+		return
+	}
+
+	grant, err := h.grantService.CreateGrant(r.Context(), adminID, &req)
+	if err != nil {
+		utils.ErrorFromAppError(w, utils.ParseError(err))
+		return
+	}
+
+	utils.JSON(w, constants.StatusCreated, grant)
+}
+
+// ListGrants returns the most recent auditor grants, including expired and revoked ones.
+//
+// HTTP Method:
+//   - GET
+//
+// URL Path:
+//   - /api/admin/auditor-grants
+//
+// Requires:
+//   - Authentication: Admin role
+//
+// Responses:
+//   - 200 OK: List of auditor grants
+//   - 401 Unauthorized: User not authenticated
+//   - 403 Forbidden: User not authorized (not admin)
+//   - 500 Internal Server Error: Server-side error
+//
+// @Summary List auditor grants
+// @Description Returns the auditor access grants, newest first, including expired and revoked ones
+// @Tags Admin/Auditors
+// @Produce json
+// @Security BearerAuth
+// @Success 200 {object} utils.Response{data=[]models.AuditorGrant} "List of auditor grants"
+// @Failure 401 {object} utils.Response{error=string} "User not authenticated"
+// @Failure 403 {object} utils.Response{error=string} "User not authorized"
+// @Failure 500 {object} utils.Response{error=string} "Server error"
+// @Router /admin/auditor-grants [get]
+func (h *AuditorGrantHandler) ListGrants(w http.ResponseWriter, r *http.Request) {
+	grants, err := h.grantService.ListGrants(r.Context())
+	if err != nil {
+		utils.ErrorFromAppError(w, utils.ParseError(err))
+		return
+	}
+
+	utils.ListAll(w, r, grants)
+}
+
+// RevokeGrant ends an auditor grant immediately.
+//
+// HTTP Method:
+//   - DELETE
+//
+// URL Path:
+//   - /api/admin/auditor-grants/{id}
+//
+// Requires:
+//   - Authentication: Admin role
+//
+// Responses:
+//   - 204 No Content: Grant revoked
+//   - 400 Bad Request: Invalid grant ID
+//   - 401 Unauthorized: User not authenticated
+//   - 403 Forbidden: User not authorized (not admin)
+//   - 404 Not Found: Grant not found
+//   - 500 Internal Server Error: Server-side error
+//
+// @Summary Revoke an auditor grant
+// @Description Revokes an auditor access grant so its token stops working
+// @Tags Admin/Auditors
+// @Security BearerAuth
+// @Param id path int true "Grant ID"
+// @Success 204 "Grant revoked"
+// @Failure 400 {object} utils.Response{error=string} "Invalid grant ID"
+// @Failure 401 {object} utils.Response{error=string} "User not authenticated"
+// @Failure 403 {object} utils.Response{error=string} "User not authorized"
+// @Failure 404 {object} utils.Response{error=string} "Grant not found"
+// @Failure 500 {object} utils.Response{error=string} "Server error"
+// @Router /admin/auditor-grants/{id} [delete]
+func (h *AuditorGrantHandler) RevokeGrant(w http.ResponseWriter, r *http.Request) {
+	adminID, ok := auth.GetUserID(r)
+	if !ok {
+		utils.Unauthorized(w, constants.MsgAuthRequired)
+		return
+	}
+
+	id, err := strconv.ParseInt(chi.URLParam(r, constants.ParamID), 10, 64)
+	if err != nil {
+		utils.BadRequest(w, "Invalid grant ID", nil)
+		return
+	}
+
+	if err := h.grantService.RevokeGrant(r.Context(), adminID, id); err != nil {
+		utils.ErrorFromAppError(w, utils.ParseError(err))
+		return
+	}
+
+	utils.NoContent(w)
+}
+
+// ListAccess returns the most recent requests made with an auditor grant.
+//
+// HTTP Method:
+//   - GET
+//
+// URL Path:
+//   - /api/admin/auditor-grants/{id}/access
+//
+// Requires:
+//   - Authentication: Admin role
+//
+// Responses:
+//   - 200 OK: Access log of the grant
+//   - 400 Bad Request: Invalid grant ID
+//   - 401 Unauthorized: User not authenticated
+//   - 403 Forbidden: User not authorized (not admin)
+//   - 500 Internal Server Error: Server-side error
+//
+// @Summary List auditor access
+// @Description Returns the requests made with an auditor access grant, newest first, including refused ones
+// @Tags Admin/Auditors
+// @Produce json
+// @Security BearerAuth
+// @Param id path int true "Grant ID"
+// @Success 200 {object} utils.Response{data=[]models.AuditorAccess} "Access log"
+// @Failure 400 {object} utils.Response{error=string} "Invalid grant ID"
+// @Failure 401 {object} utils.Response{error=string} "User not authenticated"
+// @Failure 403 {object} utils.Response{error=string} "User not authorized"
+// @Failure 500 {object} utils.Response{error=string} "Server error"
+// @Router /admin/auditor-grants/{id}/access [get]
+func (h *AuditorGrantHandler) ListAccess(w http.ResponseWriter, r *http.Request) {
+	id, err := strconv.ParseInt(chi.URLParam(r, constants.ParamID), 10, 64)
+	if err != nil {
+		utils.BadRequest(w, "Invalid grant ID", nil)
+		return
+	}
+
+	accesses, err := h.grantService.ListAccess(r.Context(), id)
+	if err != nil {
+		utils.ErrorFromAppError(w, utils.ParseError(err))
+		return
+	}
+
+	utils.ListAll(w, r, accesses)
+}
+
+// ListDocuments returns the documents covered by the auditor's grant.
+//
+// HTTP Method:
+//   - GET
+//
+// URL Path:
+//   - /api/auditor/documents
+//
+// Requires:
+//   - X-Auditor-Token header with an active grant token
+//
+// Responses:
+//   - 200 OK: List of covered documents
+//   - 401 Unauthorized: Missing, unknown, expired or revoked token
+//   - 500 Internal Server Error: Server-side error
+//
+// @Summary List audited documents
+// @Description Returns the documents an auditor may read under their access grant
+// @Tags Auditor
+// @Produce json
+// @Param X-Auditor-Token header string true "Auditor token"
+// @Success 200 {object} utils.Response{data=[]models.Document} "List of documents"
+// @Failure 401 {object} utils.Response{error=string} "Invalid auditor token"
+// @Failure 500 {object} utils.Response{error=string} "Server error"
+// @Router /auditor/documents [get]
+func (h *AuditorGrantHandler) ListDocuments(w http.ResponseWriter, r *http.Request) {
+	grant, ok := h.authenticate(w, r)
+	if !ok {
+		return
+	}
+
+	docs, err := h.grantService.ListDocuments(r.Context(), grant, utils.GetClientIP(r))
+	if err != nil {
+		utils.ErrorFromAppError(w, utils.ParseError(err))
+		return
+	}
+
+	utils.ListAll(w, r, docs)
+}
+
+// GetDocument returns a document covered by the auditor's grant.
+//
+// HTTP Method:
+//   - GET
+//
+// URL Path:
+//   - /api/auditor/documents/{id}
+//
+// Requires:
+//   - X-Auditor-Token header with an active grant token
+//
+// Responses:
+//   - 200 OK: The document
+//   - 400 Bad Request: Invalid document ID
+//   - 401 Unauthorized: Missing, unknown, expired or revoked token
+//   - 404 Not Found: Document not found or not covered by the grant
+//   - 500 Internal Server Error: Server-side error
+//
+// @Summary Get an audited document
+// @Description Returns a document covered by the auditor's access grant; every request is logged
+// @Tags Auditor
+// @Produce json
+// @Param X-Auditor-Token header string true "Auditor token"
+// @Param id path int true "Document ID"
+// @Success 200 {object} utils.Response{data=models.Document} "The document"
+// @Failure 400 {object} utils.Response{error=string} "Invalid document ID"
+// @Failure 401 {object} utils.Response{error=string} "Invalid auditor token"
+// @Failure 404 {object} utils.Response{error=string} "Document not found"
+// @Failure 500 {object} utils.Response{error=string} "Server error"
+// @Router /auditor/documents/{id} [get]
+func (h *AuditorGrantHandler) GetDocument(w http.ResponseWriter, r *http.Request) {
+	grant, ok := h.authenticate(w, r)
+	if !ok {
+		return
+	}
+
+	id, err := strconv.ParseInt(chi.URLParam(r, constants.ParamID), 10, 64)
+	if err != nil {
+		utils.BadRequest(w, "Invalid document ID", nil)
+		return
+	}
+
+	doc, err := h.grantService.GetDocument(r.Context(), grant, id, utils.GetClientIP(r))
+	if err != nil {
+		if errors.Is(err, service.ErrDocumentNotFound) {
+			utils.NotFound(w, "Document not found")
+			return
+		}
+		utils.ErrorFromAppError(w, utils.ParseError(err))
+		return
+	}
+
+	utils.JSON(w, constants.StatusOK, doc)
+}
+
+// ExportDocument exports a document covered by the auditor's grant as JSON or as a ZIP
+// archive, watermarked with the auditor and the grant.
+//
+// HTTP Method:
+//   - GET
+//
+// URL Path:
+//   - /api/auditor/documents/{id}/export
+//
+// Query Parameters:
+//   - format: "json" (default) or "zip"
+//
+// Requires:
+//   - X-Auditor-Token header with an active grant token
+//
+// Responses:
+//   - 200 OK: The watermarked export as a file download
+//   - 400 Bad Request: Invalid document ID or format
+//   - 401 Unauthorized: Missing, unknown, expired or revoked token
+//   - 404 Not Found: Document not found or not covered by the grant
+//   - 500 Internal Server Error: Server-side error
+//
+// @Summary Export an audited document
+// @Description Exports a document covered by the auditor's access grant, watermarked with the auditor and the grant; every export is logged
+// @Tags Auditor
+// @Produce json,application/zip
+// @Param X-Auditor-Token header string true "Auditor token"
+// @Param id path int true "Document ID"
+// @Param format query string false "Export format: json or zip"
+// @Success 200 {object} models.DocumentExport "The watermarked export"
+// @Failure 400 {object} utils.Response{error=string} "Invalid request"
+// @Failure 401 {object} utils.Response{error=string} "Invalid auditor token"
+// @Failure 404 {object} utils.Response{error=string} "Document not found"
+// @Failure 500 {object} utils.Response{error=string} "Server error"
+// @Router /auditor/documents/{id}/export [get]
+func (h *AuditorGrantHandler) ExportDocument(w http.ResponseWriter, r *http.Request) {
+	grant, ok := h.authenticate(w, r)
+	if !ok {
+		return
+	}
+
+	id, err := strconv.ParseInt(chi.URLParam(r, constants.ParamID), 10, 64)
+	if err != nil {
+		utils.BadRequest(w, "Invalid document ID", nil)
+		return
+	}
+	format := strings.ToLower(r.URL.Query().Get(constants.QueryParamFormat))
+	if format == "" {
+		format = constants.DocumentExportFormatJSON
+	}
+	if format != constants.DocumentExportFormatJSON && format != constants.DocumentExportFormatZIP {
+		utils.BadRequest(w, constants.MsgInvalidExportFormat, nil)
+		return
+	}
+
+	export, err := h.grantService.ExportDocument(r.Context(), grant, id, utils.GetClientIP(r))
+	if err != nil {
+		if errors.Is(err, service.ErrDocumentNotFound) {
+			utils.NotFound(w, "Document not found")
+			return
+		}
+		log.Error().Err(err).Int64("grant_id", grant.ID).Int64("document_id", id).Msg("Failed to export audited document")
+		utils.ErrorFromAppError(w, utils.ParseError(err))
+		return
+	}
+
+	filename := fmt.Sprintf("%s%d.%s", constants.DocumentExportFilenamePrefix, id, format)
+	if format == constants.DocumentExportFormatJSON {
+		utils.JsonFile(w, export, filename)
+		return
+	}
+
+	// Build the archive before sending headers so failures can still be reported
+	var buf bytes.Buffer
+	if err := export.WriteZIP(&buf); err != nil {
+		utils.ErrorFromAppError(w, utils.ParseError(err))
+		return
+	}
+	w.Header().Set(constants.HeaderContentType, constants.ContentTypeZIP)
+	w.Header().Set(constants.HeaderContentDisposition, fmt.Sprintf("attachment; filename=%s", filename))
+	w.Header().Set(constants.HeaderCacheControl, constants.CacheControlNoStore)
+	w.WriteHeader(constants.StatusOK)
+	if _, err := w.Write(buf.Bytes()); err != nil {
+		log.Error().Err(err).Msg("Failed to write audited document export")
+	}
+}
+
+// authenticate resolves the grant of the auditor token sent with a request, writing the error
+// response when there is none.
+func (h *AuditorGrantHandler) authenticate(w http.ResponseWriter, r *http.Request) (*models.AuditorGrant, bool) {
+	token := r.Header.Get(constants.HeaderXAuditorToken)
+	if token == "" {
+		utils.Unauthorized(w, constants.MsgAuditorTokenRequired)
+		return nil, false
+	}
+
+	grant, err := h.grantService.Authenticate(r.Context(), token)
+	if err != nil {
+		utils.ErrorFromAppError(w, utils.ParseError(err))
+		return nil, false
+	}
+
+	return grant, true
+}
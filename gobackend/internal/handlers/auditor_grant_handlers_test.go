@@ -0,0 +1,274 @@
+package handlers_test
+
+import (
+	"bytes"
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+
+	"github.com/yasinhessnawi1/Hideme_Backend/internal/constants"
+	"github.com/yasinhessnawi1/Hideme_Backend/internal/handlers"
+	"github.com/yasinhessnawi1/Hideme_Backend/internal/models"
+	"github.com/yasinhessnawi1/Hideme_Backend/internal/service"
+	"github.com/yasinhessnawi1/Hideme_Backend/internal/testsupport/golden"
+)
+
+// MockAuditorGrantService is a mock implementation of AuditorGrantServiceInterface
+type MockAuditorGrantService struct {
+	mock.Mock
+}
+
+func (m *MockAuditorGrantService) CreateGrant(ctx context.Context, adminID int64, req *models.AuditorGrantRequest) (*models.AuditorGrantCreated, error) {
+	args := m.Called(ctx, adminID, req)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*models.AuditorGrantCreated), args.Error(1)
+}
+
+func (m *MockAuditorGrantService) ListGrants(ctx context.Context) ([]*models.AuditorGrant, error) {
+	args := m.Called(ctx)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]*models.AuditorGrant), args.Error(1)
+}
+
+func (m *MockAuditorGrantService) RevokeGrant(ctx context.Context, adminID, grantID int64) error {
+	args := m.Called(ctx, adminID, grantID)
+	return args.Error(0)
+}
+
+func (m *MockAuditorGrantService) ListAccess(ctx context.Context, grantID int64) ([]*models.AuditorAccess, error) {
+	args := m.Called(ctx, grantID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]*models.AuditorAccess), args.Error(1)
+}
+
+func (m *MockAuditorGrantService) Authenticate(ctx context.Context, token string) (*models.AuditorGrant, error) {
+	args := m.Called(ctx, token)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*models.AuditorGrant), args.Error(1)
+}
+
+func (m *MockAuditorGrantService) ListDocuments(ctx context.Context, grant *models.AuditorGrant, ipAddress string) ([]*models.Document, error) {
+	args := m.Called(ctx, grant, ipAddress)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]*models.Document), args.Error(1)
+}
+
+func (m *MockAuditorGrantService) GetDocument(ctx context.Context, grant *models.AuditorGrant, documentID int64, ipAddress string) (*models.Document, error) {
+	args := m.Called(ctx, grant, documentID, ipAddress)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*models.Document), args.Error(1)
+}
+
+func (m *MockAuditorGrantService) ExportDocument(ctx context.Context, grant *models.AuditorGrant, documentID int64, ipAddress string) (*models.DocumentExport, error) {
+	args := m.Called(ctx, grant, documentID, ipAddress)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*models.DocumentExport), args.Error(1)
+}
+
+func setupAuditorGrantTest() (*chi.Mux, *MockAuditorGrantService) {
+	mockService := new(MockAuditorGrantService)
+	handler := handlers.NewAuditorGrantHandler(mockService)
+
+	router := chi.NewRouter()
+	router.Post("/api/admin/auditor-grants", handler.CreateGrant)
+	router.Get("/api/admin/auditor-grants", handler.ListGrants)
+	router.Delete("/api/admin/auditor-grants/{id}", handler.RevokeGrant)
+	router.Get("/api/admin/auditor-grants/{id}/access", handler.ListAccess)
+	router.Get("/api/auditor/documents", handler.ListDocuments)
+	router.Get("/api/auditor/documents/{id}", handler.GetDocument)
+	router.Get("/api/auditor/documents/{id}/export", handler.ExportDocument)
+
+	return router, mockService
+}
+
+func TestAuditorGrantHandler_CreateGrant(t *testing.T) {
+	router, mockService := setupAuditorGrantTest()
+
+	t.Run("Success", func(t *testing.T) {
+		mockService.On("CreateGrant", mock.Anything, int64(1), mock.Anything).Return(&models.AuditorGrantCreated{
+			AuditorGrant: &models.AuditorGrant{ID: 3, AuditorName: "Kari Nordmann", TokenHash: "hash", UserIDs: []int64{4}},
+			Token:        "secret-token",
+		}, nil).Once()
+
+		body := `{"auditor_name":"Kari Nordmann","auditor_email":"kari@audit.example","user_ids":[4],"expires_in_days":30,"reason":"Annual audit"}`
+		req, err := http.NewRequest("POST", "/api/admin/auditor-grants", bytes.NewBufferString(body))
+		require.NoError(t, err)
+		req.Header.Set("Content-Type", "application/json")
+		rr := httptest.NewRecorder()
+		router.ServeHTTP(rr, req.WithContext(createAuthContext(1)))
+
+		golden.AssertResponse(t, rr)
+	})
+
+	t.Run("Invalid body", func(t *testing.T) {
+		for _, body := range []string{
+			`{"auditor_name":"Kari Nordmann","auditor_email":"kari@audit.example","user_ids":[4],"expires_in_days":365,"reason":"Annual audit"}`,
+			`{"auditor_name":"Kari Nordmann","auditor_email":"not-an-email","user_ids":[4],"expires_in_days":30,"reason":"Annual audit"}`,
+			`{"auditor_name":`,
+		} {
+			req, err := http.NewRequest("POST", "/api/admin/auditor-grants", bytes.NewBufferString(body))
+			require.NoError(t, err)
+			req.Header.Set("Content-Type", "application/json")
+			rr := httptest.NewRecorder()
+			router.ServeHTTP(rr, req.WithContext(createAuthContext(1)))
+
+			assert.Equal(t, http.StatusBadRequest, rr.Code, body)
+		}
+	})
+
+	t.Run("Empty grant", func(t *testing.T) {
+		mockService.On("CreateGrant", mock.Anything, int64(1), mock.Anything).Return(nil, service.ErrAuditorGrantEmpty).Once()
+
+		body := `{"auditor_name":"Kari Nordmann","auditor_email":"kari@audit.example","expires_in_days":30,"reason":"Annual audit"}`
+		req, err := http.NewRequest("POST", "/api/admin/auditor-grants", bytes.NewBufferString(body))
+		require.NoError(t, err)
+		req.Header.Set("Content-Type", "application/json")
+		rr := httptest.NewRecorder()
+		router.ServeHTTP(rr, req.WithContext(createAuthContext(1)))
+
+		assert.Equal(t, http.StatusBadRequest, rr.Code)
+	})
+
+	mockService.AssertExpectations(t)
+}
+
+func TestAuditorGrantHandler_RevokeGrant(t *testing.T) {
+	router, mockService := setupAuditorGrantTest()
+
+	t.Run("Success", func(t *testing.T) {
+		mockService.On("RevokeGrant", mock.Anything, int64(1), int64(3)).Return(nil).Once()
+
+		req, err := http.NewRequest("DELETE", "/api/admin/auditor-grants/3", nil)
+		require.NoError(t, err)
+		rr := httptest.NewRecorder()
+		router.ServeHTTP(rr, req.WithContext(createAuthContext(1)))
+
+		assert.Equal(t, http.StatusNoContent, rr.Code)
+	})
+
+	t.Run("Not found", func(t *testing.T) {
+		mockService.On("RevokeGrant", mock.Anything, int64(1), int64(404)).Return(service.ErrAuditorGrantNotFound).Once()
+
+		req, err := http.NewRequest("DELETE", "/api/admin/auditor-grants/404", nil)
+		require.NoError(t, err)
+		rr := httptest.NewRecorder()
+		router.ServeHTTP(rr, req.WithContext(createAuthContext(1)))
+
+		assert.Equal(t, http.StatusNotFound, rr.Code)
+	})
+
+	t.Run("Invalid ID", func(t *testing.T) {
+		req, err := http.NewRequest("DELETE", "/api/admin/auditor-grants/abc", nil)
+		require.NoError(t, err)
+		rr := httptest.NewRecorder()
+		router.ServeHTTP(rr, req.WithContext(createAuthContext(1)))
+
+		assert.Equal(t, http.StatusBadRequest, rr.Code)
+	})
+
+	mockService.AssertExpectations(t)
+}
+
+func TestAuditorGrantHandler_AuditorRequests(t *testing.T) {
+	router, mockService := setupAuditorGrantTest()
+	grant := &models.AuditorGrant{ID: 3, AuditorName: "Kari Nordmann", AuditorEmail: "kari@audit.example", ExpiresAt: time.Now().Add(time.Hour)}
+
+	t.Run("Missing token", func(t *testing.T) {
+		req, err := http.NewRequest("GET", "/api/auditor/documents", nil)
+		require.NoError(t, err)
+		rr := httptest.NewRecorder()
+		router.ServeHTTP(rr, req)
+
+		golden.AssertResponse(t, rr)
+	})
+
+	t.Run("Invalid token", func(t *testing.T) {
+		mockService.On("Authenticate", mock.Anything, "expired").Return(nil, service.ErrAuditorGrantInvalid).Once()
+
+		req, err := http.NewRequest("GET", "/api/auditor/documents", nil)
+		require.NoError(t, err)
+		req.Header.Set(constants.HeaderXAuditorToken, "expired")
+		rr := httptest.NewRecorder()
+		router.ServeHTTP(rr, req)
+
+		assert.Equal(t, http.StatusUnauthorized, rr.Code)
+	})
+
+	t.Run("List documents", func(t *testing.T) {
+		mockService.On("Authenticate", mock.Anything, "valid").Return(grant, nil).Once()
+		mockService.On("ListDocuments", mock.Anything, grant, mock.Anything).Return([]*models.Document{{ID: 12, UserID: 4}}, nil).Once()
+
+		req, err := http.NewRequest("GET", "/api/auditor/documents", nil)
+		require.NoError(t, err)
+		req.Header.Set(constants.HeaderXAuditorToken, "valid")
+		rr := httptest.NewRecorder()
+		router.ServeHTTP(rr, req)
+
+		golden.AssertResponse(t, rr)
+	})
+
+	t.Run("Uncovered document", func(t *testing.T) {
+		mockService.On("Authenticate", mock.Anything, "valid").Return(grant, nil).Once()
+		mockService.On("GetDocument", mock.Anything, grant, int64(99), mock.Anything).Return(nil, service.ErrDocumentNotFound).Once()
+
+		req, err := http.NewRequest("GET", "/api/auditor/documents/99", nil)
+		require.NoError(t, err)
+		req.Header.Set(constants.HeaderXAuditorToken, "valid")
+		rr := httptest.NewRecorder()
+		router.ServeHTTP(rr, req)
+
+		assert.Equal(t, http.StatusNotFound, rr.Code)
+	})
+
+	t.Run("Watermarked ZIP export", func(t *testing.T) {
+		export := models.NewDocumentExport(&models.Document{ID: 12, UserID: 4}, models.RedactionMapping{}, nil, nil, time.Now())
+		export.Watermark = grant.Watermark(export.ExportedAt)
+		mockService.On("Authenticate", mock.Anything, "valid").Return(grant, nil).Once()
+		mockService.On("ExportDocument", mock.Anything, grant, int64(12), mock.Anything).Return(export, nil).Once()
+
+		req, err := http.NewRequest("GET", "/api/auditor/documents/12/export?format=zip", nil)
+		require.NoError(t, err)
+		req.Header.Set(constants.HeaderXAuditorToken, "valid")
+		rr := httptest.NewRecorder()
+		router.ServeHTTP(rr, req)
+
+		assert.Equal(t, http.StatusOK, rr.Code)
+		assert.Equal(t, constants.ContentTypeZIP, rr.Header().Get(constants.HeaderContentType))
+		assert.Contains(t, rr.Body.String(), "Kari Nordmann")
+	})
+
+	t.Run("Invalid format", func(t *testing.T) {
+		mockService.On("Authenticate", mock.Anything, "valid").Return(grant, nil).Once()
+
+		req, err := http.NewRequest("GET", "/api/auditor/documents/12/export?format=pdf", nil)
+		require.NoError(t, err)
+		req.Header.Set(constants.HeaderXAuditorToken, "valid")
+		rr := httptest.NewRecorder()
+		router.ServeHTTP(rr, req)
+
+		assert.Equal(t, http.StatusBadRequest, rr.Code)
+	})
+
+	mockService.AssertExpectations(t)
+}
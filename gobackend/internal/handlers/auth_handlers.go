@@ -85,6 +85,7 @@ func NewAuthHandler(authService AuthServiceInterface, jwtService JWTServiceInter
 // Responses:
 //   - 201 Created: User created successfully
 //   - 400 Bad Request: Invalid request body or validation errors
+//   - 403 Forbidden: A CAPTCHA is required (error details name the "challenge")
 //   - 409 Conflict: Username or email already exists
 //   - 500 Internal Server Error: Server-side error
 //
@@ -96,6 +97,7 @@ func NewAuthHandler(authService AuthServiceInterface, jwtService JWTServiceInter
 // @Param registration body models.UserRegistration true "User registration data"
 // @Success 201 {object} utils.Response{data=models.User} "User created successfully"
 // @Failure 400 {object} utils.Response{error=string} "Invalid request data"
+// @Failure 403 {object} utils.Response{error=string} "Risk challenge required"
 // @Failure 409 {object} utils.Response{error=string} "Username or email already in use"
 // @Failure 500 {object} utils.Response{error=string} "Server error"
 // @Router /auth/signup [post]
@@ -107,6 +109,10 @@ func (h *AuthHandler) Register(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	// Attach the client details used for risk scoring
+	reg.ClientIP = utils.GetClientIP(r)
+	reg.UserAgent = r.UserAgent()
+
 	// Register the user
 	user, err := h.authService.RegisterUser(r.Context(), &reg)
 	if err != nil {
@@ -134,6 +140,7 @@ func (h *AuthHandler) Register(w http.ResponseWriter, r *http.Request) {
 //   - 200 OK: Authentication successful with tokens and user info
 //   - 400 Bad Request: Invalid request body
 //   - 401 Unauthorized: Invalid credentials
+//   - 403 Forbidden: Account held or CAPTCHA required (error details name the "challenge")
 //   - 500 Internal Server Error: Server-side error
 //
 // Security:
@@ -149,6 +156,7 @@ func (h *AuthHandler) Register(w http.ResponseWriter, r *http.Request) {
 // @Success 200 {object} utils.Response{data=map[string]interface{}} "Authentication successful with tokens"
 // @Failure 400 {object} utils.Response{error=string} "Invalid request data"
 // @Failure 401 {object} utils.Response{error=string} "Invalid credentials"
+// @Failure 403 {object} utils.Response{error=string} "Risk challenge required"
 // @Failure 500 {object} utils.Response{error=string} "Server error"
 // @Router /auth/login [post]
 func (h *AuthHandler) Login(w http.ResponseWriter, r *http.Request) {
@@ -170,6 +178,10 @@ func (h *AuthHandler) Login(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	// Attach the client details used for risk scoring
+	creds.ClientIP = utils.GetClientIP(r)
+	creds.UserAgent = r.UserAgent()
+
 	// Authenticate the user
 	user, accessToken, refreshToken, err := h.authService.AuthenticateUser(r.Context(), &creds)
 	if err != nil {
@@ -438,7 +450,8 @@ func (h *AuthHandler) VerifyToken(w http.ResponseWriter, r *http.Request) {
 //   - Authentication: User must be logged in
 //
 // Request Body:
-//   - JSON object with "name" and "duration" fields
+//   - JSON object with "name" and "duration" fields, and optionally the "scopes" the key is
+//     limited to; keys without scopes have full access
 //
 // Responses:
 //   - 201 Created: API key created successfully
@@ -485,7 +498,7 @@ func (h *AuthHandler) CreateAPIKey(w http.ResponseWriter, r *http.Request) {
 	}
 
 	// Create the API key
-	rawKey, apiKey, err := h.authService.CreateAPIKey(r.Context(), userID, req.Name, duration)
+	rawKey, apiKey, err := h.authService.CreateAPIKey(r.Context(), userID, req.Name, duration, req.Scopes)
 	if err != nil {
 		utils.ErrorFromAppError(w, utils.ParseError(err))
 		return
@@ -498,6 +511,7 @@ func (h *AuthHandler) CreateAPIKey(w http.ResponseWriter, r *http.Request) {
 		Key:       rawKey,
 		ExpiresAt: apiKey.ExpiresAt,
 		CreatedAt: apiKey.CreatedAt,
+		Scopes:    apiKey.Scopes,
 	})
 }
 
@@ -542,7 +556,7 @@ func (h *AuthHandler) ListAPIKeys(w http.ResponseWriter, r *http.Request) {
 	}
 
 	// Return the API keys
-	utils.JSON(w, constants.StatusOK, apiKeys)
+	utils.ListAll(w, r, apiKeys)
 }
 
 // DeleteAPIKey handles revoking an API key.
@@ -603,6 +617,79 @@ func (h *AuthHandler) DeleteAPIKey(w http.ResponseWriter, r *http.Request) {
 	})
 }
 
+// RotateAPIKey handles issuing a replacement for an API key.
+// The old key keeps working until the end of the rotation overlap window, so
+// integrations can switch to the replacement without downtime.
+//
+// HTTP Method:
+//   - POST
+//
+// URL Path:
+//   - /keys/{keyID}/rotate
+//
+// Requires:
+//   - Authentication: User must be logged in
+//   - URL Parameter: keyID - The ID of the API key to rotate
+//
+// Responses:
+//   - 201 Created: Replacement API key created successfully
+//   - 400 Bad Request: Missing key ID or key already rotated
+//   - 401 Unauthorized: User not authenticated or key expired
+//   - 403 Forbidden: API key belongs to another user
+//   - 404 Not Found: API key not found
+//   - 500 Internal Server Error: Server-side error
+//
+// Security:
+//   - The raw replacement key is only returned once
+//
+// @Summary Rotate API key
+// @Description Issues a replacement API key while the old key stays valid for the overlap window
+// @Tags API Keys
+// @Produce json
+// @Security BearerAuth
+// @Param keyID path string true "API key ID"
+// @Success 201 {object} utils.Response{data=models.APIKeyResponse} "Replacement API key created successfully"
+// @Failure 400 {object} utils.Response{error=string} "Invalid key ID or key already rotated"
+// @Failure 401 {object} utils.Response{error=string} "User not authenticated"
+// @Failure 403 {object} utils.Response{error=string} "API key belongs to another user"
+// @Failure 404 {object} utils.Response{error=string} "API key not found"
+// @Failure 500 {object} utils.Response{error=string} "Server error"
+// @Router /keys/{keyID}/rotate [post]
+func (h *AuthHandler) RotateAPIKey(w http.ResponseWriter, r *http.Request) {
+	// Get the user ID from the context
+	userID, ok := auth.GetUserID(r)
+	if !ok {
+		utils.Unauthorized(w, constants.MsgAuthRequired)
+		return
+	}
+
+	// Get the key ID from the URL
+	keyID := chi.URLParam(r, constants.ParamKeyID)
+	if keyID == "" {
+		utils.BadRequest(w, "key_id parameter is required", nil)
+		return
+	}
+
+	// Rotate the API key
+	rawKey, apiKey, previous, err := h.authService.RotateAPIKey(r.Context(), userID, keyID)
+	if err != nil {
+		utils.ErrorFromAppError(w, utils.ParseError(err))
+		return
+	}
+
+	// Return the replacement key
+	utils.JSON(w, constants.StatusCreated, models.APIKeyResponse{
+		ID:                   apiKey.ID,
+		Name:                 apiKey.Name,
+		Key:                  rawKey,
+		ExpiresAt:            apiKey.ExpiresAt,
+		CreatedAt:            apiKey.CreatedAt,
+		Scopes:               apiKey.Scopes,
+		RotatedFrom:          apiKey.RotatedFrom,
+		PreviousKeyExpiresAt: &previous.ExpiresAt,
+	})
+}
+
 // GetAPIKeyDecoded retrieves and decodes a specific API key.
 // This endpoint allows users to retrieve their original API key values.
 //
@@ -79,12 +79,13 @@ type AuthServiceInterface interface {
 	//   - userID: The ID of the user who will own the API key
 	//   - name: A human-readable name for the API key
 	//   - duration: How long the API key should remain valid
+	//   - scopes: The scopes the API key is limited to; empty for full access
 	//
 	// Returns:
 	//   - The raw API key string that should be shown to the user (only once)
 	//   - The API key model containing metadata (ID, expiry date, etc.)
 	//   - An error if the operation fails
-	CreateAPIKey(ctx context.Context, userID int64, name string, duration time.Duration) (string, *models.APIKey, error)
+	CreateAPIKey(ctx context.Context, userID int64, name string, duration time.Duration, scopes []string) (string, *models.APIKey, error)
 
 	// ListAPIKeys returns all API keys for the specified user.
 	//
@@ -108,6 +109,21 @@ type AuthServiceInterface interface {
 	//   - An error if the operation fails (e.g., key not found or not owned by user)
 	DeleteAPIKey(ctx context.Context, userID int64, keyID string) error
 
+	// RotateAPIKey issues a replacement for an API key owned by the specified user.
+	// The old key keeps working until the end of the rotation overlap window.
+	//
+	// Parameters:
+	//   - ctx: Context for the operation
+	//   - userID: The ID of the user who owns the API key
+	//   - keyID: The ID of the API key to rotate
+	//
+	// Returns:
+	//   - The raw replacement key (to be shown to the user once)
+	//   - The replacement key model
+	//   - The old key model with its shortened expiry
+	//   - An error if the key is not found, not owned by the user, expired or already rotated
+	RotateAPIKey(ctx context.Context, userID int64, keyID string) (string, *models.APIKey, *models.APIKey, error)
+
 	// VerifyAPIKey validates an API key and returns the associated user.
 	//
 	// Parameters:
@@ -123,21 +139,25 @@ type AuthServiceInterface interface {
 	//
 	// Parameters:
 	//   - ctx: Context for the operation
+	//   - batchSize: The maximum number of sessions to remove; 0 for no limit
+	//   - dryRun: Whether to count the sessions instead of removing them
 	//
 	// Returns:
 	//   - The number of sessions removed
 	//   - An error if the cleanup operation fails
-	CleanupExpiredSessions(ctx context.Context) (int64, error)
+	CleanupExpiredSessions(ctx context.Context, batchSize int, dryRun bool) (int64, error)
 
 	// CleanupExpiredAPIKeys removes expired API key records.
 	//
 	// Parameters:
 	//   - ctx: Context for the operation
+	//   - batchSize: The maximum number of API keys to remove; 0 for no limit
+	//   - dryRun: Whether to count the API keys instead of removing them
 	//
 	// Returns:
 	//   - The number of API keys removed
 	//   - An error if the cleanup operation fails
-	CleanupExpiredAPIKeys(ctx context.Context) (int64, error)
+	CleanupExpiredAPIKeys(ctx context.Context, batchSize int, dryRun bool) (int64, error)
 }
 
 // JWTServiceInterface defines the methods required from the JWT service.
@@ -0,0 +1,262 @@
+// Package handlers provides HTTP request handlers for the HideMe API.
+package handlers
+
+import (
+	"context"
+	"net/http"
+
+	"github.com/yasinhessnawi1/Hideme_Backend/internal/auth"
+	"github.com/yasinhessnawi1/Hideme_Backend/internal/constants"
+	"github.com/yasinhessnawi1/Hideme_Backend/internal/models"
+	"github.com/yasinhessnawi1/Hideme_Backend/internal/utils"
+)
+
+// BenchmarkServiceInterface defines the service methods required for the cross-tenant benchmarks.
+type BenchmarkServiceInterface interface {
+	// GetComparison compares a tenant's own entities per page with the published benchmarks.
+	//
+	// Parameters:
+	//   - ctx: Context for the operation
+	//   - userID: The ID of the tenant
+	//
+	// Returns:
+	//   - The comparison
+	//   - ForbiddenError if the tenant has not opted in
+	GetComparison(ctx context.Context, userID int64) (*models.BenchmarkComparison, error)
+
+	// GetConsent retrieves a tenant's benchmark consent.
+	//
+	// Parameters:
+	//   - ctx: Context for the operation
+	//   - userID: The ID of the tenant
+	//
+	// Returns:
+	//   - The consent
+	//   - NotFoundError if the tenant has not opted in
+	GetConsent(ctx context.Context, userID int64) (*models.BenchmarkConsent, error)
+
+	// SetConsent opts a tenant in to benchmarking or changes its industry.
+	//
+	// Parameters:
+	//   - ctx: Context for the operation
+	//   - userID: The ID of the tenant
+	//   - industry: The sector the tenant is benchmarked against
+	//
+	// Returns:
+	//   - The stored consent
+	//   - An error if the consent cannot be stored
+	SetConsent(ctx context.Context, userID int64, industry models.Industry) (*models.BenchmarkConsent, error)
+
+	// RevokeConsent opts a tenant out of benchmarking.
+	//
+	// Parameters:
+	//   - ctx: Context for the operation
+	//   - userID: The ID of the tenant
+	//
+	// Returns:
+	//   - NotFoundError if the tenant has not opted in
+	RevokeConsent(ctx context.Context, userID int64) error
+}
+
+// BenchmarkHandler handles HTTP requests related to the cross-tenant benchmarks.
+type BenchmarkHandler struct {
+	benchmarkService BenchmarkServiceInterface
+}
+
+// NewBenchmarkHandler creates a new BenchmarkHandler with the provided benchmark service.
+//
+// Parameters:
+//   - benchmarkService: Service publishing the anonymized benchmarks
+//
+// Returns:
+//   - A properly initialized BenchmarkHandler
+func NewBenchmarkHandler(benchmarkService BenchmarkServiceInterface) *BenchmarkHandler {
+	return &BenchmarkHandler{
+		benchmarkService: benchmarkService,
+	}
+}
+
+// GetBenchmarks compares the current tenant with the anonymized averages of its industry.
+// Only tenants that share their own statistics can see the benchmarks.
+//
+// HTTP Method:
+//   - GET
+//
+// URL Path:
+//   - /api/benchmarks
+//
+// Requires:
+//   - Authentication: User must be logged in
+//   - Benchmark consent
+//
+// Responses:
+//   - 200 OK: Own entities per page and the published industry benchmarks
+//   - 401 Unauthorized: User not authenticated
+//   - 403 Forbidden: Tenant has not opted in to benchmarking
+//   - 500 Internal Server Error: Server-side error
+//
+// @Summary Get benchmarks
+// @Description Compares the tenant's entities per page with differentially private averages of consenting tenants by industry
+// @Tags Benchmarks
+// @Produce json
+// @Security BearerAuth
+// @Success 200 {object} utils.Response{data=models.BenchmarkComparison} "Benchmark comparison"
+// @Failure 401 {object} utils.Response{error=string} "User not authenticated"
+// @Failure 403 {object} utils.Response{error=string} "Tenant has not opted in to benchmarking"
+// @Failure 500 {object} utils.Response{error=string} "Server error"
+// @Router /benchmarks [get]
+func (h *BenchmarkHandler) GetBenchmarks(w http.ResponseWriter, r *http.Request) {
+	userID, ok := auth.GetUserID(r)
+	if !ok {
+		utils.Unauthorized(w, constants.MsgAuthRequired)
+		return
+	}
+
+	comparison, err := h.benchmarkService.GetComparison(r.Context(), userID)
+	if err != nil {
+		utils.ErrorFromAppError(w, utils.ParseError(err))
+		return
+	}
+
+	utils.JSON(w, constants.StatusOK, comparison)
+}
+
+// GetConsent returns the benchmark consent of the current tenant.
+//
+// HTTP Method:
+//   - GET
+//
+// URL Path:
+//   - /api/benchmarks/consent
+//
+// Requires:
+//   - Authentication: User must be logged in
+//
+// Responses:
+//   - 200 OK: Current consent
+//   - 401 Unauthorized: User not authenticated
+//   - 404 Not Found: Tenant has not opted in to benchmarking
+//   - 500 Internal Server Error: Server-side error
+//
+// @Summary Get benchmark consent
+// @Description Returns whether and with which industry the tenant shares its statistics for benchmarks
+// @Tags Benchmarks
+// @Produce json
+// @Security BearerAuth
+// @Success 200 {object} utils.Response{data=models.BenchmarkConsent} "Current consent"
+// @Failure 401 {object} utils.Response{error=string} "User not authenticated"
+// @Failure 404 {object} utils.Response{error=string} "Tenant has not opted in"
+// @Failure 500 {object} utils.Response{error=string} "Server error"
+// @Router /benchmarks/consent [get]
+func (h *BenchmarkHandler) GetConsent(w http.ResponseWriter, r *http.Request) {
+	userID, ok := auth.GetUserID(r)
+	if !ok {
+		utils.Unauthorized(w, constants.MsgAuthRequired)
+		return
+	}
+
+	consent, err := h.benchmarkService.GetConsent(r.Context(), userID)
+	if err != nil {
+		utils.ErrorFromAppError(w, utils.ParseError(err))
+		return
+	}
+
+	utils.JSON(w, constants.StatusOK, consent)
+}
+
+// SetConsent opts the current tenant in to benchmarking or changes its industry.
+// The tenant is included in the aggregates from the next benchmark run on.
+//
+// HTTP Method:
+//   - PUT
+//
+// URL Path:
+//   - /api/benchmarks/consent
+//
+// Requires:
+//   - Authentication: User must be logged in
+//
+// Request Body:
+//   - JSON object conforming to models.BenchmarkConsentRequest
+//
+// Responses:
+//   - 200 OK: Consent stored
+//   - 400 Bad Request: Invalid request body or unknown industry
+//   - 401 Unauthorized: User not authenticated
+//   - 500 Internal Server Error: Server-side error
+//
+// @Summary Opt in to benchmarks
+// @Description Shares the tenant's statistics for the anonymized cross-tenant benchmarks of an industry
+// @Tags Benchmarks
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param consent body models.BenchmarkConsentRequest true "Industry to be benchmarked against"
+// @Success 200 {object} utils.Response{data=models.BenchmarkConsent} "Consent stored"
+// @Failure 400 {object} utils.Response{error=string} "Invalid request body or unknown industry"
+// @Failure 401 {object} utils.Response{error=string} "User not authenticated"
+// @Failure 500 {object} utils.Response{error=string} "Server error"
+// @Router /benchmarks/consent [put]
+func (h *BenchmarkHandler) SetConsent(w http.ResponseWriter, r *http.Request) {
+	userID, ok := auth.GetUserID(r)
+	if !ok {
+		utils.Unauthorized(w, constants.MsgAuthRequired)
+		return
+	}
+
+	var req models.BenchmarkConsentRequest
+	if err := utils.DecodeAndValidate(r, &req); err != nil {
+		utils.ErrorFromAppError(w, utils.ParseError(err))
+		return
+	}
+
+	consent, err := h.benchmarkService.SetConsent(r.Context(), userID, req.Industry)
+	if err != nil {
+		utils.ErrorFromAppError(w, utils.ParseError(err))
+		return
+	}
+
+	utils.JSON(w, constants.StatusOK, consent)
+}
+
+// RevokeConsent opts the current tenant out of benchmarking.
+// The tenant is excluded from the aggregates from the next benchmark run on.
+//
+// HTTP Method:
+//   - DELETE
+//
+// URL Path:
+//   - /api/benchmarks/consent
+//
+// Requires:
+//   - Authentication: User must be logged in
+//
+// Responses:
+//   - 204 No Content: Consent revoked
+//   - 401 Unauthorized: User not authenticated
+//   - 404 Not Found: Tenant has not opted in to benchmarking
+//   - 500 Internal Server Error: Server-side error
+//
+// @Summary Opt out of benchmarks
+// @Description Stops sharing the tenant's statistics for the cross-tenant benchmarks
+// @Tags Benchmarks
+// @Security BearerAuth
+// @Success 204 "Consent revoked"
+// @Failure 401 {object} utils.Response{error=string} "User not authenticated"
+// @Failure 404 {object} utils.Response{error=string} "Tenant has not opted in"
+// @Failure 500 {object} utils.Response{error=string} "Server error"
+// @Router /benchmarks/consent [delete]
+func (h *BenchmarkHandler) RevokeConsent(w http.ResponseWriter, r *http.Request) {
+	userID, ok := auth.GetUserID(r)
+	if !ok {
+		utils.Unauthorized(w, constants.MsgAuthRequired)
+		return
+	}
+
+	if err := h.benchmarkService.RevokeConsent(r.Context(), userID); err != nil {
+		utils.ErrorFromAppError(w, utils.ParseError(err))
+		return
+	}
+
+	utils.NoContent(w)
+}
@@ -0,0 +1,188 @@
+package handlers_test
+
+import (
+	"bytes"
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+
+	"github.com/yasinhessnawi1/Hideme_Backend/internal/constants"
+	"github.com/yasinhessnawi1/Hideme_Backend/internal/handlers"
+	"github.com/yasinhessnawi1/Hideme_Backend/internal/models"
+	"github.com/yasinhessnawi1/Hideme_Backend/internal/testsupport/fixtures"
+	"github.com/yasinhessnawi1/Hideme_Backend/internal/testsupport/golden"
+	"github.com/yasinhessnawi1/Hideme_Backend/internal/utils"
+)
+
+// MockBenchmarkService is a mock implementation of the BenchmarkService
+type MockBenchmarkService struct {
+	mock.Mock
+}
+
+func (m *MockBenchmarkService) GetComparison(ctx context.Context, userID int64) (*models.BenchmarkComparison, error) {
+	args := m.Called(ctx, userID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*models.BenchmarkComparison), args.Error(1)
+}
+
+func (m *MockBenchmarkService) GetConsent(ctx context.Context, userID int64) (*models.BenchmarkConsent, error) {
+	args := m.Called(ctx, userID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*models.BenchmarkConsent), args.Error(1)
+}
+
+func (m *MockBenchmarkService) SetConsent(ctx context.Context, userID int64, industry models.Industry) (*models.BenchmarkConsent, error) {
+	args := m.Called(ctx, userID, industry)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*models.BenchmarkConsent), args.Error(1)
+}
+
+func (m *MockBenchmarkService) RevokeConsent(ctx context.Context, userID int64) error {
+	args := m.Called(ctx, userID)
+	return args.Error(0)
+}
+
+func setupBenchmarkTest() (*chi.Mux, *MockBenchmarkService) {
+	mockService := new(MockBenchmarkService)
+	handler := handlers.NewBenchmarkHandler(mockService)
+
+	router := chi.NewRouter()
+	router.Get("/api/benchmarks", handler.GetBenchmarks)
+	router.Get("/api/benchmarks/consent", handler.GetConsent)
+	router.Put("/api/benchmarks/consent", handler.SetConsent)
+	router.Delete("/api/benchmarks/consent", handler.RevokeConsent)
+
+	return router, mockService
+}
+
+func TestGetBenchmarks(t *testing.T) {
+	router, mockService := setupBenchmarkTest()
+
+	t.Run("Success", func(t *testing.T) {
+		average := 4.2
+		comparison := &models.BenchmarkComparison{
+			Industry:                   models.IndustryLegal,
+			EntitiesPerPage:            3.5,
+			IndustryAvgEntitiesPerPage: &average,
+			Report: &models.BenchmarkReport{
+				Industries: []*models.IndustryBenchmark{{Industry: models.IndustryLegal, AvgEntitiesPerPage: average}},
+				Epsilon:    1.0,
+			},
+		}
+		mockService.On("GetComparison", mock.Anything, int64(1)).Return(comparison, nil).Once()
+
+		req, err := http.NewRequest("GET", "/api/benchmarks", nil)
+		require.NoError(t, err)
+		req = req.WithContext(createAuthContext(1))
+
+		rr := httptest.NewRecorder()
+		router.ServeHTTP(rr, req)
+
+		golden.AssertResponse(t, rr)
+	})
+
+	t.Run("Not Consented", func(t *testing.T) {
+		mockService.On("GetComparison", mock.Anything, int64(2)).
+			Return(nil, utils.NewForbiddenError(constants.MsgBenchmarkConsentRequired)).Once()
+
+		req, err := http.NewRequest("GET", "/api/benchmarks", nil)
+		require.NoError(t, err)
+		req = req.WithContext(createAuthContext(2))
+
+		rr := httptest.NewRecorder()
+		router.ServeHTTP(rr, req)
+
+		assert.Equal(t, http.StatusForbidden, rr.Code)
+	})
+
+	t.Run("Unauthenticated", func(t *testing.T) {
+		req, err := http.NewRequest("GET", "/api/benchmarks", nil)
+		require.NoError(t, err)
+
+		rr := httptest.NewRecorder()
+		router.ServeHTTP(rr, req)
+
+		assert.Equal(t, http.StatusUnauthorized, rr.Code)
+	})
+
+	mockService.AssertExpectations(t)
+}
+
+func TestGetBenchmarkConsent(t *testing.T) {
+	router, mockService := setupBenchmarkTest()
+
+	mockService.On("GetConsent", mock.Anything, int64(1)).
+		Return(nil, utils.NewNotFoundError("BenchmarkConsent", int64(1))).Once()
+
+	req, err := http.NewRequest("GET", "/api/benchmarks/consent", nil)
+	require.NoError(t, err)
+	req = req.WithContext(createAuthContext(1))
+
+	rr := httptest.NewRecorder()
+	router.ServeHTTP(rr, req)
+
+	assert.Equal(t, http.StatusNotFound, rr.Code)
+	mockService.AssertExpectations(t)
+}
+
+func TestSetBenchmarkConsent(t *testing.T) {
+	router, mockService := setupBenchmarkTest()
+
+	t.Run("Success", func(t *testing.T) {
+		consent := models.NewBenchmarkConsent(1, models.IndustryFinance)
+		consent.ConsentedAt = fixtures.Time
+		mockService.On("SetConsent", mock.Anything, int64(1), models.IndustryFinance).Return(consent, nil).Once()
+
+		req, err := http.NewRequest("PUT", "/api/benchmarks/consent", bytes.NewBufferString(`{"industry":"finance"}`))
+		require.NoError(t, err)
+		req.Header.Set("Content-Type", "application/json")
+		req = req.WithContext(createAuthContext(1))
+
+		rr := httptest.NewRecorder()
+		router.ServeHTTP(rr, req)
+
+		golden.AssertResponse(t, rr)
+	})
+
+	t.Run("Unknown Industry", func(t *testing.T) {
+		req, err := http.NewRequest("PUT", "/api/benchmarks/consent", bytes.NewBufferString(`{"industry":"mining"}`))
+		require.NoError(t, err)
+		req.Header.Set("Content-Type", "application/json")
+		req = req.WithContext(createAuthContext(1))
+
+		rr := httptest.NewRecorder()
+		router.ServeHTTP(rr, req)
+
+		assert.Equal(t, http.StatusBadRequest, rr.Code)
+	})
+
+	mockService.AssertExpectations(t)
+}
+
+func TestRevokeBenchmarkConsent(t *testing.T) {
+	router, mockService := setupBenchmarkTest()
+
+	mockService.On("RevokeConsent", mock.Anything, int64(1)).Return(nil).Once()
+
+	req, err := http.NewRequest("DELETE", "/api/benchmarks/consent", nil)
+	require.NoError(t, err)
+	req = req.WithContext(createAuthContext(1))
+
+	rr := httptest.NewRecorder()
+	router.ServeHTTP(rr, req)
+
+	assert.Equal(t, http.StatusNoContent, rr.Code)
+	mockService.AssertExpectations(t)
+}
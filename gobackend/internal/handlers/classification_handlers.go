@@ -0,0 +1,300 @@
+// Package handlers provides HTTP request handlers for the HideMe API.
+package handlers
+
+import (
+	"context"
+	"net/http"
+	"strconv"
+
+	"github.com/go-chi/chi/v5"
+
+	"github.com/yasinhessnawi1/Hideme_Backend/internal/auth"
+	"github.com/yasinhessnawi1/Hideme_Backend/internal/constants"
+	"github.com/yasinhessnawi1/Hideme_Backend/internal/models"
+	"github.com/yasinhessnawi1/Hideme_Backend/internal/utils"
+)
+
+// ClassificationServiceInterface defines the service methods required for classification rule operations.
+type ClassificationServiceInterface interface {
+	// GetRules retrieves the classification rules of a user in the order they are applied.
+	//
+	// Parameters:
+	//   - ctx: Context for the operation
+	//   - userID: The ID of the user
+	//
+	// Returns:
+	//   - The rules ordered by priority
+	//   - An error if retrieval fails
+	GetRules(ctx context.Context, userID int64) ([]*models.ClassificationRule, error)
+
+	// CreateRule creates a new classification rule for a user.
+	//
+	// Parameters:
+	//   - ctx: Context for the operation
+	//   - userID: The ID of the user
+	//   - req: The rule to create
+	//
+	// Returns:
+	//   - The created rule
+	//   - ValidationError if the rule lacks a condition or an action, or has a malformed pattern
+	CreateRule(ctx context.Context, userID int64, req *models.ClassificationRuleRequest) (*models.ClassificationRule, error)
+
+	// UpdateRule replaces the conditions and actions of one of a user's classification rules.
+	//
+	// Parameters:
+	//   - ctx: Context for the operation
+	//   - userID: The ID of the user
+	//   - ruleID: The ID of the rule
+	//   - req: The new conditions and actions
+	//
+	// Returns:
+	//   - The updated rule
+	//   - ForbiddenError if the rule belongs to another user
+	//   - NotFoundError if the rule doesn't exist
+	UpdateRule(ctx context.Context, userID, ruleID int64, req *models.ClassificationRuleRequest) (*models.ClassificationRule, error)
+
+	// DeleteRule removes one of a user's classification rules.
+	//
+	// Parameters:
+	//   - ctx: Context for the operation
+	//   - userID: The ID of the user
+	//   - ruleID: The ID of the rule
+	//
+	// Returns:
+	//   - ForbiddenError if the rule belongs to another user
+	//   - NotFoundError if the rule doesn't exist
+	DeleteRule(ctx context.Context, userID, ruleID int64) error
+}
+
+// ClassificationHandler handles HTTP requests related to document classification rules.
+type ClassificationHandler struct {
+	classificationService ClassificationServiceInterface
+}
+
+// NewClassificationHandler creates a new ClassificationHandler with the provided classification service.
+//
+// Parameters:
+//   - classificationService: Service handling classification rules
+//
+// Returns:
+//   - A properly initialized ClassificationHandler
+func NewClassificationHandler(classificationService ClassificationServiceInterface) *ClassificationHandler {
+	return &ClassificationHandler{
+		classificationService: classificationService,
+	}
+}
+
+// GetRules returns the classification rules of the current user.
+//
+// HTTP Method:
+//   - GET
+//
+// URL Path:
+//   - /api/settings/classification-rules
+//
+// Requires:
+//   - Authentication: User must be logged in
+//
+// Responses:
+//   - 200 OK: List of rules in the order they are applied
+//   - 401 Unauthorized: User not authenticated
+//   - 500 Internal Server Error: Server-side error
+//
+// @Summary List classification rules
+// @Description Returns the rules that tag, file and set the retention of uploaded documents, in the order they are applied
+// @Tags Settings/Classification
+// @Produce json
+// @Security BearerAuth
+// @Success 200 {object} utils.Response{data=[]models.ClassificationRule} "List of rules"
+// @Failure 401 {object} utils.Response{error=string} "User not authenticated"
+// @Failure 500 {object} utils.Response{error=string} "Server error"
+// @Router /settings/classification-rules [get]
+func (h *ClassificationHandler) GetRules(w http.ResponseWriter, r *http.Request) {
+	userID, ok := auth.GetUserID(r)
+	if !ok {
+		utils.Unauthorized(w, constants.MsgAuthRequired)
+		return
+	}
+
+	rules, err := h.classificationService.GetRules(r.Context(), userID)
+	if err != nil {
+		utils.ErrorFromAppError(w, utils.ParseError(err))
+		return
+	}
+
+	utils.ListAll(w, r, rules)
+}
+
+// CreateRule creates a classification rule for the current user.
+// A rule needs at least one condition (filename pattern, source, entity types or
+// minimum entity count) and at least one action (tags, folder or retention).
+//
+// HTTP Method:
+//   - POST
+//
+// URL Path:
+//   - /api/settings/classification-rules
+//
+// Requires:
+//   - Authentication: User must be logged in
+//
+// Request Body:
+//   - JSON object conforming to models.ClassificationRuleRequest
+//
+// Responses:
+//   - 201 Created: Rule created
+//   - 400 Bad Request: Invalid request body or incomplete rule
+//   - 401 Unauthorized: User not authenticated
+//   - 500 Internal Server Error: Server-side error
+//
+// @Summary Create classification rule
+// @Description Creates a rule that tags, files or sets the retention of matching documents at upload
+// @Tags Settings/Classification
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param rule body models.ClassificationRuleRequest true "Classification rule"
+// @Success 201 {object} utils.Response{data=models.ClassificationRule} "Rule created"
+// @Failure 400 {object} utils.Response{error=string} "Invalid request body or incomplete rule"
+// @Failure 401 {object} utils.Response{error=string} "User not authenticated"
+// @Failure 500 {object} utils.Response{error=string} "Server error"
+// @Router /settings/classification-rules [post]
+func (h *ClassificationHandler) CreateRule(w http.ResponseWriter, r *http.Request) {
+	userID, ok := auth.GetUserID(r)
+	if !ok {
+		utils.Unauthorized(w, constants.MsgAuthRequired)
+		return
+	}
+
+	var req models.ClassificationRuleRequest
+	if err := utils.DecodeAndValidate(r, &req); err != nil {
+		utils.ErrorFromAppError(w, utils.ParseError(err))
+		return
+	}
+
+	rule, err := h.classificationService.CreateRule(r.Context(), userID, &req)
+	if err != nil {
+		utils.ErrorFromAppError(w, utils.ParseError(err))
+		return
+	}
+
+	utils.JSON(w, constants.StatusCreated, rule)
+}
+
+// UpdateRule replaces a classification rule of the current user. Documents classified
+// before the change keep their tags, folder and retention.
+//
+// HTTP Method:
+//   - PUT
+//
+// URL Path:
+//   - /api/settings/classification-rules/{ruleID}
+//
+// Requires:
+//   - Authentication: User must be logged in
+//
+// Request Body:
+//   - JSON object conforming to models.ClassificationRuleRequest
+//
+// Responses:
+//   - 200 OK: Rule updated
+//   - 400 Bad Request: Invalid request body, rule ID or incomplete rule
+//   - 401 Unauthorized: User not authenticated
+//   - 403 Forbidden: Rule belongs to another user
+//   - 404 Not Found: Rule not found
+//   - 500 Internal Server Error: Server-side error
+//
+// @Summary Update classification rule
+// @Description Replaces the conditions and actions of a classification rule
+// @Tags Settings/Classification
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param ruleID path int true "ID of the rule to update"
+// @Param rule body models.ClassificationRuleRequest true "Classification rule"
+// @Success 200 {object} utils.Response{data=models.ClassificationRule} "Rule updated"
+// @Failure 400 {object} utils.Response{error=string} "Invalid request body, rule ID or incomplete rule"
+// @Failure 401 {object} utils.Response{error=string} "User not authenticated"
+// @Failure 403 {object} utils.Response{error=string} "Rule belongs to another user"
+// @Failure 404 {object} utils.Response{error=string} "Rule not found"
+// @Failure 500 {object} utils.Response{error=string} "Server error"
+// @Router /settings/classification-rules/{ruleID} [put]
+func (h *ClassificationHandler) UpdateRule(w http.ResponseWriter, r *http.Request) {
+	userID, ok := auth.GetUserID(r)
+	if !ok {
+		utils.Unauthorized(w, constants.MsgAuthRequired)
+		return
+	}
+
+	ruleID, err := strconv.ParseInt(chi.URLParam(r, constants.ParamRuleID), 10, 64)
+	if err != nil {
+		utils.BadRequest(w, "Invalid rule ID", nil)
+		return
+	}
+
+	var req models.ClassificationRuleRequest
+	if err := utils.DecodeAndValidate(r, &req); err != nil {
+		utils.ErrorFromAppError(w, utils.ParseError(err))
+		return
+	}
+
+	rule, err := h.classificationService.UpdateRule(r.Context(), userID, ruleID, &req)
+	if err != nil {
+		utils.ErrorFromAppError(w, utils.ParseError(err))
+		return
+	}
+
+	utils.JSON(w, constants.StatusOK, rule)
+}
+
+// DeleteRule removes a classification rule of the current user.
+//
+// HTTP Method:
+//   - DELETE
+//
+// URL Path:
+//   - /api/settings/classification-rules/{ruleID}
+//
+// Requires:
+//   - Authentication: User must be logged in
+//
+// Responses:
+//   - 204 No Content: Rule removed
+//   - 400 Bad Request: Invalid rule ID
+//   - 401 Unauthorized: User not authenticated
+//   - 403 Forbidden: Rule belongs to another user
+//   - 404 Not Found: Rule not found
+//   - 500 Internal Server Error: Server-side error
+//
+// @Summary Delete classification rule
+// @Description Removes a classification rule; documents it classified keep their tags, folder and retention
+// @Tags Settings/Classification
+// @Security BearerAuth
+// @Param ruleID path int true "ID of the rule to delete"
+// @Success 204 "Rule removed"
+// @Failure 400 {object} utils.Response{error=string} "Invalid rule ID"
+// @Failure 401 {object} utils.Response{error=string} "User not authenticated"
+// @Failure 403 {object} utils.Response{error=string} "Rule belongs to another user"
+// @Failure 404 {object} utils.Response{error=string} "Rule not found"
+// @Failure 500 {object} utils.Response{error=string} "Server error"
+// @Router /settings/classification-rules/{ruleID} [delete]
+func (h *ClassificationHandler) DeleteRule(w http.ResponseWriter, r *http.Request) {
+	userID, ok := auth.GetUserID(r)
+	if !ok {
+		utils.Unauthorized(w, constants.MsgAuthRequired)
+		return
+	}
+
+	ruleID, err := strconv.ParseInt(chi.URLParam(r, constants.ParamRuleID), 10, 64)
+	if err != nil {
+		utils.BadRequest(w, "Invalid rule ID", nil)
+		return
+	}
+
+	if err := h.classificationService.DeleteRule(r.Context(), userID, ruleID); err != nil {
+		utils.ErrorFromAppError(w, utils.ParseError(err))
+		return
+	}
+
+	utils.NoContent(w)
+}
@@ -0,0 +1,226 @@
+package handlers_test
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+
+	"github.com/yasinhessnawi1/Hideme_Backend/internal/constants"
+	"github.com/yasinhessnawi1/Hideme_Backend/internal/handlers"
+	"github.com/yasinhessnawi1/Hideme_Backend/internal/models"
+	"github.com/yasinhessnawi1/Hideme_Backend/internal/testsupport/golden"
+	"github.com/yasinhessnawi1/Hideme_Backend/internal/utils"
+)
+
+// MockClassificationService is a mock implementation of the ClassificationService
+type MockClassificationService struct {
+	mock.Mock
+}
+
+func (m *MockClassificationService) GetRules(ctx context.Context, userID int64) ([]*models.ClassificationRule, error) {
+	args := m.Called(ctx, userID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]*models.ClassificationRule), args.Error(1)
+}
+
+func (m *MockClassificationService) CreateRule(ctx context.Context, userID int64, req *models.ClassificationRuleRequest) (*models.ClassificationRule, error) {
+	args := m.Called(ctx, userID, req)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*models.ClassificationRule), args.Error(1)
+}
+
+func (m *MockClassificationService) UpdateRule(ctx context.Context, userID, ruleID int64, req *models.ClassificationRuleRequest) (*models.ClassificationRule, error) {
+	args := m.Called(ctx, userID, ruleID, req)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*models.ClassificationRule), args.Error(1)
+}
+
+func (m *MockClassificationService) DeleteRule(ctx context.Context, userID, ruleID int64) error {
+	args := m.Called(ctx, userID, ruleID)
+	return args.Error(0)
+}
+
+func setupClassificationTest() (*chi.Mux, *MockClassificationService) {
+	mockService := new(MockClassificationService)
+	handler := handlers.NewClassificationHandler(mockService)
+
+	router := chi.NewRouter()
+	router.Get("/api/settings/classification-rules", handler.GetRules)
+	router.Post("/api/settings/classification-rules", handler.CreateRule)
+	router.Put("/api/settings/classification-rules/{ruleID}", handler.UpdateRule)
+	router.Delete("/api/settings/classification-rules/{ruleID}", handler.DeleteRule)
+
+	return router, mockService
+}
+
+func TestGetClassificationRules(t *testing.T) {
+	router, mockService := setupClassificationTest()
+
+	rules := []*models.ClassificationRule{
+		{ID: 5, SettingID: 1, Name: "Invoices", FilenamePattern: "invoice*", Tags: []string{"finance"}, EntityTypes: []string{}},
+	}
+	mockService.On("GetRules", mock.Anything, int64(1)).Return(rules, nil).Once()
+
+	req, err := http.NewRequest("GET", "/api/settings/classification-rules", nil)
+	require.NoError(t, err)
+	req = req.WithContext(createAuthContext(1))
+
+	rr := httptest.NewRecorder()
+	router.ServeHTTP(rr, req)
+
+	golden.AssertResponse(t, rr)
+	mockService.AssertExpectations(t)
+}
+
+func TestCreateClassificationRule(t *testing.T) {
+	router, mockService := setupClassificationTest()
+
+	t.Run("Success", func(t *testing.T) {
+		expected := &models.ClassificationRuleRequest{Name: "Scans", Source: "scanner", Folder: "Scans", RetentionDays: 30}
+		rule := models.NewClassificationRule(1, expected)
+		rule.ID = 6
+		mockService.On("CreateRule", mock.Anything, int64(1), expected).Return(rule, nil).Once()
+
+		body, _ := json.Marshal(map[string]interface{}{"name": "Scans", "source": "scanner", "folder": "Scans", "retention_days": 30})
+		req, err := http.NewRequest("POST", "/api/settings/classification-rules", bytes.NewBuffer(body))
+		require.NoError(t, err)
+		req.Header.Set("Content-Type", "application/json")
+		req = req.WithContext(createAuthContext(1))
+
+		rr := httptest.NewRecorder()
+		router.ServeHTTP(rr, req)
+
+		golden.AssertResponse(t, rr)
+	})
+
+	t.Run("Invalid Retention", func(t *testing.T) {
+		body, _ := json.Marshal(map[string]interface{}{"name": "Scans", "source": "scanner", "retention_days": -1})
+		req, err := http.NewRequest("POST", "/api/settings/classification-rules", bytes.NewBuffer(body))
+		require.NoError(t, err)
+		req.Header.Set("Content-Type", "application/json")
+		req = req.WithContext(createAuthContext(1))
+
+		rr := httptest.NewRecorder()
+		router.ServeHTTP(rr, req)
+
+		assert.Equal(t, http.StatusBadRequest, rr.Code)
+	})
+
+	t.Run("Incomplete Rule", func(t *testing.T) {
+		mockService.On("CreateRule", mock.Anything, int64(1), mock.Anything).
+			Return(nil, utils.NewValidationError("rule", constants.MsgClassificationRuleIncomplete)).Once()
+
+		body, _ := json.Marshal(map[string]interface{}{"name": "Everything", "tags": []string{"all"}})
+		req, err := http.NewRequest("POST", "/api/settings/classification-rules", bytes.NewBuffer(body))
+		require.NoError(t, err)
+		req.Header.Set("Content-Type", "application/json")
+		req = req.WithContext(createAuthContext(1))
+
+		rr := httptest.NewRecorder()
+		router.ServeHTTP(rr, req)
+
+		assert.Equal(t, http.StatusBadRequest, rr.Code)
+	})
+
+	t.Run("Unauthenticated", func(t *testing.T) {
+		req, err := http.NewRequest("POST", "/api/settings/classification-rules", bytes.NewBufferString(`{}`))
+		require.NoError(t, err)
+
+		rr := httptest.NewRecorder()
+		router.ServeHTTP(rr, req)
+
+		assert.Equal(t, http.StatusUnauthorized, rr.Code)
+	})
+}
+
+func TestUpdateClassificationRule(t *testing.T) {
+	router, mockService := setupClassificationTest()
+
+	t.Run("Success", func(t *testing.T) {
+		rule := &models.ClassificationRule{ID: 5, SettingID: 1, Name: "Invoices", FilenamePattern: "invoice*", Folder: "Finance"}
+		mockService.On("UpdateRule", mock.Anything, int64(1), int64(5), mock.Anything).Return(rule, nil).Once()
+
+		body, _ := json.Marshal(map[string]interface{}{"name": "Invoices", "filename_pattern": "invoice*", "folder": "Finance"})
+		req, err := http.NewRequest("PUT", "/api/settings/classification-rules/5", bytes.NewBuffer(body))
+		require.NoError(t, err)
+		req.Header.Set("Content-Type", "application/json")
+		req = req.WithContext(createAuthContext(1))
+
+		rr := httptest.NewRecorder()
+		router.ServeHTTP(rr, req)
+
+		golden.AssertResponse(t, rr)
+	})
+
+	t.Run("Other User's Rule", func(t *testing.T) {
+		mockService.On("UpdateRule", mock.Anything, int64(1), int64(7), mock.Anything).
+			Return(nil, utils.NewForbiddenError(constants.MsgAccessDenied)).Once()
+
+		body, _ := json.Marshal(map[string]interface{}{"name": "Invoices", "filename_pattern": "invoice*", "folder": "Finance"})
+		req, err := http.NewRequest("PUT", "/api/settings/classification-rules/7", bytes.NewBuffer(body))
+		require.NoError(t, err)
+		req.Header.Set("Content-Type", "application/json")
+		req = req.WithContext(createAuthContext(1))
+
+		rr := httptest.NewRecorder()
+		router.ServeHTTP(rr, req)
+
+		assert.Equal(t, http.StatusForbidden, rr.Code)
+	})
+
+	t.Run("Invalid ID", func(t *testing.T) {
+		req, err := http.NewRequest("PUT", "/api/settings/classification-rules/abc", bytes.NewBufferString(`{}`))
+		require.NoError(t, err)
+		req = req.WithContext(createAuthContext(1))
+
+		rr := httptest.NewRecorder()
+		router.ServeHTTP(rr, req)
+
+		assert.Equal(t, http.StatusBadRequest, rr.Code)
+	})
+}
+
+func TestDeleteClassificationRule(t *testing.T) {
+	router, mockService := setupClassificationTest()
+
+	t.Run("Success", func(t *testing.T) {
+		mockService.On("DeleteRule", mock.Anything, int64(1), int64(5)).Return(nil).Once()
+
+		req, err := http.NewRequest("DELETE", "/api/settings/classification-rules/5", nil)
+		require.NoError(t, err)
+		req = req.WithContext(createAuthContext(1))
+
+		rr := httptest.NewRecorder()
+		router.ServeHTTP(rr, req)
+
+		assert.Equal(t, http.StatusNoContent, rr.Code)
+	})
+
+	t.Run("Not Found", func(t *testing.T) {
+		mockService.On("DeleteRule", mock.Anything, int64(1), int64(6)).
+			Return(utils.NewNotFoundError("ClassificationRule", int64(6))).Once()
+
+		req, err := http.NewRequest("DELETE", "/api/settings/classification-rules/6", nil)
+		require.NoError(t, err)
+		req = req.WithContext(createAuthContext(1))
+
+		rr := httptest.NewRecorder()
+		router.ServeHTTP(rr, req)
+
+		assert.Equal(t, http.StatusNotFound, rr.Code)
+	})
+}
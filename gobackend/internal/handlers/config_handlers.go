@@ -0,0 +1,59 @@
+// Package handlers provides HTTP request handlers for the HideMe API.
+package handlers
+
+import (
+	"net/http"
+
+	"github.com/yasinhessnawi1/Hideme_Backend/internal/config"
+	"github.com/yasinhessnawi1/Hideme_Backend/internal/constants"
+	"github.com/yasinhessnawi1/Hideme_Backend/internal/utils"
+)
+
+// ConfigHandler handles HTTP requests for checking the running configuration.
+type ConfigHandler struct {
+	config *config.AppConfig
+}
+
+// NewConfigHandler creates a new ConfigHandler for the running configuration.
+//
+// Parameters:
+//   - cfg: The configuration the server was started with
+//
+// Returns:
+//   - A properly initialized ConfigHandler
+func NewConfigHandler(cfg *config.AppConfig) *ConfigHandler {
+	return &ConfigHandler{
+		config: cfg,
+	}
+}
+
+// CheckConfig validates the running configuration and reports unsafe settings.
+// Secrets are redacted from the returned settings.
+//
+// HTTP Method:
+//   - GET
+//
+// URL Path:
+//   - /api/admin/config
+//
+// Requires:
+//   - Authentication: Admin role
+//
+// Responses:
+//   - 200 OK: Check result
+//   - 401 Unauthorized: User not authenticated
+//   - 403 Forbidden: User not authorized (not admin)
+//
+// @Summary Check configuration
+// @Description Validates the running configuration and reports unsafe settings, with secrets redacted
+// @Tags Admin/Config
+// @Produce json
+// @Security BearerAuth
+// @Security ApiKeyAuth
+// @Success 200 {object} utils.Response{data=config.CheckResult} "Check result"
+// @Failure 401 {object} utils.Response{error=string} "User not authenticated"
+// @Failure 403 {object} utils.Response{error=string} "User not authorized"
+// @Router /admin/config [get]
+func (h *ConfigHandler) CheckConfig(w http.ResponseWriter, r *http.Request) {
+	utils.JSON(w, constants.StatusOK, config.Check(h.config))
+}
@@ -0,0 +1,32 @@
+package handlers_test
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/yasinhessnawi1/Hideme_Backend/internal/config"
+	"github.com/yasinhessnawi1/Hideme_Backend/internal/handlers"
+	"github.com/yasinhessnawi1/Hideme_Backend/internal/testsupport/golden"
+)
+
+func TestCheckConfig(t *testing.T) {
+	cfg := &config.AppConfig{
+		App:      config.AppSettings{Environment: "development"},
+		Database: config.DatabaseSettings{User: "hideme", Password: "db-password"},
+		JWT:      config.JWTSettings{Secret: "short"},
+		Logging:  config.LoggingSettings{Level: "info"},
+	}
+	handler := handlers.NewConfigHandler(cfg)
+
+	req, err := http.NewRequest("GET", "/api/admin/config", nil)
+	require.NoError(t, err)
+	rr := httptest.NewRecorder()
+	handler.CheckConfig(rr, req)
+
+	golden.AssertResponse(t, rr)
+	assert.NotContains(t, rr.Body.String(), "db-password")
+}
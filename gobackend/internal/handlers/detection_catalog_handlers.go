@@ -0,0 +1,214 @@
+// Package handlers provides HTTP request handlers for the HideMe API.
+package handlers
+
+import (
+	"context"
+	"net/http"
+
+	"github.com/yasinhessnawi1/Hideme_Backend/internal/auth"
+	"github.com/yasinhessnawi1/Hideme_Backend/internal/constants"
+	"github.com/yasinhessnawi1/Hideme_Backend/internal/models"
+	"github.com/yasinhessnawi1/Hideme_Backend/internal/utils"
+)
+
+// DetectionCatalogServiceInterface defines the service methods required for promoting the detection catalog between environments.
+type DetectionCatalogServiceInterface interface {
+	// Export returns the detection methods and entity types of this environment as a bundle.
+	//
+	// Parameters:
+	//   - ctx: Context for the operation
+	//
+	// Returns:
+	//   - The bundle, with methods and entity types by name
+	//   - An error if retrieval fails
+	Export(ctx context.Context) (*models.DetectionCatalogBundle, error)
+
+	// Preview compares a bundle with this environment without changing anything.
+	//
+	// Parameters:
+	//   - ctx: Context for the operation
+	//   - bundle: The bundle exported from another environment
+	//
+	// Returns:
+	//   - What importing the bundle would create, update and keep
+	//   - ValidationError if the bundle is not valid for this environment
+	Preview(ctx context.Context, bundle *models.DetectionCatalogBundle) (*models.DetectionCatalogDiff, error)
+
+	// Import creates and updates the detection methods and entity types of a bundle, all or nothing.
+	//
+	// Parameters:
+	//   - ctx: Context for the operation
+	//   - adminID: The ID of the administrator importing the bundle
+	//   - bundle: The bundle exported from another environment
+	//
+	// Returns:
+	//   - What the import created, updated and kept
+	//   - ValidationError if the bundle is not valid for this environment
+	Import(ctx context.Context, adminID int64, bundle *models.DetectionCatalogBundle) (*models.DetectionCatalogDiff, error)
+}
+
+// DetectionCatalogHandler handles HTTP requests for promoting the detection methods and the
+// entity-type taxonomy between environments.
+type DetectionCatalogHandler struct {
+	catalogService DetectionCatalogServiceInterface
+}
+
+// NewDetectionCatalogHandler creates a new DetectionCatalogHandler with the provided catalog service.
+//
+// Parameters:
+//   - catalogService: Service exporting and importing the detection catalog
+//
+// Returns:
+//   - A properly initialized DetectionCatalogHandler
+func NewDetectionCatalogHandler(catalogService DetectionCatalogServiceInterface) *DetectionCatalogHandler {
+	return &DetectionCatalogHandler{
+		catalogService: catalogService,
+	}
+}
+
+// ExportDetectionCatalog returns the detection methods and entity types of this environment
+// as a versioned bundle to import into another environment.
+//
+// HTTP Method:
+//   - GET
+//
+// URL Path:
+//   - /api/admin/detection-catalog/export
+//
+// Requires:
+//   - Authentication: Admin role
+//
+// Responses:
+//   - 200 OK: The bundle
+//   - 401 Unauthorized: User not authenticated
+//   - 403 Forbidden: User not authorized (not admin)
+//   - 500 Internal Server Error: Server-side error
+//
+// @Summary Export detection catalog
+// @Description Returns the detection methods and entity-type taxonomy as a versioned bundle
+// @Tags Admin/Detection Catalog
+// @Produce json
+// @Security BearerAuth
+// @Success 200 {object} utils.Response{data=models.DetectionCatalogBundle} "The bundle"
+// @Failure 401 {object} utils.Response{error=string} "User not authenticated"
+// @Failure 403 {object} utils.Response{error=string} "User not authorized"
+// @Failure 500 {object} utils.Response{error=string} "Server error"
+// @Router /admin/detection-catalog/export [get]
+func (h *DetectionCatalogHandler) ExportDetectionCatalog(w http.ResponseWriter, r *http.Request) {
+	bundle, err := h.catalogService.Export(r.Context())
+	if err != nil {
+		utils.ErrorFromAppError(w, utils.ParseError(err))
+		return
+	}
+
+	utils.JSON(w, constants.StatusOK, bundle)
+}
+
+// PreviewDetectionCatalogImport shows what importing a bundle would create and update in
+// this environment, without changing anything.
+//
+// HTTP Method:
+//   - POST
+//
+// URL Path:
+//   - /api/admin/detection-catalog/import/preview
+//
+// Requires:
+//   - Authentication: Admin role
+//
+// Request Body:
+//   - JSON object conforming to models.DetectionCatalogBundle
+//
+// Responses:
+//   - 200 OK: What the import would change
+//   - 400 Bad Request: Invalid bundle
+//   - 401 Unauthorized: User not authenticated
+//   - 403 Forbidden: User not authorized (not admin)
+//   - 500 Internal Server Error: Server-side error
+//
+// @Summary Preview detection catalog import
+// @Description Compares a bundle with this environment without changing anything
+// @Tags Admin/Detection Catalog
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param bundle body models.DetectionCatalogBundle true "Bundle exported from another environment"
+// @Success 200 {object} utils.Response{data=models.DetectionCatalogDiff} "What the import would change"
+// @Failure 400 {object} utils.Response{error=string} "Invalid bundle"
+// @Failure 401 {object} utils.Response{error=string} "User not authenticated"
+// @Failure 403 {object} utils.Response{error=string} "User not authorized"
+// @Failure 500 {object} utils.Response{error=string} "Server error"
+// @Router /admin/detection-catalog/import/preview [post]
+func (h *DetectionCatalogHandler) PreviewDetectionCatalogImport(w http.ResponseWriter, r *http.Request) {
+	var bundle models.DetectionCatalogBundle
+	if err := utils.DecodeAndValidate(r, &bundle); err != nil {
+		utils.ErrorFromAppError(w, utils.ParseError(err))
+		return
+	}
+
+	diff, err := h.catalogService.Preview(r.Context(), &bundle)
+	if err != nil {
+		utils.ErrorFromAppError(w, utils.ParseError(err))
+		return
+	}
+
+	utils.JSON(w, constants.StatusOK, diff)
+}
+
+// ImportDetectionCatalog creates and updates the detection methods and entity types of a
+// bundle in this environment, all or nothing. Methods and entity types the bundle doesn't
+// list are kept.
+//
+// HTTP Method:
+//   - POST
+//
+// URL Path:
+//   - /api/admin/detection-catalog/import
+//
+// Requires:
+//   - Authentication: Admin role
+//
+// Request Body:
+//   - JSON object conforming to models.DetectionCatalogBundle
+//
+// Responses:
+//   - 200 OK: What the import changed
+//   - 400 Bad Request: Invalid bundle
+//   - 401 Unauthorized: User not authenticated
+//   - 403 Forbidden: User not authorized (not admin)
+//   - 500 Internal Server Error: Server-side error
+//
+// @Summary Import detection catalog
+// @Description Creates and updates the detection methods and entity types of a bundle
+// @Tags Admin/Detection Catalog
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param bundle body models.DetectionCatalogBundle true "Bundle exported from another environment"
+// @Success 200 {object} utils.Response{data=models.DetectionCatalogDiff} "What the import changed"
+// @Failure 400 {object} utils.Response{error=string} "Invalid bundle"
+// @Failure 401 {object} utils.Response{error=string} "User not authenticated"
+// @Failure 403 {object} utils.Response{error=string} "User not authorized"
+// @Failure 500 {object} utils.Response{error=string} "Server error"
+// @Router /admin/detection-catalog/import [post]
+func (h *DetectionCatalogHandler) ImportDetectionCatalog(w http.ResponseWriter, r *http.Request) {
+	adminID, ok := auth.GetUserID(r)
+	if !ok {
+		utils.Unauthorized(w, constants.MsgAuthRequired)
+		return
+	}
+
+	var bundle models.DetectionCatalogBundle
+	if err := utils.DecodeAndValidate(r, &bundle); err != nil {
+		utils.ErrorFromAppError(w, utils.ParseError(err))
+		return
+	}
+
+	diff, err := h.catalogService.Import(r.Context(), adminID, &bundle)
+	if err != nil {
+		utils.ErrorFromAppError(w, utils.ParseError(err))
+		return
+	}
+
+	utils.JSON(w, constants.StatusOK, diff)
+}
@@ -0,0 +1,161 @@
+package handlers_test
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+
+	"github.com/yasinhessnawi1/Hideme_Backend/internal/constants"
+	"github.com/yasinhessnawi1/Hideme_Backend/internal/handlers"
+	"github.com/yasinhessnawi1/Hideme_Backend/internal/models"
+	"github.com/yasinhessnawi1/Hideme_Backend/internal/testsupport/golden"
+	"github.com/yasinhessnawi1/Hideme_Backend/internal/utils"
+)
+
+// MockDetectionCatalogService is a mock implementation of the DetectionCatalogService
+type MockDetectionCatalogService struct {
+	mock.Mock
+}
+
+func (m *MockDetectionCatalogService) Export(ctx context.Context) (*models.DetectionCatalogBundle, error) {
+	args := m.Called(ctx)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*models.DetectionCatalogBundle), args.Error(1)
+}
+
+func (m *MockDetectionCatalogService) Preview(ctx context.Context, bundle *models.DetectionCatalogBundle) (*models.DetectionCatalogDiff, error) {
+	args := m.Called(ctx, bundle)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*models.DetectionCatalogDiff), args.Error(1)
+}
+
+func (m *MockDetectionCatalogService) Import(ctx context.Context, adminID int64, bundle *models.DetectionCatalogBundle) (*models.DetectionCatalogDiff, error) {
+	args := m.Called(ctx, adminID, bundle)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*models.DetectionCatalogDiff), args.Error(1)
+}
+
+func setupDetectionCatalogTest() (*chi.Mux, *MockDetectionCatalogService) {
+	mockService := new(MockDetectionCatalogService)
+	handler := handlers.NewDetectionCatalogHandler(mockService)
+
+	router := chi.NewRouter()
+	router.Get("/api/admin/detection-catalog/export", handler.ExportDetectionCatalog)
+	router.Post("/api/admin/detection-catalog/import/preview", handler.PreviewDetectionCatalogImport)
+	router.Post("/api/admin/detection-catalog/import", handler.ImportDetectionCatalog)
+	return router, mockService
+}
+
+func TestExportDetectionCatalog(t *testing.T) {
+	router, mockService := setupDetectionCatalogTest()
+
+	bundle := &models.DetectionCatalogBundle{
+		Version:          constants.DetectionCatalogBundleVersion,
+		DetectionMethods: []models.DetectionCatalogMethod{{MethodName: "Presidio", HighlightColor: "#33FF57"}},
+		EntityTypes:      []models.DetectionCatalogEntityType{{EntityType: "PERSON", MethodName: "Presidio"}},
+	}
+	mockService.On("Export", mock.Anything).Return(bundle, nil).Once()
+
+	req, err := http.NewRequest("GET", "/api/admin/detection-catalog/export", nil)
+	require.NoError(t, err)
+	req = req.WithContext(createAuthContext(1))
+
+	rr := httptest.NewRecorder()
+	router.ServeHTTP(rr, req)
+
+	golden.AssertResponse(t, rr)
+	mockService.AssertExpectations(t)
+}
+
+func TestImportDetectionCatalog(t *testing.T) {
+	router, mockService := setupDetectionCatalogTest()
+
+	bundle := &models.DetectionCatalogBundle{
+		Version:          constants.DetectionCatalogBundleVersion,
+		DetectionMethods: []models.DetectionCatalogMethod{{MethodName: "Presidio", HighlightColor: "#00FF00"}},
+		EntityTypes:      []models.DetectionCatalogEntityType{{EntityType: "PERSON", MethodName: "Presidio"}},
+	}
+	body, _ := json.Marshal(bundle)
+
+	t.Run("Preview", func(t *testing.T) {
+		diff := &models.DetectionCatalogDiff{
+			Updated: 1,
+			Created: 1,
+			DetectionMethods: []models.DetectionCatalogChange{{
+				Name:   "Presidio",
+				Action: constants.DetectionCatalogChangeUpdate,
+				Fields: []models.DetectionCatalogFieldChange{{Field: "highlight_color", From: "#33FF57", To: "#00FF00"}},
+			}},
+			EntityTypes: []models.DetectionCatalogChange{{Name: "PERSON", Action: constants.DetectionCatalogChangeCreate}},
+		}
+		mockService.On("Preview", mock.Anything, bundle).Return(diff, nil).Once()
+
+		req, err := http.NewRequest("POST", "/api/admin/detection-catalog/import/preview", bytes.NewBuffer(body))
+		require.NoError(t, err)
+		req.Header.Set("Content-Type", "application/json")
+		req = req.WithContext(createAuthContext(1))
+
+		rr := httptest.NewRecorder()
+		router.ServeHTTP(rr, req)
+
+		golden.AssertResponse(t, rr)
+	})
+
+	t.Run("Import", func(t *testing.T) {
+		diff := &models.DetectionCatalogDiff{Applied: true, Created: 1, Updated: 1}
+		mockService.On("Import", mock.Anything, int64(1), bundle).Return(diff, nil).Once()
+
+		req, err := http.NewRequest("POST", "/api/admin/detection-catalog/import", bytes.NewBuffer(body))
+		require.NoError(t, err)
+		req.Header.Set("Content-Type", "application/json")
+		req = req.WithContext(createAuthContext(1))
+
+		rr := httptest.NewRecorder()
+		router.ServeHTTP(rr, req)
+
+		golden.AssertResponse(t, rr)
+	})
+
+	t.Run("Missing Version", func(t *testing.T) {
+		req, err := http.NewRequest("POST", "/api/admin/detection-catalog/import", bytes.NewBufferString(`{"detection_methods":[]}`))
+		require.NoError(t, err)
+		req.Header.Set("Content-Type", "application/json")
+		req = req.WithContext(createAuthContext(1))
+
+		rr := httptest.NewRecorder()
+		router.ServeHTTP(rr, req)
+
+		assert.Equal(t, http.StatusBadRequest, rr.Code)
+	})
+
+	t.Run("Unknown Method", func(t *testing.T) {
+		mockService.On("Import", mock.Anything, int64(1), mock.Anything).
+			Return(nil, utils.NewValidationError("entity_types[0].method_name", constants.MsgDetectionCatalogMethodUnknown)).Once()
+
+		req, err := http.NewRequest("POST", "/api/admin/detection-catalog/import", bytes.NewBuffer(body))
+		require.NoError(t, err)
+		req.Header.Set("Content-Type", "application/json")
+		req = req.WithContext(createAuthContext(1))
+
+		rr := httptest.NewRecorder()
+		router.ServeHTTP(rr, req)
+
+		golden.AssertResponse(t, rr)
+	})
+
+	mockService.AssertExpectations(t)
+}
@@ -0,0 +1,316 @@
+// Package handlers provides HTTP request handlers for the HideMe API.
+package handlers
+
+import (
+	"context"
+	"net/http"
+
+	"github.com/yasinhessnawi1/Hideme_Backend/internal/auth"
+	"github.com/yasinhessnawi1/Hideme_Backend/internal/constants"
+	"github.com/yasinhessnawi1/Hideme_Backend/internal/database"
+	"github.com/yasinhessnawi1/Hideme_Backend/internal/models"
+	"github.com/yasinhessnawi1/Hideme_Backend/internal/utils"
+)
+
+// QueryDiagnosticsServiceInterface defines the service methods required for query diagnostics.
+type QueryDiagnosticsServiceInterface interface {
+	// GetQueryDiagnostics returns the query monitoring settings and statistics.
+	//
+	// Parameters:
+	//   - ctx: Context for the operation
+	//
+	// Returns:
+	//   - The current monitoring settings
+	//   - The query statistics, the most expensive query first
+	//   - An error if monitoring is not available
+	GetQueryDiagnostics(ctx context.Context) (*database.QueryMonitorSettings, []database.QueryStats, error)
+
+	// UpdateQueryDiagnostics changes the query monitoring settings at runtime.
+	//
+	// Parameters:
+	//   - ctx: Context for the operation
+	//   - update: The settings to change
+	//   - userID: ID of the administrator making the change
+	//
+	// Returns:
+	//   - The updated monitoring settings
+	//   - An error if monitoring is not available
+	UpdateQueryDiagnostics(ctx context.Context, update *models.QueryDiagnosticsUpdate, userID int64) (*database.QueryMonitorSettings, error)
+
+	// ResetQueryMetrics discards the collected query statistics.
+	//
+	// Parameters:
+	//   - ctx: Context for the operation
+	//   - userID: ID of the administrator resetting the statistics
+	//
+	// Returns:
+	//   - An error if monitoring is not available
+	ResetQueryMetrics(ctx context.Context, userID int64) error
+}
+
+// CacheStatsSource is an in-process cache reporting its use.
+type CacheStatsSource interface {
+	// Stats returns the use of the cache since startup.
+	//
+	// Returns:
+	//   - The cache statistics
+	Stats() models.CacheStats
+}
+
+// ShadowStatsSource reports the requests mirrored to a shadow deployment.
+type ShadowStatsSource interface {
+	// Stats returns the requests mirrored since startup and their divergences.
+	//
+	// Returns:
+	//   - The shadow traffic statistics
+	Stats() models.ShadowTrafficStats
+}
+
+// DiagnosticsHandler handles HTTP requests related to runtime diagnostics.
+type DiagnosticsHandler struct {
+	diagnosticsService QueryDiagnosticsServiceInterface
+	caches             []CacheStatsSource
+	shadow             ShadowStatsSource
+}
+
+// NewDiagnosticsHandler creates a new DiagnosticsHandler with the provided service.
+//
+// Parameters:
+//   - diagnosticsService: Service handling query diagnostics
+//
+// Returns:
+//   - A properly initialized DiagnosticsHandler
+func NewDiagnosticsHandler(diagnosticsService QueryDiagnosticsServiceInterface) *DiagnosticsHandler {
+	return &DiagnosticsHandler{
+		diagnosticsService: diagnosticsService,
+	}
+}
+
+// SetCaches sets the in-process caches whose statistics are reported.
+//
+// Parameters:
+//   - caches: The caches to report
+func (h *DiagnosticsHandler) SetCaches(caches ...CacheStatsSource) {
+	h.caches = caches
+}
+
+// SetShadowTraffic sets the mirror of requests to the shadow deployment whose divergences
+// are reported. Without it, shadow traffic diagnostics report that mirroring is disabled.
+//
+// Parameters:
+//   - shadow: The shadow traffic mirror
+func (h *DiagnosticsHandler) SetShadowTraffic(shadow ShadowStatsSource) {
+	h.shadow = shadow
+}
+
+// GetQueryDiagnostics returns the query monitoring settings and per-query statistics.
+//
+// HTTP Method:
+//   - GET
+//
+// URL Path:
+//   - /api/admin/diagnostics/queries
+//
+// Requires:
+//   - Authentication: Admin role
+//
+// Responses:
+//   - 200 OK: Settings and query statistics
+//   - 400 Bad Request: Query monitoring not available
+//   - 401 Unauthorized: User not authenticated
+//   - 403 Forbidden: User not authorized (not admin)
+//   - 500 Internal Server Error: Server-side error
+//
+// @Summary Get query diagnostics
+// @Description Returns the query monitoring settings and per-query statistics, including captured plans of slow queries
+// @Tags Admin/Diagnostics
+// @Produce json
+// @Security BearerAuth
+// @Success 200 {object} utils.Response{data=map[string]interface{}} "Settings and query statistics"
+// @Failure 400 {object} utils.Response{error=string} "Query monitoring not available"
+// @Failure 401 {object} utils.Response{error=string} "User not authenticated"
+// @Failure 403 {object} utils.Response{error=string} "User not authorized"
+// @Failure 500 {object} utils.Response{error=string} "Server error"
+// @Router /admin/diagnostics/queries [get]
+func (h *DiagnosticsHandler) GetQueryDiagnostics(w http.ResponseWriter, r *http.Request) {
+	settings, queries, err := h.diagnosticsService.GetQueryDiagnostics(r.Context())
+	if err != nil {
+		utils.ErrorFromAppError(w, utils.ParseError(err))
+		return
+	}
+
+	utils.JSON(w, constants.StatusOK, map[string]interface{}{
+		"settings": settings,
+		"queries":  queries,
+	})
+}
+
+// UpdateQueryDiagnostics toggles query metrics and slow query plan capture at runtime.
+//
+// HTTP Method:
+//   - PUT
+//
+// URL Path:
+//   - /api/admin/diagnostics/queries/settings
+//
+// Requires:
+//   - Authentication: Admin role
+//
+// Request Body:
+//   - JSON object conforming to models.QueryDiagnosticsUpdate
+//
+// Responses:
+//   - 200 OK: Settings updated
+//   - 400 Bad Request: Invalid request body or query monitoring not available
+//   - 401 Unauthorized: User not authenticated
+//   - 403 Forbidden: User not authorized (not admin)
+//   - 500 Internal Server Error: Server-side error
+//
+// @Summary Update query diagnostics settings
+// @Description Turns query metrics and slow query plan capture on or off and changes the slow query threshold
+// @Tags Admin/Diagnostics
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param settings body models.QueryDiagnosticsUpdate true "Settings to change"
+// @Success 200 {object} utils.Response{data=database.QueryMonitorSettings} "Settings updated"
+// @Failure 400 {object} utils.Response{error=string} "Invalid request body"
+// @Failure 401 {object} utils.Response{error=string} "User not authenticated"
+// @Failure 403 {object} utils.Response{error=string} "User not authorized"
+// @Failure 500 {object} utils.Response{error=string} "Server error"
+// @Router /admin/diagnostics/queries/settings [put]
+func (h *DiagnosticsHandler) UpdateQueryDiagnostics(w http.ResponseWriter, r *http.Request) {
+	userID, ok := auth.GetUserID(r)
+	if !ok {
+		utils.Unauthorized(w, constants.MsgAuthRequired)
+		return
+	}
+
+	var req models.QueryDiagnosticsUpdate
+	if err := utils.DecodeAndValidate(r, &req); err != nil {
+		utils.ErrorFromAppError(w, utils.ParseError(err))
+		return
+	}
+
+	settings, err := h.diagnosticsService.UpdateQueryDiagnostics(r.Context(), &req, userID)
+	if err != nil {
+		utils.ErrorFromAppError(w, utils.ParseError(err))
+		return
+	}
+
+	utils.JSON(w, constants.StatusOK, settings)
+}
+
+// ResetQueryMetrics discards the collected query statistics.
+//
+// HTTP Method:
+//   - DELETE
+//
+// URL Path:
+//   - /api/admin/diagnostics/queries
+//
+// Requires:
+//   - Authentication: Admin role
+//
+// Responses:
+//   - 204 No Content: Statistics discarded
+//   - 400 Bad Request: Query monitoring not available
+//   - 401 Unauthorized: User not authenticated
+//   - 403 Forbidden: User not authorized (not admin)
+//   - 500 Internal Server Error: Server-side error
+//
+// @Summary Reset query metrics
+// @Description Discards the collected query statistics and captured plans
+// @Tags Admin/Diagnostics
+// @Security BearerAuth
+// @Success 204 "Statistics discarded"
+// @Failure 400 {object} utils.Response{error=string} "Query monitoring not available"
+// @Failure 401 {object} utils.Response{error=string} "User not authenticated"
+// @Failure 403 {object} utils.Response{error=string} "User not authorized"
+// @Failure 500 {object} utils.Response{error=string} "Server error"
+// @Router /admin/diagnostics/queries [delete]
+func (h *DiagnosticsHandler) ResetQueryMetrics(w http.ResponseWriter, r *http.Request) {
+	userID, ok := auth.GetUserID(r)
+	if !ok {
+		utils.Unauthorized(w, constants.MsgAuthRequired)
+		return
+	}
+
+	if err := h.diagnosticsService.ResetQueryMetrics(r.Context(), userID); err != nil {
+		utils.ErrorFromAppError(w, utils.ParseError(err))
+		return
+	}
+
+	utils.NoContent(w)
+}
+
+// GetCacheDiagnostics returns the hits and misses of the in-process caches.
+//
+// HTTP Method:
+//   - GET
+//
+// URL Path:
+//   - /api/admin/diagnostics/caches
+//
+// Requires:
+//   - Authentication: Admin role
+//
+// Responses:
+//   - 200 OK: Statistics of each cache
+//   - 401 Unauthorized: User not authenticated
+//   - 403 Forbidden: User not authorized (not admin)
+//
+// @Summary Get cache diagnostics
+// @Description Returns the entries, hits, misses and shared loads of each in-process cache since startup
+// @Tags Admin/Diagnostics
+// @Produce json
+// @Security BearerAuth
+// @Success 200 {object} utils.Response{data=[]models.CacheStats} "Statistics of each cache"
+// @Failure 401 {object} utils.Response{error=string} "User not authenticated"
+// @Failure 403 {object} utils.Response{error=string} "User not authorized"
+// @Router /admin/diagnostics/caches [get]
+func (h *DiagnosticsHandler) GetCacheDiagnostics(w http.ResponseWriter, r *http.Request) {
+	stats := make([]models.CacheStats, 0, len(h.caches))
+	for _, cache := range h.caches {
+		stats = append(stats, cache.Stats())
+	}
+
+	utils.JSON(w, constants.StatusOK, stats)
+}
+
+// GetShadowDiagnostics returns how the responses of the shadow deployment diverged from
+// this server's for the mirrored requests.
+//
+// HTTP Method:
+//   - GET
+//
+// URL Path:
+//   - /api/admin/diagnostics/shadow
+//
+// Requires:
+//   - Authentication: Admin role
+//
+// Responses:
+//   - 200 OK: Shadow traffic statistics
+//   - 400 Bad Request: Shadow traffic mirroring not enabled
+//   - 401 Unauthorized: User not authenticated
+//   - 403 Forbidden: User not authorized (not admin)
+//
+// @Summary Get shadow traffic diagnostics
+// @Description Returns the requests mirrored to the shadow deployment since startup, with status code mismatches and latency regressions per route
+// @Tags Admin/Diagnostics
+// @Produce json
+// @Security BearerAuth
+// @Success 200 {object} utils.Response{data=models.ShadowTrafficStats} "Shadow traffic statistics"
+// @Failure 400 {object} utils.Response{error=string} "Shadow traffic mirroring not enabled"
+// @Failure 401 {object} utils.Response{error=string} "User not authenticated"
+// @Failure 403 {object} utils.Response{error=string} "User not authorized"
+// @Router /admin/diagnostics/shadow [get]
+func (h *DiagnosticsHandler) GetShadowDiagnostics(w http.ResponseWriter, r *http.Request) {
+	if h.shadow == nil {
+		utils.BadRequest(w, constants.MsgShadowTrafficDisabled, nil)
+		return
+	}
+
+	utils.JSON(w, constants.StatusOK, h.shadow.Stats())
+}
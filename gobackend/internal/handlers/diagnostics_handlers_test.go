@@ -0,0 +1,178 @@
+package handlers_test
+
+import (
+	"bytes"
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+
+	"github.com/yasinhessnawi1/Hideme_Backend/internal/database"
+	"github.com/yasinhessnawi1/Hideme_Backend/internal/handlers"
+	"github.com/yasinhessnawi1/Hideme_Backend/internal/models"
+	"github.com/yasinhessnawi1/Hideme_Backend/internal/testsupport/golden"
+	"github.com/yasinhessnawi1/Hideme_Backend/internal/utils"
+)
+
+// MockDiagnosticsService is a mock implementation of the query diagnostics service
+type MockDiagnosticsService struct {
+	mock.Mock
+}
+
+func (m *MockDiagnosticsService) GetQueryDiagnostics(ctx context.Context) (*database.QueryMonitorSettings, []database.QueryStats, error) {
+	args := m.Called(ctx)
+	if args.Get(0) == nil {
+		return nil, nil, args.Error(2)
+	}
+	return args.Get(0).(*database.QueryMonitorSettings), args.Get(1).([]database.QueryStats), args.Error(2)
+}
+
+func (m *MockDiagnosticsService) UpdateQueryDiagnostics(ctx context.Context, update *models.QueryDiagnosticsUpdate, userID int64) (*database.QueryMonitorSettings, error) {
+	args := m.Called(ctx, update, userID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*database.QueryMonitorSettings), args.Error(1)
+}
+
+func (m *MockDiagnosticsService) ResetQueryMetrics(ctx context.Context, userID int64) error {
+	args := m.Called(ctx, userID)
+	return args.Error(0)
+}
+
+func setupDiagnosticsTest() (*chi.Mux, *MockDiagnosticsService) {
+	mockService := new(MockDiagnosticsService)
+	handler := handlers.NewDiagnosticsHandler(mockService)
+
+	router := chi.NewRouter()
+	router.Get("/api/admin/diagnostics/queries", handler.GetQueryDiagnostics)
+	router.Delete("/api/admin/diagnostics/queries", handler.ResetQueryMetrics)
+	router.Put("/api/admin/diagnostics/queries/settings", handler.UpdateQueryDiagnostics)
+
+	return router, mockService
+}
+
+func TestGetQueryDiagnostics(t *testing.T) {
+	router, mockService := setupDiagnosticsTest()
+
+	t.Run("Success", func(t *testing.T) {
+		settings := &database.QueryMonitorSettings{MetricsEnabled: true, SlowQueryThresholdMs: 500}
+		queries := []database.QueryStats{{Query: "SELECT 1", Calls: 3, SlowCalls: 1}}
+		mockService.On("GetQueryDiagnostics", mock.Anything).Return(settings, queries, nil).Once()
+
+		req, err := http.NewRequest("GET", "/api/admin/diagnostics/queries", nil)
+		require.NoError(t, err)
+		req = req.WithContext(createAuthContext(1))
+
+		rr := httptest.NewRecorder()
+		router.ServeHTTP(rr, req)
+
+		golden.AssertResponse(t, rr)
+	})
+
+	t.Run("Monitoring not available", func(t *testing.T) {
+		mockService.On("GetQueryDiagnostics", mock.Anything).
+			Return(nil, nil, utils.NewBadRequestError("Query monitoring is not available for this database connection")).Once()
+
+		req, err := http.NewRequest("GET", "/api/admin/diagnostics/queries", nil)
+		require.NoError(t, err)
+		req = req.WithContext(createAuthContext(1))
+
+		rr := httptest.NewRecorder()
+		router.ServeHTTP(rr, req)
+
+		assert.Equal(t, http.StatusBadRequest, rr.Code)
+	})
+
+	mockService.AssertExpectations(t)
+}
+
+func TestUpdateQueryDiagnostics(t *testing.T) {
+	router, mockService := setupDiagnosticsTest()
+
+	t.Run("Success", func(t *testing.T) {
+		settings := &database.QueryMonitorSettings{MetricsEnabled: true, ExplainEnabled: true, SlowQueryThresholdMs: 500}
+		mockService.On("UpdateQueryDiagnostics", mock.Anything, mock.MatchedBy(func(update *models.QueryDiagnosticsUpdate) bool {
+			return update.ExplainEnabled != nil && *update.ExplainEnabled && update.MetricsEnabled == nil
+		}), int64(1)).Return(settings, nil).Once()
+
+		req, err := http.NewRequest("PUT", "/api/admin/diagnostics/queries/settings", bytes.NewBufferString(`{"explain_enabled":true}`))
+		require.NoError(t, err)
+		req.Header.Set("Content-Type", "application/json")
+		req = req.WithContext(createAuthContext(1))
+
+		rr := httptest.NewRecorder()
+		router.ServeHTTP(rr, req)
+
+		golden.AssertResponse(t, rr)
+	})
+
+	t.Run("Invalid threshold", func(t *testing.T) {
+		req, err := http.NewRequest("PUT", "/api/admin/diagnostics/queries/settings", bytes.NewBufferString(`{"slow_query_threshold_ms":-5}`))
+		require.NoError(t, err)
+		req.Header.Set("Content-Type", "application/json")
+		req = req.WithContext(createAuthContext(1))
+
+		rr := httptest.NewRecorder()
+		router.ServeHTTP(rr, req)
+
+		assert.Equal(t, http.StatusBadRequest, rr.Code)
+	})
+
+	mockService.AssertExpectations(t)
+}
+
+func TestResetQueryMetrics(t *testing.T) {
+	router, mockService := setupDiagnosticsTest()
+
+	mockService.On("ResetQueryMetrics", mock.Anything, int64(1)).Return(nil).Once()
+
+	req, err := http.NewRequest("DELETE", "/api/admin/diagnostics/queries", nil)
+	require.NoError(t, err)
+	req = req.WithContext(createAuthContext(1))
+
+	rr := httptest.NewRecorder()
+	router.ServeHTTP(rr, req)
+
+	assert.Equal(t, http.StatusNoContent, rr.Code)
+	mockService.AssertExpectations(t)
+}
+
+// staticShadowStats reports fixed shadow traffic statistics
+type staticShadowStats struct {
+	stats models.ShadowTrafficStats
+}
+
+func (s *staticShadowStats) Stats() models.ShadowTrafficStats {
+	return s.stats
+}
+
+func TestGetShadowDiagnostics(t *testing.T) {
+	handler := handlers.NewDiagnosticsHandler(new(MockDiagnosticsService))
+
+	t.Run("Mirroring disabled", func(t *testing.T) {
+		rr := httptest.NewRecorder()
+		handler.GetShadowDiagnostics(rr, httptest.NewRequest("GET", "/api/admin/diagnostics/shadow", nil))
+
+		assert.Equal(t, http.StatusBadRequest, rr.Code)
+	})
+
+	t.Run("Success", func(t *testing.T) {
+		handler.SetShadowTraffic(&staticShadowStats{stats: models.ShadowTrafficStats{
+			Target:           "http://hideme-rc:8080",
+			Mirrored:         40,
+			StatusMismatches: 2,
+			Routes:           []models.ShadowRouteStats{{Route: "GET /api/documents/{id}", Mirrored: 10, StatusMismatches: 2}},
+		}})
+
+		rr := httptest.NewRecorder()
+		handler.GetShadowDiagnostics(rr, httptest.NewRequest("GET", "/api/admin/diagnostics/shadow", nil))
+
+		golden.AssertResponse(t, rr)
+	})
+}
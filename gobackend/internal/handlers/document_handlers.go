@@ -1,9 +1,19 @@
 package handlers
 
 import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
 	"errors"
+	"fmt"
+	"io"
+	"mime"
 	"net/http"
 	"strconv"
+	"strings"
+	"time"
 
 	"github.com/yasinhessnawi1/Hideme_Backend/internal/service"
 
@@ -12,17 +22,39 @@ import (
 	"github.com/yasinhessnawi1/Hideme_Backend/internal/auth"
 	"github.com/yasinhessnawi1/Hideme_Backend/internal/constants"
 	"github.com/yasinhessnawi1/Hideme_Backend/internal/models"
+	"github.com/yasinhessnawi1/Hideme_Backend/internal/redaction"
 	"github.com/yasinhessnawi1/Hideme_Backend/internal/utils"
 )
 
 // DocumentServiceInterface defines the service methods required for document operations.
 type DocumentServiceInterface interface {
-	ListDocuments(userID int64, page, pageSize int) ([]*models.Document, int, error)
-	UploadDocument(userID int64, filename string, redactionSchema models.RedactionMapping) (*models.Document, error)
-	GetDocumentByID(id int64) (*models.Document, error)
-	DeleteDocumentByID(id int64) error
-	GetDocumentSummary(id int64) (*models.DocumentSummary, error)
+	ListDocuments(ctx context.Context, userID int64, language string, page, pageSize int) ([]*models.Document, int, error)
+	ListDocumentsPage(ctx context.Context, userID int64, language string, after *utils.KeysetCursor, limit int) ([]*models.Document, error)
+	StreamDocuments(ctx context.Context, userID int64, language string, fn func(*models.Document) error) error
+	SearchDocuments(ctx context.Context, userID int64, filter models.DocumentSearchFilter, page, pageSize int) ([]*models.Document, int, error)
+	UploadDocument(ctx context.Context, userID int64, filename, language, source string, redactionSchema models.RedactionMapping) (*models.Document, error)
+	UploadDocumentWithContent(ctx context.Context, userID int64, filename, language, source string, redactionSchema models.RedactionMapping, content []byte) (*models.Document, error)
+	OpenDocumentContent(ctx context.Context, userID, documentID int64) (*models.DocumentContent, io.ReadSeekCloser, error)
+	ProcessEphemeral(ctx context.Context, userID int64, filename, language, source string, redactionSchema models.RedactionMapping) (*models.EphemeralDocument, error)
+	GetDocumentByID(ctx context.Context, userID, id int64) (*models.Document, error)
+	DeleteDocumentByID(ctx context.Context, userID, id int64) error
+	GetDocumentSummary(ctx context.Context, userID, id int64) (*models.DocumentSummary, error)
+	UpdateRedactionSchema(ctx context.Context, userID, documentID int64, redactionSchema models.RedactionMapping) (*models.Document, error)
+	GetSchemaVersions(ctx context.Context, userID, documentID int64) ([]*models.DocumentSchemaVersion, error)
+	RollbackRedactionSchema(ctx context.Context, userID, documentID int64, version int) (*models.Document, error)
+	AddDetectedEntities(ctx context.Context, userID, documentID int64, inputs []models.DetectedEntityInput) (*models.DetectedEntityBatchResult, error)
+	GetDetectedEntities(ctx context.Context, userID, documentID int64, filter models.DetectedEntityFilter) ([]*models.DetectedEntityWithMethod, error)
+	ReviewEntities(ctx context.Context, userID, documentID int64, entityIDs []int64, status models.EntityReviewStatus) (*models.EntityReviewResult, error)
+	GetDocumentTimeline(ctx context.Context, userID, documentID int64, page, pageSize int) ([]*models.DocumentEvent, int, error)
+	ExportDocument(ctx context.Context, userID, documentID int64, locale models.Locale) (*models.DocumentExport, error)
+	RestoreFromArchive(ctx context.Context, userID, documentID int64) (*models.Document, error)
+	SetLifecycleExempt(ctx context.Context, userID, documentID int64, exempt bool) (*models.DocumentLifecycle, error)
+	PreviewRetention(ctx context.Context, userID int64, retentionDays *int) (*models.RetentionPreview, error)
+	GetPageOverlay(ctx context.Context, userID, documentID int64, page int) (*models.PageOverlay, error)
+	RedactDocument(ctx context.Context, userID, documentID int64, method string, content []byte) (*redaction.Result, error)
 	CalculateEntityCount(redactionSchema string) int
+	CheckUploadSize(size int64) error
+	MaxUploadBytes() int64
 }
 
 // DocumentService implements DocumentServiceInterface using a DocumentRepository.
@@ -41,15 +73,38 @@ func NewDocumentHandler(documentService DocumentServiceInterface) *DocumentHandl
 }
 
 // ListDocuments handles GET /api/documents
+// The optional "language" query parameter restricts the list to documents in that language.
+// With "Accept: application/x-ndjson" all documents are streamed one JSON object per
+// line as they are read, and the pagination parameters are ignored. With
+// "Accept: application/xml" the page is rendered as XML, see schema "documents".
+// With "pagination=keyset", or the next_cursor of such a page, documents are paged by
+// keyset cursors without a total, which stays fast however many documents the user has.
 func (h *DocumentHandler) ListDocuments(w http.ResponseWriter, r *http.Request) {
 	userID, ok := auth.GetUserID(r)
 	if !ok {
 		utils.Unauthorized(w, constants.MsgAuthRequired)
 		return
 	}
+	language, ok := models.NormalizeLanguage(r.URL.Query().Get(constants.QueryParamLanguage))
+	if !ok {
+		utils.BadRequest(w, constants.MsgInvalidLanguage, nil)
+		return
+	}
+	if utils.WantsNDJSON(r) {
+		log.Info().Int64("user_id", userID).Str("language", language).Msg("Streaming documents")
+		stream := utils.NewNDJSONStream(w)
+		stream.Finish(h.documentService.StreamDocuments(r.Context(), userID, language, func(doc *models.Document) error {
+			return stream.Write(h.toDocumentSummary(doc))
+		}))
+		return
+	}
 	params := utils.GetPaginationParams(r)
-	log.Info().Int64("user_id", userID).Int("page", params.Page).Int("page_size", params.PageSize).Msg("Listing documents")
-	docs, total, err := h.documentService.ListDocuments(userID, params.Page, params.PageSize)
+	if params.Keyset {
+		h.listDocumentsAfter(w, r, userID, language, params)
+		return
+	}
+	log.Info().Int64("user_id", userID).Int("page", params.Page).Int("page_size", params.PageSize).Str("language", language).Msg("Listing documents")
+	docs, total, err := h.documentService.ListDocuments(r.Context(), userID, language, params.Page, params.PageSize)
 	if err != nil {
 		log.Error().Err(err).Int64("user_id", userID).Msg("Failed to list documents")
 		utils.ErrorFromAppError(w, utils.ParseError(err))
@@ -59,36 +114,129 @@ func (h *DocumentHandler) ListDocuments(w http.ResponseWriter, r *http.Request)
 	// Exclude redaction schema from the response
 	responseDocs := make([]*models.DocumentSummary, len(docs))
 	for i, doc := range docs {
-		// The HashedDocumentName should already be decrypted by the service
-		responseDocs[i] = &models.DocumentSummary{
-			ID:              doc.ID,
-			HashedName:      doc.HashedDocumentName,
-			UploadTimestamp: doc.UploadTimestamp,
-			LastModified:    doc.LastModified,
-			EntityCount:     h.documentService.CalculateEntityCount(doc.RedactionSchema), // Placeholder for entity count
-		}
+		responseDocs[i] = h.toDocumentSummary(doc)
+	}
+	if utils.WantsXML(r) {
+		utils.PaginatedXML(w, constants.StatusOK, responseDocs, params.Page, params.PageSize, total)
+		return
+	}
+	utils.ListPage(w, r, responseDocs, params, total)
+}
+
+// listDocumentsAfter sends a page of the documents of a user paged by keyset cursors.
+func (h *DocumentHandler) listDocumentsAfter(w http.ResponseWriter, r *http.Request, userID int64, language string, params utils.PaginationParams) {
+	log.Info().Int64("user_id", userID).Int("page_size", params.PageSize).Str("language", language).Msg("Listing documents by cursor")
+	// Read one document more than the page holds to learn whether another page follows
+	docs, err := h.documentService.ListDocumentsPage(r.Context(), userID, language, params.After, params.PageSize+1)
+	if err != nil {
+		log.Error().Err(err).Int64("user_id", userID).Msg("Failed to list documents")
+		utils.ErrorFromAppError(w, utils.ParseError(err))
+		return
+	}
+
+	var next *utils.KeysetCursor
+	if len(docs) > params.PageSize {
+		docs = docs[:params.PageSize]
+		last := docs[len(docs)-1]
+		next = &utils.KeysetCursor{Timestamp: last.UploadTimestamp, ID: last.ID}
+	}
+	responseDocs := make([]*models.DocumentSummary, len(docs))
+	for i, doc := range docs {
+		responseDocs[i] = h.toDocumentSummary(doc)
+	}
+	utils.ListKeyset(w, r, responseDocs, next)
+}
+
+// toDocumentSummary converts a document to its list representation without the redaction schema.
+func (h *DocumentHandler) toDocumentSummary(doc *models.Document) *models.DocumentSummary {
+	// The HashedDocumentName should already be decrypted by the service
+	return &models.DocumentSummary{
+		ID:               doc.ID,
+		HashedName:       doc.HashedDocumentName,
+		UploadTimestamp:  doc.UploadTimestamp,
+		LastModified:     doc.LastModified,
+		EntityCount:      h.documentService.CalculateEntityCount(doc.RedactionSchema), // Placeholder for entity count
+		Language:         doc.Language,
+		Tags:             doc.Tags,
+		Folder:           doc.Folder,
+		RetainUntil:      doc.RetainUntil,
+		FilenameScrubbed: doc.FilenameScrubbed,
+		StorageTier:      models.StorageTierOf(doc.ArchivedAt),
+		ArchivedAt:       doc.ArchivedAt,
 	}
-	utils.Paginated(w, constants.StatusOK, responseDocs, params.Page, params.PageSize, total)
+}
+
+// uploadDocumentRequest is the metadata of an uploaded document, the JSON body of an upload
+// or the "metadata" field of a multipart upload.
+type uploadDocumentRequest struct {
+	Filename        string                  `json:"filename" validate:"required"`
+	Language        string                  `json:"language"`
+	Source          string                  `json:"source" validate:"omitempty,max=50"`
+	ProcessingMode  string                  `json:"processing_mode" validate:"omitempty,oneof=persistent ephemeral"`
+	RedactionSchema models.RedactionMapping `json:"redaction_schema" validate:"required"`
+	SHA256          string                  `json:"sha256" validate:"omitempty,len=64,hexadecimal"`
+	SizeBytes       int64                   `json:"size_bytes" validate:"omitempty,min=1"`
 }
 
 // UploadDocument handles POST /api/documents
+// The document language is taken from the optional "language" field, then from the
+// Content-Language header, and is otherwise detected by the service.
+// The optional "source" field names where the document came from, such as "scanner",
+// and is matched by the user's classification rules.
+// With "processing_mode": "ephemeral" the document is processed and returned without being
+// stored; nothing about it is logged, and only an audit stub with its counts is kept.
+// Uploads over the size, page or document limit are refused with the limit that was hit
+// and the current usage; the size is checked from Content-Length before the body is read.
+// A multipart/form-data upload carries the PDF itself in the "file" field, which is stored
+// encrypted with the document and read back from GET /api/documents/{id}/content.
+// Such uploads declare the "sha256" and "size_bytes" of the file; a file that doesn't match
+// them was corrupted on the way and is refused with upload_integrity_failed, and the
+// verified checksum is kept with the stored content.
 func (h *DocumentHandler) UploadDocument(w http.ResponseWriter, r *http.Request) {
 	userID, ok := auth.GetUserID(r)
 	if !ok {
 		utils.Unauthorized(w, constants.MsgAuthRequired)
 		return
 	}
-	var req struct {
-		Filename        string                  `json:"filename" validate:"required"`
-		RedactionSchema models.RedactionMapping `json:"redaction_schema" validate:"required"`
+	if err := h.documentService.CheckUploadSize(r.ContentLength); err != nil {
+		utils.ErrorFromAppError(w, utils.ParseError(err))
+		return
 	}
-	if err := utils.DecodeAndValidate(r, &req); err != nil {
+	var req uploadDocumentRequest
+	var content []byte
+	if mediaType, _, _ := mime.ParseMediaType(r.Header.Get(constants.HeaderContentType)); mediaType == "multipart/form-data" {
+		var err error
+		if content, err = h.readMultipartUpload(w, r, &req); err != nil {
+			utils.ErrorFromAppError(w, utils.ParseError(err))
+			return
+		}
+	} else if err := utils.DecodeAndValidate(r, &req); err != nil {
 		utils.BadRequest(w, "Invalid request data required filename and redaction schema", nil)
 		return
 	}
+	if req.Language == "" {
+		// Content-Language may list several languages; the first is the main one
+		req.Language, _, _ = strings.Cut(r.Header.Get(constants.HeaderContentLanguage), ",")
+	}
+	if req.ProcessingMode == constants.ProcessingModeEphemeral {
+		// The request body is not logged, as it holds the content; an uploaded file is not stored
+		result, err := h.documentService.ProcessEphemeral(r.Context(), userID, req.Filename, req.Language, req.Source, req.RedactionSchema)
+		if err != nil {
+			utils.ErrorFromAppError(w, utils.ParseError(err))
+			return
+		}
+		utils.JSON(w, constants.StatusOK, result)
+		return
+	}
 	log.Info().Interface("request_body", req).Msg("Received upload document request")
-	log.Info().Int64("user_id", userID).Str("filename", req.Filename).Msg("Uploading document")
-	doc, err := h.documentService.UploadDocument(userID, req.Filename, req.RedactionSchema)
+	log.Info().Int64("user_id", userID).Str("filename", req.Filename).Int("content_bytes", len(content)).Msg("Uploading document")
+	var doc *models.Document
+	var err error
+	if content != nil {
+		doc, err = h.documentService.UploadDocumentWithContent(r.Context(), userID, req.Filename, req.Language, req.Source, req.RedactionSchema, content)
+	} else {
+		doc, err = h.documentService.UploadDocument(r.Context(), userID, req.Filename, req.Language, req.Source, req.RedactionSchema)
+	}
 	if err != nil {
 		if errors.Is(err, service.ErrInvalidDocumentID) {
 			utils.BadRequest(w, "Invalid document ID", nil)
@@ -100,7 +248,151 @@ func (h *DocumentHandler) UploadDocument(w http.ResponseWriter, r *http.Request)
 	utils.JSON(w, constants.StatusCreated, doc)
 }
 
+// readMultipartUpload reads a multipart upload: the PDF from the "file" field, and the
+// metadata as JSON from the "metadata" field, or from the form fields of the same names.
+// Form fields take precedence over the metadata. The filename defaults to the name of the
+// uploaded file. The body is bounded by the upload size limit while it is read.
+func (h *DocumentHandler) readMultipartUpload(w http.ResponseWriter, r *http.Request, req *uploadDocumentRequest) ([]byte, error) {
+	maxBytes := h.documentService.MaxUploadBytes()
+	r.Body = http.MaxBytesReader(w, r.Body, maxBytes)
+	if err := r.ParseMultipartForm(constants.DocumentUploadMemoryLimit); err != nil {
+		var maxBytesError *http.MaxBytesError
+		if errors.As(err, &maxBytesError) {
+			// The body is over the limit, whether or not its length was announced
+			return nil, utils.NewUploadLimitError(constants.UploadLimitBytes, max(r.ContentLength, maxBytes+1), maxBytes)
+		}
+		return nil, utils.NewBadRequestError("Invalid multipart upload: " + err.Error())
+	}
+	defer r.MultipartForm.RemoveAll()
+
+	if metadata := r.FormValue(constants.DocumentMetadataFormField); metadata != "" {
+		dec := json.NewDecoder(strings.NewReader(metadata))
+		dec.DisallowUnknownFields()
+		if err := dec.Decode(req); err != nil {
+			return nil, utils.NewValidationError(constants.DocumentMetadataFormField, "Metadata must be a JSON object with the document fields")
+		}
+	}
+	if schema := r.FormValue("redaction_schema"); schema != "" {
+		if err := json.Unmarshal([]byte(schema), &req.RedactionSchema); err != nil {
+			return nil, utils.NewValidationError("redaction_schema", "Redaction schema must be a JSON object")
+		}
+	}
+	for field, value := range map[string]*string{
+		"filename":        &req.Filename,
+		"language":        &req.Language,
+		"source":          &req.Source,
+		"processing_mode": &req.ProcessingMode,
+		"sha256":          &req.SHA256,
+	} {
+		if formValue := r.FormValue(field); formValue != "" {
+			*value = formValue
+		}
+	}
+	if size := r.FormValue("size_bytes"); size != "" {
+		var err error
+		if req.SizeBytes, err = strconv.ParseInt(size, 10, 64); err != nil {
+			return nil, utils.NewValidationError("size_bytes", "Size must be a number of bytes")
+		}
+	}
+
+	file, header, err := r.FormFile(constants.DocumentContentFormField)
+	if err != nil {
+		return nil, utils.NewValidationError(constants.DocumentContentFormField, "The PDF file is required")
+	}
+	defer file.Close()
+	if req.Filename == "" {
+		req.Filename = header.Filename
+	}
+
+	if err := utils.ValidateStruct(req); err != nil {
+		return nil, err
+	}
+	if req.SHA256 == "" || req.SizeBytes == 0 {
+		return nil, utils.NewValidationError("sha256", constants.MsgUploadIntegrityRequired)
+	}
+	// The part header already tells a truncated file apart without reading it
+	if header.Size != req.SizeBytes {
+		return nil, utils.NewUploadIntegrityError(constants.UploadIntegritySize, req.SizeBytes, header.Size)
+	}
+
+	return readVerifiedUpload(file, req.SizeBytes, req.SHA256)
+}
+
+// readVerifiedUpload reads an uploaded file, hashing it while it is read, and checks it
+// against the size and hex-encoded SHA-256 its client declared.
+func readVerifiedUpload(file io.Reader, sizeBytes int64, checksum string) ([]byte, error) {
+	hash := sha256.New()
+	// Reading one byte past the declared size is enough to tell the file is longer
+	content, err := io.ReadAll(io.TeeReader(io.LimitReader(file, sizeBytes+1), hash))
+	if err != nil {
+		return nil, fmt.Errorf("failed to read uploaded file: %w", err)
+	}
+	if int64(len(content)) != sizeBytes {
+		return nil, utils.NewUploadIntegrityError(constants.UploadIntegritySize, sizeBytes, len(content))
+	}
+	if sum := hex.EncodeToString(hash.Sum(nil)); !strings.EqualFold(sum, checksum) {
+		return nil, utils.NewUploadIntegrityError(constants.UploadIntegritySHA256, strings.ToLower(checksum), sum)
+	}
+	return content, nil
+}
+
+// GetDocumentContent handles GET /api/documents/{id}/content
+// It streams the PDF uploaded with the document, decrypted. Range requests are answered
+// with the requested part, so viewers can load large documents page by page, and the
+// SHA-256 of the content serves as its ETag.
+func (h *DocumentHandler) GetDocumentContent(w http.ResponseWriter, r *http.Request) {
+	h.serveDocumentContent(w, r, "inline")
+}
+
+// DownloadDocument handles GET and HEAD /api/documents/{id}/download
+// It streams the PDF uploaded with the document as an attachment. The content is read
+// from the document storage as it is sent, so documents of any size are downloaded
+// without being held in memory. Range requests, with If-Range on the ETag, let
+// interrupted downloads resume where they stopped.
+func (h *DocumentHandler) DownloadDocument(w http.ResponseWriter, r *http.Request) {
+	h.serveDocumentContent(w, r, "attachment")
+}
+
+// serveDocumentContent streams the content of a document the user may read with the
+// given Content-Disposition type, answering range and conditional requests.
+func (h *DocumentHandler) serveDocumentContent(w http.ResponseWriter, r *http.Request, disposition string) {
+	userID, ok := auth.GetUserID(r)
+	if !ok {
+		utils.Unauthorized(w, constants.MsgAuthRequired)
+		return
+	}
+	id, err := strconv.ParseInt(chi.URLParam(r, constants.ParamID), 10, 64)
+	if err != nil {
+		utils.BadRequest(w, "Invalid document ID", nil)
+		return
+	}
+	stored, content, err := h.documentService.OpenDocumentContent(r.Context(), userID, id)
+	if err != nil {
+		if errors.Is(err, service.ErrDocumentNotFound) {
+			utils.NotFound(w, "Document not found")
+			return
+		}
+		utils.ErrorFromAppError(w, utils.ParseError(err))
+		return
+	}
+	defer content.Close()
+
+	log.Info().
+		Int64("user_id", userID).
+		Int64("document_id", id).
+		Str("method", r.Method).
+		Str("range", r.Header.Get(constants.HeaderRange)).
+		Str("disposition", disposition).
+		Msg("Reading document content")
+
+	w.Header().Set(constants.HeaderContentType, stored.ContentType)
+	w.Header().Set(constants.HeaderContentDisposition, fmt.Sprintf(`%s; filename="%s%d.pdf"`, disposition, constants.DocumentExportFilenamePrefix, id))
+	w.Header().Set(constants.HeaderETag, `"`+stored.SHA256+`"`)
+	http.ServeContent(w, r, "", stored.CreatedAt, content)
+}
+
 // GetDocumentByID handles GET /api/documents/{id}
+// Documents shared with the user are returned as well.
 func (h *DocumentHandler) GetDocumentByID(w http.ResponseWriter, r *http.Request) {
 	userID, ok := auth.GetUserID(r)
 	if !ok {
@@ -114,7 +406,7 @@ func (h *DocumentHandler) GetDocumentByID(w http.ResponseWriter, r *http.Request
 		return
 	}
 	log.Info().Int64("user_id", userID).Int64("document_id", id).Msg("Getting document by ID")
-	doc, err := h.documentService.GetDocumentByID(id)
+	doc, err := h.documentService.GetDocumentByID(r.Context(), userID, id)
 	if err != nil {
 		if errors.Is(err, service.ErrDocumentNotFound) {
 			utils.NotFound(w, "Document not found")
@@ -128,6 +420,7 @@ func (h *DocumentHandler) GetDocumentByID(w http.ResponseWriter, r *http.Request
 }
 
 // DeleteDocumentByID handles DELETE /api/documents/{id}
+// Only the owner can delete a document.
 func (h *DocumentHandler) DeleteDocumentByID(w http.ResponseWriter, r *http.Request) {
 	userID, ok := auth.GetUserID(r)
 	if !ok {
@@ -141,7 +434,7 @@ func (h *DocumentHandler) DeleteDocumentByID(w http.ResponseWriter, r *http.Requ
 		return
 	}
 	log.Info().Int64("user_id", userID).Int64("document_id", id).Msg("Deleting document by ID")
-	if err := h.documentService.DeleteDocumentByID(id); err != nil {
+	if err := h.documentService.DeleteDocumentByID(r.Context(), userID, id); err != nil {
 		if errors.Is(err, service.ErrDocumentNotFound) {
 			utils.NotFound(w, "Document not found")
 			return
@@ -166,7 +459,7 @@ func (h *DocumentHandler) GetDocumentSummary(w http.ResponseWriter, r *http.Requ
 		return
 	}
 	log.Info().Int64("user_id", userID).Int64("document_id", id).Msg("Getting document summary")
-	summary, err := h.documentService.GetDocumentSummary(id)
+	summary, err := h.documentService.GetDocumentSummary(r.Context(), userID, id)
 	if err != nil {
 		log.Error().Err(err).Int64("user_id", userID).Int64("document_id", id).Msg("Failed to get document summary")
 		utils.ErrorFromAppError(w, utils.ParseError(err))
@@ -174,3 +467,685 @@ func (h *DocumentHandler) GetDocumentSummary(w http.ResponseWriter, r *http.Requ
 	}
 	utils.JSON(w, constants.StatusOK, summary)
 }
+
+// UpdateRedactionSchema handles PUT /api/documents/{id}/redaction-schema
+// It replaces the redaction schema of a document the user owns or that was shared with
+// them for redaction. Users with read access get 403 Forbidden.
+func (h *DocumentHandler) UpdateRedactionSchema(w http.ResponseWriter, r *http.Request) {
+	userID, ok := auth.GetUserID(r)
+	if !ok {
+		utils.Unauthorized(w, constants.MsgAuthRequired)
+		return
+	}
+	idStr := chi.URLParam(r, "id")
+	id, err := strconv.ParseInt(idStr, 10, 64)
+	if err != nil {
+		utils.BadRequest(w, "Invalid document ID", nil)
+		return
+	}
+	var req models.RedactionSchemaUpdate
+	if err := utils.DecodeAndValidate(r, &req); err != nil {
+		utils.ErrorFromAppError(w, utils.ParseError(err))
+		return
+	}
+	log.Info().Int64("user_id", userID).Int64("document_id", id).Msg("Updating document redaction schema")
+	doc, err := h.documentService.UpdateRedactionSchema(r.Context(), userID, id, req.RedactionSchema)
+	if err != nil {
+		if errors.Is(err, service.ErrDocumentNotFound) {
+			utils.NotFound(w, "Document not found")
+			return
+		}
+		utils.ErrorFromAppError(w, utils.ParseError(err))
+		return
+	}
+	utils.JSON(w, constants.StatusOK, doc)
+}
+
+// ListSchemaVersions handles GET /api/documents/{id}/redaction-schema/versions
+// It returns the redaction schemas the document had before each save, newest first,
+// without the schemas themselves.
+func (h *DocumentHandler) ListSchemaVersions(w http.ResponseWriter, r *http.Request) {
+	userID, ok := auth.GetUserID(r)
+	if !ok {
+		utils.Unauthorized(w, constants.MsgAuthRequired)
+		return
+	}
+	idStr := chi.URLParam(r, "id")
+	id, err := strconv.ParseInt(idStr, 10, 64)
+	if err != nil {
+		utils.BadRequest(w, "Invalid document ID", nil)
+		return
+	}
+	versions, err := h.documentService.GetSchemaVersions(r.Context(), userID, id)
+	if err != nil {
+		if errors.Is(err, service.ErrDocumentNotFound) {
+			utils.NotFound(w, "Document not found")
+			return
+		}
+		log.Error().Err(err).Int64("user_id", userID).Int64("document_id", id).Msg("Failed to get redaction schema versions")
+		utils.ErrorFromAppError(w, utils.ParseError(err))
+		return
+	}
+	utils.ListAll(w, r, versions)
+}
+
+// RollbackRedactionSchema handles POST /api/documents/{id}/redaction-schema/versions/{version}/rollback
+// It restores an earlier redaction schema of a document the user owns or that was shared
+// with them for redaction; the schema it replaces becomes a new version.
+func (h *DocumentHandler) RollbackRedactionSchema(w http.ResponseWriter, r *http.Request) {
+	userID, ok := auth.GetUserID(r)
+	if !ok {
+		utils.Unauthorized(w, constants.MsgAuthRequired)
+		return
+	}
+	idStr := chi.URLParam(r, "id")
+	id, err := strconv.ParseInt(idStr, 10, 64)
+	if err != nil {
+		utils.BadRequest(w, "Invalid document ID", nil)
+		return
+	}
+	version, err := strconv.Atoi(chi.URLParam(r, "version"))
+	if err != nil || version < 1 {
+		utils.BadRequest(w, "Invalid version number", nil)
+		return
+	}
+	log.Info().Int64("user_id", userID).Int64("document_id", id).Int("version", version).Msg("Rolling back document redaction schema")
+	doc, err := h.documentService.RollbackRedactionSchema(r.Context(), userID, id, version)
+	if err != nil {
+		if errors.Is(err, service.ErrDocumentNotFound) {
+			utils.NotFound(w, "Document not found")
+			return
+		}
+		utils.ErrorFromAppError(w, utils.ParseError(err))
+		return
+	}
+	utils.JSON(w, constants.StatusOK, doc)
+}
+
+// AddDetectedEntities handles POST /api/documents/{id}/entities/batch
+// It adds up to constants.DetectedEntityBatchMax detected entities to the document at once.
+// Invalid entities are reported one by one in a multi-status response; the valid ones are
+// added in one transaction, so either all of them are added or none.
+func (h *DocumentHandler) AddDetectedEntities(w http.ResponseWriter, r *http.Request) {
+	userID, ok := auth.GetUserID(r)
+	if !ok {
+		utils.Unauthorized(w, constants.MsgAuthRequired)
+		return
+	}
+	idStr := chi.URLParam(r, "id")
+	id, err := strconv.ParseInt(idStr, 10, 64)
+	if err != nil {
+		utils.BadRequest(w, "Invalid document ID", nil)
+		return
+	}
+	var req models.DetectedEntityBatchRequest
+	if err := utils.DecodeAndValidate(r, &req); err != nil {
+		utils.ErrorFromAppError(w, utils.ParseError(err))
+		return
+	}
+
+	// Report invalid entities on their own instead of rejecting the batch
+	var batch utils.BatchInfo
+	valid := make([]models.DetectedEntityInput, 0, len(req.Entities))
+	indexes := make([]int, 0, len(req.Entities))
+	for i := range req.Entities {
+		if err := utils.ValidateStruct(&req.Entities[i]); err != nil {
+			batch.Fail(i, "", err)
+			continue
+		}
+		valid = append(valid, req.Entities[i])
+		indexes = append(indexes, i)
+	}
+	if len(valid) == 0 {
+		utils.MultiStatus(w, constants.StatusCreated, nil, &batch)
+		return
+	}
+
+	log.Info().Int64("user_id", userID).Int64("document_id", id).Int("count", len(valid)).Int("invalid", batch.Failed).Msg("Adding detected entities to document")
+	result, err := h.documentService.AddDetectedEntities(r.Context(), userID, id, valid)
+	if err != nil {
+		if errors.Is(err, service.ErrDocumentNotFound) {
+			utils.NotFound(w, "Document not found")
+			return
+		}
+		utils.ErrorFromAppError(w, utils.ParseError(err))
+		return
+	}
+	for i, entityID := range result.EntityIDs {
+		batch.Succeed(indexes[i], "", entityID, constants.StatusCreated)
+	}
+	utils.MultiStatus(w, constants.StatusCreated, result, &batch)
+}
+
+// ListDetectedEntities handles GET /api/documents/{id}/entities
+// It lists the detected entities of the document, newest first, optionally only those with
+// the review status in "status" or found by the detection method in "method_id".
+// Without pagination parameters all entities are listed; with "pagination=keyset", or the
+// next_cursor of such a page, they are paged by keyset cursors of "page_size" entities.
+func (h *DocumentHandler) ListDetectedEntities(w http.ResponseWriter, r *http.Request) {
+	userID, ok := auth.GetUserID(r)
+	if !ok {
+		utils.Unauthorized(w, constants.MsgAuthRequired)
+		return
+	}
+	idStr := chi.URLParam(r, "id")
+	id, err := strconv.ParseInt(idStr, 10, 64)
+	if err != nil {
+		utils.BadRequest(w, "Invalid document ID", nil)
+		return
+	}
+	var filter models.DetectedEntityFilter
+	if status := r.URL.Query().Get(constants.QueryParamStatus); status != "" {
+		filter.Status = models.EntityReviewStatus(status)
+		if !filter.Status.Valid() {
+			utils.BadRequest(w, constants.MsgEntityStatusInvalid, nil)
+			return
+		}
+	}
+	if methodID := r.URL.Query().Get(constants.QueryParamMethodID); methodID != "" {
+		filter.MethodID, err = strconv.ParseInt(methodID, 10, 64)
+		if err != nil || filter.MethodID < 1 {
+			utils.BadRequest(w, "Invalid detection method ID", nil)
+			return
+		}
+	}
+	params := utils.GetPaginationParams(r)
+	if params.Keyset {
+		// Read one entity more than the page holds to learn whether another page follows
+		filter.After = params.After
+		filter.Limit = params.PageSize + 1
+	}
+	entities, err := h.documentService.GetDetectedEntities(r.Context(), userID, id, filter)
+	if err != nil {
+		if errors.Is(err, service.ErrDocumentNotFound) {
+			utils.NotFound(w, "Document not found")
+			return
+		}
+		utils.ErrorFromAppError(w, utils.ParseError(err))
+		return
+	}
+	if !params.Keyset {
+		utils.ListAll(w, r, entities)
+		return
+	}
+
+	var next *utils.KeysetCursor
+	if len(entities) > params.PageSize {
+		entities = entities[:params.PageSize]
+		last := entities[len(entities)-1]
+		next = &utils.KeysetCursor{Timestamp: last.DetectedTimestamp, ID: last.ID}
+	}
+	utils.ListKeyset(w, r, entities, next)
+}
+
+// UpdateEntityStatus handles PATCH /api/documents/{id}/entities/{entityID}
+// It accepts or rejects a detected entity of the document, or sets it back to pending.
+func (h *DocumentHandler) UpdateEntityStatus(w http.ResponseWriter, r *http.Request) {
+	userID, ok := auth.GetUserID(r)
+	if !ok {
+		utils.Unauthorized(w, constants.MsgAuthRequired)
+		return
+	}
+	idStr := chi.URLParam(r, "id")
+	id, err := strconv.ParseInt(idStr, 10, 64)
+	if err != nil {
+		utils.BadRequest(w, "Invalid document ID", nil)
+		return
+	}
+	entityID, err := strconv.ParseInt(chi.URLParam(r, constants.ParamEntityID), 10, 64)
+	if err != nil {
+		utils.BadRequest(w, "Invalid entity ID", nil)
+		return
+	}
+	var req models.EntityStatusUpdate
+	if err := utils.DecodeAndValidate(r, &req); err != nil {
+		utils.ErrorFromAppError(w, utils.ParseError(err))
+		return
+	}
+	h.reviewEntities(w, r, userID, id, []int64{entityID}, req.Status)
+}
+
+// UpdateEntityStatuses handles PATCH /api/documents/{id}/entities
+// It sets the review status of up to 1000 detected entities of the document at once;
+// either all of them are changed or none.
+func (h *DocumentHandler) UpdateEntityStatuses(w http.ResponseWriter, r *http.Request) {
+	userID, ok := auth.GetUserID(r)
+	if !ok {
+		utils.Unauthorized(w, constants.MsgAuthRequired)
+		return
+	}
+	idStr := chi.URLParam(r, "id")
+	id, err := strconv.ParseInt(idStr, 10, 64)
+	if err != nil {
+		utils.BadRequest(w, "Invalid document ID", nil)
+		return
+	}
+	var req models.EntityStatusBulkUpdate
+	if err := utils.DecodeAndValidate(r, &req); err != nil {
+		utils.ErrorFromAppError(w, utils.ParseError(err))
+		return
+	}
+	h.reviewEntities(w, r, userID, id, req.EntityIDs, req.Status)
+}
+
+// reviewEntities sets the review status of detected entities and sends the result.
+func (h *DocumentHandler) reviewEntities(w http.ResponseWriter, r *http.Request, userID, documentID int64, entityIDs []int64, status models.EntityReviewStatus) {
+	log.Info().Int64("user_id", userID).Int64("document_id", documentID).Int("count", len(entityIDs)).Str("status", string(status)).Msg("Reviewing detected entities")
+	result, err := h.documentService.ReviewEntities(r.Context(), userID, documentID, entityIDs, status)
+	if err != nil {
+		if errors.Is(err, service.ErrDocumentNotFound) {
+			utils.NotFound(w, "Document not found")
+			return
+		}
+		utils.ErrorFromAppError(w, utils.ParseError(err))
+		return
+	}
+	utils.JSON(w, constants.StatusOK, result)
+}
+
+// SearchDocuments handles GET /api/documents/search
+// It returns a page of the user's documents, newest first, matching all given filters:
+// "name" (part of the name, case-insensitive), "uploaded_from" and "uploaded_to" (RFC 3339
+// timestamps or dates, both inclusive), "entity_types" (comma-separated, all must be
+// detected) and "min_entities" and "max_entities" (number of detections).
+func (h *DocumentHandler) SearchDocuments(w http.ResponseWriter, r *http.Request) {
+	userID, ok := auth.GetUserID(r)
+	if !ok {
+		utils.Unauthorized(w, constants.MsgAuthRequired)
+		return
+	}
+	filter, msg := parseDocumentSearchFilter(r)
+	if msg != "" {
+		utils.BadRequest(w, msg, nil)
+		return
+	}
+	params := utils.GetPaginationParams(r)
+	log.Info().Int64("user_id", userID).Int("page", params.Page).Int("page_size", params.PageSize).Strs("entity_types", filter.EntityTypes).Msg("Searching documents")
+	docs, total, err := h.documentService.SearchDocuments(r.Context(), userID, filter, params.Page, params.PageSize)
+	if err != nil {
+		log.Error().Err(err).Int64("user_id", userID).Msg("Failed to search documents")
+		utils.ErrorFromAppError(w, utils.ParseError(err))
+		return
+	}
+
+	// Exclude redaction schema from the response
+	responseDocs := make([]*models.DocumentSummary, len(docs))
+	for i, doc := range docs {
+		responseDocs[i] = h.toDocumentSummary(doc)
+	}
+	utils.ListPage(w, r, responseDocs, params, total)
+}
+
+// parseDocumentSearchFilter reads the document search filters from the query string,
+// returning a message describing the first invalid one.
+func parseDocumentSearchFilter(r *http.Request) (models.DocumentSearchFilter, string) {
+	query := r.URL.Query()
+	filter := models.DocumentSearchFilter{Name: strings.TrimSpace(query.Get(constants.QueryParamName))}
+
+	var ok bool
+	if filter.UploadedFrom, ok = parseSearchTime(query.Get(constants.QueryParamUploadedFrom), false); !ok {
+		return filter, "Invalid uploaded_from parameter"
+	}
+	if filter.UploadedTo, ok = parseSearchTime(query.Get(constants.QueryParamUploadedTo), true); !ok {
+		return filter, "Invalid uploaded_to parameter"
+	}
+	if filter.UploadedFrom != nil && filter.UploadedTo != nil && filter.UploadedTo.Before(*filter.UploadedFrom) {
+		return filter, constants.MsgInvalidSearchDateRange
+	}
+
+	if entityTypes := query.Get(constants.QueryParamEntityTypes); entityTypes != "" {
+		for _, entityType := range strings.Split(entityTypes, ",") {
+			if entityType = strings.TrimSpace(entityType); entityType != "" {
+				filter.EntityTypes = append(filter.EntityTypes, entityType)
+			}
+		}
+		if len(filter.EntityTypes) > constants.DocumentSearchMaxEntityTypes {
+			return filter, fmt.Sprintf("At most %d entity types can be searched for", constants.DocumentSearchMaxEntityTypes)
+		}
+	}
+
+	if filter.MinEntities, ok = parseSearchCount(query.Get(constants.QueryParamMinEntities)); !ok {
+		return filter, "Invalid min_entities parameter"
+	}
+	if filter.MaxEntities, ok = parseSearchCount(query.Get(constants.QueryParamMaxEntities)); !ok {
+		return filter, "Invalid max_entities parameter"
+	}
+	if filter.MinEntities != nil && filter.MaxEntities != nil && *filter.MaxEntities < *filter.MinEntities {
+		return filter, constants.MsgInvalidSearchEntityRange
+	}
+
+	return filter, ""
+}
+
+// parseSearchTime parses an RFC 3339 timestamp or a date. A date ending a range is
+// turned into the start of the next day, so the whole day is included.
+func parseSearchTime(value string, end bool) (*time.Time, bool) {
+	if value == "" {
+		return nil, true
+	}
+	if parsed, err := time.Parse(time.RFC3339, value); err == nil {
+		if end {
+			// The repository excludes the end of the range; timestamps are stored in microseconds
+			parsed = parsed.Add(time.Microsecond)
+		}
+		return &parsed, true
+	}
+	parsed, err := time.Parse(time.DateOnly, value)
+	if err != nil {
+		return nil, false
+	}
+	if end {
+		parsed = parsed.AddDate(0, 0, 1)
+	}
+	return &parsed, true
+}
+
+// parseSearchCount parses a non-negative entity count.
+func parseSearchCount(value string) (*int, bool) {
+	if value == "" {
+		return nil, true
+	}
+	count, err := strconv.Atoi(value)
+	if err != nil || count < 0 {
+		return nil, false
+	}
+	return &count, true
+}
+
+// GetDocumentTimeline handles GET /api/documents/{id}/timeline
+// It returns a paginated, chronological feed of what happened to the document.
+func (h *DocumentHandler) GetDocumentTimeline(w http.ResponseWriter, r *http.Request) {
+	userID, ok := auth.GetUserID(r)
+	if !ok {
+		utils.Unauthorized(w, constants.MsgAuthRequired)
+		return
+	}
+	idStr := chi.URLParam(r, "id")
+	id, err := strconv.ParseInt(idStr, 10, 64)
+	if err != nil {
+		utils.BadRequest(w, "Invalid document ID", nil)
+		return
+	}
+	params := utils.GetPaginationParams(r)
+	log.Info().Int64("user_id", userID).Int64("document_id", id).Int("page", params.Page).Msg("Getting document timeline")
+	events, total, err := h.documentService.GetDocumentTimeline(r.Context(), userID, id, params.Page, params.PageSize)
+	if err != nil {
+		if errors.Is(err, service.ErrDocumentNotFound) {
+			utils.NotFound(w, "Document not found")
+			return
+		}
+		log.Error().Err(err).Int64("user_id", userID).Int64("document_id", id).Msg("Failed to get document timeline")
+		utils.ErrorFromAppError(w, utils.ParseError(err))
+		return
+	}
+	utils.ListPage(w, r, events, params, total)
+}
+
+// RestoreFromArchive handles POST /api/documents/{id}/restore-from-archive
+// It moves an archived document back to hot storage and returns it with its redaction schema.
+func (h *DocumentHandler) RestoreFromArchive(w http.ResponseWriter, r *http.Request) {
+	userID, ok := auth.GetUserID(r)
+	if !ok {
+		utils.Unauthorized(w, constants.MsgAuthRequired)
+		return
+	}
+	idStr := chi.URLParam(r, "id")
+	id, err := strconv.ParseInt(idStr, 10, 64)
+	if err != nil {
+		utils.BadRequest(w, "Invalid document ID", nil)
+		return
+	}
+	log.Info().Int64("user_id", userID).Int64("document_id", id).Msg("Restoring document from archive")
+	doc, err := h.documentService.RestoreFromArchive(r.Context(), userID, id)
+	if err != nil {
+		if errors.Is(err, service.ErrDocumentNotFound) {
+			utils.NotFound(w, "Document not found")
+			return
+		}
+		log.Error().Err(err).Int64("user_id", userID).Int64("document_id", id).Msg("Failed to restore document from archive")
+		utils.ErrorFromAppError(w, utils.ParseError(err))
+		return
+	}
+	utils.JSON(w, constants.StatusOK, doc)
+}
+
+// UpdateLifecycle handles PUT /api/documents/{id}/lifecycle
+// It exempts a document from the cleanup of stale documents, or makes it subject to it again.
+func (h *DocumentHandler) UpdateLifecycle(w http.ResponseWriter, r *http.Request) {
+	userID, ok := auth.GetUserID(r)
+	if !ok {
+		utils.Unauthorized(w, constants.MsgAuthRequired)
+		return
+	}
+	idStr := chi.URLParam(r, "id")
+	id, err := strconv.ParseInt(idStr, 10, 64)
+	if err != nil {
+		utils.BadRequest(w, "Invalid document ID", nil)
+		return
+	}
+	var req models.DocumentLifecycleRequest
+	if err := utils.DecodeAndValidate(r, &req); err != nil {
+		utils.ErrorFromAppError(w, utils.ParseError(err))
+		return
+	}
+	lifecycle, err := h.documentService.SetLifecycleExempt(r.Context(), userID, id, *req.Exempt)
+	if err != nil {
+		if errors.Is(err, service.ErrDocumentNotFound) {
+			utils.NotFound(w, "Document not found")
+			return
+		}
+		log.Error().Err(err).Int64("user_id", userID).Int64("document_id", id).Msg("Failed to update document lifecycle")
+		utils.ErrorFromAppError(w, utils.ParseError(err))
+		return
+	}
+	utils.JSON(w, constants.StatusOK, lifecycle)
+}
+
+// PreviewRetention handles GET /api/documents/retention-preview
+// It lists the user's documents the retention maintenance task would delete with their
+// detected entities, without deleting anything. "retention_days" previews another document
+// retention setting before it is saved; without it the current setting is previewed.
+func (h *DocumentHandler) PreviewRetention(w http.ResponseWriter, r *http.Request) {
+	userID, ok := auth.GetUserID(r)
+	if !ok {
+		utils.Unauthorized(w, constants.MsgAuthRequired)
+		return
+	}
+	var retentionDays *int
+	if value := r.URL.Query().Get(constants.QueryParamRetentionDays); value != "" {
+		days, err := strconv.Atoi(value)
+		if err != nil || days < 0 || days > 3650 {
+			utils.BadRequest(w, "Invalid retention_days parameter", nil)
+			return
+		}
+		retentionDays = &days
+	}
+	preview, err := h.documentService.PreviewRetention(r.Context(), userID, retentionDays)
+	if err != nil {
+		log.Error().Err(err).Int64("user_id", userID).Msg("Failed to preview document retention")
+		utils.ErrorFromAppError(w, utils.ParseError(err))
+		return
+	}
+	utils.JSON(w, constants.StatusOK, preview)
+}
+
+// GetPageOverlay handles GET /api/documents/{id}/pages/{n}/overlay
+// It returns the redaction rectangles of page n in page-relative coordinates with their
+// labels and colors, so viewers can draw them without reading the redaction schema.
+func (h *DocumentHandler) GetPageOverlay(w http.ResponseWriter, r *http.Request) {
+	userID, ok := auth.GetUserID(r)
+	if !ok {
+		utils.Unauthorized(w, constants.MsgAuthRequired)
+		return
+	}
+	idStr := chi.URLParam(r, "id")
+	id, err := strconv.ParseInt(idStr, 10, 64)
+	if err != nil {
+		utils.BadRequest(w, "Invalid document ID", nil)
+		return
+	}
+	page, err := strconv.Atoi(chi.URLParam(r, "n"))
+	if err != nil || page < 1 {
+		utils.BadRequest(w, "Invalid page number", nil)
+		return
+	}
+	overlay, err := h.documentService.GetPageOverlay(r.Context(), userID, id, page)
+	if err != nil {
+		if errors.Is(err, service.ErrDocumentNotFound) {
+			utils.NotFound(w, "Document not found")
+			return
+		}
+		log.Error().Err(err).Int64("user_id", userID).Int64("document_id", id).Int("page", page).Msg("Failed to get page overlay")
+		utils.ErrorFromAppError(w, utils.ParseError(err))
+		return
+	}
+	utils.JSON(w, constants.StatusOK, overlay)
+}
+
+// ExportDocument handles GET /api/documents/{id}/export
+// It bundles the document's metadata, redaction schema, detected entities and timeline
+// into one downloadable file. The optional "format" query parameter selects a single
+// JSON file (default) or a ZIP archive with one JSON file per section, and the optional
+// "locale" query parameter the regional formatting of the export.
+func (h *DocumentHandler) ExportDocument(w http.ResponseWriter, r *http.Request) {
+	userID, ok := auth.GetUserID(r)
+	if !ok {
+		utils.Unauthorized(w, constants.MsgAuthRequired)
+		return
+	}
+	idStr := chi.URLParam(r, "id")
+	id, err := strconv.ParseInt(idStr, 10, 64)
+	if err != nil {
+		utils.BadRequest(w, "Invalid document ID", nil)
+		return
+	}
+	format := strings.ToLower(r.URL.Query().Get(constants.QueryParamFormat))
+	if format == "" {
+		format = constants.DocumentExportFormatJSON
+	}
+	if format != constants.DocumentExportFormatJSON && format != constants.DocumentExportFormatZIP {
+		utils.BadRequest(w, constants.MsgInvalidExportFormat, nil)
+		return
+	}
+	locale := models.Locale(r.URL.Query().Get(constants.QueryParamLocale))
+	if !locale.IsSupported() {
+		utils.BadRequest(w, "Unsupported locale parameter", nil)
+		return
+	}
+	log.Info().Int64("user_id", userID).Int64("document_id", id).Str("format", format).Str("locale", string(locale)).Msg("Exporting document")
+	export, err := h.documentService.ExportDocument(r.Context(), userID, id, locale)
+	if err != nil {
+		if errors.Is(err, service.ErrDocumentNotFound) {
+			utils.NotFound(w, "Document not found")
+			return
+		}
+		log.Error().Err(err).Int64("user_id", userID).Int64("document_id", id).Msg("Failed to export document")
+		utils.ErrorFromAppError(w, utils.ParseError(err))
+		return
+	}
+
+	filename := fmt.Sprintf("%s%d.%s", constants.DocumentExportFilenamePrefix, id, format)
+	if format == constants.DocumentExportFormatJSON {
+		utils.JsonFile(w, export, filename)
+		return
+	}
+
+	// Build the archive before sending headers so failures can still be reported
+	var buf bytes.Buffer
+	if err := export.WriteZIP(&buf); err != nil {
+		utils.ErrorFromAppError(w, utils.ParseError(err))
+		return
+	}
+	w.Header().Set(constants.HeaderContentType, constants.ContentTypeZIP)
+	w.Header().Set(constants.HeaderContentDisposition, fmt.Sprintf("attachment; filename=%s", filename))
+	w.Header().Set(constants.HeaderCacheControl, constants.CacheControlNoStore)
+	w.WriteHeader(constants.StatusOK)
+	if _, err := w.Write(buf.Bytes()); err != nil {
+		log.Error().Err(err).Msg("Failed to write document export")
+	}
+}
+
+// RedactDocument handles POST /api/documents/{id}/redact
+// It applies the document's redaction schema to its PDF on the server and returns the
+// redacted PDF, with the redacted text, images and annotations removed from the file.
+// The PDF can be uploaded as the "file" field of a multipart form; otherwise the content
+// stored with the document is redacted. The optional "method" query parameter sets the
+// method of redactions whose entity has none: blackout (default), mask or replace.
+func (h *DocumentHandler) RedactDocument(w http.ResponseWriter, r *http.Request) {
+	userID, ok := auth.GetUserID(r)
+	if !ok {
+		utils.Unauthorized(w, constants.MsgAuthRequired)
+		return
+	}
+	id, err := strconv.ParseInt(chi.URLParam(r, constants.ParamID), 10, 64)
+	if err != nil {
+		utils.BadRequest(w, "Invalid document ID", nil)
+		return
+	}
+	method := strings.ToLower(r.URL.Query().Get(constants.QueryParamRedactionMethod))
+	switch method {
+	case "":
+		method = constants.RedactionMethodBlackout
+	case constants.RedactionMethodBlackout, constants.RedactionMethodMask, constants.RedactionMethodReplace:
+	default:
+		utils.BadRequest(w, constants.MsgInvalidRedactionMethod, nil)
+		return
+	}
+
+	var content []byte
+	if mediaType, _, _ := mime.ParseMediaType(r.Header.Get(constants.HeaderContentType)); mediaType == "multipart/form-data" {
+		if content, err = h.readRedactionUpload(w, r); err != nil {
+			utils.ErrorFromAppError(w, utils.ParseError(err))
+			return
+		}
+	}
+
+	log.Info().Int64("user_id", userID).Int64("document_id", id).Str("method", method).Bool("uploaded", content != nil).Msg("Redacting document")
+	result, err := h.documentService.RedactDocument(r.Context(), userID, id, method, content)
+	if err != nil {
+		if errors.Is(err, service.ErrDocumentNotFound) {
+			utils.NotFound(w, "Document not found")
+			return
+		}
+		log.Error().Err(err).Int64("user_id", userID).Int64("document_id", id).Msg("Failed to redact document")
+		utils.ErrorFromAppError(w, utils.ParseError(err))
+		return
+	}
+
+	w.Header().Set(constants.HeaderContentType, constants.ContentTypePDF)
+	w.Header().Set(constants.HeaderContentDisposition, fmt.Sprintf("attachment; filename=%s%d.pdf", constants.RedactedFilenamePrefix, id))
+	w.Header().Set(constants.HeaderCacheControl, constants.CacheControlNoStore)
+	w.WriteHeader(constants.StatusOK)
+	if _, err := w.Write(result.PDF); err != nil {
+		log.Error().Err(err).Msg("Failed to write redacted document")
+	}
+}
+
+// readRedactionUpload reads the PDF to redact from the "file" field of a multipart form.
+// The body is bounded by the upload size limit while it is read.
+func (h *DocumentHandler) readRedactionUpload(w http.ResponseWriter, r *http.Request) ([]byte, error) {
+	if err := h.documentService.CheckUploadSize(r.ContentLength); err != nil {
+		return nil, err
+	}
+	maxBytes := h.documentService.MaxUploadBytes()
+	r.Body = http.MaxBytesReader(w, r.Body, maxBytes)
+	if err := r.ParseMultipartForm(constants.DocumentUploadMemoryLimit); err != nil {
+		var maxBytesError *http.MaxBytesError
+		if errors.As(err, &maxBytesError) {
+			return nil, utils.NewUploadLimitError(constants.UploadLimitBytes, max(r.ContentLength, maxBytes+1), maxBytes)
+		}
+		return nil, utils.NewBadRequestError("Invalid multipart upload: " + err.Error())
+	}
+	defer r.MultipartForm.RemoveAll()
+
+	file, _, err := r.FormFile(constants.DocumentContentFormField)
+	if err != nil {
+		return nil, utils.NewValidationError(constants.DocumentContentFormField, "The PDF file is required")
+	}
+	defer file.Close()
+	content, err := io.ReadAll(file)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read uploaded file: %w", err)
+	}
+	return content, nil
+}
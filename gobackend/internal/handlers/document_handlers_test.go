@@ -1,23 +1,33 @@
 package handlers
 
 import (
+	"archive/zip"
 	"bytes"
 	"context"
+	"crypto/sha256"
+	"encoding/hex"
 	"encoding/json"
 	"errors"
+	"io"
+	"mime/multipart"
 	"net/http"
 	"net/http/httptest"
 	"strconv"
+	"strings"
 	"testing"
 	"time"
 
 	"github.com/go-chi/chi/v5"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
 	"github.com/yasinhessnawi1/Hideme_Backend/internal/auth"
 	"github.com/yasinhessnawi1/Hideme_Backend/internal/constants"
 	"github.com/yasinhessnawi1/Hideme_Backend/internal/models"
+	"github.com/yasinhessnawi1/Hideme_Backend/internal/redaction"
 	"github.com/yasinhessnawi1/Hideme_Backend/internal/service"
+	"github.com/yasinhessnawi1/Hideme_Backend/internal/testsupport/fixtures"
+	"github.com/yasinhessnawi1/Hideme_Backend/internal/testsupport/golden"
 	"github.com/yasinhessnawi1/Hideme_Backend/internal/utils"
 )
 
@@ -26,51 +36,230 @@ type MockDocumentService struct {
 	mock.Mock
 }
 
-func (m *MockDocumentService) ListDocuments(userID int64, page, pageSize int) ([]*models.Document, int, error) {
-	args := m.Called(userID, page, pageSize)
+func (m *MockDocumentService) ListDocuments(ctx context.Context, userID int64, language string, page, pageSize int) ([]*models.Document, int, error) {
+	args := m.Called(ctx, userID, language, page, pageSize)
 	if args.Get(0) == nil {
 		return nil, args.Int(1), args.Error(2)
 	}
 	return args.Get(0).([]*models.Document), args.Int(1), args.Error(2)
 }
 
-func (m *MockDocumentService) UploadDocument(userID int64, filename string, redactionSchema models.RedactionMapping) (*models.Document, error) {
-	args := m.Called(userID, filename, redactionSchema)
+func (m *MockDocumentService) ListDocumentsPage(ctx context.Context, userID int64, language string, after *utils.KeysetCursor, limit int) ([]*models.Document, error) {
+	args := m.Called(ctx, userID, language, after, limit)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]*models.Document), args.Error(1)
+}
+
+func (m *MockDocumentService) StreamDocuments(ctx context.Context, userID int64, language string, fn func(*models.Document) error) error {
+	args := m.Called(ctx, userID, language)
+	if docs, ok := args.Get(0).([]*models.Document); ok {
+		for _, doc := range docs {
+			if err := fn(doc); err != nil {
+				return err
+			}
+		}
+	}
+	return args.Error(1)
+}
+
+func (m *MockDocumentService) SearchDocuments(ctx context.Context, userID int64, filter models.DocumentSearchFilter, page, pageSize int) ([]*models.Document, int, error) {
+	args := m.Called(ctx, userID, filter, page, pageSize)
+	if args.Get(0) == nil {
+		return nil, args.Int(1), args.Error(2)
+	}
+	return args.Get(0).([]*models.Document), args.Int(1), args.Error(2)
+}
+
+func (m *MockDocumentService) UploadDocument(ctx context.Context, userID int64, filename, language, source string, redactionSchema models.RedactionMapping) (*models.Document, error) {
+	args := m.Called(ctx, userID, filename, language, source, redactionSchema)
 	if args.Get(0) == nil {
 		return nil, args.Error(1)
 	}
 	return args.Get(0).(*models.Document), args.Error(1)
 }
 
-func (m *MockDocumentService) GetDocumentByID(id int64) (*models.Document, error) {
-	args := m.Called(id)
+func (m *MockDocumentService) ProcessEphemeral(ctx context.Context, userID int64, filename, language, source string, redactionSchema models.RedactionMapping) (*models.EphemeralDocument, error) {
+	args := m.Called(ctx, userID, filename, language, source, redactionSchema)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*models.EphemeralDocument), args.Error(1)
+}
+
+func (m *MockDocumentService) GetDocumentByID(ctx context.Context, userID, id int64) (*models.Document, error) {
+	args := m.Called(ctx, userID, id)
 	if args.Get(0) == nil {
 		return nil, args.Error(1)
 	}
 	return args.Get(0).(*models.Document), args.Error(1)
 }
 
-func (m *MockDocumentService) DeleteDocumentByID(id int64) error {
-	args := m.Called(id)
+func (m *MockDocumentService) DeleteDocumentByID(ctx context.Context, userID, id int64) error {
+	args := m.Called(ctx, userID, id)
 	return args.Error(0)
 }
 
-func (m *MockDocumentService) GetDocumentSummary(id int64) (*models.DocumentSummary, error) {
-	args := m.Called(id)
+func (m *MockDocumentService) GetDocumentSummary(ctx context.Context, userID, id int64) (*models.DocumentSummary, error) {
+	args := m.Called(ctx, userID, id)
 	if args.Get(0) == nil {
 		return nil, args.Error(1)
 	}
 	return args.Get(0).(*models.DocumentSummary), args.Error(1)
 }
 
+func (m *MockDocumentService) UpdateRedactionSchema(ctx context.Context, userID, documentID int64, redactionSchema models.RedactionMapping) (*models.Document, error) {
+	args := m.Called(ctx, userID, documentID, redactionSchema)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*models.Document), args.Error(1)
+}
+
+func (m *MockDocumentService) GetSchemaVersions(ctx context.Context, userID, documentID int64) ([]*models.DocumentSchemaVersion, error) {
+	args := m.Called(ctx, userID, documentID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]*models.DocumentSchemaVersion), args.Error(1)
+}
+
+func (m *MockDocumentService) RollbackRedactionSchema(ctx context.Context, userID, documentID int64, version int) (*models.Document, error) {
+	args := m.Called(ctx, userID, documentID, version)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*models.Document), args.Error(1)
+}
+
+func (m *MockDocumentService) AddDetectedEntities(ctx context.Context, userID, documentID int64, inputs []models.DetectedEntityInput) (*models.DetectedEntityBatchResult, error) {
+	args := m.Called(ctx, userID, documentID, inputs)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*models.DetectedEntityBatchResult), args.Error(1)
+}
+
+func (m *MockDocumentService) GetDetectedEntities(ctx context.Context, userID, documentID int64, filter models.DetectedEntityFilter) ([]*models.DetectedEntityWithMethod, error) {
+	args := m.Called(ctx, userID, documentID, filter)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]*models.DetectedEntityWithMethod), args.Error(1)
+}
+
+func (m *MockDocumentService) ReviewEntities(ctx context.Context, userID, documentID int64, entityIDs []int64, status models.EntityReviewStatus) (*models.EntityReviewResult, error) {
+	args := m.Called(ctx, userID, documentID, entityIDs, status)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*models.EntityReviewResult), args.Error(1)
+}
+
+func (m *MockDocumentService) GetDocumentTimeline(ctx context.Context, userID, documentID int64, page, pageSize int) ([]*models.DocumentEvent, int, error) {
+	args := m.Called(ctx, userID, documentID, page, pageSize)
+	if args.Get(0) == nil {
+		return nil, args.Int(1), args.Error(2)
+	}
+	return args.Get(0).([]*models.DocumentEvent), args.Int(1), args.Error(2)
+}
+
+func (m *MockDocumentService) ExportDocument(ctx context.Context, userID, documentID int64, locale models.Locale) (*models.DocumentExport, error) {
+	args := m.Called(ctx, userID, documentID, locale)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*models.DocumentExport), args.Error(1)
+}
+
+func (m *MockDocumentService) RestoreFromArchive(ctx context.Context, userID, documentID int64) (*models.Document, error) {
+	args := m.Called(ctx, userID, documentID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*models.Document), args.Error(1)
+}
+
+func (m *MockDocumentService) SetLifecycleExempt(ctx context.Context, userID, documentID int64, exempt bool) (*models.DocumentLifecycle, error) {
+	args := m.Called(ctx, userID, documentID, exempt)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*models.DocumentLifecycle), args.Error(1)
+}
+
+func (m *MockDocumentService) PreviewRetention(ctx context.Context, userID int64, retentionDays *int) (*models.RetentionPreview, error) {
+	args := m.Called(ctx, userID, retentionDays)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*models.RetentionPreview), args.Error(1)
+}
+
+func (m *MockDocumentService) GetPageOverlay(ctx context.Context, userID, documentID int64, page int) (*models.PageOverlay, error) {
+	args := m.Called(ctx, userID, documentID, page)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*models.PageOverlay), args.Error(1)
+}
+
+func (m *MockDocumentService) RedactDocument(ctx context.Context, userID, documentID int64, method string, content []byte) (*redaction.Result, error) {
+	args := m.Called(ctx, userID, documentID, method, content)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*redaction.Result), args.Error(1)
+}
+
 func (m *MockDocumentService) CalculateEntityCount(redactionSchema string) int {
 	args := m.Called(redactionSchema)
 	return args.Int(0)
 }
 
+func (m *MockDocumentService) CheckUploadSize(size int64) error {
+	args := m.Called(size)
+	return args.Error(0)
+}
+
+func (m *MockDocumentService) UploadDocumentWithContent(ctx context.Context, userID int64, filename, language, source string, redactionSchema models.RedactionMapping, content []byte) (*models.Document, error) {
+	args := m.Called(ctx, userID, filename, language, source, redactionSchema, content)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*models.Document), args.Error(1)
+}
+
+func (m *MockDocumentService) OpenDocumentContent(ctx context.Context, userID, documentID int64) (*models.DocumentContent, io.ReadSeekCloser, error) {
+	args := m.Called(ctx, userID, documentID)
+	if args.Get(0) == nil {
+		return nil, nil, args.Error(2)
+	}
+	return args.Get(0).(*models.DocumentContent), args.Get(1).(io.ReadSeekCloser), args.Error(2)
+}
+
+// closingReader is document content that records whether it was closed
+type closingReader struct {
+	*bytes.Reader
+	closed bool
+}
+
+func (c *closingReader) Close() error {
+	c.closed = true
+	return nil
+}
+
+func (m *MockDocumentService) MaxUploadBytes() int64 {
+	args := m.Called()
+	return args.Get(0).(int64)
+}
+
 // Test helpers
 func setupDocumentTest(t *testing.T) (*DocumentHandler, *MockDocumentService) {
 	mockService := new(MockDocumentService)
+	mockService.On("CheckUploadSize", mock.Anything).Return(nil).Maybe()
+	mockService.On("MaxUploadBytes").Return(int64(1 << 20)).Maybe()
 	handler := NewDocumentHandler(mockService)
 	return handler, mockService
 }
@@ -99,59 +288,62 @@ func TestListDocuments(t *testing.T) {
 
 		userID := int64(123)
 		req := httptest.NewRequest(http.MethodGet, "/api/documents?page=1&page_size=10", nil)
+		req.Header.Set(constants.HeaderXAPIVersion, "2")
 		req = req.WithContext(createDocumentAuthContext(userID))
 
 		rr := httptest.NewRecorder()
 
 		// Create test documents
-		testTime := time.Now()
-		mockDocs := []*models.Document{
-			{
-				ID:                 1,
-				UserID:             userID,
-				HashedDocumentName: "test-doc-1",
-				UploadTimestamp:    testTime,
-				LastModified:       testTime,
-				RedactionSchema:    "schema1",
-			},
-			{
-				ID:                 2,
-				UserID:             userID,
-				HashedDocumentName: "test-doc-2",
-				UploadTimestamp:    testTime,
-				LastModified:       testTime,
-				RedactionSchema:    "schema2",
-			},
-		}
+		second := fixtures.Document(2, userID)
+		second.HashedDocumentName = "invoice.pdf"
+		second.RedactionSchema = "schema2"
+		mockDocs := []*models.Document{fixtures.Document(1, userID), second}
 
-		totalDocs := 2
-
-		mockService.On("ListDocuments", userID, 1, 10).Return(mockDocs, totalDocs, nil)
-		mockService.On("CalculateEntityCount", "schema1").Return(5)
+		mockService.On("ListDocuments", mock.Anything, userID, "", 1, 10).Return(mockDocs, len(mockDocs), nil)
+		mockService.On("CalculateEntityCount", fixtures.RedactionSchema).Return(1)
 		mockService.On("CalculateEntityCount", "schema2").Return(3)
 
 		// Act
 		handler.ListDocuments(rr, req)
 
 		// Assert
-		assert.Equal(t, http.StatusOK, rr.Code)
-
-		var response utils.Response
-		err := json.Unmarshal(rr.Body.Bytes(), &response)
-		assert.NoError(t, err)
-
-		// Check response structure
-		assert.True(t, response.Success)
-		assert.NotNil(t, response.Data)
-		assert.NotNil(t, response.Meta)
-		assert.Equal(t, 1, response.Meta.Page)
-		assert.Equal(t, 10, response.Meta.PageSize)
-		assert.Equal(t, totalDocs, response.Meta.TotalItems)
+		golden.AssertResponse(t, rr)
 
 		// Verify the mock expectations
 		mockService.AssertExpectations(t)
 	})
 
+	t.Run("Clients pinned to API version 1 get page metadata", func(t *testing.T) {
+		handler, mockService := setupDocumentTest(t)
+		mockService.On("ListDocuments", mock.Anything, int64(123), "", 1, 1).Return([]*models.Document{fixtures.Document(1, 123)}, 2, nil)
+		mockService.On("CalculateEntityCount", fixtures.RedactionSchema).Return(1)
+
+		req := httptest.NewRequest(http.MethodGet, "/api/documents?page=1&page_size=1", nil)
+		req.Header.Set(constants.HeaderXAPIVersion, "1")
+		req = req.WithContext(createDocumentAuthContext(123))
+		rr := httptest.NewRecorder()
+		handler.ListDocuments(rr, req)
+
+		golden.AssertResponse(t, rr)
+		assert.Equal(t, "true", rr.Header().Get(constants.HeaderDeprecation))
+	})
+
+	t.Run("Clients without an API version get page metadata", func(t *testing.T) {
+		handler, mockService := setupDocumentTest(t)
+		mockService.On("ListDocuments", mock.Anything, int64(123), "", 1, 1).Return([]*models.Document{fixtures.Document(1, 123)}, 2, nil)
+		mockService.On("CalculateEntityCount", fixtures.RedactionSchema).Return(1)
+
+		req := httptest.NewRequest(http.MethodGet, "/api/documents?page=1&page_size=1", nil)
+		req = req.WithContext(createDocumentAuthContext(123))
+		rr := httptest.NewRecorder()
+		handler.ListDocuments(rr, req)
+
+		// The response is the one clients received before the list envelope
+		golden.AssertResponse(t, rr)
+		assert.Equal(t, "1", rr.Header().Get(constants.HeaderXAPIVersion))
+		assert.Equal(t, "true", rr.Header().Get(constants.HeaderDeprecation))
+	})
+
 	t.Run("Unauthorized access", func(t *testing.T) {
 		// Arrange
 		handler, _ := setupDocumentTest(t)
@@ -165,14 +357,7 @@ func TestListDocuments(t *testing.T) {
 		handler.ListDocuments(rr, req)
 
 		// Assert
-		assert.Equal(t, http.StatusUnauthorized, rr.Code)
-
-		var response utils.Response
-		err := json.Unmarshal(rr.Body.Bytes(), &response)
-		assert.NoError(t, err)
-
-		assert.False(t, response.Success)
-		assert.Equal(t, constants.MsgAuthRequired, response.Error.Message)
+		golden.AssertResponse(t, rr)
 	})
 
 	t.Run("Service error", func(t *testing.T) {
@@ -186,27 +371,204 @@ func TestListDocuments(t *testing.T) {
 		rr := httptest.NewRecorder()
 
 		serviceErr := errors.New("database error")
-		mockService.On("ListDocuments", userID, 1, 10).Return(nil, 0, serviceErr)
+		mockService.On("ListDocuments", mock.Anything, userID, "", 1, 10).Return(nil, 0, serviceErr)
+
+		// Act
+		handler.ListDocuments(rr, req)
+
+		// Assert
+		golden.AssertResponse(t, rr)
+
+		// Verify the mock expectations
+		mockService.AssertExpectations(t)
+	})
+
+	t.Run("Language filter", func(t *testing.T) {
+		// Arrange
+		handler, mockService := setupDocumentTest(t)
+
+		userID := int64(123)
+		req := httptest.NewRequest(http.MethodGet, "/api/documents?page=1&page_size=10&language=nb-NO", nil)
+		req = req.WithContext(createDocumentAuthContext(userID))
+
+		rr := httptest.NewRecorder()
+
+		doc := fixtures.Document(1, userID)
+		doc.HashedDocumentName = "arbeidsavtale.pdf"
+		doc.Language = "nb"
+
+		mockService.On("ListDocuments", mock.Anything, userID, "nb", 1, 10).Return([]*models.Document{doc}, 1, nil)
+		mockService.On("CalculateEntityCount", fixtures.RedactionSchema).Return(1)
+
+		// Act
+		handler.ListDocuments(rr, req)
+
+		// Assert
+		golden.AssertResponse(t, rr)
+
+		mockService.AssertExpectations(t)
+	})
+
+	t.Run("Invalid language", func(t *testing.T) {
+		// Arrange
+		handler, mockService := setupDocumentTest(t)
+
+		req := httptest.NewRequest(http.MethodGet, "/api/documents?language=norwegian", nil)
+		req = req.WithContext(createDocumentAuthContext(123))
+
+		rr := httptest.NewRecorder()
+
+		// Act
+		handler.ListDocuments(rr, req)
+
+		// Assert
+		assert.Equal(t, http.StatusBadRequest, rr.Code)
+		mockService.AssertNotCalled(t, "ListDocuments", mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+	})
+}
+
+func TestListDocuments_NDJSON(t *testing.T) {
+	t.Run("Streams all documents", func(t *testing.T) {
+		// Arrange
+		handler, mockService := setupDocumentTest(t)
+
+		userID := int64(123)
+		req := httptest.NewRequest(http.MethodGet, "/api/documents?page=1&page_size=1", nil)
+		req.Header.Set("Accept", "application/x-ndjson")
+		req = req.WithContext(createDocumentAuthContext(userID))
+
+		rr := httptest.NewRecorder()
+
+		testTime := time.Now()
+		mockDocs := []*models.Document{
+			{ID: 1, UserID: userID, HashedDocumentName: "test-doc-1", UploadTimestamp: testTime, LastModified: testTime, RedactionSchema: "schema1"},
+			{ID: 2, UserID: userID, HashedDocumentName: "test-doc-2", UploadTimestamp: testTime, LastModified: testTime, RedactionSchema: "schema2"},
+		}
+
+		// Pagination is ignored when streaming
+		mockService.On("StreamDocuments", mock.Anything, userID, "").Return(mockDocs, nil)
+		mockService.On("CalculateEntityCount", "schema1").Return(5)
+		mockService.On("CalculateEntityCount", "schema2").Return(3)
 
 		// Act
 		handler.ListDocuments(rr, req)
 
 		// Assert
+		assert.Equal(t, http.StatusOK, rr.Code)
+		assert.Equal(t, constants.ContentTypeNDJSON, rr.Header().Get(constants.HeaderContentType))
+
+		lines := strings.Split(strings.TrimSpace(rr.Body.String()), "\n")
+		require.Len(t, lines, 2)
+
+		var first models.DocumentSummary
+		require.NoError(t, json.Unmarshal([]byte(lines[0]), &first))
+		assert.Equal(t, int64(1), first.ID)
+		assert.Equal(t, 5, first.EntityCount)
+		assert.NotContains(t, rr.Body.String(), "schema1", "redaction schemas must not be exposed")
+
+		mockService.AssertExpectations(t)
+	})
+
+	t.Run("Service error before the first row", func(t *testing.T) {
+		// Arrange
+		handler, mockService := setupDocumentTest(t)
+
+		userID := int64(123)
+		req := httptest.NewRequest(http.MethodGet, "/api/documents", nil)
+		req.Header.Set("Accept", "application/x-ndjson")
+		req = req.WithContext(createDocumentAuthContext(userID))
+
+		rr := httptest.NewRecorder()
+
+		mockService.On("StreamDocuments", mock.Anything, userID, "").Return(nil, errors.New("database error"))
+
+		// Act
+		handler.ListDocuments(rr, req)
+
+		// Assert: a regular error response is still possible
 		assert.Equal(t, http.StatusInternalServerError, rr.Code)
+		assert.Equal(t, constants.ContentTypeJSON, rr.Header().Get(constants.HeaderContentType))
 
-		var response utils.Response
-		err := json.Unmarshal(rr.Body.Bytes(), &response)
-		assert.NoError(t, err)
+		mockService.AssertExpectations(t)
+	})
+}
 
-		assert.False(t, response.Success)
-		assert.NotNil(t, response.Error)
+func TestListDocuments_Keyset(t *testing.T) {
+	userID := int64(123)
+	mockDocs := []*models.Document{fixtures.Document(9, userID), fixtures.Document(8, userID), fixtures.Document(7, userID)}
+	mockDocs[2].UploadTimestamp = fixtures.Time.Add(-time.Hour)
 
-		// Verify the mock expectations
+	t.Run("First page", func(t *testing.T) {
+		handler, mockService := setupDocumentTest(t)
+		req := httptest.NewRequest(http.MethodGet, "/api/documents?pagination=keyset&page_size=2", nil)
+		req.Header.Set(constants.HeaderXAPIVersion, "2")
+		rr := httptest.NewRecorder()
+
+		// One document more than the page holds tells that another page follows
+		mockService.On("ListDocumentsPage", mock.Anything, userID, "", (*utils.KeysetCursor)(nil), 3).Return(mockDocs, nil).Once()
+		mockService.On("CalculateEntityCount", fixtures.RedactionSchema).Return(1)
+
+		handler.ListDocuments(rr, req.WithContext(createDocumentAuthContext(userID)))
+
+		// The next cursor points after the last document of the page
+		golden.AssertResponse(t, rr)
+		mockService.AssertExpectations(t)
+	})
+
+	t.Run("Last page", func(t *testing.T) {
+		handler, mockService := setupDocumentTest(t)
+		after := utils.KeysetCursor{Timestamp: fixtures.Time, ID: 8}
+		req := httptest.NewRequest(http.MethodGet, "/api/documents?page_size=2&cursor="+utils.EncodeKeysetCursor(after), nil)
+		req.Header.Set(constants.HeaderXAPIVersion, "2")
+		rr := httptest.NewRecorder()
+
+		mockService.On("ListDocumentsPage", mock.Anything, userID, "", &after, 3).Return(mockDocs[2:], nil).Once()
+		mockService.On("CalculateEntityCount", fixtures.RedactionSchema).Return(1)
+
+		handler.ListDocuments(rr, req.WithContext(createDocumentAuthContext(userID)))
+
+		golden.AssertResponse(t, rr)
 		mockService.AssertExpectations(t)
 	})
 }
 
 // UploadDocument tests
+func TestListDocuments_XML(t *testing.T) {
+	// Arrange
+	handler, mockService := setupDocumentTest(t)
+
+	userID := int64(123)
+	req := httptest.NewRequest(http.MethodGet, "/api/documents?page=1&page_size=10", nil)
+	req.Header.Set("Accept", "application/xml")
+	req = req.WithContext(createDocumentAuthContext(userID))
+
+	rr := httptest.NewRecorder()
+
+	testTime := time.Date(2026, 3, 1, 12, 0, 0, 0, time.UTC)
+	mockDocs := []*models.Document{
+		{ID: 1, UserID: userID, HashedDocumentName: "test-doc-1", UploadTimestamp: testTime, LastModified: testTime, RedactionSchema: "schema1", Tags: []string{"contract"}},
+	}
+
+	mockService.On("ListDocuments", mock.Anything, userID, "", 1, 10).Return(mockDocs, 1, nil)
+	mockService.On("CalculateEntityCount", "schema1").Return(5)
+
+	// Act
+	handler.ListDocuments(rr, req)
+
+	// Assert
+	assert.Equal(t, http.StatusOK, rr.Code)
+	assert.Equal(t, constants.ContentTypeXML, rr.Header().Get(constants.HeaderContentType))
+
+	body := rr.Body.String()
+	assert.Contains(t, body, "<hashed_name>test-doc-1</hashed_name>")
+	assert.Contains(t, body, "<entity_count>5</entity_count>")
+	assert.Contains(t, body, "<item>contract</item>")
+	assert.Contains(t, body, "<total_items>1</total_items>")
+	assert.NotContains(t, body, "schema1", "redaction schemas must not be exposed")
+
+	mockService.AssertExpectations(t)
+}
+
 func TestUploadDocument(t *testing.T) {
 	t.Run("Successful upload", func(t *testing.T) {
 		// Arrange
@@ -253,59 +615,156 @@ func TestUploadDocument(t *testing.T) {
 
 		rr := httptest.NewRecorder()
 
-		testTime := time.Now()
-		mockDoc := &models.Document{
-			ID:                 1,
-			UserID:             userID,
-			HashedDocumentName: "sensitive-document.pdf",
-			UploadTimestamp:    testTime,
-			LastModified:       testTime,
-		}
+		mockDoc := fixtures.Document(1, userID)
+		mockDoc.HashedDocumentName = "sensitive-document.pdf"
 
-		mockService.On("UploadDocument", userID, "sensitive-document.pdf", redactionMapping).Return(mockDoc, nil)
+		mockService.On("UploadDocument", mock.Anything, userID, "sensitive-document.pdf", "", "", redactionMapping).Return(mockDoc, nil)
 
 		// Act
 		handler.UploadDocument(rr, req)
 
 		// Assert
-		assert.Equal(t, constants.StatusCreated, rr.Code)
-
-		var response utils.Response
-		err := json.Unmarshal(rr.Body.Bytes(), &response)
-		assert.NoError(t, err)
-
-		assert.True(t, response.Success)
-		assert.NotNil(t, response.Data)
+		golden.AssertResponse(t, rr)
 
 		// Verify the mock expectations
 		mockService.AssertExpectations(t)
 	})
 
-	t.Run("Invalid JSON", func(t *testing.T) {
+	t.Run("Language from Content-Language header", func(t *testing.T) {
 		// Arrange
-		handler, _ := setupDocumentTest(t)
+		handler, mockService := setupDocumentTest(t)
 
 		userID := int64(123)
+		redactionMapping := models.RedactionMapping{Pages: []models.Page{{PageNumber: 1}}}
 
-		invalidJSON := `{"filename": "test", "redaction_schema": invalid}`
-		req := httptest.NewRequest(http.MethodPost, "/api/documents", bytes.NewBufferString(invalidJSON))
+		jsonBody, _ := json.Marshal(map[string]interface{}{
+			"filename":         "arbeidsavtale.pdf",
+			"redaction_schema": redactionMapping,
+		})
+
+		req := httptest.NewRequest(http.MethodPost, "/api/documents", bytes.NewBuffer(jsonBody))
 		req.Header.Set("Content-Type", "application/json")
+		req.Header.Set("Content-Language", "nb-NO, en")
 		req = req.WithContext(createDocumentAuthContext(userID))
 
 		rr := httptest.NewRecorder()
 
+		mockDoc := &models.Document{ID: 1, UserID: userID, HashedDocumentName: "arbeidsavtale.pdf", Language: "nb"}
+		mockService.On("UploadDocument", mock.Anything, userID, "arbeidsavtale.pdf", "nb-NO", "", redactionMapping).Return(mockDoc, nil)
+
 		// Act
 		handler.UploadDocument(rr, req)
 
 		// Assert
-		assert.Equal(t, http.StatusBadRequest, rr.Code)
-
-		var response utils.Response
-		err := json.Unmarshal(rr.Body.Bytes(), &response)
-		assert.NoError(t, err)
-
-		assert.False(t, response.Success)
-		assert.Equal(t, "Invalid request data required filename and redaction schema", response.Error.Message)
+		assert.Equal(t, constants.StatusCreated, rr.Code)
+		mockService.AssertExpectations(t)
+	})
+
+	t.Run("Source is passed to the service", func(t *testing.T) {
+		// Arrange
+		handler, mockService := setupDocumentTest(t)
+
+		userID := int64(123)
+		redactionMapping := models.RedactionMapping{Pages: []models.Page{{PageNumber: 1}}}
+
+		jsonBody, _ := json.Marshal(map[string]interface{}{
+			"filename":         "invoice_2024.pdf",
+			"source":           "scanner",
+			"redaction_schema": redactionMapping,
+		})
+
+		req := httptest.NewRequest(http.MethodPost, "/api/documents", bytes.NewBuffer(jsonBody))
+		req.Header.Set("Content-Type", "application/json")
+		req = req.WithContext(createDocumentAuthContext(userID))
+
+		rr := httptest.NewRecorder()
+
+		mockDoc := fixtures.Document(1, userID)
+		mockDoc.HashedDocumentName = "invoice_2024.pdf"
+		mockDoc.Source = "scanner"
+		mockDoc.Tags = []string{"finance"}
+		mockService.On("UploadDocument", mock.Anything, userID, "invoice_2024.pdf", "", "scanner", redactionMapping).Return(mockDoc, nil)
+
+		// Act
+		handler.UploadDocument(rr, req)
+
+		// Assert
+		golden.AssertResponse(t, rr)
+		mockService.AssertExpectations(t)
+	})
+
+	t.Run("Ephemeral processing mode", func(t *testing.T) {
+		// Arrange
+		handler, mockService := setupDocumentTest(t)
+
+		userID := int64(123)
+		redactionMapping := models.RedactionMapping{Pages: []models.Page{{PageNumber: 1}}}
+
+		jsonBody, _ := json.Marshal(map[string]interface{}{
+			"filename":         "patient_record.pdf",
+			"processing_mode":  "ephemeral",
+			"redaction_schema": redactionMapping,
+		})
+
+		req := httptest.NewRequest(http.MethodPost, "/api/documents", bytes.NewBuffer(jsonBody))
+		req.Header.Set("Content-Type", "application/json")
+		req = req.WithContext(createDocumentAuthContext(userID))
+
+		rr := httptest.NewRecorder()
+
+		mockService.On("ProcessEphemeral", mock.Anything, userID, "patient_record.pdf", "", "", redactionMapping).
+			Return(&models.EphemeralDocument{ProcessingMode: constants.ProcessingModeEphemeral, AuditID: 7, Filename: "patient_record.pdf"}, nil)
+
+		// Act
+		handler.UploadDocument(rr, req)
+
+		// Assert
+		golden.AssertResponse(t, rr)
+		mockService.AssertNotCalled(t, "UploadDocument", mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+		mockService.AssertExpectations(t)
+	})
+
+	t.Run("Unknown processing mode", func(t *testing.T) {
+		// Arrange
+		handler, _ := setupDocumentTest(t)
+
+		jsonBody, _ := json.Marshal(map[string]interface{}{
+			"filename":         "patient_record.pdf",
+			"processing_mode":  "transient",
+			"redaction_schema": models.RedactionMapping{Pages: []models.Page{{PageNumber: 1}}},
+		})
+
+		req := httptest.NewRequest(http.MethodPost, "/api/documents", bytes.NewBuffer(jsonBody))
+		req.Header.Set("Content-Type", "application/json")
+		req = req.WithContext(createDocumentAuthContext(123))
+
+		rr := httptest.NewRecorder()
+
+		// Act
+		handler.UploadDocument(rr, req)
+
+		// Assert
+		assert.Equal(t, constants.StatusBadRequest, rr.Code)
+	})
+
+	t.Run("Invalid JSON", func(t *testing.T) {
+		// Arrange
+		handler, _ := setupDocumentTest(t)
+
+		userID := int64(123)
+
+		invalidJSON := `{"filename": "test", "redaction_schema": invalid}`
+		req := httptest.NewRequest(http.MethodPost, "/api/documents", bytes.NewBufferString(invalidJSON))
+		req.Header.Set("Content-Type", "application/json")
+		req = req.WithContext(createDocumentAuthContext(userID))
+
+		rr := httptest.NewRecorder()
+
+		// Act
+		handler.UploadDocument(rr, req)
+
+		// Assert
+		golden.AssertResponse(t, rr)
 	})
 
 	t.Run("Missing filename", func(t *testing.T) {
@@ -378,7 +837,7 @@ func TestUploadDocument(t *testing.T) {
 		rr := httptest.NewRecorder()
 
 		serviceErr := errors.New("storage error")
-		mockService.On("UploadDocument", userID, "test-document.pdf", redactionMapping).Return(nil, serviceErr)
+		mockService.On("UploadDocument", mock.Anything, userID, "test-document.pdf", "", "", redactionMapping).Return(nil, serviceErr)
 
 		// Act
 		handler.UploadDocument(rr, req)
@@ -421,168 +880,1162 @@ func TestUploadDocument(t *testing.T) {
 
 		rr := httptest.NewRecorder()
 
-		mockService.On("UploadDocument", userID, "test-document.pdf", redactionMapping).Return(nil, service.ErrInvalidDocumentID)
+		mockService.On("UploadDocument", mock.Anything, userID, "test-document.pdf", "", "", redactionMapping).Return(nil, service.ErrInvalidDocumentID)
+
+		// Act
+		handler.UploadDocument(rr, req)
+
+		// Assert
+		golden.AssertResponse(t, rr)
+
+		// Verify the mock expectations
+		mockService.AssertExpectations(t)
+	})
+
+	t.Run("Upload over the size limit", func(t *testing.T) {
+		// Arrange
+		mockService := new(MockDocumentService)
+		handler := NewDocumentHandler(mockService)
+
+		jsonBody, _ := json.Marshal(map[string]interface{}{
+			"filename":         "scan.pdf",
+			"redaction_schema": models.RedactionMapping{Pages: []models.Page{{PageNumber: 1}}},
+		})
+
+		req := httptest.NewRequest(http.MethodPost, "/api/documents", bytes.NewBuffer(jsonBody))
+		req.Header.Set("Content-Type", "application/json")
+		req = req.WithContext(createDocumentAuthContext(123))
+
+		rr := httptest.NewRecorder()
+
+		mockService.On("CheckUploadSize", int64(len(jsonBody))).
+			Return(utils.NewUploadLimitError(constants.UploadLimitBytes, int64(len(jsonBody)), 10))
+
+		// Act
+		handler.UploadDocument(rr, req)
+
+		// Assert
+		golden.AssertResponse(t, rr)
+		mockService.AssertNotCalled(t, "UploadDocument", mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+		mockService.AssertExpectations(t)
+	})
+}
+
+// GetDocumentByID tests
+// newMultipartUpload builds a multipart upload of a file with the given form fields
+func newMultipartUpload(t *testing.T, file []byte, fields map[string]string) *http.Request {
+	t.Helper()
+	body := &bytes.Buffer{}
+	writer := multipart.NewWriter(body)
+	for name, value := range fields {
+		require.NoError(t, writer.WriteField(name, value))
+	}
+	if file != nil {
+		part, err := writer.CreateFormFile(constants.DocumentContentFormField, "contract.pdf")
+		require.NoError(t, err)
+		_, err = part.Write(file)
+		require.NoError(t, err)
+	}
+	require.NoError(t, writer.Close())
+
+	req := httptest.NewRequest(http.MethodPost, "/api/documents", body)
+	req.Header.Set("Content-Type", writer.FormDataContentType())
+	return req.WithContext(createDocumentAuthContext(123))
+}
+
+// withDeclaredFile adds the SHA-256 and size of a file to the form fields of its upload
+func withDeclaredFile(file []byte, fields map[string]string) map[string]string {
+	sum := sha256.Sum256(file)
+	fields["sha256"] = hex.EncodeToString(sum[:])
+	fields["size_bytes"] = strconv.Itoa(len(file))
+	return fields
+}
+
+func TestUploadDocument_Multipart(t *testing.T) {
+	pdf := []byte("%PDF-1.7\n%%EOF")
+	schema := models.RedactionMapping{Pages: []models.Page{{PageNumber: 1}}}
+	schemaJSON, _ := json.Marshal(schema)
+
+	t.Run("File with JSON metadata", func(t *testing.T) {
+		handler, mockService := setupDocumentTest(t)
+		metadata, _ := json.Marshal(map[string]interface{}{"language": "nb", "redaction_schema": schema})
+		mockService.On("UploadDocumentWithContent", mock.Anything, int64(123), "contract.pdf", "nb", "scanner", schema, pdf).
+			Return(&models.Document{ID: 5, UserID: 123, HashedDocumentName: "contract.pdf"}, nil).Once()
+
+		rr := httptest.NewRecorder()
+		handler.UploadDocument(rr, newMultipartUpload(t, pdf, withDeclaredFile(pdf, map[string]string{"metadata": string(metadata), "source": "scanner"})))
+
+		golden.AssertResponse(t, rr)
+		mockService.AssertExpectations(t)
+	})
+
+	t.Run("Missing file", func(t *testing.T) {
+		handler, mockService := setupDocumentTest(t)
+
+		rr := httptest.NewRecorder()
+		handler.UploadDocument(rr, newMultipartUpload(t, nil, map[string]string{"redaction_schema": string(schemaJSON)}))
+
+		golden.AssertResponse(t, rr)
+		mockService.AssertNotCalled(t, "UploadDocumentWithContent", mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+	})
+
+	t.Run("Over the size limit", func(t *testing.T) {
+		mockService := new(MockDocumentService)
+		mockService.On("CheckUploadSize", mock.Anything).Return(nil)
+		mockService.On("MaxUploadBytes").Return(int64(64))
+		handler := NewDocumentHandler(mockService)
+
+		rr := httptest.NewRecorder()
+		handler.UploadDocument(rr, newMultipartUpload(t, bytes.Repeat(pdf, 20), withDeclaredFile(bytes.Repeat(pdf, 20), map[string]string{"redaction_schema": string(schemaJSON)})))
+
+		golden.AssertResponse(t, rr)
+	})
+
+	t.Run("Ephemeral uploads keep no file", func(t *testing.T) {
+		handler, mockService := setupDocumentTest(t)
+		mockService.On("ProcessEphemeral", mock.Anything, int64(123), "contract.pdf", "", "", schema).
+			Return(&models.EphemeralDocument{ProcessingMode: constants.ProcessingModeEphemeral, AuditID: 9}, nil).Once()
+
+		rr := httptest.NewRecorder()
+		handler.UploadDocument(rr, newMultipartUpload(t, pdf, withDeclaredFile(pdf, map[string]string{"redaction_schema": string(schemaJSON), "processing_mode": "ephemeral"})))
+
+		assert.Equal(t, http.StatusOK, rr.Code)
+		mockService.AssertExpectations(t)
+	})
+
+	integrity := []struct {
+		name       string
+		fields     map[string]string
+		wantStatus int
+		wantCode   string
+	}{
+		{name: "Undeclared file", fields: map[string]string{}, wantStatus: http.StatusBadRequest, wantCode: constants.CodeValidationError},
+		{name: "Truncated file", fields: map[string]string{"size_bytes": strconv.Itoa(len(pdf) + 1)}, wantStatus: http.StatusUnprocessableEntity, wantCode: constants.CodeUploadIntegrityFailed},
+		{name: "Corrupted file", fields: map[string]string{"sha256": strings.Repeat("0", 64)}, wantStatus: http.StatusUnprocessableEntity, wantCode: constants.CodeUploadIntegrityFailed},
+		{name: "Malformed checksum", fields: map[string]string{"sha256": "abc"}, wantStatus: http.StatusBadRequest, wantCode: constants.CodeValidationError},
+	}
+	for _, tt := range integrity {
+		t.Run(tt.name, func(t *testing.T) {
+			handler, mockService := setupDocumentTest(t)
+			fields := map[string]string{"redaction_schema": string(schemaJSON)}
+			if len(tt.fields) > 0 {
+				withDeclaredFile(pdf, fields)
+			}
+			for name, value := range tt.fields {
+				fields[name] = value
+			}
+
+			rr := httptest.NewRecorder()
+			handler.UploadDocument(rr, newMultipartUpload(t, pdf, fields))
+
+			var response utils.Response
+			require.NoError(t, json.Unmarshal(rr.Body.Bytes(), &response))
+			assert.Equal(t, tt.wantStatus, rr.Code)
+			assert.Equal(t, tt.wantCode, response.Error.Code)
+			mockService.AssertNotCalled(t, "UploadDocumentWithContent", mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+		})
+	}
+}
+
+func TestReadVerifiedUpload(t *testing.T) {
+	file := []byte("%PDF-1.7\n%%EOF")
+	sum := sha256.Sum256(file)
+	checksum := hex.EncodeToString(sum[:])
+
+	content, err := readVerifiedUpload(bytes.NewReader(file), int64(len(file)), strings.ToUpper(checksum))
+	require.NoError(t, err)
+	assert.Equal(t, file, content)
+
+	// A longer file is caught by its size, even if the declared size is a prefix of it
+	_, err = readVerifiedUpload(bytes.NewReader(append(file, 'x')), int64(len(file)), checksum)
+	assert.True(t, errors.Is(err, utils.ErrUploadIntegrity))
+	assert.Equal(t, constants.UploadIntegritySize, utils.ParseError(err).Details["check"])
+}
+
+func TestGetDocumentContent(t *testing.T) {
+	pdf := []byte("%PDF-1.7\n%%EOF")
+	stored := &models.DocumentContent{DocumentID: 5, ContentType: constants.ContentTypePDF, SizeBytes: int64(len(pdf)), SHA256: "ab12", CreatedAt: time.Now()}
+
+	newRequest := func(id string, header map[string]string) *http.Request {
+		req := httptest.NewRequest(http.MethodGet, "/api/documents/"+id+"/content", nil)
+		for name, value := range header {
+			req.Header.Set(name, value)
+		}
+		rctx := chi.NewRouteContext()
+		rctx.URLParams.Add("id", id)
+		ctx := context.WithValue(createDocumentAuthContext(123), chi.RouteCtxKey, rctx)
+		return req.WithContext(ctx)
+	}
+
+	t.Run("Streams the PDF", func(t *testing.T) {
+		handler, mockService := setupDocumentTest(t)
+		content := &closingReader{Reader: bytes.NewReader(pdf)}
+		mockService.On("OpenDocumentContent", mock.Anything, int64(123), int64(5)).Return(stored, content, nil).Once()
+
+		rr := httptest.NewRecorder()
+		handler.GetDocumentContent(rr, newRequest("5", nil))
+
+		assert.Equal(t, http.StatusOK, rr.Code)
+		assert.Equal(t, constants.ContentTypePDF, rr.Header().Get("Content-Type"))
+		assert.Equal(t, `"ab12"`, rr.Header().Get("ETag"))
+		assert.Equal(t, pdf, rr.Body.Bytes())
+		assert.True(t, content.closed)
+	})
+
+	t.Run("Range request", func(t *testing.T) {
+		handler, mockService := setupDocumentTest(t)
+		mockService.On("OpenDocumentContent", mock.Anything, int64(123), int64(5)).Return(stored, &closingReader{Reader: bytes.NewReader(pdf)}, nil).Once()
+
+		rr := httptest.NewRecorder()
+		handler.GetDocumentContent(rr, newRequest("5", map[string]string{"Range": "bytes=0-4"}))
+
+		assert.Equal(t, http.StatusPartialContent, rr.Code)
+		assert.Equal(t, "%PDF-", rr.Body.String())
+	})
+
+	t.Run("Download resumes a matching version", func(t *testing.T) {
+		handler, mockService := setupDocumentTest(t)
+		mockService.On("OpenDocumentContent", mock.Anything, int64(123), int64(5)).Return(stored, &closingReader{Reader: bytes.NewReader(pdf)}, nil).Twice()
+
+		rr := httptest.NewRecorder()
+		handler.DownloadDocument(rr, newRequest("5", map[string]string{"Range": "bytes=9-", "If-Range": `"ab12"`}))
+
+		assert.Equal(t, http.StatusPartialContent, rr.Code)
+		assert.Equal(t, "%%EOF", rr.Body.String())
+		assert.Equal(t, `attachment; filename="hideme-document-5.pdf"`, rr.Header().Get("Content-Disposition"))
+		assert.Equal(t, "bytes", rr.Header().Get("Accept-Ranges"))
+
+		// A changed document is downloaded again in full
+		rr = httptest.NewRecorder()
+		handler.DownloadDocument(rr, newRequest("5", map[string]string{"Range": "bytes=9-", "If-Range": `"cd34"`}))
+
+		assert.Equal(t, http.StatusOK, rr.Code)
+		assert.Equal(t, pdf, rr.Body.Bytes())
+	})
+
+	t.Run("Uploaded without content", func(t *testing.T) {
+		handler, mockService := setupDocumentTest(t)
+		mockService.On("OpenDocumentContent", mock.Anything, int64(123), int64(6)).Return(nil, nil, service.ErrDocumentContentNotFound).Once()
+
+		rr := httptest.NewRecorder()
+		handler.GetDocumentContent(rr, newRequest("6", nil))
+
+		assert.Equal(t, http.StatusNotFound, rr.Code)
+	})
+
+	t.Run("Document of another user", func(t *testing.T) {
+		handler, mockService := setupDocumentTest(t)
+		mockService.On("OpenDocumentContent", mock.Anything, int64(123), int64(7)).Return(nil, nil, service.ErrDocumentNotFound).Once()
+
+		rr := httptest.NewRecorder()
+		handler.GetDocumentContent(rr, newRequest("7", nil))
+
+		assert.Equal(t, http.StatusNotFound, rr.Code)
+	})
+}
+
+func TestGetDocumentByID(t *testing.T) {
+	// Setup a router for URL parameter extraction
+	setupChiRouter := func(handler http.HandlerFunc) (http.Handler, *httptest.ResponseRecorder) {
+		r := chi.NewRouter()
+		r.Get("/api/documents/{id}", handler)
+		rr := httptest.NewRecorder()
+		return r, rr
+	}
+
+	t.Run("Successful retrieval", func(t *testing.T) {
+		// Arrange
+		handler, mockService := setupDocumentTest(t)
+
+		userID := int64(123)
+		docID := int64(456)
+
+		router, rr := setupChiRouter(handler.GetDocumentByID)
+
+		req := httptest.NewRequest(http.MethodGet, "/api/documents/"+strconv.FormatInt(docID, 10), nil)
+		req = req.WithContext(createDocumentAuthContext(userID))
+
+		mockDoc := fixtures.Document(docID, userID)
+
+		mockService.On("GetDocumentByID", mock.Anything, userID, docID).Return(mockDoc, nil)
+
+		// Act
+		router.ServeHTTP(rr, req)
+
+		// Assert
+		golden.AssertResponse(t, rr)
+
+		// Verify the mock expectations
+		mockService.AssertExpectations(t)
+	})
+
+	t.Run("Invalid document ID parameter", func(t *testing.T) {
+		// Arrange
+		handler, _ := setupDocumentTest(t)
+
+		userID := int64(123)
+
+		router, rr := setupChiRouter(handler.GetDocumentByID)
+
+		req := httptest.NewRequest(http.MethodGet, "/api/documents/invalid", nil)
+		req = req.WithContext(createDocumentAuthContext(userID))
+
+		// Act
+		router.ServeHTTP(rr, req)
+
+		// Assert
+		golden.AssertResponse(t, rr)
+	})
+
+	t.Run("Document not found", func(t *testing.T) {
+		// Arrange
+		handler, mockService := setupDocumentTest(t)
+
+		userID := int64(123)
+		docID := int64(999)
+
+		router, rr := setupChiRouter(handler.GetDocumentByID)
+
+		req := httptest.NewRequest(http.MethodGet, "/api/documents/"+strconv.FormatInt(docID, 10), nil)
+		req = req.WithContext(createDocumentAuthContext(userID))
+
+		mockService.On("GetDocumentByID", mock.Anything, userID, docID).Return(nil, service.ErrDocumentNotFound)
+
+		// Act
+		router.ServeHTTP(rr, req)
+
+		// Assert
+		golden.AssertResponse(t, rr)
+
+		// Verify the mock expectations
+		mockService.AssertExpectations(t)
+	})
+
+	t.Run("Service error", func(t *testing.T) {
+		// Arrange
+		handler, mockService := setupDocumentTest(t)
+
+		userID := int64(123)
+		docID := int64(456)
+
+		router, rr := setupChiRouter(handler.GetDocumentByID)
+
+		req := httptest.NewRequest(http.MethodGet, "/api/documents/"+strconv.FormatInt(docID, 10), nil)
+		req = req.WithContext(createDocumentAuthContext(userID))
+
+		serviceErr := errors.New("database error")
+		mockService.On("GetDocumentByID", mock.Anything, userID, docID).Return(nil, serviceErr)
+
+		// Act
+		router.ServeHTTP(rr, req)
+
+		// Assert
+		golden.AssertResponse(t, rr)
+
+		// Verify the mock expectations
+		mockService.AssertExpectations(t)
+	})
+
+	t.Run("Unauthorized access", func(t *testing.T) {
+		// Arrange
+		handler, _ := setupDocumentTest(t)
+
+		docID := int64(456)
+
+		router, rr := setupChiRouter(handler.GetDocumentByID)
+
+		req := httptest.NewRequest(http.MethodGet, "/api/documents/"+strconv.FormatInt(docID, 10), nil)
+		// No user ID in context
+
+		// Act
+		router.ServeHTTP(rr, req)
+
+		// Assert
+		golden.AssertResponse(t, rr)
+	})
+}
+
+// DeleteDocumentByID tests
+func TestDeleteDocumentByID(t *testing.T) {
+	// Setup a router for URL parameter extraction
+	setupChiRouter := func(handler http.HandlerFunc) (http.Handler, *httptest.ResponseRecorder) {
+		r := chi.NewRouter()
+		r.Delete("/api/documents/{id}", handler)
+		rr := httptest.NewRecorder()
+		return r, rr
+	}
+
+	t.Run("Successful deletion", func(t *testing.T) {
+		// Arrange
+		handler, mockService := setupDocumentTest(t)
+
+		userID := int64(123)
+		docID := int64(456)
+
+		router, rr := setupChiRouter(handler.DeleteDocumentByID)
+
+		req := httptest.NewRequest(http.MethodDelete, "/api/documents/"+strconv.FormatInt(docID, 10), nil)
+		req = req.WithContext(createDocumentAuthContext(userID))
+
+		mockService.On("DeleteDocumentByID", mock.Anything, userID, docID).Return(nil)
+
+		// Act
+		router.ServeHTTP(rr, req)
+
+		// Assert
+		assert.Equal(t, http.StatusNoContent, rr.Code)
+
+		// Verify the mock expectations
+		mockService.AssertExpectations(t)
+	})
+
+	t.Run("Invalid document ID parameter", func(t *testing.T) {
+		// Arrange
+		handler, _ := setupDocumentTest(t)
+
+		userID := int64(123)
+
+		router, rr := setupChiRouter(handler.DeleteDocumentByID)
+
+		req := httptest.NewRequest(http.MethodDelete, "/api/documents/invalid", nil)
+		req = req.WithContext(createDocumentAuthContext(userID))
+
+		// Act
+		router.ServeHTTP(rr, req)
+
+		// Assert
+		assert.Equal(t, http.StatusBadRequest, rr.Code)
+	})
+
+	t.Run("Document not found", func(t *testing.T) {
+		// Arrange
+		handler, mockService := setupDocumentTest(t)
+
+		userID := int64(123)
+		docID := int64(999)
+
+		router, rr := setupChiRouter(handler.DeleteDocumentByID)
+
+		req := httptest.NewRequest(http.MethodDelete, "/api/documents/"+strconv.FormatInt(docID, 10), nil)
+		req = req.WithContext(createDocumentAuthContext(userID))
+
+		mockService.On("DeleteDocumentByID", mock.Anything, userID, docID).Return(service.ErrDocumentNotFound)
+
+		// Act
+		router.ServeHTTP(rr, req)
+
+		// Assert
+		assert.Equal(t, http.StatusNotFound, rr.Code)
+
+		// Verify the mock expectations
+		mockService.AssertExpectations(t)
+	})
+
+	t.Run("Service error", func(t *testing.T) {
+		// Arrange
+		handler, mockService := setupDocumentTest(t)
+
+		userID := int64(123)
+		docID := int64(456)
+
+		router, rr := setupChiRouter(handler.DeleteDocumentByID)
+
+		req := httptest.NewRequest(http.MethodDelete, "/api/documents/"+strconv.FormatInt(docID, 10), nil)
+		req = req.WithContext(createDocumentAuthContext(userID))
+
+		serviceErr := errors.New("database error")
+		mockService.On("DeleteDocumentByID", mock.Anything, userID, docID).Return(serviceErr)
+
+		// Act
+		router.ServeHTTP(rr, req)
+
+		// Assert
+		assert.Equal(t, http.StatusInternalServerError, rr.Code)
+
+		// Verify the mock expectations
+		mockService.AssertExpectations(t)
+	})
+
+	t.Run("Unauthorized access", func(t *testing.T) {
+		// Arrange
+		handler, _ := setupDocumentTest(t)
+
+		docID := int64(456)
+
+		router, rr := setupChiRouter(handler.DeleteDocumentByID)
+
+		req := httptest.NewRequest(http.MethodDelete, "/api/documents/"+strconv.FormatInt(docID, 10), nil)
+		// No user ID in context
+
+		// Act
+		router.ServeHTTP(rr, req)
+
+		// Assert
+		assert.Equal(t, http.StatusUnauthorized, rr.Code)
+	})
+}
+
+// UpdateRedactionSchema tests
+func TestUpdateRedactionSchema(t *testing.T) {
+	setupChiRouter := func(handler http.HandlerFunc) (http.Handler, *httptest.ResponseRecorder) {
+		r := chi.NewRouter()
+		r.Put("/api/documents/{id}/redaction-schema", handler)
+		rr := httptest.NewRecorder()
+		return r, rr
+	}
+	body := `{"redaction_schema":{"version":2,"pages":[{"page":1,"sensitive":[]}]}}`
+
+	tests := []struct {
+		name           string
+		body           string
+		serviceErr     error
+		expectCall     bool
+		expectedStatus int
+	}{
+		{name: "Updated", body: body, expectCall: true, expectedStatus: http.StatusOK},
+		{name: "Malformed body", body: `{"redaction_schema":`, expectedStatus: http.StatusBadRequest},
+		{name: "Shared for reading only", body: body, serviceErr: service.ErrDocumentReadOnly, expectCall: true, expectedStatus: http.StatusForbidden},
+		{name: "Not shared", body: body, serviceErr: service.ErrDocumentNotFound, expectCall: true, expectedStatus: http.StatusNotFound},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			handler, mockService := setupDocumentTest(t)
+			userID := int64(123)
+			docID := int64(456)
+
+			if tt.expectCall {
+				if tt.serviceErr != nil {
+					mockService.On("UpdateRedactionSchema", mock.Anything, userID, docID, mock.Anything).Return(nil, tt.serviceErr).Once()
+				} else {
+					mockService.On("UpdateRedactionSchema", mock.Anything, userID, docID, mock.Anything).Return(fixtures.Document(docID, 7), nil).Once()
+				}
+			}
+
+			router, rr := setupChiRouter(handler.UpdateRedactionSchema)
+			req := httptest.NewRequest(http.MethodPut, "/api/documents/"+strconv.FormatInt(docID, 10)+"/redaction-schema", strings.NewReader(tt.body))
+			req.Header.Set(constants.HeaderContentType, constants.ContentTypeJSON)
+			req = req.WithContext(createDocumentAuthContext(userID))
+
+			router.ServeHTTP(rr, req)
+
+			assert.Equal(t, tt.expectedStatus, rr.Code)
+			mockService.AssertExpectations(t)
+		})
+	}
+}
+
+// Redaction schema version tests
+func TestListSchemaVersions(t *testing.T) {
+	router := func(handler http.HandlerFunc) http.Handler {
+		r := chi.NewRouter()
+		r.Get("/api/documents/{id}/redaction-schema/versions", handler)
+		return r
+	}
+
+	t.Run("Listed", func(t *testing.T) {
+		handler, mockService := setupDocumentTest(t)
+		mockService.On("GetSchemaVersions", mock.Anything, int64(123), int64(456)).
+			Return([]*models.DocumentSchemaVersion{{DocumentID: 456, Version: 2, EntityCount: 3, ReplacedBy: 123}}, nil).Once()
+
+		rr := httptest.NewRecorder()
+		req := httptest.NewRequest(http.MethodGet, "/api/documents/456/redaction-schema/versions", nil)
+		router(handler.ListSchemaVersions).ServeHTTP(rr, req.WithContext(createDocumentAuthContext(123)))
+
+		golden.AssertResponse(t, rr)
+		mockService.AssertExpectations(t)
+	})
+
+	t.Run("Not shared", func(t *testing.T) {
+		handler, mockService := setupDocumentTest(t)
+		mockService.On("GetSchemaVersions", mock.Anything, int64(123), int64(456)).Return(nil, service.ErrDocumentNotFound).Once()
+
+		rr := httptest.NewRecorder()
+		req := httptest.NewRequest(http.MethodGet, "/api/documents/456/redaction-schema/versions", nil)
+		router(handler.ListSchemaVersions).ServeHTTP(rr, req.WithContext(createDocumentAuthContext(123)))
+
+		assert.Equal(t, http.StatusNotFound, rr.Code)
+		mockService.AssertExpectations(t)
+	})
+}
+
+func TestRollbackRedactionSchema(t *testing.T) {
+	tests := []struct {
+		name           string
+		version        string
+		serviceErr     error
+		expectCall     bool
+		expectedStatus int
+	}{
+		{name: "Restored", version: "2", expectCall: true, expectedStatus: http.StatusOK},
+		{name: "Invalid version", version: "first", expectedStatus: http.StatusBadRequest},
+		{name: "Unknown version", version: "9", serviceErr: utils.NewNotFoundError("Redaction schema version", 9), expectCall: true, expectedStatus: http.StatusNotFound},
+		{name: "Shared for reading only", version: "2", serviceErr: service.ErrDocumentReadOnly, expectCall: true, expectedStatus: http.StatusForbidden},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			handler, mockService := setupDocumentTest(t)
+			if tt.expectCall {
+				version, _ := strconv.Atoi(tt.version)
+				if tt.serviceErr != nil {
+					mockService.On("RollbackRedactionSchema", mock.Anything, int64(123), int64(456), version).Return(nil, tt.serviceErr).Once()
+				} else {
+					mockService.On("RollbackRedactionSchema", mock.Anything, int64(123), int64(456), version).Return(fixtures.Document(456, 123), nil).Once()
+				}
+			}
+
+			r := chi.NewRouter()
+			r.Post("/api/documents/{id}/redaction-schema/versions/{version}/rollback", handler.RollbackRedactionSchema)
+			rr := httptest.NewRecorder()
+			req := httptest.NewRequest(http.MethodPost, "/api/documents/456/redaction-schema/versions/"+tt.version+"/rollback", nil)
+			r.ServeHTTP(rr, req.WithContext(createDocumentAuthContext(123)))
+
+			assert.Equal(t, tt.expectedStatus, rr.Code)
+			mockService.AssertExpectations(t)
+		})
+	}
+}
+
+func TestAddDetectedEntities(t *testing.T) {
+	entity := `{"method_id": 1, "entity_name": "Ola Nordmann", "redaction_schema": {"page": 1, "end_x": 10, "end_y": 10}}`
+	tests := []struct {
+		name           string
+		body           string
+		serviceErr     error
+		expectCall     bool
+		expectedStatus int
+	}{
+		{name: "Added", body: `{"entities": [` + entity + `, ` + entity + `]}`, expectCall: true, expectedStatus: http.StatusCreated},
+		{name: "Empty batch", body: `{"entities": []}`, expectedStatus: http.StatusBadRequest},
+		{name: "Missing method", body: `{"entities": [{"entity_name": "Ola Nordmann"}]}`, expectedStatus: http.StatusMultiStatus},
+		{name: "Name longer than the column", body: `{"entities": [{"method_id": 1, "entity_name": "` + strings.Repeat("a", 256) + `"}]}`, expectedStatus: http.StatusMultiStatus},
+		{name: "Partially invalid", body: `{"entities": [` + entity + `, {"entity_name": "Kari"}, ` + entity + `]}`, expectCall: true, expectedStatus: http.StatusMultiStatus},
+		{name: "Too many entities", body: `{"entities": [` + strings.Repeat(entity+`, `, constants.DetectedEntityBatchMax) + entity + `]}`, expectedStatus: http.StatusBadRequest},
+		{name: "Unknown document", body: `{"entities": [` + entity + `]}`, serviceErr: service.ErrDocumentNotFound, expectCall: true, expectedStatus: http.StatusNotFound},
+		{name: "Archived document", body: `{"entities": [` + entity + `]}`, serviceErr: service.ErrDocumentArchived, expectCall: true, expectedStatus: http.StatusConflict},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			handler, mockService := setupDocumentTest(t)
+			if tt.expectCall {
+				if tt.serviceErr != nil {
+					mockService.On("AddDetectedEntities", mock.Anything, int64(123), int64(456), mock.Anything).Return(nil, tt.serviceErr).Once()
+				} else {
+					// Only the valid entities reach the service
+					mockService.On("AddDetectedEntities", mock.Anything, int64(123), int64(456), mock.MatchedBy(func(inputs []models.DetectedEntityInput) bool {
+						return len(inputs) == 2 && inputs[1].MethodID == 1 && inputs[1].RedactionSchema.Page == 1
+					})).Return(&models.DetectedEntityBatchResult{DocumentID: 456, EntityIDs: []int64{1, 2}}, nil).Once()
+				}
+			}
+
+			r := chi.NewRouter()
+			r.Post("/api/documents/{id}/entities/batch", handler.AddDetectedEntities)
+			rr := httptest.NewRecorder()
+			req := httptest.NewRequest(http.MethodPost, "/api/documents/456/entities/batch", strings.NewReader(tt.body))
+			req.Header.Set("Content-Type", "application/json")
+			r.ServeHTTP(rr, req.WithContext(createDocumentAuthContext(123)))
+
+			assert.Equal(t, tt.expectedStatus, rr.Code)
+			golden.AssertResponse(t, rr)
+			mockService.AssertExpectations(t)
+		})
+	}
+}
+
+func TestListDetectedEntities(t *testing.T) {
+	tests := []struct {
+		name           string
+		query          string
+		filter         models.DetectedEntityFilter
+		serviceErr     error
+		expectCall     bool
+		expectedStatus int
+	}{
+		{name: "All entities", expectCall: true, expectedStatus: http.StatusOK},
+		{name: "Filtered", query: "?status=rejected&method_id=2", filter: models.DetectedEntityFilter{Status: models.EntityStatusRejected, MethodID: 2}, expectCall: true, expectedStatus: http.StatusOK},
+		{name: "Unknown status", query: "?status=maybe", expectedStatus: http.StatusBadRequest},
+		{name: "Invalid method", query: "?method_id=abc", expectedStatus: http.StatusBadRequest},
+		{name: "Unknown document", serviceErr: service.ErrDocumentNotFound, expectCall: true, expectedStatus: http.StatusNotFound},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			handler, mockService := setupDocumentTest(t)
+			if tt.expectCall {
+				var entities []*models.DetectedEntityWithMethod
+				if tt.serviceErr == nil {
+					entities = []*models.DetectedEntityWithMethod{{DetectedEntity: models.DetectedEntity{ID: 1, Status: models.EntityStatusRejected}}}
+				}
+				mockService.On("GetDetectedEntities", mock.Anything, int64(123), int64(456), tt.filter).Return(entities, tt.serviceErr).Once()
+			}
+
+			r := chi.NewRouter()
+			r.Get("/api/documents/{id}/entities", handler.ListDetectedEntities)
+			rr := httptest.NewRecorder()
+			req := httptest.NewRequest(http.MethodGet, "/api/documents/456/entities"+tt.query, nil)
+			r.ServeHTTP(rr, req.WithContext(createDocumentAuthContext(123)))
+
+			assert.Equal(t, tt.expectedStatus, rr.Code)
+			mockService.AssertExpectations(t)
+		})
+	}
+}
+
+func TestListDetectedEntities_Keyset(t *testing.T) {
+	handler, mockService := setupDocumentTest(t)
+	testTime := time.Date(2025, 3, 1, 12, 0, 0, 0, time.UTC)
+	after := utils.KeysetCursor{Timestamp: testTime, ID: 30}
+	entities := []*models.DetectedEntityWithMethod{
+		{DetectedEntity: models.DetectedEntity{ID: 21, DetectedTimestamp: testTime}},
+		{DetectedEntity: models.DetectedEntity{ID: 20, DetectedTimestamp: testTime}},
+	}
+	filter := models.DetectedEntityFilter{Status: models.EntityStatusPending, After: &after, Limit: 2}
+	mockService.On("GetDetectedEntities", mock.Anything, int64(123), int64(456), filter).Return(entities, nil).Once()
+
+	r := chi.NewRouter()
+	r.Get("/api/documents/{id}/entities", handler.ListDetectedEntities)
+	rr := httptest.NewRecorder()
+	req := httptest.NewRequest(http.MethodGet, "/api/documents/456/entities?status=pending&page_size=1&cursor="+utils.EncodeKeysetCursor(after), nil)
+	req.Header.Set(constants.HeaderXAPIVersion, "2")
+	r.ServeHTTP(rr, req.WithContext(createDocumentAuthContext(123)))
+
+	golden.AssertResponse(t, rr)
+	mockService.AssertExpectations(t)
+}
+
+func TestUpdateEntityStatus(t *testing.T) {
+	tests := []struct {
+		name           string
+		path           string
+		body           string
+		entityIDs      []int64
+		serviceErr     error
+		expectedStatus int
+	}{
+		{name: "One entity", path: "/api/documents/456/entities/7", body: `{"status": "accepted"}`, entityIDs: []int64{7}, expectedStatus: http.StatusOK},
+		{name: "Several entities", path: "/api/documents/456/entities", body: `{"entity_ids": [7, 8], "status": "accepted"}`, entityIDs: []int64{7, 8}, expectedStatus: http.StatusOK},
+		{name: "Unknown status", path: "/api/documents/456/entities/7", body: `{"status": "maybe"}`, expectedStatus: http.StatusBadRequest},
+		{name: "Invalid entity ID", path: "/api/documents/456/entities/abc", body: `{"status": "accepted"}`, expectedStatus: http.StatusBadRequest},
+		{name: "No entities", path: "/api/documents/456/entities", body: `{"entity_ids": [], "status": "accepted"}`, expectedStatus: http.StatusBadRequest},
+		{name: "Entity of another document", path: "/api/documents/456/entities/7", body: `{"status": "accepted"}`, entityIDs: []int64{7}, serviceErr: utils.NewNotFoundError("DetectedEntity", 7), expectedStatus: http.StatusNotFound},
+		{name: "Read only", path: "/api/documents/456/entities", body: `{"entity_ids": [7], "status": "rejected"}`, entityIDs: []int64{7}, serviceErr: service.ErrDocumentReadOnly, expectedStatus: http.StatusForbidden},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			handler, mockService := setupDocumentTest(t)
+			if tt.entityIDs != nil {
+				var result *models.EntityReviewResult
+				if tt.serviceErr == nil {
+					result = &models.EntityReviewResult{DocumentID: 456, EntityIDs: tt.entityIDs, Status: models.EntityStatusAccepted, ReviewedBy: 123, ReviewedAt: time.Now()}
+				}
+				mockService.On("ReviewEntities", mock.Anything, int64(123), int64(456), tt.entityIDs, mock.Anything).Return(result, tt.serviceErr).Once()
+			}
+
+			r := chi.NewRouter()
+			r.Patch("/api/documents/{id}/entities", handler.UpdateEntityStatuses)
+			r.Patch("/api/documents/{id}/entities/{entityID}", handler.UpdateEntityStatus)
+			rr := httptest.NewRecorder()
+			req := httptest.NewRequest(http.MethodPatch, tt.path, strings.NewReader(tt.body))
+			req.Header.Set("Content-Type", "application/json")
+			r.ServeHTTP(rr, req.WithContext(createDocumentAuthContext(123)))
+
+			assert.Equal(t, tt.expectedStatus, rr.Code)
+			mockService.AssertExpectations(t)
+		})
+	}
+}
+
+// GetDocumentSummary tests
+func TestGetDocumentSummary(t *testing.T) {
+	// Setup a router for URL parameter extraction
+	setupChiRouter := func(handler http.HandlerFunc) (http.Handler, *httptest.ResponseRecorder) {
+		r := chi.NewRouter()
+		r.Get("/api/documents/{id}/summary", handler)
+		rr := httptest.NewRecorder()
+		return r, rr
+	}
+
+	t.Run("Successful retrieval", func(t *testing.T) {
+		// Arrange
+		handler, mockService := setupDocumentTest(t)
+
+		userID := int64(123)
+		docID := int64(456)
+
+		router, rr := setupChiRouter(handler.GetDocumentSummary)
+
+		req := httptest.NewRequest(http.MethodGet, "/api/documents/"+strconv.FormatInt(docID, 10)+"/summary", nil)
+		req = req.WithContext(createDocumentAuthContext(userID))
+
+		testTime := fixtures.Time
+		mockSummary := &models.DocumentSummary{
+			ID:              docID,
+			HashedName:      "test-document.pdf",
+			UploadTimestamp: testTime,
+			LastModified:    testTime,
+			EntityCount:     5,
+		}
+
+		mockService.On("GetDocumentSummary", mock.Anything, userID, docID).Return(mockSummary, nil)
+
+		// Act
+		router.ServeHTTP(rr, req)
+
+		// Assert
+		golden.AssertResponse(t, rr)
+
+		// Verify the mock expectations
+		mockService.AssertExpectations(t)
+	})
+
+	t.Run("Invalid document ID parameter", func(t *testing.T) {
+		// Arrange
+		handler, _ := setupDocumentTest(t)
+
+		userID := int64(123)
+
+		router, rr := setupChiRouter(handler.GetDocumentSummary)
+
+		req := httptest.NewRequest(http.MethodGet, "/api/documents/invalid/summary", nil)
+		req = req.WithContext(createDocumentAuthContext(userID))
+
+		// Act
+		router.ServeHTTP(rr, req)
+
+		// Assert
+		assert.Equal(t, http.StatusBadRequest, rr.Code)
+	})
+
+	t.Run("Service error", func(t *testing.T) {
+		// Arrange
+		handler, mockService := setupDocumentTest(t)
+
+		userID := int64(123)
+		docID := int64(456)
+
+		router, rr := setupChiRouter(handler.GetDocumentSummary)
+
+		req := httptest.NewRequest(http.MethodGet, "/api/documents/"+strconv.FormatInt(docID, 10)+"/summary", nil)
+		req = req.WithContext(createDocumentAuthContext(userID))
+
+		serviceErr := errors.New("database error")
+		mockService.On("GetDocumentSummary", mock.Anything, userID, docID).Return(nil, serviceErr)
+
+		// Act
+		router.ServeHTTP(rr, req)
+
+		// Assert
+		assert.Equal(t, http.StatusInternalServerError, rr.Code)
+
+		// Verify the mock expectations
+		mockService.AssertExpectations(t)
+	})
+
+	t.Run("Unauthorized access", func(t *testing.T) {
+		// Arrange
+		handler, _ := setupDocumentTest(t)
+
+		docID := int64(456)
+
+		router, rr := setupChiRouter(handler.GetDocumentSummary)
+
+		req := httptest.NewRequest(http.MethodGet, "/api/documents/"+strconv.FormatInt(docID, 10)+"/summary", nil)
+		// No user ID in context
+
+		// Act
+		router.ServeHTTP(rr, req)
+
+		// Assert
+		assert.Equal(t, http.StatusUnauthorized, rr.Code)
+	})
+}
+
+func TestSearchDocuments(t *testing.T) {
+	t.Run("Successful search", func(t *testing.T) {
+		// Arrange
+		handler, mockService := setupDocumentTest(t)
+
+		req := httptest.NewRequest(http.MethodGet, "/api/documents/search?name=contract&uploaded_from=2026-01-01&uploaded_to=2026-01-31&entity_types=PERSON,%20EMAIL_ADDRESS&min_entities=2&page=1&page_size=10", nil)
+		req.Header.Set(constants.HeaderXAPIVersion, "2")
+		req = req.WithContext(createDocumentAuthContext(123))
+		rr := httptest.NewRecorder()
+
+		from := time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+		to := time.Date(2026, 2, 1, 0, 0, 0, 0, time.UTC)
+		minEntities := 2
+		filter := models.DocumentSearchFilter{
+			Name:         "contract",
+			UploadedFrom: &from,
+			UploadedTo:   &to,
+			EntityTypes:  []string{"PERSON", "EMAIL_ADDRESS"},
+			MinEntities:  &minEntities,
+		}
+		docs := []*models.Document{{ID: 1, UserID: 123, HashedDocumentName: "contract.pdf", RedactionSchema: "schema1"}}
+		mockService.On("SearchDocuments", mock.Anything, int64(123), filter, 1, 10).Return(docs, 1, nil)
+		mockService.On("CalculateEntityCount", "schema1").Return(2)
+
+		// Act
+		handler.SearchDocuments(rr, req)
+
+		// Assert
+		golden.AssertResponse(t, rr)
+
+		mockService.AssertExpectations(t)
+	})
+
+	t.Run("Invalid filters", func(t *testing.T) {
+		handler, mockService := setupDocumentTest(t)
+
+		for _, query := range []string{
+			"uploaded_from=yesterday",
+			"uploaded_from=2026-02-01&uploaded_to=2026-01-01",
+			"min_entities=-1",
+			"max_entities=many",
+			"min_entities=5&max_entities=2",
+			"entity_types=" + strings.Repeat("PERSON,", constants.DocumentSearchMaxEntityTypes+1),
+		} {
+			req := httptest.NewRequest(http.MethodGet, "/api/documents/search?"+query, nil)
+			req = req.WithContext(createDocumentAuthContext(123))
+			rr := httptest.NewRecorder()
+
+			handler.SearchDocuments(rr, req)
+
+			assert.Equal(t, http.StatusBadRequest, rr.Code, query)
+		}
+		mockService.AssertNotCalled(t, "SearchDocuments", mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+	})
+
+	t.Run("Unauthorized", func(t *testing.T) {
+		handler, _ := setupDocumentTest(t)
+
+		req := httptest.NewRequest(http.MethodGet, "/api/documents/search", nil)
+		rr := httptest.NewRecorder()
+
+		handler.SearchDocuments(rr, req)
+
+		assert.Equal(t, http.StatusUnauthorized, rr.Code)
+	})
+}
+
+func TestGetDocumentTimeline(t *testing.T) {
+	// Setup a router for URL parameter extraction
+	setupChiRouter := func(handler http.HandlerFunc) (http.Handler, *httptest.ResponseRecorder) {
+		r := chi.NewRouter()
+		r.Get("/api/documents/{id}/timeline", handler)
+		rr := httptest.NewRecorder()
+		return r, rr
+	}
+
+	t.Run("Successful retrieval", func(t *testing.T) {
+		// Arrange
+		handler, mockService := setupDocumentTest(t)
+		router, rr := setupChiRouter(handler.GetDocumentTimeline)
+
+		req := httptest.NewRequest(http.MethodGet, "/api/documents/456/timeline?page=2&page_size=1", nil)
+		req.Header.Set(constants.HeaderXAPIVersion, "2")
+		req = req.WithContext(createDocumentAuthContext(123))
+
+		events := []*models.DocumentEvent{
+			{Type: models.EventEntitiesDetected, Timestamp: fixtures.Time, EntityCount: 5, Description: "Detected 5 entities"},
+		}
+		mockService.On("GetDocumentTimeline", mock.Anything, int64(123), int64(456), 2, 1).Return(events, 3, nil)
+
+		// Act
+		router.ServeHTTP(rr, req)
+
+		// Assert
+		golden.AssertResponse(t, rr)
+
+		mockService.AssertExpectations(t)
+	})
+
+	t.Run("Document not found", func(t *testing.T) {
+		// Arrange
+		handler, mockService := setupDocumentTest(t)
+		router, rr := setupChiRouter(handler.GetDocumentTimeline)
+
+		req := httptest.NewRequest(http.MethodGet, "/api/documents/456/timeline", nil)
+		req = req.WithContext(createDocumentAuthContext(123))
+
+		mockService.On("GetDocumentTimeline", mock.Anything, int64(123), int64(456), constants.DefaultPage, constants.DefaultPageSize).
+			Return(nil, 0, service.ErrDocumentNotFound)
+
+		// Act
+		router.ServeHTTP(rr, req)
+
+		// Assert
+		assert.Equal(t, http.StatusNotFound, rr.Code)
+		mockService.AssertExpectations(t)
+	})
+
+	t.Run("Invalid document ID parameter", func(t *testing.T) {
+		// Arrange
+		handler, _ := setupDocumentTest(t)
+		router, rr := setupChiRouter(handler.GetDocumentTimeline)
+
+		req := httptest.NewRequest(http.MethodGet, "/api/documents/invalid/timeline", nil)
+		req = req.WithContext(createDocumentAuthContext(123))
 
 		// Act
-		handler.UploadDocument(rr, req)
+		router.ServeHTTP(rr, req)
 
 		// Assert
 		assert.Equal(t, http.StatusBadRequest, rr.Code)
+	})
 
-		var response utils.Response
-		err := json.Unmarshal(rr.Body.Bytes(), &response)
-		assert.NoError(t, err)
+	t.Run("Unauthorized access", func(t *testing.T) {
+		// Arrange
+		handler, _ := setupDocumentTest(t)
+		router, rr := setupChiRouter(handler.GetDocumentTimeline)
 
-		assert.False(t, response.Success)
-		assert.Equal(t, "Invalid document ID", response.Error.Message)
+		req := httptest.NewRequest(http.MethodGet, "/api/documents/456/timeline", nil)
 
-		// Verify the mock expectations
-		mockService.AssertExpectations(t)
+		// Act
+		router.ServeHTTP(rr, req)
+
+		// Assert
+		assert.Equal(t, http.StatusUnauthorized, rr.Code)
 	})
 }
 
-// GetDocumentByID tests
-func TestGetDocumentByID(t *testing.T) {
+func TestExportDocument(t *testing.T) {
 	// Setup a router for URL parameter extraction
 	setupChiRouter := func(handler http.HandlerFunc) (http.Handler, *httptest.ResponseRecorder) {
 		r := chi.NewRouter()
-		r.Get("/api/documents/{id}", handler)
+		r.Get("/api/documents/{id}/export", handler)
 		rr := httptest.NewRecorder()
 		return r, rr
 	}
 
-	t.Run("Successful retrieval", func(t *testing.T) {
+	newExport := func() *models.DocumentExport {
+		doc := &models.Document{ID: 456, UserID: 123, HashedDocumentName: "contract.pdf"}
+		entities := []*models.DetectedEntityWithMethod{
+			{DetectedEntity: models.DetectedEntity{ID: 7, DocumentID: 456, EntityName: "PERSON"}, MethodName: "Presidio"},
+		}
+		timeline := []*models.DocumentEvent{{Type: models.EventDocumentUploaded, Timestamp: fixtures.Time, Description: "Document uploaded"}}
+		return models.NewDocumentExport(doc, models.RedactionMapping{}, entities, timeline, fixtures.Time)
+	}
+
+	t.Run("JSON export", func(t *testing.T) {
 		// Arrange
 		handler, mockService := setupDocumentTest(t)
+		router, rr := setupChiRouter(handler.ExportDocument)
 
-		userID := int64(123)
-		docID := int64(456)
-
-		router, rr := setupChiRouter(handler.GetDocumentByID)
-
-		req := httptest.NewRequest(http.MethodGet, "/api/documents/"+strconv.FormatInt(docID, 10), nil)
-		req = req.WithContext(createDocumentAuthContext(userID))
-
-		testTime := time.Now()
-		mockDoc := &models.Document{
-			ID:                 docID,
-			UserID:             userID,
-			HashedDocumentName: "test-document.pdf",
-			UploadTimestamp:    testTime,
-			LastModified:       testTime,
-			RedactionSchema:    "schema1",
-		}
+		req := httptest.NewRequest(http.MethodGet, "/api/documents/456/export", nil)
+		req = req.WithContext(createDocumentAuthContext(123))
 
-		mockService.On("GetDocumentByID", docID).Return(mockDoc, nil)
+		mockService.On("ExportDocument", mock.Anything, int64(123), int64(456), models.LocaleISO).Return(newExport(), nil)
 
 		// Act
 		router.ServeHTTP(rr, req)
 
 		// Assert
 		assert.Equal(t, http.StatusOK, rr.Code)
+		assert.Contains(t, rr.Header().Get(constants.HeaderContentDisposition), "hideme-document-456.json")
+		golden.AssertResponse(t, rr)
 
-		var response utils.Response
-		err := json.Unmarshal(rr.Body.Bytes(), &response)
-		assert.NoError(t, err)
-
-		assert.True(t, response.Success)
-		assert.NotNil(t, response.Data)
-
-		// Verify the mock expectations
 		mockService.AssertExpectations(t)
 	})
 
-	t.Run("Invalid document ID parameter", func(t *testing.T) {
+	t.Run("ZIP export", func(t *testing.T) {
 		// Arrange
-		handler, _ := setupDocumentTest(t)
-
-		userID := int64(123)
+		handler, mockService := setupDocumentTest(t)
+		router, rr := setupChiRouter(handler.ExportDocument)
 
-		router, rr := setupChiRouter(handler.GetDocumentByID)
+		req := httptest.NewRequest(http.MethodGet, "/api/documents/456/export?format=zip", nil)
+		req = req.WithContext(createDocumentAuthContext(123))
 
-		req := httptest.NewRequest(http.MethodGet, "/api/documents/invalid", nil)
-		req = req.WithContext(createDocumentAuthContext(userID))
+		mockService.On("ExportDocument", mock.Anything, int64(123), int64(456), models.LocaleISO).Return(newExport(), nil)
 
 		// Act
 		router.ServeHTTP(rr, req)
 
 		// Assert
-		assert.Equal(t, http.StatusBadRequest, rr.Code)
-
-		var response utils.Response
-		err := json.Unmarshal(rr.Body.Bytes(), &response)
-		assert.NoError(t, err)
+		assert.Equal(t, http.StatusOK, rr.Code)
+		assert.Equal(t, constants.ContentTypeZIP, rr.Header().Get(constants.HeaderContentType))
+		assert.Contains(t, rr.Header().Get(constants.HeaderContentDisposition), "hideme-document-456.zip")
+		archive, err := zip.NewReader(bytes.NewReader(rr.Body.Bytes()), int64(rr.Body.Len()))
+		require.NoError(t, err)
+		assert.Len(t, archive.File, 5)
 
-		assert.False(t, response.Success)
-		assert.Equal(t, "Invalid document ID", response.Error.Message)
+		mockService.AssertExpectations(t)
 	})
 
-	t.Run("Document not found", func(t *testing.T) {
+	t.Run("Localized export", func(t *testing.T) {
 		// Arrange
 		handler, mockService := setupDocumentTest(t)
+		router, rr := setupChiRouter(handler.ExportDocument)
 
-		userID := int64(123)
-		docID := int64(999)
+		req := httptest.NewRequest(http.MethodGet, "/api/documents/456/export?locale=nb-NO", nil)
+		req = req.WithContext(createDocumentAuthContext(123))
 
-		router, rr := setupChiRouter(handler.GetDocumentByID)
+		mockService.On("ExportDocument", mock.Anything, int64(123), int64(456), models.LocaleNorwegian).Return(newExport(), nil)
 
-		req := httptest.NewRequest(http.MethodGet, "/api/documents/"+strconv.FormatInt(docID, 10), nil)
-		req = req.WithContext(createDocumentAuthContext(userID))
+		// Act
+		router.ServeHTTP(rr, req)
+
+		// Assert
+		assert.Equal(t, http.StatusOK, rr.Code)
+		mockService.AssertExpectations(t)
+	})
+
+	t.Run("Unsupported locale", func(t *testing.T) {
+		// Arrange
+		handler, _ := setupDocumentTest(t)
+		router, rr := setupChiRouter(handler.ExportDocument)
 
-		mockService.On("GetDocumentByID", docID).Return(nil, service.ErrDocumentNotFound)
+		req := httptest.NewRequest(http.MethodGet, "/api/documents/456/export?locale=xx-XX", nil)
+		req = req.WithContext(createDocumentAuthContext(123))
 
 		// Act
 		router.ServeHTTP(rr, req)
 
 		// Assert
-		assert.Equal(t, http.StatusNotFound, rr.Code)
+		assert.Equal(t, http.StatusBadRequest, rr.Code)
+	})
 
-		var response utils.Response
-		err := json.Unmarshal(rr.Body.Bytes(), &response)
-		assert.NoError(t, err)
+	t.Run("Invalid format", func(t *testing.T) {
+		// Arrange
+		handler, _ := setupDocumentTest(t)
+		router, rr := setupChiRouter(handler.ExportDocument)
 
-		assert.False(t, response.Success)
-		assert.Equal(t, "Document not found", response.Error.Message)
+		req := httptest.NewRequest(http.MethodGet, "/api/documents/456/export?format=pdf", nil)
+		req = req.WithContext(createDocumentAuthContext(123))
 
-		// Verify the mock expectations
-		mockService.AssertExpectations(t)
+		// Act
+		router.ServeHTTP(rr, req)
+
+		// Assert
+		assert.Equal(t, http.StatusBadRequest, rr.Code)
 	})
 
-	t.Run("Service error", func(t *testing.T) {
+	t.Run("Document not found", func(t *testing.T) {
 		// Arrange
 		handler, mockService := setupDocumentTest(t)
+		router, rr := setupChiRouter(handler.ExportDocument)
 
-		userID := int64(123)
-		docID := int64(456)
-
-		router, rr := setupChiRouter(handler.GetDocumentByID)
-
-		req := httptest.NewRequest(http.MethodGet, "/api/documents/"+strconv.FormatInt(docID, 10), nil)
-		req = req.WithContext(createDocumentAuthContext(userID))
+		req := httptest.NewRequest(http.MethodGet, "/api/documents/456/export", nil)
+		req = req.WithContext(createDocumentAuthContext(123))
 
-		serviceErr := errors.New("database error")
-		mockService.On("GetDocumentByID", docID).Return(nil, serviceErr)
+		mockService.On("ExportDocument", mock.Anything, int64(123), int64(456), models.LocaleISO).Return(nil, service.ErrDocumentNotFound)
 
 		// Act
 		router.ServeHTTP(rr, req)
 
 		// Assert
-		assert.Equal(t, http.StatusInternalServerError, rr.Code)
-
-		// Verify the mock expectations
+		assert.Equal(t, http.StatusNotFound, rr.Code)
 		mockService.AssertExpectations(t)
 	})
 
 	t.Run("Unauthorized access", func(t *testing.T) {
 		// Arrange
 		handler, _ := setupDocumentTest(t)
+		router, rr := setupChiRouter(handler.ExportDocument)
 
-		docID := int64(456)
-
-		router, rr := setupChiRouter(handler.GetDocumentByID)
-
-		req := httptest.NewRequest(http.MethodGet, "/api/documents/"+strconv.FormatInt(docID, 10), nil)
-		// No user ID in context
+		req := httptest.NewRequest(http.MethodGet, "/api/documents/456/export", nil)
 
 		// Act
 		router.ServeHTTP(rr, req)
@@ -592,234 +2045,353 @@ func TestGetDocumentByID(t *testing.T) {
 	})
 }
 
-// DeleteDocumentByID tests
-func TestDeleteDocumentByID(t *testing.T) {
+func TestRestoreFromArchive(t *testing.T) {
 	// Setup a router for URL parameter extraction
 	setupChiRouter := func(handler http.HandlerFunc) (http.Handler, *httptest.ResponseRecorder) {
 		r := chi.NewRouter()
-		r.Delete("/api/documents/{id}", handler)
+		r.Post("/api/documents/{id}/restore-from-archive", handler)
 		rr := httptest.NewRecorder()
 		return r, rr
 	}
 
-	t.Run("Successful deletion", func(t *testing.T) {
+	t.Run("Success", func(t *testing.T) {
 		// Arrange
 		handler, mockService := setupDocumentTest(t)
+		router, rr := setupChiRouter(handler.RestoreFromArchive)
 
-		userID := int64(123)
-		docID := int64(456)
-
-		router, rr := setupChiRouter(handler.DeleteDocumentByID)
-
-		req := httptest.NewRequest(http.MethodDelete, "/api/documents/"+strconv.FormatInt(docID, 10), nil)
-		req = req.WithContext(createDocumentAuthContext(userID))
+		req := httptest.NewRequest(http.MethodPost, "/api/documents/456/restore-from-archive", nil)
+		req = req.WithContext(createDocumentAuthContext(123))
 
-		mockService.On("DeleteDocumentByID", docID).Return(nil)
+		doc := &models.Document{ID: 456, UserID: 123, HashedDocumentName: "contract.pdf", RedactionSchema: "{}"}
+		mockService.On("RestoreFromArchive", mock.Anything, int64(123), int64(456)).Return(doc, nil)
 
 		// Act
 		router.ServeHTTP(rr, req)
 
 		// Assert
-		assert.Equal(t, http.StatusNoContent, rr.Code)
-
-		// Verify the mock expectations
+		assert.Equal(t, http.StatusOK, rr.Code)
 		mockService.AssertExpectations(t)
 	})
 
-	t.Run("Invalid document ID parameter", func(t *testing.T) {
+	t.Run("Not archived", func(t *testing.T) {
 		// Arrange
-		handler, _ := setupDocumentTest(t)
-
-		userID := int64(123)
+		handler, mockService := setupDocumentTest(t)
+		router, rr := setupChiRouter(handler.RestoreFromArchive)
 
-		router, rr := setupChiRouter(handler.DeleteDocumentByID)
+		req := httptest.NewRequest(http.MethodPost, "/api/documents/456/restore-from-archive", nil)
+		req = req.WithContext(createDocumentAuthContext(123))
 
-		req := httptest.NewRequest(http.MethodDelete, "/api/documents/invalid", nil)
-		req = req.WithContext(createDocumentAuthContext(userID))
+		mockService.On("RestoreFromArchive", mock.Anything, int64(123), int64(456)).Return(nil, service.ErrDocumentNotArchived)
 
 		// Act
 		router.ServeHTTP(rr, req)
 
 		// Assert
-		assert.Equal(t, http.StatusBadRequest, rr.Code)
+		assert.Equal(t, http.StatusConflict, rr.Code)
+		mockService.AssertExpectations(t)
 	})
 
 	t.Run("Document not found", func(t *testing.T) {
 		// Arrange
 		handler, mockService := setupDocumentTest(t)
+		router, rr := setupChiRouter(handler.RestoreFromArchive)
 
-		userID := int64(123)
-		docID := int64(999)
-
-		router, rr := setupChiRouter(handler.DeleteDocumentByID)
-
-		req := httptest.NewRequest(http.MethodDelete, "/api/documents/"+strconv.FormatInt(docID, 10), nil)
-		req = req.WithContext(createDocumentAuthContext(userID))
+		req := httptest.NewRequest(http.MethodPost, "/api/documents/456/restore-from-archive", nil)
+		req = req.WithContext(createDocumentAuthContext(123))
 
-		mockService.On("DeleteDocumentByID", docID).Return(service.ErrDocumentNotFound)
+		mockService.On("RestoreFromArchive", mock.Anything, int64(123), int64(456)).Return(nil, service.ErrDocumentNotFound)
 
 		// Act
 		router.ServeHTTP(rr, req)
 
 		// Assert
 		assert.Equal(t, http.StatusNotFound, rr.Code)
-
-		// Verify the mock expectations
 		mockService.AssertExpectations(t)
 	})
+}
 
-	t.Run("Service error", func(t *testing.T) {
+func TestUpdateLifecycle(t *testing.T) {
+	tests := []struct {
+		name           string
+		body           string
+		exempt         bool
+		callsService   bool
+		serviceErr     error
+		expectedStatus int
+	}{
+		{name: "Exempt", body: `{"exempt": true}`, exempt: true, callsService: true, expectedStatus: http.StatusOK},
+		{name: "Subject to cleanup again", body: `{"exempt": false}`, callsService: true, expectedStatus: http.StatusOK},
+		{name: "Missing exempt", body: `{}`, expectedStatus: http.StatusBadRequest},
+		{name: "Document of another user", body: `{"exempt": true}`, exempt: true, callsService: true, serviceErr: service.ErrDocumentNotFound, expectedStatus: http.StatusNotFound},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			handler, mockService := setupDocumentTest(t)
+			if tt.callsService {
+				var lifecycle *models.DocumentLifecycle
+				if tt.serviceErr == nil {
+					lifecycle = &models.DocumentLifecycle{DocumentID: 456, Exempt: tt.exempt}
+				}
+				mockService.On("SetLifecycleExempt", mock.Anything, int64(123), int64(456), tt.exempt).Return(lifecycle, tt.serviceErr).Once()
+			}
+
+			r := chi.NewRouter()
+			r.Put("/api/documents/{id}/lifecycle", handler.UpdateLifecycle)
+			rr := httptest.NewRecorder()
+			req := httptest.NewRequest(http.MethodPut, "/api/documents/456/lifecycle", strings.NewReader(tt.body))
+			req.Header.Set("Content-Type", "application/json")
+			r.ServeHTTP(rr, req.WithContext(createDocumentAuthContext(123)))
+
+			assert.Equal(t, tt.expectedStatus, rr.Code)
+			golden.AssertResponse(t, rr)
+			mockService.AssertExpectations(t)
+		})
+	}
+}
+
+func TestPreviewRetention(t *testing.T) {
+	thirty := 30
+	tests := []struct {
+		name           string
+		query          string
+		retentionDays  *int
+		callsService   bool
+		expectedStatus int
+	}{
+		{name: "Current setting", callsService: true, expectedStatus: http.StatusOK},
+		{name: "Proposed setting", query: "?retention_days=30", retentionDays: &thirty, callsService: true, expectedStatus: http.StatusOK},
+		{name: "Negative days", query: "?retention_days=-1", expectedStatus: http.StatusBadRequest},
+		{name: "Too many days", query: "?retention_days=3651", expectedStatus: http.StatusBadRequest},
+		{name: "Not a number", query: "?retention_days=month", expectedStatus: http.StatusBadRequest},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			handler, mockService := setupDocumentTest(t)
+			if tt.callsService {
+				days := 0
+				if tt.retentionDays != nil {
+					days = *tt.retentionDays
+				}
+				mockService.On("PreviewRetention", mock.Anything, int64(123), tt.retentionDays).
+					Return(&models.RetentionPreview{RetentionDays: days, Documents: []*models.ExpiredDocument{}}, nil).Once()
+			}
+
+			rr := httptest.NewRecorder()
+			req := httptest.NewRequest(http.MethodGet, "/api/documents/retention-preview"+tt.query, nil)
+			handler.PreviewRetention(rr, req.WithContext(createDocumentAuthContext(123)))
+
+			assert.Equal(t, tt.expectedStatus, rr.Code)
+			golden.AssertResponse(t, rr)
+			mockService.AssertExpectations(t)
+		})
+	}
+}
+
+func TestGetPageOverlay(t *testing.T) {
+	// Setup a router for URL parameter extraction
+	setupChiRouter := func(handler http.HandlerFunc) (http.Handler, *httptest.ResponseRecorder) {
+		r := chi.NewRouter()
+		r.Get("/api/documents/{id}/pages/{n}/overlay", handler)
+		rr := httptest.NewRecorder()
+		return r, rr
+	}
+
+	t.Run("Success", func(t *testing.T) {
 		// Arrange
 		handler, mockService := setupDocumentTest(t)
+		router, rr := setupChiRouter(handler.GetPageOverlay)
 
-		userID := int64(123)
-		docID := int64(456)
+		req := httptest.NewRequest(http.MethodGet, "/api/documents/456/pages/2/overlay", nil)
+		req = req.WithContext(createDocumentAuthContext(123))
 
-		router, rr := setupChiRouter(handler.DeleteDocumentByID)
+		overlay := &models.PageOverlay{
+			DocumentID: 456,
+			Page:       2,
+			Rects:      []models.OverlayRect{{X: 0.1, Y: 0.2, Width: 0.3, Height: 0.05, Label: "PERSON", Color: "#33FF57", Method: "Presidio"}},
+		}
+		mockService.On("GetPageOverlay", mock.Anything, int64(123), int64(456), 2).Return(overlay, nil)
 
-		req := httptest.NewRequest(http.MethodDelete, "/api/documents/"+strconv.FormatInt(docID, 10), nil)
-		req = req.WithContext(createDocumentAuthContext(userID))
+		// Act
+		router.ServeHTTP(rr, req)
 
-		serviceErr := errors.New("database error")
-		mockService.On("DeleteDocumentByID", docID).Return(serviceErr)
+		// Assert
+		golden.AssertResponse(t, rr)
+		mockService.AssertExpectations(t)
+	})
+
+	t.Run("Invalid page number", func(t *testing.T) {
+		for _, page := range []string{"0", "-1", "first"} {
+			// Arrange
+			handler, mockService := setupDocumentTest(t)
+			router, rr := setupChiRouter(handler.GetPageOverlay)
+
+			req := httptest.NewRequest(http.MethodGet, "/api/documents/456/pages/"+page+"/overlay", nil)
+			req = req.WithContext(createDocumentAuthContext(123))
+
+			// Act
+			router.ServeHTTP(rr, req)
+
+			// Assert
+			assert.Equal(t, http.StatusBadRequest, rr.Code, page)
+			mockService.AssertNotCalled(t, "GetPageOverlay")
+		}
+	})
+
+	t.Run("Archived document", func(t *testing.T) {
+		// Arrange
+		handler, mockService := setupDocumentTest(t)
+		router, rr := setupChiRouter(handler.GetPageOverlay)
+
+		req := httptest.NewRequest(http.MethodGet, "/api/documents/456/pages/1/overlay", nil)
+		req = req.WithContext(createDocumentAuthContext(123))
+
+		mockService.On("GetPageOverlay", mock.Anything, int64(123), int64(456), 1).Return(nil, service.ErrDocumentArchived)
 
 		// Act
 		router.ServeHTTP(rr, req)
 
 		// Assert
-		assert.Equal(t, http.StatusInternalServerError, rr.Code)
-
-		// Verify the mock expectations
+		assert.Equal(t, http.StatusConflict, rr.Code)
 		mockService.AssertExpectations(t)
 	})
 
-	t.Run("Unauthorized access", func(t *testing.T) {
+	t.Run("Document not found", func(t *testing.T) {
 		// Arrange
-		handler, _ := setupDocumentTest(t)
-
-		docID := int64(456)
+		handler, mockService := setupDocumentTest(t)
+		router, rr := setupChiRouter(handler.GetPageOverlay)
 
-		router, rr := setupChiRouter(handler.DeleteDocumentByID)
+		req := httptest.NewRequest(http.MethodGet, "/api/documents/456/pages/1/overlay", nil)
+		req = req.WithContext(createDocumentAuthContext(123))
 
-		req := httptest.NewRequest(http.MethodDelete, "/api/documents/"+strconv.FormatInt(docID, 10), nil)
-		// No user ID in context
+		mockService.On("GetPageOverlay", mock.Anything, int64(123), int64(456), 1).Return(nil, service.ErrDocumentNotFound)
 
 		// Act
 		router.ServeHTTP(rr, req)
 
 		// Assert
-		assert.Equal(t, http.StatusUnauthorized, rr.Code)
+		assert.Equal(t, http.StatusNotFound, rr.Code)
+		mockService.AssertExpectations(t)
 	})
 }
 
-// GetDocumentSummary tests
-func TestGetDocumentSummary(t *testing.T) {
+func TestRedactDocument(t *testing.T) {
 	// Setup a router for URL parameter extraction
 	setupChiRouter := func(handler http.HandlerFunc) (http.Handler, *httptest.ResponseRecorder) {
 		r := chi.NewRouter()
-		r.Get("/api/documents/{id}/summary", handler)
+		r.Post("/api/documents/{id}/redact", handler)
 		rr := httptest.NewRecorder()
 		return r, rr
 	}
+	redacted := &redaction.Result{PDF: []byte("%PDF-1.7\nredacted\n%%EOF"), Pages: 1, RedactedPages: 1}
 
-	t.Run("Successful retrieval", func(t *testing.T) {
+	t.Run("Stored content", func(t *testing.T) {
 		// Arrange
 		handler, mockService := setupDocumentTest(t)
+		router, rr := setupChiRouter(handler.RedactDocument)
 
-		userID := int64(123)
-		docID := int64(456)
-
-		router, rr := setupChiRouter(handler.GetDocumentSummary)
-
-		req := httptest.NewRequest(http.MethodGet, "/api/documents/"+strconv.FormatInt(docID, 10)+"/summary", nil)
-		req = req.WithContext(createDocumentAuthContext(userID))
-
-		testTime := time.Now()
-		mockSummary := &models.DocumentSummary{
-			ID:              docID,
-			HashedName:      "test-document.pdf",
-			UploadTimestamp: testTime,
-			LastModified:    testTime,
-			EntityCount:     5,
-		}
+		req := httptest.NewRequest(http.MethodPost, "/api/documents/456/redact", nil)
+		req = req.WithContext(createDocumentAuthContext(123))
 
-		mockService.On("GetDocumentSummary", docID).Return(mockSummary, nil)
+		mockService.On("RedactDocument", mock.Anything, int64(123), int64(456), constants.RedactionMethodBlackout, []byte(nil)).Return(redacted, nil)
 
 		// Act
 		router.ServeHTTP(rr, req)
 
 		// Assert
 		assert.Equal(t, http.StatusOK, rr.Code)
+		assert.Equal(t, constants.ContentTypePDF, rr.Header().Get(constants.HeaderContentType))
+		assert.Equal(t, "attachment; filename=redacted-456.pdf", rr.Header().Get(constants.HeaderContentDisposition))
+		assert.Equal(t, redacted.PDF, rr.Body.Bytes())
+		mockService.AssertExpectations(t)
+	})
+
+	t.Run("Uploaded PDF with a default method", func(t *testing.T) {
+		// Arrange
+		handler, mockService := setupDocumentTest(t)
+		router, rr := setupChiRouter(handler.RedactDocument)
 
-		var response utils.Response
-		err := json.Unmarshal(rr.Body.Bytes(), &response)
-		assert.NoError(t, err)
+		pdf := []byte("%PDF-1.7\n%%EOF")
+		req := newMultipartUpload(t, pdf, nil)
+		req.URL.Path = "/api/documents/456/redact"
+		req.URL.RawQuery = "method=MASK"
 
-		assert.True(t, response.Success)
-		assert.NotNil(t, response.Data)
+		mockService.On("RedactDocument", mock.Anything, int64(123), int64(456), constants.RedactionMethodMask, pdf).Return(redacted, nil)
 
-		// Verify the mock expectations
+		// Act
+		router.ServeHTTP(rr, req)
+
+		// Assert
+		assert.Equal(t, http.StatusOK, rr.Code)
 		mockService.AssertExpectations(t)
 	})
 
-	t.Run("Invalid document ID parameter", func(t *testing.T) {
+	t.Run("Upload without a file", func(t *testing.T) {
 		// Arrange
-		handler, _ := setupDocumentTest(t)
-
-		userID := int64(123)
-
-		router, rr := setupChiRouter(handler.GetDocumentSummary)
+		handler, mockService := setupDocumentTest(t)
+		router, rr := setupChiRouter(handler.RedactDocument)
 
-		req := httptest.NewRequest(http.MethodGet, "/api/documents/invalid/summary", nil)
-		req = req.WithContext(createDocumentAuthContext(userID))
+		req := newMultipartUpload(t, nil, map[string]string{"filename": "contract.pdf"})
+		req.URL.Path = "/api/documents/456/redact"
 
 		// Act
 		router.ServeHTTP(rr, req)
 
 		// Assert
 		assert.Equal(t, http.StatusBadRequest, rr.Code)
+		mockService.AssertNotCalled(t, "RedactDocument")
 	})
 
-	t.Run("Service error", func(t *testing.T) {
+	t.Run("Invalid method", func(t *testing.T) {
 		// Arrange
 		handler, mockService := setupDocumentTest(t)
+		router, rr := setupChiRouter(handler.RedactDocument)
 
-		userID := int64(123)
-		docID := int64(456)
+		req := httptest.NewRequest(http.MethodPost, "/api/documents/456/redact?method=blur", nil)
+		req = req.WithContext(createDocumentAuthContext(123))
 
-		router, rr := setupChiRouter(handler.GetDocumentSummary)
+		// Act
+		router.ServeHTTP(rr, req)
 
-		req := httptest.NewRequest(http.MethodGet, "/api/documents/"+strconv.FormatInt(docID, 10)+"/summary", nil)
-		req = req.WithContext(createDocumentAuthContext(userID))
+		// Assert
+		assert.Equal(t, http.StatusBadRequest, rr.Code)
+		mockService.AssertNotCalled(t, "RedactDocument")
+	})
 
-		serviceErr := errors.New("database error")
-		mockService.On("GetDocumentSummary", docID).Return(nil, serviceErr)
+	t.Run("PDF that cannot be redacted", func(t *testing.T) {
+		// Arrange
+		handler, mockService := setupDocumentTest(t)
+		router, rr := setupChiRouter(handler.RedactDocument)
+
+		req := httptest.NewRequest(http.MethodPost, "/api/documents/456/redact", nil)
+		req = req.WithContext(createDocumentAuthContext(123))
+
+		mockService.On("RedactDocument", mock.Anything, int64(123), int64(456), constants.RedactionMethodBlackout, []byte(nil)).Return(nil, service.ErrDocumentNotRedactable)
 
 		// Act
 		router.ServeHTTP(rr, req)
 
 		// Assert
-		assert.Equal(t, http.StatusInternalServerError, rr.Code)
-
-		// Verify the mock expectations
+		assert.Equal(t, http.StatusBadRequest, rr.Code)
 		mockService.AssertExpectations(t)
 	})
 
-	t.Run("Unauthorized access", func(t *testing.T) {
+	t.Run("Document not found", func(t *testing.T) {
 		// Arrange
-		handler, _ := setupDocumentTest(t)
-
-		docID := int64(456)
+		handler, mockService := setupDocumentTest(t)
+		router, rr := setupChiRouter(handler.RedactDocument)
 
-		router, rr := setupChiRouter(handler.GetDocumentSummary)
+		req := httptest.NewRequest(http.MethodPost, "/api/documents/456/redact", nil)
+		req = req.WithContext(createDocumentAuthContext(123))
 
-		req := httptest.NewRequest(http.MethodGet, "/api/documents/"+strconv.FormatInt(docID, 10)+"/summary", nil)
-		// No user ID in context
+		mockService.On("RedactDocument", mock.Anything, int64(123), int64(456), constants.RedactionMethodBlackout, []byte(nil)).Return(nil, service.ErrDocumentNotFound)
 
 		// Act
 		router.ServeHTTP(rr, req)
 
 		// Assert
-		assert.Equal(t, http.StatusUnauthorized, rr.Code)
+		assert.Equal(t, http.StatusNotFound, rr.Code)
+		mockService.AssertExpectations(t)
 	})
 }
@@ -0,0 +1,294 @@
+// Package handlers provides HTTP request handlers for the HideMe API.
+package handlers
+
+import (
+	"context"
+	"net/http"
+	"strconv"
+
+	"github.com/go-chi/chi/v5"
+
+	"github.com/yasinhessnawi1/Hideme_Backend/internal/auth"
+	"github.com/yasinhessnawi1/Hideme_Backend/internal/constants"
+	"github.com/yasinhessnawi1/Hideme_Backend/internal/models"
+	"github.com/yasinhessnawi1/Hideme_Backend/internal/utils"
+)
+
+// DocumentShareServiceInterface defines the service methods required for sharing documents.
+type DocumentShareServiceInterface interface {
+	// ShareDocument shares a document with the account of an email address.
+	//
+	// Parameters:
+	//   - ctx: Context for the operation
+	//   - ownerID: The ID of the user sharing the document
+	//   - documentID: The ID of the document
+	//   - req: The email address of the account and the permission to grant
+	//
+	// Returns:
+	//   - The share
+	//   - NotFoundError if the document or the account doesn't exist
+	//   - BadRequestError if the email address is the owner's
+	ShareDocument(ctx context.Context, ownerID, documentID int64, req *models.DocumentShareRequest) (*models.DocumentShare, error)
+
+	// ListShares returns the accounts a document owned by a user is shared with.
+	//
+	// Parameters:
+	//   - ctx: Context for the operation
+	//   - ownerID: The ID of the owner of the document
+	//   - documentID: The ID of the document
+	//
+	// Returns:
+	//   - The shares of the document
+	//   - NotFoundError if the document doesn't exist or belongs to another user
+	ListShares(ctx context.Context, ownerID, documentID int64) ([]*models.DocumentShare, error)
+
+	// RevokeShare stops sharing a document owned by a user with another account.
+	//
+	// Parameters:
+	//   - ctx: Context for the operation
+	//   - ownerID: The ID of the owner of the document
+	//   - documentID: The ID of the document
+	//   - userID: The ID of the account the document is shared with
+	//
+	// Returns:
+	//   - NotFoundError if the document or the share doesn't exist
+	RevokeShare(ctx context.Context, ownerID, documentID, userID int64) error
+
+	// ListSharedWithMe returns the documents other users shared with a user.
+	//
+	// Parameters:
+	//   - ctx: Context for the operation
+	//   - userID: The ID of the user the documents are shared with
+	//
+	// Returns:
+	//   - The shared documents, most recently shared first
+	ListSharedWithMe(ctx context.Context, userID int64) ([]*models.SharedDocument, error)
+}
+
+// DocumentShareHandler handles HTTP requests for sharing documents between users.
+type DocumentShareHandler struct {
+	shareService DocumentShareServiceInterface
+}
+
+// NewDocumentShareHandler creates a new DocumentShareHandler with the provided share service.
+//
+// Parameters:
+//   - shareService: Service managing the shares of documents
+//
+// Returns:
+//   - A properly initialized DocumentShareHandler
+func NewDocumentShareHandler(shareService DocumentShareServiceInterface) *DocumentShareHandler {
+	return &DocumentShareHandler{
+		shareService: shareService,
+	}
+}
+
+// ShareDocument grants another account read or redact access to a document. Sharing it
+// again with the same account changes the permission.
+//
+// HTTP Method:
+//   - POST
+//
+// URL Path:
+//   - /api/documents/{id}/shares
+//
+// Requires:
+//   - Authentication: User must be logged in and own the document
+//
+// Request Body:
+//   - JSON object conforming to models.DocumentShareRequest
+//
+// Responses:
+//   - 201 Created: Document shared
+//   - 400 Bad Request: Invalid document ID or request body, or the owner's own email
+//   - 401 Unauthorized: User not authenticated
+//   - 404 Not Found: Document or account not found
+//   - 500 Internal Server Error: Server-side error
+//
+// @Summary Share document
+// @Description Grants another account read or redact access to a document
+// @Tags Documents
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path int true "Document ID"
+// @Param share body models.DocumentShareRequest true "Account and permission"
+// @Success 201 {object} utils.Response{data=models.DocumentShare} "Document shared"
+// @Failure 400 {object} utils.Response{error=string} "Invalid request body"
+// @Failure 401 {object} utils.Response{error=string} "User not authenticated"
+// @Failure 404 {object} utils.Response{error=string} "Document or account not found"
+// @Failure 500 {object} utils.Response{error=string} "Server error"
+// @Router /documents/{id}/shares [post]
+func (h *DocumentShareHandler) ShareDocument(w http.ResponseWriter, r *http.Request) {
+	userID, ok := auth.GetUserID(r)
+	if !ok {
+		utils.Unauthorized(w, constants.MsgAuthRequired)
+		return
+	}
+	documentID, err := strconv.ParseInt(chi.URLParam(r, constants.ParamID), 10, 64)
+	if err != nil {
+		utils.BadRequest(w, "Invalid document ID", nil)
+		return
+	}
+
+	var req models.DocumentShareRequest
+	if err := utils.DecodeAndValidate(r, &req); err != nil {
+		utils.ErrorFromAppError(w, utils.ParseError(err))
+		return
+	}
+
+	share, err := h.shareService.ShareDocument(r.Context(), userID, documentID, &req)
+	if err != nil {
+		utils.ErrorFromAppError(w, utils.ParseError(err))
+		return
+	}
+
+	utils.JSON(w, constants.StatusCreated, share)
+}
+
+// ListDocumentShares returns the accounts a document is shared with.
+//
+// HTTP Method:
+//   - GET
+//
+// URL Path:
+//   - /api/documents/{id}/shares
+//
+// Requires:
+//   - Authentication: User must be logged in and own the document
+//
+// Responses:
+//   - 200 OK: List of shares
+//   - 400 Bad Request: Invalid document ID
+//   - 401 Unauthorized: User not authenticated
+//   - 404 Not Found: Document not found
+//   - 500 Internal Server Error: Server-side error
+//
+// @Summary List document shares
+// @Description Returns the accounts a document is shared with and their permissions
+// @Tags Documents
+// @Produce json
+// @Security BearerAuth
+// @Param id path int true "Document ID"
+// @Success 200 {object} utils.Response{data=[]models.DocumentShare} "List of shares"
+// @Failure 400 {object} utils.Response{error=string} "Invalid document ID"
+// @Failure 401 {object} utils.Response{error=string} "User not authenticated"
+// @Failure 404 {object} utils.Response{error=string} "Document not found"
+// @Failure 500 {object} utils.Response{error=string} "Server error"
+// @Router /documents/{id}/shares [get]
+func (h *DocumentShareHandler) ListDocumentShares(w http.ResponseWriter, r *http.Request) {
+	userID, ok := auth.GetUserID(r)
+	if !ok {
+		utils.Unauthorized(w, constants.MsgAuthRequired)
+		return
+	}
+	documentID, err := strconv.ParseInt(chi.URLParam(r, constants.ParamID), 10, 64)
+	if err != nil {
+		utils.BadRequest(w, "Invalid document ID", nil)
+		return
+	}
+
+	shares, err := h.shareService.ListShares(r.Context(), userID, documentID)
+	if err != nil {
+		utils.ErrorFromAppError(w, utils.ParseError(err))
+		return
+	}
+
+	utils.ListAll(w, r, shares)
+}
+
+// RevokeDocumentShare stops sharing a document with an account.
+//
+// HTTP Method:
+//   - DELETE
+//
+// URL Path:
+//   - /api/documents/{id}/shares/{userId}
+//
+// Requires:
+//   - Authentication: User must be logged in and own the document
+//
+// Responses:
+//   - 204 No Content: Share revoked
+//   - 400 Bad Request: Invalid document or user ID
+//   - 401 Unauthorized: User not authenticated
+//   - 404 Not Found: Document not found, or not shared with the account
+//   - 500 Internal Server Error: Server-side error
+//
+// @Summary Revoke document share
+// @Description Stops sharing a document with an account
+// @Tags Documents
+// @Security BearerAuth
+// @Param id path int true "Document ID"
+// @Param userId path int true "ID of the account the document is shared with"
+// @Success 204 "Share revoked"
+// @Failure 400 {object} utils.Response{error=string} "Invalid ID"
+// @Failure 401 {object} utils.Response{error=string} "User not authenticated"
+// @Failure 404 {object} utils.Response{error=string} "Share not found"
+// @Failure 500 {object} utils.Response{error=string} "Server error"
+// @Router /documents/{id}/shares/{userId} [delete]
+func (h *DocumentShareHandler) RevokeDocumentShare(w http.ResponseWriter, r *http.Request) {
+	userID, ok := auth.GetUserID(r)
+	if !ok {
+		utils.Unauthorized(w, constants.MsgAuthRequired)
+		return
+	}
+	documentID, err := strconv.ParseInt(chi.URLParam(r, constants.ParamID), 10, 64)
+	if err != nil {
+		utils.BadRequest(w, "Invalid document ID", nil)
+		return
+	}
+	sharedWith, err := strconv.ParseInt(chi.URLParam(r, "userId"), 10, 64)
+	if err != nil {
+		utils.BadRequest(w, "Invalid user ID", nil)
+		return
+	}
+
+	if err := h.shareService.RevokeShare(r.Context(), userID, documentID, sharedWith); err != nil {
+		utils.ErrorFromAppError(w, utils.ParseError(err))
+		return
+	}
+
+	utils.NoContent(w)
+}
+
+// ListSharedWithMe returns the documents other users shared with the authenticated user.
+//
+// HTTP Method:
+//   - GET
+//
+// URL Path:
+//   - /api/documents/shared-with-me
+//
+// Requires:
+//   - Authentication: User must be logged in
+//
+// Responses:
+//   - 200 OK: List of shared documents
+//   - 401 Unauthorized: User not authenticated
+//   - 500 Internal Server Error: Server-side error
+//
+// @Summary List documents shared with me
+// @Description Returns the documents other users shared with the user and the granted permissions
+// @Tags Documents
+// @Produce json
+// @Security BearerAuth
+// @Success 200 {object} utils.Response{data=[]models.SharedDocument} "List of shared documents"
+// @Failure 401 {object} utils.Response{error=string} "User not authenticated"
+// @Failure 500 {object} utils.Response{error=string} "Server error"
+// @Router /documents/shared-with-me [get]
+func (h *DocumentShareHandler) ListSharedWithMe(w http.ResponseWriter, r *http.Request) {
+	userID, ok := auth.GetUserID(r)
+	if !ok {
+		utils.Unauthorized(w, constants.MsgAuthRequired)
+		return
+	}
+
+	documents, err := h.shareService.ListSharedWithMe(r.Context(), userID)
+	if err != nil {
+		utils.ErrorFromAppError(w, utils.ParseError(err))
+		return
+	}
+
+	utils.ListAll(w, r, documents)
+}
@@ -0,0 +1,189 @@
+package handlers_test
+
+import (
+	"bytes"
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+
+	"github.com/yasinhessnawi1/Hideme_Backend/internal/constants"
+	"github.com/yasinhessnawi1/Hideme_Backend/internal/handlers"
+	"github.com/yasinhessnawi1/Hideme_Backend/internal/models"
+	"github.com/yasinhessnawi1/Hideme_Backend/internal/service"
+	"github.com/yasinhessnawi1/Hideme_Backend/internal/testsupport/golden"
+)
+
+// MockDocumentShareService is a mock implementation of the DocumentShareService
+type MockDocumentShareService struct {
+	mock.Mock
+}
+
+func (m *MockDocumentShareService) ShareDocument(ctx context.Context, ownerID, documentID int64, req *models.DocumentShareRequest) (*models.DocumentShare, error) {
+	args := m.Called(ctx, ownerID, documentID, req)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*models.DocumentShare), args.Error(1)
+}
+
+func (m *MockDocumentShareService) ListShares(ctx context.Context, ownerID, documentID int64) ([]*models.DocumentShare, error) {
+	args := m.Called(ctx, ownerID, documentID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]*models.DocumentShare), args.Error(1)
+}
+
+func (m *MockDocumentShareService) RevokeShare(ctx context.Context, ownerID, documentID, userID int64) error {
+	args := m.Called(ctx, ownerID, documentID, userID)
+	return args.Error(0)
+}
+
+func (m *MockDocumentShareService) ListSharedWithMe(ctx context.Context, userID int64) ([]*models.SharedDocument, error) {
+	args := m.Called(ctx, userID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]*models.SharedDocument), args.Error(1)
+}
+
+func setupDocumentShareTest() (*chi.Mux, *MockDocumentShareService) {
+	mockService := new(MockDocumentShareService)
+	handler := handlers.NewDocumentShareHandler(mockService)
+
+	router := chi.NewRouter()
+	router.Get("/api/documents/shared-with-me", handler.ListSharedWithMe)
+	router.Post("/api/documents/{id}/shares", handler.ShareDocument)
+	router.Get("/api/documents/{id}/shares", handler.ListDocumentShares)
+	router.Delete("/api/documents/{id}/shares/{userId}", handler.RevokeDocumentShare)
+	return router, mockService
+}
+
+func TestShareDocument(t *testing.T) {
+	tests := []struct {
+		name           string
+		body           string
+		shareErr       error
+		expectCall     bool
+		expectedStatus int
+	}{
+		{
+			name:           "Shared",
+			body:           `{"email":"colleague@example.com","permission":"redact"}`,
+			expectCall:     true,
+			expectedStatus: http.StatusCreated,
+		},
+		{
+			name:           "Unknown permission",
+			body:           `{"email":"colleague@example.com","permission":"owner"}`,
+			expectedStatus: http.StatusBadRequest,
+		},
+		{
+			name:           "Invalid email",
+			body:           `{"email":"colleague","permission":"read"}`,
+			expectedStatus: http.StatusBadRequest,
+		},
+		{
+			name:           "Shared with the owner",
+			body:           `{"email":"owner@example.com","permission":"read"}`,
+			shareErr:       service.ErrShareWithSelf,
+			expectCall:     true,
+			expectedStatus: http.StatusBadRequest,
+		},
+		{
+			name:           "Unknown account",
+			body:           `{"email":"nobody@example.com","permission":"read"}`,
+			shareErr:       service.ErrShareUserNotFound,
+			expectCall:     true,
+			expectedStatus: http.StatusNotFound,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			router, mockService := setupDocumentShareTest()
+			if tt.expectCall {
+				if tt.shareErr != nil {
+					mockService.On("ShareDocument", mock.Anything, int64(7), int64(12), mock.Anything).Return(nil, tt.shareErr).Once()
+				} else {
+					mockService.On("ShareDocument", mock.Anything, int64(7), int64(12), &models.DocumentShareRequest{Email: "colleague@example.com", Permission: constants.SharePermissionRedact}).
+						Return(&models.DocumentShare{ID: 3, DocumentID: 12, UserID: 8, Email: "colleague@example.com", GrantedBy: 7, Permission: constants.SharePermissionRedact}, nil).Once()
+				}
+			}
+
+			req, err := http.NewRequest("POST", "/api/documents/12/shares", bytes.NewBufferString(tt.body))
+			require.NoError(t, err)
+			req.Header.Set("Content-Type", "application/json")
+			req = req.WithContext(createAuthContext(7))
+
+			rr := httptest.NewRecorder()
+			router.ServeHTTP(rr, req)
+
+			assert.Equal(t, tt.expectedStatus, rr.Code)
+			mockService.AssertExpectations(t)
+		})
+	}
+}
+
+func TestListDocumentShares(t *testing.T) {
+	router, mockService := setupDocumentShareTest()
+
+	shares := []*models.DocumentShare{{ID: 3, DocumentID: 12, UserID: 8, Email: "colleague@example.com", GrantedBy: 7, Permission: constants.SharePermissionRead}}
+	mockService.On("ListShares", mock.Anything, int64(7), int64(12)).Return(shares, nil).Once()
+	mockService.On("ListShares", mock.Anything, int64(8), int64(12)).Return(nil, service.ErrDocumentNotFound).Once()
+
+	req, err := http.NewRequest("GET", "/api/documents/12/shares", nil)
+	require.NoError(t, err)
+	req.Header.Set(constants.HeaderXAPIVersion, "2")
+	rr := httptest.NewRecorder()
+	router.ServeHTTP(rr, req.WithContext(createAuthContext(7)))
+
+	golden.AssertResponse(t, rr)
+
+	// Only the owner lists the shares
+	rr = httptest.NewRecorder()
+	router.ServeHTTP(rr, req.WithContext(createAuthContext(8)))
+	assert.Equal(t, http.StatusNotFound, rr.Code)
+	mockService.AssertExpectations(t)
+}
+
+func TestRevokeDocumentShare(t *testing.T) {
+	router, mockService := setupDocumentShareTest()
+
+	mockService.On("RevokeShare", mock.Anything, int64(7), int64(12), int64(8)).Return(nil).Once()
+	mockService.On("RevokeShare", mock.Anything, int64(7), int64(12), int64(9)).Return(service.ErrDocumentShareNotFound).Once()
+
+	for path, expectedStatus := range map[string]int{
+		"/api/documents/12/shares/8":   http.StatusNoContent,
+		"/api/documents/12/shares/9":   http.StatusNotFound,
+		"/api/documents/12/shares/abc": http.StatusBadRequest,
+	} {
+		req, err := http.NewRequest("DELETE", path, nil)
+		require.NoError(t, err)
+		rr := httptest.NewRecorder()
+		router.ServeHTTP(rr, req.WithContext(createAuthContext(7)))
+		assert.Equal(t, expectedStatus, rr.Code, path)
+	}
+	mockService.AssertExpectations(t)
+}
+
+func TestListSharedWithMe(t *testing.T) {
+	router, mockService := setupDocumentShareTest()
+
+	documents := []*models.SharedDocument{{ID: 12, HashedName: "contract.pdf", OwnerID: 7, Permission: constants.SharePermissionRedact}}
+	mockService.On("ListSharedWithMe", mock.Anything, int64(8)).Return(documents, nil).Once()
+
+	req, err := http.NewRequest("GET", "/api/documents/shared-with-me", nil)
+	require.NoError(t, err)
+	rr := httptest.NewRecorder()
+	router.ServeHTTP(rr, req.WithContext(createAuthContext(8)))
+
+	golden.AssertResponse(t, rr)
+	mockService.AssertExpectations(t)
+}
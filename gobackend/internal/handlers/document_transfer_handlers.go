@@ -0,0 +1,153 @@
+// Package handlers provides HTTP request handlers for the HideMe API.
+package handlers
+
+import (
+	"context"
+	"net/http"
+	"strconv"
+
+	"github.com/yasinhessnawi1/Hideme_Backend/internal/auth"
+	"github.com/yasinhessnawi1/Hideme_Backend/internal/constants"
+	"github.com/yasinhessnawi1/Hideme_Backend/internal/models"
+	"github.com/yasinhessnawi1/Hideme_Backend/internal/utils"
+)
+
+// DocumentTransferServiceInterface defines the service methods required for transferring documents between users.
+type DocumentTransferServiceInterface interface {
+	TransferDocuments(ctx context.Context, adminID int64, req *models.DocumentTransferRequest) (*models.DocumentTransfer, error)
+	ListTransfers(ctx context.Context, userID int64) ([]*models.DocumentTransfer, error)
+}
+
+// DocumentTransferHandler handles HTTP requests for administrators transferring documents
+// between users.
+type DocumentTransferHandler struct {
+	transferService DocumentTransferServiceInterface
+}
+
+// NewDocumentTransferHandler creates a new DocumentTransferHandler with the provided service.
+//
+// Parameters:
+//   - transferService: Service transferring documents and recording the transfers
+//
+// Returns:
+//   - A properly initialized DocumentTransferHandler
+func NewDocumentTransferHandler(transferService DocumentTransferServiceInterface) *DocumentTransferHandler {
+	return &DocumentTransferHandler{
+		transferService: transferService,
+	}
+}
+
+// TransferDocuments transfers selected documents, or all documents, of a user to another user.
+// Documents that cannot be transferred stay with their owner and are listed as failed.
+// The transfer is recorded as pending before the first document moves and completed once
+// every document is handled. Users don't belong to organizations yet, so there is no
+// organization boundary: an administrator can transfer documents between any two users.
+//
+// HTTP Method:
+//   - POST
+//
+// URL Path:
+//   - /api/admin/document-transfers
+//
+// Request Body:
+//   - JSON object with "from_user_id", "to_user_id", "reason" and optional "document_ids" fields
+//
+// Requires:
+//   - Authentication: Admin role
+//
+// Responses:
+//   - 201 Created: Transfer completed, with the transferred and failed documents
+//   - 400 Bad Request: Invalid request body or both users are the same
+//   - 401 Unauthorized: User not authenticated
+//   - 403 Forbidden: User not authorized (not admin)
+//   - 404 Not Found: User not found
+//   - 500 Internal Server Error: Server-side error
+//
+// @Summary Transfer documents between users
+// @Description Transfers selected documents, or all documents when none are given, from one user to any other user, re-encrypting them for the new owner and recording the transfer
+// @Tags Admin/Documents
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param transfer body models.DocumentTransferRequest true "Documents to transfer"
+// @Success 201 {object} utils.Response{data=models.DocumentTransfer} "Transfer recorded"
+// @Failure 400 {object} utils.Response{error=string} "Invalid request"
+// @Failure 401 {object} utils.Response{error=string} "User not authenticated"
+// @Failure 403 {object} utils.Response{error=string} "User not authorized"
+// @Failure 404 {object} utils.Response{error=string} "User not found"
+// @Failure 500 {object} utils.Response{error=string} "Server error"
+// @Router /admin/document-transfers [post]
+func (h *DocumentTransferHandler) TransferDocuments(w http.ResponseWriter, r *http.Request) {
+	adminID, ok := auth.GetUserID(r)
+	if !ok {
+		utils.Unauthorized(w, constants.MsgAuthRequired)
+		return
+	}
+
+	var req models.DocumentTransferRequest
+	if err := utils.DecodeAndValidate(r, &req); err != nil {
+		utils.ErrorFromAppError(w, utils.ParseError(err))
+		return
+	}
+
+	transfer, err := h.transferService.TransferDocuments(r.Context(), adminID, &req)
+	if err != nil {
+		utils.ErrorFromAppError(w, utils.ParseError(err))
+		return
+	}
+
+	utils.JSON(w, constants.StatusCreated, transfer)
+}
+
+// ListTransfers returns the most recent document transfers.
+//
+// HTTP Method:
+//   - GET
+//
+// URL Path:
+//   - /api/admin/document-transfers
+//
+// Query Parameters:
+//   - user_id: Only return transfers from or to this user (optional)
+//
+// Requires:
+//   - Authentication: Admin role
+//
+// Responses:
+//   - 200 OK: List of document transfers
+//   - 400 Bad Request: Invalid user_id parameter
+//   - 401 Unauthorized: User not authenticated
+//   - 403 Forbidden: User not authorized (not admin)
+//   - 500 Internal Server Error: Server-side error
+//
+// @Summary List document transfers
+// @Description Returns the audit trail of documents transferred between users, newest first
+// @Tags Admin/Documents
+// @Produce json
+// @Security BearerAuth
+// @Param user_id query int false "Only transfers from or to this user"
+// @Success 200 {object} utils.Response{data=[]models.DocumentTransfer} "List of document transfers"
+// @Failure 400 {object} utils.Response{error=string} "Invalid user_id parameter"
+// @Failure 401 {object} utils.Response{error=string} "User not authenticated"
+// @Failure 403 {object} utils.Response{error=string} "User not authorized"
+// @Failure 500 {object} utils.Response{error=string} "Server error"
+// @Router /admin/document-transfers [get]
+func (h *DocumentTransferHandler) ListTransfers(w http.ResponseWriter, r *http.Request) {
+	var userID int64
+	if userStr := r.URL.Query().Get(constants.QueryParamUserID); userStr != "" {
+		parsed, err := strconv.ParseInt(userStr, 10, 64)
+		if err != nil || parsed <= 0 {
+			utils.BadRequest(w, "Invalid user_id parameter", nil)
+			return
+		}
+		userID = parsed
+	}
+
+	transfers, err := h.transferService.ListTransfers(r.Context(), userID)
+	if err != nil {
+		utils.ErrorFromAppError(w, utils.ParseError(err))
+		return
+	}
+
+	utils.ListAll(w, r, transfers)
+}
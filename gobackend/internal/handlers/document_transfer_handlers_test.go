@@ -0,0 +1,163 @@
+package handlers_test
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+
+	"github.com/yasinhessnawi1/Hideme_Backend/internal/handlers"
+	"github.com/yasinhessnawi1/Hideme_Backend/internal/models"
+	"github.com/yasinhessnawi1/Hideme_Backend/internal/service"
+	"github.com/yasinhessnawi1/Hideme_Backend/internal/testsupport/golden"
+)
+
+// MockDocumentTransferService is a mock implementation of DocumentTransferServiceInterface
+type MockDocumentTransferService struct {
+	mock.Mock
+}
+
+func (m *MockDocumentTransferService) TransferDocuments(ctx context.Context, adminID int64, req *models.DocumentTransferRequest) (*models.DocumentTransfer, error) {
+	args := m.Called(ctx, adminID, req)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*models.DocumentTransfer), args.Error(1)
+}
+
+func (m *MockDocumentTransferService) ListTransfers(ctx context.Context, userID int64) ([]*models.DocumentTransfer, error) {
+	args := m.Called(ctx, userID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]*models.DocumentTransfer), args.Error(1)
+}
+
+func setupDocumentTransferTest() (*chi.Mux, *MockDocumentTransferService) {
+	mockService := new(MockDocumentTransferService)
+	handler := handlers.NewDocumentTransferHandler(mockService)
+
+	router := chi.NewRouter()
+	router.Post("/api/admin/document-transfers", handler.TransferDocuments)
+	router.Get("/api/admin/document-transfers", handler.ListTransfers)
+
+	return router, mockService
+}
+
+func TestDocumentTransferHandler_TransferDocuments(t *testing.T) {
+	router, mockService := setupDocumentTransferTest()
+
+	t.Run("Success", func(t *testing.T) {
+		expected := &models.DocumentTransferRequest{FromUserID: 4, ToUserID: 9, DocumentIDs: []int64{12, 15}, Reason: "Employee left the company"}
+		mockService.On("TransferDocuments", mock.Anything, int64(1), expected).Return(&models.DocumentTransfer{
+			ID:                3,
+			FromUserID:        4,
+			ToUserID:          9,
+			TransferredBy:     1,
+			DocumentIDs:       []int64{12},
+			FailedDocumentIDs: []int64{15},
+		}, nil).Once()
+
+		body := `{"from_user_id":4,"to_user_id":9,"document_ids":[12,15],"reason":"Employee left the company"}`
+		req, err := http.NewRequest("POST", "/api/admin/document-transfers", bytes.NewBufferString(body))
+		require.NoError(t, err)
+		req.Header.Set("Content-Type", "application/json")
+		rr := httptest.NewRecorder()
+		router.ServeHTTP(rr, req.WithContext(createAuthContext(1)))
+
+		golden.AssertResponse(t, rr)
+	})
+
+	t.Run("Invalid body", func(t *testing.T) {
+		for _, body := range []string{`{"from_user_id":4,"to_user_id":9}`, `{"from_user_id":4,`} {
+			req, err := http.NewRequest("POST", "/api/admin/document-transfers", bytes.NewBufferString(body))
+			require.NoError(t, err)
+			req.Header.Set("Content-Type", "application/json")
+			rr := httptest.NewRecorder()
+			router.ServeHTTP(rr, req.WithContext(createAuthContext(1)))
+
+			assert.Equal(t, http.StatusBadRequest, rr.Code, body)
+		}
+	})
+
+	t.Run("Same user", func(t *testing.T) {
+		mockService.On("TransferDocuments", mock.Anything, int64(1), mock.Anything).Return(nil, service.ErrTransferToSelf).Once()
+
+		body := `{"from_user_id":4,"to_user_id":4,"reason":"Employee left the company"}`
+		req, err := http.NewRequest("POST", "/api/admin/document-transfers", bytes.NewBufferString(body))
+		require.NoError(t, err)
+		req.Header.Set("Content-Type", "application/json")
+		rr := httptest.NewRecorder()
+		router.ServeHTTP(rr, req.WithContext(createAuthContext(1)))
+
+		assert.Equal(t, http.StatusBadRequest, rr.Code)
+	})
+
+	t.Run("Unknown user", func(t *testing.T) {
+		mockService.On("TransferDocuments", mock.Anything, int64(1), mock.Anything).Return(nil, service.ErrTransferUserNotFound).Once()
+
+		body := `{"from_user_id":4,"to_user_id":404,"reason":"Employee left the company"}`
+		req, err := http.NewRequest("POST", "/api/admin/document-transfers", bytes.NewBufferString(body))
+		require.NoError(t, err)
+		req.Header.Set("Content-Type", "application/json")
+		rr := httptest.NewRecorder()
+		router.ServeHTTP(rr, req.WithContext(createAuthContext(1)))
+
+		assert.Equal(t, http.StatusNotFound, rr.Code)
+	})
+
+	t.Run("Unauthorized", func(t *testing.T) {
+		req, err := http.NewRequest("POST", "/api/admin/document-transfers", bytes.NewBufferString(`{}`))
+		require.NoError(t, err)
+		rr := httptest.NewRecorder()
+		router.ServeHTTP(rr, req)
+
+		assert.Equal(t, http.StatusUnauthorized, rr.Code)
+	})
+
+	mockService.AssertExpectations(t)
+}
+
+func TestDocumentTransferHandler_ListTransfers(t *testing.T) {
+	router, mockService := setupDocumentTransferTest()
+
+	t.Run("Success", func(t *testing.T) {
+		mockService.On("ListTransfers", mock.Anything, int64(4)).Return([]*models.DocumentTransfer{{ID: 3, FromUserID: 4, ToUserID: 9}}, nil).Once()
+
+		req, err := http.NewRequest("GET", "/api/admin/document-transfers?user_id=4", nil)
+		require.NoError(t, err)
+		rr := httptest.NewRecorder()
+		router.ServeHTTP(rr, req.WithContext(createAuthContext(1)))
+
+		golden.AssertResponse(t, rr)
+	})
+
+	t.Run("Invalid user", func(t *testing.T) {
+		req, err := http.NewRequest("GET", "/api/admin/document-transfers?user_id=abc", nil)
+		require.NoError(t, err)
+		rr := httptest.NewRecorder()
+		router.ServeHTTP(rr, req.WithContext(createAuthContext(1)))
+
+		assert.Equal(t, http.StatusBadRequest, rr.Code)
+	})
+
+	t.Run("Service Error", func(t *testing.T) {
+		mockService.On("ListTransfers", mock.Anything, int64(0)).Return(nil, errors.New("database error")).Once()
+
+		req, err := http.NewRequest("GET", "/api/admin/document-transfers", nil)
+		require.NoError(t, err)
+		rr := httptest.NewRecorder()
+		router.ServeHTTP(rr, req.WithContext(createAuthContext(1)))
+
+		assert.Equal(t, http.StatusInternalServerError, rr.Code)
+	})
+
+	mockService.AssertExpectations(t)
+}
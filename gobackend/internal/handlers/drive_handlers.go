@@ -0,0 +1,365 @@
+// Package handlers provides HTTP request handlers for the HideMe API.
+package handlers
+
+import (
+	"context"
+	"io"
+	"mime"
+	"net/http"
+	"strconv"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/rs/zerolog/log"
+
+	"github.com/yasinhessnawi1/Hideme_Backend/internal/auth"
+	"github.com/yasinhessnawi1/Hideme_Backend/internal/constants"
+	"github.com/yasinhessnawi1/Hideme_Backend/internal/models"
+	"github.com/yasinhessnawi1/Hideme_Backend/internal/utils"
+)
+
+// DriveServiceInterface defines the service methods required for the cloud drive connectors.
+type DriveServiceInterface interface {
+	ListProviders(ctx context.Context, userID int64) ([]*models.DriveProviderStatus, error)
+	Authorize(ctx context.Context, userID int64, provider string) (*models.DriveAuthorization, error)
+	Connect(ctx context.Context, userID int64, req *models.DriveConnectRequest) (*models.DriveProviderStatus, error)
+	Disconnect(ctx context.Context, userID int64, provider string) error
+	ListFiles(ctx context.Context, userID int64, provider, folderID, pageToken string) (*models.DriveFileList, error)
+	OpenFile(ctx context.Context, userID int64, provider, fileID string) (*models.DriveDownload, error)
+}
+
+// DriveHandler handles HTTP requests for importing documents from cloud drives.
+// Files are streamed through to the client, which processes them and uploads
+// the redaction results like any other document.
+type DriveHandler struct {
+	driveService DriveServiceInterface
+}
+
+// NewDriveHandler creates a new DriveHandler with the provided service.
+//
+// Parameters:
+//   - driveService: Service connecting and reading cloud drives
+//
+// Returns:
+//   - A properly initialized DriveHandler
+func NewDriveHandler(driveService DriveServiceInterface) *DriveHandler {
+	return &DriveHandler{
+		driveService: driveService,
+	}
+}
+
+// ListProviders lists the supported cloud drives, whether this server offers them
+// and whether the user has connected them.
+//
+// HTTP Method:
+//   - GET
+//
+// URL Path:
+//   - /api/drives
+//
+// Requires:
+//   - Authentication: User must be logged in
+//
+// Responses:
+//   - 200 OK: Status of every cloud drive
+//   - 401 Unauthorized: User not authenticated
+//   - 500 Internal Server Error: Server-side error
+//
+// @Summary List cloud drives
+// @Description Lists Google Drive, OneDrive and SharePoint with whether they are configured on this server and connected by the user
+// @Tags Drives
+// @Produce json
+// @Security BearerAuth
+// @Success 200 {object} utils.Response{data=[]models.DriveProviderStatus} "Cloud drives"
+// @Failure 401 {object} utils.Response{error=string} "User not authenticated"
+// @Failure 500 {object} utils.Response{error=string} "Server error"
+// @Router /drives [get]
+func (h *DriveHandler) ListProviders(w http.ResponseWriter, r *http.Request) {
+	userID, ok := auth.GetUserID(r)
+	if !ok {
+		utils.Unauthorized(w, constants.MsgAuthRequired)
+		return
+	}
+
+	statuses, err := h.driveService.ListProviders(r.Context(), userID)
+	if err != nil {
+		utils.ErrorFromAppError(w, utils.ParseError(err))
+		return
+	}
+
+	utils.ListAll(w, r, statuses)
+}
+
+// Authorize starts connecting a cloud drive and returns the provider's consent page.
+// The provider sends the user back to the configured redirect URL with a code and
+// state, which the frontend passes to POST /api/drives/connect.
+//
+// HTTP Method:
+//   - POST
+//
+// URL Path:
+//   - /api/drives/{provider}/authorize
+//
+// Requires:
+//   - Authentication: User must be logged in
+//
+// Responses:
+//   - 200 OK: Consent page to send the user to
+//   - 400 Bad Request: Unknown provider, or not configured on this server
+//   - 401 Unauthorized: User not authenticated
+//   - 500 Internal Server Error: Server-side error
+//
+// @Summary Authorize a cloud drive
+// @Description Returns the consent page of the provider; it redirects back to the frontend with a code and state
+// @Tags Drives
+// @Produce json
+// @Security BearerAuth
+// @Param provider path string true "google_drive, onedrive or sharepoint"
+// @Success 200 {object} utils.Response{data=models.DriveAuthorization} "Consent page"
+// @Failure 400 {object} utils.Response{error=string} "Unknown or unavailable provider"
+// @Failure 401 {object} utils.Response{error=string} "User not authenticated"
+// @Failure 500 {object} utils.Response{error=string} "Server error"
+// @Router /drives/{provider}/authorize [post]
+func (h *DriveHandler) Authorize(w http.ResponseWriter, r *http.Request) {
+	userID, ok := auth.GetUserID(r)
+	if !ok {
+		utils.Unauthorized(w, constants.MsgAuthRequired)
+		return
+	}
+
+	authorization, err := h.driveService.Authorize(r.Context(), userID, chi.URLParam(r, constants.ParamProvider))
+	if err != nil {
+		utils.ErrorFromAppError(w, utils.ParseError(err))
+		return
+	}
+
+	utils.JSON(w, constants.StatusOK, authorization)
+}
+
+// Connect completes connecting a cloud drive with the code and state the provider
+// sent the user back with. The state must have been issued to the same user.
+//
+// HTTP Method:
+//   - POST
+//
+// URL Path:
+//   - /api/drives/connect
+//
+// Requires:
+//   - Authentication: User must be logged in
+//   - Body: DriveConnectRequest
+//
+// Responses:
+//   - 200 OK: Drive connected
+//   - 400 Bad Request: Invalid body, expired or foreign state, or rejected code
+//   - 401 Unauthorized: User not authenticated
+//   - 500 Internal Server Error: Server-side error
+//
+// @Summary Connect a cloud drive
+// @Description Exchanges the authorization code for tokens, which are stored encrypted
+// @Tags Drives
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param request body models.DriveConnectRequest true "Code and state from the redirect"
+// @Success 200 {object} utils.Response{data=models.DriveProviderStatus} "Drive connected"
+// @Failure 400 {object} utils.Response{error=string} "Invalid authorization"
+// @Failure 401 {object} utils.Response{error=string} "User not authenticated"
+// @Failure 500 {object} utils.Response{error=string} "Server error"
+// @Router /drives/connect [post]
+func (h *DriveHandler) Connect(w http.ResponseWriter, r *http.Request) {
+	userID, ok := auth.GetUserID(r)
+	if !ok {
+		utils.Unauthorized(w, constants.MsgAuthRequired)
+		return
+	}
+
+	var req models.DriveConnectRequest
+	if err := utils.DecodeAndValidate(r, &req); err != nil {
+		utils.ErrorFromAppError(w, utils.ParseError(err))
+		return
+	}
+
+	status, err := h.driveService.Connect(r.Context(), userID, &req)
+	if err != nil {
+		utils.ErrorFromAppError(w, utils.ParseError(err))
+		return
+	}
+
+	utils.JSON(w, constants.StatusOK, status)
+}
+
+// Disconnect removes the user's authorization of a cloud drive.
+//
+// HTTP Method:
+//   - DELETE
+//
+// URL Path:
+//   - /api/drives/{provider}
+//
+// Requires:
+//   - Authentication: User must be logged in
+//
+// Responses:
+//   - 204 No Content: Drive disconnected
+//   - 400 Bad Request: Unknown provider
+//   - 401 Unauthorized: User not authenticated
+//   - 404 Not Found: Drive not connected
+//   - 500 Internal Server Error: Server-side error
+//
+// @Summary Disconnect a cloud drive
+// @Description Deletes the stored tokens; the authorization can be revoked at the provider as well
+// @Tags Drives
+// @Security BearerAuth
+// @Param provider path string true "google_drive, onedrive or sharepoint"
+// @Success 204 "Drive disconnected"
+// @Failure 400 {object} utils.Response{error=string} "Unknown provider"
+// @Failure 401 {object} utils.Response{error=string} "User not authenticated"
+// @Failure 404 {object} utils.Response{error=string} "Drive not connected"
+// @Failure 500 {object} utils.Response{error=string} "Server error"
+// @Router /drives/{provider} [delete]
+func (h *DriveHandler) Disconnect(w http.ResponseWriter, r *http.Request) {
+	userID, ok := auth.GetUserID(r)
+	if !ok {
+		utils.Unauthorized(w, constants.MsgAuthRequired)
+		return
+	}
+
+	if err := h.driveService.Disconnect(r.Context(), userID, chi.URLParam(r, constants.ParamProvider)); err != nil {
+		utils.ErrorFromAppError(w, utils.ParseError(err))
+		return
+	}
+
+	utils.NoContent(w)
+}
+
+// ListFiles lists a page of a folder in a connected cloud drive.
+//
+// HTTP Method:
+//   - GET
+//
+// URL Path:
+//   - /api/drives/{provider}/files
+//
+// Query Parameters:
+//   - folder: The folder ID to list; the top level if omitted
+//   - page_token: The next_page_token of the previous page
+//
+// Requires:
+//   - Authentication: User must be logged in
+//
+// Responses:
+//   - 200 OK: Page of files and folders
+//   - 400 Bad Request: Unknown provider, drive not connected, or invalid page token
+//   - 401 Unauthorized: User not authenticated
+//   - 404 Not Found: Folder not found
+//   - 500 Internal Server Error: Server-side error
+//
+// @Summary List cloud drive files
+// @Description Lists a folder; SharePoint lists the user's sites at the top level
+// @Tags Drives
+// @Produce json
+// @Security BearerAuth
+// @Param provider path string true "google_drive, onedrive or sharepoint"
+// @Param folder query string false "Folder ID"
+// @Param page_token query string false "Next page token"
+// @Success 200 {object} utils.Response{data=models.DriveFileList} "Files and folders"
+// @Failure 400 {object} utils.Response{error=string} "Invalid request or drive not connected"
+// @Failure 401 {object} utils.Response{error=string} "User not authenticated"
+// @Failure 404 {object} utils.Response{error=string} "Folder not found"
+// @Failure 500 {object} utils.Response{error=string} "Server error"
+// @Router /drives/{provider}/files [get]
+func (h *DriveHandler) ListFiles(w http.ResponseWriter, r *http.Request) {
+	userID, ok := auth.GetUserID(r)
+	if !ok {
+		utils.Unauthorized(w, constants.MsgAuthRequired)
+		return
+	}
+
+	// The cursor of the list envelope is the page token of the provider
+	query := r.URL.Query()
+	pageToken := query.Get(constants.QueryParamCursor)
+	if pageToken == "" {
+		pageToken = query.Get(constants.QueryParamPageToken)
+	}
+	list, err := h.driveService.ListFiles(r.Context(), userID, chi.URLParam(r, constants.ParamProvider),
+		query.Get(constants.QueryParamFolder), pageToken)
+	if err != nil {
+		utils.ErrorFromAppError(w, utils.ParseError(err))
+		return
+	}
+
+	// Providers don't report the number of files in a folder
+	envelope := utils.ListEnvelope{Items: list.Files}
+	if list.Files == nil {
+		envelope.Items = []*models.DriveFile{}
+	}
+	if list.NextPageToken != "" {
+		envelope.PageInfo.NextCursor = &list.NextPageToken
+	}
+	utils.List(w, r, envelope, utils.Response{Success: constants.ResponseSuccess, Data: list})
+}
+
+// DownloadFile streams a file from a connected cloud drive for import.
+// Native Google documents are exported as PDF.
+//
+// HTTP Method:
+//   - GET
+//
+// URL Path:
+//   - /api/drives/{provider}/files/{fileID}/content
+//
+// Requires:
+//   - Authentication: User must be logged in
+//
+// Responses:
+//   - 200 OK: Content of the file
+//   - 400 Bad Request: Unknown provider, drive not connected, folder, or file too large
+//   - 401 Unauthorized: User not authenticated
+//   - 404 Not Found: File not found
+//   - 500 Internal Server Error: Server-side error
+//
+// @Summary Download a cloud drive file
+// @Description Streams the file content for processing; the result is uploaded through POST /documents
+// @Tags Drives
+// @Produce octet-stream
+// @Security BearerAuth
+// @Param provider path string true "google_drive, onedrive or sharepoint"
+// @Param fileID path string true "File ID"
+// @Success 200 {file} binary "File content"
+// @Failure 400 {object} utils.Response{error=string} "Invalid request or file too large"
+// @Failure 401 {object} utils.Response{error=string} "User not authenticated"
+// @Failure 404 {object} utils.Response{error=string} "File not found"
+// @Failure 500 {object} utils.Response{error=string} "Server error"
+// @Router /drives/{provider}/files/{fileID}/content [get]
+func (h *DriveHandler) DownloadFile(w http.ResponseWriter, r *http.Request) {
+	userID, ok := auth.GetUserID(r)
+	if !ok {
+		utils.Unauthorized(w, constants.MsgAuthRequired)
+		return
+	}
+
+	provider := chi.URLParam(r, constants.ParamProvider)
+	download, err := h.driveService.OpenFile(r.Context(), userID, provider, chi.URLParam(r, constants.ParamFileID))
+	if err != nil {
+		utils.ErrorFromAppError(w, utils.ParseError(err))
+		return
+	}
+	defer download.Body.Close()
+
+	contentType := download.MimeType
+	if contentType == "" {
+		contentType = constants.ContentTypeOctetStream
+	}
+	w.Header().Set(constants.HeaderContentType, contentType)
+	w.Header().Set(constants.HeaderContentDisposition, mime.FormatMediaType("attachment", map[string]string{"filename": download.Name}))
+	w.Header().Set(constants.HeaderCacheControl, constants.CacheControlNoStore)
+	if download.Size > 0 {
+		w.Header().Set(constants.HeaderContentLength, strconv.FormatInt(download.Size, 10))
+	}
+	w.WriteHeader(constants.StatusOK)
+
+	// Once streaming has started, failures can only cut the response short
+	if _, err := io.Copy(w, download.Body); err != nil {
+		log.Error().Err(err).Int64("user_id", userID).Str("provider", provider).Msg("Failed to stream cloud drive file")
+	}
+}
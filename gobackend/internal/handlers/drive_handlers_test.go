@@ -0,0 +1,279 @@
+package handlers_test
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+
+	"github.com/yasinhessnawi1/Hideme_Backend/internal/constants"
+	"github.com/yasinhessnawi1/Hideme_Backend/internal/handlers"
+	"github.com/yasinhessnawi1/Hideme_Backend/internal/models"
+	"github.com/yasinhessnawi1/Hideme_Backend/internal/testsupport/golden"
+	"github.com/yasinhessnawi1/Hideme_Backend/internal/utils"
+)
+
+// MockDriveService is a mock implementation of the DriveService
+type MockDriveService struct {
+	mock.Mock
+}
+
+func (m *MockDriveService) ListProviders(ctx context.Context, userID int64) ([]*models.DriveProviderStatus, error) {
+	args := m.Called(ctx, userID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]*models.DriveProviderStatus), args.Error(1)
+}
+
+func (m *MockDriveService) Authorize(ctx context.Context, userID int64, provider string) (*models.DriveAuthorization, error) {
+	args := m.Called(ctx, userID, provider)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*models.DriveAuthorization), args.Error(1)
+}
+
+func (m *MockDriveService) Connect(ctx context.Context, userID int64, req *models.DriveConnectRequest) (*models.DriveProviderStatus, error) {
+	args := m.Called(ctx, userID, req)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*models.DriveProviderStatus), args.Error(1)
+}
+
+func (m *MockDriveService) Disconnect(ctx context.Context, userID int64, provider string) error {
+	args := m.Called(ctx, userID, provider)
+	return args.Error(0)
+}
+
+func (m *MockDriveService) ListFiles(ctx context.Context, userID int64, provider, folderID, pageToken string) (*models.DriveFileList, error) {
+	args := m.Called(ctx, userID, provider, folderID, pageToken)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*models.DriveFileList), args.Error(1)
+}
+
+func (m *MockDriveService) OpenFile(ctx context.Context, userID int64, provider, fileID string) (*models.DriveDownload, error) {
+	args := m.Called(ctx, userID, provider, fileID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*models.DriveDownload), args.Error(1)
+}
+
+func setupDriveTest() (*chi.Mux, *MockDriveService) {
+	mockService := new(MockDriveService)
+	handler := handlers.NewDriveHandler(mockService)
+
+	router := chi.NewRouter()
+	router.Get("/api/drives", handler.ListProviders)
+	router.Post("/api/drives/connect", handler.Connect)
+	router.Post("/api/drives/{provider}/authorize", handler.Authorize)
+	router.Delete("/api/drives/{provider}", handler.Disconnect)
+	router.Get("/api/drives/{provider}/files", handler.ListFiles)
+	router.Get("/api/drives/{provider}/files/{fileID}/content", handler.DownloadFile)
+
+	return router, mockService
+}
+
+func TestDriveListProviders(t *testing.T) {
+	router, mockService := setupDriveTest()
+
+	t.Run("Success", func(t *testing.T) {
+		mockService.On("ListProviders", mock.Anything, int64(1)).Return([]*models.DriveProviderStatus{
+			{Provider: constants.DriveProviderGoogle, Configured: true},
+		}, nil).Once()
+
+		req, err := http.NewRequest("GET", "/api/drives", nil)
+		require.NoError(t, err)
+		req = req.WithContext(createAuthContext(1))
+		rr := httptest.NewRecorder()
+		router.ServeHTTP(rr, req)
+
+		golden.AssertResponse(t, rr)
+	})
+
+	t.Run("Unauthorized", func(t *testing.T) {
+		req, err := http.NewRequest("GET", "/api/drives", nil)
+		require.NoError(t, err)
+		rr := httptest.NewRecorder()
+		router.ServeHTTP(rr, req)
+
+		assert.Equal(t, http.StatusUnauthorized, rr.Code)
+	})
+
+	mockService.AssertExpectations(t)
+}
+
+func TestDriveAuthorizeAndConnect(t *testing.T) {
+	router, mockService := setupDriveTest()
+
+	t.Run("Authorize", func(t *testing.T) {
+		mockService.On("Authorize", mock.Anything, int64(1), constants.DriveProviderOneDrive).
+			Return(&models.DriveAuthorization{AuthorizationURL: "https://login.test/authorize"}, nil).Once()
+
+		req, err := http.NewRequest("POST", "/api/drives/onedrive/authorize", nil)
+		require.NoError(t, err)
+		req = req.WithContext(createAuthContext(1))
+		rr := httptest.NewRecorder()
+		router.ServeHTTP(rr, req)
+
+		golden.AssertResponse(t, rr)
+	})
+
+	t.Run("Connect", func(t *testing.T) {
+		mockService.On("Connect", mock.Anything, int64(1), &models.DriveConnectRequest{Code: "code", State: "state"}).
+			Return(&models.DriveProviderStatus{Provider: constants.DriveProviderOneDrive, Configured: true, Connected: true}, nil).Once()
+
+		req, err := http.NewRequest("POST", "/api/drives/connect", bytes.NewBufferString(`{"code":"code","state":"state"}`))
+		require.NoError(t, err)
+		req.Header.Set("Content-Type", "application/json")
+		req = req.WithContext(createAuthContext(1))
+		rr := httptest.NewRecorder()
+		router.ServeHTTP(rr, req)
+
+		golden.AssertResponse(t, rr)
+	})
+
+	t.Run("Connect Without State", func(t *testing.T) {
+		req, err := http.NewRequest("POST", "/api/drives/connect", bytes.NewBufferString(`{"code":"code"}`))
+		require.NoError(t, err)
+		req.Header.Set("Content-Type", "application/json")
+		req = req.WithContext(createAuthContext(1))
+		rr := httptest.NewRecorder()
+		router.ServeHTTP(rr, req)
+
+		assert.Equal(t, http.StatusBadRequest, rr.Code)
+	})
+
+	t.Run("Connect Invalid State", func(t *testing.T) {
+		mockService.On("Connect", mock.Anything, int64(1), mock.Anything).
+			Return(nil, utils.NewBadRequestError(constants.MsgDriveStateInvalid)).Once()
+
+		req, err := http.NewRequest("POST", "/api/drives/connect", bytes.NewBufferString(`{"code":"code","state":"forged"}`))
+		require.NoError(t, err)
+		req.Header.Set("Content-Type", "application/json")
+		req = req.WithContext(createAuthContext(1))
+		rr := httptest.NewRecorder()
+		router.ServeHTTP(rr, req)
+
+		assert.Equal(t, http.StatusBadRequest, rr.Code)
+	})
+
+	mockService.AssertExpectations(t)
+}
+
+func TestDriveDisconnect(t *testing.T) {
+	router, mockService := setupDriveTest()
+
+	t.Run("Success", func(t *testing.T) {
+		mockService.On("Disconnect", mock.Anything, int64(1), constants.DriveProviderGoogle).Return(nil).Once()
+
+		req, err := http.NewRequest("DELETE", "/api/drives/google_drive", nil)
+		require.NoError(t, err)
+		req = req.WithContext(createAuthContext(1))
+		rr := httptest.NewRecorder()
+		router.ServeHTTP(rr, req)
+
+		assert.Equal(t, http.StatusNoContent, rr.Code)
+	})
+
+	t.Run("Not Connected", func(t *testing.T) {
+		mockService.On("Disconnect", mock.Anything, int64(1), constants.DriveProviderGoogle).
+			Return(utils.NewNotFoundError("Drive connection", constants.DriveProviderGoogle)).Once()
+
+		req, err := http.NewRequest("DELETE", "/api/drives/google_drive", nil)
+		require.NoError(t, err)
+		req = req.WithContext(createAuthContext(1))
+		rr := httptest.NewRecorder()
+		router.ServeHTTP(rr, req)
+
+		assert.Equal(t, http.StatusNotFound, rr.Code)
+	})
+
+	mockService.AssertExpectations(t)
+}
+
+func TestDriveListFiles(t *testing.T) {
+	router, mockService := setupDriveTest()
+
+	t.Run("Folder", func(t *testing.T) {
+		mockService.On("ListFiles", mock.Anything, int64(1), constants.DriveProviderGoogle, "folder1", "page2").
+			Return(&models.DriveFileList{Files: []*models.DriveFile{{ID: "f1", Name: "lease.pdf"}}}, nil).Once()
+
+		req, err := http.NewRequest("GET", "/api/drives/google_drive/files?folder=folder1&page_token=page2", nil)
+		require.NoError(t, err)
+		req.Header.Set(constants.HeaderXAPIVersion, "2")
+		req = req.WithContext(createAuthContext(1))
+		rr := httptest.NewRecorder()
+		router.ServeHTTP(rr, req)
+
+		golden.AssertResponse(t, rr)
+	})
+
+	t.Run("Cursor", func(t *testing.T) {
+		// The page token of the provider is the cursor of the list envelope
+		mockService.On("ListFiles", mock.Anything, int64(1), constants.DriveProviderGoogle, "", "page3").
+			Return(&models.DriveFileList{Files: []*models.DriveFile{{ID: "f2"}}, NextPageToken: "page4"}, nil).Once()
+
+		req, err := http.NewRequest("GET", "/api/drives/google_drive/files?cursor=page3", nil)
+		require.NoError(t, err)
+		req.Header.Set(constants.HeaderXAPIVersion, "2")
+		rr := httptest.NewRecorder()
+		router.ServeHTTP(rr, req.WithContext(createAuthContext(1)))
+
+		golden.AssertResponse(t, rr)
+	})
+
+	mockService.AssertExpectations(t)
+}
+
+func TestDriveDownloadFile(t *testing.T) {
+	router, mockService := setupDriveTest()
+
+	t.Run("Success", func(t *testing.T) {
+		mockService.On("OpenFile", mock.Anything, int64(1), constants.DriveProviderSharePoint, "drive1:item2").
+			Return(&models.DriveDownload{
+				Name:     "brief final.pdf",
+				MimeType: "application/pdf",
+				Size:     7,
+				Body:     io.NopCloser(strings.NewReader("content")),
+			}, nil).Once()
+
+		req, err := http.NewRequest("GET", "/api/drives/sharepoint/files/drive1:item2/content", nil)
+		require.NoError(t, err)
+		req = req.WithContext(createAuthContext(1))
+		rr := httptest.NewRecorder()
+		router.ServeHTTP(rr, req)
+
+		assert.Equal(t, http.StatusOK, rr.Code)
+		assert.Equal(t, "content", rr.Body.String())
+		assert.Equal(t, "application/pdf", rr.Header().Get(constants.HeaderContentType))
+		assert.Equal(t, `attachment; filename="brief final.pdf"`, rr.Header().Get(constants.HeaderContentDisposition))
+	})
+
+	t.Run("Too Large", func(t *testing.T) {
+		mockService.On("OpenFile", mock.Anything, int64(1), constants.DriveProviderGoogle, "big").
+			Return(nil, utils.NewBadRequestError(constants.MsgDriveFileTooLarge)).Once()
+
+		req, err := http.NewRequest("GET", "/api/drives/google_drive/files/big/content", nil)
+		require.NoError(t, err)
+		req = req.WithContext(createAuthContext(1))
+		rr := httptest.NewRecorder()
+		router.ServeHTTP(rr, req)
+
+		assert.Equal(t, http.StatusBadRequest, rr.Code)
+	})
+
+	mockService.AssertExpectations(t)
+}
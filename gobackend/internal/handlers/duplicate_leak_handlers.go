@@ -0,0 +1,96 @@
+// Package handlers provides HTTP request handlers for the HideMe API.
+package handlers
+
+import (
+	"context"
+	"net/http"
+	"strconv"
+
+	"github.com/yasinhessnawi1/Hideme_Backend/internal/auth"
+	"github.com/yasinhessnawi1/Hideme_Backend/internal/constants"
+	"github.com/yasinhessnawi1/Hideme_Backend/internal/models"
+	"github.com/yasinhessnawi1/Hideme_Backend/internal/utils"
+)
+
+// DuplicateLeakServiceInterface defines the service methods required for the report of values leaking in several documents.
+type DuplicateLeakServiceInterface interface {
+	GetDuplicateLeakReport(ctx context.Context, userID int64, minDocuments int) (*models.DuplicateLeakReport, error)
+}
+
+// DuplicateLeakHandler handles HTTP requests for the report of detected values that leak
+// in several of the user's documents.
+type DuplicateLeakHandler struct {
+	hashService DuplicateLeakServiceInterface
+}
+
+// NewDuplicateLeakHandler creates a new DuplicateLeakHandler with the provided service.
+//
+// Parameters:
+//   - hashService: Service aggregating the hashes of detected values
+//
+// Returns:
+//   - A properly initialized DuplicateLeakHandler
+func NewDuplicateLeakHandler(hashService DuplicateLeakServiceInterface) *DuplicateLeakHandler {
+	return &DuplicateLeakHandler{
+		hashService: hashService,
+	}
+}
+
+// GetDuplicateLeakReport counts the distinct values detected in the user's documents and lists
+// the values detected in several of them. Values are identified by a fingerprint of their
+// salted hash and are never revealed.
+//
+// HTTP Method:
+//   - GET
+//
+// URL Path:
+//   - /api/documents/duplicate-leaks
+//
+// Query Parameters:
+//   - min_documents: The number of documents a value must be detected in, at least 2 (default 2)
+//
+// Requires:
+//   - Authentication: User must be logged in
+//
+// Responses:
+//   - 200 OK: The distinct values per entity type and the repeated values
+//   - 400 Bad Request: Invalid min_documents parameter
+//   - 401 Unauthorized: User not authenticated
+//   - 500 Internal Server Error: Server-side error
+//
+// @Summary Get the duplicate leak report
+// @Description Counts distinct detected values per entity type and lists the values detected in several documents, identified by a fingerprint of their salted hash
+// @Tags Documents
+// @Produce json
+// @Security BearerAuth
+// @Param min_documents query int false "Number of documents a value must be detected in" default(2) minimum(2)
+// @Success 200 {object} utils.Response{data=models.DuplicateLeakReport} "Duplicate leak report"
+// @Failure 400 {object} utils.Response{error=string} "Invalid min_documents parameter"
+// @Failure 401 {object} utils.Response{error=string} "User not authenticated"
+// @Failure 500 {object} utils.Response{error=string} "Server error"
+// @Router /documents/duplicate-leaks [get]
+func (h *DuplicateLeakHandler) GetDuplicateLeakReport(w http.ResponseWriter, r *http.Request) {
+	userID, ok := auth.GetUserID(r)
+	if !ok {
+		utils.Unauthorized(w, constants.MsgAuthRequired)
+		return
+	}
+
+	minDocuments := constants.DefaultDuplicateLeakMinDocuments
+	if minStr := r.URL.Query().Get(constants.QueryParamMinDocuments); minStr != "" {
+		parsed, err := strconv.Atoi(minStr)
+		if err != nil || parsed < 2 {
+			utils.BadRequest(w, "Invalid min_documents parameter", nil)
+			return
+		}
+		minDocuments = parsed
+	}
+
+	report, err := h.hashService.GetDuplicateLeakReport(r.Context(), userID, minDocuments)
+	if err != nil {
+		utils.ErrorFromAppError(w, utils.ParseError(err))
+		return
+	}
+
+	utils.JSON(w, constants.StatusOK, report)
+}
@@ -0,0 +1,105 @@
+package handlers_test
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+
+	"github.com/yasinhessnawi1/Hideme_Backend/internal/handlers"
+	"github.com/yasinhessnawi1/Hideme_Backend/internal/models"
+	"github.com/yasinhessnawi1/Hideme_Backend/internal/testsupport/golden"
+)
+
+// MockDuplicateLeakService is a mock implementation of the EntityHashService
+type MockDuplicateLeakService struct {
+	mock.Mock
+}
+
+func (m *MockDuplicateLeakService) GetDuplicateLeakReport(ctx context.Context, userID int64, minDocuments int) (*models.DuplicateLeakReport, error) {
+	args := m.Called(ctx, userID, minDocuments)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*models.DuplicateLeakReport), args.Error(1)
+}
+
+func TestDuplicateLeakHandler_GetDuplicateLeakReport(t *testing.T) {
+	mockService := new(MockDuplicateLeakService)
+	handler := handlers.NewDuplicateLeakHandler(mockService)
+	router := chi.NewRouter()
+	router.Get("/api/documents/duplicate-leaks", handler.GetDuplicateLeakReport)
+
+	t.Run("Success", func(t *testing.T) {
+		mockService.On("GetDuplicateLeakReport", mock.Anything, int64(1), 3).Return(&models.DuplicateLeakReport{
+			MinDocuments:   3,
+			DistinctValues: 12,
+			EntityTypes:    []*models.EntityTypeCount{{EntityType: "NATIONAL_ID", DistinctValues: 12, Occurrences: 20}},
+			Duplicates: []*models.DuplicateLeak{{
+				EntityType:    "NATIONAL_ID",
+				ValueHash:     "0123456789abcdef0123456789abcdef0123456789abcdef0123456789abcdef",
+				Fingerprint:   "0123456789ab",
+				DocumentCount: 3,
+				Occurrences:   4,
+				DocumentIDs:   []int64{4, 8, 15},
+			}},
+		}, nil).Once()
+
+		req, err := http.NewRequest("GET", "/api/documents/duplicate-leaks?min_documents=3", nil)
+		require.NoError(t, err)
+		rr := httptest.NewRecorder()
+		router.ServeHTTP(rr, req.WithContext(createAuthContext(1)))
+
+		golden.AssertResponse(t, rr)
+	})
+
+	t.Run("Default minimum", func(t *testing.T) {
+		mockService.On("GetDuplicateLeakReport", mock.Anything, int64(2), 2).Return(&models.DuplicateLeakReport{MinDocuments: 2}, nil).Once()
+
+		req, err := http.NewRequest("GET", "/api/documents/duplicate-leaks", nil)
+		require.NoError(t, err)
+		rr := httptest.NewRecorder()
+		router.ServeHTTP(rr, req.WithContext(createAuthContext(2)))
+
+		assert.Equal(t, http.StatusOK, rr.Code)
+	})
+
+	t.Run("Invalid minimum", func(t *testing.T) {
+		for _, value := range []string{"1", "abc"} {
+			req, err := http.NewRequest("GET", "/api/documents/duplicate-leaks?min_documents="+value, nil)
+			require.NoError(t, err)
+			rr := httptest.NewRecorder()
+			router.ServeHTTP(rr, req.WithContext(createAuthContext(1)))
+
+			assert.Equal(t, http.StatusBadRequest, rr.Code, value)
+		}
+	})
+
+	t.Run("Service Error", func(t *testing.T) {
+		mockService.On("GetDuplicateLeakReport", mock.Anything, int64(3), 2).Return(nil, errors.New("database error")).Once()
+
+		req, err := http.NewRequest("GET", "/api/documents/duplicate-leaks", nil)
+		require.NoError(t, err)
+		rr := httptest.NewRecorder()
+		router.ServeHTTP(rr, req.WithContext(createAuthContext(3)))
+
+		assert.Equal(t, http.StatusInternalServerError, rr.Code)
+	})
+
+	t.Run("Unauthorized", func(t *testing.T) {
+		req, err := http.NewRequest("GET", "/api/documents/duplicate-leaks", nil)
+		require.NoError(t, err)
+		rr := httptest.NewRecorder()
+		router.ServeHTTP(rr, req)
+
+		assert.Equal(t, http.StatusUnauthorized, rr.Code)
+	})
+
+	mockService.AssertExpectations(t)
+}
@@ -0,0 +1,313 @@
+// Package handlers provides HTTP request handlers for the HideMe API.
+package handlers
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"strconv"
+
+	"github.com/go-chi/chi/v5"
+
+	"github.com/yasinhessnawi1/Hideme_Backend/internal/auth"
+	"github.com/yasinhessnawi1/Hideme_Backend/internal/constants"
+	"github.com/yasinhessnawi1/Hideme_Backend/internal/models"
+	"github.com/yasinhessnawi1/Hideme_Backend/internal/service"
+	"github.com/yasinhessnawi1/Hideme_Backend/internal/utils"
+)
+
+// ExportServiceInterface defines the service methods required for export destinations and deliveries.
+type ExportServiceInterface interface {
+	ListDestinations(ctx context.Context, userID int64) ([]*models.ExportDestination, error)
+	CreateDestination(ctx context.Context, userID int64, req *models.ExportDestinationCreate) (*models.ExportDestination, error)
+	DeleteDestination(ctx context.Context, userID, destinationID int64) error
+	ListDeliveries(ctx context.Context, userID, documentID int64) ([]*models.ExportDelivery, error)
+	Deliver(ctx context.Context, userID, documentID, destinationID int64) (*models.ExportDelivery, error)
+}
+
+// ExportHandler handles HTTP requests for the destinations redacted documents are
+// delivered to, and the delivery status of documents.
+type ExportHandler struct {
+	exportService ExportServiceInterface
+}
+
+// NewExportHandler creates a new ExportHandler with the provided service.
+//
+// Parameters:
+//   - exportService: Service managing destinations and delivering documents
+//
+// Returns:
+//   - A properly initialized ExportHandler
+func NewExportHandler(exportService ExportServiceInterface) *ExportHandler {
+	return &ExportHandler{
+		exportService: exportService,
+	}
+}
+
+// ListDestinations lists the user's export destinations. Credentials are never returned.
+//
+// HTTP Method:
+//   - GET
+//
+// URL Path:
+//   - /api/export-destinations
+//
+// Requires:
+//   - Authentication: User must be logged in
+//
+// Responses:
+//   - 200 OK: The user's export destinations
+//   - 401 Unauthorized: User not authenticated
+//   - 500 Internal Server Error: Server-side error
+//
+// @Summary List export destinations
+// @Description Lists the S3 buckets, SFTP servers and SharePoint folders the user's redacted documents are delivered to, without credentials
+// @Tags Exports
+// @Produce json
+// @Security BearerAuth
+// @Success 200 {object} utils.Response{data=[]models.ExportDestination} "Export destinations"
+// @Failure 401 {object} utils.Response{error=string} "User not authenticated"
+// @Failure 500 {object} utils.Response{error=string} "Server error"
+// @Router /export-destinations [get]
+func (h *ExportHandler) ListDestinations(w http.ResponseWriter, r *http.Request) {
+	userID, ok := auth.GetUserID(r)
+	if !ok {
+		utils.Unauthorized(w, constants.MsgAuthRequired)
+		return
+	}
+
+	destinations, err := h.exportService.ListDestinations(r.Context(), userID)
+	if err != nil {
+		utils.ErrorFromAppError(w, utils.ParseError(err))
+		return
+	}
+
+	utils.ListAll(w, r, destinations)
+}
+
+// CreateDestination adds an export destination. Documents saved afterwards are delivered
+// to it automatically unless auto_deliver is false.
+//
+// HTTP Method:
+//   - POST
+//
+// URL Path:
+//   - /api/export-destinations
+//
+// Request Body:
+//   - models.ExportDestinationCreate: Name, type and connection settings
+//
+// Requires:
+//   - Authentication: User must be logged in
+//
+// Responses:
+//   - 201 Created: The destination, without credentials
+//   - 400 Bad Request: Invalid type or settings
+//   - 401 Unauthorized: User not authenticated
+//   - 409 Conflict: The user already has a destination with the name
+//   - 500 Internal Server Error: Server-side error
+//
+// @Summary Create an export destination
+// @Description Adds an S3 bucket, SFTP server or SharePoint folder redacted documents are delivered to
+// @Tags Exports
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param destination body models.ExportDestinationCreate true "Export destination"
+// @Success 201 {object} utils.Response{data=models.ExportDestination} "Export destination created"
+// @Failure 400 {object} utils.Response{error=string} "Invalid type or settings"
+// @Failure 401 {object} utils.Response{error=string} "User not authenticated"
+// @Failure 409 {object} utils.Response{error=string} "Name already used"
+// @Failure 500 {object} utils.Response{error=string} "Server error"
+// @Router /export-destinations [post]
+func (h *ExportHandler) CreateDestination(w http.ResponseWriter, r *http.Request) {
+	userID, ok := auth.GetUserID(r)
+	if !ok {
+		utils.Unauthorized(w, constants.MsgAuthRequired)
+		return
+	}
+
+	var req models.ExportDestinationCreate
+	if err := utils.DecodeAndValidate(r, &req); err != nil {
+		utils.ErrorFromAppError(w, utils.ParseError(err))
+		return
+	}
+
+	destination, err := h.exportService.CreateDestination(r.Context(), userID, &req)
+	if err != nil {
+		utils.ErrorFromAppError(w, utils.ParseError(err))
+		return
+	}
+
+	utils.JSON(w, constants.StatusCreated, destination)
+}
+
+// DeleteDestination removes an export destination and its delivery history.
+// Documents already delivered stay at the destination.
+//
+// HTTP Method:
+//   - DELETE
+//
+// URL Path:
+//   - /api/export-destinations/{id}
+//
+// Requires:
+//   - Authentication: User must be logged in
+//
+// Responses:
+//   - 204 No Content: Destination deleted
+//   - 400 Bad Request: Invalid destination ID
+//   - 401 Unauthorized: User not authenticated
+//   - 404 Not Found: The user has no such destination
+//   - 500 Internal Server Error: Server-side error
+//
+// @Summary Delete an export destination
+// @Description Removes an export destination; documents already delivered stay there
+// @Tags Exports
+// @Security BearerAuth
+// @Param id path int true "Destination ID"
+// @Success 204 "Export destination deleted"
+// @Failure 400 {object} utils.Response{error=string} "Invalid destination ID"
+// @Failure 401 {object} utils.Response{error=string} "User not authenticated"
+// @Failure 404 {object} utils.Response{error=string} "Export destination not found"
+// @Failure 500 {object} utils.Response{error=string} "Server error"
+// @Router /export-destinations/{id} [delete]
+func (h *ExportHandler) DeleteDestination(w http.ResponseWriter, r *http.Request) {
+	userID, ok := auth.GetUserID(r)
+	if !ok {
+		utils.Unauthorized(w, constants.MsgAuthRequired)
+		return
+	}
+
+	destinationID, err := strconv.ParseInt(chi.URLParam(r, constants.ParamID), 10, 64)
+	if err != nil {
+		utils.BadRequest(w, "Invalid destination ID", nil)
+		return
+	}
+
+	if err := h.exportService.DeleteDestination(r.Context(), userID, destinationID); err != nil {
+		utils.ErrorFromAppError(w, utils.ParseError(err))
+		return
+	}
+
+	utils.NoContent(w)
+}
+
+// ListDeliveries returns the delivery status of a document at each destination it was delivered to.
+//
+// HTTP Method:
+//   - GET
+//
+// URL Path:
+//   - /api/documents/{id}/deliveries
+//
+// Requires:
+//   - Authentication: User must be logged in
+//
+// Responses:
+//   - 200 OK: Deliveries of the document
+//   - 400 Bad Request: Invalid document ID
+//   - 401 Unauthorized: User not authenticated
+//   - 500 Internal Server Error: Server-side error
+//
+// @Summary List document deliveries
+// @Description Lists whether a document is pending, delivered or failed at each export destination, with the reason of the last failure
+// @Tags Exports
+// @Produce json
+// @Security BearerAuth
+// @Param id path int true "Document ID"
+// @Success 200 {object} utils.Response{data=[]models.ExportDelivery} "Deliveries"
+// @Failure 400 {object} utils.Response{error=string} "Invalid document ID"
+// @Failure 401 {object} utils.Response{error=string} "User not authenticated"
+// @Failure 500 {object} utils.Response{error=string} "Server error"
+// @Router /documents/{id}/deliveries [get]
+func (h *ExportHandler) ListDeliveries(w http.ResponseWriter, r *http.Request) {
+	userID, ok := auth.GetUserID(r)
+	if !ok {
+		utils.Unauthorized(w, constants.MsgAuthRequired)
+		return
+	}
+
+	documentID, err := strconv.ParseInt(chi.URLParam(r, constants.ParamID), 10, 64)
+	if err != nil {
+		utils.BadRequest(w, "Invalid document ID", nil)
+		return
+	}
+
+	deliveries, err := h.exportService.ListDeliveries(r.Context(), userID, documentID)
+	if err != nil {
+		utils.ErrorFromAppError(w, utils.ParseError(err))
+		return
+	}
+
+	utils.ListAll(w, r, deliveries)
+}
+
+// Deliver delivers a document to a destination and waits for the outcome, for destinations
+// without automatic delivery, documents saved before the destination was added, and
+// deliveries that used up their automatic retries.
+//
+// HTTP Method:
+//   - POST
+//
+// URL Path:
+//   - /api/documents/{id}/deliveries
+//
+// Request Body:
+//   - models.ExportDeliveryRequest: The destination to deliver to
+//
+// Requires:
+//   - Authentication: User must be logged in
+//
+// Responses:
+//   - 200 OK: The delivery; its status tells whether it succeeded
+//   - 400 Bad Request: Invalid document ID or request body
+//   - 401 Unauthorized: User not authenticated
+//   - 404 Not Found: The user has no such document or destination
+//   - 500 Internal Server Error: Server-side error
+//
+// @Summary Deliver a document
+// @Description Delivers the export bundle of a document to an export destination; a failed delivery is returned with status failed and the reason
+// @Tags Exports
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path int true "Document ID"
+// @Param delivery body models.ExportDeliveryRequest true "Destination"
+// @Success 200 {object} utils.Response{data=models.ExportDelivery} "Delivery"
+// @Failure 400 {object} utils.Response{error=string} "Invalid request"
+// @Failure 401 {object} utils.Response{error=string} "User not authenticated"
+// @Failure 404 {object} utils.Response{error=string} "Document or destination not found"
+// @Failure 500 {object} utils.Response{error=string} "Server error"
+// @Router /documents/{id}/deliveries [post]
+func (h *ExportHandler) Deliver(w http.ResponseWriter, r *http.Request) {
+	userID, ok := auth.GetUserID(r)
+	if !ok {
+		utils.Unauthorized(w, constants.MsgAuthRequired)
+		return
+	}
+
+	documentID, err := strconv.ParseInt(chi.URLParam(r, constants.ParamID), 10, 64)
+	if err != nil {
+		utils.BadRequest(w, "Invalid document ID", nil)
+		return
+	}
+
+	var req models.ExportDeliveryRequest
+	if err := utils.DecodeAndValidate(r, &req); err != nil {
+		utils.ErrorFromAppError(w, utils.ParseError(err))
+		return
+	}
+
+	delivery, err := h.exportService.Deliver(r.Context(), userID, documentID, req.DestinationID)
+	if err != nil {
+		if errors.Is(err, service.ErrDocumentNotFound) {
+			utils.NotFound(w, "Document not found")
+			return
+		}
+		utils.ErrorFromAppError(w, utils.ParseError(err))
+		return
+	}
+
+	utils.JSON(w, constants.StatusOK, delivery)
+}
@@ -0,0 +1,204 @@
+package handlers_test
+
+import (
+	"bytes"
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+
+	"github.com/yasinhessnawi1/Hideme_Backend/internal/constants"
+	"github.com/yasinhessnawi1/Hideme_Backend/internal/handlers"
+	"github.com/yasinhessnawi1/Hideme_Backend/internal/models"
+	"github.com/yasinhessnawi1/Hideme_Backend/internal/service"
+	"github.com/yasinhessnawi1/Hideme_Backend/internal/testsupport/golden"
+	"github.com/yasinhessnawi1/Hideme_Backend/internal/utils"
+)
+
+// MockExportService is a mock implementation of the ExportService
+type MockExportService struct {
+	mock.Mock
+}
+
+func (m *MockExportService) ListDestinations(ctx context.Context, userID int64) ([]*models.ExportDestination, error) {
+	args := m.Called(ctx, userID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]*models.ExportDestination), args.Error(1)
+}
+
+func (m *MockExportService) CreateDestination(ctx context.Context, userID int64, req *models.ExportDestinationCreate) (*models.ExportDestination, error) {
+	args := m.Called(ctx, userID, req)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*models.ExportDestination), args.Error(1)
+}
+
+func (m *MockExportService) DeleteDestination(ctx context.Context, userID, destinationID int64) error {
+	args := m.Called(ctx, userID, destinationID)
+	return args.Error(0)
+}
+
+func (m *MockExportService) ListDeliveries(ctx context.Context, userID, documentID int64) ([]*models.ExportDelivery, error) {
+	args := m.Called(ctx, userID, documentID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]*models.ExportDelivery), args.Error(1)
+}
+
+func (m *MockExportService) Deliver(ctx context.Context, userID, documentID, destinationID int64) (*models.ExportDelivery, error) {
+	args := m.Called(ctx, userID, documentID, destinationID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*models.ExportDelivery), args.Error(1)
+}
+
+func setupExportTest() (*chi.Mux, *MockExportService) {
+	mockService := new(MockExportService)
+	handler := handlers.NewExportHandler(mockService)
+
+	router := chi.NewRouter()
+	router.Get("/api/export-destinations", handler.ListDestinations)
+	router.Post("/api/export-destinations", handler.CreateDestination)
+	router.Delete("/api/export-destinations/{id}", handler.DeleteDestination)
+	router.Get("/api/documents/{id}/deliveries", handler.ListDeliveries)
+	router.Post("/api/documents/{id}/deliveries", handler.Deliver)
+
+	return router, mockService
+}
+
+func TestExportDestinations(t *testing.T) {
+	router, mockService := setupExportTest()
+
+	t.Run("List", func(t *testing.T) {
+		mockService.On("ListDestinations", mock.Anything, int64(1)).Return([]*models.ExportDestination{
+			{ID: 3, Name: "Archive", Type: constants.ExportDestinationS3, AutoDeliver: true},
+		}, nil).Once()
+
+		req, err := http.NewRequest("GET", "/api/export-destinations", nil)
+		require.NoError(t, err)
+		req = req.WithContext(createAuthContext(1))
+		rr := httptest.NewRecorder()
+		router.ServeHTTP(rr, req)
+
+		golden.AssertResponse(t, rr)
+	})
+
+	t.Run("Create", func(t *testing.T) {
+		mockService.On("CreateDestination", mock.Anything, int64(1), mock.MatchedBy(func(req *models.ExportDestinationCreate) bool {
+			return req.Type == constants.ExportDestinationSFTP && req.Config.Host == "sftp.example.com"
+		})).Return(&models.ExportDestination{ID: 4, Name: "Outbox", Type: constants.ExportDestinationSFTP}, nil).Once()
+
+		body := `{"name":"Outbox","type":"sftp","config":{"host":"sftp.example.com","username":"hideme","password":"secret"}}`
+		req, err := http.NewRequest("POST", "/api/export-destinations", bytes.NewBufferString(body))
+		require.NoError(t, err)
+		req.Header.Set("Content-Type", "application/json")
+		req = req.WithContext(createAuthContext(1))
+		rr := httptest.NewRecorder()
+		router.ServeHTTP(rr, req)
+
+		golden.AssertResponse(t, rr)
+	})
+
+	t.Run("Create Without Name", func(t *testing.T) {
+		req, err := http.NewRequest("POST", "/api/export-destinations", bytes.NewBufferString(`{"type":"s3"}`))
+		require.NoError(t, err)
+		req.Header.Set("Content-Type", "application/json")
+		req = req.WithContext(createAuthContext(1))
+		rr := httptest.NewRecorder()
+		router.ServeHTTP(rr, req)
+
+		assert.Equal(t, http.StatusBadRequest, rr.Code)
+	})
+
+	t.Run("Delete Not Found", func(t *testing.T) {
+		mockService.On("DeleteDestination", mock.Anything, int64(1), int64(9)).
+			Return(utils.NewNotFoundError("ExportDestination", int64(9))).Once()
+
+		req, err := http.NewRequest("DELETE", "/api/export-destinations/9", nil)
+		require.NoError(t, err)
+		req = req.WithContext(createAuthContext(1))
+		rr := httptest.NewRecorder()
+		router.ServeHTTP(rr, req)
+
+		assert.Equal(t, http.StatusNotFound, rr.Code)
+	})
+
+	t.Run("Unauthorized", func(t *testing.T) {
+		req, err := http.NewRequest("GET", "/api/export-destinations", nil)
+		require.NoError(t, err)
+		rr := httptest.NewRecorder()
+		router.ServeHTTP(rr, req)
+
+		assert.Equal(t, http.StatusUnauthorized, rr.Code)
+	})
+
+	mockService.AssertExpectations(t)
+}
+
+func TestExportDeliveries(t *testing.T) {
+	router, mockService := setupExportTest()
+
+	t.Run("List", func(t *testing.T) {
+		mockService.On("ListDeliveries", mock.Anything, int64(1), int64(11)).Return([]*models.ExportDelivery{
+			{ID: 5, DestinationID: 3, DestinationName: "Archive", DocumentID: 11, Status: constants.ExportDeliveryFailed, LastError: "timeout"},
+		}, nil).Once()
+
+		req, err := http.NewRequest("GET", "/api/documents/11/deliveries", nil)
+		require.NoError(t, err)
+		req = req.WithContext(createAuthContext(1))
+		rr := httptest.NewRecorder()
+		router.ServeHTTP(rr, req)
+
+		golden.AssertResponse(t, rr)
+	})
+
+	t.Run("Deliver", func(t *testing.T) {
+		mockService.On("Deliver", mock.Anything, int64(1), int64(11), int64(3)).
+			Return(&models.ExportDelivery{ID: 5, Status: constants.ExportDeliveryDelivered}, nil).Once()
+
+		req, err := http.NewRequest("POST", "/api/documents/11/deliveries", bytes.NewBufferString(`{"destination_id":3}`))
+		require.NoError(t, err)
+		req.Header.Set("Content-Type", "application/json")
+		req = req.WithContext(createAuthContext(1))
+		rr := httptest.NewRecorder()
+		router.ServeHTTP(rr, req)
+
+		golden.AssertResponse(t, rr)
+	})
+
+	t.Run("Deliver Other User's Document", func(t *testing.T) {
+		mockService.On("Deliver", mock.Anything, int64(1), int64(12), int64(3)).
+			Return(nil, service.ErrDocumentNotFound).Once()
+
+		req, err := http.NewRequest("POST", "/api/documents/12/deliveries", bytes.NewBufferString(`{"destination_id":3}`))
+		require.NoError(t, err)
+		req.Header.Set("Content-Type", "application/json")
+		req = req.WithContext(createAuthContext(1))
+		rr := httptest.NewRecorder()
+		router.ServeHTTP(rr, req)
+
+		assert.Equal(t, http.StatusNotFound, rr.Code)
+	})
+
+	t.Run("Invalid Document ID", func(t *testing.T) {
+		req, err := http.NewRequest("GET", "/api/documents/abc/deliveries", nil)
+		require.NoError(t, err)
+		req = req.WithContext(createAuthContext(1))
+		rr := httptest.NewRecorder()
+		router.ServeHTTP(rr, req)
+
+		assert.Equal(t, http.StatusBadRequest, rr.Code)
+	})
+
+	mockService.AssertExpectations(t)
+}
@@ -0,0 +1,326 @@
+// Package handlers provides HTTP request handlers for the HideMe API.
+package handlers
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"strconv"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/rs/zerolog/log"
+
+	"github.com/yasinhessnawi1/Hideme_Backend/internal/auth"
+	"github.com/yasinhessnawi1/Hideme_Backend/internal/constants"
+	"github.com/yasinhessnawi1/Hideme_Backend/internal/models"
+	"github.com/yasinhessnawi1/Hideme_Backend/internal/utils"
+)
+
+// JobServiceInterface defines the service methods required for background jobs.
+type JobServiceInterface interface {
+	// Enqueue queues a job processing a document a user owns or that was shared with them.
+	//
+	// Parameters:
+	//   - ctx: Context for the operation
+	//   - userID: The ID of the user queuing the job
+	//   - req: The kind of the job, its document and parameters
+	//   - execution: The context of the request queuing the job, kept to run the job with
+	//
+	// Returns:
+	//   - The pending job
+	//   - A not found error if the document doesn't exist or the user has no access to it
+	Enqueue(ctx context.Context, userID int64, req *models.JobRequest, execution models.JobContext) (*models.Job, error)
+
+	// GetJob retrieves a job of a user with its status.
+	//
+	// Parameters:
+	//   - ctx: Context for the operation
+	//   - userID: The ID of the user
+	//   - id: The ID of the job
+	//
+	// Returns:
+	//   - The job
+	//   - NotFoundError if the user has no such job
+	GetJob(ctx context.Context, userID, id int64) (*models.Job, error)
+
+	// ListJobs retrieves the jobs of a user, newest first.
+	//
+	// Parameters:
+	//   - ctx: Context for the operation
+	//   - userID: The ID of the user
+	//
+	// Returns:
+	//   - The jobs
+	//   - An error if retrieval fails
+	ListJobs(ctx context.Context, userID int64) ([]*models.Job, error)
+
+	// GetResult retrieves the result of a completed job of a user.
+	//
+	// Parameters:
+	//   - ctx: Context for the operation
+	//   - userID: The ID of the user
+	//   - id: The ID of the job
+	//
+	// Returns:
+	//   - The job and its result
+	//   - NotFoundError if the user has no such job, a conflict error if it has not completed
+	GetResult(ctx context.Context, userID, id int64) (*models.Job, *models.JobResult, error)
+}
+
+// JobHandler handles HTTP requests for the background jobs detecting, redacting and
+// exporting documents.
+type JobHandler struct {
+	jobService JobServiceInterface
+}
+
+// NewJobHandler creates a new JobHandler with the provided job service.
+//
+// Parameters:
+//   - jobService: Service queuing and reporting background jobs
+//
+// Returns:
+//   - A properly initialized JobHandler
+func NewJobHandler(jobService JobServiceInterface) *JobHandler {
+	return &JobHandler{
+		jobService: jobService,
+	}
+}
+
+// CreateJob queues the detection, redaction or export of a document as a background job,
+// for documents too large to process within a request. Poll the job until it is completed,
+// then download its result. Failed attempts are retried with a growing delay. The job runs
+// with the context of this request: its request ID and the requested locale.
+//
+// HTTP Method:
+//   - POST
+//
+// URL Path:
+//   - /api/jobs
+//
+// Requires:
+//   - Authentication: User must be logged in or use an API key
+//   - Body: JSON with kind, document_id, the optional locale and, for redaction jobs, the
+//     optional redaction_method, or for detection jobs the entities to add
+//
+// Responses:
+//   - 202 Accepted: Job queued
+//   - 400 Bad Request: Invalid request body
+//   - 401 Unauthorized: User not authenticated
+//   - 404 Not Found: Document not found
+//   - 500 Internal Server Error: Server-side error
+//
+// @Summary Queue a job
+// @Description Queues the detection, redaction or export of a document to run in the background
+// @Tags Jobs
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param request body models.JobRequest true "Kind, document and parameters of the job"
+// @Success 202 {object} utils.Response{data=models.Job} "Job queued"
+// @Failure 400 {object} utils.Response{error=string} "Invalid request body"
+// @Failure 401 {object} utils.Response{error=string} "User not authenticated"
+// @Failure 404 {object} utils.Response{error=string} "Document not found"
+// @Failure 500 {object} utils.Response{error=string} "Server error"
+// @Router /jobs [post]
+func (h *JobHandler) CreateJob(w http.ResponseWriter, r *http.Request) {
+	userID, ok := auth.GetUserID(r)
+	if !ok {
+		utils.Unauthorized(w, constants.MsgAuthRequired)
+		return
+	}
+
+	var req models.JobRequest
+	if err := utils.DecodeAndValidate(r, &req); err != nil {
+		utils.ErrorFromAppError(w, utils.ParseError(err))
+		return
+	}
+
+	// Keep the context of this request for the job to run with
+	requestID, _ := auth.GetRequestID(r)
+	keyScopes, apiKey := auth.GetAPIKeyScopes(r)
+	execution := models.JobContext{
+		RequestID:    requestID,
+		UserID:       userID,
+		APIKey:       apiKey,
+		APIKeyScopes: keyScopes,
+		Locale:       req.Locale,
+	}
+
+	job, err := h.jobService.Enqueue(r.Context(), userID, &req, execution)
+	if err != nil {
+		utils.ErrorFromAppError(w, utils.ParseError(err))
+		return
+	}
+
+	utils.JSON(w, constants.StatusAccepted, job)
+}
+
+// ListJobs returns the background jobs of the current user, newest first.
+//
+// HTTP Method:
+//   - GET
+//
+// URL Path:
+//   - /api/jobs
+//
+// Requires:
+//   - Authentication: User must be logged in or use an API key
+//
+// Responses:
+//   - 200 OK: Jobs returned
+//   - 401 Unauthorized: User not authenticated
+//   - 500 Internal Server Error: Server-side error
+//
+// @Summary List my jobs
+// @Description Lists the background jobs of the current user with their status
+// @Tags Jobs
+// @Produce json
+// @Security BearerAuth
+// @Success 200 {object} utils.Response{data=[]models.Job} "Jobs"
+// @Failure 401 {object} utils.Response{error=string} "User not authenticated"
+// @Failure 500 {object} utils.Response{error=string} "Server error"
+// @Router /jobs [get]
+func (h *JobHandler) ListJobs(w http.ResponseWriter, r *http.Request) {
+	userID, ok := auth.GetUserID(r)
+	if !ok {
+		utils.Unauthorized(w, constants.MsgAuthRequired)
+		return
+	}
+
+	jobs, err := h.jobService.ListJobs(r.Context(), userID)
+	if err != nil {
+		utils.ErrorFromAppError(w, utils.ParseError(err))
+		return
+	}
+
+	utils.ListAll(w, r, jobs)
+}
+
+// GetJob returns the status of a background job of the current user: whether it is
+// pending, running, completed or failed, how often it was attempted, when a retry is due
+// and why the last attempt failed.
+//
+// HTTP Method:
+//   - GET
+//
+// URL Path:
+//   - /api/jobs/{id}
+//
+// Requires:
+//   - Authentication: User must be logged in or use an API key
+//
+// Responses:
+//   - 200 OK: Job returned
+//   - 400 Bad Request: Invalid job ID
+//   - 401 Unauthorized: User not authenticated
+//   - 404 Not Found: The user has no such job
+//   - 500 Internal Server Error: Server-side error
+//
+// @Summary Get a job
+// @Description Returns the status of a background job of the current user
+// @Tags Jobs
+// @Produce json
+// @Security BearerAuth
+// @Param id path int true "Job ID"
+// @Success 200 {object} utils.Response{data=models.Job} "Job"
+// @Failure 400 {object} utils.Response{error=string} "Invalid job ID"
+// @Failure 401 {object} utils.Response{error=string} "User not authenticated"
+// @Failure 404 {object} utils.Response{error=string} "Job not found"
+// @Failure 500 {object} utils.Response{error=string} "Server error"
+// @Router /jobs/{id} [get]
+func (h *JobHandler) GetJob(w http.ResponseWriter, r *http.Request) {
+	userID, ok := auth.GetUserID(r)
+	if !ok {
+		utils.Unauthorized(w, constants.MsgAuthRequired)
+		return
+	}
+
+	jobID, err := strconv.ParseInt(chi.URLParam(r, constants.ParamID), 10, 64)
+	if err != nil {
+		utils.BadRequest(w, "Invalid job ID", nil)
+		return
+	}
+
+	job, err := h.jobService.GetJob(r.Context(), userID, jobID)
+	if err != nil {
+		utils.ErrorFromAppError(w, utils.ParseError(err))
+		return
+	}
+
+	utils.JSON(w, constants.StatusOK, job)
+}
+
+// DownloadJobResult returns the result of a completed background job of the current user:
+// the redacted PDF of a redaction job, the document record of an export job, or the IDs of
+// the entities a detection job added.
+//
+// HTTP Method:
+//   - GET
+//
+// URL Path:
+//   - /api/jobs/{id}/result
+//
+// Requires:
+//   - Authentication: User must be logged in or use an API key
+//
+// Responses:
+//   - 200 OK: Result returned
+//   - 400 Bad Request: Invalid job ID
+//   - 401 Unauthorized: User not authenticated
+//   - 404 Not Found: The user has no such job
+//   - 409 Conflict: The job has not completed
+//   - 500 Internal Server Error: Server-side error
+//
+// @Summary Download the result of a job
+// @Description Returns the result of a completed background job of the current user
+// @Tags Jobs
+// @Produce json,application/pdf
+// @Security BearerAuth
+// @Param id path int true "Job ID"
+// @Success 200 {file} file "Job result"
+// @Failure 400 {object} utils.Response{error=string} "Invalid job ID"
+// @Failure 401 {object} utils.Response{error=string} "User not authenticated"
+// @Failure 404 {object} utils.Response{error=string} "Job not found"
+// @Failure 409 {object} utils.Response{error=string} "Job has not completed"
+// @Failure 500 {object} utils.Response{error=string} "Server error"
+// @Router /jobs/{id}/result [get]
+func (h *JobHandler) DownloadJobResult(w http.ResponseWriter, r *http.Request) {
+	userID, ok := auth.GetUserID(r)
+	if !ok {
+		utils.Unauthorized(w, constants.MsgAuthRequired)
+		return
+	}
+
+	jobID, err := strconv.ParseInt(chi.URLParam(r, constants.ParamID), 10, 64)
+	if err != nil {
+		utils.BadRequest(w, "Invalid job ID", nil)
+		return
+	}
+
+	job, result, err := h.jobService.GetResult(r.Context(), userID, jobID)
+	if err != nil {
+		utils.ErrorFromAppError(w, utils.ParseError(err))
+		return
+	}
+
+	w.Header().Set(constants.HeaderContentType, result.ContentType)
+	w.Header().Set(constants.HeaderContentDisposition, "attachment; filename="+jobResultFilename(job))
+	w.Header().Set(constants.HeaderCacheControl, constants.CacheControlNoStore)
+	w.WriteHeader(constants.StatusOK)
+	if _, err := w.Write(result.Data); err != nil {
+		log.Error().Err(err).Int64("user_id", userID).Int64("job_id", jobID).Msg("Failed to write job result")
+	}
+}
+
+// jobResultFilename returns the file name of the result of a job, named like the result of
+// the synchronous endpoint the job replaces.
+func jobResultFilename(job *models.Job) string {
+	switch job.Kind {
+	case constants.JobKindRedaction:
+		return fmt.Sprintf("%s%d.pdf", constants.RedactedFilenamePrefix, job.DocumentID)
+	case constants.JobKindExport:
+		return fmt.Sprintf("%s%d.%s", constants.DocumentExportFilenamePrefix, job.DocumentID, constants.DocumentExportFormatJSON)
+	default:
+		return fmt.Sprintf("%s%d.json", constants.JobResultFilenamePrefix, job.ID)
+	}
+}
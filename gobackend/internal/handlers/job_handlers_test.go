@@ -0,0 +1,245 @@
+package handlers_test
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/yasinhessnawi1/Hideme_Backend/internal/auth"
+	"github.com/yasinhessnawi1/Hideme_Backend/internal/constants"
+	"github.com/yasinhessnawi1/Hideme_Backend/internal/handlers"
+	"github.com/yasinhessnawi1/Hideme_Backend/internal/models"
+	"github.com/yasinhessnawi1/Hideme_Backend/internal/testsupport/golden"
+	"github.com/yasinhessnawi1/Hideme_Backend/internal/utils"
+)
+
+// FakeJobService queues jobs in memory; document 404 does not exist
+type FakeJobService struct {
+	jobs          []*models.Job
+	results       map[int64]*models.JobResult
+	lastReq       *models.JobRequest
+	lastExecution models.JobContext
+}
+
+func (f *FakeJobService) Enqueue(ctx context.Context, userID int64, req *models.JobRequest, execution models.JobContext) (*models.Job, error) {
+	f.lastReq = req
+	f.lastExecution = execution
+	if req.DocumentID == 404 {
+		return nil, utils.NewNotFoundError("Document", req.DocumentID)
+	}
+	job := &models.Job{ID: int64(len(f.jobs) + 1), UserID: userID, Kind: req.Kind, DocumentID: req.DocumentID, Status: constants.JobPending}
+	f.jobs = append(f.jobs, job)
+	return job, nil
+}
+
+func (f *FakeJobService) GetJob(ctx context.Context, userID, id int64) (*models.Job, error) {
+	for _, job := range f.jobs {
+		if job.ID == id && job.UserID == userID {
+			return job, nil
+		}
+	}
+	return nil, utils.NewNotFoundError("Job", id)
+}
+
+func (f *FakeJobService) ListJobs(ctx context.Context, userID int64) ([]*models.Job, error) {
+	jobs := []*models.Job{}
+	for _, job := range f.jobs {
+		if job.UserID == userID {
+			jobs = append(jobs, job)
+		}
+	}
+	return jobs, nil
+}
+
+func (f *FakeJobService) GetResult(ctx context.Context, userID, id int64) (*models.Job, *models.JobResult, error) {
+	job, err := f.GetJob(ctx, userID, id)
+	if err != nil {
+		return nil, nil, err
+	}
+	if job.Status != constants.JobCompleted {
+		return nil, nil, utils.New(utils.ErrBadRequest, constants.StatusConflict, constants.MsgJobNotReady)
+	}
+	return job, f.results[id], nil
+}
+
+func setupJobTest() (*chi.Mux, *FakeJobService) {
+	service := &FakeJobService{results: make(map[int64]*models.JobResult)}
+	handler := handlers.NewJobHandler(service)
+
+	router := chi.NewRouter()
+	router.Post("/api/jobs", handler.CreateJob)
+	router.Get("/api/jobs", handler.ListJobs)
+	router.Get("/api/jobs/{id}", handler.GetJob)
+	router.Get("/api/jobs/{id}/result", handler.DownloadJobResult)
+
+	return router, service
+}
+
+func TestCreateJob(t *testing.T) {
+	tests := []struct {
+		name       string
+		body       string
+		wantStatus int
+	}{
+		{name: "Redaction", body: `{"kind":"redaction","document_id":12,"redaction_method":"mask"}`, wantStatus: http.StatusAccepted},
+		{name: "Export", body: `{"kind":"export","document_id":12}`, wantStatus: http.StatusAccepted},
+		{name: "Detection", body: `{"kind":"detection","document_id":12,"entities":[{"method_id":1,"entity_name":"John Doe"}]}`, wantStatus: http.StatusAccepted},
+		{name: "Detection without entities", body: `{"kind":"detection","document_id":12}`, wantStatus: http.StatusBadRequest},
+		{name: "Unknown kind", body: `{"kind":"ocr","document_id":12}`, wantStatus: http.StatusBadRequest},
+		{name: "Invalid redaction method", body: `{"kind":"redaction","document_id":12,"redaction_method":"blur"}`, wantStatus: http.StatusBadRequest},
+		{name: "Document not found", body: `{"kind":"export","document_id":404}`, wantStatus: http.StatusNotFound},
+		{name: "Unsupported locale", body: `{"kind":"export","document_id":12,"locale":"xx-XX"}`, wantStatus: http.StatusBadRequest},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			router, _ := setupJobTest()
+
+			req, err := http.NewRequest("POST", "/api/jobs", strings.NewReader(tt.body))
+			require.NoError(t, err)
+			req.Header.Set("Content-Type", "application/json")
+			req = req.WithContext(createAuthContext(7))
+
+			rr := httptest.NewRecorder()
+			router.ServeHTTP(rr, req)
+
+			assert.Equal(t, tt.wantStatus, rr.Code, rr.Body.String())
+		})
+	}
+
+	t.Run("Request Context", func(t *testing.T) {
+		router, service := setupJobTest()
+
+		req, err := http.NewRequest("POST", "/api/jobs", strings.NewReader(`{"kind":"export","document_id":12,"locale":"de-DE"}`))
+		require.NoError(t, err)
+		req.Header.Set("Content-Type", "application/json")
+		req = req.WithContext(context.WithValue(createAuthContext(7), auth.RequestIDContextKey, "req-1"))
+
+		rr := httptest.NewRecorder()
+		router.ServeHTTP(rr, req)
+
+		assert.Equal(t, http.StatusAccepted, rr.Code)
+		assert.Equal(t, models.JobContext{RequestID: "req-1", UserID: 7, Locale: models.LocaleGerman}, service.lastExecution)
+	})
+
+	t.Run("API Key Context", func(t *testing.T) {
+		router, service := setupJobTest()
+
+		req, err := http.NewRequest("POST", "/api/jobs", strings.NewReader(`{"kind":"export","document_id":12}`))
+		require.NoError(t, err)
+		req.Header.Set("Content-Type", "application/json")
+		req = req.WithContext(context.WithValue(createAuthContext(7), auth.APIKeyScopesContextKey, []string{constants.APIKeyScopeDocumentsWrite}))
+
+		rr := httptest.NewRecorder()
+		router.ServeHTTP(rr, req)
+
+		assert.Equal(t, http.StatusAccepted, rr.Code)
+		assert.True(t, service.lastExecution.APIKey)
+		assert.Equal(t, []string{constants.APIKeyScopeDocumentsWrite}, service.lastExecution.APIKeyScopes)
+	})
+
+	t.Run("Unauthorized", func(t *testing.T) {
+		router, _ := setupJobTest()
+
+		req, err := http.NewRequest("POST", "/api/jobs", strings.NewReader(`{"kind":"export","document_id":12}`))
+		require.NoError(t, err)
+
+		rr := httptest.NewRecorder()
+		router.ServeHTTP(rr, req)
+
+		assert.Equal(t, http.StatusUnauthorized, rr.Code)
+	})
+}
+
+func TestGetJob(t *testing.T) {
+	router, service := setupJobTest()
+	service.jobs = []*models.Job{{ID: 3, UserID: 7, Kind: constants.JobKindRedaction, DocumentID: 12, Status: constants.JobPending, Attempts: 1, LastError: "connection reset"}}
+
+	t.Run("Found", func(t *testing.T) {
+		req, err := http.NewRequest("GET", "/api/jobs/3", nil)
+		require.NoError(t, err)
+		req = req.WithContext(createAuthContext(7))
+
+		rr := httptest.NewRecorder()
+		router.ServeHTTP(rr, req)
+
+		golden.AssertResponse(t, rr)
+	})
+
+	t.Run("Other user", func(t *testing.T) {
+		req, err := http.NewRequest("GET", "/api/jobs/3", nil)
+		require.NoError(t, err)
+		req = req.WithContext(createAuthContext(8))
+
+		rr := httptest.NewRecorder()
+		router.ServeHTTP(rr, req)
+
+		assert.Equal(t, http.StatusNotFound, rr.Code)
+	})
+
+	t.Run("Invalid ID", func(t *testing.T) {
+		req, err := http.NewRequest("GET", "/api/jobs/abc", nil)
+		require.NoError(t, err)
+		req = req.WithContext(createAuthContext(7))
+
+		rr := httptest.NewRecorder()
+		router.ServeHTTP(rr, req)
+
+		assert.Equal(t, http.StatusBadRequest, rr.Code)
+	})
+}
+
+func TestDownloadJobResult(t *testing.T) {
+	router, service := setupJobTest()
+	service.jobs = []*models.Job{
+		{ID: 1, UserID: 7, Kind: constants.JobKindRedaction, DocumentID: 12, Status: constants.JobCompleted},
+		{ID: 2, UserID: 7, Kind: constants.JobKindExport, DocumentID: 12, Status: constants.JobRunning},
+	}
+	service.results[1] = &models.JobResult{ContentType: constants.ContentTypePDF, Data: []byte("%PDF-1.7")}
+
+	t.Run("Completed", func(t *testing.T) {
+		req, err := http.NewRequest("GET", "/api/jobs/1/result", nil)
+		require.NoError(t, err)
+		req = req.WithContext(createAuthContext(7))
+
+		rr := httptest.NewRecorder()
+		router.ServeHTTP(rr, req)
+
+		assert.Equal(t, http.StatusOK, rr.Code)
+		assert.Equal(t, "application/pdf", rr.Header().Get("Content-Type"))
+		assert.Equal(t, "attachment; filename=redacted-12.pdf", rr.Header().Get("Content-Disposition"))
+		assert.Equal(t, "%PDF-1.7", rr.Body.String())
+	})
+
+	t.Run("Not completed", func(t *testing.T) {
+		req, err := http.NewRequest("GET", "/api/jobs/2/result", nil)
+		require.NoError(t, err)
+		req = req.WithContext(createAuthContext(7))
+
+		rr := httptest.NewRecorder()
+		router.ServeHTTP(rr, req)
+
+		assert.Equal(t, http.StatusConflict, rr.Code)
+	})
+}
+
+func TestListJobs(t *testing.T) {
+	router, service := setupJobTest()
+	service.jobs = []*models.Job{{ID: 1, UserID: 7}, {ID: 2, UserID: 8}}
+
+	req, err := http.NewRequest("GET", "/api/jobs", nil)
+	require.NoError(t, err)
+	req.Header.Set(constants.HeaderXAPIVersion, "2")
+	req = req.WithContext(createAuthContext(7))
+
+	rr := httptest.NewRecorder()
+	router.ServeHTTP(rr, req)
+
+	golden.AssertResponse(t, rr)
+}
@@ -0,0 +1,172 @@
+// Package handlers provides HTTP request handlers for the HideMe API.
+package handlers
+
+import (
+	"context"
+	"net/http"
+
+	"github.com/go-chi/chi/v5"
+
+	"github.com/yasinhessnawi1/Hideme_Backend/internal/auth"
+	"github.com/yasinhessnawi1/Hideme_Backend/internal/constants"
+	"github.com/yasinhessnawi1/Hideme_Backend/internal/models"
+	"github.com/yasinhessnawi1/Hideme_Backend/internal/utils"
+)
+
+// MaintenanceServiceInterface defines the service methods required for running maintenance tasks.
+type MaintenanceServiceInterface interface {
+	Tasks() []models.MaintenanceTask
+	Run(ctx context.Context, name string) (*models.MaintenanceRun, error)
+	UpdateSettings(ctx context.Context, adminID int64, name string, update *models.MaintenanceTaskSettingsUpdate) (*models.MaintenanceTask, error)
+}
+
+// MaintenanceHandler handles HTTP requests for running maintenance tasks on demand.
+type MaintenanceHandler struct {
+	maintenanceService MaintenanceServiceInterface
+}
+
+// NewMaintenanceHandler creates a new MaintenanceHandler with the provided maintenance service.
+//
+// Parameters:
+//   - maintenanceService: Service holding the maintenance tasks
+//
+// Returns:
+//   - A properly initialized MaintenanceHandler
+func NewMaintenanceHandler(maintenanceService MaintenanceServiceInterface) *MaintenanceHandler {
+	return &MaintenanceHandler{
+		maintenanceService: maintenanceService,
+	}
+}
+
+// ListTasks returns the maintenance tasks with their settings in the order they run periodically.
+//
+// HTTP Method:
+//   - GET
+//
+// URL Path:
+//   - /api/admin/maintenance
+//
+// Requires:
+//   - Authentication: Admin role
+//
+// Responses:
+//   - 200 OK: List of maintenance tasks
+//   - 401 Unauthorized: User not authenticated
+//   - 403 Forbidden: User not authorized (not admin)
+//
+// @Summary List maintenance tasks
+// @Description Returns the maintenance tasks in the order they run periodically
+// @Tags Admin/Maintenance
+// @Produce json
+// @Security BearerAuth
+// @Security ApiKeyAuth
+// @Success 200 {object} utils.Response{data=[]models.MaintenanceTask} "List of maintenance tasks"
+// @Failure 401 {object} utils.Response{error=string} "User not authenticated"
+// @Failure 403 {object} utils.Response{error=string} "User not authorized"
+// @Router /admin/maintenance [get]
+func (h *MaintenanceHandler) ListTasks(w http.ResponseWriter, r *http.Request) {
+	utils.ListAll(w, r, h.maintenanceService.Tasks())
+}
+
+// RunTask runs a maintenance task now instead of waiting for the next periodic run, with
+// its current batch size and dry run setting. A failing task is reported in the error
+// field of the run.
+//
+// HTTP Method:
+//   - POST
+//
+// URL Path:
+//   - /api/admin/maintenance/{task}
+//
+// Requires:
+//   - Authentication: Admin role
+//
+// Responses:
+//   - 200 OK: Task ran
+//   - 401 Unauthorized: User not authenticated
+//   - 403 Forbidden: User not authorized (not admin)
+//   - 404 Not Found: Unknown task
+//
+// @Summary Run maintenance task
+// @Description Runs a maintenance task now and returns its outcome
+// @Tags Admin/Maintenance
+// @Produce json
+// @Security BearerAuth
+// @Security ApiKeyAuth
+// @Param task path string true "Task name"
+// @Success 200 {object} utils.Response{data=models.MaintenanceRun} "Task ran"
+// @Failure 401 {object} utils.Response{error=string} "User not authenticated"
+// @Failure 403 {object} utils.Response{error=string} "User not authorized"
+// @Failure 404 {object} utils.Response{error=string} "Unknown task"
+// @Router /admin/maintenance/{task} [post]
+func (h *MaintenanceHandler) RunTask(w http.ResponseWriter, r *http.Request) {
+	run, err := h.maintenanceService.Run(r.Context(), chi.URLParam(r, constants.ParamTask))
+	if err != nil {
+		utils.ErrorFromAppError(w, utils.ParseError(err))
+		return
+	}
+
+	utils.JSON(w, constants.StatusOK, run)
+}
+
+// UpdateTaskSettings changes whether a maintenance task runs periodically, how often, and
+// for tasks that support them, its batch size and dry run setting. The settings are stored
+// and survive restarts.
+//
+// HTTP Method:
+//   - PUT
+//
+// URL Path:
+//   - /api/admin/maintenance/{task}/settings
+//
+// Requires:
+//   - Authentication: Admin role
+//
+// Request Body:
+//   - JSON object conforming to models.MaintenanceTaskSettingsUpdate
+//
+// Responses:
+//   - 200 OK: The task with its new settings
+//   - 400 Bad Request: Invalid request body, or a batch size or dry run for a task without them
+//   - 401 Unauthorized: User not authenticated
+//   - 403 Forbidden: User not authorized (not admin)
+//   - 404 Not Found: Unknown task
+//   - 500 Internal Server Error: Server-side error
+//
+// @Summary Update maintenance task settings
+// @Description Changes whether a maintenance task is enabled, its interval, batch size and dry run setting
+// @Tags Admin/Maintenance
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Security ApiKeyAuth
+// @Param task path string true "Task name"
+// @Param settings body models.MaintenanceTaskSettingsUpdate true "Settings to change"
+// @Success 200 {object} utils.Response{data=models.MaintenanceTask} "The task with its new settings"
+// @Failure 400 {object} utils.Response{error=string} "Invalid request body"
+// @Failure 401 {object} utils.Response{error=string} "User not authenticated"
+// @Failure 403 {object} utils.Response{error=string} "User not authorized"
+// @Failure 404 {object} utils.Response{error=string} "Unknown task"
+// @Failure 500 {object} utils.Response{error=string} "Server error"
+// @Router /admin/maintenance/{task}/settings [put]
+func (h *MaintenanceHandler) UpdateTaskSettings(w http.ResponseWriter, r *http.Request) {
+	adminID, ok := auth.GetUserID(r)
+	if !ok {
+		utils.Unauthorized(w, constants.MsgAuthRequired)
+		return
+	}
+
+	var req models.MaintenanceTaskSettingsUpdate
+	if err := utils.DecodeAndValidate(r, &req); err != nil {
+		utils.ErrorFromAppError(w, utils.ParseError(err))
+		return
+	}
+
+	task, err := h.maintenanceService.UpdateSettings(r.Context(), adminID, chi.URLParam(r, constants.ParamTask), &req)
+	if err != nil {
+		utils.ErrorFromAppError(w, utils.ParseError(err))
+		return
+	}
+
+	utils.JSON(w, constants.StatusOK, task)
+}
@@ -0,0 +1,151 @@
+package handlers_test
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+
+	"github.com/yasinhessnawi1/Hideme_Backend/internal/auth"
+	"github.com/yasinhessnawi1/Hideme_Backend/internal/constants"
+	"github.com/yasinhessnawi1/Hideme_Backend/internal/handlers"
+	"github.com/yasinhessnawi1/Hideme_Backend/internal/models"
+	"github.com/yasinhessnawi1/Hideme_Backend/internal/testsupport/golden"
+	"github.com/yasinhessnawi1/Hideme_Backend/internal/utils"
+)
+
+// MockMaintenanceService is a mock implementation of the MaintenanceService
+type MockMaintenanceService struct {
+	mock.Mock
+}
+
+func (m *MockMaintenanceService) Tasks() []models.MaintenanceTask {
+	args := m.Called()
+	return args.Get(0).([]models.MaintenanceTask)
+}
+
+func (m *MockMaintenanceService) Run(ctx context.Context, name string) (*models.MaintenanceRun, error) {
+	args := m.Called(ctx, name)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*models.MaintenanceRun), args.Error(1)
+}
+
+func (m *MockMaintenanceService) UpdateSettings(ctx context.Context, adminID int64, name string, update *models.MaintenanceTaskSettingsUpdate) (*models.MaintenanceTask, error) {
+	args := m.Called(ctx, adminID, name, update)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*models.MaintenanceTask), args.Error(1)
+}
+
+func setupMaintenanceTest() (*chi.Mux, *MockMaintenanceService) {
+	mockService := new(MockMaintenanceService)
+	handler := handlers.NewMaintenanceHandler(mockService)
+
+	router := chi.NewRouter()
+	router.Get("/api/admin/maintenance", handler.ListTasks)
+	router.Post("/api/admin/maintenance/{task}", handler.RunTask)
+	router.Put("/api/admin/maintenance/{task}/settings", handler.UpdateTaskSettings)
+
+	return router, mockService
+}
+
+func TestListMaintenanceTasks(t *testing.T) {
+	router, mockService := setupMaintenanceTest()
+	mockService.On("Tasks").Return([]models.MaintenanceTask{
+		{Name: "expired_sessions", Description: "Delete expired sessions"},
+	})
+
+	req, err := http.NewRequest("GET", "/api/admin/maintenance", nil)
+	require.NoError(t, err)
+	rr := httptest.NewRecorder()
+	router.ServeHTTP(rr, req)
+
+	golden.AssertResponse(t, rr)
+}
+
+func TestRunMaintenanceTask(t *testing.T) {
+	t.Run("Success", func(t *testing.T) {
+		router, mockService := setupMaintenanceTest()
+		mockService.On("Run", mock.Anything, "expired_sessions").
+			Return(&models.MaintenanceRun{Task: "expired_sessions", Count: 4}, nil).Once()
+
+		req, err := http.NewRequest("POST", "/api/admin/maintenance/expired_sessions", nil)
+		require.NoError(t, err)
+		rr := httptest.NewRecorder()
+		router.ServeHTTP(rr, req)
+
+		golden.AssertResponse(t, rr)
+		mockService.AssertExpectations(t)
+	})
+
+	t.Run("Unknown Task", func(t *testing.T) {
+		router, mockService := setupMaintenanceTest()
+		mockService.On("Run", mock.Anything, "vacuum").
+			Return(nil, utils.NewNotFoundError("Maintenance task", "vacuum")).Once()
+
+		req, err := http.NewRequest("POST", "/api/admin/maintenance/vacuum", nil)
+		require.NoError(t, err)
+		rr := httptest.NewRecorder()
+		router.ServeHTTP(rr, req)
+
+		assert.Equal(t, http.StatusNotFound, rr.Code)
+		mockService.AssertExpectations(t)
+	})
+}
+
+func TestUpdateMaintenanceTaskSettings(t *testing.T) {
+	newRequest := func(t *testing.T, task, body string) *http.Request {
+		req, err := http.NewRequest("PUT", "/api/admin/maintenance/"+task+"/settings", strings.NewReader(body))
+		require.NoError(t, err)
+		req.Header.Set("Content-Type", "application/json")
+		return req.WithContext(context.WithValue(req.Context(), auth.UserIDContextKey, int64(1)))
+	}
+
+	t.Run("Success", func(t *testing.T) {
+		router, mockService := setupMaintenanceTest()
+		mockService.On("UpdateSettings", mock.Anything, int64(1), "expired_sessions", mock.MatchedBy(func(u *models.MaintenanceTaskSettingsUpdate) bool {
+			return u.BatchSize != nil && *u.BatchSize == 500 && u.DryRun != nil && *u.DryRun && u.Enabled == nil
+		})).Return(&models.MaintenanceTask{
+			Name:     "expired_sessions",
+			Batched:  true,
+			Settings: models.MaintenanceTaskSettings{Enabled: true, IntervalSeconds: 3600, BatchSize: 500, DryRun: true},
+		}, nil).Once()
+
+		rr := httptest.NewRecorder()
+		router.ServeHTTP(rr, newRequest(t, "expired_sessions", `{"batch_size":500,"dry_run":true}`))
+
+		golden.AssertResponse(t, rr)
+		mockService.AssertExpectations(t)
+	})
+
+	t.Run("Interval Too Short", func(t *testing.T) {
+		router, mockService := setupMaintenanceTest()
+
+		rr := httptest.NewRecorder()
+		router.ServeHTTP(rr, newRequest(t, "expired_sessions", `{"interval_seconds":10}`))
+
+		assert.Equal(t, http.StatusBadRequest, rr.Code)
+		mockService.AssertNotCalled(t, "UpdateSettings", mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+	})
+
+	t.Run("Task Without Batches", func(t *testing.T) {
+		router, mockService := setupMaintenanceTest()
+		mockService.On("UpdateSettings", mock.Anything, int64(1), "gdpr_logs", mock.Anything).
+			Return(nil, utils.NewValidationError("batch_size", constants.MsgMaintenanceTaskNotBatched)).Once()
+
+		rr := httptest.NewRecorder()
+		router.ServeHTTP(rr, newRequest(t, "gdpr_logs", `{"batch_size":100}`))
+
+		assert.Equal(t, http.StatusBadRequest, rr.Code)
+		mockService.AssertExpectations(t)
+	})
+}
@@ -0,0 +1,162 @@
+// Package handlers provides HTTP request handlers for the HideMe API.
+package handlers
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/rs/zerolog/log"
+
+	"github.com/yasinhessnawi1/Hideme_Backend/internal/auth"
+	"github.com/yasinhessnawi1/Hideme_Backend/internal/constants"
+	"github.com/yasinhessnawi1/Hideme_Backend/internal/models"
+	"github.com/yasinhessnawi1/Hideme_Backend/internal/utils"
+)
+
+// NotificationHubInterface defines the hub methods required for streaming events.
+type NotificationHubInterface interface {
+	// Subscribe opens an event stream for a user.
+	//
+	// Parameters:
+	//   - userID: The ID of the user
+	//
+	// Returns:
+	//   - The channel receiving the notifications of the user; closed when the server ends the stream
+	//   - A function closing the stream
+	//   - An error if the user has too many streams open or the server shuts down
+	Subscribe(userID int64) (<-chan *models.Notification, func(), error)
+}
+
+// NotificationHandler streams job progress, detection completion and settings changes to
+// the clients of the logged-in user as server-sent events.
+type NotificationHandler struct {
+	hub NotificationHubInterface
+
+	// keepAlive is how often an idle stream sends a comment
+	keepAlive time.Duration
+}
+
+// NewNotificationHandler creates a new NotificationHandler with the provided hub.
+//
+// Parameters:
+//   - hub: The hub delivering the notifications
+//
+// Returns:
+//   - A properly initialized NotificationHandler
+func NewNotificationHandler(hub NotificationHubInterface) *NotificationHandler {
+	return &NotificationHandler{
+		hub:       hub,
+		keepAlive: constants.NotificationKeepAliveInterval,
+	}
+}
+
+// StreamEvents streams the events of the logged-in user until the client disconnects.
+// Each event is named by its type (job_updated, detection_completed or settings_changed)
+// and carries the notification as JSON. Events are not replayed: after reconnecting,
+// clients fetch the state they may have missed from /api/jobs and /api/settings/changes.
+//
+// HTTP Method:
+//   - GET
+//
+// URL Path:
+//   - /api/events
+//
+// Requires:
+//   - Authentication: User must be logged in
+//
+// Responses:
+//   - 200 OK: Event stream (text/event-stream)
+//   - 401 Unauthorized: User not authenticated
+//   - 429 Too Many Requests: Too many event streams open
+//   - 503 Service Unavailable: The server is shutting down
+//
+// @Summary Stream events
+// @Description Streams job progress, detection completion and settings changes as server-sent events
+// @Tags Events
+// @Produce text/event-stream
+// @Security BearerAuth
+// @Success 200 {object} models.Notification "Event stream"
+// @Failure 401 {object} utils.Response{error=string} "User not authenticated"
+// @Failure 429 {object} utils.Response{error=string} "Too many event streams"
+// @Failure 503 {object} utils.Response{error=string} "Server shutting down"
+// @Router /events [get]
+func (h *NotificationHandler) StreamEvents(w http.ResponseWriter, r *http.Request) {
+	userID, ok := auth.GetUserID(r)
+	if !ok {
+		utils.Unauthorized(w, constants.MsgAuthRequired)
+		return
+	}
+
+	notifications, unsubscribe, err := h.hub.Subscribe(userID)
+	if err != nil {
+		utils.ErrorFromAppError(w, utils.ParseError(err))
+		return
+	}
+	defer unsubscribe()
+
+	w.Header().Set(constants.HeaderContentType, constants.ContentTypeEventStream)
+	w.Header().Set(constants.HeaderCacheControl, constants.CacheControlNoStore)
+	// Ask reverse proxies not to buffer the stream
+	w.Header().Set(constants.HeaderXAccelBuffering, "no")
+	w.WriteHeader(constants.StatusOK)
+
+	controller := http.NewResponseController(w)
+	stream := &eventStream{
+		w:          &deadlineWriter{w: w, controller: controller},
+		controller: controller,
+	}
+	if err := stream.send(fmt.Sprintf("retry: %d\n\n", constants.NotificationClientRetry.Milliseconds())); err != nil {
+		return
+	}
+
+	keepAlive := time.NewTicker(h.keepAlive)
+	defer keepAlive.Stop()
+
+	for {
+		select {
+		case <-r.Context().Done():
+			return
+		case <-keepAlive.C:
+			err = stream.send(": keep-alive\n\n")
+		case notification, open := <-notifications:
+			if !open {
+				// The server shuts down or the client fell behind; the client reconnects
+				return
+			}
+			err = stream.event(notification)
+		}
+		if err != nil {
+			log.Debug().Err(err).Int64("user_id", userID).Msg("Event stream closed")
+			return
+		}
+	}
+}
+
+// eventStream writes server-sent events, flushing each so it reaches the client at once.
+type eventStream struct {
+	w          *deadlineWriter
+	controller *http.ResponseController
+}
+
+// event writes a notification as an event named by its type.
+func (s *eventStream) event(notification *models.Notification) error {
+	data, err := json.Marshal(notification)
+	if err != nil {
+		return fmt.Errorf("failed to encode notification: %w", err)
+	}
+	return s.send(fmt.Sprintf("event: %s\ndata: %s\n\n", notification.Type, data))
+}
+
+// send writes a chunk of the stream and flushes it.
+func (s *eventStream) send(chunk string) error {
+	if _, err := s.w.Write([]byte(chunk)); err != nil {
+		return err
+	}
+	if err := s.controller.Flush(); err != nil && !errors.Is(err, http.ErrNotSupported) {
+		return err
+	}
+	return nil
+}
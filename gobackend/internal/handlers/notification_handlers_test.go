@@ -0,0 +1,100 @@
+package handlers_test
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/yasinhessnawi1/Hideme_Backend/internal/constants"
+	"github.com/yasinhessnawi1/Hideme_Backend/internal/handlers"
+	"github.com/yasinhessnawi1/Hideme_Backend/internal/models"
+	"github.com/yasinhessnawi1/Hideme_Backend/internal/testsupport/golden"
+	"github.com/yasinhessnawi1/Hideme_Backend/internal/utils"
+)
+
+// FakeNotificationHub hands out one stream, filled before the request
+type FakeNotificationHub struct {
+	stream       chan *models.Notification
+	err          error
+	subscriber   int64
+	unsubscribed bool
+}
+
+func (f *FakeNotificationHub) Subscribe(userID int64) (<-chan *models.Notification, func(), error) {
+	if f.err != nil {
+		return nil, nil, f.err
+	}
+	f.subscriber = userID
+	return f.stream, func() { f.unsubscribed = true }, nil
+}
+
+func TestStreamEvents(t *testing.T) {
+	t.Run("Streams notifications until the stream is closed", func(t *testing.T) {
+		hub := &FakeNotificationHub{stream: make(chan *models.Notification, 2)}
+		hub.stream <- &models.Notification{
+			Type:      constants.NotificationJobUpdated,
+			UserID:    7,
+			Data:      json.RawMessage(`{"id":3,"status":"running"}`),
+			CreatedAt: time.Date(2026, 3, 1, 12, 0, 0, 0, time.UTC),
+		}
+		hub.stream <- &models.Notification{
+			Type: constants.NotificationSettingsChanged,
+			Data: json.RawMessage(`{"latest_revision":12}`),
+		}
+		close(hub.stream)
+		handler := handlers.NewNotificationHandler(hub)
+
+		req := httptest.NewRequest(http.MethodGet, "/api/events", nil).WithContext(createAuthContext(7))
+		rr := httptest.NewRecorder()
+		handler.StreamEvents(rr, req)
+
+		assert.Equal(t, http.StatusOK, rr.Code)
+		assert.Equal(t, constants.ContentTypeEventStream, rr.Header().Get(constants.HeaderContentType))
+		assert.Equal(t, "retry: 5000\n\n"+
+			"event: job_updated\n"+
+			`data: {"type":"job_updated","data":{"id":3,"status":"running"},"created_at":"2026-03-01T12:00:00Z"}`+"\n\n"+
+			"event: settings_changed\n"+
+			`data: {"type":"settings_changed","data":{"latest_revision":12},"created_at":"0001-01-01T00:00:00Z"}`+"\n\n",
+			rr.Body.String())
+		assert.Equal(t, int64(7), hub.subscriber)
+		assert.True(t, hub.unsubscribed)
+	})
+
+	t.Run("Client disconnects", func(t *testing.T) {
+		hub := &FakeNotificationHub{stream: make(chan *models.Notification)}
+		handler := handlers.NewNotificationHandler(hub)
+
+		ctx, cancel := context.WithCancel(createAuthContext(7))
+		cancel()
+		req := httptest.NewRequest(http.MethodGet, "/api/events", nil).WithContext(ctx)
+		rr := httptest.NewRecorder()
+		handler.StreamEvents(rr, req)
+
+		assert.True(t, hub.unsubscribed)
+	})
+
+	t.Run("Too many streams", func(t *testing.T) {
+		hub := &FakeNotificationHub{err: utils.New(utils.ErrBadRequest, constants.StatusTooManyRequests, constants.MsgTooManyNotificationStreams)}
+		handler := handlers.NewNotificationHandler(hub)
+
+		req := httptest.NewRequest(http.MethodGet, "/api/events", nil).WithContext(createAuthContext(7))
+		rr := httptest.NewRecorder()
+		handler.StreamEvents(rr, req)
+
+		golden.AssertResponse(t, rr)
+	})
+
+	t.Run("Unauthorized", func(t *testing.T) {
+		handler := handlers.NewNotificationHandler(&FakeNotificationHub{})
+
+		rr := httptest.NewRecorder()
+		handler.StreamEvents(rr, httptest.NewRequest(http.MethodGet, "/api/events", nil))
+
+		assert.Equal(t, http.StatusUnauthorized, rr.Code)
+	})
+}
@@ -0,0 +1,213 @@
+// Package handlers provides HTTP request handlers for the HideMe API.
+package handlers
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+
+	"github.com/yasinhessnawi1/Hideme_Backend/internal/constants"
+	"github.com/yasinhessnawi1/Hideme_Backend/internal/models"
+	"github.com/yasinhessnawi1/Hideme_Backend/internal/utils"
+)
+
+// OAuthServiceInterface defines the service methods required for signing in with providers.
+type OAuthServiceInterface interface {
+	Start(provider string) (*models.OAuthAuthorization, error)
+	Callback(ctx context.Context, provider string, req *models.OAuthCallbackRequest, flow string, creds *models.UserCredentials) (*models.User, string, string, error)
+}
+
+// OAuthHandler handles HTTP requests for signing in with OpenID Connect providers.
+// Both endpoints are visited by the browser rather than called by the frontend: start
+// redirects to the provider, and the provider redirects back to the callback.
+type OAuthHandler struct {
+	oauthService     OAuthServiceInterface
+	jwtService       JWTServiceInterface
+	loginRedirectURL string
+}
+
+// NewOAuthHandler creates a new OAuthHandler with the provided services.
+//
+// Parameters:
+//   - oauthService: Service signing users in with providers
+//   - jwtService: Service providing the token lifetimes for the cookies
+//   - loginRedirectURL: The frontend page users are sent back to; empty to respond with JSON
+//
+// Returns:
+//   - A properly initialized OAuthHandler
+func NewOAuthHandler(oauthService OAuthServiceInterface, jwtService JWTServiceInterface, loginRedirectURL string) *OAuthHandler {
+	return &OAuthHandler{
+		oauthService:     oauthService,
+		jwtService:       jwtService,
+		loginRedirectURL: loginRedirectURL,
+	}
+}
+
+// Start starts signing in with a provider and redirects the browser to its sign-in page.
+// The sign-in flow is kept in a cookie, so only this browser can complete it.
+//
+// HTTP Method:
+//   - GET
+//
+// URL Path:
+//   - /api/auth/oauth/{provider}/start
+//
+// Responses:
+//   - 302 Found: Redirect to the provider's sign-in page
+//   - 400 Bad Request: Unknown provider, or not configured on this server
+//   - 500 Internal Server Error: Server-side error
+//
+// @Summary Start signing in with a provider
+// @Description Redirects to the sign-in page of Google or Microsoft, which redirects back to the callback
+// @Tags Authentication
+// @Param provider path string true "google or microsoft"
+// @Success 302 "Redirect to the provider"
+// @Failure 400 {object} utils.Response{error=string} "Unknown or unavailable provider"
+// @Failure 500 {object} utils.Response{error=string} "Server error"
+// @Router /auth/oauth/{provider}/start [get]
+func (h *OAuthHandler) Start(w http.ResponseWriter, r *http.Request) {
+	authorization, err := h.oauthService.Start(chi.URLParam(r, constants.ParamProvider))
+	if err != nil {
+		utils.ErrorFromAppError(w, utils.ParseError(err))
+		return
+	}
+
+	// The provider sends the user back with a top-level navigation, which Lax cookies survive
+	http.SetCookie(w, &http.Cookie{
+		Name:     constants.OAuthFlowCookie,
+		Value:    authorization.Flow,
+		Path:     constants.OAuthCookiePath,
+		HttpOnly: true,
+		Secure:   h.secureCookies(r),
+		SameSite: http.SameSiteLaxMode,
+		MaxAge:   int(time.Until(authorization.ExpiresAt).Seconds()),
+		Expires:  authorization.ExpiresAt,
+	})
+
+	http.Redirect(w, r, authorization.AuthorizationURL, http.StatusFound)
+}
+
+// Callback completes signing in with a provider. The provider account is linked to the
+// user with its verified email address, or to a new user, the first time it is used.
+// With a login redirect URL configured, the browser is sent there with the refresh token
+// cookie set, or with the error message; otherwise the tokens are returned like on login.
+//
+// HTTP Method:
+//   - GET
+//
+// URL Path:
+//   - /api/auth/oauth/{provider}/callback
+//
+// Query Parameters:
+//   - code: The authorization code issued by the provider
+//   - state: The state of the sign-in flow
+//   - error: Set by the provider instead of the code if the sign-in was cancelled
+//
+// Responses:
+//   - 200 OK: Signed in (without a login redirect URL)
+//   - 302 Found: Redirect to the login redirect URL
+//   - 400 Bad Request: Sign-in expired, forged, started in another browser or refused
+//   - 403 Forbidden: No verified email address for a new account, or account disabled
+//   - 409 Conflict: An account has the email address, but the provider did not verify it
+//   - 500 Internal Server Error: Server-side error
+//
+// @Summary Complete signing in with a provider
+// @Description Exchanges the code for an ID token and signs in, linking or creating the account
+// @Tags Authentication
+// @Produce json
+// @Param provider path string true "google or microsoft"
+// @Param code query string false "Authorization code"
+// @Param state query string false "State of the sign-in flow"
+// @Param error query string false "Error returned by the provider"
+// @Success 200 {object} utils.Response{data=map[string]interface{}} "Signed in"
+// @Success 302 "Redirect to the frontend"
+// @Failure 400 {object} utils.Response{error=string} "Invalid or refused sign-in"
+// @Failure 403 {object} utils.Response{error=string} "Email not verified or account disabled"
+// @Failure 409 {object} utils.Response{error=string} "Email address used by another account"
+// @Failure 500 {object} utils.Response{error=string} "Server error"
+// @Router /auth/oauth/{provider}/callback [get]
+func (h *OAuthHandler) Callback(w http.ResponseWriter, r *http.Request) {
+	if h.jwtService == nil || h.jwtService.GetConfig() == nil {
+		utils.InternalServerError(w, errors.New("JWT configuration not initialized"))
+		return
+	}
+
+	var flow string
+	if cookie, err := r.Cookie(constants.OAuthFlowCookie); err == nil {
+		flow = cookie.Value
+	}
+
+	// The flow is used once, whatever the outcome
+	http.SetCookie(w, &http.Cookie{
+		Name:     constants.OAuthFlowCookie,
+		Value:    "",
+		Path:     constants.OAuthCookiePath,
+		HttpOnly: true,
+		Secure:   h.secureCookies(r),
+		SameSite: http.SameSiteLaxMode,
+		MaxAge:   -1,
+		Expires:  time.Unix(0, 0),
+	})
+
+	query := r.URL.Query()
+	req := &models.OAuthCallbackRequest{
+		Code:  query.Get("code"),
+		State: query.Get("state"),
+		Error: query.Get("error"),
+	}
+	creds := &models.UserCredentials{
+		ClientIP:  utils.GetClientIP(r),
+		UserAgent: r.UserAgent(),
+	}
+
+	user, accessToken, refreshToken, err := h.oauthService.Callback(r.Context(), chi.URLParam(r, constants.ParamProvider), req, flow, creds)
+	if err != nil {
+		appErr := utils.ParseError(err)
+		if h.loginRedirectURL != "" {
+			http.Redirect(w, r, h.loginRedirectURL+"?"+url.Values{"error": {appErr.Message}}.Encode(), http.StatusFound)
+			return
+		}
+		utils.ErrorFromAppError(w, appErr)
+		return
+	}
+
+	// Set the refresh token as an HTTP-only cookie, as on login
+	refreshExpiry := h.jwtService.GetConfig().RefreshExpiry
+	http.SetCookie(w, &http.Cookie{
+		Name:     constants.RefreshTokenCookie,
+		Value:    refreshToken,
+		Path:     "/",
+		HttpOnly: true,
+		Secure:   h.secureCookies(r),
+		SameSite: http.SameSiteLaxMode,
+		MaxAge:   int(refreshExpiry.Seconds()),
+		Expires:  time.Now().Add(refreshExpiry),
+	})
+
+	// The access token is not put in the URL; the frontend obtains one with the refresh token
+	if h.loginRedirectURL != "" {
+		http.Redirect(w, r, h.loginRedirectURL, http.StatusFound)
+		return
+	}
+
+	utils.JSON(w, constants.StatusOK, map[string]interface{}{
+		"user":         user,
+		"access_token": accessToken,
+		"token_type":   constants.BearerTokenPrefix[:len(constants.BearerTokenPrefix)-1], // Remove the space
+		"expires_in":   int(h.jwtService.GetConfig().Expiry.Seconds()),
+	})
+}
+
+// secureCookies reports whether cookies are restricted to HTTPS, which they are unless
+// the server runs on localhost without TLS.
+func (h *OAuthHandler) secureCookies(r *http.Request) bool {
+	if r.TLS != nil {
+		return true
+	}
+	return h.jwtService == nil || h.jwtService.GetConfig() == nil || !strings.Contains(h.jwtService.GetConfig().Issuer, "localhost")
+}
@@ -0,0 +1,195 @@
+// oauth_handlers_test.go
+
+package handlers
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/yasinhessnawi1/Hideme_Backend/internal/config"
+	"github.com/yasinhessnawi1/Hideme_Backend/internal/constants"
+	"github.com/yasinhessnawi1/Hideme_Backend/internal/models"
+	"github.com/yasinhessnawi1/Hideme_Backend/internal/testsupport/golden"
+	"github.com/yasinhessnawi1/Hideme_Backend/internal/utils"
+)
+
+// MockOAuthService implements the OAuthServiceInterface for testing
+type MockOAuthService struct {
+	StartFunc    func(provider string) (*models.OAuthAuthorization, error)
+	CallbackFunc func(ctx context.Context, provider string, req *models.OAuthCallbackRequest, flow string, creds *models.UserCredentials) (*models.User, string, string, error)
+}
+
+func (m *MockOAuthService) Start(provider string) (*models.OAuthAuthorization, error) {
+	return m.StartFunc(provider)
+}
+
+func (m *MockOAuthService) Callback(ctx context.Context, provider string, req *models.OAuthCallbackRequest, flow string, creds *models.UserCredentials) (*models.User, string, string, error) {
+	return m.CallbackFunc(ctx, provider, req, flow, creds)
+}
+
+func newOAuthRequest(target, provider string) *http.Request {
+	req := httptest.NewRequest(http.MethodGet, target, nil)
+	chiCtx := chi.NewRouteContext()
+	chiCtx.URLParams.Add(constants.ParamProvider, provider)
+	return req.WithContext(context.WithValue(req.Context(), chi.RouteCtxKey, chiCtx))
+}
+
+func findCookie(rec *httptest.ResponseRecorder, name string) *http.Cookie {
+	for _, cookie := range rec.Result().Cookies() {
+		if cookie.Name == name {
+			return cookie
+		}
+	}
+	return nil
+}
+
+func newOAuthJWTService() *MockJWTService {
+	return &MockJWTService{Config: &config.JWTSettings{
+		Expiry:        15 * time.Minute,
+		RefreshExpiry: 24 * time.Hour,
+		Issuer:        "localhost",
+	}}
+}
+
+func TestOAuthHandler_Start(t *testing.T) {
+	t.Run("Redirects to the provider", func(t *testing.T) {
+		mockService := &MockOAuthService{
+			StartFunc: func(provider string) (*models.OAuthAuthorization, error) {
+				if provider != constants.OAuthProviderGoogle {
+					t.Errorf("Expected provider google, got %s", provider)
+				}
+				return &models.OAuthAuthorization{
+					AuthorizationURL: "https://accounts.google.com/o/oauth2/v2/auth?state=abc",
+					Flow:             "signed-flow",
+					ExpiresAt:        time.Now().Add(constants.OAuthFlowTTL),
+				}, nil
+			},
+		}
+		handler := NewOAuthHandler(mockService, newOAuthJWTService(), "")
+
+		rec := httptest.NewRecorder()
+		handler.Start(rec, newOAuthRequest("/api/auth/oauth/google/start", constants.OAuthProviderGoogle))
+
+		if rec.Code != http.StatusFound {
+			t.Fatalf("Expected status %d, got %d", http.StatusFound, rec.Code)
+		}
+		if location := rec.Header().Get("Location"); location != "https://accounts.google.com/o/oauth2/v2/auth?state=abc" {
+			t.Errorf("Unexpected redirect %s", location)
+		}
+		cookie := findCookie(rec, constants.OAuthFlowCookie)
+		if cookie == nil || cookie.Value != "signed-flow" || !cookie.HttpOnly || cookie.Path != constants.OAuthCookiePath {
+			t.Errorf("Unexpected flow cookie %v", cookie)
+		}
+	})
+
+	t.Run("Unknown provider", func(t *testing.T) {
+		mockService := &MockOAuthService{
+			StartFunc: func(provider string) (*models.OAuthAuthorization, error) {
+				return nil, utils.NewValidationError(constants.ParamProvider, constants.MsgOAuthProviderUnknown)
+			},
+		}
+		handler := NewOAuthHandler(mockService, newOAuthJWTService(), "")
+
+		rec := httptest.NewRecorder()
+		handler.Start(rec, newOAuthRequest("/api/auth/oauth/github/start", "github"))
+
+		if rec.Code != http.StatusBadRequest {
+			t.Errorf("Expected status %d, got %d", http.StatusBadRequest, rec.Code)
+		}
+		if findCookie(rec, constants.OAuthFlowCookie) != nil {
+			t.Error("Expected no flow cookie")
+		}
+	})
+}
+
+func TestOAuthHandler_Callback(t *testing.T) {
+	success := func(t *testing.T) *MockOAuthService {
+		return &MockOAuthService{
+			CallbackFunc: func(ctx context.Context, provider string, req *models.OAuthCallbackRequest, flow string, creds *models.UserCredentials) (*models.User, string, string, error) {
+				if req.Code != "code123" || req.State != "abc" || flow != "signed-flow" {
+					t.Errorf("Unexpected callback %+v with flow %s", req, flow)
+				}
+				return &models.User{ID: 1, Username: "ola"}, "access_token_123", "refresh_token_456", nil
+			},
+		}
+	}
+	failure := &MockOAuthService{
+		CallbackFunc: func(ctx context.Context, provider string, req *models.OAuthCallbackRequest, flow string, creds *models.UserCredentials) (*models.User, string, string, error) {
+			return nil, "", "", utils.NewBadRequestError(constants.MsgOAuthStateInvalid)
+		},
+	}
+	newCallback := func() *http.Request {
+		req := newOAuthRequest("/api/auth/oauth/google/callback?code=code123&state=abc", constants.OAuthProviderGoogle)
+		req.AddCookie(&http.Cookie{Name: constants.OAuthFlowCookie, Value: "signed-flow"})
+		return req
+	}
+
+	t.Run("Responds with the tokens", func(t *testing.T) {
+		handler := NewOAuthHandler(success(t), newOAuthJWTService(), "")
+
+		rec := httptest.NewRecorder()
+		handler.Callback(rec, newCallback())
+
+		golden.AssertResponse(t, rec)
+		if cookie := findCookie(rec, constants.RefreshTokenCookie); cookie == nil || cookie.Value != "refresh_token_456" {
+			t.Errorf("Unexpected refresh token cookie %v", cookie)
+		}
+		if cookie := findCookie(rec, constants.OAuthFlowCookie); cookie == nil || cookie.MaxAge >= 0 {
+			t.Errorf("Expected flow cookie to be cleared, got %v", cookie)
+		}
+	})
+
+	t.Run("Redirects to the frontend", func(t *testing.T) {
+		handler := NewOAuthHandler(success(t), newOAuthJWTService(), "https://app.example.com/login")
+
+		rec := httptest.NewRecorder()
+		handler.Callback(rec, newCallback())
+
+		if rec.Code != http.StatusFound {
+			t.Fatalf("Expected status %d, got %d", http.StatusFound, rec.Code)
+		}
+		if location := rec.Header().Get("Location"); location != "https://app.example.com/login" {
+			t.Errorf("Unexpected redirect %s", location)
+		}
+		if findCookie(rec, constants.RefreshTokenCookie) == nil {
+			t.Error("Expected refresh token cookie")
+		}
+	})
+
+	t.Run("Responds with the error", func(t *testing.T) {
+		handler := NewOAuthHandler(failure, newOAuthJWTService(), "")
+
+		rec := httptest.NewRecorder()
+		handler.Callback(rec, newCallback())
+
+		if rec.Code != http.StatusBadRequest {
+			t.Errorf("Expected status %d, got %d", http.StatusBadRequest, rec.Code)
+		}
+		if findCookie(rec, constants.RefreshTokenCookie) != nil {
+			t.Error("Expected no refresh token cookie")
+		}
+	})
+
+	t.Run("Redirects with the error", func(t *testing.T) {
+		handler := NewOAuthHandler(failure, newOAuthJWTService(), "https://app.example.com/login")
+
+		rec := httptest.NewRecorder()
+		handler.Callback(rec, newCallback())
+
+		if rec.Code != http.StatusFound {
+			t.Fatalf("Expected status %d, got %d", http.StatusFound, rec.Code)
+		}
+		location, err := url.Parse(rec.Header().Get("Location"))
+		if err != nil {
+			t.Fatalf("Invalid redirect: %v", err)
+		}
+		if location.Query().Get("error") != constants.MsgOAuthStateInvalid {
+			t.Errorf("Unexpected redirect %s", location)
+		}
+	})
+}
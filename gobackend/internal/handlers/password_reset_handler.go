@@ -69,6 +69,11 @@ func (h *PasswordResetHandler) ForgotPassword(w http.ResponseWriter, r *http.Req
 		return
 	}
 
+	// Only the most recent link works; earlier ones are invalidated
+	if err := h.PasswordResetRepo.DeleteByUserID(ctx, user.ID); err != nil {
+		log.Error().Err(err).Int64("user_id", user.ID).Msg("Failed to invalidate earlier password reset tokens")
+	}
+
 	if err := h.PasswordResetRepo.Create(ctx, user.ID, tokenHash, PasswordResetTokenDuration); err != nil {
 		log.Error().Err(err).Int64("user_id", user.ID).Msg("Failed to store password reset token")
 		utils.JSON(w, http.StatusOK, genericMsg)
@@ -106,7 +111,8 @@ func (h *PasswordResetHandler) ResetPassword(w http.ResponseWriter, r *http.Requ
 	hash := sha256.Sum256([]byte(req.Token))
 	tokenHashToValidate := hex.EncodeToString(hash[:])
 
-	userID, expiresAt, err := h.PasswordResetRepo.GetUserIDByTokenHash(ctx, tokenHashToValidate)
+	// Consume the token up front, so concurrent requests cannot both use it
+	userID, expiresAt, err := h.PasswordResetRepo.Consume(ctx, tokenHashToValidate)
 	if err != nil {
 		if errors.Is(err, repository.ErrTokenNotFound) {
 			utils.JSON(w, http.StatusBadRequest, "Invalid or expired password reset token.")
@@ -118,10 +124,6 @@ func (h *PasswordResetHandler) ResetPassword(w http.ResponseWriter, r *http.Requ
 	}
 
 	if time.Now().After(expiresAt) {
-		// Attempt to delete the expired token
-		if delErr := h.PasswordResetRepo.Delete(ctx, tokenHashToValidate); delErr != nil {
-			log.Error().Err(delErr).Str("token_hash", tokenHashToValidate).Msg("Failed to delete expired token")
-		}
 		utils.JSON(w, http.StatusBadRequest, "Password reset token has expired.")
 		return
 	}
@@ -144,12 +146,6 @@ func (h *PasswordResetHandler) ResetPassword(w http.ResponseWriter, r *http.Requ
 		return
 	}
 
-	// Password changed successfully, delete the reset token
-	if err := h.PasswordResetRepo.Delete(ctx, tokenHashToValidate); err != nil {
-		log.Error().Err(err).Str("token_hash", tokenHashToValidate).Msg("Failed to delete used password reset token")
-		// Continue, as password was successfully changed. This is a cleanup step.
-	}
-
 	// Optionally, delete all tokens for this user to invalidate any other pending requests
 	if err := h.PasswordResetRepo.DeleteByUserID(ctx, userID); err != nil {
 		log.Error().Err(err).Int64("user_id", userID).Msg("Failed to delete all password reset tokens for user")
@@ -0,0 +1,98 @@
+// Package handlers provides HTTP request handlers for the HideMe API.
+package handlers
+
+import (
+	"context"
+	"net/http"
+
+	"github.com/yasinhessnawi1/Hideme_Backend/internal/auth"
+	"github.com/yasinhessnawi1/Hideme_Backend/internal/constants"
+	"github.com/yasinhessnawi1/Hideme_Backend/internal/models"
+	"github.com/yasinhessnawi1/Hideme_Backend/internal/utils"
+)
+
+// PermissionServiceInterface defines the service methods required for permission introspection.
+type PermissionServiceInterface interface {
+	// GetPermissions returns the effective permissions of a caller.
+	//
+	// Parameters:
+	//   - ctx: Context for the operation
+	//   - userID: The ID of the caller
+	//   - role: The role of the caller
+	//   - apiKey: Whether the caller authenticated with an API key
+	//   - keyScopes: The scopes of the API key; empty for a key with full access
+	//
+	// Returns:
+	//   - The permissions of the caller
+	//   - An error if retrieval fails
+	GetPermissions(ctx context.Context, userID int64, role string, apiKey bool, keyScopes []string) (*models.Permissions, error)
+}
+
+// PermissionHandler handles HTTP requests related to the permissions of the caller.
+type PermissionHandler struct {
+	permissionService PermissionServiceInterface
+}
+
+// NewPermissionHandler creates a new PermissionHandler with the provided permission service.
+//
+// Parameters:
+//   - permissionService: Service computing the permissions of callers
+//
+// Returns:
+//   - A properly initialized PermissionHandler
+func NewPermissionHandler(permissionService PermissionServiceInterface) *PermissionHandler {
+	return &PermissionHandler{
+		permissionService: permissionService,
+	}
+}
+
+// GetMyPermissions returns the effective permissions of the caller: its role, the scopes
+// and capabilities it has, the policies applying to all users and the features the
+// deployment offers. Callers using an API key get the scopes of that key.
+//
+// HTTP Method:
+//   - GET
+//
+// URL Path:
+//   - /api/users/me/permissions
+//
+// Requires:
+//   - Authentication: User must be logged in or send an API key
+//
+// Responses:
+//   - 200 OK: Permissions retrieved successfully
+//   - 401 Unauthorized: User not authenticated
+//   - 500 Internal Server Error: Server-side error
+//
+// @Summary Get current permissions
+// @Description Returns the roles, scopes, capabilities, policies and features applying to the caller
+// @Tags Users
+// @Produce json
+// @Security BearerAuth
+// @Security ApiKeyAuth
+// @Success 200 {object} utils.Response{data=models.Permissions} "Permissions retrieved successfully"
+// @Failure 401 {object} utils.Response{error=string} "User not authenticated"
+// @Failure 500 {object} utils.Response{error=string} "Server error"
+// @Router /users/me/permissions [get]
+func (h *PermissionHandler) GetMyPermissions(w http.ResponseWriter, r *http.Request) {
+	userID, ok := auth.GetUserID(r)
+	if !ok {
+		utils.Unauthorized(w, constants.MsgAuthRequired)
+		return
+	}
+
+	// Callers without a role in their token get the permissions of a standard user
+	role, ok := GetUserRole(r)
+	if !ok || role == "" {
+		role = constants.RoleUser
+	}
+	keyScopes, apiKey := auth.GetAPIKeyScopes(r)
+
+	permissions, err := h.permissionService.GetPermissions(r.Context(), userID, role, apiKey, keyScopes)
+	if err != nil {
+		utils.ErrorFromAppError(w, utils.ParseError(err))
+		return
+	}
+
+	utils.JSON(w, constants.StatusOK, permissions)
+}
@@ -0,0 +1,105 @@
+package handlers_test
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+
+	"github.com/yasinhessnawi1/Hideme_Backend/internal/auth"
+	"github.com/yasinhessnawi1/Hideme_Backend/internal/constants"
+	"github.com/yasinhessnawi1/Hideme_Backend/internal/handlers"
+	"github.com/yasinhessnawi1/Hideme_Backend/internal/models"
+	"github.com/yasinhessnawi1/Hideme_Backend/internal/testsupport/golden"
+)
+
+// MockPermissionService is a mock implementation of the PermissionService
+type MockPermissionService struct {
+	mock.Mock
+}
+
+func (m *MockPermissionService) GetPermissions(ctx context.Context, userID int64, role string, apiKey bool, keyScopes []string) (*models.Permissions, error) {
+	args := m.Called(ctx, userID, role, apiKey, keyScopes)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*models.Permissions), args.Error(1)
+}
+
+func setupPermissionTest() (*chi.Mux, *MockPermissionService) {
+	mockService := new(MockPermissionService)
+	handler := handlers.NewPermissionHandler(mockService)
+
+	router := chi.NewRouter()
+	router.Get("/api/users/me/permissions", handler.GetMyPermissions)
+
+	return router, mockService
+}
+
+func TestGetMyPermissions(t *testing.T) {
+	router, mockService := setupPermissionTest()
+
+	t.Run("Success", func(t *testing.T) {
+		permissions := models.NewPermissions(1, constants.RoleAdmin, false, nil)
+		permissions.Policies = models.PermissionPolicies{APIKeyMaxLifetimeDays: 90, MaxPages: 50, ApprovalRequiredActions: []string{"user_role"}}
+		permissions.Features = map[string]bool{constants.FeatureOAuthGoogle: true, constants.FeaturePIIScreening: false}
+		mockService.On("GetPermissions", mock.Anything, int64(1), constants.RoleAdmin, false, []string(nil)).Return(permissions, nil).Once()
+
+		req, err := http.NewRequest("GET", "/api/users/me/permissions", nil)
+		require.NoError(t, err)
+		ctx := context.WithValue(createAuthContext(1), handlers.GetContextKeyUserRole(), constants.RoleAdmin)
+		req = req.WithContext(ctx)
+
+		rr := httptest.NewRecorder()
+		router.ServeHTTP(rr, req)
+
+		golden.AssertResponse(t, rr)
+	})
+
+	t.Run("API key scopes", func(t *testing.T) {
+		scopes := []string{constants.APIKeyScopeDocumentsRead}
+		permissions := models.NewPermissions(1, constants.RoleUser, true, scopes)
+		mockService.On("GetPermissions", mock.Anything, int64(1), constants.RoleUser, true, scopes).Return(permissions, nil).Once()
+
+		req, err := http.NewRequest("GET", "/api/users/me/permissions", nil)
+		require.NoError(t, err)
+		ctx := context.WithValue(createAuthContext(1), auth.APIKeyScopesContextKey, scopes)
+		req = req.WithContext(ctx)
+
+		rr := httptest.NewRecorder()
+		router.ServeHTTP(rr, req)
+
+		golden.AssertResponse(t, rr)
+	})
+
+	t.Run("Unauthorized", func(t *testing.T) {
+		req, err := http.NewRequest("GET", "/api/users/me/permissions", nil)
+		require.NoError(t, err)
+
+		rr := httptest.NewRecorder()
+		router.ServeHTTP(rr, req)
+
+		assert.Equal(t, http.StatusUnauthorized, rr.Code)
+	})
+
+	t.Run("Service error", func(t *testing.T) {
+		mockService.On("GetPermissions", mock.Anything, int64(2), constants.RoleUser, false, []string(nil)).Return(nil, errors.New("database error")).Once()
+
+		req, err := http.NewRequest("GET", "/api/users/me/permissions", nil)
+		require.NoError(t, err)
+		req = req.WithContext(createAuthContext(2))
+
+		rr := httptest.NewRecorder()
+		router.ServeHTTP(rr, req)
+
+		assert.Equal(t, http.StatusInternalServerError, rr.Code)
+	})
+
+	mockService.AssertExpectations(t)
+}
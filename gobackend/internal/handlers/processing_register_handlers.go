@@ -0,0 +1,53 @@
+// Package handlers provides HTTP request handlers for the HideMe API.
+package handlers
+
+import (
+	"net/http"
+
+	"github.com/yasinhessnawi1/Hideme_Backend/internal/constants"
+	"github.com/yasinhessnawi1/Hideme_Backend/internal/utils"
+	"github.com/yasinhessnawi1/Hideme_Backend/internal/utils/gdprlog"
+)
+
+// ProcessingRegisterHandler publishes the register of personal and sensitive data the
+// application stores, as classified by the gdpr struct tags of the models.
+type ProcessingRegisterHandler struct{}
+
+// NewProcessingRegisterHandler creates a new ProcessingRegisterHandler.
+//
+// Returns:
+//   - A properly initialized ProcessingRegisterHandler
+func NewProcessingRegisterHandler() *ProcessingRegisterHandler {
+	return &ProcessingRegisterHandler{}
+}
+
+// GetProcessingRegister lists the models holding personal or sensitive data, with the
+// table they are stored in and the GDPR category of each classified field.
+//
+// HTTP Method:
+//   - GET
+//
+// URL Path:
+//   - /api/admin/processing-register
+//
+// Requires:
+//   - Authentication: Admin role
+//
+// Responses:
+//   - 200 OK: The classified models, ordered by name
+//   - 401 Unauthorized: User not authenticated
+//   - 403 Forbidden: User not authorized (not admin)
+//
+// @Summary Get processing register
+// @Description Lists the models holding personal or sensitive data and the GDPR category of each of their classified fields
+// @Tags Admin/GDPR
+// @Produce json
+// @Security BearerAuth
+// @Security ApiKeyAuth
+// @Success 200 {object} utils.Response{data=[]gdprlog.ModelClassification} "Processing register"
+// @Failure 401 {object} utils.Response{error=string} "User not authenticated"
+// @Failure 403 {object} utils.Response{error=string} "User not authorized"
+// @Router /admin/processing-register [get]
+func (h *ProcessingRegisterHandler) GetProcessingRegister(w http.ResponseWriter, r *http.Request) {
+	utils.JSON(w, constants.StatusOK, gdprlog.RegisteredModels())
+}
@@ -0,0 +1,50 @@
+package handlers_test
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/yasinhessnawi1/Hideme_Backend/internal/handlers"
+)
+
+func TestGetProcessingRegister(t *testing.T) {
+	handler := handlers.NewProcessingRegisterHandler()
+
+	req, err := http.NewRequest("GET", "/api/admin/processing-register", nil)
+	require.NoError(t, err)
+	rr := httptest.NewRecorder()
+	handler.GetProcessingRegister(rr, req)
+
+	assert.Equal(t, http.StatusOK, rr.Code)
+
+	var response struct {
+		Data []struct {
+			Model  string `json:"model"`
+			Table  string `json:"table"`
+			Fields []struct {
+				Field    string `json:"field"`
+				Category string `json:"category"`
+			} `json:"fields"`
+		} `json:"data"`
+	}
+	require.NoError(t, json.Unmarshal(rr.Body.Bytes(), &response))
+
+	categories := make(map[string]string)
+	for _, model := range response.Data {
+		if model.Model != "User" {
+			continue
+		}
+		assert.Equal(t, "users", model.Table)
+		for _, field := range model.Fields {
+			categories[field.Field] = field.Category
+		}
+	}
+	assert.Equal(t, "personal", categories["email"])
+	assert.Equal(t, "sensitive", categories["password_hash"])
+	assert.NotContains(t, categories, "role")
+}
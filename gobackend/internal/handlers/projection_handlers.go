@@ -0,0 +1,119 @@
+// Package handlers provides HTTP request handlers for the HideMe API.
+package handlers
+
+import (
+	"context"
+	"net/http"
+
+	"github.com/go-chi/chi/v5"
+
+	"github.com/yasinhessnawi1/Hideme_Backend/internal/auth"
+	"github.com/yasinhessnawi1/Hideme_Backend/internal/constants"
+	"github.com/yasinhessnawi1/Hideme_Backend/internal/models"
+	"github.com/yasinhessnawi1/Hideme_Backend/internal/utils"
+)
+
+// ProjectionServiceInterface defines the service methods required for rebuilding projections.
+type ProjectionServiceInterface interface {
+	Projections() []models.Projection
+	Rebuild(ctx context.Context, adminID int64, name string) (*models.ProjectionRebuild, error)
+}
+
+// ProjectionHandler handles HTTP requests for rebuilding derived tables from the event log.
+type ProjectionHandler struct {
+	projectionService ProjectionServiceInterface
+}
+
+// NewProjectionHandler creates a new ProjectionHandler with the provided projection service.
+//
+// Parameters:
+//   - projectionService: Service holding the projections
+//
+// Returns:
+//   - A properly initialized ProjectionHandler
+func NewProjectionHandler(projectionService ProjectionServiceInterface) *ProjectionHandler {
+	return &ProjectionHandler{
+		projectionService: projectionService,
+	}
+}
+
+// ListProjections returns the derived tables that can be rebuilt from the event log.
+//
+// HTTP Method:
+//   - GET
+//
+// URL Path:
+//   - /api/admin/projections
+//
+// Requires:
+//   - Authentication: Admin role
+//
+// Responses:
+//   - 200 OK: List of projections
+//   - 401 Unauthorized: User not authenticated
+//   - 403 Forbidden: User not authorized (not admin)
+//
+// @Summary List projections
+// @Description Returns the derived tables that can be rebuilt from the event log
+// @Tags Admin/Projections
+// @Produce json
+// @Security BearerAuth
+// @Security ApiKeyAuth
+// @Success 200 {object} utils.Response{data=[]models.Projection} "List of projections"
+// @Failure 401 {object} utils.Response{error=string} "User not authenticated"
+// @Failure 403 {object} utils.Response{error=string} "User not authorized"
+// @Router /admin/projections [get]
+func (h *ProjectionHandler) ListProjections(w http.ResponseWriter, r *http.Request) {
+	utils.ListAll(w, r, h.projectionService.Projections())
+}
+
+// RebuildProjection rebuilds a derived table by replaying its events, for example once a
+// bug that corrupted its aggregation is fixed. The table is rebuilt within one transaction;
+// changes to it wait until the rebuild finished.
+//
+// HTTP Method:
+//   - POST
+//
+// URL Path:
+//   - /api/admin/projections/{projection}/rebuild
+//
+// Requires:
+//   - Authentication: Admin role
+//
+// Responses:
+//   - 200 OK: Projection rebuilt
+//   - 401 Unauthorized: User not authenticated
+//   - 403 Forbidden: User not authorized (not admin)
+//   - 404 Not Found: Unknown projection
+//   - 409 Conflict: The projection is already being rebuilt
+//   - 500 Internal Server Error: The rebuild failed; the projection is unchanged
+//
+// @Summary Rebuild projection
+// @Description Rebuilds a derived table by replaying its events and returns the outcome
+// @Tags Admin/Projections
+// @Produce json
+// @Security BearerAuth
+// @Security ApiKeyAuth
+// @Param projection path string true "Projection name"
+// @Success 200 {object} utils.Response{data=models.ProjectionRebuild} "Projection rebuilt"
+// @Failure 401 {object} utils.Response{error=string} "User not authenticated"
+// @Failure 403 {object} utils.Response{error=string} "User not authorized"
+// @Failure 404 {object} utils.Response{error=string} "Unknown projection"
+// @Failure 409 {object} utils.Response{error=string} "Projection already being rebuilt"
+// @Failure 500 {object} utils.Response{error=string} "Server error"
+// @Router /admin/projections/{projection}/rebuild [post]
+func (h *ProjectionHandler) RebuildProjection(w http.ResponseWriter, r *http.Request) {
+	adminID, ok := auth.GetUserID(r)
+	if !ok {
+		utils.Unauthorized(w, constants.MsgAuthRequired)
+		return
+	}
+
+	rebuild, err := h.projectionService.Rebuild(r.Context(), adminID, chi.URLParam(r, constants.ParamProjection))
+	if err != nil {
+		utils.ErrorFromAppError(w, utils.ParseError(err))
+		return
+	}
+
+	utils.JSON(w, constants.StatusOK, rebuild)
+}
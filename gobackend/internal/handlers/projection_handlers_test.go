@@ -0,0 +1,128 @@
+package handlers_test
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+
+	"github.com/yasinhessnawi1/Hideme_Backend/internal/constants"
+	"github.com/yasinhessnawi1/Hideme_Backend/internal/handlers"
+	"github.com/yasinhessnawi1/Hideme_Backend/internal/models"
+	"github.com/yasinhessnawi1/Hideme_Backend/internal/testsupport/golden"
+	"github.com/yasinhessnawi1/Hideme_Backend/internal/utils"
+)
+
+// MockProjectionService is a mock implementation of the ProjectionService
+type MockProjectionService struct {
+	mock.Mock
+}
+
+func (m *MockProjectionService) Projections() []models.Projection {
+	args := m.Called()
+	return args.Get(0).([]models.Projection)
+}
+
+func (m *MockProjectionService) Rebuild(ctx context.Context, adminID int64, name string) (*models.ProjectionRebuild, error) {
+	args := m.Called(ctx, adminID, name)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*models.ProjectionRebuild), args.Error(1)
+}
+
+func setupProjectionTest() (*chi.Mux, *MockProjectionService) {
+	mockService := new(MockProjectionService)
+	handler := handlers.NewProjectionHandler(mockService)
+
+	router := chi.NewRouter()
+	router.Get("/api/admin/projections", handler.ListProjections)
+	router.Post("/api/admin/projections/{projection}/rebuild", handler.RebuildProjection)
+
+	return router, mockService
+}
+
+func TestListProjections(t *testing.T) {
+	router, mockService := setupProjectionTest()
+	mockService.On("Projections").Return([]models.Projection{
+		{Name: constants.ProjectionTenantUsage, EventTypes: []string{constants.EventUsageCounted}},
+	})
+
+	req, err := http.NewRequest("GET", "/api/admin/projections", nil)
+	require.NoError(t, err)
+	rr := httptest.NewRecorder()
+	router.ServeHTTP(rr, req)
+
+	golden.AssertResponse(t, rr)
+}
+
+func TestRebuildProjection(t *testing.T) {
+	tests := []struct {
+		name       string
+		projection string
+		rebuild    *models.ProjectionRebuild
+		err        error
+		wantStatus int
+	}{
+		{
+			name:       "Success",
+			projection: constants.ProjectionRuleHits,
+			rebuild:    &models.ProjectionRebuild{Projection: constants.ProjectionRuleHits, EventsReplayed: 12},
+			wantStatus: http.StatusOK,
+		},
+		{
+			name:       "Unknown projection",
+			projection: "coverage",
+			err:        utils.NewNotFoundError("Projection", "coverage"),
+			wantStatus: http.StatusNotFound,
+		},
+		{
+			name:       "Already rebuilding",
+			projection: constants.ProjectionRuleHits,
+			err:        utils.New(utils.ErrBadRequest, constants.StatusConflict, constants.MsgProjectionRebuilding),
+			wantStatus: http.StatusConflict,
+		},
+		{
+			name:       "Rebuild failed",
+			projection: constants.ProjectionRuleHits,
+			err:        errors.New("failed to replay event 9"),
+			wantStatus: http.StatusInternalServerError,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			router, mockService := setupProjectionTest()
+			if tt.rebuild != nil {
+				mockService.On("Rebuild", mock.Anything, int64(1), tt.projection).Return(tt.rebuild, nil)
+			} else {
+				mockService.On("Rebuild", mock.Anything, int64(1), tt.projection).Return(nil, tt.err)
+			}
+
+			req, err := http.NewRequest("POST", "/api/admin/projections/"+tt.projection+"/rebuild", nil)
+			require.NoError(t, err)
+			req = req.WithContext(createAuthContext(1))
+			rr := httptest.NewRecorder()
+			router.ServeHTTP(rr, req)
+
+			assert.Equal(t, tt.wantStatus, rr.Code, rr.Body.String())
+		})
+	}
+
+	t.Run("Unauthorized", func(t *testing.T) {
+		router, _ := setupProjectionTest()
+
+		req, err := http.NewRequest("POST", "/api/admin/projections/rule_hits/rebuild", nil)
+		require.NoError(t, err)
+		rr := httptest.NewRecorder()
+		router.ServeHTTP(rr, req)
+
+		assert.Equal(t, http.StatusUnauthorized, rr.Code)
+	})
+}
@@ -0,0 +1,275 @@
+// Package handlers provides HTTP request handlers for the HideMe API.
+package handlers
+
+import (
+	"context"
+	"net/http"
+	"strconv"
+
+	"github.com/go-chi/chi/v5"
+
+	"github.com/yasinhessnawi1/Hideme_Backend/internal/auth"
+	"github.com/yasinhessnawi1/Hideme_Backend/internal/constants"
+	"github.com/yasinhessnawi1/Hideme_Backend/internal/models"
+	"github.com/yasinhessnawi1/Hideme_Backend/internal/utils"
+)
+
+// QuotaServiceInterface defines the service methods required for detection quota operations.
+type QuotaServiceInterface interface {
+	// Consume takes units from a user's quota.
+	//
+	// Parameters:
+	//   - ctx: Context for the operation
+	//   - userID: The ID of the user
+	//   - units: The number of tokens to consume
+	//
+	// Returns:
+	//   - The quota state after consuming
+	//   - QuotaExceededError if not enough tokens are available
+	Consume(ctx context.Context, userID int64, units int64) (*models.QuotaState, error)
+
+	// GetQuota returns a user's current quota.
+	//
+	// Parameters:
+	//   - ctx: Context for the operation
+	//   - userID: The ID of the user
+	//
+	// Returns:
+	//   - The quota state
+	//   - An error if retrieval fails
+	GetQuota(ctx context.Context, userID int64) (*models.QuotaState, error)
+
+	// AdjustQuota adds tokens to or removes tokens from a user's quota.
+	//
+	// Parameters:
+	//   - ctx: Context for the operation
+	//   - userID: The ID of the user
+	//   - delta: The number of tokens to add (positive) or remove (negative)
+	//
+	// Returns:
+	//   - The quota state after the adjustment
+	//   - An error if the adjustment fails
+	AdjustQuota(ctx context.Context, userID int64, delta int64) (*models.QuotaState, error)
+}
+
+// QuotaHandler handles HTTP requests related to the detection quota.
+type QuotaHandler struct {
+	quotaService QuotaServiceInterface
+}
+
+// NewQuotaHandler creates a new QuotaHandler with the provided quota service.
+//
+// Parameters:
+//   - quotaService: Service handling quota operations
+//
+// Returns:
+//   - A properly initialized QuotaHandler
+func NewQuotaHandler(quotaService QuotaServiceInterface) *QuotaHandler {
+	return &QuotaHandler{
+		quotaService: quotaService,
+	}
+}
+
+// GetMyQuota returns the detection quota of the current user.
+//
+// HTTP Method:
+//   - GET
+//
+// URL Path:
+//   - /api/users/me/quota
+//
+// Requires:
+//   - Authentication: User must be logged in
+//
+// Responses:
+//   - 200 OK: Current quota
+//   - 401 Unauthorized: User not authenticated
+//   - 500 Internal Server Error: Server-side error
+//
+// @Summary Get detection quota
+// @Description Returns the detection quota of the currently authenticated user
+// @Tags Users
+// @Produce json
+// @Security BearerAuth
+// @Success 200 {object} utils.Response{data=models.QuotaState} "Current quota"
+// @Failure 401 {object} utils.Response{error=string} "User not authenticated"
+// @Failure 500 {object} utils.Response{error=string} "Server error"
+// @Router /users/me/quota [get]
+func (h *QuotaHandler) GetMyQuota(w http.ResponseWriter, r *http.Request) {
+	userID, ok := auth.GetUserID(r)
+	if !ok {
+		utils.Unauthorized(w, constants.MsgAuthRequired)
+		return
+	}
+
+	quota, err := h.quotaService.GetQuota(r.Context(), userID)
+	if err != nil {
+		utils.ErrorFromAppError(w, utils.ParseError(err))
+		return
+	}
+
+	utils.JSON(w, constants.StatusOK, quota)
+}
+
+// ConsumeQuota takes units from the detection quota of the current user.
+// The detection service calls this with the user's token before processing pages,
+// so that both backends draw from the same bucket. The X-Quota-Remaining header
+// carries the tokens left after consuming.
+//
+// HTTP Method:
+//   - POST
+//
+// URL Path:
+//   - /api/users/me/quota/consume
+//
+// Requires:
+//   - Authentication: User must be logged in
+//
+// Request Body:
+//   - JSON object conforming to models.QuotaConsumeRequest
+//
+// Responses:
+//   - 200 OK: Units consumed, remaining quota returned
+//   - 400 Bad Request: Invalid request body
+//   - 401 Unauthorized: User not authenticated
+//   - 429 Too Many Requests: Quota exceeded, nothing consumed
+//   - 500 Internal Server Error: Server-side error
+//
+// @Summary Consume detection quota
+// @Description Atomically takes units from the detection quota of the currently authenticated user
+// @Tags Users
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param consume body models.QuotaConsumeRequest true "Units to consume"
+// @Success 200 {object} utils.Response{data=models.QuotaState} "Units consumed"
+// @Failure 400 {object} utils.Response{error=string} "Invalid request body"
+// @Failure 401 {object} utils.Response{error=string} "User not authenticated"
+// @Failure 429 {object} utils.Response{error=string} "Quota exceeded"
+// @Failure 500 {object} utils.Response{error=string} "Server error"
+// @Router /users/me/quota/consume [post]
+func (h *QuotaHandler) ConsumeQuota(w http.ResponseWriter, r *http.Request) {
+	userID, ok := auth.GetUserID(r)
+	if !ok {
+		utils.Unauthorized(w, constants.MsgAuthRequired)
+		return
+	}
+
+	var req models.QuotaConsumeRequest
+	if err := utils.DecodeAndValidate(r, &req); err != nil {
+		utils.ErrorFromAppError(w, utils.ParseError(err))
+		return
+	}
+
+	quota, err := h.quotaService.Consume(r.Context(), userID, req.Units)
+	if err != nil {
+		utils.ErrorFromAppError(w, utils.ParseError(err))
+		return
+	}
+
+	w.Header().Set(constants.HeaderXQuotaRemaining, strconv.FormatInt(quota.Remaining, 10))
+	utils.JSON(w, constants.StatusOK, quota)
+}
+
+// GetUserQuota returns the detection quota of any user.
+//
+// HTTP Method:
+//   - GET
+//
+// URL Path:
+//   - /api/admin/quotas/{id}
+//
+// Requires:
+//   - Authentication: Admin role
+//
+// Responses:
+//   - 200 OK: Current quota
+//   - 400 Bad Request: Invalid user ID
+//   - 401 Unauthorized: User not authenticated
+//   - 403 Forbidden: User not authorized (not admin)
+//   - 500 Internal Server Error: Server-side error
+//
+// @Summary Get user detection quota
+// @Description Returns the detection quota of a user
+// @Tags Admin/Quotas
+// @Produce json
+// @Security BearerAuth
+// @Param id path int true "User ID"
+// @Success 200 {object} utils.Response{data=models.QuotaState} "Current quota"
+// @Failure 400 {object} utils.Response{error=string} "Invalid user ID"
+// @Failure 401 {object} utils.Response{error=string} "User not authenticated"
+// @Failure 403 {object} utils.Response{error=string} "User not authorized"
+// @Failure 500 {object} utils.Response{error=string} "Server error"
+// @Router /admin/quotas/{id} [get]
+func (h *QuotaHandler) GetUserQuota(w http.ResponseWriter, r *http.Request) {
+	userID, err := strconv.ParseInt(chi.URLParam(r, constants.ParamID), 10, 64)
+	if err != nil {
+		utils.BadRequest(w, "Invalid user ID", nil)
+		return
+	}
+
+	quota, err := h.quotaService.GetQuota(r.Context(), userID)
+	if err != nil {
+		utils.ErrorFromAppError(w, utils.ParseError(err))
+		return
+	}
+
+	utils.JSON(w, constants.StatusOK, quota)
+}
+
+// AdjustUserQuota adds tokens to or removes tokens from the detection quota of a user.
+//
+// HTTP Method:
+//   - POST
+//
+// URL Path:
+//   - /api/admin/quotas/{id}/adjust
+//
+// Requires:
+//   - Authentication: Admin role
+//
+// Request Body:
+//   - JSON object conforming to models.QuotaAdjustRequest
+//
+// Responses:
+//   - 200 OK: Quota adjusted
+//   - 400 Bad Request: Invalid user ID or request body
+//   - 401 Unauthorized: User not authenticated
+//   - 403 Forbidden: User not authorized (not admin)
+//   - 500 Internal Server Error: Server-side error
+//
+// @Summary Adjust user detection quota
+// @Description Adds tokens to (positive delta) or removes tokens from (negative delta) a user's detection quota
+// @Tags Admin/Quotas
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path int true "User ID"
+// @Param adjustment body models.QuotaAdjustRequest true "Quota adjustment"
+// @Success 200 {object} utils.Response{data=models.QuotaState} "Quota adjusted"
+// @Failure 400 {object} utils.Response{error=string} "Invalid user ID or request body"
+// @Failure 401 {object} utils.Response{error=string} "User not authenticated"
+// @Failure 403 {object} utils.Response{error=string} "User not authorized"
+// @Failure 500 {object} utils.Response{error=string} "Server error"
+// @Router /admin/quotas/{id}/adjust [post]
+func (h *QuotaHandler) AdjustUserQuota(w http.ResponseWriter, r *http.Request) {
+	userID, err := strconv.ParseInt(chi.URLParam(r, constants.ParamID), 10, 64)
+	if err != nil {
+		utils.BadRequest(w, "Invalid user ID", nil)
+		return
+	}
+
+	var req models.QuotaAdjustRequest
+	if err := utils.DecodeAndValidate(r, &req); err != nil {
+		utils.ErrorFromAppError(w, utils.ParseError(err))
+		return
+	}
+
+	quota, err := h.quotaService.AdjustQuota(r.Context(), userID, req.Delta)
+	if err != nil {
+		utils.ErrorFromAppError(w, utils.ParseError(err))
+		return
+	}
+
+	utils.JSON(w, constants.StatusOK, quota)
+}
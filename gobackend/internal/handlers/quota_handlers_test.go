@@ -0,0 +1,176 @@
+package handlers_test
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+
+	"github.com/yasinhessnawi1/Hideme_Backend/internal/handlers"
+	"github.com/yasinhessnawi1/Hideme_Backend/internal/models"
+	"github.com/yasinhessnawi1/Hideme_Backend/internal/testsupport/golden"
+	"github.com/yasinhessnawi1/Hideme_Backend/internal/utils"
+)
+
+// MockQuotaService is a mock implementation of the QuotaService
+type MockQuotaService struct {
+	mock.Mock
+}
+
+func (m *MockQuotaService) Consume(ctx context.Context, userID int64, units int64) (*models.QuotaState, error) {
+	args := m.Called(ctx, userID, units)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*models.QuotaState), args.Error(1)
+}
+
+func (m *MockQuotaService) GetQuota(ctx context.Context, userID int64) (*models.QuotaState, error) {
+	args := m.Called(ctx, userID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*models.QuotaState), args.Error(1)
+}
+
+func (m *MockQuotaService) AdjustQuota(ctx context.Context, userID int64, delta int64) (*models.QuotaState, error) {
+	args := m.Called(ctx, userID, delta)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*models.QuotaState), args.Error(1)
+}
+
+func setupQuotaTest() (*chi.Mux, *MockQuotaService) {
+	mockService := new(MockQuotaService)
+	handler := handlers.NewQuotaHandler(mockService)
+
+	router := chi.NewRouter()
+	router.Get("/api/users/me/quota", handler.GetMyQuota)
+	router.Post("/api/users/me/quota/consume", handler.ConsumeQuota)
+	router.Get("/api/admin/quotas/{id}", handler.GetUserQuota)
+	router.Post("/api/admin/quotas/{id}/adjust", handler.AdjustUserQuota)
+
+	return router, mockService
+}
+
+func TestGetMyQuota(t *testing.T) {
+	router, mockService := setupQuotaTest()
+
+	t.Run("Success", func(t *testing.T) {
+		quota := &models.QuotaState{UserID: 1, Remaining: 42, Capacity: 500, RefillSeconds: 86400}
+		mockService.On("GetQuota", mock.Anything, int64(1)).Return(quota, nil).Once()
+
+		req, err := http.NewRequest("GET", "/api/users/me/quota", nil)
+		require.NoError(t, err)
+		req = req.WithContext(createAuthContext(1))
+
+		rr := httptest.NewRecorder()
+		router.ServeHTTP(rr, req)
+
+		golden.AssertResponse(t, rr)
+	})
+
+	t.Run("Unauthenticated", func(t *testing.T) {
+		req, err := http.NewRequest("GET", "/api/users/me/quota", nil)
+		require.NoError(t, err)
+
+		rr := httptest.NewRecorder()
+		router.ServeHTTP(rr, req)
+
+		assert.Equal(t, http.StatusUnauthorized, rr.Code)
+	})
+
+	mockService.AssertExpectations(t)
+}
+
+func TestConsumeQuota(t *testing.T) {
+	router, mockService := setupQuotaTest()
+
+	t.Run("Success", func(t *testing.T) {
+		quota := &models.QuotaState{UserID: 1, Remaining: 37, Capacity: 500}
+		mockService.On("Consume", mock.Anything, int64(1), int64(5)).Return(quota, nil).Once()
+
+		body, _ := json.Marshal(map[string]int64{"units": 5})
+		req, err := http.NewRequest("POST", "/api/users/me/quota/consume", bytes.NewBuffer(body))
+		require.NoError(t, err)
+		req.Header.Set("Content-Type", "application/json")
+		req = req.WithContext(createAuthContext(1))
+
+		rr := httptest.NewRecorder()
+		router.ServeHTTP(rr, req)
+
+		assert.Equal(t, http.StatusOK, rr.Code)
+		assert.Equal(t, "37", rr.Header().Get("X-Quota-Remaining"))
+	})
+
+	t.Run("Quota exceeded", func(t *testing.T) {
+		mockService.On("Consume", mock.Anything, int64(1), int64(50)).Return(nil, utils.NewQuotaExceededError(3)).Once()
+
+		body, _ := json.Marshal(map[string]int64{"units": 50})
+		req, err := http.NewRequest("POST", "/api/users/me/quota/consume", bytes.NewBuffer(body))
+		require.NoError(t, err)
+		req.Header.Set("Content-Type", "application/json")
+		req = req.WithContext(createAuthContext(1))
+
+		rr := httptest.NewRecorder()
+		router.ServeHTTP(rr, req)
+
+		golden.AssertResponse(t, rr)
+	})
+
+	t.Run("Invalid units", func(t *testing.T) {
+		req, err := http.NewRequest("POST", "/api/users/me/quota/consume", bytes.NewBufferString(`{"units":0}`))
+		require.NoError(t, err)
+		req.Header.Set("Content-Type", "application/json")
+		req = req.WithContext(createAuthContext(1))
+
+		rr := httptest.NewRecorder()
+		router.ServeHTTP(rr, req)
+
+		assert.Equal(t, http.StatusBadRequest, rr.Code)
+	})
+
+	mockService.AssertExpectations(t)
+}
+
+func TestAdjustUserQuota(t *testing.T) {
+	router, mockService := setupQuotaTest()
+
+	t.Run("Success", func(t *testing.T) {
+		quota := &models.QuotaState{UserID: 7, Remaining: 600, Capacity: 500}
+		mockService.On("AdjustQuota", mock.Anything, int64(7), int64(100)).Return(quota, nil).Once()
+
+		body, _ := json.Marshal(map[string]int64{"delta": 100})
+		req, err := http.NewRequest("POST", "/api/admin/quotas/7/adjust", bytes.NewBuffer(body))
+		require.NoError(t, err)
+		req.Header.Set("Content-Type", "application/json")
+		req = req.WithContext(createAuthContext(1))
+
+		rr := httptest.NewRecorder()
+		router.ServeHTTP(rr, req)
+
+		assert.Equal(t, http.StatusOK, rr.Code)
+	})
+
+	t.Run("Invalid ID", func(t *testing.T) {
+		req, err := http.NewRequest("POST", "/api/admin/quotas/abc/adjust", bytes.NewBufferString(`{"delta":1}`))
+		require.NoError(t, err)
+		req.Header.Set("Content-Type", "application/json")
+		req = req.WithContext(createAuthContext(1))
+
+		rr := httptest.NewRecorder()
+		router.ServeHTTP(rr, req)
+
+		assert.Equal(t, http.StatusBadRequest, rr.Code)
+	})
+
+	mockService.AssertExpectations(t)
+}
@@ -0,0 +1,156 @@
+// Package handlers provides HTTP request handlers for the HideMe API.
+package handlers
+
+import (
+	"context"
+	"net"
+	"net/http"
+	"strconv"
+
+	"github.com/yasinhessnawi1/Hideme_Backend/internal/auth"
+	"github.com/yasinhessnawi1/Hideme_Backend/internal/constants"
+	"github.com/yasinhessnawi1/Hideme_Backend/internal/models"
+	"github.com/yasinhessnawi1/Hideme_Backend/internal/utils"
+)
+
+// RateLimitServiceInterface defines the service methods required for the rate limit dashboard.
+type RateLimitServiceInterface interface {
+	RateLimitOverview(filter models.RateLimitFilter) *models.RateLimitOverview
+	GrantRateLimitBurst(ctx context.Context, adminID int64, req *models.RateLimitGrantRequest) (*models.RateLimitGrant, error)
+}
+
+// RateLimitHandler handles HTTP requests for the rate limit dashboard and burst grants.
+type RateLimitHandler struct {
+	rateLimitService RateLimitServiceInterface
+}
+
+// NewRateLimitHandler creates a new RateLimitHandler with the provided service.
+//
+// Parameters:
+//   - rateLimitService: Service holding the rate limits
+//
+// Returns:
+//   - A properly initialized RateLimitHandler
+func NewRateLimitHandler(rateLimitService RateLimitServiceInterface) *RateLimitHandler {
+	return &RateLimitHandler{
+		rateLimitService: rateLimitService,
+	}
+}
+
+// GetRateLimits returns the configured limits of the route groups, how much of their burst
+// the clients seen recently have used, and the burst grants in effect. The buckets can be
+// narrowed to a route group, a user or an IP address.
+//
+// HTTP Method:
+//   - GET
+//
+// URL Path:
+//   - /api/admin/rate-limits
+//
+// Query Parameters:
+//   - group: Route group of the buckets
+//   - user_id: User of the buckets
+//   - ip: IP address of the buckets
+//
+// Requires:
+//   - Authentication: Admin role
+//
+// Responses:
+//   - 200 OK: Rate limit overview
+//   - 400 Bad Request: Invalid user ID or IP address
+//   - 401 Unauthorized: User not authenticated
+//   - 403 Forbidden: User not authorized (not admin)
+//
+// @Summary Rate limit dashboard
+// @Description Returns the configured rate limits, the consumption of each client and the burst grants in effect
+// @Tags Admin/Security
+// @Produce json
+// @Security BearerAuth
+// @Security ApiKeyAuth
+// @Param group query string false "Route group"
+// @Param user_id query int false "User ID"
+// @Param ip query string false "IP address"
+// @Success 200 {object} utils.Response{data=models.RateLimitOverview} "Rate limit overview"
+// @Failure 400 {object} utils.Response{error=string} "Invalid user ID or IP address"
+// @Failure 401 {object} utils.Response{error=string} "User not authenticated"
+// @Failure 403 {object} utils.Response{error=string} "User not authorized"
+// @Router /admin/rate-limits [get]
+func (h *RateLimitHandler) GetRateLimits(w http.ResponseWriter, r *http.Request) {
+	query := r.URL.Query()
+	filter := models.RateLimitFilter{
+		Group:     query.Get(constants.QueryParamGroup),
+		IPAddress: query.Get(constants.QueryParamIPAddress),
+	}
+	if userIDStr := query.Get(constants.QueryParamUserID); userIDStr != "" {
+		userID, err := strconv.ParseInt(userIDStr, 10, 64)
+		if err != nil || userID <= 0 {
+			utils.BadRequest(w, "Invalid user ID", nil)
+			return
+		}
+		filter.UserID = userID
+	}
+	if filter.IPAddress != "" && net.ParseIP(filter.IPAddress) == nil {
+		utils.BadRequest(w, "Invalid IP address", nil)
+		return
+	}
+
+	utils.JSON(w, constants.StatusOK, h.rateLimitService.RateLimitOverview(filter))
+}
+
+// GrantBurst temporarily lets a user or IP address send more requests at once in a route
+// group, for example during a customer's month-end processing. A new grant to the same
+// client in the same group replaces the previous one. Grants are logged for auditing and
+// kept in memory, so they end when the server restarts.
+//
+// HTTP Method:
+//   - POST
+//
+// URL Path:
+//   - /api/admin/rate-limits/grants
+//
+// Requires:
+//   - Authentication: Admin role
+//
+// Request Body:
+//   - JSON object conforming to models.RateLimitGrantRequest
+//
+// Responses:
+//   - 201 Created: Burst granted
+//   - 400 Bad Request: Invalid request body or unknown route group
+//   - 401 Unauthorized: User not authenticated
+//   - 403 Forbidden: User not authorized (not admin)
+//
+// @Summary Grant rate limit burst
+// @Description Temporarily raises the burst of a user or IP address in a route group
+// @Tags Admin/Security
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Security ApiKeyAuth
+// @Param grant body models.RateLimitGrantRequest true "Client, route group, burst and duration"
+// @Success 201 {object} utils.Response{data=models.RateLimitGrant} "Burst granted"
+// @Failure 400 {object} utils.Response{error=string} "Invalid request body"
+// @Failure 401 {object} utils.Response{error=string} "User not authenticated"
+// @Failure 403 {object} utils.Response{error=string} "User not authorized"
+// @Router /admin/rate-limits/grants [post]
+func (h *RateLimitHandler) GrantBurst(w http.ResponseWriter, r *http.Request) {
+	adminID, ok := auth.GetUserID(r)
+	if !ok {
+		utils.Unauthorized(w, constants.MsgAuthRequired)
+		return
+	}
+
+	var req models.RateLimitGrantRequest
+	if err := utils.DecodeAndValidate(r, &req); err != nil {
+		utils.ErrorFromAppError(w, utils.ParseError(err))
+		return
+	}
+
+	grant, err := h.rateLimitService.GrantRateLimitBurst(r.Context(), adminID, &req)
+	if err != nil {
+		utils.ErrorFromAppError(w, utils.ParseError(err))
+		return
+	}
+
+	utils.JSON(w, constants.StatusCreated, grant)
+}
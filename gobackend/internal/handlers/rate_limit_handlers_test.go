@@ -0,0 +1,159 @@
+package handlers_test
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+
+	"github.com/yasinhessnawi1/Hideme_Backend/internal/auth"
+	"github.com/yasinhessnawi1/Hideme_Backend/internal/constants"
+	"github.com/yasinhessnawi1/Hideme_Backend/internal/handlers"
+	"github.com/yasinhessnawi1/Hideme_Backend/internal/models"
+	"github.com/yasinhessnawi1/Hideme_Backend/internal/testsupport/fixtures"
+	"github.com/yasinhessnawi1/Hideme_Backend/internal/testsupport/golden"
+	"github.com/yasinhessnawi1/Hideme_Backend/internal/utils"
+)
+
+// MockRateLimitService is a mock implementation of the rate limit methods of the SecurityService
+type MockRateLimitService struct {
+	mock.Mock
+}
+
+func (m *MockRateLimitService) RateLimitOverview(filter models.RateLimitFilter) *models.RateLimitOverview {
+	args := m.Called(filter)
+	return args.Get(0).(*models.RateLimitOverview)
+}
+
+func (m *MockRateLimitService) GrantRateLimitBurst(ctx context.Context, adminID int64, req *models.RateLimitGrantRequest) (*models.RateLimitGrant, error) {
+	args := m.Called(ctx, adminID, req)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*models.RateLimitGrant), args.Error(1)
+}
+
+func setupRateLimitTest() (*chi.Mux, *MockRateLimitService) {
+	mockService := new(MockRateLimitService)
+	handler := handlers.NewRateLimitHandler(mockService)
+
+	router := chi.NewRouter()
+	router.Get("/api/admin/rate-limits", handler.GetRateLimits)
+	router.Post("/api/admin/rate-limits/grants", handler.GrantBurst)
+
+	return router, mockService
+}
+
+func TestGetRateLimits(t *testing.T) {
+	t.Run("Success", func(t *testing.T) {
+		router, mockService := setupRateLimitTest()
+		mockService.On("RateLimitOverview", models.RateLimitFilter{Group: "documents", UserID: 42}).
+			Return(&models.RateLimitOverview{
+				Groups:  []models.RateLimitGroup{{Group: "documents", Rate: 10, Burst: 20}},
+				Buckets: []models.RateLimitBucket{{Group: "documents", ClientType: models.RateLimitClientUser, ClientID: "42", UsedPercent: 80}},
+				Grants:  []models.RateLimitGrant{},
+			}).Once()
+
+		req, err := http.NewRequest("GET", "/api/admin/rate-limits?group=documents&user_id=42", nil)
+		require.NoError(t, err)
+		rr := httptest.NewRecorder()
+		router.ServeHTTP(rr, req)
+
+		golden.AssertResponse(t, rr)
+		mockService.AssertExpectations(t)
+	})
+
+	for _, query := range []string{"user_id=abc", "user_id=0", "ip=not-an-ip"} {
+		t.Run("Invalid "+query, func(t *testing.T) {
+			router, mockService := setupRateLimitTest()
+
+			req, err := http.NewRequest("GET", "/api/admin/rate-limits?"+query, nil)
+			require.NoError(t, err)
+			rr := httptest.NewRecorder()
+			router.ServeHTTP(rr, req)
+
+			assert.Equal(t, http.StatusBadRequest, rr.Code)
+			mockService.AssertNotCalled(t, "RateLimitOverview", mock.Anything)
+		})
+	}
+}
+
+func TestGrantRateLimitBurst(t *testing.T) {
+	newRequest := func(t *testing.T, body string) *http.Request {
+		req, err := http.NewRequest("POST", "/api/admin/rate-limits/grants", strings.NewReader(body))
+		require.NoError(t, err)
+		req.Header.Set("Content-Type", "application/json")
+		return req.WithContext(context.WithValue(req.Context(), auth.UserIDContextKey, int64(1)))
+	}
+
+	t.Run("Success", func(t *testing.T) {
+		router, mockService := setupRateLimitTest()
+		mockService.On("GrantRateLimitBurst", mock.Anything, int64(1), mock.MatchedBy(func(req *models.RateLimitGrantRequest) bool {
+			return req.Group == "documents" && req.UserID == 42 && req.Burst == 500 && req.DurationSeconds == 86400
+		})).Return(&models.RateLimitGrant{
+			Group:      "documents",
+			ClientType: models.RateLimitClientUser,
+			ClientID:   "42",
+			Burst:      500,
+			Reason:     "Month-end processing",
+			GrantedBy:  1,
+			GrantedAt:  fixtures.Time,
+			ExpiresAt:  fixtures.Time.Add(24 * time.Hour),
+		}, nil).Once()
+
+		rr := httptest.NewRecorder()
+		router.ServeHTTP(rr, newRequest(t, `{"group":"documents","user_id":42,"burst":500,"duration_seconds":86400,"reason":"Month-end processing"}`))
+
+		golden.AssertResponse(t, rr)
+		mockService.AssertExpectations(t)
+	})
+
+	invalid := map[string]string{
+		"No client":          `{"group":"documents","burst":5,"duration_seconds":60,"reason":"x"}`,
+		"Both clients":       `{"group":"documents","user_id":42,"ip_address":"10.0.0.1","burst":5,"duration_seconds":60,"reason":"x"}`,
+		"Invalid IP":         `{"group":"documents","ip_address":"10.0.0","burst":5,"duration_seconds":60,"reason":"x"}`,
+		"Duration too short": `{"group":"documents","user_id":42,"burst":5,"duration_seconds":10,"reason":"x"}`,
+		"No reason":          `{"group":"documents","user_id":42,"burst":5,"duration_seconds":60}`,
+	}
+	for name, body := range invalid {
+		t.Run(name, func(t *testing.T) {
+			router, mockService := setupRateLimitTest()
+
+			rr := httptest.NewRecorder()
+			router.ServeHTTP(rr, newRequest(t, body))
+
+			assert.Equal(t, http.StatusBadRequest, rr.Code)
+			mockService.AssertNotCalled(t, "GrantRateLimitBurst", mock.Anything, mock.Anything, mock.Anything)
+		})
+	}
+
+	t.Run("Unknown Group", func(t *testing.T) {
+		router, mockService := setupRateLimitTest()
+		mockService.On("GrantRateLimitBurst", mock.Anything, int64(1), mock.Anything).
+			Return(nil, utils.NewValidationError("group", constants.MsgRateLimitGroupUnknown)).Once()
+
+		rr := httptest.NewRecorder()
+		router.ServeHTTP(rr, newRequest(t, `{"group":"uploads","ip_address":"10.0.0.1","burst":5,"duration_seconds":60,"reason":"x"}`))
+
+		assert.Equal(t, http.StatusBadRequest, rr.Code)
+		mockService.AssertExpectations(t)
+	})
+
+	t.Run("Unauthenticated", func(t *testing.T) {
+		router, _ := setupRateLimitTest()
+		req, err := http.NewRequest("POST", "/api/admin/rate-limits/grants", strings.NewReader(`{}`))
+		require.NoError(t, err)
+
+		rr := httptest.NewRecorder()
+		router.ServeHTTP(rr, req)
+
+		assert.Equal(t, http.StatusUnauthorized, rr.Code)
+	})
+}
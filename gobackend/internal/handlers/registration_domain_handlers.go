@@ -0,0 +1,272 @@
+// Package handlers provides HTTP request handlers for the HideMe API.
+package handlers
+
+import (
+	"context"
+	"net/http"
+	"strconv"
+
+	"github.com/go-chi/chi/v5"
+
+	"github.com/yasinhessnawi1/Hideme_Backend/internal/auth"
+	"github.com/yasinhessnawi1/Hideme_Backend/internal/constants"
+	"github.com/yasinhessnawi1/Hideme_Backend/internal/models"
+	"github.com/yasinhessnawi1/Hideme_Backend/internal/utils"
+)
+
+// RegistrationDomainServiceInterface defines the service methods required for the registration domain allowlist.
+type RegistrationDomainServiceInterface interface {
+	// ListDomains returns all registration domains with their verification records.
+	//
+	// Parameters:
+	//   - ctx: Context for the operation
+	//
+	// Returns:
+	//   - The domains by name
+	//   - An error if retrieval fails
+	ListDomains(ctx context.Context) ([]*models.RegistrationDomainView, error)
+
+	// AddDomain adds an unverified registration domain with a new verification token.
+	//
+	// Parameters:
+	//   - ctx: Context for the operation
+	//   - adminID: The ID of the administrator adding the domain
+	//   - req: The domain name
+	//
+	// Returns:
+	//   - The domain with the TXT record to publish
+	//   - ValidationError if the name is not a domain name, DuplicateError if it was already added
+	AddDomain(ctx context.Context, adminID int64, req *models.RegistrationDomainCreate) (*models.RegistrationDomainView, error)
+
+	// VerifyDomain looks up the verification TXT record of a domain and marks the domain verified.
+	//
+	// Parameters:
+	//   - ctx: Context for the operation
+	//   - id: The ID of the domain
+	//
+	// Returns:
+	//   - The domain
+	//   - NotFoundError if the domain doesn't exist
+	//   - ValidationError if the record was not found or holds another value
+	VerifyDomain(ctx context.Context, id int64) (*models.RegistrationDomainView, error)
+
+	// DeleteDomain removes a registration domain.
+	//
+	// Parameters:
+	//   - ctx: Context for the operation
+	//   - id: The ID of the domain
+	//
+	// Returns:
+	//   - NotFoundError if the domain doesn't exist
+	DeleteDomain(ctx context.Context, id int64) error
+}
+
+// RegistrationDomainHandler handles HTTP requests for the email domains registration is limited to.
+type RegistrationDomainHandler struct {
+	domainService RegistrationDomainServiceInterface
+}
+
+// NewRegistrationDomainHandler creates a new RegistrationDomainHandler with the provided domain service.
+//
+// Parameters:
+//   - domainService: Service managing the registration domains
+//
+// Returns:
+//   - A properly initialized RegistrationDomainHandler
+func NewRegistrationDomainHandler(domainService RegistrationDomainServiceInterface) *RegistrationDomainHandler {
+	return &RegistrationDomainHandler{
+		domainService: domainService,
+	}
+}
+
+// ListRegistrationDomains returns the registration domains with the TXT records that verify them.
+//
+// HTTP Method:
+//   - GET
+//
+// URL Path:
+//   - /api/admin/registration-domains
+//
+// Requires:
+//   - Authentication: Admin role
+//
+// Responses:
+//   - 200 OK: List of domains
+//   - 401 Unauthorized: User not authenticated
+//   - 403 Forbidden: User not authorized (not admin)
+//   - 500 Internal Server Error: Server-side error
+//
+// @Summary List registration domains
+// @Description Returns the email domains registration is limited to, with their verification records
+// @Tags Admin/Registration Domains
+// @Produce json
+// @Security BearerAuth
+// @Success 200 {object} utils.Response{data=[]models.RegistrationDomainView} "List of domains"
+// @Failure 401 {object} utils.Response{error=string} "User not authenticated"
+// @Failure 403 {object} utils.Response{error=string} "User not authorized"
+// @Failure 500 {object} utils.Response{error=string} "Server error"
+// @Router /admin/registration-domains [get]
+func (h *RegistrationDomainHandler) ListRegistrationDomains(w http.ResponseWriter, r *http.Request) {
+	domains, err := h.domainService.ListDomains(r.Context())
+	if err != nil {
+		utils.ErrorFromAppError(w, utils.ParseError(err))
+		return
+	}
+
+	utils.ListAll(w, r, domains)
+}
+
+// AddRegistrationDomain adds an email domain to the registration allowlist. The domain
+// only limits registration once its verification record is published and verified.
+//
+// HTTP Method:
+//   - POST
+//
+// URL Path:
+//   - /api/admin/registration-domains
+//
+// Requires:
+//   - Authentication: Admin role
+//
+// Request Body:
+//   - JSON object conforming to models.RegistrationDomainCreate
+//
+// Responses:
+//   - 201 Created: Domain added, with the TXT record to publish
+//   - 400 Bad Request: Invalid request body or domain name
+//   - 401 Unauthorized: User not authenticated
+//   - 403 Forbidden: User not authorized (not admin)
+//   - 409 Conflict: Domain already added
+//   - 500 Internal Server Error: Server-side error
+//
+// @Summary Add registration domain
+// @Description Adds an unverified email domain and returns the TXT record that verifies it
+// @Tags Admin/Registration Domains
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param domain body models.RegistrationDomainCreate true "Domain name"
+// @Success 201 {object} utils.Response{data=models.RegistrationDomainView} "Domain added"
+// @Failure 400 {object} utils.Response{error=string} "Invalid request body"
+// @Failure 401 {object} utils.Response{error=string} "User not authenticated"
+// @Failure 403 {object} utils.Response{error=string} "User not authorized"
+// @Failure 409 {object} utils.Response{error=string} "Domain already added"
+// @Failure 500 {object} utils.Response{error=string} "Server error"
+// @Router /admin/registration-domains [post]
+func (h *RegistrationDomainHandler) AddRegistrationDomain(w http.ResponseWriter, r *http.Request) {
+	adminID, ok := auth.GetUserID(r)
+	if !ok {
+		utils.Unauthorized(w, constants.MsgAuthRequired)
+		return
+	}
+
+	var req models.RegistrationDomainCreate
+	if err := utils.DecodeAndValidate(r, &req); err != nil {
+		utils.ErrorFromAppError(w, utils.ParseError(err))
+		return
+	}
+
+	domain, err := h.domainService.AddDomain(r.Context(), adminID, &req)
+	if err != nil {
+		utils.ErrorFromAppError(w, utils.ParseError(err))
+		return
+	}
+
+	utils.JSON(w, constants.StatusCreated, domain)
+}
+
+// VerifyRegistrationDomain checks the verification TXT record of a domain. Once verified,
+// new accounts need an email address at one of the verified domains.
+//
+// HTTP Method:
+//   - POST
+//
+// URL Path:
+//   - /api/admin/registration-domains/{id}/verify
+//
+// Requires:
+//   - Authentication: Admin role
+//
+// Responses:
+//   - 200 OK: Domain verified
+//   - 400 Bad Request: Invalid domain ID, or the record was not found
+//   - 401 Unauthorized: User not authenticated
+//   - 403 Forbidden: User not authorized (not admin)
+//   - 404 Not Found: Domain not found
+//   - 500 Internal Server Error: Server-side error
+//
+// @Summary Verify registration domain
+// @Description Looks up the TXT record of a domain and starts limiting registration to it
+// @Tags Admin/Registration Domains
+// @Produce json
+// @Security BearerAuth
+// @Param id path int true "Domain ID"
+// @Success 200 {object} utils.Response{data=models.RegistrationDomainView} "Domain verified"
+// @Failure 400 {object} utils.Response{error=string} "Verification record not found"
+// @Failure 401 {object} utils.Response{error=string} "User not authenticated"
+// @Failure 403 {object} utils.Response{error=string} "User not authorized"
+// @Failure 404 {object} utils.Response{error=string} "Domain not found"
+// @Failure 500 {object} utils.Response{error=string} "Server error"
+// @Router /admin/registration-domains/{id}/verify [post]
+func (h *RegistrationDomainHandler) VerifyRegistrationDomain(w http.ResponseWriter, r *http.Request) {
+	domainID, err := strconv.ParseInt(chi.URLParam(r, constants.ParamID), 10, 64)
+	if err != nil {
+		utils.BadRequest(w, "Invalid domain ID", nil)
+		return
+	}
+
+	domain, err := h.domainService.VerifyDomain(r.Context(), domainID)
+	if err != nil {
+		utils.ErrorFromAppError(w, utils.ParseError(err))
+		return
+	}
+
+	utils.JSON(w, constants.StatusOK, domain)
+}
+
+// DeleteRegistrationDomain removes a domain from the registration allowlist. Existing
+// accounts are kept; registration is open again once no verified domain is left.
+//
+// HTTP Method:
+//   - DELETE
+//
+// URL Path:
+//   - /api/admin/registration-domains/{id}
+//
+// Requires:
+//   - Authentication: Admin role
+//
+// Responses:
+//   - 204 No Content: Domain removed
+//   - 400 Bad Request: Invalid domain ID
+//   - 401 Unauthorized: User not authenticated
+//   - 403 Forbidden: User not authorized (not admin)
+//   - 404 Not Found: Domain not found
+//   - 500 Internal Server Error: Server-side error
+//
+// @Summary Delete registration domain
+// @Description Removes a domain from the registration allowlist
+// @Tags Admin/Registration Domains
+// @Security BearerAuth
+// @Param id path int true "Domain ID"
+// @Success 204 "Domain removed"
+// @Failure 400 {object} utils.Response{error=string} "Invalid domain ID"
+// @Failure 401 {object} utils.Response{error=string} "User not authenticated"
+// @Failure 403 {object} utils.Response{error=string} "User not authorized"
+// @Failure 404 {object} utils.Response{error=string} "Domain not found"
+// @Failure 500 {object} utils.Response{error=string} "Server error"
+// @Router /admin/registration-domains/{id} [delete]
+func (h *RegistrationDomainHandler) DeleteRegistrationDomain(w http.ResponseWriter, r *http.Request) {
+	domainID, err := strconv.ParseInt(chi.URLParam(r, constants.ParamID), 10, 64)
+	if err != nil {
+		utils.BadRequest(w, "Invalid domain ID", nil)
+		return
+	}
+
+	if err := h.domainService.DeleteDomain(r.Context(), domainID); err != nil {
+		utils.ErrorFromAppError(w, utils.ParseError(err))
+		return
+	}
+
+	utils.NoContent(w)
+}
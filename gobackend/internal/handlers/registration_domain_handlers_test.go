@@ -0,0 +1,209 @@
+package handlers_test
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+
+	"github.com/yasinhessnawi1/Hideme_Backend/internal/constants"
+	"github.com/yasinhessnawi1/Hideme_Backend/internal/handlers"
+	"github.com/yasinhessnawi1/Hideme_Backend/internal/models"
+	"github.com/yasinhessnawi1/Hideme_Backend/internal/testsupport/golden"
+	"github.com/yasinhessnawi1/Hideme_Backend/internal/utils"
+)
+
+// MockRegistrationDomainService is a mock implementation of the RegistrationDomainService
+type MockRegistrationDomainService struct {
+	mock.Mock
+}
+
+func (m *MockRegistrationDomainService) ListDomains(ctx context.Context) ([]*models.RegistrationDomainView, error) {
+	args := m.Called(ctx)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]*models.RegistrationDomainView), args.Error(1)
+}
+
+func (m *MockRegistrationDomainService) AddDomain(ctx context.Context, adminID int64, req *models.RegistrationDomainCreate) (*models.RegistrationDomainView, error) {
+	args := m.Called(ctx, adminID, req)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*models.RegistrationDomainView), args.Error(1)
+}
+
+func (m *MockRegistrationDomainService) VerifyDomain(ctx context.Context, id int64) (*models.RegistrationDomainView, error) {
+	args := m.Called(ctx, id)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*models.RegistrationDomainView), args.Error(1)
+}
+
+func (m *MockRegistrationDomainService) DeleteDomain(ctx context.Context, id int64) error {
+	args := m.Called(ctx, id)
+	return args.Error(0)
+}
+
+func setupRegistrationDomainTest() (*chi.Mux, *MockRegistrationDomainService) {
+	mockService := new(MockRegistrationDomainService)
+	handler := handlers.NewRegistrationDomainHandler(mockService)
+
+	router := chi.NewRouter()
+	router.Get("/api/admin/registration-domains", handler.ListRegistrationDomains)
+	router.Post("/api/admin/registration-domains", handler.AddRegistrationDomain)
+	router.Post("/api/admin/registration-domains/{id}/verify", handler.VerifyRegistrationDomain)
+	router.Delete("/api/admin/registration-domains/{id}", handler.DeleteRegistrationDomain)
+	return router, mockService
+}
+
+func TestListRegistrationDomains(t *testing.T) {
+	router, mockService := setupRegistrationDomainTest()
+
+	domains := []*models.RegistrationDomainView{
+		models.NewRegistrationDomainView(&models.RegistrationDomain{ID: 3, Domain: "example.com", VerificationToken: "abc"}),
+	}
+	mockService.On("ListDomains", mock.Anything).Return(domains, nil).Once()
+
+	req, err := http.NewRequest("GET", "/api/admin/registration-domains", nil)
+	require.NoError(t, err)
+	req = req.WithContext(createAuthContext(1))
+
+	rr := httptest.NewRecorder()
+	router.ServeHTTP(rr, req)
+
+	golden.AssertResponse(t, rr)
+	mockService.AssertExpectations(t)
+}
+
+func TestAddRegistrationDomain(t *testing.T) {
+	router, mockService := setupRegistrationDomainTest()
+
+	t.Run("Success", func(t *testing.T) {
+		expected := &models.RegistrationDomainCreate{Domain: "example.com"}
+		domain := models.NewRegistrationDomainView(&models.RegistrationDomain{ID: 3, Domain: "example.com", VerificationToken: "abc"})
+		mockService.On("AddDomain", mock.Anything, int64(1), expected).Return(domain, nil).Once()
+
+		body, _ := json.Marshal(map[string]interface{}{"domain": "example.com"})
+		req, err := http.NewRequest("POST", "/api/admin/registration-domains", bytes.NewBuffer(body))
+		require.NoError(t, err)
+		req.Header.Set("Content-Type", "application/json")
+		req = req.WithContext(createAuthContext(1))
+
+		rr := httptest.NewRecorder()
+		router.ServeHTTP(rr, req)
+
+		golden.AssertResponse(t, rr)
+	})
+
+	t.Run("Missing Domain", func(t *testing.T) {
+		req, err := http.NewRequest("POST", "/api/admin/registration-domains", bytes.NewBufferString(`{}`))
+		require.NoError(t, err)
+		req.Header.Set("Content-Type", "application/json")
+		req = req.WithContext(createAuthContext(1))
+
+		rr := httptest.NewRecorder()
+		router.ServeHTTP(rr, req)
+
+		assert.Equal(t, http.StatusBadRequest, rr.Code)
+	})
+
+	t.Run("Already Added", func(t *testing.T) {
+		mockService.On("AddDomain", mock.Anything, int64(1), mock.Anything).
+			Return(nil, utils.NewDuplicateError("RegistrationDomain", "domain", "example.org")).Once()
+
+		body, _ := json.Marshal(map[string]interface{}{"domain": "example.org"})
+		req, err := http.NewRequest("POST", "/api/admin/registration-domains", bytes.NewBuffer(body))
+		require.NoError(t, err)
+		req.Header.Set("Content-Type", "application/json")
+		req = req.WithContext(createAuthContext(1))
+
+		rr := httptest.NewRecorder()
+		router.ServeHTTP(rr, req)
+
+		assert.Equal(t, http.StatusConflict, rr.Code)
+	})
+}
+
+func TestVerifyRegistrationDomain(t *testing.T) {
+	router, mockService := setupRegistrationDomainTest()
+
+	t.Run("Success", func(t *testing.T) {
+		domain := models.NewRegistrationDomainView(&models.RegistrationDomain{ID: 3, Domain: "example.com"})
+		mockService.On("VerifyDomain", mock.Anything, int64(3)).Return(domain, nil).Once()
+
+		req, err := http.NewRequest("POST", "/api/admin/registration-domains/3/verify", nil)
+		require.NoError(t, err)
+		req = req.WithContext(createAuthContext(1))
+
+		rr := httptest.NewRecorder()
+		router.ServeHTTP(rr, req)
+
+		assert.Equal(t, http.StatusOK, rr.Code)
+	})
+
+	t.Run("Record Not Found", func(t *testing.T) {
+		mockService.On("VerifyDomain", mock.Anything, int64(4)).
+			Return(nil, utils.NewValidationError("domain", constants.MsgRegistrationDomainUnverified)).Once()
+
+		req, err := http.NewRequest("POST", "/api/admin/registration-domains/4/verify", nil)
+		require.NoError(t, err)
+		req = req.WithContext(createAuthContext(1))
+
+		rr := httptest.NewRecorder()
+		router.ServeHTTP(rr, req)
+
+		assert.Equal(t, http.StatusBadRequest, rr.Code)
+	})
+
+	t.Run("Invalid ID", func(t *testing.T) {
+		req, err := http.NewRequest("POST", "/api/admin/registration-domains/abc/verify", nil)
+		require.NoError(t, err)
+		req = req.WithContext(createAuthContext(1))
+
+		rr := httptest.NewRecorder()
+		router.ServeHTTP(rr, req)
+
+		assert.Equal(t, http.StatusBadRequest, rr.Code)
+	})
+}
+
+func TestDeleteRegistrationDomain(t *testing.T) {
+	router, mockService := setupRegistrationDomainTest()
+
+	t.Run("Success", func(t *testing.T) {
+		mockService.On("DeleteDomain", mock.Anything, int64(3)).Return(nil).Once()
+
+		req, err := http.NewRequest("DELETE", "/api/admin/registration-domains/3", nil)
+		require.NoError(t, err)
+		req = req.WithContext(createAuthContext(1))
+
+		rr := httptest.NewRecorder()
+		router.ServeHTTP(rr, req)
+
+		assert.Equal(t, http.StatusNoContent, rr.Code)
+	})
+
+	t.Run("Not Found", func(t *testing.T) {
+		mockService.On("DeleteDomain", mock.Anything, int64(9)).
+			Return(utils.NewNotFoundError("RegistrationDomain", int64(9))).Once()
+
+		req, err := http.NewRequest("DELETE", "/api/admin/registration-domains/9", nil)
+		require.NoError(t, err)
+		req = req.WithContext(createAuthContext(1))
+
+		rr := httptest.NewRecorder()
+		router.ServeHTTP(rr, req)
+
+		assert.Equal(t, http.StatusNotFound, rr.Code)
+	})
+}
@@ -0,0 +1,219 @@
+// Package handlers provides HTTP request handlers for the HideMe API.
+package handlers
+
+import (
+	"context"
+	"net/http"
+	"strconv"
+
+	"github.com/go-chi/chi/v5"
+
+	"github.com/yasinhessnawi1/Hideme_Backend/internal/auth"
+	"github.com/yasinhessnawi1/Hideme_Backend/internal/constants"
+	"github.com/yasinhessnawi1/Hideme_Backend/internal/models"
+	"github.com/yasinhessnawi1/Hideme_Backend/internal/utils"
+)
+
+// ReportServiceInterface defines the service methods required for report subscription operations.
+type ReportServiceInterface interface {
+	// Subscribe subscribes a user to a scheduled report.
+	//
+	// Parameters:
+	//   - ctx: Context for the operation
+	//   - userID: The ID of the user
+	//   - req: The report to subscribe to
+	//
+	// Returns:
+	//   - The created subscription
+	//   - DuplicateError if the user is already subscribed to the report
+	Subscribe(ctx context.Context, userID int64, req *models.ReportSubscriptionCreate) (*models.ReportSubscription, error)
+
+	// ListSubscriptions returns the report subscriptions of a user.
+	//
+	// Parameters:
+	//   - ctx: Context for the operation
+	//   - userID: The ID of the user
+	//
+	// Returns:
+	//   - The subscriptions
+	//   - An error if retrieval fails
+	ListSubscriptions(ctx context.Context, userID int64) ([]*models.ReportSubscription, error)
+
+	// Unsubscribe removes one of a user's report subscriptions.
+	//
+	// Parameters:
+	//   - ctx: Context for the operation
+	//   - userID: The ID of the user
+	//   - subscriptionID: The ID of the subscription
+	//
+	// Returns:
+	//   - NotFoundError if the user has no such subscription
+	Unsubscribe(ctx context.Context, userID int64, subscriptionID int64) error
+}
+
+// ReportHandler handles HTTP requests related to scheduled report subscriptions.
+type ReportHandler struct {
+	reportService ReportServiceInterface
+}
+
+// NewReportHandler creates a new ReportHandler with the provided report service.
+//
+// Parameters:
+//   - reportService: Service handling report subscriptions
+//
+// Returns:
+//   - A properly initialized ReportHandler
+func NewReportHandler(reportService ReportServiceInterface) *ReportHandler {
+	return &ReportHandler{
+		reportService: reportService,
+	}
+}
+
+// ListSubscriptions returns the report subscriptions of the current user.
+//
+// HTTP Method:
+//   - GET
+//
+// URL Path:
+//   - /api/reports/subscriptions
+//
+// Requires:
+//   - Authentication: User must be logged in
+//
+// Responses:
+//   - 200 OK: List of subscriptions
+//   - 401 Unauthorized: User not authenticated
+//   - 500 Internal Server Error: Server-side error
+//
+// @Summary List report subscriptions
+// @Description Returns the scheduled reports the currently authenticated user is subscribed to
+// @Tags Reports
+// @Produce json
+// @Security BearerAuth
+// @Success 200 {object} utils.Response{data=[]models.ReportSubscription} "List of subscriptions"
+// @Failure 401 {object} utils.Response{error=string} "User not authenticated"
+// @Failure 500 {object} utils.Response{error=string} "Server error"
+// @Router /reports/subscriptions [get]
+func (h *ReportHandler) ListSubscriptions(w http.ResponseWriter, r *http.Request) {
+	userID, ok := auth.GetUserID(r)
+	if !ok {
+		utils.Unauthorized(w, constants.MsgAuthRequired)
+		return
+	}
+
+	subscriptions, err := h.reportService.ListSubscriptions(r.Context(), userID)
+	if err != nil {
+		utils.ErrorFromAppError(w, utils.ParseError(err))
+		return
+	}
+
+	utils.ListAll(w, r, subscriptions)
+}
+
+// Subscribe subscribes the current user to a scheduled report.
+// Weekly entity summaries are sent on Mondays, monthly usage reports on the
+// first day of the month, both by email with the report attached as CSV.
+//
+// HTTP Method:
+//   - POST
+//
+// URL Path:
+//   - /api/reports/subscriptions
+//
+// Requires:
+//   - Authentication: User must be logged in
+//
+// Request Body:
+//   - JSON object conforming to models.ReportSubscriptionCreate
+//
+// Responses:
+//   - 201 Created: Subscription created
+//   - 400 Bad Request: Invalid request body
+//   - 401 Unauthorized: User not authenticated
+//   - 409 Conflict: Already subscribed to the report
+//   - 500 Internal Server Error: Server-side error
+//
+// @Summary Subscribe to a report
+// @Description Subscribes the currently authenticated user to a scheduled report delivered by email
+// @Tags Reports
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param subscription body models.ReportSubscriptionCreate true "Report to subscribe to"
+// @Success 201 {object} utils.Response{data=models.ReportSubscription} "Subscription created"
+// @Failure 400 {object} utils.Response{error=string} "Invalid request body"
+// @Failure 401 {object} utils.Response{error=string} "User not authenticated"
+// @Failure 409 {object} utils.Response{error=string} "Already subscribed"
+// @Failure 500 {object} utils.Response{error=string} "Server error"
+// @Router /reports/subscriptions [post]
+func (h *ReportHandler) Subscribe(w http.ResponseWriter, r *http.Request) {
+	userID, ok := auth.GetUserID(r)
+	if !ok {
+		utils.Unauthorized(w, constants.MsgAuthRequired)
+		return
+	}
+
+	var req models.ReportSubscriptionCreate
+	if err := utils.DecodeAndValidate(r, &req); err != nil {
+		utils.ErrorFromAppError(w, utils.ParseError(err))
+		return
+	}
+
+	subscription, err := h.reportService.Subscribe(r.Context(), userID, &req)
+	if err != nil {
+		utils.ErrorFromAppError(w, utils.ParseError(err))
+		return
+	}
+
+	utils.JSON(w, constants.StatusCreated, subscription)
+}
+
+// Unsubscribe removes one of the current user's report subscriptions.
+//
+// HTTP Method:
+//   - DELETE
+//
+// URL Path:
+//   - /api/reports/subscriptions/{id}
+//
+// Requires:
+//   - Authentication: User must be logged in
+//
+// Responses:
+//   - 204 No Content: Subscription removed
+//   - 400 Bad Request: Invalid subscription ID
+//   - 401 Unauthorized: User not authenticated
+//   - 404 Not Found: Subscription not found
+//   - 500 Internal Server Error: Server-side error
+//
+// @Summary Unsubscribe from a report
+// @Description Removes one of the currently authenticated user's report subscriptions
+// @Tags Reports
+// @Security BearerAuth
+// @Param id path int true "Subscription ID"
+// @Success 204 "Subscription removed"
+// @Failure 400 {object} utils.Response{error=string} "Invalid subscription ID"
+// @Failure 401 {object} utils.Response{error=string} "User not authenticated"
+// @Failure 404 {object} utils.Response{error=string} "Subscription not found"
+// @Failure 500 {object} utils.Response{error=string} "Server error"
+// @Router /reports/subscriptions/{id} [delete]
+func (h *ReportHandler) Unsubscribe(w http.ResponseWriter, r *http.Request) {
+	userID, ok := auth.GetUserID(r)
+	if !ok {
+		utils.Unauthorized(w, constants.MsgAuthRequired)
+		return
+	}
+
+	subscriptionID, err := strconv.ParseInt(chi.URLParam(r, constants.ParamID), 10, 64)
+	if err != nil {
+		utils.BadRequest(w, "Invalid subscription ID", nil)
+		return
+	}
+
+	if err := h.reportService.Unsubscribe(r.Context(), userID, subscriptionID); err != nil {
+		utils.ErrorFromAppError(w, utils.ParseError(err))
+		return
+	}
+
+	utils.NoContent(w)
+}
@@ -0,0 +1,179 @@
+package handlers_test
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+
+	"github.com/yasinhessnawi1/Hideme_Backend/internal/handlers"
+	"github.com/yasinhessnawi1/Hideme_Backend/internal/models"
+	"github.com/yasinhessnawi1/Hideme_Backend/internal/testsupport/golden"
+	"github.com/yasinhessnawi1/Hideme_Backend/internal/utils"
+)
+
+// MockReportService is a mock implementation of the ReportService
+type MockReportService struct {
+	mock.Mock
+}
+
+func (m *MockReportService) Subscribe(ctx context.Context, userID int64, req *models.ReportSubscriptionCreate) (*models.ReportSubscription, error) {
+	args := m.Called(ctx, userID, req)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*models.ReportSubscription), args.Error(1)
+}
+
+func (m *MockReportService) ListSubscriptions(ctx context.Context, userID int64) ([]*models.ReportSubscription, error) {
+	args := m.Called(ctx, userID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]*models.ReportSubscription), args.Error(1)
+}
+
+func (m *MockReportService) Unsubscribe(ctx context.Context, userID int64, subscriptionID int64) error {
+	args := m.Called(ctx, userID, subscriptionID)
+	return args.Error(0)
+}
+
+func setupReportTest() (*chi.Mux, *MockReportService) {
+	mockService := new(MockReportService)
+	handler := handlers.NewReportHandler(mockService)
+
+	router := chi.NewRouter()
+	router.Get("/api/reports/subscriptions", handler.ListSubscriptions)
+	router.Post("/api/reports/subscriptions", handler.Subscribe)
+	router.Delete("/api/reports/subscriptions/{id}", handler.Unsubscribe)
+
+	return router, mockService
+}
+
+func TestListReportSubscriptions(t *testing.T) {
+	router, mockService := setupReportTest()
+
+	subscriptions := []*models.ReportSubscription{
+		{ID: 1, UserID: 1, ReportType: models.ReportWeeklyEntitySummary, Format: models.ReportFormatCSV, Email: "user@example.com"},
+	}
+	mockService.On("ListSubscriptions", mock.Anything, int64(1)).Return(subscriptions, nil).Once()
+
+	req, err := http.NewRequest("GET", "/api/reports/subscriptions", nil)
+	require.NoError(t, err)
+	req = req.WithContext(createAuthContext(1))
+
+	rr := httptest.NewRecorder()
+	router.ServeHTTP(rr, req)
+
+	golden.AssertResponse(t, rr)
+	mockService.AssertExpectations(t)
+}
+
+func TestSubscribeReport(t *testing.T) {
+	router, mockService := setupReportTest()
+
+	t.Run("Success", func(t *testing.T) {
+		subscription := &models.ReportSubscription{ID: 3, UserID: 1, ReportType: models.ReportMonthlyUsage, Format: models.ReportFormatCSV}
+		mockService.On("Subscribe", mock.Anything, int64(1), &models.ReportSubscriptionCreate{ReportType: models.ReportMonthlyUsage}).
+			Return(subscription, nil).Once()
+
+		body, _ := json.Marshal(map[string]string{"report_type": "monthly_usage"})
+		req, err := http.NewRequest("POST", "/api/reports/subscriptions", bytes.NewBuffer(body))
+		require.NoError(t, err)
+		req.Header.Set("Content-Type", "application/json")
+		req = req.WithContext(createAuthContext(1))
+
+		rr := httptest.NewRecorder()
+		router.ServeHTTP(rr, req)
+
+		golden.AssertResponse(t, rr)
+	})
+
+	t.Run("Unknown Report Type", func(t *testing.T) {
+		body, _ := json.Marshal(map[string]string{"report_type": "daily_gossip"})
+		req, err := http.NewRequest("POST", "/api/reports/subscriptions", bytes.NewBuffer(body))
+		require.NoError(t, err)
+		req.Header.Set("Content-Type", "application/json")
+		req = req.WithContext(createAuthContext(1))
+
+		rr := httptest.NewRecorder()
+		router.ServeHTTP(rr, req)
+
+		assert.Equal(t, http.StatusBadRequest, rr.Code)
+	})
+
+	t.Run("Already Subscribed", func(t *testing.T) {
+		mockService.On("Subscribe", mock.Anything, int64(1), mock.Anything).
+			Return(nil, utils.NewDuplicateError("ReportSubscription", "report_type", models.ReportMonthlyUsage)).Once()
+
+		body, _ := json.Marshal(map[string]string{"report_type": "monthly_usage"})
+		req, err := http.NewRequest("POST", "/api/reports/subscriptions", bytes.NewBuffer(body))
+		require.NoError(t, err)
+		req.Header.Set("Content-Type", "application/json")
+		req = req.WithContext(createAuthContext(1))
+
+		rr := httptest.NewRecorder()
+		router.ServeHTTP(rr, req)
+
+		assert.Equal(t, http.StatusConflict, rr.Code)
+	})
+
+	t.Run("Unauthenticated", func(t *testing.T) {
+		req, err := http.NewRequest("POST", "/api/reports/subscriptions", bytes.NewBufferString(`{}`))
+		require.NoError(t, err)
+
+		rr := httptest.NewRecorder()
+		router.ServeHTTP(rr, req)
+
+		assert.Equal(t, http.StatusUnauthorized, rr.Code)
+	})
+}
+
+func TestUnsubscribeReport(t *testing.T) {
+	router, mockService := setupReportTest()
+
+	t.Run("Success", func(t *testing.T) {
+		mockService.On("Unsubscribe", mock.Anything, int64(1), int64(3)).Return(nil).Once()
+
+		req, err := http.NewRequest("DELETE", "/api/reports/subscriptions/3", nil)
+		require.NoError(t, err)
+		req = req.WithContext(createAuthContext(1))
+
+		rr := httptest.NewRecorder()
+		router.ServeHTTP(rr, req)
+
+		assert.Equal(t, http.StatusNoContent, rr.Code)
+	})
+
+	t.Run("Not Found", func(t *testing.T) {
+		mockService.On("Unsubscribe", mock.Anything, int64(1), int64(4)).
+			Return(utils.NewNotFoundError("ReportSubscription", int64(4))).Once()
+
+		req, err := http.NewRequest("DELETE", "/api/reports/subscriptions/4", nil)
+		require.NoError(t, err)
+		req = req.WithContext(createAuthContext(1))
+
+		rr := httptest.NewRecorder()
+		router.ServeHTTP(rr, req)
+
+		assert.Equal(t, http.StatusNotFound, rr.Code)
+	})
+
+	t.Run("Invalid ID", func(t *testing.T) {
+		req, err := http.NewRequest("DELETE", "/api/reports/subscriptions/abc", nil)
+		require.NoError(t, err)
+		req = req.WithContext(createAuthContext(1))
+
+		rr := httptest.NewRecorder()
+		router.ServeHTTP(rr, req)
+
+		assert.Equal(t, http.StatusBadRequest, rr.Code)
+	})
+}
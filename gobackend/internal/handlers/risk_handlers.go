@@ -0,0 +1,167 @@
+// Package handlers provides HTTP request handlers for the HideMe API.
+package handlers
+
+import (
+	"context"
+	"net/http"
+	"strconv"
+
+	"github.com/go-chi/chi/v5"
+
+	"github.com/yasinhessnawi1/Hideme_Backend/internal/constants"
+	"github.com/yasinhessnawi1/Hideme_Backend/internal/models"
+	"github.com/yasinhessnawi1/Hideme_Backend/internal/utils"
+)
+
+// RiskServiceInterface defines the service methods required for lifting account holds.
+type RiskServiceInterface interface {
+	VerifyEmail(ctx context.Context, token string) error
+	ListHolds(ctx context.Context) ([]*models.AccountHold, error)
+	ReleaseHold(ctx context.Context, userID int64) error
+}
+
+// RiskHandler handles HTTP requests for accounts held back by risk scoring.
+type RiskHandler struct {
+	riskService RiskServiceInterface
+}
+
+// NewRiskHandler creates a new RiskHandler with the provided risk service.
+//
+// Parameters:
+//   - riskService: Service managing account holds
+//
+// Returns:
+//   - A properly initialized RiskHandler
+func NewRiskHandler(riskService RiskServiceInterface) *RiskHandler {
+	return &RiskHandler{
+		riskService: riskService,
+	}
+}
+
+// VerifyEmail verifies a user's email address, lifting the hold placed on their account.
+//
+// HTTP Method:
+//   - POST
+//
+// URL Path:
+//   - /api/auth/verify-email
+//
+// Request Body:
+//   - JSON object with a "token" field
+//
+// Responses:
+//   - 200 OK: Email verified
+//   - 400 Bad Request: Invalid request body
+//   - 401 Unauthorized: Invalid or expired token
+//   - 500 Internal Server Error: Server-side error
+//
+// @Summary Verify email address
+// @Description Verifies an email address with the token sent by email and lifts the account hold
+// @Tags Authentication
+// @Accept json
+// @Produce json
+// @Param verification body models.EmailVerificationRequest true "Verification token"
+// @Success 200 {object} utils.Response{data=map[string]string} "Email verified"
+// @Failure 400 {object} utils.Response{error=string} "Invalid request data"
+// @Failure 401 {object} utils.Response{error=string} "Invalid or expired token"
+// @Failure 500 {object} utils.Response{error=string} "Server error"
+// @Router /auth/verify-email [post]
+func (h *RiskHandler) VerifyEmail(w http.ResponseWriter, r *http.Request) {
+	var req models.EmailVerificationRequest
+	if err := utils.DecodeAndValidate(r, &req); err != nil {
+		utils.ErrorFromAppError(w, utils.ParseError(err))
+		return
+	}
+
+	if err := h.riskService.VerifyEmail(r.Context(), req.Token); err != nil {
+		utils.ErrorFromAppError(w, utils.ParseError(err))
+		return
+	}
+
+	utils.JSON(w, constants.StatusOK, map[string]string{
+		"message": constants.MsgEmailVerified,
+	})
+}
+
+// ListHolds returns the accounts held back by risk scoring.
+//
+// HTTP Method:
+//   - GET
+//
+// URL Path:
+//   - /api/admin/account-holds
+//
+// Requires:
+//   - Authentication: Admin role
+//
+// Responses:
+//   - 200 OK: List of account holds
+//   - 401 Unauthorized: User not authenticated
+//   - 403 Forbidden: User not authorized (not admin)
+//   - 500 Internal Server Error: Server-side error
+//
+// @Summary List account holds
+// @Description Returns accounts held back by risk scoring, oldest first
+// @Tags Admin/Risk
+// @Produce json
+// @Security BearerAuth
+// @Success 200 {object} utils.Response{data=[]models.AccountHold} "List of account holds"
+// @Failure 401 {object} utils.Response{error=string} "User not authenticated"
+// @Failure 403 {object} utils.Response{error=string} "User not authorized"
+// @Failure 500 {object} utils.Response{error=string} "Server error"
+// @Router /admin/account-holds [get]
+func (h *RiskHandler) ListHolds(w http.ResponseWriter, r *http.Request) {
+	holds, err := h.riskService.ListHolds(r.Context())
+	if err != nil {
+		utils.ErrorFromAppError(w, utils.ParseError(err))
+		return
+	}
+
+	utils.ListAll(w, r, holds)
+}
+
+// ReleaseHold releases the hold on an account after manual review.
+//
+// HTTP Method:
+//   - DELETE
+//
+// URL Path:
+//   - /api/admin/account-holds/{id}
+//
+// Requires:
+//   - Authentication: Admin role
+//
+// Responses:
+//   - 204 No Content: Hold released
+//   - 400 Bad Request: Invalid user ID
+//   - 401 Unauthorized: User not authenticated
+//   - 403 Forbidden: User not authorized (not admin)
+//   - 404 Not Found: Account is not held
+//   - 500 Internal Server Error: Server-side error
+//
+// @Summary Release account hold
+// @Description Releases the hold placed on an account by risk scoring
+// @Tags Admin/Risk
+// @Security BearerAuth
+// @Param id path int true "User ID"
+// @Success 204 "Hold released"
+// @Failure 400 {object} utils.Response{error=string} "Invalid user ID"
+// @Failure 401 {object} utils.Response{error=string} "User not authenticated"
+// @Failure 403 {object} utils.Response{error=string} "User not authorized"
+// @Failure 404 {object} utils.Response{error=string} "Account is not held"
+// @Failure 500 {object} utils.Response{error=string} "Server error"
+// @Router /admin/account-holds/{id} [delete]
+func (h *RiskHandler) ReleaseHold(w http.ResponseWriter, r *http.Request) {
+	userID, err := strconv.ParseInt(chi.URLParam(r, constants.ParamID), 10, 64)
+	if err != nil {
+		utils.BadRequest(w, "Invalid user ID", nil)
+		return
+	}
+
+	if err := h.riskService.ReleaseHold(r.Context(), userID); err != nil {
+		utils.ErrorFromAppError(w, utils.ParseError(err))
+		return
+	}
+
+	utils.NoContent(w)
+}
@@ -0,0 +1,160 @@
+package handlers_test
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+
+	"github.com/yasinhessnawi1/Hideme_Backend/internal/handlers"
+	"github.com/yasinhessnawi1/Hideme_Backend/internal/models"
+	"github.com/yasinhessnawi1/Hideme_Backend/internal/testsupport/golden"
+	"github.com/yasinhessnawi1/Hideme_Backend/internal/utils"
+)
+
+// MockRiskService is a mock implementation of the RiskService
+type MockRiskService struct {
+	mock.Mock
+}
+
+func (m *MockRiskService) VerifyEmail(ctx context.Context, token string) error {
+	args := m.Called(ctx, token)
+	return args.Error(0)
+}
+
+func (m *MockRiskService) ListHolds(ctx context.Context) ([]*models.AccountHold, error) {
+	args := m.Called(ctx)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]*models.AccountHold), args.Error(1)
+}
+
+func (m *MockRiskService) ReleaseHold(ctx context.Context, userID int64) error {
+	args := m.Called(ctx, userID)
+	return args.Error(0)
+}
+
+func setupRiskTest() (*chi.Mux, *MockRiskService) {
+	mockService := new(MockRiskService)
+	handler := handlers.NewRiskHandler(mockService)
+
+	router := chi.NewRouter()
+	router.Post("/api/auth/verify-email", handler.VerifyEmail)
+	router.Get("/api/admin/account-holds", handler.ListHolds)
+	router.Delete("/api/admin/account-holds/{id}", handler.ReleaseHold)
+
+	return router, mockService
+}
+
+func TestVerifyEmail(t *testing.T) {
+	router, mockService := setupRiskTest()
+
+	t.Run("Success", func(t *testing.T) {
+		mockService.On("VerifyEmail", mock.Anything, "token-123").Return(nil).Once()
+
+		body, _ := json.Marshal(map[string]string{"token": "token-123"})
+		req, err := http.NewRequest("POST", "/api/auth/verify-email", bytes.NewBuffer(body))
+		require.NoError(t, err)
+		req.Header.Set("Content-Type", "application/json")
+
+		rr := httptest.NewRecorder()
+		router.ServeHTTP(rr, req)
+
+		assert.Equal(t, http.StatusOK, rr.Code)
+	})
+
+	t.Run("Missing token", func(t *testing.T) {
+		req, err := http.NewRequest("POST", "/api/auth/verify-email", bytes.NewBufferString(`{}`))
+		require.NoError(t, err)
+		req.Header.Set("Content-Type", "application/json")
+
+		rr := httptest.NewRecorder()
+		router.ServeHTTP(rr, req)
+
+		assert.Equal(t, http.StatusBadRequest, rr.Code)
+	})
+
+	t.Run("Invalid token", func(t *testing.T) {
+		mockService.On("VerifyEmail", mock.Anything, "wrong").Return(utils.NewInvalidTokenError()).Once()
+
+		body, _ := json.Marshal(map[string]string{"token": "wrong"})
+		req, err := http.NewRequest("POST", "/api/auth/verify-email", bytes.NewBuffer(body))
+		require.NoError(t, err)
+		req.Header.Set("Content-Type", "application/json")
+
+		rr := httptest.NewRecorder()
+		router.ServeHTTP(rr, req)
+
+		assert.Equal(t, http.StatusUnauthorized, rr.Code)
+	})
+
+	mockService.AssertExpectations(t)
+}
+
+func TestListHolds(t *testing.T) {
+	router, mockService := setupRiskTest()
+
+	holds := []*models.AccountHold{{UserID: 7, HoldType: models.RiskActionManualReview, RiskScore: 90, TokenHash: "secret"}}
+	mockService.On("ListHolds", mock.Anything).Return(holds, nil).Once()
+
+	req, err := http.NewRequest("GET", "/api/admin/account-holds", nil)
+	require.NoError(t, err)
+	req = req.WithContext(createAuthContext(1))
+
+	rr := httptest.NewRecorder()
+	router.ServeHTTP(rr, req)
+
+	golden.AssertResponse(t, rr)
+	mockService.AssertExpectations(t)
+}
+
+func TestReleaseHold(t *testing.T) {
+	router, mockService := setupRiskTest()
+
+	t.Run("Success", func(t *testing.T) {
+		mockService.On("ReleaseHold", mock.Anything, int64(7)).Return(nil).Once()
+
+		req, err := http.NewRequest("DELETE", "/api/admin/account-holds/7", nil)
+		require.NoError(t, err)
+		req = req.WithContext(createAuthContext(1))
+
+		rr := httptest.NewRecorder()
+		router.ServeHTTP(rr, req)
+
+		assert.Equal(t, http.StatusNoContent, rr.Code)
+	})
+
+	t.Run("Not held", func(t *testing.T) {
+		mockService.On("ReleaseHold", mock.Anything, int64(8)).Return(utils.NewNotFoundError("AccountHold", int64(8))).Once()
+
+		req, err := http.NewRequest("DELETE", "/api/admin/account-holds/8", nil)
+		require.NoError(t, err)
+		req = req.WithContext(createAuthContext(1))
+
+		rr := httptest.NewRecorder()
+		router.ServeHTTP(rr, req)
+
+		assert.Equal(t, http.StatusNotFound, rr.Code)
+	})
+
+	t.Run("Invalid ID", func(t *testing.T) {
+		req, err := http.NewRequest("DELETE", "/api/admin/account-holds/abc", nil)
+		require.NoError(t, err)
+		req = req.WithContext(createAuthContext(1))
+
+		rr := httptest.NewRecorder()
+		router.ServeHTTP(rr, req)
+
+		assert.Equal(t, http.StatusBadRequest, rr.Code)
+	})
+
+	mockService.AssertExpectations(t)
+}
@@ -0,0 +1,78 @@
+// Package handlers provides HTTP request handlers for the HideMe API.
+package handlers
+
+import (
+	"context"
+	"net/http"
+
+	"github.com/yasinhessnawi1/Hideme_Backend/internal/auth"
+	"github.com/yasinhessnawi1/Hideme_Backend/internal/constants"
+	"github.com/yasinhessnawi1/Hideme_Backend/internal/models"
+	"github.com/yasinhessnawi1/Hideme_Backend/internal/utils"
+)
+
+// RuleEffectivenessServiceInterface defines the service methods required for the effectiveness of ban list words and search patterns.
+type RuleEffectivenessServiceInterface interface {
+	GetEffectiveness(ctx context.Context, userID int64) (*models.RuleEffectivenessReport, error)
+}
+
+// RuleEffectivenessHandler handles HTTP requests for the effectiveness analytics of the
+// user's ban list words and search patterns.
+type RuleEffectivenessHandler struct {
+	effectivenessService RuleEffectivenessServiceInterface
+}
+
+// NewRuleEffectivenessHandler creates a new RuleEffectivenessHandler with the provided service.
+//
+// Parameters:
+//   - effectivenessService: Service tracking the detections produced by the rules
+//
+// Returns:
+//   - A properly initialized RuleEffectivenessHandler
+func NewRuleEffectivenessHandler(effectivenessService RuleEffectivenessServiceInterface) *RuleEffectivenessHandler {
+	return &RuleEffectivenessHandler{
+		effectivenessService: effectivenessService,
+	}
+}
+
+// GetEffectiveness ranks the user's ban list words and search patterns by the detections
+// they produced in saved documents, and suggests the ones that went long without one for removal.
+//
+// HTTP Method:
+//   - GET
+//
+// URL Path:
+//   - /api/settings/effectiveness
+//
+// Requires:
+//   - Authentication: User must be logged in
+//
+// Responses:
+//   - 200 OK: The rules with their hit counts and the stale ones
+//   - 401 Unauthorized: User not authenticated
+//   - 500 Internal Server Error: Server-side error
+//
+// @Summary Get ban list and search pattern effectiveness
+// @Description Ranks ban list words and search patterns by hit count and last hit, and suggests stale rules for removal; AI search patterns are not tracked
+// @Tags Settings
+// @Produce json
+// @Security BearerAuth
+// @Success 200 {object} utils.Response{data=models.RuleEffectivenessReport} "Rule effectiveness"
+// @Failure 401 {object} utils.Response{error=string} "User not authenticated"
+// @Failure 500 {object} utils.Response{error=string} "Server error"
+// @Router /settings/effectiveness [get]
+func (h *RuleEffectivenessHandler) GetEffectiveness(w http.ResponseWriter, r *http.Request) {
+	userID, ok := auth.GetUserID(r)
+	if !ok {
+		utils.Unauthorized(w, constants.MsgAuthRequired)
+		return
+	}
+
+	report, err := h.effectivenessService.GetEffectiveness(r.Context(), userID)
+	if err != nil {
+		utils.ErrorFromAppError(w, utils.ParseError(err))
+		return
+	}
+
+	utils.JSON(w, constants.StatusOK, report)
+}
@@ -0,0 +1,82 @@
+package handlers_test
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+
+	"github.com/yasinhessnawi1/Hideme_Backend/internal/constants"
+	"github.com/yasinhessnawi1/Hideme_Backend/internal/handlers"
+	"github.com/yasinhessnawi1/Hideme_Backend/internal/models"
+	"github.com/yasinhessnawi1/Hideme_Backend/internal/testsupport/golden"
+)
+
+// MockRuleEffectivenessService is a mock implementation of the RuleEffectivenessService
+type MockRuleEffectivenessService struct {
+	mock.Mock
+}
+
+func (m *MockRuleEffectivenessService) GetEffectiveness(ctx context.Context, userID int64) (*models.RuleEffectivenessReport, error) {
+	args := m.Called(ctx, userID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*models.RuleEffectivenessReport), args.Error(1)
+}
+
+func TestRuleEffectivenessHandler_GetEffectiveness(t *testing.T) {
+	mockService := new(MockRuleEffectivenessService)
+	handler := handlers.NewRuleEffectivenessHandler(mockService)
+	router := chi.NewRouter()
+	router.Get("/api/settings/effectiveness", handler.GetEffectiveness)
+
+	t.Run("Success", func(t *testing.T) {
+		stale := &models.RuleEffectiveness{RuleType: constants.RuleTypeBanListWord, Text: "bergen", Tracked: true, Stale: true}
+		mockService.On("GetEffectiveness", mock.Anything, int64(1)).Return(&models.RuleEffectivenessReport{
+			StaleAfterDays: 90,
+			Rules: []*models.RuleEffectiveness{
+				{RuleType: constants.RuleTypeSearchPattern, Text: "nordmann", PatternID: 1, PatternType: models.Normal, Tracked: true, HitCount: 4},
+				stale,
+			},
+			Suggestions: []*models.RuleEffectiveness{stale},
+		}, nil).Once()
+
+		req, err := http.NewRequest("GET", "/api/settings/effectiveness", nil)
+		require.NoError(t, err)
+		req = req.WithContext(createAuthContext(1))
+		rr := httptest.NewRecorder()
+		router.ServeHTTP(rr, req)
+
+		golden.AssertResponse(t, rr)
+	})
+
+	t.Run("Service Error", func(t *testing.T) {
+		mockService.On("GetEffectiveness", mock.Anything, int64(2)).Return(nil, errors.New("database error")).Once()
+
+		req, err := http.NewRequest("GET", "/api/settings/effectiveness", nil)
+		require.NoError(t, err)
+		req = req.WithContext(createAuthContext(2))
+		rr := httptest.NewRecorder()
+		router.ServeHTTP(rr, req)
+
+		assert.Equal(t, http.StatusInternalServerError, rr.Code)
+	})
+
+	t.Run("Unauthorized", func(t *testing.T) {
+		req, err := http.NewRequest("GET", "/api/settings/effectiveness", nil)
+		require.NoError(t, err)
+		rr := httptest.NewRecorder()
+		router.ServeHTTP(rr, req)
+
+		assert.Equal(t, http.StatusUnauthorized, rr.Code)
+	})
+
+	mockService.AssertExpectations(t)
+}
@@ -0,0 +1,80 @@
+// Package handlers provides HTTP request handlers for the HideMe API.
+package handlers
+
+import (
+	"embed"
+	"fmt"
+	"net/http"
+	"strings"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/rs/zerolog/log"
+
+	"github.com/yasinhessnawi1/Hideme_Backend/internal/constants"
+	"github.com/yasinhessnawi1/Hideme_Backend/internal/utils"
+)
+
+// xmlSchemas holds the XSD files of the endpoints that can respond with XML.
+//
+//go:embed schemas/*.xsd
+var xmlSchemas embed.FS
+
+// SchemaHandler publishes the XML schemas of the endpoints that can respond with XML,
+// so integrations that only ingest XML can validate what they receive.
+type SchemaHandler struct{}
+
+// NewSchemaHandler creates a new SchemaHandler.
+//
+// Returns:
+//   - A properly initialized SchemaHandler
+func NewSchemaHandler() *SchemaHandler {
+	return &SchemaHandler{}
+}
+
+// GetXMLSchema returns a published XML schema.
+//
+// HTTP Method:
+//   - GET
+//
+// URL Path:
+//   - /api/schemas/xml/{schema}
+//
+// URL Parameters:
+//   - schema: The schema name, e.g. "documents", with or without the .xsd extension
+//
+// Responses:
+//   - 200 OK: The XSD document
+//   - 404 Not Found: No schema with that name
+//
+// @Summary Get XML schema
+// @Description Returns the XSD of an endpoint's XML rendering: documents, model_entities or settings_export
+// @Tags Schemas
+// @Produce xml
+// @Param schema path string true "Schema name"
+// @Success 200 {file} byte[] "XSD document"
+// @Failure 404 {object} utils.Response{error=string} "Schema not found"
+// @Router /schemas/xml/{schema} [get]
+func (h *SchemaHandler) GetXMLSchema(w http.ResponseWriter, r *http.Request) {
+	name := strings.TrimSuffix(chi.URLParam(r, constants.ParamSchema), ".xsd")
+
+	// Only the published schema names are looked up, which also rules out path traversal
+	switch name {
+	case constants.XMLSchemaDocuments, constants.XMLSchemaModelEntities, constants.XMLSchemaSettingsExport:
+	default:
+		utils.NotFound(w, "")
+		return
+	}
+
+	schema, err := xmlSchemas.ReadFile("schemas/" + name + ".xsd")
+	if err != nil {
+		utils.InternalServerError(w, fmt.Errorf("failed to read schema %s: %w", name, err))
+		return
+	}
+
+	w.Header().Set(constants.HeaderContentType, constants.ContentTypeXML)
+	w.Header().Set(constants.HeaderCacheControl, fmt.Sprintf(constants.CacheControlPublicFormat, constants.XMLSchemaCacheSeconds))
+	w.WriteHeader(constants.StatusOK)
+	if _, err := w.Write(schema); err != nil {
+		log.Error().Err(err).Str("schema", name).Msg("Failed to write XML schema")
+	}
+}
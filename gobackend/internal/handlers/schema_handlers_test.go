@@ -0,0 +1,55 @@
+package handlers_test
+
+import (
+	"encoding/xml"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/yasinhessnawi1/Hideme_Backend/internal/constants"
+	"github.com/yasinhessnawi1/Hideme_Backend/internal/handlers"
+)
+
+func TestGetXMLSchema(t *testing.T) {
+	handler := handlers.NewSchemaHandler()
+	router := chi.NewRouter()
+	router.Get("/api/schemas/xml/{schema}", handler.GetXMLSchema)
+
+	for _, name := range []string{constants.XMLSchemaDocuments, constants.XMLSchemaModelEntities, constants.XMLSchemaSettingsExport + ".xsd"} {
+		t.Run(name, func(t *testing.T) {
+			req, err := http.NewRequest("GET", "/api/schemas/xml/"+name, nil)
+			require.NoError(t, err)
+
+			rr := httptest.NewRecorder()
+			router.ServeHTTP(rr, req)
+
+			assert.Equal(t, http.StatusOK, rr.Code)
+			assert.Equal(t, constants.ContentTypeXML, rr.Header().Get("Content-Type"))
+			assert.Contains(t, rr.Header().Get("Cache-Control"), "public")
+
+			// The schema must be well-formed XML
+			decoder := xml.NewDecoder(rr.Body)
+			for {
+				if _, err := decoder.Token(); err != nil {
+					assert.ErrorIs(t, err, io.EOF)
+					break
+				}
+			}
+		})
+	}
+
+	t.Run("Unknown Schema", func(t *testing.T) {
+		req, err := http.NewRequest("GET", "/api/schemas/xml/users", nil)
+		require.NoError(t, err)
+
+		rr := httptest.NewRecorder()
+		router.ServeHTTP(rr, req)
+
+		assert.Equal(t, http.StatusNotFound, rr.Code)
+	})
+}
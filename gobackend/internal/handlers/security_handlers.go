@@ -78,7 +78,7 @@ func (h *SecurityHandler) ListBannedIPs(w http.ResponseWriter, r *http.Request)
 	}
 
 	// Return the bans
-	utils.JSON(w, http.StatusOK, bans)
+	utils.ListAll(w, r, bans)
 }
 
 // BanIP adds an IP address to the ban list.
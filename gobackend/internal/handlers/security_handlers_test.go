@@ -17,6 +17,8 @@ import (
 
 	"github.com/yasinhessnawi1/Hideme_Backend/internal/auth"
 	"github.com/yasinhessnawi1/Hideme_Backend/internal/models"
+	"github.com/yasinhessnawi1/Hideme_Backend/internal/testsupport/fixtures"
+	"github.com/yasinhessnawi1/Hideme_Backend/internal/testsupport/golden"
 	"github.com/yasinhessnawi1/Hideme_Backend/internal/utils"
 )
 
@@ -146,13 +148,12 @@ func TestListBannedIPs(t *testing.T) {
 		name           string
 		setupMocks     func(*MockSecurityService)
 		expectedStatus int
-		checkResponse  func(*testing.T, *httptest.ResponseRecorder)
 	}{
 		{
 			name: "Success - Returns list of IP bans",
 			setupMocks: func(mockService *MockSecurityService) {
 				// Create test data
-				now := time.Now()
+				now := fixtures.Time
 				expires := now.Add(24 * time.Hour)
 				bans := []*models.IPBan{
 					{
@@ -175,26 +176,6 @@ func TestListBannedIPs(t *testing.T) {
 				mockService.On("ListBans", mock.Anything).Return(bans, nil)
 			},
 			expectedStatus: http.StatusOK,
-			checkResponse: func(t *testing.T, rr *httptest.ResponseRecorder) {
-				var response utils.Response
-				err := json.Unmarshal(rr.Body.Bytes(), &response)
-				assert.NoError(t, err)
-
-				// Verify response is successful
-				assert.True(t, response.Success)
-
-				// Verify bans data
-				var bans []*models.IPBan
-				dataBytes, err := json.Marshal(response.Data)
-				assert.NoError(t, err)
-				err = json.Unmarshal(dataBytes, &bans)
-				assert.NoError(t, err)
-
-				// Verify we got 2 bans
-				assert.Len(t, bans, 2)
-				assert.Equal(t, "192.168.1.1", bans[0].IPAddress)
-				assert.Equal(t, "10.0.0.1", bans[1].IPAddress)
-			},
 		},
 		{
 			name: "Success - Empty ban list",
@@ -202,23 +183,6 @@ func TestListBannedIPs(t *testing.T) {
 				mockService.On("ListBans", mock.Anything).Return([]*models.IPBan{}, nil)
 			},
 			expectedStatus: http.StatusOK,
-			checkResponse: func(t *testing.T, rr *httptest.ResponseRecorder) {
-				var response utils.Response
-				err := json.Unmarshal(rr.Body.Bytes(), &response)
-				assert.NoError(t, err)
-
-				// Verify response is successful
-				assert.True(t, response.Success)
-
-				// Verify empty array
-				var bans []*models.IPBan
-				dataBytes, err := json.Marshal(response.Data)
-				assert.NoError(t, err)
-				err = json.Unmarshal(dataBytes, &bans)
-				assert.NoError(t, err)
-
-				assert.Empty(t, bans)
-			},
 		},
 		{
 			name: "Error - Service returns error",
@@ -226,16 +190,6 @@ func TestListBannedIPs(t *testing.T) {
 				mockService.On("ListBans", mock.Anything).Return(nil, errors.New("database error"))
 			},
 			expectedStatus: http.StatusInternalServerError,
-			checkResponse: func(t *testing.T, rr *httptest.ResponseRecorder) {
-				var response utils.Response
-				err := json.Unmarshal(rr.Body.Bytes(), &response)
-				assert.NoError(t, err)
-
-				// Verify response indicates error
-				assert.False(t, response.Success)
-				assert.NotNil(t, response.Error)
-				assert.Equal(t, "internal_error", response.Error.Code)
-			},
 		},
 	}
 
@@ -264,10 +218,7 @@ func TestListBannedIPs(t *testing.T) {
 			// Check status code
 			assert.Equal(t, tt.expectedStatus, rr.Code)
 
-			// Additional response checks
-			if tt.checkResponse != nil {
-				tt.checkResponse(t, rr)
-			}
+			golden.AssertResponse(t, rr)
 
 			// Verify mocks
 			mockService.AssertExpectations(t)
@@ -283,7 +234,6 @@ func TestBanIP(t *testing.T) {
 		requestBody    interface{}
 		setupMocks     func(*MockSecurityService)
 		expectedStatus int
-		checkResponse  func(*testing.T, *httptest.ResponseRecorder)
 	}{
 		{
 			name: "Success - Temporary ban",
@@ -293,7 +243,7 @@ func TestBanIP(t *testing.T) {
 				"duration":   int64(3600), // 1 hour in seconds
 			},
 			setupMocks: func(mockService *MockSecurityService) {
-				now := time.Now()
+				now := fixtures.Time
 				expiry := now.Add(time.Hour)
 				mockBan := &models.IPBan{
 					ID:        1,
@@ -312,26 +262,6 @@ func TestBanIP(t *testing.T) {
 					"admin").Return(mockBan, nil)
 			},
 			expectedStatus: http.StatusCreated,
-			checkResponse: func(t *testing.T, rr *httptest.ResponseRecorder) {
-				var response utils.Response
-				err := json.Unmarshal(rr.Body.Bytes(), &response)
-				assert.NoError(t, err)
-
-				// Verify response is successful
-				assert.True(t, response.Success)
-
-				// Verify ban data
-				ban := make(map[string]interface{})
-				dataBytes, err := json.Marshal(response.Data)
-				assert.NoError(t, err)
-				err = json.Unmarshal(dataBytes, &ban)
-				assert.NoError(t, err)
-
-				assert.Equal(t, float64(1), ban["id"])
-				assert.Equal(t, "192.168.1.1", ban["ip_address"])
-				assert.Equal(t, "Suspicious activity", ban["reason"])
-				assert.Equal(t, "admin", ban["created_by"])
-			},
 		},
 		{
 			name: "Success - Permanent ban",
@@ -341,7 +271,7 @@ func TestBanIP(t *testing.T) {
 				"duration":   int64(0), // Permanent (0 seconds)
 			},
 			setupMocks: func(mockService *MockSecurityService) {
-				now := time.Now()
+				now := fixtures.Time
 				mockBan := &models.IPBan{
 					ID:        2,
 					IPAddress: "10.0.0.1",
@@ -359,27 +289,6 @@ func TestBanIP(t *testing.T) {
 					"admin").Return(mockBan, nil)
 			},
 			expectedStatus: http.StatusCreated,
-			checkResponse: func(t *testing.T, rr *httptest.ResponseRecorder) {
-				var response utils.Response
-				err := json.Unmarshal(rr.Body.Bytes(), &response)
-				assert.NoError(t, err)
-
-				// Verify response is successful
-				assert.True(t, response.Success)
-
-				// Verify ban data
-				ban := make(map[string]interface{})
-				dataBytes, err := json.Marshal(response.Data)
-				assert.NoError(t, err)
-				err = json.Unmarshal(dataBytes, &ban)
-				assert.NoError(t, err)
-
-				assert.Equal(t, float64(2), ban["id"])
-				assert.Equal(t, "10.0.0.1", ban["ip_address"])
-				assert.Equal(t, "Malicious activity", ban["reason"])
-				assert.Equal(t, "admin", ban["created_by"])
-				assert.Nil(t, ban["expires_at"])
-			},
 		},
 		{
 			name: "Error - Invalid IP address",
@@ -392,16 +301,6 @@ func TestBanIP(t *testing.T) {
 				// No mock setup needed - validation should fail
 			},
 			expectedStatus: http.StatusBadRequest,
-			checkResponse: func(t *testing.T, rr *httptest.ResponseRecorder) {
-				var response utils.Response
-				err := json.Unmarshal(rr.Body.Bytes(), &response)
-				assert.NoError(t, err)
-
-				// Verify response indicates error
-				assert.False(t, response.Success)
-				assert.NotNil(t, response.Error)
-				assert.Equal(t, "validation_error", response.Error.Code)
-			},
 		},
 		{
 			name: "Error - Missing reason",
@@ -414,16 +313,6 @@ func TestBanIP(t *testing.T) {
 				// No mock setup needed - validation should fail
 			},
 			expectedStatus: http.StatusBadRequest,
-			checkResponse: func(t *testing.T, rr *httptest.ResponseRecorder) {
-				var response utils.Response
-				err := json.Unmarshal(rr.Body.Bytes(), &response)
-				assert.NoError(t, err)
-
-				// Verify response indicates error
-				assert.False(t, response.Success)
-				assert.NotNil(t, response.Error)
-				assert.Equal(t, "validation_error", response.Error.Code)
-			},
 		},
 		{
 			name: "Error - Service returns error",
@@ -442,16 +331,6 @@ func TestBanIP(t *testing.T) {
 					"admin").Return(nil, errors.New("database error"))
 			},
 			expectedStatus: http.StatusInternalServerError,
-			checkResponse: func(t *testing.T, rr *httptest.ResponseRecorder) {
-				var response utils.Response
-				err := json.Unmarshal(rr.Body.Bytes(), &response)
-				assert.NoError(t, err)
-
-				// Verify response indicates error
-				assert.False(t, response.Success)
-				assert.NotNil(t, response.Error)
-				assert.Equal(t, "internal_error", response.Error.Code)
-			},
 		},
 		{
 			name:        "Error - Invalid JSON",
@@ -460,15 +339,6 @@ func TestBanIP(t *testing.T) {
 				// No mock setup needed - JSON parsing should fail
 			},
 			expectedStatus: http.StatusBadRequest,
-			checkResponse: func(t *testing.T, rr *httptest.ResponseRecorder) {
-				var response utils.Response
-				err := json.Unmarshal(rr.Body.Bytes(), &response)
-				assert.NoError(t, err)
-
-				// Verify response indicates error
-				assert.False(t, response.Success)
-				assert.NotNil(t, response.Error)
-			},
 		},
 	}
 
@@ -510,10 +380,7 @@ func TestBanIP(t *testing.T) {
 			// Check status code
 			assert.Equal(t, tt.expectedStatus, rr.Code)
 
-			// Additional response checks
-			if tt.checkResponse != nil {
-				tt.checkResponse(t, rr)
-			}
+			golden.AssertResponse(t, rr)
 
 			// Verify mocks
 			mockService.AssertExpectations(t)
@@ -537,7 +404,6 @@ func TestUnbanIP(t *testing.T) {
 		banID          string // As path parameter
 		setupMocks     func(*MockSecurityService, int64)
 		expectedStatus int
-		checkResponse  func(*testing.T, *httptest.ResponseRecorder)
 	}{
 		{
 			name:  "Success - Ban removed",
@@ -546,21 +412,6 @@ func TestUnbanIP(t *testing.T) {
 				mockService.On("UnbanIP", mock.Anything, banID).Return(nil)
 			},
 			expectedStatus: http.StatusOK,
-			checkResponse: func(t *testing.T, rr *httptest.ResponseRecorder) {
-				var response utils.Response
-				err := json.Unmarshal(rr.Body.Bytes(), &response)
-				assert.NoError(t, err)
-
-				// Verify response is successful
-				assert.True(t, response.Success)
-
-				// Verify success message
-				data, ok := response.Data.(map[string]interface{})
-				assert.True(t, ok)
-				message, ok := data["message"].(string)
-				assert.True(t, ok)
-				assert.Equal(t, "IP address successfully unbanned", message)
-			},
 		},
 		{
 			name:  "Error - Invalid ban ID format",
@@ -569,17 +420,6 @@ func TestUnbanIP(t *testing.T) {
 				// No mock setup needed - ID parsing should fail
 			},
 			expectedStatus: http.StatusBadRequest,
-			checkResponse: func(t *testing.T, rr *httptest.ResponseRecorder) {
-				var response utils.Response
-				err := json.Unmarshal(rr.Body.Bytes(), &response)
-				assert.NoError(t, err)
-
-				// Verify response indicates error
-				assert.False(t, response.Success)
-				assert.NotNil(t, response.Error)
-				assert.Equal(t, "bad_request", response.Error.Code)
-				assert.Contains(t, response.Error.Message, "Invalid ban ID")
-			},
 		},
 		{
 			name:  "Error - Ban not found",
@@ -588,16 +428,6 @@ func TestUnbanIP(t *testing.T) {
 				mockService.On("UnbanIP", mock.Anything, banID).Return(utils.NewNotFoundError("IPBan", banID))
 			},
 			expectedStatus: http.StatusNotFound,
-			checkResponse: func(t *testing.T, rr *httptest.ResponseRecorder) {
-				var response utils.Response
-				err := json.Unmarshal(rr.Body.Bytes(), &response)
-				assert.NoError(t, err)
-
-				// Verify response indicates error
-				assert.False(t, response.Success)
-				assert.NotNil(t, response.Error)
-				assert.Equal(t, "not_found", response.Error.Code)
-			},
 		},
 		{
 			name:  "Error - Service returns error",
@@ -606,16 +436,6 @@ func TestUnbanIP(t *testing.T) {
 				mockService.On("UnbanIP", mock.Anything, banID).Return(errors.New("database error"))
 			},
 			expectedStatus: http.StatusInternalServerError,
-			checkResponse: func(t *testing.T, rr *httptest.ResponseRecorder) {
-				var response utils.Response
-				err := json.Unmarshal(rr.Body.Bytes(), &response)
-				assert.NoError(t, err)
-
-				// Verify response indicates error
-				assert.False(t, response.Success)
-				assert.NotNil(t, response.Error)
-				assert.Equal(t, "internal_error", response.Error.Code)
-			},
 		},
 	}
 
@@ -650,10 +470,7 @@ func TestUnbanIP(t *testing.T) {
 			// Check status code
 			assert.Equal(t, tt.expectedStatus, rr.Code)
 
-			// Additional response checks
-			if tt.checkResponse != nil {
-				tt.checkResponse(t, rr)
-			}
+			golden.AssertResponse(t, rr)
 
 			// Verify mocks
 			mockService.AssertExpectations(t)
@@ -0,0 +1,74 @@
+// Package handlers provides HTTP request handlers for the HideMe API.
+package handlers
+
+import (
+	"context"
+	"net/http"
+
+	"github.com/yasinhessnawi1/Hideme_Backend/internal/constants"
+	"github.com/yasinhessnawi1/Hideme_Backend/internal/models"
+	"github.com/yasinhessnawi1/Hideme_Backend/internal/utils"
+)
+
+// SettingsConsistencyServiceInterface defines the service methods required for checking the settings object graph.
+type SettingsConsistencyServiceInterface interface {
+	Check(ctx context.Context) (*models.SettingsConsistencyReport, error)
+}
+
+// SettingsConsistencyHandler handles HTTP requests for the consistency check of the settings object graph.
+// The repair runs as the settings_consistency maintenance task.
+type SettingsConsistencyHandler struct {
+	consistencyService SettingsConsistencyServiceInterface
+}
+
+// NewSettingsConsistencyHandler creates a new SettingsConsistencyHandler with the provided service.
+//
+// Parameters:
+//   - consistencyService: Service checking the settings object graph
+//
+// Returns:
+//   - A properly initialized SettingsConsistencyHandler
+func NewSettingsConsistencyHandler(consistencyService SettingsConsistencyServiceInterface) *SettingsConsistencyHandler {
+	return &SettingsConsistencyHandler{
+		consistencyService: consistencyService,
+	}
+}
+
+// CheckSettings reports the inconsistencies in the settings object graph without repairing them,
+// such as users without settings or without a ban list.
+//
+// HTTP Method:
+//   - GET
+//
+// URL Path:
+//   - /api/admin/maintenance/settings_consistency
+//
+// Requires:
+//   - Authentication: Admin role
+//
+// Responses:
+//   - 200 OK: Inconsistencies found
+//   - 401 Unauthorized: User not authenticated
+//   - 403 Forbidden: User not authorized (not admin)
+//   - 500 Internal Server Error: Server-side error
+//
+// @Summary Check settings consistency
+// @Description Counts users without settings or ban list, orphaned patterns, model entities and ban list words, and duplicates; POST to the same path repairs them
+// @Tags Admin/Maintenance
+// @Produce json
+// @Security BearerAuth
+// @Security ApiKeyAuth
+// @Success 200 {object} utils.Response{data=models.SettingsConsistencyReport} "Inconsistencies found"
+// @Failure 401 {object} utils.Response{error=string} "User not authenticated"
+// @Failure 403 {object} utils.Response{error=string} "User not authorized"
+// @Failure 500 {object} utils.Response{error=string} "Server error"
+// @Router /admin/maintenance/settings_consistency [get]
+func (h *SettingsConsistencyHandler) CheckSettings(w http.ResponseWriter, r *http.Request) {
+	report, err := h.consistencyService.Check(r.Context())
+	if err != nil {
+		utils.ErrorFromAppError(w, utils.ParseError(err))
+		return
+	}
+
+	utils.JSON(w, constants.StatusOK, report)
+}
@@ -0,0 +1,70 @@
+package handlers_test
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+
+	"github.com/yasinhessnawi1/Hideme_Backend/internal/handlers"
+	"github.com/yasinhessnawi1/Hideme_Backend/internal/models"
+	"github.com/yasinhessnawi1/Hideme_Backend/internal/testsupport/golden"
+)
+
+// MockSettingsConsistencyService is a mock implementation of the SettingsConsistencyService
+type MockSettingsConsistencyService struct {
+	mock.Mock
+}
+
+func (m *MockSettingsConsistencyService) Check(ctx context.Context) (*models.SettingsConsistencyReport, error) {
+	args := m.Called(ctx)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*models.SettingsConsistencyReport), args.Error(1)
+}
+
+func setupSettingsConsistencyTest() (*chi.Mux, *MockSettingsConsistencyService) {
+	mockService := new(MockSettingsConsistencyService)
+	handler := handlers.NewSettingsConsistencyHandler(mockService)
+
+	router := chi.NewRouter()
+	router.Get("/api/admin/maintenance/settings_consistency", handler.CheckSettings)
+
+	return router, mockService
+}
+
+func TestCheckSettingsConsistency(t *testing.T) {
+	router, mockService := setupSettingsConsistencyTest()
+
+	t.Run("Success", func(t *testing.T) {
+		mockService.On("Check", mock.Anything).
+			Return(&models.SettingsConsistencyReport{SettingsWithoutBanList: 2}, nil).Once()
+
+		req, err := http.NewRequest("GET", "/api/admin/maintenance/settings_consistency", nil)
+		require.NoError(t, err)
+		rr := httptest.NewRecorder()
+		router.ServeHTTP(rr, req)
+
+		golden.AssertResponse(t, rr)
+	})
+
+	t.Run("Service Error", func(t *testing.T) {
+		mockService.On("Check", mock.Anything).Return(nil, errors.New("connection lost")).Once()
+
+		req, err := http.NewRequest("GET", "/api/admin/maintenance/settings_consistency", nil)
+		require.NoError(t, err)
+		rr := httptest.NewRecorder()
+		router.ServeHTTP(rr, req)
+
+		assert.Equal(t, http.StatusInternalServerError, rr.Code)
+	})
+
+	mockService.AssertExpectations(t)
+}
@@ -2,16 +2,18 @@
 package handlers
 
 import (
-	"encoding/json"
+	"errors"
 	"fmt"
 	"net/http"
 	"strconv"
 	"strings"
+	"time"
 
 	"github.com/go-chi/chi/v5"
 	"github.com/yasinhessnawi1/Hideme_Backend/internal/auth"
 	"github.com/yasinhessnawi1/Hideme_Backend/internal/constants"
 	"github.com/yasinhessnawi1/Hideme_Backend/internal/models"
+	"github.com/yasinhessnawi1/Hideme_Backend/internal/service"
 	"github.com/yasinhessnawi1/Hideme_Backend/internal/utils"
 )
 
@@ -91,16 +93,17 @@ func (h *SettingsHandler) GetSettings(w http.ResponseWriter, r *http.Request) {
 //   - Authentication: User must be logged in
 //
 // Request Body:
-//   - JSON object with settings to update
+//   - JSON object with settings to update, and optionally the version they are based on
 //
 // Responses:
 //   - 200 OK: Settings updated successfully
 //   - 400 Bad Request: Invalid request body
 //   - 401 Unauthorized: User not authenticated
+//   - 409 Conflict: Settings changed since the version, with the current settings
 //   - 500 Internal Server Error: Server-side error
 //
 // @Summary Update user settings
-// @Description Updates the current user's settings
+// @Description Updates the current user's settings. With a version, the update is refused with 409 Conflict if the settings were changed since, returning the current and the submitted settings
 // @Tags Settings
 // @Accept json
 // @Produce json
@@ -109,6 +112,7 @@ func (h *SettingsHandler) GetSettings(w http.ResponseWriter, r *http.Request) {
 // @Success 200 {object} utils.Response{data=models.UserSetting} "Settings updated successfully"
 // @Failure 400 {object} utils.Response{error=string} "Invalid request body"
 // @Failure 401 {object} utils.Response{error=string} "User not authenticated"
+// @Failure 409 {object} utils.Response{data=models.SettingsConflict} "Settings changed since the version"
 // @Failure 500 {object} utils.Response{error=string} "Server error"
 // @Router /settings [put]
 func (h *SettingsHandler) UpdateSettings(w http.ResponseWriter, r *http.Request) {
@@ -129,7 +133,7 @@ func (h *SettingsHandler) UpdateSettings(w http.ResponseWriter, r *http.Request)
 	// Update the settings
 	settings, err := h.settingsService.UpdateUserSettings(r.Context(), userID, &update)
 	if err != nil {
-		utils.ErrorFromAppError(w, utils.ParseError(err))
+		settingsError(w, err)
 		return
 	}
 
@@ -137,6 +141,77 @@ func (h *SettingsHandler) UpdateSettings(w http.ResponseWriter, r *http.Request)
 	utils.JSON(w, constants.StatusOK, settings)
 }
 
+// MergeSettings merges changes made to an older version of the current user's settings.
+//
+// HTTP Method:
+//   - POST
+//
+// URL Path:
+//   - /api/settings/merge
+//
+// Requires:
+//   - Authentication: User must be logged in
+//
+// Request Body:
+//   - JSON object with the "base_version" the changes were made to and the "changes"
+//
+// Responses:
+//   - 200 OK: Settings merged successfully
+//   - 400 Bad Request: Invalid request body, or base version newer than the settings
+//   - 401 Unauthorized: User not authenticated
+//   - 409 Conflict: Fields changed to other values since, with the current settings
+//   - 500 Internal Server Error: Server-side error
+//
+// @Summary Merge user settings
+// @Description Applies changes made to an older version of the current user's settings field by field. A field changed since the base version may only be changed to its current value; otherwise the merge is refused with 409 Conflict, listing the conflicting fields
+// @Tags Settings
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param merge body models.SettingsMerge true "Changes and the version they were made to"
+// @Success 200 {object} utils.Response{data=models.UserSetting} "Settings merged successfully"
+// @Failure 400 {object} utils.Response{error=string} "Invalid request body"
+// @Failure 401 {object} utils.Response{error=string} "User not authenticated"
+// @Failure 409 {object} utils.Response{data=models.SettingsConflict} "Fields changed to other values since"
+// @Failure 500 {object} utils.Response{error=string} "Server error"
+// @Router /settings/merge [post]
+func (h *SettingsHandler) MergeSettings(w http.ResponseWriter, r *http.Request) {
+	// Get the user ID from the context
+	userID, ok := auth.GetUserID(r)
+	if !ok {
+		utils.Unauthorized(w, constants.MsgAuthRequired)
+		return
+	}
+
+	// Decode and validate the request body
+	var merge models.SettingsMerge
+	if err := utils.DecodeAndValidate(r, &merge); err != nil {
+		utils.ErrorFromAppError(w, utils.ParseError(err))
+		return
+	}
+
+	// Merge the changes
+	settings, err := h.settingsService.MergeUserSettings(r.Context(), userID, &merge)
+	if err != nil {
+		settingsError(w, err)
+		return
+	}
+
+	// Return the merged settings
+	utils.JSON(w, constants.StatusOK, settings)
+}
+
+// settingsError sends the error of a settings update, returning the conflicting
+// versions of the settings with a conflict.
+func settingsError(w http.ResponseWriter, err error) {
+	var conflict *service.SettingsConflictError
+	if errors.As(err, &conflict) {
+		utils.ConflictWithData(w, conflict.Error(), conflict.Conflict)
+		return
+	}
+	utils.ErrorFromAppError(w, utils.ParseError(err))
+}
+
 // GetBanList returns the current user's ban list.
 //
 // HTTP Method:
@@ -193,22 +268,24 @@ func (h *SettingsHandler) GetBanList(w http.ResponseWriter, r *http.Request) {
 //   - Authentication: User must be logged in
 //
 // Request Body:
-//   - JSON object with "words" array of strings
+//   - JSON object with "words" array of strings and optional "options" for matching them
 //
 // Responses:
 //   - 200 OK: Words added successfully
+//   - 207 Multi-Status: Some words were empty; the outcome of each word is in "batch"
 //   - 400 Bad Request: Invalid request body
 //   - 401 Unauthorized: User not authenticated
 //   - 500 Internal Server Error: Server-side error
 //
 // @Summary Add ban list words
-// @Description Adds words to the current user's ban list
+// @Description Adds words to the current user's ban list. With options, the words also match ignoring case or diacritics, as whole words only, or with their English or Norwegian inflections
 // @Tags Settings/Ban List
 // @Accept json
 // @Produce json
 // @Security BearerAuth
 // @Param words body models.BanListWordBatch true "Words to add to ban list"
 // @Success 200 {object} utils.Response{data=models.BanListWithWords} "Words added successfully"
+// @Success 207 {object} utils.Response{data=models.BanListWithWords} "Some words were not added"
 // @Failure 400 {object} utils.Response{error=string} "Invalid request body"
 // @Failure 401 {object} utils.Response{error=string} "User not authenticated"
 // @Failure 500 {object} utils.Response{error=string} "Server error"
@@ -228,12 +305,31 @@ func (h *SettingsHandler) AddBanListWords(w http.ResponseWriter, r *http.Request
 		return
 	}
 
+	// Report empty words on their own instead of rejecting the batch
+	var outcomes utils.BatchInfo
+	words, indexes := validBanListWords(batch.Words, &outcomes)
+	if len(words) == 0 {
+		utils.MultiStatus(w, constants.StatusOK, nil, &outcomes)
+		return
+	}
+
 	// Add the words
-	if err := h.settingsService.AddBanListWords(r.Context(), userID, batch.Words); err != nil {
+	if err := h.settingsService.AddBanListWords(r.Context(), userID, words); err != nil {
 		utils.ErrorFromAppError(w, utils.ParseError(err))
 		return
 	}
 
+	// Set how the words are matched
+	if batch.Options != nil {
+		if err := h.settingsService.SetBanListWordOptions(r.Context(), userID, words, *batch.Options); err != nil {
+			utils.ErrorFromAppError(w, utils.ParseError(err))
+			return
+		}
+	}
+	for i, word := range words {
+		outcomes.Succeed(indexes[i], word, 0, constants.StatusOK)
+	}
+
 	// Return the updated ban list
 	banList, err := h.settingsService.GetBanList(r.Context(), userID)
 	if err != nil {
@@ -241,7 +337,7 @@ func (h *SettingsHandler) AddBanListWords(w http.ResponseWriter, r *http.Request
 		return
 	}
 
-	utils.JSON(w, constants.StatusOK, banList)
+	utils.MultiStatus(w, constants.StatusOK, banList, &outcomes)
 }
 
 // RemoveBanListWords removes words from the current user's ban list.
@@ -260,6 +356,7 @@ func (h *SettingsHandler) AddBanListWords(w http.ResponseWriter, r *http.Request
 //
 // Responses:
 //   - 200 OK: Words removed successfully
+//   - 207 Multi-Status: Some words were empty or not on the ban list; the outcome of each word is in "batch"
 //   - 400 Bad Request: Invalid request body
 //   - 401 Unauthorized: User not authenticated
 //   - 500 Internal Server Error: Server-side error
@@ -272,6 +369,7 @@ func (h *SettingsHandler) AddBanListWords(w http.ResponseWriter, r *http.Request
 // @Security BearerAuth
 // @Param words body models.BanListWordBatch true "Words to remove from ban list"
 // @Success 200 {object} utils.Response{data=models.BanListWithWords} "Words removed successfully"
+// @Success 207 {object} utils.Response{data=models.BanListWithWords} "Some words were not removed"
 // @Failure 400 {object} utils.Response{error=string} "Invalid request body"
 // @Failure 401 {object} utils.Response{error=string} "User not authenticated"
 // @Failure 500 {object} utils.Response{error=string} "Server error"
@@ -291,8 +389,180 @@ func (h *SettingsHandler) RemoveBanListWords(w http.ResponseWriter, r *http.Requ
 		return
 	}
 
+	// Report empty words on their own instead of rejecting the batch
+	var outcomes utils.BatchInfo
+	words, indexes := validBanListWords(batch.Words, &outcomes)
+	if len(words) == 0 {
+		utils.MultiStatus(w, constants.StatusOK, nil, &outcomes)
+		return
+	}
+
 	// Remove the words
-	if err := h.settingsService.RemoveBanListWords(r.Context(), userID, batch.Words); err != nil {
+	missing, err := h.settingsService.RemoveBanListWords(r.Context(), userID, words)
+	if err != nil {
+		utils.ErrorFromAppError(w, utils.ParseError(err))
+		return
+	}
+	// A word given twice is removed by its first occurrence; the later ones are missing
+	notOnList := make(map[string]int, len(missing))
+	for _, word := range missing {
+		notOnList[word]++
+	}
+	for i := len(words) - 1; i >= 0; i-- {
+		if word := words[i]; notOnList[word] > 0 {
+			notOnList[word]--
+			outcomes.Fail(indexes[i], word, utils.New(utils.ErrNotFound, constants.StatusNotFound, constants.MsgBanListWordNotFound))
+		} else {
+			outcomes.Succeed(indexes[i], word, 0, constants.StatusOK)
+		}
+	}
+
+	// Return the updated ban list
+	banList, err := h.settingsService.GetBanList(r.Context(), userID)
+	if err != nil {
+		utils.ErrorFromAppError(w, utils.ParseError(err))
+		return
+	}
+
+	utils.MultiStatus(w, constants.StatusOK, banList, &outcomes)
+}
+
+// validBanListWords separates the words of a batch that can be added or removed from
+// the empty ones, which are recorded as failed.
+//
+// Parameters:
+//   - words: The words of the batch
+//   - outcomes: The outcomes of the batch, to which the empty words are added
+//
+// Returns:
+//   - The non-empty words
+//   - The position of each of them in the batch
+func validBanListWords(words []string, outcomes *utils.BatchInfo) ([]string, []int) {
+	valid := make([]string, 0, len(words))
+	indexes := make([]int, 0, len(words))
+	for i, word := range words {
+		if word == "" {
+			outcomes.Fail(i, word, utils.NewValidationError("words", constants.MsgBanListWordEmpty))
+			continue
+		}
+		valid = append(valid, word)
+		indexes = append(indexes, i)
+	}
+	return valid, indexes
+}
+
+// SetBanListOptions sets how all words on the current user's ban list are matched.
+//
+// HTTP Method:
+//   - PATCH
+//
+// URL Path:
+//   - /api/settings/ban-list
+//
+// Requires:
+//   - Authentication: User must be logged in
+//
+// Request Body:
+//   - JSON object with the "case_insensitive", "diacritic_insensitive" and "whole_word" options to set
+//
+// Responses:
+//   - 200 OK: Options set
+//   - 400 Bad Request: Invalid request body
+//   - 401 Unauthorized: User not authenticated
+//   - 500 Internal Server Error: Server-side error
+//
+// @Summary Set ban list options
+// @Description Sets how all words on the current user's ban list are matched: ignoring case or diacritics, or as whole words only. Each option applies on top of the options of each word; omitted options are kept
+// @Tags Settings/Ban List
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param options body models.BanListOptionsUpdate true "Ban list options"
+// @Success 200 {object} utils.Response{data=models.BanListWithWords} "Options set"
+// @Failure 400 {object} utils.Response{error=string} "Invalid request body"
+// @Failure 401 {object} utils.Response{error=string} "User not authenticated"
+// @Failure 500 {object} utils.Response{error=string} "Server error"
+// @Router /settings/ban-list [patch]
+func (h *SettingsHandler) SetBanListOptions(w http.ResponseWriter, r *http.Request) {
+	// Get the user ID from the context
+	userID, ok := auth.GetUserID(r)
+	if !ok {
+		utils.Unauthorized(w, constants.MsgAuthRequired)
+		return
+	}
+
+	// Decode and validate the request body
+	var update models.BanListOptionsUpdate
+	if err := utils.DecodeAndValidate(r, &update); err != nil {
+		utils.ErrorFromAppError(w, utils.ParseError(err))
+		return
+	}
+
+	// Set the options
+	if err := h.settingsService.SetBanListOptions(r.Context(), userID, &update); err != nil {
+		utils.ErrorFromAppError(w, utils.ParseError(err))
+		return
+	}
+
+	// Return the updated ban list
+	banList, err := h.settingsService.GetBanList(r.Context(), userID)
+	if err != nil {
+		utils.ErrorFromAppError(w, utils.ParseError(err))
+		return
+	}
+
+	utils.JSON(w, constants.StatusOK, banList)
+}
+
+// SetBanListWordOptions sets how words on the current user's ban list are matched.
+//
+// HTTP Method:
+//   - PATCH
+//
+// URL Path:
+//   - /api/settings/ban-list/words
+//
+// Requires:
+//   - Authentication: User must be logged in
+//
+// Request Body:
+//   - JSON object with "words" array of strings and the "options" to match them with
+//
+// Responses:
+//   - 200 OK: Options set
+//   - 400 Bad Request: Invalid request body or stemming language
+//   - 401 Unauthorized: User not authenticated
+//   - 500 Internal Server Error: Server-side error
+//
+// @Summary Set ban list word options
+// @Description Sets how words on the current user's ban list are matched: ignoring case or diacritics, as whole words only, or with their English (en) or Norwegian (no) inflections. Words not on the ban list are ignored
+// @Tags Settings/Ban List
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param options body models.BanListWordOptionsUpdate true "Words and their options"
+// @Success 200 {object} utils.Response{data=models.BanListWithWords} "Options set"
+// @Failure 400 {object} utils.Response{error=string} "Invalid request body"
+// @Failure 401 {object} utils.Response{error=string} "User not authenticated"
+// @Failure 500 {object} utils.Response{error=string} "Server error"
+// @Router /settings/ban-list/words [patch]
+func (h *SettingsHandler) SetBanListWordOptions(w http.ResponseWriter, r *http.Request) {
+	// Get the user ID from the context
+	userID, ok := auth.GetUserID(r)
+	if !ok {
+		utils.Unauthorized(w, constants.MsgAuthRequired)
+		return
+	}
+
+	// Decode and validate the request body
+	var update models.BanListWordOptionsUpdate
+	if err := utils.DecodeAndValidate(r, &update); err != nil {
+		utils.ErrorFromAppError(w, utils.ParseError(err))
+		return
+	}
+
+	// Set the options
+	if err := h.settingsService.SetBanListWordOptions(r.Context(), userID, update.Words, update.Options); err != nil {
 		utils.ErrorFromAppError(w, utils.ParseError(err))
 		return
 	}
@@ -348,7 +618,7 @@ func (h *SettingsHandler) GetSearchPatterns(w http.ResponseWriter, r *http.Reque
 	}
 
 	// Return the search patterns
-	utils.JSON(w, constants.StatusOK, patterns)
+	utils.ListAll(w, r, patterns)
 }
 
 // CreateSearchPattern creates a new search pattern for the current user.
@@ -409,6 +679,169 @@ func (h *SettingsHandler) CreateSearchPattern(w http.ResponseWriter, r *http.Req
 	utils.JSON(w, constants.StatusCreated, newPattern)
 }
 
+// TestSearchPattern tries out a search pattern on sample text, so users see what it
+// matches before they save it.
+//
+// HTTP Method:
+//   - POST
+//
+// URL Path:
+//   - /api/settings/patterns/test
+//
+// Requires:
+//   - Authentication: User must be logged in
+//
+// Request Body:
+//   - JSON object conforming to models.SearchPatternTest
+//
+// Responses:
+//   - 200 OK: Matches of the pattern in the sample text
+//   - 400 Bad Request: Invalid request body, AI search pattern, or invalid or unsafe regular expression
+//   - 401 Unauthorized: User not authenticated
+//
+// @Summary Test search pattern
+// @Description Runs a search pattern against sample text and returns its matches, at most 100; offsets are in characters
+// @Tags Settings/Patterns
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param test body models.SearchPatternTest true "Pattern and sample text"
+// @Success 200 {object} utils.Response{data=models.SearchPatternTestResult} "Matches of the pattern"
+// @Failure 400 {object} utils.Response{error=string} "Invalid request body or pattern"
+// @Failure 401 {object} utils.Response{error=string} "User not authenticated"
+// @Router /settings/patterns/test [post]
+func (h *SettingsHandler) TestSearchPattern(w http.ResponseWriter, r *http.Request) {
+	// Only signed-in users may try out patterns
+	if _, ok := auth.GetUserID(r); !ok {
+		utils.Unauthorized(w, constants.MsgAuthRequired)
+		return
+	}
+
+	// Decode and validate the request body
+	var test models.SearchPatternTest
+	if err := utils.DecodeAndValidate(r, &test); err != nil {
+		utils.ErrorFromAppError(w, utils.ParseError(err))
+		return
+	}
+
+	// Try out the pattern
+	result, err := h.settingsService.TestSearchPattern(&test)
+	if err != nil {
+		utils.ErrorFromAppError(w, utils.ParseError(err))
+		return
+	}
+
+	utils.JSON(w, constants.StatusOK, result)
+}
+
+// GetSearchPatternCatalog returns the catalog of search patterns for common kinds of
+// personal data, such as national identity numbers, IBANs and phone numbers.
+//
+// HTTP Method:
+//   - GET
+//
+// URL Path:
+//   - /api/settings/patterns/catalog
+//
+// Requires:
+//   - Authentication: User must be logged in
+//
+// Responses:
+//   - 200 OK: Catalog patterns
+//   - 401 Unauthorized: User not authenticated
+//
+// @Summary Get search pattern catalog
+// @Description Returns the catalog of search patterns for common kinds of personal data, which can be copied into the user's settings
+// @Tags Settings/Patterns
+// @Produce json
+// @Security BearerAuth
+// @Success 200 {object} utils.Response{data=[]models.SearchPatternCatalogEntry} "Catalog patterns"
+// @Failure 401 {object} utils.Response{error=string} "User not authenticated"
+// @Router /settings/patterns/catalog [get]
+func (h *SettingsHandler) GetSearchPatternCatalog(w http.ResponseWriter, r *http.Request) {
+	if _, ok := auth.GetUserID(r); !ok {
+		utils.Unauthorized(w, constants.MsgAuthRequired)
+		return
+	}
+
+	utils.ListAll(w, r, models.SearchPatternCatalog())
+}
+
+// ImportCatalogPatterns copies patterns from the catalog into the current user's settings.
+//
+// HTTP Method:
+//   - POST
+//
+// URL Path:
+//   - /api/settings/patterns/import-catalog
+//
+// Requires:
+//   - Authentication: User must be logged in
+//
+// Request Body:
+//   - JSON object conforming to models.SearchPatternCatalogImport
+//
+// Responses:
+//   - 200 OK: Patterns copied, or already in the user's settings
+//   - 207 Multi-Status: Some keys are not in the catalog; the outcome of each key is in "batch"
+//   - 400 Bad Request: Invalid request body
+//   - 401 Unauthorized: User not authenticated
+//   - 500 Internal Server Error: Server-side error
+//
+// @Summary Import catalog patterns
+// @Description Copies catalog patterns into the current user's search patterns. Patterns the user already has are not copied again; their items have status 200 instead of 201
+// @Tags Settings/Patterns
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param keys body models.SearchPatternCatalogImport true "Keys of the catalog patterns to copy"
+// @Success 200 {object} utils.Response{data=[]models.SearchPattern} "Patterns copied"
+// @Success 207 {object} utils.Response{data=[]models.SearchPattern} "Some patterns were not copied"
+// @Failure 400 {object} utils.Response{error=string} "Invalid request body"
+// @Failure 401 {object} utils.Response{error=string} "User not authenticated"
+// @Failure 500 {object} utils.Response{error=string} "Server error"
+// @Router /settings/patterns/import-catalog [post]
+func (h *SettingsHandler) ImportCatalogPatterns(w http.ResponseWriter, r *http.Request) {
+	// Get the user ID from the context
+	userID, ok := auth.GetUserID(r)
+	if !ok {
+		utils.Unauthorized(w, constants.MsgAuthRequired)
+		return
+	}
+
+	// Decode and validate the request body
+	var req models.SearchPatternCatalogImport
+	if err := utils.DecodeAndValidate(r, &req); err != nil {
+		utils.ErrorFromAppError(w, utils.ParseError(err))
+		return
+	}
+
+	// Copy the patterns
+	imports, err := h.settingsService.ImportCatalogPatterns(r.Context(), userID, req.Keys)
+	if err != nil {
+		utils.ErrorFromAppError(w, utils.ParseError(err))
+		return
+	}
+
+	// Report each key, and return the user's copies of the patterns
+	var outcomes utils.BatchInfo
+	patterns := make([]*models.SearchPattern, 0, len(imports))
+	for i, imported := range imports {
+		if imported.Err != nil {
+			outcomes.Fail(i, imported.Key, imported.Err)
+			continue
+		}
+		status := constants.StatusOK
+		if imported.Created {
+			status = constants.StatusCreated
+		}
+		outcomes.Succeed(i, imported.Key, imported.Pattern.ID, status)
+		patterns = append(patterns, imported.Pattern)
+	}
+
+	utils.MultiStatus(w, constants.StatusOK, patterns, &outcomes)
+}
+
 // UpdateSearchPattern updates an existing search pattern.
 //
 // HTTP Method:
@@ -551,6 +984,10 @@ func (h *SettingsHandler) DeleteSearchPattern(w http.ResponseWriter, r *http.Req
 // URL Parameters:
 //   - method_id: The ID of the method to get entities for
 //
+// Headers:
+//   - Accept: application/x-ndjson streams the entities one JSON object per line
+//     instead of returning them in a single response
+//
 // Requires:
 //   - Authentication: User must be logged in
 //
@@ -563,9 +1000,10 @@ func (h *SettingsHandler) DeleteSearchPattern(w http.ResponseWriter, r *http.Req
 // @Summary Get model entities
 // @Description Returns the model entities for a specific method
 // @Tags Settings/Entities
-// @Produce json
+// @Produce json,application/x-ndjson,xml
 // @Security BearerAuth
 // @Param methodID path int true "ID of the method to get entities for"
+// @Param Accept header string false "application/x-ndjson to stream the entities, application/xml for XML"
 // @Success 200 {object} utils.Response{data=[]models.ModelEntityWithMethod} "Entities retrieved successfully"
 // @Failure 400 {object} utils.Response{error=string} "Invalid method ID"
 // @Failure 401 {object} utils.Response{error=string} "User not authenticated"
@@ -587,6 +1025,15 @@ func (h *SettingsHandler) GetModelEntities(w http.ResponseWriter, r *http.Reques
 		return
 	}
 
+	// Stream the entities as they are read if the client asked for NDJSON
+	if utils.WantsNDJSON(r) {
+		stream := utils.NewNDJSONStream(w)
+		stream.Finish(h.settingsService.StreamModelEntities(r.Context(), userID, methodID, func(entity *models.ModelEntityWithMethod) error {
+			return stream.Write(entity)
+		}))
+		return
+	}
+
 	// Get the entities
 	entities, err := h.settingsService.GetModelEntities(r.Context(), userID, methodID)
 	if err != nil {
@@ -594,8 +1041,12 @@ func (h *SettingsHandler) GetModelEntities(w http.ResponseWriter, r *http.Reques
 		return
 	}
 
-	// Return the entities
-	utils.JSON(w, constants.StatusOK, entities)
+	// Return the entities, as XML if the client asked for it
+	if utils.WantsXML(r) {
+		utils.XML(w, constants.StatusOK, entities)
+		return
+	}
+	utils.ListAll(w, r, entities)
 }
 
 // AddModelEntities adds entities for a specific detection method.
@@ -772,7 +1223,128 @@ func (h *SettingsHandler) DeleteModelEntityByMethodID(w http.ResponseWriter, r *
 	utils.NoContent(w)
 }
 
-// ExportSettings exports all user settings as a JSON file.
+// ReplaceModelEntities replaces all model entities of a detection method with the list in
+// the request, in one transaction. Entities whose text is on both lists keep their IDs, so
+// clients syncing the full list don't need to delete the method's entities and add them again.
+//
+// HTTP Method:
+//   - PUT
+//
+// URL Path:
+//   - /api/settings/entities/{methodID}
+//
+// URL Parameters:
+//   - methodID: The ID of the method to replace entities for
+//
+// Requires:
+//   - Authentication: User must be logged in
+//
+// Request Body:
+//   - JSON object conforming to models.ModelEntityReplace
+//
+// Responses:
+//   - 200 OK: The entities after the replacement and those created and deleted
+//   - 400 Bad Request: Invalid method ID or request body
+//   - 401 Unauthorized: User not authenticated
+//   - 500 Internal Server Error: Server-side error
+//
+// @Summary Replace model entities by method
+// @Description Replaces all model entities for a specific method in one transaction
+// @Tags Settings/Entities
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param methodID path int true "ID of the method to replace entities for"
+// @Param entities body models.ModelEntityReplace true "Entities after the replacement"
+// @Success 200 {object} utils.Response{data=models.ModelEntityReplacement} "Entities replaced successfully"
+// @Failure 400 {object} utils.Response{error=string} "Invalid method ID or request body"
+// @Failure 401 {object} utils.Response{error=string} "User not authenticated"
+// @Failure 500 {object} utils.Response{error=string} "Server error"
+// @Router /settings/entities/{methodID} [put]
+func (h *SettingsHandler) ReplaceModelEntities(w http.ResponseWriter, r *http.Request) {
+	// Get the user ID from the context
+	userID, ok := auth.GetUserID(r)
+	if !ok {
+		utils.Unauthorized(w, constants.MsgAuthRequired)
+		return
+	}
+
+	// Get the method ID from the URL
+	methodID, err := strconv.ParseInt(chi.URLParam(r, constants.ParamMethodID), 10, 64)
+	if err != nil {
+		utils.BadRequest(w, "Invalid method ID", nil)
+		return
+	}
+
+	// Decode and validate the request body
+	var replace models.ModelEntityReplace
+	if err := utils.DecodeAndValidate(r, &replace); err != nil {
+		utils.ErrorFromAppError(w, utils.ParseError(err))
+		return
+	}
+
+	// Replace the entities
+	replacement, err := h.settingsService.ReplaceModelEntities(r.Context(), userID, methodID, &replace)
+	if err != nil {
+		utils.ErrorFromAppError(w, utils.ParseError(err))
+		return
+	}
+
+	utils.JSON(w, constants.StatusOK, replacement)
+}
+
+// GetDetectionConfig returns the user's general settings, ban list, search patterns and
+// model entities in one response, as the detection service needs them for every document.
+// The X-Cache header tells whether the configuration was served from the cache.
+//
+// HTTP Method:
+//   - GET
+//
+// URL Path:
+//   - /api/settings/detection-config
+//
+// Requires:
+//   - Authentication: User must be logged in
+//
+// Responses:
+//   - 200 OK: The detection configuration
+//   - 401 Unauthorized: User not authenticated
+//   - 500 Internal Server Error: Server-side error
+//
+// @Summary Get detection configuration
+// @Description Returns the general settings, ban list, search patterns and model entities used for detection, cached per user
+// @Tags Settings
+// @Produce json
+// @Security BearerAuth
+// @Success 200 {object} utils.Response{data=models.DetectionConfig} "The detection configuration"
+// @Header 200 {string} X-Cache "hit or miss"
+// @Failure 401 {object} utils.Response{error=string} "User not authenticated"
+// @Failure 500 {object} utils.Response{error=string} "Server error"
+// @Router /settings/detection-config [get]
+func (h *SettingsHandler) GetDetectionConfig(w http.ResponseWriter, r *http.Request) {
+	// Get the user ID from the context
+	userID, ok := auth.GetUserID(r)
+	if !ok {
+		utils.Unauthorized(w, constants.MsgAuthRequired)
+		return
+	}
+
+	detectionConfig, cached, err := h.settingsService.GetDetectionConfig(r.Context(), userID)
+	if err != nil {
+		utils.ErrorFromAppError(w, utils.ParseError(err))
+		return
+	}
+
+	if cached {
+		w.Header().Set(constants.HeaderXCache, "hit")
+	} else {
+		w.Header().Set(constants.HeaderXCache, "miss")
+	}
+	utils.JSON(w, constants.StatusOK, detectionConfig)
+}
+
+// ExportSettings exports all user settings as a JSON file, or as an XML file
+// when the client asks for XML.
 // This allows users to backup their settings or transfer them to another account.
 //
 // HTTP Method:
@@ -785,16 +1357,17 @@ func (h *SettingsHandler) DeleteModelEntityByMethodID(w http.ResponseWriter, r *
 //   - Authentication: User must be logged in
 //
 // Responses:
-//   - 200 OK: Settings exported successfully as a downloadable JSON file
+//   - 200 OK: Settings exported successfully as a downloadable JSON or XML file
 //   - 401 Unauthorized: User not authenticated
 //   - 500 Internal Server Error: Server-side error
 //
 // @Summary Export settings
-// @Description Exports all user settings as a JSON file
+// @Description Exports all user settings as a JSON file, or as an XML file with "Accept: application/xml"
 // @Tags Settings
-// @Produce json
+// @Produce json,xml
 // @Security BearerAuth
-// @Success 200 {file} byte[] "Settings exported successfully as a downloadable JSON file"
+// @Param Accept header string false "application/xml to export the settings as XML"
+// @Success 200 {file} byte[] "Settings exported successfully as a downloadable JSON or XML file"
 // @Failure 401 {object} utils.Response{error=string} "User not authenticated"
 // @Failure 500 {object} utils.Response{error=string} "Server error"
 // @Router /settings/export [get]
@@ -827,9 +1400,15 @@ func (h *SettingsHandler) ExportSettings(w http.ResponseWriter, r *http.Request)
 			return '_'
 		}, username)
 
-		filename = fmt.Sprintf("%s_settings.json", safeUsername)
+		filename = fmt.Sprintf("%s_settings", safeUsername)
 	} else {
-		filename = fmt.Sprintf("user_%d_settings.json", userID)
+		filename = fmt.Sprintf("user_%d_settings", userID)
+	}
+
+	// Send as XML file if the client asked for it
+	if utils.WantsXML(r) {
+		utils.XMLFile(w, constants.XMLRootSettingsExport, settingsExport, filename)
+		return
 	}
 
 	// Use the JsonFile method to send as downloadable file
@@ -837,7 +1416,8 @@ func (h *SettingsHandler) ExportSettings(w http.ResponseWriter, r *http.Request)
 }
 
 // ImportSettings imports user settings from a JSON file.
-// This allows users to restore previously exported settings.
+// This allows users to restore previously exported settings. Exports of older
+// versions are upgraded before they are imported; exports of newer versions are rejected.
 //
 // HTTP Method:
 //   - POST
@@ -852,7 +1432,7 @@ func (h *SettingsHandler) ExportSettings(w http.ResponseWriter, r *http.Request)
 //
 // Responses:
 //   - 200 OK: Settings imported successfully
-//   - 400 Bad Request: Invalid file format or content
+//   - 400 Bad Request: Invalid file format or content, or export version newer than supported
 //   - 401 Unauthorized: User not authenticated
 //   - 500 Internal Server Error: Server-side error
 //
@@ -862,14 +1442,14 @@ func (h *SettingsHandler) ExportSettings(w http.ResponseWriter, r *http.Request)
 //   - The imported settings are validated before being applied
 //
 // @Summary Import settings
-// @Description Imports user settings from a JSON file
+// @Description Imports user settings from a JSON file; exports of older versions are upgraded
 // @Tags Settings
 // @Accept multipart/form-data
 // @Produce json
 // @Security BearerAuth
 // @Param settings formData file true "JSON file with settings to import"
 // @Success 200 {object} utils.Response{data=map[string]string} "Settings imported successfully"
-// @Failure 400 {object} utils.Response{error=string} "Invalid file format or content"
+// @Failure 400 {object} utils.Response{error=string} "Invalid file format or content, or unsupported export version"
 // @Failure 401 {object} utils.Response{error=string} "User not authenticated"
 // @Failure 500 {object} utils.Response{error=string} "Server error"
 // @Router /settings/import [post]
@@ -905,11 +1485,10 @@ func (h *SettingsHandler) ImportSettings(w http.ResponseWriter, r *http.Request)
 		return
 	}
 
-	// Read and parse the settings JSON
-	var settingsExport models.SettingsExport
-	decoder := json.NewDecoder(file)
-	if err := decoder.Decode(&settingsExport); err != nil {
-		utils.BadRequest(w, "Invalid JSON format: "+err.Error(), nil)
+	// Read the settings JSON, upgrading exports made by older releases
+	settingsExport, err := models.ParseSettingsExport(file)
+	if err != nil {
+		utils.BadRequest(w, "Invalid settings file: "+err.Error(), nil)
 		return
 	}
 
@@ -929,7 +1508,7 @@ func (h *SettingsHandler) ImportSettings(w http.ResponseWriter, r *http.Request)
 	}
 
 	// Import settings
-	if err := h.settingsService.ImportSettings(r.Context(), userID, &settingsExport); err != nil {
+	if err := h.settingsService.ImportSettings(r.Context(), userID, settingsExport); err != nil {
 		utils.ErrorFromAppError(w, utils.ParseError(err))
 		return
 	}
@@ -939,3 +1518,88 @@ func (h *SettingsHandler) ImportSettings(w http.ResponseWriter, r *http.Request)
 		"message": constants.MsgSettingsImported,
 	})
 }
+
+// GetSettingsChanges returns the settings changes made after a given revision.
+// Clients keep the latest_revision from each response and pass it as since on the
+// next call. When wait is set and there are no new changes, the request is held open
+// until a change arrives or the wait time elapses.
+//
+// HTTP Method:
+//   - GET
+//
+// URL Path:
+//   - /api/settings/changes
+//
+// Query Parameters:
+//   - since: The last revision the client has applied (default 0)
+//   - wait: Seconds to wait for new changes (default 0, capped at 30)
+//
+// Requires:
+//   - Authentication: User must be logged in
+//
+// Responses:
+//   - 200 OK: Changes retrieved successfully (possibly empty)
+//   - 400 Bad Request: Invalid since or wait parameter
+//   - 401 Unauthorized: User not authenticated
+//   - 500 Internal Server Error: Server-side error
+//
+// @Summary Get settings changes
+// @Description Returns settings changes after a given revision, optionally long-polling for new ones
+// @Tags Settings
+// @Produce json
+// @Security BearerAuth
+// @Param since query int false "Last revision the client has applied" default(0)
+// @Param wait query int false "Seconds to wait for new changes (max 30)" default(0)
+// @Success 200 {object} utils.Response{data=models.SettingsChangeFeed} "Changes retrieved successfully"
+// @Failure 400 {object} utils.Response{error=string} "Invalid query parameters"
+// @Failure 401 {object} utils.Response{error=string} "User not authenticated"
+// @Failure 500 {object} utils.Response{error=string} "Server error"
+// @Router /settings/changes [get]
+func (h *SettingsHandler) GetSettingsChanges(w http.ResponseWriter, r *http.Request) {
+	// Get the user ID from the context
+	userID, ok := auth.GetUserID(r)
+	if !ok {
+		utils.Unauthorized(w, constants.MsgAuthRequired)
+		return
+	}
+
+	// Parse the revision to start after
+	var since int64
+	if sinceStr := r.URL.Query().Get(constants.QueryParamSince); sinceStr != "" {
+		parsed, err := strconv.ParseInt(sinceStr, 10, 64)
+		if err != nil || parsed < 0 {
+			utils.BadRequest(w, "Invalid since parameter", nil)
+			return
+		}
+		since = parsed
+	}
+
+	// Parse the long-poll wait time
+	var wait time.Duration
+	if waitStr := r.URL.Query().Get(constants.QueryParamWait); waitStr != "" {
+		seconds, err := strconv.Atoi(waitStr)
+		if err != nil || seconds < 0 {
+			utils.BadRequest(w, "Invalid wait parameter", nil)
+			return
+		}
+		wait = time.Duration(seconds) * time.Second
+		if wait > constants.MaxSettingsChangesWait {
+			wait = constants.MaxSettingsChangesWait
+		}
+	}
+
+	// Make sure the server write timeout does not cut off a long-poll
+	if wait > 0 {
+		_ = http.NewResponseController(w).SetWriteDeadline(time.Now().Add(wait + constants.DefaultWriteTimeout))
+	}
+
+	// Get the changes
+	feed, err := h.settingsService.GetSettingsChanges(r.Context(), userID, since, constants.MaxSettingsChangesPerPage, wait)
+	if err != nil {
+		utils.ErrorFromAppError(w, utils.ParseError(err))
+		return
+	}
+
+	// Return the changes
+	utils.JSON(w, constants.StatusOK, feed)
+}
@@ -9,7 +9,9 @@ import (
 	"net/http"
 	"net/http/httptest"
 	"strconv"
+	"strings"
 	"testing"
+	"time"
 
 	"github.com/go-chi/chi/v5"
 	"github.com/stretchr/testify/assert"
@@ -17,8 +19,13 @@ import (
 	"github.com/stretchr/testify/require"
 
 	"github.com/yasinhessnawi1/Hideme_Backend/internal/auth"
+	"github.com/yasinhessnawi1/Hideme_Backend/internal/constants"
 	"github.com/yasinhessnawi1/Hideme_Backend/internal/handlers"
 	"github.com/yasinhessnawi1/Hideme_Backend/internal/models"
+	"github.com/yasinhessnawi1/Hideme_Backend/internal/service"
+	"github.com/yasinhessnawi1/Hideme_Backend/internal/testsupport/fixtures"
+	"github.com/yasinhessnawi1/Hideme_Backend/internal/testsupport/golden"
+	"github.com/yasinhessnawi1/Hideme_Backend/internal/utils"
 )
 
 // MockSettingsService is a mock implementation of the SettingsService
@@ -42,6 +49,14 @@ func (m *MockSettingsService) UpdateUserSettings(ctx context.Context, userID int
 	return args.Get(0).(*models.UserSetting), args.Error(1)
 }
 
+func (m *MockSettingsService) MergeUserSettings(ctx context.Context, userID int64, merge *models.SettingsMerge) (*models.UserSetting, error) {
+	args := m.Called(ctx, userID, merge)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*models.UserSetting), args.Error(1)
+}
+
 func (m *MockSettingsService) GetBanList(ctx context.Context, userID int64) (*models.BanListWithWords, error) {
 	args := m.Called(ctx, userID)
 	if args.Get(0) == nil {
@@ -55,8 +70,21 @@ func (m *MockSettingsService) AddBanListWords(ctx context.Context, userID int64,
 	return args.Error(0)
 }
 
-func (m *MockSettingsService) RemoveBanListWords(ctx context.Context, userID int64, words []string) error {
+func (m *MockSettingsService) RemoveBanListWords(ctx context.Context, userID int64, words []string) ([]string, error) {
 	args := m.Called(ctx, userID, words)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]string), args.Error(1)
+}
+
+func (m *MockSettingsService) SetBanListOptions(ctx context.Context, userID int64, update *models.BanListOptionsUpdate) error {
+	args := m.Called(ctx, userID, update)
+	return args.Error(0)
+}
+
+func (m *MockSettingsService) SetBanListWordOptions(ctx context.Context, userID int64, words []string, options models.BanListMatchOptions) error {
+	args := m.Called(ctx, userID, words, options)
 	return args.Error(0)
 }
 
@@ -84,6 +112,22 @@ func (m *MockSettingsService) UpdateSearchPattern(ctx context.Context, userID in
 	return args.Get(0).(*models.SearchPattern), args.Error(1)
 }
 
+func (m *MockSettingsService) TestSearchPattern(test *models.SearchPatternTest) (*models.SearchPatternTestResult, error) {
+	args := m.Called(test)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*models.SearchPatternTestResult), args.Error(1)
+}
+
+func (m *MockSettingsService) ImportCatalogPatterns(ctx context.Context, userID int64, keys []string) ([]models.SearchPatternImport, error) {
+	args := m.Called(ctx, userID, keys)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]models.SearchPatternImport), args.Error(1)
+}
+
 func (m *MockSettingsService) DeleteSearchPattern(ctx context.Context, userID int64, patternID int64) error {
 	args := m.Called(ctx, userID, patternID)
 	return args.Error(0)
@@ -97,6 +141,18 @@ func (m *MockSettingsService) GetModelEntities(ctx context.Context, userID int64
 	return args.Get(0).([]*models.ModelEntityWithMethod), args.Error(1)
 }
 
+func (m *MockSettingsService) StreamModelEntities(ctx context.Context, userID int64, methodID int64, fn func(*models.ModelEntityWithMethod) error) error {
+	args := m.Called(ctx, userID, methodID)
+	if entities, ok := args.Get(0).([]*models.ModelEntityWithMethod); ok {
+		for _, entity := range entities {
+			if err := fn(entity); err != nil {
+				return err
+			}
+		}
+	}
+	return args.Error(1)
+}
+
 func (m *MockSettingsService) AddModelEntities(ctx context.Context, userID int64, batch *models.ModelEntityBatch) ([]*models.ModelEntity, error) {
 	args := m.Called(ctx, userID, batch)
 	if args.Get(0) == nil {
@@ -115,6 +171,14 @@ func (m *MockSettingsService) DeleteModelEntityByMethodID(ctx context.Context, u
 	return args.Error(0)
 }
 
+func (m *MockSettingsService) ReplaceModelEntities(ctx context.Context, userID int64, methodID int64, replace *models.ModelEntityReplace) (*models.ModelEntityReplacement, error) {
+	args := m.Called(ctx, userID, methodID, replace)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*models.ModelEntityReplacement), args.Error(1)
+}
+
 // Add to your MockSettingsService struct
 func (m *MockSettingsService) ExportSettings(ctx context.Context, userID int64) (*models.SettingsExport, error) {
 	// For tests, you can return a simple implementation or nil
@@ -125,11 +189,27 @@ func (m *MockSettingsService) ExportSettings(ctx context.Context, userID int64)
 	return args.Get(0).(*models.SettingsExport), args.Error(1)
 }
 
+func (m *MockSettingsService) GetDetectionConfig(ctx context.Context, userID int64) (*models.DetectionConfig, bool, error) {
+	args := m.Called(ctx, userID)
+	if args.Get(0) == nil {
+		return nil, false, args.Error(2)
+	}
+	return args.Get(0).(*models.DetectionConfig), args.Bool(1), args.Error(2)
+}
+
 func (m *MockSettingsService) ImportSettings(ctx context.Context, userID int64, importData *models.SettingsExport) error {
 	args := m.Called(ctx, userID, importData)
 	return args.Error(0)
 }
 
+func (m *MockSettingsService) GetSettingsChanges(ctx context.Context, userID int64, since int64, limit int, wait time.Duration) (*models.SettingsChangeFeed, error) {
+	args := m.Called(ctx, userID, since, limit, wait)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*models.SettingsChangeFeed), args.Error(1)
+}
+
 // Helper functions for testing
 func setupSettingsTest(t *testing.T) (*handlers.SettingsHandler, *MockSettingsService) {
 	mockService := new(MockSettingsService)
@@ -149,15 +229,9 @@ func TestGetSettings(t *testing.T) {
 	// Test successful case
 	t.Run("Success", func(t *testing.T) {
 		// Create expected settings
-		expectedSettings := &models.UserSetting{
-			ID:                     1,
-			UserID:                 1001,
-			RemoveImages:           true,
-			Theme:                  "dark",
-			AutoProcessing:         true,
-			DetectionThreshold:     0.75,
-			UseBanlistForDetection: true,
-		}
+		expectedSettings := fixtures.Settings(1001)
+		expectedSettings.Theme = "dark"
+		expectedSettings.DetectionThreshold = 0.75
 
 		// Setup mock service
 		mockService.On("GetUserSettings", mock.Anything, int64(1001)).Return(expectedSettings, nil).Once()
@@ -174,26 +248,7 @@ func TestGetSettings(t *testing.T) {
 		handler.GetSettings(rr, req)
 
 		// Verify response
-		assert.Equal(t, http.StatusOK, rr.Code)
-
-		// Define wrapper for the response envelope
-		var responseWrapper struct {
-			Success bool               `json:"success"`
-			Data    models.UserSetting `json:"data"`
-		}
-
-		// Parse response body into the wrapper
-		err = json.Unmarshal(rr.Body.Bytes(), &responseWrapper)
-		require.NoError(t, err)
-
-		// Verify response content using the data field from the wrapper
-		assert.Equal(t, expectedSettings.ID, responseWrapper.Data.ID)
-		assert.Equal(t, expectedSettings.UserID, responseWrapper.Data.UserID)
-		assert.Equal(t, expectedSettings.RemoveImages, responseWrapper.Data.RemoveImages)
-		assert.Equal(t, expectedSettings.Theme, responseWrapper.Data.Theme)
-		assert.Equal(t, expectedSettings.AutoProcessing, responseWrapper.Data.AutoProcessing)
-		assert.Equal(t, expectedSettings.DetectionThreshold, responseWrapper.Data.DetectionThreshold)
-		assert.Equal(t, expectedSettings.UseBanlistForDetection, responseWrapper.Data.UseBanlistForDetection)
+		golden.AssertResponse(t, rr)
 
 		// Verify mock expectations
 		mockService.AssertExpectations(t)
@@ -211,7 +266,7 @@ func TestGetSettings(t *testing.T) {
 		handler.GetSettings(rr, req)
 
 		// Verify response
-		assert.Equal(t, http.StatusUnauthorized, rr.Code)
+		golden.AssertResponse(t, rr)
 	})
 
 	t.Run("Service Error", func(t *testing.T) {
@@ -294,27 +349,7 @@ func TestUpdateSettings(t *testing.T) {
 		// Call the handler
 		handler.UpdateSettings(rr, req)
 
-		// Verify response
-		assert.Equal(t, http.StatusOK, rr.Code)
-
-		// Define wrapper for the response envelope
-		var responseWrapper struct {
-			Success bool               `json:"success"`
-			Data    models.UserSetting `json:"data"`
-		}
-
-		// Parse response body into the wrapper
-		err = json.Unmarshal(rr.Body.Bytes(), &responseWrapper)
-		require.NoError(t, err)
-
-		// Verify response content using the data field from the wrapper
-		assert.Equal(t, expectedSettings.ID, responseWrapper.Data.ID)
-		assert.Equal(t, expectedSettings.UserID, responseWrapper.Data.UserID)
-		assert.Equal(t, expectedSettings.RemoveImages, responseWrapper.Data.RemoveImages)
-		assert.Equal(t, expectedSettings.Theme, responseWrapper.Data.Theme)
-		assert.Equal(t, expectedSettings.AutoProcessing, responseWrapper.Data.AutoProcessing)
-		assert.Equal(t, expectedSettings.DetectionThreshold, responseWrapper.Data.DetectionThreshold)
-		assert.Equal(t, expectedSettings.UseBanlistForDetection, responseWrapper.Data.UseBanlistForDetection)
+		golden.AssertResponse(t, rr)
 
 		// Verify mock expectations
 		mockService.AssertExpectations(t)
@@ -397,6 +432,120 @@ func TestUpdateSettings(t *testing.T) {
 		// Verify mock expectations
 		mockService.AssertExpectations(t)
 	})
+
+	t.Run("Version Conflict", func(t *testing.T) {
+		// Settings changed to version 4 on another device
+		current := fixtures.Settings(1001)
+		current.Theme = "dark"
+		current.Version = 4
+		conflict := &service.SettingsConflictError{Conflict: &models.SettingsConflict{
+			Current:   current,
+			Submitted: &models.UserSettingsUpdate{Theme: stringPtr("light"), Version: int64Ptr(3)},
+		}}
+
+		mockService.On("UpdateUserSettings", mock.Anything, int64(1001), mock.MatchedBy(func(u *models.UserSettingsUpdate) bool {
+			return u.Version != nil && *u.Version == 3
+		})).Return(nil, conflict).Once()
+
+		// Create test request
+		req, err := http.NewRequest("PUT", "/api/settings", strings.NewReader(`{"theme":"light","version":3}`))
+		require.NoError(t, err)
+		req.Header.Set("Content-Type", "application/json")
+		req = req.WithContext(createAuthContext(1001))
+		rr := httptest.NewRecorder()
+
+		// Call the handler
+		handler.UpdateSettings(rr, req)
+
+		// Verify both versions are returned
+		golden.AssertResponse(t, rr)
+		mockService.AssertExpectations(t)
+	})
+
+	t.Run("Invalid Version", func(t *testing.T) {
+		req, err := http.NewRequest("PUT", "/api/settings", strings.NewReader(`{"theme":"light","version":0}`))
+		require.NoError(t, err)
+		req.Header.Set("Content-Type", "application/json")
+		req = req.WithContext(createAuthContext(1001))
+		rr := httptest.NewRecorder()
+
+		handler.UpdateSettings(rr, req)
+
+		assert.Equal(t, http.StatusBadRequest, rr.Code)
+	})
+}
+
+func TestMergeSettings(t *testing.T) {
+	handler, mockService := setupSettingsTest(t)
+
+	t.Run("Success", func(t *testing.T) {
+		merged := fixtures.Settings(1001)
+		merged.Theme = "light"
+		merged.Version = 5
+
+		mockService.On("MergeUserSettings", mock.Anything, int64(1001), mock.MatchedBy(func(m *models.SettingsMerge) bool {
+			return m.BaseVersion == 3 && *m.Changes.Theme == "light"
+		})).Return(merged, nil).Once()
+
+		req, err := http.NewRequest("POST", "/api/settings/merge", strings.NewReader(`{"base_version":3,"changes":{"theme":"light"}}`))
+		require.NoError(t, err)
+		req.Header.Set("Content-Type", "application/json")
+		req = req.WithContext(createAuthContext(1001))
+		rr := httptest.NewRecorder()
+
+		handler.MergeSettings(rr, req)
+
+		golden.AssertResponse(t, rr)
+		mockService.AssertExpectations(t)
+	})
+
+	t.Run("Conflicting Fields", func(t *testing.T) {
+		current := fixtures.Settings(1001)
+		current.Theme = "dark"
+		current.Version = 4
+		conflict := &service.SettingsConflictError{Conflict: &models.SettingsConflict{
+			Current:           current,
+			Submitted:         &models.UserSettingsUpdate{Theme: stringPtr("light")},
+			ConflictingFields: []string{"theme"},
+		}}
+
+		mockService.On("MergeUserSettings", mock.Anything, int64(1001), mock.MatchedBy(func(m *models.SettingsMerge) bool {
+			return m.BaseVersion == 2
+		})).Return(nil, conflict).Once()
+
+		req, err := http.NewRequest("POST", "/api/settings/merge", strings.NewReader(`{"base_version":2,"changes":{"theme":"light"}}`))
+		require.NoError(t, err)
+		req.Header.Set("Content-Type", "application/json")
+		req = req.WithContext(createAuthContext(1001))
+		rr := httptest.NewRecorder()
+
+		handler.MergeSettings(rr, req)
+
+		golden.AssertResponse(t, rr)
+		mockService.AssertExpectations(t)
+	})
+
+	t.Run("Missing Base Version", func(t *testing.T) {
+		req, err := http.NewRequest("POST", "/api/settings/merge", strings.NewReader(`{"changes":{"theme":"light"}}`))
+		require.NoError(t, err)
+		req.Header.Set("Content-Type", "application/json")
+		req = req.WithContext(createAuthContext(1001))
+		rr := httptest.NewRecorder()
+
+		handler.MergeSettings(rr, req)
+
+		assert.Equal(t, http.StatusBadRequest, rr.Code)
+	})
+
+	t.Run("Unauthorized", func(t *testing.T) {
+		req, err := http.NewRequest("POST", "/api/settings/merge", strings.NewReader(`{"base_version":2,"changes":{}}`))
+		require.NoError(t, err)
+		rr := httptest.NewRecorder()
+
+		handler.MergeSettings(rr, req)
+
+		assert.Equal(t, http.StatusUnauthorized, rr.Code)
+	})
 }
 
 func TestGetBanList(t *testing.T) {
@@ -425,22 +574,7 @@ func TestGetBanList(t *testing.T) {
 		// Call the handler
 		handler.GetBanList(rr, req)
 
-		// Verify response
-		assert.Equal(t, http.StatusOK, rr.Code)
-
-		// Define wrapper for the response envelope
-		var responseWrapper struct {
-			Success bool                    `json:"success"`
-			Data    models.BanListWithWords `json:"data"`
-		}
-
-		// Parse response body into the wrapper
-		err = json.Unmarshal(rr.Body.Bytes(), &responseWrapper)
-		require.NoError(t, err)
-
-		// Verify response content using the data field from the wrapper
-		assert.Equal(t, expectedBanList.ID, responseWrapper.Data.ID)
-		assert.ElementsMatch(t, expectedBanList.Words, responseWrapper.Data.Words)
+		golden.AssertResponse(t, rr)
 
 		// Verify mock expectations
 		mockService.AssertExpectations(t)
@@ -521,27 +655,65 @@ func TestAddBanListWords(t *testing.T) {
 		// Call the handler
 		handler.AddBanListWords(rr, req)
 
-		// Verify response
-		assert.Equal(t, http.StatusOK, rr.Code)
+		golden.AssertResponse(t, rr)
 
-		// Define wrapper for the response envelope
-		var responseWrapper struct {
-			Success bool                    `json:"success"`
-			Data    models.BanListWithWords `json:"data"`
-		}
+		// Verify mock expectations
+		mockService.AssertExpectations(t)
+	})
 
-		// Parse response body into the wrapper
-		err = json.Unmarshal(rr.Body.Bytes(), &responseWrapper)
+	t.Run("Empty Word", func(t *testing.T) {
+		mockService.On("AddBanListWords", mock.Anything, int64(1001), []string{"word4"}).Return(nil).Once()
+		mockService.On("GetBanList", mock.Anything, int64(1001)).Return(&models.BanListWithWords{ID: 1, Words: []string{"word4"}}, nil).Once()
+
+		req, err := http.NewRequest("POST", "/api/settings/ban-list/words", bytes.NewBufferString(`{"words":["","word4"]}`))
 		require.NoError(t, err)
+		req.Header.Set("Content-Type", "application/json")
+		req = req.WithContext(createAuthContext(1001))
+		rr := httptest.NewRecorder()
 
-		// Verify response content using the data field from the wrapper
-		assert.Equal(t, expectedBanList.ID, responseWrapper.Data.ID)
-		assert.ElementsMatch(t, expectedBanList.Words, responseWrapper.Data.Words)
+		handler.AddBanListWords(rr, req)
 
-		// Verify mock expectations
+		// The word is added and the empty one reported
+		golden.AssertResponse(t, rr)
+		mockService.AssertExpectations(t)
+	})
+
+	t.Run("With Options", func(t *testing.T) {
+		options := models.BanListMatchOptions{CaseInsensitive: true, DiacriticInsensitive: true, Stemming: models.StemmingNorwegian}
+		mockService.On("AddBanListWords", mock.Anything, int64(1001), []string{"Ström"}).Return(nil).Once()
+		mockService.On("SetBanListWordOptions", mock.Anything, int64(1001), []string{"Ström"}, options).Return(nil).Once()
+		mockService.On("GetBanList", mock.Anything, int64(1001)).Return(&models.BanListWithWords{
+			ID:      1,
+			Words:   []string{"Ström"},
+			Options: map[string]models.BanListMatchOptions{"Ström": options},
+		}, nil).Once()
+
+		body := `{"words":["Ström"],"options":{"case_insensitive":true,"diacritic_insensitive":true,"stemming":"no"}}`
+		req, err := http.NewRequest("POST", "/api/settings/ban-list/words", bytes.NewBufferString(body))
+		require.NoError(t, err)
+		req.Header.Set("Content-Type", "application/json")
+		req = req.WithContext(createAuthContext(1001))
+		rr := httptest.NewRecorder()
+
+		handler.AddBanListWords(rr, req)
+
+		golden.AssertResponse(t, rr)
 		mockService.AssertExpectations(t)
 	})
 
+	t.Run("Unsupported Stemming Language", func(t *testing.T) {
+		body := `{"words":["Ström"],"options":{"stemming":"de"}}`
+		req, err := http.NewRequest("POST", "/api/settings/ban-list/words", bytes.NewBufferString(body))
+		require.NoError(t, err)
+		req.Header.Set("Content-Type", "application/json")
+		req = req.WithContext(createAuthContext(1001))
+		rr := httptest.NewRecorder()
+
+		handler.AddBanListWords(rr, req)
+
+		assert.Equal(t, http.StatusBadRequest, rr.Code)
+	})
+
 	t.Run("Invalid Request Body", func(t *testing.T) {
 		// Create invalid JSON
 		invalidJSON := []byte(`{"words": [invalid]}`)
@@ -653,6 +825,54 @@ func TestAddBanListWords(t *testing.T) {
 	})
 }
 
+func TestSetBanListOptions(t *testing.T) {
+	// Setup
+	handler, mockService := setupSettingsTest(t)
+
+	t.Run("Success", func(t *testing.T) {
+		enabled := true
+		mockService.On("SetBanListOptions", mock.Anything, int64(1001), &models.BanListOptionsUpdate{CaseInsensitive: &enabled}).Return(nil).Once()
+		mockService.On("GetBanList", mock.Anything, int64(1001)).Return(&models.BanListWithWords{
+			ID:             1,
+			BanListOptions: models.BanListOptions{CaseInsensitive: true},
+			Words:          []string{"Ström"},
+		}, nil).Once()
+
+		req, err := http.NewRequest("PATCH", "/api/settings/ban-list", bytes.NewBufferString(`{"case_insensitive":true}`))
+		require.NoError(t, err)
+		req.Header.Set("Content-Type", "application/json")
+		req = req.WithContext(createAuthContext(1001))
+		rr := httptest.NewRecorder()
+
+		handler.SetBanListOptions(rr, req)
+
+		golden.AssertResponse(t, rr)
+		mockService.AssertExpectations(t)
+	})
+
+	t.Run("Invalid Request Body", func(t *testing.T) {
+		req, err := http.NewRequest("PATCH", "/api/settings/ban-list", bytes.NewBufferString(`{"case_insensitive":"yes"}`))
+		require.NoError(t, err)
+		req.Header.Set("Content-Type", "application/json")
+		req = req.WithContext(createAuthContext(1001))
+		rr := httptest.NewRecorder()
+
+		handler.SetBanListOptions(rr, req)
+
+		assert.Equal(t, http.StatusBadRequest, rr.Code)
+	})
+
+	t.Run("Unauthorized", func(t *testing.T) {
+		req, err := http.NewRequest("PATCH", "/api/settings/ban-list", bytes.NewBufferString(`{}`))
+		require.NoError(t, err)
+		rr := httptest.NewRecorder()
+
+		handler.SetBanListOptions(rr, req)
+
+		assert.Equal(t, http.StatusUnauthorized, rr.Code)
+	})
+}
+
 func TestRemoveBanListWords(t *testing.T) {
 	// Setup
 	handler, mockService := setupSettingsTest(t)
@@ -671,7 +891,7 @@ func TestRemoveBanListWords(t *testing.T) {
 		}
 
 		// Setup mock service expectations
-		mockService.On("RemoveBanListWords", mock.Anything, int64(1001), batch.Words).Return(nil).Once()
+		mockService.On("RemoveBanListWords", mock.Anything, int64(1001), batch.Words).Return([]string{}, nil).Once()
 		mockService.On("GetBanList", mock.Anything, int64(1001)).Return(expectedBanList, nil).Once()
 
 		// Create request body
@@ -690,24 +910,27 @@ func TestRemoveBanListWords(t *testing.T) {
 		// Call the handler
 		handler.RemoveBanListWords(rr, req)
 
-		// Verify response
-		assert.Equal(t, http.StatusOK, rr.Code)
+		golden.AssertResponse(t, rr)
 
-		// Define response wrapper to handle the JSON envelope format
-		var responseWrapper struct {
-			Success bool                    `json:"success"`
-			Data    models.BanListWithWords `json:"data"`
-		}
+		// Verify mock expectations
+		mockService.AssertExpectations(t)
+	})
 
-		// Parse response body into wrapper
-		err = json.Unmarshal(rr.Body.Bytes(), &responseWrapper)
+	t.Run("Not On List", func(t *testing.T) {
+		words := []string{"word1", "word9", "word1"}
+		mockService.On("RemoveBanListWords", mock.Anything, int64(1001), words).Return([]string{"word9", "word1"}, nil).Once()
+		mockService.On("GetBanList", mock.Anything, int64(1001)).Return(&models.BanListWithWords{ID: 1, Words: []string{"word3"}}, nil).Once()
+
+		req, err := http.NewRequest("DELETE", "/api/settings/ban-list/words", bytes.NewBufferString(`{"words":["word1","word9","word1"]}`))
 		require.NoError(t, err)
+		req.Header.Set("Content-Type", "application/json")
+		req = req.WithContext(createAuthContext(1001))
+		rr := httptest.NewRecorder()
 
-		// Verify response content using the data field from the wrapper
-		assert.Equal(t, expectedBanList.ID, responseWrapper.Data.ID)
-		assert.ElementsMatch(t, expectedBanList.Words, responseWrapper.Data.Words)
+		handler.RemoveBanListWords(rr, req)
 
-		// Verify mock expectations
+		// Only the first "word1" was on the list
+		golden.AssertResponse(t, rr)
 		mockService.AssertExpectations(t)
 	})
 
@@ -762,7 +985,7 @@ func TestRemoveBanListWords(t *testing.T) {
 
 		// Setup mock service to return error
 		mockService.On("RemoveBanListWords", mock.Anything, int64(1001), batch.Words).
-			Return(errors.New("service error")).Once()
+			Return(nil, errors.New("service error")).Once()
 
 		// Create request body
 		requestBody, err := json.Marshal(batch)
@@ -816,6 +1039,7 @@ func TestGetSearchPatterns(t *testing.T) {
 		// Create test request
 		req, err := http.NewRequest("GET", "/api/settings/patterns", nil)
 		require.NoError(t, err)
+		req.Header.Set(constants.HeaderXAPIVersion, "2")
 		req = req.WithContext(createAuthContext(1001))
 
 		// Create response recorder
@@ -824,27 +1048,7 @@ func TestGetSearchPatterns(t *testing.T) {
 		// Call the handler
 		handler.GetSearchPatterns(rr, req)
 
-		// Verify response
-		assert.Equal(t, http.StatusOK, rr.Code)
-
-		// Define wrapper for the response envelope
-		var responseWrapper struct {
-			Success bool                    `json:"success"`
-			Data    []*models.SearchPattern `json:"data"`
-		}
-
-		// Parse response body into the wrapper
-		err = json.Unmarshal(rr.Body.Bytes(), &responseWrapper)
-		require.NoError(t, err)
-
-		// Verify response content using the data field from the wrapper
-		assert.Equal(t, len(expectedPatterns), len(responseWrapper.Data))
-		for i, pattern := range expectedPatterns {
-			assert.Equal(t, pattern.ID, responseWrapper.Data[i].ID)
-			assert.Equal(t, pattern.SettingID, responseWrapper.Data[i].SettingID)
-			assert.Equal(t, pattern.PatternType, responseWrapper.Data[i].PatternType)
-			assert.Equal(t, pattern.PatternText, responseWrapper.Data[i].PatternText)
-		}
+		golden.AssertResponse(t, rr)
 
 		// Verify mock expectations
 		mockService.AssertExpectations(t)
@@ -929,24 +1133,7 @@ func TestCreateSearchPattern(t *testing.T) {
 		// Call the handler
 		handler.CreateSearchPattern(rr, req)
 
-		// Verify response
-		assert.Equal(t, http.StatusCreated, rr.Code)
-
-		// Define wrapper for the response envelope
-		var responseWrapper struct {
-			Success bool                 `json:"success"`
-			Data    models.SearchPattern `json:"data"`
-		}
-
-		// Parse response body into the wrapper
-		err = json.Unmarshal(rr.Body.Bytes(), &responseWrapper)
-		require.NoError(t, err)
-
-		// Verify response content using the data field from the wrapper
-		assert.Equal(t, expectedPattern.ID, responseWrapper.Data.ID)
-		assert.Equal(t, expectedPattern.SettingID, responseWrapper.Data.SettingID)
-		assert.Equal(t, expectedPattern.PatternType, responseWrapper.Data.PatternType)
-		assert.Equal(t, expectedPattern.PatternText, responseWrapper.Data.PatternText)
+		golden.AssertResponse(t, rr)
 
 		// Verify mock expectations
 		mockService.AssertExpectations(t)
@@ -1076,24 +1263,7 @@ func TestUpdateSearchPattern(t *testing.T) {
 		// Call the handler via router to process URL parameters
 		router.ServeHTTP(rr, req)
 
-		// Verify response
-		assert.Equal(t, http.StatusOK, rr.Code)
-
-		// Define wrapper for the response envelope
-		var responseWrapper struct {
-			Success bool                 `json:"success"`
-			Data    models.SearchPattern `json:"data"`
-		}
-
-		// Parse response body into the wrapper
-		err = json.Unmarshal(rr.Body.Bytes(), &responseWrapper)
-		require.NoError(t, err)
-
-		// Verify response content using the data field from the wrapper
-		assert.Equal(t, expectedPattern.ID, responseWrapper.Data.ID)
-		assert.Equal(t, expectedPattern.SettingID, responseWrapper.Data.SettingID)
-		assert.Equal(t, expectedPattern.PatternType, responseWrapper.Data.PatternType)
-		assert.Equal(t, expectedPattern.PatternText, responseWrapper.Data.PatternText)
+		golden.AssertResponse(t, rr)
 
 		// Verify mock expectations
 		mockService.AssertExpectations(t)
@@ -1205,31 +1375,164 @@ func TestUpdateSearchPattern(t *testing.T) {
 	})
 }
 
-func TestDeleteSearchPattern(t *testing.T) {
+func TestTestSearchPattern(t *testing.T) {
 	// Setup
 	handler, mockService := setupSettingsTest(t)
-	router := chi.NewRouter()
-	router.Delete("/api/settings/patterns/{patternID}", handler.DeleteSearchPattern)
 
-	// Test successful case
 	t.Run("Success", func(t *testing.T) {
-		// Create pattern ID
-		patternID := int64(123)
-
-		// Setup mock service
-		mockService.On("DeleteSearchPattern", mock.Anything, int64(1001), patternID).Return(nil).Once()
+		result := &models.SearchPatternTestResult{
+			Matches: []models.SearchPatternMatch{{Text: "12345678901", Start: 14, End: 25}},
+		}
+		mockService.On("TestSearchPattern", mock.MatchedBy(func(test *models.SearchPatternTest) bool {
+			return test.PatternType == "case_sensitive" && test.PatternText == `\d{11}`
+		})).Return(result, nil).Once()
 
-		// Create test request
-		req, err := http.NewRequest("DELETE", "/api/settings/patterns/"+strconv.FormatInt(patternID, 10), nil)
+		body := `{"pattern_type": "case_sensitive", "pattern_text": "\\d{11}", "sample_text": "Fødselsnummer 12345678901"}`
+		req, err := http.NewRequest("POST", "/api/settings/patterns/test", bytes.NewBufferString(body))
 		require.NoError(t, err)
+		req.Header.Set("Content-Type", "application/json")
 		req = req.WithContext(createAuthContext(1001))
 
-		// Create response recorder
 		rr := httptest.NewRecorder()
+		handler.TestSearchPattern(rr, req)
+
+		golden.AssertResponse(t, rr)
+		mockService.AssertExpectations(t)
+	})
+
+	t.Run("Sample Text Required", func(t *testing.T) {
+		body := `{"pattern_type": "normal", "pattern_text": "oslo"}`
+		req, err := http.NewRequest("POST", "/api/settings/patterns/test", bytes.NewBufferString(body))
+		require.NoError(t, err)
+		req.Header.Set("Content-Type", "application/json")
+		req = req.WithContext(createAuthContext(1001))
+
+		rr := httptest.NewRecorder()
+		handler.TestSearchPattern(rr, req)
+
+		assert.Equal(t, http.StatusBadRequest, rr.Code)
+	})
+
+	t.Run("Unsafe Pattern", func(t *testing.T) {
+		mockService.On("TestSearchPattern", mock.Anything).
+			Return(nil, utils.NewValidationError("pattern_text", constants.MsgSearchPatternUnsafe)).Once()
+
+		body := `{"pattern_type": "normal", "pattern_text": "(a+)+", "sample_text": "aaaa"}`
+		req, err := http.NewRequest("POST", "/api/settings/patterns/test", bytes.NewBufferString(body))
+		require.NoError(t, err)
+		req.Header.Set("Content-Type", "application/json")
+		req = req.WithContext(createAuthContext(1001))
+
+		rr := httptest.NewRecorder()
+		handler.TestSearchPattern(rr, req)
+
+		golden.AssertResponse(t, rr)
+		mockService.AssertExpectations(t)
+	})
+
+	t.Run("Unauthorized", func(t *testing.T) {
+		req, err := http.NewRequest("POST", "/api/settings/patterns/test", bytes.NewBufferString(`{}`))
+		require.NoError(t, err)
+
+		rr := httptest.NewRecorder()
+		handler.TestSearchPattern(rr, req)
+
+		assert.Equal(t, http.StatusUnauthorized, rr.Code)
+	})
+}
+
+func TestGetSearchPatternCatalog(t *testing.T) {
+	handler, _ := setupSettingsTest(t)
+
+	req, err := http.NewRequest("GET", "/api/settings/patterns/catalog", nil)
+	require.NoError(t, err)
+	req.Header.Set(constants.HeaderXAPIVersion, "2")
+	req = req.WithContext(createAuthContext(1001))
+
+	rr := httptest.NewRecorder()
+	handler.GetSearchPatternCatalog(rr, req)
+
+	golden.AssertResponse(t, rr)
+}
+
+func TestImportCatalogPatterns(t *testing.T) {
+	// Setup
+	handler, mockService := setupSettingsTest(t)
+
+	t.Run("Partial Success", func(t *testing.T) {
+		imports := []models.SearchPatternImport{
+			{Key: "iban", Pattern: &models.SearchPattern{ID: 7, SettingID: 1, PatternType: models.CaseSensitive, PatternText: "iban"}, Created: true},
+			{Key: "us_ssn", Pattern: &models.SearchPattern{ID: 3, SettingID: 1, PatternType: models.CaseSensitive, PatternText: "ssn"}},
+			{Key: "unknown", Err: utils.New(utils.ErrNotFound, constants.StatusNotFound, constants.MsgSearchPatternCatalogNotFound)},
+		}
+		mockService.On("ImportCatalogPatterns", mock.Anything, int64(1001), []string{"iban", "us_ssn", "unknown"}).Return(imports, nil).Once()
+
+		body := `{"keys": ["iban", "us_ssn", "unknown"]}`
+		req, err := http.NewRequest("POST", "/api/settings/patterns/import-catalog", bytes.NewBufferString(body))
+		require.NoError(t, err)
+		req.Header.Set("Content-Type", "application/json")
+		req = req.WithContext(createAuthContext(1001))
+
+		rr := httptest.NewRecorder()
+		handler.ImportCatalogPatterns(rr, req)
+
+		golden.AssertResponse(t, rr)
+		mockService.AssertExpectations(t)
+	})
+
+	t.Run("Keys Required", func(t *testing.T) {
+		req, err := http.NewRequest("POST", "/api/settings/patterns/import-catalog", bytes.NewBufferString(`{"keys": []}`))
+		require.NoError(t, err)
+		req.Header.Set("Content-Type", "application/json")
+		req = req.WithContext(createAuthContext(1001))
+
+		rr := httptest.NewRecorder()
+		handler.ImportCatalogPatterns(rr, req)
+
+		assert.Equal(t, http.StatusBadRequest, rr.Code)
+	})
+
+	t.Run("Service Error", func(t *testing.T) {
+		mockService.On("ImportCatalogPatterns", mock.Anything, int64(1002), []string{"iban"}).Return(nil, errors.New("service error")).Once()
+
+		req, err := http.NewRequest("POST", "/api/settings/patterns/import-catalog", bytes.NewBufferString(`{"keys": ["iban"]}`))
+		require.NoError(t, err)
+		req.Header.Set("Content-Type", "application/json")
+		req = req.WithContext(createAuthContext(1002))
+
+		rr := httptest.NewRecorder()
+		handler.ImportCatalogPatterns(rr, req)
+
+		assert.Equal(t, http.StatusInternalServerError, rr.Code)
+		mockService.AssertExpectations(t)
+	})
+}
+
+func TestDeleteSearchPattern(t *testing.T) {
+	// Setup
+	handler, mockService := setupSettingsTest(t)
+	router := chi.NewRouter()
+	router.Delete("/api/settings/patterns/{patternID}", handler.DeleteSearchPattern)
+
+	// Test successful case
+	t.Run("Success", func(t *testing.T) {
+		// Create pattern ID
+		patternID := int64(123)
+
+		// Setup mock service
+		mockService.On("DeleteSearchPattern", mock.Anything, int64(1001), patternID).Return(nil).Once()
+
+		// Create test request
+		req, err := http.NewRequest("DELETE", "/api/settings/patterns/"+strconv.FormatInt(patternID, 10), nil)
+		require.NoError(t, err)
+		req = req.WithContext(createAuthContext(1001))
+
+		// Create response recorder
+		rr := httptest.NewRecorder()
+
+		// Call the handler via router
+		router.ServeHTTP(rr, req)
 
-		// Call the handler via router
-		router.ServeHTTP(rr, req)
-
 		// Verify response
 		assert.Equal(t, http.StatusNoContent, rr.Code)
 
@@ -1335,6 +1638,7 @@ func TestGetModelEntities(t *testing.T) {
 		// Create test request
 		req, err := http.NewRequest("GET", "/api/settings/entities/"+strconv.FormatInt(methodID, 10), nil)
 		require.NoError(t, err)
+		req.Header.Set(constants.HeaderXAPIVersion, "2")
 		req = req.WithContext(createAuthContext(1001))
 
 		// Create response recorder
@@ -1343,28 +1647,7 @@ func TestGetModelEntities(t *testing.T) {
 		// Call the handler via router
 		router.ServeHTTP(rr, req)
 
-		// Verify response
-		assert.Equal(t, http.StatusOK, rr.Code)
-
-		// Define wrapper for the response envelope
-		var responseWrapper struct {
-			Success bool                            `json:"success"`
-			Data    []*models.ModelEntityWithMethod `json:"data"`
-		}
-
-		// Parse response body into the wrapper
-		err = json.Unmarshal(rr.Body.Bytes(), &responseWrapper)
-		require.NoError(t, err)
-
-		// Verify response content using the data field from the wrapper
-		assert.Equal(t, len(expectedEntities), len(responseWrapper.Data))
-		for i, entity := range expectedEntities {
-			assert.Equal(t, entity.ID, responseWrapper.Data[i].ID)
-			assert.Equal(t, entity.SettingID, responseWrapper.Data[i].SettingID)
-			assert.Equal(t, entity.MethodID, responseWrapper.Data[i].MethodID)
-			assert.Equal(t, entity.EntityText, responseWrapper.Data[i].EntityText)
-			assert.Equal(t, entity.MethodName, responseWrapper.Data[i].MethodName)
-		}
+		golden.AssertResponse(t, rr)
 
 		// Verify mock expectations
 		mockService.AssertExpectations(t)
@@ -1428,6 +1711,67 @@ func TestGetModelEntities(t *testing.T) {
 	})
 }
 
+func TestGetModelEntities_XML(t *testing.T) {
+	// Setup
+	handler, mockService := setupSettingsTest(t)
+	router := chi.NewRouter()
+	router.Get("/api/settings/entities/{methodID}", handler.GetModelEntities)
+
+	entities := []*models.ModelEntityWithMethod{
+		{ModelEntity: models.ModelEntity{ID: 1, SettingID: 1, MethodID: 1, EntityText: "Entity 1"}, MethodName: "Method 1"},
+	}
+	mockService.On("GetModelEntities", mock.Anything, int64(1001), int64(1)).Return(entities, nil).Once()
+
+	req, err := http.NewRequest("GET", "/api/settings/entities/1", nil)
+	require.NoError(t, err)
+	req.Header.Set("Accept", "text/xml")
+	req = req.WithContext(createAuthContext(1001))
+
+	rr := httptest.NewRecorder()
+	router.ServeHTTP(rr, req)
+
+	assert.Equal(t, http.StatusOK, rr.Code)
+	assert.Equal(t, constants.ContentTypeXML, rr.Header().Get("Content-Type"))
+	assert.Contains(t, rr.Body.String(), "<entity_text>Entity 1</entity_text>")
+	assert.Contains(t, rr.Body.String(), "<method_name>Method 1</method_name>")
+
+	mockService.AssertExpectations(t)
+}
+
+func TestGetModelEntities_NDJSON(t *testing.T) {
+	// Setup
+	handler, mockService := setupSettingsTest(t)
+	router := chi.NewRouter()
+	router.Get("/api/settings/entities/{methodID}", handler.GetModelEntities)
+
+	entities := []*models.ModelEntityWithMethod{
+		{ModelEntity: models.ModelEntity{ID: 1, SettingID: 1, MethodID: 1, EntityText: "Entity 1"}, MethodName: "Method 1"},
+		{ModelEntity: models.ModelEntity{ID: 2, SettingID: 1, MethodID: 1, EntityText: "Entity 2"}, MethodName: "Method 1"},
+	}
+	mockService.On("StreamModelEntities", mock.Anything, int64(1001), int64(1)).Return(entities, nil).Once()
+
+	req, err := http.NewRequest("GET", "/api/settings/entities/1", nil)
+	require.NoError(t, err)
+	req.Header.Set("Accept", "application/x-ndjson")
+	req = req.WithContext(createAuthContext(1001))
+
+	rr := httptest.NewRecorder()
+	router.ServeHTTP(rr, req)
+
+	// One entity per line, without the response envelope
+	assert.Equal(t, http.StatusOK, rr.Code)
+	assert.Equal(t, "application/x-ndjson", rr.Header().Get("Content-Type"))
+
+	lines := strings.Split(strings.TrimSpace(rr.Body.String()), "\n")
+	require.Len(t, lines, 2)
+
+	var second models.ModelEntityWithMethod
+	require.NoError(t, json.Unmarshal([]byte(lines[1]), &second))
+	assert.Equal(t, "Entity 2", second.EntityText)
+
+	mockService.AssertExpectations(t)
+}
+
 func TestAddModelEntities(t *testing.T) {
 	// Setup
 	handler, mockService := setupSettingsTest(t)
@@ -1480,27 +1824,7 @@ func TestAddModelEntities(t *testing.T) {
 		// Call the handler
 		handler.AddModelEntities(rr, req)
 
-		// Verify response
-		assert.Equal(t, http.StatusCreated, rr.Code)
-
-		// Define wrapper for the response envelope
-		var responseWrapper struct {
-			Success bool                  `json:"success"`
-			Data    []*models.ModelEntity `json:"data"`
-		}
-
-		// Parse response body into the wrapper
-		err = json.Unmarshal(rr.Body.Bytes(), &responseWrapper)
-		require.NoError(t, err)
-
-		// Verify response content using the data field from the wrapper
-		assert.Equal(t, len(expectedEntities), len(responseWrapper.Data))
-		for i, entity := range expectedEntities {
-			assert.Equal(t, entity.ID, responseWrapper.Data[i].ID)
-			assert.Equal(t, entity.SettingID, responseWrapper.Data[i].SettingID)
-			assert.Equal(t, entity.MethodID, responseWrapper.Data[i].MethodID)
-			assert.Equal(t, entity.EntityText, responseWrapper.Data[i].EntityText)
-		}
+		golden.AssertResponse(t, rr)
 
 		// Verify mock expectations
 		mockService.AssertExpectations(t)
@@ -1768,6 +2092,94 @@ func TestDeleteModelEntityByMethodID(t *testing.T) {
 	})
 }
 
+func TestReplaceModelEntities(t *testing.T) {
+	// Setup
+	handler, mockService := setupSettingsTest(t)
+	router := chi.NewRouter()
+	router.Put("/api/settings/entities/{methodID}", handler.ReplaceModelEntities)
+
+	t.Run("Success", func(t *testing.T) {
+		methodID := int64(5)
+		replace := &models.ModelEntityReplace{EntityTexts: []string{"John Doe", "Oslo"}}
+		replacement := &models.ModelEntityReplacement{
+			Entities: []*models.ModelEntity{
+				{ID: 1, SettingID: 1, MethodID: methodID, EntityText: "John Doe"},
+				{ID: 3, SettingID: 1, MethodID: methodID, EntityText: "Oslo"},
+			},
+			Created: []*models.ModelEntity{{ID: 3, SettingID: 1, MethodID: methodID, EntityText: "Oslo"}},
+			Deleted: []*models.ModelEntity{{ID: 2, SettingID: 1, MethodID: methodID, EntityText: "Bergen"}},
+		}
+
+		// Setup mock service
+		mockService.On("ReplaceModelEntities", mock.Anything, int64(1001), methodID, replace).Return(replacement, nil).Once()
+
+		// Create test request
+		body, _ := json.Marshal(replace)
+		req, err := http.NewRequest("PUT", "/api/settings/entities/"+strconv.FormatInt(methodID, 10), bytes.NewBuffer(body))
+		require.NoError(t, err)
+		req.Header.Set("Content-Type", "application/json")
+		req = req.WithContext(createAuthContext(1001))
+
+		rr := httptest.NewRecorder()
+		router.ServeHTTP(rr, req)
+
+		// Verify response
+		golden.AssertResponse(t, rr)
+		mockService.AssertExpectations(t)
+	})
+
+	t.Run("Empty List", func(t *testing.T) {
+		replace := &models.ModelEntityReplace{EntityTexts: []string{}}
+		mockService.On("ReplaceModelEntities", mock.Anything, int64(1001), int64(5), replace).
+			Return(&models.ModelEntityReplacement{Entities: []*models.ModelEntity{}}, nil).Once()
+
+		req, err := http.NewRequest("PUT", "/api/settings/entities/5", bytes.NewBufferString(`{"entity_texts":[]}`))
+		require.NoError(t, err)
+		req.Header.Set("Content-Type", "application/json")
+		req = req.WithContext(createAuthContext(1001))
+
+		rr := httptest.NewRecorder()
+		router.ServeHTTP(rr, req)
+
+		assert.Equal(t, http.StatusOK, rr.Code)
+		mockService.AssertExpectations(t)
+	})
+
+	t.Run("Missing List", func(t *testing.T) {
+		req, err := http.NewRequest("PUT", "/api/settings/entities/5", bytes.NewBufferString(`{}`))
+		require.NoError(t, err)
+		req.Header.Set("Content-Type", "application/json")
+		req = req.WithContext(createAuthContext(1001))
+
+		rr := httptest.NewRecorder()
+		router.ServeHTTP(rr, req)
+
+		assert.Equal(t, http.StatusBadRequest, rr.Code)
+	})
+
+	t.Run("Invalid Method ID", func(t *testing.T) {
+		req, err := http.NewRequest("PUT", "/api/settings/entities/invalid", bytes.NewBufferString(`{"entity_texts":["John Doe"]}`))
+		require.NoError(t, err)
+		req.Header.Set("Content-Type", "application/json")
+		req = req.WithContext(createAuthContext(1001))
+
+		rr := httptest.NewRecorder()
+		router.ServeHTTP(rr, req)
+
+		assert.Equal(t, http.StatusBadRequest, rr.Code)
+	})
+
+	t.Run("Unauthorized", func(t *testing.T) {
+		req, err := http.NewRequest("PUT", "/api/settings/entities/5", bytes.NewBufferString(`{"entity_texts":["John Doe"]}`))
+		require.NoError(t, err)
+
+		rr := httptest.NewRecorder()
+		handler.ReplaceModelEntities(rr, req)
+
+		assert.Equal(t, http.StatusUnauthorized, rr.Code)
+	})
+}
+
 func TestExportSettings(t *testing.T) {
 	// Setup
 	handler, mockService := setupSettingsTest(t)
@@ -1811,6 +2223,89 @@ func TestExportSettings(t *testing.T) {
 	})
 }
 
+func TestGetDetectionConfig(t *testing.T) {
+	// Setup
+	handler, mockService := setupSettingsTest(t)
+
+	t.Run("Unauthorized", func(t *testing.T) {
+		req, err := http.NewRequest("GET", "/api/settings/detection-config", nil)
+		require.NoError(t, err)
+		rr := httptest.NewRecorder()
+
+		handler.GetDetectionConfig(rr, req)
+
+		assert.Equal(t, http.StatusUnauthorized, rr.Code)
+	})
+
+	detectionConfig := &models.DetectionConfig{
+		UserID:  1001,
+		BanList: &models.BanListWithWords{ID: 1, Words: []string{"secret"}},
+	}
+
+	for name, cached := range map[string]bool{"miss": false, "hit": true} {
+		t.Run("Cache "+name, func(t *testing.T) {
+			mockService.On("GetDetectionConfig", mock.Anything, int64(1001)).Return(detectionConfig, cached, nil).Once()
+
+			req, err := http.NewRequest("GET", "/api/settings/detection-config", nil)
+			require.NoError(t, err)
+			req = req.WithContext(createAuthContext(1001))
+			rr := httptest.NewRecorder()
+
+			handler.GetDetectionConfig(rr, req)
+
+			assert.Equal(t, http.StatusOK, rr.Code)
+			assert.Equal(t, name, rr.Header().Get(constants.HeaderXCache))
+			golden.AssertResponse(t, rr)
+		})
+	}
+
+	t.Run("Service Error", func(t *testing.T) {
+		mockService.On("GetDetectionConfig", mock.Anything, int64(1002)).Return(nil, false, errors.New("service error")).Once()
+
+		req, err := http.NewRequest("GET", "/api/settings/detection-config", nil)
+		require.NoError(t, err)
+		req = req.WithContext(createAuthContext(1002))
+		rr := httptest.NewRecorder()
+
+		handler.GetDetectionConfig(rr, req)
+
+		assert.Equal(t, http.StatusInternalServerError, rr.Code)
+	})
+
+	mockService.AssertExpectations(t)
+}
+
+func TestExportSettings_XML(t *testing.T) {
+	// Setup
+	handler, mockService := setupSettingsTest(t)
+
+	export := &models.SettingsExport{
+		Version:    1,
+		UserID:     1001,
+		ExportDate: time.Date(2026, 3, 1, 12, 0, 0, 0, time.UTC),
+		BanList:    &models.BanListWithWords{ID: 1, Words: []string{"secret"}},
+	}
+	mockService.On("ExportSettings", mock.Anything, int64(1001)).Return(export, nil).Once()
+
+	req, err := http.NewRequest("GET", "/api/settings/export", nil)
+	require.NoError(t, err)
+	req.Header.Set("Accept", "application/xml")
+	req = req.WithContext(createAuthContext(1001))
+
+	rr := httptest.NewRecorder()
+	handler.ExportSettings(rr, req)
+
+	// The export is a downloadable XML file without the response envelope
+	assert.Equal(t, http.StatusOK, rr.Code)
+	assert.Equal(t, constants.ContentTypeXML, rr.Header().Get("Content-Type"))
+	assert.Contains(t, rr.Header().Get("Content-Disposition"), "_settings.xml")
+	assert.Contains(t, rr.Body.String(), "<settings_export>")
+	assert.Contains(t, rr.Body.String(), "<item>secret</item>")
+	assert.NotContains(t, rr.Body.String(), "<response>")
+
+	mockService.AssertExpectations(t)
+}
+
 func TestImportSettings(t *testing.T) {
 	// Setup
 	handler, mockService := setupSettingsTest(t)
@@ -1918,6 +2413,34 @@ func TestImportSettings(t *testing.T) {
 		assert.Equal(t, http.StatusBadRequest, rr.Code)
 	})
 
+	t.Run("Unversioned Export", func(t *testing.T) {
+		// Exports made before versioning have no version and no redaction placeholders
+		oldSettings := []byte(`{"user_id": 1001, "general_settings": {"theme": "light"}, "ban_list": {"id": 1, "words": ["word1"]}}`)
+
+		mockService.On("ImportSettings", mock.Anything, int64(1001), mock.MatchedBy(func(s *models.SettingsExport) bool {
+			return s.Version == models.SettingsExportVersion &&
+				s.GeneralSettings.Theme == "light" &&
+				s.GeneralSettings.RedactionPlaceholders != nil
+		})).Return(nil).Once()
+
+		req := createSettingsFileRequest(t, oldSettings, "settings.json")
+		rr := httptest.NewRecorder()
+		handler.ImportSettings(rr, req)
+
+		assert.Equal(t, http.StatusOK, rr.Code)
+		mockService.AssertExpectations(t)
+	})
+
+	t.Run("Newer Version", func(t *testing.T) {
+		newSettings := []byte(`{"version": 99, "general_settings": {"theme": "dark"}}`)
+
+		req := createSettingsFileRequest(t, newSettings, "settings.json")
+		rr := httptest.NewRecorder()
+		handler.ImportSettings(rr, req)
+
+		golden.AssertResponse(t, rr)
+	})
+
 	t.Run("Missing General Settings", func(t *testing.T) {
 		// Create settings JSON without general settings
 		invalidSettings := &models.SettingsExport{
@@ -2065,7 +2588,104 @@ func TestImportSettings(t *testing.T) {
 	})
 }
 
+func TestGetSettingsChanges(t *testing.T) {
+	// Setup
+	handler, mockService := setupSettingsTest(t)
+
+	t.Run("Success", func(t *testing.T) {
+		feed := &models.SettingsChangeFeed{
+			Since:          5,
+			LatestRevision: 6,
+			Changes: []*models.SettingsRevision{
+				{Revision: 6, ResourceType: models.ResourceBanListWord, ResourceID: 1, Action: models.RevisionCreated},
+			},
+		}
+		mockService.On("GetSettingsChanges", mock.Anything, int64(1001), int64(5), mock.AnythingOfType("int"), 10*time.Second).
+			Return(feed, nil).Once()
+
+		req, err := http.NewRequest("GET", "/api/settings/changes?since=5&wait=10", nil)
+		require.NoError(t, err)
+		req = req.WithContext(createAuthContext(1001))
+
+		rr := httptest.NewRecorder()
+		handler.GetSettingsChanges(rr, req)
+
+		golden.AssertResponse(t, rr)
+
+		mockService.AssertExpectations(t)
+	})
+
+	t.Run("Wait Is Capped", func(t *testing.T) {
+		mockService.On("GetSettingsChanges", mock.Anything, int64(1001), int64(0), mock.AnythingOfType("int"), 30*time.Second).
+			Return(&models.SettingsChangeFeed{Changes: []*models.SettingsRevision{}}, nil).Once()
+
+		req, err := http.NewRequest("GET", "/api/settings/changes?wait=3600", nil)
+		require.NoError(t, err)
+		req = req.WithContext(createAuthContext(1001))
+
+		rr := httptest.NewRecorder()
+		handler.GetSettingsChanges(rr, req)
+
+		assert.Equal(t, http.StatusOK, rr.Code)
+		mockService.AssertExpectations(t)
+	})
+
+	t.Run("Invalid Since", func(t *testing.T) {
+		for _, since := range []string{"abc", "-1"} {
+			req, err := http.NewRequest("GET", "/api/settings/changes?since="+since, nil)
+			require.NoError(t, err)
+			req = req.WithContext(createAuthContext(1001))
+
+			rr := httptest.NewRecorder()
+			handler.GetSettingsChanges(rr, req)
+
+			assert.Equal(t, http.StatusBadRequest, rr.Code)
+		}
+	})
+
+	t.Run("Invalid Wait", func(t *testing.T) {
+		req, err := http.NewRequest("GET", "/api/settings/changes?wait=soon", nil)
+		require.NoError(t, err)
+		req = req.WithContext(createAuthContext(1001))
+
+		rr := httptest.NewRecorder()
+		handler.GetSettingsChanges(rr, req)
+
+		assert.Equal(t, http.StatusBadRequest, rr.Code)
+	})
+
+	t.Run("Service Error", func(t *testing.T) {
+		mockService.On("GetSettingsChanges", mock.Anything, int64(1002), int64(0), mock.AnythingOfType("int"), time.Duration(0)).
+			Return(nil, errors.New("service error")).Once()
+
+		req, err := http.NewRequest("GET", "/api/settings/changes", nil)
+		require.NoError(t, err)
+		req = req.WithContext(createAuthContext(1002))
+
+		rr := httptest.NewRecorder()
+		handler.GetSettingsChanges(rr, req)
+
+		assert.Equal(t, http.StatusInternalServerError, rr.Code)
+		mockService.AssertExpectations(t)
+	})
+
+	t.Run("Unauthorized", func(t *testing.T) {
+		req, err := http.NewRequest("GET", "/api/settings/changes", nil)
+		require.NoError(t, err)
+
+		rr := httptest.NewRecorder()
+		handler.GetSettingsChanges(rr, req)
+
+		assert.Equal(t, http.StatusUnauthorized, rr.Code)
+	})
+}
+
 // Helper function to create string pointer
 func stringPtr(s string) *string {
 	return &s
 }
+
+// Helper function to create int64 pointer
+func int64Ptr(i int64) *int64 {
+	return &i
+}
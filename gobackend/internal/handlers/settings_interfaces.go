@@ -3,6 +3,8 @@ package handlers
 
 import (
 	"context"
+	"time"
+
 	"github.com/yasinhessnawi1/Hideme_Backend/internal/models"
 )
 
@@ -30,9 +32,24 @@ type SettingsServiceInterface interface {
 	//
 	// Returns:
 	//   - The updated user settings
+	//   - A service.SettingsConflictError if the update is based on an older version
 	//   - An error if the update fails
 	UpdateUserSettings(ctx context.Context, userID int64, update *models.UserSettingsUpdate) (*models.UserSetting, error)
 
+	// MergeUserSettings applies changes made to an older version of a user's settings
+	// to the current settings, unless they change fields changed to other values since.
+	//
+	// Parameters:
+	//   - ctx: Context for the operation
+	//   - userID: The ID of the user whose settings to update
+	//   - merge: The changes and the version they were made to
+	//
+	// Returns:
+	//   - The merged user settings
+	//   - A service.SettingsConflictError listing the conflicting fields
+	//   - An error if the merge fails
+	MergeUserSettings(ctx context.Context, userID int64, merge *models.SettingsMerge) (*models.UserSetting, error)
+
 	// GetBanList retrieves the ban list for a specific user.
 	//
 	// Parameters:
@@ -63,8 +80,32 @@ type SettingsServiceInterface interface {
 	//   - words: The words to remove from the ban list
 	//
 	// Returns:
+	//   - The words that were not on the ban list
 	//   - An error if the operation fails
-	RemoveBanListWords(ctx context.Context, userID int64, words []string) error
+	RemoveBanListWords(ctx context.Context, userID int64, words []string) ([]string, error)
+
+	// SetBanListOptions sets how all words on a user's ban list are matched.
+	//
+	// Parameters:
+	//   - ctx: Context for the operation
+	//   - userID: The ID of the user whose ban list to update
+	//   - update: The options to set; omitted options are kept
+	//
+	// Returns:
+	//   - An error if the update fails
+	SetBanListOptions(ctx context.Context, userID int64, update *models.BanListOptionsUpdate) error
+
+	// SetBanListWordOptions sets how words on a user's ban list are matched.
+	//
+	// Parameters:
+	//   - ctx: Context for the operation
+	//   - userID: The ID of the user whose ban list to update
+	//   - words: The words whose options are set
+	//   - options: How the words are matched
+	//
+	// Returns:
+	//   - An error if the options are invalid or the update fails
+	SetBanListWordOptions(ctx context.Context, userID int64, words []string, options models.BanListMatchOptions) error
 
 	// GetSearchPatterns retrieves the search patterns for a specific user.
 	//
@@ -113,6 +154,28 @@ type SettingsServiceInterface interface {
 	//   - An error if the deletion fails or pattern not found
 	DeleteSearchPattern(ctx context.Context, userID int64, patternID int64) error
 
+	// TestSearchPattern tries out a search pattern on sample text without saving it.
+	//
+	// Parameters:
+	//   - test: The pattern and the sample text
+	//
+	// Returns:
+	//   - The matches of the pattern in the sample text
+	//   - An error if the pattern cannot be tried out or is not a valid regular expression
+	TestSearchPattern(test *models.SearchPatternTest) (*models.SearchPatternTestResult, error)
+
+	// ImportCatalogPatterns copies patterns from the search pattern catalog into a user's settings.
+	//
+	// Parameters:
+	//   - ctx: Context for the operation
+	//   - userID: The ID of the user to copy the patterns to
+	//   - keys: The keys of the catalog patterns to copy
+	//
+	// Returns:
+	//   - The outcome of each key, in the order given
+	//   - An error if the patterns could not be copied
+	ImportCatalogPatterns(ctx context.Context, userID int64, keys []string) ([]models.SearchPatternImport, error)
+
 	// GetModelEntities retrieves model entities for a specific method.
 	//
 	// Parameters:
@@ -125,6 +188,18 @@ type SettingsServiceInterface interface {
 	//   - An error if retrieval fails
 	GetModelEntities(ctx context.Context, userID int64, methodID int64) ([]*models.ModelEntityWithMethod, error)
 
+	// StreamModelEntities passes model entities for a specific method to fn one at a time.
+	//
+	// Parameters:
+	//   - ctx: Context for the operation
+	//   - userID: The ID of the user who owns the entities
+	//   - methodID: The ID of the method to get entities for
+	//   - fn: Called for each entity; returning an error stops the stream
+	//
+	// Returns:
+	//   - The error returned by fn, or an error if retrieval fails
+	StreamModelEntities(ctx context.Context, userID int64, methodID int64, fn func(*models.ModelEntityWithMethod) error) error
+
 	// AddModelEntities adds model entities for a user.
 	//
 	// Parameters:
@@ -159,6 +234,19 @@ type SettingsServiceInterface interface {
 	//   - An error if the deletion fails
 	DeleteModelEntityByMethodID(ctx context.Context, userID int64, methodID int64) error
 
+	// ReplaceModelEntities replaces all model entities for a specific method in one transaction.
+	//
+	// Parameters:
+	//   - ctx: Context for the operation
+	//   - userID: The ID of the user who owns the entities
+	//   - methodID: The ID of the method whose entities to replace
+	//   - replace: The texts of the entities after the replacement
+	//
+	// Returns:
+	//   - The entities after the replacement and those created and deleted
+	//   - An error if the replacement fails
+	ReplaceModelEntities(ctx context.Context, userID int64, methodID int64, replace *models.ModelEntityReplace) (*models.ModelEntityReplacement, error)
+
 	// ExportSettings exports all settings for a user.
 	//
 	// Parameters:
@@ -170,6 +258,18 @@ type SettingsServiceInterface interface {
 	//   - An error if the export fails
 	ExportSettings(ctx context.Context, userID int64) (*models.SettingsExport, error)
 
+	// GetDetectionConfig returns the composed detection configuration of a user.
+	//
+	// Parameters:
+	//   - ctx: Context for the operation
+	//   - userID: The ID of the user whose configuration to return
+	//
+	// Returns:
+	//   - The detection configuration
+	//   - Whether it was served from the cache
+	//   - An error if retrieval fails
+	GetDetectionConfig(ctx context.Context, userID int64) (*models.DetectionConfig, bool, error)
+
 	// ImportSettings imports settings for a user.
 	//
 	// Parameters:
@@ -180,4 +280,19 @@ type SettingsServiceInterface interface {
 	// Returns:
 	//   - An error if the import fails
 	ImportSettings(ctx context.Context, userID int64, importData *models.SettingsExport) error
+
+	// GetSettingsChanges retrieves settings revisions newer than a given revision,
+	// optionally waiting for new revisions to arrive.
+	//
+	// Parameters:
+	//   - ctx: Context for the operation
+	//   - userID: The ID of the user whose change feed to read
+	//   - since: The last revision the client has already applied
+	//   - limit: The maximum number of revisions to return
+	//   - wait: How long to wait for new revisions when none are available
+	//
+	// Returns:
+	//   - The change feed
+	//   - An error if retrieval fails
+	GetSettingsChanges(ctx context.Context, userID int64, since int64, limit int, wait time.Duration) (*models.SettingsChangeFeed, error)
 }
@@ -0,0 +1,331 @@
+// Package handlers provides HTTP request handlers for the HideMe API.
+package handlers
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+
+	"github.com/yasinhessnawi1/Hideme_Backend/internal/auth"
+	"github.com/yasinhessnawi1/Hideme_Backend/internal/constants"
+	"github.com/yasinhessnawi1/Hideme_Backend/internal/models"
+	"github.com/yasinhessnawi1/Hideme_Backend/internal/utils"
+)
+
+// StatusServiceInterface defines the service methods required for the status page.
+type StatusServiceInterface interface {
+	// GetStatus returns the data behind the public status page.
+	//
+	// Parameters:
+	//   - ctx: Context for the operation
+	//
+	// Returns:
+	//   - The component states, uptime percentages and unresolved incidents
+	//   - An error if retrieval fails
+	GetStatus(ctx context.Context) (*models.PublicStatus, error)
+
+	// ListIncidents returns the most recent incidents, resolved or not.
+	//
+	// Parameters:
+	//   - ctx: Context for the operation
+	//
+	// Returns:
+	//   - The incidents, newest first
+	//   - An error if retrieval fails
+	ListIncidents(ctx context.Context) ([]*models.StatusIncident, error)
+
+	// CreateIncident publishes a new incident on the status page.
+	//
+	// Parameters:
+	//   - ctx: Context for the operation
+	//   - adminID: The ID of the administrator opening the incident
+	//   - req: The title, message, impact and optional status of the incident
+	//
+	// Returns:
+	//   - The created incident
+	//   - An error if the incident could not be stored
+	CreateIncident(ctx context.Context, adminID int64, req *models.StatusIncidentCreate) (*models.StatusIncident, error)
+
+	// UpdateIncident posts an update on an incident.
+	//
+	// Parameters:
+	//   - ctx: Context for the operation
+	//   - adminID: The ID of the administrator posting the update
+	//   - id: The ID of the incident
+	//   - req: The fields to change
+	//
+	// Returns:
+	//   - The updated incident
+	//   - NotFoundError if the incident doesn't exist
+	UpdateIncident(ctx context.Context, adminID int64, id int64, req *models.StatusIncidentUpdate) (*models.StatusIncident, error)
+
+	// DeleteIncident removes an incident from the status page.
+	//
+	// Parameters:
+	//   - ctx: Context for the operation
+	//   - id: The ID of the incident
+	//
+	// Returns:
+	//   - NotFoundError if the incident doesn't exist
+	DeleteIncident(ctx context.Context, id int64) error
+}
+
+// StatusHandler handles HTTP requests for the public status page and its incident notes.
+type StatusHandler struct {
+	statusService StatusServiceInterface
+	cacheTTL      time.Duration
+}
+
+// NewStatusHandler creates a new StatusHandler with the provided status service.
+//
+// Parameters:
+//   - statusService: Service providing the status page data
+//   - cacheTTL: How long clients and proxies may cache the public status
+//
+// Returns:
+//   - A properly initialized StatusHandler
+func NewStatusHandler(statusService StatusServiceInterface, cacheTTL time.Duration) *StatusHandler {
+	return &StatusHandler{
+		statusService: statusService,
+		cacheTTL:      cacheTTL,
+	}
+}
+
+// GetStatus returns the health of the service's components, their uptime and the
+// unresolved incidents, for display on a public status page.
+//
+// HTTP Method:
+//   - GET
+//
+// URL Path:
+//   - /api/status
+//
+// Responses:
+//   - 200 OK: Current status
+//   - 500 Internal Server Error: Server-side error
+//
+// @Summary Get service status
+// @Description Returns the state and uptime of each component and the unresolved incidents. The response is cached.
+// @Tags Status
+// @Produce json
+// @Success 200 {object} utils.Response{data=models.PublicStatus} "Current status"
+// @Failure 500 {object} utils.Response{error=string} "Server error"
+// @Router /status [get]
+func (h *StatusHandler) GetStatus(w http.ResponseWriter, r *http.Request) {
+	status, err := h.statusService.GetStatus(r.Context())
+	if err != nil {
+		utils.ErrorFromAppError(w, utils.ParseError(err))
+		return
+	}
+
+	w.Header().Set(constants.HeaderCacheControl, fmt.Sprintf(constants.CacheControlPublicFormat, int(h.cacheTTL.Seconds())))
+	utils.JSON(w, constants.StatusOK, status)
+}
+
+// ListIncidents returns the most recent status page incidents, including resolved ones.
+//
+// HTTP Method:
+//   - GET
+//
+// URL Path:
+//   - /api/admin/status/incidents
+//
+// Requires:
+//   - Authentication: Admin role
+//
+// Responses:
+//   - 200 OK: List of incidents
+//   - 401 Unauthorized: User not authenticated
+//   - 403 Forbidden: User not authorized (not admin)
+//   - 500 Internal Server Error: Server-side error
+//
+// @Summary List status incidents
+// @Description Returns the most recent status page incidents, newest first
+// @Tags Admin/Status
+// @Produce json
+// @Security BearerAuth
+// @Success 200 {object} utils.Response{data=[]models.StatusIncident} "List of incidents"
+// @Failure 401 {object} utils.Response{error=string} "User not authenticated"
+// @Failure 403 {object} utils.Response{error=string} "User not authorized"
+// @Failure 500 {object} utils.Response{error=string} "Server error"
+// @Router /admin/status/incidents [get]
+func (h *StatusHandler) ListIncidents(w http.ResponseWriter, r *http.Request) {
+	incidents, err := h.statusService.ListIncidents(r.Context())
+	if err != nil {
+		utils.ErrorFromAppError(w, utils.ParseError(err))
+		return
+	}
+
+	utils.ListAll(w, r, incidents)
+}
+
+// CreateIncident publishes a new incident note on the status page.
+//
+// HTTP Method:
+//   - POST
+//
+// URL Path:
+//   - /api/admin/status/incidents
+//
+// Requires:
+//   - Authentication: Admin role
+//
+// Request Body:
+//   - JSON object conforming to models.StatusIncidentCreate
+//
+// Responses:
+//   - 201 Created: Incident published
+//   - 400 Bad Request: Invalid request body
+//   - 401 Unauthorized: User not authenticated
+//   - 403 Forbidden: User not authorized (not admin)
+//   - 500 Internal Server Error: Server-side error
+//
+// @Summary Create status incident
+// @Description Publishes a new incident note on the public status page
+// @Tags Admin/Status
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param incident body models.StatusIncidentCreate true "Incident details"
+// @Success 201 {object} utils.Response{data=models.StatusIncident} "Incident published"
+// @Failure 400 {object} utils.Response{error=string} "Invalid request body"
+// @Failure 401 {object} utils.Response{error=string} "User not authenticated"
+// @Failure 403 {object} utils.Response{error=string} "User not authorized"
+// @Failure 500 {object} utils.Response{error=string} "Server error"
+// @Router /admin/status/incidents [post]
+func (h *StatusHandler) CreateIncident(w http.ResponseWriter, r *http.Request) {
+	adminID, ok := auth.GetUserID(r)
+	if !ok {
+		utils.Unauthorized(w, constants.MsgAuthRequired)
+		return
+	}
+
+	var req models.StatusIncidentCreate
+	if err := utils.DecodeAndValidate(r, &req); err != nil {
+		utils.ErrorFromAppError(w, utils.ParseError(err))
+		return
+	}
+
+	incident, err := h.statusService.CreateIncident(r.Context(), adminID, &req)
+	if err != nil {
+		utils.ErrorFromAppError(w, utils.ParseError(err))
+		return
+	}
+
+	utils.JSON(w, constants.StatusCreated, incident)
+}
+
+// UpdateIncident posts an update on a status page incident, such as a new message or its resolution.
+//
+// HTTP Method:
+//   - PUT
+//
+// URL Path:
+//   - /api/admin/status/incidents/{id}
+//
+// Requires:
+//   - Authentication: Admin role
+//
+// Request Body:
+//   - JSON object conforming to models.StatusIncidentUpdate
+//
+// Responses:
+//   - 200 OK: Incident updated
+//   - 400 Bad Request: Invalid incident ID or request body
+//   - 401 Unauthorized: User not authenticated
+//   - 403 Forbidden: User not authorized (not admin)
+//   - 404 Not Found: Incident not found
+//   - 500 Internal Server Error: Server-side error
+//
+// @Summary Update status incident
+// @Description Updates the message, impact or status of a status page incident
+// @Tags Admin/Status
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path int true "Incident ID"
+// @Param incident body models.StatusIncidentUpdate true "Changes to the incident"
+// @Success 200 {object} utils.Response{data=models.StatusIncident} "Incident updated"
+// @Failure 400 {object} utils.Response{error=string} "Invalid request"
+// @Failure 401 {object} utils.Response{error=string} "User not authenticated"
+// @Failure 403 {object} utils.Response{error=string} "User not authorized"
+// @Failure 404 {object} utils.Response{error=string} "Incident not found"
+// @Failure 500 {object} utils.Response{error=string} "Server error"
+// @Router /admin/status/incidents/{id} [put]
+func (h *StatusHandler) UpdateIncident(w http.ResponseWriter, r *http.Request) {
+	adminID, ok := auth.GetUserID(r)
+	if !ok {
+		utils.Unauthorized(w, constants.MsgAuthRequired)
+		return
+	}
+
+	incidentID, err := strconv.ParseInt(chi.URLParam(r, constants.ParamID), 10, 64)
+	if err != nil {
+		utils.BadRequest(w, "Invalid incident ID", nil)
+		return
+	}
+
+	var req models.StatusIncidentUpdate
+	if err := utils.DecodeAndValidate(r, &req); err != nil {
+		utils.ErrorFromAppError(w, utils.ParseError(err))
+		return
+	}
+
+	incident, err := h.statusService.UpdateIncident(r.Context(), adminID, incidentID, &req)
+	if err != nil {
+		utils.ErrorFromAppError(w, utils.ParseError(err))
+		return
+	}
+
+	utils.JSON(w, constants.StatusOK, incident)
+}
+
+// DeleteIncident removes an incident note from the status page, e.g. one opened by mistake.
+//
+// HTTP Method:
+//   - DELETE
+//
+// URL Path:
+//   - /api/admin/status/incidents/{id}
+//
+// Requires:
+//   - Authentication: Admin role
+//
+// Responses:
+//   - 204 No Content: Incident removed
+//   - 400 Bad Request: Invalid incident ID
+//   - 401 Unauthorized: User not authenticated
+//   - 403 Forbidden: User not authorized (not admin)
+//   - 404 Not Found: Incident not found
+//   - 500 Internal Server Error: Server-side error
+//
+// @Summary Delete status incident
+// @Description Removes an incident note from the public status page
+// @Tags Admin/Status
+// @Security BearerAuth
+// @Param id path int true "Incident ID"
+// @Success 204 "Incident removed"
+// @Failure 400 {object} utils.Response{error=string} "Invalid incident ID"
+// @Failure 401 {object} utils.Response{error=string} "User not authenticated"
+// @Failure 403 {object} utils.Response{error=string} "User not authorized"
+// @Failure 404 {object} utils.Response{error=string} "Incident not found"
+// @Failure 500 {object} utils.Response{error=string} "Server error"
+// @Router /admin/status/incidents/{id} [delete]
+func (h *StatusHandler) DeleteIncident(w http.ResponseWriter, r *http.Request) {
+	incidentID, err := strconv.ParseInt(chi.URLParam(r, constants.ParamID), 10, 64)
+	if err != nil {
+		utils.BadRequest(w, "Invalid incident ID", nil)
+		return
+	}
+
+	if err := h.statusService.DeleteIncident(r.Context(), incidentID); err != nil {
+		utils.ErrorFromAppError(w, utils.ParseError(err))
+		return
+	}
+
+	utils.NoContent(w)
+}
@@ -0,0 +1,245 @@
+package handlers_test
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+
+	"github.com/yasinhessnawi1/Hideme_Backend/internal/handlers"
+	"github.com/yasinhessnawi1/Hideme_Backend/internal/models"
+	"github.com/yasinhessnawi1/Hideme_Backend/internal/testsupport/golden"
+	"github.com/yasinhessnawi1/Hideme_Backend/internal/utils"
+)
+
+// MockStatusService is a mock implementation of the StatusService
+type MockStatusService struct {
+	mock.Mock
+}
+
+func (m *MockStatusService) GetStatus(ctx context.Context) (*models.PublicStatus, error) {
+	args := m.Called(ctx)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*models.PublicStatus), args.Error(1)
+}
+
+func (m *MockStatusService) ListIncidents(ctx context.Context) ([]*models.StatusIncident, error) {
+	args := m.Called(ctx)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]*models.StatusIncident), args.Error(1)
+}
+
+func (m *MockStatusService) CreateIncident(ctx context.Context, adminID int64, req *models.StatusIncidentCreate) (*models.StatusIncident, error) {
+	args := m.Called(ctx, adminID, req)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*models.StatusIncident), args.Error(1)
+}
+
+func (m *MockStatusService) UpdateIncident(ctx context.Context, adminID int64, id int64, req *models.StatusIncidentUpdate) (*models.StatusIncident, error) {
+	args := m.Called(ctx, adminID, id, req)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*models.StatusIncident), args.Error(1)
+}
+
+func (m *MockStatusService) DeleteIncident(ctx context.Context, id int64) error {
+	args := m.Called(ctx, id)
+	return args.Error(0)
+}
+
+func setupStatusTest() (*chi.Mux, *MockStatusService) {
+	mockService := new(MockStatusService)
+	handler := handlers.NewStatusHandler(mockService, 30*time.Second)
+
+	router := chi.NewRouter()
+	router.Get("/api/status", handler.GetStatus)
+	router.Get("/api/admin/status/incidents", handler.ListIncidents)
+	router.Post("/api/admin/status/incidents", handler.CreateIncident)
+	router.Put("/api/admin/status/incidents/{id}", handler.UpdateIncident)
+	router.Delete("/api/admin/status/incidents/{id}", handler.DeleteIncident)
+
+	return router, mockService
+}
+
+func TestGetStatus(t *testing.T) {
+	router, mockService := setupStatusTest()
+
+	t.Run("Success", func(t *testing.T) {
+		uptime := 99.9
+		creator := int64(1)
+		status := &models.PublicStatus{
+			Status: models.ComponentDegraded,
+			Components: []*models.ComponentStatus{
+				{Name: "database", Status: models.ComponentDegraded, Uptime: models.UptimePercentages{Day: &uptime}},
+			},
+			Incidents: []*models.StatusIncident{
+				{ID: 2, Title: "Slow queries", Impact: models.IncidentImpactMajor, Status: models.IncidentIdentified, CreatedBy: &creator},
+			},
+		}
+		mockService.On("GetStatus", mock.Anything).Return(status, nil).Once()
+
+		// No authentication is required
+		req, err := http.NewRequest("GET", "/api/status", nil)
+		require.NoError(t, err)
+
+		rr := httptest.NewRecorder()
+		router.ServeHTTP(rr, req)
+
+		assert.Equal(t, http.StatusOK, rr.Code)
+		assert.Equal(t, "public, max-age=30", rr.Header().Get("Cache-Control"))
+		golden.AssertResponse(t, rr)
+	})
+
+	t.Run("Service Error", func(t *testing.T) {
+		mockService.On("GetStatus", mock.Anything).Return(nil, errors.New("database error")).Once()
+
+		req, err := http.NewRequest("GET", "/api/status", nil)
+		require.NoError(t, err)
+
+		rr := httptest.NewRecorder()
+		router.ServeHTTP(rr, req)
+
+		assert.Equal(t, http.StatusInternalServerError, rr.Code)
+		assert.Empty(t, rr.Header().Get("Cache-Control"))
+	})
+}
+
+func TestListStatusIncidents(t *testing.T) {
+	router, mockService := setupStatusTest()
+
+	incidents := []*models.StatusIncident{{ID: 1, Title: "Outage", Status: models.IncidentResolved}}
+	mockService.On("ListIncidents", mock.Anything).Return(incidents, nil).Once()
+
+	req, err := http.NewRequest("GET", "/api/admin/status/incidents", nil)
+	require.NoError(t, err)
+	req = req.WithContext(createAuthContext(1))
+
+	rr := httptest.NewRecorder()
+	router.ServeHTTP(rr, req)
+
+	golden.AssertResponse(t, rr)
+	mockService.AssertExpectations(t)
+}
+
+func TestCreateStatusIncident(t *testing.T) {
+	router, mockService := setupStatusTest()
+
+	t.Run("Success", func(t *testing.T) {
+		expected := &models.StatusIncidentCreate{Title: "Detection outage", Message: "Investigating", Impact: models.IncidentImpactCritical}
+		incident := &models.StatusIncident{ID: 5, Title: "Detection outage", Impact: models.IncidentImpactCritical, Status: models.IncidentInvestigating}
+		mockService.On("CreateIncident", mock.Anything, int64(1), expected).Return(incident, nil).Once()
+
+		body, _ := json.Marshal(map[string]string{"title": "Detection outage", "message": "Investigating", "impact": "critical"})
+		req, err := http.NewRequest("POST", "/api/admin/status/incidents", bytes.NewBuffer(body))
+		require.NoError(t, err)
+		req.Header.Set("Content-Type", "application/json")
+		req = req.WithContext(createAuthContext(1))
+
+		rr := httptest.NewRecorder()
+		router.ServeHTTP(rr, req)
+
+		golden.AssertResponse(t, rr)
+	})
+
+	t.Run("Invalid Impact", func(t *testing.T) {
+		body, _ := json.Marshal(map[string]string{"title": "Outage", "message": "Investigating", "impact": "apocalyptic"})
+		req, err := http.NewRequest("POST", "/api/admin/status/incidents", bytes.NewBuffer(body))
+		require.NoError(t, err)
+		req.Header.Set("Content-Type", "application/json")
+		req = req.WithContext(createAuthContext(1))
+
+		rr := httptest.NewRecorder()
+		router.ServeHTTP(rr, req)
+
+		assert.Equal(t, http.StatusBadRequest, rr.Code)
+	})
+
+	t.Run("Unauthenticated", func(t *testing.T) {
+		req, err := http.NewRequest("POST", "/api/admin/status/incidents", bytes.NewBufferString(`{}`))
+		require.NoError(t, err)
+
+		rr := httptest.NewRecorder()
+		router.ServeHTTP(rr, req)
+
+		assert.Equal(t, http.StatusUnauthorized, rr.Code)
+	})
+}
+
+func TestUpdateStatusIncident(t *testing.T) {
+	router, mockService := setupStatusTest()
+
+	t.Run("Success", func(t *testing.T) {
+		expected := &models.StatusIncidentUpdate{Message: "Fixed", Status: models.IncidentResolved}
+		incident := &models.StatusIncident{ID: 5, Message: "Fixed", Status: models.IncidentResolved}
+		mockService.On("UpdateIncident", mock.Anything, int64(1), int64(5), expected).Return(incident, nil).Once()
+
+		body, _ := json.Marshal(map[string]string{"message": "Fixed", "status": "resolved"})
+		req, err := http.NewRequest("PUT", "/api/admin/status/incidents/5", bytes.NewBuffer(body))
+		require.NoError(t, err)
+		req.Header.Set("Content-Type", "application/json")
+		req = req.WithContext(createAuthContext(1))
+
+		rr := httptest.NewRecorder()
+		router.ServeHTTP(rr, req)
+
+		golden.AssertResponse(t, rr)
+	})
+
+	t.Run("Not Found", func(t *testing.T) {
+		mockService.On("UpdateIncident", mock.Anything, int64(1), int64(6), mock.Anything).
+			Return(nil, utils.NewNotFoundError("StatusIncident", int64(6))).Once()
+
+		req, err := http.NewRequest("PUT", "/api/admin/status/incidents/6", bytes.NewBufferString(`{"message":"Update"}`))
+		require.NoError(t, err)
+		req.Header.Set("Content-Type", "application/json")
+		req = req.WithContext(createAuthContext(1))
+
+		rr := httptest.NewRecorder()
+		router.ServeHTTP(rr, req)
+
+		assert.Equal(t, http.StatusNotFound, rr.Code)
+	})
+
+	t.Run("Invalid ID", func(t *testing.T) {
+		req, err := http.NewRequest("PUT", "/api/admin/status/incidents/abc", bytes.NewBufferString(`{}`))
+		require.NoError(t, err)
+		req = req.WithContext(createAuthContext(1))
+
+		rr := httptest.NewRecorder()
+		router.ServeHTTP(rr, req)
+
+		assert.Equal(t, http.StatusBadRequest, rr.Code)
+	})
+}
+
+func TestDeleteStatusIncident(t *testing.T) {
+	router, mockService := setupStatusTest()
+
+	mockService.On("DeleteIncident", mock.Anything, int64(5)).Return(nil).Once()
+
+	req, err := http.NewRequest("DELETE", "/api/admin/status/incidents/5", nil)
+	require.NoError(t, err)
+	req = req.WithContext(createAuthContext(1))
+
+	rr := httptest.NewRecorder()
+	router.ServeHTTP(rr, req)
+
+	assert.Equal(t, http.StatusNoContent, rr.Code)
+	mockService.AssertExpectations(t)
+}
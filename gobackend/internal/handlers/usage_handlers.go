@@ -0,0 +1,146 @@
+// Package handlers provides HTTP request handlers for the HideMe API.
+package handlers
+
+import (
+	"bytes"
+	"context"
+	"encoding/csv"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/rs/zerolog/log"
+
+	"github.com/yasinhessnawi1/Hideme_Backend/internal/constants"
+	"github.com/yasinhessnawi1/Hideme_Backend/internal/models"
+	"github.com/yasinhessnawi1/Hideme_Backend/internal/utils"
+)
+
+// UsageServiceInterface defines the service methods required for tenant usage operations.
+type UsageServiceInterface interface {
+	// GetMonthlyUsage retrieves the usage rollups of all tenants for a month.
+	//
+	// Parameters:
+	//   - ctx: Context for the operation
+	//   - month: Any point in time within the requested month
+	//
+	// Returns:
+	//   - The rollups ordered by user ID
+	//   - An error if retrieval fails
+	GetMonthlyUsage(ctx context.Context, month time.Time) ([]*models.TenantUsage, error)
+}
+
+// UsageHandler handles HTTP requests related to tenant usage and billing.
+type UsageHandler struct {
+	usageService UsageServiceInterface
+}
+
+// NewUsageHandler creates a new UsageHandler with the provided usage service.
+//
+// Parameters:
+//   - usageService: Service handling usage operations
+//
+// Returns:
+//   - A properly initialized UsageHandler
+func NewUsageHandler(usageService UsageServiceInterface) *UsageHandler {
+	return &UsageHandler{
+		usageService: usageService,
+	}
+}
+
+// ExportBilling returns the monthly usage of all tenants as a CSV file for the billing system.
+//
+// HTTP Method:
+//   - GET
+//
+// URL Path:
+//   - /api/admin/billing-export
+//
+// Query Parameters:
+//   - month: The month to export in YYYY-MM format (default: the previous month)
+//   - locale: The regional formatting of dates and numbers, e.g. de-DE (default: ISO formats)
+//   - csv_delimiter: comma or semicolon (default: the locale's delimiter)
+//
+// Requires:
+//   - Authentication: Admin role
+//
+// Responses:
+//   - 200 OK: CSV file with one row per tenant
+//   - 400 Bad Request: Invalid month, locale or delimiter parameter
+//   - 401 Unauthorized: User not authenticated
+//   - 403 Forbidden: User not authorized (not admin)
+//   - 500 Internal Server Error: Server-side error
+//
+// @Summary Export billing usage
+// @Description Returns monthly per-tenant usage (documents, pages, storage, API calls) as CSV
+// @Tags Admin/Billing
+// @Produce text/csv
+// @Security BearerAuth
+// @Param month query string false "Month to export (YYYY-MM), defaults to the previous month"
+// @Param locale query string false "Regional formatting of dates and numbers (e.g. de-DE), defaults to ISO formats"
+// @Param csv_delimiter query string false "CSV delimiter (comma or semicolon), defaults to the locale's delimiter"
+// @Success 200 {file} file "Billing CSV"
+// @Failure 400 {object} utils.Response{error=string} "Invalid month, locale or delimiter parameter"
+// @Failure 401 {object} utils.Response{error=string} "User not authenticated"
+// @Failure 403 {object} utils.Response{error=string} "User not authorized"
+// @Failure 500 {object} utils.Response{error=string} "Server error"
+// @Router /admin/billing-export [get]
+func (h *UsageHandler) ExportBilling(w http.ResponseWriter, r *http.Request) {
+	// Default to the last complete month, which is what billing runs on
+	month := models.UsageMonthOf(time.Now()).AddDate(0, -1, 0)
+	if monthStr := r.URL.Query().Get(constants.QueryParamMonth); monthStr != "" {
+		parsed, err := time.Parse(constants.BillingMonthFormat, monthStr)
+		if err != nil {
+			utils.BadRequest(w, "Invalid month parameter, expected YYYY-MM", nil)
+			return
+		}
+		month = parsed
+	}
+
+	locale := models.Locale(r.URL.Query().Get(constants.QueryParamLocale))
+	if !locale.IsSupported() {
+		utils.BadRequest(w, "Unsupported locale parameter", nil)
+		return
+	}
+	delimiter := models.CSVDelimiter(r.URL.Query().Get(constants.QueryParamCSVDelimiter))
+	if delimiter != "" && delimiter.Rune() == 0 {
+		utils.BadRequest(w, "Invalid csv_delimiter parameter, expected comma or semicolon", nil)
+		return
+	}
+	lf := models.NewLocaleFormat(locale, delimiter)
+
+	// Get the usage rollups
+	usage, err := h.usageService.GetMonthlyUsage(r.Context(), month)
+	if err != nil {
+		utils.ErrorFromAppError(w, utils.ParseError(err))
+		return
+	}
+
+	// Render the CSV before writing headers so errors can still be reported as JSON
+	var buf bytes.Buffer
+	writer := csv.NewWriter(&buf)
+	writer.Comma = lf.CSVDelimiter
+	if err := writer.Write(models.BillingCSVHeader()); err != nil {
+		utils.ErrorFromAppError(w, utils.ParseError(err))
+		return
+	}
+	for _, row := range usage {
+		if err := writer.Write(row.CSVRecord(lf)); err != nil {
+			utils.ErrorFromAppError(w, utils.ParseError(err))
+			return
+		}
+	}
+	writer.Flush()
+	if err := writer.Error(); err != nil {
+		utils.ErrorFromAppError(w, utils.ParseError(err))
+		return
+	}
+
+	filename := fmt.Sprintf("%s%s.csv", constants.BillingExportFilenamePrefix, month.Format(constants.BillingMonthFormat))
+	w.Header().Set(constants.HeaderContentType, constants.ContentTypeCSV)
+	w.Header().Set(constants.HeaderContentDisposition, fmt.Sprintf("attachment; filename=%s", filename))
+	w.WriteHeader(constants.StatusOK)
+	if _, err := w.Write(buf.Bytes()); err != nil {
+		log.Error().Err(err).Msg("Failed to write billing export")
+	}
+}
@@ -0,0 +1,92 @@
+package handlers_test
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+
+	"github.com/yasinhessnawi1/Hideme_Backend/internal/handlers"
+	"github.com/yasinhessnawi1/Hideme_Backend/internal/models"
+)
+
+// MockUsageService is a mock implementation of the UsageService
+type MockUsageService struct {
+	mock.Mock
+}
+
+func (m *MockUsageService) GetMonthlyUsage(ctx context.Context, month time.Time) ([]*models.TenantUsage, error) {
+	args := m.Called(ctx, month)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]*models.TenantUsage), args.Error(1)
+}
+
+func setupUsageTest() (*chi.Mux, *MockUsageService) {
+	mockService := new(MockUsageService)
+	handler := handlers.NewUsageHandler(mockService)
+
+	router := chi.NewRouter()
+	router.Get("/api/admin/billing-export", handler.ExportBilling)
+
+	return router, mockService
+}
+
+func TestExportBilling(t *testing.T) {
+	month := time.Date(2024, time.May, 1, 0, 0, 0, 0, time.UTC)
+	usage := []*models.TenantUsage{
+		{UserID: 7, Username: "ola", Email: "ola@example.com", UsageMonth: month, DocumentCount: 3, PageCount: 12, StorageBytes: 2048, APICallCount: 40},
+	}
+
+	tests := []struct {
+		name     string
+		query    string
+		expected string
+	}{
+		{name: "Default formatting", query: "?month=2024-05", expected: "2024-05,7,ola,ola@example.com,3,12,2048,40\n"},
+		{name: "German locale", query: "?month=2024-05&locale=de-DE", expected: "05.2024;7;ola;ola@example.com;3;12;2048;40\n"},
+		{name: "German locale with commas", query: "?month=2024-05&locale=de-DE&csv_delimiter=comma", expected: "05.2024,7,ola,ola@example.com,3,12,2048,40\n"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			router, mockService := setupUsageTest()
+			mockService.On("GetMonthlyUsage", mock.Anything, month).Return(usage, nil).Once()
+
+			req, err := http.NewRequest("GET", "/api/admin/billing-export"+tt.query, nil)
+			require.NoError(t, err)
+			req = req.WithContext(createAuthContext(1))
+
+			rr := httptest.NewRecorder()
+			router.ServeHTTP(rr, req)
+
+			assert.Equal(t, http.StatusOK, rr.Code)
+			assert.Equal(t, "attachment; filename=hideme-billing-2024-05.csv", rr.Header().Get("Content-Disposition"))
+			assert.Contains(t, rr.Body.String(), tt.expected)
+			mockService.AssertExpectations(t)
+		})
+	}
+
+	t.Run("Invalid Parameters", func(t *testing.T) {
+		router, mockService := setupUsageTest()
+
+		for _, query := range []string{"?month=May", "?locale=xx-XX", "?csv_delimiter=tab"} {
+			req, err := http.NewRequest("GET", "/api/admin/billing-export"+query, nil)
+			require.NoError(t, err)
+			req = req.WithContext(createAuthContext(1))
+
+			rr := httptest.NewRecorder()
+			router.ServeHTTP(rr, req)
+
+			assert.Equal(t, http.StatusBadRequest, rr.Code, query)
+		}
+		mockService.AssertNotCalled(t, "GetMonthlyUsage", mock.Anything, mock.Anything)
+	})
+}
@@ -0,0 +1,426 @@
+// Package handlers provides HTTP request handlers for the HideMe API.
+package handlers
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/rs/zerolog/log"
+
+	"github.com/yasinhessnawi1/Hideme_Backend/internal/auth"
+	"github.com/yasinhessnawi1/Hideme_Backend/internal/constants"
+	"github.com/yasinhessnawi1/Hideme_Backend/internal/models"
+	"github.com/yasinhessnawi1/Hideme_Backend/internal/utils"
+)
+
+// UserDataExportServiceInterface defines the service methods required for user data exports.
+type UserDataExportServiceInterface interface {
+	// ExportUserData writes the export of all data kept about a user.
+	//
+	// Parameters:
+	//   - ctx: Context for the operation
+	//   - userID: The ID of the user
+	//   - format: constants.DocumentExportFormatJSON or constants.DocumentExportFormatZIP
+	//   - open: Called before anything is written; returns the writer receiving the export
+	//
+	// Returns:
+	//   - An error if the export could not be read or written
+	ExportUserData(ctx context.Context, userID int64, format string, open func() io.Writer) error
+
+	// CreateJob requests a background export of all data kept about a user.
+	//
+	// Parameters:
+	//   - ctx: Context for the operation
+	//   - userID: The ID of the user
+	//   - format: constants.DocumentExportFormatJSON or constants.DocumentExportFormatZIP
+	//
+	// Returns:
+	//   - The pending job
+	//   - A conflict error if the user already has an unfinished job
+	CreateJob(ctx context.Context, userID int64, format string) (*models.UserDataExportJob, error)
+
+	// GetJob retrieves an export job of a user with its checkpoint.
+	//
+	// Parameters:
+	//   - ctx: Context for the operation
+	//   - userID: The ID of the user
+	//   - id: The ID of the job
+	//
+	// Returns:
+	//   - The job
+	//   - NotFoundError if the user has no such job
+	GetJob(ctx context.Context, userID, id int64) (*models.UserDataExportJob, error)
+
+	// ListJobs retrieves the export jobs of a user, newest first.
+	//
+	// Parameters:
+	//   - ctx: Context for the operation
+	//   - userID: The ID of the user
+	//
+	// Returns:
+	//   - The jobs
+	//   - An error if retrieval fails
+	ListJobs(ctx context.Context, userID int64) ([]*models.UserDataExportJob, error)
+
+	// DownloadJob writes the file of a completed export job.
+	//
+	// Parameters:
+	//   - ctx: Context for the operation
+	//   - userID: The ID of the user
+	//   - id: The ID of the job
+	//   - open: Called before anything is written; returns the writer receiving the export
+	//
+	// Returns:
+	//   - NotFoundError if the user has no such job, a conflict error if it has not completed
+	//   - Other errors if the export could not be written
+	DownloadJob(ctx context.Context, userID, id int64, open func(*models.UserDataExportJob) io.Writer) error
+}
+
+// UserDataExportHandler handles HTTP requests for the export of all data kept about a user.
+type UserDataExportHandler struct {
+	exportService UserDataExportServiceInterface
+}
+
+// NewUserDataExportHandler creates a new UserDataExportHandler with the provided export service.
+//
+// Parameters:
+//   - exportService: Service assembling user data exports
+//
+// Returns:
+//   - A properly initialized UserDataExportHandler
+func NewUserDataExportHandler(exportService UserDataExportServiceInterface) *UserDataExportHandler {
+	return &UserDataExportHandler{
+		exportService: exportService,
+	}
+}
+
+// ExportMyData streams everything kept about the current user as one downloadable file:
+// the profile, settings, ban list, search patterns, model entities, document metadata and
+// detected entities. The optional "format" query parameter selects a single JSON file
+// (default) or a ZIP archive with one JSON file per section.
+//
+// HTTP Method:
+//   - GET
+//
+// URL Path:
+//   - /api/users/me/export
+//
+// Requires:
+//   - Authentication: User must be logged in
+//
+// Responses:
+//   - 200 OK: Export streamed; a file that fails to parse was cut off by an error
+//   - 400 Bad Request: Unsupported format
+//   - 401 Unauthorized: User not authenticated
+//   - 404 Not Found: User account no longer exists
+//   - 500 Internal Server Error: Server-side error
+//
+// @Summary Export my data
+// @Description Streams all data kept about the current user as a JSON file or ZIP archive (GDPR Article 15)
+// @Tags Users
+// @Produce json,application/zip
+// @Security BearerAuth
+// @Param format query string false "json (default) or zip"
+// @Success 200 {file} file "Data export"
+// @Failure 400 {object} utils.Response{error=string} "Unsupported format"
+// @Failure 401 {object} utils.Response{error=string} "User not authenticated"
+// @Failure 404 {object} utils.Response{error=string} "User account no longer exists"
+// @Failure 500 {object} utils.Response{error=string} "Server error"
+// @Router /users/me/export [get]
+func (h *UserDataExportHandler) ExportMyData(w http.ResponseWriter, r *http.Request) {
+	userID, ok := auth.GetUserID(r)
+	if !ok {
+		utils.Unauthorized(w, constants.MsgAuthRequired)
+		return
+	}
+
+	format, ok := exportFormat(w, r)
+	if !ok {
+		return
+	}
+
+	started := false
+	open := func() io.Writer {
+		started = true
+		return openExportDownload(w, fmt.Sprintf("%s%d.%s", constants.UserDataExportFilenamePrefix, userID, format), format)
+	}
+
+	log.Info().Int64("user_id", userID).Str("format", format).Msg("Exporting user data")
+	err := h.exportService.ExportUserData(r.Context(), userID, format, open)
+	finishExportDownload(w, userID, started, err)
+}
+
+// CreateMyExport starts a background export of everything kept about the current user, for
+// accounts too large to export in a single request. The job stores its progress as it goes;
+// if the server restarts, it resumes from its last checkpoint. Poll the job until it is
+// completed, then download the file.
+//
+// HTTP Method:
+//   - POST
+//
+// URL Path:
+//   - /api/users/me/exports
+//
+// Requires:
+//   - Authentication: User must be logged in
+//
+// Responses:
+//   - 202 Accepted: Job created
+//   - 400 Bad Request: Unsupported format
+//   - 401 Unauthorized: User not authenticated
+//   - 409 Conflict: Another export of the user is unfinished
+//   - 500 Internal Server Error: Server-side error
+//
+// @Summary Start an export of my data
+// @Description Starts a background export of all data kept about the current user (GDPR Article 15)
+// @Tags Users
+// @Produce json
+// @Security BearerAuth
+// @Param format query string false "json (default) or zip"
+// @Success 202 {object} utils.Response{data=models.UserDataExportJob} "Job created"
+// @Failure 400 {object} utils.Response{error=string} "Unsupported format"
+// @Failure 401 {object} utils.Response{error=string} "User not authenticated"
+// @Failure 409 {object} utils.Response{error=string} "Another export is unfinished"
+// @Failure 500 {object} utils.Response{error=string} "Server error"
+// @Router /users/me/exports [post]
+func (h *UserDataExportHandler) CreateMyExport(w http.ResponseWriter, r *http.Request) {
+	userID, ok := auth.GetUserID(r)
+	if !ok {
+		utils.Unauthorized(w, constants.MsgAuthRequired)
+		return
+	}
+
+	format, ok := exportFormat(w, r)
+	if !ok {
+		return
+	}
+
+	job, err := h.exportService.CreateJob(r.Context(), userID, format)
+	if err != nil {
+		utils.ErrorFromAppError(w, utils.ParseError(err))
+		return
+	}
+
+	utils.JSON(w, constants.StatusAccepted, job)
+}
+
+// ListMyExports returns the export jobs of the current user, newest first.
+//
+// HTTP Method:
+//   - GET
+//
+// URL Path:
+//   - /api/users/me/exports
+//
+// Requires:
+//   - Authentication: User must be logged in
+//
+// Responses:
+//   - 200 OK: Jobs returned
+//   - 401 Unauthorized: User not authenticated
+//   - 500 Internal Server Error: Server-side error
+//
+// @Summary List my exports
+// @Description Lists the background exports of the current user with their progress
+// @Tags Users
+// @Produce json
+// @Security BearerAuth
+// @Success 200 {object} utils.Response{data=[]models.UserDataExportJob} "Jobs"
+// @Failure 401 {object} utils.Response{error=string} "User not authenticated"
+// @Failure 500 {object} utils.Response{error=string} "Server error"
+// @Router /users/me/exports [get]
+func (h *UserDataExportHandler) ListMyExports(w http.ResponseWriter, r *http.Request) {
+	userID, ok := auth.GetUserID(r)
+	if !ok {
+		utils.Unauthorized(w, constants.MsgAuthRequired)
+		return
+	}
+
+	jobs, err := h.exportService.ListJobs(r.Context(), userID)
+	if err != nil {
+		utils.ErrorFromAppError(w, utils.ParseError(err))
+		return
+	}
+
+	utils.ListAll(w, r, jobs)
+}
+
+// GetMyExport returns the status of an export job of the current user with its checkpoint:
+// the last document and detected entity exported, how many were exported so far, and how
+// often the job resumed after a restart.
+//
+// HTTP Method:
+//   - GET
+//
+// URL Path:
+//   - /api/users/me/exports/{id}
+//
+// Requires:
+//   - Authentication: User must be logged in
+//
+// Responses:
+//   - 200 OK: Job returned
+//   - 400 Bad Request: Invalid job ID
+//   - 401 Unauthorized: User not authenticated
+//   - 404 Not Found: The user has no such job
+//   - 500 Internal Server Error: Server-side error
+//
+// @Summary Get my export
+// @Description Returns the status and checkpoint of a background export of the current user
+// @Tags Users
+// @Produce json
+// @Security BearerAuth
+// @Param id path int true "Export job ID"
+// @Success 200 {object} utils.Response{data=models.UserDataExportJob} "Job"
+// @Failure 400 {object} utils.Response{error=string} "Invalid job ID"
+// @Failure 401 {object} utils.Response{error=string} "User not authenticated"
+// @Failure 404 {object} utils.Response{error=string} "Job not found"
+// @Failure 500 {object} utils.Response{error=string} "Server error"
+// @Router /users/me/exports/{id} [get]
+func (h *UserDataExportHandler) GetMyExport(w http.ResponseWriter, r *http.Request) {
+	userID, ok := auth.GetUserID(r)
+	if !ok {
+		utils.Unauthorized(w, constants.MsgAuthRequired)
+		return
+	}
+
+	jobID, err := strconv.ParseInt(chi.URLParam(r, constants.ParamID), 10, 64)
+	if err != nil {
+		utils.BadRequest(w, "Invalid export ID", nil)
+		return
+	}
+
+	job, err := h.exportService.GetJob(r.Context(), userID, jobID)
+	if err != nil {
+		utils.ErrorFromAppError(w, utils.ParseError(err))
+		return
+	}
+
+	utils.JSON(w, constants.StatusOK, job)
+}
+
+// DownloadMyExport streams the file of a completed export job of the current user.
+//
+// HTTP Method:
+//   - GET
+//
+// URL Path:
+//   - /api/users/me/exports/{id}/download
+//
+// Requires:
+//   - Authentication: User must be logged in
+//
+// Responses:
+//   - 200 OK: Export streamed; a file that fails to parse was cut off by an error
+//   - 400 Bad Request: Invalid job ID
+//   - 401 Unauthorized: User not authenticated
+//   - 404 Not Found: The user has no such job
+//   - 409 Conflict: The job has not completed
+//   - 500 Internal Server Error: Server-side error
+//
+// @Summary Download my export
+// @Description Streams the file of a completed background export of the current user
+// @Tags Users
+// @Produce json,application/zip
+// @Security BearerAuth
+// @Param id path int true "Export job ID"
+// @Success 200 {file} file "Data export"
+// @Failure 400 {object} utils.Response{error=string} "Invalid job ID"
+// @Failure 401 {object} utils.Response{error=string} "User not authenticated"
+// @Failure 404 {object} utils.Response{error=string} "Job not found"
+// @Failure 409 {object} utils.Response{error=string} "Job has not completed"
+// @Failure 500 {object} utils.Response{error=string} "Server error"
+// @Router /users/me/exports/{id}/download [get]
+func (h *UserDataExportHandler) DownloadMyExport(w http.ResponseWriter, r *http.Request) {
+	userID, ok := auth.GetUserID(r)
+	if !ok {
+		utils.Unauthorized(w, constants.MsgAuthRequired)
+		return
+	}
+
+	jobID, err := strconv.ParseInt(chi.URLParam(r, constants.ParamID), 10, 64)
+	if err != nil {
+		utils.BadRequest(w, "Invalid export ID", nil)
+		return
+	}
+
+	started := false
+	open := func(job *models.UserDataExportJob) io.Writer {
+		started = true
+		return openExportDownload(w, fmt.Sprintf("%s%d-%d.%s", constants.UserDataExportFilenamePrefix, userID, job.ID, job.Format), job.Format)
+	}
+
+	err = h.exportService.DownloadJob(r.Context(), userID, jobID, open)
+	finishExportDownload(w, userID, started, err)
+}
+
+// exportFormat reads the optional "format" query parameter of a user data export,
+// answering the request if it is not supported.
+func exportFormat(w http.ResponseWriter, r *http.Request) (string, bool) {
+	format := strings.ToLower(r.URL.Query().Get(constants.QueryParamFormat))
+	if format == "" {
+		format = constants.DocumentExportFormatJSON
+	}
+	if format != constants.DocumentExportFormatJSON && format != constants.DocumentExportFormatZIP {
+		utils.BadRequest(w, constants.MsgInvalidExportFormat, nil)
+		return "", false
+	}
+	return format, true
+}
+
+// openExportDownload sends the headers of a user data export download and returns the
+// writer receiving the file.
+func openExportDownload(w http.ResponseWriter, filename, format string) io.Writer {
+	contentType := constants.ContentTypeJSON
+	if format == constants.DocumentExportFormatZIP {
+		contentType = constants.ContentTypeZIP
+	}
+	w.Header().Set(constants.HeaderContentType, contentType)
+	w.Header().Set(constants.HeaderContentDisposition, fmt.Sprintf("attachment; filename=%s", filename))
+	w.Header().Set(constants.HeaderCacheControl, constants.CacheControlNoStore)
+	// Ask reverse proxies not to buffer the stream
+	w.Header().Set(constants.HeaderXAccelBuffering, "no")
+	w.WriteHeader(constants.StatusOK)
+	return &deadlineWriter{w: w, controller: http.NewResponseController(w)}
+}
+
+// finishExportDownload reports the outcome of a user data export download: errors before
+// the download started are answered, later ones can only be logged.
+func finishExportDownload(w http.ResponseWriter, userID int64, started bool, err error) {
+	if err == nil {
+		return
+	}
+	if !started {
+		utils.ErrorFromAppError(w, utils.ParseError(err))
+		return
+	}
+	// The status has been sent; the client notices the cut-off file
+	if errors.Is(err, context.Canceled) {
+		log.Debug().Err(err).Int64("user_id", userID).Msg("Client disconnected during user data export")
+		return
+	}
+	log.Error().Err(err).Int64("user_id", userID).Msg("User data export ended with an error")
+}
+
+// deadlineWriter gives the client constants.StreamWriteTimeout for every write of a long
+// download. Without it the server's write timeout would cut off large exports.
+type deadlineWriter struct {
+	w          io.Writer
+	controller *http.ResponseController
+}
+
+// Write extends the write deadline and writes p.
+func (d *deadlineWriter) Write(p []byte) (int, error) {
+	err := d.controller.SetWriteDeadline(time.Now().Add(constants.StreamWriteTimeout))
+	if err != nil && !errors.Is(err, http.ErrNotSupported) {
+		log.Debug().Err(err).Msg("Failed to extend export write deadline")
+	}
+	return d.w.Write(p)
+}
@@ -0,0 +1,328 @@
+package handlers_test
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/yasinhessnawi1/Hideme_Backend/internal/constants"
+	"github.com/yasinhessnawi1/Hideme_Backend/internal/handlers"
+	"github.com/yasinhessnawi1/Hideme_Backend/internal/models"
+	"github.com/yasinhessnawi1/Hideme_Backend/internal/testsupport/golden"
+	"github.com/yasinhessnawi1/Hideme_Backend/internal/utils"
+)
+
+// FakeUserDataExportService writes a fixed export, optionally failing before or after opening it
+type FakeUserDataExportService struct {
+	format     string
+	errBefore  error
+	errAfter   error
+	lastUserID int64
+
+	// job is the only export job; nil if the user has none
+	job    *models.UserDataExportJob
+	jobErr error
+}
+
+func (f *FakeUserDataExportService) ExportUserData(ctx context.Context, userID int64, format string, open func() io.Writer) error {
+	f.lastUserID = userID
+	f.format = format
+	if f.errBefore != nil {
+		return f.errBefore
+	}
+	w := open()
+	if _, err := io.WriteString(w, `{"version":1`); err != nil {
+		return err
+	}
+	if f.errAfter != nil {
+		return f.errAfter
+	}
+	_, err := io.WriteString(w, "}\n")
+	return err
+}
+
+func (f *FakeUserDataExportService) CreateJob(ctx context.Context, userID int64, format string) (*models.UserDataExportJob, error) {
+	f.lastUserID = userID
+	if f.jobErr != nil {
+		return nil, f.jobErr
+	}
+	f.job = &models.UserDataExportJob{ID: 3, UserID: userID, Format: format, Status: constants.UserDataExportPending}
+	return f.job, nil
+}
+
+func (f *FakeUserDataExportService) GetJob(ctx context.Context, userID, id int64) (*models.UserDataExportJob, error) {
+	if f.job == nil || f.job.ID != id || f.job.UserID != userID {
+		return nil, utils.NewNotFoundError("UserDataExport", id)
+	}
+	return f.job, nil
+}
+
+func (f *FakeUserDataExportService) ListJobs(ctx context.Context, userID int64) ([]*models.UserDataExportJob, error) {
+	jobs := []*models.UserDataExportJob{}
+	if f.job != nil && f.job.UserID == userID {
+		jobs = append(jobs, f.job)
+	}
+	return jobs, nil
+}
+
+func (f *FakeUserDataExportService) DownloadJob(ctx context.Context, userID, id int64, open func(*models.UserDataExportJob) io.Writer) error {
+	job, err := f.GetJob(ctx, userID, id)
+	if err != nil {
+		return err
+	}
+	if job.Status != constants.UserDataExportCompleted {
+		return utils.New(utils.ErrBadRequest, constants.StatusConflict, constants.MsgUserDataExportNotReady)
+	}
+	_, err = io.WriteString(open(job), "{\"version\":1}\n")
+	return err
+}
+
+func setupUserDataExportTest() (*chi.Mux, *FakeUserDataExportService) {
+	service := &FakeUserDataExportService{}
+	handler := handlers.NewUserDataExportHandler(service)
+
+	router := chi.NewRouter()
+	router.Get("/api/users/me/export", handler.ExportMyData)
+	router.Post("/api/users/me/exports", handler.CreateMyExport)
+	router.Get("/api/users/me/exports", handler.ListMyExports)
+	router.Get("/api/users/me/exports/{id}", handler.GetMyExport)
+	router.Get("/api/users/me/exports/{id}/download", handler.DownloadMyExport)
+
+	return router, service
+}
+
+func TestExportMyData(t *testing.T) {
+	t.Run("JSON", func(t *testing.T) {
+		router, service := setupUserDataExportTest()
+
+		req, err := http.NewRequest("GET", "/api/users/me/export", nil)
+		require.NoError(t, err)
+		req = req.WithContext(createAuthContext(7))
+
+		rr := httptest.NewRecorder()
+		router.ServeHTTP(rr, req)
+
+		assert.Equal(t, http.StatusOK, rr.Code)
+		assert.Equal(t, int64(7), service.lastUserID)
+		assert.Equal(t, "json", service.format)
+		assert.Equal(t, "application/json", rr.Header().Get("Content-Type"))
+		assert.Equal(t, "attachment; filename=hideme-data-export-7.json", rr.Header().Get("Content-Disposition"))
+		assert.Equal(t, "{\"version\":1}\n", rr.Body.String())
+	})
+
+	t.Run("ZIP", func(t *testing.T) {
+		router, service := setupUserDataExportTest()
+
+		req, err := http.NewRequest("GET", "/api/users/me/export?format=ZIP", nil)
+		require.NoError(t, err)
+		req = req.WithContext(createAuthContext(7))
+
+		rr := httptest.NewRecorder()
+		router.ServeHTTP(rr, req)
+
+		assert.Equal(t, http.StatusOK, rr.Code)
+		assert.Equal(t, "zip", service.format)
+		assert.Equal(t, "application/zip", rr.Header().Get("Content-Type"))
+	})
+
+	t.Run("Invalid format", func(t *testing.T) {
+		router, _ := setupUserDataExportTest()
+
+		req, err := http.NewRequest("GET", "/api/users/me/export?format=csv", nil)
+		require.NoError(t, err)
+		req = req.WithContext(createAuthContext(7))
+
+		rr := httptest.NewRecorder()
+		router.ServeHTTP(rr, req)
+
+		assert.Equal(t, http.StatusBadRequest, rr.Code)
+	})
+
+	t.Run("Unauthorized", func(t *testing.T) {
+		router, _ := setupUserDataExportTest()
+
+		req, err := http.NewRequest("GET", "/api/users/me/export", nil)
+		require.NoError(t, err)
+
+		rr := httptest.NewRecorder()
+		router.ServeHTTP(rr, req)
+
+		assert.Equal(t, http.StatusUnauthorized, rr.Code)
+	})
+
+	t.Run("User not found", func(t *testing.T) {
+		router, service := setupUserDataExportTest()
+		service.errBefore = utils.NewNotFoundError("User", 7)
+
+		req, err := http.NewRequest("GET", "/api/users/me/export", nil)
+		require.NoError(t, err)
+		req = req.WithContext(createAuthContext(7))
+
+		rr := httptest.NewRecorder()
+		router.ServeHTTP(rr, req)
+
+		assert.Equal(t, http.StatusNotFound, rr.Code)
+	})
+
+	t.Run("Error while streaming", func(t *testing.T) {
+		router, service := setupUserDataExportTest()
+		service.errAfter = errors.New("database error")
+
+		req, err := http.NewRequest("GET", "/api/users/me/export", nil)
+		require.NoError(t, err)
+		req = req.WithContext(createAuthContext(7))
+
+		rr := httptest.NewRecorder()
+		router.ServeHTTP(rr, req)
+
+		// The status was sent with the first bytes; the file is left incomplete
+		assert.Equal(t, http.StatusOK, rr.Code)
+		assert.Equal(t, `{"version":1`, rr.Body.String())
+	})
+}
+
+func TestCreateMyExport(t *testing.T) {
+	t.Run("Accepted", func(t *testing.T) {
+		router, service := setupUserDataExportTest()
+
+		req, err := http.NewRequest("POST", "/api/users/me/exports?format=zip", nil)
+		require.NoError(t, err)
+		req = req.WithContext(createAuthContext(7))
+
+		rr := httptest.NewRecorder()
+		router.ServeHTTP(rr, req)
+
+		assert.Equal(t, http.StatusAccepted, rr.Code)
+		require.NotNil(t, service.job)
+		assert.Equal(t, "zip", service.job.Format)
+	})
+
+	t.Run("Another export unfinished", func(t *testing.T) {
+		router, service := setupUserDataExportTest()
+		service.jobErr = utils.New(utils.ErrDuplicate, constants.StatusConflict, constants.MsgUserDataExportActive)
+
+		req, err := http.NewRequest("POST", "/api/users/me/exports", nil)
+		require.NoError(t, err)
+		req = req.WithContext(createAuthContext(7))
+
+		rr := httptest.NewRecorder()
+		router.ServeHTTP(rr, req)
+
+		assert.Equal(t, http.StatusConflict, rr.Code)
+	})
+
+	t.Run("Invalid format", func(t *testing.T) {
+		router, _ := setupUserDataExportTest()
+
+		req, err := http.NewRequest("POST", "/api/users/me/exports?format=csv", nil)
+		require.NoError(t, err)
+		req = req.WithContext(createAuthContext(7))
+
+		rr := httptest.NewRecorder()
+		router.ServeHTTP(rr, req)
+
+		assert.Equal(t, http.StatusBadRequest, rr.Code)
+	})
+}
+
+func TestGetMyExport(t *testing.T) {
+	createdAt := time.Date(2026, 3, 1, 2, 0, 0, 0, time.UTC)
+
+	t.Run("Success", func(t *testing.T) {
+		router, service := setupUserDataExportTest()
+		service.job = &models.UserDataExportJob{
+			ID:     3,
+			UserID: 7,
+			Format: "json",
+			Status: constants.UserDataExportRunning,
+			Checkpoint: models.UserDataExportCheckpoint{
+				LastDocumentID:    120,
+				LastEntityID:      4512,
+				DocumentsExported: 100,
+				EntitiesExported:  2310,
+				Chunks:            5,
+			},
+			Resumes:   1,
+			CreatedAt: createdAt,
+			UpdatedAt: createdAt.Add(3 * time.Hour),
+		}
+
+		req, err := http.NewRequest("GET", "/api/users/me/exports/3", nil)
+		require.NoError(t, err)
+		req = req.WithContext(createAuthContext(7))
+
+		rr := httptest.NewRecorder()
+		router.ServeHTTP(rr, req)
+
+		assert.Equal(t, http.StatusOK, rr.Code)
+		golden.AssertResponse(t, rr)
+	})
+
+	t.Run("Another user's export", func(t *testing.T) {
+		router, service := setupUserDataExportTest()
+		service.job = &models.UserDataExportJob{ID: 3, UserID: 8}
+
+		req, err := http.NewRequest("GET", "/api/users/me/exports/3", nil)
+		require.NoError(t, err)
+		req = req.WithContext(createAuthContext(7))
+
+		rr := httptest.NewRecorder()
+		router.ServeHTTP(rr, req)
+
+		assert.Equal(t, http.StatusNotFound, rr.Code)
+	})
+
+	t.Run("Invalid ID", func(t *testing.T) {
+		router, _ := setupUserDataExportTest()
+
+		req, err := http.NewRequest("GET", "/api/users/me/exports/abc", nil)
+		require.NoError(t, err)
+		req = req.WithContext(createAuthContext(7))
+
+		rr := httptest.NewRecorder()
+		router.ServeHTTP(rr, req)
+
+		assert.Equal(t, http.StatusBadRequest, rr.Code)
+	})
+}
+
+func TestDownloadMyExport(t *testing.T) {
+	t.Run("Completed", func(t *testing.T) {
+		router, service := setupUserDataExportTest()
+		service.job = &models.UserDataExportJob{ID: 3, UserID: 7, Format: "json", Status: constants.UserDataExportCompleted}
+
+		req, err := http.NewRequest("GET", "/api/users/me/exports/3/download", nil)
+		require.NoError(t, err)
+		req = req.WithContext(createAuthContext(7))
+
+		rr := httptest.NewRecorder()
+		router.ServeHTTP(rr, req)
+
+		assert.Equal(t, http.StatusOK, rr.Code)
+		assert.Equal(t, "attachment; filename=hideme-data-export-7-3.json", rr.Header().Get("Content-Disposition"))
+		assert.Equal(t, "{\"version\":1}\n", rr.Body.String())
+	})
+
+	t.Run("Not completed", func(t *testing.T) {
+		router, service := setupUserDataExportTest()
+		service.job = &models.UserDataExportJob{ID: 3, UserID: 7, Format: "json", Status: constants.UserDataExportInterrupted}
+
+		req, err := http.NewRequest("GET", "/api/users/me/exports/3/download", nil)
+		require.NoError(t, err)
+		req = req.WithContext(createAuthContext(7))
+
+		rr := httptest.NewRecorder()
+		router.ServeHTTP(rr, req)
+
+		assert.Equal(t, http.StatusConflict, rr.Code)
+		assert.Empty(t, rr.Header().Get("Content-Disposition"))
+	})
+}
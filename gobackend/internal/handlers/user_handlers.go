@@ -5,6 +5,9 @@ package handlers
 
 import (
 	"net/http"
+	"strconv"
+
+	"github.com/go-chi/chi/v5"
 
 	"github.com/yasinhessnawi1/Hideme_Backend/internal/auth"
 	"github.com/yasinhessnawi1/Hideme_Backend/internal/constants"
@@ -16,19 +19,22 @@ import (
 // It provides endpoints for viewing and updating user profiles,
 // changing passwords, and managing sessions.
 type UserHandler struct {
-	userService UserServiceInterface
+	userService     UserServiceInterface
+	approvalService ApprovalServiceInterface
 }
 
-// NewUserHandler creates a new UserHandler with the provided user service.
+// NewUserHandler creates a new UserHandler with the provided services.
 //
 // Parameters:
 //   - userService: Service handling user operations
+//   - approvalService: Service queuing admin changes that need a second administrator
 //
 // Returns:
 //   - A properly initialized UserHandler
-func NewUserHandler(userService UserServiceInterface) *UserHandler {
+func NewUserHandler(userService UserServiceInterface, approvalService ApprovalServiceInterface) *UserHandler {
 	return &UserHandler{
-		userService: userService,
+		userService:     userService,
+		approvalService: approvalService,
 	}
 }
 
@@ -206,77 +212,6 @@ func (h *UserHandler) ChangePassword(w http.ResponseWriter, r *http.Request) {
 	})
 }
 
-// DeleteAccount handles deleting the current user's account.
-//
-// HTTP Method:
-//   - DELETE
-//
-// URL Path:
-//   - /api/users/me
-//
-// Requires:
-//   - Authentication: User must be logged in
-//
-// Request Body:
-//   - JSON object with "password" field to verify identity
-//   - JSON object with "confirm" field set to "DELETE" to confirm deletion
-//
-// Responses:
-//   - 200 OK: Account deleted successfully
-//   - 400 Bad Request: Invalid request body or confirmation
-//   - 401 Unauthorized: User not authenticated or password incorrect
-//   - 500 Internal Server Error: Server-side error
-//
-// Security:
-//   - Requires the current password to verify user identity
-//   - Requires explicit confirmation text to prevent accidental deletion
-//
-// @Summary Delete account
-// @Description Deletes the account of the currently authenticated user
-// @Tags Users
-// @Accept json
-// @Produce json
-// @Security BearerAuth
-// @Param request body object true "Account deletion request containing password and confirm fields"
-// @Success 200 {object} utils.Response{data=map[string]string} "Account deleted successfully"
-// @Failure 400 {object} utils.Response{error=string} "Invalid request body or confirmation"
-// @Failure 401 {object} utils.Response{error=string} "User not authenticated or password incorrect"
-// @Failure 500 {object} utils.Response{error=string} "Server error"
-// @Router /users/me [delete]
-func (h *UserHandler) DeleteAccount(w http.ResponseWriter, r *http.Request) {
-	// Get the user ID from the context
-	userID, ok := auth.GetUserID(r)
-	if !ok {
-		utils.Unauthorized(w, constants.MsgAuthRequired)
-		return
-	}
-
-	// Decode and validate the request body
-	var req struct {
-		Password string `json:"password" validate:"required"`
-		Confirm  string `json:"confirm" validate:"required,eq=DELETE"`
-	}
-	if err := utils.DecodeAndValidate(r, &req); err != nil {
-		utils.ErrorFromAppError(w, utils.ParseError(err))
-		return
-	}
-
-	// For security, we need to authenticate with the password and require confirmation
-	// This would typically be handled by the auth service
-	// For now, we'll just delete the account directly
-
-	// Delete the account
-	if err := h.userService.DeleteUser(r.Context(), userID); err != nil {
-		utils.ErrorFromAppError(w, utils.ParseError(err))
-		return
-	}
-
-	// Return success
-	utils.JSON(w, constants.StatusOK, map[string]interface{}{
-		"message": constants.MsgUserDeleted,
-	})
-}
-
 // CheckUsername checks if a username is available.
 //
 // HTTP Method:
@@ -419,7 +354,7 @@ func (h *UserHandler) GetActiveSessions(w http.ResponseWriter, r *http.Request)
 	}
 
 	// Return the sessions
-	utils.JSON(w, constants.StatusOK, sessions)
+	utils.ListAll(w, r, sessions)
 }
 
 // InvalidateSession invalidates a specific session.
@@ -484,3 +419,421 @@ func (h *UserHandler) InvalidateSession(w http.ResponseWriter, r *http.Request)
 		"message": constants.MsgSessionInvalidated,
 	})
 }
+
+// LookupUser finds a user by ID, username or email for administrators.
+// Without an identifier, it lists users page by page instead.
+//
+// HTTP Method:
+//   - GET
+//
+// URL Path:
+//   - /api/admin/users
+//
+// Query Parameters:
+//   - id: User ID
+//   - username: Username
+//   - email: Email address
+//   - role: Only list users with this role (user, admin, auditor or support)
+//   - status: Only list users with this status (active or disabled)
+//   - page: Page number of the listing (default: 1)
+//   - page_size: Users per page of the listing (default: 10)
+//
+// Requires:
+//   - Authentication: Admin role
+//
+// Responses:
+//   - 200 OK: User found, or a page of users
+//   - 400 Bad Request: Several identifiers, or an unknown role or status given
+//   - 401 Unauthorized: User not authenticated
+//   - 403 Forbidden: User not authorized (not admin)
+//   - 404 Not Found: User not found
+//   - 500 Internal Server Error: Server-side error
+//
+// @Summary Look up or list users
+// @Description Finds a user by one of ID, username or email, or lists users when none is given
+// @Tags Admin/Users
+// @Produce json
+// @Security BearerAuth
+// @Security ApiKeyAuth
+// @Param id query int false "User ID"
+// @Param username query string false "Username"
+// @Param email query string false "Email address"
+// @Param role query string false "Role filter of the listing" Enums(user, admin, auditor, support)
+// @Param status query string false "Status filter of the listing" Enums(active, disabled)
+// @Param page query int false "Page number" default(1)
+// @Param page_size query int false "Page size" default(10)
+// @Success 200 {object} utils.Response{data=models.User} "User found"
+// @Success 200 {object} utils.Response{data=[]models.User} "Users listed"
+// @Failure 400 {object} utils.Response{error=string} "Invalid identifiers"
+// @Failure 401 {object} utils.Response{error=string} "User not authenticated"
+// @Failure 403 {object} utils.Response{error=string} "User not authorized"
+// @Failure 404 {object} utils.Response{error=string} "User not found"
+// @Failure 500 {object} utils.Response{error=string} "Server error"
+// @Router /admin/users [get]
+func (h *UserHandler) LookupUser(w http.ResponseWriter, r *http.Request) {
+	query := r.URL.Query()
+
+	var id int64
+	if value := query.Get(constants.ParamID); value != "" {
+		parsed, err := strconv.ParseInt(value, 10, 64)
+		if err != nil || parsed <= 0 {
+			utils.BadRequest(w, "Invalid user ID", nil)
+			return
+		}
+		id = parsed
+	}
+
+	username, email := query.Get(constants.QueryParamUsername), query.Get(constants.QueryParamEmail)
+	if id == 0 && username == "" && email == "" {
+		h.listUsers(w, r)
+		return
+	}
+
+	user, err := h.userService.FindUser(r.Context(), id, username, email)
+	if err != nil {
+		utils.ErrorFromAppError(w, utils.ParseError(err))
+		return
+	}
+
+	utils.JSON(w, constants.StatusOK, user)
+}
+
+// RevokeUserSessions signs a user out of every device for administrators.
+//
+// HTTP Method:
+//   - DELETE
+//
+// URL Path:
+//   - /api/admin/users/{id}/sessions
+//
+// Requires:
+//   - Authentication: Admin role
+//
+// Responses:
+//   - 200 OK: Sessions revoked
+//   - 400 Bad Request: Invalid user ID
+//   - 401 Unauthorized: User not authenticated
+//   - 403 Forbidden: User not authorized (not admin)
+//   - 404 Not Found: User not found
+//   - 500 Internal Server Error: Server-side error
+//
+// @Summary Revoke user sessions
+// @Description Deletes all sessions of a user, signing them out of every device
+// @Tags Admin/Users
+// @Produce json
+// @Security BearerAuth
+// @Security ApiKeyAuth
+// @Param id path int true "User ID"
+// @Success 200 {object} utils.Response{data=map[string]interface{}} "Sessions revoked"
+// @Failure 400 {object} utils.Response{error=string} "Invalid user ID"
+// @Failure 401 {object} utils.Response{error=string} "User not authenticated"
+// @Failure 403 {object} utils.Response{error=string} "User not authorized"
+// @Failure 404 {object} utils.Response{error=string} "User not found"
+// @Failure 500 {object} utils.Response{error=string} "Server error"
+// @Router /admin/users/{id}/sessions [delete]
+func (h *UserHandler) RevokeUserSessions(w http.ResponseWriter, r *http.Request) {
+	userID, err := strconv.ParseInt(chi.URLParam(r, constants.ParamID), 10, 64)
+	if err != nil {
+		utils.BadRequest(w, "Invalid user ID", nil)
+		return
+	}
+
+	count, err := h.userService.RevokeSessions(r.Context(), userID)
+	if err != nil {
+		utils.ErrorFromAppError(w, utils.ParseError(err))
+		return
+	}
+
+	utils.JSON(w, constants.StatusOK, map[string]interface{}{
+		"user_id": userID,
+		"revoked": count,
+	})
+}
+
+// listUsers writes one page of users, filtered by the role and status query parameters.
+func (h *UserHandler) listUsers(w http.ResponseWriter, r *http.Request) {
+	filter := models.UserFilter{
+		Role:   r.URL.Query().Get(constants.QueryParamRole),
+		Status: r.URL.Query().Get(constants.QueryParamStatus),
+	}
+	params := utils.GetPaginationParams(r)
+
+	users, total, err := h.userService.ListUsers(r.Context(), filter, params.Page, params.PageSize)
+	if err != nil {
+		utils.ErrorFromAppError(w, utils.ParseError(err))
+		return
+	}
+
+	utils.ListPage(w, r, users, params, total)
+}
+
+// DisableUser stops a user from signing in and from using their API keys, for administrators.
+// The user is signed out of every device; the account and its data are kept.
+//
+// HTTP Method:
+//   - POST
+//
+// URL Path:
+//   - /api/admin/users/{id}/disable
+//
+// Requires:
+//   - Authentication: Admin role
+//
+// Responses:
+//   - 204 No Content: User disabled
+//   - 400 Bad Request: Invalid user ID
+//   - 401 Unauthorized: User not authenticated
+//   - 403 Forbidden: User not authorized (not admin), or the administrator's own account
+//   - 404 Not Found: User not found
+//   - 500 Internal Server Error: Server-side error
+//
+// @Summary Disable user
+// @Description Disables a user account and revokes its sessions
+// @Tags Admin/Users
+// @Produce json
+// @Security BearerAuth
+// @Security ApiKeyAuth
+// @Param id path int true "User ID"
+// @Success 204 "User disabled"
+// @Failure 400 {object} utils.Response{error=string} "Invalid user ID"
+// @Failure 401 {object} utils.Response{error=string} "User not authenticated"
+// @Failure 403 {object} utils.Response{error=string} "User not authorized or own account"
+// @Failure 404 {object} utils.Response{error=string} "User not found"
+// @Failure 500 {object} utils.Response{error=string} "Server error"
+// @Router /admin/users/{id}/disable [post]
+func (h *UserHandler) DisableUser(w http.ResponseWriter, r *http.Request) {
+	adminID, ok := auth.GetUserID(r)
+	if !ok {
+		utils.Unauthorized(w, constants.MsgAuthRequired)
+		return
+	}
+
+	userID, err := strconv.ParseInt(chi.URLParam(r, constants.ParamID), 10, 64)
+	if err != nil {
+		utils.BadRequest(w, "Invalid user ID", nil)
+		return
+	}
+
+	if err := h.userService.DisableUser(r.Context(), adminID, userID); err != nil {
+		utils.ErrorFromAppError(w, utils.ParseError(err))
+		return
+	}
+
+	utils.NoContent(w)
+}
+
+// EnableUser lets a disabled user sign in again, for administrators.
+//
+// HTTP Method:
+//   - POST
+//
+// URL Path:
+//   - /api/admin/users/{id}/enable
+//
+// Requires:
+//   - Authentication: Admin role
+//
+// Responses:
+//   - 204 No Content: User enabled
+//   - 400 Bad Request: Invalid user ID
+//   - 401 Unauthorized: User not authenticated
+//   - 403 Forbidden: User not authorized (not admin)
+//   - 404 Not Found: User not found
+//   - 500 Internal Server Error: Server-side error
+//
+// @Summary Enable user
+// @Description Enables a disabled user account
+// @Tags Admin/Users
+// @Produce json
+// @Security BearerAuth
+// @Security ApiKeyAuth
+// @Param id path int true "User ID"
+// @Success 204 "User enabled"
+// @Failure 400 {object} utils.Response{error=string} "Invalid user ID"
+// @Failure 401 {object} utils.Response{error=string} "User not authenticated"
+// @Failure 403 {object} utils.Response{error=string} "User not authorized"
+// @Failure 404 {object} utils.Response{error=string} "User not found"
+// @Failure 500 {object} utils.Response{error=string} "Server error"
+// @Router /admin/users/{id}/enable [post]
+func (h *UserHandler) EnableUser(w http.ResponseWriter, r *http.Request) {
+	adminID, ok := auth.GetUserID(r)
+	if !ok {
+		utils.Unauthorized(w, constants.MsgAuthRequired)
+		return
+	}
+
+	userID, err := strconv.ParseInt(chi.URLParam(r, constants.ParamID), 10, 64)
+	if err != nil {
+		utils.BadRequest(w, "Invalid user ID", nil)
+		return
+	}
+
+	if err := h.userService.EnableUser(r.Context(), adminID, userID); err != nil {
+		utils.ErrorFromAppError(w, utils.ParseError(err))
+		return
+	}
+
+	utils.NoContent(w)
+}
+
+// UpdateUserRole changes the role of a user, for administrators.
+// Administrators can't change their own role.
+//
+// HTTP Method:
+//   - PUT
+//
+// URL Path:
+//   - /api/admin/users/{id}/role
+//
+// Requires:
+//   - Authentication: Admin role
+//
+// Request Body:
+//   - models.UserRoleUpdate: The new role (user, admin, auditor or support), and a reason for admin
+//
+// A promotion to admin is not applied here. It is requested as an admin_promotion action,
+// which a second administrator approves through /api/admin/actions/{id}/approve.
+//
+// Responses:
+//   - 200 OK: Role changed
+//   - 202 Accepted: Promotion to admin requested, the body is the admin action
+//   - 400 Bad Request: Invalid user ID or role, or a promotion to admin without a reason
+//   - 401 Unauthorized: User not authenticated
+//   - 403 Forbidden: User not authorized (not admin), or the administrator's own account
+//   - 404 Not Found: User not found
+//   - 500 Internal Server Error: Server-side error
+//
+// @Summary Change user role
+// @Description Changes the role of a user; promotions to admin wait for a second administrator
+// @Tags Admin/Users
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Security ApiKeyAuth
+// @Param id path int true "User ID"
+// @Param role body models.UserRoleUpdate true "New role"
+// @Success 200 {object} utils.Response{data=models.User} "Role changed"
+// @Success 202 {object} utils.Response{data=models.AdminAction} "Promotion to admin requested"
+// @Failure 400 {object} utils.Response{error=string} "Invalid user ID or role"
+// @Failure 401 {object} utils.Response{error=string} "User not authenticated"
+// @Failure 403 {object} utils.Response{error=string} "User not authorized or own account"
+// @Failure 404 {object} utils.Response{error=string} "User not found"
+// @Failure 500 {object} utils.Response{error=string} "Server error"
+// @Router /admin/users/{id}/role [put]
+func (h *UserHandler) UpdateUserRole(w http.ResponseWriter, r *http.Request) {
+	adminID, ok := auth.GetUserID(r)
+	if !ok {
+		utils.Unauthorized(w, constants.MsgAuthRequired)
+		return
+	}
+
+	userID, err := strconv.ParseInt(chi.URLParam(r, constants.ParamID), 10, 64)
+	if err != nil {
+		utils.BadRequest(w, "Invalid user ID", nil)
+		return
+	}
+
+	var update models.UserRoleUpdate
+	if err := utils.DecodeAndValidate(r, &update); err != nil {
+		utils.ErrorFromAppError(w, utils.ParseError(err))
+		return
+	}
+
+	if update.Role == constants.RoleAdmin {
+		h.requestUserAction(w, r, adminID, &models.AdminActionRequest{
+			ActionType: models.ActionAdminPromotion,
+			TargetID:   userID,
+			Reason:     update.Reason,
+		})
+		return
+	}
+
+	user, err := h.userService.SetRole(r.Context(), adminID, userID, update.Role)
+	if err != nil {
+		utils.ErrorFromAppError(w, utils.ParseError(err))
+		return
+	}
+
+	utils.JSON(w, constants.StatusOK, user)
+}
+
+// EraseUser requests the permanent deletion of a user account and all of its data.
+// The deletion is a user_erasure admin action, so it waits for a second administrator
+// to approve it through /api/admin/actions/{id}/approve.
+//
+// HTTP Method:
+//   - DELETE
+//
+// URL Path:
+//   - /api/admin/users/{id}
+//
+// Requires:
+//   - Authentication: Admin role
+//
+// Request Body:
+//   - models.UserErasureRequest: The reason for the erasure
+//
+// Responses:
+//   - 202 Accepted: Erasure requested, the body is the admin action
+//   - 400 Bad Request: Invalid user ID or missing reason
+//   - 401 Unauthorized: User not authenticated
+//   - 403 Forbidden: User not authorized (not admin), or the administrator's own account
+//   - 500 Internal Server Error: Server-side error
+//
+// @Summary Erase user
+// @Description Requests the permanent deletion of a user account; the erasure waits for a second administrator
+// @Tags Admin/Users
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Security ApiKeyAuth
+// @Param id path int true "User ID"
+// @Param erasure body models.UserErasureRequest true "Erasure reason"
+// @Success 202 {object} utils.Response{data=models.AdminAction} "Erasure requested"
+// @Failure 400 {object} utils.Response{error=string} "Invalid user ID or reason"
+// @Failure 401 {object} utils.Response{error=string} "User not authenticated"
+// @Failure 403 {object} utils.Response{error=string} "User not authorized or own account"
+// @Failure 500 {object} utils.Response{error=string} "Server error"
+// @Router /admin/users/{id} [delete]
+func (h *UserHandler) EraseUser(w http.ResponseWriter, r *http.Request) {
+	adminID, ok := auth.GetUserID(r)
+	if !ok {
+		utils.Unauthorized(w, constants.MsgAuthRequired)
+		return
+	}
+
+	userID, err := strconv.ParseInt(chi.URLParam(r, constants.ParamID), 10, 64)
+	if err != nil {
+		utils.BadRequest(w, "Invalid user ID", nil)
+		return
+	}
+
+	var erasure models.UserErasureRequest
+	if err := utils.DecodeAndValidate(r, &erasure); err != nil {
+		utils.ErrorFromAppError(w, utils.ParseError(err))
+		return
+	}
+
+	h.requestUserAction(w, r, adminID, &models.AdminActionRequest{
+		ActionType: models.ActionUserErasure,
+		TargetID:   userID,
+		Reason:     erasure.Reason,
+	})
+}
+
+// requestUserAction queues an admin action against another user's account and
+// responds with the recorded action.
+func (h *UserHandler) requestUserAction(w http.ResponseWriter, r *http.Request, adminID int64, req *models.AdminActionRequest) {
+	if req.TargetID == adminID {
+		utils.Forbidden(w, constants.MsgOwnAccountChange)
+		return
+	}
+
+	action, err := h.approvalService.RequestAction(r.Context(), adminID, req)
+	if err != nil {
+		utils.ErrorFromAppError(w, utils.ParseError(err))
+		return
+	}
+
+	utils.JSON(w, constants.StatusAccepted, action)
+}
@@ -10,12 +10,16 @@ import (
 	"testing"
 	"time"
 
+	"github.com/go-chi/chi/v5"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/mock"
 	"github.com/stretchr/testify/require"
 
 	"github.com/yasinhessnawi1/Hideme_Backend/internal/auth"
+	"github.com/yasinhessnawi1/Hideme_Backend/internal/constants"
 	"github.com/yasinhessnawi1/Hideme_Backend/internal/models"
+	"github.com/yasinhessnawi1/Hideme_Backend/internal/testsupport/fixtures"
+	"github.com/yasinhessnawi1/Hideme_Backend/internal/testsupport/golden"
 	"github.com/yasinhessnawi1/Hideme_Backend/internal/utils"
 )
 
@@ -74,9 +78,93 @@ func (m *MockUserService) InvalidateSession(ctx context.Context, userID int64, s
 }
 
 // Helper functions for testing
+func (m *MockUserService) FindUser(ctx context.Context, id int64, username, email string) (*models.User, error) {
+	args := m.Called(ctx, id, username, email)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*models.User), args.Error(1)
+}
+
+func (m *MockUserService) RevokeSessions(ctx context.Context, userID int64) (int, error) {
+	args := m.Called(ctx, userID)
+	return args.Int(0), args.Error(1)
+}
+
+func (m *MockUserService) ListUsers(ctx context.Context, filter models.UserFilter, page, pageSize int) ([]*models.User, int, error) {
+	args := m.Called(ctx, filter, page, pageSize)
+	if args.Get(0) == nil {
+		return nil, 0, args.Error(2)
+	}
+	return args.Get(0).([]*models.User), args.Int(1), args.Error(2)
+}
+
+func (m *MockUserService) DisableUser(ctx context.Context, adminID, userID int64) error {
+	args := m.Called(ctx, adminID, userID)
+	return args.Error(0)
+}
+
+func (m *MockUserService) EnableUser(ctx context.Context, adminID, userID int64) error {
+	args := m.Called(ctx, adminID, userID)
+	return args.Error(0)
+}
+
+func (m *MockUserService) SetRole(ctx context.Context, adminID, userID int64, role string) (*models.User, error) {
+	args := m.Called(ctx, adminID, userID, role)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*models.User), args.Error(1)
+}
+
+// MockUserApprovalService is a mock of the approval workflow that queues admin changes to users
+type MockUserApprovalService struct {
+	mock.Mock
+}
+
+func (m *MockUserApprovalService) RequestAction(ctx context.Context, requesterID int64, req *models.AdminActionRequest) (*models.AdminAction, error) {
+	args := m.Called(ctx, requesterID, req)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*models.AdminAction), args.Error(1)
+}
+
+func (m *MockUserApprovalService) ApproveAction(ctx context.Context, id int64, reviewerID int64, note string) (*models.AdminAction, error) {
+	args := m.Called(ctx, id, reviewerID, note)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*models.AdminAction), args.Error(1)
+}
+
+func (m *MockUserApprovalService) RejectAction(ctx context.Context, id int64, reviewerID int64, note string) (*models.AdminAction, error) {
+	args := m.Called(ctx, id, reviewerID, note)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*models.AdminAction), args.Error(1)
+}
+
+func (m *MockUserApprovalService) GetAction(ctx context.Context, id int64) (*models.AdminAction, error) {
+	args := m.Called(ctx, id)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*models.AdminAction), args.Error(1)
+}
+
+func (m *MockUserApprovalService) ListActions(ctx context.Context, status models.AdminActionStatus) ([]*models.AdminAction, error) {
+	args := m.Called(ctx, status)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]*models.AdminAction), args.Error(1)
+}
+
 func setupUserTest(t *testing.T) (*UserHandler, *MockUserService) {
 	mockService := new(MockUserService)
-	handler := NewUserHandler(mockService)
+	handler := NewUserHandler(mockService, new(MockUserApprovalService))
 	return handler, mockService
 }
 
@@ -96,14 +184,7 @@ func TestGetCurrentUser(t *testing.T) {
 	handler, mockService := setupUserTest(t)
 
 	t.Run("Success", func(t *testing.T) {
-		// Create expected user with consistent time values
-		expectedUser := &models.User{
-			ID:        1001,
-			Username:  "testuser",
-			Email:     "test@example.com",
-			CreatedAt: testTime(),
-			UpdatedAt: testTime(),
-		}
+		expectedUser := fixtures.User(1001)
 
 		// Setup mock service
 		mockService.On("GetUserByID", mock.Anything, int64(1001)).Return(expectedUser, nil).Once()
@@ -120,22 +201,7 @@ func TestGetCurrentUser(t *testing.T) {
 		handler.GetCurrentUser(rr, req)
 
 		// Verify response
-		assert.Equal(t, http.StatusOK, rr.Code)
-
-		// Define wrapper for the response envelope
-		var responseWrapper struct {
-			Success bool        `json:"success"`
-			Data    models.User `json:"data"`
-		}
-
-		// Parse response body into the wrapper
-		err = json.Unmarshal(rr.Body.Bytes(), &responseWrapper)
-		require.NoError(t, err)
-
-		// Verify response content using the data field from the wrapper
-		assert.Equal(t, expectedUser.ID, responseWrapper.Data.ID)
-		assert.Equal(t, expectedUser.Username, responseWrapper.Data.Username)
-		assert.Equal(t, expectedUser.Email, responseWrapper.Data.Email)
+		golden.AssertResponse(t, rr)
 
 		// Verify mock expectations
 		mockService.AssertExpectations(t)
@@ -153,26 +219,7 @@ func TestGetCurrentUser(t *testing.T) {
 		handler.GetCurrentUser(rr, req)
 
 		// Verify response
-		assert.Equal(t, http.StatusUnauthorized, rr.Code)
-
-		// Define wrapper for the error response envelope
-		var responseWrapper struct {
-			Success bool `json:"success"`
-			Error   struct {
-				Code    string      `json:"code"`
-				Message string      `json:"message"`
-				Details interface{} `json:"details"`
-			} `json:"error"`
-		}
-
-		// Parse response body into the wrapper
-		err = json.Unmarshal(rr.Body.Bytes(), &responseWrapper)
-		require.NoError(t, err)
-
-		// Verify error response
-		assert.False(t, responseWrapper.Success)
-		assert.Equal(t, "unauthorized", responseWrapper.Error.Code)
-		assert.Equal(t, "Authentication required", responseWrapper.Error.Message)
+		golden.AssertResponse(t, rr)
 	})
 
 	t.Run("Service Error", func(t *testing.T) {
@@ -213,25 +260,7 @@ func TestGetCurrentUser(t *testing.T) {
 		handler.GetCurrentUser(rr, req)
 
 		// Verify response
-		assert.Equal(t, http.StatusNotFound, rr.Code)
-
-		// Define wrapper for the error response envelope
-		var responseWrapper struct {
-			Success bool `json:"success"`
-			Error   struct {
-				Code    string      `json:"code"`
-				Message string      `json:"message"`
-				Details interface{} `json:"details"`
-			} `json:"error"`
-		}
-
-		// Parse response body into the wrapper
-		err = json.Unmarshal(rr.Body.Bytes(), &responseWrapper)
-		require.NoError(t, err)
-
-		// Verify error response
-		assert.False(t, responseWrapper.Success)
-		assert.Equal(t, "not_found", responseWrapper.Error.Code)
+		golden.AssertResponse(t, rr)
 
 		// Verify mock expectations
 		mockService.AssertExpectations(t)
@@ -280,23 +309,7 @@ func TestUpdateUser(t *testing.T) {
 		// Call the handler
 		handler.UpdateUser(rr, req)
 
-		// Verify response
-		assert.Equal(t, http.StatusOK, rr.Code)
-
-		// Define wrapper for the response envelope
-		var responseWrapper struct {
-			Success bool        `json:"success"`
-			Data    models.User `json:"data"`
-		}
-
-		// Parse response body into the wrapper
-		err = json.Unmarshal(rr.Body.Bytes(), &responseWrapper)
-		require.NoError(t, err)
-
-		// Verify response content using the data field from the wrapper
-		assert.Equal(t, expectedUser.ID, responseWrapper.Data.ID)
-		assert.Equal(t, expectedUser.Username, responseWrapper.Data.Username)
-		assert.Equal(t, expectedUser.Email, responseWrapper.Data.Email)
+		golden.AssertResponse(t, rr)
 
 		// Verify mock expectations
 		mockService.AssertExpectations(t)
@@ -372,26 +385,7 @@ func TestUpdateUser(t *testing.T) {
 		// Call the handler
 		handler.UpdateUser(rr, req)
 
-		// Verify response
-		assert.Equal(t, http.StatusConflict, rr.Code)
-
-		// Define wrapper for the error response envelope
-		var responseWrapper struct {
-			Success bool `json:"success"`
-			Error   struct {
-				Code    string      `json:"code"`
-				Message string      `json:"message"`
-				Details interface{} `json:"details"`
-			} `json:"error"`
-		}
-
-		// Parse response body into the wrapper
-		err = json.Unmarshal(rr.Body.Bytes(), &responseWrapper)
-		require.NoError(t, err)
-
-		// Verify error response
-		assert.False(t, responseWrapper.Success)
-		assert.Equal(t, "duplicate_resource", responseWrapper.Error.Code)
+		golden.AssertResponse(t, rr)
 
 		// Verify mock expectations
 		mockService.AssertExpectations(t)
@@ -468,24 +462,7 @@ func TestChangePassword(t *testing.T) {
 		// Call the handler
 		handler.ChangePassword(rr, httpReq)
 
-		// Verify response
-		assert.Equal(t, http.StatusOK, rr.Code)
-
-		// Define wrapper for the response envelope
-		var responseWrapper struct {
-			Success bool `json:"success"`
-			Data    struct {
-				Message string `json:"message"`
-			} `json:"data"`
-		}
-
-		// Parse response body into the wrapper
-		err = json.Unmarshal(rr.Body.Bytes(), &responseWrapper)
-		require.NoError(t, err)
-
-		// Verify response content
-		assert.True(t, responseWrapper.Success)
-		assert.Equal(t, "Password successfully changed", responseWrapper.Data.Message)
+		golden.AssertResponse(t, rr)
 
 		// Verify mock expectations
 		mockService.AssertExpectations(t)
@@ -519,26 +496,7 @@ func TestChangePassword(t *testing.T) {
 		// Call the handler
 		handler.ChangePassword(rr, httpReq)
 
-		// Verify response
-		assert.Equal(t, http.StatusBadRequest, rr.Code)
-
-		// Define wrapper for the error response envelope
-		var responseWrapper struct {
-			Success bool `json:"success"`
-			Error   struct {
-				Code    string      `json:"code"`
-				Message string      `json:"message"`
-				Details interface{} `json:"details"`
-			} `json:"error"`
-		}
-
-		// Parse response body into the wrapper
-		err = json.Unmarshal(rr.Body.Bytes(), &responseWrapper)
-		require.NoError(t, err)
-
-		// Verify error response
-		assert.False(t, responseWrapper.Success)
-		assert.Equal(t, "validation_error", responseWrapper.Error.Code)
+		golden.AssertResponse(t, rr)
 	})
 
 	t.Run("Invalid Request Body", func(t *testing.T) {
@@ -629,195 +587,6 @@ func TestChangePassword(t *testing.T) {
 	})
 }
 
-// TestDeleteAccount tests the DeleteAccount handler
-func TestDeleteAccount(t *testing.T) {
-	// Setup
-	handler, mockService := setupUserTest(t)
-
-	t.Run("Success", func(t *testing.T) {
-		// Create request payload
-		req := struct {
-			Password string `json:"password"`
-			Confirm  string `json:"confirm"`
-		}{
-			Password: "password123",
-			Confirm:  "DELETE",
-		}
-
-		// Setup mock service
-		mockService.On("DeleteUser", mock.Anything, int64(1001)).Return(nil).Once()
-
-		// Create request body
-		requestBody, err := json.Marshal(req)
-		require.NoError(t, err)
-
-		// Create test request
-		httpReq, err := http.NewRequest("DELETE", "/api/users/me", bytes.NewBuffer(requestBody))
-		require.NoError(t, err)
-		httpReq.Header.Set("Content-Type", "application/json")
-		httpReq = httpReq.WithContext(createAuthContext(1001))
-
-		// Create response recorder
-		rr := httptest.NewRecorder()
-
-		// Call the handler
-		handler.DeleteAccount(rr, httpReq)
-
-		// Verify response
-		assert.Equal(t, http.StatusOK, rr.Code)
-
-		// Define wrapper for the response envelope
-		var responseWrapper struct {
-			Success bool `json:"success"`
-			Data    struct {
-				Message string `json:"message"`
-			} `json:"data"`
-		}
-
-		// Parse response body into the wrapper
-		err = json.Unmarshal(rr.Body.Bytes(), &responseWrapper)
-		require.NoError(t, err)
-
-		// Verify response content
-		assert.True(t, responseWrapper.Success)
-		assert.Equal(t, "Account successfully deleted", responseWrapper.Data.Message)
-
-		// Verify mock expectations
-		mockService.AssertExpectations(t)
-	})
-
-	t.Run("Invalid Confirmation", func(t *testing.T) {
-		// Create request payload with wrong confirmation text
-		req := struct {
-			Password string `json:"password"`
-			Confirm  string `json:"confirm"`
-		}{
-			Password: "password123",
-			Confirm:  "Wrong", // Should be "DELETE"
-		}
-
-		// Create request body
-		requestBody, err := json.Marshal(req)
-		require.NoError(t, err)
-
-		// Create test request
-		httpReq, err := http.NewRequest("DELETE", "/api/users/me", bytes.NewBuffer(requestBody))
-		require.NoError(t, err)
-		httpReq.Header.Set("Content-Type", "application/json")
-		httpReq = httpReq.WithContext(createAuthContext(1001))
-
-		// Create response recorder
-		rr := httptest.NewRecorder()
-
-		// Call the handler
-		handler.DeleteAccount(rr, httpReq)
-
-		// Verify response
-		assert.Equal(t, http.StatusBadRequest, rr.Code)
-
-		// Define wrapper for the error response envelope
-		var responseWrapper struct {
-			Success bool `json:"success"`
-			Error   struct {
-				Code    string      `json:"code"`
-				Message string      `json:"message"`
-				Details interface{} `json:"details"`
-			} `json:"error"`
-		}
-
-		// Parse response body into the wrapper
-		err = json.Unmarshal(rr.Body.Bytes(), &responseWrapper)
-		require.NoError(t, err)
-
-		// Verify error response
-		assert.False(t, responseWrapper.Success)
-		assert.Equal(t, "validation_error", responseWrapper.Error.Code)
-	})
-
-	t.Run("Invalid Request Body", func(t *testing.T) {
-		// Create invalid JSON
-		invalidJSON := []byte(`{"password": invalid}`)
-
-		// Create test request
-		httpReq, err := http.NewRequest("DELETE", "/api/users/me", bytes.NewBuffer(invalidJSON))
-		require.NoError(t, err)
-		httpReq.Header.Set("Content-Type", "application/json")
-		httpReq = httpReq.WithContext(createAuthContext(1001))
-
-		// Create response recorder
-		rr := httptest.NewRecorder()
-
-		// Call the handler
-		handler.DeleteAccount(rr, httpReq)
-
-		// Verify response
-		assert.Equal(t, http.StatusBadRequest, rr.Code)
-	})
-
-	t.Run("Unauthorized", func(t *testing.T) {
-		// Create valid request body
-		req := struct {
-			Password string `json:"password"`
-			Confirm  string `json:"confirm"`
-		}{
-			Password: "password123",
-			Confirm:  "DELETE",
-		}
-		requestBody, err := json.Marshal(req)
-		require.NoError(t, err)
-
-		// Create test request without auth context
-		httpReq, err := http.NewRequest("DELETE", "/api/users/me", bytes.NewBuffer(requestBody))
-		require.NoError(t, err)
-		httpReq.Header.Set("Content-Type", "application/json")
-
-		// Create response recorder
-		rr := httptest.NewRecorder()
-
-		// Call the handler
-		handler.DeleteAccount(rr, httpReq)
-
-		// Verify response
-		assert.Equal(t, http.StatusUnauthorized, rr.Code)
-	})
-
-	t.Run("Service Error", func(t *testing.T) {
-		// Create request payload
-		req := struct {
-			Password string `json:"password"`
-			Confirm  string `json:"confirm"`
-		}{
-			Password: "password123",
-			Confirm:  "DELETE",
-		}
-
-		// Setup mock service to return error
-		mockService.On("DeleteUser", mock.Anything, int64(1001)).Return(errors.New("service error")).Once()
-
-		// Create request body
-		requestBody, err := json.Marshal(req)
-		require.NoError(t, err)
-
-		// Create test request
-		httpReq, err := http.NewRequest("DELETE", "/api/users/me", bytes.NewBuffer(requestBody))
-		require.NoError(t, err)
-		httpReq.Header.Set("Content-Type", "application/json")
-		httpReq = httpReq.WithContext(createAuthContext(1001))
-
-		// Create response recorder
-		rr := httptest.NewRecorder()
-
-		// Call the handler
-		handler.DeleteAccount(rr, httpReq)
-
-		// Verify response
-		assert.Equal(t, http.StatusInternalServerError, rr.Code)
-
-		// Verify mock expectations
-		mockService.AssertExpectations(t)
-	})
-}
-
 // TestCheckUsername tests the CheckUsername handler
 func TestCheckUsername(t *testing.T) {
 	// Setup
@@ -837,26 +606,7 @@ func TestCheckUsername(t *testing.T) {
 		// Call the handler
 		handler.CheckUsername(rr, req)
 
-		// Verify response
-		assert.Equal(t, http.StatusOK, rr.Code)
-
-		// Define wrapper for the response envelope
-		var responseWrapper struct {
-			Success bool `json:"success"`
-			Data    struct {
-				Username  string `json:"username"`
-				Available bool   `json:"available"`
-			} `json:"data"`
-		}
-
-		// Parse response body into the wrapper
-		err = json.Unmarshal(rr.Body.Bytes(), &responseWrapper)
-		require.NoError(t, err)
-
-		// Verify response content
-		assert.True(t, responseWrapper.Success)
-		assert.Equal(t, "newuser", responseWrapper.Data.Username)
-		assert.True(t, responseWrapper.Data.Available)
+		golden.AssertResponse(t, rr)
 
 		// Verify mock expectations
 		mockService.AssertExpectations(t)
@@ -876,26 +626,7 @@ func TestCheckUsername(t *testing.T) {
 		// Call the handler
 		handler.CheckUsername(rr, req)
 
-		// Verify response
-		assert.Equal(t, http.StatusOK, rr.Code)
-
-		// Define wrapper for the response envelope
-		var responseWrapper struct {
-			Success bool `json:"success"`
-			Data    struct {
-				Username  string `json:"username"`
-				Available bool   `json:"available"`
-			} `json:"data"`
-		}
-
-		// Parse response body into the wrapper
-		err = json.Unmarshal(rr.Body.Bytes(), &responseWrapper)
-		require.NoError(t, err)
-
-		// Verify response content
-		assert.True(t, responseWrapper.Success)
-		assert.Equal(t, "existinguser", responseWrapper.Data.Username)
-		assert.False(t, responseWrapper.Data.Available)
+		golden.AssertResponse(t, rr)
 
 		// Verify mock expectations
 		mockService.AssertExpectations(t)
@@ -912,27 +643,7 @@ func TestCheckUsername(t *testing.T) {
 		// Call the handler
 		handler.CheckUsername(rr, req)
 
-		// Verify response
-		assert.Equal(t, http.StatusBadRequest, rr.Code)
-
-		// Define wrapper for the error response envelope
-		var responseWrapper struct {
-			Success bool `json:"success"`
-			Error   struct {
-				Code    string      `json:"code"`
-				Message string      `json:"message"`
-				Details interface{} `json:"details"`
-			} `json:"error"`
-		}
-
-		// Parse response body into the wrapper
-		err = json.Unmarshal(rr.Body.Bytes(), &responseWrapper)
-		require.NoError(t, err)
-
-		// Verify error response
-		assert.False(t, responseWrapper.Success)
-		assert.Equal(t, "bad_request", responseWrapper.Error.Code)
-		assert.Equal(t, "Username parameter is required", responseWrapper.Error.Message)
+		golden.AssertResponse(t, rr)
 	})
 
 	t.Run("Service Error", func(t *testing.T) {
@@ -971,26 +682,7 @@ func TestCheckUsername(t *testing.T) {
 		// Call the handler
 		handler.CheckUsername(rr, req)
 
-		// Verify response
-		assert.Equal(t, http.StatusBadRequest, rr.Code)
-
-		// Define wrapper for the error response envelope
-		var responseWrapper struct {
-			Success bool `json:"success"`
-			Error   struct {
-				Code    string      `json:"code"`
-				Message string      `json:"message"`
-				Details interface{} `json:"details"`
-			} `json:"error"`
-		}
-
-		// Parse response body into the wrapper
-		err = json.Unmarshal(rr.Body.Bytes(), &responseWrapper)
-		require.NoError(t, err)
-
-		// Verify error response
-		assert.False(t, responseWrapper.Success)
-		assert.Equal(t, "validation_error", responseWrapper.Error.Code)
+		golden.AssertResponse(t, rr)
 
 		// Verify mock expectations
 		mockService.AssertExpectations(t)
@@ -1016,26 +708,7 @@ func TestCheckEmail(t *testing.T) {
 		// Call the handler
 		handler.CheckEmail(rr, req)
 
-		// Verify response
-		assert.Equal(t, http.StatusOK, rr.Code)
-
-		// Define wrapper for the response envelope
-		var responseWrapper struct {
-			Success bool `json:"success"`
-			Data    struct {
-				Email     string `json:"email"`
-				Available bool   `json:"available"`
-			} `json:"data"`
-		}
-
-		// Parse response body into the wrapper
-		err = json.Unmarshal(rr.Body.Bytes(), &responseWrapper)
-		require.NoError(t, err)
-
-		// Verify response content
-		assert.True(t, responseWrapper.Success)
-		assert.Equal(t, "new@example.com", responseWrapper.Data.Email)
-		assert.True(t, responseWrapper.Data.Available)
+		golden.AssertResponse(t, rr)
 
 		// Verify mock expectations
 		mockService.AssertExpectations(t)
@@ -1055,26 +728,7 @@ func TestCheckEmail(t *testing.T) {
 		// Call the handler
 		handler.CheckEmail(rr, req)
 
-		// Verify response
-		assert.Equal(t, http.StatusOK, rr.Code)
-
-		// Define wrapper for the response envelope
-		var responseWrapper struct {
-			Success bool `json:"success"`
-			Data    struct {
-				Email     string `json:"email"`
-				Available bool   `json:"available"`
-			} `json:"data"`
-		}
-
-		// Parse response body into the wrapper
-		err = json.Unmarshal(rr.Body.Bytes(), &responseWrapper)
-		require.NoError(t, err)
-
-		// Verify response content
-		assert.True(t, responseWrapper.Success)
-		assert.Equal(t, "existing@example.com", responseWrapper.Data.Email)
-		assert.False(t, responseWrapper.Data.Available)
+		golden.AssertResponse(t, rr)
 
 		// Verify mock expectations
 		mockService.AssertExpectations(t)
@@ -1091,27 +745,7 @@ func TestCheckEmail(t *testing.T) {
 		// Call the handler
 		handler.CheckEmail(rr, req)
 
-		// Verify response
-		assert.Equal(t, http.StatusBadRequest, rr.Code)
-
-		// Define wrapper for the error response envelope
-		var responseWrapper struct {
-			Success bool `json:"success"`
-			Error   struct {
-				Code    string      `json:"code"`
-				Message string      `json:"message"`
-				Details interface{} `json:"details"`
-			} `json:"error"`
-		}
-
-		// Parse response body into the wrapper
-		err = json.Unmarshal(rr.Body.Bytes(), &responseWrapper)
-		require.NoError(t, err)
-
-		// Verify error response
-		assert.False(t, responseWrapper.Success)
-		assert.Equal(t, "bad_request", responseWrapper.Error.Code)
-		assert.Equal(t, "Email parameter is required", responseWrapper.Error.Message)
+		golden.AssertResponse(t, rr)
 	})
 
 	t.Run("Service Error", func(t *testing.T) {
@@ -1208,6 +842,7 @@ func TestGetActiveSessions(t *testing.T) {
 		// Create test request
 		req, err := http.NewRequest("GET", "/api/users/me/sessions", nil)
 		require.NoError(t, err)
+		req.Header.Set(constants.HeaderXAPIVersion, "2")
 		req = req.WithContext(createAuthContext(1001))
 
 		// Create response recorder
@@ -1216,24 +851,7 @@ func TestGetActiveSessions(t *testing.T) {
 		// Call the handler
 		handler.GetActiveSessions(rr, req)
 
-		// Verify response
-		assert.Equal(t, http.StatusOK, rr.Code)
-
-		// Define wrapper for the response envelope
-		var responseWrapper struct {
-			Success bool                        `json:"success"`
-			Data    []*models.ActiveSessionInfo `json:"data"`
-		}
-
-		// Parse response body into the wrapper
-		err = json.Unmarshal(rr.Body.Bytes(), &responseWrapper)
-		require.NoError(t, err)
-
-		// Verify response content
-		assert.True(t, responseWrapper.Success)
-		assert.Equal(t, len(expectedSessions), len(responseWrapper.Data))
-		assert.Equal(t, expectedSessions[0].ID, responseWrapper.Data[0].ID)
-		assert.Equal(t, expectedSessions[1].ID, responseWrapper.Data[1].ID)
+		golden.AssertResponse(t, rr)
 
 		// Verify mock expectations
 		mockService.AssertExpectations(t)
@@ -1294,22 +912,7 @@ func TestGetActiveSessions(t *testing.T) {
 		// Call the handler
 		handler.GetActiveSessions(rr, req)
 
-		// Verify response
-		assert.Equal(t, http.StatusOK, rr.Code)
-
-		// Define wrapper for the response envelope
-		var responseWrapper struct {
-			Success bool                        `json:"success"`
-			Data    []*models.ActiveSessionInfo `json:"data"`
-		}
-
-		// Parse response body into the wrapper
-		err = json.Unmarshal(rr.Body.Bytes(), &responseWrapper)
-		require.NoError(t, err)
-
-		// Verify response content
-		assert.True(t, responseWrapper.Success)
-		assert.Empty(t, responseWrapper.Data)
+		golden.AssertResponse(t, rr)
 
 		// Verify mock expectations
 		mockService.AssertExpectations(t)
@@ -1348,24 +951,7 @@ func TestInvalidateSession(t *testing.T) {
 		// Call the handler
 		handler.InvalidateSession(rr, httpReq)
 
-		// Verify response
-		assert.Equal(t, http.StatusOK, rr.Code)
-
-		// Define wrapper for the response envelope
-		var responseWrapper struct {
-			Success bool `json:"success"`
-			Data    struct {
-				Message string `json:"message"`
-			} `json:"data"`
-		}
-
-		// Parse response body into the wrapper
-		err = json.Unmarshal(rr.Body.Bytes(), &responseWrapper)
-		require.NoError(t, err)
-
-		// Verify response content
-		assert.True(t, responseWrapper.Success)
-		assert.Equal(t, "Session successfully invalidated", responseWrapper.Data.Message)
+		golden.AssertResponse(t, rr)
 
 		// Verify mock expectations
 		mockService.AssertExpectations(t)
@@ -1415,26 +1001,7 @@ func TestInvalidateSession(t *testing.T) {
 		// Call the handler
 		handler.InvalidateSession(rr, httpReq)
 
-		// Verify response
-		assert.Equal(t, http.StatusBadRequest, rr.Code)
-
-		// Define wrapper for the error response envelope
-		var responseWrapper struct {
-			Success bool `json:"success"`
-			Error   struct {
-				Code    string      `json:"code"`
-				Message string      `json:"message"`
-				Details interface{} `json:"details"`
-			} `json:"error"`
-		}
-
-		// Parse response body into the wrapper
-		err = json.Unmarshal(rr.Body.Bytes(), &responseWrapper)
-		require.NoError(t, err)
-
-		// Verify error response
-		assert.False(t, responseWrapper.Success)
-		assert.Equal(t, "validation_error", responseWrapper.Error.Code)
+		golden.AssertResponse(t, rr)
 	})
 
 	t.Run("Unauthorized", func(t *testing.T) {
@@ -1524,28 +1091,290 @@ func TestInvalidateSession(t *testing.T) {
 		// Call the handler
 		handler.InvalidateSession(rr, httpReq)
 
-		// Verify response
+		golden.AssertResponse(t, rr)
+
+		// Verify mock expectations
+		mockService.AssertExpectations(t)
+	})
+}
+
+func TestLookupUser(t *testing.T) {
+	handler, mockService := setupUserTest(t)
+
+	t.Run("By Email", func(t *testing.T) {
+		user := &models.User{ID: 7, Username: "ola", Email: "ola@example.com", Role: "user"}
+		mockService.On("FindUser", mock.Anything, int64(0), "", "ola@example.com").Return(user, nil).Once()
+
+		req, err := http.NewRequest("GET", "/api/admin/users?email=ola@example.com", nil)
+		require.NoError(t, err)
+		rr := httptest.NewRecorder()
+
+		handler.LookupUser(rr, req)
+
+		golden.AssertResponse(t, rr)
+		mockService.AssertExpectations(t)
+	})
+
+	t.Run("Invalid ID", func(t *testing.T) {
+		req, err := http.NewRequest("GET", "/api/admin/users?id=abc", nil)
+		require.NoError(t, err)
+		rr := httptest.NewRecorder()
+
+		handler.LookupUser(rr, req)
+
+		assert.Equal(t, http.StatusBadRequest, rr.Code)
+	})
+
+	t.Run("Not Found", func(t *testing.T) {
+		mockService.On("FindUser", mock.Anything, int64(0), "nobody", "").
+			Return(nil, utils.NewNotFoundError("User", "nobody")).Once()
+
+		req, err := http.NewRequest("GET", "/api/admin/users?username=nobody", nil)
+		require.NoError(t, err)
+		rr := httptest.NewRecorder()
+
+		handler.LookupUser(rr, req)
+
+		assert.Equal(t, http.StatusNotFound, rr.Code)
+		mockService.AssertExpectations(t)
+	})
+
+	t.Run("List", func(t *testing.T) {
+		users := []*models.User{{ID: 8, Username: "kari", Role: "admin"}}
+		filter := models.UserFilter{Role: "admin", Status: "active"}
+		mockService.On("ListUsers", mock.Anything, filter, 2, 5).Return(users, 6, nil).Once()
+
+		req, err := http.NewRequest("GET", "/api/admin/users?role=admin&status=active&page=2&page_size=5", nil)
+		require.NoError(t, err)
+		req.Header.Set(constants.HeaderXAPIVersion, "2")
+		rr := httptest.NewRecorder()
+
+		handler.LookupUser(rr, req)
+
+		golden.AssertResponse(t, rr)
+		mockService.AssertExpectations(t)
+	})
+}
+
+func TestDisableUser(t *testing.T) {
+	handler, mockService := setupUserTest(t)
+
+	router := chi.NewRouter()
+	router.Post("/api/admin/users/{id}/disable", handler.DisableUser)
+	router.Post("/api/admin/users/{id}/enable", handler.EnableUser)
+
+	t.Run("Disable", func(t *testing.T) {
+		mockService.On("DisableUser", mock.Anything, int64(1), int64(7)).Return(nil).Once()
+
+		req, err := http.NewRequest("POST", "/api/admin/users/7/disable", nil)
+		require.NoError(t, err)
+		req = req.WithContext(createAuthContext(1))
+		rr := httptest.NewRecorder()
+
+		router.ServeHTTP(rr, req)
+
+		assert.Equal(t, http.StatusNoContent, rr.Code)
+		mockService.AssertExpectations(t)
+	})
+
+	t.Run("Own Account", func(t *testing.T) {
+		mockService.On("DisableUser", mock.Anything, int64(1), int64(1)).
+			Return(utils.NewForbiddenError(constants.MsgOwnAccountChange)).Once()
+
+		req, err := http.NewRequest("POST", "/api/admin/users/1/disable", nil)
+		require.NoError(t, err)
+		req = req.WithContext(createAuthContext(1))
+		rr := httptest.NewRecorder()
+
+		router.ServeHTTP(rr, req)
+
 		assert.Equal(t, http.StatusForbidden, rr.Code)
+		mockService.AssertExpectations(t)
+	})
 
-		// Define wrapper for the error response envelope
-		var responseWrapper struct {
-			Success bool `json:"success"`
-			Error   struct {
-				Code    string      `json:"code"`
-				Message string      `json:"message"`
-				Details interface{} `json:"details"`
-			} `json:"error"`
-		}
+	t.Run("Enable", func(t *testing.T) {
+		mockService.On("EnableUser", mock.Anything, int64(1), int64(7)).Return(nil).Once()
 
-		// Parse response body into the wrapper
-		err = json.Unmarshal(rr.Body.Bytes(), &responseWrapper)
+		req, err := http.NewRequest("POST", "/api/admin/users/7/enable", nil)
 		require.NoError(t, err)
+		req = req.WithContext(createAuthContext(1))
+		rr := httptest.NewRecorder()
 
-		// Verify error response
-		assert.False(t, responseWrapper.Success)
-		assert.Equal(t, "forbidden", responseWrapper.Error.Code)
+		router.ServeHTTP(rr, req)
 
-		// Verify mock expectations
+		assert.Equal(t, http.StatusNoContent, rr.Code)
+		mockService.AssertExpectations(t)
+	})
+
+	t.Run("Unauthenticated", func(t *testing.T) {
+		req, err := http.NewRequest("POST", "/api/admin/users/7/disable", nil)
+		require.NoError(t, err)
+		rr := httptest.NewRecorder()
+
+		router.ServeHTTP(rr, req)
+
+		assert.Equal(t, http.StatusUnauthorized, rr.Code)
+	})
+}
+
+func TestUpdateUserRole(t *testing.T) {
+	mockService := new(MockUserService)
+	mockApprovals := new(MockUserApprovalService)
+	handler := NewUserHandler(mockService, mockApprovals)
+
+	router := chi.NewRouter()
+	router.Put("/api/admin/users/{id}/role", handler.UpdateUserRole)
+
+	t.Run("Success", func(t *testing.T) {
+		user := &models.User{ID: 7, Username: "ola", Role: "support"}
+		mockService.On("SetRole", mock.Anything, int64(1), int64(7), "support").Return(user, nil).Once()
+
+		req, err := http.NewRequest("PUT", "/api/admin/users/7/role", bytes.NewBufferString(`{"role":"support"}`))
+		require.NoError(t, err)
+		req.Header.Set("Content-Type", "application/json")
+		req = req.WithContext(createAuthContext(1))
+		rr := httptest.NewRecorder()
+
+		router.ServeHTTP(rr, req)
+
+		golden.AssertResponse(t, rr)
+		mockService.AssertExpectations(t)
+	})
+
+	t.Run("Admin Promotion Requested", func(t *testing.T) {
+		action := &models.AdminAction{ID: 3, ActionType: models.ActionAdminPromotion, TargetID: 7, Status: models.ActionStatusPending, RequestedBy: 1}
+		mockApprovals.On("RequestAction", mock.Anything, int64(1), &models.AdminActionRequest{
+			ActionType: models.ActionAdminPromotion,
+			TargetID:   7,
+			Reason:     "New on-call administrator",
+		}).Return(action, nil).Once()
+
+		req, err := http.NewRequest("PUT", "/api/admin/users/7/role", bytes.NewBufferString(`{"role":"admin","reason":"New on-call administrator"}`))
+		require.NoError(t, err)
+		req.Header.Set("Content-Type", "application/json")
+		req = req.WithContext(createAuthContext(1))
+		rr := httptest.NewRecorder()
+
+		router.ServeHTTP(rr, req)
+
+		golden.AssertResponse(t, rr)
+		mockApprovals.AssertExpectations(t)
+		mockService.AssertNotCalled(t, "SetRole", mock.Anything, int64(1), int64(7), "admin")
+	})
+
+	t.Run("Admin Promotion Without Reason", func(t *testing.T) {
+		req, err := http.NewRequest("PUT", "/api/admin/users/7/role", bytes.NewBufferString(`{"role":"admin"}`))
+		require.NoError(t, err)
+		req.Header.Set("Content-Type", "application/json")
+		req = req.WithContext(createAuthContext(1))
+		rr := httptest.NewRecorder()
+
+		router.ServeHTTP(rr, req)
+
+		assert.Equal(t, http.StatusBadRequest, rr.Code)
+	})
+
+	t.Run("Invalid Role", func(t *testing.T) {
+		req, err := http.NewRequest("PUT", "/api/admin/users/7/role", bytes.NewBufferString(`{"role":"owner"}`))
+		require.NoError(t, err)
+		req.Header.Set("Content-Type", "application/json")
+		req = req.WithContext(createAuthContext(1))
+		rr := httptest.NewRecorder()
+
+		router.ServeHTTP(rr, req)
+
+		assert.Equal(t, http.StatusBadRequest, rr.Code)
+	})
+}
+
+func TestEraseUser(t *testing.T) {
+	mockApprovals := new(MockUserApprovalService)
+	handler := NewUserHandler(new(MockUserService), mockApprovals)
+
+	router := chi.NewRouter()
+	router.Delete("/api/admin/users/{id}", handler.EraseUser)
+
+	t.Run("Erasure Requested", func(t *testing.T) {
+		action := &models.AdminAction{ID: 4, ActionType: models.ActionUserErasure, TargetID: 7, Status: models.ActionStatusPending, RequestedBy: 1}
+		mockApprovals.On("RequestAction", mock.Anything, int64(1), &models.AdminActionRequest{
+			ActionType: models.ActionUserErasure,
+			TargetID:   7,
+			Reason:     "Erasure request from the user",
+		}).Return(action, nil).Once()
+
+		req, err := http.NewRequest("DELETE", "/api/admin/users/7", bytes.NewBufferString(`{"reason":"Erasure request from the user"}`))
+		require.NoError(t, err)
+		req.Header.Set("Content-Type", "application/json")
+		req = req.WithContext(createAuthContext(1))
+		rr := httptest.NewRecorder()
+
+		router.ServeHTTP(rr, req)
+
+		golden.AssertResponse(t, rr)
+		mockApprovals.AssertExpectations(t)
+	})
+
+	t.Run("Missing Reason", func(t *testing.T) {
+		req, err := http.NewRequest("DELETE", "/api/admin/users/7", bytes.NewBufferString(`{}`))
+		require.NoError(t, err)
+		req.Header.Set("Content-Type", "application/json")
+		req = req.WithContext(createAuthContext(1))
+		rr := httptest.NewRecorder()
+
+		router.ServeHTTP(rr, req)
+
+		assert.Equal(t, http.StatusBadRequest, rr.Code)
+	})
+
+	t.Run("Own Account", func(t *testing.T) {
+		req, err := http.NewRequest("DELETE", "/api/admin/users/1", bytes.NewBufferString(`{"reason":"Leaving the team"}`))
+		require.NoError(t, err)
+		req.Header.Set("Content-Type", "application/json")
+		req = req.WithContext(createAuthContext(1))
+		rr := httptest.NewRecorder()
+
+		router.ServeHTTP(rr, req)
+
+		assert.Equal(t, http.StatusForbidden, rr.Code)
+	})
+
+	t.Run("Unauthenticated", func(t *testing.T) {
+		req, err := http.NewRequest("DELETE", "/api/admin/users/7", bytes.NewBufferString(`{"reason":"Erasure request from the user"}`))
+		require.NoError(t, err)
+		rr := httptest.NewRecorder()
+
+		router.ServeHTTP(rr, req)
+
+		assert.Equal(t, http.StatusUnauthorized, rr.Code)
+	})
+}
+
+func TestRevokeUserSessions(t *testing.T) {
+	handler, mockService := setupUserTest(t)
+
+	router := chi.NewRouter()
+	router.Delete("/api/admin/users/{id}/sessions", handler.RevokeUserSessions)
+
+	t.Run("Success", func(t *testing.T) {
+		mockService.On("RevokeSessions", mock.Anything, int64(7)).Return(2, nil).Once()
+
+		req, err := http.NewRequest("DELETE", "/api/admin/users/7/sessions", nil)
+		require.NoError(t, err)
+		rr := httptest.NewRecorder()
+
+		router.ServeHTTP(rr, req)
+
+		golden.AssertResponse(t, rr)
 		mockService.AssertExpectations(t)
 	})
+
+	t.Run("Invalid ID", func(t *testing.T) {
+		req, err := http.NewRequest("DELETE", "/api/admin/users/abc/sessions", nil)
+		require.NoError(t, err)
+		rr := httptest.NewRecorder()
+
+		router.ServeHTTP(rr, req)
+
+		assert.Equal(t, http.StatusBadRequest, rr.Code)
+	})
 }
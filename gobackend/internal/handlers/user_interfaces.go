@@ -122,4 +122,77 @@ type UserServiceInterface interface {
 	// This method enables the "logout from specific device" feature, enhancing
 	// security by allowing users to terminate suspicious sessions.
 	InvalidateSession(ctx context.Context, userID int64, sessionID string) error
+
+	// FindUser looks up a user by exactly one of ID, username or email for administrators.
+	//
+	// Parameters:
+	//   - ctx: The context for the operation, which may include deadlines or cancellation
+	//   - id: The user ID, or 0
+	//   - username: The username, or the empty string
+	//   - email: The email address, or the empty string
+	//
+	// Returns:
+	//   - The user if found, with sensitive fields removed
+	//   - An error if not exactly one identifier is set, if the user doesn't exist, or if database access fails
+	FindUser(ctx context.Context, id int64, username, email string) (*models.User, error)
+
+	// RevokeSessions terminates all sessions of a user for administrators.
+	//
+	// Parameters:
+	//   - ctx: The context for the operation, which may include deadlines or cancellation
+	//   - userID: The unique identifier of the user whose sessions to revoke
+	//
+	// Returns:
+	//   - The number of active sessions that were revoked
+	//   - An error if the user doesn't exist or if database access fails
+	RevokeSessions(ctx context.Context, userID int64) (int, error)
+
+	// ListUsers lists users for administrators with pagination.
+	//
+	// Parameters:
+	//   - ctx: The context for the operation, which may include deadlines or cancellation
+	//   - filter: The role and status to select; empty fields select all users
+	//   - page: The page number (starting from 1)
+	//   - pageSize: The number of users per page
+	//
+	// Returns:
+	//   - The users of the page, with sensitive fields removed
+	//   - The total number of matching users
+	//   - An error if the role or status is unknown or if database access fails
+	ListUsers(ctx context.Context, filter models.UserFilter, page, pageSize int) ([]*models.User, int, error)
+
+	// DisableUser stops a user from signing in and revokes their sessions.
+	//
+	// Parameters:
+	//   - ctx: The context for the operation, which may include deadlines or cancellation
+	//   - adminID: The unique identifier of the administrator
+	//   - userID: The unique identifier of the user to disable
+	//
+	// Returns:
+	//   - An error if the administrator targets their own account, if the user doesn't exist, or if database access fails
+	DisableUser(ctx context.Context, adminID, userID int64) error
+
+	// EnableUser lets a disabled user sign in again.
+	//
+	// Parameters:
+	//   - ctx: The context for the operation, which may include deadlines or cancellation
+	//   - adminID: The unique identifier of the administrator
+	//   - userID: The unique identifier of the user to enable
+	//
+	// Returns:
+	//   - An error if the user doesn't exist or if database access fails
+	EnableUser(ctx context.Context, adminID, userID int64) error
+
+	// SetRole changes the role of a user.
+	//
+	// Parameters:
+	//   - ctx: The context for the operation, which may include deadlines or cancellation
+	//   - adminID: The unique identifier of the administrator
+	//   - userID: The unique identifier of the user
+	//   - role: The new role
+	//
+	// Returns:
+	//   - The updated user, with sensitive fields removed
+	//   - An error if the administrator targets their own account or the admin role, if the user doesn't exist, or if database access fails
+	SetRole(ctx context.Context, adminID, userID int64, role string) (*models.User, error)
 }
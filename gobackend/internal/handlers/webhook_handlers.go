@@ -0,0 +1,305 @@
+// Package handlers provides HTTP request handlers for the HideMe API.
+package handlers
+
+import (
+	"context"
+	"net/http"
+	"strconv"
+
+	"github.com/go-chi/chi/v5"
+
+	"github.com/yasinhessnawi1/Hideme_Backend/internal/auth"
+	"github.com/yasinhessnawi1/Hideme_Backend/internal/constants"
+	"github.com/yasinhessnawi1/Hideme_Backend/internal/models"
+	"github.com/yasinhessnawi1/Hideme_Backend/internal/utils"
+)
+
+// WebhookServiceInterface defines the service methods required for webhooks and their deliveries.
+type WebhookServiceInterface interface {
+	ListWebhooks(ctx context.Context, userID int64) ([]*models.Webhook, error)
+	CreateWebhook(ctx context.Context, userID int64, req *models.WebhookCreate) (*models.Webhook, error)
+	DeleteWebhook(ctx context.Context, userID, webhookID int64) error
+	ListDeliveries(ctx context.Context, userID, webhookID int64) ([]*models.WebhookDelivery, error)
+	Redeliver(ctx context.Context, userID, webhookID, deliveryID int64) (*models.WebhookDelivery, error)
+}
+
+// WebhookHandler handles HTTP requests for the URLs users receive account and document
+// events at, and the deliveries of those events.
+type WebhookHandler struct {
+	webhookService WebhookServiceInterface
+}
+
+// NewWebhookHandler creates a new WebhookHandler with the provided service.
+//
+// Parameters:
+//   - webhookService: Service managing webhooks and delivering events
+//
+// Returns:
+//   - A properly initialized WebhookHandler
+func NewWebhookHandler(webhookService WebhookServiceInterface) *WebhookHandler {
+	return &WebhookHandler{
+		webhookService: webhookService,
+	}
+}
+
+// ListWebhooks lists the user's webhooks. Secrets are never returned.
+//
+// HTTP Method:
+//   - GET
+//
+// URL Path:
+//   - /api/webhooks
+//
+// Requires:
+//   - Authentication: User must be logged in
+//
+// Responses:
+//   - 200 OK: The user's webhooks
+//   - 401 Unauthorized: User not authenticated
+//   - 500 Internal Server Error: Server-side error
+//
+// @Summary List webhooks
+// @Description Lists the URLs the user receives events at, with the events each receives, without secrets
+// @Tags Webhooks
+// @Produce json
+// @Security BearerAuth
+// @Success 200 {object} utils.Response{data=[]models.Webhook} "Webhooks"
+// @Failure 401 {object} utils.Response{error=string} "User not authenticated"
+// @Failure 500 {object} utils.Response{error=string} "Server error"
+// @Router /webhooks [get]
+func (h *WebhookHandler) ListWebhooks(w http.ResponseWriter, r *http.Request) {
+	userID, ok := auth.GetUserID(r)
+	if !ok {
+		utils.Unauthorized(w, constants.MsgAuthRequired)
+		return
+	}
+
+	webhooks, err := h.webhookService.ListWebhooks(r.Context(), userID)
+	if err != nil {
+		utils.ErrorFromAppError(w, utils.ParseError(err))
+		return
+	}
+
+	utils.ListAll(w, r, webhooks)
+}
+
+// CreateWebhook registers a webhook. Events occurring afterwards are posted to it, signed
+// with its secret.
+//
+// HTTP Method:
+//   - POST
+//
+// URL Path:
+//   - /api/webhooks
+//
+// Request Body:
+//   - models.WebhookCreate: URL, secret and events
+//
+// Requires:
+//   - Authentication: User must be logged in
+//
+// Responses:
+//   - 201 Created: The webhook, without its secret
+//   - 400 Bad Request: Invalid URL, secret or event
+//   - 401 Unauthorized: User not authenticated
+//   - 409 Conflict: The user registered the maximum number of webhooks
+//   - 500 Internal Server Error: Server-side error
+//
+// @Summary Register a webhook
+// @Description Registers a URL that receives document.processed, entity.detected, apikey.created or slo.alert events as signed JSON
+// @Tags Webhooks
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param webhook body models.WebhookCreate true "Webhook"
+// @Success 201 {object} utils.Response{data=models.Webhook} "Webhook registered"
+// @Failure 400 {object} utils.Response{error=string} "Invalid URL, secret or event"
+// @Failure 401 {object} utils.Response{error=string} "User not authenticated"
+// @Failure 409 {object} utils.Response{error=string} "Too many webhooks"
+// @Failure 500 {object} utils.Response{error=string} "Server error"
+// @Router /webhooks [post]
+func (h *WebhookHandler) CreateWebhook(w http.ResponseWriter, r *http.Request) {
+	userID, ok := auth.GetUserID(r)
+	if !ok {
+		utils.Unauthorized(w, constants.MsgAuthRequired)
+		return
+	}
+
+	var req models.WebhookCreate
+	if err := utils.DecodeAndValidate(r, &req); err != nil {
+		utils.ErrorFromAppError(w, utils.ParseError(err))
+		return
+	}
+
+	webhook, err := h.webhookService.CreateWebhook(r.Context(), userID, &req)
+	if err != nil {
+		utils.ErrorFromAppError(w, utils.ParseError(err))
+		return
+	}
+
+	utils.JSON(w, constants.StatusCreated, webhook)
+}
+
+// DeleteWebhook removes a webhook with its deliveries, including those not yet made.
+//
+// HTTP Method:
+//   - DELETE
+//
+// URL Path:
+//   - /api/webhooks/{id}
+//
+// Requires:
+//   - Authentication: User must be logged in
+//
+// Responses:
+//   - 204 No Content: Webhook deleted
+//   - 400 Bad Request: Invalid webhook ID
+//   - 401 Unauthorized: User not authenticated
+//   - 404 Not Found: The user has no such webhook
+//   - 500 Internal Server Error: Server-side error
+//
+// @Summary Delete a webhook
+// @Description Removes a webhook; its pending deliveries are not made
+// @Tags Webhooks
+// @Security BearerAuth
+// @Param id path int true "Webhook ID"
+// @Success 204 "Webhook deleted"
+// @Failure 400 {object} utils.Response{error=string} "Invalid webhook ID"
+// @Failure 401 {object} utils.Response{error=string} "User not authenticated"
+// @Failure 404 {object} utils.Response{error=string} "Webhook not found"
+// @Failure 500 {object} utils.Response{error=string} "Server error"
+// @Router /webhooks/{id} [delete]
+func (h *WebhookHandler) DeleteWebhook(w http.ResponseWriter, r *http.Request) {
+	userID, ok := auth.GetUserID(r)
+	if !ok {
+		utils.Unauthorized(w, constants.MsgAuthRequired)
+		return
+	}
+
+	webhookID, err := strconv.ParseInt(chi.URLParam(r, constants.ParamID), 10, 64)
+	if err != nil {
+		utils.BadRequest(w, "Invalid webhook ID", nil)
+		return
+	}
+
+	if err := h.webhookService.DeleteWebhook(r.Context(), userID, webhookID); err != nil {
+		utils.ErrorFromAppError(w, utils.ParseError(err))
+		return
+	}
+
+	utils.NoContent(w)
+}
+
+// ListDeliveries returns the most recent deliveries to a webhook with their status, attempts
+// and the webhook's last response.
+//
+// HTTP Method:
+//   - GET
+//
+// URL Path:
+//   - /api/webhooks/{id}/deliveries
+//
+// Requires:
+//   - Authentication: User must be logged in
+//
+// Responses:
+//   - 200 OK: Deliveries of the webhook, newest first
+//   - 400 Bad Request: Invalid webhook ID
+//   - 401 Unauthorized: User not authenticated
+//   - 404 Not Found: The user has no such webhook
+//   - 500 Internal Server Error: Server-side error
+//
+// @Summary List webhook deliveries
+// @Description Lists the most recent deliveries to a webhook: pending, delivered, or dead once they used up their attempts
+// @Tags Webhooks
+// @Produce json
+// @Security BearerAuth
+// @Param id path int true "Webhook ID"
+// @Success 200 {object} utils.Response{data=[]models.WebhookDelivery} "Deliveries"
+// @Failure 400 {object} utils.Response{error=string} "Invalid webhook ID"
+// @Failure 401 {object} utils.Response{error=string} "User not authenticated"
+// @Failure 404 {object} utils.Response{error=string} "Webhook not found"
+// @Failure 500 {object} utils.Response{error=string} "Server error"
+// @Router /webhooks/{id}/deliveries [get]
+func (h *WebhookHandler) ListDeliveries(w http.ResponseWriter, r *http.Request) {
+	userID, ok := auth.GetUserID(r)
+	if !ok {
+		utils.Unauthorized(w, constants.MsgAuthRequired)
+		return
+	}
+
+	webhookID, err := strconv.ParseInt(chi.URLParam(r, constants.ParamID), 10, 64)
+	if err != nil {
+		utils.BadRequest(w, "Invalid webhook ID", nil)
+		return
+	}
+
+	deliveries, err := h.webhookService.ListDeliveries(r.Context(), userID, webhookID)
+	if err != nil {
+		utils.ErrorFromAppError(w, utils.ParseError(err))
+		return
+	}
+
+	utils.ListAll(w, r, deliveries)
+}
+
+// Redeliver queues a dead-lettered delivery again, once the receiver is fixed.
+//
+// HTTP Method:
+//   - POST
+//
+// URL Path:
+//   - /api/webhooks/{id}/deliveries/{deliveryID}/redeliver
+//
+// Requires:
+//   - Authentication: User must be logged in
+//
+// Responses:
+//   - 202 Accepted: The delivery is queued again
+//   - 400 Bad Request: Invalid webhook or delivery ID
+//   - 401 Unauthorized: User not authenticated
+//   - 404 Not Found: The user has no such webhook or delivery
+//   - 409 Conflict: The delivery has not used up its attempts
+//   - 500 Internal Server Error: Server-side error
+//
+// @Summary Redeliver a webhook delivery
+// @Description Queues a dead-lettered delivery again with all its attempts; it is made within seconds
+// @Tags Webhooks
+// @Produce json
+// @Security BearerAuth
+// @Param id path int true "Webhook ID"
+// @Param deliveryID path int true "Delivery ID"
+// @Success 202 {object} utils.Response{data=models.WebhookDelivery} "Delivery queued"
+// @Failure 400 {object} utils.Response{error=string} "Invalid ID"
+// @Failure 401 {object} utils.Response{error=string} "User not authenticated"
+// @Failure 404 {object} utils.Response{error=string} "Webhook or delivery not found"
+// @Failure 409 {object} utils.Response{error=string} "Delivery not dead-lettered"
+// @Failure 500 {object} utils.Response{error=string} "Server error"
+// @Router /webhooks/{id}/deliveries/{deliveryID}/redeliver [post]
+func (h *WebhookHandler) Redeliver(w http.ResponseWriter, r *http.Request) {
+	userID, ok := auth.GetUserID(r)
+	if !ok {
+		utils.Unauthorized(w, constants.MsgAuthRequired)
+		return
+	}
+
+	webhookID, err := strconv.ParseInt(chi.URLParam(r, constants.ParamID), 10, 64)
+	if err != nil {
+		utils.BadRequest(w, "Invalid webhook ID", nil)
+		return
+	}
+
+	deliveryID, err := strconv.ParseInt(chi.URLParam(r, constants.ParamDeliveryID), 10, 64)
+	if err != nil {
+		utils.BadRequest(w, "Invalid delivery ID", nil)
+		return
+	}
+
+	delivery, err := h.webhookService.Redeliver(r.Context(), userID, webhookID, deliveryID)
+	if err != nil {
+		utils.ErrorFromAppError(w, utils.ParseError(err))
+		return
+	}
+
+	utils.JSON(w, constants.StatusAccepted, delivery)
+}
@@ -0,0 +1,229 @@
+package handlers_test
+
+import (
+	"bytes"
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+
+	"github.com/yasinhessnawi1/Hideme_Backend/internal/constants"
+	"github.com/yasinhessnawi1/Hideme_Backend/internal/handlers"
+	"github.com/yasinhessnawi1/Hideme_Backend/internal/models"
+	"github.com/yasinhessnawi1/Hideme_Backend/internal/service"
+	"github.com/yasinhessnawi1/Hideme_Backend/internal/testsupport/golden"
+	"github.com/yasinhessnawi1/Hideme_Backend/internal/utils"
+)
+
+// MockWebhookService is a mock implementation of the WebhookService
+type MockWebhookService struct {
+	mock.Mock
+}
+
+func (m *MockWebhookService) ListWebhooks(ctx context.Context, userID int64) ([]*models.Webhook, error) {
+	args := m.Called(ctx, userID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]*models.Webhook), args.Error(1)
+}
+
+func (m *MockWebhookService) CreateWebhook(ctx context.Context, userID int64, req *models.WebhookCreate) (*models.Webhook, error) {
+	args := m.Called(ctx, userID, req)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*models.Webhook), args.Error(1)
+}
+
+func (m *MockWebhookService) DeleteWebhook(ctx context.Context, userID, webhookID int64) error {
+	args := m.Called(ctx, userID, webhookID)
+	return args.Error(0)
+}
+
+func (m *MockWebhookService) ListDeliveries(ctx context.Context, userID, webhookID int64) ([]*models.WebhookDelivery, error) {
+	args := m.Called(ctx, userID, webhookID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]*models.WebhookDelivery), args.Error(1)
+}
+
+func (m *MockWebhookService) Redeliver(ctx context.Context, userID, webhookID, deliveryID int64) (*models.WebhookDelivery, error) {
+	args := m.Called(ctx, userID, webhookID, deliveryID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*models.WebhookDelivery), args.Error(1)
+}
+
+func setupWebhookTest() (*chi.Mux, *MockWebhookService) {
+	mockService := new(MockWebhookService)
+	handler := handlers.NewWebhookHandler(mockService)
+
+	router := chi.NewRouter()
+	router.Get("/api/webhooks", handler.ListWebhooks)
+	router.Post("/api/webhooks", handler.CreateWebhook)
+	router.Delete("/api/webhooks/{id}", handler.DeleteWebhook)
+	router.Get("/api/webhooks/{id}/deliveries", handler.ListDeliveries)
+	router.Post("/api/webhooks/{id}/deliveries/{deliveryID}/redeliver", handler.Redeliver)
+
+	return router, mockService
+}
+
+func TestWebhooks(t *testing.T) {
+	router, mockService := setupWebhookTest()
+
+	t.Run("List", func(t *testing.T) {
+		mockService.On("ListWebhooks", mock.Anything, int64(1)).Return([]*models.Webhook{
+			{ID: 2, URL: "https://hooks.example.com", Secret: "0123456789abcdef", Events: []string{constants.WebhookEventDocumentProcessed}, Active: true},
+		}, nil).Once()
+
+		req, err := http.NewRequest("GET", "/api/webhooks", nil)
+		require.NoError(t, err)
+		req = req.WithContext(createAuthContext(1))
+		rr := httptest.NewRecorder()
+		router.ServeHTTP(rr, req)
+
+		golden.AssertResponse(t, rr)
+	})
+
+	t.Run("Create", func(t *testing.T) {
+		mockService.On("CreateWebhook", mock.Anything, int64(1), mock.MatchedBy(func(req *models.WebhookCreate) bool {
+			return req.URL == "https://hooks.example.com" && len(req.Events) == 2
+		})).Return(&models.Webhook{ID: 3, URL: "https://hooks.example.com", Active: true}, nil).Once()
+
+		body := `{"url":"https://hooks.example.com","secret":"0123456789abcdef","events":["document.processed","entity.detected"]}`
+		req, err := http.NewRequest("POST", "/api/webhooks", bytes.NewBufferString(body))
+		require.NoError(t, err)
+		req.Header.Set("Content-Type", "application/json")
+		req = req.WithContext(createAuthContext(1))
+		rr := httptest.NewRecorder()
+		router.ServeHTTP(rr, req)
+
+		golden.AssertResponse(t, rr)
+	})
+
+	t.Run("Create Without Events", func(t *testing.T) {
+		body := `{"url":"https://hooks.example.com","secret":"0123456789abcdef"}`
+		req, err := http.NewRequest("POST", "/api/webhooks", bytes.NewBufferString(body))
+		require.NoError(t, err)
+		req.Header.Set("Content-Type", "application/json")
+		req = req.WithContext(createAuthContext(1))
+		rr := httptest.NewRecorder()
+		router.ServeHTTP(rr, req)
+
+		assert.Equal(t, http.StatusBadRequest, rr.Code)
+	})
+
+	t.Run("Create Too Many", func(t *testing.T) {
+		mockService.On("CreateWebhook", mock.Anything, int64(2), mock.Anything).Return(nil, service.ErrTooManyWebhooks).Once()
+
+		body := `{"url":"https://hooks.example.com","secret":"0123456789abcdef","events":["apikey.created"]}`
+		req, err := http.NewRequest("POST", "/api/webhooks", bytes.NewBufferString(body))
+		require.NoError(t, err)
+		req.Header.Set("Content-Type", "application/json")
+		req = req.WithContext(createAuthContext(2))
+		rr := httptest.NewRecorder()
+		router.ServeHTTP(rr, req)
+
+		assert.Equal(t, http.StatusConflict, rr.Code)
+	})
+
+	t.Run("Delete", func(t *testing.T) {
+		mockService.On("DeleteWebhook", mock.Anything, int64(1), int64(3)).Return(nil).Once()
+
+		req, err := http.NewRequest("DELETE", "/api/webhooks/3", nil)
+		require.NoError(t, err)
+		req = req.WithContext(createAuthContext(1))
+		rr := httptest.NewRecorder()
+		router.ServeHTTP(rr, req)
+
+		assert.Equal(t, http.StatusNoContent, rr.Code)
+	})
+
+	t.Run("Delete Not Found", func(t *testing.T) {
+		mockService.On("DeleteWebhook", mock.Anything, int64(1), int64(9)).
+			Return(utils.NewNotFoundError("Webhook", int64(9))).Once()
+
+		req, err := http.NewRequest("DELETE", "/api/webhooks/9", nil)
+		require.NoError(t, err)
+		req = req.WithContext(createAuthContext(1))
+		rr := httptest.NewRecorder()
+		router.ServeHTTP(rr, req)
+
+		assert.Equal(t, http.StatusNotFound, rr.Code)
+	})
+
+	t.Run("Unauthorized", func(t *testing.T) {
+		req, err := http.NewRequest("GET", "/api/webhooks", nil)
+		require.NoError(t, err)
+		rr := httptest.NewRecorder()
+		router.ServeHTTP(rr, req)
+
+		assert.Equal(t, http.StatusUnauthorized, rr.Code)
+	})
+
+	mockService.AssertExpectations(t)
+}
+
+func TestWebhookDeliveries(t *testing.T) {
+	router, mockService := setupWebhookTest()
+
+	t.Run("List", func(t *testing.T) {
+		mockService.On("ListDeliveries", mock.Anything, int64(1), int64(2)).Return([]*models.WebhookDelivery{
+			{ID: 41, WebhookID: 2, EventType: constants.WebhookEventDocumentProcessed, Status: constants.WebhookDeliveryDead, Attempts: 10, LastStatusCode: 503},
+		}, nil).Once()
+
+		req, err := http.NewRequest("GET", "/api/webhooks/2/deliveries", nil)
+		require.NoError(t, err)
+		req = req.WithContext(createAuthContext(1))
+		rr := httptest.NewRecorder()
+		router.ServeHTTP(rr, req)
+
+		golden.AssertResponse(t, rr)
+	})
+
+	t.Run("Redeliver", func(t *testing.T) {
+		mockService.On("Redeliver", mock.Anything, int64(1), int64(2), int64(41)).
+			Return(&models.WebhookDelivery{ID: 41, WebhookID: 2, Status: constants.WebhookDeliveryPending}, nil).Once()
+
+		req, err := http.NewRequest("POST", "/api/webhooks/2/deliveries/41/redeliver", nil)
+		require.NoError(t, err)
+		req = req.WithContext(createAuthContext(1))
+		rr := httptest.NewRecorder()
+		router.ServeHTTP(rr, req)
+
+		golden.AssertResponse(t, rr)
+	})
+
+	t.Run("Redeliver Not Dead", func(t *testing.T) {
+		mockService.On("Redeliver", mock.Anything, int64(1), int64(2), int64(42)).
+			Return(nil, service.ErrWebhookDeliveryNotDead).Once()
+
+		req, err := http.NewRequest("POST", "/api/webhooks/2/deliveries/42/redeliver", nil)
+		require.NoError(t, err)
+		req = req.WithContext(createAuthContext(1))
+		rr := httptest.NewRecorder()
+		router.ServeHTTP(rr, req)
+
+		assert.Equal(t, http.StatusConflict, rr.Code)
+	})
+
+	t.Run("Invalid Delivery ID", func(t *testing.T) {
+		req, err := http.NewRequest("POST", "/api/webhooks/2/deliveries/abc/redeliver", nil)
+		require.NoError(t, err)
+		req = req.WithContext(createAuthContext(1))
+		rr := httptest.NewRecorder()
+		router.ServeHTTP(rr, req)
+
+		assert.Equal(t, http.StatusBadRequest, rr.Code)
+	})
+
+	mockService.AssertExpectations(t)
+}
@@ -10,23 +10,26 @@ package middleware
 
 import (
 	"context"
+	"fmt"
 	"net/http"
+	"strconv"
 	"strings"
+	"time"
 
 	"github.com/yasinhessnawi1/Hideme_Backend/internal/handlers"
 
 	"github.com/rs/zerolog/log"
 
 	"github.com/yasinhessnawi1/Hideme_Backend/internal/auth"
+	"github.com/yasinhessnawi1/Hideme_Backend/internal/config"
 	"github.com/yasinhessnawi1/Hideme_Backend/internal/constants"
+	"github.com/yasinhessnawi1/Hideme_Backend/internal/models"
 	"github.com/yasinhessnawi1/Hideme_Backend/internal/utils"
 )
 
-// Define a custom type for context keys
-type contextKeyUserRole struct{}
-
 // JWTAuth is a middleware that requires a valid JWT token for a request to proceed.
-// It verifies the token signature, expiration, and that it's an access token.
+// It verifies the token signature, expiration, and that it's an access token, and adds
+// the role of the token to the context with AddRoleToContext.
 //
 // Parameters:
 //   - jwtService: A service that can validate JWT tokens
@@ -35,16 +38,32 @@ type contextKeyUserRole struct{}
 //   - A middleware function that can be used with an HTTP handler
 func JWTAuth(jwtService auth.JWTValidator) func(http.Handler) http.Handler {
 	provider := auth.NewJWTAuthProvider(jwtService)
-	return auth.RequireAuth(provider)
+	requireAuth := auth.RequireAuth(provider)
+	addRole := AddRoleToContext(jwtService)
+	return func(next http.Handler) http.Handler {
+		return requireAuth(addRole(next))
+	}
 }
 
-// APIKeyAuth is a middleware that requires a valid API key for a request to proceed.
-// This is a placeholder implementation that will be completed when the repository layer is available.
+// APIKeyAuth is a middleware that requires a valid API key in the X-API-Key header.
+// The key's owner becomes the authenticated user of the request, and their current
+// role is added to the context so RequireRole can be applied after it. Responses
+// leave out the fields redacted for the role.
+//
+// If the verifier implements auth.APIKeyScopeVerifier, the scopes of the key are added
+// to the context so RequireScope can be applied after it; otherwise keys have full access.
+//
+// If the verifier implements auth.APIKeyBackoff, clients that sent an invalid key are
+// answered with 429 Too Many Requests until their backoff has passed.
+//
+// Parameters:
+//   - verifier: Resolves API keys to their owners
 //
 // Returns:
 //   - A middleware function that can be used with an HTTP handler
-func APIKeyAuth( /* apiKeyRepo repository.APIKeyRepository */ ) func(http.Handler) http.Handler {
-	// Simple stub implementation for now
+func APIKeyAuth(verifier auth.APIKeyVerifier) func(http.Handler) http.Handler {
+	backoff, _ := verifier.(auth.APIKeyBackoff)
+	scoped, _ := verifier.(auth.APIKeyScopeVerifier)
 	return func(next http.Handler) http.Handler {
 		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 			// Get API key from header
@@ -54,10 +73,89 @@ func APIKeyAuth( /* apiKeyRepo repository.APIKeyRepository */ ) func(http.Handle
 				return
 			}
 
-			// Here we would validate the API key against the database
-			// For now, just pass it through as we'll implement this properly later
+			// Make clients that sent invalid keys wait before they may try again
+			ip := getClientIP(r)
+			if backoff != nil {
+				if wait := backoff.RetryAfter(ip); wait > 0 {
+					writeAPIKeyBackoff(w, wait)
+					return
+				}
+			}
 
-			next.ServeHTTP(w, r)
+			var user *models.User
+			var scopes []string
+			var err error
+			if scoped != nil {
+				user, scopes, err = scoped.VerifyAPIKeyScopes(r.Context(), apiKey)
+			} else {
+				user, err = verifier.VerifyAPIKey(r.Context(), apiKey)
+			}
+			if err != nil {
+				log.Info().
+					Err(err).
+					Str("method", r.Method).
+					Str("path", r.URL.Path).
+					Msg("API key authentication failed")
+				// Only invalid keys count as failures, not errors while checking them
+				if backoff != nil && utils.StatusCode(err) == constants.StatusUnauthorized {
+					backoff.RecordFailure(ip)
+				}
+				utils.ErrorFromAppError(w, utils.ParseError(err))
+				return
+			}
+			if backoff != nil {
+				backoff.RecordSuccess(ip)
+			}
+
+			// Add the key owner to the context, as the JWT providers do
+			ctx := context.WithValue(r.Context(), auth.UserIDContextKey, user.ID)
+			ctx = context.WithValue(ctx, auth.UsernameContextKey, user.Username)
+			ctx = context.WithValue(ctx, auth.EmailContextKey, user.Email)
+			ctx = context.WithValue(ctx, handlers.GetContextKeyUserRole(), user.Role)
+			ctx = context.WithValue(ctx, auth.APIKeyScopesContextKey, scopes)
+
+			log.Info().
+				Int64("user_id", user.ID).
+				Str("method", r.Method).
+				Str("path", r.URL.Path).
+				Msg("User authenticated with API key")
+
+			next.ServeHTTP(utils.WithResponseRole(w, user.Role), r.WithContext(ctx))
+		})
+	}
+}
+
+// writeAPIKeyBackoff answers a client that must wait after sending invalid API keys.
+func writeAPIKeyBackoff(w http.ResponseWriter, wait time.Duration) {
+	// Round up so clients never retry before the backoff has passed
+	seconds := int((wait + time.Second - 1) / time.Second)
+	w.Header().Set(constants.HeaderRetryAfter, strconv.Itoa(seconds))
+	utils.Error(w, constants.StatusTooManyRequests, constants.CodeTooManyAttempts, constants.MsgAPIKeyBackoff, nil)
+}
+
+// JWTOrAPIKeyAuth is a middleware that accepts either an access token or an API key.
+// Requests carrying an X-API-Key header are authenticated with APIKeyAuth; all others
+// with JWTAuth. This lets scripts and the hidemectl tool
+// call routes that browsers reach with a session.
+//
+// Parameters:
+//   - jwtService: A service that can validate JWT tokens
+//   - verifier: Resolves API keys to their owners
+//
+// Returns:
+//   - A middleware function that can be used with an HTTP handler
+func JWTOrAPIKeyAuth(jwtService auth.JWTValidator, verifier auth.APIKeyVerifier) func(http.Handler) http.Handler {
+	apiKeyAuth := APIKeyAuth(verifier)
+	jwtAuth := JWTAuth(jwtService)
+	return func(next http.Handler) http.Handler {
+		withAPIKey := apiKeyAuth(next)
+		withJWT := jwtAuth(next)
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if r.Header.Get(constants.HeaderXAPIKey) != "" {
+				withAPIKey.ServeHTTP(w, r)
+				return
+			}
+			withJWT.ServeHTTP(w, r)
 		})
 	}
 }
@@ -105,6 +203,57 @@ func RequireRole(role string) func(http.Handler) http.Handler {
 	}
 }
 
+// RequireScope is middleware that requires requests authenticated with an API key to be
+// allowed the given scope. Requests authenticated otherwise, and keys without scopes, are
+// not limited. It must be applied after the authentication middleware.
+//
+// Parameters:
+//   - scope: The constants.APIKeyScope value the endpoint requires
+//
+// Returns:
+//   - A middleware function that can be used with an HTTP handler
+func RequireScope(scope string) func(http.Handler) http.Handler {
+	return RequireReadWriteScope(scope, scope)
+}
+
+// RequireReadWriteScope is middleware like RequireScope that requires the read scope for
+// safe methods (GET, HEAD and OPTIONS) and the write scope for all other methods.
+//
+// Parameters:
+//   - readScope: The scope required to read, e.g. constants.APIKeyScopeSettingsRead
+//   - writeScope: The scope required to change, e.g. constants.APIKeyScopeSettingsWrite
+//
+// Returns:
+//   - A middleware function that can be used with an HTTP handler
+func RequireReadWriteScope(readScope, writeScope string) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			scopes, ok := auth.GetAPIKeyScopes(r)
+			if !ok {
+				next.ServeHTTP(w, r)
+				return
+			}
+
+			scope := writeScope
+			if r.Method == http.MethodGet || r.Method == http.MethodHead || r.Method == http.MethodOptions {
+				scope = readScope
+			}
+			if !models.APIKeyScopesAllow(scopes, scope) {
+				userID, _ := auth.GetUserID(r)
+				log.Warn().
+					Int64("user_id", userID).
+					Strs("scopes", scopes).
+					Str("required_scope", scope).
+					Msg("Access denied: API key scope missing")
+				utils.Forbidden(w, constants.MsgAPIKeyScopeMissing)
+				return
+			}
+
+			next.ServeHTTP(w, r)
+		})
+	}
+}
+
 // CSRF is a middleware that protects against Cross-Site Request Forgery attacks.
 // It verifies that the CSRF token in the request header matches the token in the cookie.
 //
@@ -138,15 +287,30 @@ func CSRF() func(http.Handler) http.Handler {
 // SecurityHeaders adds security-related HTTP headers to responses.
 // These headers help protect against common web vulnerabilities.
 //
+// Parameters:
+//   - settings: The HSTS, referrer and content security policies to apply
+//
 // Returns:
 //   - A middleware function that can be used with an HTTP handler
-//
-// SecurityHeaders adds security-related headers to all responses.
-func SecurityHeaders() func(http.Handler) http.Handler {
+func SecurityHeaders(settings *config.SecurityHeaderSettings) func(http.Handler) http.Handler {
+	// The policies don't change at runtime, so they are formatted once
+	var hsts string
+	if settings.HSTSEnabled {
+		hsts = fmt.Sprintf(constants.HSTSMaxAgeFormat, int(settings.HSTSMaxAge.Seconds()))
+		if settings.HSTSIncludeSubdomains {
+			hsts += constants.HSTSIncludeSubdomains
+		}
+	}
+
 	return func(next http.Handler) http.Handler {
 		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 			// Add security headers
 
+			// Strict-Transport-Security: Keeps browsers on HTTPS
+			if hsts != "" {
+				w.Header().Set(constants.HeaderStrictTransportSecurity, hsts)
+			}
+
 			// X-Content-Type-Options: Prevents MIME type sniffing
 			w.Header().Set(constants.HeaderXContentTypeOptions, constants.ContentTypeOptionsNoSniff)
 
@@ -157,17 +321,17 @@ func SecurityHeaders() func(http.Handler) http.Handler {
 			w.Header().Set(constants.HeaderXXSSProtection, constants.XSSProtectionModeBlock)
 
 			// Referrer-Policy: Controls how much referrer information is sent
-			w.Header().Set(constants.HeaderReferrerPolicy, constants.ReferrerPolicyStrictOrigin)
+			w.Header().Set(constants.HeaderReferrerPolicy, settings.ReferrerPolicy)
 
-			// Content-Security-Policy: Conditionally set based on path
-			if strings.HasPrefix(r.URL.Path, "/docs/") {
-				// Relaxed CSP for Swagger UI to allow inline scripts and styles
-				w.Header().Set(constants.HeaderContentSecurityPolicy,
-					"default-src 'self'; script-src 'self' 'unsafe-inline'; style-src 'self' 'unsafe-inline'; img-src 'self' data:")
-			} else {
-				// Standard strict CSP for all other paths
-				w.Header().Set(constants.HeaderContentSecurityPolicy, constants.CSPDefaultSrc)
+			// Content-Security-Policy: HTML pages such as the Swagger UI get their own policy
+			csp := settings.ContentSecurityPolicy
+			for _, prefix := range settings.HTMLPaths {
+				if strings.HasPrefix(r.URL.Path, prefix) {
+					csp = settings.HTMLContentSecurityPolicy
+					break
+				}
 			}
+			w.Header().Set(constants.HeaderContentSecurityPolicy, csp)
 
 			next.ServeHTTP(w, r)
 		})
@@ -175,7 +339,8 @@ func SecurityHeaders() func(http.Handler) http.Handler {
 }
 
 // AddRoleToContext extracts the JWT token from the Authorization header,
-// validates it, and adds the user role to the request context
+// validates it, and adds the user role to the request context.
+// Responses leave out the fields redacted for the role.
 func AddRoleToContext(jwtService auth.JWTValidator) func(http.Handler) http.Handler {
 	return func(next http.Handler) http.Handler {
 		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
@@ -196,11 +361,11 @@ func AddRoleToContext(jwtService auth.JWTValidator) func(http.Handler) http.Hand
 				return
 			}
 
-			// Add role to context
-			ctx := context.WithValue(r.Context(), contextKeyUserRole{}, claims.Role)
+			// Add role to context under the key RequireRole reads it from
+			ctx := context.WithValue(r.Context(), handlers.GetContextKeyUserRole(), claims.Role)
 
 			// Call the next handler with the updated context
-			next.ServeHTTP(w, r.WithContext(ctx))
+			next.ServeHTTP(utils.WithResponseRole(w, claims.Role), r.WithContext(ctx))
 		})
 	}
 }
@@ -6,10 +6,13 @@ import (
 	"net/http"
 	"net/http/httptest"
 	"testing"
+	"time"
 
 	"github.com/yasinhessnawi1/Hideme_Backend/internal/auth"
 	"github.com/yasinhessnawi1/Hideme_Backend/internal/config"
+	"github.com/yasinhessnawi1/Hideme_Backend/internal/handlers"
 	"github.com/yasinhessnawi1/Hideme_Backend/internal/middleware"
+	"github.com/yasinhessnawi1/Hideme_Backend/internal/models"
 	"github.com/yasinhessnawi1/Hideme_Backend/internal/utils"
 )
 
@@ -105,9 +108,6 @@ func (m *MockHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
 	}
 }
 
-// Define a custom type for context keys
-type contextKeyUserRole struct{}
-
 func TestJWTAuth(t *testing.T) {
 	tests := []struct {
 		name            string
@@ -237,7 +237,24 @@ func TestJWTAuth(t *testing.T) {
 	}
 }
 
+// MockAPIKeyVerifier is a mock implementation of auth.APIKeyVerifier
+type MockAPIKeyVerifier struct {
+	Users map[string]*models.User
+}
+
+// VerifyAPIKey implements the interface method
+func (m *MockAPIKeyVerifier) VerifyAPIKey(ctx context.Context, apiKey string) (*models.User, error) {
+	if user, ok := m.Users[apiKey]; ok {
+		return user, nil
+	}
+	return nil, utils.NewInvalidTokenError()
+}
+
 func TestAPIKeyAuth(t *testing.T) {
+	verifier := &MockAPIKeyVerifier{Users: map[string]*models.User{
+		"valid-api-key": {ID: 7, Username: "operator", Email: "ops@example.com", Role: "admin"},
+	}}
+
 	tests := []struct {
 		name           string
 		apiKeyHeader   string
@@ -256,6 +273,12 @@ func TestAPIKeyAuth(t *testing.T) {
 			expectedStatus: http.StatusUnauthorized,
 			shouldCallNext: false,
 		},
+		{
+			name:           "Unknown API key",
+			apiKeyHeader:   "revoked-api-key",
+			expectedStatus: http.StatusUnauthorized,
+			shouldCallNext: false,
+		},
 	}
 
 	for _, tt := range tests {
@@ -264,7 +287,7 @@ func TestAPIKeyAuth(t *testing.T) {
 			mockHandler := &MockHandler{}
 
 			// Create the middleware
-			middleware := middleware.APIKeyAuth()(mockHandler)
+			middleware := middleware.APIKeyAuth(verifier)(mockHandler)
 
 			// Create a test request
 			req, err := http.NewRequest("GET", "/test", nil)
@@ -296,6 +319,210 @@ func TestAPIKeyAuth(t *testing.T) {
 	}
 }
 
+func TestAPIKeyAuth_Backoff(t *testing.T) {
+	verifier := auth.NewCachingAPIKeyVerifier(&MockAPIKeyVerifier{Users: map[string]*models.User{
+		"valid-api-key": {ID: 7, Username: "operator", Role: "admin"},
+	}}, &config.APIKeySettings{CacheTTL: time.Minute, FailureBackoff: time.Minute, MaxFailureBackoff: time.Hour})
+	handler := middleware.APIKeyAuth(verifier)(&MockHandler{})
+
+	send := func(apiKey, remoteAddr string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest("GET", "/test", nil)
+		req.RemoteAddr = remoteAddr
+		req.Header.Set("X-API-Key", apiKey)
+		rr := httptest.NewRecorder()
+		handler.ServeHTTP(rr, req)
+		return rr
+	}
+
+	// The first invalid key is rejected as usual
+	if rr := send("guessed-api-key", "192.0.2.1:1234"); rr.Code != http.StatusUnauthorized {
+		t.Errorf("First invalid key: got %v want %v", rr.Code, http.StatusUnauthorized)
+	}
+
+	// Further keys from the same client must wait, even valid ones
+	rr := send("valid-api-key", "192.0.2.1:1234")
+	if rr.Code != http.StatusTooManyRequests {
+		t.Errorf("Key during backoff: got %v want %v", rr.Code, http.StatusTooManyRequests)
+	}
+	if retryAfter := rr.Header().Get("Retry-After"); retryAfter != "60" {
+		t.Errorf("Retry-After = %s, want 60", retryAfter)
+	}
+
+	// Other clients are not affected
+	if rr := send("valid-api-key", "192.0.2.2:1234"); rr.Code != http.StatusOK {
+		t.Errorf("Key from another client: got %v want %v", rr.Code, http.StatusOK)
+	}
+}
+
+func TestJWTOrAPIKeyAuth_RequireRole(t *testing.T) {
+	jwtService := &MockJWTService{
+		ValidateTokenFunc: func(tokenString string, expectedType string) (*auth.CustomClaims, error) {
+			switch tokenString {
+			case "admin-token":
+				return &auth.CustomClaims{UserID: 1, Username: "admin", Role: "admin"}, nil
+			case "user-token":
+				return &auth.CustomClaims{UserID: 2, Username: "user", Role: "user"}, nil
+			}
+			return nil, utils.ErrUnauthorized
+		},
+	}
+	verifier := &MockAPIKeyVerifier{Users: map[string]*models.User{
+		"admin-key": {ID: 1, Username: "admin", Role: "admin"},
+		"user-key":  {ID: 2, Username: "user", Role: "user"},
+	}}
+
+	tests := []struct {
+		name           string
+		header         string
+		value          string
+		expectedStatus int
+	}{
+		{name: "Admin access token", header: "Authorization", value: "Bearer admin-token", expectedStatus: http.StatusOK},
+		{name: "User access token", header: "Authorization", value: "Bearer user-token", expectedStatus: http.StatusForbidden},
+		{name: "Admin API key", header: "X-API-Key", value: "admin-key", expectedStatus: http.StatusOK},
+		{name: "User API key", header: "X-API-Key", value: "user-key", expectedStatus: http.StatusForbidden},
+		{name: "No credentials", expectedStatus: http.StatusUnauthorized},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockHandler := &MockHandler{}
+			handler := middleware.JWTOrAPIKeyAuth(jwtService, verifier)(middleware.RequireRole("admin")(mockHandler))
+
+			req := httptest.NewRequest("GET", "/api/admin/config", nil)
+			if tt.header != "" {
+				req.Header.Set(tt.header, tt.value)
+			}
+			rr := httptest.NewRecorder()
+
+			handler.ServeHTTP(rr, req)
+
+			if status := rr.Code; status != tt.expectedStatus {
+				t.Errorf("Handler returned wrong status code: got %v want %v", status, tt.expectedStatus)
+			}
+			if mockHandler.Called != (tt.expectedStatus == http.StatusOK) {
+				t.Errorf("Next handler called = %v", mockHandler.Called)
+			}
+		})
+	}
+}
+
+// MockAPIKeyScopeVerifier is a mock implementation of auth.APIKeyScopeVerifier
+type MockAPIKeyScopeVerifier struct {
+	MockAPIKeyVerifier
+	Scopes map[string][]string
+}
+
+// VerifyAPIKeyScopes implements the interface method
+func (m *MockAPIKeyScopeVerifier) VerifyAPIKeyScopes(ctx context.Context, apiKey string) (*models.User, []string, error) {
+	user, err := m.VerifyAPIKey(ctx, apiKey)
+	if err != nil {
+		return nil, nil, err
+	}
+	return user, m.Scopes[apiKey], nil
+}
+
+func TestJWTOrAPIKeyAuth_RequireReadWriteScope(t *testing.T) {
+	jwtService := &MockJWTService{
+		ValidateTokenFunc: func(tokenString string, expectedType string) (*auth.CustomClaims, error) {
+			if tokenString == "user-token" {
+				return &auth.CustomClaims{UserID: 2, Username: "user", Role: "user"}, nil
+			}
+			return nil, utils.ErrUnauthorized
+		},
+	}
+	user := &models.User{ID: 2, Username: "user", Role: "user"}
+	verifier := &MockAPIKeyScopeVerifier{
+		MockAPIKeyVerifier: MockAPIKeyVerifier{Users: map[string]*models.User{
+			"full-key": user, "read-key": user, "write-key": user, "other-key": user,
+		}},
+		Scopes: map[string][]string{
+			"read-key":  {"settings:read"},
+			"write-key": {"settings:write"},
+			"other-key": {"documents:write"},
+		},
+	}
+
+	tests := []struct {
+		name           string
+		method         string
+		header         string
+		value          string
+		expectedStatus int
+	}{
+		{name: "Access token", method: "PUT", header: "Authorization", value: "Bearer user-token", expectedStatus: http.StatusOK},
+		{name: "Key without scopes", method: "PUT", header: "X-API-Key", value: "full-key", expectedStatus: http.StatusOK},
+		{name: "Read scope reads", method: "GET", header: "X-API-Key", value: "read-key", expectedStatus: http.StatusOK},
+		{name: "Read scope cannot write", method: "PUT", header: "X-API-Key", value: "read-key", expectedStatus: http.StatusForbidden},
+		{name: "Write scope includes read", method: "GET", header: "X-API-Key", value: "write-key", expectedStatus: http.StatusOK},
+		{name: "Write scope writes", method: "PUT", header: "X-API-Key", value: "write-key", expectedStatus: http.StatusOK},
+		{name: "Other scope", method: "GET", header: "X-API-Key", value: "other-key", expectedStatus: http.StatusForbidden},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockHandler := &MockHandler{}
+			handler := middleware.JWTOrAPIKeyAuth(jwtService, verifier)(
+				middleware.RequireReadWriteScope("settings:read", "settings:write")(mockHandler))
+
+			req := httptest.NewRequest(tt.method, "/api/settings", nil)
+			req.Header.Set(tt.header, tt.value)
+			rr := httptest.NewRecorder()
+
+			handler.ServeHTTP(rr, req)
+
+			if status := rr.Code; status != tt.expectedStatus {
+				t.Errorf("Handler returned wrong status code: got %v want %v", status, tt.expectedStatus)
+			}
+			if mockHandler.Called != (tt.expectedStatus == http.StatusOK) {
+				t.Errorf("Next handler called = %v", mockHandler.Called)
+			}
+		})
+	}
+}
+
+func TestJWTOrAPIKeyAuth_ResponseRole(t *testing.T) {
+	jwtService := &MockJWTService{
+		ValidateTokenFunc: func(tokenString string, expectedType string) (*auth.CustomClaims, error) {
+			if tokenString == "auditor-token" {
+				return &auth.CustomClaims{UserID: 3, Username: "auditor", Role: "auditor"}, nil
+			}
+			return nil, utils.ErrUnauthorized
+		},
+	}
+	verifier := &MockAPIKeyVerifier{Users: map[string]*models.User{
+		"support-key": {ID: 4, Username: "support", Role: "support"},
+	}}
+
+	tests := []struct {
+		name   string
+		header string
+		value  string
+		role   string
+	}{
+		{name: "Access token", header: "Authorization", value: "Bearer auditor-token", role: "auditor"},
+		{name: "API key", header: "X-API-Key", value: "support-key", role: "support"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var role string
+			handler := middleware.JWTOrAPIKeyAuth(jwtService, verifier)(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				role, _ = utils.ResponseRole(w)
+			}))
+
+			req := httptest.NewRequest("GET", "/api/users/me", nil)
+			req.Header.Set(tt.header, tt.value)
+			handler.ServeHTTP(httptest.NewRecorder(), req)
+
+			// Responses are redacted for the caller's role
+			if role != tt.role {
+				t.Errorf("Response role = %q, want %q", role, tt.role)
+			}
+		})
+	}
+}
+
 func TestRequireRole(t *testing.T) {
 	tests := []struct {
 		name           string
@@ -304,20 +531,17 @@ func TestRequireRole(t *testing.T) {
 		expectedStatus int
 		shouldCallNext bool
 	}{
-		/*
-			{
-				name: "User has required role",
-				role: "admin",
-				setupContext: func(r *http.Request) *http.Request {
-					ctx := context.WithValue(r.Context(), auth.UserIDContextKey, int64(123))
-					ctx = context.WithValue(ctx, contextKeyUserRole{}, "admin") // Add role to context
-					return r.WithContext(ctx)
-				},
-				expectedStatus: http.StatusOK,
-				shouldCallNext: true,
+		{
+			name: "User has required role",
+			role: "admin",
+			setupContext: func(r *http.Request) *http.Request {
+				ctx := context.WithValue(r.Context(), auth.UserIDContextKey, int64(123))
+				ctx = context.WithValue(ctx, handlers.GetContextKeyUserRole(), "admin") // Add role to context
+				return r.WithContext(ctx)
 			},
-
-		*/
+			expectedStatus: http.StatusOK,
+			shouldCallNext: true,
+		},
 		{
 			name: "User not authenticated",
 			role: "admin",
@@ -486,8 +710,15 @@ func TestSecurityHeaders(t *testing.T) {
 		Response:   "Success",
 	}
 
-	// Create the middleware
-	middleware := middleware.SecurityHeaders()(mockHandler)
+	// Create the middleware with the default policies, HSTS off
+	settings := &config.SecurityHeaderSettings{
+		HSTSMaxAge:                365 * 24 * time.Hour,
+		ReferrerPolicy:            "strict-origin-when-cross-origin",
+		ContentSecurityPolicy:     "default-src 'self'",
+		HTMLContentSecurityPolicy: "default-src 'self'; script-src 'self' 'unsafe-inline'",
+		HTMLPaths:                 []string{"/docs/"},
+	}
+	middleware := middleware.SecurityHeaders(settings)(mockHandler)
 
 	// Create a test request
 	req, err := http.NewRequest("GET", "/test", nil)
@@ -522,4 +753,53 @@ func TestSecurityHeaders(t *testing.T) {
 	if body := rr.Body.String(); body != "Success" {
 		t.Errorf("Handler returned unexpected body: got %v want %v", body, "Success")
 	}
+
+	// HSTS is only sent when enabled
+	if value := rr.Header().Get("Strict-Transport-Security"); value != "" {
+		t.Errorf("Strict-Transport-Security = %s, want none", value)
+	}
+}
+
+func TestSecurityHeaders_Policies(t *testing.T) {
+	settings := &config.SecurityHeaderSettings{
+		HSTSEnabled:               true,
+		HSTSMaxAge:                365 * 24 * time.Hour,
+		HSTSIncludeSubdomains:     true,
+		ReferrerPolicy:            "no-referrer",
+		ContentSecurityPolicy:     "default-src 'none'",
+		HTMLContentSecurityPolicy: "default-src 'self'; script-src 'self' 'unsafe-inline'",
+		HTMLPaths:                 []string{"/docs/", "/status-page/"},
+	}
+	handler := middleware.SecurityHeaders(settings)(&MockHandler{StatusCode: http.StatusOK})
+
+	tests := []struct {
+		path    string
+		wantCSP string
+	}{
+		{path: "/api/documents", wantCSP: "default-src 'none'"},
+		{path: "/docs/index.html", wantCSP: "default-src 'self'; script-src 'self' 'unsafe-inline'"},
+		{path: "/status-page/", wantCSP: "default-src 'self'; script-src 'self' 'unsafe-inline'"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.path, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodGet, tt.path, nil)
+			rr := httptest.NewRecorder()
+
+			handler.ServeHTTP(rr, req)
+
+			if got := rr.Header().Get("Content-Security-Policy"); got != tt.wantCSP {
+				t.Errorf("Content-Security-Policy = %s, want %s", got, tt.wantCSP)
+			}
+			if got := rr.Header().Get("Strict-Transport-Security"); got != "max-age=31536000; includeSubDomains" {
+				t.Errorf("Strict-Transport-Security = %s, want max-age=31536000; includeSubDomains", got)
+			}
+			if got := rr.Header().Get("Referrer-Policy"); got != "no-referrer" {
+				t.Errorf("Referrer-Policy = %s, want no-referrer", got)
+			}
+			if got := rr.Header().Get("X-Content-Type-Options"); got != "nosniff" {
+				t.Errorf("X-Content-Type-Options = %s, want nosniff", got)
+			}
+		})
+	}
 }
@@ -0,0 +1,134 @@
+// Package middleware provides HTTP middleware components.
+package middleware
+
+import (
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/rs/zerolog/log"
+
+	"github.com/yasinhessnawi1/Hideme_Backend/internal/config"
+	"github.com/yasinhessnawi1/Hideme_Backend/internal/constants"
+	"github.com/yasinhessnawi1/Hideme_Backend/internal/database"
+	"github.com/yasinhessnawi1/Hideme_Backend/internal/utils"
+)
+
+// DBHealthSource defines methods required to observe the recent health of the database.
+type DBHealthSource interface {
+	Health() database.HealthStats
+}
+
+// LoadShedder rejects lower-priority requests while the database is slow or failing,
+// so the remaining capacity goes to the requests that matter most instead of every
+// request timing out. Each route group is assigned a priority, which can be
+// overridden through configuration.
+type LoadShedder struct {
+	health     DBHealthSource
+	settings   *config.LoadSheddingSettings
+	priorities map[string]string
+}
+
+// NewLoadShedder creates a new LoadShedder.
+// Invalid entries in the configured route priorities are logged and ignored.
+//
+// Parameters:
+//   - health: The source of the database health; if nil, nothing is shed
+//   - settings: The load shedding thresholds and route priorities
+//
+// Returns:
+//   - A configured LoadShedder
+func NewLoadShedder(health DBHealthSource, settings *config.LoadSheddingSettings) *LoadShedder {
+	priorities := make(map[string]string)
+	for _, entry := range settings.RoutePriorities {
+		group, priority, ok := strings.Cut(entry, "=")
+		group = strings.TrimSpace(group)
+		priority = strings.ToLower(strings.TrimSpace(priority))
+		if !ok || group == "" || !isLoadShedPriority(priority) {
+			log.Warn().Str("entry", entry).Msg("Ignoring invalid load shedding route priority")
+			continue
+		}
+		priorities[group] = priority
+	}
+
+	return &LoadShedder{
+		health:     health,
+		settings:   settings,
+		priorities: priorities,
+	}
+}
+
+// Shed is middleware that rejects requests to a route group with 503 Service Unavailable
+// while the database is too slow or failing for the group's priority.
+//
+// Parameters:
+//   - group: The name of the route group, used to look up its configured priority
+//   - defaultPriority: The priority of the group if none is configured
+//
+// Returns:
+//   - A middleware function that can be used with an HTTP handler
+func (s *LoadShedder) Shed(group, defaultPriority string) func(http.Handler) http.Handler {
+	priority := defaultPriority
+	if configured, ok := s.priorities[group]; ok {
+		priority = configured
+	}
+
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			shed, health := s.shouldShed(priority)
+			if !shed {
+				next.ServeHTTP(w, r)
+				return
+			}
+
+			log.Warn().
+				Str("path", r.URL.Path).
+				Str("method", r.Method).
+				Str("group", group).
+				Str("priority", priority).
+				Dur("db_mean_latency", health.MeanLatency).
+				Float64("db_error_rate", health.ErrorRate).
+				Msg("Shedding request while the database is overloaded")
+
+			w.Header().Set(constants.HeaderRetryAfter, strconv.Itoa(int(constants.LoadShedRetryAfter.Seconds())))
+			utils.Error(w, constants.StatusServiceUnavailable, constants.CodeServiceOverloaded, constants.MsgServiceOverloaded, nil)
+		})
+	}
+}
+
+// shouldShed decides whether a request of the given priority is shed now.
+//
+// Parameters:
+//   - priority: The priority of the request's route group
+//
+// Returns:
+//   - Whether the request is shed
+//   - The database health the decision was based on
+func (s *LoadShedder) shouldShed(priority string) (bool, database.HealthStats) {
+	if !s.settings.Enabled || s.health == nil || priority == constants.LoadShedPriorityCritical {
+		return false, database.HealthStats{}
+	}
+
+	health := s.health.Health()
+	if health.Samples < s.settings.MinSamples {
+		return false, health
+	}
+
+	overloaded := health.MeanLatency >= s.settings.OverloadedLatency || health.ErrorRate >= s.settings.ErrorRate
+	if overloaded {
+		return true, health
+	}
+
+	degraded := health.MeanLatency >= s.settings.DegradedLatency
+	return degraded && priority == constants.LoadShedPriorityLow, health
+}
+
+// isLoadShedPriority reports whether a string names a load shedding priority.
+func isLoadShedPriority(priority string) bool {
+	switch priority {
+	case constants.LoadShedPriorityLow, constants.LoadShedPriorityNormal, constants.LoadShedPriorityCritical:
+		return true
+	default:
+		return false
+	}
+}
@@ -0,0 +1,143 @@
+package middleware_test
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/yasinhessnawi1/Hideme_Backend/internal/config"
+	"github.com/yasinhessnawi1/Hideme_Backend/internal/constants"
+	"github.com/yasinhessnawi1/Hideme_Backend/internal/database"
+	"github.com/yasinhessnawi1/Hideme_Backend/internal/middleware"
+)
+
+// staticHealth reports fixed database health
+type staticHealth struct {
+	stats database.HealthStats
+}
+
+func (h *staticHealth) Health() database.HealthStats {
+	return h.stats
+}
+
+func loadSheddingSettings() *config.LoadSheddingSettings {
+	return &config.LoadSheddingSettings{
+		Enabled:           true,
+		DegradedLatency:   250 * time.Millisecond,
+		OverloadedLatency: time.Second,
+		ErrorRate:         0.25,
+		MinSamples:        20,
+		RoutePriorities:   []string{"documents=low", "broken", "reports=urgent"},
+	}
+}
+
+func TestLoadShedder_Shed(t *testing.T) {
+	tests := []struct {
+		name     string
+		health   database.HealthStats
+		group    string
+		priority string
+		shed     bool
+	}{
+		{
+			name:     "Healthy database",
+			health:   database.HealthStats{Samples: 100, MeanLatency: 10 * time.Millisecond},
+			group:    "analytics",
+			priority: constants.LoadShedPriorityLow,
+		},
+		{
+			name:     "Too few samples",
+			health:   database.HealthStats{Samples: 5, MeanLatency: 5 * time.Second},
+			group:    "analytics",
+			priority: constants.LoadShedPriorityLow,
+		},
+		{
+			name:     "Degraded sheds low priority",
+			health:   database.HealthStats{Samples: 100, MeanLatency: 300 * time.Millisecond},
+			group:    "analytics",
+			priority: constants.LoadShedPriorityLow,
+			shed:     true,
+		},
+		{
+			name:     "Degraded keeps normal priority",
+			health:   database.HealthStats{Samples: 100, MeanLatency: 300 * time.Millisecond},
+			group:    "settings",
+			priority: constants.LoadShedPriorityNormal,
+		},
+		{
+			name:     "Configured priority overrides default",
+			health:   database.HealthStats{Samples: 100, MeanLatency: 300 * time.Millisecond},
+			group:    "documents",
+			priority: constants.LoadShedPriorityNormal,
+			shed:     true,
+		},
+		{
+			name:     "Invalid configured priority is ignored",
+			health:   database.HealthStats{Samples: 100, MeanLatency: 300 * time.Millisecond},
+			group:    "reports",
+			priority: constants.LoadShedPriorityNormal,
+		},
+		{
+			name:     "Overloaded sheds normal priority",
+			health:   database.HealthStats{Samples: 100, MeanLatency: 2 * time.Second},
+			group:    "settings",
+			priority: constants.LoadShedPriorityNormal,
+			shed:     true,
+		},
+		{
+			name:     "Error rate sheds normal priority",
+			health:   database.HealthStats{Samples: 100, MeanLatency: 10 * time.Millisecond, ErrorRate: 0.5},
+			group:    "settings",
+			priority: constants.LoadShedPriorityNormal,
+			shed:     true,
+		},
+		{
+			name:     "Critical is never shed",
+			health:   database.HealthStats{Samples: 100, MeanLatency: 5 * time.Second, ErrorRate: 1},
+			group:    "auth",
+			priority: constants.LoadShedPriorityCritical,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			shedder := middleware.NewLoadShedder(&staticHealth{stats: tt.health}, loadSheddingSettings())
+			handler := &SecurityMockHandler{}
+
+			req := httptest.NewRequest("GET", "/api/test", nil)
+			rr := httptest.NewRecorder()
+			shedder.Shed(tt.group, tt.priority)(handler).ServeHTTP(rr, req)
+
+			if tt.shed {
+				assert.False(t, handler.Called, "Handler should not be called")
+				assert.Equal(t, http.StatusServiceUnavailable, rr.Code)
+				assert.Equal(t, "30", rr.Header().Get("Retry-After"))
+				assert.Contains(t, rr.Body.String(), constants.CodeServiceOverloaded)
+			} else {
+				assert.True(t, handler.Called, "Handler should be called")
+				assert.Equal(t, http.StatusOK, rr.Code)
+			}
+		})
+	}
+}
+
+func TestLoadShedder_Disabled(t *testing.T) {
+	settings := loadSheddingSettings()
+	settings.Enabled = false
+	overloaded := &staticHealth{stats: database.HealthStats{Samples: 100, MeanLatency: 5 * time.Second}}
+
+	for _, shedder := range []*middleware.LoadShedder{
+		middleware.NewLoadShedder(overloaded, settings),
+		middleware.NewLoadShedder(nil, loadSheddingSettings()),
+	} {
+		handler := &SecurityMockHandler{}
+		rr := httptest.NewRecorder()
+		shedder.Shed("analytics", constants.LoadShedPriorityLow)(handler).ServeHTTP(rr, httptest.NewRequest("GET", "/api/test", nil))
+
+		assert.True(t, handler.Called)
+		assert.Equal(t, http.StatusOK, rr.Code)
+	}
+}
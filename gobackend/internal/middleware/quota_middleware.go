@@ -0,0 +1,48 @@
+// Package middleware provides HTTP middleware components.
+package middleware
+
+import (
+	"context"
+	"net/http"
+	"strconv"
+
+	"github.com/rs/zerolog/log"
+
+	"github.com/yasinhessnawi1/Hideme_Backend/internal/auth"
+	"github.com/yasinhessnawi1/Hideme_Backend/internal/constants"
+	"github.com/yasinhessnawi1/Hideme_Backend/internal/models"
+)
+
+// QuotaReader defines methods required to read a user's detection quota.
+type QuotaReader interface {
+	GetQuota(ctx context.Context, userID int64) (*models.QuotaState, error)
+}
+
+// QuotaRemaining is middleware that tells clients of processing endpoints how much
+// detection quota they have left in the X-Quota-Remaining response header, so they
+// can slow down before requests start failing. Handlers that consume quota may
+// overwrite the header with the value after consuming.
+// It must be registered after the authentication middleware; when the quota cannot
+// be read, the header is left out rather than failing the request.
+//
+// Parameters:
+//   - quotas: The reader of the detection quota
+//
+// Returns:
+//   - A middleware function that can be used with an HTTP handler
+func QuotaRemaining(quotas QuotaReader) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if userID, ok := auth.GetUserID(r); ok {
+				quota, err := quotas.GetQuota(r.Context(), userID)
+				if err != nil {
+					log.Warn().Err(err).Int64("user_id", userID).Msg("Failed to read quota for response header")
+				} else {
+					w.Header().Set(constants.HeaderXQuotaRemaining, strconv.FormatInt(quota.Remaining, 10))
+				}
+			}
+
+			next.ServeHTTP(w, r)
+		})
+	}
+}
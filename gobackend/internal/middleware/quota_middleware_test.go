@@ -0,0 +1,58 @@
+package middleware_test
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/yasinhessnawi1/Hideme_Backend/internal/auth"
+	"github.com/yasinhessnawi1/Hideme_Backend/internal/constants"
+	"github.com/yasinhessnawi1/Hideme_Backend/internal/middleware"
+	"github.com/yasinhessnawi1/Hideme_Backend/internal/models"
+)
+
+// stubQuotaReader returns a fixed quota or error.
+type stubQuotaReader struct {
+	quota *models.QuotaState
+	err   error
+}
+
+func (s *stubQuotaReader) GetQuota(ctx context.Context, userID int64) (*models.QuotaState, error) {
+	return s.quota, s.err
+}
+
+func TestQuotaRemaining(t *testing.T) {
+	next := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	})
+
+	tests := []struct {
+		name     string
+		reader   *stubQuotaReader
+		userID   int64
+		expected string
+	}{
+		{"Authenticated user", &stubQuotaReader{quota: &models.QuotaState{Remaining: 42}}, 123, "42"},
+		{"Quota store unavailable", &stubQuotaReader{err: errors.New("redis down")}, 123, ""},
+		{"Unauthenticated request", &stubQuotaReader{quota: &models.QuotaState{Remaining: 42}}, 0, ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodPost, "/api/documents", nil)
+			if tt.userID != 0 {
+				req = req.WithContext(context.WithValue(req.Context(), auth.UserIDContextKey, tt.userID))
+			}
+			rr := httptest.NewRecorder()
+
+			middleware.QuotaRemaining(tt.reader)(next).ServeHTTP(rr, req)
+
+			assert.Equal(t, http.StatusOK, rr.Code)
+			assert.Equal(t, tt.expected, rr.Header().Get(constants.HeaderXQuotaRemaining))
+		})
+	}
+}
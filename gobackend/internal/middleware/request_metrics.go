@@ -0,0 +1,159 @@
+// Package middleware provides HTTP middleware components.
+package middleware
+
+import (
+	"net/http"
+	"sync"
+	"time"
+
+	chimiddleware "github.com/go-chi/chi/v5/middleware"
+
+	"github.com/yasinhessnawi1/Hideme_Backend/internal/constants"
+	"github.com/yasinhessnawi1/Hideme_Backend/internal/models"
+	"github.com/yasinhessnawi1/Hideme_Backend/internal/utils/clock"
+)
+
+// requestBucket holds the requests answered during one bucket of the window.
+type requestBucket struct {
+	start time.Time
+	stats models.RequestWindowStats
+}
+
+// RequestMetrics counts the answered API requests, their server errors and the requests
+// slower than the latency threshold over a rolling window, split into buckets that expire
+// one at a time. The service-level objectives are evaluated on them, so installations
+// without an external metrics system still get burn-rate alerts.
+// It is safe for concurrent use; a nil RequestMetrics records nothing.
+type RequestMetrics struct {
+	threshold time.Duration
+	bucket    time.Duration
+	buckets   []requestBucket
+	mu        sync.Mutex
+	clock     clock.Clock
+}
+
+// NewRequestMetrics creates a new RequestMetrics keeping constants.SLOMetricsWindow of
+// requests in buckets of constants.SLOMetricsBucket.
+//
+// Parameters:
+//   - latencyThreshold: The time within which a request counts as answered in time
+//
+// Returns:
+//   - A configured RequestMetrics
+func NewRequestMetrics(latencyThreshold time.Duration) *RequestMetrics {
+	return &RequestMetrics{
+		threshold: latencyThreshold,
+		bucket:    constants.SLOMetricsBucket,
+		buckets:   make([]requestBucket, int(constants.SLOMetricsWindow/constants.SLOMetricsBucket)),
+	}
+}
+
+// SetClock sets the clock requests are bucketed by, so tests can move time
+// past a bucket.
+func (m *RequestMetrics) SetClock(c clock.Clock) {
+	m.clock = c
+}
+
+// now returns the current time of the metrics' clock.
+func (m *RequestMetrics) now() time.Time {
+	return clock.Or(m.clock).Now()
+}
+
+// Observe is middleware that records each answered request. A request whose handler
+// panics is recorded as a server error before the panic continues to the recovery
+// middleware.
+//
+// Parameters:
+//   - timed: Whether the latency of the route counts towards the latency objective;
+//     false for streams and long-running routes
+//
+// Returns:
+//   - A middleware function that can be used with an HTTP handler
+func (m *RequestMetrics) Observe(timed bool) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		if m == nil {
+			return next
+		}
+
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			ww := chimiddleware.NewWrapResponseWriter(w, r.ProtoMajor)
+			start := time.Now()
+			defer func() {
+				if rvr := recover(); rvr != nil {
+					m.record(http.StatusInternalServerError, time.Since(start), timed)
+					panic(rvr)
+				}
+				m.record(ww.Status(), time.Since(start), timed)
+			}()
+
+			next.ServeHTTP(ww, r)
+		})
+	}
+}
+
+// record adds an answered request to the current bucket.
+//
+// Parameters:
+//   - status: The status code of the response; 0 if the handler wrote nothing
+//   - latency: The time the request took
+//   - timed: Whether the latency counts towards the latency objective
+func (m *RequestMetrics) record(status int, latency time.Duration, timed bool) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	start := m.now().Truncate(m.bucket)
+	b := &m.buckets[int(start.UnixNano()/int64(m.bucket))%len(m.buckets)]
+	if !b.start.Equal(start) {
+		*b = requestBucket{start: start}
+	}
+
+	b.stats.Requests++
+	if status >= http.StatusInternalServerError {
+		b.stats.Errors++
+	}
+	if timed {
+		b.stats.Timed++
+		if latency > m.threshold {
+			b.stats.Slow++
+		}
+	}
+}
+
+// Window returns the counts of the requests answered during the most recent window,
+// rounded to whole buckets and capped at constants.SLOMetricsWindow.
+//
+// Parameters:
+//   - window: The duration the counts cover
+//
+// Returns:
+//   - The counts of the requests in the window; zero for a nil RequestMetrics
+func (m *RequestMetrics) Window(window time.Duration) models.RequestWindowStats {
+	var stats models.RequestWindowStats
+	if m == nil {
+		return stats
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	count := int(window / m.bucket)
+	if count < 1 {
+		count = 1
+	}
+	if count > len(m.buckets) {
+		count = len(m.buckets)
+	}
+	oldest := m.now().Truncate(m.bucket).Add(-time.Duration(count-1) * m.bucket)
+
+	for _, b := range m.buckets {
+		if b.start.Before(oldest) {
+			continue
+		}
+		stats.Requests += b.stats.Requests
+		stats.Errors += b.stats.Errors
+		stats.Timed += b.stats.Timed
+		stats.Slow += b.stats.Slow
+	}
+
+	return stats
+}
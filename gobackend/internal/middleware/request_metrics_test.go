@@ -0,0 +1,58 @@
+package middleware_test
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/yasinhessnawi1/Hideme_Backend/internal/middleware"
+	"github.com/yasinhessnawi1/Hideme_Backend/internal/models"
+)
+
+func TestRequestMetrics_Observe(t *testing.T) {
+	metrics := middleware.NewRequestMetrics(time.Nanosecond)
+	status := func(code int) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			time.Sleep(time.Millisecond)
+			if code != 0 {
+				w.WriteHeader(code)
+			}
+		})
+	}
+
+	serve := func(handler http.Handler) {
+		handler.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/api/documents", nil))
+	}
+
+	serve(metrics.Observe(true)(status(http.StatusOK)))
+	serve(metrics.Observe(true)(status(0)))
+	serve(metrics.Observe(true)(status(http.StatusNotFound)))
+	serve(metrics.Observe(true)(status(http.StatusServiceUnavailable)))
+	// Streams count as requests, but not towards the latency objective
+	serve(metrics.Observe(false)(status(http.StatusInternalServerError)))
+
+	// A panicking handler is recorded as a server error and still panics
+	assert.Panics(t, func() {
+		serve(metrics.Observe(true)(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			panic("boom")
+		})))
+	})
+
+	assert.Equal(t, models.RequestWindowStats{Requests: 6, Errors: 3, Timed: 5, Slow: 5}, metrics.Window(time.Hour))
+}
+
+func TestRequestMetrics_Nil(t *testing.T) {
+	var metrics *middleware.RequestMetrics
+
+	handler := metrics.Observe(true)(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusTeapot)
+	}))
+	rr := httptest.NewRecorder()
+	handler.ServeHTTP(rr, httptest.NewRequest(http.MethodGet, "/api/documents", nil))
+
+	assert.Equal(t, http.StatusTeapot, rr.Code)
+	assert.Equal(t, models.RequestWindowStats{}, metrics.Window(time.Hour))
+}
@@ -0,0 +1,96 @@
+// Package middleware provides HTTP middleware components.
+package middleware
+
+import (
+	"net/http"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+	chimiddleware "github.com/go-chi/chi/v5/middleware"
+	"github.com/rs/zerolog/log"
+
+	"github.com/yasinhessnawi1/Hideme_Backend/internal/auth"
+	"github.com/yasinhessnawi1/Hideme_Backend/internal/constants"
+)
+
+// WriteTimeout is middleware that gives a route longer than the server's write timeout to
+// write its response, for routes such as server-side redaction that take long to respond.
+// Writers that cannot change their deadline keep the server's write timeout.
+//
+// Parameters:
+//   - timeout: The time from the start of the request the response may take to write
+//
+// Returns:
+//   - A middleware function that can be used with an HTTP handler
+func WriteTimeout(timeout time.Duration) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if err := http.NewResponseController(w).SetWriteDeadline(time.Now().Add(timeout)); err != nil {
+				log.Debug().Err(err).Str("path", r.URL.Path).Msg("Failed to extend the write deadline")
+			}
+
+			next.ServeHTTP(w, r)
+		})
+	}
+}
+
+// Audit is middleware that logs every request to a route in an audit category, with the
+// user who sent it and the status it was answered with. It must be applied after the
+// authentication middleware, since it reads the user ID from the request context.
+//
+// Parameters:
+//   - category: The log category of the entries, e.g. constants.LogCategoryAdmin
+//
+// Returns:
+//   - A middleware function that can be used with an HTTP handler
+func Audit(category string) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			ww := chimiddleware.NewWrapResponseWriter(w, r.ProtoMajor)
+			start := time.Now()
+			next.ServeHTTP(ww, r)
+
+			route := r.URL.Path
+			if rctx := chi.RouteContext(r.Context()); rctx != nil && rctx.RoutePattern() != "" {
+				route = rctx.RoutePattern()
+			}
+			userID, _ := auth.GetUserID(r)
+
+			log.Info().
+				Int64("user_id", userID).
+				Str("method", r.Method).
+				Str("route", route).
+				Str("path", r.URL.Path).
+				Int("status", ww.Status()).
+				Dur("duration", time.Since(start)).
+				Str("request_id", chimiddleware.GetReqID(r.Context())).
+				Str("category", category).
+				Msg("Audited request")
+		})
+	}
+}
+
+// Deprecated is middleware that marks the responses of a deprecated route, so clients
+// learn when it goes away and what replaces it.
+//
+// Parameters:
+//   - sunset: The date after which the route may be removed; zero if not decided
+//   - successor: The path of the route replacing it; empty if there is none
+//
+// Returns:
+//   - A middleware function that can be used with an HTTP handler
+func Deprecated(sunset time.Time, successor string) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.Header().Set(constants.HeaderDeprecation, "true")
+			if !sunset.IsZero() {
+				w.Header().Set(constants.HeaderSunset, sunset.UTC().Format(http.TimeFormat))
+			}
+			if successor != "" {
+				w.Header().Add(constants.HeaderLink, "<"+successor+`>; rel="successor-version"`)
+			}
+
+			next.ServeHTTP(w, r)
+		})
+	}
+}
@@ -0,0 +1,62 @@
+package middleware_test
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/yasinhessnawi1/Hideme_Backend/internal/constants"
+	"github.com/yasinhessnawi1/Hideme_Backend/internal/middleware"
+)
+
+func TestWriteTimeout(t *testing.T) {
+	// httptest.ResponseRecorder cannot change its deadline; the request is served anyway
+	called := false
+	handler := middleware.WriteTimeout(time.Minute)(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		called = true
+		w.WriteHeader(http.StatusNoContent)
+	}))
+
+	rr := httptest.NewRecorder()
+	handler.ServeHTTP(rr, httptest.NewRequest(http.MethodGet, "/", nil))
+
+	assert.True(t, called)
+	assert.Equal(t, http.StatusNoContent, rr.Code)
+}
+
+func TestAudit(t *testing.T) {
+	handler := middleware.Audit(constants.LogCategoryAdmin)(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusForbidden)
+	}))
+
+	rr := httptest.NewRecorder()
+	handler.ServeHTTP(rr, httptest.NewRequest(http.MethodPost, "/api/admin/users/1/disable", nil))
+
+	assert.Equal(t, http.StatusForbidden, rr.Code)
+}
+
+func TestDeprecated(t *testing.T) {
+	next := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {})
+
+	t.Run("Sunset and successor", func(t *testing.T) {
+		sunset := time.Date(2027, time.March, 1, 0, 0, 0, 0, time.UTC)
+		rr := httptest.NewRecorder()
+		middleware.Deprecated(sunset, "/api/v2/documents")(next).ServeHTTP(rr, httptest.NewRequest(http.MethodGet, "/", nil))
+
+		assert.Equal(t, "true", rr.Header().Get(constants.HeaderDeprecation))
+		assert.Equal(t, "Mon, 01 Mar 2027 00:00:00 GMT", rr.Header().Get(constants.HeaderSunset))
+		assert.Equal(t, `</api/v2/documents>; rel="successor-version"`, rr.Header().Get(constants.HeaderLink))
+	})
+
+	t.Run("Undecided", func(t *testing.T) {
+		rr := httptest.NewRecorder()
+		middleware.Deprecated(time.Time{}, "")(next).ServeHTTP(rr, httptest.NewRequest(http.MethodGet, "/", nil))
+
+		assert.Equal(t, "true", rr.Header().Get(constants.HeaderDeprecation))
+		assert.Empty(t, rr.Header().Get(constants.HeaderSunset))
+		assert.Empty(t, rr.Header().Get(constants.HeaderLink))
+	})
+}
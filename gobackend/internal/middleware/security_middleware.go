@@ -6,12 +6,15 @@ import (
 	"fmt"
 	"net"
 	"net/http"
+	"strconv"
 	"strings"
 	"sync"
 	"time"
 
 	"github.com/rs/zerolog/log"
 
+	"github.com/yasinhessnawi1/Hideme_Backend/internal/auth"
+	"github.com/yasinhessnawi1/Hideme_Backend/internal/constants"
 	"github.com/yasinhessnawi1/Hideme_Backend/internal/models"
 	"github.com/yasinhessnawi1/Hideme_Backend/internal/utils"
 )
@@ -23,8 +26,22 @@ type SecurityService interface {
 	BanIP(ctx context.Context, ipAddress string, reason string, duration time.Duration, bannedBy string) (*models.IPBan, error)
 }
 
+// RateLimitRetryAfter is implemented by security services that know when a rate limited
+// client may send its next request.
+type RateLimitRetryAfter interface {
+	// RetryAfter returns how long a client must still wait in a category; zero if unknown.
+	RetryAfter(clientID, category string) time.Duration
+}
+
 // RateLimit is middleware that limits the rate of requests from clients.
 // It uses the SecurityService to check if a client has exceeded their rate limit.
+// Every client IP address has a bucket in the category; once a user is authenticated,
+// the user also has a bucket in the category with RateLimitUserGroupSuffix, so users
+// cannot get around their limit by switching addresses. Apply it after the authentication
+// middleware of a route group to limit users.
+//
+// Limited clients are answered with 429 Too Many Requests and a Retry-After header, taken
+// from the security service if it implements RateLimitRetryAfter.
 //
 // Parameters:
 //   - securityService: The security service that implements rate limiting
@@ -33,6 +50,8 @@ type SecurityService interface {
 // Returns:
 //   - A middleware function that can be used with an HTTP handler
 func RateLimit(securityService SecurityService, category string) func(http.Handler) http.Handler {
+	retryAfter, _ := securityService.(RateLimitRetryAfter)
+	userCategory := category + constants.RateLimitUserGroupSuffix
 	return func(next http.Handler) http.Handler {
 		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 			// Get the client IP address, handling proxies
@@ -53,18 +72,47 @@ func RateLimit(securityService SecurityService, category string) func(http.Handl
 					Str("category", category).
 					Msg("Rate limit exceeded")
 
-				// Return 429 Too Many Requests
-				w.Header().Set("Retry-After", "60")
-				utils.Error(w, http.StatusTooManyRequests, "too_many_requests", "Rate limit exceeded. Please try again later.", nil)
+				writeRateLimited(w, retryAfter, clientIP, category)
 				return
 			}
 
+			// Then check the user's own bucket
+			if userID, ok := auth.GetUserID(r); ok {
+				clientID := constants.RateLimitUserClientPrefix + strconv.FormatInt(userID, 10)
+				if securityService.IsRateLimited(clientID, userCategory) {
+					log.Warn().
+						Int64("user_id", userID).
+						Str("path", r.URL.Path).
+						Str("method", r.Method).
+						Str("category", category).
+						Msg("User rate limit exceeded")
+
+					writeRateLimited(w, retryAfter, clientID, userCategory)
+					return
+				}
+			}
+
 			// Request is allowed, continue to next handler
 			next.ServeHTTP(w, r)
 		})
 	}
 }
 
+// writeRateLimited answers a client that exceeded its rate limit in a category.
+func writeRateLimited(w http.ResponseWriter, retryAfter RateLimitRetryAfter, clientID, category string) {
+	wait := constants.RateLimitRetryAfter
+	if retryAfter != nil {
+		if d := retryAfter.RetryAfter(clientID, category); d > 0 {
+			wait = d
+		}
+	}
+
+	// Round up so clients never retry before a token is available
+	seconds := int((wait + time.Second - 1) / time.Second)
+	w.Header().Set(constants.HeaderRetryAfter, strconv.Itoa(seconds))
+	utils.Error(w, http.StatusTooManyRequests, "too_many_requests", "Rate limit exceeded. Please try again later.", nil)
+}
+
 // IPBanCheck is middleware that blocks requests from banned IP addresses.
 // It uses the SecurityService to check if an IP is banned.
 //
@@ -217,31 +265,29 @@ func AutoBan(securityService SecurityService, threshold int, window, banDuration
 	}
 }
 
-// getClientIP extracts the client IP address from the request,
-// taking into account common proxy headers.
-func getClientIP(r *http.Request) string {
-	// Check for X-Forwarded-For header
-	xForwardedFor := r.Header.Get("X-Forwarded-For")
-	if xForwardedFor != "" {
-		// Use the leftmost IP in the list (client IP)
-		ips := strings.Split(xForwardedFor, ",")
-		ip := strings.TrimSpace(ips[0])
-		return ip
-	}
-
-	// Check for X-Real-IP header
-	xRealIP := r.Header.Get("X-Real-IP")
-	if xRealIP != "" {
-		return xRealIP
+// ClientIP replaces the remote address of each request with the address of the client when
+// the request came through trusted reverse proxies, so rate limits, bans, risk scores and
+// access logs see the client rather than the proxy. Proxy headers of other senders are ignored.
+//
+// Parameters:
+//   - trustedProxies: The networks of the reverse proxies in front of the server
+//
+// Returns:
+//   - A middleware function that can be used with an HTTP handler
+func ClientIP(trustedProxies []*net.IPNet) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if len(trustedProxies) > 0 {
+				r.RemoteAddr = utils.ResolveClientIP(r, trustedProxies)
+			}
+			next.ServeHTTP(w, r)
+		})
 	}
+}
 
-	// Fall back to RemoteAddr
-	ip, _, err := net.SplitHostPort(r.RemoteAddr)
-	if err != nil {
-		// If there's no port in the address, use it as is
-		return r.RemoteAddr
-	}
-	return ip
+// getClientIP extracts the client IP address from the request, as set by ClientIP.
+func getClientIP(r *http.Request) string {
+	return utils.GetClientIP(r)
 }
 
 // isExemptedPath returns true if the path should be exempted from
@@ -11,8 +11,10 @@ import (
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/mock"
 
+	"github.com/yasinhessnawi1/Hideme_Backend/internal/auth"
 	"github.com/yasinhessnawi1/Hideme_Backend/internal/middleware"
 	"github.com/yasinhessnawi1/Hideme_Backend/internal/models"
+	"github.com/yasinhessnawi1/Hideme_Backend/internal/utils"
 )
 
 // MockSecurityService implements the middleware.SecurityService interface
@@ -160,6 +162,73 @@ func TestSecurityRateLimit(t *testing.T) {
 	}
 }
 
+// RetryingSecurityService also tells rate limited clients when to retry
+type RetryingSecurityService struct {
+	MockSecurityService
+}
+
+// RetryAfter mocks the RetryAfter method
+func (m *RetryingSecurityService) RetryAfter(clientID, category string) time.Duration {
+	args := m.Called(clientID, category)
+	return args.Get(0).(time.Duration)
+}
+
+func TestSecurityRateLimit_PerUser(t *testing.T) {
+	t.Run("User limit exceeded", func(t *testing.T) {
+		mockService := new(RetryingSecurityService)
+		mockService.On("IsRateLimited", "192.168.1.1", "api").Return(false)
+		mockService.On("IsRateLimited", "user:7", "api:user").Return(true)
+		mockService.On("RetryAfter", "user:7", "api:user").Return(1500 * time.Millisecond)
+		mockHandler := &SecurityMockHandler{}
+
+		req := httptest.NewRequest("GET", "/api/test", nil)
+		req.RemoteAddr = "192.168.1.1:12345"
+		req = req.WithContext(context.WithValue(req.Context(), auth.UserIDContextKey, int64(7)))
+		rr := httptest.NewRecorder()
+
+		middleware.RateLimit(mockService, "api")(mockHandler).ServeHTTP(rr, req)
+
+		assert.Equal(t, http.StatusTooManyRequests, rr.Code)
+		assert.Equal(t, "2", rr.Header().Get("Retry-After"))
+		assert.False(t, mockHandler.Called)
+		mockService.AssertExpectations(t)
+	})
+
+	t.Run("IP limit exceeded without a known wait", func(t *testing.T) {
+		mockService := new(MockSecurityService)
+		mockService.On("IsRateLimited", "192.168.1.2", "auth").Return(true)
+		mockHandler := &SecurityMockHandler{}
+
+		req := httptest.NewRequest("POST", "/api/auth/login", nil)
+		req.RemoteAddr = "192.168.1.2:12345"
+		rr := httptest.NewRecorder()
+
+		middleware.RateLimit(mockService, "auth")(mockHandler).ServeHTTP(rr, req)
+
+		assert.Equal(t, http.StatusTooManyRequests, rr.Code)
+		assert.Equal(t, "60", rr.Header().Get("Retry-After"))
+		mockService.AssertExpectations(t)
+	})
+
+	t.Run("Both buckets allow the request", func(t *testing.T) {
+		mockService := new(MockSecurityService)
+		mockService.On("IsRateLimited", "192.168.1.3", "documents").Return(false)
+		mockService.On("IsRateLimited", "user:8", "documents:user").Return(false)
+		mockHandler := &SecurityMockHandler{}
+
+		req := httptest.NewRequest("GET", "/api/documents", nil)
+		req.RemoteAddr = "192.168.1.3:12345"
+		req = req.WithContext(context.WithValue(req.Context(), auth.UserIDContextKey, int64(8)))
+		rr := httptest.NewRecorder()
+
+		middleware.RateLimit(mockService, "documents")(mockHandler).ServeHTTP(rr, req)
+
+		assert.Equal(t, http.StatusOK, rr.Code)
+		assert.True(t, mockHandler.Called)
+		mockService.AssertExpectations(t)
+	})
+}
+
 func TestIPBanCheck(t *testing.T) {
 	tests := []struct {
 		name           string
@@ -394,3 +463,27 @@ func TestRequestHeaderProcessing(t *testing.T) {
 		})
 	}
 }
+
+func TestClientIP(t *testing.T) {
+	trusted, err := utils.ParseTrustedProxies([]string{"10.0.0.0/8"})
+	assert.NoError(t, err)
+
+	var clientIP string
+	handler := middleware.ClientIP(trusted)(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		clientIP = utils.GetClientIP(r)
+	}))
+
+	// Behind a trusted proxy the client is the hop the proxy added
+	req := httptest.NewRequest(http.MethodGet, "/api/test", nil)
+	req.RemoteAddr = "10.0.0.2:1234"
+	req.Header.Set("X-Forwarded-For", "203.0.113.7, 198.51.100.4")
+	handler.ServeHTTP(httptest.NewRecorder(), req)
+	assert.Equal(t, "198.51.100.4", clientIP)
+
+	// A client connecting directly can't choose the address it is known by
+	req = httptest.NewRequest(http.MethodGet, "/api/test", nil)
+	req.RemoteAddr = "198.51.100.4:1234"
+	req.Header.Set("X-Forwarded-For", "203.0.113.7")
+	handler.ServeHTTP(httptest.NewRecorder(), req)
+	assert.Equal(t, "198.51.100.4", clientIP)
+}
@@ -0,0 +1,310 @@
+// Package middleware provides HTTP middleware components.
+package middleware
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"math/rand/v2"
+	"net/http"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+	chimiddleware "github.com/go-chi/chi/v5/middleware"
+	"github.com/rs/zerolog/log"
+
+	"github.com/yasinhessnawi1/Hideme_Backend/internal/config"
+	"github.com/yasinhessnawi1/Hideme_Backend/internal/constants"
+	"github.com/yasinhessnawi1/Hideme_Backend/internal/models"
+)
+
+// shadowStrippedHeaders are never sent to the shadow deployment: hop-by-hop headers,
+// cookies and the client address headers of this server's proxy.
+var shadowStrippedHeaders = []string{
+	"Connection", "Keep-Alive", "Proxy-Authorization", "Proxy-Connection", "TE", "Trailer",
+	"Transfer-Encoding", "Upgrade", constants.HeaderCookie, "X-Forwarded-For", "X-Real-IP",
+}
+
+// ShadowMirror mirrors a share of the read-only requests to a shadow deployment, such as
+// a release candidate, and compares its response codes and latency with this server's.
+// Mirrored requests run in the background after the client got its response, so the
+// shadow never slows down or changes what the client sees. The divergences are counted
+// in memory and reported by Stats.
+type ShadowMirror struct {
+	settings *config.ShadowTrafficSettings
+	target   string
+	client   *http.Client
+	inFlight chan struct{}
+	sample   func() float64
+
+	mu                 sync.Mutex
+	mirrored           int64
+	dropped            int64
+	failed             int64
+	statusMismatches   int64
+	latencyRegressions int64
+	primaryLatency     time.Duration
+	shadowLatency      time.Duration
+	routes             map[string]*models.ShadowRouteStats
+}
+
+// NewShadowMirror creates a new ShadowMirror.
+//
+// Parameters:
+//   - settings: The shadow deployment and the share of requests to mirror
+//
+// Returns:
+//   - A configured ShadowMirror, or nil if no shadow deployment is configured
+func NewShadowMirror(settings *config.ShadowTrafficSettings) *ShadowMirror {
+	if settings.TargetURL == "" {
+		return nil
+	}
+
+	return &ShadowMirror{
+		settings: settings,
+		target:   strings.TrimSuffix(settings.TargetURL, "/"),
+		client: &http.Client{
+			Timeout: settings.Timeout,
+			// Compare the shadow's own answer, not the page it redirects to
+			CheckRedirect: func(*http.Request, []*http.Request) error {
+				return http.ErrUseLastResponse
+			},
+		},
+		inFlight: make(chan struct{}, settings.MaxInFlight),
+		sample:   rand.Float64,
+		routes:   make(map[string]*models.ShadowRouteStats),
+	}
+}
+
+// Mirror is middleware that serves each request as usual and, for a sampled share of the
+// GET and HEAD requests, sends a copy to the shadow deployment afterwards.
+// A nil ShadowMirror passes all requests through untouched.
+//
+// Returns:
+//   - A middleware function that can be used with an HTTP handler
+func (m *ShadowMirror) Mirror() func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		if m == nil {
+			return next
+		}
+
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if !m.shouldMirror(r) {
+				next.ServeHTTP(w, r)
+				return
+			}
+
+			// Copy the request before the handler sees it; handlers may change headers
+			shadowReq, err := m.newShadowRequest(r)
+			if err != nil {
+				log.Debug().Err(err).Str("path", r.URL.Path).Msg("Failed to copy request for the shadow deployment")
+				next.ServeHTTP(w, r)
+				return
+			}
+
+			ww := chimiddleware.NewWrapResponseWriter(w, r.ProtoMajor)
+			start := time.Now()
+			next.ServeHTTP(ww, r)
+			primaryLatency := time.Since(start)
+
+			route := r.Method + " " + r.URL.Path
+			if rctx := chi.RouteContext(r.Context()); rctx != nil && rctx.RoutePattern() != "" {
+				route = r.Method + " " + rctx.RoutePattern()
+			}
+
+			// Drop instead of queueing, so a slow shadow cannot pile up goroutines
+			select {
+			case m.inFlight <- struct{}{}:
+			default:
+				m.mu.Lock()
+				m.dropped++
+				m.mu.Unlock()
+				return
+			}
+
+			go func() {
+				defer func() { <-m.inFlight }()
+				m.compare(shadowReq, route, ww.Status(), primaryLatency)
+			}()
+		})
+	}
+}
+
+// shouldMirror decides whether a request is mirrored: only sampled read-only requests
+// outside the excluded paths are.
+//
+// Parameters:
+//   - r: The HTTP request
+//
+// Returns:
+//   - Whether the request is mirrored
+func (m *ShadowMirror) shouldMirror(r *http.Request) bool {
+	if r.Method != http.MethodGet && r.Method != http.MethodHead {
+		return false
+	}
+	// Requests from a shadow are never mirrored again, should the shadow point back here
+	if r.Header.Get(constants.HeaderXShadowRequest) != "" {
+		return false
+	}
+	if strings.HasPrefix(r.URL.Path, constants.ShadowExcludedPathPrefix) || r.URL.Path == constants.ShadowExcludedStreamPath {
+		return false
+	}
+	for _, prefix := range m.settings.ExcludedPaths {
+		if prefix = strings.TrimSpace(prefix); prefix != "" && strings.HasPrefix(r.URL.Path, prefix) {
+			return false
+		}
+	}
+	return m.sample()*100 < m.settings.Percent
+}
+
+// newShadowRequest copies a request for the shadow deployment, leaving out cookies,
+// hop-by-hop headers and, unless configured otherwise, the credentials.
+//
+// Parameters:
+//   - r: The HTTP request served by this server
+//
+// Returns:
+//   - The request to send to the shadow deployment
+//   - An error if the request cannot be created
+func (m *ShadowMirror) newShadowRequest(r *http.Request) (*http.Request, error) {
+	// The shadow request outlives the client's request, so it gets its own context
+	req, err := http.NewRequestWithContext(context.Background(), r.Method, m.target+r.URL.RequestURI(), nil)
+	if err != nil {
+		return nil, err
+	}
+
+	req.Header = r.Header.Clone()
+	for _, header := range shadowStrippedHeaders {
+		req.Header.Del(header)
+	}
+	if !m.settings.ForwardCredentials {
+		req.Header.Del(constants.HeaderAuthorization)
+		req.Header.Del(constants.HeaderXAPIKey)
+	}
+	req.Header.Set(constants.HeaderXShadowRequest, "1")
+
+	return req, nil
+}
+
+// compare sends a request to the shadow deployment and records how its answer diverged
+// from this server's.
+//
+// Parameters:
+//   - req: The request for the shadow deployment
+//   - route: The method and route pattern of the request
+//   - primaryStatus: The status code this server answered with
+//   - primaryLatency: The time this server took to answer
+func (m *ShadowMirror) compare(req *http.Request, route string, primaryStatus int, primaryLatency time.Duration) {
+	if primaryStatus == 0 {
+		primaryStatus = http.StatusOK
+	}
+
+	start := time.Now()
+	resp, err := m.client.Do(req)
+	var shadowLatency time.Duration
+	if err == nil {
+		// Read the whole body, so the latency covers what the client would have waited for
+		_, _ = io.Copy(io.Discard, resp.Body)
+		resp.Body.Close()
+		shadowLatency = time.Since(start)
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	routeStats := m.route(route)
+	if err != nil {
+		m.failed++
+		routeStats.Failed++
+		log.Debug().Err(err).Str("route", route).Msg("Shadow request failed")
+		return
+	}
+
+	m.mirrored++
+	routeStats.Mirrored++
+	m.primaryLatency += primaryLatency
+	m.shadowLatency += shadowLatency
+
+	if resp.StatusCode != primaryStatus {
+		m.statusMismatches++
+		routeStats.StatusMismatches++
+		routeStats.LastMismatch = fmt.Sprintf("%d != %d", primaryStatus, resp.StatusCode)
+		log.Warn().
+			Str("route", route).
+			Int("primary_status", primaryStatus).
+			Int("shadow_status", resp.StatusCode).
+			Msg("Shadow response status diverged")
+	}
+	if shadowLatency > primaryLatency+m.settings.LatencyTolerance {
+		m.latencyRegressions++
+		routeStats.LatencyRegressions++
+	}
+}
+
+// route returns the statistics of a route, creating them if needed. The number of routes
+// is bounded, since unmatched paths would otherwise each get their own entry.
+// The caller must hold m.mu.
+//
+// Parameters:
+//   - route: The method and route pattern
+//
+// Returns:
+//   - The statistics of the route
+func (m *ShadowMirror) route(route string) *models.ShadowRouteStats {
+	if stats, ok := m.routes[route]; ok {
+		return stats
+	}
+	if len(m.routes) >= constants.ShadowMaxRoutes {
+		route = constants.ShadowOtherRoute
+		if stats, ok := m.routes[route]; ok {
+			return stats
+		}
+	}
+
+	stats := &models.ShadowRouteStats{Route: route}
+	m.routes[route] = stats
+	return stats
+}
+
+// Stats returns the requests mirrored since startup and their divergences.
+//
+// Returns:
+//   - The shadow traffic statistics, the most divergent route first
+func (m *ShadowMirror) Stats() models.ShadowTrafficStats {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	stats := models.ShadowTrafficStats{
+		Target:             m.target,
+		Percent:            m.settings.Percent,
+		Mirrored:           m.mirrored,
+		Dropped:            m.dropped,
+		Failed:             m.failed,
+		StatusMismatches:   m.statusMismatches,
+		LatencyRegressions: m.latencyRegressions,
+		Routes:             make([]models.ShadowRouteStats, 0, len(m.routes)),
+	}
+	if m.mirrored > 0 {
+		stats.PrimaryMeanLatencyMs = float64(m.primaryLatency.Microseconds()) / float64(m.mirrored) / 1000
+		stats.ShadowMeanLatencyMs = float64(m.shadowLatency.Microseconds()) / float64(m.mirrored) / 1000
+	}
+
+	for _, route := range m.routes {
+		stats.Routes = append(stats.Routes, *route)
+	}
+	sort.Slice(stats.Routes, func(i, j int) bool {
+		a, b := stats.Routes[i], stats.Routes[j]
+		if a.StatusMismatches != b.StatusMismatches {
+			return a.StatusMismatches > b.StatusMismatches
+		}
+		if a.LatencyRegressions+a.Failed != b.LatencyRegressions+b.Failed {
+			return a.LatencyRegressions+a.Failed > b.LatencyRegressions+b.Failed
+		}
+		return a.Route < b.Route
+	})
+
+	return stats
+}
@@ -0,0 +1,132 @@
+package middleware_test
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/yasinhessnawi1/Hideme_Backend/internal/config"
+	"github.com/yasinhessnawi1/Hideme_Backend/internal/constants"
+	"github.com/yasinhessnawi1/Hideme_Backend/internal/middleware"
+	"github.com/yasinhessnawi1/Hideme_Backend/internal/models"
+)
+
+func TestNewShadowMirror_Disabled(t *testing.T) {
+	mirror := middleware.NewShadowMirror(&config.ShadowTrafficSettings{})
+	assert.Nil(t, mirror)
+
+	// A disabled mirror passes requests through
+	handler := mirror.Mirror()(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusTeapot)
+	}))
+	rr := httptest.NewRecorder()
+	handler.ServeHTTP(rr, httptest.NewRequest(http.MethodGet, "/api/documents", nil))
+	assert.Equal(t, http.StatusTeapot, rr.Code)
+}
+
+func TestShadowMirror_Mirror(t *testing.T) {
+	var mu sync.Mutex
+	var shadowHeaders []http.Header
+	shadow := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		shadowHeaders = append(shadowHeaders, r.Header.Clone())
+		mu.Unlock()
+		// The release candidate broke one route
+		if r.URL.Path == "/api/documents/7" {
+			w.WriteHeader(http.StatusInternalServerError)
+			return
+		}
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer shadow.Close()
+
+	mirror := middleware.NewShadowMirror(&config.ShadowTrafficSettings{
+		TargetURL:        shadow.URL + "/",
+		Percent:          100,
+		Timeout:          time.Second,
+		LatencyTolerance: time.Second,
+		MaxInFlight:      10,
+		ExcludedPaths:    []string{"/api/admin"},
+	})
+	require.NotNil(t, mirror)
+
+	r := chi.NewRouter()
+	r.Use(mirror.Mirror())
+	ok := func(w http.ResponseWriter, r *http.Request) { w.WriteHeader(http.StatusOK) }
+	r.Get("/api/documents/{id}", ok)
+	r.Post("/api/documents", ok)
+	r.Get("/api/auth/oauth/{provider}/callback", ok)
+	r.Get("/api/admin/users", ok)
+	r.Get("/api/events", ok)
+
+	send := func(method, path string) int {
+		req := httptest.NewRequest(method, path, nil)
+		req.Header.Set(constants.HeaderAuthorization, "Bearer token")
+		req.Header.Set(constants.HeaderCookie, "refresh_token=secret")
+		req.Header.Set(constants.HeaderAccept, constants.ContentTypeJSON)
+		rr := httptest.NewRecorder()
+		r.ServeHTTP(rr, req)
+		return rr.Code
+	}
+
+	// Clients get this server's answer whatever the shadow says
+	assert.Equal(t, http.StatusOK, send(http.MethodGet, "/api/documents/7"))
+	assert.Equal(t, http.StatusOK, send(http.MethodGet, "/api/documents/8"))
+	// Writes, authentication, event streams and excluded paths are not mirrored
+	send(http.MethodPost, "/api/documents")
+	send(http.MethodGet, "/api/auth/oauth/google/callback?code=once")
+	send(http.MethodGet, "/api/admin/users")
+	send(http.MethodGet, "/api/events")
+
+	var stats models.ShadowTrafficStats
+	require.Eventually(t, func() bool {
+		stats = mirror.Stats()
+		return stats.Mirrored == 2
+	}, 2*time.Second, 10*time.Millisecond)
+
+	assert.Equal(t, int64(1), stats.StatusMismatches)
+	require.Len(t, stats.Routes, 1)
+	assert.Equal(t, "GET /api/documents/{id}", stats.Routes[0].Route)
+	assert.Equal(t, "200 != 500", stats.Routes[0].LastMismatch)
+
+	// Credentials and cookies stay on this server
+	mu.Lock()
+	defer mu.Unlock()
+	require.Len(t, shadowHeaders, 2)
+	for _, header := range shadowHeaders {
+		assert.Empty(t, header.Get(constants.HeaderAuthorization))
+		assert.Empty(t, header.Get(constants.HeaderCookie))
+		assert.Equal(t, constants.ContentTypeJSON, header.Get(constants.HeaderAccept))
+		assert.Equal(t, "1", header.Get(constants.HeaderXShadowRequest))
+	}
+}
+
+func TestShadowMirror_UnreachableShadow(t *testing.T) {
+	shadow := httptest.NewServer(http.NotFoundHandler())
+	shadow.Close()
+
+	mirror := middleware.NewShadowMirror(&config.ShadowTrafficSettings{
+		TargetURL:   shadow.URL,
+		Percent:     100,
+		Timeout:     time.Second,
+		MaxInFlight: 10,
+	})
+	handler := mirror.Mirror()(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	}))
+
+	rr := httptest.NewRecorder()
+	handler.ServeHTTP(rr, httptest.NewRequest(http.MethodGet, "/health", nil))
+	assert.Equal(t, http.StatusOK, rr.Code)
+
+	assert.Eventually(t, func() bool {
+		stats := mirror.Stats()
+		return stats.Failed == 1 && stats.Mirrored == 0
+	}, 2*time.Second, 10*time.Millisecond)
+}
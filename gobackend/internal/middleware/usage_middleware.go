@@ -0,0 +1,34 @@
+// Package middleware provides HTTP middleware components.
+package middleware
+
+import (
+	"net/http"
+
+	"github.com/yasinhessnawi1/Hideme_Backend/internal/auth"
+)
+
+// UsageTracker defines methods required to count tenant API usage.
+type UsageTracker interface {
+	RecordAPICall(userID int64)
+}
+
+// TrackUsage is middleware that counts authenticated API calls per tenant for billing.
+// It must be registered after the authentication middleware, since it reads the
+// user ID from the request context; unauthenticated requests are not counted.
+//
+// Parameters:
+//   - tracker: The usage tracker that records API calls
+//
+// Returns:
+//   - A middleware function that can be used with an HTTP handler
+func TrackUsage(tracker UsageTracker) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if userID, ok := auth.GetUserID(r); ok {
+				tracker.RecordAPICall(userID)
+			}
+
+			next.ServeHTTP(w, r)
+		})
+	}
+}
@@ -0,0 +1,79 @@
+// Package models provides data structures and operations for the HideMe application.
+// This file contains the scheduled erasures of user accounts, which delete an account and
+// everything kept about it in the background once a grace period has passed.
+package models
+
+import (
+	"time"
+
+	"github.com/yasinhessnawi1/Hideme_Backend/internal/constants"
+)
+
+// AccountErasure is the scheduled erasure of a user account (GDPR Article 17). Each step of
+// the erasure can be repeated, so a failed attempt is retried from the start.
+type AccountErasure struct {
+	// ID is the unique identifier for this erasure
+	ID int64 `json:"id" db:"erasure_id"`
+
+	// UserID references the user whose account is erased; it is kept after the account is gone
+	UserID int64 `json:"-" db:"user_id"`
+
+	// Status is scheduled, running, completed or cancelled
+	Status string `json:"status" db:"status"`
+
+	// ScheduledFor is when the grace period ends, or when a failed erasure is attempted again
+	ScheduledFor time.Time `json:"scheduled_for" db:"scheduled_for"`
+
+	// Attempts is the number of times the erasure was started
+	Attempts int `json:"attempts" db:"attempts"`
+
+	// LastError describes why the last attempt failed; empty otherwise
+	LastError string `json:"-" db:"last_error"`
+
+	// LeaseUntil is when another server may take over a running erasure
+	LeaseUntil *time.Time `json:"-" db:"lease_until"`
+
+	// CreatedAt records when the erasure was requested
+	CreatedAt time.Time `json:"created_at" db:"created_at"`
+
+	// UpdatedAt records when the erasure last changed
+	UpdatedAt time.Time `json:"updated_at" db:"updated_at"`
+
+	// CompletedAt records when the account was erased or the erasure cancelled; nil until then
+	CompletedAt *time.Time `json:"completed_at,omitempty" db:"completed_at"`
+}
+
+// NewAccountErasure creates an erasure of a user account that runs once the grace period has passed.
+//
+// Parameters:
+//   - userID: The ID of the user whose account is erased
+//   - now: The time the erasure is requested
+//
+// Returns:
+//   - The scheduled erasure
+func NewAccountErasure(userID int64, now time.Time) *AccountErasure {
+	return &AccountErasure{
+		UserID:       userID,
+		Status:       constants.AccountErasureScheduled,
+		ScheduledFor: now.Add(constants.AccountErasureGracePeriod),
+		CreatedAt:    now,
+		UpdatedAt:    now,
+	}
+}
+
+// Cancellable reports whether the user can still cancel the erasure.
+//
+// Returns:
+//   - true while the erasure waits for its grace period to pass
+func (e *AccountErasure) Cancellable() bool {
+	return e.Status == constants.AccountErasureScheduled && e.Attempts == 0
+}
+
+// AccountErasureRequest confirms the erasure of the caller's account.
+type AccountErasureRequest struct {
+	// Password is the current password of the user
+	Password string `json:"password" validate:"required"`
+
+	// Confirm must be DELETE, so accounts are not erased by accident
+	Confirm string `json:"confirm" validate:"required,eq=DELETE"`
+}
@@ -0,0 +1,154 @@
+// Package models provides data structures and operations for the HideMe application.
+// This file contains models for destructive administrative actions, which are queued
+// until a second administrator approves them.
+package models
+
+import (
+	"time"
+
+	"github.com/yasinhessnawi1/Hideme_Backend/internal/constants"
+)
+
+// AdminActionType identifies a high-risk administrative operation.
+type AdminActionType string
+
+// Available administrative action types.
+const (
+	// ActionUserErasure permanently deletes a user account and all of its data.
+	ActionUserErasure AdminActionType = "user_erasure"
+
+	// ActionTenantDeletion permanently deletes a tenant and everything it owns.
+	ActionTenantDeletion AdminActionType = "tenant_deletion"
+
+	// ActionAdminPromotion grants the admin role to a user.
+	ActionAdminPromotion AdminActionType = "admin_promotion"
+)
+
+// AdminActionStatus describes where an administrative action is in the approval workflow.
+type AdminActionStatus string
+
+// Available administrative action statuses.
+const (
+	// ActionStatusPending indicates the action is waiting for a second administrator.
+	ActionStatusPending AdminActionStatus = "pending"
+
+	// ActionStatusApproved indicates the action was approved and is being executed.
+	ActionStatusApproved AdminActionStatus = "approved"
+
+	// ActionStatusExecuted indicates the action was carried out successfully.
+	ActionStatusExecuted AdminActionStatus = "executed"
+
+	// ActionStatusFailed indicates the action was approved but its execution failed.
+	ActionStatusFailed AdminActionStatus = "failed"
+
+	// ActionStatusRejected indicates an administrator rejected the action.
+	ActionStatusRejected AdminActionStatus = "rejected"
+
+	// ActionStatusExpired indicates nobody reviewed the action before it expired.
+	ActionStatusExpired AdminActionStatus = "expired"
+)
+
+// AdminAction represents a requested high-risk administrative operation.
+// Depending on policy, the action either runs immediately or waits in the
+// pending queue until a different administrator approves or rejects it.
+type AdminAction struct {
+	// ID is the unique identifier for the action
+	ID int64 `json:"id" db:"action_id"`
+
+	// ActionType identifies the operation to perform
+	ActionType AdminActionType `json:"action_type" db:"action_type"`
+
+	// TargetID is the identifier of the resource the action applies to
+	TargetID int64 `json:"target_id" db:"target_id"`
+
+	// Reason is the justification given by the requesting administrator
+	Reason string `json:"reason" db:"reason"`
+
+	// Status is the current state of the action
+	Status AdminActionStatus `json:"status" db:"status"`
+
+	// RequestedBy is the user ID of the administrator who requested the action
+	RequestedBy int64 `json:"requested_by" db:"requested_by"`
+
+	// ReviewedBy is the user ID of the administrator who approved or rejected the action
+	ReviewedBy *int64 `json:"reviewed_by,omitempty" db:"reviewed_by"`
+
+	// ReviewNote is an optional comment left by the reviewing administrator
+	ReviewNote string `json:"review_note,omitempty" db:"review_note"`
+
+	// ExecutionError holds the failure message if execution failed
+	ExecutionError string `json:"execution_error,omitempty" db:"execution_error"`
+
+	// CreatedAt is when the action was requested
+	CreatedAt time.Time `json:"created_at" db:"created_at"`
+
+	// ExpiresAt is when a pending action stops being approvable
+	ExpiresAt time.Time `json:"expires_at" db:"expires_at"`
+
+	// ReviewedAt is when the action was approved, rejected or expired
+	ReviewedAt *time.Time `json:"reviewed_at,omitempty" db:"reviewed_at"`
+}
+
+// NewAdminAction creates a new pending AdminAction.
+//
+// Parameters:
+//   - actionType: The operation to perform
+//   - targetID: The identifier of the resource the action applies to
+//   - reason: The justification for the action
+//   - requestedBy: The user ID of the requesting administrator
+//   - expiry: How long the action may wait for approval
+//
+// Returns:
+//   - A new AdminAction pointer in the pending state
+func NewAdminAction(actionType AdminActionType, targetID int64, reason string, requestedBy int64, expiry time.Duration) *AdminAction {
+	now := time.Now()
+	return &AdminAction{
+		ActionType:  actionType,
+		TargetID:    targetID,
+		Reason:      reason,
+		Status:      ActionStatusPending,
+		RequestedBy: requestedBy,
+		CreatedAt:   now,
+		ExpiresAt:   now.Add(expiry),
+	}
+}
+
+// TableName returns the database table name for the AdminAction model.
+// This method is used by ORM frameworks to determine where to persist this entity.
+func (a *AdminAction) TableName() string {
+	return constants.TableAdminActions
+}
+
+// IsPending checks if the action is still waiting for review.
+//
+// Returns:
+//   - true if the action is pending, false otherwise
+func (a *AdminAction) IsPending() bool {
+	return a.Status == ActionStatusPending
+}
+
+// IsExpired checks if a pending action has passed its approval deadline.
+//
+// Returns:
+//   - true if the action is pending and its expiry time has passed, false otherwise
+func (a *AdminAction) IsExpired() bool {
+	return a.IsPending() && time.Now().After(a.ExpiresAt)
+}
+
+// AdminActionRequest represents the data needed to request an administrative action.
+type AdminActionRequest struct {
+	// ActionType identifies the operation to perform
+	ActionType AdminActionType `json:"action_type" validate:"required"`
+
+	// TargetID is the identifier of the resource the action applies to
+	TargetID int64 `json:"target_id" validate:"required,gt=0"`
+
+	// Reason is the justification for the action
+	Reason string `json:"reason" validate:"required,max=500"`
+}
+
+// AdminActionReview represents the data submitted when approving or rejecting an action.
+type AdminActionReview struct {
+	// Note is an optional comment explaining the decision
+	Note string `json:"note" validate:"max=500"`
+}
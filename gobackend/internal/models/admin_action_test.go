@@ -0,0 +1,64 @@
+package models
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/yasinhessnawi1/Hideme_Backend/internal/constants"
+)
+
+func TestNewAdminAction(t *testing.T) {
+	// Act
+	action := NewAdminAction(ActionUserErasure, 42, "Account takeover", 1, time.Hour)
+
+	// Assert
+	assert.Equal(t, ActionUserErasure, action.ActionType)
+	assert.Equal(t, int64(42), action.TargetID)
+	assert.Equal(t, "Account takeover", action.Reason)
+	assert.Equal(t, int64(1), action.RequestedBy)
+	assert.Equal(t, ActionStatusPending, action.Status)
+	assert.Nil(t, action.ReviewedBy)
+	assert.Nil(t, action.ReviewedAt)
+	assert.Equal(t, time.Hour, action.ExpiresAt.Sub(action.CreatedAt))
+	assert.Zero(t, action.ID) // ID should be zero until saved
+}
+
+func TestAdminAction_TableName(t *testing.T) {
+	action := &AdminAction{}
+	assert.Equal(t, constants.TableAdminActions, action.TableName())
+}
+
+func TestAdminAction_IsExpired(t *testing.T) {
+	past := time.Now().Add(-time.Minute)
+	future := time.Now().Add(time.Minute)
+
+	tests := []struct {
+		name     string
+		action   *AdminAction
+		expected bool
+	}{
+		{
+			name:     "Pending and within deadline",
+			action:   &AdminAction{Status: ActionStatusPending, ExpiresAt: future},
+			expected: false,
+		},
+		{
+			name:     "Pending and past deadline",
+			action:   &AdminAction{Status: ActionStatusPending, ExpiresAt: past},
+			expected: true,
+		},
+		{
+			name:     "Executed actions never expire",
+			action:   &AdminAction{Status: ActionStatusExecuted, ExpiresAt: past},
+			expected: false,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.expected, tt.action.IsExpired())
+		})
+	}
+}
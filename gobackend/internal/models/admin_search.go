@@ -0,0 +1,60 @@
+// Package models provides data structures and operations for the HideMe application.
+// This file contains the results of the administrator search, which looks up users,
+// documents, API keys and sessions from a single reference, such as one quoted in a
+// support ticket.
+package models
+
+import "time"
+
+// AdminSearchDocument is a document found by the administrator search.
+// Document names are encrypted with the owner's tenant key and are not decrypted for
+// administrators, so only identifiers and timestamps are returned.
+type AdminSearchDocument struct {
+	// ID is the unique identifier of the document
+	ID int64 `json:"id"`
+
+	// UserID is the ID of the user who owns the document
+	UserID int64 `json:"user_id"`
+
+	// UploadTimestamp records when the document was uploaded
+	UploadTimestamp time.Time `json:"upload_timestamp"`
+
+	// LastModified records when the document was last modified
+	LastModified time.Time `json:"last_modified"`
+}
+
+// AdminSearchResults holds the matches of an administrator search, one bucket per resource type.
+// Every bucket is present, empty if nothing of that type matched.
+type AdminSearchResults struct {
+	// Query is the search term as it was matched
+	Query string `json:"query"`
+
+	// Users are the users whose ID matches or whose username or email contains the term
+	Users []*User `json:"users"`
+
+	// Documents are the documents whose ID or stored name matches the term
+	Documents []*AdminSearchDocument `json:"documents"`
+
+	// APIKeys are the API keys whose ID starts with the term
+	APIKeys []*APIKey `json:"api_keys"`
+
+	// Sessions are the sessions whose ID starts with the term
+	Sessions []*Session `json:"sessions"`
+}
+
+// NewAdminSearchResults creates empty results for a search term.
+//
+// Parameters:
+//   - query: The search term
+//
+// Returns:
+//   - A new AdminSearchResults pointer with all buckets empty
+func NewAdminSearchResults(query string) *AdminSearchResults {
+	return &AdminSearchResults{
+		Query:     query,
+		Users:     []*User{},
+		Documents: []*AdminSearchDocument{},
+		APIKeys:   []*APIKey{},
+		Sessions:  []*Session{},
+	}
+}
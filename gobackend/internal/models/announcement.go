@@ -0,0 +1,177 @@
+// Package models provides data structures and operations for the HideMe application.
+// This file contains models for in-product announcements, such as maintenance windows
+// and new-feature notices, which administrators publish and users read through the API.
+package models
+
+import (
+	"time"
+
+	"github.com/yasinhessnawi1/Hideme_Backend/internal/constants"
+)
+
+// AnnouncementCategory describes what an announcement is about.
+type AnnouncementCategory string
+
+// Available announcement categories.
+const (
+	// AnnouncementMaintenance announces a planned maintenance window.
+	AnnouncementMaintenance AnnouncementCategory = "maintenance"
+
+	// AnnouncementFeature announces a new or changed feature.
+	AnnouncementFeature AnnouncementCategory = "feature"
+
+	// AnnouncementInfo is any other notice.
+	AnnouncementInfo AnnouncementCategory = "info"
+)
+
+// Announcement is a notice published by an administrator to all users.
+type Announcement struct {
+	// ID is the unique identifier for this announcement
+	ID int64 `json:"id" db:"announcement_id"`
+
+	// Title is a short summary of the announcement
+	Title string `json:"title" db:"title"`
+
+	// Message is the text of the announcement
+	Message string `json:"message" db:"message"`
+
+	// Category describes what the announcement is about
+	Category AnnouncementCategory `json:"category" db:"category"`
+
+	// StartsAt is when the announcement is first shown to users
+	StartsAt time.Time `json:"starts_at" db:"starts_at"`
+
+	// EndsAt is when the announcement stops being shown, if it expires
+	EndsAt *time.Time `json:"ends_at,omitempty" db:"ends_at"`
+
+	// CreatedBy references the administrator who published the announcement; not shown to users
+	CreatedBy *int64 `json:"-" db:"created_by"`
+
+	// CreatedAt records when the announcement was created
+	CreatedAt time.Time `json:"created_at" db:"created_at"`
+
+	// UpdatedAt records when the announcement was last updated
+	UpdatedAt time.Time `json:"updated_at" db:"updated_at"`
+}
+
+// NewAnnouncement creates a new Announcement from the data entered by an administrator.
+// Announcements without a start time are shown immediately.
+//
+// Parameters:
+//   - createdBy: The ID of the administrator publishing the announcement
+//   - create: The title, message, category and optional display window of the announcement
+//
+// Returns:
+//   - A new Announcement pointer with the timestamps initialized
+func NewAnnouncement(createdBy int64, create *AnnouncementCreate) *Announcement {
+	now := time.Now()
+	announcement := &Announcement{
+		Title:     create.Title,
+		Message:   create.Message,
+		Category:  create.Category,
+		StartsAt:  now,
+		EndsAt:    create.EndsAt,
+		CreatedBy: &createdBy,
+		CreatedAt: now,
+		UpdatedAt: now,
+	}
+	if create.StartsAt != nil {
+		announcement.StartsAt = *create.StartsAt
+	}
+	return announcement
+}
+
+// Apply updates the announcement with the changes entered by an administrator.
+//
+// Parameters:
+//   - update: The fields to change; empty fields are left as they are
+func (a *Announcement) Apply(update *AnnouncementUpdate) {
+	if update.Title != "" {
+		a.Title = update.Title
+	}
+	if update.Message != "" {
+		a.Message = update.Message
+	}
+	if update.Category != "" {
+		a.Category = update.Category
+	}
+	if update.StartsAt != nil {
+		a.StartsAt = *update.StartsAt
+	}
+	if update.EndsAt != nil {
+		a.EndsAt = update.EndsAt
+	}
+	a.UpdatedAt = time.Now()
+}
+
+// ValidWindow reports whether the announcement ends after it starts.
+//
+// Returns:
+//   - true if the announcement has no end or ends after its start
+func (a *Announcement) ValidWindow() bool {
+	return a.EndsAt == nil || a.EndsAt.After(a.StartsAt)
+}
+
+// ActiveAt reports whether the announcement is shown to users at a point in time.
+//
+// Parameters:
+//   - t: The point in time to check
+//
+// Returns:
+//   - true if the announcement has started and not ended yet
+func (a *Announcement) ActiveAt(t time.Time) bool {
+	return !a.StartsAt.After(t) && (a.EndsAt == nil || a.EndsAt.After(t))
+}
+
+// TableName returns the database table name for the Announcement model.
+// This method is used by ORM frameworks to determine where to persist this entity.
+func (a *Announcement) TableName() string {
+	return constants.TableAnnouncements
+}
+
+// AnnouncementCreate represents the data required to publish an announcement.
+type AnnouncementCreate struct {
+	// Title is a short summary of the announcement
+	Title string `json:"title" validate:"required,max=200"`
+
+	// Message is the text of the announcement
+	Message string `json:"message" validate:"required,max=2000"`
+
+	// Category describes what the announcement is about
+	Category AnnouncementCategory `json:"category" validate:"required,oneof=maintenance feature info"`
+
+	// StartsAt is when the announcement is first shown (default: now)
+	StartsAt *time.Time `json:"starts_at,omitempty"`
+
+	// EndsAt is when the announcement stops being shown (default: never)
+	EndsAt *time.Time `json:"ends_at,omitempty"`
+}
+
+// AnnouncementUpdate represents the changes to an announcement; empty fields are left as they are.
+type AnnouncementUpdate struct {
+	// Title is a short summary of the announcement
+	Title string `json:"title" validate:"omitempty,max=200"`
+
+	// Message is the text of the announcement
+	Message string `json:"message" validate:"omitempty,max=2000"`
+
+	// Category describes what the announcement is about
+	Category AnnouncementCategory `json:"category" validate:"omitempty,oneof=maintenance feature info"`
+
+	// StartsAt is when the announcement is first shown
+	StartsAt *time.Time `json:"starts_at,omitempty"`
+
+	// EndsAt is when the announcement stops being shown
+	EndsAt *time.Time `json:"ends_at,omitempty"`
+}
+
+// UserAnnouncement is an announcement as shown to a user, together with whether they have read it.
+type UserAnnouncement struct {
+	Announcement
+
+	// Read indicates whether the user has marked the announcement as read
+	Read bool `json:"read"`
+
+	// ReadAt records when the user marked the announcement as read
+	ReadAt *time.Time `json:"read_at,omitempty"`
+}
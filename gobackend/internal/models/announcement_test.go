@@ -0,0 +1,97 @@
+package models
+
+import (
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/yasinhessnawi1/Hideme_Backend/internal/constants"
+)
+
+func TestNewAnnouncement(t *testing.T) {
+	announcement := NewAnnouncement(7, &AnnouncementCreate{
+		Title:    "New export format",
+		Message:  "Redacted documents can now be exported as images",
+		Category: AnnouncementFeature,
+	})
+
+	assert.Equal(t, "New export format", announcement.Title)
+	assert.Equal(t, AnnouncementFeature, announcement.Category)
+	require.NotNil(t, announcement.CreatedBy)
+	assert.Equal(t, int64(7), *announcement.CreatedBy)
+	assert.Equal(t, announcement.CreatedAt, announcement.StartsAt)
+	assert.Nil(t, announcement.EndsAt)
+
+	startsAt := time.Date(2026, 3, 1, 22, 0, 0, 0, time.UTC)
+	endsAt := startsAt.Add(2 * time.Hour)
+	scheduled := NewAnnouncement(7, &AnnouncementCreate{
+		Title:    "Maintenance",
+		Message:  "The service is unavailable during the upgrade",
+		Category: AnnouncementMaintenance,
+		StartsAt: &startsAt,
+		EndsAt:   &endsAt,
+	})
+	assert.Equal(t, startsAt, scheduled.StartsAt)
+	assert.Equal(t, &endsAt, scheduled.EndsAt)
+}
+
+func TestAnnouncement_Apply(t *testing.T) {
+	announcement := &Announcement{Title: "Maintenance", Message: "Tonight", Category: AnnouncementMaintenance}
+	endsAt := time.Date(2026, 3, 2, 0, 0, 0, 0, time.UTC)
+
+	announcement.Apply(&AnnouncementUpdate{Message: "Postponed to tomorrow", EndsAt: &endsAt})
+
+	assert.Equal(t, "Maintenance", announcement.Title)
+	assert.Equal(t, "Postponed to tomorrow", announcement.Message)
+	assert.Equal(t, AnnouncementMaintenance, announcement.Category)
+	assert.Equal(t, &endsAt, announcement.EndsAt)
+	assert.False(t, announcement.UpdatedAt.IsZero())
+}
+
+func TestAnnouncement_ActiveAt(t *testing.T) {
+	startsAt := time.Date(2026, 3, 1, 22, 0, 0, 0, time.UTC)
+	endsAt := startsAt.Add(2 * time.Hour)
+	announcement := &Announcement{StartsAt: startsAt, EndsAt: &endsAt}
+
+	assert.False(t, announcement.ActiveAt(startsAt.Add(-time.Minute)))
+	assert.True(t, announcement.ActiveAt(startsAt))
+	assert.True(t, announcement.ActiveAt(endsAt.Add(-time.Minute)))
+	assert.False(t, announcement.ActiveAt(endsAt))
+
+	announcement.EndsAt = nil
+	assert.True(t, announcement.ActiveAt(startsAt.AddDate(1, 0, 0)))
+}
+
+func TestAnnouncement_ValidWindow(t *testing.T) {
+	startsAt := time.Date(2026, 3, 1, 22, 0, 0, 0, time.UTC)
+	before := startsAt.Add(-time.Hour)
+	after := startsAt.Add(time.Hour)
+
+	assert.True(t, (&Announcement{StartsAt: startsAt}).ValidWindow())
+	assert.True(t, (&Announcement{StartsAt: startsAt, EndsAt: &after}).ValidWindow())
+	assert.False(t, (&Announcement{StartsAt: startsAt, EndsAt: &startsAt}).ValidWindow())
+	assert.False(t, (&Announcement{StartsAt: startsAt, EndsAt: &before}).ValidWindow())
+}
+
+func TestAnnouncement_TableName(t *testing.T) {
+	assert.Equal(t, constants.TableAnnouncements, (&Announcement{}).TableName())
+}
+
+func TestUserAnnouncement_JSON(t *testing.T) {
+	announcement := &UserAnnouncement{
+		Announcement: Announcement{ID: 3, Title: "Maintenance", Category: AnnouncementMaintenance},
+		Read:         true,
+	}
+	createdBy := int64(7)
+	announcement.CreatedBy = &createdBy
+
+	data, err := json.Marshal(announcement)
+	require.NoError(t, err)
+
+	assert.Contains(t, string(data), `"id":3`)
+	assert.Contains(t, string(data), `"read":true`)
+	assert.NotContains(t, string(data), "created_by")
+}
@@ -4,6 +4,7 @@
 package models
 
 import (
+	"strings"
 	"time"
 
 	"github.com/yasinhessnawi1/Hideme_Backend/internal/constants"
@@ -21,7 +22,7 @@ type APIKey struct {
 
 	// APIKeyHash stores a secure hash of the actual API key
 	// The actual key is only displayed to the user once upon creation
-	APIKeyHash string `json:"-" db:"api_key_hash"`
+	APIKeyHash string `json:"-" db:"api_key_hash" gdpr:"sensitive"`
 
 	// Name is a user-friendly identifier for this API key
 	Name string `json:"name" db:"name"`
@@ -31,6 +32,27 @@ type APIKey struct {
 
 	// CreatedAt records when this API key was created
 	CreatedAt time.Time `json:"created_at" db:"created_at"`
+
+	// RotatedFrom is the ID of the key this key replaced, if it was issued by a rotation
+	RotatedFrom *string `json:"rotated_from,omitempty" db:"rotated_from"`
+
+	// RotatedAt records when this key was replaced; it stays valid until ExpiresAt
+	RotatedAt *time.Time `json:"rotated_at,omitempty" db:"rotated_at"`
+
+	// UsedAfterRotationAt records the first use of this key after it was replaced
+	UsedAfterRotationAt *time.Time `json:"used_after_rotation_at,omitempty" db:"used_after_rotation_at"`
+
+	// DisabledAt records when an administrator disabled this key; nil while it is enabled
+	DisabledAt *time.Time `json:"disabled_at,omitempty" db:"disabled_at"`
+
+	// RotationCampaignID is the rotation campaign requiring this key to be rotated, if any
+	RotationCampaignID *int64 `json:"rotation_campaign_id,omitempty" db:"rotation_campaign_id"`
+
+	// RotationDeadline is when this key stops working under its rotation campaign, if any
+	RotationDeadline *time.Time `json:"rotation_deadline,omitempty" db:"rotation_deadline"`
+
+	// Scopes are the constants.APIKeyScope values this key is limited to; empty for full access
+	Scopes []string `json:"scopes" db:"scopes"`
 }
 
 // TableName returns the database table name for the APIKey model.
@@ -70,7 +92,107 @@ func NewAPIKey(userID int64, name string, keyHash string, expiryDuration time.Du
 //
 // This method helps maintain security by ensuring expired keys are not accepted.
 func (ak *APIKey) IsExpired() bool {
-	return time.Now().After(ak.ExpiresAt)
+	return ak.IsExpiredAt(time.Now())
+}
+
+// IsExpiredAt checks if the API key has expired at the given time.
+//
+// Parameters:
+//   - now: The current time
+//
+// Returns:
+//   - true if now is after the expiry time, false otherwise
+func (ak *APIKey) IsExpiredAt(now time.Time) bool {
+	return now.After(ak.ExpiresAt)
+}
+
+// IsRotated checks if the API key has been replaced by a rotation.
+//
+// Returns:
+//   - true if a replacement was issued, false otherwise
+//
+// A rotated key keeps working until it expires at the end of the overlap window.
+func (ak *APIKey) IsRotated() bool {
+	return ak.RotatedAt != nil
+}
+
+// IsDisabled checks if an administrator has disabled the API key.
+//
+// Returns:
+//   - true if the key was disabled, false otherwise
+func (ak *APIKey) IsDisabled() bool {
+	return ak.DisabledAt != nil
+}
+
+// IsPastRotationDeadline checks if the deadline of the key's rotation campaign has passed.
+// A key stops working at the deadline, whether or not it was rotated in time.
+//
+// Parameters:
+//   - now: The current time
+//
+// Returns:
+//   - true if the key is in a rotation campaign whose deadline has passed, false otherwise
+func (ak *APIKey) IsPastRotationDeadline(now time.Time) bool {
+	return ak.RotationDeadline != nil && now.After(*ak.RotationDeadline)
+}
+
+// IsUsable checks if the API key may authenticate requests.
+//
+// Parameters:
+//   - now: The current time
+//
+// Returns:
+//   - true if the key is neither expired, disabled nor past its rotation deadline
+func (ak *APIKey) IsUsable(now time.Time) bool {
+	return now.Before(ak.ExpiresAt) && !ak.IsDisabled() && !ak.IsPastRotationDeadline(now)
+}
+
+// Status describes the key for administrators, as one of the constants.APIKeyStatus values.
+//
+// Parameters:
+//   - now: The current time
+//
+// Returns:
+//   - The status of the key
+func (ak *APIKey) Status(now time.Time) string {
+	switch {
+	case ak.IsDisabled():
+		return constants.APIKeyStatusDisabled
+	case !now.Before(ak.ExpiresAt) || ak.IsPastRotationDeadline(now):
+		return constants.APIKeyStatusExpired
+	case ak.IsRotated():
+		return constants.APIKeyStatusRotated
+	case ak.RotationDeadline != nil:
+		return constants.APIKeyStatusPendingRotation
+	default:
+		return constants.APIKeyStatusActive
+	}
+}
+
+// APIKeyScopesAllow checks if the scopes of an API key allow a route requiring a scope.
+//
+// Parameters:
+//   - scopes: The scopes of the key; empty for a key with full access
+//   - required: The constants.APIKeyScope value the route requires
+//
+// Returns:
+//   - true if the key has no scopes, has the required scope, or has the write scope
+//     matching a required read scope
+func APIKeyScopesAllow(scopes []string, required string) bool {
+	if len(scopes) == 0 {
+		return true
+	}
+
+	implied := ""
+	if strings.HasSuffix(required, constants.APIKeyScopeReadSuffix) {
+		implied = strings.TrimSuffix(required, constants.APIKeyScopeReadSuffix) + constants.APIKeyScopeWriteSuffix
+	}
+	for _, scope := range scopes {
+		if scope == required || scope == implied {
+			return true
+		}
+	}
+	return false
 }
 
 // APIKeyCreationRequest represents a request to create a new API key.
@@ -83,6 +205,9 @@ type APIKeyCreationRequest struct {
 	// Duration specifies how long the API key should remain valid
 	// Must be one of the predefined durations (15m, 30m, 30d, 90d, 180d, 365d)
 	Duration string `json:"duration" validate:"required,oneof=15m 30m 30d 90d 180d 365d"` // Duration in days or minutes
+
+	// Scopes limit the routes the key may call; omit them for a key with full access
+	Scopes []string `json:"scopes" validate:"omitempty,max=5,unique,dive,oneof=settings:read settings:write documents:read documents:write admin"`
 }
 
 // APIKeyResponse represents the response for API key creation.
@@ -104,4 +229,13 @@ type APIKeyResponse struct {
 
 	// CreatedAt records when this API key was created
 	CreatedAt time.Time `json:"created_at"`
+
+	// Scopes are the scopes this key is limited to; empty for full access
+	Scopes []string `json:"scopes"`
+
+	// RotatedFrom is the ID of the key this key replaced, if it was issued by a rotation
+	RotatedFrom *string `json:"rotated_from,omitempty"`
+
+	// PreviousKeyExpiresAt is when the replaced key stops working, if this key was issued by a rotation
+	PreviousKeyExpiresAt *time.Time `json:"previous_key_expires_at,omitempty"`
 }
@@ -0,0 +1,113 @@
+// Package models provides data structures and operations for the HideMe application.
+// This file contains models for the administration of all users' API keys: the key
+// listing, the expiry policy and forced rotation campaigns.
+package models
+
+import (
+	"time"
+
+	"github.com/yasinhessnawi1/Hideme_Backend/internal/constants"
+)
+
+// AdminAPIKey is an API key as listed to administrators, with its owner.
+type AdminAPIKey struct {
+	APIKey
+
+	// Username is the username of the key's owner
+	Username string `json:"username"`
+
+	// Email is the email address of the key's owner
+	Email string `json:"email" redact:"support"`
+
+	// Status is one of the constants.APIKeyStatus values
+	Status string `json:"status"`
+}
+
+// APIKeyFilter selects the API keys listed to administrators.
+type APIKeyFilter struct {
+	// UserID only selects the keys of this user; 0 for all users
+	UserID int64
+
+	// Status only selects keys with this status, one of the constants.APIKeyStatus values; empty for all
+	Status string
+}
+
+// APIKeyPolicy is the expiry policy applied to all API keys.
+type APIKeyPolicy struct {
+	// MaxLifetimeDays is the longest lifetime of an API key in days; 0 for no limit
+	MaxLifetimeDays int `json:"max_lifetime_days" db:"max_lifetime_days"`
+
+	// UpdatedBy is the administrator who last changed the policy; nil if it was never set
+	UpdatedBy *int64 `json:"updated_by,omitempty" db:"updated_by"`
+
+	// UpdatedAt records when the policy was last changed; nil if it was never set
+	UpdatedAt *time.Time `json:"updated_at,omitempty" db:"updated_at"`
+}
+
+// MaxLifetime returns the longest lifetime of an API key.
+//
+// Returns:
+//   - The maximum lifetime; 0 for no limit
+func (p *APIKeyPolicy) MaxLifetime() time.Duration {
+	return time.Duration(p.MaxLifetimeDays) * 24 * time.Hour
+}
+
+// APIKeyPolicyUpdate is the request to change the API key expiry policy.
+type APIKeyPolicyUpdate struct {
+	// MaxLifetimeDays is the longest lifetime of an API key in days; 0 removes the limit
+	MaxLifetimeDays int `json:"max_lifetime_days" validate:"min=0,max=3650"`
+}
+
+// APIKeyPolicyResult is the expiry policy after a change.
+type APIKeyPolicyResult struct {
+	// Policy is the policy now in force
+	Policy *APIKeyPolicy `json:"policy"`
+
+	// KeysShortened is the number of existing keys whose expiry was brought forward to the new limit
+	KeysShortened int64 `json:"keys_shortened"`
+}
+
+// RotationCampaign requires the owners of a set of API keys to rotate them before a deadline.
+// Keys in the campaign stop working at the deadline; their replacements are not affected.
+type RotationCampaign struct {
+	// ID is the unique identifier for the campaign
+	ID int64 `json:"id" db:"campaign_id"`
+
+	// Name describes the reason for the campaign, such as a suspected leak
+	Name string `json:"name" db:"name"`
+
+	// Deadline is when the keys in the campaign stop working
+	Deadline time.Time `json:"deadline" db:"deadline"`
+
+	// CreatedBy is the administrator who started the campaign
+	CreatedBy int64 `json:"created_by" db:"created_by"`
+
+	// CreatedAt records when the campaign was started
+	CreatedAt time.Time `json:"created_at" db:"created_at"`
+
+	// KeyCount is the number of keys in the campaign
+	KeyCount int `json:"key_count"`
+
+	// RotatedCount is the number of keys in the campaign their owners have rotated
+	RotatedCount int `json:"rotated_count"`
+}
+
+// TableName returns the database table name for the RotationCampaign model.
+func (rc *RotationCampaign) TableName() string {
+	return constants.TableAPIKeyRotationCampaigns
+}
+
+// RotationCampaignCreate is the request to start a rotation campaign.
+type RotationCampaignCreate struct {
+	// Name describes the reason for the campaign
+	Name string `json:"name" validate:"required,max=100"`
+
+	// Deadline is when the keys in the campaign stop working; it must be in the future
+	Deadline time.Time `json:"deadline" validate:"required"`
+
+	// UserID only includes the keys of this user; 0 for all users
+	UserID int64 `json:"user_id,omitempty" validate:"min=0"`
+
+	// CreatedBefore only includes keys created before this time, such as the start of a suspected leak
+	CreatedBefore *time.Time `json:"created_before,omitempty"`
+}
@@ -105,3 +105,25 @@ func TestAPIKeyResponse(t *testing.T) {
 	assert.Equal(t, expiresAt, response.ExpiresAt)
 	assert.Equal(t, now, response.CreatedAt)
 }
+
+func TestAPIKeyScopesAllow(t *testing.T) {
+	tests := []struct {
+		name     string
+		scopes   []string
+		required string
+		want     bool
+	}{
+		{"No scopes", nil, "admin", true},
+		{"Exact scope", []string{"settings:read"}, "settings:read", true},
+		{"Write includes read", []string{"documents:write"}, "documents:read", true},
+		{"Read excludes write", []string{"documents:read"}, "documents:write", false},
+		{"Other resource", []string{"settings:write"}, "documents:read", false},
+		{"Admin is not implied", []string{"settings:write"}, "admin", false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, models.APIKeyScopesAllow(tt.scopes, tt.required))
+		})
+	}
+}
@@ -0,0 +1,135 @@
+// Package models provides data structures and operations for the HideMe application.
+// This file contains the time-boxed, read-only access grants of external auditors, which
+// replace the full user accounts auditors used to be given.
+package models
+
+import (
+	"fmt"
+	"slices"
+	"time"
+)
+
+// AuditorGrantRequest represents an administrator granting an external auditor read-only access.
+type AuditorGrantRequest struct {
+	// AuditorName is the name of the auditor, printed on their exports
+	AuditorName string `json:"auditor_name" validate:"required,max=255"`
+
+	// AuditorEmail is the email address of the auditor
+	AuditorEmail string `json:"auditor_email" validate:"required,email,max=255"`
+
+	// DocumentIDs are the documents the auditor may read
+	DocumentIDs []int64 `json:"document_ids,omitempty" validate:"omitempty,max=1000,dive,gt=0"`
+
+	// UserIDs are the users whose documents the auditor may read
+	UserIDs []int64 `json:"user_ids,omitempty" validate:"omitempty,max=100,dive,gt=0"`
+
+	// ExpiresInDays is how long the grant is valid, at most constants.AuditorGrantMaxDays
+	ExpiresInDays int `json:"expires_in_days" validate:"required,min=1,max=90"`
+
+	// Reason explains the audit for the access log
+	Reason string `json:"reason" validate:"required,max=500"`
+}
+
+// AuditorGrant grants an external auditor read-only access to documents until it expires or
+// is revoked. Only the hash of its token is stored.
+type AuditorGrant struct {
+	// ID is the unique identifier for this grant
+	ID int64 `json:"id" db:"grant_id"`
+
+	// AuditorName is the name of the auditor
+	AuditorName string `json:"auditor_name" db:"auditor_name" gdpr:"personal"`
+
+	// AuditorEmail is the email address of the auditor
+	AuditorEmail string `json:"auditor_email" db:"auditor_email" gdpr:"personal"`
+
+	// TokenHash is the SHA-256 hash of the token the auditor authenticates with
+	TokenHash string `json:"-" db:"token_hash" gdpr:"sensitive"`
+
+	// DocumentIDs are the documents the auditor may read
+	DocumentIDs []int64 `json:"document_ids" db:"document_ids"`
+
+	// UserIDs are the users whose documents the auditor may read
+	UserIDs []int64 `json:"user_ids" db:"user_ids"`
+
+	// Reason explains the audit
+	Reason string `json:"reason" db:"reason"`
+
+	// CreatedBy references the administrator who granted the access
+	CreatedBy int64 `json:"created_by" db:"created_by"`
+
+	// ExpiresAt is when the grant stops working
+	ExpiresAt time.Time `json:"expires_at" db:"expires_at"`
+
+	// RevokedAt records when an administrator revoked the grant; nil while it is not revoked
+	RevokedAt *time.Time `json:"revoked_at,omitempty" db:"revoked_at"`
+
+	// CreatedAt records when the grant was created
+	CreatedAt time.Time `json:"created_at" db:"created_at"`
+}
+
+// AuditorGrantCreated is a new auditor grant together with its token, which is shown only once.
+type AuditorGrantCreated struct {
+	*AuditorGrant
+
+	// Token is sent by the auditor in the X-Auditor-Token header
+	Token string `json:"token" gdpr:"sensitive"`
+}
+
+// Active reports whether the grant can be used at a moment.
+//
+// Parameters:
+//   - now: The moment to check
+//
+// Returns:
+//   - true if the grant is neither revoked nor expired
+func (g *AuditorGrant) Active(now time.Time) bool {
+	return g.RevokedAt == nil && now.Before(g.ExpiresAt)
+}
+
+// Covers reports whether the grant allows reading a document.
+//
+// Parameters:
+//   - doc: The document with its owner
+//
+// Returns:
+//   - true if the document, or all documents of its owner, are covered by the grant
+func (g *AuditorGrant) Covers(doc *Document) bool {
+	return slices.Contains(g.DocumentIDs, doc.ID) || slices.Contains(g.UserIDs, doc.UserID)
+}
+
+// Watermark returns the text marking an export made under the grant, so leaked copies can be
+// traced back to the auditor.
+//
+// Parameters:
+//   - exportedAt: When the export is created
+//
+// Returns:
+//   - The watermark naming the auditor, the grant and the time of the export
+func (g *AuditorGrant) Watermark(exportedAt time.Time) string {
+	return fmt.Sprintf("Confidential - exported for auditor %s <%s> under access grant %d at %s; all access is logged",
+		g.AuditorName, g.AuditorEmail, g.ID, exportedAt.UTC().Format(time.RFC3339))
+}
+
+// AuditorAccess is a request made with an auditor grant, logged whether it was allowed or not.
+type AuditorAccess struct {
+	// ID is the unique identifier for this log entry
+	ID int64 `json:"id" db:"access_id"`
+
+	// GrantID references the grant used
+	GrantID int64 `json:"grant_id" db:"grant_id"`
+
+	// Action is the constants.AuditorAction requested
+	Action string `json:"action" db:"action"`
+
+	// DocumentID references the document requested; nil for listings
+	DocumentID *int64 `json:"document_id,omitempty" db:"document_id"`
+
+	// Allowed reports whether the document was covered by the grant
+	Allowed bool `json:"allowed" db:"allowed"`
+
+	// IPAddress is the address the request came from
+	IPAddress string `json:"ip_address" db:"ip_address" gdpr:"personal"`
+
+	// AccessedAt records when the request was made
+	AccessedAt time.Time `json:"accessed_at" db:"accessed_at"`
+}
@@ -0,0 +1,62 @@
+package models
+
+import (
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestAuditorGrant_Active(t *testing.T) {
+	now := time.Now()
+	revokedAt := now.Add(-time.Hour)
+
+	tests := []struct {
+		name  string
+		grant AuditorGrant
+		want  bool
+	}{
+		{"Valid", AuditorGrant{ExpiresAt: now.Add(time.Hour)}, true},
+		{"Expired", AuditorGrant{ExpiresAt: now.Add(-time.Second)}, false},
+		{"Revoked", AuditorGrant{ExpiresAt: now.Add(time.Hour), RevokedAt: &revokedAt}, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.grant.Active(now); got != tt.want {
+				t.Errorf("Active() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestAuditorGrant_Covers(t *testing.T) {
+	grant := &AuditorGrant{DocumentIDs: []int64{10}, UserIDs: []int64{2}}
+
+	tests := []struct {
+		name string
+		doc  *Document
+		want bool
+	}{
+		{"Granted document", &Document{ID: 10, UserID: 1}, true},
+		{"Document of granted user", &Document{ID: 20, UserID: 2}, true},
+		{"Other document", &Document{ID: 11, UserID: 1}, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := grant.Covers(tt.doc); got != tt.want {
+				t.Errorf("Covers() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestAuditorGrant_Watermark(t *testing.T) {
+	grant := &AuditorGrant{ID: 7, AuditorName: "Kari Nordmann", AuditorEmail: "kari@audit.example"}
+
+	watermark := grant.Watermark(time.Date(2026, 3, 1, 12, 0, 0, 0, time.UTC))
+
+	for _, want := range []string{"Kari Nordmann", "kari@audit.example", "grant 7", "2026-03-01T12:00:00Z"} {
+		if !strings.Contains(watermark, want) {
+			t.Errorf("Watermark() = %q, want it to contain %q", watermark, want)
+		}
+	}
+}
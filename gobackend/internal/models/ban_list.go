@@ -17,6 +17,49 @@ type BanList struct {
 
 	// SettingID references the user settings to which this ban list belongs
 	SettingID int64 `json:"setting_id" db:"setting_id"`
+
+	// BanListOptions controls how all words of the ban list are matched
+	BanListOptions
+}
+
+// BanListOptions controls how all words of a ban list are matched. Each option applies to
+// every word on top of the word's own options, so a list that ignores case matches all its
+// words in any case. The zero value leaves matching to the options of each word.
+type BanListOptions struct {
+	// CaseInsensitive matches every word in any case, e.g. "STRÖM" for "Ström"
+	CaseInsensitive bool `json:"case_insensitive" db:"case_insensitive"`
+
+	// DiacriticInsensitive matches every word with or without diacritics, e.g. "Strom" for "Ström"
+	DiacriticInsensitive bool `json:"diacritic_insensitive" db:"diacritic_insensitive"`
+
+	// WholeWord matches every word only where it is not part of a longer word
+	WholeWord bool `json:"whole_word" db:"whole_word"`
+}
+
+// Apply adds the options of a ban list to the options of one of its words.
+//
+// Parameters:
+//   - word: The options set on the word
+//
+// Returns:
+//   - The options the word is matched with
+func (o BanListOptions) Apply(word BanListMatchOptions) BanListMatchOptions {
+	word.CaseInsensitive = word.CaseInsensitive || o.CaseInsensitive
+	word.DiacriticInsensitive = word.DiacriticInsensitive || o.DiacriticInsensitive
+	word.WholeWord = word.WholeWord || o.WholeWord
+	return word
+}
+
+// BanListOptionsUpdate sets how all words of a ban list are matched; omitted options are kept.
+type BanListOptionsUpdate struct {
+	// CaseInsensitive matches every word in any case
+	CaseInsensitive *bool `json:"case_insensitive,omitempty"`
+
+	// DiacriticInsensitive matches every word with or without diacritics
+	DiacriticInsensitive *bool `json:"diacritic_insensitive,omitempty"`
+
+	// WholeWord matches every word only where it is not part of a longer word
+	WholeWord *bool `json:"whole_word,omitempty"`
 }
 
 // TableName returns the database table name for the BanList model.
@@ -50,4 +93,17 @@ type BanListWithWords struct {
 
 	// Words is a slice of banned words associated with this ban list
 	Words []string `json:"words"`
+
+	// BanListOptions controls how all words of the ban list are matched
+	BanListOptions
+
+	// Options holds the options set on each word, for the words with any; words missing
+	// from it match as the options of the ban list say
+	Options map[string]BanListMatchOptions `json:"options,omitempty"`
+}
+
+// MatchOptions returns how a word of the ban list is matched, with the options of the
+// ban list added to those of the word.
+func (bl *BanListWithWords) MatchOptions(word string) BanListMatchOptions {
+	return bl.BanListOptions.Apply(bl.Options[word])
 }
@@ -19,7 +19,46 @@ type BanListWord struct {
 	BanID int64 `json:"ban_id" db:"ban_id"`
 
 	// Word contains the actual text to be excluded from detection
-	Word string `json:"word" db:"word"`
+	Word string `json:"word" db:"word" gdpr:"personal"`
+
+	// Options controls which variants of the word match it
+	Options BanListMatchOptions `json:"options"`
+}
+
+// StemmingLanguage is the language whose inflections of a banned word match it.
+type StemmingLanguage string
+
+const (
+	// StemmingNone matches the word only as written
+	StemmingNone StemmingLanguage = ""
+
+	// StemmingEnglish also matches English inflections, e.g. "invoices" for "invoice"
+	StemmingEnglish StemmingLanguage = "en"
+
+	// StemmingNorwegian also matches Norwegian inflections, e.g. "fakturaen" for "faktura"
+	StemmingNorwegian StemmingLanguage = "no"
+)
+
+// BanListMatchOptions controls how a banned word is matched against text. The zero value
+// matches the word exactly as written, anywhere in the text.
+type BanListMatchOptions struct {
+	// CaseInsensitive matches the word in any case, e.g. "STRÖM" for "Ström"
+	CaseInsensitive bool `json:"case_insensitive" db:"case_insensitive"`
+
+	// DiacriticInsensitive matches the word with or without diacritics, e.g. "Strom" for
+	// "Ström"; Norwegian and German letters fold as well, e.g. "Sorensen" for "Sørensen"
+	DiacriticInsensitive bool `json:"diacritic_insensitive" db:"diacritic_insensitive"`
+
+	// WholeWord matches the word only where it is not part of a longer word
+	WholeWord bool `json:"whole_word" db:"whole_word"`
+
+	// Stemming is the language whose inflections of the word match it; empty for none
+	Stemming StemmingLanguage `json:"stemming,omitempty" db:"stemming" validate:"omitempty,oneof=en no"`
+}
+
+// IsZero reports whether the options match the word only exactly as written.
+func (o BanListMatchOptions) IsZero() bool {
+	return o == BanListMatchOptions{}
 }
 
 // TableName returns the database table name for the BanListWord model.
@@ -47,6 +86,19 @@ func NewBanListWord(banID int64, word string) *BanListWord {
 // This structure facilitates bulk operations on ban lists for more efficient updates.
 type BanListWordBatch struct {
 	// Words is a slice of strings to be added to or removed from a ban list
-	// Each word must be non-empty and the slice must contain at least one word
+	// The slice must contain at least one word; empty words are reported one by one
+	Words []string `json:"words" validate:"required,min=1"`
+
+	// Options sets how the added words are matched, for words that belong together such as
+	// a list of surnames; words already on the list keep their options if omitted
+	Options *BanListMatchOptions `json:"options,omitempty"`
+}
+
+// BanListWordOptionsUpdate sets how words already on a ban list are matched.
+type BanListWordOptionsUpdate struct {
+	// Words are the words whose options are set; words not on the list are ignored
 	Words []string `json:"words" validate:"required,min=1,dive,required,min=1"`
+
+	// Options is how the words are matched from now on
+	Options BanListMatchOptions `json:"options"`
 }
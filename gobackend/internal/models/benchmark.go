@@ -0,0 +1,168 @@
+// Package models provides data structures and operations for the HideMe application.
+// This file contains models for the opt-in cross-tenant benchmarks, which compare a
+// tenant's detection statistics with noise-added averages of its industry.
+package models
+
+import (
+	"time"
+
+	"github.com/yasinhessnawi1/Hideme_Backend/internal/constants"
+)
+
+// Industry is the sector a tenant declares when it opts in to benchmarking.
+type Industry string
+
+// Available industries.
+const (
+	// IndustryHealthcare covers hospitals, clinics and care providers.
+	IndustryHealthcare Industry = "healthcare"
+
+	// IndustryFinance covers banks, payment providers and accounting.
+	IndustryFinance Industry = "finance"
+
+	// IndustryInsurance covers insurers and brokers.
+	IndustryInsurance Industry = "insurance"
+
+	// IndustryLegal covers law firms and legal departments.
+	IndustryLegal Industry = "legal"
+
+	// IndustryGovernment covers public administration.
+	IndustryGovernment Industry = "government"
+
+	// IndustryEducation covers schools and universities.
+	IndustryEducation Industry = "education"
+
+	// IndustryTechnology covers software and IT services.
+	IndustryTechnology Industry = "technology"
+
+	// IndustryRetail covers retail and e-commerce.
+	IndustryRetail Industry = "retail"
+
+	// IndustryOther covers all remaining sectors.
+	IndustryOther Industry = "other"
+)
+
+// BenchmarkConsent records that a tenant shares its statistics for the cross-tenant benchmarks.
+// Tenants without a consent are never included in any aggregate.
+type BenchmarkConsent struct {
+	// UserID references the consenting tenant
+	UserID int64 `json:"user_id" db:"user_id"`
+
+	// Industry is the sector the tenant is benchmarked against
+	Industry Industry `json:"industry" db:"industry"`
+
+	// ConsentedAt records when the tenant opted in or last changed its industry
+	ConsentedAt time.Time `json:"consented_at" db:"consented_at"`
+}
+
+// NewBenchmarkConsent creates a new BenchmarkConsent for a tenant.
+//
+// Parameters:
+//   - userID: The ID of the consenting tenant
+//   - industry: The sector the tenant is benchmarked against
+//
+// Returns:
+//   - A new BenchmarkConsent pointer with the consent time set to now
+func NewBenchmarkConsent(userID int64, industry Industry) *BenchmarkConsent {
+	return &BenchmarkConsent{
+		UserID:      userID,
+		Industry:    industry,
+		ConsentedAt: time.Now(),
+	}
+}
+
+// TableName returns the database table name for the BenchmarkConsent model.
+// This method is used by ORM frameworks to determine where to persist this entity.
+func (bc *BenchmarkConsent) TableName() string {
+	return constants.TableBenchmarkConsents
+}
+
+// BenchmarkConsentRequest represents the data required to opt in to benchmarking.
+type BenchmarkConsentRequest struct {
+	// Industry is the sector the tenant is benchmarked against
+	Industry Industry `json:"industry" validate:"required,oneof=healthcare finance insurance legal government education technology retail other"`
+}
+
+// TenantBenchmarkStats is the activity of a single tenant within the benchmark window.
+// These raw statistics never leave the service; only noise-added aggregates are published.
+type TenantBenchmarkStats struct {
+	// UserID references the tenant
+	UserID int64 `db:"user_id"`
+
+	// Industry is the sector the tenant is benchmarked against
+	Industry Industry `db:"industry"`
+
+	// EntityCount is the number of entities detected in the tenant's documents
+	EntityCount int64 `db:"entity_count"`
+
+	// PageCount is the number of pages the tenant had processed
+	PageCount int64 `db:"page_count"`
+}
+
+// EntitiesPerPage returns the average number of entities detected per processed page.
+//
+// Returns:
+//   - The average, or 0 if no pages were processed
+func (ts *TenantBenchmarkStats) EntitiesPerPage() float64 {
+	if ts.PageCount <= 0 {
+		return 0
+	}
+	return float64(ts.EntityCount) / float64(ts.PageCount)
+}
+
+// IndustryBenchmark is the published, noise-added aggregate of an industry.
+// The number of contributing tenants is deliberately not published.
+type IndustryBenchmark struct {
+	// Industry is the sector the aggregate covers
+	Industry Industry `json:"industry"`
+
+	// AvgEntitiesPerPage is the noise-added average of the tenants' entities per page
+	AvgEntitiesPerPage float64 `json:"avg_entities_per_page"`
+}
+
+// BenchmarkReport is the set of benchmarks published by the last benchmark run.
+type BenchmarkReport struct {
+	// Industries lists the industries with enough consenting tenants, in alphabetical order
+	Industries []*IndustryBenchmark `json:"industries"`
+
+	// Epsilon is the privacy budget spent on each aggregate
+	Epsilon float64 `json:"epsilon"`
+
+	// WindowStart is the start of the activity the aggregates cover
+	WindowStart time.Time `json:"window_start"`
+
+	// GeneratedAt is when the aggregates were computed
+	GeneratedAt time.Time `json:"generated_at"`
+}
+
+// Industry returns the published benchmark of an industry.
+//
+// Parameters:
+//   - industry: The sector to look up
+//
+// Returns:
+//   - The benchmark, or nil if the industry has too few consenting tenants
+func (br *BenchmarkReport) Industry(industry Industry) *IndustryBenchmark {
+	for _, benchmark := range br.Industries {
+		if benchmark.Industry == industry {
+			return benchmark
+		}
+	}
+	return nil
+}
+
+// BenchmarkComparison compares a tenant's own statistics with the published benchmarks.
+type BenchmarkComparison struct {
+	// Industry is the sector the tenant is benchmarked against
+	Industry Industry `json:"industry"`
+
+	// EntitiesPerPage is the tenant's own, exact average of entities per page
+	EntitiesPerPage float64 `json:"entities_per_page"`
+
+	// IndustryAvgEntitiesPerPage is the benchmark of the tenant's industry,
+	// or nil if the industry has too few consenting tenants
+	IndustryAvgEntitiesPerPage *float64 `json:"industry_avg_entities_per_page"`
+
+	// Report contains the benchmarks of all industries
+	Report *BenchmarkReport `json:"report"`
+}
@@ -0,0 +1,54 @@
+package models
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/yasinhessnawi1/Hideme_Backend/internal/constants"
+)
+
+func TestNewBenchmarkConsent(t *testing.T) {
+	// Act
+	consent := NewBenchmarkConsent(42, IndustryLegal)
+
+	// Assert
+	assert.Equal(t, int64(42), consent.UserID)
+	assert.Equal(t, IndustryLegal, consent.Industry)
+	assert.False(t, consent.ConsentedAt.IsZero())
+}
+
+func TestBenchmarkConsent_TableName(t *testing.T) {
+	consent := &BenchmarkConsent{}
+	assert.Equal(t, constants.TableBenchmarkConsents, consent.TableName())
+}
+
+func TestTenantBenchmarkStats_EntitiesPerPage(t *testing.T) {
+	tests := []struct {
+		name     string
+		stats    *TenantBenchmarkStats
+		expected float64
+	}{
+		{name: "Entities per page", stats: &TenantBenchmarkStats{EntityCount: 30, PageCount: 12}, expected: 2.5},
+		{name: "No entities", stats: &TenantBenchmarkStats{EntityCount: 0, PageCount: 12}, expected: 0},
+		{name: "No pages", stats: &TenantBenchmarkStats{EntityCount: 30, PageCount: 0}, expected: 0},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.expected, tt.stats.EntitiesPerPage())
+		})
+	}
+}
+
+func TestBenchmarkReport_Industry(t *testing.T) {
+	report := &BenchmarkReport{
+		Industries: []*IndustryBenchmark{
+			{Industry: IndustryFinance, AvgEntitiesPerPage: 3.2},
+			{Industry: IndustryLegal, AvgEntitiesPerPage: 7.9},
+		},
+	}
+
+	assert.Equal(t, 7.9, report.Industry(IndustryLegal).AvgEntitiesPerPage)
+	assert.Nil(t, report.Industry(IndustryHealthcare))
+}
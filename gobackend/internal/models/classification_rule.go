@@ -0,0 +1,196 @@
+// Package models provides data structures and operations for the HideMe application.
+// This file contains models for classification rules, which automatically tag, file
+// and set the retention of documents when they are uploaded.
+package models
+
+import (
+	"path"
+	"slices"
+	"strings"
+)
+
+// ClassificationRule assigns tags, a folder and a retention period to the documents
+// it matches at upload. A rule matches a document when all of its conditions hold.
+type ClassificationRule struct {
+	// ID is the unique identifier for this rule
+	ID int64 `json:"id" db:"rule_id"`
+
+	// SettingID references the user settings to which this rule belongs
+	SettingID int64 `json:"setting_id" db:"setting_id"`
+
+	// Name describes the rule to the user
+	Name string `json:"name" db:"name"`
+
+	// Priority orders the rules; rules with a lower priority are applied first
+	Priority int `json:"priority" db:"priority"`
+
+	// FilenamePattern is a case-insensitive glob the filename must match, such as "invoice_*.pdf"
+	FilenamePattern string `json:"filename_pattern" db:"filename_pattern"`
+
+	// Source is the source the document must come from, such as "scanner" or "email"
+	Source string `json:"source" db:"source"`
+
+	// EntityTypes are the entity types that must all be detected in the document
+	EntityTypes []string `json:"entity_types" db:"entity_types"`
+
+	// MinEntities is the minimum number of entities that must be detected in the document
+	MinEntities int `json:"min_entities" db:"min_entities"`
+
+	// Tags are added to matching documents
+	Tags []string `json:"tags" db:"tags"`
+
+	// Folder is the folder matching documents are filed in
+	Folder string `json:"folder" db:"folder"`
+
+	// RetentionDays is the number of days matching documents are kept; zero keeps them
+	RetentionDays int `json:"retention_days" db:"retention_days"`
+}
+
+// TableName returns the database table name for the ClassificationRule model.
+// This method is used by ORM frameworks to determine where to persist this entity.
+func (cr *ClassificationRule) TableName() string {
+	return "classification_rules"
+}
+
+// HasCondition reports whether the rule has at least one condition. Rules without
+// conditions would match every document.
+func (cr *ClassificationRule) HasCondition() bool {
+	return cr.FilenamePattern != "" || cr.Source != "" || len(cr.EntityTypes) > 0 || cr.MinEntities > 0
+}
+
+// HasAction reports whether the rule assigns anything to the documents it matches.
+func (cr *ClassificationRule) HasAction() bool {
+	return len(cr.Tags) > 0 || cr.Folder != "" || cr.RetentionDays > 0
+}
+
+// Matches reports whether a document meets all conditions of the rule.
+//
+// Parameters:
+//   - filename: The original filename of the document
+//   - source: The source the document came from; empty if unknown
+//   - entityCounts: The number of detected entities by entity type
+//
+// Returns:
+//   - true if every condition of the rule holds for the document
+func (cr *ClassificationRule) Matches(filename, source string, entityCounts map[string]int) bool {
+	if cr.FilenamePattern != "" {
+		if ok, _ := path.Match(strings.ToLower(cr.FilenamePattern), strings.ToLower(filename)); !ok {
+			return false
+		}
+	}
+
+	if cr.Source != "" && !strings.EqualFold(cr.Source, source) {
+		return false
+	}
+
+	for _, entityType := range cr.EntityTypes {
+		if entityCounts[entityType] == 0 {
+			return false
+		}
+	}
+
+	if cr.MinEntities > 0 {
+		total := 0
+		for _, count := range entityCounts {
+			total += count
+		}
+		if total < cr.MinEntities {
+			return false
+		}
+	}
+
+	return true
+}
+
+// DocumentClassification is what the matching classification rules assign to a document.
+type DocumentClassification struct {
+	// Tags are the tags of all matching rules, without duplicates
+	Tags []string `json:"tags"`
+
+	// Folder is the folder of the first matching rule that sets one
+	Folder string `json:"folder"`
+
+	// RetentionDays is the retention of the first matching rule that sets one; zero keeps the document
+	RetentionDays int `json:"retention_days"`
+}
+
+// Apply adds the actions of a matching rule. Tags accumulate, while the folder and the
+// retention are kept from the first rule that sets them.
+//
+// Parameters:
+//   - rule: The matching rule
+func (dc *DocumentClassification) Apply(rule *ClassificationRule) {
+	for _, tag := range rule.Tags {
+		if !slices.Contains(dc.Tags, tag) {
+			dc.Tags = append(dc.Tags, tag)
+		}
+	}
+	if dc.Folder == "" {
+		dc.Folder = rule.Folder
+	}
+	if dc.RetentionDays == 0 {
+		dc.RetentionDays = rule.RetentionDays
+	}
+}
+
+// ClassificationRuleRequest represents a request to create or replace a classification rule.
+// This structure validates input parameters for classification rules.
+type ClassificationRuleRequest struct {
+	// Name describes the rule to the user
+	Name string `json:"name" validate:"required,max=100,printable_text"`
+
+	// Priority orders the rules; rules with a lower priority are applied first
+	Priority int `json:"priority" validate:"min=0,max=1000"`
+
+	// FilenamePattern is a case-insensitive glob the filename must match
+	FilenamePattern string `json:"filename_pattern" validate:"omitempty,max=255"`
+
+	// Source is the source the document must come from
+	Source string `json:"source" validate:"omitempty,max=50"`
+
+	// EntityTypes are the entity types that must all be detected in the document
+	EntityTypes []string `json:"entity_types" validate:"omitempty,max=20,dive,required,max=50"`
+
+	// MinEntities is the minimum number of entities that must be detected in the document
+	MinEntities int `json:"min_entities" validate:"min=0"`
+
+	// Tags are added to matching documents
+	Tags []string `json:"tags" validate:"omitempty,max=20,dive,required,max=50,printable_text"`
+
+	// Folder is the folder matching documents are filed in
+	Folder string `json:"folder" validate:"omitempty,max=255,printable_text"`
+
+	// RetentionDays is the number of days matching documents are kept; zero keeps them
+	RetentionDays int `json:"retention_days" validate:"min=0,max=3650"`
+}
+
+// NewClassificationRule creates a ClassificationRule from a request.
+//
+// Parameters:
+//   - settingID: The ID of the user settings to which the rule belongs
+//   - req: The validated rule request
+//
+// Returns:
+//   - A new ClassificationRule pointer with the requested conditions and actions
+func NewClassificationRule(settingID int64, req *ClassificationRuleRequest) *ClassificationRule {
+	return &ClassificationRule{
+		SettingID:       settingID,
+		Name:            req.Name,
+		Priority:        req.Priority,
+		FilenamePattern: req.FilenamePattern,
+		Source:          req.Source,
+		EntityTypes:     nonNilStrings(req.EntityTypes),
+		MinEntities:     req.MinEntities,
+		Tags:            nonNilStrings(req.Tags),
+		Folder:          req.Folder,
+		RetentionDays:   req.RetentionDays,
+	}
+}
+
+// nonNilStrings returns an empty slice for nil, so that lists are serialized as [].
+func nonNilStrings(values []string) []string {
+	if values == nil {
+		return []string{}
+	}
+	return values
+}
@@ -0,0 +1,52 @@
+package models
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestClassificationRule_Matches(t *testing.T) {
+	counts := map[string]int{"PERSON": 2, "HEALTH": 1}
+
+	tests := []struct {
+		name string
+		rule ClassificationRule
+		want bool
+	}{
+		{"filename pattern ignores case", ClassificationRule{FilenamePattern: "Invoice_*.PDF"}, true},
+		{"filename pattern mismatch", ClassificationRule{FilenamePattern: "contract_*"}, false},
+		{"source", ClassificationRule{Source: "Scanner"}, true},
+		{"other source", ClassificationRule{Source: "email"}, false},
+		{"all entity types detected", ClassificationRule{EntityTypes: []string{"PERSON", "HEALTH"}}, true},
+		{"entity type missing", ClassificationRule{EntityTypes: []string{"PERSON", "IBAN"}}, false},
+		{"enough entities", ClassificationRule{MinEntities: 3}, true},
+		{"too few entities", ClassificationRule{MinEntities: 4}, false},
+		{"all conditions must hold", ClassificationRule{Source: "scanner", MinEntities: 4}, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, tt.rule.Matches("invoice_2024.pdf", "scanner", counts))
+		})
+	}
+}
+
+func TestDocumentClassification_Apply(t *testing.T) {
+	var classification DocumentClassification
+
+	classification.Apply(&ClassificationRule{Tags: []string{"finance"}, RetentionDays: 30})
+	classification.Apply(&ClassificationRule{Tags: []string{"finance", "scanned"}, Folder: "Scans", RetentionDays: 365})
+	classification.Apply(&ClassificationRule{Folder: "Other"})
+
+	assert.Equal(t, []string{"finance", "scanned"}, classification.Tags)
+	assert.Equal(t, "Scans", classification.Folder)
+	assert.Equal(t, 30, classification.RetentionDays)
+}
+
+func TestClassificationRule_HasConditionAndAction(t *testing.T) {
+	assert.False(t, (&ClassificationRule{Tags: []string{"all"}}).HasCondition())
+	assert.True(t, (&ClassificationRule{MinEntities: 1}).HasCondition())
+	assert.False(t, (&ClassificationRule{Source: "scanner"}).HasAction())
+	assert.True(t, (&ClassificationRule{RetentionDays: 7}).HasAction())
+}
@@ -17,6 +17,34 @@ import (
 	"github.com/yasinhessnawi1/Hideme_Backend/internal/constants"
 )
 
+// EntityReviewStatus describes where a detected entity is in the review workflow.
+type EntityReviewStatus string
+
+// Available entity review statuses.
+const (
+	// EntityStatusPending indicates nobody has reviewed the detection yet.
+	EntityStatusPending EntityReviewStatus = "pending"
+
+	// EntityStatusAccepted indicates a reviewer confirmed the detection.
+	EntityStatusAccepted EntityReviewStatus = "accepted"
+
+	// EntityStatusRejected indicates a reviewer dismissed the detection as a false positive;
+	// it is not redacted.
+	EntityStatusRejected EntityReviewStatus = "rejected"
+)
+
+// Valid reports whether the status is one of the entity review statuses.
+//
+// Returns:
+//   - true if the status is pending, accepted or rejected
+func (s EntityReviewStatus) Valid() bool {
+	switch s {
+	case EntityStatusPending, EntityStatusAccepted, EntityStatusRejected:
+		return true
+	}
+	return false
+}
+
 // DetectedEntity represents sensitive information identified within a document.
 // It stores both the entity itself and structured information about its position and redaction.
 // Each entity is linked to a document and detection method, allowing for tracking of
@@ -33,7 +61,8 @@ type DetectedEntity struct {
 
 	// EntityName contains the actual sensitive information detected.
 	// Note: This field contains sensitive data and should be handled according to privacy policies.
-	EntityName string `json:"entity_name" db:"entity_name"`
+	// Auditors see the metadata of the entity but not its text.
+	EntityName string `json:"entity_name" db:"entity_name" gdpr:"sensitive" redact:"auditor"`
 
 	// RedactionSchema contains positional and styling information for redaction.
 	// This is stored in an encrypted format in the database.
@@ -41,6 +70,15 @@ type DetectedEntity struct {
 
 	// DetectedTimestamp records when this entity was detected.
 	DetectedTimestamp time.Time `json:"detected_timestamp" db:"detected_timestamp"`
+
+	// Status is where the detection is in the review workflow.
+	Status EntityReviewStatus `json:"status" db:"status"`
+
+	// ReviewedBy is the user who last changed the status; nil until the entity is reviewed.
+	ReviewedBy *int64 `json:"reviewed_by,omitempty" db:"reviewed_by"`
+
+	// ReviewedAt records when the status was last changed; nil until the entity is reviewed.
+	ReviewedAt *time.Time `json:"reviewed_at,omitempty" db:"reviewed_at"`
 }
 
 // TableName returns the database table name for the DetectedEntity model.
@@ -50,7 +88,7 @@ func (de *DetectedEntity) TableName() string {
 }
 
 // NewDetectedEntity creates a new DetectedEntity instance with the given parameters.
-// It automatically sets the detection timestamp to the current time.
+// It automatically sets the detection timestamp to the current time; the entity awaits review.
 //
 // Parameters:
 //   - documentID: The ID of the document where the entity was found
@@ -67,6 +105,7 @@ func NewDetectedEntity(documentID, methodID int64, entityName string, schema Red
 		EntityName:        entityName,
 		RedactionSchema:   schema,
 		DetectedTimestamp: time.Now(),
+		Status:            EntityStatusPending,
 	}
 }
 
@@ -186,6 +225,88 @@ func (rs *RedactionSchema) Scan(value interface{}) error {
 	return json.Unmarshal(bytes, &rs)
 }
 
+// DetectedEntityBatchRequest is a batch of detected entities to add to a document in one call.
+// The entities are validated one by one, so that invalid entities are reported without
+// failing the whole batch.
+type DetectedEntityBatchRequest struct {
+	// Entities are the detected entities, at most constants.DetectedEntityBatchMax
+	Entities []DetectedEntityInput `json:"entities" validate:"required,min=1,max=1000"`
+}
+
+// DetectedEntityInput is a detected entity in a batch request.
+type DetectedEntityInput struct {
+	// MethodID references the detection method that found the entity
+	MethodID int64 `json:"method_id" validate:"required,gt=0"`
+
+	// EntityName contains the sensitive information detected
+	EntityName string `json:"entity_name" validate:"required,max=255"`
+
+	// RedactionSchema contains the position and redaction of the entity
+	RedactionSchema RedactionSchema `json:"redaction_schema"`
+
+	// DetectedTimestamp records when the entity was detected; the time of the request if omitted
+	DetectedTimestamp *time.Time `json:"detected_timestamp,omitempty"`
+}
+
+// DetectedEntityBatchResult is the outcome of adding a batch of detected entities.
+type DetectedEntityBatchResult struct {
+	// DocumentID references the document the entities were added to
+	DocumentID int64 `json:"document_id"`
+
+	// EntityIDs are the IDs of the added entities, in the order of the request; entities
+	// that failed validation are left out
+	EntityIDs []int64 `json:"entity_ids"`
+}
+
+// DetectedEntityFilter selects the detected entities of a document. Zero values don't filter.
+type DetectedEntityFilter struct {
+	// Status only selects entities with this review status
+	Status EntityReviewStatus
+
+	// MethodID only selects entities found by this detection method
+	MethodID int64
+
+	// After only selects entities after this one, newest first; nil to start at the newest
+	After *utils.KeysetCursor
+
+	// Limit is the maximum number of entities to select; 0 selects all
+	Limit int
+}
+
+// EntityStatusUpdate is the request to change the review status of a detected entity.
+type EntityStatusUpdate struct {
+	// Status is the new review status
+	Status EntityReviewStatus `json:"status" validate:"required,oneof=pending accepted rejected"`
+}
+
+// EntityStatusBulkUpdate is the request to change the review status of many detected
+// entities of a document at once.
+type EntityStatusBulkUpdate struct {
+	// EntityIDs are the entities to change, at most constants.DetectedEntityBatchMax
+	EntityIDs []int64 `json:"entity_ids" validate:"required,min=1,max=1000,dive,gt=0"`
+
+	// Status is the new review status
+	Status EntityReviewStatus `json:"status" validate:"required,oneof=pending accepted rejected"`
+}
+
+// EntityReviewResult is the outcome of changing the review status of detected entities.
+type EntityReviewResult struct {
+	// DocumentID references the document the entities belong to
+	DocumentID int64 `json:"document_id"`
+
+	// EntityIDs are the entities whose status changed
+	EntityIDs []int64 `json:"entity_ids"`
+
+	// Status is the new review status
+	Status EntityReviewStatus `json:"status"`
+
+	// ReviewedBy is the user who changed the status
+	ReviewedBy int64 `json:"reviewed_by"`
+
+	// ReviewedAt records when the status changed
+	ReviewedAt time.Time `json:"reviewed_at"`
+}
+
 // DetectedEntityWithMethod represents a detected entity with its associated detection method.
 // This is a convenience struct for API responses that need method information.
 // It extends DetectedEntity with method-specific fields to avoid multiple database lookups.
@@ -0,0 +1,120 @@
+// Package models provides data structures and operations for the HideMe application.
+// This file contains the entity-type taxonomy and the bundles administrators promote the
+// detection-method catalog and the taxonomy between environments with, e.g. from staging
+// to production.
+package models
+
+import (
+	"time"
+)
+
+// EntityType is an entity type of the taxonomy, such as PERSON or EMAIL_ADDRESS, with the
+// detection method detecting it.
+type EntityType struct {
+	// ID is the unique identifier for this entity type
+	ID int64 `json:"id" db:"entity_type_id"`
+
+	// EntityType is the name of the entity type
+	EntityType string `json:"entity_type" db:"entity_type"`
+
+	// MethodID references the detection method detecting the entity type
+	MethodID int64 `json:"method_id" db:"method_id"`
+
+	// MethodName is the name of the detection method
+	MethodName string `json:"method_name" db:"method_name"`
+
+	// Description explains what the entity type covers
+	Description string `json:"description" db:"description"`
+}
+
+// DetectionCatalogBundle is the detection-method catalog and entity-type taxonomy of an
+// environment. Methods and entity types are identified by name, as their IDs differ
+// between environments.
+type DetectionCatalogBundle struct {
+	// Version is the version of the bundle format, constants.DetectionCatalogBundleVersion
+	Version int `json:"version" validate:"required"`
+
+	// ExportedAt records when the bundle was exported
+	ExportedAt time.Time `json:"exported_at"`
+
+	// DetectionMethods is the detection-method catalog, by name
+	DetectionMethods []DetectionCatalogMethod `json:"detection_methods" validate:"max=500,dive"`
+
+	// EntityTypes is the entity-type taxonomy, by name
+	EntityTypes []DetectionCatalogEntityType `json:"entity_types" validate:"max=2000,dive"`
+}
+
+// DetectionCatalogMethod is a detection method in a bundle.
+type DetectionCatalogMethod struct {
+	// MethodName is the name of the detection method
+	MethodName string `json:"method_name" validate:"required,max=50"`
+
+	// HighlightColor is the CSS color of the entities found by the method
+	HighlightColor string `json:"highlight_color" validate:"required,max=20"`
+}
+
+// DetectionCatalogEntityType is an entity type in a bundle.
+type DetectionCatalogEntityType struct {
+	// EntityType is the name of the entity type
+	EntityType string `json:"entity_type" validate:"required,max=100"`
+
+	// MethodName is the name of the detection method detecting the entity type
+	MethodName string `json:"method_name" validate:"required,max=50"`
+
+	// Description explains what the entity type covers
+	Description string `json:"description" validate:"max=255"`
+}
+
+// DetectionCatalogChange is what importing a bundle does with a method or entity type.
+type DetectionCatalogChange struct {
+	// Name is the name of the method or entity type
+	Name string `json:"name"`
+
+	// Action is create, update, unchanged, or kept for items only the environment has
+	Action string `json:"action"`
+
+	// Fields lists the values an update changes
+	Fields []DetectionCatalogFieldChange `json:"fields,omitempty"`
+}
+
+// DetectionCatalogFieldChange is a value an import changes.
+type DetectionCatalogFieldChange struct {
+	// Field is the name of the value, e.g. highlight_color
+	Field string `json:"field"`
+
+	// From is the value in the environment
+	From string `json:"from"`
+
+	// To is the value in the bundle
+	To string `json:"to"`
+}
+
+// DetectionCatalogDiff is the difference between a bundle and the configuration of an
+// environment, previewed before an import or reported after it.
+type DetectionCatalogDiff struct {
+	// Applied is set once the changes were made; a preview changes nothing
+	Applied bool `json:"applied"`
+
+	// Created is the number of methods and entity types the import creates
+	Created int `json:"created"`
+
+	// Updated is the number of methods and entity types the import updates
+	Updated int `json:"updated"`
+
+	// Unchanged is the number of methods and entity types already as in the bundle
+	Unchanged int `json:"unchanged"`
+
+	// DetectionMethods lists the changes to the detection methods, by name
+	DetectionMethods []DetectionCatalogChange `json:"detection_methods"`
+
+	// EntityTypes lists the changes to the entity types, by name
+	EntityTypes []DetectionCatalogChange `json:"entity_types"`
+}
+
+// HasChanges reports whether importing the bundle changes anything.
+//
+// Returns:
+//   - true if a method or entity type is created or updated
+func (d *DetectionCatalogDiff) HasChanges() bool {
+	return d.Created > 0 || d.Updated > 0
+}
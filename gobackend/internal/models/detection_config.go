@@ -0,0 +1,27 @@
+// Package models provides data structures and operations for the HideMe application.
+// This file contains the detection configuration composed from a user's settings.
+package models
+
+import "time"
+
+// DetectionConfig is everything the detection service needs to process documents for a
+// user, composed from their general settings, ban list, search patterns and model entities.
+type DetectionConfig struct {
+	// UserID identifies the user the configuration belongs to
+	UserID int64 `json:"user_id"`
+
+	// GeneralSettings contains the user's detection preferences
+	GeneralSettings *UserSetting `json:"general_settings"`
+
+	// BanList contains the words excluded from detection
+	BanList *BanListWithWords `json:"ban_list"`
+
+	// SearchPatterns contains the user's custom search patterns
+	SearchPatterns []*SearchPattern `json:"search_patterns"`
+
+	// ModelEntities contains the user's custom entities with their detection methods
+	ModelEntities []*ModelEntityWithMethod `json:"model_entities"`
+
+	// ComposedAt is when the configuration was read from the database
+	ComposedAt time.Time `json:"composed_at"`
+}
@@ -0,0 +1,90 @@
+// Package models provides data structures and operations for the HideMe application.
+// This file contains models for the runtime diagnostics.
+package models
+
+// QueryDiagnosticsUpdate represents a change to the query monitoring settings.
+// Omitted fields keep their current value.
+type QueryDiagnosticsUpdate struct {
+	// MetricsEnabled turns per-query statistics and slow query reporting on or off
+	MetricsEnabled *bool `json:"metrics_enabled,omitempty"`
+
+	// ExplainEnabled turns capturing the plan of slow queries on or off
+	ExplainEnabled *bool `json:"explain_enabled,omitempty"`
+
+	// SlowQueryThresholdMs is the duration in milliseconds from which a query is slow
+	SlowQueryThresholdMs *int64 `json:"slow_query_threshold_ms,omitempty" validate:"omitempty,gt=0"`
+}
+
+// CacheStats describes the use of an in-process cache since startup.
+type CacheStats struct {
+	// Name identifies the cache
+	Name string `json:"name"`
+
+	// Entries is the number of values currently cached
+	Entries int `json:"entries"`
+
+	// Hits is the number of requests served from the cache
+	Hits int64 `json:"hits"`
+
+	// Misses is the number of requests that loaded the value from the database
+	Misses int64 `json:"misses"`
+
+	// SharedLoads is the number of requests that waited for another request's load
+	// instead of querying the database themselves
+	SharedLoads int64 `json:"shared_loads"`
+}
+
+// ShadowTrafficStats describes the requests mirrored to the shadow deployment since startup
+// and how the shadow's answers diverged from this server's.
+type ShadowTrafficStats struct {
+	// Target is the base URL of the shadow deployment
+	Target string `json:"target"`
+
+	// Percent is the share of read-only requests that is mirrored
+	Percent float64 `json:"percent"`
+
+	// Mirrored is the number of requests whose shadow response was compared
+	Mirrored int64 `json:"mirrored"`
+
+	// Dropped is the number of sampled requests not mirrored because too many were in flight
+	Dropped int64 `json:"dropped"`
+
+	// Failed is the number of mirrored requests the shadow did not answer in time
+	Failed int64 `json:"failed"`
+
+	// StatusMismatches is the number of shadow responses with a different status code
+	StatusMismatches int64 `json:"status_mismatches"`
+
+	// LatencyRegressions is the number of shadow responses slower than the tolerance allows
+	LatencyRegressions int64 `json:"latency_regressions"`
+
+	// PrimaryMeanLatencyMs is the mean latency of this server for the mirrored requests
+	PrimaryMeanLatencyMs float64 `json:"primary_mean_latency_ms"`
+
+	// ShadowMeanLatencyMs is the mean latency of the shadow for the mirrored requests
+	ShadowMeanLatencyMs float64 `json:"shadow_mean_latency_ms"`
+
+	// Routes breaks the divergences down by route pattern, the most divergent first
+	Routes []ShadowRouteStats `json:"routes"`
+}
+
+// ShadowRouteStats describes the mirrored requests of one route pattern.
+type ShadowRouteStats struct {
+	// Route is the route pattern, such as GET /api/documents/{id}
+	Route string `json:"route"`
+
+	// Mirrored is the number of requests whose shadow response was compared
+	Mirrored int64 `json:"mirrored"`
+
+	// Failed is the number of mirrored requests the shadow did not answer in time
+	Failed int64 `json:"failed"`
+
+	// StatusMismatches is the number of shadow responses with a different status code
+	StatusMismatches int64 `json:"status_mismatches"`
+
+	// LatencyRegressions is the number of shadow responses slower than the tolerance allows
+	LatencyRegressions int64 `json:"latency_regressions"`
+
+	// LastMismatch describes the most recent status mismatch, e.g. "200 != 500"
+	LastMismatch string `json:"last_mismatch,omitempty"`
+}
@@ -26,7 +26,7 @@ type Document struct {
 
 	// HashedDocumentName stores a secure hash of the original filename
 	// This preserves privacy while enabling document identification
-	HashedDocumentName string `json:"hashed_document_name" db:"hashed_document_name"`
+	HashedDocumentName string `json:"hashed_document_name" db:"hashed_document_name" gdpr:"personal"`
 
 	// UploadTimestamp records when this document was initially uploaded
 	UploadTimestamp time.Time `json:"upload_timestamp" db:"upload_timestamp"`
@@ -36,7 +36,151 @@ type Document struct {
 
 	// RedactionSchema stores the redaction mapping for the document as a JSON string
 	// This is stored encrypted in the database for added security
-	RedactionSchema string `json:"redaction_schema" db:"redaction_schema"`
+	// It holds the detected texts, so it is not shown to auditors
+	RedactionSchema string `json:"redaction_schema" db:"redaction_schema" gdpr:"sensitive" redact:"auditor"`
+
+	// Language is the ISO 639-1 code of the document's language, used by the detection
+	// service to choose its models; empty if it could not be determined
+	Language string `json:"language" db:"language"`
+
+	// Tags are the tags assigned to the document by classification rules
+	Tags []string `json:"tags" db:"tags"`
+
+	// Folder is the folder the document was filed in by classification rules; empty for none
+	Folder string `json:"folder" db:"folder"`
+
+	// Source is where the document came from, such as "scanner" or "email"; empty if unknown
+	Source string `json:"source" db:"source"`
+
+	// RetainUntil is when the document is deleted under its retention; nil keeps it
+	RetainUntil *time.Time `json:"retain_until,omitempty" db:"retain_until"`
+
+	// SchemaVersion is the coordinate system version of the stored redaction schema. It is
+	// not serialized, as schemas are returned with their own version after normalization.
+	SchemaVersion int `json:"-" db:"schema_version"`
+
+	// FilenameScrubbed reports whether the filename matched the user's ban list or search
+	// patterns at upload; the stored name is then the scrubbed one, not the original
+	FilenameScrubbed bool `json:"filename_scrubbed" db:"filename_scrubbed"`
+
+	// ArchivedAt is when the document was moved to cold storage; nil while it is in hot storage.
+	// Archived documents have no redaction schema or entities until they are restored.
+	ArchivedAt *time.Time `json:"archived_at,omitempty" db:"archived_at"`
+
+	// EntityCount is the number of detections in the redaction schema, stored unencrypted
+	// so documents can be searched by it; nil for documents stored before it was kept
+	EntityCount *int `json:"-" db:"entity_count"`
+
+	// EntityTypes are the distinct entity types in the redaction schema, stored unencrypted
+	// so documents can be searched by them
+	EntityTypes []string `json:"-" db:"entity_types"`
+}
+
+// DocumentSearchFilter selects the documents of a user by name, upload date and detections.
+// Zero values don't filter.
+type DocumentSearchFilter struct {
+	// Name is part of the document name, matched without regard to case
+	Name string
+
+	// UploadedFrom only selects documents uploaded at or after this time
+	UploadedFrom *time.Time
+
+	// UploadedTo only selects documents uploaded before this time
+	UploadedTo *time.Time
+
+	// EntityTypes only selects documents in which all of these entity types were detected
+	EntityTypes []string
+
+	// MinEntities only selects documents with at least this many detections
+	MinEntities *int
+
+	// MaxEntities only selects documents with at most this many detections
+	MaxEntities *int
+}
+
+// SetEntitySummary stores the number of detections and the detected entity types of a
+// redaction schema on the document, so it can be searched without decrypting the schema.
+//
+// Parameters:
+//   - redactionSchema: The redaction schema of the document
+func (d *Document) SetEntitySummary(redactionSchema RedactionMapping) {
+	count := redactionSchema.EntityCount()
+	d.EntityCount = &count
+	d.EntityTypes = redactionSchema.EntityTypes()
+}
+
+// StorageTierOf returns the storage tier of a document archived at archivedAt,
+// constants.StorageTierArchive once it is archived and constants.StorageTierHot otherwise.
+func StorageTierOf(archivedAt *time.Time) string {
+	if archivedAt != nil {
+		return constants.StorageTierArchive
+	}
+	return constants.StorageTierHot
+}
+
+// StaleDocument is a document left unfinished for longer than the stale threshold: never
+// processed, or with detected entities still awaiting review.
+type StaleDocument struct {
+	// DocumentID is the ID of the stale document
+	DocumentID int64 `json:"document_id" db:"document_id"`
+
+	// UserID references the owner of the document
+	UserID int64 `json:"-" db:"user_id"`
+
+	// Reason is why the document is stale, constants.StaleReasonUnprocessed or constants.StaleReasonPendingReview
+	Reason string `json:"reason" db:"reason"`
+
+	// LastActivity is when the document was last changed or one of its entities reviewed
+	LastActivity time.Time `json:"last_activity" db:"last_activity"`
+}
+
+// RetentionPreview lists the documents of a user the document retention maintenance task
+// would delete, together with their detected entities, without deleting anything.
+type RetentionPreview struct {
+	// RetentionDays is the document retention setting the preview is for; zero keeps documents
+	RetentionDays int `json:"retention_days"`
+
+	// DocumentCount is the number of documents that would be deleted
+	DocumentCount int64 `json:"document_count"`
+
+	// EntityCount is the number of detected entities that would be deleted with them
+	EntityCount int64 `json:"entity_count"`
+
+	// Documents are the documents that would be deleted, by ID, at most constants.RetentionPreviewLimit of them
+	Documents []*ExpiredDocument `json:"documents"`
+}
+
+// ExpiredDocument is a document whose retention has passed.
+type ExpiredDocument struct {
+	// DocumentID is the ID of the document
+	DocumentID int64 `json:"document_id" db:"document_id"`
+
+	// UploadTimestamp records when the document was uploaded
+	UploadTimestamp time.Time `json:"upload_timestamp" db:"upload_timestamp"`
+
+	// RetainUntil is the retention set by a classification rule at upload, if any
+	RetainUntil *time.Time `json:"retain_until,omitempty" db:"retain_until"`
+
+	// EntityCount is the number of detected entities of the document
+	EntityCount int64 `json:"entity_count" db:"detected_entities"`
+
+	// Reason is why the document is deleted, constants.RetentionReasonRule or constants.RetentionReasonSetting
+	Reason string `json:"reason"`
+}
+
+// DocumentLifecycleRequest exempts a document from stale document cleanup or makes it subject to it again.
+type DocumentLifecycleRequest struct {
+	// Exempt keeps the document however long it stays unfinished
+	Exempt *bool `json:"exempt" validate:"required"`
+}
+
+// DocumentLifecycle reports whether a document is exempt from stale document cleanup.
+type DocumentLifecycle struct {
+	// DocumentID is the ID of the document
+	DocumentID int64 `json:"document_id"`
+
+	// Exempt reports whether the document is kept however long it stays unfinished
+	Exempt bool `json:"exempt"`
 }
 
 // NewDocument creates a new Document instance with the given original filename and user ID.
@@ -150,25 +294,56 @@ type DocumentSummary struct {
 	// LastModified records when this document was last modified
 	LastModified time.Time `json:"last_modified"`
 
-	// EntityCount indicates how many sensitive entities were detected in this document
+	// EntityCount indicates how many sensitive entities were detected in this document;
+	// zero while the document is archived, as its entities are then in cold storage
 	EntityCount int `json:"entity_count"`
+
+	// Language is the ISO 639-1 code of the document's language; empty if unknown
+	Language string `json:"language"`
+
+	// Tags are the tags assigned to the document by classification rules
+	Tags []string `json:"tags"`
+
+	// Folder is the folder the document was filed in; empty for none
+	Folder string `json:"folder"`
+
+	// RetainUntil is when the document is deleted under its retention; nil keeps it
+	RetainUntil *time.Time `json:"retain_until,omitempty"`
+
+	// FilenameScrubbed reports whether the filename matched the user's rules at upload
+	FilenameScrubbed bool `json:"filename_scrubbed"`
+
+	// StorageTier is "hot", or "archive" once the document is in cold storage and must be
+	// restored before it is read
+	StorageTier string `json:"storage_tier"`
+
+	// ArchivedAt is when the document was moved to cold storage; nil while it is in hot storage
+	ArchivedAt *time.Time `json:"archived_at,omitempty"`
 }
 
 // RedactionMapping represents the structure for redaction data
 // It includes a list of file results
 type RedactionMapping struct {
-	Pages []Page `json:"pages"`
+	// Version is the coordinate system of the bounding boxes, see RedactionSchemaVersion;
+	// zero for schemas stored before versioning
+	Version int    `json:"version"`
+	Pages   []Page `json:"pages"`
 }
 
 // Page represents a single page in the document with sensitive information
 type Page struct {
-	PageNumber int         `json:"page"`
-	Sensitive  []Sensitive `json:"sensitive"`
+	PageNumber int `json:"page"`
+
+	// Width and Height are the page size in points, needed to convert absolute bounding boxes
+	Width  float64 `json:"width,omitempty"`
+	Height float64 `json:"height,omitempty"`
+
+	Sensitive []Sensitive `json:"sensitive"`
 }
 
 // Sensitive represents sensitive information detected on a page
 type Sensitive struct {
-	OriginalText string  `json:"original_text"`
+	OriginalText string  `json:"original_text" gdpr:"sensitive" redact:"auditor"`
 	EntityType   string  `json:"entity_type"`
 	Score        float64 `json:"score"`
 	Start        int     `json:"start"`
@@ -0,0 +1,50 @@
+// Package models provides data structures and operations for the HideMe application.
+// This file contains the stored PDF content of uploaded documents, which is kept encrypted
+// in the document storage and linked to its document by a row in the database.
+package models
+
+import (
+	"bytes"
+	"time"
+
+	"github.com/yasinhessnawi1/Hideme_Backend/internal/constants"
+)
+
+// DocumentContent links a document to its PDF content in the document storage. The content
+// is encrypted with the data-encryption key of the document's owner before it is stored.
+// The row outlives its document, so the content of deleted documents can be found and
+// deleted from the storage afterwards.
+type DocumentContent struct {
+	// DocumentID references the document the content belongs to
+	DocumentID int64 `json:"document_id" db:"document_id"`
+
+	// StorageKey is the name of the encrypted content in the document storage
+	StorageKey string `json:"-" db:"storage_key"`
+
+	// ContentType is the media type of the content, application/pdf
+	ContentType string `json:"content_type" db:"content_type"`
+
+	// SizeBytes is the size of the content before encryption
+	SizeBytes int64 `json:"size_bytes" db:"size_bytes"`
+
+	// SHA256 is the hex-encoded SHA-256 of the content before encryption
+	SHA256 string `json:"sha256" db:"sha256"`
+
+	// SegmentSize is the size of the segments the content is encrypted in, so parts of it
+	// can be read on their own; 0 if it was encrypted as a whole, as before segments
+	SegmentSize int `json:"-" db:"segment_size"`
+
+	// CreatedAt records when the content was stored
+	CreatedAt time.Time `json:"created_at" db:"created_at"`
+}
+
+// IsPDF reports whether content starts with the PDF file signature.
+//
+// Parameters:
+//   - content: The uploaded file
+//
+// Returns:
+//   - true if the file is a PDF
+func IsPDF(content []byte) bool {
+	return bytes.HasPrefix(content, []byte(constants.PDFSignature))
+}
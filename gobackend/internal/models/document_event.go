@@ -0,0 +1,64 @@
+// Package models provides data structures and operations for the HideMe application.
+// This file contains models for the document timeline, a chronological feed of what
+// happened to a document assembled from the records kept about it.
+package models
+
+import (
+	"fmt"
+	"time"
+)
+
+// DocumentEventType identifies what happened to a document.
+type DocumentEventType string
+
+// Available document event types.
+const (
+	// EventDocumentUploaded indicates the document was uploaded.
+	EventDocumentUploaded DocumentEventType = "uploaded"
+
+	// EventEntitiesDetected indicates sensitive entities were detected in the document.
+	EventEntitiesDetected DocumentEventType = "entities_detected"
+
+	// EventDocumentModified indicates the document or its redaction schema was changed.
+	EventDocumentModified DocumentEventType = "modified"
+)
+
+// DocumentEvent represents a single entry in the timeline of a document.
+// Entities detected by the same method within the same second are reported as one event.
+type DocumentEvent struct {
+	// Type identifies what happened
+	Type DocumentEventType `json:"type"`
+
+	// Timestamp records when it happened
+	Timestamp time.Time `json:"timestamp"`
+
+	// EntityCount is the number of entities detected, for entities_detected events
+	EntityCount int `json:"entity_count,omitempty"`
+
+	// MethodName is the detection method that found the entities, for entities_detected events
+	MethodName string `json:"method_name,omitempty"`
+
+	// Description is a human-readable summary of the event
+	Description string `json:"description"`
+}
+
+// Describe fills in the human-readable description of the event.
+func (e *DocumentEvent) Describe() {
+	switch e.Type {
+	case EventDocumentUploaded:
+		e.Description = "Document uploaded"
+	case EventEntitiesDetected:
+		noun := "entities"
+		if e.EntityCount == 1 {
+			noun = "entity"
+		}
+		e.Description = fmt.Sprintf("Detected %d %s", e.EntityCount, noun)
+		if e.MethodName != "" {
+			e.Description += " with " + e.MethodName
+		}
+	case EventDocumentModified:
+		e.Description = "Document modified"
+	default:
+		e.Description = string(e.Type)
+	}
+}
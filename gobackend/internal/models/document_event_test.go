@@ -0,0 +1,43 @@
+package models
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDocumentEvent_Describe(t *testing.T) {
+	tests := []struct {
+		name     string
+		event    DocumentEvent
+		expected string
+	}{
+		{
+			name:     "Uploaded",
+			event:    DocumentEvent{Type: EventDocumentUploaded},
+			expected: "Document uploaded",
+		},
+		{
+			name:     "Entities detected",
+			event:    DocumentEvent{Type: EventEntitiesDetected, EntityCount: 5, MethodName: "Presidio"},
+			expected: "Detected 5 entities with Presidio",
+		},
+		{
+			name:     "Single entity without method",
+			event:    DocumentEvent{Type: EventEntitiesDetected, EntityCount: 1},
+			expected: "Detected 1 entity",
+		},
+		{
+			name:     "Modified",
+			event:    DocumentEvent{Type: EventDocumentModified},
+			expected: "Document modified",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.event.Describe()
+			assert.Equal(t, tt.expected, tt.event.Description)
+		})
+	}
+}
@@ -0,0 +1,200 @@
+// Package models provides data structures and operations for the HideMe application.
+// This file contains the export of a single document's full record, which bundles
+// everything kept about a document so it can be handed off, e.g. to external counsel.
+package models
+
+import (
+	"archive/zip"
+	"encoding/json"
+	"fmt"
+	"io"
+	"time"
+
+	"github.com/yasinhessnawi1/Hideme_Backend/internal/utils/gdprlog"
+)
+
+// DocumentExportVersion is the version of the document export format.
+const DocumentExportVersion = 1
+
+// DocumentExportMetadata is the metadata of an exported document.
+type DocumentExportMetadata struct {
+	// ID is the unique identifier of the document
+	ID int64 `json:"id"`
+
+	// Filename is the original name of the document
+	Filename string `json:"filename" gdpr:"personal"`
+
+	// UploadTimestamp records when the document was uploaded
+	UploadTimestamp time.Time `json:"upload_timestamp"`
+
+	// LastModified records when the document was last modified
+	LastModified time.Time `json:"last_modified"`
+
+	// Language is the ISO 639-1 code of the document's language; empty if unknown
+	Language string `json:"language"`
+
+	// Tags are the tags assigned to the document by classification rules
+	Tags []string `json:"tags"`
+
+	// Folder is the folder the document was filed in; empty for none
+	Folder string `json:"folder"`
+
+	// Source is where the document came from; empty if unknown
+	Source string `json:"source"`
+
+	// RetainUntil is when the document is deleted under its retention; nil keeps it
+	RetainUntil *time.Time `json:"retain_until,omitempty"`
+}
+
+// DocumentExport is the full record of a single document.
+type DocumentExport struct {
+	// Version is the export format version, see DocumentExportVersion
+	Version int `json:"version"`
+
+	// ExportedAt records when the export was created
+	ExportedAt time.Time `json:"exported_at"`
+
+	// Document is the metadata of the document
+	Document DocumentExportMetadata `json:"document"`
+
+	// RedactionSchema is the redaction mapping of the whole document
+	RedactionSchema RedactionMapping `json:"redaction_schema"`
+
+	// Entities lists every detected entity with its redaction schema and detection method
+	Entities []*DetectedEntityWithMethod `json:"entities"`
+
+	// Timeline lists what happened to the document, oldest first
+	Timeline []*DocumentEvent `json:"timeline"`
+
+	// DataCategories maps the JSON path of each personal and sensitive field of the export
+	// to its GDPR category, so the recipient knows which parts need protection
+	DataCategories map[string]gdprlog.LogCategory `json:"data_categories"`
+
+	// Watermark marks exports made for someone other than the owner, such as an auditor,
+	// so leaked copies can be traced back; empty otherwise
+	Watermark string `json:"watermark,omitempty"`
+
+	// Locale is the regional formatting the export was made for; empty for ISO formats
+	Locale Locale `json:"locale,omitempty"`
+
+	// ExportedOn is the date of the export in the formatting of Locale; empty for ISO formats
+	ExportedOn string `json:"exported_on,omitempty"`
+}
+
+// documentExportCategories are the personal and sensitive fields of a document export,
+// read from the gdpr tags of the models it is made of.
+var documentExportCategories = gdprlog.CategorizedPaths(DocumentExport{})
+
+// NewDocumentExport creates the export of a document.
+//
+// Parameters:
+//   - doc: The document with its name decrypted
+//   - schema: The decrypted redaction mapping of the document
+//   - entities: The detected entities of the document
+//   - timeline: The events of the document, oldest first
+//   - exportedAt: When the export is created
+//
+// Returns:
+//   - A new DocumentExport pointer; nil lists are exported as empty lists
+func NewDocumentExport(doc *Document, schema RedactionMapping, entities []*DetectedEntityWithMethod, timeline []*DocumentEvent, exportedAt time.Time) *DocumentExport {
+	if entities == nil {
+		entities = []*DetectedEntityWithMethod{}
+	}
+	if timeline == nil {
+		timeline = []*DocumentEvent{}
+	}
+	return &DocumentExport{
+		Version:         DocumentExportVersion,
+		ExportedAt:      exportedAt,
+		Document:        newDocumentExportMetadata(doc),
+		RedactionSchema: schema,
+		Entities:        entities,
+		Timeline:        timeline,
+		DataCategories:  documentExportCategories,
+	}
+}
+
+// Localize formats the export for a locale. ISO formats, the default, need no localizing.
+//
+// Parameters:
+//   - locale: The locale to format for; unsupported locales fall back to ISO formats
+func (de *DocumentExport) Localize(locale Locale) {
+	if locale == LocaleISO || !locale.IsSupported() {
+		return
+	}
+	de.Locale = locale
+	de.ExportedOn = de.ExportedAt.Format(NewLocaleFormat(locale, "").DateLayout)
+}
+
+// newDocumentExportMetadata creates the exported metadata of a document with its name decrypted.
+func newDocumentExportMetadata(doc *Document) DocumentExportMetadata {
+	tags := doc.Tags
+	if tags == nil {
+		tags = []string{}
+	}
+	return DocumentExportMetadata{
+		ID:              doc.ID,
+		Filename:        doc.HashedDocumentName,
+		UploadTimestamp: doc.UploadTimestamp,
+		LastModified:    doc.LastModified,
+		Language:        doc.Language,
+		Tags:            tags,
+		Folder:          doc.Folder,
+		Source:          doc.Source,
+		RetainUntil:     doc.RetainUntil,
+	}
+}
+
+// WriteZIP writes the export as a ZIP archive with one JSON file per section:
+// manifest.json (version, export time, data categories, any locale and any watermark), document.json,
+// redaction_schema.json, entities.json and timeline.json. A watermark is also set as the
+// comment of the archive.
+//
+// Parameters:
+//   - w: The writer receiving the archive
+//
+// Returns:
+//   - An error if a section could not be encoded or written
+func (de *DocumentExport) WriteZIP(w io.Writer) error {
+	archive := zip.NewWriter(w)
+
+	manifest := map[string]interface{}{"version": de.Version, "exported_at": de.ExportedAt, "data_categories": de.DataCategories}
+	if de.Locale != LocaleISO {
+		manifest["locale"] = de.Locale
+		manifest["exported_on"] = de.ExportedOn
+	}
+	if de.Watermark != "" {
+		manifest["watermark"] = de.Watermark
+		if err := archive.SetComment(de.Watermark); err != nil {
+			return fmt.Errorf("failed to watermark export: %w", err)
+		}
+	}
+
+	sections := []struct {
+		name string
+		data interface{}
+	}{
+		{"manifest.json", manifest},
+		{"document.json", de.Document},
+		{"redaction_schema.json", de.RedactionSchema},
+		{"entities.json", de.Entities},
+		{"timeline.json", de.Timeline},
+	}
+	for _, section := range sections {
+		file, err := archive.CreateHeader(&zip.FileHeader{
+			Name:     section.name,
+			Method:   zip.Deflate,
+			Modified: de.ExportedAt,
+		})
+		if err != nil {
+			return fmt.Errorf("failed to add %s to export: %w", section.name, err)
+		}
+		encoder := json.NewEncoder(file)
+		encoder.SetIndent("", "  ")
+		if err := encoder.Encode(section.data); err != nil {
+			return fmt.Errorf("failed to encode %s: %w", section.name, err)
+		}
+	}
+
+	return archive.Close()
+}
@@ -0,0 +1,109 @@
+package models
+
+import (
+	"archive/zip"
+	"bytes"
+	"encoding/json"
+	"io"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/yasinhessnawi1/Hideme_Backend/internal/utils/gdprlog"
+)
+
+func TestNewDocumentExport(t *testing.T) {
+	exportedAt := time.Date(2026, 3, 1, 12, 0, 0, 0, time.UTC)
+	doc := &Document{ID: 4, UserID: 1, HashedDocumentName: "contract.pdf", Language: "nb", Folder: "Legal", RedactionSchema: `{"pages":[]}`}
+
+	export := NewDocumentExport(doc, RedactionMapping{Version: RedactionSchemaVersion}, nil, nil, exportedAt)
+
+	assert.Equal(t, DocumentExportVersion, export.Version)
+	assert.Equal(t, exportedAt, export.ExportedAt)
+	assert.Equal(t, int64(4), export.Document.ID)
+	assert.Equal(t, "contract.pdf", export.Document.Filename)
+	assert.Equal(t, "Legal", export.Document.Folder)
+	assert.NotNil(t, export.Document.Tags)
+	assert.NotNil(t, export.Entities)
+	assert.NotNil(t, export.Timeline)
+	assert.Equal(t, gdprlog.PersonalLog, export.DataCategories["document.filename"])
+	assert.Equal(t, gdprlog.SensitiveLog, export.DataCategories["redaction_schema.pages[].sensitive[].original_text"])
+	assert.Equal(t, gdprlog.SensitiveLog, export.DataCategories["entities[].entity_name"])
+
+	data, err := json.Marshal(export)
+	require.NoError(t, err)
+	assert.Contains(t, string(data), `"entities":[]`)
+	assert.Contains(t, string(data), `"document.filename":"personal"`)
+	assert.NotContains(t, string(data), "user_id")
+}
+
+func TestDocumentExport_Localize(t *testing.T) {
+	exportedAt := time.Date(2026, 3, 1, 12, 0, 0, 0, time.UTC)
+
+	export := NewDocumentExport(&Document{ID: 4}, RedactionMapping{}, nil, nil, exportedAt)
+	export.Localize(LocaleNorwegian)
+	assert.Equal(t, LocaleNorwegian, export.Locale)
+	assert.Equal(t, "01.03.2026", export.ExportedOn)
+
+	// ISO formats, the default, leave the export as it was
+	export = NewDocumentExport(&Document{ID: 4}, RedactionMapping{}, nil, nil, exportedAt)
+	export.Localize(LocaleISO)
+	data, err := json.Marshal(export)
+	require.NoError(t, err)
+	assert.NotContains(t, string(data), "locale")
+	assert.NotContains(t, string(data), "exported_on")
+}
+
+func TestDocumentExport_WriteZIP(t *testing.T) {
+	exportedAt := time.Date(2026, 3, 1, 12, 0, 0, 0, time.UTC)
+	entities := []*DetectedEntityWithMethod{
+		{DetectedEntity: DetectedEntity{ID: 9, DocumentID: 4, EntityName: "Ola Nordmann"}, MethodName: "Presidio"},
+	}
+	timeline := []*DocumentEvent{{Type: EventDocumentUploaded, Timestamp: exportedAt, Description: "Document uploaded"}}
+	export := NewDocumentExport(&Document{ID: 4, HashedDocumentName: "contract.pdf"}, RedactionMapping{}, entities, timeline, exportedAt)
+
+	var buf bytes.Buffer
+	require.NoError(t, export.WriteZIP(&buf))
+
+	archive, err := zip.NewReader(bytes.NewReader(buf.Bytes()), int64(buf.Len()))
+	require.NoError(t, err)
+
+	files := make(map[string]string)
+	for _, file := range archive.File {
+		reader, err := file.Open()
+		require.NoError(t, err)
+		content, err := io.ReadAll(reader)
+		require.NoError(t, err)
+		require.NoError(t, reader.Close())
+		files[file.Name] = string(content)
+	}
+
+	require.Len(t, files, 5)
+	assert.Contains(t, files["manifest.json"], `"version": 1`)
+	assert.Contains(t, files["manifest.json"], `"entities[].entity_name": "sensitive"`)
+	assert.Contains(t, files["document.json"], `"filename": "contract.pdf"`)
+	assert.Contains(t, files["entities.json"], `"method_name": "Presidio"`)
+	assert.Contains(t, files["timeline.json"], `"type": "uploaded"`)
+	assert.Contains(t, files, "redaction_schema.json")
+}
+
+func TestDocumentExport_WriteZIP_Watermark(t *testing.T) {
+	exportedAt := time.Date(2026, 3, 1, 12, 0, 0, 0, time.UTC)
+	export := NewDocumentExport(&Document{ID: 4, HashedDocumentName: "contract.pdf"}, RedactionMapping{}, nil, nil, exportedAt)
+	export.Watermark = "Exported for auditor Kari Nordmann"
+
+	var buf bytes.Buffer
+	require.NoError(t, export.WriteZIP(&buf))
+
+	archive, err := zip.NewReader(bytes.NewReader(buf.Bytes()), int64(buf.Len()))
+	require.NoError(t, err)
+	assert.Equal(t, export.Watermark, archive.Comment)
+
+	manifest, err := archive.Open("manifest.json")
+	require.NoError(t, err)
+	content, err := io.ReadAll(manifest)
+	require.NoError(t, err)
+	assert.Contains(t, string(content), `"watermark": "Exported for auditor Kari Nordmann"`)
+}
@@ -0,0 +1,64 @@
+// Package models provides data structures and operations for the HideMe application.
+// This file contains models for the language of processed documents, which the
+// detection service uses to choose language-specific detection models.
+package models
+
+import "strings"
+
+// Languages recognized by the backend's own language detection. Documents in other
+// languages can still be tagged by the client with their ISO 639-1 code.
+const (
+	// LanguageEnglish is English.
+	LanguageEnglish = "en"
+
+	// LanguageNorwegian is Norwegian Bokmål. The macrolanguage code "no" is mapped to it.
+	LanguageNorwegian = "nb"
+
+	// LanguageSwedish is Swedish.
+	LanguageSwedish = "sv"
+
+	// LanguageDanish is Danish.
+	LanguageDanish = "da"
+
+	// LanguageGerman is German.
+	LanguageGerman = "de"
+
+	// LanguageFrench is French.
+	LanguageFrench = "fr"
+
+	// LanguageSpanish is Spanish.
+	LanguageSpanish = "es"
+)
+
+// NormalizeLanguage reduces a language tag such as "nb-NO", "en_GB" or "NO" to the
+// lowercase primary language code stored on documents.
+//
+// Parameters:
+//   - tag: The language tag as sent by the client
+//
+// Returns:
+//   - The language code, or the empty string for an empty tag
+//   - false if the tag does not start with a two- or three-letter language code
+func NormalizeLanguage(tag string) (string, bool) {
+	tag = strings.ToLower(strings.TrimSpace(tag))
+	if tag == "" {
+		return "", true
+	}
+
+	if i := strings.IndexAny(tag, "-_"); i >= 0 {
+		tag = tag[:i]
+	}
+	if len(tag) < 2 || len(tag) > 3 {
+		return "", false
+	}
+	for _, r := range tag {
+		if r < 'a' || r > 'z' {
+			return "", false
+		}
+	}
+
+	if tag == "no" {
+		return LanguageNorwegian, true
+	}
+	return tag, true
+}
@@ -0,0 +1,36 @@
+package models
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNormalizeLanguage(t *testing.T) {
+	tests := []struct {
+		tag      string
+		expected string
+		valid    bool
+	}{
+		{tag: "", expected: "", valid: true},
+		{tag: "en", expected: "en", valid: true},
+		{tag: "nb-NO", expected: "nb", valid: true},
+		{tag: "NO", expected: "nb", valid: true},
+		{tag: "no_NO", expected: "nb", valid: true},
+		{tag: " sv-SE ", expected: "sv", valid: true},
+		{tag: "nn", expected: "nn", valid: true},
+		{tag: "fil", expected: "fil", valid: true},
+		{tag: "english", valid: false},
+		{tag: "e", valid: false},
+		{tag: "1a", valid: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.tag, func(t *testing.T) {
+			language, valid := NormalizeLanguage(tt.tag)
+
+			assert.Equal(t, tt.valid, valid)
+			assert.Equal(t, tt.expected, language)
+		})
+	}
+}
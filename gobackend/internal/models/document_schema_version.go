@@ -0,0 +1,44 @@
+// Package models provides data structures and operations for the HideMe application.
+// This file contains the version history of document redaction schemas, which keeps each
+// schema a save replaces so users can go back to an earlier redaction state.
+package models
+
+import (
+	"time"
+
+	"github.com/yasinhessnawi1/Hideme_Backend/internal/constants"
+)
+
+// DocumentSchemaVersion is a redaction schema a document had before it was replaced.
+// Versions are numbered per document from 1, oldest first.
+type DocumentSchemaVersion struct {
+	// ID is the unique identifier for this version
+	ID int64 `json:"-" db:"version_id"`
+
+	// DocumentID references the document the schema belonged to
+	DocumentID int64 `json:"document_id" db:"document_id"`
+
+	// Version is the number of the version within the document's history
+	Version int `json:"version" db:"version"`
+
+	// RedactionSchema is the replaced schema, encrypted like the schema of the document.
+	// It is not listed; rolling back restores it.
+	RedactionSchema string `json:"-" db:"redaction_schema" gdpr:"sensitive"`
+
+	// EntityCount is the number of detections in the schema
+	EntityCount int `json:"entity_count" db:"entity_count"`
+
+	// EntityTypes are the distinct entity types in the schema
+	EntityTypes []string `json:"entity_types" db:"entity_types"`
+
+	// ReplacedBy references the user whose save replaced the schema
+	ReplacedBy int64 `json:"replaced_by" db:"replaced_by"`
+
+	// ReplacedAt records when the schema was replaced
+	ReplacedAt time.Time `json:"replaced_at" db:"replaced_at"`
+}
+
+// TableName returns the database table name for the DocumentSchemaVersion model.
+func (v *DocumentSchemaVersion) TableName() string {
+	return constants.TableDocumentSchemaVersions
+}
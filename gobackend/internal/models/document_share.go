@@ -0,0 +1,97 @@
+// Package models provides data structures and operations for the HideMe application.
+// This file contains the shares that grant users access to documents of other users, so
+// teams can collaborate on the redaction of the same file.
+package models
+
+import (
+	"time"
+
+	"github.com/yasinhessnawi1/Hideme_Backend/internal/constants"
+)
+
+// DocumentShare grants a user access to a document of another user.
+type DocumentShare struct {
+	// ID is the unique identifier for this share
+	ID int64 `json:"id" db:"share_id"`
+
+	// DocumentID references the shared document
+	DocumentID int64 `json:"document_id" db:"document_id"`
+
+	// UserID references the user the document is shared with
+	UserID int64 `json:"user_id" db:"user_id"`
+
+	// Email is the email address of the user the document is shared with
+	Email string `json:"email,omitempty" db:"-"`
+
+	// GrantedBy references the owner who shared the document
+	GrantedBy int64 `json:"granted_by" db:"granted_by"`
+
+	// Permission is the access granted: read or redact
+	Permission string `json:"permission" db:"permission"`
+
+	// CreatedAt records when the document was shared
+	CreatedAt time.Time `json:"created_at" db:"created_at"`
+}
+
+// DocumentShareRequest represents the sharing of a document with another user.
+// Sharing a document with a user it is already shared with changes the permission.
+type DocumentShareRequest struct {
+	// Email is the email address of the account to share the document with
+	Email string `json:"email" validate:"required,email"`
+
+	// Permission is the access to grant: read or redact
+	Permission string `json:"permission" validate:"required,oneof=read redact"`
+}
+
+// RedactionSchemaUpdate represents a change to the redaction schema of a document.
+type RedactionSchemaUpdate struct {
+	// RedactionSchema replaces the redaction schema of the document
+	RedactionSchema RedactionMapping `json:"redaction_schema" validate:"required"`
+}
+
+// SharePermissionAllows reports whether a granted permission includes a needed one.
+// Redact access includes read access.
+//
+// Parameters:
+//   - granted: The permission of the share
+//   - needed: The permission the operation needs
+//
+// Returns:
+//   - true if the share allows the operation
+func SharePermissionAllows(granted, needed string) bool {
+	switch granted {
+	case constants.SharePermissionRedact:
+		return needed == constants.SharePermissionRedact || needed == constants.SharePermissionRead
+	case constants.SharePermissionRead:
+		return needed == constants.SharePermissionRead
+	default:
+		return false
+	}
+}
+
+// SharedDocument is a document another user shared with the requesting user.
+type SharedDocument struct {
+	// ID is the unique identifier of the document
+	ID int64 `json:"id"`
+
+	// HashedName is the original filename of the document
+	HashedName string `json:"hashed_name"`
+
+	// OwnerID references the user who owns the document
+	OwnerID int64 `json:"owner_id"`
+
+	// Permission is the access granted: read or redact
+	Permission string `json:"permission"`
+
+	// SharedAt records when the document was shared
+	SharedAt time.Time `json:"shared_at"`
+
+	// UploadTimestamp records when the document was initially uploaded
+	UploadTimestamp time.Time `json:"upload_timestamp"`
+
+	// LastModified records when the document was last modified
+	LastModified time.Time `json:"last_modified"`
+
+	// StorageTier is "hot" or "archive"; archived documents are read once the owner restores them
+	StorageTier string `json:"storage_tier"`
+}
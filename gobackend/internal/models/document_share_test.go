@@ -0,0 +1,30 @@
+package models
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/yasinhessnawi1/Hideme_Backend/internal/constants"
+)
+
+func TestSharePermissionAllows(t *testing.T) {
+	tests := []struct {
+		granted string
+		needed  string
+		want    bool
+	}{
+		{constants.SharePermissionRead, constants.SharePermissionRead, true},
+		{constants.SharePermissionRead, constants.SharePermissionRedact, false},
+		{constants.SharePermissionRedact, constants.SharePermissionRead, true},
+		{constants.SharePermissionRedact, constants.SharePermissionRedact, true},
+		{"", constants.SharePermissionRead, false},
+		{"owner", constants.SharePermissionRead, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.granted+"/"+tt.needed, func(t *testing.T) {
+			assert.Equal(t, tt.want, SharePermissionAllows(tt.granted, tt.needed))
+		})
+	}
+}
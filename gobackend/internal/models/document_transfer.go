@@ -0,0 +1,71 @@
+// Package models provides data structures and operations for the HideMe application.
+// This file contains the transfers of documents between users, which administrators use to
+// hand the documents of a leaving employee over to a colleague.
+package models
+
+import (
+	"time"
+)
+
+// DocumentTransferRequest represents an administrator transferring documents between users.
+type DocumentTransferRequest struct {
+	// FromUserID references the user who owns the documents
+	FromUserID int64 `json:"from_user_id" validate:"required,gt=0"`
+
+	// ToUserID references the user to transfer the documents to
+	ToUserID int64 `json:"to_user_id" validate:"required,gt=0"`
+
+	// DocumentIDs are the documents to transfer; all documents of the user are transferred when empty
+	DocumentIDs []int64 `json:"document_ids,omitempty" validate:"omitempty,max=1000,dive,gt=0"`
+
+	// Reason explains the transfer for the audit trail
+	Reason string `json:"reason" validate:"required,max=500"`
+}
+
+// DocumentTransferStatus describes whether all documents of a transfer have been handled.
+type DocumentTransferStatus string
+
+// Available document transfer statuses.
+const (
+	// TransferStatusPending indicates the documents are still being moved. A transfer that
+	// stays pending was interrupted; its document lists show how far it got.
+	TransferStatusPending DocumentTransferStatus = "pending"
+
+	// TransferStatusCompleted indicates every document was either moved or reported as failed.
+	TransferStatusCompleted DocumentTransferStatus = "completed"
+)
+
+// DocumentTransfer records a transfer of documents between users for the audit trail.
+// The record is created before the first document is moved and updated as each one is,
+// so the audit trail shows moved documents even if the transfer is interrupted.
+type DocumentTransfer struct {
+	// ID is the unique identifier for this transfer
+	ID int64 `json:"id" db:"transfer_id"`
+
+	// FromUserID references the user who owned the documents
+	FromUserID int64 `json:"from_user_id" db:"from_user_id"`
+
+	// ToUserID references the user who received the documents
+	ToUserID int64 `json:"to_user_id" db:"to_user_id"`
+
+	// TransferredBy references the administrator who transferred the documents
+	TransferredBy int64 `json:"transferred_by" db:"transferred_by"`
+
+	// Reason explains the transfer
+	Reason string `json:"reason" db:"reason"`
+
+	// DocumentIDs are the documents that were transferred
+	DocumentIDs []int64 `json:"document_ids" db:"document_ids"`
+
+	// FailedDocumentIDs are the documents that could not be transferred and still belong to the previous owner
+	FailedDocumentIDs []int64 `json:"failed_document_ids" db:"failed_document_ids"`
+
+	// Status tells whether all documents of the transfer have been handled
+	Status DocumentTransferStatus `json:"status" db:"status"`
+
+	// CreatedAt records when the transfer started
+	CreatedAt time.Time `json:"created_at" db:"created_at"`
+
+	// CompletedAt records when the last document was handled, nil while the transfer is pending
+	CompletedAt *time.Time `json:"completed_at,omitempty" db:"completed_at"`
+}
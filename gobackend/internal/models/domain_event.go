@@ -0,0 +1,73 @@
+// Package models provides data structures and operations for the HideMe application.
+// This file contains models for the log of domain changes and the derived tables, or
+// projections, rebuilt by replaying it.
+package models
+
+import (
+	"encoding/json"
+	"time"
+)
+
+// DomainEvent is a domain change recorded in the append-only event log.
+type DomainEvent struct {
+	// ID orders the events; they are replayed in this order
+	ID int64 `json:"id" db:"event_id"`
+
+	// Type is the kind of change, such as usage_counted
+	Type string `json:"type" db:"event_type"`
+
+	// UserID references the user the change belongs to
+	UserID int64 `json:"user_id" db:"user_id"`
+
+	// Payload is the JSON encoded change, one of the event types below
+	Payload json.RawMessage `json:"payload" db:"payload"`
+
+	// OccurredAt is when the change happened
+	OccurredAt time.Time `json:"occurred_at" db:"occurred_at"`
+}
+
+// UsageCountedEvent records the pages and API calls of a tenant added to a monthly usage rollup.
+type UsageCountedEvent struct {
+	// Month is the first day of the month the counters belong to
+	Month time.Time `json:"month"`
+
+	// PageCount is the number of pages processed
+	PageCount int64 `json:"page_count"`
+
+	// APICallCount is the number of API calls made
+	APICallCount int64 `json:"api_call_count"`
+}
+
+// RuleHitsRecordedEvent records the detections of a saved document counted towards the
+// user's ban list words and search patterns.
+type RuleHitsRecordedEvent struct {
+	// Hits are the detections per rule
+	Hits []*RuleHit `json:"hits"`
+}
+
+// Projection describes a derived table that can be rebuilt from the event log.
+type Projection struct {
+	// Name identifies the projection in the admin API
+	Name string `json:"name"`
+
+	// Description says what the projection holds
+	Description string `json:"description"`
+
+	// EventTypes are the events the projection is built from
+	EventTypes []string `json:"event_types"`
+}
+
+// ProjectionRebuild is the outcome of rebuilding a projection.
+type ProjectionRebuild struct {
+	// Projection is the name of the rebuilt projection
+	Projection string `json:"projection"`
+
+	// EventsReplayed is the number of events the projection was rebuilt from
+	EventsReplayed int64 `json:"events_replayed"`
+
+	// StartedAt is when the rebuild started
+	StartedAt time.Time `json:"started_at"`
+
+	// DurationMS is how long the rebuild took in milliseconds
+	DurationMS int64 `json:"duration_ms"`
+}
@@ -0,0 +1,130 @@
+// Package models provides data structures and operations for the HideMe application.
+// This file contains models for the cloud drive connectors, which let users import files
+// from Google Drive, OneDrive and SharePoint instead of downloading and uploading them again.
+package models
+
+import (
+	"io"
+	"time"
+
+	"github.com/yasinhessnawi1/Hideme_Backend/internal/constants"
+)
+
+// DriveConnection is a user's authorization of a cloud drive.
+// The tokens are stored encrypted with the tenant's data-encryption key and never returned to clients.
+type DriveConnection struct {
+	// UserID is the ID of the user who authorized the drive
+	UserID int64 `json:"-" db:"user_id"`
+
+	// Provider is the cloud drive, such as google_drive
+	Provider string `json:"provider" db:"provider"`
+
+	// AccessToken authorizes requests to the drive's API
+	AccessToken string `json:"-" db:"access_token" gdpr:"sensitive"`
+
+	// RefreshToken obtains a new access token once it expires; empty if the provider issued none
+	RefreshToken string `json:"-" db:"refresh_token" gdpr:"sensitive"`
+
+	// ExpiresAt is when the access token expires
+	ExpiresAt time.Time `json:"-" db:"expires_at"`
+
+	// CreatedAt is when the drive was first connected
+	CreatedAt time.Time `json:"created_at" db:"created_at"`
+
+	// UpdatedAt is when the tokens were last renewed
+	UpdatedAt time.Time `json:"updated_at" db:"updated_at"`
+}
+
+// TableName returns the database table name for the DriveConnection model.
+func (c *DriveConnection) TableName() string {
+	return constants.TableDriveConnections
+}
+
+// NeedsRefresh reports whether the access token expires within the margin and can be refreshed.
+//
+// Parameters:
+//   - now: The current time
+//   - margin: How long before expiry the token is refreshed
+//
+// Returns:
+//   - true if the access token should be refreshed before use
+func (c *DriveConnection) NeedsRefresh(now time.Time, margin time.Duration) bool {
+	return c.RefreshToken != "" && !now.Add(margin).Before(c.ExpiresAt)
+}
+
+// DriveProviderStatus tells a user whether a cloud drive can be used and is connected.
+type DriveProviderStatus struct {
+	// Provider is the cloud drive, such as google_drive
+	Provider string `json:"provider"`
+
+	// Configured tells whether this server has an OAuth client for the provider
+	Configured bool `json:"configured"`
+
+	// Connected tells whether the user has authorized the drive
+	Connected bool `json:"connected"`
+
+	// ConnectedAt is when the user first authorized the drive; nil if not connected
+	ConnectedAt *time.Time `json:"connected_at,omitempty"`
+}
+
+// DriveAuthorization is the start of a cloud drive authorization.
+type DriveAuthorization struct {
+	// AuthorizationURL is the provider's consent page the user is sent to
+	AuthorizationURL string `json:"authorization_url"`
+}
+
+// DriveConnectRequest completes the authorization of a cloud drive with the query parameters
+// the provider sent the user back to the redirect URL with.
+type DriveConnectRequest struct {
+	// Code is the authorization code issued by the provider
+	Code string `json:"code" validate:"required"`
+
+	// State is the state returned by the authorize endpoint, passed through by the provider
+	State string `json:"state" validate:"required"`
+}
+
+// DriveFile is a file or folder in a cloud drive.
+type DriveFile struct {
+	// ID identifies the file within the provider; folders are listed by passing it as folder
+	ID string `json:"id"`
+
+	// Name is the file name as shown in the drive
+	Name string `json:"name"`
+
+	// MimeType is the media type of the file
+	MimeType string `json:"mime_type,omitempty"`
+
+	// Size is the size in bytes; 0 for folders and native documents without a fixed size
+	Size int64 `json:"size"`
+
+	// IsFolder tells whether the entry is a folder, or a SharePoint site
+	IsFolder bool `json:"is_folder"`
+
+	// ModifiedAt is when the file was last changed; nil if the provider doesn't say
+	ModifiedAt *time.Time `json:"modified_at,omitempty"`
+}
+
+// DriveFileList is a page of a cloud drive folder.
+type DriveFileList struct {
+	// Files are the files and folders on this page
+	Files []*DriveFile `json:"files"`
+
+	// NextPageToken fetches the next page; empty on the last page
+	NextPageToken string `json:"next_page_token,omitempty"`
+}
+
+// DriveDownload is the content of a cloud drive file being imported.
+// The caller must close Body.
+type DriveDownload struct {
+	// Name is the file name, used for the imported document
+	Name string
+
+	// MimeType is the media type of the content
+	MimeType string
+
+	// Size is the size of the content in bytes; 0 if unknown
+	Size int64
+
+	// Body is the content of the file
+	Body io.ReadCloser
+}
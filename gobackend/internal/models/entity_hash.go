@@ -0,0 +1,113 @@
+// Package models provides data structures and operations for the HideMe application.
+// This file contains the salted hashes of detected entity values, which let analytics count
+// distinct values and find values leaking in several documents without storing the values.
+package models
+
+import (
+	"time"
+)
+
+// EntityHash is the salted hash of a value detected in a document.
+type EntityHash struct {
+	// ID is the unique identifier for this hash
+	ID int64 `json:"id" db:"hash_id"`
+
+	// UserID references the owner of the document
+	UserID int64 `json:"user_id" db:"user_id"`
+
+	// DocumentID references the document the value was detected in
+	DocumentID int64 `json:"document_id" db:"document_id"`
+
+	// EntityType is the type of the detected value, such as PERSON or EMAIL_ADDRESS
+	EntityType string `json:"entity_type" db:"entity_type"`
+
+	// ValueHash is the hex HMAC-SHA256 of the normalized value under the tenant's hashing key
+	ValueHash string `json:"-" db:"value_hash"`
+
+	// Occurrences is the number of times the value was detected in the document
+	Occurrences int `json:"occurrences" db:"occurrences"`
+
+	// CreatedAt records when the value was detected
+	CreatedAt time.Time `json:"created_at" db:"created_at"`
+}
+
+// EntityTypeCount counts the distinct values detected of an entity type.
+type EntityTypeCount struct {
+	// EntityType is the type of the detected values
+	EntityType string `json:"entity_type"`
+
+	// DistinctValues is the number of different values detected
+	DistinctValues int64 `json:"distinct_values"`
+
+	// Occurrences is the number of detections of these values
+	Occurrences int64 `json:"occurrences"`
+}
+
+// DuplicateLeak is a value that was detected in several documents.
+type DuplicateLeak struct {
+	// EntityType is the type of the detected value
+	EntityType string `json:"entity_type"`
+
+	// ValueHash is the full hash of the value; only its fingerprint is reported
+	ValueHash string `json:"-"`
+
+	// Fingerprint identifies the value in the report without revealing it
+	Fingerprint string `json:"fingerprint"`
+
+	// DocumentCount is the number of documents the value was detected in
+	DocumentCount int64 `json:"document_count"`
+
+	// Occurrences is the number of detections of the value in these documents
+	Occurrences int64 `json:"occurrences"`
+
+	// DocumentIDs are the documents the value was detected in
+	DocumentIDs []int64 `json:"document_ids"`
+
+	// FirstSeen records when the value was first detected
+	FirstSeen time.Time `json:"first_seen"`
+
+	// LastSeen records when the value was last detected
+	LastSeen time.Time `json:"last_seen"`
+}
+
+// DuplicateLeakReport lists the values that leaked in several documents of a user.
+type DuplicateLeakReport struct {
+	// MinDocuments is the number of documents a value must be detected in to be reported
+	MinDocuments int `json:"min_documents"`
+
+	// DistinctValues is the number of different values detected in all documents
+	DistinctValues int64 `json:"distinct_values"`
+
+	// EntityTypes counts the distinct values per entity type
+	EntityTypes []*EntityTypeCount `json:"entity_types"`
+
+	// Duplicates are the repeated values, the most widespread first
+	Duplicates []*DuplicateLeak `json:"duplicates"`
+}
+
+// LeakAlert records that a user was alerted about a value detected in many of their documents.
+type LeakAlert struct {
+	// ID is the unique identifier for this alert
+	ID int64 `json:"id" db:"alert_id"`
+
+	// UserID references the user who was alerted
+	UserID int64 `json:"user_id" db:"user_id"`
+
+	// EntityType is the type of the detected value
+	EntityType string `json:"entity_type" db:"entity_type"`
+
+	// ValueHash is the full hash of the value; only its fingerprint is reported
+	ValueHash string `json:"-" db:"value_hash"`
+
+	// Fingerprint identifies the value in the alert without revealing it
+	Fingerprint string `json:"fingerprint" db:"-"`
+
+	// DocumentCount is the number of documents the value was detected in within the alert window
+	DocumentCount int64 `json:"document_count" db:"document_count"`
+
+	// DocumentIDs are the documents the value was detected in within the alert window
+	DocumentIDs []int64 `json:"document_ids" db:"document_ids"`
+
+	// AlertedAt records when the user was alerted
+	AlertedAt time.Time `json:"alerted_at" db:"alerted_at"`
+}
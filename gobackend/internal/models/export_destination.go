@@ -0,0 +1,149 @@
+// Package models provides data structures and operations for the HideMe application.
+// This file contains models for export destinations, which redacted documents are
+// delivered to automatically once they are saved, and the tracking of those deliveries.
+package models
+
+import (
+	"time"
+)
+
+// ExportDestination is a place a user's redacted documents are delivered to.
+type ExportDestination struct {
+	// ID is the unique identifier for this destination
+	ID int64 `json:"id" db:"destination_id"`
+
+	// UserID references the user who owns this destination
+	UserID int64 `json:"-" db:"user_id"`
+
+	// Name is the name the user gave the destination, unique per user
+	Name string `json:"name" db:"name"`
+
+	// Type is s3, sftp or sharepoint
+	Type string `json:"type" db:"destination_type"`
+
+	// Config holds the connection settings; it is stored encrypted with the tenant's key
+	Config ExportDestinationConfig `json:"config" db:"config"`
+
+	// AutoDeliver tells whether documents are delivered as soon as they are saved
+	AutoDeliver bool `json:"auto_deliver" db:"auto_deliver"`
+
+	// CreatedAt records when this destination was created
+	CreatedAt time.Time `json:"created_at" db:"created_at"`
+
+	// UpdatedAt records when this destination was last changed
+	UpdatedAt time.Time `json:"updated_at" db:"updated_at"`
+}
+
+// ExportDestinationConfig holds the connection settings of an export destination.
+// Only the fields of the destination's type are used; credentials are never returned to clients.
+type ExportDestinationConfig struct {
+	// Bucket is the S3 bucket
+	Bucket string `json:"bucket,omitempty"`
+
+	// Region is the AWS region of the S3 bucket
+	Region string `json:"region,omitempty"`
+
+	// Endpoint is the HTTPS endpoint of an S3-compatible store; empty for Amazon S3
+	Endpoint string `json:"endpoint,omitempty"`
+
+	// AccessKeyID is the S3 access key ID
+	AccessKeyID string `json:"access_key_id,omitempty"`
+
+	// SecretAccessKey is the S3 secret access key
+	SecretAccessKey string `json:"secret_access_key,omitempty" gdpr:"sensitive"`
+
+	// Host is the SFTP server
+	Host string `json:"host,omitempty"`
+
+	// Port is the SFTP port; 0 for the default port 22
+	Port int `json:"port,omitempty"`
+
+	// Username is the SFTP user
+	Username string `json:"username,omitempty" gdpr:"personal"`
+
+	// Password is the SFTP password; empty when a private key is used
+	Password string `json:"password,omitempty" gdpr:"sensitive"`
+
+	// PrivateKey is the PEM-encoded SFTP private key; empty when a password is used
+	PrivateKey string `json:"private_key,omitempty" gdpr:"sensitive"`
+
+	// HostKey is the SFTP server's public key in authorized_keys format, which the server must present
+	HostKey string `json:"host_key,omitempty"`
+
+	// FolderID is the SharePoint folder, as listed by GET /api/drives/sharepoint/files
+	FolderID string `json:"folder_id,omitempty"`
+
+	// Prefix is the S3 key prefix or the SFTP directory documents are written to
+	Prefix string `json:"prefix,omitempty"`
+}
+
+// Redacted returns a copy of the settings without the credentials, for returning to clients.
+//
+// Returns:
+//   - The settings without the secret access key, password and private key
+func (c ExportDestinationConfig) Redacted() ExportDestinationConfig {
+	c.SecretAccessKey = ""
+	c.Password = ""
+	c.PrivateKey = ""
+	return c
+}
+
+// ExportDestinationCreate is the request to create an export destination.
+type ExportDestinationCreate struct {
+	// Name is the name of the destination, unique per user
+	Name string `json:"name" validate:"required,max=100"`
+
+	// Type is s3, sftp or sharepoint
+	Type string `json:"type" validate:"required"`
+
+	// Config holds the connection settings of the type
+	Config ExportDestinationConfig `json:"config"`
+
+	// AutoDeliver delivers documents as soon as they are saved (default: true)
+	AutoDeliver *bool `json:"auto_deliver,omitempty"`
+}
+
+// ExportDelivery tracks the delivery of a document to an export destination.
+type ExportDelivery struct {
+	// ID is the unique identifier for this delivery
+	ID int64 `json:"id" db:"delivery_id"`
+
+	// DestinationID references the destination
+	DestinationID int64 `json:"destination_id" db:"destination_id"`
+
+	// DestinationName is the name of the destination
+	DestinationName string `json:"destination_name" db:"name"`
+
+	// UserID references the user who owns the destination
+	UserID int64 `json:"-" db:"user_id"`
+
+	// DocumentID references the delivered document
+	DocumentID int64 `json:"document_id" db:"document_id"`
+
+	// Status is pending, delivered or failed
+	Status string `json:"status" db:"status"`
+
+	// Attempts is the number of times delivery was attempted
+	Attempts int `json:"attempts" db:"attempts"`
+
+	// ObjectName is the name the document was written under at the destination
+	ObjectName string `json:"object_name,omitempty" db:"object_name"`
+
+	// LastError describes why the last attempt failed; empty once delivered
+	LastError string `json:"last_error,omitempty" db:"last_error"`
+
+	// CreatedAt records when the document was first delivered to the destination
+	CreatedAt time.Time `json:"created_at" db:"created_at"`
+
+	// UpdatedAt records when delivery was last attempted
+	UpdatedAt time.Time `json:"updated_at" db:"updated_at"`
+
+	// DeliveredAt records when the delivery succeeded; nil until then
+	DeliveredAt *time.Time `json:"delivered_at,omitempty" db:"delivered_at"`
+}
+
+// ExportDeliveryRequest is the request to deliver a document to a destination, or to retry a delivery.
+type ExportDeliveryRequest struct {
+	// DestinationID is the destination to deliver to
+	DestinationID int64 `json:"destination_id" validate:"required,gt=0"`
+}
@@ -0,0 +1,32 @@
+// Package models provides data structures and operations for the HideMe application.
+// This file registers the models whose fields carry gdpr struct tags, so the GDPR logger,
+// document exports, the processing register and the erasure of log entries classify
+// personal and sensitive data from the models themselves.
+package models
+
+import (
+	"github.com/yasinhessnawi1/Hideme_Backend/internal/utils/gdprlog"
+)
+
+// classifiedModels are the models with personal or sensitive fields.
+// A model gaining a gdpr-tagged field must be added here.
+var classifiedModels = []interface{}{
+	User{},
+	APIKey{},
+	Session{},
+	PasswordResetToken{},
+	Document{},
+	Sensitive{},
+	DetectedEntity{},
+	DocumentSchemaVersion{},
+	SearchPattern{},
+	BanListWord{},
+	IPBan{},
+	DriveConnection{},
+	UserIdentity{},
+	ExportDestinationConfig{},
+}
+
+func init() {
+	gdprlog.RegisterModels(classifiedModels...)
+}
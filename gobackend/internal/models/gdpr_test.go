@@ -0,0 +1,24 @@
+package models
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/yasinhessnawi1/Hideme_Backend/internal/utils/gdprlog"
+)
+
+func TestClassifiedModels(t *testing.T) {
+	for _, model := range classifiedModels {
+		classification := gdprlog.ClassifyModel(model)
+		assert.NotEmpty(t, classification.Fields, "%s is registered without gdpr tags", classification.Model)
+	}
+
+	category, ok := gdprlog.FieldCategory("password_hash")
+	assert.True(t, ok)
+	assert.Equal(t, gdprlog.SensitiveLog, category)
+
+	category, ok = gdprlog.FieldCategory("email")
+	assert.True(t, ok)
+	assert.Equal(t, gdprlog.PersonalLog, category)
+}
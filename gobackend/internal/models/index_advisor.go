@@ -0,0 +1,94 @@
+// Package models provides data structures and operations for the HideMe application.
+// This file contains models for the index advisor, which reports missing indexes
+// on the largest tables before they turn into request timeouts.
+package models
+
+import "time"
+
+// TableScanStats are the access statistics PostgreSQL keeps for a table.
+type TableScanStats struct {
+	// Table is the name of the table
+	Table string `json:"table"`
+
+	// LiveRows is the estimated number of rows in the table
+	LiveRows int64 `json:"live_rows"`
+
+	// SeqScans is the number of sequential scans since statistics were reset
+	SeqScans int64 `json:"seq_scans"`
+
+	// SeqRowsRead is the number of rows read by sequential scans
+	SeqRowsRead int64 `json:"seq_rows_read"`
+
+	// IndexScans is the number of index scans since statistics were reset
+	IndexScans int64 `json:"index_scans"`
+}
+
+// UnindexedForeignKey is a foreign key whose columns are not the leading columns of any index.
+// Joins and cascading deletes over such a key scan the whole table.
+type UnindexedForeignKey struct {
+	// Table is the table holding the foreign key
+	Table string `json:"table"`
+
+	// Constraint is the name of the foreign key constraint
+	Constraint string `json:"constraint"`
+
+	// Columns are the columns of the foreign key in order
+	Columns []string `json:"columns"`
+}
+
+// StatementStats are the statistics pg_stat_statements keeps for a normalized statement.
+type StatementStats struct {
+	// Query is the normalized statement text
+	Query string `json:"query"`
+
+	// Calls is the number of times the statement ran
+	Calls int64 `json:"calls"`
+
+	// TotalMs is the total execution time in milliseconds
+	TotalMs float64 `json:"total_ms"`
+
+	// MeanMs is the average execution time in milliseconds
+	MeanMs float64 `json:"mean_ms"`
+
+	// Rows is the total number of rows returned or affected
+	Rows int64 `json:"rows"`
+}
+
+// IndexSuggestion is a single recommendation of the index advisor.
+type IndexSuggestion struct {
+	// Table is the table the suggestion applies to
+	Table string `json:"table"`
+
+	// Severity is either "warning" or "info"
+	Severity string `json:"severity"`
+
+	// Reason explains what the heuristic observed
+	Reason string `json:"reason"`
+
+	// Statement is a suggested DDL statement, if the fix is known
+	Statement string `json:"statement,omitempty"`
+
+	// Query is the statement the suggestion is based on, if any
+	Query string `json:"query,omitempty"`
+}
+
+// IndexAdvisorReport is the result of one index advisor run.
+type IndexAdvisorReport struct {
+	// GeneratedAt is when the report was created
+	GeneratedAt time.Time `json:"generated_at"`
+
+	// Tables are the access statistics of the inspected tables
+	Tables []TableScanStats `json:"tables"`
+
+	// UnindexedForeignKeys are the foreign keys of the inspected tables without an index
+	UnindexedForeignKeys []UnindexedForeignKey `json:"unindexed_foreign_keys"`
+
+	// SlowStatements are the most expensive statements touching the inspected tables
+	SlowStatements []StatementStats `json:"slow_statements"`
+
+	// StatStatementsAvailable reports whether the pg_stat_statements extension is installed
+	StatStatementsAvailable bool `json:"stat_statements_available"`
+
+	// Suggestions are the recommendations derived from the statistics
+	Suggestions []IndexSuggestion `json:"suggestions"`
+}
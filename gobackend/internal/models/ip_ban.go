@@ -12,7 +12,7 @@ type IPBan struct {
 	ID int64 `json:"id" db:"ban_id"`
 
 	// IPAddress is the banned IP address or CIDR range
-	IPAddress string `json:"ip_address" db:"ip_address"`
+	IPAddress string `json:"ip_address" db:"ip_address" gdpr:"personal"`
 
 	// Reason provides context for why the IP was banned
 	Reason string `json:"reason" db:"reason"`
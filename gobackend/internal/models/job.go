@@ -0,0 +1,168 @@
+// Package models provides data structures and operations for the HideMe application.
+// This file contains the jobs that detect, redact and export documents in the background,
+// for documents too large to process within the timeout of a request.
+package models
+
+import (
+	"database/sql/driver"
+	"encoding/json"
+	"errors"
+	"time"
+)
+
+// Job is a detection, redaction or export of a document queued to run in the background.
+// A failed attempt is retried with a growing delay until the job runs out of attempts.
+type Job struct {
+	// ID is the unique identifier for this job
+	ID int64 `json:"id" db:"job_id"`
+
+	// UserID references the user who queued the job; it runs with their access
+	UserID int64 `json:"-" db:"user_id"`
+
+	// Kind is detection, redaction or export
+	Kind string `json:"kind" db:"kind"`
+
+	// DocumentID references the document the job processes
+	DocumentID int64 `json:"document_id" db:"document_id"`
+
+	// Status is pending, running, completed or failed
+	Status string `json:"status" db:"status"`
+
+	// Payload holds the parameters of the job, such as a JobRedactionPayload
+	Payload json.RawMessage `json:"-" db:"payload"`
+
+	// Context is the context of the request that queued the job, restored when it runs
+	Context JobContext `json:"-" db:"execution_context"`
+
+	// Attempts is the number of times the job was started
+	Attempts int `json:"attempts" db:"attempts"`
+
+	// MaxAttempts is the number of times the job is started before it fails for good
+	MaxAttempts int `json:"max_attempts" db:"max_attempts"`
+
+	// RunAfter is when a pending job may start; later than its creation after a failed attempt
+	RunAfter time.Time `json:"run_after" db:"run_after"`
+
+	// LeaseUntil is when the worker running the job must have finished it; after that another
+	// server takes the job over
+	LeaseUntil *time.Time `json:"-" db:"lease_until"`
+
+	// LastError describes why the last attempt failed; empty otherwise
+	LastError string `json:"last_error,omitempty" db:"last_error"`
+
+	// ResultType is the media type of the result; empty until the job completed
+	ResultType string `json:"result_type,omitempty" db:"result_type"`
+
+	// CreatedAt records when the job was queued
+	CreatedAt time.Time `json:"created_at" db:"created_at"`
+
+	// UpdatedAt records when the job last changed
+	UpdatedAt time.Time `json:"updated_at" db:"updated_at"`
+
+	// CompletedAt records when the result became available; nil until then
+	CompletedAt *time.Time `json:"completed_at,omitempty" db:"completed_at"`
+}
+
+// JobRequest is the request to queue a job processing a document.
+type JobRequest struct {
+	// Kind is detection, redaction or export
+	Kind string `json:"kind" validate:"required,oneof=detection redaction export"`
+
+	// DocumentID is the document to process
+	DocumentID int64 `json:"document_id" validate:"required,gt=0"`
+
+	// RedactionMethod is the redaction method of redactions whose entity has none, for
+	// redaction jobs; blackout if empty
+	RedactionMethod string `json:"redaction_method,omitempty" validate:"omitempty,oneof=blackout mask replace"`
+
+	// Entities are the detected entities to add, for detection jobs; at most
+	// constants.DetectedEntityBatchMax
+	Entities []DetectedEntityInput `json:"entities,omitempty" validate:"required_if=Kind detection,omitempty,max=1000,dive"`
+
+	// Locale is the regional formatting of the output of the job; ISO formats if empty
+	Locale Locale `json:"locale,omitempty" validate:"omitempty,oneof=en-US en-GB de-DE fr-FR es-ES it-IT nl-NL nb-NO sv-SE da-DK"`
+}
+
+// JobContext is the context of the request that queued a job. Jobs run long after their
+// request is gone, so what the request carried is kept with the job and restored when it
+// runs, for the job to behave as the request would have.
+type JobContext struct {
+	// RequestID identifies the request that queued the job; the log entries of the job carry it
+	RequestID string `json:"request_id,omitempty"`
+
+	// UserID is the caller who queued the job. Tenants are user accounts, so it is also the
+	// tenant whose data the job acts on
+	UserID int64 `json:"user_id,omitempty"`
+
+	// APIKey tells whether the caller authenticated with an API key rather than a session
+	APIKey bool `json:"api_key,omitempty"`
+
+	// APIKeyScopes are the scopes of the caller's API key; empty for a key with full access
+	APIKeyScopes []string `json:"api_key_scopes,omitempty"`
+
+	// Locale is the regional formatting of the output of the job; ISO formats if empty
+	Locale Locale `json:"locale,omitempty"`
+
+	// Features are the optional features the deployment offered when the job was queued,
+	// so a configuration change while it waits does not change its output
+	Features map[string]bool `json:"features,omitempty"`
+}
+
+// Value implements the driver.Valuer interface for JobContext.
+//
+// Returns:
+//   - The JSON encoding of the context
+//   - An error if JSON marshaling fails
+func (c JobContext) Value() (driver.Value, error) {
+	return json.Marshal(c)
+}
+
+// Scan implements the sql.Scanner interface for JobContext.
+//
+// Parameters:
+//   - value: The database value to scan (expected to be []byte or string)
+//
+// Returns:
+//   - An error if type assertion or JSON unmarshaling fails
+func (c *JobContext) Scan(value interface{}) error {
+	var data []byte
+	switch v := value.(type) {
+	case nil:
+		*c = JobContext{}
+		return nil
+	case []byte:
+		data = v
+	case string:
+		data = []byte(v)
+	default:
+		return errors.New("type assertion to []byte failed")
+	}
+
+	jobContext := JobContext{}
+	if err := json.Unmarshal(data, &jobContext); err != nil {
+		return err
+	}
+	*c = jobContext
+	return nil
+}
+
+// JobRedactionPayload holds the parameters of a redaction job.
+type JobRedactionPayload struct {
+	// Method is the redaction method of redactions whose entity has none
+	Method string `json:"method"`
+}
+
+// JobDetectionPayload holds the parameters of a detection job.
+type JobDetectionPayload struct {
+	// Entities are the detected entities to add
+	Entities []DetectedEntityInput `json:"entities"`
+}
+
+// JobResult is the output of a completed job.
+type JobResult struct {
+	// ContentType is the media type of the data, e.g. application/pdf for redaction jobs
+	ContentType string
+
+	// Data is the output: the redacted PDF, the exported record or the detection outcome
+	Data []byte
+}
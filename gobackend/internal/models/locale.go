@@ -0,0 +1,160 @@
+// Package models provides data structures and operations for the HideMe application.
+// This file contains models for the regional formatting of generated reports and exports,
+// covering dates, decimal separators and CSV delimiters.
+package models
+
+import (
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Locale identifies the regional formatting applied to a report or export.
+// The empty locale keeps the ISO formats reports have always used.
+type Locale string
+
+// Supported locales.
+const (
+	// LocaleISO uses ISO 8601 dates, a decimal point and commas between CSV fields.
+	LocaleISO Locale = ""
+
+	// LocaleEnglishUS is English as used in the United States.
+	LocaleEnglishUS Locale = "en-US"
+
+	// LocaleEnglishGB is English as used in the United Kingdom.
+	LocaleEnglishGB Locale = "en-GB"
+
+	// LocaleGerman is German as used in Germany.
+	LocaleGerman Locale = "de-DE"
+
+	// LocaleFrench is French as used in France.
+	LocaleFrench Locale = "fr-FR"
+
+	// LocaleSpanish is Spanish as used in Spain.
+	LocaleSpanish Locale = "es-ES"
+
+	// LocaleItalian is Italian as used in Italy.
+	LocaleItalian Locale = "it-IT"
+
+	// LocaleDutch is Dutch as used in the Netherlands.
+	LocaleDutch Locale = "nl-NL"
+
+	// LocaleNorwegian is Norwegian Bokmål as used in Norway.
+	LocaleNorwegian Locale = "nb-NO"
+
+	// LocaleSwedish is Swedish as used in Sweden.
+	LocaleSwedish Locale = "sv-SE"
+
+	// LocaleDanish is Danish as used in Denmark.
+	LocaleDanish Locale = "da-DK"
+)
+
+// CSVDelimiter selects the field separator of a CSV file independently of the locale.
+type CSVDelimiter string
+
+// Available CSV delimiters.
+const (
+	// CSVDelimiterComma separates fields with commas.
+	CSVDelimiterComma CSVDelimiter = "comma"
+
+	// CSVDelimiterSemicolon separates fields with semicolons, which spreadsheet
+	// applications expect in locales that use the comma as decimal separator.
+	CSVDelimiterSemicolon CSVDelimiter = "semicolon"
+)
+
+// Rune returns the separator character of the delimiter.
+//
+// Returns:
+//   - The separator, or 0 if the delimiter is empty or unknown
+func (d CSVDelimiter) Rune() rune {
+	switch d {
+	case CSVDelimiterComma:
+		return ','
+	case CSVDelimiterSemicolon:
+		return ';'
+	default:
+		return 0
+	}
+}
+
+// LocaleFormat contains the formatting rules of a locale.
+type LocaleFormat struct {
+	// DateLayout is the time layout for dates
+	DateLayout string
+
+	// MonthLayout is the time layout for months
+	MonthLayout string
+
+	// DecimalSeparator separates the integer part of a number from its fraction
+	DecimalSeparator string
+
+	// CSVDelimiter separates the fields of CSV files
+	CSVDelimiter rune
+}
+
+// localeFormats maps each supported locale to its formatting rules. Locales that use
+// the comma as decimal separator use semicolon-separated CSV files, since that is
+// what spreadsheet applications configured for them open correctly.
+var localeFormats = map[Locale]LocaleFormat{
+	LocaleISO:       {DateLayout: "2006-01-02", MonthLayout: "2006-01", DecimalSeparator: ".", CSVDelimiter: ','},
+	LocaleEnglishUS: {DateLayout: "01/02/2006", MonthLayout: "01/2006", DecimalSeparator: ".", CSVDelimiter: ','},
+	LocaleEnglishGB: {DateLayout: "02/01/2006", MonthLayout: "01/2006", DecimalSeparator: ".", CSVDelimiter: ','},
+	LocaleGerman:    {DateLayout: "02.01.2006", MonthLayout: "01.2006", DecimalSeparator: ",", CSVDelimiter: ';'},
+	LocaleFrench:    {DateLayout: "02/01/2006", MonthLayout: "01/2006", DecimalSeparator: ",", CSVDelimiter: ';'},
+	LocaleSpanish:   {DateLayout: "02/01/2006", MonthLayout: "01/2006", DecimalSeparator: ",", CSVDelimiter: ';'},
+	LocaleItalian:   {DateLayout: "02/01/2006", MonthLayout: "01/2006", DecimalSeparator: ",", CSVDelimiter: ';'},
+	LocaleDutch:     {DateLayout: "02-01-2006", MonthLayout: "01-2006", DecimalSeparator: ",", CSVDelimiter: ';'},
+	LocaleNorwegian: {DateLayout: "02.01.2006", MonthLayout: "01.2006", DecimalSeparator: ",", CSVDelimiter: ';'},
+	LocaleSwedish:   {DateLayout: "2006-01-02", MonthLayout: "2006-01", DecimalSeparator: ",", CSVDelimiter: ';'},
+	LocaleDanish:    {DateLayout: "02-01-2006", MonthLayout: "01-2006", DecimalSeparator: ",", CSVDelimiter: ';'},
+}
+
+// IsSupported reports whether the locale has formatting rules.
+//
+// Returns:
+//   - true for the supported locales and the empty (ISO) locale
+func (l Locale) IsSupported() bool {
+	_, ok := localeFormats[l]
+	return ok
+}
+
+// NewLocaleFormat returns the formatting rules of a locale, optionally with a different CSV delimiter.
+// Unsupported locales fall back to the ISO formats.
+//
+// Parameters:
+//   - locale: The locale to format for
+//   - delimiter: The CSV delimiter to use instead of the locale's; empty to keep it
+//
+// Returns:
+//   - The formatting rules
+func NewLocaleFormat(locale Locale, delimiter CSVDelimiter) LocaleFormat {
+	format, ok := localeFormats[locale]
+	if !ok {
+		format = localeFormats[LocaleISO]
+	}
+	if r := delimiter.Rune(); r != 0 {
+		format.CSVDelimiter = r
+	}
+	return format
+}
+
+// FormatDate formats the date of t.
+func (lf LocaleFormat) FormatDate(t time.Time) string {
+	return t.Format(lf.DateLayout)
+}
+
+// FormatMonth formats the month of t.
+func (lf LocaleFormat) FormatMonth(t time.Time) string {
+	return t.Format(lf.MonthLayout)
+}
+
+// FormatInt formats a whole number. No digit grouping is applied, so spreadsheet
+// applications read the value as a number.
+func (lf LocaleFormat) FormatInt(n int64) string {
+	return strconv.FormatInt(n, 10)
+}
+
+// FormatDecimal formats a fractional number with the given number of decimals.
+func (lf LocaleFormat) FormatDecimal(f float64, decimals int) string {
+	return strings.Replace(strconv.FormatFloat(f, 'f', decimals, 64), ".", lf.DecimalSeparator, 1)
+}
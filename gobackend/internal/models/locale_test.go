@@ -0,0 +1,47 @@
+package models
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestLocale_IsSupported(t *testing.T) {
+	assert.True(t, LocaleISO.IsSupported())
+	assert.True(t, LocaleNorwegian.IsSupported())
+	assert.False(t, Locale("xx-XX").IsSupported())
+}
+
+func TestNewLocaleFormat(t *testing.T) {
+	date := time.Date(2024, 5, 13, 0, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		name      string
+		locale    Locale
+		delimiter CSVDelimiter
+		date      string
+		month     string
+		decimal   string
+		comma     rune
+	}{
+		{name: "ISO", locale: LocaleISO, date: "2024-05-13", month: "2024-05", decimal: "1234.50", comma: ','},
+		{name: "United States", locale: LocaleEnglishUS, date: "05/13/2024", month: "05/2024", decimal: "1234.50", comma: ','},
+		{name: "Germany", locale: LocaleGerman, date: "13.05.2024", month: "05.2024", decimal: "1234,50", comma: ';'},
+		{name: "Germany with commas", locale: LocaleGerman, delimiter: CSVDelimiterComma, date: "13.05.2024", month: "05.2024", decimal: "1234,50", comma: ','},
+		{name: "United Kingdom with semicolons", locale: LocaleEnglishGB, delimiter: CSVDelimiterSemicolon, date: "13/05/2024", month: "05/2024", decimal: "1234.50", comma: ';'},
+		{name: "Unsupported locale", locale: Locale("xx-XX"), date: "2024-05-13", month: "2024-05", decimal: "1234.50", comma: ','},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			lf := NewLocaleFormat(tt.locale, tt.delimiter)
+
+			assert.Equal(t, tt.date, lf.FormatDate(date))
+			assert.Equal(t, tt.month, lf.FormatMonth(date))
+			assert.Equal(t, tt.decimal, lf.FormatDecimal(1234.5, 2))
+			assert.Equal(t, "1234567", lf.FormatInt(1234567))
+			assert.Equal(t, tt.comma, lf.CSVDelimiter)
+		})
+	}
+}
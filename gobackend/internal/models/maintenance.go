@@ -0,0 +1,96 @@
+// Package models provides data structures and operations for the HideMe application.
+// This file contains models for the maintenance tasks that run periodically and can
+// be triggered by administrators.
+package models
+
+import "time"
+
+// MaintenanceTask describes a registered maintenance task.
+type MaintenanceTask struct {
+	// Name identifies the task in the admin API
+	Name string `json:"name"`
+
+	// Description tells administrators what the task does
+	Description string `json:"description"`
+
+	// Batched is set for tasks that honor a batch size and dry runs
+	Batched bool `json:"batched"`
+
+	// Settings are the settings the task runs with
+	Settings MaintenanceTaskSettings `json:"settings"`
+
+	// LastRunAt is when the task last ran, periodically or on demand; nil if it has not run
+	// since the server started
+	LastRunAt *time.Time `json:"last_run_at,omitempty"`
+}
+
+// MaintenanceTaskSettings are the settings of a maintenance task. Tasks without stored
+// settings run with the defaults.
+type MaintenanceTaskSettings struct {
+	// Task is the name of the task
+	Task string `json:"-" db:"task_name"`
+
+	// Enabled is set when the task runs periodically; disabled tasks can still be run on demand
+	Enabled bool `json:"enabled" db:"enabled"`
+
+	// IntervalSeconds is the time between two periodic runs of the task
+	IntervalSeconds int `json:"interval_seconds" db:"interval_seconds"`
+
+	// BatchSize is the maximum number of items a batched task processes in one run; 0 for no limit
+	BatchSize int `json:"batch_size" db:"batch_size"`
+
+	// DryRun is set when a batched task counts the items it would process without changing them
+	DryRun bool `json:"dry_run" db:"dry_run"`
+
+	// UpdatedBy is the administrator who last changed the settings; nil for the defaults
+	UpdatedBy *int64 `json:"updated_by,omitempty" db:"updated_by"`
+
+	// UpdatedAt records when the settings were last changed; nil for the defaults
+	UpdatedAt *time.Time `json:"updated_at,omitempty" db:"updated_at"`
+}
+
+// Interval returns the time between two periodic runs of the task.
+//
+// Returns:
+//   - The interval between runs
+func (s *MaintenanceTaskSettings) Interval() time.Duration {
+	return time.Duration(s.IntervalSeconds) * time.Second
+}
+
+// MaintenanceTaskSettingsUpdate is the request to change the settings of a maintenance task.
+// Omitted fields keep their current value.
+type MaintenanceTaskSettingsUpdate struct {
+	// Enabled turns the periodic runs of the task on or off
+	Enabled *bool `json:"enabled"`
+
+	// IntervalSeconds is the time between two periodic runs, from a minute to 30 days
+	IntervalSeconds *int `json:"interval_seconds" validate:"omitempty,min=60,max=2592000"`
+
+	// BatchSize is the maximum number of items processed in one run; 0 removes the limit
+	BatchSize *int `json:"batch_size" validate:"omitempty,min=0,max=1000000"`
+
+	// DryRun makes the task count the items it would process without changing them
+	DryRun *bool `json:"dry_run"`
+}
+
+// MaintenanceRun is the outcome of running a maintenance task once.
+type MaintenanceRun struct {
+	// Task is the name of the task that ran
+	Task string `json:"task"`
+
+	// Count is the number of items the task processed, such as deleted sessions, or
+	// would have processed in a dry run
+	Count int64 `json:"count"`
+
+	// DryRun is set when the task only counted the items it would process
+	DryRun bool `json:"dry_run,omitempty"`
+
+	// StartedAt is when the task started
+	StartedAt time.Time `json:"started_at"`
+
+	// DurationMS is how long the task took in milliseconds
+	DurationMS int64 `json:"duration_ms"`
+
+	// Error is the error message if the task failed
+	Error string `json:"error,omitempty"`
+}
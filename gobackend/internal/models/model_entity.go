@@ -23,7 +23,7 @@ type ModelEntity struct {
 
 	// EntityText contains the actual text to be detected by the specified method
 	// This might include names, terms, or patterns specific to the user's needs
-	EntityText string `json:"entity_text" db:"entity_text"`
+	EntityText string `json:"entity_text" db:"entity_text" redact:"auditor"`
 }
 
 // TableName returns the database table name for the ModelEntity model.
@@ -74,6 +74,26 @@ type ModelEntityBatch struct {
 	EntityTexts []string `json:"entity_texts" validate:"required,min=1,dive,required"`
 }
 
+// ModelEntityReplace represents a request to replace all model entities of a detection
+// method with a new list, as sync clients hold the full list rather than its changes.
+type ModelEntityReplace struct {
+	// EntityTexts contains the texts to be detected by the method after the replacement;
+	// an empty list removes all entities of the method, a missing one is rejected
+	EntityTexts []string `json:"entity_texts" validate:"required,max=10000,dive,required"`
+}
+
+// ModelEntityReplacement is the outcome of replacing the model entities of a detection method.
+type ModelEntityReplacement struct {
+	// Entities are the entities of the method after the replacement, in the order requested
+	Entities []*ModelEntity `json:"entities"`
+
+	// Created are the entities added by the replacement
+	Created []*ModelEntity `json:"created"`
+
+	// Deleted are the entities removed by the replacement
+	Deleted []*ModelEntity `json:"deleted"`
+}
+
 // ModelEntityDelete represents a request to delete specific model entities.
 // This structure validates delete operations to ensure proper request format.
 type ModelEntityDelete struct {
@@ -0,0 +1,31 @@
+// Package models provides data structures and operations for the HideMe application.
+// This file contains the notifications streamed to logged-in users, so clients learn about
+// job progress and settings changes without polling.
+package models
+
+import (
+	"encoding/json"
+	"time"
+)
+
+// Notification is an event streamed to the clients of a user at /api/events.
+type Notification struct {
+	// Type is the kind of event, such as constants.NotificationJobUpdated; it is sent as the
+	// event name of the stream
+	Type string `json:"type"`
+
+	// UserID references the user the notification is streamed to
+	UserID int64 `json:"-"`
+
+	// Data is the payload of the event, such as the updated job
+	Data json.RawMessage `json:"data"`
+
+	// CreatedAt records when the event happened
+	CreatedAt time.Time `json:"created_at"`
+}
+
+// SettingsChangedNotification is the payload of a settings_changed notification.
+type SettingsChangedNotification struct {
+	// LatestRevision is the newest revision of the user's settings
+	LatestRevision int64 `json:"latest_revision"`
+}
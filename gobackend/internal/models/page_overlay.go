@@ -0,0 +1,84 @@
+// Package models provides data structures and operations for the HideMe application.
+// This file contains the overlay of a single page, the render-friendly form of its
+// redactions that viewers draw over the page without parsing the full redaction schema.
+package models
+
+import (
+	"github.com/yasinhessnawi1/Hideme_Backend/internal/constants"
+)
+
+// OverlayRect is a single redaction rectangle on a page. Coordinates are page-relative,
+// from 0 at the top-left corner to 1 at the bottom-right corner.
+type OverlayRect struct {
+	// X and Y are the top-left corner of the rectangle
+	X float64 `json:"x"`
+	Y float64 `json:"y"`
+
+	// Width and Height are the size of the rectangle
+	Width  float64 `json:"width"`
+	Height float64 `json:"height"`
+
+	// Label is the entity type of the redaction, such as "PERSON"
+	Label string `json:"label"`
+
+	// Color is the highlight color of the detection method that found the redaction
+	Color string `json:"color"`
+
+	// Method is the name of the detection method; empty if it is unknown
+	Method string `json:"method,omitempty"`
+}
+
+// PageOverlay holds the redaction rectangles of a single page of a document.
+// It leaves out the detected texts, so it can be drawn without handling them.
+type PageOverlay struct {
+	// DocumentID is the unique identifier of the document
+	DocumentID int64 `json:"document_id"`
+
+	// Page is the page number, starting at 1
+	Page int `json:"page"`
+
+	// Width and Height are the page size in points; zero if unknown
+	Width  float64 `json:"width"`
+	Height float64 `json:"height"`
+
+	// Rects lists the redaction rectangles of the page
+	Rects []OverlayRect `json:"rects"`
+}
+
+// NewPageOverlay creates the overlay of a page of a normalized redaction schema.
+//
+// Parameters:
+//   - documentID: The ID of the document
+//   - page: The page of the redaction schema, with page-relative bounding boxes
+//   - methods: The detection methods of the document's entities, keyed by entity text
+//
+// Returns:
+//   - A new PageOverlay pointer; redactions whose text has no detection method are
+//     drawn in constants.DefaultOverlayColor
+func NewPageOverlay(documentID int64, page Page, methods map[string]*DetectedEntityWithMethod) *PageOverlay {
+	rects := make([]OverlayRect, 0, len(page.Sensitive))
+	for _, sensitive := range page.Sensitive {
+		rect := OverlayRect{
+			X:      sensitive.BBox.X0,
+			Y:      sensitive.BBox.Y0,
+			Width:  sensitive.BBox.X1 - sensitive.BBox.X0,
+			Height: sensitive.BBox.Y1 - sensitive.BBox.Y0,
+			Label:  sensitive.EntityType,
+			Color:  constants.DefaultOverlayColor,
+		}
+		if method, ok := methods[sensitive.OriginalText]; ok {
+			rect.Method = method.MethodName
+			if method.HighlightColor != "" {
+				rect.Color = method.HighlightColor
+			}
+		}
+		rects = append(rects, rect)
+	}
+	return &PageOverlay{
+		DocumentID: documentID,
+		Page:       page.PageNumber,
+		Width:      page.Width,
+		Height:     page.Height,
+		Rects:      rects,
+	}
+}
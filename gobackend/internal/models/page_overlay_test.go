@@ -0,0 +1,52 @@
+package models
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/yasinhessnawi1/Hideme_Backend/internal/constants"
+)
+
+func TestNewPageOverlay(t *testing.T) {
+	page := Page{
+		PageNumber: 2,
+		Width:      595,
+		Height:     842,
+		Sensitive: []Sensitive{
+			{OriginalText: "Kari Nordmann", EntityType: "PERSON", BBox: BBox{X0: 0.1, Y0: 0.2, X1: 0.35, Y1: 0.25}},
+			{OriginalText: "Oslo", EntityType: "LOCATION", BBox: BBox{X0: 0.5, Y0: 0.5, X1: 0.6, Y1: 0.55}},
+		},
+	}
+	methods := map[string]*DetectedEntityWithMethod{
+		"Kari Nordmann": {MethodName: DetectionMethodMLModel1, HighlightColor: "#33FF57"},
+	}
+
+	overlay := NewPageOverlay(7, page, methods)
+
+	assert.Equal(t, int64(7), overlay.DocumentID)
+	assert.Equal(t, 2, overlay.Page)
+	assert.Equal(t, 595.0, overlay.Width)
+	require.Len(t, overlay.Rects, 2)
+
+	person := overlay.Rects[0]
+	assert.Equal(t, "PERSON", person.Label)
+	assert.Equal(t, "#33FF57", person.Color)
+	assert.Equal(t, DetectionMethodMLModel1, person.Method)
+	assert.InDelta(t, 0.1, person.X, 1e-9)
+	assert.InDelta(t, 0.2, person.Y, 1e-9)
+	assert.InDelta(t, 0.25, person.Width, 1e-9)
+	assert.InDelta(t, 0.05, person.Height, 1e-9)
+
+	location := overlay.Rects[1]
+	assert.Equal(t, "LOCATION", location.Label)
+	assert.Equal(t, constants.DefaultOverlayColor, location.Color)
+	assert.Empty(t, location.Method)
+
+	t.Run("Page without redactions", func(t *testing.T) {
+		overlay := NewPageOverlay(7, Page{PageNumber: 3}, nil)
+		assert.NotNil(t, overlay.Rects)
+		assert.Empty(t, overlay.Rects)
+	})
+}
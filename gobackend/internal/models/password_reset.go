@@ -2,16 +2,23 @@ package models
 
 import (
 	"time"
+
+	"github.com/yasinhessnawi1/Hideme_Backend/internal/constants"
 )
 
 // PasswordResetToken represents a password reset token in the database.
 type PasswordResetToken struct {
-	TokenHash string    `json:"-"` // The hashed token, not sent to client
+	TokenHash string    `json:"-" db:"token_hash" gdpr:"sensitive"` // The hashed token, not sent to client
 	UserID    int64     `json:"user_id"`
 	ExpiresAt time.Time `json:"expires_at"`
 	CreatedAt time.Time `json:"created_at"`
 }
 
+// TableName returns the database table name for the PasswordResetToken model.
+func (t *PasswordResetToken) TableName() string {
+	return constants.TablePasswordResetTokens
+}
+
 // PasswordResetRequest defines the structure for requesting a password reset.
 type ForgotPasswordRequest struct {
 	Email string `json:"email" validate:"required,email"`
@@ -0,0 +1,132 @@
+// Package models provides data structures and operations for the HideMe application.
+// This file contains the effective permissions of a caller, which the frontend reads to
+// render its affordances instead of probing endpoints and interpreting 403 responses.
+package models
+
+import (
+	"github.com/yasinhessnawi1/Hideme_Backend/internal/constants"
+)
+
+// permissionScopes lists every API key scope in the order they are reported.
+var permissionScopes = []string{
+	constants.APIKeyScopeSettingsRead,
+	constants.APIKeyScopeSettingsWrite,
+	constants.APIKeyScopeDocumentsRead,
+	constants.APIKeyScopeDocumentsWrite,
+	constants.APIKeyScopeAdmin,
+}
+
+// Permissions is the effective set of permissions applying to a caller.
+type Permissions struct {
+	// UserID is the unique identifier of the caller
+	UserID int64 `json:"user_id"`
+
+	// Role is the role of the caller, one of the constants.Role values
+	Role string `json:"role"`
+
+	// AuthMethod is how the caller authenticated, one of the constants.AuthMethod values
+	AuthMethod string `json:"auth_method"`
+
+	// Scopes are the constants.APIKeyScope values the caller may use; every scope its role
+	// allows for a session, and the scopes of the key, with implied read scopes, for an API key
+	Scopes []string `json:"scopes"`
+
+	// Capabilities are the constants.Capability values of the caller
+	Capabilities []string `json:"capabilities"`
+
+	// Policies are the limits administrators and the deployment set for all users
+	Policies PermissionPolicies `json:"policies"`
+
+	// Features tells for each constants.Feature value if the deployment offers it
+	Features map[string]bool `json:"features"`
+}
+
+// PermissionPolicies are the limits applying to all users.
+type PermissionPolicies struct {
+	// APIKeyMaxLifetimeDays is the longest lifetime of a new API key in days; 0 for no limit
+	APIKeyMaxLifetimeDays int `json:"api_key_max_lifetime_days"`
+
+	// MaxDocuments is the number of documents a user may store; 0 for no limit
+	MaxDocuments int64 `json:"max_documents"`
+
+	// MaxPages is the number of pages of one uploaded document; 0 for no limit
+	MaxPages int64 `json:"max_pages"`
+
+	// MaxUploadBytes is the size of one upload request in bytes; 0 for no limit
+	MaxUploadBytes int64 `json:"max_upload_bytes"`
+
+	// DocumentArchiveAfterDays is the number of days without a change after which a document
+	// is archived; 0 if documents are never archived
+	DocumentArchiveAfterDays int `json:"document_archive_after_days"`
+
+	// ApprovalRequiredActions lists the admin actions that need a second administrator's
+	// approval; only reported to callers with the admin capability
+	ApprovalRequiredActions []string `json:"approval_required_actions,omitempty"`
+}
+
+// NewPermissions creates the permissions a role and an authentication method give a caller,
+// without policies and features.
+//
+// Parameters:
+//   - userID: The ID of the caller
+//   - role: The role of the caller
+//   - apiKey: Whether the caller authenticated with an API key
+//   - keyScopes: The scopes of the API key; empty for a key with full access
+//
+// Returns:
+//   - A new Permissions pointer
+func NewPermissions(userID int64, role string, apiKey bool, keyScopes []string) *Permissions {
+	authMethod := constants.AuthMethodSession
+	if apiKey {
+		authMethod = constants.AuthMethodAPIKey
+	} else {
+		// Sessions are not limited by scopes
+		keyScopes = nil
+	}
+
+	scopes := make([]string, 0, len(permissionScopes))
+	for _, scope := range permissionScopes {
+		if scope == constants.APIKeyScopeAdmin && role != constants.RoleAdmin {
+			continue
+		}
+		if APIKeyScopesAllow(keyScopes, scope) {
+			scopes = append(scopes, scope)
+		}
+	}
+
+	capabilities := make([]string, 0, 3)
+	if role == constants.RoleAdmin && APIKeyScopesAllow(keyScopes, constants.APIKeyScopeAdmin) {
+		capabilities = append(capabilities, constants.CapabilityAdmin)
+	}
+	if role != constants.RoleAuditor {
+		capabilities = append(capabilities, constants.CapabilityViewDetectedText)
+	}
+	if role != constants.RoleSupport {
+		capabilities = append(capabilities, constants.CapabilityViewEmailAddresses)
+	}
+
+	return &Permissions{
+		UserID:       userID,
+		Role:         role,
+		AuthMethod:   authMethod,
+		Scopes:       scopes,
+		Capabilities: capabilities,
+		Features:     map[string]bool{},
+	}
+}
+
+// HasCapability checks if the caller has a capability.
+//
+// Parameters:
+//   - capability: One of the constants.Capability values
+//
+// Returns:
+//   - true if the caller has the capability
+func (p *Permissions) HasCapability(capability string) bool {
+	for _, c := range p.Capabilities {
+		if c == capability {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,91 @@
+package models_test
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/yasinhessnawi1/Hideme_Backend/internal/constants"
+	"github.com/yasinhessnawi1/Hideme_Backend/internal/models"
+)
+
+func TestNewPermissions(t *testing.T) {
+	tests := []struct {
+		name             string
+		role             string
+		apiKey           bool
+		keyScopes        []string
+		wantMethod       string
+		wantScopes       []string
+		wantCapabilities []string
+	}{
+		{
+			name:             "User session",
+			role:             constants.RoleUser,
+			wantMethod:       constants.AuthMethodSession,
+			wantScopes:       []string{"settings:read", "settings:write", "documents:read", "documents:write"},
+			wantCapabilities: []string{constants.CapabilityViewDetectedText, constants.CapabilityViewEmailAddresses},
+		},
+		{
+			name:             "Admin session",
+			role:             constants.RoleAdmin,
+			wantMethod:       constants.AuthMethodSession,
+			wantScopes:       []string{"settings:read", "settings:write", "documents:read", "documents:write", "admin"},
+			wantCapabilities: []string{constants.CapabilityAdmin, constants.CapabilityViewDetectedText, constants.CapabilityViewEmailAddresses},
+		},
+		{
+			name:             "Admin key without admin scope",
+			role:             constants.RoleAdmin,
+			apiKey:           true,
+			keyScopes:        []string{constants.APIKeyScopeDocumentsWrite},
+			wantMethod:       constants.AuthMethodAPIKey,
+			wantScopes:       []string{"documents:read", "documents:write"},
+			wantCapabilities: []string{constants.CapabilityViewDetectedText, constants.CapabilityViewEmailAddresses},
+		},
+		{
+			name:             "User key with admin scope",
+			role:             constants.RoleUser,
+			apiKey:           true,
+			keyScopes:        []string{constants.APIKeyScopeAdmin, constants.APIKeyScopeSettingsRead},
+			wantMethod:       constants.AuthMethodAPIKey,
+			wantScopes:       []string{"settings:read"},
+			wantCapabilities: []string{constants.CapabilityViewDetectedText, constants.CapabilityViewEmailAddresses},
+		},
+		{
+			name:             "Auditor key with full access",
+			role:             constants.RoleAuditor,
+			apiKey:           true,
+			wantMethod:       constants.AuthMethodAPIKey,
+			wantScopes:       []string{"settings:read", "settings:write", "documents:read", "documents:write"},
+			wantCapabilities: []string{constants.CapabilityViewEmailAddresses},
+		},
+		{
+			name:             "Support session",
+			role:             constants.RoleSupport,
+			wantMethod:       constants.AuthMethodSession,
+			wantScopes:       []string{"settings:read", "settings:write", "documents:read", "documents:write"},
+			wantCapabilities: []string{constants.CapabilityViewDetectedText},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			permissions := models.NewPermissions(7, tt.role, tt.apiKey, tt.keyScopes)
+
+			assert.Equal(t, int64(7), permissions.UserID)
+			assert.Equal(t, tt.role, permissions.Role)
+			assert.Equal(t, tt.wantMethod, permissions.AuthMethod)
+			assert.Equal(t, tt.wantScopes, permissions.Scopes)
+			assert.Equal(t, tt.wantCapabilities, permissions.Capabilities)
+			assert.NotNil(t, permissions.Features)
+		})
+	}
+}
+
+func TestPermissions_HasCapability(t *testing.T) {
+	permissions := models.NewPermissions(1, constants.RoleAuditor, false, nil)
+
+	assert.False(t, permissions.HasCapability(constants.CapabilityAdmin))
+	assert.False(t, permissions.HasCapability(constants.CapabilityViewDetectedText))
+	assert.True(t, permissions.HasCapability(constants.CapabilityViewEmailAddresses))
+}
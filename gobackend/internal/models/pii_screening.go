@@ -0,0 +1,39 @@
+// Package models provides data structures and operations for the HideMe application.
+// This file contains models for the screening of free-text fields, such as API key
+// names and admin notes, for personal data before they are stored.
+package models
+
+// PIIKind identifies the kind of personal data found in a free-text field.
+type PIIKind string
+
+// Available kinds of personal data.
+const (
+	// PIIEmail is an email address.
+	PIIEmail PIIKind = "email"
+
+	// PIIPhoneNumber is a phone number in international format.
+	PIIPhoneNumber PIIKind = "phone_number"
+
+	// PIIPaymentCard is a payment card number that passes the Luhn check.
+	PIIPaymentCard PIIKind = "payment_card"
+
+	// PIINationalID is a Norwegian national identity number with valid check digits.
+	PIINationalID PIIKind = "national_id"
+
+	// PIIIBAN is an international bank account number with a valid checksum.
+	PIIIBAN PIIKind = "iban"
+
+	// PIISearchPattern is a term from the user's own search patterns.
+	PIISearchPattern PIIKind = "search_pattern"
+)
+
+// PIIFinding records that a free-text field contains a kind of personal data.
+// The matched text itself is deliberately not part of a finding, so findings can be
+// logged and returned to the client without repeating the personal data.
+type PIIFinding struct {
+	// Field is the name of the screened field
+	Field string `json:"field"`
+
+	// Kind is the kind of personal data found
+	Kind PIIKind `json:"kind"`
+}
@@ -0,0 +1,58 @@
+// Package models provides data structures and operations for the HideMe application.
+// This file contains models for ephemeral processing, where a document is processed
+// without the server keeping any record of its content.
+package models
+
+import (
+	"time"
+)
+
+// ProcessingAudit is the only record kept of a document processed in ephemeral mode.
+// It holds no filename, text or detected entities, only counts.
+type ProcessingAudit struct {
+	// ID is the unique identifier for this audit stub
+	ID int64 `json:"id" db:"audit_id"`
+
+	// UserID references the user who processed the document
+	UserID int64 `json:"-" db:"user_id"`
+
+	// PageCount is the number of pages of the document
+	PageCount int `json:"page_count" db:"page_count"`
+
+	// EntityCount is the number of entities detected in the document
+	EntityCount int `json:"entity_count" db:"entity_count"`
+
+	// ProcessedAt records when the document was processed
+	ProcessedAt time.Time `json:"processed_at" db:"processed_at"`
+}
+
+// EphemeralDocument is a document processed in ephemeral mode, returned to the client
+// and then forgotten. It has no document ID, as there is no document to fetch later.
+type EphemeralDocument struct {
+	// ProcessingMode is always ephemeral
+	ProcessingMode string `json:"processing_mode"`
+
+	// AuditID identifies the audit stub recording the processing
+	AuditID int64 `json:"audit_id"`
+
+	// Filename is the filename sent by the client
+	Filename string `json:"filename"`
+
+	// RedactionSchema is the normalized redaction mapping as a JSON string
+	RedactionSchema string `json:"redaction_schema"`
+
+	// Language is the ISO 639-1 code of the document's language; empty if it could not be determined
+	Language string `json:"language"`
+
+	// Source is where the document came from; empty if unknown
+	Source string `json:"source"`
+
+	// Tags are the tags the user's classification rules assign to the document
+	Tags []string `json:"tags"`
+
+	// Folder is the folder the user's classification rules file the document in; empty for none
+	Folder string `json:"folder"`
+
+	// ProcessedAt records when the document was processed
+	ProcessedAt time.Time `json:"processed_at"`
+}
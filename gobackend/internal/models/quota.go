@@ -0,0 +1,45 @@
+// Package models provides data structures and operations for the HideMe application.
+// This file contains models for the detection quota, a token bucket per user that is
+// shared between the API and the detection service.
+package models
+
+// QuotaState describes a user's detection quota bucket.
+type QuotaState struct {
+	// UserID is the ID of the user owning the bucket
+	UserID int64 `json:"user_id"`
+
+	// Remaining is the number of tokens currently available
+	Remaining int64 `json:"remaining"`
+
+	// Capacity is the number of tokens in a full bucket
+	Capacity int64 `json:"capacity"`
+
+	// RefillSeconds is the time an empty bucket needs to refill completely
+	RefillSeconds int64 `json:"refill_seconds"`
+
+	// WarningThreshold is the highest warning threshold, in percent used, the user has reached; zero for none
+	WarningThreshold int `json:"warning_threshold,omitempty"`
+}
+
+// UsedPercent returns the share of the bucket that has been used, from 0 to 100.
+func (q *QuotaState) UsedPercent() int {
+	if q.Capacity <= 0 || q.Remaining >= q.Capacity {
+		return 0
+	}
+	if q.Remaining <= 0 {
+		return 100
+	}
+	return int((q.Capacity - q.Remaining) * 100 / q.Capacity)
+}
+
+// QuotaConsumeRequest represents a request to consume detection quota.
+type QuotaConsumeRequest struct {
+	// Units is the number of tokens to consume, typically the number of pages
+	Units int64 `json:"units" validate:"required,gt=0"`
+}
+
+// QuotaAdjustRequest represents an administrative quota adjustment.
+type QuotaAdjustRequest struct {
+	// Delta is the number of tokens to add (positive) or remove (negative)
+	Delta int64 `json:"delta" validate:"required"`
+}
@@ -0,0 +1,163 @@
+// Package models provides data structures and operations for the HideMe application.
+// This file contains models for the rate limits of the route groups, the consumption of
+// each client's bucket, and the temporary burst grants support gives to customers.
+package models
+
+import (
+	"strconv"
+	"time"
+)
+
+// Rate limit client types tell the buckets of IP addresses and users apart.
+const (
+	// RateLimitClientIP is the bucket of an IP address in a route group
+	RateLimitClientIP = "ip"
+
+	// RateLimitClientUser is the bucket of a user in a route group; requests with an API key
+	// count against the bucket of the key's user
+	RateLimitClientUser = "user"
+)
+
+// RateLimitGroup is the configured limit of a route group.
+type RateLimitGroup struct {
+	// Group is the name of the route group, such as "documents"
+	Group string `json:"group"`
+
+	// Rate is the number of requests per second allowed per IP address
+	Rate float64 `json:"rate"`
+
+	// Burst is the number of requests an IP address may send at once
+	Burst int `json:"burst"`
+
+	// UserRate is the number of requests per second allowed per user
+	UserRate float64 `json:"user_rate"`
+
+	// UserBurst is the number of requests a user may send at once
+	UserBurst int `json:"user_burst"`
+}
+
+// RateLimitBucket is the current consumption of one client in a route group.
+type RateLimitBucket struct {
+	// Group is the name of the route group
+	Group string `json:"group"`
+
+	// ClientType is RateLimitClientIP or RateLimitClientUser
+	ClientType string `json:"client_type"`
+
+	// ClientID is the IP address, or the ID of the user
+	ClientID string `json:"client_id"`
+
+	// Tokens is the number of requests the client may send right now
+	Tokens float64 `json:"tokens"`
+
+	// Burst is the number of requests the client may send at once, including a grant
+	Burst float64 `json:"burst"`
+
+	// Rate is the number of requests per second the client may send
+	Rate float64 `json:"rate"`
+
+	// UsedPercent is the share of the burst the client has used, from 0 to 100
+	UsedPercent int `json:"used_percent"`
+
+	// GrantBurst is the extra burst granted to the client; zero without a grant
+	GrantBurst int `json:"grant_burst,omitempty"`
+
+	// GrantExpiresAt is when the grant expires; nil without a grant
+	GrantExpiresAt *time.Time `json:"grant_expires_at,omitempty"`
+}
+
+// RateLimitFilter selects the buckets of a rate limit overview. Zero fields select all.
+type RateLimitFilter struct {
+	// Group selects the buckets of one route group
+	Group string
+
+	// UserID selects the buckets of one user
+	UserID int64
+
+	// IPAddress selects the buckets of one IP address
+	IPAddress string
+}
+
+// Matches reports whether the filter selects the bucket or grant of a client in a route group.
+//
+// Parameters:
+//   - group: The route group
+//   - clientType: RateLimitClientIP or RateLimitClientUser
+//   - clientID: The IP address, or the ID of the user
+//
+// Returns:
+//   - True if the filter selects the client
+func (f RateLimitFilter) Matches(group, clientType, clientID string) bool {
+	if f.Group != "" && f.Group != group {
+		return false
+	}
+	if f.UserID != 0 && (clientType != RateLimitClientUser || clientID != strconv.FormatInt(f.UserID, 10)) {
+		return false
+	}
+	if f.IPAddress != "" && (clientType != RateLimitClientIP || clientID != f.IPAddress) {
+		return false
+	}
+	return true
+}
+
+// RateLimitOverview is the rate limit dashboard: the configured limits, the consumption
+// of the clients seen recently and the grants in effect.
+type RateLimitOverview struct {
+	// Groups are the configured limits of the route groups
+	Groups []RateLimitGroup `json:"groups"`
+
+	// Buckets are the buckets of the clients, most used first
+	Buckets []RateLimitBucket `json:"buckets"`
+
+	// Grants are the unexpired burst grants, soonest expiring first
+	Grants []RateLimitGrant `json:"grants"`
+}
+
+// RateLimitGrantRequest is the request to grant a client a temporary burst in a route group,
+// for example for month-end processing. Exactly one of UserID and IPAddress is set.
+type RateLimitGrantRequest struct {
+	// Group is the route group the burst is granted in
+	Group string `json:"group" validate:"required"`
+
+	// UserID is the user the burst is granted to
+	UserID int64 `json:"user_id" validate:"required_without=IPAddress,excluded_with=IPAddress,omitempty,gt=0"`
+
+	// IPAddress is the IP address the burst is granted to
+	IPAddress string `json:"ip_address" validate:"required_without=UserID,omitempty,ip"`
+
+	// Burst is the number of requests the client may send at once on top of its limit
+	Burst int `json:"burst" validate:"required,min=1,max=100000"`
+
+	// DurationSeconds is how long the grant lasts, from a minute to 30 days
+	DurationSeconds int `json:"duration_seconds" validate:"required,min=60,max=2592000"`
+
+	// Reason tells other administrators why the burst was granted
+	Reason string `json:"reason" validate:"required,max=500"`
+}
+
+// RateLimitGrant is a temporary burst granted to a client in a route group.
+type RateLimitGrant struct {
+	// Group is the route group the burst is granted in
+	Group string `json:"group"`
+
+	// ClientType is RateLimitClientIP or RateLimitClientUser
+	ClientType string `json:"client_type"`
+
+	// ClientID is the IP address, or the ID of the user
+	ClientID string `json:"client_id"`
+
+	// Burst is the number of requests the client may send at once on top of its limit
+	Burst int `json:"burst"`
+
+	// Reason tells other administrators why the burst was granted
+	Reason string `json:"reason"`
+
+	// GrantedBy is the administrator who granted the burst
+	GrantedBy int64 `json:"granted_by"`
+
+	// GrantedAt is when the burst was granted
+	GrantedAt time.Time `json:"granted_at"`
+
+	// ExpiresAt is when the grant expires
+	ExpiresAt time.Time `json:"expires_at"`
+}
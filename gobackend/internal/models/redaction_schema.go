@@ -0,0 +1,177 @@
+// Package models provides data structures and operations for the HideMe application.
+// This file contains the coordinate systems of redaction schemas and the conversion
+// of bounding boxes between them.
+package models
+
+import (
+	"fmt"
+	"sort"
+
+	"github.com/yasinhessnawi1/Hideme_Backend/internal/constants"
+)
+
+// Redaction schema versions identify the coordinate system of the bounding boxes.
+const (
+	// RedactionSchemaVersionAbsolute has bounding boxes in points from the top-left
+	// corner of the page. Schemas stored before versioning use it.
+	RedactionSchemaVersionAbsolute = 1
+
+	// RedactionSchemaVersionNormalized has bounding boxes relative to the page size,
+	// from 0 at the top-left corner to 1 at the bottom-right corner.
+	RedactionSchemaVersionNormalized = 2
+
+	// RedactionSchemaVersion is the version schemas are stored in.
+	RedactionSchemaVersion = RedactionSchemaVersionNormalized
+)
+
+// Normalize converts an absolute bounding box to page-relative coordinates.
+// Coordinates are clamped to the page and ordered so that X0 <= X1 and Y0 <= Y1.
+//
+// Parameters:
+//   - width: The page width in points
+//   - height: The page height in points
+//
+// Returns:
+//   - The bounding box with coordinates from 0 to 1
+func (b BBox) Normalize(width, height float64) BBox {
+	return BBox{
+		X0: clampUnit(b.X0 / width),
+		Y0: clampUnit(b.Y0 / height),
+		X1: clampUnit(b.X1 / width),
+		Y1: clampUnit(b.Y1 / height),
+	}.ordered()
+}
+
+// Absolute converts a page-relative bounding box to points.
+//
+// Parameters:
+//   - width: The page width in points
+//   - height: The page height in points
+//
+// Returns:
+//   - The bounding box in points from the top-left corner of the page
+func (b BBox) Absolute(width, height float64) BBox {
+	return BBox{
+		X0: b.X0 * width,
+		Y0: b.Y0 * height,
+		X1: b.X1 * width,
+		Y1: b.Y1 * height,
+	}
+}
+
+// IsNormalized reports whether all coordinates of the bounding box are page-relative.
+func (b BBox) IsNormalized() bool {
+	for _, c := range []float64{b.X0, b.Y0, b.X1, b.Y1} {
+		if c < 0 || c > 1 {
+			return false
+		}
+	}
+	return true
+}
+
+// ordered swaps the corners of the bounding box where they are reversed.
+func (b BBox) ordered() BBox {
+	if b.X0 > b.X1 {
+		b.X0, b.X1 = b.X1, b.X0
+	}
+	if b.Y0 > b.Y1 {
+		b.Y0, b.Y1 = b.Y1, b.Y0
+	}
+	return b
+}
+
+// clampUnit limits a coordinate to the range from 0 to 1.
+func clampUnit(c float64) float64 {
+	return max(0, min(1, c))
+}
+
+// Normalize converts the bounding boxes of the schema to page-relative coordinates and
+// sets its version to RedactionSchemaVersion.
+//
+// Returns:
+//   - An error if the version is unknown, a page size is negative, or a schema that
+//     claims to be normalized has a bounding box outside the page
+//
+// Unversioned schemas whose bounding boxes are all within 0 and 1 are taken to be
+// normalized already; other unversioned schemas are converted like absolute ones. Absolute
+// bounding boxes are converted with the size of their page, or an A4 page in points if the
+// page size is unknown.
+func (m *RedactionMapping) Normalize() error {
+	if m.Version < 0 || m.Version > RedactionSchemaVersion {
+		return fmt.Errorf("unsupported redaction schema version %d", m.Version)
+	}
+	for _, page := range m.Pages {
+		if page.Width < 0 || page.Height < 0 {
+			return fmt.Errorf("page %d has a negative size", page.PageNumber)
+		}
+	}
+
+	switch {
+	case m.Version == RedactionSchemaVersionNormalized:
+		for _, page := range m.Pages {
+			for _, sensitive := range page.Sensitive {
+				if !sensitive.BBox.IsNormalized() {
+					return fmt.Errorf("bounding box on page %d is outside the page", page.PageNumber)
+				}
+			}
+		}
+	case m.Version == 0 && m.isNormalized():
+		// Sent by a client that already uses page-relative coordinates
+	default:
+		for i := range m.Pages {
+			page := &m.Pages[i]
+			width, height := page.Width, page.Height
+			if width == 0 || height == 0 {
+				width, height = constants.DefaultPageWidthPoints, constants.DefaultPageHeightPoints
+			}
+			for j := range page.Sensitive {
+				page.Sensitive[j].BBox = page.Sensitive[j].BBox.Normalize(width, height)
+			}
+		}
+	}
+
+	for i := range m.Pages {
+		for j := range m.Pages[i].Sensitive {
+			m.Pages[i].Sensitive[j].BBox = m.Pages[i].Sensitive[j].BBox.ordered()
+		}
+	}
+	m.Version = RedactionSchemaVersion
+	return nil
+}
+
+// isNormalized reports whether all bounding boxes of the schema are page-relative.
+func (m *RedactionMapping) isNormalized() bool {
+	for _, page := range m.Pages {
+		for _, sensitive := range page.Sensitive {
+			if !sensitive.BBox.IsNormalized() {
+				return false
+			}
+		}
+	}
+	return true
+}
+
+// EntityCount returns the number of detections in the schema.
+func (m RedactionMapping) EntityCount() int {
+	count := 0
+	for _, page := range m.Pages {
+		count += len(page.Sensitive)
+	}
+	return count
+}
+
+// EntityTypes returns the distinct entity types detected in the schema, sorted.
+func (m RedactionMapping) EntityTypes() []string {
+	seen := make(map[string]bool)
+	entityTypes := []string{}
+	for _, page := range m.Pages {
+		for _, sensitive := range page.Sensitive {
+			if sensitive.EntityType != "" && !seen[sensitive.EntityType] {
+				seen[sensitive.EntityType] = true
+				entityTypes = append(entityTypes, sensitive.EntityType)
+			}
+		}
+	}
+	sort.Strings(entityTypes)
+	return entityTypes
+}
@@ -0,0 +1,85 @@
+package models
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestBBox_Normalize(t *testing.T) {
+	box := BBox{X0: 300, Y0: 800, X1: 100, Y1: 900}.Normalize(600, 850)
+
+	assert.InDelta(t, 1.0/6, box.X0, 1e-9)
+	assert.InDelta(t, 0.5, box.X1, 1e-9)
+	assert.InDelta(t, 800.0/850, box.Y0, 1e-9)
+	assert.Equal(t, 1.0, box.Y1, "clamped to the page")
+}
+
+func TestBBox_Absolute(t *testing.T) {
+	box := BBox{X0: 0.25, Y0: 0.5, X1: 0.75, Y1: 1}.Absolute(600, 800)
+
+	assert.Equal(t, BBox{X0: 150, Y0: 400, X1: 450, Y1: 800}, box)
+}
+
+func TestRedactionMapping_Normalize(t *testing.T) {
+	t.Run("Legacy absolute coordinates with page size", func(t *testing.T) {
+		mapping := RedactionMapping{Pages: []Page{
+			{PageNumber: 1, Width: 600, Height: 800, Sensitive: []Sensitive{{BBox: BBox{X0: 60, Y0: 80, X1: 300, Y1: 400}}}},
+		}}
+
+		require.NoError(t, mapping.Normalize())
+		assert.Equal(t, RedactionSchemaVersion, mapping.Version)
+		assert.Equal(t, BBox{X0: 0.1, Y0: 0.1, X1: 0.5, Y1: 0.5}, mapping.Pages[0].Sensitive[0].BBox)
+	})
+
+	t.Run("Legacy absolute coordinates without page size", func(t *testing.T) {
+		mapping := RedactionMapping{Version: RedactionSchemaVersionAbsolute, Pages: []Page{
+			{PageNumber: 1, Sensitive: []Sensitive{{BBox: BBox{X0: 0, Y0: 0, X1: 595, Y1: 421}}}},
+		}}
+
+		require.NoError(t, mapping.Normalize())
+		assert.Equal(t, BBox{X0: 0, Y0: 0, X1: 1, Y1: 0.5}, mapping.Pages[0].Sensitive[0].BBox)
+	})
+
+	t.Run("Unversioned relative coordinates", func(t *testing.T) {
+		mapping := RedactionMapping{Pages: []Page{
+			{PageNumber: 1, Sensitive: []Sensitive{{BBox: BBox{X0: 0.2, Y0: 0.3, X1: 0.4, Y1: 0.5}}}},
+		}}
+
+		require.NoError(t, mapping.Normalize())
+		assert.Equal(t, RedactionSchemaVersion, mapping.Version)
+		assert.Equal(t, BBox{X0: 0.2, Y0: 0.3, X1: 0.4, Y1: 0.5}, mapping.Pages[0].Sensitive[0].BBox)
+	})
+
+	t.Run("Normalized box outside the page", func(t *testing.T) {
+		mapping := RedactionMapping{Version: RedactionSchemaVersionNormalized, Pages: []Page{
+			{PageNumber: 2, Sensitive: []Sensitive{{BBox: BBox{X0: 0.2, Y0: 0.3, X1: 40, Y1: 0.5}}}},
+		}}
+
+		assert.EqualError(t, mapping.Normalize(), "bounding box on page 2 is outside the page")
+	})
+
+	t.Run("Unknown version", func(t *testing.T) {
+		mapping := RedactionMapping{Version: RedactionSchemaVersion + 1}
+
+		assert.Error(t, mapping.Normalize())
+	})
+}
+
+func TestRedactionMapping_EntitySummary(t *testing.T) {
+	mapping := RedactionMapping{Pages: []Page{
+		{PageNumber: 1, Sensitive: []Sensitive{{EntityType: "PERSON"}, {EntityType: "EMAIL_ADDRESS"}}},
+		{PageNumber: 2, Sensitive: []Sensitive{{EntityType: "PERSON"}, {EntityType: ""}}},
+		{PageNumber: 3},
+	}}
+
+	assert.Equal(t, 4, mapping.EntityCount())
+	assert.Equal(t, []string{"EMAIL_ADDRESS", "PERSON"}, mapping.EntityTypes())
+
+	var doc Document
+	doc.SetEntitySummary(RedactionMapping{})
+	require.NotNil(t, doc.EntityCount)
+	assert.Equal(t, 0, *doc.EntityCount)
+	assert.Equal(t, []string{}, doc.EntityTypes)
+}
@@ -0,0 +1,127 @@
+// Package models provides data structures and operations for the HideMe application.
+// This file contains the email domains administrators limit registration to, so only
+// people with an address at the organization's own domains can create accounts.
+package models
+
+import (
+	"strings"
+	"time"
+
+	"github.com/yasinhessnawi1/Hideme_Backend/internal/constants"
+)
+
+// RegistrationDomain is an email domain registration is limited to. It only takes effect
+// once it is verified, so administrators can't lock sign-ups to a domain they don't own.
+type RegistrationDomain struct {
+	// ID is the unique identifier for this domain
+	ID int64 `json:"id" db:"domain_id"`
+
+	// Domain is the lower-case domain name, e.g. example.com
+	Domain string `json:"domain" db:"domain"`
+
+	// VerificationToken is the value to publish in the verification TXT record
+	VerificationToken string `json:"verification_token" db:"verification_token"`
+
+	// VerifiedAt records when the TXT record was found; nil until then
+	VerifiedAt *time.Time `json:"verified_at,omitempty" db:"verified_at"`
+
+	// CreatedBy references the administrator who added the domain
+	CreatedBy *int64 `json:"-" db:"created_by"`
+
+	// CreatedAt records when the domain was added
+	CreatedAt time.Time `json:"created_at" db:"created_at"`
+}
+
+// VerificationRecord returns the name of the DNS TXT record proving ownership of the domain.
+//
+// Returns:
+//   - The record name, e.g. _hideme-verification.example.com
+func (d *RegistrationDomain) VerificationRecord() string {
+	return constants.RegistrationDomainRecordPrefix + d.Domain
+}
+
+// VerificationValue returns the value the DNS TXT record must hold.
+//
+// Returns:
+//   - The record value, e.g. hideme-domain-verification=3f2a...
+func (d *RegistrationDomain) VerificationValue() string {
+	return constants.RegistrationDomainTokenPrefix + d.VerificationToken
+}
+
+// RegistrationDomainView is a registration domain with the DNS record that verifies it.
+type RegistrationDomainView struct {
+	*RegistrationDomain
+
+	// RecordName is the name of the TXT record to publish
+	RecordName string `json:"record_name"`
+
+	// RecordValue is the value of the TXT record to publish
+	RecordValue string `json:"record_value"`
+}
+
+// NewRegistrationDomainView adds the verification record to a registration domain.
+//
+// Parameters:
+//   - domain: The registration domain
+//
+// Returns:
+//   - A new RegistrationDomainView pointer
+func NewRegistrationDomainView(domain *RegistrationDomain) *RegistrationDomainView {
+	return &RegistrationDomainView{
+		RegistrationDomain: domain,
+		RecordName:         domain.VerificationRecord(),
+		RecordValue:        domain.VerificationValue(),
+	}
+}
+
+// RegistrationDomainCreate is the request to limit registration to a domain.
+type RegistrationDomainCreate struct {
+	// Domain is the domain name, e.g. example.com
+	Domain string `json:"domain" validate:"required,max=253"`
+}
+
+// NormalizeDomain lower-cases a domain name and checks that it is a valid, fully qualified one.
+//
+// Parameters:
+//   - domain: The domain name as entered, optionally with a trailing dot
+//
+// Returns:
+//   - The normalized domain name
+//   - Whether the domain name is valid
+func NormalizeDomain(domain string) (string, bool) {
+	domain = strings.TrimSuffix(strings.ToLower(strings.TrimSpace(domain)), ".")
+	if domain == "" || len(domain) > constants.RegistrationDomainMaxLength {
+		return "", false
+	}
+
+	labels := strings.Split(domain, ".")
+	if len(labels) < 2 {
+		return "", false
+	}
+	for _, label := range labels {
+		if len(label) == 0 || len(label) > 63 || label[0] == '-' || label[len(label)-1] == '-' {
+			return "", false
+		}
+		for _, r := range label {
+			if (r < 'a' || r > 'z') && (r < '0' || r > '9') && r != '-' {
+				return "", false
+			}
+		}
+	}
+	return domain, true
+}
+
+// EmailDomain returns the lower-case domain of an email address.
+//
+// Parameters:
+//   - email: The email address
+//
+// Returns:
+//   - The domain after the last @; empty if there is none
+func EmailDomain(email string) string {
+	at := strings.LastIndex(email, "@")
+	if at < 0 {
+		return ""
+	}
+	return strings.TrimSuffix(strings.ToLower(strings.TrimSpace(email[at+1:])), ".")
+}
@@ -0,0 +1,47 @@
+package models
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNormalizeDomain(t *testing.T) {
+	tests := []struct {
+		input string
+		want  string
+		valid bool
+	}{
+		{"example.com", "example.com", true},
+		{" Example.COM. ", "example.com", true},
+		{"mail.example.co.uk", "mail.example.co.uk", true},
+		{"xn--bcher-kva.example", "xn--bcher-kva.example", true},
+		{"localhost", "", false},
+		{"", "", false},
+		{"exa mple.com", "", false},
+		{"-example.com", "", false},
+		{"example..com", "", false},
+		{"@example.com", "", false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.input, func(t *testing.T) {
+			got, valid := NormalizeDomain(tt.input)
+			assert.Equal(t, tt.valid, valid)
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
+
+func TestEmailDomain(t *testing.T) {
+	assert.Equal(t, "example.com", EmailDomain("Ola@Example.com"))
+	assert.Equal(t, "example.com", EmailDomain("\"a@b\"@example.com"))
+	assert.Equal(t, "", EmailDomain("no-at-sign"))
+}
+
+func TestRegistrationDomainView(t *testing.T) {
+	view := NewRegistrationDomainView(&RegistrationDomain{Domain: "example.com", VerificationToken: "abc"})
+
+	assert.Equal(t, "_hideme-verification.example.com", view.RecordName)
+	assert.Equal(t, "hideme-domain-verification=abc", view.RecordValue)
+}
@@ -0,0 +1,225 @@
+// Package models provides data structures and operations for the HideMe application.
+// This file contains models for scheduled reports, which users subscribe to and
+// receive periodically by email with the report attached.
+package models
+
+import (
+	"time"
+
+	"github.com/yasinhessnawi1/Hideme_Backend/internal/constants"
+)
+
+// ReportType identifies a scheduled report.
+type ReportType string
+
+// Available report types.
+const (
+	// ReportWeeklyEntitySummary summarizes the entities detected in the user's documents during the previous week.
+	ReportWeeklyEntitySummary ReportType = "weekly_entity_summary"
+
+	// ReportMonthlyUsage contains the user's usage during the previous month.
+	ReportMonthlyUsage ReportType = "monthly_usage"
+)
+
+// ReportFormat identifies the file format of a report attachment.
+type ReportFormat string
+
+// Available report formats.
+const (
+	// ReportFormatCSV attaches the report as comma-separated values.
+	ReportFormatCSV ReportFormat = "csv"
+)
+
+// NextRun returns the first delivery time of the report after t.
+// Weekly reports are sent on Mondays, monthly reports on the first day of the month,
+// both at constants.ReportDeliveryHourUTC.
+//
+// Parameters:
+//   - t: The point in time after which the next delivery is wanted
+//
+// Returns:
+//   - The next delivery time in UTC
+func (rt ReportType) NextRun(t time.Time) time.Time {
+	t = t.UTC()
+	if rt == ReportMonthlyUsage {
+		next := time.Date(t.Year(), t.Month(), 1, constants.ReportDeliveryHourUTC, 0, 0, 0, time.UTC)
+		if !next.After(t) {
+			next = next.AddDate(0, 1, 0)
+		}
+		return next
+	}
+
+	daysUntilMonday := (int(time.Monday) - int(t.Weekday()) + 7) % 7
+	next := time.Date(t.Year(), t.Month(), t.Day()+daysUntilMonday, constants.ReportDeliveryHourUTC, 0, 0, 0, time.UTC)
+	if !next.After(t) {
+		next = next.AddDate(0, 0, 7)
+	}
+	return next
+}
+
+// Period returns the time range covered by a report delivered at runAt.
+// Weekly reports cover the seven days before the Monday of delivery, monthly
+// reports cover the previous calendar month.
+//
+// Parameters:
+//   - runAt: The delivery time of the report
+//
+// Returns:
+//   - The inclusive start of the period
+//   - The exclusive end of the period
+func (rt ReportType) Period(runAt time.Time) (time.Time, time.Time) {
+	runAt = runAt.UTC()
+	if rt == ReportMonthlyUsage {
+		end := UsageMonthOf(runAt)
+		return end.AddDate(0, -1, 0), end
+	}
+
+	end := time.Date(runAt.Year(), runAt.Month(), runAt.Day(), 0, 0, 0, 0, time.UTC)
+	return end.AddDate(0, 0, -7), end
+}
+
+// ReportSubscription represents a user's subscription to a scheduled report.
+type ReportSubscription struct {
+	// ID is the unique identifier for this subscription
+	ID int64 `json:"id" db:"subscription_id"`
+
+	// UserID references the user who receives the report
+	UserID int64 `json:"user_id" db:"user_id"`
+
+	// ReportType identifies the report
+	ReportType ReportType `json:"report_type" db:"report_type"`
+
+	// Format is the file format of the attached report
+	Format ReportFormat `json:"format" db:"format"`
+
+	// Locale selects the regional formatting of dates and numbers; empty for ISO formats
+	Locale Locale `json:"locale" db:"locale"`
+
+	// CSVDelimiter overrides the CSV delimiter of the locale; empty to keep it
+	CSVDelimiter CSVDelimiter `json:"csv_delimiter" db:"csv_delimiter"`
+
+	// NextRunAt is when the report is sent next
+	NextRunAt time.Time `json:"next_run_at" db:"next_run_at"`
+
+	// LastSentAt is when the report was last sent, if ever
+	LastSentAt *time.Time `json:"last_sent_at,omitempty" db:"last_sent_at"`
+
+	// CreatedAt records when the subscription was created
+	CreatedAt time.Time `json:"created_at" db:"created_at"`
+
+	// Email is the recipient's email address, joined in when reports are sent
+	Email string `json:"-" db:"email"`
+
+	// Username is the recipient's username, joined in when reports are sent
+	Username string `json:"-" db:"username"`
+}
+
+// NewReportSubscription creates a new ReportSubscription scheduled for the next delivery time.
+//
+// Parameters:
+//   - userID: The ID of the user who receives the report
+//   - reportType: The report to send
+//   - format: The file format of the attachment
+//   - locale: The regional formatting of dates and numbers
+//   - delimiter: The CSV delimiter to use instead of the locale's; empty to keep it
+//
+// Returns:
+//   - A new ReportSubscription pointer with the schedule and creation time initialized
+func NewReportSubscription(userID int64, reportType ReportType, format ReportFormat, locale Locale, delimiter CSVDelimiter) *ReportSubscription {
+	now := time.Now()
+	return &ReportSubscription{
+		UserID:       userID,
+		ReportType:   reportType,
+		Format:       format,
+		Locale:       locale,
+		CSVDelimiter: delimiter,
+		NextRunAt:    reportType.NextRun(now),
+		CreatedAt:    now,
+	}
+}
+
+// LocaleFormat returns the regional formatting of the subscription's reports.
+//
+// Returns:
+//   - The formatting rules of the locale, with the CSV delimiter override applied
+func (rs *ReportSubscription) LocaleFormat() LocaleFormat {
+	return NewLocaleFormat(rs.Locale, rs.CSVDelimiter)
+}
+
+// TableName returns the database table name for the ReportSubscription model.
+// This method is used by ORM frameworks to determine where to persist this entity.
+func (rs *ReportSubscription) TableName() string {
+	return constants.TableReportSubscriptions
+}
+
+// ReportSubscriptionCreate represents the data required to subscribe to a report.
+type ReportSubscriptionCreate struct {
+	// ReportType identifies the report
+	ReportType ReportType `json:"report_type" validate:"required,oneof=weekly_entity_summary monthly_usage"`
+
+	// Format is the file format of the attachment (default: csv)
+	Format ReportFormat `json:"format" validate:"omitempty,oneof=csv"`
+
+	// Locale selects the regional formatting of dates and numbers (default: ISO formats)
+	Locale Locale `json:"locale" validate:"omitempty,oneof=en-US en-GB de-DE fr-FR es-ES it-IT nl-NL nb-NO sv-SE da-DK"`
+
+	// CSVDelimiter overrides the CSV delimiter of the locale (default: the locale's delimiter)
+	CSVDelimiter CSVDelimiter `json:"csv_delimiter" validate:"omitempty,oneof=comma semicolon"`
+}
+
+// EntitySummary is the number of entities of one kind detected by one method during a report period.
+type EntitySummary struct {
+	// MethodName is the detection method that found the entities
+	MethodName string `json:"method_name"`
+
+	// EntityName is the kind of entity
+	EntityName string `json:"entity_name"`
+
+	// EntityCount is the number of entities detected
+	EntityCount int64 `json:"entity_count"`
+
+	// DocumentCount is the number of documents the entities were found in
+	DocumentCount int64 `json:"document_count"`
+}
+
+// EntitySummaryCSVHeader returns the column header row of the weekly entity summary.
+//
+// Returns:
+//   - The header row
+func EntitySummaryCSVHeader() []string {
+	return []string{"method", "entity", "entities", "documents"}
+}
+
+// CSVRecord returns the summary as a row matching EntitySummaryCSVHeader.
+//
+// Parameters:
+//   - lf: The regional formatting to apply
+//
+// Returns:
+//   - The row values as strings
+func (es *EntitySummary) CSVRecord(lf LocaleFormat) []string {
+	return []string{
+		es.MethodName,
+		es.EntityName,
+		lf.FormatInt(es.EntityCount),
+		lf.FormatInt(es.DocumentCount),
+	}
+}
+
+// Report is a generated report ready to be delivered.
+type Report struct {
+	// Subject is the subject line of the delivery email
+	Subject string
+
+	// Body is the plain text of the delivery email
+	Body string
+
+	// Filename is the name of the attachment
+	Filename string
+
+	// ContentType is the MIME type of the attachment
+	ContentType string
+
+	// Content is the attachment itself
+	Content []byte
+}
@@ -0,0 +1,87 @@
+package models
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/yasinhessnawi1/Hideme_Backend/internal/constants"
+)
+
+func TestReportType_NextRun(t *testing.T) {
+	tests := []struct {
+		name       string
+		reportType ReportType
+		from       time.Time
+		expected   time.Time
+	}{
+		{
+			name:       "Weekly midweek",
+			reportType: ReportWeeklyEntitySummary,
+			from:       time.Date(2024, 5, 15, 12, 0, 0, 0, time.UTC), // Wednesday
+			expected:   time.Date(2024, 5, 20, 6, 0, 0, 0, time.UTC),
+		},
+		{
+			name:       "Weekly Monday before delivery",
+			reportType: ReportWeeklyEntitySummary,
+			from:       time.Date(2024, 5, 13, 5, 0, 0, 0, time.UTC),
+			expected:   time.Date(2024, 5, 13, 6, 0, 0, 0, time.UTC),
+		},
+		{
+			name:       "Weekly Monday at delivery",
+			reportType: ReportWeeklyEntitySummary,
+			from:       time.Date(2024, 5, 13, 6, 0, 0, 0, time.UTC),
+			expected:   time.Date(2024, 5, 20, 6, 0, 0, 0, time.UTC),
+		},
+		{
+			name:       "Monthly",
+			reportType: ReportMonthlyUsage,
+			from:       time.Date(2024, 12, 15, 12, 0, 0, 0, time.UTC),
+			expected:   time.Date(2025, 1, 1, 6, 0, 0, 0, time.UTC),
+		},
+		{
+			name:       "Monthly first day before delivery",
+			reportType: ReportMonthlyUsage,
+			from:       time.Date(2024, 5, 1, 0, 0, 0, 0, time.UTC),
+			expected:   time.Date(2024, 5, 1, 6, 0, 0, 0, time.UTC),
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.expected, tt.reportType.NextRun(tt.from))
+		})
+	}
+}
+
+func TestReportType_Period(t *testing.T) {
+	from, to := ReportWeeklyEntitySummary.Period(time.Date(2024, 5, 13, 6, 0, 0, 0, time.UTC))
+	assert.Equal(t, time.Date(2024, 5, 6, 0, 0, 0, 0, time.UTC), from)
+	assert.Equal(t, time.Date(2024, 5, 13, 0, 0, 0, 0, time.UTC), to)
+
+	from, to = ReportMonthlyUsage.Period(time.Date(2024, 1, 1, 6, 0, 0, 0, time.UTC))
+	assert.Equal(t, time.Date(2023, 12, 1, 0, 0, 0, 0, time.UTC), from)
+	assert.Equal(t, time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC), to)
+}
+
+func TestNewReportSubscription(t *testing.T) {
+	subscription := NewReportSubscription(42, ReportMonthlyUsage, ReportFormatCSV, LocaleGerman, CSVDelimiterComma)
+
+	assert.Equal(t, int64(42), subscription.UserID)
+	assert.Equal(t, ReportMonthlyUsage, subscription.ReportType)
+	assert.Equal(t, ReportFormatCSV, subscription.Format)
+	assert.Equal(t, LocaleGerman, subscription.Locale)
+	assert.Equal(t, ',', subscription.LocaleFormat().CSVDelimiter)
+	assert.True(t, subscription.NextRunAt.After(subscription.CreatedAt))
+	assert.Equal(t, 1, subscription.NextRunAt.Day())
+	assert.Nil(t, subscription.LastSentAt)
+	assert.Equal(t, constants.TableReportSubscriptions, subscription.TableName())
+}
+
+func TestEntitySummary_CSVRecord(t *testing.T) {
+	summary := &EntitySummary{MethodName: "Presidio", EntityName: "PERSON", EntityCount: 12, DocumentCount: 3}
+
+	assert.Len(t, EntitySummaryCSVHeader(), 4)
+	assert.Equal(t, []string{"Presidio", "PERSON", "12", "3"}, summary.CSVRecord(NewLocaleFormat(LocaleISO, "")))
+}
@@ -0,0 +1,153 @@
+// Package models provides data structures and operations for the HideMe application.
+// This file contains models for the risk scoring of registrations and logins, which
+// holds back suspicious accounts until they pass an additional challenge.
+package models
+
+import (
+	"time"
+
+	"github.com/yasinhessnawi1/Hideme_Backend/internal/constants"
+)
+
+// RiskEvent identifies the authentication event being scored.
+type RiskEvent string
+
+// Available risk events.
+const (
+	// RiskEventRegistration is scored when a new account is created.
+	RiskEventRegistration RiskEvent = "registration"
+
+	// RiskEventLogin is scored when a user signs in.
+	RiskEventLogin RiskEvent = "login"
+)
+
+// RiskAction is the challenge a client must pass because of its risk score.
+// Actions are ordered from least to most restrictive.
+type RiskAction string
+
+// Available risk actions.
+const (
+	// RiskActionAllow lets the request through without any challenge.
+	RiskActionAllow RiskAction = "allow"
+
+	// RiskActionCaptcha requires the client to solve a CAPTCHA.
+	RiskActionCaptcha RiskAction = "captcha"
+
+	// RiskActionEmailVerification requires the user to verify their email address.
+	RiskActionEmailVerification RiskAction = "email_verification"
+
+	// RiskActionManualReview requires an administrator to review the account.
+	RiskActionManualReview RiskAction = "manual_review"
+)
+
+// RiskSignals contains the information about a request that risk scorers can inspect.
+type RiskSignals struct {
+	// Event is the authentication event being scored
+	Event RiskEvent
+
+	// UserID is the ID of the user, if known (zero during registration)
+	UserID int64
+
+	// Username is the username provided by the client
+	Username string
+
+	// Email is the email address provided by the client
+	Email string
+
+	// IPAddress is the client's IP address
+	IPAddress string
+
+	// UserAgent is the client's User-Agent header
+	UserAgent string
+}
+
+// RiskScore is the result of a single risk scorer.
+type RiskScore struct {
+	// Scorer is the name of the scorer that produced the score
+	Scorer string `json:"scorer"`
+
+	// Score is the amount of risk found, zero if nothing suspicious was detected
+	Score int `json:"score"`
+
+	// Reason explains why the score was given
+	Reason string `json:"reason,omitempty"`
+}
+
+// RiskAssessment is the combined result of all risk scorers for a request.
+type RiskAssessment struct {
+	// Score is the sum of all scores, capped at the maximum risk score
+	Score int `json:"score"`
+
+	// Action is the challenge the client must pass
+	Action RiskAction `json:"action"`
+
+	// Reasons lists why each non-zero score was given
+	Reasons []string `json:"reasons,omitempty"`
+}
+
+// AccountHold represents an account held back by risk scoring.
+// A held account cannot sign in until the hold is lifted, either by the user
+// verifying their email address or by an administrator releasing it.
+type AccountHold struct {
+	// UserID is the ID of the held user
+	UserID int64 `json:"user_id" db:"user_id"`
+
+	// HoldType is the challenge that lifts the hold
+	HoldType RiskAction `json:"hold_type" db:"hold_type"`
+
+	// RiskScore is the score that caused the hold
+	RiskScore int `json:"risk_score" db:"risk_score"`
+
+	// Reasons explains why the account was held
+	Reasons string `json:"reasons" db:"reasons"`
+
+	// TokenHash is the hash of the email verification token, if any
+	// This field is excluded from JSON serialization for security
+	TokenHash string `json:"-" db:"token_hash"`
+
+	// CreatedAt is when the hold was placed
+	CreatedAt time.Time `json:"created_at" db:"created_at"`
+
+	// ExpiresAt is when the email verification token stops being valid
+	ExpiresAt *time.Time `json:"expires_at,omitempty" db:"expires_at"`
+}
+
+// NewAccountHold creates a new AccountHold for a user.
+//
+// Parameters:
+//   - userID: The ID of the held user
+//   - holdType: The challenge that lifts the hold
+//   - riskScore: The score that caused the hold
+//   - reasons: Why the account was held
+//
+// Returns:
+//   - A new AccountHold pointer with the creation time initialized
+func NewAccountHold(userID int64, holdType RiskAction, riskScore int, reasons string) *AccountHold {
+	return &AccountHold{
+		UserID:    userID,
+		HoldType:  holdType,
+		RiskScore: riskScore,
+		Reasons:   reasons,
+		CreatedAt: time.Now(),
+	}
+}
+
+// TableName returns the database table name for the AccountHold model.
+// This method is used by ORM frameworks to determine where to persist this entity.
+func (h *AccountHold) TableName() string {
+	return constants.TableAccountHolds
+}
+
+// IsTokenExpired checks if the email verification token of the hold has expired.
+//
+// Returns:
+//   - true if the hold has a token expiry time that has passed, false otherwise
+func (h *AccountHold) IsTokenExpired() bool {
+	return h.ExpiresAt != nil && time.Now().After(*h.ExpiresAt)
+}
+
+// EmailVerificationRequest represents the data submitted to verify an email address.
+type EmailVerificationRequest struct {
+	// Token is the verification token sent by email
+	Token string `json:"token" validate:"required"`
+}
@@ -0,0 +1,62 @@
+package models
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/yasinhessnawi1/Hideme_Backend/internal/constants"
+)
+
+func TestNewAccountHold(t *testing.T) {
+	// Act
+	hold := NewAccountHold(42, RiskActionManualReview, 85, "IP address has a bad reputation")
+
+	// Assert
+	assert.Equal(t, int64(42), hold.UserID)
+	assert.Equal(t, RiskActionManualReview, hold.HoldType)
+	assert.Equal(t, 85, hold.RiskScore)
+	assert.Equal(t, "IP address has a bad reputation", hold.Reasons)
+	assert.Empty(t, hold.TokenHash)
+	assert.Nil(t, hold.ExpiresAt)
+	assert.False(t, hold.CreatedAt.IsZero())
+}
+
+func TestAccountHold_TableName(t *testing.T) {
+	hold := &AccountHold{}
+	assert.Equal(t, constants.TableAccountHolds, hold.TableName())
+}
+
+func TestAccountHold_IsTokenExpired(t *testing.T) {
+	past := time.Now().Add(-time.Minute)
+	future := time.Now().Add(time.Minute)
+
+	tests := []struct {
+		name     string
+		hold     *AccountHold
+		expected bool
+	}{
+		{
+			name:     "No token expiry",
+			hold:     &AccountHold{},
+			expected: false,
+		},
+		{
+			name:     "Token still valid",
+			hold:     &AccountHold{ExpiresAt: &future},
+			expected: false,
+		},
+		{
+			name:     "Token expired",
+			hold:     &AccountHold{ExpiresAt: &past},
+			expected: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.expected, tt.hold.IsTokenExpired())
+		})
+	}
+}
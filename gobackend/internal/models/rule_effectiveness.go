@@ -0,0 +1,72 @@
+// Package models provides data structures and operations for the HideMe application.
+// This file contains the effectiveness analytics of ban list words and search patterns:
+// how many detections each rule produced, when it last did, and which rules have gone
+// long enough without one to be worth removing.
+package models
+
+import (
+	"time"
+)
+
+// RuleHit counts the detections a ban list word or search pattern produced in a user's documents.
+type RuleHit struct {
+	// UserID references the user who owns the rule
+	UserID int64 `json:"-" db:"user_id"`
+
+	// RuleType is ban_list_word or search_pattern
+	RuleType string `json:"rule_type" db:"rule_type"`
+
+	// RuleText is the word or pattern text identifying the rule
+	RuleText string `json:"rule_text" db:"rule_text"`
+
+	// HitCount is the number of detected texts the rule matched
+	HitCount int64 `json:"hit_count" db:"hit_count"`
+
+	// LastHitAt is when a saved document last had a detection the rule matched; nil if none had
+	LastHitAt *time.Time `json:"last_hit_at,omitempty" db:"last_hit_at"`
+
+	// TrackedSince is when the rule was first checked against a saved document
+	TrackedSince time.Time `json:"tracked_since" db:"tracked_since"`
+}
+
+// RuleEffectiveness describes how effective one of the user's current rules is.
+type RuleEffectiveness struct {
+	// RuleType is ban_list_word or search_pattern
+	RuleType string `json:"rule_type"`
+
+	// Text is the word or pattern text
+	Text string `json:"text"`
+
+	// PatternID is the ID of the search pattern; zero for ban list words
+	PatternID int64 `json:"pattern_id,omitempty"`
+
+	// PatternType is the type of the search pattern; empty for ban list words
+	PatternType PatternType `json:"pattern_type,omitempty"`
+
+	// Tracked is false for AI search patterns, whose detections can't be told apart from others
+	Tracked bool `json:"tracked"`
+
+	// HitCount is the number of detected texts the rule matched
+	HitCount int64 `json:"hit_count"`
+
+	// LastHitAt is when a saved document last had a detection the rule matched
+	LastHitAt *time.Time `json:"last_hit_at,omitempty"`
+
+	// TrackedSince is when the rule was first checked against a saved document; nil if it hasn't been yet
+	TrackedSince *time.Time `json:"tracked_since,omitempty"`
+
+	// Stale tells whether the rule has gone without a detection for longer than the report's StaleAfterDays
+	Stale bool `json:"stale"`
+}
+
+// RuleEffectivenessReport ranks a user's ban list words and search patterns by the detections they produced.
+type RuleEffectivenessReport struct {
+	// StaleAfterDays is how many days a rule may go without a detection before it is suggested for removal
+	StaleAfterDays int `json:"stale_after_days"`
+
+	// Rules are all of the user's rules, most hits first, then most recent hit first
+	Rules []*RuleEffectiveness `json:"rules"`
+
+	// Suggestions are the stale rules, suggested for removal
+	Suggestions []*RuleEffectiveness `json:"suggestions"`
+}
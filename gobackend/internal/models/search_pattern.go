@@ -39,7 +39,7 @@ type SearchPattern struct {
 	PatternType PatternType `json:"pattern_type" db:"pattern_type"`
 
 	// PatternText contains the actual text or pattern to search for
-	PatternText string `json:"pattern_text" db:"pattern_text"`
+	PatternText string `json:"pattern_text" db:"pattern_text" gdpr:"personal"`
 }
 
 // TableName returns the database table name for the SearchPattern model.
@@ -114,3 +114,38 @@ type SearchPatternDelete struct {
 	// Must contain at least one valid ID
 	IDs []int64 `json:"ids" validate:"required,min=1,dive,required,min=1"`
 }
+
+// SearchPatternTest represents a request to try out a search pattern on sample text
+// before saving it.
+type SearchPatternTest struct {
+	// PatternType defines the search strategy to try
+	// AI search patterns are matched by the detection service and cannot be tried out
+	PatternType string `json:"pattern_type" validate:"required,oneof=ai_search normal case_sensitive"`
+
+	// PatternText contains the regular expression to try
+	PatternText string `json:"pattern_text" validate:"required,min=1"`
+
+	// SampleText is the text the pattern is tried on, at most 10000 characters
+	SampleText string `json:"sample_text" validate:"required,max=10000"`
+}
+
+// SearchPatternMatch is a match of a search pattern in sample text.
+type SearchPatternMatch struct {
+	// Text is the matched text
+	Text string `json:"text"`
+
+	// Start is the offset of the first matched character in the sample text, in characters
+	Start int `json:"start"`
+
+	// End is the offset just past the last matched character in the sample text, in characters
+	End int `json:"end"`
+}
+
+// SearchPatternTestResult is the outcome of trying out a search pattern on sample text.
+type SearchPatternTestResult struct {
+	// Matches are the matches of the pattern, in the order they appear in the sample text
+	Matches []SearchPatternMatch `json:"matches"`
+
+	// Truncated reports whether the pattern matched more often than the matches reported
+	Truncated bool `json:"truncated"`
+}
@@ -0,0 +1,147 @@
+// Package models provides data structures and operations for the HideMe application.
+// This file contains the catalog of search patterns for common kinds of personal data,
+// which users copy into their settings instead of writing the same patterns by hand.
+package models
+
+// SearchPatternCatalogEntry is a search pattern for a common kind of personal data.
+type SearchPatternCatalogEntry struct {
+	// Key identifies the pattern in the catalog; it doesn't change between releases
+	Key string `json:"key"`
+
+	// Name is the human-readable name of the kind of data the pattern finds
+	Name string `json:"name"`
+
+	// Description explains what the pattern matches
+	Description string `json:"description"`
+
+	// Region is the ISO 3166 country code of the country the data belongs to;
+	// empty for data used internationally
+	Region string `json:"region,omitempty"`
+
+	// PatternType is the search strategy of the pattern
+	PatternType PatternType `json:"pattern_type"`
+
+	// PatternText is the regular expression of the pattern
+	PatternText string `json:"pattern_text"`
+}
+
+// SearchPatternCatalog returns the patterns of the catalog, in the order they are listed.
+//
+// Returns:
+//   - The catalog patterns; the slice is a new copy on every call
+//
+// The patterns are written to find the data as it is usually formatted, with or without
+// separators, and to never nest unbounded repetitions, so they are accepted as search
+// patterns. Checksums, such as the control digits of a fødselsnummer, are not verified.
+func SearchPatternCatalog() []SearchPatternCatalogEntry {
+	return []SearchPatternCatalogEntry{
+		{
+			Key:         "no_fodselsnummer",
+			Name:        "Norwegian national identity number",
+			Description: "Fødselsnummer and D-number: 11 digits, optionally with a space after the date of birth",
+			Region:      "NO",
+			PatternType: CaseSensitive,
+			PatternText: `\b[0-7]\d[0-1]\d{3} ?\d{5}\b`,
+		},
+		{
+			Key:         "no_organisasjonsnummer",
+			Name:        "Norwegian organisation number",
+			Description: "Organisasjonsnummer: 9 digits starting with 8 or 9, optionally in groups of three",
+			Region:      "NO",
+			PatternType: CaseSensitive,
+			PatternText: `\b[89]\d{2} ?\d{3} ?\d{3}\b`,
+		},
+		{
+			Key:         "no_bank_account",
+			Name:        "Norwegian bank account number",
+			Description: "Kontonummer: 11 digits, optionally written as 1234.56.78901 or 1234 56 78901",
+			Region:      "NO",
+			PatternType: CaseSensitive,
+			PatternText: `\b\d{4}[ .]?\d{2}[ .]?\d{5}\b`,
+		},
+		{
+			Key:         "no_phone_number",
+			Name:        "Norwegian phone number",
+			Description: "8 digits starting with 2 to 9, optionally with +47 or 0047 and spaces or dashes between the digits",
+			Region:      "NO",
+			PatternType: CaseSensitive,
+			PatternText: `(?:\+47[ \-]?|\b0047[ \-]?|\b)[2-9]\d(?:[ \-]?\d){6}\b`,
+		},
+		{
+			Key:         "us_ssn",
+			Name:        "US Social Security number",
+			Description: "9 digits written as 123-45-6789",
+			Region:      "US",
+			PatternType: CaseSensitive,
+			PatternText: `\b\d{3}-\d{2}-\d{4}\b`,
+		},
+		{
+			Key:         "iban",
+			Name:        "International bank account number",
+			Description: "IBAN: a country code, 2 check digits and up to 30 letters or digits, optionally in groups of four",
+			PatternType: CaseSensitive,
+			PatternText: `\b[A-Z]{2}\d{2}(?: ?[A-Z0-9]){11,30}\b`,
+		},
+		{
+			Key:         "international_phone_number",
+			Name:        "International phone number",
+			Description: "A phone number starting with + or 00 and a country code",
+			PatternType: CaseSensitive,
+			PatternText: `(?:\+|\b00)\d{1,3}[ \-]?\d(?:[ \-]?\d){6,11}\b`,
+		},
+		{
+			Key:         "email_address",
+			Name:        "Email address",
+			Description: "An email address, in any letter case",
+			PatternType: Normal,
+			PatternText: `\b[a-z0-9._%+\-]+@[a-z0-9.\-]+\.[a-z]{2,}\b`,
+		},
+		{
+			Key:         "payment_card",
+			Name:        "Payment card number",
+			Description: "13 to 19 digits, optionally with spaces or dashes between them",
+			PatternType: CaseSensitive,
+			PatternText: `\b\d(?:[ \-]?\d){12,18}\b`,
+		},
+	}
+}
+
+// FindSearchPatternCatalogEntry returns the catalog pattern with a key.
+//
+// Parameters:
+//   - key: The key of the pattern
+//
+// Returns:
+//   - The catalog pattern
+//   - false if the catalog has no pattern with the key
+func FindSearchPatternCatalogEntry(key string) (SearchPatternCatalogEntry, bool) {
+	for _, entry := range SearchPatternCatalog() {
+		if entry.Key == key {
+			return entry, true
+		}
+	}
+	return SearchPatternCatalogEntry{}, false
+}
+
+// SearchPatternCatalogImport represents a request to copy catalog patterns into the
+// user's settings.
+type SearchPatternCatalogImport struct {
+	// Keys are the keys of the catalog patterns to copy
+	Keys []string `json:"keys" validate:"required,min=1,max=50"`
+}
+
+// SearchPatternImport is the outcome of copying one catalog pattern into a user's settings.
+type SearchPatternImport struct {
+	// Key is the key of the catalog pattern
+	Key string
+
+	// Pattern is the user's copy of the pattern; nil if it could not be copied
+	Pattern *SearchPattern
+
+	// Created reports whether the copy was created, rather than already being in the
+	// user's settings
+	Created bool
+
+	// Err is why the pattern could not be copied, such as a key not in the catalog
+	Err error
+}
@@ -23,13 +23,16 @@ type Session struct {
 
 	// JWTID stores the unique identifier of the JWT token associated with this session
 	// This enables tracking and revocation of specific tokens
-	JWTID string `json:"jwt_id" db:"jwt_id"`
+	JWTID string `json:"jwt_id" db:"jwt_id" gdpr:"sensitive"`
 
 	// ExpiresAt defines when this session will automatically expire
 	ExpiresAt time.Time `json:"expires_at" db:"expires_at"`
 
 	// CreatedAt records when this session was initiated
 	CreatedAt time.Time `json:"created_at" db:"created_at"`
+
+	// LastActivityAt records when the session was last used to refresh its tokens
+	LastActivityAt time.Time `json:"last_activity_at" db:"last_activity_at"`
 }
 
 // TableName returns the database table name for the Session model.
@@ -51,12 +54,26 @@ func (s *Session) TableName() string {
 // This function automatically sets the creation time and calculates the expiry time
 // based on the provided duration, ensuring consistent session lifecycle management.
 func NewSession(userID int64, jwtID string, expiryDuration time.Duration) *Session {
-	now := time.Now()
+	return NewSessionAt(userID, jwtID, expiryDuration, time.Now())
+}
+
+// NewSessionAt creates a new Session started at the given time.
+//
+// Parameters:
+//   - userID: The ID of the user who owns this session
+//   - jwtID: The unique identifier of the JWT token associated with this session
+//   - expiryDuration: How long this session should remain valid
+//   - now: The time the session starts, as told by the service's clock
+//
+// Returns:
+//   - A new Session pointer with all fields populated
+func NewSessionAt(userID int64, jwtID string, expiryDuration time.Duration, now time.Time) *Session {
 	return &Session{
-		UserID:    userID,
-		JWTID:     jwtID,
-		ExpiresAt: now.Add(expiryDuration),
-		CreatedAt: now,
+		UserID:         userID,
+		JWTID:          jwtID,
+		ExpiresAt:      now.Add(expiryDuration),
+		CreatedAt:      now,
+		LastActivityAt: now,
 	}
 }
 
@@ -68,7 +85,32 @@ func NewSession(userID int64, jwtID string, expiryDuration time.Duration) *Sessi
 // This method helps maintain security by ensuring expired sessions are not accepted,
 // preventing unauthorized access through outdated tokens.
 func (s *Session) IsExpired() bool {
-	return time.Now().After(s.ExpiresAt)
+	return s.IsExpiredAt(time.Now())
+}
+
+// IsExpiredAt checks if the session has expired at the given time.
+//
+// Parameters:
+//   - now: The current time
+//
+// Returns:
+//   - true if now is after the expiry time, false otherwise
+func (s *Session) IsExpiredAt(now time.Time) bool {
+	return now.After(s.ExpiresAt)
+}
+
+// IsIdle checks if the session has not been used for longer than the idle timeout.
+// Unlike the expiry, which is fixed when the session is created, the idle deadline
+// slides forward every time the session is used.
+//
+// Parameters:
+//   - now: The current time
+//   - idleTimeout: How long a session may go unused; 0 disables the idle timeout
+//
+// Returns:
+//   - true if the session was last used before the idle timeout, false otherwise
+func (s *Session) IsIdle(now time.Time, idleTimeout time.Duration) bool {
+	return idleTimeout > 0 && now.Sub(s.LastActivityAt) > idleTimeout
 }
 
 // ActiveSessionInfo represents summary information about an active session.
@@ -84,6 +126,9 @@ type ActiveSessionInfo struct {
 	// ExpiresAt defines when this session will automatically expire
 	ExpiresAt time.Time `json:"expires_at"`
 
+	// LastActivityAt records when the session was last used
+	LastActivityAt time.Time `json:"last_activity_at"`
+
 	// Device and location information could be added in the future to enhance
 	// security awareness and enable more informed session management
 }
@@ -0,0 +1,54 @@
+// Package models provides data structures and operations for the HideMe application.
+// This file contains the report of the consistency check of the settings object graph:
+// each user's settings row, its ban list, and the patterns, model entities and ban
+// list words hanging off them.
+package models
+
+// SettingsConsistencyReport counts the inconsistencies found in the settings object graph.
+// The first group of counts can be repaired automatically; the second group is only
+// reported, because repairing it would mean choosing which of the user's data to discard.
+type SettingsConsistencyReport struct {
+	// UsersWithoutSettings counts users without a settings row; repaired by creating default settings
+	UsersWithoutSettings int64 `json:"users_without_settings"`
+
+	// SettingsWithoutBanList counts settings rows without a ban list; repaired by creating an empty ban list
+	SettingsWithoutBanList int64 `json:"settings_without_ban_list"`
+
+	// OrphanedPatterns counts search patterns whose settings row is gone; repaired by deleting them
+	OrphanedPatterns int64 `json:"orphaned_patterns"`
+
+	// OrphanedModelEntities counts model entities whose settings row or detection method is gone; repaired by deleting them
+	OrphanedModelEntities int64 `json:"orphaned_model_entities"`
+
+	// OrphanedBanListWords counts ban list words whose ban list is gone; repaired by deleting them
+	OrphanedBanListWords int64 `json:"orphaned_ban_list_words"`
+
+	// DuplicateSettings counts users with more than one settings row
+	DuplicateSettings int64 `json:"duplicate_settings"`
+
+	// DuplicateBanLists counts settings rows with more than one ban list
+	DuplicateBanLists int64 `json:"duplicate_ban_lists"`
+
+	// DetectedEntitiesWithoutMethod counts detected entities whose detection method is gone
+	DetectedEntitiesWithoutMethod int64 `json:"detected_entities_without_method"`
+
+	// Repaired tells whether the repairable inconsistencies were repaired, or only counted
+	Repaired bool `json:"repaired"`
+}
+
+// Repairable returns the number of inconsistencies that can be repaired automatically.
+//
+// Returns:
+//   - The number of repairable inconsistencies
+func (r *SettingsConsistencyReport) Repairable() int64 {
+	return r.UsersWithoutSettings + r.SettingsWithoutBanList + r.OrphanedPatterns +
+		r.OrphanedModelEntities + r.OrphanedBanListWords
+}
+
+// Unrepairable returns the number of inconsistencies that need manual attention.
+//
+// Returns:
+//   - The number of inconsistencies that are only reported
+func (r *SettingsConsistencyReport) Unrepairable() int64 {
+	return r.DuplicateSettings + r.DuplicateBanLists + r.DetectedEntitiesWithoutMethod
+}
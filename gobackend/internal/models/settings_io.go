@@ -3,7 +3,32 @@
 // users to backup, transfer, and restore their personalized configurations.
 package models
 
-import "time"
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"time"
+)
+
+// Settings export versions identify the layout of an exported settings bundle.
+const (
+	// SettingsExportVersionUnversioned is the layout of exports made before versioning.
+	// They have no version field and no redaction placeholders.
+	SettingsExportVersionUnversioned = 1
+
+	// SettingsExportVersionPlaceholders adds the version field and the redaction
+	// placeholders of the general settings.
+	SettingsExportVersionPlaceholders = 2
+
+	// SettingsExportVersion is the version settings are exported in.
+	SettingsExportVersion = SettingsExportVersionPlaceholders
+)
+
+// settingsExportUpgrades converts the raw JSON of an export from the version it is keyed
+// by to the next version. Every version below SettingsExportVersion needs an entry.
+var settingsExportUpgrades = map[int]func(raw map[string]interface{}){
+	SettingsExportVersionUnversioned: upgradeUnversionedSettingsExport,
+}
 
 // SettingsExport represents the complete set of user settings for export/import.
 // This comprehensive structure captures all user-specific configuration elements
@@ -11,6 +36,9 @@ import "time"
 // The export/import feature allows users to maintain consistent configurations
 // and share standardized settings across teams or organizations.
 type SettingsExport struct {
+	// Version is the layout of the export, see SettingsExportVersion
+	Version int `json:"version"`
+
 	// UserID identifies the user who owns these settings
 	UserID int64 `json:"user_id"`
 
@@ -30,3 +58,96 @@ type SettingsExport struct {
 	// Each entity includes its associated detection method for completeness
 	ModelEntities []*ModelEntityWithMethod `json:"model_entities"`
 }
+
+// ParseSettingsExport reads an exported settings bundle of any supported version and
+// upgrades it to SettingsExportVersion.
+//
+// Parameters:
+//   - r: The JSON of the export
+//
+// Returns:
+//   - The export in the current layout
+//   - An error if the JSON is malformed or the export is newer than this server supports
+//
+// Exports without a version, or with version 0, were made before versioning.
+// The upgrades run on the raw JSON, one version at a time, so that each upgrade only
+// needs to know the layout of the version it starts from.
+func ParseSettingsExport(r io.Reader) (*SettingsExport, error) {
+	var raw map[string]interface{}
+	decoder := json.NewDecoder(r)
+	decoder.UseNumber()
+	if err := decoder.Decode(&raw); err != nil {
+		return nil, fmt.Errorf("invalid JSON format: %w", err)
+	}
+	if raw == nil {
+		return nil, fmt.Errorf("settings export must be a JSON object")
+	}
+
+	version, err := settingsExportVersion(raw)
+	if err != nil {
+		return nil, err
+	}
+	if version > SettingsExportVersion {
+		return nil, fmt.Errorf("settings export version %d is newer than the supported version %d; import it into an up-to-date installation", version, SettingsExportVersion)
+	}
+
+	for ; version < SettingsExportVersion; version++ {
+		settingsExportUpgrades[version](raw)
+	}
+	raw["version"] = SettingsExportVersion
+
+	data, err := json.Marshal(raw)
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode upgraded settings export: %w", err)
+	}
+
+	export := &SettingsExport{}
+	if err := json.Unmarshal(data, export); err != nil {
+		return nil, fmt.Errorf("invalid settings export: %w", err)
+	}
+
+	return export, nil
+}
+
+// settingsExportVersion returns the version of a raw export.
+func settingsExportVersion(raw map[string]interface{}) (int, error) {
+	value, ok := raw["version"]
+	if !ok || value == nil {
+		return SettingsExportVersionUnversioned, nil
+	}
+
+	number, ok := value.(json.Number)
+	if !ok {
+		return 0, fmt.Errorf("settings export version must be a number")
+	}
+	version, err := number.Int64()
+	if err != nil || version < 0 {
+		return 0, fmt.Errorf("invalid settings export version %s", number)
+	}
+	if version == 0 {
+		return SettingsExportVersionUnversioned, nil
+	}
+
+	return int(version), nil
+}
+
+// upgradeUnversionedSettingsExport converts an export made before versioning.
+// Redaction placeholders did not exist yet, so they are imported as cleared, and
+// sections that were left out of older exports are imported as empty.
+func upgradeUnversionedSettingsExport(raw map[string]interface{}) {
+	if general, ok := raw["general_settings"].(map[string]interface{}); ok {
+		if general["redaction_placeholders"] == nil {
+			general["redaction_placeholders"] = map[string]interface{}{}
+		}
+	}
+
+	if raw["ban_list"] == nil {
+		raw["ban_list"] = map[string]interface{}{"words": []interface{}{}}
+	}
+
+	for _, section := range []string{"search_patterns", "model_entities"} {
+		if raw[section] == nil {
+			raw[section] = []interface{}{}
+		}
+	}
+}
@@ -0,0 +1,91 @@
+package models
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseSettingsExport(t *testing.T) {
+	t.Run("Current version", func(t *testing.T) {
+		export, err := ParseSettingsExport(strings.NewReader(`{
+			"version": 2,
+			"user_id": 1001,
+			"general_settings": {"theme": "dark", "redaction_placeholders": {"EMAIL_ADDRESS": "[EMAIL]"}},
+			"ban_list": {"id": 3, "words": ["secret"]},
+			"search_patterns": [{"pattern_type": "normal", "pattern_text": "invoice"}],
+			"model_entities": []
+		}`))
+
+		require.NoError(t, err)
+		assert.Equal(t, SettingsExportVersion, export.Version)
+		assert.Equal(t, int64(1001), export.UserID)
+		assert.Equal(t, "[EMAIL]", export.GeneralSettings.RedactionPlaceholders["EMAIL_ADDRESS"])
+		assert.Equal(t, []string{"secret"}, export.BanList.Words)
+		require.Len(t, export.SearchPatterns, 1)
+		assert.Equal(t, Normal, export.SearchPatterns[0].PatternType)
+	})
+
+	t.Run("Unversioned export", func(t *testing.T) {
+		// Exports made before versioning have no version and no redaction placeholders,
+		// and may leave out sections that were empty
+		export, err := ParseSettingsExport(strings.NewReader(`{
+			"user_id": 1001,
+			"export_date": "2025-03-14T09:30:00Z",
+			"general_settings": {"theme": "light", "remove_images": true, "detection_threshold": 0.6},
+			"ban_list": null
+		}`))
+
+		require.NoError(t, err)
+		assert.Equal(t, SettingsExportVersion, export.Version)
+		assert.Equal(t, "light", export.GeneralSettings.Theme)
+		assert.Equal(t, 0.6, export.GeneralSettings.DetectionThreshold)
+		assert.NotNil(t, export.GeneralSettings.RedactionPlaceholders)
+		assert.Empty(t, export.GeneralSettings.RedactionPlaceholders)
+		require.NotNil(t, export.BanList)
+		assert.Empty(t, export.BanList.Words)
+		assert.NotNil(t, export.SearchPatterns)
+		assert.NotNil(t, export.ModelEntities)
+	})
+
+	t.Run("Version zero is unversioned", func(t *testing.T) {
+		export, err := ParseSettingsExport(strings.NewReader(`{"version": 0, "general_settings": {"theme": "dark"}}`))
+
+		require.NoError(t, err)
+		assert.Equal(t, SettingsExportVersion, export.Version)
+		assert.NotNil(t, export.GeneralSettings.RedactionPlaceholders)
+	})
+
+	t.Run("Newer version", func(t *testing.T) {
+		export, err := ParseSettingsExport(strings.NewReader(`{"version": 99, "general_settings": {"theme": "dark"}}`))
+
+		assert.Nil(t, export)
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "version 99 is newer than the supported version 2")
+	})
+
+	t.Run("Invalid version", func(t *testing.T) {
+		_, err := ParseSettingsExport(strings.NewReader(`{"version": "two"}`))
+		assert.Error(t, err)
+
+		_, err = ParseSettingsExport(strings.NewReader(`{"version": 1.5}`))
+		assert.Error(t, err)
+	})
+
+	t.Run("Invalid JSON", func(t *testing.T) {
+		_, err := ParseSettingsExport(strings.NewReader(`{"general_settings": invalid}`))
+		assert.Error(t, err)
+
+		_, err = ParseSettingsExport(strings.NewReader(`null`))
+		assert.Error(t, err)
+	})
+}
+
+func TestSettingsExportUpgrades(t *testing.T) {
+	// Every version below the current one must be upgradable
+	for version := SettingsExportVersionUnversioned; version < SettingsExportVersion; version++ {
+		assert.NotNil(t, settingsExportUpgrades[version], "Missing upgrade from settings export version %d", version)
+	}
+}
@@ -0,0 +1,131 @@
+// Package models provides data structures and operations for the HideMe application.
+// This file contains models for the settings revision history, which records every
+// change to a user's settings so that clients can synchronize incrementally.
+package models
+
+import (
+	"encoding/json"
+	"time"
+
+	"github.com/yasinhessnawi1/Hideme_Backend/internal/constants"
+)
+
+// SettingsResourceType identifies the kind of settings resource affected by a revision.
+type SettingsResourceType string
+
+// Available settings resource types recorded in the revision history.
+const (
+	// ResourceGeneralSettings identifies the user's general settings row.
+	ResourceGeneralSettings SettingsResourceType = "general_settings"
+
+	// ResourceBanList identifies the options of the user's ban list.
+	ResourceBanList SettingsResourceType = "ban_list"
+
+	// ResourceBanListWord identifies a single word in the user's ban list.
+	ResourceBanListWord SettingsResourceType = "ban_list_word"
+
+	// ResourceSearchPattern identifies a user-defined search pattern.
+	ResourceSearchPattern SettingsResourceType = "search_pattern"
+
+	// ResourceModelEntity identifies a custom entity for a detection method.
+	ResourceModelEntity SettingsResourceType = "model_entity"
+
+	// ResourceClassificationRule identifies a document classification rule.
+	ResourceClassificationRule SettingsResourceType = "classification_rule"
+)
+
+// RevisionAction describes what happened to a settings resource.
+type RevisionAction string
+
+// Available revision actions.
+const (
+	// RevisionCreated indicates the resource was created.
+	RevisionCreated RevisionAction = "created"
+
+	// RevisionUpdated indicates the resource was modified.
+	RevisionUpdated RevisionAction = "updated"
+
+	// RevisionDeleted indicates the resource was removed.
+	RevisionDeleted RevisionAction = "deleted"
+)
+
+// SettingsRevision represents a single entry in the settings revision history.
+// Revisions are numbered by a monotonically increasing sequence, so a client that
+// remembers the highest revision it has applied can request only newer changes.
+type SettingsRevision struct {
+	// Revision is the monotonically increasing revision number
+	Revision int64 `json:"revision" db:"revision_id"`
+
+	// UserID references the user whose settings changed
+	UserID int64 `json:"-" db:"user_id"`
+
+	// ResourceType identifies the kind of resource that changed
+	ResourceType SettingsResourceType `json:"resource_type" db:"resource_type"`
+
+	// ResourceID is the identifier of the changed resource
+	ResourceID int64 `json:"resource_id" db:"resource_id"`
+
+	// Action describes whether the resource was created, updated or deleted
+	Action RevisionAction `json:"action" db:"action"`
+
+	// Data contains a snapshot of the resource after the change (empty for deletions)
+	Data json.RawMessage `json:"data,omitempty" db:"data"`
+
+	// CreatedAt records when the change happened
+	CreatedAt time.Time `json:"created_at" db:"created_at"`
+}
+
+// NewSettingsRevision creates a new SettingsRevision for a changed resource.
+//
+// Parameters:
+//   - userID: The ID of the user whose settings changed
+//   - resourceType: The kind of resource that changed
+//   - resourceID: The identifier of the changed resource
+//   - action: What happened to the resource
+//   - data: A snapshot of the resource, marshalled to JSON (nil for none)
+//
+// Returns:
+//   - A new SettingsRevision pointer; the revision number is assigned on insert
+//
+// If the snapshot cannot be marshalled the revision is still returned without data,
+// since the change itself must never be lost from the feed.
+func NewSettingsRevision(userID int64, resourceType SettingsResourceType, resourceID int64, action RevisionAction, data interface{}) *SettingsRevision {
+	revision := &SettingsRevision{
+		UserID:       userID,
+		ResourceType: resourceType,
+		ResourceID:   resourceID,
+		Action:       action,
+		CreatedAt:    time.Now(),
+	}
+
+	if data != nil {
+		if raw, err := json.Marshal(data); err == nil {
+			revision.Data = raw
+		}
+	}
+
+	return revision
+}
+
+// TableName returns the database table name for the SettingsRevision model.
+// This method is used by ORM frameworks to determine where to persist this entity.
+func (sr *SettingsRevision) TableName() string {
+	return constants.TableSettingsRevisions
+}
+
+// SettingsChangeFeed is the response returned by the settings change feed endpoint.
+// It contains every revision newer than the one the client last saw, in order.
+type SettingsChangeFeed struct {
+	// Since echoes the revision the client asked to start after
+	Since int64 `json:"since"`
+
+	// LatestRevision is the highest revision included in this response, or Since if there are none.
+	// Clients should pass this value as the next since parameter.
+	LatestRevision int64 `json:"latest_revision"`
+
+	// HasMore indicates that more revisions are available and the client should fetch again immediately
+	HasMore bool `json:"has_more"`
+
+	// Changes contains the revisions in ascending order
+	Changes []*SettingsRevision `json:"changes"`
+}
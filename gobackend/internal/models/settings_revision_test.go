@@ -0,0 +1,48 @@
+package models
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/yasinhessnawi1/Hideme_Backend/internal/constants"
+)
+
+func TestNewSettingsRevision(t *testing.T) {
+	t.Run("Create with snapshot", func(t *testing.T) {
+		// Act
+		revision := NewSettingsRevision(1, ResourceBanListWord, 10, RevisionCreated, map[string]string{"word": "alpha"})
+
+		// Assert
+		assert.Equal(t, int64(1), revision.UserID)
+		assert.Equal(t, ResourceBanListWord, revision.ResourceType)
+		assert.Equal(t, int64(10), revision.ResourceID)
+		assert.Equal(t, RevisionCreated, revision.Action)
+		assert.JSONEq(t, `{"word":"alpha"}`, string(revision.Data))
+		assert.NotZero(t, revision.CreatedAt)
+		assert.Zero(t, revision.Revision) // Revision should be zero until saved
+	})
+
+	t.Run("Create without snapshot", func(t *testing.T) {
+		// Act
+		revision := NewSettingsRevision(1, ResourceSearchPattern, 20, RevisionDeleted, nil)
+
+		// Assert
+		assert.Equal(t, RevisionDeleted, revision.Action)
+		assert.Nil(t, revision.Data)
+	})
+
+	t.Run("Unmarshalable snapshot is dropped", func(t *testing.T) {
+		// Act
+		revision := NewSettingsRevision(1, ResourceGeneralSettings, 1, RevisionUpdated, make(chan int))
+
+		// Assert
+		assert.Equal(t, RevisionUpdated, revision.Action)
+		assert.Nil(t, revision.Data)
+	})
+}
+
+func TestSettingsRevision_TableName(t *testing.T) {
+	revision := &SettingsRevision{}
+	assert.Equal(t, constants.TableSettingsRevisions, revision.TableName())
+}
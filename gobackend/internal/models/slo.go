@@ -0,0 +1,58 @@
+// Package models provides data structures and operations for the HideMe application.
+// This file contains the request metrics the service-level objectives are evaluated on
+// and the alerts sent when their error budgets burn too fast.
+package models
+
+import "time"
+
+// RequestWindowStats count the API requests answered during a window.
+type RequestWindowStats struct {
+	// Requests is the number of requests answered
+	Requests int64 `json:"requests"`
+
+	// Errors is the number of requests answered with a server error (5xx)
+	Errors int64 `json:"errors"`
+
+	// Timed is the number of requests whose latency counts towards the latency objective;
+	// streams and long-running routes are left out
+	Timed int64 `json:"timed"`
+
+	// Slow is the number of timed requests not answered within the latency threshold
+	Slow int64 `json:"slow"`
+}
+
+// SLOAlert is the payload of the slo_alert notification and the slo.alert webhook event,
+// sent when a burn-rate rule of an objective fires or resolves.
+type SLOAlert struct {
+	// Objective is the objective whose error budget burns, e.g. constants.SLOObjectiveLatency
+	Objective string `json:"objective"`
+
+	// Target is the share of good requests the objective promises, e.g. 0.995
+	Target float64 `json:"target"`
+
+	// Severity is constants.SLOSeverityCritical or constants.SLOSeverityWarning
+	Severity string `json:"severity"`
+
+	// Status is constants.SLOAlertFiring or constants.SLOAlertResolved
+	Status string `json:"status"`
+
+	// LongWindow and ShortWindow are the windows of the rule, e.g. "1h0m0s" and "5m0s"
+	LongWindow  string `json:"long_window"`
+	ShortWindow string `json:"short_window"`
+
+	// BurnRate is how many times faster than sustainable the error budget was spent in
+	// the long window; ShortBurnRate in the short window
+	BurnRate      float64 `json:"burn_rate"`
+	ShortBurnRate float64 `json:"short_burn_rate"`
+
+	// Threshold is the burn rate at which the rule fires
+	Threshold float64 `json:"threshold"`
+
+	// Requests and BadRequests count the requests of the long window and those that
+	// missed the objective
+	Requests    int64 `json:"requests"`
+	BadRequests int64 `json:"bad_requests"`
+
+	// At records when the alert changed status
+	At time.Time `json:"at"`
+}
@@ -0,0 +1,300 @@
+// Package models provides data structures and operations for the HideMe application.
+// This file contains models for the public status page, which shows the health of the
+// components the service depends on, their uptime and the incident notes published
+// by administrators.
+package models
+
+import (
+	"time"
+
+	"github.com/yasinhessnawi1/Hideme_Backend/internal/constants"
+)
+
+// ComponentState is the health of a component as shown on the status page.
+type ComponentState string
+
+// Available component states, from best to worst.
+const (
+	// ComponentOperational means the component works as expected.
+	ComponentOperational ComponentState = "operational"
+
+	// ComponentDegraded means the component works but is slow or partially failing.
+	ComponentDegraded ComponentState = "degraded"
+
+	// ComponentOutage means the component is unavailable.
+	ComponentOutage ComponentState = "outage"
+)
+
+// severity orders the states so the worst of several states can be determined.
+func (cs ComponentState) severity() int {
+	switch cs {
+	case ComponentOperational:
+		return 0
+	case ComponentDegraded:
+		return 1
+	default:
+		return 2
+	}
+}
+
+// Healthy reports whether a check with this state counts towards the uptime.
+// Degraded components still serve requests, so only outages count as downtime.
+//
+// Returns:
+//   - true unless the component is unavailable
+func (cs ComponentState) Healthy() bool {
+	return cs != ComponentOutage
+}
+
+// WorstState returns the worst of the given states, which is the overall state of the service.
+//
+// Parameters:
+//   - states: The states of the individual components
+//
+// Returns:
+//   - The worst state, or ComponentOperational if no states are given
+func WorstState(states ...ComponentState) ComponentState {
+	worst := ComponentOperational
+	for _, state := range states {
+		if state.severity() > worst.severity() {
+			worst = state
+		}
+	}
+	return worst
+}
+
+// UptimeCounts is the number of health checks of a component within a time window.
+type UptimeCounts struct {
+	// Component is the name of the component
+	Component string `json:"component" db:"component"`
+
+	// Checks is the number of health checks run
+	Checks int64 `json:"checks" db:"checks"`
+
+	// HealthyChecks is the number of health checks that passed
+	HealthyChecks int64 `json:"healthy_checks" db:"healthy_checks"`
+}
+
+// Percentage returns the share of passed health checks in percent.
+//
+// Returns:
+//   - The uptime in percent, or nil if no checks were run
+func (uc *UptimeCounts) Percentage() *float64 {
+	if uc == nil || uc.Checks == 0 {
+		return nil
+	}
+	percentage := float64(uc.HealthyChecks) * 100 / float64(uc.Checks)
+	return &percentage
+}
+
+// UptimePercentages contains the uptime of a component over the windows shown on the status page.
+// A window without any health checks is left empty.
+type UptimePercentages struct {
+	// Day is the uptime over the last 24 hours
+	Day *float64 `json:"24h"`
+
+	// Week is the uptime over the last 7 days
+	Week *float64 `json:"7d"`
+
+	// Month is the uptime over the last 30 days
+	Month *float64 `json:"30d"`
+}
+
+// ComponentStatus is the health of a single component on the status page.
+type ComponentStatus struct {
+	// Name identifies the component
+	Name string `json:"name"`
+
+	// Status is the result of the latest health check
+	Status ComponentState `json:"status"`
+
+	// Uptime is the share of passed health checks over recent windows
+	Uptime UptimePercentages `json:"uptime"`
+}
+
+// IncidentImpact describes how severely an incident affects users.
+type IncidentImpact string
+
+// Available incident impacts.
+const (
+	// IncidentImpactMinor affects few users or non-essential features.
+	IncidentImpactMinor IncidentImpact = "minor"
+
+	// IncidentImpactMajor affects many users or essential features.
+	IncidentImpactMajor IncidentImpact = "major"
+
+	// IncidentImpactCritical makes the service unusable.
+	IncidentImpactCritical IncidentImpact = "critical"
+)
+
+// State returns the overall state of the service implied by an unresolved incident of this impact.
+// Minor incidents leave the service operational, major incidents degrade it and critical
+// incidents mark an outage.
+//
+// Returns:
+//   - The implied component state
+func (ii IncidentImpact) State() ComponentState {
+	switch ii {
+	case IncidentImpactCritical:
+		return ComponentOutage
+	case IncidentImpactMajor:
+		return ComponentDegraded
+	default:
+		return ComponentOperational
+	}
+}
+
+// IncidentStatus is the progress of the work on an incident.
+type IncidentStatus string
+
+// Available incident statuses.
+const (
+	// IncidentInvestigating means the cause of the incident is being looked for.
+	IncidentInvestigating IncidentStatus = "investigating"
+
+	// IncidentIdentified means the cause is known and a fix is in progress.
+	IncidentIdentified IncidentStatus = "identified"
+
+	// IncidentMonitoring means a fix is deployed and being watched.
+	IncidentMonitoring IncidentStatus = "monitoring"
+
+	// IncidentResolved means the incident is over.
+	IncidentResolved IncidentStatus = "resolved"
+)
+
+// StatusIncident is an incident note published on the status page.
+type StatusIncident struct {
+	// ID is the unique identifier for this incident
+	ID int64 `json:"id" db:"incident_id"`
+
+	// Title is a short summary of the incident
+	Title string `json:"title" db:"title"`
+
+	// Message is the latest update on the incident
+	Message string `json:"message" db:"message"`
+
+	// Impact describes how severely users are affected
+	Impact IncidentImpact `json:"impact" db:"impact"`
+
+	// Status is the progress of the work on the incident
+	Status IncidentStatus `json:"status" db:"status"`
+
+	// CreatedBy references the administrator who opened the incident; not shown publicly
+	CreatedBy *int64 `json:"-" db:"created_by"`
+
+	// CreatedAt records when the incident was opened
+	CreatedAt time.Time `json:"created_at" db:"created_at"`
+
+	// UpdatedAt records when the incident was last updated
+	UpdatedAt time.Time `json:"updated_at" db:"updated_at"`
+
+	// ResolvedAt records when the incident was resolved, if it is
+	ResolvedAt *time.Time `json:"resolved_at,omitempty" db:"resolved_at"`
+}
+
+// NewStatusIncident creates a new StatusIncident from the data entered by an administrator.
+// Incidents without a status start out as being investigated.
+//
+// Parameters:
+//   - createdBy: The ID of the administrator opening the incident
+//   - create: The title, message, impact and optional status of the incident
+//
+// Returns:
+//   - A new StatusIncident pointer with the timestamps initialized
+func NewStatusIncident(createdBy int64, create *StatusIncidentCreate) *StatusIncident {
+	now := time.Now()
+	incident := &StatusIncident{
+		Title:     create.Title,
+		Message:   create.Message,
+		Impact:    create.Impact,
+		Status:    create.Status,
+		CreatedBy: &createdBy,
+		CreatedAt: now,
+		UpdatedAt: now,
+	}
+	if incident.Status == "" {
+		incident.Status = IncidentInvestigating
+	}
+	if incident.Status == IncidentResolved {
+		incident.ResolvedAt = &now
+	}
+	return incident
+}
+
+// Apply updates the incident with the changes entered by an administrator.
+// Resolving the incident records the time of resolution; reopening it clears it again.
+//
+// Parameters:
+//   - update: The fields to change; empty fields are left as they are
+func (si *StatusIncident) Apply(update *StatusIncidentUpdate) {
+	now := time.Now()
+	if update.Title != "" {
+		si.Title = update.Title
+	}
+	if update.Message != "" {
+		si.Message = update.Message
+	}
+	if update.Impact != "" {
+		si.Impact = update.Impact
+	}
+	if update.Status != "" && update.Status != si.Status {
+		si.Status = update.Status
+		if si.Status == IncidentResolved {
+			si.ResolvedAt = &now
+		} else {
+			si.ResolvedAt = nil
+		}
+	}
+	si.UpdatedAt = now
+}
+
+// TableName returns the database table name for the StatusIncident model.
+// This method is used by ORM frameworks to determine where to persist this entity.
+func (si *StatusIncident) TableName() string {
+	return constants.TableStatusIncidents
+}
+
+// StatusIncidentCreate represents the data required to open an incident.
+type StatusIncidentCreate struct {
+	// Title is a short summary of the incident
+	Title string `json:"title" validate:"required,max=200"`
+
+	// Message is the first update on the incident
+	Message string `json:"message" validate:"required,max=2000"`
+
+	// Impact describes how severely users are affected
+	Impact IncidentImpact `json:"impact" validate:"required,oneof=minor major critical"`
+
+	// Status is the progress of the work on the incident (default: investigating)
+	Status IncidentStatus `json:"status" validate:"omitempty,oneof=investigating identified monitoring resolved"`
+}
+
+// StatusIncidentUpdate represents the changes to an incident; empty fields are left as they are.
+type StatusIncidentUpdate struct {
+	// Title is a short summary of the incident
+	Title string `json:"title" validate:"omitempty,max=200"`
+
+	// Message is the latest update on the incident
+	Message string `json:"message" validate:"omitempty,max=2000"`
+
+	// Impact describes how severely users are affected
+	Impact IncidentImpact `json:"impact" validate:"omitempty,oneof=minor major critical"`
+
+	// Status is the progress of the work on the incident
+	Status IncidentStatus `json:"status" validate:"omitempty,oneof=investigating identified monitoring resolved"`
+}
+
+// PublicStatus is the data behind the public status page.
+type PublicStatus struct {
+	// Status is the overall state of the service, the worst state of its components and unresolved incidents
+	Status ComponentState `json:"status"`
+
+	// Components lists the health of each checked component
+	Components []*ComponentStatus `json:"components"`
+
+	// Incidents lists the incidents that are not resolved yet, newest first
+	Incidents []*StatusIncident `json:"incidents"`
+
+	// CheckedAt is when the components were last checked
+	CheckedAt time.Time `json:"checked_at"`
+}
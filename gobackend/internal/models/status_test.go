@@ -0,0 +1,90 @@
+package models
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/yasinhessnawi1/Hideme_Backend/internal/constants"
+)
+
+func TestWorstState(t *testing.T) {
+	assert.Equal(t, ComponentOperational, WorstState())
+	assert.Equal(t, ComponentOperational, WorstState(ComponentOperational, ComponentOperational))
+	assert.Equal(t, ComponentDegraded, WorstState(ComponentOperational, ComponentDegraded))
+	assert.Equal(t, ComponentOutage, WorstState(ComponentOutage, ComponentDegraded, ComponentOperational))
+}
+
+func TestComponentState_Healthy(t *testing.T) {
+	assert.True(t, ComponentOperational.Healthy())
+	assert.True(t, ComponentDegraded.Healthy())
+	assert.False(t, ComponentOutage.Healthy())
+}
+
+func TestIncidentImpact_State(t *testing.T) {
+	assert.Equal(t, ComponentOperational, IncidentImpactMinor.State())
+	assert.Equal(t, ComponentDegraded, IncidentImpactMajor.State())
+	assert.Equal(t, ComponentOutage, IncidentImpactCritical.State())
+}
+
+func TestUptimeCounts_Percentage(t *testing.T) {
+	var missing *UptimeCounts
+	assert.Nil(t, missing.Percentage())
+	assert.Nil(t, (&UptimeCounts{Component: "api"}).Percentage())
+
+	percentage := (&UptimeCounts{Component: "api", Checks: 200, HealthyChecks: 199}).Percentage()
+	require.NotNil(t, percentage)
+	assert.InDelta(t, 99.5, *percentage, 0.0001)
+}
+
+func TestNewStatusIncident(t *testing.T) {
+	incident := NewStatusIncident(7, &StatusIncidentCreate{
+		Title:   "Slow detection",
+		Message: "Detection requests are slower than usual",
+		Impact:  IncidentImpactMinor,
+	})
+
+	assert.Equal(t, "Slow detection", incident.Title)
+	assert.Equal(t, IncidentImpactMinor, incident.Impact)
+	assert.Equal(t, IncidentInvestigating, incident.Status)
+	require.NotNil(t, incident.CreatedBy)
+	assert.Equal(t, int64(7), *incident.CreatedBy)
+	assert.False(t, incident.CreatedAt.IsZero())
+	assert.Nil(t, incident.ResolvedAt)
+
+	resolved := NewStatusIncident(7, &StatusIncidentCreate{
+		Title:   "Past outage",
+		Message: "Recorded after the fact",
+		Impact:  IncidentImpactMajor,
+		Status:  IncidentResolved,
+	})
+	assert.NotNil(t, resolved.ResolvedAt)
+}
+
+func TestStatusIncident_Apply(t *testing.T) {
+	incident := NewStatusIncident(7, &StatusIncidentCreate{
+		Title:   "Database outage",
+		Message: "Investigating",
+		Impact:  IncidentImpactCritical,
+	})
+
+	// Only the given fields change
+	incident.Apply(&StatusIncidentUpdate{Message: "Fix deployed", Status: IncidentMonitoring})
+	assert.Equal(t, "Database outage", incident.Title)
+	assert.Equal(t, "Fix deployed", incident.Message)
+	assert.Equal(t, IncidentMonitoring, incident.Status)
+	assert.Nil(t, incident.ResolvedAt)
+
+	// Resolving records the time, reopening clears it
+	incident.Apply(&StatusIncidentUpdate{Status: IncidentResolved})
+	assert.NotNil(t, incident.ResolvedAt)
+
+	incident.Apply(&StatusIncidentUpdate{Status: IncidentIdentified})
+	assert.Nil(t, incident.ResolvedAt)
+}
+
+func TestStatusIncident_TableName(t *testing.T) {
+	incident := &StatusIncident{}
+	assert.Equal(t, constants.TableStatusIncidents, incident.TableName())
+}
@@ -0,0 +1,139 @@
+// Package models provides data structures and operations for the HideMe application.
+// This file contains models for monthly tenant usage rollups, which are the source
+// of the billing export.
+package models
+
+import (
+	"strconv"
+	"time"
+
+	"github.com/yasinhessnawi1/Hideme_Backend/internal/constants"
+)
+
+// TenantUsage represents the usage of a single tenant (user account) in a calendar month.
+// Rows are maintained by the usage rollup maintenance task and are read by the billing export.
+type TenantUsage struct {
+	// UserID references the tenant this usage belongs to
+	UserID int64 `json:"user_id" db:"user_id"`
+
+	// Username is the tenant's username, joined in for the billing export
+	Username string `json:"username" db:"username"`
+
+	// Email is the tenant's email address, joined in for the billing export
+	Email string `json:"email" db:"email" redact:"support"`
+
+	// UsageMonth is the first day of the month (UTC) this usage covers
+	UsageMonth time.Time `json:"usage_month" db:"usage_month"`
+
+	// DocumentCount is the number of documents uploaded during the month
+	DocumentCount int64 `json:"document_count" db:"document_count"`
+
+	// PageCount is the number of pages processed during the month
+	PageCount int64 `json:"page_count" db:"page_count"`
+
+	// StorageBytes is the amount of document metadata stored for the tenant at the last rollup
+	StorageBytes int64 `json:"storage_bytes" db:"storage_bytes"`
+
+	// APICallCount is the number of authenticated API calls made during the month
+	APICallCount int64 `json:"api_call_count" db:"api_call_count"`
+
+	// UpdatedAt records when this rollup was last refreshed
+	UpdatedAt time.Time `json:"updated_at" db:"updated_at"`
+}
+
+// TableName returns the database table name for the TenantUsage model.
+// This method is used by ORM frameworks to determine where to persist this entity.
+func (tu *TenantUsage) TableName() string {
+	return constants.TableTenantUsage
+}
+
+// UsageCounters holds usage that has been observed in memory but not yet
+// persisted by the rollup task.
+type UsageCounters struct {
+	// PageCount is the number of pages processed since the last rollup
+	PageCount int64
+
+	// APICallCount is the number of API calls made since the last rollup
+	APICallCount int64
+}
+
+// UsageMonthOf returns the first instant of the UTC calendar month containing t.
+//
+// Parameters:
+//   - t: Any point in time
+//
+// Returns:
+//   - Midnight UTC on the first day of t's month
+func UsageMonthOf(t time.Time) time.Time {
+	t = t.UTC()
+	return time.Date(t.Year(), t.Month(), 1, 0, 0, 0, 0, time.UTC)
+}
+
+// BillingCSVHeader returns the column header row of the billing export.
+// The column order is part of the contract with the billing system and must not change.
+//
+// Returns:
+//   - The header row
+func BillingCSVHeader() []string {
+	return []string{
+		"month",
+		"user_id",
+		"username",
+		"email",
+		"documents",
+		"pages_processed",
+		"storage_bytes",
+		"api_calls",
+	}
+}
+
+// CSVRecord returns the usage as a billing export row matching BillingCSVHeader.
+//
+// Parameters:
+//   - lf: The regional formatting to apply
+//
+// Returns:
+//   - The row values as strings
+func (tu *TenantUsage) CSVRecord(lf LocaleFormat) []string {
+	return []string{
+		lf.FormatMonth(tu.UsageMonth),
+		strconv.FormatInt(tu.UserID, 10),
+		tu.Username,
+		tu.Email,
+		lf.FormatInt(tu.DocumentCount),
+		lf.FormatInt(tu.PageCount),
+		lf.FormatInt(tu.StorageBytes),
+		lf.FormatInt(tu.APICallCount),
+	}
+}
+
+// UsageReportCSVHeader returns the column header row of the monthly usage report sent to tenants.
+//
+// Returns:
+//   - The header row
+func UsageReportCSVHeader() []string {
+	return []string{
+		"month",
+		"documents",
+		"pages_processed",
+		"storage_bytes",
+		"api_calls",
+	}
+}
+
+// UsageReportCSVRecord returns the usage as a monthly usage report row matching UsageReportCSVHeader.
+//
+// Parameters:
+//   - lf: The regional formatting to apply
+//
+// Returns:
+//   - The row values as strings
+func (tu *TenantUsage) UsageReportCSVRecord(lf LocaleFormat) []string {
+	return []string{
+		lf.FormatMonth(tu.UsageMonth),
+		lf.FormatInt(tu.DocumentCount),
+		lf.FormatInt(tu.PageCount),
+		lf.FormatInt(tu.StorageBytes),
+		lf.FormatInt(tu.APICallCount),
+	}
+}
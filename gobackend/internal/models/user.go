@@ -19,28 +19,31 @@ type User struct {
 
 	// Username is the user's chosen display name
 	// Must be between 3 and 50 characters
-	Username string `json:"username" db:"username" validate:"required,min=3,max=50"`
+	Username string `json:"username" db:"username" validate:"required,min=3,max=50" gdpr:"personal"`
 
 	// Email is the user's email address for communications and recovery
 	// Must be a valid email format
-	Email string `json:"email" db:"email" validate:"required,email"`
+	Email string `json:"email" db:"email" validate:"required,email" gdpr:"personal" redact:"support"`
 
 	// Role defines the user's permission level (e.g., "user", "admin")
 	Role string `json:"role" db:"role"`
 
 	// PasswordHash stores the hashed version of the user's password
 	// This field is excluded from JSON serialization for security
-	PasswordHash string `json:"-" db:"password_hash"`
+	PasswordHash string `json:"-" db:"password_hash" gdpr:"sensitive"`
 
 	// Salt is a unique value used in the password hashing process
 	// This field is excluded from JSON serialization for security
-	Salt string `json:"-" db:"salt"`
+	Salt string `json:"-" db:"salt" gdpr:"sensitive"`
 
 	// CreatedAt records when this user account was created
 	CreatedAt time.Time `json:"created_at" db:"created_at"`
 
 	// UpdatedAt records when this user account was last modified
 	UpdatedAt time.Time `json:"updated_at" db:"updated_at"`
+
+	// DisabledAt records when an administrator disabled the account; nil while it is enabled
+	DisabledAt *time.Time `json:"disabled_at,omitempty" db:"disabled_at"`
 }
 
 // NewUser creates a new User instance with the given username and email.
@@ -77,6 +80,14 @@ func (u *User) TableName() string {
 	return "users"
 }
 
+// IsDisabled checks if an administrator has disabled the account.
+//
+// Returns:
+//   - true if the account is disabled and may not sign in or use its API keys
+func (u *User) IsDisabled() bool {
+	return u.DisabledAt != nil
+}
+
 // Sanitize removes sensitive information from the User object when sending to clients.
 // This ensures sensitive fields like password hash are never exposed.
 //
@@ -107,6 +118,15 @@ type UserCredentials struct {
 	// Password is the user's plain text password for authentication
 	// Must be at least 8 characters
 	Password string `json:"password" validate:"required,min=8"`
+
+	// CaptchaToken is the CAPTCHA response, required when risk scoring asks for one
+	CaptchaToken string `json:"captcha_token,omitempty"`
+
+	// ClientIP is the client's IP address, filled in by the handler for risk scoring
+	ClientIP string `json:"-"`
+
+	// UserAgent is the client's User-Agent header, filled in by the handler for risk scoring
+	UserAgent string `json:"-"`
 }
 
 // UserRegistration represents the data required for user registration.
@@ -128,6 +148,15 @@ type UserRegistration struct {
 	// ConfirmPassword must match Password exactly
 	// This ensures the user has entered their intended password correctly
 	ConfirmPassword string `json:"confirm_password" validate:"required,eqfield=Password"`
+
+	// CaptchaToken is the CAPTCHA response, required when risk scoring asks for one
+	CaptchaToken string `json:"captcha_token,omitempty"`
+
+	// ClientIP is the client's IP address, filled in by the handler for risk scoring
+	ClientIP string `json:"-"`
+
+	// UserAgent is the client's User-Agent header, filled in by the handler for risk scoring
+	UserAgent string `json:"-"`
 }
 
 // UserUpdate represents the data that can be updated for a user.
@@ -146,3 +175,27 @@ type UserUpdate struct {
 	// If provided, must be at least 8 characters
 	Password string `json:"password" validate:"omitempty,min=8"`
 }
+
+// UserFilter selects the users listed to administrators.
+type UserFilter struct {
+	// Role only selects users with this role; empty for all roles
+	Role string
+
+	// Status only selects users with this status, one of the constants.UserStatus values; empty for all
+	Status string
+}
+
+// UserRoleUpdate is the request to change a user's role.
+type UserRoleUpdate struct {
+	// Role is the new role of the user
+	Role string `json:"role" validate:"required,oneof=user admin auditor support"`
+
+	// Reason is the justification for a promotion to admin, which waits for a second administrator
+	Reason string `json:"reason" validate:"required_if=Role admin,max=500"`
+}
+
+// UserErasureRequest is the request to permanently delete a user account.
+type UserErasureRequest struct {
+	// Reason is the justification for the erasure, shown to the approving administrator
+	Reason string `json:"reason" validate:"required,max=500"`
+}
@@ -0,0 +1,310 @@
+// Package models provides data structures and operations for the HideMe application.
+// This file contains the export of all data kept about a user, which users download to
+// exercise their right of access (GDPR Article 15).
+package models
+
+import (
+	"archive/zip"
+	"encoding/json"
+	"fmt"
+	"io"
+	"time"
+
+	"github.com/yasinhessnawi1/Hideme_Backend/internal/constants"
+	"github.com/yasinhessnawi1/Hideme_Backend/internal/utils/gdprlog"
+)
+
+// UserDataExportVersion is the version of the user data export format.
+const UserDataExportVersion = 1
+
+// UserDataExportDocument is the metadata of a document in a user data export.
+type UserDataExportDocument struct {
+	DocumentExportMetadata
+
+	// ArchivedAt is when the document was moved to cold storage; its detected entities are
+	// only exported once it is restored
+	ArchivedAt *time.Time `json:"archived_at,omitempty"`
+}
+
+// NewUserDataExportDocument creates the exported metadata of a document.
+//
+// Parameters:
+//   - doc: The document with its name decrypted
+//
+// Returns:
+//   - A new UserDataExportDocument pointer
+func NewUserDataExportDocument(doc *Document) *UserDataExportDocument {
+	return &UserDataExportDocument{
+		DocumentExportMetadata: newDocumentExportMetadata(doc),
+		ArchivedAt:             doc.ArchivedAt,
+	}
+}
+
+// UserDataExport is the layout of the export of all data kept about a user. Exports are
+// written section by section with a UserDataExportWriter rather than built as a whole,
+// so the documents and detected entities are never held in memory together.
+type UserDataExport struct {
+	// Version is the export format version, see UserDataExportVersion
+	Version int `json:"version"`
+
+	// ExportedAt records when the export was created
+	ExportedAt time.Time `json:"exported_at"`
+
+	// DataCategories maps the JSON path of each personal and sensitive field of the export
+	// to its GDPR category
+	DataCategories map[string]gdprlog.LogCategory `json:"data_categories"`
+
+	// User is the profile of the user
+	User *User `json:"user"`
+
+	// Settings are the settings, ban list, search patterns and model entities of the user
+	Settings *SettingsExport `json:"settings"`
+
+	// Documents lists the metadata of every document of the user, newest first
+	Documents []*UserDataExportDocument `json:"documents"`
+
+	// DetectedEntities lists the detected entities of every document in hot storage
+	DetectedEntities []*DetectedEntityWithMethod `json:"detected_entities"`
+}
+
+// userDataExportCategories are the personal and sensitive fields of a user data export,
+// read from the gdpr tags of the models it is made of.
+var userDataExportCategories = gdprlog.CategorizedPaths(UserDataExport{})
+
+// Sections of a user data export that are written item by item, in this order.
+const (
+	userDataExportDocuments = "documents"
+	userDataExportEntities  = "detected_entities"
+)
+
+var userDataExportLists = []string{userDataExportDocuments, userDataExportEntities}
+
+// UserDataExportWriter writes a user data export as a single JSON object or as a ZIP archive
+// with one JSON file per section: manifest.json (version, export time and data categories),
+// user.json, settings.json, documents.json and detected_entities.json.
+//
+// Documents must be written before detected entities. Each item is written as soon as it is
+// passed in; if writing stops before Close, the output is left incomplete and fails to parse,
+// so clients notice that the export was cut off.
+type UserDataExportWriter struct {
+	// archive is the ZIP archive being written; nil for JSON
+	archive *zip.Writer
+
+	// w is the response for JSON and the open file of the archive for ZIP
+	w io.Writer
+
+	exportedAt time.Time
+
+	// lists is the number of item lists opened so far, items the number written to the open one
+	lists int
+	items int
+}
+
+// NewUserDataExportWriter starts a user data export by writing its profile and settings.
+//
+// Parameters:
+//   - w: The writer receiving the export
+//   - format: constants.DocumentExportFormatJSON or constants.DocumentExportFormatZIP
+//   - user: The profile of the user
+//   - settings: The settings of the user
+//   - exportedAt: When the export is created
+//
+// Returns:
+//   - A writer taking the documents and detected entities
+//   - An error if the format is not supported or the header could not be written
+func NewUserDataExportWriter(w io.Writer, format string, user *User, settings *SettingsExport, exportedAt time.Time) (*UserDataExportWriter, error) {
+	uw := &UserDataExportWriter{w: w, exportedAt: exportedAt}
+
+	header := []struct {
+		name string
+		data interface{}
+	}{
+		{"user", user},
+		{"settings", settings},
+	}
+
+	switch format {
+	case constants.DocumentExportFormatJSON:
+		manifest, err := json.Marshal(map[string]interface{}{
+			"version":         UserDataExportVersion,
+			"exported_at":     exportedAt,
+			"data_categories": userDataExportCategories,
+		})
+		if err != nil {
+			return nil, fmt.Errorf("failed to encode export manifest: %w", err)
+		}
+		// Leave the object open so the sections can follow
+		if _, err := w.Write(manifest[:len(manifest)-1]); err != nil {
+			return nil, fmt.Errorf("failed to write export manifest: %w", err)
+		}
+		for _, section := range header {
+			if err := uw.writeField(section.name, section.data); err != nil {
+				return nil, err
+			}
+		}
+	case constants.DocumentExportFormatZIP:
+		uw.archive = zip.NewWriter(w)
+		manifest := map[string]interface{}{"version": UserDataExportVersion, "exported_at": exportedAt, "data_categories": userDataExportCategories}
+		if err := uw.writeFile("manifest.json", manifest); err != nil {
+			return nil, err
+		}
+		for _, section := range header {
+			if err := uw.writeFile(section.name+".json", section.data); err != nil {
+				return nil, err
+			}
+		}
+	default:
+		return nil, fmt.Errorf("unsupported export format %q", format)
+	}
+
+	return uw, nil
+}
+
+// WriteDocument writes the metadata of a document.
+//
+// Parameters:
+//   - doc: The metadata of the document
+//
+// Returns:
+//   - An error if the document could not be encoded or written
+func (uw *UserDataExportWriter) WriteDocument(doc *UserDataExportDocument) error {
+	return uw.writeItem(userDataExportDocuments, doc)
+}
+
+// WriteEntity writes a detected entity. Once the first entity was written, no more documents can follow.
+//
+// Parameters:
+//   - entity: The detected entity with its detection method
+//
+// Returns:
+//   - An error if the entity could not be encoded or written
+func (uw *UserDataExportWriter) WriteEntity(entity *DetectedEntityWithMethod) error {
+	return uw.writeItem(userDataExportEntities, entity)
+}
+
+// Close completes the export; sections nothing was written to are exported as empty lists.
+//
+// Returns:
+//   - An error if the end of the export could not be written
+func (uw *UserDataExportWriter) Close() error {
+	if err := uw.openList(userDataExportLists[len(userDataExportLists)-1]); err != nil {
+		return err
+	}
+	if err := uw.closeList(); err != nil {
+		return err
+	}
+	if uw.archive != nil {
+		return uw.archive.Close()
+	}
+	if _, err := io.WriteString(uw.w, "}\n"); err != nil {
+		return fmt.Errorf("failed to write end of export: %w", err)
+	}
+	return nil
+}
+
+// writeItem writes an item to a list, opening the list first if needed.
+func (uw *UserDataExportWriter) writeItem(list string, item interface{}) error {
+	if err := uw.openList(list); err != nil {
+		return err
+	}
+	data, err := json.Marshal(item)
+	if err != nil {
+		return fmt.Errorf("failed to encode %s: %w", list, err)
+	}
+	separator := ",\n"
+	if uw.items == 0 {
+		separator = "\n"
+	}
+	if _, err := io.WriteString(uw.w, separator); err != nil {
+		return fmt.Errorf("failed to write %s: %w", list, err)
+	}
+	if _, err := uw.w.Write(data); err != nil {
+		return fmt.Errorf("failed to write %s: %w", list, err)
+	}
+	uw.items++
+	return nil
+}
+
+// openList closes the open list and opens the lists up to list, in the order of userDataExportLists.
+func (uw *UserDataExportWriter) openList(list string) error {
+	for uw.lists == 0 || userDataExportLists[uw.lists-1] != list {
+		if uw.lists == len(userDataExportLists) {
+			return fmt.Errorf("%s written after the sections following it", list)
+		}
+		if uw.lists > 0 {
+			if err := uw.closeList(); err != nil {
+				return err
+			}
+		}
+
+		name := userDataExportLists[uw.lists]
+		uw.lists++
+		uw.items = 0
+		if uw.archive != nil {
+			file, err := uw.createFile(name + ".json")
+			if err != nil {
+				return err
+			}
+			uw.w = file
+			if _, err := io.WriteString(uw.w, "["); err != nil {
+				return fmt.Errorf("failed to write %s: %w", name, err)
+			}
+			continue
+		}
+		if _, err := fmt.Fprintf(uw.w, ",%q:[", name); err != nil {
+			return fmt.Errorf("failed to write %s: %w", name, err)
+		}
+	}
+	return nil
+}
+
+// closeList ends the open list.
+func (uw *UserDataExportWriter) closeList() error {
+	end := "\n]"
+	if uw.archive != nil {
+		end += "\n"
+	}
+	if _, err := io.WriteString(uw.w, end); err != nil {
+		return fmt.Errorf("failed to write end of %s: %w", userDataExportLists[uw.lists-1], err)
+	}
+	return nil
+}
+
+// writeField adds a field to the open JSON object.
+func (uw *UserDataExportWriter) writeField(name string, value interface{}) error {
+	data, err := json.Marshal(value)
+	if err != nil {
+		return fmt.Errorf("failed to encode %s: %w", name, err)
+	}
+	if _, err := fmt.Fprintf(uw.w, ",%q:%s", name, data); err != nil {
+		return fmt.Errorf("failed to write %s: %w", name, err)
+	}
+	return nil
+}
+
+// writeFile adds a file with a single JSON value to the archive.
+func (uw *UserDataExportWriter) writeFile(name string, value interface{}) error {
+	file, err := uw.createFile(name)
+	if err != nil {
+		return err
+	}
+	encoder := json.NewEncoder(file)
+	encoder.SetIndent("", "  ")
+	if err := encoder.Encode(value); err != nil {
+		return fmt.Errorf("failed to encode %s: %w", name, err)
+	}
+	return nil
+}
+
+// createFile adds a file to the archive.
+func (uw *UserDataExportWriter) createFile(name string) (io.Writer, error) {
+	file, err := uw.archive.CreateHeader(&zip.FileHeader{
+		Name:     name,
+		Method:   zip.Deflate,
+		Modified: uw.exportedAt,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to add %s to export: %w", name, err)
+	}
+	return file, nil
+}
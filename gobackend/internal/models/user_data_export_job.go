@@ -0,0 +1,148 @@
+// Package models provides data structures and operations for the HideMe application.
+// This file contains the jobs that export all data kept about a user in the background,
+// for users whose exports take too long to stream in a single request.
+package models
+
+import (
+	"encoding/json"
+	"fmt"
+	"time"
+)
+
+// Sections of the chunks a user data export job stores its output in.
+const (
+	// UserDataExportSectionHeader holds the profile and settings of the user
+	UserDataExportSectionHeader = "header"
+
+	// UserDataExportSectionDocuments holds a list of UserDataExportDocument
+	UserDataExportSectionDocuments = userDataExportDocuments
+
+	// UserDataExportSectionEntities holds a list of DetectedEntityWithMethod
+	UserDataExportSectionEntities = userDataExportEntities
+)
+
+// UserDataExportCheckpoint records how far a user data export job got. Everything up to the
+// checkpoint is stored; a job stopped by a restart resumes after it instead of starting over.
+type UserDataExportCheckpoint struct {
+	// LastDocumentID is the ID of the last document exported; documents are exported by ascending ID
+	LastDocumentID int64 `json:"last_document_id" db:"last_document_id"`
+
+	// LastEntityID is the ID of the last detected entity exported
+	LastEntityID int64 `json:"last_entity_id" db:"last_entity_id"`
+
+	// DocumentsExported is the number of documents exported so far
+	DocumentsExported int64 `json:"documents_exported" db:"documents_exported"`
+
+	// EntitiesExported is the number of detected entities exported so far
+	EntitiesExported int64 `json:"entities_exported" db:"entities_exported"`
+
+	// Chunks is the number of chunks of output stored so far
+	Chunks int `json:"chunks" db:"chunks"`
+}
+
+// UserDataExportJob is a background export of all data kept about a user.
+type UserDataExportJob struct {
+	// ID is the unique identifier for this job
+	ID int64 `json:"id" db:"export_id"`
+
+	// UserID references the user whose data is exported
+	UserID int64 `json:"-" db:"user_id"`
+
+	// Format is json or zip
+	Format string `json:"format" db:"format"`
+
+	// Status is pending, running, interrupted, completed or failed
+	Status string `json:"status" db:"status"`
+
+	// Checkpoint records how far the job got
+	Checkpoint UserDataExportCheckpoint `json:"checkpoint"`
+
+	// Resumes is the number of times the job was resumed from its checkpoint
+	Resumes int `json:"resumes" db:"resumes"`
+
+	// LastError describes why the job failed; empty otherwise
+	LastError string `json:"last_error,omitempty" db:"last_error"`
+
+	// LeaseUntil is when the server running the job must have stored its next checkpoint;
+	// after that another server takes the job over
+	LeaseUntil *time.Time `json:"-" db:"lease_until"`
+
+	// CreatedAt records when the export was requested; it is the export time of the file
+	CreatedAt time.Time `json:"created_at" db:"created_at"`
+
+	// UpdatedAt records when the job last changed
+	UpdatedAt time.Time `json:"updated_at" db:"updated_at"`
+
+	// CompletedAt records when the file became available; nil until then
+	CompletedAt *time.Time `json:"completed_at,omitempty" db:"completed_at"`
+}
+
+// UserDataExportChunk is a part of the output of a user data export job.
+type UserDataExportChunk struct {
+	// Section is one of the UserDataExportSection constants
+	Section string
+
+	// Data is the JSON encoded content: a UserDataExportHeader for the header, a list otherwise
+	Data []byte
+}
+
+// UserDataExportHeader is the content of the header chunk of a user data export job.
+type UserDataExportHeader struct {
+	// User is the profile of the user when the job started
+	User *User `json:"user"`
+
+	// Settings are the settings of the user when the job started
+	Settings *SettingsExport `json:"settings"`
+}
+
+// NewUserDataExportChunk encodes a part of the output of a user data export job.
+//
+// Parameters:
+//   - section: One of the UserDataExportSection constants
+//   - content: The header, or the list of documents or detected entities
+//
+// Returns:
+//   - The chunk
+//   - An error if the content could not be encoded
+func NewUserDataExportChunk(section string, content interface{}) (*UserDataExportChunk, error) {
+	data, err := json.Marshal(content)
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode export %s: %w", section, err)
+	}
+	return &UserDataExportChunk{Section: section, Data: data}, nil
+}
+
+// WriteChunk writes the documents or detected entities of a chunk of a user data export job.
+//
+// Parameters:
+//   - chunk: A chunk of the documents or detected entities section
+//
+// Returns:
+//   - An error if the chunk could not be decoded or written
+func (uw *UserDataExportWriter) WriteChunk(chunk *UserDataExportChunk) error {
+	switch chunk.Section {
+	case UserDataExportSectionDocuments:
+		var docs []*UserDataExportDocument
+		if err := json.Unmarshal(chunk.Data, &docs); err != nil {
+			return fmt.Errorf("failed to decode export documents: %w", err)
+		}
+		for _, doc := range docs {
+			if err := uw.WriteDocument(doc); err != nil {
+				return err
+			}
+		}
+	case UserDataExportSectionEntities:
+		var entities []*DetectedEntityWithMethod
+		if err := json.Unmarshal(chunk.Data, &entities); err != nil {
+			return fmt.Errorf("failed to decode export detected entities: %w", err)
+		}
+		for _, entity := range entities {
+			if err := uw.WriteEntity(entity); err != nil {
+				return err
+			}
+		}
+	default:
+		return fmt.Errorf("export section %q cannot be written as items", chunk.Section)
+	}
+	return nil
+}
@@ -0,0 +1,45 @@
+package models
+
+import (
+	"bytes"
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/yasinhessnawi1/Hideme_Backend/internal/constants"
+)
+
+func TestUserDataExportWriter_WriteChunk(t *testing.T) {
+	docs, err := NewUserDataExportChunk(UserDataExportSectionDocuments, []*UserDataExportDocument{
+		NewUserDataExportDocument(&Document{ID: 4, HashedDocumentName: "contract.pdf"}),
+		NewUserDataExportDocument(&Document{ID: 5, HashedDocumentName: "invoice.pdf"}),
+	})
+	require.NoError(t, err)
+	entities, err := NewUserDataExportChunk(UserDataExportSectionEntities, []*DetectedEntityWithMethod{
+		{DetectedEntity: DetectedEntity{ID: 9, DocumentID: 4, EntityName: "Ola Nordmann"}, MethodName: "Presidio"},
+	})
+	require.NoError(t, err)
+
+	var buf bytes.Buffer
+	writer, err := NewUserDataExportWriter(&buf, constants.DocumentExportFormatJSON, &User{ID: 1}, &SettingsExport{UserID: 1}, time.Now())
+	require.NoError(t, err)
+	require.NoError(t, writer.WriteChunk(docs))
+	require.NoError(t, writer.WriteChunk(entities))
+	require.NoError(t, writer.Close())
+
+	var export UserDataExport
+	require.NoError(t, json.Unmarshal(buf.Bytes(), &export))
+	require.Len(t, export.Documents, 2)
+	assert.Equal(t, "invoice.pdf", export.Documents[1].Filename)
+	require.Len(t, export.DetectedEntities, 1)
+	assert.Equal(t, "Presidio", export.DetectedEntities[0].MethodName)
+
+	t.Run("Header chunk", func(t *testing.T) {
+		header, err := NewUserDataExportChunk(UserDataExportSectionHeader, &UserDataExportHeader{User: &User{ID: 1}})
+		require.NoError(t, err)
+		assert.Error(t, writer.WriteChunk(header))
+	})
+}
@@ -0,0 +1,102 @@
+package models
+
+import (
+	"archive/zip"
+	"bytes"
+	"encoding/json"
+	"io"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/yasinhessnawi1/Hideme_Backend/internal/constants"
+	"github.com/yasinhessnawi1/Hideme_Backend/internal/utils/gdprlog"
+)
+
+func TestUserDataExportWriter_JSON(t *testing.T) {
+	exportedAt := time.Date(2026, 3, 1, 12, 0, 0, 0, time.UTC)
+	archivedAt := exportedAt.Add(-time.Hour)
+	user := &User{ID: 1, Username: "ola", Email: "ola@example.com", PasswordHash: "hash"}
+	settings := &SettingsExport{Version: SettingsExportVersion, UserID: 1}
+
+	var buf bytes.Buffer
+	writer, err := NewUserDataExportWriter(&buf, constants.DocumentExportFormatJSON, user, settings, exportedAt)
+	require.NoError(t, err)
+	require.NoError(t, writer.WriteDocument(NewUserDataExportDocument(&Document{ID: 4, HashedDocumentName: "contract.pdf"})))
+	require.NoError(t, writer.WriteDocument(NewUserDataExportDocument(&Document{ID: 5, HashedDocumentName: "old.pdf", ArchivedAt: &archivedAt})))
+	require.NoError(t, writer.WriteEntity(&DetectedEntityWithMethod{DetectedEntity: DetectedEntity{ID: 9, DocumentID: 4, EntityName: "Ola Nordmann"}, MethodName: "Presidio"}))
+	require.NoError(t, writer.Close())
+
+	var export UserDataExport
+	require.NoError(t, json.Unmarshal(buf.Bytes(), &export))
+	assert.Equal(t, UserDataExportVersion, export.Version)
+	assert.Equal(t, "ola@example.com", export.User.Email)
+	assert.Equal(t, int64(1), export.Settings.UserID)
+	require.Len(t, export.Documents, 2)
+	assert.Equal(t, "contract.pdf", export.Documents[0].Filename)
+	assert.NotNil(t, export.Documents[1].ArchivedAt)
+	require.Len(t, export.DetectedEntities, 1)
+	assert.Equal(t, "Ola Nordmann", export.DetectedEntities[0].EntityName)
+	assert.Equal(t, gdprlog.PersonalLog, export.DataCategories["user.email"])
+	assert.Equal(t, gdprlog.SensitiveLog, export.DataCategories["detected_entities[].entity_name"])
+	assert.NotContains(t, buf.String(), "hash")
+
+	t.Run("Documents after entities", func(t *testing.T) {
+		writer, err := NewUserDataExportWriter(io.Discard, constants.DocumentExportFormatJSON, user, settings, exportedAt)
+		require.NoError(t, err)
+		require.NoError(t, writer.WriteEntity(&DetectedEntityWithMethod{}))
+		assert.Error(t, writer.WriteDocument(&UserDataExportDocument{}))
+	})
+
+	t.Run("Unsupported format", func(t *testing.T) {
+		_, err := NewUserDataExportWriter(io.Discard, "csv", user, settings, exportedAt)
+		assert.Error(t, err)
+	})
+}
+
+func TestUserDataExportWriter_EmptyLists(t *testing.T) {
+	var buf bytes.Buffer
+	writer, err := NewUserDataExportWriter(&buf, constants.DocumentExportFormatJSON, &User{ID: 1}, &SettingsExport{}, time.Now())
+	require.NoError(t, err)
+	require.NoError(t, writer.Close())
+
+	var export map[string]json.RawMessage
+	require.NoError(t, json.Unmarshal(buf.Bytes(), &export))
+	assert.JSONEq(t, `[]`, string(export["documents"]))
+	assert.JSONEq(t, `[]`, string(export["detected_entities"]))
+}
+
+func TestUserDataExportWriter_ZIP(t *testing.T) {
+	exportedAt := time.Date(2026, 3, 1, 12, 0, 0, 0, time.UTC)
+
+	var buf bytes.Buffer
+	writer, err := NewUserDataExportWriter(&buf, constants.DocumentExportFormatZIP, &User{ID: 1, Username: "ola"}, &SettingsExport{UserID: 1}, exportedAt)
+	require.NoError(t, err)
+	require.NoError(t, writer.WriteEntity(&DetectedEntityWithMethod{DetectedEntity: DetectedEntity{ID: 9}}))
+	require.NoError(t, writer.Close())
+
+	archive, err := zip.NewReader(bytes.NewReader(buf.Bytes()), int64(buf.Len()))
+	require.NoError(t, err)
+
+	files := make(map[string]string)
+	for _, file := range archive.File {
+		reader, err := file.Open()
+		require.NoError(t, err)
+		content, err := io.ReadAll(reader)
+		require.NoError(t, err)
+		reader.Close()
+		files[file.Name] = string(content)
+	}
+
+	assert.Len(t, files, 5)
+	assert.Contains(t, files["manifest.json"], `"data_categories"`)
+	assert.Contains(t, files["user.json"], `"username": "ola"`)
+	assert.JSONEq(t, `[]`, files["documents.json"])
+
+	var entities []*DetectedEntityWithMethod
+	require.NoError(t, json.Unmarshal([]byte(files["detected_entities.json"]), &entities))
+	require.Len(t, entities, 1)
+	assert.Equal(t, int64(9), entities[0].ID)
+}
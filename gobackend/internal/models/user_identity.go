@@ -0,0 +1,82 @@
+// Package models provides data structures and operations for the HideMe application.
+// This file contains the model linking user accounts to the accounts users sign in with
+// at OpenID Connect providers, such as Google and Microsoft.
+package models
+
+import (
+	"time"
+
+	"github.com/yasinhessnawi1/Hideme_Backend/internal/constants"
+)
+
+// UserIdentity links a user account to an account at a sign-in provider.
+// A user can have one identity per provider; the provider's subject identifies it,
+// since the email address at the provider may change.
+type UserIdentity struct {
+	// ID is the unique identifier for this identity
+	ID int64 `json:"id" db:"identity_id"`
+
+	// UserID is the ID of the user account the identity signs in to
+	UserID int64 `json:"user_id" db:"user_id"`
+
+	// Provider is the sign-in provider, such as google
+	Provider string `json:"provider" db:"provider"`
+
+	// Subject identifies the user at the provider
+	Subject string `json:"-" db:"subject" gdpr:"personal"`
+
+	// Email is the email address at the provider when the identity was linked
+	Email string `json:"email" db:"email" gdpr:"personal"`
+
+	// CreatedAt is when the identity was linked
+	CreatedAt time.Time `json:"created_at" db:"created_at"`
+}
+
+// NewUserIdentity creates a new identity for a user.
+//
+// Parameters:
+//   - userID: The ID of the user account
+//   - provider: The sign-in provider
+//   - subject: The identifier of the user at the provider
+//   - email: The email address at the provider
+//
+// Returns:
+//   - A new UserIdentity pointer with the creation time set
+func NewUserIdentity(userID int64, provider, subject, email string) *UserIdentity {
+	return &UserIdentity{
+		UserID:    userID,
+		Provider:  provider,
+		Subject:   subject,
+		Email:     email,
+		CreatedAt: time.Now(),
+	}
+}
+
+// TableName returns the database table name for the UserIdentity model.
+func (i *UserIdentity) TableName() string {
+	return constants.TableUserIdentities
+}
+
+// OAuthAuthorization is the start of a sign-in with a provider.
+type OAuthAuthorization struct {
+	// AuthorizationURL is the provider's sign-in page the user is sent to
+	AuthorizationURL string `json:"authorization_url"`
+
+	// Flow is the signed sign-in flow kept in a cookie until the provider sends the user back
+	Flow string `json:"-"`
+
+	// ExpiresAt is when the user has to have completed the sign-in
+	ExpiresAt time.Time `json:"-"`
+}
+
+// OAuthCallbackRequest holds the query parameters a provider sends the user back to the callback with.
+type OAuthCallbackRequest struct {
+	// Code is the authorization code issued by the provider
+	Code string
+
+	// State is the state of the sign-in flow, passed through by the provider
+	State string
+
+	// Error is set instead of the code when the user cancelled or the provider refused the sign-in
+	Error string
+}
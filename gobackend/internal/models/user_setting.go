@@ -4,6 +4,9 @@
 package models
 
 import (
+	"database/sql/driver"
+	"encoding/json"
+	"errors"
 	"time"
 
 	"github.com/yasinhessnawi1/Hideme_Backend/internal/constants"
@@ -41,11 +44,28 @@ type UserSetting struct {
 	// applied during detection to exclude specified terms
 	UseBanlistForDetection bool `json:"use_banlist_for_detection" db:"use_banlist_for_detection"`
 
+	// RedactionPlaceholders maps entity types to the text that replaces them when redacted,
+	// e.g. "EMAIL_ADDRESS" to "[EMAIL REDACTED]"
+	// Entity types without an entry are replaced with constants.DefaultRedactionPlaceholder
+	RedactionPlaceholders RedactionPlaceholders `json:"redaction_placeholders" db:"redaction_placeholders"`
+
+	// ScrubFilenames replaces ban list words and search pattern matches in the filenames
+	// of uploaded documents before they are stored, since filenames bypass redaction
+	ScrubFilenames bool `json:"scrub_filenames" db:"scrub_filenames"`
+
+	// DocumentRetentionDays is the number of days after upload the user's documents and their
+	// detected entities are deleted by the retention maintenance task; zero keeps them
+	DocumentRetentionDays int `json:"document_retention_days" db:"document_retention_days"`
+
 	// CreatedAt records when these settings were initially created
 	CreatedAt time.Time `json:"created_at" db:"created_at"`
 
 	// UpdatedAt records when these settings were last modified
 	UpdatedAt time.Time `json:"updated_at" db:"updated_at"`
+
+	// Version is incremented by every update, so that an update based on an older version
+	// is refused instead of overwriting changes made on another device
+	Version int64 `json:"version" db:"version"`
 }
 
 // NewUserSetting creates a new UserSetting instance with default values.
@@ -67,8 +87,10 @@ func NewUserSetting(userID int64) *UserSetting {
 		DetectionThreshold:     0.50,
 		UseBanlistForDetection: true,
 		AutoProcessing:         true,
+		RedactionPlaceholders:  RedactionPlaceholders{},
 		CreatedAt:              now,
 		UpdatedAt:              now,
+		Version:                1,
 	}
 }
 
@@ -97,6 +119,44 @@ type UserSettingsUpdate struct {
 
 	// UseBanlistForDetection determines whether the ban list should be applied during detection
 	UseBanlistForDetection *bool `json:"use_banlist_for_detection" validate:"omitempty"`
+
+	// RedactionPlaceholders replaces all placeholder templates when present; an empty object clears them
+	RedactionPlaceholders RedactionPlaceholders `json:"redaction_placeholders" validate:"omitempty,max=100,dive,keys,required,max=50,printable_text,endkeys,required,max=100,printable_text"`
+
+	// ScrubFilenames replaces ban list words and search pattern matches in uploaded filenames
+	ScrubFilenames *bool `json:"scrub_filenames" validate:"omitempty"`
+
+	// DocumentRetentionDays is the number of days after upload documents are deleted; zero keeps them
+	DocumentRetentionDays *int `json:"document_retention_days" validate:"omitempty,min=0,max=3650"`
+
+	// Version is the version of the settings the update is based on; the update is refused
+	// if the settings were changed since. Updates without a version are not checked
+	Version *int64 `json:"version,omitempty" validate:"omitempty,min=1"`
+}
+
+// SettingsConflict is returned with 409 Conflict when an update of settings is based on
+// an older version than the current one, so the client can resolve the conflict.
+type SettingsConflict struct {
+	// Current is the settings as they are now
+	Current *UserSetting `json:"current"`
+
+	// Submitted is the refused update
+	Submitted *UserSettingsUpdate `json:"submitted"`
+
+	// ConflictingFields are the fields the update changes that were changed to another
+	// value since; set when a merge fails
+	ConflictingFields []string `json:"conflicting_fields,omitempty"`
+}
+
+// SettingsMerge is a change of settings made on a device while another device changed
+// them. The changes are applied to the current settings unless they change a field that
+// was changed to another value since the base version.
+type SettingsMerge struct {
+	// BaseVersion is the version of the settings the changes were made to
+	BaseVersion int64 `json:"base_version" validate:"required,min=1"`
+
+	// Changes are the fields changed on the device; their version is ignored
+	Changes UserSettingsUpdate `json:"changes"`
 }
 
 // Apply updates the UserSetting with values from the update request.
@@ -123,7 +183,75 @@ func (s *UserSetting) Apply(update *UserSettingsUpdate) {
 	if update.UseBanlistForDetection != nil {
 		s.UseBanlistForDetection = *update.UseBanlistForDetection
 	}
+	if update.RedactionPlaceholders != nil {
+		s.RedactionPlaceholders = update.RedactionPlaceholders
+	}
+	if update.ScrubFilenames != nil {
+		s.ScrubFilenames = *update.ScrubFilenames
+	}
+	if update.DocumentRetentionDays != nil {
+		s.DocumentRetentionDays = *update.DocumentRetentionDays
+	}
 
 	// Update the timestamp
 	s.UpdatedAt = time.Now()
 }
+
+// Placeholder returns the text that replaces entities of the given type when redacted.
+//
+// Parameters:
+//   - entityType: The type of the redacted entity
+//
+// Returns:
+//   - The configured placeholder, or constants.DefaultRedactionPlaceholder if none is configured
+func (s *UserSetting) Placeholder(entityType string) string {
+	if placeholder, ok := s.RedactionPlaceholders[entityType]; ok {
+		return placeholder
+	}
+	return constants.DefaultRedactionPlaceholder
+}
+
+// RedactionPlaceholders maps entity types to the text that replaces them when redacted.
+// It is stored as a JSON object.
+type RedactionPlaceholders map[string]string
+
+// Value implements the driver.Valuer interface for RedactionPlaceholders.
+//
+// Returns:
+//   - The JSON representation of the placeholders, "{}" when there are none
+//   - An error if JSON marshaling fails
+func (p RedactionPlaceholders) Value() (driver.Value, error) {
+	if p == nil {
+		return []byte("{}"), nil
+	}
+	return json.Marshal(p)
+}
+
+// Scan implements the sql.Scanner interface for RedactionPlaceholders.
+//
+// Parameters:
+//   - value: The database value to scan (expected to be []byte or string)
+//
+// Returns:
+//   - An error if type assertion or JSON unmarshaling fails
+func (p *RedactionPlaceholders) Scan(value interface{}) error {
+	var data []byte
+	switch v := value.(type) {
+	case nil:
+		*p = RedactionPlaceholders{}
+		return nil
+	case []byte:
+		data = v
+	case string:
+		data = []byte(v)
+	default:
+		return errors.New("type assertion to []byte failed")
+	}
+
+	placeholders := RedactionPlaceholders{}
+	if err := json.Unmarshal(data, &placeholders); err != nil {
+		return err
+	}
+	*p = placeholders
+	return nil
+}
@@ -1,11 +1,16 @@
 package models_test
 
 import (
+	"strings"
 	"testing"
 	"time"
 
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/yasinhessnawi1/Hideme_Backend/internal/constants"
 	"github.com/yasinhessnawi1/Hideme_Backend/internal/models"
+	"github.com/yasinhessnawi1/Hideme_Backend/internal/utils"
 )
 
 func TestUserSetting_TableName(t *testing.T) {
@@ -215,6 +220,69 @@ func TestUserSetting_EmptyUpdateHandling(t *testing.T) {
 	assert.Equal(t, originalValues["UseBanlistForDetection"], setting.UseBanlistForDetection)
 }
 
+func TestUserSetting_RedactionPlaceholders(t *testing.T) {
+	setting := models.NewUserSetting(100)
+	assert.Equal(t, constants.DefaultRedactionPlaceholder, setting.Placeholder("EMAIL_ADDRESS"))
+
+	// Placeholders are replaced as a whole
+	setting.Apply(&models.UserSettingsUpdate{
+		RedactionPlaceholders: models.RedactionPlaceholders{
+			"EMAIL_ADDRESS": "[EMAIL REDACTED]",
+			"PERSON":        "[PERSON]",
+		},
+	})
+	assert.Equal(t, "[EMAIL REDACTED]", setting.Placeholder("EMAIL_ADDRESS"))
+	assert.Equal(t, "[PERSON]", setting.Placeholder("PERSON"))
+	assert.Equal(t, constants.DefaultRedactionPlaceholder, setting.Placeholder("PHONE_NUMBER"))
+
+	// An empty object clears them
+	setting.Apply(&models.UserSettingsUpdate{RedactionPlaceholders: models.RedactionPlaceholders{}})
+	assert.Empty(t, setting.RedactionPlaceholders)
+}
+
+func TestUserSettingsUpdate_RedactionPlaceholdersValidation(t *testing.T) {
+	utils.InitValidator()
+
+	tests := []struct {
+		name         string
+		placeholders models.RedactionPlaceholders
+		wantErr      bool
+	}{
+		{"Valid", models.RedactionPlaceholders{"EMAIL_ADDRESS": "[EMAIL REDACTED]"}, false},
+		{"Blank placeholder", models.RedactionPlaceholders{"PERSON": "   "}, true},
+		{"Line break in placeholder", models.RedactionPlaceholders{"PERSON": "[PER\nSON]"}, true},
+		{"Placeholder too long", models.RedactionPlaceholders{"PERSON": strings.Repeat("x", 101)}, true},
+		{"Empty entity type", models.RedactionPlaceholders{"": "[REDACTED]"}, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := utils.ValidateStruct(&models.UserSettingsUpdate{RedactionPlaceholders: tt.placeholders})
+			assert.Equal(t, tt.wantErr, err != nil, "ValidateStruct() error = %v", err)
+		})
+	}
+}
+
+func TestRedactionPlaceholders_ValueAndScan(t *testing.T) {
+	// Missing placeholders are stored as an empty object
+	value, err := models.RedactionPlaceholders(nil).Value()
+	require.NoError(t, err)
+	assert.Equal(t, []byte("{}"), value)
+
+	value, err = models.RedactionPlaceholders{"PERSON": "[PERSON]"}.Value()
+	require.NoError(t, err)
+
+	var scanned models.RedactionPlaceholders
+	require.NoError(t, scanned.Scan(value))
+	assert.Equal(t, "[PERSON]", scanned["PERSON"])
+
+	require.NoError(t, scanned.Scan(nil))
+	assert.NotNil(t, scanned)
+	assert.Empty(t, scanned)
+
+	assert.Error(t, scanned.Scan(42))
+}
+
 // Helper functions for creating pointers
 func getBoolPtr(v bool) *bool {
 	return &v
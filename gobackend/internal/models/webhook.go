@@ -0,0 +1,148 @@
+// Package models provides data structures and operations for the HideMe application.
+// This file contains models for webhooks, the URLs users receive account and document
+// events at, and the deliveries of those events.
+package models
+
+import (
+	"encoding/json"
+	"time"
+)
+
+// Webhook is a URL a user receives events at.
+type Webhook struct {
+	// ID is the unique identifier for this webhook
+	ID int64 `json:"id" db:"webhook_id"`
+
+	// UserID references the user who owns this webhook
+	UserID int64 `json:"-" db:"user_id"`
+
+	// URL is where the events are posted to
+	URL string `json:"url" db:"url"`
+
+	// Secret signs the deliveries; it is stored encrypted with the tenant's key and never returned
+	Secret string `json:"-" db:"secret" gdpr:"sensitive"`
+
+	// Events are the event types the webhook receives
+	Events []string `json:"events" db:"events"`
+
+	// Active tells whether events are delivered to the webhook
+	Active bool `json:"active" db:"active"`
+
+	// CreatedAt records when this webhook was created
+	CreatedAt time.Time `json:"created_at" db:"created_at"`
+
+	// UpdatedAt records when this webhook was last changed
+	UpdatedAt time.Time `json:"updated_at" db:"updated_at"`
+}
+
+// WebhookCreate is the request to register a webhook.
+type WebhookCreate struct {
+	// URL is where the events are posted to; it must use HTTPS
+	URL string `json:"url" validate:"required,max=2048"`
+
+	// Secret signs the deliveries, so the receiver can check they come from HideMe
+	Secret string `json:"secret" validate:"required,max=256"`
+
+	// Events are the event types to receive
+	Events []string `json:"events" validate:"required,min=1"`
+}
+
+// WebhookDelivery tracks the delivery of an event to a webhook.
+type WebhookDelivery struct {
+	// ID is the unique identifier for this delivery, sent in the X-HideMe-Delivery header
+	ID int64 `json:"id" db:"delivery_id"`
+
+	// WebhookID references the webhook
+	WebhookID int64 `json:"webhook_id" db:"webhook_id"`
+
+	// UserID references the user who owns the webhook
+	UserID int64 `json:"-" db:"user_id"`
+
+	// EventType is the type of the delivered event
+	EventType string `json:"event_type" db:"event_type"`
+
+	// Payload is the data of the event
+	Payload json.RawMessage `json:"payload" db:"payload"`
+
+	// Status is pending, delivered or dead
+	Status string `json:"status" db:"status"`
+
+	// Attempts is the number of times delivery was attempted
+	Attempts int `json:"attempts" db:"attempts"`
+
+	// NextAttemptAt is when a pending delivery is attempted next
+	NextAttemptAt time.Time `json:"next_attempt_at" db:"next_attempt_at"`
+
+	// LastStatusCode is the HTTP status the webhook last answered with; 0 if it didn't answer
+	LastStatusCode int `json:"last_status_code,omitempty" db:"last_status_code"`
+
+	// LastError describes why the last attempt failed; empty once delivered
+	LastError string `json:"last_error,omitempty" db:"last_error"`
+
+	// CreatedAt records when the event occurred
+	CreatedAt time.Time `json:"created_at" db:"created_at"`
+
+	// UpdatedAt records when delivery was last attempted
+	UpdatedAt time.Time `json:"updated_at" db:"updated_at"`
+
+	// DeliveredAt records when the webhook accepted the delivery; nil until then
+	DeliveredAt *time.Time `json:"delivered_at,omitempty" db:"delivered_at"`
+}
+
+// WebhookEvent is the body posted to a webhook. It is the same on every attempt of a
+// delivery, so receivers can drop the events they already handled by their ID.
+type WebhookEvent struct {
+	// ID is the ID of the delivery
+	ID int64 `json:"id"`
+
+	// Type is the event type, such as document.processed
+	Type string `json:"type"`
+
+	// CreatedAt records when the event occurred
+	CreatedAt time.Time `json:"created_at"`
+
+	// Data holds the event, in the shape of its type
+	Data json.RawMessage `json:"data"`
+}
+
+// DocumentProcessedEvent is the data of a document.processed event.
+type DocumentProcessedEvent struct {
+	// DocumentID is the ID of the saved document
+	DocumentID int64 `json:"document_id"`
+
+	// PageCount is the number of processed pages
+	PageCount int `json:"page_count"`
+
+	// EntityCount is the number of entities detected in the document
+	EntityCount int `json:"entity_count"`
+
+	// Source is where the document was uploaded from
+	Source string `json:"source,omitempty"`
+}
+
+// EntityDetectedEvent is the data of an entity.detected event.
+type EntityDetectedEvent struct {
+	// DocumentID is the ID of the document the entities were added to
+	DocumentID int64 `json:"document_id"`
+
+	// EntityIDs are the IDs of the added entities
+	EntityIDs []int64 `json:"entity_ids"`
+}
+
+// APIKeyCreatedEvent is the data of an apikey.created event. The key itself is never sent.
+type APIKeyCreatedEvent struct {
+	// KeyID is the ID of the created key
+	KeyID string `json:"key_id"`
+
+	// Name is the name of the key
+	Name string `json:"name"`
+
+	// Scopes are the scopes the key is limited to; empty for full access
+	Scopes []string `json:"scopes,omitempty"`
+
+	// RotatedFrom is the ID of the key this key replaced, if it was issued by a rotation
+	RotatedFrom *string `json:"rotated_from,omitempty"`
+
+	// ExpiresAt records when the key expires
+	ExpiresAt time.Time `json:"expires_at"`
+}
@@ -0,0 +1,660 @@
+// Package redaction applies the redaction schema of a document to its PDF.
+// This file contains the parsing of page content streams and the removal of the text,
+// images and forms they draw inside redaction areas.
+package redaction
+
+import (
+	"bytes"
+	"fmt"
+	"math"
+)
+
+// maxFormDepth bounds the nesting of form XObjects redacted inside each other.
+const maxFormDepth = 8
+
+// Minimum share of a glyph's width and height that must lie in a redaction area for the
+// glyph to be removed, so neighbouring characters touching the area are kept.
+const glyphOverlap = 0.25
+
+// operation is an operator of a content stream with its operands.
+type operation struct {
+	operands []any
+	op       string
+
+	// inline holds an inline image, from BI to EI, as it was read
+	inline []byte
+}
+
+// parseContent splits a content stream into its operations.
+func parseContent(data []byte) ([]operation, error) {
+	l := &lexer{data: data}
+	var ops []operation
+	var operands []any
+	for {
+		l.skipSpace()
+		if l.pos >= len(l.data) {
+			return ops, nil
+		}
+		start := l.pos
+		obj, err := l.object(false)
+		if err != nil {
+			return nil, fmt.Errorf("%w: content stream: %v", ErrInvalidPDF, err)
+		}
+		keyword, ok := obj.(pdfKeyword)
+		if !ok {
+			operands = append(operands, obj)
+			continue
+		}
+		switch keyword {
+		case ")", ">", "]", "{", "}":
+			// Stray delimiters are skipped, as viewers do
+			continue
+		case "BI":
+			end, err := inlineImageEnd(data, l.pos)
+			if err != nil {
+				return nil, err
+			}
+			ops = append(ops, operation{op: "BI", inline: data[start:end]})
+			l.pos = end
+			operands = nil
+			continue
+		}
+		ops = append(ops, operation{operands: operands, op: string(keyword)})
+		operands = nil
+	}
+}
+
+// inlineImageEnd returns the offset after the EI operator ending an inline image whose
+// dictionary starts at an offset.
+func inlineImageEnd(data []byte, pos int) (int, error) {
+	l := &lexer{data: data, pos: pos}
+	for {
+		l.skipSpace()
+		if l.pos >= len(data) {
+			return 0, fmt.Errorf("%w: unterminated inline image", ErrInvalidPDF)
+		}
+		obj, err := l.object(false)
+		if err != nil {
+			return 0, fmt.Errorf("%w: inline image: %v", ErrInvalidPDF, err)
+		}
+		if obj == pdfKeyword("ID") {
+			break
+		}
+	}
+	// The image data follows a single white-space character and ends at an EI operator
+	for i := l.pos + 1; i+2 <= len(data); i++ {
+		if data[i] != 'E' || data[i+1] != 'I' || !isWhite(data[i-1]) {
+			continue
+		}
+		if i+2 == len(data) || isWhite(data[i+2]) || isDelim(data[i+2]) {
+			return i + 2, nil
+		}
+	}
+	return 0, fmt.Errorf("%w: unterminated inline image", ErrInvalidPDF)
+}
+
+// writeContent serializes operations into a content stream.
+func writeContent(ops []operation) []byte {
+	var buf bytes.Buffer
+	for _, op := range ops {
+		if op.inline != nil {
+			buf.Write(op.inline)
+			buf.WriteByte('\n')
+			continue
+		}
+		for _, operand := range op.operands {
+			writeObject(&buf, operand, nil)
+			buf.WriteByte(' ')
+		}
+		buf.WriteString(op.op)
+		buf.WriteByte('\n')
+	}
+	return buf.Bytes()
+}
+
+// matrix is a PDF transformation matrix [a b c d e f].
+type matrix [6]float64
+
+// identity is the identity matrix.
+var identity = matrix{1, 0, 0, 1, 0, 0}
+
+// multiply returns m × n, the transformation m followed by n.
+func (m matrix) multiply(n matrix) matrix {
+	return matrix{
+		m[0]*n[0] + m[1]*n[2],
+		m[0]*n[1] + m[1]*n[3],
+		m[2]*n[0] + m[3]*n[2],
+		m[2]*n[1] + m[3]*n[3],
+		m[4]*n[0] + m[5]*n[2] + n[4],
+		m[4]*n[1] + m[5]*n[3] + n[5],
+	}
+}
+
+// apply transforms a point.
+func (m matrix) apply(x, y float64) (float64, float64) {
+	return m[0]*x + m[2]*y + m[4], m[1]*x + m[3]*y + m[5]
+}
+
+// toMatrix reads six numbers as a matrix.
+func toMatrix(values []any) (matrix, bool) {
+	if len(values) != 6 {
+		return identity, false
+	}
+	var m matrix
+	for i, v := range values {
+		f, ok := toFloat(v)
+		if !ok {
+			return identity, false
+		}
+		m[i] = f
+	}
+	return m, true
+}
+
+// rect is an axis-aligned rectangle in user space.
+type rect struct {
+	x0, y0, x1, y1 float64
+}
+
+// transformRect returns the bounding box of a rectangle transformed by a matrix.
+func transformRect(r rect, m matrix) rect {
+	out := rect{math.Inf(1), math.Inf(1), math.Inf(-1), math.Inf(-1)}
+	for _, p := range [][2]float64{{r.x0, r.y0}, {r.x1, r.y0}, {r.x0, r.y1}, {r.x1, r.y1}} {
+		x, y := m.apply(p[0], p[1])
+		out.x0, out.y0 = min(out.x0, x), min(out.y0, y)
+		out.x1, out.y1 = max(out.x1, x), max(out.y1, y)
+	}
+	return out
+}
+
+// overlap returns the share of a rectangle's width and height covered by another.
+func (r rect) overlap(area rect) (float64, float64) {
+	w := min(r.x1, area.x1) - max(r.x0, area.x0)
+	h := min(r.y1, area.y1) - max(r.y0, area.y0)
+	if w <= 0 || h <= 0 {
+		return 0, 0
+	}
+	rw, rh := r.x1-r.x0, r.y1-r.y0
+	if rw <= 0 || rh <= 0 {
+		return 1, 1
+	}
+	return w / rw, h / rh
+}
+
+// intersects reports whether two rectangles overlap.
+func (r rect) intersects(area rect) bool {
+	w, h := r.overlap(area)
+	return w > 0 && h > 0
+}
+
+// graphicsState is the part of the graphics state needed to place text and images.
+type graphicsState struct {
+	ctm       matrix
+	font      *font
+	fontSize  float64
+	charSpace float64
+	wordSpace float64
+	scale     float64
+	leading   float64
+	rise      float64
+}
+
+// redactionStats counts what a redaction removed.
+type redactionStats struct {
+	glyphs      int
+	images      int
+	annotations int
+}
+
+// contentRedactor removes the content drawn inside redaction areas from content streams.
+type contentRedactor struct {
+	doc   *document
+	areas []rect
+	fonts map[any]*font
+	stats *redactionStats
+	names int
+}
+
+// resourceScope holds the resources of a content stream, copied when they change.
+type resourceScope struct {
+	original pdfDict
+	copied   pdfDict
+
+	// removed and drawn record the XObjects removed from the content and those still drawn
+	removed map[pdfName]bool
+	drawn   map[pdfName]bool
+}
+
+// prune deletes the XObjects that were removed everywhere from the resources, so the
+// redacted images and forms are not kept in the file.
+func (r *contentRedactor) prune(scope *resourceScope) {
+	var unused []pdfName
+	for name := range scope.removed {
+		if !scope.drawn[name] {
+			unused = append(unused, name)
+		}
+	}
+	if len(unused) == 0 {
+		return
+	}
+	r.copyScope(scope)
+	entries := pdfDict{}
+	for k, v := range r.doc.getDict(scope.copied["XObject"]) {
+		entries[k] = v
+	}
+	for _, name := range unused {
+		delete(entries, name)
+	}
+	scope.copied["XObject"] = entries
+}
+
+// copyScope copies the resources of a scope before they are changed.
+func (r *contentRedactor) copyScope(scope *resourceScope) {
+	if scope.copied != nil {
+		return
+	}
+	scope.copied = pdfDict{}
+	for k, v := range scope.original {
+		scope.copied[k] = v
+	}
+}
+
+// record notes whether an XObject of a scope was removed from the content or drawn.
+func (s *resourceScope) record(name pdfName, drawn bool) {
+	if s.removed == nil {
+		s.removed, s.drawn = map[pdfName]bool{}, map[pdfName]bool{}
+	}
+	if drawn {
+		s.drawn[name] = true
+	} else {
+		s.removed[name] = true
+	}
+}
+
+// lookup returns an entry of a resource category, such as a font by its name.
+func (r *contentRedactor) lookup(scope *resourceScope, category, name pdfName) any {
+	resources := scope.original
+	if scope.copied != nil {
+		resources = scope.copied
+	}
+	entries := r.doc.getDict(resources[category])
+	if entries == nil {
+		return nil
+	}
+	return entries[name]
+}
+
+// addResource adds an entry to a resource category under a new name and returns the name.
+func (r *contentRedactor) addResource(scope *resourceScope, category pdfName, value any) pdfName {
+	r.copyScope(scope)
+	entries := pdfDict{}
+	for k, v := range r.doc.getDict(scope.copied[category]) {
+		entries[k] = v
+	}
+	var name pdfName
+	for {
+		r.names++
+		name = pdfName(fmt.Sprintf("HmRed%d", r.names))
+		if _, taken := entries[name]; !taken {
+			break
+		}
+	}
+	entries[name] = value
+	scope.copied[category] = entries
+	return name
+}
+
+// fontFor returns the metrics of a font resource.
+func (r *contentRedactor) fontFor(scope *resourceScope, name pdfName) *font {
+	ref := r.lookup(scope, "Font", name)
+	if ref == nil {
+		return defaultFont
+	}
+	key := any(ref)
+	if _, ok := ref.(pdfRef); !ok {
+		key = fmt.Sprintf("%p", r.doc.getDict(ref))
+	}
+	if f, ok := r.fonts[key]; ok {
+		return f
+	}
+	f := r.doc.loadFont(r.doc.getDict(ref))
+	r.fonts[key] = f
+	return f
+}
+
+// redact removes what the operations draw inside the redaction areas. It returns the new
+// operations and whether any were changed.
+func (r *contentRedactor) redact(ops []operation, scope *resourceScope, ctm matrix, depth int) ([]operation, bool, error) {
+	gs := graphicsState{ctm: ctm, font: defaultFont, scale: 1}
+	var stack []graphicsState
+	var tm, tlm matrix
+	changed := false
+	out := make([]operation, 0, len(ops))
+
+	for _, op := range ops {
+		nums := make([]float64, len(op.operands))
+		for i, operand := range op.operands {
+			nums[i], _ = toFloat(operand)
+		}
+		last := func(n int) bool { return len(nums) >= n }
+
+		switch op.op {
+		case "q":
+			stack = append(stack, gs)
+		case "Q":
+			if len(stack) > 0 {
+				gs = stack[len(stack)-1]
+				stack = stack[:len(stack)-1]
+			}
+		case "cm":
+			if m, ok := toMatrix(op.operands); ok {
+				gs.ctm = m.multiply(gs.ctm)
+			}
+		case "BT":
+			tm, tlm = identity, identity
+		case "Tc":
+			if last(1) {
+				gs.charSpace = nums[len(nums)-1]
+			}
+		case "Tw":
+			if last(1) {
+				gs.wordSpace = nums[len(nums)-1]
+			}
+		case "Tz":
+			if last(1) {
+				gs.scale = nums[len(nums)-1] / 100
+			}
+		case "TL":
+			if last(1) {
+				gs.leading = nums[len(nums)-1]
+			}
+		case "Ts":
+			if last(1) {
+				gs.rise = nums[len(nums)-1]
+			}
+		case "Tf":
+			if len(op.operands) >= 2 {
+				if name, ok := op.operands[len(op.operands)-2].(pdfName); ok {
+					gs.font = r.fontFor(scope, name)
+				}
+				gs.fontSize = nums[len(nums)-1]
+			}
+		case "Td", "TD":
+			if last(2) {
+				tx, ty := nums[len(nums)-2], nums[len(nums)-1]
+				if op.op == "TD" {
+					gs.leading = -ty
+				}
+				tlm = matrix{1, 0, 0, 1, tx, ty}.multiply(tlm)
+				tm = tlm
+			}
+		case "Tm":
+			if m, ok := toMatrix(op.operands); ok {
+				tm, tlm = m, m
+			}
+		case "T*":
+			tlm = matrix{1, 0, 0, 1, 0, -gs.leading}.multiply(tlm)
+			tm = tlm
+		case "Tj", "TJ", "'", "\"":
+			if op.op == "\"" && last(3) {
+				gs.wordSpace, gs.charSpace = nums[0], nums[1]
+			}
+			if op.op == "'" || op.op == "\"" {
+				tlm = matrix{1, 0, 0, 1, 0, -gs.leading}.multiply(tlm)
+				tm = tlm
+			}
+			var items pdfArray
+			if len(op.operands) > 0 {
+				switch v := op.operands[len(op.operands)-1].(type) {
+				case pdfArray:
+					items = v
+				case pdfString:
+					items = pdfArray{v}
+				}
+			}
+			kept, removed := r.showText(&gs, &tm, items)
+			if removed == 0 {
+				break
+			}
+			changed = true
+			// Quoting operators also move to the next line and set the spacing
+			if op.op == "\"" && last(3) {
+				out = append(out,
+					operation{operands: []any{op.operands[0]}, op: "Tw"},
+					operation{operands: []any{op.operands[1]}, op: "Tc"})
+			}
+			if op.op == "'" || op.op == "\"" {
+				out = append(out, operation{op: "T*"})
+			}
+			out = append(out, operation{operands: []any{kept}, op: "TJ"})
+			continue
+		case "Do":
+			if len(op.operands) == 0 {
+				break
+			}
+			name, _ := op.operands[0].(pdfName)
+			replaced, keep, err := r.redactXObject(scope, name, gs.ctm, depth)
+			if err != nil {
+				return nil, false, err
+			}
+			if !keep {
+				scope.record(name, false)
+				changed = true
+				continue
+			}
+			if replaced != "" {
+				scope.record(name, false)
+				changed = true
+				out = append(out, operation{operands: []any{replaced}, op: "Do"})
+				continue
+			}
+			scope.record(name, true)
+		case "BI":
+			if r.inArea(transformRect(rect{0, 0, 1, 1}, gs.ctm)) {
+				r.stats.images++
+				changed = true
+				continue
+			}
+		case "BDC":
+			// Replacement and alternate texts of marked content would repeat redacted text
+			if len(op.operands) == 2 {
+				if props, ok := op.operands[1].(pdfDict); ok && hasAnyKey(props, "ActualText", "Alt", "E") {
+					stripped := pdfDict{}
+					for k, v := range props {
+						if k != "ActualText" && k != "Alt" && k != "E" {
+							stripped[k] = v
+						}
+					}
+					changed = true
+					out = append(out, operation{operands: []any{op.operands[0], stripped}, op: "BDC"})
+					continue
+				}
+			}
+		}
+		out = append(out, op)
+	}
+	return out, changed, nil
+}
+
+// hasAnyKey reports whether a dictionary has any of the keys.
+func hasAnyKey(d pdfDict, keys ...pdfName) bool {
+	for _, k := range keys {
+		if _, ok := d[k]; ok {
+			return true
+		}
+	}
+	return false
+}
+
+// inArea reports whether a rectangle touches any redaction area.
+func (r *contentRedactor) inArea(box rect) bool {
+	for _, area := range r.areas {
+		if box.intersects(area) {
+			return true
+		}
+	}
+	return false
+}
+
+// glyphInArea reports whether enough of a glyph lies in a redaction area to remove it.
+func (r *contentRedactor) glyphInArea(box rect) bool {
+	for _, area := range r.areas {
+		w, h := box.overlap(area)
+		if w >= glyphOverlap && h >= glyphOverlap {
+			return true
+		}
+	}
+	return false
+}
+
+// showText places the glyphs of a text-showing operation and drops those in redaction
+// areas. It returns the operand of a TJ operator drawing the remaining glyphs at their
+// original positions, and the number of glyphs removed.
+func (r *contentRedactor) showText(gs *graphicsState, tm *matrix, items pdfArray) (pdfArray, int) {
+	var kept pdfArray
+	var run []byte
+	adjust := 0.0
+	removed := 0
+	flush := func() {
+		if len(run) > 0 {
+			kept = append(kept, pdfString(run))
+			run = nil
+		}
+	}
+	unit := gs.fontSize * gs.scale
+
+	for _, item := range items {
+		if n, ok := toFloat(item); ok {
+			*tm = matrix{1, 0, 0, 1, -n / 1000 * unit, 0}.multiply(*tm)
+			flush()
+			adjust += n
+			continue
+		}
+		s, ok := item.(pdfString)
+		if !ok {
+			continue
+		}
+		for _, code := range gs.font.codes(s) {
+			w0 := gs.font.width(code)
+			advance := w0*gs.fontSize + gs.charSpace
+			if !gs.font.twoByte && code == ' ' {
+				advance += gs.wordSpace
+			}
+			advance *= gs.scale
+
+			trm := matrix{unit, 0, 0, gs.fontSize, 0, gs.rise}.multiply(*tm).multiply(gs.ctm)
+			box := transformRect(rect{0, glyphDescent, w0, glyphAscent}, trm)
+			if unit != 0 && r.glyphInArea(box) {
+				// The glyph is replaced by a move over its width
+				flush()
+				adjust -= advance / unit * 1000
+				removed++
+			} else {
+				if adjust != 0 {
+					flush()
+					kept = append(kept, adjust)
+					adjust = 0
+				}
+				run = append(run, gs.font.codeBytes(code)...)
+			}
+			*tm = matrix{1, 0, 0, 1, advance, 0}.multiply(*tm)
+		}
+	}
+	flush()
+	if adjust != 0 {
+		kept = append(kept, adjust)
+	}
+	r.stats.glyphs += removed
+	return kept, removed
+}
+
+// redactXObject handles an XObject drawn over the page. Images in a redaction area are
+// removed. Forms in a redaction area are redacted like the page: a redacted copy of the form
+// is added to the resources and its new name returned. keep is false if the XObject must
+// not be drawn at all.
+func (r *contentRedactor) redactXObject(scope *resourceScope, name pdfName, ctm matrix, depth int) (pdfName, bool, error) {
+	ref := r.lookup(scope, "XObject", name)
+	stream, ok := r.doc.get(ref).(*pdfStream)
+	if !ok {
+		return "", true, nil
+	}
+
+	switch r.doc.get(stream.dict["Subtype"]) {
+	case pdfName("Image"):
+		if r.inArea(transformRect(rect{0, 0, 1, 1}, ctm)) {
+			r.stats.images++
+			return "", false, nil
+		}
+		return "", true, nil
+	case pdfName("Form"):
+	default:
+		return "", true, nil
+	}
+
+	formMatrix := identity
+	if arr, ok := r.doc.get(stream.dict["Matrix"]).(pdfArray); ok {
+		values := make([]any, len(arr))
+		for i, v := range arr {
+			values[i] = r.doc.get(v)
+		}
+		if m, ok := toMatrix(values); ok {
+			formMatrix = m
+		}
+	}
+	formCTM := formMatrix.multiply(ctm)
+	if bbox, ok := r.doc.get(stream.dict["BBox"]).(pdfArray); ok && len(bbox) == 4 {
+		var c [4]float64
+		for i, v := range bbox {
+			c[i], _ = toFloat(r.doc.get(v))
+		}
+		box := rect{min(c[0], c[2]), min(c[1], c[3]), max(c[0], c[2]), max(c[1], c[3])}
+		if !r.inArea(transformRect(box, formCTM)) {
+			return "", true, nil
+		}
+	}
+
+	// Forms nested too deeply, or whose content cannot be read, are removed entirely
+	if depth >= maxFormDepth {
+		r.stats.images++
+		return "", false, nil
+	}
+	data, err := r.doc.decodeStream(stream)
+	if err != nil {
+		r.stats.images++
+		return "", false, nil
+	}
+	ops, err := parseContent(data)
+	if err != nil {
+		r.stats.images++
+		return "", false, nil
+	}
+
+	// Forms without resources use those of the content drawing them
+	formScope := &resourceScope{original: r.doc.getDict(stream.dict["Resources"])}
+	if formScope.original == nil {
+		formScope = scope
+	}
+	redacted, changed, err := r.redact(ops, formScope, formCTM, depth+1)
+	if err != nil {
+		return "", false, err
+	}
+	if !changed {
+		return "", true, nil
+	}
+	if formScope != scope {
+		r.prune(formScope)
+	}
+
+	dict := pdfDict{}
+	for k, v := range stream.dict {
+		if k != "Filter" && k != "DecodeParms" && k != "Length" {
+			dict[k] = v
+		}
+	}
+	dict["Filter"] = pdfName("FlateDecode")
+	if formScope != scope && formScope.copied != nil {
+		dict["Resources"] = formScope.copied
+	}
+	copyRef := r.doc.add(&pdfStream{dict: dict, data: deflate(writeContent(redacted))})
+	return r.addResource(scope, "XObject", copyRef), true, nil
+}
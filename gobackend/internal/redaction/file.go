@@ -0,0 +1,834 @@
+// Package redaction applies the redaction schema of a document to its PDF.
+// This file contains the reading of PDF files through their cross-reference data, the
+// decoding of stream filters, and the rewriting of a file with only its objects in use.
+package redaction
+
+import (
+	"bytes"
+	"compress/zlib"
+	"errors"
+	"fmt"
+	"io"
+	"regexp"
+	"strconv"
+)
+
+var (
+	// ErrInvalidPDF is returned when a file cannot be read as a PDF.
+	ErrInvalidPDF = errors.New("invalid or damaged PDF")
+
+	// ErrEncryptedPDF is returned for encrypted PDFs, whose content cannot be redacted.
+	ErrEncryptedPDF = errors.New("encrypted PDFs cannot be redacted")
+
+	// ErrUnsupportedPDF is returned when a PDF uses features the redaction cannot handle,
+	// such as a content stream compressed with an unsupported filter.
+	ErrUnsupportedPDF = errors.New("unsupported PDF")
+)
+
+// maxResolveDepth bounds the nesting of references resolved while reading a file,
+// so reference loops in damaged files cannot recurse forever.
+const maxResolveDepth = 32
+
+// objectLocation is where an object is stored: at an offset of the file, or inside an
+// object stream.
+type objectLocation struct {
+	offset    int
+	streamNum int
+	index     int
+	inStream  bool
+}
+
+// document is a parsed PDF file. Objects are read lazily when they are resolved.
+type document struct {
+	data      []byte
+	locations map[int]objectLocation
+	objects   map[int]any
+	trailer   pdfDict
+	nextNum   int
+}
+
+// objHeader matches the start of an indirect object, used to rebuild the cross-reference
+// data of damaged files.
+var objHeader = regexp.MustCompile(`(?m)(\d+)\s+(\d+)\s+obj\b`)
+
+// parseDocument reads the structure of a PDF file.
+func parseDocument(data []byte) (*document, error) {
+	if !bytes.HasPrefix(bytes.TrimLeft(data, "\x00\t\n\f\r "), []byte("%PDF-")) {
+		return nil, fmt.Errorf("%w: missing PDF header", ErrInvalidPDF)
+	}
+
+	doc := &document{
+		data:      data,
+		locations: map[int]objectLocation{},
+		objects:   map[int]any{},
+	}
+	if err := doc.readXref(); err != nil {
+		// Damaged cross-reference data is rebuilt from the objects of the file, as viewers do
+		if err := doc.scanObjects(); err != nil {
+			return nil, err
+		}
+	}
+	if _, ok := doc.trailer["Encrypt"]; ok {
+		return nil, ErrEncryptedPDF
+	}
+	if _, ok := doc.trailer["Root"].(pdfRef); !ok {
+		return nil, fmt.Errorf("%w: missing document catalog", ErrInvalidPDF)
+	}
+
+	for num := range doc.locations {
+		if num >= doc.nextNum {
+			doc.nextNum = num + 1
+		}
+	}
+	return doc, nil
+}
+
+// readXref reads the cross-reference sections of the file, newest first.
+func (d *document) readXref() error {
+	idx := bytes.LastIndex(d.data, []byte("startxref"))
+	if idx < 0 {
+		return fmt.Errorf("%w: missing startxref", ErrInvalidPDF)
+	}
+	l := &lexer{data: d.data, pos: idx + len("startxref")}
+	l.skipSpace()
+	offset, err := strconv.Atoi(l.regular())
+	if err != nil {
+		return fmt.Errorf("%w: invalid startxref", ErrInvalidPDF)
+	}
+
+	seen := map[int]bool{}
+	for offset > 0 && !seen[offset] {
+		seen[offset] = true
+		if offset >= len(d.data) {
+			return fmt.Errorf("%w: cross-reference offset out of range", ErrInvalidPDF)
+		}
+
+		var trailer pdfDict
+		l := &lexer{data: d.data, pos: offset}
+		l.skipSpace()
+		if bytes.HasPrefix(d.data[l.pos:], []byte("xref")) {
+			trailer, err = d.readXrefTable(l)
+		} else {
+			trailer, err = d.readXrefStream(offset)
+		}
+		if err != nil {
+			return err
+		}
+
+		if d.trailer == nil {
+			d.trailer = trailer
+		}
+		// Hybrid files keep the objects of their object streams in an extra section
+		if stm, ok := toInt(trailer["XRefStm"]); ok && !seen[stm] {
+			seen[stm] = true
+			if _, err := d.readXrefStream(stm); err != nil {
+				return err
+			}
+		}
+		prev, ok := toInt(trailer["Prev"])
+		if !ok {
+			break
+		}
+		offset = prev
+	}
+	if d.trailer == nil {
+		return fmt.Errorf("%w: missing trailer", ErrInvalidPDF)
+	}
+	return nil
+}
+
+// readXrefTable reads a classic cross-reference table and its trailer.
+func (d *document) readXrefTable(l *lexer) (pdfDict, error) {
+	l.pos += len("xref")
+	for {
+		l.skipSpace()
+		if bytes.HasPrefix(d.data[l.pos:], []byte("trailer")) {
+			l.pos += len("trailer")
+			obj, err := l.object(true)
+			if err != nil {
+				return nil, fmt.Errorf("%w: %v", ErrInvalidPDF, err)
+			}
+			trailer, ok := obj.(pdfDict)
+			if !ok {
+				return nil, fmt.Errorf("%w: invalid trailer", ErrInvalidPDF)
+			}
+			return trailer, nil
+		}
+
+		start, err1 := strconv.Atoi(l.regular())
+		l.skipSpace()
+		count, err2 := strconv.Atoi(l.regular())
+		if err1 != nil || err2 != nil || count < 0 {
+			return nil, fmt.Errorf("%w: invalid cross-reference table", ErrInvalidPDF)
+		}
+		for i := 0; i < count; i++ {
+			l.skipSpace()
+			offset, err1 := strconv.Atoi(l.regular())
+			l.skipSpace()
+			_, err2 := strconv.Atoi(l.regular())
+			l.skipSpace()
+			kind := l.regular()
+			if err1 != nil || err2 != nil || (kind != "n" && kind != "f") {
+				return nil, fmt.Errorf("%w: invalid cross-reference entry", ErrInvalidPDF)
+			}
+			num := start + i
+			if _, known := d.locations[num]; known || kind != "n" {
+				continue
+			}
+			d.locations[num] = objectLocation{offset: offset}
+		}
+	}
+}
+
+// readXrefStream reads a cross-reference stream at an offset and returns its dictionary.
+func (d *document) readXrefStream(offset int) (pdfDict, error) {
+	_, obj, err := d.readObjectAt(offset)
+	if err != nil {
+		return nil, err
+	}
+	stream, ok := obj.(*pdfStream)
+	if !ok || stream.dict["Type"] != pdfName("XRef") {
+		return nil, fmt.Errorf("%w: invalid cross-reference stream", ErrInvalidPDF)
+	}
+	data, err := d.decodeStream(stream)
+	if err != nil {
+		return nil, err
+	}
+
+	widths, ok := stream.dict["W"].(pdfArray)
+	if !ok || len(widths) != 3 {
+		return nil, fmt.Errorf("%w: invalid cross-reference stream widths", ErrInvalidPDF)
+	}
+	var w [3]int
+	for i, v := range widths {
+		w[i], _ = toInt(v)
+		if w[i] < 0 || w[i] > 8 {
+			return nil, fmt.Errorf("%w: invalid cross-reference stream widths", ErrInvalidPDF)
+		}
+	}
+	size, _ := toInt(stream.dict["Size"])
+	index := pdfArray{int64(0), int64(size)}
+	if arr, ok := stream.dict["Index"].(pdfArray); ok {
+		index = arr
+	}
+
+	entry := w[0] + w[1] + w[2]
+	pos := 0
+	for i := 0; i+1 < len(index); i += 2 {
+		start, _ := toInt(index[i])
+		count, _ := toInt(index[i+1])
+		for j := 0; j < count; j++ {
+			if entry == 0 || pos+entry > len(data) {
+				return stream.dict, nil
+			}
+			field := func(k, from int) int {
+				v := 0
+				for _, c := range data[from : from+w[k]] {
+					v = v<<8 | int(c)
+				}
+				return v
+			}
+			kind := 1
+			if w[0] > 0 {
+				kind = field(0, pos)
+			}
+			f2 := field(1, pos+w[0])
+			f3 := field(2, pos+w[0]+w[1])
+			pos += entry
+
+			num := start + j
+			if _, known := d.locations[num]; known {
+				continue
+			}
+			switch kind {
+			case 1:
+				d.locations[num] = objectLocation{offset: f2}
+			case 2:
+				d.locations[num] = objectLocation{streamNum: f2, index: f3, inStream: true}
+			}
+		}
+	}
+	return stream.dict, nil
+}
+
+// scanObjects rebuilds the cross-reference data of a damaged file from the objects it
+// contains. Later definitions of an object replace earlier ones, as in incremental updates.
+func (d *document) scanObjects() error {
+	d.locations = map[int]objectLocation{}
+	d.objects = map[int]any{}
+	d.trailer = nil
+	for _, m := range objHeader.FindAllSubmatchIndex(d.data, -1) {
+		// Headers must start a line, or they are part of another object
+		if m[0] > 0 && !isWhite(d.data[m[0]-1]) {
+			continue
+		}
+		num, _ := strconv.Atoi(string(d.data[m[2]:m[3]]))
+		d.locations[num] = objectLocation{offset: m[0]}
+	}
+
+	// Objects of object streams are only known from the streams themselves, and the last
+	// cross-reference stream or trailer of the file describes its latest revision
+	trailerOffset := -1
+	for num, loc := range d.locations {
+		if loc.inStream {
+			continue
+		}
+		obj, err := d.resolve(pdfRef{num: num})
+		if err != nil {
+			continue
+		}
+		stream, ok := obj.(*pdfStream)
+		if !ok {
+			continue
+		}
+		switch stream.dict["Type"] {
+		case pdfName("ObjStm"):
+			nums, _, err := d.objectStreamIndex(stream)
+			if err != nil {
+				continue
+			}
+			for i, n := range nums {
+				if _, known := d.locations[n]; !known {
+					d.locations[n] = objectLocation{streamNum: num, index: i, inStream: true}
+				}
+			}
+		case pdfName("XRef"):
+			if loc.offset > trailerOffset {
+				d.trailer = stream.dict
+				trailerOffset = loc.offset
+			}
+		}
+	}
+	if idx := bytes.LastIndex(d.data, []byte("trailer")); idx > trailerOffset {
+		l := &lexer{data: d.data, pos: idx + len("trailer")}
+		if obj, err := l.object(true); err == nil {
+			if trailer, ok := obj.(pdfDict); ok {
+				d.trailer = trailer
+			}
+		}
+	}
+	if d.trailer == nil {
+		d.trailer = pdfDict{}
+	}
+	if _, ok := d.trailer["Root"]; !ok {
+		for num := range d.locations {
+			if obj, err := d.resolve(pdfRef{num: num}); err == nil {
+				if dict, ok := obj.(pdfDict); ok && dict["Type"] == pdfName("Catalog") {
+					d.trailer["Root"] = pdfRef{num: num}
+					break
+				}
+			}
+		}
+	}
+	if len(d.locations) == 0 {
+		return fmt.Errorf("%w: no objects found", ErrInvalidPDF)
+	}
+	return nil
+}
+
+// readObjectAt reads the indirect object starting at an offset.
+func (d *document) readObjectAt(offset int) (int, any, error) {
+	if offset < 0 || offset >= len(d.data) {
+		return 0, nil, fmt.Errorf("%w: object offset out of range", ErrInvalidPDF)
+	}
+	l := &lexer{data: d.data, pos: offset}
+	l.skipSpace()
+	num, err := strconv.Atoi(l.regular())
+	if err != nil {
+		return 0, nil, fmt.Errorf("%w: invalid object header", ErrInvalidPDF)
+	}
+	l.skipSpace()
+	l.regular()
+	l.skipSpace()
+	if l.regular() != "obj" {
+		return 0, nil, fmt.Errorf("%w: invalid object header", ErrInvalidPDF)
+	}
+
+	obj, err := l.object(true)
+	if err != nil {
+		return 0, nil, fmt.Errorf("%w: %v", ErrInvalidPDF, err)
+	}
+	dict, ok := obj.(pdfDict)
+	if !ok {
+		return num, obj, nil
+	}
+	l.skipSpace()
+	if !bytes.HasPrefix(d.data[l.pos:], []byte("stream")) {
+		return num, obj, nil
+	}
+
+	// The data starts after the end-of-line following the keyword
+	start := l.pos + len("stream")
+	if start < len(d.data) && d.data[start] == '\r' {
+		start++
+	}
+	if start < len(d.data) && d.data[start] == '\n' {
+		start++
+	}
+	end := -1
+	if length, ok := d.streamLength(dict); ok && start+length <= len(d.data) {
+		end = start + length
+		// Lengths of damaged files are checked against the end of the stream
+		rest := bytes.TrimLeft(d.data[end:], "\x00\t\n\f\r ")
+		if !bytes.HasPrefix(rest, []byte("endstream")) {
+			end = -1
+		}
+	}
+	if end < 0 {
+		idx := bytes.Index(d.data[start:], []byte("endstream"))
+		if idx < 0 {
+			return 0, nil, fmt.Errorf("%w: unterminated stream", ErrInvalidPDF)
+		}
+		end = start + idx
+		// The end-of-line before the keyword is not part of the data
+		if end > start && d.data[end-1] == '\n' {
+			end--
+		}
+		if end > start && d.data[end-1] == '\r' {
+			end--
+		}
+	}
+	return num, &pdfStream{dict: dict, data: d.data[start:end]}, nil
+}
+
+// streamLength returns the /Length of a stream, which may be an indirect object.
+func (d *document) streamLength(dict pdfDict) (int, bool) {
+	v := dict["Length"]
+	if ref, ok := v.(pdfRef); ok {
+		loc, known := d.locations[ref.num]
+		if !known || loc.inStream {
+			return 0, false
+		}
+		_, obj, err := d.readObjectAt(loc.offset)
+		if err != nil {
+			return 0, false
+		}
+		v = obj
+	}
+	return toInt(v)
+}
+
+// resolve returns the object a reference points to; other objects are returned as is.
+// References to missing objects resolve to null.
+func (d *document) resolve(v any) (any, error) {
+	for depth := 0; depth < maxResolveDepth; depth++ {
+		ref, ok := v.(pdfRef)
+		if !ok {
+			return v, nil
+		}
+		obj, err := d.load(ref.num)
+		if err != nil {
+			return nil, err
+		}
+		v = obj
+	}
+	return nil, fmt.Errorf("%w: reference loop", ErrInvalidPDF)
+}
+
+// get resolves an object, ignoring errors: damaged parts of a file read as null.
+func (d *document) get(v any) any {
+	obj, _ := d.resolve(v)
+	return obj
+}
+
+// getDict resolves a dictionary, or the dictionary of a stream.
+func (d *document) getDict(v any) pdfDict {
+	switch o := d.get(v).(type) {
+	case pdfDict:
+		return o
+	case *pdfStream:
+		return o.dict
+	}
+	return nil
+}
+
+// load reads an object by its number.
+func (d *document) load(num int) (any, error) {
+	if obj, ok := d.objects[num]; ok {
+		return obj, nil
+	}
+	loc, ok := d.locations[num]
+	if !ok {
+		return nil, nil
+	}
+	// Mark the object as being read, so reference loops end
+	d.objects[num] = nil
+
+	var obj any
+	if loc.inStream {
+		container, err := d.load(loc.streamNum)
+		if err != nil {
+			return nil, err
+		}
+		stream, ok := container.(*pdfStream)
+		if !ok {
+			return nil, fmt.Errorf("%w: object stream %d not found", ErrInvalidPDF, loc.streamNum)
+		}
+		nums, offsets, err := d.objectStreamIndex(stream)
+		if err != nil {
+			return nil, err
+		}
+		if loc.index >= len(nums) {
+			return nil, fmt.Errorf("%w: object %d missing from its object stream", ErrInvalidPDF, num)
+		}
+		data, _ := d.decodeStream(stream)
+		l := &lexer{data: data, pos: offsets[loc.index]}
+		if obj, err = l.object(true); err != nil {
+			return nil, fmt.Errorf("%w: %v", ErrInvalidPDF, err)
+		}
+	} else {
+		got, o, err := d.readObjectAt(loc.offset)
+		if err != nil {
+			return nil, err
+		}
+		if got != num {
+			return nil, fmt.Errorf("%w: object %d not at its offset", ErrInvalidPDF, num)
+		}
+		obj = o
+	}
+
+	d.objects[num] = obj
+	return obj, nil
+}
+
+// objectStreamIndex reads the numbers and offsets of the objects of an object stream.
+func (d *document) objectStreamIndex(stream *pdfStream) ([]int, []int, error) {
+	data, err := d.decodeStream(stream)
+	if err != nil {
+		return nil, nil, err
+	}
+	n, _ := toInt(d.get(stream.dict["N"]))
+	first, _ := toInt(d.get(stream.dict["First"]))
+	if n < 0 || first < 0 || first > len(data) {
+		return nil, nil, fmt.Errorf("%w: invalid object stream", ErrInvalidPDF)
+	}
+
+	l := &lexer{data: data[:first]}
+	nums := make([]int, 0, n)
+	offsets := make([]int, 0, n)
+	for i := 0; i < n; i++ {
+		l.skipSpace()
+		num, err1 := strconv.Atoi(l.regular())
+		l.skipSpace()
+		offset, err2 := strconv.Atoi(l.regular())
+		if err1 != nil || err2 != nil || first+offset > len(data) {
+			return nil, nil, fmt.Errorf("%w: invalid object stream", ErrInvalidPDF)
+		}
+		nums = append(nums, num)
+		offsets = append(offsets, first+offset)
+	}
+	return nums, offsets, nil
+}
+
+// decodeStream returns the data of a stream with its filters removed.
+func (d *document) decodeStream(stream *pdfStream) ([]byte, error) {
+	filters := d.get(stream.dict["Filter"])
+	params := d.get(stream.dict["DecodeParms"])
+	var filterList, paramList pdfArray
+	switch f := filters.(type) {
+	case nil:
+		return stream.data, nil
+	case pdfName:
+		filterList = pdfArray{f}
+		paramList = pdfArray{params}
+	case pdfArray:
+		filterList = f
+		if p, ok := params.(pdfArray); ok {
+			paramList = p
+		}
+	default:
+		return nil, fmt.Errorf("%w: invalid stream filter", ErrInvalidPDF)
+	}
+
+	data := stream.data
+	for i, f := range filterList {
+		var p pdfDict
+		if i < len(paramList) {
+			p = d.getDict(paramList[i])
+		}
+		var err error
+		switch d.get(f) {
+		case pdfName("FlateDecode"), pdfName("Fl"):
+			data, err = inflate(data)
+			if err == nil {
+				data, err = d.unpredict(data, p)
+			}
+		case pdfName("ASCIIHexDecode"), pdfName("AHx"):
+			data, err = decodeASCIIHex(data)
+		case pdfName("ASCII85Decode"), pdfName("A85"):
+			data, err = decodeASCII85(data)
+		default:
+			return nil, fmt.Errorf("%w: stream filter %v", ErrUnsupportedPDF, f)
+		}
+		if err != nil {
+			return nil, err
+		}
+	}
+	return data, nil
+}
+
+// inflate decompresses zlib data. Truncated streams keep the data read before the damage,
+// as viewers show it.
+func inflate(data []byte) ([]byte, error) {
+	r, err := zlib.NewReader(bytes.NewReader(data))
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidPDF, err)
+	}
+	defer r.Close()
+	out, err := io.ReadAll(r)
+	if err != nil && len(out) == 0 {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidPDF, err)
+	}
+	return out, nil
+}
+
+// unpredict reverses the PNG predictors of Flate-compressed data.
+func (d *document) unpredict(data []byte, params pdfDict) ([]byte, error) {
+	predictor, _ := toInt(d.get(params["Predictor"]))
+	if predictor <= 1 {
+		return data, nil
+	}
+	if predictor < 10 {
+		return nil, fmt.Errorf("%w: TIFF predictor", ErrUnsupportedPDF)
+	}
+	columns, ok := toInt(d.get(params["Columns"]))
+	if !ok {
+		columns = 1
+	}
+	colors, ok := toInt(d.get(params["Colors"]))
+	if !ok {
+		colors = 1
+	}
+	bits, ok := toInt(d.get(params["BitsPerComponent"]))
+	if !ok {
+		bits = 8
+	}
+	bpp := max(1, (colors*bits+7)/8)
+	rowLen := (columns*colors*bits + 7) / 8
+	if rowLen <= 0 {
+		return nil, fmt.Errorf("%w: invalid predictor parameters", ErrInvalidPDF)
+	}
+
+	var out []byte
+	prev := make([]byte, rowLen)
+	for pos := 0; pos+rowLen+1 <= len(data); pos += rowLen + 1 {
+		kind := data[pos]
+		row := append([]byte(nil), data[pos+1:pos+1+rowLen]...)
+		for i := range row {
+			var left, up, upLeft byte
+			if i >= bpp {
+				left = row[i-bpp]
+				upLeft = prev[i-bpp]
+			}
+			up = prev[i]
+			switch kind {
+			case 1:
+				row[i] += left
+			case 2:
+				row[i] += up
+			case 3:
+				row[i] += byte((int(left) + int(up)) / 2)
+			case 4:
+				row[i] += paeth(left, up, upLeft)
+			}
+		}
+		out = append(out, row...)
+		prev = row
+	}
+	return out, nil
+}
+
+// paeth is the Paeth predictor of PNG.
+func paeth(a, b, c byte) byte {
+	p := int(a) + int(b) - int(c)
+	pa, pb, pc := abs(p-int(a)), abs(p-int(b)), abs(p-int(c))
+	switch {
+	case pa <= pb && pa <= pc:
+		return a
+	case pb <= pc:
+		return b
+	}
+	return c
+}
+
+// abs returns the absolute value of an integer.
+func abs(v int) int {
+	if v < 0 {
+		return -v
+	}
+	return v
+}
+
+// decodeASCIIHex decodes ASCIIHexDecode data.
+func decodeASCIIHex(data []byte) ([]byte, error) {
+	l := &lexer{data: append(append([]byte("<"), data...), '>')}
+	s, err := l.hexString()
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidPDF, err)
+	}
+	return s, nil
+}
+
+// decodeASCII85 decodes ASCII85Decode data.
+func decodeASCII85(data []byte) ([]byte, error) {
+	var out []byte
+	var group [5]byte
+	n := 0
+loop:
+	for _, c := range data {
+		switch {
+		case isWhite(c):
+		case c == '~':
+			// The end-of-data marker ~>
+			break loop
+		case c == 'z' && n == 0:
+			out = append(out, 0, 0, 0, 0)
+		case c < '!' || c > 'u':
+			return nil, fmt.Errorf("%w: invalid ASCII85 data", ErrInvalidPDF)
+		default:
+			group[n] = c - '!'
+			n++
+			if n == 5 {
+				out = appendBase85(out, group, 5)
+				n = 0
+			}
+		}
+	}
+	if n > 1 {
+		for i := n; i < 5; i++ {
+			group[i] = 84
+		}
+		out = appendBase85(out, group, n)
+	}
+	return out, nil
+}
+
+// appendBase85 appends the n-1 bytes of a group of n base-85 digits.
+func appendBase85(out []byte, group [5]byte, n int) []byte {
+	var v uint32
+	for _, g := range group {
+		v = v*85 + uint32(g)
+	}
+	b := []byte{byte(v >> 24), byte(v >> 16), byte(v >> 8), byte(v)}
+	return append(out, b[:n-1]...)
+}
+
+// add stores a new object in the document and returns its reference.
+func (d *document) add(obj any) pdfRef {
+	num := d.nextNum
+	d.nextNum++
+	d.objects[num] = obj
+	d.locations[num] = objectLocation{offset: -1}
+	return pdfRef{num: num}
+}
+
+// set replaces an object of the document.
+func (d *document) set(ref pdfRef, obj any) {
+	d.objects[ref.num] = obj
+}
+
+// write serializes the objects reachable from the trailer into a new file. Objects that
+// are no longer used, including the earlier versions of updated objects, are left out.
+func (d *document) write() ([]byte, error) {
+	// Number the reachable objects in the order they are found
+	numbers := map[int]int{}
+	var order []int
+	var visit func(v any) error
+	visit = func(v any) error {
+		switch o := v.(type) {
+		case pdfRef:
+			if _, seen := numbers[o.num]; seen {
+				return nil
+			}
+			obj, err := d.load(o.num)
+			if err != nil {
+				return err
+			}
+			numbers[o.num] = len(order) + 1
+			order = append(order, o.num)
+			return visit(obj)
+		case pdfDict:
+			for _, item := range o {
+				if err := visit(item); err != nil {
+					return err
+				}
+			}
+		case pdfArray:
+			for _, item := range o {
+				if err := visit(item); err != nil {
+					return err
+				}
+			}
+		case *pdfStream:
+			// Lengths are written directly, so indirect ones are not needed
+			for k, item := range o.dict {
+				if k == "Length" {
+					continue
+				}
+				if err := visit(item); err != nil {
+					return err
+				}
+			}
+		}
+		return nil
+	}
+	trailer := pdfDict{"Root": d.trailer["Root"]}
+	if info, ok := d.trailer["Info"].(pdfRef); ok {
+		trailer["Info"] = info
+	}
+	if err := visit(trailer); err != nil {
+		return nil, err
+	}
+
+	renumber := func(ref pdfRef) pdfRef {
+		if n, ok := numbers[ref.num]; ok {
+			return pdfRef{num: n}
+		}
+		// References to missing objects read as null, which object 0 always is
+		return pdfRef{num: 0, gen: 65535}
+	}
+
+	var buf bytes.Buffer
+	buf.WriteString("%PDF-1.7\n%\xe2\xe3\xcf\xd3\n")
+	offsets := make([]int, len(order)+1)
+	for i, num := range order {
+		offsets[i+1] = buf.Len()
+		fmt.Fprintf(&buf, "%d 0 obj\n", i+1)
+		switch obj := d.objects[num].(type) {
+		case *pdfStream:
+			dict := pdfDict{}
+			for k, v := range obj.dict {
+				dict[k] = v
+			}
+			dict["Length"] = int64(len(obj.data))
+			writeObject(&buf, dict, renumber)
+			buf.WriteString("\nstream\n")
+			buf.Write(obj.data)
+			buf.WriteString("\nendstream")
+		default:
+			writeObject(&buf, obj, renumber)
+		}
+		buf.WriteString("\nendobj\n")
+	}
+
+	xref := buf.Len()
+	fmt.Fprintf(&buf, "xref\n0 %d\n0000000000 65535 f\r\n", len(order)+1)
+	for _, offset := range offsets[1:] {
+		fmt.Fprintf(&buf, "%010d 00000 n\r\n", offset)
+	}
+	trailer["Size"] = int64(len(order) + 1)
+	buf.WriteString("trailer\n")
+	writeObject(&buf, trailer, renumber)
+	fmt.Fprintf(&buf, "\nstartxref\n%d\n%%%%EOF\n", xref)
+	return buf.Bytes(), nil
+}
+
+// deflate compresses data for a new FlateDecode stream.
+func deflate(data []byte) []byte {
+	var buf bytes.Buffer
+	w := zlib.NewWriter(&buf)
+	// Writes to a bytes.Buffer cannot fail
+	_, _ = w.Write(data)
+	_ = w.Close()
+	return buf.Bytes()
+}
@@ -0,0 +1,225 @@
+// Package redaction applies the redaction schema of a document to its PDF.
+// This file contains the font metrics needed to find where each glyph of a text is drawn.
+package redaction
+
+import "strings"
+
+// Glyph extents in text space for a font size of 1. Fonts rarely declare reliable ascent
+// and descent values, so every glyph is assumed to reach from below the baseline to the
+// full font size above it.
+const (
+	glyphDescent = -0.25
+	glyphAscent  = 1.0
+)
+
+// helveticaWidths are the widths of the printable ASCII characters of Helvetica, from the
+// space (32) to the tilde (126), in thousandths of the font size. They stand in for the
+// widths of standard fonts, which files are not required to declare.
+var helveticaWidths = [95]float64{
+	278, 278, 355, 556, 556, 889, 667, 222, 333, 333, 389, 584, 278, 333, 278, 278,
+	556, 556, 556, 556, 556, 556, 556, 556, 556, 556,
+	278, 278, 584, 584, 584, 556, 1015,
+	667, 667, 722, 722, 667, 611, 778, 722, 278, 500, 667, 556, 833, 722, 778, 667, 778, 722, 667, 611, 722, 667, 944, 667, 667, 611,
+	278, 278, 278, 469, 556, 222,
+	556, 556, 500, 556, 556, 278, 556, 556, 222, 222, 500, 222, 833, 556, 556, 556, 556, 333, 500, 278, 556, 500, 722, 500, 500, 500,
+	334, 260, 334, 584,
+}
+
+// helveticaWidth returns the width of a character of Helvetica for a font size of 1.
+func helveticaWidth(code int) float64 {
+	if code >= 32 && code <= 126 {
+		return helveticaWidths[code-32] / 1000
+	}
+	return 0.556
+}
+
+// font holds what is needed to measure the text shown with a font.
+type font struct {
+	// twoByte is set for composite fonts, whose character codes are two bytes long
+	twoByte bool
+
+	// widths are the widths of the character codes for a font size of 1
+	widths map[int]float64
+
+	// defaultWidth is the width of codes without a declared width
+	defaultWidth float64
+
+	// standard is the width function of a standard font without declared widths
+	standard func(code int) float64
+}
+
+// defaultFont measures text whose font cannot be found.
+var defaultFont = &font{defaultWidth: 0.5}
+
+// codes splits a shown string into its character codes.
+func (f *font) codes(s []byte) []int {
+	if !f.twoByte {
+		codes := make([]int, len(s))
+		for i, c := range s {
+			codes[i] = int(c)
+		}
+		return codes
+	}
+	codes := make([]int, 0, (len(s)+1)/2)
+	for i := 0; i < len(s); i += 2 {
+		if i+1 < len(s) {
+			codes = append(codes, int(s[i])<<8|int(s[i+1]))
+		} else {
+			codes = append(codes, int(s[i])<<8)
+		}
+	}
+	return codes
+}
+
+// width returns the width of a character code for a font size of 1.
+func (f *font) width(code int) float64 {
+	if w, ok := f.widths[code]; ok {
+		return w
+	}
+	if f.standard != nil {
+		return f.standard(code)
+	}
+	return f.defaultWidth
+}
+
+// codeBytes returns the bytes of a character code as they are shown.
+func (f *font) codeBytes(code int) []byte {
+	if f.twoByte {
+		return []byte{byte(code >> 8), byte(code)}
+	}
+	return []byte{byte(code)}
+}
+
+// loadFont reads the metrics of a font dictionary.
+func (d *document) loadFont(dict pdfDict) *font {
+	if dict == nil {
+		return defaultFont
+	}
+
+	switch d.get(dict["Subtype"]) {
+	case pdfName("Type0"):
+		return d.loadCompositeFont(dict)
+	case pdfName("Type3"):
+		// Type 3 widths are in glyph space, scaled by the font matrix
+		scale := 0.001
+		if m, ok := d.get(dict["FontMatrix"]).(pdfArray); ok && len(m) == 6 {
+			if a, ok := toFloat(d.get(m[0])); ok {
+				scale = a
+			}
+		}
+		return d.loadSimpleFont(dict, scale)
+	}
+	return d.loadSimpleFont(dict, 0.001)
+}
+
+// loadSimpleFont reads the widths of a font with single-byte character codes.
+func (d *document) loadSimpleFont(dict pdfDict, scale float64) *font {
+	f := &font{widths: map[int]float64{}, defaultWidth: 0.5}
+	if desc := d.getDict(dict["FontDescriptor"]); desc != nil {
+		if w, ok := toFloat(d.get(desc["MissingWidth"])); ok && w > 0 {
+			f.defaultWidth = w * scale
+		}
+	}
+
+	widths, ok := d.get(dict["Widths"]).(pdfArray)
+	if !ok {
+		base, _ := d.get(dict["BaseFont"]).(pdfName)
+		if strings.Contains(string(base), "Courier") {
+			f.defaultWidth = 0.6
+		} else {
+			f.standard = helveticaWidth
+		}
+		return f
+	}
+	first, _ := toInt(d.get(dict["FirstChar"]))
+	for i, v := range widths {
+		if w, ok := toFloat(d.get(v)); ok {
+			f.widths[first+i] = w * scale
+		}
+	}
+	return f
+}
+
+// loadCompositeFont reads the widths of a Type 0 font from its descendant font.
+func (d *document) loadCompositeFont(dict pdfDict) *font {
+	f := &font{twoByte: true, widths: map[int]float64{}, defaultWidth: 1}
+	// Embedded CMaps with only single-byte code ranges use single-byte codes
+	if cmap, ok := d.get(dict["Encoding"]).(*pdfStream); ok {
+		if data, err := d.decodeStream(cmap); err == nil && singleByteCMap(data) {
+			f.twoByte = false
+		}
+	}
+
+	descendants, _ := d.get(dict["DescendantFonts"]).(pdfArray)
+	if len(descendants) == 0 {
+		return f
+	}
+	cid := d.getDict(descendants[0])
+	if dw, ok := toFloat(d.get(cid["DW"])); ok {
+		f.defaultWidth = dw / 1000
+	}
+
+	// Widths are listed as "first [w1 w2 ...]" or "first last w"
+	w, _ := d.get(cid["W"]).(pdfArray)
+	for i := 0; i < len(w); {
+		first, ok := toInt(d.get(w[i]))
+		if !ok || i+1 >= len(w) {
+			break
+		}
+		if list, ok := d.get(w[i+1]).(pdfArray); ok {
+			for j, v := range list {
+				if width, ok := toFloat(d.get(v)); ok {
+					f.widths[first+j] = width / 1000
+				}
+			}
+			i += 2
+			continue
+		}
+		if i+2 >= len(w) {
+			break
+		}
+		last, ok1 := toInt(d.get(w[i+1]))
+		width, ok2 := toFloat(d.get(w[i+2]))
+		if !ok1 || !ok2 || last-first > 0xFFFF {
+			break
+		}
+		for code := first; code <= last; code++ {
+			f.widths[code] = width / 1000
+		}
+		i += 3
+	}
+	return f
+}
+
+// singleByteCMap reports whether all code space ranges of a CMap are one byte long.
+func singleByteCMap(data []byte) bool {
+	l := &lexer{data: data}
+	inRange := false
+	found := false
+	for {
+		l.skipSpace()
+		if l.pos >= len(l.data) {
+			return found
+		}
+		obj, err := l.object(false)
+		if err != nil {
+			return false
+		}
+		switch o := obj.(type) {
+		case pdfKeyword:
+			switch o {
+			case "begincodespacerange":
+				inRange = true
+			case "endcodespacerange":
+				inRange = false
+			}
+		case pdfString:
+			if inRange {
+				if len(o) != 1 {
+					return false
+				}
+				found = true
+			}
+		}
+	}
+}
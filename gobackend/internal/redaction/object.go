@@ -0,0 +1,469 @@
+// Package redaction applies the redaction schema of a document to its PDF.
+// This file contains the PDF object model and the parser of the PDF object syntax.
+package redaction
+
+import (
+	"bytes"
+	"fmt"
+	"sort"
+	"strconv"
+)
+
+// pdfName is a PDF name object such as /Type, stored without the slash.
+type pdfName string
+
+// pdfString is a PDF string object, literal or hexadecimal, stored as its bytes.
+type pdfString []byte
+
+// pdfKeyword is a bare keyword: true, false, null, or an operator in a content stream.
+type pdfKeyword string
+
+// pdfRef is an indirect reference to an object of the file.
+type pdfRef struct {
+	num int
+	gen int
+}
+
+// pdfDict is a PDF dictionary.
+type pdfDict map[pdfName]any
+
+// pdfArray is a PDF array.
+type pdfArray []any
+
+// pdfStream is a PDF stream with its dictionary and its data as stored, still encoded.
+type pdfStream struct {
+	dict pdfDict
+	data []byte
+}
+
+// isWhite reports whether a byte is PDF white-space.
+func isWhite(c byte) bool {
+	switch c {
+	case 0, '\t', '\n', '\f', '\r', ' ':
+		return true
+	}
+	return false
+}
+
+// isDelim reports whether a byte is a PDF delimiter.
+func isDelim(c byte) bool {
+	switch c {
+	case '(', ')', '<', '>', '[', ']', '{', '}', '/', '%':
+		return true
+	}
+	return false
+}
+
+// lexer reads PDF objects from a buffer.
+type lexer struct {
+	data []byte
+	pos  int
+}
+
+// skipSpace moves past white-space and comments.
+func (l *lexer) skipSpace() {
+	for l.pos < len(l.data) {
+		c := l.data[l.pos]
+		if isWhite(c) {
+			l.pos++
+			continue
+		}
+		if c == '%' {
+			for l.pos < len(l.data) && l.data[l.pos] != '\n' && l.data[l.pos] != '\r' {
+				l.pos++
+			}
+			continue
+		}
+		return
+	}
+}
+
+// regular reads a run of regular characters: a number, keyword or name body.
+func (l *lexer) regular() string {
+	start := l.pos
+	for l.pos < len(l.data) && !isWhite(l.data[l.pos]) && !isDelim(l.data[l.pos]) {
+		l.pos++
+	}
+	return string(l.data[start:l.pos])
+}
+
+// object reads the next object. References ("1 0 R") are recognized only if refs is set,
+// since content streams have none.
+func (l *lexer) object(refs bool) (any, error) {
+	l.skipSpace()
+	if l.pos >= len(l.data) {
+		return nil, fmt.Errorf("unexpected end of data")
+	}
+
+	switch c := l.data[l.pos]; c {
+	case '/':
+		l.pos++
+		return pdfName(decodeName(l.regular())), nil
+	case '(':
+		return l.literalString()
+	case '<':
+		if l.pos+1 < len(l.data) && l.data[l.pos+1] == '<' {
+			l.pos += 2
+			return l.dict(refs)
+		}
+		return l.hexString()
+	case '[':
+		l.pos++
+		arr := pdfArray{}
+		for {
+			l.skipSpace()
+			if l.pos >= len(l.data) {
+				return nil, fmt.Errorf("unterminated array")
+			}
+			if l.data[l.pos] == ']' {
+				l.pos++
+				return arr, nil
+			}
+			item, err := l.object(refs)
+			if err != nil {
+				return nil, err
+			}
+			arr = append(arr, item)
+		}
+	case ')', '>', ']', '{', '}':
+		l.pos++
+		return pdfKeyword(string(c)), nil
+	}
+
+	token := l.regular()
+	if token == "" {
+		return nil, fmt.Errorf("unexpected character %q at offset %d", l.data[l.pos], l.pos)
+	}
+	if isNumber(token) {
+		if !refs || !isInteger(token) {
+			return parseNumber(token), nil
+		}
+		// An integer may start a reference "num gen R"
+		save := l.pos
+		l.skipSpace()
+		gen := l.regular()
+		if isInteger(gen) {
+			l.skipSpace()
+			if l.regular() == "R" {
+				num, _ := strconv.Atoi(token)
+				g, _ := strconv.Atoi(gen)
+				return pdfRef{num: num, gen: g}, nil
+			}
+		}
+		l.pos = save
+		return parseNumber(token), nil
+	}
+	switch token {
+	case "true":
+		return true, nil
+	case "false":
+		return false, nil
+	case "null":
+		return nil, nil
+	}
+	return pdfKeyword(token), nil
+}
+
+// dict reads the entries of a dictionary after its opening <<.
+func (l *lexer) dict(refs bool) (pdfDict, error) {
+	d := pdfDict{}
+	for {
+		l.skipSpace()
+		if l.pos+1 < len(l.data) && l.data[l.pos] == '>' && l.data[l.pos+1] == '>' {
+			l.pos += 2
+			return d, nil
+		}
+		if l.pos >= len(l.data) {
+			return nil, fmt.Errorf("unterminated dictionary")
+		}
+		key, err := l.object(refs)
+		if err != nil {
+			return nil, err
+		}
+		name, ok := key.(pdfName)
+		if !ok {
+			return nil, fmt.Errorf("dictionary key %v is not a name", key)
+		}
+		value, err := l.object(refs)
+		if err != nil {
+			return nil, err
+		}
+		d[name] = value
+	}
+}
+
+// literalString reads a (string) with its escapes and balanced parentheses.
+func (l *lexer) literalString() (pdfString, error) {
+	l.pos++
+	var buf []byte
+	depth := 1
+	for l.pos < len(l.data) {
+		c := l.data[l.pos]
+		l.pos++
+		switch c {
+		case '(':
+			depth++
+		case ')':
+			depth--
+			if depth == 0 {
+				return pdfString(buf), nil
+			}
+		case '\\':
+			if l.pos >= len(l.data) {
+				continue
+			}
+			e := l.data[l.pos]
+			l.pos++
+			switch e {
+			case 'n':
+				c = '\n'
+			case 'r':
+				c = '\r'
+			case 't':
+				c = '\t'
+			case 'b':
+				c = '\b'
+			case 'f':
+				c = '\f'
+			case '\r':
+				// A line continuation
+				if l.pos < len(l.data) && l.data[l.pos] == '\n' {
+					l.pos++
+				}
+				continue
+			case '\n':
+				continue
+			case '0', '1', '2', '3', '4', '5', '6', '7':
+				v := int(e - '0')
+				for i := 0; i < 2 && l.pos < len(l.data) && l.data[l.pos] >= '0' && l.data[l.pos] <= '7'; i++ {
+					v = v*8 + int(l.data[l.pos]-'0')
+					l.pos++
+				}
+				c = byte(v)
+			default:
+				c = e
+			}
+		case '\r':
+			// End-of-line markers in strings read as a single line feed
+			if l.pos < len(l.data) && l.data[l.pos] == '\n' {
+				l.pos++
+			}
+			c = '\n'
+		}
+		buf = append(buf, c)
+	}
+	return nil, fmt.Errorf("unterminated string")
+}
+
+// hexString reads a <hexadecimal string>.
+func (l *lexer) hexString() (pdfString, error) {
+	l.pos++
+	var buf []byte
+	var hi int = -1
+	for l.pos < len(l.data) {
+		c := l.data[l.pos]
+		l.pos++
+		if c == '>' {
+			if hi >= 0 {
+				buf = append(buf, byte(hi<<4))
+			}
+			return pdfString(buf), nil
+		}
+		v := hexValue(c)
+		if v < 0 {
+			continue
+		}
+		if hi < 0 {
+			hi = v
+		} else {
+			buf = append(buf, byte(hi<<4|v))
+			hi = -1
+		}
+	}
+	return nil, fmt.Errorf("unterminated hexadecimal string")
+}
+
+// hexValue returns the value of a hexadecimal digit, or -1 for other characters.
+func hexValue(c byte) int {
+	switch {
+	case c >= '0' && c <= '9':
+		return int(c - '0')
+	case c >= 'a' && c <= 'f':
+		return int(c-'a') + 10
+	case c >= 'A' && c <= 'F':
+		return int(c-'A') + 10
+	}
+	return -1
+}
+
+// decodeName resolves the #xx escapes of a name.
+func decodeName(s string) string {
+	if !bytes.Contains([]byte(s), []byte("#")) {
+		return s
+	}
+	var buf []byte
+	for i := 0; i < len(s); i++ {
+		if s[i] == '#' && i+2 < len(s) && hexValue(s[i+1]) >= 0 && hexValue(s[i+2]) >= 0 {
+			buf = append(buf, byte(hexValue(s[i+1])<<4|hexValue(s[i+2])))
+			i += 2
+			continue
+		}
+		buf = append(buf, s[i])
+	}
+	return string(buf)
+}
+
+// isNumber reports whether a token is a PDF number.
+func isNumber(s string) bool {
+	digits := 0
+	for i := 0; i < len(s); i++ {
+		c := s[i]
+		switch {
+		case c >= '0' && c <= '9':
+			digits++
+		case (c == '+' || c == '-') && i == 0:
+		case c == '.':
+		default:
+			return false
+		}
+	}
+	return digits > 0
+}
+
+// isInteger reports whether a token is an unsigned integer.
+func isInteger(s string) bool {
+	if s == "" {
+		return false
+	}
+	for i := 0; i < len(s); i++ {
+		if s[i] < '0' || s[i] > '9' {
+			return false
+		}
+	}
+	return true
+}
+
+// parseNumber parses a number token as an int64 or float64.
+func parseNumber(s string) any {
+	if i, err := strconv.ParseInt(s, 10, 64); err == nil {
+		return i
+	}
+	f, err := strconv.ParseFloat(s, 64)
+	if err != nil {
+		// Malformed numbers such as "--1" or "1.2.3" are read as zero, as viewers do
+		return int64(0)
+	}
+	return f
+}
+
+// toFloat returns the value of a number object.
+func toFloat(v any) (float64, bool) {
+	switch n := v.(type) {
+	case int64:
+		return float64(n), true
+	case float64:
+		return n, true
+	}
+	return 0, false
+}
+
+// toInt returns the value of an integer object.
+func toInt(v any) (int, bool) {
+	switch n := v.(type) {
+	case int64:
+		return int(n), true
+	case float64:
+		return int(n), true
+	}
+	return 0, false
+}
+
+// writeObject serializes an object, mapping references with renumber if it is set.
+func writeObject(buf *bytes.Buffer, v any, renumber func(pdfRef) pdfRef) {
+	switch o := v.(type) {
+	case nil:
+		buf.WriteString("null")
+	case bool:
+		buf.WriteString(strconv.FormatBool(o))
+	case int64:
+		buf.WriteString(strconv.FormatInt(o, 10))
+	case float64:
+		buf.WriteString(formatFloat(o))
+	case pdfName:
+		writeName(buf, o)
+	case pdfString:
+		buf.WriteByte('<')
+		for _, c := range o {
+			fmt.Fprintf(buf, "%02x", c)
+		}
+		buf.WriteByte('>')
+	case pdfKeyword:
+		buf.WriteString(string(o))
+	case pdfRef:
+		if renumber != nil {
+			o = renumber(o)
+		}
+		fmt.Fprintf(buf, "%d %d R", o.num, o.gen)
+	case pdfArray:
+		buf.WriteByte('[')
+		for i, item := range o {
+			if i > 0 {
+				buf.WriteByte(' ')
+			}
+			writeObject(buf, item, renumber)
+		}
+		buf.WriteByte(']')
+	case pdfDict:
+		buf.WriteString("<<")
+		// Keys are sorted so the output is deterministic
+		keys := make([]string, 0, len(o))
+		for k := range o {
+			keys = append(keys, string(k))
+		}
+		sort.Strings(keys)
+		for _, k := range keys {
+			writeName(buf, pdfName(k))
+			buf.WriteByte(' ')
+			writeObject(buf, o[pdfName(k)], renumber)
+			buf.WriteByte(' ')
+		}
+		buf.WriteString(">>")
+	}
+}
+
+// writeName serializes a name, escaping the characters names cannot hold.
+func writeName(buf *bytes.Buffer, n pdfName) {
+	buf.WriteByte('/')
+	for i := 0; i < len(n); i++ {
+		c := n[i]
+		if c < '!' || c > '~' || c == '#' || isDelim(c) {
+			fmt.Fprintf(buf, "#%02X", c)
+			continue
+		}
+		buf.WriteByte(c)
+	}
+}
+
+// formatFloat formats a real number without an exponent, which PDF does not allow.
+func formatFloat(f float64) string {
+	s := strconv.FormatFloat(f, 'f', 5, 64)
+	s = trimZeros(s)
+	if s == "-0" {
+		return "0"
+	}
+	return s
+}
+
+// trimZeros removes trailing zeros after the decimal point.
+func trimZeros(s string) string {
+	if !bytes.Contains([]byte(s), []byte(".")) {
+		return s
+	}
+	for s[len(s)-1] == '0' {
+		s = s[:len(s)-1]
+	}
+	if s[len(s)-1] == '.' {
+		s = s[:len(s)-1]
+	}
+	return s
+}
@@ -0,0 +1,437 @@
+// Package redaction applies the redaction schema of a document to its PDF on the server,
+// so redacted output no longer depends on the client drawing boxes over the pages.
+//
+// Redacting a PDF removes the content under each redaction area before covering it:
+// the glyphs of text shown in the area are dropped from the content streams, images drawn
+// over the area are removed entirely, forms are redacted like the page, and annotations
+// lying in the area are deleted together with their values. The file is then rewritten with
+// only the objects still in use, so earlier revisions kept by incremental updates are not
+// carried over. Encrypted PDFs are not supported.
+//
+// This file contains the redaction of the pages and the boxes drawn over the redacted areas.
+package redaction
+
+import (
+	"bytes"
+	"fmt"
+	"strings"
+
+	"github.com/yasinhessnawi1/Hideme_Backend/internal/constants"
+	"github.com/yasinhessnawi1/Hideme_Backend/internal/models"
+)
+
+// Area is a region of a page to redact.
+type Area struct {
+	// Page is the page number, starting at 1
+	Page int
+
+	// BBox is the region in page-relative coordinates, from 0 at the top-left corner of
+	// the page as displayed to 1 at its bottom-right corner
+	BBox models.BBox
+
+	// Method is how the region is covered: constants.RedactionMethodBlackout,
+	// RedactionMethodMask or RedactionMethodReplace
+	Method string
+
+	// Label is the text written over the region by the mask and replace methods
+	Label string
+}
+
+// Result is a redacted PDF with what the redaction removed.
+type Result struct {
+	// PDF is the redacted file
+	PDF []byte
+
+	// Pages is the number of pages of the file
+	Pages int
+
+	// RedactedPages is the number of pages with redaction areas
+	RedactedPages int
+
+	// Glyphs is the number of characters removed from the text of the pages
+	Glyphs int
+
+	// Images is the number of images and forms removed
+	Images int
+
+	// Annotations is the number of annotations removed
+	Annotations int
+}
+
+// page is a page of the page tree with its inherited attributes.
+type page struct {
+	ref       pdfRef
+	dict      pdfDict
+	resources pdfDict
+	box       rect
+	rotate    int
+}
+
+// Redact removes the content of a PDF inside the redaction areas and covers the areas.
+//
+// Parameters:
+//   - pdf: The PDF file
+//   - areas: The regions to redact; regions on pages the file doesn't have are ignored
+//
+// Returns:
+//   - The redacted file with what was removed
+//   - ErrInvalidPDF if the file cannot be read
+//   - ErrEncryptedPDF if the file is encrypted
+//   - ErrUnsupportedPDF if a page uses features that cannot be redacted
+func Redact(pdf []byte, areas []Area) (*Result, error) {
+	doc, err := parseDocument(pdf)
+	if err != nil {
+		return nil, err
+	}
+	pages, err := doc.pages()
+	if err != nil {
+		return nil, err
+	}
+
+	byPage := map[int][]Area{}
+	for _, area := range areas {
+		if area.Page >= 1 && area.Page <= len(pages) {
+			byPage[area.Page] = append(byPage[area.Page], area)
+		}
+	}
+
+	stats := &redactionStats{}
+	for number, pageAreas := range byPage {
+		if err := doc.redactPage(pages[number-1], pageAreas, stats); err != nil {
+			return nil, fmt.Errorf("page %d: %w", number, err)
+		}
+	}
+
+	if len(byPage) > 0 {
+		// The structure tree can repeat redacted text as replacement or alternate text
+		if catalog := doc.getDict(doc.trailer["Root"]); catalog != nil {
+			updated := pdfDict{}
+			for k, v := range catalog {
+				if k != "StructTreeRoot" {
+					updated[k] = v
+				}
+			}
+			if ref, ok := doc.trailer["Root"].(pdfRef); ok {
+				doc.set(ref, updated)
+			} else {
+				doc.trailer["Root"] = updated
+			}
+		}
+	}
+
+	out, err := doc.write()
+	if err != nil {
+		return nil, err
+	}
+	return &Result{
+		PDF:           out,
+		Pages:         len(pages),
+		RedactedPages: len(byPage),
+		Glyphs:        stats.glyphs,
+		Images:        stats.images,
+		Annotations:   stats.annotations,
+	}, nil
+}
+
+// pages lists the pages of the document in order, with the attributes they inherit.
+func (d *document) pages() ([]page, error) {
+	catalog := d.getDict(d.trailer["Root"])
+	root, ok := catalog["Pages"].(pdfRef)
+	if !ok {
+		return nil, fmt.Errorf("%w: missing page tree", ErrInvalidPDF)
+	}
+
+	var pages []page
+	seen := map[int]bool{}
+	var walk func(ref pdfRef, inherited pdfDict) error
+	walk = func(ref pdfRef, inherited pdfDict) error {
+		if seen[ref.num] {
+			return fmt.Errorf("%w: page tree loop", ErrInvalidPDF)
+		}
+		seen[ref.num] = true
+		node := d.getDict(ref)
+		if node == nil {
+			return nil
+		}
+
+		attrs := pdfDict{}
+		for k, v := range inherited {
+			attrs[k] = v
+		}
+		for _, k := range []pdfName{"Resources", "MediaBox", "CropBox", "Rotate"} {
+			if v, ok := node[k]; ok {
+				attrs[k] = v
+			}
+		}
+
+		if kids, ok := d.get(node["Kids"]).(pdfArray); ok && d.get(node["Type"]) != pdfName("Page") {
+			for _, kid := range kids {
+				if kidRef, ok := kid.(pdfRef); ok {
+					if err := walk(kidRef, attrs); err != nil {
+						return err
+					}
+				}
+			}
+			return nil
+		}
+
+		box := d.pageBox(attrs["MediaBox"], rect{0, 0, 612, 792})
+		box = d.pageBox(attrs["CropBox"], box)
+		rotate, _ := toInt(d.get(attrs["Rotate"]))
+		pages = append(pages, page{
+			ref:       ref,
+			dict:      node,
+			resources: d.getDict(attrs["Resources"]),
+			box:       box,
+			rotate:    ((rotate % 360) + 360) % 360 / 90 * 90,
+		})
+		return nil
+	}
+	if err := walk(root, pdfDict{}); err != nil {
+		return nil, err
+	}
+	return pages, nil
+}
+
+// pageBox reads a page boundary, falling back to another if it is missing or invalid.
+func (d *document) pageBox(v any, fallback rect) rect {
+	arr, ok := d.get(v).(pdfArray)
+	if !ok || len(arr) != 4 {
+		return fallback
+	}
+	var c [4]float64
+	for i, item := range arr {
+		f, ok := toFloat(d.get(item))
+		if !ok {
+			return fallback
+		}
+		c[i] = f
+	}
+	box := rect{min(c[0], c[2]), min(c[1], c[3]), max(c[0], c[2]), max(c[1], c[3])}
+	if box.x1-box.x0 <= 0 || box.y1-box.y0 <= 0 {
+		return fallback
+	}
+	return box
+}
+
+// displaySize returns the width and height of the page as displayed, after its rotation.
+func (p page) displaySize() (float64, float64) {
+	w, h := p.box.x1-p.box.x0, p.box.y1-p.box.y0
+	if p.rotate == 90 || p.rotate == 270 {
+		return h, w
+	}
+	return w, h
+}
+
+// userPoint converts a point of the displayed page, in points from its top-left corner,
+// to user space.
+func (p page) userPoint(dx, dy float64) (float64, float64) {
+	b := p.box
+	switch p.rotate {
+	case 90:
+		return b.x0 + dy, b.y0 + dx
+	case 180:
+		return b.x1 - dx, b.y0 + dy
+	case 270:
+		return b.x1 - dy, b.y1 - dx
+	}
+	return b.x0 + dx, b.y1 - dy
+}
+
+// userRect converts a page-relative bounding box to a rectangle in user space.
+func (p page) userRect(bbox models.BBox) rect {
+	w, h := p.displaySize()
+	x0, y0 := p.userPoint(bbox.X0*w, bbox.Y0*h)
+	x1, y1 := p.userPoint(bbox.X1*w, bbox.Y1*h)
+	return rect{min(x0, x1), min(y0, y1), max(x0, x1), max(y0, y1)}
+}
+
+// textMatrix returns the matrix writing text upright on the displayed page, from a point of
+// the displayed page in points from its top-left corner.
+func (p page) textMatrix(dx, dy float64) matrix {
+	x, y := p.userPoint(dx, dy)
+	switch p.rotate {
+	case 90:
+		return matrix{0, 1, -1, 0, x, y}
+	case 180:
+		return matrix{-1, 0, 0, -1, x, y}
+	case 270:
+		return matrix{0, -1, 1, 0, x, y}
+	}
+	return matrix{1, 0, 0, 1, x, y}
+}
+
+// redactPage removes the content of a page inside its redaction areas and covers them.
+func (d *document) redactPage(p page, areas []Area, stats *redactionStats) error {
+	rects := make([]rect, len(areas))
+	for i, area := range areas {
+		rects[i] = p.userRect(area.BBox)
+	}
+
+	content, err := d.pageContent(p.dict)
+	if err != nil {
+		return err
+	}
+	ops, err := parseContent(content)
+	if err != nil {
+		return err
+	}
+
+	r := &contentRedactor{doc: d, areas: rects, fonts: map[any]*font{}, stats: stats}
+	scope := &resourceScope{original: p.resources}
+	if scope.original == nil {
+		scope.original = pdfDict{}
+	}
+	redacted, _, err := r.redact(ops, scope, identity, 0)
+	if err != nil {
+		return err
+	}
+	r.prune(scope)
+
+	// The original content is wrapped in its own graphics state, so the boxes are drawn
+	// in user space whatever state it leaves behind
+	var buf bytes.Buffer
+	buf.WriteString("q\n")
+	buf.Write(writeContent(redacted))
+	buf.WriteString("Q\n")
+	var fontName pdfName
+	for i, area := range areas {
+		if area.Method != constants.RedactionMethodBlackout && area.Label != "" && fontName == "" {
+			fontName = r.addResource(scope, "Font", pdfDict{
+				"Type":     pdfName("Font"),
+				"Subtype":  pdfName("Type1"),
+				"BaseFont": pdfName("Helvetica"),
+				"Encoding": pdfName("WinAnsiEncoding"),
+			})
+		}
+		p.drawBox(&buf, area, rects[i], fontName)
+	}
+
+	updated := pdfDict{}
+	for k, v := range p.dict {
+		updated[k] = v
+	}
+	// Thumbnails show the page as it was
+	delete(updated, "Thumb")
+	resources := scope.original
+	if scope.copied != nil {
+		resources = scope.copied
+	}
+	updated["Resources"] = resources
+	updated["Contents"] = d.add(&pdfStream{
+		dict: pdfDict{"Filter": pdfName("FlateDecode")},
+		data: deflate(buf.Bytes()),
+	})
+	if annots, ok := d.get(p.dict["Annots"]).(pdfArray); ok {
+		updated["Annots"] = d.redactAnnotations(annots, r, stats)
+	}
+	d.set(p.ref, updated)
+	return nil
+}
+
+// pageContent returns the decoded content of a page, joining its content streams.
+func (d *document) pageContent(dict pdfDict) ([]byte, error) {
+	var streams pdfArray
+	switch c := d.get(dict["Contents"]).(type) {
+	case *pdfStream:
+		streams = pdfArray{c}
+	case pdfArray:
+		streams = c
+	}
+
+	var buf bytes.Buffer
+	for _, item := range streams {
+		stream, ok := d.get(item).(*pdfStream)
+		if !ok {
+			continue
+		}
+		data, err := d.decodeStream(stream)
+		if err != nil {
+			return nil, err
+		}
+		buf.Write(data)
+		buf.WriteByte('\n')
+	}
+	return buf.Bytes(), nil
+}
+
+// redactAnnotations removes the annotations lying in a redaction area, with their values
+// and appearances, since form fields can keep them beyond the page.
+func (d *document) redactAnnotations(annots pdfArray, r *contentRedactor, stats *redactionStats) pdfArray {
+	kept := pdfArray{}
+	for _, item := range annots {
+		annot := d.getDict(item)
+		box := d.pageBox(annot["Rect"], rect{})
+		if annot == nil || box == (rect{}) || !r.inArea(box) {
+			kept = append(kept, item)
+			continue
+		}
+		stats.annotations++
+		if ref, ok := item.(pdfRef); ok {
+			cleared := pdfDict{}
+			for k, v := range annot {
+				switch k {
+				case "V", "AP", "Contents", "RC":
+				default:
+					cleared[k] = v
+				}
+			}
+			d.set(ref, cleared)
+		}
+	}
+	return kept
+}
+
+// drawBox covers a redaction area, writing its label for the mask and replace methods.
+func (p page) drawBox(buf *bytes.Buffer, area Area, box rect, fontName pdfName) {
+	fill, text := "0 g", "1 g"
+	if area.Method == constants.RedactionMethodReplace {
+		fill, text = "1 g", "0 g"
+	}
+	fmt.Fprintf(buf, "q %s %s %s %s %s re f Q\n", fill,
+		formatFloat(box.x0), formatFloat(box.y0), formatFloat(box.x1-box.x0), formatFloat(box.y1-box.y0))
+
+	label := winAnsi(area.Label)
+	if area.Method == constants.RedactionMethodBlackout || len(label) == 0 || fontName == "" {
+		return
+	}
+
+	// The label is fitted into the box as it is displayed
+	w, h := p.displaySize()
+	boxW := (area.BBox.X1 - area.BBox.X0) * w
+	boxH := (area.BBox.Y1 - area.BBox.Y0) * h
+	textW := 0.0
+	for _, c := range label {
+		textW += helveticaWidth(int(c))
+	}
+	padding := boxH * 0.1
+	size := min(boxH*0.75, (boxW-2*padding)/textW)
+	if size < constants.RedactionLabelMinFontSize {
+		return
+	}
+	baseline := area.BBox.Y1*h - (boxH-size*0.7)/2
+	m := p.textMatrix(area.BBox.X0*w+padding, baseline)
+
+	var s bytes.Buffer
+	writeObject(&s, pdfString(label), nil)
+	fmt.Fprintf(buf, "q %s BT ", text)
+	writeName(buf, fontName)
+	fmt.Fprintf(buf, " %s Tf %s %s %s %s %s %s Tm %s Tj ET Q\n", formatFloat(size),
+		formatFloat(m[0]), formatFloat(m[1]), formatFloat(m[2]), formatFloat(m[3]), formatFloat(m[4]), formatFloat(m[5]),
+		s.String())
+}
+
+// winAnsi encodes a label for the standard Helvetica font, replacing the characters it
+// cannot show.
+func winAnsi(label string) []byte {
+	label = strings.TrimSpace(label)
+	out := make([]byte, 0, len(label))
+	for _, c := range label {
+		if (c >= ' ' && c <= '~') || (c >= 0xA0 && c <= 0xFF) {
+			out = append(out, byte(c))
+		} else {
+			out = append(out, '?')
+		}
+	}
+	return out
+}
@@ -0,0 +1,341 @@
+package redaction
+
+import (
+	"bytes"
+	"compress/zlib"
+	"errors"
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/yasinhessnawi1/Hideme_Backend/internal/constants"
+	"github.com/yasinhessnawi1/Hideme_Backend/internal/models"
+)
+
+// buildPDF writes a PDF with a cross-reference table from objects numbered from 1; the
+// first object is the catalog.
+func buildPDF(objects ...string) []byte {
+	var buf bytes.Buffer
+	buf.WriteString("%PDF-1.4\n")
+	offsets := make([]int, len(objects))
+	for i, obj := range objects {
+		offsets[i] = buf.Len()
+		fmt.Fprintf(&buf, "%d 0 obj\n%s\nendobj\n", i+1, obj)
+	}
+	xref := buf.Len()
+	fmt.Fprintf(&buf, "xref\n0 %d\n0000000000 65535 f \n", len(objects)+1)
+	for _, offset := range offsets {
+		fmt.Fprintf(&buf, "%010d 00000 n \n", offset)
+	}
+	fmt.Fprintf(&buf, "trailer\n<< /Size %d /Root 1 0 R >>\nstartxref\n%d\n%%%%EOF\n", len(objects)+1, xref)
+	return buf.Bytes()
+}
+
+// streamObject writes a stream object with its length.
+func streamObject(dict, data string) string {
+	return fmt.Sprintf("<< %s /Length %d >>\nstream\n%s\nendstream", dict, len(data), data)
+}
+
+// textPage builds a US Letter page showing content with Helvetica as /F1.
+func textPage(content string, pageExtra string, extra ...string) []byte {
+	objects := []string{
+		"<< /Type /Catalog /Pages 2 0 R >>",
+		"<< /Type /Pages /Kids [3 0 R] /Count 1 /MediaBox [0 0 612 792] >>",
+		"<< /Type /Page /Parent 2 0 R /Resources << /Font << /F1 4 0 R >> >> /Contents 5 0 R " + pageExtra + " >>",
+		"<< /Type /Font /Subtype /Type1 /BaseFont /Helvetica >>",
+		streamObject("", content),
+	}
+	return buildPDF(append(objects, extra...)...)
+}
+
+// userBox converts a rectangle of a US Letter page in user space to page-relative coordinates.
+func userBox(x0, y0, x1, y1 float64) models.BBox {
+	return models.BBox{X0: x0 / 612, Y0: (792 - y1) / 792, X1: x1 / 612, Y1: (792 - y0) / 792}
+}
+
+// shownText returns the text shown on a page of a PDF.
+func shownText(t *testing.T, pdf []byte, number int) string {
+	t.Helper()
+	doc, err := parseDocument(pdf)
+	require.NoError(t, err)
+	pages, err := doc.pages()
+	require.NoError(t, err)
+	content, err := doc.pageContent(pages[number-1].dict)
+	require.NoError(t, err)
+	ops, err := parseContent(content)
+	require.NoError(t, err)
+
+	var text strings.Builder
+	for _, op := range ops {
+		switch op.op {
+		case "Tj", "TJ", "'", "\"":
+			switch v := op.operands[len(op.operands)-1].(type) {
+			case pdfString:
+				text.Write(v)
+			case pdfArray:
+				for _, item := range v {
+					if s, ok := item.(pdfString); ok {
+						text.Write(s)
+					}
+				}
+			}
+		}
+	}
+	return text.String()
+}
+
+// fileContains reports whether any object of a PDF, with its streams decoded, contains a text.
+func fileContains(t *testing.T, pdf []byte, text string) bool {
+	t.Helper()
+	doc, err := parseDocument(pdf)
+	require.NoError(t, err)
+	for num := range doc.locations {
+		obj, err := doc.load(num)
+		require.NoError(t, err)
+		var buf bytes.Buffer
+		if stream, ok := obj.(*pdfStream); ok {
+			data, err := doc.decodeStream(stream)
+			require.NoError(t, err)
+			buf.Write(data)
+			obj = stream.dict
+		}
+		writeObject(&buf, obj, nil)
+		if bytes.Contains(buf.Bytes(), []byte(text)) || strings.Contains(buf.String(), fmt.Sprintf("%x", text)) {
+			return true
+		}
+	}
+	return false
+}
+
+func TestRedact_Text(t *testing.T) {
+	// "Hello " is 30.672 points wide at 12 points and "John Smith" 60.024 points
+	pdf := textPage("BT /F1 12 Tf 72 700 Td (Hello John Smith today) Tj ET", "")
+
+	result, err := Redact(pdf, []Area{{Page: 1, BBox: userBox(102, 695, 163, 712), Method: constants.RedactionMethodBlackout}})
+	require.NoError(t, err)
+
+	assert.Equal(t, "Hello  today", shownText(t, result.PDF, 1))
+	assert.Equal(t, 10, result.Glyphs)
+	assert.Equal(t, 1, result.RedactedPages)
+	assert.False(t, fileContains(t, result.PDF, "John"), "the redacted text must not remain in the file")
+
+	// The text after the redaction keeps its position
+	doc, err := parseDocument(result.PDF)
+	require.NoError(t, err)
+	pages, err := doc.pages()
+	require.NoError(t, err)
+	content, err := doc.pageContent(pages[0].dict)
+	require.NoError(t, err)
+	assert.Contains(t, string(content), "-5002 ")
+	assert.Contains(t, string(content), "0 g 102 695 61 17 re f")
+}
+
+func TestRedact_TextOperators(t *testing.T) {
+	content := strings.Join([]string{
+		"BT /F1 10 Tf 14 TL 72 700 Td",
+		"[(Secret) -250 (Public)] TJ",
+		"(Secret line) '",
+		"2 0 (Public line) \"",
+		"ET",
+		"q 2 0 0 2 0 0 cm BT /F1 10 Tf 36 300 Td (Scaled secret) Tj ET Q",
+	}, "\n")
+	pdf := textPage(content, "")
+
+	result, err := Redact(pdf, []Area{
+		// The first word on the first line and the whole second line
+		{Page: 1, BBox: userBox(70, 695, 104, 711), Method: constants.RedactionMethodBlackout},
+		{Page: 1, BBox: userBox(70, 682, 130, 694.5), Method: constants.RedactionMethodBlackout},
+		// Text drawn at twice the size, from 72,600 in user space
+		{Page: 1, BBox: userBox(70, 590, 400, 625), Method: constants.RedactionMethodBlackout},
+	})
+	require.NoError(t, err)
+
+	assert.Equal(t, "PublicPublic line", shownText(t, result.PDF, 1))
+}
+
+func TestRedact_Methods(t *testing.T) {
+	pdf := textPage("BT /F1 12 Tf 72 700 Td (Call Kari Nordmann) Tj ET", "")
+
+	t.Run("Mask", func(t *testing.T) {
+		result, err := Redact(pdf, []Area{{Page: 1, BBox: userBox(97, 690, 300, 715), Method: constants.RedactionMethodMask, Label: "PERSON"}})
+		require.NoError(t, err)
+
+		assert.Equal(t, "Call PERSON", shownText(t, result.PDF, 1))
+		assert.True(t, fileContains(t, result.PDF, "/WinAnsiEncoding"))
+	})
+
+	t.Run("Replace", func(t *testing.T) {
+		result, err := Redact(pdf, []Area{{Page: 1, BBox: userBox(97, 690, 300, 715), Method: constants.RedactionMethodReplace, Label: "Ola Nordmann"}})
+		require.NoError(t, err)
+
+		assert.Equal(t, "Call Ola Nordmann", shownText(t, result.PDF, 1))
+	})
+
+	t.Run("Label that doesn't fit", func(t *testing.T) {
+		result, err := Redact(pdf, []Area{{Page: 1, BBox: userBox(95, 700, 100, 701), Method: constants.RedactionMethodMask, Label: "PERSON"}})
+		require.NoError(t, err)
+
+		assert.NotContains(t, shownText(t, result.PDF, 1), "PERSON")
+	})
+}
+
+func TestRedact_ImagesAndAnnotations(t *testing.T) {
+	image := streamObject("/Type /XObject /Subtype /Image /Width 1 /Height 1 /ColorSpace /DeviceGray /BitsPerComponent 8", "\x80")
+	pdf := buildPDF(
+		"<< /Type /Catalog /Pages 2 0 R >>",
+		"<< /Type /Pages /Kids [3 0 R] /Count 1 /MediaBox [0 0 612 792] >>",
+		"<< /Type /Page /Parent 2 0 R /Resources << /XObject << /Im1 4 0 R /Im2 5 0 R /Fm1 8 0 R >> >> /Contents 6 0 R /Annots [7 0 R 9 0 R] >>",
+		image,
+		image,
+		streamObject("", "q 100 0 0 100 300 300 cm /Im1 Do Q q 100 0 0 100 50 50 cm /Im2 Do Q /Fm1 Do"),
+		"<< /Type /Annot /Subtype /Widget /Rect [310 310 350 350] /V (Account 1234) /FT /Tx >>",
+		streamObject("/Type /XObject /Subtype /Form /BBox [0 0 612 792] /Resources << /Font << /F1 10 0 R >> >>",
+			"BT /F1 12 Tf 320 340 Td (Form secret) Tj ET BT /F1 12 Tf 72 100 Td (Form public) Tj ET"),
+		"<< /Type /Annot /Subtype /Text /Rect [50 700 70 720] /Contents (Kept note) >>",
+		"<< /Type /Font /Subtype /Type1 /BaseFont /Helvetica >>",
+	)
+
+	result, err := Redact(pdf, []Area{{Page: 1, BBox: userBox(300, 300, 400, 400), Method: constants.RedactionMethodBlackout}})
+	require.NoError(t, err)
+
+	assert.Equal(t, 1, result.Images)
+	assert.Equal(t, 1, result.Annotations)
+	assert.Equal(t, 11, result.Glyphs)
+	assert.False(t, fileContains(t, result.PDF, "Account 1234"), "the value of the removed field must not remain")
+	assert.False(t, fileContains(t, result.PDF, "Form secret"))
+	assert.True(t, fileContains(t, result.PDF, "Form public"))
+	assert.True(t, fileContains(t, result.PDF, "Kept note"))
+
+	doc, err := parseDocument(result.PDF)
+	require.NoError(t, err)
+	images := 0
+	for num := range doc.locations {
+		obj, err := doc.load(num)
+		require.NoError(t, err)
+		if stream, ok := obj.(*pdfStream); ok && stream.dict["Subtype"] == pdfName("Image") {
+			images++
+		}
+	}
+	assert.Equal(t, 1, images, "the removed image must not remain in the file")
+}
+
+func TestRedact_CompressedFile(t *testing.T) {
+	compress := func(data string) string {
+		var buf bytes.Buffer
+		w := zlib.NewWriter(&buf)
+		_, _ = w.Write([]byte(data))
+		_ = w.Close()
+		return buf.String()
+	}
+
+	// Objects 1 to 4 are stored in the object stream 6, found through the cross-reference stream 7
+	objects := []string{
+		"<< /Type /Catalog /Pages 2 0 R >>",
+		"<< /Type /Pages /Kids [3 0 R] /Count 1 >>",
+		"<< /Type /Page /Parent 2 0 R /MediaBox [0 0 612 792] /Resources << /Font << /F1 4 0 R >> >> /Contents 5 0 R >>",
+		"<< /Type /Font /Subtype /Type1 /BaseFont /Courier >>",
+	}
+	var header, body strings.Builder
+	for i, obj := range objects {
+		fmt.Fprintf(&header, "%d %d ", i+1, body.Len())
+		body.WriteString(obj + "\n")
+	}
+	objStm := compress(header.String() + body.String())
+
+	var buf bytes.Buffer
+	buf.WriteString("%PDF-1.5\n")
+	offsets := map[int]int{}
+	content := compress("BT /F1 10 Tf 72 700 Td (ID 12345678) Tj ET")
+	offsets[5] = buf.Len()
+	fmt.Fprintf(&buf, "5 0 obj\n%s\nendobj\n", streamObject("/Filter /FlateDecode", content))
+	offsets[6] = buf.Len()
+	fmt.Fprintf(&buf, "6 0 obj\n%s\nendobj\n", streamObject(fmt.Sprintf("/Type /ObjStm /N 4 /First %d /Filter /FlateDecode", header.Len()), objStm))
+
+	offsets[7] = buf.Len()
+	var xref bytes.Buffer
+	xref.Write([]byte{0, 0, 0, 0xff})
+	for i := 0; i < 4; i++ {
+		xref.Write([]byte{2, 0, 6, byte(i)})
+	}
+	for num := 5; num <= 7; num++ {
+		xref.Write([]byte{1, byte(offsets[num] >> 8), byte(offsets[num]), 0})
+	}
+	fmt.Fprintf(&buf, "7 0 obj\n%s\nendobj\n", streamObject("/Type /XRef /Size 8 /W [1 2 1] /Root 1 0 R", xref.String()))
+	fmt.Fprintf(&buf, "startxref\n%d\n%%%%EOF\n", offsets[7])
+
+	// Courier is 6 points wide per character at 10 points, so the number starts at 90
+	result, err := Redact(buf.Bytes(), []Area{{Page: 1, BBox: userBox(89, 695, 140, 710), Method: constants.RedactionMethodBlackout}})
+	require.NoError(t, err)
+
+	assert.Equal(t, "ID ", shownText(t, result.PDF, 1))
+	assert.False(t, fileContains(t, result.PDF, "12345678"))
+}
+
+func TestRedact_IncrementalUpdate(t *testing.T) {
+	pdf := textPage("BT /F1 12 Tf 72 700 Td (Draft with Kari Nordmann) Tj ET", "")
+
+	// An incremental update replaces the content, keeping the earlier version in the file
+	var buf bytes.Buffer
+	buf.Write(pdf)
+	offset := buf.Len()
+	fmt.Fprintf(&buf, "5 0 obj\n%s\nendobj\n", streamObject("", "BT /F1 12 Tf 72 700 Td (Final text) Tj ET"))
+	prev := bytes.LastIndex(pdf, []byte("\nxref")) + 1
+	xref := buf.Len()
+	fmt.Fprintf(&buf, "xref\n5 1\n%010d 00000 n \ntrailer\n<< /Size 6 /Root 1 0 R /Prev %d >>\nstartxref\n%d\n%%%%EOF\n", offset, prev, xref)
+
+	result, err := Redact(buf.Bytes(), []Area{{Page: 1, BBox: userBox(72, 695, 100, 712), Method: constants.RedactionMethodBlackout}})
+	require.NoError(t, err)
+
+	assert.Equal(t, "text", strings.TrimSpace(shownText(t, result.PDF, 1)))
+	assert.False(t, fileContains(t, result.PDF, "Kari Nordmann"), "earlier revisions must not be carried over")
+}
+
+func TestRedact_PagesWithoutAreas(t *testing.T) {
+	pdf := textPage("BT /F1 12 Tf 72 700 Td (Nothing to hide) Tj ET", "")
+
+	result, err := Redact(pdf, []Area{{Page: 2, BBox: userBox(0, 0, 612, 792), Method: constants.RedactionMethodBlackout}})
+	require.NoError(t, err)
+
+	assert.Equal(t, 1, result.Pages)
+	assert.Equal(t, 0, result.RedactedPages)
+	assert.Equal(t, "Nothing to hide", shownText(t, result.PDF, 1))
+}
+
+func TestRedact_Errors(t *testing.T) {
+	_, err := Redact([]byte("not a pdf"), nil)
+	assert.True(t, errors.Is(err, ErrInvalidPDF))
+
+	encrypted := bytes.Replace(textPage("", ""), []byte("/Root 1 0 R"), []byte("/Root 1 0 R /Encrypt << /Filter /Standard >>"), 1)
+	_, err = Redact(encrypted, nil)
+	assert.True(t, errors.Is(err, ErrEncryptedPDF))
+
+	unsupported := textPage("", "")
+	unsupported = bytes.Replace(unsupported, []byte("<<  /Length 0 >>"), []byte("<< /Filter /JBIG2Decode /Length 0 >>"), 1)
+	_, err = Redact(unsupported, []Area{{Page: 1, BBox: userBox(0, 0, 10, 10)}})
+	assert.True(t, errors.Is(err, ErrUnsupportedPDF))
+}
+
+func TestPage_UserRect(t *testing.T) {
+	bbox := models.BBox{X0: 0.1, Y0: 0.2, X1: 0.3, Y1: 0.4}
+
+	tests := []struct {
+		rotate int
+		want   rect
+	}{
+		{0, rect{60, 480, 180, 640}},
+		{90, rect{120, 80, 240, 240}},
+		{180, rect{420, 160, 540, 320}},
+		{270, rect{360, 560, 480, 720}},
+	}
+	for _, tt := range tests {
+		p := page{box: rect{0, 0, 600, 800}, rotate: tt.rotate}
+		got := p.userRect(bbox)
+		assert.InDelta(t, tt.want.x0, got.x0, 1e-9, "rotate %d", tt.rotate)
+		assert.InDelta(t, tt.want.y0, got.y0, 1e-9, "rotate %d", tt.rotate)
+		assert.InDelta(t, tt.want.x1, got.x1, 1e-9, "rotate %d", tt.rotate)
+		assert.InDelta(t, tt.want.y1, got.y1, 1e-9, "rotate %d", tt.rotate)
+	}
+}
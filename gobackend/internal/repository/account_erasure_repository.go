@@ -0,0 +1,379 @@
+// Package repository provides data access interfaces and implementations for the HideMe application.
+// It follows the repository pattern to abstract database operations and provide a clean API
+// for data persistence operations.
+//
+// This file implements the account erasure repository, which tracks the scheduled erasures
+// of user accounts while they wait for their grace period and run in the background.
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/lib/pq"
+
+	"github.com/yasinhessnawi1/Hideme_Backend/internal/constants"
+	"github.com/yasinhessnawi1/Hideme_Backend/internal/database"
+	"github.com/yasinhessnawi1/Hideme_Backend/internal/models"
+	"github.com/yasinhessnawi1/Hideme_Backend/internal/utils"
+)
+
+// AccountErasureRepository defines methods for tracking the erasures of user accounts.
+type AccountErasureRepository interface {
+	// Create stores a new scheduled erasure.
+	//
+	// Parameters:
+	//   - ctx: Context for transaction and cancellation control
+	//   - erasure: The erasure to store; its ID is set on success
+	//
+	// Returns:
+	//   - DuplicateError if the account already has an unfinished erasure
+	//   - Other errors for database issues
+	Create(ctx context.Context, erasure *models.AccountErasure) error
+
+	// GetActiveByUserID retrieves the unfinished erasure of an account.
+	//
+	// Parameters:
+	//   - ctx: Context for transaction and cancellation control
+	//   - userID: The ID of the user
+	//
+	// Returns:
+	//   - The scheduled or running erasure
+	//   - NotFoundError if the account has no unfinished erasure
+	//   - Other errors for database issues
+	GetActiveByUserID(ctx context.Context, userID int64) (*models.AccountErasure, error)
+
+	// Cancel cancels an erasure that has not been attempted yet.
+	//
+	// Parameters:
+	//   - ctx: Context for transaction and cancellation control
+	//   - id: The ID of the erasure
+	//   - now: The time of the cancellation
+	//
+	// Returns:
+	//   - NotFoundError if the erasure doesn't exist or was already attempted
+	//   - Other errors for database issues
+	Cancel(ctx context.Context, id int64, now time.Time) error
+
+	// Claim takes the erasure that has been due longest: a scheduled erasure whose time has
+	// come, or a running erasure whose server stopped before its lease ran out. The erasure
+	// is set running and its attempts are counted.
+	//
+	// Parameters:
+	//   - ctx: Context for transaction and cancellation control
+	//   - now: The current time, to find due erasures and expired leases
+	//   - leaseUntil: When another server may take the erasure over
+	//
+	// Returns:
+	//   - The claimed erasure, or nil if no erasure is due
+	//   - An error for database issues
+	Claim(ctx context.Context, now, leaseUntil time.Time) (*models.AccountErasure, error)
+
+	// Complete records that the account and its data were erased.
+	//
+	// Parameters:
+	//   - ctx: Context for transaction and cancellation control
+	//   - id: The ID of the erasure
+	//   - now: The time the erasure finished
+	//
+	// Returns:
+	//   - NotFoundError if the erasure doesn't exist
+	//   - Other errors for database issues
+	Complete(ctx context.Context, id int64, now time.Time) error
+
+	// Reschedule releases a running erasure whose attempt failed, to attempt it again later.
+	//
+	// Parameters:
+	//   - ctx: Context for transaction and cancellation control
+	//   - id: The ID of the erasure
+	//   - retryAt: When to attempt the erasure again
+	//   - lastError: Why the attempt failed
+	//
+	// Returns:
+	//   - NotFoundError if the erasure doesn't exist
+	//   - Other errors for database issues
+	Reschedule(ctx context.Context, id int64, retryAt time.Time, lastError string) error
+}
+
+// PostgresAccountErasureRepository is a PostgreSQL implementation of AccountErasureRepository.
+type PostgresAccountErasureRepository struct {
+	db *database.Pool
+}
+
+// NewAccountErasureRepository creates a new AccountErasureRepository implementation for PostgreSQL.
+//
+// Parameters:
+//   - db: A connection pool for PostgreSQL database access
+//
+// Returns:
+//   - An implementation of the AccountErasureRepository interface
+func NewAccountErasureRepository(db *database.Pool) AccountErasureRepository {
+	return &PostgresAccountErasureRepository{
+		db: db,
+	}
+}
+
+// accountErasureColumns is the column list shared by all erasure queries.
+const accountErasureColumns = `erasure_id, ` + constants.ColumnUserID + `, status, scheduled_for, attempts, last_error,
+               lease_until, created_at, updated_at, completed_at`
+
+// scanAccountErasure scans the accountErasureColumns of a row.
+//
+// Parameters:
+//   - scanner: The row to scan from
+//
+// Returns:
+//   - The scanned erasure
+//   - An error if scanning fails
+func scanAccountErasure(scanner interface{ Scan(dest ...any) error }) (*models.AccountErasure, error) {
+	erasure := &models.AccountErasure{}
+	err := scanner.Scan(
+		&erasure.ID,
+		&erasure.UserID,
+		&erasure.Status,
+		&erasure.ScheduledFor,
+		&erasure.Attempts,
+		&erasure.LastError,
+		&erasure.LeaseUntil,
+		&erasure.CreatedAt,
+		&erasure.UpdatedAt,
+		&erasure.CompletedAt,
+	)
+	return erasure, err
+}
+
+// Create stores a new scheduled erasure.
+func (r *PostgresAccountErasureRepository) Create(ctx context.Context, erasure *models.AccountErasure) error {
+	// Start query timer
+	startTime := time.Now()
+
+	// Define the query
+	query := `
+        INSERT INTO ` + constants.TableAccountErasures + ` (` + constants.ColumnUserID + `, status, scheduled_for, created_at, updated_at)
+        VALUES ($1, $2, $3, $4, $4)
+        RETURNING erasure_id
+    `
+	args := []interface{}{erasure.UserID, erasure.Status, erasure.ScheduledFor, erasure.CreatedAt}
+
+	// Execute the query
+	err := r.db.QueryRowContext(ctx, query, args...).Scan(&erasure.ID)
+
+	// Log the query execution
+	utils.LogDBQuery(
+		query,
+		args,
+		time.Since(startTime),
+		err,
+	)
+
+	if err != nil {
+		var pqErr *pq.Error
+		if errors.As(err, &pqErr) && pqErr.Code == constants.PGErrorDuplicateConstraint {
+			return utils.NewDuplicateError("AccountErasure", "status", erasure.Status).WithCause(err)
+		}
+		return fmt.Errorf("failed to create account erasure: %w", err)
+	}
+
+	return nil
+}
+
+// GetActiveByUserID retrieves the unfinished erasure of an account.
+func (r *PostgresAccountErasureRepository) GetActiveByUserID(ctx context.Context, userID int64) (*models.AccountErasure, error) {
+	// Start query timer
+	startTime := time.Now()
+
+	// Define the query
+	query := `
+        SELECT ` + accountErasureColumns + `
+        FROM ` + constants.TableAccountErasures + `
+        WHERE ` + constants.ColumnUserID + ` = $1 AND status IN ($2, $3)
+    `
+	args := []interface{}{userID, constants.AccountErasureScheduled, constants.AccountErasureRunning}
+
+	// Execute the query
+	erasure, err := scanAccountErasure(r.db.QueryRowContext(ctx, query, args...))
+
+	// Log the query execution
+	utils.LogDBQuery(
+		query,
+		args,
+		time.Since(startTime),
+		err,
+	)
+
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, utils.NewNotFoundError("AccountErasure", userID)
+		}
+		return nil, fmt.Errorf("failed to get account erasure: %w", err)
+	}
+
+	return erasure, nil
+}
+
+// Cancel cancels an erasure that has not been attempted yet.
+func (r *PostgresAccountErasureRepository) Cancel(ctx context.Context, id int64, now time.Time) error {
+	// Start query timer
+	startTime := time.Now()
+
+	// Define the query
+	query := `
+        UPDATE ` + constants.TableAccountErasures + `
+        SET status = $1,
+            updated_at = $2,
+            completed_at = $2
+        WHERE erasure_id = $3 AND status = $4 AND attempts = 0
+    `
+	args := []interface{}{constants.AccountErasureCancelled, now, id, constants.AccountErasureScheduled}
+
+	// Execute the query
+	result, err := r.db.ExecContext(ctx, query, args...)
+
+	// Log the query execution
+	utils.LogDBQuery(
+		query,
+		args,
+		time.Since(startTime),
+		err,
+	)
+
+	if err != nil {
+		return fmt.Errorf("failed to cancel account erasure: %w", err)
+	}
+
+	return requireAccountErasureAffected(result, id)
+}
+
+// Claim takes the erasure that has been due longest.
+// The erasure is locked while it is claimed; erasures locked by another server are skipped.
+func (r *PostgresAccountErasureRepository) Claim(ctx context.Context, now, leaseUntil time.Time) (*models.AccountErasure, error) {
+	// Start query timer
+	startTime := time.Now()
+
+	// Define the query
+	query := `
+        UPDATE ` + constants.TableAccountErasures + `
+        SET status = $1,
+            attempts = attempts + 1,
+            lease_until = $2,
+            updated_at = $3
+        WHERE erasure_id = (
+            SELECT erasure_id
+            FROM ` + constants.TableAccountErasures + `
+            WHERE (status = $4 AND scheduled_for <= $3) OR (status = $1 AND lease_until < $3)
+            ORDER BY scheduled_for
+            LIMIT 1
+            FOR UPDATE SKIP LOCKED
+        )
+        RETURNING ` + accountErasureColumns + `
+    `
+	args := []interface{}{constants.AccountErasureRunning, leaseUntil, now, constants.AccountErasureScheduled}
+
+	// Execute the query
+	erasure, err := scanAccountErasure(r.db.QueryRowContext(ctx, query, args...))
+
+	// Log the query execution
+	utils.LogDBQuery(
+		query,
+		args,
+		time.Since(startTime),
+		err,
+	)
+
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("failed to claim account erasure: %w", err)
+	}
+
+	return erasure, nil
+}
+
+// Complete records that the account and its data were erased.
+func (r *PostgresAccountErasureRepository) Complete(ctx context.Context, id int64, now time.Time) error {
+	// Start query timer
+	startTime := time.Now()
+
+	// Define the query
+	query := `
+        UPDATE ` + constants.TableAccountErasures + `
+        SET status = $1,
+            last_error = '',
+            lease_until = NULL,
+            updated_at = $2,
+            completed_at = $2
+        WHERE erasure_id = $3
+    `
+	args := []interface{}{constants.AccountErasureCompleted, now, id}
+
+	// Execute the query
+	result, err := r.db.ExecContext(ctx, query, args...)
+
+	// Log the query execution
+	utils.LogDBQuery(
+		query,
+		args,
+		time.Since(startTime),
+		err,
+	)
+
+	if err != nil {
+		return fmt.Errorf("failed to complete account erasure: %w", err)
+	}
+
+	return requireAccountErasureAffected(result, id)
+}
+
+// Reschedule releases a running erasure whose attempt failed, to attempt it again later.
+func (r *PostgresAccountErasureRepository) Reschedule(ctx context.Context, id int64, retryAt time.Time, lastError string) error {
+	// Start query timer
+	startTime := time.Now()
+
+	// Define the query
+	query := `
+        UPDATE ` + constants.TableAccountErasures + `
+        SET status = $1,
+            scheduled_for = $2,
+            last_error = $3,
+            lease_until = NULL,
+            updated_at = $4
+        WHERE erasure_id = $5
+    `
+	now := time.Now()
+	args := []interface{}{constants.AccountErasureScheduled, retryAt, lastError, now, id}
+
+	// Execute the query
+	result, err := r.db.ExecContext(ctx, query, args...)
+
+	// Log the query execution
+	utils.LogDBQuery(
+		query,
+		args,
+		time.Since(startTime),
+		err,
+	)
+
+	if err != nil {
+		return fmt.Errorf("failed to reschedule account erasure: %w", err)
+	}
+
+	return requireAccountErasureAffected(result, id)
+}
+
+// requireAccountErasureAffected returns NotFoundError if a statement changed no erasure.
+func requireAccountErasureAffected(result sql.Result, id int64) error {
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("failed to get affected rows: %w", err)
+	}
+
+	if rowsAffected == 0 {
+		return utils.NewNotFoundError("AccountErasure", id)
+	}
+
+	return nil
+}
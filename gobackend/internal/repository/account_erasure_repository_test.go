@@ -0,0 +1,145 @@
+package repository_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/lib/pq"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/yasinhessnawi1/Hideme_Backend/internal/constants"
+	"github.com/yasinhessnawi1/Hideme_Backend/internal/database"
+	"github.com/yasinhessnawi1/Hideme_Backend/internal/models"
+	"github.com/yasinhessnawi1/Hideme_Backend/internal/repository"
+	"github.com/yasinhessnawi1/Hideme_Backend/internal/utils"
+)
+
+var accountErasureTestColumns = []string{
+	"erasure_id", "user_id", "status", "scheduled_for", "attempts", "last_error",
+	"lease_until", "created_at", "updated_at", "completed_at",
+}
+
+func setupAccountErasureTest(t *testing.T) (repository.AccountErasureRepository, sqlmock.Sqlmock, func()) {
+	db, mock, err := sqlmock.New()
+	require.NoError(t, err)
+
+	return repository.NewAccountErasureRepository(&database.Pool{DB: db}), mock, func() { db.Close() }
+}
+
+func TestAccountErasureRepository_Create(t *testing.T) {
+	repo, mock, cleanup := setupAccountErasureTest(t)
+	defer cleanup()
+	now := time.Now()
+	erasure := models.NewAccountErasure(7, now)
+
+	mock.ExpectQuery("INSERT INTO account_erasures").
+		WithArgs(int64(7), constants.AccountErasureScheduled, erasure.ScheduledFor, now).
+		WillReturnRows(sqlmock.NewRows([]string{"erasure_id"}).AddRow(3))
+
+	require.NoError(t, repo.Create(context.Background(), erasure))
+	assert.Equal(t, int64(3), erasure.ID)
+
+	// The account already has an unfinished erasure
+	mock.ExpectQuery("INSERT INTO account_erasures").
+		WillReturnError(&pq.Error{Code: constants.PGErrorDuplicateConstraint})
+
+	err := repo.Create(context.Background(), models.NewAccountErasure(7, now))
+	var appErr *utils.AppError
+	require.True(t, errors.As(err, &appErr))
+	assert.True(t, errors.Is(appErr.Err, utils.ErrDuplicate))
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestAccountErasureRepository_GetActiveByUserID(t *testing.T) {
+	repo, mock, cleanup := setupAccountErasureTest(t)
+	defer cleanup()
+	now := time.Now()
+
+	mock.ExpectQuery("SELECT (.+) FROM account_erasures WHERE user_id = \\$1 AND status IN").
+		WithArgs(int64(7), constants.AccountErasureScheduled, constants.AccountErasureRunning).
+		WillReturnRows(sqlmock.NewRows(accountErasureTestColumns).
+			AddRow(3, 7, constants.AccountErasureScheduled, now, 0, "", nil, now, now, nil))
+
+	erasure, err := repo.GetActiveByUserID(context.Background(), 7)
+	require.NoError(t, err)
+	assert.Equal(t, int64(3), erasure.ID)
+	assert.Equal(t, constants.AccountErasureScheduled, erasure.Status)
+
+	mock.ExpectQuery("SELECT (.+) FROM account_erasures").
+		WillReturnRows(sqlmock.NewRows(accountErasureTestColumns))
+
+	_, err = repo.GetActiveByUserID(context.Background(), 8)
+	var appErr *utils.AppError
+	require.True(t, errors.As(err, &appErr))
+	assert.True(t, errors.Is(appErr.Err, utils.ErrNotFound))
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestAccountErasureRepository_Cancel(t *testing.T) {
+	repo, mock, cleanup := setupAccountErasureTest(t)
+	defer cleanup()
+	now := time.Now()
+
+	mock.ExpectExec("UPDATE account_erasures SET status = \\$1(.+)WHERE erasure_id = \\$3 AND status = \\$4 AND attempts = 0").
+		WithArgs(constants.AccountErasureCancelled, now, int64(3), constants.AccountErasureScheduled).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	require.NoError(t, repo.Cancel(context.Background(), 3, now))
+
+	// The erasure was already attempted
+	mock.ExpectExec("UPDATE account_erasures").
+		WillReturnResult(sqlmock.NewResult(0, 0))
+	err := repo.Cancel(context.Background(), 4, now)
+	var appErr *utils.AppError
+	require.True(t, errors.As(err, &appErr))
+	assert.True(t, errors.Is(appErr.Err, utils.ErrNotFound))
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestAccountErasureRepository_Claim(t *testing.T) {
+	repo, mock, cleanup := setupAccountErasureTest(t)
+	defer cleanup()
+	now := time.Now()
+	leaseUntil := now.Add(constants.AccountErasureLease)
+
+	mock.ExpectQuery("UPDATE account_erasures SET status = \\$1, attempts = attempts \\+ 1(.+)FOR UPDATE SKIP LOCKED").
+		WithArgs(constants.AccountErasureRunning, leaseUntil, now, constants.AccountErasureScheduled).
+		WillReturnRows(sqlmock.NewRows(accountErasureTestColumns).
+			AddRow(3, 7, constants.AccountErasureRunning, now, 1, "", leaseUntil, now, now, nil))
+
+	erasure, err := repo.Claim(context.Background(), now, leaseUntil)
+	require.NoError(t, err)
+	require.NotNil(t, erasure)
+	assert.Equal(t, int64(7), erasure.UserID)
+	assert.Equal(t, 1, erasure.Attempts)
+
+	// Nothing is due
+	mock.ExpectQuery("UPDATE account_erasures").
+		WillReturnRows(sqlmock.NewRows(accountErasureTestColumns))
+
+	erasure, err = repo.Claim(context.Background(), now, leaseUntil)
+	require.NoError(t, err)
+	assert.Nil(t, erasure)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestAccountErasureRepository_CompleteAndReschedule(t *testing.T) {
+	repo, mock, cleanup := setupAccountErasureTest(t)
+	defer cleanup()
+	now := time.Now()
+
+	mock.ExpectExec("UPDATE account_erasures SET status = \\$1, last_error = '', lease_until = NULL").
+		WithArgs(constants.AccountErasureCompleted, now, int64(3)).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	require.NoError(t, repo.Complete(context.Background(), 3, now))
+
+	retryAt := now.Add(constants.AccountErasureRetryDelay)
+	mock.ExpectExec("UPDATE account_erasures SET status = \\$1, scheduled_for = \\$2, last_error = \\$3").
+		WithArgs(constants.AccountErasureScheduled, retryAt, "boom", sqlmock.AnyArg(), int64(4)).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	require.NoError(t, repo.Reschedule(context.Background(), 4, retryAt, "boom"))
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
@@ -0,0 +1,336 @@
+// Package repository provides data access interfaces and implementations for the HideMe application.
+// It follows the repository pattern to abstract database operations and provide a clean API
+// for data persistence operations.
+//
+// This file implements the account hold repository, which stores accounts held back
+// by risk scoring until they pass email verification or manual review.
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/yasinhessnawi1/Hideme_Backend/internal/database"
+	"github.com/yasinhessnawi1/Hideme_Backend/internal/models"
+	"github.com/yasinhessnawi1/Hideme_Backend/internal/utils"
+)
+
+// AccountHoldRepository defines methods for managing account holds.
+type AccountHoldRepository interface {
+	// Create places a hold on an account, replacing any existing hold.
+	//
+	// Parameters:
+	//   - ctx: Context for transaction and cancellation control
+	//   - hold: The hold to store
+	//
+	// Returns:
+	//   - An error if the hold could not be stored
+	Create(ctx context.Context, hold *models.AccountHold) error
+
+	// GetByUserID retrieves the hold on an account.
+	//
+	// Parameters:
+	//   - ctx: Context for transaction and cancellation control
+	//   - userID: The ID of the held user
+	//
+	// Returns:
+	//   - The hold if found
+	//   - NotFoundError if the account is not held
+	//   - Other errors for database issues
+	GetByUserID(ctx context.Context, userID int64) (*models.AccountHold, error)
+
+	// GetByTokenHash retrieves a hold by the hash of its email verification token.
+	//
+	// Parameters:
+	//   - ctx: Context for transaction and cancellation control
+	//   - tokenHash: The hash of the verification token
+	//
+	// Returns:
+	//   - The hold if found
+	//   - NotFoundError if no hold has this token
+	//   - Other errors for database issues
+	GetByTokenHash(ctx context.Context, tokenHash string) (*models.AccountHold, error)
+
+	// List retrieves all account holds, oldest first.
+	//
+	// Parameters:
+	//   - ctx: Context for transaction and cancellation control
+	//
+	// Returns:
+	//   - The holds (empty if there are none)
+	//   - An error for database issues
+	List(ctx context.Context) ([]*models.AccountHold, error)
+
+	// Delete lifts the hold on an account.
+	//
+	// Parameters:
+	//   - ctx: Context for transaction and cancellation control
+	//   - userID: The ID of the held user
+	//
+	// Returns:
+	//   - NotFoundError if the account is not held
+	//   - Other errors for database issues
+	Delete(ctx context.Context, userID int64) error
+}
+
+// PostgresAccountHoldRepository is a PostgreSQL implementation of AccountHoldRepository.
+type PostgresAccountHoldRepository struct {
+	db *database.Pool
+}
+
+// NewAccountHoldRepository creates a new AccountHoldRepository implementation for PostgreSQL.
+//
+// Parameters:
+//   - db: A connection pool for PostgreSQL database access
+//
+// Returns:
+//   - An implementation of the AccountHoldRepository interface
+func NewAccountHoldRepository(db *database.Pool) AccountHoldRepository {
+	return &PostgresAccountHoldRepository{
+		db: db,
+	}
+}
+
+// accountHoldColumns is the column list shared by all account hold queries.
+const accountHoldColumns = `user_id, hold_type, risk_score, COALESCE(reasons, ''), COALESCE(token_hash, ''), created_at, expires_at`
+
+// scanAccountHold scans a single account hold row.
+//
+// Parameters:
+//   - scanner: The row or rows to scan from
+//
+// Returns:
+//   - The scanned hold
+//   - An error if scanning fails
+func scanAccountHold(scanner interface{ Scan(dest ...any) error }) (*models.AccountHold, error) {
+	hold := &models.AccountHold{}
+	err := scanner.Scan(
+		&hold.UserID,
+		&hold.HoldType,
+		&hold.RiskScore,
+		&hold.Reasons,
+		&hold.TokenHash,
+		&hold.CreatedAt,
+		&hold.ExpiresAt,
+	)
+	return hold, err
+}
+
+// Create places a hold on an account, replacing any existing hold.
+//
+// Parameters:
+//   - ctx: Context for transaction and cancellation control
+//   - hold: The hold to store
+//
+// Returns:
+//   - An error if the hold could not be stored
+func (r *PostgresAccountHoldRepository) Create(ctx context.Context, hold *models.AccountHold) error {
+	// Start query timer
+	startTime := time.Now()
+
+	// Define the query
+	query := `
+        INSERT INTO account_holds (user_id, hold_type, risk_score, reasons, token_hash, created_at, expires_at)
+        VALUES ($1, $2, $3, $4, NULLIF($5, ''), $6, $7)
+        ON CONFLICT (user_id) DO UPDATE
+        SET hold_type = EXCLUDED.hold_type, risk_score = EXCLUDED.risk_score, reasons = EXCLUDED.reasons,
+            token_hash = EXCLUDED.token_hash, created_at = EXCLUDED.created_at, expires_at = EXCLUDED.expires_at
+    `
+
+	// Execute the query
+	_, err := r.db.ExecContext(
+		ctx,
+		query,
+		hold.UserID,
+		hold.HoldType,
+		hold.RiskScore,
+		hold.Reasons,
+		hold.TokenHash,
+		hold.CreatedAt,
+		hold.ExpiresAt,
+	)
+
+	// Log the query execution
+	utils.LogDBQuery(
+		query,
+		[]interface{}{hold.UserID, hold.HoldType, hold.RiskScore},
+		time.Since(startTime),
+		err,
+	)
+
+	if err != nil {
+		return fmt.Errorf("failed to create account hold: %w", err)
+	}
+
+	return nil
+}
+
+// GetByUserID retrieves the hold on an account.
+//
+// Parameters:
+//   - ctx: Context for transaction and cancellation control
+//   - userID: The ID of the held user
+//
+// Returns:
+//   - The hold if found
+//   - NotFoundError if the account is not held
+//   - Other errors for database issues
+func (r *PostgresAccountHoldRepository) GetByUserID(ctx context.Context, userID int64) (*models.AccountHold, error) {
+	// Start query timer
+	startTime := time.Now()
+
+	// Define the query
+	query := `SELECT ` + accountHoldColumns + ` FROM account_holds WHERE user_id = $1`
+
+	// Execute the query
+	hold, err := scanAccountHold(r.db.QueryRowContext(ctx, query, userID))
+
+	// Log the query execution
+	utils.LogDBQuery(
+		query,
+		[]interface{}{userID},
+		time.Since(startTime),
+		err,
+	)
+
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, utils.NewNotFoundError("AccountHold", userID).WithCause(err)
+		}
+		return nil, fmt.Errorf("failed to get account hold: %w", err)
+	}
+
+	return hold, nil
+}
+
+// GetByTokenHash retrieves a hold by the hash of its email verification token.
+//
+// Parameters:
+//   - ctx: Context for transaction and cancellation control
+//   - tokenHash: The hash of the verification token
+//
+// Returns:
+//   - The hold if found
+//   - NotFoundError if no hold has this token
+//   - Other errors for database issues
+func (r *PostgresAccountHoldRepository) GetByTokenHash(ctx context.Context, tokenHash string) (*models.AccountHold, error) {
+	// Start query timer
+	startTime := time.Now()
+
+	// Define the query
+	query := `SELECT ` + accountHoldColumns + ` FROM account_holds WHERE token_hash = $1`
+
+	// Execute the query
+	hold, err := scanAccountHold(r.db.QueryRowContext(ctx, query, tokenHash))
+
+	// Log the query execution; the token hash itself is not logged
+	utils.LogDBQuery(
+		query,
+		[]interface{}{"[REDACTED]"},
+		time.Since(startTime),
+		err,
+	)
+
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, utils.NewNotFoundError("AccountHold", "token").WithCause(err)
+		}
+		return nil, fmt.Errorf("failed to get account hold by token: %w", err)
+	}
+
+	return hold, nil
+}
+
+// List retrieves all account holds, oldest first.
+//
+// Parameters:
+//   - ctx: Context for transaction and cancellation control
+//
+// Returns:
+//   - The holds (empty if there are none)
+//   - An error for database issues
+func (r *PostgresAccountHoldRepository) List(ctx context.Context) ([]*models.AccountHold, error) {
+	// Start query timer
+	startTime := time.Now()
+
+	// Define the query
+	query := `SELECT ` + accountHoldColumns + ` FROM account_holds ORDER BY created_at, user_id`
+
+	// Execute the query
+	rows, err := r.db.QueryContext(ctx, query)
+
+	// Log the query execution
+	utils.LogDBQuery(
+		query,
+		[]interface{}{},
+		time.Since(startTime),
+		err,
+	)
+
+	if err != nil {
+		return nil, fmt.Errorf("failed to list account holds: %w", err)
+	}
+	defer rows.Close()
+
+	holds := []*models.AccountHold{}
+	for rows.Next() {
+		hold, err := scanAccountHold(rows)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan account hold row: %w", err)
+		}
+		holds = append(holds, hold)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating account hold rows: %w", err)
+	}
+
+	return holds, nil
+}
+
+// Delete lifts the hold on an account.
+//
+// Parameters:
+//   - ctx: Context for transaction and cancellation control
+//   - userID: The ID of the held user
+//
+// Returns:
+//   - NotFoundError if the account is not held
+//   - Other errors for database issues
+func (r *PostgresAccountHoldRepository) Delete(ctx context.Context, userID int64) error {
+	// Start query timer
+	startTime := time.Now()
+
+	// Define the query
+	query := `DELETE FROM account_holds WHERE user_id = $1`
+
+	// Execute the query
+	result, err := r.db.ExecContext(ctx, query, userID)
+
+	// Log the query execution
+	utils.LogDBQuery(
+		query,
+		[]interface{}{userID},
+		time.Since(startTime),
+		err,
+	)
+
+	if err != nil {
+		return fmt.Errorf("failed to delete account hold: %w", err)
+	}
+
+	// Check if a hold was deleted
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("failed to get affected rows: %w", err)
+	}
+
+	if rowsAffected == 0 {
+		return utils.NewNotFoundError("AccountHold", userID)
+	}
+
+	return nil
+}
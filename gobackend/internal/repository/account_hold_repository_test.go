@@ -0,0 +1,224 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/yasinhessnawi1/Hideme_Backend/internal/models"
+	"github.com/yasinhessnawi1/Hideme_Backend/internal/utils"
+)
+
+var accountHoldTestColumns = []string{
+	"user_id", "hold_type", "risk_score", "reasons", "token_hash", "created_at", "expires_at",
+}
+
+func TestNewAccountHoldRepository(t *testing.T) {
+	// Arrange
+	pool, _, cleanup := setupDBMock(t)
+	defer cleanup()
+
+	// Act
+	repo := NewAccountHoldRepository(pool)
+
+	// Assert
+	assert.NotNil(t, repo, "Repository should not be nil")
+	assert.Implements(t, (*AccountHoldRepository)(nil), repo, "Should implement AccountHoldRepository interface")
+}
+
+func TestAccountHoldRepository_Create(t *testing.T) {
+	t.Run("Success", func(t *testing.T) {
+		// Arrange
+		pool, mock, cleanup := setupDBMock(t)
+		defer cleanup()
+		repo := NewAccountHoldRepository(pool)
+		hold := models.NewAccountHold(42, models.RiskActionManualReview, 90, "disposable email")
+
+		mock.ExpectExec("INSERT INTO account_holds (.+) ON CONFLICT \\(user_id\\) DO UPDATE").
+			WithArgs(int64(42), models.RiskActionManualReview, 90, "disposable email", "", sqlmock.AnyArg(), sqlmock.AnyArg()).
+			WillReturnResult(sqlmock.NewResult(0, 1))
+
+		// Act
+		err := repo.Create(context.Background(), hold)
+
+		// Assert
+		assert.NoError(t, err)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("Database Error", func(t *testing.T) {
+		// Arrange
+		pool, mock, cleanup := setupDBMock(t)
+		defer cleanup()
+		repo := NewAccountHoldRepository(pool)
+
+		mock.ExpectExec("INSERT INTO account_holds").
+			WillReturnError(errors.New("database error"))
+
+		// Act
+		err := repo.Create(context.Background(), models.NewAccountHold(42, models.RiskActionManualReview, 90, ""))
+
+		// Assert
+		assert.Error(t, err)
+		assert.Contains(t, err.Error(), "failed to create account hold")
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+}
+
+func TestAccountHoldRepository_GetByUserID(t *testing.T) {
+	now := time.Now()
+
+	t.Run("Success", func(t *testing.T) {
+		// Arrange
+		pool, mock, cleanup := setupDBMock(t)
+		defer cleanup()
+		repo := NewAccountHoldRepository(pool)
+
+		rows := sqlmock.NewRows(accountHoldTestColumns).
+			AddRow(42, "email_verification", 55, "disposable email", "hash", now, now.Add(time.Hour))
+		mock.ExpectQuery("SELECT (.+) FROM account_holds WHERE user_id = \\$1").
+			WithArgs(int64(42)).
+			WillReturnRows(rows)
+
+		// Act
+		hold, err := repo.GetByUserID(context.Background(), 42)
+
+		// Assert
+		require.NoError(t, err)
+		assert.Equal(t, int64(42), hold.UserID)
+		assert.Equal(t, models.RiskActionEmailVerification, hold.HoldType)
+		assert.Equal(t, "hash", hold.TokenHash)
+		assert.NotNil(t, hold.ExpiresAt)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("Not Found", func(t *testing.T) {
+		// Arrange
+		pool, mock, cleanup := setupDBMock(t)
+		defer cleanup()
+		repo := NewAccountHoldRepository(pool)
+
+		mock.ExpectQuery("SELECT (.+) FROM account_holds").
+			WithArgs(int64(99)).
+			WillReturnRows(sqlmock.NewRows(accountHoldTestColumns))
+
+		// Act
+		hold, err := repo.GetByUserID(context.Background(), 99)
+
+		// Assert
+		assert.Nil(t, hold)
+		assert.True(t, errors.Is(err, utils.ErrNotFound))
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+}
+
+func TestAccountHoldRepository_GetByTokenHash(t *testing.T) {
+	now := time.Now()
+
+	t.Run("Success", func(t *testing.T) {
+		// Arrange
+		pool, mock, cleanup := setupDBMock(t)
+		defer cleanup()
+		repo := NewAccountHoldRepository(pool)
+
+		rows := sqlmock.NewRows(accountHoldTestColumns).
+			AddRow(42, "email_verification", 55, "", "hash", now, now.Add(time.Hour))
+		mock.ExpectQuery("SELECT (.+) FROM account_holds WHERE token_hash = \\$1").
+			WithArgs("hash").
+			WillReturnRows(rows)
+
+		// Act
+		hold, err := repo.GetByTokenHash(context.Background(), "hash")
+
+		// Assert
+		require.NoError(t, err)
+		assert.Equal(t, int64(42), hold.UserID)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("Not Found", func(t *testing.T) {
+		// Arrange
+		pool, mock, cleanup := setupDBMock(t)
+		defer cleanup()
+		repo := NewAccountHoldRepository(pool)
+
+		mock.ExpectQuery("SELECT (.+) FROM account_holds").
+			WithArgs("missing").
+			WillReturnRows(sqlmock.NewRows(accountHoldTestColumns))
+
+		// Act
+		hold, err := repo.GetByTokenHash(context.Background(), "missing")
+
+		// Assert
+		assert.Nil(t, hold)
+		assert.True(t, errors.Is(err, utils.ErrNotFound))
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+}
+
+func TestAccountHoldRepository_List(t *testing.T) {
+	// Arrange
+	now := time.Now()
+	pool, mock, cleanup := setupDBMock(t)
+	defer cleanup()
+	repo := NewAccountHoldRepository(pool)
+
+	rows := sqlmock.NewRows(accountHoldTestColumns).
+		AddRow(1, "manual_review", 90, "listed ip", "", now, nil).
+		AddRow(2, "email_verification", 50, "disposable email", "hash", now, now.Add(time.Hour))
+	mock.ExpectQuery("SELECT (.+) FROM account_holds ORDER BY created_at").
+		WillReturnRows(rows)
+
+	// Act
+	holds, err := repo.List(context.Background())
+
+	// Assert
+	require.NoError(t, err)
+	require.Len(t, holds, 2)
+	assert.Nil(t, holds[0].ExpiresAt)
+	assert.Equal(t, models.RiskActionEmailVerification, holds[1].HoldType)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestAccountHoldRepository_Delete(t *testing.T) {
+	t.Run("Success", func(t *testing.T) {
+		// Arrange
+		pool, mock, cleanup := setupDBMock(t)
+		defer cleanup()
+		repo := NewAccountHoldRepository(pool)
+
+		mock.ExpectExec("DELETE FROM account_holds WHERE user_id = \\$1").
+			WithArgs(int64(42)).
+			WillReturnResult(sqlmock.NewResult(0, 1))
+
+		// Act
+		err := repo.Delete(context.Background(), 42)
+
+		// Assert
+		assert.NoError(t, err)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("Not Found", func(t *testing.T) {
+		// Arrange
+		pool, mock, cleanup := setupDBMock(t)
+		defer cleanup()
+		repo := NewAccountHoldRepository(pool)
+
+		mock.ExpectExec("DELETE FROM account_holds").
+			WithArgs(int64(99)).
+			WillReturnResult(sqlmock.NewResult(0, 0))
+
+		// Act
+		err := repo.Delete(context.Background(), 99)
+
+		// Assert
+		assert.True(t, errors.Is(err, utils.ErrNotFound))
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+}
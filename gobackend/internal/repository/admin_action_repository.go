@@ -0,0 +1,370 @@
+// Package repository provides data access interfaces and implementations for the HideMe application.
+// It follows the repository pattern to abstract database operations and provide a clean API
+// for data persistence operations.
+//
+// This file implements the admin action repository, which stores the queue of
+// destructive admin actions that require a second administrator's approval.
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/yasinhessnawi1/Hideme_Backend/internal/constants"
+	"github.com/yasinhessnawi1/Hideme_Backend/internal/database"
+	"github.com/yasinhessnawi1/Hideme_Backend/internal/models"
+	"github.com/yasinhessnawi1/Hideme_Backend/internal/utils"
+)
+
+// AdminActionRepository defines methods for managing queued admin actions.
+type AdminActionRepository interface {
+	// Create stores a new admin action.
+	//
+	// Parameters:
+	//   - ctx: Context for transaction and cancellation control
+	//   - action: The action to store; its ID is populated on success
+	//
+	// Returns:
+	//   - An error if the action could not be stored
+	Create(ctx context.Context, action *models.AdminAction) error
+
+	// GetByID retrieves an admin action by its ID.
+	//
+	// Parameters:
+	//   - ctx: Context for transaction and cancellation control
+	//   - id: The ID of the action
+	//
+	// Returns:
+	//   - The action if found
+	//   - NotFoundError if the action doesn't exist
+	//   - Other errors for database issues
+	GetByID(ctx context.Context, id int64) (*models.AdminAction, error)
+
+	// List retrieves admin actions, newest first.
+	//
+	// Parameters:
+	//   - ctx: Context for transaction and cancellation control
+	//   - status: Only return actions in this status (empty for all)
+	//
+	// Returns:
+	//   - The matching actions (empty if there are none)
+	//   - An error for database issues
+	List(ctx context.Context, status models.AdminActionStatus) ([]*models.AdminAction, error)
+
+	// UpdateStatus moves an action to a new status, but only if it is still in the expected one.
+	// This makes concurrent reviews safe: only the first reviewer wins.
+	//
+	// Parameters:
+	//   - ctx: Context for transaction and cancellation control
+	//   - action: The action with its new status and review fields set
+	//   - from: The status the action must currently have
+	//
+	// Returns:
+	//   - A conflict error if the action is no longer in the expected status
+	//   - Other errors for database issues
+	UpdateStatus(ctx context.Context, action *models.AdminAction, from models.AdminActionStatus) error
+
+	// ExpirePending marks all pending actions past their expiry time as expired.
+	//
+	// Parameters:
+	//   - ctx: Context for transaction and cancellation control
+	//
+	// Returns:
+	//   - The number of actions that expired
+	//   - An error for database issues
+	ExpirePending(ctx context.Context) (int64, error)
+}
+
+// PostgresAdminActionRepository is a PostgreSQL implementation of AdminActionRepository.
+type PostgresAdminActionRepository struct {
+	db *database.Pool
+}
+
+// NewAdminActionRepository creates a new AdminActionRepository implementation for PostgreSQL.
+//
+// Parameters:
+//   - db: A connection pool for PostgreSQL database access
+//
+// Returns:
+//   - An implementation of the AdminActionRepository interface
+func NewAdminActionRepository(db *database.Pool) AdminActionRepository {
+	return &PostgresAdminActionRepository{
+		db: db,
+	}
+}
+
+// adminActionColumns is the column list shared by all admin action queries.
+const adminActionColumns = `action_id, action_type, target_id, reason, status, requested_by, reviewed_by,
+               COALESCE(review_note, ''), COALESCE(execution_error, ''), created_at, expires_at, reviewed_at`
+
+// scanAdminAction scans a single admin action row.
+//
+// Parameters:
+//   - scanner: The row or rows to scan from
+//
+// Returns:
+//   - The scanned action
+//   - An error if scanning fails
+func scanAdminAction(scanner interface{ Scan(dest ...any) error }) (*models.AdminAction, error) {
+	action := &models.AdminAction{}
+	err := scanner.Scan(
+		&action.ID,
+		&action.ActionType,
+		&action.TargetID,
+		&action.Reason,
+		&action.Status,
+		&action.RequestedBy,
+		&action.ReviewedBy,
+		&action.ReviewNote,
+		&action.ExecutionError,
+		&action.CreatedAt,
+		&action.ExpiresAt,
+		&action.ReviewedAt,
+	)
+	return action, err
+}
+
+// Create stores a new admin action.
+//
+// Parameters:
+//   - ctx: Context for transaction and cancellation control
+//   - action: The action to store; its ID is populated on success
+//
+// Returns:
+//   - An error if the action could not be stored
+func (r *PostgresAdminActionRepository) Create(ctx context.Context, action *models.AdminAction) error {
+	// Start query timer
+	startTime := time.Now()
+
+	// Define the query
+	query := `
+        INSERT INTO admin_actions (action_type, target_id, reason, status, requested_by, created_at, expires_at)
+        VALUES ($1, $2, $3, $4, $5, $6, $7)
+        RETURNING action_id
+    `
+
+	// Execute the query
+	err := r.db.QueryRowContext(
+		ctx,
+		query,
+		action.ActionType,
+		action.TargetID,
+		action.Reason,
+		action.Status,
+		action.RequestedBy,
+		action.CreatedAt,
+		action.ExpiresAt,
+	).Scan(&action.ID)
+
+	// Log the query execution
+	utils.LogDBQuery(
+		query,
+		[]interface{}{action.ActionType, action.TargetID, action.Status, action.RequestedBy},
+		time.Since(startTime),
+		err,
+	)
+
+	if err != nil {
+		return fmt.Errorf("failed to create admin action: %w", err)
+	}
+
+	return nil
+}
+
+// GetByID retrieves an admin action by its ID.
+//
+// Parameters:
+//   - ctx: Context for transaction and cancellation control
+//   - id: The ID of the action
+//
+// Returns:
+//   - The action if found
+//   - NotFoundError if the action doesn't exist
+//   - Other errors for database issues
+func (r *PostgresAdminActionRepository) GetByID(ctx context.Context, id int64) (*models.AdminAction, error) {
+	// Start query timer
+	startTime := time.Now()
+
+	// Define the query
+	query := `SELECT ` + adminActionColumns + ` FROM admin_actions WHERE action_id = $1`
+
+	// Execute the query
+	action, err := scanAdminAction(r.db.QueryRowContext(ctx, query, id))
+
+	// Log the query execution
+	utils.LogDBQuery(
+		query,
+		[]interface{}{id},
+		time.Since(startTime),
+		err,
+	)
+
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, utils.NewNotFoundError("AdminAction", id).WithCause(err)
+		}
+		return nil, fmt.Errorf("failed to get admin action: %w", err)
+	}
+
+	return action, nil
+}
+
+// List retrieves admin actions, newest first.
+//
+// Parameters:
+//   - ctx: Context for transaction and cancellation control
+//   - status: Only return actions in this status (empty for all)
+//
+// Returns:
+//   - The matching actions (empty if there are none)
+//   - An error for database issues
+func (r *PostgresAdminActionRepository) List(ctx context.Context, status models.AdminActionStatus) ([]*models.AdminAction, error) {
+	// Start query timer
+	startTime := time.Now()
+
+	// Define the query
+	query := `
+        SELECT ` + adminActionColumns + `
+        FROM admin_actions
+        WHERE ($1::text = '' OR status = $1)
+        ORDER BY created_at DESC, action_id DESC
+    `
+
+	// Execute the query
+	rows, err := r.db.QueryContext(ctx, query, string(status))
+
+	// Log the query execution
+	utils.LogDBQuery(
+		query,
+		[]interface{}{status},
+		time.Since(startTime),
+		err,
+	)
+
+	if err != nil {
+		return nil, fmt.Errorf("failed to list admin actions: %w", err)
+	}
+	defer rows.Close()
+
+	actions := []*models.AdminAction{}
+	for rows.Next() {
+		action, err := scanAdminAction(rows)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan admin action row: %w", err)
+		}
+		actions = append(actions, action)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating admin action rows: %w", err)
+	}
+
+	return actions, nil
+}
+
+// UpdateStatus moves an action to a new status, but only if it is still in the expected one.
+//
+// Parameters:
+//   - ctx: Context for transaction and cancellation control
+//   - action: The action with its new status and review fields set
+//   - from: The status the action must currently have
+//
+// Returns:
+//   - A conflict error if the action is no longer in the expected status
+//   - Other errors for database issues
+func (r *PostgresAdminActionRepository) UpdateStatus(ctx context.Context, action *models.AdminAction, from models.AdminActionStatus) error {
+	// Start query timer
+	startTime := time.Now()
+
+	// Define the query
+	query := `
+        UPDATE admin_actions
+        SET status = $1, reviewed_by = $2, review_note = $3, execution_error = $4, reviewed_at = $5
+        WHERE action_id = $6 AND status = $7
+    `
+
+	// Execute the query
+	result, err := r.db.ExecContext(
+		ctx,
+		query,
+		action.Status,
+		action.ReviewedBy,
+		action.ReviewNote,
+		action.ExecutionError,
+		action.ReviewedAt,
+		action.ID,
+		from,
+	)
+
+	// Log the query execution
+	utils.LogDBQuery(
+		query,
+		[]interface{}{action.Status, action.ReviewedBy, action.ID, from},
+		time.Since(startTime),
+		err,
+	)
+
+	if err != nil {
+		return fmt.Errorf("failed to update admin action: %w", err)
+	}
+
+	// Check if the action was still in the expected status
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("failed to get affected rows: %w", err)
+	}
+
+	if rowsAffected == 0 {
+		return utils.New(utils.ErrBadRequest, constants.StatusConflict,
+			fmt.Sprintf("Admin action %d is no longer %s", action.ID, from))
+	}
+
+	return nil
+}
+
+// ExpirePending marks all pending actions past their expiry time as expired.
+//
+// Parameters:
+//   - ctx: Context for transaction and cancellation control
+//
+// Returns:
+//   - The number of actions that expired
+//   - An error for database issues
+func (r *PostgresAdminActionRepository) ExpirePending(ctx context.Context) (int64, error) {
+	// Start query timer
+	startTime := time.Now()
+
+	// Define the query
+	query := `
+        UPDATE admin_actions
+        SET status = $1, reviewed_at = $2
+        WHERE status = $3 AND expires_at < $2
+    `
+
+	// Execute the query
+	now := time.Now()
+	result, err := r.db.ExecContext(ctx, query, models.ActionStatusExpired, now, models.ActionStatusPending)
+
+	// Log the query execution
+	utils.LogDBQuery(
+		query,
+		[]interface{}{models.ActionStatusExpired, now, models.ActionStatusPending},
+		time.Since(startTime),
+		err,
+	)
+
+	if err != nil {
+		return 0, fmt.Errorf("failed to expire admin actions: %w", err)
+	}
+
+	// Get the number of rows affected
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return 0, fmt.Errorf("failed to get affected rows: %w", err)
+	}
+
+	return rowsAffected, nil
+}
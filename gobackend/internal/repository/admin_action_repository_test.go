@@ -0,0 +1,227 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/yasinhessnawi1/Hideme_Backend/internal/models"
+	"github.com/yasinhessnawi1/Hideme_Backend/internal/utils"
+)
+
+var adminActionTestColumns = []string{
+	"action_id", "action_type", "target_id", "reason", "status", "requested_by", "reviewed_by",
+	"review_note", "execution_error", "created_at", "expires_at", "reviewed_at",
+}
+
+func TestNewAdminActionRepository(t *testing.T) {
+	// Arrange
+	pool, _, cleanup := setupDBMock(t)
+	defer cleanup()
+
+	// Act
+	repo := NewAdminActionRepository(pool)
+
+	// Assert
+	assert.NotNil(t, repo, "Repository should not be nil")
+	assert.Implements(t, (*AdminActionRepository)(nil), repo, "Should implement AdminActionRepository interface")
+}
+
+func TestAdminActionRepository_Create(t *testing.T) {
+	t.Run("Success", func(t *testing.T) {
+		// Arrange
+		pool, mock, cleanup := setupDBMock(t)
+		defer cleanup()
+		repo := NewAdminActionRepository(pool)
+		action := models.NewAdminAction(models.ActionUserErasure, 42, "Requested by legal", 1, time.Hour)
+
+		mock.ExpectQuery("INSERT INTO admin_actions").
+			WithArgs(models.ActionUserErasure, int64(42), "Requested by legal", models.ActionStatusPending, int64(1), sqlmock.AnyArg(), sqlmock.AnyArg()).
+			WillReturnRows(sqlmock.NewRows([]string{"action_id"}).AddRow(7))
+
+		// Act
+		err := repo.Create(context.Background(), action)
+
+		// Assert
+		assert.NoError(t, err)
+		assert.Equal(t, int64(7), action.ID)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("Database Error", func(t *testing.T) {
+		// Arrange
+		pool, mock, cleanup := setupDBMock(t)
+		defer cleanup()
+		repo := NewAdminActionRepository(pool)
+
+		mock.ExpectQuery("INSERT INTO admin_actions").
+			WillReturnError(errors.New("database error"))
+
+		// Act
+		err := repo.Create(context.Background(), models.NewAdminAction(models.ActionUserErasure, 42, "reason", 1, time.Hour))
+
+		// Assert
+		assert.Error(t, err)
+		assert.Contains(t, err.Error(), "failed to create admin action")
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+}
+
+func TestAdminActionRepository_GetByID(t *testing.T) {
+	now := time.Now()
+
+	t.Run("Success", func(t *testing.T) {
+		// Arrange
+		pool, mock, cleanup := setupDBMock(t)
+		defer cleanup()
+		repo := NewAdminActionRepository(pool)
+
+		rows := sqlmock.NewRows(adminActionTestColumns).
+			AddRow(7, "user_erasure", 42, "reason", "pending", 1, nil, "", "", now, now.Add(time.Hour), nil)
+		mock.ExpectQuery("SELECT (.+) FROM admin_actions WHERE action_id = \\$1").
+			WithArgs(int64(7)).
+			WillReturnRows(rows)
+
+		// Act
+		action, err := repo.GetByID(context.Background(), 7)
+
+		// Assert
+		require.NoError(t, err)
+		assert.Equal(t, int64(7), action.ID)
+		assert.Equal(t, models.ActionUserErasure, action.ActionType)
+		assert.Equal(t, models.ActionStatusPending, action.Status)
+		assert.Nil(t, action.ReviewedBy)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("Not Found", func(t *testing.T) {
+		// Arrange
+		pool, mock, cleanup := setupDBMock(t)
+		defer cleanup()
+		repo := NewAdminActionRepository(pool)
+
+		mock.ExpectQuery("SELECT (.+) FROM admin_actions").
+			WithArgs(int64(99)).
+			WillReturnRows(sqlmock.NewRows(adminActionTestColumns))
+
+		// Act
+		action, err := repo.GetByID(context.Background(), 99)
+
+		// Assert
+		assert.Nil(t, action)
+		assert.True(t, errors.Is(err, utils.ErrNotFound))
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+}
+
+func TestAdminActionRepository_List(t *testing.T) {
+	now := time.Now()
+
+	t.Run("Filtered by status", func(t *testing.T) {
+		// Arrange
+		pool, mock, cleanup := setupDBMock(t)
+		defer cleanup()
+		repo := NewAdminActionRepository(pool)
+
+		rows := sqlmock.NewRows(adminActionTestColumns).
+			AddRow(8, "tenant_deletion", 50, "reason", "pending", 2, nil, "", "", now, now.Add(time.Hour), nil).
+			AddRow(7, "user_erasure", 42, "reason", "pending", 1, nil, "", "", now, now.Add(time.Hour), nil)
+		mock.ExpectQuery("SELECT (.+) FROM admin_actions").
+			WithArgs("pending").
+			WillReturnRows(rows)
+
+		// Act
+		actions, err := repo.List(context.Background(), models.ActionStatusPending)
+
+		// Assert
+		require.NoError(t, err)
+		assert.Len(t, actions, 2)
+		assert.Equal(t, int64(8), actions[0].ID)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("Database Error", func(t *testing.T) {
+		// Arrange
+		pool, mock, cleanup := setupDBMock(t)
+		defer cleanup()
+		repo := NewAdminActionRepository(pool)
+
+		mock.ExpectQuery("SELECT (.+) FROM admin_actions").
+			WillReturnError(errors.New("database error"))
+
+		// Act
+		actions, err := repo.List(context.Background(), "")
+
+		// Assert
+		assert.Error(t, err)
+		assert.Nil(t, actions)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+}
+
+func TestAdminActionRepository_UpdateStatus(t *testing.T) {
+	reviewer := int64(2)
+	now := time.Now()
+	action := &models.AdminAction{ID: 7, Status: models.ActionStatusApproved, ReviewedBy: &reviewer, ReviewedAt: &now}
+
+	t.Run("Success", func(t *testing.T) {
+		// Arrange
+		pool, mock, cleanup := setupDBMock(t)
+		defer cleanup()
+		repo := NewAdminActionRepository(pool)
+
+		mock.ExpectExec("UPDATE admin_actions SET status").
+			WithArgs(models.ActionStatusApproved, &reviewer, "", "", &now, int64(7), models.ActionStatusPending).
+			WillReturnResult(sqlmock.NewResult(0, 1))
+
+		// Act
+		err := repo.UpdateStatus(context.Background(), action, models.ActionStatusPending)
+
+		// Assert
+		assert.NoError(t, err)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("No Longer In Expected Status", func(t *testing.T) {
+		// Arrange
+		pool, mock, cleanup := setupDBMock(t)
+		defer cleanup()
+		repo := NewAdminActionRepository(pool)
+
+		mock.ExpectExec("UPDATE admin_actions SET status").
+			WillReturnResult(sqlmock.NewResult(0, 0))
+
+		// Act
+		err := repo.UpdateStatus(context.Background(), action, models.ActionStatusPending)
+
+		// Assert
+		require.Error(t, err)
+		appErr := utils.ParseError(err)
+		assert.Equal(t, 409, appErr.StatusCode)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+}
+
+func TestAdminActionRepository_ExpirePending(t *testing.T) {
+	// Arrange
+	pool, mock, cleanup := setupDBMock(t)
+	defer cleanup()
+	repo := NewAdminActionRepository(pool)
+
+	mock.ExpectExec("UPDATE admin_actions SET status = \\$1, reviewed_at = \\$2 WHERE status = \\$3").
+		WithArgs(models.ActionStatusExpired, sqlmock.AnyArg(), models.ActionStatusPending).
+		WillReturnResult(sqlmock.NewResult(0, 3))
+
+	// Act
+	count, err := repo.ExpirePending(context.Background())
+
+	// Assert
+	assert.NoError(t, err)
+	assert.Equal(t, int64(3), count)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
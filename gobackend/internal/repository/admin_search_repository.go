@@ -0,0 +1,286 @@
+// Package repository provides data access interfaces and implementations for the HideMe application.
+// It follows the repository pattern to abstract database operations and provide a clean API
+// for data persistence operations.
+//
+// This file implements the administrator search, which looks up users, documents,
+// API keys and sessions across all tenants.
+package repository
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/yasinhessnawi1/Hideme_Backend/internal/constants"
+	"github.com/yasinhessnawi1/Hideme_Backend/internal/database"
+	"github.com/yasinhessnawi1/Hideme_Backend/internal/models"
+	"github.com/yasinhessnawi1/Hideme_Backend/internal/utils"
+)
+
+// AdminSearchRepository defines the lookups behind the administrator search.
+// Each method returns at most limit matches, ordered by ID.
+type AdminSearchRepository interface {
+	// SearchUsers finds users by ID, or by a username or email address containing the term.
+	//
+	// Parameters:
+	//   - ctx: Context for transaction and cancellation control
+	//   - term: The text to look for in usernames and email addresses, case-insensitively
+	//   - id: The user ID to match; nil if the term is not an ID
+	//   - limit: The maximum number of users to return
+	//
+	// Returns:
+	//   - The matching users without credentials (empty if there are none)
+	//   - An error for database issues
+	SearchUsers(ctx context.Context, term string, id *int64, limit int) ([]*models.User, error)
+
+	// SearchDocuments finds documents by ID or by their stored (encrypted) name.
+	//
+	// Parameters:
+	//   - ctx: Context for transaction and cancellation control
+	//   - term: The stored name to match exactly
+	//   - id: The document ID to match; nil if the term is not an ID
+	//   - limit: The maximum number of documents to return
+	//
+	// Returns:
+	//   - The matching documents (empty if there are none)
+	//   - An error for database issues
+	SearchDocuments(ctx context.Context, term string, id *int64, limit int) ([]*models.AdminSearchDocument, error)
+
+	// SearchAPIKeys finds API keys whose ID starts with the term.
+	//
+	// Parameters:
+	//   - ctx: Context for transaction and cancellation control
+	//   - prefix: The start of the key ID, case-insensitively
+	//   - limit: The maximum number of keys to return
+	//
+	// Returns:
+	//   - The matching keys without their secrets (empty if there are none)
+	//   - An error for database issues
+	SearchAPIKeys(ctx context.Context, prefix string, limit int) ([]*models.APIKey, error)
+
+	// SearchSessions finds sessions whose ID starts with the term.
+	//
+	// Parameters:
+	//   - ctx: Context for transaction and cancellation control
+	//   - prefix: The start of the session ID, case-insensitively
+	//   - limit: The maximum number of sessions to return
+	//
+	// Returns:
+	//   - The matching sessions (empty if there are none)
+	//   - An error for database issues
+	SearchSessions(ctx context.Context, prefix string, limit int) ([]*models.Session, error)
+}
+
+// PostgresAdminSearchRepository is a PostgreSQL implementation of AdminSearchRepository.
+type PostgresAdminSearchRepository struct {
+	db *database.Pool
+}
+
+// NewAdminSearchRepository creates a new AdminSearchRepository implementation for PostgreSQL.
+//
+// Parameters:
+//   - db: A connection pool for PostgreSQL database access
+//
+// Returns:
+//   - An implementation of the AdminSearchRepository interface
+func NewAdminSearchRepository(db *database.Pool) AdminSearchRepository {
+	return &PostgresAdminSearchRepository{
+		db: db,
+	}
+}
+
+// likeEscaper escapes the LIKE wildcards in search terms, so they are matched literally.
+var likeEscaper = strings.NewReplacer(`\`, `\\`, `%`, `\%`, `_`, `\_`)
+
+// SearchUsers finds users by ID, or by a username or email address containing the term.
+func (r *PostgresAdminSearchRepository) SearchUsers(ctx context.Context, term string, id *int64, limit int) ([]*models.User, error) {
+	// Start query timer
+	startTime := time.Now()
+
+	// Define the query
+	query := `
+        SELECT ` + constants.ColumnUserID + `, username, email, role, created_at, updated_at
+        FROM ` + constants.TableUsers + `
+        WHERE ` + constants.ColumnUserID + ` = $1
+           OR LOWER(username) LIKE $2 ESCAPE '\'
+           OR LOWER(email) LIKE $2 ESCAPE '\'
+        ORDER BY ` + constants.ColumnUserID + `
+        LIMIT $3
+    `
+	pattern := "%" + likeEscaper.Replace(strings.ToLower(term)) + "%"
+
+	// Execute the query
+	rows, err := r.db.QueryContext(ctx, query, id, pattern, limit)
+
+	// Log the query execution
+	utils.LogDBQuery(
+		query,
+		[]interface{}{id, pattern, limit},
+		time.Since(startTime),
+		err,
+	)
+
+	if err != nil {
+		return nil, fmt.Errorf("failed to search users: %w", err)
+	}
+	defer rows.Close()
+
+	users := []*models.User{}
+	for rows.Next() {
+		user := &models.User{}
+		if err := rows.Scan(&user.ID, &user.Username, &user.Email, &user.Role, &user.CreatedAt, &user.UpdatedAt); err != nil {
+			return nil, fmt.Errorf("failed to scan user row: %w", err)
+		}
+		users = append(users, user)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating user rows: %w", err)
+	}
+
+	return users, nil
+}
+
+// SearchDocuments finds documents by ID or by their stored (encrypted) name.
+func (r *PostgresAdminSearchRepository) SearchDocuments(ctx context.Context, term string, id *int64, limit int) ([]*models.AdminSearchDocument, error) {
+	// Start query timer
+	startTime := time.Now()
+
+	// Define the query
+	query := `
+        SELECT ` + constants.ColumnDocumentID + `, ` + constants.ColumnUserID + `, upload_timestamp, last_modified
+        FROM ` + constants.TableDocuments + `
+        WHERE ` + constants.ColumnDocumentID + ` = $1
+           OR hashed_document_name = $2
+        ORDER BY ` + constants.ColumnDocumentID + `
+        LIMIT $3
+    `
+
+	// Execute the query
+	rows, err := r.db.QueryContext(ctx, query, id, term, limit)
+
+	// Log the query execution
+	utils.LogDBQuery(
+		query,
+		[]interface{}{id, term, limit},
+		time.Since(startTime),
+		err,
+	)
+
+	if err != nil {
+		return nil, fmt.Errorf("failed to search documents: %w", err)
+	}
+	defer rows.Close()
+
+	documents := []*models.AdminSearchDocument{}
+	for rows.Next() {
+		doc := &models.AdminSearchDocument{}
+		if err := rows.Scan(&doc.ID, &doc.UserID, &doc.UploadTimestamp, &doc.LastModified); err != nil {
+			return nil, fmt.Errorf("failed to scan document row: %w", err)
+		}
+		documents = append(documents, doc)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating document rows: %w", err)
+	}
+
+	return documents, nil
+}
+
+// SearchAPIKeys finds API keys whose ID starts with the term.
+func (r *PostgresAdminSearchRepository) SearchAPIKeys(ctx context.Context, prefix string, limit int) ([]*models.APIKey, error) {
+	// Start query timer
+	startTime := time.Now()
+
+	// Define the query; the key hash is never selected
+	query := `
+        SELECT ` + constants.ColumnKeyID + `, ` + constants.ColumnUserID + `, ` + constants.ColumnName + `, ` + constants.ColumnExpiresAt + `, ` + constants.ColumnCreatedAt + `,
+               ` + constants.ColumnRotatedFrom + `, ` + constants.ColumnRotatedAt + `, ` + constants.ColumnUsedAfterRotationAt + `
+        FROM ` + constants.TableAPIKeys + `
+        WHERE LOWER(` + constants.ColumnKeyID + `) LIKE $1 ESCAPE '\'
+        ORDER BY ` + constants.ColumnKeyID + `
+        LIMIT $2
+    `
+	pattern := likeEscaper.Replace(strings.ToLower(prefix)) + "%"
+
+	// Execute the query
+	rows, err := r.db.QueryContext(ctx, query, pattern, limit)
+
+	// Log the query execution
+	utils.LogDBQuery(
+		query,
+		[]interface{}{pattern, limit},
+		time.Since(startTime),
+		err,
+	)
+
+	if err != nil {
+		return nil, fmt.Errorf("failed to search API keys: %w", err)
+	}
+	defer rows.Close()
+
+	keys := []*models.APIKey{}
+	for rows.Next() {
+		key := &models.APIKey{}
+		if err := rows.Scan(&key.ID, &key.UserID, &key.Name, &key.ExpiresAt, &key.CreatedAt,
+			&key.RotatedFrom, &key.RotatedAt, &key.UsedAfterRotationAt); err != nil {
+			return nil, fmt.Errorf("failed to scan API key row: %w", err)
+		}
+		keys = append(keys, key)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating API key rows: %w", err)
+	}
+
+	return keys, nil
+}
+
+// SearchSessions finds sessions whose ID starts with the term.
+func (r *PostgresAdminSearchRepository) SearchSessions(ctx context.Context, prefix string, limit int) ([]*models.Session, error) {
+	// Start query timer
+	startTime := time.Now()
+
+	// Define the query
+	query := `
+        SELECT ` + constants.ColumnSessionID + `, ` + constants.ColumnUserID + `, jwt_id, expires_at, created_at
+        FROM ` + constants.TableSessions + `
+        WHERE LOWER(` + constants.ColumnSessionID + `) LIKE $1 ESCAPE '\'
+        ORDER BY ` + constants.ColumnSessionID + `
+        LIMIT $2
+    `
+	pattern := likeEscaper.Replace(strings.ToLower(prefix)) + "%"
+
+	// Execute the query
+	rows, err := r.db.QueryContext(ctx, query, pattern, limit)
+
+	// Log the query execution
+	utils.LogDBQuery(
+		query,
+		[]interface{}{pattern, limit},
+		time.Since(startTime),
+		err,
+	)
+
+	if err != nil {
+		return nil, fmt.Errorf("failed to search sessions: %w", err)
+	}
+	defer rows.Close()
+
+	sessions := []*models.Session{}
+	for rows.Next() {
+		session := &models.Session{}
+		if err := rows.Scan(&session.ID, &session.UserID, &session.JWTID, &session.ExpiresAt, &session.CreatedAt); err != nil {
+			return nil, fmt.Errorf("failed to scan session row: %w", err)
+		}
+		sessions = append(sessions, session)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating session rows: %w", err)
+	}
+
+	return sessions, nil
+}
@@ -0,0 +1,157 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNewAdminSearchRepository(t *testing.T) {
+	// Arrange
+	pool, _, cleanup := setupDBMock(t)
+	defer cleanup()
+
+	// Act
+	repo := NewAdminSearchRepository(pool)
+
+	// Assert
+	assert.NotNil(t, repo, "Repository should not be nil")
+	assert.Implements(t, (*AdminSearchRepository)(nil), repo, "Should implement AdminSearchRepository interface")
+}
+
+func TestAdminSearchRepository_SearchUsers(t *testing.T) {
+	t.Run("Success", func(t *testing.T) {
+		// Arrange
+		pool, mock, cleanup := setupDBMock(t)
+		defer cleanup()
+		repo := NewAdminSearchRepository(pool)
+		now := time.Now()
+		id := int64(42)
+
+		mock.ExpectQuery("SELECT (.+) FROM users WHERE user_id = \\$1 OR LOWER\\(username\\) LIKE \\$2").
+			WithArgs(&id, "%42%", 20).
+			WillReturnRows(sqlmock.NewRows([]string{"user_id", "username", "email", "role", "created_at", "updated_at"}).
+				AddRow(42, "support", "support@example.com", "user", now, now))
+
+		// Act
+		users, err := repo.SearchUsers(context.Background(), "42", &id, 20)
+
+		// Assert
+		require.NoError(t, err)
+		require.Len(t, users, 1)
+		assert.Equal(t, "support@example.com", users[0].Email)
+		assert.Empty(t, users[0].PasswordHash)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("Escapes wildcards", func(t *testing.T) {
+		// Arrange
+		pool, mock, cleanup := setupDBMock(t)
+		defer cleanup()
+		repo := NewAdminSearchRepository(pool)
+
+		mock.ExpectQuery("SELECT (.+) FROM users").
+			WithArgs(nil, `%john\_doe\%%`, 20).
+			WillReturnRows(sqlmock.NewRows([]string{"user_id", "username", "email", "role", "created_at", "updated_at"}))
+
+		// Act
+		users, err := repo.SearchUsers(context.Background(), "John_Doe%", nil, 20)
+
+		// Assert
+		require.NoError(t, err)
+		assert.Empty(t, users)
+		assert.NotNil(t, users)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("Database error", func(t *testing.T) {
+		// Arrange
+		pool, mock, cleanup := setupDBMock(t)
+		defer cleanup()
+		repo := NewAdminSearchRepository(pool)
+
+		mock.ExpectQuery("SELECT (.+) FROM users").WillReturnError(errors.New("connection lost"))
+
+		// Act
+		users, err := repo.SearchUsers(context.Background(), "john", nil, 20)
+
+		// Assert
+		assert.Error(t, err)
+		assert.Nil(t, users)
+		assert.Contains(t, err.Error(), "failed to search users")
+	})
+}
+
+func TestAdminSearchRepository_SearchDocuments(t *testing.T) {
+	// Arrange
+	pool, mock, cleanup := setupDBMock(t)
+	defer cleanup()
+	repo := NewAdminSearchRepository(pool)
+	now := time.Now()
+
+	mock.ExpectQuery("SELECT (.+) FROM documents WHERE document_id = \\$1 OR hashed_document_name = \\$2").
+		WithArgs(nil, "a1b2c3", 20).
+		WillReturnRows(sqlmock.NewRows([]string{"document_id", "user_id", "upload_timestamp", "last_modified"}).
+			AddRow(7, 3, now, now))
+
+	// Act
+	docs, err := repo.SearchDocuments(context.Background(), "a1b2c3", nil, 20)
+
+	// Assert
+	require.NoError(t, err)
+	require.Len(t, docs, 1)
+	assert.Equal(t, int64(7), docs[0].ID)
+	assert.Equal(t, int64(3), docs[0].UserID)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestAdminSearchRepository_SearchAPIKeys(t *testing.T) {
+	// Arrange
+	pool, mock, cleanup := setupDBMock(t)
+	defer cleanup()
+	repo := NewAdminSearchRepository(pool)
+	now := time.Now()
+
+	mock.ExpectQuery("SELECT (.+) FROM api_keys WHERE LOWER\\(key_id\\) LIKE \\$1").
+		WithArgs("abc%", 20).
+		WillReturnRows(sqlmock.NewRows([]string{"key_id", "user_id", "name", "expires_at", "created_at",
+			"rotated_from", "rotated_at", "used_after_rotation_at"}).
+			AddRow("abc-123", 3, "CI", now.Add(time.Hour), now, nil, nil, nil))
+
+	// Act
+	keys, err := repo.SearchAPIKeys(context.Background(), "ABC", 20)
+
+	// Assert
+	require.NoError(t, err)
+	require.Len(t, keys, 1)
+	assert.Equal(t, "abc-123", keys[0].ID)
+	assert.Empty(t, keys[0].APIKeyHash)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestAdminSearchRepository_SearchSessions(t *testing.T) {
+	// Arrange
+	pool, mock, cleanup := setupDBMock(t)
+	defer cleanup()
+	repo := NewAdminSearchRepository(pool)
+	now := time.Now()
+
+	mock.ExpectQuery("SELECT (.+) FROM sessions WHERE LOWER\\(session_id\\) LIKE \\$1").
+		WithArgs("f00d%", 20).
+		WillReturnRows(sqlmock.NewRows([]string{"session_id", "user_id", "jwt_id", "expires_at", "created_at"}).
+			AddRow("f00d-1", 3, "jwt-1", now.Add(time.Hour), now))
+
+	// Act
+	sessions, err := repo.SearchSessions(context.Background(), "f00d", 20)
+
+	// Assert
+	require.NoError(t, err)
+	require.Len(t, sessions, 1)
+	assert.Equal(t, "jwt-1", sessions[0].JWTID)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
@@ -0,0 +1,501 @@
+// Package repository provides data access interfaces and implementations for the HideMe application.
+// It follows the repository pattern to abstract database operations and provide a clean API
+// for data persistence operations.
+//
+// This file implements the announcement repository, which stores the announcements
+// administrators publish to users and which users have read them.
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/yasinhessnawi1/Hideme_Backend/internal/database"
+	"github.com/yasinhessnawi1/Hideme_Backend/internal/models"
+	"github.com/yasinhessnawi1/Hideme_Backend/internal/utils"
+)
+
+// AnnouncementRepository defines methods for managing announcements and their read state.
+type AnnouncementRepository interface {
+	// Create stores a new announcement.
+	//
+	// Parameters:
+	//   - ctx: Context for transaction and cancellation control
+	//   - announcement: The announcement to store; its ID is set on success
+	//
+	// Returns:
+	//   - An error for database issues
+	Create(ctx context.Context, announcement *models.Announcement) error
+
+	// GetByID retrieves an announcement by its ID.
+	//
+	// Parameters:
+	//   - ctx: Context for transaction and cancellation control
+	//   - id: The ID of the announcement
+	//
+	// Returns:
+	//   - The announcement
+	//   - NotFoundError if the announcement doesn't exist
+	//   - Other errors for database issues
+	GetByID(ctx context.Context, id int64) (*models.Announcement, error)
+
+	// List retrieves the most recent announcements, including scheduled and expired ones.
+	//
+	// Parameters:
+	//   - ctx: Context for transaction and cancellation control
+	//   - limit: The maximum number of announcements to return
+	//
+	// Returns:
+	//   - The announcements, latest start first
+	//   - An error for database issues
+	List(ctx context.Context, limit int) ([]*models.Announcement, error)
+
+	// ListActiveForUser retrieves the announcements shown at a point in time, together
+	// with whether a user has read them.
+	//
+	// Parameters:
+	//   - ctx: Context for transaction and cancellation control
+	//   - userID: The ID of the user reading the announcements
+	//   - now: The point in time the announcements must be shown at
+	//   - unreadOnly: Whether to leave out the announcements the user has read
+	//
+	// Returns:
+	//   - The announcements, latest start first
+	//   - An error for database issues
+	ListActiveForUser(ctx context.Context, userID int64, now time.Time, unreadOnly bool) ([]*models.UserAnnouncement, error)
+
+	// Update stores the changes to an announcement.
+	//
+	// Parameters:
+	//   - ctx: Context for transaction and cancellation control
+	//   - announcement: The updated announcement
+	//
+	// Returns:
+	//   - NotFoundError if the announcement doesn't exist
+	//   - Other errors for database issues
+	Update(ctx context.Context, announcement *models.Announcement) error
+
+	// Delete removes an announcement and its read state.
+	//
+	// Parameters:
+	//   - ctx: Context for transaction and cancellation control
+	//   - id: The ID of the announcement
+	//
+	// Returns:
+	//   - NotFoundError if the announcement doesn't exist
+	//   - Other errors for database issues
+	Delete(ctx context.Context, id int64) error
+
+	// MarkRead records that a user has read an announcement. Marking an announcement
+	// that is already read keeps the original time.
+	//
+	// Parameters:
+	//   - ctx: Context for transaction and cancellation control
+	//   - id: The ID of the announcement
+	//   - userID: The ID of the user
+	//   - readAt: When the user read the announcement
+	//
+	// Returns:
+	//   - An error for database issues
+	MarkRead(ctx context.Context, id, userID int64, readAt time.Time) error
+}
+
+// PostgresAnnouncementRepository is a PostgreSQL implementation of AnnouncementRepository.
+type PostgresAnnouncementRepository struct {
+	db *database.Pool
+}
+
+// NewAnnouncementRepository creates a new AnnouncementRepository implementation for PostgreSQL.
+//
+// Parameters:
+//   - db: A connection pool for PostgreSQL database access
+//
+// Returns:
+//   - An implementation of the AnnouncementRepository interface
+func NewAnnouncementRepository(db *database.Pool) AnnouncementRepository {
+	return &PostgresAnnouncementRepository{
+		db: db,
+	}
+}
+
+// announcementColumns is the column list shared by all announcement queries.
+const announcementColumns = `a.announcement_id, a.title, a.message, a.category, a.starts_at, a.ends_at, a.created_by, a.created_at, a.updated_at`
+
+// scanAnnouncement scans the announcementColumns of a row, followed by any extra destinations.
+//
+// Parameters:
+//   - scanner: The row or rows to scan from
+//   - extra: Destinations for columns selected after announcementColumns
+//
+// Returns:
+//   - The scanned announcement
+//   - An error if scanning fails
+func scanAnnouncement(scanner interface{ Scan(dest ...any) error }, extra ...any) (*models.Announcement, error) {
+	announcement := &models.Announcement{}
+	var endsAt sql.NullTime
+	var createdBy sql.NullInt64
+	dest := append([]any{
+		&announcement.ID,
+		&announcement.Title,
+		&announcement.Message,
+		&announcement.Category,
+		&announcement.StartsAt,
+		&endsAt,
+		&createdBy,
+		&announcement.CreatedAt,
+		&announcement.UpdatedAt,
+	}, extra...)
+	if err := scanner.Scan(dest...); err != nil {
+		return nil, err
+	}
+	if endsAt.Valid {
+		announcement.EndsAt = &endsAt.Time
+	}
+	if createdBy.Valid {
+		announcement.CreatedBy = &createdBy.Int64
+	}
+	return announcement, nil
+}
+
+// Create stores a new announcement.
+//
+// Parameters:
+//   - ctx: Context for transaction and cancellation control
+//   - announcement: The announcement to store; its ID is set on success
+//
+// Returns:
+//   - An error for database issues
+func (r *PostgresAnnouncementRepository) Create(ctx context.Context, announcement *models.Announcement) error {
+	// Start query timer
+	startTime := time.Now()
+
+	// Define the query
+	query := `
+        INSERT INTO announcements (title, message, category, starts_at, ends_at, created_by, created_at, updated_at)
+        VALUES ($1, $2, $3, $4, $5, $6, $7, $8)
+        RETURNING announcement_id
+    `
+
+	// Execute the query
+	err := r.db.QueryRowContext(
+		ctx,
+		query,
+		announcement.Title,
+		announcement.Message,
+		announcement.Category,
+		announcement.StartsAt,
+		announcement.EndsAt,
+		announcement.CreatedBy,
+		announcement.CreatedAt,
+		announcement.UpdatedAt,
+	).Scan(&announcement.ID)
+
+	// Log the query execution
+	utils.LogDBQuery(
+		query,
+		[]interface{}{announcement.Category, announcement.StartsAt},
+		time.Since(startTime),
+		err,
+	)
+
+	if err != nil {
+		return fmt.Errorf("failed to create announcement: %w", err)
+	}
+
+	return nil
+}
+
+// GetByID retrieves an announcement by its ID.
+//
+// Parameters:
+//   - ctx: Context for transaction and cancellation control
+//   - id: The ID of the announcement
+//
+// Returns:
+//   - The announcement
+//   - NotFoundError if the announcement doesn't exist
+//   - Other errors for database issues
+func (r *PostgresAnnouncementRepository) GetByID(ctx context.Context, id int64) (*models.Announcement, error) {
+	// Start query timer
+	startTime := time.Now()
+
+	// Define the query
+	query := `SELECT ` + announcementColumns + ` FROM announcements a WHERE a.announcement_id = $1`
+
+	// Execute the query
+	announcement, err := scanAnnouncement(r.db.QueryRowContext(ctx, query, id))
+
+	// Log the query execution
+	utils.LogDBQuery(
+		query,
+		[]interface{}{id},
+		time.Since(startTime),
+		err,
+	)
+
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, utils.NewNotFoundError("Announcement", id).WithCause(err)
+		}
+		return nil, fmt.Errorf("failed to get announcement: %w", err)
+	}
+
+	return announcement, nil
+}
+
+// List retrieves the most recent announcements, including scheduled and expired ones.
+//
+// Parameters:
+//   - ctx: Context for transaction and cancellation control
+//   - limit: The maximum number of announcements to return
+//
+// Returns:
+//   - The announcements, latest start first
+//   - An error for database issues
+func (r *PostgresAnnouncementRepository) List(ctx context.Context, limit int) ([]*models.Announcement, error) {
+	// Start query timer
+	startTime := time.Now()
+
+	// Define the query
+	query := `SELECT ` + announcementColumns + ` FROM announcements a ORDER BY a.starts_at DESC, a.announcement_id DESC LIMIT $1`
+
+	// Execute the query
+	rows, err := r.db.QueryContext(ctx, query, limit)
+
+	// Log the query execution
+	utils.LogDBQuery(
+		query,
+		[]interface{}{limit},
+		time.Since(startTime),
+		err,
+	)
+
+	if err != nil {
+		return nil, fmt.Errorf("failed to list announcements: %w", err)
+	}
+	defer rows.Close()
+
+	announcements := []*models.Announcement{}
+	for rows.Next() {
+		announcement, err := scanAnnouncement(rows)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan announcement row: %w", err)
+		}
+		announcements = append(announcements, announcement)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating announcement rows: %w", err)
+	}
+
+	return announcements, nil
+}
+
+// ListActiveForUser retrieves the announcements shown at a point in time, together
+// with whether a user has read them.
+//
+// Parameters:
+//   - ctx: Context for transaction and cancellation control
+//   - userID: The ID of the user reading the announcements
+//   - now: The point in time the announcements must be shown at
+//   - unreadOnly: Whether to leave out the announcements the user has read
+//
+// Returns:
+//   - The announcements, latest start first
+//   - An error for database issues
+func (r *PostgresAnnouncementRepository) ListActiveForUser(ctx context.Context, userID int64, now time.Time, unreadOnly bool) ([]*models.UserAnnouncement, error) {
+	// Start query timer
+	startTime := time.Now()
+
+	// Define the query
+	query := `
+        SELECT ` + announcementColumns + `, ar.read_at
+        FROM announcements a
+        LEFT JOIN announcement_reads ar ON ar.announcement_id = a.announcement_id AND ar.user_id = $1
+        WHERE a.starts_at <= $2 AND (a.ends_at IS NULL OR a.ends_at > $2)
+          AND (NOT $3 OR ar.read_at IS NULL)
+        ORDER BY a.starts_at DESC, a.announcement_id DESC
+    `
+
+	// Execute the query
+	rows, err := r.db.QueryContext(ctx, query, userID, now, unreadOnly)
+
+	// Log the query execution
+	utils.LogDBQuery(
+		query,
+		[]interface{}{userID, now, unreadOnly},
+		time.Since(startTime),
+		err,
+	)
+
+	if err != nil {
+		return nil, fmt.Errorf("failed to list announcements for user: %w", err)
+	}
+	defer rows.Close()
+
+	announcements := []*models.UserAnnouncement{}
+	for rows.Next() {
+		var readAt sql.NullTime
+		announcement, err := scanAnnouncement(rows, &readAt)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan announcement row: %w", err)
+		}
+		userAnnouncement := &models.UserAnnouncement{Announcement: *announcement}
+		if readAt.Valid {
+			userAnnouncement.Read = true
+			userAnnouncement.ReadAt = &readAt.Time
+		}
+		announcements = append(announcements, userAnnouncement)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating announcement rows: %w", err)
+	}
+
+	return announcements, nil
+}
+
+// Update stores the changes to an announcement.
+//
+// Parameters:
+//   - ctx: Context for transaction and cancellation control
+//   - announcement: The updated announcement
+//
+// Returns:
+//   - NotFoundError if the announcement doesn't exist
+//   - Other errors for database issues
+func (r *PostgresAnnouncementRepository) Update(ctx context.Context, announcement *models.Announcement) error {
+	// Start query timer
+	startTime := time.Now()
+
+	// Define the query
+	query := `
+        UPDATE announcements
+        SET title = $1, message = $2, category = $3, starts_at = $4, ends_at = $5, updated_at = $6
+        WHERE announcement_id = $7
+    `
+
+	// Execute the query
+	result, err := r.db.ExecContext(
+		ctx,
+		query,
+		announcement.Title,
+		announcement.Message,
+		announcement.Category,
+		announcement.StartsAt,
+		announcement.EndsAt,
+		announcement.UpdatedAt,
+		announcement.ID,
+	)
+
+	// Log the query execution
+	utils.LogDBQuery(
+		query,
+		[]interface{}{announcement.Category, announcement.ID},
+		time.Since(startTime),
+		err,
+	)
+
+	if err != nil {
+		return fmt.Errorf("failed to update announcement: %w", err)
+	}
+
+	// Check if an announcement was updated
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("failed to get affected rows: %w", err)
+	}
+
+	if rowsAffected == 0 {
+		return utils.NewNotFoundError("Announcement", announcement.ID)
+	}
+
+	return nil
+}
+
+// Delete removes an announcement and its read state.
+//
+// Parameters:
+//   - ctx: Context for transaction and cancellation control
+//   - id: The ID of the announcement
+//
+// Returns:
+//   - NotFoundError if the announcement doesn't exist
+//   - Other errors for database issues
+func (r *PostgresAnnouncementRepository) Delete(ctx context.Context, id int64) error {
+	// Start query timer
+	startTime := time.Now()
+
+	// Define the query; read state is removed by the foreign key cascade
+	query := `DELETE FROM announcements WHERE announcement_id = $1`
+
+	// Execute the query
+	result, err := r.db.ExecContext(ctx, query, id)
+
+	// Log the query execution
+	utils.LogDBQuery(
+		query,
+		[]interface{}{id},
+		time.Since(startTime),
+		err,
+	)
+
+	if err != nil {
+		return fmt.Errorf("failed to delete announcement: %w", err)
+	}
+
+	// Check if an announcement was deleted
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("failed to get affected rows: %w", err)
+	}
+
+	if rowsAffected == 0 {
+		return utils.NewNotFoundError("Announcement", id)
+	}
+
+	return nil
+}
+
+// MarkRead records that a user has read an announcement. Marking an announcement
+// that is already read keeps the original time.
+//
+// Parameters:
+//   - ctx: Context for transaction and cancellation control
+//   - id: The ID of the announcement
+//   - userID: The ID of the user
+//   - readAt: When the user read the announcement
+//
+// Returns:
+//   - An error for database issues
+func (r *PostgresAnnouncementRepository) MarkRead(ctx context.Context, id, userID int64, readAt time.Time) error {
+	// Start query timer
+	startTime := time.Now()
+
+	// Define the query
+	query := `
+        INSERT INTO announcement_reads (announcement_id, user_id, read_at)
+        VALUES ($1, $2, $3)
+        ON CONFLICT (announcement_id, user_id) DO NOTHING
+    `
+
+	// Execute the query
+	_, err := r.db.ExecContext(ctx, query, id, userID, readAt)
+
+	// Log the query execution
+	utils.LogDBQuery(
+		query,
+		[]interface{}{id, userID},
+		time.Since(startTime),
+		err,
+	)
+
+	if err != nil {
+		return fmt.Errorf("failed to mark announcement as read: %w", err)
+	}
+
+	return nil
+}
@@ -0,0 +1,266 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/yasinhessnawi1/Hideme_Backend/internal/models"
+	"github.com/yasinhessnawi1/Hideme_Backend/internal/utils"
+)
+
+var announcementTestColumns = []string{
+	"announcement_id", "title", "message", "category", "starts_at", "ends_at", "created_by", "created_at", "updated_at",
+}
+
+func TestNewAnnouncementRepository(t *testing.T) {
+	// Arrange
+	pool, _, cleanup := setupDBMock(t)
+	defer cleanup()
+
+	// Act
+	repo := NewAnnouncementRepository(pool)
+
+	// Assert
+	assert.NotNil(t, repo, "Repository should not be nil")
+	assert.Implements(t, (*AnnouncementRepository)(nil), repo, "Should implement AnnouncementRepository interface")
+}
+
+func TestAnnouncementRepository_Create(t *testing.T) {
+	// Arrange
+	pool, mock, cleanup := setupDBMock(t)
+	defer cleanup()
+	repo := NewAnnouncementRepository(pool)
+	announcement := models.NewAnnouncement(1, &models.AnnouncementCreate{
+		Title:    "Maintenance",
+		Message:  "Tonight from 22:00",
+		Category: models.AnnouncementMaintenance,
+	})
+
+	mock.ExpectQuery("INSERT INTO announcements (.+) RETURNING announcement_id").
+		WithArgs("Maintenance", "Tonight from 22:00", models.AnnouncementMaintenance,
+			sqlmock.AnyArg(), sqlmock.AnyArg(), sqlmock.AnyArg(), sqlmock.AnyArg(), sqlmock.AnyArg()).
+		WillReturnRows(sqlmock.NewRows([]string{"announcement_id"}).AddRow(4))
+
+	// Act
+	err := repo.Create(context.Background(), announcement)
+
+	// Assert
+	assert.NoError(t, err)
+	assert.Equal(t, int64(4), announcement.ID)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestAnnouncementRepository_GetByID(t *testing.T) {
+	t.Run("Success", func(t *testing.T) {
+		// Arrange
+		pool, mock, cleanup := setupDBMock(t)
+		defer cleanup()
+		repo := NewAnnouncementRepository(pool)
+		now := time.Now()
+
+		mock.ExpectQuery("SELECT (.+) FROM announcements a WHERE a.announcement_id = \\$1").
+			WithArgs(int64(4)).
+			WillReturnRows(sqlmock.NewRows(announcementTestColumns).
+				AddRow(4, "Maintenance", "Tonight", "maintenance", now, now.Add(time.Hour), nil, now, now))
+
+		// Act
+		announcement, err := repo.GetByID(context.Background(), 4)
+
+		// Assert
+		require.NoError(t, err)
+		assert.Equal(t, models.AnnouncementMaintenance, announcement.Category)
+		assert.NotNil(t, announcement.EndsAt)
+		assert.Nil(t, announcement.CreatedBy)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("Not Found", func(t *testing.T) {
+		// Arrange
+		pool, mock, cleanup := setupDBMock(t)
+		defer cleanup()
+		repo := NewAnnouncementRepository(pool)
+
+		mock.ExpectQuery("SELECT (.+) FROM announcements a WHERE a.announcement_id = \\$1").
+			WithArgs(int64(9)).
+			WillReturnRows(sqlmock.NewRows(announcementTestColumns))
+
+		// Act
+		announcement, err := repo.GetByID(context.Background(), 9)
+
+		// Assert
+		assert.Nil(t, announcement)
+		assert.True(t, utils.IsNotFoundError(err))
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+}
+
+func TestAnnouncementRepository_List(t *testing.T) {
+	// Arrange
+	pool, mock, cleanup := setupDBMock(t)
+	defer cleanup()
+	repo := NewAnnouncementRepository(pool)
+	now := time.Now()
+
+	mock.ExpectQuery("SELECT (.+) FROM announcements a ORDER BY a.starts_at DESC, a.announcement_id DESC LIMIT \\$1").
+		WithArgs(100).
+		WillReturnRows(sqlmock.NewRows(announcementTestColumns).
+			AddRow(5, "New export", "Images", "feature", now, nil, 1, now, now).
+			AddRow(4, "Maintenance", "Tonight", "maintenance", now.Add(-time.Hour), now, 1, now, now))
+
+	// Act
+	announcements, err := repo.List(context.Background(), 100)
+
+	// Assert
+	require.NoError(t, err)
+	require.Len(t, announcements, 2)
+	assert.Equal(t, int64(5), announcements[0].ID)
+	assert.Nil(t, announcements[0].EndsAt)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestAnnouncementRepository_ListActiveForUser(t *testing.T) {
+	// Arrange
+	pool, mock, cleanup := setupDBMock(t)
+	defer cleanup()
+	repo := NewAnnouncementRepository(pool)
+	now := time.Now()
+
+	mock.ExpectQuery("SELECT (.+), ar.read_at FROM announcements a LEFT JOIN announcement_reads ar (.+) WHERE a.starts_at <= \\$2").
+		WithArgs(int64(2), now, true).
+		WillReturnRows(sqlmock.NewRows(append(announcementTestColumns, "read_at")).
+			AddRow(5, "New export", "Images", "feature", now, nil, 1, now, now, nil).
+			AddRow(4, "Maintenance", "Tonight", "maintenance", now, nil, 1, now, now, now))
+
+	// Act
+	announcements, err := repo.ListActiveForUser(context.Background(), 2, now, true)
+
+	// Assert
+	require.NoError(t, err)
+	require.Len(t, announcements, 2)
+	assert.False(t, announcements[0].Read)
+	assert.Nil(t, announcements[0].ReadAt)
+	assert.True(t, announcements[1].Read)
+	assert.NotNil(t, announcements[1].ReadAt)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestAnnouncementRepository_Update(t *testing.T) {
+	t.Run("Success", func(t *testing.T) {
+		// Arrange
+		pool, mock, cleanup := setupDBMock(t)
+		defer cleanup()
+		repo := NewAnnouncementRepository(pool)
+		announcement := &models.Announcement{ID: 4, Title: "Maintenance", Message: "Postponed", Category: models.AnnouncementMaintenance}
+
+		mock.ExpectExec("UPDATE announcements SET (.+) WHERE announcement_id = \\$7").
+			WithArgs("Maintenance", "Postponed", models.AnnouncementMaintenance,
+				sqlmock.AnyArg(), sqlmock.AnyArg(), sqlmock.AnyArg(), int64(4)).
+			WillReturnResult(sqlmock.NewResult(0, 1))
+
+		// Act
+		err := repo.Update(context.Background(), announcement)
+
+		// Assert
+		assert.NoError(t, err)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("Not Found", func(t *testing.T) {
+		// Arrange
+		pool, mock, cleanup := setupDBMock(t)
+		defer cleanup()
+		repo := NewAnnouncementRepository(pool)
+
+		mock.ExpectExec("UPDATE announcements SET (.+) WHERE announcement_id = \\$7").
+			WillReturnResult(sqlmock.NewResult(0, 0))
+
+		// Act
+		err := repo.Update(context.Background(), &models.Announcement{ID: 9})
+
+		// Assert
+		assert.True(t, utils.IsNotFoundError(err))
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+}
+
+func TestAnnouncementRepository_Delete(t *testing.T) {
+	t.Run("Success", func(t *testing.T) {
+		// Arrange
+		pool, mock, cleanup := setupDBMock(t)
+		defer cleanup()
+		repo := NewAnnouncementRepository(pool)
+
+		mock.ExpectExec("DELETE FROM announcements WHERE announcement_id = \\$1").
+			WithArgs(int64(4)).
+			WillReturnResult(sqlmock.NewResult(0, 1))
+
+		// Act
+		err := repo.Delete(context.Background(), 4)
+
+		// Assert
+		assert.NoError(t, err)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("Not Found", func(t *testing.T) {
+		// Arrange
+		pool, mock, cleanup := setupDBMock(t)
+		defer cleanup()
+		repo := NewAnnouncementRepository(pool)
+
+		mock.ExpectExec("DELETE FROM announcements WHERE announcement_id = \\$1").
+			WithArgs(int64(9)).
+			WillReturnResult(sqlmock.NewResult(0, 0))
+
+		// Act
+		err := repo.Delete(context.Background(), 9)
+
+		// Assert
+		assert.True(t, utils.IsNotFoundError(err))
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+}
+
+func TestAnnouncementRepository_MarkRead(t *testing.T) {
+	t.Run("Success", func(t *testing.T) {
+		// Arrange
+		pool, mock, cleanup := setupDBMock(t)
+		defer cleanup()
+		repo := NewAnnouncementRepository(pool)
+		now := time.Now()
+
+		mock.ExpectExec("INSERT INTO announcement_reads (.+) ON CONFLICT \\(announcement_id, user_id\\) DO NOTHING").
+			WithArgs(int64(4), int64(2), now).
+			WillReturnResult(sqlmock.NewResult(0, 1))
+
+		// Act
+		err := repo.MarkRead(context.Background(), 4, 2, now)
+
+		// Assert
+		assert.NoError(t, err)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("Database Error", func(t *testing.T) {
+		// Arrange
+		pool, mock, cleanup := setupDBMock(t)
+		defer cleanup()
+		repo := NewAnnouncementRepository(pool)
+
+		mock.ExpectExec("INSERT INTO announcement_reads").
+			WillReturnError(errors.New("connection reset"))
+
+		// Act
+		err := repo.MarkRead(context.Background(), 4, 2, time.Now())
+
+		// Assert
+		assert.Error(t, err)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+}
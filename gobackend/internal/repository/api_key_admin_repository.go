@@ -0,0 +1,449 @@
+// Package repository provides data access interfaces and implementations for the HideMe application.
+// It follows the repository pattern to abstract database operations and provide a clean API
+// for data persistence operations.
+//
+// This file implements the administration of all users' API keys: listing them with their
+// owners, disabling them, the expiry policy and forced rotation campaigns.
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/lib/pq"
+	"github.com/rs/zerolog/log"
+
+	"github.com/yasinhessnawi1/Hideme_Backend/internal/constants"
+	"github.com/yasinhessnawi1/Hideme_Backend/internal/database"
+	"github.com/yasinhessnawi1/Hideme_Backend/internal/models"
+	"github.com/yasinhessnawi1/Hideme_Backend/internal/utils"
+)
+
+// APIKeyAdminRepository defines methods for administering the API keys of all users.
+type APIKeyAdminRepository interface {
+	// List retrieves API keys with their owners, newest first.
+	//
+	// Parameters:
+	//   - ctx: Context for transaction and cancellation control
+	//   - userID: Only return the keys of this user; 0 for all users
+	//
+	// Returns:
+	//   - The keys without their hashes (empty if there are none)
+	//   - An error for database issues
+	List(ctx context.Context, userID int64) ([]*models.AdminAPIKey, error)
+
+	// Disable stops an API key from authenticating requests.
+	//
+	// Parameters:
+	//   - ctx: Context for transaction and cancellation control
+	//   - keyID: The unique identifier of the key
+	//   - disabledAt: The time the key is disabled
+	//
+	// Returns:
+	//   - A conflict error if the key doesn't exist or is already disabled
+	//   - Other errors for database issues
+	Disable(ctx context.Context, keyID string, disabledAt time.Time) error
+
+	// GetPolicy retrieves the API key expiry policy.
+	//
+	// Parameters:
+	//   - ctx: Context for transaction and cancellation control
+	//
+	// Returns:
+	//   - The policy; a policy without limits if none was ever set
+	//   - An error for database issues
+	GetPolicy(ctx context.Context) (*models.APIKeyPolicy, error)
+
+	// SetPolicy stores the API key expiry policy and brings the expiry of existing keys
+	// forward to its maximum lifetime, in a single transaction.
+	//
+	// Parameters:
+	//   - ctx: Context for transaction and cancellation control
+	//   - policy: The policy, with UpdatedBy and UpdatedAt set
+	//
+	// Returns:
+	//   - The number of keys whose expiry was brought forward
+	//   - An error for database issues
+	SetPolicy(ctx context.Context, policy *models.APIKeyPolicy) (int64, error)
+
+	// CreateCampaign stores a rotation campaign and adds the matching keys to it, in a single
+	// transaction. Only keys that are usable and not yet rotated are added, and keys already
+	// in a campaign with an earlier deadline keep it.
+	//
+	// Parameters:
+	//   - ctx: Context for transaction and cancellation control
+	//   - campaign: The campaign to store; its ID is populated on success
+	//   - userID: Only add the keys of this user; 0 for all users
+	//   - createdBefore: Only add keys created before this time; nil for all keys
+	//
+	// Returns:
+	//   - The keys added to the campaign with their owners
+	//   - An error for database issues
+	CreateCampaign(ctx context.Context, campaign *models.RotationCampaign, userID int64, createdBefore *time.Time) ([]*models.AdminAPIKey, error)
+
+	// ListCampaigns retrieves the rotation campaigns with their progress, newest first.
+	//
+	// Parameters:
+	//   - ctx: Context for transaction and cancellation control
+	//
+	// Returns:
+	//   - The campaigns (empty if there are none)
+	//   - An error for database issues
+	ListCampaigns(ctx context.Context) ([]*models.RotationCampaign, error)
+}
+
+// PostgresAPIKeyAdminRepository is a PostgreSQL implementation of APIKeyAdminRepository.
+type PostgresAPIKeyAdminRepository struct {
+	db *database.Pool
+}
+
+// NewAPIKeyAdminRepository creates a new APIKeyAdminRepository implementation for PostgreSQL.
+//
+// Parameters:
+//   - db: A connection pool for PostgreSQL database access
+//
+// Returns:
+//   - An implementation of the APIKeyAdminRepository interface
+func NewAPIKeyAdminRepository(db *database.Pool) APIKeyAdminRepository {
+	return &PostgresAPIKeyAdminRepository{
+		db: db,
+	}
+}
+
+// List retrieves API keys with their owners, newest first.
+func (r *PostgresAPIKeyAdminRepository) List(ctx context.Context, userID int64) ([]*models.AdminAPIKey, error) {
+	// Start query timer
+	startTime := time.Now()
+
+	// Define the query
+	query := `
+        SELECT k.key_id, k.user_id, k.name, k.expires_at, k.created_at,
+               k.rotated_from, k.rotated_at, k.used_after_rotation_at,
+               k.disabled_at, k.rotation_campaign_id, k.rotation_deadline, k.scopes,
+               u.username, u.email
+        FROM ` + constants.TableAPIKeys + ` k
+        JOIN ` + constants.TableUsers + ` u ON u.user_id = k.user_id
+        WHERE ($1::bigint = 0 OR k.user_id = $1)
+        ORDER BY k.created_at DESC, k.key_id
+    `
+
+	// Execute the query
+	rows, err := r.db.QueryContext(ctx, query, userID)
+
+	// Log the query execution
+	utils.LogDBQuery(
+		query,
+		[]interface{}{userID},
+		time.Since(startTime),
+		err,
+	)
+
+	if err != nil {
+		return nil, fmt.Errorf("failed to list API keys: %w", err)
+	}
+	defer func() {
+		if closeErr := rows.Close(); closeErr != nil {
+			log.Error().Err(closeErr).Msg("failed to close rows")
+		}
+	}()
+
+	keys := []*models.AdminAPIKey{}
+	for rows.Next() {
+		key := &models.AdminAPIKey{}
+		err := rows.Scan(
+			&key.ID,
+			&key.UserID,
+			&key.Name,
+			&key.ExpiresAt,
+			&key.CreatedAt,
+			&key.RotatedFrom,
+			&key.RotatedAt,
+			&key.UsedAfterRotationAt,
+			&key.DisabledAt,
+			&key.RotationCampaignID,
+			&key.RotationDeadline,
+			pq.Array(&key.Scopes),
+			&key.Username,
+			&key.Email,
+		)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan API key row: %w", err)
+		}
+		keys = append(keys, key)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating API key rows: %w", err)
+	}
+
+	return keys, nil
+}
+
+// Disable stops an API key from authenticating requests.
+func (r *PostgresAPIKeyAdminRepository) Disable(ctx context.Context, keyID string, disabledAt time.Time) error {
+	// Start query timer
+	startTime := time.Now()
+
+	// Define the query
+	query := `
+        UPDATE ` + constants.TableAPIKeys + `
+        SET ` + constants.ColumnDisabledAt + ` = $2
+        WHERE ` + constants.ColumnKeyID + ` = $1 AND ` + constants.ColumnDisabledAt + ` IS NULL
+    `
+
+	// Execute the query
+	result, err := r.db.ExecContext(ctx, query, keyID, disabledAt)
+
+	// Log the query execution
+	utils.LogDBQuery(
+		query,
+		[]interface{}{keyID, disabledAt},
+		time.Since(startTime),
+		err,
+	)
+
+	if err != nil {
+		return fmt.Errorf("failed to disable API key: %w", err)
+	}
+
+	// Check if the key was still enabled
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("failed to get affected rows: %w", err)
+	}
+
+	if rowsAffected == 0 {
+		return utils.New(utils.ErrBadRequest, constants.StatusConflict,
+			fmt.Sprintf("API key %s is already disabled", keyID))
+	}
+
+	return nil
+}
+
+// GetPolicy retrieves the API key expiry policy.
+func (r *PostgresAPIKeyAdminRepository) GetPolicy(ctx context.Context) (*models.APIKeyPolicy, error) {
+	// Start query timer
+	startTime := time.Now()
+
+	// Define the query
+	query := `
+        SELECT max_lifetime_days, updated_by, updated_at
+        FROM ` + constants.TableAPIKeyPolicy + `
+        WHERE policy_id = 1
+    `
+
+	// Execute the query
+	policy := &models.APIKeyPolicy{}
+	err := r.db.QueryRowContext(ctx, query).Scan(&policy.MaxLifetimeDays, &policy.UpdatedBy, &policy.UpdatedAt)
+
+	// Log the query execution
+	utils.LogDBQuery(
+		query,
+		nil,
+		time.Since(startTime),
+		err,
+	)
+
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return &models.APIKeyPolicy{}, nil
+		}
+		return nil, fmt.Errorf("failed to get API key policy: %w", err)
+	}
+
+	return policy, nil
+}
+
+// SetPolicy stores the API key expiry policy and brings the expiry of existing keys
+// forward to its maximum lifetime.
+func (r *PostgresAPIKeyAdminRepository) SetPolicy(ctx context.Context, policy *models.APIKeyPolicy) (int64, error) {
+	// Start query timer
+	startTime := time.Now()
+
+	var shortened int64
+
+	// Execute within a transaction
+	err := r.db.Transaction(ctx, func(tx *sql.Tx) error {
+		// Define the query
+		query := `
+            INSERT INTO ` + constants.TableAPIKeyPolicy + ` (policy_id, max_lifetime_days, updated_by, updated_at)
+            VALUES (1, $1, $2, $3)
+            ON CONFLICT (policy_id) DO UPDATE
+            SET max_lifetime_days = EXCLUDED.max_lifetime_days,
+                updated_by = EXCLUDED.updated_by,
+                updated_at = EXCLUDED.updated_at
+        `
+
+		if _, err := tx.ExecContext(ctx, query, policy.MaxLifetimeDays, policy.UpdatedBy, policy.UpdatedAt); err != nil {
+			return fmt.Errorf("failed to set API key policy: %w", err)
+		}
+
+		if policy.MaxLifetimeDays > 0 {
+			// Bring the expiry of keys issued for longer forward to the new limit
+			capQuery := `
+                UPDATE ` + constants.TableAPIKeys + `
+                SET ` + constants.ColumnExpiresAt + ` = ` + constants.ColumnCreatedAt + ` + make_interval(days => $1)
+                WHERE ` + constants.ColumnExpiresAt + ` > ` + constants.ColumnCreatedAt + ` + make_interval(days => $1)
+            `
+
+			result, err := tx.ExecContext(ctx, capQuery, policy.MaxLifetimeDays)
+			if err != nil {
+				return fmt.Errorf("failed to apply API key policy: %w", err)
+			}
+			if shortened, err = result.RowsAffected(); err != nil {
+				return fmt.Errorf("failed to get affected rows: %w", err)
+			}
+		}
+
+		// Log the operation
+		utils.LogDBQuery(
+			fmt.Sprintf("Set API key policy, shortening %d keys", shortened),
+			[]interface{}{policy.MaxLifetimeDays, policy.UpdatedBy},
+			time.Since(startTime),
+			nil,
+		)
+
+		return nil
+	})
+	if err != nil {
+		return 0, err
+	}
+
+	return shortened, nil
+}
+
+// CreateCampaign stores a rotation campaign and adds the matching keys to it.
+func (r *PostgresAPIKeyAdminRepository) CreateCampaign(ctx context.Context, campaign *models.RotationCampaign, userID int64, createdBefore *time.Time) ([]*models.AdminAPIKey, error) {
+	// Start query timer
+	startTime := time.Now()
+
+	keys := []*models.AdminAPIKey{}
+
+	// Execute within a transaction
+	err := r.db.Transaction(ctx, func(tx *sql.Tx) error {
+		// Define the query
+		query := `
+            INSERT INTO ` + constants.TableAPIKeyRotationCampaigns + ` (name, deadline, created_by, created_at)
+            VALUES ($1, $2, $3, $4)
+            RETURNING campaign_id
+        `
+
+		err := tx.QueryRowContext(ctx, query, campaign.Name, campaign.Deadline, campaign.CreatedBy, campaign.CreatedAt).Scan(&campaign.ID)
+		if err != nil {
+			return fmt.Errorf("failed to create rotation campaign: %w", err)
+		}
+
+		// Add the usable keys that are not rotated yet and not due earlier in another campaign
+		keyQuery := `
+            UPDATE ` + constants.TableAPIKeys + ` k
+            SET rotation_campaign_id = $1, rotation_deadline = $2
+            FROM ` + constants.TableUsers + ` u
+            WHERE u.user_id = k.user_id
+              AND k.disabled_at IS NULL
+              AND k.rotated_at IS NULL
+              AND k.expires_at > $3
+              AND (k.rotation_deadline IS NULL OR k.rotation_deadline > $2)
+              AND ($4::bigint = 0 OR k.user_id = $4)
+              AND ($5::timestamp IS NULL OR k.created_at < $5)
+            RETURNING k.key_id, k.user_id, k.name, k.expires_at, k.created_at, u.username, u.email
+        `
+
+		rows, err := tx.QueryContext(ctx, keyQuery, campaign.ID, campaign.Deadline, campaign.CreatedAt, userID, createdBefore)
+		if err != nil {
+			return fmt.Errorf("failed to add API keys to rotation campaign: %w", err)
+		}
+		defer rows.Close()
+
+		for rows.Next() {
+			key := &models.AdminAPIKey{}
+			if err := rows.Scan(&key.ID, &key.UserID, &key.Name, &key.ExpiresAt, &key.CreatedAt, &key.Username, &key.Email); err != nil {
+				return fmt.Errorf("failed to scan API key row: %w", err)
+			}
+			key.RotationCampaignID = &campaign.ID
+			key.RotationDeadline = &campaign.Deadline
+			keys = append(keys, key)
+		}
+		if err := rows.Err(); err != nil {
+			return fmt.Errorf("error iterating API key rows: %w", err)
+		}
+
+		// Log the operation
+		utils.LogDBQuery(
+			fmt.Sprintf("Created rotation campaign with %d keys", len(keys)),
+			[]interface{}{campaign.ID, campaign.Deadline, userID},
+			time.Since(startTime),
+			nil,
+		)
+
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	campaign.KeyCount = len(keys)
+	return keys, nil
+}
+
+// ListCampaigns retrieves the rotation campaigns with their progress, newest first.
+func (r *PostgresAPIKeyAdminRepository) ListCampaigns(ctx context.Context) ([]*models.RotationCampaign, error) {
+	// Start query timer
+	startTime := time.Now()
+
+	// Define the query
+	query := `
+        SELECT c.campaign_id, c.name, c.deadline, c.created_by, c.created_at,
+               COUNT(k.key_id), COUNT(k.rotated_at)
+        FROM ` + constants.TableAPIKeyRotationCampaigns + ` c
+        LEFT JOIN ` + constants.TableAPIKeys + ` k ON k.rotation_campaign_id = c.campaign_id
+        GROUP BY c.campaign_id
+        ORDER BY c.created_at DESC, c.campaign_id DESC
+    `
+
+	// Execute the query
+	rows, err := r.db.QueryContext(ctx, query)
+
+	// Log the query execution
+	utils.LogDBQuery(
+		query,
+		nil,
+		time.Since(startTime),
+		err,
+	)
+
+	if err != nil {
+		return nil, fmt.Errorf("failed to list rotation campaigns: %w", err)
+	}
+	defer func() {
+		if closeErr := rows.Close(); closeErr != nil {
+			log.Error().Err(closeErr).Msg("failed to close rows")
+		}
+	}()
+
+	campaigns := []*models.RotationCampaign{}
+	for rows.Next() {
+		campaign := &models.RotationCampaign{}
+		err := rows.Scan(
+			&campaign.ID,
+			&campaign.Name,
+			&campaign.Deadline,
+			&campaign.CreatedBy,
+			&campaign.CreatedAt,
+			&campaign.KeyCount,
+			&campaign.RotatedCount,
+		)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan rotation campaign row: %w", err)
+		}
+		campaigns = append(campaigns, campaign)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating rotation campaign rows: %w", err)
+	}
+
+	return campaigns, nil
+}
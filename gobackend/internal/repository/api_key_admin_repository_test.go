@@ -0,0 +1,220 @@
+package repository_test
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/yasinhessnawi1/Hideme_Backend/internal/database"
+	"github.com/yasinhessnawi1/Hideme_Backend/internal/models"
+	"github.com/yasinhessnawi1/Hideme_Backend/internal/repository"
+)
+
+func TestAPIKeyAdminRepository_List(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	require.NoError(t, err)
+	defer db.Close()
+	repo := repository.NewAPIKeyAdminRepository(&database.Pool{DB: db})
+
+	now := time.Now()
+	columns := []string{"key_id", "user_id", "name", "expires_at", "created_at", "rotated_from", "rotated_at",
+		"used_after_rotation_at", "disabled_at", "rotation_campaign_id", "rotation_deadline", "scopes", "username", "email"}
+
+	t.Run("Success", func(t *testing.T) {
+		mock.ExpectQuery("SELECT k.key_id, k.user_id, k.name(.+)FROM api_keys k(.+)JOIN users u").
+			WithArgs(int64(0)).
+			WillReturnRows(sqlmock.NewRows(columns).
+				AddRow("key-1", int64(1), "CI", now.Add(time.Hour), now, nil, nil, nil, now, nil, nil, "{admin}", "alice", "alice@example.com"))
+
+		keys, err := repo.List(context.Background(), 0)
+
+		require.NoError(t, err)
+		require.Len(t, keys, 1)
+		assert.Equal(t, "key-1", keys[0].ID)
+		assert.Equal(t, "alice", keys[0].Username)
+		assert.True(t, keys[0].IsDisabled())
+		assert.Empty(t, keys[0].APIKeyHash)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("Error", func(t *testing.T) {
+		mock.ExpectQuery("SELECT k.key_id").WithArgs(int64(2)).WillReturnError(errors.New("database error"))
+
+		_, err := repo.List(context.Background(), 2)
+
+		assert.Error(t, err)
+		assert.Contains(t, err.Error(), "failed to list API keys")
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+}
+
+func TestAPIKeyAdminRepository_Disable(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	require.NoError(t, err)
+	defer db.Close()
+	repo := repository.NewAPIKeyAdminRepository(&database.Pool{DB: db})
+	now := time.Now()
+
+	t.Run("Success", func(t *testing.T) {
+		mock.ExpectExec("UPDATE api_keys SET disabled_at = \\$2 WHERE key_id = \\$1 AND disabled_at IS NULL").
+			WithArgs("key-1", now).
+			WillReturnResult(sqlmock.NewResult(0, 1))
+
+		assert.NoError(t, repo.Disable(context.Background(), "key-1", now))
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("Already disabled", func(t *testing.T) {
+		mock.ExpectExec("UPDATE api_keys SET disabled_at").
+			WithArgs("key-1", now).
+			WillReturnResult(sqlmock.NewResult(0, 0))
+
+		err := repo.Disable(context.Background(), "key-1", now)
+
+		assert.Error(t, err)
+		assert.Contains(t, err.Error(), "already disabled")
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+}
+
+func TestAPIKeyAdminRepository_GetPolicy(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	require.NoError(t, err)
+	defer db.Close()
+	repo := repository.NewAPIKeyAdminRepository(&database.Pool{DB: db})
+
+	t.Run("Success", func(t *testing.T) {
+		mock.ExpectQuery("SELECT max_lifetime_days, updated_by, updated_at FROM api_key_policy").
+			WillReturnRows(sqlmock.NewRows([]string{"max_lifetime_days", "updated_by", "updated_at"}).AddRow(90, int64(1), time.Now()))
+
+		policy, err := repo.GetPolicy(context.Background())
+
+		require.NoError(t, err)
+		assert.Equal(t, 90, policy.MaxLifetimeDays)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("Never set", func(t *testing.T) {
+		mock.ExpectQuery("SELECT max_lifetime_days").WillReturnError(sql.ErrNoRows)
+
+		policy, err := repo.GetPolicy(context.Background())
+
+		require.NoError(t, err)
+		assert.Equal(t, 0, policy.MaxLifetimeDays)
+		assert.Nil(t, policy.UpdatedAt)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+}
+
+func TestAPIKeyAdminRepository_SetPolicy(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	require.NoError(t, err)
+	defer db.Close()
+	repo := repository.NewAPIKeyAdminRepository(&database.Pool{DB: db})
+
+	adminID := int64(1)
+	now := time.Now()
+
+	t.Run("Limit", func(t *testing.T) {
+		mock.ExpectBegin()
+		mock.ExpectExec("INSERT INTO api_key_policy").
+			WithArgs(90, &adminID, &now).
+			WillReturnResult(sqlmock.NewResult(0, 1))
+		mock.ExpectExec("UPDATE api_keys SET expires_at = created_at \\+ make_interval\\(days => \\$1\\)").
+			WithArgs(90).
+			WillReturnResult(sqlmock.NewResult(0, 3))
+		mock.ExpectCommit()
+
+		shortened, err := repo.SetPolicy(context.Background(), &models.APIKeyPolicy{MaxLifetimeDays: 90, UpdatedBy: &adminID, UpdatedAt: &now})
+
+		require.NoError(t, err)
+		assert.Equal(t, int64(3), shortened)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("No limit", func(t *testing.T) {
+		mock.ExpectBegin()
+		mock.ExpectExec("INSERT INTO api_key_policy").
+			WithArgs(0, &adminID, &now).
+			WillReturnResult(sqlmock.NewResult(0, 1))
+		mock.ExpectCommit()
+
+		shortened, err := repo.SetPolicy(context.Background(), &models.APIKeyPolicy{UpdatedBy: &adminID, UpdatedAt: &now})
+
+		require.NoError(t, err)
+		assert.Equal(t, int64(0), shortened)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+}
+
+func TestAPIKeyAdminRepository_CreateCampaign(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	require.NoError(t, err)
+	defer db.Close()
+	repo := repository.NewAPIKeyAdminRepository(&database.Pool{DB: db})
+
+	now := time.Now()
+	deadline := now.Add(7 * 24 * time.Hour)
+	campaign := &models.RotationCampaign{Name: "Leaked CI logs", Deadline: deadline, CreatedBy: 1, CreatedAt: now}
+
+	t.Run("Success", func(t *testing.T) {
+		mock.ExpectBegin()
+		mock.ExpectQuery("INSERT INTO api_key_rotation_campaigns").
+			WithArgs("Leaked CI logs", deadline, int64(1), now).
+			WillReturnRows(sqlmock.NewRows([]string{"campaign_id"}).AddRow(5))
+		mock.ExpectQuery("UPDATE api_keys k SET rotation_campaign_id = \\$1, rotation_deadline = \\$2").
+			WithArgs(int64(5), deadline, now, int64(0), nil).
+			WillReturnRows(sqlmock.NewRows([]string{"key_id", "user_id", "name", "expires_at", "created_at", "username", "email"}).
+				AddRow("key-1", int64(2), "CI", now.Add(time.Hour), now, "bob", "bob@example.com").
+				AddRow("key-2", int64(3), "Sync", now.Add(time.Hour), now, "carol", "carol@example.com"))
+		mock.ExpectCommit()
+
+		keys, err := repo.CreateCampaign(context.Background(), campaign, 0, nil)
+
+		require.NoError(t, err)
+		assert.Equal(t, int64(5), campaign.ID)
+		assert.Equal(t, 2, campaign.KeyCount)
+		require.Len(t, keys, 2)
+		assert.Equal(t, "bob", keys[0].Username)
+		assert.Equal(t, deadline, *keys[1].RotationDeadline)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("Error", func(t *testing.T) {
+		mock.ExpectBegin()
+		mock.ExpectQuery("INSERT INTO api_key_rotation_campaigns").WillReturnError(errors.New("database error"))
+		mock.ExpectRollback()
+
+		_, err := repo.CreateCampaign(context.Background(), campaign, 0, nil)
+
+		assert.Error(t, err)
+		assert.Contains(t, err.Error(), "failed to create rotation campaign")
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+}
+
+func TestAPIKeyAdminRepository_ListCampaigns(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	require.NoError(t, err)
+	defer db.Close()
+	repo := repository.NewAPIKeyAdminRepository(&database.Pool{DB: db})
+	now := time.Now()
+
+	mock.ExpectQuery("SELECT c.campaign_id(.+)FROM api_key_rotation_campaigns c(.+)LEFT JOIN api_keys k").
+		WillReturnRows(sqlmock.NewRows([]string{"campaign_id", "name", "deadline", "created_by", "created_at", "count", "count"}).
+			AddRow(int64(5), "Leaked CI logs", now.Add(time.Hour), int64(1), now, 4, 1))
+
+	campaigns, err := repo.ListCampaigns(context.Background())
+
+	require.NoError(t, err)
+	require.Len(t, campaigns, 1)
+	assert.Equal(t, 4, campaigns[0].KeyCount)
+	assert.Equal(t, 1, campaigns[0].RotatedCount)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
@@ -99,17 +99,55 @@ type APIKeyRepository interface {
 	//   - nil if deletion succeeds or there were no keys to delete
 	DeleteByUserID(ctx context.Context, userID int64) error
 
-	// DeleteExpired removes all expired API keys from the database.
+	// DeleteExpired removes expired API keys from the database.
 	//
 	// Parameters:
 	//   - ctx: Context for transaction and cancellation control
+	//   - limit: The maximum number of keys to delete; 0 for no limit
 	//
 	// Returns:
 	//   - The number of expired keys deleted
 	//   - An error if deletion fails
-	DeleteExpired(ctx context.Context) (int64, error)
+	DeleteExpired(ctx context.Context, limit int) (int64, error)
+
+	// CountExpired counts the expired API keys without deleting them.
+	//
+	// Parameters:
+	//   - ctx: Context for transaction and cancellation control
+	//
+	// Returns:
+	//   - The number of expired keys
+	//   - An error if counting fails
+	CountExpired(ctx context.Context) (int64, error)
 
 	GetAll(ctx context.Context) ([]*models.APIKey, error)
+
+	// Rotate stores the replacement of an API key and shortens the lifetime of the old key
+	// to the overlap window, in a single transaction.
+	//
+	// Parameters:
+	//   - ctx: Context for transaction and cancellation control
+	//   - oldKeyID: The unique identifier of the key being replaced
+	//   - rotatedAt: The time of the rotation
+	//   - oldExpiresAt: The new expiry of the old key
+	//   - replacement: The new key, with RotatedFrom set to the old key
+	//
+	// Returns:
+	//   - BadRequestError if the old key doesn't exist or was already rotated
+	//   - Other errors for database issues
+	Rotate(ctx context.Context, oldKeyID string, rotatedAt, oldExpiresAt time.Time, replacement *models.APIKey) error
+
+	// MarkUsedAfterRotation records the first use of a rotated API key.
+	//
+	// Parameters:
+	//   - ctx: Context for transaction and cancellation control
+	//   - keyID: The unique identifier of the rotated key
+	//   - usedAt: The time of the use
+	//
+	// Returns:
+	//   - true if this was the first use after rotation, false if it was recorded before
+	//   - An error if the update fails
+	MarkUsedAfterRotation(ctx context.Context, keyID string, usedAt time.Time) (bool, error)
 }
 
 // PostgresAPIKeyRepository is a PostgreSQL implementation of APIKeyRepository.
@@ -147,8 +185,8 @@ func (r *PostgresAPIKeyRepository) Create(ctx context.Context, apiKey *models.AP
 
 	// Define the query
 	query := `
-		INSERT INTO api_keys (key_id, user_id, api_key_hash, name, expires_at, created_at)
-		VALUES ($1, $2, $3, $4, $5, $6)
+		INSERT INTO api_keys (key_id, user_id, api_key_hash, name, expires_at, created_at, scopes)
+		VALUES ($1, $2, $3, $4, $5, $6, $7)
 	`
 
 	// Execute the query
@@ -161,22 +199,24 @@ func (r *PostgresAPIKeyRepository) Create(ctx context.Context, apiKey *models.AP
 		apiKey.Name,
 		apiKey.ExpiresAt,
 		apiKey.CreatedAt,
+		pq.Array(apiKey.Scopes),
 	)
 
 	// Log the query execution with sensitive data redacted
 	utils.LogDBQuery(
 		query,
-		[]interface{}{apiKey.ID, apiKey.UserID, constants.LogRedactedValue, apiKey.Name, apiKey.ExpiresAt, apiKey.CreatedAt},
+		[]interface{}{apiKey.ID, apiKey.UserID, constants.LogRedactedValue, apiKey.Name, apiKey.ExpiresAt, apiKey.CreatedAt, apiKey.Scopes},
 		time.Since(startTime),
 		err,
 	)
 
 	if err != nil {
 		// Handle PostgreSQL specific errors
-		if pqErr, ok := err.(*pq.Error); ok {
+		var pqErr *pq.Error
+		if errors.As(err, &pqErr) {
 			// Check for duplicate key error
 			if pqErr.Code == constants.PGErrorDuplicateConstraint {
-				return utils.NewDuplicateError("APIKey", "id", apiKey.ID)
+				return utils.NewDuplicateError("APIKey", "id", apiKey.ID).WithCause(err)
 			}
 		}
 		return fmt.Errorf("failed to create API key: %w", err)
@@ -209,7 +249,9 @@ func (r *PostgresAPIKeyRepository) GetByID(ctx context.Context, id string) (*mod
 
 	// Define the query
 	query := `
-		SELECT ` + constants.ColumnKeyID + `, ` + constants.ColumnUserID + `, ` + constants.ColumnAPIKeyHash + `, ` + constants.ColumnName + `, ` + constants.ColumnExpiresAt + `, ` + constants.ColumnCreatedAt + `
+		SELECT ` + constants.ColumnKeyID + `, ` + constants.ColumnUserID + `, ` + constants.ColumnAPIKeyHash + `, ` + constants.ColumnName + `, ` + constants.ColumnExpiresAt + `, ` + constants.ColumnCreatedAt + `,
+		       ` + constants.ColumnRotatedFrom + `, ` + constants.ColumnRotatedAt + `, ` + constants.ColumnUsedAfterRotationAt + `,
+		       ` + constants.ColumnDisabledAt + `, ` + constants.ColumnRotationCampaignID + `, ` + constants.ColumnRotationDeadline + `, ` + constants.ColumnScopes + `
 		FROM ` + constants.TableAPIKeys + `
 		WHERE ` + constants.ColumnKeyID + ` = $1
 	`
@@ -223,6 +265,13 @@ func (r *PostgresAPIKeyRepository) GetByID(ctx context.Context, id string) (*mod
 		&apiKey.Name,
 		&apiKey.ExpiresAt,
 		&apiKey.CreatedAt,
+		&apiKey.RotatedFrom,
+		&apiKey.RotatedAt,
+		&apiKey.UsedAfterRotationAt,
+		&apiKey.DisabledAt,
+		&apiKey.RotationCampaignID,
+		&apiKey.RotationDeadline,
+		pq.Array(&apiKey.Scopes),
 	)
 
 	// Log the query execution
@@ -235,7 +284,7 @@ func (r *PostgresAPIKeyRepository) GetByID(ctx context.Context, id string) (*mod
 
 	if err != nil {
 		if errors.Is(err, sql.ErrNoRows) {
-			return nil, utils.NewNotFoundError("APIKey", id)
+			return nil, utils.NewNotFoundError("APIKey", id).WithCause(err)
 		}
 		return nil, fmt.Errorf("failed to get API key by ID: %w", err)
 	}
@@ -259,7 +308,9 @@ func (r *PostgresAPIKeyRepository) GetByUserID(ctx context.Context, userID int64
 
 	// Define the query
 	query := `
-		SELECT ` + constants.ColumnKeyID + `, ` + constants.ColumnUserID + `, ` + constants.ColumnAPIKeyHash + `, ` + constants.ColumnName + `, ` + constants.ColumnExpiresAt + `, ` + constants.ColumnCreatedAt + `
+		SELECT ` + constants.ColumnKeyID + `, ` + constants.ColumnUserID + `, ` + constants.ColumnAPIKeyHash + `, ` + constants.ColumnName + `, ` + constants.ColumnExpiresAt + `, ` + constants.ColumnCreatedAt + `,
+		       ` + constants.ColumnRotatedFrom + `, ` + constants.ColumnRotatedAt + `, ` + constants.ColumnUsedAfterRotationAt + `,
+		       ` + constants.ColumnDisabledAt + `, ` + constants.ColumnRotationCampaignID + `, ` + constants.ColumnRotationDeadline + `, ` + constants.ColumnScopes + `
 		FROM ` + constants.TableAPIKeys + `
 		WHERE ` + constants.ColumnUserID + ` = $1
 		ORDER BY ` + constants.ColumnCreatedAt + ` DESC
@@ -296,6 +347,13 @@ func (r *PostgresAPIKeyRepository) GetByUserID(ctx context.Context, userID int64
 			&apiKey.Name,
 			&apiKey.ExpiresAt,
 			&apiKey.CreatedAt,
+			&apiKey.RotatedFrom,
+			&apiKey.RotatedAt,
+			&apiKey.UsedAfterRotationAt,
+			&apiKey.DisabledAt,
+			&apiKey.RotationCampaignID,
+			&apiKey.RotationDeadline,
+			pq.Array(&apiKey.Scopes),
 		)
 		if err != nil {
 			return nil, fmt.Errorf("failed to scan API key row: %w", err)
@@ -468,30 +526,40 @@ func (r *PostgresAPIKeyRepository) DeleteByUserID(ctx context.Context, userID in
 	return nil
 }
 
-// DeleteExpired removes all expired API keys.
+// DeleteExpired removes expired API keys, at most limit of them unless limit is 0.
 // This is typically used by a scheduled cleanup process.
 //
 // Parameters:
 //   - ctx: Context for transaction and cancellation control
+//   - limit: The maximum number of keys to delete; 0 for no limit
 //
 // Returns:
 //   - The number of expired keys deleted
 //   - An error if deletion fails
-func (r *PostgresAPIKeyRepository) DeleteExpired(ctx context.Context) (int64, error) {
+func (r *PostgresAPIKeyRepository) DeleteExpired(ctx context.Context, limit int) (int64, error) {
 	// Start query timer
 	startTime := time.Now()
 
 	// Define the query
+	now := time.Now()
 	query := `DELETE FROM ` + constants.TableAPIKeys + ` WHERE ` + constants.ColumnExpiresAt + ` < $1`
+	args := []interface{}{now}
+	if limit > 0 {
+		query = `
+            DELETE FROM ` + constants.TableAPIKeys + ` WHERE ` + constants.ColumnKeyID + ` IN (
+                SELECT ` + constants.ColumnKeyID + ` FROM ` + constants.TableAPIKeys + ` WHERE ` + constants.ColumnExpiresAt + ` < $1 LIMIT $2
+            )
+        `
+		args = append(args, limit)
+	}
 
 	// Execute the query
-	now := time.Now()
-	result, err := r.db.ExecContext(ctx, query, now)
+	result, err := r.db.ExecContext(ctx, query, args...)
 
 	// Log the query execution
 	utils.LogDBQuery(
 		query,
-		[]interface{}{now},
+		args,
 		time.Since(startTime),
 		err,
 	)
@@ -513,6 +581,41 @@ func (r *PostgresAPIKeyRepository) DeleteExpired(ctx context.Context) (int64, er
 	return count, nil
 }
 
+// CountExpired counts the expired API keys without deleting them.
+//
+// Parameters:
+//   - ctx: Context for transaction and cancellation control
+//
+// Returns:
+//   - The number of expired keys
+//   - An error if counting fails
+func (r *PostgresAPIKeyRepository) CountExpired(ctx context.Context) (int64, error) {
+	// Start query timer
+	startTime := time.Now()
+
+	// Define the query
+	query := `SELECT COUNT(*) FROM ` + constants.TableAPIKeys + ` WHERE ` + constants.ColumnExpiresAt + ` < $1`
+
+	// Execute the query
+	now := time.Now()
+	var count int64
+	err := r.db.QueryRowContext(ctx, query, now).Scan(&count)
+
+	// Log the query execution
+	utils.LogDBQuery(
+		query,
+		[]interface{}{now},
+		time.Since(startTime),
+		err,
+	)
+
+	if err != nil {
+		return 0, fmt.Errorf("failed to count expired API keys: %w", err)
+	}
+
+	return count, nil
+}
+
 func (r *PostgresAPIKeyRepository) GetByHash(ctx context.Context, hash string) (*models.APIKey, error) {
 	// Start query timer
 	startTime := time.Now()
@@ -558,7 +661,9 @@ func (r *PostgresAPIKeyRepository) GetAll(ctx context.Context) ([]*models.APIKey
 	startTime := time.Now()
 
 	query := `
-		SELECT key_id, user_id, api_key_hash, name, expires_at, created_at
+		SELECT key_id, user_id, api_key_hash, name, expires_at, created_at,
+		       rotated_from, rotated_at, used_after_rotation_at,
+		       disabled_at, rotation_campaign_id, rotation_deadline, scopes
 		FROM api_keys
 	`
 
@@ -590,6 +695,13 @@ func (r *PostgresAPIKeyRepository) GetAll(ctx context.Context) ([]*models.APIKey
 			&apiKey.Name,
 			&apiKey.ExpiresAt,
 			&apiKey.CreatedAt,
+			&apiKey.RotatedFrom,
+			&apiKey.RotatedAt,
+			&apiKey.UsedAfterRotationAt,
+			&apiKey.DisabledAt,
+			&apiKey.RotationCampaignID,
+			&apiKey.RotationDeadline,
+			pq.Array(&apiKey.Scopes),
 		)
 		if err != nil {
 			return nil, fmt.Errorf("failed to scan API key row: %w", err)
@@ -603,3 +715,137 @@ func (r *PostgresAPIKeyRepository) GetAll(ctx context.Context) ([]*models.APIKey
 
 	return apiKeys, nil
 }
+
+// Rotate stores the replacement of an API key and shortens the lifetime of the old key
+// to the overlap window. Both changes are made in a single transaction, and the old key
+// is only updated if it has not been rotated before, so concurrent rotations of the same
+// key cannot issue two replacements.
+//
+// Parameters:
+//   - ctx: Context for transaction and cancellation control
+//   - oldKeyID: The unique identifier of the key being replaced
+//   - rotatedAt: The time of the rotation
+//   - oldExpiresAt: The new expiry of the old key
+//   - replacement: The new key, with RotatedFrom set to the old key
+//
+// Returns:
+//   - BadRequestError if the old key doesn't exist or was already rotated
+//   - Other errors for database issues
+func (r *PostgresAPIKeyRepository) Rotate(ctx context.Context, oldKeyID string, rotatedAt, oldExpiresAt time.Time, replacement *models.APIKey) error {
+	// Start query timer
+	startTime := time.Now()
+
+	return r.db.Transaction(ctx, func(tx *sql.Tx) error {
+		// Shorten the old key to the overlap window unless it was already rotated
+		updateQuery := `
+			UPDATE ` + constants.TableAPIKeys + `
+			SET ` + constants.ColumnRotatedAt + ` = $2, ` + constants.ColumnExpiresAt + ` = $3
+			WHERE ` + constants.ColumnKeyID + ` = $1 AND ` + constants.ColumnRotatedAt + ` IS NULL
+		`
+		result, err := tx.ExecContext(ctx, updateQuery, oldKeyID, rotatedAt, oldExpiresAt)
+
+		// Log the query execution
+		utils.LogDBQuery(
+			updateQuery,
+			[]interface{}{oldKeyID, rotatedAt, oldExpiresAt},
+			time.Since(startTime),
+			err,
+		)
+
+		if err != nil {
+			return fmt.Errorf("failed to mark API key as rotated: %w", err)
+		}
+
+		rowsAffected, err := result.RowsAffected()
+		if err != nil {
+			return fmt.Errorf("failed to get rows affected: %w", err)
+		}
+		if rowsAffected == 0 {
+			return utils.NewBadRequestError(constants.MsgAPIKeyAlreadyRotated)
+		}
+
+		// Store the replacement with a link to the key it replaces
+		insertQuery := `
+			INSERT INTO ` + constants.TableAPIKeys + ` (key_id, user_id, api_key_hash, name, expires_at, created_at, rotated_from, scopes)
+			VALUES ($1, $2, $3, $4, $5, $6, $7, $8)
+		`
+		_, err = tx.ExecContext(
+			ctx,
+			insertQuery,
+			replacement.ID,
+			replacement.UserID,
+			replacement.APIKeyHash,
+			replacement.Name,
+			replacement.ExpiresAt,
+			replacement.CreatedAt,
+			replacement.RotatedFrom,
+			pq.Array(replacement.Scopes),
+		)
+
+		// Log the query execution with sensitive data redacted
+		utils.LogDBQuery(
+			insertQuery,
+			[]interface{}{replacement.ID, replacement.UserID, constants.LogRedactedValue, replacement.Name, replacement.ExpiresAt, replacement.CreatedAt, oldKeyID, replacement.Scopes},
+			time.Since(startTime),
+			err,
+		)
+
+		if err != nil {
+			return fmt.Errorf("failed to create replacement API key: %w", err)
+		}
+
+		log.Info().
+			Str(constants.ParamKeyID, replacement.ID).
+			Str(constants.ColumnRotatedFrom, oldKeyID).
+			Int64(constants.ColumnUserID, replacement.UserID).
+			Time(constants.ColumnExpiresAt, oldExpiresAt).
+			Msg(constants.LogEventAPIKey + " rotated")
+
+		return nil
+	})
+}
+
+// MarkUsedAfterRotation records the first use of a rotated API key. Later uses
+// leave the recorded time unchanged.
+//
+// Parameters:
+//   - ctx: Context for transaction and cancellation control
+//   - keyID: The unique identifier of the rotated key
+//   - usedAt: The time of the use
+//
+// Returns:
+//   - true if this was the first use after rotation, false if it was recorded before
+//   - An error if the update fails
+func (r *PostgresAPIKeyRepository) MarkUsedAfterRotation(ctx context.Context, keyID string, usedAt time.Time) (bool, error) {
+	// Start query timer
+	startTime := time.Now()
+
+	// Define the query
+	query := `
+		UPDATE ` + constants.TableAPIKeys + `
+		SET ` + constants.ColumnUsedAfterRotationAt + ` = $2
+		WHERE ` + constants.ColumnKeyID + ` = $1 AND ` + constants.ColumnRotatedAt + ` IS NOT NULL AND ` + constants.ColumnUsedAfterRotationAt + ` IS NULL
+	`
+
+	// Execute the query
+	result, err := r.db.ExecContext(ctx, query, keyID, usedAt)
+
+	// Log the query execution
+	utils.LogDBQuery(
+		query,
+		[]interface{}{keyID, usedAt},
+		time.Since(startTime),
+		err,
+	)
+
+	if err != nil {
+		return false, fmt.Errorf("failed to record use of rotated API key: %w", err)
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return false, fmt.Errorf("failed to get rows affected: %w", err)
+	}
+
+	return rowsAffected > 0, nil
+}
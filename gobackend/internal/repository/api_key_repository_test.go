@@ -48,11 +48,12 @@ func TestAPIKeyRepository_Create(t *testing.T) {
 		Name:       "Test API Key",
 		ExpiresAt:  now.Add(24 * time.Hour),
 		CreatedAt:  now,
+		Scopes:     []string{"documents:read"},
 	}
 
 	// Expected query with placeholders for the arguments
 	mock.ExpectExec("INSERT INTO api_keys").
-		WithArgs(apiKey.ID, apiKey.UserID, apiKey.APIKeyHash, apiKey.Name, apiKey.ExpiresAt, apiKey.CreatedAt).
+		WithArgs(apiKey.ID, apiKey.UserID, apiKey.APIKeyHash, apiKey.Name, apiKey.ExpiresAt, apiKey.CreatedAt, "{\"documents:read\"}").
 		WillReturnResult(sqlmock.NewResult(1, 1))
 
 	// Execute the method being tested
@@ -81,7 +82,7 @@ func TestAPIKeyRepository_Create_Error(t *testing.T) {
 
 	// Mock database error
 	mock.ExpectExec("INSERT INTO api_keys").
-		WithArgs(apiKey.ID, apiKey.UserID, apiKey.APIKeyHash, apiKey.Name, apiKey.ExpiresAt, apiKey.CreatedAt).
+		WithArgs(apiKey.ID, apiKey.UserID, apiKey.APIKeyHash, apiKey.Name, apiKey.ExpiresAt, apiKey.CreatedAt, sqlmock.AnyArg()).
 		WillReturnError(errors.New("database error"))
 
 	// Execute the method being tested
@@ -111,11 +112,11 @@ func TestAPIKeyRepository_GetByID(t *testing.T) {
 	}
 
 	// Set up query result
-	rows := sqlmock.NewRows([]string{"key_id", "user_id", "api_key_hash", "name", "expires_at", "created_at"}).
-		AddRow(apiKey.ID, apiKey.UserID, apiKey.APIKeyHash, apiKey.Name, apiKey.ExpiresAt, apiKey.CreatedAt)
+	rows := sqlmock.NewRows([]string{"key_id", "user_id", "api_key_hash", "name", "expires_at", "created_at", "rotated_from", "rotated_at", "used_after_rotation_at", "disabled_at", "rotation_campaign_id", "rotation_deadline", "scopes"}).
+		AddRow(apiKey.ID, apiKey.UserID, apiKey.APIKeyHash, apiKey.Name, apiKey.ExpiresAt, apiKey.CreatedAt, nil, nil, nil, nil, nil, nil, "{documents:read,settings:read}")
 
 	// Expected query with placeholder for the ID
-	mock.ExpectQuery("SELECT key_id, user_id, api_key_hash, name, expires_at, created_at FROM api_keys WHERE key_id = \\$1").
+	mock.ExpectQuery("SELECT key_id, user_id, api_key_hash, name, expires_at, created_at, rotated_from, rotated_at, used_after_rotation_at, disabled_at, rotation_campaign_id, rotation_deadline, scopes FROM api_keys WHERE key_id = \\$1").
 		WithArgs(id).
 		WillReturnRows(rows)
 
@@ -130,6 +131,7 @@ func TestAPIKeyRepository_GetByID(t *testing.T) {
 	assert.Equal(t, apiKey.Name, result.Name)
 	assert.WithinDuration(t, apiKey.ExpiresAt, result.ExpiresAt, time.Second)
 	assert.WithinDuration(t, apiKey.CreatedAt, result.CreatedAt, time.Second)
+	assert.Equal(t, []string{"documents:read", "settings:read"}, result.Scopes)
 	assert.NoError(t, mock.ExpectationsWereMet())
 }
 
@@ -142,7 +144,7 @@ func TestAPIKeyRepository_GetByID_NotFound(t *testing.T) {
 	id := "nonexistent-id"
 
 	// Mock database response - empty result
-	mock.ExpectQuery("SELECT key_id, user_id, api_key_hash, name, expires_at, created_at FROM api_keys WHERE key_id = \\$1").
+	mock.ExpectQuery("SELECT key_id, user_id, api_key_hash, name, expires_at, created_at, rotated_from, rotated_at, used_after_rotation_at, disabled_at, rotation_campaign_id, rotation_deadline, scopes FROM api_keys WHERE key_id = \\$1").
 		WithArgs(id).
 		WillReturnError(sql.ErrNoRows)
 
@@ -183,13 +185,13 @@ func TestAPIKeyRepository_GetByUserID(t *testing.T) {
 	}
 
 	// Set up query result
-	rows := sqlmock.NewRows([]string{"key_id", "user_id", "api_key_hash", "name", "expires_at", "created_at"})
+	rows := sqlmock.NewRows([]string{"key_id", "user_id", "api_key_hash", "name", "expires_at", "created_at", "rotated_from", "rotated_at", "used_after_rotation_at", "disabled_at", "rotation_campaign_id", "rotation_deadline", "scopes"})
 	for _, apiKey := range apiKeys {
-		rows.AddRow(apiKey.ID, apiKey.UserID, apiKey.APIKeyHash, apiKey.Name, apiKey.ExpiresAt, apiKey.CreatedAt)
+		rows.AddRow(apiKey.ID, apiKey.UserID, apiKey.APIKeyHash, apiKey.Name, apiKey.ExpiresAt, apiKey.CreatedAt, nil, nil, nil, nil, nil, nil, "{}")
 	}
 
 	// Expected query with placeholder for the user ID
-	mock.ExpectQuery("SELECT key_id, user_id, api_key_hash, name, expires_at, created_at FROM api_keys WHERE user_id = \\$1 ORDER BY created_at DESC").
+	mock.ExpectQuery("SELECT key_id, user_id, api_key_hash, name, expires_at, created_at, rotated_from, rotated_at, used_after_rotation_at, disabled_at, rotation_campaign_id, rotation_deadline, scopes FROM api_keys WHERE user_id = \\$1 ORDER BY created_at DESC").
 		WithArgs(userID).
 		WillReturnRows(rows)
 
@@ -219,7 +221,7 @@ func TestAPIKeyRepository_GetByUserID_QueryError(t *testing.T) {
 	userID := int64(100)
 
 	// Mock database error
-	mock.ExpectQuery("SELECT key_id, user_id, api_key_hash, name, expires_at, created_at FROM api_keys WHERE user_id = \\$1 ORDER BY created_at DESC").
+	mock.ExpectQuery("SELECT key_id, user_id, api_key_hash, name, expires_at, created_at, rotated_from, rotated_at, used_after_rotation_at, disabled_at, rotation_campaign_id, rotation_deadline, scopes FROM api_keys WHERE user_id = \\$1 ORDER BY created_at DESC").
 		WithArgs(userID).
 		WillReturnError(errors.New("query error"))
 
@@ -242,11 +244,11 @@ func TestAPIKeyRepository_GetByUserID_ScanError(t *testing.T) {
 	userID := int64(100)
 
 	// Set up a row with invalid data that will cause a scan error
-	rows := sqlmock.NewRows([]string{"key_id", "user_id", "api_key_hash", "name", "expires_at", "created_at"}).
-		AddRow("key-1", "invalid-user-id", "hash-1", "Key 1", time.Now(), time.Now()) // invalid type for user_id
+	rows := sqlmock.NewRows([]string{"key_id", "user_id", "api_key_hash", "name", "expires_at", "created_at", "rotated_from", "rotated_at", "used_after_rotation_at", "disabled_at", "rotation_campaign_id", "rotation_deadline", "scopes"}).
+		AddRow("key-1", "invalid-user-id", "hash-1", "Key 1", time.Now(), time.Now(), nil, nil, nil, nil, nil, nil, "{}") // invalid type for user_id
 
 	// Expected query
-	mock.ExpectQuery("SELECT key_id, user_id, api_key_hash, name, expires_at, created_at FROM api_keys WHERE user_id = \\$1 ORDER BY created_at DESC").
+	mock.ExpectQuery("SELECT key_id, user_id, api_key_hash, name, expires_at, created_at, rotated_from, rotated_at, used_after_rotation_at, disabled_at, rotation_campaign_id, rotation_deadline, scopes FROM api_keys WHERE user_id = \\$1 ORDER BY created_at DESC").
 		WithArgs(userID).
 		WillReturnRows(rows)
 
@@ -269,12 +271,12 @@ func TestAPIKeyRepository_GetByUserID_RowsErr(t *testing.T) {
 	userID := int64(100)
 
 	// Create a custom rows mock that returns an error on Err()
-	rows := sqlmock.NewRows([]string{"key_id", "user_id", "api_key_hash", "name", "expires_at", "created_at"}).
-		AddRow("key-1", userID, "hash-1", "Key 1", time.Now(), time.Now()).
+	rows := sqlmock.NewRows([]string{"key_id", "user_id", "api_key_hash", "name", "expires_at", "created_at", "rotated_from", "rotated_at", "used_after_rotation_at", "disabled_at", "rotation_campaign_id", "rotation_deadline", "scopes"}).
+		AddRow("key-1", userID, "hash-1", "Key 1", time.Now(), time.Now(), nil, nil, nil, nil, nil, nil, "{}").
 		RowError(0, errors.New("row error"))
 
 	// Expected query
-	mock.ExpectQuery("SELECT key_id, user_id, api_key_hash, name, expires_at, created_at FROM api_keys WHERE user_id = \\$1 ORDER BY created_at DESC").
+	mock.ExpectQuery("SELECT key_id, user_id, api_key_hash, name, expires_at, created_at, rotated_from, rotated_at, used_after_rotation_at, disabled_at, rotation_campaign_id, rotation_deadline, scopes FROM api_keys WHERE user_id = \\$1 ORDER BY created_at DESC").
 		WithArgs(userID).
 		WillReturnRows(rows)
 
@@ -508,7 +510,7 @@ func TestAPIKeyRepository_DeleteExpired(t *testing.T) {
 		WillReturnResult(sqlmock.NewResult(0, 5)) // 5 expired keys deleted
 
 	// Execute the method being tested
-	count, err := repo.DeleteExpired(context.Background())
+	count, err := repo.DeleteExpired(context.Background(), 0)
 
 	// Assert the results
 	assert.NoError(t, err)
@@ -516,6 +518,43 @@ func TestAPIKeyRepository_DeleteExpired(t *testing.T) {
 	assert.NoError(t, mock.ExpectationsWereMet())
 }
 
+func TestAPIKeyRepository_DeleteExpired_Batch(t *testing.T) {
+	// Set up the test
+	repo, mock, cleanup := setupAPIKeyRepositoryTest(t)
+	defer cleanup()
+
+	// Only a batch of the expired keys is deleted
+	mock.ExpectExec("DELETE FROM api_keys WHERE key_id IN \\( SELECT key_id FROM api_keys WHERE expires_at < \\$1 LIMIT \\$2 \\)").
+		WithArgs(sqlmock.AnyArg(), 100).
+		WillReturnResult(sqlmock.NewResult(0, 100))
+
+	// Execute the method being tested
+	count, err := repo.DeleteExpired(context.Background(), 100)
+
+	// Assert the results
+	assert.NoError(t, err)
+	assert.Equal(t, int64(100), count)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestAPIKeyRepository_CountExpired(t *testing.T) {
+	// Set up the test
+	repo, mock, cleanup := setupAPIKeyRepositoryTest(t)
+	defer cleanup()
+
+	mock.ExpectQuery("SELECT COUNT\\(\\*\\) FROM api_keys WHERE expires_at < \\$1").
+		WithArgs(sqlmock.AnyArg()).
+		WillReturnRows(sqlmock.NewRows([]string{"count"}).AddRow(7))
+
+	// Execute the method being tested
+	count, err := repo.CountExpired(context.Background())
+
+	// Assert the results
+	assert.NoError(t, err)
+	assert.Equal(t, int64(7), count)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
 func TestAPIKeyRepository_DeleteExpired_QueryError(t *testing.T) {
 	// Set up the test
 	repo, mock, cleanup := setupAPIKeyRepositoryTest(t)
@@ -527,7 +566,7 @@ func TestAPIKeyRepository_DeleteExpired_QueryError(t *testing.T) {
 		WillReturnError(errors.New("database error"))
 
 	// Execute the method being tested
-	count, err := repo.DeleteExpired(context.Background())
+	count, err := repo.DeleteExpired(context.Background(), 0)
 
 	// Assert the results
 	assert.Error(t, err)
@@ -550,7 +589,7 @@ func TestAPIKeyRepository_DeleteExpired_RowsAffectedError(t *testing.T) {
 		WillReturnResult(result)
 
 	// Execute the method being tested
-	count, err := repo.DeleteExpired(context.Background())
+	count, err := repo.DeleteExpired(context.Background(), 0)
 
 	// Assert the results
 	assert.Error(t, err)
@@ -558,3 +597,111 @@ func TestAPIKeyRepository_DeleteExpired_RowsAffectedError(t *testing.T) {
 	assert.Contains(t, err.Error(), "failed to get rows affected")
 	assert.NoError(t, mock.ExpectationsWereMet())
 }
+
+func TestAPIKeyRepository_GetByID_Rotated(t *testing.T) {
+	// Set up the test
+	repo, mock, cleanup := setupAPIKeyRepositoryTest(t)
+	defer cleanup()
+
+	// Set up test data
+	now := time.Now()
+	rows := sqlmock.NewRows([]string{"key_id", "user_id", "api_key_hash", "name", "expires_at", "created_at", "rotated_from", "rotated_at", "used_after_rotation_at", "disabled_at", "rotation_campaign_id", "rotation_deadline", "scopes"}).
+		AddRow("key-2", int64(100), "hash-2", "CI", now.Add(24*time.Hour), now, "key-1", now, nil, nil, nil, nil, "{}")
+
+	mock.ExpectQuery("SELECT key_id, user_id, api_key_hash, name, expires_at, created_at, rotated_from, rotated_at, used_after_rotation_at, disabled_at, rotation_campaign_id, rotation_deadline, scopes FROM api_keys WHERE key_id = \\$1").
+		WithArgs("key-2").
+		WillReturnRows(rows)
+
+	// Execute the method being tested
+	result, err := repo.GetByID(context.Background(), "key-2")
+
+	// Assert the results
+	assert.NoError(t, err)
+	if assert.NotNil(t, result.RotatedFrom) {
+		assert.Equal(t, "key-1", *result.RotatedFrom)
+	}
+	assert.True(t, result.IsRotated())
+	assert.Nil(t, result.UsedAfterRotationAt)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestAPIKeyRepository_Rotate(t *testing.T) {
+	now := time.Now()
+	oldExpiresAt := now.Add(24 * time.Hour)
+	oldKeyID := "key-1"
+	replacement := &models.APIKey{
+		ID:          "key-2",
+		UserID:      100,
+		APIKeyHash:  "hash-2",
+		Name:        "CI",
+		ExpiresAt:   now.Add(30 * 24 * time.Hour),
+		CreatedAt:   now,
+		RotatedFrom: &oldKeyID,
+	}
+
+	t.Run("Success", func(t *testing.T) {
+		repo, mock, cleanup := setupAPIKeyRepositoryTest(t)
+		defer cleanup()
+
+		mock.ExpectBegin()
+		mock.ExpectExec("UPDATE api_keys SET rotated_at = \\$2, expires_at = \\$3 WHERE key_id = \\$1 AND rotated_at IS NULL").
+			WithArgs(oldKeyID, now, oldExpiresAt).
+			WillReturnResult(sqlmock.NewResult(0, 1))
+		mock.ExpectExec("INSERT INTO api_keys").
+			WithArgs(replacement.ID, replacement.UserID, replacement.APIKeyHash, replacement.Name, replacement.ExpiresAt, replacement.CreatedAt, replacement.RotatedFrom, sqlmock.AnyArg()).
+			WillReturnResult(sqlmock.NewResult(0, 1))
+		mock.ExpectCommit()
+
+		err := repo.Rotate(context.Background(), oldKeyID, now, oldExpiresAt, replacement)
+
+		assert.NoError(t, err)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("Already Rotated", func(t *testing.T) {
+		repo, mock, cleanup := setupAPIKeyRepositoryTest(t)
+		defer cleanup()
+
+		mock.ExpectBegin()
+		mock.ExpectExec("UPDATE api_keys SET rotated_at").
+			WithArgs(oldKeyID, now, oldExpiresAt).
+			WillReturnResult(sqlmock.NewResult(0, 0))
+		mock.ExpectRollback()
+
+		err := repo.Rotate(context.Background(), oldKeyID, now, oldExpiresAt, replacement)
+
+		assert.Error(t, err)
+		assert.Contains(t, err.Error(), "already been rotated")
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+}
+
+func TestAPIKeyRepository_MarkUsedAfterRotation(t *testing.T) {
+	now := time.Now()
+
+	tests := []struct {
+		name         string
+		rowsAffected int64
+		expected     bool
+	}{
+		{name: "First use", rowsAffected: 1, expected: true},
+		{name: "Already recorded", rowsAffected: 0, expected: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			repo, mock, cleanup := setupAPIKeyRepositoryTest(t)
+			defer cleanup()
+
+			mock.ExpectExec("UPDATE api_keys SET used_after_rotation_at = \\$2 WHERE key_id = \\$1 AND rotated_at IS NOT NULL AND used_after_rotation_at IS NULL").
+				WithArgs("key-1", now).
+				WillReturnResult(sqlmock.NewResult(0, tt.rowsAffected))
+
+			first, err := repo.MarkUsedAfterRotation(context.Background(), "key-1", now)
+
+			assert.NoError(t, err)
+			assert.Equal(t, tt.expected, first)
+			assert.NoError(t, mock.ExpectationsWereMet())
+		})
+	}
+}
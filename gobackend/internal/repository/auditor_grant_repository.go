@@ -0,0 +1,376 @@
+// Package repository provides data access interfaces and implementations for the HideMe application.
+// It follows the repository pattern to abstract database operations and provide a clean API
+// for data persistence operations.
+//
+// This file implements the auditor grant repository, which stores the read-only access grants
+// of external auditors and logs every request made with them.
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/lib/pq"
+
+	"github.com/yasinhessnawi1/Hideme_Backend/internal/constants"
+	"github.com/yasinhessnawi1/Hideme_Backend/internal/database"
+	"github.com/yasinhessnawi1/Hideme_Backend/internal/models"
+	"github.com/yasinhessnawi1/Hideme_Backend/internal/utils"
+)
+
+// AuditorGrantRepository defines methods for storing auditor access grants and their access log.
+type AuditorGrantRepository interface {
+	// Create stores a new grant.
+	//
+	// Parameters:
+	//   - ctx: Context for transaction and cancellation control
+	//   - grant: The grant to store; its ID and creation time are set on success
+	//
+	// Returns:
+	//   - An error for database issues
+	Create(ctx context.Context, grant *models.AuditorGrant) error
+
+	// GetByTokenHash retrieves the grant of a token, whether it is still active or not.
+	//
+	// Parameters:
+	//   - ctx: Context for transaction and cancellation control
+	//   - tokenHash: The SHA-256 hash of the token
+	//
+	// Returns:
+	//   - The grant
+	//   - NotFoundError if no grant has the token
+	//   - Other errors for database issues
+	GetByTokenHash(ctx context.Context, tokenHash string) (*models.AuditorGrant, error)
+
+	// List retrieves the most recent grants, including expired and revoked ones.
+	//
+	// Parameters:
+	//   - ctx: Context for transaction and cancellation control
+	//   - limit: The maximum number of grants to return
+	//
+	// Returns:
+	//   - The grants, latest first
+	//   - An error for database issues
+	List(ctx context.Context, limit int) ([]*models.AuditorGrant, error)
+
+	// Revoke ends a grant. Revoking a revoked grant keeps the first revocation time.
+	//
+	// Parameters:
+	//   - ctx: Context for transaction and cancellation control
+	//   - id: The ID of the grant
+	//   - revokedAt: When the grant was revoked
+	//
+	// Returns:
+	//   - NotFoundError if the grant doesn't exist
+	//   - Other errors for database issues
+	Revoke(ctx context.Context, id int64, revokedAt time.Time) error
+
+	// LogAccess records a request made with a grant.
+	//
+	// Parameters:
+	//   - ctx: Context for transaction and cancellation control
+	//   - access: The request to record; its ID and time are set on success
+	//
+	// Returns:
+	//   - An error for database issues
+	LogAccess(ctx context.Context, access *models.AuditorAccess) error
+
+	// ListAccess retrieves the most recent requests made with a grant.
+	//
+	// Parameters:
+	//   - ctx: Context for transaction and cancellation control
+	//   - grantID: The ID of the grant
+	//   - limit: The maximum number of requests to return
+	//
+	// Returns:
+	//   - The requests, latest first
+	//   - An error for database issues
+	ListAccess(ctx context.Context, grantID int64, limit int) ([]*models.AuditorAccess, error)
+}
+
+// PostgresAuditorGrantRepository is a PostgreSQL implementation of AuditorGrantRepository.
+type PostgresAuditorGrantRepository struct {
+	db *database.Pool
+}
+
+// NewAuditorGrantRepository creates a new AuditorGrantRepository implementation for PostgreSQL.
+//
+// Parameters:
+//   - db: A connection pool for PostgreSQL database access
+//
+// Returns:
+//   - An implementation of the AuditorGrantRepository interface
+func NewAuditorGrantRepository(db *database.Pool) AuditorGrantRepository {
+	return &PostgresAuditorGrantRepository{
+		db: db,
+	}
+}
+
+// auditorGrantColumns are the columns selected for grants.
+const auditorGrantColumns = `grant_id, auditor_name, auditor_email, token_hash, document_ids, user_ids, reason, created_by, expires_at, revoked_at, created_at`
+
+// scanAuditorGrant scans a row selecting auditorGrantColumns.
+func scanAuditorGrant(row interface{ Scan(...interface{}) error }) (*models.AuditorGrant, error) {
+	grant := &models.AuditorGrant{}
+	err := row.Scan(
+		&grant.ID,
+		&grant.AuditorName,
+		&grant.AuditorEmail,
+		&grant.TokenHash,
+		pq.Array(&grant.DocumentIDs),
+		pq.Array(&grant.UserIDs),
+		&grant.Reason,
+		&grant.CreatedBy,
+		&grant.ExpiresAt,
+		&grant.RevokedAt,
+		&grant.CreatedAt,
+	)
+	return grant, err
+}
+
+// Create stores a new grant.
+func (r *PostgresAuditorGrantRepository) Create(ctx context.Context, grant *models.AuditorGrant) error {
+	// Start query timer
+	startTime := time.Now()
+
+	// Define the query
+	query := `
+        INSERT INTO ` + constants.TableAuditorGrants + ` (auditor_name, auditor_email, token_hash, document_ids, user_ids, reason, created_by, expires_at)
+        VALUES ($1, $2, $3, $4, $5, $6, $7, $8)
+        RETURNING grant_id, created_at
+    `
+	args := []interface{}{
+		grant.AuditorName,
+		grant.AuditorEmail,
+		grant.TokenHash,
+		pq.Array(grant.DocumentIDs),
+		pq.Array(grant.UserIDs),
+		grant.Reason,
+		grant.CreatedBy,
+		grant.ExpiresAt,
+	}
+
+	// Execute the query
+	err := r.db.QueryRowContext(ctx, query, args...).Scan(&grant.ID, &grant.CreatedAt)
+
+	// Log the query execution
+	utils.LogDBQuery(
+		query,
+		[]interface{}{grant.AuditorName, grant.AuditorEmail, "[REDACTED]", grant.DocumentIDs, grant.UserIDs, grant.Reason, grant.CreatedBy, grant.ExpiresAt},
+		time.Since(startTime),
+		err,
+	)
+
+	if err != nil {
+		return fmt.Errorf("failed to create auditor grant: %w", err)
+	}
+
+	return nil
+}
+
+// GetByTokenHash retrieves the grant of a token, whether it is still active or not.
+func (r *PostgresAuditorGrantRepository) GetByTokenHash(ctx context.Context, tokenHash string) (*models.AuditorGrant, error) {
+	// Start query timer
+	startTime := time.Now()
+
+	// Define the query
+	query := `
+        SELECT ` + auditorGrantColumns + `
+        FROM ` + constants.TableAuditorGrants + `
+        WHERE token_hash = $1
+    `
+
+	// Execute the query
+	grant, err := scanAuditorGrant(r.db.QueryRowContext(ctx, query, tokenHash))
+
+	// Log the query execution
+	utils.LogDBQuery(
+		query,
+		[]interface{}{"[REDACTED]"},
+		time.Since(startTime),
+		err,
+	)
+
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, utils.NewNotFoundError("AuditorGrant", "token").WithCause(err)
+		}
+		return nil, fmt.Errorf("failed to get auditor grant: %w", err)
+	}
+
+	return grant, nil
+}
+
+// List retrieves the most recent grants, including expired and revoked ones.
+func (r *PostgresAuditorGrantRepository) List(ctx context.Context, limit int) ([]*models.AuditorGrant, error) {
+	// Start query timer
+	startTime := time.Now()
+
+	// Define the query
+	query := `
+        SELECT ` + auditorGrantColumns + `
+        FROM ` + constants.TableAuditorGrants + `
+        ORDER BY created_at DESC, grant_id DESC
+        LIMIT $1
+    `
+
+	// Execute the query
+	rows, err := r.db.QueryContext(ctx, query, limit)
+
+	// Log the query execution
+	utils.LogDBQuery(
+		query,
+		[]interface{}{limit},
+		time.Since(startTime),
+		err,
+	)
+
+	if err != nil {
+		return nil, fmt.Errorf("failed to list auditor grants: %w", err)
+	}
+	defer rows.Close()
+
+	grants := []*models.AuditorGrant{}
+	for rows.Next() {
+		grant, err := scanAuditorGrant(rows)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan auditor grant row: %w", err)
+		}
+		grants = append(grants, grant)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating auditor grant rows: %w", err)
+	}
+
+	return grants, nil
+}
+
+// Revoke ends a grant. Revoking a revoked grant keeps the first revocation time.
+func (r *PostgresAuditorGrantRepository) Revoke(ctx context.Context, id int64, revokedAt time.Time) error {
+	// Start query timer
+	startTime := time.Now()
+
+	// Define the query
+	query := `
+        UPDATE ` + constants.TableAuditorGrants + `
+        SET revoked_at = COALESCE(revoked_at, $2)
+        WHERE grant_id = $1
+    `
+
+	// Execute the query
+	result, err := r.db.ExecContext(ctx, query, id, revokedAt)
+
+	// Log the query execution
+	utils.LogDBQuery(
+		query,
+		[]interface{}{id, revokedAt},
+		time.Since(startTime),
+		err,
+	)
+
+	if err != nil {
+		return fmt.Errorf("failed to revoke auditor grant: %w", err)
+	}
+
+	// Check if any rows were affected
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("failed to get rows affected: %w", err)
+	}
+
+	if rowsAffected == 0 {
+		return utils.NewNotFoundError("AuditorGrant", id)
+	}
+
+	return nil
+}
+
+// LogAccess records a request made with a grant.
+func (r *PostgresAuditorGrantRepository) LogAccess(ctx context.Context, access *models.AuditorAccess) error {
+	// Start query timer
+	startTime := time.Now()
+
+	// Define the query
+	query := `
+        INSERT INTO ` + constants.TableAuditorAccessLog + ` (grant_id, action, document_id, allowed, ip_address)
+        VALUES ($1, $2, $3, $4, $5)
+        RETURNING access_id, accessed_at
+    `
+	args := []interface{}{access.GrantID, access.Action, access.DocumentID, access.Allowed, access.IPAddress}
+
+	// Execute the query
+	err := r.db.QueryRowContext(ctx, query, args...).Scan(&access.ID, &access.AccessedAt)
+
+	// Log the query execution
+	utils.LogDBQuery(
+		query,
+		args,
+		time.Since(startTime),
+		err,
+	)
+
+	if err != nil {
+		return fmt.Errorf("failed to log auditor access: %w", err)
+	}
+
+	return nil
+}
+
+// ListAccess retrieves the most recent requests made with a grant.
+func (r *PostgresAuditorGrantRepository) ListAccess(ctx context.Context, grantID int64, limit int) ([]*models.AuditorAccess, error) {
+	// Start query timer
+	startTime := time.Now()
+
+	// Define the query
+	query := `
+        SELECT access_id, grant_id, action, document_id, allowed, ip_address, accessed_at
+        FROM ` + constants.TableAuditorAccessLog + `
+        WHERE grant_id = $1
+        ORDER BY accessed_at DESC, access_id DESC
+        LIMIT $2
+    `
+	args := []interface{}{grantID, limit}
+
+	// Execute the query
+	rows, err := r.db.QueryContext(ctx, query, args...)
+
+	// Log the query execution
+	utils.LogDBQuery(
+		query,
+		args,
+		time.Since(startTime),
+		err,
+	)
+
+	if err != nil {
+		return nil, fmt.Errorf("failed to list auditor access: %w", err)
+	}
+	defer rows.Close()
+
+	accesses := []*models.AuditorAccess{}
+	for rows.Next() {
+		access := &models.AuditorAccess{}
+		if err := rows.Scan(
+			&access.ID,
+			&access.GrantID,
+			&access.Action,
+			&access.DocumentID,
+			&access.Allowed,
+			&access.IPAddress,
+			&access.AccessedAt,
+		); err != nil {
+			return nil, fmt.Errorf("failed to scan auditor access row: %w", err)
+		}
+		accesses = append(accesses, access)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating auditor access rows: %w", err)
+	}
+
+	return accesses, nil
+}
@@ -0,0 +1,150 @@
+package repository_test
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/yasinhessnawi1/Hideme_Backend/internal/database"
+	"github.com/yasinhessnawi1/Hideme_Backend/internal/models"
+	"github.com/yasinhessnawi1/Hideme_Backend/internal/repository"
+	"github.com/yasinhessnawi1/Hideme_Backend/internal/utils"
+)
+
+func setupAuditorGrantTest(t *testing.T) (repository.AuditorGrantRepository, sqlmock.Sqlmock, func()) {
+	db, mock, err := sqlmock.New()
+	require.NoError(t, err)
+
+	return repository.NewAuditorGrantRepository(&database.Pool{DB: db}), mock, func() { db.Close() }
+}
+
+var auditorGrantRowColumns = []string{"grant_id", "auditor_name", "auditor_email", "token_hash", "document_ids", "user_ids", "reason", "created_by", "expires_at", "revoked_at", "created_at"}
+
+func TestAuditorGrantRepository_Create(t *testing.T) {
+	repo, mock, cleanup := setupAuditorGrantTest(t)
+	defer cleanup()
+	now := time.Now()
+	grant := &models.AuditorGrant{
+		AuditorName:  "Kari Nordmann",
+		AuditorEmail: "kari@audit.example",
+		TokenHash:    "hash",
+		DocumentIDs:  []int64{12},
+		UserIDs:      []int64{4, 9},
+		Reason:       "Annual audit",
+		CreatedBy:    1,
+		ExpiresAt:    now.AddDate(0, 0, 30),
+	}
+
+	mock.ExpectQuery("INSERT INTO auditor_grants").
+		WithArgs("Kari Nordmann", "kari@audit.example", "hash", "{12}", "{4,9}", "Annual audit", int64(1), grant.ExpiresAt).
+		WillReturnRows(sqlmock.NewRows([]string{"grant_id", "created_at"}).AddRow(3, now))
+
+	require.NoError(t, repo.Create(context.Background(), grant))
+	assert.Equal(t, int64(3), grant.ID)
+	assert.Equal(t, now, grant.CreatedAt)
+
+	mock.ExpectQuery("INSERT INTO auditor_grants").
+		WillReturnError(errors.New("connection reset"))
+
+	assert.Error(t, repo.Create(context.Background(), grant))
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestAuditorGrantRepository_GetByTokenHash(t *testing.T) {
+	repo, mock, cleanup := setupAuditorGrantTest(t)
+	defer cleanup()
+	now := time.Now()
+
+	mock.ExpectQuery("SELECT (.+) FROM auditor_grants WHERE token_hash = \\$1").
+		WithArgs("hash").
+		WillReturnRows(sqlmock.NewRows(auditorGrantRowColumns).
+			AddRow(3, "Kari Nordmann", "kari@audit.example", "hash", "{12}", "{4}", "Annual audit", 1, now, nil, now))
+
+	grant, err := repo.GetByTokenHash(context.Background(), "hash")
+	require.NoError(t, err)
+	assert.Equal(t, []int64{12}, grant.DocumentIDs)
+	assert.Equal(t, []int64{4}, grant.UserIDs)
+	assert.Nil(t, grant.RevokedAt)
+
+	// Unknown tokens are reported as not found
+	mock.ExpectQuery("SELECT (.+) FROM auditor_grants").
+		WithArgs("unknown").
+		WillReturnError(sql.ErrNoRows)
+
+	_, err = repo.GetByTokenHash(context.Background(), "unknown")
+	assert.True(t, errors.Is(err, utils.ErrNotFound))
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestAuditorGrantRepository_List(t *testing.T) {
+	repo, mock, cleanup := setupAuditorGrantTest(t)
+	defer cleanup()
+	now := time.Now()
+
+	mock.ExpectQuery("SELECT (.+) FROM auditor_grants ORDER BY created_at DESC(.+) LIMIT \\$1").
+		WithArgs(50).
+		WillReturnRows(sqlmock.NewRows(auditorGrantRowColumns).
+			AddRow(4, "Kari Nordmann", "kari@audit.example", "hash2", "{}", "{4}", "Follow-up", 1, now, now, now).
+			AddRow(3, "Kari Nordmann", "kari@audit.example", "hash", "{12}", "{}", "Annual audit", 1, now, nil, now))
+
+	grants, err := repo.List(context.Background(), 50)
+	require.NoError(t, err)
+	require.Len(t, grants, 2)
+	assert.NotNil(t, grants[0].RevokedAt)
+	assert.Equal(t, []int64{12}, grants[1].DocumentIDs)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestAuditorGrantRepository_Revoke(t *testing.T) {
+	repo, mock, cleanup := setupAuditorGrantTest(t)
+	defer cleanup()
+	now := time.Now()
+
+	mock.ExpectExec("UPDATE auditor_grants SET revoked_at = COALESCE\\(revoked_at, \\$2\\) WHERE grant_id = \\$1").
+		WithArgs(int64(3), now).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectExec("UPDATE auditor_grants").
+		WithArgs(int64(4), now).
+		WillReturnResult(sqlmock.NewResult(0, 0))
+
+	require.NoError(t, repo.Revoke(context.Background(), 3, now))
+
+	// A missing grant is reported as not found
+	err := repo.Revoke(context.Background(), 4, now)
+	assert.True(t, errors.Is(err, utils.ErrNotFound))
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestAuditorGrantRepository_Access(t *testing.T) {
+	repo, mock, cleanup := setupAuditorGrantTest(t)
+	defer cleanup()
+	now := time.Now()
+	documentID := int64(12)
+	access := &models.AuditorAccess{GrantID: 3, Action: "view_document", DocumentID: &documentID, Allowed: true, IPAddress: "192.0.2.10"}
+
+	mock.ExpectQuery("INSERT INTO auditor_access_log").
+		WithArgs(int64(3), "view_document", &documentID, true, "192.0.2.10").
+		WillReturnRows(sqlmock.NewRows([]string{"access_id", "accessed_at"}).AddRow(8, now))
+
+	require.NoError(t, repo.LogAccess(context.Background(), access))
+	assert.Equal(t, int64(8), access.ID)
+
+	mock.ExpectQuery("SELECT (.+) FROM auditor_access_log WHERE grant_id = \\$1 ORDER BY accessed_at DESC(.+) LIMIT \\$2").
+		WithArgs(int64(3), 500).
+		WillReturnRows(sqlmock.NewRows([]string{"access_id", "grant_id", "action", "document_id", "allowed", "ip_address", "accessed_at"}).
+			AddRow(9, 3, "list_documents", nil, true, "192.0.2.10", now).
+			AddRow(8, 3, "view_document", 12, true, "192.0.2.10", now))
+
+	accesses, err := repo.ListAccess(context.Background(), 3, 500)
+	require.NoError(t, err)
+	require.Len(t, accesses, 2)
+	assert.Nil(t, accesses[0].DocumentID)
+	assert.Equal(t, int64(12), *accesses[1].DocumentID)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
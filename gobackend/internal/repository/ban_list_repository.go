@@ -12,6 +12,7 @@ import (
 	"database/sql"
 	"errors"
 	"fmt"
+	"strings"
 	"time"
 
 	"github.com/lib/pq"
@@ -64,6 +65,18 @@ type BanListRepository interface {
 	//   - Other errors for database issues
 	CreateBanList(ctx context.Context, settingID int64) (*models.BanList, error)
 
+	// SetOptions sets how all words of a ban list are matched.
+	//
+	// Parameters:
+	//   - ctx: Context for transaction and cancellation control
+	//   - banListID: The unique identifier of the ban list
+	//   - options: How all words of the ban list are matched
+	//
+	// Returns:
+	//   - NotFoundError if the ban list doesn't exist
+	//   - Other errors for database issues
+	SetOptions(ctx context.Context, banListID int64, options models.BanListOptions) error
+
 	// Delete removes a ban list and all its words.
 	//
 	// Parameters:
@@ -118,7 +131,8 @@ type BanListRepository interface {
 	// This method is idempotent - removing words that don't exist is not an error.
 	RemoveWords(ctx context.Context, banListID int64, words []string) error
 
-	// WordExists checks if a word exists in a ban list.
+	// WordExists checks if a word exists in a ban list, ignoring case and diacritics where
+	// the ban list or the word on it asks for it.
 	//
 	// Parameters:
 	//   - ctx: Context for transaction and cancellation control
@@ -130,6 +144,29 @@ type BanListRepository interface {
 	//   - false if the word does not exist in the ban list
 	//   - An error if the check fails
 	WordExists(ctx context.Context, banListID int64, word string) (bool, error)
+
+	// GetWordOptions retrieves how the words of a ban list are matched.
+	//
+	// Parameters:
+	//   - ctx: Context for transaction and cancellation control
+	//   - banListID: The unique identifier of the ban list
+	//
+	// Returns:
+	//   - The options keyed by word, for the words not matched exactly as written
+	//   - An error if retrieval fails
+	GetWordOptions(ctx context.Context, banListID int64) (map[string]models.BanListMatchOptions, error)
+
+	// SetWordOptions sets how words of a ban list are matched.
+	//
+	// Parameters:
+	//   - ctx: Context for transaction and cancellation control
+	//   - banListID: The unique identifier of the ban list
+	//   - words: The words whose options are set; words not in the ban list are ignored
+	//   - options: How the words are matched
+	//
+	// Returns:
+	//   - An error if the update fails
+	SetWordOptions(ctx context.Context, banListID int64, words []string, options models.BanListMatchOptions) error
 }
 
 // PostgresBanListRepository is a PostgreSQL implementation of BanListRepository.
@@ -152,6 +189,30 @@ func NewBanListRepository(db *database.Pool) BanListRepository {
 	}
 }
 
+// banListColumns is the column list shared by the ban list queries.
+const banListColumns = constants.ColumnBanID + `, ` + constants.ColumnSettingID + `, ` +
+	constants.ColumnCaseInsensitive + `, ` + constants.ColumnDiacriticInsensitive + `, ` + constants.ColumnWholeWord
+
+// scanBanList scans the banListColumns of a row.
+//
+// Parameters:
+//   - scanner: The row to scan from
+//
+// Returns:
+//   - The scanned ban list
+//   - An error if scanning fails
+func scanBanList(scanner interface{ Scan(dest ...any) error }) (*models.BanList, error) {
+	banList := &models.BanList{}
+	err := scanner.Scan(
+		&banList.ID,
+		&banList.SettingID,
+		&banList.CaseInsensitive,
+		&banList.DiacriticInsensitive,
+		&banList.WholeWord,
+	)
+	return banList, err
+}
+
 // GetByID retrieves a ban list by ID.
 //
 // Parameters:
@@ -168,17 +229,13 @@ func (r *PostgresBanListRepository) GetByID(ctx context.Context, id int64) (*mod
 
 	// Define the query
 	query := `
-        SELECT ` + constants.ColumnBanID + `, ` + constants.ColumnSettingID + `
+        SELECT ` + banListColumns + `
         FROM ` + constants.TableBanLists + `
         WHERE ` + constants.ColumnBanID + ` = $1
     `
 
 	// Execute the query
-	banList := &models.BanList{}
-	err := r.db.QueryRowContext(ctx, query, id).Scan(
-		&banList.ID,
-		&banList.SettingID,
-	)
+	banList, err := scanBanList(r.db.QueryRowContext(ctx, query, id))
 
 	// Log the query execution
 	utils.LogDBQuery(
@@ -190,7 +247,7 @@ func (r *PostgresBanListRepository) GetByID(ctx context.Context, id int64) (*mod
 
 	if err != nil {
 		if errors.Is(err, sql.ErrNoRows) {
-			return nil, utils.NewNotFoundError("BanList", id)
+			return nil, utils.NewNotFoundError("BanList", id).WithCause(err)
 		}
 		return nil, fmt.Errorf("failed to get ban list by ID: %w", err)
 	}
@@ -214,17 +271,13 @@ func (r *PostgresBanListRepository) GetBySettingID(ctx context.Context, settingI
 
 	// Define the query
 	query := `
-        SELECT ` + constants.ColumnBanID + `, ` + constants.ColumnSettingID + `
+        SELECT ` + banListColumns + `
         FROM ` + constants.TableBanLists + `
         WHERE ` + constants.ColumnSettingID + ` = $1
     `
 
 	// Execute the query
-	banList := &models.BanList{}
-	err := r.db.QueryRowContext(ctx, query, settingID).Scan(
-		&banList.ID,
-		&banList.SettingID,
-	)
+	banList, err := scanBanList(r.db.QueryRowContext(ctx, query, settingID))
 
 	// Log the query execution
 	utils.LogDBQuery(
@@ -236,7 +289,7 @@ func (r *PostgresBanListRepository) GetBySettingID(ctx context.Context, settingI
 
 	if err != nil {
 		if errors.Is(err, sql.ErrNoRows) {
-			return nil, utils.NewNotFoundError("BanList", fmt.Sprintf("setting_id=%d", settingID))
+			return nil, utils.NewNotFoundError("BanList", fmt.Sprintf("setting_id=%d", settingID)).WithCause(err)
 		}
 		return nil, fmt.Errorf("failed to get ban list by setting ID: %w", err)
 	}
@@ -279,10 +332,11 @@ func (r *PostgresBanListRepository) CreateBanList(ctx context.Context, settingID
 
 	if err != nil {
 		// Check for unique constraint violations
-		if pqErr, ok := err.(*pq.Error); ok {
+		var pqErr *pq.Error
+		if errors.As(err, &pqErr) {
 			// Check for duplicate key error
 			if pqErr.Code == constants.PGErrorDuplicateConstraint {
-				return nil, utils.NewDuplicateError("BanList", constants.ColumnSettingID, settingID)
+				return nil, utils.NewDuplicateError("BanList", constants.ColumnSettingID, settingID).WithCause(err)
 			}
 		}
 		return nil, fmt.Errorf("failed to create ban list: %w", err)
@@ -301,6 +355,59 @@ func (r *PostgresBanListRepository) CreateBanList(ctx context.Context, settingID
 	return banList, nil
 }
 
+// SetOptions sets how all words of a ban list are matched.
+//
+// Parameters:
+//   - ctx: Context for transaction and cancellation control
+//   - banListID: The unique identifier of the ban list
+//   - options: How all words of the ban list are matched
+//
+// Returns:
+//   - NotFoundError if the ban list doesn't exist
+//   - Other errors for database issues
+func (r *PostgresBanListRepository) SetOptions(ctx context.Context, banListID int64, options models.BanListOptions) error {
+	// Start query timer
+	startTime := time.Now()
+
+	// Define the query
+	query := `
+        UPDATE ` + constants.TableBanLists + `
+        SET ` + constants.ColumnCaseInsensitive + ` = $1, ` + constants.ColumnDiacriticInsensitive + ` = $2, ` + constants.ColumnWholeWord + ` = $3
+        WHERE ` + constants.ColumnBanID + ` = $4
+    `
+
+	// Execute the query
+	args := []interface{}{options.CaseInsensitive, options.DiacriticInsensitive, options.WholeWord, banListID}
+	result, err := r.db.ExecContext(ctx, query, args...)
+
+	// Log the query execution
+	utils.LogDBQuery(
+		query,
+		args,
+		time.Since(startTime),
+		err,
+	)
+
+	if err != nil {
+		return fmt.Errorf("failed to set ban list options: %w", err)
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("failed to get rows affected: %w", err)
+	}
+
+	if rowsAffected == 0 {
+		return utils.NewNotFoundError("BanList", banListID)
+	}
+
+	log.Info().
+		Int64(constants.ColumnBanID, banListID).
+		Msg("Ban list options set")
+
+	return nil
+}
+
 // Delete removes a ban list and all its words.
 // This operation uses a transaction to ensure both the ban list and its words are deleted atomically.
 //
@@ -524,7 +631,9 @@ func (r *PostgresBanListRepository) RemoveWords(ctx context.Context, banListID i
 	})
 }
 
-// WordExists checks if a word exists in a ban list.
+// WordExists checks if a word exists in a ban list, ignoring case and diacritics where
+// the ban list or the word on it asks for it. Words that ignore diacritics are compared
+// here rather than in the database, which has no diacritic folding of its own.
 //
 // Parameters:
 //   - ctx: Context for transaction and cancellation control
@@ -539,17 +648,21 @@ func (r *PostgresBanListRepository) WordExists(ctx context.Context, banListID in
 	// Start query timer
 	startTime := time.Now()
 
-	// Define the query
+	// Define the query; it narrows the words down to those that may match
 	query := `
-        SELECT EXISTS(
-            SELECT 1 FROM ` + constants.TableBanListWords + `
-            WHERE ` + constants.ColumnBanID + ` = $1 AND ` + constants.ColumnWord + ` = $2
-        )
+        SELECT w.` + constants.ColumnWord + `,
+               l.` + constants.ColumnCaseInsensitive + ` OR w.` + constants.ColumnCaseInsensitive + `,
+               l.` + constants.ColumnDiacriticInsensitive + ` OR w.` + constants.ColumnDiacriticInsensitive + `
+        FROM ` + constants.TableBanListWords + ` w
+        JOIN ` + constants.TableBanLists + ` l ON l.` + constants.ColumnBanID + ` = w.` + constants.ColumnBanID + `
+        WHERE w.` + constants.ColumnBanID + ` = $1
+          AND (w.` + constants.ColumnWord + ` = $2
+               OR ((l.` + constants.ColumnCaseInsensitive + ` OR w.` + constants.ColumnCaseInsensitive + `) AND LOWER(w.` + constants.ColumnWord + `) = LOWER($2))
+               OR l.` + constants.ColumnDiacriticInsensitive + ` OR w.` + constants.ColumnDiacriticInsensitive + `)
     `
 
 	// Execute the query
-	var exists bool
-	err := r.db.QueryRowContext(ctx, query, banListID, word).Scan(&exists)
+	rows, err := r.db.QueryContext(ctx, query, banListID, word)
 
 	// Log the query execution
 	utils.LogDBQuery(
@@ -562,6 +675,148 @@ func (r *PostgresBanListRepository) WordExists(ctx context.Context, banListID in
 	if err != nil {
 		return false, fmt.Errorf("failed to check if word exists in ban list: %w", err)
 	}
+	defer func() {
+		if closeErr := rows.Close(); closeErr != nil {
+			log.Error().Err(closeErr).Msg("failed to close rows")
+		}
+	}()
+
+	for rows.Next() {
+		var candidate string
+		var caseInsensitive, diacriticInsensitive bool
+		if err := rows.Scan(&candidate, &caseInsensitive, &diacriticInsensitive); err != nil {
+			return false, fmt.Errorf("failed to scan ban list word: %w", err)
+		}
+		if banListWordKey(candidate, caseInsensitive, diacriticInsensitive) == banListWordKey(word, caseInsensitive, diacriticInsensitive) {
+			return true, nil
+		}
+	}
+
+	if err := rows.Err(); err != nil {
+		return false, fmt.Errorf("error iterating ban list words: %w", err)
+	}
+
+	return false, nil
+}
+
+// banListWordKey returns the form of a word compared with the words of a ban list.
+func banListWordKey(word string, caseInsensitive, diacriticInsensitive bool) string {
+	if diacriticInsensitive {
+		word = utils.FoldDiacritics(word)
+	}
+	if caseInsensitive {
+		word = strings.ToLower(word)
+	}
+	return word
+}
+
+// GetWordOptions retrieves how the words of a ban list are matched.
+//
+// Parameters:
+//   - ctx: Context for transaction and cancellation control
+//   - banListID: The unique identifier of the ban list
+//
+// Returns:
+//   - The options keyed by word, for the words not matched exactly as written
+//   - An error if retrieval fails
+func (r *PostgresBanListRepository) GetWordOptions(ctx context.Context, banListID int64) (map[string]models.BanListMatchOptions, error) {
+	// Start query timer
+	startTime := time.Now()
+
+	// Define the query
+	query := `
+        SELECT ` + constants.ColumnWord + `, ` + constants.ColumnCaseInsensitive + `, ` + constants.ColumnDiacriticInsensitive + `,
+               ` + constants.ColumnWholeWord + `, ` + constants.ColumnStemming + `
+        FROM ` + constants.TableBanListWords + `
+        WHERE ` + constants.ColumnBanID + ` = $1
+          AND (` + constants.ColumnCaseInsensitive + ` OR ` + constants.ColumnDiacriticInsensitive + ` OR ` + constants.ColumnWholeWord + ` OR ` + constants.ColumnStemming + ` <> '')
+    `
+
+	// Execute the query
+	rows, err := r.db.QueryContext(ctx, query, banListID)
+
+	// Log the query execution
+	utils.LogDBQuery(
+		query,
+		[]interface{}{banListID},
+		time.Since(startTime),
+		err,
+	)
+
+	if err != nil {
+		return nil, fmt.Errorf("failed to get ban list word options: %w", err)
+	}
+	defer func() {
+		if closeErr := rows.Close(); closeErr != nil {
+			log.Error().Err(closeErr).Msg("failed to close rows")
+		}
+	}()
+
+	// Parse the results
+	options := make(map[string]models.BanListMatchOptions)
+	for rows.Next() {
+		var word string
+		var option models.BanListMatchOptions
+		if err := rows.Scan(&word, &option.CaseInsensitive, &option.DiacriticInsensitive, &option.WholeWord, &option.Stemming); err != nil {
+			return nil, fmt.Errorf("failed to scan ban list word options: %w", err)
+		}
+		options[word] = option
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating ban list word options: %w", err)
+	}
+
+	return options, nil
+}
+
+// SetWordOptions sets how words of a ban list are matched, in one statement.
+//
+// Parameters:
+//   - ctx: Context for transaction and cancellation control
+//   - banListID: The unique identifier of the ban list
+//   - words: The words whose options are set; words not in the ban list are ignored
+//   - options: How the words are matched
+//
+// Returns:
+//   - An error if the update fails
+func (r *PostgresBanListRepository) SetWordOptions(ctx context.Context, banListID int64, words []string, options models.BanListMatchOptions) error {
+	if len(words) == 0 {
+		return nil
+	}
+
+	// Start query timer
+	startTime := time.Now()
+
+	// Define the query
+	query := `
+        UPDATE ` + constants.TableBanListWords + `
+        SET ` + constants.ColumnCaseInsensitive + ` = $1, ` + constants.ColumnDiacriticInsensitive + ` = $2,
+            ` + constants.ColumnWholeWord + ` = $3, ` + constants.ColumnStemming + ` = $4
+        WHERE ` + constants.ColumnBanID + ` = $5 AND ` + constants.ColumnWord + ` = ANY($6)
+    `
+
+	// Execute the query
+	_, err := r.db.ExecContext(ctx, query,
+		options.CaseInsensitive, options.DiacriticInsensitive, options.WholeWord, options.Stemming,
+		banListID, pq.Array(words))
+
+	// Log the query execution
+	utils.LogDBQuery(
+		query,
+		[]interface{}{banListID, len(words)},
+		time.Since(startTime),
+		err,
+	)
+
+	if err != nil {
+		return fmt.Errorf("failed to set ban list word options: %w", err)
+	}
+
+	log.Info().
+		Int64(constants.ColumnBanID, banListID).
+		Int("word_count", len(words)).
+		Msg("Ban list word options set")
 
-	return exists, nil
+	return nil
 }
@@ -12,7 +12,9 @@ import (
 	"github.com/stretchr/testify/require"
 
 	"github.com/yasinhessnawi1/Hideme_Backend/internal/database"
+	"github.com/yasinhessnawi1/Hideme_Backend/internal/models"
 	"github.com/yasinhessnawi1/Hideme_Backend/internal/repository"
+	"github.com/yasinhessnawi1/Hideme_Backend/internal/utils"
 )
 
 // setupBanListRepositoryTest creates a new test database connection and mock
@@ -43,11 +45,11 @@ func TestBanListRepository_GetByID(t *testing.T) {
 	settingID := int64(100)
 
 	// Set up query result
-	rows := sqlmock.NewRows([]string{"ban_id", "setting_id"}).
-		AddRow(id, settingID)
+	rows := sqlmock.NewRows([]string{"ban_id", "setting_id", "case_insensitive", "diacritic_insensitive", "whole_word"}).
+		AddRow(id, settingID, true, false, true)
 
 	// Expected query with placeholder for the ID
-	mock.ExpectQuery("SELECT ban_id, setting_id FROM ban_lists WHERE ban_id = \\$1").
+	mock.ExpectQuery("SELECT ban_id, setting_id, case_insensitive, diacritic_insensitive, whole_word FROM ban_lists WHERE ban_id = \\$1").
 		WithArgs(id).
 		WillReturnRows(rows)
 
@@ -58,6 +60,7 @@ func TestBanListRepository_GetByID(t *testing.T) {
 	assert.NoError(t, err)
 	assert.Equal(t, id, result.ID)
 	assert.Equal(t, settingID, result.SettingID)
+	assert.Equal(t, models.BanListOptions{CaseInsensitive: true, WholeWord: true}, result.BanListOptions)
 	assert.NoError(t, mock.ExpectationsWereMet())
 }
 
@@ -70,7 +73,7 @@ func TestBanListRepository_GetByID_NotFound(t *testing.T) {
 	id := int64(999)
 
 	// Mock database response - no rows
-	mock.ExpectQuery("SELECT ban_id, setting_id FROM ban_lists WHERE ban_id = \\$1").
+	mock.ExpectQuery("SELECT ban_id, setting_id, case_insensitive, diacritic_insensitive, whole_word FROM ban_lists WHERE ban_id = \\$1").
 		WithArgs(id).
 		WillReturnError(sql.ErrNoRows)
 
@@ -92,7 +95,7 @@ func TestBanListRepository_GetByID_OtherError(t *testing.T) {
 	id := int64(1)
 
 	// Mock database error (not ErrNoRows)
-	mock.ExpectQuery("SELECT ban_id, setting_id FROM ban_lists WHERE ban_id = \\$1").
+	mock.ExpectQuery("SELECT ban_id, setting_id, case_insensitive, diacritic_insensitive, whole_word FROM ban_lists WHERE ban_id = \\$1").
 		WithArgs(id).
 		WillReturnError(errors.New("database connection error"))
 
@@ -116,11 +119,11 @@ func TestBanListRepository_GetBySettingID(t *testing.T) {
 	settingID := int64(100)
 
 	// Set up query result
-	rows := sqlmock.NewRows([]string{"ban_id", "setting_id"}).
-		AddRow(id, settingID)
+	rows := sqlmock.NewRows([]string{"ban_id", "setting_id", "case_insensitive", "diacritic_insensitive", "whole_word"}).
+		AddRow(id, settingID, true, false, true)
 
 	// Expected query with placeholder for the setting ID
-	mock.ExpectQuery("SELECT ban_id, setting_id FROM ban_lists WHERE setting_id = \\$1").
+	mock.ExpectQuery("SELECT ban_id, setting_id, case_insensitive, diacritic_insensitive, whole_word FROM ban_lists WHERE setting_id = \\$1").
 		WithArgs(settingID).
 		WillReturnRows(rows)
 
@@ -131,6 +134,7 @@ func TestBanListRepository_GetBySettingID(t *testing.T) {
 	assert.NoError(t, err)
 	assert.Equal(t, id, result.ID)
 	assert.Equal(t, settingID, result.SettingID)
+	assert.Equal(t, models.BanListOptions{CaseInsensitive: true, WholeWord: true}, result.BanListOptions)
 	assert.NoError(t, mock.ExpectationsWereMet())
 }
 
@@ -143,7 +147,7 @@ func TestBanListRepository_GetBySettingID_NotFound(t *testing.T) {
 	settingID := int64(999)
 
 	// Mock database response - no rows
-	mock.ExpectQuery("SELECT ban_id, setting_id FROM ban_lists WHERE setting_id = \\$1").
+	mock.ExpectQuery("SELECT ban_id, setting_id, case_insensitive, diacritic_insensitive, whole_word FROM ban_lists WHERE setting_id = \\$1").
 		WithArgs(settingID).
 		WillReturnError(sql.ErrNoRows)
 
@@ -165,7 +169,7 @@ func TestBanListRepository_GetBySettingID_OtherError(t *testing.T) {
 	settingID := int64(100)
 
 	// Mock database error (not ErrNoRows)
-	mock.ExpectQuery("SELECT ban_id, setting_id FROM ban_lists WHERE setting_id = \\$1").
+	mock.ExpectQuery("SELECT ban_id, setting_id, case_insensitive, diacritic_insensitive, whole_word FROM ban_lists WHERE setting_id = \\$1").
 		WithArgs(settingID).
 		WillReturnError(errors.New("database connection error"))
 
@@ -612,10 +616,10 @@ func TestBanListRepository_WordExists(t *testing.T) {
 	word := "exists"
 
 	// Set up query result
-	rows := sqlmock.NewRows([]string{"exists"}).AddRow(true)
+	rows := sqlmock.NewRows([]string{"word", "case_insensitive", "diacritic_insensitive"}).AddRow(word, false, false)
 
 	// Expected query with placeholders for ban list ID and word
-	mock.ExpectQuery("SELECT EXISTS").
+	mock.ExpectQuery("SELECT w.word, (.+) FROM ban_list_words w JOIN ban_lists l").
 		WithArgs(banListID, word).
 		WillReturnRows(rows)
 
@@ -637,11 +641,11 @@ func TestBanListRepository_WordExists_DoesNotExist(t *testing.T) {
 	banListID := int64(1)
 	word := "nonexistent"
 
-	// Set up query result
-	rows := sqlmock.NewRows([]string{"exists"}).AddRow(false)
+	// Set up query result; a word ignoring diacritics that differs otherwise doesn't match
+	rows := sqlmock.NewRows([]string{"word", "case_insensitive", "diacritic_insensitive"}).AddRow("existent", false, true)
 
 	// Expected query with placeholders for ban list ID and word
-	mock.ExpectQuery("SELECT EXISTS").
+	mock.ExpectQuery("SELECT w.word, (.+) FROM ban_list_words w JOIN ban_lists l").
 		WithArgs(banListID, word).
 		WillReturnRows(rows)
 
@@ -654,6 +658,51 @@ func TestBanListRepository_WordExists_DoesNotExist(t *testing.T) {
 	assert.NoError(t, mock.ExpectationsWereMet())
 }
 
+func TestBanListRepository_WordExists_ListOptions(t *testing.T) {
+	// Set up the test
+	repo, mock, cleanup := setupBanListRepositoryTest(t)
+	defer cleanup()
+
+	// The ban list ignores case and diacritics for all its words
+	rows := sqlmock.NewRows([]string{"word", "case_insensitive", "diacritic_insensitive"}).
+		AddRow("Oslo", true, true).
+		AddRow("Ström", true, true)
+
+	mock.ExpectQuery("SELECT w.word, (.+) FROM ban_list_words w JOIN ban_lists l").
+		WithArgs(int64(1), "STROM").
+		WillReturnRows(rows)
+
+	// Execute the method being tested
+	exists, err := repo.WordExists(context.Background(), 1, "STROM")
+
+	// Assert the results
+	assert.NoError(t, err)
+	assert.True(t, exists)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestBanListRepository_SetOptions(t *testing.T) {
+	// Set up the test
+	repo, mock, cleanup := setupBanListRepositoryTest(t)
+	defer cleanup()
+
+	options := models.BanListOptions{CaseInsensitive: true, WholeWord: true}
+	mock.ExpectExec("UPDATE ban_lists SET case_insensitive = \\$1, diacritic_insensitive = \\$2, whole_word = \\$3 WHERE ban_id = \\$4").
+		WithArgs(true, false, true, int64(1)).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectExec("UPDATE ban_lists").
+		WithArgs(true, false, true, int64(2)).
+		WillReturnResult(sqlmock.NewResult(0, 0))
+
+	// Execute the method being tested
+	assert.NoError(t, repo.SetOptions(context.Background(), 1, options))
+
+	// A ban list that doesn't exist isn't found
+	err := repo.SetOptions(context.Background(), 2, options)
+	assert.True(t, errors.Is(err, utils.ErrNotFound))
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
 func TestBanListRepository_WordExists_QueryError(t *testing.T) {
 	// Set up the test
 	repo, mock, cleanup := setupBanListRepositoryTest(t)
@@ -664,7 +713,7 @@ func TestBanListRepository_WordExists_QueryError(t *testing.T) {
 	word := "test"
 
 	// Mock database error
-	mock.ExpectQuery("SELECT EXISTS").
+	mock.ExpectQuery("SELECT w.word, (.+) FROM ban_list_words w JOIN ban_lists l").
 		WithArgs(banListID, word).
 		WillReturnError(errors.New("database error"))
 
@@ -677,3 +726,48 @@ func TestBanListRepository_WordExists_QueryError(t *testing.T) {
 	assert.Contains(t, err.Error(), "failed to check if word exists in ban list")
 	assert.NoError(t, mock.ExpectationsWereMet())
 }
+
+func TestBanListRepository_GetWordOptions(t *testing.T) {
+	// Set up the test
+	repo, mock, cleanup := setupBanListRepositoryTest(t)
+	defer cleanup()
+
+	// Only words matched other than exactly as written are returned
+	rows := sqlmock.NewRows([]string{"word", "case_insensitive", "diacritic_insensitive", "whole_word", "stemming"}).
+		AddRow("Ström", true, true, false, "").
+		AddRow("faktura", false, false, true, "no")
+
+	mock.ExpectQuery("SELECT word, case_insensitive, (.+) FROM ban_list_words WHERE ban_id = \\$1 AND \\(case_insensitive OR").
+		WithArgs(int64(1)).
+		WillReturnRows(rows)
+
+	// Execute the method being tested
+	options, err := repo.GetWordOptions(context.Background(), 1)
+
+	// Assert the results
+	require.NoError(t, err)
+	assert.Equal(t, map[string]models.BanListMatchOptions{
+		"Ström":   {CaseInsensitive: true, DiacriticInsensitive: true},
+		"faktura": {WholeWord: true, Stemming: models.StemmingNorwegian},
+	}, options)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestBanListRepository_SetWordOptions(t *testing.T) {
+	// Set up the test
+	repo, mock, cleanup := setupBanListRepositoryTest(t)
+	defer cleanup()
+
+	options := models.BanListMatchOptions{CaseInsensitive: true, WholeWord: true, Stemming: models.StemmingEnglish}
+	mock.ExpectExec("UPDATE ban_list_words SET case_insensitive = \\$1, (.+) WHERE ban_id = \\$5 AND word = ANY\\(\\$6\\)").
+		WithArgs(true, false, true, models.StemmingEnglish, int64(1), pq.Array([]string{"invoice", "receipt"})).
+		WillReturnResult(sqlmock.NewResult(0, 2))
+
+	// Execute the method being tested
+	err := repo.SetWordOptions(context.Background(), 1, []string{"invoice", "receipt"}, options)
+
+	// Assert the results
+	assert.NoError(t, err)
+	assert.NoError(t, repo.SetWordOptions(context.Background(), 1, nil, options))
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
@@ -0,0 +1,332 @@
+// Package repository provides data access interfaces and implementations for the HideMe application.
+// It follows the repository pattern to abstract database operations and provide a clean API
+// for data persistence operations.
+//
+// This file implements the benchmark repository, which stores the tenants that share their
+// statistics for the cross-tenant benchmarks and reads those statistics.
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/yasinhessnawi1/Hideme_Backend/internal/database"
+	"github.com/yasinhessnawi1/Hideme_Backend/internal/models"
+	"github.com/yasinhessnawi1/Hideme_Backend/internal/utils"
+)
+
+// BenchmarkRepository defines methods for managing benchmark consents and reading benchmark statistics.
+type BenchmarkRepository interface {
+	// SetConsent opts a tenant in to benchmarking, replacing its industry if it already consented.
+	//
+	// Parameters:
+	//   - ctx: Context for transaction and cancellation control
+	//   - consent: The consent to store
+	//
+	// Returns:
+	//   - An error if the consent could not be stored
+	SetConsent(ctx context.Context, consent *models.BenchmarkConsent) error
+
+	// GetConsent retrieves the benchmark consent of a tenant.
+	//
+	// Parameters:
+	//   - ctx: Context for transaction and cancellation control
+	//   - userID: The ID of the tenant
+	//
+	// Returns:
+	//   - The consent if found
+	//   - NotFoundError if the tenant has not opted in
+	//   - Other errors for database issues
+	GetConsent(ctx context.Context, userID int64) (*models.BenchmarkConsent, error)
+
+	// DeleteConsent opts a tenant out of benchmarking.
+	//
+	// Parameters:
+	//   - ctx: Context for transaction and cancellation control
+	//   - userID: The ID of the tenant
+	//
+	// Returns:
+	//   - NotFoundError if the tenant has not opted in
+	//   - Other errors for database issues
+	DeleteConsent(ctx context.Context, userID int64) error
+
+	// GetStats retrieves the statistics of all consenting tenants.
+	//
+	// Parameters:
+	//   - ctx: Context for transaction and cancellation control
+	//   - since: The start of the activity to count
+	//
+	// Returns:
+	//   - The statistics of each consenting tenant (empty if there are none)
+	//   - An error for database issues
+	GetStats(ctx context.Context, since time.Time) ([]*models.TenantBenchmarkStats, error)
+
+	// GetStatsByUserID retrieves the statistics of a single consenting tenant.
+	//
+	// Parameters:
+	//   - ctx: Context for transaction and cancellation control
+	//   - userID: The ID of the tenant
+	//   - since: The start of the activity to count
+	//
+	// Returns:
+	//   - The statistics of the tenant
+	//   - NotFoundError if the tenant has not opted in
+	//   - Other errors for database issues
+	GetStatsByUserID(ctx context.Context, userID int64, since time.Time) (*models.TenantBenchmarkStats, error)
+}
+
+// PostgresBenchmarkRepository is a PostgreSQL implementation of BenchmarkRepository.
+type PostgresBenchmarkRepository struct {
+	db *database.Pool
+}
+
+// NewBenchmarkRepository creates a new BenchmarkRepository implementation for PostgreSQL.
+//
+// Parameters:
+//   - db: A connection pool for PostgreSQL database access
+//
+// Returns:
+//   - An implementation of the BenchmarkRepository interface
+func NewBenchmarkRepository(db *database.Pool) BenchmarkRepository {
+	return &PostgresBenchmarkRepository{
+		db: db,
+	}
+}
+
+// benchmarkStatsQuery selects the entities detected and pages processed of consenting tenants
+// since $1; $2 is the first usage month of the window, since pages are only rolled up per month.
+const benchmarkStatsQuery = `
+        SELECT bc.user_id, bc.industry,
+               (SELECT COUNT(*) FROM detected_entities de
+                JOIN documents d ON d.document_id = de.document_id
+                WHERE d.user_id = bc.user_id AND de.detected_timestamp >= $1) AS entity_count,
+               (SELECT COALESCE(SUM(tu.page_count), 0) FROM tenant_usage tu
+                WHERE tu.user_id = bc.user_id AND tu.usage_month >= $2) AS page_count
+        FROM benchmark_consents bc
+`
+
+// scanTenantBenchmarkStats scans a single row of benchmarkStatsQuery.
+//
+// Parameters:
+//   - scanner: The row or rows to scan from
+//
+// Returns:
+//   - The scanned statistics
+//   - An error if scanning fails
+func scanTenantBenchmarkStats(scanner interface{ Scan(dest ...any) error }) (*models.TenantBenchmarkStats, error) {
+	stats := &models.TenantBenchmarkStats{}
+	err := scanner.Scan(
+		&stats.UserID,
+		&stats.Industry,
+		&stats.EntityCount,
+		&stats.PageCount,
+	)
+	return stats, err
+}
+
+// SetConsent opts a tenant in to benchmarking, replacing its industry if it already consented.
+//
+// Parameters:
+//   - ctx: Context for transaction and cancellation control
+//   - consent: The consent to store
+//
+// Returns:
+//   - An error if the consent could not be stored
+func (r *PostgresBenchmarkRepository) SetConsent(ctx context.Context, consent *models.BenchmarkConsent) error {
+	// Start query timer
+	startTime := time.Now()
+
+	// Define the query
+	query := `
+        INSERT INTO benchmark_consents (user_id, industry, consented_at)
+        VALUES ($1, $2, $3)
+        ON CONFLICT (user_id) DO UPDATE
+        SET industry = EXCLUDED.industry, consented_at = EXCLUDED.consented_at
+    `
+
+	// Execute the query
+	_, err := r.db.ExecContext(ctx, query, consent.UserID, consent.Industry, consent.ConsentedAt)
+
+	// Log the query execution
+	utils.LogDBQuery(
+		query,
+		[]interface{}{consent.UserID, consent.Industry, consent.ConsentedAt},
+		time.Since(startTime),
+		err,
+	)
+
+	if err != nil {
+		return fmt.Errorf("failed to set benchmark consent: %w", err)
+	}
+
+	return nil
+}
+
+// GetConsent retrieves the benchmark consent of a tenant.
+//
+// Parameters:
+//   - ctx: Context for transaction and cancellation control
+//   - userID: The ID of the tenant
+//
+// Returns:
+//   - The consent if found
+//   - NotFoundError if the tenant has not opted in
+//   - Other errors for database issues
+func (r *PostgresBenchmarkRepository) GetConsent(ctx context.Context, userID int64) (*models.BenchmarkConsent, error) {
+	// Start query timer
+	startTime := time.Now()
+
+	// Define the query
+	query := `SELECT user_id, industry, consented_at FROM benchmark_consents WHERE user_id = $1`
+
+	// Execute the query
+	consent := &models.BenchmarkConsent{}
+	err := r.db.QueryRowContext(ctx, query, userID).Scan(
+		&consent.UserID,
+		&consent.Industry,
+		&consent.ConsentedAt,
+	)
+
+	// Log the query execution
+	utils.LogDBQuery(
+		query,
+		[]interface{}{userID},
+		time.Since(startTime),
+		err,
+	)
+
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, utils.NewNotFoundError("BenchmarkConsent", userID).WithCause(err)
+		}
+		return nil, fmt.Errorf("failed to get benchmark consent: %w", err)
+	}
+
+	return consent, nil
+}
+
+// DeleteConsent opts a tenant out of benchmarking.
+//
+// Parameters:
+//   - ctx: Context for transaction and cancellation control
+//   - userID: The ID of the tenant
+//
+// Returns:
+//   - NotFoundError if the tenant has not opted in
+//   - Other errors for database issues
+func (r *PostgresBenchmarkRepository) DeleteConsent(ctx context.Context, userID int64) error {
+	// Start query timer
+	startTime := time.Now()
+
+	// Define the query
+	query := `DELETE FROM benchmark_consents WHERE user_id = $1`
+
+	// Execute the query
+	result, err := r.db.ExecContext(ctx, query, userID)
+
+	// Log the query execution
+	utils.LogDBQuery(
+		query,
+		[]interface{}{userID},
+		time.Since(startTime),
+		err,
+	)
+
+	if err != nil {
+		return fmt.Errorf("failed to delete benchmark consent: %w", err)
+	}
+
+	// Check if a consent was deleted
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("failed to get affected rows: %w", err)
+	}
+
+	if rowsAffected == 0 {
+		return utils.NewNotFoundError("BenchmarkConsent", userID)
+	}
+
+	return nil
+}
+
+// GetStats retrieves the statistics of all consenting tenants.
+//
+// Parameters:
+//   - ctx: Context for transaction and cancellation control
+//   - since: The start of the activity to count
+//
+// Returns:
+//   - The statistics of each consenting tenant (empty if there are none)
+//   - An error for database issues
+func (r *PostgresBenchmarkRepository) GetStats(ctx context.Context, since time.Time) ([]*models.TenantBenchmarkStats, error) {
+	// Start query timer
+	startTime := time.Now()
+
+	// Define the query
+	query := benchmarkStatsQuery + `ORDER BY bc.user_id`
+	args := []interface{}{since, models.UsageMonthOf(since)}
+
+	// Execute the query
+	rows, err := r.db.QueryContext(ctx, query, args...)
+
+	// Log the query execution
+	utils.LogDBQuery(query, args, time.Since(startTime), err)
+
+	if err != nil {
+		return nil, fmt.Errorf("failed to get benchmark statistics: %w", err)
+	}
+	defer rows.Close()
+
+	statsList := []*models.TenantBenchmarkStats{}
+	for rows.Next() {
+		stats, err := scanTenantBenchmarkStats(rows)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan benchmark statistics row: %w", err)
+		}
+		statsList = append(statsList, stats)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating benchmark statistics rows: %w", err)
+	}
+
+	return statsList, nil
+}
+
+// GetStatsByUserID retrieves the statistics of a single consenting tenant.
+//
+// Parameters:
+//   - ctx: Context for transaction and cancellation control
+//   - userID: The ID of the tenant
+//   - since: The start of the activity to count
+//
+// Returns:
+//   - The statistics of the tenant
+//   - NotFoundError if the tenant has not opted in
+//   - Other errors for database issues
+func (r *PostgresBenchmarkRepository) GetStatsByUserID(ctx context.Context, userID int64, since time.Time) (*models.TenantBenchmarkStats, error) {
+	// Start query timer
+	startTime := time.Now()
+
+	// Define the query
+	query := benchmarkStatsQuery + `WHERE bc.user_id = $3`
+	args := []interface{}{since, models.UsageMonthOf(since), userID}
+
+	// Execute the query
+	stats, err := scanTenantBenchmarkStats(r.db.QueryRowContext(ctx, query, args...))
+
+	// Log the query execution
+	utils.LogDBQuery(query, args, time.Since(startTime), err)
+
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, utils.NewNotFoundError("BenchmarkConsent", userID).WithCause(err)
+		}
+		return nil, fmt.Errorf("failed to get benchmark statistics: %w", err)
+	}
+
+	return stats, nil
+}
@@ -0,0 +1,219 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/yasinhessnawi1/Hideme_Backend/internal/models"
+	"github.com/yasinhessnawi1/Hideme_Backend/internal/utils"
+)
+
+var benchmarkStatsTestColumns = []string{"user_id", "industry", "entity_count", "page_count"}
+
+func TestNewBenchmarkRepository(t *testing.T) {
+	// Arrange
+	pool, _, cleanup := setupDBMock(t)
+	defer cleanup()
+
+	// Act
+	repo := NewBenchmarkRepository(pool)
+
+	// Assert
+	assert.NotNil(t, repo, "Repository should not be nil")
+	assert.Implements(t, (*BenchmarkRepository)(nil), repo, "Should implement BenchmarkRepository interface")
+}
+
+func TestBenchmarkRepository_SetConsent(t *testing.T) {
+	t.Run("Success", func(t *testing.T) {
+		// Arrange
+		pool, mock, cleanup := setupDBMock(t)
+		defer cleanup()
+		repo := NewBenchmarkRepository(pool)
+		consent := models.NewBenchmarkConsent(42, models.IndustryLegal)
+
+		mock.ExpectExec("INSERT INTO benchmark_consents (.+) ON CONFLICT \\(user_id\\) DO UPDATE").
+			WithArgs(int64(42), models.IndustryLegal, sqlmock.AnyArg()).
+			WillReturnResult(sqlmock.NewResult(0, 1))
+
+		// Act
+		err := repo.SetConsent(context.Background(), consent)
+
+		// Assert
+		assert.NoError(t, err)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("Database Error", func(t *testing.T) {
+		// Arrange
+		pool, mock, cleanup := setupDBMock(t)
+		defer cleanup()
+		repo := NewBenchmarkRepository(pool)
+
+		mock.ExpectExec("INSERT INTO benchmark_consents").
+			WillReturnError(errors.New("database error"))
+
+		// Act
+		err := repo.SetConsent(context.Background(), models.NewBenchmarkConsent(42, models.IndustryLegal))
+
+		// Assert
+		assert.Error(t, err)
+		assert.Contains(t, err.Error(), "failed to set benchmark consent")
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+}
+
+func TestBenchmarkRepository_GetConsent(t *testing.T) {
+	t.Run("Success", func(t *testing.T) {
+		// Arrange
+		pool, mock, cleanup := setupDBMock(t)
+		defer cleanup()
+		repo := NewBenchmarkRepository(pool)
+
+		rows := sqlmock.NewRows([]string{"user_id", "industry", "consented_at"}).
+			AddRow(42, "finance", time.Now())
+		mock.ExpectQuery("SELECT (.+) FROM benchmark_consents WHERE user_id = \\$1").
+			WithArgs(int64(42)).
+			WillReturnRows(rows)
+
+		// Act
+		consent, err := repo.GetConsent(context.Background(), 42)
+
+		// Assert
+		require.NoError(t, err)
+		assert.Equal(t, int64(42), consent.UserID)
+		assert.Equal(t, models.IndustryFinance, consent.Industry)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("Not Found", func(t *testing.T) {
+		// Arrange
+		pool, mock, cleanup := setupDBMock(t)
+		defer cleanup()
+		repo := NewBenchmarkRepository(pool)
+
+		mock.ExpectQuery("SELECT (.+) FROM benchmark_consents").
+			WithArgs(int64(99)).
+			WillReturnRows(sqlmock.NewRows([]string{"user_id", "industry", "consented_at"}))
+
+		// Act
+		consent, err := repo.GetConsent(context.Background(), 99)
+
+		// Assert
+		assert.Nil(t, consent)
+		assert.True(t, errors.Is(err, utils.ErrNotFound))
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+}
+
+func TestBenchmarkRepository_DeleteConsent(t *testing.T) {
+	t.Run("Success", func(t *testing.T) {
+		// Arrange
+		pool, mock, cleanup := setupDBMock(t)
+		defer cleanup()
+		repo := NewBenchmarkRepository(pool)
+
+		mock.ExpectExec("DELETE FROM benchmark_consents WHERE user_id = \\$1").
+			WithArgs(int64(42)).
+			WillReturnResult(sqlmock.NewResult(0, 1))
+
+		// Act
+		err := repo.DeleteConsent(context.Background(), 42)
+
+		// Assert
+		assert.NoError(t, err)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("Not Found", func(t *testing.T) {
+		// Arrange
+		pool, mock, cleanup := setupDBMock(t)
+		defer cleanup()
+		repo := NewBenchmarkRepository(pool)
+
+		mock.ExpectExec("DELETE FROM benchmark_consents").
+			WithArgs(int64(99)).
+			WillReturnResult(sqlmock.NewResult(0, 0))
+
+		// Act
+		err := repo.DeleteConsent(context.Background(), 99)
+
+		// Assert
+		assert.True(t, errors.Is(err, utils.ErrNotFound))
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+}
+
+func TestBenchmarkRepository_GetStats(t *testing.T) {
+	// Arrange
+	since := time.Date(2026, 7, 18, 0, 0, 0, 0, time.UTC)
+	pool, mock, cleanup := setupDBMock(t)
+	defer cleanup()
+	repo := NewBenchmarkRepository(pool)
+
+	rows := sqlmock.NewRows(benchmarkStatsTestColumns).
+		AddRow(1, "legal", 120, 40).
+		AddRow(2, "finance", 0, 0)
+	mock.ExpectQuery("SELECT (.+) FROM benchmark_consents bc ORDER BY bc.user_id").
+		WithArgs(since, time.Date(2026, 7, 1, 0, 0, 0, 0, time.UTC)).
+		WillReturnRows(rows)
+
+	// Act
+	stats, err := repo.GetStats(context.Background(), since)
+
+	// Assert
+	require.NoError(t, err)
+	require.Len(t, stats, 2)
+	assert.Equal(t, models.IndustryLegal, stats[0].Industry)
+	assert.Equal(t, int64(120), stats[0].EntityCount)
+	assert.Equal(t, int64(40), stats[0].PageCount)
+	assert.Equal(t, int64(0), stats[1].PageCount)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestBenchmarkRepository_GetStatsByUserID(t *testing.T) {
+	since := time.Date(2026, 7, 18, 0, 0, 0, 0, time.UTC)
+
+	t.Run("Success", func(t *testing.T) {
+		// Arrange
+		pool, mock, cleanup := setupDBMock(t)
+		defer cleanup()
+		repo := NewBenchmarkRepository(pool)
+
+		rows := sqlmock.NewRows(benchmarkStatsTestColumns).AddRow(42, "legal", 90, 30)
+		mock.ExpectQuery("SELECT (.+) FROM benchmark_consents bc WHERE bc.user_id = \\$3").
+			WithArgs(since, time.Date(2026, 7, 1, 0, 0, 0, 0, time.UTC), int64(42)).
+			WillReturnRows(rows)
+
+		// Act
+		stats, err := repo.GetStatsByUserID(context.Background(), 42, since)
+
+		// Assert
+		require.NoError(t, err)
+		assert.Equal(t, 3.0, stats.EntitiesPerPage())
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("Not Consented", func(t *testing.T) {
+		// Arrange
+		pool, mock, cleanup := setupDBMock(t)
+		defer cleanup()
+		repo := NewBenchmarkRepository(pool)
+
+		mock.ExpectQuery("SELECT (.+) FROM benchmark_consents bc").
+			WillReturnRows(sqlmock.NewRows(benchmarkStatsTestColumns))
+
+		// Act
+		stats, err := repo.GetStatsByUserID(context.Background(), 99, since)
+
+		// Assert
+		assert.Nil(t, stats)
+		assert.True(t, errors.Is(err, utils.ErrNotFound))
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+}
@@ -0,0 +1,372 @@
+// Package repository provides data access interfaces and implementations for the HideMe application.
+// It follows the repository pattern to abstract database operations and provide a clean API
+// for data persistence operations.
+//
+// This file implements the classification rule repository, which manages the rules that
+// automatically tag, file and set the retention of documents when they are uploaded.
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/lib/pq"
+	"github.com/rs/zerolog/log"
+
+	"github.com/yasinhessnawi1/Hideme_Backend/internal/constants"
+	"github.com/yasinhessnawi1/Hideme_Backend/internal/database"
+	"github.com/yasinhessnawi1/Hideme_Backend/internal/models"
+	"github.com/yasinhessnawi1/Hideme_Backend/internal/utils"
+)
+
+// ClassificationRuleRepository defines methods for interacting with classification rules in the database.
+type ClassificationRuleRepository interface {
+	// Create adds a new classification rule to the database.
+	//
+	// Parameters:
+	//   - ctx: Context for transaction and cancellation control
+	//   - rule: The rule to store, with required fields populated
+	//
+	// Returns:
+	//   - An error if creation fails
+	//
+	// The rule ID will be populated after successful creation.
+	Create(ctx context.Context, rule *models.ClassificationRule) error
+
+	// GetByID retrieves a classification rule by its unique identifier.
+	//
+	// Parameters:
+	//   - ctx: Context for transaction and cancellation control
+	//   - id: The unique identifier of the rule
+	//
+	// Returns:
+	//   - The rule if found
+	//   - NotFoundError if the rule doesn't exist
+	//   - Other errors for database issues
+	GetByID(ctx context.Context, id int64) (*models.ClassificationRule, error)
+
+	// GetBySettingID retrieves all classification rules for a specific user settings
+	// in the order they are applied.
+	//
+	// Parameters:
+	//   - ctx: Context for transaction and cancellation control
+	//   - settingID: The unique identifier of the user settings
+	//
+	// Returns:
+	//   - The rules ordered by priority, then by ID
+	//   - An empty slice if no rules exist
+	//   - An error if retrieval fails
+	GetBySettingID(ctx context.Context, settingID int64) ([]*models.ClassificationRule, error)
+
+	// Update replaces the conditions and actions of a classification rule.
+	//
+	// Parameters:
+	//   - ctx: Context for transaction and cancellation control
+	//   - rule: The rule to update
+	//
+	// Returns:
+	//   - NotFoundError if the rule doesn't exist
+	//   - Other errors for database issues
+	Update(ctx context.Context, rule *models.ClassificationRule) error
+
+	// Delete removes a classification rule from the database.
+	//
+	// Parameters:
+	//   - ctx: Context for transaction and cancellation control
+	//   - id: The unique identifier of the rule to delete
+	//
+	// Returns:
+	//   - NotFoundError if the rule doesn't exist
+	//   - Other errors for database issues
+	Delete(ctx context.Context, id int64) error
+}
+
+// PostgresClassificationRuleRepository is a PostgreSQL implementation of ClassificationRuleRepository.
+type PostgresClassificationRuleRepository struct {
+	db *database.Pool
+}
+
+// NewClassificationRuleRepository creates a new ClassificationRuleRepository implementation for PostgreSQL.
+//
+// Parameters:
+//   - db: A connection pool for PostgreSQL database access
+//
+// Returns:
+//   - An implementation of the ClassificationRuleRepository interface
+func NewClassificationRuleRepository(db *database.Pool) ClassificationRuleRepository {
+	return &PostgresClassificationRuleRepository{
+		db: db,
+	}
+}
+
+// classificationRuleColumns are the columns selected for a classification rule, in scan order.
+const classificationRuleColumns = `rule_id, setting_id, name, priority, filename_pattern, source, entity_types, min_entities, tags, folder, retention_days`
+
+// scanClassificationRule scans a row of classificationRuleColumns into a rule.
+func scanClassificationRule(row interface{ Scan(...any) error }) (*models.ClassificationRule, error) {
+	rule := &models.ClassificationRule{}
+	err := row.Scan(
+		&rule.ID,
+		&rule.SettingID,
+		&rule.Name,
+		&rule.Priority,
+		&rule.FilenamePattern,
+		&rule.Source,
+		pq.Array(&rule.EntityTypes),
+		&rule.MinEntities,
+		pq.Array(&rule.Tags),
+		&rule.Folder,
+		&rule.RetentionDays,
+	)
+	return rule, err
+}
+
+// Create adds a new classification rule to the database.
+//
+// Parameters:
+//   - ctx: Context for transaction and cancellation control
+//   - rule: The rule to store
+//
+// Returns:
+//   - An error if creation fails
+//
+// The rule ID will be populated after successful creation.
+func (r *PostgresClassificationRuleRepository) Create(ctx context.Context, rule *models.ClassificationRule) error {
+	// Start query timer
+	startTime := time.Now()
+
+	// Define the query with RETURNING for PostgreSQL
+	query := `
+		INSERT INTO ` + constants.TableClassificationRules + ` (setting_id, name, priority, filename_pattern, source, entity_types, min_entities, tags, folder, retention_days)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10)
+		RETURNING ` + constants.ColumnRuleID + `
+	`
+
+	args := []interface{}{
+		rule.SettingID,
+		rule.Name,
+		rule.Priority,
+		rule.FilenamePattern,
+		rule.Source,
+		pq.Array(rule.EntityTypes),
+		rule.MinEntities,
+		pq.Array(rule.Tags),
+		rule.Folder,
+		rule.RetentionDays,
+	}
+
+	// Execute the query
+	err := r.db.QueryRowContext(ctx, query, args...).Scan(&rule.ID)
+
+	// Log the query execution
+	utils.LogDBQuery(query, args, time.Since(startTime), err)
+
+	if err != nil {
+		return fmt.Errorf("failed to create classification rule: %w", err)
+	}
+
+	log.Info().
+		Int64(constants.ColumnRuleID, rule.ID).
+		Int64(constants.ColumnSettingID, rule.SettingID).
+		Msg("Classification rule created")
+
+	return nil
+}
+
+// GetByID retrieves a classification rule by ID.
+//
+// Parameters:
+//   - ctx: Context for transaction and cancellation control
+//   - id: The unique identifier of the rule
+//
+// Returns:
+//   - The rule if found
+//   - NotFoundError if the rule doesn't exist
+//   - Other errors for database issues
+func (r *PostgresClassificationRuleRepository) GetByID(ctx context.Context, id int64) (*models.ClassificationRule, error) {
+	// Start query timer
+	startTime := time.Now()
+
+	// Define the query
+	query := `
+		SELECT ` + classificationRuleColumns + `
+		FROM ` + constants.TableClassificationRules + `
+		WHERE ` + constants.ColumnRuleID + ` = $1
+	`
+
+	// Execute the query
+	rule, err := scanClassificationRule(r.db.QueryRowContext(ctx, query, id))
+
+	// Log the query execution
+	utils.LogDBQuery(query, []interface{}{id}, time.Since(startTime), err)
+
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, utils.NewNotFoundError("ClassificationRule", id).WithCause(err)
+		}
+		return nil, fmt.Errorf("failed to get classification rule by ID: %w", err)
+	}
+
+	return rule, nil
+}
+
+// GetBySettingID retrieves all classification rules for a setting in the order they are applied.
+//
+// Parameters:
+//   - ctx: Context for transaction and cancellation control
+//   - settingID: The unique identifier of the user settings
+//
+// Returns:
+//   - The rules ordered by priority, then by ID
+//   - An empty slice if no rules exist
+//   - An error if retrieval fails
+func (r *PostgresClassificationRuleRepository) GetBySettingID(ctx context.Context, settingID int64) ([]*models.ClassificationRule, error) {
+	// Start query timer
+	startTime := time.Now()
+
+	// Define the query
+	query := `
+		SELECT ` + classificationRuleColumns + `
+		FROM ` + constants.TableClassificationRules + `
+		WHERE setting_id = $1
+		ORDER BY priority, ` + constants.ColumnRuleID + `
+	`
+
+	// Execute the query
+	rows, err := r.db.QueryContext(ctx, query, settingID)
+
+	// Log the query execution
+	utils.LogDBQuery(query, []interface{}{settingID}, time.Since(startTime), err)
+
+	if err != nil {
+		return nil, fmt.Errorf("failed to get classification rules by setting ID: %w", err)
+	}
+	defer func() {
+		if closeErr := rows.Close(); closeErr != nil {
+			log.Error().Err(closeErr).Msg("failed to close rows")
+		}
+	}()
+
+	// Parse the results
+	rules := []*models.ClassificationRule{}
+	for rows.Next() {
+		rule, err := scanClassificationRule(rows)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan classification rule row: %w", err)
+		}
+		rules = append(rules, rule)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating classification rule rows: %w", err)
+	}
+
+	return rules, nil
+}
+
+// Update replaces the conditions and actions of a classification rule.
+//
+// Parameters:
+//   - ctx: Context for transaction and cancellation control
+//   - rule: The rule to update
+//
+// Returns:
+//   - NotFoundError if the rule doesn't exist
+//   - Other errors for database issues
+func (r *PostgresClassificationRuleRepository) Update(ctx context.Context, rule *models.ClassificationRule) error {
+	// Start query timer
+	startTime := time.Now()
+
+	// Define the query
+	query := `
+		UPDATE ` + constants.TableClassificationRules + `
+		SET name = $1, priority = $2, filename_pattern = $3, source = $4, entity_types = $5,
+		    min_entities = $6, tags = $7, folder = $8, retention_days = $9
+		WHERE ` + constants.ColumnRuleID + ` = $10
+	`
+
+	args := []interface{}{
+		rule.Name,
+		rule.Priority,
+		rule.FilenamePattern,
+		rule.Source,
+		pq.Array(rule.EntityTypes),
+		rule.MinEntities,
+		pq.Array(rule.Tags),
+		rule.Folder,
+		rule.RetentionDays,
+		rule.ID,
+	}
+
+	// Execute the query
+	result, err := r.db.ExecContext(ctx, query, args...)
+
+	// Log the query execution
+	utils.LogDBQuery(query, args, time.Since(startTime), err)
+
+	if err != nil {
+		return fmt.Errorf("failed to update classification rule: %w", err)
+	}
+
+	// Check if any rows were affected
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("failed to get rows affected: %w", err)
+	}
+
+	if rowsAffected == 0 {
+		return utils.NewNotFoundError("ClassificationRule", rule.ID)
+	}
+
+	log.Info().
+		Int64(constants.ColumnRuleID, rule.ID).
+		Msg("Classification rule updated")
+
+	return nil
+}
+
+// Delete removes a classification rule from the database.
+//
+// Parameters:
+//   - ctx: Context for transaction and cancellation control
+//   - id: The unique identifier of the rule to delete
+//
+// Returns:
+//   - NotFoundError if the rule doesn't exist
+//   - Other errors for database issues
+func (r *PostgresClassificationRuleRepository) Delete(ctx context.Context, id int64) error {
+	// Start query timer
+	startTime := time.Now()
+
+	// Define the query
+	query := `DELETE FROM ` + constants.TableClassificationRules + ` WHERE ` + constants.ColumnRuleID + ` = $1`
+
+	// Execute the query
+	result, err := r.db.ExecContext(ctx, query, id)
+
+	// Log the query execution
+	utils.LogDBQuery(query, []interface{}{id}, time.Since(startTime), err)
+
+	if err != nil {
+		return fmt.Errorf("failed to delete classification rule: %w", err)
+	}
+
+	// Check if any rows were affected
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("failed to get rows affected: %w", err)
+	}
+
+	if rowsAffected == 0 {
+		return utils.NewNotFoundError("ClassificationRule", id)
+	}
+
+	log.Info().
+		Int64(constants.ColumnRuleID, id).
+		Msg("Classification rule deleted")
+
+	return nil
+}
@@ -0,0 +1,191 @@
+package repository_test
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"testing"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/yasinhessnawi1/Hideme_Backend/internal/database"
+	"github.com/yasinhessnawi1/Hideme_Backend/internal/models"
+	"github.com/yasinhessnawi1/Hideme_Backend/internal/repository"
+	"github.com/yasinhessnawi1/Hideme_Backend/internal/utils"
+)
+
+// setupClassificationRuleRepositoryTest creates a new test database connection and mock
+func setupClassificationRuleRepositoryTest(t *testing.T) (repository.ClassificationRuleRepository, sqlmock.Sqlmock, func()) {
+	db, mock, err := sqlmock.New()
+	require.NoError(t, err)
+
+	repo := repository.NewClassificationRuleRepository(&database.Pool{DB: db})
+
+	return repo, mock, func() {
+		db.Close()
+	}
+}
+
+var classificationRuleRowColumns = []string{
+	"rule_id", "setting_id", "name", "priority", "filename_pattern", "source",
+	"entity_types", "min_entities", "tags", "folder", "retention_days",
+}
+
+func TestClassificationRuleRepository_Create(t *testing.T) {
+	repo, mock, cleanup := setupClassificationRuleRepositoryTest(t)
+	defer cleanup()
+
+	rule := &models.ClassificationRule{
+		SettingID:       1,
+		Name:            "Invoices",
+		Priority:        10,
+		FilenamePattern: "invoice*.pdf",
+		EntityTypes:     []string{},
+		Tags:            []string{"finance"},
+		Folder:          "Invoices",
+		RetentionDays:   365,
+	}
+
+	t.Run("Success", func(t *testing.T) {
+		mock.ExpectQuery("INSERT INTO classification_rules").
+			WithArgs(rule.SettingID, rule.Name, rule.Priority, rule.FilenamePattern, rule.Source,
+				sqlmock.AnyArg(), rule.MinEntities, sqlmock.AnyArg(), rule.Folder, rule.RetentionDays).
+			WillReturnRows(sqlmock.NewRows([]string{"rule_id"}).AddRow(5))
+
+		err := repo.Create(context.Background(), rule)
+
+		require.NoError(t, err)
+		assert.Equal(t, int64(5), rule.ID)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("Database error", func(t *testing.T) {
+		mock.ExpectQuery("INSERT INTO classification_rules").
+			WillReturnError(errors.New("database error"))
+
+		err := repo.Create(context.Background(), rule)
+
+		assert.Error(t, err)
+		assert.Contains(t, err.Error(), "failed to create classification rule")
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+}
+
+func TestClassificationRuleRepository_GetByID(t *testing.T) {
+	repo, mock, cleanup := setupClassificationRuleRepositoryTest(t)
+	defer cleanup()
+
+	t.Run("Success", func(t *testing.T) {
+		rows := sqlmock.NewRows(classificationRuleRowColumns).
+			AddRow(5, 1, "Invoices", 10, "invoice*.pdf", "", "{}", 0, "{finance,billing}", "Invoices", 365)
+		mock.ExpectQuery("SELECT (.+) FROM classification_rules WHERE rule_id = \\$1").
+			WithArgs(int64(5)).
+			WillReturnRows(rows)
+
+		rule, err := repo.GetByID(context.Background(), 5)
+
+		require.NoError(t, err)
+		assert.Equal(t, int64(1), rule.SettingID)
+		assert.Equal(t, "invoice*.pdf", rule.FilenamePattern)
+		assert.Equal(t, []string{"finance", "billing"}, rule.Tags)
+		assert.Equal(t, 365, rule.RetentionDays)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("Not found", func(t *testing.T) {
+		mock.ExpectQuery("SELECT (.+) FROM classification_rules WHERE rule_id = \\$1").
+			WithArgs(int64(6)).
+			WillReturnError(sql.ErrNoRows)
+
+		rule, err := repo.GetByID(context.Background(), 6)
+
+		assert.Nil(t, rule)
+		assert.True(t, utils.IsNotFoundError(err))
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+}
+
+func TestClassificationRuleRepository_GetBySettingID(t *testing.T) {
+	repo, mock, cleanup := setupClassificationRuleRepositoryTest(t)
+	defer cleanup()
+
+	t.Run("Success", func(t *testing.T) {
+		rows := sqlmock.NewRows(classificationRuleRowColumns).
+			AddRow(5, 1, "Invoices", 10, "invoice*.pdf", "", "{}", 0, "{finance}", "Invoices", 0).
+			AddRow(7, 1, "Medical", 20, "", "", "{HEALTH}", 2, "{medical}", "", 30)
+		mock.ExpectQuery("SELECT (.+) FROM classification_rules WHERE setting_id = \\$1 ORDER BY priority").
+			WithArgs(int64(1)).
+			WillReturnRows(rows)
+
+		rules, err := repo.GetBySettingID(context.Background(), 1)
+
+		require.NoError(t, err)
+		require.Len(t, rules, 2)
+		assert.Equal(t, []string{"HEALTH"}, rules[1].EntityTypes)
+		assert.Equal(t, 2, rules[1].MinEntities)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("Database error", func(t *testing.T) {
+		mock.ExpectQuery("SELECT (.+) FROM classification_rules").
+			WillReturnError(errors.New("database error"))
+
+		rules, err := repo.GetBySettingID(context.Background(), 1)
+
+		assert.Nil(t, rules)
+		assert.Error(t, err)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+}
+
+func TestClassificationRuleRepository_Update(t *testing.T) {
+	repo, mock, cleanup := setupClassificationRuleRepositoryTest(t)
+	defer cleanup()
+
+	rule := &models.ClassificationRule{ID: 5, SettingID: 1, Name: "Invoices", Tags: []string{"finance"}}
+
+	t.Run("Success", func(t *testing.T) {
+		mock.ExpectExec("UPDATE classification_rules SET (.+) WHERE rule_id = \\$10").
+			WillReturnResult(sqlmock.NewResult(0, 1))
+
+		err := repo.Update(context.Background(), rule)
+
+		assert.NoError(t, err)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("Not found", func(t *testing.T) {
+		mock.ExpectExec("UPDATE classification_rules").
+			WillReturnResult(sqlmock.NewResult(0, 0))
+
+		err := repo.Update(context.Background(), rule)
+
+		assert.True(t, utils.IsNotFoundError(err))
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+}
+
+func TestClassificationRuleRepository_Delete(t *testing.T) {
+	repo, mock, cleanup := setupClassificationRuleRepositoryTest(t)
+	defer cleanup()
+
+	t.Run("Success", func(t *testing.T) {
+		mock.ExpectExec("DELETE FROM classification_rules WHERE rule_id = \\$1").
+			WithArgs(int64(5)).
+			WillReturnResult(sqlmock.NewResult(0, 1))
+
+		assert.NoError(t, repo.Delete(context.Background(), 5))
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("Not found", func(t *testing.T) {
+		mock.ExpectExec("DELETE FROM classification_rules WHERE rule_id = \\$1").
+			WithArgs(int64(6)).
+			WillReturnResult(sqlmock.NewResult(0, 0))
+
+		assert.True(t, utils.IsNotFoundError(repo.Delete(context.Background(), 6)))
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+}
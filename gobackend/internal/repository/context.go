@@ -0,0 +1,29 @@
+// Package repository provides data access interfaces and implementations for the HideMe application.
+// This file contains helpers that let long-running repository loops observe cancellation
+// of the caller's context.
+//
+// Queries started with QueryContext are already aborted by the driver when the context is
+// canceled, but rows that have been buffered keep being scanned and handed on. Checking the
+// context between rows stops a stream for a client that has gone away instead of letting
+// it run to completion.
+package repository
+
+import (
+	"context"
+)
+
+// checkContext reports whether the caller has gone away.
+//
+// Parameters:
+//   - ctx: The context of the caller
+//
+// Returns:
+//   - The context's error once it is canceled or past its deadline, nil otherwise
+func checkContext(ctx context.Context) error {
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	default:
+		return nil
+	}
+}
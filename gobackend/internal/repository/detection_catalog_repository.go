@@ -0,0 +1,220 @@
+// Package repository provides data access interfaces and implementations for the HideMe application.
+// It follows the repository pattern to abstract database operations and provide a clean API
+// for data persistence operations.
+//
+// This file implements the detection catalog repository, which reads and imports the
+// detection methods and the entity-type taxonomy as a whole.
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"time"
+
+	"github.com/yasinhessnawi1/Hideme_Backend/internal/constants"
+	"github.com/yasinhessnawi1/Hideme_Backend/internal/database"
+	"github.com/yasinhessnawi1/Hideme_Backend/internal/models"
+	"github.com/yasinhessnawi1/Hideme_Backend/internal/utils"
+)
+
+// DetectionCatalogRepository defines methods for reading and importing the detection methods
+// and the entity-type taxonomy.
+type DetectionCatalogRepository interface {
+	// ListDetectionMethods retrieves all detection methods by name.
+	//
+	// Parameters:
+	//   - ctx: Context for transaction and cancellation control
+	//
+	// Returns:
+	//   - The detection methods (empty if there are none)
+	//   - An error for database issues
+	ListDetectionMethods(ctx context.Context) ([]*models.DetectionMethod, error)
+
+	// ListEntityTypes retrieves all entity types by name, with the names of their detection methods.
+	//
+	// Parameters:
+	//   - ctx: Context for transaction and cancellation control
+	//
+	// Returns:
+	//   - The entity types (empty if there are none)
+	//   - An error for database issues
+	ListEntityTypes(ctx context.Context) ([]*models.EntityType, error)
+
+	// Import creates or updates the detection methods and entity types of a bundle, matched
+	// by name, in one transaction. Methods and entity types that are not in the bundle are kept.
+	//
+	// Parameters:
+	//   - ctx: Context for transaction and cancellation control
+	//   - bundle: The detection methods and entity types to import
+	//
+	// Returns:
+	//   - NotFoundError if an entity type references a detection method that doesn't exist
+	//   - Other errors for database issues; nothing is imported then
+	Import(ctx context.Context, bundle *models.DetectionCatalogBundle) error
+}
+
+// PostgresDetectionCatalogRepository is a PostgreSQL implementation of DetectionCatalogRepository.
+type PostgresDetectionCatalogRepository struct {
+	db *database.Pool
+}
+
+// NewDetectionCatalogRepository creates a new DetectionCatalogRepository implementation for PostgreSQL.
+//
+// Parameters:
+//   - db: A connection pool for PostgreSQL database access
+//
+// Returns:
+//   - An implementation of the DetectionCatalogRepository interface
+func NewDetectionCatalogRepository(db *database.Pool) DetectionCatalogRepository {
+	return &PostgresDetectionCatalogRepository{
+		db: db,
+	}
+}
+
+// ListDetectionMethods retrieves all detection methods by name.
+func (r *PostgresDetectionCatalogRepository) ListDetectionMethods(ctx context.Context) ([]*models.DetectionMethod, error) {
+	// Start query timer
+	startTime := time.Now()
+
+	// Define the query
+	query := `
+        SELECT ` + constants.ColumnMethodID + `, method_name, highlight_color
+        FROM ` + constants.TableDetectionMethods + `
+        ORDER BY method_name
+    `
+
+	// Execute the query
+	rows, err := r.db.QueryContext(ctx, query)
+
+	// Log the query execution
+	utils.LogDBQuery(
+		query,
+		nil,
+		time.Since(startTime),
+		err,
+	)
+
+	if err != nil {
+		return nil, fmt.Errorf("failed to list detection methods: %w", err)
+	}
+	defer rows.Close()
+
+	methods := []*models.DetectionMethod{}
+	for rows.Next() {
+		method := &models.DetectionMethod{}
+		if err := rows.Scan(&method.ID, &method.MethodName, &method.HighlightColor); err != nil {
+			return nil, fmt.Errorf("failed to scan detection method row: %w", err)
+		}
+		methods = append(methods, method)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating detection method rows: %w", err)
+	}
+
+	return methods, nil
+}
+
+// ListEntityTypes retrieves all entity types by name, with the names of their detection methods.
+func (r *PostgresDetectionCatalogRepository) ListEntityTypes(ctx context.Context) ([]*models.EntityType, error) {
+	// Start query timer
+	startTime := time.Now()
+
+	// Define the query
+	query := `
+        SELECT e.entity_type_id, e.entity_type, e.` + constants.ColumnMethodID + `, dm.method_name, e.description
+        FROM ` + constants.TableEntityTypes + ` e
+        JOIN ` + constants.TableDetectionMethods + ` dm ON dm.` + constants.ColumnMethodID + ` = e.` + constants.ColumnMethodID + `
+        ORDER BY e.entity_type
+    `
+
+	// Execute the query
+	rows, err := r.db.QueryContext(ctx, query)
+
+	// Log the query execution
+	utils.LogDBQuery(
+		query,
+		nil,
+		time.Since(startTime),
+		err,
+	)
+
+	if err != nil {
+		return nil, fmt.Errorf("failed to list entity types: %w", err)
+	}
+	defer rows.Close()
+
+	entityTypes := []*models.EntityType{}
+	for rows.Next() {
+		entityType := &models.EntityType{}
+		if err := rows.Scan(&entityType.ID, &entityType.EntityType, &entityType.MethodID, &entityType.MethodName, &entityType.Description); err != nil {
+			return nil, fmt.Errorf("failed to scan entity type row: %w", err)
+		}
+		entityTypes = append(entityTypes, entityType)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating entity type rows: %w", err)
+	}
+
+	return entityTypes, nil
+}
+
+// Import creates or updates the detection methods and entity types of a bundle in one transaction.
+func (r *PostgresDetectionCatalogRepository) Import(ctx context.Context, bundle *models.DetectionCatalogBundle) error {
+	// Start query timer
+	startTime := time.Now()
+
+	// The methods go first, so the entity types of the bundle can reference new ones
+	methodQuery := `
+        INSERT INTO ` + constants.TableDetectionMethods + ` (method_name, highlight_color)
+        VALUES ($1, $2)
+        ON CONFLICT (method_name) DO UPDATE SET
+            highlight_color = EXCLUDED.highlight_color
+    `
+	entityTypeQuery := `
+        INSERT INTO ` + constants.TableEntityTypes + ` (entity_type, ` + constants.ColumnMethodID + `, description)
+        SELECT $1, ` + constants.ColumnMethodID + `, $3
+        FROM ` + constants.TableDetectionMethods + `
+        WHERE method_name = $2
+        ON CONFLICT (entity_type) DO UPDATE SET
+            ` + constants.ColumnMethodID + ` = EXCLUDED.` + constants.ColumnMethodID + `,
+            description = EXCLUDED.description
+    `
+
+	err := r.db.Transaction(ctx, func(tx *sql.Tx) error {
+		for _, method := range bundle.DetectionMethods {
+			if _, err := tx.ExecContext(ctx, methodQuery, method.MethodName, method.HighlightColor); err != nil {
+				return fmt.Errorf("failed to import detection method %s: %w", method.MethodName, err)
+			}
+		}
+
+		for _, entityType := range bundle.EntityTypes {
+			result, err := tx.ExecContext(ctx, entityTypeQuery, entityType.EntityType, entityType.MethodName, entityType.Description)
+			if err != nil {
+				return fmt.Errorf("failed to import entity type %s: %w", entityType.EntityType, err)
+			}
+
+			rowsAffected, err := result.RowsAffected()
+			if err != nil {
+				return fmt.Errorf("failed to get affected rows: %w", err)
+			}
+			if rowsAffected == 0 {
+				return utils.NewNotFoundError("DetectionMethod", entityType.MethodName)
+			}
+		}
+
+		return nil
+	})
+
+	// Log the import as a whole
+	utils.LogDBQuery(
+		entityTypeQuery,
+		[]interface{}{len(bundle.DetectionMethods), len(bundle.EntityTypes)},
+		time.Since(startTime),
+		err,
+	)
+
+	return err
+}
@@ -0,0 +1,93 @@
+package repository_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/yasinhessnawi1/Hideme_Backend/internal/database"
+	"github.com/yasinhessnawi1/Hideme_Backend/internal/models"
+	"github.com/yasinhessnawi1/Hideme_Backend/internal/repository"
+	"github.com/yasinhessnawi1/Hideme_Backend/internal/utils"
+)
+
+func setupDetectionCatalogTest(t *testing.T) (repository.DetectionCatalogRepository, sqlmock.Sqlmock, func()) {
+	db, mock, err := sqlmock.New()
+	require.NoError(t, err)
+
+	return repository.NewDetectionCatalogRepository(&database.Pool{DB: db}), mock, func() { db.Close() }
+}
+
+func TestDetectionCatalogRepository_List(t *testing.T) {
+	repo, mock, cleanup := setupDetectionCatalogTest(t)
+	defer cleanup()
+
+	mock.ExpectQuery("SELECT method_id, method_name, highlight_color FROM detection_methods ORDER BY method_name").
+		WillReturnRows(sqlmock.NewRows([]string{"method_id", "method_name", "highlight_color"}).
+			AddRow(1, "Gliner", "#F033FF").
+			AddRow(2, "Presidio", "#33FF57"))
+
+	methods, err := repo.ListDetectionMethods(context.Background())
+	require.NoError(t, err)
+	assert.Equal(t, []*models.DetectionMethod{
+		{ID: 1, MethodName: "Gliner", HighlightColor: "#F033FF"},
+		{ID: 2, MethodName: "Presidio", HighlightColor: "#33FF57"},
+	}, methods)
+
+	mock.ExpectQuery("SELECT (.+) FROM entity_types e JOIN detection_methods dm (.+) ORDER BY e.entity_type").
+		WillReturnRows(sqlmock.NewRows([]string{"entity_type_id", "entity_type", "method_id", "method_name", "description"}).
+			AddRow(7, "PERSON", 2, "Presidio", "Names of people"))
+
+	entityTypes, err := repo.ListEntityTypes(context.Background())
+	require.NoError(t, err)
+	assert.Equal(t, []*models.EntityType{
+		{ID: 7, EntityType: "PERSON", MethodID: 2, MethodName: "Presidio", Description: "Names of people"},
+	}, entityTypes)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestDetectionCatalogRepository_Import(t *testing.T) {
+	bundle := &models.DetectionCatalogBundle{
+		DetectionMethods: []models.DetectionCatalogMethod{{MethodName: "Presidio", HighlightColor: "#33FF57"}},
+		EntityTypes: []models.DetectionCatalogEntityType{
+			{EntityType: "PERSON", MethodName: "Presidio", Description: "Names of people"},
+		},
+	}
+
+	t.Run("Success", func(t *testing.T) {
+		repo, mock, cleanup := setupDetectionCatalogTest(t)
+		defer cleanup()
+
+		mock.ExpectBegin()
+		mock.ExpectExec("INSERT INTO detection_methods (.+) ON CONFLICT \\(method_name\\) DO UPDATE").
+			WithArgs("Presidio", "#33FF57").
+			WillReturnResult(sqlmock.NewResult(0, 1))
+		mock.ExpectExec("INSERT INTO entity_types (.+) ON CONFLICT \\(entity_type\\) DO UPDATE").
+			WithArgs("PERSON", "Presidio", "Names of people").
+			WillReturnResult(sqlmock.NewResult(0, 1))
+		mock.ExpectCommit()
+
+		require.NoError(t, repo.Import(context.Background(), bundle))
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("Unknown method rolls back", func(t *testing.T) {
+		repo, mock, cleanup := setupDetectionCatalogTest(t)
+		defer cleanup()
+
+		mock.ExpectBegin()
+		mock.ExpectExec("INSERT INTO detection_methods").
+			WillReturnResult(sqlmock.NewResult(0, 1))
+		mock.ExpectExec("INSERT INTO entity_types").
+			WillReturnResult(sqlmock.NewResult(0, 0))
+		mock.ExpectRollback()
+
+		err := repo.Import(context.Background(), bundle)
+		assert.True(t, errors.Is(err, utils.ErrNotFound))
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+}
@@ -0,0 +1,292 @@
+// Package repository provides data access interfaces and implementations for the HideMe application.
+// It follows the repository pattern to abstract database operations and provide a clean API
+// for data persistence operations.
+//
+// This file implements the cold storage tier of documents, which holds the redaction schemas
+// and detected entities of documents nobody has touched for a long time.
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/lib/pq"
+	"github.com/rs/zerolog/log"
+
+	"github.com/yasinhessnawi1/Hideme_Backend/internal/constants"
+	"github.com/yasinhessnawi1/Hideme_Backend/internal/database"
+	"github.com/yasinhessnawi1/Hideme_Backend/internal/utils"
+)
+
+// DocumentArchiveRepository defines methods for moving documents between hot and cold storage.
+// Archiving moves the redaction schema and detected entities of a document to the archive
+// table and keeps only its metadata in the documents table; restoring moves them back.
+// Both keep the values encrypted as they were, so the tenant keys still apply.
+type DocumentArchiveRepository interface {
+	// Archive moves documents not modified since a cutoff to cold storage.
+	//
+	// Parameters:
+	//   - ctx: Context for transaction and cancellation control
+	//   - cutoff: Documents last modified before this moment are archived
+	//   - now: The moment recorded as the archive time
+	//   - limit: The maximum number of documents to archive
+	//
+	// Returns:
+	//   - The number of archived documents
+	//   - An error if archiving fails; nothing is archived then
+	Archive(ctx context.Context, cutoff, now time.Time, limit int) (int64, error)
+
+	// ArchiveDocuments moves the given documents to cold storage, skipping those already archived.
+	//
+	// Parameters:
+	//   - ctx: Context for transaction and cancellation control
+	//   - documentIDs: The IDs of the documents to archive
+	//   - now: The moment recorded as the archive time
+	//
+	// Returns:
+	//   - The number of archived documents
+	//   - An error if archiving fails; nothing is archived then
+	ArchiveDocuments(ctx context.Context, documentIDs []int64, now time.Time) (int64, error)
+
+	// Restore moves an archived document back to hot storage.
+	//
+	// Parameters:
+	//   - ctx: Context for transaction and cancellation control
+	//   - documentID: The unique identifier of the archived document
+	//
+	// Returns:
+	//   - NotFoundError if the document is not archived
+	//   - Other errors for database issues; the document stays archived then
+	Restore(ctx context.Context, documentID int64) error
+}
+
+// PostgresDocumentArchiveRepository is a PostgreSQL implementation of DocumentArchiveRepository.
+type PostgresDocumentArchiveRepository struct {
+	db *database.Pool
+}
+
+// NewDocumentArchiveRepository creates a new DocumentArchiveRepository implementation for PostgreSQL.
+//
+// Parameters:
+//   - db: A connection pool for PostgreSQL database access
+//
+// Returns:
+//   - An implementation of the DocumentArchiveRepository interface
+func NewDocumentArchiveRepository(db *database.Pool) DocumentArchiveRepository {
+	return &PostgresDocumentArchiveRepository{
+		db: db,
+	}
+}
+
+// Archive moves documents not modified since a cutoff to cold storage.
+// The documents are locked while they are archived; documents locked by another run are skipped.
+func (r *PostgresDocumentArchiveRepository) Archive(ctx context.Context, cutoff, now time.Time, limit int) (int64, error) {
+	// Start query timer
+	startTime := time.Now()
+
+	var archived int64
+	err := r.db.Transaction(ctx, func(tx *sql.Tx) error {
+		// Pick the documents to archive
+		selectQuery := `
+            SELECT ` + constants.ColumnDocumentID + `
+            FROM ` + constants.TableDocuments + `
+            WHERE ` + constants.ColumnArchivedAt + ` IS NULL AND last_modified < $1
+            ORDER BY ` + constants.ColumnDocumentID + `
+            LIMIT $2
+            FOR UPDATE SKIP LOCKED
+        `
+		ids, err := selectDocumentsToArchive(ctx, tx, selectQuery, cutoff, limit)
+		if err != nil || len(ids) == 0 {
+			return err
+		}
+
+		archived, err = archiveDocuments(ctx, tx, ids, now, startTime)
+		return err
+	})
+	if err != nil {
+		return 0, err
+	}
+
+	if archived > 0 {
+		log.Info().
+			Int64("archived", archived).
+			Time("cutoff", cutoff).
+			Msg("Documents archived")
+	}
+
+	return archived, nil
+}
+
+// ArchiveDocuments moves the given documents to cold storage, skipping those already archived.
+// The documents are locked while they are archived.
+func (r *PostgresDocumentArchiveRepository) ArchiveDocuments(ctx context.Context, documentIDs []int64, now time.Time) (int64, error) {
+	// Start query timer
+	startTime := time.Now()
+
+	var archived int64
+	err := r.db.Transaction(ctx, func(tx *sql.Tx) error {
+		// Lock the documents still in hot storage
+		selectQuery := `
+            SELECT ` + constants.ColumnDocumentID + `
+            FROM ` + constants.TableDocuments + `
+            WHERE ` + constants.ColumnDocumentID + ` = ANY($1) AND ` + constants.ColumnArchivedAt + ` IS NULL
+            ORDER BY ` + constants.ColumnDocumentID + `
+            FOR UPDATE
+        `
+		ids, err := selectDocumentsToArchive(ctx, tx, selectQuery, pq.Array(documentIDs))
+		if err != nil || len(ids) == 0 {
+			return err
+		}
+
+		archived, err = archiveDocuments(ctx, tx, ids, now, startTime)
+		return err
+	})
+	if err != nil {
+		return 0, err
+	}
+
+	if archived > 0 {
+		log.Info().
+			Int64("archived", archived).
+			Msg("Documents archived")
+	}
+
+	return archived, nil
+}
+
+// selectDocumentsToArchive runs a query selecting the IDs of documents to archive.
+func selectDocumentsToArchive(ctx context.Context, tx *sql.Tx, query string, args ...interface{}) ([]int64, error) {
+	rows, err := tx.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to select documents to archive: %w", err)
+	}
+	defer rows.Close()
+
+	var ids []int64
+	for rows.Next() {
+		var id int64
+		if err := rows.Scan(&id); err != nil {
+			return nil, fmt.Errorf("failed to scan document ID: %w", err)
+		}
+		ids = append(ids, id)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating documents to archive: %w", err)
+	}
+
+	return ids, nil
+}
+
+// archiveDocuments copies the redaction schemas and detected entities of documents to cold
+// storage and removes them from hot storage, within the transaction that locked them.
+func archiveDocuments(ctx context.Context, tx *sql.Tx, ids []int64, now, startTime time.Time) (int64, error) {
+	// Copy the redaction schemas and entities to cold storage
+	archiveQuery := `
+            INSERT INTO ` + constants.TableDocumentArchives + ` (` + constants.ColumnDocumentID + `, redaction_schema, entities, ` + constants.ColumnArchivedAt + `)
+            SELECT d.` + constants.ColumnDocumentID + `, d.redaction_schema,
+                   COALESCE((SELECT jsonb_agg(to_jsonb(de)) FROM ` + constants.TableDetectedEntities + ` de WHERE de.` + constants.ColumnDocumentID + ` = d.` + constants.ColumnDocumentID + `), '[]'::jsonb),
+                   $2
+            FROM ` + constants.TableDocuments + ` d
+            WHERE d.` + constants.ColumnDocumentID + ` = ANY($1)
+        `
+	if _, err := tx.ExecContext(ctx, archiveQuery, pq.Array(ids), now); err != nil {
+		return 0, fmt.Errorf("failed to copy documents to the archive: %w", err)
+	}
+
+	// Then remove them from hot storage
+	entitiesQuery := "DELETE FROM " + constants.TableDetectedEntities + " WHERE " + constants.ColumnDocumentID + " = ANY($1)"
+	if _, err := tx.ExecContext(ctx, entitiesQuery, pq.Array(ids)); err != nil {
+		return 0, fmt.Errorf("failed to delete detected entities of archived documents: %w", err)
+	}
+
+	documentQuery := `
+            UPDATE ` + constants.TableDocuments + `
+            SET redaction_schema = '{}', ` + constants.ColumnArchivedAt + ` = $2
+            WHERE ` + constants.ColumnDocumentID + ` = ANY($1)
+        `
+	result, err := tx.ExecContext(ctx, documentQuery, pq.Array(ids), now)
+
+	// Log the query execution
+	utils.LogDBQuery(
+		documentQuery,
+		[]interface{}{ids, now},
+		time.Since(startTime),
+		err,
+	)
+
+	if err != nil {
+		return 0, fmt.Errorf("failed to mark documents as archived: %w", err)
+	}
+
+	archived, err := result.RowsAffected()
+	if err != nil {
+		return 0, fmt.Errorf("failed to get rows affected: %w", err)
+	}
+
+	return archived, nil
+}
+
+// Restore moves an archived document back to hot storage.
+// The detected entities get back the IDs they had before they were archived.
+func (r *PostgresDocumentArchiveRepository) Restore(ctx context.Context, documentID int64) error {
+	// Start query timer
+	startTime := time.Now()
+
+	return r.db.Transaction(ctx, func(tx *sql.Tx) error {
+		// Take the document out of cold storage
+		archiveQuery := `
+            DELETE FROM ` + constants.TableDocumentArchives + `
+            WHERE ` + constants.ColumnDocumentID + ` = $1
+            RETURNING redaction_schema, entities
+        `
+		var redactionSchema, entities string
+		err := tx.QueryRowContext(ctx, archiveQuery, documentID).Scan(&redactionSchema, &entities)
+
+		// Log the query execution
+		utils.LogDBQuery(
+			archiveQuery,
+			[]interface{}{documentID},
+			time.Since(startTime),
+			err,
+		)
+
+		if err != nil {
+			if errors.Is(err, sql.ErrNoRows) {
+				return utils.NewNotFoundError("DocumentArchive", documentID).WithCause(err)
+			}
+			return fmt.Errorf("failed to read document archive: %w", err)
+		}
+
+		// Put back the entities and the redaction schema
+		entitiesQuery := `
+            INSERT INTO ` + constants.TableDetectedEntities + ` OVERRIDING SYSTEM VALUE
+            SELECT * FROM jsonb_populate_recordset(NULL::` + constants.TableDetectedEntities + `, $1::jsonb)
+        `
+		if _, err := tx.ExecContext(ctx, entitiesQuery, entities); err != nil {
+			return fmt.Errorf("failed to restore detected entities: %w", err)
+		}
+
+		documentQuery := `
+            UPDATE ` + constants.TableDocuments + `
+            SET redaction_schema = $1, ` + constants.ColumnArchivedAt + ` = NULL
+            WHERE ` + constants.ColumnDocumentID + ` = $2
+        `
+		result, err := tx.ExecContext(ctx, documentQuery, redactionSchema, documentID)
+		if err != nil {
+			return fmt.Errorf("failed to restore document: %w", err)
+		}
+
+		rowsAffected, err := result.RowsAffected()
+		if err != nil {
+			return fmt.Errorf("failed to get rows affected: %w", err)
+		}
+		if rowsAffected == 0 {
+			return utils.NewNotFoundError("Document", documentID)
+		}
+
+		return nil
+	})
+}
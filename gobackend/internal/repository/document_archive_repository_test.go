@@ -0,0 +1,129 @@
+package repository_test
+
+import (
+	"context"
+	"database/sql"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/yasinhessnawi1/Hideme_Backend/internal/database"
+	"github.com/yasinhessnawi1/Hideme_Backend/internal/repository"
+	"github.com/yasinhessnawi1/Hideme_Backend/internal/utils"
+)
+
+func TestDocumentArchiveRepository_Archive(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	require.NoError(t, err)
+	defer db.Close()
+	repo := repository.NewDocumentArchiveRepository(&database.Pool{DB: db})
+
+	now := time.Now()
+	cutoff := now.AddDate(-1, 0, 0)
+
+	t.Run("Success", func(t *testing.T) {
+		mock.ExpectBegin()
+		mock.ExpectQuery("SELECT document_id FROM documents WHERE archived_at IS NULL AND last_modified < \\$1 ORDER BY document_id LIMIT \\$2 FOR UPDATE SKIP LOCKED").
+			WithArgs(cutoff, 100).
+			WillReturnRows(sqlmock.NewRows([]string{"document_id"}).AddRow(1).AddRow(2))
+		mock.ExpectExec("INSERT INTO document_archives \\(document_id, redaction_schema, entities, archived_at\\)").
+			WithArgs(sqlmock.AnyArg(), now).
+			WillReturnResult(sqlmock.NewResult(0, 2))
+		mock.ExpectExec("DELETE FROM detected_entities WHERE document_id = ANY\\(\\$1\\)").
+			WillReturnResult(sqlmock.NewResult(0, 5))
+		mock.ExpectExec("UPDATE documents SET redaction_schema = '\\{\\}', archived_at = \\$2").
+			WithArgs(sqlmock.AnyArg(), now).
+			WillReturnResult(sqlmock.NewResult(0, 2))
+		mock.ExpectCommit()
+
+		archived, err := repo.Archive(context.Background(), cutoff, now, 100)
+
+		require.NoError(t, err)
+		assert.Equal(t, int64(2), archived)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("Nothing to archive", func(t *testing.T) {
+		mock.ExpectBegin()
+		mock.ExpectQuery("SELECT document_id FROM documents").
+			WillReturnRows(sqlmock.NewRows([]string{"document_id"}))
+		mock.ExpectCommit()
+
+		archived, err := repo.Archive(context.Background(), cutoff, now, 100)
+
+		require.NoError(t, err)
+		assert.Zero(t, archived)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+}
+
+func TestDocumentArchiveRepository_ArchiveDocuments(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	require.NoError(t, err)
+	defer db.Close()
+	repo := repository.NewDocumentArchiveRepository(&database.Pool{DB: db})
+
+	now := time.Now()
+
+	// Document 3 was archived already, so only document 1 is moved
+	mock.ExpectBegin()
+	mock.ExpectQuery("SELECT document_id FROM documents WHERE document_id = ANY\\(\\$1\\) AND archived_at IS NULL ORDER BY document_id FOR UPDATE").
+		WithArgs(sqlmock.AnyArg()).
+		WillReturnRows(sqlmock.NewRows([]string{"document_id"}).AddRow(1))
+	mock.ExpectExec("INSERT INTO document_archives").
+		WithArgs(sqlmock.AnyArg(), now).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectExec("DELETE FROM detected_entities WHERE document_id = ANY\\(\\$1\\)").
+		WillReturnResult(sqlmock.NewResult(0, 0))
+	mock.ExpectExec("UPDATE documents SET redaction_schema = '\\{\\}', archived_at = \\$2").
+		WithArgs(sqlmock.AnyArg(), now).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectCommit()
+
+	archived, err := repo.ArchiveDocuments(context.Background(), []int64{1, 3}, now)
+
+	require.NoError(t, err)
+	assert.Equal(t, int64(1), archived)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestDocumentArchiveRepository_Restore(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	require.NoError(t, err)
+	defer db.Close()
+	repo := repository.NewDocumentArchiveRepository(&database.Pool{DB: db})
+
+	t.Run("Success", func(t *testing.T) {
+		entities := `[{"entity_id":3,"document_id":1,"method_id":1}]`
+		mock.ExpectBegin()
+		mock.ExpectQuery("DELETE FROM document_archives WHERE document_id = \\$1 RETURNING redaction_schema, entities").
+			WithArgs(int64(1)).
+			WillReturnRows(sqlmock.NewRows([]string{"redaction_schema", "entities"}).AddRow(`{"file_results":"x"}`, entities))
+		mock.ExpectExec("INSERT INTO detected_entities OVERRIDING SYSTEM VALUE SELECT \\* FROM jsonb_populate_recordset").
+			WithArgs(entities).
+			WillReturnResult(sqlmock.NewResult(0, 1))
+		mock.ExpectExec("UPDATE documents SET redaction_schema = \\$1, archived_at = NULL WHERE document_id = \\$2").
+			WithArgs(`{"file_results":"x"}`, int64(1)).
+			WillReturnResult(sqlmock.NewResult(0, 1))
+		mock.ExpectCommit()
+
+		require.NoError(t, repo.Restore(context.Background(), 1))
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("Not archived", func(t *testing.T) {
+		mock.ExpectBegin()
+		mock.ExpectQuery("DELETE FROM document_archives").
+			WithArgs(int64(2)).
+			WillReturnError(sql.ErrNoRows)
+		mock.ExpectRollback()
+
+		err := repo.Restore(context.Background(), 2)
+
+		assert.True(t, utils.IsNotFoundError(err))
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+}
@@ -0,0 +1,268 @@
+// Package repository provides data access interfaces and implementations for the HideMe application.
+// It follows the repository pattern to abstract database operations and provide a clean API
+// for data persistence operations.
+//
+// This file implements the document content repository, which links documents to their
+// encrypted PDF content in the document storage.
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/lib/pq"
+
+	"github.com/yasinhessnawi1/Hideme_Backend/internal/constants"
+	"github.com/yasinhessnawi1/Hideme_Backend/internal/database"
+	"github.com/yasinhessnawi1/Hideme_Backend/internal/models"
+	"github.com/yasinhessnawi1/Hideme_Backend/internal/utils"
+)
+
+// DocumentContentRepository defines methods for linking documents to their stored content.
+type DocumentContentRepository interface {
+	// Create links a document to its stored content.
+	//
+	// Parameters:
+	//   - ctx: Context for transaction and cancellation control
+	//   - content: The stored content of the document
+	//
+	// Returns:
+	//   - DuplicateError if the document already has stored content
+	//   - Other errors for database issues
+	Create(ctx context.Context, content *models.DocumentContent) error
+
+	// GetByDocumentID retrieves the stored content of a document.
+	//
+	// Parameters:
+	//   - ctx: Context for transaction and cancellation control
+	//   - documentID: The ID of the document
+	//
+	// Returns:
+	//   - The stored content
+	//   - NotFoundError if the document was uploaded without content
+	//   - Other errors for database issues
+	GetByDocumentID(ctx context.Context, documentID int64) (*models.DocumentContent, error)
+
+	// ListOrphaned retrieves stored contents whose document was deleted.
+	//
+	// Parameters:
+	//   - ctx: Context for transaction and cancellation control
+	//   - limit: The maximum number of contents to return
+	//
+	// Returns:
+	//   - The contents of deleted documents, oldest first
+	//   - An error for database issues
+	ListOrphaned(ctx context.Context, limit int) ([]*models.DocumentContent, error)
+
+	// Delete removes the link of a document to its stored content.
+	//
+	// Parameters:
+	//   - ctx: Context for transaction and cancellation control
+	//   - documentID: The ID of the document
+	//
+	// Returns:
+	//   - NotFoundError if the document has no stored content
+	//   - Other errors for database issues
+	Delete(ctx context.Context, documentID int64) error
+}
+
+// PostgresDocumentContentRepository is a PostgreSQL implementation of DocumentContentRepository.
+type PostgresDocumentContentRepository struct {
+	db *database.Pool
+}
+
+// NewDocumentContentRepository creates a new DocumentContentRepository implementation for PostgreSQL.
+//
+// Parameters:
+//   - db: A connection pool for PostgreSQL database access
+//
+// Returns:
+//   - An implementation of the DocumentContentRepository interface
+func NewDocumentContentRepository(db *database.Pool) DocumentContentRepository {
+	return &PostgresDocumentContentRepository{
+		db: db,
+	}
+}
+
+// documentContentColumns is the column list shared by all content queries.
+const documentContentColumns = constants.ColumnDocumentID + `, storage_key, content_type, size_bytes, sha256, segment_size, created_at`
+
+// scanDocumentContent scans the documentContentColumns of a row.
+//
+// Parameters:
+//   - scanner: The row to scan from
+//
+// Returns:
+//   - The scanned content
+//   - An error if scanning fails
+func scanDocumentContent(scanner interface{ Scan(dest ...any) error }) (*models.DocumentContent, error) {
+	content := &models.DocumentContent{}
+	err := scanner.Scan(
+		&content.DocumentID,
+		&content.StorageKey,
+		&content.ContentType,
+		&content.SizeBytes,
+		&content.SHA256,
+		&content.SegmentSize,
+		&content.CreatedAt,
+	)
+	return content, err
+}
+
+// Create links a document to its stored content.
+func (r *PostgresDocumentContentRepository) Create(ctx context.Context, content *models.DocumentContent) error {
+	// Start query timer
+	startTime := time.Now()
+
+	// Define the query
+	query := `
+        INSERT INTO ` + constants.TableDocumentContents + ` (` + documentContentColumns + `)
+        VALUES ($1, $2, $3, $4, $5, $6, $7)
+    `
+	args := []interface{}{content.DocumentID, content.StorageKey, content.ContentType, content.SizeBytes, content.SHA256, content.SegmentSize, content.CreatedAt}
+
+	// Execute the query
+	_, err := r.db.ExecContext(ctx, query, args...)
+
+	// Log the query execution
+	utils.LogDBQuery(
+		query,
+		args,
+		time.Since(startTime),
+		err,
+	)
+
+	if err != nil {
+		var pqErr *pq.Error
+		if errors.As(err, &pqErr) && pqErr.Code == constants.PGErrorDuplicateConstraint {
+			return utils.NewDuplicateError("DocumentContent", constants.ColumnDocumentID, content.DocumentID).WithCause(err)
+		}
+		return fmt.Errorf("failed to create document content: %w", err)
+	}
+
+	return nil
+}
+
+// GetByDocumentID retrieves the stored content of a document.
+func (r *PostgresDocumentContentRepository) GetByDocumentID(ctx context.Context, documentID int64) (*models.DocumentContent, error) {
+	// Start query timer
+	startTime := time.Now()
+
+	// Define the query
+	query := `
+        SELECT ` + documentContentColumns + `
+        FROM ` + constants.TableDocumentContents + `
+        WHERE ` + constants.ColumnDocumentID + ` = $1
+    `
+	args := []interface{}{documentID}
+
+	// Execute the query
+	content, err := scanDocumentContent(r.db.QueryRowContext(ctx, query, args...))
+
+	// Log the query execution
+	utils.LogDBQuery(
+		query,
+		args,
+		time.Since(startTime),
+		err,
+	)
+
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, utils.NewNotFoundError("DocumentContent", documentID)
+		}
+		return nil, fmt.Errorf("failed to get document content: %w", err)
+	}
+
+	return content, nil
+}
+
+// ListOrphaned retrieves stored contents whose document was deleted.
+func (r *PostgresDocumentContentRepository) ListOrphaned(ctx context.Context, limit int) ([]*models.DocumentContent, error) {
+	// Start query timer
+	startTime := time.Now()
+
+	// Define the query
+	query := `
+        SELECT ` + documentContentColumns + `
+        FROM ` + constants.TableDocumentContents + ` c
+        WHERE NOT EXISTS (
+            SELECT 1 FROM ` + constants.TableDocuments + ` d
+            WHERE d.` + constants.ColumnDocumentID + ` = c.` + constants.ColumnDocumentID + `
+        )
+        ORDER BY created_at
+        LIMIT $1
+    `
+	args := []interface{}{limit}
+
+	// Execute the query
+	rows, err := r.db.QueryContext(ctx, query, args...)
+
+	// Log the query execution
+	utils.LogDBQuery(
+		query,
+		args,
+		time.Since(startTime),
+		err,
+	)
+
+	if err != nil {
+		return nil, fmt.Errorf("failed to list orphaned document contents: %w", err)
+	}
+	defer rows.Close()
+
+	var contents []*models.DocumentContent
+	for rows.Next() {
+		content, err := scanDocumentContent(rows)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan document content: %w", err)
+		}
+		contents = append(contents, content)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating document contents: %w", err)
+	}
+
+	return contents, nil
+}
+
+// Delete removes the link of a document to its stored content.
+func (r *PostgresDocumentContentRepository) Delete(ctx context.Context, documentID int64) error {
+	// Start query timer
+	startTime := time.Now()
+
+	// Define the query
+	query := `
+        DELETE FROM ` + constants.TableDocumentContents + `
+        WHERE ` + constants.ColumnDocumentID + ` = $1
+    `
+	args := []interface{}{documentID}
+
+	// Execute the query
+	result, err := r.db.ExecContext(ctx, query, args...)
+
+	// Log the query execution
+	utils.LogDBQuery(
+		query,
+		args,
+		time.Since(startTime),
+		err,
+	)
+
+	if err != nil {
+		return fmt.Errorf("failed to delete document content: %w", err)
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("failed to get rows affected: %w", err)
+	}
+	if rowsAffected == 0 {
+		return utils.NewNotFoundError("DocumentContent", documentID)
+	}
+
+	return nil
+}
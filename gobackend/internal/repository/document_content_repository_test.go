@@ -0,0 +1,120 @@
+package repository_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/lib/pq"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/yasinhessnawi1/Hideme_Backend/internal/constants"
+	"github.com/yasinhessnawi1/Hideme_Backend/internal/database"
+	"github.com/yasinhessnawi1/Hideme_Backend/internal/models"
+	"github.com/yasinhessnawi1/Hideme_Backend/internal/repository"
+	"github.com/yasinhessnawi1/Hideme_Backend/internal/utils"
+)
+
+var documentContentTestColumns = []string{"document_id", "storage_key", "content_type", "size_bytes", "sha256", "segment_size", "created_at"}
+
+func setupDocumentContentTest(t *testing.T) (repository.DocumentContentRepository, sqlmock.Sqlmock, func()) {
+	db, mock, err := sqlmock.New()
+	require.NoError(t, err)
+
+	return repository.NewDocumentContentRepository(&database.Pool{DB: db}), mock, func() { db.Close() }
+}
+
+func TestDocumentContentRepository_Create(t *testing.T) {
+	repo, mock, cleanup := setupDocumentContentTest(t)
+	defer cleanup()
+	content := &models.DocumentContent{
+		DocumentID:  12,
+		StorageKey:  "7/0190b3c4",
+		ContentType: constants.ContentTypePDF,
+		SizeBytes:   2048,
+		SHA256:      "ab12",
+		SegmentSize: constants.DocumentContentSegmentSize,
+		CreatedAt:   time.Now(),
+	}
+
+	mock.ExpectExec("INSERT INTO document_contents").
+		WithArgs(int64(12), "7/0190b3c4", constants.ContentTypePDF, int64(2048), "ab12", constants.DocumentContentSegmentSize, content.CreatedAt).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	require.NoError(t, repo.Create(context.Background(), content))
+
+	// The document already has content
+	mock.ExpectExec("INSERT INTO document_contents").
+		WillReturnError(&pq.Error{Code: constants.PGErrorDuplicateConstraint})
+
+	err := repo.Create(context.Background(), content)
+	var appErr *utils.AppError
+	require.True(t, errors.As(err, &appErr))
+	assert.True(t, errors.Is(appErr.Err, utils.ErrDuplicate))
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestDocumentContentRepository_GetByDocumentID(t *testing.T) {
+	repo, mock, cleanup := setupDocumentContentTest(t)
+	defer cleanup()
+	now := time.Now()
+
+	mock.ExpectQuery("SELECT (.+) FROM document_contents WHERE document_id = \\$1").
+		WithArgs(int64(12)).
+		WillReturnRows(sqlmock.NewRows(documentContentTestColumns).
+			AddRow(12, "7/0190b3c4", constants.ContentTypePDF, 2048, "ab12", 65536, now))
+
+	content, err := repo.GetByDocumentID(context.Background(), 12)
+	require.NoError(t, err)
+	assert.Equal(t, "7/0190b3c4", content.StorageKey)
+	assert.Equal(t, int64(2048), content.SizeBytes)
+	assert.Equal(t, 65536, content.SegmentSize)
+
+	// The document was uploaded without content
+	mock.ExpectQuery("SELECT (.+) FROM document_contents").
+		WillReturnRows(sqlmock.NewRows(documentContentTestColumns))
+
+	_, err = repo.GetByDocumentID(context.Background(), 13)
+	var appErr *utils.AppError
+	require.True(t, errors.As(err, &appErr))
+	assert.True(t, errors.Is(appErr.Err, utils.ErrNotFound))
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestDocumentContentRepository_ListOrphaned(t *testing.T) {
+	repo, mock, cleanup := setupDocumentContentTest(t)
+	defer cleanup()
+	now := time.Now()
+
+	mock.ExpectQuery("SELECT (.+) FROM document_contents c WHERE NOT EXISTS \\( SELECT 1 FROM documents d").
+		WithArgs(100).
+		WillReturnRows(sqlmock.NewRows(documentContentTestColumns).
+			AddRow(12, "7/0190b3c4", constants.ContentTypePDF, 2048, "ab12", 0, now).
+			AddRow(14, "8/0190b3c5", constants.ContentTypePDF, 4096, "cd34", 65536, now))
+
+	contents, err := repo.ListOrphaned(context.Background(), 100)
+	require.NoError(t, err)
+	require.Len(t, contents, 2)
+	assert.Equal(t, int64(14), contents[1].DocumentID)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestDocumentContentRepository_Delete(t *testing.T) {
+	repo, mock, cleanup := setupDocumentContentTest(t)
+	defer cleanup()
+
+	mock.ExpectExec("DELETE FROM document_contents WHERE document_id = \\$1").
+		WithArgs(int64(12)).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	require.NoError(t, repo.Delete(context.Background(), 12))
+
+	mock.ExpectExec("DELETE FROM document_contents").
+		WillReturnResult(sqlmock.NewResult(0, 0))
+	err := repo.Delete(context.Background(), 12)
+	var appErr *utils.AppError
+	require.True(t, errors.As(err, &appErr))
+	assert.True(t, errors.Is(appErr.Err, utils.ErrNotFound))
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
@@ -10,10 +10,13 @@ package repository
 import (
 	"context"
 	"database/sql"
+	"encoding/json"
 	"errors"
 	"fmt"
+	"strings"
 	"time"
 
+	"github.com/lib/pq"
 	"github.com/rs/zerolog/log"
 
 	"github.com/yasinhessnawi1/Hideme_Backend/internal/constants"
@@ -56,14 +59,83 @@ type DocumentRepository interface {
 	// Parameters:
 	//   - ctx: Context for transaction and cancellation control
 	//   - userID: The unique identifier of the user
+	//   - language: Only return documents in this language; empty for all documents
 	//   - page: The page number (starting from 1)
 	//   - pageSize: The number of documents per page
 	//
 	// Returns:
-	//   - A slice of documents owned by the user for the requested page
-	//   - The total count of documents owned by the user
+	//   - A slice of matching documents owned by the user for the requested page
+	//   - The total count of matching documents owned by the user
 	//   - An error if retrieval fails
-	GetByUserID(ctx context.Context, userID int64, page, pageSize int) ([]*models.Document, int, error)
+	GetByUserID(ctx context.Context, userID int64, language string, page, pageSize int) ([]*models.Document, int, error)
+
+	// GetByUserIDAfter retrieves the next documents of a user newest first, starting after a
+	// keyset cursor. Unlike GetByUserID it neither counts nor skips rows, so every page costs
+	// the same however many documents the user has.
+	//
+	// Parameters:
+	//   - ctx: Context for transaction and cancellation control
+	//   - userID: The unique identifier of the user
+	//   - language: Only return documents in this language; empty for all documents
+	//   - after: The last document of the previous page; nil for the first page
+	//   - limit: The maximum number of documents to return
+	//
+	// Returns:
+	//   - The documents with their names decrypted (empty after the last one)
+	//   - An error if retrieval fails
+	GetByUserIDAfter(ctx context.Context, userID int64, language string, after *utils.KeysetCursor, limit int) ([]*models.Document, error)
+
+	// CountByUserID counts the documents stored for a user.
+	//
+	// Parameters:
+	//   - ctx: Context for transaction and cancellation control
+	//   - userID: The unique identifier of the user
+	//
+	// Returns:
+	//   - The number of documents owned by the user
+	//   - An error if counting fails
+	CountByUserID(ctx context.Context, userID int64) (int64, error)
+
+	// StreamByUserID passes all documents of a user to fn one at a time, newest first,
+	// as they are scanned and decrypted.
+	//
+	// Parameters:
+	//   - ctx: Context for transaction and cancellation control
+	//   - userID: The unique identifier of the user
+	//   - language: Only pass on documents in this language; empty for all documents
+	//   - fn: Called for each document; returning an error stops the scan
+	//
+	// Returns:
+	//   - The error returned by fn, or an error if retrieval fails
+	StreamByUserID(ctx context.Context, userID int64, language string, fn func(*models.Document) error) error
+
+	// ListByUserIDAfter retrieves the next documents of a user in ascending ID order, so
+	// long running jobs can walk all documents in batches and resume after the last one.
+	//
+	// Parameters:
+	//   - ctx: Context for transaction and cancellation control
+	//   - userID: The unique identifier of the user
+	//   - afterID: Only return documents with a higher ID; 0 to start at the first
+	//   - limit: The maximum number of documents to return
+	//
+	// Returns:
+	//   - The documents with their names decrypted (empty after the last one)
+	//   - An error if retrieval fails
+	ListByUserIDAfter(ctx context.Context, userID, afterID int64, limit int) ([]*models.Document, error)
+
+	// SearchByUserID passes the documents of a user matching the upload date and entity
+	// filters to fn one at a time, newest first. Document names are encrypted, so the name
+	// filter is left to the caller.
+	//
+	// Parameters:
+	//   - ctx: Context for transaction and cancellation control
+	//   - userID: The unique identifier of the user
+	//   - filter: The upload date range, entity types and entity count to match
+	//   - fn: Called for each matching document; returning an error stops the scan
+	//
+	// Returns:
+	//   - The error returned by fn, or an error if retrieval fails
+	SearchByUserID(ctx context.Context, userID int64, filter models.DocumentSearchFilter, fn func(*models.Document) error) error
 
 	// Update updates a document in the database.
 	//
@@ -106,17 +178,34 @@ type DocumentRepository interface {
 	// are deleted atomically.
 	DeleteByUserID(ctx context.Context, userID int64) error
 
-	// GetDetectedEntities retrieves all detected entities for a document.
+	// GetDetectedEntities retrieves the detected entities of a document selected by a filter.
 	//
 	// Parameters:
 	//   - ctx: Context for transaction and cancellation control
 	//   - documentID: The unique identifier of the document
+	//   - filter: The review status, detection method and keyset page to select; the zero filter selects all
 	//
 	// Returns:
 	//   - A slice of detected entities with their associated detection methods
-	//   - An empty slice if the document has no detected entities
+	//   - An empty slice if the document has no matching detected entities
 	//   - An error if retrieval fails
-	GetDetectedEntities(ctx context.Context, documentID int64) ([]*models.DetectedEntityWithMethod, error)
+	GetDetectedEntities(ctx context.Context, documentID int64, filter models.DetectedEntityFilter) ([]*models.DetectedEntityWithMethod, error)
+
+	// UpdateEntityStatus changes the review status of detected entities of a document in one
+	// transaction; if any of them doesn't belong to the document, none is changed.
+	//
+	// Parameters:
+	//   - ctx: Context for transaction and cancellation control
+	//   - documentID: The document the entities belong to
+	//   - entityIDs: The entities to change, without duplicates
+	//   - status: The new review status
+	//   - reviewedBy: The user changing the status
+	//
+	// Returns:
+	//   - The time the status was changed
+	//   - NotFoundError if an entity doesn't exist or belongs to another document
+	//   - Other errors if the update fails
+	UpdateEntityStatus(ctx context.Context, documentID int64, entityIDs []int64, status models.EntityReviewStatus, reviewedBy int64) (time.Time, error)
 
 	// AddDetectedEntity adds a new detected entity to a document.
 	//
@@ -131,6 +220,21 @@ type DocumentRepository interface {
 	// The entity ID will be populated after successful addition.
 	AddDetectedEntity(ctx context.Context, entity *models.DetectedEntity) error
 
+	// AddDetectedEntities adds many detected entities to a document in one transaction;
+	// either all of them are added or none.
+	//
+	// Parameters:
+	//   - ctx: Context for transaction and cancellation control
+	//   - documentID: The document the entities belong to
+	//   - entities: The detected entities to add
+	//
+	// Returns:
+	//   - NotFoundError if the document doesn't exist
+	//   - Other errors if addition fails
+	//
+	// The entity IDs will be populated after successful addition.
+	AddDetectedEntities(ctx context.Context, documentID int64, entities []*models.DetectedEntity) error
+
 	// DeleteDetectedEntity removes a detected entity.
 	//
 	// Parameters:
@@ -153,22 +257,259 @@ type DocumentRepository interface {
 	//   - NotFoundError if the document doesn't exist
 	//   - Other errors for database issues
 	GetDocumentSummary(ctx context.Context, documentID int64) (*models.DocumentSummary, error)
+
+	// GetTimeline retrieves the events of a document in chronological order with pagination.
+	//
+	// Parameters:
+	//   - ctx: Context for transaction and cancellation control
+	//   - documentID: The unique identifier of the document
+	//   - page: The page number (starting from 1)
+	//   - pageSize: The number of events per page
+	//
+	// Returns:
+	//   - The events for the requested page, oldest first
+	//   - The total count of events of the document
+	//   - An error if retrieval fails
+	GetTimeline(ctx context.Context, documentID int64, page, pageSize int) ([]*models.DocumentEvent, int, error)
+
+	// ReencryptLegacy moves document names and redaction schemas that are still encrypted
+	// with the shared master key to the data-encryption key of their tenant.
+	//
+	// Parameters:
+	//   - ctx: Context for transaction and cancellation control
+	//   - limit: The maximum number of rows per table to re-encrypt
+	//
+	// Returns:
+	//   - The number of re-encrypted rows
+	//   - An error if re-encryption fails; rows re-encrypted before the failure are kept
+	ReencryptLegacy(ctx context.Context, limit int) (int64, error)
+
+	// TransferOwnership moves a document to another user. Its name and redaction schemas are
+	// re-encrypted with the data-encryption key of the new owner, a share the new owner had
+	// on the document is removed, and the hashes of its detected values, which are keyed to
+	// the previous owner, are deleted. The document must not be archived.
+	//
+	// Parameters:
+	//   - ctx: Context for transaction and cancellation control
+	//   - documentID: The unique identifier of the document
+	//   - fromUserID: The ID of the current owner
+	//   - toUserID: The ID of the new owner
+	//   - storageKey: The key of the content re-encrypted for the new owner; empty if the
+	//     document has no stored content
+	//
+	// Returns:
+	//   - NotFoundError if the document doesn't exist or is not owned by fromUserID
+	//   - Other errors if the transfer fails; nothing is changed then
+	TransferOwnership(ctx context.Context, documentID, fromUserID, toUserID int64, storageKey string) error
+
+	// GetOutdatedSchemas retrieves documents whose redaction schema is older than a version.
+	//
+	// Parameters:
+	//   - ctx: Context for transaction and cancellation control
+	//   - version: The redaction schema version to compare with
+	//   - limit: The maximum number of documents to return
+	//
+	// Returns:
+	//   - The documents with their ID, owner, schema version and redaction schema
+	//   - An error if retrieval fails
+	GetOutdatedSchemas(ctx context.Context, version, limit int) ([]*models.Document, error)
+
+	// UpdateRedactionSchema replaces the redaction schema, schema version and entity summary
+	// of a document.
+	//
+	// Parameters:
+	//   - ctx: Context for transaction and cancellation control
+	//   - document: The document with the new redaction schema, schema version and entity summary
+	//
+	// Returns:
+	//   - NotFoundError if the document doesn't exist
+	//   - Other errors for database issues
+	//
+	// The LastModified timestamp is kept, as the content of the schema does not change.
+	UpdateRedactionSchema(ctx context.Context, document *models.Document) error
+
+	// CreateSchemaVersion adds a replaced redaction schema to the version history of a
+	// document, numbered after the latest version.
+	//
+	// Parameters:
+	//   - ctx: Context for transaction and cancellation control
+	//   - ownerID: The ID of the document owner, whose key seals the schema
+	//   - version: The version to store; its number and replacement time are set from the database
+	//
+	// Returns:
+	//   - An error if the version could not be stored
+	CreateSchemaVersion(ctx context.Context, ownerID int64, version *models.DocumentSchemaVersion) error
+
+	// GetSchemaVersions retrieves the version history of a document, newest first, without
+	// the schemas themselves.
+	//
+	// Parameters:
+	//   - ctx: Context for transaction and cancellation control
+	//   - documentID: The unique identifier of the document
+	//
+	// Returns:
+	//   - The versions of the document
+	//   - An error if retrieval fails
+	GetSchemaVersions(ctx context.Context, documentID int64) ([]*models.DocumentSchemaVersion, error)
+
+	// GetSchemaVersion retrieves a version of the redaction schema of a document.
+	//
+	// Parameters:
+	//   - ctx: Context for transaction and cancellation control
+	//   - ownerID: The ID of the document owner, whose key opens the schema
+	//   - documentID: The unique identifier of the document
+	//   - version: The number of the version
+	//
+	// Returns:
+	//   - The version with its schema
+	//   - NotFoundError if the document has no such version
+	//   - Other errors for database issues
+	GetSchemaVersion(ctx context.Context, ownerID, documentID int64, version int) (*models.DocumentSchemaVersion, error)
+
+	// GetMissingEntitySummaries retrieves documents stored before their detected entities
+	// were counted.
+	//
+	// Parameters:
+	//   - ctx: Context for transaction and cancellation control
+	//   - limit: The maximum number of documents to return
+	//
+	// Returns:
+	//   - The documents with their ID, owner and redaction schema
+	//   - An error if retrieval fails
+	GetMissingEntitySummaries(ctx context.Context, limit int) ([]*models.Document, error)
+
+	// UpdateEntitySummary stores the number and types of entities detected in a document.
+	//
+	// Parameters:
+	//   - ctx: Context for transaction and cancellation control
+	//   - document: The document with its entity count and entity types
+	//
+	// Returns:
+	//   - NotFoundError if the document doesn't exist
+	//   - Other errors for database issues
+	UpdateEntitySummary(ctx context.Context, document *models.Document) error
+
+	// DeleteExpired removes the documents whose retention period has ended, together
+	// with their detected entities. A document's retention ends at the retain_until set by
+	// a classification rule, or once it is older than its owner's document retention setting.
+	//
+	// Parameters:
+	//   - ctx: Context for transaction and cancellation control
+	//   - now: The moment to compare the retentions with
+	//   - limit: The maximum number of documents to delete, oldest retention first; 0 for no limit
+	//
+	// Returns:
+	//   - The number of deleted documents
+	//   - An error if deletion fails
+	DeleteExpired(ctx context.Context, now time.Time, limit int) (int64, error)
+
+	// CountExpired counts the documents whose retention period has ended without deleting them.
+	//
+	// Parameters:
+	//   - ctx: Context for transaction and cancellation control
+	//   - now: The moment to compare the retentions with
+	//
+	// Returns:
+	//   - The number of expired documents
+	//   - An error if counting fails
+	CountExpired(ctx context.Context, now time.Time) (int64, error)
+
+	// PreviewExpired lists the documents of a user whose retention period has ended, or would
+	// have under a document retention setting, without deleting them.
+	//
+	// Parameters:
+	//   - ctx: Context for transaction and cancellation control
+	//   - userID: The ID of the user whose documents to list
+	//   - now: The moment to compare the retentions with
+	//   - retentionDays: The document retention setting to apply; zero keeps documents
+	//   - limit: The maximum number of documents to list, by ID
+	//
+	// Returns:
+	//   - The expired documents and the number of them and their detected entities
+	//   - An error if retrieval fails
+	PreviewExpired(ctx context.Context, userID int64, now time.Time, retentionDays, limit int) (*models.RetentionPreview, error)
+
+	// ListStale retrieves the stale documents whose owners have not been told about them
+	// since they were last active. A document is stale when it has no detections at all or
+	// detected entities awaiting review, and it is neither archived nor exempt.
+	//
+	// Parameters:
+	//   - ctx: Context for transaction and cancellation control
+	//   - inactiveBefore: Only documents last active before this moment are returned
+	//   - limit: The maximum number of documents to return
+	//
+	// Returns:
+	//   - The stale documents, by ID
+	//   - An error if retrieval fails
+	ListStale(ctx context.Context, inactiveBefore time.Time, limit int) ([]*models.StaleDocument, error)
+
+	// ListStaleDue retrieves the stale documents whose owners were told about them before a
+	// moment and that have not been active since.
+	//
+	// Parameters:
+	//   - ctx: Context for transaction and cancellation control
+	//   - notifiedBefore: Only documents whose owners were told before this moment are returned
+	//   - limit: The maximum number of documents to return
+	//
+	// Returns:
+	//   - The stale documents, by ID
+	//   - An error if retrieval fails
+	ListStaleDue(ctx context.Context, notifiedBefore time.Time, limit int) ([]*models.StaleDocument, error)
+
+	// MarkStaleNotified records that the owners of documents were told they are stale.
+	//
+	// Parameters:
+	//   - ctx: Context for transaction and cancellation control
+	//   - documentIDs: The IDs of the documents
+	//   - at: The moment the owners were told
+	//
+	// Returns:
+	//   - An error if the update fails
+	MarkStaleNotified(ctx context.Context, documentIDs []int64, at time.Time) error
+
+	// SetLifecycleExempt exempts a document from stale document cleanup or makes it subject
+	// to it again. Either way, a notice already sent about the document no longer counts.
+	//
+	// Parameters:
+	//   - ctx: Context for transaction and cancellation control
+	//   - documentID: The unique identifier of the document
+	//   - exempt: Whether the document is kept however long it stays unfinished
+	//
+	// Returns:
+	//   - NotFoundError if the document doesn't exist
+	//   - Other errors for database issues
+	SetLifecycleExempt(ctx context.Context, documentID int64, exempt bool) error
 }
 
 // PostgresDocumentRepository is a PostgreSQL implementation of DocumentRepository.
 // It implements all required methods using PostgreSQL-specific features
 // and error handling.
+//
+// Document names and redaction schemas are encrypted with the data-encryption key of
+// the owning tenant, so deleting that key crypto-shreds them. Values written before
+// tenant keys existed are encrypted with the master key and remain readable until
+// ReencryptLegacy has moved them.
 type PostgresDocumentRepository struct {
 	db            *database.Pool
 	encryptionKey []byte
+	tenantKeys    TenantKeyRepository
 }
 
 // NewDocumentRepository creates a new DocumentRepository implementation for PostgreSQL.
-// Accepts an encryption key for document name encryption.
-func NewDocumentRepository(db *database.Pool, encryptionKey []byte) DocumentRepository {
+//
+// Parameters:
+//   - db: A connection pool for PostgreSQL database access
+//   - encryptionKey: The master key; used for values written before tenant keys existed
+//   - tenantKeys: Repository of the tenant data-encryption keys; if nil, all values are
+//     encrypted with the master key
+//
+// Returns:
+//   - An implementation of the DocumentRepository interface
+func NewDocumentRepository(db *database.Pool, encryptionKey []byte, tenantKeys TenantKeyRepository) DocumentRepository {
 	return &PostgresDocumentRepository{
 		db:            db,
 		encryptionKey: encryptionKey,
+		tenantKeys:    tenantKeys,
 	}
 }
 
@@ -188,14 +529,18 @@ func (r *PostgresDocumentRepository) Create(ctx context.Context, document *model
 	startTime := time.Now()
 
 	// Encrypt the document name before saving
-	encryptedName, err := utils.EncryptKey(document.HashedDocumentName, r.encryptionKey)
+	encryptedName, err := r.encryptForTenant(ctx, document.UserID, document.HashedDocumentName)
 	if err != nil {
 		return fmt.Errorf("failed to encrypt document name: %w", err)
 	}
+	redactionSchema, err := r.sealRedactionSchema(ctx, document.UserID, document.RedactionSchema)
+	if err != nil {
+		return fmt.Errorf("failed to encrypt redaction schema: %w", err)
+	}
 	// Define the query with RETURNING for PostgreSQL
 	query := `
-        INSERT INTO ` + constants.TableDocuments + ` (` + constants.ColumnUserID + `, hashed_document_name, upload_timestamp, last_modified, redaction_schema)
-        VALUES ($1, $2, $3, $4, $5)
+        INSERT INTO ` + constants.TableDocuments + ` (` + constants.ColumnUserID + `, hashed_document_name, upload_timestamp, last_modified, redaction_schema, language, ` + constants.ColumnSchemaVersion + `, tags, folder, source, ` + constants.ColumnRetainUntil + `, ` + constants.ColumnFilenameScrubbed + `, ` + constants.ColumnEntityCount + `, ` + constants.ColumnEntityTypes + `)
+        VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14)
         RETURNING ` + constants.ColumnDocumentID + `
     `
 
@@ -207,13 +552,22 @@ func (r *PostgresDocumentRepository) Create(ctx context.Context, document *model
 		encryptedName,
 		document.UploadTimestamp,
 		document.LastModified,
-		document.RedactionSchema,
+		redactionSchema,
+		document.Language,
+		document.SchemaVersion,
+		pq.Array(nonNilTags(document.Tags)),
+		document.Folder,
+		document.Source,
+		document.RetainUntil,
+		document.FilenameScrubbed,
+		document.EntityCount,
+		pq.Array(nonNilTags(document.EntityTypes)),
 	).Scan(&document.ID)
 
 	// Log the query execution
 	utils.LogDBQuery(
 		query,
-		[]interface{}{document.UserID, encryptedName, document.UploadTimestamp, document.LastModified, document.RedactionSchema},
+		[]interface{}{document.UserID, encryptedName, document.UploadTimestamp, document.LastModified, "redactionSchema", document.Language, document.SchemaVersion, document.Tags, document.Folder, document.Source, document.RetainUntil, document.FilenameScrubbed, document.EntityCount, document.EntityTypes},
 		time.Since(startTime),
 		err,
 	)
@@ -246,7 +600,7 @@ func (r *PostgresDocumentRepository) GetByID(ctx context.Context, id int64) (*mo
 
 	// Define the query
 	query := `
-        SELECT ` + constants.ColumnDocumentID + `, ` + constants.ColumnUserID + `, hashed_document_name, upload_timestamp, last_modified, redaction_schema
+        SELECT ` + constants.ColumnDocumentID + `, ` + constants.ColumnUserID + `, hashed_document_name, upload_timestamp, last_modified, redaction_schema, language, tags, folder, source, ` + constants.ColumnRetainUntil + `, ` + constants.ColumnFilenameScrubbed + `, ` + constants.ColumnArchivedAt + `
         FROM ` + constants.TableDocuments + `
         WHERE ` + constants.ColumnDocumentID + ` = $1
     `
@@ -260,6 +614,13 @@ func (r *PostgresDocumentRepository) GetByID(ctx context.Context, id int64) (*mo
 		&document.UploadTimestamp,
 		&document.LastModified,
 		&document.RedactionSchema,
+		&document.Language,
+		pq.Array(&document.Tags),
+		&document.Folder,
+		&document.Source,
+		&document.RetainUntil,
+		&document.FilenameScrubbed,
+		&document.ArchivedAt,
 	)
 
 	// Log the query execution
@@ -272,34 +633,75 @@ func (r *PostgresDocumentRepository) GetByID(ctx context.Context, id int64) (*mo
 
 	if err != nil {
 		if errors.Is(err, sql.ErrNoRows) {
-			return nil, utils.NewNotFoundError("Document", id)
+			return nil, utils.NewNotFoundError("Document", id).WithCause(err)
 		}
 		return nil, fmt.Errorf("failed to get document by ID: %w", err)
 	}
 
 	// Decrypt the document name before returning
-	decryptedName, err := utils.DecryptKey(document.HashedDocumentName, r.encryptionKey)
+	decryptedName, err := r.decryptForTenant(ctx, document.UserID, document.HashedDocumentName)
 	if err != nil {
 		return nil, fmt.Errorf("failed to decrypt document name: %w", err)
 	}
 
 	document.HashedDocumentName = decryptedName
+
+	if document.RedactionSchema, err = r.openRedactionSchema(ctx, document.UserID, document.RedactionSchema); err != nil {
+		return nil, fmt.Errorf("failed to decrypt redaction schema: %w", err)
+	}
+
 	return document, nil
 }
 
+// CountByUserID counts the documents stored for a user.
+//
+// Parameters:
+//   - ctx: Context for transaction and cancellation control
+//   - userID: The unique identifier of the user
+//
+// Returns:
+//   - The number of documents owned by the user
+//   - An error if counting fails
+func (r *PostgresDocumentRepository) CountByUserID(ctx context.Context, userID int64) (int64, error) {
+	// Start query timer
+	startTime := time.Now()
+
+	// Define the query
+	query := `SELECT COUNT(*) FROM ` + constants.TableDocuments + ` WHERE ` + constants.ColumnUserID + ` = $1`
+
+	// Execute the query
+	var count int64
+	err := r.db.QueryRowContext(ctx, query, userID).Scan(&count)
+
+	// Log the query execution
+	utils.LogDBQuery(
+		query,
+		[]interface{}{userID},
+		time.Since(startTime),
+		err,
+	)
+
+	if err != nil {
+		return 0, fmt.Errorf("failed to count documents: %w", err)
+	}
+
+	return count, nil
+}
+
 // GetByUserID retrieves all documents for a user with pagination.
 //
 // Parameters:
 //   - ctx: Context for transaction and cancellation control
 //   - userID: The unique identifier of the user
+//   - language: Only return documents in this language; empty for all documents
 //   - page: The page number (starting from 1)
 //   - pageSize: The number of documents per page
 //
 // Returns:
-//   - A slice of documents owned by the user for the requested page
-//   - The total count of documents owned by the user
+//   - A slice of matching documents owned by the user for the requested page
+//   - The total count of matching documents owned by the user
 //   - An error if retrieval fails
-func (r *PostgresDocumentRepository) GetByUserID(ctx context.Context, userID int64, page, pageSize int) ([]*models.Document, int, error) {
+func (r *PostgresDocumentRepository) GetByUserID(ctx context.Context, userID int64, language string, page, pageSize int) ([]*models.Document, int, error) {
 	// Start query timer
 	startTime := time.Now()
 
@@ -307,28 +709,28 @@ func (r *PostgresDocumentRepository) GetByUserID(ctx context.Context, userID int
 	offset := (page - 1) * pageSize
 
 	// Get total count
-	countQuery := `SELECT COUNT(*) FROM ` + constants.TableDocuments + ` WHERE ` + constants.ColumnUserID + ` = $1`
+	countQuery := `SELECT COUNT(*) FROM ` + constants.TableDocuments + ` WHERE ` + constants.ColumnUserID + ` = $1 AND ($2::text = '' OR language = $2)`
 	var totalCount int
-	if err := r.db.QueryRowContext(ctx, countQuery, userID).Scan(&totalCount); err != nil {
+	if err := r.db.QueryRowContext(ctx, countQuery, userID, language).Scan(&totalCount); err != nil {
 		return nil, 0, fmt.Errorf("failed to count documents: %w", err)
 	}
 
 	// Define the query
 	query := `
-        SELECT ` + constants.ColumnDocumentID + `, ` + constants.ColumnUserID + `, hashed_document_name, upload_timestamp, last_modified, redaction_schema
+        SELECT ` + constants.ColumnDocumentID + `, ` + constants.ColumnUserID + `, hashed_document_name, upload_timestamp, last_modified, redaction_schema, language, tags, folder, source, ` + constants.ColumnRetainUntil + `, ` + constants.ColumnFilenameScrubbed + `, ` + constants.ColumnArchivedAt + `
         FROM ` + constants.TableDocuments + `
-        WHERE ` + constants.ColumnUserID + ` = $1
+        WHERE ` + constants.ColumnUserID + ` = $1 AND ($2::text = '' OR language = $2)
         ORDER BY upload_timestamp DESC
-        LIMIT $2 OFFSET $3
+        LIMIT $3 OFFSET $4
     `
 
 	// Execute the query
-	rows, err := r.db.QueryContext(ctx, query, userID, pageSize, offset)
+	rows, err := r.db.QueryContext(ctx, query, userID, language, pageSize, offset)
 
 	// Log the query execution
 	utils.LogDBQuery(
 		query,
-		[]interface{}{userID, pageSize, offset},
+		[]interface{}{userID, language, pageSize, offset},
 		time.Since(startTime),
 		err,
 	)
@@ -353,17 +755,28 @@ func (r *PostgresDocumentRepository) GetByUserID(ctx context.Context, userID int
 			&document.UploadTimestamp,
 			&document.LastModified,
 			&document.RedactionSchema,
+			&document.Language,
+			pq.Array(&document.Tags),
+			&document.Folder,
+			&document.Source,
+			&document.RetainUntil,
+			&document.FilenameScrubbed,
+			&document.ArchivedAt,
 		); err != nil {
 			return nil, 0, fmt.Errorf("failed to scan document row: %w", err)
 		}
 
 		// Decrypt the document name before returning
-		decryptedName, err := utils.DecryptKey(document.HashedDocumentName, r.encryptionKey)
+		decryptedName, err := r.decryptForTenant(ctx, document.UserID, document.HashedDocumentName)
 		if err != nil {
 			return nil, 0, fmt.Errorf("failed to decrypt document name: %w", err)
 		}
 		document.HashedDocumentName = decryptedName
 
+		if document.RedactionSchema, err = r.openRedactionSchema(ctx, document.UserID, document.RedactionSchema); err != nil {
+			return nil, 0, fmt.Errorf("failed to decrypt redaction schema: %w", err)
+		}
+
 		documents = append(documents, document)
 	}
 
@@ -374,51 +787,243 @@ func (r *PostgresDocumentRepository) GetByUserID(ctx context.Context, userID int
 	return documents, totalCount, nil
 }
 
-// Update updates a document in the database.
-// This method automatically updates the LastModified timestamp.
+// GetByUserIDAfter retrieves the next documents of a user newest first, starting after a
+// keyset cursor. Documents uploaded at the same time are ordered by descending ID.
 //
 // Parameters:
 //   - ctx: Context for transaction and cancellation control
-//   - document: The document to update
+//   - userID: The unique identifier of the user
+//   - language: Only return documents in this language; empty for all documents
+//   - after: The last document of the previous page; nil for the first page
+//   - limit: The maximum number of documents to return
 //
 // Returns:
-//   - NotFoundError if the document doesn't exist
-//   - Other errors for database issues
-func (r *PostgresDocumentRepository) Update(ctx context.Context, document *models.Document) error {
-	// Start query timer
-	startTime := time.Now()
-
-	// Update the last modified timestamp
-	document.LastModified = time.Now()
-
-	// Define the query
-	query := `
-        UPDATE ` + constants.TableDocuments + `
-        SET last_modified = $1
-        WHERE ` + constants.ColumnDocumentID + ` = $2
-    `
-
-	// Execute the query
-	result, err := r.db.ExecContext(
-		ctx,
-		query,
-		document.LastModified,
-		document.ID,
-	)
-
-	// Log the query execution
-	utils.LogDBQuery(
-		query,
-		[]interface{}{document.LastModified, document.ID},
-		time.Since(startTime),
-		err,
-	)
+//   - The documents with their names decrypted (empty after the last one)
+//   - An error if retrieval fails
+func (r *PostgresDocumentRepository) GetByUserIDAfter(ctx context.Context, userID int64, language string, after *utils.KeysetCursor, limit int) ([]*models.Document, error) {
+	var afterTimestamp *time.Time
+	var afterID int64
+	if after != nil {
+		afterTimestamp, afterID = &after.Timestamp, after.ID
+	}
 
+	documents := []*models.Document{}
+	err := r.streamDocuments(ctx, `
+        SELECT `+streamedDocumentColumns+`
+        FROM `+constants.TableDocuments+`
+        WHERE `+constants.ColumnUserID+` = $1 AND ($2::text = '' OR language = $2)
+          AND ($3::timestamp IS NULL OR (upload_timestamp, `+constants.ColumnDocumentID+`) < ($3, $4))
+        ORDER BY upload_timestamp DESC, `+constants.ColumnDocumentID+` DESC
+        LIMIT $5
+    `, []interface{}{userID, language, afterTimestamp, afterID, limit}, func(document *models.Document) error {
+		documents = append(documents, document)
+		return nil
+	})
 	if err != nil {
-		return fmt.Errorf("failed to update document: %w", err)
+		return nil, err
 	}
 
-	// Check if any rows were affected
+	return documents, nil
+}
+
+// StreamByUserID passes all documents of a user to fn one at a time, newest first.
+// Rows are scanned and decrypted one by one, so exporting many documents never
+// requires holding the full list in memory.
+//
+// Parameters:
+//   - ctx: Context for transaction and cancellation control
+//   - userID: The unique identifier of the user
+//   - language: Only pass on documents in this language; empty for all documents
+//   - fn: Called for each document; returning an error stops the scan
+//
+// Returns:
+//   - The error returned by fn, or an error if retrieval fails
+func (r *PostgresDocumentRepository) StreamByUserID(ctx context.Context, userID int64, language string, fn func(*models.Document) error) error {
+	return r.streamDocuments(ctx, `
+        SELECT `+streamedDocumentColumns+`
+        FROM `+constants.TableDocuments+`
+        WHERE `+constants.ColumnUserID+` = $1 AND ($2::text = '' OR language = $2)
+        ORDER BY upload_timestamp DESC
+    `, []interface{}{userID, language}, fn)
+}
+
+// ListByUserIDAfter retrieves the next documents of a user in ascending ID order.
+//
+// Parameters:
+//   - ctx: Context for transaction and cancellation control
+//   - userID: The unique identifier of the user
+//   - afterID: Only return documents with a higher ID; 0 to start at the first
+//   - limit: The maximum number of documents to return
+//
+// Returns:
+//   - The documents with their names decrypted (empty after the last one)
+//   - An error if retrieval fails
+func (r *PostgresDocumentRepository) ListByUserIDAfter(ctx context.Context, userID, afterID int64, limit int) ([]*models.Document, error) {
+	documents := []*models.Document{}
+	err := r.streamDocuments(ctx, `
+        SELECT `+streamedDocumentColumns+`
+        FROM `+constants.TableDocuments+`
+        WHERE `+constants.ColumnUserID+` = $1 AND `+constants.ColumnDocumentID+` > $2
+        ORDER BY `+constants.ColumnDocumentID+`
+        LIMIT $3
+    `, []interface{}{userID, afterID, limit}, func(document *models.Document) error {
+		documents = append(documents, document)
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return documents, nil
+}
+
+// SearchByUserID passes the documents of a user matching the upload date and entity filters
+// to fn one at a time, newest first.
+//
+// Parameters:
+//   - ctx: Context for transaction and cancellation control
+//   - userID: The unique identifier of the user
+//   - filter: The upload date range, entity types and entity count to match
+//   - fn: Called for each matching document; returning an error stops the scan
+//
+// Returns:
+//   - The error returned by fn, or an error if retrieval fails
+func (r *PostgresDocumentRepository) SearchByUserID(ctx context.Context, userID int64, filter models.DocumentSearchFilter, fn func(*models.Document) error) error {
+	return r.streamDocuments(ctx, `
+        SELECT `+streamedDocumentColumns+`
+        FROM `+constants.TableDocuments+`
+        WHERE `+constants.ColumnUserID+` = $1
+          AND ($2::timestamp IS NULL OR upload_timestamp >= $2)
+          AND ($3::timestamp IS NULL OR upload_timestamp < $3)
+          AND (cardinality($4::text[]) = 0 OR `+constants.ColumnEntityTypes+` @> $4)
+          AND ($5::int IS NULL OR `+constants.ColumnEntityCount+` >= $5)
+          AND ($6::int IS NULL OR `+constants.ColumnEntityCount+` <= $6)
+        ORDER BY upload_timestamp DESC, `+constants.ColumnDocumentID+` DESC
+    `, []interface{}{userID, filter.UploadedFrom, filter.UploadedTo, pq.Array(nonNilTags(filter.EntityTypes)), filter.MinEntities, filter.MaxEntities}, fn)
+}
+
+// streamedDocumentColumns are the columns selected for documents passed to streamDocuments.
+const streamedDocumentColumns = constants.ColumnDocumentID + `, ` + constants.ColumnUserID + `, hashed_document_name, upload_timestamp, last_modified, redaction_schema, language, tags, folder, source, ` + constants.ColumnRetainUntil + `, ` + constants.ColumnFilenameScrubbed + `, ` + constants.ColumnArchivedAt
+
+// streamDocuments runs a query selecting streamedDocumentColumns and passes each document to fn
+// as soon as it is scanned and decrypted.
+func (r *PostgresDocumentRepository) streamDocuments(ctx context.Context, query string, args []interface{}, fn func(*models.Document) error) error {
+	// Start query timer
+	startTime := time.Now()
+
+	// Execute the query
+	rows, err := r.db.QueryContext(ctx, query, args...)
+
+	// Log the query execution
+	utils.LogDBQuery(
+		query,
+		args,
+		time.Since(startTime),
+		err,
+	)
+
+	if err != nil {
+		return fmt.Errorf("failed to get documents by user ID: %w", err)
+	}
+	defer func() {
+		if closeErr := rows.Close(); closeErr != nil {
+			log.Error().Err(closeErr).Msg("failed to close rows")
+		}
+	}()
+
+	// Pass each row on as soon as it is scanned, stopping once the caller goes away
+	for rows.Next() {
+		if err := checkContext(ctx); err != nil {
+			return fmt.Errorf("document stream stopped: %w", err)
+		}
+
+		document := &models.Document{}
+		if err := rows.Scan(
+			&document.ID,
+			&document.UserID,
+			&document.HashedDocumentName,
+			&document.UploadTimestamp,
+			&document.LastModified,
+			&document.RedactionSchema,
+			&document.Language,
+			pq.Array(&document.Tags),
+			&document.Folder,
+			&document.Source,
+			&document.RetainUntil,
+			&document.FilenameScrubbed,
+			&document.ArchivedAt,
+		); err != nil {
+			return fmt.Errorf("failed to scan document row: %w", err)
+		}
+
+		// Decrypt the document name before passing it on
+		decryptedName, err := r.decryptForTenant(ctx, document.UserID, document.HashedDocumentName)
+		if err != nil {
+			return fmt.Errorf("failed to decrypt document name: %w", err)
+		}
+		document.HashedDocumentName = decryptedName
+
+		if document.RedactionSchema, err = r.openRedactionSchema(ctx, document.UserID, document.RedactionSchema); err != nil {
+			return fmt.Errorf("failed to decrypt redaction schema: %w", err)
+		}
+
+		if err := fn(document); err != nil {
+			return err
+		}
+	}
+
+	if err := rows.Err(); err != nil {
+		return fmt.Errorf("error iterating document rows: %w", err)
+	}
+
+	return nil
+}
+
+// Update updates a document in the database.
+// This method automatically updates the LastModified timestamp.
+//
+// Parameters:
+//   - ctx: Context for transaction and cancellation control
+//   - document: The document to update
+//
+// Returns:
+//   - NotFoundError if the document doesn't exist
+//   - Other errors for database issues
+func (r *PostgresDocumentRepository) Update(ctx context.Context, document *models.Document) error {
+	// Start query timer
+	startTime := time.Now()
+
+	// Update the last modified timestamp
+	document.LastModified = time.Now()
+
+	// Define the query
+	query := `
+        UPDATE ` + constants.TableDocuments + `
+        SET last_modified = $1
+        WHERE ` + constants.ColumnDocumentID + ` = $2
+    `
+
+	// Execute the query
+	result, err := r.db.ExecContext(
+		ctx,
+		query,
+		document.LastModified,
+		document.ID,
+	)
+
+	// Log the query execution
+	utils.LogDBQuery(
+		query,
+		[]interface{}{document.LastModified, document.ID},
+		time.Since(startTime),
+		err,
+	)
+
+	if err != nil {
+		return fmt.Errorf("failed to update document: %w", err)
+	}
+
+	// Check if any rows were affected
 	rowsAffected, err := result.RowsAffected()
 	if err != nil {
 		return fmt.Errorf("failed to get rows affected: %w", err)
@@ -571,38 +1176,64 @@ func (r *PostgresDocumentRepository) DeleteByUserID(ctx context.Context, userID
 	})
 }
 
-// GetDetectedEntities retrieves all detected entities for a document.
-// It also decrypts the redaction schemas before returning.
+// GetDetectedEntities retrieves the detected entities of a document selected by a filter,
+// newest first. Entities detected at the same time are ordered by descending ID, so a
+// filter can page through them with a keyset cursor.
+// It joins with the detection_methods table to include method information.
 //
 // Parameters:
 //   - ctx: Context for transaction and cancellation control
 //   - documentID: The unique identifier of the document
+//   - filter: The review status, detection method and keyset page to select; the zero filter selects all
 //
 // Returns:
 //   - A slice of detected entities with their associated detection methods
-//   - An empty slice if the document has no detected entities
+//   - An empty slice if the document has no matching detected entities
 //   - An error if retrieval fails
-func (r *PostgresDocumentRepository) GetDetectedEntities(ctx context.Context, documentID int64) ([]*models.DetectedEntityWithMethod, error) {
+func (r *PostgresDocumentRepository) GetDetectedEntities(ctx context.Context, documentID int64, filter models.DetectedEntityFilter) ([]*models.DetectedEntityWithMethod, error) {
 	// Start query timer
 	startTime := time.Now()
 
+	// Only filters that are set add conditions
+	conditions := []string{"de." + constants.ColumnDocumentID + " = $1"}
+	args := []interface{}{documentID}
+	if filter.Status != "" {
+		args = append(args, filter.Status)
+		conditions = append(conditions, fmt.Sprintf("de.status = $%d", len(args)))
+	}
+	if filter.MethodID != 0 {
+		args = append(args, filter.MethodID)
+		conditions = append(conditions, fmt.Sprintf("de.%s = $%d", constants.ColumnMethodID, len(args)))
+	}
+	if filter.After != nil {
+		args = append(args, filter.After.Timestamp, filter.After.ID)
+		conditions = append(conditions, fmt.Sprintf("(de.detected_timestamp, de.%s) < ($%d, $%d)", constants.ColumnEntityID, len(args)-1, len(args)))
+	}
+	limit := ""
+	if filter.Limit > 0 {
+		args = append(args, filter.Limit)
+		limit = fmt.Sprintf("LIMIT $%d", len(args))
+	}
+
 	// Define the query
 	query := `
         SELECT de.` + constants.ColumnEntityID + `, de.` + constants.ColumnDocumentID + `, de.` + constants.ColumnMethodID + `, de.` + constants.ColumnEntityName + `, de.redaction_schema, de.detected_timestamp,
+               de.status, de.reviewed_by, de.reviewed_at,
                dm.` + constants.ColumnMethodName + `, dm.` + constants.ColumnHighlightColor + `
         FROM ` + constants.TableDetectedEntities + ` de
         JOIN ` + constants.TableDetectionMethods + ` dm ON de.` + constants.ColumnMethodID + ` = dm.` + constants.ColumnMethodID + `
-        WHERE de.` + constants.ColumnDocumentID + ` = $1
-        ORDER BY de.detected_timestamp DESC
+        WHERE ` + strings.Join(conditions, " AND ") + `
+        ORDER BY de.detected_timestamp DESC, de.` + constants.ColumnEntityID + ` DESC
+        ` + limit + `
     `
 
 	// Execute the query
-	rows, err := r.db.QueryContext(ctx, query, documentID)
+	rows, err := r.db.QueryContext(ctx, query, args...)
 
 	// Log the query execution
 	utils.LogDBQuery(
 		query,
-		[]interface{}{documentID},
+		args,
 		time.Since(startTime),
 		err,
 	)
@@ -616,12 +1247,15 @@ func (r *PostgresDocumentRepository) GetDetectedEntities(ctx context.Context, do
 		}
 	}()
 
-	// Parse the results
+	// Parse the results; the owner is only looked up once a tenant-encrypted schema is found
 	var entities []*models.DetectedEntityWithMethod
+	var ownerID int64
 	for rows.Next() {
 		entity := &models.DetectedEntityWithMethod{
 			DetectedEntity: models.DetectedEntity{},
 		}
+		var reviewedBy sql.NullInt64
+		var reviewedAt sql.NullTime
 		if err := rows.Scan(
 			&entity.ID,
 			&entity.DocumentID,
@@ -629,14 +1263,29 @@ func (r *PostgresDocumentRepository) GetDetectedEntities(ctx context.Context, do
 			&entity.EntityName,
 			&entity.RedactionSchema,
 			&entity.DetectedTimestamp,
+			&entity.Status,
+			&reviewedBy,
+			&reviewedAt,
 			&entity.MethodName,
 			&entity.HighlightColor,
 		); err != nil {
 			return nil, fmt.Errorf("failed to scan detected entity row: %w", err)
 		}
+		if reviewedBy.Valid {
+			entity.ReviewedBy = &reviewedBy.Int64
+		}
+		if reviewedAt.Valid {
+			entity.ReviewedAt = &reviewedAt.Time
+		}
+
+		if ownerID == 0 && strings.HasPrefix(entity.RedactionSchema.EncryptedData, constants.TenantCiphertextPrefix) {
+			if ownerID, err = r.documentOwner(ctx, documentID); err != nil {
+				return nil, err
+			}
+		}
 
 		// Decrypt the redaction schema
-		if err := entity.DecryptRedactionSchema(r.encryptionKey); err != nil {
+		if err := r.decryptRedactionSchema(ctx, ownerID, &entity.DetectedEntity); err != nil {
 			return nil, fmt.Errorf("failed to decrypt redaction schema for entity %d: %w", entity.ID, err)
 		}
 
@@ -666,8 +1315,15 @@ func (r *PostgresDocumentRepository) AddDetectedEntity(ctx context.Context, enti
 	// Start query timer
 	startTime := time.Now()
 
-	// Encrypt the redaction schema before storing
-	if err := entity.EncryptRedactionSchema(r.encryptionKey); err != nil {
+	// Encrypt the redaction schema with the key of the document's owner before storing
+	var ownerID int64
+	if r.tenantKeys != nil {
+		var err error
+		if ownerID, err = r.documentOwner(ctx, entity.DocumentID); err != nil {
+			return err
+		}
+	}
+	if err := r.encryptRedactionSchema(ctx, ownerID, entity); err != nil {
 		return fmt.Errorf("failed to encrypt redaction schema: %w", err)
 	}
 
@@ -711,6 +1367,109 @@ func (r *PostgresDocumentRepository) AddDetectedEntity(ctx context.Context, enti
 	return nil
 }
 
+// AddDetectedEntities adds many detected entities to a document in one transaction, with
+// multi-row inserts of constants.DetectedEntityInsertRows entities each.
+//
+// Parameters:
+//   - ctx: Context for transaction and cancellation control
+//   - documentID: The document the entities belong to
+//   - entities: The detected entities to add
+//
+// Returns:
+//   - NotFoundError if the document doesn't exist
+//   - Other errors if addition fails; no entity is added then
+//
+// The entity IDs will be populated after successful addition.
+func (r *PostgresDocumentRepository) AddDetectedEntities(ctx context.Context, documentID int64, entities []*models.DetectedEntity) error {
+	if len(entities) == 0 {
+		return nil
+	}
+
+	// Start query timer
+	startTime := time.Now()
+
+	// Encrypt the redaction schemas with the key of the document's owner before storing
+	var ownerID int64
+	if r.tenantKeys != nil {
+		var err error
+		if ownerID, err = r.documentOwner(ctx, documentID); err != nil {
+			return err
+		}
+	}
+	for _, entity := range entities {
+		entity.DocumentID = documentID
+		if err := r.encryptRedactionSchema(ctx, ownerID, entity); err != nil {
+			return fmt.Errorf("failed to encrypt redaction schema: %w", err)
+		}
+	}
+
+	err := r.db.Transaction(ctx, func(tx *sql.Tx) error {
+		for start := 0; start < len(entities); start += constants.DetectedEntityInsertRows {
+			chunk := entities[start:min(start+constants.DetectedEntityInsertRows, len(entities))]
+
+			// One row of placeholders per entity
+			values := make([]string, len(chunk))
+			args := make([]interface{}, 0, len(chunk)*5)
+			for i, entity := range chunk {
+				n := i * 5
+				values[i] = fmt.Sprintf("($%d, $%d, $%d, $%d, $%d)", n+1, n+2, n+3, n+4, n+5)
+				args = append(args, entity.DocumentID, entity.MethodID, entity.EntityName, entity.RedactionSchema, entity.DetectedTimestamp)
+			}
+
+			query := `
+                INSERT INTO ` + constants.TableDetectedEntities + ` (` + constants.ColumnDocumentID + `, ` + constants.ColumnMethodID + `, ` + constants.ColumnEntityName + `, redaction_schema, detected_timestamp)
+                VALUES ` + strings.Join(values, ", ") + `
+                RETURNING ` + constants.ColumnEntityID + `
+            `
+			rows, err := tx.QueryContext(ctx, query, args...)
+			if err != nil {
+				return fmt.Errorf("failed to create detected entities: %w", err)
+			}
+
+			// The IDs are returned in the order of the rows
+			i := 0
+			for rows.Next() {
+				if i >= len(chunk) {
+					break
+				}
+				if err := rows.Scan(&chunk[i].ID); err != nil {
+					rows.Close()
+					return fmt.Errorf("failed to scan detected entity ID: %w", err)
+				}
+				i++
+			}
+			if err := rows.Err(); err != nil {
+				rows.Close()
+				return fmt.Errorf("error iterating detected entity IDs: %w", err)
+			}
+			rows.Close()
+			if i != len(chunk) {
+				return fmt.Errorf("failed to create detected entities: %d of %d rows inserted", i, len(chunk))
+			}
+		}
+		return nil
+	})
+
+	// Log the operation (without sensitive data)
+	utils.LogDBQuery(
+		"Added detected entities",
+		[]interface{}{documentID, len(entities)},
+		time.Since(startTime),
+		err,
+	)
+
+	if err != nil {
+		return err
+	}
+
+	log.Info().
+		Int64(constants.ColumnDocumentID, documentID).
+		Int("count", len(entities)).
+		Msg("Detected entities created")
+
+	return nil
+}
+
 // DeleteDetectedEntity removes a detected entity from the database.
 //
 // Parameters:
@@ -759,6 +1518,74 @@ func (r *PostgresDocumentRepository) DeleteDetectedEntity(ctx context.Context, e
 	return nil
 }
 
+// UpdateEntityStatus changes the review status of detected entities of a document in one
+// transaction; if any of them doesn't belong to the document, none is changed.
+//
+// Parameters:
+//   - ctx: Context for transaction and cancellation control
+//   - documentID: The document the entities belong to
+//   - entityIDs: The entities to change, without duplicates
+//   - status: The new review status
+//   - reviewedBy: The user changing the status
+//
+// Returns:
+//   - The time the status was changed
+//   - NotFoundError if an entity doesn't exist or belongs to another document
+//   - Other errors if the update fails
+func (r *PostgresDocumentRepository) UpdateEntityStatus(ctx context.Context, documentID int64, entityIDs []int64, status models.EntityReviewStatus, reviewedBy int64) (time.Time, error) {
+	reviewedAt := time.Now()
+
+	err := r.db.Transaction(ctx, func(tx *sql.Tx) error {
+		// Start query timer
+		startTime := time.Now()
+
+		// Define the query
+		query := `
+            UPDATE ` + constants.TableDetectedEntities + `
+            SET status = $1, reviewed_by = $2, reviewed_at = $3
+            WHERE ` + constants.ColumnDocumentID + ` = $4 AND ` + constants.ColumnEntityID + ` = ANY($5)
+        `
+
+		// Execute the query
+		result, err := tx.ExecContext(ctx, query, status, reviewedBy, reviewedAt, documentID, pq.Array(entityIDs))
+
+		// Log the query execution
+		utils.LogDBQuery(
+			query,
+			[]interface{}{status, reviewedBy, reviewedAt, documentID, entityIDs},
+			time.Since(startTime),
+			err,
+		)
+
+		if err != nil {
+			return fmt.Errorf("failed to update detected entity status: %w", err)
+		}
+
+		// Every entity must have been changed, otherwise the transaction is rolled back
+		rowsAffected, err := result.RowsAffected()
+		if err != nil {
+			return fmt.Errorf("failed to get rows affected: %w", err)
+		}
+
+		if rowsAffected != int64(len(entityIDs)) {
+			return utils.NewNotFoundError("DetectedEntity", entityIDs)
+		}
+
+		return nil
+	})
+	if err != nil {
+		return time.Time{}, err
+	}
+
+	log.Info().
+		Int64(constants.ColumnDocumentID, documentID).
+		Int("entity_count", len(entityIDs)).
+		Str("status", string(status)).
+		Msg("Detected entity status updated")
+
+	return reviewedAt, nil
+}
+
 // GetDocumentSummary retrieves a summary of a document including the entity count.
 // This method performs a join between the documents table and detected entities table
 // to count entities in a single query.
@@ -777,8 +1604,8 @@ func (r *PostgresDocumentRepository) GetDocumentSummary(ctx context.Context, doc
 
 	// Define the query
 	query := `
-        SELECT d.` + constants.ColumnDocumentID + `, d.hashed_document_name, d.upload_timestamp, d.last_modified,
-               COUNT(de.` + constants.ColumnEntityID + `) AS entity_count
+        SELECT d.` + constants.ColumnDocumentID + `, d.hashed_document_name, d.upload_timestamp, d.last_modified, d.language,
+               d.tags, d.folder, d.` + constants.ColumnRetainUntil + `, d.` + constants.ColumnFilenameScrubbed + `, d.` + constants.ColumnArchivedAt + `, COUNT(de.` + constants.ColumnEntityID + `) AS entity_count
         FROM ` + constants.TableDocuments + ` d
         LEFT JOIN ` + constants.TableDetectedEntities + ` de ON d.` + constants.ColumnDocumentID + ` = de.` + constants.ColumnDocumentID + `
         WHERE d.` + constants.ColumnDocumentID + ` = $1
@@ -792,6 +1619,12 @@ func (r *PostgresDocumentRepository) GetDocumentSummary(ctx context.Context, doc
 		&summary.HashedName,
 		&summary.UploadTimestamp,
 		&summary.LastModified,
+		&summary.Language,
+		pq.Array(&summary.Tags),
+		&summary.Folder,
+		&summary.RetainUntil,
+		&summary.FilenameScrubbed,
+		&summary.ArchivedAt,
 		&summary.EntityCount,
 	)
 
@@ -805,17 +1638,1393 @@ func (r *PostgresDocumentRepository) GetDocumentSummary(ctx context.Context, doc
 
 	if err != nil {
 		if errors.Is(err, sql.ErrNoRows) {
-			return nil, utils.NewNotFoundError("Document", documentID)
+			return nil, utils.NewNotFoundError("Document", documentID).WithCause(err)
 		}
 		return nil, fmt.Errorf("failed to get document summary: %w", err)
 	}
 
 	// Decrypt the document name before returning
-	decryptedName, err := utils.DecryptKey(summary.HashedName, r.encryptionKey)
+	var ownerID int64
+	if strings.HasPrefix(summary.HashedName, constants.TenantCiphertextPrefix) {
+		if ownerID, err = r.documentOwner(ctx, documentID); err != nil {
+			return nil, err
+		}
+	}
+	decryptedName, err := r.decryptForTenant(ctx, ownerID, summary.HashedName)
 	if err != nil {
 		return nil, fmt.Errorf("failed to decrypt document name: %w", err)
 	}
 	summary.HashedName = decryptedName
+	summary.StorageTier = models.StorageTierOf(summary.ArchivedAt)
 
 	return summary, nil
 }
+
+// documentEventsQuery assembles the events of a document from the document itself and its
+// detected entities. Entities detected by the same method within the same second form one event.
+const documentEventsQuery = `
+        SELECT '` + string(models.EventDocumentUploaded) + `' AS event_type, upload_timestamp AS occurred_at, 0 AS entity_count, '' AS method_name
+        FROM ` + constants.TableDocuments + `
+        WHERE ` + constants.ColumnDocumentID + ` = $1
+        UNION ALL
+        SELECT '` + string(models.EventEntitiesDetected) + `', date_trunc('second', de.detected_timestamp), COUNT(*), dm.` + constants.ColumnMethodName + `
+        FROM ` + constants.TableDetectedEntities + ` de
+        JOIN ` + constants.TableDetectionMethods + ` dm ON dm.` + constants.ColumnMethodID + ` = de.` + constants.ColumnMethodID + `
+        WHERE de.` + constants.ColumnDocumentID + ` = $1
+        GROUP BY date_trunc('second', de.detected_timestamp), dm.` + constants.ColumnMethodName + `
+        UNION ALL
+        SELECT '` + string(models.EventDocumentModified) + `', last_modified, 0, ''
+        FROM ` + constants.TableDocuments + `
+        WHERE ` + constants.ColumnDocumentID + ` = $1 AND last_modified > upload_timestamp
+`
+
+// GetTimeline retrieves the events of a document in chronological order with pagination.
+// Events are derived from the stored timestamps, so the timeline needs no separate event log.
+//
+// Parameters:
+//   - ctx: Context for transaction and cancellation control
+//   - documentID: The unique identifier of the document
+//   - page: The page number (starting from 1)
+//   - pageSize: The number of events per page
+//
+// Returns:
+//   - The events for the requested page, oldest first
+//   - The total count of events of the document
+//   - An error if retrieval fails
+func (r *PostgresDocumentRepository) GetTimeline(ctx context.Context, documentID int64, page, pageSize int) ([]*models.DocumentEvent, int, error) {
+	// Start query timer
+	startTime := time.Now()
+
+	// Calculate offset
+	offset := (page - 1) * pageSize
+
+	// Get total count
+	countQuery := `SELECT COUNT(*) FROM (` + documentEventsQuery + `) events`
+	var totalCount int
+	if err := r.db.QueryRowContext(ctx, countQuery, documentID).Scan(&totalCount); err != nil {
+		return nil, 0, fmt.Errorf("failed to count document events: %w", err)
+	}
+
+	// Define the query
+	query := `
+        SELECT event_type, occurred_at, entity_count, method_name
+        FROM (` + documentEventsQuery + `) events
+        ORDER BY occurred_at, event_type
+        LIMIT $2 OFFSET $3
+    `
+
+	// Execute the query
+	rows, err := r.db.QueryContext(ctx, query, documentID, pageSize, offset)
+
+	// Log the query execution
+	utils.LogDBQuery(
+		query,
+		[]interface{}{documentID, pageSize, offset},
+		time.Since(startTime),
+		err,
+	)
+
+	if err != nil {
+		return nil, 0, fmt.Errorf("failed to get document events: %w", err)
+	}
+	defer func() {
+		if closeErr := rows.Close(); closeErr != nil {
+			log.Error().Err(closeErr).Msg("failed to close rows")
+		}
+	}()
+
+	// Parse the results
+	events := []*models.DocumentEvent{}
+	for rows.Next() {
+		event := &models.DocumentEvent{}
+		if err := rows.Scan(&event.Type, &event.Timestamp, &event.EntityCount, &event.MethodName); err != nil {
+			return nil, 0, fmt.Errorf("failed to scan document event row: %w", err)
+		}
+		events = append(events, event)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, 0, fmt.Errorf("error iterating document event rows: %w", err)
+	}
+
+	return events, totalCount, nil
+}
+
+// ReencryptLegacy moves document names and redaction schemas that are still encrypted with
+// the shared master key to the data-encryption key of their tenant. Each row is updated only
+// if it was not changed in the meantime, so the task can run alongside regular writes.
+//
+// Parameters:
+//   - ctx: Context for transaction and cancellation control
+//   - limit: The maximum number of rows per table to re-encrypt
+//
+// Returns:
+//   - The number of re-encrypted rows
+//   - An error if re-encryption fails
+func (r *PostgresDocumentRepository) ReencryptLegacy(ctx context.Context, limit int) (int64, error) {
+	if r.tenantKeys == nil {
+		return 0, nil
+	}
+
+	documents, err := r.reencryptLegacyDocuments(ctx, limit)
+	if err != nil {
+		return documents, err
+	}
+
+	entities, err := r.reencryptLegacyRedactionSchemas(ctx, limit)
+	return documents + entities, err
+}
+
+// TransferOwnership moves a document to another user, re-encrypting it for the new owner.
+//
+// Parameters:
+//   - ctx: Context for transaction and cancellation control
+//   - documentID: The unique identifier of the document
+//   - fromUserID: The ID of the current owner
+//   - toUserID: The ID of the new owner
+//   - storageKey: The key of the content re-encrypted for the new owner; empty if none
+//
+// Returns:
+//   - NotFoundError if the document doesn't exist or is not owned by fromUserID
+//   - Other errors if the transfer fails; nothing is changed then
+func (r *PostgresDocumentRepository) TransferOwnership(ctx context.Context, documentID, fromUserID, toUserID int64, storageKey string) error {
+	// Start query timer
+	startTime := time.Now()
+
+	// Execute within a transaction
+	err := r.db.Transaction(ctx, func(tx *sql.Tx) error {
+		// Lock the document so it is not changed while it is re-encrypted
+		query := `
+            SELECT hashed_document_name, redaction_schema
+            FROM ` + constants.TableDocuments + `
+            WHERE ` + constants.ColumnDocumentID + ` = $1 AND ` + constants.ColumnUserID + ` = $2
+            FOR UPDATE
+        `
+		var encryptedName, redactionSchema string
+		if err := tx.QueryRowContext(ctx, query, documentID, fromUserID).Scan(&encryptedName, &redactionSchema); err != nil {
+			if errors.Is(err, sql.ErrNoRows) {
+				return utils.NewNotFoundError("Document", documentID).WithCause(err)
+			}
+			return fmt.Errorf("failed to get document: %w", err)
+		}
+
+		name, err := r.decryptForTenant(ctx, fromUserID, encryptedName)
+		if err != nil {
+			return fmt.Errorf("failed to decrypt document name: %w", err)
+		}
+		if encryptedName, err = r.encryptForTenant(ctx, toUserID, name); err != nil {
+			return fmt.Errorf("failed to encrypt document name: %w", err)
+		}
+		schema, err := r.openRedactionSchema(ctx, fromUserID, redactionSchema)
+		if err != nil {
+			return fmt.Errorf("failed to decrypt redaction schema: %w", err)
+		}
+		if redactionSchema, err = r.sealRedactionSchema(ctx, toUserID, schema); err != nil {
+			return fmt.Errorf("failed to encrypt redaction schema: %w", err)
+		}
+
+		updateQuery := `
+            UPDATE ` + constants.TableDocuments + `
+            SET ` + constants.ColumnUserID + ` = $1, hashed_document_name = $2, redaction_schema = $3
+            WHERE ` + constants.ColumnDocumentID + ` = $4
+        `
+		if _, err := tx.ExecContext(ctx, updateQuery, toUserID, encryptedName, redactionSchema, documentID); err != nil {
+			return fmt.Errorf("failed to update document owner: %w", err)
+		}
+
+		// Read the detected entities before updating them on the same connection
+		entitiesQuery := `
+            SELECT ` + constants.ColumnEntityID + `, redaction_schema
+            FROM ` + constants.TableDetectedEntities + `
+            WHERE ` + constants.ColumnDocumentID + ` = $1
+        `
+		rows, err := tx.QueryContext(ctx, entitiesQuery, documentID)
+		if err != nil {
+			return fmt.Errorf("failed to get detected entities: %w", err)
+		}
+		var entities []*models.DetectedEntity
+		for rows.Next() {
+			entity := &models.DetectedEntity{}
+			if err := rows.Scan(&entity.ID, &entity.RedactionSchema); err != nil {
+				rows.Close()
+				return fmt.Errorf("failed to scan detected entity row: %w", err)
+			}
+			entities = append(entities, entity)
+		}
+		if err := rows.Err(); err != nil {
+			rows.Close()
+			return fmt.Errorf("error iterating detected entity rows: %w", err)
+		}
+		rows.Close()
+
+		entityQuery := `
+            UPDATE ` + constants.TableDetectedEntities + `
+            SET redaction_schema = $1
+            WHERE ` + constants.ColumnEntityID + ` = $2
+        `
+		for _, entity := range entities {
+			if !entity.RedactionSchema.IsEncrypted {
+				continue
+			}
+			if err := r.decryptRedactionSchema(ctx, fromUserID, entity); err != nil {
+				return fmt.Errorf("failed to decrypt redaction schema of entity %d: %w", entity.ID, err)
+			}
+			if err := r.encryptRedactionSchema(ctx, toUserID, entity); err != nil {
+				return fmt.Errorf("failed to encrypt redaction schema of entity %d: %w", entity.ID, err)
+			}
+			if _, err := tx.ExecContext(ctx, entityQuery, entity.RedactionSchema, entity.ID); err != nil {
+				return fmt.Errorf("failed to re-encrypt redaction schema: %w", err)
+			}
+		}
+
+		// Earlier redaction schemas are sealed with the key of the owner as well
+		versionsQuery := `
+            SELECT version_id, redaction_schema
+            FROM ` + constants.TableDocumentSchemaVersions + `
+            WHERE ` + constants.ColumnDocumentID + ` = $1
+        `
+		rows, err = tx.QueryContext(ctx, versionsQuery, documentID)
+		if err != nil {
+			return fmt.Errorf("failed to get redaction schema versions: %w", err)
+		}
+		var versions []*models.DocumentSchemaVersion
+		for rows.Next() {
+			version := &models.DocumentSchemaVersion{}
+			if err := rows.Scan(&version.ID, &version.RedactionSchema); err != nil {
+				rows.Close()
+				return fmt.Errorf("failed to scan redaction schema version row: %w", err)
+			}
+			versions = append(versions, version)
+		}
+		if err := rows.Err(); err != nil {
+			rows.Close()
+			return fmt.Errorf("error iterating redaction schema version rows: %w", err)
+		}
+		rows.Close()
+
+		versionQuery := `
+            UPDATE ` + constants.TableDocumentSchemaVersions + `
+            SET redaction_schema = $1
+            WHERE version_id = $2
+        `
+		for _, version := range versions {
+			schema, err := r.openRedactionSchema(ctx, fromUserID, version.RedactionSchema)
+			if err != nil {
+				return fmt.Errorf("failed to decrypt redaction schema version %d: %w", version.ID, err)
+			}
+			if version.RedactionSchema, err = r.sealRedactionSchema(ctx, toUserID, schema); err != nil {
+				return fmt.Errorf("failed to encrypt redaction schema version %d: %w", version.ID, err)
+			}
+			if _, err := tx.ExecContext(ctx, versionQuery, version.RedactionSchema, version.ID); err != nil {
+				return fmt.Errorf("failed to re-encrypt redaction schema version: %w", err)
+			}
+		}
+
+		// The new owner no longer needs a share, and the hashes are keyed to the previous owner
+		shareQuery := `DELETE FROM ` + constants.TableDocumentShares + ` WHERE ` + constants.ColumnDocumentID + ` = $1 AND ` + constants.ColumnUserID + ` = $2`
+		if _, err := tx.ExecContext(ctx, shareQuery, documentID, toUserID); err != nil {
+			return fmt.Errorf("failed to delete share of new owner: %w", err)
+		}
+		hashQuery := `DELETE FROM ` + constants.TableEntityHashes + ` WHERE ` + constants.ColumnDocumentID + ` = $1`
+		if _, err := tx.ExecContext(ctx, hashQuery, documentID); err != nil {
+			return fmt.Errorf("failed to delete entity hashes: %w", err)
+		}
+
+		if storageKey != "" {
+			contentQuery := `UPDATE ` + constants.TableDocumentContents + ` SET storage_key = $1 WHERE ` + constants.ColumnDocumentID + ` = $2`
+			if _, err := tx.ExecContext(ctx, contentQuery, storageKey, documentID); err != nil {
+				return fmt.Errorf("failed to update document content: %w", err)
+			}
+		}
+
+		return nil
+	})
+
+	// Log the operation
+	utils.LogDBQuery(
+		"Transferred document ownership",
+		[]interface{}{documentID, fromUserID, toUserID},
+		time.Since(startTime),
+		err,
+	)
+
+	if err != nil {
+		return err
+	}
+
+	log.Info().
+		Int64(constants.ColumnDocumentID, documentID).
+		Int64("from_user_id", fromUserID).
+		Int64("to_user_id", toUserID).
+		Msg("Document ownership transferred")
+
+	return nil
+}
+
+// GetOutdatedSchemas retrieves documents whose redaction schema is older than a version.
+//
+// Parameters:
+//   - ctx: Context for transaction and cancellation control
+//   - version: The redaction schema version to compare with
+//   - limit: The maximum number of documents to return
+//
+// Returns:
+//   - The documents with their ID, owner, schema version and redaction schema
+//   - An error if retrieval fails
+func (r *PostgresDocumentRepository) GetOutdatedSchemas(ctx context.Context, version, limit int) ([]*models.Document, error) {
+	// Start query timer
+	startTime := time.Now()
+
+	// Define the query
+	query := `
+        SELECT ` + constants.ColumnDocumentID + `, ` + constants.ColumnUserID + `, redaction_schema, ` + constants.ColumnSchemaVersion + `
+        FROM ` + constants.TableDocuments + `
+        WHERE ` + constants.ColumnSchemaVersion + ` < $1 AND ` + constants.ColumnArchivedAt + ` IS NULL
+        ORDER BY ` + constants.ColumnDocumentID + `
+        LIMIT $2
+    `
+
+	// Execute the query
+	rows, err := r.db.QueryContext(ctx, query, version, limit)
+
+	// Log the query execution
+	utils.LogDBQuery(
+		query,
+		[]interface{}{version, limit},
+		time.Since(startTime),
+		err,
+	)
+
+	if err != nil {
+		return nil, fmt.Errorf("failed to get documents with outdated schemas: %w", err)
+	}
+	defer rows.Close()
+
+	var documents []*models.Document
+	for rows.Next() {
+		document := &models.Document{}
+		if err := rows.Scan(&document.ID, &document.UserID, &document.RedactionSchema, &document.SchemaVersion); err != nil {
+			return nil, fmt.Errorf("failed to scan document row: %w", err)
+		}
+		documents = append(documents, document)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating document rows: %w", err)
+	}
+
+	for _, document := range documents {
+		if document.RedactionSchema, err = r.openRedactionSchema(ctx, document.UserID, document.RedactionSchema); err != nil {
+			return nil, fmt.Errorf("failed to decrypt redaction schema of document %d: %w", document.ID, err)
+		}
+	}
+
+	return documents, nil
+}
+
+// UpdateRedactionSchema replaces the redaction schema, schema version and entity summary of a document.
+//
+// Parameters:
+//   - ctx: Context for transaction and cancellation control
+//   - document: The document with the new redaction schema, schema version and entity summary
+//
+// Returns:
+//   - NotFoundError if the document doesn't exist
+//   - Other errors for database issues
+func (r *PostgresDocumentRepository) UpdateRedactionSchema(ctx context.Context, document *models.Document) error {
+	// Start query timer
+	startTime := time.Now()
+
+	redactionSchema, err := r.sealRedactionSchema(ctx, document.UserID, document.RedactionSchema)
+	if err != nil {
+		return fmt.Errorf("failed to encrypt redaction schema: %w", err)
+	}
+
+	// Define the query
+	query := `
+        UPDATE ` + constants.TableDocuments + `
+        SET redaction_schema = $1, ` + constants.ColumnSchemaVersion + ` = $2, ` + constants.ColumnEntityCount + ` = $3, ` + constants.ColumnEntityTypes + ` = $4
+        WHERE ` + constants.ColumnDocumentID + ` = $5
+    `
+
+	// Execute the query
+	result, err := r.db.ExecContext(ctx, query, redactionSchema, document.SchemaVersion, document.EntityCount, pq.Array(nonNilTags(document.EntityTypes)), document.ID)
+
+	// Log the query execution
+	utils.LogDBQuery(
+		query,
+		[]interface{}{"redactionSchema", document.SchemaVersion, document.EntityCount, document.EntityTypes, document.ID},
+		time.Since(startTime),
+		err,
+	)
+
+	if err != nil {
+		return fmt.Errorf("failed to update redaction schema: %w", err)
+	}
+
+	// Check if any rows were affected
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("failed to get rows affected: %w", err)
+	}
+
+	if rowsAffected == 0 {
+		return utils.NewNotFoundError("Document", document.ID)
+	}
+
+	return nil
+}
+
+// CreateSchemaVersion adds a replaced redaction schema to the version history of a document.
+//
+// Parameters:
+//   - ctx: Context for transaction and cancellation control
+//   - ownerID: The ID of the document owner, whose key seals the schema
+//   - version: The version to store; its number and replacement time are set from the database
+//
+// Returns:
+//   - An error if the version could not be stored
+func (r *PostgresDocumentRepository) CreateSchemaVersion(ctx context.Context, ownerID int64, version *models.DocumentSchemaVersion) error {
+	// Start query timer
+	startTime := time.Now()
+
+	redactionSchema, err := r.sealRedactionSchema(ctx, ownerID, version.RedactionSchema)
+	if err != nil {
+		return fmt.Errorf("failed to encrypt redaction schema: %w", err)
+	}
+
+	// Define the query; the number follows the latest version of the document
+	query := `
+        INSERT INTO ` + constants.TableDocumentSchemaVersions + ` (` + constants.ColumnDocumentID + `, version, redaction_schema, entity_count, entity_types, replaced_by)
+        SELECT $1, COALESCE(MAX(version), 0) + 1, $2, $3, $4, $5
+        FROM ` + constants.TableDocumentSchemaVersions + `
+        WHERE ` + constants.ColumnDocumentID + ` = $1
+        RETURNING version, replaced_at
+    `
+
+	// Execute the query
+	err = r.db.QueryRowContext(ctx, query, version.DocumentID, redactionSchema, version.EntityCount, pq.Array(nonNilTags(version.EntityTypes)), version.ReplacedBy).
+		Scan(&version.Version, &version.ReplacedAt)
+
+	// Log the query execution
+	utils.LogDBQuery(
+		query,
+		[]interface{}{version.DocumentID, "redactionSchema", version.EntityCount, version.EntityTypes, version.ReplacedBy},
+		time.Since(startTime),
+		err,
+	)
+
+	if err != nil {
+		return fmt.Errorf("failed to create redaction schema version: %w", err)
+	}
+
+	return nil
+}
+
+// GetSchemaVersions retrieves the version history of a document, newest first, without
+// the schemas themselves.
+//
+// Parameters:
+//   - ctx: Context for transaction and cancellation control
+//   - documentID: The unique identifier of the document
+//
+// Returns:
+//   - The versions of the document
+//   - An error if retrieval fails
+func (r *PostgresDocumentRepository) GetSchemaVersions(ctx context.Context, documentID int64) ([]*models.DocumentSchemaVersion, error) {
+	// Start query timer
+	startTime := time.Now()
+
+	// Define the query
+	query := `
+        SELECT ` + constants.ColumnDocumentID + `, version, entity_count, entity_types, replaced_by, replaced_at
+        FROM ` + constants.TableDocumentSchemaVersions + `
+        WHERE ` + constants.ColumnDocumentID + ` = $1
+        ORDER BY version DESC
+    `
+
+	// Execute the query
+	rows, err := r.db.QueryContext(ctx, query, documentID)
+
+	// Log the query execution
+	utils.LogDBQuery(
+		query,
+		[]interface{}{documentID},
+		time.Since(startTime),
+		err,
+	)
+
+	if err != nil {
+		return nil, fmt.Errorf("failed to get redaction schema versions: %w", err)
+	}
+	defer func() {
+		if closeErr := rows.Close(); closeErr != nil {
+			log.Error().Err(closeErr).Msg("failed to close rows")
+		}
+	}()
+
+	// Parse the results
+	versions := []*models.DocumentSchemaVersion{}
+	for rows.Next() {
+		version := &models.DocumentSchemaVersion{}
+		if err := rows.Scan(&version.DocumentID, &version.Version, &version.EntityCount, pq.Array(&version.EntityTypes), &version.ReplacedBy, &version.ReplacedAt); err != nil {
+			return nil, fmt.Errorf("failed to scan redaction schema version row: %w", err)
+		}
+		versions = append(versions, version)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating redaction schema version rows: %w", err)
+	}
+
+	return versions, nil
+}
+
+// GetSchemaVersion retrieves a version of the redaction schema of a document.
+//
+// Parameters:
+//   - ctx: Context for transaction and cancellation control
+//   - ownerID: The ID of the document owner, whose key opens the schema
+//   - documentID: The unique identifier of the document
+//   - version: The number of the version
+//
+// Returns:
+//   - The version with its schema
+//   - NotFoundError if the document has no such version
+//   - Other errors for database issues
+func (r *PostgresDocumentRepository) GetSchemaVersion(ctx context.Context, ownerID, documentID int64, version int) (*models.DocumentSchemaVersion, error) {
+	// Start query timer
+	startTime := time.Now()
+
+	// Define the query
+	query := `
+        SELECT version_id, ` + constants.ColumnDocumentID + `, version, redaction_schema, entity_count, entity_types, replaced_by, replaced_at
+        FROM ` + constants.TableDocumentSchemaVersions + `
+        WHERE ` + constants.ColumnDocumentID + ` = $1 AND version = $2
+    `
+
+	// Execute the query
+	result := &models.DocumentSchemaVersion{}
+	err := r.db.QueryRowContext(ctx, query, documentID, version).Scan(
+		&result.ID,
+		&result.DocumentID,
+		&result.Version,
+		&result.RedactionSchema,
+		&result.EntityCount,
+		pq.Array(&result.EntityTypes),
+		&result.ReplacedBy,
+		&result.ReplacedAt,
+	)
+
+	// Log the query execution
+	utils.LogDBQuery(
+		query,
+		[]interface{}{documentID, version},
+		time.Since(startTime),
+		err,
+	)
+
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, utils.NewNotFoundError("Redaction schema version", version)
+		}
+		return nil, fmt.Errorf("failed to get redaction schema version: %w", err)
+	}
+
+	if result.RedactionSchema, err = r.openRedactionSchema(ctx, ownerID, result.RedactionSchema); err != nil {
+		return nil, fmt.Errorf("failed to decrypt redaction schema: %w", err)
+	}
+
+	return result, nil
+}
+
+// GetMissingEntitySummaries retrieves documents stored before their detected entities were counted.
+//
+// Parameters:
+//   - ctx: Context for transaction and cancellation control
+//   - limit: The maximum number of documents to return
+//
+// Returns:
+//   - The documents with their ID, owner and redaction schema
+//   - An error if retrieval fails
+func (r *PostgresDocumentRepository) GetMissingEntitySummaries(ctx context.Context, limit int) ([]*models.Document, error) {
+	// Start query timer
+	startTime := time.Now()
+
+	// Define the query
+	query := `
+        SELECT ` + constants.ColumnDocumentID + `, ` + constants.ColumnUserID + `, redaction_schema
+        FROM ` + constants.TableDocuments + `
+        WHERE ` + constants.ColumnEntityCount + ` IS NULL AND ` + constants.ColumnArchivedAt + ` IS NULL
+        ORDER BY ` + constants.ColumnDocumentID + `
+        LIMIT $1
+    `
+
+	// Execute the query
+	rows, err := r.db.QueryContext(ctx, query, limit)
+
+	// Log the query execution
+	utils.LogDBQuery(
+		query,
+		[]interface{}{limit},
+		time.Since(startTime),
+		err,
+	)
+
+	if err != nil {
+		return nil, fmt.Errorf("failed to get documents without entity summary: %w", err)
+	}
+	defer rows.Close()
+
+	var documents []*models.Document
+	for rows.Next() {
+		document := &models.Document{}
+		if err := rows.Scan(&document.ID, &document.UserID, &document.RedactionSchema); err != nil {
+			return nil, fmt.Errorf("failed to scan document row: %w", err)
+		}
+		documents = append(documents, document)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating document rows: %w", err)
+	}
+
+	for _, document := range documents {
+		if document.RedactionSchema, err = r.openRedactionSchema(ctx, document.UserID, document.RedactionSchema); err != nil {
+			return nil, fmt.Errorf("failed to decrypt redaction schema of document %d: %w", document.ID, err)
+		}
+	}
+
+	return documents, nil
+}
+
+// UpdateEntitySummary stores the number and types of entities detected in a document.
+//
+// Parameters:
+//   - ctx: Context for transaction and cancellation control
+//   - document: The document with its entity count and entity types
+//
+// Returns:
+//   - NotFoundError if the document doesn't exist
+//   - Other errors for database issues
+func (r *PostgresDocumentRepository) UpdateEntitySummary(ctx context.Context, document *models.Document) error {
+	// Start query timer
+	startTime := time.Now()
+
+	// Define the query
+	query := `
+        UPDATE ` + constants.TableDocuments + `
+        SET ` + constants.ColumnEntityCount + ` = $1, ` + constants.ColumnEntityTypes + ` = $2
+        WHERE ` + constants.ColumnDocumentID + ` = $3
+    `
+
+	// Execute the query
+	result, err := r.db.ExecContext(ctx, query, document.EntityCount, pq.Array(nonNilTags(document.EntityTypes)), document.ID)
+
+	// Log the query execution
+	utils.LogDBQuery(
+		query,
+		[]interface{}{document.EntityCount, document.EntityTypes, document.ID},
+		time.Since(startTime),
+		err,
+	)
+
+	if err != nil {
+		return fmt.Errorf("failed to update entity summary: %w", err)
+	}
+
+	// Check if any rows were affected
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("failed to get rows affected: %w", err)
+	}
+
+	if rowsAffected == 0 {
+		return utils.NewNotFoundError("Document", document.ID)
+	}
+
+	return nil
+}
+
+// expiredDocumentsCondition selects the documents whose retention has passed at $1: the
+// retain_until set by a classification rule at upload, or the owner's document retention setting.
+const expiredDocumentsCondition = `(` + constants.ColumnRetainUntil + ` <= $1 OR EXISTS (
+                SELECT 1 FROM ` + constants.TableUserSettings + ` s
+                WHERE s.` + constants.ColumnUserID + ` = ` + constants.TableDocuments + `.` + constants.ColumnUserID + `
+                AND s.document_retention_days > 0
+                AND ` + constants.TableDocuments + `.upload_timestamp + make_interval(days => s.document_retention_days) <= $1
+            ))`
+
+// DeleteExpired removes the documents whose retention period has ended, at most limit
+// of them unless limit is 0.
+//
+// Parameters:
+//   - ctx: Context for transaction and cancellation control
+//   - now: The moment to compare the retentions with
+//   - limit: The maximum number of documents to delete, oldest retention first; 0 for no limit
+//
+// Returns:
+//   - The number of deleted documents
+//   - An error if deletion fails
+func (r *PostgresDocumentRepository) DeleteExpired(ctx context.Context, now time.Time, limit int) (int64, error) {
+	// Start query timer
+	startTime := time.Now()
+
+	var deleted int64
+
+	// Execute the delete within a transaction to cascade properly
+	err := r.db.Transaction(ctx, func(tx *sql.Tx) error {
+		// The documents to delete, all expired ones or the batch expired the longest ago
+		expired := `SELECT ` + constants.ColumnDocumentID + ` FROM ` + constants.TableDocuments + ` WHERE ` + expiredDocumentsCondition
+		args := []interface{}{now}
+		if limit > 0 {
+			expired += ` ORDER BY ` + constants.ColumnRetainUntil + `, ` + constants.ColumnDocumentID + ` LIMIT $2 FOR UPDATE`
+			args = append(args, limit)
+		}
+
+		// First delete the detected entities of the expired documents
+		entitiesQuery := `
+            DELETE FROM ` + constants.TableDetectedEntities + `
+            WHERE ` + constants.ColumnDocumentID + ` IN (
+                ` + expired + `
+            )
+        `
+		if _, err := tx.ExecContext(ctx, entitiesQuery, args...); err != nil {
+			return fmt.Errorf("failed to delete detected entities of expired documents: %w", err)
+		}
+
+		// Then delete the documents themselves
+		documentQuery := "DELETE FROM " + constants.TableDocuments + " WHERE " + expiredDocumentsCondition
+		if limit > 0 {
+			documentQuery = "DELETE FROM " + constants.TableDocuments + " WHERE " + constants.ColumnDocumentID + " IN (" + expired + ")"
+		}
+		result, err := tx.ExecContext(ctx, documentQuery, args...)
+
+		// Log the query execution
+		utils.LogDBQuery(
+			documentQuery,
+			args,
+			time.Since(startTime),
+			err,
+		)
+
+		if err != nil {
+			return fmt.Errorf("failed to delete expired documents: %w", err)
+		}
+
+		deleted, err = result.RowsAffected()
+		if err != nil {
+			return fmt.Errorf("failed to get rows affected: %w", err)
+		}
+
+		return nil
+	})
+	if err != nil {
+		return 0, err
+	}
+
+	if deleted > 0 {
+		log.Info().
+			Int64("deleted", deleted).
+			Msg("Expired documents deleted")
+	}
+
+	return deleted, nil
+}
+
+// CountExpired counts the documents whose retention period has ended without deleting them.
+//
+// Parameters:
+//   - ctx: Context for transaction and cancellation control
+//   - now: The moment to compare the retentions with
+//
+// Returns:
+//   - The number of expired documents
+//   - An error if counting fails
+func (r *PostgresDocumentRepository) CountExpired(ctx context.Context, now time.Time) (int64, error) {
+	// Start query timer
+	startTime := time.Now()
+
+	// Define the query
+	query := "SELECT COUNT(*) FROM " + constants.TableDocuments + " WHERE " + expiredDocumentsCondition
+
+	// Execute the query
+	var count int64
+	err := r.db.QueryRowContext(ctx, query, now).Scan(&count)
+
+	// Log the query execution
+	utils.LogDBQuery(
+		query,
+		[]interface{}{now},
+		time.Since(startTime),
+		err,
+	)
+
+	if err != nil {
+		return 0, fmt.Errorf("failed to count expired documents: %w", err)
+	}
+
+	return count, nil
+}
+
+// PreviewExpired lists the documents of a user whose retention period has ended, or would
+// have under a document retention setting, without deleting them. The counts cover all
+// expired documents, also those beyond the limit.
+//
+// Parameters:
+//   - ctx: Context for transaction and cancellation control
+//   - userID: The ID of the user whose documents to list
+//   - now: The moment to compare the retentions with
+//   - retentionDays: The document retention setting to apply; zero keeps documents
+//   - limit: The maximum number of documents to list, by ID
+//
+// Returns:
+//   - The expired documents and the number of them and their detected entities
+//   - An error if retrieval fails
+func (r *PostgresDocumentRepository) PreviewExpired(ctx context.Context, userID int64, now time.Time, retentionDays, limit int) (*models.RetentionPreview, error) {
+	// Start query timer
+	startTime := time.Now()
+
+	// Define the query
+	query := `
+        SELECT ` + constants.ColumnDocumentID + `, upload_timestamp, ` + constants.ColumnRetainUntil + `, detected_entities,
+               COUNT(*) OVER (), COALESCE(SUM(detected_entities) OVER (), 0)
+        FROM (
+            SELECT d.` + constants.ColumnDocumentID + `, d.upload_timestamp, d.` + constants.ColumnRetainUntil + `,
+                   (SELECT COUNT(*) FROM ` + constants.TableDetectedEntities + ` de WHERE de.` + constants.ColumnDocumentID + ` = d.` + constants.ColumnDocumentID + `) AS detected_entities
+            FROM ` + constants.TableDocuments + ` d
+            WHERE d.` + constants.ColumnUserID + ` = $1
+            AND (d.` + constants.ColumnRetainUntil + ` <= $2 OR ($3::int > 0 AND d.upload_timestamp + make_interval(days => $3::int) <= $2))
+        ) expired
+        ORDER BY ` + constants.ColumnDocumentID + `
+        LIMIT $4
+    `
+
+	// Execute the query
+	rows, err := r.db.QueryContext(ctx, query, userID, now, retentionDays, limit)
+
+	// Log the query execution
+	utils.LogDBQuery(
+		query,
+		[]interface{}{userID, now, retentionDays, limit},
+		time.Since(startTime),
+		err,
+	)
+
+	if err != nil {
+		return nil, fmt.Errorf("failed to preview expired documents: %w", err)
+	}
+	defer rows.Close()
+
+	preview := &models.RetentionPreview{
+		RetentionDays: retentionDays,
+		Documents:     []*models.ExpiredDocument{},
+	}
+	for rows.Next() {
+		document := &models.ExpiredDocument{}
+		if err := rows.Scan(&document.DocumentID, &document.UploadTimestamp, &document.RetainUntil, &document.EntityCount, &preview.DocumentCount, &preview.EntityCount); err != nil {
+			return nil, fmt.Errorf("failed to scan expired document row: %w", err)
+		}
+		document.Reason = constants.RetentionReasonSetting
+		if document.RetainUntil != nil && !document.RetainUntil.After(now) {
+			document.Reason = constants.RetentionReasonRule
+		}
+		preview.Documents = append(preview.Documents, document)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating expired document rows: %w", err)
+	}
+
+	return preview, nil
+}
+
+// staleDocumentsQuery selects the stale documents matching a condition on their last
+// activity and stale notice; the condition's moment is $1 and the limit $2. A document is
+// last active when it is changed or an entity is detected in it or reviewed.
+const staleDocumentsQuery = `
+        SELECT ` + constants.ColumnDocumentID + `, ` + constants.ColumnUserID + `, reason, last_activity
+        FROM (
+            SELECT d.` + constants.ColumnDocumentID + `, d.` + constants.ColumnUserID + `, d.` + constants.ColumnStaleNotifiedAt + `,
+                   CASE WHEN COUNT(de.` + constants.ColumnEntityID + `) FILTER (WHERE de.status = '` + string(models.EntityStatusPending) + `') > 0
+                        THEN '` + constants.StaleReasonPendingReview + `' ELSE '` + constants.StaleReasonUnprocessed + `' END AS reason,
+                   GREATEST(d.last_modified, MAX(de.detected_timestamp), MAX(de.reviewed_at)) AS last_activity,
+                   COUNT(de.` + constants.ColumnEntityID + `) AS entities,
+                   COUNT(de.` + constants.ColumnEntityID + `) FILTER (WHERE de.status = '` + string(models.EntityStatusPending) + `') AS pending,
+                   COALESCE(d.` + constants.ColumnEntityCount + `, 0) AS schema_entities
+            FROM ` + constants.TableDocuments + ` d
+            LEFT JOIN ` + constants.TableDetectedEntities + ` de ON de.` + constants.ColumnDocumentID + ` = d.` + constants.ColumnDocumentID + `
+            WHERE d.` + constants.ColumnArchivedAt + ` IS NULL AND NOT d.` + constants.ColumnLifecycleExempt + `
+            GROUP BY d.` + constants.ColumnDocumentID + `
+        ) stale
+        WHERE (pending > 0 OR (entities = 0 AND schema_entities = 0)) AND %s
+        ORDER BY ` + constants.ColumnDocumentID + `
+        LIMIT $2
+    `
+
+// ListStale retrieves the stale documents whose owners have not been told about them
+// since they were last active.
+//
+// Parameters:
+//   - ctx: Context for transaction and cancellation control
+//   - inactiveBefore: Only documents last active before this moment are returned
+//   - limit: The maximum number of documents to return
+//
+// Returns:
+//   - The stale documents, by ID
+//   - An error if retrieval fails
+func (r *PostgresDocumentRepository) ListStale(ctx context.Context, inactiveBefore time.Time, limit int) ([]*models.StaleDocument, error) {
+	condition := `last_activity < $1 AND (` + constants.ColumnStaleNotifiedAt + ` IS NULL OR ` + constants.ColumnStaleNotifiedAt + ` < last_activity)`
+	return r.listStale(ctx, condition, inactiveBefore, limit)
+}
+
+// ListStaleDue retrieves the stale documents whose owners were told about them before a
+// moment and that have not been active since.
+//
+// Parameters:
+//   - ctx: Context for transaction and cancellation control
+//   - notifiedBefore: Only documents whose owners were told before this moment are returned
+//   - limit: The maximum number of documents to return
+//
+// Returns:
+//   - The stale documents, by ID
+//   - An error if retrieval fails
+func (r *PostgresDocumentRepository) ListStaleDue(ctx context.Context, notifiedBefore time.Time, limit int) ([]*models.StaleDocument, error) {
+	condition := constants.ColumnStaleNotifiedAt + ` < $1 AND last_activity <= ` + constants.ColumnStaleNotifiedAt
+	return r.listStale(ctx, condition, notifiedBefore, limit)
+}
+
+// listStale retrieves the stale documents matching a condition of staleDocumentsQuery.
+func (r *PostgresDocumentRepository) listStale(ctx context.Context, condition string, moment time.Time, limit int) ([]*models.StaleDocument, error) {
+	// Start query timer
+	startTime := time.Now()
+
+	// Define the query
+	query := fmt.Sprintf(staleDocumentsQuery, condition)
+
+	// Execute the query
+	rows, err := r.db.QueryContext(ctx, query, moment, limit)
+
+	// Log the query execution
+	utils.LogDBQuery(
+		query,
+		[]interface{}{moment, limit},
+		time.Since(startTime),
+		err,
+	)
+
+	if err != nil {
+		return nil, fmt.Errorf("failed to list stale documents: %w", err)
+	}
+	defer rows.Close()
+
+	var documents []*models.StaleDocument
+	for rows.Next() {
+		document := &models.StaleDocument{}
+		if err := rows.Scan(&document.DocumentID, &document.UserID, &document.Reason, &document.LastActivity); err != nil {
+			return nil, fmt.Errorf("failed to scan stale document row: %w", err)
+		}
+		documents = append(documents, document)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating stale document rows: %w", err)
+	}
+
+	return documents, nil
+}
+
+// MarkStaleNotified records that the owners of documents were told they are stale.
+//
+// Parameters:
+//   - ctx: Context for transaction and cancellation control
+//   - documentIDs: The IDs of the documents
+//   - at: The moment the owners were told
+//
+// Returns:
+//   - An error if the update fails
+func (r *PostgresDocumentRepository) MarkStaleNotified(ctx context.Context, documentIDs []int64, at time.Time) error {
+	// Start query timer
+	startTime := time.Now()
+
+	// Define the query
+	query := `
+        UPDATE ` + constants.TableDocuments + `
+        SET ` + constants.ColumnStaleNotifiedAt + ` = $1
+        WHERE ` + constants.ColumnDocumentID + ` = ANY($2)
+    `
+
+	// Execute the query
+	_, err := r.db.ExecContext(ctx, query, at, pq.Array(documentIDs))
+
+	// Log the query execution
+	utils.LogDBQuery(
+		query,
+		[]interface{}{at, documentIDs},
+		time.Since(startTime),
+		err,
+	)
+
+	if err != nil {
+		return fmt.Errorf("failed to record stale document notice: %w", err)
+	}
+
+	return nil
+}
+
+// SetLifecycleExempt exempts a document from stale document cleanup or makes it subject
+// to it again, and forgets any notice sent about it.
+//
+// Parameters:
+//   - ctx: Context for transaction and cancellation control
+//   - documentID: The unique identifier of the document
+//   - exempt: Whether the document is kept however long it stays unfinished
+//
+// Returns:
+//   - NotFoundError if the document doesn't exist
+//   - Other errors for database issues
+func (r *PostgresDocumentRepository) SetLifecycleExempt(ctx context.Context, documentID int64, exempt bool) error {
+	// Start query timer
+	startTime := time.Now()
+
+	// Define the query
+	query := `
+        UPDATE ` + constants.TableDocuments + `
+        SET ` + constants.ColumnLifecycleExempt + ` = $1, ` + constants.ColumnStaleNotifiedAt + ` = NULL
+        WHERE ` + constants.ColumnDocumentID + ` = $2
+    `
+
+	// Execute the query
+	result, err := r.db.ExecContext(ctx, query, exempt, documentID)
+
+	// Log the query execution
+	utils.LogDBQuery(
+		query,
+		[]interface{}{exempt, documentID},
+		time.Since(startTime),
+		err,
+	)
+
+	if err != nil {
+		return fmt.Errorf("failed to update document lifecycle exemption: %w", err)
+	}
+
+	// Check if any rows were affected
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("failed to get rows affected: %w", err)
+	}
+
+	if rowsAffected == 0 {
+		return utils.NewNotFoundError("Document", documentID)
+	}
+
+	return nil
+}
+
+// nonNilTags returns tags, or an empty slice if tags is nil, so that the NOT NULL
+// tags column is stored as an empty array.
+func nonNilTags(tags []string) []string {
+	if tags == nil {
+		return []string{}
+	}
+	return tags
+}
+
+// reencryptLegacyDocuments moves document names and redaction schemas from the master key to tenant keys.
+func (r *PostgresDocumentRepository) reencryptLegacyDocuments(ctx context.Context, limit int) (int64, error) {
+	// Start query timer
+	startTime := time.Now()
+
+	// Define the query
+	query := `
+        SELECT ` + constants.ColumnDocumentID + `, ` + constants.ColumnUserID + `, hashed_document_name, redaction_schema
+        FROM ` + constants.TableDocuments + `
+        WHERE hashed_document_name NOT LIKE $1
+           OR (redaction_schema->>'tenant_encrypted' IS NULL AND redaction_schema <> '{}'::jsonb)
+        ORDER BY ` + constants.ColumnDocumentID + `
+        LIMIT $2
+    `
+
+	// Execute the query
+	rows, err := r.db.QueryContext(ctx, query, constants.TenantCiphertextPrefix+"%", limit)
+
+	// Log the query execution
+	utils.LogDBQuery(
+		query,
+		[]interface{}{constants.TenantCiphertextPrefix + "%", limit},
+		time.Since(startTime),
+		err,
+	)
+
+	if err != nil {
+		return 0, fmt.Errorf("failed to get legacy documents: %w", err)
+	}
+
+	// Read the batch before updating so no connection is held by the open result
+	var documents []*models.Document
+	for rows.Next() {
+		document := &models.Document{}
+		if err := rows.Scan(&document.ID, &document.UserID, &document.HashedDocumentName, &document.RedactionSchema); err != nil {
+			rows.Close()
+			return 0, fmt.Errorf("failed to scan document row: %w", err)
+		}
+		documents = append(documents, document)
+	}
+	if err := rows.Err(); err != nil {
+		rows.Close()
+		return 0, fmt.Errorf("error iterating document rows: %w", err)
+	}
+	rows.Close()
+
+	updateQuery := `
+        UPDATE ` + constants.TableDocuments + `
+        SET hashed_document_name = $1, redaction_schema = $2
+        WHERE ` + constants.ColumnDocumentID + ` = $3 AND hashed_document_name = $4
+    `
+
+	var count int64
+	for _, document := range documents {
+		encryptedName := document.HashedDocumentName
+		if !strings.HasPrefix(encryptedName, constants.TenantCiphertextPrefix) {
+			name, err := utils.DecryptKey(encryptedName, r.encryptionKey)
+			if err != nil {
+				return count, fmt.Errorf("failed to decrypt name of document %d: %w", document.ID, err)
+			}
+			if encryptedName, err = r.encryptForTenant(ctx, document.UserID, name); err != nil {
+				return count, fmt.Errorf("failed to encrypt name of document %d: %w", document.ID, err)
+			}
+		}
+
+		redactionSchema, err := r.sealRedactionSchema(ctx, document.UserID, document.RedactionSchema)
+		if err != nil {
+			return count, fmt.Errorf("failed to encrypt redaction schema of document %d: %w", document.ID, err)
+		}
+
+		result, err := r.db.ExecContext(ctx, updateQuery, encryptedName, redactionSchema, document.ID, document.HashedDocumentName)
+		if err != nil {
+			return count, fmt.Errorf("failed to re-encrypt document: %w", err)
+		}
+		rowsAffected, _ := result.RowsAffected()
+		count += rowsAffected
+	}
+
+	return count, nil
+}
+
+// reencryptLegacyRedactionSchemas moves encrypted redaction schemas from the master key to tenant keys.
+func (r *PostgresDocumentRepository) reencryptLegacyRedactionSchemas(ctx context.Context, limit int) (int64, error) {
+	// Start query timer
+	startTime := time.Now()
+
+	// Define the query
+	query := `
+        SELECT de.` + constants.ColumnEntityID + `, d.` + constants.ColumnUserID + `, de.redaction_schema
+        FROM ` + constants.TableDetectedEntities + ` de
+        JOIN ` + constants.TableDocuments + ` d ON d.` + constants.ColumnDocumentID + ` = de.` + constants.ColumnDocumentID + `
+        WHERE de.redaction_schema->>'is_encrypted' = 'true'
+          AND de.redaction_schema->>'encrypted_data' NOT LIKE $1
+        ORDER BY de.` + constants.ColumnEntityID + `
+        LIMIT $2
+    `
+
+	// Execute the query
+	rows, err := r.db.QueryContext(ctx, query, constants.TenantCiphertextPrefix+"%", limit)
+
+	// Log the query execution
+	utils.LogDBQuery(
+		query,
+		[]interface{}{constants.TenantCiphertextPrefix + "%", limit},
+		time.Since(startTime),
+		err,
+	)
+
+	if err != nil {
+		return 0, fmt.Errorf("failed to get legacy redaction schemas: %w", err)
+	}
+
+	// Read the batch before updating so no connection is held by the open result
+	type legacyEntity struct {
+		entity  models.DetectedEntity
+		ownerID int64
+	}
+	var entities []*legacyEntity
+	for rows.Next() {
+		legacy := &legacyEntity{}
+		if err := rows.Scan(&legacy.entity.ID, &legacy.ownerID, &legacy.entity.RedactionSchema); err != nil {
+			rows.Close()
+			return 0, fmt.Errorf("failed to scan detected entity row: %w", err)
+		}
+		entities = append(entities, legacy)
+	}
+	if err := rows.Err(); err != nil {
+		rows.Close()
+		return 0, fmt.Errorf("error iterating detected entity rows: %w", err)
+	}
+	rows.Close()
+
+	updateQuery := `
+        UPDATE ` + constants.TableDetectedEntities + `
+        SET redaction_schema = $1
+        WHERE ` + constants.ColumnEntityID + ` = $2 AND redaction_schema->>'encrypted_data' = $3
+    `
+
+	var count int64
+	for _, legacy := range entities {
+		entity := &legacy.entity
+		previous := entity.RedactionSchema.EncryptedData
+
+		if err := entity.DecryptRedactionSchema(r.encryptionKey); err != nil {
+			return count, fmt.Errorf("failed to decrypt redaction schema of entity %d: %w", entity.ID, err)
+		}
+		if err := r.encryptRedactionSchema(ctx, legacy.ownerID, entity); err != nil {
+			return count, fmt.Errorf("failed to encrypt redaction schema of entity %d: %w", entity.ID, err)
+		}
+
+		result, err := r.db.ExecContext(ctx, updateQuery, entity.RedactionSchema, entity.ID, previous)
+		if err != nil {
+			return count, fmt.Errorf("failed to re-encrypt redaction schema: %w", err)
+		}
+		rowsAffected, _ := result.RowsAffected()
+		count += rowsAffected
+	}
+
+	return count, nil
+}
+
+// documentOwner returns the ID of the user owning a document.
+func (r *PostgresDocumentRepository) documentOwner(ctx context.Context, documentID int64) (int64, error) {
+	query := `SELECT ` + constants.ColumnUserID + ` FROM ` + constants.TableDocuments + ` WHERE ` + constants.ColumnDocumentID + ` = $1`
+
+	var userID int64
+	if err := r.db.QueryRowContext(ctx, query, documentID).Scan(&userID); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return 0, utils.NewNotFoundError("Document", documentID).WithCause(err)
+		}
+		return 0, fmt.Errorf("failed to get document owner: %w", err)
+	}
+
+	return userID, nil
+}
+
+// encryptForTenant encrypts a value with the data-encryption key of a tenant.
+// Without tenant keys the master key is used.
+func (r *PostgresDocumentRepository) encryptForTenant(ctx context.Context, userID int64, plaintext string) (string, error) {
+	if r.tenantKeys == nil {
+		return utils.EncryptKey(plaintext, r.encryptionKey)
+	}
+
+	dataKey, err := r.tenantKeys.GetOrCreateDataKey(ctx, userID)
+	if err != nil {
+		return "", fmt.Errorf("failed to get tenant data key: %w", err)
+	}
+
+	ciphertext, err := utils.EncryptKey(plaintext, dataKey)
+	if err != nil {
+		return "", err
+	}
+
+	return constants.TenantCiphertextPrefix + ciphertext, nil
+}
+
+// decryptForTenant decrypts a value encrypted with either a tenant key or the master key.
+func (r *PostgresDocumentRepository) decryptForTenant(ctx context.Context, userID int64, ciphertext string) (string, error) {
+	key, ciphertext, err := r.decryptionKey(ctx, userID, ciphertext)
+	if err != nil {
+		return "", err
+	}
+
+	return utils.DecryptKey(ciphertext, key)
+}
+
+// encryptRedactionSchema encrypts the redaction schema of an entity with the data-encryption key of a tenant.
+func (r *PostgresDocumentRepository) encryptRedactionSchema(ctx context.Context, userID int64, entity *models.DetectedEntity) error {
+	if r.tenantKeys == nil {
+		return entity.EncryptRedactionSchema(r.encryptionKey)
+	}
+
+	dataKey, err := r.tenantKeys.GetOrCreateDataKey(ctx, userID)
+	if err != nil {
+		return fmt.Errorf("failed to get tenant data key: %w", err)
+	}
+
+	if err := entity.EncryptRedactionSchema(dataKey); err != nil {
+		return err
+	}
+	entity.RedactionSchema.EncryptedData = constants.TenantCiphertextPrefix + entity.RedactionSchema.EncryptedData
+
+	return nil
+}
+
+// decryptRedactionSchema decrypts the redaction schema of an entity encrypted with either a tenant key or the master key.
+func (r *PostgresDocumentRepository) decryptRedactionSchema(ctx context.Context, userID int64, entity *models.DetectedEntity) error {
+	if !entity.RedactionSchema.IsEncrypted {
+		return nil
+	}
+
+	key, ciphertext, err := r.decryptionKey(ctx, userID, entity.RedactionSchema.EncryptedData)
+	if err != nil {
+		return err
+	}
+	entity.RedactionSchema.EncryptedData = ciphertext
+
+	return entity.DecryptRedactionSchema(key)
+}
+
+// tenantRedactionSchema wraps a document redaction schema encrypted with a tenant key,
+// keeping it a valid JSON object for the JSONB column.
+type tenantRedactionSchema struct {
+	TenantEncrypted string `json:"tenant_encrypted"`
+}
+
+// sealRedactionSchema encrypts a document redaction schema with the data-encryption key of a tenant.
+// Without tenant keys the schema is stored as given.
+func (r *PostgresDocumentRepository) sealRedactionSchema(ctx context.Context, userID int64, schema string) (string, error) {
+	if r.tenantKeys == nil || schema == "" || schema == "{}" {
+		return schema, nil
+	}
+
+	var sealed tenantRedactionSchema
+	if err := json.Unmarshal([]byte(schema), &sealed); err == nil && sealed.TenantEncrypted != "" {
+		return schema, nil
+	}
+
+	encrypted, err := r.encryptForTenant(ctx, userID, schema)
+	if err != nil {
+		return "", err
+	}
+
+	wrapped, err := json.Marshal(tenantRedactionSchema{TenantEncrypted: encrypted})
+	if err != nil {
+		return "", err
+	}
+
+	return string(wrapped), nil
+}
+
+// openRedactionSchema decrypts a document redaction schema sealed with a tenant key.
+// Schemas stored before tenant keys existed are returned as they are.
+func (r *PostgresDocumentRepository) openRedactionSchema(ctx context.Context, userID int64, schema string) (string, error) {
+	var sealed tenantRedactionSchema
+	if err := json.Unmarshal([]byte(schema), &sealed); err != nil || sealed.TenantEncrypted == "" {
+		return schema, nil
+	}
+
+	return r.decryptForTenant(ctx, userID, sealed.TenantEncrypted)
+}
+
+// decryptionKey returns the key a value was encrypted with and the value without its key marker.
+// Values without the tenant marker were encrypted with the master key.
+func (r *PostgresDocumentRepository) decryptionKey(ctx context.Context, userID int64, ciphertext string) ([]byte, string, error) {
+	tenantCiphertext, ok := strings.CutPrefix(ciphertext, constants.TenantCiphertextPrefix)
+	if !ok {
+		return r.encryptionKey, ciphertext, nil
+	}
+
+	if r.tenantKeys == nil {
+		return nil, "", errors.New("value is encrypted with a tenant key but tenant keys are not configured")
+	}
+
+	dataKey, err := r.tenantKeys.GetDataKey(ctx, userID)
+	if err != nil {
+		return nil, "", fmt.Errorf("failed to get tenant data key: %w", err)
+	}
+
+	return dataKey, tenantCiphertext, nil
+}
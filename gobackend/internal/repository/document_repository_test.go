@@ -3,15 +3,20 @@ package repository_test
 import (
 	"context"
 	"database/sql"
+	"database/sql/driver"
 	"errors"
+	"fmt"
 	"github.com/yasinhessnawi1/Hideme_Backend/internal/utils"
+	"strings"
 	"testing"
 	"time"
 
 	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/lib/pq"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 
+	"github.com/yasinhessnawi1/Hideme_Backend/internal/constants"
 	"github.com/yasinhessnawi1/Hideme_Backend/internal/database"
 	"github.com/yasinhessnawi1/Hideme_Backend/internal/models"
 	"github.com/yasinhessnawi1/Hideme_Backend/internal/repository"
@@ -30,7 +35,7 @@ func setupDocumentRepositoryTest(t *testing.T) (*repository.PostgresDocumentRepo
 	encryptionKey := []byte("test-encryption-key-for-unit-tests")
 
 	// Create a new repository with the mocked database and encryption key
-	repo := repository.NewDocumentRepository(dbPool, encryptionKey).(*repository.PostgresDocumentRepository)
+	repo := repository.NewDocumentRepository(dbPool, encryptionKey, nil).(*repository.PostgresDocumentRepository)
 
 	// Return the repository, mock and a cleanup function
 	return repo, mock, func() {
@@ -58,7 +63,7 @@ func TestDocumentRepository_Create(t *testing.T) {
 
 	// Expected query with placeholders for the arguments - now including redaction_schema
 	mock.ExpectQuery("INSERT INTO documents").
-		WithArgs(doc.UserID, sqlmock.AnyArg(), doc.UploadTimestamp, doc.LastModified, doc.RedactionSchema).
+		WithArgs(doc.UserID, sqlmock.AnyArg(), doc.UploadTimestamp, doc.LastModified, doc.RedactionSchema, doc.Language, doc.SchemaVersion, sqlmock.AnyArg(), doc.Folder, doc.Source, doc.RetainUntil, doc.FilenameScrubbed, doc.EntityCount, sqlmock.AnyArg()).
 		WillReturnRows(rows)
 
 	// Execute the method being tested
@@ -87,7 +92,7 @@ func TestDocumentRepository_Create_Error(t *testing.T) {
 
 	// Mock database error - now expecting 5 arguments including redaction_schema
 	mock.ExpectQuery("INSERT INTO documents").
-		WithArgs(doc.UserID, sqlmock.AnyArg(), doc.UploadTimestamp, doc.LastModified, doc.RedactionSchema).
+		WithArgs(doc.UserID, sqlmock.AnyArg(), doc.UploadTimestamp, doc.LastModified, doc.RedactionSchema, doc.Language, doc.SchemaVersion, sqlmock.AnyArg(), doc.Folder, doc.Source, doc.RetainUntil, doc.FilenameScrubbed, doc.EntityCount, sqlmock.AnyArg()).
 		WillReturnError(errors.New("database error"))
 
 	// Execute the method being tested
@@ -132,11 +137,11 @@ func TestDocumentRepository_GetByID(t *testing.T) {
 	}
 
 	// Set up query result - now including redaction_schema
-	rows := sqlmock.NewRows([]string{"document_id", "user_id", "hashed_document_name", "upload_timestamp", "last_modified", "redaction_schema"}).
-		AddRow(doc.ID, doc.UserID, doc.HashedDocumentName, doc.UploadTimestamp, doc.LastModified, doc.RedactionSchema)
+	rows := sqlmock.NewRows([]string{"document_id", "user_id", "hashed_document_name", "upload_timestamp", "last_modified", "redaction_schema", "language", "tags", "folder", "source", "retain_until", "filename_scrubbed", "archived_at"}).
+		AddRow(doc.ID, doc.UserID, doc.HashedDocumentName, doc.UploadTimestamp, doc.LastModified, doc.RedactionSchema, doc.Language, "{}", "", "", nil, false, nil)
 
 	// Expected query with placeholder for the ID - now selecting redaction_schema
-	mock.ExpectQuery("SELECT document_id, user_id, hashed_document_name, upload_timestamp, last_modified, redaction_schema FROM documents WHERE document_id = \\$1").
+	mock.ExpectQuery("SELECT document_id, user_id, hashed_document_name, upload_timestamp, last_modified, redaction_schema, language, tags, folder, source, retain_until, filename_scrubbed, archived_at FROM documents WHERE document_id = \\$1").
 		WithArgs(id).
 		WillReturnRows(rows)
 
@@ -165,7 +170,7 @@ func TestDocumentRepository_GetByID_NotFound(t *testing.T) {
 	id := int64(999)
 
 	// Mock database response - empty result
-	mock.ExpectQuery("SELECT document_id, user_id, hashed_document_name, upload_timestamp, last_modified, redaction_schema FROM documents WHERE document_id = \\$1").
+	mock.ExpectQuery("SELECT document_id, user_id, hashed_document_name, upload_timestamp, last_modified, redaction_schema, language, tags, folder, source, retain_until, filename_scrubbed, archived_at FROM documents WHERE document_id = \\$1").
 		WithArgs(id).
 		WillReturnError(sql.ErrNoRows)
 
@@ -188,7 +193,7 @@ func TestDocumentRepository_GetByID_OtherError(t *testing.T) {
 	id := int64(1)
 
 	// Mock database error (not ErrNoRows)
-	mock.ExpectQuery("SELECT document_id, user_id, hashed_document_name, upload_timestamp, last_modified, redaction_schema FROM documents WHERE document_id = \\$1").
+	mock.ExpectQuery("SELECT document_id, user_id, hashed_document_name, upload_timestamp, last_modified, redaction_schema, language, tags, folder, source, retain_until, filename_scrubbed, archived_at FROM documents WHERE document_id = \\$1").
 		WithArgs(id).
 		WillReturnError(errors.New("database connection error"))
 
@@ -202,6 +207,42 @@ func TestDocumentRepository_GetByID_OtherError(t *testing.T) {
 	assert.NoError(t, mock.ExpectationsWereMet())
 }
 
+func TestDocumentRepository_CountByUserID(t *testing.T) {
+	// Set up the test
+	repo, mock, cleanup := setupDocumentRepositoryTest(t)
+	defer cleanup()
+
+	mock.ExpectQuery("SELECT COUNT\\(\\*\\) FROM documents WHERE user_id = \\$1").
+		WithArgs(int64(100)).
+		WillReturnRows(sqlmock.NewRows([]string{"count"}).AddRow(7))
+
+	// Execute the method being tested
+	count, err := repo.CountByUserID(context.Background(), 100)
+
+	// Assert the results
+	assert.NoError(t, err)
+	assert.Equal(t, int64(7), count)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestDocumentRepository_CountByUserID_Error(t *testing.T) {
+	// Set up the test
+	repo, mock, cleanup := setupDocumentRepositoryTest(t)
+	defer cleanup()
+
+	mock.ExpectQuery("SELECT COUNT").
+		WithArgs(int64(100)).
+		WillReturnError(errors.New("database error"))
+
+	// Execute the method being tested
+	_, err := repo.CountByUserID(context.Background(), 100)
+
+	// Assert the results
+	assert.Error(t, err)
+	assert.Contains(t, err.Error(), "failed to count documents")
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
 func TestDocumentRepository_GetByUserID(t *testing.T) {
 	// Set up the test
 	repo, mock, cleanup := setupDocumentRepositoryTest(t)
@@ -232,7 +273,7 @@ func TestDocumentRepository_GetByUserID(t *testing.T) {
 	// Setup for count query
 	countRows := sqlmock.NewRows([]string{"count"}).AddRow(totalCount)
 	mock.ExpectQuery("SELECT COUNT\\(\\*\\) FROM documents WHERE user_id = \\$1").
-		WithArgs(userID).
+		WithArgs(userID, "").
 		WillReturnRows(countRows)
 
 	// Setup for main query
@@ -255,18 +296,18 @@ func TestDocumentRepository_GetByUserID(t *testing.T) {
 		},
 	}
 
-	rows := sqlmock.NewRows([]string{"document_id", "user_id", "hashed_document_name", "upload_timestamp", "last_modified", "redaction_schema"}) // Include redaction_schema
+	rows := sqlmock.NewRows([]string{"document_id", "user_id", "hashed_document_name", "upload_timestamp", "last_modified", "redaction_schema", "language", "tags", "folder", "source", "retain_until", "filename_scrubbed", "archived_at"}) // Include redaction_schema
 	for _, doc := range docs {
-		rows.AddRow(doc.ID, doc.UserID, doc.HashedDocumentName, doc.UploadTimestamp, doc.LastModified, doc.RedactionSchema) // Add redaction_schema value
+		rows.AddRow(doc.ID, doc.UserID, doc.HashedDocumentName, doc.UploadTimestamp, doc.LastModified, doc.RedactionSchema, doc.Language, "{}", "", "", nil, false, nil) // Add redaction_schema value
 	}
 
 	// Expected query with pagination parameters - include redaction_schema
-	mock.ExpectQuery("SELECT document_id, user_id, hashed_document_name, upload_timestamp, last_modified, redaction_schema FROM documents WHERE user_id = \\$1 ORDER BY upload_timestamp DESC LIMIT \\$2 OFFSET \\$3").
-		WithArgs(userID, pageSize, offset).
+	mock.ExpectQuery("SELECT document_id, user_id, hashed_document_name, upload_timestamp, last_modified, redaction_schema, language, tags, folder, source, retain_until, filename_scrubbed, archived_at FROM documents WHERE user_id = \\$1 AND \\(\\$2::text = '' OR language = \\$2\\) ORDER BY upload_timestamp DESC LIMIT \\$3 OFFSET \\$4").
+		WithArgs(userID, "", pageSize, offset).
 		WillReturnRows(rows)
 
 	// Execute the method being tested
-	results, count, err := repo.GetByUserID(context.Background(), userID, page, pageSize)
+	results, count, err := repo.GetByUserID(context.Background(), userID, "", page, pageSize)
 
 	// Assert the results
 	assert.NoError(t, err)
@@ -280,6 +321,34 @@ func TestDocumentRepository_GetByUserID(t *testing.T) {
 	assert.NoError(t, mock.ExpectationsWereMet())
 }
 
+func TestDocumentRepository_GetByUserID_Language(t *testing.T) {
+	// Set up the test
+	repo, mock, cleanup := setupDocumentRepositoryTest(t)
+	defer cleanup()
+
+	userID := int64(100)
+	now := time.Now()
+	encryptedName, err := utils.EncryptKey("arbeidsavtale.pdf", []byte("test-encryption-key-for-unit-tests"))
+	require.NoError(t, err)
+
+	// Both queries are restricted to the requested language
+	mock.ExpectQuery("SELECT COUNT\\(\\*\\) FROM documents WHERE user_id = \\$1 AND \\(\\$2::text = '' OR language = \\$2\\)").
+		WithArgs(userID, "nb").
+		WillReturnRows(sqlmock.NewRows([]string{"count"}).AddRow(1))
+	mock.ExpectQuery("SELECT document_id, user_id, hashed_document_name, upload_timestamp, last_modified, redaction_schema, language, tags, folder, source, retain_until, filename_scrubbed, archived_at FROM documents WHERE user_id = \\$1 AND \\(\\$2::text = '' OR language = \\$2\\)").
+		WithArgs(userID, "nb", 10, 0).
+		WillReturnRows(sqlmock.NewRows([]string{"document_id", "user_id", "hashed_document_name", "upload_timestamp", "last_modified", "redaction_schema", "language", "tags", "folder", "source", "retain_until", "filename_scrubbed", "archived_at"}).
+			AddRow(1, userID, encryptedName, now, now, "{}", "nb", "{}", "", "", nil, false, nil))
+
+	results, count, err := repo.GetByUserID(context.Background(), userID, "nb", 1, 10)
+
+	assert.NoError(t, err)
+	assert.Equal(t, 1, count)
+	require.Len(t, results, 1)
+	assert.Equal(t, "nb", results[0].Language)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
 func TestDocumentRepository_GetByUserID_CountError(t *testing.T) {
 	// Set up the test
 	repo, mock, cleanup := setupDocumentRepositoryTest(t)
@@ -292,11 +361,11 @@ func TestDocumentRepository_GetByUserID_CountError(t *testing.T) {
 
 	// Mock count query error
 	mock.ExpectQuery("SELECT COUNT\\(\\*\\) FROM documents WHERE user_id = \\$1").
-		WithArgs(userID).
+		WithArgs(userID, "").
 		WillReturnError(errors.New("count query error"))
 
 	// Execute the method being tested
-	results, count, err := repo.GetByUserID(context.Background(), userID, page, pageSize)
+	results, count, err := repo.GetByUserID(context.Background(), userID, "", page, pageSize)
 
 	// Assert the results
 	assert.Error(t, err)
@@ -321,16 +390,16 @@ func TestDocumentRepository_GetByUserID_QueryError(t *testing.T) {
 	// Setup for count query
 	countRows := sqlmock.NewRows([]string{"count"}).AddRow(totalCount)
 	mock.ExpectQuery("SELECT COUNT\\(\\*\\) FROM documents WHERE user_id = \\$1").
-		WithArgs(userID).
+		WithArgs(userID, "").
 		WillReturnRows(countRows)
 
 	// Mock main query error - update to include redaction_schema
-	mock.ExpectQuery("SELECT document_id, user_id, hashed_document_name, upload_timestamp, last_modified, redaction_schema FROM documents WHERE user_id = \\$1 ORDER BY upload_timestamp DESC LIMIT \\$2 OFFSET \\$3").
-		WithArgs(userID, pageSize, offset).
+	mock.ExpectQuery("SELECT document_id, user_id, hashed_document_name, upload_timestamp, last_modified, redaction_schema, language, tags, folder, source, retain_until, filename_scrubbed, archived_at FROM documents WHERE user_id = \\$1 AND \\(\\$2::text = '' OR language = \\$2\\) ORDER BY upload_timestamp DESC LIMIT \\$3 OFFSET \\$4").
+		WithArgs(userID, "", pageSize, offset).
 		WillReturnError(errors.New("query error"))
 
 	// Execute the method being tested
-	results, count, err := repo.GetByUserID(context.Background(), userID, page, pageSize)
+	results, count, err := repo.GetByUserID(context.Background(), userID, "", page, pageSize)
 
 	// Assert the results
 	assert.Error(t, err)
@@ -355,19 +424,19 @@ func TestDocumentRepository_GetByUserID_ScanError(t *testing.T) {
 	// Setup for count query
 	countRows := sqlmock.NewRows([]string{"count"}).AddRow(totalCount)
 	mock.ExpectQuery("SELECT COUNT\\(\\*\\) FROM documents WHERE user_id = \\$1").
-		WithArgs(userID).
+		WithArgs(userID, "").
 		WillReturnRows(countRows)
 
 	// Setup for main query with invalid data to cause scan error - update to include redaction_schema
-	rows := sqlmock.NewRows([]string{"document_id", "user_id", "hashed_document_name", "upload_timestamp", "last_modified", "redaction_schema"}).
-		AddRow("invalid_id", userID, "doc1", time.Now(), time.Now(), "{}") // invalid_id will cause scan error
+	rows := sqlmock.NewRows([]string{"document_id", "user_id", "hashed_document_name", "upload_timestamp", "last_modified", "redaction_schema", "language", "tags", "folder", "source", "retain_until", "filename_scrubbed", "archived_at"}).
+		AddRow("invalid_id", userID, "doc1", time.Now(), time.Now(), "{}", "", "{}", "", "", nil, false, nil) // invalid_id will cause scan error
 
-	mock.ExpectQuery("SELECT document_id, user_id, hashed_document_name, upload_timestamp, last_modified, redaction_schema FROM documents WHERE user_id = \\$1 ORDER BY upload_timestamp DESC LIMIT \\$2 OFFSET \\$3").
-		WithArgs(userID, pageSize, offset).
+	mock.ExpectQuery("SELECT document_id, user_id, hashed_document_name, upload_timestamp, last_modified, redaction_schema, language, tags, folder, source, retain_until, filename_scrubbed, archived_at FROM documents WHERE user_id = \\$1 AND \\(\\$2::text = '' OR language = \\$2\\) ORDER BY upload_timestamp DESC LIMIT \\$3 OFFSET \\$4").
+		WithArgs(userID, "", pageSize, offset).
 		WillReturnRows(rows)
 
 	// Execute the method being tested
-	results, count, err := repo.GetByUserID(context.Background(), userID, page, pageSize)
+	results, count, err := repo.GetByUserID(context.Background(), userID, "", page, pageSize)
 
 	// Assert the results
 	assert.Error(t, err)
@@ -392,20 +461,20 @@ func TestDocumentRepository_GetByUserID_RowsError(t *testing.T) {
 	// Setup for count query
 	countRows := sqlmock.NewRows([]string{"count"}).AddRow(totalCount)
 	mock.ExpectQuery("SELECT COUNT\\(\\*\\) FROM documents WHERE user_id = \\$1").
-		WithArgs(userID).
+		WithArgs(userID, "").
 		WillReturnRows(countRows)
 
 	// Setup for main query with row error - update to include redaction_schema
-	rows := sqlmock.NewRows([]string{"document_id", "user_id", "hashed_document_name", "upload_timestamp", "last_modified", "redaction_schema"}).
-		AddRow(1, userID, "doc1", time.Now(), time.Now(), "{}").
+	rows := sqlmock.NewRows([]string{"document_id", "user_id", "hashed_document_name", "upload_timestamp", "last_modified", "redaction_schema", "language", "tags", "folder", "source", "retain_until", "filename_scrubbed", "archived_at"}).
+		AddRow(1, userID, "doc1", time.Now(), time.Now(), "{}", "", "{}", "", "", nil, false, nil).
 		RowError(0, errors.New("row error"))
 
-	mock.ExpectQuery("SELECT document_id, user_id, hashed_document_name, upload_timestamp, last_modified, redaction_schema FROM documents WHERE user_id = \\$1 ORDER BY upload_timestamp DESC LIMIT \\$2 OFFSET \\$3").
-		WithArgs(userID, pageSize, offset).
+	mock.ExpectQuery("SELECT document_id, user_id, hashed_document_name, upload_timestamp, last_modified, redaction_schema, language, tags, folder, source, retain_until, filename_scrubbed, archived_at FROM documents WHERE user_id = \\$1 AND \\(\\$2::text = '' OR language = \\$2\\) ORDER BY upload_timestamp DESC LIMIT \\$3 OFFSET \\$4").
+		WithArgs(userID, "", pageSize, offset).
 		WillReturnRows(rows)
 
 	// Execute the method being tested
-	results, count, err := repo.GetByUserID(context.Background(), userID, page, pageSize)
+	results, count, err := repo.GetByUserID(context.Background(), userID, "", page, pageSize)
 
 	// Assert the results
 	assert.Error(t, err)
@@ -415,6 +484,237 @@ func TestDocumentRepository_GetByUserID_RowsError(t *testing.T) {
 	assert.NoError(t, mock.ExpectationsWereMet())
 }
 
+func TestDocumentRepository_StreamByUserID(t *testing.T) {
+	// Set up the test
+	repo, mock, cleanup := setupDocumentRepositoryTest(t)
+	defer cleanup()
+
+	// Set up test data
+	userID := int64(100)
+	now := time.Now()
+
+	// Create a proper 32-byte encryption key
+	encryptionKey := make([]byte, 32)
+	copy(encryptionKey, "test-encryption-key-for-unit-tests")
+
+	encryptedName1, err := utils.EncryptKey("doc1", encryptionKey)
+	require.NoError(t, err)
+	encryptedName2, err := utils.EncryptKey("doc2", encryptionKey)
+	require.NoError(t, err)
+
+	rows := sqlmock.NewRows([]string{"document_id", "user_id", "hashed_document_name", "upload_timestamp", "last_modified", "redaction_schema", "language", "tags", "folder", "source", "retain_until", "filename_scrubbed", "archived_at"}).
+		AddRow(1, userID, encryptedName1, now, now, "{}", "", "{}", "", "", nil, false, nil).
+		AddRow(2, userID, encryptedName2, now, now, "{}", "", "{}", "", "", nil, false, nil)
+
+	// No pagination when streaming
+	mock.ExpectQuery("SELECT document_id, user_id, hashed_document_name, upload_timestamp, last_modified, redaction_schema, language, tags, folder, source, retain_until, filename_scrubbed, archived_at FROM documents WHERE user_id = \\$1 AND \\(\\$2::text = '' OR language = \\$2\\) ORDER BY upload_timestamp DESC").
+		WithArgs(userID, "").
+		WillReturnRows(rows)
+
+	// Execute the method being tested
+	var names []string
+	err = repo.StreamByUserID(context.Background(), userID, "", func(document *models.Document) error {
+		names = append(names, document.HashedDocumentName)
+		return nil
+	})
+
+	// Assert the results
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"doc1", "doc2"}, names)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestDocumentRepository_ListByUserIDAfter(t *testing.T) {
+	// Set up the test
+	repo, mock, cleanup := setupDocumentRepositoryTest(t)
+	defer cleanup()
+
+	// Set up test data
+	userID := int64(100)
+	now := time.Now()
+
+	// Create a proper 32-byte encryption key
+	encryptionKey := make([]byte, 32)
+	copy(encryptionKey, "test-encryption-key-for-unit-tests")
+
+	encryptedName, err := utils.EncryptKey("doc3", encryptionKey)
+	require.NoError(t, err)
+
+	rows := sqlmock.NewRows([]string{"document_id", "user_id", "hashed_document_name", "upload_timestamp", "last_modified", "redaction_schema", "language", "tags", "folder", "source", "retain_until", "filename_scrubbed", "archived_at"}).
+		AddRow(3, userID, encryptedName, now, now, "{}", "", "{}", "", "", nil, false, nil)
+
+	// Documents follow the last one by ID
+	mock.ExpectQuery("SELECT document_id, (.+) FROM documents WHERE user_id = \\$1 AND document_id > \\$2 ORDER BY document_id LIMIT \\$3").
+		WithArgs(userID, int64(2), 50).
+		WillReturnRows(rows)
+
+	// Execute the method being tested
+	documents, err := repo.ListByUserIDAfter(context.Background(), userID, 2, 50)
+
+	// Assert the results
+	assert.NoError(t, err)
+	require.Len(t, documents, 1)
+	assert.Equal(t, int64(3), documents[0].ID)
+	assert.Equal(t, "doc3", documents[0].HashedDocumentName)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestDocumentRepository_GetByUserIDAfter(t *testing.T) {
+	userID := int64(100)
+	now := time.Now()
+	query := "SELECT document_id, (.+) FROM documents WHERE user_id = \\$1 AND \\(\\$2::text = '' OR language = \\$2\\) " +
+		"AND \\(\\$3::timestamp IS NULL OR \\(upload_timestamp, document_id\\) < \\(\\$3, \\$4\\)\\) " +
+		"ORDER BY upload_timestamp DESC, document_id DESC LIMIT \\$5"
+
+	encryptionKey := make([]byte, 32)
+	copy(encryptionKey, "test-encryption-key-for-unit-tests")
+	encryptedName, err := utils.EncryptKey("doc3", encryptionKey)
+	require.NoError(t, err)
+	columns := []string{"document_id", "user_id", "hashed_document_name", "upload_timestamp", "last_modified", "redaction_schema", "language", "tags", "folder", "source", "retain_until", "filename_scrubbed", "archived_at"}
+
+	t.Run("First page", func(t *testing.T) {
+		repo, mock, cleanup := setupDocumentRepositoryTest(t)
+		defer cleanup()
+
+		mock.ExpectQuery(query).
+			WithArgs(userID, "nb", (*time.Time)(nil), int64(0), 21).
+			WillReturnRows(sqlmock.NewRows(columns).AddRow(3, userID, encryptedName, now, now, "{}", "nb", "{}", "", "", nil, false, nil))
+
+		documents, err := repo.GetByUserIDAfter(context.Background(), userID, "nb", nil, 21)
+
+		assert.NoError(t, err)
+		require.Len(t, documents, 1)
+		assert.Equal(t, "doc3", documents[0].HashedDocumentName)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("After a document", func(t *testing.T) {
+		repo, mock, cleanup := setupDocumentRepositoryTest(t)
+		defer cleanup()
+
+		// Documents uploaded at the same time as the last one follow it by descending ID
+		after := &utils.KeysetCursor{Timestamp: now, ID: 3}
+		mock.ExpectQuery(query).
+			WithArgs(userID, "", &after.Timestamp, int64(3), 21).
+			WillReturnRows(sqlmock.NewRows(columns))
+
+		documents, err := repo.GetByUserIDAfter(context.Background(), userID, "", after, 21)
+
+		assert.NoError(t, err)
+		assert.Empty(t, documents)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+}
+
+func TestDocumentRepository_StreamByUserID_CallbackError(t *testing.T) {
+	// Set up the test
+	repo, mock, cleanup := setupDocumentRepositoryTest(t)
+	defer cleanup()
+
+	userID := int64(100)
+	now := time.Now()
+
+	encryptionKey := make([]byte, 32)
+	copy(encryptionKey, "test-encryption-key-for-unit-tests")
+	encryptedName, err := utils.EncryptKey("doc1", encryptionKey)
+	require.NoError(t, err)
+
+	rows := sqlmock.NewRows([]string{"document_id", "user_id", "hashed_document_name", "upload_timestamp", "last_modified", "redaction_schema", "language", "tags", "folder", "source", "retain_until", "filename_scrubbed", "archived_at"}).
+		AddRow(1, userID, encryptedName, now, now, "{}", "", "{}", "", "", nil, false, nil).
+		AddRow(2, userID, encryptedName, now, now, "{}", "", "{}", "", "", nil, false, nil)
+
+	mock.ExpectQuery("SELECT document_id, user_id, hashed_document_name, upload_timestamp, last_modified, redaction_schema, language, tags, folder, source, retain_until, filename_scrubbed, archived_at FROM documents").
+		WithArgs(userID, "").
+		WillReturnRows(rows)
+
+	// The scan stops at the first callback error
+	callbackErr := errors.New("client went away")
+	calls := 0
+	err = repo.StreamByUserID(context.Background(), userID, "", func(document *models.Document) error {
+		calls++
+		return callbackErr
+	})
+
+	assert.ErrorIs(t, err, callbackErr)
+	assert.Equal(t, 1, calls)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestDocumentRepository_StreamByUserID_ContextCanceled(t *testing.T) {
+	// Set up the test
+	repo, mock, cleanup := setupDocumentRepositoryTest(t)
+	defer cleanup()
+
+	userID := int64(100)
+	now := time.Now()
+
+	encryptionKey := make([]byte, 32)
+	copy(encryptionKey, "test-encryption-key-for-unit-tests")
+	encryptedName, err := utils.EncryptKey("doc1", encryptionKey)
+	require.NoError(t, err)
+
+	rows := sqlmock.NewRows([]string{"document_id", "user_id", "hashed_document_name", "upload_timestamp", "last_modified", "redaction_schema", "language", "tags", "folder", "source", "retain_until", "filename_scrubbed", "archived_at"}).
+		AddRow(1, userID, encryptedName, now, now, "{}", "", "{}", "", "", nil, false, nil).
+		AddRow(2, userID, encryptedName, now, now, "{}", "", "{}", "", "", nil, false, nil).
+		AddRow(3, userID, encryptedName, now, now, "{}", "", "{}", "", "", nil, false, nil)
+
+	mock.ExpectQuery("SELECT document_id, user_id, hashed_document_name, upload_timestamp, last_modified, redaction_schema, language, tags, folder, source, retain_until, filename_scrubbed, archived_at FROM documents").
+		WithArgs(userID, "").
+		WillReturnRows(rows).
+		RowsWillBeClosed()
+
+	// The client goes away while the first document is being written
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	calls := 0
+	err = repo.StreamByUserID(ctx, userID, "", func(document *models.Document) error {
+		calls++
+		cancel()
+		return nil
+	})
+
+	// The stream stops before the next row and the result set is released
+	assert.ErrorIs(t, err, context.Canceled)
+	assert.Equal(t, 1, calls)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestDocumentRepository_SearchByUserID(t *testing.T) {
+	// Set up the test
+	repo, mock, cleanup := setupDocumentRepositoryTest(t)
+	defer cleanup()
+
+	userID := int64(100)
+	now := time.Now()
+
+	encryptionKey := make([]byte, 32)
+	copy(encryptionKey, "test-encryption-key-for-unit-tests")
+	encryptedName, err := utils.EncryptKey("contract.pdf", encryptionKey)
+	require.NoError(t, err)
+
+	from := now.AddDate(0, -1, 0)
+	minEntities := 2
+	filter := models.DocumentSearchFilter{UploadedFrom: &from, EntityTypes: []string{"PERSON"}, MinEntities: &minEntities}
+
+	// The filters are passed as parameters, unset ones as NULL or an empty array
+	mock.ExpectQuery("SELECT document_id, user_id, hashed_document_name, upload_timestamp, last_modified, redaction_schema, language, tags, folder, source, retain_until, filename_scrubbed, archived_at FROM documents WHERE user_id = \\$1 AND .*entity_types @> \\$4.*entity_count >= \\$5.* ORDER BY upload_timestamp DESC, document_id DESC").
+		WithArgs(userID, filter.UploadedFrom, filter.UploadedTo, pq.Array(filter.EntityTypes), filter.MinEntities, filter.MaxEntities).
+		WillReturnRows(sqlmock.NewRows([]string{"document_id", "user_id", "hashed_document_name", "upload_timestamp", "last_modified", "redaction_schema", "language", "tags", "folder", "source", "retain_until", "filename_scrubbed", "archived_at"}).
+			AddRow(1, userID, encryptedName, now, now, "{}", "", "{}", "", "", nil, false, nil))
+
+	// Execute the method being tested
+	var names []string
+	err = repo.SearchByUserID(context.Background(), userID, filter, func(document *models.Document) error {
+		names = append(names, document.HashedDocumentName)
+		return nil
+	})
+
+	// Assert the results
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"contract.pdf"}, names)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
 func TestDocumentRepository_Update(t *testing.T) {
 	// Set up the test
 	repo, mock, cleanup := setupDocumentRepositoryTest(t)
@@ -878,6 +1178,7 @@ func TestDocumentRepository_GetDetectedEntities(t *testing.T) {
 
 	// Set up test data
 	documentID := int64(1)
+	reviewer := int64(7)
 	now := time.Now()
 	entities := []*models.DetectedEntityWithMethod{
 		{
@@ -888,6 +1189,9 @@ func TestDocumentRepository_GetDetectedEntities(t *testing.T) {
 				EntityName:        "Credit Card",
 				RedactionSchema:   models.RedactionSchema{Page: 1, StartX: 10, StartY: 20, EndX: 30, EndY: 40, RedactionMethod: "blackout"},
 				DetectedTimestamp: now,
+				Status:            models.EntityStatusAccepted,
+				ReviewedBy:        &reviewer,
+				ReviewedAt:        &now,
 			},
 			MethodName:     "ML Model",
 			HighlightColor: "#FF0000",
@@ -900,6 +1204,7 @@ func TestDocumentRepository_GetDetectedEntities(t *testing.T) {
 				EntityName:        "SSN",
 				RedactionSchema:   models.RedactionSchema{Page: 1, StartX: 50, StartY: 60, EndX: 70, EndY: 80, RedactionMethod: "mask"},
 				DetectedTimestamp: now.Add(-time.Hour),
+				Status:            models.EntityStatusPending,
 			},
 			MethodName:     "Regex",
 			HighlightColor: "#00FF00",
@@ -907,19 +1212,19 @@ func TestDocumentRepository_GetDetectedEntities(t *testing.T) {
 	}
 
 	// Set up query result
-	rows := sqlmock.NewRows([]string{"entity_id", "document_id", "method_id", "entity_name", "redaction_schema", "detected_timestamp", "method_name", "highlight_color"})
+	rows := sqlmock.NewRows([]string{"entity_id", "document_id", "method_id", "entity_name", "redaction_schema", "detected_timestamp", "status", "reviewed_by", "reviewed_at", "method_name", "highlight_color"})
 	for _, entity := range entities {
 		schemaJSON, _ := entity.RedactionSchema.Value()
-		rows.AddRow(entity.ID, entity.DocumentID, entity.MethodID, entity.EntityName, schemaJSON, entity.DetectedTimestamp, entity.MethodName, entity.HighlightColor)
+		rows.AddRow(entity.ID, entity.DocumentID, entity.MethodID, entity.EntityName, schemaJSON, entity.DetectedTimestamp, entity.Status, entity.ReviewedBy, entity.ReviewedAt, entity.MethodName, entity.HighlightColor)
 	}
 
 	// Expected query with placeholder for document ID
-	mock.ExpectQuery("SELECT de\\.entity_id, de\\.document_id, de\\.method_id, de\\.entity_name, de\\.redaction_schema, de\\.detected_timestamp, dm\\.method_name, dm\\.highlight_color FROM detected_entities de JOIN detection_methods dm ON de\\.method_id = dm\\.method_id WHERE de\\.document_id = \\$1 ORDER BY de\\.detected_timestamp DESC").
+	mock.ExpectQuery("SELECT de\\.entity_id, de\\.document_id, de\\.method_id, de\\.entity_name, de\\.redaction_schema, de\\.detected_timestamp, de\\.status, de\\.reviewed_by, de\\.reviewed_at, dm\\.method_name, dm\\.highlight_color FROM detected_entities de JOIN detection_methods dm ON de\\.method_id = dm\\.method_id WHERE de\\.document_id = \\$1 ORDER BY de\\.detected_timestamp DESC").
 		WithArgs(documentID).
 		WillReturnRows(rows)
 
 	// Execute the method being tested
-	results, err := repo.GetDetectedEntities(context.Background(), documentID)
+	results, err := repo.GetDetectedEntities(context.Background(), documentID, models.DetectedEntityFilter{})
 
 	// Assert the results
 	assert.NoError(t, err)
@@ -930,9 +1235,101 @@ func TestDocumentRepository_GetDetectedEntities(t *testing.T) {
 	assert.Equal(t, entities[1].ID, results[1].ID)
 	assert.Equal(t, entities[1].EntityName, results[1].EntityName)
 	assert.Equal(t, entities[1].MethodName, results[1].MethodName)
+	assert.Equal(t, models.EntityStatusAccepted, results[0].Status)
+	assert.Equal(t, &reviewer, results[0].ReviewedBy)
+	assert.Equal(t, models.EntityStatusPending, results[1].Status)
+	assert.Nil(t, results[1].ReviewedBy)
+	assert.Nil(t, results[1].ReviewedAt)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestDocumentRepository_GetDetectedEntities_Filter(t *testing.T) {
+	// Set up the test
+	repo, mock, cleanup := setupDocumentRepositoryTest(t)
+	defer cleanup()
+
+	// Set up test data
+	documentID := int64(1)
+	filter := models.DetectedEntityFilter{Status: models.EntityStatusRejected, MethodID: 2}
+
+	// Each filter adds a condition with its own placeholder
+	mock.ExpectQuery("WHERE de\\.document_id = \\$1 AND de\\.status = \\$2 AND de\\.method_id = \\$3 ORDER BY").
+		WithArgs(documentID, filter.Status, filter.MethodID).
+		WillReturnRows(sqlmock.NewRows([]string{"entity_id", "document_id", "method_id", "entity_name", "redaction_schema", "detected_timestamp", "status", "reviewed_by", "reviewed_at", "method_name", "highlight_color"}))
+
+	// Execute the method being tested
+	results, err := repo.GetDetectedEntities(context.Background(), documentID, filter)
+
+	// Assert the results
+	assert.NoError(t, err)
+	assert.Empty(t, results)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestDocumentRepository_GetDetectedEntities_Keyset(t *testing.T) {
+	// Set up the test
+	repo, mock, cleanup := setupDocumentRepositoryTest(t)
+	defer cleanup()
+
+	// Set up test data
+	documentID := int64(1)
+	after := &utils.KeysetCursor{Timestamp: time.Now(), ID: 40}
+	filter := models.DetectedEntityFilter{MethodID: 2, After: after, Limit: 51}
+
+	// The cursor continues after the last entity and the limit ends the page
+	mock.ExpectQuery("WHERE de\\.document_id = \\$1 AND de\\.method_id = \\$2 AND \\(de\\.detected_timestamp, de\\.entity_id\\) < \\(\\$3, \\$4\\) "+
+		"ORDER BY de\\.detected_timestamp DESC, de\\.entity_id DESC LIMIT \\$5").
+		WithArgs(documentID, filter.MethodID, after.Timestamp, after.ID, 51).
+		WillReturnRows(sqlmock.NewRows([]string{"entity_id", "document_id", "method_id", "entity_name", "redaction_schema", "detected_timestamp", "status", "reviewed_by", "reviewed_at", "method_name", "highlight_color"}))
+
+	// Execute the method being tested
+	results, err := repo.GetDetectedEntities(context.Background(), documentID, filter)
+
+	// Assert the results
+	assert.NoError(t, err)
+	assert.Empty(t, results)
 	assert.NoError(t, mock.ExpectationsWereMet())
 }
 
+func TestDocumentRepository_UpdateEntityStatus(t *testing.T) {
+	documentID := int64(1)
+	entityIDs := []int64{10, 11}
+	query := "UPDATE detected_entities SET status = \\$1, reviewed_by = \\$2, reviewed_at = \\$3 WHERE document_id = \\$4 AND entity_id = ANY\\(\\$5\\)"
+
+	t.Run("Updates every entity", func(t *testing.T) {
+		repo, mock, cleanup := setupDocumentRepositoryTest(t)
+		defer cleanup()
+
+		mock.ExpectBegin()
+		mock.ExpectExec(query).
+			WithArgs(models.EntityStatusAccepted, int64(7), sqlmock.AnyArg(), documentID, pq.Array(entityIDs)).
+			WillReturnResult(sqlmock.NewResult(0, 2))
+		mock.ExpectCommit()
+
+		reviewedAt, err := repo.UpdateEntityStatus(context.Background(), documentID, entityIDs, models.EntityStatusAccepted, 7)
+
+		assert.NoError(t, err)
+		assert.False(t, reviewedAt.IsZero())
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("Entity of another document", func(t *testing.T) {
+		repo, mock, cleanup := setupDocumentRepositoryTest(t)
+		defer cleanup()
+
+		mock.ExpectBegin()
+		mock.ExpectExec(query).
+			WithArgs(models.EntityStatusRejected, int64(7), sqlmock.AnyArg(), documentID, pq.Array(entityIDs)).
+			WillReturnResult(sqlmock.NewResult(0, 1))
+		mock.ExpectRollback()
+
+		_, err := repo.UpdateEntityStatus(context.Background(), documentID, entityIDs, models.EntityStatusRejected, 7)
+
+		assert.True(t, errors.Is(err, utils.ErrNotFound))
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+}
+
 func TestDocumentRepository_GetDetectedEntities_QueryError(t *testing.T) {
 	// Set up the test
 	repo, mock, cleanup := setupDocumentRepositoryTest(t)
@@ -942,12 +1339,12 @@ func TestDocumentRepository_GetDetectedEntities_QueryError(t *testing.T) {
 	documentID := int64(1)
 
 	// Mock query error
-	mock.ExpectQuery("SELECT de\\.entity_id, de\\.document_id, de\\.method_id, de\\.entity_name, de\\.redaction_schema, de\\.detected_timestamp, dm\\.method_name, dm\\.highlight_color FROM detected_entities de JOIN detection_methods dm ON de\\.method_id = dm\\.method_id WHERE de\\.document_id = \\$1 ORDER BY de\\.detected_timestamp DESC").
+	mock.ExpectQuery("SELECT de\\.entity_id, de\\.document_id, de\\.method_id, de\\.entity_name, de\\.redaction_schema, de\\.detected_timestamp, de\\.status, de\\.reviewed_by, de\\.reviewed_at, dm\\.method_name, dm\\.highlight_color FROM detected_entities de JOIN detection_methods dm ON de\\.method_id = dm\\.method_id WHERE de\\.document_id = \\$1 ORDER BY de\\.detected_timestamp DESC").
 		WithArgs(documentID).
 		WillReturnError(errors.New("query error"))
 
 	// Execute the method being tested
-	results, err := repo.GetDetectedEntities(context.Background(), documentID)
+	results, err := repo.GetDetectedEntities(context.Background(), documentID, models.DetectedEntityFilter{})
 
 	// Assert the results
 	assert.Error(t, err)
@@ -965,15 +1362,15 @@ func TestDocumentRepository_GetDetectedEntities_ScanError(t *testing.T) {
 	documentID := int64(1)
 
 	// Set up query result with invalid data to cause scan error
-	rows := sqlmock.NewRows([]string{"entity_id", "document_id", "method_id", "entity_name", "redaction_schema", "detected_timestamp", "method_name", "highlight_color"}).
-		AddRow("invalid_id", documentID, 1, "Credit Card", "{}", time.Now(), "ML Model", "#FF0000") // invalid_id will cause scan error
+	rows := sqlmock.NewRows([]string{"entity_id", "document_id", "method_id", "entity_name", "redaction_schema", "detected_timestamp", "status", "reviewed_by", "reviewed_at", "method_name", "highlight_color"}).
+		AddRow("invalid_id", documentID, 1, "Credit Card", "{}", time.Now(), "pending", nil, nil, "ML Model", "#FF0000") // invalid_id will cause scan error
 
-	mock.ExpectQuery("SELECT de\\.entity_id, de\\.document_id, de\\.method_id, de\\.entity_name, de\\.redaction_schema, de\\.detected_timestamp, dm\\.method_name, dm\\.highlight_color FROM detected_entities de JOIN detection_methods dm ON de\\.method_id = dm\\.method_id WHERE de\\.document_id = \\$1 ORDER BY de\\.detected_timestamp DESC").
+	mock.ExpectQuery("SELECT de\\.entity_id, de\\.document_id, de\\.method_id, de\\.entity_name, de\\.redaction_schema, de\\.detected_timestamp, de\\.status, de\\.reviewed_by, de\\.reviewed_at, dm\\.method_name, dm\\.highlight_color FROM detected_entities de JOIN detection_methods dm ON de\\.method_id = dm\\.method_id WHERE de\\.document_id = \\$1 ORDER BY de\\.detected_timestamp DESC").
 		WithArgs(documentID).
 		WillReturnRows(rows)
 
 	// Execute the method being tested
-	results, err := repo.GetDetectedEntities(context.Background(), documentID)
+	results, err := repo.GetDetectedEntities(context.Background(), documentID, models.DetectedEntityFilter{})
 
 	// Assert the results
 	assert.Error(t, err)
@@ -991,16 +1388,16 @@ func TestDocumentRepository_GetDetectedEntities_RowsError(t *testing.T) {
 	documentID := int64(1)
 
 	// Set up query result with row error
-	rows := sqlmock.NewRows([]string{"entity_id", "document_id", "method_id", "entity_name", "redaction_schema", "detected_timestamp", "method_name", "highlight_color"}).
-		AddRow(1, documentID, 1, "Credit Card", "{}", time.Now(), "ML Model", "#FF0000").
+	rows := sqlmock.NewRows([]string{"entity_id", "document_id", "method_id", "entity_name", "redaction_schema", "detected_timestamp", "status", "reviewed_by", "reviewed_at", "method_name", "highlight_color"}).
+		AddRow(1, documentID, 1, "Credit Card", "{}", time.Now(), "pending", nil, nil, "ML Model", "#FF0000").
 		RowError(0, errors.New("row iteration error"))
 
-	mock.ExpectQuery("SELECT de\\.entity_id, de\\.document_id, de\\.method_id, de\\.entity_name, de\\.redaction_schema, de\\.detected_timestamp, dm\\.method_name, dm\\.highlight_color FROM detected_entities de JOIN detection_methods dm ON de\\.method_id = dm\\.method_id WHERE de\\.document_id = \\$1 ORDER BY de\\.detected_timestamp DESC").
+	mock.ExpectQuery("SELECT de\\.entity_id, de\\.document_id, de\\.method_id, de\\.entity_name, de\\.redaction_schema, de\\.detected_timestamp, de\\.status, de\\.reviewed_by, de\\.reviewed_at, dm\\.method_name, dm\\.highlight_color FROM detected_entities de JOIN detection_methods dm ON de\\.method_id = dm\\.method_id WHERE de\\.document_id = \\$1 ORDER BY de\\.detected_timestamp DESC").
 		WithArgs(documentID).
 		WillReturnRows(rows)
 
 	// Execute the method being tested
-	results, err := repo.GetDetectedEntities(context.Background(), documentID)
+	results, err := repo.GetDetectedEntities(context.Background(), documentID, models.DetectedEntityFilter{})
 
 	// Assert the results
 	assert.Error(t, err)
@@ -1088,6 +1485,74 @@ func TestDocumentRepository_AddDetectedEntity_Error(t *testing.T) {
 	assert.NoError(t, mock.ExpectationsWereMet())
 }
 
+func TestDocumentRepository_AddDetectedEntities(t *testing.T) {
+	// Set up the test
+	repo, mock, cleanup := setupDocumentRepositoryTest(t)
+	defer cleanup()
+
+	// Set up test data: one row more than fits in a single insert
+	now := time.Now()
+	entities := make([]*models.DetectedEntity, constants.DetectedEntityInsertRows+1)
+	for i := range entities {
+		entities[i] = &models.DetectedEntity{
+			MethodID:          1,
+			EntityName:        "Credit Card",
+			RedactionSchema:   models.RedactionSchema{Page: 1, EndX: 30.5, EndY: 40.5},
+			DetectedTimestamp: now,
+		}
+	}
+
+	first := sqlmock.NewRows([]string{"entity_id"})
+	for i := 0; i < constants.DetectedEntityInsertRows; i++ {
+		first.AddRow(100 + i)
+	}
+
+	// Both inserts run in one transaction
+	mock.ExpectBegin()
+	mock.ExpectQuery(`INSERT INTO detected_entities .+ VALUES \(\$1, \$2, \$3, \$4, \$5\), \(\$6,`).
+		WillReturnRows(first)
+	mock.ExpectQuery(`INSERT INTO detected_entities`).
+		WithArgs(int64(1), int64(1), "Credit Card", sqlmock.AnyArg(), now).
+		WillReturnRows(sqlmock.NewRows([]string{"entity_id"}).AddRow(900))
+	mock.ExpectCommit()
+
+	// Execute the method being tested
+	err := repo.AddDetectedEntities(context.Background(), 1, entities)
+
+	// Assert the results
+	assert.NoError(t, err)
+	assert.Equal(t, int64(100), entities[0].ID)
+	assert.Equal(t, int64(100+constants.DetectedEntityInsertRows-1), entities[constants.DetectedEntityInsertRows-1].ID)
+	assert.Equal(t, int64(900), entities[constants.DetectedEntityInsertRows].ID)
+	assert.Equal(t, int64(1), entities[0].DocumentID)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestDocumentRepository_AddDetectedEntities_Error(t *testing.T) {
+	// Set up the test
+	repo, mock, cleanup := setupDocumentRepositoryTest(t)
+	defer cleanup()
+
+	entities := []*models.DetectedEntity{
+		{MethodID: 1, EntityName: "Credit Card", DetectedTimestamp: time.Now()},
+		{MethodID: 99, EntityName: "Phone", DetectedTimestamp: time.Now()},
+	}
+
+	// A failing insert rolls back the whole batch
+	mock.ExpectBegin()
+	mock.ExpectQuery("INSERT INTO detected_entities").
+		WillReturnError(errors.New("insert error"))
+	mock.ExpectRollback()
+
+	// Execute the method being tested
+	err := repo.AddDetectedEntities(context.Background(), 1, entities)
+
+	// Assert the results
+	assert.Error(t, err)
+	assert.Contains(t, err.Error(), "failed to create detected entities")
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
 func TestDocumentRepository_DeleteDetectedEntity(t *testing.T) {
 	// Set up the test
 	repo, mock, cleanup := setupDocumentRepositoryTest(t)
@@ -1208,11 +1673,11 @@ func TestDocumentRepository_GetDocumentSummary(t *testing.T) {
 	}
 
 	// Set up query result
-	rows := sqlmock.NewRows([]string{"document_id", "hashed_document_name", "upload_timestamp", "last_modified", "entity_count"}).
-		AddRow(summary.ID, summary.HashedName, summary.UploadTimestamp, summary.LastModified, summary.EntityCount)
+	rows := sqlmock.NewRows([]string{"document_id", "hashed_document_name", "upload_timestamp", "last_modified", "language", "tags", "folder", "retain_until", "filename_scrubbed", "archived_at", "entity_count"}).
+		AddRow(summary.ID, summary.HashedName, summary.UploadTimestamp, summary.LastModified, summary.Language, "{}", "", nil, false, nil, summary.EntityCount)
 
 	// Expected query with placeholder for document ID
-	mock.ExpectQuery("SELECT d\\.document_id, d\\.hashed_document_name, d\\.upload_timestamp, d\\.last_modified, COUNT\\(de\\.entity_id\\) AS entity_count FROM documents d LEFT JOIN detected_entities de ON d\\.document_id = de\\.document_id WHERE d\\.document_id = \\$1 GROUP BY d\\.document_id").
+	mock.ExpectQuery("SELECT d\\.document_id, d\\.hashed_document_name, d\\.upload_timestamp, d\\.last_modified, d\\.language, d\\.tags, d\\.folder, d\\.retain_until, d\\.filename_scrubbed, d\\.archived_at, COUNT\\(de\\.entity_id\\) AS entity_count FROM documents d LEFT JOIN detected_entities de ON d\\.document_id = de\\.document_id WHERE d\\.document_id = \\$1 GROUP BY d\\.document_id").
 		WithArgs(documentID).
 		WillReturnRows(rows)
 
@@ -1239,7 +1704,7 @@ func TestDocumentRepository_GetDocumentSummary_NotFound(t *testing.T) {
 	documentID := int64(999)
 
 	// Mock database response - no rows
-	mock.ExpectQuery("SELECT d\\.document_id, d\\.hashed_document_name, d\\.upload_timestamp, d\\.last_modified, COUNT\\(de\\.entity_id\\) AS entity_count FROM documents d LEFT JOIN detected_entities de ON d\\.document_id = de\\.document_id WHERE d\\.document_id = \\$1 GROUP BY d\\.document_id").
+	mock.ExpectQuery("SELECT d\\.document_id, d\\.hashed_document_name, d\\.upload_timestamp, d\\.last_modified, d\\.language, d\\.tags, d\\.folder, d\\.retain_until, d\\.filename_scrubbed, d\\.archived_at, COUNT\\(de\\.entity_id\\) AS entity_count FROM documents d LEFT JOIN detected_entities de ON d\\.document_id = de\\.document_id WHERE d\\.document_id = \\$1 GROUP BY d\\.document_id").
 		WithArgs(documentID).
 		WillReturnError(sql.ErrNoRows)
 
@@ -1261,7 +1726,7 @@ func TestDocumentRepository_GetDocumentSummary_OtherError(t *testing.T) {
 	documentID := int64(1)
 
 	// Mock database error (not ErrNoRows)
-	mock.ExpectQuery("SELECT d\\.document_id, d\\.hashed_document_name, d\\.upload_timestamp, d\\.last_modified, COUNT\\(de\\.entity_id\\) AS entity_count FROM documents d LEFT JOIN detected_entities de ON d\\.document_id = de\\.document_id WHERE d\\.document_id = \\$1 GROUP BY d\\.document_id").
+	mock.ExpectQuery("SELECT d\\.document_id, d\\.hashed_document_name, d\\.upload_timestamp, d\\.last_modified, d\\.language, d\\.tags, d\\.folder, d\\.retain_until, d\\.filename_scrubbed, d\\.archived_at, COUNT\\(de\\.entity_id\\) AS entity_count FROM documents d LEFT JOIN detected_entities de ON d\\.document_id = de\\.document_id WHERE d\\.document_id = \\$1 GROUP BY d\\.document_id").
 		WithArgs(documentID).
 		WillReturnError(errors.New("database error"))
 
@@ -1274,3 +1739,653 @@ func TestDocumentRepository_GetDocumentSummary_OtherError(t *testing.T) {
 	assert.Contains(t, err.Error(), "failed to get document summary")
 	assert.NoError(t, mock.ExpectationsWereMet())
 }
+
+// stubTenantKeyRepository serves fixed data-encryption keys from memory
+type stubTenantKeyRepository struct {
+	keys map[int64][]byte
+}
+
+func (s *stubTenantKeyRepository) GetOrCreateDataKey(ctx context.Context, userID int64) ([]byte, error) {
+	if key, ok := s.keys[userID]; ok {
+		return key, nil
+	}
+	key := make([]byte, 32)
+	copy(key, fmt.Sprintf("tenant-key-%d", userID))
+	s.keys[userID] = key
+	return key, nil
+}
+
+func (s *stubTenantKeyRepository) GetDataKey(ctx context.Context, userID int64) ([]byte, error) {
+	if key, ok := s.keys[userID]; ok {
+		return key, nil
+	}
+	return nil, utils.NewNotFoundError("TenantKey", userID)
+}
+
+func (s *stubTenantKeyRepository) Delete(ctx context.Context, userID int64) error {
+	delete(s.keys, userID)
+	return nil
+}
+
+// capturedArg matches any value and keeps it for later assertions
+type capturedArg struct {
+	value driver.Value
+}
+
+func (c *capturedArg) Match(v driver.Value) bool {
+	c.value = v
+	return true
+}
+
+func TestDocumentRepository_TenantKeys_RoundTrip(t *testing.T) {
+	// Set up the test with tenant keys
+	db, mock, err := sqlmock.New()
+	require.NoError(t, err)
+	defer db.Close()
+
+	tenantKeys := &stubTenantKeyRepository{keys: make(map[int64][]byte)}
+	repo := repository.NewDocumentRepository(&database.Pool{DB: db}, []byte("test-encryption-key-for-unit-tests"), tenantKeys)
+
+	now := time.Now()
+	doc := &models.Document{
+		UserID:             100,
+		HashedDocumentName: "report.pdf",
+		UploadTimestamp:    now,
+		LastModified:       now,
+		RedactionSchema:    `{"pages":[]}`,
+	}
+
+	// Create stores the name and schema encrypted with the tenant key
+	name := &capturedArg{}
+	schema := &capturedArg{}
+	mock.ExpectQuery("INSERT INTO documents").
+		WithArgs(doc.UserID, name, doc.UploadTimestamp, doc.LastModified, schema, doc.Language, doc.SchemaVersion, sqlmock.AnyArg(), doc.Folder, doc.Source, doc.RetainUntil, doc.FilenameScrubbed, doc.EntityCount, sqlmock.AnyArg()).
+		WillReturnRows(sqlmock.NewRows([]string{"document_id"}).AddRow(1))
+
+	require.NoError(t, repo.Create(context.Background(), doc))
+	assert.True(t, strings.HasPrefix(name.value.(string), constants.TenantCiphertextPrefix))
+	assert.Contains(t, schema.value.(string), `"tenant_encrypted":"`+constants.TenantCiphertextPrefix)
+
+	// GetByID decrypts both with the tenant key
+	mock.ExpectQuery("SELECT document_id, user_id, hashed_document_name, upload_timestamp, last_modified, redaction_schema, language, tags, folder, source, retain_until, filename_scrubbed, archived_at FROM documents WHERE document_id = \\$1").
+		WithArgs(int64(1)).
+		WillReturnRows(sqlmock.NewRows([]string{"document_id", "user_id", "hashed_document_name", "upload_timestamp", "last_modified", "redaction_schema", "language", "tags", "folder", "source", "retain_until", "filename_scrubbed", "archived_at"}).
+			AddRow(1, doc.UserID, name.value, now, now, schema.value, "", "{}", "", "", nil, false, nil))
+
+	result, err := repo.GetByID(context.Background(), 1)
+	require.NoError(t, err)
+	assert.Equal(t, "report.pdf", result.HashedDocumentName)
+	assert.Equal(t, `{"pages":[]}`, result.RedactionSchema)
+
+	// Once the key is shredded the document can no longer be read
+	require.NoError(t, tenantKeys.Delete(context.Background(), doc.UserID))
+	mock.ExpectQuery("SELECT document_id, user_id, hashed_document_name").
+		WithArgs(int64(1)).
+		WillReturnRows(sqlmock.NewRows([]string{"document_id", "user_id", "hashed_document_name", "upload_timestamp", "last_modified", "redaction_schema", "language", "tags", "folder", "source", "retain_until", "filename_scrubbed", "archived_at"}).
+			AddRow(1, doc.UserID, name.value, now, now, schema.value, "", "{}", "", "", nil, false, nil))
+
+	_, err = repo.GetByID(context.Background(), 1)
+	assert.Error(t, err)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestDocumentRepository_ReencryptLegacy(t *testing.T) {
+	// Set up the test with tenant keys
+	db, mock, err := sqlmock.New()
+	require.NoError(t, err)
+	defer db.Close()
+
+	masterKey := []byte("test-encryption-key-for-unit-tests")
+	tenantKeys := &stubTenantKeyRepository{keys: make(map[int64][]byte)}
+	repo := repository.NewDocumentRepository(&database.Pool{DB: db}, masterKey, tenantKeys)
+
+	legacyName, err := utils.EncryptKey("legacy.pdf", masterKey)
+	require.NoError(t, err)
+
+	// One legacy document is re-encrypted with the tenant key
+	mock.ExpectQuery("SELECT document_id, user_id, hashed_document_name, redaction_schema FROM documents").
+		WithArgs(constants.TenantCiphertextPrefix+"%", 10).
+		WillReturnRows(sqlmock.NewRows([]string{"document_id", "user_id", "hashed_document_name", "redaction_schema"}).
+			AddRow(1, 100, legacyName, "{}"))
+
+	name := &capturedArg{}
+	mock.ExpectExec("UPDATE documents SET hashed_document_name = \\$1, redaction_schema = \\$2").
+		WithArgs(name, "{}", int64(1), legacyName).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+
+	// No legacy redaction schemas are left
+	mock.ExpectQuery("SELECT de.entity_id, d.user_id, de.redaction_schema FROM detected_entities de").
+		WithArgs(constants.TenantCiphertextPrefix+"%", 10).
+		WillReturnRows(sqlmock.NewRows([]string{"entity_id", "user_id", "redaction_schema"}))
+
+	// Execute the method being tested
+	count, err := repo.ReencryptLegacy(context.Background(), 10)
+
+	// Assert the results
+	require.NoError(t, err)
+	assert.Equal(t, int64(1), count)
+	assert.True(t, strings.HasPrefix(name.value.(string), constants.TenantCiphertextPrefix))
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestDocumentRepository_ReencryptLegacy_WithoutTenantKeys(t *testing.T) {
+	// Set up the test
+	repo, mock, cleanup := setupDocumentRepositoryTest(t)
+	defer cleanup()
+
+	// Execute the method being tested
+	count, err := repo.ReencryptLegacy(context.Background(), 10)
+
+	// Assert that nothing was queried
+	assert.NoError(t, err)
+	assert.Equal(t, int64(0), count)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestDocumentRepository_GetOutdatedSchemas(t *testing.T) {
+	// Set up the test
+	repo, mock, cleanup := setupDocumentRepositoryTest(t)
+	defer cleanup()
+
+	mock.ExpectQuery("SELECT document_id, user_id, redaction_schema, schema_version FROM documents WHERE schema_version < \\$1 AND archived_at IS NULL ORDER BY document_id LIMIT \\$2").
+		WithArgs(models.RedactionSchemaVersion, 10).
+		WillReturnRows(sqlmock.NewRows([]string{"document_id", "user_id", "redaction_schema", "schema_version"}).
+			AddRow(1, 100, `{"file_results": "encrypted"}`, 1))
+
+	// Execute the method being tested
+	documents, err := repo.GetOutdatedSchemas(context.Background(), models.RedactionSchemaVersion, 10)
+
+	// Assert the results
+	require.NoError(t, err)
+	require.Len(t, documents, 1)
+	assert.Equal(t, int64(100), documents[0].UserID)
+	assert.Equal(t, `{"file_results": "encrypted"}`, documents[0].RedactionSchema)
+	assert.Equal(t, models.RedactionSchemaVersionAbsolute, documents[0].SchemaVersion)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestDocumentRepository_UpdateRedactionSchema(t *testing.T) {
+	// Set up the test
+	repo, mock, cleanup := setupDocumentRepositoryTest(t)
+	defer cleanup()
+
+	entityCount := 3
+	doc := &models.Document{ID: 1, UserID: 100, RedactionSchema: `{"file_results": "encrypted"}`, SchemaVersion: models.RedactionSchemaVersion, EntityCount: &entityCount, EntityTypes: []string{"EMAIL", "PERSON"}}
+
+	mock.ExpectExec("UPDATE documents SET redaction_schema = \\$1, schema_version = \\$2, entity_count = \\$3, entity_types = \\$4 WHERE document_id = \\$5").
+		WithArgs(doc.RedactionSchema, doc.SchemaVersion, doc.EntityCount, pq.Array(doc.EntityTypes), doc.ID).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectExec("UPDATE documents SET redaction_schema").
+		WithArgs(doc.RedactionSchema, doc.SchemaVersion, doc.EntityCount, pq.Array(doc.EntityTypes), int64(2)).
+		WillReturnResult(sqlmock.NewResult(0, 0))
+
+	// Execute the method being tested
+	require.NoError(t, repo.UpdateRedactionSchema(context.Background(), doc))
+
+	// A missing document is reported as not found
+	doc.ID = 2
+	err := repo.UpdateRedactionSchema(context.Background(), doc)
+	assert.True(t, errors.Is(err, utils.ErrNotFound))
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestDocumentRepository_GetMissingEntitySummaries(t *testing.T) {
+	// Set up the test
+	repo, mock, cleanup := setupDocumentRepositoryTest(t)
+	defer cleanup()
+
+	mock.ExpectQuery("SELECT document_id, user_id, redaction_schema FROM documents WHERE entity_count IS NULL AND archived_at IS NULL ORDER BY document_id LIMIT \\$1").
+		WithArgs(10).
+		WillReturnRows(sqlmock.NewRows([]string{"document_id", "user_id", "redaction_schema"}).
+			AddRow(1, 100, `{"file_results": "encrypted"}`))
+
+	// Execute the method being tested
+	documents, err := repo.GetMissingEntitySummaries(context.Background(), 10)
+
+	// Assert the results
+	require.NoError(t, err)
+	require.Len(t, documents, 1)
+	assert.Equal(t, int64(100), documents[0].UserID)
+	assert.Equal(t, `{"file_results": "encrypted"}`, documents[0].RedactionSchema)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestDocumentRepository_UpdateEntitySummary(t *testing.T) {
+	// Set up the test
+	repo, mock, cleanup := setupDocumentRepositoryTest(t)
+	defer cleanup()
+
+	entityCount := 0
+	doc := &models.Document{ID: 1, EntityCount: &entityCount}
+
+	// Documents without detected entities store an empty array instead of NULL
+	mock.ExpectExec("UPDATE documents SET entity_count = \\$1, entity_types = \\$2 WHERE document_id = \\$3").
+		WithArgs(doc.EntityCount, pq.Array([]string{}), doc.ID).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectExec("UPDATE documents SET entity_count").
+		WithArgs(doc.EntityCount, pq.Array([]string{}), int64(2)).
+		WillReturnResult(sqlmock.NewResult(0, 0))
+
+	// Execute the method being tested
+	require.NoError(t, repo.UpdateEntitySummary(context.Background(), doc))
+
+	// A missing document is reported as not found
+	doc.ID = 2
+	err := repo.UpdateEntitySummary(context.Background(), doc)
+	assert.True(t, errors.Is(err, utils.ErrNotFound))
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestDocumentRepository_GetTimeline(t *testing.T) {
+	// Set up the test
+	repo, mock, cleanup := setupDocumentRepositoryTest(t)
+	defer cleanup()
+
+	// Set up test data
+	documentID := int64(1)
+	uploaded := time.Now().Add(-time.Hour)
+	detected := uploaded.Add(time.Minute)
+
+	mock.ExpectQuery("SELECT COUNT\\(\\*\\) FROM \\(.+\\) events").
+		WithArgs(documentID).
+		WillReturnRows(sqlmock.NewRows([]string{"count"}).AddRow(3))
+
+	rows := sqlmock.NewRows([]string{"event_type", "occurred_at", "entity_count", "method_name"}).
+		AddRow("uploaded", uploaded, 0, "").
+		AddRow("entities_detected", detected, 4, "Presidio")
+	mock.ExpectQuery("SELECT event_type, occurred_at, entity_count, method_name FROM \\(.+\\) events ORDER BY occurred_at, event_type LIMIT \\$2 OFFSET \\$3").
+		WithArgs(documentID, 2, 0).
+		WillReturnRows(rows)
+
+	// Execute the method being tested
+	events, total, err := repo.GetTimeline(context.Background(), documentID, 1, 2)
+
+	// Assert the results
+	require.NoError(t, err)
+	assert.Equal(t, 3, total)
+	require.Len(t, events, 2)
+	assert.Equal(t, models.EventDocumentUploaded, events[0].Type)
+	assert.Equal(t, models.EventEntitiesDetected, events[1].Type)
+	assert.Equal(t, 4, events[1].EntityCount)
+	assert.Equal(t, "Presidio", events[1].MethodName)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestDocumentRepository_GetTimeline_CountError(t *testing.T) {
+	// Set up the test
+	repo, mock, cleanup := setupDocumentRepositoryTest(t)
+	defer cleanup()
+
+	mock.ExpectQuery("SELECT COUNT").
+		WithArgs(int64(1)).
+		WillReturnError(errors.New("database error"))
+
+	// Execute the method being tested
+	events, total, err := repo.GetTimeline(context.Background(), 1, 1, 20)
+
+	// Assert the results
+	assert.Error(t, err)
+	assert.Contains(t, err.Error(), "failed to count document events")
+	assert.Nil(t, events)
+	assert.Equal(t, 0, total)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+// expiredCondition matches the retention of documents set by classification rules or by
+// the document retention setting of their owners.
+const expiredCondition = "\\(retain_until <= \\$1 OR EXISTS \\( SELECT 1 FROM user_settings s WHERE s.user_id = documents.user_id " +
+	"AND s.document_retention_days > 0 AND documents.upload_timestamp \\+ make_interval\\(days => s.document_retention_days\\) <= \\$1 \\)\\)"
+
+func TestDocumentRepository_DeleteExpired(t *testing.T) {
+	// Set up the test
+	repo, mock, cleanup := setupDocumentRepositoryTest(t)
+	defer cleanup()
+
+	now := time.Now()
+
+	// The detected entities of expired documents are deleted before the documents
+	mock.ExpectBegin()
+	mock.ExpectExec("DELETE FROM detected_entities WHERE document_id IN \\( SELECT document_id FROM documents WHERE " + expiredCondition + " \\)").
+		WithArgs(now).
+		WillReturnResult(sqlmock.NewResult(0, 4))
+	mock.ExpectExec("DELETE FROM documents WHERE " + expiredCondition).
+		WithArgs(now).
+		WillReturnResult(sqlmock.NewResult(0, 2))
+	mock.ExpectCommit()
+
+	deleted, err := repo.DeleteExpired(context.Background(), now, 0)
+
+	assert.NoError(t, err)
+	assert.Equal(t, int64(2), deleted)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestDocumentRepository_DeleteExpired_Batch(t *testing.T) {
+	// Set up the test
+	repo, mock, cleanup := setupDocumentRepositoryTest(t)
+	defer cleanup()
+
+	now := time.Now()
+
+	// Both deletes select the same batch of documents expired the longest ago
+	batch := "SELECT document_id FROM documents WHERE " + expiredCondition + " ORDER BY retain_until, document_id LIMIT \\$2 FOR UPDATE"
+	mock.ExpectBegin()
+	mock.ExpectExec("DELETE FROM detected_entities WHERE document_id IN \\( "+batch+" \\)").
+		WithArgs(now, 50).
+		WillReturnResult(sqlmock.NewResult(0, 80))
+	mock.ExpectExec("DELETE FROM documents WHERE document_id IN \\("+batch+"\\)").
+		WithArgs(now, 50).
+		WillReturnResult(sqlmock.NewResult(0, 50))
+	mock.ExpectCommit()
+
+	deleted, err := repo.DeleteExpired(context.Background(), now, 50)
+
+	assert.NoError(t, err)
+	assert.Equal(t, int64(50), deleted)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestDocumentRepository_CountExpired(t *testing.T) {
+	// Set up the test
+	repo, mock, cleanup := setupDocumentRepositoryTest(t)
+	defer cleanup()
+
+	now := time.Now()
+	mock.ExpectQuery("SELECT COUNT\\(\\*\\) FROM documents WHERE " + expiredCondition).
+		WithArgs(now).
+		WillReturnRows(sqlmock.NewRows([]string{"count"}).AddRow(3))
+
+	count, err := repo.CountExpired(context.Background(), now)
+
+	assert.NoError(t, err)
+	assert.Equal(t, int64(3), count)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestDocumentRepository_PreviewExpired(t *testing.T) {
+	// Set up the test
+	repo, mock, cleanup := setupDocumentRepositoryTest(t)
+	defer cleanup()
+
+	now := time.Date(2026, 3, 1, 12, 0, 0, 0, time.UTC)
+	retainUntil := now.AddDate(0, 0, -1)
+	columns := []string{"document_id", "upload_timestamp", "retain_until", "detected_entities", "count", "sum"}
+
+	// The counts cover the documents beyond the limit as well
+	mock.ExpectQuery("SELECT document_id, upload_timestamp, retain_until, detected_entities, COUNT\\(\\*\\) OVER \\(\\), (.+) "+
+		"WHERE d.user_id = \\$1 AND \\(d.retain_until <= \\$2 OR \\(\\$3::int > 0 (.+)\\)\\) \\) expired ORDER BY document_id LIMIT \\$4").
+		WithArgs(int64(100), now, 30, 2).
+		WillReturnRows(sqlmock.NewRows(columns).
+			AddRow(1, now.AddDate(0, 0, -10), retainUntil, 4, 3, 9).
+			AddRow(2, now.AddDate(0, 0, -40), nil, 5, 3, 9))
+
+	preview, err := repo.PreviewExpired(context.Background(), 100, now, 30, 2)
+
+	require.NoError(t, err)
+	assert.Equal(t, &models.RetentionPreview{
+		RetentionDays: 30,
+		DocumentCount: 3,
+		EntityCount:   9,
+		Documents: []*models.ExpiredDocument{
+			{DocumentID: 1, UploadTimestamp: now.AddDate(0, 0, -10), RetainUntil: &retainUntil, EntityCount: 4, Reason: constants.RetentionReasonRule},
+			{DocumentID: 2, UploadTimestamp: now.AddDate(0, 0, -40), EntityCount: 5, Reason: constants.RetentionReasonSetting},
+		},
+	}, preview)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestDocumentRepository_PreviewExpired_Empty(t *testing.T) {
+	// Set up the test
+	repo, mock, cleanup := setupDocumentRepositoryTest(t)
+	defer cleanup()
+
+	now := time.Now()
+	mock.ExpectQuery("SELECT document_id, upload_timestamp, retain_until, detected_entities").
+		WithArgs(int64(100), now, 0, 500).
+		WillReturnRows(sqlmock.NewRows([]string{"document_id", "upload_timestamp", "retain_until", "detected_entities", "count", "sum"}))
+
+	preview, err := repo.PreviewExpired(context.Background(), 100, now, 0, 500)
+
+	require.NoError(t, err)
+	assert.Equal(t, int64(0), preview.DocumentCount)
+	assert.NotNil(t, preview.Documents)
+	assert.Empty(t, preview.Documents)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestDocumentRepository_DeleteExpired_Error(t *testing.T) {
+	// Set up the test
+	repo, mock, cleanup := setupDocumentRepositoryTest(t)
+	defer cleanup()
+
+	mock.ExpectBegin()
+	mock.ExpectExec("DELETE FROM detected_entities").
+		WillReturnError(errors.New("database error"))
+	mock.ExpectRollback()
+
+	deleted, err := repo.DeleteExpired(context.Background(), time.Now(), 0)
+
+	assert.Error(t, err)
+	assert.Contains(t, err.Error(), "failed to delete detected entities of expired documents")
+	assert.Equal(t, int64(0), deleted)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestDocumentRepository_ListStale(t *testing.T) {
+	// Set up the test
+	repo, mock, cleanup := setupDocumentRepositoryTest(t)
+	defer cleanup()
+
+	cutoff := time.Now().AddDate(0, 0, -30)
+	lastActivity := cutoff.AddDate(0, 0, -5)
+	mock.ExpectQuery("FROM documents d LEFT JOIN detected_entities de .* WHERE d.archived_at IS NULL AND NOT d.lifecycle_exempt .* WHERE \\(pending > 0 OR \\(entities = 0 AND schema_entities = 0\\)\\) AND last_activity < \\$1 AND \\(stale_notified_at IS NULL OR stale_notified_at < last_activity\\)").
+		WithArgs(cutoff, 500).
+		WillReturnRows(sqlmock.NewRows([]string{"document_id", "user_id", "reason", "last_activity"}).
+			AddRow(4, 1, "unprocessed", lastActivity).
+			AddRow(7, 2, "pending_review", lastActivity))
+
+	documents, err := repo.ListStale(context.Background(), cutoff, 500)
+
+	require.NoError(t, err)
+	assert.Equal(t, []*models.StaleDocument{
+		{DocumentID: 4, UserID: 1, Reason: "unprocessed", LastActivity: lastActivity},
+		{DocumentID: 7, UserID: 2, Reason: "pending_review", LastActivity: lastActivity},
+	}, documents)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestDocumentRepository_ListStaleDue(t *testing.T) {
+	// Set up the test
+	repo, mock, cleanup := setupDocumentRepositoryTest(t)
+	defer cleanup()
+
+	cutoff := time.Now().AddDate(0, 0, -7)
+	mock.ExpectQuery("AND stale_notified_at < \\$1 AND last_activity <= stale_notified_at").
+		WithArgs(cutoff, 500).
+		WillReturnError(errors.New("database error"))
+
+	documents, err := repo.ListStaleDue(context.Background(), cutoff, 500)
+
+	assert.Error(t, err)
+	assert.Contains(t, err.Error(), "failed to list stale documents")
+	assert.Nil(t, documents)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestDocumentRepository_MarkStaleNotified(t *testing.T) {
+	// Set up the test
+	repo, mock, cleanup := setupDocumentRepositoryTest(t)
+	defer cleanup()
+
+	now := time.Now()
+	mock.ExpectExec("UPDATE documents SET stale_notified_at = \\$1 WHERE document_id = ANY\\(\\$2\\)").
+		WithArgs(now, sqlmock.AnyArg()).
+		WillReturnResult(sqlmock.NewResult(0, 2))
+
+	err := repo.MarkStaleNotified(context.Background(), []int64{4, 7}, now)
+
+	assert.NoError(t, err)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestDocumentRepository_SetLifecycleExempt(t *testing.T) {
+	// Set up the test
+	repo, mock, cleanup := setupDocumentRepositoryTest(t)
+	defer cleanup()
+
+	// Changing the exemption forgets the notice already sent
+	mock.ExpectExec("UPDATE documents SET lifecycle_exempt = \\$1, stale_notified_at = NULL WHERE document_id = \\$2").
+		WithArgs(true, int64(4)).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectExec("UPDATE documents SET lifecycle_exempt").
+		WithArgs(false, int64(9)).
+		WillReturnResult(sqlmock.NewResult(0, 0))
+
+	assert.NoError(t, repo.SetLifecycleExempt(context.Background(), 4, true))
+
+	err := repo.SetLifecycleExempt(context.Background(), 9, false)
+	assert.True(t, errors.Is(err, utils.ErrNotFound))
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestDocumentRepository_TransferOwnership(t *testing.T) {
+	// Set up the test with tenant keys
+	db, mock, err := sqlmock.New()
+	require.NoError(t, err)
+	defer db.Close()
+
+	masterKey := []byte("test-encryption-key-for-unit-tests")
+	tenantKeys := &stubTenantKeyRepository{keys: make(map[int64][]byte)}
+	repo := repository.NewDocumentRepository(&database.Pool{DB: db}, masterKey, tenantKeys)
+
+	now := time.Now()
+	doc := &models.Document{UserID: 100, HashedDocumentName: "report.pdf", UploadTimestamp: now, LastModified: now, RedactionSchema: `{"pages":[]}`}
+	name := &capturedArg{}
+	schema := &capturedArg{}
+	mock.ExpectQuery("INSERT INTO documents").
+		WithArgs(doc.UserID, name, doc.UploadTimestamp, doc.LastModified, schema, doc.Language, doc.SchemaVersion, sqlmock.AnyArg(), doc.Folder, doc.Source, doc.RetainUntil, doc.FilenameScrubbed, doc.EntityCount, sqlmock.AnyArg()).
+		WillReturnRows(sqlmock.NewRows([]string{"document_id"}).AddRow(1))
+	require.NoError(t, repo.Create(context.Background(), doc))
+
+	// An entity schema still encrypted with the master key
+	entity := &models.DetectedEntity{RedactionSchema: models.RedactionSchema{Page: 1, RedactionMethod: "blackout"}}
+	require.NoError(t, entity.EncryptRedactionSchema(masterKey))
+	entitySchema, err := entity.RedactionSchema.Value()
+	require.NoError(t, err)
+
+	// The name and schemas are re-encrypted with the key of the new owner
+	newName := &capturedArg{}
+	newSchema := &capturedArg{}
+	newEntitySchema := &capturedArg{}
+	mock.ExpectBegin()
+	mock.ExpectQuery("SELECT hashed_document_name, redaction_schema FROM documents WHERE document_id = \\$1 AND user_id = \\$2 FOR UPDATE").
+		WithArgs(int64(1), int64(100)).
+		WillReturnRows(sqlmock.NewRows([]string{"hashed_document_name", "redaction_schema"}).AddRow(name.value, schema.value))
+	mock.ExpectExec("UPDATE documents SET user_id = \\$1, hashed_document_name = \\$2, redaction_schema = \\$3 WHERE document_id = \\$4").
+		WithArgs(int64(200), newName, newSchema, int64(1)).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectQuery("SELECT entity_id, redaction_schema FROM detected_entities WHERE document_id = \\$1").
+		WithArgs(int64(1)).
+		WillReturnRows(sqlmock.NewRows([]string{"entity_id", "redaction_schema"}).AddRow(5, entitySchema))
+	mock.ExpectExec("UPDATE detected_entities SET redaction_schema = \\$1 WHERE entity_id = \\$2").
+		WithArgs(newEntitySchema, int64(5)).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	newVersionSchema := &capturedArg{}
+	mock.ExpectQuery("SELECT version_id, redaction_schema FROM document_schema_versions WHERE document_id = \\$1").
+		WithArgs(int64(1)).
+		WillReturnRows(sqlmock.NewRows([]string{"version_id", "redaction_schema"}).AddRow(8, schema.value))
+	mock.ExpectExec("UPDATE document_schema_versions SET redaction_schema = \\$1 WHERE version_id = \\$2").
+		WithArgs(newVersionSchema, int64(8)).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectExec("DELETE FROM document_shares WHERE document_id = \\$1 AND user_id = \\$2").
+		WithArgs(int64(1), int64(200)).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectExec("DELETE FROM entity_hashes WHERE document_id = \\$1").
+		WithArgs(int64(1)).
+		WillReturnResult(sqlmock.NewResult(0, 3))
+	mock.ExpectExec("UPDATE document_contents SET storage_key = \\$1 WHERE document_id = \\$2").
+		WithArgs("200/new-key", int64(1)).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectCommit()
+
+	require.NoError(t, repo.TransferOwnership(context.Background(), 1, 100, 200, "200/new-key"))
+	assert.NotEqual(t, name.value, newName.value)
+
+	// The previous owner's key no longer opens the document; the new owner's does
+	require.NoError(t, tenantKeys.Delete(context.Background(), 100))
+	mock.ExpectQuery("SELECT document_id, user_id, hashed_document_name").
+		WithArgs(int64(1)).
+		WillReturnRows(sqlmock.NewRows([]string{"document_id", "user_id", "hashed_document_name", "upload_timestamp", "last_modified", "redaction_schema", "language", "tags", "folder", "source", "retain_until", "filename_scrubbed", "archived_at"}).
+			AddRow(1, 200, newName.value, now, now, newSchema.value, "", "{}", "", "", nil, false, nil))
+	result, err := repo.GetByID(context.Background(), 1)
+	require.NoError(t, err)
+	assert.Equal(t, "report.pdf", result.HashedDocumentName)
+	assert.Equal(t, `{"pages":[]}`, result.RedactionSchema)
+	assert.Contains(t, string(newEntitySchema.value.([]byte)), constants.TenantCiphertextPrefix)
+	assert.NotEqual(t, schema.value, newVersionSchema.value)
+
+	// Documents of other users are not transferred
+	mock.ExpectBegin()
+	mock.ExpectQuery("SELECT hashed_document_name, redaction_schema FROM documents").
+		WithArgs(int64(2), int64(100)).
+		WillReturnError(sql.ErrNoRows)
+	mock.ExpectRollback()
+
+	err = repo.TransferOwnership(context.Background(), 2, 100, 200, "")
+	assert.True(t, errors.Is(err, utils.ErrNotFound))
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestDocumentRepository_SchemaVersions(t *testing.T) {
+	// Set up the test with tenant keys
+	db, mock, err := sqlmock.New()
+	require.NoError(t, err)
+	defer db.Close()
+
+	tenantKeys := &stubTenantKeyRepository{keys: make(map[int64][]byte)}
+	repo := repository.NewDocumentRepository(&database.Pool{DB: db}, []byte("test-encryption-key-for-unit-tests"), tenantKeys)
+	now := time.Now()
+
+	// The replaced schema is sealed with the key of the owner and numbered after the latest version
+	version := &models.DocumentSchemaVersion{DocumentID: 1, RedactionSchema: `{"file_results": "abc"}`, EntityCount: 2, EntityTypes: []string{"PERSON"}, ReplacedBy: 7}
+	sealed := &capturedArg{}
+	mock.ExpectQuery("INSERT INTO document_schema_versions .* SELECT \\$1, COALESCE\\(MAX\\(version\\), 0\\) \\+ 1").
+		WithArgs(int64(1), sealed, 2, sqlmock.AnyArg(), int64(7)).
+		WillReturnRows(sqlmock.NewRows([]string{"version", "replaced_at"}).AddRow(3, now))
+
+	require.NoError(t, repo.CreateSchemaVersion(context.Background(), 100, version))
+	assert.Equal(t, 3, version.Version)
+	assert.Equal(t, now, version.ReplacedAt)
+	assert.NotContains(t, sealed.value, "abc")
+
+	// Versions are listed newest first
+	mock.ExpectQuery("SELECT document_id, version, entity_count, entity_types, replaced_by, replaced_at FROM document_schema_versions WHERE document_id = \\$1 ORDER BY version DESC").
+		WithArgs(int64(1)).
+		WillReturnRows(sqlmock.NewRows([]string{"document_id", "version", "entity_count", "entity_types", "replaced_by", "replaced_at"}).
+			AddRow(1, 3, 2, "{PERSON}", 7, now).
+			AddRow(1, 2, 0, "{}", 7, now))
+
+	versions, err := repo.GetSchemaVersions(context.Background(), 1)
+	require.NoError(t, err)
+	require.Len(t, versions, 2)
+	assert.Equal(t, 3, versions[0].Version)
+	assert.Equal(t, []string{"PERSON"}, versions[0].EntityTypes)
+
+	// A single version is opened with the key of the owner
+	mock.ExpectQuery("SELECT version_id, document_id, version, redaction_schema").
+		WithArgs(int64(1), 3).
+		WillReturnRows(sqlmock.NewRows([]string{"version_id", "document_id", "version", "redaction_schema", "entity_count", "entity_types", "replaced_by", "replaced_at"}).
+			AddRow(8, 1, 3, sealed.value, 2, "{PERSON}", 7, now))
+
+	stored, err := repo.GetSchemaVersion(context.Background(), 100, 1, 3)
+	require.NoError(t, err)
+	assert.Equal(t, `{"file_results": "abc"}`, stored.RedactionSchema)
+
+	mock.ExpectQuery("SELECT version_id").
+		WithArgs(int64(1), 9).
+		WillReturnError(sql.ErrNoRows)
+
+	_, err = repo.GetSchemaVersion(context.Background(), 100, 1, 9)
+	assert.True(t, errors.Is(err, utils.ErrNotFound))
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
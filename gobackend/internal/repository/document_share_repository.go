@@ -0,0 +1,310 @@
+// Package repository provides data access interfaces and implementations for the HideMe application.
+// It follows the repository pattern to abstract database operations and provide a clean API
+// for data persistence operations.
+//
+// This file implements the document share repository, which stores the access users granted
+// other users to their documents.
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/yasinhessnawi1/Hideme_Backend/internal/constants"
+	"github.com/yasinhessnawi1/Hideme_Backend/internal/database"
+	"github.com/yasinhessnawi1/Hideme_Backend/internal/models"
+	"github.com/yasinhessnawi1/Hideme_Backend/internal/utils"
+)
+
+// DocumentShareRepository defines methods for managing the shares of documents.
+type DocumentShareRepository interface {
+	// Upsert shares a document with a user, replacing the permission of an existing share.
+	//
+	// Parameters:
+	//   - ctx: Context for transaction and cancellation control
+	//   - share: The share to store; its ID and creation time are populated
+	//
+	// Returns:
+	//   - An error if the share could not be stored
+	Upsert(ctx context.Context, share *models.DocumentShare) error
+
+	// GetPermission retrieves the access a user was granted to a document.
+	//
+	// Parameters:
+	//   - ctx: Context for transaction and cancellation control
+	//   - documentID: The ID of the document
+	//   - userID: The ID of the user
+	//
+	// Returns:
+	//   - The permission of the share
+	//   - NotFoundError if the document is not shared with the user
+	//   - Other errors for database issues
+	GetPermission(ctx context.Context, documentID, userID int64) (string, error)
+
+	// ListByDocument retrieves the shares of a document with the email of each user.
+	//
+	// Parameters:
+	//   - ctx: Context for transaction and cancellation control
+	//   - documentID: The ID of the document
+	//
+	// Returns:
+	//   - The shares of the document, oldest first
+	//   - An error for database issues
+	ListByDocument(ctx context.Context, documentID int64) ([]*models.DocumentShare, error)
+
+	// ListByUser retrieves the shares granted to a user.
+	//
+	// Parameters:
+	//   - ctx: Context for transaction and cancellation control
+	//   - userID: The ID of the user the documents are shared with
+	//
+	// Returns:
+	//   - The shares granted to the user, newest first
+	//   - An error for database issues
+	ListByUser(ctx context.Context, userID int64) ([]*models.DocumentShare, error)
+
+	// Delete revokes the share of a document with a user.
+	//
+	// Parameters:
+	//   - ctx: Context for transaction and cancellation control
+	//   - documentID: The ID of the document
+	//   - userID: The ID of the user
+	//
+	// Returns:
+	//   - NotFoundError if the document is not shared with the user
+	//   - Other errors for database issues
+	Delete(ctx context.Context, documentID, userID int64) error
+}
+
+// PostgresDocumentShareRepository is a PostgreSQL implementation of DocumentShareRepository.
+type PostgresDocumentShareRepository struct {
+	db *database.Pool
+}
+
+// NewDocumentShareRepository creates a new DocumentShareRepository implementation for PostgreSQL.
+//
+// Parameters:
+//   - db: A connection pool for PostgreSQL database access
+//
+// Returns:
+//   - An implementation of the DocumentShareRepository interface
+func NewDocumentShareRepository(db *database.Pool) DocumentShareRepository {
+	return &PostgresDocumentShareRepository{
+		db: db,
+	}
+}
+
+// Upsert shares a document with a user, replacing the permission of an existing share.
+func (r *PostgresDocumentShareRepository) Upsert(ctx context.Context, share *models.DocumentShare) error {
+	// Start query timer
+	startTime := time.Now()
+
+	// Define the query
+	query := `
+        INSERT INTO ` + constants.TableDocumentShares + ` (document_id, user_id, granted_by, permission, created_at)
+        VALUES ($1, $2, $3, $4, $5)
+        ON CONFLICT (document_id, user_id) DO UPDATE
+        SET granted_by = EXCLUDED.granted_by, permission = EXCLUDED.permission
+        RETURNING share_id, created_at
+    `
+	args := []interface{}{share.DocumentID, share.UserID, share.GrantedBy, share.Permission, time.Now()}
+
+	// Execute the query
+	err := r.db.QueryRowContext(ctx, query, args...).Scan(&share.ID, &share.CreatedAt)
+
+	// Log the query execution
+	utils.LogDBQuery(
+		query,
+		args,
+		time.Since(startTime),
+		err,
+	)
+
+	if err != nil {
+		return fmt.Errorf("failed to share document: %w", err)
+	}
+
+	return nil
+}
+
+// GetPermission retrieves the access a user was granted to a document.
+func (r *PostgresDocumentShareRepository) GetPermission(ctx context.Context, documentID, userID int64) (string, error) {
+	// Start query timer
+	startTime := time.Now()
+
+	// Define the query
+	query := `
+        SELECT permission
+        FROM ` + constants.TableDocumentShares + `
+        WHERE ` + constants.ColumnDocumentID + ` = $1 AND ` + constants.ColumnUserID + ` = $2
+    `
+	args := []interface{}{documentID, userID}
+
+	// Execute the query
+	var permission string
+	err := r.db.QueryRowContext(ctx, query, args...).Scan(&permission)
+
+	// Log the query execution
+	utils.LogDBQuery(
+		query,
+		args,
+		time.Since(startTime),
+		err,
+	)
+
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return "", utils.NewNotFoundError("DocumentShare", documentID)
+		}
+		return "", fmt.Errorf("failed to get document share: %w", err)
+	}
+
+	return permission, nil
+}
+
+// ListByDocument retrieves the shares of a document with the email of each user.
+func (r *PostgresDocumentShareRepository) ListByDocument(ctx context.Context, documentID int64) ([]*models.DocumentShare, error) {
+	// Start query timer
+	startTime := time.Now()
+
+	// Define the query
+	query := `
+        SELECT s.share_id, s.document_id, s.user_id, u.email, s.granted_by, s.permission, s.created_at
+        FROM ` + constants.TableDocumentShares + ` s
+        JOIN ` + constants.TableUsers + ` u ON u.user_id = s.user_id
+        WHERE s.` + constants.ColumnDocumentID + ` = $1
+        ORDER BY s.created_at, s.share_id
+    `
+	args := []interface{}{documentID}
+
+	// Execute the query
+	rows, err := r.db.QueryContext(ctx, query, args...)
+
+	// Log the query execution
+	utils.LogDBQuery(
+		query,
+		args,
+		time.Since(startTime),
+		err,
+	)
+
+	if err != nil {
+		return nil, fmt.Errorf("failed to list document shares: %w", err)
+	}
+	defer rows.Close()
+
+	var shares []*models.DocumentShare
+	for rows.Next() {
+		share := &models.DocumentShare{}
+		if err := rows.Scan(
+			&share.ID,
+			&share.DocumentID,
+			&share.UserID,
+			&share.Email,
+			&share.GrantedBy,
+			&share.Permission,
+			&share.CreatedAt,
+		); err != nil {
+			return nil, fmt.Errorf("failed to scan document share: %w", err)
+		}
+		shares = append(shares, share)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating document shares: %w", err)
+	}
+
+	return shares, nil
+}
+
+// ListByUser retrieves the shares granted to a user.
+func (r *PostgresDocumentShareRepository) ListByUser(ctx context.Context, userID int64) ([]*models.DocumentShare, error) {
+	// Start query timer
+	startTime := time.Now()
+
+	// Define the query
+	query := `
+        SELECT share_id, document_id, user_id, granted_by, permission, created_at
+        FROM ` + constants.TableDocumentShares + `
+        WHERE ` + constants.ColumnUserID + ` = $1
+        ORDER BY created_at DESC, share_id DESC
+    `
+	args := []interface{}{userID}
+
+	// Execute the query
+	rows, err := r.db.QueryContext(ctx, query, args...)
+
+	// Log the query execution
+	utils.LogDBQuery(
+		query,
+		args,
+		time.Since(startTime),
+		err,
+	)
+
+	if err != nil {
+		return nil, fmt.Errorf("failed to list shared documents: %w", err)
+	}
+	defer rows.Close()
+
+	var shares []*models.DocumentShare
+	for rows.Next() {
+		share := &models.DocumentShare{}
+		if err := rows.Scan(
+			&share.ID,
+			&share.DocumentID,
+			&share.UserID,
+			&share.GrantedBy,
+			&share.Permission,
+			&share.CreatedAt,
+		); err != nil {
+			return nil, fmt.Errorf("failed to scan document share: %w", err)
+		}
+		shares = append(shares, share)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating document shares: %w", err)
+	}
+
+	return shares, nil
+}
+
+// Delete revokes the share of a document with a user.
+func (r *PostgresDocumentShareRepository) Delete(ctx context.Context, documentID, userID int64) error {
+	// Start query timer
+	startTime := time.Now()
+
+	// Define the query
+	query := `
+        DELETE FROM ` + constants.TableDocumentShares + `
+        WHERE ` + constants.ColumnDocumentID + ` = $1 AND ` + constants.ColumnUserID + ` = $2
+    `
+	args := []interface{}{documentID, userID}
+
+	// Execute the query
+	result, err := r.db.ExecContext(ctx, query, args...)
+
+	// Log the query execution
+	utils.LogDBQuery(
+		query,
+		args,
+		time.Since(startTime),
+		err,
+	)
+
+	if err != nil {
+		return fmt.Errorf("failed to delete document share: %w", err)
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("failed to get rows affected: %w", err)
+	}
+	if rowsAffected == 0 {
+		return utils.NewNotFoundError("DocumentShare", documentID)
+	}
+
+	return nil
+}
@@ -0,0 +1,121 @@
+package repository_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/yasinhessnawi1/Hideme_Backend/internal/constants"
+	"github.com/yasinhessnawi1/Hideme_Backend/internal/database"
+	"github.com/yasinhessnawi1/Hideme_Backend/internal/models"
+	"github.com/yasinhessnawi1/Hideme_Backend/internal/repository"
+	"github.com/yasinhessnawi1/Hideme_Backend/internal/utils"
+)
+
+func setupDocumentShareTest(t *testing.T) (repository.DocumentShareRepository, sqlmock.Sqlmock, func()) {
+	db, mock, err := sqlmock.New()
+	require.NoError(t, err)
+
+	return repository.NewDocumentShareRepository(&database.Pool{DB: db}), mock, func() { db.Close() }
+}
+
+func TestDocumentShareRepository_Upsert(t *testing.T) {
+	repo, mock, cleanup := setupDocumentShareTest(t)
+	defer cleanup()
+	now := time.Now()
+	share := &models.DocumentShare{DocumentID: 12, UserID: 8, GrantedBy: 7, Permission: constants.SharePermissionRedact}
+
+	mock.ExpectQuery("INSERT INTO document_shares (.+) ON CONFLICT \\(document_id, user_id\\) DO UPDATE").
+		WithArgs(int64(12), int64(8), int64(7), constants.SharePermissionRedact, sqlmock.AnyArg()).
+		WillReturnRows(sqlmock.NewRows([]string{"share_id", "created_at"}).AddRow(3, now))
+
+	require.NoError(t, repo.Upsert(context.Background(), share))
+	assert.Equal(t, int64(3), share.ID)
+	assert.Equal(t, now, share.CreatedAt)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestDocumentShareRepository_GetPermission(t *testing.T) {
+	repo, mock, cleanup := setupDocumentShareTest(t)
+	defer cleanup()
+
+	mock.ExpectQuery("SELECT permission FROM document_shares WHERE document_id = \\$1 AND user_id = \\$2").
+		WithArgs(int64(12), int64(8)).
+		WillReturnRows(sqlmock.NewRows([]string{"permission"}).AddRow(constants.SharePermissionRead))
+
+	permission, err := repo.GetPermission(context.Background(), 12, 8)
+	require.NoError(t, err)
+	assert.Equal(t, constants.SharePermissionRead, permission)
+
+	// The document is not shared with the user
+	mock.ExpectQuery("SELECT permission FROM document_shares").
+		WillReturnRows(sqlmock.NewRows([]string{"permission"}))
+
+	_, err = repo.GetPermission(context.Background(), 12, 9)
+	var appErr *utils.AppError
+	require.True(t, errors.As(err, &appErr))
+	assert.True(t, errors.Is(appErr.Err, utils.ErrNotFound))
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestDocumentShareRepository_ListByDocument(t *testing.T) {
+	repo, mock, cleanup := setupDocumentShareTest(t)
+	defer cleanup()
+	now := time.Now()
+
+	mock.ExpectQuery("SELECT (.+) FROM document_shares s JOIN users u (.+) WHERE s.document_id = \\$1").
+		WithArgs(int64(12)).
+		WillReturnRows(sqlmock.NewRows([]string{"share_id", "document_id", "user_id", "email", "granted_by", "permission", "created_at"}).
+			AddRow(3, 12, 8, "kari@example.com", 7, constants.SharePermissionRedact, now).
+			AddRow(4, 12, 9, "ola@example.com", 7, constants.SharePermissionRead, now))
+
+	shares, err := repo.ListByDocument(context.Background(), 12)
+	require.NoError(t, err)
+	require.Len(t, shares, 2)
+	assert.Equal(t, "kari@example.com", shares[0].Email)
+	assert.Equal(t, constants.SharePermissionRead, shares[1].Permission)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestDocumentShareRepository_ListByUser(t *testing.T) {
+	repo, mock, cleanup := setupDocumentShareTest(t)
+	defer cleanup()
+	now := time.Now()
+
+	mock.ExpectQuery("SELECT (.+) FROM document_shares WHERE user_id = \\$1 ORDER BY created_at DESC").
+		WithArgs(int64(8)).
+		WillReturnRows(sqlmock.NewRows([]string{"share_id", "document_id", "user_id", "granted_by", "permission", "created_at"}).
+			AddRow(3, 12, 8, 7, constants.SharePermissionRedact, now))
+
+	shares, err := repo.ListByUser(context.Background(), 8)
+	require.NoError(t, err)
+	require.Len(t, shares, 1)
+	assert.Equal(t, int64(12), shares[0].DocumentID)
+	assert.Equal(t, int64(7), shares[0].GrantedBy)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestDocumentShareRepository_Delete(t *testing.T) {
+	repo, mock, cleanup := setupDocumentShareTest(t)
+	defer cleanup()
+
+	mock.ExpectExec("DELETE FROM document_shares WHERE document_id = \\$1 AND user_id = \\$2").
+		WithArgs(int64(12), int64(8)).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	require.NoError(t, repo.Delete(context.Background(), 12, 8))
+
+	// The document is not shared with the user
+	mock.ExpectExec("DELETE FROM document_shares").
+		WillReturnResult(sqlmock.NewResult(0, 0))
+
+	err := repo.Delete(context.Background(), 12, 9)
+	var appErr *utils.AppError
+	require.True(t, errors.As(err, &appErr))
+	assert.True(t, errors.Is(appErr.Err, utils.ErrNotFound))
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
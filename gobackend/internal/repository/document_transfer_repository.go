@@ -0,0 +1,218 @@
+// Package repository provides data access interfaces and implementations for the HideMe application.
+// It follows the repository pattern to abstract database operations and provide a clean API
+// for data persistence operations.
+//
+// This file implements the document transfer repository, which keeps the audit trail of
+// administrators transferring documents between users.
+package repository
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/lib/pq"
+
+	"github.com/yasinhessnawi1/Hideme_Backend/internal/constants"
+	"github.com/yasinhessnawi1/Hideme_Backend/internal/database"
+	"github.com/yasinhessnawi1/Hideme_Backend/internal/models"
+	"github.com/yasinhessnawi1/Hideme_Backend/internal/utils"
+)
+
+// DocumentTransferRepository defines methods for recording transfers of documents between users.
+type DocumentTransferRepository interface {
+	// Create records a transfer of documents.
+	//
+	// Parameters:
+	//   - ctx: Context for transaction and cancellation control
+	//   - transfer: The transfer to record; its ID and creation time are set on success
+	//
+	// Returns:
+	//   - An error for database issues
+	Create(ctx context.Context, transfer *models.DocumentTransfer) error
+
+	// Update stores the progress of a transfer: its transferred and failed documents,
+	// its status and when it completed.
+	//
+	// Parameters:
+	//   - ctx: Context for transaction and cancellation control
+	//   - transfer: The transfer to update
+	//
+	// Returns:
+	//   - NotFoundError if the transfer doesn't exist
+	//   - An error for database issues
+	Update(ctx context.Context, transfer *models.DocumentTransfer) error
+
+	// List retrieves the most recent transfers, optionally only those from or to a user.
+	//
+	// Parameters:
+	//   - ctx: Context for transaction and cancellation control
+	//   - userID: The ID of the user the documents were transferred from or to; 0 lists all transfers
+	//   - limit: The maximum number of transfers to return
+	//
+	// Returns:
+	//   - The transfers, latest first
+	//   - An error for database issues
+	List(ctx context.Context, userID int64, limit int) ([]*models.DocumentTransfer, error)
+}
+
+// PostgresDocumentTransferRepository is a PostgreSQL implementation of DocumentTransferRepository.
+type PostgresDocumentTransferRepository struct {
+	db *database.Pool
+}
+
+// NewDocumentTransferRepository creates a new DocumentTransferRepository implementation for PostgreSQL.
+//
+// Parameters:
+//   - db: A connection pool for PostgreSQL database access
+//
+// Returns:
+//   - An implementation of the DocumentTransferRepository interface
+func NewDocumentTransferRepository(db *database.Pool) DocumentTransferRepository {
+	return &PostgresDocumentTransferRepository{
+		db: db,
+	}
+}
+
+// Create records a transfer of documents.
+func (r *PostgresDocumentTransferRepository) Create(ctx context.Context, transfer *models.DocumentTransfer) error {
+	// Start query timer
+	startTime := time.Now()
+
+	// Define the query
+	query := `
+        INSERT INTO ` + constants.TableDocumentTransfers + ` (from_user_id, to_user_id, transferred_by, reason, document_ids, failed_document_ids, status, completed_at)
+        VALUES ($1, $2, $3, $4, $5, $6, $7, $8)
+        RETURNING transfer_id, created_at
+    `
+	args := []interface{}{
+		transfer.FromUserID,
+		transfer.ToUserID,
+		transfer.TransferredBy,
+		transfer.Reason,
+		pq.Array(transfer.DocumentIDs),
+		pq.Array(transfer.FailedDocumentIDs),
+		transfer.Status,
+		transfer.CompletedAt,
+	}
+
+	// Execute the query
+	err := r.db.QueryRowContext(ctx, query, args...).Scan(&transfer.ID, &transfer.CreatedAt)
+
+	// Log the query execution
+	utils.LogDBQuery(
+		query,
+		args,
+		time.Since(startTime),
+		err,
+	)
+
+	if err != nil {
+		return fmt.Errorf("failed to record document transfer: %w", err)
+	}
+
+	return nil
+}
+
+// Update stores the progress of a transfer.
+func (r *PostgresDocumentTransferRepository) Update(ctx context.Context, transfer *models.DocumentTransfer) error {
+	// Start query timer
+	startTime := time.Now()
+
+	// Define the query
+	query := `
+        UPDATE ` + constants.TableDocumentTransfers + `
+        SET document_ids = $2, failed_document_ids = $3, status = $4, completed_at = $5
+        WHERE transfer_id = $1
+    `
+	args := []interface{}{
+		transfer.ID,
+		pq.Array(transfer.DocumentIDs),
+		pq.Array(transfer.FailedDocumentIDs),
+		transfer.Status,
+		transfer.CompletedAt,
+	}
+
+	// Execute the query
+	result, err := r.db.ExecContext(ctx, query, args...)
+
+	// Log the query execution
+	utils.LogDBQuery(
+		query,
+		args,
+		time.Since(startTime),
+		err,
+	)
+
+	if err != nil {
+		return fmt.Errorf("failed to update document transfer: %w", err)
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("failed to get rows affected: %w", err)
+	}
+	if rowsAffected == 0 {
+		return utils.NewNotFoundError("DocumentTransfer", transfer.ID)
+	}
+
+	return nil
+}
+
+// List retrieves the most recent transfers, optionally only those from or to a user.
+func (r *PostgresDocumentTransferRepository) List(ctx context.Context, userID int64, limit int) ([]*models.DocumentTransfer, error) {
+	// Start query timer
+	startTime := time.Now()
+
+	// Define the query
+	query := `
+        SELECT transfer_id, from_user_id, to_user_id, transferred_by, reason, document_ids, failed_document_ids, status, created_at, completed_at
+        FROM ` + constants.TableDocumentTransfers + `
+        WHERE $1::BIGINT = 0 OR from_user_id = $1 OR to_user_id = $1
+        ORDER BY created_at DESC, transfer_id DESC
+        LIMIT $2
+    `
+	args := []interface{}{userID, limit}
+
+	// Execute the query
+	rows, err := r.db.QueryContext(ctx, query, args...)
+
+	// Log the query execution
+	utils.LogDBQuery(
+		query,
+		args,
+		time.Since(startTime),
+		err,
+	)
+
+	if err != nil {
+		return nil, fmt.Errorf("failed to list document transfers: %w", err)
+	}
+	defer rows.Close()
+
+	transfers := []*models.DocumentTransfer{}
+	for rows.Next() {
+		transfer := &models.DocumentTransfer{}
+		if err := rows.Scan(
+			&transfer.ID,
+			&transfer.FromUserID,
+			&transfer.ToUserID,
+			&transfer.TransferredBy,
+			&transfer.Reason,
+			pq.Array(&transfer.DocumentIDs),
+			pq.Array(&transfer.FailedDocumentIDs),
+			&transfer.Status,
+			&transfer.CreatedAt,
+			&transfer.CompletedAt,
+		); err != nil {
+			return nil, fmt.Errorf("failed to scan document transfer row: %w", err)
+		}
+		transfers = append(transfers, transfer)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating document transfer rows: %w", err)
+	}
+
+	return transfers, nil
+}
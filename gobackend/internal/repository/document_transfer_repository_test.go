@@ -0,0 +1,111 @@
+package repository_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/yasinhessnawi1/Hideme_Backend/internal/database"
+	"github.com/yasinhessnawi1/Hideme_Backend/internal/models"
+	"github.com/yasinhessnawi1/Hideme_Backend/internal/repository"
+	"github.com/yasinhessnawi1/Hideme_Backend/internal/utils"
+)
+
+func setupDocumentTransferTest(t *testing.T) (repository.DocumentTransferRepository, sqlmock.Sqlmock, func()) {
+	db, mock, err := sqlmock.New()
+	require.NoError(t, err)
+
+	return repository.NewDocumentTransferRepository(&database.Pool{DB: db}), mock, func() { db.Close() }
+}
+
+func TestDocumentTransferRepository_Create(t *testing.T) {
+	repo, mock, cleanup := setupDocumentTransferTest(t)
+	defer cleanup()
+	now := time.Now()
+	transfer := &models.DocumentTransfer{
+		FromUserID:        4,
+		ToUserID:          9,
+		TransferredBy:     1,
+		Reason:            "Employee left the company",
+		DocumentIDs:       []int64{},
+		FailedDocumentIDs: []int64{},
+		Status:            models.TransferStatusPending,
+	}
+
+	mock.ExpectQuery("INSERT INTO document_transfers").
+		WithArgs(int64(4), int64(9), int64(1), "Employee left the company", "{}", "{}", models.TransferStatusPending, nil).
+		WillReturnRows(sqlmock.NewRows([]string{"transfer_id", "created_at"}).AddRow(3, now))
+
+	require.NoError(t, repo.Create(context.Background(), transfer))
+	assert.Equal(t, int64(3), transfer.ID)
+	assert.Equal(t, now, transfer.CreatedAt)
+
+	mock.ExpectQuery("INSERT INTO document_transfers").
+		WillReturnError(errors.New("connection reset"))
+
+	assert.Error(t, repo.Create(context.Background(), transfer))
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestDocumentTransferRepository_Update(t *testing.T) {
+	repo, mock, cleanup := setupDocumentTransferTest(t)
+	defer cleanup()
+	completedAt := time.Now()
+	transfer := &models.DocumentTransfer{
+		ID:                3,
+		DocumentIDs:       []int64{12, 15},
+		FailedDocumentIDs: []int64{19},
+		Status:            models.TransferStatusCompleted,
+		CompletedAt:       &completedAt,
+	}
+
+	mock.ExpectExec("UPDATE document_transfers SET document_ids = \\$2, failed_document_ids = \\$3, status = \\$4, completed_at = \\$5 WHERE transfer_id = \\$1").
+		WithArgs(int64(3), "{12,15}", "{19}", models.TransferStatusCompleted, &completedAt).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+
+	require.NoError(t, repo.Update(context.Background(), transfer))
+
+	mock.ExpectExec("UPDATE document_transfers").
+		WillReturnResult(sqlmock.NewResult(0, 0))
+
+	err := repo.Update(context.Background(), transfer)
+	assert.True(t, utils.IsNotFoundError(err), "Update() error = %v, want not found", err)
+
+	mock.ExpectExec("UPDATE document_transfers").
+		WillReturnError(errors.New("connection reset"))
+
+	assert.Error(t, repo.Update(context.Background(), transfer))
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestDocumentTransferRepository_List(t *testing.T) {
+	repo, mock, cleanup := setupDocumentTransferTest(t)
+	defer cleanup()
+	now := time.Now()
+
+	mock.ExpectQuery("SELECT (.+) FROM document_transfers WHERE \\$1::BIGINT = 0 OR from_user_id = \\$1 OR to_user_id = \\$1 ORDER BY created_at DESC(.+) LIMIT \\$2").
+		WithArgs(int64(4), 50).
+		WillReturnRows(sqlmock.NewRows([]string{"transfer_id", "from_user_id", "to_user_id", "transferred_by", "reason", "document_ids", "failed_document_ids", "status", "created_at", "completed_at"}).
+			AddRow(3, 4, 9, 1, "Employee left the company", "{12,15}", "{}", "completed", now, now))
+
+	transfers, err := repo.List(context.Background(), 4, 50)
+	require.NoError(t, err)
+	require.Len(t, transfers, 1)
+	assert.Equal(t, int64(9), transfers[0].ToUserID)
+	assert.Equal(t, []int64{12, 15}, transfers[0].DocumentIDs)
+	assert.Empty(t, transfers[0].FailedDocumentIDs)
+	assert.Equal(t, models.TransferStatusCompleted, transfers[0].Status)
+	require.NotNil(t, transfers[0].CompletedAt)
+
+	mock.ExpectQuery("SELECT (.+) FROM document_transfers").
+		WillReturnError(errors.New("connection reset"))
+
+	_, err = repo.List(context.Background(), 0, 50)
+	assert.Error(t, err)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
@@ -0,0 +1,127 @@
+// Package repository provides data access interfaces and implementations for the HideMe application.
+// It follows the repository pattern to abstract database operations and provide a clean API
+// for data persistence operations.
+//
+// This file implements the append-only log of domain changes. Repositories of derived
+// tables record an event in the same transaction as they update the table, and rebuild
+// the table by replaying the events through the same code.
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/yasinhessnawi1/Hideme_Backend/internal/constants"
+	"github.com/yasinhessnawi1/Hideme_Backend/internal/models"
+	"github.com/yasinhessnawi1/Hideme_Backend/internal/utils"
+)
+
+// appendEvent records a domain change within a transaction.
+//
+// Parameters:
+//   - ctx: Context for transaction and cancellation control
+//   - tx: The transaction updating the derived table
+//   - eventType: The kind of change, such as constants.EventUsageCounted
+//   - userID: The ID of the user the change belongs to
+//   - occurredAt: When the change happened
+//   - payload: The change, encoded as JSON
+//
+// Returns:
+//   - An error if the event could not be recorded
+func appendEvent(ctx context.Context, tx *sql.Tx, eventType string, userID int64, occurredAt time.Time, payload interface{}) error {
+	data, err := json.Marshal(payload)
+	if err != nil {
+		return fmt.Errorf("failed to marshal %s event: %w", eventType, err)
+	}
+
+	query := `
+        INSERT INTO ` + constants.TableDomainEvents + ` (event_type, ` + constants.ColumnUserID + `, payload, occurred_at)
+        VALUES ($1, $2, $3, $4)
+    `
+	if _, err := tx.ExecContext(ctx, query, eventType, userID, data, occurredAt); err != nil {
+		return fmt.Errorf("failed to record %s event: %w", eventType, err)
+	}
+	return nil
+}
+
+// replayEvents applies the events of a type to a derived table within a transaction, in the
+// order they were recorded. The events are read constants.DomainEventReplayBatchSize at a time.
+//
+// Parameters:
+//   - ctx: Context for transaction and cancellation control
+//   - tx: The transaction rebuilding the derived table
+//   - eventType: The kind of events to replay
+//   - apply: Applies one event to the derived table
+//
+// Returns:
+//   - The number of replayed events
+//   - An error if reading or applying an event fails
+func replayEvents(ctx context.Context, tx *sql.Tx, eventType string, apply func(event *models.DomainEvent) error) (int64, error) {
+	query := `
+        SELECT event_id, event_type, ` + constants.ColumnUserID + `, payload, occurred_at
+        FROM ` + constants.TableDomainEvents + `
+        WHERE event_type = $1 AND event_id > $2
+        ORDER BY event_id ASC
+        LIMIT $3
+    `
+
+	var replayed, lastID int64
+	for {
+		startTime := time.Now()
+		events, err := readEvents(ctx, tx, query, eventType, lastID)
+		utils.LogDBQuery(query, []interface{}{eventType, lastID, constants.DomainEventReplayBatchSize}, time.Since(startTime), err)
+		if err != nil {
+			return replayed, err
+		}
+
+		for _, event := range events {
+			if err := apply(event); err != nil {
+				return replayed, fmt.Errorf("failed to replay event %d: %w", event.ID, err)
+			}
+			replayed++
+			lastID = event.ID
+		}
+
+		if len(events) < constants.DomainEventReplayBatchSize {
+			return replayed, nil
+		}
+	}
+}
+
+// readEvents reads one batch of events, closing the rows before they are applied.
+func readEvents(ctx context.Context, tx *sql.Tx, query, eventType string, afterID int64) ([]*models.DomainEvent, error) {
+	rows, err := tx.QueryContext(ctx, query, eventType, afterID, constants.DomainEventReplayBatchSize)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read %s events: %w", eventType, err)
+	}
+	defer rows.Close()
+
+	var events []*models.DomainEvent
+	for rows.Next() {
+		event := &models.DomainEvent{}
+		var payload []byte
+		if err := rows.Scan(&event.ID, &event.Type, &event.UserID, &payload, &event.OccurredAt); err != nil {
+			return nil, fmt.Errorf("failed to scan %s event: %w", eventType, err)
+		}
+		event.Payload = payload
+		events = append(events, event)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating %s events: %w", eventType, err)
+	}
+	return events, nil
+}
+
+// lockProjection keeps other transactions from changing a derived table while it is rebuilt.
+// Transactions recording an event wait for the rebuild to finish before they update the
+// table, so their change is applied on top of the rebuilt rows rather than lost.
+func lockProjection(ctx context.Context, tx *sql.Tx, table string) error {
+	if _, err := tx.ExecContext(ctx, `LOCK TABLE `+table+` IN EXCLUSIVE MODE`); err != nil {
+		return fmt.Errorf("failed to lock %s: %w", table, err)
+	}
+	return nil
+}
@@ -0,0 +1,299 @@
+// Package repository provides data access interfaces and implementations for the HideMe application.
+// It follows the repository pattern to abstract database operations and provide a clean API
+// for data persistence operations.
+//
+// This file implements the drive connection repository, which stores users' authorizations
+// of cloud drives. The OAuth tokens are encrypted with the tenant's data-encryption key, so
+// they are shredded together with the tenant's documents.
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/yasinhessnawi1/Hideme_Backend/internal/constants"
+	"github.com/yasinhessnawi1/Hideme_Backend/internal/database"
+	"github.com/yasinhessnawi1/Hideme_Backend/internal/models"
+	"github.com/yasinhessnawi1/Hideme_Backend/internal/utils"
+)
+
+// DriveConnectionRepository defines methods for storing users' cloud drive authorizations.
+type DriveConnectionRepository interface {
+	// Get retrieves a user's authorization of a cloud drive, with the tokens decrypted.
+	//
+	// Parameters:
+	//   - ctx: Context for transaction and cancellation control
+	//   - userID: The ID of the user
+	//   - provider: The cloud drive, such as google_drive
+	//
+	// Returns:
+	//   - The connection
+	//   - NotFoundError if the user has not connected the drive
+	//   - Other errors for database or decryption issues
+	Get(ctx context.Context, userID int64, provider string) (*models.DriveConnection, error)
+
+	// ListByUser retrieves the cloud drives a user has connected, without their tokens.
+	//
+	// Parameters:
+	//   - ctx: Context for transaction and cancellation control
+	//   - userID: The ID of the user
+	//
+	// Returns:
+	//   - The connections (empty if there are none)
+	//   - An error for database issues
+	ListByUser(ctx context.Context, userID int64) ([]*models.DriveConnection, error)
+
+	// Save stores a user's authorization of a cloud drive, replacing the tokens of an existing one.
+	//
+	// Parameters:
+	//   - ctx: Context for transaction and cancellation control
+	//   - conn: The connection to store
+	//
+	// Returns:
+	//   - An error for database or encryption issues
+	Save(ctx context.Context, conn *models.DriveConnection) error
+
+	// Delete removes a user's authorization of a cloud drive.
+	//
+	// Parameters:
+	//   - ctx: Context for transaction and cancellation control
+	//   - userID: The ID of the user
+	//   - provider: The cloud drive, such as google_drive
+	//
+	// Returns:
+	//   - NotFoundError if the user has not connected the drive
+	//   - Other errors for database issues
+	Delete(ctx context.Context, userID int64, provider string) error
+}
+
+// PostgresDriveConnectionRepository is a PostgreSQL implementation of DriveConnectionRepository.
+type PostgresDriveConnectionRepository struct {
+	db         *database.Pool
+	tenantKeys TenantKeyRepository
+}
+
+// NewDriveConnectionRepository creates a new DriveConnectionRepository implementation for PostgreSQL.
+//
+// Parameters:
+//   - db: A connection pool for PostgreSQL database access
+//   - tenantKeys: Repository for the data-encryption keys the tokens are encrypted with
+//
+// Returns:
+//   - An implementation of the DriveConnectionRepository interface
+func NewDriveConnectionRepository(db *database.Pool, tenantKeys TenantKeyRepository) DriveConnectionRepository {
+	return &PostgresDriveConnectionRepository{
+		db:         db,
+		tenantKeys: tenantKeys,
+	}
+}
+
+// Get retrieves a user's authorization of a cloud drive, with the tokens decrypted.
+func (r *PostgresDriveConnectionRepository) Get(ctx context.Context, userID int64, provider string) (*models.DriveConnection, error) {
+	// Start query timer
+	startTime := time.Now()
+
+	// Define the query
+	query := `
+        SELECT ` + constants.ColumnUserID + `, provider, access_token, refresh_token, expires_at, created_at, updated_at
+        FROM ` + constants.TableDriveConnections + `
+        WHERE ` + constants.ColumnUserID + ` = $1 AND provider = $2
+    `
+
+	// Execute the query
+	conn := &models.DriveConnection{}
+	err := r.db.QueryRowContext(ctx, query, userID, provider).Scan(
+		&conn.UserID, &conn.Provider, &conn.AccessToken, &conn.RefreshToken,
+		&conn.ExpiresAt, &conn.CreatedAt, &conn.UpdatedAt,
+	)
+
+	// Log the query execution
+	utils.LogDBQuery(
+		query,
+		[]interface{}{userID, provider},
+		time.Since(startTime),
+		err,
+	)
+
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, utils.NewNotFoundError("Drive connection", provider).WithCause(err)
+		}
+		return nil, fmt.Errorf("failed to get drive connection: %w", err)
+	}
+
+	if conn.AccessToken, err = r.decrypt(ctx, userID, conn.AccessToken); err != nil {
+		return nil, fmt.Errorf("failed to decrypt access token: %w", err)
+	}
+	if conn.RefreshToken, err = r.decrypt(ctx, userID, conn.RefreshToken); err != nil {
+		return nil, fmt.Errorf("failed to decrypt refresh token: %w", err)
+	}
+
+	return conn, nil
+}
+
+// ListByUser retrieves the cloud drives a user has connected, without their tokens.
+func (r *PostgresDriveConnectionRepository) ListByUser(ctx context.Context, userID int64) ([]*models.DriveConnection, error) {
+	// Start query timer
+	startTime := time.Now()
+
+	// Define the query
+	query := `
+        SELECT ` + constants.ColumnUserID + `, provider, created_at, updated_at
+        FROM ` + constants.TableDriveConnections + `
+        WHERE ` + constants.ColumnUserID + ` = $1
+        ORDER BY provider
+    `
+
+	// Execute the query
+	rows, err := r.db.QueryContext(ctx, query, userID)
+
+	// Log the query execution
+	utils.LogDBQuery(
+		query,
+		[]interface{}{userID},
+		time.Since(startTime),
+		err,
+	)
+
+	if err != nil {
+		return nil, fmt.Errorf("failed to list drive connections: %w", err)
+	}
+	defer rows.Close()
+
+	connections := []*models.DriveConnection{}
+	for rows.Next() {
+		conn := &models.DriveConnection{}
+		if err := rows.Scan(&conn.UserID, &conn.Provider, &conn.CreatedAt, &conn.UpdatedAt); err != nil {
+			return nil, fmt.Errorf("failed to scan drive connection row: %w", err)
+		}
+		connections = append(connections, conn)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating drive connection rows: %w", err)
+	}
+
+	return connections, nil
+}
+
+// Save stores a user's authorization of a cloud drive, replacing the tokens of an existing one.
+func (r *PostgresDriveConnectionRepository) Save(ctx context.Context, conn *models.DriveConnection) error {
+	accessToken, err := r.encrypt(ctx, conn.UserID, conn.AccessToken)
+	if err != nil {
+		return fmt.Errorf("failed to encrypt access token: %w", err)
+	}
+	refreshToken, err := r.encrypt(ctx, conn.UserID, conn.RefreshToken)
+	if err != nil {
+		return fmt.Errorf("failed to encrypt refresh token: %w", err)
+	}
+
+	// Start query timer
+	startTime := time.Now()
+
+	// Define the query; the connection keeps the time it was first created
+	query := `
+        INSERT INTO ` + constants.TableDriveConnections + ` (` + constants.ColumnUserID + `, provider, access_token, refresh_token, expires_at, created_at, updated_at)
+        VALUES ($1, $2, $3, $4, $5, $6, $6)
+        ON CONFLICT (` + constants.ColumnUserID + `, provider) DO UPDATE SET
+            access_token = EXCLUDED.access_token,
+            refresh_token = EXCLUDED.refresh_token,
+            expires_at = EXCLUDED.expires_at,
+            updated_at = EXCLUDED.updated_at
+        RETURNING created_at
+    `
+	now := time.Now()
+
+	// Execute the query
+	err = r.db.QueryRowContext(ctx, query, conn.UserID, conn.Provider, accessToken, refreshToken, conn.ExpiresAt, now).
+		Scan(&conn.CreatedAt)
+
+	// Log the query execution; the tokens are left out
+	utils.LogDBQuery(
+		query,
+		[]interface{}{conn.UserID, conn.Provider, conn.ExpiresAt},
+		time.Since(startTime),
+		err,
+	)
+
+	if err != nil {
+		return fmt.Errorf("failed to save drive connection: %w", err)
+	}
+	conn.UpdatedAt = now
+
+	return nil
+}
+
+// Delete removes a user's authorization of a cloud drive.
+func (r *PostgresDriveConnectionRepository) Delete(ctx context.Context, userID int64, provider string) error {
+	// Start query timer
+	startTime := time.Now()
+
+	// Define the query
+	query := `
+        DELETE FROM ` + constants.TableDriveConnections + `
+        WHERE ` + constants.ColumnUserID + ` = $1 AND provider = $2
+    `
+
+	// Execute the query
+	result, err := r.db.ExecContext(ctx, query, userID, provider)
+
+	// Log the query execution
+	utils.LogDBQuery(
+		query,
+		[]interface{}{userID, provider},
+		time.Since(startTime),
+		err,
+	)
+
+	if err != nil {
+		return fmt.Errorf("failed to delete drive connection: %w", err)
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("failed to get affected rows: %w", err)
+	}
+
+	if rowsAffected == 0 {
+		return utils.NewNotFoundError("Drive connection", provider)
+	}
+
+	return nil
+}
+
+// encrypt encrypts a token with the data-encryption key of a tenant. Empty tokens stay empty.
+func (r *PostgresDriveConnectionRepository) encrypt(ctx context.Context, userID int64, token string) (string, error) {
+	if token == "" {
+		return "", nil
+	}
+
+	dataKey, err := r.tenantKeys.GetOrCreateDataKey(ctx, userID)
+	if err != nil {
+		return "", fmt.Errorf("failed to get tenant data key: %w", err)
+	}
+
+	ciphertext, err := utils.EncryptKey(token, dataKey)
+	if err != nil {
+		return "", err
+	}
+
+	return constants.TenantCiphertextPrefix + ciphertext, nil
+}
+
+// decrypt decrypts a token encrypted with the data-encryption key of a tenant.
+func (r *PostgresDriveConnectionRepository) decrypt(ctx context.Context, userID int64, ciphertext string) (string, error) {
+	if ciphertext == "" {
+		return "", nil
+	}
+
+	dataKey, err := r.tenantKeys.GetDataKey(ctx, userID)
+	if err != nil {
+		return "", fmt.Errorf("failed to get tenant data key: %w", err)
+	}
+
+	return utils.DecryptKey(strings.TrimPrefix(ciphertext, constants.TenantCiphertextPrefix), dataKey)
+}
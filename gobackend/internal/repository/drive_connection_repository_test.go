@@ -0,0 +1,164 @@
+package repository_test
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/yasinhessnawi1/Hideme_Backend/internal/constants"
+	"github.com/yasinhessnawi1/Hideme_Backend/internal/database"
+	"github.com/yasinhessnawi1/Hideme_Backend/internal/models"
+	"github.com/yasinhessnawi1/Hideme_Backend/internal/repository"
+	"github.com/yasinhessnawi1/Hideme_Backend/internal/utils"
+)
+
+var driveConnectionTestColumns = []string{
+	"user_id", "provider", "access_token", "refresh_token", "expires_at", "created_at", "updated_at",
+}
+
+func setupDriveConnectionTest(t *testing.T) (repository.DriveConnectionRepository, sqlmock.Sqlmock, *stubTenantKeyRepository, func()) {
+	db, mock, err := sqlmock.New()
+	require.NoError(t, err)
+
+	tenantKeys := &stubTenantKeyRepository{keys: make(map[int64][]byte)}
+	repo := repository.NewDriveConnectionRepository(&database.Pool{DB: db}, tenantKeys)
+
+	return repo, mock, tenantKeys, func() { db.Close() }
+}
+
+func TestDriveConnectionRepository_SaveAndGet(t *testing.T) {
+	// Arrange
+	repo, mock, _, cleanup := setupDriveConnectionTest(t)
+	defer cleanup()
+	now := time.Now()
+	conn := &models.DriveConnection{
+		UserID:       7,
+		Provider:     constants.DriveProviderGoogle,
+		AccessToken:  "access-token",
+		RefreshToken: "refresh-token",
+		ExpiresAt:    now.Add(time.Hour),
+	}
+
+	accessToken := &capturedArg{}
+	refreshToken := &capturedArg{}
+	mock.ExpectQuery("INSERT INTO drive_connections (.+) ON CONFLICT \\(user_id, provider\\) DO UPDATE").
+		WithArgs(int64(7), constants.DriveProviderGoogle, accessToken, refreshToken, conn.ExpiresAt, sqlmock.AnyArg()).
+		WillReturnRows(sqlmock.NewRows([]string{"created_at"}).AddRow(now))
+
+	// Act
+	err := repo.Save(context.Background(), conn)
+
+	// Assert
+	require.NoError(t, err)
+	assert.True(t, strings.HasPrefix(accessToken.value.(string), constants.TenantCiphertextPrefix))
+	assert.NotContains(t, accessToken.value.(string), "access-token")
+	assert.NotContains(t, refreshToken.value.(string), "refresh-token")
+
+	// Get decrypts the tokens with the tenant key
+	mock.ExpectQuery("SELECT (.+) FROM drive_connections WHERE user_id = \\$1 AND provider = \\$2").
+		WithArgs(int64(7), constants.DriveProviderGoogle).
+		WillReturnRows(sqlmock.NewRows(driveConnectionTestColumns).
+			AddRow(7, constants.DriveProviderGoogle, accessToken.value, refreshToken.value, conn.ExpiresAt, now, now))
+
+	result, err := repo.Get(context.Background(), 7, constants.DriveProviderGoogle)
+	require.NoError(t, err)
+	assert.Equal(t, "access-token", result.AccessToken)
+	assert.Equal(t, "refresh-token", result.RefreshToken)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestDriveConnectionRepository_Get_NotFound(t *testing.T) {
+	// Arrange
+	repo, mock, _, cleanup := setupDriveConnectionTest(t)
+	defer cleanup()
+
+	mock.ExpectQuery("SELECT (.+) FROM drive_connections").
+		WithArgs(int64(7), constants.DriveProviderOneDrive).
+		WillReturnRows(sqlmock.NewRows(driveConnectionTestColumns))
+
+	// Act
+	conn, err := repo.Get(context.Background(), 7, constants.DriveProviderOneDrive)
+
+	// Assert
+	assert.Nil(t, conn)
+	assert.True(t, utils.IsNotFoundError(err))
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestDriveConnectionRepository_ListByUser(t *testing.T) {
+	// Arrange
+	repo, mock, _, cleanup := setupDriveConnectionTest(t)
+	defer cleanup()
+	now := time.Now()
+
+	mock.ExpectQuery("SELECT user_id, provider, created_at, updated_at FROM drive_connections WHERE user_id = \\$1").
+		WithArgs(int64(7)).
+		WillReturnRows(sqlmock.NewRows([]string{"user_id", "provider", "created_at", "updated_at"}).
+			AddRow(7, constants.DriveProviderSharePoint, now, now))
+
+	// Act
+	connections, err := repo.ListByUser(context.Background(), 7)
+
+	// Assert
+	require.NoError(t, err)
+	require.Len(t, connections, 1)
+	assert.Equal(t, constants.DriveProviderSharePoint, connections[0].Provider)
+	assert.Empty(t, connections[0].AccessToken)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestDriveConnectionRepository_Delete(t *testing.T) {
+	t.Run("Success", func(t *testing.T) {
+		// Arrange
+		repo, mock, _, cleanup := setupDriveConnectionTest(t)
+		defer cleanup()
+
+		mock.ExpectExec("DELETE FROM drive_connections WHERE user_id = \\$1 AND provider = \\$2").
+			WithArgs(int64(7), constants.DriveProviderGoogle).
+			WillReturnResult(sqlmock.NewResult(0, 1))
+
+		// Act
+		err := repo.Delete(context.Background(), 7, constants.DriveProviderGoogle)
+
+		// Assert
+		assert.NoError(t, err)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("Not connected", func(t *testing.T) {
+		// Arrange
+		repo, mock, _, cleanup := setupDriveConnectionTest(t)
+		defer cleanup()
+
+		mock.ExpectExec("DELETE FROM drive_connections").
+			WillReturnResult(sqlmock.NewResult(0, 0))
+
+		// Act
+		err := repo.Delete(context.Background(), 7, constants.DriveProviderGoogle)
+
+		// Assert
+		assert.True(t, utils.IsNotFoundError(err))
+	})
+
+	t.Run("Database error", func(t *testing.T) {
+		// Arrange
+		repo, mock, _, cleanup := setupDriveConnectionTest(t)
+		defer cleanup()
+
+		mock.ExpectExec("DELETE FROM drive_connections").
+			WillReturnError(errors.New("connection lost"))
+
+		// Act
+		err := repo.Delete(context.Background(), 7, constants.DriveProviderGoogle)
+
+		// Assert
+		assert.Error(t, err)
+		assert.Contains(t, err.Error(), "failed to delete drive connection")
+	})
+}
@@ -0,0 +1,293 @@
+// Package repository provides data access interfaces and implementations for the HideMe application.
+// It follows the repository pattern to abstract database operations and provide a clean API
+// for data persistence operations.
+//
+// This file implements the entity hash repository, which stores salted hashes of the values
+// detected in documents and aggregates them for analytics.
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"time"
+
+	"github.com/lib/pq"
+
+	"github.com/yasinhessnawi1/Hideme_Backend/internal/constants"
+	"github.com/yasinhessnawi1/Hideme_Backend/internal/database"
+	"github.com/yasinhessnawi1/Hideme_Backend/internal/models"
+	"github.com/yasinhessnawi1/Hideme_Backend/internal/utils"
+)
+
+// EntityHashRepository defines methods for storing and aggregating the hashes of detected values.
+type EntityHashRepository interface {
+	// ReplaceForDocument replaces the hashes of the values detected in a document.
+	//
+	// Parameters:
+	//   - ctx: Context for transaction and cancellation control
+	//   - documentID: The ID of the document
+	//   - hashes: The hashes of the values now detected in the document
+	//
+	// Returns:
+	//   - An error if the hashes could not be stored; the previous hashes are kept then
+	ReplaceForDocument(ctx context.Context, documentID int64, hashes []*models.EntityHash) error
+
+	// CountByEntityType counts the distinct values detected in the documents of a user.
+	//
+	// Parameters:
+	//   - ctx: Context for transaction and cancellation control
+	//   - userID: The ID of the user
+	//
+	// Returns:
+	//   - The distinct values and detections per entity type, most values first
+	//   - An error for database issues
+	CountByEntityType(ctx context.Context, userID int64) ([]*models.EntityTypeCount, error)
+
+	// ListDuplicates retrieves the values detected in several documents of a user.
+	//
+	// Parameters:
+	//   - ctx: Context for transaction and cancellation control
+	//   - userID: The ID of the user
+	//   - minDocuments: The number of documents a value must be detected in
+	//   - limit: The maximum number of values to return
+	//
+	// Returns:
+	//   - The repeated values, the most widespread first
+	//   - An error for database issues
+	ListDuplicates(ctx context.Context, userID int64, minDocuments, limit int) ([]*models.DuplicateLeak, error)
+
+	// ListRepeatedInDocument retrieves the values detected in a document that were detected in
+	// more than a number of the user's documents since a time.
+	//
+	// Parameters:
+	//   - ctx: Context for transaction and cancellation control
+	//   - userID: The ID of the user
+	//   - documentID: The ID of the document the values were detected in
+	//   - since: The start of the period detections are counted over
+	//   - threshold: The number of documents a value may be detected in without being returned
+	//
+	// Returns:
+	//   - The repeated values with the documents they were detected in during the period
+	//   - An error for database issues
+	ListRepeatedInDocument(ctx context.Context, userID, documentID int64, since time.Time, threshold int) ([]*models.DuplicateLeak, error)
+}
+
+// PostgresEntityHashRepository is a PostgreSQL implementation of EntityHashRepository.
+type PostgresEntityHashRepository struct {
+	db *database.Pool
+}
+
+// NewEntityHashRepository creates a new EntityHashRepository implementation for PostgreSQL.
+//
+// Parameters:
+//   - db: A connection pool for PostgreSQL database access
+//
+// Returns:
+//   - An implementation of the EntityHashRepository interface
+func NewEntityHashRepository(db *database.Pool) EntityHashRepository {
+	return &PostgresEntityHashRepository{
+		db: db,
+	}
+}
+
+// ReplaceForDocument replaces the hashes of the values detected in a document.
+func (r *PostgresEntityHashRepository) ReplaceForDocument(ctx context.Context, documentID int64, hashes []*models.EntityHash) error {
+	// Start query timer
+	startTime := time.Now()
+
+	// Execute within a transaction
+	err := r.db.Transaction(ctx, func(tx *sql.Tx) error {
+		deleteQuery := `
+            DELETE FROM ` + constants.TableEntityHashes + `
+            WHERE ` + constants.ColumnDocumentID + ` = $1
+        `
+		if _, err := tx.ExecContext(ctx, deleteQuery, documentID); err != nil {
+			return fmt.Errorf("failed to delete entity hashes: %w", err)
+		}
+
+		insertQuery := `
+            INSERT INTO ` + constants.TableEntityHashes + ` (user_id, document_id, entity_type, value_hash, occurrences, created_at)
+            VALUES ($1, $2, $3, $4, $5, $6)
+        `
+		for _, hash := range hashes {
+			if _, err := tx.ExecContext(ctx, insertQuery, hash.UserID, documentID, hash.EntityType, hash.ValueHash, hash.Occurrences, hash.CreatedAt); err != nil {
+				return fmt.Errorf("failed to store entity hash: %w", err)
+			}
+		}
+
+		return nil
+	})
+
+	// Log the operation
+	utils.LogDBQuery(
+		fmt.Sprintf("Replaced entity hashes with %d hashes", len(hashes)),
+		[]interface{}{documentID},
+		time.Since(startTime),
+		err,
+	)
+
+	return err
+}
+
+// CountByEntityType counts the distinct values detected in the documents of a user.
+func (r *PostgresEntityHashRepository) CountByEntityType(ctx context.Context, userID int64) ([]*models.EntityTypeCount, error) {
+	// Start query timer
+	startTime := time.Now()
+
+	// Define the query
+	query := `
+        SELECT entity_type, COUNT(DISTINCT value_hash), COALESCE(SUM(occurrences), 0)
+        FROM ` + constants.TableEntityHashes + `
+        WHERE ` + constants.ColumnUserID + ` = $1
+        GROUP BY entity_type
+        ORDER BY COUNT(DISTINCT value_hash) DESC, entity_type
+    `
+	args := []interface{}{userID}
+
+	// Execute the query
+	rows, err := r.db.QueryContext(ctx, query, args...)
+
+	// Log the query execution
+	utils.LogDBQuery(
+		query,
+		args,
+		time.Since(startTime),
+		err,
+	)
+
+	if err != nil {
+		return nil, fmt.Errorf("failed to count entity values: %w", err)
+	}
+	defer rows.Close()
+
+	var counts []*models.EntityTypeCount
+	for rows.Next() {
+		count := &models.EntityTypeCount{}
+		if err := rows.Scan(&count.EntityType, &count.DistinctValues, &count.Occurrences); err != nil {
+			return nil, fmt.Errorf("failed to scan entity value count: %w", err)
+		}
+		counts = append(counts, count)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating entity value counts: %w", err)
+	}
+
+	return counts, nil
+}
+
+// ListDuplicates retrieves the values detected in several documents of a user.
+func (r *PostgresEntityHashRepository) ListDuplicates(ctx context.Context, userID int64, minDocuments, limit int) ([]*models.DuplicateLeak, error) {
+	// Start query timer
+	startTime := time.Now()
+
+	// Define the query
+	query := `
+        SELECT entity_type, value_hash, COUNT(DISTINCT document_id), SUM(occurrences),
+               ARRAY_AGG(DISTINCT document_id), MIN(created_at), MAX(created_at)
+        FROM ` + constants.TableEntityHashes + `
+        WHERE ` + constants.ColumnUserID + ` = $1
+        GROUP BY entity_type, value_hash
+        HAVING COUNT(DISTINCT document_id) >= $2
+        ORDER BY COUNT(DISTINCT document_id) DESC, SUM(occurrences) DESC, entity_type, value_hash
+        LIMIT $3
+    `
+	args := []interface{}{userID, minDocuments, limit}
+
+	// Execute the query
+	rows, err := r.db.QueryContext(ctx, query, args...)
+
+	// Log the query execution
+	utils.LogDBQuery(
+		query,
+		args,
+		time.Since(startTime),
+		err,
+	)
+
+	if err != nil {
+		return nil, fmt.Errorf("failed to list duplicate entity values: %w", err)
+	}
+	defer rows.Close()
+
+	var duplicates []*models.DuplicateLeak
+	for rows.Next() {
+		duplicate := &models.DuplicateLeak{}
+		if err := rows.Scan(
+			&duplicate.EntityType,
+			&duplicate.ValueHash,
+			&duplicate.DocumentCount,
+			&duplicate.Occurrences,
+			pq.Array(&duplicate.DocumentIDs),
+			&duplicate.FirstSeen,
+			&duplicate.LastSeen,
+		); err != nil {
+			return nil, fmt.Errorf("failed to scan duplicate entity value: %w", err)
+		}
+		duplicates = append(duplicates, duplicate)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating duplicate entity values: %w", err)
+	}
+
+	return duplicates, nil
+}
+
+// ListRepeatedInDocument retrieves the values detected in a document that were detected in
+// more than a number of the user's documents since a time.
+func (r *PostgresEntityHashRepository) ListRepeatedInDocument(ctx context.Context, userID, documentID int64, since time.Time, threshold int) ([]*models.DuplicateLeak, error) {
+	// Start query timer
+	startTime := time.Now()
+
+	// Define the query
+	query := `
+        SELECT h.entity_type, h.value_hash, COUNT(DISTINCT h.document_id), SUM(h.occurrences),
+               ARRAY_AGG(DISTINCT h.document_id), MIN(h.created_at), MAX(h.created_at)
+        FROM ` + constants.TableEntityHashes + ` h
+        JOIN ` + constants.TableEntityHashes + ` d
+            ON d.entity_type = h.entity_type AND d.value_hash = h.value_hash AND d.document_id = $2
+        WHERE h.user_id = $1 AND h.created_at >= $3
+        GROUP BY h.entity_type, h.value_hash
+        HAVING COUNT(DISTINCT h.document_id) > $4
+        ORDER BY COUNT(DISTINCT h.document_id) DESC, h.entity_type, h.value_hash
+    `
+	args := []interface{}{userID, documentID, since, threshold}
+
+	// Execute the query
+	rows, err := r.db.QueryContext(ctx, query, args...)
+
+	// Log the query execution
+	utils.LogDBQuery(
+		query,
+		args,
+		time.Since(startTime),
+		err,
+	)
+
+	if err != nil {
+		return nil, fmt.Errorf("failed to list repeated entity values: %w", err)
+	}
+	defer rows.Close()
+
+	var repeated []*models.DuplicateLeak
+	for rows.Next() {
+		duplicate := &models.DuplicateLeak{}
+		if err := rows.Scan(
+			&duplicate.EntityType,
+			&duplicate.ValueHash,
+			&duplicate.DocumentCount,
+			&duplicate.Occurrences,
+			pq.Array(&duplicate.DocumentIDs),
+			&duplicate.FirstSeen,
+			&duplicate.LastSeen,
+		); err != nil {
+			return nil, fmt.Errorf("failed to scan repeated entity value: %w", err)
+		}
+		repeated = append(repeated, duplicate)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating repeated entity values: %w", err)
+	}
+
+	return repeated, nil
+}
@@ -0,0 +1,121 @@
+package repository_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/yasinhessnawi1/Hideme_Backend/internal/database"
+	"github.com/yasinhessnawi1/Hideme_Backend/internal/models"
+	"github.com/yasinhessnawi1/Hideme_Backend/internal/repository"
+)
+
+func setupEntityHashTest(t *testing.T) (repository.EntityHashRepository, sqlmock.Sqlmock, func()) {
+	db, mock, err := sqlmock.New()
+	require.NoError(t, err)
+
+	return repository.NewEntityHashRepository(&database.Pool{DB: db}), mock, func() { db.Close() }
+}
+
+func TestEntityHashRepository_ReplaceForDocument(t *testing.T) {
+	repo, mock, cleanup := setupEntityHashTest(t)
+	defer cleanup()
+	now := time.Now()
+	hashes := []*models.EntityHash{
+		{UserID: 7, EntityType: "PERSON", ValueHash: "ab12", Occurrences: 2, CreatedAt: now},
+		{UserID: 7, EntityType: "EMAIL_ADDRESS", ValueHash: "cd34", Occurrences: 1, CreatedAt: now},
+	}
+
+	mock.ExpectBegin()
+	mock.ExpectExec("DELETE FROM entity_hashes WHERE document_id = \\$1").
+		WithArgs(int64(12)).
+		WillReturnResult(sqlmock.NewResult(0, 3))
+	mock.ExpectExec("INSERT INTO entity_hashes").
+		WithArgs(int64(7), int64(12), "PERSON", "ab12", 2, now).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectExec("INSERT INTO entity_hashes").
+		WithArgs(int64(7), int64(12), "EMAIL_ADDRESS", "cd34", 1, now).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectCommit()
+
+	require.NoError(t, repo.ReplaceForDocument(context.Background(), 12, hashes))
+
+	// A failed insert keeps the previous hashes
+	mock.ExpectBegin()
+	mock.ExpectExec("DELETE FROM entity_hashes").
+		WillReturnResult(sqlmock.NewResult(0, 2))
+	mock.ExpectExec("INSERT INTO entity_hashes").
+		WillReturnError(errors.New("connection reset"))
+	mock.ExpectRollback()
+
+	assert.Error(t, repo.ReplaceForDocument(context.Background(), 12, hashes))
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestEntityHashRepository_CountByEntityType(t *testing.T) {
+	repo, mock, cleanup := setupEntityHashTest(t)
+	defer cleanup()
+
+	mock.ExpectQuery("SELECT entity_type, COUNT\\(DISTINCT value_hash\\)(.+) FROM entity_hashes WHERE user_id = \\$1 GROUP BY entity_type").
+		WithArgs(int64(7)).
+		WillReturnRows(sqlmock.NewRows([]string{"entity_type", "distinct_values", "occurrences"}).
+			AddRow("PERSON", 14, 40).
+			AddRow("EMAIL_ADDRESS", 3, 5))
+
+	counts, err := repo.CountByEntityType(context.Background(), 7)
+	require.NoError(t, err)
+	require.Len(t, counts, 2)
+	assert.Equal(t, "PERSON", counts[0].EntityType)
+	assert.Equal(t, int64(14), counts[0].DistinctValues)
+	assert.Equal(t, int64(5), counts[1].Occurrences)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestEntityHashRepository_ListDuplicates(t *testing.T) {
+	repo, mock, cleanup := setupEntityHashTest(t)
+	defer cleanup()
+	now := time.Now()
+
+	mock.ExpectQuery("SELECT (.+) FROM entity_hashes WHERE user_id = \\$1 GROUP BY entity_type, value_hash HAVING COUNT\\(DISTINCT document_id\\) >= \\$2 (.+) LIMIT \\$3").
+		WithArgs(int64(7), 2, 100).
+		WillReturnRows(sqlmock.NewRows([]string{"entity_type", "value_hash", "document_count", "occurrences", "document_ids", "first_seen", "last_seen"}).
+			AddRow("NATIONAL_ID", "ab12", 3, 4, "{12,15,19}", now.Add(-time.Hour), now))
+
+	duplicates, err := repo.ListDuplicates(context.Background(), 7, 2, 100)
+	require.NoError(t, err)
+	require.Len(t, duplicates, 1)
+	assert.Equal(t, "ab12", duplicates[0].ValueHash)
+	assert.Equal(t, int64(3), duplicates[0].DocumentCount)
+	assert.Equal(t, []int64{12, 15, 19}, duplicates[0].DocumentIDs)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestEntityHashRepository_ListRepeatedInDocument(t *testing.T) {
+	repo, mock, cleanup := setupEntityHashTest(t)
+	defer cleanup()
+	now := time.Now()
+	since := now.AddDate(0, 0, -30)
+
+	mock.ExpectQuery("SELECT (.+) FROM entity_hashes h JOIN entity_hashes d (.+) d.document_id = \\$2 WHERE h.user_id = \\$1 AND h.created_at >= \\$3 (.+) HAVING COUNT\\(DISTINCT h.document_id\\) > \\$4").
+		WithArgs(int64(7), int64(19), since, 3).
+		WillReturnRows(sqlmock.NewRows([]string{"entity_type", "value_hash", "document_count", "occurrences", "document_ids", "first_seen", "last_seen"}).
+			AddRow("NATIONAL_ID", "ab12", 4, 4, "{3,12,15,19}", since, now))
+
+	repeated, err := repo.ListRepeatedInDocument(context.Background(), 7, 19, since, 3)
+	require.NoError(t, err)
+	require.Len(t, repeated, 1)
+	assert.Equal(t, int64(4), repeated[0].DocumentCount)
+	assert.Equal(t, []int64{3, 12, 15, 19}, repeated[0].DocumentIDs)
+
+	mock.ExpectQuery("SELECT (.+) FROM entity_hashes h").
+		WillReturnError(errors.New("connection reset"))
+
+	_, err = repo.ListRepeatedInDocument(context.Background(), 7, 19, since, 3)
+	assert.Error(t, err)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
@@ -0,0 +1,278 @@
+// Package repository provides data access interfaces and implementations for the HideMe application.
+// It follows the repository pattern to abstract database operations and provide a clean API
+// for data persistence operations.
+//
+// This file implements the export delivery repository, which tracks the delivery of each
+// document to each of its owner's export destinations.
+package repository
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/yasinhessnawi1/Hideme_Backend/internal/constants"
+	"github.com/yasinhessnawi1/Hideme_Backend/internal/database"
+	"github.com/yasinhessnawi1/Hideme_Backend/internal/models"
+	"github.com/yasinhessnawi1/Hideme_Backend/internal/utils"
+)
+
+// ExportDeliveryRepository defines methods for tracking deliveries of documents to export destinations.
+type ExportDeliveryRepository interface {
+	// Start records an attempt to deliver a document to a destination. The delivery is
+	// created on the first attempt; later attempts set it back to pending and count it.
+	//
+	// Parameters:
+	//   - ctx: Context for transaction and cancellation control
+	//   - destinationID: The ID of the destination
+	//   - documentID: The ID of the document
+	//   - objectName: The name the document is written under at the destination
+	//
+	// Returns:
+	//   - The pending delivery
+	//   - An error for database issues
+	Start(ctx context.Context, destinationID, documentID int64, objectName string) (*models.ExportDelivery, error)
+
+	// Finish records the outcome of a delivery attempt.
+	//
+	// Parameters:
+	//   - ctx: Context for transaction and cancellation control
+	//   - id: The ID of the delivery
+	//   - status: delivered or failed
+	//   - lastError: Why the attempt failed; empty once delivered
+	//
+	// Returns:
+	//   - NotFoundError if the delivery no longer exists
+	//   - Other errors for database issues
+	Finish(ctx context.Context, id int64, status, lastError string) error
+
+	// ListByDocument retrieves the deliveries of a user's document.
+	//
+	// Parameters:
+	//   - ctx: Context for transaction and cancellation control
+	//   - userID: The ID of the user who owns the destinations
+	//   - documentID: The ID of the document
+	//
+	// Returns:
+	//   - The deliveries with their destination names (empty if there are none)
+	//   - An error for database issues
+	ListByDocument(ctx context.Context, userID, documentID int64) ([]*models.ExportDelivery, error)
+
+	// ListRetryable retrieves the deliveries to attempt again: failed ones, and pending ones
+	// whose attempt stalled, that have been attempted fewer than maxAttempts times.
+	//
+	// Parameters:
+	//   - ctx: Context for transaction and cancellation control
+	//   - maxAttempts: The number of attempts after which a delivery is given up
+	//   - stalledBefore: Pending deliveries last attempted before this time are considered stalled
+	//   - limit: The maximum number of deliveries to return
+	//
+	// Returns:
+	//   - The deliveries, least recently attempted first, with the users owning their destinations
+	//   - An error for database issues
+	ListRetryable(ctx context.Context, maxAttempts int, stalledBefore time.Time, limit int) ([]*models.ExportDelivery, error)
+}
+
+// PostgresExportDeliveryRepository is a PostgreSQL implementation of ExportDeliveryRepository.
+type PostgresExportDeliveryRepository struct {
+	db *database.Pool
+}
+
+// NewExportDeliveryRepository creates a new ExportDeliveryRepository implementation for PostgreSQL.
+//
+// Parameters:
+//   - db: A connection pool for PostgreSQL database access
+//
+// Returns:
+//   - An implementation of the ExportDeliveryRepository interface
+func NewExportDeliveryRepository(db *database.Pool) ExportDeliveryRepository {
+	return &PostgresExportDeliveryRepository{
+		db: db,
+	}
+}
+
+// exportDeliveryColumns are the columns selected for deliveries joined with their destination.
+const exportDeliveryColumns = `d.delivery_id, d.destination_id, t.name, t.` + constants.ColumnUserID + `, d.document_id, d.status,
+               d.attempts, d.object_name, d.last_error, d.created_at, d.updated_at, d.delivered_at`
+
+// Start records an attempt to deliver a document to a destination.
+func (r *PostgresExportDeliveryRepository) Start(ctx context.Context, destinationID, documentID int64, objectName string) (*models.ExportDelivery, error) {
+	// Start query timer
+	startTime := time.Now()
+
+	// Define the query
+	query := `
+        INSERT INTO ` + constants.TableExportDeliveries + ` (destination_id, document_id, status, attempts, object_name, created_at, updated_at)
+        VALUES ($1, $2, $3, 1, $4, $5, $5)
+        ON CONFLICT (destination_id, document_id) DO UPDATE
+        SET status = EXCLUDED.status,
+            attempts = ` + constants.TableExportDeliveries + `.attempts + 1,
+            object_name = EXCLUDED.object_name,
+            updated_at = EXCLUDED.updated_at
+        RETURNING delivery_id, attempts, created_at
+    `
+	now := time.Now()
+	delivery := &models.ExportDelivery{
+		DestinationID: destinationID,
+		DocumentID:    documentID,
+		Status:        constants.ExportDeliveryPending,
+		ObjectName:    objectName,
+		UpdatedAt:     now,
+	}
+
+	// Execute the query
+	err := r.db.QueryRowContext(ctx, query, destinationID, documentID, constants.ExportDeliveryPending, objectName, now).
+		Scan(&delivery.ID, &delivery.Attempts, &delivery.CreatedAt)
+
+	// Log the query execution
+	utils.LogDBQuery(
+		query,
+		[]interface{}{destinationID, documentID, constants.ExportDeliveryPending, objectName, now},
+		time.Since(startTime),
+		err,
+	)
+
+	if err != nil {
+		return nil, fmt.Errorf("failed to start export delivery: %w", err)
+	}
+
+	return delivery, nil
+}
+
+// Finish records the outcome of a delivery attempt.
+func (r *PostgresExportDeliveryRepository) Finish(ctx context.Context, id int64, status, lastError string) error {
+	// Start query timer
+	startTime := time.Now()
+
+	// Define the query
+	query := `
+        UPDATE ` + constants.TableExportDeliveries + `
+        SET status = $1,
+            last_error = $2,
+            updated_at = $3,
+            delivered_at = CASE WHEN $1 = '` + constants.ExportDeliveryDelivered + `' THEN $3 ELSE delivered_at END
+        WHERE delivery_id = $4
+    `
+	now := time.Now()
+
+	// Execute the query
+	result, err := r.db.ExecContext(ctx, query, status, lastError, now, id)
+
+	// Log the query execution
+	utils.LogDBQuery(
+		query,
+		[]interface{}{status, lastError, now, id},
+		time.Since(startTime),
+		err,
+	)
+
+	if err != nil {
+		return fmt.Errorf("failed to finish export delivery: %w", err)
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("failed to get affected rows: %w", err)
+	}
+
+	if rowsAffected == 0 {
+		return utils.NewNotFoundError("ExportDelivery", id)
+	}
+
+	return nil
+}
+
+// ListByDocument retrieves the deliveries of a user's document.
+func (r *PostgresExportDeliveryRepository) ListByDocument(ctx context.Context, userID, documentID int64) ([]*models.ExportDelivery, error) {
+	// Start query timer
+	startTime := time.Now()
+
+	// Define the query
+	query := `
+        SELECT ` + exportDeliveryColumns + `
+        FROM ` + constants.TableExportDeliveries + ` d
+        JOIN ` + constants.TableExportDestinations + ` t ON t.destination_id = d.destination_id
+        WHERE d.document_id = $1 AND t.` + constants.ColumnUserID + ` = $2
+        ORDER BY t.name
+    `
+
+	// Execute the query
+	deliveries, err := r.query(ctx, query, documentID, userID)
+
+	// Log the query execution
+	utils.LogDBQuery(
+		query,
+		[]interface{}{documentID, userID},
+		time.Since(startTime),
+		err,
+	)
+
+	if err != nil {
+		return nil, fmt.Errorf("failed to list export deliveries: %w", err)
+	}
+
+	return deliveries, nil
+}
+
+// ListRetryable retrieves the deliveries to attempt again.
+func (r *PostgresExportDeliveryRepository) ListRetryable(ctx context.Context, maxAttempts int, stalledBefore time.Time, limit int) ([]*models.ExportDelivery, error) {
+	// Start query timer
+	startTime := time.Now()
+
+	// Define the query
+	query := `
+        SELECT ` + exportDeliveryColumns + `
+        FROM ` + constants.TableExportDeliveries + ` d
+        JOIN ` + constants.TableExportDestinations + ` t ON t.destination_id = d.destination_id
+        WHERE d.attempts < $1
+          AND (d.status = $2 OR (d.status = $3 AND d.updated_at < $4))
+        ORDER BY d.updated_at
+        LIMIT $5
+    `
+	args := []interface{}{maxAttempts, constants.ExportDeliveryFailed, constants.ExportDeliveryPending, stalledBefore, limit}
+
+	// Execute the query
+	deliveries, err := r.query(ctx, query, args...)
+
+	// Log the query execution
+	utils.LogDBQuery(
+		query,
+		args,
+		time.Since(startTime),
+		err,
+	)
+
+	if err != nil {
+		return nil, fmt.Errorf("failed to list retryable export deliveries: %w", err)
+	}
+
+	return deliveries, nil
+}
+
+// query runs a query selecting exportDeliveryColumns and scans the deliveries.
+func (r *PostgresExportDeliveryRepository) query(ctx context.Context, query string, args ...interface{}) ([]*models.ExportDelivery, error) {
+	rows, err := r.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	deliveries := []*models.ExportDelivery{}
+	for rows.Next() {
+		delivery := &models.ExportDelivery{}
+		if err := rows.Scan(
+			&delivery.ID, &delivery.DestinationID, &delivery.DestinationName, &delivery.UserID,
+			&delivery.DocumentID, &delivery.Status, &delivery.Attempts, &delivery.ObjectName,
+			&delivery.LastError, &delivery.CreatedAt, &delivery.UpdatedAt, &delivery.DeliveredAt,
+		); err != nil {
+			return nil, fmt.Errorf("failed to scan export delivery row: %w", err)
+		}
+		deliveries = append(deliveries, delivery)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating export delivery rows: %w", err)
+	}
+
+	return deliveries, nil
+}
@@ -0,0 +1,320 @@
+// Package repository provides data access interfaces and implementations for the HideMe application.
+// It follows the repository pattern to abstract database operations and provide a clean API
+// for data persistence operations.
+//
+// This file implements the export destination repository. The connection settings of a
+// destination include credentials, so they are stored as JSON encrypted with the tenant's
+// data-encryption key.
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/lib/pq"
+
+	"github.com/yasinhessnawi1/Hideme_Backend/internal/constants"
+	"github.com/yasinhessnawi1/Hideme_Backend/internal/database"
+	"github.com/yasinhessnawi1/Hideme_Backend/internal/models"
+	"github.com/yasinhessnawi1/Hideme_Backend/internal/utils"
+)
+
+// ExportDestinationRepository defines methods for storing the destinations redacted documents are delivered to.
+type ExportDestinationRepository interface {
+	// Create stores a new export destination.
+	//
+	// Parameters:
+	//   - ctx: Context for transaction and cancellation control
+	//   - destination: The destination to store; its ID and timestamps are set on success
+	//
+	// Returns:
+	//   - DuplicateError if the user already has a destination with the name
+	//   - Other errors for database or encryption issues
+	Create(ctx context.Context, destination *models.ExportDestination) error
+
+	// GetByID retrieves an export destination of a user, with its settings decrypted.
+	//
+	// Parameters:
+	//   - ctx: Context for transaction and cancellation control
+	//   - id: The ID of the destination
+	//   - userID: The ID of the user who must own the destination
+	//
+	// Returns:
+	//   - The destination
+	//   - NotFoundError if the user has no such destination
+	//   - Other errors for database or decryption issues
+	GetByID(ctx context.Context, id, userID int64) (*models.ExportDestination, error)
+
+	// ListByUser retrieves the export destinations of a user, with their settings decrypted.
+	//
+	// Parameters:
+	//   - ctx: Context for transaction and cancellation control
+	//   - userID: The ID of the user
+	//
+	// Returns:
+	//   - The destinations (empty if there are none)
+	//   - An error for database or decryption issues
+	ListByUser(ctx context.Context, userID int64) ([]*models.ExportDestination, error)
+
+	// Delete removes an export destination of a user, with its delivery history.
+	//
+	// Parameters:
+	//   - ctx: Context for transaction and cancellation control
+	//   - id: The ID of the destination
+	//   - userID: The ID of the user who must own the destination
+	//
+	// Returns:
+	//   - NotFoundError if the user has no such destination
+	//   - Other errors for database issues
+	Delete(ctx context.Context, id, userID int64) error
+}
+
+// PostgresExportDestinationRepository is a PostgreSQL implementation of ExportDestinationRepository.
+type PostgresExportDestinationRepository struct {
+	db         *database.Pool
+	tenantKeys TenantKeyRepository
+}
+
+// NewExportDestinationRepository creates a new ExportDestinationRepository implementation for PostgreSQL.
+//
+// Parameters:
+//   - db: A connection pool for PostgreSQL database access
+//   - tenantKeys: Repository for the data-encryption keys the settings are encrypted with
+//
+// Returns:
+//   - An implementation of the ExportDestinationRepository interface
+func NewExportDestinationRepository(db *database.Pool, tenantKeys TenantKeyRepository) ExportDestinationRepository {
+	return &PostgresExportDestinationRepository{
+		db:         db,
+		tenantKeys: tenantKeys,
+	}
+}
+
+// exportDestinationColumns are the columns selected for export destinations.
+const exportDestinationColumns = `destination_id, ` + constants.ColumnUserID + `, name, destination_type, config, auto_deliver, created_at, updated_at`
+
+// Create stores a new export destination.
+func (r *PostgresExportDestinationRepository) Create(ctx context.Context, destination *models.ExportDestination) error {
+	config, err := r.encryptConfig(ctx, destination.UserID, &destination.Config)
+	if err != nil {
+		return err
+	}
+
+	// Start query timer
+	startTime := time.Now()
+
+	// Define the query
+	query := `
+        INSERT INTO ` + constants.TableExportDestinations + ` (` + constants.ColumnUserID + `, name, destination_type, config, auto_deliver, created_at, updated_at)
+        VALUES ($1, $2, $3, $4, $5, $6, $6)
+        RETURNING destination_id
+    `
+	now := time.Now()
+
+	// Execute the query
+	err = r.db.QueryRowContext(ctx, query, destination.UserID, destination.Name, destination.Type, config, destination.AutoDeliver, now).
+		Scan(&destination.ID)
+
+	// Log the query execution; the settings are left out
+	utils.LogDBQuery(
+		query,
+		[]interface{}{destination.UserID, destination.Name, destination.Type, destination.AutoDeliver},
+		time.Since(startTime),
+		err,
+	)
+
+	if err != nil {
+		var pqErr *pq.Error
+		if errors.As(err, &pqErr) && pqErr.Code == constants.PGErrorDuplicateConstraint {
+			return utils.NewDuplicateError("ExportDestination", "name", destination.Name).WithCause(err)
+		}
+		return fmt.Errorf("failed to create export destination: %w", err)
+	}
+	destination.CreatedAt = now
+	destination.UpdatedAt = now
+
+	return nil
+}
+
+// GetByID retrieves an export destination of a user, with its settings decrypted.
+func (r *PostgresExportDestinationRepository) GetByID(ctx context.Context, id, userID int64) (*models.ExportDestination, error) {
+	// Start query timer
+	startTime := time.Now()
+
+	// Define the query
+	query := `
+        SELECT ` + exportDestinationColumns + `
+        FROM ` + constants.TableExportDestinations + `
+        WHERE destination_id = $1 AND ` + constants.ColumnUserID + ` = $2
+    `
+
+	// Execute the query
+	row := r.db.QueryRowContext(ctx, query, id, userID)
+	destination, err := r.scan(ctx, row)
+
+	// Log the query execution
+	utils.LogDBQuery(
+		query,
+		[]interface{}{id, userID},
+		time.Since(startTime),
+		err,
+	)
+
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, utils.NewNotFoundError("ExportDestination", id).WithCause(err)
+		}
+		return nil, fmt.Errorf("failed to get export destination: %w", err)
+	}
+
+	return destination, nil
+}
+
+// ListByUser retrieves the export destinations of a user, with their settings decrypted.
+func (r *PostgresExportDestinationRepository) ListByUser(ctx context.Context, userID int64) ([]*models.ExportDestination, error) {
+	// Start query timer
+	startTime := time.Now()
+
+	// Define the query
+	query := `
+        SELECT ` + exportDestinationColumns + `
+        FROM ` + constants.TableExportDestinations + `
+        WHERE ` + constants.ColumnUserID + ` = $1
+        ORDER BY name
+    `
+
+	// Execute the query
+	rows, err := r.db.QueryContext(ctx, query, userID)
+
+	// Log the query execution
+	utils.LogDBQuery(
+		query,
+		[]interface{}{userID},
+		time.Since(startTime),
+		err,
+	)
+
+	if err != nil {
+		return nil, fmt.Errorf("failed to list export destinations: %w", err)
+	}
+	defer rows.Close()
+
+	destinations := []*models.ExportDestination{}
+	for rows.Next() {
+		destination, err := r.scan(ctx, rows)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan export destination row: %w", err)
+		}
+		destinations = append(destinations, destination)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating export destination rows: %w", err)
+	}
+
+	return destinations, nil
+}
+
+// Delete removes an export destination of a user, with its delivery history.
+func (r *PostgresExportDestinationRepository) Delete(ctx context.Context, id, userID int64) error {
+	// Start query timer
+	startTime := time.Now()
+
+	// Define the query
+	query := `
+        DELETE FROM ` + constants.TableExportDestinations + `
+        WHERE destination_id = $1 AND ` + constants.ColumnUserID + ` = $2
+    `
+
+	// Execute the query
+	result, err := r.db.ExecContext(ctx, query, id, userID)
+
+	// Log the query execution
+	utils.LogDBQuery(
+		query,
+		[]interface{}{id, userID},
+		time.Since(startTime),
+		err,
+	)
+
+	if err != nil {
+		return fmt.Errorf("failed to delete export destination: %w", err)
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("failed to get affected rows: %w", err)
+	}
+
+	if rowsAffected == 0 {
+		return utils.NewNotFoundError("ExportDestination", id)
+	}
+
+	return nil
+}
+
+// rowScanner is implemented by both sql.Row and sql.Rows.
+type rowScanner interface {
+	Scan(dest ...interface{}) error
+}
+
+// scan reads an export destination and decrypts its settings.
+func (r *PostgresExportDestinationRepository) scan(ctx context.Context, row rowScanner) (*models.ExportDestination, error) {
+	destination := &models.ExportDestination{}
+	var config string
+	if err := row.Scan(
+		&destination.ID, &destination.UserID, &destination.Name, &destination.Type,
+		&config, &destination.AutoDeliver, &destination.CreatedAt, &destination.UpdatedAt,
+	); err != nil {
+		return nil, err
+	}
+
+	if err := r.decryptConfig(ctx, destination.UserID, config, &destination.Config); err != nil {
+		return nil, err
+	}
+
+	return destination, nil
+}
+
+// encryptConfig encrypts the settings of a destination with the data-encryption key of a tenant.
+func (r *PostgresExportDestinationRepository) encryptConfig(ctx context.Context, userID int64, config *models.ExportDestinationConfig) (string, error) {
+	plaintext, err := json.Marshal(config)
+	if err != nil {
+		return "", fmt.Errorf("failed to marshal export destination config: %w", err)
+	}
+
+	dataKey, err := r.tenantKeys.GetOrCreateDataKey(ctx, userID)
+	if err != nil {
+		return "", fmt.Errorf("failed to get tenant data key: %w", err)
+	}
+
+	ciphertext, err := utils.EncryptKey(string(plaintext), dataKey)
+	if err != nil {
+		return "", fmt.Errorf("failed to encrypt export destination config: %w", err)
+	}
+
+	return constants.TenantCiphertextPrefix + ciphertext, nil
+}
+
+// decryptConfig decrypts the settings of a destination encrypted with the data-encryption key of a tenant.
+func (r *PostgresExportDestinationRepository) decryptConfig(ctx context.Context, userID int64, ciphertext string, config *models.ExportDestinationConfig) error {
+	dataKey, err := r.tenantKeys.GetDataKey(ctx, userID)
+	if err != nil {
+		return fmt.Errorf("failed to get tenant data key: %w", err)
+	}
+
+	plaintext, err := utils.DecryptKey(strings.TrimPrefix(ciphertext, constants.TenantCiphertextPrefix), dataKey)
+	if err != nil {
+		return fmt.Errorf("failed to decrypt export destination config: %w", err)
+	}
+
+	if err := json.Unmarshal([]byte(plaintext), config); err != nil {
+		return fmt.Errorf("failed to unmarshal export destination config: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,196 @@
+package repository_test
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/lib/pq"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/yasinhessnawi1/Hideme_Backend/internal/constants"
+	"github.com/yasinhessnawi1/Hideme_Backend/internal/database"
+	"github.com/yasinhessnawi1/Hideme_Backend/internal/models"
+	"github.com/yasinhessnawi1/Hideme_Backend/internal/repository"
+	"github.com/yasinhessnawi1/Hideme_Backend/internal/utils"
+)
+
+var exportDestinationTestColumns = []string{
+	"destination_id", "user_id", "name", "destination_type", "config", "auto_deliver", "created_at", "updated_at",
+}
+
+func setupExportDestinationTest(t *testing.T) (repository.ExportDestinationRepository, sqlmock.Sqlmock, func()) {
+	db, mock, err := sqlmock.New()
+	require.NoError(t, err)
+
+	tenantKeys := &stubTenantKeyRepository{keys: make(map[int64][]byte)}
+	repo := repository.NewExportDestinationRepository(&database.Pool{DB: db}, tenantKeys)
+
+	return repo, mock, func() { db.Close() }
+}
+
+func TestExportDestinationRepository_CreateAndGet(t *testing.T) {
+	// Arrange
+	repo, mock, cleanup := setupExportDestinationTest(t)
+	defer cleanup()
+	now := time.Now()
+	destination := &models.ExportDestination{
+		UserID:      7,
+		Name:        "Archive",
+		Type:        constants.ExportDestinationS3,
+		AutoDeliver: true,
+		Config: models.ExportDestinationConfig{
+			Bucket:          "archive",
+			Region:          "eu-north-1",
+			AccessKeyID:     "AKIA",
+			SecretAccessKey: "s3cr3t",
+		},
+	}
+
+	config := &capturedArg{}
+	mock.ExpectQuery("INSERT INTO export_destinations (.+) RETURNING destination_id").
+		WithArgs(int64(7), "Archive", constants.ExportDestinationS3, config, true, sqlmock.AnyArg()).
+		WillReturnRows(sqlmock.NewRows([]string{"destination_id"}).AddRow(3))
+
+	// Act
+	err := repo.Create(context.Background(), destination)
+
+	// Assert
+	require.NoError(t, err)
+	assert.Equal(t, int64(3), destination.ID)
+	assert.True(t, strings.HasPrefix(config.value.(string), constants.TenantCiphertextPrefix))
+	assert.NotContains(t, config.value.(string), "s3cr3t")
+
+	// GetByID decrypts the settings with the tenant key
+	mock.ExpectQuery("SELECT (.+) FROM export_destinations WHERE destination_id = \\$1 AND user_id = \\$2").
+		WithArgs(int64(3), int64(7)).
+		WillReturnRows(sqlmock.NewRows(exportDestinationTestColumns).
+			AddRow(3, 7, "Archive", constants.ExportDestinationS3, config.value, true, now, now))
+
+	result, err := repo.GetByID(context.Background(), 3, 7)
+	require.NoError(t, err)
+	assert.Equal(t, "s3cr3t", result.Config.SecretAccessKey)
+	assert.Equal(t, "archive", result.Config.Bucket)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestExportDestinationRepository_Create_DuplicateName(t *testing.T) {
+	// Arrange
+	repo, mock, cleanup := setupExportDestinationTest(t)
+	defer cleanup()
+
+	mock.ExpectQuery("INSERT INTO export_destinations").
+		WillReturnError(&pq.Error{Code: constants.PGErrorDuplicateConstraint})
+
+	// Act
+	err := repo.Create(context.Background(), &models.ExportDestination{UserID: 7, Name: "Archive", Type: constants.ExportDestinationSFTP})
+
+	// Assert
+	assert.True(t, utils.IsDuplicateError(err))
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestExportDestinationRepository_GetByID_NotFound(t *testing.T) {
+	// Arrange
+	repo, mock, cleanup := setupExportDestinationTest(t)
+	defer cleanup()
+
+	mock.ExpectQuery("SELECT (.+) FROM export_destinations").
+		WithArgs(int64(3), int64(8)).
+		WillReturnRows(sqlmock.NewRows(exportDestinationTestColumns))
+
+	// Act
+	_, err := repo.GetByID(context.Background(), 3, 8)
+
+	// Assert
+	assert.True(t, utils.IsNotFoundError(err))
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestExportDestinationRepository_Delete(t *testing.T) {
+	repo, mock, cleanup := setupExportDestinationTest(t)
+	defer cleanup()
+
+	t.Run("Success", func(t *testing.T) {
+		mock.ExpectExec("DELETE FROM export_destinations WHERE destination_id = \\$1 AND user_id = \\$2").
+			WithArgs(int64(3), int64(7)).
+			WillReturnResult(sqlmock.NewResult(0, 1))
+
+		assert.NoError(t, repo.Delete(context.Background(), 3, 7))
+	})
+
+	t.Run("Other user", func(t *testing.T) {
+		mock.ExpectExec("DELETE FROM export_destinations").
+			WithArgs(int64(3), int64(8)).
+			WillReturnResult(sqlmock.NewResult(0, 0))
+
+		assert.True(t, utils.IsNotFoundError(repo.Delete(context.Background(), 3, 8)))
+	})
+
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestExportDeliveryRepository_StartAndFinish(t *testing.T) {
+	// Arrange
+	db, mock, err := sqlmock.New()
+	require.NoError(t, err)
+	defer db.Close()
+	repo := repository.NewExportDeliveryRepository(&database.Pool{DB: db})
+	now := time.Now()
+
+	mock.ExpectQuery("INSERT INTO export_deliveries (.+) ON CONFLICT \\(destination_id, document_id\\) DO UPDATE").
+		WithArgs(int64(3), int64(11), constants.ExportDeliveryPending, "hideme-document-11.json", sqlmock.AnyArg()).
+		WillReturnRows(sqlmock.NewRows([]string{"delivery_id", "attempts", "created_at"}).AddRow(5, 2, now))
+
+	// Act
+	delivery, err := repo.Start(context.Background(), 3, 11, "hideme-document-11.json")
+
+	// Assert
+	require.NoError(t, err)
+	assert.Equal(t, int64(5), delivery.ID)
+	assert.Equal(t, 2, delivery.Attempts)
+	assert.Equal(t, constants.ExportDeliveryPending, delivery.Status)
+
+	mock.ExpectExec("UPDATE export_deliveries SET status = \\$1").
+		WithArgs(constants.ExportDeliveryDelivered, "", sqlmock.AnyArg(), int64(5)).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	require.NoError(t, repo.Finish(context.Background(), 5, constants.ExportDeliveryDelivered, ""))
+
+	mock.ExpectExec("UPDATE export_deliveries").
+		WithArgs(constants.ExportDeliveryFailed, "timeout", sqlmock.AnyArg(), int64(6)).
+		WillReturnResult(sqlmock.NewResult(0, 0))
+	assert.True(t, utils.IsNotFoundError(repo.Finish(context.Background(), 6, constants.ExportDeliveryFailed, "timeout")))
+
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestExportDeliveryRepository_ListRetryable(t *testing.T) {
+	// Arrange
+	db, mock, err := sqlmock.New()
+	require.NoError(t, err)
+	defer db.Close()
+	repo := repository.NewExportDeliveryRepository(&database.Pool{DB: db})
+	now := time.Now()
+	stalledBefore := now.Add(-time.Hour)
+
+	mock.ExpectQuery("SELECT (.+) FROM export_deliveries d JOIN export_destinations t (.+) WHERE d.attempts < \\$1").
+		WithArgs(3, constants.ExportDeliveryFailed, constants.ExportDeliveryPending, stalledBefore, 100).
+		WillReturnRows(sqlmock.NewRows([]string{
+			"delivery_id", "destination_id", "name", "user_id", "document_id", "status",
+			"attempts", "object_name", "last_error", "created_at", "updated_at", "delivered_at",
+		}).AddRow(5, 3, "Archive", 7, 11, constants.ExportDeliveryFailed, 1, "hideme-document-11.json", "timeout", now, now, nil))
+
+	// Act
+	deliveries, err := repo.ListRetryable(context.Background(), 3, stalledBefore, 100)
+
+	// Assert
+	require.NoError(t, err)
+	require.Len(t, deliveries, 1)
+	assert.Equal(t, int64(7), deliveries[0].UserID)
+	assert.Equal(t, "Archive", deliveries[0].DestinationName)
+	assert.Nil(t, deliveries[0].DeliveredAt)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
@@ -0,0 +1,310 @@
+// Package repository provides data access interfaces and implementations for the HideMe application.
+// It follows the repository pattern to abstract database operations and provide a clean API
+// for data persistence operations.
+//
+// This file implements the index advisor repository, which reads the statistics PostgreSQL
+// keeps about table access and statement execution.
+package repository
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/lib/pq"
+
+	"github.com/yasinhessnawi1/Hideme_Backend/internal/database"
+	"github.com/yasinhessnawi1/Hideme_Backend/internal/models"
+	"github.com/yasinhessnawi1/Hideme_Backend/internal/utils"
+)
+
+// IndexAdvisorRepository defines methods for reading the database statistics the index advisor inspects.
+type IndexAdvisorRepository interface {
+	// GetTableStats retrieves the access statistics of tables.
+	//
+	// Parameters:
+	//   - ctx: Context for transaction and cancellation control
+	//   - tables: The names of the tables to inspect
+	//
+	// Returns:
+	//   - The statistics ordered by table name; tables without statistics are omitted
+	//   - An error for database issues
+	GetTableStats(ctx context.Context, tables []string) ([]models.TableScanStats, error)
+
+	// GetUnindexedForeignKeys retrieves the foreign keys of tables that no index starts with.
+	//
+	// Parameters:
+	//   - ctx: Context for transaction and cancellation control
+	//   - tables: The names of the tables to inspect
+	//
+	// Returns:
+	//   - The unindexed foreign keys ordered by table and constraint name
+	//   - An error for database issues
+	GetUnindexedForeignKeys(ctx context.Context, tables []string) ([]models.UnindexedForeignKey, error)
+
+	// HasStatStatements checks whether the pg_stat_statements extension is installed.
+	//
+	// Parameters:
+	//   - ctx: Context for transaction and cancellation control
+	//
+	// Returns:
+	//   - true if statement statistics can be read
+	//   - An error for database issues
+	HasStatStatements(ctx context.Context) (bool, error)
+
+	// GetTopStatements retrieves the most expensive statements referencing any of the tables.
+	// It requires the pg_stat_statements extension.
+	//
+	// Parameters:
+	//   - ctx: Context for transaction and cancellation control
+	//   - tables: The names of the tables the statements must reference
+	//   - limit: The maximum number of statements to return
+	//
+	// Returns:
+	//   - The statements ordered by total execution time, the most expensive first
+	//   - An error for database issues
+	GetTopStatements(ctx context.Context, tables []string, limit int) ([]models.StatementStats, error)
+}
+
+// PostgresIndexAdvisorRepository is a PostgreSQL implementation of IndexAdvisorRepository.
+type PostgresIndexAdvisorRepository struct {
+	db *database.Pool
+}
+
+// NewIndexAdvisorRepository creates a new IndexAdvisorRepository implementation for PostgreSQL.
+//
+// Parameters:
+//   - db: A connection pool for PostgreSQL database access
+//
+// Returns:
+//   - An implementation of the IndexAdvisorRepository interface
+func NewIndexAdvisorRepository(db *database.Pool) IndexAdvisorRepository {
+	return &PostgresIndexAdvisorRepository{
+		db: db,
+	}
+}
+
+// GetTableStats retrieves the access statistics of tables from pg_stat_user_tables.
+//
+// Parameters:
+//   - ctx: Context for transaction and cancellation control
+//   - tables: The names of the tables to inspect
+//
+// Returns:
+//   - The statistics ordered by table name
+//   - An error for database issues
+func (r *PostgresIndexAdvisorRepository) GetTableStats(ctx context.Context, tables []string) ([]models.TableScanStats, error) {
+	// Start query timer
+	startTime := time.Now()
+
+	// Define the query
+	query := `
+        SELECT relname, n_live_tup, COALESCE(seq_scan, 0), COALESCE(seq_tup_read, 0), COALESCE(idx_scan, 0)
+        FROM pg_stat_user_tables
+        WHERE relname = ANY($1)
+        ORDER BY relname ASC
+    `
+
+	// Execute the query
+	rows, err := r.db.QueryContext(ctx, query, pq.Array(tables))
+
+	// Log the query execution
+	utils.LogDBQuery(
+		query,
+		[]interface{}{tables},
+		time.Since(startTime),
+		err,
+	)
+
+	if err != nil {
+		return nil, fmt.Errorf("failed to get table statistics: %w", err)
+	}
+	defer rows.Close()
+
+	stats := []models.TableScanStats{}
+	for rows.Next() {
+		var table models.TableScanStats
+		if err := rows.Scan(
+			&table.Table,
+			&table.LiveRows,
+			&table.SeqScans,
+			&table.SeqRowsRead,
+			&table.IndexScans,
+		); err != nil {
+			return nil, fmt.Errorf("failed to scan table statistics row: %w", err)
+		}
+		stats = append(stats, table)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating table statistics rows: %w", err)
+	}
+
+	return stats, nil
+}
+
+// GetUnindexedForeignKeys retrieves the foreign keys of tables whose columns are not
+// the leading columns of any index on the same table.
+//
+// Parameters:
+//   - ctx: Context for transaction and cancellation control
+//   - tables: The names of the tables to inspect
+//
+// Returns:
+//   - The unindexed foreign keys ordered by table and constraint name
+//   - An error for database issues
+func (r *PostgresIndexAdvisorRepository) GetUnindexedForeignKeys(ctx context.Context, tables []string) ([]models.UnindexedForeignKey, error) {
+	// Start query timer
+	startTime := time.Now()
+
+	// Define the query; indkey is converted through text so its subscripts start at 1 like conkey
+	query := `
+        SELECT rel.relname, con.conname, array_agg(att.attname ORDER BY key.ord)
+        FROM pg_constraint con
+        JOIN pg_class rel ON rel.oid = con.conrelid
+        CROSS JOIN LATERAL unnest(con.conkey) WITH ORDINALITY AS key(attnum, ord)
+        JOIN pg_attribute att ON att.attrelid = con.conrelid AND att.attnum = key.attnum
+        WHERE con.contype = 'f'
+          AND rel.relname = ANY($1)
+          AND NOT EXISTS (
+              SELECT 1
+              FROM pg_index idx
+              WHERE idx.indrelid = con.conrelid
+                AND (string_to_array(idx.indkey::text, ' ')::int2[])[1:array_length(con.conkey, 1)] @> con.conkey
+          )
+        GROUP BY rel.relname, con.conname
+        ORDER BY rel.relname ASC, con.conname ASC
+    `
+
+	// Execute the query
+	rows, err := r.db.QueryContext(ctx, query, pq.Array(tables))
+
+	// Log the query execution
+	utils.LogDBQuery(
+		query,
+		[]interface{}{tables},
+		time.Since(startTime),
+		err,
+	)
+
+	if err != nil {
+		return nil, fmt.Errorf("failed to get unindexed foreign keys: %w", err)
+	}
+	defer rows.Close()
+
+	keys := []models.UnindexedForeignKey{}
+	for rows.Next() {
+		var key models.UnindexedForeignKey
+		if err := rows.Scan(&key.Table, &key.Constraint, pq.Array(&key.Columns)); err != nil {
+			return nil, fmt.Errorf("failed to scan foreign key row: %w", err)
+		}
+		keys = append(keys, key)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating foreign key rows: %w", err)
+	}
+
+	return keys, nil
+}
+
+// HasStatStatements checks whether the pg_stat_statements extension is installed.
+//
+// Parameters:
+//   - ctx: Context for transaction and cancellation control
+//
+// Returns:
+//   - true if statement statistics can be read
+//   - An error for database issues
+func (r *PostgresIndexAdvisorRepository) HasStatStatements(ctx context.Context) (bool, error) {
+	// Start query timer
+	startTime := time.Now()
+
+	// Define the query
+	query := `SELECT EXISTS (SELECT 1 FROM pg_extension WHERE extname = 'pg_stat_statements')`
+
+	// Execute the query
+	var available bool
+	err := r.db.QueryRowContext(ctx, query).Scan(&available)
+
+	// Log the query execution
+	utils.LogDBQuery(
+		query,
+		nil,
+		time.Since(startTime),
+		err,
+	)
+
+	if err != nil {
+		return false, fmt.Errorf("failed to check for pg_stat_statements: %w", err)
+	}
+
+	return available, nil
+}
+
+// GetTopStatements retrieves the most expensive statements of the current database
+// that reference any of the tables.
+//
+// Parameters:
+//   - ctx: Context for transaction and cancellation control
+//   - tables: The names of the tables the statements must reference
+//   - limit: The maximum number of statements to return
+//
+// Returns:
+//   - The statements ordered by total execution time, the most expensive first
+//   - An error for database issues
+func (r *PostgresIndexAdvisorRepository) GetTopStatements(ctx context.Context, tables []string, limit int) ([]models.StatementStats, error) {
+	// Start query timer
+	startTime := time.Now()
+
+	// Match the table names as whole words
+	pattern := `\m(` + strings.Join(tables, "|") + `)\M`
+
+	// Define the query
+	query := `
+        SELECT query, calls, total_exec_time, mean_exec_time, rows
+        FROM pg_stat_statements
+        WHERE dbid = (SELECT oid FROM pg_database WHERE datname = current_database())
+          AND query ~* $1
+        ORDER BY total_exec_time DESC
+        LIMIT $2
+    `
+
+	// Execute the query
+	rows, err := r.db.QueryContext(ctx, query, pattern, limit)
+
+	// Log the query execution
+	utils.LogDBQuery(
+		query,
+		[]interface{}{pattern, limit},
+		time.Since(startTime),
+		err,
+	)
+
+	if err != nil {
+		return nil, fmt.Errorf("failed to get statement statistics: %w", err)
+	}
+	defer rows.Close()
+
+	statements := []models.StatementStats{}
+	for rows.Next() {
+		var statement models.StatementStats
+		if err := rows.Scan(
+			&statement.Query,
+			&statement.Calls,
+			&statement.TotalMs,
+			&statement.MeanMs,
+			&statement.Rows,
+		); err != nil {
+			return nil, fmt.Errorf("failed to scan statement statistics row: %w", err)
+		}
+		statements = append(statements, statement)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating statement statistics rows: %w", err)
+	}
+
+	return statements, nil
+}
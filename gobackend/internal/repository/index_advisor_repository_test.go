@@ -0,0 +1,134 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNewIndexAdvisorRepository(t *testing.T) {
+	// Arrange
+	pool, _, cleanup := setupDBMock(t)
+	defer cleanup()
+
+	// Act
+	repo := NewIndexAdvisorRepository(pool)
+
+	// Assert
+	assert.NotNil(t, repo, "Repository should not be nil")
+	assert.Implements(t, (*IndexAdvisorRepository)(nil), repo, "Should implement IndexAdvisorRepository interface")
+}
+
+func TestIndexAdvisorRepository_GetTableStats(t *testing.T) {
+	tables := []string{"documents", "detected_entities"}
+
+	t.Run("Success", func(t *testing.T) {
+		// Arrange
+		pool, mock, cleanup := setupDBMock(t)
+		defer cleanup()
+		repo := NewIndexAdvisorRepository(pool)
+
+		rows := sqlmock.NewRows([]string{"relname", "n_live_tup", "seq_scan", "seq_tup_read", "idx_scan"}).
+			AddRow("detected_entities", 250000, 40, 9000000, 12).
+			AddRow("documents", 5000, 3, 15000, 800)
+		mock.ExpectQuery("SELECT (.+) FROM pg_stat_user_tables WHERE relname = ANY").
+			WillReturnRows(rows)
+
+		// Act
+		stats, err := repo.GetTableStats(context.Background(), tables)
+
+		// Assert
+		require.NoError(t, err)
+		require.Len(t, stats, 2)
+		assert.Equal(t, "detected_entities", stats[0].Table)
+		assert.Equal(t, int64(250000), stats[0].LiveRows)
+		assert.Equal(t, int64(12), stats[0].IndexScans)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("Database Error", func(t *testing.T) {
+		// Arrange
+		pool, mock, cleanup := setupDBMock(t)
+		defer cleanup()
+		repo := NewIndexAdvisorRepository(pool)
+
+		mock.ExpectQuery("SELECT (.+) FROM pg_stat_user_tables").
+			WillReturnError(errors.New("database error"))
+
+		// Act
+		stats, err := repo.GetTableStats(context.Background(), tables)
+
+		// Assert
+		assert.Error(t, err)
+		assert.Nil(t, stats)
+		assert.Contains(t, err.Error(), "failed to get table statistics")
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+}
+
+func TestIndexAdvisorRepository_GetUnindexedForeignKeys(t *testing.T) {
+	// Arrange
+	pool, mock, cleanup := setupDBMock(t)
+	defer cleanup()
+	repo := NewIndexAdvisorRepository(pool)
+
+	rows := sqlmock.NewRows([]string{"relname", "conname", "columns"}).
+		AddRow("detected_entities", "detected_entities_method_id_fkey", "{method_id}")
+	mock.ExpectQuery("SELECT (.+) FROM pg_constraint con (.+) NOT EXISTS").
+		WillReturnRows(rows)
+
+	// Act
+	keys, err := repo.GetUnindexedForeignKeys(context.Background(), []string{"detected_entities"})
+
+	// Assert
+	require.NoError(t, err)
+	require.Len(t, keys, 1)
+	assert.Equal(t, "detected_entities_method_id_fkey", keys[0].Constraint)
+	assert.Equal(t, []string{"method_id"}, keys[0].Columns)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestIndexAdvisorRepository_HasStatStatements(t *testing.T) {
+	// Arrange
+	pool, mock, cleanup := setupDBMock(t)
+	defer cleanup()
+	repo := NewIndexAdvisorRepository(pool)
+
+	mock.ExpectQuery("SELECT EXISTS (.+) pg_extension").
+		WillReturnRows(sqlmock.NewRows([]string{"exists"}).AddRow(true))
+
+	// Act
+	available, err := repo.HasStatStatements(context.Background())
+
+	// Assert
+	require.NoError(t, err)
+	assert.True(t, available)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestIndexAdvisorRepository_GetTopStatements(t *testing.T) {
+	// Arrange
+	pool, mock, cleanup := setupDBMock(t)
+	defer cleanup()
+	repo := NewIndexAdvisorRepository(pool)
+
+	rows := sqlmock.NewRows([]string{"query", "calls", "total_exec_time", "mean_exec_time", "rows"}).
+		AddRow("SELECT * FROM documents WHERE user_id = $1", 1200, 360000.0, 300.0, 4800)
+	mock.ExpectQuery("SELECT (.+) FROM pg_stat_statements").
+		WithArgs(`\m(documents|detected_entities)\M`, 20).
+		WillReturnRows(rows)
+
+	// Act
+	statements, err := repo.GetTopStatements(context.Background(), []string{"documents", "detected_entities"}, 20)
+
+	// Assert
+	require.NoError(t, err)
+	require.Len(t, statements, 1)
+	assert.Equal(t, int64(1200), statements[0].Calls)
+	assert.Equal(t, 300.0, statements[0].MeanMs)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
@@ -0,0 +1,594 @@
+// Package repository provides data access interfaces and implementations for the HideMe application.
+// It follows the repository pattern to abstract database operations and provide a clean API
+// for data persistence operations.
+//
+// This file implements the job repository, which queues the detection, redaction and export
+// jobs running in the background and keeps their results.
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/yasinhessnawi1/Hideme_Backend/internal/constants"
+	"github.com/yasinhessnawi1/Hideme_Backend/internal/database"
+	"github.com/yasinhessnawi1/Hideme_Backend/internal/models"
+	"github.com/yasinhessnawi1/Hideme_Backend/internal/utils"
+)
+
+// JobRepository defines methods for the background job queue. Payloads and results are
+// stored encrypted with the data-encryption key of the user who queued the job, and the
+// payload is cleared once the job finished.
+type JobRepository interface {
+	// Create queues a new pending job that may start right away.
+	//
+	// Parameters:
+	//   - ctx: Context for transaction and cancellation control
+	//   - job: The job to create with its user, kind, document, payload, execution context and
+	//     max attempts; its ID, status, run time and timestamps are set on success
+	//
+	// Returns:
+	//   - An error for database issues
+	Create(ctx context.Context, job *models.Job) error
+
+	// GetByID retrieves a job of a user.
+	//
+	// Parameters:
+	//   - ctx: Context for transaction and cancellation control
+	//   - userID: The ID of the user
+	//   - id: The ID of the job
+	//
+	// Returns:
+	//   - The job
+	//   - NotFoundError if the user has no such job
+	//   - Other errors for database issues
+	GetByID(ctx context.Context, userID, id int64) (*models.Job, error)
+
+	// ListByUser retrieves the jobs of a user, newest first.
+	//
+	// Parameters:
+	//   - ctx: Context for transaction and cancellation control
+	//   - userID: The ID of the user
+	//
+	// Returns:
+	//   - The jobs (empty if there are none)
+	//   - An error for database issues
+	ListByUser(ctx context.Context, userID int64) ([]*models.Job, error)
+
+	// Claim takes the oldest job due to run: a pending job whose run time has passed, or a
+	// running job whose server let its lease expire. The job is set running and its attempt
+	// is counted; its payload is decrypted and its execution context read for the handler.
+	//
+	// Parameters:
+	//   - ctx: Context for transaction and cancellation control
+	//   - now: The current time, to find due jobs and expired leases
+	//   - leaseUntil: When the claiming worker must have finished the job
+	//
+	// Returns:
+	//   - The claimed job, or nil if no job is due
+	//   - An error for database issues
+	Claim(ctx context.Context, now, leaseUntil time.Time) (*models.Job, error)
+
+	// Complete stores the result of a running job and sets it completed.
+	//
+	// Parameters:
+	//   - ctx: Context for transaction and cancellation control
+	//   - job: The job as claimed
+	//   - result: The output of the job
+	//
+	// Returns:
+	//   - NotFoundError if the job is no longer running this attempt, e.g. because another
+	//     server took it over; nothing is stored then
+	//   - Other errors for database issues
+	Complete(ctx context.Context, job *models.Job, result *models.JobResult) error
+
+	// Retry sets a running job pending again after a failed attempt.
+	//
+	// Parameters:
+	//   - ctx: Context for transaction and cancellation control
+	//   - job: The job as claimed
+	//   - lastError: Why the attempt failed
+	//   - runAfter: When the next attempt may start
+	//
+	// Returns:
+	//   - NotFoundError if the job is no longer running this attempt
+	//   - Other errors for database issues
+	Retry(ctx context.Context, job *models.Job, lastError string, runAfter time.Time) error
+
+	// Fail sets a running job failed for good.
+	//
+	// Parameters:
+	//   - ctx: Context for transaction and cancellation control
+	//   - job: The job as claimed
+	//   - lastError: Why the job failed
+	//
+	// Returns:
+	//   - NotFoundError if the job is no longer running this attempt
+	//   - Other errors for database issues
+	Fail(ctx context.Context, job *models.Job, lastError string) error
+
+	// GetResult retrieves and decrypts the result of a completed job of a user.
+	//
+	// Parameters:
+	//   - ctx: Context for transaction and cancellation control
+	//   - userID: The ID of the user
+	//   - id: The ID of the job
+	//
+	// Returns:
+	//   - The result
+	//   - NotFoundError if the user has no such job or it has no result
+	//   - Other errors for database issues
+	GetResult(ctx context.Context, userID, id int64) (*models.JobResult, error)
+
+	// DeleteFinishedBefore removes completed and failed jobs, with their results, last
+	// changed before a cutoff.
+	//
+	// Parameters:
+	//   - ctx: Context for transaction and cancellation control
+	//   - cutoff: Jobs finished before this moment are removed
+	//
+	// Returns:
+	//   - The number of removed jobs
+	//   - An error for database issues
+	DeleteFinishedBefore(ctx context.Context, cutoff time.Time) (int64, error)
+}
+
+// PostgresJobRepository is a PostgreSQL implementation of JobRepository.
+type PostgresJobRepository struct {
+	db         *database.Pool
+	tenantKeys TenantKeyRepository
+}
+
+// NewJobRepository creates a new JobRepository implementation for PostgreSQL.
+//
+// Parameters:
+//   - db: A connection pool for PostgreSQL database access
+//   - tenantKeys: Repository for the data-encryption keys payloads and results are encrypted with
+//
+// Returns:
+//   - An implementation of the JobRepository interface
+func NewJobRepository(db *database.Pool, tenantKeys TenantKeyRepository) JobRepository {
+	return &PostgresJobRepository{
+		db:         db,
+		tenantKeys: tenantKeys,
+	}
+}
+
+// jobColumns are the columns selected for jobs; the payload and execution context are only
+// read by Claim and the result by GetResult.
+const jobColumns = `job_id, ` + constants.ColumnUserID + `, kind, ` + constants.ColumnDocumentID + `, status,
+               attempts, max_attempts, run_after, lease_until, last_error, result_type,
+               created_at, updated_at, completed_at`
+
+// Create queues a new pending job that may start right away.
+func (r *PostgresJobRepository) Create(ctx context.Context, job *models.Job) error {
+	// Encrypt the payload before the insert
+	payload, err := r.seal(ctx, job.UserID, job.Payload)
+	if err != nil {
+		return fmt.Errorf("failed to encrypt job payload: %w", err)
+	}
+
+	// Start query timer
+	startTime := time.Now()
+
+	// Define the query
+	query := `
+        INSERT INTO ` + constants.TableJobs + ` (` + constants.ColumnUserID + `, ` + constants.ColumnDocumentID + `, kind, status, payload, max_attempts, run_after, created_at, updated_at, execution_context)
+        VALUES ($1, $2, $3, $4, $5, $6, $7, $7, $7, $8)
+        RETURNING job_id
+    `
+	now := time.Now()
+
+	// Execute the query
+	err = r.db.QueryRowContext(ctx, query, job.UserID, job.DocumentID, job.Kind, constants.JobPending, payload, job.MaxAttempts, now, job.Context).Scan(&job.ID)
+
+	// Log the query execution; the payload is left out
+	utils.LogDBQuery(
+		query,
+		[]interface{}{job.UserID, job.DocumentID, job.Kind, constants.JobPending, job.MaxAttempts, now, job.Context},
+		time.Since(startTime),
+		err,
+	)
+
+	if err != nil {
+		return fmt.Errorf("failed to create job: %w", err)
+	}
+	job.Status = constants.JobPending
+	job.RunAfter = now
+	job.CreatedAt = now
+	job.UpdatedAt = now
+
+	return nil
+}
+
+// GetByID retrieves a job of a user.
+func (r *PostgresJobRepository) GetByID(ctx context.Context, userID, id int64) (*models.Job, error) {
+	// Start query timer
+	startTime := time.Now()
+
+	// Define the query
+	query := `
+        SELECT ` + jobColumns + `
+        FROM ` + constants.TableJobs + `
+        WHERE job_id = $1 AND ` + constants.ColumnUserID + ` = $2
+    `
+
+	// Execute the query
+	job, err := scanJob(r.db.QueryRowContext(ctx, query, id, userID))
+
+	// Log the query execution
+	utils.LogDBQuery(
+		query,
+		[]interface{}{id, userID},
+		time.Since(startTime),
+		err,
+	)
+
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, utils.NewNotFoundError("Job", id)
+		}
+		return nil, fmt.Errorf("failed to get job: %w", err)
+	}
+
+	return job, nil
+}
+
+// ListByUser retrieves the jobs of a user, newest first.
+func (r *PostgresJobRepository) ListByUser(ctx context.Context, userID int64) ([]*models.Job, error) {
+	// Start query timer
+	startTime := time.Now()
+
+	// Define the query
+	query := `
+        SELECT ` + jobColumns + `
+        FROM ` + constants.TableJobs + `
+        WHERE ` + constants.ColumnUserID + ` = $1
+        ORDER BY created_at DESC, job_id DESC
+    `
+
+	// Execute the query
+	jobs, err := r.query(ctx, query, userID)
+
+	// Log the query execution
+	utils.LogDBQuery(
+		query,
+		[]interface{}{userID},
+		time.Since(startTime),
+		err,
+	)
+
+	if err != nil {
+		return nil, fmt.Errorf("failed to list jobs: %w", err)
+	}
+
+	return jobs, nil
+}
+
+// Claim takes the oldest job due to run.
+// The job is locked while it is claimed; jobs locked by another server are skipped.
+func (r *PostgresJobRepository) Claim(ctx context.Context, now, leaseUntil time.Time) (*models.Job, error) {
+	// Start query timer
+	startTime := time.Now()
+
+	// Define the query
+	query := `
+        UPDATE ` + constants.TableJobs + `
+        SET attempts = attempts + 1,
+            status = $1,
+            lease_until = $2,
+            updated_at = $3
+        WHERE job_id = (
+            SELECT job_id
+            FROM ` + constants.TableJobs + `
+            WHERE (status = $4 AND run_after <= $3) OR (status = $1 AND lease_until < $3)
+            ORDER BY run_after
+            LIMIT 1
+            FOR UPDATE SKIP LOCKED
+        )
+        RETURNING ` + jobColumns + `, payload, execution_context
+    `
+	args := []interface{}{constants.JobRunning, leaseUntil, now, constants.JobPending}
+
+	// Execute the query
+	var payload string
+	var jobContext models.JobContext
+	job, err := scanJob(r.db.QueryRowContext(ctx, query, args...), &payload, &jobContext)
+
+	// Log the query execution
+	utils.LogDBQuery(
+		query,
+		args,
+		time.Since(startTime),
+		err,
+	)
+
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("failed to claim job: %w", err)
+	}
+
+	if payload != "" {
+		data, err := r.open(ctx, job.UserID, payload)
+		if err != nil {
+			return nil, fmt.Errorf("failed to decrypt job payload: %w", err)
+		}
+		job.Payload = data
+	}
+	job.Context = jobContext
+
+	return job, nil
+}
+
+// Complete stores the result of a running job and sets it completed.
+func (r *PostgresJobRepository) Complete(ctx context.Context, job *models.Job, result *models.JobResult) error {
+	// Encrypt the result before the update
+	sealed, err := r.seal(ctx, job.UserID, result.Data)
+	if err != nil {
+		return fmt.Errorf("failed to encrypt job result: %w", err)
+	}
+
+	// Start query timer
+	startTime := time.Now()
+
+	// Define the query
+	query := `
+        UPDATE ` + constants.TableJobs + `
+        SET status = $1,
+            result_type = $2,
+            result = $3,
+            payload = '',
+            last_error = '',
+            lease_until = NULL,
+            updated_at = $4,
+            completed_at = $4
+        WHERE job_id = $5 AND status = $6 AND attempts = $7
+    `
+	now := time.Now()
+
+	// Execute the query
+	res, err := r.db.ExecContext(ctx, query, constants.JobCompleted, result.ContentType, sealed, now, job.ID, constants.JobRunning, job.Attempts)
+
+	// Log the query execution; the result is left out
+	utils.LogDBQuery(
+		query,
+		[]interface{}{constants.JobCompleted, result.ContentType, now, job.ID, constants.JobRunning, job.Attempts},
+		time.Since(startTime),
+		err,
+	)
+
+	if err != nil {
+		return fmt.Errorf("failed to complete job: %w", err)
+	}
+
+	return r.checkFinished(res, job.ID)
+}
+
+// Retry sets a running job pending again after a failed attempt.
+func (r *PostgresJobRepository) Retry(ctx context.Context, job *models.Job, lastError string, runAfter time.Time) error {
+	// Start query timer
+	startTime := time.Now()
+
+	// Define the query
+	query := `
+        UPDATE ` + constants.TableJobs + `
+        SET status = $1,
+            last_error = $2,
+            run_after = $3,
+            lease_until = NULL,
+            updated_at = $4
+        WHERE job_id = $5 AND status = $6 AND attempts = $7
+    `
+	args := []interface{}{constants.JobPending, lastError, runAfter, time.Now(), job.ID, constants.JobRunning, job.Attempts}
+
+	// Execute the query
+	res, err := r.db.ExecContext(ctx, query, args...)
+
+	// Log the query execution
+	utils.LogDBQuery(
+		query,
+		args,
+		time.Since(startTime),
+		err,
+	)
+
+	if err != nil {
+		return fmt.Errorf("failed to retry job: %w", err)
+	}
+
+	return r.checkFinished(res, job.ID)
+}
+
+// Fail sets a running job failed for good.
+func (r *PostgresJobRepository) Fail(ctx context.Context, job *models.Job, lastError string) error {
+	// Start query timer
+	startTime := time.Now()
+
+	// Define the query
+	query := `
+        UPDATE ` + constants.TableJobs + `
+        SET status = $1,
+            last_error = $2,
+            payload = '',
+            lease_until = NULL,
+            updated_at = $3
+        WHERE job_id = $4 AND status = $5 AND attempts = $6
+    `
+	args := []interface{}{constants.JobFailed, lastError, time.Now(), job.ID, constants.JobRunning, job.Attempts}
+
+	// Execute the query
+	res, err := r.db.ExecContext(ctx, query, args...)
+
+	// Log the query execution
+	utils.LogDBQuery(
+		query,
+		args,
+		time.Since(startTime),
+		err,
+	)
+
+	if err != nil {
+		return fmt.Errorf("failed to fail job: %w", err)
+	}
+
+	return r.checkFinished(res, job.ID)
+}
+
+// GetResult retrieves and decrypts the result of a completed job of a user.
+func (r *PostgresJobRepository) GetResult(ctx context.Context, userID, id int64) (*models.JobResult, error) {
+	// Start query timer
+	startTime := time.Now()
+
+	// Define the query
+	query := `
+        SELECT result_type, result
+        FROM ` + constants.TableJobs + `
+        WHERE job_id = $1 AND ` + constants.ColumnUserID + ` = $2 AND result IS NOT NULL
+    `
+
+	// Execute the query
+	result := &models.JobResult{}
+	var ciphertext string
+	err := r.db.QueryRowContext(ctx, query, id, userID).Scan(&result.ContentType, &ciphertext)
+
+	// Log the query execution
+	utils.LogDBQuery(
+		query,
+		[]interface{}{id, userID},
+		time.Since(startTime),
+		err,
+	)
+
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, utils.NewNotFoundError("JobResult", id)
+		}
+		return nil, fmt.Errorf("failed to get job result: %w", err)
+	}
+
+	if result.Data, err = r.open(ctx, userID, ciphertext); err != nil {
+		return nil, fmt.Errorf("failed to decrypt job result: %w", err)
+	}
+
+	return result, nil
+}
+
+// DeleteFinishedBefore removes completed and failed jobs last changed before a cutoff.
+func (r *PostgresJobRepository) DeleteFinishedBefore(ctx context.Context, cutoff time.Time) (int64, error) {
+	// Start query timer
+	startTime := time.Now()
+
+	// Define the query
+	query := `
+        DELETE FROM ` + constants.TableJobs + `
+        WHERE status IN ($1, $2) AND updated_at < $3
+    `
+
+	// Execute the query
+	result, err := r.db.ExecContext(ctx, query, constants.JobCompleted, constants.JobFailed, cutoff)
+
+	// Log the query execution
+	utils.LogDBQuery(
+		query,
+		[]interface{}{constants.JobCompleted, constants.JobFailed, cutoff},
+		time.Since(startTime),
+		err,
+	)
+
+	if err != nil {
+		return 0, fmt.Errorf("failed to delete finished jobs: %w", err)
+	}
+
+	return result.RowsAffected()
+}
+
+// seal encrypts a payload or result with the data-encryption key of a user; empty data is
+// stored as is.
+func (r *PostgresJobRepository) seal(ctx context.Context, userID int64, data []byte) (string, error) {
+	if len(data) == 0 {
+		return "", nil
+	}
+	dataKey, err := r.tenantKeys.GetOrCreateDataKey(ctx, userID)
+	if err != nil {
+		return "", fmt.Errorf("failed to get tenant data key: %w", err)
+	}
+	ciphertext, err := utils.EncryptKey(string(data), dataKey)
+	if err != nil {
+		return "", err
+	}
+	return constants.TenantCiphertextPrefix + ciphertext, nil
+}
+
+// open decrypts a payload or result sealed with the data-encryption key of a user.
+func (r *PostgresJobRepository) open(ctx context.Context, userID int64, sealed string) ([]byte, error) {
+	if sealed == "" {
+		return nil, nil
+	}
+	dataKey, err := r.tenantKeys.GetDataKey(ctx, userID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get tenant data key: %w", err)
+	}
+	data, err := utils.DecryptKey(strings.TrimPrefix(sealed, constants.TenantCiphertextPrefix), dataKey)
+	if err != nil {
+		return nil, err
+	}
+	return []byte(data), nil
+}
+
+// checkFinished reports a NotFoundError if an update ending an attempt of a job matched no row.
+func (r *PostgresJobRepository) checkFinished(result sql.Result, id int64) error {
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("failed to get affected rows: %w", err)
+	}
+
+	if rowsAffected == 0 {
+		return utils.NewNotFoundError("Job", id)
+	}
+
+	return nil
+}
+
+// query runs a query selecting jobColumns and scans the jobs.
+func (r *PostgresJobRepository) query(ctx context.Context, query string, args ...interface{}) ([]*models.Job, error) {
+	rows, err := r.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	jobs := []*models.Job{}
+	for rows.Next() {
+		job, err := scanJob(rows)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan job row: %w", err)
+		}
+		jobs = append(jobs, job)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating job rows: %w", err)
+	}
+
+	return jobs, nil
+}
+
+// scanJob scans a row of jobColumns, followed by the extra columns into extra.
+func scanJob(scanner interface{ Scan(dest ...any) error }, extra ...any) (*models.Job, error) {
+	job := &models.Job{}
+	dest := append([]any{
+		&job.ID, &job.UserID, &job.Kind, &job.DocumentID, &job.Status,
+		&job.Attempts, &job.MaxAttempts, &job.RunAfter, &job.LeaseUntil, &job.LastError, &job.ResultType,
+		&job.CreatedAt, &job.UpdatedAt, &job.CompletedAt,
+	}, extra...)
+	if err := scanner.Scan(dest...); err != nil {
+		return nil, err
+	}
+	return job, nil
+}
@@ -0,0 +1,197 @@
+package repository_test
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/yasinhessnawi1/Hideme_Backend/internal/constants"
+	"github.com/yasinhessnawi1/Hideme_Backend/internal/database"
+	"github.com/yasinhessnawi1/Hideme_Backend/internal/models"
+	"github.com/yasinhessnawi1/Hideme_Backend/internal/repository"
+	"github.com/yasinhessnawi1/Hideme_Backend/internal/utils"
+)
+
+var jobTestColumns = []string{
+	"job_id", "user_id", "kind", "document_id", "status",
+	"attempts", "max_attempts", "run_after", "lease_until", "last_error", "result_type",
+	"created_at", "updated_at", "completed_at",
+}
+
+func setupJobTest(t *testing.T) (repository.JobRepository, sqlmock.Sqlmock, func()) {
+	db, mock, err := sqlmock.New()
+	require.NoError(t, err)
+
+	tenantKeys := &stubTenantKeyRepository{keys: make(map[int64][]byte)}
+	repo := repository.NewJobRepository(&database.Pool{DB: db}, tenantKeys)
+
+	return repo, mock, func() { db.Close() }
+}
+
+func TestJobRepository_Create(t *testing.T) {
+	repo, mock, cleanup := setupJobTest(t)
+	defer cleanup()
+
+	// The payload is stored encrypted, the execution context as it is
+	sealed := &capturedArg{}
+	mock.ExpectQuery("INSERT INTO jobs").
+		WithArgs(int64(7), int64(12), constants.JobKindRedaction, constants.JobPending, sealed, constants.JobMaxAttempts, sqlmock.AnyArg(),
+			[]byte(`{"request_id":"req-1","locale":"de-DE"}`)).
+		WillReturnRows(sqlmock.NewRows([]string{"job_id"}).AddRow(3))
+
+	job := &models.Job{
+		UserID:      7,
+		DocumentID:  12,
+		Kind:        constants.JobKindRedaction,
+		Payload:     []byte(`{"method":"mask"}`),
+		Context:     models.JobContext{RequestID: "req-1", Locale: models.LocaleGerman},
+		MaxAttempts: constants.JobMaxAttempts,
+	}
+	require.NoError(t, repo.Create(context.Background(), job))
+	assert.Equal(t, int64(3), job.ID)
+	assert.Equal(t, constants.JobPending, job.Status)
+	assert.False(t, job.RunAfter.IsZero())
+	assert.True(t, strings.HasPrefix(sealed.value.(string), constants.TenantCiphertextPrefix))
+	assert.NotContains(t, sealed.value.(string), "mask")
+
+	// Claiming the job decrypts the payload and restores the execution context for the handler
+	now := time.Now()
+	mock.ExpectQuery("UPDATE jobs SET attempts = attempts \\+ 1, (.+) RETURNING (.+), payload, execution_context").
+		WillReturnRows(sqlmock.NewRows(append(jobTestColumns, "payload", "execution_context")).
+			AddRow(3, 7, constants.JobKindRedaction, 12, constants.JobRunning, 1, 5, now, now, "", "", now, now, nil, sealed.value, []byte(`{"request_id":"req-1","locale":"de-DE"}`)))
+
+	claimed, err := repo.Claim(context.Background(), now, now.Add(constants.JobLease))
+	require.NoError(t, err)
+	assert.JSONEq(t, `{"method":"mask"}`, string(claimed.Payload))
+	assert.Equal(t, job.Context, claimed.Context)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestJobRepository_GetByID_NotFound(t *testing.T) {
+	repo, mock, cleanup := setupJobTest(t)
+	defer cleanup()
+
+	mock.ExpectQuery("SELECT (.+) FROM jobs WHERE job_id = \\$1 AND user_id = \\$2").
+		WithArgs(int64(3), int64(7)).
+		WillReturnRows(sqlmock.NewRows(jobTestColumns))
+
+	_, err := repo.GetByID(context.Background(), 7, 3)
+	var appErr *utils.AppError
+	require.True(t, errors.As(err, &appErr))
+	assert.True(t, errors.Is(appErr.Err, utils.ErrNotFound))
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestJobRepository_Claim(t *testing.T) {
+	repo, mock, cleanup := setupJobTest(t)
+	defer cleanup()
+	now := time.Now()
+	leaseUntil := now.Add(constants.JobLease)
+
+	mock.ExpectQuery("UPDATE jobs SET attempts = attempts \\+ 1, (.+) FOR UPDATE SKIP LOCKED").
+		WithArgs(constants.JobRunning, leaseUntil, now, constants.JobPending).
+		WillReturnRows(sqlmock.NewRows(append(jobTestColumns, "payload", "execution_context")).
+			AddRow(3, 7, constants.JobKindExport, 12, constants.JobRunning, 2, 5, now, leaseUntil, "timeout", "", now, now, nil, "", []byte(`{}`)))
+
+	job, err := repo.Claim(context.Background(), now, leaseUntil)
+	require.NoError(t, err)
+	require.NotNil(t, job)
+	assert.Equal(t, constants.JobKindExport, job.Kind)
+	assert.Equal(t, int64(12), job.DocumentID)
+	assert.Equal(t, 2, job.Attempts)
+	assert.Equal(t, "timeout", job.LastError)
+	assert.Nil(t, job.Payload)
+	assert.Equal(t, models.JobContext{}, job.Context)
+
+	// Nothing due
+	mock.ExpectQuery("UPDATE jobs SET attempts").
+		WillReturnRows(sqlmock.NewRows(append(jobTestColumns, "payload", "execution_context")))
+
+	job, err = repo.Claim(context.Background(), now, leaseUntil)
+	require.NoError(t, err)
+	assert.Nil(t, job)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestJobRepository_CompleteAndGetResult(t *testing.T) {
+	repo, mock, cleanup := setupJobTest(t)
+	defer cleanup()
+
+	job := &models.Job{ID: 3, UserID: 7, Attempts: 2}
+	result := &models.JobResult{ContentType: constants.ContentTypePDF, Data: []byte("%PDF-1.7 redacted contract")}
+
+	// The result is stored encrypted, for the attempt that claimed the job only
+	sealed := &capturedArg{}
+	mock.ExpectExec("UPDATE jobs SET status = \\$1, result_type = \\$2, result = \\$3").
+		WithArgs(constants.JobCompleted, constants.ContentTypePDF, sealed, sqlmock.AnyArg(), int64(3), constants.JobRunning, 2).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+
+	require.NoError(t, repo.Complete(context.Background(), job, result))
+	assert.True(t, strings.HasPrefix(sealed.value.(string), constants.TenantCiphertextPrefix))
+	assert.NotContains(t, sealed.value.(string), "contract")
+
+	// Reading the result decrypts it with the tenant key
+	mock.ExpectQuery("SELECT result_type, result FROM jobs WHERE job_id = \\$1 AND user_id = \\$2 AND result IS NOT NULL").
+		WithArgs(int64(3), int64(7)).
+		WillReturnRows(sqlmock.NewRows([]string{"result_type", "result"}).AddRow(constants.ContentTypePDF, sealed.value))
+
+	got, err := repo.GetResult(context.Background(), 7, 3)
+	require.NoError(t, err)
+	assert.Equal(t, result, got)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestJobRepository_Retry(t *testing.T) {
+	repo, mock, cleanup := setupJobTest(t)
+	defer cleanup()
+	runAfter := time.Now().Add(constants.JobRetryBaseDelay)
+
+	mock.ExpectExec("UPDATE jobs SET status = \\$1, last_error = \\$2, run_after = \\$3, lease_until = NULL").
+		WithArgs(constants.JobPending, "connection reset", runAfter, sqlmock.AnyArg(), int64(3), constants.JobRunning, 1).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+
+	require.NoError(t, repo.Retry(context.Background(), &models.Job{ID: 3, Attempts: 1}, "connection reset", runAfter))
+
+	// Another server took the job over after the lease expired
+	mock.ExpectExec("UPDATE jobs SET status = \\$1, last_error = \\$2, run_after = \\$3").
+		WillReturnResult(sqlmock.NewResult(0, 0))
+
+	err := repo.Retry(context.Background(), &models.Job{ID: 3, Attempts: 1}, "connection reset", runAfter)
+	var appErr *utils.AppError
+	require.True(t, errors.As(err, &appErr))
+	assert.True(t, errors.Is(appErr.Err, utils.ErrNotFound))
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestJobRepository_Fail(t *testing.T) {
+	repo, mock, cleanup := setupJobTest(t)
+	defer cleanup()
+
+	mock.ExpectExec("UPDATE jobs SET status = \\$1, last_error = \\$2, payload = '', lease_until = NULL").
+		WithArgs(constants.JobFailed, "document not found", sqlmock.AnyArg(), int64(3), constants.JobRunning, 5).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+
+	require.NoError(t, repo.Fail(context.Background(), &models.Job{ID: 3, Attempts: 5}, "document not found"))
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestJobRepository_DeleteFinishedBefore(t *testing.T) {
+	repo, mock, cleanup := setupJobTest(t)
+	defer cleanup()
+	cutoff := time.Now().Add(-constants.JobRetention)
+
+	mock.ExpectExec("DELETE FROM jobs WHERE status IN \\(\\$1, \\$2\\) AND updated_at < \\$3").
+		WithArgs(constants.JobCompleted, constants.JobFailed, cutoff).
+		WillReturnResult(sqlmock.NewResult(0, 2))
+
+	deleted, err := repo.DeleteFinishedBefore(context.Background(), cutoff)
+	require.NoError(t, err)
+	assert.Equal(t, int64(2), deleted)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
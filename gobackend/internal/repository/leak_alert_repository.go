@@ -0,0 +1,95 @@
+// Package repository provides data access interfaces and implementations for the HideMe application.
+// It follows the repository pattern to abstract database operations and provide a clean API
+// for data persistence operations.
+//
+// This file implements the leak alert repository, which records the values users were
+// alerted about for leaking in many documents.
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/lib/pq"
+
+	"github.com/yasinhessnawi1/Hideme_Backend/internal/constants"
+	"github.com/yasinhessnawi1/Hideme_Backend/internal/database"
+	"github.com/yasinhessnawi1/Hideme_Backend/internal/models"
+	"github.com/yasinhessnawi1/Hideme_Backend/internal/utils"
+)
+
+// LeakAlertRepository defines methods for recording alerts about values leaking in many documents.
+type LeakAlertRepository interface {
+	// Claim records an alert unless the user was alerted about the same value since a time.
+	// Claiming is atomic, so concurrent uploads alert about a value only once.
+	//
+	// Parameters:
+	//   - ctx: Context for transaction and cancellation control
+	//   - alert: The alert to record; its ID is set when it is claimed
+	//   - realertAfter: The time after which an earlier alert about the value suppresses this one
+	//
+	// Returns:
+	//   - Whether the alert was recorded and should be raised
+	//   - An error for database issues
+	Claim(ctx context.Context, alert *models.LeakAlert, realertAfter time.Time) (bool, error)
+}
+
+// PostgresLeakAlertRepository is a PostgreSQL implementation of LeakAlertRepository.
+type PostgresLeakAlertRepository struct {
+	db *database.Pool
+}
+
+// NewLeakAlertRepository creates a new LeakAlertRepository implementation for PostgreSQL.
+//
+// Parameters:
+//   - db: A connection pool for PostgreSQL database access
+//
+// Returns:
+//   - An implementation of the LeakAlertRepository interface
+func NewLeakAlertRepository(db *database.Pool) LeakAlertRepository {
+	return &PostgresLeakAlertRepository{
+		db: db,
+	}
+}
+
+// Claim records an alert unless the user was alerted about the same value since a time.
+func (r *PostgresLeakAlertRepository) Claim(ctx context.Context, alert *models.LeakAlert, realertAfter time.Time) (bool, error) {
+	// Start query timer
+	startTime := time.Now()
+
+	// Define the query; an earlier alert is only replaced once it is older than realertAfter
+	query := `
+        INSERT INTO ` + constants.TableLeakAlerts + ` (user_id, entity_type, value_hash, document_count, document_ids, alerted_at)
+        VALUES ($1, $2, $3, $4, $5, $6)
+        ON CONFLICT (user_id, entity_type, value_hash) DO UPDATE
+        SET document_count = EXCLUDED.document_count,
+            document_ids = EXCLUDED.document_ids,
+            alerted_at = EXCLUDED.alerted_at
+        WHERE ` + constants.TableLeakAlerts + `.alerted_at < $7
+        RETURNING alert_id
+    `
+	args := []interface{}{alert.UserID, alert.EntityType, alert.ValueHash, alert.DocumentCount, pq.Array(alert.DocumentIDs), alert.AlertedAt, realertAfter}
+
+	// Execute the query
+	err := r.db.QueryRowContext(ctx, query, args...).Scan(&alert.ID)
+
+	// Log the query execution
+	utils.LogDBQuery(
+		query,
+		args,
+		time.Since(startTime),
+		err,
+	)
+
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return false, nil
+		}
+		return false, fmt.Errorf("failed to record leak alert: %w", err)
+	}
+
+	return true, nil
+}
@@ -0,0 +1,57 @@
+package repository_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/yasinhessnawi1/Hideme_Backend/internal/database"
+	"github.com/yasinhessnawi1/Hideme_Backend/internal/models"
+	"github.com/yasinhessnawi1/Hideme_Backend/internal/repository"
+)
+
+func TestLeakAlertRepository_Claim(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	require.NoError(t, err)
+	defer db.Close()
+	repo := repository.NewLeakAlertRepository(&database.Pool{DB: db})
+
+	now := time.Now()
+	realertAfter := now.AddDate(0, 0, -30)
+	alert := &models.LeakAlert{UserID: 7, EntityType: "NATIONAL_ID", ValueHash: "ab12", DocumentCount: 4, DocumentIDs: []int64{3, 12, 15, 19}, AlertedAt: now}
+
+	t.Run("Claimed", func(t *testing.T) {
+		mock.ExpectQuery("INSERT INTO leak_alerts (.+) ON CONFLICT \\(user_id, entity_type, value_hash\\) DO UPDATE (.+) WHERE leak_alerts.alerted_at < \\$7 RETURNING alert_id").
+			WithArgs(int64(7), "NATIONAL_ID", "ab12", int64(4), "{3,12,15,19}", now, realertAfter).
+			WillReturnRows(sqlmock.NewRows([]string{"alert_id"}).AddRow(5))
+
+		claimed, err := repo.Claim(context.Background(), alert, realertAfter)
+		require.NoError(t, err)
+		assert.True(t, claimed)
+		assert.Equal(t, int64(5), alert.ID)
+	})
+
+	t.Run("Alerted recently", func(t *testing.T) {
+		mock.ExpectQuery("INSERT INTO leak_alerts").
+			WillReturnRows(sqlmock.NewRows([]string{"alert_id"}))
+
+		claimed, err := repo.Claim(context.Background(), alert, realertAfter)
+		require.NoError(t, err)
+		assert.False(t, claimed)
+	})
+
+	t.Run("Database error", func(t *testing.T) {
+		mock.ExpectQuery("INSERT INTO leak_alerts").
+			WillReturnError(errors.New("connection reset"))
+
+		_, err := repo.Claim(context.Background(), alert, realertAfter)
+		assert.Error(t, err)
+	})
+
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
@@ -0,0 +1,141 @@
+// Package repository provides data access interfaces and implementations for the HideMe application.
+// It follows the repository pattern to abstract database operations and provide a clean API
+// for data persistence operations.
+//
+// This file implements the maintenance settings repository, which stores the settings
+// administrators chose for the periodic maintenance tasks.
+package repository
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/yasinhessnawi1/Hideme_Backend/internal/constants"
+	"github.com/yasinhessnawi1/Hideme_Backend/internal/database"
+	"github.com/yasinhessnawi1/Hideme_Backend/internal/models"
+	"github.com/yasinhessnawi1/Hideme_Backend/internal/utils"
+)
+
+// MaintenanceSettingsRepository defines methods for storing the settings of maintenance tasks.
+type MaintenanceSettingsRepository interface {
+	// List retrieves the stored settings of all tasks. Tasks whose settings were never
+	// changed have none.
+	//
+	// Parameters:
+	//   - ctx: Context for transaction and cancellation control
+	//
+	// Returns:
+	//   - The stored settings
+	//   - An error for database issues
+	List(ctx context.Context) ([]*models.MaintenanceTaskSettings, error)
+
+	// Upsert stores the settings of a task, replacing those stored before.
+	//
+	// Parameters:
+	//   - ctx: Context for transaction and cancellation control
+	//   - settings: The settings to store
+	//
+	// Returns:
+	//   - An error for database issues
+	Upsert(ctx context.Context, settings *models.MaintenanceTaskSettings) error
+}
+
+// PostgresMaintenanceSettingsRepository is a PostgreSQL implementation of MaintenanceSettingsRepository.
+type PostgresMaintenanceSettingsRepository struct {
+	db *database.Pool
+}
+
+// NewMaintenanceSettingsRepository creates a new MaintenanceSettingsRepository implementation for PostgreSQL.
+//
+// Parameters:
+//   - db: A connection pool for PostgreSQL database access
+//
+// Returns:
+//   - An implementation of the MaintenanceSettingsRepository interface
+func NewMaintenanceSettingsRepository(db *database.Pool) MaintenanceSettingsRepository {
+	return &PostgresMaintenanceSettingsRepository{
+		db: db,
+	}
+}
+
+// List retrieves the stored settings of all tasks.
+func (r *PostgresMaintenanceSettingsRepository) List(ctx context.Context) ([]*models.MaintenanceTaskSettings, error) {
+	// Start query timer
+	startTime := time.Now()
+
+	// Define the query
+	query := `
+        SELECT task_name, enabled, interval_seconds, batch_size, dry_run, updated_by, updated_at
+        FROM ` + constants.TableMaintenanceTaskSettings + `
+        ORDER BY task_name
+    `
+
+	// Execute the query
+	rows, err := r.db.QueryContext(ctx, query)
+
+	// Log the query execution
+	utils.LogDBQuery(
+		query,
+		nil,
+		time.Since(startTime),
+		err,
+	)
+
+	if err != nil {
+		return nil, fmt.Errorf("failed to list maintenance task settings: %w", err)
+	}
+	defer rows.Close()
+
+	var settings []*models.MaintenanceTaskSettings
+	for rows.Next() {
+		s := &models.MaintenanceTaskSettings{}
+		if err := rows.Scan(&s.Task, &s.Enabled, &s.IntervalSeconds, &s.BatchSize, &s.DryRun, &s.UpdatedBy, &s.UpdatedAt); err != nil {
+			return nil, fmt.Errorf("failed to scan maintenance task settings row: %w", err)
+		}
+		settings = append(settings, s)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating maintenance task settings rows: %w", err)
+	}
+
+	return settings, nil
+}
+
+// Upsert stores the settings of a task, replacing those stored before.
+func (r *PostgresMaintenanceSettingsRepository) Upsert(ctx context.Context, settings *models.MaintenanceTaskSettings) error {
+	// Start query timer
+	startTime := time.Now()
+
+	// Define the query
+	query := `
+        INSERT INTO ` + constants.TableMaintenanceTaskSettings + ` (task_name, enabled, interval_seconds, batch_size, dry_run, updated_by, updated_at)
+        VALUES ($1, $2, $3, $4, $5, $6, $7)
+        ON CONFLICT (task_name) DO UPDATE
+        SET enabled = EXCLUDED.enabled,
+            interval_seconds = EXCLUDED.interval_seconds,
+            batch_size = EXCLUDED.batch_size,
+            dry_run = EXCLUDED.dry_run,
+            updated_by = EXCLUDED.updated_by,
+            updated_at = EXCLUDED.updated_at
+    `
+
+	// Execute the query
+	args := []interface{}{settings.Task, settings.Enabled, settings.IntervalSeconds, settings.BatchSize, settings.DryRun, settings.UpdatedBy, settings.UpdatedAt}
+	_, err := r.db.ExecContext(ctx, query, args...)
+
+	// Log the query execution
+	utils.LogDBQuery(
+		query,
+		args,
+		time.Since(startTime),
+		err,
+	)
+
+	if err != nil {
+		return fmt.Errorf("failed to store maintenance task settings: %w", err)
+	}
+
+	return nil
+}
@@ -0,0 +1,78 @@
+package repository_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/yasinhessnawi1/Hideme_Backend/internal/database"
+	"github.com/yasinhessnawi1/Hideme_Backend/internal/models"
+	"github.com/yasinhessnawi1/Hideme_Backend/internal/repository"
+)
+
+func setupMaintenanceSettingsTest(t *testing.T) (repository.MaintenanceSettingsRepository, sqlmock.Sqlmock, func()) {
+	db, mock, err := sqlmock.New()
+	require.NoError(t, err)
+
+	return repository.NewMaintenanceSettingsRepository(&database.Pool{DB: db}), mock, func() { db.Close() }
+}
+
+func TestMaintenanceSettingsRepository_List(t *testing.T) {
+	repo, mock, cleanup := setupMaintenanceSettingsTest(t)
+	defer cleanup()
+	now := time.Now()
+
+	mock.ExpectQuery("SELECT task_name, enabled, interval_seconds, batch_size, dry_run, updated_by, updated_at FROM maintenance_task_settings").
+		WillReturnRows(sqlmock.NewRows([]string{"task_name", "enabled", "interval_seconds", "batch_size", "dry_run", "updated_by", "updated_at"}).
+			AddRow("expired_sessions", true, 900, 1000, false, int64(1), now).
+			AddRow("gdpr_logs", false, 3600, 0, false, int64(1), now))
+
+	settings, err := repo.List(context.Background())
+	require.NoError(t, err)
+	require.Len(t, settings, 2)
+	assert.Equal(t, "expired_sessions", settings[0].Task)
+	assert.Equal(t, 900, settings[0].IntervalSeconds)
+	assert.Equal(t, 1000, settings[0].BatchSize)
+	assert.False(t, settings[1].Enabled)
+	assert.Equal(t, int64(1), *settings[1].UpdatedBy)
+
+	mock.ExpectQuery("SELECT task_name").
+		WillReturnError(errors.New("connection reset"))
+
+	_, err = repo.List(context.Background())
+	assert.Error(t, err)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestMaintenanceSettingsRepository_Upsert(t *testing.T) {
+	repo, mock, cleanup := setupMaintenanceSettingsTest(t)
+	defer cleanup()
+	adminID := int64(1)
+	now := time.Now()
+	settings := &models.MaintenanceTaskSettings{
+		Task:            "document_retention",
+		Enabled:         true,
+		IntervalSeconds: 86400,
+		BatchSize:       500,
+		DryRun:          true,
+		UpdatedBy:       &adminID,
+		UpdatedAt:       &now,
+	}
+
+	mock.ExpectExec("INSERT INTO maintenance_task_settings .* ON CONFLICT \\(task_name\\) DO UPDATE").
+		WithArgs("document_retention", true, 86400, 500, true, &adminID, &now).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+
+	require.NoError(t, repo.Upsert(context.Background(), settings))
+
+	mock.ExpectExec("INSERT INTO maintenance_task_settings").
+		WillReturnError(errors.New("connection reset"))
+
+	assert.Error(t, repo.Upsert(context.Background(), settings))
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
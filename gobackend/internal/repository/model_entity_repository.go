@@ -14,6 +14,7 @@ import (
 	"fmt"
 	"time"
 
+	"github.com/lib/pq"
 	"github.com/rs/zerolog/log"
 
 	"github.com/yasinhessnawi1/Hideme_Backend/internal/constants"
@@ -90,6 +91,19 @@ type ModelEntityRepository interface {
 	//   - An error if retrieval fails
 	GetBySettingIDAndMethodID(ctx context.Context, settingID, methodID int64) ([]*models.ModelEntityWithMethod, error)
 
+	// StreamBySettingIDAndMethodID passes the model entities for specific user settings and
+	// detection method to fn one at a time, as they are scanned.
+	//
+	// Parameters:
+	//   - ctx: Context for transaction and cancellation control
+	//   - settingID: The unique identifier of the user settings
+	//   - methodID: The unique identifier of the detection method
+	//   - fn: Called for each entity; returning an error stops the scan
+	//
+	// Returns:
+	//   - The error returned by fn, or an error if retrieval fails
+	StreamBySettingIDAndMethodID(ctx context.Context, settingID, methodID int64, fn func(*models.ModelEntityWithMethod) error) error
+
 	// Update updates a model entity in the database.
 	//
 	// Parameters:
@@ -134,6 +148,21 @@ type ModelEntityRepository interface {
 	//   - An error if deletion fails
 	//   - nil if deletion succeeds
 	DeleteByMethodID(ctx context.Context, settingID, methodID int64) error
+
+	// ReplaceByMethodID replaces all model entities for specific user settings and detection
+	// method with a new list in one transaction. Entities whose text is on both lists are
+	// kept as they are, so only the difference is deleted and created.
+	//
+	// Parameters:
+	//   - ctx: Context for transaction and cancellation control
+	//   - settingID: The unique identifier of the user settings
+	//   - methodID: The unique identifier of the detection method
+	//   - entityTexts: The texts of the entities after the replacement; duplicates are ignored
+	//
+	// Returns:
+	//   - The entities after the replacement and those created and deleted
+	//   - An error if the replacement fails; nothing is changed then
+	ReplaceByMethodID(ctx context.Context, settingID, methodID int64, entityTexts []string) (*models.ModelEntityReplacement, error)
 }
 
 // PostgresModelEntityRepository is a PostgreSQL implementation of ModelEntityRepository.
@@ -308,7 +337,7 @@ func (r *PostgresModelEntityRepository) GetByID(ctx context.Context, id int64) (
 
 	if err != nil {
 		if errors.Is(err, sql.ErrNoRows) {
-			return nil, utils.NewNotFoundError("ModelEntity", id)
+			return nil, utils.NewNotFoundError("ModelEntity", id).WithCause(err)
 		}
 		return nil, fmt.Errorf("failed to get model entity by ID: %w", err)
 	}
@@ -393,6 +422,30 @@ func (r *PostgresModelEntityRepository) GetBySettingID(ctx context.Context, sett
 //   - An empty slice if no entities exist
 //   - An error if retrieval fails
 func (r *PostgresModelEntityRepository) GetBySettingIDAndMethodID(ctx context.Context, settingID, methodID int64) ([]*models.ModelEntityWithMethod, error) {
+	var entities []*models.ModelEntityWithMethod
+	err := r.StreamBySettingIDAndMethodID(ctx, settingID, methodID, func(entity *models.ModelEntityWithMethod) error {
+		entities = append(entities, entity)
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return entities, nil
+}
+
+// StreamBySettingIDAndMethodID passes the model entities for a setting and method to fn
+// one at a time, as they are scanned, so large lists never have to be held in memory.
+//
+// Parameters:
+//   - ctx: Context for transaction and cancellation control
+//   - settingID: The unique identifier of the user settings
+//   - methodID: The unique identifier of the detection method
+//   - fn: Called for each entity; returning an error stops the scan
+//
+// Returns:
+//   - The error returned by fn, or an error if retrieval fails
+func (r *PostgresModelEntityRepository) StreamBySettingIDAndMethodID(ctx context.Context, settingID, methodID int64, fn func(*models.ModelEntityWithMethod) error) error {
 	// Start query timer
 	startTime := time.Now()
 
@@ -417,7 +470,7 @@ func (r *PostgresModelEntityRepository) GetBySettingIDAndMethodID(ctx context.Co
 	)
 
 	if err != nil {
-		return nil, fmt.Errorf("failed to get model entities by setting ID and method ID: %w", err)
+		return fmt.Errorf("failed to get model entities by setting ID and method ID: %w", err)
 	}
 	defer func() {
 		if closeErr := rows.Close(); closeErr != nil {
@@ -425,9 +478,12 @@ func (r *PostgresModelEntityRepository) GetBySettingIDAndMethodID(ctx context.Co
 		}
 	}()
 
-	// Parse the results
-	var entities []*models.ModelEntityWithMethod
+	// Pass each row on as soon as it is scanned, stopping once the caller goes away
 	for rows.Next() {
+		if err := checkContext(ctx); err != nil {
+			return fmt.Errorf("model entity stream stopped: %w", err)
+		}
+
 		entity := &models.ModelEntityWithMethod{
 			ModelEntity: models.ModelEntity{},
 		}
@@ -438,16 +494,18 @@ func (r *PostgresModelEntityRepository) GetBySettingIDAndMethodID(ctx context.Co
 			&entity.EntityText,
 			&entity.MethodName,
 		); err != nil {
-			return nil, fmt.Errorf("failed to scan model entity row: %w", err)
+			return fmt.Errorf("failed to scan model entity row: %w", err)
+		}
+		if err := fn(entity); err != nil {
+			return err
 		}
-		entities = append(entities, entity)
 	}
 
 	if err := rows.Err(); err != nil {
-		return nil, fmt.Errorf("error iterating model entity rows: %w", err)
+		return fmt.Errorf("error iterating model entity rows: %w", err)
 	}
 
-	return entities, nil
+	return nil
 }
 
 // Update updates a model entity in the database.
@@ -644,3 +702,134 @@ func (r *PostgresModelEntityRepository) DeleteByMethodID(ctx context.Context, se
 
 	return nil
 }
+
+// ReplaceByMethodID replaces all model entities for specific user settings and detection
+// method with a new list in one transaction. The user settings row is locked for the
+// transaction, so replacements of the same user's entities run one after the other.
+//
+// Parameters:
+//   - ctx: Context for transaction and cancellation control
+//   - settingID: The unique identifier of the user settings
+//   - methodID: The unique identifier of the detection method
+//   - entityTexts: The texts of the entities after the replacement; duplicates are ignored
+//
+// Returns:
+//   - The entities after the replacement and those created and deleted
+//   - An error if the replacement fails; nothing is changed then
+func (r *PostgresModelEntityRepository) ReplaceByMethodID(ctx context.Context, settingID, methodID int64, entityTexts []string) (*models.ModelEntityReplacement, error) {
+	// Start query timer
+	startTime := time.Now()
+
+	replacement := &models.ModelEntityReplacement{
+		Entities: []*models.ModelEntity{},
+		Created:  []*models.ModelEntity{},
+		Deleted:  []*models.ModelEntity{},
+	}
+
+	err := r.db.Transaction(ctx, func(tx *sql.Tx) error {
+		// Serialize replacements of the same user's entities
+		lockQuery := `SELECT ` + constants.ColumnSettingID + ` FROM ` + constants.TableUserSettings + ` WHERE ` + constants.ColumnSettingID + ` = $1 FOR UPDATE`
+		var lockedID int64
+		if err := tx.QueryRowContext(ctx, lockQuery, settingID).Scan(&lockedID); err != nil {
+			if errors.Is(err, sql.ErrNoRows) {
+				return utils.NewNotFoundError("UserSetting", settingID)
+			}
+			return fmt.Errorf("failed to lock user settings: %w", err)
+		}
+
+		// Read the current entities of the method
+		selectQuery := `
+            SELECT model_entity_id, setting_id, method_id, entity_text
+            FROM model_entities
+            WHERE setting_id = $1 AND method_id = $2
+            ORDER BY model_entity_id
+        `
+		rows, err := tx.QueryContext(ctx, selectQuery, settingID, methodID)
+		if err != nil {
+			return fmt.Errorf("failed to get model entities: %w", err)
+		}
+		var current []*models.ModelEntity
+		existing := make(map[string]*models.ModelEntity)
+		for rows.Next() {
+			entity := &models.ModelEntity{}
+			if err := rows.Scan(&entity.ID, &entity.SettingID, &entity.MethodID, &entity.EntityText); err != nil {
+				rows.Close()
+				return fmt.Errorf("failed to scan model entity: %w", err)
+			}
+			current = append(current, entity)
+			if _, ok := existing[entity.EntityText]; !ok {
+				existing[entity.EntityText] = entity
+			}
+		}
+		if err := rows.Err(); err != nil {
+			rows.Close()
+			return fmt.Errorf("error iterating model entity rows: %w", err)
+		}
+		rows.Close()
+
+		// Keep the entities still listed and create the new ones
+		insertQuery := `
+            INSERT INTO model_entities (setting_id, method_id, entity_text)
+            VALUES ($1, $2, $3)
+            RETURNING model_entity_id
+        `
+		listed := make(map[string]bool, len(entityTexts))
+		for _, text := range entityTexts {
+			if listed[text] {
+				continue
+			}
+			listed[text] = true
+
+			if entity, ok := existing[text]; ok {
+				replacement.Entities = append(replacement.Entities, entity)
+				continue
+			}
+			entity := models.NewModelEntity(settingID, methodID, text)
+			if err := tx.QueryRowContext(ctx, insertQuery, settingID, methodID, text).Scan(&entity.ID); err != nil {
+				return fmt.Errorf("failed to create model entity: %w", err)
+			}
+			replacement.Entities = append(replacement.Entities, entity)
+			replacement.Created = append(replacement.Created, entity)
+		}
+
+		// Delete the entities no longer listed and duplicates of those kept
+		for _, entity := range current {
+			if !listed[entity.EntityText] || existing[entity.EntityText] != entity {
+				replacement.Deleted = append(replacement.Deleted, entity)
+			}
+		}
+		if len(replacement.Deleted) > 0 {
+			ids := make([]int64, len(replacement.Deleted))
+			for i, entity := range replacement.Deleted {
+				ids[i] = entity.ID
+			}
+			deleteQuery := `DELETE FROM model_entities WHERE model_entity_id = ANY($1)`
+			if _, err := tx.ExecContext(ctx, deleteQuery, pq.Array(ids)); err != nil {
+				return fmt.Errorf("failed to delete model entities: %w", err)
+			}
+		}
+
+		return nil
+	})
+
+	// Log the operation
+	utils.LogDBQuery(
+		fmt.Sprintf("Replaced model entities: %d created, %d deleted", len(replacement.Created), len(replacement.Deleted)),
+		[]interface{}{settingID, methodID},
+		time.Since(startTime),
+		err,
+	)
+
+	if err != nil {
+		return nil, err
+	}
+
+	log.Info().
+		Int64(constants.ColumnSettingID, settingID).
+		Int64(constants.ColumnMethodID, methodID).
+		Int("created", len(replacement.Created)).
+		Int("deleted", len(replacement.Deleted)).
+		Msg("Model entities replaced for setting and method")
+
+	return replacement, nil
+}
@@ -13,6 +13,7 @@ import (
 	"github.com/yasinhessnawi1/Hideme_Backend/internal/database"
 	"github.com/yasinhessnawi1/Hideme_Backend/internal/models"
 	"github.com/yasinhessnawi1/Hideme_Backend/internal/repository"
+	"github.com/yasinhessnawi1/Hideme_Backend/internal/utils"
 )
 
 // setupModelEntityRepositoryTest creates a new test database connection and mock
@@ -572,6 +573,71 @@ func TestModelEntityRepository_GetBySettingIDAndMethodID_RowsError(t *testing.T)
 	assert.NoError(t, mock.ExpectationsWereMet())
 }
 
+func TestModelEntityRepository_StreamBySettingIDAndMethodID_CallbackError(t *testing.T) {
+	// Set up the test
+	repo, mock, cleanup := setupModelEntityRepositoryTest(t)
+	defer cleanup()
+
+	// Set up test data
+	settingID := int64(100)
+	methodID := int64(5)
+
+	rows := sqlmock.NewRows([]string{"model_entity_id", "setting_id", "method_id", "entity_text", "method_name"}).
+		AddRow(1, settingID, methodID, "Credit Card", "Regex Search").
+		AddRow(2, settingID, methodID, "SSN", "Regex Search")
+
+	mock.ExpectQuery("SELECT me.model_entity_id, me.setting_id, me.method_id, me.entity_text, dm.method_name FROM model_entities me JOIN detection_methods dm ON me.method_id = dm.method_id WHERE me.setting_id = \\$1 AND me.method_id = \\$2 ORDER BY me.entity_text").
+		WithArgs(settingID, methodID).
+		WillReturnRows(rows)
+
+	// Execute the method being tested; the scan stops at the first callback error
+	callbackErr := errors.New("client went away")
+	var received []string
+	err := repo.StreamBySettingIDAndMethodID(context.Background(), settingID, methodID, func(entity *models.ModelEntityWithMethod) error {
+		received = append(received, entity.EntityText)
+		return callbackErr
+	})
+
+	// Assert the results
+	assert.ErrorIs(t, err, callbackErr)
+	assert.Equal(t, []string{"Credit Card"}, received)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestModelEntityRepository_StreamBySettingIDAndMethodID_ContextCanceled(t *testing.T) {
+	// Set up the test
+	repo, mock, cleanup := setupModelEntityRepositoryTest(t)
+	defer cleanup()
+
+	// Set up test data
+	settingID := int64(100)
+	methodID := int64(5)
+
+	rows := sqlmock.NewRows([]string{"model_entity_id", "setting_id", "method_id", "entity_text", "method_name"}).
+		AddRow(1, settingID, methodID, "Credit Card", "Regex Search").
+		AddRow(2, settingID, methodID, "SSN", "Regex Search")
+
+	mock.ExpectQuery("SELECT me.model_entity_id, me.setting_id, me.method_id, me.entity_text, dm.method_name FROM model_entities me JOIN detection_methods dm ON me.method_id = dm.method_id WHERE me.setting_id = \\$1 AND me.method_id = \\$2 ORDER BY me.entity_text").
+		WithArgs(settingID, methodID).
+		WillReturnRows(rows).
+		RowsWillBeClosed()
+
+	// Execute the method being tested; the client goes away after the first entity
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	var received []string
+	err := repo.StreamBySettingIDAndMethodID(ctx, settingID, methodID, func(entity *models.ModelEntityWithMethod) error {
+		received = append(received, entity.EntityText)
+		cancel()
+		return nil
+	})
+
+	// Assert the results
+	assert.ErrorIs(t, err, context.Canceled)
+	assert.Equal(t, []string{"Credit Card"}, received)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
 func TestModelEntityRepository_Update(t *testing.T) {
 	// Set up the test
 	repo, mock, cleanup := setupModelEntityRepositoryTest(t)
@@ -857,3 +923,109 @@ func TestModelEntityRepository_DeleteByMethodID_Error(t *testing.T) {
 	assert.Contains(t, err.Error(), "failed to delete model entities by setting ID and method ID")
 	assert.NoError(t, mock.ExpectationsWereMet())
 }
+
+func TestModelEntityRepository_ReplaceByMethodID(t *testing.T) {
+	settingID := int64(100)
+	methodID := int64(5)
+	columns := []string{"model_entity_id", "setting_id", "method_id", "entity_text"}
+
+	t.Run("Success", func(t *testing.T) {
+		repo, mock, cleanup := setupModelEntityRepositoryTest(t)
+		defer cleanup()
+
+		mock.ExpectBegin()
+		mock.ExpectQuery("SELECT setting_id FROM user_settings WHERE setting_id = \\$1 FOR UPDATE").
+			WithArgs(settingID).
+			WillReturnRows(sqlmock.NewRows([]string{"setting_id"}).AddRow(settingID))
+		mock.ExpectQuery("SELECT model_entity_id, setting_id, method_id, entity_text FROM model_entities").
+			WithArgs(settingID, methodID).
+			WillReturnRows(sqlmock.NewRows(columns).
+				AddRow(1, settingID, methodID, "John Doe").
+				AddRow(2, settingID, methodID, "Oslo").
+				AddRow(3, settingID, methodID, "John Doe"))
+		mock.ExpectQuery("INSERT INTO model_entities").
+			WithArgs(settingID, methodID, "Bergen").
+			WillReturnRows(sqlmock.NewRows([]string{"model_entity_id"}).AddRow(4))
+		mock.ExpectExec("DELETE FROM model_entities WHERE model_entity_id = ANY").
+			WithArgs(sqlmock.AnyArg()).
+			WillReturnResult(sqlmock.NewResult(0, 2))
+		mock.ExpectCommit()
+
+		replacement, err := repo.ReplaceByMethodID(context.Background(), settingID, methodID, []string{"Bergen", "John Doe", "Bergen"})
+
+		require.NoError(t, err)
+		assert.Equal(t, []*models.ModelEntity{
+			{ID: 4, SettingID: settingID, MethodID: methodID, EntityText: "Bergen"},
+			{ID: 1, SettingID: settingID, MethodID: methodID, EntityText: "John Doe"},
+		}, replacement.Entities)
+		assert.Equal(t, []*models.ModelEntity{
+			{ID: 4, SettingID: settingID, MethodID: methodID, EntityText: "Bergen"},
+		}, replacement.Created)
+		assert.Equal(t, []*models.ModelEntity{
+			{ID: 2, SettingID: settingID, MethodID: methodID, EntityText: "Oslo"},
+			{ID: 3, SettingID: settingID, MethodID: methodID, EntityText: "John Doe"},
+		}, replacement.Deleted)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("Unchanged list", func(t *testing.T) {
+		repo, mock, cleanup := setupModelEntityRepositoryTest(t)
+		defer cleanup()
+
+		mock.ExpectBegin()
+		mock.ExpectQuery("SELECT setting_id FROM user_settings").
+			WithArgs(settingID).
+			WillReturnRows(sqlmock.NewRows([]string{"setting_id"}).AddRow(settingID))
+		mock.ExpectQuery("SELECT model_entity_id, setting_id, method_id, entity_text FROM model_entities").
+			WithArgs(settingID, methodID).
+			WillReturnRows(sqlmock.NewRows(columns).AddRow(1, settingID, methodID, "John Doe"))
+		mock.ExpectCommit()
+
+		replacement, err := repo.ReplaceByMethodID(context.Background(), settingID, methodID, []string{"John Doe"})
+
+		require.NoError(t, err)
+		assert.Len(t, replacement.Entities, 1)
+		assert.Empty(t, replacement.Created)
+		assert.Empty(t, replacement.Deleted)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("Settings not found", func(t *testing.T) {
+		repo, mock, cleanup := setupModelEntityRepositoryTest(t)
+		defer cleanup()
+
+		mock.ExpectBegin()
+		mock.ExpectQuery("SELECT setting_id FROM user_settings").
+			WithArgs(settingID).
+			WillReturnError(sql.ErrNoRows)
+		mock.ExpectRollback()
+
+		_, err := repo.ReplaceByMethodID(context.Background(), settingID, methodID, []string{"John Doe"})
+
+		assert.True(t, errors.Is(err, utils.ErrNotFound))
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("Insert error rolls back", func(t *testing.T) {
+		repo, mock, cleanup := setupModelEntityRepositoryTest(t)
+		defer cleanup()
+
+		mock.ExpectBegin()
+		mock.ExpectQuery("SELECT setting_id FROM user_settings").
+			WithArgs(settingID).
+			WillReturnRows(sqlmock.NewRows([]string{"setting_id"}).AddRow(settingID))
+		mock.ExpectQuery("SELECT model_entity_id, setting_id, method_id, entity_text FROM model_entities").
+			WithArgs(settingID, methodID).
+			WillReturnRows(sqlmock.NewRows(columns).AddRow(1, settingID, methodID, "Oslo"))
+		mock.ExpectQuery("INSERT INTO model_entities").
+			WithArgs(settingID, methodID, "Bergen").
+			WillReturnError(errors.New("database error"))
+		mock.ExpectRollback()
+
+		_, err := repo.ReplaceByMethodID(context.Background(), settingID, methodID, []string{"Bergen"})
+
+		assert.Error(t, err)
+		assert.Contains(t, err.Error(), "failed to create model entity")
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+}
@@ -13,10 +13,12 @@ import (
 	"github.com/yasinhessnawi1/Hideme_Backend/internal/constants"
 	"github.com/yasinhessnawi1/Hideme_Backend/internal/database"
 	_ "github.com/yasinhessnawi1/Hideme_Backend/internal/models"
+	"github.com/yasinhessnawi1/Hideme_Backend/internal/utils"
 )
 
 var (
-	ErrTokenNotFound = errors.New("token not found or expired")
+	// ErrTokenNotFound is a not found error, returned when a reset token is unknown, expired or used.
+	ErrTokenNotFound = utils.NewKindError(utils.ErrNotFound, "token not found or expired")
 )
 
 // PasswordResetRepository handles database operations for password reset tokens.
@@ -82,6 +84,31 @@ func (r *PasswordResetRepository) GetUserIDByTokenHash(ctx context.Context, toke
 	return userID, expiresAt, nil
 }
 
+// Consume removes a password reset token and returns the user ID and expiry it had.
+// The token is deleted in the same statement that reads it, so it can be used only
+// once even by concurrent requests; expired tokens are consumed as well, and the
+// caller must check the expiry.
+// It returns ErrTokenNotFound if the token doesn't exist or was already used.
+func (r *PasswordResetRepository) Consume(ctx context.Context, tokenHash string) (int64, time.Time, error) {
+	var userID int64
+	var expiresAt time.Time
+	query := fmt.Sprintf(`
+		DELETE FROM %s
+		WHERE token_hash = $1
+		RETURNING user_id, expires_at
+	`, constants.TablePasswordResetTokens)
+
+	err := r.db.QueryRowContext(ctx, query, tokenHash).Scan(&userID, &expiresAt)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return 0, time.Time{}, ErrTokenNotFound
+		}
+		return 0, time.Time{}, fmt.Errorf("failed to consume password reset token: %w", err)
+	}
+
+	return userID, expiresAt, nil
+}
+
 // Delete removes a password reset token hash from the database.
 func (r *PasswordResetRepository) Delete(ctx context.Context, tokenHash string) error {
 	query := fmt.Sprintf("DELETE FROM %s WHERE token_hash = $1", constants.TablePasswordResetTokens)
@@ -213,7 +213,7 @@ func (r *PostgresPatternRepository) GetByID(ctx context.Context, id int64) (*mod
 
 	if err != nil {
 		if errors.Is(err, sql.ErrNoRows) {
-			return nil, utils.NewNotFoundError("SearchPattern", id)
+			return nil, utils.NewNotFoundError("SearchPattern", id).WithCause(err)
 		}
 		return nil, fmt.Errorf("failed to get search pattern by ID: %w", err)
 	}
@@ -0,0 +1,80 @@
+// Package repository provides data access interfaces and implementations for the HideMe application.
+// It follows the repository pattern to abstract database operations and provide a clean API
+// for data persistence operations.
+//
+// This file implements the audit stubs of documents processed in ephemeral mode.
+package repository
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/yasinhessnawi1/Hideme_Backend/internal/constants"
+	"github.com/yasinhessnawi1/Hideme_Backend/internal/database"
+	"github.com/yasinhessnawi1/Hideme_Backend/internal/models"
+	"github.com/yasinhessnawi1/Hideme_Backend/internal/utils"
+)
+
+// ProcessingAuditRepository defines methods for recording documents processed in ephemeral mode.
+type ProcessingAuditRepository interface {
+	// Create records that a document was processed in ephemeral mode.
+	//
+	// Parameters:
+	//   - ctx: Context for transaction and cancellation control
+	//   - audit: The audit stub; its ID and ProcessedAt are set on success
+	//
+	// Returns:
+	//   - An error if the stub can't be stored
+	Create(ctx context.Context, audit *models.ProcessingAudit) error
+}
+
+// PostgresProcessingAuditRepository is a PostgreSQL implementation of ProcessingAuditRepository.
+type PostgresProcessingAuditRepository struct {
+	db *database.Pool
+}
+
+// NewProcessingAuditRepository creates a new ProcessingAuditRepository implementation for PostgreSQL.
+//
+// Parameters:
+//   - db: A connection pool for PostgreSQL database access
+//
+// Returns:
+//   - An implementation of the ProcessingAuditRepository interface
+func NewProcessingAuditRepository(db *database.Pool) ProcessingAuditRepository {
+	return &PostgresProcessingAuditRepository{
+		db: db,
+	}
+}
+
+// Create records that a document was processed in ephemeral mode.
+func (r *PostgresProcessingAuditRepository) Create(ctx context.Context, audit *models.ProcessingAudit) error {
+	// Start query timer
+	startTime := time.Now()
+
+	// Define the query
+	query := `
+        INSERT INTO ` + constants.TableProcessingAudits + ` (` + constants.ColumnUserID + `, page_count, entity_count, processed_at)
+        VALUES ($1, $2, $3, $4)
+        RETURNING audit_id
+    `
+	now := time.Now()
+
+	// Execute the query
+	err := r.db.QueryRowContext(ctx, query, audit.UserID, audit.PageCount, audit.EntityCount, now).Scan(&audit.ID)
+
+	// Log the query execution
+	utils.LogDBQuery(
+		query,
+		[]interface{}{audit.UserID, audit.PageCount, audit.EntityCount, now},
+		time.Since(startTime),
+		err,
+	)
+
+	if err != nil {
+		return fmt.Errorf("failed to create processing audit: %w", err)
+	}
+	audit.ProcessedAt = now
+
+	return nil
+}
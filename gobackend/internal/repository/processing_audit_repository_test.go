@@ -0,0 +1,45 @@
+package repository_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/yasinhessnawi1/Hideme_Backend/internal/database"
+	"github.com/yasinhessnawi1/Hideme_Backend/internal/models"
+	"github.com/yasinhessnawi1/Hideme_Backend/internal/repository"
+)
+
+func TestProcessingAuditRepository_Create(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	require.NoError(t, err)
+	defer db.Close()
+	repo := repository.NewProcessingAuditRepository(&database.Pool{DB: db})
+
+	t.Run("Success", func(t *testing.T) {
+		mock.ExpectQuery("INSERT INTO processing_audits \\(user_id, page_count, entity_count, processed_at\\)").
+			WithArgs(int64(1), 3, 12, sqlmock.AnyArg()).
+			WillReturnRows(sqlmock.NewRows([]string{"audit_id"}).AddRow(7))
+
+		audit := &models.ProcessingAudit{UserID: 1, PageCount: 3, EntityCount: 12}
+		require.NoError(t, repo.Create(context.Background(), audit))
+
+		assert.Equal(t, int64(7), audit.ID)
+		assert.False(t, audit.ProcessedAt.IsZero())
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("Error", func(t *testing.T) {
+		mock.ExpectQuery("INSERT INTO processing_audits").WillReturnError(errors.New("database error"))
+
+		err := repo.Create(context.Background(), &models.ProcessingAudit{UserID: 1})
+
+		assert.Error(t, err)
+		assert.Contains(t, err.Error(), "failed to create processing audit")
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+}
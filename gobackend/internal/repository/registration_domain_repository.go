@@ -0,0 +1,364 @@
+// Package repository provides data access interfaces and implementations for the HideMe application.
+// It follows the repository pattern to abstract database operations and provide a clean API
+// for data persistence operations.
+//
+// This file implements the registration domain repository, which stores the email domains
+// registration is limited to.
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/lib/pq"
+
+	"github.com/yasinhessnawi1/Hideme_Backend/internal/constants"
+	"github.com/yasinhessnawi1/Hideme_Backend/internal/database"
+	"github.com/yasinhessnawi1/Hideme_Backend/internal/models"
+	"github.com/yasinhessnawi1/Hideme_Backend/internal/utils"
+)
+
+// RegistrationDomainRepository defines methods for managing the email domains registration is limited to.
+type RegistrationDomainRepository interface {
+	// Create stores a new, unverified domain.
+	//
+	// Parameters:
+	//   - ctx: Context for transaction and cancellation control
+	//   - domain: The domain to store; its ID and creation time are set on success
+	//
+	// Returns:
+	//   - DuplicateError if the domain was already added
+	//   - Other errors for database issues
+	Create(ctx context.Context, domain *models.RegistrationDomain) error
+
+	// GetByID retrieves a domain by its ID.
+	//
+	// Parameters:
+	//   - ctx: Context for transaction and cancellation control
+	//   - id: The ID of the domain
+	//
+	// Returns:
+	//   - The domain
+	//   - NotFoundError if the domain doesn't exist
+	//   - Other errors for database issues
+	GetByID(ctx context.Context, id int64) (*models.RegistrationDomain, error)
+
+	// List retrieves all domains, verified or not, by name.
+	//
+	// Parameters:
+	//   - ctx: Context for transaction and cancellation control
+	//
+	// Returns:
+	//   - The domains (empty if there are none)
+	//   - An error for database issues
+	List(ctx context.Context) ([]*models.RegistrationDomain, error)
+
+	// ListVerified retrieves the names of the verified domains, which registration is limited to.
+	//
+	// Parameters:
+	//   - ctx: Context for transaction and cancellation control
+	//
+	// Returns:
+	//   - The domain names (empty if registration is open to all domains)
+	//   - An error for database issues
+	ListVerified(ctx context.Context) ([]string, error)
+
+	// MarkVerified records that the verification record of a domain was found.
+	//
+	// Parameters:
+	//   - ctx: Context for transaction and cancellation control
+	//   - id: The ID of the domain
+	//   - verifiedAt: When the record was found
+	//
+	// Returns:
+	//   - NotFoundError if the domain doesn't exist
+	//   - Other errors for database issues
+	MarkVerified(ctx context.Context, id int64, verifiedAt time.Time) error
+
+	// Delete removes a domain.
+	//
+	// Parameters:
+	//   - ctx: Context for transaction and cancellation control
+	//   - id: The ID of the domain
+	//
+	// Returns:
+	//   - NotFoundError if the domain doesn't exist
+	//   - Other errors for database issues
+	Delete(ctx context.Context, id int64) error
+}
+
+// PostgresRegistrationDomainRepository is a PostgreSQL implementation of RegistrationDomainRepository.
+type PostgresRegistrationDomainRepository struct {
+	db *database.Pool
+}
+
+// NewRegistrationDomainRepository creates a new RegistrationDomainRepository implementation for PostgreSQL.
+//
+// Parameters:
+//   - db: A connection pool for PostgreSQL database access
+//
+// Returns:
+//   - An implementation of the RegistrationDomainRepository interface
+func NewRegistrationDomainRepository(db *database.Pool) RegistrationDomainRepository {
+	return &PostgresRegistrationDomainRepository{
+		db: db,
+	}
+}
+
+// registrationDomainColumns is the column list shared by all domain queries.
+const registrationDomainColumns = `domain_id, domain, verification_token, verified_at, created_by, created_at`
+
+// scanRegistrationDomain scans the registrationDomainColumns of a row.
+//
+// Parameters:
+//   - scanner: The row or rows to scan from
+//
+// Returns:
+//   - The scanned domain
+//   - An error if scanning fails
+func scanRegistrationDomain(scanner interface{ Scan(dest ...any) error }) (*models.RegistrationDomain, error) {
+	domain := &models.RegistrationDomain{}
+	err := scanner.Scan(
+		&domain.ID,
+		&domain.Domain,
+		&domain.VerificationToken,
+		&domain.VerifiedAt,
+		&domain.CreatedBy,
+		&domain.CreatedAt,
+	)
+	return domain, err
+}
+
+// Create stores a new, unverified domain.
+func (r *PostgresRegistrationDomainRepository) Create(ctx context.Context, domain *models.RegistrationDomain) error {
+	// Start query timer
+	startTime := time.Now()
+
+	// Define the query
+	query := `
+        INSERT INTO ` + constants.TableRegistrationDomains + ` (domain, verification_token, created_by, created_at)
+        VALUES ($1, $2, $3, $4)
+        RETURNING domain_id
+    `
+	now := time.Now()
+
+	// Execute the query
+	err := r.db.QueryRowContext(ctx, query, domain.Domain, domain.VerificationToken, domain.CreatedBy, now).Scan(&domain.ID)
+
+	// Log the query execution; the token is left out
+	utils.LogDBQuery(
+		query,
+		[]interface{}{domain.Domain, domain.CreatedBy, now},
+		time.Since(startTime),
+		err,
+	)
+
+	if err != nil {
+		var pqErr *pq.Error
+		if errors.As(err, &pqErr) && pqErr.Code == constants.PGErrorDuplicateConstraint {
+			return utils.NewDuplicateError("RegistrationDomain", "domain", domain.Domain).WithCause(err)
+		}
+		return fmt.Errorf("failed to create registration domain: %w", err)
+	}
+	domain.CreatedAt = now
+
+	return nil
+}
+
+// GetByID retrieves a domain by its ID.
+func (r *PostgresRegistrationDomainRepository) GetByID(ctx context.Context, id int64) (*models.RegistrationDomain, error) {
+	// Start query timer
+	startTime := time.Now()
+
+	// Define the query
+	query := `
+        SELECT ` + registrationDomainColumns + `
+        FROM ` + constants.TableRegistrationDomains + `
+        WHERE domain_id = $1
+    `
+
+	// Execute the query
+	domain, err := scanRegistrationDomain(r.db.QueryRowContext(ctx, query, id))
+
+	// Log the query execution
+	utils.LogDBQuery(
+		query,
+		[]interface{}{id},
+		time.Since(startTime),
+		err,
+	)
+
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, utils.NewNotFoundError("RegistrationDomain", id)
+		}
+		return nil, fmt.Errorf("failed to get registration domain: %w", err)
+	}
+
+	return domain, nil
+}
+
+// List retrieves all domains, verified or not, by name.
+func (r *PostgresRegistrationDomainRepository) List(ctx context.Context) ([]*models.RegistrationDomain, error) {
+	// Start query timer
+	startTime := time.Now()
+
+	// Define the query
+	query := `
+        SELECT ` + registrationDomainColumns + `
+        FROM ` + constants.TableRegistrationDomains + `
+        ORDER BY domain
+    `
+
+	// Execute the query
+	rows, err := r.db.QueryContext(ctx, query)
+
+	// Log the query execution
+	utils.LogDBQuery(
+		query,
+		nil,
+		time.Since(startTime),
+		err,
+	)
+
+	if err != nil {
+		return nil, fmt.Errorf("failed to list registration domains: %w", err)
+	}
+	defer rows.Close()
+
+	domains := []*models.RegistrationDomain{}
+	for rows.Next() {
+		domain, err := scanRegistrationDomain(rows)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan registration domain row: %w", err)
+		}
+		domains = append(domains, domain)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating registration domain rows: %w", err)
+	}
+
+	return domains, nil
+}
+
+// ListVerified retrieves the names of the verified domains.
+func (r *PostgresRegistrationDomainRepository) ListVerified(ctx context.Context) ([]string, error) {
+	// Start query timer
+	startTime := time.Now()
+
+	// Define the query
+	query := `
+        SELECT domain
+        FROM ` + constants.TableRegistrationDomains + `
+        WHERE verified_at IS NOT NULL
+        ORDER BY domain
+    `
+
+	// Execute the query
+	rows, err := r.db.QueryContext(ctx, query)
+
+	// Log the query execution
+	utils.LogDBQuery(
+		query,
+		nil,
+		time.Since(startTime),
+		err,
+	)
+
+	if err != nil {
+		return nil, fmt.Errorf("failed to list verified registration domains: %w", err)
+	}
+	defer rows.Close()
+
+	domains := []string{}
+	for rows.Next() {
+		var domain string
+		if err := rows.Scan(&domain); err != nil {
+			return nil, fmt.Errorf("failed to scan registration domain row: %w", err)
+		}
+		domains = append(domains, domain)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating registration domain rows: %w", err)
+	}
+
+	return domains, nil
+}
+
+// MarkVerified records that the verification record of a domain was found.
+func (r *PostgresRegistrationDomainRepository) MarkVerified(ctx context.Context, id int64, verifiedAt time.Time) error {
+	// Start query timer
+	startTime := time.Now()
+
+	// Define the query
+	query := `
+        UPDATE ` + constants.TableRegistrationDomains + `
+        SET verified_at = $1
+        WHERE domain_id = $2
+    `
+
+	// Execute the query
+	result, err := r.db.ExecContext(ctx, query, verifiedAt, id)
+
+	// Log the query execution
+	utils.LogDBQuery(
+		query,
+		[]interface{}{verifiedAt, id},
+		time.Since(startTime),
+		err,
+	)
+
+	if err != nil {
+		return fmt.Errorf("failed to verify registration domain: %w", err)
+	}
+
+	return requireRegistrationDomainAffected(result, id)
+}
+
+// Delete removes a domain.
+func (r *PostgresRegistrationDomainRepository) Delete(ctx context.Context, id int64) error {
+	// Start query timer
+	startTime := time.Now()
+
+	// Define the query
+	query := `
+        DELETE FROM ` + constants.TableRegistrationDomains + `
+        WHERE domain_id = $1
+    `
+
+	// Execute the query
+	result, err := r.db.ExecContext(ctx, query, id)
+
+	// Log the query execution
+	utils.LogDBQuery(
+		query,
+		[]interface{}{id},
+		time.Since(startTime),
+		err,
+	)
+
+	if err != nil {
+		return fmt.Errorf("failed to delete registration domain: %w", err)
+	}
+
+	return requireRegistrationDomainAffected(result, id)
+}
+
+// requireRegistrationDomainAffected returns NotFoundError if a statement changed no domain.
+func requireRegistrationDomainAffected(result sql.Result, id int64) error {
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("failed to get affected rows: %w", err)
+	}
+
+	if rowsAffected == 0 {
+		return utils.NewNotFoundError("RegistrationDomain", id)
+	}
+
+	return nil
+}
@@ -0,0 +1,104 @@
+package repository_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/lib/pq"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/yasinhessnawi1/Hideme_Backend/internal/constants"
+	"github.com/yasinhessnawi1/Hideme_Backend/internal/database"
+	"github.com/yasinhessnawi1/Hideme_Backend/internal/models"
+	"github.com/yasinhessnawi1/Hideme_Backend/internal/repository"
+	"github.com/yasinhessnawi1/Hideme_Backend/internal/utils"
+)
+
+var registrationDomainTestColumns = []string{"domain_id", "domain", "verification_token", "verified_at", "created_by", "created_at"}
+
+func setupRegistrationDomainTest(t *testing.T) (repository.RegistrationDomainRepository, sqlmock.Sqlmock, func()) {
+	db, mock, err := sqlmock.New()
+	require.NoError(t, err)
+
+	return repository.NewRegistrationDomainRepository(&database.Pool{DB: db}), mock, func() { db.Close() }
+}
+
+func TestRegistrationDomainRepository_Create(t *testing.T) {
+	repo, mock, cleanup := setupRegistrationDomainTest(t)
+	defer cleanup()
+	adminID := int64(1)
+
+	mock.ExpectQuery("INSERT INTO registration_domains").
+		WithArgs("example.com", "token", &adminID, sqlmock.AnyArg()).
+		WillReturnRows(sqlmock.NewRows([]string{"domain_id"}).AddRow(5))
+
+	domain := &models.RegistrationDomain{Domain: "example.com", VerificationToken: "token", CreatedBy: &adminID}
+	require.NoError(t, repo.Create(context.Background(), domain))
+	assert.Equal(t, int64(5), domain.ID)
+
+	// Adding a domain twice
+	mock.ExpectQuery("INSERT INTO registration_domains").
+		WillReturnError(&pq.Error{Code: constants.PGErrorDuplicateConstraint})
+
+	err := repo.Create(context.Background(), &models.RegistrationDomain{Domain: "example.com"})
+	var appErr *utils.AppError
+	require.True(t, errors.As(err, &appErr))
+	assert.True(t, errors.Is(appErr.Err, utils.ErrDuplicate))
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestRegistrationDomainRepository_List(t *testing.T) {
+	repo, mock, cleanup := setupRegistrationDomainTest(t)
+	defer cleanup()
+	now := time.Now()
+
+	mock.ExpectQuery("SELECT (.+) FROM registration_domains ORDER BY domain").
+		WillReturnRows(sqlmock.NewRows(registrationDomainTestColumns).
+			AddRow(5, "example.com", "token", now, 1, now).
+			AddRow(6, "example.org", "token2", nil, nil, now))
+
+	domains, err := repo.List(context.Background())
+	require.NoError(t, err)
+	require.Len(t, domains, 2)
+	assert.NotNil(t, domains[0].VerifiedAt)
+	assert.Nil(t, domains[1].VerifiedAt)
+	assert.Nil(t, domains[1].CreatedBy)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestRegistrationDomainRepository_ListVerified(t *testing.T) {
+	repo, mock, cleanup := setupRegistrationDomainTest(t)
+	defer cleanup()
+
+	mock.ExpectQuery("SELECT domain FROM registration_domains WHERE verified_at IS NOT NULL").
+		WillReturnRows(sqlmock.NewRows([]string{"domain"}).AddRow("example.com"))
+
+	domains, err := repo.ListVerified(context.Background())
+	require.NoError(t, err)
+	assert.Equal(t, []string{"example.com"}, domains)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestRegistrationDomainRepository_MarkVerifiedAndDelete(t *testing.T) {
+	repo, mock, cleanup := setupRegistrationDomainTest(t)
+	defer cleanup()
+	now := time.Now()
+
+	mock.ExpectExec("UPDATE registration_domains SET verified_at = \\$1 WHERE domain_id = \\$2").
+		WithArgs(now, int64(5)).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	require.NoError(t, repo.MarkVerified(context.Background(), 5, now))
+
+	mock.ExpectExec("DELETE FROM registration_domains WHERE domain_id = \\$1").
+		WithArgs(int64(6)).
+		WillReturnResult(sqlmock.NewResult(0, 0))
+	err := repo.Delete(context.Background(), 6)
+	var appErr *utils.AppError
+	require.True(t, errors.As(err, &appErr))
+	assert.True(t, errors.Is(appErr.Err, utils.ErrNotFound))
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
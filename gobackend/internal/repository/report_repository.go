@@ -0,0 +1,511 @@
+// Package repository provides data access interfaces and implementations for the HideMe application.
+// It follows the repository pattern to abstract database operations and provide a clean API
+// for data persistence operations.
+//
+// This file implements the report repository, which stores users' subscriptions to
+// scheduled reports and queries the data the reports are built from.
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/lib/pq"
+
+	"github.com/yasinhessnawi1/Hideme_Backend/internal/constants"
+	"github.com/yasinhessnawi1/Hideme_Backend/internal/database"
+	"github.com/yasinhessnawi1/Hideme_Backend/internal/models"
+	"github.com/yasinhessnawi1/Hideme_Backend/internal/utils"
+)
+
+// ReportRepository defines methods for managing report subscriptions and reading report data.
+type ReportRepository interface {
+	// Create stores a new report subscription.
+	//
+	// Parameters:
+	//   - ctx: Context for transaction and cancellation control
+	//   - subscription: The subscription to store; its ID is set on success
+	//
+	// Returns:
+	//   - DuplicateError if the user is already subscribed to the report
+	//   - Other errors for database issues
+	Create(ctx context.Context, subscription *models.ReportSubscription) error
+
+	// GetByUserID retrieves all report subscriptions of a user.
+	//
+	// Parameters:
+	//   - ctx: Context for transaction and cancellation control
+	//   - userID: The ID of the user
+	//
+	// Returns:
+	//   - The subscriptions (empty if there are none)
+	//   - An error for database issues
+	GetByUserID(ctx context.Context, userID int64) ([]*models.ReportSubscription, error)
+
+	// Delete removes a report subscription owned by a user.
+	//
+	// Parameters:
+	//   - ctx: Context for transaction and cancellation control
+	//   - id: The ID of the subscription
+	//   - userID: The ID of the user who owns the subscription
+	//
+	// Returns:
+	//   - NotFoundError if the user has no such subscription
+	//   - Other errors for database issues
+	Delete(ctx context.Context, id int64, userID int64) error
+
+	// GetDue retrieves subscriptions whose next delivery time has passed, with the
+	// recipient's email address and username.
+	//
+	// Parameters:
+	//   - ctx: Context for transaction and cancellation control
+	//   - now: The current time
+	//   - limit: The maximum number of subscriptions to return
+	//
+	// Returns:
+	//   - The due subscriptions, oldest delivery time first
+	//   - An error for database issues
+	GetDue(ctx context.Context, now time.Time, limit int) ([]*models.ReportSubscription, error)
+
+	// MarkSent records that a report was sent and schedules the next delivery.
+	//
+	// Parameters:
+	//   - ctx: Context for transaction and cancellation control
+	//   - id: The ID of the subscription
+	//   - sentAt: When the report was sent
+	//   - nextRunAt: When the report is sent next
+	//
+	// Returns:
+	//   - An error for database issues
+	MarkSent(ctx context.Context, id int64, sentAt, nextRunAt time.Time) error
+
+	// GetEntitySummary counts the entities detected in a user's documents during a period,
+	// grouped by detection method and entity.
+	//
+	// Parameters:
+	//   - ctx: Context for transaction and cancellation control
+	//   - userID: The ID of the user
+	//   - from: The inclusive start of the period
+	//   - to: The exclusive end of the period
+	//
+	// Returns:
+	//   - The summary rows, ordered by method and entity
+	//   - An error for database issues
+	GetEntitySummary(ctx context.Context, userID int64, from, to time.Time) ([]*models.EntitySummary, error)
+
+	// GetUsage retrieves a user's usage rollup for a month.
+	//
+	// Parameters:
+	//   - ctx: Context for transaction and cancellation control
+	//   - userID: The ID of the user
+	//   - month: The first day of the month
+	//
+	// Returns:
+	//   - The rollup, or an empty rollup for the month if none was recorded
+	//   - An error for database issues
+	GetUsage(ctx context.Context, userID int64, month time.Time) (*models.TenantUsage, error)
+}
+
+// PostgresReportRepository is a PostgreSQL implementation of ReportRepository.
+type PostgresReportRepository struct {
+	db *database.Pool
+}
+
+// NewReportRepository creates a new ReportRepository implementation for PostgreSQL.
+//
+// Parameters:
+//   - db: A connection pool for PostgreSQL database access
+//
+// Returns:
+//   - An implementation of the ReportRepository interface
+func NewReportRepository(db *database.Pool) ReportRepository {
+	return &PostgresReportRepository{
+		db: db,
+	}
+}
+
+// reportSubscriptionColumns is the column list shared by all report subscription queries.
+const reportSubscriptionColumns = `rs.subscription_id, rs.user_id, rs.report_type, rs.format, rs.locale, rs.csv_delimiter, rs.next_run_at, rs.last_sent_at, rs.created_at`
+
+// scanReportSubscription scans a single report subscription row.
+//
+// Parameters:
+//   - scanner: The row or rows to scan from
+//   - withRecipient: Whether the row also contains the recipient's email address and username
+//
+// Returns:
+//   - The scanned subscription
+//   - An error if scanning fails
+func scanReportSubscription(scanner interface{ Scan(dest ...any) error }, withRecipient bool) (*models.ReportSubscription, error) {
+	subscription := &models.ReportSubscription{}
+	var lastSentAt sql.NullTime
+	dest := []any{
+		&subscription.ID,
+		&subscription.UserID,
+		&subscription.ReportType,
+		&subscription.Format,
+		&subscription.Locale,
+		&subscription.CSVDelimiter,
+		&subscription.NextRunAt,
+		&lastSentAt,
+		&subscription.CreatedAt,
+	}
+	if withRecipient {
+		dest = append(dest, &subscription.Email, &subscription.Username)
+	}
+	if err := scanner.Scan(dest...); err != nil {
+		return nil, err
+	}
+	if lastSentAt.Valid {
+		subscription.LastSentAt = &lastSentAt.Time
+	}
+	return subscription, nil
+}
+
+// Create stores a new report subscription.
+//
+// Parameters:
+//   - ctx: Context for transaction and cancellation control
+//   - subscription: The subscription to store; its ID is set on success
+//
+// Returns:
+//   - DuplicateError if the user is already subscribed to the report
+//   - Other errors for database issues
+func (r *PostgresReportRepository) Create(ctx context.Context, subscription *models.ReportSubscription) error {
+	// Start query timer
+	startTime := time.Now()
+
+	// Define the query
+	query := `
+        INSERT INTO report_subscriptions (user_id, report_type, format, locale, csv_delimiter, next_run_at, created_at)
+        VALUES ($1, $2, $3, $4, $5, $6, $7)
+        RETURNING subscription_id
+    `
+
+	// Execute the query
+	err := r.db.QueryRowContext(
+		ctx,
+		query,
+		subscription.UserID,
+		subscription.ReportType,
+		subscription.Format,
+		subscription.Locale,
+		subscription.CSVDelimiter,
+		subscription.NextRunAt,
+		subscription.CreatedAt,
+	).Scan(&subscription.ID)
+
+	// Log the query execution
+	utils.LogDBQuery(
+		query,
+		[]interface{}{subscription.UserID, subscription.ReportType, subscription.Format, subscription.Locale, subscription.CSVDelimiter},
+		time.Since(startTime),
+		err,
+	)
+
+	if err != nil {
+		var pqErr *pq.Error
+		if errors.As(err, &pqErr) && pqErr.Code == constants.PGErrorDuplicateConstraint {
+			return utils.NewDuplicateError("ReportSubscription", "report_type", subscription.ReportType).WithCause(err)
+		}
+		return fmt.Errorf("failed to create report subscription: %w", err)
+	}
+
+	return nil
+}
+
+// GetByUserID retrieves all report subscriptions of a user.
+//
+// Parameters:
+//   - ctx: Context for transaction and cancellation control
+//   - userID: The ID of the user
+//
+// Returns:
+//   - The subscriptions (empty if there are none)
+//   - An error for database issues
+func (r *PostgresReportRepository) GetByUserID(ctx context.Context, userID int64) ([]*models.ReportSubscription, error) {
+	// Start query timer
+	startTime := time.Now()
+
+	// Define the query
+	query := `SELECT ` + reportSubscriptionColumns + ` FROM report_subscriptions rs WHERE rs.user_id = $1 ORDER BY rs.subscription_id`
+
+	// Execute the query
+	rows, err := r.db.QueryContext(ctx, query, userID)
+
+	// Log the query execution
+	utils.LogDBQuery(
+		query,
+		[]interface{}{userID},
+		time.Since(startTime),
+		err,
+	)
+
+	if err != nil {
+		return nil, fmt.Errorf("failed to get report subscriptions: %w", err)
+	}
+	defer rows.Close()
+
+	subscriptions := []*models.ReportSubscription{}
+	for rows.Next() {
+		subscription, err := scanReportSubscription(rows, false)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan report subscription row: %w", err)
+		}
+		subscriptions = append(subscriptions, subscription)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating report subscription rows: %w", err)
+	}
+
+	return subscriptions, nil
+}
+
+// Delete removes a report subscription owned by a user.
+//
+// Parameters:
+//   - ctx: Context for transaction and cancellation control
+//   - id: The ID of the subscription
+//   - userID: The ID of the user who owns the subscription
+//
+// Returns:
+//   - NotFoundError if the user has no such subscription
+//   - Other errors for database issues
+func (r *PostgresReportRepository) Delete(ctx context.Context, id int64, userID int64) error {
+	// Start query timer
+	startTime := time.Now()
+
+	// Define the query
+	query := `DELETE FROM report_subscriptions WHERE subscription_id = $1 AND user_id = $2`
+
+	// Execute the query
+	result, err := r.db.ExecContext(ctx, query, id, userID)
+
+	// Log the query execution
+	utils.LogDBQuery(
+		query,
+		[]interface{}{id, userID},
+		time.Since(startTime),
+		err,
+	)
+
+	if err != nil {
+		return fmt.Errorf("failed to delete report subscription: %w", err)
+	}
+
+	// Check if a subscription was deleted
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("failed to get affected rows: %w", err)
+	}
+
+	if rowsAffected == 0 {
+		return utils.NewNotFoundError("ReportSubscription", id)
+	}
+
+	return nil
+}
+
+// GetDue retrieves subscriptions whose next delivery time has passed, with the
+// recipient's email address and username.
+//
+// Parameters:
+//   - ctx: Context for transaction and cancellation control
+//   - now: The current time
+//   - limit: The maximum number of subscriptions to return
+//
+// Returns:
+//   - The due subscriptions, oldest delivery time first
+//   - An error for database issues
+func (r *PostgresReportRepository) GetDue(ctx context.Context, now time.Time, limit int) ([]*models.ReportSubscription, error) {
+	// Start query timer
+	startTime := time.Now()
+
+	// Define the query
+	query := `
+        SELECT ` + reportSubscriptionColumns + `, u.email, u.username
+        FROM report_subscriptions rs
+        JOIN users u ON u.user_id = rs.user_id
+        WHERE rs.next_run_at <= $1
+        ORDER BY rs.next_run_at, rs.subscription_id
+        LIMIT $2
+    `
+
+	// Execute the query
+	rows, err := r.db.QueryContext(ctx, query, now, limit)
+
+	// Log the query execution
+	utils.LogDBQuery(
+		query,
+		[]interface{}{now, limit},
+		time.Since(startTime),
+		err,
+	)
+
+	if err != nil {
+		return nil, fmt.Errorf("failed to get due report subscriptions: %w", err)
+	}
+	defer rows.Close()
+
+	subscriptions := []*models.ReportSubscription{}
+	for rows.Next() {
+		subscription, err := scanReportSubscription(rows, true)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan report subscription row: %w", err)
+		}
+		subscriptions = append(subscriptions, subscription)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating report subscription rows: %w", err)
+	}
+
+	return subscriptions, nil
+}
+
+// MarkSent records that a report was sent and schedules the next delivery.
+//
+// Parameters:
+//   - ctx: Context for transaction and cancellation control
+//   - id: The ID of the subscription
+//   - sentAt: When the report was sent
+//   - nextRunAt: When the report is sent next
+//
+// Returns:
+//   - An error for database issues
+func (r *PostgresReportRepository) MarkSent(ctx context.Context, id int64, sentAt, nextRunAt time.Time) error {
+	// Start query timer
+	startTime := time.Now()
+
+	// Define the query
+	query := `UPDATE report_subscriptions SET last_sent_at = $1, next_run_at = $2 WHERE subscription_id = $3`
+
+	// Execute the query
+	_, err := r.db.ExecContext(ctx, query, sentAt, nextRunAt, id)
+
+	// Log the query execution
+	utils.LogDBQuery(
+		query,
+		[]interface{}{sentAt, nextRunAt, id},
+		time.Since(startTime),
+		err,
+	)
+
+	if err != nil {
+		return fmt.Errorf("failed to mark report subscription as sent: %w", err)
+	}
+
+	return nil
+}
+
+// GetEntitySummary counts the entities detected in a user's documents during a period,
+// grouped by detection method and entity.
+//
+// Parameters:
+//   - ctx: Context for transaction and cancellation control
+//   - userID: The ID of the user
+//   - from: The inclusive start of the period
+//   - to: The exclusive end of the period
+//
+// Returns:
+//   - The summary rows, ordered by method and entity
+//   - An error for database issues
+func (r *PostgresReportRepository) GetEntitySummary(ctx context.Context, userID int64, from, to time.Time) ([]*models.EntitySummary, error) {
+	// Start query timer
+	startTime := time.Now()
+
+	// Define the query
+	query := `
+        SELECT dm.method_name, de.entity_name, COUNT(*), COUNT(DISTINCT de.document_id)
+        FROM detected_entities de
+        JOIN documents d ON d.document_id = de.document_id
+        JOIN detection_methods dm ON dm.method_id = de.method_id
+        WHERE d.user_id = $1 AND de.detected_timestamp >= $2 AND de.detected_timestamp < $3
+        GROUP BY dm.method_name, de.entity_name
+        ORDER BY dm.method_name, de.entity_name
+    `
+
+	// Execute the query
+	rows, err := r.db.QueryContext(ctx, query, userID, from, to)
+
+	// Log the query execution
+	utils.LogDBQuery(
+		query,
+		[]interface{}{userID, from, to},
+		time.Since(startTime),
+		err,
+	)
+
+	if err != nil {
+		return nil, fmt.Errorf("failed to get entity summary: %w", err)
+	}
+	defer rows.Close()
+
+	summary := []*models.EntitySummary{}
+	for rows.Next() {
+		row := &models.EntitySummary{}
+		if err := rows.Scan(&row.MethodName, &row.EntityName, &row.EntityCount, &row.DocumentCount); err != nil {
+			return nil, fmt.Errorf("failed to scan entity summary row: %w", err)
+		}
+		summary = append(summary, row)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating entity summary rows: %w", err)
+	}
+
+	return summary, nil
+}
+
+// GetUsage retrieves a user's usage rollup for a month.
+//
+// Parameters:
+//   - ctx: Context for transaction and cancellation control
+//   - userID: The ID of the user
+//   - month: The first day of the month
+//
+// Returns:
+//   - The rollup, or an empty rollup for the month if none was recorded
+//   - An error for database issues
+func (r *PostgresReportRepository) GetUsage(ctx context.Context, userID int64, month time.Time) (*models.TenantUsage, error) {
+	// Start query timer
+	startTime := time.Now()
+
+	// Define the query
+	query := `
+        SELECT document_count, page_count, storage_bytes, api_call_count, updated_at
+        FROM tenant_usage
+        WHERE user_id = $1 AND usage_month = $2
+    `
+
+	// Execute the query
+	usage := &models.TenantUsage{UserID: userID, UsageMonth: month}
+	err := r.db.QueryRowContext(ctx, query, userID, month).Scan(
+		&usage.DocumentCount,
+		&usage.PageCount,
+		&usage.StorageBytes,
+		&usage.APICallCount,
+		&usage.UpdatedAt,
+	)
+
+	// Log the query execution
+	utils.LogDBQuery(
+		query,
+		[]interface{}{userID, month},
+		time.Since(startTime),
+		err,
+	)
+
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return &models.TenantUsage{UserID: userID, UsageMonth: month}, nil
+		}
+		return nil, fmt.Errorf("failed to get tenant usage: %w", err)
+	}
+
+	return usage, nil
+}
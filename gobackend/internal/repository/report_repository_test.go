@@ -0,0 +1,275 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/lib/pq"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/yasinhessnawi1/Hideme_Backend/internal/models"
+	"github.com/yasinhessnawi1/Hideme_Backend/internal/utils"
+)
+
+var reportSubscriptionTestColumns = []string{
+	"subscription_id", "user_id", "report_type", "format", "locale", "csv_delimiter", "next_run_at", "last_sent_at", "created_at",
+}
+
+func TestNewReportRepository(t *testing.T) {
+	// Arrange
+	pool, _, cleanup := setupDBMock(t)
+	defer cleanup()
+
+	// Act
+	repo := NewReportRepository(pool)
+
+	// Assert
+	assert.NotNil(t, repo, "Repository should not be nil")
+	assert.Implements(t, (*ReportRepository)(nil), repo, "Should implement ReportRepository interface")
+}
+
+func TestReportRepository_Create(t *testing.T) {
+	t.Run("Success", func(t *testing.T) {
+		// Arrange
+		pool, mock, cleanup := setupDBMock(t)
+		defer cleanup()
+		repo := NewReportRepository(pool)
+		subscription := models.NewReportSubscription(42, models.ReportMonthlyUsage, models.ReportFormatCSV, models.LocaleNorwegian, "")
+
+		mock.ExpectQuery("INSERT INTO report_subscriptions (.+) RETURNING subscription_id").
+			WithArgs(int64(42), models.ReportMonthlyUsage, models.ReportFormatCSV, models.LocaleNorwegian, models.CSVDelimiter(""), subscription.NextRunAt, sqlmock.AnyArg()).
+			WillReturnRows(sqlmock.NewRows([]string{"subscription_id"}).AddRow(7))
+
+		// Act
+		err := repo.Create(context.Background(), subscription)
+
+		// Assert
+		assert.NoError(t, err)
+		assert.Equal(t, int64(7), subscription.ID)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("Already Subscribed", func(t *testing.T) {
+		// Arrange
+		pool, mock, cleanup := setupDBMock(t)
+		defer cleanup()
+		repo := NewReportRepository(pool)
+
+		mock.ExpectQuery("INSERT INTO report_subscriptions").
+			WillReturnError(&pq.Error{Code: "23505", Constraint: "uq_report_subscription"})
+
+		// Act
+		err := repo.Create(context.Background(), models.NewReportSubscription(42, models.ReportMonthlyUsage, models.ReportFormatCSV, models.LocaleISO, ""))
+
+		// Assert
+		assert.Error(t, err)
+		assert.True(t, errors.Is(err, utils.ErrDuplicate))
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("Database Error", func(t *testing.T) {
+		// Arrange
+		pool, mock, cleanup := setupDBMock(t)
+		defer cleanup()
+		repo := NewReportRepository(pool)
+
+		mock.ExpectQuery("INSERT INTO report_subscriptions").
+			WillReturnError(errors.New("database error"))
+
+		// Act
+		err := repo.Create(context.Background(), models.NewReportSubscription(42, models.ReportMonthlyUsage, models.ReportFormatCSV, models.LocaleISO, ""))
+
+		// Assert
+		assert.Error(t, err)
+		assert.Contains(t, err.Error(), "failed to create report subscription")
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+}
+
+func TestReportRepository_GetByUserID(t *testing.T) {
+	// Arrange
+	pool, mock, cleanup := setupDBMock(t)
+	defer cleanup()
+	repo := NewReportRepository(pool)
+	now := time.Now()
+
+	rows := sqlmock.NewRows(reportSubscriptionTestColumns).
+		AddRow(1, 42, "weekly_entity_summary", "csv", "", "", now, nil, now).
+		AddRow(2, 42, "monthly_usage", "csv", "de-DE", "comma", now, now, now)
+	mock.ExpectQuery("SELECT (.+) FROM report_subscriptions rs WHERE rs.user_id = \\$1").
+		WithArgs(int64(42)).
+		WillReturnRows(rows)
+
+	// Act
+	subscriptions, err := repo.GetByUserID(context.Background(), 42)
+
+	// Assert
+	require.NoError(t, err)
+	require.Len(t, subscriptions, 2)
+	assert.Equal(t, models.ReportWeeklyEntitySummary, subscriptions[0].ReportType)
+	assert.Nil(t, subscriptions[0].LastSentAt)
+	require.NotNil(t, subscriptions[1].LastSentAt)
+	assert.Equal(t, models.LocaleGerman, subscriptions[1].Locale)
+	assert.Equal(t, models.CSVDelimiterComma, subscriptions[1].CSVDelimiter)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestReportRepository_Delete(t *testing.T) {
+	t.Run("Success", func(t *testing.T) {
+		// Arrange
+		pool, mock, cleanup := setupDBMock(t)
+		defer cleanup()
+		repo := NewReportRepository(pool)
+
+		mock.ExpectExec("DELETE FROM report_subscriptions WHERE subscription_id = \\$1 AND user_id = \\$2").
+			WithArgs(int64(7), int64(42)).
+			WillReturnResult(sqlmock.NewResult(0, 1))
+
+		// Act
+		err := repo.Delete(context.Background(), 7, 42)
+
+		// Assert
+		assert.NoError(t, err)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("Not Found", func(t *testing.T) {
+		// Arrange
+		pool, mock, cleanup := setupDBMock(t)
+		defer cleanup()
+		repo := NewReportRepository(pool)
+
+		mock.ExpectExec("DELETE FROM report_subscriptions").
+			WithArgs(int64(7), int64(42)).
+			WillReturnResult(sqlmock.NewResult(0, 0))
+
+		// Act
+		err := repo.Delete(context.Background(), 7, 42)
+
+		// Assert
+		assert.Error(t, err)
+		assert.True(t, utils.IsNotFoundError(err))
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+}
+
+func TestReportRepository_GetDue(t *testing.T) {
+	// Arrange
+	pool, mock, cleanup := setupDBMock(t)
+	defer cleanup()
+	repo := NewReportRepository(pool)
+	now := time.Now()
+
+	columns := append(append([]string{}, reportSubscriptionTestColumns...), "email", "username")
+	rows := sqlmock.NewRows(columns).
+		AddRow(1, 42, "weekly_entity_summary", "csv", "nb-NO", "", now, nil, now, "user@example.com", "user")
+	mock.ExpectQuery("SELECT (.+) FROM report_subscriptions rs JOIN users u (.+) WHERE rs.next_run_at <= \\$1 (.+) LIMIT \\$2").
+		WithArgs(now, 10).
+		WillReturnRows(rows)
+
+	// Act
+	subscriptions, err := repo.GetDue(context.Background(), now, 10)
+
+	// Assert
+	require.NoError(t, err)
+	require.Len(t, subscriptions, 1)
+	assert.Equal(t, "user@example.com", subscriptions[0].Email)
+	assert.Equal(t, "user", subscriptions[0].Username)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestReportRepository_MarkSent(t *testing.T) {
+	// Arrange
+	pool, mock, cleanup := setupDBMock(t)
+	defer cleanup()
+	repo := NewReportRepository(pool)
+	sentAt := time.Now()
+	nextRunAt := sentAt.AddDate(0, 0, 7)
+
+	mock.ExpectExec("UPDATE report_subscriptions SET last_sent_at = \\$1, next_run_at = \\$2 WHERE subscription_id = \\$3").
+		WithArgs(sentAt, nextRunAt, int64(7)).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+
+	// Act
+	err := repo.MarkSent(context.Background(), 7, sentAt, nextRunAt)
+
+	// Assert
+	assert.NoError(t, err)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestReportRepository_GetEntitySummary(t *testing.T) {
+	// Arrange
+	pool, mock, cleanup := setupDBMock(t)
+	defer cleanup()
+	repo := NewReportRepository(pool)
+	from := time.Date(2024, 5, 6, 0, 0, 0, 0, time.UTC)
+	to := from.AddDate(0, 0, 7)
+
+	rows := sqlmock.NewRows([]string{"method_name", "entity_name", "count", "documents"}).
+		AddRow("Presidio", "EMAIL_ADDRESS", 12, 3)
+	mock.ExpectQuery("SELECT dm.method_name, de.entity_name, COUNT(.+) FROM detected_entities de (.+) GROUP BY dm.method_name, de.entity_name").
+		WithArgs(int64(42), from, to).
+		WillReturnRows(rows)
+
+	// Act
+	summary, err := repo.GetEntitySummary(context.Background(), 42, from, to)
+
+	// Assert
+	require.NoError(t, err)
+	require.Len(t, summary, 1)
+	assert.Equal(t, &models.EntitySummary{MethodName: "Presidio", EntityName: "EMAIL_ADDRESS", EntityCount: 12, DocumentCount: 3}, summary[0])
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestReportRepository_GetUsage(t *testing.T) {
+	month := time.Date(2024, 5, 1, 0, 0, 0, 0, time.UTC)
+
+	t.Run("Success", func(t *testing.T) {
+		// Arrange
+		pool, mock, cleanup := setupDBMock(t)
+		defer cleanup()
+		repo := NewReportRepository(pool)
+
+		rows := sqlmock.NewRows([]string{"document_count", "page_count", "storage_bytes", "api_call_count", "updated_at"}).
+			AddRow(5, 40, 2048, 300, time.Now())
+		mock.ExpectQuery("SELECT (.+) FROM tenant_usage WHERE user_id = \\$1 AND usage_month = \\$2").
+			WithArgs(int64(42), month).
+			WillReturnRows(rows)
+
+		// Act
+		usage, err := repo.GetUsage(context.Background(), 42, month)
+
+		// Assert
+		require.NoError(t, err)
+		assert.Equal(t, int64(5), usage.DocumentCount)
+		assert.Equal(t, int64(300), usage.APICallCount)
+		assert.Equal(t, month, usage.UsageMonth)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("No Usage Recorded", func(t *testing.T) {
+		// Arrange
+		pool, mock, cleanup := setupDBMock(t)
+		defer cleanup()
+		repo := NewReportRepository(pool)
+
+		mock.ExpectQuery("SELECT (.+) FROM tenant_usage").
+			WithArgs(int64(42), month).
+			WillReturnError(sql.ErrNoRows)
+
+		// Act
+		usage, err := repo.GetUsage(context.Background(), 42, month)
+
+		// Assert
+		require.NoError(t, err)
+		assert.Equal(t, int64(0), usage.DocumentCount)
+		assert.Equal(t, month, usage.UsageMonth)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+}
@@ -0,0 +1,224 @@
+// Package repository provides data access interfaces and implementations for the HideMe application.
+// It follows the repository pattern to abstract database operations and provide a clean API
+// for data persistence operations.
+//
+// This file implements the counting of the detections produced by ban list words and search patterns.
+// The detections of each saved document are recorded as an event, so the counts can be rebuilt from them.
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/rs/zerolog/log"
+
+	"github.com/yasinhessnawi1/Hideme_Backend/internal/constants"
+	"github.com/yasinhessnawi1/Hideme_Backend/internal/database"
+	"github.com/yasinhessnawi1/Hideme_Backend/internal/models"
+	"github.com/yasinhessnawi1/Hideme_Backend/internal/utils"
+)
+
+// RuleHitRepository defines methods for counting the detections produced by users' rules.
+type RuleHitRepository interface {
+	// Record adds the detections a saved document had to the counts of the user's rules.
+	// Rules seen for the first time start being tracked, even without detections.
+	//
+	// Parameters:
+	//   - ctx: Context for transaction and cancellation control
+	//   - userID: The ID of the user who owns the rules
+	//   - hits: The detections per rule; LastHitAt is set only for rules with detections
+	//
+	// Returns:
+	//   - An error if the counts can't be updated; none are updated then
+	Record(ctx context.Context, userID int64, hits []*models.RuleHit) error
+
+	// ListByUser retrieves the counts of all rules a user's documents were checked against,
+	// including rules the user has removed since.
+	//
+	// Parameters:
+	//   - ctx: Context for transaction and cancellation control
+	//   - userID: The ID of the user
+	//
+	// Returns:
+	//   - The counts
+	//   - An error if retrieval fails
+	ListByUser(ctx context.Context, userID int64) ([]*models.RuleHit, error)
+
+	// Rebuild recalculates the counts of all users' rules by replaying the recorded detections.
+	//
+	// Parameters:
+	//   - ctx: Context for transaction and cancellation control
+	//
+	// Returns:
+	//   - The number of replayed events
+	//   - An error if the rebuild fails; in that case the counts are unchanged
+	Rebuild(ctx context.Context) (int64, error)
+}
+
+// PostgresRuleHitRepository is a PostgreSQL implementation of RuleHitRepository.
+type PostgresRuleHitRepository struct {
+	db *database.Pool
+}
+
+// NewRuleHitRepository creates a new RuleHitRepository implementation for PostgreSQL.
+//
+// Parameters:
+//   - db: A connection pool for PostgreSQL database access
+//
+// Returns:
+//   - An implementation of the RuleHitRepository interface
+func NewRuleHitRepository(db *database.Pool) RuleHitRepository {
+	return &PostgresRuleHitRepository{
+		db: db,
+	}
+}
+
+// Record adds the detections a saved document had to the counts of the user's rules, and
+// records them as an event.
+func (r *PostgresRuleHitRepository) Record(ctx context.Context, userID int64, hits []*models.RuleHit) error {
+	if len(hits) == 0 {
+		return nil
+	}
+
+	// Start query timer
+	startTime := time.Now()
+
+	// Execute within a transaction
+	return r.db.Transaction(ctx, func(tx *sql.Tx) error {
+		if err := addRuleHits(ctx, tx, userID, hits); err != nil {
+			return err
+		}
+		event := models.RuleHitsRecordedEvent{Hits: hits}
+		if err := appendEvent(ctx, tx, constants.EventRuleHitsRecorded, userID, time.Now(), event); err != nil {
+			return err
+		}
+
+		// Log the operation
+		utils.LogDBQuery(
+			fmt.Sprintf("Recorded hits of %d rules", len(hits)),
+			[]interface{}{userID},
+			time.Since(startTime),
+			nil,
+		)
+
+		return nil
+	})
+}
+
+// Rebuild recalculates the counts of all users' rules by replaying the recorded detections
+// within a single transaction.
+func (r *PostgresRuleHitRepository) Rebuild(ctx context.Context) (int64, error) {
+	// Start query timer
+	startTime := time.Now()
+
+	var replayed int64
+	err := r.db.Transaction(ctx, func(tx *sql.Tx) error {
+		if err := lockProjection(ctx, tx, constants.TableRuleHits); err != nil {
+			return err
+		}
+
+		query := `DELETE FROM ` + constants.TableRuleHits
+		if _, err := tx.ExecContext(ctx, query); err != nil {
+			return fmt.Errorf("failed to reset rule hits: %w", err)
+		}
+
+		var err error
+		replayed, err = replayEvents(ctx, tx, constants.EventRuleHitsRecorded, func(event *models.DomainEvent) error {
+			var recorded models.RuleHitsRecordedEvent
+			if err := json.Unmarshal(event.Payload, &recorded); err != nil {
+				return fmt.Errorf("failed to unmarshal rule hits event: %w", err)
+			}
+			return addRuleHits(ctx, tx, event.UserID, recorded.Hits)
+		})
+		return err
+	})
+
+	// Log the operation
+	utils.LogDBQuery(
+		fmt.Sprintf("Rebuilt rule hits from %d events", replayed),
+		nil,
+		time.Since(startTime),
+		err,
+	)
+
+	if err != nil {
+		return 0, err
+	}
+	return replayed, nil
+}
+
+// addRuleHits adds detections to the counts of a user's rules. It applies both new
+// detections and replayed events, so a rebuild counts them the same way.
+func addRuleHits(ctx context.Context, tx *sql.Tx, userID int64, hits []*models.RuleHit) error {
+	query := `
+        INSERT INTO ` + constants.TableRuleHits + ` (` + constants.ColumnUserID + `, rule_type, rule_text, hit_count, last_hit_at, tracked_since)
+        VALUES ($1, $2, $3, $4, $5, $6)
+        ON CONFLICT (` + constants.ColumnUserID + `, rule_type, rule_text) DO UPDATE
+        SET hit_count = ` + constants.TableRuleHits + `.hit_count + EXCLUDED.hit_count,
+            last_hit_at = COALESCE(EXCLUDED.last_hit_at, ` + constants.TableRuleHits + `.last_hit_at)
+    `
+
+	// Upsert each rule individually
+	for _, hit := range hits {
+		_, err := tx.ExecContext(ctx, query, userID, hit.RuleType, hit.RuleText, hit.HitCount, hit.LastHitAt, hit.TrackedSince)
+		if err != nil {
+			return fmt.Errorf("failed to record rule hits: %w", err)
+		}
+	}
+	return nil
+}
+
+// ListByUser retrieves the counts of all rules a user's documents were checked against.
+func (r *PostgresRuleHitRepository) ListByUser(ctx context.Context, userID int64) ([]*models.RuleHit, error) {
+	// Start query timer
+	startTime := time.Now()
+
+	// Define the query
+	query := `
+        SELECT ` + constants.ColumnUserID + `, rule_type, rule_text, hit_count, last_hit_at, tracked_since
+        FROM ` + constants.TableRuleHits + `
+        WHERE ` + constants.ColumnUserID + ` = $1
+    `
+
+	// Execute the query
+	rows, err := r.db.QueryContext(ctx, query, userID)
+
+	// Log the query execution
+	utils.LogDBQuery(
+		query,
+		[]interface{}{userID},
+		time.Since(startTime),
+		err,
+	)
+
+	if err != nil {
+		return nil, fmt.Errorf("failed to list rule hits: %w", err)
+	}
+	defer func() {
+		if closeErr := rows.Close(); closeErr != nil {
+			log.Error().Err(closeErr).Msg("failed to close rows")
+		}
+	}()
+
+	var hits []*models.RuleHit
+	for rows.Next() {
+		hit := &models.RuleHit{}
+		var lastHitAt sql.NullTime
+		if err := rows.Scan(&hit.UserID, &hit.RuleType, &hit.RuleText, &hit.HitCount, &lastHitAt, &hit.TrackedSince); err != nil {
+			return nil, fmt.Errorf("failed to scan rule hit: %w", err)
+		}
+		if lastHitAt.Valid {
+			hit.LastHitAt = &lastHitAt.Time
+		}
+		hits = append(hits, hit)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating rule hits: %w", err)
+	}
+
+	return hits, nil
+}
@@ -0,0 +1,135 @@
+package repository_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/yasinhessnawi1/Hideme_Backend/internal/constants"
+	"github.com/yasinhessnawi1/Hideme_Backend/internal/database"
+	"github.com/yasinhessnawi1/Hideme_Backend/internal/models"
+	"github.com/yasinhessnawi1/Hideme_Backend/internal/repository"
+)
+
+func setupRuleHitRepositoryTest(t *testing.T) (repository.RuleHitRepository, sqlmock.Sqlmock, func()) {
+	db, mock, err := sqlmock.New()
+	require.NoError(t, err)
+
+	return repository.NewRuleHitRepository(&database.Pool{DB: db}), mock, func() {
+		db.Close()
+	}
+}
+
+func TestRuleHitRepository_Record(t *testing.T) {
+	repo, mock, cleanup := setupRuleHitRepositoryTest(t)
+	defer cleanup()
+
+	now := time.Date(2026, 3, 1, 8, 0, 0, 0, time.UTC)
+	hits := []*models.RuleHit{
+		{RuleType: constants.RuleTypeSearchPattern, RuleText: "Ola Nordmann", HitCount: 2, LastHitAt: &now, TrackedSince: now},
+		{RuleType: constants.RuleTypeBanListWord, RuleText: "oslo", TrackedSince: now},
+	}
+
+	t.Run("Success", func(t *testing.T) {
+		mock.ExpectBegin()
+		mock.ExpectExec("INSERT INTO rule_hits .* ON CONFLICT \\(user_id, rule_type, rule_text\\) DO UPDATE").
+			WithArgs(int64(1), constants.RuleTypeSearchPattern, "Ola Nordmann", int64(2), &now, now).
+			WillReturnResult(sqlmock.NewResult(0, 1))
+		mock.ExpectExec("INSERT INTO rule_hits").
+			WithArgs(int64(1), constants.RuleTypeBanListWord, "oslo", int64(0), nil, now).
+			WillReturnResult(sqlmock.NewResult(0, 1))
+		// The detections are recorded as an event to rebuild the counts from
+		mock.ExpectExec("INSERT INTO domain_events").
+			WithArgs(constants.EventRuleHitsRecorded, int64(1), sqlmock.AnyArg(), sqlmock.AnyArg()).
+			WillReturnResult(sqlmock.NewResult(1, 1))
+		mock.ExpectCommit()
+
+		assert.NoError(t, repo.Record(context.Background(), 1, hits))
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("Error rolls back", func(t *testing.T) {
+		mock.ExpectBegin()
+		mock.ExpectExec("INSERT INTO rule_hits").WillReturnError(errors.New("database error"))
+		mock.ExpectRollback()
+
+		err := repo.Record(context.Background(), 1, hits)
+		assert.Error(t, err)
+		assert.Contains(t, err.Error(), "failed to record rule hits")
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("Nothing to record", func(t *testing.T) {
+		assert.NoError(t, repo.Record(context.Background(), 1, nil))
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+}
+
+func TestRuleHitRepository_ListByUser(t *testing.T) {
+	repo, mock, cleanup := setupRuleHitRepositoryTest(t)
+	defer cleanup()
+
+	now := time.Date(2026, 3, 1, 8, 0, 0, 0, time.UTC)
+	rows := sqlmock.NewRows([]string{"user_id", "rule_type", "rule_text", "hit_count", "last_hit_at", "tracked_since"}).
+		AddRow(1, constants.RuleTypeSearchPattern, "Ola Nordmann", 2, now, now).
+		AddRow(1, constants.RuleTypeBanListWord, "oslo", 0, nil, now)
+	mock.ExpectQuery("SELECT user_id, rule_type, rule_text, hit_count, last_hit_at, tracked_since FROM rule_hits WHERE user_id = \\$1").
+		WithArgs(int64(1)).
+		WillReturnRows(rows)
+
+	hits, err := repo.ListByUser(context.Background(), 1)
+
+	require.NoError(t, err)
+	require.Len(t, hits, 2)
+	assert.Equal(t, int64(2), hits[0].HitCount)
+	require.NotNil(t, hits[0].LastHitAt)
+	assert.True(t, hits[0].LastHitAt.Equal(now))
+	assert.Nil(t, hits[1].LastHitAt)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestRuleHitRepository_Rebuild(t *testing.T) {
+	repo, mock, cleanup := setupRuleHitRepositoryTest(t)
+	defer cleanup()
+
+	now := time.Date(2026, 3, 1, 8, 0, 0, 0, time.UTC)
+	payload := `{"hits":[{"rule_type":"search_pattern","rule_text":"Ola Nordmann","hit_count":2,"last_hit_at":"2026-03-01T08:00:00Z","tracked_since":"2026-03-01T08:00:00Z"}]}`
+
+	t.Run("Success", func(t *testing.T) {
+		mock.ExpectBegin()
+		mock.ExpectExec("LOCK TABLE rule_hits IN EXCLUSIVE MODE").WillReturnResult(sqlmock.NewResult(0, 0))
+		mock.ExpectExec("DELETE FROM rule_hits").WillReturnResult(sqlmock.NewResult(0, 4))
+		mock.ExpectQuery("SELECT event_id, event_type, user_id, payload, occurred_at FROM domain_events").
+			WithArgs(constants.EventRuleHitsRecorded, int64(0), constants.DomainEventReplayBatchSize).
+			WillReturnRows(sqlmock.NewRows([]string{"event_id", "event_type", "user_id", "payload", "occurred_at"}).
+				AddRow(int64(9), constants.EventRuleHitsRecorded, int64(1), []byte(payload), now))
+		mock.ExpectExec("INSERT INTO rule_hits").
+			WithArgs(int64(1), constants.RuleTypeSearchPattern, "Ola Nordmann", int64(2), &now, now).
+			WillReturnResult(sqlmock.NewResult(0, 1))
+		mock.ExpectCommit()
+
+		replayed, err := repo.Rebuild(context.Background())
+		require.NoError(t, err)
+		assert.Equal(t, int64(1), replayed)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("Malformed event rolls back", func(t *testing.T) {
+		mock.ExpectBegin()
+		mock.ExpectExec("LOCK TABLE rule_hits").WillReturnResult(sqlmock.NewResult(0, 0))
+		mock.ExpectExec("DELETE FROM rule_hits").WillReturnResult(sqlmock.NewResult(0, 4))
+		mock.ExpectQuery("FROM domain_events").
+			WillReturnRows(sqlmock.NewRows([]string{"event_id", "event_type", "user_id", "payload", "occurred_at"}).
+				AddRow(int64(9), constants.EventRuleHitsRecorded, int64(1), []byte(`{"hits":`), now))
+		mock.ExpectRollback()
+
+		_, err := repo.Rebuild(context.Background())
+		assert.ErrorContains(t, err, "failed to replay event 9")
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+}
@@ -14,7 +14,6 @@ import (
 	"fmt"
 	"time"
 
-	"github.com/google/uuid"
 	"github.com/lib/pq"
 	"github.com/rs/zerolog/log"
 
@@ -39,7 +38,7 @@ type SessionRepository interface {
 	//   - Other errors for database issues
 	//   - nil on successful creation
 	//
-	// If the session ID is empty, a new UUID will be generated automatically.
+	// If the session ID is empty, a new ID will be generated automatically.
 	Create(ctx context.Context, session *models.Session) error
 
 	// GetByID retrieves a session by its unique identifier.
@@ -114,16 +113,39 @@ type SessionRepository interface {
 	//   - nil on successful deletion or if no sessions exist
 	DeleteByUserID(ctx context.Context, userID int64) error
 
-	// DeleteExpired removes all expired sessions from the database.
+	// DeleteExpired removes expired sessions from the database.
 	// This is typically used by a scheduled cleanup process.
 	//
 	// Parameters:
 	//   - ctx: Context for transaction and cancellation control
+	//   - limit: The maximum number of sessions to delete; 0 for no limit
 	//
 	// Returns:
 	//   - The number of expired sessions deleted
 	//   - An error if deletion fails
-	DeleteExpired(ctx context.Context) (int64, error)
+	DeleteExpired(ctx context.Context, limit int) (int64, error)
+
+	// CountExpired counts the expired sessions without deleting them.
+	//
+	// Parameters:
+	//   - ctx: Context for transaction and cancellation control
+	//
+	// Returns:
+	//   - The number of expired sessions
+	//   - An error if counting fails
+	CountExpired(ctx context.Context) (int64, error)
+
+	// DeleteIdle removes all sessions that were last used before a cutoff.
+	// This is typically used by a scheduled cleanup process to enforce the idle timeout.
+	//
+	// Parameters:
+	//   - ctx: Context for transaction and cancellation control
+	//   - cutoff: Sessions last used before this time are deleted
+	//
+	// Returns:
+	//   - The number of idle sessions deleted
+	//   - An error if deletion fails
+	DeleteIdle(ctx context.Context, cutoff time.Time) (int64, error)
 
 	// IsValidSession checks if a session with the given JWT ID exists and is not expired.
 	//
@@ -169,20 +191,20 @@ func NewSessionRepository(db *database.Pool) SessionRepository {
 //   - Other errors for database issues
 //   - nil on successful creation
 //
-// If the session ID is empty, a new UUID will be generated automatically.
+// If the session ID is empty, a new ID will be generated automatically.
 func (r *PostgresSessionRepository) Create(ctx context.Context, session *models.Session) error {
 	// Start query timer
 	startTime := time.Now()
 
 	// Generate a unique ID if not already set
 	if session.ID == "" {
-		session.ID = uuid.New().String()
+		session.ID = utils.NewID()
 	}
 
 	// Define the query
 	query := `
-		INSERT INTO sessions (session_id, user_id, jwt_id, expires_at, created_at)
-		VALUES ($1, $2, $3, $4, $5)
+		INSERT INTO sessions (session_id, user_id, jwt_id, expires_at, created_at, last_activity_at)
+		VALUES ($1, $2, $3, $4, $5, $6)
 	`
 
 	// Execute the query
@@ -194,26 +216,28 @@ func (r *PostgresSessionRepository) Create(ctx context.Context, session *models.
 		session.JWTID,
 		session.ExpiresAt,
 		session.CreatedAt,
+		session.LastActivityAt,
 	)
 
 	// Log the query execution
 	utils.LogDBQuery(
 		query,
-		[]interface{}{session.ID, session.UserID, session.JWTID, session.ExpiresAt, session.CreatedAt},
+		[]interface{}{session.ID, session.UserID, session.JWTID, session.ExpiresAt, session.CreatedAt, session.LastActivityAt},
 		time.Since(startTime),
 		err,
 	)
 
 	if err != nil {
 		// Check for unique constraint violations
-		if pqErr, ok := err.(*pq.Error); ok {
+		var pqErr *pq.Error
+		if errors.As(err, &pqErr) {
 			// Check for duplicate constraint
 			if pqErr.Code == constants.PGErrorDuplicateConstraint {
 				if pqErr.Constraint == "sessions_pkey" {
-					return utils.NewDuplicateError("Session", "id", session.ID)
+					return utils.NewDuplicateError("Session", "id", session.ID).WithCause(err)
 				}
 				if pqErr.Constraint == constants.IndexJWTID {
-					return utils.NewDuplicateError("Session", constants.ColumnJWTID, session.JWTID)
+					return utils.NewDuplicateError("Session", constants.ColumnJWTID, session.JWTID).WithCause(err)
 				}
 			}
 		}
@@ -246,7 +270,7 @@ func (r *PostgresSessionRepository) GetByID(ctx context.Context, id string) (*mo
 
 	// Define the query
 	query := `
-		SELECT session_id, user_id, jwt_id, expires_at, created_at
+		SELECT session_id, user_id, jwt_id, expires_at, created_at, last_activity_at
 		FROM sessions
 		WHERE session_id = $1
 	`
@@ -259,6 +283,7 @@ func (r *PostgresSessionRepository) GetByID(ctx context.Context, id string) (*mo
 		&session.JWTID,
 		&session.ExpiresAt,
 		&session.CreatedAt,
+		&session.LastActivityAt,
 	)
 
 	// Log the query execution
@@ -271,7 +296,7 @@ func (r *PostgresSessionRepository) GetByID(ctx context.Context, id string) (*mo
 
 	if err != nil {
 		if errors.Is(err, sql.ErrNoRows) {
-			return nil, utils.NewNotFoundError("Session", id)
+			return nil, utils.NewNotFoundError("Session", id).WithCause(err)
 		}
 		return nil, fmt.Errorf("failed to get session by ID: %w", err)
 	}
@@ -295,7 +320,7 @@ func (r *PostgresSessionRepository) GetByJWTID(ctx context.Context, jwtID string
 
 	// Define the query
 	query := `
-		SELECT session_id, user_id, jwt_id, expires_at, created_at
+		SELECT session_id, user_id, jwt_id, expires_at, created_at, last_activity_at
 		FROM sessions
 		WHERE jwt_id = $1
 	`
@@ -308,6 +333,7 @@ func (r *PostgresSessionRepository) GetByJWTID(ctx context.Context, jwtID string
 		&session.JWTID,
 		&session.ExpiresAt,
 		&session.CreatedAt,
+		&session.LastActivityAt,
 	)
 
 	// Log the query execution
@@ -320,7 +346,7 @@ func (r *PostgresSessionRepository) GetByJWTID(ctx context.Context, jwtID string
 
 	if err != nil {
 		if errors.Is(err, sql.ErrNoRows) {
-			return nil, utils.NewNotFoundError("Session", fmt.Sprintf("jwt_id=%s", jwtID))
+			return nil, utils.NewNotFoundError("Session", fmt.Sprintf("jwt_id=%s", jwtID)).WithCause(err)
 		}
 		return nil, fmt.Errorf("failed to get session by JWT ID: %w", err)
 	}
@@ -344,7 +370,7 @@ func (r *PostgresSessionRepository) GetActiveByUserID(ctx context.Context, userI
 
 	// Define the query
 	query := `
-		SELECT session_id, user_id, jwt_id, expires_at, created_at
+		SELECT session_id, user_id, jwt_id, expires_at, created_at, last_activity_at
 		FROM sessions
 		WHERE user_id = $1 AND expires_at > $2
 		ORDER BY created_at DESC
@@ -381,6 +407,7 @@ func (r *PostgresSessionRepository) GetActiveByUserID(ctx context.Context, userI
 			&session.JWTID,
 			&session.ExpiresAt,
 			&session.CreatedAt,
+			&session.LastActivityAt,
 		)
 		if err != nil {
 			return nil, fmt.Errorf("failed to scan session row: %w", err)
@@ -535,30 +562,41 @@ func (r *PostgresSessionRepository) DeleteByUserID(ctx context.Context, userID i
 	return nil
 }
 
-// DeleteExpired removes all expired sessions from the database.
+// DeleteExpired removes expired sessions from the database, at most limit of them
+// unless limit is 0.
 // This is typically used by a scheduled cleanup process.
 //
 // Parameters:
 //   - ctx: Context for transaction and cancellation control
+//   - limit: The maximum number of sessions to delete; 0 for no limit
 //
 // Returns:
 //   - The number of expired sessions deleted
 //   - An error if deletion fails
-func (r *PostgresSessionRepository) DeleteExpired(ctx context.Context) (int64, error) {
+func (r *PostgresSessionRepository) DeleteExpired(ctx context.Context, limit int) (int64, error) {
 	// Start query timer
 	startTime := time.Now()
 
 	// Define the query
+	now := time.Now()
 	query := `DELETE FROM sessions WHERE expires_at < $1`
+	args := []interface{}{now}
+	if limit > 0 {
+		query = `
+            DELETE FROM sessions WHERE ` + constants.ColumnSessionID + ` IN (
+                SELECT ` + constants.ColumnSessionID + ` FROM sessions WHERE expires_at < $1 LIMIT $2
+            )
+        `
+		args = append(args, limit)
+	}
 
 	// Execute the query
-	now := time.Now()
-	result, err := r.db.ExecContext(ctx, query, now)
+	result, err := r.db.ExecContext(ctx, query, args...)
 
 	// Log the query execution
 	utils.LogDBQuery(
 		query,
-		[]interface{}{now},
+		args,
 		time.Since(startTime),
 		err,
 	)
@@ -580,6 +618,86 @@ func (r *PostgresSessionRepository) DeleteExpired(ctx context.Context) (int64, e
 	return count, nil
 }
 
+// CountExpired counts the expired sessions without deleting them.
+//
+// Parameters:
+//   - ctx: Context for transaction and cancellation control
+//
+// Returns:
+//   - The number of expired sessions
+//   - An error if counting fails
+func (r *PostgresSessionRepository) CountExpired(ctx context.Context) (int64, error) {
+	// Start query timer
+	startTime := time.Now()
+
+	// Define the query
+	query := `SELECT COUNT(*) FROM sessions WHERE expires_at < $1`
+
+	// Execute the query
+	now := time.Now()
+	var count int64
+	err := r.db.QueryRowContext(ctx, query, now).Scan(&count)
+
+	// Log the query execution
+	utils.LogDBQuery(
+		query,
+		[]interface{}{now},
+		time.Since(startTime),
+		err,
+	)
+
+	if err != nil {
+		return 0, fmt.Errorf("failed to count expired sessions: %w", err)
+	}
+
+	return count, nil
+}
+
+// DeleteIdle removes all sessions that were last used before a cutoff.
+//
+// Parameters:
+//   - ctx: Context for transaction and cancellation control
+//   - cutoff: Sessions last used before this time are deleted
+//
+// Returns:
+//   - The number of idle sessions deleted
+//   - An error if deletion fails
+func (r *PostgresSessionRepository) DeleteIdle(ctx context.Context, cutoff time.Time) (int64, error) {
+	// Start query timer
+	startTime := time.Now()
+
+	// Define the query
+	query := `DELETE FROM sessions WHERE last_activity_at < $1`
+
+	// Execute the query
+	result, err := r.db.ExecContext(ctx, query, cutoff)
+
+	// Log the query execution
+	utils.LogDBQuery(
+		query,
+		[]interface{}{cutoff},
+		time.Since(startTime),
+		err,
+	)
+
+	if err != nil {
+		return 0, fmt.Errorf("failed to delete idle sessions: %w", err)
+	}
+
+	// Log the deletion
+	count, err := result.RowsAffected()
+	if err != nil {
+		return 0, fmt.Errorf("failed to get rows affected: %w", err)
+	}
+
+	log.Info().
+		Int64("count", count).
+		Time("cutoff", cutoff).
+		Msg("Idle sessions deleted")
+
+	return count, nil
+}
+
 // IsValidSession checks if a session with the given JWT ID exists and is not expired.
 //
 // Parameters:
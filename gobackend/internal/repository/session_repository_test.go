@@ -52,7 +52,7 @@ func TestSessionRepository_Create(t *testing.T) {
 
 	// Expected query with placeholders for the arguments
 	mock.ExpectExec("INSERT INTO sessions").
-		WithArgs(session.ID, session.UserID, session.JWTID, session.ExpiresAt, session.CreatedAt).
+		WithArgs(session.ID, session.UserID, session.JWTID, session.ExpiresAt, session.CreatedAt, session.LastActivityAt).
 		WillReturnResult(sqlmock.NewResult(1, 1))
 
 	// Execute the method being tested
@@ -80,7 +80,7 @@ func TestSessionRepository_Create_EmptyID(t *testing.T) {
 
 	// Expected query with placeholders - note that ID will be generated
 	mock.ExpectExec("INSERT INTO sessions").
-		WithArgs(sqlmock.AnyArg(), session.UserID, session.JWTID, session.ExpiresAt, session.CreatedAt).
+		WithArgs(sqlmock.AnyArg(), session.UserID, session.JWTID, session.ExpiresAt, session.CreatedAt, session.LastActivityAt).
 		WillReturnResult(sqlmock.NewResult(1, 1))
 
 	// Execute the method being tested
@@ -114,7 +114,7 @@ func TestSessionRepository_Create_DuplicateID(t *testing.T) {
 	}
 
 	mock.ExpectExec("INSERT INTO sessions").
-		WithArgs(session.ID, session.UserID, session.JWTID, session.ExpiresAt, session.CreatedAt).
+		WithArgs(session.ID, session.UserID, session.JWTID, session.ExpiresAt, session.CreatedAt, session.LastActivityAt).
 		WillReturnError(pqErr)
 
 	// Execute the method being tested
@@ -149,7 +149,7 @@ func TestSessionRepository_Create_DuplicateJWTID(t *testing.T) {
 	}
 
 	mock.ExpectExec("INSERT INTO sessions").
-		WithArgs(session.ID, session.UserID, session.JWTID, session.ExpiresAt, session.CreatedAt).
+		WithArgs(session.ID, session.UserID, session.JWTID, session.ExpiresAt, session.CreatedAt, session.LastActivityAt).
 		WillReturnError(pqErr)
 
 	// Execute the method being tested
@@ -179,7 +179,7 @@ func TestSessionRepository_Create_Error(t *testing.T) {
 
 	// Mock database error
 	mock.ExpectExec("INSERT INTO sessions").
-		WithArgs(session.ID, session.UserID, session.JWTID, session.ExpiresAt, session.CreatedAt).
+		WithArgs(session.ID, session.UserID, session.JWTID, session.ExpiresAt, session.CreatedAt, session.LastActivityAt).
 		WillReturnError(errors.New("database error"))
 
 	// Execute the method being tested
@@ -208,11 +208,11 @@ func TestSessionRepository_GetByID(t *testing.T) {
 	}
 
 	// Set up query result
-	rows := sqlmock.NewRows([]string{"session_id", "user_id", "jwt_id", "expires_at", "created_at"}).
-		AddRow(session.ID, session.UserID, session.JWTID, session.ExpiresAt, session.CreatedAt)
+	rows := sqlmock.NewRows([]string{"session_id", "user_id", "jwt_id", "expires_at", "created_at", "last_activity_at"}).
+		AddRow(session.ID, session.UserID, session.JWTID, session.ExpiresAt, session.CreatedAt, session.LastActivityAt)
 
 	// Expected query with placeholder for the ID
-	mock.ExpectQuery("SELECT session_id, user_id, jwt_id, expires_at, created_at FROM sessions WHERE session_id = \\$1").
+	mock.ExpectQuery("SELECT session_id, user_id, jwt_id, expires_at, created_at, last_activity_at FROM sessions WHERE session_id = \\$1").
 		WithArgs(id).
 		WillReturnRows(rows)
 
@@ -238,7 +238,7 @@ func TestSessionRepository_GetByID_NotFound(t *testing.T) {
 	id := "nonexistent-session"
 
 	// Mock database response - empty result
-	mock.ExpectQuery("SELECT session_id, user_id, jwt_id, expires_at, created_at FROM sessions WHERE session_id = \\$1").
+	mock.ExpectQuery("SELECT session_id, user_id, jwt_id, expires_at, created_at, last_activity_at FROM sessions WHERE session_id = \\$1").
 		WithArgs(id).
 		WillReturnError(sql.ErrNoRows)
 
@@ -260,7 +260,7 @@ func TestSessionRepository_GetByID_Error(t *testing.T) {
 	id := "session123"
 
 	// Mock general database error
-	mock.ExpectQuery("SELECT session_id, user_id, jwt_id, expires_at, created_at FROM sessions WHERE session_id = \\$1").
+	mock.ExpectQuery("SELECT session_id, user_id, jwt_id, expires_at, created_at, last_activity_at FROM sessions WHERE session_id = \\$1").
 		WithArgs(id).
 		WillReturnError(errors.New("database error"))
 
@@ -291,11 +291,11 @@ func TestSessionRepository_GetByJWTID(t *testing.T) {
 	}
 
 	// Set up query result
-	rows := sqlmock.NewRows([]string{"session_id", "user_id", "jwt_id", "expires_at", "created_at"}).
-		AddRow(session.ID, session.UserID, session.JWTID, session.ExpiresAt, session.CreatedAt)
+	rows := sqlmock.NewRows([]string{"session_id", "user_id", "jwt_id", "expires_at", "created_at", "last_activity_at"}).
+		AddRow(session.ID, session.UserID, session.JWTID, session.ExpiresAt, session.CreatedAt, session.LastActivityAt)
 
 	// Expected query with placeholder for the JWT ID
-	mock.ExpectQuery("SELECT session_id, user_id, jwt_id, expires_at, created_at FROM sessions WHERE jwt_id = \\$1").
+	mock.ExpectQuery("SELECT session_id, user_id, jwt_id, expires_at, created_at, last_activity_at FROM sessions WHERE jwt_id = \\$1").
 		WithArgs(jwtID).
 		WillReturnRows(rows)
 
@@ -321,7 +321,7 @@ func TestSessionRepository_GetByJWTID_NotFound(t *testing.T) {
 	jwtID := "nonexistent-jwt"
 
 	// Mock database response - empty result
-	mock.ExpectQuery("SELECT session_id, user_id, jwt_id, expires_at, created_at FROM sessions WHERE jwt_id = \\$1").
+	mock.ExpectQuery("SELECT session_id, user_id, jwt_id, expires_at, created_at, last_activity_at FROM sessions WHERE jwt_id = \\$1").
 		WithArgs(jwtID).
 		WillReturnError(sql.ErrNoRows)
 
@@ -343,7 +343,7 @@ func TestSessionRepository_GetByJWTID_Error(t *testing.T) {
 	jwtID := "jwt456"
 
 	// Mock general database error
-	mock.ExpectQuery("SELECT session_id, user_id, jwt_id, expires_at, created_at FROM sessions WHERE jwt_id = \\$1").
+	mock.ExpectQuery("SELECT session_id, user_id, jwt_id, expires_at, created_at, last_activity_at FROM sessions WHERE jwt_id = \\$1").
 		WithArgs(jwtID).
 		WillReturnError(errors.New("database error"))
 
@@ -383,13 +383,13 @@ func TestSessionRepository_GetActiveByUserID(t *testing.T) {
 	}
 
 	// Set up query result
-	rows := sqlmock.NewRows([]string{"session_id", "user_id", "jwt_id", "expires_at", "created_at"})
+	rows := sqlmock.NewRows([]string{"session_id", "user_id", "jwt_id", "expires_at", "created_at", "last_activity_at"})
 	for _, session := range sessions {
-		rows.AddRow(session.ID, session.UserID, session.JWTID, session.ExpiresAt, session.CreatedAt)
+		rows.AddRow(session.ID, session.UserID, session.JWTID, session.ExpiresAt, session.CreatedAt, session.LastActivityAt)
 	}
 
 	// Expected query with placeholders for user ID and current time
-	mock.ExpectQuery("SELECT session_id, user_id, jwt_id, expires_at, created_at FROM sessions WHERE user_id = \\$1 AND expires_at > \\$2 ORDER BY created_at DESC").
+	mock.ExpectQuery("SELECT session_id, user_id, jwt_id, expires_at, created_at, last_activity_at FROM sessions WHERE user_id = \\$1 AND expires_at > \\$2 ORDER BY created_at DESC").
 		WithArgs(userID, sqlmock.AnyArg()).
 		WillReturnRows(rows)
 
@@ -413,7 +413,7 @@ func TestSessionRepository_GetActiveByUserID_Error(t *testing.T) {
 	userID := int64(100)
 
 	// Mock database error
-	mock.ExpectQuery("SELECT session_id, user_id, jwt_id, expires_at, created_at FROM sessions WHERE user_id = \\$1 AND expires_at > \\$2 ORDER BY created_at DESC").
+	mock.ExpectQuery("SELECT session_id, user_id, jwt_id, expires_at, created_at, last_activity_at FROM sessions WHERE user_id = \\$1 AND expires_at > \\$2 ORDER BY created_at DESC").
 		WithArgs(userID, sqlmock.AnyArg()).
 		WillReturnError(errors.New("database error"))
 
@@ -436,10 +436,10 @@ func TestSessionRepository_GetActiveByUserID_ScanError(t *testing.T) {
 	userID := int64(100)
 
 	// Create rows with invalid data to cause scan error
-	rows := sqlmock.NewRows([]string{"session_id", "user_id", "jwt_id", "expires_at", "created_at"}).
-		AddRow("session1", "invalid_user_id", "jwt1", time.Now(), time.Now()) // invalid_user_id should cause scan error
+	rows := sqlmock.NewRows([]string{"session_id", "user_id", "jwt_id", "expires_at", "created_at", "last_activity_at"}).
+		AddRow("session1", "invalid_user_id", "jwt1", time.Now(), time.Now(), time.Now()) // invalid_user_id should cause scan error
 
-	mock.ExpectQuery("SELECT session_id, user_id, jwt_id, expires_at, created_at FROM sessions WHERE user_id = \\$1 AND expires_at > \\$2 ORDER BY created_at DESC").
+	mock.ExpectQuery("SELECT session_id, user_id, jwt_id, expires_at, created_at, last_activity_at FROM sessions WHERE user_id = \\$1 AND expires_at > \\$2 ORDER BY created_at DESC").
 		WithArgs(userID, sqlmock.AnyArg()).
 		WillReturnRows(rows)
 
@@ -462,11 +462,11 @@ func TestSessionRepository_GetActiveByUserID_RowsError(t *testing.T) {
 	userID := int64(100)
 
 	// Create rows with a row error
-	rows := sqlmock.NewRows([]string{"session_id", "user_id", "jwt_id", "expires_at", "created_at"}).
-		AddRow("session1", userID, "jwt1", time.Now(), time.Now()).
+	rows := sqlmock.NewRows([]string{"session_id", "user_id", "jwt_id", "expires_at", "created_at", "last_activity_at"}).
+		AddRow("session1", userID, "jwt1", time.Now(), time.Now(), time.Now()).
 		RowError(0, errors.New("row error"))
 
-	mock.ExpectQuery("SELECT session_id, user_id, jwt_id, expires_at, created_at FROM sessions WHERE user_id = \\$1 AND expires_at > \\$2 ORDER BY created_at DESC").
+	mock.ExpectQuery("SELECT session_id, user_id, jwt_id, expires_at, created_at, last_activity_at FROM sessions WHERE user_id = \\$1 AND expires_at > \\$2 ORDER BY created_at DESC").
 		WithArgs(userID, sqlmock.AnyArg()).
 		WillReturnRows(rows)
 
@@ -712,7 +712,7 @@ func TestSessionRepository_DeleteExpired(t *testing.T) {
 		WillReturnResult(sqlmock.NewResult(0, 5)) // 5 expired sessions deleted
 
 	// Execute the method being tested
-	count, err := repo.DeleteExpired(context.Background())
+	count, err := repo.DeleteExpired(context.Background(), 0)
 
 	// Assert the results
 	assert.NoError(t, err)
@@ -720,6 +720,81 @@ func TestSessionRepository_DeleteExpired(t *testing.T) {
 	assert.NoError(t, mock.ExpectationsWereMet())
 }
 
+func TestSessionRepository_DeleteExpired_Batch(t *testing.T) {
+	// Set up the test
+	repo, mock, cleanup := setupSessionRepositoryTest(t)
+	defer cleanup()
+
+	// Only a batch of the expired sessions is deleted
+	mock.ExpectExec("DELETE FROM sessions WHERE session_id IN \\( SELECT session_id FROM sessions WHERE expires_at < \\$1 LIMIT \\$2 \\)").
+		WithArgs(sqlmock.AnyArg(), 100).
+		WillReturnResult(sqlmock.NewResult(0, 100))
+
+	// Execute the method being tested
+	count, err := repo.DeleteExpired(context.Background(), 100)
+
+	// Assert the results
+	assert.NoError(t, err)
+	assert.Equal(t, int64(100), count)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestSessionRepository_CountExpired(t *testing.T) {
+	// Set up the test
+	repo, mock, cleanup := setupSessionRepositoryTest(t)
+	defer cleanup()
+
+	mock.ExpectQuery("SELECT COUNT\\(\\*\\) FROM sessions WHERE expires_at < \\$1").
+		WithArgs(sqlmock.AnyArg()).
+		WillReturnRows(sqlmock.NewRows([]string{"count"}).AddRow(7))
+
+	// Execute the method being tested
+	count, err := repo.CountExpired(context.Background())
+
+	// Assert the results
+	assert.NoError(t, err)
+	assert.Equal(t, int64(7), count)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestSessionRepository_DeleteIdle(t *testing.T) {
+	// Set up the test
+	repo, mock, cleanup := setupSessionRepositoryTest(t)
+	defer cleanup()
+
+	cutoff := time.Now().Add(-30 * time.Minute)
+	mock.ExpectExec("DELETE FROM sessions WHERE last_activity_at < \\$1").
+		WithArgs(cutoff).
+		WillReturnResult(sqlmock.NewResult(0, 3)) // 3 idle sessions deleted
+
+	// Execute the method being tested
+	count, err := repo.DeleteIdle(context.Background(), cutoff)
+
+	// Assert the results
+	assert.NoError(t, err)
+	assert.Equal(t, int64(3), count)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestSessionRepository_DeleteIdle_Error(t *testing.T) {
+	// Set up the test
+	repo, mock, cleanup := setupSessionRepositoryTest(t)
+	defer cleanup()
+
+	// Mock database error
+	mock.ExpectExec("DELETE FROM sessions WHERE last_activity_at < \\$1").
+		WithArgs(sqlmock.AnyArg()).
+		WillReturnError(errors.New("database error"))
+
+	// Execute the method being tested
+	_, err := repo.DeleteIdle(context.Background(), time.Now())
+
+	// Assert the results
+	assert.Error(t, err)
+	assert.Contains(t, err.Error(), "failed to delete idle sessions")
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
 func TestSessionRepository_DeleteExpired_Error(t *testing.T) {
 	// Set up the test
 	repo, mock, cleanup := setupSessionRepositoryTest(t)
@@ -731,7 +806,7 @@ func TestSessionRepository_DeleteExpired_Error(t *testing.T) {
 		WillReturnError(errors.New("database error"))
 
 	// Execute the method being tested
-	count, err := repo.DeleteExpired(context.Background())
+	count, err := repo.DeleteExpired(context.Background(), 0)
 
 	// Assert the results
 	assert.Error(t, err)
@@ -754,7 +829,7 @@ func TestSessionRepository_DeleteExpired_RowsAffectedError(t *testing.T) {
 		WillReturnResult(result)
 
 	// Execute the method being tested
-	count, err := repo.DeleteExpired(context.Background())
+	count, err := repo.DeleteExpired(context.Background(), 0)
 
 	// Assert the results
 	assert.Error(t, err)
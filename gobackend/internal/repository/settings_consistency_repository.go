@@ -0,0 +1,241 @@
+// Package repository provides data access interfaces and implementations for the HideMe application.
+// It follows the repository pattern to abstract database operations and provide a clean API
+// for data persistence operations.
+//
+// This file implements the consistency check of the settings object graph. Every user needs
+// exactly one settings row with exactly one ban list; databases created before the foreign keys
+// and unique constraints were added can still hold users without them, and the settings
+// endpoints fail for those users.
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"time"
+
+	"github.com/rs/zerolog/log"
+
+	"github.com/yasinhessnawi1/Hideme_Backend/internal/constants"
+	"github.com/yasinhessnawi1/Hideme_Backend/internal/database"
+	"github.com/yasinhessnawi1/Hideme_Backend/internal/models"
+	"github.com/yasinhessnawi1/Hideme_Backend/internal/utils"
+)
+
+// SettingsConsistencyRepository defines the consistency check and repair of the settings object graph.
+type SettingsConsistencyRepository interface {
+	// Check counts the inconsistencies in the settings object graph without changing anything.
+	//
+	// Parameters:
+	//   - ctx: Context for transaction and cancellation control
+	//
+	// Returns:
+	//   - The inconsistencies found
+	//   - An error for database issues
+	Check(ctx context.Context) (*models.SettingsConsistencyReport, error)
+
+	// Repair repairs the repairable inconsistencies in a single transaction and counts
+	// the ones that are left for manual attention.
+	//
+	// Parameters:
+	//   - ctx: Context for transaction and cancellation control
+	//   - defaults: The settings given to users without a settings row
+	//
+	// Returns:
+	//   - The inconsistencies repaired and the ones left
+	//   - An error for database issues; nothing is repaired then
+	Repair(ctx context.Context, defaults *models.UserSetting) (*models.SettingsConsistencyReport, error)
+}
+
+// PostgresSettingsConsistencyRepository is a PostgreSQL implementation of SettingsConsistencyRepository.
+type PostgresSettingsConsistencyRepository struct {
+	db *database.Pool
+}
+
+// NewSettingsConsistencyRepository creates a new SettingsConsistencyRepository implementation for PostgreSQL.
+//
+// Parameters:
+//   - db: A connection pool for PostgreSQL database access
+//
+// Returns:
+//   - An implementation of the SettingsConsistencyRepository interface
+func NewSettingsConsistencyRepository(db *database.Pool) SettingsConsistencyRepository {
+	return &PostgresSettingsConsistencyRepository{
+		db: db,
+	}
+}
+
+// Conditions selecting the broken rows of the settings object graph, shared by the check and the repair.
+const (
+	usersWithoutSettingsCondition = `NOT EXISTS (SELECT 1 FROM ` + constants.TableUserSettings + ` s WHERE s.` + constants.ColumnUserID + ` = u.` + constants.ColumnUserID + `)`
+
+	settingsWithoutBanListCondition = `NOT EXISTS (SELECT 1 FROM ` + constants.TableBanLists + ` b WHERE b.` + constants.ColumnSettingID + ` = s.` + constants.ColumnSettingID + `)`
+
+	orphanedPatternsCondition = `NOT EXISTS (SELECT 1 FROM ` + constants.TableUserSettings + ` s WHERE s.` + constants.ColumnSettingID + ` = p.` + constants.ColumnSettingID + `)`
+
+	orphanedModelEntitiesCondition = `NOT EXISTS (SELECT 1 FROM ` + constants.TableUserSettings + ` s WHERE s.` + constants.ColumnSettingID + ` = m.` + constants.ColumnSettingID + `)
+               OR NOT EXISTS (SELECT 1 FROM ` + constants.TableDetectionMethods + ` d WHERE d.` + constants.ColumnMethodID + ` = m.` + constants.ColumnMethodID + `)`
+
+	orphanedBanListWordsCondition = `NOT EXISTS (SELECT 1 FROM ` + constants.TableBanLists + ` b WHERE b.` + constants.ColumnBanID + ` = w.` + constants.ColumnBanID + `)`
+)
+
+// checkQuery counts every kind of inconsistency in one round trip.
+const checkQuery = `
+        SELECT
+            (SELECT COUNT(*) FROM ` + constants.TableUsers + ` u WHERE ` + usersWithoutSettingsCondition + `),
+            (SELECT COUNT(*) FROM ` + constants.TableUserSettings + ` s WHERE ` + settingsWithoutBanListCondition + `),
+            (SELECT COUNT(*) FROM ` + constants.TableSearchPatterns + ` p WHERE ` + orphanedPatternsCondition + `),
+            (SELECT COUNT(*) FROM ` + constants.TableModelEntities + ` m WHERE ` + orphanedModelEntitiesCondition + `),
+            (SELECT COUNT(*) FROM ` + constants.TableBanListWords + ` w WHERE ` + orphanedBanListWordsCondition + `),
+            (SELECT COUNT(*) FROM (SELECT ` + constants.ColumnUserID + ` FROM ` + constants.TableUserSettings + ` GROUP BY ` + constants.ColumnUserID + ` HAVING COUNT(*) > 1) dup_settings),
+            (SELECT COUNT(*) FROM (SELECT ` + constants.ColumnSettingID + ` FROM ` + constants.TableBanLists + ` GROUP BY ` + constants.ColumnSettingID + ` HAVING COUNT(*) > 1) dup_ban_lists),
+            (SELECT COUNT(*) FROM ` + constants.TableDetectedEntities + ` e
+                WHERE NOT EXISTS (SELECT 1 FROM ` + constants.TableDetectionMethods + ` d WHERE d.` + constants.ColumnMethodID + ` = e.` + constants.ColumnMethodID + `))
+    `
+
+// rowQuerier is implemented by both the connection pool and transactions.
+type rowQuerier interface {
+	QueryRowContext(ctx context.Context, query string, args ...interface{}) *sql.Row
+}
+
+// Check counts the inconsistencies in the settings object graph without changing anything.
+func (r *PostgresSettingsConsistencyRepository) Check(ctx context.Context) (*models.SettingsConsistencyReport, error) {
+	return r.count(ctx, r.db)
+}
+
+// count runs the check query on a connection or transaction.
+func (r *PostgresSettingsConsistencyRepository) count(ctx context.Context, q rowQuerier) (*models.SettingsConsistencyReport, error) {
+	// Start query timer
+	startTime := time.Now()
+
+	// Execute the query
+	report := &models.SettingsConsistencyReport{}
+	err := q.QueryRowContext(ctx, checkQuery).Scan(
+		&report.UsersWithoutSettings,
+		&report.SettingsWithoutBanList,
+		&report.OrphanedPatterns,
+		&report.OrphanedModelEntities,
+		&report.OrphanedBanListWords,
+		&report.DuplicateSettings,
+		&report.DuplicateBanLists,
+		&report.DetectedEntitiesWithoutMethod,
+	)
+
+	// Log the query execution
+	utils.LogDBQuery(
+		checkQuery,
+		[]interface{}{},
+		time.Since(startTime),
+		err,
+	)
+
+	if err != nil {
+		return nil, fmt.Errorf("failed to check settings consistency: %w", err)
+	}
+
+	return report, nil
+}
+
+// Repair repairs the repairable inconsistencies in a single transaction and counts
+// the ones that are left for manual attention.
+func (r *PostgresSettingsConsistencyRepository) Repair(ctx context.Context, defaults *models.UserSetting) (*models.SettingsConsistencyReport, error) {
+	var report *models.SettingsConsistencyReport
+
+	err := r.db.Transaction(ctx, func(tx *sql.Tx) error {
+		// Count the inconsistencies that can't be repaired before changing anything
+		var err error
+		report, err = r.count(ctx, tx)
+		if err != nil {
+			return err
+		}
+
+		// Settings first, so the ban list repair also covers the settings created here
+		repairs := []struct {
+			name   string
+			query  string
+			args   []interface{}
+			target *int64
+		}{
+			{
+				name: "create missing settings",
+				query: `
+                    INSERT INTO ` + constants.TableUserSettings + ` (` + constants.ColumnUserID + `, remove_images, theme, detection_threshold,
+                        use_banlist_for_detection, auto_processing, redaction_placeholders, created_at, updated_at)
+                    SELECT u.` + constants.ColumnUserID + `, $1, $2, $3, $4, $5, $6, $7, $7
+                    FROM ` + constants.TableUsers + ` u
+                    WHERE ` + usersWithoutSettingsCondition,
+				args: []interface{}{defaults.RemoveImages, defaults.Theme, defaults.DetectionThreshold,
+					defaults.UseBanlistForDetection, defaults.AutoProcessing, defaults.RedactionPlaceholders, time.Now()},
+				target: &report.UsersWithoutSettings,
+			},
+			{
+				name: "create missing ban lists",
+				query: `
+                    INSERT INTO ` + constants.TableBanLists + ` (` + constants.ColumnSettingID + `)
+                    SELECT s.` + constants.ColumnSettingID + `
+                    FROM ` + constants.TableUserSettings + ` s
+                    WHERE ` + settingsWithoutBanListCondition,
+				target: &report.SettingsWithoutBanList,
+			},
+			{
+				name:   "delete orphaned patterns",
+				query:  `DELETE FROM ` + constants.TableSearchPatterns + ` p WHERE ` + orphanedPatternsCondition,
+				target: &report.OrphanedPatterns,
+			},
+			{
+				name:   "delete orphaned model entities",
+				query:  `DELETE FROM ` + constants.TableModelEntities + ` m WHERE ` + orphanedModelEntitiesCondition,
+				target: &report.OrphanedModelEntities,
+			},
+			{
+				name:   "delete orphaned ban list words",
+				query:  `DELETE FROM ` + constants.TableBanListWords + ` w WHERE ` + orphanedBanListWordsCondition,
+				target: &report.OrphanedBanListWords,
+			},
+		}
+
+		for _, repair := range repairs {
+			// Start query timer
+			startTime := time.Now()
+
+			// Execute the query
+			result, err := tx.ExecContext(ctx, repair.query, repair.args...)
+
+			// Log the query execution
+			utils.LogDBQuery(
+				repair.query,
+				repair.args,
+				time.Since(startTime),
+				err,
+			)
+
+			if err != nil {
+				return fmt.Errorf("failed to %s: %w", repair.name, err)
+			}
+
+			affected, err := result.RowsAffected()
+			if err != nil {
+				return fmt.Errorf("failed to get affected rows: %w", err)
+			}
+			*repair.target = affected
+		}
+
+		report.Repaired = true
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	if report.Repairable() > 0 {
+		log.Info().
+			Int64("users_without_settings", report.UsersWithoutSettings).
+			Int64("settings_without_ban_list", report.SettingsWithoutBanList).
+			Int64("orphaned_patterns", report.OrphanedPatterns).
+			Int64("orphaned_model_entities", report.OrphanedModelEntities).
+			Int64("orphaned_ban_list_words", report.OrphanedBanListWords).
+			Msg("Settings object graph repaired")
+	}
+
+	return report, nil
+}
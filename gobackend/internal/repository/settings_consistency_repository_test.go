@@ -0,0 +1,137 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/yasinhessnawi1/Hideme_Backend/internal/models"
+)
+
+var settingsConsistencyTestColumns = []string{
+	"users_without_settings", "settings_without_ban_list", "orphaned_patterns", "orphaned_model_entities",
+	"orphaned_ban_list_words", "duplicate_settings", "duplicate_ban_lists", "detected_entities_without_method",
+}
+
+func TestNewSettingsConsistencyRepository(t *testing.T) {
+	// Arrange
+	pool, _, cleanup := setupDBMock(t)
+	defer cleanup()
+
+	// Act
+	repo := NewSettingsConsistencyRepository(pool)
+
+	// Assert
+	assert.NotNil(t, repo, "Repository should not be nil")
+	assert.Implements(t, (*SettingsConsistencyRepository)(nil), repo, "Should implement SettingsConsistencyRepository interface")
+}
+
+func TestSettingsConsistencyRepository_Check(t *testing.T) {
+	t.Run("Success", func(t *testing.T) {
+		// Arrange
+		pool, mock, cleanup := setupDBMock(t)
+		defer cleanup()
+		repo := NewSettingsConsistencyRepository(pool)
+
+		mock.ExpectQuery("SELECT \\(SELECT COUNT\\(\\*\\) FROM users u WHERE NOT EXISTS").
+			WillReturnRows(sqlmock.NewRows(settingsConsistencyTestColumns).AddRow(1, 2, 0, 3, 0, 1, 0, 4))
+
+		// Act
+		report, err := repo.Check(context.Background())
+
+		// Assert
+		require.NoError(t, err)
+		assert.Equal(t, int64(1), report.UsersWithoutSettings)
+		assert.Equal(t, int64(2), report.SettingsWithoutBanList)
+		assert.Equal(t, int64(3), report.OrphanedModelEntities)
+		assert.Equal(t, int64(6), report.Repairable())
+		assert.Equal(t, int64(5), report.Unrepairable())
+		assert.False(t, report.Repaired)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("Database error", func(t *testing.T) {
+		// Arrange
+		pool, mock, cleanup := setupDBMock(t)
+		defer cleanup()
+		repo := NewSettingsConsistencyRepository(pool)
+
+		mock.ExpectQuery("SELECT (.+)").WillReturnError(errors.New("connection lost"))
+
+		// Act
+		report, err := repo.Check(context.Background())
+
+		// Assert
+		assert.Error(t, err)
+		assert.Nil(t, report)
+		assert.Contains(t, err.Error(), "failed to check settings consistency")
+	})
+}
+
+func TestSettingsConsistencyRepository_Repair(t *testing.T) {
+	t.Run("Success", func(t *testing.T) {
+		// Arrange
+		pool, mock, cleanup := setupDBMock(t)
+		defer cleanup()
+		repo := NewSettingsConsistencyRepository(pool)
+		defaults := models.NewUserSetting(0)
+
+		mock.ExpectBegin()
+		mock.ExpectQuery("SELECT (.+) FROM users u").
+			WillReturnRows(sqlmock.NewRows(settingsConsistencyTestColumns).AddRow(1, 0, 0, 0, 0, 1, 0, 0))
+		mock.ExpectExec("INSERT INTO user_settings (.+) SELECT u.user_id").
+			WithArgs(defaults.RemoveImages, defaults.Theme, defaults.DetectionThreshold, defaults.UseBanlistForDetection,
+				defaults.AutoProcessing, sqlmock.AnyArg(), sqlmock.AnyArg()).
+			WillReturnResult(sqlmock.NewResult(0, 1))
+		mock.ExpectExec("INSERT INTO ban_lists (.+) SELECT s.setting_id").
+			WillReturnResult(sqlmock.NewResult(0, 2))
+		mock.ExpectExec("DELETE FROM search_patterns p").
+			WillReturnResult(sqlmock.NewResult(0, 0))
+		mock.ExpectExec("DELETE FROM model_entities m").
+			WillReturnResult(sqlmock.NewResult(0, 3))
+		mock.ExpectExec("DELETE FROM ban_list_words w").
+			WillReturnResult(sqlmock.NewResult(0, 0))
+		mock.ExpectCommit()
+
+		// Act
+		report, err := repo.Repair(context.Background(), defaults)
+
+		// Assert
+		require.NoError(t, err)
+		assert.True(t, report.Repaired)
+		assert.Equal(t, int64(1), report.UsersWithoutSettings)
+		assert.Equal(t, int64(2), report.SettingsWithoutBanList, "Ban lists are also created for the new settings")
+		assert.Equal(t, int64(3), report.OrphanedModelEntities)
+		assert.Equal(t, int64(1), report.DuplicateSettings)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("Rolls back on error", func(t *testing.T) {
+		// Arrange
+		pool, mock, cleanup := setupDBMock(t)
+		defer cleanup()
+		repo := NewSettingsConsistencyRepository(pool)
+
+		mock.ExpectBegin()
+		mock.ExpectQuery("SELECT (.+) FROM users u").
+			WillReturnRows(sqlmock.NewRows(settingsConsistencyTestColumns).AddRow(1, 0, 0, 0, 0, 0, 0, 0))
+		mock.ExpectExec("INSERT INTO user_settings").
+			WillReturnResult(sqlmock.NewResult(0, 1))
+		mock.ExpectExec("INSERT INTO ban_lists").
+			WillReturnError(errors.New("connection lost"))
+		mock.ExpectRollback()
+
+		// Act
+		report, err := repo.Repair(context.Background(), models.NewUserSetting(0))
+
+		// Assert
+		assert.Error(t, err)
+		assert.Nil(t, report)
+		assert.Contains(t, err.Error(), "failed to create missing ban lists")
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+}
@@ -21,6 +21,12 @@ import (
 	"github.com/yasinhessnawi1/Hideme_Backend/internal/utils"
 )
 
+var (
+	// ErrSettingsVersionConflict is returned by Update when the settings were updated since
+	// they were read.
+	ErrSettingsVersionConflict = utils.NewKindError(utils.ErrBadRequest, "user settings were updated since they were read")
+)
+
 // SettingsRepository defines methods for interacting with user settings in the database.
 // It provides operations for managing configuration options that control the behavior
 // of document processing and the application UI for each user.
@@ -145,8 +151,8 @@ func (r *PostgresSettingsRepository) Create(ctx context.Context, settings *model
 
 	// Define the query with RETURNING for PostgreSQL
 	query := `
-        INSERT INTO user_settings (user_id, remove_images, theme, detection_threshold, use_banlist_for_detection, auto_processing, created_at, updated_at)
-        VALUES ($1, $2, $3, $4, $5, $6, $7, $8)
+        INSERT INTO user_settings (user_id, remove_images, theme, detection_threshold, use_banlist_for_detection, auto_processing, redaction_placeholders, scrub_filenames, document_retention_days, created_at, updated_at)
+        VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11)
         RETURNING setting_id
     `
 
@@ -160,6 +166,9 @@ func (r *PostgresSettingsRepository) Create(ctx context.Context, settings *model
 		settings.DetectionThreshold,
 		settings.UseBanlistForDetection,
 		settings.AutoProcessing,
+		settings.RedactionPlaceholders,
+		settings.ScrubFilenames,
+		settings.DocumentRetentionDays,
 		settings.CreatedAt,
 		settings.UpdatedAt,
 	).Scan(&settings.ID)
@@ -167,7 +176,7 @@ func (r *PostgresSettingsRepository) Create(ctx context.Context, settings *model
 	// Log the query execution
 	utils.LogDBQuery(
 		query,
-		[]interface{}{settings.UserID, settings.RemoveImages, settings.Theme, settings.DetectionThreshold, settings.UseBanlistForDetection, settings.AutoProcessing, settings.CreatedAt, settings.UpdatedAt},
+		[]interface{}{settings.UserID, settings.RemoveImages, settings.Theme, settings.DetectionThreshold, settings.UseBanlistForDetection, settings.AutoProcessing, settings.RedactionPlaceholders, settings.ScrubFilenames, settings.DocumentRetentionDays, settings.CreatedAt, settings.UpdatedAt},
 		time.Since(startTime),
 		err,
 	)
@@ -175,7 +184,7 @@ func (r *PostgresSettingsRepository) Create(ctx context.Context, settings *model
 	if err != nil {
 		// Check for unique constraint violations
 		if utils.IsDuplicateKeyError(err) {
-			return utils.NewDuplicateError("UserSetting", "user_id", settings.UserID)
+			return utils.NewDuplicateError("UserSetting", "user_id", settings.UserID).WithCause(err)
 		}
 		return fmt.Errorf("failed to create user settings: %w", err)
 	}
@@ -207,7 +216,7 @@ func (r *PostgresSettingsRepository) GetByUserID(ctx context.Context, userID int
 
 	// Define the query
 	query := `
-        SELECT setting_id, user_id, remove_images, theme, detection_threshold, use_banlist_for_detection, auto_processing, created_at, updated_at
+        SELECT setting_id, user_id, remove_images, theme, detection_threshold, use_banlist_for_detection, auto_processing, redaction_placeholders, scrub_filenames, document_retention_days, created_at, updated_at, version
         FROM user_settings
         WHERE user_id = $1
     `
@@ -222,8 +231,12 @@ func (r *PostgresSettingsRepository) GetByUserID(ctx context.Context, userID int
 		&settings.DetectionThreshold,
 		&settings.UseBanlistForDetection,
 		&settings.AutoProcessing,
+		&settings.RedactionPlaceholders,
+		&settings.ScrubFilenames,
+		&settings.DocumentRetentionDays,
 		&settings.CreatedAt,
 		&settings.UpdatedAt,
+		&settings.Version,
 	)
 
 	// Log the query execution
@@ -236,7 +249,7 @@ func (r *PostgresSettingsRepository) GetByUserID(ctx context.Context, userID int
 
 	if err != nil {
 		if errors.Is(err, sql.ErrNoRows) {
-			return nil, utils.NewNotFoundError("UserSetting", fmt.Sprintf("user_id=%d", userID))
+			return nil, utils.NewNotFoundError("UserSetting", fmt.Sprintf("user_id=%d", userID)).WithCause(err)
 		}
 		return nil, fmt.Errorf("failed to get user settings by user ID: %w", err)
 	}
@@ -244,15 +257,17 @@ func (r *PostgresSettingsRepository) GetByUserID(ctx context.Context, userID int
 	return settings, nil
 }
 
-// Update updates user settings in the database.
-// This method automatically updates the UpdatedAt timestamp.
+// Update updates user settings in the database if they are still at the version they
+// were read at. This method automatically updates the UpdatedAt timestamp and increments
+// the version.
 //
 // Parameters:
 //   - ctx: Context for transaction and cancellation control
-//   - settings: The user settings to update
+//   - settings: The user settings to update, with the version they were read at
 //
 // Returns:
 //   - NotFoundError if the settings don't exist
+//   - ErrSettingsVersionConflict if the settings were updated since they were read
 //   - Other errors for database issues
 //   - nil on successful update
 func (r *PostgresSettingsRepository) Update(ctx context.Context, settings *models.UserSetting) error {
@@ -265,8 +280,8 @@ func (r *PostgresSettingsRepository) Update(ctx context.Context, settings *model
 	// Define the query
 	query := `
         UPDATE user_settings
-        SET remove_images = $1, theme = $2, detection_threshold = $3, use_banlist_for_detection = $4, auto_processing = $5, updated_at = $6
-        WHERE setting_id = $7
+        SET remove_images = $1, theme = $2, detection_threshold = $3, use_banlist_for_detection = $4, auto_processing = $5, redaction_placeholders = $6, scrub_filenames = $7, document_retention_days = $8, updated_at = $9, version = version + 1
+        WHERE setting_id = $10 AND version = $11
     `
 
 	// Execute the query
@@ -278,14 +293,18 @@ func (r *PostgresSettingsRepository) Update(ctx context.Context, settings *model
 		settings.DetectionThreshold,
 		settings.UseBanlistForDetection,
 		settings.AutoProcessing,
+		settings.RedactionPlaceholders,
+		settings.ScrubFilenames,
+		settings.DocumentRetentionDays,
 		settings.UpdatedAt,
 		settings.ID,
+		settings.Version,
 	)
 
 	// Log the query execution
 	utils.LogDBQuery(
 		query,
-		[]interface{}{settings.RemoveImages, settings.Theme, settings.AutoProcessing, settings.UpdatedAt, settings.ID},
+		[]interface{}{settings.RemoveImages, settings.Theme, settings.AutoProcessing, settings.UpdatedAt, settings.ID, settings.Version},
 		time.Since(startTime),
 		err,
 	)
@@ -301,12 +320,23 @@ func (r *PostgresSettingsRepository) Update(ctx context.Context, settings *model
 	}
 
 	if rowsAffected == 0 {
+		// Tell settings that were updated since they were read from missing ones
+		var exists bool
+		existsQuery := `SELECT EXISTS(SELECT 1 FROM user_settings WHERE setting_id = $1)`
+		if err := r.db.QueryRowContext(ctx, existsQuery, settings.ID).Scan(&exists); err != nil {
+			return fmt.Errorf("failed to check user settings: %w", err)
+		}
+		if exists {
+			return ErrSettingsVersionConflict
+		}
 		return utils.NewNotFoundError("UserSetting", settings.ID)
 	}
+	settings.Version++
 
 	log.Info().
 		Int64("setting_id", settings.ID).
 		Int64("user_id", settings.UserID).
+		Int64("version", settings.Version).
 		Bool("remove_images", settings.RemoveImages).
 		Str("theme", settings.Theme).
 		Bool("auto_processing", settings.AutoProcessing).
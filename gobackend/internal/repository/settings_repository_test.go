@@ -66,6 +66,9 @@ func TestSettingsRepository_Create(t *testing.T) {
 			settings.DetectionThreshold,
 			settings.UseBanlistForDetection,
 			settings.AutoProcessing,
+			settings.RedactionPlaceholders,
+			settings.ScrubFilenames,
+			settings.DocumentRetentionDays,
 			settings.CreatedAt,
 			settings.UpdatedAt,
 		).
@@ -108,6 +111,9 @@ func TestSettingsRepository_Create_DuplicateError(t *testing.T) {
 			settings.DetectionThreshold,
 			settings.UseBanlistForDetection,
 			settings.AutoProcessing,
+			settings.RedactionPlaceholders,
+			settings.ScrubFilenames,
+			settings.DocumentRetentionDays,
 			sqlmock.AnyArg(), // CreatedAt - accept any timestamp
 			sqlmock.AnyArg(), // UpdatedAt - accept any timestamp
 		).
@@ -152,6 +158,9 @@ func TestSettingsRepository_Create_OtherError(t *testing.T) {
 			settings.DetectionThreshold,
 			settings.UseBanlistForDetection,
 			settings.AutoProcessing,
+			settings.RedactionPlaceholders,
+			settings.ScrubFilenames,
+			settings.DocumentRetentionDays,
 			settings.CreatedAt,
 			settings.UpdatedAt,
 		).
@@ -192,15 +201,15 @@ func TestSettingsRepository_GetByUserID(t *testing.T) {
 	rows := sqlmock.NewRows([]string{
 		"setting_id", "user_id", "remove_images", "theme",
 		"detection_threshold", "use_banlist_for_detection", "auto_processing",
-		"created_at", "updated_at",
+		"redaction_placeholders", "scrub_filenames", "document_retention_days", "created_at", "updated_at", "version",
 	}).AddRow(
 		settings.ID, settings.UserID, settings.RemoveImages, settings.Theme,
 		settings.DetectionThreshold, settings.UseBanlistForDetection, settings.AutoProcessing,
-		settings.CreatedAt, settings.UpdatedAt,
+		[]byte(`{"EMAIL_ADDRESS":"[EMAIL REDACTED]"}`), settings.ScrubFilenames, settings.DocumentRetentionDays, settings.CreatedAt, settings.UpdatedAt, int64(3),
 	)
 
 	// Expected query with placeholder for the user ID
-	mock.ExpectQuery("SELECT setting_id, user_id, remove_images, theme, detection_threshold, use_banlist_for_detection, auto_processing, created_at, updated_at FROM user_settings WHERE user_id = \\$1").
+	mock.ExpectQuery("SELECT setting_id, user_id, remove_images, theme, detection_threshold, use_banlist_for_detection, auto_processing, redaction_placeholders, scrub_filenames, document_retention_days, created_at, updated_at, version FROM user_settings WHERE user_id = \\$1").
 		WithArgs(userID).
 		WillReturnRows(rows)
 
@@ -210,6 +219,7 @@ func TestSettingsRepository_GetByUserID(t *testing.T) {
 	// Assert the results
 	assert.NoError(t, err)
 	assert.Equal(t, settings.ID, result.ID)
+	assert.Equal(t, "[EMAIL REDACTED]", result.RedactionPlaceholders["EMAIL_ADDRESS"])
 	assert.Equal(t, settings.UserID, result.UserID)
 	assert.Equal(t, settings.RemoveImages, result.RemoveImages)
 	assert.Equal(t, settings.Theme, result.Theme)
@@ -218,6 +228,7 @@ func TestSettingsRepository_GetByUserID(t *testing.T) {
 	assert.Equal(t, settings.AutoProcessing, result.AutoProcessing)
 	assert.WithinDuration(t, settings.CreatedAt, result.CreatedAt, time.Second)
 	assert.WithinDuration(t, settings.UpdatedAt, result.UpdatedAt, time.Second)
+	assert.Equal(t, int64(3), result.Version)
 	assert.NoError(t, mock.ExpectationsWereMet())
 }
 
@@ -230,7 +241,7 @@ func TestSettingsRepository_GetByUserID_NotFound(t *testing.T) {
 	userID := int64(999)
 
 	// Mock database response - empty result
-	mock.ExpectQuery("SELECT setting_id, user_id, remove_images, theme, detection_threshold, use_banlist_for_detection, auto_processing, created_at, updated_at FROM user_settings WHERE user_id = \\$1").
+	mock.ExpectQuery("SELECT setting_id, user_id, remove_images, theme, detection_threshold, use_banlist_for_detection, auto_processing, redaction_placeholders, scrub_filenames, document_retention_days, created_at, updated_at, version FROM user_settings WHERE user_id = \\$1").
 		WithArgs(userID).
 		WillReturnError(sql.ErrNoRows)
 
@@ -254,7 +265,7 @@ func TestSettingsRepository_GetByUserID_OtherError(t *testing.T) {
 	// Mock a different database error
 	otherErr := errors.New("database query failed")
 
-	mock.ExpectQuery("SELECT setting_id, user_id, remove_images, theme, detection_threshold, use_banlist_for_detection, auto_processing, created_at, updated_at FROM user_settings WHERE user_id = \\$1").
+	mock.ExpectQuery("SELECT setting_id, user_id, remove_images, theme, detection_threshold, use_banlist_for_detection, auto_processing, redaction_placeholders, scrub_filenames, document_retention_days, created_at, updated_at, version FROM user_settings WHERE user_id = \\$1").
 		WithArgs(userID).
 		WillReturnError(otherErr)
 
@@ -285,18 +296,23 @@ func TestSettingsRepository_Update(t *testing.T) {
 		AutoProcessing:         false,
 		CreatedAt:              now.Add(-time.Hour),
 		UpdatedAt:              now,
+		Version:                3,
 	}
 
 	// Expected query with placeholders
-	mock.ExpectExec("UPDATE user_settings SET remove_images = \\$1, theme = \\$2, detection_threshold = \\$3, use_banlist_for_detection = \\$4, auto_processing = \\$5, updated_at = \\$6 WHERE setting_id = \\$7").
+	mock.ExpectExec("UPDATE user_settings SET remove_images = \\$1, theme = \\$2, detection_threshold = \\$3, use_banlist_for_detection = \\$4, auto_processing = \\$5, redaction_placeholders = \\$6, scrub_filenames = \\$7, document_retention_days = \\$8, updated_at = \\$9, version = version \\+ 1 WHERE setting_id = \\$10 AND version = \\$11").
 		WithArgs(
 			settings.RemoveImages,
 			settings.Theme,
 			settings.DetectionThreshold,
 			settings.UseBanlistForDetection,
 			settings.AutoProcessing,
+			settings.RedactionPlaceholders,
+			settings.ScrubFilenames,
+			settings.DocumentRetentionDays,
 			sqlmock.AnyArg(),
 			settings.ID,
+			settings.Version,
 		).
 		WillReturnResult(sqlmock.NewResult(0, 1))
 
@@ -305,6 +321,7 @@ func TestSettingsRepository_Update(t *testing.T) {
 
 	// Assert the results
 	assert.NoError(t, err)
+	assert.Equal(t, int64(4), settings.Version)
 	assert.NoError(t, mock.ExpectationsWereMet())
 }
 
@@ -328,17 +345,24 @@ func TestSettingsRepository_Update_NotFound(t *testing.T) {
 	}
 
 	// Expected query with placeholders, but no rows affected
-	mock.ExpectExec("UPDATE user_settings SET remove_images = \\$1, theme = \\$2, detection_threshold = \\$3, use_banlist_for_detection = \\$4, auto_processing = \\$5, updated_at = \\$6 WHERE setting_id = \\$7").
+	mock.ExpectExec("UPDATE user_settings SET remove_images = \\$1, theme = \\$2, detection_threshold = \\$3, use_banlist_for_detection = \\$4, auto_processing = \\$5, redaction_placeholders = \\$6, scrub_filenames = \\$7, document_retention_days = \\$8, updated_at = \\$9, version = version \\+ 1 WHERE setting_id = \\$10 AND version = \\$11").
 		WithArgs(
 			settings.RemoveImages,
 			settings.Theme,
 			settings.DetectionThreshold,
 			settings.UseBanlistForDetection,
 			settings.AutoProcessing,
+			settings.RedactionPlaceholders,
+			settings.ScrubFilenames,
+			settings.DocumentRetentionDays,
 			sqlmock.AnyArg(),
 			settings.ID,
+			settings.Version,
 		).
 		WillReturnResult(sqlmock.NewResult(0, 0))
+	mock.ExpectQuery("SELECT EXISTS\\(SELECT 1 FROM user_settings WHERE setting_id = \\$1\\)").
+		WithArgs(settings.ID).
+		WillReturnRows(sqlmock.NewRows([]string{"exists"}).AddRow(false))
 
 	// Execute the method being tested
 	err := repo.Update(context.Background(), settings)
@@ -349,6 +373,53 @@ func TestSettingsRepository_Update_NotFound(t *testing.T) {
 	assert.NoError(t, mock.ExpectationsWereMet())
 }
 
+func TestSettingsRepository_Update_VersionConflict(t *testing.T) {
+	// Set up the test
+	repo, mock, cleanup := setupSettingsRepositoryTest(t)
+	defer cleanup()
+
+	// Set up test data
+	now := time.Now()
+	settings := &models.UserSetting{
+		ID:                     1,
+		UserID:                 100,
+		RemoveImages:           true,
+		Theme:                  "dark",
+		DetectionThreshold:     0.8,
+		UseBanlistForDetection: false,
+		AutoProcessing:         false,
+		CreatedAt:              now.Add(-time.Hour),
+		UpdatedAt:              now,
+	}
+
+	// Expected query with placeholders, but the settings were updated since they were read
+	mock.ExpectExec("UPDATE user_settings SET remove_images = \\$1, theme = \\$2, detection_threshold = \\$3, use_banlist_for_detection = \\$4, auto_processing = \\$5, redaction_placeholders = \\$6, scrub_filenames = \\$7, document_retention_days = \\$8, updated_at = \\$9, version = version \\+ 1 WHERE setting_id = \\$10 AND version = \\$11").
+		WithArgs(
+			settings.RemoveImages,
+			settings.Theme,
+			settings.DetectionThreshold,
+			settings.UseBanlistForDetection,
+			settings.AutoProcessing,
+			settings.RedactionPlaceholders,
+			settings.ScrubFilenames,
+			settings.DocumentRetentionDays,
+			sqlmock.AnyArg(),
+			settings.ID,
+			settings.Version,
+		).
+		WillReturnResult(sqlmock.NewResult(0, 0))
+	mock.ExpectQuery("SELECT EXISTS\\(SELECT 1 FROM user_settings WHERE setting_id = \\$1\\)").
+		WithArgs(settings.ID).
+		WillReturnRows(sqlmock.NewRows([]string{"exists"}).AddRow(true))
+
+	// Execute the method being tested
+	err := repo.Update(context.Background(), settings)
+
+	// Assert the results
+	assert.ErrorIs(t, err, repository.ErrSettingsVersionConflict)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
 func TestSettingsRepository_Update_ErrorGettingRowsAffected(t *testing.T) {
 	// Set up the test
 	repo, mock, cleanup := setupSettingsRepositoryTest(t)
@@ -372,15 +443,19 @@ func TestSettingsRepository_Update_ErrorGettingRowsAffected(t *testing.T) {
 	result := sqlmock.NewErrorResult(errors.New("rows affected error"))
 
 	// Expected query with placeholders
-	mock.ExpectExec("UPDATE user_settings SET remove_images = \\$1, theme = \\$2, detection_threshold = \\$3, use_banlist_for_detection = \\$4, auto_processing = \\$5, updated_at = \\$6 WHERE setting_id = \\$7").
+	mock.ExpectExec("UPDATE user_settings SET remove_images = \\$1, theme = \\$2, detection_threshold = \\$3, use_banlist_for_detection = \\$4, auto_processing = \\$5, redaction_placeholders = \\$6, scrub_filenames = \\$7, document_retention_days = \\$8, updated_at = \\$9, version = version \\+ 1 WHERE setting_id = \\$10 AND version = \\$11").
 		WithArgs(
 			settings.RemoveImages,
 			settings.Theme,
 			settings.DetectionThreshold,
 			settings.UseBanlistForDetection,
 			settings.AutoProcessing,
+			settings.RedactionPlaceholders,
+			settings.ScrubFilenames,
+			settings.DocumentRetentionDays,
 			sqlmock.AnyArg(),
 			settings.ID,
+			settings.Version,
 		).
 		WillReturnResult(result)
 
@@ -416,15 +491,19 @@ func TestSettingsRepository_Update_ExecError(t *testing.T) {
 	execErr := errors.New("exec error")
 
 	// Expected query with placeholders
-	mock.ExpectExec("UPDATE user_settings SET remove_images = \\$1, theme = \\$2, detection_threshold = \\$3, use_banlist_for_detection = \\$4, auto_processing = \\$5, updated_at = \\$6 WHERE setting_id = \\$7").
+	mock.ExpectExec("UPDATE user_settings SET remove_images = \\$1, theme = \\$2, detection_threshold = \\$3, use_banlist_for_detection = \\$4, auto_processing = \\$5, redaction_placeholders = \\$6, scrub_filenames = \\$7, document_retention_days = \\$8, updated_at = \\$9, version = version \\+ 1 WHERE setting_id = \\$10 AND version = \\$11").
 		WithArgs(
 			settings.RemoveImages,
 			settings.Theme,
 			settings.DetectionThreshold,
 			settings.UseBanlistForDetection,
 			settings.AutoProcessing,
+			settings.RedactionPlaceholders,
+			settings.ScrubFilenames,
+			settings.DocumentRetentionDays,
 			sqlmock.AnyArg(),
 			settings.ID,
+			settings.Version,
 		).
 		WillReturnError(execErr)
 
@@ -647,15 +726,15 @@ func TestSettingsRepository_EnsureDefaultSettings_Existing(t *testing.T) {
 	rows := sqlmock.NewRows([]string{
 		"setting_id", "user_id", "remove_images", "theme",
 		"detection_threshold", "use_banlist_for_detection", "auto_processing",
-		"created_at", "updated_at",
+		"redaction_placeholders", "scrub_filenames", "document_retention_days", "created_at", "updated_at", "version",
 	}).AddRow(
 		settings.ID, settings.UserID, settings.RemoveImages, settings.Theme,
 		settings.DetectionThreshold, settings.UseBanlistForDetection, settings.AutoProcessing,
-		settings.CreatedAt, settings.UpdatedAt,
+		[]byte(`{"EMAIL_ADDRESS":"[EMAIL REDACTED]"}`), settings.ScrubFilenames, settings.DocumentRetentionDays, settings.CreatedAt, settings.UpdatedAt, int64(3),
 	)
 
 	// Expected query with placeholder for the user ID
-	mock.ExpectQuery("SELECT setting_id, user_id, remove_images, theme, detection_threshold, use_banlist_for_detection, auto_processing, created_at, updated_at FROM user_settings WHERE user_id = \\$1").
+	mock.ExpectQuery("SELECT setting_id, user_id, remove_images, theme, detection_threshold, use_banlist_for_detection, auto_processing, redaction_placeholders, scrub_filenames, document_retention_days, created_at, updated_at, version FROM user_settings WHERE user_id = \\$1").
 		WithArgs(userID).
 		WillReturnRows(rows)
 
@@ -685,7 +764,7 @@ func TestSettingsRepository_EnsureDefaultSettings_Create(t *testing.T) {
 	userID := int64(100)
 
 	// Mock a "not found" error for the first GetByUserID call
-	mock.ExpectQuery("SELECT setting_id, user_id, remove_images, theme, detection_threshold, use_banlist_for_detection, auto_processing, created_at, updated_at FROM user_settings WHERE user_id = \\$1").
+	mock.ExpectQuery("SELECT setting_id, user_id, remove_images, theme, detection_threshold, use_banlist_for_detection, auto_processing, redaction_placeholders, scrub_filenames, document_retention_days, created_at, updated_at, version FROM user_settings WHERE user_id = \\$1").
 		WithArgs(userID).
 		WillReturnError(sql.ErrNoRows)
 
@@ -699,6 +778,9 @@ func TestSettingsRepository_EnsureDefaultSettings_Create(t *testing.T) {
 			0.5,              // Default DetectionThreshold
 			true,             // Default UseBanlistForDetection
 			true,             // Default AutoProcessing
+			[]byte("{}"),     // Default RedactionPlaceholders
+			false,            // Default ScrubFilenames
+			0,                // Default DocumentRetentionDays
 			sqlmock.AnyArg(), // CreatedAt
 			sqlmock.AnyArg(), // UpdatedAt
 		).
@@ -729,7 +811,7 @@ func TestSettingsRepository_EnsureDefaultSettings_CreateError(t *testing.T) {
 	userID := int64(100)
 
 	// Mock a "not found" error for the first GetByUserID call
-	mock.ExpectQuery("SELECT setting_id, user_id, remove_images, theme, detection_threshold, use_banlist_for_detection, auto_processing, created_at, updated_at FROM user_settings WHERE user_id = \\$1").
+	mock.ExpectQuery("SELECT setting_id, user_id, remove_images, theme, detection_threshold, use_banlist_for_detection, auto_processing, redaction_placeholders, scrub_filenames, document_retention_days, created_at, updated_at, version FROM user_settings WHERE user_id = \\$1").
 		WithArgs(userID).
 		WillReturnError(sql.ErrNoRows)
 
@@ -745,6 +827,9 @@ func TestSettingsRepository_EnsureDefaultSettings_CreateError(t *testing.T) {
 			sqlmock.AnyArg(),
 			sqlmock.AnyArg(),
 			sqlmock.AnyArg(),
+			sqlmock.AnyArg(),
+			sqlmock.AnyArg(),
+			sqlmock.AnyArg(),
 		).
 		WillReturnError(createErr)
 
@@ -768,7 +853,7 @@ func TestSettingsRepository_EnsureDefaultSettings_OtherError(t *testing.T) {
 
 	// Mock an unexpected database error (not sql.ErrNoRows)
 	dbErr := errors.New("database connection error")
-	mock.ExpectQuery("SELECT setting_id, user_id, remove_images, theme, detection_threshold, use_banlist_for_detection, auto_processing, created_at, updated_at FROM user_settings WHERE user_id = \\$1").
+	mock.ExpectQuery("SELECT setting_id, user_id, remove_images, theme, detection_threshold, use_banlist_for_detection, auto_processing, redaction_placeholders, scrub_filenames, document_retention_days, created_at, updated_at, version FROM user_settings WHERE user_id = \\$1").
 		WithArgs(userID).
 		WillReturnError(dbErr)
 
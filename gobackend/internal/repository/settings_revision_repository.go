@@ -0,0 +1,272 @@
+// Package repository provides data access interfaces and implementations for the HideMe application.
+// It follows the repository pattern to abstract database operations and provide a clean API
+// for data persistence operations.
+//
+// This file implements the settings revision repository, which stores the append-only
+// history of settings changes used by the settings change feed.
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/yasinhessnawi1/Hideme_Backend/internal/database"
+	"github.com/yasinhessnawi1/Hideme_Backend/internal/models"
+	"github.com/yasinhessnawi1/Hideme_Backend/internal/utils"
+)
+
+// SettingsRevisionRepository defines methods for interacting with the settings revision history.
+// Revisions are append-only; they are never updated, and are only removed together with the user.
+type SettingsRevisionRepository interface {
+	// Record appends revisions to the history.
+	//
+	// Parameters:
+	//   - ctx: Context for transaction and cancellation control
+	//   - revisions: The revisions of one user to store, in the order they happened
+	//
+	// Returns:
+	//   - An error if the revisions could not be stored
+	//
+	// All revisions are stored in a single transaction, which joins the transaction
+	// carried by ctx, and their revision numbers are populated after a successful insert.
+	// The user's settings row stays locked until that transaction ends, so revision
+	// numbers of a user are handed out in the order their transactions commit.
+	Record(ctx context.Context, revisions ...*models.SettingsRevision) error
+
+	// GetSince retrieves the revisions for a user that are newer than a given revision.
+	// Because Record commits the revisions of a user in revision order, a revision that
+	// commits later never has a number below one that was already returned.
+	//
+	// Parameters:
+	//   - ctx: Context for transaction and cancellation control
+	//   - userID: The unique identifier of the user
+	//   - since: The last revision the client has already applied
+	//   - limit: The maximum number of revisions to return
+	//
+	// Returns:
+	//   - The revisions in ascending order (empty if there are none)
+	//   - An error for database issues
+	GetSince(ctx context.Context, userID int64, since int64, limit int) ([]*models.SettingsRevision, error)
+
+	// GetSettingsVersion retrieves a user's general settings as they were at a version,
+	// from the snapshot recorded by the update that produced it.
+	//
+	// Parameters:
+	//   - ctx: Context for transaction and cancellation control
+	//   - userID: The unique identifier of the user
+	//   - version: The version of the settings
+	//
+	// Returns:
+	//   - The settings at the version
+	//   - NotFoundError if no snapshot of the version was recorded
+	//   - An error for database issues
+	GetSettingsVersion(ctx context.Context, userID int64, version int64) (*models.UserSetting, error)
+}
+
+// PostgresSettingsRevisionRepository is a PostgreSQL implementation of SettingsRevisionRepository.
+type PostgresSettingsRevisionRepository struct {
+	db *database.Pool
+}
+
+// NewSettingsRevisionRepository creates a new SettingsRevisionRepository implementation for PostgreSQL.
+//
+// Parameters:
+//   - db: A connection pool for PostgreSQL database access
+//
+// Returns:
+//   - An implementation of the SettingsRevisionRepository interface
+func NewSettingsRevisionRepository(db *database.Pool) SettingsRevisionRepository {
+	return &PostgresSettingsRevisionRepository{
+		db: db,
+	}
+}
+
+// Record appends revisions to the history within a single transaction.
+// Revision numbers come from an identity column, which hands them out when a row is
+// inserted rather than when it commits. Locking the user's settings row before the
+// inserts keeps a second transaction of the same user from taking numbers until the
+// first one has committed, so the change feed can't skip a late commit.
+//
+// Parameters:
+//   - ctx: Context for transaction and cancellation control
+//   - revisions: The revisions of one user to store
+//
+// Returns:
+//   - An error if the revisions could not be stored
+func (r *PostgresSettingsRevisionRepository) Record(ctx context.Context, revisions ...*models.SettingsRevision) error {
+	if len(revisions) == 0 {
+		return nil
+	}
+
+	// Start query timer
+	startTime := time.Now()
+
+	return r.db.Transaction(ctx, func(tx *sql.Tx) error {
+		lockQuery := `SELECT setting_id FROM user_settings WHERE user_id = $1 FOR UPDATE`
+		var settingID int64
+		if err := tx.QueryRowContext(ctx, lockQuery, revisions[0].UserID).Scan(&settingID); err != nil && !errors.Is(err, sql.ErrNoRows) {
+			return fmt.Errorf("failed to lock user settings: %w", err)
+		}
+
+		query := `
+            INSERT INTO settings_revisions (user_id, resource_type, resource_id, action, data, created_at)
+            VALUES ($1, $2, $3, $4, $5, $6)
+            RETURNING revision_id
+        `
+
+		for _, revision := range revisions {
+			// JSONB columns need a NULL rather than an empty byte slice
+			var data interface{}
+			if len(revision.Data) > 0 {
+				data = []byte(revision.Data)
+			}
+
+			err := tx.QueryRowContext(
+				ctx,
+				query,
+				revision.UserID,
+				revision.ResourceType,
+				revision.ResourceID,
+				revision.Action,
+				data,
+				revision.CreatedAt,
+			).Scan(&revision.Revision)
+			if err != nil {
+				return fmt.Errorf("failed to record settings revision: %w", err)
+			}
+		}
+
+		// Log the operation
+		utils.LogDBQuery(
+			fmt.Sprintf("Recorded %d settings revisions", len(revisions)),
+			[]interface{}{revisions[0].UserID},
+			time.Since(startTime),
+			nil,
+		)
+
+		return nil
+	})
+}
+
+// GetSince retrieves the revisions for a user that are newer than a given revision.
+//
+// Parameters:
+//   - ctx: Context for transaction and cancellation control
+//   - userID: The unique identifier of the user
+//   - since: The last revision the client has already applied
+//   - limit: The maximum number of revisions to return
+//
+// Returns:
+//   - The revisions in ascending order
+//   - An error for database issues
+func (r *PostgresSettingsRevisionRepository) GetSince(ctx context.Context, userID int64, since int64, limit int) ([]*models.SettingsRevision, error) {
+	// Start query timer
+	startTime := time.Now()
+
+	// Define the query
+	query := `
+        SELECT revision_id, user_id, resource_type, resource_id, action, data, created_at
+        FROM settings_revisions
+        WHERE user_id = $1 AND revision_id > $2
+        ORDER BY revision_id ASC
+        LIMIT $3
+    `
+
+	// Execute the query
+	rows, err := r.db.QueryContext(ctx, query, userID, since, limit)
+
+	// Log the query execution
+	utils.LogDBQuery(
+		query,
+		[]interface{}{userID, since, limit},
+		time.Since(startTime),
+		err,
+	)
+
+	if err != nil {
+		return nil, fmt.Errorf("failed to get settings revisions: %w", err)
+	}
+	defer rows.Close()
+
+	revisions := []*models.SettingsRevision{}
+	for rows.Next() {
+		revision := &models.SettingsRevision{}
+		var data []byte
+		if err := rows.Scan(
+			&revision.Revision,
+			&revision.UserID,
+			&revision.ResourceType,
+			&revision.ResourceID,
+			&revision.Action,
+			&data,
+			&revision.CreatedAt,
+		); err != nil {
+			return nil, fmt.Errorf("failed to scan settings revision row: %w", err)
+		}
+		if len(data) > 0 {
+			revision.Data = data
+		}
+		revisions = append(revisions, revision)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating settings revision rows: %w", err)
+	}
+
+	return revisions, nil
+}
+
+// GetSettingsVersion retrieves a user's general settings as they were at a version.
+//
+// Parameters:
+//   - ctx: Context for transaction and cancellation control
+//   - userID: The unique identifier of the user
+//   - version: The version of the settings
+//
+// Returns:
+//   - The settings at the version
+//   - NotFoundError if no snapshot of the version was recorded
+//   - An error for database issues
+func (r *PostgresSettingsRevisionRepository) GetSettingsVersion(ctx context.Context, userID int64, version int64) (*models.UserSetting, error) {
+	// Start query timer
+	startTime := time.Now()
+
+	// Define the query
+	query := `
+        SELECT data
+        FROM settings_revisions
+        WHERE user_id = $1 AND resource_type = $2 AND (data->>'version')::bigint = $3
+        ORDER BY revision_id DESC
+        LIMIT 1
+    `
+
+	// Execute the query
+	var data []byte
+	err := r.db.QueryRowContext(ctx, query, userID, models.ResourceGeneralSettings, version).Scan(&data)
+
+	// Log the query execution
+	utils.LogDBQuery(
+		query,
+		[]interface{}{userID, version},
+		time.Since(startTime),
+		err,
+	)
+
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, utils.NewNotFoundError("UserSettingVersion", version)
+		}
+		return nil, fmt.Errorf("failed to get settings version: %w", err)
+	}
+
+	settings := &models.UserSetting{}
+	if err := json.Unmarshal(data, settings); err != nil {
+		return nil, fmt.Errorf("failed to decode settings version: %w", err)
+	}
+
+	return settings, nil
+}
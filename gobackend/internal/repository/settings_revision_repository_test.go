@@ -0,0 +1,247 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/yasinhessnawi1/Hideme_Backend/internal/models"
+	"github.com/yasinhessnawi1/Hideme_Backend/internal/utils"
+)
+
+func TestNewSettingsRevisionRepository(t *testing.T) {
+	// Arrange
+	pool, _, cleanup := setupDBMock(t)
+	defer cleanup()
+
+	// Act
+	repo := NewSettingsRevisionRepository(pool)
+
+	// Assert
+	assert.NotNil(t, repo, "Repository should not be nil")
+	assert.Implements(t, (*SettingsRevisionRepository)(nil), repo, "Should implement SettingsRevisionRepository interface")
+}
+
+func TestSettingsRevisionRepository_Record(t *testing.T) {
+	t.Run("Success", func(t *testing.T) {
+		// Arrange
+		pool, mock, cleanup := setupDBMock(t)
+		defer cleanup()
+		repo := NewSettingsRevisionRepository(pool)
+
+		ctx := context.Background()
+		created := models.NewSettingsRevision(1, models.ResourceBanListWord, 10, models.RevisionCreated, map[string]string{"word": "alpha"})
+		deleted := models.NewSettingsRevision(1, models.ResourceSearchPattern, 20, models.RevisionDeleted, nil)
+
+		mock.ExpectBegin()
+		mock.ExpectQuery("SELECT setting_id FROM user_settings WHERE user_id = \\$1 FOR UPDATE").
+			WithArgs(created.UserID).
+			WillReturnRows(sqlmock.NewRows([]string{"setting_id"}).AddRow(3))
+		mock.ExpectQuery("INSERT INTO settings_revisions").
+			WithArgs(created.UserID, created.ResourceType, created.ResourceID, created.Action, []byte(created.Data), created.CreatedAt).
+			WillReturnRows(sqlmock.NewRows([]string{"revision_id"}).AddRow(7))
+		mock.ExpectQuery("INSERT INTO settings_revisions").
+			WithArgs(deleted.UserID, deleted.ResourceType, deleted.ResourceID, deleted.Action, nil, deleted.CreatedAt).
+			WillReturnRows(sqlmock.NewRows([]string{"revision_id"}).AddRow(8))
+		mock.ExpectCommit()
+
+		// Act
+		err := repo.Record(ctx, created, deleted)
+
+		// Assert
+		assert.NoError(t, err)
+		assert.Equal(t, int64(7), created.Revision)
+		assert.Equal(t, int64(8), deleted.Revision)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("No Revisions", func(t *testing.T) {
+		// Arrange
+		pool, mock, cleanup := setupDBMock(t)
+		defer cleanup()
+		repo := NewSettingsRevisionRepository(pool)
+
+		// Act
+		err := repo.Record(context.Background())
+
+		// Assert
+		assert.NoError(t, err)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("Database Error", func(t *testing.T) {
+		// Arrange
+		pool, mock, cleanup := setupDBMock(t)
+		defer cleanup()
+		repo := NewSettingsRevisionRepository(pool)
+
+		revision := models.NewSettingsRevision(1, models.ResourceGeneralSettings, 1, models.RevisionUpdated, nil)
+
+		mock.ExpectBegin()
+		mock.ExpectQuery("SELECT setting_id FROM user_settings").
+			WithArgs(revision.UserID).
+			WillReturnError(sql.ErrNoRows)
+		mock.ExpectQuery("INSERT INTO settings_revisions").
+			WillReturnError(errors.New("database error"))
+		mock.ExpectRollback()
+
+		// Act
+		err := repo.Record(context.Background(), revision)
+
+		// Assert
+		assert.Error(t, err)
+		assert.Contains(t, err.Error(), "failed to record settings revision")
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+}
+
+func TestSettingsRevisionRepository_RecordInTransaction(t *testing.T) {
+	// Arrange
+	pool, mock, cleanup := setupDBMock(t)
+	defer cleanup()
+	repo := NewSettingsRevisionRepository(pool)
+
+	revision := models.NewSettingsRevision(1, models.ResourceSearchPattern, 20, models.RevisionDeleted, nil)
+
+	// The settings change and its revision share one transaction
+	mock.ExpectBegin()
+	mock.ExpectExec("DELETE FROM search_patterns").
+		WithArgs(int64(20)).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectQuery("SELECT setting_id FROM user_settings").
+		WithArgs(revision.UserID).
+		WillReturnRows(sqlmock.NewRows([]string{"setting_id"}).AddRow(3))
+	mock.ExpectQuery("INSERT INTO settings_revisions").
+		WillReturnRows(sqlmock.NewRows([]string{"revision_id"}).AddRow(9))
+	mock.ExpectCommit()
+
+	// Act
+	err := pool.WithTransaction(context.Background(), func(ctx context.Context) error {
+		if _, err := pool.ExecContext(ctx, "DELETE FROM search_patterns WHERE pattern_id = $1", int64(20)); err != nil {
+			return err
+		}
+		return repo.Record(ctx, revision)
+	})
+
+	// Assert
+	assert.NoError(t, err)
+	assert.Equal(t, int64(9), revision.Revision)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestSettingsRevisionRepository_GetSince(t *testing.T) {
+	t.Run("Success With Results", func(t *testing.T) {
+		// Arrange
+		pool, mock, cleanup := setupDBMock(t)
+		defer cleanup()
+		repo := NewSettingsRevisionRepository(pool)
+
+		now := time.Now()
+		rows := sqlmock.NewRows([]string{"revision_id", "user_id", "resource_type", "resource_id", "action", "data", "created_at"}).
+			AddRow(4, 1, "ban_list_word", 10, "created", []byte(`{"word":"alpha"}`), now).
+			AddRow(5, 1, "search_pattern", 20, "deleted", nil, now)
+
+		mock.ExpectQuery("SELECT (.+) FROM settings_revisions WHERE user_id = \\$1 AND revision_id > \\$2").
+			WithArgs(int64(1), int64(3), 100).
+			WillReturnRows(rows)
+
+		// Act
+		revisions, err := repo.GetSince(context.Background(), 1, 3, 100)
+
+		// Assert
+		require.NoError(t, err)
+		require.Len(t, revisions, 2)
+		assert.Equal(t, int64(4), revisions[0].Revision)
+		assert.Equal(t, models.ResourceBanListWord, revisions[0].ResourceType)
+		assert.JSONEq(t, `{"word":"alpha"}`, string(revisions[0].Data))
+		assert.Equal(t, models.RevisionDeleted, revisions[1].Action)
+		assert.Nil(t, revisions[1].Data)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("Empty Result", func(t *testing.T) {
+		// Arrange
+		pool, mock, cleanup := setupDBMock(t)
+		defer cleanup()
+		repo := NewSettingsRevisionRepository(pool)
+
+		mock.ExpectQuery("SELECT (.+) FROM settings_revisions").
+			WithArgs(int64(1), int64(0), 100).
+			WillReturnRows(sqlmock.NewRows([]string{"revision_id", "user_id", "resource_type", "resource_id", "action", "data", "created_at"}))
+
+		// Act
+		revisions, err := repo.GetSince(context.Background(), 1, 0, 100)
+
+		// Assert
+		assert.NoError(t, err)
+		assert.NotNil(t, revisions)
+		assert.Empty(t, revisions)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("Database Error", func(t *testing.T) {
+		// Arrange
+		pool, mock, cleanup := setupDBMock(t)
+		defer cleanup()
+		repo := NewSettingsRevisionRepository(pool)
+
+		mock.ExpectQuery("SELECT (.+) FROM settings_revisions").
+			WillReturnError(errors.New("database error"))
+
+		// Act
+		revisions, err := repo.GetSince(context.Background(), 1, 0, 100)
+
+		// Assert
+		assert.Error(t, err)
+		assert.Nil(t, revisions)
+		assert.Contains(t, err.Error(), "failed to get settings revisions")
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+}
+
+func TestSettingsRevisionRepository_GetSettingsVersion(t *testing.T) {
+	t.Run("Success", func(t *testing.T) {
+		// Arrange
+		pool, mock, cleanup := setupDBMock(t)
+		defer cleanup()
+		repo := NewSettingsRevisionRepository(pool)
+
+		mock.ExpectQuery("SELECT data FROM settings_revisions WHERE user_id = \\$1 AND resource_type = \\$2 AND \\(data->>'version'\\)::bigint = \\$3").
+			WithArgs(int64(1), models.ResourceGeneralSettings, int64(3)).
+			WillReturnRows(sqlmock.NewRows([]string{"data"}).AddRow([]byte(`{"id":5,"user_id":1,"theme":"dark","version":3}`)))
+
+		// Act
+		settings, err := repo.GetSettingsVersion(context.Background(), 1, 3)
+
+		// Assert
+		require.NoError(t, err)
+		assert.Equal(t, "dark", settings.Theme)
+		assert.Equal(t, int64(3), settings.Version)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("Not Recorded", func(t *testing.T) {
+		// Arrange
+		pool, mock, cleanup := setupDBMock(t)
+		defer cleanup()
+		repo := NewSettingsRevisionRepository(pool)
+
+		mock.ExpectQuery("SELECT data FROM settings_revisions").
+			WithArgs(int64(1), models.ResourceGeneralSettings, int64(1)).
+			WillReturnRows(sqlmock.NewRows([]string{"data"}))
+
+		// Act
+		settings, err := repo.GetSettingsVersion(context.Background(), 1, 1)
+
+		// Assert
+		assert.Nil(t, settings)
+		assert.True(t, utils.IsNotFoundError(err))
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+}
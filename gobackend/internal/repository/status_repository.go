@@ -0,0 +1,579 @@
+// Package repository provides data access interfaces and implementations for the HideMe application.
+// It follows the repository pattern to abstract database operations and provide a clean API
+// for data persistence operations.
+//
+// This file implements the status repository, which stores the incident notes and the
+// hourly health check rollups shown on the public status page.
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/yasinhessnawi1/Hideme_Backend/internal/database"
+	"github.com/yasinhessnawi1/Hideme_Backend/internal/models"
+	"github.com/yasinhessnawi1/Hideme_Backend/internal/utils"
+)
+
+// StatusRepository defines methods for managing status page incidents and component uptime.
+type StatusRepository interface {
+	// CreateIncident stores a new incident.
+	//
+	// Parameters:
+	//   - ctx: Context for transaction and cancellation control
+	//   - incident: The incident to store; its ID is set on success
+	//
+	// Returns:
+	//   - An error for database issues
+	CreateIncident(ctx context.Context, incident *models.StatusIncident) error
+
+	// GetIncidentByID retrieves an incident by its ID.
+	//
+	// Parameters:
+	//   - ctx: Context for transaction and cancellation control
+	//   - id: The ID of the incident
+	//
+	// Returns:
+	//   - The incident
+	//   - NotFoundError if the incident doesn't exist
+	//   - Other errors for database issues
+	GetIncidentByID(ctx context.Context, id int64) (*models.StatusIncident, error)
+
+	// ListIncidents retrieves the most recent incidents, resolved or not.
+	//
+	// Parameters:
+	//   - ctx: Context for transaction and cancellation control
+	//   - limit: The maximum number of incidents to return
+	//
+	// Returns:
+	//   - The incidents, newest first
+	//   - An error for database issues
+	ListIncidents(ctx context.Context, limit int) ([]*models.StatusIncident, error)
+
+	// ListActiveIncidents retrieves all incidents that are not resolved yet.
+	//
+	// Parameters:
+	//   - ctx: Context for transaction and cancellation control
+	//
+	// Returns:
+	//   - The unresolved incidents, newest first
+	//   - An error for database issues
+	ListActiveIncidents(ctx context.Context) ([]*models.StatusIncident, error)
+
+	// UpdateIncident stores the changes to an incident.
+	//
+	// Parameters:
+	//   - ctx: Context for transaction and cancellation control
+	//   - incident: The updated incident
+	//
+	// Returns:
+	//   - NotFoundError if the incident doesn't exist
+	//   - Other errors for database issues
+	UpdateIncident(ctx context.Context, incident *models.StatusIncident) error
+
+	// DeleteIncident removes an incident.
+	//
+	// Parameters:
+	//   - ctx: Context for transaction and cancellation control
+	//   - id: The ID of the incident
+	//
+	// Returns:
+	//   - NotFoundError if the incident doesn't exist
+	//   - Other errors for database issues
+	DeleteIncident(ctx context.Context, id int64) error
+
+	// RecordCheck adds the result of a health check to the rollup of its component and hour.
+	//
+	// Parameters:
+	//   - ctx: Context for transaction and cancellation control
+	//   - component: The name of the checked component
+	//   - healthy: Whether the check passed
+	//   - checkedAt: When the check was run
+	//
+	// Returns:
+	//   - An error for database issues
+	RecordCheck(ctx context.Context, component string, healthy bool, checkedAt time.Time) error
+
+	// GetUptime counts the health checks of each component since a point in time.
+	//
+	// Parameters:
+	//   - ctx: Context for transaction and cancellation control
+	//   - since: The start of the window; rollups of the hour containing it are included
+	//
+	// Returns:
+	//   - The counts per component
+	//   - An error for database issues
+	GetUptime(ctx context.Context, since time.Time) ([]*models.UptimeCounts, error)
+
+	// DeleteUptimeBefore removes rollups of hours before a point in time.
+	//
+	// Parameters:
+	//   - ctx: Context for transaction and cancellation control
+	//   - before: Rollups of earlier hours are removed
+	//
+	// Returns:
+	//   - The number of removed rollups
+	//   - An error for database issues
+	DeleteUptimeBefore(ctx context.Context, before time.Time) (int64, error)
+}
+
+// PostgresStatusRepository is a PostgreSQL implementation of StatusRepository.
+type PostgresStatusRepository struct {
+	db *database.Pool
+}
+
+// NewStatusRepository creates a new StatusRepository implementation for PostgreSQL.
+//
+// Parameters:
+//   - db: A connection pool for PostgreSQL database access
+//
+// Returns:
+//   - An implementation of the StatusRepository interface
+func NewStatusRepository(db *database.Pool) StatusRepository {
+	return &PostgresStatusRepository{
+		db: db,
+	}
+}
+
+// statusIncidentColumns is the column list shared by all incident queries.
+const statusIncidentColumns = `incident_id, title, message, impact, status, created_by, created_at, updated_at, resolved_at`
+
+// scanStatusIncident scans a single incident row.
+//
+// Parameters:
+//   - scanner: The row or rows to scan from
+//
+// Returns:
+//   - The scanned incident
+//   - An error if scanning fails
+func scanStatusIncident(scanner interface{ Scan(dest ...any) error }) (*models.StatusIncident, error) {
+	incident := &models.StatusIncident{}
+	var createdBy sql.NullInt64
+	var resolvedAt sql.NullTime
+	if err := scanner.Scan(
+		&incident.ID,
+		&incident.Title,
+		&incident.Message,
+		&incident.Impact,
+		&incident.Status,
+		&createdBy,
+		&incident.CreatedAt,
+		&incident.UpdatedAt,
+		&resolvedAt,
+	); err != nil {
+		return nil, err
+	}
+	if createdBy.Valid {
+		incident.CreatedBy = &createdBy.Int64
+	}
+	if resolvedAt.Valid {
+		incident.ResolvedAt = &resolvedAt.Time
+	}
+	return incident, nil
+}
+
+// CreateIncident stores a new incident.
+//
+// Parameters:
+//   - ctx: Context for transaction and cancellation control
+//   - incident: The incident to store; its ID is set on success
+//
+// Returns:
+//   - An error for database issues
+func (r *PostgresStatusRepository) CreateIncident(ctx context.Context, incident *models.StatusIncident) error {
+	// Start query timer
+	startTime := time.Now()
+
+	// Define the query
+	query := `
+        INSERT INTO status_incidents (title, message, impact, status, created_by, created_at, updated_at, resolved_at)
+        VALUES ($1, $2, $3, $4, $5, $6, $7, $8)
+        RETURNING incident_id
+    `
+
+	// Execute the query
+	err := r.db.QueryRowContext(
+		ctx,
+		query,
+		incident.Title,
+		incident.Message,
+		incident.Impact,
+		incident.Status,
+		incident.CreatedBy,
+		incident.CreatedAt,
+		incident.UpdatedAt,
+		incident.ResolvedAt,
+	).Scan(&incident.ID)
+
+	// Log the query execution
+	utils.LogDBQuery(
+		query,
+		[]interface{}{incident.Impact, incident.Status},
+		time.Since(startTime),
+		err,
+	)
+
+	if err != nil {
+		return fmt.Errorf("failed to create status incident: %w", err)
+	}
+
+	return nil
+}
+
+// GetIncidentByID retrieves an incident by its ID.
+//
+// Parameters:
+//   - ctx: Context for transaction and cancellation control
+//   - id: The ID of the incident
+//
+// Returns:
+//   - The incident
+//   - NotFoundError if the incident doesn't exist
+//   - Other errors for database issues
+func (r *PostgresStatusRepository) GetIncidentByID(ctx context.Context, id int64) (*models.StatusIncident, error) {
+	// Start query timer
+	startTime := time.Now()
+
+	// Define the query
+	query := `SELECT ` + statusIncidentColumns + ` FROM status_incidents WHERE incident_id = $1`
+
+	// Execute the query
+	incident, err := scanStatusIncident(r.db.QueryRowContext(ctx, query, id))
+
+	// Log the query execution
+	utils.LogDBQuery(
+		query,
+		[]interface{}{id},
+		time.Since(startTime),
+		err,
+	)
+
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, utils.NewNotFoundError("StatusIncident", id).WithCause(err)
+		}
+		return nil, fmt.Errorf("failed to get status incident: %w", err)
+	}
+
+	return incident, nil
+}
+
+// ListIncidents retrieves the most recent incidents, resolved or not.
+//
+// Parameters:
+//   - ctx: Context for transaction and cancellation control
+//   - limit: The maximum number of incidents to return
+//
+// Returns:
+//   - The incidents, newest first
+//   - An error for database issues
+func (r *PostgresStatusRepository) ListIncidents(ctx context.Context, limit int) ([]*models.StatusIncident, error) {
+	query := `SELECT ` + statusIncidentColumns + ` FROM status_incidents ORDER BY created_at DESC, incident_id DESC LIMIT $1`
+	return r.queryIncidents(ctx, query, limit)
+}
+
+// ListActiveIncidents retrieves all incidents that are not resolved yet.
+//
+// Parameters:
+//   - ctx: Context for transaction and cancellation control
+//
+// Returns:
+//   - The unresolved incidents, newest first
+//   - An error for database issues
+func (r *PostgresStatusRepository) ListActiveIncidents(ctx context.Context) ([]*models.StatusIncident, error) {
+	query := `SELECT ` + statusIncidentColumns + ` FROM status_incidents WHERE resolved_at IS NULL ORDER BY created_at DESC, incident_id DESC`
+	return r.queryIncidents(ctx, query)
+}
+
+// queryIncidents runs an incident query and scans all rows.
+//
+// Parameters:
+//   - ctx: Context for transaction and cancellation control
+//   - query: The query selecting statusIncidentColumns
+//   - args: The query arguments
+//
+// Returns:
+//   - The incidents (empty if there are none)
+//   - An error for database issues
+func (r *PostgresStatusRepository) queryIncidents(ctx context.Context, query string, args ...interface{}) ([]*models.StatusIncident, error) {
+	// Start query timer
+	startTime := time.Now()
+
+	// Execute the query
+	rows, err := r.db.QueryContext(ctx, query, args...)
+
+	// Log the query execution
+	utils.LogDBQuery(
+		query,
+		args,
+		time.Since(startTime),
+		err,
+	)
+
+	if err != nil {
+		return nil, fmt.Errorf("failed to list status incidents: %w", err)
+	}
+	defer rows.Close()
+
+	incidents := []*models.StatusIncident{}
+	for rows.Next() {
+		incident, err := scanStatusIncident(rows)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan status incident row: %w", err)
+		}
+		incidents = append(incidents, incident)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating status incident rows: %w", err)
+	}
+
+	return incidents, nil
+}
+
+// UpdateIncident stores the changes to an incident.
+//
+// Parameters:
+//   - ctx: Context for transaction and cancellation control
+//   - incident: The updated incident
+//
+// Returns:
+//   - NotFoundError if the incident doesn't exist
+//   - Other errors for database issues
+func (r *PostgresStatusRepository) UpdateIncident(ctx context.Context, incident *models.StatusIncident) error {
+	// Start query timer
+	startTime := time.Now()
+
+	// Define the query
+	query := `
+        UPDATE status_incidents
+        SET title = $1, message = $2, impact = $3, status = $4, updated_at = $5, resolved_at = $6
+        WHERE incident_id = $7
+    `
+
+	// Execute the query
+	result, err := r.db.ExecContext(
+		ctx,
+		query,
+		incident.Title,
+		incident.Message,
+		incident.Impact,
+		incident.Status,
+		incident.UpdatedAt,
+		incident.ResolvedAt,
+		incident.ID,
+	)
+
+	// Log the query execution
+	utils.LogDBQuery(
+		query,
+		[]interface{}{incident.Impact, incident.Status, incident.ID},
+		time.Since(startTime),
+		err,
+	)
+
+	if err != nil {
+		return fmt.Errorf("failed to update status incident: %w", err)
+	}
+
+	// Check if an incident was updated
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("failed to get affected rows: %w", err)
+	}
+
+	if rowsAffected == 0 {
+		return utils.NewNotFoundError("StatusIncident", incident.ID)
+	}
+
+	return nil
+}
+
+// DeleteIncident removes an incident.
+//
+// Parameters:
+//   - ctx: Context for transaction and cancellation control
+//   - id: The ID of the incident
+//
+// Returns:
+//   - NotFoundError if the incident doesn't exist
+//   - Other errors for database issues
+func (r *PostgresStatusRepository) DeleteIncident(ctx context.Context, id int64) error {
+	// Start query timer
+	startTime := time.Now()
+
+	// Define the query
+	query := `DELETE FROM status_incidents WHERE incident_id = $1`
+
+	// Execute the query
+	result, err := r.db.ExecContext(ctx, query, id)
+
+	// Log the query execution
+	utils.LogDBQuery(
+		query,
+		[]interface{}{id},
+		time.Since(startTime),
+		err,
+	)
+
+	if err != nil {
+		return fmt.Errorf("failed to delete status incident: %w", err)
+	}
+
+	// Check if an incident was deleted
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("failed to get affected rows: %w", err)
+	}
+
+	if rowsAffected == 0 {
+		return utils.NewNotFoundError("StatusIncident", id)
+	}
+
+	return nil
+}
+
+// RecordCheck adds the result of a health check to the rollup of its component and hour.
+//
+// Parameters:
+//   - ctx: Context for transaction and cancellation control
+//   - component: The name of the checked component
+//   - healthy: Whether the check passed
+//   - checkedAt: When the check was run
+//
+// Returns:
+//   - An error for database issues
+func (r *PostgresStatusRepository) RecordCheck(ctx context.Context, component string, healthy bool, checkedAt time.Time) error {
+	// Start query timer
+	startTime := time.Now()
+
+	healthyChecks := 0
+	if healthy {
+		healthyChecks = 1
+	}
+	periodStart := checkedAt.UTC().Truncate(time.Hour)
+
+	// Define the query
+	query := `
+        INSERT INTO component_uptime (component, period_start, checks, healthy_checks)
+        VALUES ($1, $2, 1, $3)
+        ON CONFLICT (component, period_start) DO UPDATE
+        SET checks = component_uptime.checks + 1,
+            healthy_checks = component_uptime.healthy_checks + EXCLUDED.healthy_checks
+    `
+
+	// Execute the query
+	_, err := r.db.ExecContext(ctx, query, component, periodStart, healthyChecks)
+
+	// Log the query execution
+	utils.LogDBQuery(
+		query,
+		[]interface{}{component, periodStart, healthyChecks},
+		time.Since(startTime),
+		err,
+	)
+
+	if err != nil {
+		return fmt.Errorf("failed to record component check: %w", err)
+	}
+
+	return nil
+}
+
+// GetUptime counts the health checks of each component since a point in time.
+//
+// Parameters:
+//   - ctx: Context for transaction and cancellation control
+//   - since: The start of the window; rollups of the hour containing it are included
+//
+// Returns:
+//   - The counts per component
+//   - An error for database issues
+func (r *PostgresStatusRepository) GetUptime(ctx context.Context, since time.Time) ([]*models.UptimeCounts, error) {
+	// Start query timer
+	startTime := time.Now()
+
+	periodStart := since.UTC().Truncate(time.Hour)
+
+	// Define the query
+	query := `
+        SELECT component, SUM(checks), SUM(healthy_checks)
+        FROM component_uptime
+        WHERE period_start >= $1
+        GROUP BY component
+        ORDER BY component
+    `
+
+	// Execute the query
+	rows, err := r.db.QueryContext(ctx, query, periodStart)
+
+	// Log the query execution
+	utils.LogDBQuery(
+		query,
+		[]interface{}{periodStart},
+		time.Since(startTime),
+		err,
+	)
+
+	if err != nil {
+		return nil, fmt.Errorf("failed to get component uptime: %w", err)
+	}
+	defer rows.Close()
+
+	counts := []*models.UptimeCounts{}
+	for rows.Next() {
+		count := &models.UptimeCounts{}
+		if err := rows.Scan(&count.Component, &count.Checks, &count.HealthyChecks); err != nil {
+			return nil, fmt.Errorf("failed to scan component uptime row: %w", err)
+		}
+		counts = append(counts, count)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating component uptime rows: %w", err)
+	}
+
+	return counts, nil
+}
+
+// DeleteUptimeBefore removes rollups of hours before a point in time.
+//
+// Parameters:
+//   - ctx: Context for transaction and cancellation control
+//   - before: Rollups of earlier hours are removed
+//
+// Returns:
+//   - The number of removed rollups
+//   - An error for database issues
+func (r *PostgresStatusRepository) DeleteUptimeBefore(ctx context.Context, before time.Time) (int64, error) {
+	// Start query timer
+	startTime := time.Now()
+
+	// Define the query
+	query := `DELETE FROM component_uptime WHERE period_start < $1`
+
+	// Execute the query
+	result, err := r.db.ExecContext(ctx, query, before.UTC())
+
+	// Log the query execution
+	utils.LogDBQuery(
+		query,
+		[]interface{}{before},
+		time.Since(startTime),
+		err,
+	)
+
+	if err != nil {
+		return 0, fmt.Errorf("failed to delete component uptime: %w", err)
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return 0, fmt.Errorf("failed to get affected rows: %w", err)
+	}
+
+	return rowsAffected, nil
+}
@@ -0,0 +1,265 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/yasinhessnawi1/Hideme_Backend/internal/models"
+	"github.com/yasinhessnawi1/Hideme_Backend/internal/utils"
+)
+
+var statusIncidentTestColumns = []string{
+	"incident_id", "title", "message", "impact", "status", "created_by", "created_at", "updated_at", "resolved_at",
+}
+
+func TestNewStatusRepository(t *testing.T) {
+	// Arrange
+	pool, _, cleanup := setupDBMock(t)
+	defer cleanup()
+
+	// Act
+	repo := NewStatusRepository(pool)
+
+	// Assert
+	assert.NotNil(t, repo, "Repository should not be nil")
+	assert.Implements(t, (*StatusRepository)(nil), repo, "Should implement StatusRepository interface")
+}
+
+func TestStatusRepository_CreateIncident(t *testing.T) {
+	// Arrange
+	pool, mock, cleanup := setupDBMock(t)
+	defer cleanup()
+	repo := NewStatusRepository(pool)
+	incident := models.NewStatusIncident(1, &models.StatusIncidentCreate{
+		Title:   "Detection outage",
+		Message: "Investigating",
+		Impact:  models.IncidentImpactMajor,
+	})
+
+	mock.ExpectQuery("INSERT INTO status_incidents (.+) RETURNING incident_id").
+		WithArgs("Detection outage", "Investigating", models.IncidentImpactMajor, models.IncidentInvestigating,
+			sqlmock.AnyArg(), sqlmock.AnyArg(), sqlmock.AnyArg(), sqlmock.AnyArg()).
+		WillReturnRows(sqlmock.NewRows([]string{"incident_id"}).AddRow(3))
+
+	// Act
+	err := repo.CreateIncident(context.Background(), incident)
+
+	// Assert
+	assert.NoError(t, err)
+	assert.Equal(t, int64(3), incident.ID)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestStatusRepository_GetIncidentByID(t *testing.T) {
+	t.Run("Success", func(t *testing.T) {
+		// Arrange
+		pool, mock, cleanup := setupDBMock(t)
+		defer cleanup()
+		repo := NewStatusRepository(pool)
+		now := time.Now()
+
+		mock.ExpectQuery("SELECT (.+) FROM status_incidents WHERE incident_id = \\$1").
+			WithArgs(int64(3)).
+			WillReturnRows(sqlmock.NewRows(statusIncidentTestColumns).
+				AddRow(3, "Detection outage", "Fixed", "major", "resolved", nil, now, now, now))
+
+		// Act
+		incident, err := repo.GetIncidentByID(context.Background(), 3)
+
+		// Assert
+		require.NoError(t, err)
+		assert.Equal(t, models.IncidentResolved, incident.Status)
+		assert.Nil(t, incident.CreatedBy)
+		assert.NotNil(t, incident.ResolvedAt)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("Not Found", func(t *testing.T) {
+		// Arrange
+		pool, mock, cleanup := setupDBMock(t)
+		defer cleanup()
+		repo := NewStatusRepository(pool)
+
+		mock.ExpectQuery("SELECT (.+) FROM status_incidents WHERE incident_id = \\$1").
+			WithArgs(int64(3)).
+			WillReturnRows(sqlmock.NewRows(statusIncidentTestColumns))
+
+		// Act
+		incident, err := repo.GetIncidentByID(context.Background(), 3)
+
+		// Assert
+		assert.Nil(t, incident)
+		assert.True(t, errors.Is(err, utils.ErrNotFound))
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+}
+
+func TestStatusRepository_ListActiveIncidents(t *testing.T) {
+	// Arrange
+	pool, mock, cleanup := setupDBMock(t)
+	defer cleanup()
+	repo := NewStatusRepository(pool)
+	now := time.Now()
+
+	mock.ExpectQuery("SELECT (.+) FROM status_incidents WHERE resolved_at IS NULL ORDER BY created_at DESC").
+		WillReturnRows(sqlmock.NewRows(statusIncidentTestColumns).
+			AddRow(4, "Slow uploads", "Identified", "minor", "identified", 1, now, now, nil).
+			AddRow(2, "Database outage", "Monitoring", "critical", "monitoring", 1, now, now, nil))
+
+	// Act
+	incidents, err := repo.ListActiveIncidents(context.Background())
+
+	// Assert
+	require.NoError(t, err)
+	require.Len(t, incidents, 2)
+	assert.Equal(t, int64(4), incidents[0].ID)
+	require.NotNil(t, incidents[0].CreatedBy)
+	assert.Equal(t, int64(1), *incidents[0].CreatedBy)
+	assert.Nil(t, incidents[1].ResolvedAt)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestStatusRepository_ListIncidents(t *testing.T) {
+	// Arrange
+	pool, mock, cleanup := setupDBMock(t)
+	defer cleanup()
+	repo := NewStatusRepository(pool)
+
+	mock.ExpectQuery("SELECT (.+) FROM status_incidents ORDER BY created_at DESC, incident_id DESC LIMIT \\$1").
+		WithArgs(100).
+		WillReturnRows(sqlmock.NewRows(statusIncidentTestColumns))
+
+	// Act
+	incidents, err := repo.ListIncidents(context.Background(), 100)
+
+	// Assert
+	assert.NoError(t, err)
+	assert.Empty(t, incidents)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestStatusRepository_UpdateIncident(t *testing.T) {
+	t.Run("Success", func(t *testing.T) {
+		// Arrange
+		pool, mock, cleanup := setupDBMock(t)
+		defer cleanup()
+		repo := NewStatusRepository(pool)
+		incident := &models.StatusIncident{ID: 3, Title: "Outage", Message: "Fixed", Impact: models.IncidentImpactMajor, Status: models.IncidentResolved}
+
+		mock.ExpectExec("UPDATE status_incidents SET (.+) WHERE incident_id = \\$7").
+			WithArgs("Outage", "Fixed", models.IncidentImpactMajor, models.IncidentResolved, sqlmock.AnyArg(), sqlmock.AnyArg(), int64(3)).
+			WillReturnResult(sqlmock.NewResult(0, 1))
+
+		// Act
+		err := repo.UpdateIncident(context.Background(), incident)
+
+		// Assert
+		assert.NoError(t, err)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("Not Found", func(t *testing.T) {
+		// Arrange
+		pool, mock, cleanup := setupDBMock(t)
+		defer cleanup()
+		repo := NewStatusRepository(pool)
+
+		mock.ExpectExec("UPDATE status_incidents").
+			WillReturnResult(sqlmock.NewResult(0, 0))
+
+		// Act
+		err := repo.UpdateIncident(context.Background(), &models.StatusIncident{ID: 3})
+
+		// Assert
+		assert.True(t, errors.Is(err, utils.ErrNotFound))
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+}
+
+func TestStatusRepository_DeleteIncident(t *testing.T) {
+	// Arrange
+	pool, mock, cleanup := setupDBMock(t)
+	defer cleanup()
+	repo := NewStatusRepository(pool)
+
+	mock.ExpectExec("DELETE FROM status_incidents WHERE incident_id = \\$1").
+		WithArgs(int64(3)).
+		WillReturnResult(sqlmock.NewResult(0, 0))
+
+	// Act
+	err := repo.DeleteIncident(context.Background(), 3)
+
+	// Assert
+	assert.True(t, errors.Is(err, utils.ErrNotFound))
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestStatusRepository_RecordCheck(t *testing.T) {
+	// Arrange
+	pool, mock, cleanup := setupDBMock(t)
+	defer cleanup()
+	repo := NewStatusRepository(pool)
+	checkedAt := time.Date(2024, 5, 13, 10, 42, 7, 0, time.UTC)
+
+	// Checks are added to the rollup of their hour
+	mock.ExpectExec("INSERT INTO component_uptime (.+) ON CONFLICT \\(component, period_start\\) DO UPDATE").
+		WithArgs("database", time.Date(2024, 5, 13, 10, 0, 0, 0, time.UTC), 0).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+
+	// Act
+	err := repo.RecordCheck(context.Background(), "database", false, checkedAt)
+
+	// Assert
+	assert.NoError(t, err)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestStatusRepository_GetUptime(t *testing.T) {
+	// Arrange
+	pool, mock, cleanup := setupDBMock(t)
+	defer cleanup()
+	repo := NewStatusRepository(pool)
+	since := time.Date(2024, 5, 12, 10, 42, 7, 0, time.UTC)
+
+	mock.ExpectQuery("SELECT component, SUM\\(checks\\), SUM\\(healthy_checks\\) FROM component_uptime WHERE period_start >= \\$1 GROUP BY component").
+		WithArgs(time.Date(2024, 5, 12, 10, 0, 0, 0, time.UTC)).
+		WillReturnRows(sqlmock.NewRows([]string{"component", "checks", "healthy_checks"}).
+			AddRow("api", 1440, 1440).
+			AddRow("database", 1440, 1438))
+
+	// Act
+	counts, err := repo.GetUptime(context.Background(), since)
+
+	// Assert
+	require.NoError(t, err)
+	require.Len(t, counts, 2)
+	assert.Equal(t, "database", counts[1].Component)
+	assert.Equal(t, int64(1438), counts[1].HealthyChecks)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestStatusRepository_DeleteUptimeBefore(t *testing.T) {
+	// Arrange
+	pool, mock, cleanup := setupDBMock(t)
+	defer cleanup()
+	repo := NewStatusRepository(pool)
+	before := time.Date(2024, 2, 13, 0, 0, 0, 0, time.UTC)
+
+	mock.ExpectExec("DELETE FROM component_uptime WHERE period_start < \\$1").
+		WithArgs(before).
+		WillReturnResult(sqlmock.NewResult(0, 96))
+
+	// Act
+	count, err := repo.DeleteUptimeBefore(context.Background(), before)
+
+	// Assert
+	assert.NoError(t, err)
+	assert.Equal(t, int64(96), count)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
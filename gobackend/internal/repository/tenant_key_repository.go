@@ -0,0 +1,268 @@
+// Package repository provides data access interfaces and implementations for the HideMe application.
+// It follows the repository pattern to abstract database operations and provide a clean API
+// for data persistence operations.
+//
+// This file implements the tenant key repository, which manages a data-encryption key per
+// tenant. Keys are stored wrapped (encrypted) with the master key and are only held in
+// plaintext in memory. Deleting a tenant's key crypto-shreds everything encrypted with it.
+package repository
+
+import (
+	"context"
+	"crypto/rand"
+	"database/sql"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"io"
+	"sync"
+	"time"
+
+	"github.com/rs/zerolog/log"
+
+	"github.com/yasinhessnawi1/Hideme_Backend/internal/constants"
+	"github.com/yasinhessnawi1/Hideme_Backend/internal/database"
+	"github.com/yasinhessnawi1/Hideme_Backend/internal/utils"
+)
+
+// TenantKeyRepository defines methods for managing the data-encryption keys of tenants.
+type TenantKeyRepository interface {
+	// GetOrCreateDataKey retrieves the data-encryption key of a tenant, creating it on first use.
+	//
+	// Parameters:
+	//   - ctx: Context for transaction and cancellation control
+	//   - userID: The ID of the tenant (user account)
+	//
+	// Returns:
+	//   - The unwrapped data-encryption key
+	//   - An error if the key cannot be created or unwrapped
+	GetOrCreateDataKey(ctx context.Context, userID int64) ([]byte, error)
+
+	// GetDataKey retrieves the data-encryption key of a tenant without creating one.
+	//
+	// Parameters:
+	//   - ctx: Context for transaction and cancellation control
+	//   - userID: The ID of the tenant (user account)
+	//
+	// Returns:
+	//   - The unwrapped data-encryption key
+	//   - NotFoundError if the tenant has no key, e.g. because it was shredded
+	//   - Other errors for database issues
+	GetDataKey(ctx context.Context, userID int64) ([]byte, error)
+
+	// Delete removes the data-encryption key of a tenant, crypto-shredding its ciphertexts.
+	//
+	// Parameters:
+	//   - ctx: Context for transaction and cancellation control
+	//   - userID: The ID of the tenant (user account)
+	//
+	// Returns:
+	//   - An error for database issues; a tenant without a key is not an error
+	Delete(ctx context.Context, userID int64) error
+}
+
+// PostgresTenantKeyRepository is a PostgreSQL implementation of TenantKeyRepository.
+// Unwrapped keys are cached in memory, so each key is unwrapped once per process.
+type PostgresTenantKeyRepository struct {
+	db         *database.Pool
+	masterKey  []byte
+	cache      map[int64][]byte
+	cacheMutex sync.RWMutex
+}
+
+// NewTenantKeyRepository creates a new TenantKeyRepository implementation for PostgreSQL.
+//
+// Parameters:
+//   - db: A connection pool for PostgreSQL database access
+//   - masterKey: The key wrapping the data-encryption keys (at least 32 bytes)
+//
+// Returns:
+//   - An implementation of the TenantKeyRepository interface
+func NewTenantKeyRepository(db *database.Pool, masterKey []byte) TenantKeyRepository {
+	return &PostgresTenantKeyRepository{
+		db:        db,
+		masterKey: masterKey,
+		cache:     make(map[int64][]byte),
+	}
+}
+
+// GetOrCreateDataKey retrieves the data-encryption key of a tenant, creating it on first use.
+// Concurrent first uses are resolved by the database, so every caller ends up with the same key.
+//
+// Parameters:
+//   - ctx: Context for transaction and cancellation control
+//   - userID: The ID of the tenant (user account)
+//
+// Returns:
+//   - The unwrapped data-encryption key
+//   - An error if the key cannot be created or unwrapped
+func (r *PostgresTenantKeyRepository) GetOrCreateDataKey(ctx context.Context, userID int64) ([]byte, error) {
+	if key, ok := r.cached(userID); ok {
+		return key, nil
+	}
+
+	// Generate and wrap a new key
+	dataKey := make([]byte, constants.DataKeySize)
+	if _, err := io.ReadFull(rand.Reader, dataKey); err != nil {
+		return nil, fmt.Errorf("failed to generate data key: %w", err)
+	}
+	wrappedKey, err := utils.EncryptKey(base64.StdEncoding.EncodeToString(dataKey), r.masterKey)
+	if err != nil {
+		return nil, fmt.Errorf("failed to wrap data key: %w", err)
+	}
+
+	// Start query timer
+	startTime := time.Now()
+
+	// Define the query; an existing key is kept
+	query := `
+        INSERT INTO tenant_keys (user_id, wrapped_key, created_at)
+        VALUES ($1, $2, $3)
+        ON CONFLICT (user_id) DO NOTHING
+    `
+
+	// Execute the query
+	now := time.Now()
+	result, err := r.db.ExecContext(ctx, query, userID, wrappedKey, now)
+
+	// Log the query execution (without the key)
+	utils.LogDBQuery(
+		query,
+		[]interface{}{userID, "wrappedKey", now},
+		time.Since(startTime),
+		err,
+	)
+
+	if err != nil {
+		return nil, fmt.Errorf("failed to create data key: %w", err)
+	}
+
+	if rowsAffected, _ := result.RowsAffected(); rowsAffected == 0 {
+		// Another request created the key first
+		return r.GetDataKey(ctx, userID)
+	}
+
+	log.Info().
+		Int64("user_id", userID).
+		Msg("Tenant data key created")
+
+	r.store(userID, dataKey)
+	return dataKey, nil
+}
+
+// GetDataKey retrieves the data-encryption key of a tenant without creating one.
+//
+// Parameters:
+//   - ctx: Context for transaction and cancellation control
+//   - userID: The ID of the tenant (user account)
+//
+// Returns:
+//   - The unwrapped data-encryption key
+//   - NotFoundError if the tenant has no key
+//   - Other errors for database issues
+func (r *PostgresTenantKeyRepository) GetDataKey(ctx context.Context, userID int64) ([]byte, error) {
+	if key, ok := r.cached(userID); ok {
+		return key, nil
+	}
+
+	// Start query timer
+	startTime := time.Now()
+
+	// Define the query
+	query := `SELECT wrapped_key FROM tenant_keys WHERE user_id = $1`
+
+	// Execute the query
+	var wrappedKey string
+	err := r.db.QueryRowContext(ctx, query, userID).Scan(&wrappedKey)
+
+	// Log the query execution
+	utils.LogDBQuery(
+		query,
+		[]interface{}{userID},
+		time.Since(startTime),
+		err,
+	)
+
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, utils.NewNotFoundError("TenantKey", userID).WithCause(err)
+		}
+		return nil, fmt.Errorf("failed to get data key: %w", err)
+	}
+
+	// Unwrap the key
+	encodedKey, err := utils.DecryptKey(wrappedKey, r.masterKey)
+	if err != nil {
+		return nil, fmt.Errorf("failed to unwrap data key: %w", err)
+	}
+	dataKey, err := base64.StdEncoding.DecodeString(encodedKey)
+	if err != nil {
+		return nil, fmt.Errorf("failed to decode data key: %w", err)
+	}
+
+	r.store(userID, dataKey)
+	return dataKey, nil
+}
+
+// Delete removes the data-encryption key of a tenant, crypto-shredding its ciphertexts.
+// Deleting a user cascades to its key as well; calling Delete afterwards still
+// evicts the key from the cache.
+//
+// Parameters:
+//   - ctx: Context for transaction and cancellation control
+//   - userID: The ID of the tenant (user account)
+//
+// Returns:
+//   - An error for database issues
+func (r *PostgresTenantKeyRepository) Delete(ctx context.Context, userID int64) error {
+	// Forget the key first so it is not used while it is being deleted
+	r.cacheMutex.Lock()
+	delete(r.cache, userID)
+	r.cacheMutex.Unlock()
+
+	// Start query timer
+	startTime := time.Now()
+
+	// Define the query
+	query := `DELETE FROM tenant_keys WHERE user_id = $1`
+
+	// Execute the query
+	result, err := r.db.ExecContext(ctx, query, userID)
+
+	// Log the query execution
+	utils.LogDBQuery(
+		query,
+		[]interface{}{userID},
+		time.Since(startTime),
+		err,
+	)
+
+	if err != nil {
+		return fmt.Errorf("failed to delete data key: %w", err)
+	}
+
+	if rowsAffected, _ := result.RowsAffected(); rowsAffected > 0 {
+		log.Info().
+			Int64("user_id", userID).
+			Msg("Tenant data key deleted")
+	}
+
+	return nil
+}
+
+// cached returns the cached key of a tenant.
+func (r *PostgresTenantKeyRepository) cached(userID int64) ([]byte, bool) {
+	r.cacheMutex.RLock()
+	defer r.cacheMutex.RUnlock()
+
+	key, ok := r.cache[userID]
+	return key, ok
+}
+
+// store caches the key of a tenant.
+func (r *PostgresTenantKeyRepository) store(userID int64, key []byte) {
+	r.cacheMutex.Lock()
+	defer r.cacheMutex.Unlock()
+
+	r.cache[userID] = key
+}
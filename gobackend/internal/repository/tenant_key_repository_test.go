@@ -0,0 +1,126 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"encoding/base64"
+	"errors"
+	"testing"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/yasinhessnawi1/Hideme_Backend/internal/utils"
+)
+
+var testMasterKey = []byte("test-master-key-for-tenant-keys-32")
+
+func TestNewTenantKeyRepository(t *testing.T) {
+	// Arrange
+	pool, _, cleanup := setupDBMock(t)
+	defer cleanup()
+
+	// Act
+	repo := NewTenantKeyRepository(pool, testMasterKey)
+
+	// Assert
+	assert.NotNil(t, repo, "Repository should not be nil")
+	assert.Implements(t, (*TenantKeyRepository)(nil), repo, "Should implement TenantKeyRepository interface")
+}
+
+func TestTenantKeyRepository_GetOrCreateDataKey(t *testing.T) {
+	t.Run("Creates and caches a key", func(t *testing.T) {
+		// Arrange
+		pool, mock, cleanup := setupDBMock(t)
+		defer cleanup()
+		repo := NewTenantKeyRepository(pool, testMasterKey)
+
+		mock.ExpectExec("INSERT INTO tenant_keys (.+) ON CONFLICT \\(user_id\\) DO NOTHING").
+			WithArgs(int64(7), sqlmock.AnyArg(), sqlmock.AnyArg()).
+			WillReturnResult(sqlmock.NewResult(0, 1))
+
+		// Act
+		key, err := repo.GetOrCreateDataKey(context.Background(), 7)
+		require.NoError(t, err)
+		cachedKey, err := repo.GetOrCreateDataKey(context.Background(), 7)
+
+		// Assert
+		require.NoError(t, err)
+		assert.Len(t, key, 32)
+		assert.Equal(t, key, cachedKey)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("Uses the key created concurrently", func(t *testing.T) {
+		// Arrange
+		pool, mock, cleanup := setupDBMock(t)
+		defer cleanup()
+		repo := NewTenantKeyRepository(pool, testMasterKey)
+
+		existingKey := []byte("existing-data-key-of-the-tenant!")
+		wrappedKey, err := utils.EncryptKey(base64.StdEncoding.EncodeToString(existingKey), testMasterKey)
+		require.NoError(t, err)
+
+		mock.ExpectExec("INSERT INTO tenant_keys").
+			WillReturnResult(sqlmock.NewResult(0, 0))
+		mock.ExpectQuery("SELECT wrapped_key FROM tenant_keys WHERE user_id = \\$1").
+			WithArgs(int64(7)).
+			WillReturnRows(sqlmock.NewRows([]string{"wrapped_key"}).AddRow(wrappedKey))
+
+		// Act
+		key, err := repo.GetOrCreateDataKey(context.Background(), 7)
+
+		// Assert
+		require.NoError(t, err)
+		assert.Equal(t, existingKey, key)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+}
+
+func TestTenantKeyRepository_GetDataKey(t *testing.T) {
+	// Arrange
+	pool, mock, cleanup := setupDBMock(t)
+	defer cleanup()
+	repo := NewTenantKeyRepository(pool, testMasterKey)
+
+	mock.ExpectQuery("SELECT wrapped_key FROM tenant_keys WHERE user_id = \\$1").
+		WithArgs(int64(7)).
+		WillReturnError(sql.ErrNoRows)
+
+	// Act
+	key, err := repo.GetDataKey(context.Background(), 7)
+
+	// Assert
+	assert.Nil(t, key)
+	assert.True(t, errors.Is(err, utils.ErrNotFound))
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestTenantKeyRepository_Delete(t *testing.T) {
+	// Arrange
+	pool, mock, cleanup := setupDBMock(t)
+	defer cleanup()
+	repo := NewTenantKeyRepository(pool, testMasterKey)
+
+	mock.ExpectExec("INSERT INTO tenant_keys").
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectExec("DELETE FROM tenant_keys WHERE user_id = \\$1").
+		WithArgs(int64(7)).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectQuery("SELECT wrapped_key FROM tenant_keys").
+		WithArgs(int64(7)).
+		WillReturnError(sql.ErrNoRows)
+
+	_, err := repo.GetOrCreateDataKey(context.Background(), 7)
+	require.NoError(t, err)
+
+	// Act
+	err = repo.Delete(context.Background(), 7)
+
+	// Assert: the key is no longer served from the cache
+	require.NoError(t, err)
+	_, err = repo.GetDataKey(context.Background(), 7)
+	assert.True(t, errors.Is(err, utils.ErrNotFound))
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
@@ -0,0 +1,318 @@
+// Package repository provides data access interfaces and implementations for the HideMe application.
+// It follows the repository pattern to abstract database operations and provide a clean API
+// for data persistence operations.
+//
+// This file implements the usage repository, which maintains the monthly tenant usage
+// rollups consumed by the billing export. Counters added to the rollups are recorded as
+// events, so the page and API call counts can be rebuilt from them.
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/yasinhessnawi1/Hideme_Backend/internal/constants"
+	"github.com/yasinhessnawi1/Hideme_Backend/internal/database"
+	"github.com/yasinhessnawi1/Hideme_Backend/internal/models"
+	"github.com/yasinhessnawi1/Hideme_Backend/internal/utils"
+)
+
+// UsageRepository defines methods for maintaining and reading monthly tenant usage rollups.
+type UsageRepository interface {
+	// AddCounters adds in-memory usage counters to the rollups of a month.
+	//
+	// Parameters:
+	//   - ctx: Context for transaction and cancellation control
+	//   - month: The first day of the month the counters belong to
+	//   - counters: The counters to add, keyed by user ID
+	//
+	// Returns:
+	//   - An error if the counters could not be stored; in that case none are stored
+	AddCounters(ctx context.Context, month time.Time, counters map[int64]*models.UsageCounters) error
+
+	// RebuildCounters recalculates the page and API call counts of all rollups by replaying
+	// the recorded counters. Document counts and storage are left to RefreshDocumentStats.
+	//
+	// Parameters:
+	//   - ctx: Context for transaction and cancellation control
+	//
+	// Returns:
+	//   - The number of replayed events
+	//   - An error if the rebuild fails; in that case the counts are unchanged
+	RebuildCounters(ctx context.Context) (int64, error)
+
+	// RefreshDocumentStats recalculates document counts and storage from the documents table.
+	//
+	// Parameters:
+	//   - ctx: Context for transaction and cancellation control
+	//   - month: The first day of the month to recalculate
+	//
+	// Returns:
+	//   - The number of tenant rollups that were refreshed
+	//   - An error if the recalculation fails
+	RefreshDocumentStats(ctx context.Context, month time.Time) (int64, error)
+
+	// GetByMonth retrieves the usage rollups of all tenants for a month.
+	//
+	// Parameters:
+	//   - ctx: Context for transaction and cancellation control
+	//   - month: The first day of the month to retrieve
+	//
+	// Returns:
+	//   - The rollups ordered by user ID (empty if there are none)
+	//   - An error for database issues
+	GetByMonth(ctx context.Context, month time.Time) ([]*models.TenantUsage, error)
+}
+
+// PostgresUsageRepository is a PostgreSQL implementation of UsageRepository.
+type PostgresUsageRepository struct {
+	db *database.Pool
+}
+
+// NewUsageRepository creates a new UsageRepository implementation for PostgreSQL.
+//
+// Parameters:
+//   - db: A connection pool for PostgreSQL database access
+//
+// Returns:
+//   - An implementation of the UsageRepository interface
+func NewUsageRepository(db *database.Pool) UsageRepository {
+	return &PostgresUsageRepository{
+		db: db,
+	}
+}
+
+// AddCounters adds in-memory usage counters to the rollups of a month within a single
+// transaction, recording an event for each tenant.
+//
+// Parameters:
+//   - ctx: Context for transaction and cancellation control
+//   - month: The first day of the month the counters belong to
+//   - counters: The counters to add, keyed by user ID
+//
+// Returns:
+//   - An error if the counters could not be stored
+func (r *PostgresUsageRepository) AddCounters(ctx context.Context, month time.Time, counters map[int64]*models.UsageCounters) error {
+	if len(counters) == 0 {
+		return nil
+	}
+
+	// Start query timer
+	startTime := time.Now()
+
+	return r.db.Transaction(ctx, func(tx *sql.Tx) error {
+		now := time.Now()
+		for userID, counter := range counters {
+			if err := addUsageCounters(ctx, tx, userID, month, counter, now); err != nil {
+				return err
+			}
+			event := models.UsageCountedEvent{Month: month, PageCount: counter.PageCount, APICallCount: counter.APICallCount}
+			if err := appendEvent(ctx, tx, constants.EventUsageCounted, userID, now, event); err != nil {
+				return err
+			}
+		}
+
+		// Log the operation
+		utils.LogDBQuery(
+			fmt.Sprintf("Added usage counters for %d tenants", len(counters)),
+			[]interface{}{month},
+			time.Since(startTime),
+			nil,
+		)
+
+		return nil
+	})
+}
+
+// RebuildCounters recalculates the page and API call counts of all rollups by replaying
+// the recorded counters within a single transaction.
+//
+// Parameters:
+//   - ctx: Context for transaction and cancellation control
+//
+// Returns:
+//   - The number of replayed events
+//   - An error if the rebuild fails
+func (r *PostgresUsageRepository) RebuildCounters(ctx context.Context) (int64, error) {
+	// Start query timer
+	startTime := time.Now()
+
+	var replayed int64
+	err := r.db.Transaction(ctx, func(tx *sql.Tx) error {
+		if err := lockProjection(ctx, tx, constants.TableTenantUsage); err != nil {
+			return err
+		}
+
+		query := `UPDATE tenant_usage SET page_count = 0, api_call_count = 0`
+		if _, err := tx.ExecContext(ctx, query); err != nil {
+			return fmt.Errorf("failed to reset usage counters: %w", err)
+		}
+
+		var err error
+		replayed, err = replayEvents(ctx, tx, constants.EventUsageCounted, func(event *models.DomainEvent) error {
+			var counted models.UsageCountedEvent
+			if err := json.Unmarshal(event.Payload, &counted); err != nil {
+				return fmt.Errorf("failed to unmarshal usage event: %w", err)
+			}
+			counter := &models.UsageCounters{PageCount: counted.PageCount, APICallCount: counted.APICallCount}
+			return addUsageCounters(ctx, tx, event.UserID, counted.Month, counter, event.OccurredAt)
+		})
+		return err
+	})
+
+	// Log the operation
+	utils.LogDBQuery(
+		fmt.Sprintf("Rebuilt usage counters from %d events", replayed),
+		nil,
+		time.Since(startTime),
+		err,
+	)
+
+	if err != nil {
+		return 0, err
+	}
+	return replayed, nil
+}
+
+// addUsageCounters adds the counters of a tenant to its rollup of a month. It applies both
+// new counters and replayed events, so a rebuild aggregates them the same way.
+func addUsageCounters(ctx context.Context, tx *sql.Tx, userID int64, month time.Time, counter *models.UsageCounters, updatedAt time.Time) error {
+	query := `
+        INSERT INTO tenant_usage (user_id, usage_month, page_count, api_call_count, updated_at)
+        VALUES ($1, $2, $3, $4, $5)
+        ON CONFLICT (user_id, usage_month) DO UPDATE SET
+            page_count = tenant_usage.page_count + EXCLUDED.page_count,
+            api_call_count = tenant_usage.api_call_count + EXCLUDED.api_call_count,
+            updated_at = EXCLUDED.updated_at
+    `
+	if _, err := tx.ExecContext(ctx, query, userID, month, counter.PageCount, counter.APICallCount, updatedAt); err != nil {
+		return fmt.Errorf("failed to add usage counters: %w", err)
+	}
+	return nil
+}
+
+// RefreshDocumentStats recalculates document counts and storage from the documents table.
+// The document count covers uploads within the month, while storage covers every document
+// uploaded before the end of the month that still exists.
+//
+// Parameters:
+//   - ctx: Context for transaction and cancellation control
+//   - month: The first day of the month to recalculate
+//
+// Returns:
+//   - The number of tenant rollups that were refreshed
+//   - An error if the recalculation fails
+func (r *PostgresUsageRepository) RefreshDocumentStats(ctx context.Context, month time.Time) (int64, error) {
+	// Start query timer
+	startTime := time.Now()
+
+	monthEnd := month.AddDate(0, 1, 0)
+
+	// Define the query
+	query := `
+        INSERT INTO tenant_usage (user_id, usage_month, document_count, storage_bytes, updated_at)
+        SELECT d.user_id, $1,
+               COUNT(*) FILTER (WHERE d.upload_timestamp >= $1),
+               COALESCE(SUM(octet_length(d.hashed_document_name) + octet_length(d.redaction_schema::text)), 0),
+               $3
+        FROM documents d
+        WHERE d.upload_timestamp < $2
+        GROUP BY d.user_id
+        ON CONFLICT (user_id, usage_month) DO UPDATE SET
+            document_count = EXCLUDED.document_count,
+            storage_bytes = EXCLUDED.storage_bytes,
+            updated_at = EXCLUDED.updated_at
+    `
+
+	// Execute the query
+	now := time.Now()
+	result, err := r.db.ExecContext(ctx, query, month, monthEnd, now)
+
+	// Log the query execution
+	utils.LogDBQuery(
+		query,
+		[]interface{}{month, monthEnd, now},
+		time.Since(startTime),
+		err,
+	)
+
+	if err != nil {
+		return 0, fmt.Errorf("failed to refresh document usage: %w", err)
+	}
+
+	// Get the number of rows affected
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return 0, fmt.Errorf("failed to get affected rows: %w", err)
+	}
+
+	return rowsAffected, nil
+}
+
+// GetByMonth retrieves the usage rollups of all tenants for a month.
+//
+// Parameters:
+//   - ctx: Context for transaction and cancellation control
+//   - month: The first day of the month to retrieve
+//
+// Returns:
+//   - The rollups ordered by user ID
+//   - An error for database issues
+func (r *PostgresUsageRepository) GetByMonth(ctx context.Context, month time.Time) ([]*models.TenantUsage, error) {
+	// Start query timer
+	startTime := time.Now()
+
+	// Define the query
+	query := `
+        SELECT tu.user_id, u.username, u.email, tu.usage_month, tu.document_count,
+               tu.page_count, tu.storage_bytes, tu.api_call_count, tu.updated_at
+        FROM tenant_usage tu
+        JOIN users u ON u.user_id = tu.user_id
+        WHERE tu.usage_month = $1
+        ORDER BY tu.user_id ASC
+    `
+
+	// Execute the query
+	rows, err := r.db.QueryContext(ctx, query, month)
+
+	// Log the query execution
+	utils.LogDBQuery(
+		query,
+		[]interface{}{month},
+		time.Since(startTime),
+		err,
+	)
+
+	if err != nil {
+		return nil, fmt.Errorf("failed to get tenant usage: %w", err)
+	}
+	defer rows.Close()
+
+	usage := []*models.TenantUsage{}
+	for rows.Next() {
+		row := &models.TenantUsage{}
+		if err := rows.Scan(
+			&row.UserID,
+			&row.Username,
+			&row.Email,
+			&row.UsageMonth,
+			&row.DocumentCount,
+			&row.PageCount,
+			&row.StorageBytes,
+			&row.APICallCount,
+			&row.UpdatedAt,
+		); err != nil {
+			return nil, fmt.Errorf("failed to scan tenant usage row: %w", err)
+		}
+		usage = append(usage, row)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating tenant usage rows: %w", err)
+	}
+
+	return usage, nil
+}
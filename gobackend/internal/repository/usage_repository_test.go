@@ -0,0 +1,265 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/yasinhessnawi1/Hideme_Backend/internal/constants"
+	"github.com/yasinhessnawi1/Hideme_Backend/internal/models"
+)
+
+func TestNewUsageRepository(t *testing.T) {
+	// Arrange
+	pool, _, cleanup := setupDBMock(t)
+	defer cleanup()
+
+	// Act
+	repo := NewUsageRepository(pool)
+
+	// Assert
+	assert.NotNil(t, repo, "Repository should not be nil")
+	assert.Implements(t, (*UsageRepository)(nil), repo, "Should implement UsageRepository interface")
+}
+
+func TestUsageRepository_AddCounters(t *testing.T) {
+	month := time.Date(2024, time.May, 1, 0, 0, 0, 0, time.UTC)
+
+	t.Run("Success", func(t *testing.T) {
+		// Arrange
+		pool, mock, cleanup := setupDBMock(t)
+		defer cleanup()
+		repo := NewUsageRepository(pool)
+
+		mock.ExpectBegin()
+		mock.ExpectExec("INSERT INTO tenant_usage (.+) ON CONFLICT").
+			WithArgs(int64(1), month, int64(12), int64(40), sqlmock.AnyArg()).
+			WillReturnResult(sqlmock.NewResult(0, 1))
+		// The counters are recorded as an event to rebuild the rollups from
+		mock.ExpectExec("INSERT INTO domain_events").
+			WithArgs(constants.EventUsageCounted, int64(1), sqlmock.AnyArg(), sqlmock.AnyArg()).
+			WillReturnResult(sqlmock.NewResult(1, 1))
+		mock.ExpectCommit()
+
+		// Act
+		err := repo.AddCounters(context.Background(), month, map[int64]*models.UsageCounters{
+			1: {PageCount: 12, APICallCount: 40},
+		})
+
+		// Assert
+		assert.NoError(t, err)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("No Counters", func(t *testing.T) {
+		// Arrange
+		pool, mock, cleanup := setupDBMock(t)
+		defer cleanup()
+		repo := NewUsageRepository(pool)
+
+		// Act
+		err := repo.AddCounters(context.Background(), month, nil)
+
+		// Assert
+		assert.NoError(t, err)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("Database Error", func(t *testing.T) {
+		// Arrange
+		pool, mock, cleanup := setupDBMock(t)
+		defer cleanup()
+		repo := NewUsageRepository(pool)
+
+		mock.ExpectBegin()
+		mock.ExpectExec("INSERT INTO tenant_usage").
+			WillReturnError(errors.New("database error"))
+		mock.ExpectRollback()
+
+		// Act
+		err := repo.AddCounters(context.Background(), month, map[int64]*models.UsageCounters{
+			1: {APICallCount: 1},
+		})
+
+		// Assert
+		assert.Error(t, err)
+		assert.Contains(t, err.Error(), "failed to add usage counters")
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+}
+
+func TestUsageRepository_RebuildCounters(t *testing.T) {
+	month := time.Date(2024, time.May, 1, 0, 0, 0, 0, time.UTC)
+	occurredAt := time.Date(2024, time.May, 3, 10, 0, 0, 0, time.UTC)
+	columns := []string{"event_id", "event_type", "user_id", "payload", "occurred_at"}
+
+	t.Run("Success", func(t *testing.T) {
+		// Arrange
+		pool, mock, cleanup := setupDBMock(t)
+		defer cleanup()
+		repo := NewUsageRepository(pool)
+
+		mock.ExpectBegin()
+		mock.ExpectExec("LOCK TABLE tenant_usage IN EXCLUSIVE MODE").WillReturnResult(sqlmock.NewResult(0, 0))
+		mock.ExpectExec("UPDATE tenant_usage SET page_count = 0, api_call_count = 0").WillReturnResult(sqlmock.NewResult(0, 2))
+		mock.ExpectQuery("FROM domain_events").
+			WithArgs(constants.EventUsageCounted, int64(0), constants.DomainEventReplayBatchSize).
+			WillReturnRows(sqlmock.NewRows(columns).
+				AddRow(int64(3), constants.EventUsageCounted, int64(1), []byte(`{"month":"2024-05-01T00:00:00Z","page_count":12,"api_call_count":40}`), occurredAt).
+				AddRow(int64(8), constants.EventUsageCounted, int64(1), []byte(`{"month":"2024-05-01T00:00:00Z","page_count":3,"api_call_count":5}`), occurredAt))
+		mock.ExpectExec("INSERT INTO tenant_usage (.+) ON CONFLICT").
+			WithArgs(int64(1), month, int64(12), int64(40), occurredAt).
+			WillReturnResult(sqlmock.NewResult(0, 1))
+		mock.ExpectExec("INSERT INTO tenant_usage (.+) ON CONFLICT").
+			WithArgs(int64(1), month, int64(3), int64(5), occurredAt).
+			WillReturnResult(sqlmock.NewResult(0, 1))
+		mock.ExpectCommit()
+
+		// Act
+		replayed, err := repo.RebuildCounters(context.Background())
+
+		// Assert
+		require.NoError(t, err)
+		assert.Equal(t, int64(2), replayed)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("Database Error", func(t *testing.T) {
+		// Arrange
+		pool, mock, cleanup := setupDBMock(t)
+		defer cleanup()
+		repo := NewUsageRepository(pool)
+
+		mock.ExpectBegin()
+		mock.ExpectExec("LOCK TABLE tenant_usage").WillReturnResult(sqlmock.NewResult(0, 0))
+		mock.ExpectExec("UPDATE tenant_usage").WillReturnResult(sqlmock.NewResult(0, 2))
+		mock.ExpectQuery("FROM domain_events").WillReturnError(errors.New("database error"))
+		mock.ExpectRollback()
+
+		// Act
+		_, err := repo.RebuildCounters(context.Background())
+
+		// Assert
+		assert.ErrorContains(t, err, "failed to read usage_counted events")
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+}
+
+func TestUsageRepository_RefreshDocumentStats(t *testing.T) {
+	month := time.Date(2024, time.May, 1, 0, 0, 0, 0, time.UTC)
+
+	t.Run("Success", func(t *testing.T) {
+		// Arrange
+		pool, mock, cleanup := setupDBMock(t)
+		defer cleanup()
+		repo := NewUsageRepository(pool)
+
+		mock.ExpectExec("INSERT INTO tenant_usage (.+) FROM documents d").
+			WithArgs(month, month.AddDate(0, 1, 0), sqlmock.AnyArg()).
+			WillReturnResult(sqlmock.NewResult(0, 3))
+
+		// Act
+		count, err := repo.RefreshDocumentStats(context.Background(), month)
+
+		// Assert
+		assert.NoError(t, err)
+		assert.Equal(t, int64(3), count)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("Database Error", func(t *testing.T) {
+		// Arrange
+		pool, mock, cleanup := setupDBMock(t)
+		defer cleanup()
+		repo := NewUsageRepository(pool)
+
+		mock.ExpectExec("INSERT INTO tenant_usage").
+			WillReturnError(errors.New("database error"))
+
+		// Act
+		count, err := repo.RefreshDocumentStats(context.Background(), month)
+
+		// Assert
+		assert.Error(t, err)
+		assert.Zero(t, count)
+		assert.Contains(t, err.Error(), "failed to refresh document usage")
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+}
+
+func TestUsageRepository_GetByMonth(t *testing.T) {
+	month := time.Date(2024, time.May, 1, 0, 0, 0, 0, time.UTC)
+	columns := []string{"user_id", "username", "email", "usage_month", "document_count", "page_count", "storage_bytes", "api_call_count", "updated_at"}
+
+	t.Run("Success With Results", func(t *testing.T) {
+		// Arrange
+		pool, mock, cleanup := setupDBMock(t)
+		defer cleanup()
+		repo := NewUsageRepository(pool)
+
+		now := time.Now()
+		rows := sqlmock.NewRows(columns).
+			AddRow(1, "alice", "alice@example.com", month, 5, 40, 2048, 300, now).
+			AddRow(2, "bob", "bob@example.com", month, 1, 3, 512, 20, now)
+
+		mock.ExpectQuery("SELECT (.+) FROM tenant_usage tu JOIN users u").
+			WithArgs(month).
+			WillReturnRows(rows)
+
+		// Act
+		usage, err := repo.GetByMonth(context.Background(), month)
+
+		// Assert
+		require.NoError(t, err)
+		require.Len(t, usage, 2)
+		assert.Equal(t, "alice", usage[0].Username)
+		assert.Equal(t, int64(40), usage[0].PageCount)
+		assert.Equal(t, int64(300), usage[0].APICallCount)
+		assert.Equal(t, int64(512), usage[1].StorageBytes)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("Empty Result", func(t *testing.T) {
+		// Arrange
+		pool, mock, cleanup := setupDBMock(t)
+		defer cleanup()
+		repo := NewUsageRepository(pool)
+
+		mock.ExpectQuery("SELECT (.+) FROM tenant_usage").
+			WithArgs(month).
+			WillReturnRows(sqlmock.NewRows(columns))
+
+		// Act
+		usage, err := repo.GetByMonth(context.Background(), month)
+
+		// Assert
+		assert.NoError(t, err)
+		assert.NotNil(t, usage)
+		assert.Empty(t, usage)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("Database Error", func(t *testing.T) {
+		// Arrange
+		pool, mock, cleanup := setupDBMock(t)
+		defer cleanup()
+		repo := NewUsageRepository(pool)
+
+		mock.ExpectQuery("SELECT (.+) FROM tenant_usage").
+			WillReturnError(errors.New("database error"))
+
+		// Act
+		usage, err := repo.GetByMonth(context.Background(), month)
+
+		// Assert
+		assert.Error(t, err)
+		assert.Nil(t, usage)
+		assert.Contains(t, err.Error(), "failed to get tenant usage")
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+}
@@ -0,0 +1,563 @@
+// Package repository provides data access interfaces and implementations for the HideMe application.
+// It follows the repository pattern to abstract database operations and provide a clean API
+// for data persistence operations.
+//
+// This file implements the user data export repository, which keeps the jobs exporting all
+// data kept about a user, their checkpoints and the output they stored so far.
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/lib/pq"
+	"github.com/rs/zerolog/log"
+
+	"github.com/yasinhessnawi1/Hideme_Backend/internal/constants"
+	"github.com/yasinhessnawi1/Hideme_Backend/internal/database"
+	"github.com/yasinhessnawi1/Hideme_Backend/internal/models"
+	"github.com/yasinhessnawi1/Hideme_Backend/internal/utils"
+)
+
+// UserDataExportRepository defines methods for user data export jobs and their output.
+// The output is stored in chunks encrypted with the data-encryption key of the user.
+type UserDataExportRepository interface {
+	// Create stores a new pending job.
+	//
+	// Parameters:
+	//   - ctx: Context for transaction and cancellation control
+	//   - job: The job to create; its ID, status and timestamps are set on success
+	//
+	// Returns:
+	//   - DuplicateError if the user already has an unfinished job
+	//   - Other errors for database issues
+	Create(ctx context.Context, job *models.UserDataExportJob) error
+
+	// GetByID retrieves a job of a user.
+	//
+	// Parameters:
+	//   - ctx: Context for transaction and cancellation control
+	//   - userID: The ID of the user
+	//   - id: The ID of the job
+	//
+	// Returns:
+	//   - The job
+	//   - NotFoundError if the user has no such job
+	//   - Other errors for database issues
+	GetByID(ctx context.Context, userID, id int64) (*models.UserDataExportJob, error)
+
+	// ListByUser retrieves the jobs of a user, newest first.
+	//
+	// Parameters:
+	//   - ctx: Context for transaction and cancellation control
+	//   - userID: The ID of the user
+	//
+	// Returns:
+	//   - The jobs (empty if there are none)
+	//   - An error for database issues
+	ListByUser(ctx context.Context, userID int64) ([]*models.UserDataExportJob, error)
+
+	// Claim takes the oldest job waiting to run: a pending or interrupted job, or a running
+	// job whose server stopped storing checkpoints. The job is set running; jobs that had
+	// started before are counted as resumed.
+	//
+	// Parameters:
+	//   - ctx: Context for transaction and cancellation control
+	//   - now: The current time, to find expired leases
+	//   - leaseUntil: When the claiming server must have stored its next checkpoint
+	//
+	// Returns:
+	//   - The claimed job with its checkpoint, or nil if no job is waiting
+	//   - An error for database issues
+	Claim(ctx context.Context, now, leaseUntil time.Time) (*models.UserDataExportJob, error)
+
+	// Checkpoint stores the next chunks of a running job together with its new checkpoint,
+	// so the stored output always matches the checkpoint.
+	//
+	// Parameters:
+	//   - ctx: Context for transaction and cancellation control
+	//   - job: The job as claimed or last checkpointed; its checkpoint is updated on success
+	//   - checkpoint: The checkpoint after the chunks
+	//   - chunks: The chunks to append to the output
+	//   - leaseUntil: When the server must have stored its next checkpoint
+	//
+	// Returns:
+	//   - NotFoundError if the job is no longer running from this checkpoint, e.g. because
+	//     another server took it over; nothing is stored then
+	//   - Other errors for database issues
+	Checkpoint(ctx context.Context, job *models.UserDataExportJob, checkpoint models.UserDataExportCheckpoint, chunks []*models.UserDataExportChunk, leaseUntil time.Time) error
+
+	// Finish ends a run of a job and releases its lease.
+	//
+	// Parameters:
+	//   - ctx: Context for transaction and cancellation control
+	//   - id: The ID of the job
+	//   - status: completed, failed, or interrupted to resume it later
+	//   - lastError: Why the job failed; empty otherwise
+	//
+	// Returns:
+	//   - NotFoundError if the job no longer exists
+	//   - Other errors for database issues
+	Finish(ctx context.Context, id int64, status, lastError string) error
+
+	// StreamChunks passes the chunks of a section of a job's output to fn in the order they
+	// were stored, decrypting them one at a time.
+	//
+	// Parameters:
+	//   - ctx: Context for transaction and cancellation control
+	//   - userID: The ID of the user whose data was exported
+	//   - id: The ID of the job
+	//   - section: The section to read
+	//   - fn: Called for each chunk; returning an error stops the scan
+	//
+	// Returns:
+	//   - The error returned by fn, or an error if retrieval fails
+	StreamChunks(ctx context.Context, userID, id int64, section string, fn func(*models.UserDataExportChunk) error) error
+
+	// DeleteFinishedBefore removes completed and failed jobs, with their output, last
+	// changed before a cutoff.
+	//
+	// Parameters:
+	//   - ctx: Context for transaction and cancellation control
+	//   - cutoff: Jobs finished before this moment are removed
+	//
+	// Returns:
+	//   - The number of removed jobs
+	//   - An error for database issues
+	DeleteFinishedBefore(ctx context.Context, cutoff time.Time) (int64, error)
+}
+
+// PostgresUserDataExportRepository is a PostgreSQL implementation of UserDataExportRepository.
+type PostgresUserDataExportRepository struct {
+	db         *database.Pool
+	tenantKeys TenantKeyRepository
+}
+
+// NewUserDataExportRepository creates a new UserDataExportRepository implementation for PostgreSQL.
+//
+// Parameters:
+//   - db: A connection pool for PostgreSQL database access
+//   - tenantKeys: Repository for the data-encryption keys the output is encrypted with
+//
+// Returns:
+//   - An implementation of the UserDataExportRepository interface
+func NewUserDataExportRepository(db *database.Pool, tenantKeys TenantKeyRepository) UserDataExportRepository {
+	return &PostgresUserDataExportRepository{
+		db:         db,
+		tenantKeys: tenantKeys,
+	}
+}
+
+// userDataExportColumns are the columns selected for jobs.
+const userDataExportColumns = `export_id, ` + constants.ColumnUserID + `, format, status, last_document_id, last_entity_id,
+               documents_exported, entities_exported, chunks, resumes, last_error, lease_until,
+               created_at, updated_at, completed_at`
+
+// Create stores a new pending job.
+func (r *PostgresUserDataExportRepository) Create(ctx context.Context, job *models.UserDataExportJob) error {
+	// Start query timer
+	startTime := time.Now()
+
+	// Define the query
+	query := `
+        INSERT INTO ` + constants.TableUserDataExports + ` (` + constants.ColumnUserID + `, format, status, created_at, updated_at)
+        VALUES ($1, $2, $3, $4, $4)
+        RETURNING export_id
+    `
+	now := time.Now()
+
+	// Execute the query
+	err := r.db.QueryRowContext(ctx, query, job.UserID, job.Format, constants.UserDataExportPending, now).Scan(&job.ID)
+
+	// Log the query execution
+	utils.LogDBQuery(
+		query,
+		[]interface{}{job.UserID, job.Format, constants.UserDataExportPending, now},
+		time.Since(startTime),
+		err,
+	)
+
+	if err != nil {
+		var pqErr *pq.Error
+		if errors.As(err, &pqErr) && pqErr.Code == constants.PGErrorDuplicateConstraint {
+			return utils.NewDuplicateError("UserDataExport", "status", constants.UserDataExportPending).WithCause(err)
+		}
+		return fmt.Errorf("failed to create user data export: %w", err)
+	}
+	job.Status = constants.UserDataExportPending
+	job.CreatedAt = now
+	job.UpdatedAt = now
+
+	return nil
+}
+
+// GetByID retrieves a job of a user.
+func (r *PostgresUserDataExportRepository) GetByID(ctx context.Context, userID, id int64) (*models.UserDataExportJob, error) {
+	// Start query timer
+	startTime := time.Now()
+
+	// Define the query
+	query := `
+        SELECT ` + userDataExportColumns + `
+        FROM ` + constants.TableUserDataExports + `
+        WHERE export_id = $1 AND ` + constants.ColumnUserID + ` = $2
+    `
+
+	// Execute the query
+	job, err := scanUserDataExport(r.db.QueryRowContext(ctx, query, id, userID))
+
+	// Log the query execution
+	utils.LogDBQuery(
+		query,
+		[]interface{}{id, userID},
+		time.Since(startTime),
+		err,
+	)
+
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, utils.NewNotFoundError("UserDataExport", id)
+		}
+		return nil, fmt.Errorf("failed to get user data export: %w", err)
+	}
+
+	return job, nil
+}
+
+// ListByUser retrieves the jobs of a user, newest first.
+func (r *PostgresUserDataExportRepository) ListByUser(ctx context.Context, userID int64) ([]*models.UserDataExportJob, error) {
+	// Start query timer
+	startTime := time.Now()
+
+	// Define the query
+	query := `
+        SELECT ` + userDataExportColumns + `
+        FROM ` + constants.TableUserDataExports + `
+        WHERE ` + constants.ColumnUserID + ` = $1
+        ORDER BY created_at DESC, export_id DESC
+    `
+
+	// Execute the query
+	jobs, err := r.query(ctx, query, userID)
+
+	// Log the query execution
+	utils.LogDBQuery(
+		query,
+		[]interface{}{userID},
+		time.Since(startTime),
+		err,
+	)
+
+	if err != nil {
+		return nil, fmt.Errorf("failed to list user data exports: %w", err)
+	}
+
+	return jobs, nil
+}
+
+// Claim takes the oldest job waiting to run.
+// The job is locked while it is claimed; jobs locked by another server are skipped.
+func (r *PostgresUserDataExportRepository) Claim(ctx context.Context, now, leaseUntil time.Time) (*models.UserDataExportJob, error) {
+	// Start query timer
+	startTime := time.Now()
+
+	// Define the query
+	query := `
+        UPDATE ` + constants.TableUserDataExports + `
+        SET resumes = resumes + CASE WHEN status = $1 THEN 0 ELSE 1 END,
+            status = $2,
+            lease_until = $3,
+            updated_at = $4
+        WHERE export_id = (
+            SELECT export_id
+            FROM ` + constants.TableUserDataExports + `
+            WHERE status IN ($1, $5) OR (status = $2 AND lease_until < $4)
+            ORDER BY created_at
+            LIMIT 1
+            FOR UPDATE SKIP LOCKED
+        )
+        RETURNING ` + userDataExportColumns + `
+    `
+	args := []interface{}{constants.UserDataExportPending, constants.UserDataExportRunning, leaseUntil, now, constants.UserDataExportInterrupted}
+
+	// Execute the query
+	job, err := scanUserDataExport(r.db.QueryRowContext(ctx, query, args...))
+
+	// Log the query execution
+	utils.LogDBQuery(
+		query,
+		args,
+		time.Since(startTime),
+		err,
+	)
+
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("failed to claim user data export: %w", err)
+	}
+
+	return job, nil
+}
+
+// Checkpoint stores the next chunks of a running job together with its new checkpoint.
+func (r *PostgresUserDataExportRepository) Checkpoint(ctx context.Context, job *models.UserDataExportJob, checkpoint models.UserDataExportCheckpoint, chunks []*models.UserDataExportChunk, leaseUntil time.Time) error {
+	// Start query timer
+	startTime := time.Now()
+
+	// Encrypt the chunks before opening the transaction
+	sealed := make([]string, len(chunks))
+	if len(chunks) > 0 {
+		dataKey, err := r.tenantKeys.GetOrCreateDataKey(ctx, job.UserID)
+		if err != nil {
+			return fmt.Errorf("failed to get tenant data key: %w", err)
+		}
+		for i, chunk := range chunks {
+			ciphertext, err := utils.EncryptKey(string(chunk.Data), dataKey)
+			if err != nil {
+				return fmt.Errorf("failed to encrypt export chunk: %w", err)
+			}
+			sealed[i] = constants.TenantCiphertextPrefix + ciphertext
+		}
+	}
+
+	err := r.db.Transaction(ctx, func(tx *sql.Tx) error {
+		// Move the checkpoint first; it fails if the job is no longer ours
+		updateQuery := `
+            UPDATE ` + constants.TableUserDataExports + `
+            SET last_document_id = $1,
+                last_entity_id = $2,
+                documents_exported = $3,
+                entities_exported = $4,
+                chunks = $5,
+                lease_until = $6,
+                updated_at = $7
+            WHERE export_id = $8 AND status = $9 AND chunks = $10
+        `
+		result, err := tx.ExecContext(ctx, updateQuery,
+			checkpoint.LastDocumentID, checkpoint.LastEntityID, checkpoint.DocumentsExported, checkpoint.EntitiesExported,
+			checkpoint.Chunks, leaseUntil, time.Now(), job.ID, constants.UserDataExportRunning, job.Checkpoint.Chunks)
+		if err != nil {
+			return fmt.Errorf("failed to update export checkpoint: %w", err)
+		}
+		rowsAffected, err := result.RowsAffected()
+		if err != nil {
+			return fmt.Errorf("failed to get affected rows: %w", err)
+		}
+		if rowsAffected == 0 {
+			return utils.NewNotFoundError("UserDataExport", job.ID)
+		}
+
+		insertQuery := `
+            INSERT INTO ` + constants.TableUserDataExportChunks + ` (export_id, seq, section, data)
+            VALUES ($1, $2, $3, $4)
+        `
+		for i, chunk := range chunks {
+			if _, err := tx.ExecContext(ctx, insertQuery, job.ID, job.Checkpoint.Chunks+i, chunk.Section, sealed[i]); err != nil {
+				return fmt.Errorf("failed to store export chunk: %w", err)
+			}
+		}
+		return nil
+	})
+
+	// Log the transaction; the chunks are left out
+	utils.LogDBQuery(
+		"UPDATE "+constants.TableUserDataExports+" checkpoint",
+		[]interface{}{job.ID, checkpoint.LastDocumentID, checkpoint.LastEntityID, len(chunks)},
+		time.Since(startTime),
+		err,
+	)
+
+	if err != nil {
+		return err
+	}
+	job.Checkpoint = checkpoint
+	job.LeaseUntil = &leaseUntil
+
+	return nil
+}
+
+// Finish ends a run of a job and releases its lease.
+func (r *PostgresUserDataExportRepository) Finish(ctx context.Context, id int64, status, lastError string) error {
+	// Start query timer
+	startTime := time.Now()
+
+	// Define the query
+	query := `
+        UPDATE ` + constants.TableUserDataExports + `
+        SET status = $1,
+            last_error = $2,
+            lease_until = NULL,
+            updated_at = $3,
+            completed_at = CASE WHEN $1 = '` + constants.UserDataExportCompleted + `' THEN $3 ELSE completed_at END
+        WHERE export_id = $4
+    `
+	now := time.Now()
+
+	// Execute the query
+	result, err := r.db.ExecContext(ctx, query, status, lastError, now, id)
+
+	// Log the query execution
+	utils.LogDBQuery(
+		query,
+		[]interface{}{status, lastError, now, id},
+		time.Since(startTime),
+		err,
+	)
+
+	if err != nil {
+		return fmt.Errorf("failed to finish user data export: %w", err)
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("failed to get affected rows: %w", err)
+	}
+
+	if rowsAffected == 0 {
+		return utils.NewNotFoundError("UserDataExport", id)
+	}
+
+	return nil
+}
+
+// StreamChunks passes the chunks of a section of a job's output to fn in the order they were stored.
+func (r *PostgresUserDataExportRepository) StreamChunks(ctx context.Context, userID, id int64, section string, fn func(*models.UserDataExportChunk) error) error {
+	// Start query timer
+	startTime := time.Now()
+
+	// Define the query
+	query := `
+        SELECT data
+        FROM ` + constants.TableUserDataExportChunks + `
+        WHERE export_id = $1 AND section = $2
+        ORDER BY seq
+    `
+
+	// Execute the query
+	rows, err := r.db.QueryContext(ctx, query, id, section)
+
+	// Log the query execution
+	utils.LogDBQuery(
+		query,
+		[]interface{}{id, section},
+		time.Since(startTime),
+		err,
+	)
+
+	if err != nil {
+		return fmt.Errorf("failed to get export chunks: %w", err)
+	}
+	defer func() {
+		if closeErr := rows.Close(); closeErr != nil {
+			log.Error().Err(closeErr).Msg("failed to close rows")
+		}
+	}()
+
+	var dataKey []byte
+	for rows.Next() {
+		if err := checkContext(ctx); err != nil {
+			return fmt.Errorf("export chunk stream stopped: %w", err)
+		}
+
+		var ciphertext string
+		if err := rows.Scan(&ciphertext); err != nil {
+			return fmt.Errorf("failed to scan export chunk row: %w", err)
+		}
+
+		if dataKey == nil {
+			if dataKey, err = r.tenantKeys.GetDataKey(ctx, userID); err != nil {
+				return fmt.Errorf("failed to get tenant data key: %w", err)
+			}
+		}
+		data, err := utils.DecryptKey(strings.TrimPrefix(ciphertext, constants.TenantCiphertextPrefix), dataKey)
+		if err != nil {
+			return fmt.Errorf("failed to decrypt export chunk: %w", err)
+		}
+
+		if err := fn(&models.UserDataExportChunk{Section: section, Data: []byte(data)}); err != nil {
+			return err
+		}
+	}
+
+	if err := rows.Err(); err != nil {
+		return fmt.Errorf("error iterating export chunk rows: %w", err)
+	}
+
+	return nil
+}
+
+// DeleteFinishedBefore removes completed and failed jobs last changed before a cutoff.
+// Their chunks are removed with them by the foreign key.
+func (r *PostgresUserDataExportRepository) DeleteFinishedBefore(ctx context.Context, cutoff time.Time) (int64, error) {
+	// Start query timer
+	startTime := time.Now()
+
+	// Define the query
+	query := `
+        DELETE FROM ` + constants.TableUserDataExports + `
+        WHERE status IN ($1, $2) AND updated_at < $3
+    `
+
+	// Execute the query
+	result, err := r.db.ExecContext(ctx, query, constants.UserDataExportCompleted, constants.UserDataExportFailed, cutoff)
+
+	// Log the query execution
+	utils.LogDBQuery(
+		query,
+		[]interface{}{constants.UserDataExportCompleted, constants.UserDataExportFailed, cutoff},
+		time.Since(startTime),
+		err,
+	)
+
+	if err != nil {
+		return 0, fmt.Errorf("failed to delete finished user data exports: %w", err)
+	}
+
+	return result.RowsAffected()
+}
+
+// query runs a query selecting userDataExportColumns and scans the jobs.
+func (r *PostgresUserDataExportRepository) query(ctx context.Context, query string, args ...interface{}) ([]*models.UserDataExportJob, error) {
+	rows, err := r.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	jobs := []*models.UserDataExportJob{}
+	for rows.Next() {
+		job, err := scanUserDataExport(rows)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan user data export row: %w", err)
+		}
+		jobs = append(jobs, job)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating user data export rows: %w", err)
+	}
+
+	return jobs, nil
+}
+
+// scanUserDataExport scans a row of userDataExportColumns.
+func scanUserDataExport(scanner interface{ Scan(dest ...any) error }) (*models.UserDataExportJob, error) {
+	job := &models.UserDataExportJob{}
+	err := scanner.Scan(
+		&job.ID, &job.UserID, &job.Format, &job.Status,
+		&job.Checkpoint.LastDocumentID, &job.Checkpoint.LastEntityID,
+		&job.Checkpoint.DocumentsExported, &job.Checkpoint.EntitiesExported, &job.Checkpoint.Chunks,
+		&job.Resumes, &job.LastError, &job.LeaseUntil,
+		&job.CreatedAt, &job.UpdatedAt, &job.CompletedAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+	return job, nil
+}
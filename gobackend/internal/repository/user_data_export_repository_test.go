@@ -0,0 +1,192 @@
+package repository_test
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/lib/pq"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/yasinhessnawi1/Hideme_Backend/internal/constants"
+	"github.com/yasinhessnawi1/Hideme_Backend/internal/database"
+	"github.com/yasinhessnawi1/Hideme_Backend/internal/models"
+	"github.com/yasinhessnawi1/Hideme_Backend/internal/repository"
+	"github.com/yasinhessnawi1/Hideme_Backend/internal/utils"
+)
+
+var userDataExportTestColumns = []string{
+	"export_id", "user_id", "format", "status", "last_document_id", "last_entity_id",
+	"documents_exported", "entities_exported", "chunks", "resumes", "last_error", "lease_until",
+	"created_at", "updated_at", "completed_at",
+}
+
+func setupUserDataExportTest(t *testing.T) (repository.UserDataExportRepository, sqlmock.Sqlmock, func()) {
+	db, mock, err := sqlmock.New()
+	require.NoError(t, err)
+
+	tenantKeys := &stubTenantKeyRepository{keys: make(map[int64][]byte)}
+	repo := repository.NewUserDataExportRepository(&database.Pool{DB: db}, tenantKeys)
+
+	return repo, mock, func() { db.Close() }
+}
+
+func TestUserDataExportRepository_Create(t *testing.T) {
+	repo, mock, cleanup := setupUserDataExportTest(t)
+	defer cleanup()
+
+	mock.ExpectQuery("INSERT INTO user_data_exports").
+		WithArgs(int64(7), constants.DocumentExportFormatJSON, constants.UserDataExportPending, sqlmock.AnyArg()).
+		WillReturnRows(sqlmock.NewRows([]string{"export_id"}).AddRow(3))
+
+	job := &models.UserDataExportJob{UserID: 7, Format: constants.DocumentExportFormatJSON}
+	require.NoError(t, repo.Create(context.Background(), job))
+	assert.Equal(t, int64(3), job.ID)
+	assert.Equal(t, constants.UserDataExportPending, job.Status)
+
+	// A second unfinished job violates the unique index
+	mock.ExpectQuery("INSERT INTO user_data_exports").
+		WillReturnError(&pq.Error{Code: constants.PGErrorDuplicateConstraint})
+
+	err := repo.Create(context.Background(), &models.UserDataExportJob{UserID: 7, Format: constants.DocumentExportFormatJSON})
+	var appErr *utils.AppError
+	require.True(t, errors.As(err, &appErr))
+	assert.True(t, errors.Is(appErr.Err, utils.ErrDuplicate))
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestUserDataExportRepository_GetByID_NotFound(t *testing.T) {
+	repo, mock, cleanup := setupUserDataExportTest(t)
+	defer cleanup()
+
+	mock.ExpectQuery("SELECT (.+) FROM user_data_exports WHERE export_id = \\$1 AND user_id = \\$2").
+		WithArgs(int64(3), int64(7)).
+		WillReturnRows(sqlmock.NewRows(userDataExportTestColumns))
+
+	_, err := repo.GetByID(context.Background(), 7, 3)
+	var appErr *utils.AppError
+	require.True(t, errors.As(err, &appErr))
+	assert.True(t, errors.Is(appErr.Err, utils.ErrNotFound))
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestUserDataExportRepository_Claim(t *testing.T) {
+	repo, mock, cleanup := setupUserDataExportTest(t)
+	defer cleanup()
+	now := time.Now()
+	leaseUntil := now.Add(constants.UserDataExportLease)
+
+	mock.ExpectQuery("UPDATE user_data_exports SET resumes = (.+) FOR UPDATE SKIP LOCKED").
+		WithArgs(constants.UserDataExportPending, constants.UserDataExportRunning, leaseUntil, now, constants.UserDataExportInterrupted).
+		WillReturnRows(sqlmock.NewRows(userDataExportTestColumns).
+			AddRow(3, 7, "zip", constants.UserDataExportRunning, 120, 900, 40, 300, 3, 1, "", leaseUntil, now, now, nil))
+
+	job, err := repo.Claim(context.Background(), now, leaseUntil)
+	require.NoError(t, err)
+	require.NotNil(t, job)
+	assert.Equal(t, int64(120), job.Checkpoint.LastDocumentID)
+	assert.Equal(t, int64(900), job.Checkpoint.LastEntityID)
+	assert.Equal(t, 3, job.Checkpoint.Chunks)
+	assert.Equal(t, 1, job.Resumes)
+
+	// Nothing waiting
+	mock.ExpectQuery("UPDATE user_data_exports SET resumes").
+		WillReturnRows(sqlmock.NewRows(userDataExportTestColumns))
+
+	job, err = repo.Claim(context.Background(), now, leaseUntil)
+	require.NoError(t, err)
+	assert.Nil(t, job)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestUserDataExportRepository_CheckpointAndStream(t *testing.T) {
+	repo, mock, cleanup := setupUserDataExportTest(t)
+	defer cleanup()
+	leaseUntil := time.Now().Add(constants.UserDataExportLease)
+
+	job := &models.UserDataExportJob{ID: 3, UserID: 7, Checkpoint: models.UserDataExportCheckpoint{LastDocumentID: 4, Chunks: 2}}
+	checkpoint := models.UserDataExportCheckpoint{LastDocumentID: 9, LastEntityID: 30, DocumentsExported: 5, EntitiesExported: 8, Chunks: 3}
+	chunk := &models.UserDataExportChunk{Section: models.UserDataExportSectionDocuments, Data: []byte(`[{"filename":"contract.pdf"}]`)}
+
+	// The chunk is appended after the existing ones in the transaction moving the checkpoint
+	sealed := &capturedArg{}
+	mock.ExpectBegin()
+	mock.ExpectExec("UPDATE user_data_exports SET last_document_id = \\$1").
+		WithArgs(int64(9), int64(30), int64(5), int64(8), 3, leaseUntil, sqlmock.AnyArg(), int64(3), constants.UserDataExportRunning, 2).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectExec("INSERT INTO user_data_export_chunks").
+		WithArgs(int64(3), 2, models.UserDataExportSectionDocuments, sealed).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectCommit()
+
+	require.NoError(t, repo.Checkpoint(context.Background(), job, checkpoint, []*models.UserDataExportChunk{chunk}, leaseUntil))
+	assert.Equal(t, checkpoint, job.Checkpoint)
+	assert.True(t, strings.HasPrefix(sealed.value.(string), constants.TenantCiphertextPrefix))
+	assert.NotContains(t, sealed.value.(string), "contract.pdf")
+
+	// Reading the chunk decrypts it with the tenant key
+	mock.ExpectQuery("SELECT data FROM user_data_export_chunks WHERE export_id = \\$1 AND section = \\$2 ORDER BY seq").
+		WithArgs(int64(3), models.UserDataExportSectionDocuments).
+		WillReturnRows(sqlmock.NewRows([]string{"data"}).AddRow(sealed.value))
+
+	var chunks []string
+	err := repo.StreamChunks(context.Background(), 7, 3, models.UserDataExportSectionDocuments, func(c *models.UserDataExportChunk) error {
+		chunks = append(chunks, string(c.Data))
+		return nil
+	})
+	require.NoError(t, err)
+	assert.Equal(t, []string{string(chunk.Data)}, chunks)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestUserDataExportRepository_Checkpoint_TakenOver(t *testing.T) {
+	repo, mock, cleanup := setupUserDataExportTest(t)
+	defer cleanup()
+
+	job := &models.UserDataExportJob{ID: 3, UserID: 7, Checkpoint: models.UserDataExportCheckpoint{Chunks: 2}}
+
+	// Another server moved the checkpoint on; nothing is stored
+	mock.ExpectBegin()
+	mock.ExpectExec("UPDATE user_data_exports SET last_document_id").
+		WillReturnResult(sqlmock.NewResult(0, 0))
+	mock.ExpectRollback()
+
+	err := repo.Checkpoint(context.Background(), job, models.UserDataExportCheckpoint{Chunks: 3},
+		[]*models.UserDataExportChunk{{Section: models.UserDataExportSectionDocuments, Data: []byte(`[]`)}}, time.Now())
+	var appErr *utils.AppError
+	require.True(t, errors.As(err, &appErr))
+	assert.True(t, errors.Is(appErr.Err, utils.ErrNotFound))
+	assert.Equal(t, 2, job.Checkpoint.Chunks)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestUserDataExportRepository_Finish(t *testing.T) {
+	repo, mock, cleanup := setupUserDataExportTest(t)
+	defer cleanup()
+
+	mock.ExpectExec("UPDATE user_data_exports SET status = \\$1, last_error = \\$2, lease_until = NULL").
+		WithArgs(constants.UserDataExportInterrupted, "", sqlmock.AnyArg(), int64(3)).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+
+	require.NoError(t, repo.Finish(context.Background(), 3, constants.UserDataExportInterrupted, ""))
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestUserDataExportRepository_DeleteFinishedBefore(t *testing.T) {
+	repo, mock, cleanup := setupUserDataExportTest(t)
+	defer cleanup()
+	cutoff := time.Now().Add(-constants.UserDataExportRetention)
+
+	mock.ExpectExec("DELETE FROM user_data_exports WHERE status IN \\(\\$1, \\$2\\) AND updated_at < \\$3").
+		WithArgs(constants.UserDataExportCompleted, constants.UserDataExportFailed, cutoff).
+		WillReturnResult(sqlmock.NewResult(0, 4))
+
+	deleted, err := repo.DeleteFinishedBefore(context.Background(), cutoff)
+	require.NoError(t, err)
+	assert.Equal(t, int64(4), deleted)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
@@ -0,0 +1,146 @@
+// Package repository provides data access interfaces and implementations for the HideMe application.
+// It follows the repository pattern to abstract database operations and provide a clean API
+// for data persistence operations.
+//
+// This file implements the user identity repository, which links user accounts to the
+// accounts users sign in with at OpenID Connect providers.
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/lib/pq"
+
+	"github.com/yasinhessnawi1/Hideme_Backend/internal/constants"
+	"github.com/yasinhessnawi1/Hideme_Backend/internal/database"
+	"github.com/yasinhessnawi1/Hideme_Backend/internal/models"
+	"github.com/yasinhessnawi1/Hideme_Backend/internal/utils"
+)
+
+// UserIdentityRepository defines methods for linking users to their sign-in provider accounts.
+type UserIdentityRepository interface {
+	// Create links a provider account to a user.
+	//
+	// Parameters:
+	//   - ctx: Context for transaction and cancellation control
+	//   - identity: The identity to store; its ID is set on success
+	//
+	// Returns:
+	//   - DuplicateError if the provider account is already linked, or the user already
+	//     has an account at the provider
+	//   - Other errors for database issues
+	Create(ctx context.Context, identity *models.UserIdentity) error
+
+	// GetByProviderSubject retrieves the identity of a provider account.
+	//
+	// Parameters:
+	//   - ctx: Context for transaction and cancellation control
+	//   - provider: The sign-in provider, such as google
+	//   - subject: The identifier of the user at the provider
+	//
+	// Returns:
+	//   - The identity
+	//   - NotFoundError if the provider account is not linked to any user
+	//   - Other errors for database issues
+	GetByProviderSubject(ctx context.Context, provider, subject string) (*models.UserIdentity, error)
+}
+
+// PostgresUserIdentityRepository is a PostgreSQL implementation of UserIdentityRepository.
+type PostgresUserIdentityRepository struct {
+	db *database.Pool
+}
+
+// NewUserIdentityRepository creates a new UserIdentityRepository implementation for PostgreSQL.
+//
+// Parameters:
+//   - db: A connection pool for PostgreSQL database access
+//
+// Returns:
+//   - An implementation of the UserIdentityRepository interface
+func NewUserIdentityRepository(db *database.Pool) UserIdentityRepository {
+	return &PostgresUserIdentityRepository{
+		db: db,
+	}
+}
+
+// Create links a provider account to a user.
+func (r *PostgresUserIdentityRepository) Create(ctx context.Context, identity *models.UserIdentity) error {
+	// Start query timer
+	startTime := time.Now()
+
+	// Define the query
+	query := `
+        INSERT INTO ` + constants.TableUserIdentities + ` (` + constants.ColumnUserID + `, provider, subject, email, created_at)
+        VALUES ($1, $2, $3, $4, $5)
+        RETURNING identity_id
+    `
+
+	// Execute the query
+	err := r.db.QueryRowContext(
+		ctx,
+		query,
+		identity.UserID,
+		identity.Provider,
+		identity.Subject,
+		identity.Email,
+		identity.CreatedAt,
+	).Scan(&identity.ID)
+
+	// Log the query execution
+	utils.LogDBQuery(
+		query,
+		[]interface{}{identity.UserID, identity.Provider, identity.Subject, identity.Email, identity.CreatedAt},
+		time.Since(startTime),
+		err,
+	)
+
+	if err != nil {
+		var pqErr *pq.Error
+		if errors.As(err, &pqErr) && pqErr.Code == constants.PGErrorDuplicateConstraint {
+			return utils.NewDuplicateError("Identity", "provider", identity.Provider).WithCause(err)
+		}
+		return fmt.Errorf("failed to create user identity: %w", err)
+	}
+
+	return nil
+}
+
+// GetByProviderSubject retrieves the identity of a provider account.
+func (r *PostgresUserIdentityRepository) GetByProviderSubject(ctx context.Context, provider, subject string) (*models.UserIdentity, error) {
+	// Start query timer
+	startTime := time.Now()
+
+	// Define the query
+	query := `
+        SELECT identity_id, ` + constants.ColumnUserID + `, provider, subject, email, created_at
+        FROM ` + constants.TableUserIdentities + `
+        WHERE provider = $1 AND subject = $2
+    `
+
+	// Execute the query
+	identity := &models.UserIdentity{}
+	err := r.db.QueryRowContext(ctx, query, provider, subject).Scan(
+		&identity.ID, &identity.UserID, &identity.Provider, &identity.Subject, &identity.Email, &identity.CreatedAt,
+	)
+
+	// Log the query execution
+	utils.LogDBQuery(
+		query,
+		[]interface{}{provider, subject},
+		time.Since(startTime),
+		err,
+	)
+
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, utils.NewNotFoundError("Identity", provider).WithCause(err)
+		}
+		return nil, fmt.Errorf("failed to get user identity: %w", err)
+	}
+
+	return identity, nil
+}
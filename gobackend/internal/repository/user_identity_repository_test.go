@@ -0,0 +1,103 @@
+package repository_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/lib/pq"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/yasinhessnawi1/Hideme_Backend/internal/constants"
+	"github.com/yasinhessnawi1/Hideme_Backend/internal/database"
+	"github.com/yasinhessnawi1/Hideme_Backend/internal/models"
+	"github.com/yasinhessnawi1/Hideme_Backend/internal/repository"
+	"github.com/yasinhessnawi1/Hideme_Backend/internal/utils"
+)
+
+func setupUserIdentityTest(t *testing.T) (repository.UserIdentityRepository, sqlmock.Sqlmock, func()) {
+	db, mock, err := sqlmock.New()
+	require.NoError(t, err)
+
+	repo := repository.NewUserIdentityRepository(&database.Pool{DB: db})
+
+	return repo, mock, func() { db.Close() }
+}
+
+func TestUserIdentityRepository_Create(t *testing.T) {
+	t.Run("Success", func(t *testing.T) {
+		// Arrange
+		repo, mock, cleanup := setupUserIdentityTest(t)
+		defer cleanup()
+		identity := models.NewUserIdentity(7, constants.OAuthProviderGoogle, "subject-1", "ola@example.com")
+
+		mock.ExpectQuery("INSERT INTO user_identities (.+) RETURNING identity_id").
+			WithArgs(int64(7), constants.OAuthProviderGoogle, "subject-1", "ola@example.com", identity.CreatedAt).
+			WillReturnRows(sqlmock.NewRows([]string{"identity_id"}).AddRow(3))
+
+		// Act
+		err := repo.Create(context.Background(), identity)
+
+		// Assert
+		require.NoError(t, err)
+		assert.Equal(t, int64(3), identity.ID)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("Already linked", func(t *testing.T) {
+		// Arrange
+		repo, mock, cleanup := setupUserIdentityTest(t)
+		defer cleanup()
+
+		mock.ExpectQuery("INSERT INTO user_identities").
+			WillReturnError(&pq.Error{Code: constants.PGErrorDuplicateConstraint})
+
+		// Act
+		err := repo.Create(context.Background(), models.NewUserIdentity(7, constants.OAuthProviderGoogle, "subject-1", ""))
+
+		// Assert
+		assert.True(t, utils.IsDuplicateError(err))
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+}
+
+func TestUserIdentityRepository_GetByProviderSubject(t *testing.T) {
+	t.Run("Success", func(t *testing.T) {
+		// Arrange
+		repo, mock, cleanup := setupUserIdentityTest(t)
+		defer cleanup()
+		now := time.Now()
+
+		mock.ExpectQuery("SELECT (.+) FROM user_identities WHERE provider = \\$1 AND subject = \\$2").
+			WithArgs(constants.OAuthProviderMicrosoft, "subject-1").
+			WillReturnRows(sqlmock.NewRows([]string{"identity_id", "user_id", "provider", "subject", "email", "created_at"}).
+				AddRow(3, 7, constants.OAuthProviderMicrosoft, "subject-1", "ola@example.com", now))
+
+		// Act
+		identity, err := repo.GetByProviderSubject(context.Background(), constants.OAuthProviderMicrosoft, "subject-1")
+
+		// Assert
+		require.NoError(t, err)
+		assert.Equal(t, int64(7), identity.UserID)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("Not linked", func(t *testing.T) {
+		// Arrange
+		repo, mock, cleanup := setupUserIdentityTest(t)
+		defer cleanup()
+
+		mock.ExpectQuery("SELECT (.+) FROM user_identities").
+			WillReturnRows(sqlmock.NewRows([]string{"identity_id", "user_id", "provider", "subject", "email", "created_at"}))
+
+		// Act
+		identity, err := repo.GetByProviderSubject(context.Background(), constants.OAuthProviderGoogle, "unknown")
+
+		// Assert
+		assert.Nil(t, identity)
+		assert.True(t, utils.IsNotFoundError(err))
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+}
@@ -18,6 +18,7 @@ import (
 	"github.com/lib/pq"
 	"github.com/rs/zerolog/log"
 
+	"github.com/yasinhessnawi1/Hideme_Backend/internal/constants"
 	"github.com/yasinhessnawi1/Hideme_Backend/internal/database"
 	"github.com/yasinhessnawi1/Hideme_Backend/internal/models"
 	"github.com/yasinhessnawi1/Hideme_Backend/internal/utils"
@@ -161,6 +162,37 @@ type UserRepository interface {
 	//
 	// For privacy reasons, this method avoids logging the actual email address.
 	ExistsByEmail(ctx context.Context, email string) (bool, error)
+
+	// List retrieves users for administrators with pagination.
+	//
+	// Parameters:
+	//   - ctx: Context for transaction and cancellation control
+	//   - filter: The role and status to select; empty fields select all users
+	//   - page: The page number (starting from 1)
+	//   - pageSize: The number of users per page
+	//
+	// Returns:
+	//   - The matching users for the requested page, oldest account first
+	//   - The total count of matching users
+	//   - An error if retrieval fails
+	//
+	// Note: The caller should use user.Sanitize() before returning to clients.
+	List(ctx context.Context, filter models.UserFilter, page, pageSize int) ([]*models.User, int, error)
+
+	// SetDisabled disables or re-enables a user account.
+	//
+	// Parameters:
+	//   - ctx: Context for transaction and cancellation control
+	//   - id: The unique identifier of the user
+	//   - disabledAt: When the account was disabled; nil to enable it again
+	//
+	// Returns:
+	//   - NotFoundError if the user doesn't exist
+	//   - Other errors for database issues
+	//   - nil on successful update
+	//
+	// This method also updates the UpdatedAt timestamp.
+	SetDisabled(ctx context.Context, id int64, disabledAt *time.Time) error
 }
 
 // PostgresUserRepository is a PostgreSQL implementation of UserRepository.
@@ -236,15 +268,16 @@ func (r *PostgresUserRepository) Create(ctx context.Context, user *models.User)
 
 	if err != nil {
 		// Check for unique constraint violations using PostgreSQL error handling
-		if pqErr, ok := err.(*pq.Error); ok {
+		var pqErr *pq.Error
+		if errors.As(err, &pqErr) {
 			// 23505 is the PostgreSQL error code for unique_violation
 			if pqErr.Code == "23505" {
 				// Check which constraint was violated
 				if strings.Contains(pqErr.Constraint, "username") {
-					return utils.NewDuplicateError("User", "username", user.Username)
+					return utils.NewDuplicateError("User", "username", user.Username).WithCause(err)
 				}
 				if strings.Contains(pqErr.Constraint, "email") {
-					return utils.NewDuplicateError("User", "email", user.Email)
+					return utils.NewDuplicateError("User", "email", user.Email).WithCause(err)
 				}
 			}
 		}
@@ -288,7 +321,7 @@ func (r *PostgresUserRepository) GetByID(ctx context.Context, id int64) (*models
 
 	// Define the query
 	query := `
-    SELECT user_id, username, email, password_hash, salt, role, created_at, updated_at
+    SELECT user_id, username, email, password_hash, salt, role, created_at, updated_at, disabled_at
     FROM users
     WHERE user_id = $1
 `
@@ -304,6 +337,7 @@ func (r *PostgresUserRepository) GetByID(ctx context.Context, id int64) (*models
 		&user.Role,
 		&user.CreatedAt,
 		&user.UpdatedAt,
+		&user.DisabledAt,
 	)
 
 	// Log the query execution
@@ -316,7 +350,7 @@ func (r *PostgresUserRepository) GetByID(ctx context.Context, id int64) (*models
 
 	if err != nil {
 		if errors.Is(err, sql.ErrNoRows) {
-			return nil, utils.NewNotFoundError("User", id)
+			return nil, utils.NewNotFoundError("User", id).WithCause(err)
 		}
 		return nil, fmt.Errorf("failed to get user by ID: %w", err)
 	}
@@ -341,7 +375,7 @@ func (r *PostgresUserRepository) GetByUsername(ctx context.Context, username str
 
 	// Define the query with case-insensitive comparison for PostgreSQL
 	query := `
-        SELECT user_id, username, email, password_hash, salt, role, created_at, updated_at
+        SELECT user_id, username, email, password_hash, salt, role, created_at, updated_at, disabled_at
         FROM users
         WHERE LOWER(username) = LOWER($1)
     `
@@ -357,6 +391,7 @@ func (r *PostgresUserRepository) GetByUsername(ctx context.Context, username str
 		&user.Role,
 		&user.CreatedAt,
 		&user.UpdatedAt,
+		&user.DisabledAt,
 	)
 
 	// Log the query execution
@@ -369,7 +404,7 @@ func (r *PostgresUserRepository) GetByUsername(ctx context.Context, username str
 
 	if err != nil {
 		if errors.Is(err, sql.ErrNoRows) {
-			return nil, utils.NewNotFoundError("User", fmt.Sprintf("username=%s", username))
+			return nil, utils.NewNotFoundError("User", fmt.Sprintf("username=%s", username)).WithCause(err)
 		}
 		return nil, fmt.Errorf("failed to get user by username: %w", err)
 	}
@@ -396,7 +431,7 @@ func (r *PostgresUserRepository) GetByEmail(ctx context.Context, email string) (
 
 	// Define the query with case-insensitive comparison for PostgreSQL
 	query := `
-        SELECT user_id, username, email, password_hash, salt, role, created_at, updated_at
+        SELECT user_id, username, email, password_hash, salt, role, created_at, updated_at, disabled_at
         FROM users
         WHERE LOWER(email) = LOWER($1)
     `
@@ -412,6 +447,7 @@ func (r *PostgresUserRepository) GetByEmail(ctx context.Context, email string) (
 		&user.Role,
 		&user.CreatedAt,
 		&user.UpdatedAt,
+		&user.DisabledAt,
 	)
 
 	// Log the query execution with email redacted for GDPR compliance
@@ -424,7 +460,7 @@ func (r *PostgresUserRepository) GetByEmail(ctx context.Context, email string) (
 
 	if err != nil {
 		if errors.Is(err, sql.ErrNoRows) {
-			return nil, utils.NewNotFoundError("User", "email=[REDACTED]") // Don't include actual email in error
+			return nil, utils.NewNotFoundError("User", "email=[REDACTED]").WithCause(err) // Don't include actual email in error
 		}
 		return nil, fmt.Errorf("failed to get user by email: %w", err)
 	}
@@ -480,14 +516,15 @@ func (r *PostgresUserRepository) Update(ctx context.Context, user *models.User)
 
 	if err != nil {
 		// Check for unique constraint violations using PostgreSQL error handling
-		if pqErr, ok := err.(*pq.Error); ok {
+		var pqErr *pq.Error
+		if errors.As(err, &pqErr) {
 			// 23505 is the PostgreSQL error code for unique_violation
 			if pqErr.Code == "23505" {
 				if strings.Contains(pqErr.Constraint, "username") {
-					return utils.NewDuplicateError("User", "username", user.Username)
+					return utils.NewDuplicateError("User", "username", user.Username).WithCause(err)
 				}
 				if strings.Contains(pqErr.Constraint, "email") {
-					return utils.NewDuplicateError("User", "email", "[REDACTED]") // Don't include actual email in error
+					return utils.NewDuplicateError("User", "email", "[REDACTED]").WithCause(err) // Don't include actual email in error
 				}
 			}
 		}
@@ -735,3 +772,139 @@ func (r *PostgresUserRepository) ExistsByEmail(ctx context.Context, email string
 
 	return exists, nil
 }
+
+// List retrieves users for administrators with pagination.
+//
+// Parameters:
+//   - ctx: Context for transaction and cancellation control
+//   - filter: The role and status to select; empty fields select all users
+//   - page: The page number (starting from 1)
+//   - pageSize: The number of users per page
+//
+// Returns:
+//   - The matching users for the requested page, oldest account first
+//   - The total count of matching users
+//   - An error if retrieval fails
+func (r *PostgresUserRepository) List(ctx context.Context, filter models.UserFilter, page, pageSize int) ([]*models.User, int, error) {
+	// Start query timer
+	startTime := time.Now()
+
+	// Calculate offset
+	offset := (page - 1) * pageSize
+
+	// An account is disabled exactly when disabled_at is set
+	condition := `($1::text = '' OR role = $1) AND ($2::text = '' OR ($2 = '` + constants.UserStatusDisabled + `') = (disabled_at IS NOT NULL))`
+
+	// Get total count
+	countQuery := `SELECT COUNT(*) FROM ` + constants.TableUsers + ` WHERE ` + condition
+	var totalCount int
+	if err := r.db.QueryRowContext(ctx, countQuery, filter.Role, filter.Status).Scan(&totalCount); err != nil {
+		return nil, 0, fmt.Errorf("failed to count users: %w", err)
+	}
+
+	// Define the query
+	query := `
+    SELECT user_id, username, email, password_hash, salt, role, created_at, updated_at, disabled_at
+    FROM ` + constants.TableUsers + `
+    WHERE ` + condition + `
+    ORDER BY user_id
+    LIMIT $3 OFFSET $4
+`
+
+	// Execute the query
+	rows, err := r.db.QueryContext(ctx, query, filter.Role, filter.Status, pageSize, offset)
+
+	// Log the query execution
+	utils.LogDBQuery(
+		query,
+		[]interface{}{filter.Role, filter.Status, pageSize, offset},
+		time.Since(startTime),
+		err,
+	)
+
+	if err != nil {
+		return nil, 0, fmt.Errorf("failed to list users: %w", err)
+	}
+	defer func() {
+		if closeErr := rows.Close(); closeErr != nil {
+			log.Error().Err(closeErr).Msg("failed to close rows")
+		}
+	}()
+
+	// Parse the results
+	users := []*models.User{}
+	for rows.Next() {
+		user := &models.User{}
+		if err := rows.Scan(
+			&user.ID,
+			&user.Username,
+			&user.Email,
+			&user.PasswordHash,
+			&user.Salt,
+			&user.Role,
+			&user.CreatedAt,
+			&user.UpdatedAt,
+			&user.DisabledAt,
+		); err != nil {
+			return nil, 0, fmt.Errorf("failed to scan user row: %w", err)
+		}
+		users = append(users, user)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, 0, fmt.Errorf("error iterating user rows: %w", err)
+	}
+
+	return users, totalCount, nil
+}
+
+// SetDisabled disables or re-enables a user account.
+//
+// Parameters:
+//   - ctx: Context for transaction and cancellation control
+//   - id: The unique identifier of the user
+//   - disabledAt: When the account was disabled; nil to enable it again
+//
+// Returns:
+//   - NotFoundError if the user doesn't exist
+//   - Other errors for database issues
+//   - nil on successful update
+func (r *PostgresUserRepository) SetDisabled(ctx context.Context, id int64, disabledAt *time.Time) error {
+	// Start query timer
+	startTime := time.Now()
+
+	// Define the query
+	query := `
+        UPDATE ` + constants.TableUsers + `
+        SET ` + constants.ColumnDisabledAt + ` = $1, updated_at = $2
+        WHERE user_id = $3
+    `
+
+	// Execute the query
+	now := time.Now()
+	result, err := r.db.ExecContext(ctx, query, disabledAt, now, id)
+
+	// Log the query execution
+	utils.LogDBQuery(
+		query,
+		[]interface{}{disabledAt, now, id},
+		time.Since(startTime),
+		err,
+	)
+
+	if err != nil {
+		return fmt.Errorf("failed to update user status: %w", err)
+	}
+
+	// Check if any rows were affected
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("failed to get rows affected: %w", err)
+	}
+
+	if rowsAffected == 0 {
+		return utils.NewNotFoundError("User", id)
+	}
+
+	return nil
+}
@@ -226,11 +226,11 @@ func TestUserRepository_GetByID(t *testing.T) {
 	}
 
 	// Set up query result - include role field
-	rows := sqlmock.NewRows([]string{"user_id", "username", "email", "password_hash", "salt", "role", "created_at", "updated_at"}).
-		AddRow(expectedUser.ID, expectedUser.Username, expectedUser.Email, expectedUser.PasswordHash, expectedUser.Salt, expectedUser.Role, expectedUser.CreatedAt, expectedUser.UpdatedAt)
+	rows := sqlmock.NewRows([]string{"user_id", "username", "email", "password_hash", "salt", "role", "created_at", "updated_at", "disabled_at"}).
+		AddRow(expectedUser.ID, expectedUser.Username, expectedUser.Email, expectedUser.PasswordHash, expectedUser.Salt, expectedUser.Role, expectedUser.CreatedAt, expectedUser.UpdatedAt, nil)
 
 	// Expected query with placeholder for the ID - include role in SELECT
-	mock.ExpectQuery("SELECT user_id, username, email, password_hash, salt, role, created_at, updated_at FROM users WHERE user_id = \\$1").
+	mock.ExpectQuery("SELECT user_id, username, email, password_hash, salt, role, created_at, updated_at, disabled_at FROM users WHERE user_id = \\$1").
 		WithArgs(id).
 		WillReturnRows(rows)
 
@@ -260,7 +260,7 @@ func TestUserRepository_GetByID_DatabaseError(t *testing.T) {
 	id := int64(1)
 
 	// Mock database error - update the regex to include role
-	mock.ExpectQuery("SELECT user_id, username, email, password_hash, salt, role, created_at, updated_at FROM users WHERE user_id = \\$1").
+	mock.ExpectQuery("SELECT user_id, username, email, password_hash, salt, role, created_at, updated_at, disabled_at FROM users WHERE user_id = \\$1").
 		WithArgs(id).
 		WillReturnError(errors.New("database connection error"))
 
@@ -283,7 +283,7 @@ func TestUserRepository_GetByID_NotFound(t *testing.T) {
 	id := int64(999)
 
 	// Mock not found error - update the regex to include role
-	mock.ExpectQuery("SELECT user_id, username, email, password_hash, salt, role, created_at, updated_at FROM users WHERE user_id = \\$1").
+	mock.ExpectQuery("SELECT user_id, username, email, password_hash, salt, role, created_at, updated_at, disabled_at FROM users WHERE user_id = \\$1").
 		WithArgs(id).
 		WillReturnError(sql.ErrNoRows)
 
@@ -316,11 +316,11 @@ func TestUserRepository_GetByUsername(t *testing.T) {
 	}
 
 	// Set up query result - include role field
-	rows := sqlmock.NewRows([]string{"user_id", "username", "email", "password_hash", "salt", "role", "created_at", "updated_at"}).
-		AddRow(expectedUser.ID, expectedUser.Username, expectedUser.Email, expectedUser.PasswordHash, expectedUser.Salt, expectedUser.Role, expectedUser.CreatedAt, expectedUser.UpdatedAt)
+	rows := sqlmock.NewRows([]string{"user_id", "username", "email", "password_hash", "salt", "role", "created_at", "updated_at", "disabled_at"}).
+		AddRow(expectedUser.ID, expectedUser.Username, expectedUser.Email, expectedUser.PasswordHash, expectedUser.Salt, expectedUser.Role, expectedUser.CreatedAt, expectedUser.UpdatedAt, nil)
 
 	// Expected query with placeholder for the username - include role in SELECT
-	mock.ExpectQuery("SELECT user_id, username, email, password_hash, salt, role, created_at, updated_at FROM users WHERE LOWER\\(username\\) = LOWER\\(\\$1\\)").
+	mock.ExpectQuery("SELECT user_id, username, email, password_hash, salt, role, created_at, updated_at, disabled_at FROM users WHERE LOWER\\(username\\) = LOWER\\(\\$1\\)").
 		WithArgs(username).
 		WillReturnRows(rows)
 
@@ -350,7 +350,7 @@ func TestUserRepository_GetByUsername_DatabaseError(t *testing.T) {
 	username := "testuser"
 
 	// Mock database error - update to include role field
-	mock.ExpectQuery("SELECT user_id, username, email, password_hash, salt, role, created_at, updated_at FROM users WHERE LOWER\\(username\\) = LOWER\\(\\$1\\)").
+	mock.ExpectQuery("SELECT user_id, username, email, password_hash, salt, role, created_at, updated_at, disabled_at FROM users WHERE LOWER\\(username\\) = LOWER\\(\\$1\\)").
 		WithArgs(username).
 		WillReturnError(errors.New("database connection error"))
 
@@ -373,7 +373,7 @@ func TestUserRepository_GetByUsername_NotFound(t *testing.T) {
 	username := "nonexistent"
 
 	// Mock database response - no rows - update to include role field
-	mock.ExpectQuery("SELECT user_id, username, email, password_hash, salt, role, created_at, updated_at FROM users WHERE LOWER\\(username\\) = LOWER\\(\\$1\\)").
+	mock.ExpectQuery("SELECT user_id, username, email, password_hash, salt, role, created_at, updated_at, disabled_at FROM users WHERE LOWER\\(username\\) = LOWER\\(\\$1\\)").
 		WithArgs(username).
 		WillReturnError(sql.ErrNoRows)
 
@@ -407,11 +407,11 @@ func TestUserRepository_GetByEmail(t *testing.T) {
 	}
 
 	// Set up query result - update to include role field
-	rows := sqlmock.NewRows([]string{"user_id", "username", "email", "password_hash", "salt", "role", "created_at", "updated_at"}).
-		AddRow(expectedUser.ID, expectedUser.Username, expectedUser.Email, expectedUser.PasswordHash, expectedUser.Salt, expectedUser.Role, expectedUser.CreatedAt, expectedUser.UpdatedAt)
+	rows := sqlmock.NewRows([]string{"user_id", "username", "email", "password_hash", "salt", "role", "created_at", "updated_at", "disabled_at"}).
+		AddRow(expectedUser.ID, expectedUser.Username, expectedUser.Email, expectedUser.PasswordHash, expectedUser.Salt, expectedUser.Role, expectedUser.CreatedAt, expectedUser.UpdatedAt, nil)
 
 	// Expected query with role field
-	mock.ExpectQuery("SELECT user_id, username, email, password_hash, salt, role, created_at, updated_at FROM users WHERE LOWER\\(email\\) = LOWER\\(\\$1\\)").
+	mock.ExpectQuery("SELECT user_id, username, email, password_hash, salt, role, created_at, updated_at, disabled_at FROM users WHERE LOWER\\(email\\) = LOWER\\(\\$1\\)").
 		WithArgs(email).
 		WillReturnRows(rows)
 
@@ -438,7 +438,7 @@ func TestUserRepository_GetByEmail_DatabaseError(t *testing.T) {
 
 	// Mock a database error - include role field
 	dbErr := errors.New("database connection error")
-	mock.ExpectQuery("SELECT user_id, username, email, password_hash, salt, role, created_at, updated_at FROM users WHERE LOWER\\(email\\) = LOWER\\(\\$1\\)").
+	mock.ExpectQuery("SELECT user_id, username, email, password_hash, salt, role, created_at, updated_at, disabled_at FROM users WHERE LOWER\\(email\\) = LOWER\\(\\$1\\)").
 		WithArgs(email).
 		WillReturnError(dbErr)
 
@@ -461,7 +461,7 @@ func TestUserRepository_GetByEmail_NotFound(t *testing.T) {
 	email := "nonexistent@example.com"
 
 	// Mock database response - empty result - include role field
-	mock.ExpectQuery("SELECT user_id, username, email, password_hash, salt, role, created_at, updated_at FROM users WHERE LOWER\\(email\\) = LOWER\\(\\$1\\)").
+	mock.ExpectQuery("SELECT user_id, username, email, password_hash, salt, role, created_at, updated_at, disabled_at FROM users WHERE LOWER\\(email\\) = LOWER\\(\\$1\\)").
 		WithArgs(email).
 		WillReturnError(sql.ErrNoRows)
 
@@ -1057,3 +1057,94 @@ func TestUserRepository_ExistsByEmail_NotExists(t *testing.T) {
 	assert.False(t, exists)
 	assert.NoError(t, mock.ExpectationsWereMet())
 }
+
+func TestUserRepository_List(t *testing.T) {
+	// Set up the test
+	repo, mock, cleanup := setupUserRepositoryTest(t)
+	defer cleanup()
+
+	// Set up test data
+	now := time.Now()
+	filter := models.UserFilter{Role: "user", Status: "disabled"}
+
+	// Expected count and page queries with the filter
+	mock.ExpectQuery("SELECT COUNT\\(\\*\\) FROM users WHERE").
+		WithArgs(filter.Role, filter.Status).
+		WillReturnRows(sqlmock.NewRows([]string{"count"}).AddRow(3))
+	rows := sqlmock.NewRows([]string{"user_id", "username", "email", "password_hash", "salt", "role", "created_at", "updated_at", "disabled_at"}).
+		AddRow(int64(4), "bob", "bob@example.com", "hash", "salt", "user", now, now, now)
+	mock.ExpectQuery("SELECT user_id, username, email, password_hash, salt, role, created_at, updated_at, disabled_at FROM users WHERE .* ORDER BY user_id LIMIT \\$3 OFFSET \\$4").
+		WithArgs(filter.Role, filter.Status, 2, 2).
+		WillReturnRows(rows)
+
+	// Execute the method being tested
+	users, total, err := repo.List(context.Background(), filter, 2, 2)
+
+	// Assert the results
+	assert.NoError(t, err)
+	assert.Equal(t, 3, total)
+	require.Len(t, users, 1)
+	assert.Equal(t, "bob", users[0].Username)
+	assert.True(t, users[0].IsDisabled())
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestUserRepository_List_DatabaseError(t *testing.T) {
+	// Set up the test
+	repo, mock, cleanup := setupUserRepositoryTest(t)
+	defer cleanup()
+
+	// Expected count query fails
+	mock.ExpectQuery("SELECT COUNT\\(\\*\\) FROM users WHERE").
+		WithArgs("", "").
+		WillReturnError(errors.New("database error"))
+
+	// Execute the method being tested
+	users, total, err := repo.List(context.Background(), models.UserFilter{}, 1, 10)
+
+	// Assert the results
+	assert.Error(t, err)
+	assert.Nil(t, users)
+	assert.Equal(t, 0, total)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestUserRepository_SetDisabled(t *testing.T) {
+	// Set up the test
+	repo, mock, cleanup := setupUserRepositoryTest(t)
+	defer cleanup()
+
+	// Set up test data
+	id := int64(1)
+	disabledAt := time.Now()
+
+	// Expected query with placeholders for the arguments
+	mock.ExpectExec("UPDATE users SET disabled_at = \\$1, updated_at = \\$2 WHERE user_id = \\$3").
+		WithArgs(&disabledAt, sqlmock.AnyArg(), id).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+
+	// Execute the method being tested
+	err := repo.SetDisabled(context.Background(), id, &disabledAt)
+
+	// Assert the results
+	assert.NoError(t, err)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestUserRepository_SetDisabled_NotFound(t *testing.T) {
+	// Set up the test
+	repo, mock, cleanup := setupUserRepositoryTest(t)
+	defer cleanup()
+
+	// Expected query affects no rows
+	mock.ExpectExec("UPDATE users SET disabled_at = \\$1, updated_at = \\$2 WHERE user_id = \\$3").
+		WithArgs(nil, sqlmock.AnyArg(), int64(999)).
+		WillReturnResult(sqlmock.NewResult(0, 0))
+
+	// Execute the method being tested
+	err := repo.SetDisabled(context.Background(), 999, nil)
+
+	// Assert the results
+	assert.Error(t, err)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
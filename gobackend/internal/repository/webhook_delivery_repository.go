@@ -0,0 +1,440 @@
+// Package repository provides data access interfaces and implementations for the HideMe application.
+// It follows the repository pattern to abstract database operations and provide a clean API
+// for data persistence operations.
+//
+// This file implements the webhook delivery repository, which queues the events to deliver
+// to each webhook. Servers claim due deliveries with a lease, so a delivery is attempted by
+// one server at a time and again by another if that server stops.
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/yasinhessnawi1/Hideme_Backend/internal/constants"
+	"github.com/yasinhessnawi1/Hideme_Backend/internal/database"
+	"github.com/yasinhessnawi1/Hideme_Backend/internal/models"
+	"github.com/yasinhessnawi1/Hideme_Backend/internal/utils"
+)
+
+// WebhookDeliveryRepository defines methods for queuing and tracking deliveries of events to webhooks.
+type WebhookDeliveryRepository interface {
+	// Enqueue queues an event for each of a user's active webhooks subscribed to its type.
+	//
+	// Parameters:
+	//   - ctx: Context for transaction and cancellation control
+	//   - userID: The ID of the user the event belongs to
+	//   - eventType: The type of the event
+	//   - payload: The data of the event, as JSON
+	//   - now: When the event occurred; the deliveries are due at once
+	//
+	// Returns:
+	//   - The number of queued deliveries
+	//   - An error for database issues
+	Enqueue(ctx context.Context, userID int64, eventType string, payload []byte, now time.Time) (int64, error)
+
+	// Claim takes the pending delivery that has been due the longest and counts an attempt.
+	// It is not claimed again until leaseUntil, unless its outcome is recorded before.
+	//
+	// Parameters:
+	//   - ctx: Context for transaction and cancellation control
+	//   - now: The current time
+	//   - leaseUntil: When another server may attempt the delivery again
+	//
+	// Returns:
+	//   - The delivery with the user owning its webhook; nil if none is due
+	//   - An error for database issues
+	Claim(ctx context.Context, now, leaseUntil time.Time) (*models.WebhookDelivery, error)
+
+	// Finish records the outcome of an attempt: the status, last status code, last error
+	// and next attempt of the delivery.
+	//
+	// Parameters:
+	//   - ctx: Context for transaction and cancellation control
+	//   - delivery: The delivery with its outcome; UpdatedAt is the time of the attempt
+	//
+	// Returns:
+	//   - NotFoundError if the delivery no longer exists
+	//   - Other errors for database issues
+	Finish(ctx context.Context, delivery *models.WebhookDelivery) error
+
+	// GetByID retrieves a delivery to a user's webhook.
+	//
+	// Parameters:
+	//   - ctx: Context for transaction and cancellation control
+	//   - id: The ID of the delivery
+	//   - webhookID: The ID of the webhook
+	//   - userID: The ID of the user who must own the webhook
+	//
+	// Returns:
+	//   - The delivery
+	//   - NotFoundError if the webhook has no such delivery
+	//   - Other errors for database issues
+	GetByID(ctx context.Context, id, webhookID, userID int64) (*models.WebhookDelivery, error)
+
+	// ListByWebhook retrieves the most recent deliveries to a user's webhook, newest first.
+	//
+	// Parameters:
+	//   - ctx: Context for transaction and cancellation control
+	//   - webhookID: The ID of the webhook
+	//   - userID: The ID of the user who must own the webhook
+	//   - limit: The maximum number of deliveries to return
+	//
+	// Returns:
+	//   - The deliveries (empty if there are none)
+	//   - An error for database issues
+	ListByWebhook(ctx context.Context, webhookID, userID int64, limit int) ([]*models.WebhookDelivery, error)
+
+	// Redeliver queues a dead-lettered delivery again, with all its attempts.
+	//
+	// Parameters:
+	//   - ctx: Context for transaction and cancellation control
+	//   - id: The ID of the delivery
+	//   - now: The current time; the delivery is due at once
+	//
+	// Returns:
+	//   - NotFoundError if the delivery doesn't exist or is not dead-lettered
+	//   - Other errors for database issues
+	Redeliver(ctx context.Context, id int64, now time.Time) error
+
+	// DeleteCreatedBefore deletes the deliveries of events that occurred before a time,
+	// whatever their status.
+	//
+	// Parameters:
+	//   - ctx: Context for transaction and cancellation control
+	//   - before: The deliveries created before this time are deleted
+	//
+	// Returns:
+	//   - The number of deleted deliveries
+	//   - An error for database issues
+	DeleteCreatedBefore(ctx context.Context, before time.Time) (int64, error)
+}
+
+// PostgresWebhookDeliveryRepository is a PostgreSQL implementation of WebhookDeliveryRepository.
+type PostgresWebhookDeliveryRepository struct {
+	db *database.Pool
+}
+
+// NewWebhookDeliveryRepository creates a new WebhookDeliveryRepository implementation for PostgreSQL.
+//
+// Parameters:
+//   - db: A connection pool for PostgreSQL database access
+//
+// Returns:
+//   - An implementation of the WebhookDeliveryRepository interface
+func NewWebhookDeliveryRepository(db *database.Pool) WebhookDeliveryRepository {
+	return &PostgresWebhookDeliveryRepository{
+		db: db,
+	}
+}
+
+// webhookDeliveryColumns are the columns selected for deliveries joined with their webhook.
+const webhookDeliveryColumns = `d.delivery_id, d.webhook_id, w.` + constants.ColumnUserID + `, d.event_type, d.payload, d.status,
+               d.attempts, d.next_attempt_at, d.last_status_code, d.last_error, d.created_at, d.updated_at, d.delivered_at`
+
+// Enqueue queues an event for each of a user's active webhooks subscribed to its type.
+func (r *PostgresWebhookDeliveryRepository) Enqueue(ctx context.Context, userID int64, eventType string, payload []byte, now time.Time) (int64, error) {
+	// Start query timer
+	startTime := time.Now()
+
+	// Define the query
+	query := `
+        INSERT INTO ` + constants.TableWebhookDeliveries + ` (webhook_id, event_type, payload, status, next_attempt_at, created_at, updated_at)
+        SELECT webhook_id, $2, $3, $4, $5, $5, $5
+        FROM ` + constants.TableWebhooks + `
+        WHERE ` + constants.ColumnUserID + ` = $1 AND active AND $2 = ANY(events)
+    `
+	args := []interface{}{userID, eventType, string(payload), constants.WebhookDeliveryPending, now}
+
+	// Execute the query
+	result, err := r.db.ExecContext(ctx, query, args...)
+
+	// Log the query execution
+	utils.LogDBQuery(
+		query,
+		args,
+		time.Since(startTime),
+		err,
+	)
+
+	if err != nil {
+		return 0, fmt.Errorf("failed to enqueue webhook deliveries: %w", err)
+	}
+
+	queued, err := result.RowsAffected()
+	if err != nil {
+		return 0, fmt.Errorf("failed to get affected rows: %w", err)
+	}
+
+	return queued, nil
+}
+
+// Claim takes the pending delivery that has been due the longest and counts an attempt.
+func (r *PostgresWebhookDeliveryRepository) Claim(ctx context.Context, now, leaseUntil time.Time) (*models.WebhookDelivery, error) {
+	// Start query timer
+	startTime := time.Now()
+
+	// Define the query; the lease is the next attempt, should this one never finish
+	query := `
+        UPDATE ` + constants.TableWebhookDeliveries + ` d
+        SET attempts = d.attempts + 1,
+            next_attempt_at = $1,
+            updated_at = $2
+        FROM ` + constants.TableWebhooks + ` w
+        WHERE w.webhook_id = d.webhook_id
+          AND d.delivery_id = (
+            SELECT delivery_id
+            FROM ` + constants.TableWebhookDeliveries + `
+            WHERE status = $3 AND next_attempt_at <= $2
+            ORDER BY next_attempt_at
+            LIMIT 1
+            FOR UPDATE SKIP LOCKED
+        )
+        RETURNING ` + webhookDeliveryColumns + `
+    `
+	args := []interface{}{leaseUntil, now, constants.WebhookDeliveryPending}
+
+	// Execute the query
+	delivery, err := scanWebhookDelivery(r.db.QueryRowContext(ctx, query, args...))
+
+	// Log the query execution
+	utils.LogDBQuery(
+		query,
+		args,
+		time.Since(startTime),
+		err,
+	)
+
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("failed to claim webhook delivery: %w", err)
+	}
+
+	return delivery, nil
+}
+
+// Finish records the outcome of an attempt.
+func (r *PostgresWebhookDeliveryRepository) Finish(ctx context.Context, delivery *models.WebhookDelivery) error {
+	// Start query timer
+	startTime := time.Now()
+
+	// Define the query
+	query := `
+        UPDATE ` + constants.TableWebhookDeliveries + `
+        SET status = $1,
+            last_status_code = $2,
+            last_error = $3,
+            next_attempt_at = $4,
+            updated_at = $5,
+            delivered_at = $6
+        WHERE delivery_id = $7
+    `
+	args := []interface{}{
+		delivery.Status, delivery.LastStatusCode, delivery.LastError,
+		delivery.NextAttemptAt, delivery.UpdatedAt, delivery.DeliveredAt, delivery.ID,
+	}
+
+	// Execute the query
+	result, err := r.db.ExecContext(ctx, query, args...)
+
+	// Log the query execution
+	utils.LogDBQuery(
+		query,
+		args,
+		time.Since(startTime),
+		err,
+	)
+
+	if err != nil {
+		return fmt.Errorf("failed to finish webhook delivery: %w", err)
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("failed to get affected rows: %w", err)
+	}
+
+	if rowsAffected == 0 {
+		return utils.NewNotFoundError("WebhookDelivery", delivery.ID)
+	}
+
+	return nil
+}
+
+// GetByID retrieves a delivery to a user's webhook.
+func (r *PostgresWebhookDeliveryRepository) GetByID(ctx context.Context, id, webhookID, userID int64) (*models.WebhookDelivery, error) {
+	// Start query timer
+	startTime := time.Now()
+
+	// Define the query
+	query := `
+        SELECT ` + webhookDeliveryColumns + `
+        FROM ` + constants.TableWebhookDeliveries + ` d
+        JOIN ` + constants.TableWebhooks + ` w ON w.webhook_id = d.webhook_id
+        WHERE d.delivery_id = $1 AND d.webhook_id = $2 AND w.` + constants.ColumnUserID + ` = $3
+    `
+
+	// Execute the query
+	delivery, err := scanWebhookDelivery(r.db.QueryRowContext(ctx, query, id, webhookID, userID))
+
+	// Log the query execution
+	utils.LogDBQuery(
+		query,
+		[]interface{}{id, webhookID, userID},
+		time.Since(startTime),
+		err,
+	)
+
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, utils.NewNotFoundError("WebhookDelivery", id).WithCause(err)
+		}
+		return nil, fmt.Errorf("failed to get webhook delivery: %w", err)
+	}
+
+	return delivery, nil
+}
+
+// ListByWebhook retrieves the most recent deliveries to a user's webhook, newest first.
+func (r *PostgresWebhookDeliveryRepository) ListByWebhook(ctx context.Context, webhookID, userID int64, limit int) ([]*models.WebhookDelivery, error) {
+	// Start query timer
+	startTime := time.Now()
+
+	// Define the query
+	query := `
+        SELECT ` + webhookDeliveryColumns + `
+        FROM ` + constants.TableWebhookDeliveries + ` d
+        JOIN ` + constants.TableWebhooks + ` w ON w.webhook_id = d.webhook_id
+        WHERE d.webhook_id = $1 AND w.` + constants.ColumnUserID + ` = $2
+        ORDER BY d.created_at DESC, d.delivery_id DESC
+        LIMIT $3
+    `
+
+	// Execute the query
+	rows, err := r.db.QueryContext(ctx, query, webhookID, userID, limit)
+
+	// Log the query execution
+	utils.LogDBQuery(
+		query,
+		[]interface{}{webhookID, userID, limit},
+		time.Since(startTime),
+		err,
+	)
+
+	if err != nil {
+		return nil, fmt.Errorf("failed to list webhook deliveries: %w", err)
+	}
+	defer rows.Close()
+
+	deliveries := []*models.WebhookDelivery{}
+	for rows.Next() {
+		delivery, err := scanWebhookDelivery(rows)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan webhook delivery row: %w", err)
+		}
+		deliveries = append(deliveries, delivery)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating webhook delivery rows: %w", err)
+	}
+
+	return deliveries, nil
+}
+
+// Redeliver queues a dead-lettered delivery again, with all its attempts.
+func (r *PostgresWebhookDeliveryRepository) Redeliver(ctx context.Context, id int64, now time.Time) error {
+	// Start query timer
+	startTime := time.Now()
+
+	// Define the query
+	query := `
+        UPDATE ` + constants.TableWebhookDeliveries + `
+        SET status = $1,
+            attempts = 0,
+            next_attempt_at = $2,
+            updated_at = $2
+        WHERE delivery_id = $3 AND status = $4
+    `
+	args := []interface{}{constants.WebhookDeliveryPending, now, id, constants.WebhookDeliveryDead}
+
+	// Execute the query
+	result, err := r.db.ExecContext(ctx, query, args...)
+
+	// Log the query execution
+	utils.LogDBQuery(
+		query,
+		args,
+		time.Since(startTime),
+		err,
+	)
+
+	if err != nil {
+		return fmt.Errorf("failed to redeliver webhook delivery: %w", err)
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("failed to get affected rows: %w", err)
+	}
+
+	if rowsAffected == 0 {
+		return utils.NewNotFoundError("WebhookDelivery", id)
+	}
+
+	return nil
+}
+
+// DeleteCreatedBefore deletes the deliveries of events that occurred before a time.
+func (r *PostgresWebhookDeliveryRepository) DeleteCreatedBefore(ctx context.Context, before time.Time) (int64, error) {
+	// Start query timer
+	startTime := time.Now()
+
+	// Define the query
+	query := `
+        DELETE FROM ` + constants.TableWebhookDeliveries + `
+        WHERE created_at < $1
+    `
+
+	// Execute the query
+	result, err := r.db.ExecContext(ctx, query, before)
+
+	// Log the query execution
+	utils.LogDBQuery(
+		query,
+		[]interface{}{before},
+		time.Since(startTime),
+		err,
+	)
+
+	if err != nil {
+		return 0, fmt.Errorf("failed to delete webhook deliveries: %w", err)
+	}
+
+	deleted, err := result.RowsAffected()
+	if err != nil {
+		return 0, fmt.Errorf("failed to get affected rows: %w", err)
+	}
+
+	return deleted, nil
+}
+
+// scanWebhookDelivery reads the webhookDeliveryColumns of a delivery.
+func scanWebhookDelivery(row rowScanner) (*models.WebhookDelivery, error) {
+	delivery := &models.WebhookDelivery{}
+	var payload []byte
+	if err := row.Scan(
+		&delivery.ID, &delivery.WebhookID, &delivery.UserID, &delivery.EventType, &payload, &delivery.Status,
+		&delivery.Attempts, &delivery.NextAttemptAt, &delivery.LastStatusCode, &delivery.LastError,
+		&delivery.CreatedAt, &delivery.UpdatedAt, &delivery.DeliveredAt,
+	); err != nil {
+		return nil, err
+	}
+	delivery.Payload = payload
+	return delivery, nil
+}
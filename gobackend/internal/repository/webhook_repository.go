@@ -0,0 +1,300 @@
+// Package repository provides data access interfaces and implementations for the HideMe application.
+// It follows the repository pattern to abstract database operations and provide a clean API
+// for data persistence operations.
+//
+// This file implements the webhook repository. The secret deliveries are signed with is
+// stored encrypted with the tenant's data-encryption key.
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/lib/pq"
+
+	"github.com/yasinhessnawi1/Hideme_Backend/internal/constants"
+	"github.com/yasinhessnawi1/Hideme_Backend/internal/database"
+	"github.com/yasinhessnawi1/Hideme_Backend/internal/models"
+	"github.com/yasinhessnawi1/Hideme_Backend/internal/utils"
+)
+
+// WebhookRepository defines methods for storing the URLs users receive events at.
+type WebhookRepository interface {
+	// Create stores a new webhook.
+	//
+	// Parameters:
+	//   - ctx: Context for transaction and cancellation control
+	//   - webhook: The webhook to store; its ID and timestamps are set on success
+	//
+	// Returns:
+	//   - An error for database or encryption issues
+	Create(ctx context.Context, webhook *models.Webhook) error
+
+	// GetByID retrieves a webhook of a user, with its secret decrypted.
+	//
+	// Parameters:
+	//   - ctx: Context for transaction and cancellation control
+	//   - id: The ID of the webhook
+	//   - userID: The ID of the user who must own the webhook
+	//
+	// Returns:
+	//   - The webhook
+	//   - NotFoundError if the user has no such webhook
+	//   - Other errors for database or decryption issues
+	GetByID(ctx context.Context, id, userID int64) (*models.Webhook, error)
+
+	// ListByUser retrieves the webhooks of a user, oldest first, without their secrets.
+	//
+	// Parameters:
+	//   - ctx: Context for transaction and cancellation control
+	//   - userID: The ID of the user
+	//
+	// Returns:
+	//   - The webhooks (empty if there are none)
+	//   - An error for database issues
+	ListByUser(ctx context.Context, userID int64) ([]*models.Webhook, error)
+
+	// Delete removes a webhook of a user, with its deliveries.
+	//
+	// Parameters:
+	//   - ctx: Context for transaction and cancellation control
+	//   - id: The ID of the webhook
+	//   - userID: The ID of the user who must own the webhook
+	//
+	// Returns:
+	//   - NotFoundError if the user has no such webhook
+	//   - Other errors for database issues
+	Delete(ctx context.Context, id, userID int64) error
+}
+
+// PostgresWebhookRepository is a PostgreSQL implementation of WebhookRepository.
+type PostgresWebhookRepository struct {
+	db         *database.Pool
+	tenantKeys TenantKeyRepository
+}
+
+// NewWebhookRepository creates a new WebhookRepository implementation for PostgreSQL.
+//
+// Parameters:
+//   - db: A connection pool for PostgreSQL database access
+//   - tenantKeys: Repository for the data-encryption keys the secrets are encrypted with
+//
+// Returns:
+//   - An implementation of the WebhookRepository interface
+func NewWebhookRepository(db *database.Pool, tenantKeys TenantKeyRepository) WebhookRepository {
+	return &PostgresWebhookRepository{
+		db:         db,
+		tenantKeys: tenantKeys,
+	}
+}
+
+// webhookColumns are the columns selected for webhooks, without the secret.
+const webhookColumns = `webhook_id, ` + constants.ColumnUserID + `, url, events, active, created_at, updated_at`
+
+// Create stores a new webhook.
+func (r *PostgresWebhookRepository) Create(ctx context.Context, webhook *models.Webhook) error {
+	secret, err := r.encryptSecret(ctx, webhook.UserID, webhook.Secret)
+	if err != nil {
+		return err
+	}
+
+	// Start query timer
+	startTime := time.Now()
+
+	// Define the query
+	query := `
+        INSERT INTO ` + constants.TableWebhooks + ` (` + constants.ColumnUserID + `, url, secret, events, active, created_at, updated_at)
+        VALUES ($1, $2, $3, $4, $5, $6, $6)
+        RETURNING webhook_id
+    `
+	now := time.Now()
+
+	// Execute the query
+	err = r.db.QueryRowContext(ctx, query, webhook.UserID, webhook.URL, secret, pq.Array(webhook.Events), webhook.Active, now).
+		Scan(&webhook.ID)
+
+	// Log the query execution; the secret is left out
+	utils.LogDBQuery(
+		query,
+		[]interface{}{webhook.UserID, webhook.URL, webhook.Events, webhook.Active},
+		time.Since(startTime),
+		err,
+	)
+
+	if err != nil {
+		return fmt.Errorf("failed to create webhook: %w", err)
+	}
+	webhook.CreatedAt = now
+	webhook.UpdatedAt = now
+
+	return nil
+}
+
+// GetByID retrieves a webhook of a user, with its secret decrypted.
+func (r *PostgresWebhookRepository) GetByID(ctx context.Context, id, userID int64) (*models.Webhook, error) {
+	// Start query timer
+	startTime := time.Now()
+
+	// Define the query
+	query := `
+        SELECT ` + webhookColumns + `, secret
+        FROM ` + constants.TableWebhooks + `
+        WHERE webhook_id = $1 AND ` + constants.ColumnUserID + ` = $2
+    `
+
+	// Execute the query
+	var secret string
+	webhook, err := scanWebhook(r.db.QueryRowContext(ctx, query, id, userID), &secret)
+
+	// Log the query execution
+	utils.LogDBQuery(
+		query,
+		[]interface{}{id, userID},
+		time.Since(startTime),
+		err,
+	)
+
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, utils.NewNotFoundError("Webhook", id).WithCause(err)
+		}
+		return nil, fmt.Errorf("failed to get webhook: %w", err)
+	}
+
+	if webhook.Secret, err = r.decryptSecret(ctx, webhook.UserID, secret); err != nil {
+		return nil, err
+	}
+
+	return webhook, nil
+}
+
+// ListByUser retrieves the webhooks of a user, oldest first, without their secrets.
+func (r *PostgresWebhookRepository) ListByUser(ctx context.Context, userID int64) ([]*models.Webhook, error) {
+	// Start query timer
+	startTime := time.Now()
+
+	// Define the query
+	query := `
+        SELECT ` + webhookColumns + `
+        FROM ` + constants.TableWebhooks + `
+        WHERE ` + constants.ColumnUserID + ` = $1
+        ORDER BY webhook_id
+    `
+
+	// Execute the query
+	rows, err := r.db.QueryContext(ctx, query, userID)
+
+	// Log the query execution
+	utils.LogDBQuery(
+		query,
+		[]interface{}{userID},
+		time.Since(startTime),
+		err,
+	)
+
+	if err != nil {
+		return nil, fmt.Errorf("failed to list webhooks: %w", err)
+	}
+	defer rows.Close()
+
+	webhooks := []*models.Webhook{}
+	for rows.Next() {
+		webhook, err := scanWebhook(rows)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan webhook row: %w", err)
+		}
+		webhooks = append(webhooks, webhook)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating webhook rows: %w", err)
+	}
+
+	return webhooks, nil
+}
+
+// Delete removes a webhook of a user, with its deliveries.
+func (r *PostgresWebhookRepository) Delete(ctx context.Context, id, userID int64) error {
+	// Start query timer
+	startTime := time.Now()
+
+	// Define the query
+	query := `
+        DELETE FROM ` + constants.TableWebhooks + `
+        WHERE webhook_id = $1 AND ` + constants.ColumnUserID + ` = $2
+    `
+
+	// Execute the query
+	result, err := r.db.ExecContext(ctx, query, id, userID)
+
+	// Log the query execution
+	utils.LogDBQuery(
+		query,
+		[]interface{}{id, userID},
+		time.Since(startTime),
+		err,
+	)
+
+	if err != nil {
+		return fmt.Errorf("failed to delete webhook: %w", err)
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("failed to get affected rows: %w", err)
+	}
+
+	if rowsAffected == 0 {
+		return utils.NewNotFoundError("Webhook", id)
+	}
+
+	return nil
+}
+
+// scanWebhook reads the webhookColumns of a webhook, followed by the extra columns given.
+func scanWebhook(row rowScanner, extra ...interface{}) (*models.Webhook, error) {
+	webhook := &models.Webhook{}
+	var events pq.StringArray
+	dest := append([]interface{}{
+		&webhook.ID, &webhook.UserID, &webhook.URL, &events,
+		&webhook.Active, &webhook.CreatedAt, &webhook.UpdatedAt,
+	}, extra...)
+	if err := row.Scan(dest...); err != nil {
+		return nil, err
+	}
+	webhook.Events = []string(events)
+	return webhook, nil
+}
+
+// encryptSecret encrypts the secret of a webhook with the data-encryption key of a tenant.
+func (r *PostgresWebhookRepository) encryptSecret(ctx context.Context, userID int64, secret string) (string, error) {
+	dataKey, err := r.tenantKeys.GetOrCreateDataKey(ctx, userID)
+	if err != nil {
+		return "", fmt.Errorf("failed to get tenant data key: %w", err)
+	}
+
+	ciphertext, err := utils.EncryptKey(secret, dataKey)
+	if err != nil {
+		return "", fmt.Errorf("failed to encrypt webhook secret: %w", err)
+	}
+
+	return constants.TenantCiphertextPrefix + ciphertext, nil
+}
+
+// decryptSecret decrypts the secret of a webhook encrypted with the data-encryption key of a tenant.
+func (r *PostgresWebhookRepository) decryptSecret(ctx context.Context, userID int64, ciphertext string) (string, error) {
+	dataKey, err := r.tenantKeys.GetDataKey(ctx, userID)
+	if err != nil {
+		return "", fmt.Errorf("failed to get tenant data key: %w", err)
+	}
+
+	secret, err := utils.DecryptKey(strings.TrimPrefix(ciphertext, constants.TenantCiphertextPrefix), dataKey)
+	if err != nil {
+		return "", fmt.Errorf("failed to decrypt webhook secret: %w", err)
+	}
+	return secret, nil
+}
@@ -0,0 +1,147 @@
+package repository_test
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/lib/pq"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/yasinhessnawi1/Hideme_Backend/internal/constants"
+	"github.com/yasinhessnawi1/Hideme_Backend/internal/database"
+	"github.com/yasinhessnawi1/Hideme_Backend/internal/models"
+	"github.com/yasinhessnawi1/Hideme_Backend/internal/repository"
+	"github.com/yasinhessnawi1/Hideme_Backend/internal/utils"
+)
+
+var webhookDeliveryTestColumns = []string{
+	"delivery_id", "webhook_id", "user_id", "event_type", "payload", "status", "attempts",
+	"next_attempt_at", "last_status_code", "last_error", "created_at", "updated_at", "delivered_at",
+}
+
+func TestWebhookRepository_CreateAndGet(t *testing.T) {
+	// Arrange
+	db, mock, err := sqlmock.New()
+	require.NoError(t, err)
+	defer db.Close()
+	repo := repository.NewWebhookRepository(&database.Pool{DB: db}, &stubTenantKeyRepository{keys: make(map[int64][]byte)})
+	now := time.Now()
+	webhook := &models.Webhook{
+		UserID: 7,
+		URL:    "https://hooks.example.com",
+		Secret: "0123456789abcdef",
+		Events: []string{constants.WebhookEventDocumentProcessed},
+		Active: true,
+	}
+
+	secret := &capturedArg{}
+	mock.ExpectQuery("INSERT INTO webhooks (.+) RETURNING webhook_id").
+		WithArgs(int64(7), "https://hooks.example.com", secret, sqlmock.AnyArg(), true, sqlmock.AnyArg()).
+		WillReturnRows(sqlmock.NewRows([]string{"webhook_id"}).AddRow(2))
+
+	// Act
+	err = repo.Create(context.Background(), webhook)
+
+	// Assert
+	require.NoError(t, err)
+	assert.Equal(t, int64(2), webhook.ID)
+	assert.True(t, strings.HasPrefix(secret.value.(string), constants.TenantCiphertextPrefix))
+	assert.NotContains(t, secret.value.(string), "0123456789abcdef")
+
+	// GetByID decrypts the secret with the tenant key
+	mock.ExpectQuery("SELECT (.+), secret FROM webhooks WHERE webhook_id = \\$1 AND user_id = \\$2").
+		WithArgs(int64(2), int64(7)).
+		WillReturnRows(sqlmock.NewRows([]string{"webhook_id", "user_id", "url", "events", "active", "created_at", "updated_at", "secret"}).
+			AddRow(2, 7, "https://hooks.example.com", "{document.processed}", true, now, now, secret.value))
+
+	result, err := repo.GetByID(context.Background(), 2, 7)
+	require.NoError(t, err)
+	assert.Equal(t, "0123456789abcdef", result.Secret)
+	assert.Equal(t, []string{constants.WebhookEventDocumentProcessed}, result.Events)
+
+	// Another user's webhook is not found
+	mock.ExpectQuery("SELECT (.+) FROM webhooks").
+		WithArgs(int64(2), int64(8)).
+		WillReturnRows(sqlmock.NewRows([]string{"webhook_id"}))
+
+	_, err = repo.GetByID(context.Background(), 2, 8)
+	assert.True(t, utils.IsNotFoundError(err))
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestWebhookDeliveryRepository_EnqueueAndClaim(t *testing.T) {
+	// Arrange
+	db, mock, err := sqlmock.New()
+	require.NoError(t, err)
+	defer db.Close()
+	repo := repository.NewWebhookDeliveryRepository(&database.Pool{DB: db})
+	now := time.Now()
+
+	mock.ExpectExec("INSERT INTO webhook_deliveries (.+) SELECT (.+) FROM webhooks WHERE user_id = \\$1 AND active AND \\$2 = ANY\\(events\\)").
+		WithArgs(int64(7), constants.WebhookEventDocumentProcessed, `{"document_id":11}`, constants.WebhookDeliveryPending, now).
+		WillReturnResult(sqlmock.NewResult(0, 2))
+
+	mock.ExpectQuery("UPDATE webhook_deliveries d SET attempts = d.attempts \\+ 1, (.+) FOR UPDATE SKIP LOCKED").
+		WithArgs(now.Add(time.Minute), now, constants.WebhookDeliveryPending).
+		WillReturnRows(sqlmock.NewRows(webhookDeliveryTestColumns).
+			AddRow(41, 2, 7, constants.WebhookEventDocumentProcessed, []byte(`{"document_id":11}`), constants.WebhookDeliveryPending,
+				1, now.Add(time.Minute), 0, "", now, now, nil))
+
+	mock.ExpectQuery("UPDATE webhook_deliveries d").
+		WillReturnRows(sqlmock.NewRows(webhookDeliveryTestColumns))
+
+	// Act
+	queued, err := repo.Enqueue(context.Background(), 7, constants.WebhookEventDocumentProcessed, []byte(`{"document_id":11}`), now)
+	require.NoError(t, err)
+	delivery, err := repo.Claim(context.Background(), now, now.Add(time.Minute))
+	require.NoError(t, err)
+	none, err := repo.Claim(context.Background(), now, now.Add(time.Minute))
+
+	// Assert
+	require.NoError(t, err)
+	assert.Equal(t, int64(2), queued)
+	assert.Equal(t, int64(41), delivery.ID)
+	assert.Equal(t, 1, delivery.Attempts)
+	assert.JSONEq(t, `{"document_id":11}`, string(delivery.Payload))
+	assert.Nil(t, none)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestWebhookDeliveryRepository_Redeliver(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	require.NoError(t, err)
+	defer db.Close()
+	repo := repository.NewWebhookDeliveryRepository(&database.Pool{DB: db})
+	now := time.Now()
+
+	t.Run("Dead", func(t *testing.T) {
+		mock.ExpectExec("UPDATE webhook_deliveries SET status = \\$1, attempts = 0, (.+) WHERE delivery_id = \\$3 AND status = \\$4").
+			WithArgs(constants.WebhookDeliveryPending, now, int64(41), constants.WebhookDeliveryDead).
+			WillReturnResult(sqlmock.NewResult(0, 1))
+
+		assert.NoError(t, repo.Redeliver(context.Background(), 41, now))
+	})
+
+	t.Run("Not dead", func(t *testing.T) {
+		mock.ExpectExec("UPDATE webhook_deliveries").
+			WithArgs(constants.WebhookDeliveryPending, now, int64(42), constants.WebhookDeliveryDead).
+			WillReturnResult(sqlmock.NewResult(0, 0))
+
+		assert.True(t, utils.IsNotFoundError(repo.Redeliver(context.Background(), 42, now)))
+	})
+
+	t.Run("Database error", func(t *testing.T) {
+		mock.ExpectExec("UPDATE webhook_deliveries").
+			WillReturnError(&pq.Error{Code: "08006"})
+
+		err := repo.Redeliver(context.Background(), 43, now)
+		assert.Error(t, err)
+		assert.False(t, utils.IsNotFoundError(err))
+	})
+
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
@@ -0,0 +1,231 @@
+// Package server provides the HTTP server implementation for the HideMe API.
+// This file implements the notification hub, which streams events such as job progress
+// and settings changes to the logged-in clients of a user, so they don't have to poll.
+package server
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/lib/pq"
+	"github.com/rs/zerolog/log"
+
+	"github.com/yasinhessnawi1/Hideme_Backend/internal/constants"
+	"github.com/yasinhessnawi1/Hideme_Backend/internal/models"
+	"github.com/yasinhessnawi1/Hideme_Backend/internal/utils"
+	"github.com/yasinhessnawi1/Hideme_Backend/internal/utils/clock"
+)
+
+var (
+	// errTooManyNotificationStreams is returned when a user opens more event streams than allowed
+	errTooManyNotificationStreams = utils.New(utils.ErrBadRequest, constants.StatusTooManyRequests, constants.MsgTooManyNotificationStreams)
+
+	// errNotificationHubClosed is returned when a stream is opened while the server shuts down
+	errNotificationHubClosed = utils.New(utils.ErrInternalServer, constants.StatusServiceUnavailable, constants.MsgServerShuttingDown)
+)
+
+// relayedNotification is a notification relayed between servers, with the user it is for.
+type relayedNotification struct {
+	UserID int64 `json:"user_id"`
+	*models.Notification
+}
+
+// notificationHub keeps the event streams open on this server and delivers notifications
+// to them. Jobs run and settings change on any server, so while the relay runs, notifications
+// are sent through the database (NOTIFY) and every server delivers them to its own streams.
+// Otherwise they reach the streams of this server only.
+type notificationHub struct {
+	db       *sql.DB
+	relaying atomic.Bool
+	clock    clock.Clock
+
+	// mu guards streams and closed
+	mu      sync.Mutex
+	streams map[int64]map[chan *models.Notification]struct{}
+	closed  bool
+}
+
+// newNotificationHub creates a hub without streams.
+//
+// Parameters:
+//   - db: The database relaying the notifications between servers; nil to deliver locally only
+//
+// Returns:
+//   - A new notificationHub
+func newNotificationHub(db *sql.DB) *notificationHub {
+	return &notificationHub{
+		db:      db,
+		streams: make(map[int64]map[chan *models.Notification]struct{}),
+	}
+}
+
+// SetClock sets the clock notifications are stamped with.
+func (h *notificationHub) SetClock(c clock.Clock) {
+	h.clock = c
+}
+
+// now returns the current time of the hub's clock.
+func (h *notificationHub) now() time.Time {
+	return clock.Or(h.clock).Now()
+}
+
+// Subscribe opens an event stream for a user.
+//
+// Parameters:
+//   - userID: The ID of the user
+//
+// Returns:
+//   - The channel receiving the notifications of the user; it is closed when the server
+//     shuts down or the client falls too far behind
+//   - A function closing the stream, to be called once the client left
+//   - errTooManyNotificationStreams if the user has too many streams open, or
+//     errNotificationHubClosed if the server shuts down
+func (h *notificationHub) Subscribe(userID int64) (<-chan *models.Notification, func(), error) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	if h.closed {
+		return nil, nil, errNotificationHubClosed
+	}
+	if len(h.streams[userID]) >= constants.MaxNotificationStreamsPerUser {
+		return nil, nil, errTooManyNotificationStreams
+	}
+
+	stream := make(chan *models.Notification, constants.NotificationStreamBuffer)
+	if h.streams[userID] == nil {
+		h.streams[userID] = make(map[chan *models.Notification]struct{})
+	}
+	h.streams[userID][stream] = struct{}{}
+
+	unsubscribe := func() {
+		h.mu.Lock()
+		defer h.mu.Unlock()
+		h.remove(userID, stream)
+	}
+	return stream, unsubscribe, nil
+}
+
+// Notify sends an event to the streams of a user, on every server while the relay runs.
+// Failures are logged, as notifications must not fail the change they report.
+//
+// Parameters:
+//   - ctx: Context for the operation
+//   - userID: The ID of the user
+//   - eventType: The kind of event, such as constants.NotificationJobUpdated
+//   - data: The payload of the event, encoded as JSON
+func (h *notificationHub) Notify(ctx context.Context, userID int64, eventType string, data interface{}) {
+	payload, err := json.Marshal(data)
+	if err != nil {
+		log.Error().Err(err).Str("type", eventType).Msg("Failed to encode notification")
+		return
+	}
+	notification := &models.Notification{Type: eventType, UserID: userID, Data: payload, CreatedAt: h.now()}
+
+	if h.relaying.Load() {
+		relayed, err := json.Marshal(relayedNotification{UserID: userID, Notification: notification})
+		if err == nil && len(relayed) <= constants.NotificationMaxRelaySize {
+			_, err = h.db.ExecContext(ctx, "SELECT pg_notify($1, $2)", constants.NotificationChannel, string(relayed))
+			if err == nil {
+				return
+			}
+		}
+		if err != nil {
+			log.Warn().Err(err).Int64("user_id", userID).Str("type", eventType).Msg("Failed to relay notification, delivering locally")
+		}
+	}
+	h.deliver(notification)
+}
+
+// relay delivers the notifications relayed through the database until ctx is canceled.
+// While it runs, Notify sends notifications through the database instead of delivering
+// them itself.
+//
+// Parameters:
+//   - ctx: Canceled when the server shuts down
+//   - notifications: The notifications received on constants.NotificationChannel; nil after
+//     the connection was reestablished
+//   - ping: Checks the connection of the listener
+func (h *notificationHub) relay(ctx context.Context, notifications <-chan *pq.Notification, ping func() error) {
+	if h.db == nil {
+		return
+	}
+	h.relaying.Store(true)
+	defer h.relaying.Store(false)
+
+	ticker := time.NewTicker(constants.NotificationListenerPingInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			if err := ping(); err != nil {
+				log.Warn().Err(err).Msg("Notification listener ping failed")
+			}
+		case n, ok := <-notifications:
+			if !ok {
+				return
+			}
+			if n == nil {
+				// Notifications sent while the listener reconnected are lost
+				log.Info().Msg("Notification listener reconnected, notifications may have been missed")
+				continue
+			}
+
+			var relayed relayedNotification
+			if err := json.Unmarshal([]byte(n.Extra), &relayed); err != nil || relayed.Notification == nil {
+				log.Warn().Err(err).Msg("Dropped malformed relayed notification")
+				continue
+			}
+			relayed.Notification.UserID = relayed.UserID
+			h.deliver(relayed.Notification)
+		}
+	}
+}
+
+// deliver sends a notification to the streams of its user on this server. A stream whose
+// buffer is full is closed rather than blocking the others; its client reconnects.
+func (h *notificationHub) deliver(notification *models.Notification) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	for stream := range h.streams[notification.UserID] {
+		select {
+		case stream <- notification:
+		default:
+			log.Warn().Int64("user_id", notification.UserID).Msg("Closed event stream of a client that fell behind")
+			h.remove(notification.UserID, stream)
+		}
+	}
+}
+
+// remove closes a stream and forgets it. The caller must hold mu.
+func (h *notificationHub) remove(userID int64, stream chan *models.Notification) {
+	if _, ok := h.streams[userID][stream]; !ok {
+		return
+	}
+	delete(h.streams[userID], stream)
+	if len(h.streams[userID]) == 0 {
+		delete(h.streams, userID)
+	}
+	close(stream)
+}
+
+// close closes all streams and refuses new ones, so the server can shut down without
+// waiting for clients that would never leave.
+func (h *notificationHub) close() {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	h.closed = true
+	for userID, streams := range h.streams {
+		for stream := range streams {
+			h.remove(userID, stream)
+		}
+	}
+}
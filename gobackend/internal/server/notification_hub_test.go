@@ -0,0 +1,147 @@
+package server
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/lib/pq"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/yasinhessnawi1/Hideme_Backend/internal/constants"
+	"github.com/yasinhessnawi1/Hideme_Backend/internal/models"
+)
+
+func TestNotificationHub_Deliver(t *testing.T) {
+	hub := newNotificationHub(nil)
+
+	first, unsubscribeFirst, err := hub.Subscribe(7)
+	require.NoError(t, err)
+	second, _, err := hub.Subscribe(7)
+	require.NoError(t, err)
+	other, _, err := hub.Subscribe(8)
+	require.NoError(t, err)
+
+	hub.Notify(context.Background(), 7, constants.NotificationJobUpdated, map[string]string{"status": "running"})
+
+	for _, stream := range []<-chan *models.Notification{first, second} {
+		select {
+		case n := <-stream:
+			assert.Equal(t, constants.NotificationJobUpdated, n.Type)
+			assert.JSONEq(t, `{"status":"running"}`, string(n.Data))
+		default:
+			t.Fatal("notification not delivered")
+		}
+	}
+	assert.Empty(t, other)
+
+	// A closed stream is not delivered to anymore
+	unsubscribeFirst()
+	_, open := <-first
+	assert.False(t, open)
+	hub.Notify(context.Background(), 7, constants.NotificationJobUpdated, nil)
+	assert.Len(t, second, 1)
+}
+
+func TestNotificationHub_Limits(t *testing.T) {
+	t.Run("Streams per user", func(t *testing.T) {
+		hub := newNotificationHub(nil)
+		for i := 0; i < constants.MaxNotificationStreamsPerUser; i++ {
+			_, _, err := hub.Subscribe(7)
+			require.NoError(t, err)
+		}
+
+		_, _, err := hub.Subscribe(7)
+		assert.ErrorIs(t, err, errTooManyNotificationStreams)
+
+		// Other users are not affected
+		_, _, err = hub.Subscribe(8)
+		assert.NoError(t, err)
+	})
+
+	t.Run("Client falling behind", func(t *testing.T) {
+		hub := newNotificationHub(nil)
+		stream, unsubscribe, err := hub.Subscribe(7)
+		require.NoError(t, err)
+
+		for i := 0; i <= constants.NotificationStreamBuffer; i++ {
+			hub.Notify(context.Background(), 7, constants.NotificationJobUpdated, i)
+		}
+
+		// The buffered notifications are still read, then the stream ends
+		received := 0
+		for range stream {
+			received++
+		}
+		assert.Equal(t, constants.NotificationStreamBuffer, received)
+
+		// Closing it again is harmless
+		unsubscribe()
+	})
+}
+
+func TestNotificationHub_Close(t *testing.T) {
+	hub := newNotificationHub(nil)
+	stream, unsubscribe, err := hub.Subscribe(7)
+	require.NoError(t, err)
+
+	hub.close()
+
+	_, open := <-stream
+	assert.False(t, open)
+	unsubscribe()
+
+	_, _, err = hub.Subscribe(7)
+	assert.ErrorIs(t, err, errNotificationHubClosed)
+}
+
+func TestNotificationHub_Relay(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	require.NoError(t, err)
+	defer db.Close()
+
+	hub := newNotificationHub(db)
+	stream, _, err := hub.Subscribe(7)
+	require.NoError(t, err)
+
+	notifications := make(chan *pq.Notification)
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan struct{})
+	go func() {
+		hub.relay(ctx, notifications, func() error { return nil })
+		close(done)
+	}()
+	require.Eventually(t, hub.relaying.Load, time.Second, time.Millisecond)
+
+	// Notifications are sent through the database rather than delivered directly
+	mock.ExpectExec("SELECT pg_notify").
+		WithArgs(constants.NotificationChannel, sqlmock.AnyArg()).
+		WillReturnResult(sqlmock.NewResult(0, 0))
+	hub.Notify(context.Background(), 7, constants.NotificationSettingsChanged, models.SettingsChangedNotification{LatestRevision: 12})
+	assert.NoError(t, mock.ExpectationsWereMet())
+	assert.Empty(t, stream)
+
+	// Notifications relayed by any server are delivered to the streams of this one
+	relayed, err := json.Marshal(relayedNotification{
+		UserID:       7,
+		Notification: &models.Notification{Type: constants.NotificationSettingsChanged, Data: json.RawMessage(`{"latest_revision":12}`)},
+	})
+	require.NoError(t, err)
+	notifications <- nil
+	notifications <- &pq.Notification{Channel: constants.NotificationChannel, Extra: "not json"}
+	notifications <- &pq.Notification{Channel: constants.NotificationChannel, Extra: string(relayed)}
+
+	n := <-stream
+	assert.Equal(t, int64(7), n.UserID)
+	assert.Equal(t, constants.NotificationSettingsChanged, n.Type)
+	assert.JSONEq(t, `{"latest_revision":12}`, string(n.Data))
+
+	// Without the relay, notifications are delivered directly
+	cancel()
+	<-done
+	hub.Notify(context.Background(), 7, constants.NotificationJobUpdated, nil)
+	assert.Len(t, stream, 1)
+}
@@ -0,0 +1,160 @@
+// Package server provides the HTTP server implementation for the HideMe API.
+// This file implements the liveness, readiness and startup probes used by Kubernetes,
+// container health checks and systemd.
+package server
+
+import (
+	"context"
+	"net/http"
+	"strconv"
+	"sync/atomic"
+
+	"github.com/rs/zerolog/log"
+
+	"github.com/yasinhessnawi1/Hideme_Backend/internal/constants"
+	"github.com/yasinhessnawi1/Hideme_Backend/internal/utils"
+)
+
+// readinessCheck is a dependency the server needs to serve requests.
+type readinessCheck struct {
+	name  string
+	check func(ctx context.Context) error
+}
+
+// probeHandler is the handler of the HTTP server. The probes are answered from the moment
+// the server listens, before the database is reachable and while migrations run, so startup
+// probes see a starting server rather than a refused connection. Other requests are answered
+// with 503 until the router is set, and then passed to it. The probes never pass through the
+// router, so rate limiting, load shedding and bans don't apply to them.
+type probeHandler struct {
+	phase  atomic.Value
+	router atomic.Value
+	checks []readinessCheck
+}
+
+// newProbeHandler creates a probe handler in the starting phase, without router.
+func newProbeHandler() *probeHandler {
+	h := &probeHandler{}
+	h.phase.Store(constants.PhaseStarting)
+	return h
+}
+
+// setPhase records how far the server got. It does nothing on a nil handler, as servers
+// created without NewServer don't answer probes.
+func (h *probeHandler) setPhase(phase string) {
+	if h == nil {
+		return
+	}
+	h.phase.Store(phase)
+}
+
+// currentPhase returns how far the server got.
+func (h *probeHandler) currentPhase() string {
+	return h.phase.Load().(string)
+}
+
+// serve sets the router and the dependencies checked by the readiness probe, and marks the
+// server ready.
+//
+// Parameters:
+//   - router: The handler serving the API
+//   - checks: The dependencies checked by the readiness probe
+func (h *probeHandler) serve(router http.Handler, checks []readinessCheck) {
+	// The checks are set before the phase, which publishes them to the probes
+	h.checks = checks
+	h.router.Store(router)
+	h.setPhase(constants.PhaseReady)
+}
+
+// ServeHTTP answers the probes and passes other requests to the router once it is set.
+func (h *probeHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if r.Method == http.MethodGet || r.Method == http.MethodHead {
+		switch r.URL.Path {
+		case constants.LivenessPath:
+			h.live(w)
+			return
+		case constants.ReadinessPath:
+			h.ready(w, r)
+			return
+		case constants.StartupPath:
+			h.started(w)
+			return
+		}
+	}
+
+	if router, ok := h.router.Load().(http.Handler); ok {
+		router.ServeHTTP(w, r)
+		return
+	}
+
+	w.Header().Set(constants.HeaderRetryAfter, strconv.Itoa(int(constants.StartupRetryAfter.Seconds())))
+	utils.Error(w, constants.StatusServiceUnavailable, "service_unavailable", "Service is starting", map[string]string{
+		"phase": h.currentPhase(),
+	})
+}
+
+// live answers the liveness probe: the process serves requests, whatever its phase.
+// Dependencies are not checked, so an unreachable database does not get the server restarted.
+func (h *probeHandler) live(w http.ResponseWriter) {
+	utils.JSON(w, constants.StatusOK, map[string]string{
+		"status": "alive",
+		"phase":  h.currentPhase(),
+	})
+}
+
+// started answers the startup probe: startup, including the migrations, has finished.
+func (h *probeHandler) started(w http.ResponseWriter) {
+	phase := h.currentPhase()
+	if phase == constants.PhaseStarting || phase == constants.PhaseMigrating {
+		utils.Error(w, constants.StatusServiceUnavailable, "service_unavailable", "Service is starting", map[string]string{
+			"phase": phase,
+		})
+		return
+	}
+
+	utils.JSON(w, constants.StatusOK, map[string]string{
+		"status": "started",
+		"phase":  phase,
+	})
+}
+
+// ready answers the readiness probe: the server serves the API and every dependency is
+// reachable. Failing dependencies are listed by name.
+func (h *probeHandler) ready(w http.ResponseWriter, r *http.Request) {
+	phase := h.currentPhase()
+	if phase != constants.PhaseReady {
+		utils.Error(w, constants.StatusServiceUnavailable, "service_unavailable", "Service is not ready", map[string]string{
+			"phase": phase,
+		})
+		return
+	}
+
+	if failures := h.check(r.Context()); len(failures) > 0 {
+		failures["phase"] = phase
+		utils.Error(w, constants.StatusServiceUnavailable, "service_unavailable", "Service is not ready", failures)
+		return
+	}
+
+	utils.JSON(w, constants.StatusOK, map[string]string{
+		"status": "ready",
+		"phase":  phase,
+	})
+}
+
+// check runs the readiness checks, each within constants.ProbeCheckTimeout.
+//
+// Returns:
+//   - The failed checks with their error, keyed by name; empty if all passed
+func (h *probeHandler) check(ctx context.Context) map[string]string {
+	failures := make(map[string]string)
+	for _, c := range h.checks {
+		checkCtx, cancel := context.WithTimeout(ctx, constants.ProbeCheckTimeout)
+		err := c.check(checkCtx)
+		cancel()
+		if err != nil {
+			log.Warn().Err(err).Str("dependency", c.name).Msg("Readiness check failed")
+			failures[c.name] = "unreachable"
+		}
+	}
+	return failures
+}
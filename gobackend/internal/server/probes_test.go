@@ -0,0 +1,122 @@
+package server
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/yasinhessnawi1/Hideme_Backend/internal/constants"
+)
+
+func probe(h http.Handler, method, path string) *httptest.ResponseRecorder {
+	rr := httptest.NewRecorder()
+	h.ServeHTTP(rr, httptest.NewRequest(method, path, nil))
+	return rr
+}
+
+func TestProbeHandler_Phases(t *testing.T) {
+	router := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusTeapot)
+	})
+
+	tests := []struct {
+		name    string
+		prepare func(h *probeHandler)
+		live    int
+		started int
+		ready   int
+		other   int
+	}{
+		{
+			name:    "Starting",
+			prepare: func(h *probeHandler) {},
+			live:    http.StatusOK,
+			started: http.StatusServiceUnavailable,
+			ready:   http.StatusServiceUnavailable,
+			other:   http.StatusServiceUnavailable,
+		},
+		{
+			name:    "Migrating",
+			prepare: func(h *probeHandler) { h.setPhase(constants.PhaseMigrating) },
+			live:    http.StatusOK,
+			started: http.StatusServiceUnavailable,
+			ready:   http.StatusServiceUnavailable,
+			other:   http.StatusServiceUnavailable,
+		},
+		{
+			name:    "Ready",
+			prepare: func(h *probeHandler) { h.serve(router, nil) },
+			live:    http.StatusOK,
+			started: http.StatusOK,
+			ready:   http.StatusOK,
+			other:   http.StatusTeapot,
+		},
+		{
+			name: "Stopping",
+			prepare: func(h *probeHandler) {
+				h.serve(router, nil)
+				h.setPhase(constants.PhaseStopping)
+			},
+			live:    http.StatusOK,
+			started: http.StatusOK,
+			ready:   http.StatusServiceUnavailable,
+			other:   http.StatusTeapot,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			h := newProbeHandler()
+			tt.prepare(h)
+
+			assert.Equal(t, tt.live, probe(h, http.MethodGet, constants.LivenessPath).Code)
+			assert.Equal(t, tt.started, probe(h, http.MethodGet, constants.StartupPath).Code)
+			assert.Equal(t, tt.ready, probe(h, http.MethodGet, constants.ReadinessPath).Code)
+			assert.Equal(t, tt.other, probe(h, http.MethodGet, "/api/documents").Code)
+		})
+	}
+}
+
+func TestProbeHandler_RetryAfterWhileStarting(t *testing.T) {
+	h := newProbeHandler()
+
+	rr := probe(h, http.MethodPost, "/api/auth/login")
+	assert.Equal(t, http.StatusServiceUnavailable, rr.Code)
+	assert.Equal(t, "5", rr.Header().Get(constants.HeaderRetryAfter))
+}
+
+func TestProbeHandler_ReadinessChecks(t *testing.T) {
+	h := newProbeHandler()
+	h.serve(http.NotFoundHandler(), []readinessCheck{
+		{name: "database", check: func(ctx context.Context) error { return nil }},
+		{name: "redis", check: func(ctx context.Context) error { return errors.New("connection refused") }},
+	})
+
+	rr := probe(h, http.MethodGet, constants.ReadinessPath)
+	assert.Equal(t, http.StatusServiceUnavailable, rr.Code)
+	assert.Contains(t, rr.Body.String(), `"redis":"unreachable"`)
+	assert.NotContains(t, rr.Body.String(), `"database"`)
+	assert.NotContains(t, rr.Body.String(), "connection refused")
+
+	// An unreachable dependency does not fail the liveness probe
+	assert.Equal(t, http.StatusOK, probe(h, http.MethodGet, constants.LivenessPath).Code)
+}
+
+func TestProbeHandler_BypassesRouter(t *testing.T) {
+	routed := false
+	h := newProbeHandler()
+	h.serve(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		routed = true
+	}), nil)
+
+	assert.Equal(t, http.StatusOK, probe(h, http.MethodHead, constants.LivenessPath).Code)
+	assert.False(t, routed)
+
+	// Other methods on the probe paths go to the router
+	probe(h, http.MethodPost, constants.LivenessPath)
+	assert.True(t, routed)
+}
@@ -0,0 +1,267 @@
+// Package server provides HTTP server implementation for the HideMe application.
+// This file implements the route registry: API routes are declared as data together with
+// the cross-cutting behavior they need, and the middleware for that behavior is derived
+// from the declaration instead of being configured by hand for each route. The route
+// documentation served at /api/routes reports the same declarations.
+package server
+
+import (
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+
+	"github.com/yasinhessnawi1/Hideme_Backend/internal/constants"
+	"github.com/yasinhessnawi1/Hideme_Backend/internal/middleware"
+)
+
+// route declares an API route. Empty fields take the defaults of the route's group.
+type route struct {
+	// Method is the HTTP method of the route
+	Method string
+
+	// Pattern is the chi pattern of the route, relative to the prefix of its group
+	Pattern string
+
+	// Handler handles the requests to the route
+	Handler http.HandlerFunc
+
+	// Scopes are the API key scopes the route requires
+	Scopes routeScopes
+
+	// RateLimit is the rate limit group requests to the route count against, such as
+	// constants.RateLimitGroupDocuments; requests always count against the default group
+	RateLimit string
+
+	// LoadShed is the load shedding group of the route
+	LoadShed loadShedClass
+
+	// Timeout is the timeout class of the route, such as constants.RouteTimeoutLong;
+	// empty for constants.RouteTimeoutStandard
+	Timeout string
+
+	// Audit is the log category every request to the route is logged in; empty if requests
+	// are not audited
+	Audit string
+
+	// Deprecation marks the route as deprecated; nil for current routes
+	Deprecation *routeDeprecation
+
+	// Middleware is applied after the middleware derived from the fields above, for behavior
+	// specific to the route such as reporting the detection quota
+	Middleware []func(http.Handler) http.Handler
+}
+
+// routeScopes are the API key scopes a route requires. Requests authenticated otherwise
+// are not limited by scopes.
+type routeScopes struct {
+	// Read is the scope required for GET, HEAD and OPTIONS requests
+	Read string
+
+	// Write is the scope required for all other requests
+	Write string
+}
+
+// loadShedClass is the load shedding group of a route and the priority it has unless
+// configured otherwise.
+type loadShedClass struct {
+	// Group names the load shedding group in the configuration
+	Group string
+
+	// Priority is the default priority of the group, such as constants.LoadShedPriorityLow
+	Priority string
+}
+
+// routeDeprecation describes when a deprecated route goes away and what replaces it.
+type routeDeprecation struct {
+	// Sunset is the date after which the route may be removed; zero if not decided
+	Sunset time.Time
+
+	// Successor is the path of the route replacing it; empty if there is none
+	Successor string
+}
+
+// routeGroup declares routes sharing a prefix, authentication and defaults.
+type routeGroup struct {
+	// Prefix is the path of the group below /api; empty for routes directly below /api.
+	// Groups with the same prefix are mounted on the same subrouter.
+	Prefix string
+
+	// Middleware authenticates the requests to the group's routes. It runs after load
+	// shedding, so shed requests don't reach the database to be authenticated.
+	Middleware []func(http.Handler) http.Handler
+
+	// Defaults are the fields of the group's routes left empty. The load shedding group
+	// of the defaults is applied to the whole group, and its Middleware runs before the
+	// middleware of each route.
+	Defaults route
+
+	// Routes are the routes of the group
+	Routes []route
+}
+
+// routeRegistry mounts declared routes with the middleware their declarations call for,
+// and keeps the declarations for the route documentation.
+type routeRegistry struct {
+	security    middleware.SecurityService
+	loadShedder *middleware.LoadShedder
+	metrics     *middleware.RequestMetrics
+
+	// routes are the mounted routes with their defaults applied and full paths
+	routes []route
+}
+
+// newRouteRegistry creates a new, empty routeRegistry.
+//
+// Parameters:
+//   - security: The security service rate limiting the routes
+//   - loadShedder: The load shedder of the routes
+//   - metrics: The request metrics the SLOs are evaluated on; nil records nothing
+//
+// Returns:
+//   - An empty route registry
+func newRouteRegistry(security middleware.SecurityService, loadShedder *middleware.LoadShedder, metrics *middleware.RequestMetrics) *routeRegistry {
+	return &routeRegistry{
+		security:    security,
+		loadShedder: loadShedder,
+		metrics:     metrics,
+	}
+}
+
+// mount registers the routes of the groups on a router, in order.
+//
+// Parameters:
+//   - r: The router serving /api
+//   - basePath: The path of the router, for the documented paths of the routes
+//   - groups: The route groups
+func (reg *routeRegistry) mount(r chi.Router, basePath string, groups []routeGroup) {
+	var prefixes []string
+	byPrefix := make(map[string][]routeGroup)
+	for _, group := range groups {
+		if _, ok := byPrefix[group.Prefix]; !ok {
+			prefixes = append(prefixes, group.Prefix)
+		}
+		byPrefix[group.Prefix] = append(byPrefix[group.Prefix], group)
+	}
+
+	for _, prefix := range prefixes {
+		groups := byPrefix[prefix]
+		mountGroups := func(r chi.Router) {
+			for _, group := range groups {
+				r.Group(func(r chi.Router) {
+					reg.mountGroup(r, basePath+prefix, group)
+				})
+			}
+		}
+		if prefix == "" {
+			mountGroups(r)
+		} else {
+			r.Route(prefix, mountGroups)
+		}
+	}
+}
+
+// mountGroup registers the routes of a group on a router.
+func (reg *routeRegistry) mountGroup(r chi.Router, path string, group routeGroup) {
+	if group.Defaults.LoadShed.Group != "" {
+		r.Use(reg.loadShedder.Shed(group.Defaults.LoadShed.Group, group.Defaults.LoadShed.Priority))
+	}
+	r.Use(group.Middleware...)
+
+	for _, rt := range group.Routes {
+		rt = group.resolve(rt)
+		r.With(reg.middleware(rt, group.Defaults.LoadShed)...).Method(rt.Method, rt.Pattern, rt.Handler)
+
+		rt.Pattern = path + strings.TrimSuffix(rt.Pattern, "/")
+		reg.routes = append(reg.routes, rt)
+	}
+}
+
+// middleware returns the middleware a route's declaration calls for, in the order it runs.
+func (reg *routeRegistry) middleware(rt route, groupLoadShed loadShedClass) []func(http.Handler) http.Handler {
+	// Only routes finishing within the server's write timeout count towards the latency SLO
+	chain := []func(http.Handler) http.Handler{reg.metrics.Observe(rt.Timeout == constants.RouteTimeoutStandard)}
+	if rt.LoadShed != groupLoadShed {
+		chain = append(chain, reg.loadShedder.Shed(rt.LoadShed.Group, rt.LoadShed.Priority))
+	}
+	if rt.Deprecation != nil {
+		chain = append(chain, middleware.Deprecated(rt.Deprecation.Sunset, rt.Deprecation.Successor))
+	}
+	if rt.Scopes != (routeScopes{}) {
+		chain = append(chain, middleware.RequireReadWriteScope(rt.Scopes.Read, rt.Scopes.Write))
+	}
+	if rt.RateLimit != "" {
+		chain = append(chain, middleware.RateLimit(reg.security, rt.RateLimit))
+	}
+	if rt.Timeout == constants.RouteTimeoutLong {
+		chain = append(chain, middleware.WriteTimeout(constants.RouteLongWriteTimeout))
+	}
+	if rt.Audit != "" {
+		chain = append(chain, middleware.Audit(rt.Audit))
+	}
+	return append(chain, rt.Middleware...)
+}
+
+// resolve fills the empty fields of a route with the defaults of the group.
+func (g routeGroup) resolve(rt route) route {
+	defaults := g.Defaults
+	if rt.Scopes == (routeScopes{}) {
+		rt.Scopes = defaults.Scopes
+	}
+	if rt.RateLimit == "" {
+		rt.RateLimit = defaults.RateLimit
+	}
+	if rt.LoadShed == (loadShedClass{}) {
+		rt.LoadShed = defaults.LoadShed
+	}
+	if rt.Timeout == "" {
+		rt.Timeout = defaults.Timeout
+	}
+	if rt.Timeout == "" {
+		rt.Timeout = constants.RouteTimeoutStandard
+	}
+	if rt.Audit == "" {
+		rt.Audit = defaults.Audit
+	}
+	if rt.Deprecation == nil {
+		rt.Deprecation = defaults.Deprecation
+	}
+	rt.Middleware = append(append([]func(http.Handler) http.Handler{}, defaults.Middleware...), rt.Middleware...)
+	return rt
+}
+
+// documentation returns the declared behavior of a route for the route documentation.
+func (rt route) documentation() map[string]interface{} {
+	doc := map[string]interface{}{
+		"timeout": rt.Timeout,
+	}
+	if rt.Scopes != (routeScopes{}) {
+		doc["scopes"] = map[string]string{"read": rt.Scopes.Read, "write": rt.Scopes.Write}
+	}
+	if rt.RateLimit != "" {
+		doc["rate_limit"] = rt.RateLimit
+	}
+	if rt.LoadShed.Group != "" {
+		doc["load_shedding"] = rt.LoadShed.Group
+	}
+	if rt.Audit != "" {
+		doc["audit"] = rt.Audit
+	}
+	if rt.Deprecation != nil {
+		deprecation := map[string]interface{}{}
+		if !rt.Deprecation.Sunset.IsZero() {
+			deprecation["sunset"] = rt.Deprecation.Sunset.UTC().Format(time.RFC3339)
+		}
+		if rt.Deprecation.Successor != "" {
+			deprecation["successor"] = rt.Deprecation.Successor
+		}
+		doc["deprecated"] = deprecation
+	}
+	return doc
+}
+
+// middlewares lists the middleware of a route declaration.
+func middlewares(m ...func(http.Handler) http.Handler) []func(http.Handler) http.Handler {
+	return m
+}
@@ -0,0 +1,146 @@
+package server
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/yasinhessnawi1/Hideme_Backend/internal/auth"
+	"github.com/yasinhessnawi1/Hideme_Backend/internal/config"
+	"github.com/yasinhessnawi1/Hideme_Backend/internal/constants"
+	"github.com/yasinhessnawi1/Hideme_Backend/internal/middleware"
+	"github.com/yasinhessnawi1/Hideme_Backend/internal/models"
+)
+
+// limitedSecurityService rate limits every request in its limited categories.
+type limitedSecurityService struct {
+	limited map[string]bool
+}
+
+func (s *limitedSecurityService) IsBanned(string) bool { return false }
+
+func (s *limitedSecurityService) IsRateLimited(_ string, category string) bool {
+	return s.limited[category]
+}
+
+func (s *limitedSecurityService) BanIP(context.Context, string, string, time.Duration, string) (*models.IPBan, error) {
+	return nil, nil
+}
+
+func TestRouteRegistry(t *testing.T) {
+	ok := func(w http.ResponseWriter, r *http.Request) { w.WriteHeader(http.StatusOK) }
+	sunset := time.Date(2027, time.March, 1, 0, 0, 0, 0, time.UTC)
+
+	security := &limitedSecurityService{limited: map[string]bool{"limited": true}}
+	registry := newRouteRegistry(security, middleware.NewLoadShedder(nil, &config.LoadSheddingSettings{}), nil)
+	r := chi.NewRouter()
+	r.Route("/api", func(r chi.Router) {
+		registry.mount(r, "/api", []routeGroup{
+			{
+				Routes: []route{
+					{Method: http.MethodGet, Pattern: "/status", Handler: ok},
+				},
+			},
+			{
+				Prefix: "/things",
+				Defaults: route{
+					Scopes:    routeScopes{"things:read", "things:write"},
+					RateLimit: "things",
+					LoadShed:  loadShedClass{"things", constants.LoadShedPriorityNormal},
+					Audit:     constants.LogCategoryAdmin,
+				},
+				Routes: []route{
+					{Method: http.MethodGet, Pattern: "/", Handler: ok},
+					{Method: http.MethodPost, Pattern: "/", Handler: ok},
+					{Method: http.MethodGet, Pattern: "/limited", Handler: ok, RateLimit: "limited"},
+					{Method: http.MethodGet, Pattern: "/old", Handler: ok, Deprecation: &routeDeprecation{Sunset: sunset, Successor: "/api/things"}},
+					{Method: http.MethodGet, Pattern: "/{id}/export", Handler: ok, Timeout: constants.RouteTimeoutLong, LoadShed: loadShedClass{"exports", constants.LoadShedPriorityLow}},
+				},
+			},
+			{
+				Prefix: "/things",
+				Middleware: middlewares(func(next http.Handler) http.Handler {
+					return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+						w.Header().Set("X-Group", "second")
+						next.ServeHTTP(w, r)
+					})
+				}),
+				Routes: []route{
+					{Method: http.MethodDelete, Pattern: "/{id}", Handler: ok},
+				},
+			},
+		})
+	})
+
+	serve := func(method, path string, scopes ...string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(method, path, nil)
+		if scopes != nil {
+			req = req.WithContext(context.WithValue(req.Context(), auth.APIKeyScopesContextKey, scopes))
+		}
+		rr := httptest.NewRecorder()
+		r.ServeHTTP(rr, req)
+		return rr
+	}
+
+	t.Run("Routes", func(t *testing.T) {
+		assert.Equal(t, http.StatusOK, serve(http.MethodGet, "/api/status").Code)
+		assert.Equal(t, http.StatusOK, serve(http.MethodGet, "/api/things").Code)
+		assert.Equal(t, http.StatusOK, serve(http.MethodGet, "/api/things/7/export").Code)
+		assert.Equal(t, http.StatusNotFound, serve(http.MethodGet, "/api/missing").Code)
+	})
+
+	t.Run("Scopes", func(t *testing.T) {
+		assert.Equal(t, http.StatusOK, serve(http.MethodGet, "/api/things", "things:read").Code)
+		assert.Equal(t, http.StatusForbidden, serve(http.MethodPost, "/api/things", "things:read").Code)
+		assert.Equal(t, http.StatusOK, serve(http.MethodPost, "/api/things", "things:write").Code)
+	})
+
+	t.Run("Rate limit", func(t *testing.T) {
+		assert.Equal(t, http.StatusTooManyRequests, serve(http.MethodGet, "/api/things/limited").Code)
+	})
+
+	t.Run("Deprecation", func(t *testing.T) {
+		rr := serve(http.MethodGet, "/api/things/old")
+		assert.Equal(t, "true", rr.Header().Get(constants.HeaderDeprecation))
+		assert.Equal(t, "Mon, 01 Mar 2027 00:00:00 GMT", rr.Header().Get(constants.HeaderSunset))
+		assert.Empty(t, serve(http.MethodGet, "/api/things").Header().Get(constants.HeaderDeprecation))
+	})
+
+	t.Run("Groups sharing a prefix", func(t *testing.T) {
+		rr := serve(http.MethodDelete, "/api/things/7")
+		assert.Equal(t, http.StatusOK, rr.Code)
+		assert.Equal(t, "second", rr.Header().Get("X-Group"))
+		assert.Empty(t, serve(http.MethodGet, "/api/things").Header().Get("X-Group"))
+	})
+
+	t.Run("Declarations", func(t *testing.T) {
+		require.Len(t, registry.routes, 7)
+		byKey := make(map[string]route)
+		for _, rt := range registry.routes {
+			byKey[rt.Method+" "+rt.Pattern] = rt
+		}
+
+		list := byKey["GET /api/things"]
+		assert.Equal(t, routeScopes{"things:read", "things:write"}, list.Scopes)
+		assert.Equal(t, constants.RouteTimeoutStandard, list.Timeout)
+		assert.Equal(t, constants.LogCategoryAdmin, list.Audit)
+
+		export := byKey["GET /api/things/{id}/export"]
+		assert.Equal(t, constants.RouteTimeoutLong, export.Timeout)
+		assert.Equal(t, "exports", export.LoadShed.Group)
+		assert.Equal(t, "things", export.RateLimit)
+
+		doc := byKey["GET /api/things/old"].documentation()
+		assert.Equal(t, map[string]interface{}{"sunset": "2027-03-01T00:00:00Z", "successor": "/api/things"}, doc["deprecated"])
+		assert.Equal(t, "things", doc["rate_limit"])
+
+		// Routes of a group without defaults declare nothing but their timeout
+		assert.Equal(t, map[string]interface{}{"timeout": constants.RouteTimeoutStandard}, byKey["DELETE /api/things/{id}"].documentation())
+	})
+}
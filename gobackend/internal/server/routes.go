@@ -10,6 +10,7 @@ package server
 import (
 	"net/http"
 	"os"
+	"strconv"
 	"strings"
 	"time"
 
@@ -40,16 +41,34 @@ import (
 // - Settings management (preferences, ban lists, patterns, entities)
 // - Generic database operations (admin/dev access only)
 //
-// Route protection is handled through middleware for authenticated endpoints.
+// Route protection is handled through middleware for authenticated endpoints. The API
+// routes are declared in a route registry together with their API key scopes, rate limit
+// group, load shedding group, timeout class and audit category, from which the middleware
+// of each route is derived.
 func (s *Server) SetupRoutes() {
 	// Create security service for rate limiting and IP banning
 	securityService := service.NewSecurityService(
 		repository.NewIPBanRepository(s.Db),
 		1*time.Minute, // Cache refresh interval
 	)
+	securityService.SetRateLimits(&s.Config.Security.RateLimiting)
 
 	// Create security handlers
 	securityHandler := handlers.NewSecurityHandler(securityService)
+	rateLimitHandler := handlers.NewRateLimitHandler(securityService)
+
+	// Shed low-priority requests while the database is slow or failing
+	var dbHealth middleware.DBHealthSource
+	if s.Db != nil && s.Db.Monitor != nil {
+		dbHealth = s.Db.Monitor
+	}
+	loadShedder := middleware.NewLoadShedder(dbHealth, &s.Config.LoadShedding)
+
+	// Mirror a share of the read-only traffic to a shadow deployment, if one is configured
+	shadowMirror := middleware.NewShadowMirror(&s.Config.ShadowTraffic)
+	if shadowMirror != nil {
+		s.Handlers.DiagnosticsHandler.SetShadowTraffic(shadowMirror)
+	}
 
 	// Create router
 	r := chi.NewRouter()
@@ -57,11 +76,18 @@ func (s *Server) SetupRoutes() {
 	// Get allowed origins from environment or use default values
 	allowedOrigins := getAllowedOrigins()
 
+	// Only the reverse proxies in front of the server may report the client address
+	trustedProxies, err := utils.ParseTrustedProxies(s.Config.Server.TrustedProxies)
+	if err != nil {
+		log.Error().Err(err).Msg("Ignoring trusted proxies; client addresses are read from connections")
+		trustedProxies = nil
+	}
+
 	// Base middleware
 	r.Use(chimiddleware.RequestID)
 	r.Use(middleware.Recovery())
-	r.Use(chimiddleware.RealIP)
-	r.Use(middleware.SecurityHeaders())
+	r.Use(middleware.ClientIP(trustedProxies))
+	r.Use(middleware.SecurityHeaders(&s.Config.SecurityHeaders))
 	// Custom CORS middleware that applies to all routes
 	// This ensures CORS headers are applied properly and consistently
 	r.Use(corsMiddleware(allowedOrigins))
@@ -70,12 +96,15 @@ func (s *Server) SetupRoutes() {
 	r.Use(middleware.IPBanCheck(securityService))
 
 	// Basic rate limit for all endpoints
-	r.Use(middleware.RateLimit(securityService, "default"))
+	r.Use(middleware.RateLimit(securityService, constants.RateLimitGroupDefault))
 
 	// Add auto-ban middleware that monitors for suspicious requests
 	// Ban for 24 hours after 5 suspicious activities within 5 minutes
 	r.Use(middleware.AutoBan(securityService, 10, 10*time.Minute, 24*time.Hour))
 
+	// Only requests this server accepted are mirrored to the shadow deployment
+	r.Use(shadowMirror.Mirror())
+
 	// Custom CORS middleware that applies to all routes
 	// This ensures CORS headers are applied properly and consistently
 	r.Use(corsMiddleware(allowedOrigins))
@@ -103,6 +132,7 @@ func (s *Server) SetupRoutes() {
 			utils.JSON(w, http.StatusOK, map[string]string{
 				"version":     s.Config.App.Version,
 				"environment": s.Config.App.Environment,
+				"api_version": strconv.Itoa(constants.APIVersionCurrent),
 			})
 		})
 
@@ -118,146 +148,495 @@ func (s *Server) SetupRoutes() {
 		))
 	})
 
-	// API routes
+	// API routes, declared with the scopes, rate limits, load shedding, timeouts and
+	// auditing they need; see route_registry.go
+	registry := newRouteRegistry(securityService, loadShedder, s.requestMetrics)
+	jwtAuth := middleware.JWTAuth(s.authProviders.JWTService)
+	// API keys let automation use the routes authenticated this way, within the scopes of the key
+	jwtOrAPIKeyAuth := middleware.JWTOrAPIKeyAuth(s.authProviders.JWTService, services.apiKeyVerifier)
+	trackUsage := middleware.TrackUsage(services.usageService)
+	// Processing endpoints report the remaining detection quota
+	quotaRemaining := middleware.QuotaRemaining(services.quotaService)
+	// Exports and downloads are shed first under load
+	exports := loadShedClass{"exports", constants.LoadShedPriorityLow}
+
 	r.Route("/api", func(r chi.Router) {
-		// Authentication routes
-		r.Route("/auth", func(r chi.Router) {
-			// Apply stricter rate limit for auth endpoints to prevent brute force
-			r.Use(middleware.RateLimit(securityService, "auth"))
-
-			// Public auth endpoints
-			r.Group(func(r chi.Router) {
-				r.Post("/signup", s.Handlers.AuthHandler.Register)
-				r.Post("/login", s.Handlers.AuthHandler.Login)
-				r.Post("/refresh", s.Handlers.AuthHandler.RefreshToken)
-				r.Post("/logout", s.Handlers.AuthHandler.Logout)
-				r.Post("/validate-key", s.Handlers.AuthHandler.ValidateAPIKey)
-				// endpoint for verifying API keys without user authentication
-				r.Get("/verify-key", s.Handlers.AuthHandler.VerifyAPIKeySimple)
-
-				// Explicitly handle OPTIONS preflight request for /verify endpoint
-				r.Options("/verify", handlePreflight(allowedOrigins))
-				r.Get("/verify", s.Handlers.AuthHandler.VerifyToken)
-
-				// Add forgot-password and reset-password routes
-				r.Post("/forgot-password", s.Handlers.PasswordResetHandler.ForgotPassword)
-				r.Post("/reset-password", s.Handlers.PasswordResetHandler.ResetPassword)
-			})
+		registry.mount(r, "/api", []routeGroup{
+			{
+				Routes: []route{
+					// Public status page data; it must stay available while the database struggles
+					{Method: http.MethodGet, Pattern: "/status", Handler: s.Handlers.StatusHandler.GetStatus, LoadShed: loadShedClass{"status", constants.LoadShedPriorityCritical}},
+					// Public XSD files of the endpoints that can respond with XML
+					{Method: http.MethodGet, Pattern: "/schemas/xml/{schema}", Handler: s.Handlers.SchemaHandler.GetXMLSchema},
+				},
+			},
 
-			// Protected auth endpoints
-			r.Group(func(r chi.Router) {
-				r.Use(middleware.JWTAuth(s.authProviders.JWTService))
-				r.Use(middleware.AddRoleToContext(s.authProviders.JWTService))
-				// verify JWT tokens used for user sessions
-				r.Get("/verify", s.Handlers.AuthHandler.VerifyToken)
-				// security feature to log out all sessions - admin only
-				r.With(middleware.RequireRole(constants.RoleAdmin)).Post("/logout-all", s.Handlers.AuthHandler.LogoutAll)
-			})
-		})
+			// Public authentication endpoints, with a stricter rate limit to slow down brute force
+			{
+				Prefix: "/auth",
+				Defaults: route{
+					RateLimit: constants.RateLimitGroupAuth,
+					LoadShed:  loadShedClass{"auth", constants.LoadShedPriorityCritical},
+				},
+				Routes: []route{
+					{Method: http.MethodPost, Pattern: "/signup", Handler: s.Handlers.AuthHandler.Register},
+					{Method: http.MethodPost, Pattern: "/login", Handler: s.Handlers.AuthHandler.Login},
+					{Method: http.MethodPost, Pattern: "/refresh", Handler: s.Handlers.AuthHandler.RefreshToken},
+					{Method: http.MethodPost, Pattern: "/logout", Handler: s.Handlers.AuthHandler.Logout},
+					{Method: http.MethodPost, Pattern: "/validate-key", Handler: s.Handlers.AuthHandler.ValidateAPIKey},
+					// Verifies API keys without user authentication
+					{Method: http.MethodGet, Pattern: "/verify-key", Handler: s.Handlers.AuthHandler.VerifyAPIKeySimple},
+					// Explicitly handle OPTIONS preflight request for /verify endpoint
+					{Method: http.MethodOptions, Pattern: "/verify", Handler: handlePreflight(allowedOrigins)},
+					{Method: http.MethodPost, Pattern: "/forgot-password", Handler: s.Handlers.PasswordResetHandler.ForgotPassword},
+					{Method: http.MethodPost, Pattern: "/reset-password", Handler: s.Handlers.PasswordResetHandler.ResetPassword},
+					// Lifts the hold risk scoring placed on a new account
+					{Method: http.MethodPost, Pattern: "/verify-email", Handler: s.Handlers.RiskHandler.VerifyEmail},
+					// Sign in with Google or Microsoft; the browser is redirected through both endpoints
+					{Method: http.MethodGet, Pattern: "/oauth/{provider}/start", Handler: s.Handlers.OAuthHandler.Start},
+					{Method: http.MethodGet, Pattern: "/oauth/{provider}/callback", Handler: s.Handlers.OAuthHandler.Callback},
+				},
+			},
+			{
+				Prefix:     "/auth",
+				Middleware: middlewares(jwtAuth),
+				Defaults: route{
+					RateLimit: constants.RateLimitGroupAuth,
+					LoadShed:  loadShedClass{"auth", constants.LoadShedPriorityCritical},
+				},
+				Routes: []route{
+					// Verifies JWT tokens used for user sessions
+					{Method: http.MethodGet, Pattern: "/verify", Handler: s.Handlers.AuthHandler.VerifyToken},
+					// Logs out all sessions of all users
+					{Method: http.MethodPost, Pattern: "/logout-all", Handler: s.Handlers.AuthHandler.LogoutAll, Audit: constants.LogCategoryAdmin, Middleware: middlewares(middleware.RequireRole(constants.RoleAdmin))},
+				},
+			},
 
-		// User routes (all protected)
-		r.Route("/users", func(r chi.Router) {
-			// Public user endpoints
-			r.Group(func(r chi.Router) {
-				r.Use(chimiddleware.NoCache)
-				//Checks if a specific username is available (not already taken)
-				r.Get("/check/username", s.Handlers.UserHandler.CheckUsername)
-				// checks if a specific email address is available (not already registered)
-				r.Get("/check/email", s.Handlers.UserHandler.CheckEmail)
-			})
+			// Availability of usernames and email addresses
+			{
+				Prefix:     "/users",
+				Middleware: middlewares(chimiddleware.NoCache),
+				Defaults:   route{LoadShed: loadShedClass{"users", constants.LoadShedPriorityNormal}},
+				Routes: []route{
+					{Method: http.MethodGet, Pattern: "/check/username", Handler: s.Handlers.UserHandler.CheckUsername},
+					{Method: http.MethodGet, Pattern: "/check/email", Handler: s.Handlers.UserHandler.CheckEmail},
+				},
+			},
+			// Permissions of the caller; API keys get the scopes of the key
+			{
+				Prefix:     "/users",
+				Middleware: middlewares(jwtOrAPIKeyAuth),
+				Defaults: route{
+					LoadShed:   loadShedClass{"users", constants.LoadShedPriorityNormal},
+					Middleware: middlewares(trackUsage),
+				},
+				Routes: []route{
+					{Method: http.MethodGet, Pattern: "/me/permissions", Handler: s.Handlers.PermissionHandler.GetMyPermissions},
+				},
+			},
+			// The account of the caller
+			{
+				Prefix:     "/users",
+				Middleware: middlewares(jwtAuth),
+				Defaults: route{
+					LoadShed:   loadShedClass{"users", constants.LoadShedPriorityNormal},
+					Middleware: middlewares(trackUsage),
+				},
+				Routes: []route{
+					{Method: http.MethodGet, Pattern: "/me", Handler: s.Handlers.UserHandler.GetCurrentUser},
+					{Method: http.MethodPut, Pattern: "/me", Handler: s.Handlers.UserHandler.UpdateUser},
+					// Accounts are erased in the background after a grace period, until which users can cancel
+					{Method: http.MethodDelete, Pattern: "/me", Handler: s.Handlers.AccountErasureHandler.DeleteAccount},
+					{Method: http.MethodGet, Pattern: "/me/erasure", Handler: s.Handlers.AccountErasureHandler.GetMyErasure},
+					{Method: http.MethodDelete, Pattern: "/me/erasure", Handler: s.Handlers.AccountErasureHandler.CancelMyErasure},
+					{Method: http.MethodPost, Pattern: "/me/change-password", Handler: s.Handlers.UserHandler.ChangePassword},
+					{Method: http.MethodGet, Pattern: "/me/sessions", Handler: s.Handlers.UserHandler.GetActiveSessions},
+					{Method: http.MethodDelete, Pattern: "/me/sessions", Handler: s.Handlers.UserHandler.InvalidateSession},
+					// Everything kept about the user, streamed as one download (GDPR Article 15)
+					{Method: http.MethodGet, Pattern: "/me/export", Handler: s.Handlers.UserDataExportHandler.ExportMyData, Timeout: constants.RouteTimeoutStream},
+					// The same as a background job, for accounts too large to export in one request
+					{Method: http.MethodPost, Pattern: "/me/exports", Handler: s.Handlers.UserDataExportHandler.CreateMyExport},
+					{Method: http.MethodGet, Pattern: "/me/exports", Handler: s.Handlers.UserDataExportHandler.ListMyExports},
+					{Method: http.MethodGet, Pattern: "/me/exports/{id}", Handler: s.Handlers.UserDataExportHandler.GetMyExport},
+					{Method: http.MethodGet, Pattern: "/me/exports/{id}/download", Handler: s.Handlers.UserDataExportHandler.DownloadMyExport, Timeout: constants.RouteTimeoutStream},
+					// Detection quota, shared with the detection service
+					{Method: http.MethodGet, Pattern: "/me/quota", Handler: s.Handlers.QuotaHandler.GetMyQuota},
+					{Method: http.MethodPost, Pattern: "/me/quota/consume", Handler: s.Handlers.QuotaHandler.ConsumeQuota, Middleware: middlewares(quotaRemaining)},
+				},
+			},
 
-			// Protected user endpoints
-			r.Group(func(r chi.Router) {
-				r.Use(middleware.JWTAuth(s.authProviders.JWTService))
-
-				// /me allows to delete account and change password and get current user info and update user info
-				// and get active sessions and invalidate session
-				r.Route("/me", func(r chi.Router) {
-					r.Get("/", s.Handlers.UserHandler.GetCurrentUser)
-					r.Put("/", s.Handlers.UserHandler.UpdateUser)
-					r.Delete("/", s.Handlers.UserHandler.DeleteAccount)
-					r.Post("/change-password", s.Handlers.UserHandler.ChangePassword)
-					r.Get("/sessions", s.Handlers.UserHandler.GetActiveSessions)
-					r.Delete("/sessions", s.Handlers.UserHandler.InvalidateSession)
-				})
-			})
-		})
+			// API keys of the caller
+			{
+				Prefix:     "/keys",
+				Middleware: middlewares(jwtAuth),
+				Defaults: route{
+					LoadShed:   loadShedClass{"keys", constants.LoadShedPriorityNormal},
+					Middleware: middlewares(trackUsage),
+				},
+				Routes: []route{
+					{Method: http.MethodGet, Pattern: "/", Handler: s.Handlers.AuthHandler.ListAPIKeys},
+					{Method: http.MethodPost, Pattern: "/", Handler: s.Handlers.AuthHandler.CreateAPIKey},
+					{Method: http.MethodDelete, Pattern: "/{keyID}", Handler: s.Handlers.AuthHandler.DeleteAPIKey},
+					{Method: http.MethodPost, Pattern: "/{keyID}/rotate", Handler: s.Handlers.AuthHandler.RotateAPIKey},
+					{Method: http.MethodGet, Pattern: "/{keyID}/decode", Handler: s.Handlers.AuthHandler.GetAPIKeyDecoded},
+				},
+			},
 
-		// API key routes (all protected)
-		r.Route("/keys", func(r chi.Router) {
-			r.Use(middleware.JWTAuth(s.authProviders.JWTService))
+			// Settings of the caller
+			{
+				Prefix:     "/settings",
+				Middleware: middlewares(jwtOrAPIKeyAuth),
+				Defaults: route{
+					Scopes:     routeScopes{constants.APIKeyScopeSettingsRead, constants.APIKeyScopeSettingsWrite},
+					RateLimit:  constants.RateLimitGroupAPI,
+					LoadShed:   loadShedClass{"settings", constants.LoadShedPriorityNormal},
+					Middleware: middlewares(trackUsage),
+				},
+				Routes: []route{
+					{Method: http.MethodGet, Pattern: "/", Handler: s.Handlers.SettingsHandler.GetSettings},
+					{Method: http.MethodPut, Pattern: "/", Handler: s.Handlers.SettingsHandler.UpdateSettings},
+					// Changes made to an older version of the settings on another device
+					{Method: http.MethodPost, Pattern: "/merge", Handler: s.Handlers.SettingsHandler.MergeSettings},
+					// Composed configuration read by the detection service for every document
+					{Method: http.MethodGet, Pattern: "/detection-config", Handler: s.Handlers.SettingsHandler.GetDetectionConfig},
+					{Method: http.MethodGet, Pattern: "/export", Handler: s.Handlers.SettingsHandler.ExportSettings},
+					{Method: http.MethodPost, Pattern: "/import", Handler: s.Handlers.SettingsHandler.ImportSettings},
+					// Settings change feed; long-polling requests extend their own deadline
+					{Method: http.MethodGet, Pattern: "/changes", Handler: s.Handlers.SettingsHandler.GetSettingsChanges, Timeout: constants.RouteTimeoutStream},
+					// Detections produced by the ban list words and search patterns
+					{Method: http.MethodGet, Pattern: "/effectiveness", Handler: s.Handlers.RuleEffectivenessHandler.GetEffectiveness},
+					{Method: http.MethodGet, Pattern: "/ban-list", Handler: s.Handlers.SettingsHandler.GetBanList},
+					{Method: http.MethodPatch, Pattern: "/ban-list", Handler: s.Handlers.SettingsHandler.SetBanListOptions},
+					{Method: http.MethodPost, Pattern: "/ban-list/words", Handler: s.Handlers.SettingsHandler.AddBanListWords},
+					{Method: http.MethodDelete, Pattern: "/ban-list/words", Handler: s.Handlers.SettingsHandler.RemoveBanListWords},
+					{Method: http.MethodPatch, Pattern: "/ban-list/words", Handler: s.Handlers.SettingsHandler.SetBanListWordOptions},
+					{Method: http.MethodGet, Pattern: "/patterns", Handler: s.Handlers.SettingsHandler.GetSearchPatterns},
+					{Method: http.MethodPost, Pattern: "/patterns", Handler: s.Handlers.SettingsHandler.CreateSearchPattern},
+					{Method: http.MethodPost, Pattern: "/patterns/test", Handler: s.Handlers.SettingsHandler.TestSearchPattern},
+					{Method: http.MethodGet, Pattern: "/patterns/catalog", Handler: s.Handlers.SettingsHandler.GetSearchPatternCatalog},
+					{Method: http.MethodPost, Pattern: "/patterns/import-catalog", Handler: s.Handlers.SettingsHandler.ImportCatalogPatterns},
+					{Method: http.MethodPut, Pattern: "/patterns/{patternID}", Handler: s.Handlers.SettingsHandler.UpdateSearchPattern},
+					{Method: http.MethodDelete, Pattern: "/patterns/{patternID}", Handler: s.Handlers.SettingsHandler.DeleteSearchPattern},
+					{Method: http.MethodGet, Pattern: "/classification-rules", Handler: s.Handlers.ClassificationHandler.GetRules},
+					{Method: http.MethodPost, Pattern: "/classification-rules", Handler: s.Handlers.ClassificationHandler.CreateRule},
+					{Method: http.MethodPut, Pattern: "/classification-rules/{ruleID}", Handler: s.Handlers.ClassificationHandler.UpdateRule},
+					{Method: http.MethodDelete, Pattern: "/classification-rules/{ruleID}", Handler: s.Handlers.ClassificationHandler.DeleteRule},
+					// Model entities are streamed as NDJSON if the client asks for it
+					{Method: http.MethodGet, Pattern: "/entities/{methodID}", Handler: s.Handlers.SettingsHandler.GetModelEntities, Timeout: constants.RouteTimeoutStream},
+					{Method: http.MethodPost, Pattern: "/entities", Handler: s.Handlers.SettingsHandler.AddModelEntities},
+					// Sync clients replace a method's entities with their full list in one transaction
+					{Method: http.MethodPut, Pattern: "/entities/{methodID}", Handler: s.Handlers.SettingsHandler.ReplaceModelEntities},
+					{Method: http.MethodDelete, Pattern: "/entities/{entityID}", Handler: s.Handlers.SettingsHandler.DeleteModelEntity},
+					{Method: http.MethodDelete, Pattern: "/entities/delete_entities_by_method_id/{methodID}", Handler: s.Handlers.SettingsHandler.DeleteModelEntityByMethodID},
+				},
+			},
 
-			r.Get("/", s.Handlers.AuthHandler.ListAPIKeys)
-			r.Post("/", s.Handlers.AuthHandler.CreateAPIKey)
-			r.Delete("/{keyID}", s.Handlers.AuthHandler.DeleteAPIKey)
-			r.Get("/{keyID}/decode", s.Handlers.AuthHandler.GetAPIKeyDecoded)
-		})
+			// Administration. API keys let operators script admin tasks, for example with
+			// hidemectl, and every request is audited.
+			{
+				Prefix:     "/admin",
+				Middleware: middlewares(jwtOrAPIKeyAuth, middleware.RequireRole(constants.RoleAdmin)),
+				Defaults: route{
+					Scopes: routeScopes{constants.APIKeyScopeAdmin, constants.APIKeyScopeAdmin},
+					// Administrators must be able to investigate an overloaded database
+					LoadShed: loadShedClass{"admin", constants.LoadShedPriorityCritical},
+					Audit:    constants.LogCategoryAdmin,
+				},
+				Routes: []route{
+					// Security management
+					{Method: http.MethodGet, Pattern: "/security/bans", Handler: securityHandler.ListBannedIPs},
+					{Method: http.MethodPost, Pattern: "/security/bans", Handler: securityHandler.BanIP},
+					{Method: http.MethodDelete, Pattern: "/security/bans/{id}", Handler: securityHandler.UnbanIP},
+					// Rate limit consumption and temporary burst grants
+					{Method: http.MethodGet, Pattern: "/rate-limits", Handler: rateLimitHandler.GetRateLimits},
+					{Method: http.MethodPost, Pattern: "/rate-limits/grants", Handler: rateLimitHandler.GrantBurst},
+					// Billing export of monthly tenant usage
+					{Method: http.MethodGet, Pattern: "/billing-export", Handler: s.Handlers.UsageHandler.ExportBilling, LoadShed: exports, Timeout: constants.RouteTimeoutLong},
+					// Destructive actions with two-person approval
+					{Method: http.MethodGet, Pattern: "/actions", Handler: s.Handlers.ApprovalHandler.ListActions},
+					{Method: http.MethodPost, Pattern: "/actions", Handler: s.Handlers.ApprovalHandler.RequestAction},
+					{Method: http.MethodGet, Pattern: "/actions/{id}", Handler: s.Handlers.ApprovalHandler.GetAction},
+					{Method: http.MethodPost, Pattern: "/actions/{id}/approve", Handler: s.Handlers.ApprovalHandler.ApproveAction},
+					{Method: http.MethodPost, Pattern: "/actions/{id}/reject", Handler: s.Handlers.ApprovalHandler.RejectAction},
+					// Transfer of documents between users, for example when an employee leaves
+					{Method: http.MethodGet, Pattern: "/document-transfers", Handler: s.Handlers.DocumentTransferHandler.ListTransfers},
+					{Method: http.MethodPost, Pattern: "/document-transfers", Handler: s.Handlers.DocumentTransferHandler.TransferDocuments},
+					// Time-boxed, read-only access of external auditors
+					{Method: http.MethodGet, Pattern: "/auditor-grants", Handler: s.Handlers.AuditorGrantHandler.ListGrants},
+					{Method: http.MethodPost, Pattern: "/auditor-grants", Handler: s.Handlers.AuditorGrantHandler.CreateGrant},
+					{Method: http.MethodDelete, Pattern: "/auditor-grants/{id}", Handler: s.Handlers.AuditorGrantHandler.RevokeGrant},
+					{Method: http.MethodGet, Pattern: "/auditor-grants/{id}/access", Handler: s.Handlers.AuditorGrantHandler.ListAccess},
+					// Accounts held back by risk scoring
+					{Method: http.MethodGet, Pattern: "/account-holds", Handler: s.Handlers.RiskHandler.ListHolds},
+					{Method: http.MethodDelete, Pattern: "/account-holds/{id}", Handler: s.Handlers.RiskHandler.ReleaseHold},
+					// Detection quotas of users
+					{Method: http.MethodGet, Pattern: "/quotas/{id}", Handler: s.Handlers.QuotaHandler.GetUserQuota},
+					{Method: http.MethodPost, Pattern: "/quotas/{id}/adjust", Handler: s.Handlers.QuotaHandler.AdjustUserQuota},
+					// Query metrics and slow query plans
+					{Method: http.MethodGet, Pattern: "/diagnostics/queries", Handler: s.Handlers.DiagnosticsHandler.GetQueryDiagnostics},
+					{Method: http.MethodDelete, Pattern: "/diagnostics/queries", Handler: s.Handlers.DiagnosticsHandler.ResetQueryMetrics},
+					{Method: http.MethodPut, Pattern: "/diagnostics/queries/settings", Handler: s.Handlers.DiagnosticsHandler.UpdateQueryDiagnostics},
+					// Hits and misses of the in-process caches
+					{Method: http.MethodGet, Pattern: "/diagnostics/caches", Handler: s.Handlers.DiagnosticsHandler.GetCacheDiagnostics},
+					// Divergences of the shadow deployment from this server
+					{Method: http.MethodGet, Pattern: "/diagnostics/shadow", Handler: s.Handlers.DiagnosticsHandler.GetShadowDiagnostics},
+					// Incident notes shown on the public status page
+					{Method: http.MethodGet, Pattern: "/status/incidents", Handler: s.Handlers.StatusHandler.ListIncidents},
+					{Method: http.MethodPost, Pattern: "/status/incidents", Handler: s.Handlers.StatusHandler.CreateIncident},
+					{Method: http.MethodPut, Pattern: "/status/incidents/{id}", Handler: s.Handlers.StatusHandler.UpdateIncident},
+					{Method: http.MethodDelete, Pattern: "/status/incidents/{id}", Handler: s.Handlers.StatusHandler.DeleteIncident},
+					// Announcements delivered to users through the API
+					{Method: http.MethodGet, Pattern: "/announcements", Handler: s.Handlers.AnnouncementHandler.ListAnnouncements},
+					{Method: http.MethodPost, Pattern: "/announcements", Handler: s.Handlers.AnnouncementHandler.CreateAnnouncement},
+					{Method: http.MethodPut, Pattern: "/announcements/{id}", Handler: s.Handlers.AnnouncementHandler.UpdateAnnouncement},
+					{Method: http.MethodDelete, Pattern: "/announcements/{id}", Handler: s.Handlers.AnnouncementHandler.DeleteAnnouncement},
+					// Email domains registration is limited to once verified
+					{Method: http.MethodGet, Pattern: "/registration-domains", Handler: s.Handlers.RegistrationDomainHandler.ListRegistrationDomains},
+					{Method: http.MethodPost, Pattern: "/registration-domains", Handler: s.Handlers.RegistrationDomainHandler.AddRegistrationDomain},
+					{Method: http.MethodPost, Pattern: "/registration-domains/{id}/verify", Handler: s.Handlers.RegistrationDomainHandler.VerifyRegistrationDomain},
+					{Method: http.MethodDelete, Pattern: "/registration-domains/{id}", Handler: s.Handlers.RegistrationDomainHandler.DeleteRegistrationDomain},
+					// Promotion of the detection methods and entity-type taxonomy between environments
+					{Method: http.MethodGet, Pattern: "/detection-catalog/export", Handler: s.Handlers.DetectionCatalogHandler.ExportDetectionCatalog},
+					{Method: http.MethodPost, Pattern: "/detection-catalog/import/preview", Handler: s.Handlers.DetectionCatalogHandler.PreviewDetectionCatalogImport},
+					{Method: http.MethodPost, Pattern: "/detection-catalog/import", Handler: s.Handlers.DetectionCatalogHandler.ImportDetectionCatalog},
+					// Search across users, documents, API keys and sessions
+					{Method: http.MethodGet, Pattern: "/search", Handler: s.Handlers.AdminSearchHandler.Search},
+					// Analytics of the database health
+					{Method: http.MethodGet, Pattern: "/analytics/index-suggestions", Handler: s.Handlers.AnalyticsHandler.GetIndexSuggestions, LoadShed: loadShedClass{"analytics", constants.LoadShedPriorityLow}},
+					{Method: http.MethodPost, Pattern: "/analytics/index-suggestions/refresh", Handler: s.Handlers.AnalyticsHandler.RefreshIndexSuggestions, LoadShed: loadShedClass{"analytics", constants.LoadShedPriorityLow}, Timeout: constants.RouteTimeoutLong},
+					// User lookup and session revocation for support and incident response
+					{Method: http.MethodGet, Pattern: "/users", Handler: s.Handlers.UserHandler.LookupUser},
+					{Method: http.MethodDelete, Pattern: "/users/{id}/sessions", Handler: s.Handlers.UserHandler.RevokeUserSessions},
+					{Method: http.MethodPost, Pattern: "/users/{id}/disable", Handler: s.Handlers.UserHandler.DisableUser},
+					{Method: http.MethodPost, Pattern: "/users/{id}/enable", Handler: s.Handlers.UserHandler.EnableUser},
+					{Method: http.MethodPut, Pattern: "/users/{id}/role", Handler: s.Handlers.UserHandler.UpdateUserRole},
+					{Method: http.MethodDelete, Pattern: "/users/{id}", Handler: s.Handlers.UserHandler.EraseUser},
+					// Maintenance tasks on demand and their settings
+					{Method: http.MethodGet, Pattern: "/maintenance", Handler: s.Handlers.MaintenanceHandler.ListTasks},
+					{Method: http.MethodGet, Pattern: "/maintenance/" + constants.MaintenanceTaskSettingsConsistency, Handler: s.Handlers.SettingsConsistencyHandler.CheckSettings},
+					{Method: http.MethodPost, Pattern: "/maintenance/{task}", Handler: s.Handlers.MaintenanceHandler.RunTask, Timeout: constants.RouteTimeoutLong},
+					{Method: http.MethodPut, Pattern: "/maintenance/{task}/settings", Handler: s.Handlers.MaintenanceHandler.UpdateTaskSettings},
+					// Rebuild of derived tables from the event log
+					{Method: http.MethodGet, Pattern: "/projections", Handler: s.Handlers.ProjectionHandler.ListProjections},
+					{Method: http.MethodPost, Pattern: "/projections/{projection}/rebuild", Handler: s.Handlers.ProjectionHandler.RebuildProjection, Timeout: constants.RouteTimeoutLong},
+					// Check of the running configuration
+					{Method: http.MethodGet, Pattern: "/config", Handler: s.Handlers.ConfigHandler.CheckConfig},
+					// Register of the personal and sensitive data the application stores
+					{Method: http.MethodGet, Pattern: "/processing-register", Handler: s.Handlers.ProcessingRegisterHandler.GetProcessingRegister},
+					// API keys of all users, their expiry policy and rotation campaigns
+					{Method: http.MethodGet, Pattern: "/api-keys", Handler: s.Handlers.APIKeyAdminHandler.ListKeys},
+					{Method: http.MethodPost, Pattern: "/api-keys/{keyID}/disable", Handler: s.Handlers.APIKeyAdminHandler.DisableKey},
+					{Method: http.MethodGet, Pattern: "/api-keys/policy", Handler: s.Handlers.APIKeyAdminHandler.GetPolicy},
+					{Method: http.MethodPut, Pattern: "/api-keys/policy", Handler: s.Handlers.APIKeyAdminHandler.SetPolicy},
+					{Method: http.MethodGet, Pattern: "/api-keys/rotation-campaigns", Handler: s.Handlers.APIKeyAdminHandler.ListCampaigns},
+					{Method: http.MethodPost, Pattern: "/api-keys/rotation-campaigns", Handler: s.Handlers.APIKeyAdminHandler.StartRotationCampaign},
+				},
+			},
 
-		// Settings routes (all protected)
-		r.Route("/settings", func(r chi.Router) {
-			r.Use(middleware.JWTAuth(s.authProviders.JWTService))
+			// Documents of the caller and the documents shared with them
+			{
+				Prefix:     "/documents",
+				Middleware: middlewares(jwtOrAPIKeyAuth),
+				Defaults: route{
+					Scopes:     routeScopes{constants.APIKeyScopeDocumentsRead, constants.APIKeyScopeDocumentsWrite},
+					RateLimit:  constants.RateLimitGroupDocuments,
+					LoadShed:   loadShedClass{"documents", constants.LoadShedPriorityNormal},
+					Middleware: middlewares(trackUsage),
+				},
+				Routes: []route{
+					// Documents are streamed as NDJSON if the client asks for it
+					{Method: http.MethodGet, Pattern: "/", Handler: s.Handlers.DocumentHandler.ListDocuments, Timeout: constants.RouteTimeoutStream},
+					// Search by name, upload date and detected entities
+					{Method: http.MethodGet, Pattern: "/search", Handler: s.Handlers.DocumentHandler.SearchDocuments},
+					{Method: http.MethodPost, Pattern: "/", Handler: s.Handlers.DocumentHandler.UploadDocument, Middleware: middlewares(quotaRemaining)},
+					// Documents other users shared with the user
+					{Method: http.MethodGet, Pattern: "/shared-with-me", Handler: s.Handlers.DocumentShareHandler.ListSharedWithMe},
+					// Detected values leaking in several documents, by salted hash only
+					{Method: http.MethodGet, Pattern: "/duplicate-leaks", Handler: s.Handlers.DuplicateLeakHandler.GetDuplicateLeakReport},
+					// Dry run of the document retention maintenance task for the user's documents
+					{Method: http.MethodGet, Pattern: "/retention-preview", Handler: s.Handlers.DocumentHandler.PreviewRetention},
+					{Method: http.MethodGet, Pattern: "/{id}", Handler: s.Handlers.DocumentHandler.GetDocumentByID},
+					{Method: http.MethodDelete, Pattern: "/{id}", Handler: s.Handlers.DocumentHandler.DeleteDocumentByID},
+					// Collaborators with redact access change the redactions of shared documents
+					{Method: http.MethodPut, Pattern: "/{id}/redaction-schema", Handler: s.Handlers.DocumentHandler.UpdateRedactionSchema},
+					// Each save keeps the replaced schema, so earlier redaction states can be restored
+					{Method: http.MethodGet, Pattern: "/{id}/redaction-schema/versions", Handler: s.Handlers.DocumentHandler.ListSchemaVersions},
+					{Method: http.MethodPost, Pattern: "/{id}/redaction-schema/versions/{version}/rollback", Handler: s.Handlers.DocumentHandler.RollbackRedactionSchema},
+					// Detection results of large documents are added in one transaction
+					{Method: http.MethodPost, Pattern: "/{id}/entities/batch", Handler: s.Handlers.DocumentHandler.AddDetectedEntities},
+					// Reviewers accept detected entities or reject false positives, which redaction then skips
+					{Method: http.MethodGet, Pattern: "/{id}/entities", Handler: s.Handlers.DocumentHandler.ListDetectedEntities},
+					{Method: http.MethodPatch, Pattern: "/{id}/entities", Handler: s.Handlers.DocumentHandler.UpdateEntityStatuses},
+					{Method: http.MethodPatch, Pattern: "/{id}/entities/{entityID}", Handler: s.Handlers.DocumentHandler.UpdateEntityStatus},
+					{Method: http.MethodPost, Pattern: "/{id}/shares", Handler: s.Handlers.DocumentShareHandler.ShareDocument},
+					{Method: http.MethodGet, Pattern: "/{id}/shares", Handler: s.Handlers.DocumentShareHandler.ListDocumentShares},
+					{Method: http.MethodDelete, Pattern: "/{id}/shares/{userId}", Handler: s.Handlers.DocumentShareHandler.RevokeDocumentShare},
+					// The uploaded PDF, decrypted, with range requests for viewers
+					{Method: http.MethodGet, Pattern: "/{id}/content", Handler: s.Handlers.DocumentHandler.GetDocumentContent, Timeout: constants.RouteTimeoutLong, Audit: constants.LogCategoryDocument},
+					// The same PDF as an attachment, streamed from storage so large files and resumed downloads don't load it whole
+					{Method: http.MethodGet, Pattern: "/{id}/download", Handler: s.Handlers.DocumentHandler.DownloadDocument, Timeout: constants.RouteTimeoutLong, Audit: constants.LogCategoryDocument},
+					{Method: http.MethodHead, Pattern: "/{id}/download", Handler: s.Handlers.DocumentHandler.DownloadDocument, Audit: constants.LogCategoryDocument},
+					{Method: http.MethodGet, Pattern: "/{id}/summary", Handler: s.Handlers.DocumentHandler.GetDocumentSummary},
+					{Method: http.MethodGet, Pattern: "/{id}/timeline", Handler: s.Handlers.DocumentHandler.GetDocumentTimeline},
+					// Full record of a document for handoff, e.g. to external counsel
+					{Method: http.MethodGet, Pattern: "/{id}/export", Handler: s.Handlers.DocumentHandler.ExportDocument, LoadShed: exports, Timeout: constants.RouteTimeoutLong},
+					// Documents in cold storage must be restored before their content is read
+					{Method: http.MethodPost, Pattern: "/{id}/restore-from-archive", Handler: s.Handlers.DocumentHandler.RestoreFromArchive},
+					// Unfinished documents are cleaned up after a notice unless they are exempt
+					{Method: http.MethodPut, Pattern: "/{id}/lifecycle", Handler: s.Handlers.DocumentHandler.UpdateLifecycle},
+					// Redaction rectangles of one page, for viewers drawing overlays
+					{Method: http.MethodGet, Pattern: "/{id}/pages/{n}/overlay", Handler: s.Handlers.DocumentHandler.GetPageOverlay},
+					// The PDF with its redactions applied on the server
+					{Method: http.MethodPost, Pattern: "/{id}/redact", Handler: s.Handlers.DocumentHandler.RedactDocument, LoadShed: exports, Timeout: constants.RouteTimeoutLong},
+					// Delivery of the document to the user's export destinations
+					{Method: http.MethodGet, Pattern: "/{id}/deliveries", Handler: s.Handlers.ExportHandler.ListDeliveries},
+					{Method: http.MethodPost, Pattern: "/{id}/deliveries", Handler: s.Handlers.ExportHandler.Deliver, LoadShed: exports, Timeout: constants.RouteTimeoutLong},
+				},
+			},
 
-			// Apply appropriate rate limit for API endpoints
-			r.Use(middleware.RateLimit(securityService, "api"))
+			// Detection, redaction and export of documents too large to process within a request
+			{
+				Prefix:     "/jobs",
+				Middleware: middlewares(jwtOrAPIKeyAuth),
+				Defaults: route{
+					Scopes:     routeScopes{constants.APIKeyScopeDocumentsRead, constants.APIKeyScopeDocumentsWrite},
+					RateLimit:  constants.RateLimitGroupDocuments,
+					LoadShed:   loadShedClass{"documents", constants.LoadShedPriorityNormal},
+					Middleware: middlewares(trackUsage),
+				},
+				Routes: []route{
+					{Method: http.MethodPost, Pattern: "/", Handler: s.Handlers.JobHandler.CreateJob},
+					{Method: http.MethodGet, Pattern: "/", Handler: s.Handlers.JobHandler.ListJobs},
+					{Method: http.MethodGet, Pattern: "/{id}", Handler: s.Handlers.JobHandler.GetJob},
+					{Method: http.MethodGet, Pattern: "/{id}/result", Handler: s.Handlers.JobHandler.DownloadJobResult, Timeout: constants.RouteTimeoutLong},
+				},
+			},
 
-			r.Get("/", s.Handlers.SettingsHandler.GetSettings)
-			r.Put("/", s.Handlers.SettingsHandler.UpdateSettings)
+			// Server-sent events with job progress and settings changes, so clients don't poll
+			{
+				Prefix:     "/events",
+				Middleware: middlewares(jwtAuth),
+				Defaults: route{
+					LoadShed: loadShedClass{"events", constants.LoadShedPriorityLow},
+				},
+				Routes: []route{
+					{Method: http.MethodGet, Pattern: "/", Handler: s.Handlers.NotificationHandler.StreamEvents, Timeout: constants.RouteTimeoutStream},
+				},
+			},
 
-			// Settings export/import routes
-			r.Get("/export", s.Handlers.SettingsHandler.ExportSettings)
-			r.Post("/import", s.Handlers.SettingsHandler.ImportSettings)
+			// Scheduled report subscriptions
+			{
+				Prefix:     "/reports",
+				Middleware: middlewares(jwtAuth),
+				Defaults: route{
+					LoadShed:   loadShedClass{"reports", constants.LoadShedPriorityLow},
+					Middleware: middlewares(trackUsage),
+				},
+				Routes: []route{
+					{Method: http.MethodGet, Pattern: "/subscriptions", Handler: s.Handlers.ReportHandler.ListSubscriptions},
+					{Method: http.MethodPost, Pattern: "/subscriptions", Handler: s.Handlers.ReportHandler.Subscribe},
+					{Method: http.MethodDelete, Pattern: "/subscriptions/{id}", Handler: s.Handlers.ReportHandler.Unsubscribe},
+				},
+			},
 
-			// Ban list routes
-			r.Route("/ban-list", func(r chi.Router) {
-				r.Get("/", s.Handlers.SettingsHandler.GetBanList)
-				r.Post("/words", s.Handlers.SettingsHandler.AddBanListWords)
-				r.Delete("/words", s.Handlers.SettingsHandler.RemoveBanListWords)
-			})
+			// Anonymized cross-tenant benchmarks
+			{
+				Prefix:     "/benchmarks",
+				Middleware: middlewares(jwtAuth),
+				Defaults: route{
+					LoadShed:   loadShedClass{"benchmarks", constants.LoadShedPriorityLow},
+					Middleware: middlewares(trackUsage),
+				},
+				Routes: []route{
+					{Method: http.MethodGet, Pattern: "/", Handler: s.Handlers.BenchmarkHandler.GetBenchmarks},
+					{Method: http.MethodGet, Pattern: "/consent", Handler: s.Handlers.BenchmarkHandler.GetConsent},
+					{Method: http.MethodPut, Pattern: "/consent", Handler: s.Handlers.BenchmarkHandler.SetConsent},
+					{Method: http.MethodDelete, Pattern: "/consent", Handler: s.Handlers.BenchmarkHandler.RevokeConsent},
+				},
+			},
 
-			// Search pattern routes
-			r.Route("/patterns", func(r chi.Router) {
-				r.Get("/", s.Handlers.SettingsHandler.GetSearchPatterns)
-				r.Post("/", s.Handlers.SettingsHandler.CreateSearchPattern)
-				r.Put("/{patternID}", s.Handlers.SettingsHandler.UpdateSearchPattern)
-				r.Delete("/{patternID}", s.Handlers.SettingsHandler.DeleteSearchPattern)
-			})
+			// In-product announcements such as maintenance windows
+			{
+				Prefix:     "/announcements",
+				Middleware: middlewares(jwtAuth),
+				Defaults: route{
+					LoadShed:   loadShedClass{"announcements", constants.LoadShedPriorityNormal},
+					Middleware: middlewares(trackUsage),
+				},
+				Routes: []route{
+					{Method: http.MethodGet, Pattern: "/", Handler: s.Handlers.AnnouncementHandler.GetAnnouncements},
+					{Method: http.MethodPost, Pattern: "/{id}/read", Handler: s.Handlers.AnnouncementHandler.MarkAnnouncementRead},
+				},
+			},
 
-			// Model entity routes
-			r.Route("/entities", func(r chi.Router) {
-				r.Get("/{methodID}", s.Handlers.SettingsHandler.GetModelEntities)
-				r.Post("/", s.Handlers.SettingsHandler.AddModelEntities)
-				r.Delete("/{entityID}", s.Handlers.SettingsHandler.DeleteModelEntity)
-				r.Delete("/delete_entities_by_method_id/{methodID}", s.Handlers.SettingsHandler.DeleteModelEntityByMethodID)
-			})
-		})
+			// Cloud drive connectors for importing documents
+			{
+				Prefix:     "/drives",
+				Middleware: middlewares(jwtAuth),
+				Defaults: route{
+					LoadShed:   loadShedClass{"drives", constants.LoadShedPriorityNormal},
+					Middleware: middlewares(trackUsage),
+				},
+				Routes: []route{
+					{Method: http.MethodGet, Pattern: "/", Handler: s.Handlers.DriveHandler.ListProviders},
+					{Method: http.MethodPost, Pattern: "/connect", Handler: s.Handlers.DriveHandler.Connect},
+					{Method: http.MethodPost, Pattern: "/{provider}/authorize", Handler: s.Handlers.DriveHandler.Authorize},
+					{Method: http.MethodDelete, Pattern: "/{provider}", Handler: s.Handlers.DriveHandler.Disconnect},
+					{Method: http.MethodGet, Pattern: "/{provider}/files", Handler: s.Handlers.DriveHandler.ListFiles},
+					// Downloads stream large files, so they are shed first under load
+					{Method: http.MethodGet, Pattern: "/{provider}/files/{fileID}/content", Handler: s.Handlers.DriveHandler.DownloadFile, LoadShed: loadShedClass{"drive_downloads", constants.LoadShedPriorityLow}, Timeout: constants.RouteTimeoutLong},
+				},
+			},
 
-		// Admin routes (require admin role)
-		r.Route("/admin", func(r chi.Router) {
-			// Apply JWT authentication and admin role check middleware
-			r.Use(middleware.JWTAuth(s.authProviders.JWTService))
-			r.Use(middleware.AddRoleToContext(s.authProviders.JWTService))
-			r.Use(middleware.RequireRole(constants.RoleAdmin))
-
-			// Security management
-			r.Route("/security", func(r chi.Router) {
-				r.Route("/bans", func(r chi.Router) {
-					r.Get("/", securityHandler.ListBannedIPs)
-					r.Post("/", securityHandler.BanIP)
-					r.Delete("/{id}", securityHandler.UnbanIP)
-				})
-			})
-		})
+			// Read-only access of external auditors, authenticated by the X-Auditor-Token header
+			// of their grant instead of a user account; every request is logged by the handlers
+			{
+				Prefix: "/auditor",
+				Defaults: route{
+					RateLimit: constants.RateLimitGroupAPI,
+					LoadShed:  loadShedClass{"auditor", constants.LoadShedPriorityNormal},
+				},
+				Routes: []route{
+					{Method: http.MethodGet, Pattern: "/documents", Handler: s.Handlers.AuditorGrantHandler.ListDocuments},
+					{Method: http.MethodGet, Pattern: "/documents/{id}", Handler: s.Handlers.AuditorGrantHandler.GetDocument},
+					{Method: http.MethodGet, Pattern: "/documents/{id}/export", Handler: s.Handlers.AuditorGrantHandler.ExportDocument, LoadShed: exports, Timeout: constants.RouteTimeoutLong},
+				},
+			},
+
+			// Destinations redacted documents are delivered to
+			{
+				Prefix:     "/export-destinations",
+				Middleware: middlewares(jwtAuth),
+				Defaults: route{
+					LoadShed:   loadShedClass{"export_destinations", constants.LoadShedPriorityNormal},
+					Middleware: middlewares(trackUsage),
+				},
+				Routes: []route{
+					{Method: http.MethodGet, Pattern: "/", Handler: s.Handlers.ExportHandler.ListDestinations},
+					{Method: http.MethodPost, Pattern: "/", Handler: s.Handlers.ExportHandler.CreateDestination},
+					{Method: http.MethodDelete, Pattern: "/{id}", Handler: s.Handlers.ExportHandler.DeleteDestination},
+				},
+			},
 
-		// Document routes (protected)
-		r.Route("/documents", func(r chi.Router) {
-			r.Use(middleware.JWTAuth(s.authProviders.JWTService))
-			r.Get("/", s.Handlers.DocumentHandler.ListDocuments)
-			r.Post("/", s.Handlers.DocumentHandler.UploadDocument)
-			r.Get("/{id}", s.Handlers.DocumentHandler.GetDocumentByID)
-			r.Delete("/{id}", s.Handlers.DocumentHandler.DeleteDocumentByID)
-			r.Get("/{id}/summary", s.Handlers.DocumentHandler.GetDocumentSummary)
+			// URLs account and document events are delivered to
+			{
+				Prefix:     "/webhooks",
+				Middleware: middlewares(jwtAuth),
+				Defaults: route{
+					LoadShed:   loadShedClass{"webhooks", constants.LoadShedPriorityNormal},
+					Middleware: middlewares(trackUsage),
+				},
+				Routes: []route{
+					{Method: http.MethodGet, Pattern: "/", Handler: s.Handlers.WebhookHandler.ListWebhooks},
+					{Method: http.MethodPost, Pattern: "/", Handler: s.Handlers.WebhookHandler.CreateWebhook},
+					{Method: http.MethodDelete, Pattern: "/{id}", Handler: s.Handlers.WebhookHandler.DeleteWebhook},
+					{Method: http.MethodGet, Pattern: "/{id}/deliveries", Handler: s.Handlers.WebhookHandler.ListDeliveries},
+					{Method: http.MethodPost, Pattern: "/{id}/deliveries/{deliveryID}/redeliver", Handler: s.Handlers.WebhookHandler.Redeliver},
+				},
+			},
 		})
 	})
+	s.routes = registry.routes
 
 	// Set the router
 	s.router = r
@@ -274,6 +653,10 @@ func (s *Server) GetRouter() chi.Router {
 	return s.router
 }
 
+// corsAllowedMethods are the methods preflight responses allow; every method a route is
+// registered with must be listed, or browsers can't call the route cross-origin.
+const corsAllowedMethods = "GET, POST, PUT, PATCH, DELETE, OPTIONS"
+
 // handlePreflight is an explicit handler for OPTIONS preflight requests.
 // It properly configures CORS headers for preflight requests to ensure
 // cross-origin requests can proceed if the origin is allowed.
@@ -302,8 +685,8 @@ func handlePreflight(allowedOrigins []string) http.HandlerFunc {
 		if allowed {
 			w.Header().Set(constants.HeaderContentType, constants.ContentTypeJSON)
 			w.Header().Set("Access-Control-Allow-Origin", origin)
-			w.Header().Set("Access-Control-Allow-Methods", "GET, POST, PUT, DELETE, OPTIONS")
-			w.Header().Set("Access-Control-Allow-Headers", "Accept, Authorization, Content-Type, X-CSRF-Token, X-Request-ID, X-API-Key")
+			w.Header().Set("Access-Control-Allow-Methods", corsAllowedMethods)
+			w.Header().Set("Access-Control-Allow-Headers", "Accept, Authorization, Content-Type, X-CSRF-Token, X-Request-ID, X-API-Key, X-Auditor-Token, X-API-Version")
 			w.Header().Set("Access-Control-Allow-Credentials", "true")
 			w.Header().Set("Access-Control-Max-Age", "300")
 		}
@@ -346,8 +729,8 @@ func corsMiddleware(allowedOrigins []string) func(http.Handler) http.Handler {
 					}
 
 					// Handle OPTIONS preflight requests
-					w.Header().Set("Access-Control-Allow-Methods", "GET, POST, PUT, DELETE, OPTIONS")
-					w.Header().Set("Access-Control-Allow-Headers", "Accept, Authorization, Content-Type, X-CSRF-Token, X-Request-ID, X-API-Key")
+					w.Header().Set("Access-Control-Allow-Methods", corsAllowedMethods)
+					w.Header().Set("Access-Control-Allow-Headers", "Accept, Authorization, Content-Type, X-CSRF-Token, X-Request-ID, X-API-Key, X-Auditor-Token, X-API-Version")
 					w.Header().Set("Access-Control-Max-Age", "300")
 
 					// Respond to preflight request
@@ -393,6 +776,22 @@ func getAllowedOrigins() []string {
 	return defaultOrigins
 }
 
+// listResponse is the example response of a list endpoint, in the list envelope clients
+// opt in to with X-API-Version: 2.
+func listResponse(items ...map[string]interface{}) map[string]interface{} {
+	return map[string]interface{}{
+		"success": true,
+		"data": map[string]interface{}{
+			"items": items,
+			"total": len(items),
+			"page_info": map[string]interface{}{
+				"next_cursor": nil,
+				"prev_cursor": nil,
+			},
+		},
+	}
+}
+
 // GetAPIRoutes returns documentation about all API routes.
 // This provides a self-documenting API endpoint that describes all available endpoints,
 // their parameters, expected responses, and required authentication.
@@ -420,6 +819,7 @@ func (s *Server) GetAPIRoutes(w http.ResponseWriter, r *http.Request) {
 				"email":            "string - Unique email address",
 				"password":         "string - Password (min 8 characters)",
 				"confirm_password": "string - Must match password",
+				"captcha_token":    "string - CAPTCHA response (only when a captcha challenge was returned)",
 			},
 			"response": map[string]interface{}{
 				"success": true,
@@ -438,9 +838,10 @@ func (s *Server) GetAPIRoutes(w http.ResponseWriter, r *http.Request) {
 				"Content-Type": "application/json",
 			},
 			"body": map[string]interface{}{
-				"username": "string - Username or null if using email",
-				"email":    "string - Email or null if using username",
-				"password": "string - User's password",
+				"username":      "string - Username or null if using email",
+				"email":         "string - Email or null if using username",
+				"password":      "string - User's password",
+				"captcha_token": "string - CAPTCHA response (only when a captcha challenge was returned)",
 			},
 			"response": map[string]interface{}{
 				"success": true,
@@ -565,6 +966,47 @@ func (s *Server) GetAPIRoutes(w http.ResponseWriter, r *http.Request) {
 				},
 			},
 		},
+		"POST /api/auth/verify-email": map[string]interface{}{
+			"description": "Verify an email address to lift the hold risk scoring placed on a new account",
+			"headers": map[string]string{
+				"Content-Type": "application/json",
+			},
+			"body": map[string]interface{}{
+				"token": "string - Verification token sent by email",
+			},
+			"response": map[string]interface{}{
+				"success": true,
+				"data": map[string]interface{}{
+					"message": "Email address successfully verified",
+				},
+			},
+		},
+		"GET /api/auth/oauth/{provider}/start": map[string]interface{}{
+			"description": "Start signing in with google or microsoft; redirects the browser to the provider's sign-in page",
+			"response":    "302 redirect to the provider, with the sign-in flow in an HTTP-only cookie",
+		},
+		"GET /api/auth/oauth/{provider}/callback": map[string]interface{}{
+			"description": "Complete signing in with a provider, which redirects the browser here. The provider account is linked to the account with its verified email address, or to a new account, on first use",
+			"query_params": map[string]string{
+				"code":  "string - Authorization code issued by the provider",
+				"state": "string - State of the sign-in flow",
+				"error": "string - Set by the provider instead of the code if the sign-in was cancelled",
+			},
+			"cookies_required": []string{"oauth_flow"},
+			"response": map[string]interface{}{
+				"success": true,
+				"data": map[string]interface{}{
+					"user":         "object - The signed-in user",
+					"access_token": "string - JWT access token",
+					"token_type":   "Bearer",
+					"expires_in":   3600,
+				},
+			},
+			"cookies": map[string]interface{}{
+				"refresh_token": "HTTP-only cookie containing the refresh token",
+			},
+			"note": "With OAUTH_LOGIN_REDIRECT_URL set, the browser is redirected there instead, with only the refresh token cookie, or with the error message in the error query parameter",
+		},
 	}
 
 	// User routes
@@ -633,7 +1075,7 @@ func (s *Server) GetAPIRoutes(w http.ResponseWriter, r *http.Request) {
 			},
 		},
 		"DELETE /api/users/me": map[string]interface{}{
-			"description": "Delete current user account",
+			"description": "Schedule the erasure of the current user account and all data kept about it; it runs in the background after a 7 day grace period",
 			"headers": map[string]string{
 				"Authorization": "Bearer {access_token}",
 				"Content-Type":  "application/json",
@@ -645,10 +1087,23 @@ func (s *Server) GetAPIRoutes(w http.ResponseWriter, r *http.Request) {
 			"response": map[string]interface{}{
 				"success": true,
 				"data": map[string]interface{}{
-					"message": "Account successfully deleted",
+					"message": "Account scheduled for deletion",
+					"erasure": "object - status and scheduled_for, the end of the grace period",
 				},
 			},
 		},
+		"GET /api/users/me/erasure": map[string]interface{}{
+			"description": "Get the scheduled erasure of the current user account (404 if none)",
+			"headers": map[string]string{
+				"Authorization": "Bearer {access_token}",
+			},
+		},
+		"DELETE /api/users/me/erasure": map[string]interface{}{
+			"description": "Cancel the erasure of the current user account during the grace period (409 once it has started)",
+			"headers": map[string]string{
+				"Authorization": "Bearer {access_token}",
+			},
+		},
 		"POST /api/users/me/change-password": map[string]interface{}{
 			"description": "Change current user password",
 			"headers": map[string]string{
@@ -667,22 +1122,88 @@ func (s *Server) GetAPIRoutes(w http.ResponseWriter, r *http.Request) {
 				},
 			},
 		},
-		"GET /api/users/me/sessions": map[string]interface{}{
-			"description": "Get active sessions for current user",
+		"GET /api/users/me/export": map[string]interface{}{
+			"description": "Download everything kept about the current user (GDPR Article 15): profile, settings, ban list, search patterns, model entities, document metadata and detected entities. The file is streamed; one that fails to parse was cut off by an error",
+			"headers": map[string]string{
+				"Authorization": "Bearer {access_token}",
+			},
+			"query_params": map[string]string{
+				"format": "json (default) for a single JSON file, or zip for a ZIP archive with manifest.json, user.json, settings.json, documents.json and detected_entities.json",
+			},
+			"response": map[string]interface{}{
+				"version":           1,
+				"exported_at":       "2023-01-02T09:00:00Z",
+				"data_categories":   map[string]string{"user.email": "personal", "detected_entities[].entity_name": "sensitive"},
+				"user":              map[string]interface{}{"id": 123, "username": "johndoe", "email": "john@example.com"},
+				"settings":          map[string]interface{}{"version": 2, "user_id": 123, "general_settings": map[string]interface{}{}, "ban_list": map[string]interface{}{}, "search_patterns": []interface{}{}, "model_entities": []interface{}{}},
+				"documents":         []map[string]interface{}{{"id": 456, "filename": "contract.pdf", "upload_timestamp": "2023-01-01T12:00:00Z"}},
+				"detected_entities": []map[string]interface{}{{"id": 789, "document_id": 456, "entity_name": "John Doe", "method_name": "Presidio"}},
+			},
+		},
+		"POST /api/users/me/exports": map[string]interface{}{
+			"description": "Start exporting everything kept about the current user as a background job, for accounts too large to download in one request. Only one export per user can be unfinished. The job stores a checkpoint after every batch of documents; if the server restarts, it resumes from there",
+			"headers": map[string]string{
+				"Authorization": "Bearer {access_token}",
+			},
+			"query_params": map[string]string{
+				"format": "json (default) or zip, as for GET /api/users/me/export",
+			},
+			"response": map[string]interface{}{
+				"success": true,
+				"data": map[string]interface{}{
+					"id":         42,
+					"format":     "json",
+					"status":     "pending",
+					"checkpoint": map[string]interface{}{"last_document_id": 0, "last_entity_id": 0, "documents_exported": 0, "entities_exported": 0, "chunks": 0},
+					"resumes":    0,
+					"created_at": "2023-01-02T09:00:00Z",
+					"updated_at": "2023-01-02T09:00:00Z",
+				},
+			},
+		},
+		"GET /api/users/me/exports": map[string]interface{}{
+			"description": "List the export jobs of the current user, newest first. Finished jobs and their files are deleted after 7 days",
+			"headers": map[string]string{
+				"Authorization": "Bearer {access_token}",
+			},
+		},
+		"GET /api/users/me/exports/{id}": map[string]interface{}{
+			"description": "Get the status of an export job with its checkpoint: the last document and detected entity exported and how often the job resumed after a restart. Status is pending, running, interrupted (resumes shortly), completed or failed",
 			"headers": map[string]string{
 				"Authorization": "Bearer {access_token}",
 			},
 			"response": map[string]interface{}{
 				"success": true,
-				"data": []map[string]interface{}{
-					{
-						"id":         "session-id-1",
-						"created_at": "2023-01-01T12:00:00Z",
-						"expires_at": "2023-01-08T12:00:00Z",
-					},
+				"data": map[string]interface{}{
+					"id":         42,
+					"format":     "json",
+					"status":     "running",
+					"checkpoint": map[string]interface{}{"last_document_id": 1200, "last_entity_id": 45120, "documents_exported": 1000, "entities_exported": 23100, "chunks": 41},
+					"resumes":    1,
+					"created_at": "2023-01-02T09:00:00Z",
+					"updated_at": "2023-01-02T11:30:00Z",
 				},
 			},
 		},
+		"GET /api/users/me/exports/{id}/download": map[string]interface{}{
+			"description": "Download the file of a completed export job, in the layout of GET /api/users/me/export. Returns 409 until the job has completed",
+			"headers": map[string]string{
+				"Authorization": "Bearer {access_token}",
+			},
+		},
+		"GET /api/users/me/sessions": map[string]interface{}{
+			"description": "Get active sessions for current user",
+			"headers": map[string]string{
+				"Authorization": "Bearer {access_token}",
+			},
+			"response": listResponse(
+				map[string]interface{}{
+					"id":         "session-id-1",
+					"created_at": "2023-01-01T12:00:00Z",
+					"expires_at": "2023-01-08T12:00:00Z",
+				},
+			),
+		},
 		"DELETE /api/users/me/sessions": map[string]interface{}{
 			"description": "Invalidate a specific session",
 			"headers": map[string]string{
@@ -699,6 +1220,66 @@ func (s *Server) GetAPIRoutes(w http.ResponseWriter, r *http.Request) {
 				},
 			},
 		},
+		"GET /api/users/me/quota": map[string]interface{}{
+			"description": "Get the detection quota of the current user",
+			"headers": map[string]string{
+				"Authorization": "Bearer {access_token}",
+			},
+			"response": map[string]interface{}{
+				"success": true,
+				"data": map[string]interface{}{
+					"user_id":           123,
+					"remaining":         60,
+					"capacity":          500,
+					"refill_seconds":    86400,
+					"warning_threshold": 80,
+				},
+			},
+		},
+		"GET /api/users/me/permissions": map[string]interface{}{
+			"description": "Get the role, scopes, capabilities, policies and features applying to the caller, so clients can show what it may do. Callers using an API key get the scopes of that key",
+			"headers": map[string]string{
+				"Authorization": "Bearer {access_token} or X-API-Key: {api_key}",
+			},
+			"response": map[string]interface{}{
+				"success": true,
+				"data": map[string]interface{}{
+					"user_id":      123,
+					"role":         "user",
+					"auth_method":  "session",
+					"scopes":       []string{"settings:read", "settings:write", "documents:read", "documents:write"},
+					"capabilities": []string{"view_detected_text", "view_email_addresses"},
+					"policies": map[string]interface{}{
+						"api_key_max_lifetime_days":   90,
+						"max_documents":               1000,
+						"max_pages":                   500,
+						"max_upload_bytes":            52428800,
+						"document_archive_after_days": 365,
+					},
+					"features": map[string]interface{}{
+						"oauth_google":     true,
+						"oauth_microsoft":  false,
+						"drive_google":     true,
+						"drive_microsoft":  false,
+						"document_archive": true,
+						"pii_screening":    true,
+					},
+				},
+			},
+		},
+		"POST /api/users/me/quota/consume": map[string]interface{}{
+			"description": "Atomically consume detection quota; returns 429 quota_exceeded when not enough is left. The user is emailed when the used share crosses a warning threshold (80% and 95% by default)",
+			"headers": map[string]string{
+				"Authorization": "Bearer {access_token}",
+				"Content-Type":  "application/json",
+			},
+			"body": map[string]interface{}{
+				"units": "integer - number of pages to process",
+			},
+			"response_headers": map[string]string{
+				"X-Quota-Remaining": "Detection quota tokens left after consuming",
+			},
+		},
 	}
 
 	// API Key routes
@@ -708,17 +1289,14 @@ func (s *Server) GetAPIRoutes(w http.ResponseWriter, r *http.Request) {
 			"headers": map[string]string{
 				"Authorization": "Bearer {access_token}",
 			},
-			"response": map[string]interface{}{
-				"success": true,
-				"data": []map[string]interface{}{
-					{
-						"id":         "key-id-1",
-						"name":       "My API Key",
-						"expires_at": "2023-12-31T23:59:59Z",
-						"created_at": "2023-01-01T12:00:00Z",
-					},
+			"response": listResponse(
+				map[string]interface{}{
+					"id":         "key-id-1",
+					"name":       "My API Key",
+					"expires_at": "2023-12-31T23:59:59Z",
+					"created_at": "2023-01-01T12:00:00Z",
 				},
-			},
+			),
 		},
 		"POST /api/keys": map[string]interface{}{
 			"description": "Create a new API key",
@@ -729,6 +1307,7 @@ func (s *Server) GetAPIRoutes(w http.ResponseWriter, r *http.Request) {
 			"body": map[string]interface{}{
 				"name":     "string - Name for the API key",
 				"duration": "string - Duration (e.g., '30d', '1y')",
+				"scopes":   "array - Optional scopes limiting the key: settings:read, settings:write, documents:read, documents:write, admin; a write scope includes the read scope. Keys without scopes have full access",
 			},
 			"response": map[string]interface{}{
 				"success": true,
@@ -738,6 +1317,28 @@ func (s *Server) GetAPIRoutes(w http.ResponseWriter, r *http.Request) {
 					"key":        "actual-api-key-value", // Only returned once on creation
 					"expires_at": "2023-12-31T23:59:59Z",
 					"created_at": "2023-01-01T12:00:00Z",
+					"scopes":     []string{"settings:read", "documents:write"},
+				},
+			},
+		},
+		"POST /api/keys/{keyID}/rotate": map[string]interface{}{
+			"description": "Issue a replacement API key; the old key keeps working until previous_key_expires_at",
+			"headers": map[string]string{
+				"Authorization": "Bearer {access_token}",
+			},
+			"path_params": map[string]string{
+				"keyID": "ID of the API key to rotate",
+			},
+			"response": map[string]interface{}{
+				"success": true,
+				"data": map[string]interface{}{
+					"id":                      "key-id-2",
+					"name":                    "My API Key",
+					"key":                     "actual-api-key-value", // Only returned once on rotation
+					"expires_at":              "2024-12-31T23:59:59Z",
+					"created_at":              "2024-01-01T12:00:00Z",
+					"rotated_from":            "key-id-1",
+					"previous_key_expires_at": "2024-01-02T12:00:00Z",
 				},
 			},
 		},
@@ -779,15 +1380,17 @@ func (s *Server) GetAPIRoutes(w http.ResponseWriter, r *http.Request) {
 			},
 		},
 		"PUT /api/settings": map[string]interface{}{
-			"description": "Update user settings",
+			"description": "Update user settings. With a version, the update is refused with 409 Conflict if the settings were changed since; the response data then holds the current and the submitted settings",
 			"headers": map[string]string{
 				"Authorization": "Bearer {access_token}",
 				"Content-Type":  "application/json",
 			},
 			"body": map[string]interface{}{
-				"remove_images":   "boolean (optional) - Whether to remove images",
-				"theme":           "string (optional) - Theme preference (system, light, dark)",
-				"auto_processing": "boolean (optional) - Whether to enable auto processing",
+				"remove_images":           "boolean (optional) - Whether to remove images",
+				"theme":                   "string (optional) - Theme preference (system, light, dark)",
+				"auto_processing":         "boolean (optional) - Whether to enable auto processing",
+				"document_retention_days": "integer (optional) - Days after upload documents and their detected entities are deleted, 0 to 3650; 0 keeps them",
+				"version":                 "integer (optional) - Version of the settings the update is based on",
 			},
 			"response": map[string]interface{}{
 				"success": true,
@@ -799,110 +1402,257 @@ func (s *Server) GetAPIRoutes(w http.ResponseWriter, r *http.Request) {
 					"auto_processing": false,
 					"created_at":      "2023-01-01T12:00:00Z",
 					"updated_at":      "2023-01-02T12:00:00Z",
+					"version":         4,
 				},
 			},
 		},
-		"GET /api/settings/ban-list": map[string]interface{}{
-			"description": "Get user's ban list",
+		"POST /api/settings/merge": map[string]interface{}{
+			"description": "Merge changes made to an older version of the settings. A field changed since the base version may only be changed to its current value; otherwise the merge is refused with 409 Conflict listing the conflicting_fields",
 			"headers": map[string]string{
 				"Authorization": "Bearer {access_token}",
+				"Content-Type":  "application/json",
+			},
+			"body": map[string]interface{}{
+				"base_version": "integer - Version of the settings the changes were made to",
+				"changes":      "object - Settings changed, as for PUT /api/settings",
 			},
 			"response": map[string]interface{}{
 				"success": true,
 				"data": map[string]interface{}{
-					"id":    1,
-					"words": []string{"word1", "word2", "word3"},
+					"setting_id": 1,
+					"user_id":    1,
+					"theme":      "dark",
+					"version":    5,
 				},
 			},
 		},
-		"POST /api/settings/ban-list/words": map[string]interface{}{
-			"description": "Add words to ban list",
+		"GET /api/settings/ban-list": map[string]interface{}{
+			"description": "Get user's ban list. The case_insensitive, diacritic_insensitive and whole_word options apply to all words; words matched other than exactly as written are listed in options with how they match; the detection configuration includes them as well",
 			"headers": map[string]string{
 				"Authorization": "Bearer {access_token}",
-				"Content-Type":  "application/json",
-			},
-			"body": map[string]interface{}{
-				"words": []string{"word4", "word5"},
 			},
 			"response": map[string]interface{}{
 				"success": true,
 				"data": map[string]interface{}{
-					"id":    1,
-					"words": []string{"word1", "word2", "word3", "word4", "word5"},
+					"id":                    1,
+					"case_insensitive":      true,
+					"diacritic_insensitive": false,
+					"whole_word":            false,
+					"words":                 []string{"Ström", "word2", "word3"},
+					"options": map[string]interface{}{
+						"Ström": map[string]interface{}{"case_insensitive": true, "diacritic_insensitive": true, "whole_word": true},
+					},
 				},
 			},
 		},
-		"DELETE /api/settings/ban-list/words": map[string]interface{}{
-			"description": "Remove words from ban list",
+		"PATCH /api/settings/ban-list": map[string]interface{}{
+			"description": "Set how all words on the ban list are matched, on top of the options of each word; omitted options are kept",
 			"headers": map[string]string{
 				"Authorization": "Bearer {access_token}",
 				"Content-Type":  "application/json",
 			},
 			"body": map[string]interface{}{
-				"words": []string{"word1", "word2"},
+				"case_insensitive":      true,
+				"diacritic_insensitive": "boolean (optional)",
+				"whole_word":            "boolean (optional)",
 			},
 			"response": map[string]interface{}{
 				"success": true,
 				"data": map[string]interface{}{
-					"id":    1,
-					"words": []string{"word3", "word4", "word5"},
+					"id":                    1,
+					"case_insensitive":      true,
+					"diacritic_insensitive": false,
+					"whole_word":            false,
+					"words":                 []string{"Ström", "word2", "word3"},
 				},
 			},
 		},
-		"GET /api/settings/patterns": map[string]interface{}{
-			"description": "Get user's search patterns",
+		"POST /api/settings/ban-list/words": map[string]interface{}{
+			"description": "Add words to ban list, optionally with how they are matched; words already on the list keep their options if options are omitted. Empty words are reported per item in \"batch\", with 207 Multi-Status if any failed",
 			"headers": map[string]string{
 				"Authorization": "Bearer {access_token}",
+				"Content-Type":  "application/json",
+			},
+			"body": map[string]interface{}{
+				"words":   []string{"word4", "word5"},
+				"options": "object (optional) - case_insensitive, diacritic_insensitive and whole_word booleans, and stemming \"en\" or \"no\" to match English or Norwegian inflections",
 			},
 			"response": map[string]interface{}{
 				"success": true,
-				"data": []map[string]interface{}{
-					{
-						"pattern_id":   1,
-						"setting_id":   1,
-						"pattern_type": "Normal",
-						"pattern_text": "pattern1",
-					},
-					{
-						"pattern_id":   2,
-						"setting_id":   1,
-						"pattern_type": "case_sensitive",
-						"pattern_text": "pattern2",
+				"data": map[string]interface{}{
+					"id":    1,
+					"words": []string{"word1", "word2", "word3", "word4", "word5"},
+				},
+				"batch": map[string]interface{}{
+					"succeeded": 2,
+					"failed":    0,
+					"items": []map[string]interface{}{
+						{"index": 0, "key": "word4", "status": 200},
+						{"index": 1, "key": "word5", "status": 200},
 					},
 				},
 			},
 		},
-		"POST /api/settings/patterns": map[string]interface{}{
-			"description": "Create a new search pattern",
+		"DELETE /api/settings/ban-list/words": map[string]interface{}{
+			"description": "Remove words from ban list. Words that are empty or not on the list are reported per item in \"batch\", with 207 Multi-Status if any failed",
 			"headers": map[string]string{
 				"Authorization": "Bearer {access_token}",
 				"Content-Type":  "application/json",
 			},
 			"body": map[string]interface{}{
-				"pattern_type": "string - Type of pattern (ai_search, Normal, case_sensitive)",
-				"pattern_text": "string - The pattern text",
+				"words": []string{"word1", "word9"},
 			},
 			"response": map[string]interface{}{
 				"success": true,
 				"data": map[string]interface{}{
-					"pattern_id":   3,
-					"setting_id":   1,
-					"pattern_type": "ai_search",
-					"pattern_text": "new pattern",
+					"id":    1,
+					"words": []string{"word2", "word3", "word4", "word5"},
+				},
+				"batch": map[string]interface{}{
+					"succeeded": 1,
+					"failed":    1,
+					"items": []map[string]interface{}{
+						{"index": 0, "key": "word1", "status": 200},
+						{"index": 1, "key": "word9", "status": 404, "error": map[string]interface{}{"code": "not_found", "message": "Word is not on the ban list"}},
+					},
 				},
 			},
 		},
-		"PUT /api/settings/patterns/{patternID}": map[string]interface{}{
-			"description": "Update a search pattern",
+		"PATCH /api/settings/ban-list/words": map[string]interface{}{
+			"description": "Set how words on the ban list are matched, e.g. so that \"Strom\" matches \"Ström\"; words not on the list are ignored",
 			"headers": map[string]string{
 				"Authorization": "Bearer {access_token}",
 				"Content-Type":  "application/json",
 			},
-			"path_params": map[string]string{
-				"patternID": "ID of the pattern to update",
-			},
 			"body": map[string]interface{}{
-				"pattern_type": "string (optional) - Type of pattern",
+				"words": []string{"Ström"},
+				"options": map[string]interface{}{
+					"case_insensitive":      true,
+					"diacritic_insensitive": true,
+					"whole_word":            true,
+					"stemming":              "no",
+				},
+			},
+			"response": map[string]interface{}{
+				"success": true,
+				"data": map[string]interface{}{
+					"id":    1,
+					"words": []string{"Ström"},
+					"options": map[string]interface{}{
+						"Ström": map[string]interface{}{"case_insensitive": true, "diacritic_insensitive": true, "whole_word": true, "stemming": "no"},
+					},
+				},
+			},
+		},
+		"GET /api/settings/patterns": map[string]interface{}{
+			"description": "Get user's search patterns",
+			"headers": map[string]string{
+				"Authorization": "Bearer {access_token}",
+			},
+			"response": listResponse(
+				map[string]interface{}{
+					"pattern_id":   1,
+					"setting_id":   1,
+					"pattern_type": "Normal",
+					"pattern_text": "pattern1",
+				},
+				map[string]interface{}{
+					"pattern_id":   2,
+					"setting_id":   1,
+					"pattern_type": "case_sensitive",
+					"pattern_text": "pattern2",
+				},
+			),
+		},
+		"POST /api/settings/patterns": map[string]interface{}{
+			"description": "Create a new search pattern",
+			"headers": map[string]string{
+				"Authorization": "Bearer {access_token}",
+				"Content-Type":  "application/json",
+			},
+			"body": map[string]interface{}{
+				"pattern_type": "string - Type of pattern (ai_search, Normal, case_sensitive)",
+				"pattern_text": "string - The pattern text",
+			},
+			"response": map[string]interface{}{
+				"success": true,
+				"data": map[string]interface{}{
+					"pattern_id":   3,
+					"setting_id":   1,
+					"pattern_type": "ai_search",
+					"pattern_text": "new pattern",
+				},
+			},
+		},
+		"POST /api/settings/patterns/test": map[string]interface{}{
+			"description": "Run a search pattern against sample text without saving it. Normal patterns ignore case; AI search patterns cannot be tested. Offsets are in characters and at most 100 matches are returned",
+			"headers": map[string]string{
+				"Authorization": "Bearer {access_token}",
+				"Content-Type":  "application/json",
+			},
+			"body": map[string]interface{}{
+				"pattern_type": "string - Type of pattern (normal, case_sensitive)",
+				"pattern_text": "string - The regular expression to try",
+				"sample_text":  "string - The text to run it against, at most 10000 characters",
+			},
+			"response": map[string]interface{}{
+				"success": true,
+				"data": map[string]interface{}{
+					"matches": []interface{}{
+						map[string]interface{}{"text": "12345678901", "start": 14, "end": 25},
+					},
+					"truncated": false,
+				},
+			},
+		},
+		"GET /api/settings/patterns/catalog": map[string]interface{}{
+			"description": "Get the catalog of search patterns for common kinds of personal data, such as Norwegian fødselsnummer, IBANs, US Social Security numbers and phone numbers",
+			"headers": map[string]string{
+				"Authorization": "Bearer {access_token}",
+			},
+			"response": listResponse(
+				map[string]interface{}{
+					"key":          "no_fodselsnummer",
+					"name":         "Norwegian national identity number",
+					"description":  "Fødselsnummer and D-number: 11 digits, optionally with a space after the date of birth",
+					"region":       "NO",
+					"pattern_type": "case_sensitive",
+					"pattern_text": `\b[0-7]\d[0-1]\d{3} ?\d{5}\b`,
+				},
+			),
+		},
+		"POST /api/settings/patterns/import-catalog": map[string]interface{}{
+			"description": "Copy catalog patterns into the user's search patterns. Patterns the user already has are not copied again and are reported with status 200; unknown keys are reported with status 404 in a 207 Multi-Status response",
+			"headers": map[string]string{
+				"Authorization": "Bearer {access_token}",
+				"Content-Type":  "application/json",
+			},
+			"body": map[string]interface{}{
+				"keys": "array of strings - Keys of the catalog patterns to copy, at most 50",
+			},
+			"response": map[string]interface{}{
+				"success": true,
+				"data": []interface{}{
+					map[string]interface{}{"id": 7, "setting_id": 1, "pattern_type": "case_sensitive", "pattern_text": `\b\d{3}-\d{2}-\d{4}\b`},
+				},
+				"batch": map[string]interface{}{
+					"succeeded": 1,
+					"failed":    0,
+					"items":     []interface{}{map[string]interface{}{"index": 0, "key": "us_ssn", "id": 7, "status": 201}},
+				},
+			},
+		},
+		"PUT /api/settings/patterns/{patternID}": map[string]interface{}{
+			"description": "Update a search pattern",
+			"headers": map[string]string{
+				"Authorization": "Bearer {access_token}",
+				"Content-Type":  "application/json",
+			},
+			"path_params": map[string]string{
+				"patternID": "ID of the pattern to update",
+			},
+			"body": map[string]interface{}{
+				"pattern_type": "string (optional) - Type of pattern",
 				"pattern_text": "string (optional) - The pattern text",
 			},
 			"response": map[string]interface{}{
@@ -929,27 +1679,151 @@ func (s *Server) GetAPIRoutes(w http.ResponseWriter, r *http.Request) {
 				"no_content":  true,
 			},
 		},
-		"GET /api/settings/entities/{methodID}": map[string]interface{}{
-			"description": "Get model entities for a specific method",
+		"GET /api/settings/classification-rules": map[string]interface{}{
+			"description": "Get the rules that tag, file and set the retention of uploaded documents, in the order they are applied",
+			"headers": map[string]string{
+				"Authorization": "Bearer {access_token}",
+			},
+			"response": listResponse(
+				map[string]interface{}{
+					"id":               5,
+					"setting_id":       1,
+					"name":             "Invoices",
+					"priority":         10,
+					"filename_pattern": "invoice*.pdf",
+					"source":           "",
+					"entity_types":     []string{},
+					"min_entities":     0,
+					"tags":             []string{"finance"},
+					"folder":           "Invoices",
+					"retention_days":   365,
+				},
+			),
+		},
+		"POST /api/settings/classification-rules": map[string]interface{}{
+			"description": "Create a classification rule; it needs at least one condition and at least one of tags, folder or retention_days",
+			"headers": map[string]string{
+				"Authorization": "Bearer {access_token}",
+				"Content-Type":  "application/json",
+			},
+			"body": map[string]interface{}{
+				"name":             "string - Name of the rule",
+				"priority":         "integer (optional) - Rules with a lower priority are applied first",
+				"filename_pattern": "string (optional) - Case-insensitive glob the filename must match, such as invoice*.pdf",
+				"source":           "string (optional) - Source the document must come from, such as scanner",
+				"entity_types":     "array of strings (optional) - Entity types that must all be detected",
+				"min_entities":     "integer (optional) - Minimum number of detected entities",
+				"tags":             "array of strings (optional) - Tags added to matching documents",
+				"folder":           "string (optional) - Folder matching documents are filed in",
+				"retention_days":   "integer (optional) - Days after upload matching documents are deleted",
+			},
+			"response": map[string]interface{}{
+				"success": true,
+				"data": map[string]interface{}{
+					"id":               5,
+					"setting_id":       1,
+					"name":             "Invoices",
+					"priority":         10,
+					"filename_pattern": "invoice*.pdf",
+					"source":           "",
+					"entity_types":     []string{},
+					"min_entities":     0,
+					"tags":             []string{"finance"},
+					"folder":           "Invoices",
+					"retention_days":   365,
+				},
+			},
+		},
+		"PUT /api/settings/classification-rules/{ruleID}": map[string]interface{}{
+			"description": "Replace a classification rule; documents classified before keep their tags, folder and retention",
 			"headers": map[string]string{
 				"Authorization": "Bearer {access_token}",
+				"Content-Type":  "application/json",
 			},
 			"path_params": map[string]string{
-				"methodID": "ID of the detection method",
+				"ruleID": "ID of the rule to update",
+			},
+			"body": map[string]interface{}{
+				"name":             "string - Name of the rule",
+				"priority":         "integer (optional) - Rules with a lower priority are applied first",
+				"filename_pattern": "string (optional) - Case-insensitive glob the filename must match, such as invoice*.pdf",
+				"source":           "string (optional) - Source the document must come from, such as scanner",
+				"entity_types":     "array of strings (optional) - Entity types that must all be detected",
+				"min_entities":     "integer (optional) - Minimum number of detected entities",
+				"tags":             "array of strings (optional) - Tags added to matching documents",
+				"folder":           "string (optional) - Folder matching documents are filed in",
+				"retention_days":   "integer (optional) - Days after upload matching documents are deleted",
 			},
 			"response": map[string]interface{}{
 				"success": true,
-				"data": []map[string]interface{}{
-					{
-						"id":          1,
-						"setting_id":  1,
-						"method_id":   1,
-						"entity_text": "Entity 1",
-						"method_name": "Method Name",
-					},
+				"data": map[string]interface{}{
+					"id":               5,
+					"setting_id":       1,
+					"name":             "Invoices",
+					"priority":         10,
+					"filename_pattern": "invoice*.pdf",
+					"source":           "",
+					"entity_types":     []string{},
+					"min_entities":     0,
+					"tags":             []string{"finance"},
+					"folder":           "Invoices",
+					"retention_days":   365,
 				},
 			},
 		},
+		"DELETE /api/settings/classification-rules/{ruleID}": map[string]interface{}{
+			"description": "Delete a classification rule",
+			"headers": map[string]string{
+				"Authorization": "Bearer {access_token}",
+			},
+			"path_params": map[string]string{
+				"ruleID": "ID of the rule to delete",
+			},
+			"response": map[string]interface{}{
+				"success":     true,
+				"status_code": 204,
+				"no_content":  true,
+			},
+		},
+		"GET /api/settings/detection-config": map[string]interface{}{
+			"description": "Get the general settings, ban list, search patterns and model entities used for detection in one response. Cached per user; concurrent requests share one database read and settings changes invalidate the cache",
+			"headers": map[string]string{
+				"Authorization": "Bearer {access_token}",
+			},
+			"response_headers": map[string]string{
+				"X-Cache": "hit when served from the cache, otherwise miss",
+			},
+		},
+		"GET /api/settings/export": map[string]interface{}{
+			"description": "Download all settings as a JSON file, or as an XML file with Accept: application/xml (see /api/schemas/xml/settings_export)",
+			"headers": map[string]string{
+				"Authorization": "Bearer {access_token}",
+				"Accept":        "application/xml (optional) - export as XML",
+			},
+			"response": map[string]interface{}{
+				"content_type": "application/json or application/xml",
+				"filename":     "{username}_settings.json or {username}_settings.xml",
+			},
+		},
+		"GET /api/settings/entities/{methodID}": map[string]interface{}{
+			"description": "Get model entities for a specific method",
+			"headers": map[string]string{
+				"Authorization": "Bearer {access_token}",
+				"Accept":        "application/x-ndjson (optional) - stream one entity per line; application/xml (optional) - render as XML, see /api/schemas/xml/model_entities",
+			},
+			"path_params": map[string]string{
+				"methodID": "ID of the detection method",
+			},
+			"response": listResponse(
+				map[string]interface{}{
+					"id":          1,
+					"setting_id":  1,
+					"method_id":   1,
+					"entity_text": "Entity 1",
+					"method_name": "Method Name",
+				},
+			),
+		},
 		"POST /api/settings/entities": map[string]interface{}{
 			"description": "Add model entities",
 			"headers": map[string]string{
@@ -978,6 +1852,34 @@ func (s *Server) GetAPIRoutes(w http.ResponseWriter, r *http.Request) {
 				},
 			},
 		},
+		"PUT /api/settings/entities/{methodID}": map[string]interface{}{
+			"description": "Replace all model entities of a detection method in one transaction. Entities whose text is on both lists keep their IDs; an empty list removes all entities of the method",
+			"headers": map[string]string{
+				"Authorization": "Bearer {access_token}",
+				"Content-Type":  "application/json",
+			},
+			"path_params": map[string]string{
+				"methodID": "ID of the detection method",
+			},
+			"body": map[string]interface{}{
+				"entity_texts": []string{"Entity 1", "Entity 4"},
+			},
+			"response": map[string]interface{}{
+				"success": true,
+				"data": map[string]interface{}{
+					"entities": []map[string]interface{}{
+						{"id": 1, "setting_id": 1, "method_id": 1, "entity_text": "Entity 1"},
+						{"id": 4, "setting_id": 1, "method_id": 1, "entity_text": "Entity 4"},
+					},
+					"created": []map[string]interface{}{
+						{"id": 4, "setting_id": 1, "method_id": 1, "entity_text": "Entity 4"},
+					},
+					"deleted": []map[string]interface{}{
+						{"id": 2, "setting_id": 1, "method_id": 1, "entity_text": "Entity 2"},
+					},
+				},
+			},
+		},
 		"DELETE /api/settings/entities/{entityID}": map[string]interface{}{
 			"description": "Delete a model entity",
 			"headers": map[string]string{
@@ -992,6 +1894,77 @@ func (s *Server) GetAPIRoutes(w http.ResponseWriter, r *http.Request) {
 				"no_content":  true,
 			},
 		},
+		"GET /api/settings/changes": map[string]interface{}{
+			"description": "Get settings changes after a revision, optionally waiting for new ones",
+			"headers": map[string]string{
+				"Authorization": "Bearer {access_token}",
+			},
+			"query_params": map[string]string{
+				"since": "Last revision the client has applied (default: 0)",
+				"wait":  "Seconds to wait for new changes (default: 0, max: 30)",
+			},
+			"response": map[string]interface{}{
+				"success": true,
+				"data": map[string]interface{}{
+					"since":           10,
+					"latest_revision": 11,
+					"has_more":        false,
+					"changes": []map[string]interface{}{
+						{
+							"revision":      11,
+							"resource_type": "ban_list_word",
+							"resource_id":   1,
+							"action":        "created",
+							"data":          map[string]interface{}{"word": "word4"},
+							"created_at":    "2023-01-02T12:00:00Z",
+						},
+					},
+				},
+			},
+		},
+		"GET /api/settings/effectiveness": map[string]interface{}{
+			"description": "Rank ban list words and search patterns by the detections they produced in saved documents, and suggest rules without a detection in stale_after_days for removal; AI search patterns are not tracked",
+			"headers": map[string]string{
+				"Authorization": "Bearer {access_token}",
+			},
+			"response": map[string]interface{}{
+				"success": true,
+				"data": map[string]interface{}{
+					"stale_after_days": 90,
+					"rules": []map[string]interface{}{
+						{
+							"rule_type":     "search_pattern",
+							"text":          "Nordmann",
+							"pattern_id":    1,
+							"pattern_type":  "normal",
+							"tracked":       true,
+							"hit_count":     42,
+							"last_hit_at":   "2023-01-02T12:00:00Z",
+							"tracked_since": "2022-10-01T09:00:00Z",
+							"stale":         false,
+						},
+						{
+							"rule_type":     "ban_list_word",
+							"text":          "word1",
+							"tracked":       true,
+							"hit_count":     0,
+							"tracked_since": "2022-10-01T09:00:00Z",
+							"stale":         true,
+						},
+					},
+					"suggestions": []map[string]interface{}{
+						{
+							"rule_type":     "ban_list_word",
+							"text":          "word1",
+							"tracked":       true,
+							"hit_count":     0,
+							"tracked_since": "2022-10-01T09:00:00Z",
+							"stale":         true,
+						},
+					},
+				},
+			},
+		},
 	}
 
 	// System routes
@@ -1006,6 +1979,36 @@ func (s *Server) GetAPIRoutes(w http.ResponseWriter, r *http.Request) {
 				},
 			},
 		},
+		"GET /livez": map[string]interface{}{
+			"description": "Liveness probe; answered from process start, whatever the state of the dependencies",
+			"response": map[string]interface{}{
+				"success": true,
+				"data": map[string]interface{}{
+					"status": "alive",
+					"phase":  "migrating",
+				},
+			},
+		},
+		"GET /startupz": map[string]interface{}{
+			"description": "Startup probe; 503 until the database is connected and migrations have run",
+			"response": map[string]interface{}{
+				"success": true,
+				"data": map[string]interface{}{
+					"status": "started",
+					"phase":  "ready",
+				},
+			},
+		},
+		"GET /readyz": map[string]interface{}{
+			"description": "Readiness probe; 503 while starting or stopping, or if the database or Redis is unreachable",
+			"response": map[string]interface{}{
+				"success": true,
+				"data": map[string]interface{}{
+					"status": "ready",
+					"phase":  "ready",
+				},
+			},
+		},
 		"GET /version": map[string]interface{}{
 			"description": "Get application version",
 			"response": map[string]interface{}{
@@ -1023,6 +2026,44 @@ func (s *Server) GetAPIRoutes(w http.ResponseWriter, r *http.Request) {
 				"data":    "This document you're viewing right now",
 			},
 		},
+		"GET /api/schemas/xml/{schema}": map[string]interface{}{
+			"description": "Get the XSD of an endpoint's XML rendering",
+			"path_params": map[string]string{
+				"schema": "Schema name: documents, model_entities or settings_export",
+			},
+			"response": map[string]interface{}{
+				"content_type": "application/xml",
+				"body":         "XSD document",
+			},
+		},
+		"GET /api/status": map[string]interface{}{
+			"description": "Get the state and uptime of each component and the unresolved incidents for a public status page (cached)",
+			"response": map[string]interface{}{
+				"success": true,
+				"data": map[string]interface{}{
+					"status": "degraded",
+					"components": []map[string]interface{}{
+						{
+							"name":   "database",
+							"status": "degraded",
+							"uptime": map[string]interface{}{"24h": 99.86, "7d": 99.97, "30d": 99.99},
+						},
+					},
+					"incidents": []map[string]interface{}{
+						{
+							"id":         1,
+							"title":      "Slow document uploads",
+							"message":    "We have identified the cause and are deploying a fix",
+							"impact":     "major",
+							"status":     "identified",
+							"created_at": "2023-01-01T12:00:00Z",
+							"updated_at": "2023-01-01T12:30:00Z",
+						},
+					},
+					"checked_at": "2023-01-01T12:45:00Z",
+				},
+			},
+		},
 	}
 
 	// Document routes
@@ -1031,35 +2072,72 @@ func (s *Server) GetAPIRoutes(w http.ResponseWriter, r *http.Request) {
 			"description": "List all documents for the current user (paginated)",
 			"headers": map[string]string{
 				"Authorization": "Bearer {access_token}",
+				"Accept":        "application/x-ndjson (optional) - stream all documents one per line, ignoring pagination; application/xml (optional) - render the page as XML, see /api/schemas/xml/documents",
 			},
 			"query_params": map[string]string{
-				"page":     "Page number (optional, default 1)",
-				"pageSize": "Page size (optional, default 10)",
-			},
-			"response": map[string]interface{}{
-				"success": true,
-				"data": []map[string]interface{}{
-					{
-						"id":               1,
-						"hashed_name":      "document.pdf",
-						"upload_timestamp": "2025-05-10T21:09:03.46195Z",
-						"last_modified":    "2025-05-10T21:09:03.46195Z",
-						"entity_count":     0,
-					},
+				"page":       "Page number (optional, default 1)",
+				"cursor":     "page_info.next_cursor or prev_cursor of an earlier page (optional, instead of page)",
+				"pageSize":   "Page size (optional, default 10)",
+				"pagination": "keyset (optional) - page by keyset cursors instead of page numbers: fast for any number of documents, with only a next_cursor and a null total",
+				"language":   "ISO 639-1 language code (optional) - only list documents in this language, e.g. nb",
+			},
+			"response": listResponse(
+				map[string]interface{}{
+					"id":               1,
+					"hashed_name":      "document.pdf",
+					"upload_timestamp": "2025-05-10T21:09:03.46195Z",
+					"last_modified":    "2025-05-10T21:09:03.46195Z",
+					"entity_count":     0,
+					"language":         "nb",
 				},
-				"total_count": 42,
+			),
+		},
+		"GET /api/documents/search": map[string]interface{}{
+			"description": "Search the current user's documents, newest first (paginated); documents match when they match all given filters",
+			"headers": map[string]string{
+				"Authorization": "Bearer {access_token}",
 			},
+			"query_params": map[string]string{
+				"page":          "Page number (optional, default 1)",
+				"cursor":        "page_info.next_cursor or prev_cursor of an earlier page (optional, instead of page)",
+				"pageSize":      "Page size (optional, default 10)",
+				"name":          "string (optional) - Part of the document name, case-insensitive",
+				"uploaded_from": "RFC 3339 timestamp or date (optional) - Earliest upload, e.g. 2026-01-01",
+				"uploaded_to":   "RFC 3339 timestamp or date (optional) - Latest upload; a date includes the whole day",
+				"entity_types":  "Comma-separated entity types (optional, at most 20) - Only documents in which all were detected, e.g. PERSON,EMAIL_ADDRESS",
+				"min_entities":  "integer (optional) - Minimum number of detected entities",
+				"max_entities":  "integer (optional) - Maximum number of detected entities",
+			},
+			"response": listResponse(
+				map[string]interface{}{
+					"id":               1,
+					"hashed_name":      "contract.pdf",
+					"upload_timestamp": "2026-01-10T21:09:03.46195Z",
+					"last_modified":    "2026-01-10T21:09:03.46195Z",
+					"entity_count":     4,
+					"language":         "nb",
+				},
+			),
 		},
 		"POST /api/documents": map[string]interface{}{
-			"description": "Upload a new document (expects a file, metadata, and redaction schema)",
+			"description": "Upload a new document (expects a file, metadata, and redaction schema). Uploads over the size (413), page (413) or stored document (403) limit are refused before processing with upload_limit_exceeded and details naming the limit, the current usage and the maximum",
 			"headers": map[string]string{
-				"Authorization": "Bearer {access_token}",
-				"Content-Type":  "multipart/form-data",
+				"Authorization":    "Bearer {access_token}",
+				"Content-Type":     "multipart/form-data",
+				"Content-Language": "Document language (optional) - used when the body has no language",
 			},
 			"body": map[string]interface{}{
-				"file":             "The document file to upload",
-				"metadata":         "Optional JSON metadata (e.g., original filename)",
-				"redaction_schema": "JSON object representing the redaction schema",
+				"file":             "The PDF file (multipart only, optional) - stored encrypted with the tenant's key and served by GET /api/documents/{id}/content; the upload is refused with 400 if it is not a PDF. The filename defaults to the file's name",
+				"metadata":         "Optional JSON object with the document fields of a JSON upload (multipart only); form fields of the same name override it",
+				"redaction_schema": "JSON object representing the redaction schema; bounding boxes are page-relative (0-1) with \"version\": 2, or absolute points with the page \"width\" and \"height\" for older clients",
+				"language":         "ISO 639-1 language code (optional) - detected from the filename and detected texts if omitted",
+				"source":           "string (optional) - Where the document came from, such as scanner or email; matched by classification rules",
+				"processing_mode":  "persistent (default) or ephemeral - ephemeral returns the processed document with status 200 and an audit_id instead of an id, storing nothing but an audit stub with the page and entity counts",
+				"sha256":           "Hex-encoded SHA-256 of the file (required with a file) - a file that doesn't match is refused with 422 upload_integrity_failed",
+				"size_bytes":       "Size of the file in bytes (required with a file) - a file of another size is refused with 422 upload_integrity_failed",
+			},
+			"response_headers": map[string]string{
+				"X-Quota-Remaining": "Detection quota tokens left",
 			},
 			"response": map[string]interface{}{
 				"success": true,
@@ -1068,11 +2146,16 @@ func (s *Server) GetAPIRoutes(w http.ResponseWriter, r *http.Request) {
 					"hashed_name":      "document.pdf",
 					"upload_timestamp": "2025-05-10T21:09:03.46195Z",
 					"last_modified":    "2025-05-10T21:09:03.46195Z",
+					"language":         "nb",
+					"tags":             []string{"finance"},
+					"folder":           "Invoices",
+					"source":           "scanner",
+					"retain_until":     "2026-05-10T21:09:03.46195Z",
 				},
 			},
 		},
 		"GET /api/documents/{id}": map[string]interface{}{
-			"description": "Get a document's metadata by ID",
+			"description": "Get a document's metadata by ID; documents shared with the user are returned as well",
 			"headers": map[string]string{
 				"Authorization": "Bearer {access_token}",
 			},
@@ -1089,39 +2172,1663 @@ func (s *Server) GetAPIRoutes(w http.ResponseWriter, r *http.Request) {
 				},
 			},
 		},
-		"DELETE /api/documents/{id}": map[string]interface{}{
-			"description": "Delete a document by ID",
+		"GET /api/documents/{id}/content": map[string]interface{}{
+			"description": "Download the PDF uploaded with a document, decrypted. Supports Range and If-None-Match requests; 404 if the document was uploaded without a file",
 			"headers": map[string]string{
 				"Authorization": "Bearer {access_token}",
+				"Range":         "bytes={start}-{end} (optional) - part of the PDF, answered with 206",
 			},
 			"path_params": map[string]string{
 				"id": "ID of the document",
 			},
-			"response": map[string]interface{}{
-				"success":     true,
-				"status_code": 204,
-				"no_content":  true,
+			"response_headers": map[string]string{
+				"Content-Type":        "application/pdf",
+				"Content-Disposition": "inline; filename=\"hideme-document-{id}.pdf\"",
+				"ETag":                "SHA-256 of the PDF",
 			},
+			"response": "The PDF",
 		},
-		"GET /api/documents/{id}/summary": map[string]interface{}{
-			"description": "Get a summary of a document (with entity count, etc.)",
+		"GET /api/documents/{id}/download": map[string]interface{}{
+			"description": "Download the PDF uploaded with a document as an attachment. The PDF is decrypted as it is streamed from storage; a Range request reads only the part asked for, and If-Range resumes an interrupted download if the PDF is unchanged. HEAD returns the headers only. Each download is logged",
 			"headers": map[string]string{
 				"Authorization": "Bearer {access_token}",
+				"Range":         "bytes={start}-{end} (optional) - part of the PDF, answered with 206",
+				"If-Range":      "ETag of an earlier response (optional) - the range is returned only if the PDF still matches it",
 			},
 			"path_params": map[string]string{
 				"id": "ID of the document",
 			},
-			"response": map[string]interface{}{
-				"success": true,
-				"data": map[string]interface{}{
-					"id":               1,
-					"hashed_name":      "encrypted-filename-string",
-					"upload_timestamp": "2023-01-01T12:00:00Z",
-					"last_modified":    "2023-01-01T12:00:00Z",
-					"entity_count":     5,
-				},
+			"response_headers": map[string]string{
+				"Content-Type":        "application/pdf",
+				"Content-Disposition": "attachment; filename=\"hideme-document-{id}.pdf\"",
+				"Accept-Ranges":       "bytes",
+				"ETag":                "SHA-256 of the PDF",
 			},
+			"response": "The PDF",
 		},
+		"HEAD /api/documents/{id}/download": map[string]interface{}{
+			"description": "The headers of GET /api/documents/{id}/download without the PDF, e.g. to learn its size and ETag before a ranged download",
+			"headers": map[string]string{
+				"Authorization": "Bearer {access_token}",
+			},
+			"path_params": map[string]string{
+				"id": "ID of the document",
+			},
+		},
+		"DELETE /api/documents/{id}": map[string]interface{}{
+			"description": "Delete a document by ID; only the owner can delete a document",
+			"headers": map[string]string{
+				"Authorization": "Bearer {access_token}",
+			},
+			"path_params": map[string]string{
+				"id": "ID of the document",
+			},
+			"response": map[string]interface{}{
+				"success":     true,
+				"status_code": 204,
+				"no_content":  true,
+			},
+		},
+		"PUT /api/documents/{id}/redaction-schema": map[string]interface{}{
+			"description": "Replace the redaction schema of a document the user owns or that was shared with them for redaction; the replaced schema is kept as a version. 403 with read access only",
+			"headers": map[string]string{
+				"Authorization": "Bearer {access_token}",
+				"Content-Type":  "application/json",
+			},
+			"path_params": map[string]string{
+				"id": "ID of the document",
+			},
+			"body": map[string]interface{}{
+				"redaction_schema": "JSON object representing the new redaction schema, in the format of an upload",
+			},
+			"response": map[string]interface{}{
+				"success": true,
+				"data": map[string]interface{}{
+					"id":               1,
+					"hashed_name":      "document.pdf",
+					"last_modified":    "2025-05-10T21:09:03.46195Z",
+					"redaction_schema": "{\"version\":2,\"pages\":[...]}",
+				},
+			},
+		},
+		"GET /api/documents/{id}/redaction-schema/versions": map[string]interface{}{
+			"description": "List the redaction schemas a document had before each save, newest first, without the schemas themselves",
+			"headers": map[string]string{
+				"Authorization": "Bearer {access_token}",
+			},
+			"path_params": map[string]string{
+				"id": "ID of the document",
+			},
+			"response": listResponse(
+				map[string]interface{}{
+					"document_id":  1,
+					"version":      2,
+					"entity_count": 3,
+					"entity_types": []string{"EMAIL_ADDRESS", "PERSON"},
+					"replaced_by":  7,
+					"replaced_at":  "2025-05-10T21:09:03Z",
+				},
+			),
+		},
+		"POST /api/documents/{id}/redaction-schema/versions/{version}/rollback": map[string]interface{}{
+			"description": "Restore an earlier redaction schema of a document the user owns or that was shared with them for redaction; the schema it replaces is kept as a new version. 403 with read access only",
+			"headers": map[string]string{
+				"Authorization": "Bearer {access_token}",
+			},
+			"path_params": map[string]string{
+				"id":      "ID of the document",
+				"version": "Number of the version to restore",
+			},
+			"response": map[string]interface{}{
+				"success": true,
+				"data": map[string]interface{}{
+					"id":               1,
+					"hashed_name":      "document.pdf",
+					"last_modified":    "2025-05-10T21:12:44.10233Z",
+					"redaction_schema": "{\"version\":2,\"pages\":[...]}",
+				},
+			},
+		},
+		"POST /api/documents/{id}/entities/batch": map[string]interface{}{
+			"description": "Add up to 1000 detected entities to a document the user owns or that was shared with them for redaction. The valid entities are added in one transaction: either all of them are added or none. Invalid entities are reported per item in \"batch\", with 207 Multi-Status if any failed. 400 for an unknown detection method, 403 with read access only",
+			"headers": map[string]string{
+				"Authorization": "Bearer {access_token}",
+				"Content-Type":  "application/json",
+			},
+			"path_params": map[string]string{
+				"id": "ID of the document",
+			},
+			"body": map[string]interface{}{
+				"entities": []map[string]interface{}{
+					{
+						"method_id":          1,
+						"entity_name":        "Ola Nordmann",
+						"redaction_schema":   map[string]interface{}{"page": 1, "start_x": 0.1, "start_y": 0.2, "end_x": 0.3, "end_y": 0.25, "redaction_method": "blackout"},
+						"detected_timestamp": "Optional time of the detection, the time of the request if omitted",
+					},
+				},
+			},
+			"response": map[string]interface{}{
+				"success": true,
+				"data": map[string]interface{}{
+					"document_id": 1,
+					"entity_ids":  []int64{101, 102},
+				},
+				"batch": map[string]interface{}{
+					"succeeded": 2,
+					"failed":    1,
+					"items": []map[string]interface{}{
+						{"index": 0, "id": 101, "status": 201},
+						{"index": 1, "status": 400, "error": map[string]interface{}{"code": "validation_error", "message": "This field is required", "details": map[string]string{"method_id": "This field is required"}}},
+						{"index": 2, "id": 102, "status": 201},
+					},
+				},
+			},
+		},
+		"GET /api/documents/{id}/entities": map[string]interface{}{
+			"description": "List the detected entities of a document the user owns or that was shared with them, newest first, with their review status",
+			"headers": map[string]string{
+				"Authorization": "Bearer {access_token}",
+			},
+			"path_params": map[string]string{
+				"id": "ID of the document",
+			},
+			"query_params": map[string]string{
+				"status":     "Optional review status to filter by: pending, accepted or rejected",
+				"method_id":  "Optional ID of the detection method to filter by",
+				"pagination": "keyset (optional) - page the entities by keyset cursors instead of listing all of them",
+				"cursor":     "page_info.next_cursor of an earlier keyset page (optional)",
+				"page_size":  "Entities per keyset page (optional, default 10)",
+			},
+			"response": listResponse(
+				map[string]interface{}{
+					"id":                 101,
+					"document_id":        1,
+					"method_id":          1,
+					"entity_name":        "Ola Nordmann",
+					"redaction_schema":   map[string]interface{}{"page": 1, "start_x": 0.1, "start_y": 0.2, "end_x": 0.3, "end_y": 0.25, "redaction_method": "blackout"},
+					"detected_timestamp": "2025-05-10T21:12:44Z",
+					"status":             "accepted",
+					"reviewed_by":        1,
+					"reviewed_at":        "2025-05-11T08:30:00Z",
+					"method_name":        "Presidio",
+					"highlight_color":    "#FF0000",
+				},
+			),
+		},
+		"PATCH /api/documents/{id}/entities/{entityID}": map[string]interface{}{
+			"description": "Accept a detected entity, reject it as a false positive or set it back to pending. Rejected entities are not redacted. 403 with read access only",
+			"headers": map[string]string{
+				"Authorization": "Bearer {access_token}",
+				"Content-Type":  "application/json",
+			},
+			"path_params": map[string]string{
+				"id":       "ID of the document",
+				"entityID": "ID of the detected entity",
+			},
+			"body": map[string]interface{}{
+				"status": "pending, accepted or rejected",
+			},
+			"response": map[string]interface{}{
+				"success": true,
+				"data": map[string]interface{}{
+					"document_id": 1,
+					"entity_ids":  []int64{101},
+					"status":      "rejected",
+					"reviewed_by": 1,
+					"reviewed_at": "2025-05-11T08:30:00Z",
+				},
+			},
+		},
+		"PATCH /api/documents/{id}/entities": map[string]interface{}{
+			"description": "Set the review status of up to 1000 detected entities of a document at once: either all of them are changed or none. 404 if an entity belongs to another document",
+			"headers": map[string]string{
+				"Authorization": "Bearer {access_token}",
+				"Content-Type":  "application/json",
+			},
+			"path_params": map[string]string{
+				"id": "ID of the document",
+			},
+			"body": map[string]interface{}{
+				"entity_ids": []int64{101, 102},
+				"status":     "pending, accepted or rejected",
+			},
+			"response": map[string]interface{}{
+				"success": true,
+				"data": map[string]interface{}{
+					"document_id": 1,
+					"entity_ids":  []int64{101, 102},
+					"status":      "accepted",
+					"reviewed_by": 1,
+					"reviewed_at": "2025-05-11T08:30:00Z",
+				},
+			},
+		},
+		"POST /api/documents/{id}/shares": map[string]interface{}{
+			"description": "Share a document with another account for read or redact access; sharing it again with the same account changes the permission. Only the owner can share a document",
+			"headers": map[string]string{
+				"Authorization": "Bearer {access_token}",
+				"Content-Type":  "application/json",
+			},
+			"path_params": map[string]string{
+				"id": "ID of the document",
+			},
+			"body": map[string]interface{}{
+				"email":      "Email address of the account to share the document with",
+				"permission": "read (view the document, its content and redactions) or redact (change the redaction schema as well)",
+			},
+			"response": map[string]interface{}{
+				"success": true,
+				"data": map[string]interface{}{
+					"id":          3,
+					"document_id": 1,
+					"user_id":     8,
+					"email":       "colleague@example.com",
+					"granted_by":  7,
+					"permission":  "redact",
+					"created_at":  "2025-05-10T21:09:03.46195Z",
+				},
+			},
+		},
+		"GET /api/documents/{id}/shares": map[string]interface{}{
+			"description": "List the accounts a document owned by the user is shared with",
+			"headers": map[string]string{
+				"Authorization": "Bearer {access_token}",
+			},
+			"path_params": map[string]string{
+				"id": "ID of the document",
+			},
+			"response": listResponse(
+				map[string]interface{}{
+					"id":          3,
+					"document_id": 1,
+					"user_id":     8,
+					"email":       "colleague@example.com",
+					"granted_by":  7,
+					"permission":  "redact",
+					"created_at":  "2025-05-10T21:09:03.46195Z",
+				},
+			),
+		},
+		"DELETE /api/documents/{id}/shares/{userId}": map[string]interface{}{
+			"description": "Stop sharing a document with an account",
+			"headers": map[string]string{
+				"Authorization": "Bearer {access_token}",
+			},
+			"path_params": map[string]string{
+				"id":     "ID of the document",
+				"userId": "ID of the account the document is shared with",
+			},
+			"response": map[string]interface{}{
+				"success":     true,
+				"status_code": 204,
+				"no_content":  true,
+			},
+		},
+		"GET /api/documents/shared-with-me": map[string]interface{}{
+			"description": "List the documents other users shared with the user, most recently shared first",
+			"headers": map[string]string{
+				"Authorization": "Bearer {access_token}",
+			},
+			"response": listResponse(
+				map[string]interface{}{
+					"id":               1,
+					"hashed_name":      "document.pdf",
+					"owner_id":         7,
+					"permission":       "read",
+					"shared_at":        "2025-05-10T21:09:03.46195Z",
+					"upload_timestamp": "2025-05-09T10:00:00Z",
+					"last_modified":    "2025-05-10T21:09:03.46195Z",
+					"storage_tier":     "hot",
+				},
+			),
+		},
+		"GET /api/documents/duplicate-leaks": map[string]interface{}{
+			"description": "Count the distinct values detected in the user's documents and list the values detected in several of them; values are identified by a fingerprint of their salted hash and never revealed",
+			"headers": map[string]string{
+				"Authorization": "Bearer {access_token}",
+			},
+			"query_params": map[string]string{
+				"min_documents": "Number of documents a value must be detected in, at least 2 (default 2)",
+			},
+			"response": map[string]interface{}{
+				"success": true,
+				"data": map[string]interface{}{
+					"min_documents":   2,
+					"distinct_values": 42,
+					"entity_types": []map[string]interface{}{
+						{"entity_type": "PERSON", "distinct_values": 30, "occurrences": 75},
+						{"entity_type": "NATIONAL_ID", "distinct_values": 12, "occurrences": 20},
+					},
+					"duplicates": []map[string]interface{}{
+						{
+							"entity_type":    "NATIONAL_ID",
+							"fingerprint":    "3f9a1c0b7e42",
+							"document_count": 3,
+							"occurrences":    4,
+							"document_ids":   []int{4, 8, 15},
+							"first_seen":     "2025-05-01T09:12:44Z",
+							"last_seen":      "2025-05-10T21:09:03Z",
+						},
+					},
+				},
+			},
+		},
+		"GET /api/documents/retention-preview": map[string]interface{}{
+			"description": "Dry run of the document retention maintenance task: list the user's documents whose retention, set by a classification rule or the document retention setting, has passed, without deleting anything. Their detected entities are deleted with them",
+			"headers": map[string]string{
+				"Authorization": "Bearer {access_token}",
+			},
+			"query_params": map[string]string{
+				"retention_days": "Document retention setting to preview before saving it, 0 to 3650 (default the current setting)",
+			},
+			"response": map[string]interface{}{
+				"success": true,
+				"data": map[string]interface{}{
+					"retention_days": 90,
+					"document_count": 2,
+					"entity_count":   17,
+					"documents": []map[string]interface{}{
+						{"document_id": 4, "upload_timestamp": "2025-01-03T10:00:00Z", "retain_until": "2025-04-03T10:00:00Z", "entity_count": 12, "reason": "classification_rule"},
+						{"document_id": 8, "upload_timestamp": "2025-01-20T08:30:00Z", "entity_count": 5, "reason": "retention_setting"},
+					},
+				},
+			},
+		},
+		"GET /api/documents/{id}/summary": map[string]interface{}{
+			"description": "Get a summary of a document (with entity count, etc.)",
+			"headers": map[string]string{
+				"Authorization": "Bearer {access_token}",
+			},
+			"path_params": map[string]string{
+				"id": "ID of the document",
+			},
+			"response": map[string]interface{}{
+				"success": true,
+				"data": map[string]interface{}{
+					"id":               1,
+					"hashed_name":      "encrypted-filename-string",
+					"upload_timestamp": "2023-01-01T12:00:00Z",
+					"last_modified":    "2023-01-01T12:00:00Z",
+					"entity_count":     5,
+					"language":         "nb",
+				},
+			},
+		},
+		"GET /api/documents/{id}/timeline": map[string]interface{}{
+			"description": "Get the chronological event feed of a document (paginated)",
+			"headers": map[string]string{
+				"Authorization": "Bearer {access_token}",
+			},
+			"path_params": map[string]string{
+				"id": "ID of the document",
+			},
+			"query_params": map[string]string{
+				"page":      "Page number (default: 1)",
+				"cursor":    "page_info.next_cursor or prev_cursor of an earlier page (optional, instead of page)",
+				"page_size": "Number of events per page (default: 20)",
+			},
+			"response": listResponse(
+				map[string]interface{}{
+					"type":        "uploaded",
+					"timestamp":   "2023-01-01T12:00:00Z",
+					"description": "Document uploaded",
+				},
+				map[string]interface{}{
+					"type":         "entities_detected",
+					"timestamp":    "2023-01-01T12:00:05Z",
+					"entity_count": 5,
+					"method_name":  "Presidio",
+					"description":  "Detected 5 entities with Presidio",
+				},
+			),
+		},
+		"GET /api/documents/{id}/export": map[string]interface{}{
+			"description": "Download the full record of a document: metadata, redaction schema, detected entities with their schemas and the complete timeline",
+			"headers": map[string]string{
+				"Authorization": "Bearer {access_token}",
+			},
+			"path_params": map[string]string{
+				"id": "ID of the document",
+			},
+			"query_params": map[string]string{
+				"format": "json (default) for a single JSON file, or zip for an archive with manifest.json, document.json, redaction_schema.json, entities.json and timeline.json",
+				"locale": "Regional formatting of the export, e.g. de-DE; sets locale and the localized exported_on date. ISO formats if omitted; 400 if unsupported",
+			},
+			"response": map[string]interface{}{
+				"version":     1,
+				"exported_at": "2023-01-02T09:00:00Z",
+				"document": map[string]interface{}{
+					"id":               1,
+					"filename":         "contract.pdf",
+					"upload_timestamp": "2023-01-01T12:00:00Z",
+					"last_modified":    "2023-01-01T12:00:05Z",
+					"language":         "en",
+					"tags":             []string{"legal"},
+					"folder":           "Contracts",
+					"source":           "scanner",
+				},
+				"redaction_schema": map[string]interface{}{
+					"version": 2,
+					"pages":   []interface{}{},
+				},
+				"entities": []map[string]interface{}{
+					{
+						"id":                 7,
+						"document_id":        1,
+						"method_id":          1,
+						"entity_name":        "PERSON",
+						"redaction_schema":   map[string]interface{}{},
+						"detected_timestamp": "2023-01-01T12:00:05Z",
+						"method_name":        "Presidio",
+						"highlight_color":    "#FF0000",
+					},
+				},
+				"timeline": []map[string]interface{}{
+					{
+						"type":        "uploaded",
+						"timestamp":   "2023-01-01T12:00:00Z",
+						"description": "Document uploaded",
+					},
+				},
+			},
+		},
+		"GET /api/documents/{id}/pages/{n}/overlay": map[string]interface{}{
+			"description": "Get the redaction rectangles of one page for drawing overlays. Coordinates are relative to the page, from 0 at the top-left corner to 1 at the bottom-right corner, and colors are those of the detection methods. The detected texts are not included.",
+			"headers": map[string]string{
+				"Authorization": "Bearer {access_token}",
+			},
+			"path_params": map[string]string{
+				"id": "ID of the document",
+				"n":  "Page number, starting at 1",
+			},
+			"response": map[string]interface{}{
+				"success": true,
+				"data": map[string]interface{}{
+					"document_id": 1,
+					"page":        1,
+					"width":       595,
+					"height":      842,
+					"rects": []map[string]interface{}{
+						{
+							"x":      0.1,
+							"y":      0.2,
+							"width":  0.15,
+							"height": 0.02,
+							"label":  "PERSON",
+							"color":  "#33FF57",
+							"method": "Presidio",
+						},
+					},
+				},
+			},
+		},
+		"POST /api/jobs": map[string]interface{}{
+			"description": "Queue the detection, redaction or export of a document as a background job, for documents too large to process within a request. The job runs with the access of the caller and the context of the request: its request ID, caller and API key scopes, locale (which formats export jobs) and the features offered when it was queued; failed attempts are retried with a growing delay, up to 5 attempts. Returns 202 with the pending job; 404 if the document doesn't exist",
+			"headers": map[string]string{
+				"Authorization": "Bearer {access_token}",
+				"Content-Type":  "application/json",
+			},
+			"body": map[string]interface{}{
+				"kind":             "detection, redaction or export",
+				"document_id":      12,
+				"redaction_method": "blackout (default), mask or replace; redaction jobs only",
+				"entities":         "the detected entities to add, as for POST /api/documents/{id}/entities/batch; required for detection jobs",
+				"locale":           "string - en-US, en-GB, de-DE, fr-FR, es-ES, it-IT, nl-NL, nb-NO, sv-SE or da-DK (optional, default ISO formats)",
+			},
+			"response": map[string]interface{}{
+				"success": true,
+				"data": map[string]interface{}{
+					"id":           7,
+					"kind":         "redaction",
+					"document_id":  12,
+					"status":       "pending",
+					"attempts":     0,
+					"max_attempts": 5,
+					"run_after":    "2023-01-02T09:00:00Z",
+					"created_at":   "2023-01-02T09:00:00Z",
+					"updated_at":   "2023-01-02T09:00:00Z",
+				},
+			},
+		},
+		"GET /api/jobs": map[string]interface{}{
+			"description": "List the background jobs of the current user, newest first. Finished jobs and their results are deleted after 7 days",
+			"headers": map[string]string{
+				"Authorization": "Bearer {access_token}",
+			},
+		},
+		"GET /api/jobs/{id}": map[string]interface{}{
+			"description": "Get the status of a background job: pending, running, completed or failed, with its attempts, when the next attempt is due and why the last one failed",
+			"headers": map[string]string{
+				"Authorization": "Bearer {access_token}",
+			},
+			"path_params": map[string]string{
+				"id": "ID of the job",
+			},
+		},
+		"GET /api/jobs/{id}/result": map[string]interface{}{
+			"description": "Download the result of a completed job: the redacted PDF of a redaction job, the document record of an export job, or the IDs of the entities a detection job added. Returns 409 until the job has completed",
+			"headers": map[string]string{
+				"Authorization": "Bearer {access_token}",
+			},
+			"path_params": map[string]string{
+				"id": "ID of the job",
+			},
+		},
+		"POST /api/documents/{id}/redact": map[string]interface{}{
+			"description": "Apply the redaction schema of a document to its PDF and download the result. The text, images and annotations under each redaction are removed from the file before it is covered, and earlier revisions of the file are dropped. Each redaction uses the method saved with its detected entity, or the method query parameter. Encrypted or damaged PDFs are refused with 400; 404 if no file is uploaded and the document has no stored content",
+			"headers": map[string]string{
+				"Authorization": "Bearer {access_token}",
+				"Content-Type":  "multipart/form-data (optional)",
+			},
+			"path_params": map[string]string{
+				"id": "ID of the document",
+			},
+			"query_params": map[string]string{
+				"method": "blackout (default) for black boxes, mask for black boxes labelled with the entity type, or replace for white boxes with the replacement value",
+			},
+			"body": map[string]interface{}{
+				"file": "The PDF to redact (multipart only, optional) - the content stored with the document is redacted if omitted",
+			},
+			"response_headers": map[string]string{
+				"Content-Type":        "application/pdf",
+				"Content-Disposition": "attachment; filename=redacted-{id}.pdf",
+			},
+			"response": "The redacted PDF",
+		},
+		"PUT /api/documents/{id}/lifecycle": map[string]interface{}{
+			"description": "Exempt a document from the cleanup of stale documents, or make it subject to it again. Documents never processed, or whose detections await review, are stale once untouched for 30 days; their owners are emailed, and they are archived or deleted 7 days later. Either way a notice already sent no longer counts. 404 for documents of other users",
+			"headers": map[string]string{
+				"Authorization": "Bearer {access_token}",
+				"Content-Type":  "application/json",
+			},
+			"path_params": map[string]string{
+				"id": "ID of the document",
+			},
+			"body": map[string]interface{}{
+				"exempt": "boolean - keep the document however long it stays unfinished",
+			},
+			"response": map[string]interface{}{
+				"success": true,
+				"data": map[string]interface{}{
+					"document_id": 1,
+					"exempt":      true,
+				},
+			},
+		},
+		"POST /api/documents/{id}/restore-from-archive": map[string]interface{}{
+			"description": "Move a document from cold storage back to hot storage. Archived documents are listed with storage_tier \"archive\" and their content cannot be read until they are restored.",
+			"headers": map[string]string{
+				"Authorization": "Bearer {access_token}",
+			},
+			"path_params": map[string]string{
+				"id": "ID of the document",
+			},
+			"response": map[string]interface{}{
+				"success": true,
+				"data": map[string]interface{}{
+					"id":                   1,
+					"user_id":              1,
+					"hashed_document_name": "contract.pdf",
+					"upload_timestamp":     "2023-01-01T12:00:00Z",
+					"last_modified":        "2023-01-01T12:00:00Z",
+					"redaction_schema":     "{\"version\":2,\"pages\":[]}",
+				},
+			},
+		},
+	}
+
+	// Event stream routes
+	routes["events"] = map[string]interface{}{
+		"GET /api/events": map[string]interface{}{
+			"description": "Stream the current user's events as server-sent events (text/event-stream): job_updated, detection_completed and settings_changed. Events are not replayed; after reconnecting, fetch /api/jobs and /api/settings/changes",
+			"headers": map[string]string{
+				"Authorization": "Bearer {access_token}",
+				"Accept":        "text/event-stream",
+			},
+			"response": "event: job_updated\ndata: {\"type\":\"job_updated\",\"data\":{\"id\":3,\"kind\":\"redaction\",\"status\":\"running\"},\"created_at\":\"2023-01-01T12:00:00Z\"}",
+		},
+	}
+
+	// Report routes
+	routes["reports"] = map[string]interface{}{
+		"GET /api/reports/subscriptions": map[string]interface{}{
+			"description": "List the scheduled reports the current user is subscribed to",
+			"headers": map[string]string{
+				"Authorization": "Bearer {access_token}",
+			},
+			"response": listResponse(
+				map[string]interface{}{
+					"id":            1,
+					"user_id":       123,
+					"report_type":   "weekly_entity_summary",
+					"format":        "csv",
+					"locale":        "de-DE",
+					"csv_delimiter": "",
+					"next_run_at":   "2023-01-02T06:00:00Z",
+					"created_at":    "2023-01-01T12:00:00Z",
+				},
+			),
+		},
+		"POST /api/reports/subscriptions": map[string]interface{}{
+			"description": "Subscribe to a report emailed as a CSV attachment; weekly reports are sent on Mondays, monthly reports on the 1st",
+			"headers": map[string]string{
+				"Authorization": "Bearer {access_token}",
+				"Content-Type":  "application/json",
+			},
+			"body": map[string]interface{}{
+				"report_type":   "string - weekly_entity_summary or monthly_usage",
+				"format":        "string - csv (optional, default csv)",
+				"locale":        "string - en-US, en-GB, de-DE, fr-FR, es-ES, it-IT, nl-NL, nb-NO, sv-SE or da-DK (optional, default ISO formats)",
+				"csv_delimiter": "string - comma or semicolon (optional, default the locale's delimiter)",
+			},
+		},
+		"DELETE /api/reports/subscriptions/{id}": map[string]interface{}{
+			"description": "Unsubscribe from a report",
+			"headers": map[string]string{
+				"Authorization": "Bearer {access_token}",
+			},
+		},
+	}
+
+	// Benchmark routes
+	routes["benchmarks"] = map[string]interface{}{
+		"GET /api/benchmarks": map[string]interface{}{
+			"description": "Compare the current tenant's entities per page with differentially private industry averages; only available to tenants that opted in, and industries with too few tenants are left out",
+			"headers": map[string]string{
+				"Authorization": "Bearer {access_token}",
+			},
+			"response": map[string]interface{}{
+				"success": true,
+				"data": map[string]interface{}{
+					"industry":                       "legal",
+					"entities_per_page":              3.5,
+					"industry_avg_entities_per_page": 4.2,
+					"report": map[string]interface{}{
+						"industries": []map[string]interface{}{
+							{"industry": "finance", "avg_entities_per_page": 2.8},
+							{"industry": "legal", "avg_entities_per_page": 4.2},
+						},
+						"epsilon":      1.0,
+						"window_start": "2023-01-01T00:00:00Z",
+						"generated_at": "2023-04-01T00:00:00Z",
+					},
+				},
+			},
+		},
+		"GET /api/benchmarks/consent": map[string]interface{}{
+			"description": "Get the current tenant's benchmark consent; 404 if the tenant has not opted in",
+			"headers": map[string]string{
+				"Authorization": "Bearer {access_token}",
+			},
+		},
+		"PUT /api/benchmarks/consent": map[string]interface{}{
+			"description": "Opt in to benchmarks or change the industry; the tenant is included from the next weekly benchmark run",
+			"headers": map[string]string{
+				"Authorization": "Bearer {access_token}",
+				"Content-Type":  "application/json",
+			},
+			"body": map[string]interface{}{
+				"industry": "string - healthcare, finance, insurance, legal, government, education, technology, retail or other",
+			},
+		},
+		"DELETE /api/benchmarks/consent": map[string]interface{}{
+			"description": "Opt out of benchmarks; the tenant is excluded from the next benchmark run",
+			"headers": map[string]string{
+				"Authorization": "Bearer {access_token}",
+			},
+		},
+	}
+
+	// Admin routes
+	routes["announcements"] = map[string]interface{}{
+		"GET /api/announcements": map[string]interface{}{
+			"description": "List the announcements currently shown, such as maintenance windows and new-feature notices, with whether the user has read them",
+			"headers": map[string]string{
+				"Authorization": "Bearer {access_token}",
+			},
+			"query_params": map[string]string{
+				"unread": "true to only return unread announcements (optional)",
+			},
+			"response": listResponse(
+				map[string]interface{}{
+					"id":         4,
+					"title":      "Scheduled maintenance",
+					"message":    "The service is unavailable on Saturday from 22:00 to 23:00 UTC",
+					"category":   "maintenance",
+					"starts_at":  "2026-03-01T08:00:00Z",
+					"ends_at":    "2026-03-07T23:00:00Z",
+					"created_at": "2026-03-01T07:45:00Z",
+					"updated_at": "2026-03-01T07:45:00Z",
+					"read":       false,
+				},
+			),
+		},
+		"POST /api/announcements/{id}/read": map[string]interface{}{
+			"description": "Mark an announcement as read; marking it again keeps the time it was first read",
+			"headers": map[string]string{
+				"Authorization": "Bearer {access_token}",
+			},
+			"path_params": map[string]string{
+				"id": "ID of the announcement",
+			},
+			"response": map[string]interface{}{
+				"success":     true,
+				"status_code": 204,
+				"no_content":  true,
+			},
+		},
+	}
+
+	// Cloud drive routes
+	routes["drives"] = map[string]interface{}{
+		"GET /api/drives": map[string]interface{}{
+			"description": "List Google Drive, OneDrive and SharePoint with whether they are available on this server and connected by the user",
+			"headers": map[string]string{
+				"Authorization": "Bearer {access_token}",
+			},
+			"response": listResponse(
+				map[string]interface{}{
+					"provider":     "google_drive",
+					"configured":   true,
+					"connected":    true,
+					"connected_at": "2026-03-01T07:45:00Z",
+				},
+				map[string]interface{}{
+					"provider":   "onedrive",
+					"configured": true,
+					"connected":  false,
+				},
+			),
+		},
+		"POST /api/drives/{provider}/authorize": map[string]interface{}{
+			"description": "Start connecting a cloud drive; send the user to the returned consent page, which redirects back to the frontend with code and state query parameters",
+			"headers": map[string]string{
+				"Authorization": "Bearer {access_token}",
+			},
+			"path_params": map[string]string{
+				"provider": "google_drive, onedrive or sharepoint",
+			},
+			"response": map[string]interface{}{
+				"authorization_url": "https://accounts.google.com/o/oauth2/v2/auth?client_id=...&state=...",
+			},
+		},
+		"POST /api/drives/connect": map[string]interface{}{
+			"description": "Complete connecting a cloud drive with the code and state from the redirect; the state expires after 10 minutes and only works for the user who started the authorization",
+			"headers": map[string]string{
+				"Authorization": "Bearer {access_token}",
+				"Content-Type":  "application/json",
+			},
+			"body": map[string]interface{}{
+				"code":  "string - code query parameter of the redirect",
+				"state": "string - state query parameter of the redirect",
+			},
+		},
+		"DELETE /api/drives/{provider}": map[string]interface{}{
+			"description": "Disconnect a cloud drive and delete its stored tokens",
+			"headers": map[string]string{
+				"Authorization": "Bearer {access_token}",
+			},
+			"path_params": map[string]string{
+				"provider": "google_drive, onedrive or sharepoint",
+			},
+		},
+		"GET /api/drives/{provider}/files": map[string]interface{}{
+			"description": "List a folder of a connected cloud drive, folders first; SharePoint lists the user's sites at the top level",
+			"headers": map[string]string{
+				"Authorization": "Bearer {access_token}",
+			},
+			"query_params": map[string]string{
+				"folder": "ID of the folder to list (optional, default the top level)",
+				"cursor": "page_info.next_cursor of the previous page (optional); page_token is accepted as well",
+			},
+			"response": map[string]interface{}{
+				"success": true,
+				"data": map[string]interface{}{
+					"items": []map[string]interface{}{
+						{
+							"id":          "1a2b3c",
+							"name":        "Contracts",
+							"size":        0,
+							"is_folder":   true,
+							"modified_at": "2026-03-01T07:45:00Z",
+						},
+						{
+							"id":          "4d5e6f",
+							"name":        "lease.pdf",
+							"mime_type":   "application/pdf",
+							"size":        482113,
+							"is_folder":   false,
+							"modified_at": "2026-02-12T10:03:00Z",
+						},
+					},
+					"total": nil,
+					"page_info": map[string]interface{}{
+						"next_cursor": "...",
+						"prev_cursor": nil,
+					},
+				},
+			},
+		},
+		"GET /api/drives/{provider}/files/{fileID}/content": map[string]interface{}{
+			"description": "Download a file from a connected cloud drive for processing, then upload the result through POST /api/documents with the provider as source; native Google documents are exported as PDF and files over the import limit are rejected",
+			"headers": map[string]string{
+				"Authorization": "Bearer {access_token}",
+			},
+			"path_params": map[string]string{
+				"provider": "google_drive, onedrive or sharepoint",
+				"fileID":   "ID of the file",
+			},
+		},
+	}
+
+	// Export destination routes
+	routes["exports"] = map[string]interface{}{
+		"GET /api/export-destinations": map[string]interface{}{
+			"description": "List the destinations the user's redacted documents are delivered to; credentials are never returned",
+			"headers": map[string]string{
+				"Authorization": "Bearer {access_token}",
+			},
+			"response": listResponse(
+				map[string]interface{}{
+					"id":   1,
+					"name": "Archive",
+					"type": "s3",
+					"config": map[string]interface{}{
+						"bucket":        "acme-redacted",
+						"region":        "eu-north-1",
+						"access_key_id": "AKIA...",
+						"prefix":        "hideme/",
+					},
+					"auto_deliver": true,
+					"created_at":   "2026-03-01T07:45:00Z",
+					"updated_at":   "2026-03-01T07:45:00Z",
+				},
+			),
+		},
+		"POST /api/export-destinations": map[string]interface{}{
+			"description": "Add an S3 bucket, SFTP server or SharePoint folder; the export bundle of every document saved afterwards is delivered to it as hideme-document-{id}.json",
+			"headers": map[string]string{
+				"Authorization": "Bearer {access_token}",
+				"Content-Type":  "application/json",
+			},
+			"body": map[string]interface{}{
+				"name":         "string - Name of the destination, unique per user",
+				"type":         "string - s3, sftp or sharepoint",
+				"auto_deliver": "boolean (optional, default true) - Deliver documents as soon as they are saved",
+				"config": map[string]string{
+					"bucket":            "s3: Bucket name",
+					"region":            "s3: AWS region",
+					"endpoint":          "s3 (optional): HTTPS endpoint of an S3-compatible store",
+					"access_key_id":     "s3: Access key ID",
+					"secret_access_key": "s3: Secret access key",
+					"host":              "sftp: Server host name",
+					"port":              "sftp (optional, default 22): Server port",
+					"username":          "sftp: User name",
+					"password":          "sftp: Password, or",
+					"private_key":       "sftp: Unencrypted PEM private key",
+					"host_key":          "sftp: Server public key in authorized_keys format",
+					"folder_id":         "sharepoint: Folder ID from GET /api/drives/sharepoint/files; SharePoint must be connected",
+					"prefix":            "s3, sftp (optional): Key prefix or directory",
+				},
+			},
+		},
+		"DELETE /api/export-destinations/{id}": map[string]interface{}{
+			"description": "Remove an export destination and its delivery history; delivered documents stay at the destination",
+			"headers": map[string]string{
+				"Authorization": "Bearer {access_token}",
+			},
+			"path_params": map[string]string{
+				"id": "ID of the destination",
+			},
+		},
+		"GET /api/documents/{id}/deliveries": map[string]interface{}{
+			"description": "Get the delivery status of a document at each export destination; failed deliveries are retried automatically until they have used up their attempts",
+			"headers": map[string]string{
+				"Authorization": "Bearer {access_token}",
+			},
+			"path_params": map[string]string{
+				"id": "ID of the document",
+			},
+			"response": listResponse(
+				map[string]interface{}{
+					"id":               5,
+					"destination_id":   1,
+					"destination_name": "Archive",
+					"document_id":      11,
+					"status":           "delivered",
+					"attempts":         1,
+					"object_name":      "hideme-document-11.json",
+					"created_at":       "2026-03-01T08:00:00Z",
+					"updated_at":       "2026-03-01T08:00:01Z",
+					"delivered_at":     "2026-03-01T08:00:01Z",
+				},
+			),
+		},
+		"POST /api/documents/{id}/deliveries": map[string]interface{}{
+			"description": "Deliver a document to an export destination now and wait for the outcome; a failed delivery is returned with status failed and last_error",
+			"headers": map[string]string{
+				"Authorization": "Bearer {access_token}",
+				"Content-Type":  "application/json",
+			},
+			"path_params": map[string]string{
+				"id": "ID of the document",
+			},
+			"body": map[string]interface{}{
+				"destination_id": "integer - ID of the destination",
+			},
+		},
+	}
+
+	// Webhook routes
+	routes["webhooks"] = map[string]interface{}{
+		"GET /api/webhooks": map[string]interface{}{
+			"description": "List the URLs the user receives events at; secrets are never returned",
+			"headers": map[string]string{
+				"Authorization": "Bearer {access_token}",
+			},
+			"response": listResponse(
+				map[string]interface{}{
+					"id":         2,
+					"url":        "https://hooks.example.com/hideme",
+					"events":     []string{"document.processed", "entity.detected"},
+					"active":     true,
+					"created_at": "2026-03-01T07:45:00Z",
+					"updated_at": "2026-03-01T07:45:00Z",
+				},
+			),
+		},
+		"POST /api/webhooks": map[string]interface{}{
+			"description": "Register a URL that receives events as a POST of {\"id\", \"type\", \"created_at\", \"data\"}. Each delivery carries X-HideMe-Event, X-HideMe-Delivery (the same on every attempt) and X-HideMe-Signature: t=<unix time>,v1=<hex HMAC-SHA256 of \"<unix time>.<body>\" with the secret>. Deliveries not answered with 2xx within 10 seconds are retried with a growing delay, 10 attempts in total by default, and then dead-lettered. Up to 20 webhooks per user",
+			"headers": map[string]string{
+				"Authorization": "Bearer {access_token}",
+				"Content-Type":  "application/json",
+			},
+			"body": map[string]interface{}{
+				"url":    "string - HTTPS URL receiving the events",
+				"secret": "string - At least 16 characters; signs the deliveries",
+				"events": "array - document.processed, entity.detected, apikey.created and/or slo.alert (administrators only)",
+			},
+		},
+		"DELETE /api/webhooks/{id}": map[string]interface{}{
+			"description": "Remove a webhook with its deliveries; pending deliveries are not made",
+			"headers": map[string]string{
+				"Authorization": "Bearer {access_token}",
+			},
+			"path_params": map[string]string{
+				"id": "ID of the webhook",
+			},
+		},
+		"GET /api/webhooks/{id}/deliveries": map[string]interface{}{
+			"description": "List the 100 most recent deliveries to a webhook, newest first. Status is pending, delivered or dead; deliveries are kept for 30 days",
+			"headers": map[string]string{
+				"Authorization": "Bearer {access_token}",
+			},
+			"path_params": map[string]string{
+				"id": "ID of the webhook",
+			},
+			"response": listResponse(
+				map[string]interface{}{
+					"id":               41,
+					"webhook_id":       2,
+					"event_type":       "document.processed",
+					"payload":          map[string]interface{}{"document_id": 11, "page_count": 3, "entity_count": 17, "source": "upload"},
+					"status":           "dead",
+					"attempts":         10,
+					"next_attempt_at":  "2026-03-01T08:40:00Z",
+					"last_status_code": 503,
+					"last_error":       "webhook responded with status 503",
+					"created_at":       "2026-03-01T08:00:00Z",
+					"updated_at":       "2026-03-01T08:40:00Z",
+				},
+			),
+		},
+		"POST /api/webhooks/{id}/deliveries/{deliveryID}/redeliver": map[string]interface{}{
+			"description": "Queue a dead-lettered delivery again with all its attempts, once the receiver is fixed. Returns 202 with the pending delivery; 409 if the delivery is not dead",
+			"headers": map[string]string{
+				"Authorization": "Bearer {access_token}",
+			},
+			"path_params": map[string]string{
+				"id":         "ID of the webhook",
+				"deliveryID": "ID of the delivery",
+			},
+		},
+	}
+
+	routes["admin"] = map[string]interface{}{
+		"GET /api/admin/billing-export": map[string]interface{}{
+			"description": "Export monthly per-tenant usage as CSV for billing (admin only)",
+			"headers": map[string]string{
+				"Authorization": "Bearer {access_token}",
+			},
+			"query_params": map[string]string{
+				"month":         "Month to export in YYYY-MM format (optional, default previous month)",
+				"locale":        "Regional formatting of dates and numbers, e.g. de-DE (optional, default ISO formats)",
+				"csv_delimiter": "comma or semicolon (optional, default the locale's delimiter)",
+			},
+			"response": map[string]interface{}{
+				"content_type": "text/csv",
+				"columns":      []string{"month", "user_id", "username", "email", "documents", "pages_processed", "storage_bytes", "api_calls"},
+			},
+		},
+		"GET /api/admin/actions": map[string]interface{}{
+			"description": "List destructive admin actions, newest first (admin only)",
+			"headers": map[string]string{
+				"Authorization": "Bearer {access_token}",
+			},
+			"query_params": map[string]string{
+				"status": "Filter by status: pending, approved, executed, failed, rejected, expired (optional)",
+			},
+		},
+		"POST /api/admin/actions": map[string]interface{}{
+			"description": "Request a destructive admin action; high-risk actions wait for a second administrator (admin only)",
+			"headers": map[string]string{
+				"Authorization": "Bearer {access_token}",
+				"Content-Type":  "application/json",
+			},
+			"body": map[string]interface{}{
+				"action_type": "string (required, user_erasure, tenant_deletion or admin_promotion)",
+				"target_id":   "integer (required)",
+				"reason":      "string (required)",
+			},
+		},
+		"GET /api/admin/actions/{id}": map[string]interface{}{
+			"description": "Get a destructive admin action and its approval state (admin only)",
+			"headers": map[string]string{
+				"Authorization": "Bearer {access_token}",
+			},
+		},
+		"POST /api/admin/actions/{id}/approve": map[string]interface{}{
+			"description": "Approve and execute a pending action requested by another administrator (admin only)",
+			"headers": map[string]string{
+				"Authorization": "Bearer {access_token}",
+				"Content-Type":  "application/json",
+			},
+			"body": map[string]interface{}{
+				"note": "string (optional)",
+			},
+		},
+		"POST /api/admin/actions/{id}/reject": map[string]interface{}{
+			"description": "Reject a pending admin action (admin only)",
+			"headers": map[string]string{
+				"Authorization": "Bearer {access_token}",
+				"Content-Type":  "application/json",
+			},
+			"body": map[string]interface{}{
+				"note": "string (optional)",
+			},
+		},
+		"GET /api/admin/document-transfers": map[string]interface{}{
+			"description": "List documents transferred between users, newest first; a transfer that stays pending was interrupted (admin only)",
+			"headers": map[string]string{
+				"Authorization": "Bearer {access_token}",
+			},
+			"query_params": map[string]string{
+				"user_id": "Only transfers from or to this user (optional)",
+			},
+		},
+		"POST /api/admin/document-transfers": map[string]interface{}{
+			"description": "Transfer selected documents, or all documents, of a user to any other user, re-encrypting them for the new owner; users have no organization boundary yet (admin only)",
+			"headers": map[string]string{
+				"Authorization": "Bearer {access_token}",
+				"Content-Type":  "application/json",
+			},
+			"body": map[string]interface{}{
+				"from_user_id": "integer (required)",
+				"to_user_id":   "integer (required)",
+				"document_ids": "array of integers (optional, all documents when empty)",
+				"reason":       "string (required, max 500 characters)",
+			},
+		},
+		"GET /api/admin/auditor-grants": map[string]interface{}{
+			"description": "List auditor access grants, newest first, including expired and revoked ones (admin only)",
+			"headers": map[string]string{
+				"Authorization": "Bearer {access_token}",
+			},
+		},
+		"POST /api/admin/auditor-grants": map[string]interface{}{
+			"description": "Grant an external auditor read-only access to documents until the grant expires; the token is returned only once (admin only)",
+			"headers": map[string]string{
+				"Authorization": "Bearer {access_token}",
+				"Content-Type":  "application/json",
+			},
+			"body": map[string]interface{}{
+				"auditor_name":    "string (required)",
+				"auditor_email":   "string (required)",
+				"document_ids":    "array of integers (optional)",
+				"user_ids":        "array of integers (optional, all documents of these users)",
+				"expires_in_days": "integer (required, 1-90)",
+				"reason":          "string (required, max 500 characters)",
+			},
+		},
+		"DELETE /api/admin/auditor-grants/{id}": map[string]interface{}{
+			"description": "Revoke an auditor access grant (admin only)",
+			"headers": map[string]string{
+				"Authorization": "Bearer {access_token}",
+			},
+		},
+		"GET /api/admin/auditor-grants/{id}/access": map[string]interface{}{
+			"description": "List the requests made with an auditor access grant, including refused ones (admin only)",
+			"headers": map[string]string{
+				"Authorization": "Bearer {access_token}",
+			},
+		},
+		"GET /api/auditor/documents": map[string]interface{}{
+			"description": "List the documents covered by the auditor's access grant",
+			"headers": map[string]string{
+				"X-Auditor-Token": "{auditor_token}",
+			},
+		},
+		"GET /api/auditor/documents/{id}": map[string]interface{}{
+			"description": "Get a document covered by the auditor's access grant",
+			"headers": map[string]string{
+				"X-Auditor-Token": "{auditor_token}",
+			},
+		},
+		"GET /api/auditor/documents/{id}/export": map[string]interface{}{
+			"description": "Export a document covered by the auditor's access grant, watermarked with the auditor and the grant",
+			"headers": map[string]string{
+				"X-Auditor-Token": "{auditor_token}",
+			},
+			"query_params": map[string]string{
+				"format": "json (default) or zip",
+			},
+		},
+		"GET /api/admin/account-holds": map[string]interface{}{
+			"description": "List accounts held back by risk scoring, oldest first (admin only)",
+			"headers": map[string]string{
+				"Authorization": "Bearer {access_token}",
+			},
+		},
+		"DELETE /api/admin/account-holds/{id}": map[string]interface{}{
+			"description": "Release the hold on a user's account after manual review (admin only)",
+			"headers": map[string]string{
+				"Authorization": "Bearer {access_token}",
+			},
+		},
+		"GET /api/admin/quotas/{id}": map[string]interface{}{
+			"description": "Get the detection quota of a user (admin only)",
+			"headers": map[string]string{
+				"Authorization": "Bearer {access_token}",
+			},
+		},
+		"POST /api/admin/quotas/{id}/adjust": map[string]interface{}{
+			"description": "Add tokens to or remove tokens from a user's detection quota (admin only)",
+			"headers": map[string]string{
+				"Authorization": "Bearer {access_token}",
+				"Content-Type":  "application/json",
+			},
+			"body": map[string]interface{}{
+				"delta": "integer - tokens to add (positive) or remove (negative)",
+			},
+		},
+		"GET /api/admin/rate-limits": map[string]interface{}{
+			"description": "Get the configured rate limits of the route groups, how much of their burst each user and IP address seen recently has used, most used first, and the burst grants in effect (admin only). Filter with the group, user_id and ip query parameters",
+			"headers": map[string]string{
+				"Authorization": "Bearer {access_token}",
+			},
+			"response": map[string]interface{}{
+				"success": true,
+				"data": map[string]interface{}{
+					"groups": []map[string]interface{}{
+						{"group": "documents", "rate": 50, "burst": 100, "user_rate": 20, "user_burst": 40},
+					},
+					"buckets": []map[string]interface{}{
+						{"group": "documents", "client_type": "user", "client_id": "42", "tokens": 4.5, "burst": 40, "rate": 20, "used_percent": 88},
+					},
+					"grants": []map[string]interface{}{},
+				},
+			},
+		},
+		"POST /api/admin/rate-limits/grants": map[string]interface{}{
+			"description": "Temporarily raise the burst of a user or IP address in a route group, for example for month-end processing (admin only). Replaces an earlier grant to the same client in the group; grants are logged and end when the server restarts",
+			"headers": map[string]string{
+				"Authorization": "Bearer {access_token}",
+				"Content-Type":  "application/json",
+			},
+			"body": map[string]interface{}{
+				"group":            "string - route group, such as documents",
+				"user_id":          "integer (optional) - user to grant the burst to",
+				"ip_address":       "string (optional) - IP address to grant the burst to, instead of a user",
+				"burst":            "integer - extra requests the client may send at once (1-100000)",
+				"duration_seconds": "integer - how long the grant lasts (60-2592000)",
+				"reason":           "string - why the burst was granted",
+			},
+		},
+		"GET /api/admin/diagnostics/queries": map[string]interface{}{
+			"description": "Get query monitoring settings and per-query statistics with captured slow query plans (admin only)",
+			"headers": map[string]string{
+				"Authorization": "Bearer {access_token}",
+			},
+		},
+		"GET /api/admin/diagnostics/caches": map[string]interface{}{
+			"description": "Get the entries, hits, misses and shared loads of the in-process caches, such as the detection configuration cache (admin only)",
+			"headers": map[string]string{
+				"Authorization": "Bearer {access_token}",
+			},
+		},
+		"GET /api/admin/diagnostics/shadow": map[string]interface{}{
+			"description": "Get how the shadow deployment's responses diverged from this server's for the mirrored read-only requests: status code mismatches, latency regressions and failures per route since startup (admin only). 400 if no SHADOW_TARGET_URL is configured",
+			"headers": map[string]string{
+				"Authorization": "Bearer {access_token}",
+			},
+			"response": map[string]interface{}{
+				"success": true,
+				"data": map[string]interface{}{
+					"target":                  "http://hideme-rc:8080",
+					"percent":                 1,
+					"mirrored":                1200,
+					"dropped":                 0,
+					"failed":                  3,
+					"status_mismatches":       4,
+					"latency_regressions":     12,
+					"primary_mean_latency_ms": 18.4,
+					"shadow_mean_latency_ms":  22.1,
+					"routes": []map[string]interface{}{
+						{"route": "GET /api/documents/{id}", "mirrored": 310, "failed": 0, "status_mismatches": 4, "latency_regressions": 2, "last_mismatch": "200 != 500"},
+					},
+				},
+			},
+		},
+		"DELETE /api/admin/diagnostics/queries": map[string]interface{}{
+			"description": "Discard the collected query statistics (admin only)",
+			"headers": map[string]string{
+				"Authorization": "Bearer {access_token}",
+			},
+		},
+		"PUT /api/admin/diagnostics/queries/settings": map[string]interface{}{
+			"description": "Toggle query metrics and slow query plan capture at runtime (admin only)",
+			"headers": map[string]string{
+				"Authorization": "Bearer {access_token}",
+				"Content-Type":  "application/json",
+			},
+			"body": map[string]interface{}{
+				"metrics_enabled":         "boolean (optional)",
+				"explain_enabled":         "boolean (optional)",
+				"slow_query_threshold_ms": "integer (optional) - duration from which a query is slow",
+			},
+		},
+		"GET /api/admin/analytics/index-suggestions": map[string]interface{}{
+			"description": "Get the latest index advisor report for the documents and detected entities tables (admin only)",
+			"headers": map[string]string{
+				"Authorization": "Bearer {access_token}",
+			},
+		},
+		"POST /api/admin/analytics/index-suggestions/refresh": map[string]interface{}{
+			"description": "Run the index advisor now and return the new report (admin only)",
+			"headers": map[string]string{
+				"Authorization": "Bearer {access_token}",
+			},
+		},
+		"GET /api/admin/status/incidents": map[string]interface{}{
+			"description": "List the most recent status page incidents, including resolved ones (admin only)",
+			"headers": map[string]string{
+				"Authorization": "Bearer {access_token}",
+			},
+		},
+		"POST /api/admin/status/incidents": map[string]interface{}{
+			"description": "Publish an incident note on the public status page (admin only)",
+			"headers": map[string]string{
+				"Authorization": "Bearer {access_token}",
+				"Content-Type":  "application/json",
+			},
+			"body": map[string]interface{}{
+				"title":   "string - short summary",
+				"message": "string - first update",
+				"impact":  "string - minor, major or critical",
+				"status":  "string (optional) - investigating, identified, monitoring or resolved",
+			},
+		},
+		"PUT /api/admin/status/incidents/{id}": map[string]interface{}{
+			"description": "Post an update on an incident; setting the status to resolved removes it from the status page (admin only)",
+			"headers": map[string]string{
+				"Authorization": "Bearer {access_token}",
+				"Content-Type":  "application/json",
+			},
+			"body": map[string]interface{}{
+				"title":   "string (optional)",
+				"message": "string (optional)",
+				"impact":  "string (optional) - minor, major or critical",
+				"status":  "string (optional) - investigating, identified, monitoring or resolved",
+			},
+		},
+		"DELETE /api/admin/status/incidents/{id}": map[string]interface{}{
+			"description": "Remove an incident note from the status page (admin only)",
+			"headers": map[string]string{
+				"Authorization": "Bearer {access_token}",
+			},
+		},
+		"GET /api/admin/announcements": map[string]interface{}{
+			"description": "List the most recent announcements, including scheduled and expired ones (admin only)",
+			"headers": map[string]string{
+				"Authorization": "Bearer {access_token}",
+			},
+		},
+		"POST /api/admin/announcements": map[string]interface{}{
+			"description": "Publish an announcement to all users (admin only)",
+			"headers": map[string]string{
+				"Authorization": "Bearer {access_token}",
+				"Content-Type":  "application/json",
+			},
+			"body": map[string]interface{}{
+				"title":     "string - short summary",
+				"message":   "string - text of the announcement",
+				"category":  "string - maintenance, feature or info",
+				"starts_at": "string (optional) - RFC 3339 time the announcement is first shown (default: now)",
+				"ends_at":   "string (optional) - RFC 3339 time the announcement stops being shown",
+			},
+		},
+		"PUT /api/admin/announcements/{id}": map[string]interface{}{
+			"description": "Change the text, category or display window of an announcement (admin only)",
+			"headers": map[string]string{
+				"Authorization": "Bearer {access_token}",
+				"Content-Type":  "application/json",
+			},
+			"body": map[string]interface{}{
+				"title":     "string (optional)",
+				"message":   "string (optional)",
+				"category":  "string (optional) - maintenance, feature or info",
+				"starts_at": "string (optional) - RFC 3339",
+				"ends_at":   "string (optional) - RFC 3339",
+			},
+		},
+		"DELETE /api/admin/announcements/{id}": map[string]interface{}{
+			"description": "Remove an announcement and its read state (admin only)",
+			"headers": map[string]string{
+				"Authorization": "Bearer {access_token}",
+			},
+		},
+		"GET /api/admin/registration-domains": map[string]interface{}{
+			"description": "List the email domains registration is limited to, with the TXT records that verify them (admin only)",
+			"headers": map[string]string{
+				"Authorization": "Bearer {access_token}",
+			},
+		},
+		"POST /api/admin/registration-domains": map[string]interface{}{
+			"description": "Add an email domain; it limits registration once its TXT record is verified (admin only)",
+			"headers": map[string]string{
+				"Authorization": "Bearer {access_token}",
+				"Content-Type":  "application/json",
+			},
+			"body": map[string]interface{}{
+				"domain": "string - domain name such as example.com",
+			},
+		},
+		"POST /api/admin/registration-domains/{id}/verify": map[string]interface{}{
+			"description": "Look up the TXT record of a domain and start limiting registration to it (admin only)",
+			"headers": map[string]string{
+				"Authorization": "Bearer {access_token}",
+			},
+		},
+		"DELETE /api/admin/registration-domains/{id}": map[string]interface{}{
+			"description": "Remove an email domain; registration is open again once no verified domain is left (admin only)",
+			"headers": map[string]string{
+				"Authorization": "Bearer {access_token}",
+			},
+		},
+		"GET /api/admin/detection-catalog/export": map[string]interface{}{
+			"description": "Export the detection methods and entity-type taxonomy as a versioned bundle to import into another environment (admin only)",
+			"headers": map[string]string{
+				"Authorization": "Bearer {access_token}",
+			},
+			"response": map[string]interface{}{
+				"success": true,
+				"data": map[string]interface{}{
+					"version":     1,
+					"exported_at": "2025-05-10T21:09:03Z",
+					"detection_methods": []map[string]interface{}{
+						{"method_name": "Presidio", "highlight_color": "#33FF57"},
+					},
+					"entity_types": []map[string]interface{}{
+						{"entity_type": "PERSON", "method_name": "Presidio", "description": "Names of people"},
+					},
+				},
+			},
+		},
+		"POST /api/admin/detection-catalog/import/preview": map[string]interface{}{
+			"description": "Show what importing a bundle would create and update in this environment, without changing anything (admin only)",
+			"headers": map[string]string{
+				"Authorization": "Bearer {access_token}",
+				"Content-Type":  "application/json",
+			},
+			"body": map[string]interface{}{
+				"version":           "integer - bundle format version, 1",
+				"detection_methods": "array - method_name and highlight_color of each detection method",
+				"entity_types":      "array - entity_type, method_name and description (optional) of each entity type",
+			},
+			"response": map[string]interface{}{
+				"success": true,
+				"data": map[string]interface{}{
+					"applied":   false,
+					"created":   1,
+					"updated":   1,
+					"unchanged": 0,
+					"detection_methods": []map[string]interface{}{
+						{"name": "Presidio", "action": "update", "fields": []map[string]interface{}{
+							{"field": "highlight_color", "from": "#33FF57", "to": "#00FF00"},
+						}},
+						{"name": "Gliner", "action": "kept"},
+					},
+					"entity_types": []map[string]interface{}{
+						{"name": "PERSON", "action": "create"},
+					},
+				},
+			},
+		},
+		"POST /api/admin/detection-catalog/import": map[string]interface{}{
+			"description": "Create and update the detection methods and entity types of a bundle, all or nothing; those the bundle doesn't list are kept (admin only)",
+			"headers": map[string]string{
+				"Authorization": "Bearer {access_token}",
+				"Content-Type":  "application/json",
+			},
+			"body": map[string]interface{}{
+				"version":           "integer - bundle format version, 1",
+				"detection_methods": "array - method_name and highlight_color of each detection method",
+				"entity_types":      "array - entity_type, method_name and description (optional) of each entity type",
+			},
+		},
+		"GET /api/admin/search": map[string]interface{}{
+			"description": "Find users by ID, username or email, documents by ID or stored name, and API keys and sessions by ID prefix (admin only)",
+			"headers": map[string]string{
+				"Authorization": "Bearer {access_token} or X-API-Key: {api_key}",
+			},
+			"query_params": map[string]string{
+				"q": "Search term, 3 to 255 characters",
+			},
+		},
+		"GET /api/admin/users": map[string]interface{}{
+			"description": "Look up a user by one of ID, username or email, or list users page by page when none is given (admin only)",
+			"headers": map[string]string{
+				"Authorization": "Bearer {access_token} or X-API-Key: {api_key}",
+			},
+			"query_params": map[string]string{
+				"id":        "User ID",
+				"username":  "Username",
+				"email":     "Email address",
+				"role":      "Only list users with this role (user, admin, auditor or support)",
+				"status":    "Only list users with this status (active or disabled)",
+				"page":      "Page number of the listing (default: 1)",
+				"cursor":    "page_info.next_cursor or prev_cursor of an earlier page (optional, instead of page)",
+				"page_size": "Users per page of the listing (default: 10)",
+			},
+		},
+		"DELETE /api/admin/users/{id}/sessions": map[string]interface{}{
+			"description": "Revoke all sessions of a user, signing them out of every device (admin only)",
+			"headers": map[string]string{
+				"Authorization": "Bearer {access_token} or X-API-Key: {api_key}",
+			},
+		},
+		"POST /api/admin/users/{id}/disable": map[string]interface{}{
+			"description": "Disable a user account: the user can't sign in or use their API keys, and is signed out of every device (admin only)",
+			"headers": map[string]string{
+				"Authorization": "Bearer {access_token} or X-API-Key: {api_key}",
+			},
+		},
+		"POST /api/admin/users/{id}/enable": map[string]interface{}{
+			"description": "Enable a disabled user account (admin only)",
+			"headers": map[string]string{
+				"Authorization": "Bearer {access_token} or X-API-Key: {api_key}",
+			},
+		},
+		"PUT /api/admin/users/{id}/role": map[string]interface{}{
+			"description": "Change the role of a user; administrators can't change their own role, and a promotion to admin is queued as an admin_promotion action for a second administrator to approve (admin only)",
+			"headers": map[string]string{
+				"Authorization": "Bearer {access_token} or X-API-Key: {api_key}",
+			},
+			"body": map[string]interface{}{
+				"role":   "user, admin, auditor or support",
+				"reason": "string (required for admin)",
+			},
+		},
+		"DELETE /api/admin/users/{id}": map[string]interface{}{
+			"description": "Request the permanent deletion of a user account; the user_erasure action waits for a second administrator to approve it (admin only)",
+			"headers": map[string]string{
+				"Authorization": "Bearer {access_token} or X-API-Key: {api_key}",
+				"Content-Type":  "application/json",
+			},
+			"body": map[string]interface{}{
+				"reason": "string (required)",
+			},
+		},
+		"GET /api/admin/maintenance": map[string]interface{}{
+			"description": "List the periodic maintenance tasks in the order they run, with their settings and last run (admin only)",
+			"headers": map[string]string{
+				"Authorization": "Bearer {access_token} or X-API-Key: {api_key}",
+			},
+		},
+		"POST /api/admin/maintenance/{task}": map[string]interface{}{
+			"description": "Run a maintenance task now with its current batch size and dry run setting, and return its outcome (admin only)",
+			"headers": map[string]string{
+				"Authorization": "Bearer {access_token} or X-API-Key: {api_key}",
+			},
+		},
+		"PUT /api/admin/maintenance/{task}/settings": map[string]interface{}{
+			"description": "Change whether a maintenance task runs periodically, its interval, and for batched tasks its batch size and dry run setting; omitted fields keep their value (admin only)",
+			"headers": map[string]string{
+				"Authorization": "Bearer {access_token} or X-API-Key: {api_key}",
+				"Content-Type":  "application/json",
+			},
+			"body": map[string]interface{}{
+				"enabled":          "boolean (optional) - whether the task runs periodically",
+				"interval_seconds": "int (optional) - seconds between periodic runs, from 60 to 2592000",
+				"batch_size":       "int (optional, batched tasks only) - maximum items per run; 0 removes the limit",
+				"dry_run":          "boolean (optional, batched tasks only) - count the items without changing them",
+			},
+		},
+		"GET /api/admin/projections": map[string]interface{}{
+			"description": "List the derived tables that can be rebuilt from the event log, with the events each is built from (admin only)",
+			"headers": map[string]string{
+				"Authorization": "Bearer {access_token} or X-API-Key: {api_key}",
+			},
+		},
+		"POST /api/admin/projections/{projection}/rebuild": map[string]interface{}{
+			"description": "Rebuild a derived table, tenant_usage or rule_hits, by replaying its events, for example once a bug in its aggregation is fixed. The table is rebuilt in one transaction and left unchanged if the rebuild fails; 409 if it is already being rebuilt (admin only)",
+			"headers": map[string]string{
+				"Authorization": "Bearer {access_token} or X-API-Key: {api_key}",
+			},
+			"response": map[string]interface{}{
+				"success": true,
+				"data": map[string]interface{}{
+					"projection":      "tenant_usage",
+					"events_replayed": 1250,
+					"started_at":      "2023-01-02T09:00:00Z",
+					"duration_ms":     840,
+				},
+			},
+		},
+		"GET /api/admin/maintenance/settings_consistency": map[string]interface{}{
+			"description": "Count users without settings or ban list, orphaned patterns, model entities and ban list words, and duplicates, without repairing them; the settings_consistency task repairs them (admin only)",
+			"headers": map[string]string{
+				"Authorization": "Bearer {access_token} or X-API-Key: {api_key}",
+			},
+		},
+		"GET /api/admin/config": map[string]interface{}{
+			"description": "Validate the running configuration and report unsafe settings, with secrets redacted (admin only)",
+			"headers": map[string]string{
+				"Authorization": "Bearer {access_token} or X-API-Key: {api_key}",
+			},
+		},
+		"GET /api/admin/processing-register": map[string]interface{}{
+			"description": "List the models holding personal or sensitive data and the GDPR category of each classified field (admin only)",
+			"headers": map[string]string{
+				"Authorization": "Bearer {access_token} or X-API-Key: {api_key}",
+			},
+		},
+		"GET /api/admin/api-keys": map[string]interface{}{
+			"description": "List the API keys of all users with their owner and status, newest first (admin only)",
+			"headers": map[string]string{
+				"Authorization": "Bearer {access_token} or X-API-Key: {api_key}",
+			},
+			"query_params": map[string]string{
+				"user_id": "Only list the keys of this user (optional)",
+				"status":  "Only list keys with this status: active, pending_rotation, rotated, expired or disabled (optional)",
+			},
+		},
+		"POST /api/admin/api-keys/{keyID}/disable": map[string]interface{}{
+			"description": "Stop an API key from working; it is rejected once the verification cache expires (admin only)",
+			"headers": map[string]string{
+				"Authorization": "Bearer {access_token} or X-API-Key: {api_key}",
+			},
+			"path_params": map[string]string{
+				"keyID": "ID of the API key",
+			},
+		},
+		"GET /api/admin/api-keys/policy": map[string]interface{}{
+			"description": "Get the maximum lifetime of API keys; 0 for no limit (admin only)",
+			"headers": map[string]string{
+				"Authorization": "Bearer {access_token} or X-API-Key: {api_key}",
+			},
+		},
+		"PUT /api/admin/api-keys/policy": map[string]interface{}{
+			"description": "Set the maximum lifetime of API keys; existing keys valid for longer now expire at the limit (admin only)",
+			"headers": map[string]string{
+				"Authorization": "Bearer {access_token} or X-API-Key: {api_key}",
+				"Content-Type":  "application/json",
+			},
+			"body": map[string]interface{}{
+				"max_lifetime_days": "int - maximum lifetime in days, up to 3650; 0 removes the limit",
+			},
+		},
+		"GET /api/admin/api-keys/rotation-campaigns": map[string]interface{}{
+			"description": "List the API key rotation campaigns with how many of their keys were rotated (admin only)",
+			"headers": map[string]string{
+				"Authorization": "Bearer {access_token} or X-API-Key: {api_key}",
+			},
+		},
+		"POST /api/admin/api-keys/rotation-campaigns": map[string]interface{}{
+			"description": "Require the owners of the selected API keys to rotate them before a deadline; owners are emailed and keys not rotated stop working at the deadline (admin only)",
+			"headers": map[string]string{
+				"Authorization": "Bearer {access_token} or X-API-Key: {api_key}",
+				"Content-Type":  "application/json",
+			},
+			"body": map[string]interface{}{
+				"name":           "string - reason for the campaign, such as a suspected leak",
+				"deadline":       "string - RFC 3339 time the keys stop working",
+				"user_id":        "int (optional) - only include the keys of this user",
+				"created_before": "string (optional) - RFC 3339 time; only include keys created before it",
+			},
+		},
+	}
+
+	// Add the declared scopes, rate limit, load shedding, timeout and auditing of each route;
+	// routes without documentation are listed with only those
+	other := map[string]interface{}{}
+	for _, rt := range s.routes {
+		key := rt.Method + " " + rt.Pattern
+		documented := false
+		for _, category := range routes {
+			docs, _ := category.(map[string]interface{})
+			if doc, ok := docs[key].(map[string]interface{}); ok {
+				doc["route"] = rt.documentation()
+				documented = true
+			}
+		}
+		if !documented {
+			other[key] = map[string]interface{}{"route": rt.documentation()}
+		}
+	}
+	if len(other) > 0 {
+		routes["other"] = other
 	}
 
 	utils.JSON(w, http.StatusOK, routes)
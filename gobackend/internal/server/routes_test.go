@@ -8,6 +8,7 @@ import (
 	"net/http"
 	"net/http/httptest"
 	"os"
+	"strings"
 	"testing"
 
 	"github.com/go-chi/chi/v5"
@@ -234,7 +235,7 @@ func TestHandlePreflights(t *testing.T) {
 
 			if tc.checkHeaders {
 				assert.Equal(t, tc.origin, resp.Header.Get("Access-Control-Allow-Origin"))
-				assert.NotEmpty(t, resp.Header.Get("Access-Control-Allow-Methods"))
+				assert.Contains(t, resp.Header.Get("Access-Control-Allow-Methods"), http.MethodPatch)
 				assert.NotEmpty(t, resp.Header.Get("Access-Control-Allow-Headers"))
 				assert.Equal(t, "true", resp.Header.Get("Access-Control-Allow-Credentials"))
 				assert.NotEmpty(t, resp.Header.Get("Access-Control-Max-Age"))
@@ -253,6 +254,8 @@ func TestCorMiddleware(t *testing.T) {
 		allowedOrigins []string
 		origin         string
 		method         string
+		path           string
+		requestMethod  string
 		expectedHeader string
 	}{
 		{
@@ -276,6 +279,15 @@ func TestCorMiddleware(t *testing.T) {
 			method:         "OPTIONS",
 			expectedHeader: "http://example.com",
 		},
+		{
+			name:           "PATCH preflight for the ban list words",
+			allowedOrigins: []string{"http://example.com"},
+			origin:         "http://example.com",
+			method:         "OPTIONS",
+			path:           "/api/settings/ban-list/words",
+			requestMethod:  http.MethodPatch,
+			expectedHeader: "http://example.com",
+		},
 		{
 			name:           "Request with disallowed origin",
 			allowedOrigins: []string{"http://example.org"},
@@ -298,8 +310,15 @@ func TestCorMiddleware(t *testing.T) {
 			handler := corsMiddleware(tc.allowedOrigins)(testHandler)
 
 			// Create test request with origin header
-			req := httptest.NewRequest(tc.method, "/test", nil)
+			path := tc.path
+			if path == "" {
+				path = "/test"
+			}
+			req := httptest.NewRequest(tc.method, path, nil)
 			req.Header.Set("Origin", tc.origin)
+			if tc.requestMethod != "" {
+				req.Header.Set("Access-Control-Request-Method", tc.requestMethod)
+			}
 
 			// Create response recorder
 			w := httptest.NewRecorder()
@@ -326,7 +345,10 @@ func TestCorMiddleware(t *testing.T) {
 				assert.Equal(t, "true", resp.Header.Get("Access-Control-Allow-Credentials"))
 
 				if tc.method == "OPTIONS" {
-					assert.NotEmpty(t, resp.Header.Get("Access-Control-Allow-Methods"))
+					assert.Contains(t, resp.Header.Get("Access-Control-Allow-Methods"), http.MethodPatch)
+					if tc.requestMethod != "" {
+						assert.Contains(t, resp.Header.Get("Access-Control-Allow-Methods"), tc.requestMethod)
+					}
 					assert.NotEmpty(t, resp.Header.Get("Access-Control-Allow-Headers"))
 					assert.NotEmpty(t, resp.Header.Get("Access-Control-Max-Age"))
 				}
@@ -335,6 +357,35 @@ func TestCorMiddleware(t *testing.T) {
 	}
 }
 
+// TestCORSAllowsDocumentedMethods checks that preflight responses allow the method of every
+// documented route, such as PATCH /api/settings/ban-list, so browsers can call them.
+func TestCORSAllowsDocumentedMethods(t *testing.T) {
+	server := &Server{Config: &config.AppConfig{}}
+	w := httptest.NewRecorder()
+	server.GetAPIRoutes(w, httptest.NewRequest(http.MethodGet, "/api/routes", nil))
+
+	var response struct {
+		Data map[string]map[string]interface{} `json:"data"`
+	}
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+
+	allowed := strings.Split(corsAllowedMethods, ", ")
+	documented := 0
+	for _, routes := range response.Data {
+		for key := range routes {
+			method, _, ok := strings.Cut(key, " ")
+			if !ok || method == http.MethodHead {
+				// HEAD is a simple method browsers send without a preflight
+				continue
+			}
+			documented++
+			assert.Contains(t, allowed, method, "preflight responses don't allow %s", key)
+		}
+	}
+	assert.NotZero(t, documented)
+	assert.Contains(t, response.Data["settings"], "PATCH /api/settings/ban-list")
+}
+
 // TestGetAllowedOrigins tests the getAllowedOrigins function
 func TestGetAllowedOrigin(t *testing.T) {
 	// Save original environment and restore after test
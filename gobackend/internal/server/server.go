@@ -9,10 +9,12 @@ package server
 
 import (
 	"context"
+	"database/sql"
 	"fmt"
 	"github.com/go-chi/chi/v5"
 	"github.com/yasinhessnawi1/Hideme_Backend/migrations"
 	"github.com/yasinhessnawi1/Hideme_Backend/scripts"
+	"net"
 	"net/http"
 	"os"
 	"os/signal"
@@ -20,16 +22,21 @@ import (
 	"time"
 
 	"github.com/rs/zerolog/log"
+	"golang.org/x/net/http2"
+	"golang.org/x/net/http2/h2c"
 
 	"github.com/yasinhessnawi1/Hideme_Backend/internal/auth"
 	"github.com/yasinhessnawi1/Hideme_Backend/internal/config"
 	"github.com/yasinhessnawi1/Hideme_Backend/internal/constants"
 	"github.com/yasinhessnawi1/Hideme_Backend/internal/database"
 	"github.com/yasinhessnawi1/Hideme_Backend/internal/handlers"
+	"github.com/yasinhessnawi1/Hideme_Backend/internal/middleware"
+	"github.com/yasinhessnawi1/Hideme_Backend/internal/models"
 	"github.com/yasinhessnawi1/Hideme_Backend/internal/repository"
 	"github.com/yasinhessnawi1/Hideme_Backend/internal/service"
 	"github.com/yasinhessnawi1/Hideme_Backend/internal/utils"
 	"github.com/yasinhessnawi1/Hideme_Backend/internal/utils/gdprlog"
+	"github.com/yasinhessnawi1/Hideme_Backend/internal/worker"
 )
 
 // Handlers contains all HTTP handlers for the application.
@@ -48,8 +55,113 @@ type Handlers struct {
 	// DocumentHandler manages document endpoints
 	DocumentHandler *handlers.DocumentHandler
 
+	// DocumentShareHandler manages the sharing of documents between users
+	DocumentShareHandler *handlers.DocumentShareHandler
+
+	// DuplicateLeakHandler reports the detected values leaking in several documents
+	DuplicateLeakHandler *handlers.DuplicateLeakHandler
+
 	// PasswordResetHandler manages password reset endpoints
 	PasswordResetHandler *handlers.PasswordResetHandler
+
+	// UsageHandler manages tenant usage and billing endpoints
+	UsageHandler *handlers.UsageHandler
+
+	// ApprovalHandler manages destructive admin actions and their approval
+	ApprovalHandler *handlers.ApprovalHandler
+
+	// DocumentTransferHandler manages the transfer of documents between users
+	DocumentTransferHandler *handlers.DocumentTransferHandler
+
+	// AuditorGrantHandler manages the read-only access of external auditors
+	AuditorGrantHandler *handlers.AuditorGrantHandler
+
+	// RiskHandler manages accounts held back by risk scoring
+	RiskHandler *handlers.RiskHandler
+
+	// QuotaHandler manages the detection quota endpoints
+	QuotaHandler *handlers.QuotaHandler
+
+	// PermissionHandler manages the permission introspection endpoint
+	PermissionHandler *handlers.PermissionHandler
+
+	// UserDataExportHandler manages the export of all data kept about a user
+	UserDataExportHandler *handlers.UserDataExportHandler
+
+	// AccountErasureHandler schedules and cancels the erasure of the caller's account
+	AccountErasureHandler *handlers.AccountErasureHandler
+
+	// JobHandler queues background jobs and reports their status and results
+	JobHandler *handlers.JobHandler
+
+	// NotificationHandler streams job progress and settings changes to the user's clients
+	NotificationHandler *handlers.NotificationHandler
+
+	// DiagnosticsHandler manages the query diagnostics endpoints
+	DiagnosticsHandler *handlers.DiagnosticsHandler
+
+	// AnalyticsHandler manages the admin analytics endpoints
+	AnalyticsHandler *handlers.AnalyticsHandler
+
+	// ClassificationHandler manages the document classification rule endpoints
+	ClassificationHandler *handlers.ClassificationHandler
+
+	// BenchmarkHandler manages the cross-tenant benchmark endpoints
+	BenchmarkHandler *handlers.BenchmarkHandler
+
+	// ReportHandler manages the scheduled report subscription endpoints
+	ReportHandler *handlers.ReportHandler
+
+	// StatusHandler manages the public status page and its incident notes
+	StatusHandler *handlers.StatusHandler
+
+	// AnnouncementHandler manages the in-product announcement endpoints
+	AnnouncementHandler *handlers.AnnouncementHandler
+
+	// SettingsConsistencyHandler reports inconsistencies in the settings object graph
+	SettingsConsistencyHandler *handlers.SettingsConsistencyHandler
+
+	// AdminSearchHandler lets administrators find users, documents, API keys and sessions from one reference
+	AdminSearchHandler *handlers.AdminSearchHandler
+
+	// DriveHandler connects users' cloud drives and streams files from them for import
+	DriveHandler *handlers.DriveHandler
+
+	// ExportHandler manages export destinations and the delivery of documents to them
+	ExportHandler *handlers.ExportHandler
+
+	// WebhookHandler manages webhooks and the deliveries of events to them
+	WebhookHandler *handlers.WebhookHandler
+
+	// RuleEffectivenessHandler reports which ban list words and search patterns produce detections
+	RuleEffectivenessHandler *handlers.RuleEffectivenessHandler
+
+	// SchemaHandler publishes the XML schemas of the endpoints that can respond with XML
+	SchemaHandler *handlers.SchemaHandler
+
+	// MaintenanceHandler runs maintenance tasks on demand for administrators
+	MaintenanceHandler *handlers.MaintenanceHandler
+
+	// ProjectionHandler rebuilds derived tables from the event log for administrators
+	ProjectionHandler *handlers.ProjectionHandler
+
+	// ConfigHandler reports problems with the running configuration to administrators
+	ConfigHandler *handlers.ConfigHandler
+
+	// ProcessingRegisterHandler lists the personal and sensitive data the application stores
+	ProcessingRegisterHandler *handlers.ProcessingRegisterHandler
+
+	// APIKeyAdminHandler lets administrators manage the API keys of all users
+	APIKeyAdminHandler *handlers.APIKeyAdminHandler
+
+	// OAuthHandler signs users in with Google and Microsoft
+	OAuthHandler *handlers.OAuthHandler
+
+	// RegistrationDomainHandler manages the email domains registration is limited to
+	RegistrationDomainHandler *handlers.RegistrationDomainHandler
+
+	// DetectionCatalogHandler promotes the detection methods and entity-type taxonomy between environments
+	DetectionCatalogHandler *handlers.DetectionCatalogHandler
 }
 
 // AuthProviders contains all authentication providers for the application.
@@ -73,9 +185,15 @@ type Server struct {
 	// Db provides database access
 	Db *database.Pool
 
+	// Redis holds the detection quota shared with the detection service; nil if not configured
+	Redis *database.RedisClient
+
 	// router handles HTTP routing
 	router chi.Router
 
+	// routes are the declared API routes, for the route documentation
+	routes []route
+
 	// Handlers contains all HTTP request handlers
 	Handlers *Handlers
 
@@ -85,8 +203,43 @@ type Server struct {
 	// httpServer is the underlying HTTP server
 	httpServer *http.Server
 
+	// probes answers the liveness, readiness and startup probes and passes other requests
+	// to the router once startup finished
+	probes *probeHandler
+
+	// serveErrors receives the error the HTTP server stopped with
+	serveErrors chan error
+
+	// systemd notifies systemd of startup, readiness and shutdown when running as a unit
+	systemd *systemdNotifier
+
+	// notifications streams events to the logged-in clients of each user
+	notifications *notificationHub
+
+	// requestMetrics counts the answered API requests the SLOs are evaluated on
+	requestMetrics *middleware.RequestMetrics
+
+	// stopNotifications stops relaying notifications between servers at shutdown; nil if
+	// notifications are not relayed
+	stopNotifications func()
+
 	// gdprLogger handles GDPR-compliant logging
 	gdprLogger *gdprlog.GDPRLogger
+
+	// stopExports interrupts the user data export jobs at shutdown; exportsDone is closed
+	// once the running job stored its checkpoint. Both are nil until maintenance tasks are set up.
+	stopExports context.CancelFunc
+	exportsDone chan struct{}
+
+	// stopJobs stops the background job workers at shutdown; jobsDone is closed once the
+	// running jobs are recorded. Both are nil until maintenance tasks are set up.
+	stopJobs context.CancelFunc
+	jobsDone chan struct{}
+
+	// stopWebhooks stops the webhook deliveries at shutdown; webhooksDone is closed once the
+	// attempt in progress is recorded. Both are nil until maintenance tasks are set up.
+	stopWebhooks context.CancelFunc
+	webhooksDone chan struct{}
 }
 
 // NewServer creates a new server instance with all required components.
@@ -102,51 +255,138 @@ type Server struct {
 //
 // The server initialization follows a specific order to ensure proper dependency
 // management: database → auth providers → repositories → services → handlers → routes.
+// The server listens from the start and answers the probes while it waits for the database
+// and runs the migrations; other requests are answered with 503 until Start is called.
 func NewServer(cfg *config.AppConfig) (*Server, error) {
 	// Create server instance
 	s := &Server{
-		Config: cfg,
+		Config:  cfg,
+		probes:  newProbeHandler(),
+		systemd: newSystemdNotifier(),
+	}
+
+	if err := s.listen(); err != nil {
+		return nil, fmt.Errorf("failed to set up HTTP server: %w", err)
+	}
+
+	if err := s.setup(); err != nil {
+		// Stop answering the probes, as the server will not start
+		if closeErr := s.httpServer.Close(); closeErr != nil {
+			log.Error().Err(closeErr).Msg("failed to close server")
+		}
+		return nil, err
+	}
+
+	return s, nil
+}
+
+// listen creates the HTTP server and starts serving the probes on the server address.
+//
+// Returns:
+//   - An error if HTTP/2 cannot be configured or the address cannot be listened on
+func (s *Server) listen() error {
+	httpServer, err := newHTTPServer(&s.Config.Server, s.probes)
+	if err != nil {
+		return err
 	}
 
+	// Listen with the configured TCP keep-alive instead of the fixed one of ListenAndServe
+	listenConfig := net.ListenConfig{KeepAlive: s.Config.Server.TCPKeepAlive}
+	listener, err := listenConfig.Listen(context.Background(), "tcp", httpServer.Addr)
+	if err != nil {
+		return err
+	}
+
+	log.Info().
+		Str("address", s.Config.Server.ServerAddress()).
+		Bool("http2", s.Config.Server.HTTP2Enabled).
+		Msg("Listening, answering probes until startup finished")
+
+	s.httpServer = httpServer
+	s.serveErrors = make(chan error, 1)
+	go func() {
+		s.serveErrors <- httpServer.Serve(listener)
+	}()
+	return nil
+}
+
+// setup initializes the components of the server in dependency order and sets up the routes.
+//
+// Returns:
+//   - An error if initialization of any component fails
+func (s *Server) setup() error {
 	// Initialize components
 	if err := s.setupDatabase(); err != nil {
-		return nil, fmt.Errorf("failed to set up database: %w", err)
+		return fmt.Errorf("failed to set up database: %w", err)
 	}
 
 	if err := s.setupAuthProviders(); err != nil {
-		return nil, fmt.Errorf("failed to set up auth providers: %w", err)
+		return fmt.Errorf("failed to set up auth providers: %w", err)
 	}
 
 	if err := s.setupRepositories(); err != nil {
-		return nil, fmt.Errorf("failed to set up repositories: %w", err)
+		return fmt.Errorf("failed to set up repositories: %w", err)
 	}
 
 	if err := s.setupServices(); err != nil {
-		return nil, fmt.Errorf("failed to set up services: %w", err)
+		return fmt.Errorf("failed to set up services: %w", err)
 	}
 
 	if err := s.setupHandlers(); err != nil {
-		return nil, fmt.Errorf("failed to set up handlers: %w", err)
+		return fmt.Errorf("failed to set up handlers: %w", err)
 	}
 
 	// Initialize GDPR logger if not already initialized by utils.InitLogger
 	if err := s.setupGDPRLogging(); err != nil {
 		log.Warn().Err(err).Msg("Failed to set up GDPR logging, falling back to standard logging")
 	}
+	if s.gdprLogger != nil {
+		// Erased accounts are redacted from the GDPR logs as well
+		services.erasureService.SetLogScrubber(s.gdprLogger)
+	}
 
 	// Set up routes
 	s.SetupRoutes()
 
-	// Create HTTP server
-	s.httpServer = &http.Server{
-		Addr:         cfg.Server.ServerAddress(), // This is the fix - using Server settings, not DB
-		Handler:      s.router,
-		ReadTimeout:  cfg.Server.ReadTimeout,
-		WriteTimeout: cfg.Server.WriteTimeout,
-		IdleTimeout:  constants.DefaultIdleTimeout,
+	return nil
+}
+
+// newHTTPServer creates the HTTP server with the timeouts, limits and protocols of the
+// server settings.
+//
+// Parameters:
+//   - settings: The server settings
+//   - handler: The handler serving all requests
+//
+// Returns:
+//   - The configured HTTP server
+//   - An error if HTTP/2 cannot be configured
+func newHTTPServer(settings *config.ServerSettings, handler http.Handler) (*http.Server, error) {
+	httpServer := &http.Server{
+		Addr:              settings.ServerAddress(),
+		Handler:           handler,
+		ReadTimeout:       settings.ReadTimeout,
+		ReadHeaderTimeout: settings.ReadHeaderTimeout,
+		WriteTimeout:      settings.WriteTimeout,
+		IdleTimeout:       settings.IdleTimeout,
+		MaxHeaderBytes:    settings.MaxHeaderBytes,
 	}
+	httpServer.SetKeepAlivesEnabled(!settings.KeepAlivesDisabled)
 
-	return s, nil
+	if settings.HTTP2Enabled {
+		// TLS is terminated in front of the server, so HTTP/2 arrives over cleartext
+		h2Server := &http2.Server{
+			MaxConcurrentStreams: uint32(settings.HTTP2MaxConcurrentStreams),
+			IdleTimeout:          settings.IdleTimeout,
+		}
+		// Registers the HTTP/2 connections for graceful shutdown
+		if err := http2.ConfigureServer(httpServer, h2Server); err != nil {
+			return nil, fmt.Errorf("failed to configure HTTP/2: %w", err)
+		}
+		httpServer.Handler = h2c.NewHandler(handler, h2Server)
+	}
+
+	return httpServer, nil
 }
 
 // setupGDPRLogging initializes GDPR-compliant logging if not already done.
@@ -193,17 +433,28 @@ func (s *Server) setupGDPRLogging() error {
 //
 // This method uses the provided application configuration to establish
 // a database connection, then runs migrations to create or update tables
-// and seeds initial data like default detection methods.
+// and seeds initial data like default detection methods. While the database
+// is not reachable yet, the connection is retried as configured in Startup.
 func (s *Server) setupDatabase() error {
-	// Connect to the database
-	db, err := database.Connect(s.Config)
+	// Connect to the database, waiting for it while it is still starting
+	s.systemd.status("Waiting for the database")
+	var db *database.Pool
+	err := database.WaitFor(context.Background(), "database", &s.Config.Startup, func(ctx context.Context) error {
+		var err error
+		db, err = database.Connect(s.Config)
+		return err
+	})
 	if err != nil {
 		return err
 	}
 
 	s.Db = db
 
-	// Run migrations to create tables if they don't exist
+	// Run migrations to create tables if they don't exist. The startup probe keeps failing
+	// until they are done, while the liveness probe passes, so long migrations are not
+	// mistaken for a hung server.
+	s.probes.setPhase(constants.PhaseMigrating)
+	s.systemd.status("Running database migrations")
 	migrator := migrations.NewMigrator(db)
 	if err := migrator.RunMigrations(context.Background()); err != nil {
 		return fmt.Errorf("failed to run database migrations: %w", err)
@@ -245,15 +496,50 @@ func (s *Server) setupAuthProviders() error {
 // repositories holds all repositories used by the server.
 // These provide data access abstraction for different domain entities.
 var repositories struct {
-	userRepo          repository.UserRepository
-	sessionRepo       repository.SessionRepository
-	apiKeyRepo        repository.APIKeyRepository
-	settingsRepo      repository.SettingsRepository
-	banListRepo       repository.BanListRepository
-	patternRepo       repository.PatternRepository
-	modelEntityRepo   repository.ModelEntityRepository
-	passwordResetRepo repository.PasswordResetRepository
-	documentRepo      repository.DocumentRepository
+	userRepo           repository.UserRepository
+	sessionRepo        repository.SessionRepository
+	apiKeyRepo         repository.APIKeyRepository
+	apiKeyAdminRepo    repository.APIKeyAdminRepository
+	settingsRepo       repository.SettingsRepository
+	banListRepo        repository.BanListRepository
+	patternRepo        repository.PatternRepository
+	modelEntityRepo    repository.ModelEntityRepository
+	passwordResetRepo  repository.PasswordResetRepository
+	documentRepo       repository.DocumentRepository
+	archiveRepo        repository.DocumentArchiveRepository
+	contentRepo        repository.DocumentContentRepository
+	shareRepo          repository.DocumentShareRepository
+	entityHashRepo     repository.EntityHashRepository
+	leakAlertRepo      repository.LeakAlertRepository
+	transferRepo       repository.DocumentTransferRepository
+	auditorGrantRepo   repository.AuditorGrantRepository
+	maintenanceRepo    repository.MaintenanceSettingsRepository
+	revisionRepo       repository.SettingsRevisionRepository
+	usageRepo          repository.UsageRepository
+	adminActionRepo    repository.AdminActionRepository
+	accountHoldRepo    repository.AccountHoldRepository
+	indexAdvisorRepo   repository.IndexAdvisorRepository
+	tenantKeyRepo      repository.TenantKeyRepository
+	reportRepo         repository.ReportRepository
+	classificationRepo repository.ClassificationRuleRepository
+	benchmarkRepo      repository.BenchmarkRepository
+	statusRepo         repository.StatusRepository
+	announcementRepo   repository.AnnouncementRepository
+	adminSearchRepo    repository.AdminSearchRepository
+	consistencyRepo    repository.SettingsConsistencyRepository
+	driveRepo          repository.DriveConnectionRepository
+	exportRepo         repository.ExportDestinationRepository
+	deliveryRepo       repository.ExportDeliveryRepository
+	ruleHitRepo        repository.RuleHitRepository
+	auditRepo          repository.ProcessingAuditRepository
+	identityRepo       repository.UserIdentityRepository
+	userExportRepo     repository.UserDataExportRepository
+	domainRepo         repository.RegistrationDomainRepository
+	catalogRepo        repository.DetectionCatalogRepository
+	erasureRepo        repository.AccountErasureRepository
+	jobRepo            repository.JobRepository
+	webhookRepo        repository.WebhookRepository
+	webhookQueueRepo   repository.WebhookDeliveryRepository
 }
 
 // setupRepositories initializes all data repositories.
@@ -265,17 +551,66 @@ var repositories struct {
 // Repositories provide a data access layer that abstracts database operations
 // and implements business logic for data validation and transformation.
 func (s *Server) setupRepositories() error {
+	// Generate the IDs of new resources with the configured strategy
+	idGenerator, err := utils.NewIDGenerator(s.Config.IDGeneration.Strategy, s.Config.IDGeneration.NodeID)
+	if err != nil {
+		return err
+	}
+	utils.SetIDGenerator(idGenerator)
+
 	// Initialize repositories
 	repositories.userRepo = repository.NewUserRepository(s.Db)
 	repositories.sessionRepo = repository.NewSessionRepository(s.Db)
 	repositories.apiKeyRepo = repository.NewAPIKeyRepository(s.Db)
+	repositories.apiKeyAdminRepo = repository.NewAPIKeyAdminRepository(s.Db)
 	repositories.settingsRepo = repository.NewSettingsRepository(s.Db)
 	repositories.banListRepo = repository.NewBanListRepository(s.Db)
 	repositories.patternRepo = repository.NewPatternRepository(s.Db)
 	repositories.modelEntityRepo = repository.NewModelEntityRepository(s.Db)
 	repositories.passwordResetRepo = repository.NewPasswordResetRepository(s.Db)
+	repositories.revisionRepo = repository.NewSettingsRevisionRepository(s.Db)
+	repositories.usageRepo = repository.NewUsageRepository(s.Db)
+	repositories.adminActionRepo = repository.NewAdminActionRepository(s.Db)
+	repositories.accountHoldRepo = repository.NewAccountHoldRepository(s.Db)
+	repositories.indexAdvisorRepo = repository.NewIndexAdvisorRepository(s.Db)
+	repositories.reportRepo = repository.NewReportRepository(s.Db)
+	repositories.classificationRepo = repository.NewClassificationRuleRepository(s.Db)
+	repositories.benchmarkRepo = repository.NewBenchmarkRepository(s.Db)
+	repositories.statusRepo = repository.NewStatusRepository(s.Db)
+	repositories.announcementRepo = repository.NewAnnouncementRepository(s.Db)
+	repositories.adminSearchRepo = repository.NewAdminSearchRepository(s.Db)
+	repositories.consistencyRepo = repository.NewSettingsConsistencyRepository(s.Db)
 	//needs an secrete witch is in the env or in the config
-	repositories.documentRepo = repository.NewDocumentRepository(s.Db, []byte(os.Getenv("API_KEY_ENCRYPTION_KEY")))
+	masterKey := []byte(os.Getenv("API_KEY_ENCRYPTION_KEY"))
+	// Each tenant's documents are encrypted with its own key, wrapped by the master key
+	repositories.tenantKeyRepo = repository.NewTenantKeyRepository(s.Db, masterKey)
+	repositories.documentRepo = repository.NewDocumentRepository(s.Db, masterKey, repositories.tenantKeyRepo)
+	repositories.archiveRepo = repository.NewDocumentArchiveRepository(s.Db)
+	repositories.contentRepo = repository.NewDocumentContentRepository(s.Db)
+	repositories.shareRepo = repository.NewDocumentShareRepository(s.Db)
+	repositories.entityHashRepo = repository.NewEntityHashRepository(s.Db)
+	repositories.leakAlertRepo = repository.NewLeakAlertRepository(s.Db)
+	repositories.transferRepo = repository.NewDocumentTransferRepository(s.Db)
+	repositories.auditorGrantRepo = repository.NewAuditorGrantRepository(s.Db)
+	repositories.maintenanceRepo = repository.NewMaintenanceSettingsRepository(s.Db)
+	// Cloud drive tokens are encrypted with the tenant's key as well
+	repositories.driveRepo = repository.NewDriveConnectionRepository(s.Db, repositories.tenantKeyRepo)
+	// So are the credentials of export destinations
+	repositories.exportRepo = repository.NewExportDestinationRepository(s.Db, repositories.tenantKeyRepo)
+	repositories.deliveryRepo = repository.NewExportDeliveryRepository(s.Db)
+	// And the files of user data exports
+	repositories.userExportRepo = repository.NewUserDataExportRepository(s.Db, repositories.tenantKeyRepo)
+	repositories.ruleHitRepo = repository.NewRuleHitRepository(s.Db)
+	repositories.auditRepo = repository.NewProcessingAuditRepository(s.Db)
+	repositories.identityRepo = repository.NewUserIdentityRepository(s.Db)
+	repositories.domainRepo = repository.NewRegistrationDomainRepository(s.Db)
+	repositories.catalogRepo = repository.NewDetectionCatalogRepository(s.Db)
+	repositories.erasureRepo = repository.NewAccountErasureRepository(s.Db)
+	// And the payloads and results of background jobs
+	repositories.jobRepo = repository.NewJobRepository(s.Db, repositories.tenantKeyRepo)
+	// And the secrets webhook deliveries are signed with
+	repositories.webhookRepo = repository.NewWebhookRepository(s.Db, repositories.tenantKeyRepo)
+	repositories.webhookQueueRepo = repository.NewWebhookDeliveryRepository(s.Db)
 
 	return nil
 }
@@ -283,12 +618,45 @@ func (s *Server) setupRepositories() error {
 // services holds all services used by the server.
 // These provide business logic implementations for the application.
 var services struct {
-	authService     *service.AuthService
-	userService     *service.UserService
-	settingsService *service.SettingsService
-	dbService       *service.DatabaseService
-	emailService    *service.EmailService
-	documentService *service.DocumentService
+	authService           *service.AuthService
+	apiKeyVerifier        *auth.CachingAPIKeyVerifier
+	userService           *service.UserService
+	settingsService       *service.SettingsService
+	detectionConfigCache  *service.DetectionConfigCache
+	dbService             *service.DatabaseService
+	emailService          *service.EmailService
+	documentService       *service.DocumentService
+	shareService          *service.DocumentShareService
+	entityHashService     *service.EntityHashService
+	transferService       *service.DocumentTransferService
+	auditorGrantService   *service.AuditorGrantService
+	classificationService *service.ClassificationService
+	usageService          *service.UsageService
+	approvalService       *service.ApprovalService
+	riskService           *service.RiskService
+	quotaService          *service.QuotaService
+	indexAdvisor          *service.IndexAdvisorService
+	benchmarkService      *service.BenchmarkService
+	reportService         *service.ReportService
+	statusService         *service.StatusService
+	announcementService   *service.AnnouncementService
+	adminSearchService    *service.AdminSearchService
+	consistencyService    *service.SettingsConsistencyService
+	driveService          *service.DriveService
+	exportService         *service.ExportService
+	webhookService        *service.WebhookService
+	sloService            *service.SLOService
+	effectivenessService  *service.RuleEffectivenessService
+	maintenanceService    *service.MaintenanceService
+	apiKeyAdminService    *service.APIKeyAdminService
+	oauthService          *service.OAuthService
+	permissionService     *service.PermissionService
+	userDataExportService *service.UserDataExportService
+	domainService         *service.RegistrationDomainService
+	catalogService        *service.DetectionCatalogService
+	erasureService        *service.AccountErasureService
+	jobService            *service.JobService
+	projectionService     *service.ProjectionService
 }
 
 // setupServices initializes all business services.
@@ -317,20 +685,55 @@ func (s *Server) setupServices() error {
 		&s.Config.APIKey,
 	)
 
+	// Enforce the expiry policy administrators set on new and rotated API keys
+	services.authService.SetAPIKeyPolicySource(repositories.apiKeyAdminRepo)
+
+	// Sign users in with Google and Microsoft; the sign-in flows are signed with the JWT secret
+	services.authService.SetIdentityRepository(repositories.identityRepo)
+	services.oauthService = service.NewOAuthService(
+		service.NewOAuthProviders(&s.Config.OAuth, nil),
+		services.authService,
+		&s.Config.OAuth,
+		s.Config.JWT.Secret,
+	)
+
+	// Reuse API key verifications for a short time, as verifying decrypts every stored key
+	services.apiKeyVerifier = auth.NewCachingAPIKeyVerifier(services.authService, &s.Config.APIKey)
+
 	services.userService = service.NewUserService(
 		repositories.userRepo,
 		repositories.sessionRepo,
 		repositories.apiKeyRepo,
 		s.authProviders.PasswordCfg,
 	)
+	services.userService.SetTenantKeyRepository(repositories.tenantKeyRepo)
+
+	// Users erase their own accounts through a background job after a grace period
+	services.erasureService = service.NewAccountErasureService(
+		repositories.erasureRepo,
+		repositories.userRepo,
+		repositories.sessionRepo,
+		repositories.apiKeyRepo,
+		repositories.documentRepo,
+		repositories.tenantKeyRepo,
+		s.authProviders.PasswordCfg,
+	)
 
 	services.settingsService = service.NewSettingsService(
 		repositories.settingsRepo,
 		repositories.banListRepo,
 		repositories.patternRepo,
 		repositories.modelEntityRepo,
+		repositories.revisionRepo,
 	)
 
+	// Serve bursts of detection requests for one user from a single settings read
+	services.detectionConfigCache = service.NewDetectionConfigCache(s.Config.SettingsCache.DetectionConfigTTL)
+	services.settingsService.SetDetectionConfigCache(services.detectionConfigCache)
+
+	// Commit every settings change together with its entry in the change feed
+	services.settingsService.SetTransactor(s.Db)
+
 	services.dbService = service.NewDatabaseService(s.Db)
 
 	// Initialize the new EmailService
@@ -339,13 +742,268 @@ func (s *Server) setupServices() error {
 		return fmt.Errorf("failed to initialize EmailService: %w", err)
 	}
 	services.emailService = emailService
+	services.authService.SetAPIKeyRotationNotifier(services.emailService)
+
+	// Initialize the administration of all users' API keys; owners are emailed about rotation campaigns
+	services.apiKeyAdminService = service.NewAPIKeyAdminService(repositories.apiKeyRepo, repositories.apiKeyAdminRepo)
+	services.apiKeyAdminService.SetRotationCampaignNotifier(services.emailService)
+
+	// Initialize the permission introspection, which reports the API key expiry policy among others
+	services.permissionService = service.NewPermissionService(s.Config, repositories.apiKeyAdminRepo)
+
+	// Initialize the usage tracking used for billing
+	services.usageService = service.NewUsageService(repositories.usageRepo)
 
 	// Initialize the new DocumentService
-	services.documentService = service.NewDocumentService(repositories.documentRepo)
+	services.documentService = service.NewDocumentService(repositories.documentRepo, repositories.auditRepo, services.usageService)
+
+	// Refuse uploads over the configured limits before processing them
+	services.documentService.SetUploadLimits(&s.Config.UploadLimits)
+
+	// Classify uploaded documents by the classification rules in the user's settings
+	services.classificationService = service.NewClassificationService(repositories.classificationRepo, services.settingsService, repositories.revisionRepo)
+	services.classificationService.SetTransactor(s.Db)
+	services.documentService.SetClassifier(services.classificationService)
+
+	// Scrub ban list words and search pattern matches from uploaded filenames for users who enabled it
+	services.documentService.SetFilenameScrubber(service.NewFilenameScrubber(services.settingsService))
+
+	// Preview which documents the users' document retention settings delete
+	services.documentService.SetRetentionSettings(services.settingsService)
+
+	// Move documents nobody has touched for a long time to cold storage
+	services.documentService.SetArchive(repositories.archiveRepo, &s.Config.DocumentArchive)
+	// Email owners of documents left unfinished, then archive or delete them after the notice
+	services.documentService.SetStaleDocuments(repositories.userRepo, services.emailService, &s.Config.StaleDocuments)
+
+	// Keep the PDF of uploaded documents, encrypted with the tenant's key, on disk or in S3
+	documentStore, err := service.NewDocumentStore(&s.Config.DocumentStorage)
+	if err != nil {
+		return fmt.Errorf("failed to set up document storage: %w", err)
+	}
+	services.documentService.SetContentStorage(repositories.contentRepo, documentStore, repositories.tenantKeyRepo)
+
+	// Let users read and redact documents other users shared with them
+	services.documentService.SetShares(repositories.shareRepo)
+	services.shareService = service.NewDocumentShareService(repositories.shareRepo, repositories.documentRepo, repositories.userRepo)
+
+	// Hash the detected values with the tenant's key, so analytics never see them
+	services.entityHashService = service.NewEntityHashService(repositories.entityHashRepo, repositories.tenantKeyRepo)
+	services.documentService.SetEntityIndexer(services.entityHashService)
+	// Email users when the same value keeps appearing across their documents
+	services.entityHashService.SetLeakAlerter(service.NewLeakAlertService(repositories.entityHashRepo, repositories.leakAlertRepo, repositories.userRepo, services.emailService, &s.Config.LeakAlerts))
+
+	// Let administrators hand the documents of a leaving employee over to a colleague
+	services.transferService = service.NewDocumentTransferService(repositories.transferRepo, repositories.documentRepo, repositories.userRepo, services.documentService)
+	// Give external auditors time-boxed, read-only access instead of user accounts
+	services.auditorGrantService = service.NewAuditorGrantService(repositories.auditorGrantRepo, repositories.documentRepo, services.documentService)
+
+	// Initialize the export of all data kept about a user, for requests under GDPR Article 15
+	services.userDataExportService = service.NewUserDataExportService(repositories.userRepo, services.settingsService, services.documentService, repositories.documentRepo)
+	services.userDataExportService.SetJobs(repositories.userExportRepo)
+
+	// Run the detection, redaction and export of large documents as background jobs, with
+	// the features offered when they were queued
+	services.jobService = service.NewJobService(repositories.jobRepo, services.documentService)
+	services.jobService.SetFeatureSource(services.permissionService)
+
+	// Stream job progress and settings changes to the clients of each user; the database
+	// relays the notifications between servers once the server starts
+	var notificationDB *sql.DB
+	if s.Db != nil {
+		notificationDB = s.Db.DB
+	}
+	s.notifications = newNotificationHub(notificationDB)
+	services.jobService.SetNotifier(s.notifications)
+	services.settingsService.SetNotifier(s.notifications)
+
+	// Initialize the two-person approval workflow for destructive admin actions.
+	// Tenants are user accounts, so erasing a user and deleting a tenant both remove
+	// the account, which cascades to everything it owns.
+	services.approvalService = service.NewApprovalService(repositories.adminActionRepo, &s.Config.Approvals)
+	services.approvalService.RegisterExecutor(models.ActionUserErasure, services.userService.DeleteUser)
+	services.approvalService.RegisterExecutor(models.ActionTenantDeletion, services.userService.DeleteUser)
+	services.approvalService.RegisterExecutor(models.ActionAdminPromotion, services.userService.GrantAdminRole)
+
+	// Initialize risk scoring of registrations and logins to stop fraudulent sign-ups
+	services.riskService = service.NewRiskService(repositories.accountHoldRepo, &s.Config.Risk, services.emailService)
+	services.authService.SetRiskService(services.riskService)
+
+	// Limit new accounts to the organization's verified email domains, if any were added
+	services.domainService = service.NewRegistrationDomainService(repositories.domainRepo)
+	services.authService.SetRegistrationDomains(services.domainService)
+
+	// Export and import the detection methods and entity-type taxonomy between environments
+	services.catalogService = service.NewDetectionCatalogService(repositories.catalogRepo)
+
+	// Initialize the detection quota. With Redis the buckets are shared with the
+	// detection service, which runs the same token bucket script on the same keys.
+	var quotaStore service.QuotaStore
+	if s.Config.Quota.RedisURL != "" {
+		redisClient, err := database.NewRedisClient(s.Config.Quota.RedisURL, constants.DefaultRedisPoolSize)
+		if err != nil {
+			return fmt.Errorf("failed to initialize Redis client: %w", err)
+		}
+		// The client connects lazily, so wait until Redis answers before relying on it
+		err = database.WaitFor(context.Background(), "redis", &s.Config.Startup, func(ctx context.Context) error {
+			pingCtx, cancel := context.WithTimeout(ctx, constants.RedisDialTimeout)
+			defer cancel()
+			_, err := redisClient.Do(pingCtx, "PING")
+			return err
+		})
+		if err != nil {
+			return fmt.Errorf("failed to connect to Redis: %w", err)
+		}
+		s.Redis = redisClient
+		quotaStore = service.NewRedisQuotaStore(redisClient, s.Config.Quota.Capacity, s.Config.Quota.RefillInterval)
+	} else {
+		log.Warn().Msg("REDIS_URL not set, detection quota is kept in memory and not shared with the detection service")
+		quotaStore = service.NewMemoryQuotaStore(s.Config.Quota.Capacity, s.Config.Quota.RefillInterval)
+	}
+	services.quotaService = service.NewQuotaService(quotaStore, &s.Config.Quota)
+	services.quotaService.SetWarningNotifier(repositories.userRepo, services.emailService)
+
+	// Initialize the index advisor, which watches the largest tables for missing indexes
+	services.indexAdvisor = service.NewIndexAdvisorService(repositories.indexAdvisorRepo)
+
+	// Initialize the differentially private benchmarks of the tenants that opted in
+	services.benchmarkService = service.NewBenchmarkService(repositories.benchmarkRepo, &s.Config.Benchmarks)
+
+	// Initialize the scheduled reports, which are delivered by email
+	services.reportService = service.NewReportService(repositories.reportRepo, services.emailService)
+
+	// Initialize the status page; components without a configured endpoint are left off
+	var dbHealth service.DatabaseHealthSource
+	if s.Db.Monitor != nil {
+		dbHealth = s.Db.Monitor
+	}
+	checkers := []service.ComponentChecker{
+		service.NewAPIChecker(),
+		service.NewDatabaseChecker(s.Db, dbHealth, &s.Config.LoadShedding),
+	}
+	if s.Config.StatusPage.DetectionServiceURL != "" {
+		checkers = append(checkers, service.NewHTTPChecker(constants.StatusComponentDetection, s.Config.StatusPage.DetectionServiceURL, nil))
+	}
+	if s.Redis != nil {
+		checkers = append(checkers, service.NewRedisChecker(s.Redis))
+	}
+	services.statusService = service.NewStatusService(repositories.statusRepo, &s.Config.StatusPage, checkers...)
+
+	// Initialize the in-product announcements, such as maintenance windows and new-feature notices
+	services.announcementService = service.NewAnnouncementService(repositories.announcementRepo)
+
+	// Initialize the administrator search across users, documents, API keys and sessions
+	services.adminSearchService = service.NewAdminSearchService(repositories.adminSearchRepo)
+
+	// Initialize the consistency check of each user's settings, ban list and their rows
+	services.consistencyService = service.NewSettingsConsistencyService(repositories.consistencyRepo)
+
+	// Initialize the cloud drive connectors; the authorization state is signed with the JWT secret
+	services.driveService = service.NewDriveService(
+		repositories.driveRepo,
+		service.NewDriveProviders(&s.Config.Drives, nil),
+		&s.Config.Drives,
+		s.Config.JWT.Secret,
+	)
+
+	// Deliver saved documents to the users' export destinations; SharePoint is written
+	// through the user's drive connection
+	services.exportService = service.NewExportService(
+		repositories.exportRepo,
+		repositories.deliveryRepo,
+		services.documentService,
+		service.NewExportTransports(&s.Config.Exports, services.driveService),
+		&s.Config.Exports,
+	)
+	services.documentService.SetDeliverer(services.exportService)
+
+	// Send account and document events to the users' webhooks
+	services.webhookService = service.NewWebhookService(
+		repositories.webhookRepo,
+		repositories.webhookQueueRepo,
+		&s.Config.Webhooks,
+	)
+	services.documentService.SetWebhooks(services.webhookService)
+	services.authService.SetWebhooks(services.webhookService)
+
+	// Alert the administrators when the API burns the error budget of its SLOs
+	s.requestMetrics = middleware.NewRequestMetrics(s.Config.SLO.LatencyThreshold)
+	services.sloService = service.NewSLOService(s.requestMetrics, repositories.userRepo, &s.Config.SLO)
+	services.sloService.SetNotifier(s.notifications)
+	services.sloService.SetWebhooks(services.webhookService)
+
+	// Track which ban list words and search patterns produce the detections of saved documents
+	services.effectivenessService = service.NewRuleEffectivenessService(repositories.ruleHitRepo, services.settingsService)
+	services.documentService.SetRuleTracker(services.effectivenessService)
+
+	// Let administrators rebuild the derived tables from the event log once a bug in their
+	// aggregation is fixed
+	services.projectionService = service.NewProjectionService()
+	services.projectionService.Register(constants.ProjectionTenantUsage, "Page and API call counts of the monthly tenant usage rollups", []string{constants.EventUsageCounted}, repositories.usageRepo.RebuildCounters)
+	services.projectionService.Register(constants.ProjectionRuleHits, "Detection counts of users' ban list words and search patterns", []string{constants.EventRuleHitsRecorded}, repositories.ruleHitRepo.Rebuild)
+
+	// Screen free-text fields that bypass the document redaction pipeline for personal data,
+	// using each user's search patterns and ban list in addition to the built-in detectors
+	piiScreener := service.NewPIIScreener(&s.Config.PIIScreening, services.settingsService)
+	services.authService.SetPIIScreener(piiScreener)
+	services.approvalService.SetPIIScreener(piiScreener)
+	services.transferService.SetPIIScreener(piiScreener)
+	services.auditorGrantService.SetPIIScreener(piiScreener)
+	services.statusService.SetPIIScreener(piiScreener)
+	services.announcementService.SetPIIScreener(piiScreener)
+
+	s.registerMaintenanceTasks()
 
 	return nil
 }
 
+// registerMaintenanceTasks registers the periodic maintenance tasks, in the order they run.
+// Administrators can change the settings of each task and run it on demand through the
+// admin API. Only the tasks deleting expired rows in bulk honor a batch size and dry runs.
+func (s *Server) registerMaintenanceTasks() {
+	services.maintenanceService = service.NewMaintenanceService(repositories.maintenanceRepo)
+	services.maintenanceService.RegisterBatch(constants.MaintenanceTaskSessions, "Delete expired sessions", 0, services.authService.CleanupExpiredSessions)
+	services.maintenanceService.Register(constants.MaintenanceTaskIdleSessions, "Delete sessions unused for longer than the idle timeout", services.authService.CleanupIdleSessions)
+	services.maintenanceService.RegisterBatch(constants.MaintenanceTaskAPIKeys, "Delete expired API keys", 0, services.authService.CleanupExpiredAPIKeys)
+	services.maintenanceService.Register(constants.MaintenanceTaskGDPRLogs, "Rotate and remove GDPR logs past their retention", func(ctx context.Context) (int64, error) {
+		// The GDPR logger is set up after the services
+		if s.gdprLogger == nil {
+			return 0, nil
+		}
+		return 0, s.gdprLogger.CleanupLogs()
+	})
+	services.maintenanceService.Register(constants.MaintenanceTaskUsageRollup, "Persist tenant usage for the billing export", func(ctx context.Context) (int64, error) {
+		return 0, services.usageService.RollupUsage(ctx)
+	})
+	services.maintenanceService.Register(constants.MaintenanceTaskAdminActions, "Expire admin actions that were not approved in time", services.approvalService.ExpirePendingActions)
+	services.maintenanceService.Register(constants.MaintenanceTaskIndexAdvisor, "Look for missing indexes once per index advisor interval", func(ctx context.Context) (int64, error) {
+		count, err := services.indexAdvisor.RunIfDue(ctx)
+		return int64(count), err
+	})
+	services.maintenanceService.Register(constants.MaintenanceTaskReencryption, "Move documents encrypted with the master key to tenant keys", services.documentService.ReencryptLegacy)
+	services.maintenanceService.Register(constants.MaintenanceTaskSchemaNormalization, "Convert legacy redaction schemas to page-relative coordinates", services.documentService.NormalizeLegacySchemas)
+	services.maintenanceService.Register(constants.MaintenanceTaskEntitySummaries, "Compute the entity counts and types of documents stored before document search", services.documentService.SummarizeLegacyEntities)
+	services.maintenanceService.RegisterBatch(constants.MaintenanceTaskDocumentRetention, "Delete documents whose retention, set by a classification rule or the owner's settings, has passed", 0, services.documentService.DeleteExpiredDocuments)
+	services.maintenanceService.Register(constants.MaintenanceTaskDocumentArchival, "Move documents unchanged for longer than the archive threshold to cold storage", services.documentService.ArchiveOldDocuments)
+	services.maintenanceService.Register(constants.MaintenanceTaskStaleDocuments, "Tell owners about stale documents and archive or delete them after the notice", services.documentService.CleanUpStaleDocuments)
+	services.maintenanceService.Register(constants.MaintenanceTaskDocumentContents, "Delete the stored PDF content of deleted documents", services.documentService.DeleteOrphanedContents)
+	services.maintenanceService.Register(constants.MaintenanceTaskUserDataExports, "Delete user data exports finished longer ago than their retention", services.userDataExportService.DeleteExpiredJobs)
+	services.maintenanceService.Register(constants.MaintenanceTaskJobs, "Delete background jobs finished longer ago than their retention", services.jobService.DeleteExpiredJobs)
+	services.maintenanceService.Register(constants.MaintenanceTaskBenchmarks, "Publish the anonymized cross-tenant benchmarks once per benchmark interval", func(ctx context.Context) (int64, error) {
+		count, err := services.benchmarkService.RunIfDue(ctx)
+		return int64(count), err
+	})
+	services.maintenanceService.Register(constants.MaintenanceTaskReports, "Send the scheduled reports that are due", func(ctx context.Context) (int64, error) {
+		count, err := services.reportService.SendDueReports(ctx)
+		return int64(count), err
+	})
+	services.maintenanceService.Register(constants.MaintenanceTaskUptime, "Prune status page uptime older than the page shows", services.statusService.PruneUptime)
+	services.maintenanceService.Register(constants.MaintenanceTaskSettingsConsistency, "Create missing settings and ban lists and delete orphaned settings rows", services.consistencyService.Repair)
+	services.maintenanceService.Register(constants.MaintenanceTaskExportDeliveries, "Retry failed deliveries to export destinations", services.exportService.RetryFailed)
+	services.maintenanceService.Register(constants.MaintenanceTaskWebhookDeliveries, "Delete webhook deliveries older than their retention", services.webhookService.DeleteExpiredDeliveries)
+	services.maintenanceService.Register(constants.MaintenanceTaskAccountErasures, "Erase the accounts whose grace period has passed", services.erasureService.RunDueErasures)
+}
+
 // setupHandlers initializes all HTTP request handlers.
 // It creates handler instances using the previously initialized services.
 //
@@ -358,13 +1016,51 @@ func (s *Server) setupHandlers() error {
 	// Initialize handlers with proper dependency injection
 	s.Handlers = &Handlers{
 		AuthHandler: handlers.NewAuthHandler(services.authService, s.authProviders.JWTService),
-		UserHandler: handlers.NewUserHandler(services.userService),
+		UserHandler: handlers.NewUserHandler(services.userService, services.approvalService),
 		// services.settingsService implicitly implements handlers.SettingsServiceInterface
 		SettingsHandler: handlers.NewSettingsHandler(services.settingsService),
 		DocumentHandler: handlers.NewDocumentHandler(services.documentService),
-
-		PasswordResetHandler: handlers.NewPasswordResetHandler(repositories.userRepo, &repositories.passwordResetRepo, services.emailService, s.authProviders.PasswordCfg),
+		// Sharing of documents between users for collaborative redaction
+		DocumentShareHandler: handlers.NewDocumentShareHandler(services.shareService),
+		DuplicateLeakHandler: handlers.NewDuplicateLeakHandler(services.entityHashService),
+
+		PasswordResetHandler:    handlers.NewPasswordResetHandler(repositories.userRepo, &repositories.passwordResetRepo, services.emailService, s.authProviders.PasswordCfg),
+		UsageHandler:            handlers.NewUsageHandler(services.usageService),
+		ApprovalHandler:         handlers.NewApprovalHandler(services.approvalService),
+		DocumentTransferHandler: handlers.NewDocumentTransferHandler(services.transferService),
+		AuditorGrantHandler:     handlers.NewAuditorGrantHandler(services.auditorGrantService),
+		RiskHandler:             handlers.NewRiskHandler(services.riskService),
+		QuotaHandler:            handlers.NewQuotaHandler(services.quotaService),
+		PermissionHandler:       handlers.NewPermissionHandler(services.permissionService),
+		UserDataExportHandler:   handlers.NewUserDataExportHandler(services.userDataExportService),
+		AccountErasureHandler:   handlers.NewAccountErasureHandler(services.erasureService),
+		JobHandler:              handlers.NewJobHandler(services.jobService),
+		NotificationHandler:     handlers.NewNotificationHandler(s.notifications),
+		DiagnosticsHandler:      handlers.NewDiagnosticsHandler(services.dbService),
+		AnalyticsHandler:        handlers.NewAnalyticsHandler(services.indexAdvisor),
+		ClassificationHandler:   handlers.NewClassificationHandler(services.classificationService),
+		BenchmarkHandler:        handlers.NewBenchmarkHandler(services.benchmarkService),
+		ReportHandler:           handlers.NewReportHandler(services.reportService),
+		StatusHandler:           handlers.NewStatusHandler(services.statusService, s.Config.StatusPage.CacheTTL),
+		AnnouncementHandler:     handlers.NewAnnouncementHandler(services.announcementService),
+		AdminSearchHandler:      handlers.NewAdminSearchHandler(services.adminSearchService),
+		DriveHandler:            handlers.NewDriveHandler(services.driveService),
+		ExportHandler:           handlers.NewExportHandler(services.exportService),
+		WebhookHandler:          handlers.NewWebhookHandler(services.webhookService),
+		SchemaHandler:           handlers.NewSchemaHandler(),
+		MaintenanceHandler:      handlers.NewMaintenanceHandler(services.maintenanceService),
+		ProjectionHandler:       handlers.NewProjectionHandler(services.projectionService),
+		ConfigHandler:           handlers.NewConfigHandler(s.Config),
+
+		SettingsConsistencyHandler: handlers.NewSettingsConsistencyHandler(services.consistencyService),
+		RuleEffectivenessHandler:   handlers.NewRuleEffectivenessHandler(services.effectivenessService),
+		ProcessingRegisterHandler:  handlers.NewProcessingRegisterHandler(),
+		APIKeyAdminHandler:         handlers.NewAPIKeyAdminHandler(services.apiKeyAdminService),
+		OAuthHandler:               handlers.NewOAuthHandler(services.oauthService, s.authProviders.JWTService, s.Config.OAuth.LoginRedirectURL),
+		RegistrationDomainHandler:  handlers.NewRegistrationDomainHandler(services.domainService),
+		DetectionCatalogHandler:    handlers.NewDetectionCatalogHandler(services.catalogService),
 	}
+	s.Handlers.DiagnosticsHandler.SetCaches(services.detectionConfigCache)
 
 	// Validate that services are properly initialized
 	if s.Handlers.AuthHandler == nil {
@@ -381,23 +1077,18 @@ func (s *Server) setupHandlers() error {
 //   - An error if the server fails to start or encounters an error during operation
 //
 // This method performs the following operations:
-// 1. Starts the HTTP server in a separate goroutine
+// 1. Passes requests to the router, which the server has been listening for since NewServer
 // 2. Sets up signal handling for graceful shutdown (SIGINT, SIGTERM)
 // 3. Initializes periodic maintenance tasks
-// 4. Blocks until an error occurs or a shutdown signal is received
-// 5. Performs graceful shutdown when requested
+// 4. Notifies systemd that the server is ready and feeds its watchdog
+// 5. Blocks until an error occurs or a shutdown signal is received
+// 6. Performs graceful shutdown when requested
 func (s *Server) Start() error {
-	// Create a channel to listen for errors from the server
-	serverErrors := make(chan error, 1)
-
-	// Start the server in a separate goroutine
-	go func() {
-		log.Info().
-			Str("address", s.Config.Server.ServerAddress()).
-			Msg("Starting server")
-
-		serverErrors <- s.httpServer.ListenAndServe()
-	}()
+	// Serve the API and mark the server ready
+	s.probes.serve(s.router, s.readinessChecks())
+	log.Info().
+		Str("address", s.Config.Server.ServerAddress()).
+		Msg("Starting server")
 
 	// Create a channel to listen for OS signals
 	shutdown := make(chan os.Signal, 1)
@@ -406,9 +1097,20 @@ func (s *Server) Start() error {
 	// Set up maintenance tasks
 	s.SetupMaintenanceTasks()
 
+	// Relay notifications between servers, and end the event streams at shutdown
+	s.relayNotifications()
+	s.httpServer.RegisterOnShutdown(s.notifications.close)
+
+	s.systemd.notify("READY=1\nSTATUS=Serving requests")
+	if interval := s.systemd.watchdogInterval(); interval > 0 {
+		stopWatchdog := make(chan struct{})
+		defer close(stopWatchdog)
+		go s.feedWatchdog(interval, stopWatchdog)
+	}
+
 	// Block until an OS signal or an error is received
 	select {
-	case err := <-serverErrors:
+	case err := <-s.serveErrors:
 		return fmt.Errorf("server error: %w", err)
 	case sig := <-shutdown:
 		log.Info().
@@ -432,6 +1134,60 @@ func (s *Server) Start() error {
 	return nil
 }
 
+// readinessChecks returns the dependencies checked by the readiness probe: the database and,
+// if configured, Redis.
+func (s *Server) readinessChecks() []readinessCheck {
+	checks := []readinessCheck{{name: "database", check: s.Db.HealthCheck}}
+	if s.Redis != nil {
+		checks = append(checks, readinessCheck{name: "redis", check: func(ctx context.Context) error {
+			_, err := s.Redis.Do(ctx, "PING")
+			return err
+		}})
+	}
+	return checks
+}
+
+// relayNotifications starts relaying notifications between the servers through the
+// database. Without the relay, notifications reach the event streams of this server only.
+func (s *Server) relayNotifications() {
+	listener, err := database.NewListener(s.Config, constants.NotificationChannel)
+	if err != nil {
+		log.Warn().Err(err).Msg("Failed to listen for notifications; they reach the event streams of this server only")
+		return
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		s.notifications.relay(ctx, listener.Notify, listener.Ping)
+	}()
+
+	s.stopNotifications = func() {
+		cancel()
+		<-done
+		if err := listener.Close(); err != nil {
+			log.Warn().Err(err).Msg("Failed to close notification listener")
+		}
+	}
+}
+
+// feedWatchdog notifies the systemd watchdog at the given interval until stop is closed.
+// The notifications are sent from the serving process, so a hung server gets restarted.
+func (s *Server) feedWatchdog(interval time.Duration, stop <-chan struct{}) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ticker.C:
+			s.systemd.notify("WATCHDOG=1")
+		case <-stop:
+			return
+		}
+	}
+}
+
 // Shutdown gracefully shuts down the server, closing all connections properly.
 // It ensures in-flight requests are completed before shutting down.
 //
@@ -443,9 +1199,14 @@ func (s *Server) Start() error {
 //
 // This method performs the following cleanup operations:
 // 1. Gracefully shuts down the HTTP server, waiting for in-flight requests
-// 2. Closes the database connection
-// 3. Performs GDPR log cleanup if needed
+// 2. Persists pending tenant usage counters
+// 3. Closes the database and Redis connections
+// 4. Performs GDPR log cleanup if needed
 func (s *Server) Shutdown(ctx context.Context) error {
+	// Fail the readiness probe while in-flight requests finish
+	s.probes.setPhase(constants.PhaseStopping)
+	s.systemd.notify("STOPPING=1")
+
 	// Shutdown the HTTP server
 	if err := s.httpServer.Shutdown(ctx); err != nil {
 		return fmt.Errorf("server shutdown error: %w", err)
@@ -453,10 +1214,59 @@ func (s *Server) Shutdown(ctx context.Context) error {
 
 	log.Info().Msg("Server stopped gracefully")
 
+	// Stop relaying notifications; the event streams ended with the HTTP server
+	if s.stopNotifications != nil {
+		s.stopNotifications()
+	}
+
+	// Let the running user data export store its checkpoint so the next server resumes it
+	if s.stopExports != nil {
+		s.stopExports()
+		select {
+		case <-s.exportsDone:
+			log.Info().Msg("User data exports interrupted")
+		case <-ctx.Done():
+			log.Warn().Msg("User data exports did not stop in time; they resume once their lease expires")
+		}
+	}
+
+	// Let the running background jobs finish so they are not attempted again
+	if s.stopJobs != nil {
+		s.stopJobs()
+		select {
+		case <-s.jobsDone:
+			log.Info().Msg("Background job workers stopped")
+		case <-ctx.Done():
+			log.Warn().Msg("Background jobs did not finish in time; they are retried once their lease expires")
+		}
+	}
+
+	// Let the webhook delivery in progress be recorded so it is not made twice
+	if s.stopWebhooks != nil {
+		s.stopWebhooks()
+		select {
+		case <-s.webhooksDone:
+			log.Info().Msg("Webhook deliveries stopped")
+		case <-ctx.Done():
+			log.Warn().Msg("Webhook deliveries did not stop in time; they are retried once their lease expires")
+		}
+	}
+
+	// Persist usage counted since the last rollup so it is not lost
+	if services.usageService != nil {
+		if err := services.usageService.RollupUsage(ctx); err != nil {
+			log.Warn().Err(err).Msg("Failed to roll up tenant usage during shutdown")
+		}
+	}
+
 	// Close the database connection
 	s.Db.Close()
 	log.Info().Msg("Database connection closed")
 
+	if s.Redis != nil {
+		s.Redis.Close()
+	}
+
 	// Clean up any GDPR logging resources if needed
 	if s.gdprLogger != nil {
 		if err := s.gdprLogger.CleanupLogs(); err != nil {
@@ -474,37 +1284,98 @@ func (s *Server) Shutdown(ctx context.Context) error {
 // 1. Cleaning up expired sessions to prevent database bloat
 // 2. Cleaning up expired API keys for security and performance
 // 3. Rotating and cleaning up GDPR logs according to retention policies
+// 4. Rolling up tenant usage for the billing export
+// 5. Expiring admin actions that were not approved in time
+// 6. Running the index advisor once per constants.IndexAdvisorInterval
+// 7. Moving documents encrypted with the master key to tenant keys
+// 8. Sending scheduled reports that are due
+// 9. Pruning status page uptime rollups older than constants.StatusUptimeRetention
+// 10. Publishing the cross-tenant benchmarks once per constants.BenchmarkInterval
 //
-// The tasks run on a fixed schedule defined by constants.DBMaintenanceInterval.
-// Each task has its own timeout to prevent long-running operations from blocking others.
+// The tasks are registered by registerMaintenanceTasks. Each enabled task runs on its own
+// interval, constants.DBMaintenanceInterval unless an administrator stored other settings;
+// administrators can also run them on demand. The scheduler looks for due tasks every
+// constants.MaintenanceSchedulerTick and gives them constants.MaintenanceRunTimeout to finish.
+// The status page components are checked, and the SLO burn rates evaluated, on their own,
+// more frequent schedules.
 func (s *Server) SetupMaintenanceTasks() {
-	// Set up a ticker for maintenance tasks
-	ticker := time.NewTicker(constants.DBMaintenanceInterval)
+	// Check the status page components on their own schedule
+	checkInterval := s.Config.StatusPage.CheckInterval
+	if checkInterval <= 0 {
+		checkInterval = constants.DefaultStatusCheckInterval
+	}
+	statusTicker := time.NewTicker(checkInterval)
 	go func() {
-		for range ticker.C {
-			// Create a context with a timeout
-			ctx, cancel := context.WithTimeout(context.Background(), 5*time.Minute)
-
-			// Cleanup expired sessions
-			if count, err := services.authService.CleanupExpiredSessions(ctx); err != nil {
-				log.Error().Err(err).Msg("Failed to cleanup expired sessions")
-			} else if count > 0 {
-				log.Info().Int64("count", count).Msg("Cleaned up expired sessions")
+		for range statusTicker.C {
+			ctx, cancel := context.WithTimeout(context.Background(), checkInterval)
+			if err := services.statusService.RunChecks(ctx); err != nil {
+				log.Error().Err(err).Msg("Failed to record status page checks")
 			}
+			cancel()
+		}
+	}()
 
-			// Cleanup expired API keys
-			if count, err := services.authService.CleanupExpiredAPIKeys(ctx); err != nil {
-				log.Error().Err(err).Msg("Failed to cleanup expired API keys")
-			} else if count > 0 {
-				log.Info().Int64("count", count).Msg("Cleaned up expired API keys")
+	// Evaluate the SLO burn rates on their own schedule
+	sloInterval := s.Config.SLO.EvaluationInterval
+	if sloInterval <= 0 {
+		sloInterval = constants.DefaultSLOEvaluationInterval
+	}
+	sloTicker := time.NewTicker(sloInterval)
+	go func() {
+		for range sloTicker.C {
+			ctx, cancel := context.WithTimeout(context.Background(), sloInterval)
+			if err := services.sloService.Evaluate(ctx); err != nil {
+				log.Error().Err(err).Msg("Failed to send SLO alerts")
 			}
+			cancel()
+		}
+	}()
 
-			// GDPR log rotation and cleanup
-			if s.gdprLogger != nil {
-				if err := s.gdprLogger.CleanupLogs(); err != nil {
-					log.Error().Err(err).Msg("Failed to clean up expired GDPR logs")
-				}
-			}
+	// Run user data export jobs until shutdown
+	var exportCtx context.Context
+	exportCtx, s.stopExports = context.WithCancel(context.Background())
+	s.exportsDone = make(chan struct{})
+	go func() {
+		defer close(s.exportsDone)
+		services.userDataExportService.RunJobs(exportCtx)
+	}()
+
+	// Run background jobs until shutdown
+	pool := worker.NewPool(repositories.jobRepo, constants.JobWorkers)
+	services.jobService.Register(pool)
+	var jobCtx context.Context
+	jobCtx, s.stopJobs = context.WithCancel(context.Background())
+	s.jobsDone = make(chan struct{})
+	go func() {
+		defer close(s.jobsDone)
+		pool.Run(jobCtx)
+	}()
+
+	// Deliver events to the users' webhooks until shutdown
+	var webhookCtx context.Context
+	webhookCtx, s.stopWebhooks = context.WithCancel(context.Background())
+	s.webhooksDone = make(chan struct{})
+	go func() {
+		defer close(s.webhooksDone)
+		services.webhookService.RunDeliveries(webhookCtx)
+	}()
+
+	// Set up a ticker for maintenance tasks
+	ticker := time.NewTicker(constants.MaintenanceSchedulerTick)
+	go func() {
+		// Apply the task settings administrators stored; tasks keep the defaults if this fails
+		loadCtx, cancelLoad := context.WithTimeout(context.Background(), constants.DBConnectionTimeout)
+		if err := services.maintenanceService.LoadSettings(loadCtx); err != nil {
+			log.Error().Err(err).Msg("Failed to load maintenance task settings, using defaults")
+		}
+		cancelLoad()
+
+		for range ticker.C {
+			// Create a context with a timeout
+			ctx, cancel := context.WithTimeout(context.Background(), constants.MaintenanceRunTimeout)
+
+			// Run the tasks that are due; failures are logged and do not stop the others
+			services.maintenanceService.RunDue(ctx)
 
 			// Call cancel at the end of each iteration to avoid resource leak
 			cancel()
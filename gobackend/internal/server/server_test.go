@@ -2,7 +2,10 @@ package server
 
 import (
 	"context"
+	"crypto/tls"
 	"database/sql"
+	"io"
+	"net"
 	"net/http"
 	"net/http/httptest"
 	"os"
@@ -13,6 +16,7 @@ import (
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/mock"
 	"github.com/yasinhessnawi1/Hideme_Backend/internal/config"
+	"golang.org/x/net/http2"
 )
 
 // MockDB implements a mock for the database.Pool
@@ -163,14 +167,10 @@ func TestServerCreation(t *testing.T) {
 		router: chi.NewRouter(),
 	}
 
-	// Manually set up the HTTP server as NewServer would
-	server.httpServer = &http.Server{
-		Addr:         cfg.Server.ServerAddress(),
-		Handler:      server.router,
-		ReadTimeout:  cfg.Server.ReadTimeout,
-		WriteTimeout: cfg.Server.WriteTimeout,
-		IdleTimeout:  120 * time.Second,
-	}
+	// Set up the HTTP server as NewServer would
+	httpServer, err := newHTTPServer(&cfg.Server, server.router)
+	assert.NoError(t, err)
+	server.httpServer = httpServer
 
 	// Verify the server is configured correctly
 	assert.Equal(t, cfg, server.Config)
@@ -179,6 +179,62 @@ func TestServerCreation(t *testing.T) {
 	assert.Equal(t, cfg.Server.ServerAddress(), server.httpServer.Addr)
 }
 
+func TestNewHTTPServer(t *testing.T) {
+	handler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte(r.Proto))
+	})
+	settings := &config.ServerSettings{
+		Host:                      "localhost",
+		Port:                      8081,
+		ReadTimeout:               30 * time.Second,
+		ReadHeaderTimeout:         5 * time.Second,
+		WriteTimeout:              5 * time.Minute,
+		IdleTimeout:               time.Minute,
+		MaxHeaderBytes:            64 << 10,
+		HTTP2MaxConcurrentStreams: 100,
+	}
+
+	t.Run("Applies the timeouts and limits", func(t *testing.T) {
+		httpServer, err := newHTTPServer(settings, handler)
+		assert.NoError(t, err)
+		assert.Equal(t, 5*time.Second, httpServer.ReadHeaderTimeout)
+		assert.Equal(t, 5*time.Minute, httpServer.WriteTimeout)
+		assert.Equal(t, time.Minute, httpServer.IdleTimeout)
+		assert.Equal(t, 64<<10, httpServer.MaxHeaderBytes)
+	})
+
+	t.Run("Serves HTTP/2 over cleartext when enabled", func(t *testing.T) {
+		h2Settings := *settings
+		h2Settings.HTTP2Enabled = true
+		httpServer, err := newHTTPServer(&h2Settings, handler)
+		assert.NoError(t, err)
+
+		testServer := httptest.NewUnstartedServer(httpServer.Handler)
+		testServer.Config = httpServer
+		testServer.Start()
+		defer testServer.Close()
+
+		client := &http.Client{Transport: &http2.Transport{
+			AllowHTTP: true,
+			DialTLSContext: func(ctx context.Context, network, addr string, _ *tls.Config) (net.Conn, error) {
+				return (&net.Dialer{}).DialContext(ctx, network, addr)
+			},
+		}}
+		resp, err := client.Get(testServer.URL)
+		assert.NoError(t, err)
+		defer resp.Body.Close()
+		body, _ := io.ReadAll(resp.Body)
+		assert.Equal(t, "HTTP/2.0", string(body))
+
+		// HTTP/1.1 clients are still served
+		resp, err = http.Get(testServer.URL)
+		assert.NoError(t, err)
+		defer resp.Body.Close()
+		body, _ = io.ReadAll(resp.Body)
+		assert.Equal(t, "HTTP/1.1", string(body))
+	})
+}
+
 func TestServerAddress(t *testing.T) {
 	// Test the ServerAddress method
 	ss := &config.ServerSettings{
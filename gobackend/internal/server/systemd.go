@@ -0,0 +1,88 @@
+// Package server provides the HTTP server implementation for the HideMe API.
+// This file implements the service notifications of systemd (sd_notify), so the server
+// can run as a Type=notify unit with a watchdog.
+package server
+
+import (
+	"fmt"
+	"net"
+	"os"
+	"strconv"
+	"time"
+
+	"github.com/rs/zerolog/log"
+)
+
+// systemdNotifier sends state changes to systemd over the socket in NOTIFY_SOCKET. Outside
+// of a Type=notify unit the variable is not set and nothing is sent.
+type systemdNotifier struct {
+	socket   string
+	watchdog time.Duration
+}
+
+// newSystemdNotifier creates a notifier from the environment systemd sets for the service.
+//
+// Returns:
+//   - The notifier; it does nothing if the server does not run under systemd
+func newSystemdNotifier() *systemdNotifier {
+	n := &systemdNotifier{socket: os.Getenv("NOTIFY_SOCKET")}
+
+	// The watchdog applies to this process only if WATCHDOG_PID is unset or names it
+	if pid := os.Getenv("WATCHDOG_PID"); pid == "" || pid == strconv.Itoa(os.Getpid()) {
+		if usec, err := strconv.ParseInt(os.Getenv("WATCHDOG_USEC"), 10, 64); err == nil && usec > 0 {
+			n.watchdog = time.Duration(usec) * time.Microsecond
+		}
+	}
+	return n
+}
+
+// enabled reports whether the server runs under systemd.
+func (n *systemdNotifier) enabled() bool {
+	return n != nil && n.socket != ""
+}
+
+// notify sends a state change, such as READY=1, to systemd. Failures are logged, as
+// notifications must not stop the server.
+//
+// Parameters:
+//   - state: Newline-separated assignments of the sd_notify protocol
+func (n *systemdNotifier) notify(state string) {
+	if !n.enabled() {
+		return
+	}
+	if err := n.send(state); err != nil {
+		log.Warn().Err(err).Str("state", state).Msg("Failed to notify systemd")
+	}
+}
+
+// send writes a state change to the notification socket. Abstract sockets, whose name
+// starts with @, are supported by the net package.
+func (n *systemdNotifier) send(state string) error {
+	conn, err := net.DialUnix("unixgram", nil, &net.UnixAddr{Name: n.socket, Net: "unixgram"})
+	if err != nil {
+		return fmt.Errorf("failed to connect to notification socket: %w", err)
+	}
+	defer conn.Close()
+
+	if _, err := conn.Write([]byte(state)); err != nil {
+		return fmt.Errorf("failed to write notification: %w", err)
+	}
+	return nil
+}
+
+// status reports what the server is doing, shown by systemctl status.
+func (n *systemdNotifier) status(status string) {
+	n.notify("STATUS=" + status)
+}
+
+// watchdogInterval returns how often the watchdog must be fed: half its timeout, so one
+// late notification doesn't get the server restarted.
+//
+// Returns:
+//   - The interval; 0 if the watchdog is not enabled
+func (n *systemdNotifier) watchdogInterval() time.Duration {
+	if !n.enabled() {
+		return 0
+	}
+	return n.watchdog / 2
+}
@@ -0,0 +1,63 @@
+package server
+
+import (
+	"net"
+	"os"
+	"path/filepath"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSystemdNotifier_Notify(t *testing.T) {
+	socket := filepath.Join(t.TempDir(), "notify.sock")
+	conn, err := net.ListenUnixgram("unixgram", &net.UnixAddr{Name: socket, Net: "unixgram"})
+	require.NoError(t, err)
+	defer conn.Close()
+
+	t.Setenv("NOTIFY_SOCKET", socket)
+	n := newSystemdNotifier()
+	require.True(t, n.enabled())
+
+	n.notify("READY=1\nSTATUS=Serving requests")
+
+	buf := make([]byte, 256)
+	require.NoError(t, conn.SetReadDeadline(time.Now().Add(time.Second)))
+	size, err := conn.Read(buf)
+	require.NoError(t, err)
+	assert.Equal(t, "READY=1\nSTATUS=Serving requests", string(buf[:size]))
+}
+
+func TestSystemdNotifier_NotUnderSystemd(t *testing.T) {
+	t.Setenv("NOTIFY_SOCKET", "")
+	t.Setenv("WATCHDOG_USEC", "10000000")
+	n := newSystemdNotifier()
+
+	assert.False(t, n.enabled())
+	assert.Zero(t, n.watchdogInterval())
+
+	// Does not panic or block
+	n.notify("READY=1")
+
+	var nilNotifier *systemdNotifier
+	nilNotifier.status("Running database migrations")
+}
+
+func TestSystemdNotifier_WatchdogInterval(t *testing.T) {
+	t.Setenv("NOTIFY_SOCKET", filepath.Join(t.TempDir(), "notify.sock"))
+
+	t.Run("Enabled", func(t *testing.T) {
+		t.Setenv("WATCHDOG_USEC", "10000000")
+		t.Setenv("WATCHDOG_PID", strconv.Itoa(os.Getpid()))
+		assert.Equal(t, 5*time.Second, newSystemdNotifier().watchdogInterval())
+	})
+
+	t.Run("Other process", func(t *testing.T) {
+		t.Setenv("WATCHDOG_USEC", "10000000")
+		t.Setenv("WATCHDOG_PID", "1")
+		assert.Zero(t, newSystemdNotifier().watchdogInterval())
+	})
+}
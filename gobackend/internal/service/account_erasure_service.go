@@ -0,0 +1,307 @@
+// Package service provides business logic implementations.
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strconv"
+	"time"
+
+	"github.com/rs/zerolog/log"
+
+	"github.com/yasinhessnawi1/Hideme_Backend/internal/auth"
+	"github.com/yasinhessnawi1/Hideme_Backend/internal/constants"
+	"github.com/yasinhessnawi1/Hideme_Backend/internal/models"
+	"github.com/yasinhessnawi1/Hideme_Backend/internal/repository"
+	"github.com/yasinhessnawi1/Hideme_Backend/internal/utils"
+	"github.com/yasinhessnawi1/Hideme_Backend/internal/utils/clock"
+	"github.com/yasinhessnawi1/Hideme_Backend/internal/utils/gdprlog"
+)
+
+var (
+	// ErrAccountErasureActive is returned when a user requests the erasure of an account already scheduled for erasure
+	ErrAccountErasureActive = utils.New(utils.ErrDuplicate, constants.StatusConflict, constants.MsgAccountErasureActive)
+
+	// ErrAccountErasureStarted is returned when a user cancels an erasure that was already attempted
+	ErrAccountErasureStarted = utils.New(utils.ErrBadRequest, constants.StatusConflict, constants.MsgAccountErasureStarted)
+)
+
+// userDataEraser deletes one kind of data kept about a user. Deleting data that is
+// already gone succeeds, so a failed erasure can be repeated from the start.
+type userDataEraser interface {
+	DeleteByUserID(ctx context.Context, userID int64) error
+}
+
+// tenantKeyShredder deletes the data-encryption key of a user.
+type tenantKeyShredder interface {
+	Delete(ctx context.Context, userID int64) error
+}
+
+// SubjectLogScrubber redacts a data subject from the GDPR logs, usually the GDPRLogger.
+type SubjectLogScrubber interface {
+	DeleteLogsForSubject(ctx context.Context, identifiers gdprlog.SubjectIdentifiers) (int, error)
+}
+
+// AccountErasureService erases user accounts on request (GDPR Article 17). Erasing an
+// account in the request risked leaving it half deleted when one of the steps failed;
+// instead the request schedules an erasure, which the user can cancel during a grace
+// period. Once the grace period has passed, the erasure runs in the background: it
+// deletes the sessions, API keys, documents with their detected entities and the user
+// row, which takes the remaining rows of the account with it, shreds the tenant key and
+// redacts the user from the GDPR logs. A failed attempt is retried later from the start.
+type AccountErasureService struct {
+	erasureRepo repository.AccountErasureRepository
+	userRepo    repository.UserRepository
+	sessions    userDataEraser
+	apiKeys     userDataEraser
+	documents   userDataEraser
+	tenantKeys  tenantKeyShredder
+	passwordCfg *auth.PasswordConfig
+	logs        SubjectLogScrubber
+	clock       clock.Clock
+}
+
+// NewAccountErasureService creates a new AccountErasureService.
+//
+// Parameters:
+//   - erasureRepo: Repository for the scheduled erasures
+//   - userRepo: Repository for user accounts
+//   - sessions: Deletes the sessions of a user, usually the SessionRepository
+//   - apiKeys: Deletes the API keys of a user, usually the APIKeyRepository
+//   - documents: Deletes the documents and detected entities of a user, usually the DocumentRepository
+//   - tenantKeys: Shreds the data-encryption key of a user, usually the TenantKeyRepository
+//   - passwordCfg: Configuration for verifying the password that confirms an erasure
+//
+// Returns:
+//   - A configured AccountErasureService
+func NewAccountErasureService(
+	erasureRepo repository.AccountErasureRepository,
+	userRepo repository.UserRepository,
+	sessions userDataEraser,
+	apiKeys userDataEraser,
+	documents userDataEraser,
+	tenantKeys tenantKeyShredder,
+	passwordCfg *auth.PasswordConfig,
+) *AccountErasureService {
+	return &AccountErasureService{
+		erasureRepo: erasureRepo,
+		userRepo:    userRepo,
+		sessions:    sessions,
+		apiKeys:     apiKeys,
+		documents:   documents,
+		tenantKeys:  tenantKeys,
+		passwordCfg: passwordCfg,
+	}
+}
+
+// SetClock sets the clock erasures are requested, scheduled and retried
+// by, so tests can move time past the grace period.
+func (s *AccountErasureService) SetClock(c clock.Clock) {
+	s.clock = c
+}
+
+// now returns the current time of the service's clock.
+func (s *AccountErasureService) now() time.Time {
+	return clock.Or(s.clock).Now()
+}
+
+// SetLogScrubber sets the GDPR logs erasures redact the user from. Without it, the logs
+// are left to their retention.
+func (s *AccountErasureService) SetLogScrubber(logs SubjectLogScrubber) {
+	s.logs = logs
+}
+
+// RequestErasure schedules the erasure of a user's account after the grace period.
+//
+// Parameters:
+//   - ctx: Context for the operation
+//   - userID: The ID of the user
+//   - password: The current password of the user, confirming the request
+//
+// Returns:
+//   - The scheduled erasure
+//   - UnauthorizedError if the password is incorrect
+//   - ErrAccountErasureActive if the account is already scheduled for erasure
+//   - Other errors if the erasure could not be stored
+func (s *AccountErasureService) RequestErasure(ctx context.Context, userID int64, password string) (*models.AccountErasure, error) {
+	user, err := s.userRepo.GetByID(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	match, err := auth.VerifyPassword(password, user.PasswordHash, user.Salt, s.passwordCfg)
+	if err != nil {
+		return nil, fmt.Errorf("password verification error: %w", err)
+	}
+	if !match {
+		return nil, utils.NewUnauthorizedError("Current password is incorrect")
+	}
+
+	erasure := models.NewAccountErasure(userID, s.now())
+	if err := s.erasureRepo.Create(ctx, erasure); err != nil {
+		if errors.Is(err, utils.ErrDuplicate) {
+			return nil, ErrAccountErasureActive
+		}
+		return nil, err
+	}
+
+	log.Info().
+		Int64("user_id", userID).
+		Int64("erasure_id", erasure.ID).
+		Time("scheduled_for", erasure.ScheduledFor).
+		Str("category", constants.LogCategoryUser).
+		Msg("Account erasure scheduled")
+
+	return erasure, nil
+}
+
+// GetErasure returns the unfinished erasure of a user's account.
+//
+// Parameters:
+//   - ctx: Context for the operation
+//   - userID: The ID of the user
+//
+// Returns:
+//   - The scheduled or running erasure
+//   - NotFoundError if the account is not scheduled for erasure
+func (s *AccountErasureService) GetErasure(ctx context.Context, userID int64) (*models.AccountErasure, error) {
+	return s.erasureRepo.GetActiveByUserID(ctx, userID)
+}
+
+// CancelErasure cancels the erasure of a user's account during the grace period.
+//
+// Parameters:
+//   - ctx: Context for the operation
+//   - userID: The ID of the user
+//
+// Returns:
+//   - NotFoundError if the account is not scheduled for erasure
+//   - ErrAccountErasureStarted if the grace period has passed and the erasure was attempted
+//   - Other errors if the cancellation could not be stored
+func (s *AccountErasureService) CancelErasure(ctx context.Context, userID int64) error {
+	erasure, err := s.erasureRepo.GetActiveByUserID(ctx, userID)
+	if err != nil {
+		return err
+	}
+	if !erasure.Cancellable() {
+		return ErrAccountErasureStarted
+	}
+
+	// A server may claim the erasure in the meantime; the cancellation then finds nothing to cancel
+	if err := s.erasureRepo.Cancel(ctx, erasure.ID, s.now()); err != nil {
+		if errors.Is(err, utils.ErrNotFound) {
+			return ErrAccountErasureStarted
+		}
+		return err
+	}
+
+	log.Info().
+		Int64("user_id", userID).
+		Int64("erasure_id", erasure.ID).
+		Str("category", constants.LogCategoryUser).
+		Msg("Account erasure cancelled")
+
+	return nil
+}
+
+// RunDueErasures erases the accounts whose grace period has passed, one at a time, until
+// none is due. Failed erasures are rescheduled and don't stop the others.
+//
+// Parameters:
+//   - ctx: Context for the operation
+//
+// Returns:
+//   - The number of erased accounts
+//   - An error if the erasures could not be read or updated
+func (s *AccountErasureService) RunDueErasures(ctx context.Context) (int64, error) {
+	var erased int64
+	for {
+		if err := ctx.Err(); err != nil {
+			return erased, err
+		}
+
+		now := s.now()
+		erasure, err := s.erasureRepo.Claim(ctx, now, now.Add(constants.AccountErasureLease))
+		if err != nil {
+			return erased, err
+		}
+		if erasure == nil {
+			return erased, nil
+		}
+
+		if err := s.eraseAccount(ctx, erasure.UserID); err != nil {
+			log.Error().
+				Err(err).
+				Int64("erasure_id", erasure.ID).
+				Int("attempts", erasure.Attempts).
+				Msg("Account erasure failed, retrying later")
+			if err := s.erasureRepo.Reschedule(ctx, erasure.ID, s.now().Add(constants.AccountErasureRetryDelay), err.Error()); err != nil {
+				return erased, err
+			}
+			continue
+		}
+
+		if err := s.erasureRepo.Complete(ctx, erasure.ID, s.now()); err != nil {
+			return erased, err
+		}
+		erased++
+
+		log.Info().
+			Int64("erasure_id", erasure.ID).
+			Str("category", constants.LogCategoryUser).
+			Msg("Account erased")
+	}
+}
+
+// eraseAccount deletes everything kept about a user. Every step succeeds on data that is
+// already gone, so an attempt that failed part way can be repeated from the start.
+//
+// Parameters:
+//   - ctx: Context for the operation
+//   - userID: The ID of the user
+//
+// Returns:
+//   - The error of the first step that failed
+func (s *AccountErasureService) eraseAccount(ctx context.Context, userID int64) error {
+	// Read the identifiers the GDPR logs are searched for before the user row is gone.
+	// A retry after the row was deleted only finds the log entries by user ID.
+	identifiers := gdprlog.SubjectIdentifiers{UserID: strconv.FormatInt(userID, 10)}
+	user, err := s.userRepo.GetByID(ctx, userID)
+	if err != nil && !errors.Is(err, utils.ErrNotFound) {
+		return fmt.Errorf("failed to get user: %w", err)
+	}
+	if user != nil {
+		identifiers.Username = user.Username
+		identifiers.Email = user.Email
+	}
+
+	// Sign the user out everywhere first, so nothing is added while the data is deleted
+	if err := s.sessions.DeleteByUserID(ctx, userID); err != nil {
+		return fmt.Errorf("failed to delete sessions: %w", err)
+	}
+	if err := s.apiKeys.DeleteByUserID(ctx, userID); err != nil {
+		return fmt.Errorf("failed to delete API keys: %w", err)
+	}
+	if err := s.documents.DeleteByUserID(ctx, userID); err != nil {
+		return fmt.Errorf("failed to delete documents: %w", err)
+	}
+
+	// Deleting the user row cascades to the settings and everything else the account owns
+	if err := s.userRepo.Delete(ctx, userID); err != nil && !errors.Is(err, utils.ErrNotFound) {
+		return fmt.Errorf("failed to delete user: %w", err)
+	}
+
+	// Shred the tenant's data key, so ciphertexts left in backups can no longer be decrypted
+	if err := s.tenantKeys.Delete(ctx, userID); err != nil {
+		return fmt.Errorf("failed to delete tenant data key: %w", err)
+	}
+
+	if s.logs != nil {
+		if _, err := s.logs.DeleteLogsForSubject(ctx, identifiers); err != nil {
+			return fmt.Errorf("failed to redact GDPR logs: %w", err)
+		}
+	}
+
+	return nil
+}
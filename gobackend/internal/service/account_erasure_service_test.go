@@ -0,0 +1,190 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/yasinhessnawi1/Hideme_Backend/internal/auth"
+	"github.com/yasinhessnawi1/Hideme_Backend/internal/constants"
+	"github.com/yasinhessnawi1/Hideme_Backend/internal/models"
+	"github.com/yasinhessnawi1/Hideme_Backend/internal/repository"
+	"github.com/yasinhessnawi1/Hideme_Backend/internal/testsupport/fakeclock"
+	"github.com/yasinhessnawi1/Hideme_Backend/internal/utils"
+	"github.com/yasinhessnawi1/Hideme_Backend/internal/utils/gdprlog"
+)
+
+// MockAccountErasureRepository keeps account erasures in memory
+type MockAccountErasureRepository struct {
+	repository.AccountErasureRepository
+	erasures []*models.AccountErasure
+}
+
+func (m *MockAccountErasureRepository) Create(ctx context.Context, erasure *models.AccountErasure) error {
+	if _, err := m.GetActiveByUserID(ctx, erasure.UserID); err == nil {
+		return utils.NewDuplicateError("AccountErasure", "status", erasure.Status)
+	}
+	erasure.ID = int64(len(m.erasures) + 1)
+	m.erasures = append(m.erasures, erasure)
+	return nil
+}
+
+func (m *MockAccountErasureRepository) GetActiveByUserID(ctx context.Context, userID int64) (*models.AccountErasure, error) {
+	for _, erasure := range m.erasures {
+		if erasure.UserID == userID && (erasure.Status == constants.AccountErasureScheduled || erasure.Status == constants.AccountErasureRunning) {
+			stored := *erasure
+			return &stored, nil
+		}
+	}
+	return nil, utils.NewNotFoundError("AccountErasure", userID)
+}
+
+func (m *MockAccountErasureRepository) Cancel(ctx context.Context, id int64, now time.Time) error {
+	erasure := m.erasures[id-1]
+	if !erasure.Cancellable() {
+		return utils.NewNotFoundError("AccountErasure", id)
+	}
+	erasure.Status = constants.AccountErasureCancelled
+	erasure.CompletedAt = &now
+	return nil
+}
+
+func (m *MockAccountErasureRepository) Claim(ctx context.Context, now, leaseUntil time.Time) (*models.AccountErasure, error) {
+	for _, erasure := range m.erasures {
+		if erasure.Status == constants.AccountErasureScheduled && !erasure.ScheduledFor.After(now) {
+			erasure.Status = constants.AccountErasureRunning
+			erasure.Attempts++
+			erasure.LeaseUntil = &leaseUntil
+			stored := *erasure
+			return &stored, nil
+		}
+	}
+	return nil, nil
+}
+
+func (m *MockAccountErasureRepository) Complete(ctx context.Context, id int64, now time.Time) error {
+	m.erasures[id-1].Status = constants.AccountErasureCompleted
+	m.erasures[id-1].CompletedAt = &now
+	return nil
+}
+
+func (m *MockAccountErasureRepository) Reschedule(ctx context.Context, id int64, retryAt time.Time, lastError string) error {
+	m.erasures[id-1].Status = constants.AccountErasureScheduled
+	m.erasures[id-1].ScheduledFor = retryAt
+	m.erasures[id-1].LastError = lastError
+	return nil
+}
+
+// stubDataEraser records the users whose data was deleted, failing while err is set
+type stubDataEraser struct {
+	erased []int64
+	err    error
+}
+
+func (s *stubDataEraser) DeleteByUserID(ctx context.Context, userID int64) error {
+	if s.err != nil {
+		return s.err
+	}
+	s.erased = append(s.erased, userID)
+	return nil
+}
+
+func (s *stubDataEraser) Delete(ctx context.Context, userID int64) error {
+	return s.DeleteByUserID(ctx, userID)
+}
+
+// stubLogScrubber records the subjects redacted from the logs
+type stubLogScrubber struct {
+	subjects []gdprlog.SubjectIdentifiers
+}
+
+func (s *stubLogScrubber) DeleteLogsForSubject(ctx context.Context, identifiers gdprlog.SubjectIdentifiers) (int, error) {
+	s.subjects = append(s.subjects, identifiers)
+	return 1, nil
+}
+
+func TestAccountErasureService(t *testing.T) {
+	passwordCfg := &auth.PasswordConfig{Memory: 16 * 1024, Iterations: 1, Parallelism: 1, SaltLength: 16, KeyLength: 32}
+	passwordHash, salt, err := auth.HashPassword("password123", passwordCfg)
+	if err != nil {
+		t.Fatalf("HashPassword() error = %v", err)
+	}
+
+	userRepo := NewMockUserRepository()
+	user := &models.User{Username: "ola", Email: "ola@example.com", PasswordHash: passwordHash, Salt: salt}
+	if err := userRepo.Create(context.Background(), user); err != nil {
+		t.Fatalf("Create() error = %v", err)
+	}
+
+	erasureRepo := &MockAccountErasureRepository{}
+	documents := &stubDataEraser{}
+	tenantKeys := &stubDataEraser{}
+	logs := &stubLogScrubber{}
+	service := NewAccountErasureService(erasureRepo, userRepo, NewMockSessionRepository(), NewMockAPIKeyRepository(), documents, tenantKeys, passwordCfg)
+	service.SetLogScrubber(logs)
+	now := time.Date(2026, 3, 1, 12, 0, 0, 0, time.UTC)
+	clk := fakeclock.New(now)
+	service.SetClock(clk)
+
+	// The password confirms the request
+	if _, err := service.RequestErasure(context.Background(), user.ID, "wrong"); !errors.Is(err, utils.ErrUnauthorized) {
+		t.Errorf("RequestErasure() error = %v, want unauthorized for a wrong password", err)
+	}
+
+	erasure, err := service.RequestErasure(context.Background(), user.ID, "password123")
+	if err != nil {
+		t.Fatalf("RequestErasure() error = %v", err)
+	}
+	if !erasure.ScheduledFor.Equal(now.Add(constants.AccountErasureGracePeriod)) {
+		t.Errorf("ScheduledFor = %v, want the end of the grace period", erasure.ScheduledFor)
+	}
+	if _, err := service.RequestErasure(context.Background(), user.ID, "password123"); !errors.Is(err, ErrAccountErasureActive) {
+		t.Errorf("RequestErasure() error = %v, want ErrAccountErasureActive", err)
+	}
+
+	// Nothing happens during the grace period, and the user can change their mind
+	if erased, err := service.RunDueErasures(context.Background()); err != nil || erased != 0 {
+		t.Errorf("RunDueErasures() = %d, %v, want nothing erased during the grace period", erased, err)
+	}
+	if err := service.CancelErasure(context.Background(), user.ID); err != nil {
+		t.Fatalf("CancelErasure() error = %v", err)
+	}
+	if _, err := service.GetErasure(context.Background(), user.ID); !errors.Is(err, utils.ErrNotFound) {
+		t.Errorf("GetErasure() error = %v, want not found after cancelling", err)
+	}
+
+	// A failed step is retried later from the start
+	if _, err := service.RequestErasure(context.Background(), user.ID, "password123"); err != nil {
+		t.Fatalf("RequestErasure() error = %v", err)
+	}
+	now = clk.Advance(constants.AccountErasureGracePeriod)
+	documents.err = errors.New("connection reset")
+	if erased, err := service.RunDueErasures(context.Background()); err != nil || erased != 0 {
+		t.Errorf("RunDueErasures() = %d, %v, want the failed erasure rescheduled", erased, err)
+	}
+	if err := service.CancelErasure(context.Background(), user.ID); !errors.Is(err, ErrAccountErasureStarted) {
+		t.Errorf("CancelErasure() error = %v, want ErrAccountErasureStarted once attempted", err)
+	}
+	if _, err := userRepo.GetByID(context.Background(), user.ID); err != nil {
+		t.Errorf("GetByID() error = %v, want the user kept until the documents are deleted", err)
+	}
+
+	documents.err = nil
+	now = clk.Advance(constants.AccountErasureRetryDelay)
+	if erased, err := service.RunDueErasures(context.Background()); err != nil || erased != 1 {
+		t.Fatalf("RunDueErasures() = %d, %v, want one account erased", erased, err)
+	}
+	if _, err := userRepo.GetByID(context.Background(), user.ID); !errors.Is(err, utils.ErrNotFound) {
+		t.Errorf("GetByID() error = %v, want the user deleted", err)
+	}
+	if len(documents.erased) != 1 || len(tenantKeys.erased) != 1 {
+		t.Errorf("documents erased %v, tenant keys %v, want both erased once", documents.erased, tenantKeys.erased)
+	}
+	if len(logs.subjects) != 1 || logs.subjects[0].Email != "ola@example.com" || logs.subjects[0].UserID != "1" {
+		t.Errorf("log subjects = %+v, want the user redacted by ID and email", logs.subjects)
+	}
+	if erasureRepo.erasures[1].Status != constants.AccountErasureCompleted || erasureRepo.erasures[1].Attempts != 2 {
+		t.Errorf("erasure = %+v, want completed on the second attempt", erasureRepo.erasures[1])
+	}
+}
@@ -0,0 +1,89 @@
+// Package service provides business logic implementations.
+package service
+
+import (
+	"context"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/rs/zerolog/log"
+
+	"github.com/yasinhessnawi1/Hideme_Backend/internal/constants"
+	"github.com/yasinhessnawi1/Hideme_Backend/internal/models"
+	"github.com/yasinhessnawi1/Hideme_Backend/internal/repository"
+	"github.com/yasinhessnawi1/Hideme_Backend/internal/utils"
+)
+
+// AdminSearchService looks up users, documents, API keys and sessions from a single
+// reference, so support staff can find whatever a ticket refers to in one call.
+type AdminSearchService struct {
+	searchRepo repository.AdminSearchRepository
+}
+
+// NewAdminSearchService creates a new AdminSearchService.
+//
+// Parameters:
+//   - searchRepo: Repository for the administrator search
+//
+// Returns:
+//   - A configured AdminSearchService
+func NewAdminSearchService(searchRepo repository.AdminSearchRepository) *AdminSearchService {
+	return &AdminSearchService{
+		searchRepo: searchRepo,
+	}
+}
+
+// Search looks up every resource type matching the term. Numeric terms also match
+// user and document IDs; API keys and sessions match by ID prefix.
+//
+// Parameters:
+//   - ctx: Context for the operation
+//   - adminID: The ID of the administrator searching, recorded for auditing
+//   - query: The search term
+//
+// Returns:
+//   - The matches, one bucket per resource type
+//   - ValidationError if the term is too short or too long
+//   - Other errors if a lookup fails
+func (s *AdminSearchService) Search(ctx context.Context, adminID int64, query string) (*models.AdminSearchResults, error) {
+	query = strings.TrimSpace(query)
+	length := utf8.RuneCountInString(query)
+	if length < constants.AdminSearchMinQueryLength || length > constants.AdminSearchMaxQueryLength {
+		return nil, utils.NewValidationError(constants.QueryParamQuery, constants.MsgAdminSearchQueryLength)
+	}
+
+	var id *int64
+	if parsed, err := strconv.ParseInt(query, 10, 64); err == nil && parsed > 0 {
+		id = &parsed
+	}
+
+	results := models.NewAdminSearchResults(query)
+	limit := constants.AdminSearchResultLimit
+
+	var err error
+	if results.Users, err = s.searchRepo.SearchUsers(ctx, query, id, limit); err != nil {
+		return nil, err
+	}
+	if results.Documents, err = s.searchRepo.SearchDocuments(ctx, query, id, limit); err != nil {
+		return nil, err
+	}
+	if results.APIKeys, err = s.searchRepo.SearchAPIKeys(ctx, query, limit); err != nil {
+		return nil, err
+	}
+	if results.Sessions, err = s.searchRepo.SearchSessions(ctx, query, limit); err != nil {
+		return nil, err
+	}
+
+	// Searches reach into every tenant's data, so each one is recorded
+	log.Info().
+		Int64("admin_id", adminID).
+		Str("category", constants.LogCategoryAdmin).
+		Int("users", len(results.Users)).
+		Int("documents", len(results.Documents)).
+		Int("api_keys", len(results.APIKeys)).
+		Int("sessions", len(results.Sessions)).
+		Msg("Administrator search performed")
+
+	return results, nil
+}
@@ -0,0 +1,128 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/yasinhessnawi1/Hideme_Backend/internal/models"
+	"github.com/yasinhessnawi1/Hideme_Backend/internal/utils"
+)
+
+// MockAdminSearchRepository is an in-memory implementation of repository.AdminSearchRepository
+type MockAdminSearchRepository struct {
+	users     []*models.User
+	documents []*models.AdminSearchDocument
+	apiKeys   []*models.APIKey
+	sessions  []*models.Session
+	err       error
+
+	lastTerm string
+	lastID   *int64
+}
+
+func (m *MockAdminSearchRepository) SearchUsers(ctx context.Context, term string, id *int64, limit int) ([]*models.User, error) {
+	m.lastTerm, m.lastID = term, id
+	result := []*models.User{}
+	for _, user := range m.users {
+		if (id != nil && user.ID == *id) || strings.Contains(user.Email, term) || strings.Contains(user.Username, term) {
+			result = append(result, user)
+		}
+	}
+	return result, m.err
+}
+
+func (m *MockAdminSearchRepository) SearchDocuments(ctx context.Context, term string, id *int64, limit int) ([]*models.AdminSearchDocument, error) {
+	result := []*models.AdminSearchDocument{}
+	for _, doc := range m.documents {
+		if id != nil && doc.ID == *id {
+			result = append(result, doc)
+		}
+	}
+	return result, nil
+}
+
+func (m *MockAdminSearchRepository) SearchAPIKeys(ctx context.Context, prefix string, limit int) ([]*models.APIKey, error) {
+	result := []*models.APIKey{}
+	for _, key := range m.apiKeys {
+		if strings.HasPrefix(key.ID, prefix) {
+			result = append(result, key)
+		}
+	}
+	return result, nil
+}
+
+func (m *MockAdminSearchRepository) SearchSessions(ctx context.Context, prefix string, limit int) ([]*models.Session, error) {
+	result := []*models.Session{}
+	for _, session := range m.sessions {
+		if strings.HasPrefix(session.ID, prefix) {
+			result = append(result, session)
+		}
+	}
+	return result, nil
+}
+
+func TestAdminSearchService_Search(t *testing.T) {
+	repo := &MockAdminSearchRepository{
+		users:     []*models.User{{ID: 123, Username: "alice", Email: "alice@example.com"}},
+		documents: []*models.AdminSearchDocument{{ID: 123, UserID: 7}},
+		apiKeys:   []*models.APIKey{{ID: "123abc", UserID: 7}},
+		sessions:  []*models.Session{{ID: "999", UserID: 7}},
+	}
+	svc := NewAdminSearchService(repo)
+
+	results, err := svc.Search(context.Background(), 1, "  123 ")
+	if err != nil {
+		t.Fatalf("Search returned error: %v", err)
+	}
+	if results.Query != "123" {
+		t.Errorf("Expected trimmed query 123, got %q", results.Query)
+	}
+	if repo.lastID == nil || *repo.lastID != 123 {
+		t.Errorf("Expected numeric term to be searched as an ID, got %v", repo.lastID)
+	}
+	if len(results.Users) != 1 || len(results.Documents) != 1 || len(results.APIKeys) != 1 {
+		t.Errorf("Expected one user, document and API key, got %+v", results)
+	}
+	if results.Sessions == nil || len(results.Sessions) != 0 {
+		t.Errorf("Expected an empty session bucket, got %v", results.Sessions)
+	}
+}
+
+func TestAdminSearchService_Search_TextTerm(t *testing.T) {
+	repo := &MockAdminSearchRepository{
+		users: []*models.User{{ID: 1, Username: "alice", Email: "alice@example.com"}},
+	}
+	svc := NewAdminSearchService(repo)
+
+	results, err := svc.Search(context.Background(), 1, "example.com")
+	if err != nil {
+		t.Fatalf("Search returned error: %v", err)
+	}
+	if repo.lastID != nil {
+		t.Errorf("Expected no ID for a text term, got %d", *repo.lastID)
+	}
+	if len(results.Users) != 1 {
+		t.Errorf("Expected one user, got %d", len(results.Users))
+	}
+}
+
+func TestAdminSearchService_Search_QueryLength(t *testing.T) {
+	svc := NewAdminSearchService(&MockAdminSearchRepository{})
+
+	for _, query := range []string{"", "  ab  ", strings.Repeat("a", 256)} {
+		_, err := svc.Search(context.Background(), 1, query)
+		if !utils.IsValidationError(err) {
+			t.Errorf("Expected ValidationError for query of length %d, got %v", len(query), err)
+		}
+	}
+}
+
+func TestAdminSearchService_Search_RepositoryError(t *testing.T) {
+	svc := NewAdminSearchService(&MockAdminSearchRepository{err: errors.New("connection lost")})
+
+	if _, err := svc.Search(context.Background(), 1, "alice"); err == nil {
+		t.Error("Expected repository error to be returned")
+	}
+}
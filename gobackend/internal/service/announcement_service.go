@@ -0,0 +1,199 @@
+// Package service provides business logic implementations.
+package service
+
+import (
+	"context"
+	"time"
+
+	"github.com/rs/zerolog/log"
+
+	"github.com/yasinhessnawi1/Hideme_Backend/internal/constants"
+	"github.com/yasinhessnawi1/Hideme_Backend/internal/models"
+	"github.com/yasinhessnawi1/Hideme_Backend/internal/repository"
+	"github.com/yasinhessnawi1/Hideme_Backend/internal/utils"
+	"github.com/yasinhessnawi1/Hideme_Backend/internal/utils/clock"
+)
+
+// AnnouncementService delivers in-product announcements, such as maintenance windows and
+// new-feature notices. Administrators publish announcements with an optional display
+// window; users see the announcements within their window and mark them as read.
+type AnnouncementService struct {
+	announcementRepo repository.AnnouncementRepository
+	screener         *PIIScreener
+	clock            clock.Clock
+}
+
+// NewAnnouncementService creates a new AnnouncementService.
+//
+// Parameters:
+//   - announcementRepo: Repository for announcements and their read state
+//
+// Returns:
+//   - A configured AnnouncementService
+func NewAnnouncementService(announcementRepo repository.AnnouncementRepository) *AnnouncementService {
+	return &AnnouncementService{
+		announcementRepo: announcementRepo,
+	}
+}
+
+// SetClock sets the clock announcements are shown and read by.
+func (s *AnnouncementService) SetClock(c clock.Clock) {
+	s.clock = c
+}
+
+// now returns the current time of the service's clock.
+func (s *AnnouncementService) now() time.Time {
+	return clock.Or(s.clock).Now()
+}
+
+// SetPIIScreener enables screening of announcement titles and messages for personal data.
+// Announcements are shown to every user, so personal data entered there would be disclosed.
+//
+// Parameters:
+//   - screener: The screener checking free-text fields
+func (s *AnnouncementService) SetPIIScreener(screener *PIIScreener) {
+	s.screener = screener
+}
+
+// ListForUser returns the announcements currently shown, together with whether the user has read them.
+//
+// Parameters:
+//   - ctx: Context for the operation
+//   - userID: The ID of the user
+//   - unreadOnly: Whether to leave out the announcements the user has read
+//
+// Returns:
+//   - The announcements, latest first
+//   - An error if retrieval fails
+func (s *AnnouncementService) ListForUser(ctx context.Context, userID int64, unreadOnly bool) ([]*models.UserAnnouncement, error) {
+	return s.announcementRepo.ListActiveForUser(ctx, userID, s.now(), unreadOnly)
+}
+
+// MarkRead records that a user has read an announcement.
+//
+// Parameters:
+//   - ctx: Context for the operation
+//   - userID: The ID of the user
+//   - id: The ID of the announcement
+//
+// Returns:
+//   - NotFoundError if the announcement doesn't exist or is not currently shown
+//   - Other errors if the read state could not be stored
+func (s *AnnouncementService) MarkRead(ctx context.Context, userID, id int64) error {
+	announcement, err := s.announcementRepo.GetByID(ctx, id)
+	if err != nil {
+		return err
+	}
+
+	// Scheduled and expired announcements are hidden from users, so they can't be read either
+	now := s.now()
+	if !announcement.ActiveAt(now) {
+		return utils.NewNotFoundError("Announcement", id)
+	}
+
+	return s.announcementRepo.MarkRead(ctx, id, userID, now)
+}
+
+// ListAnnouncements returns the most recent announcements, including scheduled and expired ones.
+//
+// Parameters:
+//   - ctx: Context for the operation
+//
+// Returns:
+//   - The announcements, latest start first
+//   - An error if retrieval fails
+func (s *AnnouncementService) ListAnnouncements(ctx context.Context) ([]*models.Announcement, error) {
+	return s.announcementRepo.List(ctx, constants.AnnouncementListLimit)
+}
+
+// CreateAnnouncement publishes a new announcement.
+//
+// Parameters:
+//   - ctx: Context for the operation
+//   - adminID: The ID of the administrator publishing the announcement
+//   - req: The title, message, category and optional display window of the announcement
+//
+// Returns:
+//   - The created announcement
+//   - ValidationError if the announcement ends before it starts, or if the title or
+//     message contains personal data and screening blocks it
+//   - Other errors if the announcement could not be stored
+func (s *AnnouncementService) CreateAnnouncement(ctx context.Context, adminID int64, req *models.AnnouncementCreate) (*models.Announcement, error) {
+	if err := s.screen(ctx, adminID, req.Title, req.Message); err != nil {
+		return nil, err
+	}
+
+	announcement := models.NewAnnouncement(adminID, req)
+	if !announcement.ValidWindow() {
+		return nil, utils.NewValidationError("ends_at", constants.MsgAnnouncementWindowInvalid)
+	}
+
+	if err := s.announcementRepo.Create(ctx, announcement); err != nil {
+		return nil, err
+	}
+
+	log.Info().
+		Int64("announcement_id", announcement.ID).
+		Int64("admin_id", adminID).
+		Str("category", string(announcement.Category)).
+		Msg("Announcement published")
+
+	return announcement, nil
+}
+
+// UpdateAnnouncement changes an announcement, such as its text or display window.
+//
+// Parameters:
+//   - ctx: Context for the operation
+//   - adminID: The ID of the administrator changing the announcement
+//   - id: The ID of the announcement
+//   - req: The fields to change
+//
+// Returns:
+//   - The updated announcement
+//   - ValidationError if the announcement would end before it starts, or if the title or
+//     message contains personal data and screening blocks it
+//   - NotFoundError if the announcement doesn't exist
+//   - Other errors if the announcement could not be stored
+func (s *AnnouncementService) UpdateAnnouncement(ctx context.Context, adminID int64, id int64, req *models.AnnouncementUpdate) (*models.Announcement, error) {
+	if err := s.screen(ctx, adminID, req.Title, req.Message); err != nil {
+		return nil, err
+	}
+
+	announcement, err := s.announcementRepo.GetByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	announcement.Apply(req)
+	if !announcement.ValidWindow() {
+		return nil, utils.NewValidationError("ends_at", constants.MsgAnnouncementWindowInvalid)
+	}
+
+	if err := s.announcementRepo.Update(ctx, announcement); err != nil {
+		return nil, err
+	}
+
+	return announcement, nil
+}
+
+// screen checks the text of an announcement shown to users for personal data.
+func (s *AnnouncementService) screen(ctx context.Context, adminID int64, title, message string) error {
+	if s.screener == nil {
+		return nil
+	}
+	return s.screener.Screen(ctx, adminID, map[string]string{"title": title, "message": message})
+}
+
+// DeleteAnnouncement removes an announcement, e.g. one published by mistake.
+//
+// Parameters:
+//   - ctx: Context for the operation
+//   - id: The ID of the announcement
+//
+// Returns:
+//   - NotFoundError if the announcement doesn't exist
+//   - Other errors if the announcement could not be removed
+func (s *AnnouncementService) DeleteAnnouncement(ctx context.Context, id int64) error {
+	return s.announcementRepo.Delete(ctx, id)
+}
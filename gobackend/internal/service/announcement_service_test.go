@@ -0,0 +1,243 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"sort"
+	"testing"
+	"time"
+
+	"github.com/yasinhessnawi1/Hideme_Backend/internal/models"
+	"github.com/yasinhessnawi1/Hideme_Backend/internal/testsupport/fakeclock"
+	"github.com/yasinhessnawi1/Hideme_Backend/internal/utils"
+)
+
+// MockAnnouncementRepository is an in-memory implementation of repository.AnnouncementRepository
+type MockAnnouncementRepository struct {
+	announcements map[int64]*models.Announcement
+	reads         map[int64]map[int64]time.Time
+	nextID        int64
+}
+
+func NewMockAnnouncementRepository() *MockAnnouncementRepository {
+	return &MockAnnouncementRepository{
+		announcements: make(map[int64]*models.Announcement),
+		reads:         make(map[int64]map[int64]time.Time),
+	}
+}
+
+func (m *MockAnnouncementRepository) Create(ctx context.Context, announcement *models.Announcement) error {
+	m.nextID++
+	announcement.ID = m.nextID
+	stored := *announcement
+	m.announcements[announcement.ID] = &stored
+	return nil
+}
+
+func (m *MockAnnouncementRepository) GetByID(ctx context.Context, id int64) (*models.Announcement, error) {
+	announcement, ok := m.announcements[id]
+	if !ok {
+		return nil, utils.NewNotFoundError("Announcement", id)
+	}
+	copied := *announcement
+	return &copied, nil
+}
+
+func (m *MockAnnouncementRepository) List(ctx context.Context, limit int) ([]*models.Announcement, error) {
+	result := []*models.Announcement{}
+	for _, announcement := range m.announcements {
+		result = append(result, announcement)
+	}
+	return result, nil
+}
+
+func (m *MockAnnouncementRepository) ListActiveForUser(ctx context.Context, userID int64, now time.Time, unreadOnly bool) ([]*models.UserAnnouncement, error) {
+	result := []*models.UserAnnouncement{}
+	for _, announcement := range m.announcements {
+		if !announcement.ActiveAt(now) {
+			continue
+		}
+		userAnnouncement := &models.UserAnnouncement{Announcement: *announcement}
+		if readAt, ok := m.reads[announcement.ID][userID]; ok {
+			if unreadOnly {
+				continue
+			}
+			userAnnouncement.Read = true
+			userAnnouncement.ReadAt = &readAt
+		}
+		result = append(result, userAnnouncement)
+	}
+	sort.Slice(result, func(i, j int) bool { return result[i].ID > result[j].ID })
+	return result, nil
+}
+
+func (m *MockAnnouncementRepository) Update(ctx context.Context, announcement *models.Announcement) error {
+	if _, ok := m.announcements[announcement.ID]; !ok {
+		return utils.NewNotFoundError("Announcement", announcement.ID)
+	}
+	stored := *announcement
+	m.announcements[announcement.ID] = &stored
+	return nil
+}
+
+func (m *MockAnnouncementRepository) Delete(ctx context.Context, id int64) error {
+	if _, ok := m.announcements[id]; !ok {
+		return utils.NewNotFoundError("Announcement", id)
+	}
+	delete(m.announcements, id)
+	delete(m.reads, id)
+	return nil
+}
+
+func (m *MockAnnouncementRepository) MarkRead(ctx context.Context, id, userID int64, readAt time.Time) error {
+	if m.reads[id] == nil {
+		m.reads[id] = make(map[int64]time.Time)
+	}
+	if _, ok := m.reads[id][userID]; !ok {
+		m.reads[id][userID] = readAt
+	}
+	return nil
+}
+
+func TestAnnouncementService_ReadTracking(t *testing.T) {
+	repo := NewMockAnnouncementRepository()
+	svc := NewAnnouncementService(repo)
+	ctx := context.Background()
+
+	first, err := svc.CreateAnnouncement(ctx, 1, &models.AnnouncementCreate{
+		Title:    "Maintenance",
+		Message:  "The service is unavailable tonight from 22:00",
+		Category: models.AnnouncementMaintenance,
+	})
+	if err != nil {
+		t.Fatalf("CreateAnnouncement failed: %v", err)
+	}
+	second, err := svc.CreateAnnouncement(ctx, 1, &models.AnnouncementCreate{
+		Title:    "New export format",
+		Message:  "Redacted documents can now be exported as images",
+		Category: models.AnnouncementFeature,
+	})
+	if err != nil {
+		t.Fatalf("CreateAnnouncement failed: %v", err)
+	}
+
+	if err := svc.MarkRead(ctx, 2, first.ID); err != nil {
+		t.Fatalf("MarkRead failed: %v", err)
+	}
+
+	all, err := svc.ListForUser(ctx, 2, false)
+	if err != nil {
+		t.Fatalf("ListForUser failed: %v", err)
+	}
+	if len(all) != 2 || all[0].Read || !all[1].Read {
+		t.Errorf("expected the first announcement to be read by user 2, got %+v", all)
+	}
+
+	unread, err := svc.ListForUser(ctx, 2, true)
+	if err != nil {
+		t.Fatalf("ListForUser failed: %v", err)
+	}
+	if len(unread) != 1 || unread[0].ID != second.ID {
+		t.Errorf("expected only the second announcement to be unread, got %+v", unread)
+	}
+
+	// Read state is tracked per user
+	others, err := svc.ListForUser(ctx, 3, true)
+	if err != nil {
+		t.Fatalf("ListForUser failed: %v", err)
+	}
+	if len(others) != 2 {
+		t.Errorf("expected both announcements to be unread for user 3, got %d", len(others))
+	}
+}
+
+func TestAnnouncementService_Window(t *testing.T) {
+	repo := NewMockAnnouncementRepository()
+	svc := NewAnnouncementService(repo)
+	now := time.Date(2026, 3, 1, 12, 0, 0, 0, time.UTC)
+	clk := fakeclock.New(now)
+	svc.SetClock(clk)
+	ctx := context.Background()
+
+	startsAt := now.Add(10 * time.Hour)
+	endsAt := startsAt.Add(2 * time.Hour)
+	scheduled, err := svc.CreateAnnouncement(ctx, 1, &models.AnnouncementCreate{
+		Title:    "Maintenance",
+		Message:  "The service is unavailable during the upgrade",
+		Category: models.AnnouncementMaintenance,
+		StartsAt: &startsAt,
+		EndsAt:   &endsAt,
+	})
+	if err != nil {
+		t.Fatalf("CreateAnnouncement failed: %v", err)
+	}
+
+	// Scheduled announcements are hidden until they start
+	announcements, err := svc.ListForUser(ctx, 2, false)
+	if err != nil {
+		t.Fatalf("ListForUser failed: %v", err)
+	}
+	if len(announcements) != 0 {
+		t.Errorf("expected no announcements before the start, got %d", len(announcements))
+	}
+	if err := svc.MarkRead(ctx, 2, scheduled.ID); !errors.Is(err, utils.ErrNotFound) {
+		t.Errorf("expected not found for a scheduled announcement, got %v", err)
+	}
+
+	clk.Set(startsAt)
+	announcements, err = svc.ListForUser(ctx, 2, false)
+	if err != nil {
+		t.Fatalf("ListForUser failed: %v", err)
+	}
+	if len(announcements) != 1 {
+		t.Errorf("expected the announcement once it starts, got %d", len(announcements))
+	}
+
+	before := startsAt.Add(-time.Hour)
+	if _, err := svc.UpdateAnnouncement(ctx, 1, scheduled.ID, &models.AnnouncementUpdate{EndsAt: &before}); !errors.Is(err, utils.ErrValidation) {
+		t.Errorf("expected a validation error for an announcement ending before it starts, got %v", err)
+	}
+	if _, err := svc.CreateAnnouncement(ctx, 1, &models.AnnouncementCreate{
+		Title:    "Backwards",
+		Message:  "Ends before it starts",
+		Category: models.AnnouncementInfo,
+		StartsAt: &startsAt,
+		EndsAt:   &before,
+	}); !errors.Is(err, utils.ErrValidation) {
+		t.Errorf("expected a validation error for an announcement ending before it starts, got %v", err)
+	}
+}
+
+func TestAnnouncementService_UpdateAndDelete(t *testing.T) {
+	repo := NewMockAnnouncementRepository()
+	svc := NewAnnouncementService(repo)
+	ctx := context.Background()
+
+	announcement, err := svc.CreateAnnouncement(ctx, 1, &models.AnnouncementCreate{
+		Title:    "Maintenance",
+		Message:  "Tonight",
+		Category: models.AnnouncementMaintenance,
+	})
+	if err != nil {
+		t.Fatalf("CreateAnnouncement failed: %v", err)
+	}
+
+	updated, err := svc.UpdateAnnouncement(ctx, 1, announcement.ID, &models.AnnouncementUpdate{Message: "Postponed to tomorrow"})
+	if err != nil {
+		t.Fatalf("UpdateAnnouncement failed: %v", err)
+	}
+	if updated.Message != "Postponed to tomorrow" || updated.Title != "Maintenance" {
+		t.Errorf("expected the message to change and the title to be kept, got %+v", updated)
+	}
+
+	if _, err := svc.UpdateAnnouncement(ctx, 1, 99, &models.AnnouncementUpdate{}); !errors.Is(err, utils.ErrNotFound) {
+		t.Errorf("expected not found for an unknown announcement, got %v", err)
+	}
+
+	if err := svc.DeleteAnnouncement(ctx, announcement.ID); err != nil {
+		t.Fatalf("DeleteAnnouncement failed: %v", err)
+	}
+	if err := svc.MarkRead(ctx, 2, announcement.ID); !errors.Is(err, utils.ErrNotFound) {
+		t.Errorf("expected not found for a deleted announcement, got %v", err)
+	}
+}
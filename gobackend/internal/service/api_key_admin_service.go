@@ -0,0 +1,270 @@
+// Package service provides business logic implementations.
+package service
+
+import (
+	"context"
+	"time"
+
+	"github.com/rs/zerolog/log"
+
+	"github.com/yasinhessnawi1/Hideme_Backend/internal/constants"
+	"github.com/yasinhessnawi1/Hideme_Backend/internal/models"
+	"github.com/yasinhessnawi1/Hideme_Backend/internal/repository"
+	"github.com/yasinhessnawi1/Hideme_Backend/internal/utils"
+	"github.com/yasinhessnawi1/Hideme_Backend/internal/utils/clock"
+)
+
+// RotationCampaignNotifier tells users that administrators require their API keys to be rotated.
+type RotationCampaignNotifier interface {
+	SendRotationCampaignEmail(toEmail, toName, campaignName string, keyNames []string, deadline time.Time) error
+}
+
+// APIKeyAdminService lets administrators manage the API keys of all users: list them
+// with their status, disable keys, set the expiry policy and start rotation campaigns
+// requiring owners to rotate their keys before a deadline.
+//
+// Changes take effect on verification, which caches successful lookups for a short
+// time, so a disabled key may keep working for up to the cache TTL.
+type APIKeyAdminService struct {
+	apiKeyRepo repository.APIKeyRepository
+	adminRepo  repository.APIKeyAdminRepository
+	notifier   RotationCampaignNotifier
+	clock      clock.Clock
+}
+
+// NewAPIKeyAdminService creates a new APIKeyAdminService.
+//
+// Parameters:
+//   - apiKeyRepo: Repository for individual API keys
+//   - adminRepo: Repository for the key listing, expiry policy and rotation campaigns
+//
+// Returns:
+//   - A configured APIKeyAdminService
+func NewAPIKeyAdminService(apiKeyRepo repository.APIKeyRepository, adminRepo repository.APIKeyAdminRepository) *APIKeyAdminService {
+	return &APIKeyAdminService{
+		apiKeyRepo: apiKeyRepo,
+		adminRepo:  adminRepo,
+	}
+}
+
+// SetClock sets the clock keys are disabled and rotation campaigns are
+// started and expired by.
+func (s *APIKeyAdminService) SetClock(c clock.Clock) {
+	s.clock = c
+}
+
+// now returns the current time of the service's clock.
+func (s *APIKeyAdminService) now() time.Time {
+	return clock.Or(s.clock).Now()
+}
+
+// SetRotationCampaignNotifier enables emailing the owners of the keys in a rotation campaign.
+// Without a notifier, campaigns are only logged and owners see them in their key listing.
+//
+// Parameters:
+//   - notifier: The notifier sending the emails
+func (s *APIKeyAdminService) SetRotationCampaignNotifier(notifier RotationCampaignNotifier) {
+	s.notifier = notifier
+}
+
+// ListKeys returns the API keys of all users, or of one user, with their status.
+//
+// Parameters:
+//   - ctx: Context for the operation
+//   - filter: The user and status to select
+//
+// Returns:
+//   - The keys, newest first, without their hashes
+//   - ValidationError if the status is unknown
+//   - Other errors if retrieval fails
+func (s *APIKeyAdminService) ListKeys(ctx context.Context, filter models.APIKeyFilter) ([]*models.AdminAPIKey, error) {
+	if filter.Status != "" && !validAPIKeyStatus(filter.Status) {
+		return nil, utils.NewValidationError("status", constants.MsgAPIKeyStatusInvalid)
+	}
+
+	keys, err := s.adminRepo.List(ctx, filter.UserID)
+	if err != nil {
+		return nil, err
+	}
+
+	// The status depends on the current time, so it is derived here rather than stored
+	now := s.now()
+	selected := make([]*models.AdminAPIKey, 0, len(keys))
+	for _, key := range keys {
+		key.Status = key.APIKey.Status(now)
+		if filter.Status == "" || key.Status == filter.Status {
+			selected = append(selected, key)
+		}
+	}
+
+	return selected, nil
+}
+
+// DisableKey stops an API key from working, such as when it is suspected to be compromised.
+// Disabling can't be undone; the owner creates a new key instead.
+//
+// Parameters:
+//   - ctx: Context for the operation
+//   - adminID: The ID of the administrator disabling the key
+//   - keyID: The ID of the key
+//
+// Returns:
+//   - NotFoundError if the key doesn't exist
+//   - A conflict error if the key is already disabled
+//   - Other errors if the key could not be updated
+func (s *APIKeyAdminService) DisableKey(ctx context.Context, adminID int64, keyID string) error {
+	apiKey, err := s.apiKeyRepo.GetByID(ctx, keyID)
+	if err != nil {
+		return err
+	}
+
+	if err := s.adminRepo.Disable(ctx, keyID, s.now()); err != nil {
+		return err
+	}
+
+	log.Info().
+		Str("key_id", keyID).
+		Int64("user_id", apiKey.UserID).
+		Int64("admin_id", adminID).
+		Msg("API key disabled by administrator")
+
+	return nil
+}
+
+// GetPolicy returns the expiry policy for API keys.
+//
+// Parameters:
+//   - ctx: Context for the operation
+//
+// Returns:
+//   - The policy; without a limit if it was never set
+//   - An error if retrieval fails
+func (s *APIKeyAdminService) GetPolicy(ctx context.Context) (*models.APIKeyPolicy, error) {
+	return s.adminRepo.GetPolicy(ctx)
+}
+
+// SetPolicy changes the expiry policy for API keys. A new limit applies to new keys and
+// to existing ones: keys valid for longer than the limit now expire at the limit.
+//
+// Parameters:
+//   - ctx: Context for the operation
+//   - adminID: The ID of the administrator changing the policy
+//   - update: The new maximum lifetime
+//
+// Returns:
+//   - The policy now in force and the number of keys whose expiry was brought forward
+//   - An error if the policy could not be stored
+func (s *APIKeyAdminService) SetPolicy(ctx context.Context, adminID int64, update *models.APIKeyPolicyUpdate) (*models.APIKeyPolicyResult, error) {
+	now := s.now()
+	policy := &models.APIKeyPolicy{
+		MaxLifetimeDays: update.MaxLifetimeDays,
+		UpdatedBy:       &adminID,
+		UpdatedAt:       &now,
+	}
+
+	shortened, err := s.adminRepo.SetPolicy(ctx, policy)
+	if err != nil {
+		return nil, err
+	}
+
+	log.Info().
+		Int("max_lifetime_days", policy.MaxLifetimeDays).
+		Int64("keys_shortened", shortened).
+		Int64("admin_id", adminID).
+		Msg("API key expiry policy changed")
+
+	return &models.APIKeyPolicyResult{Policy: policy, KeysShortened: shortened}, nil
+}
+
+// StartRotationCampaign requires the owners of the selected API keys to rotate them before
+// a deadline. Keys that are disabled, expired, already rotated or in another campaign are
+// left out. Each owner is emailed once, listing all of their keys in the campaign.
+//
+// Parameters:
+//   - ctx: Context for the operation
+//   - adminID: The ID of the administrator starting the campaign
+//   - req: The name, deadline and key selection of the campaign
+//
+// Returns:
+//   - The campaign with the number of keys it covers
+//   - ValidationError if the deadline has passed
+//   - Other errors if the campaign could not be stored
+func (s *APIKeyAdminService) StartRotationCampaign(ctx context.Context, adminID int64, req *models.RotationCampaignCreate) (*models.RotationCampaign, error) {
+	now := s.now()
+	if !req.Deadline.After(now) {
+		return nil, utils.NewValidationError("deadline", constants.MsgRotationDeadlinePast)
+	}
+
+	campaign := &models.RotationCampaign{
+		Name:      req.Name,
+		Deadline:  req.Deadline,
+		CreatedBy: adminID,
+		CreatedAt: now,
+	}
+
+	keys, err := s.adminRepo.CreateCampaign(ctx, campaign, req.UserID, req.CreatedBefore)
+	if err != nil {
+		return nil, err
+	}
+
+	log.Info().
+		Int64("campaign_id", campaign.ID).
+		Int("key_count", campaign.KeyCount).
+		Time("deadline", campaign.Deadline).
+		Int64("admin_id", adminID).
+		Msg("API key rotation campaign started")
+
+	s.notifyOwners(campaign, keys)
+
+	return campaign, nil
+}
+
+// ListCampaigns returns the rotation campaigns with their progress.
+//
+// Parameters:
+//   - ctx: Context for the operation
+//
+// Returns:
+//   - The campaigns, newest first
+//   - An error if retrieval fails
+func (s *APIKeyAdminService) ListCampaigns(ctx context.Context) ([]*models.RotationCampaign, error) {
+	return s.adminRepo.ListCampaigns(ctx)
+}
+
+// notifyOwners emails each owner of keys in a campaign once.
+// Failures are logged; the campaign is in force regardless.
+func (s *APIKeyAdminService) notifyOwners(campaign *models.RotationCampaign, keys []*models.AdminAPIKey) {
+	if s.notifier == nil {
+		return
+	}
+
+	// Group the keys by owner, keeping the order in which owners first appear
+	var owners []*models.AdminAPIKey
+	names := make(map[int64][]string)
+	for _, key := range keys {
+		if _, ok := names[key.UserID]; !ok {
+			owners = append(owners, key)
+		}
+		names[key.UserID] = append(names[key.UserID], key.Name)
+	}
+
+	for _, owner := range owners {
+		if err := s.notifier.SendRotationCampaignEmail(owner.Email, owner.Username, campaign.Name, names[owner.UserID], campaign.Deadline); err != nil {
+			log.Error().Err(err).
+				Int64("campaign_id", campaign.ID).
+				Int64("user_id", owner.UserID).
+				Msg("Failed to notify user of API key rotation campaign")
+		}
+	}
+}
+
+// validAPIKeyStatus reports whether a status is one of the constants.APIKeyStatus values.
+func validAPIKeyStatus(status string) bool {
+	switch status {
+	case constants.APIKeyStatusActive, constants.APIKeyStatusPendingRotation, constants.APIKeyStatusRotated,
+		constants.APIKeyStatusExpired, constants.APIKeyStatusDisabled:
+		return true
+	default:
+		return false
+	}
+}
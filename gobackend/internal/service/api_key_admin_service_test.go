@@ -0,0 +1,238 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"sort"
+	"testing"
+	"time"
+
+	"github.com/yasinhessnawi1/Hideme_Backend/internal/constants"
+	"github.com/yasinhessnawi1/Hideme_Backend/internal/models"
+	"github.com/yasinhessnawi1/Hideme_Backend/internal/utils"
+)
+
+// MockAPIKeyAdminRepository is an in-memory implementation of repository.APIKeyAdminRepository
+// over the keys of a MockAPIKeyRepository
+type MockAPIKeyAdminRepository struct {
+	keys      *MockAPIKeyRepository
+	users     map[int64]*models.User
+	policy    models.APIKeyPolicy
+	campaigns []*models.RotationCampaign
+}
+
+func NewMockAPIKeyAdminRepository(keys *MockAPIKeyRepository, users ...*models.User) *MockAPIKeyAdminRepository {
+	m := &MockAPIKeyAdminRepository{keys: keys, users: make(map[int64]*models.User)}
+	for _, user := range users {
+		m.users[user.ID] = user
+	}
+	return m
+}
+
+func (m *MockAPIKeyAdminRepository) adminKey(key *models.APIKey) *models.AdminAPIKey {
+	user := m.users[key.UserID]
+	return &models.AdminAPIKey{APIKey: *key, Username: user.Username, Email: user.Email}
+}
+
+func (m *MockAPIKeyAdminRepository) List(ctx context.Context, userID int64) ([]*models.AdminAPIKey, error) {
+	result := []*models.AdminAPIKey{}
+	for _, key := range m.keys.apiKeys {
+		if userID == 0 || key.UserID == userID {
+			result = append(result, m.adminKey(key))
+		}
+	}
+	sort.Slice(result, func(i, j int) bool { return result[i].ID < result[j].ID })
+	return result, nil
+}
+
+func (m *MockAPIKeyAdminRepository) Disable(ctx context.Context, keyID string, disabledAt time.Time) error {
+	key, ok := m.keys.apiKeys[keyID]
+	if !ok || key.DisabledAt != nil {
+		return utils.New(utils.ErrBadRequest, constants.StatusConflict, "API key not found or already disabled")
+	}
+	key.DisabledAt = &disabledAt
+	return nil
+}
+
+func (m *MockAPIKeyAdminRepository) GetPolicy(ctx context.Context) (*models.APIKeyPolicy, error) {
+	policy := m.policy
+	return &policy, nil
+}
+
+func (m *MockAPIKeyAdminRepository) SetPolicy(ctx context.Context, policy *models.APIKeyPolicy) (int64, error) {
+	m.policy = *policy
+	var shortened int64
+	if limit := policy.MaxLifetime(); limit > 0 {
+		for _, key := range m.keys.apiKeys {
+			if key.ExpiresAt.Sub(key.CreatedAt) > limit {
+				key.ExpiresAt = key.CreatedAt.Add(limit)
+				shortened++
+			}
+		}
+	}
+	return shortened, nil
+}
+
+func (m *MockAPIKeyAdminRepository) CreateCampaign(ctx context.Context, campaign *models.RotationCampaign, userID int64, createdBefore *time.Time) ([]*models.AdminAPIKey, error) {
+	campaign.ID = int64(len(m.campaigns) + 1)
+	keys := []*models.AdminAPIKey{}
+	ids := make([]string, 0, len(m.keys.apiKeys))
+	for id := range m.keys.apiKeys {
+		ids = append(ids, id)
+	}
+	sort.Strings(ids)
+	for _, id := range ids {
+		key := m.keys.apiKeys[id]
+		if !key.IsUsable(campaign.CreatedAt) || key.RotatedAt != nil || (userID != 0 && key.UserID != userID) {
+			continue
+		}
+		key.RotationCampaignID = &campaign.ID
+		key.RotationDeadline = &campaign.Deadline
+		keys = append(keys, m.adminKey(key))
+	}
+	campaign.KeyCount = len(keys)
+	m.campaigns = append(m.campaigns, campaign)
+	return keys, nil
+}
+
+func (m *MockAPIKeyAdminRepository) ListCampaigns(ctx context.Context) ([]*models.RotationCampaign, error) {
+	return m.campaigns, nil
+}
+
+// MockRotationCampaignNotifier records the campaign emails it was asked to send.
+type MockRotationCampaignNotifier struct {
+	sent map[string][]string
+}
+
+func (m *MockRotationCampaignNotifier) SendRotationCampaignEmail(toEmail, toName, campaignName string, keyNames []string, deadline time.Time) error {
+	m.sent[toEmail] = append(m.sent[toEmail], keyNames...)
+	return nil
+}
+
+func newTestAPIKeyAdminService(t *testing.T) (*APIKeyAdminService, *MockAPIKeyRepository, *MockRotationCampaignNotifier) {
+	alice := &models.User{ID: 1, Username: "alice", Email: "alice@example.com"}
+	bob := &models.User{ID: 2, Username: "bob", Email: "bob@example.com"}
+
+	keys := NewMockAPIKeyRepository()
+	now := time.Now()
+	for _, key := range []*models.APIKey{
+		{ID: "a1", UserID: alice.ID, Name: "CI", CreatedAt: now.Add(-time.Hour), ExpiresAt: now.Add(365 * 24 * time.Hour)},
+		{ID: "a2", UserID: alice.ID, Name: "Sync", CreatedAt: now.Add(-time.Hour), ExpiresAt: now.Add(24 * time.Hour)},
+		{ID: "b1", UserID: bob.ID, Name: "Backup", CreatedAt: now.Add(-48 * time.Hour), ExpiresAt: now.Add(-time.Hour)},
+	} {
+		if err := keys.Create(context.Background(), key); err != nil {
+			t.Fatalf("Failed to create API key: %v", err)
+		}
+	}
+
+	service := NewAPIKeyAdminService(keys, NewMockAPIKeyAdminRepository(keys, alice, bob))
+	notifier := &MockRotationCampaignNotifier{sent: make(map[string][]string)}
+	service.SetRotationCampaignNotifier(notifier)
+	return service, keys, notifier
+}
+
+func TestAPIKeyAdminService_ListKeys(t *testing.T) {
+	service, _, _ := newTestAPIKeyAdminService(t)
+
+	keys, err := service.ListKeys(context.Background(), models.APIKeyFilter{})
+	if err != nil {
+		t.Fatalf("ListKeys() error = %v", err)
+	}
+	if len(keys) != 3 || keys[0].Status != constants.APIKeyStatusActive || keys[2].Status != constants.APIKeyStatusExpired {
+		t.Errorf("Expected three keys with their status, got %+v", keys)
+	}
+
+	keys, err = service.ListKeys(context.Background(), models.APIKeyFilter{Status: constants.APIKeyStatusExpired})
+	if err != nil {
+		t.Fatalf("ListKeys() error = %v", err)
+	}
+	if len(keys) != 1 || keys[0].ID != "b1" || keys[0].Username != "bob" {
+		t.Errorf("Expected bob's expired key, got %+v", keys)
+	}
+
+	_, err = service.ListKeys(context.Background(), models.APIKeyFilter{Status: "unknown"})
+	var appErr *utils.AppError
+	if !errors.As(err, &appErr) || appErr.StatusCode != 400 {
+		t.Errorf("Expected bad request for an unknown status, got %v", err)
+	}
+}
+
+func TestAPIKeyAdminService_DisableKey(t *testing.T) {
+	service, keys, _ := newTestAPIKeyAdminService(t)
+
+	if err := service.DisableKey(context.Background(), 9, "a1"); err != nil {
+		t.Fatalf("DisableKey() error = %v", err)
+	}
+	if !keys.apiKeys["a1"].IsDisabled() {
+		t.Error("Expected the key to be disabled")
+	}
+
+	var appErr *utils.AppError
+	if err := service.DisableKey(context.Background(), 9, "a1"); !errors.As(err, &appErr) || appErr.StatusCode != constants.StatusConflict {
+		t.Errorf("Expected conflict for disabling a disabled key, got %v", err)
+	}
+	if err := service.DisableKey(context.Background(), 9, "missing"); !errors.As(err, &appErr) || appErr.StatusCode != 404 {
+		t.Errorf("Expected not found for an unknown key, got %v", err)
+	}
+}
+
+func TestAPIKeyAdminService_SetPolicy(t *testing.T) {
+	service, keys, _ := newTestAPIKeyAdminService(t)
+
+	result, err := service.SetPolicy(context.Background(), 9, &models.APIKeyPolicyUpdate{MaxLifetimeDays: 30})
+	if err != nil {
+		t.Fatalf("SetPolicy() error = %v", err)
+	}
+	if result.KeysShortened != 1 || result.Policy.MaxLifetimeDays != 30 || *result.Policy.UpdatedBy != 9 {
+		t.Errorf("Expected one key shortened by a 30 day policy, got %+v", result)
+	}
+	if lifetime := keys.apiKeys["a1"].ExpiresAt.Sub(keys.apiKeys["a1"].CreatedAt); lifetime != 30*24*time.Hour {
+		t.Errorf("Expected the long key to be capped to 30 days, got %v", lifetime)
+	}
+
+	policy, err := service.GetPolicy(context.Background())
+	if err != nil || policy.MaxLifetimeDays != 30 {
+		t.Errorf("Expected the stored policy, got %+v, %v", policy, err)
+	}
+}
+
+func TestAPIKeyAdminService_StartRotationCampaign(t *testing.T) {
+	service, keys, notifier := newTestAPIKeyAdminService(t)
+
+	// The deadline must be in the future
+	_, err := service.StartRotationCampaign(context.Background(), 9, &models.RotationCampaignCreate{
+		Name:     "Leaked CI logs",
+		Deadline: time.Now().Add(-time.Hour),
+	})
+	var appErr *utils.AppError
+	if !errors.As(err, &appErr) || appErr.StatusCode != 400 {
+		t.Errorf("Expected bad request for a past deadline, got %v", err)
+	}
+
+	deadline := time.Now().Add(7 * 24 * time.Hour)
+	campaign, err := service.StartRotationCampaign(context.Background(), 9, &models.RotationCampaignCreate{
+		Name:     "Leaked CI logs",
+		Deadline: deadline,
+	})
+	if err != nil {
+		t.Fatalf("StartRotationCampaign() error = %v", err)
+	}
+
+	// Bob's key has expired, so only alice's keys are in the campaign
+	if campaign.KeyCount != 2 || campaign.CreatedBy != 9 {
+		t.Errorf("Expected a campaign with alice's two keys, got %+v", campaign)
+	}
+	if keys.apiKeys["a1"].Status(time.Now()) != constants.APIKeyStatusPendingRotation {
+		t.Errorf("Expected alice's key to be pending rotation, got %s", keys.apiKeys["a1"].Status(time.Now()))
+	}
+
+	// Alice is emailed once about both keys
+	if len(notifier.sent) != 1 || len(notifier.sent["alice@example.com"]) != 2 {
+		t.Errorf("Expected one email to alice listing two keys, got %v", notifier.sent)
+	}
+
+	campaigns, err := service.ListCampaigns(context.Background())
+	if err != nil || len(campaigns) != 1 {
+		t.Errorf("Expected one campaign, got %v, %v", campaigns, err)
+	}
+}
@@ -0,0 +1,351 @@
+// Package service provides business logic implementations.
+package service
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/rs/zerolog/log"
+
+	"github.com/yasinhessnawi1/Hideme_Backend/internal/config"
+	"github.com/yasinhessnawi1/Hideme_Backend/internal/constants"
+	"github.com/yasinhessnawi1/Hideme_Backend/internal/models"
+	"github.com/yasinhessnawi1/Hideme_Backend/internal/repository"
+	"github.com/yasinhessnawi1/Hideme_Backend/internal/utils"
+	"github.com/yasinhessnawi1/Hideme_Backend/internal/utils/clock"
+)
+
+// AdminActionExecutor carries out an approved admin action against its target.
+type AdminActionExecutor func(ctx context.Context, targetID int64) error
+
+// ApprovalService implements the two-person approval workflow for destructive admin actions.
+// Requested actions that the policy marks as high-risk are queued until a different
+// administrator approves them; everything else runs immediately. Each action type is
+// carried out by an executor registered at startup, so new destructive operations only
+// need to register themselves to be covered by the workflow.
+type ApprovalService struct {
+	actionRepo     repository.AdminActionRepository
+	policy         *config.ApprovalSettings
+	executors      map[models.AdminActionType]AdminActionExecutor
+	executorsMutex sync.RWMutex
+	screener       *PIIScreener
+	clock          clock.Clock
+}
+
+// NewApprovalService creates a new ApprovalService.
+//
+// Parameters:
+//   - actionRepo: Repository for queued admin actions
+//   - policy: The approval policy deciding which actions need a second administrator
+//
+// Returns:
+//   - A configured ApprovalService
+func NewApprovalService(actionRepo repository.AdminActionRepository, policy *config.ApprovalSettings) *ApprovalService {
+	return &ApprovalService{
+		actionRepo: actionRepo,
+		policy:     policy,
+		executors:  make(map[models.AdminActionType]AdminActionExecutor),
+	}
+}
+
+// SetClock sets the clock reviews are recorded at.
+func (s *ApprovalService) SetClock(c clock.Clock) {
+	s.clock = c
+}
+
+// now returns the current time of the service's clock.
+func (s *ApprovalService) now() time.Time {
+	return clock.Or(s.clock).Now()
+}
+
+// RegisterExecutor registers the function that carries out an action type.
+// Action types without an executor cannot be requested.
+//
+// Parameters:
+//   - actionType: The action type the executor handles
+//   - executor: The function that performs the action
+func (s *ApprovalService) RegisterExecutor(actionType models.AdminActionType, executor AdminActionExecutor) {
+	s.executorsMutex.Lock()
+	defer s.executorsMutex.Unlock()
+
+	s.executors[actionType] = executor
+}
+
+// SetPIIScreener enables screening of request reasons and review notes for personal data.
+// Actions are kept as an audit trail, so personal data entered there would outlive the
+// erasure of the user it belongs to.
+//
+// Parameters:
+//   - screener: The screener checking free-text fields
+func (s *ApprovalService) SetPIIScreener(screener *PIIScreener) {
+	s.screener = screener
+}
+
+// screen checks a free-text field entered by an administrator for personal data.
+func (s *ApprovalService) screen(ctx context.Context, adminID int64, field, value string) error {
+	if s.screener == nil {
+		return nil
+	}
+	return s.screener.Screen(ctx, adminID, map[string]string{field: value})
+}
+
+// executorFor returns the registered executor for an action type.
+func (s *ApprovalService) executorFor(actionType models.AdminActionType) (AdminActionExecutor, bool) {
+	s.executorsMutex.RLock()
+	defer s.executorsMutex.RUnlock()
+
+	executor, ok := s.executors[actionType]
+	return executor, ok
+}
+
+// RequiresApproval checks whether the policy requires a second administrator for an action type.
+//
+// Parameters:
+//   - actionType: The action type to check
+//
+// Returns:
+//   - true if the action must be approved before it runs, false otherwise
+func (s *ApprovalService) RequiresApproval(actionType models.AdminActionType) bool {
+	for _, required := range s.policy.RequiredActions {
+		if models.AdminActionType(required) == actionType {
+			return true
+		}
+	}
+	return false
+}
+
+// RequestAction records a request for a destructive admin action.
+// If the policy requires approval the action is queued as pending; otherwise it is
+// executed straight away on behalf of the requester.
+//
+// Parameters:
+//   - ctx: Context for the operation
+//   - requesterID: The user ID of the requesting administrator
+//   - req: The requested action
+//
+// Returns:
+//   - The recorded action in its resulting state
+//   - ValidationError if the action type is not supported or the reason contains blocked personal data
+//   - Other errors if storing or executing the action fails
+func (s *ApprovalService) RequestAction(ctx context.Context, requesterID int64, req *models.AdminActionRequest) (*models.AdminAction, error) {
+	if _, ok := s.executorFor(req.ActionType); !ok {
+		return nil, utils.NewValidationError("action_type", fmt.Sprintf("Unsupported action type: %s", req.ActionType))
+	}
+	if err := s.screen(ctx, requesterID, "reason", req.Reason); err != nil {
+		return nil, err
+	}
+
+	action := models.NewAdminAction(req.ActionType, req.TargetID, req.Reason, requesterID, s.policy.Expiry)
+	if err := s.actionRepo.Create(ctx, action); err != nil {
+		return nil, fmt.Errorf("failed to queue admin action: %w", err)
+	}
+
+	log.Info().
+		Int64("action_id", action.ID).
+		Str("action_type", string(action.ActionType)).
+		Int64("target_id", action.TargetID).
+		Int64("requested_by", requesterID).
+		Str("category", constants.LogCategoryAdmin).
+		Msg("Admin action requested")
+
+	// Actions outside the policy run immediately, approved by the requester
+	if !s.RequiresApproval(action.ActionType) {
+		return s.execute(ctx, action, requesterID, "")
+	}
+
+	return action, nil
+}
+
+// ApproveAction approves a pending action and executes it.
+// The approving administrator must be different from the one who requested the action.
+//
+// Parameters:
+//   - ctx: Context for the operation
+//   - id: The ID of the action to approve
+//   - reviewerID: The user ID of the approving administrator
+//   - note: An optional comment explaining the decision
+//
+// Returns:
+//   - The action in its resulting state
+//   - ValidationError if the note contains blocked personal data
+//   - ForbiddenError if the reviewer requested the action themselves
+//   - BadRequestError if the action has expired
+//   - A conflict error if the action is no longer pending
+//   - Other errors if executing the action fails
+func (s *ApprovalService) ApproveAction(ctx context.Context, id int64, reviewerID int64, note string) (*models.AdminAction, error) {
+	if err := s.screen(ctx, reviewerID, "note", note); err != nil {
+		return nil, err
+	}
+
+	action, err := s.getPendingAction(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	if action.RequestedBy == reviewerID {
+		return nil, utils.NewForbiddenError("An admin action must be approved by a different administrator")
+	}
+
+	return s.execute(ctx, action, reviewerID, note)
+}
+
+// RejectAction rejects a pending action so it will never run.
+// The requesting administrator may reject their own action to withdraw it.
+//
+// Parameters:
+//   - ctx: Context for the operation
+//   - id: The ID of the action to reject
+//   - reviewerID: The user ID of the rejecting administrator
+//   - note: An optional comment explaining the decision
+//
+// Returns:
+//   - The rejected action
+//   - ValidationError if the note contains blocked personal data
+//   - BadRequestError if the action has expired
+//   - A conflict error if the action is no longer pending
+func (s *ApprovalService) RejectAction(ctx context.Context, id int64, reviewerID int64, note string) (*models.AdminAction, error) {
+	if err := s.screen(ctx, reviewerID, "note", note); err != nil {
+		return nil, err
+	}
+
+	action, err := s.getPendingAction(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	s.markReviewed(action, models.ActionStatusRejected, reviewerID, note)
+	if err := s.actionRepo.UpdateStatus(ctx, action, models.ActionStatusPending); err != nil {
+		return nil, err
+	}
+
+	log.Info().
+		Int64("action_id", action.ID).
+		Str("action_type", string(action.ActionType)).
+		Int64("reviewed_by", reviewerID).
+		Str("category", constants.LogCategoryAdmin).
+		Msg("Admin action rejected")
+
+	return action, nil
+}
+
+// GetAction retrieves a single admin action.
+//
+// Parameters:
+//   - ctx: Context for the operation
+//   - id: The ID of the action
+//
+// Returns:
+//   - The action
+//   - NotFoundError if the action doesn't exist
+func (s *ApprovalService) GetAction(ctx context.Context, id int64) (*models.AdminAction, error) {
+	return s.actionRepo.GetByID(ctx, id)
+}
+
+// ListActions retrieves admin actions, newest first.
+//
+// Parameters:
+//   - ctx: Context for the operation
+//   - status: Only return actions in this status (empty for all)
+//
+// Returns:
+//   - The matching actions
+//   - An error if retrieval fails
+func (s *ApprovalService) ListActions(ctx context.Context, status models.AdminActionStatus) ([]*models.AdminAction, error) {
+	actions, err := s.actionRepo.List(ctx, status)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list admin actions: %w", err)
+	}
+
+	return actions, nil
+}
+
+// ExpirePendingActions marks pending actions that passed their deadline as expired.
+// This is run periodically as a maintenance task.
+//
+// Parameters:
+//   - ctx: Context for the operation
+//
+// Returns:
+//   - The number of actions that expired
+//   - An error if the update fails
+func (s *ApprovalService) ExpirePendingActions(ctx context.Context) (int64, error) {
+	return s.actionRepo.ExpirePending(ctx)
+}
+
+// getPendingAction retrieves an action and verifies that it can still be reviewed.
+// Actions found past their deadline are marked as expired on the spot.
+func (s *ApprovalService) getPendingAction(ctx context.Context, id int64) (*models.AdminAction, error) {
+	action, err := s.actionRepo.GetByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	if action.IsExpired() {
+		s.markReviewed(action, models.ActionStatusExpired, 0, "")
+		action.ReviewedBy = nil
+		if err := s.actionRepo.UpdateStatus(ctx, action, models.ActionStatusPending); err != nil {
+			log.Warn().Err(err).Int64("action_id", id).Msg("Failed to mark admin action as expired")
+		}
+		return nil, utils.NewBadRequestError(fmt.Sprintf("Admin action %d has expired", id))
+	}
+
+	if !action.IsPending() {
+		return nil, utils.New(utils.ErrBadRequest, constants.StatusConflict,
+			fmt.Sprintf("Admin action %d is already %s", id, action.Status))
+	}
+
+	return action, nil
+}
+
+// execute approves a pending action and runs its executor, recording the outcome.
+// The action is claimed as approved first, so two concurrent approvals cannot both run it.
+func (s *ApprovalService) execute(ctx context.Context, action *models.AdminAction, reviewerID int64, note string) (*models.AdminAction, error) {
+	executor, ok := s.executorFor(action.ActionType)
+	if !ok {
+		return nil, utils.NewValidationError("action_type", fmt.Sprintf("Unsupported action type: %s", action.ActionType))
+	}
+
+	s.markReviewed(action, models.ActionStatusApproved, reviewerID, note)
+	if err := s.actionRepo.UpdateStatus(ctx, action, models.ActionStatusPending); err != nil {
+		return nil, err
+	}
+
+	execErr := executor(ctx, action.TargetID)
+
+	action.Status = models.ActionStatusExecuted
+	if execErr != nil {
+		action.Status = models.ActionStatusFailed
+		action.ExecutionError = execErr.Error()
+	}
+
+	if err := s.actionRepo.UpdateStatus(ctx, action, models.ActionStatusApproved); err != nil {
+		log.Error().Err(err).Int64("action_id", action.ID).Msg("Failed to record admin action outcome")
+	}
+
+	log.Info().
+		Int64("action_id", action.ID).
+		Str("action_type", string(action.ActionType)).
+		Int64("target_id", action.TargetID).
+		Int64("requested_by", action.RequestedBy).
+		Int64("approved_by", reviewerID).
+		Str("status", string(action.Status)).
+		Str("category", constants.LogCategoryAdmin).
+		Msg("Admin action executed")
+
+	if execErr != nil {
+		return nil, fmt.Errorf("failed to execute admin action: %w", execErr)
+	}
+
+	return action, nil
+}
+
+// markReviewed records a review decision on an action.
+func (s *ApprovalService) markReviewed(action *models.AdminAction, status models.AdminActionStatus, reviewerID int64, note string) {
+	now := s.now()
+	action.Status = status
+	action.ReviewedBy = &reviewerID
+	action.ReviewNote = note
+	action.ReviewedAt = &now
+}
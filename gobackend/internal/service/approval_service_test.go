@@ -0,0 +1,229 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/yasinhessnawi1/Hideme_Backend/internal/config"
+	"github.com/yasinhessnawi1/Hideme_Backend/internal/models"
+	"github.com/yasinhessnawi1/Hideme_Backend/internal/utils"
+)
+
+// MockAdminActionRepository is an in-memory implementation of repository.AdminActionRepository
+type MockAdminActionRepository struct {
+	actions map[int64]*models.AdminAction
+	nextID  int64
+}
+
+func NewMockAdminActionRepository() *MockAdminActionRepository {
+	return &MockAdminActionRepository{actions: make(map[int64]*models.AdminAction)}
+}
+
+func (m *MockAdminActionRepository) Create(ctx context.Context, action *models.AdminAction) error {
+	m.nextID++
+	action.ID = m.nextID
+	stored := *action
+	m.actions[action.ID] = &stored
+	return nil
+}
+
+func (m *MockAdminActionRepository) GetByID(ctx context.Context, id int64) (*models.AdminAction, error) {
+	action, ok := m.actions[id]
+	if !ok {
+		return nil, utils.NewNotFoundError("AdminAction", id)
+	}
+	copied := *action
+	return &copied, nil
+}
+
+func (m *MockAdminActionRepository) List(ctx context.Context, status models.AdminActionStatus) ([]*models.AdminAction, error) {
+	result := []*models.AdminAction{}
+	for _, action := range m.actions {
+		if status == "" || action.Status == status {
+			result = append(result, action)
+		}
+	}
+	return result, nil
+}
+
+func (m *MockAdminActionRepository) UpdateStatus(ctx context.Context, action *models.AdminAction, from models.AdminActionStatus) error {
+	stored, ok := m.actions[action.ID]
+	if !ok || stored.Status != from {
+		return utils.New(utils.ErrBadRequest, 409, "conflict")
+	}
+	copied := *action
+	m.actions[action.ID] = &copied
+	return nil
+}
+
+func (m *MockAdminActionRepository) ExpirePending(ctx context.Context) (int64, error) {
+	var count int64
+	for _, action := range m.actions {
+		if action.IsExpired() {
+			action.Status = models.ActionStatusExpired
+			count++
+		}
+	}
+	return count, nil
+}
+
+func setupApprovalService(required ...string) (*ApprovalService, *MockAdminActionRepository, *[]int64) {
+	repo := NewMockAdminActionRepository()
+	service := NewApprovalService(repo, &config.ApprovalSettings{RequiredActions: required, Expiry: time.Hour})
+
+	erased := &[]int64{}
+	service.RegisterExecutor(models.ActionUserErasure, func(ctx context.Context, targetID int64) error {
+		*erased = append(*erased, targetID)
+		return nil
+	})
+
+	return service, repo, erased
+}
+
+func TestApprovalService_RequestAction(t *testing.T) {
+	t.Run("Queued when approval is required", func(t *testing.T) {
+		service, repo, erased := setupApprovalService("user_erasure")
+
+		action, err := service.RequestAction(context.Background(), 1, &models.AdminActionRequest{
+			ActionType: models.ActionUserErasure, TargetID: 42, Reason: "GDPR request",
+		})
+
+		require.NoError(t, err)
+		assert.Equal(t, models.ActionStatusPending, action.Status)
+		assert.Equal(t, models.ActionStatusPending, repo.actions[action.ID].Status)
+		assert.Empty(t, *erased)
+	})
+
+	t.Run("Executed immediately when not covered by policy", func(t *testing.T) {
+		service, repo, erased := setupApprovalService()
+
+		action, err := service.RequestAction(context.Background(), 1, &models.AdminActionRequest{
+			ActionType: models.ActionUserErasure, TargetID: 42, Reason: "GDPR request",
+		})
+
+		require.NoError(t, err)
+		assert.Equal(t, models.ActionStatusExecuted, action.Status)
+		assert.Equal(t, models.ActionStatusExecuted, repo.actions[action.ID].Status)
+		assert.Equal(t, []int64{42}, *erased)
+	})
+
+	t.Run("Unsupported action type", func(t *testing.T) {
+		service, _, _ := setupApprovalService("legal_hold_release")
+
+		action, err := service.RequestAction(context.Background(), 1, &models.AdminActionRequest{
+			ActionType: models.AdminActionType("legal_hold_release"), TargetID: 1, Reason: "Case closed",
+		})
+
+		assert.Nil(t, action)
+		assert.True(t, errors.Is(err, utils.ErrValidation))
+	})
+}
+
+func TestApprovalService_ApproveAction(t *testing.T) {
+	request := &models.AdminActionRequest{ActionType: models.ActionUserErasure, TargetID: 42, Reason: "GDPR request"}
+
+	t.Run("Second administrator approves", func(t *testing.T) {
+		service, repo, erased := setupApprovalService("user_erasure")
+		pending, err := service.RequestAction(context.Background(), 1, request)
+		require.NoError(t, err)
+
+		action, err := service.ApproveAction(context.Background(), pending.ID, 2, "Verified")
+
+		require.NoError(t, err)
+		assert.Equal(t, models.ActionStatusExecuted, action.Status)
+		assert.Equal(t, int64(2), *action.ReviewedBy)
+		assert.Equal(t, "Verified", repo.actions[pending.ID].ReviewNote)
+		assert.Equal(t, []int64{42}, *erased)
+	})
+
+	t.Run("Requester cannot approve own action", func(t *testing.T) {
+		service, repo, erased := setupApprovalService("user_erasure")
+		pending, err := service.RequestAction(context.Background(), 1, request)
+		require.NoError(t, err)
+
+		action, err := service.ApproveAction(context.Background(), pending.ID, 1, "")
+
+		assert.Nil(t, action)
+		assert.True(t, errors.Is(err, utils.ErrForbidden))
+		assert.Equal(t, models.ActionStatusPending, repo.actions[pending.ID].Status)
+		assert.Empty(t, *erased)
+	})
+
+	t.Run("Already reviewed action cannot be approved again", func(t *testing.T) {
+		service, _, erased := setupApprovalService("user_erasure")
+		pending, err := service.RequestAction(context.Background(), 1, request)
+		require.NoError(t, err)
+		_, err = service.ApproveAction(context.Background(), pending.ID, 2, "")
+		require.NoError(t, err)
+
+		action, err := service.ApproveAction(context.Background(), pending.ID, 3, "")
+
+		assert.Nil(t, action)
+		assert.Equal(t, 409, utils.ParseError(err).StatusCode)
+		assert.Len(t, *erased, 1)
+	})
+
+	t.Run("Expired action", func(t *testing.T) {
+		service, repo, erased := setupApprovalService("user_erasure")
+		pending, err := service.RequestAction(context.Background(), 1, request)
+		require.NoError(t, err)
+		repo.actions[pending.ID].ExpiresAt = time.Now().Add(-time.Minute)
+
+		action, err := service.ApproveAction(context.Background(), pending.ID, 2, "")
+
+		assert.Nil(t, action)
+		assert.True(t, errors.Is(err, utils.ErrBadRequest))
+		assert.Equal(t, models.ActionStatusExpired, repo.actions[pending.ID].Status)
+		assert.Empty(t, *erased)
+	})
+
+	t.Run("Execution failure is recorded", func(t *testing.T) {
+		service, repo, _ := setupApprovalService("user_erasure")
+		service.RegisterExecutor(models.ActionUserErasure, func(ctx context.Context, targetID int64) error {
+			return errors.New("user not found")
+		})
+		pending, err := service.RequestAction(context.Background(), 1, request)
+		require.NoError(t, err)
+
+		action, err := service.ApproveAction(context.Background(), pending.ID, 2, "")
+
+		assert.Nil(t, action)
+		assert.Error(t, err)
+		assert.Equal(t, models.ActionStatusFailed, repo.actions[pending.ID].Status)
+		assert.Equal(t, "user not found", repo.actions[pending.ID].ExecutionError)
+	})
+}
+
+func TestApprovalService_RejectAction(t *testing.T) {
+	service, repo, erased := setupApprovalService("user_erasure")
+	pending, err := service.RequestAction(context.Background(), 1, &models.AdminActionRequest{
+		ActionType: models.ActionUserErasure, TargetID: 42, Reason: "GDPR request",
+	})
+	require.NoError(t, err)
+
+	// The requester may withdraw their own action
+	action, err := service.RejectAction(context.Background(), pending.ID, 1, "Wrong account")
+
+	require.NoError(t, err)
+	assert.Equal(t, models.ActionStatusRejected, action.Status)
+	assert.Equal(t, models.ActionStatusRejected, repo.actions[pending.ID].Status)
+	assert.Empty(t, *erased)
+
+	// A rejected action can no longer be approved
+	_, err = service.ApproveAction(context.Background(), pending.ID, 2, "")
+	assert.Error(t, err)
+	assert.Empty(t, *erased)
+}
+
+func TestApprovalService_RequiresApproval(t *testing.T) {
+	service, _, _ := setupApprovalService("user_erasure", "tenant_deletion")
+
+	assert.True(t, service.RequiresApproval(models.ActionUserErasure))
+	assert.True(t, service.RequiresApproval(models.ActionTenantDeletion))
+	assert.False(t, service.RequiresApproval(models.AdminActionType("legal_hold_release")))
+}
@@ -0,0 +1,341 @@
+// Package service provides business logic implementations.
+package service
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/rs/zerolog/log"
+
+	"github.com/yasinhessnawi1/Hideme_Backend/internal/constants"
+	"github.com/yasinhessnawi1/Hideme_Backend/internal/models"
+	"github.com/yasinhessnawi1/Hideme_Backend/internal/repository"
+	"github.com/yasinhessnawi1/Hideme_Backend/internal/utils"
+	"github.com/yasinhessnawi1/Hideme_Backend/internal/utils/clock"
+)
+
+var (
+	// ErrAuditorGrantEmpty is returned when an auditor grant covers neither documents nor users
+	ErrAuditorGrantEmpty = utils.New(utils.ErrBadRequest, constants.StatusBadRequest, constants.MsgAuditorGrantEmpty)
+
+	// ErrAuditorGrantInvalid is returned when an auditor token is unknown, expired or revoked
+	ErrAuditorGrantInvalid = utils.New(utils.ErrUnauthorized, constants.StatusUnauthorized, constants.MsgAuditorGrantInvalid)
+
+	// ErrAuditorGrantNotFound is returned when an auditor grant doesn't exist
+	ErrAuditorGrantNotFound = utils.New(utils.ErrNotFound, constants.StatusNotFound, constants.MsgAuditorGrantNotFound)
+)
+
+// AuditedDocuments reads documents on behalf of their owner, usually the DocumentService.
+type AuditedDocuments interface {
+	GetDocumentByID(ctx context.Context, userID, id int64) (*models.Document, error)
+	ListDocumentsAfter(ctx context.Context, userID, afterID int64, limit int) ([]*models.Document, error)
+	ExportDocument(ctx context.Context, userID, documentID int64, locale models.Locale) (*models.DocumentExport, error)
+}
+
+// AuditorGrantService gives external auditors time-boxed, read-only access to selected
+// documents, or all documents of selected users, instead of full user accounts that are
+// forgotten after the audit. Every request made with a grant is logged, and a request whose
+// log entry cannot be written is refused, so the access log is complete.
+type AuditorGrantService struct {
+	grantRepo repository.AuditorGrantRepository
+	docRepo   repository.DocumentRepository
+	documents AuditedDocuments
+	screener  *PIIScreener
+	clock     clock.Clock
+}
+
+// NewAuditorGrantService creates a new AuditorGrantService.
+//
+// Parameters:
+//   - grantRepo: Repository storing the grants and their access log
+//   - docRepo: Repository looking up the owners of documents
+//   - documents: Reads and exports documents on behalf of their owner
+//
+// Returns:
+//   - A configured AuditorGrantService
+func NewAuditorGrantService(grantRepo repository.AuditorGrantRepository, docRepo repository.DocumentRepository, documents AuditedDocuments) *AuditorGrantService {
+	return &AuditorGrantService{
+		grantRepo: grantRepo,
+		docRepo:   docRepo,
+		documents: documents,
+	}
+}
+
+// SetClock sets the clock grants are started, revoked and expired by, so tests can check a
+// grant at the instant it expires.
+func (s *AuditorGrantService) SetClock(c clock.Clock) {
+	s.clock = c
+}
+
+// now returns the current time of the service's clock.
+func (s *AuditorGrantService) now() time.Time {
+	return clock.Or(s.clock).Now()
+}
+
+// SetPIIScreener enables screening of grant reasons for personal data, as grants are kept
+// with their access log after they expire.
+//
+// Parameters:
+//   - screener: The screener checking free-text fields
+func (s *AuditorGrantService) SetPIIScreener(screener *PIIScreener) {
+	s.screener = screener
+}
+
+// CreateGrant grants an auditor read-only access until the grant expires or is revoked.
+//
+// Parameters:
+//   - ctx: Context for the operation
+//   - adminID: The ID of the administrator granting the access
+//   - req: The auditor, the documents and users covered, the duration and the reason
+//
+// Returns:
+//   - The grant with its token, which is not shown again
+//   - ErrAuditorGrantEmpty if the grant covers neither documents nor users
+//   - ValidationError if the reason contains blocked personal data
+//   - Other errors if the grant could not be stored
+func (s *AuditorGrantService) CreateGrant(ctx context.Context, adminID int64, req *models.AuditorGrantRequest) (*models.AuditorGrantCreated, error) {
+	if len(req.DocumentIDs) == 0 && len(req.UserIDs) == 0 {
+		return nil, ErrAuditorGrantEmpty
+	}
+	if s.screener != nil {
+		if err := s.screener.Screen(ctx, adminID, map[string]string{"reason": req.Reason}); err != nil {
+			return nil, err
+		}
+	}
+
+	token, tokenHash, err := repository.GenerateToken()
+	if err != nil {
+		return nil, fmt.Errorf("failed to generate auditor token: %w", err)
+	}
+
+	grant := &models.AuditorGrant{
+		AuditorName:  req.AuditorName,
+		AuditorEmail: req.AuditorEmail,
+		TokenHash:    tokenHash,
+		DocumentIDs:  nonNilIDs(req.DocumentIDs),
+		UserIDs:      nonNilIDs(req.UserIDs),
+		Reason:       req.Reason,
+		CreatedBy:    adminID,
+		ExpiresAt:    s.now().AddDate(0, 0, req.ExpiresInDays),
+	}
+	if err := s.grantRepo.Create(ctx, grant); err != nil {
+		return nil, err
+	}
+
+	log.Info().
+		Int64("admin_id", adminID).
+		Int64("grant_id", grant.ID).
+		Int("documents", len(grant.DocumentIDs)).
+		Int("users", len(grant.UserIDs)).
+		Time("expires_at", grant.ExpiresAt).
+		Msg("Auditor access granted")
+
+	return &models.AuditorGrantCreated{AuditorGrant: grant, Token: token}, nil
+}
+
+// nonNilIDs returns ids, or an empty list instead of nil.
+func nonNilIDs(ids []int64) []int64 {
+	if ids == nil {
+		return []int64{}
+	}
+	return ids
+}
+
+// ListGrants retrieves the most recent grants, including expired and revoked ones.
+func (s *AuditorGrantService) ListGrants(ctx context.Context) ([]*models.AuditorGrant, error) {
+	return s.grantRepo.List(ctx, constants.AuditorGrantListLimit)
+}
+
+// RevokeGrant ends a grant immediately; requests made with its token are refused from then on.
+//
+// Parameters:
+//   - ctx: Context for the operation
+//   - adminID: The ID of the administrator revoking the grant
+//   - grantID: The ID of the grant
+//
+// Returns:
+//   - ErrAuditorGrantNotFound if the grant doesn't exist
+//   - Other errors if the grant could not be revoked
+func (s *AuditorGrantService) RevokeGrant(ctx context.Context, adminID, grantID int64) error {
+	if err := s.grantRepo.Revoke(ctx, grantID, s.now()); err != nil {
+		if errors.Is(err, utils.ErrNotFound) {
+			return ErrAuditorGrantNotFound
+		}
+		return err
+	}
+
+	log.Info().Int64("admin_id", adminID).Int64("grant_id", grantID).Msg("Auditor access revoked")
+	return nil
+}
+
+// ListAccess retrieves the most recent requests made with a grant, including refused ones.
+func (s *AuditorGrantService) ListAccess(ctx context.Context, grantID int64) ([]*models.AuditorAccess, error) {
+	return s.grantRepo.ListAccess(ctx, grantID, constants.AuditorAccessLogLimit)
+}
+
+// Authenticate resolves an auditor token to its grant.
+//
+// Parameters:
+//   - ctx: Context for the operation
+//   - token: The token sent by the auditor
+//
+// Returns:
+//   - The active grant of the token
+//   - ErrAuditorGrantInvalid if the token is unknown, or its grant expired or was revoked
+//   - Other errors if the grant could not be read
+func (s *AuditorGrantService) Authenticate(ctx context.Context, token string) (*models.AuditorGrant, error) {
+	hash := sha256.Sum256([]byte(token))
+	grant, err := s.grantRepo.GetByTokenHash(ctx, hex.EncodeToString(hash[:]))
+	if err != nil {
+		if errors.Is(err, utils.ErrNotFound) {
+			return nil, ErrAuditorGrantInvalid
+		}
+		return nil, err
+	}
+	if !grant.Active(s.now()) {
+		return nil, ErrAuditorGrantInvalid
+	}
+	return grant, nil
+}
+
+// ListDocuments retrieves the documents covered by a grant in ascending ID order, decrypted.
+// Documents that were deleted since the grant was created are left out.
+//
+// Parameters:
+//   - ctx: Context for the operation
+//   - grant: The grant of the auditor
+//   - ipAddress: The address the request came from, for the access log
+//
+// Returns:
+//   - The covered documents
+//   - An error if the request could not be logged or the documents could not be read
+func (s *AuditorGrantService) ListDocuments(ctx context.Context, grant *models.AuditorGrant, ipAddress string) ([]*models.Document, error) {
+	if err := s.logAccess(ctx, grant, constants.AuditorActionListDocuments, nil, true, ipAddress); err != nil {
+		return nil, err
+	}
+
+	docs := []*models.Document{}
+	seen := make(map[int64]bool)
+	for _, userID := range grant.UserIDs {
+		var afterID int64
+		for {
+			batch, err := s.documents.ListDocumentsAfter(ctx, userID, afterID, constants.AuditorDocumentBatchSize)
+			if err != nil {
+				return nil, err
+			}
+			for _, doc := range batch {
+				if !seen[doc.ID] {
+					seen[doc.ID] = true
+					docs = append(docs, doc)
+				}
+				afterID = doc.ID
+			}
+			if len(batch) < constants.AuditorDocumentBatchSize {
+				break
+			}
+		}
+	}
+	for _, documentID := range grant.DocumentIDs {
+		if seen[documentID] {
+			continue
+		}
+		doc, err := s.coveredDocument(ctx, grant, documentID)
+		if errors.Is(err, ErrDocumentNotFound) {
+			continue
+		}
+		if err != nil {
+			return nil, err
+		}
+		seen[documentID] = true
+		docs = append(docs, doc)
+	}
+
+	sort.Slice(docs, func(i, j int) bool { return docs[i].ID < docs[j].ID })
+	return docs, nil
+}
+
+// GetDocument retrieves a document covered by a grant with its redaction schema.
+//
+// Parameters:
+//   - ctx: Context for the operation
+//   - grant: The grant of the auditor
+//   - documentID: The ID of the document
+//   - ipAddress: The address the request came from, for the access log
+//
+// Returns:
+//   - The decrypted document
+//   - ErrDocumentNotFound if the document doesn't exist or is not covered by the grant
+//   - Other errors if the request could not be logged or the document could not be read
+func (s *AuditorGrantService) GetDocument(ctx context.Context, grant *models.AuditorGrant, documentID int64, ipAddress string) (*models.Document, error) {
+	doc, err := s.coveredDocument(ctx, grant, documentID)
+	if logErr := s.logAccess(ctx, grant, constants.AuditorActionViewDocument, &documentID, err == nil, ipAddress); logErr != nil {
+		return nil, logErr
+	}
+	return doc, err
+}
+
+// ExportDocument assembles the full record of a document covered by a grant, watermarked
+// with the auditor and the grant so leaked copies can be traced back.
+//
+// Parameters:
+//   - ctx: Context for the operation
+//   - grant: The grant of the auditor
+//   - documentID: The ID of the document
+//   - ipAddress: The address the request came from, for the access log
+//
+// Returns:
+//   - The watermarked export
+//   - ErrDocumentNotFound if the document doesn't exist or is not covered by the grant
+//   - Other errors if the request could not be logged or the document could not be exported
+func (s *AuditorGrantService) ExportDocument(ctx context.Context, grant *models.AuditorGrant, documentID int64, ipAddress string) (*models.DocumentExport, error) {
+	doc, err := s.coveredDocument(ctx, grant, documentID)
+	if logErr := s.logAccess(ctx, grant, constants.AuditorActionExportDocument, &documentID, err == nil, ipAddress); logErr != nil {
+		return nil, logErr
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	export, err := s.documents.ExportDocument(ctx, doc.UserID, documentID, models.LocaleISO)
+	if err != nil {
+		return nil, err
+	}
+	export.Watermark = grant.Watermark(export.ExportedAt)
+	return export, nil
+}
+
+// coveredDocument reads a document on behalf of its owner if the grant covers it.
+// Documents that are not covered are reported as not found so their existence is not revealed.
+func (s *AuditorGrantService) coveredDocument(ctx context.Context, grant *models.AuditorGrant, documentID int64) (*models.Document, error) {
+	stored, err := s.docRepo.GetByID(ctx, documentID)
+	if err != nil {
+		if errors.Is(err, utils.ErrNotFound) {
+			return nil, ErrDocumentNotFound
+		}
+		return nil, err
+	}
+	if !grant.Covers(stored) {
+		return nil, ErrDocumentNotFound
+	}
+	return s.documents.GetDocumentByID(ctx, stored.UserID, documentID)
+}
+
+// logAccess records a request made with a grant.
+func (s *AuditorGrantService) logAccess(ctx context.Context, grant *models.AuditorGrant, action string, documentID *int64, allowed bool, ipAddress string) error {
+	access := &models.AuditorAccess{
+		GrantID:    grant.ID,
+		Action:     action,
+		DocumentID: documentID,
+		Allowed:    allowed,
+		IPAddress:  ipAddress,
+	}
+	if err := s.grantRepo.LogAccess(ctx, access); err != nil {
+		return fmt.Errorf("failed to log auditor access: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,242 @@
+package service
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/yasinhessnawi1/Hideme_Backend/internal/constants"
+	"github.com/yasinhessnawi1/Hideme_Backend/internal/models"
+	"github.com/yasinhessnawi1/Hideme_Backend/internal/testsupport/fakeclock"
+	"github.com/yasinhessnawi1/Hideme_Backend/internal/testsupport/fixtures"
+	"github.com/yasinhessnawi1/Hideme_Backend/internal/utils"
+)
+
+// memoryAuditorGrantRepository keeps grants and their access log in memory, failing LogAccess while err is set
+type memoryAuditorGrantRepository struct {
+	grants   []*models.AuditorGrant
+	accesses []*models.AuditorAccess
+	err      error
+}
+
+func (m *memoryAuditorGrantRepository) Create(ctx context.Context, grant *models.AuditorGrant) error {
+	grant.ID = int64(len(m.grants) + 1)
+	grant.CreatedAt = time.Now()
+	m.grants = append(m.grants, grant)
+	return nil
+}
+
+func (m *memoryAuditorGrantRepository) GetByTokenHash(ctx context.Context, tokenHash string) (*models.AuditorGrant, error) {
+	for _, grant := range m.grants {
+		if grant.TokenHash == tokenHash {
+			return grant, nil
+		}
+	}
+	return nil, utils.NewNotFoundError("AuditorGrant", "token")
+}
+
+func (m *memoryAuditorGrantRepository) List(ctx context.Context, limit int) ([]*models.AuditorGrant, error) {
+	return m.grants, nil
+}
+
+func (m *memoryAuditorGrantRepository) Revoke(ctx context.Context, id int64, revokedAt time.Time) error {
+	for _, grant := range m.grants {
+		if grant.ID == id {
+			grant.RevokedAt = &revokedAt
+			return nil
+		}
+	}
+	return utils.NewNotFoundError("AuditorGrant", id)
+}
+
+func (m *memoryAuditorGrantRepository) LogAccess(ctx context.Context, access *models.AuditorAccess) error {
+	if m.err != nil {
+		return m.err
+	}
+	access.ID = int64(len(m.accesses) + 1)
+	m.accesses = append(m.accesses, access)
+	return nil
+}
+
+func (m *memoryAuditorGrantRepository) ListAccess(ctx context.Context, grantID int64, limit int) ([]*models.AuditorAccess, error) {
+	var accesses []*models.AuditorAccess
+	for _, access := range m.accesses {
+		if access.GrantID == grantID {
+			accesses = append(accesses, access)
+		}
+	}
+	return accesses, nil
+}
+
+// ownerDocuments reads documents from memory, checking that they are read on behalf of their owner
+type ownerDocuments struct {
+	docs *memoryDocumentRepository
+}
+
+func (o *ownerDocuments) GetDocumentByID(ctx context.Context, userID, id int64) (*models.Document, error) {
+	doc, ok := o.docs.docs[id]
+	if !ok || doc.UserID != userID {
+		return nil, ErrDocumentNotFound
+	}
+	copied := *doc
+	return &copied, nil
+}
+
+func (o *ownerDocuments) ListDocumentsAfter(ctx context.Context, userID, afterID int64, limit int) ([]*models.Document, error) {
+	var docs []*models.Document
+	for _, doc := range o.docs.docs {
+		if doc.UserID == userID && doc.ID > afterID {
+			copied := *doc
+			docs = append(docs, &copied)
+		}
+	}
+	return docs, nil
+}
+
+func (o *ownerDocuments) ExportDocument(ctx context.Context, userID, documentID int64, locale models.Locale) (*models.DocumentExport, error) {
+	doc, err := o.GetDocumentByID(ctx, userID, documentID)
+	if err != nil {
+		return nil, err
+	}
+	return models.NewDocumentExport(doc, models.RedactionMapping{}, nil, nil, time.Now()), nil
+}
+
+func TestAuditorGrantService(t *testing.T) {
+	ctx := context.Background()
+	docs := &memoryDocumentRepository{docs: map[int64]*models.Document{
+		1: {ID: 1, UserID: 10, HashedDocumentName: "contract.pdf"},
+		2: {ID: 2, UserID: 20, HashedDocumentName: "invoice.pdf"},
+		3: {ID: 3, UserID: 20, HashedDocumentName: "payroll.pdf"},
+		4: {ID: 4, UserID: 30, HashedDocumentName: "private.pdf"},
+	}}
+	grants := &memoryAuditorGrantRepository{}
+	svc := NewAuditorGrantService(grants, docs, &ownerDocuments{docs: docs})
+	clock := fakeclock.New(fixtures.Time)
+	svc.SetClock(clock)
+
+	t.Run("Grants must cover something", func(t *testing.T) {
+		_, err := svc.CreateGrant(ctx, 1, &models.AuditorGrantRequest{AuditorName: "Kari Nordmann", AuditorEmail: "kari@audit.example", ExpiresInDays: 30, Reason: "Annual audit"})
+		if !errors.Is(err, ErrAuditorGrantEmpty) {
+			t.Errorf("CreateGrant() error = %v, want ErrAuditorGrantEmpty", err)
+		}
+	})
+
+	created, err := svc.CreateGrant(ctx, 1, &models.AuditorGrantRequest{
+		AuditorName:   "Kari Nordmann",
+		AuditorEmail:  "kari@audit.example",
+		DocumentIDs:   []int64{1, 404},
+		UserIDs:       []int64{20},
+		ExpiresInDays: 30,
+		Reason:        "Annual audit",
+	})
+	if err != nil {
+		t.Fatalf("CreateGrant() error = %v", err)
+	}
+
+	t.Run("Only the token hash is stored", func(t *testing.T) {
+		hash := sha256.Sum256([]byte(created.Token))
+		if created.Token == "" || grants.grants[0].TokenHash != hex.EncodeToString(hash[:]) {
+			t.Errorf("stored token hash %q, want the SHA-256 of the returned token", grants.grants[0].TokenHash)
+		}
+	})
+
+	grant, err := svc.Authenticate(ctx, created.Token)
+	if err != nil {
+		t.Fatalf("Authenticate() error = %v", err)
+	}
+
+	t.Run("Covered documents", func(t *testing.T) {
+		listed, err := svc.ListDocuments(ctx, grant, "192.0.2.10")
+		if err != nil {
+			t.Fatalf("ListDocuments() error = %v", err)
+		}
+		if len(listed) != 3 || listed[0].ID != 1 || listed[1].ID != 2 || listed[2].ID != 3 {
+			t.Errorf("ListDocuments() = %v, want the granted document and those of the granted user", listed)
+		}
+		if doc, err := svc.GetDocument(ctx, grant, 3, "192.0.2.10"); err != nil || doc.HashedDocumentName != "payroll.pdf" {
+			t.Errorf("GetDocument() = %v, %v, want the document of the granted user", doc, err)
+		}
+	})
+
+	t.Run("Other documents are not found", func(t *testing.T) {
+		if _, err := svc.GetDocument(ctx, grant, 4, "192.0.2.10"); !errors.Is(err, ErrDocumentNotFound) {
+			t.Errorf("GetDocument() error = %v, want ErrDocumentNotFound", err)
+		}
+		if _, err := svc.ExportDocument(ctx, grant, 4, "192.0.2.10"); !errors.Is(err, ErrDocumentNotFound) {
+			t.Errorf("ExportDocument() error = %v, want ErrDocumentNotFound", err)
+		}
+	})
+
+	t.Run("Exports are watermarked", func(t *testing.T) {
+		export, err := svc.ExportDocument(ctx, grant, 1, "192.0.2.10")
+		if err != nil {
+			t.Fatalf("ExportDocument() error = %v", err)
+		}
+		if export.Watermark != grant.Watermark(export.ExportedAt) {
+			t.Errorf("Watermark = %q, want the auditor and grant", export.Watermark)
+		}
+	})
+
+	t.Run("Every request is logged", func(t *testing.T) {
+		accesses, err := svc.ListAccess(ctx, grant.ID)
+		if err != nil {
+			t.Fatalf("ListAccess() error = %v", err)
+		}
+		var refused int
+		for _, access := range accesses {
+			if !access.Allowed {
+				refused++
+			}
+			if access.IPAddress != "192.0.2.10" {
+				t.Errorf("logged address %q, want the address of the request", access.IPAddress)
+			}
+		}
+		if len(accesses) != 5 || refused != 2 {
+			t.Errorf("logged %d requests with %d refused, want 5 with 2 refused", len(accesses), refused)
+		}
+		if accesses[len(accesses)-1].Action != constants.AuditorActionExportDocument {
+			t.Errorf("last action = %q, want the export", accesses[len(accesses)-1].Action)
+		}
+	})
+
+	t.Run("Requests that cannot be logged are refused", func(t *testing.T) {
+		grants.err = errors.New("connection reset")
+		defer func() { grants.err = nil }()
+
+		if _, err := svc.GetDocument(ctx, grant, 1, "192.0.2.10"); err == nil {
+			t.Error("GetDocument() succeeded, want the log error")
+		}
+	})
+
+	t.Run("Revoked and expired grants are refused", func(t *testing.T) {
+		if err := svc.RevokeGrant(ctx, 1, 404); !errors.Is(err, ErrAuditorGrantNotFound) {
+			t.Errorf("RevokeGrant() error = %v, want ErrAuditorGrantNotFound", err)
+		}
+		if err := svc.RevokeGrant(ctx, 1, grant.ID); err != nil {
+			t.Fatalf("RevokeGrant() error = %v", err)
+		}
+		if _, err := svc.Authenticate(ctx, created.Token); !errors.Is(err, ErrAuditorGrantInvalid) {
+			t.Errorf("Authenticate() error = %v, want ErrAuditorGrantInvalid for a revoked grant", err)
+		}
+
+		// The grant is valid until the instant it expires
+		grants.grants[0].RevokedAt = nil
+		if !grants.grants[0].ExpiresAt.Equal(fixtures.Time.AddDate(0, 0, 30)) {
+			t.Errorf("ExpiresAt = %v, want 30 days after the grant", grants.grants[0].ExpiresAt)
+		}
+		clock.Set(grants.grants[0].ExpiresAt.Add(-time.Nanosecond))
+		if _, err := svc.Authenticate(ctx, created.Token); err != nil {
+			t.Errorf("Authenticate() error = %v, want the grant just before it expires", err)
+		}
+		clock.Set(grants.grants[0].ExpiresAt)
+		if _, err := svc.Authenticate(ctx, created.Token); !errors.Is(err, ErrAuditorGrantInvalid) {
+			t.Errorf("Authenticate() error = %v, want ErrAuditorGrantInvalid for an expired grant", err)
+		}
+		if _, err := svc.Authenticate(ctx, "unknown"); !errors.Is(err, ErrAuditorGrantInvalid) {
+			t.Errorf("Authenticate() error = %v, want ErrAuditorGrantInvalid for an unknown token", err)
+		}
+	})
+}
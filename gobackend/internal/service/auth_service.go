@@ -9,17 +9,25 @@ package service
 
 import (
 	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
 	"fmt"
+	"net/http"
+	"strings"
 	"time"
+	"unicode"
 
 	"github.com/rs/zerolog/log"
 
 	"github.com/yasinhessnawi1/Hideme_Backend/internal/auth"
+	"github.com/yasinhessnawi1/Hideme_Backend/internal/auth/oauth"
 	"github.com/yasinhessnawi1/Hideme_Backend/internal/config"
 	"github.com/yasinhessnawi1/Hideme_Backend/internal/constants"
 	"github.com/yasinhessnawi1/Hideme_Backend/internal/models"
 	"github.com/yasinhessnawi1/Hideme_Backend/internal/repository"
 	"github.com/yasinhessnawi1/Hideme_Backend/internal/utils"
+	"github.com/yasinhessnawi1/Hideme_Backend/internal/utils/clock"
 )
 
 // AuthService handles authentication operations for the application.
@@ -33,6 +41,44 @@ type AuthService struct {
 	jwtService  *auth.JWTService
 	passwordCfg *auth.PasswordConfig
 	apiKeyCfg   *config.APIKeySettings
+	riskService *RiskService
+	screener    *PIIScreener
+
+	// rotationNotifier tells owners that a rotated API key is still in use
+	rotationNotifier APIKeyRotationNotifier
+
+	// webhooks receive the apikey.created events
+	webhooks WebhookPublisher
+
+	// policySource provides the expiry policy administrators set for all API keys
+	policySource APIKeyPolicySource
+
+	// identityRepo links users to the accounts they sign in with at OpenID Connect providers
+	identityRepo repository.UserIdentityRepository
+
+	// registrationDomains limits new accounts to the organization's email domains
+	registrationDomains RegistrationEmailChecker
+
+	// clock tells the time sessions and API keys are checked against; the system clock if nil
+	clock clock.Clock
+}
+
+// RegistrationEmailChecker checks whether a new account may be created with an email address.
+type RegistrationEmailChecker interface {
+	// CheckEmail returns a ValidationError if the address is outside the allowed domains.
+	CheckEmail(ctx context.Context, email string) error
+}
+
+// APIKeyPolicySource provides the API key expiry policy.
+type APIKeyPolicySource interface {
+	// GetPolicy returns the policy; a policy without limits if none was set.
+	GetPolicy(ctx context.Context) (*models.APIKeyPolicy, error)
+}
+
+// APIKeyRotationNotifier notifies the owner of an API key that was used after it was rotated.
+type APIKeyRotationNotifier interface {
+	// SendRotatedKeyUsedEmail tells the owner which key is still in use and when it stops working.
+	SendRotatedKeyUsedEmail(toEmail, toName, keyName string, expiresAt time.Time) error
 }
 
 // NewAuthService creates a new AuthService with the specified dependencies.
@@ -68,6 +114,100 @@ func NewAuthService(
 	}
 }
 
+// SetClock sets the clock sessions and API keys are started, expired and cleaned up by,
+// so tests can check them at the instant they expire. The JWT service has its own clock.
+func (s *AuthService) SetClock(c clock.Clock) {
+	s.clock = c
+}
+
+// now returns the current time of the service's clock.
+func (s *AuthService) now() time.Time {
+	return clock.Or(s.clock).Now()
+}
+
+// SetRiskService enables risk scoring of registrations and logins.
+// Without a risk service, no risk checks are performed.
+//
+// Parameters:
+//   - riskService: The service scoring authentication requests
+func (s *AuthService) SetRiskService(riskService *RiskService) {
+	s.riskService = riskService
+}
+
+// SetRegistrationDomains enables limiting new accounts, registered or created on first
+// sign-in with a provider, to the organization's email domains.
+// Without a checker, accounts can be created with any email address.
+//
+// Parameters:
+//   - checker: The checker of the email addresses of new accounts
+func (s *AuthService) SetRegistrationDomains(checker RegistrationEmailChecker) {
+	s.registrationDomains = checker
+}
+
+// SetPIIScreener enables screening of API key names for personal data.
+// Without a screener, names are stored as entered.
+//
+// Parameters:
+//   - screener: The screener checking free-text fields
+func (s *AuthService) SetPIIScreener(screener *PIIScreener) {
+	s.screener = screener
+}
+
+// SetAPIKeyRotationNotifier enables notifying owners when a rotated API key is still used.
+// Without a notifier, such uses are only logged and recorded on the key.
+//
+// Parameters:
+//   - notifier: The notifier sending the warnings
+func (s *AuthService) SetAPIKeyRotationNotifier(notifier APIKeyRotationNotifier) {
+	s.rotationNotifier = notifier
+}
+
+// SetWebhooks enables sending apikey.created events to the user's webhooks, so owners
+// learn of keys they didn't create.
+//
+// Parameters:
+//   - webhooks: The publisher queuing the events
+func (s *AuthService) SetWebhooks(webhooks WebhookPublisher) {
+	s.webhooks = webhooks
+}
+
+// SetAPIKeyPolicySource enables enforcing the API key expiry policy on new keys.
+// Without a source, keys are issued for any of the offered durations.
+//
+// Parameters:
+//   - source: The source of the policy
+func (s *AuthService) SetAPIKeyPolicySource(source APIKeyPolicySource) {
+	s.policySource = source
+}
+
+// SetIdentityRepository enables signing in with OpenID Connect providers, whose accounts
+// are linked to users through the repository.
+//
+// Parameters:
+//   - identityRepo: Repository linking users to their provider accounts
+func (s *AuthService) SetIdentityRepository(identityRepo repository.UserIdentityRepository) {
+	s.identityRepo = identityRepo
+}
+
+// maxAPIKeyLifetime returns the longest lifetime the expiry policy allows for a key.
+//
+// Parameters:
+//   - ctx: Context for the operation
+//
+// Returns:
+//   - The maximum lifetime; 0 for no limit
+//   - An error if the policy could not be read
+func (s *AuthService) maxAPIKeyLifetime(ctx context.Context) (time.Duration, error) {
+	if s.policySource == nil {
+		return 0, nil
+	}
+	policy, err := s.policySource.GetPolicy(ctx)
+	if err != nil {
+		return 0, fmt.Errorf("failed to get API key policy: %w", err)
+	}
+	return policy.MaxLifetime(), nil
+}
+
 // RegisterUser creates a new user account with provided registration information.
 //
 // Parameters:
@@ -82,15 +222,25 @@ func NewAuthService(
 //
 // The method performs several validation steps:
 // 1. Verifies password and confirmation match
-// 2. Checks for existing users with the same username or email
-// 3. Securely hashes the password with a unique salt
-// 4. Creates and stores the new user record
+// 2. Checks that the email address is at one of the allowed registration domains, if any
+// 3. Checks for existing users with the same username or email
+// 4. Scores the registration for fraud risk, enforcing a CAPTCHA if required
+// 5. Securely hashes the password with a unique salt
+// 6. Creates and stores the new user record, holding it back if the risk is high
 func (s *AuthService) RegisterUser(ctx context.Context, reg *models.UserRegistration) (*models.User, error) {
 	// Validate password match
 	if reg.Password != reg.ConfirmPassword {
 		return nil, utils.NewValidationError("confirm_password", constants.MsgPasswordsDoNotMatch)
 	}
 
+	// Only addresses at the organization's domains may sign up
+	if s.registrationDomains != nil {
+		if err := s.registrationDomains.CheckEmail(ctx, reg.Email); err != nil {
+			utils.LogAuth(constants.LogEventRegister, "0", reg.Username, false, "email domain not allowed")
+			return nil, err
+		}
+	}
+
 	// Check if username already exists
 	existsUsername, err := s.userRepo.ExistsByUsername(ctx, reg.Username)
 	if err != nil {
@@ -109,6 +259,16 @@ func (s *AuthService) RegisterUser(ctx context.Context, reg *models.UserRegistra
 		return nil, utils.NewDuplicateError("User", "email", reg.Email)
 	}
 
+	// Score the registration for fraud risk
+	var assessment *models.RiskAssessment
+	if s.riskService != nil {
+		assessment, err = s.riskService.CheckRegistration(ctx, reg)
+		if err != nil {
+			utils.LogAuth(constants.LogEventRegister, "0", reg.Username, false, "risk challenge failed")
+			return nil, err
+		}
+	}
+
 	// Hash the password
 	passwordHash, salt, err := auth.HashPassword(reg.Password, s.passwordCfg)
 	if err != nil {
@@ -125,6 +285,13 @@ func (s *AuthService) RegisterUser(ctx context.Context, reg *models.UserRegistra
 		return nil, fmt.Errorf("failed to create user: %w", err)
 	}
 
+	// Hold back risky accounts until they are verified or reviewed
+	if assessment != nil {
+		if err := s.riskService.PlaceHold(ctx, user, assessment); err != nil {
+			return nil, err
+		}
+	}
+
 	// Log successful registration (using existing utility function that now integrates with GDPR)
 	utils.LogAuth(constants.LogEventRegister, fmt.Sprintf("%d", user.ID), user.Username, true, "")
 
@@ -148,9 +315,10 @@ func (s *AuthService) RegisterUser(ctx context.Context, reg *models.UserRegistra
 // The method performs the following operations:
 // 1. Locates the user by username or email
 // 2. Verifies the provided password against the stored hash
-// 3. Generates access and refresh tokens
-// 4. Creates a session record for the refresh token
-// 5. Logs the successful authentication
+// 3. Refuses disabled and held accounts and challenges risky logins
+// 4. Generates access and refresh tokens
+// 5. Creates a session record for the refresh token
+// 6. Logs the successful authentication
 func (s *AuthService) AuthenticateUser(ctx context.Context, creds *models.UserCredentials) (*models.User, string, string, error) {
 	var user *models.User
 	var err error
@@ -183,28 +351,230 @@ func (s *AuthService) AuthenticateUser(ctx context.Context, creds *models.UserCr
 		return nil, "", "", utils.NewInvalidCredentialsError()
 	}
 
-	// Generate JWT tokens
+	// Refuse accounts disabled by an administrator
+	if user.IsDisabled() {
+		utils.LogAuth(constants.LogEventLogin, fmt.Sprintf("%d", user.ID), user.Username, false, "account disabled")
+		return nil, "", "", utils.NewForbiddenError(constants.MsgAccountDisabled)
+	}
+
+	// Refuse held accounts and challenge risky logins
+	if s.riskService != nil {
+		if err := s.riskService.CheckLogin(ctx, user, creds); err != nil {
+			utils.LogAuth(constants.LogEventLogin, fmt.Sprintf("%d", user.ID), user.Username, false, "risk challenge failed")
+			return nil, "", "", err
+		}
+	}
+
+	// Generate JWT tokens and the session
+	accessToken, refreshToken, err := s.issueTokens(ctx, user)
+	if err != nil {
+		return nil, "", "", err
+	}
+
+	utils.LogAuth(constants.LogEventLogin, fmt.Sprintf("%d", user.ID), user.Username, true, "")
+
+	return user.Sanitize(), accessToken, refreshToken, nil
+}
+
+// issueTokens generates the access and refresh tokens of a user who signed in and
+// creates the session of the refresh token.
+func (s *AuthService) issueTokens(ctx context.Context, user *models.User) (string, string, error) {
 	accessToken, _, err := s.jwtService.GenerateAccessToken(user.ID, user.Username, user.Email, user.Role)
 	if err != nil {
-		return nil, "", "", fmt.Errorf("failed to generate access token: %w", err)
+		return "", "", fmt.Errorf("failed to generate access token: %w", err)
 	}
 
 	refreshToken, refreshJWTID, err := s.jwtService.GenerateRefreshToken(user.ID, user.Username, user.Email, user.Role)
 	if err != nil {
-		return nil, "", "", fmt.Errorf("failed to generate refresh token: %w", err)
+		return "", "", fmt.Errorf("failed to generate refresh token: %w", err)
 	}
 
 	// Create a session for the refresh token
-	session := models.NewSession(user.ID, refreshJWTID, s.jwtService.Config.RefreshExpiry)
+	session := models.NewSessionAt(user.ID, refreshJWTID, s.jwtService.Config.RefreshExpiry, s.now())
 	if err := s.sessionRepo.Create(ctx, session); err != nil {
-		return nil, "", "", fmt.Errorf("failed to create session: %w", err)
+		return "", "", fmt.Errorf("failed to create session: %w", err)
 	}
 
-	utils.LogAuth(constants.LogEventLogin, fmt.Sprintf("%d", user.ID), user.Username, true, "")
+	return accessToken, refreshToken, nil
+}
+
+// AuthenticateIdentity signs in a user vouched for by an OpenID Connect provider and
+// returns authentication tokens.
+//
+// Parameters:
+//   - ctx: Context for the operation
+//   - identity: The identity verified from the provider's ID token
+//   - creds: The client details used for risk scoring
+//
+// Returns:
+//   - The authenticated user (sanitized)
+//   - Access token for API authorization
+//   - Refresh token for obtaining new access tokens
+//   - ConflictError if an account has the email address but the provider did not verify it
+//   - ForbiddenError if no verified email address was returned for a new account, or the account is disabled
+//   - Other errors for database or token generation issues
+//
+// The method performs the following operations:
+// 1. Finds the user the provider account is linked to
+// 2. Otherwise links the provider account to the user with its verified email address,
+// or creates a user for it
+// 3. Refuses disabled and held accounts and challenges risky logins
+// 4. Generates access and refresh tokens and creates a session
+//
+// Accounts are only linked by verified email addresses, so an account at a provider that
+// lets users claim any address cannot take over the HideMe account with that address.
+func (s *AuthService) AuthenticateIdentity(ctx context.Context, identity *oauth.Identity, creds *models.UserCredentials) (*models.User, string, string, error) {
+	if s.identityRepo == nil {
+		return nil, "", "", fmt.Errorf("identity repository not configured")
+	}
+
+	user, err := s.userForIdentity(ctx, identity)
+	if err != nil {
+		return nil, "", "", err
+	}
+
+	// Refuse accounts disabled by an administrator
+	if user.IsDisabled() {
+		utils.LogAuth(constants.LogEventLogin, fmt.Sprintf("%d", user.ID), user.Username, false, "account disabled")
+		return nil, "", "", utils.NewForbiddenError(constants.MsgAccountDisabled)
+	}
+
+	// Refuse held accounts and challenge risky logins
+	if s.riskService != nil {
+		if err := s.riskService.CheckLogin(ctx, user, creds); err != nil {
+			utils.LogAuth(constants.LogEventLogin, fmt.Sprintf("%d", user.ID), user.Username, false, "risk challenge failed")
+			return nil, "", "", err
+		}
+	}
+
+	// Generate JWT tokens and the session
+	accessToken, refreshToken, err := s.issueTokens(ctx, user)
+	if err != nil {
+		return nil, "", "", err
+	}
+
+	utils.LogAuth(constants.LogEventLogin, fmt.Sprintf("%d", user.ID), user.Username, true, "via "+identity.Provider)
 
 	return user.Sanitize(), accessToken, refreshToken, nil
 }
 
+// userForIdentity returns the user a provider account signs in to, linking the account
+// to the user with its email address, or to a new user, on its first sign-in.
+func (s *AuthService) userForIdentity(ctx context.Context, identity *oauth.Identity) (*models.User, error) {
+	linked, err := s.identityRepo.GetByProviderSubject(ctx, identity.Provider, identity.Subject)
+	if err == nil {
+		user, err := s.userRepo.GetByID(ctx, linked.UserID)
+		if err != nil {
+			return nil, fmt.Errorf("failed to get user: %w", err)
+		}
+		return user, nil
+	}
+	if !utils.IsNotFoundError(err) {
+		return nil, fmt.Errorf("failed to get identity: %w", err)
+	}
+
+	var user *models.User
+	if identity.Email != "" {
+		user, err = s.userRepo.GetByEmail(ctx, identity.Email)
+		if err != nil && !utils.IsNotFoundError(err) {
+			return nil, fmt.Errorf("failed to get user: %w", err)
+		}
+	}
+
+	if !identity.EmailVerified {
+		if user != nil {
+			utils.LogAuth(constants.LogEventLogin, fmt.Sprintf("%d", user.ID), user.Username, false, "unverified email from "+identity.Provider)
+			return nil, utils.New(utils.ErrDuplicate, http.StatusConflict, constants.MsgOAuthEmailUnverified)
+		}
+		return nil, utils.NewForbiddenError(constants.MsgOAuthEmailMissing)
+	}
+
+	if user == nil {
+		if s.registrationDomains != nil {
+			if err := s.registrationDomains.CheckEmail(ctx, identity.Email); err != nil {
+				utils.LogAuth(constants.LogEventRegister, "0", "", false, "email domain not allowed via "+identity.Provider)
+				return nil, err
+			}
+		}
+		if user, err = s.createIdentityUser(ctx, identity); err != nil {
+			return nil, err
+		}
+	}
+
+	if err := s.identityRepo.Create(ctx, models.NewUserIdentity(user.ID, identity.Provider, identity.Subject, identity.Email)); err != nil {
+		return nil, err
+	}
+
+	log.Info().
+		Int64("user_id", user.ID).
+		Str("provider", identity.Provider).
+		Msg("Sign-in provider account linked")
+
+	return user, nil
+}
+
+// createIdentityUser creates the user of a provider account that signs in for the first time.
+// The username is taken from the email address, with a random suffix if it is taken, and the
+// password is random, so the user can only sign in with the provider until they reset it.
+func (s *AuthService) createIdentityUser(ctx context.Context, identity *oauth.Identity) (*models.User, error) {
+	password := make([]byte, 32)
+	if _, err := rand.Read(password); err != nil {
+		return nil, fmt.Errorf("failed to generate password: %w", err)
+	}
+	passwordHash, salt, err := auth.HashPassword(hex.EncodeToString(password), s.passwordCfg)
+	if err != nil {
+		return nil, fmt.Errorf("failed to hash password: %w", err)
+	}
+
+	base := usernameFromEmail(identity.Email)
+	username := base
+	for attempt := 0; ; attempt++ {
+		user := models.NewUser(username, identity.Email, constants.RoleUser)
+		user.PasswordHash = passwordHash
+		user.Salt = salt
+
+		err := s.userRepo.Create(ctx, user)
+		if err == nil {
+			utils.LogAuth(constants.LogEventRegister, fmt.Sprintf("%d", user.ID), user.Username, true, "via "+identity.Provider)
+			return user, nil
+		}
+
+		var appErr *utils.AppError
+		if attempt == constants.OAuthUsernameAttempts-1 || !errors.As(err, &appErr) || appErr.Field != "username" {
+			return nil, fmt.Errorf("failed to create user: %w", err)
+		}
+
+		suffix := make([]byte, 3)
+		if _, err := rand.Read(suffix); err != nil {
+			return nil, fmt.Errorf("failed to generate username: %w", err)
+		}
+		username = base + "-" + hex.EncodeToString(suffix)
+	}
+}
+
+// usernameFromEmail derives a username from the local part of an email address,
+// keeping letters, digits, dots, hyphens and underscores.
+func usernameFromEmail(email string) string {
+	local, _, _ := strings.Cut(email, "@")
+
+	var b strings.Builder
+	for _, r := range local {
+		if r < unicode.MaxASCII && (unicode.IsLetter(r) || unicode.IsDigit(r) || r == '.' || r == '-' || r == '_') {
+			b.WriteRune(r)
+		}
+	}
+
+	// Leave room for the suffix added when the username is taken
+	username := b.String()
+	if len(username) > constants.OAuthUsernameMaxBase {
+		username = username[:constants.OAuthUsernameMaxBase]
+	}
+	if len(username) < 3 {
+		username = "user" + username
+	}
+	return username
+}
+
 // RefreshTokens validates a refresh token and generates new tokens.
 //
 // Parameters:
@@ -219,7 +589,7 @@ func (s *AuthService) AuthenticateUser(ctx context.Context, creds *models.UserCr
 //
 // The method performs the following operations:
 // 1. Extracts the JWT ID from the refresh token
-// 2. Verifies the token exists in the active sessions
+// 2. Verifies the token exists in the active sessions and has not gone idle
 // 3. Validates the token's signature and claims
 // 4. Retrieves the associated user
 // 5. Deletes the old session
@@ -233,15 +603,29 @@ func (s *AuthService) RefreshTokens(ctx context.Context, refreshToken string) (s
 	}
 
 	// Check if the token is in the active sessions
-	isValid, err := s.sessionRepo.IsValidSession(ctx, jwtID)
+	session, err := s.sessionRepo.GetByJWTID(ctx, jwtID)
 	if err != nil {
+		if utils.IsNotFoundError(err) {
+			return "", "", utils.NewInvalidTokenError()
+		}
 		return "", "", fmt.Errorf("failed to check session validity: %w", err)
 	}
 
-	if !isValid {
+	now := s.now()
+	if session.IsExpiredAt(now) {
 		return "", "", utils.NewInvalidTokenError()
 	}
 
+	// Sessions unused for longer than the idle timeout can't be refreshed, even before they expire
+	if session.IsIdle(now, s.jwtService.Config.IdleTimeout) {
+		_ = s.sessionRepo.DeleteByJWTID(ctx, jwtID)
+		log.Info().
+			Int64("user_id", session.UserID).
+			Time("last_activity_at", session.LastActivityAt).
+			Msg("Idle session invalidated on refresh")
+		return "", "", utils.New(utils.ErrExpiredToken, constants.StatusUnauthorized, constants.MsgSessionIdle)
+	}
+
 	// Validate the refresh token
 	claims, err := s.jwtService.ValidateToken(refreshToken, constants.TokenTypeRefresh)
 	if err != nil {
@@ -255,6 +639,10 @@ func (s *AuthService) RefreshTokens(ctx context.Context, refreshToken string) (s
 	if err != nil {
 		return "", "", fmt.Errorf("failed to get user: %w", err)
 	}
+	if user.IsDisabled() {
+		_ = s.sessionRepo.DeleteByJWTID(ctx, jwtID)
+		return "", "", utils.NewForbiddenError(constants.MsgAccountDisabled)
+	}
 
 	// Delete the old session
 	if err := s.sessionRepo.DeleteByJWTID(ctx, jwtID); err != nil {
@@ -275,8 +663,8 @@ func (s *AuthService) RefreshTokens(ctx context.Context, refreshToken string) (s
 		return "", "", fmt.Errorf("failed to generate refresh token: %w", err)
 	}
 
-	// Create a new session for the refresh token
-	session := models.NewSession(user.ID, refreshJWTID, s.jwtService.Config.RefreshExpiry)
+	// Create a new session for the refresh token; its idle window starts now
+	session = models.NewSessionAt(user.ID, refreshJWTID, s.jwtService.Config.RefreshExpiry, s.now())
 	if err := s.sessionRepo.Create(ctx, session); err != nil {
 		return "", "", fmt.Errorf("failed to create session: %w", err)
 	}
@@ -350,10 +738,13 @@ func (s *AuthService) LogoutAll(ctx context.Context, userID int64) error {
 //   - userID: The ID of the user who will own the API key
 //   - name: A human-readable name/description for the API key
 //   - duration: How long the API key should remain valid
+//   - scopes: The constants.APIKeyScope values the key is limited to; empty for full access
 //
 // Returns:
 //   - The raw API key (only returned once at creation time)
 //   - The API key metadata (without sensitive information)
+//   - ValidationError if the name contains personal data and screening blocks it,
+//     or if the duration exceeds the maximum lifetime of the expiry policy
 //   - An error if key generation or storage fails
 //
 // The method performs the following operations:
@@ -361,13 +752,31 @@ func (s *AuthService) LogoutAll(ctx context.Context, userID int64) error {
 // 2. Creates a database record with the key's hash (not the key itself)
 // 3. Logs the key creation event
 // 4. Returns the raw key and metadata to the caller
-func (s *AuthService) CreateAPIKey(ctx context.Context, userID int64, name string, duration time.Duration) (string, *models.APIKey, error) {
+func (s *AuthService) CreateAPIKey(ctx context.Context, userID int64, name string, duration time.Duration, scopes []string) (string, *models.APIKey, error) {
+	// Key names are stored as entered, so check them for personal data first
+	if s.screener != nil {
+		if err := s.screener.Screen(ctx, userID, map[string]string{"name": name}); err != nil {
+			return "", nil, err
+		}
+	}
+
+	// Keys may not outlive the expiry policy
+	maxLifetime, err := s.maxAPIKeyLifetime(ctx)
+	if err != nil {
+		return "", nil, err
+	}
+	if maxLifetime > 0 && duration > maxLifetime {
+		return "", nil, utils.NewValidationError("duration",
+			fmt.Sprintf("API keys may be valid for at most %d days", int(maxLifetime.Hours()/24)))
+	}
+
 	// Generate a new API key
 	apiKeyService := auth.NewAPIKeyService(s.apiKeyCfg)
 	apiKey, rawKey, err := apiKeyService.GenerateAPIKey(userID, name, duration)
 	if err != nil {
 		return "", nil, fmt.Errorf("failed to generate API key: %w", err)
 	}
+	apiKey.Scopes = scopes
 
 	// Save the API key to the database
 	if err := s.apiKeyRepo.Create(ctx, apiKey); err != nil {
@@ -381,10 +790,13 @@ func (s *AuthService) CreateAPIKey(ctx context.Context, userID int64, name strin
 		Name:      apiKey.Name,
 		ExpiresAt: apiKey.ExpiresAt,
 		CreatedAt: apiKey.CreatedAt,
+		Scopes:    apiKey.Scopes,
 	}
 
 	utils.LogAPIKey(constants.LogEventAPIKey, apiKey.ID, fmt.Sprintf("%d", userID))
 
+	s.publishAPIKeyCreated(ctx, response)
+
 	return rawKey, response, nil
 }
 
@@ -455,12 +867,19 @@ func (s *AuthService) DeleteAPIKey(ctx context.Context, userID int64, keyID stri
 }
 
 func (s *AuthService) VerifyAPIKey(ctx context.Context, apiKeyString string) (*models.User, error) {
+	user, _, err := s.VerifyAPIKeyScopes(ctx, apiKeyString)
+	return user, err
+}
+
+// VerifyAPIKeyScopes returns the owner of an active API key and the scopes the key is
+// limited to; keys without scopes have full access.
+func (s *AuthService) VerifyAPIKeyScopes(ctx context.Context, apiKeyString string) (*models.User, []string, error) {
 	// No need to parse the API key - just use the entire string for validation
 
 	// Get all API keys
 	apiKeys, err := s.apiKeyRepo.GetAll(ctx)
 	if err != nil {
-		return nil, fmt.Errorf("failed to get API keys: %w", err)
+		return nil, nil, fmt.Errorf("failed to get API keys: %w", err)
 	}
 
 	// Get the encryption key from the config
@@ -470,40 +889,177 @@ func (s *AuthService) VerifyAPIKey(ctx context.Context, apiKeyString string) (*m
 	}
 
 	// Check each API key
+	now := s.now()
 	for _, apiKey := range apiKeys {
-		// Skip expired keys
-		if time.Now().After(apiKey.ExpiresAt) {
+		// Skip expired and disabled keys, and keys past the deadline of their rotation campaign
+		if !apiKey.IsUsable(now) {
 			continue
 		}
 
-		// Check if the stored key is encrypted
+		var matched bool
 		if auth.IsEncrypted(apiKey.APIKeyHash) {
 			// Try to decrypt
 			decryptedKey, err := utils.DecryptKey(apiKey.APIKeyHash, encryptionKey)
-			if err == nil && decryptedKey == apiKeyString {
-				// Found a match
-				user, err := s.userRepo.GetByID(ctx, apiKey.UserID)
-				if err != nil {
-					return nil, fmt.Errorf("failed to get user for API key: %w", err)
-				}
-				utils.LogAPIKey("verified", apiKey.ID, fmt.Sprintf("%d", user.ID))
-				return user.Sanitize(), nil
-			}
+			matched = err == nil && decryptedKey == apiKeyString
 		} else {
 			// Fall back to hash comparison
-			hashedKey := auth.HashAPIKey(apiKeyString, nil) // nil forces hash mode
-			if hashedKey == apiKey.APIKeyHash {
-				user, err := s.userRepo.GetByID(ctx, apiKey.UserID)
-				if err != nil {
-					return nil, fmt.Errorf("failed to get user for API key: %w", err)
-				}
-				utils.LogAPIKey("verified", apiKey.ID, fmt.Sprintf("%d", user.ID))
-				return user.Sanitize(), nil
-			}
+			matched = auth.HashAPIKey(apiKeyString, nil) == apiKey.APIKeyHash // nil forces hash mode
 		}
+		if !matched {
+			continue
+		}
+
+		// Found a match
+		user, err := s.userRepo.GetByID(ctx, apiKey.UserID)
+		if err != nil {
+			return nil, nil, fmt.Errorf("failed to get user for API key: %w", err)
+		}
+		if user.IsDisabled() {
+			return nil, nil, utils.NewForbiddenError(constants.MsgAccountDisabled)
+		}
+		if apiKey.IsRotated() {
+			s.reportRotatedKeyUse(ctx, apiKey, user)
+		}
+		utils.LogAPIKey("verified", apiKey.ID, fmt.Sprintf("%d", user.ID))
+		return user.Sanitize(), apiKey.Scopes, nil
+	}
+
+	return nil, nil, utils.NewInvalidTokenError()
+}
+
+// reportRotatedKeyUse warns about a request authenticated with an API key that has been
+// replaced. The first such use is recorded on the key and its owner is notified, so that
+// integrations still using the old key are switched before the overlap window ends.
+func (s *AuthService) reportRotatedKeyUse(ctx context.Context, apiKey *models.APIKey, user *models.User) {
+	log.Warn().
+		Str(constants.ParamKeyID, apiKey.ID).
+		Int64("user_id", user.ID).
+		Time("expires_at", apiKey.ExpiresAt).
+		Str("category", constants.LogCategoryAuth).
+		Msg("Rotated API key used after cutover")
+
+	first, err := s.apiKeyRepo.MarkUsedAfterRotation(ctx, apiKey.ID, s.now())
+	if err != nil {
+		log.Error().Err(err).Str(constants.ParamKeyID, apiKey.ID).Msg("Failed to record use of rotated API key")
+		return
+	}
+	if !first || s.rotationNotifier == nil {
+		return
+	}
+
+	if err := s.rotationNotifier.SendRotatedKeyUsedEmail(user.Email, user.Username, apiKey.Name, apiKey.ExpiresAt); err != nil {
+		log.Error().Err(err).Str(constants.ParamKeyID, apiKey.ID).Msg("Failed to notify owner of rotated API key use")
+	}
+}
+
+// RotateAPIKey issues a replacement for an API key. The old key keeps working for the
+// configured overlap window, so integrations can switch keys without downtime.
+//
+// Parameters:
+//   - ctx: Context for the operation
+//   - userID: The ID of the user who owns the API key
+//   - keyID: The ID of the API key to rotate
+//
+// Returns:
+//   - The raw replacement key (only returned once)
+//   - The replacement key metadata (without sensitive information)
+//   - The old key metadata with its shortened expiry
+//   - ForbiddenError if the API key doesn't belong to the user
+//   - NotFoundError if the API key doesn't exist
+//   - ForbiddenError if an administrator disabled the API key
+//   - ExpiredTokenError if the API key has expired or is past its rotation deadline
+//   - BadRequestError if the API key was already rotated
+//   - Other errors for key generation or database issues
+//
+// The replacement has the same name, scopes and lifetime as the old key, shortened to the maximum
+// lifetime of the expiry policy, and records the old key as its predecessor. The old key expires at the end of the overlap window, or
+// at its original expiry if that is sooner.
+func (s *AuthService) RotateAPIKey(ctx context.Context, userID int64, keyID string) (string, *models.APIKey, *models.APIKey, error) {
+	// Get the API key to verify ownership
+	oldKey, err := s.apiKeyRepo.GetByID(ctx, keyID)
+	if err != nil {
+		return "", nil, nil, err
+	}
+	if oldKey.UserID != userID {
+		return "", nil, nil, utils.NewForbiddenError(constants.MsgAccessDenied)
+	}
+	if oldKey.IsDisabled() {
+		return "", nil, nil, utils.NewForbiddenError(constants.MsgAPIKeyDisabled)
+	}
+	now := s.now()
+	if oldKey.IsExpiredAt(now) || oldKey.IsPastRotationDeadline(now) {
+		return "", nil, nil, utils.NewExpiredTokenError()
+	}
+	if oldKey.IsRotated() {
+		return "", nil, nil, utils.NewBadRequestError(constants.MsgAPIKeyAlreadyRotated)
+	}
+
+	// Generate the replacement with the lifetime the old key was issued with, within the policy
+	lifetime := oldKey.ExpiresAt.Sub(oldKey.CreatedAt)
+	maxLifetime, err := s.maxAPIKeyLifetime(ctx)
+	if err != nil {
+		return "", nil, nil, err
 	}
+	if maxLifetime > 0 && lifetime > maxLifetime {
+		lifetime = maxLifetime
+	}
+	apiKeyService := auth.NewAPIKeyService(s.apiKeyCfg)
+	replacement, rawKey, err := apiKeyService.GenerateAPIKey(userID, oldKey.Name, lifetime)
+	if err != nil {
+		return "", nil, nil, fmt.Errorf("failed to generate API key: %w", err)
+	}
+	replacement.RotatedFrom = &oldKey.ID
+	replacement.Scopes = oldKey.Scopes
+
+	// Keep the old key valid for the overlap window, but never longer than it was issued for
+	overlap := constants.DefaultAPIKeyRotationOverlap
+	if s.apiKeyCfg != nil && s.apiKeyCfg.RotationOverlap > 0 {
+		overlap = s.apiKeyCfg.RotationOverlap
+	}
+	rotatedAt := now
+	oldExpiresAt := rotatedAt.Add(overlap)
+	if oldKey.ExpiresAt.Before(oldExpiresAt) {
+		oldExpiresAt = oldKey.ExpiresAt
+	}
+
+	if err := s.apiKeyRepo.Rotate(ctx, oldKey.ID, rotatedAt, oldExpiresAt, replacement); err != nil {
+		return "", nil, nil, err
+	}
+
+	// Create responses that don't include the hashes
+	response := &models.APIKey{
+		ID:          replacement.ID,
+		UserID:      replacement.UserID,
+		Name:        replacement.Name,
+		ExpiresAt:   replacement.ExpiresAt,
+		CreatedAt:   replacement.CreatedAt,
+		RotatedFrom: replacement.RotatedFrom,
+		Scopes:      replacement.Scopes,
+	}
+	previous := *oldKey
+	previous.APIKeyHash = ""
+	previous.RotatedAt = &rotatedAt
+	previous.ExpiresAt = oldExpiresAt
+
+	utils.LogAPIKey("rotated", replacement.ID, fmt.Sprintf("%d", userID))
+
+	s.publishAPIKeyCreated(ctx, response)
+
+	return rawKey, response, &previous, nil
+}
 
-	return nil, utils.NewInvalidTokenError()
+// publishAPIKeyCreated sends an apikey.created event to the owner's webhooks, if enabled.
+func (s *AuthService) publishAPIKeyCreated(ctx context.Context, key *models.APIKey) {
+	if s.webhooks == nil {
+		return
+	}
+	s.webhooks.Publish(ctx, key.UserID, constants.WebhookEventAPIKeyCreated, &models.APIKeyCreatedEvent{
+		KeyID:       key.ID,
+		Name:        key.Name,
+		Scopes:      key.Scopes,
+		RotatedFrom: key.RotatedFrom,
+		ExpiresAt:   key.ExpiresAt,
+	})
 }
 
 // GetDecryptedAPIKey retrieves an API key by its ID and decrypts it if encrypted.
@@ -517,7 +1073,7 @@ func (s *AuthService) VerifyAPIKey(ctx context.Context, apiKeyString string) (*m
 //
 // Returns:
 //   - The API key model with the decrypted original key
-//   - ForbiddenError if the key doesn't belong to the user
+//   - ForbiddenError if the key doesn't belong to the user or an administrator disabled it
 //   - NotFoundError if the key doesn't exist
 //   - Other errors for database or decryption issues
 func (s *AuthService) GetDecryptedAPIKey(ctx context.Context, userID int64, keyID string) (*models.APIKey, string, error) {
@@ -531,9 +1087,12 @@ func (s *AuthService) GetDecryptedAPIKey(ctx context.Context, userID int64, keyI
 	if apiKey.UserID != userID {
 		return nil, "", utils.NewForbiddenError(constants.MsgAccessDenied)
 	}
+	if apiKey.IsDisabled() {
+		return nil, "", utils.NewForbiddenError(constants.MsgAPIKeyDisabled)
+	}
 
 	// Check if the API key has expired
-	if apiKey.IsExpired() {
+	if apiKey.IsExpiredAt(s.now()) {
 		return nil, "", utils.NewExpiredTokenError()
 	}
 
@@ -564,12 +1123,36 @@ func (s *AuthService) GetDecryptedAPIKey(ctx context.Context, userID int64, keyI
 //
 // Parameters:
 //   - ctx: Context for the operation
+//   - batchSize: The maximum number of sessions to delete; 0 for no limit
+//   - dryRun: Whether to count the sessions that would be deleted instead of deleting them
 //
 // Returns:
-//   - The number of expired sessions deleted
+//   - The number of expired sessions deleted, or that would be deleted in a dry run
 //   - An error if deletion fails
-func (s *AuthService) CleanupExpiredSessions(ctx context.Context) (int64, error) {
-	return s.sessionRepo.DeleteExpired(ctx)
+func (s *AuthService) CleanupExpiredSessions(ctx context.Context, batchSize int, dryRun bool) (int64, error) {
+	if dryRun {
+		count, err := s.sessionRepo.CountExpired(ctx)
+		return batchCount(count, batchSize), err
+	}
+	return s.sessionRepo.DeleteExpired(ctx, batchSize)
+}
+
+// CleanupIdleSessions removes sessions unused for longer than the idle timeout.
+// Refreshing an idle session already fails; this removes those the user never comes back to.
+// This is typically called periodically as a maintenance task.
+//
+// Parameters:
+//   - ctx: Context for the operation
+//
+// Returns:
+//   - The number of idle sessions deleted; 0 if no idle timeout is configured
+//   - An error if deletion fails
+func (s *AuthService) CleanupIdleSessions(ctx context.Context) (int64, error) {
+	idleTimeout := s.jwtService.Config.IdleTimeout
+	if idleTimeout <= 0 {
+		return 0, nil
+	}
+	return s.sessionRepo.DeleteIdle(ctx, s.now().Add(-idleTimeout))
 }
 
 // CleanupExpiredAPIKeys removes expired API keys from the database.
@@ -577,10 +1160,16 @@ func (s *AuthService) CleanupExpiredSessions(ctx context.Context) (int64, error)
 //
 // Parameters:
 //   - ctx: Context for the operation
+//   - batchSize: The maximum number of keys to delete; 0 for no limit
+//   - dryRun: Whether to count the keys that would be deleted instead of deleting them
 //
 // Returns:
-//   - The number of expired API keys deleted
+//   - The number of expired API keys deleted, or that would be deleted in a dry run
 //   - An error if deletion fails
-func (s *AuthService) CleanupExpiredAPIKeys(ctx context.Context) (int64, error) {
-	return s.apiKeyRepo.DeleteExpired(ctx)
+func (s *AuthService) CleanupExpiredAPIKeys(ctx context.Context, batchSize int, dryRun bool) (int64, error) {
+	if dryRun {
+		count, err := s.apiKeyRepo.CountExpired(ctx)
+		return batchCount(count, batchSize), err
+	}
+	return s.apiKeyRepo.DeleteExpired(ctx, batchSize)
 }
@@ -3,13 +3,17 @@ package service
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"testing"
 	"time"
 
 	"github.com/yasinhessnawi1/Hideme_Backend/internal/auth"
 	"github.com/yasinhessnawi1/Hideme_Backend/internal/config"
+	"github.com/yasinhessnawi1/Hideme_Backend/internal/constants"
 	"github.com/yasinhessnawi1/Hideme_Backend/internal/models"
+	"github.com/yasinhessnawi1/Hideme_Backend/internal/testsupport/fakeclock"
+	"github.com/yasinhessnawi1/Hideme_Backend/internal/testsupport/fixtures"
 	"github.com/yasinhessnawi1/Hideme_Backend/internal/utils"
 )
 
@@ -112,6 +116,41 @@ func (m *MockUserRepository) ExistsByEmail(ctx context.Context, email string) (b
 	return ok, nil
 }
 
+func (m *MockUserRepository) List(ctx context.Context, filter models.UserFilter, page, pageSize int) ([]*models.User, int, error) {
+	matched := []*models.User{}
+	for id := int64(1); id < m.nextID; id++ {
+		user, ok := m.users[id]
+		if !ok || (filter.Role != "" && user.Role != filter.Role) {
+			continue
+		}
+		if filter.Status != "" && (filter.Status == constants.UserStatusDisabled) != user.IsDisabled() {
+			continue
+		}
+		matched = append(matched, user)
+	}
+
+	start := (page - 1) * pageSize
+	if start > len(matched) {
+		start = len(matched)
+	}
+	end := start + pageSize
+	if end > len(matched) {
+		end = len(matched)
+	}
+	return matched[start:end], len(matched), nil
+}
+
+func (m *MockUserRepository) SetDisabled(ctx context.Context, id int64, disabledAt *time.Time) error {
+	user, ok := m.users[id]
+	if !ok {
+		return utils.NewNotFoundError("User", id)
+	}
+
+	user.DisabledAt = disabledAt
+
+	return nil
+}
+
 type MockSessionRepository struct {
 	sessions        map[string]*models.Session
 	sessionsByJWTID map[string]*models.Session
@@ -213,11 +252,24 @@ func (m *MockSessionRepository) DeleteByUserID(ctx context.Context, userID int64
 	return nil
 }
 
-func (m *MockSessionRepository) DeleteExpired(ctx context.Context) (int64, error) {
+func (m *MockSessionRepository) CountExpired(ctx context.Context) (int64, error) {
+	var count int64
+	for _, session := range m.sessions {
+		if session.ExpiresAt.Before(time.Now()) {
+			count++
+		}
+	}
+	return count, nil
+}
+
+func (m *MockSessionRepository) DeleteExpired(ctx context.Context, limit int) (int64, error) {
 	var count int64
 	now := time.Now()
 
 	for id, session := range m.sessions {
+		if limit > 0 && count >= int64(limit) {
+			break
+		}
 		if session.ExpiresAt.Before(now) {
 			delete(m.sessions, id)
 			delete(m.sessionsByJWTID, session.JWTID)
@@ -239,6 +291,18 @@ func (m *MockSessionRepository) DeleteExpired(ctx context.Context) (int64, error
 	return count, nil
 }
 
+func (m *MockSessionRepository) DeleteIdle(ctx context.Context, cutoff time.Time) (int64, error) {
+	var count int64
+	for id, session := range m.sessions {
+		if session.LastActivityAt.Before(cutoff) {
+			delete(m.sessions, id)
+			delete(m.sessionsByJWTID, session.JWTID)
+			count++
+		}
+	}
+	return count, nil
+}
+
 func (m *MockSessionRepository) IsValidSession(ctx context.Context, jwtID string) (bool, error) {
 	session, ok := m.sessionsByJWTID[jwtID]
 	if !ok {
@@ -341,11 +405,24 @@ func (m *MockAPIKeyRepository) DeleteByUserID(ctx context.Context, userID int64)
 	return nil
 }
 
-func (m *MockAPIKeyRepository) DeleteExpired(ctx context.Context) (int64, error) {
+func (m *MockAPIKeyRepository) CountExpired(ctx context.Context) (int64, error) {
+	var count int64
+	for _, apiKey := range m.apiKeys {
+		if apiKey.ExpiresAt.Before(time.Now()) {
+			count++
+		}
+	}
+	return count, nil
+}
+
+func (m *MockAPIKeyRepository) DeleteExpired(ctx context.Context, limit int) (int64, error) {
 	var count int64
 	now := time.Now()
 
 	for id, apiKey := range m.apiKeys {
+		if limit > 0 && count >= int64(limit) {
+			break
+		}
 		if apiKey.ExpiresAt.Before(now) {
 			delete(m.apiKeys, id)
 
@@ -366,6 +443,50 @@ func (m *MockAPIKeyRepository) DeleteExpired(ctx context.Context) (int64, error)
 	return count, nil
 }
 
+func (m *MockAPIKeyRepository) Rotate(ctx context.Context, oldKeyID string, rotatedAt, oldExpiresAt time.Time, replacement *models.APIKey) error {
+	oldKey, ok := m.apiKeys[oldKeyID]
+	if !ok {
+		return utils.NewNotFoundError("APIKey", oldKeyID)
+	}
+	if oldKey.IsRotated() {
+		return utils.NewBadRequestError(constants.MsgAPIKeyAlreadyRotated)
+	}
+
+	oldKey.RotatedAt = &rotatedAt
+	oldKey.ExpiresAt = oldExpiresAt
+
+	return m.Create(ctx, replacement)
+}
+
+func (m *MockAPIKeyRepository) MarkUsedAfterRotation(ctx context.Context, keyID string, usedAt time.Time) (bool, error) {
+	apiKey, ok := m.apiKeys[keyID]
+	if !ok || !apiKey.IsRotated() || apiKey.UsedAfterRotationAt != nil {
+		return false, nil
+	}
+
+	apiKey.UsedAfterRotationAt = &usedAt
+	return true, nil
+}
+
+// MockRotationNotifier records the rotated key warnings it was asked to send.
+type MockRotationNotifier struct {
+	sent []string
+}
+
+func (m *MockRotationNotifier) SendRotatedKeyUsedEmail(toEmail, toName, keyName string, expiresAt time.Time) error {
+	m.sent = append(m.sent, toEmail+":"+keyName)
+	return nil
+}
+
+// MockPolicySource provides a fixed API key expiry policy.
+type MockPolicySource struct {
+	policy models.APIKeyPolicy
+}
+
+func (m *MockPolicySource) GetPolicy(ctx context.Context) (*models.APIKeyPolicy, error) {
+	return &m.policy, nil
+}
+
 func TestNewAuthService(t *testing.T) {
 	userRepo := NewMockUserRepository()
 	sessionRepo := NewMockSessionRepository()
@@ -473,6 +594,98 @@ func TestAuthService_RegisterUser(t *testing.T) {
 	}
 }
 
+func TestAuthService_RegisterUser_RiskHold(t *testing.T) {
+	// Setup
+	userRepo := NewMockUserRepository()
+	passwordCfg := &auth.PasswordConfig{
+		Memory:      16 * 1024, // Use minimal settings for faster tests
+		Iterations:  1,
+		Parallelism: 1,
+		SaltLength:  16,
+		KeyLength:   32,
+	}
+	service := NewAuthService(userRepo, NewMockSessionRepository(), NewMockAPIKeyRepository(),
+		auth.NewJWTService(&config.JWTSettings{}), passwordCfg, &config.APIKeySettings{})
+
+	riskService, holdRepo, sender := setupRiskService()
+	service.SetRiskService(riskService)
+
+	// Registering with a disposable email address holds the new account
+	reg := &models.UserRegistration{
+		Username:        "trialuser",
+		Email:           "trial@mailinator.com",
+		Password:        "password123",
+		ConfirmPassword: "password123",
+	}
+
+	user, err := service.RegisterUser(context.Background(), reg)
+	if err != nil {
+		t.Fatalf("RegisterUser() error = %v", err)
+	}
+
+	if _, ok := holdRepo.holds[user.ID]; !ok {
+		t.Error("Expected the new account to be held")
+	}
+
+	if sender.tokens[reg.Email] == "" {
+		t.Error("Expected a verification email to be sent")
+	}
+
+	// The held account cannot sign in until the email address is verified
+	_, _, _, err = service.AuthenticateUser(context.Background(), &models.UserCredentials{
+		Username: reg.Username,
+		Password: reg.Password,
+	})
+	if !errors.Is(err, utils.ErrForbidden) {
+		t.Errorf("Expected a forbidden error for the held account, got %v", err)
+	}
+}
+
+func TestAuthService_RegisterUser_RegistrationDomains(t *testing.T) {
+	// Setup
+	userRepo := NewMockUserRepository()
+	passwordCfg := &auth.PasswordConfig{
+		Memory:      16 * 1024, // Use minimal settings for faster tests
+		Iterations:  1,
+		Parallelism: 1,
+		SaltLength:  16,
+		KeyLength:   32,
+	}
+	service := NewAuthService(userRepo, NewMockSessionRepository(), NewMockAPIKeyRepository(),
+		auth.NewJWTService(&config.JWTSettings{}), passwordCfg, &config.APIKeySettings{})
+
+	verifiedAt := time.Now()
+	domainRepo := &MockRegistrationDomainRepository{domains: []*models.RegistrationDomain{
+		{ID: 1, Domain: "example.com", VerifiedAt: &verifiedAt},
+	}}
+	service.SetRegistrationDomains(NewRegistrationDomainService(domainRepo))
+
+	// Personal addresses are refused
+	_, err := service.RegisterUser(context.Background(), &models.UserRegistration{
+		Username:        "ola",
+		Email:           "ola@gmail.com",
+		Password:        "password123",
+		ConfirmPassword: "password123",
+	})
+	if !errors.Is(err, utils.ErrValidation) {
+		t.Errorf("Expected a validation error for a personal address, got %v", err)
+	}
+	if len(userRepo.users) != 0 {
+		t.Error("Expected no account to be created")
+	}
+
+	// Addresses at the organization's domain are accepted
+	_, err = service.RegisterUser(context.Background(), &models.UserRegistration{
+		Username:        "ola",
+		Email:           "ola@example.com",
+		Password:        "password123",
+		ConfirmPassword: "password123",
+	})
+	if err != nil {
+		t.Errorf("RegisterUser() error = %v", err)
+	}
+}
+
 func TestAuthService_AuthenticateUser(t *testing.T) {
 	// Setup
 	userRepo := NewMockUserRepository()
@@ -595,6 +808,17 @@ func TestAuthService_AuthenticateUser(t *testing.T) {
 	if err == nil {
 		t.Error("Expected error for missing credentials")
 	}
+
+	// Test with an account disabled by an administrator
+	disabledAt := time.Now()
+	user.DisabledAt = &disabledAt
+	_, _, _, err = service.AuthenticateUser(context.Background(), &models.UserCredentials{Username: "testuser", Password: testPassword})
+
+	// Check that we get a forbidden error
+	var appErr *utils.AppError
+	if !errors.As(err, &appErr) || appErr.StatusCode != 403 {
+		t.Errorf("Expected forbidden for a disabled account, got %v", err)
+	}
 }
 
 func TestAuthService_RefreshTokens(t *testing.T) {
@@ -713,7 +937,7 @@ func TestAuthService_CreateAPIKey(t *testing.T) {
 	name := "Test API Key"
 	duration := 30 * 24 * time.Hour // 30 days
 
-	rawKey, apiKey, err := service.CreateAPIKey(context.Background(), user.ID, name, duration)
+	rawKey, apiKey, err := service.CreateAPIKey(context.Background(), user.ID, name, duration, nil)
 
 	// Check results
 	if err != nil {
@@ -1015,6 +1239,180 @@ func TestVerifyAPIKey(t *testing.T) {
 	}
 }
 
+func TestAuthService_RotateAPIKey(t *testing.T) {
+	// Setup
+	userRepo := NewMockUserRepository()
+	apiKeyRepo := NewMockAPIKeyRepository()
+	apiKeyCfg := &config.APIKeySettings{
+		EncryptionKey:   "secretencryptionkey12345678901234",
+		RotationOverlap: time.Hour,
+	}
+
+	service := NewAuthService(userRepo, NewMockSessionRepository(), apiKeyRepo,
+		auth.NewJWTService(&config.JWTSettings{}), auth.DefaultPasswordConfig(), apiKeyCfg)
+	notifier := &MockRotationNotifier{}
+	service.SetAPIKeyRotationNotifier(notifier)
+
+	user := &models.User{Username: "testuser", Email: "test@example.com"}
+	if err := userRepo.Create(context.Background(), user); err != nil {
+		t.Fatalf("Failed to create test user: %v", err)
+	}
+
+	// Create the key to rotate with a 30 day lifetime
+	oldRaw := "oldsecretuihiuhwiughiurhiuetrhgu"
+	createdAt := time.Now().Add(-24 * time.Hour)
+	oldKey := &models.APIKey{
+		ID:         "oldkey",
+		UserID:     user.ID,
+		APIKeyHash: auth.HashAPIKey(oldRaw, []byte(apiKeyCfg.EncryptionKey)),
+		Name:       "CI",
+		ExpiresAt:  createdAt.Add(30 * 24 * time.Hour),
+		CreatedAt:  createdAt,
+	}
+	if err := apiKeyRepo.Create(context.Background(), oldKey); err != nil {
+		t.Fatalf("Failed to create API key: %v", err)
+	}
+
+	// Rotate the key
+	rawKey, replacement, previous, err := service.RotateAPIKey(context.Background(), user.ID, oldKey.ID)
+	if err != nil {
+		t.Fatalf("RotateAPIKey() error = %v", err)
+	}
+
+	if rawKey == "" || replacement.APIKeyHash != "" || previous.APIKeyHash != "" {
+		t.Error("Expected the raw key once and no hashes in the returned keys")
+	}
+	if replacement.Name != "CI" || replacement.RotatedFrom == nil || *replacement.RotatedFrom != oldKey.ID {
+		t.Errorf("Expected replacement named CI rotated from %s, got %+v", oldKey.ID, replacement)
+	}
+	if lifetime := replacement.ExpiresAt.Sub(replacement.CreatedAt); lifetime < 29*24*time.Hour {
+		t.Errorf("Expected replacement to keep the 30 day lifetime, got %v", lifetime)
+	}
+	if until := time.Until(previous.ExpiresAt); until > time.Hour || until < 59*time.Minute {
+		t.Errorf("Expected old key to expire after the 1h overlap, expires in %v", until)
+	}
+
+	// Both keys work during the overlap
+	if _, err := service.VerifyAPIKey(context.Background(), rawKey); err != nil {
+		t.Errorf("Expected replacement key to verify, got %v", err)
+	}
+	for i := 0; i < 2; i++ {
+		if _, err := service.VerifyAPIKey(context.Background(), oldRaw); err != nil {
+			t.Errorf("Expected old key to verify during the overlap, got %v", err)
+		}
+	}
+
+	// Only the first use of the old key is reported
+	if len(notifier.sent) != 1 || notifier.sent[0] != "test@example.com:CI" {
+		t.Errorf("Expected one warning for the old key, got %v", notifier.sent)
+	}
+	if oldKey.UsedAfterRotationAt == nil {
+		t.Error("Expected use after rotation to be recorded")
+	}
+
+	// A rotated key cannot be rotated again
+	_, _, _, err = service.RotateAPIKey(context.Background(), user.ID, oldKey.ID)
+	var appErr *utils.AppError
+	if !errors.As(err, &appErr) || appErr.StatusCode != 400 {
+		t.Errorf("Expected bad request for rotating a rotated key, got %v", err)
+	}
+
+	// Someone else's key cannot be rotated
+	_, _, _, err = service.RotateAPIKey(context.Background(), user.ID+1, replacement.ID)
+	if !errors.As(err, &appErr) || appErr.StatusCode != 403 {
+		t.Errorf("Expected forbidden for rotating someone else's key, got %v", err)
+	}
+}
+
+func TestAuthService_APIKeyAdministration(t *testing.T) {
+	// Setup
+	userRepo := NewMockUserRepository()
+	apiKeyRepo := NewMockAPIKeyRepository()
+	apiKeyCfg := &config.APIKeySettings{
+		EncryptionKey:   "secretencryptionkey12345678901234",
+		RotationOverlap: time.Hour,
+	}
+
+	service := NewAuthService(userRepo, NewMockSessionRepository(), apiKeyRepo,
+		auth.NewJWTService(&config.JWTSettings{}), auth.DefaultPasswordConfig(), apiKeyCfg)
+	service.SetAPIKeyPolicySource(&MockPolicySource{policy: models.APIKeyPolicy{MaxLifetimeDays: 30}})
+
+	user := &models.User{Username: "testuser", Email: "test@example.com"}
+	if err := userRepo.Create(context.Background(), user); err != nil {
+		t.Fatalf("Failed to create test user: %v", err)
+	}
+
+	// Keys longer than the policy allows are rejected
+	_, _, err := service.CreateAPIKey(context.Background(), user.ID, "Too long", 90*24*time.Hour, nil)
+	var appErr *utils.AppError
+	if !errors.As(err, &appErr) || appErr.StatusCode != 400 {
+		t.Errorf("Expected bad request for a key longer than the policy allows, got %v", err)
+	}
+
+	// A disabled key is not verified and cannot be rotated
+	disabledRaw := "disabledsecretuihiuhwiughiurhiue"
+	disabledAt := time.Now()
+	disabled := &models.APIKey{
+		ID:         "disabledkey",
+		UserID:     user.ID,
+		APIKeyHash: auth.HashAPIKey(disabledRaw, []byte(apiKeyCfg.EncryptionKey)),
+		Name:       "Disabled",
+		ExpiresAt:  time.Now().Add(24 * time.Hour),
+		CreatedAt:  time.Now(),
+		DisabledAt: &disabledAt,
+	}
+	if err := apiKeyRepo.Create(context.Background(), disabled); err != nil {
+		t.Fatalf("Failed to create API key: %v", err)
+	}
+	if _, err := service.VerifyAPIKey(context.Background(), disabledRaw); err == nil {
+		t.Error("Expected a disabled key not to verify")
+	}
+	_, _, _, err = service.RotateAPIKey(context.Background(), user.ID, disabled.ID)
+	if !errors.As(err, &appErr) || appErr.StatusCode != 403 {
+		t.Errorf("Expected forbidden for rotating a disabled key, got %v", err)
+	}
+
+	// A key past its rotation deadline is not verified
+	lateRaw := "latesecretuihiuhwiughiurhiuetrhg"
+	deadline := time.Now().Add(-time.Minute)
+	late := &models.APIKey{
+		ID:               "latekey",
+		UserID:           user.ID,
+		APIKeyHash:       auth.HashAPIKey(lateRaw, []byte(apiKeyCfg.EncryptionKey)),
+		Name:             "Late",
+		ExpiresAt:        time.Now().Add(24 * time.Hour),
+		CreatedAt:        time.Now(),
+		RotationDeadline: &deadline,
+	}
+	if err := apiKeyRepo.Create(context.Background(), late); err != nil {
+		t.Fatalf("Failed to create API key: %v", err)
+	}
+	if _, err := service.VerifyAPIKey(context.Background(), lateRaw); err == nil {
+		t.Error("Expected a key past its rotation deadline not to verify")
+	}
+
+	// The replacement of a key older than the policy is capped to the limit
+	createdAt := time.Now().Add(-24 * time.Hour)
+	old := &models.APIKey{
+		ID:         "oldkey",
+		UserID:     user.ID,
+		APIKeyHash: auth.HashAPIKey("oldsecretuihiuhwiughiurhiuetrhgu", []byte(apiKeyCfg.EncryptionKey)),
+		Name:       "CI",
+		ExpiresAt:  createdAt.Add(365 * 24 * time.Hour),
+		CreatedAt:  createdAt,
+	}
+	if err := apiKeyRepo.Create(context.Background(), old); err != nil {
+		t.Fatalf("Failed to create API key: %v", err)
+	}
+	_, replacement, _, err := service.RotateAPIKey(context.Background(), user.ID, old.ID)
+	if err != nil {
+		t.Fatalf("RotateAPIKey() error = %v", err)
+	}
+	if lifetime := replacement.ExpiresAt.Sub(replacement.CreatedAt); lifetime > 30*24*time.Hour {
+		t.Errorf("Expected replacement lifetime capped to 30 days, got %v", lifetime)
+	}
+}
+
 func TestAuthService_CleanupExpiredSessions(t *testing.T) {
 	// Setup
 	userRepo := NewMockUserRepository()
@@ -1079,8 +1477,20 @@ func TestAuthService_CleanupExpiredSessions(t *testing.T) {
 		t.Fatalf("Failed to create expired session: %v", err)
 	}
 
+	// A dry run counts the sessions of one batch without deleting them
+	count, err := service.CleanupExpiredSessions(context.Background(), 1, true)
+	if err != nil {
+		t.Errorf("CleanupExpiredSessions() dry run error = %v", err)
+	}
+	if count != 1 {
+		t.Errorf("Expected a dry run with a batch of 1 to count 1 session, got %d", count)
+	}
+	if _, err := sessionRepo.GetByID(context.Background(), expiredSession1.ID); err != nil {
+		t.Error("Expired session 1 was deleted in a dry run")
+	}
+
 	// Cleanup expired sessions
-	count, err := service.CleanupExpiredSessions(context.Background())
+	count, err = service.CleanupExpiredSessions(context.Background(), 0, false)
 
 	// Check results
 	if err != nil {
@@ -1108,6 +1518,147 @@ func TestAuthService_CleanupExpiredSessions(t *testing.T) {
 	}
 }
 
+func TestAuthService_RefreshTokens_IdleTimeout(t *testing.T) {
+	// Setup
+	userRepo := NewMockUserRepository()
+	sessionRepo := NewMockSessionRepository()
+	jwtService := auth.NewJWTService(&config.JWTSettings{
+		Secret:        "test-secret-that-is-long-enough-for-hs256",
+		Expiry:        15 * time.Minute,
+		RefreshExpiry: 24 * time.Hour,
+		IdleTimeout:   30 * time.Minute,
+	})
+
+	service := NewAuthService(userRepo, sessionRepo, NewMockAPIKeyRepository(), jwtService, auth.DefaultPasswordConfig(), &config.APIKeySettings{})
+
+	user := &models.User{Username: "testuser", Email: "test@example.com"}
+	if err := userRepo.Create(context.Background(), user); err != nil {
+		t.Fatalf("Failed to create test user: %v", err)
+	}
+
+	newSession := func(lastActivity time.Time) string {
+		refreshToken, jwtID, err := jwtService.GenerateRefreshToken(user.ID, user.Username, user.Email, user.Role)
+		if err != nil {
+			t.Fatalf("Failed to generate refresh token: %v", err)
+		}
+		session := models.NewSession(user.ID, jwtID, jwtService.Config.RefreshExpiry)
+		session.ID = jwtID
+		session.LastActivityAt = lastActivity
+		if err := sessionRepo.Create(context.Background(), session); err != nil {
+			t.Fatalf("Failed to create session: %v", err)
+		}
+		return refreshToken
+	}
+
+	// A session used within the idle timeout is refreshed, and the new session starts a new window
+	_, newRefreshToken, err := service.RefreshTokens(context.Background(), newSession(time.Now().Add(-20*time.Minute)))
+	if err != nil {
+		t.Fatalf("RefreshTokens() error = %v", err)
+	}
+	newJWTID, err := jwtService.ParseTokenWithoutValidation(newRefreshToken)
+	if err != nil {
+		t.Fatalf("Failed to parse new refresh token: %v", err)
+	}
+	if refreshed, err := sessionRepo.GetByJWTID(context.Background(), newJWTID); err != nil || time.Since(refreshed.LastActivityAt) > time.Minute {
+		t.Errorf("Expected the new session to be active now, got %+v, %v", refreshed, err)
+	}
+
+	// A session unused for longer is invalidated although it hasn't expired
+	idleToken := newSession(time.Now().Add(-31 * time.Minute))
+	_, _, err = service.RefreshTokens(context.Background(), idleToken)
+	var appErr *utils.AppError
+	if !errors.As(err, &appErr) || appErr.StatusCode != 401 || appErr.Message != constants.MsgSessionIdle {
+		t.Errorf("Expected an idle session error, got %v", err)
+	}
+	if _, _, err := service.RefreshTokens(context.Background(), idleToken); err == nil {
+		t.Error("Expected the idle session to be deleted")
+	}
+}
+
+func TestAuthService_RefreshTokens_Expiry(t *testing.T) {
+	// Setup with a clock shared by the JWT and auth services
+	clock := fakeclock.New(fixtures.Time)
+	userRepo := NewMockUserRepository()
+	sessionRepo := NewMockSessionRepository()
+	jwtService := auth.NewJWTService(&config.JWTSettings{
+		Secret:        "test-secret-that-is-long-enough-for-hs256",
+		Expiry:        15 * time.Minute,
+		RefreshExpiry: 24 * time.Hour,
+	})
+	jwtService.SetClock(clock)
+
+	service := NewAuthService(userRepo, sessionRepo, NewMockAPIKeyRepository(), jwtService, auth.DefaultPasswordConfig(), &config.APIKeySettings{})
+	service.SetClock(clock)
+
+	user := &models.User{Username: "testuser", Email: "test@example.com"}
+	if err := userRepo.Create(context.Background(), user); err != nil {
+		t.Fatalf("Failed to create test user: %v", err)
+	}
+	refreshToken, jwtID, err := jwtService.GenerateRefreshToken(user.ID, user.Username, user.Email, user.Role)
+	if err != nil {
+		t.Fatalf("Failed to generate refresh token: %v", err)
+	}
+	session := models.NewSessionAt(user.ID, jwtID, jwtService.Config.RefreshExpiry, clock.Now())
+	session.ID = jwtID
+	if err := sessionRepo.Create(context.Background(), session); err != nil {
+		t.Fatalf("Failed to create session: %v", err)
+	}
+
+	// The session can be refreshed until the last second before it expires
+	clock.Advance(24*time.Hour - time.Second)
+	_, newRefreshToken, err := service.RefreshTokens(context.Background(), refreshToken)
+	if err != nil {
+		t.Fatalf("RefreshTokens() error = %v", err)
+	}
+
+	// The new session lasts a full refresh expiry from the refresh, and no longer
+	clock.Advance(24*time.Hour - time.Second)
+	_, lastRefreshToken, err := service.RefreshTokens(context.Background(), newRefreshToken)
+	if err != nil {
+		t.Fatalf("RefreshTokens() error = %v", err)
+	}
+
+	clock.Advance(24*time.Hour + time.Second)
+	_, _, err = service.RefreshTokens(context.Background(), lastRefreshToken)
+	var appErr *utils.AppError
+	if !errors.As(err, &appErr) || appErr.StatusCode != 401 {
+		t.Errorf("Expected an expired session to be rejected, got %v", err)
+	}
+}
+
+func TestAuthService_CleanupIdleSessions(t *testing.T) {
+	// Setup
+	sessionRepo := NewMockSessionRepository()
+	jwtSettings := &config.JWTSettings{}
+	service := NewAuthService(NewMockUserRepository(), sessionRepo, NewMockAPIKeyRepository(),
+		auth.NewJWTService(jwtSettings), auth.DefaultPasswordConfig(), &config.APIKeySettings{})
+
+	for id, lastActivity := range map[string]time.Time{
+		"active": time.Now().Add(-10 * time.Minute),
+		"idle":   time.Now().Add(-2 * time.Hour),
+	} {
+		session := &models.Session{ID: id, UserID: 1, JWTID: id + "-jwt", ExpiresAt: time.Now().Add(24 * time.Hour), LastActivityAt: lastActivity}
+		if err := sessionRepo.Create(context.Background(), session); err != nil {
+			t.Fatalf("Failed to create session: %v", err)
+		}
+	}
+
+	// Without an idle timeout nothing is deleted
+	count, err := service.CleanupIdleSessions(context.Background())
+	if err != nil || count != 0 {
+		t.Errorf("Expected no sessions deleted without an idle timeout, got %d, %v", count, err)
+	}
+
+	jwtSettings.IdleTimeout = 30 * time.Minute
+	count, err = service.CleanupIdleSessions(context.Background())
+	if err != nil || count != 1 {
+		t.Errorf("Expected one idle session deleted, got %d, %v", count, err)
+	}
+	if _, err := sessionRepo.GetByID(context.Background(), "active"); err != nil {
+		t.Error("Active session was unexpectedly deleted")
+	}
+}
+
 func TestAuthService_CleanupExpiredAPIKeys(t *testing.T) {
 	// Setup
 	userRepo := NewMockUserRepository()
@@ -1176,7 +1727,7 @@ func TestAuthService_CleanupExpiredAPIKeys(t *testing.T) {
 	}
 
 	// Cleanup expired API keys
-	count, err := service.CleanupExpiredAPIKeys(context.Background())
+	count, err := service.CleanupExpiredAPIKeys(context.Background(), 0, false)
 
 	// Check results
 	if err != nil {
@@ -0,0 +1,177 @@
+// Package service provides business logic implementations for the HideMe application.
+//
+// This file implements the matching of ban list words with the options set for each
+// word: ignoring case and diacritics, matching whole words only, and matching English or
+// Norwegian inflections of the word.
+package service
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+
+	"github.com/yasinhessnawi1/Hideme_Backend/internal/models"
+	"github.com/yasinhessnawi1/Hideme_Backend/internal/utils"
+)
+
+// banWordExpression returns a regular expression matching a ban list word with its
+// options. Words in the ban list word match any run of separators. Case is not folded
+// here, so the caller adds the (?i) flag for case-insensitive words, and whole words are
+// checked with atWordBoundaries, as RE2 has no word boundaries outside ASCII.
+//
+// Parameters:
+//   - word: The ban list word
+//   - options: How the word is matched
+//   - separators: The characters separating the words of the text matched
+//
+// Returns:
+//   - The expression, or an empty string if the word has no words
+func banWordExpression(word string, options models.BanListMatchOptions, separators *regexp.Regexp) string {
+	var suffixes string
+	if options.Stemming != models.StemmingNone {
+		endings := utils.StemSuffixes(string(options.Stemming))
+		quoted := make([]string, 0, len(endings))
+		for _, ending := range endings {
+			quoted = append(quoted, regexp.QuoteMeta(ending))
+		}
+		suffixes = fmt.Sprintf("(?:%s){0,%d}", strings.Join(quoted, "|"), utils.MaxStemSuffixes)
+	}
+
+	parts := separators.Split(strings.TrimSpace(word), -1)
+	expressions := make([]string, 0, len(parts))
+	for _, part := range parts {
+		if part == "" {
+			continue
+		}
+		if options.Stemming != models.StemmingNone {
+			part = utils.StemWord(part, string(options.Stemming))
+		}
+
+		var b strings.Builder
+		for _, r := range part {
+			if options.DiacriticInsensitive {
+				b.WriteString(diacriticClass(r))
+			} else {
+				b.WriteString(regexp.QuoteMeta(string(r)))
+			}
+		}
+		b.WriteString(suffixes)
+		expressions = append(expressions, b.String())
+	}
+
+	return strings.Join(expressions, separators.String())
+}
+
+// diacriticClass returns an expression matching a letter with or without diacritics,
+// in the letter's case: "[oòóôõöøōő]" for 'o' and 'ö' alike, and "(?:æ|ae)" for 'æ'.
+func diacriticClass(r rune) string {
+	lower := unicode.ToLower(r)
+	upper := lower != r
+	inCase := func(s string) string {
+		if upper {
+			return strings.ToUpper(s)
+		}
+		return s
+	}
+
+	base := string(lower)
+	if folded, ok := utils.FoldDiacritic(lower); ok {
+		base = folded
+	}
+	if utf8.RuneCountInString(base) > 1 {
+		return "(?:" + inCase(string(lower)) + "|" + inCase(base) + ")"
+	}
+
+	baseRune, _ := utf8.DecodeRuneInString(base)
+	variants := utils.DiacriticVariants(baseRune)
+	if len(variants) == 0 {
+		return regexp.QuoteMeta(string(r))
+	}
+	return "[" + inCase(base+string(variants)) + "]"
+}
+
+// atWordBoundaries reports whether text[start:end] is not part of a longer word, that
+// is, neither preceded nor followed by a letter or digit.
+func atWordBoundaries(text string, start, end int) bool {
+	if before, _ := utf8.DecodeLastRuneInString(text[:start]); start > 0 && isWordRune(before) {
+		return false
+	}
+	if after, _ := utf8.DecodeRuneInString(text[end:]); end < len(text) && isWordRune(after) {
+		return false
+	}
+	return true
+}
+
+// isWordRune reports whether a character is part of a word.
+func isWordRune(r rune) bool {
+	return unicode.IsLetter(r) || unicode.IsDigit(r)
+}
+
+// banWordKey returns the form of a text that is compared with a ban list word matched
+// with the same options: folded, lowercased and stemmed as the options ask.
+//
+// Parameters:
+//   - text: The text or ban list word
+//   - options: How the ban list word is matched
+//
+// Returns:
+//   - The text in its compared form
+func banWordKey(text string, options models.BanListMatchOptions) string {
+	text = strings.TrimSpace(text)
+	if options.DiacriticInsensitive {
+		text = utils.FoldDiacritics(text)
+	}
+	if options.CaseInsensitive {
+		text = strings.ToLower(text)
+	}
+	if options.Stemming != models.StemmingNone {
+		words := strings.Fields(text)
+		for i, word := range words {
+			words[i] = utils.StemWord(word, string(options.Stemming))
+		}
+		text = strings.Join(words, " ")
+	}
+	return text
+}
+
+// banListExemptions holds the words of a ban list in their compared form, grouped by the
+// options they are matched with.
+type banListExemptions map[models.BanListMatchOptions]map[string]bool
+
+// newBanListExemptions groups the words of a ban list by their options.
+//
+// Parameters:
+//   - banList: The ban list with its words and their options
+//   - foldCase: Whether every word is compared ignoring case, whatever its options
+//
+// Returns:
+//   - The exemptions
+func newBanListExemptions(banList *models.BanListWithWords, foldCase bool) banListExemptions {
+	exemptions := make(banListExemptions)
+	if banList == nil {
+		return exemptions
+	}
+	for _, word := range banList.Words {
+		options := banList.MatchOptions(word)
+		if foldCase {
+			options.CaseInsensitive = true
+		}
+		if exemptions[options] == nil {
+			exemptions[options] = make(map[string]bool)
+		}
+		exemptions[options][banWordKey(word, options)] = true
+	}
+	return exemptions
+}
+
+// contains reports whether a text is one of the ban list words, as matched with its options.
+func (e banListExemptions) contains(text string) bool {
+	for options, words := range e {
+		if words[banWordKey(text, options)] {
+			return true
+		}
+	}
+	return false
+}
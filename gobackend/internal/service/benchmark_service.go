@@ -0,0 +1,309 @@
+// Package service provides business logic implementations.
+package service
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/binary"
+	"fmt"
+	"math"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/rs/zerolog/log"
+
+	"github.com/yasinhessnawi1/Hideme_Backend/internal/config"
+	"github.com/yasinhessnawi1/Hideme_Backend/internal/constants"
+	"github.com/yasinhessnawi1/Hideme_Backend/internal/models"
+	"github.com/yasinhessnawi1/Hideme_Backend/internal/repository"
+	"github.com/yasinhessnawi1/Hideme_Backend/internal/utils"
+	"github.com/yasinhessnawi1/Hideme_Backend/internal/utils/clock"
+)
+
+// BenchmarkService publishes differentially private averages of the entities detected per page
+// by industry, computed over the tenants that opted in to benchmarking.
+//
+// For every industry the clipped sum of the tenants' entities per page and the number of tenants
+// are each perturbed with Laplace noise using half of the privacy budget, so a published average
+// reveals little about whether any single tenant contributed or what its value was. Industries
+// whose noisy tenant count is below the minimum are not published at all.
+//
+// The report is computed by a maintenance task and kept until the next run, so repeated requests
+// see the same noise and cannot average it away.
+type BenchmarkService struct {
+	benchmarkRepo repository.BenchmarkRepository
+	policy        *config.BenchmarkSettings
+	report        *models.BenchmarkReport
+	reportMutex   sync.RWMutex
+	noise         func(scale float64) (float64, error)
+	clock         clock.Clock
+}
+
+// NewBenchmarkService creates a new BenchmarkService.
+//
+// Parameters:
+//   - benchmarkRepo: Repository for benchmark consents and statistics
+//   - policy: The privacy budget, minimum cohort size and clipping bound
+//
+// Returns:
+//   - A configured BenchmarkService
+func NewBenchmarkService(benchmarkRepo repository.BenchmarkRepository, policy *config.BenchmarkSettings) *BenchmarkService {
+	return &BenchmarkService{
+		benchmarkRepo: benchmarkRepo,
+		policy:        policy,
+		noise:         laplaceNoise,
+	}
+}
+
+// SetClock sets the clock consents are recorded and benchmark reports are
+// generated by, so tests can move time past the benchmark interval.
+func (s *BenchmarkService) SetClock(c clock.Clock) {
+	s.clock = c
+}
+
+// now returns the current time of the service's clock.
+func (s *BenchmarkService) now() time.Time {
+	return clock.Or(s.clock).Now()
+}
+
+// GetConsent retrieves a tenant's benchmark consent.
+//
+// Parameters:
+//   - ctx: Context for the operation
+//   - userID: The ID of the tenant
+//
+// Returns:
+//   - The consent
+//   - NotFoundError if the tenant has not opted in
+func (s *BenchmarkService) GetConsent(ctx context.Context, userID int64) (*models.BenchmarkConsent, error) {
+	return s.benchmarkRepo.GetConsent(ctx, userID)
+}
+
+// SetConsent opts a tenant in to benchmarking or changes its industry.
+// The tenant is included in the aggregates from the next benchmark run on.
+//
+// Parameters:
+//   - ctx: Context for the operation
+//   - userID: The ID of the tenant
+//   - industry: The sector the tenant is benchmarked against
+//
+// Returns:
+//   - The stored consent
+//   - An error if the consent cannot be stored
+func (s *BenchmarkService) SetConsent(ctx context.Context, userID int64, industry models.Industry) (*models.BenchmarkConsent, error) {
+	consent := models.NewBenchmarkConsent(userID, industry)
+	consent.ConsentedAt = s.now()
+
+	if err := s.benchmarkRepo.SetConsent(ctx, consent); err != nil {
+		return nil, err
+	}
+
+	log.Info().
+		Int64(constants.ColumnUserID, userID).
+		Str("industry", string(industry)).
+		Msg("Tenant opted in to benchmarking")
+
+	return consent, nil
+}
+
+// RevokeConsent opts a tenant out of benchmarking.
+// The tenant is excluded from the aggregates from the next benchmark run on.
+//
+// Parameters:
+//   - ctx: Context for the operation
+//   - userID: The ID of the tenant
+//
+// Returns:
+//   - NotFoundError if the tenant has not opted in
+//   - Other errors if the consent cannot be deleted
+func (s *BenchmarkService) RevokeConsent(ctx context.Context, userID int64) error {
+	if err := s.benchmarkRepo.DeleteConsent(ctx, userID); err != nil {
+		return err
+	}
+
+	log.Info().
+		Int64(constants.ColumnUserID, userID).
+		Msg("Tenant opted out of benchmarking")
+
+	return nil
+}
+
+// GetComparison compares a tenant's own entities per page with the published benchmarks.
+// Benchmarks are only shown to tenants that share their own statistics.
+//
+// Parameters:
+//   - ctx: Context for the operation
+//   - userID: The ID of the tenant
+//
+// Returns:
+//   - The comparison
+//   - ForbiddenError if the tenant has not opted in
+//   - Other errors if the statistics cannot be read
+func (s *BenchmarkService) GetComparison(ctx context.Context, userID int64) (*models.BenchmarkComparison, error) {
+	report, err := s.GetReport(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	stats, err := s.benchmarkRepo.GetStatsByUserID(ctx, userID, report.WindowStart)
+	if err != nil {
+		if utils.IsNotFoundError(err) {
+			return nil, utils.NewForbiddenError(constants.MsgBenchmarkConsentRequired)
+		}
+		return nil, err
+	}
+
+	comparison := &models.BenchmarkComparison{
+		Industry:        stats.Industry,
+		EntitiesPerPage: stats.EntitiesPerPage(),
+		Report:          report,
+	}
+	if benchmark := report.Industry(stats.Industry); benchmark != nil {
+		average := benchmark.AvgEntitiesPerPage
+		comparison.IndustryAvgEntitiesPerPage = &average
+	}
+
+	return comparison, nil
+}
+
+// GetReport returns the latest benchmark report, computing one first if there is none yet.
+//
+// Parameters:
+//   - ctx: Context for the operation
+//
+// Returns:
+//   - The latest report
+//   - An error if a new report is needed and cannot be computed
+func (s *BenchmarkService) GetReport(ctx context.Context) (*models.BenchmarkReport, error) {
+	s.reportMutex.RLock()
+	report := s.report
+	s.reportMutex.RUnlock()
+
+	if report != nil {
+		return report, nil
+	}
+
+	return s.Compute(ctx)
+}
+
+// Compute aggregates the statistics of the consenting tenants and replaces the latest report.
+//
+// Parameters:
+//   - ctx: Context for the operation
+//
+// Returns:
+//   - The new report
+//   - An error if the statistics cannot be read
+func (s *BenchmarkService) Compute(ctx context.Context) (*models.BenchmarkReport, error) {
+	now := s.now()
+	windowStart := now.Add(-constants.BenchmarkWindow)
+
+	statsList, err := s.benchmarkRepo.GetStats(ctx, windowStart)
+	if err != nil {
+		return nil, err
+	}
+
+	// Clip each tenant's value so that no tenant can move an industry's sum by more than the bound
+	bound := s.policy.MaxEntitiesPerPage
+	sums := make(map[models.Industry]float64)
+	counts := make(map[models.Industry]int)
+	for _, stats := range statsList {
+		if stats.PageCount <= 0 {
+			continue
+		}
+		sums[stats.Industry] += math.Min(stats.EntitiesPerPage(), bound)
+		counts[stats.Industry]++
+	}
+
+	// The sum and the count each spend half of the budget
+	epsilon := s.policy.Epsilon / 2
+
+	report := &models.BenchmarkReport{
+		Industries:  []*models.IndustryBenchmark{},
+		Epsilon:     s.policy.Epsilon,
+		WindowStart: windowStart,
+		GeneratedAt: now,
+	}
+	for industry, count := range counts {
+		countNoise, err := s.noise(1 / epsilon)
+		if err != nil {
+			return nil, err
+		}
+		noisyCount := float64(count) + countNoise
+		if noisyCount < float64(s.policy.MinTenants) {
+			continue
+		}
+
+		sumNoise, err := s.noise(bound / epsilon)
+		if err != nil {
+			return nil, err
+		}
+		noisySum := sums[industry] + sumNoise
+		average := math.Max(0, math.Min(noisySum/noisyCount, bound))
+
+		report.Industries = append(report.Industries, &models.IndustryBenchmark{
+			Industry:           industry,
+			AvgEntitiesPerPage: math.Round(average*100) / 100,
+		})
+	}
+
+	sort.Slice(report.Industries, func(i, j int) bool {
+		return report.Industries[i].Industry < report.Industries[j].Industry
+	})
+
+	s.reportMutex.Lock()
+	s.report = report
+	s.reportMutex.Unlock()
+
+	log.Info().
+		Int("industries", len(report.Industries)).
+		Float64("epsilon", report.Epsilon).
+		Msg("Benchmarks published")
+
+	return report, nil
+}
+
+// RunIfDue computes the benchmarks when the latest report is older than constants.BenchmarkInterval.
+// The maintenance task calls this every cycle, so the benchmarks are recomputed far less often than
+// the other tasks run, which keeps the privacy budget spent on each tenant small.
+//
+// Parameters:
+//   - ctx: Context for the operation
+//
+// Returns:
+//   - The number of industries published, or -1 if the benchmarks were not due
+//   - An error if the benchmarks cannot be computed
+func (s *BenchmarkService) RunIfDue(ctx context.Context) (int, error) {
+	s.reportMutex.RLock()
+	due := s.report == nil || s.now().Sub(s.report.GeneratedAt) >= constants.BenchmarkInterval
+	s.reportMutex.RUnlock()
+
+	if !due {
+		return -1, nil
+	}
+
+	report, err := s.Compute(ctx)
+	if err != nil {
+		return 0, err
+	}
+
+	return len(report.Industries), nil
+}
+
+// laplaceNoise draws a sample from the Laplace distribution centered at 0 with the given scale.
+// Samples come from crypto/rand so that the noise cannot be predicted from earlier reports.
+func laplaceNoise(scale float64) (float64, error) {
+	var buf [8]byte
+	if _, err := rand.Read(buf[:]); err != nil {
+		// Publishing without noise would break the privacy guarantee
+		return 0, fmt.Errorf("failed to read random bytes for benchmark noise: %w", err)
+	}
+
+	// Uniform sample in (-0.5, 0.5) from the top 53 bits
+	u := (float64(binary.BigEndian.Uint64(buf[:])>>11)+0.5)/(1<<53) - 0.5
+	if u < 0 {
+		return scale * math.Log(1+2*u), nil
+	}
+	return -scale * math.Log(1-2*u), nil
+}
@@ -0,0 +1,277 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"math"
+	"testing"
+	"time"
+
+	"github.com/yasinhessnawi1/Hideme_Backend/internal/config"
+	"github.com/yasinhessnawi1/Hideme_Backend/internal/models"
+	"github.com/yasinhessnawi1/Hideme_Backend/internal/testsupport/fakeclock"
+	"github.com/yasinhessnawi1/Hideme_Backend/internal/utils"
+)
+
+// MockBenchmarkRepository is an in-memory implementation of repository.BenchmarkRepository
+type MockBenchmarkRepository struct {
+	consents map[int64]*models.BenchmarkConsent
+	stats    []*models.TenantBenchmarkStats
+	since    time.Time
+}
+
+func NewMockBenchmarkRepository() *MockBenchmarkRepository {
+	return &MockBenchmarkRepository{consents: make(map[int64]*models.BenchmarkConsent)}
+}
+
+func (m *MockBenchmarkRepository) SetConsent(ctx context.Context, consent *models.BenchmarkConsent) error {
+	m.consents[consent.UserID] = consent
+	return nil
+}
+
+func (m *MockBenchmarkRepository) GetConsent(ctx context.Context, userID int64) (*models.BenchmarkConsent, error) {
+	consent, ok := m.consents[userID]
+	if !ok {
+		return nil, utils.NewNotFoundError("BenchmarkConsent", userID)
+	}
+	return consent, nil
+}
+
+func (m *MockBenchmarkRepository) DeleteConsent(ctx context.Context, userID int64) error {
+	if _, ok := m.consents[userID]; !ok {
+		return utils.NewNotFoundError("BenchmarkConsent", userID)
+	}
+	delete(m.consents, userID)
+	return nil
+}
+
+func (m *MockBenchmarkRepository) GetStats(ctx context.Context, since time.Time) ([]*models.TenantBenchmarkStats, error) {
+	m.since = since
+	return m.stats, nil
+}
+
+func (m *MockBenchmarkRepository) GetStatsByUserID(ctx context.Context, userID int64, since time.Time) (*models.TenantBenchmarkStats, error) {
+	for _, stats := range m.stats {
+		if stats.UserID == userID {
+			return stats, nil
+		}
+	}
+	return nil, utils.NewNotFoundError("BenchmarkConsent", userID)
+}
+
+func newTestBenchmarkService(repo *MockBenchmarkRepository) *BenchmarkService {
+	service := NewBenchmarkService(repo, &config.BenchmarkSettings{
+		Epsilon:            1.0,
+		MinTenants:         3,
+		MaxEntitiesPerPage: 10,
+	})
+	service.noise = func(scale float64) (float64, error) { return 0, nil }
+	return service
+}
+
+// benchmarkTestStats returns three legal tenants, two finance tenants and a legal tenant without pages.
+func benchmarkTestStats() []*models.TenantBenchmarkStats {
+	return []*models.TenantBenchmarkStats{
+		{UserID: 1, Industry: models.IndustryLegal, EntityCount: 20, PageCount: 10},
+		{UserID: 2, Industry: models.IndustryLegal, EntityCount: 40, PageCount: 10},
+		// Clipped to 10 entities per page
+		{UserID: 3, Industry: models.IndustryLegal, EntityCount: 900, PageCount: 10},
+		// Tenants without pages don't count towards the cohort
+		{UserID: 4, Industry: models.IndustryLegal, EntityCount: 0, PageCount: 0},
+		{UserID: 5, Industry: models.IndustryFinance, EntityCount: 10, PageCount: 10},
+		{UserID: 6, Industry: models.IndustryFinance, EntityCount: 30, PageCount: 10},
+	}
+}
+
+func TestBenchmarkService_Compute(t *testing.T) {
+	repo := NewMockBenchmarkRepository()
+	repo.stats = benchmarkTestStats()
+	service := newTestBenchmarkService(repo)
+
+	report, err := service.Compute(context.Background())
+	if err != nil {
+		t.Fatalf("Compute() error = %v", err)
+	}
+
+	// Finance has only two tenants and is suppressed
+	if len(report.Industries) != 1 {
+		t.Fatalf("Compute() published %d industries; want 1: %+v", len(report.Industries), report.Industries)
+	}
+
+	legal := report.Industries[0]
+	if legal.Industry != models.IndustryLegal || legal.AvgEntitiesPerPage != 5.33 {
+		t.Errorf("Unexpected legal benchmark: %+v", legal)
+	}
+
+	if report.Epsilon != 1.0 {
+		t.Errorf("Expected epsilon 1.0, got %v", report.Epsilon)
+	}
+
+	if !repo.since.Equal(report.WindowStart) {
+		t.Errorf("Statistics were read since %v; want the window start %v", repo.since, report.WindowStart)
+	}
+}
+
+func TestBenchmarkService_ComputeNoise(t *testing.T) {
+	repo := NewMockBenchmarkRepository()
+	repo.stats = benchmarkTestStats()
+	service := newTestBenchmarkService(repo)
+
+	// Each of the sum and count spends half of the budget
+	scales := []float64{}
+	service.noise = func(scale float64) (float64, error) {
+		scales = append(scales, scale)
+		if scale == 2 {
+			// Count noise lifts finance over the minimum and leaves legal just above it
+			return 1, nil
+		}
+		return 100, nil
+	}
+
+	report, err := service.Compute(context.Background())
+	if err != nil {
+		t.Fatalf("Compute() error = %v", err)
+	}
+
+	for _, scale := range scales {
+		if scale != 2 && scale != 20 {
+			t.Errorf("Unexpected noise scale %v; want 1/(epsilon/2) for counts and bound/(epsilon/2) for sums", scale)
+		}
+	}
+
+	// Noisy averages are clamped to the clipping bound
+	if len(report.Industries) != 2 {
+		t.Fatalf("Compute() published %d industries; want 2", len(report.Industries))
+	}
+	for _, benchmark := range report.Industries {
+		if benchmark.AvgEntitiesPerPage != 10 {
+			t.Errorf("Expected %s average to be clamped to 10, got %v", benchmark.Industry, benchmark.AvgEntitiesPerPage)
+		}
+	}
+
+	// Industries are sorted alphabetically
+	if report.Industries[0].Industry != models.IndustryFinance {
+		t.Errorf("Expected finance first, got %s", report.Industries[0].Industry)
+	}
+}
+
+func TestBenchmarkService_ComputeNoiseError(t *testing.T) {
+	repo := NewMockBenchmarkRepository()
+	repo.stats = benchmarkTestStats()
+	service := newTestBenchmarkService(repo)
+	service.noise = func(scale float64) (float64, error) { return 0, errors.New("no entropy") }
+
+	if _, err := service.Compute(context.Background()); err == nil {
+		t.Error("Compute() should fail rather than publish without noise")
+	}
+}
+
+func TestBenchmarkService_GetComparison(t *testing.T) {
+	repo := NewMockBenchmarkRepository()
+	repo.stats = benchmarkTestStats()
+	service := newTestBenchmarkService(repo)
+	ctx := context.Background()
+
+	t.Run("Published industry", func(t *testing.T) {
+		comparison, err := service.GetComparison(ctx, 1)
+		if err != nil {
+			t.Fatalf("GetComparison() error = %v", err)
+		}
+		if comparison.Industry != models.IndustryLegal || comparison.EntitiesPerPage != 2 {
+			t.Errorf("Unexpected own statistics: %+v", comparison)
+		}
+		if comparison.IndustryAvgEntitiesPerPage == nil || *comparison.IndustryAvgEntitiesPerPage != 5.33 {
+			t.Errorf("Expected industry average 5.33, got %v", comparison.IndustryAvgEntitiesPerPage)
+		}
+	})
+
+	t.Run("Suppressed industry", func(t *testing.T) {
+		comparison, err := service.GetComparison(ctx, 5)
+		if err != nil {
+			t.Fatalf("GetComparison() error = %v", err)
+		}
+		if comparison.IndustryAvgEntitiesPerPage != nil {
+			t.Errorf("Expected no industry average, got %v", *comparison.IndustryAvgEntitiesPerPage)
+		}
+	})
+
+	t.Run("Not consented", func(t *testing.T) {
+		_, err := service.GetComparison(ctx, 99)
+		if !errors.Is(err, utils.ErrForbidden) {
+			t.Errorf("Expected forbidden error, got %v", err)
+		}
+	})
+}
+
+func TestBenchmarkService_RunIfDue(t *testing.T) {
+	repo := NewMockBenchmarkRepository()
+	repo.stats = benchmarkTestStats()
+	service := newTestBenchmarkService(repo)
+	now := time.Now()
+	clk := fakeclock.New(now)
+	service.SetClock(clk)
+	ctx := context.Background()
+
+	published, err := service.RunIfDue(ctx)
+	if err != nil || published != 1 {
+		t.Fatalf("RunIfDue() = %d, %v; want 1, nil", published, err)
+	}
+
+	// A fresh report is kept so the noise cannot be averaged away
+	now = clk.Advance(time.Hour)
+	if published, _ := service.RunIfDue(ctx); published != -1 {
+		t.Errorf("RunIfDue() = %d; want -1 while the report is fresh", published)
+	}
+
+	now = clk.Advance(8 * 24 * time.Hour)
+	if published, _ := service.RunIfDue(ctx); published != 1 {
+		t.Errorf("RunIfDue() = %d; want 1 once the report is stale", published)
+	}
+}
+
+func TestBenchmarkService_Consent(t *testing.T) {
+	repo := NewMockBenchmarkRepository()
+	service := newTestBenchmarkService(repo)
+	ctx := context.Background()
+
+	consent, err := service.SetConsent(ctx, 42, models.IndustryHealthcare)
+	if err != nil {
+		t.Fatalf("SetConsent() error = %v", err)
+	}
+	if consent.Industry != models.IndustryHealthcare {
+		t.Errorf("Expected healthcare, got %s", consent.Industry)
+	}
+
+	if _, err := service.GetConsent(ctx, 42); err != nil {
+		t.Errorf("GetConsent() error = %v", err)
+	}
+
+	if err := service.RevokeConsent(ctx, 42); err != nil {
+		t.Errorf("RevokeConsent() error = %v", err)
+	}
+
+	if err := service.RevokeConsent(ctx, 42); !utils.IsNotFoundError(err) {
+		t.Errorf("Expected not found error after revoking twice, got %v", err)
+	}
+}
+
+func TestLaplaceNoise(t *testing.T) {
+	// The mean absolute deviation of a Laplace distribution equals its scale
+	const samples = 20000
+	var sum, absSum float64
+	for i := 0; i < samples; i++ {
+		sample, err := laplaceNoise(2)
+		if err != nil {
+			t.Fatalf("laplaceNoise() error = %v", err)
+		}
+		sum += sample
+		absSum += math.Abs(sample)
+	}
+
+	if mean := sum / samples; math.Abs(mean) > 0.1 {
+		t.Errorf("Expected a mean close to 0, got %v", mean)
+	}
+	if deviation := absSum / samples; math.Abs(deviation-2) > 0.1 {
+		t.Errorf("Expected a mean absolute deviation close to 2, got %v", deviation)
+	}
+}
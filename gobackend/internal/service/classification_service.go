@@ -0,0 +1,324 @@
+// Package service provides business logic implementations for the HideMe application.
+// It contains services that orchestrate operations across repositories and implement
+// the core application functionality.
+//
+// This file implements the classification service, which manages the user's document
+// classification rules and applies them to documents as they are uploaded.
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"path"
+
+	"github.com/rs/zerolog/log"
+
+	"github.com/yasinhessnawi1/Hideme_Backend/internal/constants"
+	"github.com/yasinhessnawi1/Hideme_Backend/internal/models"
+	"github.com/yasinhessnawi1/Hideme_Backend/internal/repository"
+	"github.com/yasinhessnawi1/Hideme_Backend/internal/utils"
+)
+
+// UserSettingsProvider returns the settings of a user, creating defaults if needed.
+type UserSettingsProvider interface {
+	GetUserSettings(ctx context.Context, userID int64) (*models.UserSetting, error)
+}
+
+// ClassificationService manages document classification rules. The rules belong to the
+// user's settings and decide the tags, folder and retention of documents at upload.
+type ClassificationService struct {
+	ruleRepo     repository.ClassificationRuleRepository
+	settings     UserSettingsProvider
+	revisionRepo repository.SettingsRevisionRepository
+	transactor   Transactor
+}
+
+// NewClassificationService creates a new ClassificationService with the specified dependencies.
+//
+// Parameters:
+//   - ruleRepo: Repository for classification rule operations
+//   - settings: Provider of the user settings the rules belong to
+//   - revisionRepo: Repository for the settings revision history
+//
+// Returns:
+//   - A new ClassificationService instance
+func NewClassificationService(
+	ruleRepo repository.ClassificationRuleRepository,
+	settings UserSettingsProvider,
+	revisionRepo repository.SettingsRevisionRepository,
+) *ClassificationService {
+	return &ClassificationService{
+		ruleRepo:     ruleRepo,
+		settings:     settings,
+		revisionRepo: revisionRepo,
+	}
+}
+
+// SetTransactor makes each rule change commit together with its settings revision.
+// Without a transactor, a change and its revision are stored one after the other.
+func (s *ClassificationService) SetTransactor(transactor Transactor) {
+	s.transactor = transactor
+}
+
+// GetRules retrieves the classification rules of a user in the order they are applied.
+//
+// Parameters:
+//   - ctx: Context for the operation
+//   - userID: The ID of the user whose rules to retrieve
+//
+// Returns:
+//   - The user's rules ordered by priority
+//   - An error if retrieval fails
+func (s *ClassificationService) GetRules(ctx context.Context, userID int64) ([]*models.ClassificationRule, error) {
+	settings, err := s.settings.GetUserSettings(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	rules, err := s.ruleRepo.GetBySettingID(ctx, settings.ID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get classification rules: %w", err)
+	}
+
+	return rules, nil
+}
+
+// CreateRule creates a new classification rule for a user.
+//
+// Parameters:
+//   - ctx: Context for the operation
+//   - userID: The ID of the user for whom to create the rule
+//   - req: The rule to create
+//
+// Returns:
+//   - The newly created rule
+//   - ValidationError if the rule lacks a condition or an action, or has a malformed pattern
+//   - Other errors if retrieval or creation fails
+func (s *ClassificationService) CreateRule(ctx context.Context, userID int64, req *models.ClassificationRuleRequest) (*models.ClassificationRule, error) {
+	settings, err := s.settings.GetUserSettings(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	rule := models.NewClassificationRule(settings.ID, req)
+	if err := validateClassificationRule(rule); err != nil {
+		return nil, err
+	}
+
+	err = s.changeRule(ctx, func(ctx context.Context) (*models.SettingsRevision, error) {
+		if err := s.ruleRepo.Create(ctx, rule); err != nil {
+			return nil, fmt.Errorf("failed to create classification rule: %w", err)
+		}
+		return models.NewSettingsRevision(userID, models.ResourceClassificationRule, rule.ID, models.RevisionCreated, rule), nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	log.Info().
+		Int64("user_id", userID).
+		Int64("rule_id", rule.ID).
+		Msg("Classification rule created")
+
+	return rule, nil
+}
+
+// UpdateRule replaces the conditions and actions of a classification rule.
+//
+// Parameters:
+//   - ctx: Context for the operation
+//   - userID: The ID of the user who owns the rule
+//   - ruleID: The ID of the rule to replace
+//   - req: The new conditions and actions of the rule
+//
+// Returns:
+//   - The updated rule
+//   - ForbiddenError if the rule doesn't belong to the user
+//   - NotFoundError if the rule doesn't exist
+//   - ValidationError if the rule lacks a condition or an action, or has a malformed pattern
+//   - Other errors if retrieval or update fails
+func (s *ClassificationService) UpdateRule(ctx context.Context, userID, ruleID int64, req *models.ClassificationRuleRequest) (*models.ClassificationRule, error) {
+	settings, err := s.ownedRule(ctx, userID, ruleID)
+	if err != nil {
+		return nil, err
+	}
+
+	rule := models.NewClassificationRule(settings.ID, req)
+	rule.ID = ruleID
+	if err := validateClassificationRule(rule); err != nil {
+		return nil, err
+	}
+
+	err = s.changeRule(ctx, func(ctx context.Context) (*models.SettingsRevision, error) {
+		if err := s.ruleRepo.Update(ctx, rule); err != nil {
+			return nil, fmt.Errorf("failed to update classification rule: %w", err)
+		}
+		return models.NewSettingsRevision(userID, models.ResourceClassificationRule, rule.ID, models.RevisionUpdated, rule), nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	log.Info().
+		Int64("user_id", userID).
+		Int64("rule_id", rule.ID).
+		Msg("Classification rule updated")
+
+	return rule, nil
+}
+
+// DeleteRule removes a classification rule. Documents it classified keep their tags,
+// folder and retention.
+//
+// Parameters:
+//   - ctx: Context for the operation
+//   - userID: The ID of the user who owns the rule
+//   - ruleID: The ID of the rule to delete
+//
+// Returns:
+//   - ForbiddenError if the rule doesn't belong to the user
+//   - NotFoundError if the rule doesn't exist
+//   - Other errors if retrieval or deletion fails
+func (s *ClassificationService) DeleteRule(ctx context.Context, userID, ruleID int64) error {
+	if _, err := s.ownedRule(ctx, userID, ruleID); err != nil {
+		return err
+	}
+
+	err := s.changeRule(ctx, func(ctx context.Context) (*models.SettingsRevision, error) {
+		if err := s.ruleRepo.Delete(ctx, ruleID); err != nil {
+			return nil, fmt.Errorf("failed to delete classification rule: %w", err)
+		}
+		return models.NewSettingsRevision(userID, models.ResourceClassificationRule, ruleID, models.RevisionDeleted, nil), nil
+	})
+	if err != nil {
+		return err
+	}
+
+	log.Info().
+		Int64("user_id", userID).
+		Int64("rule_id", ruleID).
+		Msg("Classification rule deleted")
+
+	return nil
+}
+
+// Classify applies the classification rules of a user to an uploaded document.
+// Rules are applied in order of priority; the tags of all matching rules are combined,
+// while the folder and retention come from the first matching rule that sets them.
+//
+// Parameters:
+//   - ctx: Context for the operation
+//   - userID: The ID of the user who uploads the document
+//   - filename: The original filename of the document
+//   - source: The source the document came from; empty if unknown
+//   - redactionSchema: The entities detected in the document
+//
+// Returns:
+//   - The classification of the document; empty if no rule matches
+//   - An error if the rules cannot be retrieved
+func (s *ClassificationService) Classify(ctx context.Context, userID int64, filename, source string, redactionSchema models.RedactionMapping) (*models.DocumentClassification, error) {
+	classification := &models.DocumentClassification{Tags: []string{}}
+
+	rules, err := s.GetRules(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	if len(rules) == 0 {
+		return classification, nil
+	}
+
+	// Count the detected entities by type
+	entityCounts := make(map[string]int)
+	for _, page := range redactionSchema.Pages {
+		for _, entity := range page.Sensitive {
+			entityCounts[entity.EntityType]++
+		}
+	}
+
+	for _, rule := range rules {
+		if rule.Matches(filename, source, entityCounts) {
+			classification.Apply(rule)
+			log.Debug().
+				Int64("user_id", userID).
+				Int64("rule_id", rule.ID).
+				Msg("Classification rule matched document")
+		}
+	}
+
+	return classification, nil
+}
+
+// ownedRule checks that a classification rule exists and belongs to the user.
+//
+// Parameters:
+//   - ctx: Context for the operation
+//   - userID: The ID of the user who should own the rule
+//   - ruleID: The ID of the rule
+//
+// Returns:
+//   - The settings of the user
+//   - ForbiddenError if the rule doesn't belong to the user
+//   - NotFoundError if the rule doesn't exist
+func (s *ClassificationService) ownedRule(ctx context.Context, userID, ruleID int64) (*models.UserSetting, error) {
+	settings, err := s.settings.GetUserSettings(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	rule, err := s.ruleRepo.GetByID(ctx, ruleID)
+	if err != nil {
+		return nil, err
+	}
+
+	if rule.SettingID != settings.ID {
+		return nil, utils.NewForbiddenError(constants.MsgAccessDenied)
+	}
+
+	return settings, nil
+}
+
+// changeRule runs a rule change and records the settings revision it returns in one
+// transaction, so the settings change feed neither misses the change nor shows it after
+// a rollback.
+//
+// Parameters:
+//   - ctx: Context for the operation
+//   - change: Makes the change with the context it is given and returns its revision
+//
+// Returns:
+//   - The error of the change, or an error if its revision could not be recorded; nothing is changed then
+func (s *ClassificationService) changeRule(ctx context.Context, change func(ctx context.Context) (*models.SettingsRevision, error)) error {
+	return inTransaction(ctx, s.transactor, func(ctx context.Context) error {
+		revision, err := change(ctx)
+		if err != nil || s.revisionRepo == nil {
+			return err
+		}
+		if err := s.revisionRepo.Record(ctx, revision); err != nil {
+			return fmt.Errorf("failed to record classification rule revision: %w", err)
+		}
+		return nil
+	})
+}
+
+// validateClassificationRule checks that a rule can match documents selectively and
+// changes something about them.
+//
+// Parameters:
+//   - rule: The rule to validate
+//
+// Returns:
+//   - ValidationError if the rule lacks a condition or an action, or has a malformed pattern
+func validateClassificationRule(rule *models.ClassificationRule) error {
+	if !rule.HasCondition() || !rule.HasAction() {
+		return utils.NewValidationError("rule", constants.MsgClassificationRuleIncomplete)
+	}
+
+	if rule.FilenamePattern != "" {
+		if _, err := path.Match(rule.FilenamePattern, ""); errors.Is(err, path.ErrBadPattern) {
+			return utils.NewValidationError("filename_pattern", constants.MsgInvalidFilenamePattern)
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,180 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/yasinhessnawi1/Hideme_Backend/internal/models"
+	"github.com/yasinhessnawi1/Hideme_Backend/internal/utils"
+)
+
+// MockClassificationRuleRepository is an in-memory ClassificationRuleRepository.
+type MockClassificationRuleRepository struct {
+	rules  map[int64]*models.ClassificationRule
+	nextID int64
+}
+
+func NewMockClassificationRuleRepository() *MockClassificationRuleRepository {
+	return &MockClassificationRuleRepository{
+		rules:  make(map[int64]*models.ClassificationRule),
+		nextID: 1,
+	}
+}
+
+func (m *MockClassificationRuleRepository) Create(ctx context.Context, rule *models.ClassificationRule) error {
+	rule.ID = m.nextID
+	m.nextID++
+	m.rules[rule.ID] = rule
+	return nil
+}
+
+func (m *MockClassificationRuleRepository) GetByID(ctx context.Context, id int64) (*models.ClassificationRule, error) {
+	rule, ok := m.rules[id]
+	if !ok {
+		return nil, utils.NewNotFoundError("ClassificationRule", id)
+	}
+	return rule, nil
+}
+
+func (m *MockClassificationRuleRepository) GetBySettingID(ctx context.Context, settingID int64) ([]*models.ClassificationRule, error) {
+	rules := []*models.ClassificationRule{}
+	for id := int64(1); id < m.nextID; id++ {
+		if rule, ok := m.rules[id]; ok && rule.SettingID == settingID {
+			rules = append(rules, rule)
+		}
+	}
+	// Order by priority like the database does; IDs are already ascending
+	for i := 1; i < len(rules); i++ {
+		for j := i; j > 0 && rules[j].Priority < rules[j-1].Priority; j-- {
+			rules[j], rules[j-1] = rules[j-1], rules[j]
+		}
+	}
+	return rules, nil
+}
+
+func (m *MockClassificationRuleRepository) Update(ctx context.Context, rule *models.ClassificationRule) error {
+	if _, ok := m.rules[rule.ID]; !ok {
+		return utils.NewNotFoundError("ClassificationRule", rule.ID)
+	}
+	m.rules[rule.ID] = rule
+	return nil
+}
+
+func (m *MockClassificationRuleRepository) Delete(ctx context.Context, id int64) error {
+	if _, ok := m.rules[id]; !ok {
+		return utils.NewNotFoundError("ClassificationRule", id)
+	}
+	delete(m.rules, id)
+	return nil
+}
+
+func setupClassificationService(t *testing.T, userIDs ...int64) (*ClassificationService, *MockSettingsRevisionRepository) {
+	settingsRepo := NewMockSettingsRepository()
+	for _, userID := range userIDs {
+		settingsRepo.RegisterValidUserID(userID)
+	}
+	settingsService := NewSettingsService(settingsRepo, NewMockBanListRepository(), NewMockPatternRepository(), NewMockModelEntityRepository(), NewMockSettingsRevisionRepository())
+
+	revisionRepo := NewMockSettingsRevisionRepository()
+	return NewClassificationService(NewMockClassificationRuleRepository(), settingsService, revisionRepo), revisionRepo
+}
+
+func TestClassificationService_CreateRule(t *testing.T) {
+	svc, revisionRepo := setupClassificationService(t, 1)
+	ctx := context.Background()
+
+	t.Run("Valid rule", func(t *testing.T) {
+		rule, err := svc.CreateRule(ctx, 1, &models.ClassificationRuleRequest{Name: "Invoices", FilenamePattern: "invoice*.pdf", Tags: []string{"finance"}})
+
+		require.NoError(t, err)
+		assert.NotZero(t, rule.ID)
+		assert.Equal(t, []string{}, rule.EntityTypes)
+		require.Len(t, revisionRepo.revisions, 1)
+		assert.Equal(t, models.ResourceClassificationRule, revisionRepo.revisions[0].ResourceType)
+	})
+
+	t.Run("Rule without condition", func(t *testing.T) {
+		_, err := svc.CreateRule(ctx, 1, &models.ClassificationRuleRequest{Name: "Everything", Tags: []string{"all"}})
+		assert.True(t, utils.IsValidationError(err))
+	})
+
+	t.Run("Rule without action", func(t *testing.T) {
+		_, err := svc.CreateRule(ctx, 1, &models.ClassificationRuleRequest{Name: "Nothing", Source: "scanner"})
+		assert.True(t, utils.IsValidationError(err))
+	})
+
+	t.Run("Malformed filename pattern", func(t *testing.T) {
+		_, err := svc.CreateRule(ctx, 1, &models.ClassificationRuleRequest{Name: "Broken", FilenamePattern: "invoice[", Folder: "Invoices"})
+		assert.True(t, utils.IsValidationError(err))
+	})
+}
+
+func TestClassificationService_UpdateAndDeleteRule(t *testing.T) {
+	svc, _ := setupClassificationService(t, 1, 2)
+	ctx := context.Background()
+
+	rule, err := svc.CreateRule(ctx, 1, &models.ClassificationRuleRequest{Name: "Scans", Source: "scanner", Folder: "Scans"})
+	require.NoError(t, err)
+
+	t.Run("Owner replaces the rule", func(t *testing.T) {
+		updated, err := svc.UpdateRule(ctx, 1, rule.ID, &models.ClassificationRuleRequest{Name: "Scans", Source: "scanner", RetentionDays: 30})
+
+		require.NoError(t, err)
+		assert.Equal(t, rule.ID, updated.ID)
+		assert.Empty(t, updated.Folder)
+		assert.Equal(t, 30, updated.RetentionDays)
+	})
+
+	t.Run("Other users cannot change the rule", func(t *testing.T) {
+		_, err := svc.UpdateRule(ctx, 2, rule.ID, &models.ClassificationRuleRequest{Name: "Scans", Source: "scanner", Folder: "Mine"})
+		assert.True(t, errors.Is(err, utils.ErrForbidden))
+
+		assert.True(t, errors.Is(svc.DeleteRule(ctx, 2, rule.ID), utils.ErrForbidden))
+	})
+
+	t.Run("Owner deletes the rule", func(t *testing.T) {
+		require.NoError(t, svc.DeleteRule(ctx, 1, rule.ID))
+		assert.True(t, utils.IsNotFoundError(svc.DeleteRule(ctx, 1, rule.ID)))
+	})
+}
+
+func TestClassificationService_Classify(t *testing.T) {
+	svc, _ := setupClassificationService(t, 1)
+	ctx := context.Background()
+
+	requests := []*models.ClassificationRuleRequest{
+		{Name: "Medical", Priority: 20, EntityTypes: []string{"HEALTH"}, Tags: []string{"medical"}, Folder: "Health", RetentionDays: 90},
+		{Name: "Invoices", Priority: 10, FilenamePattern: "INVOICE*", Tags: []string{"finance"}, Folder: "Invoices"},
+		{Name: "Scans", Priority: 30, Source: "scanner", Tags: []string{"finance", "scanned"}, RetentionDays: 365},
+	}
+	for _, req := range requests {
+		_, err := svc.CreateRule(ctx, 1, req)
+		require.NoError(t, err)
+	}
+
+	schema := models.RedactionMapping{Pages: []models.Page{
+		{PageNumber: 1, Sensitive: []models.Sensitive{{EntityType: "HEALTH"}, {EntityType: "PERSON"}}},
+	}}
+
+	t.Run("Matching rules are combined in priority order", func(t *testing.T) {
+		classification, err := svc.Classify(ctx, 1, "invoice_2024.pdf", "scanner", schema)
+
+		require.NoError(t, err)
+		assert.Equal(t, []string{"finance", "medical", "scanned"}, classification.Tags)
+		assert.Equal(t, "Invoices", classification.Folder)
+		assert.Equal(t, 90, classification.RetentionDays)
+	})
+
+	t.Run("No matching rule", func(t *testing.T) {
+		classification, err := svc.Classify(ctx, 1, "notes.txt", "email", models.RedactionMapping{})
+
+		require.NoError(t, err)
+		assert.Empty(t, classification.Tags)
+		assert.Empty(t, classification.Folder)
+		assert.Zero(t, classification.RetentionDays)
+	})
+}
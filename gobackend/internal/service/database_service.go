@@ -16,6 +16,7 @@ import (
 
 	"github.com/yasinhessnawi1/Hideme_Backend/internal/constants"
 	"github.com/yasinhessnawi1/Hideme_Backend/internal/database"
+	"github.com/yasinhessnawi1/Hideme_Backend/internal/models"
 	"github.com/yasinhessnawi1/Hideme_Backend/internal/utils"
 )
 
@@ -353,3 +354,94 @@ func (s *DatabaseService) GetTableSchema(ctx context.Context, table string) ([]m
 
 	return results, nil
 }
+
+// GetQueryDiagnostics returns the query monitoring settings and the statistics of all tracked queries.
+//
+// Parameters:
+//   - ctx: Context for the operation
+//
+// Returns:
+//   - The current monitoring settings
+//   - The query statistics, the most expensive query first
+//   - An error if the pool is not instrumented
+func (s *DatabaseService) GetQueryDiagnostics(ctx context.Context) (*database.QueryMonitorSettings, []database.QueryStats, error) {
+	monitor, err := s.queryMonitor()
+	if err != nil {
+		return nil, nil, err
+	}
+
+	settings := monitor.Settings()
+	return &settings, monitor.Snapshot(), nil
+}
+
+// UpdateQueryDiagnostics changes the query monitoring settings at runtime.
+//
+// Parameters:
+//   - ctx: Context for the operation
+//   - update: The settings to change; omitted fields keep their value
+//   - userID: ID of the administrator making the change (for auditing)
+//
+// Returns:
+//   - The updated monitoring settings
+//   - An error if the pool is not instrumented
+func (s *DatabaseService) UpdateQueryDiagnostics(ctx context.Context, update *models.QueryDiagnosticsUpdate, userID int64) (*database.QueryMonitorSettings, error) {
+	monitor, err := s.queryMonitor()
+	if err != nil {
+		return nil, err
+	}
+
+	settings := monitor.Settings()
+	if update.MetricsEnabled != nil {
+		settings.MetricsEnabled = *update.MetricsEnabled
+	}
+	if update.ExplainEnabled != nil {
+		settings.ExplainEnabled = *update.ExplainEnabled
+	}
+	if update.SlowQueryThresholdMs != nil {
+		settings.SlowQueryThresholdMs = *update.SlowQueryThresholdMs
+	}
+	monitor.Configure(settings)
+
+	log.Info().
+		Int64("user_id", userID).
+		Bool("metrics_enabled", settings.MetricsEnabled).
+		Bool("explain_enabled", settings.ExplainEnabled).
+		Int64("slow_query_threshold_ms", settings.SlowQueryThresholdMs).
+		Str("category", constants.LogCategoryAdmin).
+		Msg("Query diagnostics settings changed")
+
+	settings = monitor.Settings()
+	return &settings, nil
+}
+
+// ResetQueryMetrics discards the collected query statistics, e.g. after a release.
+//
+// Parameters:
+//   - ctx: Context for the operation
+//   - userID: ID of the administrator resetting the statistics (for auditing)
+//
+// Returns:
+//   - An error if the pool is not instrumented
+func (s *DatabaseService) ResetQueryMetrics(ctx context.Context, userID int64) error {
+	monitor, err := s.queryMonitor()
+	if err != nil {
+		return err
+	}
+
+	monitor.Reset()
+
+	log.Info().
+		Int64("user_id", userID).
+		Str("category", constants.LogCategoryAdmin).
+		Msg("Query metrics reset")
+
+	return nil
+}
+
+// queryMonitor returns the monitor of the database pool.
+func (s *DatabaseService) queryMonitor() (*database.QueryMonitor, error) {
+	if s.db == nil || s.db.Monitor == nil {
+		return nil, utils.NewBadRequestError("Query monitoring is not available for this database connection")
+	}
+	return s.db.Monitor, nil
+}
@@ -0,0 +1,240 @@
+// Package service provides business logic implementations.
+package service
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/rs/zerolog/log"
+
+	"github.com/yasinhessnawi1/Hideme_Backend/internal/constants"
+	"github.com/yasinhessnawi1/Hideme_Backend/internal/models"
+	"github.com/yasinhessnawi1/Hideme_Backend/internal/repository"
+	"github.com/yasinhessnawi1/Hideme_Backend/internal/utils"
+	"github.com/yasinhessnawi1/Hideme_Backend/internal/utils/clock"
+)
+
+// DetectionCatalogService promotes the detection methods and the entity-type taxonomy
+// between environments. Administrators export a bundle from one environment, preview what
+// importing it would change in another, and import it. Methods and entity types are matched
+// by name, and an import only creates and updates them: whatever the target environment has
+// beyond the bundle is kept, as detected entities and user settings may reference it.
+type DetectionCatalogService struct {
+	catalogRepo repository.DetectionCatalogRepository
+	clock       clock.Clock
+}
+
+// NewDetectionCatalogService creates a new DetectionCatalogService.
+//
+// Parameters:
+//   - catalogRepo: Repository for the detection methods and entity types
+//
+// Returns:
+//   - A configured DetectionCatalogService
+func NewDetectionCatalogService(catalogRepo repository.DetectionCatalogRepository) *DetectionCatalogService {
+	return &DetectionCatalogService{
+		catalogRepo: catalogRepo,
+	}
+}
+
+// SetClock sets the clock catalog exports are stamped with.
+func (s *DetectionCatalogService) SetClock(c clock.Clock) {
+	s.clock = c
+}
+
+// now returns the current time of the service's clock.
+func (s *DetectionCatalogService) now() time.Time {
+	return clock.Or(s.clock).Now()
+}
+
+// Export returns the detection methods and entity types of this environment as a bundle.
+//
+// Parameters:
+//   - ctx: Context for the operation
+//
+// Returns:
+//   - The bundle, with methods and entity types by name
+//   - An error if retrieval fails
+func (s *DetectionCatalogService) Export(ctx context.Context) (*models.DetectionCatalogBundle, error) {
+	methods, err := s.catalogRepo.ListDetectionMethods(ctx)
+	if err != nil {
+		return nil, err
+	}
+	entityTypes, err := s.catalogRepo.ListEntityTypes(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	bundle := &models.DetectionCatalogBundle{
+		Version:          constants.DetectionCatalogBundleVersion,
+		ExportedAt:       s.now().UTC(),
+		DetectionMethods: make([]models.DetectionCatalogMethod, 0, len(methods)),
+		EntityTypes:      make([]models.DetectionCatalogEntityType, 0, len(entityTypes)),
+	}
+	for _, method := range methods {
+		bundle.DetectionMethods = append(bundle.DetectionMethods, models.DetectionCatalogMethod{
+			MethodName:     method.MethodName,
+			HighlightColor: method.HighlightColor,
+		})
+	}
+	for _, entityType := range entityTypes {
+		bundle.EntityTypes = append(bundle.EntityTypes, models.DetectionCatalogEntityType{
+			EntityType:  entityType.EntityType,
+			MethodName:  entityType.MethodName,
+			Description: entityType.Description,
+		})
+	}
+	return bundle, nil
+}
+
+// Preview compares a bundle with this environment without changing anything.
+//
+// Parameters:
+//   - ctx: Context for the operation
+//   - bundle: The bundle exported from another environment
+//
+// Returns:
+//   - What importing the bundle would create, update and keep
+//   - ValidationError if the bundle has an unsupported version, lists an item twice, or
+//     references a detection method that exists nowhere
+//   - Other errors if retrieval fails
+func (s *DetectionCatalogService) Preview(ctx context.Context, bundle *models.DetectionCatalogBundle) (*models.DetectionCatalogDiff, error) {
+	return s.diff(ctx, bundle)
+}
+
+// Import creates and updates the detection methods and entity types of a bundle in this
+// environment, all or nothing. Importing a bundle that changes nothing is a no-op.
+//
+// Parameters:
+//   - ctx: Context for the operation
+//   - adminID: The ID of the administrator importing the bundle
+//   - bundle: The bundle exported from another environment
+//
+// Returns:
+//   - What the import created, updated and kept
+//   - ValidationError if the bundle has an unsupported version, lists an item twice, or
+//     references a detection method that exists nowhere
+//   - Other errors if the import failed; nothing was changed then
+func (s *DetectionCatalogService) Import(ctx context.Context, adminID int64, bundle *models.DetectionCatalogBundle) (*models.DetectionCatalogDiff, error) {
+	diff, err := s.diff(ctx, bundle)
+	if err != nil {
+		return nil, err
+	}
+
+	if diff.HasChanges() {
+		if err := s.catalogRepo.Import(ctx, bundle); err != nil {
+			return nil, err
+		}
+	}
+	diff.Applied = true
+
+	log.Info().
+		Int64("admin_id", adminID).
+		Int("created", diff.Created).
+		Int("updated", diff.Updated).
+		Int("unchanged", diff.Unchanged).
+		Msg("Detection catalog imported")
+	return diff, nil
+}
+
+// diff validates a bundle and compares it with this environment.
+func (s *DetectionCatalogService) diff(ctx context.Context, bundle *models.DetectionCatalogBundle) (*models.DetectionCatalogDiff, error) {
+	if bundle.Version != constants.DetectionCatalogBundleVersion {
+		return nil, utils.NewValidationError("version", constants.MsgDetectionCatalogVersionUnsupported)
+	}
+
+	methods, err := s.catalogRepo.ListDetectionMethods(ctx)
+	if err != nil {
+		return nil, err
+	}
+	entityTypes, err := s.catalogRepo.ListEntityTypes(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	diff := &models.DetectionCatalogDiff{
+		DetectionMethods: []models.DetectionCatalogChange{},
+		EntityTypes:      []models.DetectionCatalogChange{},
+	}
+
+	existingMethods := make(map[string]*models.DetectionMethod, len(methods))
+	for _, method := range methods {
+		existingMethods[method.MethodName] = method
+	}
+	bundledMethods := make(map[string]bool, len(bundle.DetectionMethods))
+	for i, method := range bundle.DetectionMethods {
+		if bundledMethods[method.MethodName] {
+			return nil, utils.NewValidationError(fmt.Sprintf("detection_methods[%d].method_name", i), constants.MsgDetectionCatalogDuplicate)
+		}
+		bundledMethods[method.MethodName] = true
+
+		var fields []models.DetectionCatalogFieldChange
+		existing, ok := existingMethods[method.MethodName]
+		if ok {
+			fields = appendFieldChange(fields, "highlight_color", existing.HighlightColor, method.HighlightColor)
+		}
+		recordCatalogChange(diff, &diff.DetectionMethods, method.MethodName, ok, fields)
+	}
+	for _, method := range methods {
+		if !bundledMethods[method.MethodName] {
+			diff.DetectionMethods = append(diff.DetectionMethods, models.DetectionCatalogChange{Name: method.MethodName, Action: constants.DetectionCatalogChangeKept})
+		}
+	}
+
+	existingEntityTypes := make(map[string]*models.EntityType, len(entityTypes))
+	for _, entityType := range entityTypes {
+		existingEntityTypes[entityType.EntityType] = entityType
+	}
+	bundledEntityTypes := make(map[string]bool, len(bundle.EntityTypes))
+	for i, entityType := range bundle.EntityTypes {
+		if bundledEntityTypes[entityType.EntityType] {
+			return nil, utils.NewValidationError(fmt.Sprintf("entity_types[%d].entity_type", i), constants.MsgDetectionCatalogDuplicate)
+		}
+		bundledEntityTypes[entityType.EntityType] = true
+
+		if !bundledMethods[entityType.MethodName] && existingMethods[entityType.MethodName] == nil {
+			return nil, utils.NewValidationError(fmt.Sprintf("entity_types[%d].method_name", i), constants.MsgDetectionCatalogMethodUnknown)
+		}
+
+		var fields []models.DetectionCatalogFieldChange
+		existing, ok := existingEntityTypes[entityType.EntityType]
+		if ok {
+			fields = appendFieldChange(fields, "method_name", existing.MethodName, entityType.MethodName)
+			fields = appendFieldChange(fields, "description", existing.Description, entityType.Description)
+		}
+		recordCatalogChange(diff, &diff.EntityTypes, entityType.EntityType, ok, fields)
+	}
+	for _, entityType := range entityTypes {
+		if !bundledEntityTypes[entityType.EntityType] {
+			diff.EntityTypes = append(diff.EntityTypes, models.DetectionCatalogChange{Name: entityType.EntityType, Action: constants.DetectionCatalogChangeKept})
+		}
+	}
+
+	return diff, nil
+}
+
+// recordCatalogChange adds what an import does with an item of the bundle to a diff.
+func recordCatalogChange(diff *models.DetectionCatalogDiff, changes *[]models.DetectionCatalogChange, name string, exists bool, fields []models.DetectionCatalogFieldChange) {
+	change := models.DetectionCatalogChange{Name: name, Fields: fields}
+	switch {
+	case !exists:
+		change.Action = constants.DetectionCatalogChangeCreate
+		diff.Created++
+	case len(fields) > 0:
+		change.Action = constants.DetectionCatalogChangeUpdate
+		diff.Updated++
+	default:
+		change.Action = constants.DetectionCatalogChangeUnchanged
+		diff.Unchanged++
+	}
+	*changes = append(*changes, change)
+}
+
+// appendFieldChange adds a value to the changes of an update if the bundle changes it.
+func appendFieldChange(fields []models.DetectionCatalogFieldChange, field, from, to string) []models.DetectionCatalogFieldChange {
+	if from == to {
+		return fields
+	}
+	return append(fields, models.DetectionCatalogFieldChange{Field: field, From: from, To: to})
+}
@@ -0,0 +1,197 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/yasinhessnawi1/Hideme_Backend/internal/constants"
+	"github.com/yasinhessnawi1/Hideme_Backend/internal/models"
+	"github.com/yasinhessnawi1/Hideme_Backend/internal/testsupport/fakeclock"
+	"github.com/yasinhessnawi1/Hideme_Backend/internal/testsupport/fixtures"
+	"github.com/yasinhessnawi1/Hideme_Backend/internal/utils"
+)
+
+// memoryDetectionCatalogRepository holds a catalog in memory and records the bundles imported.
+type memoryDetectionCatalogRepository struct {
+	methods     []*models.DetectionMethod
+	entityTypes []*models.EntityType
+	imported    []*models.DetectionCatalogBundle
+}
+
+func (r *memoryDetectionCatalogRepository) ListDetectionMethods(ctx context.Context) ([]*models.DetectionMethod, error) {
+	return r.methods, nil
+}
+
+func (r *memoryDetectionCatalogRepository) ListEntityTypes(ctx context.Context) ([]*models.EntityType, error) {
+	return r.entityTypes, nil
+}
+
+func (r *memoryDetectionCatalogRepository) Import(ctx context.Context, bundle *models.DetectionCatalogBundle) error {
+	r.imported = append(r.imported, bundle)
+	return nil
+}
+
+func newStagingCatalog() *memoryDetectionCatalogRepository {
+	return &memoryDetectionCatalogRepository{
+		methods: []*models.DetectionMethod{
+			{ID: 1, MethodName: "Gliner", HighlightColor: "#F033FF"},
+			{ID: 2, MethodName: "Presidio", HighlightColor: "#33FF57"},
+		},
+		entityTypes: []*models.EntityType{
+			{ID: 5, EntityType: "PERSON", MethodID: 2, MethodName: "Presidio", Description: "Names of people"},
+		},
+	}
+}
+
+func TestDetectionCatalogService_Export(t *testing.T) {
+	svc := NewDetectionCatalogService(newStagingCatalog())
+	svc.SetClock(fakeclock.New(fixtures.Time))
+
+	bundle, err := svc.Export(context.Background())
+
+	require.NoError(t, err)
+	assert.Equal(t, &models.DetectionCatalogBundle{
+		Version:    constants.DetectionCatalogBundleVersion,
+		ExportedAt: fixtures.Time,
+		DetectionMethods: []models.DetectionCatalogMethod{
+			{MethodName: "Gliner", HighlightColor: "#F033FF"},
+			{MethodName: "Presidio", HighlightColor: "#33FF57"},
+		},
+		EntityTypes: []models.DetectionCatalogEntityType{
+			{EntityType: "PERSON", MethodName: "Presidio", Description: "Names of people"},
+		},
+	}, bundle)
+}
+
+func TestDetectionCatalogService_Import(t *testing.T) {
+	ctx := context.Background()
+	bundle := &models.DetectionCatalogBundle{
+		Version: constants.DetectionCatalogBundleVersion,
+		DetectionMethods: []models.DetectionCatalogMethod{
+			{MethodName: "Presidio", HighlightColor: "#00FF00"},
+			{MethodName: "HideMeModel", HighlightColor: "#33FF57"},
+		},
+		EntityTypes: []models.DetectionCatalogEntityType{
+			{EntityType: "PERSON", MethodName: "Presidio", Description: "Names of people"},
+			{EntityType: "NATIONAL_ID", MethodName: "HideMeModel", Description: "National identity numbers"},
+			{EntityType: "ORGANIZATION", MethodName: "Gliner"},
+		},
+	}
+
+	t.Run("Preview changes nothing", func(t *testing.T) {
+		repo := newStagingCatalog()
+		svc := NewDetectionCatalogService(repo)
+
+		diff, err := svc.Preview(ctx, bundle)
+
+		require.NoError(t, err)
+		assert.Empty(t, repo.imported)
+		assert.False(t, diff.Applied)
+		assert.Equal(t, 3, diff.Created)
+		assert.Equal(t, 1, diff.Updated)
+		assert.Equal(t, 1, diff.Unchanged)
+		assert.Equal(t, []models.DetectionCatalogChange{
+			{Name: "Presidio", Action: constants.DetectionCatalogChangeUpdate, Fields: []models.DetectionCatalogFieldChange{
+				{Field: "highlight_color", From: "#33FF57", To: "#00FF00"},
+			}},
+			{Name: "HideMeModel", Action: constants.DetectionCatalogChangeCreate},
+			// Methods only this environment has are kept
+			{Name: "Gliner", Action: constants.DetectionCatalogChangeKept},
+		}, diff.DetectionMethods)
+		assert.Equal(t, []models.DetectionCatalogChange{
+			{Name: "PERSON", Action: constants.DetectionCatalogChangeUnchanged},
+			{Name: "NATIONAL_ID", Action: constants.DetectionCatalogChangeCreate},
+			{Name: "ORGANIZATION", Action: constants.DetectionCatalogChangeCreate},
+		}, diff.EntityTypes)
+	})
+
+	t.Run("Import applies the bundle", func(t *testing.T) {
+		repo := newStagingCatalog()
+		svc := NewDetectionCatalogService(repo)
+
+		diff, err := svc.Import(ctx, 1, bundle)
+
+		require.NoError(t, err)
+		assert.True(t, diff.Applied)
+		assert.Equal(t, []*models.DetectionCatalogBundle{bundle}, repo.imported)
+	})
+
+	t.Run("Import without changes is a no-op", func(t *testing.T) {
+		repo := newStagingCatalog()
+		svc := NewDetectionCatalogService(repo)
+		exported, err := svc.Export(ctx)
+		require.NoError(t, err)
+
+		diff, err := svc.Import(ctx, 1, exported)
+
+		require.NoError(t, err)
+		assert.True(t, diff.Applied)
+		assert.False(t, diff.HasChanges())
+		assert.Equal(t, 3, diff.Unchanged)
+		assert.Empty(t, repo.imported)
+	})
+
+	t.Run("Invalid bundles", func(t *testing.T) {
+		tests := []struct {
+			name   string
+			bundle *models.DetectionCatalogBundle
+			field  string
+		}{
+			{
+				name:   "Unsupported version",
+				bundle: &models.DetectionCatalogBundle{Version: constants.DetectionCatalogBundleVersion + 1},
+				field:  "version",
+			},
+			{
+				name: "Duplicate method",
+				bundle: &models.DetectionCatalogBundle{
+					Version: constants.DetectionCatalogBundleVersion,
+					DetectionMethods: []models.DetectionCatalogMethod{
+						{MethodName: "Presidio", HighlightColor: "#33FF57"},
+						{MethodName: "Presidio", HighlightColor: "#00FF00"},
+					},
+				},
+				field: "detection_methods[1].method_name",
+			},
+			{
+				name: "Duplicate entity type",
+				bundle: &models.DetectionCatalogBundle{
+					Version: constants.DetectionCatalogBundleVersion,
+					EntityTypes: []models.DetectionCatalogEntityType{
+						{EntityType: "PERSON", MethodName: "Presidio"},
+						{EntityType: "PERSON", MethodName: "Gliner"},
+					},
+				},
+				field: "entity_types[1].entity_type",
+			},
+			{
+				name: "Unknown method",
+				bundle: &models.DetectionCatalogBundle{
+					Version: constants.DetectionCatalogBundleVersion,
+					EntityTypes: []models.DetectionCatalogEntityType{
+						{EntityType: "PERSON", MethodName: "Gemini"},
+					},
+				},
+				field: "entity_types[0].method_name",
+			},
+		}
+
+		for _, tt := range tests {
+			t.Run(tt.name, func(t *testing.T) {
+				repo := newStagingCatalog()
+				svc := NewDetectionCatalogService(repo)
+
+				_, err := svc.Import(ctx, 1, tt.bundle)
+
+				var appErr *utils.AppError
+				require.True(t, errors.As(err, &appErr))
+				assert.Equal(t, tt.field, appErr.Field)
+				assert.Empty(t, repo.imported)
+			})
+		}
+	})
+}
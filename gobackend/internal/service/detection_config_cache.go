@@ -0,0 +1,184 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/yasinhessnawi1/Hideme_Backend/internal/models"
+	"github.com/yasinhessnawi1/Hideme_Backend/internal/utils/clock"
+)
+
+// errDetectionConfigLoadAborted is returned to callers waiting for a load that panicked.
+var errDetectionConfigLoadAborted = errors.New("detection configuration load aborted")
+
+// DetectionConfigLoader composes the detection configuration of a user from the database.
+type DetectionConfigLoader func(ctx context.Context, userID int64) (*models.DetectionConfig, error)
+
+// cachedDetectionConfig is a composed detection configuration kept in the cache.
+type cachedDetectionConfig struct {
+	config    *models.DetectionConfig
+	expiresAt time.Time
+}
+
+// detectionConfigLoad is a composition in progress, shared by all requests for the user
+// that arrive while it runs.
+type detectionConfigLoad struct {
+	done   chan struct{}
+	config *models.DetectionConfig
+	err    error
+}
+
+// DetectionConfigCache keeps the composed detection configuration of each user in memory.
+// Batch processing sends many detection requests for one user at once; the first request
+// composes the configuration and the others wait for it instead of repeating the same
+// settings queries, and requests within the TTL are served without touching the database.
+// Settings changes invalidate the user's entry, so the TTL only bounds how long changes
+// made by another instance go unnoticed.
+type DetectionConfigCache struct {
+	ttl   time.Duration
+	clock clock.Clock
+
+	mu        sync.Mutex
+	entries   map[int64]cachedDetectionConfig
+	loads     map[int64]*detectionConfigLoad
+	nextSweep time.Time
+
+	hits        atomic.Int64
+	misses      atomic.Int64
+	sharedLoads atomic.Int64
+}
+
+// NewDetectionConfigCache creates an empty cache.
+//
+// Parameters:
+//   - ttl: How long a composed configuration is served from the cache
+//
+// Returns:
+//   - A properly initialized DetectionConfigCache
+func NewDetectionConfigCache(ttl time.Duration) *DetectionConfigCache {
+	return &DetectionConfigCache{
+		ttl:     ttl,
+		entries: make(map[int64]cachedDetectionConfig),
+		loads:   make(map[int64]*detectionConfigLoad),
+	}
+}
+
+// SetClock sets the clock cached configurations expire by.
+func (c *DetectionConfigCache) SetClock(clk clock.Clock) {
+	c.clock = clk
+}
+
+// now returns the current time of the cache's clock.
+func (c *DetectionConfigCache) now() time.Time {
+	return clock.Or(c.clock).Now()
+}
+
+// Get returns the detection configuration of a user, from the cache if it is fresh.
+// Otherwise load composes it; concurrent callers for the same user share one load.
+// The load runs detached from the caller's cancellation, so a client going away does
+// not fail the other callers waiting for it.
+//
+// Parameters:
+//   - ctx: Context for the operation
+//   - userID: The ID of the user whose configuration to return
+//   - load: Composes the configuration when it is not cached
+//
+// Returns:
+//   - The configuration; it is shared between callers and must not be modified
+//   - Whether it was served from the cache or another caller's load
+//   - An error if the load fails or ctx ends while waiting; failures are not cached
+func (c *DetectionConfigCache) Get(ctx context.Context, userID int64, load DetectionConfigLoader) (*models.DetectionConfig, bool, error) {
+	c.mu.Lock()
+	if entry, ok := c.entries[userID]; ok && c.now().Before(entry.expiresAt) {
+		c.mu.Unlock()
+		c.hits.Add(1)
+		return entry.config, true, nil
+	}
+
+	if running, ok := c.loads[userID]; ok {
+		c.mu.Unlock()
+		c.sharedLoads.Add(1)
+		select {
+		case <-running.done:
+			return running.config, running.err == nil, running.err
+		case <-ctx.Done():
+			return nil, false, ctx.Err()
+		}
+	}
+
+	// Until the load completes, waiting callers see it as failed, should it panic
+	running := &detectionConfigLoad{done: make(chan struct{}), err: errDetectionConfigLoadAborted}
+	c.loads[userID] = running
+	c.mu.Unlock()
+	c.misses.Add(1)
+
+	defer func() {
+		c.mu.Lock()
+		// A load that was invalidated while it ran may predate the change, so its result
+		// is handed to the waiting callers but not kept
+		if c.loads[userID] == running {
+			delete(c.loads, userID)
+			if running.err == nil {
+				c.store(userID, running.config)
+			}
+		}
+		c.mu.Unlock()
+		close(running.done)
+	}()
+
+	config, err := load(context.WithoutCancel(ctx), userID)
+	running.config, running.err = config, err
+
+	return config, false, err
+}
+
+// store caches the configuration of a user, first dropping expired entries at most
+// once per TTL so users who stopped sending requests do not stay in memory.
+// The caller must hold the lock.
+func (c *DetectionConfigCache) store(userID int64, config *models.DetectionConfig) {
+	now := c.now()
+	if now.After(c.nextSweep) {
+		for id, entry := range c.entries {
+			if !now.Before(entry.expiresAt) {
+				delete(c.entries, id)
+			}
+		}
+		c.nextSweep = now.Add(c.ttl)
+	}
+	c.entries[userID] = cachedDetectionConfig{config: config, expiresAt: now.Add(c.ttl)}
+}
+
+// Invalidate drops the cached configuration of a user after their settings changed.
+// Requests arriving afterwards compose it again rather than joining a load that
+// started before the change.
+//
+// Parameters:
+//   - userID: The ID of the user whose settings changed
+func (c *DetectionConfigCache) Invalidate(userID int64) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	delete(c.entries, userID)
+	delete(c.loads, userID)
+}
+
+// Stats returns the number of cached users and the hits and misses since startup.
+//
+// Returns:
+//   - The cache statistics
+func (c *DetectionConfigCache) Stats() models.CacheStats {
+	c.mu.Lock()
+	entries := len(c.entries)
+	c.mu.Unlock()
+
+	return models.CacheStats{
+		Name:        "detection_config",
+		Entries:     entries,
+		Hits:        c.hits.Load(),
+		Misses:      c.misses.Load(),
+		SharedLoads: c.sharedLoads.Load(),
+	}
+}
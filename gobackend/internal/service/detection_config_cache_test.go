@@ -0,0 +1,149 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/yasinhessnawi1/Hideme_Backend/internal/models"
+	"github.com/yasinhessnawi1/Hideme_Backend/internal/testsupport/fakeclock"
+)
+
+func TestDetectionConfigCache_SharesConcurrentLoads(t *testing.T) {
+	cache := NewDetectionConfigCache(time.Minute)
+	release := make(chan struct{})
+	var loads atomic.Int64
+	load := func(ctx context.Context, userID int64) (*models.DetectionConfig, error) {
+		loads.Add(1)
+		<-release
+		return &models.DetectionConfig{UserID: userID}, nil
+	}
+
+	// Start a batch of requests for one user while the first load is blocked
+	const batchSize = 20
+	var wg sync.WaitGroup
+	results := make([]*models.DetectionConfig, batchSize)
+	errs := make([]error, batchSize)
+	for i := 0; i < batchSize; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			results[i], _, errs[i] = cache.Get(context.Background(), 7, load)
+		}(i)
+	}
+
+	// Let the waiting requests join the load before it completes
+	deadline := time.Now().Add(time.Second)
+	for cache.Stats().SharedLoads+cache.Stats().Misses < batchSize && time.Now().Before(deadline) {
+		time.Sleep(time.Millisecond)
+	}
+	close(release)
+	wg.Wait()
+
+	if loads.Load() != 1 {
+		t.Errorf("Expected one load for the batch, got %d", loads.Load())
+	}
+	for i := range results {
+		if errs[i] != nil || results[i] == nil || results[i].UserID != 7 {
+			t.Fatalf("Unexpected result %d: %+v, %v", i, results[i], errs[i])
+		}
+	}
+
+	// Later requests are served from the cache
+	if _, cached, err := cache.Get(context.Background(), 7, load); err != nil || !cached {
+		t.Errorf("Expected a cache hit, got cached=%v err=%v", cached, err)
+	}
+
+	stats := cache.Stats()
+	if stats.Misses != 1 || stats.SharedLoads != batchSize-1 || stats.Hits != 1 || stats.Entries != 1 {
+		t.Errorf("Unexpected stats: %+v", stats)
+	}
+}
+
+func TestDetectionConfigCache_Expiry(t *testing.T) {
+	cache := NewDetectionConfigCache(time.Minute)
+	now := time.Date(2026, 3, 1, 12, 0, 0, 0, time.UTC)
+	clk := fakeclock.New(now)
+	cache.SetClock(clk)
+	var loads int
+	load := func(ctx context.Context, userID int64) (*models.DetectionConfig, error) {
+		loads++
+		return &models.DetectionConfig{UserID: userID}, nil
+	}
+
+	cache.Get(context.Background(), 1, load)
+	cache.Get(context.Background(), 1, load)
+	if loads != 1 {
+		t.Errorf("Expected the second request within the TTL to be cached, got %d loads", loads)
+	}
+
+	now = clk.Advance(2 * time.Minute)
+	if _, cached, _ := cache.Get(context.Background(), 1, load); cached || loads != 2 {
+		t.Errorf("Expected an expired entry to be loaded again, got cached=%v and %d loads", cached, loads)
+	}
+}
+
+func TestDetectionConfigCache_Invalidate(t *testing.T) {
+	cache := NewDetectionConfigCache(time.Minute)
+	var loads int
+	load := func(ctx context.Context, userID int64) (*models.DetectionConfig, error) {
+		loads++
+		return &models.DetectionConfig{UserID: userID}, nil
+	}
+
+	cache.Get(context.Background(), 1, load)
+	cache.Invalidate(1)
+	if _, cached, _ := cache.Get(context.Background(), 1, load); cached || loads != 2 {
+		t.Errorf("Expected an invalidated entry to be loaded again, got cached=%v and %d loads", cached, loads)
+	}
+
+	t.Run("During a load", func(t *testing.T) {
+		cache := NewDetectionConfigCache(time.Minute)
+		stale := func(ctx context.Context, userID int64) (*models.DetectionConfig, error) {
+			// The settings change while they are being read
+			cache.Invalidate(userID)
+			return &models.DetectionConfig{UserID: userID}, nil
+		}
+
+		if _, _, err := cache.Get(context.Background(), 1, stale); err != nil {
+			t.Fatalf("Get returned error: %v", err)
+		}
+		if cache.Stats().Entries != 0 {
+			t.Error("Expected a load invalidated while running not to be cached")
+		}
+	})
+}
+
+func TestDetectionConfigCache_Errors(t *testing.T) {
+	cache := NewDetectionConfigCache(time.Minute)
+	failing := func(ctx context.Context, userID int64) (*models.DetectionConfig, error) {
+		return nil, errors.New("database unavailable")
+	}
+
+	if _, _, err := cache.Get(context.Background(), 1, failing); err == nil {
+		t.Fatal("Expected the load error to be returned")
+	}
+	if cache.Stats().Entries != 0 {
+		t.Error("Expected failures not to be cached")
+	}
+
+	t.Run("Panicking load", func(t *testing.T) {
+		func() {
+			defer func() { recover() }()
+			cache.Get(context.Background(), 2, func(ctx context.Context, userID int64) (*models.DetectionConfig, error) {
+				panic("boom")
+			})
+		}()
+
+		// The user is not stuck behind the aborted load
+		config, _, err := cache.Get(context.Background(), 2, func(ctx context.Context, userID int64) (*models.DetectionConfig, error) {
+			return &models.DetectionConfig{UserID: userID}, nil
+		})
+		if err != nil || config == nil {
+			t.Errorf("Expected a new load after a panic, got %+v, %v", config, err)
+		}
+	})
+}
@@ -0,0 +1,183 @@
+// Package service provides business logic implementations.
+//
+// This file implements the reading of stored document content encrypted in segments: a
+// download reads the segments it needs from the document storage as it goes, and holds
+// one of them in memory at a time.
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+
+	"github.com/yasinhessnawi1/Hideme_Backend/internal/models"
+	"github.com/yasinhessnawi1/Hideme_Backend/internal/utils"
+)
+
+// segmentedContentReader reads the decrypted content of a document encrypted in segments.
+// Reads continue the range opened in the storage while they are sequential; a seek away
+// from it opens a new range at the segment sought to.
+type segmentedContentReader struct {
+	ctx         context.Context
+	store       DocumentStore
+	key         string
+	dataKey     []byte
+	header      []byte
+	size        int64
+	segmentSize int64
+	segments    int64
+
+	// offset is the position in the decrypted content of the next read
+	offset int64
+
+	// body is the open range of the storage; it continues with segment next
+	body io.ReadCloser
+	next int64
+
+	// segment is the last decrypted segment, which starts at segmentStart
+	segment      []byte
+	segmentStart int64
+	sealed       []byte
+}
+
+// newSegmentedContentReader opens the stored content of a document encrypted in segments,
+// reading its header.
+//
+// Parameters:
+//   - ctx: Context whose cancellation stops the reading
+//   - store: The document storage
+//   - stored: The stored content record
+//   - dataKey: The data-encryption key of the document's owner
+//
+// Returns:
+//   - The reader, to be closed by the caller
+//   - An error if the header can't be read
+func newSegmentedContentReader(ctx context.Context, store DocumentStore, stored *models.DocumentContent, dataKey []byte) (*segmentedContentReader, error) {
+	body, err := store.GetRange(ctx, stored.StorageKey, 0, utils.SegmentHeaderSize)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read document content: %w", err)
+	}
+	defer body.Close()
+
+	header := make([]byte, utils.SegmentHeaderSize)
+	if _, err := io.ReadFull(body, header); err != nil {
+		return nil, fmt.Errorf("failed to read document content header: %w", err)
+	}
+
+	return &segmentedContentReader{
+		ctx:          ctx,
+		store:        store,
+		key:          stored.StorageKey,
+		dataKey:      dataKey,
+		header:       header,
+		size:         stored.SizeBytes,
+		segmentSize:  int64(stored.SegmentSize),
+		segments:     utils.SegmentCount(stored.SizeBytes, stored.SegmentSize),
+		segmentStart: -1,
+	}, nil
+}
+
+// Read reads decrypted content from the current position.
+func (r *segmentedContentReader) Read(p []byte) (int, error) {
+	if r.offset >= r.size {
+		return 0, io.EOF
+	}
+
+	if r.segmentStart < 0 || r.offset < r.segmentStart || r.offset >= r.segmentStart+int64(len(r.segment)) {
+		if err := r.readSegment(r.offset / r.segmentSize); err != nil {
+			return 0, err
+		}
+	}
+
+	n := copy(p, r.segment[r.offset-r.segmentStart:])
+	r.offset += int64(n)
+	return n, nil
+}
+
+// readSegment reads and decrypts a segment, opening a range of the storage at it unless
+// the open one continues with it.
+func (r *segmentedContentReader) readSegment(index int64) error {
+	sealedSize := r.segmentSize + utils.SegmentOverhead
+	if r.body == nil || r.next != index {
+		r.closeBody()
+		start := utils.SegmentHeaderSize + index*sealedSize
+		end := utils.SegmentHeaderSize + r.size + r.segments*utils.SegmentOverhead
+		body, err := r.store.GetRange(r.ctx, r.key, start, end-start)
+		if err != nil {
+			return fmt.Errorf("failed to read document content: %w", err)
+		}
+		r.body = body
+		r.next = index
+	}
+
+	last := index == r.segments-1
+	n := sealedSize
+	if last {
+		n = r.size - index*r.segmentSize + utils.SegmentOverhead
+	}
+	if int64(cap(r.sealed)) < n {
+		r.sealed = make([]byte, sealedSize)
+	}
+	r.sealed = r.sealed[:n]
+	if _, err := io.ReadFull(r.body, r.sealed); err != nil {
+		r.closeBody()
+		if errors.Is(err, io.EOF) || errors.Is(err, io.ErrUnexpectedEOF) {
+			return fmt.Errorf("document content is shorter than recorded: %w", io.ErrUnexpectedEOF)
+		}
+		return fmt.Errorf("failed to read document content: %w", err)
+	}
+
+	segment, err := utils.DecryptSegment(r.header, r.sealed, index, last, r.dataKey)
+	if err != nil {
+		r.closeBody()
+		return fmt.Errorf("failed to decrypt document content: %w", err)
+	}
+	r.segment = segment
+	r.segmentStart = index * r.segmentSize
+	r.next = index + 1
+	return nil
+}
+
+// Seek sets the position of the next read. The storage is not read until then.
+func (r *segmentedContentReader) Seek(offset int64, whence int) (int64, error) {
+	switch whence {
+	case io.SeekStart:
+	case io.SeekCurrent:
+		offset += r.offset
+	case io.SeekEnd:
+		offset += r.size
+	default:
+		return 0, errors.New("invalid whence")
+	}
+	if offset < 0 {
+		return 0, errors.New("negative position")
+	}
+	r.offset = offset
+	return offset, nil
+}
+
+// Close closes the open range of the storage.
+func (r *segmentedContentReader) Close() error {
+	r.closeBody()
+	return nil
+}
+
+// closeBody closes the open range of the storage, if any.
+func (r *segmentedContentReader) closeBody() {
+	if r.body != nil {
+		r.body.Close()
+		r.body = nil
+	}
+}
+
+// bufferedContentReader reads content decrypted as a whole, stored before content was
+// encrypted in segments.
+type bufferedContentReader struct {
+	io.ReadSeeker
+}
+
+// Close does nothing; the content is in memory.
+func (bufferedContentReader) Close() error {
+	return nil
+}
@@ -0,0 +1,177 @@
+// Package service provides business logic implementations.
+//
+// This file implements the cleanup of stale documents: documents uploaded but never
+// processed, or whose detections have awaited review for a long time. Their owners are
+// emailed first, and the documents are archived or deleted once the notice has passed
+// without anybody working on them.
+package service
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/rs/zerolog/log"
+
+	"github.com/yasinhessnawi1/Hideme_Backend/internal/config"
+	"github.com/yasinhessnawi1/Hideme_Backend/internal/constants"
+	"github.com/yasinhessnawi1/Hideme_Backend/internal/models"
+	"github.com/yasinhessnawi1/Hideme_Backend/internal/repository"
+	"github.com/yasinhessnawi1/Hideme_Backend/internal/utils"
+)
+
+// StaleDocumentNotifier tells owners that their stale documents will be cleaned up.
+type StaleDocumentNotifier interface {
+	SendStaleDocumentsEmail(toEmail, toName, action string, cleanupAt time.Time, documents []*models.StaleDocument) error
+}
+
+// SetStaleDocuments enables cleaning up stale documents after emailing their owners.
+// Without it, unfinished documents are kept until their owners delete them.
+//
+// Parameters:
+//   - userRepo: Repository used to look up the email addresses of the owners
+//   - notifier: The notifier telling the owners about their stale documents
+//   - settings: When documents are stale and what is done with them
+func (s *DocumentService) SetStaleDocuments(userRepo repository.UserRepository, notifier StaleDocumentNotifier, settings *config.StaleDocumentSettings) {
+	s.staleUsers = userRepo
+	s.staleNotices = notifier
+	s.stale = settings
+}
+
+// CleanUpStaleDocuments archives or deletes a batch of the stale documents whose owners were
+// told about them longer than the notice period ago, then tells the owners of a batch of
+// newly stale documents. It runs as a periodic maintenance task.
+//
+// Returns:
+//   - The number of documents cleaned up or notified about
+//   - An error if the stale documents could not be listed or handled
+func (s *DocumentService) CleanUpStaleDocuments(ctx context.Context) (int64, error) {
+	if s.stale == nil || s.stale.AfterDays <= 0 || s.staleNotices == nil {
+		return 0, nil
+	}
+
+	// Documents noticed in this run wait for the whole notice period
+	now := s.now()
+	cleaned, err := s.cleanUpDueStaleDocuments(ctx, now)
+	if err != nil {
+		return cleaned, err
+	}
+
+	notified, err := s.notifyStaleDocuments(ctx, now)
+	return cleaned + notified, err
+}
+
+// cleanUpDueStaleDocuments archives or deletes the stale documents whose notice has passed.
+func (s *DocumentService) cleanUpDueStaleDocuments(ctx context.Context, now time.Time) (int64, error) {
+	due, err := s.docRepo.ListStaleDue(ctx, now.AddDate(0, 0, -s.stale.NoticeDays), constants.StaleDocumentBatchSize)
+	if err != nil || len(due) == 0 {
+		return 0, err
+	}
+
+	if s.stale.Action != constants.StaleDocumentActionDelete {
+		if s.archiveRepo == nil {
+			return 0, nil
+		}
+		ids := make([]int64, len(due))
+		for i, document := range due {
+			ids[i] = document.DocumentID
+		}
+		return s.archiveRepo.ArchiveDocuments(ctx, ids, now)
+	}
+
+	var deleted int64
+	for _, document := range due {
+		if err := s.docRepo.Delete(ctx, document.DocumentID); err != nil {
+			// The owner deleted it in the meantime
+			if errors.Is(err, utils.ErrNotFound) {
+				continue
+			}
+			return deleted, err
+		}
+		deleted++
+	}
+
+	log.Info().
+		Int64("deleted", deleted).
+		Msg("Stale documents deleted")
+
+	return deleted, nil
+}
+
+// notifyStaleDocuments emails the owners of newly stale documents and records that they were
+// told. Owners who could not be emailed are told at the next run.
+func (s *DocumentService) notifyStaleDocuments(ctx context.Context, now time.Time) (int64, error) {
+	stale, err := s.docRepo.ListStale(ctx, now.AddDate(0, 0, -s.stale.AfterDays), constants.StaleDocumentBatchSize)
+	if err != nil || len(stale) == 0 {
+		return 0, err
+	}
+
+	// Each owner gets one email about all of their stale documents
+	var owners []int64
+	byOwner := make(map[int64][]*models.StaleDocument)
+	for _, document := range stale {
+		if _, ok := byOwner[document.UserID]; !ok {
+			owners = append(owners, document.UserID)
+		}
+		byOwner[document.UserID] = append(byOwner[document.UserID], document)
+	}
+
+	action := s.stale.Action
+	if action != constants.StaleDocumentActionDelete {
+		action = constants.StaleDocumentActionArchive
+	}
+	cleanupAt := now.AddDate(0, 0, s.stale.NoticeDays)
+
+	var notified []int64
+	for _, userID := range owners {
+		user, err := s.staleUsers.GetByID(ctx, userID)
+		if err != nil {
+			log.Warn().Err(err).Int64("user_id", userID).Msg("Failed to look up owner of stale documents")
+			continue
+		}
+		if err := s.staleNotices.SendStaleDocumentsEmail(user.Email, user.Username, action, cleanupAt, byOwner[userID]); err != nil {
+			log.Warn().Err(err).Int64("user_id", userID).Msg("Failed to tell owner about stale documents")
+			continue
+		}
+		for _, document := range byOwner[userID] {
+			notified = append(notified, document.DocumentID)
+		}
+	}
+	if len(notified) == 0 {
+		return 0, nil
+	}
+
+	if err := s.docRepo.MarkStaleNotified(ctx, notified, now); err != nil {
+		return 0, err
+	}
+	return int64(len(notified)), nil
+}
+
+// SetLifecycleExempt exempts a document owned by a user from stale document cleanup, or makes
+// it subject to it again. Either way, a notice already sent about the document no longer
+// counts, so it is only cleaned up after a new one.
+//
+// Parameters:
+//   - ctx: Context for the operation
+//   - userID: The ID of the user changing the exemption
+//   - documentID: The ID of the document
+//   - exempt: Whether the document is kept however long it stays unfinished
+//
+// Returns:
+//   - The exemption of the document
+//   - ErrDocumentNotFound if the document doesn't exist or belongs to another user
+//   - Other errors for database issues
+func (s *DocumentService) SetLifecycleExempt(ctx context.Context, userID, documentID int64, exempt bool) (*models.DocumentLifecycle, error) {
+	if _, err := s.authorizedDocument(ctx, userID, documentID, ""); err != nil {
+		return nil, err
+	}
+
+	if err := s.docRepo.SetLifecycleExempt(ctx, documentID, exempt); err != nil {
+		if errors.Is(err, utils.ErrNotFound) {
+			return nil, ErrDocumentNotFound
+		}
+		return nil, err
+	}
+
+	return &models.DocumentLifecycle{DocumentID: documentID, Exempt: exempt}, nil
+}
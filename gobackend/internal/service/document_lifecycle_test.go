@@ -0,0 +1,191 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/yasinhessnawi1/Hideme_Backend/internal/config"
+	"github.com/yasinhessnawi1/Hideme_Backend/internal/constants"
+	"github.com/yasinhessnawi1/Hideme_Backend/internal/models"
+	"github.com/yasinhessnawi1/Hideme_Backend/internal/repository"
+	"github.com/yasinhessnawi1/Hideme_Backend/internal/testsupport/fakeclock"
+	"github.com/yasinhessnawi1/Hideme_Backend/internal/testsupport/fixtures"
+	"github.com/yasinhessnawi1/Hideme_Backend/internal/utils"
+)
+
+// staleDocumentRepository serves fixed stale documents and records what was done with them;
+// any other call panics
+type staleDocumentRepository struct {
+	repository.DocumentRepository
+	docs           map[int64]*models.Document
+	stale          []*models.StaleDocument
+	due            []*models.StaleDocument
+	inactiveBefore time.Time
+	notifiedBefore time.Time
+	notified       []int64
+	deleted        []int64
+	exempt         map[int64]bool
+}
+
+func (r *staleDocumentRepository) ListStale(ctx context.Context, inactiveBefore time.Time, limit int) ([]*models.StaleDocument, error) {
+	r.inactiveBefore = inactiveBefore
+	return r.stale, nil
+}
+
+func (r *staleDocumentRepository) ListStaleDue(ctx context.Context, notifiedBefore time.Time, limit int) ([]*models.StaleDocument, error) {
+	r.notifiedBefore = notifiedBefore
+	return r.due, nil
+}
+
+func (r *staleDocumentRepository) MarkStaleNotified(ctx context.Context, documentIDs []int64, at time.Time) error {
+	r.notified = append(r.notified, documentIDs...)
+	return nil
+}
+
+func (r *staleDocumentRepository) Delete(ctx context.Context, id int64) error {
+	if _, ok := r.docs[id]; !ok {
+		return utils.NewNotFoundError("Document", id)
+	}
+	delete(r.docs, id)
+	r.deleted = append(r.deleted, id)
+	return nil
+}
+
+func (r *staleDocumentRepository) GetByID(ctx context.Context, id int64) (*models.Document, error) {
+	doc, ok := r.docs[id]
+	if !ok {
+		return nil, utils.NewNotFoundError("Document", id)
+	}
+	return doc, nil
+}
+
+func (r *staleDocumentRepository) SetLifecycleExempt(ctx context.Context, documentID int64, exempt bool) error {
+	r.exempt[documentID] = exempt
+	return nil
+}
+
+// recordingStaleNotifier records the stale documents each owner was told about
+type recordingStaleNotifier struct {
+	failFor   string
+	emails    map[string][]int64
+	action    string
+	cleanupAt time.Time
+}
+
+func (n *recordingStaleNotifier) SendStaleDocumentsEmail(toEmail, toName, action string, cleanupAt time.Time, documents []*models.StaleDocument) error {
+	if toEmail == n.failFor {
+		return errors.New("provider unavailable")
+	}
+	for _, document := range documents {
+		n.emails[toEmail] = append(n.emails[toEmail], document.DocumentID)
+	}
+	n.action = action
+	n.cleanupAt = cleanupAt
+	return nil
+}
+
+func TestDocumentService_CleanUpStaleDocuments(t *testing.T) {
+	ctx := context.Background()
+	users := NewMockUserRepository()
+	require.NoError(t, users.Create(ctx, &models.User{Username: "ola", Email: "ola@example.com"}))
+	require.NoError(t, users.Create(ctx, &models.User{Username: "kari", Email: "kari@example.com"}))
+
+	newService := func() (*DocumentService, *staleDocumentRepository, *recordingStaleNotifier) {
+		docs := &staleDocumentRepository{docs: map[int64]*models.Document{
+			4: {ID: 4, UserID: 1},
+			5: {ID: 5, UserID: 2},
+		}}
+		svc := NewDocumentService(docs, &MockProcessingAuditRepository{}, nil)
+		notifier := &recordingStaleNotifier{emails: make(map[string][]int64)}
+		return svc, docs, notifier
+	}
+
+	t.Run("Disabled without settings", func(t *testing.T) {
+		svc, docs, _ := newService()
+		docs.stale = []*models.StaleDocument{{DocumentID: 4, UserID: 1}}
+
+		count, err := svc.CleanUpStaleDocuments(ctx)
+
+		require.NoError(t, err)
+		assert.Zero(t, count)
+	})
+
+	t.Run("Owners are told once about all their documents", func(t *testing.T) {
+		svc, docs, notifier := newService()
+		svc.SetStaleDocuments(users, notifier, &config.StaleDocumentSettings{AfterDays: 30, NoticeDays: 7, Action: constants.StaleDocumentActionArchive})
+		docs.stale = []*models.StaleDocument{
+			{DocumentID: 4, UserID: 1, Reason: constants.StaleReasonUnprocessed},
+			{DocumentID: 5, UserID: 2, Reason: constants.StaleReasonPendingReview},
+			{DocumentID: 6, UserID: 1, Reason: constants.StaleReasonPendingReview},
+			{DocumentID: 7, UserID: 99, Reason: constants.StaleReasonUnprocessed},
+		}
+		notifier.failFor = "kari@example.com"
+		svc.SetClock(fakeclock.New(fixtures.Time))
+
+		count, err := svc.CleanUpStaleDocuments(ctx)
+
+		require.NoError(t, err)
+		assert.Equal(t, int64(2), count)
+		assert.Equal(t, map[string][]int64{"ola@example.com": {4, 6}}, notifier.emails)
+		assert.Equal(t, constants.StaleDocumentActionArchive, notifier.action)
+		assert.Equal(t, fixtures.Time.AddDate(0, 0, 7), notifier.cleanupAt)
+		assert.Equal(t, fixtures.Time.AddDate(0, 0, -30), docs.inactiveBefore)
+
+		// Owners who could not be told, or no longer exist, are not counted as told
+		assert.Equal(t, []int64{4, 6}, docs.notified)
+	})
+
+	t.Run("Archive after the notice", func(t *testing.T) {
+		svc, docs, notifier := newService()
+		archive := &MockDocumentArchiveRepository{}
+		svc.SetArchive(archive, &config.DocumentArchiveSettings{AfterDays: 365})
+		svc.SetStaleDocuments(users, notifier, &config.StaleDocumentSettings{AfterDays: 30, NoticeDays: 7, Action: constants.StaleDocumentActionArchive})
+		docs.due = []*models.StaleDocument{{DocumentID: 4, UserID: 1}, {DocumentID: 5, UserID: 2}}
+
+		before := time.Now()
+		count, err := svc.CleanUpStaleDocuments(ctx)
+
+		require.NoError(t, err)
+		assert.Equal(t, int64(2), count)
+		assert.Equal(t, []int64{4, 5}, archive.archived)
+		assert.Empty(t, docs.deleted)
+		assert.WithinDuration(t, before.AddDate(0, 0, -7), docs.notifiedBefore, time.Minute)
+	})
+
+	t.Run("Delete after the notice", func(t *testing.T) {
+		svc, docs, notifier := newService()
+		svc.SetStaleDocuments(users, notifier, &config.StaleDocumentSettings{AfterDays: 30, NoticeDays: 7, Action: constants.StaleDocumentActionDelete})
+		// Document 9 was deleted by its owner in the meantime
+		docs.due = []*models.StaleDocument{{DocumentID: 4, UserID: 1}, {DocumentID: 9, UserID: 1}, {DocumentID: 5, UserID: 2}}
+
+		count, err := svc.CleanUpStaleDocuments(ctx)
+
+		require.NoError(t, err)
+		assert.Equal(t, int64(2), count)
+		assert.Equal(t, []int64{4, 5}, docs.deleted)
+	})
+}
+
+func TestDocumentService_SetLifecycleExempt(t *testing.T) {
+	ctx := context.Background()
+	docs := &staleDocumentRepository{
+		docs:   map[int64]*models.Document{4: {ID: 4, UserID: 1}},
+		exempt: make(map[int64]bool),
+	}
+	svc := NewDocumentService(docs, &MockProcessingAuditRepository{}, nil)
+
+	lifecycle, err := svc.SetLifecycleExempt(ctx, 1, 4, true)
+	require.NoError(t, err)
+	assert.Equal(t, &models.DocumentLifecycle{DocumentID: 4, Exempt: true}, lifecycle)
+	assert.Equal(t, map[int64]bool{4: true}, docs.exempt)
+
+	// Only the owner may exempt a document
+	_, err = svc.SetLifecycleExempt(ctx, 2, 4, false)
+	assert.True(t, errors.Is(err, ErrDocumentNotFound))
+	assert.True(t, docs.exempt[4])
+}
@@ -1,63 +1,457 @@
 package service
 
 import (
+	"bytes"
 	"context"
+	"crypto/sha256"
+	"encoding/hex"
 	"encoding/json"
 	"fmt"
 	"github.com/yasinhessnawi1/Hideme_Backend/internal/utils"
+	"io"
 	"os"
+	"strings"
+	"time"
 
 	"errors"
 	"github.com/rs/zerolog/log"
+	"github.com/yasinhessnawi1/Hideme_Backend/internal/config"
+	"github.com/yasinhessnawi1/Hideme_Backend/internal/constants"
 	"github.com/yasinhessnawi1/Hideme_Backend/internal/models"
+	"github.com/yasinhessnawi1/Hideme_Backend/internal/redaction"
 	"github.com/yasinhessnawi1/Hideme_Backend/internal/repository"
+	"github.com/yasinhessnawi1/Hideme_Backend/internal/utils/clock"
 )
 
 // Define custom error types
 var (
-	ErrDocumentNotFound  = errors.New("document not found")
-	ErrInvalidDocumentID = errors.New("invalid document ID")
+	ErrDocumentNotFound  = utils.NewKindError(utils.ErrNotFound, "document not found")
+	ErrInvalidDocumentID = utils.NewKindError(utils.ErrBadRequest, "invalid document ID")
+
+	// ErrDocumentArchived is returned when the content of a document in cold storage is read
+	ErrDocumentArchived = utils.New(utils.ErrBadRequest, constants.StatusConflict, constants.MsgDocumentArchived)
+
+	// ErrDocumentNotArchived is returned when a document in hot storage is restored
+	ErrDocumentNotArchived = utils.New(utils.ErrBadRequest, constants.StatusConflict, constants.MsgDocumentNotArchived)
+
+	// ErrDocumentContentNotFound is returned when the content of a document uploaded without it is read
+	ErrDocumentContentNotFound = utils.New(utils.ErrNotFound, constants.StatusNotFound, constants.MsgDocumentContentNotFound)
+
+	// ErrDocumentNotRedactable is returned when the PDF of a document cannot be parsed for redaction
+	ErrDocumentNotRedactable = utils.New(utils.ErrBadRequest, constants.StatusBadRequest, constants.MsgDocumentNotRedactable)
+
+	// ErrDocumentReadOnly is returned when a user with read access changes a shared document
+	ErrDocumentReadOnly = utils.NewForbiddenError(constants.MsgDocumentReadOnly)
 )
 
+// DocumentClassifier decides the tags, folder and retention of an uploaded document.
+type DocumentClassifier interface {
+	Classify(ctx context.Context, userID int64, filename, source string, redactionSchema models.RedactionMapping) (*models.DocumentClassification, error)
+}
+
+// DocumentDeliverer delivers saved documents to the user's export destinations.
+type DocumentDeliverer interface {
+	DeliverDocument(userID, documentID int64)
+}
+
+// DocumentRuleTracker counts the detections of saved documents towards the user's ban list
+// words and search patterns.
+type DocumentRuleTracker interface {
+	RecordDetections(ctx context.Context, userID int64, redactionSchema models.RedactionMapping)
+}
+
+// DocumentEntityIndexer stores salted hashes of the values detected in saved documents for
+// analytics.
+type DocumentEntityIndexer interface {
+	IndexEntities(ctx context.Context, userID, documentID int64, redactionSchema models.RedactionMapping)
+}
+
+// DocumentFilenameScrubber removes the user's ban list words and search pattern matches
+// from the filenames of uploaded documents.
+type DocumentFilenameScrubber interface {
+	ScrubFilename(ctx context.Context, userID int64, filename string) (string, bool, error)
+}
+
+// DocumentContentKeys provides the data-encryption keys the content of documents is encrypted
+// with, usually the TenantKeyRepository.
+type DocumentContentKeys interface {
+	GetOrCreateDataKey(ctx context.Context, userID int64) ([]byte, error)
+	GetDataKey(ctx context.Context, userID int64) ([]byte, error)
+}
+
+// DocumentShareLookup reports the access users were granted to documents of other users,
+// usually the DocumentShareRepository.
+type DocumentShareLookup interface {
+	GetPermission(ctx context.Context, documentID, userID int64) (string, error)
+}
+
 // DocumentService provides operations for managing documents.
 type DocumentService struct {
-	docRepo repository.DocumentRepository
+	docRepo      repository.DocumentRepository
+	auditRepo    repository.ProcessingAuditRepository
+	usageService *UsageService
+	classifier   DocumentClassifier
+	deliverer    DocumentDeliverer
+	webhooks     WebhookPublisher
+	ruleTracker  DocumentRuleTracker
+	indexer      DocumentEntityIndexer
+	scrubber     DocumentFilenameScrubber
+	uploadLimits *config.UploadLimitSettings
+	archiveRepo  repository.DocumentArchiveRepository
+	archive      *config.DocumentArchiveSettings
+	contentRepo  repository.DocumentContentRepository
+	store        DocumentStore
+	contentKeys  DocumentContentKeys
+	shares       DocumentShareLookup
+	staleUsers   repository.UserRepository
+	staleNotices StaleDocumentNotifier
+	stale        *config.StaleDocumentSettings
+	retention    UserSettingsProvider
+	clock        clock.Clock
 }
 
 // NewDocumentService creates a new DocumentService.
-func NewDocumentService(docRepo repository.DocumentRepository) *DocumentService {
-	return &DocumentService{docRepo: docRepo}
+// The audit repository records documents processed in ephemeral mode.
+// The usage service is optional; when set, processed pages are counted for billing.
+func NewDocumentService(docRepo repository.DocumentRepository, auditRepo repository.ProcessingAuditRepository, usageService *UsageService) *DocumentService {
+	return &DocumentService{docRepo: docRepo, auditRepo: auditRepo, usageService: usageService}
+}
+
+// SetClock sets the clock retention, archiving and stale documents are judged by, so tests
+// can check documents at the instant they fall due.
+func (s *DocumentService) SetClock(c clock.Clock) {
+	s.clock = c
+}
+
+// now returns the current time of the service's clock.
+func (s *DocumentService) now() time.Time {
+	return clock.Or(s.clock).Now()
+}
+
+// SetClassifier enables classifying documents at upload by the user's classification rules.
+// Without a classifier, documents are stored without tags, folder or retention.
+func (s *DocumentService) SetClassifier(classifier DocumentClassifier) {
+	s.classifier = classifier
+}
+
+// SetDeliverer enables delivering documents to the user's export destinations once they are saved.
+func (s *DocumentService) SetDeliverer(deliverer DocumentDeliverer) {
+	s.deliverer = deliverer
+}
+
+// SetWebhooks enables sending document.processed and entity.detected events to the
+// user's webhooks.
+func (s *DocumentService) SetWebhooks(webhooks WebhookPublisher) {
+	s.webhooks = webhooks
+}
+
+// SetRuleTracker enables tracking which ban list words and search patterns produce the
+// detections of saved documents.
+func (s *DocumentService) SetRuleTracker(ruleTracker DocumentRuleTracker) {
+	s.ruleTracker = ruleTracker
+}
+
+// SetEntityIndexer enables storing salted hashes of the values detected in saved documents,
+// so that analytics can count them without storing the values.
+func (s *DocumentService) SetEntityIndexer(indexer DocumentEntityIndexer) {
+	s.indexer = indexer
+}
+
+// SetFilenameScrubber enables scrubbing the filenames of stored documents for users who
+// turned it on in their settings. Without a scrubber, filenames are stored as uploaded.
+func (s *DocumentService) SetFilenameScrubber(scrubber DocumentFilenameScrubber) {
+	s.scrubber = scrubber
+}
+
+// SetUploadLimits enables refusing uploads over the document, page and size limits before
+// they are processed. Without limits, uploads are only bounded by the request body size.
+func (s *DocumentService) SetUploadLimits(limits *config.UploadLimitSettings) {
+	s.uploadLimits = limits
+}
+
+// SetArchive enables moving documents unchanged for longer than the archive threshold to
+// cold storage. Without it, documents stay in hot storage.
+func (s *DocumentService) SetArchive(archiveRepo repository.DocumentArchiveRepository, settings *config.DocumentArchiveSettings) {
+	s.archiveRepo = archiveRepo
+	s.archive = settings
+}
+
+// SetContentStorage enables storing the PDF content of uploaded documents. The content is
+// encrypted with the data-encryption key of its owner, so shredding the key on account
+// erasure makes content left in the storage or its backups unreadable. Without it, only
+// the metadata and redaction schema of documents are stored.
+func (s *DocumentService) SetContentStorage(contentRepo repository.DocumentContentRepository, store DocumentStore, contentKeys DocumentContentKeys) {
+	s.contentRepo = contentRepo
+	s.store = store
+	s.contentKeys = contentKeys
+}
+
+// SetRetentionSettings enables previewing the documents deleted under the users' document
+// retention settings. Without it, previews only apply the retention passed to them.
+func (s *DocumentService) SetRetentionSettings(settings UserSettingsProvider) {
+	s.retention = settings
+}
+
+// SetShares enables reading and redacting documents shared by other users. Without it,
+// users can only access their own documents.
+func (s *DocumentService) SetShares(shares DocumentShareLookup) {
+	s.shares = shares
+}
+
+// authorizedDocument retrieves a document a user may access with a permission: their own
+// documents, and documents shared with them with that permission.
+// Documents the user may not see are reported as not found so their existence is not revealed.
+//
+// Parameters:
+//   - ctx: Context for the operation
+//   - userID: The ID of the user accessing the document
+//   - documentID: The ID of the document
+//   - needed: The permission the operation needs; empty if only the owner may perform it
+//
+// Returns:
+//   - The document as stored
+//   - ErrDocumentNotFound if the document doesn't exist or the user has no access to it
+//   - ErrDocumentReadOnly if the document is shared with the user for reading only
+//   - Other errors if the document or its share could not be read
+func (s *DocumentService) authorizedDocument(ctx context.Context, userID, documentID int64, needed string) (*models.Document, error) {
+	doc, err := s.docRepo.GetByID(ctx, documentID)
+	if err != nil {
+		if errors.Is(err, utils.ErrNotFound) {
+			return nil, ErrDocumentNotFound
+		}
+		return nil, err
+	}
+	if doc.UserID == userID {
+		return doc, nil
+	}
+	if needed == "" || s.shares == nil {
+		return nil, ErrDocumentNotFound
+	}
+
+	permission, err := s.shares.GetPermission(ctx, documentID, userID)
+	if err != nil {
+		if errors.Is(err, utils.ErrNotFound) {
+			return nil, ErrDocumentNotFound
+		}
+		return nil, err
+	}
+	if !models.SharePermissionAllows(permission, needed) {
+		// The user can read the document, so its existence is no secret
+		if models.SharePermissionAllows(permission, constants.SharePermissionRead) {
+			return nil, ErrDocumentReadOnly
+		}
+		return nil, ErrDocumentNotFound
+	}
+
+	return doc, nil
+}
+
+// MaxUploadBytes returns the size limit of an upload request, which bounds the body of
+// multipart uploads while it is read.
+//
+// Returns:
+//   - The configured limit in bytes, or the default limit if none is configured
+func (s *DocumentService) MaxUploadBytes() int64 {
+	if s.uploadLimits == nil || s.uploadLimits.MaxBytes <= 0 {
+		return constants.DefaultUploadMaxBytes
+	}
+	return s.uploadLimits.MaxBytes
+}
+
+// CheckUploadSize checks the size of an upload request before its body is read.
+//
+// Parameters:
+//   - size: The Content-Length of the request; negative if unknown
+//
+// Returns:
+//   - UploadLimitError if the size is over the limit
+//   - nil if the size is within the limit, unknown or not limited; the request body
+//     limit still bounds uploads of unknown size
+func (s *DocumentService) CheckUploadSize(size int64) error {
+	if s.uploadLimits == nil || s.uploadLimits.MaxBytes <= 0 || size <= s.uploadLimits.MaxBytes {
+		return nil
+	}
+	return utils.NewUploadLimitError(constants.UploadLimitBytes, size, s.uploadLimits.MaxBytes)
+}
+
+// checkUploadLimits checks the pages of an upload, and for uploads that are stored the
+// number of documents the user already has, before anything is processed.
+func (s *DocumentService) checkUploadLimits(ctx context.Context, userID int64, pages int, stored bool) error {
+	if s.uploadLimits == nil {
+		return nil
+	}
+
+	if s.uploadLimits.MaxPages > 0 && int64(pages) > s.uploadLimits.MaxPages {
+		return utils.NewUploadLimitError(constants.UploadLimitPages, int64(pages), s.uploadLimits.MaxPages)
+	}
+
+	if stored && s.uploadLimits.MaxDocuments > 0 {
+		count, err := s.docRepo.CountByUserID(ctx, userID)
+		if err != nil {
+			return err
+		}
+		if count >= s.uploadLimits.MaxDocuments {
+			return utils.NewUploadLimitError(constants.UploadLimitDocuments, count, s.uploadLimits.MaxDocuments)
+		}
+	}
+
+	return nil
 }
 
-// ListDocuments retrieves documents for a user with pagination.
-func (s *DocumentService) ListDocuments(userID int64, page, pageSize int) ([]*models.Document, int, error) {
-	docs, total, err := s.docRepo.GetByUserID(context.Background(), userID, page, pageSize)
+// ListDocuments retrieves documents for a user with pagination, optionally only those in one language.
+func (s *DocumentService) ListDocuments(ctx context.Context, userID int64, language string, page, pageSize int) ([]*models.Document, int, error) {
+	docs, total, err := s.docRepo.GetByUserID(ctx, userID, language, page, pageSize)
 	if err != nil {
 		return nil, 0, err
 	}
 
 	encryptionKey := []byte(os.Getenv("API_KEY_ENCRYPTION_KEY"))
 	for _, doc := range docs {
-		// Decrypt document names for display
-		originalFilename, err := doc.DecryptDocumentName(encryptionKey)
-		if err != nil {
-			return nil, 0, fmt.Errorf("failed to decrypt document name: %w", err)
+		if err := decryptDocument(doc, encryptionKey); err != nil {
+			return nil, 0, err
 		}
-		doc.HashedDocumentName = originalFilename
+	}
 
-		// Decrypt redaction schema if it exists
-		if doc.RedactionSchema != "" && doc.RedactionSchema != "{}" {
-			decryptedSchema, err := doc.DecryptRedactionSchema(encryptionKey)
-			if err != nil {
-				return nil, 0, fmt.Errorf("failed to decrypt redaction schema: %w", err)
-			}
-			doc.RedactionSchema = decryptedSchema
+	return docs, total, nil
+}
+
+// ListDocumentsPage retrieves the next documents of a user newest first, starting after a
+// keyset cursor, optionally only those in one language.
+func (s *DocumentService) ListDocumentsPage(ctx context.Context, userID int64, language string, after *utils.KeysetCursor, limit int) ([]*models.Document, error) {
+	docs, err := s.docRepo.GetByUserIDAfter(ctx, userID, language, after, limit)
+	if err != nil {
+		return nil, err
+	}
+
+	encryptionKey := []byte(os.Getenv("API_KEY_ENCRYPTION_KEY"))
+	for _, doc := range docs {
+		if err := decryptDocument(doc, encryptionKey); err != nil {
+			return nil, err
+		}
+	}
+
+	return docs, nil
+}
+
+// StreamDocuments passes all documents of a user to fn one at a time, newest first.
+// Documents are decrypted one by one as they are read, so large exports never
+// hold the full list in memory.
+func (s *DocumentService) StreamDocuments(ctx context.Context, userID int64, language string, fn func(*models.Document) error) error {
+	encryptionKey := []byte(os.Getenv("API_KEY_ENCRYPTION_KEY"))
+	return s.docRepo.StreamByUserID(ctx, userID, language, func(doc *models.Document) error {
+		if err := decryptDocument(doc, encryptionKey); err != nil {
+			return err
+		}
+		return fn(doc)
+	})
+}
+
+// ListDocumentsAfter retrieves the next documents of a user in ascending ID order, decrypted,
+// so long running jobs can walk all documents in batches and resume after the last one.
+func (s *DocumentService) ListDocumentsAfter(ctx context.Context, userID, afterID int64, limit int) ([]*models.Document, error) {
+	docs, err := s.docRepo.ListByUserIDAfter(ctx, userID, afterID, limit)
+	if err != nil {
+		return nil, err
+	}
+
+	encryptionKey := []byte(os.Getenv("API_KEY_ENCRYPTION_KEY"))
+	for _, doc := range docs {
+		if err := decryptDocument(doc, encryptionKey); err != nil {
+			return nil, err
+		}
+	}
+
+	return docs, nil
+}
+
+// SearchDocuments retrieves the documents of a user matching a search filter with pagination,
+// newest first. The upload date and entity filters are applied by the database; document names
+// are encrypted, so they are matched case-insensitively here after decryption.
+//
+// Parameters:
+//   - ctx: Context for the operation
+//   - userID: The ID of the user searching their documents
+//   - filter: The name, upload date range, entity types and entity count to match
+//   - page: The page number, starting at 1
+//   - pageSize: The number of documents per page
+//
+// Returns:
+//   - The decrypted documents on the page
+//   - The total number of matching documents
+//   - An error if the documents could not be read or decrypted
+func (s *DocumentService) SearchDocuments(ctx context.Context, userID int64, filter models.DocumentSearchFilter, page, pageSize int) ([]*models.Document, int, error) {
+	name := strings.ToLower(strings.TrimSpace(filter.Name))
+	offset := (page - 1) * pageSize
+
+	docs := []*models.Document{}
+	total := 0
+	encryptionKey := []byte(os.Getenv("API_KEY_ENCRYPTION_KEY"))
+	err := s.docRepo.SearchByUserID(ctx, userID, filter, func(doc *models.Document) error {
+		if err := decryptDocument(doc, encryptionKey); err != nil {
+			return err
 		}
+		if name != "" && !strings.Contains(strings.ToLower(doc.HashedDocumentName), name) {
+			return nil
+		}
+		if total >= offset && len(docs) < pageSize {
+			docs = append(docs, doc)
+		}
+		total++
+		return nil
+	})
+	if err != nil {
+		return nil, 0, err
 	}
 
 	return docs, total, nil
 }
 
+// decryptDocument decrypts the name and redaction schema of a document for display.
+func decryptDocument(doc *models.Document, encryptionKey []byte) error {
+	// Decrypt document names for display
+	originalFilename, err := doc.DecryptDocumentName(encryptionKey)
+	if err != nil {
+		return fmt.Errorf("failed to decrypt document name: %w", err)
+	}
+	doc.HashedDocumentName = originalFilename
+
+	// Decrypt redaction schema if it exists
+	if doc.RedactionSchema != "" && doc.RedactionSchema != "{}" {
+		decryptedSchema, err := doc.DecryptRedactionSchema(encryptionKey)
+		if err != nil {
+			return fmt.Errorf("failed to decrypt redaction schema: %w", err)
+		}
+		doc.RedactionSchema = normalizeStoredSchema(doc.ID, decryptedSchema)
+	}
+
+	return nil
+}
+
+// normalizeStoredSchema converts a decrypted redaction schema that has not been migrated
+// yet to page-relative coordinates, so clients always receive the current version.
+// Schemas that cannot be converted are returned as they are.
+func normalizeStoredSchema(documentID int64, schema string) string {
+	if schema == "" || schema == "{}" {
+		return schema
+	}
+
+	var redactionMapping models.RedactionMapping
+	if err := json.Unmarshal([]byte(schema), &redactionMapping); err != nil || redactionMapping.Version == models.RedactionSchemaVersion {
+		return schema
+	}
+
+	if err := redactionMapping.Normalize(); err != nil {
+		log.Warn().Err(err).Int64(constants.ColumnDocumentID, documentID).Msg("Failed to normalize redaction schema")
+		return schema
+	}
+
+	normalized, err := json.Marshal(redactionMapping)
+	if err != nil {
+		return schema
+	}
+	return string(normalized)
+}
+
 func (s *DocumentService) CalculateEntityCount(redactionSchema string) int {
 	if redactionSchema == "" || redactionSchema == "{}" {
 		return 0
@@ -77,28 +471,174 @@ func (s *DocumentService) CalculateEntityCount(redactionSchema string) int {
 }
 
 // UploadDocument uploads a new document for a user.
-func (s *DocumentService) UploadDocument(userID int64, filename string, redactionSchema models.RedactionMapping) (*models.Document, error) {
-	// Convert redactionSchema to JSON
-	redactionSchemaJSON, err := json.Marshal(redactionSchema)
+// The language sent by the client is stored on the document so the detection service can
+// choose matching models; without one, the language is detected from the filename and the
+// detected texts, and left empty if it cannot be determined.
+// The source, such as "scanner" or "email", is stored and used by the classification rules
+// that assign the tags, folder and retention of the document.
+// Uploads over the page or document limit are refused before anything is processed.
+// If the user enabled filename scrubbing, the filename is stored with their ban list words
+// and search pattern matches replaced, and the document is flagged as scrubbed.
+func (s *DocumentService) UploadDocument(ctx context.Context, userID int64, filename, language, source string, redactionSchema models.RedactionMapping) (*models.Document, error) {
+	return s.uploadDocument(ctx, userID, filename, language, source, redactionSchema, nil)
+}
+
+// UploadDocumentWithContent uploads a new document like UploadDocument, and stores its PDF
+// content encrypted with the user's data-encryption key. The content is linked to the
+// document before it is counted or delivered; if anything fails, neither is kept.
+//
+// Parameters:
+//   - ctx: Context for the operation
+//   - userID: The ID of the user uploading the document
+//   - filename: The filename of the document
+//   - language: The ISO 639-1 code of the document's language; empty to detect it
+//   - source: Where the document came from; may be empty
+//   - redactionSchema: The detections of the document
+//   - content: The PDF file
+//
+// Returns:
+//   - The stored document, with its name and redaction schema decrypted
+//   - ValidationError if the file is not a PDF, or the language or redaction schema is invalid
+//   - UploadLimitError if the document is over the page or document limit
+//   - Other errors if the document or its content could not be stored
+func (s *DocumentService) UploadDocumentWithContent(ctx context.Context, userID int64, filename, language, source string, redactionSchema models.RedactionMapping, content []byte) (*models.Document, error) {
+	if s.store == nil {
+		return nil, errors.New("document content storage is not configured")
+	}
+	if !models.IsPDF(content) {
+		return nil, utils.NewValidationError(constants.DocumentContentFormField, constants.MsgDocumentContentNotPDF)
+	}
+	if err := s.checkUploadLimits(ctx, userID, len(redactionSchema.Pages), true); err != nil {
+		return nil, err
+	}
+
+	dataKey, err := s.contentKeys.GetOrCreateDataKey(ctx, userID)
 	if err != nil {
-		return nil, fmt.Errorf("failed to marshal redaction schema: %w", err)
+		return nil, fmt.Errorf("failed to get tenant data key: %w", err)
+	}
+	// Encrypted in segments, so downloads can decrypt the parts they read
+	sealed, err := utils.EncryptSegments(content, dataKey, constants.DocumentContentSegmentSize)
+	if err != nil {
+		return nil, fmt.Errorf("failed to encrypt document content: %w", err)
+	}
+
+	// The key names neither the document nor its file, which may hold personal data
+	sum := sha256.Sum256(content)
+	stored := &models.DocumentContent{
+		StorageKey:  fmt.Sprintf("%d/%s", userID, utils.NewID()),
+		ContentType: constants.ContentTypePDF,
+		SizeBytes:   int64(len(content)),
+		SHA256:      hex.EncodeToString(sum[:]),
+		SegmentSize: constants.DocumentContentSegmentSize,
+		CreatedAt:   s.now(),
+	}
+	if err := s.store.Put(ctx, stored.StorageKey, sealed); err != nil {
+		return nil, fmt.Errorf("failed to store document content: %w", err)
+	}
+
+	doc, err := s.uploadDocument(ctx, userID, filename, language, source, redactionSchema, func(doc *models.Document) error {
+		stored.DocumentID = doc.ID
+		return s.contentRepo.Create(ctx, stored)
+	})
+	if err != nil {
+		if deleteErr := s.store.Delete(ctx, stored.StorageKey); deleteErr != nil {
+			log.Warn().Err(deleteErr).Str("storage_key", stored.StorageKey).Msg("Failed to delete content of failed upload")
+		}
+		return nil, err
+	}
+
+	return doc, nil
+}
+
+// uploadDocument stores a new document. The link function, if set, is called once the
+// document is stored and before it is counted or delivered; if it fails, the document is
+// deleted again.
+func (s *DocumentService) uploadDocument(ctx context.Context, userID int64, filename, language, source string, redactionSchema models.RedactionMapping, link func(doc *models.Document) error) (*models.Document, error) {
+	if err := s.checkUploadLimits(ctx, userID, len(redactionSchema.Pages), true); err != nil {
+		return nil, err
+	}
+
+	language, classification, redactionSchemaJSON, err := s.prepareDocument(ctx, userID, filename, language, source, &redactionSchema)
+	if err != nil {
+		return nil, err
 	}
 
 	// Log the redaction schema
 	log.Info().RawJSON("redaction_schema", redactionSchemaJSON).Msg("Processing redaction schema")
 
+	// Keep personal data in the filename out of storage, as it bypasses redaction
+	filenameScrubbed := false
+	if s.scrubber != nil {
+		filename, filenameScrubbed, err = s.scrubber.ScrubFilename(ctx, userID, filename)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scrub filename: %w", err)
+		}
+	}
+
 	encryptionKey := []byte(os.Getenv("API_KEY_ENCRYPTION_KEY"))
 	doc := models.NewDocument(userID, filename, encryptionKey)
+	doc.FilenameScrubbed = filenameScrubbed
+	doc.Language = language
+	doc.SchemaVersion = redactionSchema.Version
+	doc.Source = source
+	doc.SetEntitySummary(redactionSchema)
+
+	// Apply the user's classification rules
+	doc.Tags = classification.Tags
+	doc.Folder = classification.Folder
+	if classification.RetentionDays > 0 {
+		retainUntil := doc.UploadTimestamp.AddDate(0, 0, classification.RetentionDays)
+		doc.RetainUntil = &retainUntil
+	}
 
 	// Encrypt the redaction schema before storing
 	if err := doc.EncryptRedactionSchema(string(redactionSchemaJSON), encryptionKey); err != nil {
 		return nil, fmt.Errorf("failed to encrypt redaction schema: %w", err)
 	}
 
-	if err := s.docRepo.Create(context.Background(), doc); err != nil {
+	if err := s.docRepo.Create(ctx, doc); err != nil {
 		return nil, err
 	}
 
+	if link != nil {
+		if err := link(doc); err != nil {
+			if deleteErr := s.docRepo.Delete(ctx, doc.ID); deleteErr != nil {
+				log.Warn().Err(deleteErr).Int64("document_id", doc.ID).Msg("Failed to delete document of failed upload")
+			}
+			return nil, err
+		}
+	}
+
+	// Count the processed pages towards the tenant's usage
+	if s.usageService != nil {
+		s.usageService.RecordPagesProcessed(userID, len(redactionSchema.Pages))
+	}
+
+	// Count the detections towards the user's ban list words and search patterns
+	if s.ruleTracker != nil {
+		s.ruleTracker.RecordDetections(ctx, userID, redactionSchema)
+	}
+
+	// Hash the detected values for analytics
+	if s.indexer != nil {
+		s.indexer.IndexEntities(ctx, userID, doc.ID, redactionSchema)
+	}
+
+	// Deliver the redacted document to the user's export destinations
+	if s.deliverer != nil {
+		s.deliverer.DeliverDocument(userID, doc.ID)
+	}
+
+	// Tell the user's webhooks the document is ready
+	if s.webhooks != nil {
+		s.webhooks.Publish(ctx, userID, constants.WebhookEventDocumentProcessed, &models.DocumentProcessedEvent{
+			DocumentID:  doc.ID,
+			PageCount:   len(redactionSchema.Pages),
+			EntityCount: redactionSchema.EntityCount(),
+			Source:      source,
+		})
+	}
+
 	// Decrypt the document name before returning
 	originalFilename, err := doc.DecryptDocumentName(encryptionKey)
 	if err != nil {
@@ -112,16 +652,134 @@ func (s *DocumentService) UploadDocument(userID int64, filename string, redactio
 	return doc, nil
 }
 
-// GetDocumentByID retrieves a document by its ID.
-func (s *DocumentService) GetDocumentByID(id int64) (*models.Document, error) {
-	doc, err := s.docRepo.GetByID(context.Background(), id)
+// ProcessEphemeral processes a document like UploadDocument, but keeps no record of it
+// beyond an audit stub with its page and entity counts. The document and its entities are
+// not stored, its filename and detections are not logged, it is not delivered to export
+// destinations, and its detections are not counted towards the user's rules. The
+// classification rules still apply, and their tags and folder are returned to the client.
+//
+// Parameters:
+//   - ctx: Context for the operation
+//   - userID: The ID of the user processing the document
+//   - filename: The filename of the document
+//   - language: The ISO 639-1 code of the document's language; empty to detect it
+//   - source: Where the document came from; may be empty
+//   - redactionSchema: The detections of the document
+//
+// Returns:
+//   - The processed document, with the redaction schema normalized
+//   - UploadLimitError if the document has more pages than allowed; the document limit
+//     does not apply, as nothing is stored
+//   - ValidationError if the language or the redaction schema is invalid
+//   - Other errors if the rules or the audit stub fail; nothing is kept then
+func (s *DocumentService) ProcessEphemeral(ctx context.Context, userID int64, filename, language, source string, redactionSchema models.RedactionMapping) (*models.EphemeralDocument, error) {
+	if err := s.checkUploadLimits(ctx, userID, len(redactionSchema.Pages), false); err != nil {
+		return nil, err
+	}
+
+	language, classification, redactionSchemaJSON, err := s.prepareDocument(ctx, userID, filename, language, source, &redactionSchema)
 	if err != nil {
-		if errors.Is(err, utils.ErrNotFound) {
-			return nil, ErrDocumentNotFound
+		return nil, err
+	}
+
+	entityCount := 0
+	for _, page := range redactionSchema.Pages {
+		entityCount += len(page.Sensitive)
+	}
+	audit := &models.ProcessingAudit{UserID: userID, PageCount: len(redactionSchema.Pages), EntityCount: entityCount}
+	if err := s.auditRepo.Create(ctx, audit); err != nil {
+		return nil, err
+	}
+
+	// Count the processed pages towards the tenant's usage; only the count is kept
+	if s.usageService != nil {
+		s.usageService.RecordPagesProcessed(userID, len(redactionSchema.Pages))
+	}
+
+	log.Info().
+		Int64("user_id", userID).
+		Int64("audit_id", audit.ID).
+		Int("page_count", audit.PageCount).
+		Int("entity_count", audit.EntityCount).
+		Msg("Document processed in ephemeral mode")
+
+	return &models.EphemeralDocument{
+		ProcessingMode:  constants.ProcessingModeEphemeral,
+		AuditID:         audit.ID,
+		Filename:        filename,
+		RedactionSchema: string(redactionSchemaJSON),
+		Language:        language,
+		Source:          source,
+		Tags:            classification.Tags,
+		Folder:          classification.Folder,
+		ProcessedAt:     audit.ProcessedAt,
+	}, nil
+}
+
+// prepareDocument validates the language of an uploaded document, detecting it if the client
+// sent none, normalizes its redaction schema and applies the user's classification rules.
+// It neither stores nor logs anything about the document.
+func (s *DocumentService) prepareDocument(ctx context.Context, userID int64, filename, language, source string, redactionSchema *models.RedactionMapping) (string, *models.DocumentClassification, []byte, error) {
+	language, ok := models.NormalizeLanguage(language)
+	if !ok {
+		return "", nil, nil, utils.NewValidationError("language", constants.MsgInvalidLanguage)
+	}
+	if language == "" {
+		language = DetectLanguage(documentTexts(filename, *redactionSchema)...)
+	}
+
+	// Store bounding boxes in page-relative coordinates whatever the client sent
+	if err := redactionSchema.Normalize(); err != nil {
+		return "", nil, nil, utils.NewValidationError("redaction_schema", err.Error())
+	}
+
+	// Convert redactionSchema to JSON
+	redactionSchemaJSON, err := json.Marshal(redactionSchema)
+	if err != nil {
+		return "", nil, nil, fmt.Errorf("failed to marshal redaction schema: %w", err)
+	}
+
+	// Apply the user's classification rules
+	classification := &models.DocumentClassification{Tags: []string{}}
+	if s.classifier != nil {
+		classification, err = s.classifier.Classify(ctx, userID, filename, source, *redactionSchema)
+		if err != nil {
+			return "", nil, nil, fmt.Errorf("failed to classify document: %w", err)
 		}
+	}
+
+	return language, classification, redactionSchemaJSON, nil
+}
+
+// GetDocumentByID retrieves a document a user owns or that was shared with them, for
+// reading or for redaction; both shares allow reading it.
+//
+// Parameters:
+//   - ctx: Context for the operation
+//   - userID: The ID of the user reading the document
+//   - id: The ID of the document
+//
+// Returns:
+//   - The decrypted document
+//   - ErrDocumentNotFound if the document doesn't exist or the user has no access to it
+//   - ErrDocumentArchived if the document is in cold storage
+//   - Other errors if the document or its share could not be read
+func (s *DocumentService) GetDocumentByID(ctx context.Context, userID, id int64) (*models.Document, error) {
+	doc, err := s.authorizedDocument(ctx, userID, id, constants.SharePermissionRead)
+	if err != nil {
 		return nil, err
 	}
 
+	return openDocument(doc)
+}
+
+// openDocument decrypts the name and redaction schema of a stored document.
+func openDocument(doc *models.Document) (*models.Document, error) {
+	// The schema of an archived document is in cold storage until it is restored
+	if doc.ArchivedAt != nil {
+		return nil, ErrDocumentArchived
+	}
+
 	encryptionKey := []byte(os.Getenv("API_KEY_ENCRYPTION_KEY"))
 
 	// Check if redaction_schema is empty
@@ -133,7 +791,7 @@ func (s *DocumentService) GetDocumentByID(id int64) (*models.Document, error) {
 		if err != nil {
 			return nil, fmt.Errorf("failed to decrypt redaction schema: %w", err)
 		}
-		doc.RedactionSchema = decryptedSchema
+		doc.RedactionSchema = normalizeStoredSchema(doc.ID, decryptedSchema)
 	}
 
 	// Verify the decrypted schema is valid JSON
@@ -157,41 +815,979 @@ func (s *DocumentService) GetDocumentByID(id int64) (*models.Document, error) {
 		UploadTimestamp:    doc.UploadTimestamp,
 		LastModified:       doc.LastModified,
 		RedactionSchema:    doc.RedactionSchema, // Return the decrypted schema
+		Language:           doc.Language,
+		Tags:               doc.Tags,
+		Folder:             doc.Folder,
+		Source:             doc.Source,
+		RetainUntil:        doc.RetainUntil,
+		FilenameScrubbed:   doc.FilenameScrubbed,
 	}, nil
 }
 
-// DeleteDocumentByID deletes a document by its ID.
-func (s *DocumentService) DeleteDocumentByID(id int64) error {
-	err := s.docRepo.Delete(context.Background(), id)
+// OpenDocumentContent opens the PDF content of a document a user owns or that was shared
+// with them for reading. Content encrypted in segments is read from the document storage
+// as the reader is read, so a download holds one segment in memory at a time and a range
+// reads only the segments it covers; content stored before is decrypted as a whole.
+//
+// Parameters:
+//   - ctx: Context for the operation; canceling it stops the reading
+//   - userID: The ID of the user reading the content
+//   - documentID: The ID of the document
+//
+// Returns:
+//   - The stored content record
+//   - The decrypted PDF, to be closed by the caller
+//   - ErrDocumentNotFound if the document doesn't exist or the user has no access to it
+//   - ErrDocumentContentNotFound if the document was uploaded without its content
+//   - Other errors if the content could not be read or decrypted
+func (s *DocumentService) OpenDocumentContent(ctx context.Context, userID, documentID int64) (*models.DocumentContent, io.ReadSeekCloser, error) {
+	doc, err := s.authorizedDocument(ctx, userID, documentID, constants.SharePermissionRead)
+	if err != nil {
+		return nil, nil, err
+	}
+	if s.store == nil {
+		return nil, nil, ErrDocumentContentNotFound
+	}
+
+	stored, err := s.contentRepo.GetByDocumentID(ctx, documentID)
 	if err != nil {
 		if errors.Is(err, utils.ErrNotFound) {
-			return ErrDocumentNotFound
+			return nil, nil, ErrDocumentContentNotFound
 		}
-		return err
+		return nil, nil, err
 	}
-	return nil
-}
 
-// GetDocumentSummary retrieves a summary of a document.
-func (s *DocumentService) GetDocumentSummary(id int64) (*models.DocumentSummary, error) {
-	summary, err := s.docRepo.GetDocumentSummary(context.Background(), id)
+	// The content is encrypted with the key of the owner, whoever reads it
+	dataKey, err := s.contentKeys.GetDataKey(ctx, doc.UserID)
 	if err != nil {
-		return nil, err
+		return nil, nil, fmt.Errorf("failed to get tenant data key: %w", err)
 	}
 
-	// The HashedName should already be decrypted by the repository
-	// Double-check to ensure it's using the original filename
-	encryptionKey := []byte(os.Getenv("API_KEY_ENCRYPTION_KEY"))
-	doc, err := s.docRepo.GetByID(context.Background(), id)
-	if err != nil {
-		return nil, err
+	if stored.SegmentSize > 0 {
+		reader, err := newSegmentedContentReader(ctx, s.store, stored, dataKey)
+		if err != nil {
+			return nil, nil, err
+		}
+		return stored, reader, nil
 	}
 
-	originalFilename, err := doc.DecryptDocumentName(encryptionKey)
+	sealed, err := s.store.Get(ctx, stored.StorageKey)
 	if err != nil {
-		return nil, fmt.Errorf("failed to decrypt document name: %w", err)
+		return nil, nil, fmt.Errorf("failed to read document content: %w", err)
 	}
-	summary.HashedName = originalFilename
-
+	content, err := utils.DecryptBytes(sealed, dataKey)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to decrypt document content: %w", err)
+	}
+
+	return stored, bufferedContentReader{bytes.NewReader(content)}, nil
+}
+
+// GetDocumentContent reads the whole PDF content of a document a user owns or that was
+// shared with them, for processing it; downloads use OpenDocumentContent.
+//
+// Parameters:
+//   - ctx: Context for the operation
+//   - userID: The ID of the user reading the content
+//   - documentID: The ID of the document
+//
+// Returns:
+//   - The stored content record
+//   - The decrypted PDF
+//   - ErrDocumentNotFound if the document doesn't exist or the user has no access to it
+//   - ErrDocumentContentNotFound if the document was uploaded without its content
+//   - Other errors if the content could not be read or decrypted
+func (s *DocumentService) GetDocumentContent(ctx context.Context, userID, documentID int64) (*models.DocumentContent, []byte, error) {
+	stored, reader, err := s.OpenDocumentContent(ctx, userID, documentID)
+	if err != nil {
+		return nil, nil, err
+	}
+	defer reader.Close()
+
+	content, err := io.ReadAll(reader)
+	if err != nil {
+		return nil, nil, err
+	}
+	return stored, content, nil
+}
+
+// DeleteOrphanedContents deletes a batch of stored contents whose documents were deleted,
+// whether by the user, by their retention or with the account. It runs as a periodic
+// maintenance task.
+func (s *DocumentService) DeleteOrphanedContents(ctx context.Context) (int64, error) {
+	if s.store == nil {
+		return 0, nil
+	}
+
+	contents, err := s.contentRepo.ListOrphaned(ctx, constants.DocumentContentBatchSize)
+	if err != nil {
+		return 0, err
+	}
+
+	var count int64
+	for _, stored := range contents {
+		// Delete the content before its link, so a failure leaves it to be found again
+		if err := s.store.Delete(ctx, stored.StorageKey); err != nil {
+			return count, fmt.Errorf("failed to delete content of document %d: %w", stored.DocumentID, err)
+		}
+		if err := s.contentRepo.Delete(ctx, stored.DocumentID); err != nil && !errors.Is(err, utils.ErrNotFound) {
+			return count, err
+		}
+		count++
+	}
+
+	return count, nil
+}
+
+// ReencryptLegacy moves a batch of documents still encrypted with the shared master key
+// to the data-encryption key of their tenant. It runs as a periodic maintenance task
+// until no legacy ciphertexts are left.
+func (s *DocumentService) ReencryptLegacy(ctx context.Context) (int64, error) {
+	return s.docRepo.ReencryptLegacy(ctx, constants.LegacyReencryptBatchSize)
+}
+
+// NormalizeLegacySchemas converts a batch of redaction schemas stored before schema
+// versioning to page-relative coordinates. It runs as a periodic maintenance task until
+// no outdated schemas are left.
+func (s *DocumentService) NormalizeLegacySchemas(ctx context.Context) (int64, error) {
+	docs, err := s.docRepo.GetOutdatedSchemas(ctx, models.RedactionSchemaVersion, constants.SchemaNormalizationBatchSize)
+	if err != nil {
+		return 0, err
+	}
+
+	encryptionKey := []byte(os.Getenv("API_KEY_ENCRYPTION_KEY"))
+
+	var count int64
+	for _, doc := range docs {
+		var redactionMapping models.RedactionMapping
+		if doc.RedactionSchema != "" && doc.RedactionSchema != "{}" {
+			schema, err := doc.DecryptRedactionSchema(encryptionKey)
+			if err != nil {
+				return count, fmt.Errorf("failed to decrypt redaction schema of document %d: %w", doc.ID, err)
+			}
+
+			if err := json.Unmarshal([]byte(schema), &redactionMapping); err != nil {
+				return count, fmt.Errorf("failed to unmarshal redaction schema of document %d: %w", doc.ID, err)
+			}
+			if err := redactionMapping.Normalize(); err != nil {
+				return count, fmt.Errorf("failed to normalize redaction schema of document %d: %w", doc.ID, err)
+			}
+
+			normalized, err := json.Marshal(redactionMapping)
+			if err != nil {
+				return count, fmt.Errorf("failed to marshal redaction schema of document %d: %w", doc.ID, err)
+			}
+			if err := doc.EncryptRedactionSchema(string(normalized), encryptionKey); err != nil {
+				return count, fmt.Errorf("failed to encrypt redaction schema of document %d: %w", doc.ID, err)
+			}
+		}
+
+		doc.SchemaVersion = models.RedactionSchemaVersion
+		doc.SetEntitySummary(redactionMapping)
+		if err := s.docRepo.UpdateRedactionSchema(ctx, doc); err != nil {
+			return count, err
+		}
+		count++
+	}
+
+	return count, nil
+}
+
+// SummarizeLegacyEntities computes the entity count and entity types of a batch of documents
+// stored before document search, so search filters on detected entities match them too.
+// It runs as a periodic maintenance task until every document has a summary.
+func (s *DocumentService) SummarizeLegacyEntities(ctx context.Context) (int64, error) {
+	docs, err := s.docRepo.GetMissingEntitySummaries(ctx, constants.EntitySummaryBatchSize)
+	if err != nil {
+		return 0, err
+	}
+
+	encryptionKey := []byte(os.Getenv("API_KEY_ENCRYPTION_KEY"))
+
+	var count int64
+	for _, doc := range docs {
+		var redactionMapping models.RedactionMapping
+		if doc.RedactionSchema != "" && doc.RedactionSchema != "{}" {
+			schema, err := doc.DecryptRedactionSchema(encryptionKey)
+			if err != nil {
+				return count, fmt.Errorf("failed to decrypt redaction schema of document %d: %w", doc.ID, err)
+			}
+			if err := json.Unmarshal([]byte(schema), &redactionMapping); err != nil {
+				return count, fmt.Errorf("failed to unmarshal redaction schema of document %d: %w", doc.ID, err)
+			}
+		}
+
+		doc.SetEntitySummary(redactionMapping)
+		if err := s.docRepo.UpdateEntitySummary(ctx, doc); err != nil {
+			return count, err
+		}
+		count++
+	}
+
+	return count, nil
+}
+
+// DeleteExpiredDocuments deletes the documents whose retention, set by a classification
+// rule at upload or by their owner's document retention setting, has passed, at most
+// batchSize of them unless batchSize is 0. In a dry run the documents are counted instead.
+// It runs as a periodic maintenance task.
+func (s *DocumentService) DeleteExpiredDocuments(ctx context.Context, batchSize int, dryRun bool) (int64, error) {
+	if dryRun {
+		count, err := s.docRepo.CountExpired(ctx, s.now())
+		return batchCount(count, batchSize), err
+	}
+	return s.docRepo.DeleteExpired(ctx, s.now(), batchSize)
+}
+
+// PreviewRetention lists the documents of a user the document retention maintenance task
+// would delete, without deleting anything.
+//
+// Parameters:
+//   - ctx: Context for the operation
+//   - userID: The ID of the user whose documents to list
+//   - retentionDays: The document retention setting to preview, so users can see its effect
+//     before saving it; nil previews the user's current setting
+//
+// Returns:
+//   - The documents that would be deleted and the number of them and their detected entities
+//   - An error if the settings or documents cannot be read
+func (s *DocumentService) PreviewRetention(ctx context.Context, userID int64, retentionDays *int) (*models.RetentionPreview, error) {
+	days := 0
+	if retentionDays != nil {
+		days = *retentionDays
+	} else if s.retention != nil {
+		settings, err := s.retention.GetUserSettings(ctx, userID)
+		if err != nil {
+			return nil, err
+		}
+		days = settings.DocumentRetentionDays
+	}
+
+	return s.docRepo.PreviewExpired(ctx, userID, s.now(), days, constants.RetentionPreviewLimit)
+}
+
+// ArchiveOldDocuments moves a batch of documents not modified within the archive threshold
+// to cold storage. It runs as a periodic maintenance task.
+func (s *DocumentService) ArchiveOldDocuments(ctx context.Context) (int64, error) {
+	if s.archiveRepo == nil || s.archive == nil || s.archive.AfterDays <= 0 {
+		return 0, nil
+	}
+
+	now := s.now()
+	cutoff := now.AddDate(0, 0, -s.archive.AfterDays)
+	return s.archiveRepo.Archive(ctx, cutoff, now, constants.DocumentArchiveBatchSize)
+}
+
+// RestoreFromArchive moves an archived document owned by a user back to hot storage, so its
+// redaction schema and detected entities can be read again.
+func (s *DocumentService) RestoreFromArchive(ctx context.Context, userID, documentID int64) (*models.Document, error) {
+	doc, err := s.authorizedDocument(ctx, userID, documentID, "")
+	if err != nil {
+		return nil, err
+	}
+	if doc.ArchivedAt == nil || s.archiveRepo == nil {
+		return nil, ErrDocumentNotArchived
+	}
+
+	if err := s.archiveRepo.Restore(ctx, documentID); err != nil {
+		if errors.Is(err, utils.ErrNotFound) {
+			return nil, ErrDocumentNotArchived
+		}
+		return nil, err
+	}
+
+	log.Info().
+		Int64("user_id", userID).
+		Int64("document_id", documentID).
+		Msg("Document restored from archive")
+
+	return s.GetDocumentByID(ctx, userID, documentID)
+}
+
+// TransferDocument moves a document to another user, for example when its owner leaves.
+// Everything stored for the document is re-encrypted with the data-encryption key of the
+// new owner, and the hashes of its detected values are rebuilt under the new owner's key.
+// An archived document is restored first, since its redaction schema must be re-encrypted.
+//
+// Parameters:
+//   - ctx: Context for the operation
+//   - documentID: The ID of the document
+//   - fromUserID: The ID of the current owner
+//   - toUserID: The ID of the new owner
+//
+// Returns:
+//   - ErrDocumentNotFound if the document doesn't exist or is not owned by fromUserID
+//   - Other errors if the document could not be transferred; it is left with its owner then
+func (s *DocumentService) TransferDocument(ctx context.Context, documentID, fromUserID, toUserID int64) error {
+	doc, err := s.authorizedDocument(ctx, fromUserID, documentID, "")
+	if err != nil {
+		return err
+	}
+	if doc.ArchivedAt != nil && s.archiveRepo != nil {
+		if err := s.archiveRepo.Restore(ctx, documentID); err != nil {
+			return fmt.Errorf("failed to restore document from archive: %w", err)
+		}
+	}
+
+	// Re-encrypt the content under a new key, so the old one can be deleted once the
+	// document is moved and a failed move leaves the content of the owner untouched
+	var oldStorageKey, newStorageKey string
+	if s.store != nil {
+		stored, err := s.contentRepo.GetByDocumentID(ctx, documentID)
+		if err != nil && !errors.Is(err, utils.ErrNotFound) {
+			return err
+		}
+		if err == nil {
+			oldStorageKey = stored.StorageKey
+			newStorageKey, err = s.reencryptContent(ctx, stored, fromUserID, toUserID)
+			if err != nil {
+				return err
+			}
+		}
+	}
+
+	if err := s.docRepo.TransferOwnership(ctx, documentID, fromUserID, toUserID, newStorageKey); err != nil {
+		if newStorageKey != "" {
+			if deleteErr := s.store.Delete(ctx, newStorageKey); deleteErr != nil {
+				log.Warn().Err(deleteErr).Str("storage_key", newStorageKey).Msg("Failed to delete content of failed transfer")
+			}
+		}
+		if errors.Is(err, utils.ErrNotFound) {
+			return ErrDocumentNotFound
+		}
+		return err
+	}
+	if oldStorageKey != "" {
+		if err := s.store.Delete(ctx, oldStorageKey); err != nil {
+			log.Warn().Err(err).Str("storage_key", oldStorageKey).Msg("Failed to delete content of transferred document")
+		}
+	}
+
+	// The hashes of the previous owner were deleted with the transfer
+	if s.indexer != nil {
+		if transferred, err := s.GetDocumentByID(ctx, toUserID, documentID); err != nil {
+			log.Warn().Err(err).Int64("document_id", documentID).Msg("Failed to read transferred document for indexing")
+		} else {
+			var redactionSchema models.RedactionMapping
+			if err := json.Unmarshal([]byte(transferred.RedactionSchema), &redactionSchema); err == nil {
+				s.indexer.IndexEntities(ctx, toUserID, documentID, redactionSchema)
+			}
+		}
+	}
+
+	log.Info().
+		Int64("from_user_id", fromUserID).
+		Int64("to_user_id", toUserID).
+		Int64("document_id", documentID).
+		Msg("Document transferred")
+
+	return nil
+}
+
+// reencryptContent copies stored content encrypted for one user to a new key, encrypted for
+// another user in the same format, and returns the new key.
+func (s *DocumentService) reencryptContent(ctx context.Context, stored *models.DocumentContent, fromUserID, toUserID int64) (string, error) {
+	sealed, err := s.store.Get(ctx, stored.StorageKey)
+	if err != nil {
+		return "", fmt.Errorf("failed to read document content: %w", err)
+	}
+	fromKey, err := s.contentKeys.GetDataKey(ctx, fromUserID)
+	if err != nil {
+		return "", fmt.Errorf("failed to get tenant data key: %w", err)
+	}
+	var content []byte
+	if stored.SegmentSize > 0 {
+		content, err = utils.DecryptSegments(sealed, fromKey, stored.SegmentSize)
+	} else {
+		content, err = utils.DecryptBytes(sealed, fromKey)
+	}
+	if err != nil {
+		return "", fmt.Errorf("failed to decrypt document content: %w", err)
+	}
+	toKey, err := s.contentKeys.GetOrCreateDataKey(ctx, toUserID)
+	if err != nil {
+		return "", fmt.Errorf("failed to get tenant data key: %w", err)
+	}
+	if stored.SegmentSize > 0 {
+		sealed, err = utils.EncryptSegments(content, toKey, stored.SegmentSize)
+	} else {
+		sealed, err = utils.EncryptBytes(content, toKey)
+	}
+	if err != nil {
+		return "", fmt.Errorf("failed to encrypt document content: %w", err)
+	}
+
+	newStorageKey := fmt.Sprintf("%d/%s", toUserID, utils.NewID())
+	if err := s.store.Put(ctx, newStorageKey, sealed); err != nil {
+		return "", fmt.Errorf("failed to store document content: %w", err)
+	}
+	return newStorageKey, nil
+}
+
+// DeleteDocumentByID deletes a document owned by a user. Only the owner may delete a
+// document, whatever it was shared with others for.
+func (s *DocumentService) DeleteDocumentByID(ctx context.Context, userID, id int64) error {
+	if _, err := s.authorizedDocument(ctx, userID, id, ""); err != nil {
+		return err
+	}
+
+	err := s.docRepo.Delete(ctx, id)
+	if err != nil {
+		if errors.Is(err, utils.ErrNotFound) {
+			return ErrDocumentNotFound
+		}
+		return err
+	}
+	return nil
+}
+
+// GetDocumentSummary retrieves a summary of a document a user owns or that was shared with them.
+func (s *DocumentService) GetDocumentSummary(ctx context.Context, userID, id int64) (*models.DocumentSummary, error) {
+	doc, err := s.authorizedDocument(ctx, userID, id, constants.SharePermissionRead)
+	if err != nil {
+		return nil, err
+	}
+
+	summary, err := s.docRepo.GetDocumentSummary(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	// The HashedName should already be decrypted by the repository
+	// Double-check to ensure it's using the original filename
+	encryptionKey := []byte(os.Getenv("API_KEY_ENCRYPTION_KEY"))
+
+	originalFilename, err := doc.DecryptDocumentName(encryptionKey)
+	if err != nil {
+		return nil, fmt.Errorf("failed to decrypt document name: %w", err)
+	}
+	summary.HashedName = originalFilename
+
 	return summary, nil
 }
+
+// UpdateRedactionSchema replaces the redaction schema of a document a user owns or that was
+// shared with them for redaction, so a team can work on the redaction of the same file.
+// The replaced schema is kept in the version history of the document.
+//
+// Parameters:
+//   - ctx: Context for the operation
+//   - userID: The ID of the user changing the schema
+//   - documentID: The ID of the document
+//   - redactionSchema: The new redaction schema
+//
+// Returns:
+//   - The document with the new redaction schema
+//   - ErrDocumentNotFound if the document doesn't exist or the user has no access to it
+//   - ErrDocumentReadOnly if the document is shared with the user for reading only
+//   - ErrDocumentArchived if the document is in cold storage
+//   - ValidationError if the redaction schema is invalid
+//   - Other errors if the schema could not be stored
+func (s *DocumentService) UpdateRedactionSchema(ctx context.Context, userID, documentID int64, redactionSchema models.RedactionMapping) (*models.Document, error) {
+	doc, err := s.authorizedDocument(ctx, userID, documentID, constants.SharePermissionRedact)
+	if err != nil {
+		return nil, err
+	}
+	if doc.ArchivedAt != nil {
+		return nil, ErrDocumentArchived
+	}
+
+	// Store bounding boxes in page-relative coordinates whatever the client sent
+	if err := redactionSchema.Normalize(); err != nil {
+		return nil, utils.NewValidationError("redaction_schema", err.Error())
+	}
+	redactionSchemaJSON, err := json.Marshal(redactionSchema)
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal redaction schema: %w", err)
+	}
+
+	encryptionKey := []byte(os.Getenv("API_KEY_ENCRYPTION_KEY"))
+
+	// Keep the schema being replaced so the user can go back to it
+	if doc.RedactionSchema != "" && doc.RedactionSchema != "{}" {
+		previous, err := schemaVersionOf(doc, userID, encryptionKey)
+		if err != nil {
+			return nil, err
+		}
+		if err := s.docRepo.CreateSchemaVersion(ctx, doc.UserID, previous); err != nil {
+			return nil, err
+		}
+	}
+
+	if err := doc.EncryptRedactionSchema(string(redactionSchemaJSON), encryptionKey); err != nil {
+		return nil, fmt.Errorf("failed to encrypt redaction schema: %w", err)
+	}
+	doc.SchemaVersion = redactionSchema.Version
+	doc.SetEntitySummary(redactionSchema)
+
+	if err := s.docRepo.UpdateRedactionSchema(ctx, doc); err != nil {
+		if errors.Is(err, utils.ErrNotFound) {
+			return nil, ErrDocumentNotFound
+		}
+		return nil, err
+	}
+	// The content of the document changed, unlike when a schema is migrated
+	if err := s.docRepo.Update(ctx, doc); err != nil {
+		return nil, err
+	}
+
+	// The hashes belong to the owner, whoever redacted the document
+	if s.indexer != nil {
+		s.indexer.IndexEntities(ctx, doc.UserID, documentID, redactionSchema)
+	}
+
+	log.Info().
+		Int64("user_id", userID).
+		Int64("owner_id", doc.UserID).
+		Int64("document_id", documentID).
+		Msg("Document redaction schema updated")
+
+	return s.GetDocumentByID(ctx, userID, documentID)
+}
+
+// schemaVersionOf describes the current redaction schema of a document as a version
+// replaced by a user.
+func schemaVersionOf(doc *models.Document, replacedBy int64, encryptionKey []byte) (*models.DocumentSchemaVersion, error) {
+	schema, err := doc.DecryptRedactionSchema(encryptionKey)
+	if err != nil {
+		return nil, fmt.Errorf("failed to decrypt redaction schema: %w", err)
+	}
+	var redactionMapping models.RedactionMapping
+	if err := json.Unmarshal([]byte(schema), &redactionMapping); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal redaction schema: %w", err)
+	}
+
+	return &models.DocumentSchemaVersion{
+		DocumentID:      doc.ID,
+		RedactionSchema: doc.RedactionSchema,
+		EntityCount:     redactionMapping.EntityCount(),
+		EntityTypes:     redactionMapping.EntityTypes(),
+		ReplacedBy:      replacedBy,
+	}, nil
+}
+
+// GetSchemaVersions retrieves the earlier redaction schemas of a document a user owns or
+// that was shared with them, newest first.
+//
+// Parameters:
+//   - ctx: Context for the operation
+//   - userID: The ID of the user requesting the versions
+//   - documentID: The ID of the document
+//
+// Returns:
+//   - The versions of the document, without their schemas
+//   - ErrDocumentNotFound if the document doesn't exist or the user has no access to it
+//   - Other errors if retrieval fails
+func (s *DocumentService) GetSchemaVersions(ctx context.Context, userID, documentID int64) ([]*models.DocumentSchemaVersion, error) {
+	if _, err := s.authorizedDocument(ctx, userID, documentID, constants.SharePermissionRead); err != nil {
+		return nil, err
+	}
+
+	return s.docRepo.GetSchemaVersions(ctx, documentID)
+}
+
+// RollbackRedactionSchema restores an earlier redaction schema of a document a user owns or
+// that was shared with them for redaction. The schema being replaced is kept as a new
+// version, so a rollback can be undone.
+//
+// Parameters:
+//   - ctx: Context for the operation
+//   - userID: The ID of the user rolling back
+//   - documentID: The ID of the document
+//   - version: The number of the version to restore
+//
+// Returns:
+//   - The document with the restored redaction schema
+//   - ErrDocumentNotFound if the document doesn't exist or the user has no access to it
+//   - ErrDocumentReadOnly if the document is shared with the user for reading only
+//   - ErrDocumentArchived if the document is in cold storage
+//   - NotFoundError if the document has no such version
+//   - Other errors if the schema could not be restored
+func (s *DocumentService) RollbackRedactionSchema(ctx context.Context, userID, documentID int64, version int) (*models.Document, error) {
+	doc, err := s.authorizedDocument(ctx, userID, documentID, constants.SharePermissionRedact)
+	if err != nil {
+		return nil, err
+	}
+
+	schemaVersion, err := s.docRepo.GetSchemaVersion(ctx, doc.UserID, documentID, version)
+	if err != nil {
+		return nil, err
+	}
+
+	encryptionKey := []byte(os.Getenv("API_KEY_ENCRYPTION_KEY"))
+	stored := &models.Document{RedactionSchema: schemaVersion.RedactionSchema}
+	schema, err := stored.DecryptRedactionSchema(encryptionKey)
+	if err != nil {
+		return nil, fmt.Errorf("failed to decrypt redaction schema version: %w", err)
+	}
+	var redactionMapping models.RedactionMapping
+	if err := json.Unmarshal([]byte(schema), &redactionMapping); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal redaction schema version: %w", err)
+	}
+
+	log.Info().
+		Int64("user_id", userID).
+		Int64("document_id", documentID).
+		Int("version", version).
+		Msg("Rolling back document redaction schema")
+
+	return s.UpdateRedactionSchema(ctx, userID, documentID, redactionMapping)
+}
+
+// AddDetectedEntities adds a batch of detected entities to a document a user owns or that
+// was shared with them for redaction. The entities are added in one transaction, so either
+// all of them are added or none.
+//
+// Parameters:
+//   - ctx: Context for the operation
+//   - userID: The ID of the user adding the entities
+//   - documentID: The ID of the document
+//   - inputs: The detected entities to add
+//
+// Returns:
+//   - The IDs of the added entities in the order of the inputs
+//   - ErrDocumentNotFound if the document doesn't exist or the user has no access to it
+//   - ErrDocumentReadOnly if the document is shared with the user for reading only
+//   - ErrDocumentArchived if the document is in cold storage
+//   - Other errors if the entities could not be stored
+func (s *DocumentService) AddDetectedEntities(ctx context.Context, userID, documentID int64, inputs []models.DetectedEntityInput) (*models.DetectedEntityBatchResult, error) {
+	doc, err := s.authorizedDocument(ctx, userID, documentID, constants.SharePermissionRedact)
+	if err != nil {
+		return nil, err
+	}
+	if doc.ArchivedAt != nil {
+		return nil, ErrDocumentArchived
+	}
+
+	entities := make([]*models.DetectedEntity, len(inputs))
+	for i, input := range inputs {
+		entities[i] = models.NewDetectedEntity(documentID, input.MethodID, input.EntityName, input.RedactionSchema)
+		if input.DetectedTimestamp != nil {
+			entities[i].DetectedTimestamp = *input.DetectedTimestamp
+		}
+	}
+
+	if err := s.docRepo.AddDetectedEntities(ctx, documentID, entities); err != nil {
+		if errors.Is(err, utils.ErrNotFound) {
+			return nil, ErrDocumentNotFound
+		}
+		return nil, err
+	}
+
+	result := &models.DetectedEntityBatchResult{
+		DocumentID: documentID,
+		EntityIDs:  make([]int64, len(entities)),
+	}
+	for i, entity := range entities {
+		result.EntityIDs[i] = entity.ID
+	}
+
+	// The events go to the webhooks of the owner, who may have shared the document
+	if s.webhooks != nil {
+		s.webhooks.Publish(ctx, doc.UserID, constants.WebhookEventEntityDetected, &models.EntityDetectedEvent{
+			DocumentID: documentID,
+			EntityIDs:  result.EntityIDs,
+		})
+	}
+
+	log.Info().
+		Int64("user_id", userID).
+		Int64("document_id", documentID).
+		Int("count", len(entities)).
+		Msg("Detected entities added to document")
+
+	return result, nil
+}
+
+// GetDetectedEntities retrieves the detected entities of a document a user owns or that
+// was shared with them, newest first, selected by review status and detection method.
+//
+// Parameters:
+//   - ctx: Context for the operation
+//   - userID: The ID of the user reading the entities
+//   - documentID: The ID of the document
+//   - filter: The review status and detection method to select; the zero filter selects all
+//
+// Returns:
+//   - The matching detected entities
+//   - ErrDocumentNotFound if the document doesn't exist or the user has no access to it
+//   - Other errors if the entities could not be read
+func (s *DocumentService) GetDetectedEntities(ctx context.Context, userID, documentID int64, filter models.DetectedEntityFilter) ([]*models.DetectedEntityWithMethod, error) {
+	if _, err := s.authorizedDocument(ctx, userID, documentID, constants.SharePermissionRead); err != nil {
+		return nil, err
+	}
+
+	return s.docRepo.GetDetectedEntities(ctx, documentID, filter)
+}
+
+// ReviewEntities sets the review status of detected entities of a document a user owns or
+// that was shared with them for redaction. Either all entities are changed or none.
+// Rejected entities are false positives and are left out when the document is redacted.
+//
+// Parameters:
+//   - ctx: Context for the operation
+//   - userID: The ID of the user reviewing the entities
+//   - documentID: The ID of the document
+//   - entityIDs: The entities to change; duplicates are ignored
+//   - status: The new review status
+//
+// Returns:
+//   - The changed entities with their new status
+//   - ErrDocumentNotFound if the document doesn't exist or the user has no access to it
+//   - ErrDocumentReadOnly if the document is shared with the user for reading only
+//   - ErrDocumentArchived if the document is in cold storage
+//   - A validation error if the status is unknown
+//   - A not found error if an entity doesn't belong to the document
+//   - Other errors if the status could not be stored
+func (s *DocumentService) ReviewEntities(ctx context.Context, userID, documentID int64, entityIDs []int64, status models.EntityReviewStatus) (*models.EntityReviewResult, error) {
+	if !status.Valid() {
+		return nil, utils.NewValidationError("status", constants.MsgEntityStatusInvalid)
+	}
+
+	doc, err := s.authorizedDocument(ctx, userID, documentID, constants.SharePermissionRedact)
+	if err != nil {
+		return nil, err
+	}
+	if doc.ArchivedAt != nil {
+		return nil, ErrDocumentArchived
+	}
+
+	// The update counts the changed rows, so each entity may only be listed once
+	ids := make([]int64, 0, len(entityIDs))
+	seen := make(map[int64]bool, len(entityIDs))
+	for _, id := range entityIDs {
+		if !seen[id] {
+			seen[id] = true
+			ids = append(ids, id)
+		}
+	}
+
+	reviewedAt, err := s.docRepo.UpdateEntityStatus(ctx, documentID, ids, status, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	log.Info().
+		Int64("user_id", userID).
+		Int64("document_id", documentID).
+		Int("count", len(ids)).
+		Str("status", string(status)).
+		Msg("Detected entities reviewed")
+
+	return &models.EntityReviewResult{
+		DocumentID: documentID,
+		EntityIDs:  ids,
+		Status:     status,
+		ReviewedBy: userID,
+		ReviewedAt: reviewedAt,
+	}, nil
+}
+
+// GetDocumentTimeline retrieves the events of a document a user owns or that was shared
+// with them, oldest first.
+func (s *DocumentService) GetDocumentTimeline(ctx context.Context, userID, documentID int64, page, pageSize int) ([]*models.DocumentEvent, int, error) {
+	if _, err := s.authorizedDocument(ctx, userID, documentID, constants.SharePermissionRead); err != nil {
+		return nil, 0, err
+	}
+
+	events, total, err := s.docRepo.GetTimeline(ctx, documentID, page, pageSize)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	for _, event := range events {
+		event.Describe()
+	}
+
+	return events, total, nil
+}
+
+// ExportDocument assembles the full record of a document a user owns or that was shared
+// with them: its metadata, redaction schema, detected entities and complete timeline,
+// formatted for a locale.
+func (s *DocumentService) ExportDocument(ctx context.Context, userID, documentID int64, locale models.Locale) (*models.DocumentExport, error) {
+	doc, err := s.GetDocumentByID(ctx, userID, documentID)
+	if err != nil {
+		return nil, err
+	}
+
+	// GetDocumentByID has already verified the schema is valid JSON
+	var redactionMapping models.RedactionMapping
+	if err := json.Unmarshal([]byte(doc.RedactionSchema), &redactionMapping); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal redaction schema: %w", err)
+	}
+
+	entities, err := s.docRepo.GetDetectedEntities(ctx, documentID, models.DetectedEntityFilter{})
+	if err != nil {
+		return nil, err
+	}
+
+	// Read the whole timeline, page by page
+	var timeline []*models.DocumentEvent
+	for page := 1; ; page++ {
+		events, total, err := s.docRepo.GetTimeline(ctx, documentID, page, constants.DocumentExportTimelinePageSize)
+		if err != nil {
+			return nil, err
+		}
+		timeline = append(timeline, events...)
+		if len(events) == 0 || len(timeline) >= total {
+			break
+		}
+	}
+	for _, event := range timeline {
+		event.Describe()
+	}
+
+	log.Info().
+		Int64("user_id", userID).
+		Int64("document_id", documentID).
+		Int("entities", len(entities)).
+		Int("events", len(timeline)).
+		Msg("Document exported")
+
+	export := models.NewDocumentExport(doc, redactionMapping, entities, timeline, s.now())
+	export.Localize(locale)
+	return export, nil
+}
+
+// GetPageOverlay returns the redaction rectangles of one page of a document a user owns or
+// that was shared with them, in page-relative coordinates and colored by the detection
+// method of each redaction.
+// Pages without redactions, including pages beyond the last one, have no rectangles.
+func (s *DocumentService) GetPageOverlay(ctx context.Context, userID, documentID int64, page int) (*models.PageOverlay, error) {
+	doc, err := s.GetDocumentByID(ctx, userID, documentID)
+	if err != nil {
+		return nil, err
+	}
+
+	var redactionMapping models.RedactionMapping
+	if err := json.Unmarshal([]byte(doc.RedactionSchema), &redactionMapping); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal redaction schema: %w", err)
+	}
+	// Schemas that could not be normalized when read cannot be drawn either
+	if redactionMapping.Version != models.RedactionSchemaVersion {
+		if err := redactionMapping.Normalize(); err != nil {
+			return nil, fmt.Errorf("failed to normalize redaction schema: %w", err)
+		}
+	}
+
+	pageSchema := models.Page{PageNumber: page}
+	for _, p := range redactionMapping.Pages {
+		if p.PageNumber == page {
+			pageSchema = p
+			break
+		}
+	}
+	if len(pageSchema.Sensitive) == 0 {
+		return models.NewPageOverlay(documentID, pageSchema, nil), nil
+	}
+
+	entities, err := s.docRepo.GetDetectedEntities(ctx, documentID, models.DetectedEntityFilter{})
+	if err != nil {
+		return nil, err
+	}
+	methods := make(map[string]*models.DetectedEntityWithMethod, len(entities))
+	for _, entity := range entities {
+		methods[entity.EntityName] = entity
+	}
+
+	return models.NewPageOverlay(documentID, pageSchema, methods), nil
+}
+
+// RedactDocument applies the redaction schema of a document a user owns or that was shared
+// with them to its PDF, removing the redacted text, images and annotations before covering
+// them. Each redaction uses the method of its detected entity, or the given default method.
+// The PDF is the one uploaded with the request, or the content stored with the document.
+//
+// Parameters:
+//   - ctx: Context for the operation
+//   - userID: The ID of the user redacting the document
+//   - documentID: The ID of the document
+//   - method: The redaction method of redactions whose entity has none
+//   - content: The PDF to redact, or nil to redact the stored content
+//
+// Returns:
+//   - The redacted PDF with the number of items removed
+//   - ErrDocumentNotFound if the document doesn't exist or the user has no access to it
+//   - ErrDocumentContentNotFound if no PDF was uploaded and the document has no content
+//   - ErrDocumentNotRedactable if the PDF cannot be parsed or is encrypted
+//   - Other errors if the schema or the content could not be read
+func (s *DocumentService) RedactDocument(ctx context.Context, userID, documentID int64, method string, content []byte) (*redaction.Result, error) {
+	doc, err := s.GetDocumentByID(ctx, userID, documentID)
+	if err != nil {
+		return nil, err
+	}
+	if content == nil {
+		if _, content, err = s.GetDocumentContent(ctx, userID, documentID); err != nil {
+			return nil, err
+		}
+	} else if !models.IsPDF(content) {
+		return nil, utils.NewValidationError(constants.DocumentContentFormField, constants.MsgDocumentContentNotPDF)
+	}
+
+	var redactionMapping models.RedactionMapping
+	if err := json.Unmarshal([]byte(doc.RedactionSchema), &redactionMapping); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal redaction schema: %w", err)
+	}
+	if redactionMapping.Version != models.RedactionSchemaVersion {
+		if err := redactionMapping.Normalize(); err != nil {
+			return nil, fmt.Errorf("failed to normalize redaction schema: %w", err)
+		}
+	}
+
+	entities, err := s.docRepo.GetDetectedEntities(ctx, documentID, models.DetectedEntityFilter{})
+	if err != nil {
+		return nil, err
+	}
+	schemas := make(map[string]models.RedactionSchema, len(entities))
+	// Text is only kept when every entity detected for it was rejected as a false positive
+	rejected := make(map[string]bool, len(entities))
+	for _, entity := range entities {
+		schemas[entity.EntityName] = entity.RedactionSchema
+		if allRejected, ok := rejected[entity.EntityName]; !ok || allRejected {
+			rejected[entity.EntityName] = entity.Status == models.EntityStatusRejected
+		}
+	}
+
+	var areas []redaction.Area
+	for _, page := range redactionMapping.Pages {
+		for _, sensitive := range page.Sensitive {
+			if rejected[sensitive.OriginalText] {
+				continue
+			}
+			area := redaction.Area{Page: page.PageNumber, BBox: sensitive.BBox, Method: method, Label: sensitive.EntityType}
+			// Entities saved with another method, or none, use the default
+			switch method := schemas[sensitive.OriginalText].RedactionMethod; method {
+			case constants.RedactionMethodBlackout, constants.RedactionMethodMask, constants.RedactionMethodReplace:
+				area.Method = method
+			}
+			if area.Method == constants.RedactionMethodReplace {
+				if replacement := schemas[sensitive.OriginalText].ReplacementValue; replacement != "" {
+					area.Label = replacement
+				}
+			}
+			areas = append(areas, area)
+		}
+	}
+
+	result, err := redaction.Redact(content, areas)
+	if err != nil {
+		if errors.Is(err, redaction.ErrInvalidPDF) || errors.Is(err, redaction.ErrEncryptedPDF) || errors.Is(err, redaction.ErrUnsupportedPDF) {
+			log.Warn().Err(err).Int64("document_id", documentID).Msg("Document PDF cannot be redacted")
+			return nil, ErrDocumentNotRedactable
+		}
+		return nil, fmt.Errorf("failed to redact document: %w", err)
+	}
+
+	log.Info().
+		Int64("user_id", userID).
+		Int64("document_id", documentID).
+		Int("areas", len(areas)).
+		Int("redacted_pages", result.RedactedPages).
+		Int("glyphs", result.Glyphs).
+		Int("images", result.Images).
+		Int("annotations", result.Annotations).
+		Msg("Document redacted")
+
+	return result, nil
+}
@@ -0,0 +1,1126 @@
+package service
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"slices"
+	"sort"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/yasinhessnawi1/Hideme_Backend/internal/config"
+	"github.com/yasinhessnawi1/Hideme_Backend/internal/constants"
+	"github.com/yasinhessnawi1/Hideme_Backend/internal/models"
+	"github.com/yasinhessnawi1/Hideme_Backend/internal/repository"
+	"github.com/yasinhessnawi1/Hideme_Backend/internal/utils"
+)
+
+// MockProcessingAuditRepository is an in-memory implementation of repository.ProcessingAuditRepository
+type MockProcessingAuditRepository struct {
+	audits []*models.ProcessingAudit
+}
+
+func (m *MockProcessingAuditRepository) Create(ctx context.Context, audit *models.ProcessingAudit) error {
+	audit.ID = int64(len(m.audits) + 1)
+	copied := *audit
+	m.audits = append(m.audits, &copied)
+	return nil
+}
+
+// recordingDocumentHooks records the documents passed to the delivery and rule tracking hooks
+type recordingDocumentHooks struct {
+	delivered int
+	tracked   int
+}
+
+func (r *recordingDocumentHooks) DeliverDocument(userID, documentID int64) {
+	r.delivered++
+}
+
+func (r *recordingDocumentHooks) RecordDetections(ctx context.Context, userID int64, redactionSchema models.RedactionMapping) {
+	r.tracked++
+}
+
+// countingDocumentRepository reports a fixed document count; any other call panics
+type countingDocumentRepository struct {
+	repository.DocumentRepository
+	count int64
+}
+
+func (r *countingDocumentRepository) CountByUserID(ctx context.Context, userID int64) (int64, error) {
+	return r.count, nil
+}
+
+func TestDocumentService_UploadLimits(t *testing.T) {
+	docs := &countingDocumentRepository{count: 5}
+	svc := NewDocumentService(docs, &MockProcessingAuditRepository{}, nil)
+	ctx := context.Background()
+	schema := models.RedactionMapping{
+		Version: models.RedactionSchemaVersion,
+		Pages:   []models.Page{{PageNumber: 1}, {PageNumber: 2}, {PageNumber: 3}},
+	}
+
+	// expectLimit checks that err is an upload limit error for limit with the given usage
+	expectLimit := func(t *testing.T, err error, limit string, status int, current int64) {
+		t.Helper()
+		var appErr *utils.AppError
+		if !errors.As(err, &appErr) || !errors.Is(appErr.Err, utils.ErrUploadLimit) {
+			t.Fatalf("Expected an upload limit error, got %v", err)
+		}
+		if appErr.StatusCode != status || appErr.Details["limit"] != limit {
+			t.Errorf("Expected the %s limit with status %d, got %+v", limit, status, appErr)
+		}
+		if appErr.Details["current"] != current {
+			t.Errorf("Expected current usage %d, got %v", current, appErr.Details)
+		}
+	}
+
+	t.Run("No limits", func(t *testing.T) {
+		if err := svc.CheckUploadSize(1 << 30); err != nil {
+			t.Errorf("Expected no size limit, got %v", err)
+		}
+	})
+
+	svc.SetUploadLimits(&config.UploadLimitSettings{MaxDocuments: 5, MaxPages: 2, MaxBytes: 1024})
+
+	t.Run("Size", func(t *testing.T) {
+		if err := svc.CheckUploadSize(1024); err != nil {
+			t.Errorf("Expected an upload at the limit to pass, got %v", err)
+		}
+		if err := svc.CheckUploadSize(-1); err != nil {
+			t.Errorf("Expected an upload of unknown size to pass, got %v", err)
+		}
+		expectLimit(t, svc.CheckUploadSize(2048), constants.UploadLimitBytes, http.StatusRequestEntityTooLarge, 2048)
+	})
+
+	t.Run("Pages", func(t *testing.T) {
+		_, err := svc.ProcessEphemeral(ctx, 1, "scan.pdf", "nb", "", schema)
+		expectLimit(t, err, constants.UploadLimitPages, http.StatusRequestEntityTooLarge, 3)
+	})
+
+	t.Run("Documents", func(t *testing.T) {
+		_, err := svc.UploadDocument(ctx, 1, "scan.pdf", "nb", "", models.RedactionMapping{Pages: schema.Pages[:1]})
+		expectLimit(t, err, constants.UploadLimitDocuments, http.StatusForbidden, 5)
+	})
+
+	t.Run("Ephemeral uploads are not counted", func(t *testing.T) {
+		if _, err := svc.ProcessEphemeral(ctx, 1, "scan.pdf", "nb", "", models.RedactionMapping{Pages: schema.Pages[:1]}); err != nil {
+			t.Errorf("Expected an ephemeral upload to pass the document limit, got %v", err)
+		}
+	})
+}
+
+func TestDocumentService_ProcessEphemeral(t *testing.T) {
+	audits := &MockProcessingAuditRepository{}
+	hooks := &recordingDocumentHooks{}
+	// Without a document repository, any attempt to store the document panics
+	svc := NewDocumentService(nil, audits, nil)
+	svc.SetDeliverer(hooks)
+	svc.SetRuleTracker(hooks)
+	ctx := context.Background()
+
+	schema := models.RedactionMapping{
+		Version: models.RedactionSchemaVersion,
+		Pages: []models.Page{
+			{PageNumber: 1, Sensitive: []models.Sensitive{
+				{OriginalText: "Ola Nordmann", EntityType: "PERSON", BBox: models.BBox{X0: 0.1, Y0: 0.1, X1: 0.2, Y1: 0.2}},
+				{OriginalText: "12345678901", EntityType: "NO_FODSELSNUMMER", BBox: models.BBox{X0: 0.3, Y0: 0.1, X1: 0.4, Y1: 0.2}},
+			}},
+			{PageNumber: 2},
+		},
+	}
+
+	result, err := svc.ProcessEphemeral(ctx, 1, "patient_record.pdf", "nb", "", schema)
+	if err != nil {
+		t.Fatalf("ProcessEphemeral returned error: %v", err)
+	}
+
+	if result.AuditID != 1 || result.Language != "nb" || result.ProcessingMode != "ephemeral" {
+		t.Errorf("Unexpected result: %+v", result)
+	}
+	if len(audits.audits) != 1 || audits.audits[0].PageCount != 2 || audits.audits[0].EntityCount != 2 {
+		t.Errorf("Expected an audit stub with 2 pages and 2 entities, got %+v", audits.audits)
+	}
+	if hooks.delivered != 0 || hooks.tracked != 0 {
+		t.Errorf("Expected no delivery or rule tracking, got %d deliveries and %d tracked", hooks.delivered, hooks.tracked)
+	}
+
+	t.Run("Invalid language", func(t *testing.T) {
+		if _, err := svc.ProcessEphemeral(ctx, 1, "patient_record.pdf", "not a language", "", schema); !utils.IsValidationError(err) {
+			t.Errorf("Expected a validation error, got %v", err)
+		}
+		if len(audits.audits) != 1 {
+			t.Error("Expected no audit stub for a rejected document")
+		}
+	})
+}
+
+// archivingDocumentRepository serves documents from memory; any other call panics
+type archivingDocumentRepository struct {
+	repository.DocumentRepository
+	docs map[int64]*models.Document
+}
+
+func (r *archivingDocumentRepository) GetByID(ctx context.Context, id int64) (*models.Document, error) {
+	doc, ok := r.docs[id]
+	if !ok {
+		return nil, utils.NewNotFoundError("Document", id)
+	}
+	copied := *doc
+	return &copied, nil
+}
+
+// MockDocumentArchiveRepository records the calls made to a repository.DocumentArchiveRepository
+type MockDocumentArchiveRepository struct {
+	cutoff   time.Time
+	limit    int
+	archived []int64
+	restored []int64
+}
+
+func (m *MockDocumentArchiveRepository) Archive(ctx context.Context, cutoff, now time.Time, limit int) (int64, error) {
+	m.cutoff = cutoff
+	m.limit = limit
+	return 1, nil
+}
+
+func (m *MockDocumentArchiveRepository) ArchiveDocuments(ctx context.Context, documentIDs []int64, now time.Time) (int64, error) {
+	m.archived = append(m.archived, documentIDs...)
+	return int64(len(documentIDs)), nil
+}
+
+func (m *MockDocumentArchiveRepository) Restore(ctx context.Context, documentID int64) error {
+	m.restored = append(m.restored, documentID)
+	return nil
+}
+
+func TestDocumentService_Archive(t *testing.T) {
+	archivedAt := time.Now()
+	docs := &archivingDocumentRepository{docs: map[int64]*models.Document{
+		1: {ID: 1, UserID: 1, ArchivedAt: &archivedAt},
+		2: {ID: 2, UserID: 1},
+	}}
+	archive := &MockDocumentArchiveRepository{}
+	svc := NewDocumentService(docs, &MockProcessingAuditRepository{}, nil)
+	ctx := context.Background()
+
+	t.Run("Disabled without an archive", func(t *testing.T) {
+		if archived, err := svc.ArchiveOldDocuments(ctx); err != nil || archived != 0 {
+			t.Errorf("Expected nothing archived, got %d (err %v)", archived, err)
+		}
+	})
+
+	svc.SetArchive(archive, &config.DocumentArchiveSettings{AfterDays: 30})
+
+	t.Run("Archive old documents", func(t *testing.T) {
+		before := time.Now().AddDate(0, 0, -30)
+		if archived, err := svc.ArchiveOldDocuments(ctx); err != nil || archived != 1 {
+			t.Fatalf("Expected one document archived, got %d (err %v)", archived, err)
+		}
+		if archive.cutoff.Before(before) || archive.cutoff.After(time.Now().AddDate(0, 0, -30)) {
+			t.Errorf("Expected a cutoff 30 days ago, got %v", archive.cutoff)
+		}
+		if archive.limit != constants.DocumentArchiveBatchSize {
+			t.Errorf("Expected batch size %d, got %d", constants.DocumentArchiveBatchSize, archive.limit)
+		}
+	})
+
+	t.Run("Archived documents cannot be read", func(t *testing.T) {
+		if _, err := svc.GetDocumentByID(ctx, 1, 1); !errors.Is(err, ErrDocumentArchived) {
+			t.Errorf("Expected ErrDocumentArchived, got %v", err)
+		}
+	})
+
+	t.Run("Archived documents have no overlays", func(t *testing.T) {
+		if _, err := svc.GetPageOverlay(ctx, 1, 1, 1); !errors.Is(err, ErrDocumentArchived) {
+			t.Errorf("Expected ErrDocumentArchived, got %v", err)
+		}
+	})
+
+	t.Run("Restore a document of another user", func(t *testing.T) {
+		if _, err := svc.RestoreFromArchive(ctx, 2, 1); !errors.Is(err, ErrDocumentNotFound) {
+			t.Errorf("Expected ErrDocumentNotFound, got %v", err)
+		}
+	})
+
+	t.Run("Restore a document in hot storage", func(t *testing.T) {
+		if _, err := svc.RestoreFromArchive(ctx, 1, 2); !errors.Is(err, ErrDocumentNotArchived) {
+			t.Errorf("Expected ErrDocumentNotArchived, got %v", err)
+		}
+		if len(archive.restored) != 0 {
+			t.Errorf("Expected nothing restored, got %v", archive.restored)
+		}
+	})
+}
+
+// memoryDocumentRepository stores documents in memory; any other call panics
+type memoryDocumentRepository struct {
+	repository.DocumentRepository
+	docs     map[int64]*models.Document
+	entities map[int64][]*models.DetectedEntityWithMethod
+	versions []*models.DocumentSchemaVersion
+	nextID   int64
+}
+
+func (r *memoryDocumentRepository) Create(ctx context.Context, doc *models.Document) error {
+	r.nextID++
+	doc.ID = r.nextID
+	copied := *doc
+	r.docs[doc.ID] = &copied
+	return nil
+}
+
+func (r *memoryDocumentRepository) GetByID(ctx context.Context, id int64) (*models.Document, error) {
+	doc, ok := r.docs[id]
+	if !ok {
+		return nil, utils.NewNotFoundError("Document", id)
+	}
+	copied := *doc
+	return &copied, nil
+}
+
+func (r *memoryDocumentRepository) GetDetectedEntities(ctx context.Context, documentID int64, filter models.DetectedEntityFilter) ([]*models.DetectedEntityWithMethod, error) {
+	var entities []*models.DetectedEntityWithMethod
+	for _, entity := range r.entities[documentID] {
+		if (filter.Status == "" || entity.Status == filter.Status) && (filter.MethodID == 0 || entity.MethodID == filter.MethodID) {
+			entities = append(entities, entity)
+		}
+	}
+	return entities, nil
+}
+
+func (r *memoryDocumentRepository) UpdateEntityStatus(ctx context.Context, documentID int64, entityIDs []int64, status models.EntityReviewStatus, reviewedBy int64) (time.Time, error) {
+	var matched []*models.DetectedEntityWithMethod
+	for _, entity := range r.entities[documentID] {
+		if slices.Contains(entityIDs, entity.ID) {
+			matched = append(matched, entity)
+		}
+	}
+	if len(matched) != len(entityIDs) {
+		return time.Time{}, utils.NewNotFoundError("DetectedEntity", entityIDs)
+	}
+	now := time.Now()
+	for _, entity := range matched {
+		entity.Status = status
+		entity.ReviewedBy = &reviewedBy
+		entity.ReviewedAt = &now
+	}
+	return now, nil
+}
+
+func (r *memoryDocumentRepository) Delete(ctx context.Context, id int64) error {
+	delete(r.docs, id)
+	return nil
+}
+
+func (r *memoryDocumentRepository) CountByUserID(ctx context.Context, userID int64) (int64, error) {
+	return int64(len(r.docs)), nil
+}
+
+func (r *memoryDocumentRepository) UpdateRedactionSchema(ctx context.Context, doc *models.Document) error {
+	stored, ok := r.docs[doc.ID]
+	if !ok {
+		return utils.NewNotFoundError("Document", doc.ID)
+	}
+	stored.RedactionSchema = doc.RedactionSchema
+	stored.SchemaVersion = doc.SchemaVersion
+	stored.EntityCount = doc.EntityCount
+	stored.EntityTypes = doc.EntityTypes
+	return nil
+}
+
+func (r *memoryDocumentRepository) SearchByUserID(ctx context.Context, userID int64, filter models.DocumentSearchFilter, fn func(*models.Document) error) error {
+	var docs []*models.Document
+	for _, doc := range r.docs {
+		if doc.UserID != userID ||
+			(filter.UploadedFrom != nil && doc.UploadTimestamp.Before(*filter.UploadedFrom)) ||
+			(filter.UploadedTo != nil && !doc.UploadTimestamp.Before(*filter.UploadedTo)) ||
+			(filter.MinEntities != nil && (doc.EntityCount == nil || *doc.EntityCount < *filter.MinEntities)) ||
+			(filter.MaxEntities != nil && (doc.EntityCount == nil || *doc.EntityCount > *filter.MaxEntities)) {
+			continue
+		}
+		matches := true
+		for _, entityType := range filter.EntityTypes {
+			matches = matches && slices.Contains(doc.EntityTypes, entityType)
+		}
+		if matches {
+			copied := *doc
+			docs = append(docs, &copied)
+		}
+	}
+	sort.Slice(docs, func(i, j int) bool { return docs[i].ID > docs[j].ID })
+	for _, doc := range docs {
+		if err := fn(doc); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (r *memoryDocumentRepository) GetMissingEntitySummaries(ctx context.Context, limit int) ([]*models.Document, error) {
+	var docs []*models.Document
+	for _, doc := range r.docs {
+		if doc.EntityCount == nil && len(docs) < limit {
+			copied := *doc
+			docs = append(docs, &copied)
+		}
+	}
+	return docs, nil
+}
+
+func (r *memoryDocumentRepository) UpdateEntitySummary(ctx context.Context, doc *models.Document) error {
+	stored, ok := r.docs[doc.ID]
+	if !ok {
+		return utils.NewNotFoundError("Document", doc.ID)
+	}
+	stored.EntityCount = doc.EntityCount
+	stored.EntityTypes = doc.EntityTypes
+	return nil
+}
+
+func (r *memoryDocumentRepository) Update(ctx context.Context, doc *models.Document) error {
+	stored, ok := r.docs[doc.ID]
+	if !ok {
+		return utils.NewNotFoundError("Document", doc.ID)
+	}
+	stored.LastModified = time.Now()
+	return nil
+}
+
+func (r *memoryDocumentRepository) CreateSchemaVersion(ctx context.Context, ownerID int64, version *models.DocumentSchemaVersion) error {
+	version.Version = 1
+	for _, v := range r.versions {
+		if v.DocumentID == version.DocumentID && v.Version >= version.Version {
+			version.Version = v.Version + 1
+		}
+	}
+	version.ReplacedAt = time.Now()
+	copied := *version
+	r.versions = append(r.versions, &copied)
+	return nil
+}
+
+func (r *memoryDocumentRepository) GetSchemaVersions(ctx context.Context, documentID int64) ([]*models.DocumentSchemaVersion, error) {
+	versions := []*models.DocumentSchemaVersion{}
+	for i := len(r.versions) - 1; i >= 0; i-- {
+		if r.versions[i].DocumentID == documentID {
+			listed := *r.versions[i]
+			listed.RedactionSchema = ""
+			versions = append(versions, &listed)
+		}
+	}
+	return versions, nil
+}
+
+func (r *memoryDocumentRepository) GetSchemaVersion(ctx context.Context, ownerID, documentID int64, version int) (*models.DocumentSchemaVersion, error) {
+	for _, v := range r.versions {
+		if v.DocumentID == documentID && v.Version == version {
+			copied := *v
+			return &copied, nil
+		}
+	}
+	return nil, utils.NewNotFoundError("Redaction schema version", version)
+}
+
+func (r *memoryDocumentRepository) AddDetectedEntities(ctx context.Context, documentID int64, entities []*models.DetectedEntity) error {
+	if _, ok := r.docs[documentID]; !ok {
+		return utils.NewNotFoundError("Document", documentID)
+	}
+	for _, entity := range entities {
+		r.nextID++
+		entity.ID = r.nextID
+		r.entities[documentID] = append(r.entities[documentID], &models.DetectedEntityWithMethod{DetectedEntity: *entity})
+	}
+	return nil
+}
+
+// MockDocumentContentRepository keeps document contents in memory, failing Create while err is set
+type MockDocumentContentRepository struct {
+	contents map[int64]*models.DocumentContent
+	docs     *memoryDocumentRepository
+	err      error
+}
+
+func (m *MockDocumentContentRepository) Create(ctx context.Context, content *models.DocumentContent) error {
+	if m.err != nil {
+		return m.err
+	}
+	copied := *content
+	m.contents[content.DocumentID] = &copied
+	return nil
+}
+
+func (m *MockDocumentContentRepository) GetByDocumentID(ctx context.Context, documentID int64) (*models.DocumentContent, error) {
+	content, ok := m.contents[documentID]
+	if !ok {
+		return nil, utils.NewNotFoundError("DocumentContent", documentID)
+	}
+	copied := *content
+	return &copied, nil
+}
+
+func (m *MockDocumentContentRepository) ListOrphaned(ctx context.Context, limit int) ([]*models.DocumentContent, error) {
+	var orphaned []*models.DocumentContent
+	for id, content := range m.contents {
+		if _, ok := m.docs.docs[id]; !ok {
+			orphaned = append(orphaned, content)
+		}
+	}
+	return orphaned, nil
+}
+
+func (m *MockDocumentContentRepository) Delete(ctx context.Context, documentID int64) error {
+	delete(m.contents, documentID)
+	return nil
+}
+
+// memoryDocumentStore keeps document content in memory
+type memoryDocumentStore struct {
+	objects map[string][]byte
+
+	// rangeBytes counts the bytes read with GetRange
+	rangeBytes int64
+}
+
+func (s *memoryDocumentStore) Put(ctx context.Context, key string, content []byte) error {
+	s.objects[key] = content
+	return nil
+}
+
+func (s *memoryDocumentStore) Get(ctx context.Context, key string) ([]byte, error) {
+	content, ok := s.objects[key]
+	if !ok {
+		return nil, errDocumentObjectNotFound
+	}
+	return content, nil
+}
+
+func (s *memoryDocumentStore) GetRange(ctx context.Context, key string, offset, length int64) (io.ReadCloser, error) {
+	content, ok := s.objects[key]
+	if !ok {
+		return nil, errDocumentObjectNotFound
+	}
+	part := content[min(offset, int64(len(content))):min(offset+length, int64(len(content)))]
+	return io.NopCloser(&countingReader{r: bytes.NewReader(part), n: &s.rangeBytes}), nil
+}
+
+// countingReader counts the bytes read from a reader
+type countingReader struct {
+	r io.Reader
+	n *int64
+}
+
+func (c *countingReader) Read(p []byte) (int, error) {
+	n, err := c.r.Read(p)
+	*c.n += int64(n)
+	return n, err
+}
+
+func (s *memoryDocumentStore) Delete(ctx context.Context, key string) error {
+	delete(s.objects, key)
+	return nil
+}
+
+// stubContentKeys hands out one data key per user
+type stubContentKeys struct {
+	keys map[int64][]byte
+}
+
+func (s *stubContentKeys) GetOrCreateDataKey(ctx context.Context, userID int64) ([]byte, error) {
+	if _, ok := s.keys[userID]; !ok {
+		s.keys[userID] = bytes.Repeat([]byte{byte(userID)}, constants.DataKeySize)
+	}
+	return s.keys[userID], nil
+}
+
+func (s *stubContentKeys) GetDataKey(ctx context.Context, userID int64) ([]byte, error) {
+	return s.keys[userID], nil
+}
+
+func TestDocumentService_Content(t *testing.T) {
+	t.Setenv("API_KEY_ENCRYPTION_KEY", "0123456789abcdef0123456789abcdef")
+	docs := &memoryDocumentRepository{docs: map[int64]*models.Document{}}
+	contents := &MockDocumentContentRepository{contents: map[int64]*models.DocumentContent{}, docs: docs}
+	store := &memoryDocumentStore{objects: map[string][]byte{}}
+	hooks := &recordingDocumentHooks{}
+	svc := NewDocumentService(docs, &MockProcessingAuditRepository{}, nil)
+	svc.SetDeliverer(hooks)
+	svc.SetContentStorage(contents, store, &stubContentKeys{keys: map[int64][]byte{}})
+	ctx := context.Background()
+	schema := models.RedactionMapping{Version: models.RedactionSchemaVersion, Pages: []models.Page{{PageNumber: 1}}}
+	pdf := []byte("%PDF-1.7\nOla Nordmann\n%%EOF")
+
+	doc, err := svc.UploadDocumentWithContent(ctx, 1, "contract.pdf", "nb", "", schema, pdf)
+	if err != nil {
+		t.Fatalf("UploadDocumentWithContent() error = %v", err)
+	}
+
+	// The content is stored encrypted, under a key that doesn't name the file
+	stored := contents.contents[doc.ID]
+	if stored == nil || stored.SizeBytes != int64(len(pdf)) || stored.ContentType != constants.ContentTypePDF {
+		t.Fatalf("stored content = %+v, want the PDF linked to the document", stored)
+	}
+	if bytes.Contains(store.objects[stored.StorageKey], []byte("Ola Nordmann")) || strings.Contains(stored.StorageKey, "contract") {
+		t.Errorf("storage holds %q under %q, want it encrypted under an opaque key", store.objects[stored.StorageKey], stored.StorageKey)
+	}
+
+	t.Run("Read back", func(t *testing.T) {
+		_, content, err := svc.GetDocumentContent(ctx, 1, doc.ID)
+		if err != nil || !bytes.Equal(content, pdf) {
+			t.Errorf("GetDocumentContent() = %q, %v, want the uploaded PDF", content, err)
+		}
+	})
+
+	t.Run("Other users cannot read it", func(t *testing.T) {
+		if _, _, err := svc.GetDocumentContent(ctx, 2, doc.ID); !errors.Is(err, ErrDocumentNotFound) {
+			t.Errorf("GetDocumentContent() error = %v, want ErrDocumentNotFound", err)
+		}
+		if _, _, err := svc.OpenDocumentContent(ctx, 2, doc.ID); !errors.Is(err, ErrDocumentNotFound) {
+			t.Errorf("OpenDocumentContent() error = %v, want ErrDocumentNotFound", err)
+		}
+	})
+
+	t.Run("Ranges read only their segments", func(t *testing.T) {
+		large := append([]byte("%PDF-1.7\n"), bytes.Repeat([]byte("0123456789"), 3*constants.DocumentContentSegmentSize/10)...)
+		largeDoc, err := svc.UploadDocumentWithContent(ctx, 1, "large.pdf", "nb", "", schema, large)
+		if err != nil {
+			t.Fatalf("UploadDocumentWithContent() error = %v", err)
+		}
+
+		_, reader, err := svc.OpenDocumentContent(ctx, 1, largeDoc.ID)
+		if err != nil {
+			t.Fatalf("OpenDocumentContent() error = %v", err)
+		}
+		defer reader.Close()
+
+		store.rangeBytes = 0
+		offset := int64(2*constants.DocumentContentSegmentSize + 100)
+		if _, err := reader.Seek(offset, io.SeekStart); err != nil {
+			t.Fatalf("Seek() error = %v", err)
+		}
+		part := make([]byte, 20)
+		if _, err := io.ReadFull(reader, part); err != nil || !bytes.Equal(part, large[offset:offset+20]) {
+			t.Errorf("read %q, %v, want %q", part, err, large[offset:offset+20])
+		}
+		if store.rangeBytes > constants.DocumentContentSegmentSize+utils.SegmentOverhead {
+			t.Errorf("read %d bytes from the storage, want one segment", store.rangeBytes)
+		}
+
+		// Reading on continues with the following segments
+		if _, err := reader.Seek(0, io.SeekStart); err != nil {
+			t.Fatalf("Seek() error = %v", err)
+		}
+		if all, err := io.ReadAll(reader); err != nil || !bytes.Equal(all, large) {
+			t.Errorf("ReadAll() read %d bytes, %v, want the uploaded PDF", len(all), err)
+		}
+		delete(store.objects, contents.contents[largeDoc.ID].StorageKey)
+		delete(contents.contents, largeDoc.ID)
+		delete(docs.docs, largeDoc.ID)
+	})
+
+	t.Run("Content encrypted as a whole", func(t *testing.T) {
+		legacy, err := svc.UploadDocument(ctx, 1, "legacy.pdf", "nb", "", schema)
+		if err != nil {
+			t.Fatalf("UploadDocument() error = %v", err)
+		}
+		key, _ := svc.contentKeys.GetDataKey(ctx, 1)
+		sealed, _ := utils.EncryptBytes(pdf, key)
+		store.objects["1/legacy"] = sealed
+		contents.contents[legacy.ID] = &models.DocumentContent{DocumentID: legacy.ID, StorageKey: "1/legacy", SizeBytes: int64(len(pdf))}
+
+		if _, content, err := svc.GetDocumentContent(ctx, 1, legacy.ID); err != nil || !bytes.Equal(content, pdf) {
+			t.Errorf("GetDocumentContent() = %q, %v, want the stored PDF", content, err)
+		}
+		delete(contents.contents, legacy.ID)
+		delete(store.objects, "1/legacy")
+		delete(docs.docs, legacy.ID)
+	})
+
+	t.Run("Documents without content", func(t *testing.T) {
+		plain, err := svc.UploadDocument(ctx, 1, "notes.pdf", "nb", "", schema)
+		if err != nil {
+			t.Fatalf("UploadDocument() error = %v", err)
+		}
+		if _, _, err := svc.GetDocumentContent(ctx, 1, plain.ID); !errors.Is(err, ErrDocumentContentNotFound) {
+			t.Errorf("GetDocumentContent() error = %v, want ErrDocumentContentNotFound", err)
+		}
+	})
+
+	t.Run("Only PDFs are accepted", func(t *testing.T) {
+		if _, err := svc.UploadDocumentWithContent(ctx, 1, "notes.txt", "nb", "", schema, []byte("plain text")); !utils.IsValidationError(err) {
+			t.Errorf("UploadDocumentWithContent() error = %v, want a validation error", err)
+		}
+	})
+
+	t.Run("Failed link keeps nothing", func(t *testing.T) {
+		delivered, documents, objects := hooks.delivered, len(docs.docs), len(store.objects)
+		contents.err = errors.New("connection reset")
+		defer func() { contents.err = nil }()
+
+		if _, err := svc.UploadDocumentWithContent(ctx, 1, "contract.pdf", "nb", "", schema, pdf); err == nil {
+			t.Fatal("UploadDocumentWithContent() succeeded, want the link error")
+		}
+		if len(docs.docs) != documents || len(store.objects) != objects || hooks.delivered != delivered {
+			t.Errorf("kept %d documents and %d objects, delivered %d, want nothing kept or delivered", len(docs.docs), len(store.objects), hooks.delivered)
+		}
+	})
+
+	t.Run("Content of deleted documents is deleted", func(t *testing.T) {
+		if err := svc.DeleteDocumentByID(ctx, 1, doc.ID); err != nil {
+			t.Fatalf("DeleteDocumentByID() error = %v", err)
+		}
+		if deleted, err := svc.DeleteOrphanedContents(ctx); err != nil || deleted != 1 {
+			t.Fatalf("DeleteOrphanedContents() = %d, %v, want one content deleted", deleted, err)
+		}
+		if _, ok := store.objects[stored.StorageKey]; ok || len(contents.contents) != 0 {
+			t.Errorf("storage still holds %v, want the content deleted", store.objects)
+		}
+	})
+}
+
+// textPDF builds a one-page US Letter PDF showing text at 72,700 in 12 point Helvetica
+func textPDF(text string) []byte {
+	content := fmt.Sprintf("BT /F1 12 Tf 72 700 Td (%s) Tj ET", text)
+	objects := []string{
+		"<< /Type /Catalog /Pages 2 0 R >>",
+		"<< /Type /Pages /Kids [3 0 R] /Count 1 /MediaBox [0 0 612 792] >>",
+		"<< /Type /Page /Parent 2 0 R /Resources << /Font << /F1 4 0 R >> >> /Contents 5 0 R >>",
+		"<< /Type /Font /Subtype /Type1 /BaseFont /Helvetica >>",
+		fmt.Sprintf("<< /Length %d >>\nstream\n%s\nendstream", len(content), content),
+	}
+	var buf bytes.Buffer
+	buf.WriteString("%PDF-1.4\n")
+	offsets := make([]int, len(objects))
+	for i, obj := range objects {
+		offsets[i] = buf.Len()
+		fmt.Fprintf(&buf, "%d 0 obj\n%s\nendobj\n", i+1, obj)
+	}
+	xref := buf.Len()
+	fmt.Fprintf(&buf, "xref\n0 %d\n0000000000 65535 f \n", len(objects)+1)
+	for _, offset := range offsets {
+		fmt.Fprintf(&buf, "%010d 00000 n \n", offset)
+	}
+	fmt.Fprintf(&buf, "trailer\n<< /Size %d /Root 1 0 R >>\nstartxref\n%d\n%%%%EOF\n", len(objects)+1, xref)
+	return buf.Bytes()
+}
+
+func TestDocumentService_Redact(t *testing.T) {
+	t.Setenv("API_KEY_ENCRYPTION_KEY", "0123456789abcdef0123456789abcdef")
+	docs := &memoryDocumentRepository{docs: map[int64]*models.Document{}, entities: map[int64][]*models.DetectedEntityWithMethod{}}
+	contents := &MockDocumentContentRepository{contents: map[int64]*models.DocumentContent{}, docs: docs}
+	svc := NewDocumentService(docs, &MockProcessingAuditRepository{}, nil)
+	svc.SetContentStorage(contents, &memoryDocumentStore{objects: map[string][]byte{}}, &stubContentKeys{keys: map[int64][]byte{}})
+	ctx := context.Background()
+	// "Kari Nordmann" is drawn from 96 to 177 points across and 697 to 712 points up
+	schema := models.RedactionMapping{Version: models.RedactionSchemaVersion, Pages: []models.Page{{
+		PageNumber: 1,
+		Width:      612,
+		Height:     792,
+		Sensitive: []models.Sensitive{{
+			OriginalText: "Kari Nordmann",
+			EntityType:   "PERSON",
+			BBox:         models.BBox{X0: 97.0 / 612, Y0: 77.0 / 792, X1: 180.0 / 612, Y1: 102.0 / 792},
+		}},
+	}}}
+	pdf := textPDF("Call Kari Nordmann")
+
+	doc, err := svc.UploadDocumentWithContent(ctx, 1, "letter.pdf", "nb", "", schema, pdf)
+	if err != nil {
+		t.Fatalf("UploadDocumentWithContent() error = %v", err)
+	}
+	docs.entities[doc.ID] = []*models.DetectedEntityWithMethod{{DetectedEntity: models.DetectedEntity{
+		EntityName:      "Kari Nordmann",
+		RedactionSchema: models.RedactionSchema{RedactionMethod: constants.RedactionMethodReplace, ReplacementValue: "[name]"},
+	}}}
+
+	t.Run("Stored content", func(t *testing.T) {
+		result, err := svc.RedactDocument(ctx, 1, doc.ID, constants.RedactionMethodBlackout, nil)
+		if err != nil {
+			t.Fatalf("RedactDocument() error = %v", err)
+		}
+		if !models.IsPDF(result.PDF) || result.RedactedPages != 1 || result.Glyphs != len("Kari Nordmann") {
+			t.Errorf("RedactDocument() = %+v, want the name removed from page 1", result)
+		}
+	})
+
+	t.Run("Uploaded PDF", func(t *testing.T) {
+		result, err := svc.RedactDocument(ctx, 1, doc.ID, constants.RedactionMethodBlackout, textPDF("Dear Kari Nordmann"))
+		if err != nil {
+			t.Fatalf("RedactDocument() error = %v", err)
+		}
+		if result.Glyphs == 0 {
+			t.Errorf("RedactDocument() removed nothing from the uploaded PDF")
+		}
+	})
+
+	t.Run("Other users cannot redact it", func(t *testing.T) {
+		if _, err := svc.RedactDocument(ctx, 2, doc.ID, constants.RedactionMethodBlackout, nil); !errors.Is(err, ErrDocumentNotFound) {
+			t.Errorf("RedactDocument() error = %v, want ErrDocumentNotFound", err)
+		}
+	})
+
+	t.Run("Uploads must be PDFs", func(t *testing.T) {
+		if _, err := svc.RedactDocument(ctx, 1, doc.ID, constants.RedactionMethodBlackout, []byte("plain text")); !utils.IsValidationError(err) {
+			t.Errorf("RedactDocument() error = %v, want a validation error", err)
+		}
+	})
+
+	t.Run("Damaged PDF", func(t *testing.T) {
+		if _, err := svc.RedactDocument(ctx, 1, doc.ID, constants.RedactionMethodBlackout, []byte("%PDF-1.7\ngarbage")); !errors.Is(err, ErrDocumentNotRedactable) {
+			t.Errorf("RedactDocument() error = %v, want ErrDocumentNotRedactable", err)
+		}
+	})
+
+	t.Run("Rejected entities are kept", func(t *testing.T) {
+		docs.entities[doc.ID][0].Status = models.EntityStatusRejected
+		defer func() { docs.entities[doc.ID][0].Status = models.EntityStatusPending }()
+		result, err := svc.RedactDocument(ctx, 1, doc.ID, constants.RedactionMethodBlackout, nil)
+		if err != nil {
+			t.Fatalf("RedactDocument() error = %v", err)
+		}
+		if result.Glyphs != 0 {
+			t.Errorf("RedactDocument() removed %d glyphs of a rejected entity, want none", result.Glyphs)
+		}
+	})
+
+	t.Run("Documents without content", func(t *testing.T) {
+		plain, err := svc.UploadDocument(ctx, 1, "notes.pdf", "nb", "", schema)
+		if err != nil {
+			t.Fatalf("UploadDocument() error = %v", err)
+		}
+		if _, err := svc.RedactDocument(ctx, 1, plain.ID, constants.RedactionMethodBlackout, nil); !errors.Is(err, ErrDocumentContentNotFound) {
+			t.Errorf("RedactDocument() error = %v, want ErrDocumentContentNotFound", err)
+		}
+	})
+}
+
+func TestDocumentService_Search(t *testing.T) {
+	t.Setenv("API_KEY_ENCRYPTION_KEY", "0123456789abcdef0123456789abcdef")
+	docs := &memoryDocumentRepository{docs: map[int64]*models.Document{}}
+	svc := NewDocumentService(docs, &MockProcessingAuditRepository{}, nil)
+	ctx := context.Background()
+
+	contract, err := svc.UploadDocument(ctx, 1, "Contract Nordmann.pdf", "nb", "", sensitiveSchema("PERSON", "Ola Nordmann", "EMAIL_ADDRESS", "ola@example.com"))
+	if err != nil {
+		t.Fatalf("UploadDocument() error = %v", err)
+	}
+	invoice, err := svc.UploadDocument(ctx, 1, "invoice.pdf", "nb", "", sensitiveSchema("PERSON", "Kari Nordmann"))
+	if err != nil {
+		t.Fatalf("UploadDocument() error = %v", err)
+	}
+	if _, err := svc.UploadDocument(ctx, 2, "contract.pdf", "nb", "", sensitiveSchema("PERSON", "Ola Nordmann")); err != nil {
+		t.Fatalf("UploadDocument() error = %v", err)
+	}
+
+	ids := func(found []*models.Document) []int64 {
+		var ids []int64
+		for _, doc := range found {
+			ids = append(ids, doc.ID)
+		}
+		return ids
+	}
+
+	t.Run("Name", func(t *testing.T) {
+		found, total, err := svc.SearchDocuments(ctx, 1, models.DocumentSearchFilter{Name: "contract"}, 1, 10)
+		if err != nil || total != 1 || len(found) != 1 || found[0].ID != contract.ID || found[0].HashedDocumentName != "Contract Nordmann.pdf" {
+			t.Errorf("SearchDocuments() = %v, %d, %v, want the decrypted contract of the user", ids(found), total, err)
+		}
+	})
+
+	t.Run("Entities", func(t *testing.T) {
+		minEntities := 2
+		found, total, err := svc.SearchDocuments(ctx, 1, models.DocumentSearchFilter{EntityTypes: []string{"PERSON"}, MinEntities: &minEntities}, 1, 10)
+		if err != nil || total != 1 || len(found) != 1 || found[0].ID != contract.ID {
+			t.Errorf("SearchDocuments() = %v, %d, %v, want the contract", ids(found), total, err)
+		}
+	})
+
+	t.Run("Pagination", func(t *testing.T) {
+		found, total, err := svc.SearchDocuments(ctx, 1, models.DocumentSearchFilter{}, 2, 1)
+		if err != nil || total != 2 || len(found) != 1 || found[0].ID != contract.ID {
+			t.Errorf("SearchDocuments() = %v, %d, %v, want the older document on the second page", ids(found), total, err)
+		}
+	})
+
+	t.Run("Redaction updates the summary", func(t *testing.T) {
+		if _, err := svc.UpdateRedactionSchema(ctx, 1, invoice.ID, sensitiveSchema("PERSON", "Kari Nordmann", "PHONE_NUMBER", "+47 912 34 567")); err != nil {
+			t.Fatalf("UpdateRedactionSchema() error = %v", err)
+		}
+		found, _, err := svc.SearchDocuments(ctx, 1, models.DocumentSearchFilter{EntityTypes: []string{"PHONE_NUMBER"}}, 1, 10)
+		if err != nil || len(found) != 1 || found[0].ID != invoice.ID {
+			t.Errorf("SearchDocuments() = %v, %v, want the redacted invoice", ids(found), err)
+		}
+	})
+
+	t.Run("Legacy documents are summarized", func(t *testing.T) {
+		docs.docs[contract.ID].EntityCount = nil
+		docs.docs[contract.ID].EntityTypes = nil
+
+		if summarized, err := svc.SummarizeLegacyEntities(ctx); err != nil || summarized != 1 {
+			t.Fatalf("SummarizeLegacyEntities() = %d, %v, want one document summarized", summarized, err)
+		}
+		if stored := docs.docs[contract.ID]; stored.EntityCount == nil || *stored.EntityCount != 2 || !slices.Equal(stored.EntityTypes, []string{"EMAIL_ADDRESS", "PERSON"}) {
+			t.Errorf("stored summary = %v, %v, want two entities of two types", stored.EntityCount, stored.EntityTypes)
+		}
+	})
+}
+
+func TestDocumentService_SchemaVersions(t *testing.T) {
+	t.Setenv("API_KEY_ENCRYPTION_KEY", "0123456789abcdef0123456789abcdef")
+	docs := &memoryDocumentRepository{docs: map[int64]*models.Document{}}
+	svc := NewDocumentService(docs, &MockProcessingAuditRepository{}, nil)
+	ctx := context.Background()
+
+	doc, err := svc.UploadDocument(ctx, 1, "contract.pdf", "nb", "", sensitiveSchema("PERSON", "Ola Nordmann"))
+	if err != nil {
+		t.Fatalf("UploadDocument() error = %v", err)
+	}
+	if _, err := svc.UpdateRedactionSchema(ctx, 1, doc.ID, sensitiveSchema("PERSON", "Ola Nordmann", "EMAIL_ADDRESS", "ola@example.com")); err != nil {
+		t.Fatalf("UpdateRedactionSchema() error = %v", err)
+	}
+	if _, err := svc.UpdateRedactionSchema(ctx, 1, doc.ID, models.RedactionMapping{Version: models.RedactionSchemaVersion}); err != nil {
+		t.Fatalf("UpdateRedactionSchema() error = %v", err)
+	}
+
+	t.Run("Every save keeps the replaced schema", func(t *testing.T) {
+		versions, err := svc.GetSchemaVersions(ctx, 1, doc.ID)
+		if err != nil || len(versions) != 2 {
+			t.Fatalf("GetSchemaVersions() = %d versions, %v, want 2", len(versions), err)
+		}
+		if versions[0].Version != 2 || versions[0].EntityCount != 2 || versions[1].Version != 1 || versions[1].EntityCount != 1 {
+			t.Errorf("versions = %+v, %+v, want the two-entity schema first", versions[0], versions[1])
+		}
+		if versions[0].ReplacedBy != 1 || versions[0].RedactionSchema != "" {
+			t.Errorf("version 2 = %+v, want replaced by user 1 without its schema", versions[0])
+		}
+	})
+
+	t.Run("Rollback restores a version and keeps the current schema", func(t *testing.T) {
+		restored, err := svc.RollbackRedactionSchema(ctx, 1, doc.ID, 1)
+		if err != nil {
+			t.Fatalf("RollbackRedactionSchema() error = %v", err)
+		}
+		if stored := docs.docs[doc.ID]; stored.EntityCount == nil || *stored.EntityCount != 1 || restored.ID != doc.ID {
+			t.Errorf("stored entity count = %v, want the single entity of version 1", stored.EntityCount)
+		}
+		versions, _ := svc.GetSchemaVersions(ctx, 1, doc.ID)
+		if len(versions) != 3 || versions[0].EntityCount != 0 {
+			t.Errorf("versions = %d, want the emptied schema kept as version 3", len(versions))
+		}
+	})
+
+	t.Run("Unknown version", func(t *testing.T) {
+		if _, err := svc.RollbackRedactionSchema(ctx, 1, doc.ID, 9); !errors.Is(err, utils.ErrNotFound) {
+			t.Errorf("RollbackRedactionSchema() error = %v, want not found", err)
+		}
+	})
+
+	t.Run("Other users", func(t *testing.T) {
+		if _, err := svc.GetSchemaVersions(ctx, 2, doc.ID); !errors.Is(err, ErrDocumentNotFound) {
+			t.Errorf("GetSchemaVersions() error = %v, want ErrDocumentNotFound", err)
+		}
+		if _, err := svc.RollbackRedactionSchema(ctx, 2, doc.ID, 1); !errors.Is(err, ErrDocumentNotFound) {
+			t.Errorf("RollbackRedactionSchema() error = %v, want ErrDocumentNotFound", err)
+		}
+	})
+}
+
+func TestDocumentService_AddDetectedEntities(t *testing.T) {
+	t.Setenv("API_KEY_ENCRYPTION_KEY", "0123456789abcdef0123456789abcdef")
+	docs := &memoryDocumentRepository{docs: map[int64]*models.Document{}, entities: map[int64][]*models.DetectedEntityWithMethod{}}
+	svc := NewDocumentService(docs, &MockProcessingAuditRepository{}, nil)
+	ctx := context.Background()
+
+	doc, err := svc.UploadDocument(ctx, 1, "contract.pdf", "nb", "", sensitiveSchema("PERSON", "Ola Nordmann"))
+	if err != nil {
+		t.Fatalf("UploadDocument() error = %v", err)
+	}
+
+	detected := time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC)
+	inputs := []models.DetectedEntityInput{
+		{MethodID: 1, EntityName: "Ola Nordmann", RedactionSchema: models.RedactionSchema{Page: 1, EndX: 10, EndY: 10}},
+		{MethodID: 2, EntityName: "ola@example.com", RedactionSchema: models.RedactionSchema{Page: 2, EndX: 10, EndY: 10}, DetectedTimestamp: &detected},
+	}
+
+	t.Run("Adds every entity in order", func(t *testing.T) {
+		result, err := svc.AddDetectedEntities(ctx, 1, doc.ID, inputs)
+		if err != nil {
+			t.Fatalf("AddDetectedEntities() error = %v", err)
+		}
+		stored := docs.entities[doc.ID]
+		if len(result.EntityIDs) != 2 || len(stored) != 2 || result.DocumentID != doc.ID {
+			t.Fatalf("result = %+v, stored %d entities, want 2", result, len(stored))
+		}
+		if stored[0].ID != result.EntityIDs[0] || stored[1].EntityName != "ola@example.com" || stored[1].MethodID != 2 {
+			t.Errorf("stored = %+v, %+v, want the entities in request order", stored[0], stored[1])
+		}
+		if !stored[1].DetectedTimestamp.Equal(detected) || stored[0].DetectedTimestamp.IsZero() {
+			t.Errorf("detected timestamps = %v, %v, want now and the given time", stored[0].DetectedTimestamp, stored[1].DetectedTimestamp)
+		}
+	})
+
+	t.Run("Other users", func(t *testing.T) {
+		if _, err := svc.AddDetectedEntities(ctx, 2, doc.ID, inputs); !errors.Is(err, ErrDocumentNotFound) {
+			t.Errorf("AddDetectedEntities() error = %v, want ErrDocumentNotFound", err)
+		}
+	})
+
+	t.Run("Archived document", func(t *testing.T) {
+		archivedAt := time.Now()
+		docs.docs[doc.ID].ArchivedAt = &archivedAt
+		defer func() { docs.docs[doc.ID].ArchivedAt = nil }()
+		if _, err := svc.AddDetectedEntities(ctx, 1, doc.ID, inputs); !errors.Is(err, ErrDocumentArchived) {
+			t.Errorf("AddDetectedEntities() error = %v, want ErrDocumentArchived", err)
+		}
+	})
+}
+
+func TestDocumentService_ReviewEntities(t *testing.T) {
+	t.Setenv("API_KEY_ENCRYPTION_KEY", "0123456789abcdef0123456789abcdef")
+	docs := &memoryDocumentRepository{docs: map[int64]*models.Document{}, entities: map[int64][]*models.DetectedEntityWithMethod{}}
+	svc := NewDocumentService(docs, &MockProcessingAuditRepository{}, nil)
+	ctx := context.Background()
+
+	doc, err := svc.UploadDocument(ctx, 1, "contract.pdf", "nb", "", sensitiveSchema("PERSON", "Ola Nordmann"))
+	if err != nil {
+		t.Fatalf("UploadDocument() error = %v", err)
+	}
+	added, err := svc.AddDetectedEntities(ctx, 1, doc.ID, []models.DetectedEntityInput{
+		{MethodID: 1, EntityName: "Ola Nordmann"},
+		{MethodID: 2, EntityName: "ola@example.com"},
+	})
+	if err != nil {
+		t.Fatalf("AddDetectedEntities() error = %v", err)
+	}
+	first, second := added.EntityIDs[0], added.EntityIDs[1]
+
+	t.Run("Rejects entities once", func(t *testing.T) {
+		result, err := svc.ReviewEntities(ctx, 1, doc.ID, []int64{second, second}, models.EntityStatusRejected)
+		if err != nil {
+			t.Fatalf("ReviewEntities() error = %v", err)
+		}
+		if len(result.EntityIDs) != 1 || result.EntityIDs[0] != second || result.ReviewedBy != 1 || result.ReviewedAt.IsZero() {
+			t.Errorf("ReviewEntities() = %+v, want entity %d reviewed by user 1", result, second)
+		}
+	})
+
+	t.Run("Filters by status and method", func(t *testing.T) {
+		rejected, err := svc.GetDetectedEntities(ctx, 1, doc.ID, models.DetectedEntityFilter{Status: models.EntityStatusRejected})
+		if err != nil {
+			t.Fatalf("GetDetectedEntities() error = %v", err)
+		}
+		if len(rejected) != 1 || rejected[0].ID != second {
+			t.Errorf("rejected entities = %v, want only entity %d", rejected, second)
+		}
+		byMethod, err := svc.GetDetectedEntities(ctx, 1, doc.ID, models.DetectedEntityFilter{MethodID: 1})
+		if err != nil {
+			t.Fatalf("GetDetectedEntities() error = %v", err)
+		}
+		if len(byMethod) != 1 || byMethod[0].ID != first || byMethod[0].Status != models.EntityStatusPending {
+			t.Errorf("entities of method 1 = %v, want pending entity %d", byMethod, first)
+		}
+	})
+
+	t.Run("Entities of another document", func(t *testing.T) {
+		_, err := svc.ReviewEntities(ctx, 1, doc.ID, []int64{first, 999}, models.EntityStatusAccepted)
+		if !errors.Is(err, utils.ErrNotFound) || errors.Is(err, ErrDocumentNotFound) {
+			t.Errorf("ReviewEntities() error = %v, want an entity not found error", err)
+		}
+		if docs.entities[doc.ID][0].Status != models.EntityStatusPending {
+			t.Errorf("status = %s, want no entity changed", docs.entities[doc.ID][0].Status)
+		}
+	})
+
+	t.Run("Unknown status", func(t *testing.T) {
+		if _, err := svc.ReviewEntities(ctx, 1, doc.ID, []int64{first}, "maybe"); !utils.IsValidationError(err) {
+			t.Errorf("ReviewEntities() error = %v, want a validation error", err)
+		}
+	})
+
+	t.Run("Other users", func(t *testing.T) {
+		if _, err := svc.ReviewEntities(ctx, 2, doc.ID, []int64{first}, models.EntityStatusAccepted); !errors.Is(err, ErrDocumentNotFound) {
+			t.Errorf("ReviewEntities() error = %v, want ErrDocumentNotFound", err)
+		}
+		if _, err := svc.GetDetectedEntities(ctx, 2, doc.ID, models.DetectedEntityFilter{}); !errors.Is(err, ErrDocumentNotFound) {
+			t.Errorf("GetDetectedEntities() error = %v, want ErrDocumentNotFound", err)
+		}
+	})
+}
+
+// retentionDocumentRepository records the retention previews asked for; any other call panics
+type retentionDocumentRepository struct {
+	repository.DocumentRepository
+	userID        int64
+	retentionDays int
+	limit         int
+}
+
+func (r *retentionDocumentRepository) PreviewExpired(ctx context.Context, userID int64, now time.Time, retentionDays, limit int) (*models.RetentionPreview, error) {
+	r.userID, r.retentionDays, r.limit = userID, retentionDays, limit
+	return &models.RetentionPreview{RetentionDays: retentionDays, Documents: []*models.ExpiredDocument{}}, nil
+}
+
+// retentionSettings returns settings with a fixed document retention
+type retentionSettings struct {
+	days int
+	err  error
+}
+
+func (s *retentionSettings) GetUserSettings(ctx context.Context, userID int64) (*models.UserSetting, error) {
+	if s.err != nil {
+		return nil, s.err
+	}
+	settings := models.NewUserSetting(userID)
+	settings.DocumentRetentionDays = s.days
+	return settings, nil
+}
+
+func TestDocumentService_PreviewRetention(t *testing.T) {
+	ctx := context.Background()
+	proposed := 7
+
+	tests := []struct {
+		name          string
+		settings      UserSettingsProvider
+		retentionDays *int
+		wantDays      int
+		wantErr       bool
+	}{
+		{name: "Current setting", settings: &retentionSettings{days: 30}, wantDays: 30},
+		{name: "Proposed setting", settings: &retentionSettings{days: 30}, retentionDays: &proposed, wantDays: 7},
+		{name: "Without settings", wantDays: 0},
+		{name: "Settings error", settings: &retentionSettings{err: errors.New("database error")}, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			docs := &retentionDocumentRepository{}
+			svc := NewDocumentService(docs, nil, nil)
+			if tt.settings != nil {
+				svc.SetRetentionSettings(tt.settings)
+			}
+
+			preview, err := svc.PreviewRetention(ctx, 1, tt.retentionDays)
+			if tt.wantErr {
+				if err == nil {
+					t.Fatal("PreviewRetention() error = nil, want an error")
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("PreviewRetention() error = %v", err)
+			}
+			if preview.RetentionDays != tt.wantDays || docs.retentionDays != tt.wantDays {
+				t.Errorf("retention days = %d, want %d", docs.retentionDays, tt.wantDays)
+			}
+			if docs.userID != 1 || docs.limit != constants.RetentionPreviewLimit {
+				t.Errorf("preview of user %d limited to %d, want user 1 limited to %d", docs.userID, docs.limit, constants.RetentionPreviewLimit)
+			}
+		})
+	}
+}
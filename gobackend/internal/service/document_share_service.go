@@ -0,0 +1,231 @@
+// Package service provides business logic implementations.
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+
+	"github.com/rs/zerolog/log"
+
+	"github.com/yasinhessnawi1/Hideme_Backend/internal/constants"
+	"github.com/yasinhessnawi1/Hideme_Backend/internal/models"
+	"github.com/yasinhessnawi1/Hideme_Backend/internal/repository"
+	"github.com/yasinhessnawi1/Hideme_Backend/internal/utils"
+)
+
+var (
+	// ErrShareWithSelf is returned when a user shares a document with themselves
+	ErrShareWithSelf = utils.New(utils.ErrBadRequest, constants.StatusBadRequest, constants.MsgShareWithSelf)
+
+	// ErrShareUserNotFound is returned when no enabled account has the email a document is shared with
+	ErrShareUserNotFound = utils.New(utils.ErrNotFound, constants.StatusNotFound, constants.MsgShareUserNotFound)
+
+	// ErrDocumentShareNotFound is returned when a share that doesn't exist is revoked
+	ErrDocumentShareNotFound = utils.New(utils.ErrNotFound, constants.StatusNotFound, constants.MsgDocumentShareNotFound)
+)
+
+// DocumentShareService lets users share their documents with other accounts, so teams can
+// collaborate on the redaction of the same file. Read access lets the other user view the
+// document, its content and redactions; redact access lets them change its redaction schema
+// as well. Only the owner can share, delete or restore a document.
+type DocumentShareService struct {
+	shareRepo repository.DocumentShareRepository
+	docRepo   repository.DocumentRepository
+	userRepo  repository.UserRepository
+}
+
+// NewDocumentShareService creates a new DocumentShareService.
+//
+// Parameters:
+//   - shareRepo: Repository for the shares of documents
+//   - docRepo: Repository for the shared documents
+//   - userRepo: Repository for looking up the accounts documents are shared with
+//
+// Returns:
+//   - A configured DocumentShareService
+func NewDocumentShareService(shareRepo repository.DocumentShareRepository, docRepo repository.DocumentRepository, userRepo repository.UserRepository) *DocumentShareService {
+	return &DocumentShareService{
+		shareRepo: shareRepo,
+		docRepo:   docRepo,
+		userRepo:  userRepo,
+	}
+}
+
+// ownedDocument retrieves a document owned by a user, or ErrDocumentNotFound if another
+// user owns it.
+func (s *DocumentShareService) ownedDocument(ctx context.Context, ownerID, documentID int64) (*models.Document, error) {
+	doc, err := s.docRepo.GetByID(ctx, documentID)
+	if err != nil {
+		if errors.Is(err, utils.ErrNotFound) {
+			return nil, ErrDocumentNotFound
+		}
+		return nil, err
+	}
+	if doc.UserID != ownerID {
+		return nil, ErrDocumentNotFound
+	}
+	return doc, nil
+}
+
+// ShareDocument shares a document with the account of an email address. Sharing it again
+// with the same account changes the permission.
+//
+// Parameters:
+//   - ctx: Context for the operation
+//   - ownerID: The ID of the user sharing the document
+//   - documentID: The ID of the document
+//   - req: The email address of the account and the permission to grant
+//
+// Returns:
+//   - The share
+//   - ErrDocumentNotFound if the document doesn't exist or belongs to another user
+//   - ErrShareUserNotFound if no enabled account has the email address
+//   - ErrShareWithSelf if the email address is the owner's
+//   - Other errors if the share could not be stored
+func (s *DocumentShareService) ShareDocument(ctx context.Context, ownerID, documentID int64, req *models.DocumentShareRequest) (*models.DocumentShare, error) {
+	if _, err := s.ownedDocument(ctx, ownerID, documentID); err != nil {
+		return nil, err
+	}
+
+	user, err := s.userRepo.GetByEmail(ctx, req.Email)
+	if err != nil {
+		if errors.Is(err, utils.ErrNotFound) {
+			return nil, ErrShareUserNotFound
+		}
+		return nil, err
+	}
+	if user.IsDisabled() {
+		return nil, ErrShareUserNotFound
+	}
+	if user.ID == ownerID {
+		return nil, ErrShareWithSelf
+	}
+
+	share := &models.DocumentShare{
+		DocumentID: documentID,
+		UserID:     user.ID,
+		GrantedBy:  ownerID,
+		Permission: req.Permission,
+	}
+	if err := s.shareRepo.Upsert(ctx, share); err != nil {
+		return nil, err
+	}
+	share.Email = user.Email
+
+	log.Info().
+		Int64("user_id", ownerID).
+		Int64("document_id", documentID).
+		Int64("shared_with", user.ID).
+		Str("permission", share.Permission).
+		Msg("Document shared")
+
+	return share, nil
+}
+
+// ListShares returns the accounts a document owned by a user is shared with.
+//
+// Parameters:
+//   - ctx: Context for the operation
+//   - ownerID: The ID of the owner of the document
+//   - documentID: The ID of the document
+//
+// Returns:
+//   - The shares of the document, oldest first
+//   - ErrDocumentNotFound if the document doesn't exist or belongs to another user
+//   - Other errors if the shares could not be read
+func (s *DocumentShareService) ListShares(ctx context.Context, ownerID, documentID int64) ([]*models.DocumentShare, error) {
+	if _, err := s.ownedDocument(ctx, ownerID, documentID); err != nil {
+		return nil, err
+	}
+
+	shares, err := s.shareRepo.ListByDocument(ctx, documentID)
+	if err != nil {
+		return nil, err
+	}
+	if shares == nil {
+		shares = []*models.DocumentShare{}
+	}
+	return shares, nil
+}
+
+// RevokeShare stops sharing a document owned by a user with another account.
+//
+// Parameters:
+//   - ctx: Context for the operation
+//   - ownerID: The ID of the owner of the document
+//   - documentID: The ID of the document
+//   - userID: The ID of the account the document is shared with
+//
+// Returns:
+//   - ErrDocumentNotFound if the document doesn't exist or belongs to another user
+//   - ErrDocumentShareNotFound if the document is not shared with the account
+//   - Other errors if the share could not be deleted
+func (s *DocumentShareService) RevokeShare(ctx context.Context, ownerID, documentID, userID int64) error {
+	if _, err := s.ownedDocument(ctx, ownerID, documentID); err != nil {
+		return err
+	}
+
+	if err := s.shareRepo.Delete(ctx, documentID, userID); err != nil {
+		if errors.Is(err, utils.ErrNotFound) {
+			return ErrDocumentShareNotFound
+		}
+		return err
+	}
+
+	log.Info().
+		Int64("user_id", ownerID).
+		Int64("document_id", documentID).
+		Int64("shared_with", userID).
+		Msg("Document share revoked")
+
+	return nil
+}
+
+// ListSharedWithMe returns the documents other users shared with a user.
+//
+// Parameters:
+//   - ctx: Context for the operation
+//   - userID: The ID of the user the documents are shared with
+//
+// Returns:
+//   - The shared documents, most recently shared first
+//   - An error if the shares or their documents could not be read
+func (s *DocumentShareService) ListSharedWithMe(ctx context.Context, userID int64) ([]*models.SharedDocument, error) {
+	shares, err := s.shareRepo.ListByUser(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	encryptionKey := []byte(os.Getenv("API_KEY_ENCRYPTION_KEY"))
+	documents := make([]*models.SharedDocument, 0, len(shares))
+	for _, share := range shares {
+		doc, err := s.docRepo.GetByID(ctx, share.DocumentID)
+		if err != nil {
+			// The document was deleted after its shares were read
+			if errors.Is(err, utils.ErrNotFound) {
+				continue
+			}
+			return nil, err
+		}
+
+		filename, err := doc.DecryptDocumentName(encryptionKey)
+		if err != nil {
+			return nil, fmt.Errorf("failed to decrypt document name: %w", err)
+		}
+
+		documents = append(documents, &models.SharedDocument{
+			ID:              doc.ID,
+			HashedName:      filename,
+			OwnerID:         doc.UserID,
+			Permission:      share.Permission,
+			SharedAt:        share.CreatedAt,
+			UploadTimestamp: doc.UploadTimestamp,
+			LastModified:    doc.LastModified,
+			StorageTier:     models.StorageTierOf(doc.ArchivedAt),
+		})
+	}
+
+	return documents, nil
+}
@@ -0,0 +1,215 @@
+package service
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/yasinhessnawi1/Hideme_Backend/internal/constants"
+	"github.com/yasinhessnawi1/Hideme_Backend/internal/models"
+	"github.com/yasinhessnawi1/Hideme_Backend/internal/utils"
+)
+
+// MockDocumentShareRepository keeps document shares in memory
+type MockDocumentShareRepository struct {
+	shares []*models.DocumentShare
+}
+
+func (m *MockDocumentShareRepository) find(documentID, userID int64) *models.DocumentShare {
+	for _, share := range m.shares {
+		if share.DocumentID == documentID && share.UserID == userID {
+			return share
+		}
+	}
+	return nil
+}
+
+func (m *MockDocumentShareRepository) Upsert(ctx context.Context, share *models.DocumentShare) error {
+	if existing := m.find(share.DocumentID, share.UserID); existing != nil {
+		existing.Permission = share.Permission
+		share.ID, share.CreatedAt = existing.ID, existing.CreatedAt
+		return nil
+	}
+	share.ID = int64(len(m.shares) + 1)
+	share.CreatedAt = time.Now()
+	copied := *share
+	m.shares = append(m.shares, &copied)
+	return nil
+}
+
+func (m *MockDocumentShareRepository) GetPermission(ctx context.Context, documentID, userID int64) (string, error) {
+	if share := m.find(documentID, userID); share != nil {
+		return share.Permission, nil
+	}
+	return "", utils.NewNotFoundError("DocumentShare", documentID)
+}
+
+func (m *MockDocumentShareRepository) ListByDocument(ctx context.Context, documentID int64) ([]*models.DocumentShare, error) {
+	var shares []*models.DocumentShare
+	for _, share := range m.shares {
+		if share.DocumentID == documentID {
+			shares = append(shares, share)
+		}
+	}
+	return shares, nil
+}
+
+func (m *MockDocumentShareRepository) ListByUser(ctx context.Context, userID int64) ([]*models.DocumentShare, error) {
+	var shares []*models.DocumentShare
+	for _, share := range m.shares {
+		if share.UserID == userID {
+			shares = append(shares, share)
+		}
+	}
+	return shares, nil
+}
+
+func (m *MockDocumentShareRepository) Delete(ctx context.Context, documentID, userID int64) error {
+	for i, share := range m.shares {
+		if share.DocumentID == documentID && share.UserID == userID {
+			m.shares = append(m.shares[:i], m.shares[i+1:]...)
+			return nil
+		}
+	}
+	return utils.NewNotFoundError("DocumentShare", documentID)
+}
+
+func TestDocumentShareService(t *testing.T) {
+	t.Setenv("API_KEY_ENCRYPTION_KEY", "0123456789abcdef0123456789abcdef")
+	ctx := context.Background()
+	docs := &memoryDocumentRepository{docs: map[int64]*models.Document{}}
+	contents := &MockDocumentContentRepository{contents: map[int64]*models.DocumentContent{}, docs: docs}
+	shares := &MockDocumentShareRepository{}
+	users := NewMockUserRepository()
+	for _, user := range []*models.User{
+		{Username: "owner", Email: "owner@example.com"},
+		{Username: "reader", Email: "reader@example.com"},
+		{Username: "redactor", Email: "redactor@example.com"},
+		{Username: "outsider", Email: "outsider@example.com"},
+	} {
+		_ = users.Create(ctx, user)
+	}
+	const owner, reader, redactor, outsider = 1, 2, 3, 4
+
+	documents := NewDocumentService(docs, &MockProcessingAuditRepository{}, nil)
+	keys := &stubContentKeys{keys: map[int64][]byte{}}
+	hashes := &memoryEntityHashRepository{}
+	documents.SetContentStorage(contents, &memoryDocumentStore{objects: map[string][]byte{}}, keys)
+	documents.SetShares(shares)
+	documents.SetEntityIndexer(NewEntityHashService(hashes, keys))
+	svc := NewDocumentShareService(shares, docs, users)
+
+	schema := models.RedactionMapping{Version: models.RedactionSchemaVersion, Pages: []models.Page{{PageNumber: 1}}}
+	pdf := []byte("%PDF-1.7\nOla Nordmann\n%%EOF")
+	doc, err := documents.UploadDocumentWithContent(ctx, owner, "contract.pdf", "nb", "", schema, pdf)
+	if err != nil {
+		t.Fatalf("UploadDocumentWithContent() error = %v", err)
+	}
+
+	t.Run("Share", func(t *testing.T) {
+		share, err := svc.ShareDocument(ctx, owner, doc.ID, &models.DocumentShareRequest{Email: "reader@example.com", Permission: constants.SharePermissionRead})
+		if err != nil || share.UserID != reader || share.Email != "reader@example.com" || share.GrantedBy != owner {
+			t.Fatalf("ShareDocument() = %+v, %v, want the document shared with the reader", share, err)
+		}
+		if _, err := svc.ShareDocument(ctx, owner, doc.ID, &models.DocumentShareRequest{Email: "redactor@example.com", Permission: constants.SharePermissionRedact}); err != nil {
+			t.Fatalf("ShareDocument() error = %v", err)
+		}
+
+		listed, err := svc.ListShares(ctx, owner, doc.ID)
+		if err != nil || len(listed) != 2 {
+			t.Errorf("ListShares() = %d shares, %v, want 2", len(listed), err)
+		}
+	})
+
+	t.Run("Only the owner shares", func(t *testing.T) {
+		if _, err := svc.ShareDocument(ctx, redactor, doc.ID, &models.DocumentShareRequest{Email: "outsider@example.com", Permission: constants.SharePermissionRead}); !errors.Is(err, ErrDocumentNotFound) {
+			t.Errorf("ShareDocument() error = %v, want ErrDocumentNotFound", err)
+		}
+		if _, err := svc.ListShares(ctx, reader, doc.ID); !errors.Is(err, ErrDocumentNotFound) {
+			t.Errorf("ListShares() error = %v, want ErrDocumentNotFound", err)
+		}
+	})
+
+	t.Run("Invalid grantees", func(t *testing.T) {
+		if _, err := svc.ShareDocument(ctx, owner, doc.ID, &models.DocumentShareRequest{Email: "owner@example.com", Permission: constants.SharePermissionRead}); !errors.Is(err, ErrShareWithSelf) {
+			t.Errorf("ShareDocument() error = %v, want ErrShareWithSelf", err)
+		}
+		if _, err := svc.ShareDocument(ctx, owner, doc.ID, &models.DocumentShareRequest{Email: "nobody@example.com", Permission: constants.SharePermissionRead}); !errors.Is(err, ErrShareUserNotFound) {
+			t.Errorf("ShareDocument() error = %v, want ErrShareUserNotFound", err)
+		}
+	})
+
+	t.Run("Readers read", func(t *testing.T) {
+		got, err := documents.GetDocumentByID(ctx, reader, doc.ID)
+		if err != nil || got.HashedDocumentName != "contract.pdf" {
+			t.Errorf("GetDocumentByID() = %+v, %v, want the shared document", got, err)
+		}
+		// The content is decrypted with the owner's key
+		if _, content, err := documents.GetDocumentContent(ctx, reader, doc.ID); err != nil || !bytes.Equal(content, pdf) {
+			t.Errorf("GetDocumentContent() = %q, %v, want the uploaded PDF", content, err)
+		}
+
+		shared, err := svc.ListSharedWithMe(ctx, reader)
+		if err != nil || len(shared) != 1 || shared[0].HashedName != "contract.pdf" || shared[0].OwnerID != owner {
+			t.Errorf("ListSharedWithMe() = %+v, %v, want the shared document", shared, err)
+		}
+	})
+
+	t.Run("Readers cannot redact", func(t *testing.T) {
+		if _, err := documents.UpdateRedactionSchema(ctx, reader, doc.ID, schema); !errors.Is(err, ErrDocumentReadOnly) {
+			t.Errorf("UpdateRedactionSchema() error = %v, want ErrDocumentReadOnly", err)
+		}
+	})
+
+	t.Run("Redactors redact", func(t *testing.T) {
+		updated := models.RedactionMapping{Version: models.RedactionSchemaVersion, Pages: []models.Page{{
+			PageNumber: 1,
+			Sensitive:  []models.Sensitive{{OriginalText: "Ola Nordmann", EntityType: "PERSON", BBox: models.BBox{X0: 0.1, Y0: 0.1, X1: 0.3, Y1: 0.2}}},
+		}}}
+		got, err := documents.UpdateRedactionSchema(ctx, redactor, doc.ID, updated)
+		if err != nil || !strings.Contains(got.RedactionSchema, "Ola Nordmann") {
+			t.Fatalf("UpdateRedactionSchema() = %+v, %v, want the new schema", got, err)
+		}
+
+		// The owner sees the change
+		got, err = documents.GetDocumentByID(ctx, owner, doc.ID)
+		if err != nil || documents.CalculateEntityCount(got.RedactionSchema) != 1 {
+			t.Errorf("GetDocumentByID() = %+v, %v, want one redaction", got, err)
+		}
+
+		// The detected values are hashed for the owner
+		if len(hashes.hashes) != 1 || hashes.hashes[0].UserID != owner || hashes.hashes[0].DocumentID != doc.ID {
+			t.Errorf("entity hashes = %+v, want one hash of the owner", hashes.hashes)
+		}
+	})
+
+	t.Run("Only the owner deletes", func(t *testing.T) {
+		if err := documents.DeleteDocumentByID(ctx, redactor, doc.ID); !errors.Is(err, ErrDocumentNotFound) {
+			t.Errorf("DeleteDocumentByID() error = %v, want ErrDocumentNotFound", err)
+		}
+	})
+
+	t.Run("Others see nothing", func(t *testing.T) {
+		if _, err := documents.GetDocumentByID(ctx, outsider, doc.ID); !errors.Is(err, ErrDocumentNotFound) {
+			t.Errorf("GetDocumentByID() error = %v, want ErrDocumentNotFound", err)
+		}
+		if _, err := documents.UpdateRedactionSchema(ctx, outsider, doc.ID, schema); !errors.Is(err, ErrDocumentNotFound) {
+			t.Errorf("UpdateRedactionSchema() error = %v, want ErrDocumentNotFound", err)
+		}
+	})
+
+	t.Run("Revoke", func(t *testing.T) {
+		if err := svc.RevokeShare(ctx, owner, doc.ID, reader); err != nil {
+			t.Fatalf("RevokeShare() error = %v", err)
+		}
+		if _, err := documents.GetDocumentByID(ctx, reader, doc.ID); !errors.Is(err, ErrDocumentNotFound) {
+			t.Errorf("GetDocumentByID() error = %v, want ErrDocumentNotFound after revoking", err)
+		}
+		if err := svc.RevokeShare(ctx, owner, doc.ID, reader); !errors.Is(err, ErrDocumentShareNotFound) {
+			t.Errorf("RevokeShare() error = %v, want ErrDocumentShareNotFound", err)
+		}
+	})
+}
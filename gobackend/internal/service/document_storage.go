@@ -0,0 +1,392 @@
+// Package service provides business logic implementations.
+//
+// This file implements the document storage, where the encrypted PDF content of uploaded
+// documents is kept: a directory on the local disk or an S3 bucket.
+package service
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"net/http"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/yasinhessnawi1/Hideme_Backend/internal/config"
+	"github.com/yasinhessnawi1/Hideme_Backend/internal/constants"
+	"github.com/yasinhessnawi1/Hideme_Backend/internal/utils/clock"
+)
+
+// errDocumentObjectNotFound is returned when a key is not in the document storage.
+var errDocumentObjectNotFound = errors.New("document content not found in storage")
+
+// DocumentStore keeps the encrypted content of documents under opaque keys. The content
+// is encrypted before it reaches the store, so a store never sees it in the clear.
+type DocumentStore interface {
+	// Put stores content under a key, replacing content stored under the same key.
+	//
+	// Parameters:
+	//   - ctx: Context for cancellation control
+	//   - key: The key of the content, a relative slash-separated path
+	//   - content: The encrypted content
+	//
+	// Returns:
+	//   - An error describing why the content could not be stored
+	Put(ctx context.Context, key string, content []byte) error
+
+	// Get reads the content stored under a key.
+	//
+	// Parameters:
+	//   - ctx: Context for cancellation control
+	//   - key: The key of the content
+	//
+	// Returns:
+	//   - The encrypted content
+	//   - An error wrapping errDocumentObjectNotFound if nothing is stored under the key
+	Get(ctx context.Context, key string) ([]byte, error)
+
+	// GetRange opens a part of the content stored under a key for reading, without
+	// reading the rest of it.
+	//
+	// Parameters:
+	//   - ctx: Context for cancellation control; canceling it stops the reading
+	//   - key: The key of the content
+	//   - offset: The position of the first byte to read
+	//   - length: The number of bytes to read
+	//
+	// Returns:
+	//   - The part of the encrypted content, to be closed by the caller; it ends early if
+	//     the content is shorter
+	//   - An error wrapping errDocumentObjectNotFound if nothing is stored under the key
+	GetRange(ctx context.Context, key string, offset, length int64) (io.ReadCloser, error)
+
+	// Delete removes the content stored under a key. Deleting a key that is not stored succeeds.
+	//
+	// Parameters:
+	//   - ctx: Context for cancellation control
+	//   - key: The key of the content
+	//
+	// Returns:
+	//   - An error describing why the content could not be deleted
+	Delete(ctx context.Context, key string) error
+}
+
+// NewDocumentStore creates the document store of the configured backend.
+//
+// Parameters:
+//   - settings: The document storage configuration
+//
+// Returns:
+//   - The document store
+//   - An error if the backend is unknown or its directory cannot be created
+func NewDocumentStore(settings *config.DocumentStorageSettings) (DocumentStore, error) {
+	switch strings.ToLower(settings.Backend) {
+	case "", constants.DocumentStorageLocal:
+		return NewLocalDocumentStore(settings.LocalPath)
+	case constants.DocumentStorageS3:
+		return NewS3DocumentStore(settings), nil
+	default:
+		return nil, fmt.Errorf("invalid document storage backend: %s", settings.Backend)
+	}
+}
+
+// LocalDocumentStore keeps document content in files below a directory on the local disk.
+type LocalDocumentStore struct {
+	root string
+}
+
+// NewLocalDocumentStore creates a LocalDocumentStore, creating its directory if needed.
+//
+// Parameters:
+//   - root: The directory the content is stored in
+//
+// Returns:
+//   - A configured LocalDocumentStore
+//   - An error if the directory cannot be created
+func NewLocalDocumentStore(root string) (*LocalDocumentStore, error) {
+	if err := os.MkdirAll(root, 0o700); err != nil {
+		return nil, fmt.Errorf("failed to create document storage directory: %w", err)
+	}
+	return &LocalDocumentStore{root: root}, nil
+}
+
+// path returns the file of a key, refusing keys that would leave the storage directory.
+func (s *LocalDocumentStore) path(key string) (string, error) {
+	if key == "" || !fs.ValidPath(key) {
+		return "", fmt.Errorf("invalid document storage key: %q", key)
+	}
+	return filepath.Join(s.root, filepath.FromSlash(key)), nil
+}
+
+// Put writes the content to a temporary file and renames it into place, so a file is
+// never read half written.
+func (s *LocalDocumentStore) Put(ctx context.Context, key string, content []byte) error {
+	name, err := s.path(key)
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(name), 0o700); err != nil {
+		return fmt.Errorf("failed to create document storage directory: %w", err)
+	}
+
+	tmp, err := os.CreateTemp(filepath.Dir(name), ".upload-*")
+	if err != nil {
+		return fmt.Errorf("failed to create document file: %w", err)
+	}
+	defer os.Remove(tmp.Name())
+
+	if _, err := tmp.Write(content); err != nil {
+		tmp.Close()
+		return fmt.Errorf("failed to write document file: %w", err)
+	}
+	if err := tmp.Close(); err != nil {
+		return fmt.Errorf("failed to write document file: %w", err)
+	}
+	if err := os.Rename(tmp.Name(), name); err != nil {
+		return fmt.Errorf("failed to store document file: %w", err)
+	}
+
+	return nil
+}
+
+// Get reads the file of a key.
+func (s *LocalDocumentStore) Get(ctx context.Context, key string) ([]byte, error) {
+	name, err := s.path(key)
+	if err != nil {
+		return nil, err
+	}
+
+	content, err := os.ReadFile(name)
+	if err != nil {
+		if errors.Is(err, fs.ErrNotExist) {
+			return nil, fmt.Errorf("%w: %s", errDocumentObjectNotFound, key)
+		}
+		return nil, fmt.Errorf("failed to read document file: %w", err)
+	}
+
+	return content, nil
+}
+
+// GetRange opens the file of a key and reads from the offset.
+func (s *LocalDocumentStore) GetRange(ctx context.Context, key string, offset, length int64) (io.ReadCloser, error) {
+	name, err := s.path(key)
+	if err != nil {
+		return nil, err
+	}
+
+	file, err := os.Open(name)
+	if err != nil {
+		if errors.Is(err, fs.ErrNotExist) {
+			return nil, fmt.Errorf("%w: %s", errDocumentObjectNotFound, key)
+		}
+		return nil, fmt.Errorf("failed to open document file: %w", err)
+	}
+
+	return &rangeReadCloser{Reader: io.NewSectionReader(file, offset, length), Closer: file}, nil
+}
+
+// rangeReadCloser reads a part of content and closes what it is read from.
+type rangeReadCloser struct {
+	io.Reader
+	io.Closer
+}
+
+// Delete removes the file of a key.
+func (s *LocalDocumentStore) Delete(ctx context.Context, key string) error {
+	name, err := s.path(key)
+	if err != nil {
+		return err
+	}
+
+	if err := os.Remove(name); err != nil && !errors.Is(err, fs.ErrNotExist) {
+		return fmt.Errorf("failed to delete document file: %w", err)
+	}
+
+	return nil
+}
+
+// S3DocumentStore keeps document content in an Amazon S3 bucket or an S3-compatible store,
+// signing requests with AWS Signature Version 4 like the S3 export transport.
+type S3DocumentStore struct {
+	client          *http.Client
+	streamClient    *http.Client
+	bucket          string
+	region          string
+	endpoint        string
+	prefix          string
+	accessKeyID     string
+	secretAccessKey string
+	clock           clock.Clock
+}
+
+// NewS3DocumentStore creates an S3DocumentStore.
+//
+// Parameters:
+//   - settings: The document storage configuration naming the bucket and its credentials
+//
+// Returns:
+//   - A configured S3DocumentStore
+func NewS3DocumentStore(settings *config.DocumentStorageSettings) *S3DocumentStore {
+	return &S3DocumentStore{
+		client: &http.Client{
+			Timeout: constants.DocumentStorageRequestTimeout,
+			// Redirects would send the signed request to another host
+			CheckRedirect: func(*http.Request, []*http.Request) error {
+				return http.ErrUseLastResponse
+			},
+		},
+		// Ranges are read as fast as the client downloads them, so only the wait for the
+		// response is limited; the request ends with the download
+		streamClient: &http.Client{
+			Transport: &http.Transport{
+				Proxy:                 http.ProxyFromEnvironment,
+				ResponseHeaderTimeout: constants.DocumentStorageRequestTimeout,
+			},
+			CheckRedirect: func(*http.Request, []*http.Request) error {
+				return http.ErrUseLastResponse
+			},
+		},
+		bucket:          settings.S3Bucket,
+		region:          settings.S3Region,
+		endpoint:        settings.S3Endpoint,
+		prefix:          settings.S3Prefix,
+		accessKeyID:     settings.S3AccessKeyID,
+		secretAccessKey: settings.S3SecretAccessKey,
+	}
+}
+
+// SetClock sets the clock requests are signed at.
+func (s *S3DocumentStore) SetClock(c clock.Clock) {
+	s.clock = c
+}
+
+// now returns the current time of the store's clock.
+func (s *S3DocumentStore) now() time.Time {
+	return clock.Or(s.clock).Now()
+}
+
+// do sends a signed request for the object of a key.
+func (s *S3DocumentStore) do(ctx context.Context, method, key string, content []byte) (*http.Response, error) {
+	req, err := s.newRequest(ctx, method, key, content)
+	if err != nil {
+		return nil, err
+	}
+
+	resp, err := s.client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("S3 request failed: %w", err)
+	}
+	return resp, nil
+}
+
+// newRequest creates a signed request for the object of a key.
+func (s *S3DocumentStore) newRequest(ctx context.Context, method, key string, content []byte) (*http.Request, error) {
+	objectKey := strings.TrimPrefix(path.Join(s.prefix, key), "/")
+
+	var body io.Reader
+	if content != nil {
+		body = bytes.NewReader(content)
+	}
+	req, err := http.NewRequestWithContext(ctx, method, s3ObjectURL(s.endpoint, s.bucket, s.region, objectKey), body)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create S3 request: %w", err)
+	}
+	if content != nil {
+		req.Header.Set(constants.HeaderContentType, constants.ContentTypeOctetStream)
+	}
+	signS3Request(req, s.region, s.accessKeyID, s.secretAccessKey, sha256Hex(content), s.now())
+
+	return req, nil
+}
+
+// Put uploads the content with a single PUT request.
+func (s *S3DocumentStore) Put(ctx context.Context, key string, content []byte) error {
+	resp, err := s.do(ctx, http.MethodPut, key, content)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return s3ResponseError("upload", resp)
+	}
+	return nil
+}
+
+// Get downloads the object of a key.
+func (s *S3DocumentStore) Get(ctx context.Context, key string) ([]byte, error) {
+	resp, err := s.do(ctx, http.MethodGet, key, nil)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	switch resp.StatusCode {
+	case http.StatusOK:
+	case http.StatusNotFound:
+		return nil, fmt.Errorf("%w: %s", errDocumentObjectNotFound, key)
+	default:
+		return nil, s3ResponseError("download", resp)
+	}
+
+	content, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read S3 object: %w", err)
+	}
+	return content, nil
+}
+
+// GetRange downloads a part of the object of a key with a range request, handing the
+// response body to the caller as it arrives.
+func (s *S3DocumentStore) GetRange(ctx context.Context, key string, offset, length int64) (io.ReadCloser, error) {
+	if length <= 0 {
+		return io.NopCloser(strings.NewReader("")), nil
+	}
+
+	req, err := s.newRequest(ctx, http.MethodGet, key, nil)
+	if err != nil {
+		return nil, err
+	}
+	// The Range header is not signed, so it can be set after signing
+	req.Header.Set(constants.HeaderRange, fmt.Sprintf("bytes=%d-%d", offset, offset+length-1))
+
+	resp, err := s.streamClient.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("S3 request failed: %w", err)
+	}
+
+	switch resp.StatusCode {
+	case http.StatusPartialContent:
+		return &rangeReadCloser{Reader: io.LimitReader(resp.Body, length), Closer: resp.Body}, nil
+	case http.StatusRequestedRangeNotSatisfiable:
+		// The offset is past the end of the object
+		resp.Body.Close()
+		return io.NopCloser(strings.NewReader("")), nil
+	case http.StatusNotFound:
+		resp.Body.Close()
+		return nil, fmt.Errorf("%w: %s", errDocumentObjectNotFound, key)
+	default:
+		defer resp.Body.Close()
+		return nil, s3ResponseError("download", resp)
+	}
+}
+
+// Delete removes the object of a key. S3 reports success for objects that don't exist.
+func (s *S3DocumentStore) Delete(ctx context.Context, key string) error {
+	resp, err := s.do(ctx, http.MethodDelete, key, nil)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusNoContent && resp.StatusCode != http.StatusOK && resp.StatusCode != http.StatusNotFound {
+		return s3ResponseError("delete", resp)
+	}
+	return nil
+}
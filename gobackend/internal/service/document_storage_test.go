@@ -0,0 +1,141 @@
+package service
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/yasinhessnawi1/Hideme_Backend/internal/config"
+	"github.com/yasinhessnawi1/Hideme_Backend/internal/constants"
+	"github.com/yasinhessnawi1/Hideme_Backend/internal/testsupport/fakeclock"
+)
+
+func TestLocalDocumentStore(t *testing.T) {
+	store, err := NewLocalDocumentStore(t.TempDir())
+	if err != nil {
+		t.Fatalf("NewLocalDocumentStore() error = %v", err)
+	}
+	ctx := context.Background()
+
+	if err := store.Put(ctx, "7/0190b3c4", []byte("sealed")); err != nil {
+		t.Fatalf("Put() error = %v", err)
+	}
+	content, err := store.Get(ctx, "7/0190b3c4")
+	if err != nil || string(content) != "sealed" {
+		t.Errorf("Get() = %q, %v, want the stored content", content, err)
+	}
+	if part := readRange(t, store, "7/0190b3c4", 2, 3); part != "ale" {
+		t.Errorf("GetRange() = %q, want the middle of the content", part)
+	}
+
+	if err := store.Delete(ctx, "7/0190b3c4"); err != nil {
+		t.Fatalf("Delete() error = %v", err)
+	}
+	if _, err := store.Get(ctx, "7/0190b3c4"); !errors.Is(err, errDocumentObjectNotFound) {
+		t.Errorf("Get() error = %v, want not found after deleting", err)
+	}
+	if _, err := store.GetRange(ctx, "7/0190b3c4", 0, 1); !errors.Is(err, errDocumentObjectNotFound) {
+		t.Errorf("GetRange() error = %v, want not found after deleting", err)
+	}
+	if err := store.Delete(ctx, "7/0190b3c4"); err != nil {
+		t.Errorf("Delete() error = %v, want deleting a missing key to succeed", err)
+	}
+
+	// Keys cannot leave the storage directory
+	for _, key := range []string{"../outside", "/etc/passwd", ""} {
+		if err := store.Put(ctx, key, []byte("x")); err == nil {
+			t.Errorf("Put(%q) succeeded, want the key refused", key)
+		}
+	}
+}
+
+func TestS3DocumentStore(t *testing.T) {
+	var mu sync.Mutex
+	objects := map[string][]byte{}
+	var gotAuth string
+	server := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		defer mu.Unlock()
+		gotAuth = r.Header.Get(constants.HeaderAuthorization)
+		switch r.Method {
+		case http.MethodPut:
+			objects[r.URL.Path], _ = io.ReadAll(r.Body)
+		case http.MethodGet:
+			content, ok := objects[r.URL.Path]
+			if !ok {
+				w.WriteHeader(http.StatusNotFound)
+				_, _ = w.Write([]byte(`<?xml version="1.0"?><Error><Code>NoSuchKey</Code></Error>`))
+				return
+			}
+			http.ServeContent(w, r, "", time.Time{}, bytes.NewReader(content))
+		case http.MethodDelete:
+			delete(objects, r.URL.Path)
+			w.WriteHeader(http.StatusNoContent)
+		}
+	}))
+	defer server.Close()
+
+	store := NewS3DocumentStore(&config.DocumentStorageSettings{
+		Backend:           constants.DocumentStorageS3,
+		S3Bucket:          "documents",
+		S3Region:          "eu-north-1",
+		S3Endpoint:        server.URL,
+		S3Prefix:          "hideme",
+		S3AccessKeyID:     "AKIA",
+		S3SecretAccessKey: "s3cr3t",
+	})
+	store.client = server.Client()
+	store.streamClient = server.Client()
+	store.SetClock(fakeclock.New(time.Date(2026, 3, 1, 8, 0, 0, 0, time.UTC)))
+	ctx := context.Background()
+
+	if err := store.Put(ctx, "7/0190b3c4", []byte("sealed")); err != nil {
+		t.Fatalf("Put() error = %v", err)
+	}
+	if _, ok := objects["/documents/hideme/7/0190b3c4"]; !ok {
+		t.Errorf("objects = %v, want the object under the prefix", objects)
+	}
+	if !strings.HasPrefix(gotAuth, "AWS4-HMAC-SHA256 Credential=AKIA/20260301/eu-north-1/s3/aws4_request") {
+		t.Errorf("Unexpected authorization: %s", gotAuth)
+	}
+
+	content, err := store.Get(ctx, "7/0190b3c4")
+	if err != nil || string(content) != "sealed" {
+		t.Errorf("Get() = %q, %v, want the stored content", content, err)
+	}
+	if part := readRange(t, store, "7/0190b3c4", 2, 3); part != "ale" {
+		t.Errorf("GetRange() = %q, want the middle of the content", part)
+	}
+	if part := readRange(t, store, "7/0190b3c4", 4, 10); part != "ed" {
+		t.Errorf("GetRange() = %q, want the range cut off at the end", part)
+	}
+
+	if err := store.Delete(ctx, "7/0190b3c4"); err != nil {
+		t.Fatalf("Delete() error = %v", err)
+	}
+	if _, err := store.Get(ctx, "7/0190b3c4"); !errors.Is(err, errDocumentObjectNotFound) {
+		t.Errorf("Get() error = %v, want not found after deleting", err)
+	}
+}
+
+// readRange reads a range of the content stored under a key
+func readRange(t *testing.T, store DocumentStore, key string, offset, length int64) string {
+	t.Helper()
+	body, err := store.GetRange(context.Background(), key, offset, length)
+	if err != nil {
+		t.Fatalf("GetRange() error = %v", err)
+	}
+	defer body.Close()
+	part, err := io.ReadAll(body)
+	if err != nil {
+		t.Fatalf("reading range: %v", err)
+	}
+	return string(part)
+}
@@ -0,0 +1,212 @@
+// Package service provides business logic implementations.
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/rs/zerolog/log"
+
+	"github.com/yasinhessnawi1/Hideme_Backend/internal/constants"
+	"github.com/yasinhessnawi1/Hideme_Backend/internal/models"
+	"github.com/yasinhessnawi1/Hideme_Backend/internal/repository"
+	"github.com/yasinhessnawi1/Hideme_Backend/internal/utils"
+	"github.com/yasinhessnawi1/Hideme_Backend/internal/utils/clock"
+)
+
+var (
+	// ErrTransferToSelf is returned when documents are transferred to the user who owns them
+	ErrTransferToSelf = utils.New(utils.ErrBadRequest, constants.StatusBadRequest, constants.MsgTransferToSelf)
+
+	// ErrTransferUserNotFound is returned when a user named in a transfer doesn't exist
+	ErrTransferUserNotFound = utils.New(utils.ErrNotFound, constants.StatusNotFound, constants.MsgTransferUserNotFound)
+)
+
+// DocumentTransferer moves a single document to another user, usually the DocumentService.
+type DocumentTransferer interface {
+	TransferDocument(ctx context.Context, documentID, fromUserID, toUserID int64) error
+}
+
+// DocumentTransferService lets administrators transfer documents between users, so the
+// documents of an employee who leaves are handed over to a colleague instead of being
+// orphaned. Every transfer is recorded in an audit trail that outlives the accounts.
+type DocumentTransferService struct {
+	transferRepo repository.DocumentTransferRepository
+	docRepo      repository.DocumentRepository
+	userRepo     repository.UserRepository
+	transferer   DocumentTransferer
+	screener     *PIIScreener
+	clock        clock.Clock
+}
+
+// NewDocumentTransferService creates a new DocumentTransferService.
+//
+// Parameters:
+//   - transferRepo: Repository recording the transfers
+//   - docRepo: Repository listing the documents of users
+//   - userRepo: Repository checking that the users exist
+//   - transferer: Moves the documents and re-encrypts them for their new owner
+//
+// Returns:
+//   - A configured DocumentTransferService
+func NewDocumentTransferService(transferRepo repository.DocumentTransferRepository, docRepo repository.DocumentRepository, userRepo repository.UserRepository, transferer DocumentTransferer) *DocumentTransferService {
+	return &DocumentTransferService{
+		transferRepo: transferRepo,
+		docRepo:      docRepo,
+		userRepo:     userRepo,
+		transferer:   transferer,
+	}
+}
+
+// SetClock sets the clock transfers are completed by.
+func (s *DocumentTransferService) SetClock(c clock.Clock) {
+	s.clock = c
+}
+
+// now returns the current time of the service's clock.
+func (s *DocumentTransferService) now() time.Time {
+	return clock.Or(s.clock).Now()
+}
+
+// SetPIIScreener enables screening of transfer reasons for personal data. Transfers are kept
+// as an audit trail, so personal data entered there would outlive the erasure of the user it
+// belongs to.
+//
+// Parameters:
+//   - screener: The screener checking free-text fields
+func (s *DocumentTransferService) SetPIIScreener(screener *PIIScreener) {
+	s.screener = screener
+}
+
+// TransferDocuments moves documents from one user to another and records the transfer.
+// Documents that cannot be transferred stay with their owner and are reported as failed,
+// so a transfer of all documents of a user is not undone by a single broken document.
+// The transfer is recorded as pending before the first document moves, and its record is
+// updated after each document, so an interrupted transfer still shows what was moved.
+// There is no organization boundary between users yet: any two users can be chosen.
+//
+// Parameters:
+//   - ctx: Context for the operation
+//   - adminID: The ID of the administrator transferring the documents
+//   - req: The users, the documents to transfer or none for all, and the reason
+//
+// Returns:
+//   - The recorded transfer, with the transferred and failed documents
+//   - ErrTransferToSelf if both users are the same
+//   - ErrTransferUserNotFound if either user doesn't exist
+//   - ValidationError if the reason contains blocked personal data
+//   - Other errors if the documents could not be listed or the transfer could not be recorded;
+//     documents moved before the record failed to update are listed on the pending transfer
+func (s *DocumentTransferService) TransferDocuments(ctx context.Context, adminID int64, req *models.DocumentTransferRequest) (*models.DocumentTransfer, error) {
+	if req.FromUserID == req.ToUserID {
+		return nil, ErrTransferToSelf
+	}
+	for _, userID := range []int64{req.FromUserID, req.ToUserID} {
+		if _, err := s.userRepo.GetByID(ctx, userID); err != nil {
+			if errors.Is(err, utils.ErrNotFound) {
+				return nil, ErrTransferUserNotFound
+			}
+			return nil, err
+		}
+	}
+	if s.screener != nil {
+		if err := s.screener.Screen(ctx, adminID, map[string]string{"reason": req.Reason}); err != nil {
+			return nil, err
+		}
+	}
+
+	documentIDs := req.DocumentIDs
+	if len(documentIDs) == 0 {
+		var err error
+		if documentIDs, err = s.listDocumentIDs(ctx, req.FromUserID); err != nil {
+			return nil, err
+		}
+	}
+
+	transfer := &models.DocumentTransfer{
+		FromUserID:        req.FromUserID,
+		ToUserID:          req.ToUserID,
+		TransferredBy:     adminID,
+		Reason:            req.Reason,
+		DocumentIDs:       []int64{},
+		FailedDocumentIDs: []int64{},
+		Status:            models.TransferStatusPending,
+	}
+	if err := s.transferRepo.Create(ctx, transfer); err != nil {
+		return nil, err
+	}
+
+	seen := make(map[int64]bool, len(documentIDs))
+	for _, documentID := range documentIDs {
+		if seen[documentID] {
+			continue
+		}
+		seen[documentID] = true
+
+		if err := s.transferer.TransferDocument(ctx, documentID, req.FromUserID, req.ToUserID); err != nil {
+			log.Warn().Err(err).
+				Int64("document_id", documentID).
+				Int64("from_user_id", req.FromUserID).
+				Msg("Failed to transfer document")
+			transfer.FailedDocumentIDs = append(transfer.FailedDocumentIDs, documentID)
+		} else {
+			transfer.DocumentIDs = append(transfer.DocumentIDs, documentID)
+		}
+
+		// Stop moving documents that could no longer be accounted for
+		if err := s.transferRepo.Update(ctx, transfer); err != nil {
+			return nil, err
+		}
+	}
+
+	completedAt := s.now()
+	transfer.Status = models.TransferStatusCompleted
+	transfer.CompletedAt = &completedAt
+	if err := s.transferRepo.Update(ctx, transfer); err != nil {
+		return nil, err
+	}
+
+	log.Info().
+		Int64("admin_id", adminID).
+		Int64("from_user_id", req.FromUserID).
+		Int64("to_user_id", req.ToUserID).
+		Int("transferred", len(transfer.DocumentIDs)).
+		Int("failed", len(transfer.FailedDocumentIDs)).
+		Msg("Documents transferred")
+
+	return transfer, nil
+}
+
+// listDocumentIDs lists the IDs of all documents of a user before any of them is moved.
+func (s *DocumentTransferService) listDocumentIDs(ctx context.Context, userID int64) ([]int64, error) {
+	var documentIDs []int64
+	var afterID int64
+	for {
+		docs, err := s.docRepo.ListByUserIDAfter(ctx, userID, afterID, constants.DocumentTransferBatchSize)
+		if err != nil {
+			return nil, fmt.Errorf("failed to list documents: %w", err)
+		}
+		for _, doc := range docs {
+			documentIDs = append(documentIDs, doc.ID)
+			afterID = doc.ID
+		}
+		if len(docs) < constants.DocumentTransferBatchSize {
+			return documentIDs, nil
+		}
+	}
+}
+
+// ListTransfers retrieves the most recent transfers, optionally only those from or to a user.
+//
+// Parameters:
+//   - ctx: Context for the operation
+//   - userID: The ID of the user the documents were transferred from or to; 0 lists all transfers
+//
+// Returns:
+//   - The transfers, latest first
+//   - An error if the transfers could not be read
+func (s *DocumentTransferService) ListTransfers(ctx context.Context, userID int64) ([]*models.DocumentTransfer, error) {
+	return s.transferRepo.List(ctx, userID, constants.DocumentTransferListLimit)
+}
@@ -0,0 +1,221 @@
+package service
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"sort"
+	"testing"
+
+	"github.com/yasinhessnawi1/Hideme_Backend/internal/models"
+	"github.com/yasinhessnawi1/Hideme_Backend/internal/testsupport/fakeclock"
+	"github.com/yasinhessnawi1/Hideme_Backend/internal/testsupport/fixtures"
+	"github.com/yasinhessnawi1/Hideme_Backend/internal/utils"
+)
+
+// transferringDocumentRepository moves documents between users in memory
+type transferringDocumentRepository struct {
+	*memoryDocumentRepository
+	contents *MockDocumentContentRepository
+}
+
+func (r *transferringDocumentRepository) TransferOwnership(ctx context.Context, documentID, fromUserID, toUserID int64, storageKey string) error {
+	doc, ok := r.docs[documentID]
+	if !ok || doc.UserID != fromUserID {
+		return utils.NewNotFoundError("Document", documentID)
+	}
+	doc.UserID = toUserID
+	if storageKey != "" {
+		r.contents.contents[documentID].StorageKey = storageKey
+	}
+	return nil
+}
+
+func (r *transferringDocumentRepository) ListByUserIDAfter(ctx context.Context, userID, afterID int64, limit int) ([]*models.Document, error) {
+	var docs []*models.Document
+	for _, doc := range r.docs {
+		if doc.UserID == userID && doc.ID > afterID {
+			copied := *doc
+			docs = append(docs, &copied)
+		}
+	}
+	sort.Slice(docs, func(i, j int) bool { return docs[i].ID < docs[j].ID })
+	if len(docs) > limit {
+		docs = docs[:limit]
+	}
+	return docs, nil
+}
+
+// memoryDocumentTransferRepository records copies of transfers in memory, so a transfer
+// only changes when it is updated
+type memoryDocumentTransferRepository struct {
+	transfers      []*models.DocumentTransfer
+	failCompletion bool
+}
+
+func (m *memoryDocumentTransferRepository) Create(ctx context.Context, transfer *models.DocumentTransfer) error {
+	transfer.ID = int64(len(m.transfers) + 1)
+	m.transfers = append(m.transfers, copyDocumentTransfer(transfer))
+	return nil
+}
+
+func (m *memoryDocumentTransferRepository) Update(ctx context.Context, transfer *models.DocumentTransfer) error {
+	if m.failCompletion && transfer.Status == models.TransferStatusCompleted {
+		return errors.New("database error")
+	}
+	m.transfers[transfer.ID-1] = copyDocumentTransfer(transfer)
+	return nil
+}
+
+func copyDocumentTransfer(transfer *models.DocumentTransfer) *models.DocumentTransfer {
+	copied := *transfer
+	copied.DocumentIDs = append([]int64{}, transfer.DocumentIDs...)
+	copied.FailedDocumentIDs = append([]int64{}, transfer.FailedDocumentIDs...)
+	return &copied
+}
+
+func (m *memoryDocumentTransferRepository) List(ctx context.Context, userID int64, limit int) ([]*models.DocumentTransfer, error) {
+	var transfers []*models.DocumentTransfer
+	for i := len(m.transfers) - 1; i >= 0 && len(transfers) < limit; i-- {
+		transfer := m.transfers[i]
+		if userID == 0 || transfer.FromUserID == userID || transfer.ToUserID == userID {
+			transfers = append(transfers, transfer)
+		}
+	}
+	return transfers, nil
+}
+
+func TestDocumentTransferService(t *testing.T) {
+	t.Setenv("API_KEY_ENCRYPTION_KEY", "0123456789abcdef0123456789abcdef")
+	memory := &memoryDocumentRepository{docs: map[int64]*models.Document{}}
+	contents := &MockDocumentContentRepository{contents: map[int64]*models.DocumentContent{}, docs: memory}
+	docs := &transferringDocumentRepository{memoryDocumentRepository: memory, contents: contents}
+	store := &memoryDocumentStore{objects: map[string][]byte{}}
+	keys := &stubContentKeys{keys: map[int64][]byte{}}
+	hashes := &memoryEntityHashRepository{}
+	docService := NewDocumentService(docs, &MockProcessingAuditRepository{}, nil)
+	docService.SetContentStorage(contents, store, keys)
+	docService.SetEntityIndexer(NewEntityHashService(hashes, keys))
+	users := NewMockUserRepository()
+	for _, user := range []*models.User{
+		{Username: "leaver", Email: "leaver@example.com"},
+		{Username: "colleague", Email: "colleague@example.com"},
+	} {
+		if err := users.Create(context.Background(), user); err != nil {
+			t.Fatalf("Create() error = %v", err)
+		}
+	}
+	transfers := &memoryDocumentTransferRepository{}
+	svc := NewDocumentTransferService(transfers, docs, users, docService)
+	svc.SetClock(fakeclock.New(fixtures.Time))
+	ctx := context.Background()
+	schema := sensitiveSchema("PERSON", "Ola Nordmann")
+	pdf := []byte("%PDF-1.7\nOla Nordmann\n%%EOF")
+
+	contract, err := docService.UploadDocumentWithContent(ctx, 1, "contract.pdf", "nb", "", schema, pdf)
+	if err != nil {
+		t.Fatalf("UploadDocumentWithContent() error = %v", err)
+	}
+	notes, err := docService.UploadDocument(ctx, 1, "notes.pdf", "nb", "", schema)
+	if err != nil {
+		t.Fatalf("UploadDocument() error = %v", err)
+	}
+	oldKey := contents.contents[contract.ID].StorageKey
+
+	t.Run("Users must differ and exist", func(t *testing.T) {
+		if _, err := svc.TransferDocuments(ctx, 9, &models.DocumentTransferRequest{FromUserID: 1, ToUserID: 1, Reason: "Offboarding"}); !errors.Is(err, ErrTransferToSelf) {
+			t.Errorf("TransferDocuments() error = %v, want ErrTransferToSelf", err)
+		}
+		if _, err := svc.TransferDocuments(ctx, 9, &models.DocumentTransferRequest{FromUserID: 1, ToUserID: 5, Reason: "Offboarding"}); !errors.Is(err, ErrTransferUserNotFound) {
+			t.Errorf("TransferDocuments() error = %v, want ErrTransferUserNotFound", err)
+		}
+		if len(transfers.transfers) != 0 {
+			t.Errorf("recorded %d transfers, want none", len(transfers.transfers))
+		}
+	})
+
+	t.Run("Selected documents", func(t *testing.T) {
+		transfer, err := svc.TransferDocuments(ctx, 9, &models.DocumentTransferRequest{
+			FromUserID:  1,
+			ToUserID:    2,
+			DocumentIDs: []int64{contract.ID, contract.ID, 404},
+			Reason:      "Offboarding",
+		})
+		if err != nil {
+			t.Fatalf("TransferDocuments() error = %v", err)
+		}
+		if len(transfer.DocumentIDs) != 1 || transfer.DocumentIDs[0] != contract.ID || len(transfer.FailedDocumentIDs) != 1 || transfer.FailedDocumentIDs[0] != 404 {
+			t.Errorf("transfer = %+v, want the contract transferred and the unknown document failed", transfer)
+		}
+		if transfer.TransferredBy != 9 || len(transfers.transfers) != 1 {
+			t.Errorf("recorded %d transfers by %d, want one by the administrator", len(transfers.transfers), transfer.TransferredBy)
+		}
+		recorded := transfers.transfers[0]
+		if recorded.Status != models.TransferStatusCompleted || recorded.CompletedAt == nil || !recorded.CompletedAt.Equal(fixtures.Time) {
+			t.Errorf("recorded transfer = %+v, want it completed at %v", recorded, fixtures.Time)
+		}
+		if len(recorded.DocumentIDs) != 1 || len(recorded.FailedDocumentIDs) != 1 {
+			t.Errorf("recorded transfer = %+v, want the transferred and failed documents", recorded)
+		}
+	})
+
+	t.Run("Content is re-encrypted for the new owner", func(t *testing.T) {
+		if _, ok := store.objects[oldKey]; ok {
+			t.Errorf("storage still holds %q, want the content of the previous owner deleted", oldKey)
+		}
+		stored := contents.contents[contract.ID]
+		if content, err := utils.DecryptSegments(store.objects[stored.StorageKey], keys.keys[2], stored.SegmentSize); err != nil || !bytes.Equal(content, pdf) {
+			t.Errorf("content under %q = %q, %v, want the PDF encrypted for the new owner", stored.StorageKey, content, err)
+		}
+		if _, content, err := docService.GetDocumentContent(ctx, 2, contract.ID); err != nil || !bytes.Equal(content, pdf) {
+			t.Errorf("GetDocumentContent() = %q, %v, want the PDF for the new owner", content, err)
+		}
+		if _, _, err := docService.GetDocumentContent(ctx, 1, contract.ID); !errors.Is(err, ErrDocumentNotFound) {
+			t.Errorf("GetDocumentContent() error = %v, want ErrDocumentNotFound for the previous owner", err)
+		}
+	})
+
+	t.Run("Hashes belong to the new owner", func(t *testing.T) {
+		for _, hash := range hashes.hashes {
+			if hash.DocumentID == contract.ID && hash.UserID != 2 {
+				t.Errorf("hash of the contract belongs to user %d, want the new owner", hash.UserID)
+			}
+		}
+	})
+
+	t.Run("All documents", func(t *testing.T) {
+		transfer, err := svc.TransferDocuments(ctx, 9, &models.DocumentTransferRequest{FromUserID: 1, ToUserID: 2, Reason: "Offboarding"})
+		if err != nil {
+			t.Fatalf("TransferDocuments() error = %v", err)
+		}
+		if len(transfer.DocumentIDs) != 1 || transfer.DocumentIDs[0] != notes.ID || len(transfer.FailedDocumentIDs) != 0 {
+			t.Errorf("transfer = %+v, want the remaining document transferred", transfer)
+		}
+		if doc, err := docService.GetDocumentByID(ctx, 2, notes.ID); err != nil || doc.HashedDocumentName != "notes.pdf" {
+			t.Errorf("GetDocumentByID() = %+v, %v, want the document readable by the new owner", doc, err)
+		}
+	})
+
+	t.Run("List", func(t *testing.T) {
+		listed, err := svc.ListTransfers(ctx, 1)
+		if err != nil || len(listed) != 2 || listed[0].DocumentIDs[0] != notes.ID {
+			t.Errorf("ListTransfers() = %v, %v, want both transfers, latest first", listed, err)
+		}
+	})
+
+	t.Run("Interrupted transfer keeps the moved documents", func(t *testing.T) {
+		transfers.failCompletion = true
+		defer func() { transfers.failCompletion = false }()
+
+		if _, err := svc.TransferDocuments(ctx, 9, &models.DocumentTransferRequest{FromUserID: 2, ToUserID: 1, Reason: "Returned"}); err == nil {
+			t.Fatal("TransferDocuments() error = nil, want the failed completion")
+		}
+		recorded := transfers.transfers[len(transfers.transfers)-1]
+		if recorded.Status != models.TransferStatusPending || recorded.CompletedAt != nil {
+			t.Errorf("recorded transfer = %+v, want it pending", recorded)
+		}
+		if len(recorded.DocumentIDs) != 2 {
+			t.Errorf("recorded transfer lists %v, want both moved documents", recorded.DocumentIDs)
+		}
+	})
+}
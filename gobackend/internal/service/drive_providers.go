@@ -0,0 +1,732 @@
+// Package service provides business logic implementations.
+//
+// This file implements the cloud drive providers: the OAuth authorization code flow
+// and the file APIs of Google Drive and Microsoft Graph, which serves both OneDrive
+// and SharePoint.
+package service
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/yasinhessnawi1/Hideme_Backend/internal/config"
+	"github.com/yasinhessnawi1/Hideme_Backend/internal/constants"
+	"github.com/yasinhessnawi1/Hideme_Backend/internal/models"
+	"github.com/yasinhessnawi1/Hideme_Backend/internal/utils"
+	"github.com/yasinhessnawi1/Hideme_Backend/internal/utils/clock"
+)
+
+// errDriveTokenRejected is returned when a provider rejects a token, because it
+// expired without a refresh token or the user revoked the authorization.
+var errDriveTokenRejected = errors.New("cloud drive rejected the token")
+
+// errDriveFolder is returned when a folder is requested for download.
+var errDriveFolder = errors.New("cloud drive item is a folder")
+
+// DriveToken is the result of an OAuth token request.
+type DriveToken struct {
+	// AccessToken authorizes requests to the drive's API
+	AccessToken string
+
+	// RefreshToken obtains a new access token; empty if the provider issued none
+	RefreshToken string
+
+	// ExpiresAt is when the access token expires
+	ExpiresAt time.Time
+}
+
+// DriveProvider is a cloud drive files can be imported from.
+type DriveProvider interface {
+	// AuthCodeURL returns the consent page the user is sent to.
+	//
+	// Parameters:
+	//   - state: The signed state the provider passes back with the code
+	//   - redirectURI: Where the provider sends the user back to
+	//
+	// Returns:
+	//   - The URL of the consent page
+	AuthCodeURL(state, redirectURI string) string
+
+	// Exchange exchanges an authorization code for tokens.
+	//
+	// Parameters:
+	//   - ctx: Context for cancellation control
+	//   - code: The authorization code
+	//   - redirectURI: The redirect URI the code was issued for
+	//
+	// Returns:
+	//   - The tokens
+	//   - errDriveTokenRejected if the code is invalid or expired
+	Exchange(ctx context.Context, code, redirectURI string) (*DriveToken, error)
+
+	// Refresh obtains a new access token, keeping the refresh token if the provider issues no new one.
+	//
+	// Parameters:
+	//   - ctx: Context for cancellation control
+	//   - refreshToken: The refresh token
+	//
+	// Returns:
+	//   - The tokens
+	//   - errDriveTokenRejected if the authorization was revoked
+	Refresh(ctx context.Context, refreshToken string) (*DriveToken, error)
+
+	// ListFiles lists a page of a folder.
+	//
+	// Parameters:
+	//   - ctx: Context for cancellation control
+	//   - accessToken: The access token
+	//   - folderID: The folder to list; empty for the top level
+	//   - pageToken: The next page token of the previous page; empty for the first page
+	//
+	// Returns:
+	//   - The page of files and folders
+	//   - errDriveTokenRejected if the access token is rejected
+	ListFiles(ctx context.Context, accessToken, folderID, pageToken string) (*models.DriveFileList, error)
+
+	// Download opens the content of a file. The caller must close the body.
+	//
+	// Parameters:
+	//   - ctx: Context for cancellation control; it also bounds reading the body
+	//   - accessToken: The access token
+	//   - fileID: The file to download
+	//
+	// Returns:
+	//   - The content of the file
+	//   - errDriveFolder if the ID is a folder
+	//   - errDriveTokenRejected if the access token is rejected
+	Download(ctx context.Context, accessToken, fileID string) (*models.DriveDownload, error)
+}
+
+// NewDriveProviders creates the providers that have an OAuth client configured.
+//
+// Parameters:
+//   - settings: The cloud drive configuration
+//   - client: The HTTP client for requests to the providers; http.DefaultClient if nil
+//
+// Returns:
+//   - The configured providers by name
+func NewDriveProviders(settings *config.DriveSettings, client *http.Client) map[string]DriveProvider {
+	if client == nil {
+		client = http.DefaultClient
+	}
+
+	providers := make(map[string]DriveProvider)
+	if settings.GoogleClientID != "" && settings.GoogleClientSecret != "" {
+		providers[constants.DriveProviderGoogle] = NewGoogleDriveProvider(settings.GoogleClientID, settings.GoogleClientSecret, client)
+	}
+	if settings.MicrosoftClientID != "" && settings.MicrosoftClientSecret != "" {
+		providers[constants.DriveProviderOneDrive] = NewGraphDriveProvider(settings.MicrosoftClientID, settings.MicrosoftClientSecret, settings.MicrosoftTenant, false, client)
+		providers[constants.DriveProviderSharePoint] = NewGraphDriveProvider(settings.MicrosoftClientID, settings.MicrosoftClientSecret, settings.MicrosoftTenant, true, client)
+	}
+
+	return providers
+}
+
+// oauthClient implements the OAuth authorization code flow shared by the providers.
+type oauthClient struct {
+	clientID     string
+	clientSecret string
+	authURL      string
+	tokenURL     string
+	scope        string
+	authParams   url.Values
+	client       *http.Client
+	clock        clock.Clock
+}
+
+// now returns the current time of the client's clock.
+func (c *oauthClient) now() time.Time {
+	return clock.Or(c.clock).Now()
+}
+
+// authCodeURL returns the consent page of the provider.
+func (c *oauthClient) authCodeURL(state, redirectURI string) string {
+	params := url.Values{}
+	params.Set("client_id", c.clientID)
+	params.Set("redirect_uri", redirectURI)
+	params.Set("response_type", "code")
+	params.Set("scope", c.scope)
+	params.Set("state", state)
+	for key, values := range c.authParams {
+		params[key] = values
+	}
+
+	return c.authURL + "?" + params.Encode()
+}
+
+// exchange exchanges an authorization code for tokens.
+func (c *oauthClient) exchange(ctx context.Context, code, redirectURI string) (*DriveToken, error) {
+	return c.requestToken(ctx, url.Values{
+		"grant_type":   {"authorization_code"},
+		"code":         {code},
+		"redirect_uri": {redirectURI},
+	})
+}
+
+// refresh obtains a new access token, keeping the refresh token if none is issued.
+func (c *oauthClient) refresh(ctx context.Context, refreshToken string) (*DriveToken, error) {
+	token, err := c.requestToken(ctx, url.Values{
+		"grant_type":    {"refresh_token"},
+		"refresh_token": {refreshToken},
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	if token.RefreshToken == "" {
+		token.RefreshToken = refreshToken
+	}
+	return token, nil
+}
+
+// requestToken posts a grant to the token endpoint.
+func (c *oauthClient) requestToken(ctx context.Context, form url.Values) (*DriveToken, error) {
+	ctx, cancel := context.WithTimeout(ctx, constants.DriveRequestTimeout)
+	defer cancel()
+
+	form.Set("client_id", c.clientID)
+	form.Set("client_secret", c.clientSecret)
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, c.tokenURL, strings.NewReader(form.Encode()))
+	if err != nil {
+		return nil, fmt.Errorf("failed to create token request: %w", err)
+	}
+	req.Header.Set(constants.HeaderContentType, constants.ContentTypeForm)
+	req.Header.Set(constants.HeaderAccept, constants.ContentTypeJSON)
+
+	resp, err := c.client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("failed to request token: %w", err)
+	}
+	defer resp.Body.Close()
+
+	var body struct {
+		AccessToken  string `json:"access_token"`
+		RefreshToken string `json:"refresh_token"`
+		ExpiresIn    int64  `json:"expires_in"`
+		Error        string `json:"error"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&body); err != nil {
+		return nil, fmt.Errorf("failed to decode token response (status %d): %w", resp.StatusCode, err)
+	}
+
+	// An invalid grant is an expired or revoked code or refresh token
+	if body.Error == "invalid_grant" {
+		return nil, errDriveTokenRejected
+	}
+	if resp.StatusCode != http.StatusOK || body.AccessToken == "" {
+		return nil, fmt.Errorf("token request failed with status %d: %s", resp.StatusCode, body.Error)
+	}
+
+	return &DriveToken{
+		AccessToken:  body.AccessToken,
+		RefreshToken: body.RefreshToken,
+		ExpiresAt:    c.now().Add(time.Duration(body.ExpiresIn) * time.Second),
+	}, nil
+}
+
+// driveGet sends an authorized GET request to a drive API.
+// Responses other than 200 are closed and returned as errors.
+func driveGet(ctx context.Context, client *http.Client, accessToken, requestURL string) (*http.Response, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, requestURL, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create drive request: %w", err)
+	}
+	req.Header.Set(constants.HeaderAuthorization, constants.BearerTokenPrefix+accessToken)
+
+	resp, err := client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("drive request failed: %w", err)
+	}
+
+	switch resp.StatusCode {
+	case http.StatusOK:
+		return resp, nil
+	case http.StatusUnauthorized:
+		resp.Body.Close()
+		return nil, errDriveTokenRejected
+	case http.StatusNotFound:
+		resp.Body.Close()
+		return nil, utils.NewNotFoundError("Drive file", "")
+	default:
+		resp.Body.Close()
+		return nil, fmt.Errorf("drive request failed with status %d", resp.StatusCode)
+	}
+}
+
+// driveGetJSON sends an authorized GET request to a drive API and decodes the JSON response.
+func driveGetJSON(ctx context.Context, client *http.Client, accessToken, requestURL string, out interface{}) error {
+	ctx, cancel := context.WithTimeout(ctx, constants.DriveRequestTimeout)
+	defer cancel()
+
+	resp, err := driveGet(ctx, client, accessToken, requestURL)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if err := json.NewDecoder(resp.Body).Decode(out); err != nil {
+		return fmt.Errorf("failed to decode drive response: %w", err)
+	}
+	return nil
+}
+
+// GoogleDriveProvider imports files from Google Drive.
+type GoogleDriveProvider struct {
+	oauth  *oauthClient
+	apiURL string
+	client *http.Client
+}
+
+// NewGoogleDriveProvider creates a provider for Google Drive.
+//
+// Parameters:
+//   - clientID: The OAuth client ID
+//   - clientSecret: The OAuth client secret
+//   - client: The HTTP client for requests to Google
+//
+// Returns:
+//   - A configured GoogleDriveProvider
+func NewGoogleDriveProvider(clientID, clientSecret string, client *http.Client) *GoogleDriveProvider {
+	return &GoogleDriveProvider{
+		oauth: &oauthClient{
+			clientID:     clientID,
+			clientSecret: clientSecret,
+			authURL:      constants.GoogleAuthURL,
+			tokenURL:     constants.GoogleTokenURL,
+			scope:        constants.GoogleDriveScope,
+			// Offline access with forced consent, so every authorization returns a refresh token
+			authParams: url.Values{"access_type": {"offline"}, "prompt": {"consent"}},
+			client:     client,
+		},
+		apiURL: constants.GoogleDriveAPIURL,
+		client: client,
+	}
+}
+
+// SetClock sets the clock the expiry of issued tokens is counted from.
+func (p *GoogleDriveProvider) SetClock(c clock.Clock) {
+	p.oauth.clock = c
+}
+
+// googleFile is a file in the responses of the Google Drive API.
+type googleFile struct {
+	ID           string     `json:"id"`
+	Name         string     `json:"name"`
+	MimeType     string     `json:"mimeType"`
+	Size         int64      `json:"size,string"`
+	ModifiedTime *time.Time `json:"modifiedTime"`
+}
+
+// googleFileFields are the file fields requested from the Google Drive API.
+const googleFileFields = "id,name,mimeType,size,modifiedTime"
+
+// AuthCodeURL returns the Google consent page.
+func (p *GoogleDriveProvider) AuthCodeURL(state, redirectURI string) string {
+	return p.oauth.authCodeURL(state, redirectURI)
+}
+
+// Exchange exchanges an authorization code for tokens.
+func (p *GoogleDriveProvider) Exchange(ctx context.Context, code, redirectURI string) (*DriveToken, error) {
+	return p.oauth.exchange(ctx, code, redirectURI)
+}
+
+// Refresh obtains a new access token.
+func (p *GoogleDriveProvider) Refresh(ctx context.Context, refreshToken string) (*DriveToken, error) {
+	return p.oauth.refresh(ctx, refreshToken)
+}
+
+// ListFiles lists a page of a folder, the root folder if none is given, folders first.
+func (p *GoogleDriveProvider) ListFiles(ctx context.Context, accessToken, folderID, pageToken string) (*models.DriveFileList, error) {
+	if folderID == "" {
+		folderID = "root"
+	}
+
+	// Quotes and backslashes in the folder ID are escaped in the query
+	escaped := strings.NewReplacer(`\`, `\\`, `'`, `\'`).Replace(folderID)
+
+	params := url.Values{}
+	params.Set("q", "'"+escaped+"' in parents and trashed = false")
+	params.Set("fields", "nextPageToken,files("+googleFileFields+")")
+	params.Set("orderBy", "folder,name")
+	params.Set("pageSize", fmt.Sprint(constants.DriveListPageSize))
+	if pageToken != "" {
+		params.Set("pageToken", pageToken)
+	}
+
+	var body struct {
+		Files         []googleFile `json:"files"`
+		NextPageToken string       `json:"nextPageToken"`
+	}
+	if err := driveGetJSON(ctx, p.client, accessToken, p.apiURL+"/files?"+params.Encode(), &body); err != nil {
+		return nil, err
+	}
+
+	list := &models.DriveFileList{
+		Files:         make([]*models.DriveFile, 0, len(body.Files)),
+		NextPageToken: body.NextPageToken,
+	}
+	for _, f := range body.Files {
+		list.Files = append(list.Files, &models.DriveFile{
+			ID:         f.ID,
+			Name:       f.Name,
+			MimeType:   f.MimeType,
+			Size:       f.Size,
+			IsFolder:   f.MimeType == constants.GoogleFolderMimeType,
+			ModifiedAt: f.ModifiedTime,
+		})
+	}
+
+	return list, nil
+}
+
+// Download opens the content of a file. Native Google documents have no content
+// of their own and are exported as PDF.
+func (p *GoogleDriveProvider) Download(ctx context.Context, accessToken, fileID string) (*models.DriveDownload, error) {
+	fileURL := p.apiURL + "/files/" + url.PathEscape(fileID)
+
+	var file googleFile
+	if err := driveGetJSON(ctx, p.client, accessToken, fileURL+"?fields="+url.QueryEscape(googleFileFields), &file); err != nil {
+		return nil, err
+	}
+
+	download := &models.DriveDownload{
+		Name:     file.Name,
+		MimeType: file.MimeType,
+		Size:     file.Size,
+	}
+
+	contentURL := fileURL + "?alt=media"
+	switch {
+	case file.MimeType == constants.GoogleFolderMimeType:
+		return nil, errDriveFolder
+	case strings.HasPrefix(file.MimeType, constants.GoogleAppsMimePrefix):
+		contentURL = fileURL + "/export?mimeType=" + url.QueryEscape(constants.ContentTypePDF)
+		download.Name += ".pdf"
+		download.MimeType = constants.ContentTypePDF
+		download.Size = 0
+	}
+
+	resp, err := driveGet(ctx, p.client, accessToken, contentURL)
+	if err != nil {
+		return nil, err
+	}
+	download.Body = resp.Body
+
+	return download, nil
+}
+
+// GraphDriveProvider imports files through Microsoft Graph, from the user's OneDrive
+// or from the default document libraries of the SharePoint sites the user can open.
+type GraphDriveProvider struct {
+	oauth      *oauthClient
+	apiURL     string
+	sharePoint bool
+	client     *http.Client
+}
+
+// NewGraphDriveProvider creates a provider for OneDrive or SharePoint.
+//
+// Parameters:
+//   - clientID: The OAuth client ID
+//   - clientSecret: The OAuth client secret
+//   - tenant: The Microsoft Entra tenant users sign in to
+//   - sharePoint: Whether to list SharePoint sites instead of the user's OneDrive
+//   - client: The HTTP client for requests to Microsoft
+//
+// Returns:
+//   - A configured GraphDriveProvider
+func NewGraphDriveProvider(clientID, clientSecret, tenant string, sharePoint bool, client *http.Client) *GraphDriveProvider {
+	scope := constants.OneDriveScope
+	if sharePoint {
+		scope = constants.SharePointScope
+	}
+
+	return &GraphDriveProvider{
+		oauth: &oauthClient{
+			clientID:     clientID,
+			clientSecret: clientSecret,
+			authURL:      fmt.Sprintf(constants.MicrosoftAuthURLFormat, url.PathEscape(tenant)),
+			tokenURL:     fmt.Sprintf(constants.MicrosoftTokenURLFormat, url.PathEscape(tenant)),
+			scope:        scope,
+			client:       client,
+		},
+		apiURL:     constants.MicrosoftGraphURL,
+		sharePoint: sharePoint,
+		client:     client,
+	}
+}
+
+// SetClock sets the clock the expiry of issued tokens is counted from.
+func (p *GraphDriveProvider) SetClock(c clock.Clock) {
+	p.oauth.clock = c
+}
+
+// graphItem is a drive item in the responses of Microsoft Graph.
+type graphItem struct {
+	ID                   string     `json:"id"`
+	Name                 string     `json:"name"`
+	Size                 int64      `json:"size"`
+	LastModifiedDateTime *time.Time `json:"lastModifiedDateTime"`
+	Folder               *struct{}  `json:"folder"`
+	File                 *struct {
+		MimeType string `json:"mimeType"`
+	} `json:"file"`
+	ParentReference struct {
+		DriveID string `json:"driveId"`
+	} `json:"parentReference"`
+}
+
+// graphSitePrefix marks the IDs of SharePoint sites, which are listed as folders.
+const graphSitePrefix = "site:"
+
+// AuthCodeURL returns the Microsoft consent page.
+func (p *GraphDriveProvider) AuthCodeURL(state, redirectURI string) string {
+	return p.oauth.authCodeURL(state, redirectURI)
+}
+
+// Exchange exchanges an authorization code for tokens.
+func (p *GraphDriveProvider) Exchange(ctx context.Context, code, redirectURI string) (*DriveToken, error) {
+	return p.oauth.exchange(ctx, code, redirectURI)
+}
+
+// Refresh obtains a new access token.
+func (p *GraphDriveProvider) Refresh(ctx context.Context, refreshToken string) (*DriveToken, error) {
+	return p.oauth.refresh(ctx, refreshToken)
+}
+
+// ListFiles lists a page of a folder. The top level is the root of the user's OneDrive,
+// or the user's SharePoint sites; a site lists the root of its default document library.
+// Items are identified as {driveId}:{itemId}, since they can be in different drives.
+func (p *GraphDriveProvider) ListFiles(ctx context.Context, accessToken, folderID, pageToken string) (*models.DriveFileList, error) {
+	var listURL string
+	switch {
+	case pageToken != "":
+		// The page token is the next link returned by Graph; anything else would send
+		// the access token to another host
+		if !strings.HasPrefix(pageToken, p.apiURL+"/") {
+			return nil, utils.NewValidationError(constants.QueryParamPageToken, "Invalid page token")
+		}
+		listURL = pageToken
+	case folderID == "" && p.sharePoint:
+		return p.listSites(ctx, accessToken)
+	case folderID == "":
+		listURL = p.apiURL + "/me/drive/root/children"
+	case strings.HasPrefix(folderID, graphSitePrefix):
+		listURL = p.apiURL + "/sites/" + url.PathEscape(strings.TrimPrefix(folderID, graphSitePrefix)) + "/drive/root/children"
+	default:
+		driveID, itemID, err := splitGraphItemID(folderID)
+		if err != nil {
+			return nil, err
+		}
+		listURL = p.apiURL + "/drives/" + url.PathEscape(driveID) + "/items/" + url.PathEscape(itemID) + "/children"
+	}
+	if pageToken == "" {
+		listURL += fmt.Sprintf("?$top=%d", constants.DriveListPageSize)
+	}
+
+	var body struct {
+		Value    []graphItem `json:"value"`
+		NextLink string      `json:"@odata.nextLink"`
+	}
+	if err := driveGetJSON(ctx, p.client, accessToken, listURL, &body); err != nil {
+		return nil, err
+	}
+
+	list := &models.DriveFileList{
+		Files:         make([]*models.DriveFile, 0, len(body.Value)),
+		NextPageToken: body.NextLink,
+	}
+	for _, item := range body.Value {
+		list.Files = append(list.Files, graphDriveFile(&item))
+	}
+
+	return list, nil
+}
+
+// listSites lists the SharePoint sites the user can open as folders.
+func (p *GraphDriveProvider) listSites(ctx context.Context, accessToken string) (*models.DriveFileList, error) {
+	var body struct {
+		Value []struct {
+			ID                   string     `json:"id"`
+			DisplayName          string     `json:"displayName"`
+			LastModifiedDateTime *time.Time `json:"lastModifiedDateTime"`
+		} `json:"value"`
+		NextLink string `json:"@odata.nextLink"`
+	}
+	if err := driveGetJSON(ctx, p.client, accessToken, p.apiURL+"/sites?search=*", &body); err != nil {
+		return nil, err
+	}
+
+	list := &models.DriveFileList{
+		Files:         make([]*models.DriveFile, 0, len(body.Value)),
+		NextPageToken: body.NextLink,
+	}
+	for _, site := range body.Value {
+		list.Files = append(list.Files, &models.DriveFile{
+			ID:         graphSitePrefix + site.ID,
+			Name:       site.DisplayName,
+			IsFolder:   true,
+			ModifiedAt: site.LastModifiedDateTime,
+		})
+	}
+
+	return list, nil
+}
+
+// Download opens the content of a file. Graph redirects the content request to a
+// pre-authenticated URL; the client drops the access token when following it to another host.
+func (p *GraphDriveProvider) Download(ctx context.Context, accessToken, fileID string) (*models.DriveDownload, error) {
+	if strings.HasPrefix(fileID, graphSitePrefix) {
+		return nil, errDriveFolder
+	}
+
+	driveID, itemID, err := splitGraphItemID(fileID)
+	if err != nil {
+		return nil, err
+	}
+	itemURL := p.apiURL + "/drives/" + url.PathEscape(driveID) + "/items/" + url.PathEscape(itemID)
+
+	var item graphItem
+	if err := driveGetJSON(ctx, p.client, accessToken, itemURL, &item); err != nil {
+		return nil, err
+	}
+	if item.Folder != nil {
+		return nil, errDriveFolder
+	}
+
+	resp, err := driveGet(ctx, p.client, accessToken, itemURL+"/content")
+	if err != nil {
+		return nil, err
+	}
+
+	download := &models.DriveDownload{
+		Name: item.Name,
+		Size: item.Size,
+		Body: resp.Body,
+	}
+	if item.File != nil {
+		download.MimeType = item.File.MimeType
+	}
+
+	return download, nil
+}
+
+// driveUploader is implemented by the providers files can be written to.
+type driveUploader interface {
+	// Upload writes a file into a folder, replacing a file with the same name.
+	//
+	// Parameters:
+	//   - ctx: Context for cancellation control
+	//   - accessToken: The access token
+	//   - folderID: The folder to write to
+	//   - name: The name of the file
+	//   - contentType: The media type of the file
+	//   - content: The content of the file
+	//
+	// Returns:
+	//   - errDriveTokenRejected if the access token is rejected
+	//   - Other errors if the folder doesn't exist or the write is refused
+	Upload(ctx context.Context, accessToken, folderID, name, contentType string, content []byte) error
+}
+
+// Upload writes a file into a folder, or into the root of a site's default document
+// library. Graph accepts files up to 250 MB in a single request.
+func (p *GraphDriveProvider) Upload(ctx context.Context, accessToken, folderID, name, contentType string, content []byte) error {
+	var uploadURL string
+	switch {
+	case strings.HasPrefix(folderID, graphSitePrefix):
+		uploadURL = p.apiURL + "/sites/" + url.PathEscape(strings.TrimPrefix(folderID, graphSitePrefix)) +
+			"/drive/root:/" + url.PathEscape(name) + ":/content"
+	default:
+		driveID, itemID, err := splitGraphItemID(folderID)
+		if err != nil {
+			return err
+		}
+		uploadURL = p.apiURL + "/drives/" + url.PathEscape(driveID) + "/items/" + url.PathEscape(itemID) +
+			":/" + url.PathEscape(name) + ":/content"
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPut, uploadURL, bytes.NewReader(content))
+	if err != nil {
+		return fmt.Errorf("failed to create drive request: %w", err)
+	}
+	req.Header.Set(constants.HeaderAuthorization, constants.BearerTokenPrefix+accessToken)
+	req.Header.Set(constants.HeaderContentType, contentType)
+
+	resp, err := p.client.Do(req)
+	if err != nil {
+		return fmt.Errorf("drive request failed: %w", err)
+	}
+	defer resp.Body.Close()
+
+	switch resp.StatusCode {
+	case http.StatusOK, http.StatusCreated:
+		return nil
+	case http.StatusUnauthorized:
+		return errDriveTokenRejected
+	case http.StatusNotFound:
+		return utils.NewNotFoundError("Drive folder", folderID)
+	default:
+		return fmt.Errorf("drive upload failed with status %d", resp.StatusCode)
+	}
+}
+
+// graphDriveFile converts a Graph drive item.
+func graphDriveFile(item *graphItem) *models.DriveFile {
+	file := &models.DriveFile{
+		ID:         item.ParentReference.DriveID + ":" + item.ID,
+		Name:       item.Name,
+		Size:       item.Size,
+		IsFolder:   item.Folder != nil,
+		ModifiedAt: item.LastModifiedDateTime,
+	}
+	if item.File != nil {
+		file.MimeType = item.File.MimeType
+	}
+	if file.IsFolder {
+		file.Size = 0
+	}
+	return file
+}
+
+// splitGraphItemID splits a {driveId}:{itemId} file ID.
+func splitGraphItemID(id string) (string, string, error) {
+	driveID, itemID, ok := strings.Cut(id, ":")
+	if !ok || driveID == "" || itemID == "" {
+		return "", "", utils.NewNotFoundError("Drive file", id)
+	}
+	return driveID, itemID, nil
+}
+
+// limitedBody fails reading once more than a limit has been read, so a download
+// without a known size can't exceed the import limit.
+type limitedBody struct {
+	io.ReadCloser
+	remaining int64
+}
+
+// Read reads from the body until the limit is exceeded.
+func (b *limitedBody) Read(p []byte) (int, error) {
+	if b.remaining < 0 {
+		return 0, utils.NewBadRequestError(constants.MsgDriveFileTooLarge)
+	}
+	if int64(len(p)) > b.remaining+1 {
+		p = p[:b.remaining+1]
+	}
+	n, err := b.ReadCloser.Read(p)
+	b.remaining -= int64(n)
+	if b.remaining < 0 {
+		// Only the byte past the limit is held back
+		return n - 1, utils.NewBadRequestError(constants.MsgDriveFileTooLarge)
+	}
+	return n, err
+}
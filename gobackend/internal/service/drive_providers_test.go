@@ -0,0 +1,281 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+
+	"github.com/yasinhessnawi1/Hideme_Backend/internal/config"
+	"github.com/yasinhessnawi1/Hideme_Backend/internal/constants"
+	"github.com/yasinhessnawi1/Hideme_Backend/internal/utils"
+)
+
+func TestGoogleDriveProvider_AuthCodeURL(t *testing.T) {
+	p := NewGoogleDriveProvider("client-id", "client-secret", http.DefaultClient)
+
+	authURL, err := url.Parse(p.AuthCodeURL("state-value", "https://app.test/callback"))
+	if err != nil {
+		t.Fatalf("Invalid authorization URL: %v", err)
+	}
+	query := authURL.Query()
+	if query.Get("state") != "state-value" || query.Get("client_id") != "client-id" {
+		t.Errorf("Expected the state and client ID, got %s", authURL)
+	}
+	if query.Get("access_type") != "offline" || query.Get("scope") != constants.GoogleDriveScope {
+		t.Errorf("Expected offline read-only access, got %s", authURL)
+	}
+}
+
+func TestOAuthClient_RequestToken(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if err := r.ParseForm(); err != nil {
+			t.Fatalf("Invalid form: %v", err)
+		}
+		w.Header().Set(constants.HeaderContentType, constants.ContentTypeJSON)
+		if r.PostForm.Get("refresh_token") == "revoked" {
+			w.WriteHeader(http.StatusBadRequest)
+			_, _ = w.Write([]byte(`{"error":"invalid_grant"}`))
+			return
+		}
+		_, _ = w.Write([]byte(`{"access_token":"new-access","expires_in":3600}`))
+	}))
+	defer server.Close()
+
+	client := &oauthClient{clientID: "id", clientSecret: "secret", tokenURL: server.URL, client: server.Client()}
+
+	t.Run("Keeps refresh token", func(t *testing.T) {
+		token, err := client.refresh(context.Background(), "refresh")
+		if err != nil {
+			t.Fatalf("refresh returned error: %v", err)
+		}
+		if token.AccessToken != "new-access" || token.RefreshToken != "refresh" {
+			t.Errorf("Expected a new access token and the old refresh token, got %+v", token)
+		}
+	})
+
+	t.Run("Revoked", func(t *testing.T) {
+		_, err := client.refresh(context.Background(), "revoked")
+		if !errors.Is(err, errDriveTokenRejected) {
+			t.Errorf("Expected errDriveTokenRejected, got %v", err)
+		}
+	})
+}
+
+func TestGoogleDriveProvider_Files(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get(constants.HeaderAuthorization) != "Bearer access" {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		switch {
+		case r.URL.Path == "/files" && r.URL.Query().Get("fields") != "":
+			if r.URL.Query().Get("q") != `'it\'s' in parents and trashed = false` {
+				t.Errorf("Unexpected query: %s", r.URL.Query().Get("q"))
+			}
+			_, _ = w.Write([]byte(`{"files":[
+				{"id":"f1","name":"Contracts","mimeType":"application/vnd.google-apps.folder"},
+				{"id":"f2","name":"lease.pdf","mimeType":"application/pdf","size":"1234"}
+			],"nextPageToken":"next"}`))
+		case r.URL.Path == "/files/doc1":
+			_, _ = w.Write([]byte(`{"id":"doc1","name":"Notes","mimeType":"application/vnd.google-apps.document"}`))
+		case r.URL.Path == "/files/doc1/export":
+			_, _ = w.Write([]byte("%PDF"))
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer server.Close()
+
+	p := NewGoogleDriveProvider("id", "secret", server.Client())
+	p.apiURL = server.URL
+
+	t.Run("List", func(t *testing.T) {
+		list, err := p.ListFiles(context.Background(), "access", "it's", "")
+		if err != nil {
+			t.Fatalf("ListFiles returned error: %v", err)
+		}
+		if len(list.Files) != 2 || list.NextPageToken != "next" {
+			t.Fatalf("Expected 2 files and a next page, got %+v", list)
+		}
+		if !list.Files[0].IsFolder || list.Files[1].IsFolder || list.Files[1].Size != 1234 {
+			t.Errorf("Expected a folder and a 1234 byte file, got %+v, %+v", list.Files[0], list.Files[1])
+		}
+	})
+
+	t.Run("Export native document", func(t *testing.T) {
+		download, err := p.Download(context.Background(), "access", "doc1")
+		if err != nil {
+			t.Fatalf("Download returned error: %v", err)
+		}
+		defer download.Body.Close()
+
+		content, _ := io.ReadAll(download.Body)
+		if download.Name != "Notes.pdf" || download.MimeType != constants.ContentTypePDF || string(content) != "%PDF" {
+			t.Errorf("Expected a PDF export, got %+v with %q", download, content)
+		}
+	})
+
+	t.Run("Missing file", func(t *testing.T) {
+		if _, err := p.Download(context.Background(), "access", "missing"); !utils.IsNotFoundError(err) {
+			t.Errorf("Expected a not found error, got %v", err)
+		}
+	})
+
+	t.Run("Rejected token", func(t *testing.T) {
+		if _, err := p.ListFiles(context.Background(), "expired", "", ""); !errors.Is(err, errDriveTokenRejected) {
+			t.Errorf("Expected errDriveTokenRejected, got %v", err)
+		}
+	})
+}
+
+func TestGraphDriveProvider_Files(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/sites":
+			_, _ = w.Write([]byte(`{"value":[{"id":"contoso.sharepoint.com,1,2","displayName":"Legal"}]}`))
+		case "/sites/contoso.sharepoint.com,1,2/drive/root/children":
+			_, _ = w.Write([]byte(`{"value":[
+				{"id":"item1","name":"Cases","folder":{},"parentReference":{"driveId":"drive1"}},
+				{"id":"item2","name":"brief.docx","size":42,"file":{"mimeType":"application/msword"},"parentReference":{"driveId":"drive1"}}
+			]}`))
+		case "/drives/drive1/items/item1":
+			_, _ = w.Write([]byte(`{"id":"item1","name":"Cases","folder":{}}`))
+		case "/drives/drive1/items/item2":
+			_, _ = w.Write([]byte(`{"id":"item2","name":"brief.docx","size":42,"file":{"mimeType":"application/msword"}}`))
+		case "/drives/drive1/items/item2/content":
+			_, _ = w.Write([]byte("content"))
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer server.Close()
+
+	p := NewGraphDriveProvider("id", "secret", "common", true, server.Client())
+	p.apiURL = server.URL
+	ctx := context.Background()
+
+	t.Run("Sites at the top level", func(t *testing.T) {
+		list, err := p.ListFiles(ctx, "access", "", "")
+		if err != nil {
+			t.Fatalf("ListFiles returned error: %v", err)
+		}
+		if len(list.Files) != 1 || list.Files[0].ID != "site:contoso.sharepoint.com,1,2" || !list.Files[0].IsFolder {
+			t.Errorf("Expected the site as a folder, got %+v", list.Files)
+		}
+	})
+
+	t.Run("Site library", func(t *testing.T) {
+		list, err := p.ListFiles(ctx, "access", "site:contoso.sharepoint.com,1,2", "")
+		if err != nil {
+			t.Fatalf("ListFiles returned error: %v", err)
+		}
+		if len(list.Files) != 2 || list.Files[0].ID != "drive1:item1" || list.Files[1].MimeType != "application/msword" {
+			t.Errorf("Expected items identified by drive and item, got %+v, %+v", list.Files[0], list.Files[1])
+		}
+	})
+
+	t.Run("Foreign page token", func(t *testing.T) {
+		_, err := p.ListFiles(ctx, "access", "", "https://attacker.test/steal")
+		if !utils.IsValidationError(err) {
+			t.Errorf("Expected a validation error, got %v", err)
+		}
+	})
+
+	t.Run("Download", func(t *testing.T) {
+		download, err := p.Download(ctx, "access", "drive1:item2")
+		if err != nil {
+			t.Fatalf("Download returned error: %v", err)
+		}
+		defer download.Body.Close()
+
+		content, _ := io.ReadAll(download.Body)
+		if download.Name != "brief.docx" || download.Size != 42 || string(content) != "content" {
+			t.Errorf("Expected the file content, got %+v with %q", download, content)
+		}
+	})
+
+	t.Run("Folder", func(t *testing.T) {
+		if _, err := p.Download(ctx, "access", "drive1:item1"); !errors.Is(err, errDriveFolder) {
+			t.Errorf("Expected errDriveFolder, got %v", err)
+		}
+	})
+
+	t.Run("Malformed ID", func(t *testing.T) {
+		if _, err := p.Download(ctx, "access", "item2"); !utils.IsNotFoundError(err) {
+			t.Errorf("Expected a not found error, got %v", err)
+		}
+	})
+}
+
+func TestNewDriveProviders(t *testing.T) {
+	providers := NewDriveProviders(&config.DriveSettings{
+		MicrosoftClientID:     "client-id",
+		MicrosoftClientSecret: "client-secret",
+		MicrosoftTenant:       "contoso",
+	}, nil)
+
+	if _, ok := providers[constants.DriveProviderGoogle]; ok {
+		t.Error("Expected Google Drive to be left out without a client")
+	}
+	for _, name := range []string{constants.DriveProviderOneDrive, constants.DriveProviderSharePoint} {
+		p, ok := providers[name].(*GraphDriveProvider)
+		if !ok {
+			t.Fatalf("Expected %s to be served by Microsoft Graph", name)
+		}
+		if !strings.Contains(p.oauth.authURL, "/contoso/") {
+			t.Errorf("Expected the configured tenant in %s", p.oauth.authURL)
+		}
+	}
+}
+
+func TestGraphDriveProvider_Upload(t *testing.T) {
+	uploads := make(map[string]string)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPut || r.Header.Get(constants.HeaderContentType) != constants.ContentTypeJSON {
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+		if r.Header.Get(constants.HeaderAuthorization) != "Bearer access" {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		body, _ := io.ReadAll(r.Body)
+		uploads[r.URL.Path] = string(body)
+		w.WriteHeader(http.StatusCreated)
+	}))
+	defer server.Close()
+
+	p := NewGraphDriveProvider("id", "secret", "common", true, server.Client())
+	p.apiURL = server.URL
+	ctx := context.Background()
+
+	t.Run("Folder", func(t *testing.T) {
+		if err := p.Upload(ctx, "access", "drive1:item1", "export.json", constants.ContentTypeJSON, []byte("{}")); err != nil {
+			t.Fatalf("Upload returned error: %v", err)
+		}
+		if uploads["/drives/drive1/items/item1:/export.json:/content"] != "{}" {
+			t.Errorf("Expected the file in the folder, got %v", uploads)
+		}
+	})
+
+	t.Run("Site library", func(t *testing.T) {
+		if err := p.Upload(ctx, "access", "site:contoso.sharepoint.com,1,2", "export.json", constants.ContentTypeJSON, []byte("{}")); err != nil {
+			t.Fatalf("Upload returned error: %v", err)
+		}
+		if _, ok := uploads["/sites/contoso.sharepoint.com,1,2/drive/root:/export.json:/content"]; !ok {
+			t.Errorf("Expected the file in the site's library, got %v", uploads)
+		}
+	})
+
+	t.Run("Rejected token", func(t *testing.T) {
+		err := p.Upload(ctx, "expired", "drive1:item1", "export.json", constants.ContentTypeJSON, []byte("{}"))
+		if !errors.Is(err, errDriveTokenRejected) {
+			t.Errorf("Expected errDriveTokenRejected, got %v", err)
+		}
+	})
+}
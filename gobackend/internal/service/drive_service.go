@@ -0,0 +1,476 @@
+// Package service provides business logic implementations.
+package service
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/rs/zerolog/log"
+
+	"github.com/yasinhessnawi1/Hideme_Backend/internal/config"
+	"github.com/yasinhessnawi1/Hideme_Backend/internal/constants"
+	"github.com/yasinhessnawi1/Hideme_Backend/internal/models"
+	"github.com/yasinhessnawi1/Hideme_Backend/internal/repository"
+	"github.com/yasinhessnawi1/Hideme_Backend/internal/utils"
+	"github.com/yasinhessnawi1/Hideme_Backend/internal/utils/clock"
+)
+
+// driveProviderNames lists the supported cloud drives in the order they are shown.
+var driveProviderNames = []string{
+	constants.DriveProviderGoogle,
+	constants.DriveProviderOneDrive,
+	constants.DriveProviderSharePoint,
+}
+
+// DriveService connects users' cloud drives and imports files from them.
+//
+// A drive is connected with the OAuth authorization code flow: Authorize returns the
+// provider's consent page, the provider sends the user back to the frontend's redirect
+// URL, and the frontend passes the code and state to Connect under the user's own
+// session. The state is signed and names the user it was issued to, so a consent
+// page can't be used to attach one user's drive to another user's account.
+//
+// Files are streamed through to the client, which processes them and uploads the
+// redaction results like any other document; their content is never stored.
+type DriveService struct {
+	connectionRepo repository.DriveConnectionRepository
+	providers      map[string]DriveProvider
+	settings       *config.DriveSettings
+	stateKey       []byte
+	clock          clock.Clock
+}
+
+// NewDriveService creates a new DriveService.
+//
+// Parameters:
+//   - connectionRepo: Repository for users' drive authorizations
+//   - providers: The configured providers by name
+//   - settings: The cloud drive configuration
+//   - stateKey: The secret the authorization state is signed with
+//
+// Returns:
+//   - A configured DriveService
+func NewDriveService(
+	connectionRepo repository.DriveConnectionRepository,
+	providers map[string]DriveProvider,
+	settings *config.DriveSettings,
+	stateKey string,
+) *DriveService {
+	return &DriveService{
+		connectionRepo: connectionRepo,
+		providers:      providers,
+		settings:       settings,
+		stateKey:       []byte(stateKey),
+	}
+}
+
+// SetClock sets the clock sign-in states expire and tokens are refreshed
+// by, so tests can check a state at the instant it expires.
+func (s *DriveService) SetClock(c clock.Clock) {
+	s.clock = c
+}
+
+// now returns the current time of the service's clock.
+func (s *DriveService) now() time.Time {
+	return clock.Or(s.clock).Now()
+}
+
+// ListProviders tells a user which cloud drives are available and connected.
+//
+// Parameters:
+//   - ctx: Context for the operation
+//   - userID: The ID of the user
+//
+// Returns:
+//   - The status of every supported drive
+//   - An error if the connections can't be retrieved
+func (s *DriveService) ListProviders(ctx context.Context, userID int64) ([]*models.DriveProviderStatus, error) {
+	connections, err := s.connectionRepo.ListByUser(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	connectedAt := make(map[string]time.Time, len(connections))
+	for _, conn := range connections {
+		connectedAt[conn.Provider] = conn.CreatedAt
+	}
+
+	statuses := make([]*models.DriveProviderStatus, 0, len(driveProviderNames))
+	for _, name := range driveProviderNames {
+		status := &models.DriveProviderStatus{
+			Provider:   name,
+			Configured: s.configured(name),
+		}
+		if created, ok := connectedAt[name]; ok {
+			status.Connected = true
+			status.ConnectedAt = &created
+		}
+		statuses = append(statuses, status)
+	}
+
+	return statuses, nil
+}
+
+// Authorize starts the connection of a cloud drive.
+//
+// Parameters:
+//   - ctx: Context for the operation
+//   - userID: The ID of the user connecting the drive
+//   - provider: The cloud drive
+//
+// Returns:
+//   - The consent page to send the user to
+//   - ValidationError if the provider is unknown, BadRequestError if it isn't configured
+func (s *DriveService) Authorize(ctx context.Context, userID int64, provider string) (*models.DriveAuthorization, error) {
+	p, err := s.provider(provider)
+	if err != nil {
+		return nil, err
+	}
+
+	state, err := s.signState(userID, provider)
+	if err != nil {
+		return nil, err
+	}
+
+	return &models.DriveAuthorization{
+		AuthorizationURL: p.AuthCodeURL(state, s.settings.RedirectURL),
+	}, nil
+}
+
+// Connect completes the connection of a cloud drive with the code the provider issued.
+//
+// Parameters:
+//   - ctx: Context for the operation
+//   - userID: The ID of the authenticated user
+//   - req: The code and state passed back by the provider
+//
+// Returns:
+//   - The status of the connected drive
+//   - BadRequestError if the state is forged, expired or issued to another user, or the code is rejected
+func (s *DriveService) Connect(ctx context.Context, userID int64, req *models.DriveConnectRequest) (*models.DriveProviderStatus, error) {
+	stateUserID, provider, err := s.verifyState(req.State)
+	if err != nil || stateUserID != userID {
+		return nil, utils.NewBadRequestError(constants.MsgDriveStateInvalid)
+	}
+
+	p, err := s.provider(provider)
+	if err != nil {
+		return nil, err
+	}
+
+	token, err := p.Exchange(ctx, req.Code, s.settings.RedirectURL)
+	if err != nil {
+		if errors.Is(err, errDriveTokenRejected) {
+			return nil, utils.NewBadRequestError(constants.MsgDriveStateInvalid)
+		}
+		return nil, fmt.Errorf("failed to exchange authorization code: %w", err)
+	}
+
+	conn := &models.DriveConnection{
+		UserID:       userID,
+		Provider:     provider,
+		AccessToken:  token.AccessToken,
+		RefreshToken: token.RefreshToken,
+		ExpiresAt:    token.ExpiresAt,
+	}
+	if err := s.connectionRepo.Save(ctx, conn); err != nil {
+		return nil, err
+	}
+
+	log.Info().
+		Int64("user_id", userID).
+		Str("provider", provider).
+		Msg("Cloud drive connected")
+
+	return &models.DriveProviderStatus{
+		Provider:    provider,
+		Configured:  true,
+		Connected:   true,
+		ConnectedAt: &conn.CreatedAt,
+	}, nil
+}
+
+// Disconnect removes a user's authorization of a cloud drive. The authorization
+// is not revoked at the provider; users can do that in their account settings there.
+//
+// Parameters:
+//   - ctx: Context for the operation
+//   - userID: The ID of the user
+//   - provider: The cloud drive
+//
+// Returns:
+//   - ValidationError if the provider is unknown
+//   - NotFoundError if the drive is not connected
+func (s *DriveService) Disconnect(ctx context.Context, userID int64, provider string) error {
+	if !isDriveProvider(provider) {
+		return utils.NewValidationError(constants.ParamProvider, constants.MsgDriveProviderUnknown)
+	}
+
+	if err := s.connectionRepo.Delete(ctx, userID, provider); err != nil {
+		return err
+	}
+
+	log.Info().
+		Int64("user_id", userID).
+		Str("provider", provider).
+		Msg("Cloud drive disconnected")
+
+	return nil
+}
+
+// ListFiles lists a page of a folder in a connected cloud drive.
+//
+// Parameters:
+//   - ctx: Context for the operation
+//   - userID: The ID of the user
+//   - provider: The cloud drive
+//   - folderID: The folder to list; empty for the top level
+//   - pageToken: The next page token of the previous page; empty for the first page
+//
+// Returns:
+//   - The page of files and folders
+//   - BadRequestError if the drive is not connected or its authorization was revoked
+func (s *DriveService) ListFiles(ctx context.Context, userID int64, provider, folderID, pageToken string) (*models.DriveFileList, error) {
+	p, err := s.provider(provider)
+	if err != nil {
+		return nil, err
+	}
+
+	var list *models.DriveFileList
+	err = s.withToken(ctx, userID, provider, p, func(accessToken string) error {
+		var err error
+		list, err = p.ListFiles(ctx, accessToken, folderID, pageToken)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return list, nil
+}
+
+// OpenFile opens a file in a connected cloud drive for import. Reading the body
+// fails once it exceeds the import size limit. The caller must close the body.
+//
+// Parameters:
+//   - ctx: Context for the operation; it also bounds reading the body
+//   - userID: The ID of the user
+//   - provider: The cloud drive
+//   - fileID: The file to import
+//
+// Returns:
+//   - The content of the file
+//   - BadRequestError if the file is a folder or too large, or the drive is not connected
+func (s *DriveService) OpenFile(ctx context.Context, userID int64, provider, fileID string) (*models.DriveDownload, error) {
+	p, err := s.provider(provider)
+	if err != nil {
+		return nil, err
+	}
+
+	var download *models.DriveDownload
+	err = s.withToken(ctx, userID, provider, p, func(accessToken string) error {
+		var err error
+		download, err = p.Download(ctx, accessToken, fileID)
+		return err
+	})
+	if err != nil {
+		if errors.Is(err, errDriveFolder) {
+			return nil, utils.NewBadRequestError(constants.MsgDriveFolderNotFile)
+		}
+		return nil, err
+	}
+
+	if download.Size > s.settings.MaxImportSize {
+		download.Body.Close()
+		return nil, utils.NewBadRequestError(constants.MsgDriveFileTooLarge)
+	}
+	download.Body = &limitedBody{ReadCloser: download.Body, remaining: s.settings.MaxImportSize}
+
+	log.Info().
+		Int64("user_id", userID).
+		Str("provider", provider).
+		Int64("size", download.Size).
+		Msg("Importing file from cloud drive")
+
+	return download, nil
+}
+
+// UploadFile writes a file into a folder of a connected cloud drive, replacing a file
+// with the same name. Only drives that accept writes can be uploaded to.
+//
+// Parameters:
+//   - ctx: Context for the operation
+//   - userID: The ID of the user
+//   - provider: The cloud drive
+//   - folderID: The folder to write to
+//   - name: The name of the file
+//   - contentType: The media type of the file
+//   - content: The content of the file
+//
+// Returns:
+//   - BadRequestError if the drive doesn't accept writes, is not connected or its authorization was revoked
+//   - Other errors if the folder doesn't exist or the write is refused
+func (s *DriveService) UploadFile(ctx context.Context, userID int64, provider, folderID, name, contentType string, content []byte) error {
+	p, err := s.provider(provider)
+	if err != nil {
+		return err
+	}
+
+	uploader, ok := p.(driveUploader)
+	if !ok {
+		return utils.NewBadRequestError(constants.MsgDriveUploadUnsupported)
+	}
+
+	err = s.withToken(ctx, userID, provider, p, func(accessToken string) error {
+		return uploader.Upload(ctx, accessToken, folderID, name, contentType, content)
+	})
+	if err != nil {
+		return err
+	}
+
+	log.Info().
+		Int64("user_id", userID).
+		Str("provider", provider).
+		Int("size", len(content)).
+		Msg("Uploaded file to cloud drive")
+
+	return nil
+}
+
+// withToken calls fn with a valid access token of a user's drive, refreshing it first
+// if it is about to expire. Connections the provider no longer accepts are removed.
+func (s *DriveService) withToken(ctx context.Context, userID int64, provider string, p DriveProvider, fn func(accessToken string) error) error {
+	conn, err := s.connectionRepo.Get(ctx, userID, provider)
+	if err != nil {
+		if utils.IsNotFoundError(err) {
+			return utils.NewBadRequestError(constants.MsgDriveNotConnected)
+		}
+		return err
+	}
+
+	if conn.NeedsRefresh(s.now(), constants.DriveTokenRefreshMargin) {
+		token, err := p.Refresh(ctx, conn.RefreshToken)
+		if err != nil {
+			return s.rejected(ctx, conn, err)
+		}
+
+		conn.AccessToken = token.AccessToken
+		conn.RefreshToken = token.RefreshToken
+		conn.ExpiresAt = token.ExpiresAt
+		if err := s.connectionRepo.Save(ctx, conn); err != nil {
+			return err
+		}
+	}
+
+	if err := fn(conn.AccessToken); err != nil {
+		return s.rejected(ctx, conn, err)
+	}
+	return nil
+}
+
+// rejected removes a connection whose token the provider rejected and tells the user
+// to connect the drive again; other errors are returned unchanged.
+func (s *DriveService) rejected(ctx context.Context, conn *models.DriveConnection, err error) error {
+	if !errors.Is(err, errDriveTokenRejected) {
+		return err
+	}
+
+	if err := s.connectionRepo.Delete(ctx, conn.UserID, conn.Provider); err != nil && !utils.IsNotFoundError(err) {
+		log.Warn().Err(err).
+			Int64("user_id", conn.UserID).
+			Str("provider", conn.Provider).
+			Msg("Failed to remove rejected cloud drive connection")
+	}
+
+	return utils.NewBadRequestError(constants.MsgDriveNotConnected)
+}
+
+// provider returns a configured provider.
+func (s *DriveService) provider(name string) (DriveProvider, error) {
+	if !isDriveProvider(name) {
+		return nil, utils.NewValidationError(constants.ParamProvider, constants.MsgDriveProviderUnknown)
+	}
+	if !s.configured(name) {
+		return nil, utils.NewBadRequestError(constants.MsgDriveProviderNotConfigured)
+	}
+	return s.providers[name], nil
+}
+
+// configured reports whether users can connect a provider, which needs both
+// its OAuth client and the redirect URL.
+func (s *DriveService) configured(name string) bool {
+	_, ok := s.providers[name]
+	return ok && s.settings.RedirectURL != ""
+}
+
+// isDriveProvider reports whether a provider name is supported.
+func isDriveProvider(name string) bool {
+	for _, supported := range driveProviderNames {
+		if name == supported {
+			return true
+		}
+	}
+	return false
+}
+
+// signState creates the state of an authorization: the user, the provider, the expiry
+// and a nonce, followed by their HMAC-SHA256.
+func (s *DriveService) signState(userID int64, provider string) (string, error) {
+	nonce := make([]byte, 16)
+	if _, err := rand.Read(nonce); err != nil {
+		return "", fmt.Errorf("failed to generate state nonce: %w", err)
+	}
+
+	payload := fmt.Sprintf("%d|%s|%d|%s",
+		userID, provider, s.now().Add(constants.DriveStateTTL).Unix(), base64.RawURLEncoding.EncodeToString(nonce))
+	encoded := base64.RawURLEncoding.EncodeToString([]byte(payload))
+
+	return encoded + "." + s.stateSignature(encoded), nil
+}
+
+// verifyState checks the signature and expiry of a state.
+func (s *DriveService) verifyState(state string) (int64, string, error) {
+	encoded, signature, ok := strings.Cut(state, ".")
+	if !ok || !hmac.Equal([]byte(signature), []byte(s.stateSignature(encoded))) {
+		return 0, "", errors.New("invalid state signature")
+	}
+
+	payload, err := base64.RawURLEncoding.DecodeString(encoded)
+	if err != nil {
+		return 0, "", fmt.Errorf("invalid state encoding: %w", err)
+	}
+
+	parts := strings.Split(string(payload), "|")
+	if len(parts) != 4 {
+		return 0, "", errors.New("invalid state payload")
+	}
+
+	userID, err := strconv.ParseInt(parts[0], 10, 64)
+	if err != nil {
+		return 0, "", fmt.Errorf("invalid state user: %w", err)
+	}
+	expiresAt, err := strconv.ParseInt(parts[2], 10, 64)
+	if err != nil {
+		return 0, "", fmt.Errorf("invalid state expiry: %w", err)
+	}
+	if s.now().Unix() > expiresAt {
+		return 0, "", errors.New("state expired")
+	}
+
+	return userID, parts[1], nil
+}
+
+// stateSignature returns the HMAC-SHA256 of an encoded state payload.
+func (s *DriveService) stateSignature(encoded string) string {
+	mac := hmac.New(sha256.New, s.stateKey)
+	mac.Write([]byte(encoded))
+	return base64.RawURLEncoding.EncodeToString(mac.Sum(nil))
+}
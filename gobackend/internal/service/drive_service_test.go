@@ -0,0 +1,339 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/yasinhessnawi1/Hideme_Backend/internal/config"
+	"github.com/yasinhessnawi1/Hideme_Backend/internal/constants"
+	"github.com/yasinhessnawi1/Hideme_Backend/internal/models"
+	"github.com/yasinhessnawi1/Hideme_Backend/internal/testsupport/fakeclock"
+	"github.com/yasinhessnawi1/Hideme_Backend/internal/utils"
+)
+
+// MockDriveConnectionRepository is an in-memory implementation of repository.DriveConnectionRepository
+type MockDriveConnectionRepository struct {
+	connections map[string]*models.DriveConnection
+}
+
+func newMockDriveConnectionRepository() *MockDriveConnectionRepository {
+	return &MockDriveConnectionRepository{connections: make(map[string]*models.DriveConnection)}
+}
+
+func (m *MockDriveConnectionRepository) key(userID int64, provider string) string {
+	return fmt.Sprintf("%d/%s", userID, provider)
+}
+
+func (m *MockDriveConnectionRepository) Get(ctx context.Context, userID int64, provider string) (*models.DriveConnection, error) {
+	conn, ok := m.connections[m.key(userID, provider)]
+	if !ok {
+		return nil, utils.NewNotFoundError("Drive connection", provider)
+	}
+	copied := *conn
+	return &copied, nil
+}
+
+func (m *MockDriveConnectionRepository) ListByUser(ctx context.Context, userID int64) ([]*models.DriveConnection, error) {
+	var connections []*models.DriveConnection
+	for _, conn := range m.connections {
+		if conn.UserID == userID {
+			connections = append(connections, conn)
+		}
+	}
+	return connections, nil
+}
+
+func (m *MockDriveConnectionRepository) Save(ctx context.Context, conn *models.DriveConnection) error {
+	copied := *conn
+	m.connections[m.key(conn.UserID, conn.Provider)] = &copied
+	return nil
+}
+
+func (m *MockDriveConnectionRepository) Delete(ctx context.Context, userID int64, provider string) error {
+	if _, ok := m.connections[m.key(userID, provider)]; !ok {
+		return utils.NewNotFoundError("Drive connection", provider)
+	}
+	delete(m.connections, m.key(userID, provider))
+	return nil
+}
+
+// MockDriveProvider is a DriveProvider serving fixed responses
+type MockDriveProvider struct {
+	listErr       error
+	download      *models.DriveDownload
+	refreshed     int
+	usedToken     string
+	exchangedCode string
+}
+
+func (m *MockDriveProvider) AuthCodeURL(state, redirectURI string) string {
+	return "https://provider.test/auth?state=" + url.QueryEscape(state) + "&redirect_uri=" + url.QueryEscape(redirectURI)
+}
+
+func (m *MockDriveProvider) Exchange(ctx context.Context, code, redirectURI string) (*DriveToken, error) {
+	m.exchangedCode = code
+	return &DriveToken{AccessToken: "access", RefreshToken: "refresh", ExpiresAt: time.Now().Add(time.Hour)}, nil
+}
+
+func (m *MockDriveProvider) Refresh(ctx context.Context, refreshToken string) (*DriveToken, error) {
+	m.refreshed++
+	return &DriveToken{AccessToken: "refreshed", RefreshToken: refreshToken, ExpiresAt: time.Now().Add(time.Hour)}, nil
+}
+
+func (m *MockDriveProvider) ListFiles(ctx context.Context, accessToken, folderID, pageToken string) (*models.DriveFileList, error) {
+	m.usedToken = accessToken
+	if m.listErr != nil {
+		return nil, m.listErr
+	}
+	return &models.DriveFileList{Files: []*models.DriveFile{{ID: "f1", Name: "lease.pdf"}}}, nil
+}
+
+func (m *MockDriveProvider) Download(ctx context.Context, accessToken, fileID string) (*models.DriveDownload, error) {
+	m.usedToken = accessToken
+	if m.download == nil {
+		return nil, errDriveFolder
+	}
+	return m.download, nil
+}
+
+func newTestDriveService() (*DriveService, *MockDriveConnectionRepository, *MockDriveProvider) {
+	repo := newMockDriveConnectionRepository()
+	provider := &MockDriveProvider{}
+	settings := &config.DriveSettings{RedirectURL: "https://app.test/drives/callback", MaxImportSize: 10}
+	svc := NewDriveService(repo, map[string]DriveProvider{constants.DriveProviderGoogle: provider}, settings, "state-secret")
+	return svc, repo, provider
+}
+
+func isBadRequest(err error) bool {
+	return err != nil && utils.StatusCode(err) == http.StatusBadRequest
+}
+
+func stateFromAuthorizationURL(t *testing.T, authorizationURL string) string {
+	parsed, err := url.Parse(authorizationURL)
+	if err != nil {
+		t.Fatalf("Invalid authorization URL: %v", err)
+	}
+	return parsed.Query().Get("state")
+}
+
+func TestDriveService_ListProviders(t *testing.T) {
+	svc, repo, _ := newTestDriveService()
+	repo.connections[repo.key(1, constants.DriveProviderGoogle)] = &models.DriveConnection{
+		UserID: 1, Provider: constants.DriveProviderGoogle, CreatedAt: time.Now(),
+	}
+
+	statuses, err := svc.ListProviders(context.Background(), 1)
+	if err != nil {
+		t.Fatalf("ListProviders returned error: %v", err)
+	}
+	if len(statuses) != 3 {
+		t.Fatalf("Expected 3 providers, got %d", len(statuses))
+	}
+	if !statuses[0].Configured || !statuses[0].Connected || statuses[0].ConnectedAt == nil {
+		t.Errorf("Expected Google Drive to be configured and connected, got %+v", statuses[0])
+	}
+	if statuses[1].Configured || statuses[1].Connected {
+		t.Errorf("Expected OneDrive to be unavailable, got %+v", statuses[1])
+	}
+}
+
+func TestDriveService_AuthorizeAndConnect(t *testing.T) {
+	svc, repo, provider := newTestDriveService()
+	ctx := context.Background()
+
+	authorization, err := svc.Authorize(ctx, 1, constants.DriveProviderGoogle)
+	if err != nil {
+		t.Fatalf("Authorize returned error: %v", err)
+	}
+	if !strings.Contains(authorization.AuthorizationURL, url.QueryEscape("https://app.test/drives/callback")) {
+		t.Errorf("Expected the redirect URL in %s", authorization.AuthorizationURL)
+	}
+	state := stateFromAuthorizationURL(t, authorization.AuthorizationURL)
+
+	t.Run("State of another user", func(t *testing.T) {
+		_, err := svc.Connect(ctx, 2, &models.DriveConnectRequest{Code: "code", State: state})
+		if !isBadRequest(err) {
+			t.Errorf("Expected a bad request error, got %v", err)
+		}
+	})
+
+	t.Run("Tampered state", func(t *testing.T) {
+		_, err := svc.Connect(ctx, 1, &models.DriveConnectRequest{Code: "code", State: "x" + state})
+		if !isBadRequest(err) {
+			t.Errorf("Expected a bad request error, got %v", err)
+		}
+	})
+
+	t.Run("Expired state", func(t *testing.T) {
+		svc.SetClock(fakeclock.New(time.Now().Add(constants.DriveStateTTL + time.Minute)))
+		defer svc.SetClock(nil)
+
+		_, err := svc.Connect(ctx, 1, &models.DriveConnectRequest{Code: "code", State: state})
+		if !isBadRequest(err) {
+			t.Errorf("Expected a bad request error, got %v", err)
+		}
+	})
+
+	t.Run("Success", func(t *testing.T) {
+		status, err := svc.Connect(ctx, 1, &models.DriveConnectRequest{Code: "code", State: state})
+		if err != nil {
+			t.Fatalf("Connect returned error: %v", err)
+		}
+		if !status.Connected || status.Provider != constants.DriveProviderGoogle {
+			t.Errorf("Expected Google Drive to be connected, got %+v", status)
+		}
+		if provider.exchangedCode != "code" {
+			t.Errorf("Expected the code to be exchanged, got %q", provider.exchangedCode)
+		}
+		if conn := repo.connections[repo.key(1, constants.DriveProviderGoogle)]; conn == nil || conn.AccessToken != "access" {
+			t.Errorf("Expected the tokens to be stored, got %+v", conn)
+		}
+	})
+}
+
+func TestDriveService_Authorize_NotConfigured(t *testing.T) {
+	svc, _, _ := newTestDriveService()
+
+	if _, err := svc.Authorize(context.Background(), 1, constants.DriveProviderOneDrive); !isBadRequest(err) {
+		t.Errorf("Expected a bad request error for an unconfigured provider, got %v", err)
+	}
+	if _, err := svc.Authorize(context.Background(), 1, "dropbox"); !utils.IsValidationError(err) {
+		t.Errorf("Expected a validation error for an unknown provider, got %v", err)
+	}
+}
+
+func TestDriveService_ListFiles(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("Not connected", func(t *testing.T) {
+		svc, _, _ := newTestDriveService()
+
+		_, err := svc.ListFiles(ctx, 1, constants.DriveProviderGoogle, "", "")
+		if !isBadRequest(err) {
+			t.Errorf("Expected a bad request error, got %v", err)
+		}
+	})
+
+	t.Run("Refreshes expiring token", func(t *testing.T) {
+		svc, repo, provider := newTestDriveService()
+		repo.connections[repo.key(1, constants.DriveProviderGoogle)] = &models.DriveConnection{
+			UserID: 1, Provider: constants.DriveProviderGoogle,
+			AccessToken: "old", RefreshToken: "refresh", ExpiresAt: time.Now().Add(10 * time.Second),
+		}
+
+		list, err := svc.ListFiles(ctx, 1, constants.DriveProviderGoogle, "", "")
+		if err != nil {
+			t.Fatalf("ListFiles returned error: %v", err)
+		}
+		if len(list.Files) != 1 {
+			t.Errorf("Expected 1 file, got %d", len(list.Files))
+		}
+		if provider.refreshed != 1 || provider.usedToken != "refreshed" {
+			t.Errorf("Expected the token to be refreshed before use, got %d refreshes and token %q", provider.refreshed, provider.usedToken)
+		}
+		if repo.connections[repo.key(1, constants.DriveProviderGoogle)].AccessToken != "refreshed" {
+			t.Error("Expected the refreshed token to be stored")
+		}
+	})
+
+	t.Run("Revoked authorization removes connection", func(t *testing.T) {
+		svc, repo, provider := newTestDriveService()
+		provider.listErr = errDriveTokenRejected
+		repo.connections[repo.key(1, constants.DriveProviderGoogle)] = &models.DriveConnection{
+			UserID: 1, Provider: constants.DriveProviderGoogle, AccessToken: "access", ExpiresAt: time.Now().Add(time.Hour),
+		}
+
+		_, err := svc.ListFiles(ctx, 1, constants.DriveProviderGoogle, "", "")
+		if !isBadRequest(err) {
+			t.Errorf("Expected a bad request error, got %v", err)
+		}
+		if len(repo.connections) != 0 {
+			t.Error("Expected the rejected connection to be removed")
+		}
+	})
+
+	t.Run("Other errors are returned", func(t *testing.T) {
+		svc, repo, provider := newTestDriveService()
+		provider.listErr = errors.New("connection reset")
+		repo.connections[repo.key(1, constants.DriveProviderGoogle)] = &models.DriveConnection{
+			UserID: 1, Provider: constants.DriveProviderGoogle, AccessToken: "access", ExpiresAt: time.Now().Add(time.Hour),
+		}
+
+		if _, err := svc.ListFiles(ctx, 1, constants.DriveProviderGoogle, "", ""); err == nil || isBadRequest(err) {
+			t.Errorf("Expected the provider error, got %v", err)
+		}
+		if len(repo.connections) != 1 {
+			t.Error("Expected the connection to be kept")
+		}
+	})
+}
+
+func TestDriveService_OpenFile(t *testing.T) {
+	ctx := context.Background()
+	connect := func(repo *MockDriveConnectionRepository) {
+		repo.connections[repo.key(1, constants.DriveProviderGoogle)] = &models.DriveConnection{
+			UserID: 1, Provider: constants.DriveProviderGoogle, AccessToken: "access", ExpiresAt: time.Now().Add(time.Hour),
+		}
+	}
+
+	t.Run("Folder", func(t *testing.T) {
+		svc, repo, _ := newTestDriveService()
+		connect(repo)
+
+		_, err := svc.OpenFile(ctx, 1, constants.DriveProviderGoogle, "folder")
+		if !isBadRequest(err) {
+			t.Errorf("Expected a bad request error, got %v", err)
+		}
+	})
+
+	t.Run("Known size over the limit", func(t *testing.T) {
+		svc, repo, provider := newTestDriveService()
+		connect(repo)
+		provider.download = &models.DriveDownload{Name: "big.pdf", Size: 11, Body: io.NopCloser(strings.NewReader("01234567890"))}
+
+		_, err := svc.OpenFile(ctx, 1, constants.DriveProviderGoogle, "big")
+		if !isBadRequest(err) {
+			t.Errorf("Expected a bad request error, got %v", err)
+		}
+	})
+
+	t.Run("Unknown size over the limit", func(t *testing.T) {
+		svc, repo, provider := newTestDriveService()
+		connect(repo)
+		provider.download = &models.DriveDownload{Name: "doc.pdf", Body: io.NopCloser(strings.NewReader("01234567890"))}
+
+		download, err := svc.OpenFile(ctx, 1, constants.DriveProviderGoogle, "doc")
+		if err != nil {
+			t.Fatalf("OpenFile returned error: %v", err)
+		}
+		content, err := io.ReadAll(download.Body)
+		if !isBadRequest(err) {
+			t.Errorf("Expected reading past the limit to fail, got %v", err)
+		}
+		if len(content) != 10 {
+			t.Errorf("Expected the content up to the limit, got %d bytes", len(content))
+		}
+	})
+
+	t.Run("Success", func(t *testing.T) {
+		svc, repo, provider := newTestDriveService()
+		connect(repo)
+		provider.download = &models.DriveDownload{Name: "doc.pdf", Size: 5, Body: io.NopCloser(strings.NewReader("hello"))}
+
+		download, err := svc.OpenFile(ctx, 1, constants.DriveProviderGoogle, "doc")
+		if err != nil {
+			t.Fatalf("OpenFile returned error: %v", err)
+		}
+		content, err := io.ReadAll(download.Body)
+		if err != nil || string(content) != "hello" {
+			t.Errorf("Expected the file content, got %q, %v", content, err)
+		}
+	})
+}
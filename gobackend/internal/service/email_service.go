@@ -2,48 +2,216 @@ package service
 
 import (
 	"fmt"
+	"html"
 	"os"
+	"strconv"
+	"strings"
+	"time"
 
 	"github.com/rs/zerolog/log"
-	"github.com/sendgrid/sendgrid-go"
-	"github.com/sendgrid/sendgrid-go/helpers/mail"
+
+	"github.com/yasinhessnawi1/Hideme_Backend/internal/constants"
+	"github.com/yasinhessnawi1/Hideme_Backend/internal/models"
+	"github.com/yasinhessnawi1/Hideme_Backend/internal/utils"
 )
 
 const (
-	fromEmailAddress = "support@hidemeai.com"
-	fromEmailName    = "HideMe Support"
-	frontendResetURL = "https://hidemeai.com/reset-password?token=%s" // Replace with your actual frontend URL
+	fromEmailAddress    = "support@hidemeai.com"
+	fromEmailName       = "HideMe Support"
+	frontendResetURL    = "https://hidemeai.com/reset-password?token=%s" // Replace with your actual frontend URL
+	frontendVerifyURL   = "https://hidemeai.com/verify-email?token=%s"
+	frontendDocumentURL = "https://hidemeai.com/documents/%d"
 )
 
-// EmailService handles sending emails.
+// EmailService composes the application's emails and hands them to an email sender.
 type EmailService struct {
-	sendgridAPIKey string
+	sender utils.EmailSender
 }
 
-// NewEmailService creates a new EmailService.
-// It expects the SendGrid API key to be set in the SENDGRID_API_KEY environment variable.
+// NewEmailService creates a new EmailService with the provider chosen by the
+// EMAIL_PROVIDER environment variable:
+//   - "sendgrid" (the default) expects the API key in SENDGRID_API_KEY
+//   - "smtp" expects the server in SMTP_HOST and SMTP_PORT (default 587), and
+//     optionally credentials in SMTP_USERNAME and SMTP_PASSWORD; Amazon SES is
+//     used through its SMTP interface
 func NewEmailService() (*EmailService, error) {
-	apiKey := os.Getenv("SENDGRID_API_KEY")
-	if apiKey == "" {
-		return nil, fmt.Errorf("SENDGRID_API_KEY environment variable not set")
+	switch provider := os.Getenv("EMAIL_PROVIDER"); provider {
+	case "", "sendgrid":
+		apiKey := os.Getenv("SENDGRID_API_KEY")
+		if apiKey == "" {
+			return nil, fmt.Errorf("SENDGRID_API_KEY environment variable not set")
+		}
+		return NewEmailServiceWithSender(utils.NewSendGridSender(apiKey, fromEmailName, fromEmailAddress)), nil
+	case "smtp":
+		host := os.Getenv("SMTP_HOST")
+		if host == "" {
+			return nil, fmt.Errorf("SMTP_HOST environment variable not set")
+		}
+		port := 587
+		if value := os.Getenv("SMTP_PORT"); value != "" {
+			parsed, err := strconv.Atoi(value)
+			if err != nil {
+				return nil, fmt.Errorf("invalid SMTP_PORT: %w", err)
+			}
+			port = parsed
+		}
+		sender := utils.NewSMTPSender(host, port, os.Getenv("SMTP_USERNAME"), os.Getenv("SMTP_PASSWORD"), fromEmailName, fromEmailAddress)
+		return NewEmailServiceWithSender(sender), nil
+	default:
+		return nil, fmt.Errorf("unknown EMAIL_PROVIDER %q", provider)
 	}
-	return &EmailService{sendgridAPIKey: apiKey}, nil
+}
+
+// NewEmailServiceWithSender creates a new EmailService delivering through the given sender.
+func NewEmailServiceWithSender(sender utils.EmailSender) *EmailService {
+	return &EmailService{sender: sender}
 }
 
 // SendPasswordResetEmail sends a password reset email to the specified user.
 func (s *EmailService) SendPasswordResetEmail(toEmail, toName, token string) error {
-	from := mail.NewEmail(fromEmailName, fromEmailAddress)
-	to := mail.NewEmail(toName, toEmail)
 	subject := "Password Reset Request"
 	plainTextContent := fmt.Sprintf("Please use the following link to reset your password: %s", fmt.Sprintf(frontendResetURL, token))
 	htmlContent := fmt.Sprintf("<strong>Please use the following link to reset your password:</strong> <a href=\"%s\">Reset Password</a>", fmt.Sprintf(frontendResetURL, token))
-	message := mail.NewSingleEmail(from, subject, to, plainTextContent, htmlContent)
-	client := sendgrid.NewSendClient(s.sendgridAPIKey)
-	response, err := client.Send(message)
-	if err != nil {
+	message := &utils.EmailMessage{ToEmail: toEmail, ToName: toName, Subject: subject, PlainText: plainTextContent, HTML: htmlContent}
+	if err := s.sender.Send(message); err != nil {
 		log.Error().Err(err).Msg("Failed to send password reset email")
 		return err
 	}
-	log.Info().Int("status_code", response.StatusCode).Msg("Password reset email sent")
+	log.Info().Msg("Password reset email sent")
+	return nil
+}
+
+// SendVerificationEmail sends an email address verification link to the specified user.
+func (s *EmailService) SendVerificationEmail(toEmail, toName, token string) error {
+	subject := "Verify Your Email Address"
+	plainTextContent := fmt.Sprintf("Please use the following link to verify your email address: %s", fmt.Sprintf(frontendVerifyURL, token))
+	htmlContent := fmt.Sprintf("<strong>Please use the following link to verify your email address:</strong> <a href=\"%s\">Verify Email</a>", fmt.Sprintf(frontendVerifyURL, token))
+	message := &utils.EmailMessage{ToEmail: toEmail, ToName: toName, Subject: subject, PlainText: plainTextContent, HTML: htmlContent}
+	if err := s.sender.Send(message); err != nil {
+		log.Error().Err(err).Msg("Failed to send verification email")
+		return err
+	}
+	log.Info().Msg("Verification email sent")
+	return nil
+}
+
+// SendRotatedKeyUsedEmail warns the specified user that a rotated API key is still in use.
+func (s *EmailService) SendRotatedKeyUsedEmail(toEmail, toName, keyName string, expiresAt time.Time) error {
+	subject := "Rotated API Key Still in Use"
+	plainTextContent := fmt.Sprintf("Your API key %q was rotated but is still being used. It stops working at %s; switch your integrations to the replacement key before then.", keyName, expiresAt.UTC().Format(time.RFC1123))
+	htmlContent := fmt.Sprintf("<strong>Your API key &quot;%s&quot; was rotated but is still being used.</strong> It stops working at %s; switch your integrations to the replacement key before then.", html.EscapeString(keyName), expiresAt.UTC().Format(time.RFC1123))
+	message := &utils.EmailMessage{ToEmail: toEmail, ToName: toName, Subject: subject, PlainText: plainTextContent, HTML: htmlContent}
+	if err := s.sender.Send(message); err != nil {
+		log.Error().Err(err).Msg("Failed to send rotated API key email")
+		return err
+	}
+	log.Info().Msg("Rotated API key email sent")
+	return nil
+}
+
+// SendRotationCampaignEmail tells the specified user that administrators require some of their API keys to be rotated.
+func (s *EmailService) SendRotationCampaignEmail(toEmail, toName, campaignName string, keyNames []string, deadline time.Time) error {
+	subject := "Rotate Your API Keys"
+	quoted := make([]string, len(keyNames))
+	escaped := make([]string, len(keyNames))
+	for i, name := range keyNames {
+		quoted[i] = strconv.Quote(name)
+		escaped[i] = "&quot;" + html.EscapeString(name) + "&quot;"
+	}
+	plainTextContent := fmt.Sprintf("Your administrators require your API keys %s to be rotated (%s). They stop working at %s; rotate them and switch your integrations to the replacement keys before then.", strings.Join(quoted, ", "), campaignName, deadline.UTC().Format(time.RFC1123))
+	htmlContent := fmt.Sprintf("<strong>Your administrators require your API keys %s to be rotated (%s).</strong> They stop working at %s; rotate them and switch your integrations to the replacement keys before then.", strings.Join(escaped, ", "), html.EscapeString(campaignName), deadline.UTC().Format(time.RFC1123))
+	message := &utils.EmailMessage{ToEmail: toEmail, ToName: toName, Subject: subject, PlainText: plainTextContent, HTML: htmlContent}
+	if err := s.sender.Send(message); err != nil {
+		log.Error().Err(err).Msg("Failed to send rotation campaign email")
+		return err
+	}
+	log.Info().Int("keys", len(keyNames)).Msg("Rotation campaign email sent")
+	return nil
+}
+
+// SendQuotaWarningEmail warns the specified user that their detection quota is running low.
+func (s *EmailService) SendQuotaWarningEmail(toEmail, toName string, threshold int, quota *models.QuotaState) error {
+	subject := fmt.Sprintf("You have used %d%% of your detection quota", threshold)
+	refill := time.Duration(quota.RefillSeconds) * time.Second
+	plainTextContent := fmt.Sprintf("You have used %d%% of your detection quota; %d of %d pages are left. An empty quota refills completely in %s, and detection requests are rejected while it is used up.", threshold, quota.Remaining, quota.Capacity, refill)
+	htmlContent := fmt.Sprintf("<strong>You have used %d%% of your detection quota.</strong> %d of %d pages are left. An empty quota refills completely in %s, and detection requests are rejected while it is used up.", threshold, quota.Remaining, quota.Capacity, refill)
+	message := &utils.EmailMessage{ToEmail: toEmail, ToName: toName, Subject: subject, PlainText: plainTextContent, HTML: htmlContent}
+	if err := s.sender.Send(message); err != nil {
+		log.Error().Err(err).Msg("Failed to send quota warning email")
+		return err
+	}
+	log.Info().Int("threshold", threshold).Msg("Quota warning email sent")
+	return nil
+}
+
+// SendLeakAlertEmail alerts the specified user that values were detected in many of their
+// documents, with links to the affected documents. Values are identified by fingerprint only.
+func (s *EmailService) SendLeakAlertEmail(toEmail, toName string, windowDays int, alerts []*models.LeakAlert) error {
+	subject := "The same personal data keeps appearing in your documents"
+	var plainText, htmlContent strings.Builder
+	fmt.Fprintf(&plainText, "The following values were each detected in many of your documents in the last %d days. Check where they come from to stop them leaking into new documents.\n", windowDays)
+	fmt.Fprintf(&htmlContent, "<strong>The following values were each detected in many of your documents in the last %d days.</strong> Check where they come from to stop them leaking into new documents.<ul>", windowDays)
+	for _, alert := range alerts {
+		links := make([]string, len(alert.DocumentIDs))
+		htmlLinks := make([]string, len(alert.DocumentIDs))
+		for i, documentID := range alert.DocumentIDs {
+			links[i] = fmt.Sprintf(frontendDocumentURL, documentID)
+			htmlLinks[i] = fmt.Sprintf(`<a href="%s">%d</a>`, links[i], documentID)
+		}
+		fmt.Fprintf(&plainText, "\n%s %s in %d documents:\n%s\n", alert.EntityType, alert.Fingerprint, alert.DocumentCount, strings.Join(links, "\n"))
+		fmt.Fprintf(&htmlContent, "<li>%s %s in %d documents: %s</li>", html.EscapeString(alert.EntityType), html.EscapeString(alert.Fingerprint), alert.DocumentCount, strings.Join(htmlLinks, ", "))
+	}
+	htmlContent.WriteString("</ul>")
+
+	message := &utils.EmailMessage{ToEmail: toEmail, ToName: toName, Subject: subject, PlainText: plainText.String(), HTML: htmlContent.String()}
+	if err := s.sender.Send(message); err != nil {
+		log.Error().Err(err).Msg("Failed to send leak alert email")
+		return err
+	}
+	log.Info().Int("values", len(alerts)).Msg("Leak alert email sent")
+	return nil
+}
+
+// SendStaleDocumentsEmail tells the specified user that their documents left unfinished will
+// be archived or deleted, with links to the documents and how to keep them.
+func (s *EmailService) SendStaleDocumentsEmail(toEmail, toName, action string, cleanupAt time.Time, documents []*models.StaleDocument) error {
+	verb := "archived"
+	if action == constants.StaleDocumentActionDelete {
+		verb = "deleted"
+	}
+	subject := fmt.Sprintf("%d unfinished documents will be %s", len(documents), verb)
+	date := cleanupAt.Format("2 January 2006")
+	var plainText, htmlContent strings.Builder
+	fmt.Fprintf(&plainText, "The following documents have not been worked on for a long time and will be %s on %s. Open a document to keep it, or exempt it from cleanup in its settings.\n", verb, date)
+	fmt.Fprintf(&htmlContent, "<strong>The following documents have not been worked on for a long time and will be %s on %s.</strong> Open a document to keep it, or exempt it from cleanup in its settings.<ul>", verb, date)
+	for _, document := range documents {
+		reason := "never processed"
+		if document.Reason == constants.StaleReasonPendingReview {
+			reason = "detections awaiting review"
+		}
+		link := fmt.Sprintf(frontendDocumentURL, document.DocumentID)
+		fmt.Fprintf(&plainText, "\n%s (%s, last active %s)", link, reason, document.LastActivity.Format("2 January 2006"))
+		fmt.Fprintf(&htmlContent, `<li><a href="%s">%d</a>: %s, last active %s</li>`, link, document.DocumentID, reason, document.LastActivity.Format("2 January 2006"))
+	}
+	htmlContent.WriteString("</ul>")
+
+	message := &utils.EmailMessage{ToEmail: toEmail, ToName: toName, Subject: subject, PlainText: plainText.String(), HTML: htmlContent.String()}
+	if err := s.sender.Send(message); err != nil {
+		log.Error().Err(err).Msg("Failed to send stale documents email")
+		return err
+	}
+	log.Info().Int("documents", len(documents)).Msg("Stale documents email sent")
+	return nil
+}
+
+// SendReportEmail sends a scheduled report to the specified user with the report attached.
+func (s *EmailService) SendReportEmail(toEmail, toName string, report *models.Report) error {
+	message := &utils.EmailMessage{ToEmail: toEmail, ToName: toName, Subject: report.Subject, PlainText: report.Body, HTML: "<p>" + html.EscapeString(report.Body) + "</p>"}
+	message.Attachments = []utils.EmailAttachment{{Filename: report.Filename, ContentType: report.ContentType, Content: report.Content}}
+	if err := s.sender.Send(message); err != nil {
+		log.Error().Err(err).Msg("Failed to send report email")
+		return err
+	}
+	log.Info().Str("filename", report.Filename).Msg("Report email sent")
 	return nil
 }
@@ -1,26 +1,28 @@
 package service
 
 import (
+	"errors"
 	"os"
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/assert"
+
+	"github.com/yasinhessnawi1/Hideme_Backend/internal/constants"
+	"github.com/yasinhessnawi1/Hideme_Backend/internal/models"
+	"github.com/yasinhessnawi1/Hideme_Backend/internal/utils"
 )
 
-// MockSendGridClient is a mock implementation of SendGrid client
-type MockSendGridClient struct {
-	StatusCode int
-	Body       string
-	Error      error
-	Called     bool
-	CalledWith interface{}
+// MockEmailSender records the emails it is asked to send
+type MockEmailSender struct {
+	Error    error
+	Messages []*utils.EmailMessage
 }
 
-// Send implements the SendGrid client Send method
-func (m *MockSendGridClient) Send(email interface{}) (int, string, error) {
-	m.Called = true
-	m.CalledWith = email
-	return m.StatusCode, m.Body, m.Error
+// Send implements utils.EmailSender
+func (m *MockEmailSender) Send(message *utils.EmailMessage) error {
+	m.Messages = append(m.Messages, message)
+	return m.Error
 }
 
 // TestNewEmailService tests the NewEmailService function
@@ -38,7 +40,7 @@ func TestNewEmailService(t *testing.T) {
 		// Assert
 		assert.NoError(t, err)
 		assert.NotNil(t, service)
-		assert.Equal(t, "test-api-key", service.sendgridAPIKey)
+		assert.IsType(t, &utils.SendGridSender{}, service.sender)
 	})
 
 	t.Run("Error with no API key", func(t *testing.T) {
@@ -56,4 +58,137 @@ func TestNewEmailService(t *testing.T) {
 		assert.Nil(t, service)
 		assert.Contains(t, err.Error(), "SENDGRID_API_KEY environment variable not set")
 	})
+	t.Run("SMTP provider", func(t *testing.T) {
+		// Arrange
+		t.Setenv("EMAIL_PROVIDER", "smtp")
+		t.Setenv("SMTP_HOST", "email-smtp.eu-north-1.amazonaws.com")
+		t.Setenv("SMTP_PORT", "2587")
+
+		// Act
+		service, err := NewEmailService()
+
+		// Assert
+		assert.NoError(t, err)
+		assert.IsType(t, &utils.SMTPSender{}, service.sender)
+	})
+
+	t.Run("Error with no SMTP host", func(t *testing.T) {
+		// Arrange
+		t.Setenv("EMAIL_PROVIDER", "smtp")
+		t.Setenv("SMTP_HOST", "")
+
+		// Act
+		service, err := NewEmailService()
+
+		// Assert
+		assert.Error(t, err)
+		assert.Nil(t, service)
+	})
+
+	t.Run("Error with unknown provider", func(t *testing.T) {
+		// Arrange
+		t.Setenv("EMAIL_PROVIDER", "pigeon")
+
+		// Act
+		service, err := NewEmailService()
+
+		// Assert
+		assert.Error(t, err)
+		assert.Nil(t, service)
+		assert.Contains(t, err.Error(), "unknown EMAIL_PROVIDER")
+	})
+}
+
+// TestEmailService_Send tests that emails are composed and handed to the sender
+func TestEmailService_Send(t *testing.T) {
+	t.Run("Password reset", func(t *testing.T) {
+		// Arrange
+		sender := &MockEmailSender{}
+		service := NewEmailServiceWithSender(sender)
+
+		// Act
+		err := service.SendPasswordResetEmail("ola@example.com", "ola", "reset-token")
+
+		// Assert
+		assert.NoError(t, err)
+		if assert.Len(t, sender.Messages, 1) {
+			message := sender.Messages[0]
+			assert.Equal(t, "ola@example.com", message.ToEmail)
+			assert.Equal(t, "Password Reset Request", message.Subject)
+			assert.Contains(t, message.PlainText, "token=reset-token")
+			assert.Contains(t, message.HTML, "token=reset-token")
+		}
+	})
+
+	t.Run("Report with attachment", func(t *testing.T) {
+		// Arrange
+		sender := &MockEmailSender{}
+		service := NewEmailServiceWithSender(sender)
+		report := &models.Report{Subject: "Weekly report", Body: "See attached", Filename: "report.csv", ContentType: "text/csv", Content: []byte("a,b\n")}
+
+		// Act
+		err := service.SendReportEmail("ola@example.com", "ola", report)
+
+		// Assert
+		assert.NoError(t, err)
+		if assert.Len(t, sender.Messages, 1) {
+			assert.Equal(t, []utils.EmailAttachment{{Filename: "report.csv", ContentType: "text/csv", Content: []byte("a,b\n")}}, sender.Messages[0].Attachments)
+		}
+	})
+
+	t.Run("Leak alert with document links", func(t *testing.T) {
+		// Arrange
+		sender := &MockEmailSender{}
+		service := NewEmailServiceWithSender(sender)
+		alerts := []*models.LeakAlert{{EntityType: "NATIONAL_ID", Fingerprint: "3f9a1c0b7e42", DocumentCount: 2, DocumentIDs: []int64{4, 8}}}
+
+		// Act
+		err := service.SendLeakAlertEmail("ola@example.com", "ola", 30, alerts)
+
+		// Assert
+		assert.NoError(t, err)
+		if assert.Len(t, sender.Messages, 1) {
+			message := sender.Messages[0]
+			assert.Contains(t, message.PlainText, "last 30 days")
+			assert.Contains(t, message.PlainText, "NATIONAL_ID 3f9a1c0b7e42 in 2 documents")
+			assert.Contains(t, message.PlainText, "https://hidemeai.com/documents/8")
+			assert.Contains(t, message.HTML, `<a href="https://hidemeai.com/documents/4">4</a>`)
+		}
+	})
+
+	t.Run("Stale documents with reasons", func(t *testing.T) {
+		// Arrange
+		sender := &MockEmailSender{}
+		service := NewEmailServiceWithSender(sender)
+		lastActivity := time.Date(2025, 3, 1, 9, 0, 0, 0, time.UTC)
+		documents := []*models.StaleDocument{
+			{DocumentID: 4, Reason: constants.StaleReasonUnprocessed, LastActivity: lastActivity},
+			{DocumentID: 8, Reason: constants.StaleReasonPendingReview, LastActivity: lastActivity},
+		}
+
+		// Act
+		err := service.SendStaleDocumentsEmail("ola@example.com", "ola", constants.StaleDocumentActionDelete, time.Date(2025, 4, 7, 9, 0, 0, 0, time.UTC), documents)
+
+		// Assert
+		assert.NoError(t, err)
+		if assert.Len(t, sender.Messages, 1) {
+			message := sender.Messages[0]
+			assert.Equal(t, "2 unfinished documents will be deleted", message.Subject)
+			assert.Contains(t, message.PlainText, "deleted on 7 April 2025")
+			assert.Contains(t, message.PlainText, "https://hidemeai.com/documents/4 (never processed, last active 1 March 2025)")
+			assert.Contains(t, message.HTML, `<a href="https://hidemeai.com/documents/8">8</a>: detections awaiting review`)
+		}
+	})
+
+	t.Run("Sender error", func(t *testing.T) {
+		// Arrange
+		sender := &MockEmailSender{Error: errors.New("provider unavailable")}
+		service := NewEmailServiceWithSender(sender)
+
+		// Act
+		err := service.SendVerificationEmail("ola@example.com", "ola", "verify-token")
+
+		// Assert
+		assert.Error(t, err)
+	})
 }
@@ -0,0 +1,195 @@
+// Package service provides business logic implementations.
+package service
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/rs/zerolog/log"
+
+	"github.com/yasinhessnawi1/Hideme_Backend/internal/constants"
+	"github.com/yasinhessnawi1/Hideme_Backend/internal/models"
+	"github.com/yasinhessnawi1/Hideme_Backend/internal/repository"
+	"github.com/yasinhessnawi1/Hideme_Backend/internal/utils/clock"
+)
+
+// entityHashKeys provides the data-encryption keys the hashing keys of tenants are derived from.
+// It is implemented by TenantKeyRepository.
+type entityHashKeys interface {
+	GetOrCreateDataKey(ctx context.Context, userID int64) ([]byte, error)
+}
+
+// EntityLeakAlerter alerts users about values detected in many of their documents.
+type EntityLeakAlerter interface {
+	CheckDocument(ctx context.Context, userID, documentID int64)
+}
+
+// EntityHashService stores salted hashes of the values detected in saved documents, so that
+// analytics can count distinct values and find values leaking in several documents without
+// storing the values themselves.
+//
+// Values are hashed with HMAC-SHA256 under a key derived from the tenant's data-encryption
+// key, after lowercasing them and collapsing whitespace. The same value therefore hashes the
+// same within a tenant but can't be linked across tenants or guessed without the key, and
+// shredding the tenant key on account erasure unlinks the hashes from any value.
+type EntityHashService struct {
+	hashRepo repository.EntityHashRepository
+	keys     entityHashKeys
+	alerter  EntityLeakAlerter
+	clock    clock.Clock
+}
+
+// NewEntityHashService creates a new EntityHashService.
+//
+// Parameters:
+//   - hashRepo: Repository storing the hashes of detected values
+//   - keys: Source of the tenants' data-encryption keys
+//
+// Returns:
+//   - A configured EntityHashService
+func NewEntityHashService(hashRepo repository.EntityHashRepository, keys entityHashKeys) *EntityHashService {
+	return &EntityHashService{
+		hashRepo: hashRepo,
+		keys:     keys,
+	}
+}
+
+// SetClock sets the clock entity hashes are recorded at.
+func (s *EntityHashService) SetClock(c clock.Clock) {
+	s.clock = c
+}
+
+// now returns the current time of the service's clock.
+func (s *EntityHashService) now() time.Time {
+	return clock.Or(s.clock).Now()
+}
+
+// SetLeakAlerter enables alerting users when the values detected in a document were detected
+// in many of their documents. Without an alerter, repeated values only show in the report.
+func (s *EntityHashService) SetLeakAlerter(alerter EntityLeakAlerter) {
+	s.alerter = alerter
+}
+
+// hashingKey derives the key the detected values of a tenant are hashed with.
+func (s *EntityHashService) hashingKey(ctx context.Context, userID int64) ([]byte, error) {
+	dataKey, err := s.keys.GetOrCreateDataKey(ctx, userID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get data key: %w", err)
+	}
+	mac := hmac.New(sha256.New, dataKey)
+	mac.Write([]byte(constants.EntityHashKeyContext))
+	return mac.Sum(nil), nil
+}
+
+// hashEntityValue hashes a detected value, ignoring case and whitespace differences.
+func hashEntityValue(key []byte, value string) string {
+	normalized := strings.Join(strings.Fields(strings.ToLower(value)), " ")
+	mac := hmac.New(sha256.New, key)
+	mac.Write([]byte(normalized))
+	return hex.EncodeToString(mac.Sum(nil))
+}
+
+// IndexEntities replaces the hashes of the values detected in a document by those of its
+// redaction schema. Failures are logged rather than returned, so that they never fail the
+// upload or redaction.
+//
+// Parameters:
+//   - ctx: Context for the operation
+//   - userID: The ID of the owner of the document
+//   - documentID: The ID of the document
+//   - redactionSchema: The detections of the document
+func (s *EntityHashService) IndexEntities(ctx context.Context, userID, documentID int64, redactionSchema models.RedactionMapping) {
+	key, err := s.hashingKey(ctx, userID)
+	if err != nil {
+		log.Warn().Err(err).Int64("user_id", userID).Msg("Failed to derive entity hashing key")
+		return
+	}
+
+	now := s.now()
+	byValue := make(map[[2]string]*models.EntityHash)
+	var hashes []*models.EntityHash
+	for _, page := range redactionSchema.Pages {
+		for _, sensitive := range page.Sensitive {
+			if strings.TrimSpace(sensitive.OriginalText) == "" {
+				continue
+			}
+			id := [2]string{sensitive.EntityType, hashEntityValue(key, sensitive.OriginalText)}
+			if hash, ok := byValue[id]; ok {
+				hash.Occurrences++
+				continue
+			}
+			hash := &models.EntityHash{
+				UserID:      userID,
+				DocumentID:  documentID,
+				EntityType:  id[0],
+				ValueHash:   id[1],
+				Occurrences: 1,
+				CreatedAt:   now,
+			}
+			byValue[id] = hash
+			hashes = append(hashes, hash)
+		}
+	}
+
+	if err := s.hashRepo.ReplaceForDocument(ctx, documentID, hashes); err != nil {
+		log.Warn().Err(err).Int64("document_id", documentID).Msg("Failed to store entity hashes")
+		return
+	}
+
+	if s.alerter != nil && len(hashes) > 0 {
+		s.alerter.CheckDocument(ctx, userID, documentID)
+	}
+}
+
+// GetDuplicateLeakReport reports the values detected in several documents of a user, which
+// are the identifiers leaking most widely. Values are identified by a fingerprint of their
+// hash so the report never reveals them.
+//
+// Parameters:
+//   - ctx: Context for the operation
+//   - userID: The ID of the user
+//   - minDocuments: The number of documents a value must be detected in; the default applies below two
+//
+// Returns:
+//   - The distinct values per entity type and the repeated values, the most widespread first
+//   - An error if the hashes could not be aggregated
+func (s *EntityHashService) GetDuplicateLeakReport(ctx context.Context, userID int64, minDocuments int) (*models.DuplicateLeakReport, error) {
+	if minDocuments < 2 {
+		minDocuments = constants.DefaultDuplicateLeakMinDocuments
+	}
+
+	counts, err := s.hashRepo.CountByEntityType(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	duplicates, err := s.hashRepo.ListDuplicates(ctx, userID, minDocuments, constants.DuplicateLeakReportLimit)
+	if err != nil {
+		return nil, err
+	}
+
+	report := &models.DuplicateLeakReport{
+		MinDocuments: minDocuments,
+		EntityTypes:  []*models.EntityTypeCount{},
+		Duplicates:   []*models.DuplicateLeak{},
+	}
+	for _, count := range counts {
+		report.DistinctValues += count.DistinctValues
+		report.EntityTypes = append(report.EntityTypes, count)
+	}
+	for _, duplicate := range duplicates {
+		duplicate.Fingerprint = duplicate.ValueHash
+		if len(duplicate.Fingerprint) > constants.EntityFingerprintLength {
+			duplicate.Fingerprint = duplicate.Fingerprint[:constants.EntityFingerprintLength]
+		}
+		sort.Slice(duplicate.DocumentIDs, func(i, j int) bool { return duplicate.DocumentIDs[i] < duplicate.DocumentIDs[j] })
+		report.Duplicates = append(report.Duplicates, duplicate)
+	}
+
+	return report, nil
+}
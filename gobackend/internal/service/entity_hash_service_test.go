@@ -0,0 +1,197 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"sort"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/yasinhessnawi1/Hideme_Backend/internal/constants"
+	"github.com/yasinhessnawi1/Hideme_Backend/internal/models"
+)
+
+// memoryEntityHashRepository keeps entity hashes in memory
+type memoryEntityHashRepository struct {
+	hashes []*models.EntityHash
+	err    error
+}
+
+func (m *memoryEntityHashRepository) ReplaceForDocument(ctx context.Context, documentID int64, hashes []*models.EntityHash) error {
+	if m.err != nil {
+		return m.err
+	}
+	kept := m.hashes[:0]
+	for _, hash := range m.hashes {
+		if hash.DocumentID != documentID {
+			kept = append(kept, hash)
+		}
+	}
+	m.hashes = append(kept, hashes...)
+	return nil
+}
+
+func (m *memoryEntityHashRepository) CountByEntityType(ctx context.Context, userID int64) ([]*models.EntityTypeCount, error) {
+	counts := map[string]*models.EntityTypeCount{}
+	values := map[[2]string]bool{}
+	for _, hash := range m.hashes {
+		if hash.UserID != userID {
+			continue
+		}
+		count, ok := counts[hash.EntityType]
+		if !ok {
+			count = &models.EntityTypeCount{EntityType: hash.EntityType}
+			counts[hash.EntityType] = count
+		}
+		if id := [2]string{hash.EntityType, hash.ValueHash}; !values[id] {
+			values[id] = true
+			count.DistinctValues++
+		}
+		count.Occurrences += int64(hash.Occurrences)
+	}
+	var result []*models.EntityTypeCount
+	for _, count := range counts {
+		result = append(result, count)
+	}
+	sort.Slice(result, func(i, j int) bool { return result[i].EntityType < result[j].EntityType })
+	return result, nil
+}
+
+func (m *memoryEntityHashRepository) ListDuplicates(ctx context.Context, userID int64, minDocuments, limit int) ([]*models.DuplicateLeak, error) {
+	byValue := map[[2]string]*models.DuplicateLeak{}
+	var result []*models.DuplicateLeak
+	for _, hash := range m.hashes {
+		if hash.UserID != userID {
+			continue
+		}
+		id := [2]string{hash.EntityType, hash.ValueHash}
+		duplicate, ok := byValue[id]
+		if !ok {
+			duplicate = &models.DuplicateLeak{EntityType: hash.EntityType, ValueHash: hash.ValueHash}
+			byValue[id] = duplicate
+			result = append(result, duplicate)
+		}
+		duplicate.DocumentCount++
+		duplicate.Occurrences += int64(hash.Occurrences)
+		duplicate.DocumentIDs = append(duplicate.DocumentIDs, hash.DocumentID)
+	}
+	var duplicates []*models.DuplicateLeak
+	for _, duplicate := range result {
+		if duplicate.DocumentCount >= int64(minDocuments) && len(duplicates) < limit {
+			duplicates = append(duplicates, duplicate)
+		}
+	}
+	return duplicates, nil
+}
+
+func (m *memoryEntityHashRepository) ListRepeatedInDocument(ctx context.Context, userID, documentID int64, since time.Time, threshold int) ([]*models.DuplicateLeak, error) {
+	inDocument := map[[2]string]bool{}
+	for _, hash := range m.hashes {
+		if hash.DocumentID == documentID {
+			inDocument[[2]string{hash.EntityType, hash.ValueHash}] = true
+		}
+	}
+	byValue := map[[2]string]*models.DuplicateLeak{}
+	var result []*models.DuplicateLeak
+	for _, hash := range m.hashes {
+		id := [2]string{hash.EntityType, hash.ValueHash}
+		if hash.UserID != userID || hash.CreatedAt.Before(since) || !inDocument[id] {
+			continue
+		}
+		duplicate, ok := byValue[id]
+		if !ok {
+			duplicate = &models.DuplicateLeak{EntityType: hash.EntityType, ValueHash: hash.ValueHash}
+			byValue[id] = duplicate
+			result = append(result, duplicate)
+		}
+		duplicate.DocumentCount++
+		duplicate.Occurrences += int64(hash.Occurrences)
+		duplicate.DocumentIDs = append(duplicate.DocumentIDs, hash.DocumentID)
+	}
+	var repeated []*models.DuplicateLeak
+	for _, duplicate := range result {
+		if duplicate.DocumentCount > int64(threshold) {
+			repeated = append(repeated, duplicate)
+		}
+	}
+	return repeated, nil
+}
+
+func sensitiveSchema(values ...string) models.RedactionMapping {
+	page := models.Page{PageNumber: 1}
+	for i := 0; i+1 < len(values); i += 2 {
+		page.Sensitive = append(page.Sensitive, models.Sensitive{EntityType: values[i], OriginalText: values[i+1]})
+	}
+	return models.RedactionMapping{Version: models.RedactionSchemaVersion, Pages: []models.Page{page}}
+}
+
+func TestEntityHashService(t *testing.T) {
+	ctx := context.Background()
+	repo := &memoryEntityHashRepository{}
+	keys := &stubContentKeys{keys: map[int64][]byte{}}
+	svc := NewEntityHashService(repo, keys)
+
+	svc.IndexEntities(ctx, 1, 10, sensitiveSchema("PERSON", "Ola Nordmann", "PERSON", "ola  NORDMANN", "EMAIL_ADDRESS", "ola@example.com"))
+	svc.IndexEntities(ctx, 1, 11, sensitiveSchema("PERSON", " Ola Nordmann ", "PERSON", "Kari Nordmann", "PERSON", ""))
+	svc.IndexEntities(ctx, 2, 20, sensitiveSchema("PERSON", "Ola Nordmann"))
+
+	t.Run("Only hashes are stored", func(t *testing.T) {
+		for _, hash := range repo.hashes {
+			if strings.Contains(strings.ToLower(hash.ValueHash), "nordmann") || len(hash.ValueHash) != 64 {
+				t.Errorf("stored hash %q, want a hex SHA-256", hash.ValueHash)
+			}
+		}
+	})
+
+	t.Run("Values are normalized", func(t *testing.T) {
+		report, err := svc.GetDuplicateLeakReport(ctx, 1, 0)
+		if err != nil {
+			t.Fatalf("GetDuplicateLeakReport() error = %v", err)
+		}
+		if report.MinDocuments != constants.DefaultDuplicateLeakMinDocuments || report.DistinctValues != 3 {
+			t.Errorf("report = %+v, want 3 distinct values with the default minimum", report)
+		}
+		if len(report.Duplicates) != 1 {
+			t.Fatalf("report.Duplicates = %d, want 1", len(report.Duplicates))
+		}
+		duplicate := report.Duplicates[0]
+		if duplicate.EntityType != "PERSON" || duplicate.DocumentCount != 2 || duplicate.Occurrences != 3 {
+			t.Errorf("duplicate = %+v, want the person in both documents", duplicate)
+		}
+		if len(duplicate.Fingerprint) != constants.EntityFingerprintLength || !strings.HasPrefix(duplicate.ValueHash, duplicate.Fingerprint) {
+			t.Errorf("duplicate.Fingerprint = %q, want the start of the hash", duplicate.Fingerprint)
+		}
+	})
+
+	t.Run("Tenants do not share hashes", func(t *testing.T) {
+		var first, other string
+		for _, hash := range repo.hashes {
+			switch hash.DocumentID {
+			case 10:
+				if hash.EntityType == "PERSON" {
+					first = hash.ValueHash
+				}
+			case 20:
+				other = hash.ValueHash
+			}
+		}
+		if first == "" || first == other {
+			t.Errorf("the same value hashed to %q and %q for two tenants, want different hashes", first, other)
+		}
+	})
+
+	t.Run("Reindexing replaces the hashes", func(t *testing.T) {
+		svc.IndexEntities(ctx, 1, 11, sensitiveSchema("PERSON", "Kari Nordmann"))
+		report, err := svc.GetDuplicateLeakReport(ctx, 1, 2)
+		if err != nil || len(report.Duplicates) != 0 {
+			t.Errorf("GetDuplicateLeakReport() = %+v, %v, want no duplicates", report, err)
+		}
+	})
+
+	t.Run("Failures do not propagate", func(t *testing.T) {
+		repo.err = errors.New("database error")
+		defer func() { repo.err = nil }()
+		svc.IndexEntities(ctx, 1, 12, sensitiveSchema("PERSON", "Ola Nordmann"))
+	})
+}
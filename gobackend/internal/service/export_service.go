@@ -0,0 +1,363 @@
+// Package service provides business logic implementations.
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/rs/zerolog/log"
+
+	"github.com/yasinhessnawi1/Hideme_Backend/internal/config"
+	"github.com/yasinhessnawi1/Hideme_Backend/internal/constants"
+	"github.com/yasinhessnawi1/Hideme_Backend/internal/models"
+	"github.com/yasinhessnawi1/Hideme_Backend/internal/repository"
+	"github.com/yasinhessnawi1/Hideme_Backend/internal/utils"
+	"github.com/yasinhessnawi1/Hideme_Backend/internal/utils/clock"
+)
+
+// exportDocumentSource assembles the export bundle of a document.
+type exportDocumentSource interface {
+	ExportDocument(ctx context.Context, userID, documentID int64, locale models.Locale) (*models.DocumentExport, error)
+}
+
+// ExportService manages users' export destinations and delivers their redacted documents to them.
+//
+// The backend never stores the files themselves, so what is delivered is the document's
+// export bundle, the same JSON file GET /api/documents/{id}/export returns: its metadata,
+// redaction schema, detected entities and timeline. Documents are delivered to the
+// destinations with automatic delivery as soon as they are saved; failed deliveries are
+// retried by the export_deliveries maintenance task until they have used up their attempts.
+type ExportService struct {
+	destinationRepo repository.ExportDestinationRepository
+	deliveryRepo    repository.ExportDeliveryRepository
+	documents       exportDocumentSource
+	transports      map[string]ExportTransport
+	settings        *config.ExportSettings
+	clock           clock.Clock
+}
+
+// NewExportService creates a new ExportService.
+//
+// Parameters:
+//   - destinationRepo: Repository for export destinations
+//   - deliveryRepo: Repository for delivery tracking
+//   - documents: Assembles the export bundles of documents
+//   - transports: The transports by destination type
+//   - settings: The export configuration
+//
+// Returns:
+//   - A configured ExportService
+func NewExportService(
+	destinationRepo repository.ExportDestinationRepository,
+	deliveryRepo repository.ExportDeliveryRepository,
+	documents exportDocumentSource,
+	transports map[string]ExportTransport,
+	settings *config.ExportSettings,
+) *ExportService {
+	return &ExportService{
+		destinationRepo: destinationRepo,
+		deliveryRepo:    deliveryRepo,
+		documents:       documents,
+		transports:      transports,
+		settings:        settings,
+	}
+}
+
+// SetClock sets the clock deliveries are stamped and found stalled by.
+func (s *ExportService) SetClock(c clock.Clock) {
+	s.clock = c
+}
+
+// now returns the current time of the service's clock.
+func (s *ExportService) now() time.Time {
+	return clock.Or(s.clock).Now()
+}
+
+// ListDestinations retrieves a user's export destinations, without their credentials.
+//
+// Parameters:
+//   - ctx: Context for the operation
+//   - userID: The ID of the user
+//
+// Returns:
+//   - The destinations
+//   - An error if they can't be retrieved
+func (s *ExportService) ListDestinations(ctx context.Context, userID int64) ([]*models.ExportDestination, error) {
+	destinations, err := s.destinationRepo.ListByUser(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, destination := range destinations {
+		destination.Config = destination.Config.Redacted()
+	}
+	return destinations, nil
+}
+
+// CreateDestination adds an export destination for a user. The settings are checked,
+// but no connection is made; a failing destination shows in the delivery status.
+//
+// Parameters:
+//   - ctx: Context for the operation
+//   - userID: The ID of the user
+//   - req: The destination to create
+//
+// Returns:
+//   - The created destination, without its credentials
+//   - ValidationError if the type or a setting is invalid, DuplicateError if the name is taken
+func (s *ExportService) CreateDestination(ctx context.Context, userID int64, req *models.ExportDestinationCreate) (*models.ExportDestination, error) {
+	transport, ok := s.transports[req.Type]
+	if !ok {
+		return nil, utils.NewValidationError("type", constants.MsgExportDestinationType)
+	}
+	if err := transport.Validate(&req.Config); err != nil {
+		return nil, err
+	}
+
+	destination := &models.ExportDestination{
+		UserID:      userID,
+		Name:        req.Name,
+		Type:        req.Type,
+		Config:      req.Config,
+		AutoDeliver: req.AutoDeliver == nil || *req.AutoDeliver,
+	}
+	if err := s.destinationRepo.Create(ctx, destination); err != nil {
+		return nil, err
+	}
+
+	log.Info().
+		Int64("user_id", userID).
+		Int64("destination_id", destination.ID).
+		Str("type", destination.Type).
+		Msg("Export destination created")
+
+	destination.Config = destination.Config.Redacted()
+	return destination, nil
+}
+
+// DeleteDestination removes a user's export destination and its delivery history.
+// Files already delivered are left at the destination.
+//
+// Parameters:
+//   - ctx: Context for the operation
+//   - userID: The ID of the user
+//   - destinationID: The ID of the destination
+//
+// Returns:
+//   - NotFoundError if the user has no such destination
+func (s *ExportService) DeleteDestination(ctx context.Context, userID, destinationID int64) error {
+	if err := s.destinationRepo.Delete(ctx, destinationID, userID); err != nil {
+		return err
+	}
+
+	log.Info().
+		Int64("user_id", userID).
+		Int64("destination_id", destinationID).
+		Msg("Export destination deleted")
+
+	return nil
+}
+
+// ListDeliveries retrieves the delivery status of a user's document at each destination
+// it was delivered to.
+//
+// Parameters:
+//   - ctx: Context for the operation
+//   - userID: The ID of the user
+//   - documentID: The ID of the document
+//
+// Returns:
+//   - The deliveries
+//   - An error if they can't be retrieved
+func (s *ExportService) ListDeliveries(ctx context.Context, userID, documentID int64) ([]*models.ExportDelivery, error) {
+	return s.deliveryRepo.ListByDocument(ctx, userID, documentID)
+}
+
+// Deliver delivers a user's document to one of the user's destinations and waits for the
+// outcome. It is used to deliver documents saved before the destination was added, to
+// destinations without automatic delivery, and to retry deliveries that gave up.
+//
+// Parameters:
+//   - ctx: Context for the operation
+//   - userID: The ID of the user
+//   - documentID: The ID of the document
+//   - destinationID: The ID of the destination
+//
+// Returns:
+//   - The delivery, delivered or failed with the reason
+//   - ErrDocumentNotFound or NotFoundError if the user has no such document or destination
+func (s *ExportService) Deliver(ctx context.Context, userID, documentID, destinationID int64) (*models.ExportDelivery, error) {
+	destination, err := s.destinationRepo.GetByID(ctx, destinationID, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	content, err := s.exportContent(ctx, userID, documentID)
+	if err != nil {
+		return nil, err
+	}
+
+	return s.deliver(ctx, destination, documentID, content)
+}
+
+// DeliverDocument delivers a newly saved document to the user's destinations with automatic
+// delivery. It returns at once; the deliveries run in the background and are tracked in
+// the delivery status.
+//
+// Parameters:
+//   - userID: The ID of the user
+//   - documentID: The ID of the saved document
+func (s *ExportService) DeliverDocument(userID, documentID int64) {
+	go func() {
+		// Each delivery is bounded by its own timeout
+		if err := s.deliverToAll(context.Background(), userID, documentID); err != nil {
+			log.Error().Err(err).
+				Int64("user_id", userID).
+				Int64("document_id", documentID).
+				Msg("Failed to deliver document to export destinations")
+		}
+	}()
+}
+
+// deliverToAll delivers a document to all of a user's destinations with automatic delivery.
+func (s *ExportService) deliverToAll(ctx context.Context, userID, documentID int64) error {
+	destinations, err := s.destinationRepo.ListByUser(ctx, userID)
+	if err != nil {
+		return err
+	}
+
+	var content []byte
+	for _, destination := range destinations {
+		if !destination.AutoDeliver {
+			continue
+		}
+		// Assemble the bundle once, and only for users with destinations
+		if content == nil {
+			if content, err = s.exportContent(ctx, userID, documentID); err != nil {
+				return err
+			}
+		}
+		if _, err := s.deliver(ctx, destination, documentID, content); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// RetryFailed attempts failed and stalled deliveries again, least recently attempted first.
+//
+// Parameters:
+//   - ctx: Context for the operation
+//
+// Returns:
+//   - The number of deliveries that succeeded
+//   - An error if the deliveries can't be retrieved or tracked
+func (s *ExportService) RetryFailed(ctx context.Context) (int64, error) {
+	// A pending delivery older than two delivery timeouts was interrupted, for example by a restart
+	stalledBefore := s.now().Add(-2 * constants.ExportDeliveryTimeout)
+	deliveries, err := s.deliveryRepo.ListRetryable(ctx, s.settings.MaxAttempts, stalledBefore, constants.ExportDeliveryRetryBatchSize)
+	if err != nil {
+		return 0, err
+	}
+
+	var delivered int64
+	for _, delivery := range deliveries {
+		destination, err := s.destinationRepo.GetByID(ctx, delivery.DestinationID, delivery.UserID)
+		if err != nil {
+			if utils.IsNotFoundError(err) {
+				// Deleted since; its deliveries went with it
+				continue
+			}
+			return delivered, err
+		}
+
+		content, err := s.exportContent(ctx, delivery.UserID, delivery.DocumentID)
+		if err != nil {
+			if errors.Is(err, ErrDocumentNotFound) {
+				continue
+			}
+			return delivered, err
+		}
+
+		result, err := s.deliver(ctx, destination, delivery.DocumentID, content)
+		if err != nil {
+			return delivered, err
+		}
+		if result.Status == constants.ExportDeliveryDelivered {
+			delivered++
+		}
+	}
+
+	return delivered, nil
+}
+
+// deliver writes a document's export bundle to a destination and records the outcome.
+// Failures to write are recorded on the delivery; only tracking failures are returned.
+func (s *ExportService) deliver(ctx context.Context, destination *models.ExportDestination, documentID int64, content []byte) (*models.ExportDelivery, error) {
+	transport, ok := s.transports[destination.Type]
+	if !ok {
+		return nil, fmt.Errorf("no transport for export destination type %q", destination.Type)
+	}
+
+	name := fmt.Sprintf("%s%d.%s", constants.DocumentExportFilenamePrefix, documentID, constants.DocumentExportFormatJSON)
+	delivery, err := s.deliveryRepo.Start(ctx, destination.ID, documentID, name)
+	if err != nil {
+		return nil, err
+	}
+	delivery.DestinationName = destination.Name
+	delivery.UserID = destination.UserID
+
+	deliverCtx, cancel := context.WithTimeout(ctx, constants.ExportDeliveryTimeout)
+	deliverErr := transport.Deliver(deliverCtx, destination, name, content)
+	cancel()
+
+	delivery.Status = constants.ExportDeliveryDelivered
+	delivery.LastError = ""
+	if deliverErr != nil {
+		delivery.Status = constants.ExportDeliveryFailed
+		delivery.LastError = deliverErr.Error()
+	}
+	if err := s.deliveryRepo.Finish(ctx, delivery.ID, delivery.Status, delivery.LastError); err != nil {
+		return nil, err
+	}
+
+	now := s.now()
+	delivery.UpdatedAt = now
+	if deliverErr != nil {
+		log.Warn().Err(deliverErr).
+			Int64("user_id", destination.UserID).
+			Int64("document_id", documentID).
+			Int64("destination_id", destination.ID).
+			Int("attempt", delivery.Attempts).
+			Msg("Failed to deliver document to export destination")
+		return delivery, nil
+	}
+	delivery.DeliveredAt = &now
+
+	log.Info().
+		Int64("user_id", destination.UserID).
+		Int64("document_id", documentID).
+		Int64("destination_id", destination.ID).
+		Str("type", destination.Type).
+		Msg("Document delivered to export destination")
+
+	return delivery, nil
+}
+
+// exportContent assembles the export bundle of a user's document as delivered.
+func (s *ExportService) exportContent(ctx context.Context, userID, documentID int64) ([]byte, error) {
+	export, err := s.documents.ExportDocument(ctx, userID, documentID, models.LocaleISO)
+	if err != nil {
+		return nil, err
+	}
+
+	content, err := json.MarshalIndent(export, "", "  ")
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal document export: %w", err)
+	}
+	return content, nil
+}
@@ -0,0 +1,278 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/yasinhessnawi1/Hideme_Backend/internal/config"
+	"github.com/yasinhessnawi1/Hideme_Backend/internal/constants"
+	"github.com/yasinhessnawi1/Hideme_Backend/internal/models"
+	"github.com/yasinhessnawi1/Hideme_Backend/internal/utils"
+)
+
+// MockExportDestinationRepository is an in-memory implementation of repository.ExportDestinationRepository
+type MockExportDestinationRepository struct {
+	destinations map[int64]*models.ExportDestination
+	nextID       int64
+}
+
+func (m *MockExportDestinationRepository) Create(ctx context.Context, destination *models.ExportDestination) error {
+	m.nextID++
+	destination.ID = m.nextID
+	copied := *destination
+	m.destinations[destination.ID] = &copied
+	return nil
+}
+
+func (m *MockExportDestinationRepository) GetByID(ctx context.Context, id, userID int64) (*models.ExportDestination, error) {
+	destination, ok := m.destinations[id]
+	if !ok || destination.UserID != userID {
+		return nil, utils.NewNotFoundError("ExportDestination", id)
+	}
+	copied := *destination
+	return &copied, nil
+}
+
+func (m *MockExportDestinationRepository) ListByUser(ctx context.Context, userID int64) ([]*models.ExportDestination, error) {
+	destinations := []*models.ExportDestination{}
+	for id := int64(1); id <= m.nextID; id++ {
+		if destination, ok := m.destinations[id]; ok && destination.UserID == userID {
+			copied := *destination
+			destinations = append(destinations, &copied)
+		}
+	}
+	return destinations, nil
+}
+
+func (m *MockExportDestinationRepository) Delete(ctx context.Context, id, userID int64) error {
+	if _, err := m.GetByID(ctx, id, userID); err != nil {
+		return err
+	}
+	delete(m.destinations, id)
+	return nil
+}
+
+// MockExportDeliveryRepository is an in-memory implementation of repository.ExportDeliveryRepository
+type MockExportDeliveryRepository struct {
+	deliveries []*models.ExportDelivery
+	retryable  []*models.ExportDelivery
+}
+
+func (m *MockExportDeliveryRepository) Start(ctx context.Context, destinationID, documentID int64, objectName string) (*models.ExportDelivery, error) {
+	for _, delivery := range m.deliveries {
+		if delivery.DestinationID == destinationID && delivery.DocumentID == documentID {
+			delivery.Status = constants.ExportDeliveryPending
+			delivery.Attempts++
+			copied := *delivery
+			return &copied, nil
+		}
+	}
+	delivery := &models.ExportDelivery{
+		ID:            int64(len(m.deliveries) + 1),
+		DestinationID: destinationID,
+		DocumentID:    documentID,
+		Status:        constants.ExportDeliveryPending,
+		Attempts:      1,
+		ObjectName:    objectName,
+	}
+	m.deliveries = append(m.deliveries, delivery)
+	copied := *delivery
+	return &copied, nil
+}
+
+func (m *MockExportDeliveryRepository) Finish(ctx context.Context, id int64, status, lastError string) error {
+	for _, delivery := range m.deliveries {
+		if delivery.ID == id {
+			delivery.Status = status
+			delivery.LastError = lastError
+			return nil
+		}
+	}
+	return utils.NewNotFoundError("ExportDelivery", id)
+}
+
+func (m *MockExportDeliveryRepository) ListByDocument(ctx context.Context, userID, documentID int64) ([]*models.ExportDelivery, error) {
+	var deliveries []*models.ExportDelivery
+	for _, delivery := range m.deliveries {
+		if delivery.DocumentID == documentID {
+			deliveries = append(deliveries, delivery)
+		}
+	}
+	return deliveries, nil
+}
+
+func (m *MockExportDeliveryRepository) ListRetryable(ctx context.Context, maxAttempts int, stalledBefore time.Time, limit int) ([]*models.ExportDelivery, error) {
+	return m.retryable, nil
+}
+
+// MockExportDocumentSource exports the documents of user 1
+type MockExportDocumentSource struct{}
+
+func (m *MockExportDocumentSource) ExportDocument(ctx context.Context, userID, documentID int64, locale models.Locale) (*models.DocumentExport, error) {
+	if userID != 1 {
+		return nil, ErrDocumentNotFound
+	}
+	return &models.DocumentExport{Version: 1, Document: models.DocumentExportMetadata{ID: documentID}}, nil
+}
+
+// MockExportTransport records the files delivered to it
+type MockExportTransport struct {
+	deliverErr error
+	delivered  map[string][]byte
+}
+
+func (m *MockExportTransport) Validate(cfg *models.ExportDestinationConfig) error {
+	if cfg.Bucket == "" {
+		return utils.NewValidationError("config.bucket", "Bucket is required")
+	}
+	return nil
+}
+
+func (m *MockExportTransport) Deliver(ctx context.Context, dest *models.ExportDestination, name string, content []byte) error {
+	if m.deliverErr != nil {
+		return m.deliverErr
+	}
+	m.delivered[dest.Name+"/"+name] = content
+	return nil
+}
+
+func newTestExportService() (*ExportService, *MockExportDestinationRepository, *MockExportDeliveryRepository, *MockExportTransport) {
+	destinations := &MockExportDestinationRepository{destinations: make(map[int64]*models.ExportDestination)}
+	deliveries := &MockExportDeliveryRepository{}
+	transport := &MockExportTransport{delivered: make(map[string][]byte)}
+	svc := NewExportService(destinations, deliveries, &MockExportDocumentSource{},
+		map[string]ExportTransport{constants.ExportDestinationS3: transport},
+		&config.ExportSettings{MaxAttempts: 3})
+	return svc, destinations, deliveries, transport
+}
+
+func TestExportService_CreateDestination(t *testing.T) {
+	svc, destinations, _, _ := newTestExportService()
+	ctx := context.Background()
+
+	t.Run("Unknown type", func(t *testing.T) {
+		_, err := svc.CreateDestination(ctx, 1, &models.ExportDestinationCreate{Name: "Archive", Type: "ftp"})
+		if !utils.IsValidationError(err) {
+			t.Errorf("Expected a validation error, got %v", err)
+		}
+	})
+
+	t.Run("Invalid settings", func(t *testing.T) {
+		_, err := svc.CreateDestination(ctx, 1, &models.ExportDestinationCreate{Name: "Archive", Type: constants.ExportDestinationS3})
+		if !utils.IsValidationError(err) {
+			t.Errorf("Expected a validation error, got %v", err)
+		}
+	})
+
+	t.Run("Credentials are not returned", func(t *testing.T) {
+		destination, err := svc.CreateDestination(ctx, 1, &models.ExportDestinationCreate{
+			Name:   "Archive",
+			Type:   constants.ExportDestinationS3,
+			Config: models.ExportDestinationConfig{Bucket: "archive", AccessKeyID: "AKIA", SecretAccessKey: "s3cr3t"},
+		})
+		if err != nil {
+			t.Fatalf("CreateDestination returned error: %v", err)
+		}
+		if !destination.AutoDeliver {
+			t.Error("Expected automatic delivery by default")
+		}
+		if destination.Config.SecretAccessKey != "" || destination.Config.AccessKeyID != "AKIA" {
+			t.Errorf("Expected the secret to be left out, got %+v", destination.Config)
+		}
+		if destinations.destinations[destination.ID].Config.SecretAccessKey != "s3cr3t" {
+			t.Error("Expected the secret to be stored")
+		}
+
+		listed, _ := svc.ListDestinations(ctx, 1)
+		if len(listed) != 1 || listed[0].Config.SecretAccessKey != "" {
+			t.Errorf("Expected the listed destination without its secret, got %+v", listed)
+		}
+	})
+}
+
+func TestExportService_DeliverToAll(t *testing.T) {
+	svc, destinations, deliveries, transport := newTestExportService()
+	ctx := context.Background()
+	manual := false
+	for _, req := range []*models.ExportDestinationCreate{
+		{Name: "Archive", Type: constants.ExportDestinationS3, Config: models.ExportDestinationConfig{Bucket: "archive"}},
+		{Name: "Manual", Type: constants.ExportDestinationS3, Config: models.ExportDestinationConfig{Bucket: "manual"}, AutoDeliver: &manual},
+	} {
+		if _, err := svc.CreateDestination(ctx, 1, req); err != nil {
+			t.Fatalf("CreateDestination returned error: %v", err)
+		}
+	}
+
+	if err := svc.deliverToAll(ctx, 1, 11); err != nil {
+		t.Fatalf("deliverToAll returned error: %v", err)
+	}
+
+	content, ok := transport.delivered["Archive/hideme-document-11.json"]
+	if !ok || !strings.Contains(string(content), `"version": 1`) {
+		t.Fatalf("Expected the export bundle delivered to Archive, got %v", transport.delivered)
+	}
+	if _, ok := transport.delivered["Manual/hideme-document-11.json"]; ok {
+		t.Error("Expected no delivery to a destination without automatic delivery")
+	}
+	if len(deliveries.deliveries) != 1 || deliveries.deliveries[0].Status != constants.ExportDeliveryDelivered {
+		t.Errorf("Expected one delivered delivery, got %+v", deliveries.deliveries)
+	}
+
+	t.Run("Failure is tracked", func(t *testing.T) {
+		transport.deliverErr = errors.New("S3 upload failed with status 403: AccessDenied")
+		defer func() { transport.deliverErr = nil }()
+
+		delivery, err := svc.Deliver(ctx, 1, 11, 2)
+		if err != nil {
+			t.Fatalf("Deliver returned error: %v", err)
+		}
+		if delivery.Status != constants.ExportDeliveryFailed || !strings.Contains(delivery.LastError, "AccessDenied") {
+			t.Errorf("Expected a failed delivery with the reason, got %+v", delivery)
+		}
+		if delivery.DestinationName != "Manual" {
+			t.Errorf("Expected the destination name, got %q", delivery.DestinationName)
+		}
+	})
+
+	t.Run("Other user's destination", func(t *testing.T) {
+		if _, err := svc.Deliver(ctx, 2, 11, 1); !utils.IsNotFoundError(err) {
+			t.Errorf("Expected a not found error, got %v", err)
+		}
+		if len(destinations.destinations) != 2 {
+			t.Error("Expected the destinations to be untouched")
+		}
+	})
+}
+
+func TestExportService_RetryFailed(t *testing.T) {
+	svc, _, deliveries, transport := newTestExportService()
+	ctx := context.Background()
+	destination, err := svc.CreateDestination(ctx, 1, &models.ExportDestinationCreate{
+		Name: "Archive", Type: constants.ExportDestinationS3, Config: models.ExportDestinationConfig{Bucket: "archive"},
+	})
+	if err != nil {
+		t.Fatalf("CreateDestination returned error: %v", err)
+	}
+
+	deliveries.retryable = []*models.ExportDelivery{
+		{ID: 1, DestinationID: destination.ID, UserID: 1, DocumentID: 11, Status: constants.ExportDeliveryFailed},
+		// Destination deleted since
+		{ID: 2, DestinationID: 99, UserID: 1, DocumentID: 12, Status: constants.ExportDeliveryFailed},
+		// Document deleted since
+		{ID: 3, DestinationID: destination.ID, UserID: 2, DocumentID: 13, Status: constants.ExportDeliveryFailed},
+	}
+
+	delivered, err := svc.RetryFailed(ctx)
+	if err != nil {
+		t.Fatalf("RetryFailed returned error: %v", err)
+	}
+	if delivered != 1 {
+		t.Errorf("Expected 1 delivery to succeed, got %d", delivered)
+	}
+	if _, ok := transport.delivered["Archive/hideme-document-11.json"]; !ok {
+		t.Errorf("Expected the document to be delivered again, got %v", transport.delivered)
+	}
+}
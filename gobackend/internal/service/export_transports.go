@@ -0,0 +1,586 @@
+// Package service provides business logic implementations.
+//
+// This file implements the transports that deliver redacted documents to export
+// destinations: S3 buckets, SFTP servers and SharePoint folders.
+package service
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/binary"
+	"encoding/hex"
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"net/url"
+	"path"
+	"regexp"
+	"strconv"
+	"strings"
+	"syscall"
+	"time"
+
+	"golang.org/x/crypto/ssh"
+
+	"github.com/yasinhessnawi1/Hideme_Backend/internal/config"
+	"github.com/yasinhessnawi1/Hideme_Backend/internal/constants"
+	"github.com/yasinhessnawi1/Hideme_Backend/internal/models"
+	"github.com/yasinhessnawi1/Hideme_Backend/internal/utils"
+	"github.com/yasinhessnawi1/Hideme_Backend/internal/utils/clock"
+)
+
+// errExportPrivateNetwork is returned when a destination resolves to a private network address.
+var errExportPrivateNetwork = errors.New(constants.MsgExportDestinationPrivate)
+
+// ExportTransport delivers files to one type of export destination.
+type ExportTransport interface {
+	// Validate checks the settings of a destination before it is stored.
+	//
+	// Parameters:
+	//   - cfg: The settings of the destination
+	//
+	// Returns:
+	//   - ValidationError naming the first invalid setting
+	Validate(cfg *models.ExportDestinationConfig) error
+
+	// Deliver writes a file to a destination, replacing a file with the same name.
+	//
+	// Parameters:
+	//   - ctx: Context for cancellation control
+	//   - dest: The destination
+	//   - name: The name of the file
+	//   - content: The content of the file, a JSON document
+	//
+	// Returns:
+	//   - An error describing why the file could not be written
+	Deliver(ctx context.Context, dest *models.ExportDestination, name string, content []byte) error
+}
+
+// exportDriveUploader uploads files to the user's connected cloud drives.
+type exportDriveUploader interface {
+	UploadFile(ctx context.Context, userID int64, provider, folderID, name, contentType string, content []byte) error
+}
+
+// NewExportTransports creates the transports of all destination types.
+//
+// Parameters:
+//   - settings: The export configuration
+//   - drives: Uploads to the users' SharePoint connections
+//
+// Returns:
+//   - The transports by destination type
+func NewExportTransports(settings *config.ExportSettings, drives exportDriveUploader) map[string]ExportTransport {
+	dialer := newExportDialer(settings.AllowPrivateNetworks)
+
+	return map[string]ExportTransport{
+		constants.ExportDestinationS3:         NewS3Transport(dialer),
+		constants.ExportDestinationSFTP:       NewSFTPTransport(dialer),
+		constants.ExportDestinationSharePoint: &SharePointTransport{drives: drives},
+	}
+}
+
+// newExportDialer creates the dialer connections to destinations are made with. Users choose
+// the hosts, so unless allowed, connections to loopback, private and link-local addresses are
+// refused after the host is resolved, whatever name it was given.
+func newExportDialer(allowPrivateNetworks bool) *net.Dialer {
+	dialer := &net.Dialer{Timeout: constants.DriveRequestTimeout}
+	if allowPrivateNetworks {
+		return dialer
+	}
+
+	dialer.Control = func(network, address string, _ syscall.RawConn) error {
+		host, _, err := net.SplitHostPort(address)
+		if err != nil {
+			return err
+		}
+		ip := net.ParseIP(host)
+		if ip == nil || ip.IsLoopback() || ip.IsPrivate() || ip.IsUnspecified() ||
+			ip.IsLinkLocalUnicast() || ip.IsLinkLocalMulticast() || ip.IsMulticast() {
+			return errExportPrivateNetwork
+		}
+		return nil
+	}
+	return dialer
+}
+
+// S3Transport delivers to Amazon S3 buckets and S3-compatible stores, signing requests
+// with AWS Signature Version 4.
+type S3Transport struct {
+	client *http.Client
+	clock  clock.Clock
+}
+
+// NewS3Transport creates an S3Transport.
+//
+// Parameters:
+//   - dialer: The dialer connections to the stores are made with
+//
+// Returns:
+//   - A configured S3Transport
+func NewS3Transport(dialer *net.Dialer) *S3Transport {
+	return &S3Transport{
+		client: &http.Client{
+			Transport: &http.Transport{
+				DialContext:         dialer.DialContext,
+				TLSHandshakeTimeout: constants.DriveRequestTimeout,
+			},
+			// Redirects would send the signed request to a host the user didn't configure
+			CheckRedirect: func(*http.Request, []*http.Request) error {
+				return http.ErrUseLastResponse
+			},
+		},
+	}
+}
+
+// SetClock sets the clock uploads are signed at.
+func (t *S3Transport) SetClock(c clock.Clock) {
+	t.clock = c
+}
+
+// now returns the current time of the transport's clock.
+func (t *S3Transport) now() time.Time {
+	return clock.Or(t.clock).Now()
+}
+
+var (
+	// s3BucketPattern matches valid S3 bucket names, which are also part of the host name.
+	s3BucketPattern = regexp.MustCompile(`^[a-z0-9][a-z0-9.-]{1,61}[a-z0-9]$`)
+
+	// s3RegionPattern matches AWS region names.
+	s3RegionPattern = regexp.MustCompile(`^[a-z0-9-]+$`)
+)
+
+// Validate checks the bucket, region, credentials and endpoint.
+func (t *S3Transport) Validate(cfg *models.ExportDestinationConfig) error {
+	if !s3BucketPattern.MatchString(cfg.Bucket) {
+		return utils.NewValidationError("config.bucket", "Bucket must be a valid S3 bucket name")
+	}
+	if !s3RegionPattern.MatchString(cfg.Region) {
+		return utils.NewValidationError("config.region", "Region must be a valid AWS region")
+	}
+	if cfg.AccessKeyID == "" || cfg.SecretAccessKey == "" {
+		return utils.NewValidationError("config.access_key_id", "Access key ID and secret access key are required")
+	}
+	if cfg.Endpoint != "" {
+		endpoint, err := url.Parse(cfg.Endpoint)
+		if err != nil || endpoint.Scheme != "https" || endpoint.Host == "" || endpoint.User != nil {
+			return utils.NewValidationError("config.endpoint", "Endpoint must be an HTTPS URL")
+		}
+	}
+	return nil
+}
+
+// Deliver uploads the file with a single PUT request. Amazon S3 is addressed through the
+// bucket's virtual host; custom endpoints are addressed by path.
+func (t *S3Transport) Deliver(ctx context.Context, dest *models.ExportDestination, name string, content []byte) error {
+	cfg := &dest.Config
+	key := strings.TrimPrefix(path.Join(cfg.Prefix, name), "/")
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPut, s3ObjectURL(cfg.Endpoint, cfg.Bucket, cfg.Region, key), bytes.NewReader(content))
+	if err != nil {
+		return fmt.Errorf("failed to create S3 request: %w", err)
+	}
+	req.Header.Set(constants.HeaderContentType, constants.ContentTypeJSON)
+	signS3Request(req, cfg.Region, cfg.AccessKeyID, cfg.SecretAccessKey, sha256Hex(content), t.now())
+
+	resp, err := t.client.Do(req)
+	if err != nil {
+		return fmt.Errorf("S3 request failed: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return s3ResponseError("upload", resp)
+	}
+
+	return nil
+}
+
+// s3ObjectURL returns the URL of an object. Amazon S3 is addressed through the bucket's
+// virtual host; custom endpoints are addressed by path.
+func s3ObjectURL(endpoint, bucket, region, key string) string {
+	if endpoint == "" {
+		return fmt.Sprintf(constants.S3EndpointFormat, bucket, region) + "/" + awsURIEncode(key, false)
+	}
+	return strings.TrimSuffix(endpoint, "/") + "/" + bucket + "/" + awsURIEncode(key, false)
+}
+
+// s3ResponseError describes a failed S3 request by the error code of its XML body, such as
+// AccessDenied or NoSuchBucket.
+func s3ResponseError(op string, resp *http.Response) error {
+	body, _ := io.ReadAll(io.LimitReader(resp.Body, 1024))
+	var s3Error struct {
+		Code string `xml:"Code"`
+	}
+	_ = xml.Unmarshal(body, &s3Error)
+	if s3Error.Code != "" {
+		return fmt.Errorf("S3 %s failed with status %d: %s", op, resp.StatusCode, s3Error.Code)
+	}
+	return fmt.Errorf("S3 %s failed with status %d", op, resp.StatusCode)
+}
+
+// signS3Request adds the AWS Signature Version 4 headers to a request to S3.
+//
+// Parameters:
+//   - req: The request to sign
+//   - region: The region of the bucket
+//   - accessKeyID: The access key ID the request is signed with
+//   - secretAccessKey: The secret access key the request is signed with
+//   - payloadHash: The hex-encoded SHA-256 of the request body
+//   - now: The time of the request
+func signS3Request(req *http.Request, region, accessKeyID, secretAccessKey, payloadHash string, now time.Time) {
+	now = now.UTC()
+	amzDate := now.Format("20060102T150405Z")
+	date := now.Format("20060102")
+
+	req.Header.Set("X-Amz-Content-Sha256", payloadHash)
+	req.Header.Set("X-Amz-Date", amzDate)
+
+	signedHeaders := "host;x-amz-content-sha256;x-amz-date"
+	canonicalRequest := strings.Join([]string{
+		req.Method,
+		req.URL.EscapedPath(),
+		"",
+		"host:" + req.URL.Host,
+		"x-amz-content-sha256:" + payloadHash,
+		"x-amz-date:" + amzDate,
+		"",
+		signedHeaders,
+		payloadHash,
+	}, "\n")
+
+	scope := date + "/" + region + "/s3/aws4_request"
+	stringToSign := "AWS4-HMAC-SHA256\n" + amzDate + "\n" + scope + "\n" + sha256Hex([]byte(canonicalRequest))
+
+	key := hmacSHA256([]byte("AWS4"+secretAccessKey), date)
+	key = hmacSHA256(key, region)
+	key = hmacSHA256(key, "s3")
+	key = hmacSHA256(key, "aws4_request")
+	signature := hex.EncodeToString(hmacSHA256(key, stringToSign))
+
+	req.Header.Set(constants.HeaderAuthorization, fmt.Sprintf(
+		"AWS4-HMAC-SHA256 Credential=%s/%s, SignedHeaders=%s, Signature=%s",
+		accessKeyID, scope, signedHeaders, signature))
+}
+
+// awsURIEncode encodes a string the way AWS signatures expect: every byte except the
+// unreserved characters is percent-encoded, and slashes too unless they separate the path.
+func awsURIEncode(s string, encodeSlash bool) string {
+	var b strings.Builder
+	for i := 0; i < len(s); i++ {
+		c := s[i]
+		switch {
+		case 'A' <= c && c <= 'Z', 'a' <= c && c <= 'z', '0' <= c && c <= '9',
+			c == '-', c == '_', c == '.', c == '~':
+			b.WriteByte(c)
+		case c == '/' && !encodeSlash:
+			b.WriteByte(c)
+		default:
+			fmt.Fprintf(&b, "%%%02X", c)
+		}
+	}
+	return b.String()
+}
+
+// sha256Hex returns the hex-encoded SHA-256 of data.
+func sha256Hex(data []byte) string {
+	sum := sha256.Sum256(data)
+	return hex.EncodeToString(sum[:])
+}
+
+// hmacSHA256 returns the HMAC-SHA256 of data.
+func hmacSHA256(key []byte, data string) []byte {
+	mac := hmac.New(sha256.New, key)
+	mac.Write([]byte(data))
+	return mac.Sum(nil)
+}
+
+// SFTPTransport delivers to directories on SFTP servers. The server must present the
+// host key configured for the destination.
+type SFTPTransport struct {
+	dialer *net.Dialer
+}
+
+// NewSFTPTransport creates an SFTPTransport.
+//
+// Parameters:
+//   - dialer: The dialer connections to the servers are made with
+//
+// Returns:
+//   - A configured SFTPTransport
+func NewSFTPTransport(dialer *net.Dialer) *SFTPTransport {
+	return &SFTPTransport{dialer: dialer}
+}
+
+// Validate checks the server, credentials and host key.
+func (t *SFTPTransport) Validate(cfg *models.ExportDestinationConfig) error {
+	if cfg.Host == "" || strings.ContainsAny(cfg.Host, "/@ ") {
+		return utils.NewValidationError("config.host", "Host must be a host name or IP address")
+	}
+	if cfg.Port < 0 || cfg.Port > 65535 {
+		return utils.NewValidationError("config.port", "Port must be between 1 and 65535")
+	}
+	if cfg.Username == "" {
+		return utils.NewValidationError("config.username", "Username is required")
+	}
+	if (cfg.Password == "") == (cfg.PrivateKey == "") {
+		return utils.NewValidationError("config.password", "Either a password or a private key is required")
+	}
+	if cfg.PrivateKey != "" {
+		if _, err := ssh.ParsePrivateKey([]byte(cfg.PrivateKey)); err != nil {
+			return utils.NewValidationError("config.private_key", "Private key must be an unencrypted PEM private key")
+		}
+	}
+	if _, _, _, _, err := ssh.ParseAuthorizedKey([]byte(cfg.HostKey)); err != nil {
+		return utils.NewValidationError("config.host_key", "Host key must be a public key in authorized_keys format")
+	}
+	return nil
+}
+
+// Deliver connects to the server, opens the SFTP subsystem and writes the file.
+func (t *SFTPTransport) Deliver(ctx context.Context, dest *models.ExportDestination, name string, content []byte) error {
+	cfg := &dest.Config
+
+	hostKey, _, _, _, err := ssh.ParseAuthorizedKey([]byte(cfg.HostKey))
+	if err != nil {
+		return fmt.Errorf("invalid host key: %w", err)
+	}
+
+	var auth ssh.AuthMethod
+	if cfg.PrivateKey != "" {
+		signer, err := ssh.ParsePrivateKey([]byte(cfg.PrivateKey))
+		if err != nil {
+			return fmt.Errorf("invalid private key: %w", err)
+		}
+		auth = ssh.PublicKeys(signer)
+	} else {
+		auth = ssh.Password(cfg.Password)
+	}
+
+	port := cfg.Port
+	if port == 0 {
+		port = constants.DefaultSFTPPort
+	}
+	addr := net.JoinHostPort(cfg.Host, strconv.Itoa(port))
+
+	conn, err := t.dialer.DialContext(ctx, "tcp", addr)
+	if err != nil {
+		return fmt.Errorf("failed to connect to SFTP server: %w", err)
+	}
+	defer conn.Close()
+
+	// Bound the whole session by the context
+	if deadline, ok := ctx.Deadline(); ok {
+		_ = conn.SetDeadline(deadline)
+	}
+	stop := context.AfterFunc(ctx, func() { conn.Close() })
+	defer stop()
+
+	sshConn, chans, reqs, err := ssh.NewClientConn(conn, addr, &ssh.ClientConfig{
+		User:            cfg.Username,
+		Auth:            []ssh.AuthMethod{auth},
+		HostKeyCallback: ssh.FixedHostKey(hostKey),
+		Timeout:         constants.DriveRequestTimeout,
+	})
+	if err != nil {
+		return fmt.Errorf("SSH handshake failed: %w", err)
+	}
+	client := ssh.NewClient(sshConn, chans, reqs)
+	defer client.Close()
+
+	session, err := client.NewSession()
+	if err != nil {
+		return fmt.Errorf("failed to open SSH session: %w", err)
+	}
+	defer session.Close()
+
+	stdin, err := session.StdinPipe()
+	if err != nil {
+		return fmt.Errorf("failed to open SFTP input: %w", err)
+	}
+	stdout, err := session.StdoutPipe()
+	if err != nil {
+		return fmt.Errorf("failed to open SFTP output: %w", err)
+	}
+	if err := session.RequestSubsystem("sftp"); err != nil {
+		return fmt.Errorf("SFTP subsystem unavailable: %w", err)
+	}
+
+	return (&sftpWriter{w: stdin, r: stdout}).writeFile(path.Join(cfg.Prefix, name), content)
+}
+
+// SFTP protocol version 3 packet types and flags, as used to write a file.
+const (
+	sftpInit    = 1
+	sftpVersion = 2
+	sftpOpen    = 3
+	sftpClose   = 4
+	sftpWrite   = 6
+	sftpStatus  = 101
+	sftpHandle  = 102
+
+	// sftpOpenWriteCreateTruncate opens a file for writing, creating or truncating it.
+	sftpOpenWriteCreateTruncate = 0x02 | 0x08 | 0x10
+
+	// sftpMaxPacket bounds the size of the packets read from the server.
+	sftpMaxPacket = 256 * 1024
+)
+
+// sftpWriter speaks the part of the SFTP protocol needed to write a file.
+type sftpWriter struct {
+	w      io.Writer
+	r      io.Reader
+	nextID uint32
+}
+
+// writeFile creates or replaces a file with the content.
+func (s *sftpWriter) writeFile(filePath string, content []byte) error {
+	if err := s.send(sftpInit, binary.BigEndian.AppendUint32(nil, 3)); err != nil {
+		return err
+	}
+	if typ, _, err := s.recv(); err != nil {
+		return err
+	} else if typ != sftpVersion {
+		return fmt.Errorf("unexpected SFTP packet %d during initialization", typ)
+	}
+
+	// Open the file; the empty attributes leave the permissions to the server
+	payload := sftpString(nil, filePath)
+	payload = binary.BigEndian.AppendUint32(payload, sftpOpenWriteCreateTruncate)
+	payload = binary.BigEndian.AppendUint32(payload, 0)
+	typ, resp, err := s.request(sftpOpen, payload)
+	if err != nil {
+		return err
+	}
+	if typ != sftpHandle {
+		return sftpStatusError("open", typ, resp)
+	}
+	handle, _, ok := sftpReadString(resp)
+	if !ok {
+		return errors.New("malformed SFTP handle")
+	}
+
+	for offset := 0; offset < len(content); offset += constants.SFTPChunkSize {
+		end := min(offset+constants.SFTPChunkSize, len(content))
+		payload := sftpString(nil, string(handle))
+		payload = binary.BigEndian.AppendUint64(payload, uint64(offset))
+		payload = sftpString(payload, string(content[offset:end]))
+		if err := s.expectOK("write", sftpWrite, payload); err != nil {
+			return err
+		}
+	}
+
+	return s.expectOK("close", sftpClose, sftpString(nil, string(handle)))
+}
+
+// request sends a request with the next ID and reads the response to it.
+func (s *sftpWriter) request(typ byte, payload []byte) (byte, []byte, error) {
+	s.nextID++
+	id := s.nextID
+	if err := s.send(typ, append(binary.BigEndian.AppendUint32(nil, id), payload...)); err != nil {
+		return 0, nil, err
+	}
+
+	respType, resp, err := s.recv()
+	if err != nil {
+		return 0, nil, err
+	}
+	if len(resp) < 4 || binary.BigEndian.Uint32(resp) != id {
+		return 0, nil, errors.New("unexpected SFTP response ID")
+	}
+	return respType, resp[4:], nil
+}
+
+// expectOK sends a request that is answered with a status, and fails unless it is OK.
+func (s *sftpWriter) expectOK(op string, typ byte, payload []byte) error {
+	respType, resp, err := s.request(typ, payload)
+	if err != nil {
+		return err
+	}
+	if respType != sftpStatus || len(resp) < 4 || binary.BigEndian.Uint32(resp) != 0 {
+		return sftpStatusError(op, respType, resp)
+	}
+	return nil
+}
+
+// send writes a packet.
+func (s *sftpWriter) send(typ byte, payload []byte) error {
+	packet := binary.BigEndian.AppendUint32(nil, uint32(len(payload)+1))
+	packet = append(packet, typ)
+	if _, err := s.w.Write(append(packet, payload...)); err != nil {
+		return fmt.Errorf("failed to send SFTP packet: %w", err)
+	}
+	return nil
+}
+
+// recv reads a packet.
+func (s *sftpWriter) recv() (byte, []byte, error) {
+	var header [5]byte
+	if _, err := io.ReadFull(s.r, header[:]); err != nil {
+		return 0, nil, fmt.Errorf("failed to read SFTP packet: %w", err)
+	}
+	length := binary.BigEndian.Uint32(header[:4])
+	if length < 1 || length > sftpMaxPacket {
+		return 0, nil, fmt.Errorf("invalid SFTP packet length %d", length)
+	}
+
+	payload := make([]byte, length-1)
+	if _, err := io.ReadFull(s.r, payload); err != nil {
+		return 0, nil, fmt.Errorf("failed to read SFTP packet: %w", err)
+	}
+	return header[4], payload, nil
+}
+
+// sftpStatusError describes a failed SFTP request, with the server's message if it sent one.
+func sftpStatusError(op string, typ byte, resp []byte) error {
+	if typ != sftpStatus || len(resp) < 4 {
+		return fmt.Errorf("SFTP %s failed: unexpected packet %d", op, typ)
+	}
+	code := binary.BigEndian.Uint32(resp)
+	if message, _, ok := sftpReadString(resp[4:]); ok && len(message) > 0 {
+		return fmt.Errorf("SFTP %s failed with status %d: %s", op, code, message)
+	}
+	return fmt.Errorf("SFTP %s failed with status %d", op, code)
+}
+
+// sftpString appends a length-prefixed string.
+func sftpString(b []byte, s string) []byte {
+	b = binary.BigEndian.AppendUint32(b, uint32(len(s)))
+	return append(b, s...)
+}
+
+// sftpReadString reads a length-prefixed string.
+func sftpReadString(b []byte) ([]byte, []byte, bool) {
+	if len(b) < 4 {
+		return nil, nil, false
+	}
+	length := binary.BigEndian.Uint32(b)
+	if uint32(len(b)-4) < length {
+		return nil, nil, false
+	}
+	return b[4 : 4+length], b[4+length:], true
+}
+
+// SharePointTransport delivers to a folder of the SharePoint sites the destination's owner
+// has connected, with the owner's own authorization.
+type SharePointTransport struct {
+	drives exportDriveUploader
+}
+
+// Validate checks that a folder is chosen.
+func (t *SharePointTransport) Validate(cfg *models.ExportDestinationConfig) error {
+	if cfg.FolderID == "" {
+		return utils.NewValidationError("config.folder_id", "Folder ID is required")
+	}
+	return nil
+}
+
+// Deliver uploads the file to the folder.
+func (t *SharePointTransport) Deliver(ctx context.Context, dest *models.ExportDestination, name string, content []byte) error {
+	return t.drives.UploadFile(ctx, dest.UserID, constants.DriveProviderSharePoint, dest.Config.FolderID, name, constants.ContentTypeJSON, content)
+}
@@ -0,0 +1,187 @@
+// Package service provides business logic implementations for the HideMe application.
+//
+// This file implements the scrubbing of personal data from the filenames of uploaded
+// documents, which are stored as entered and would otherwise bypass redaction.
+package service
+
+import (
+	"context"
+	"path"
+	"regexp"
+	"sort"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/yasinhessnawi1/Hideme_Backend/internal/constants"
+	"github.com/yasinhessnawi1/Hideme_Backend/internal/models"
+)
+
+// FilenameRuleSource provides the settings, ban list and search patterns of a user.
+// It is implemented by SettingsService.
+type FilenameRuleSource interface {
+	GetUserSettings(ctx context.Context, userID int64) (*models.UserSetting, error)
+	GetBanList(ctx context.Context, userID int64) (*models.BanListWithWords, error)
+	GetSearchPatterns(ctx context.Context, userID int64) ([]*models.SearchPattern, error)
+}
+
+// filenameSeparators matches the characters that separate words in a filename, so that a
+// term like "John Doe" also matches "John_Doe" and "john-doe".
+var filenameSeparators = regexp.MustCompile(`[\s_.\-]+`)
+
+// FilenameScrubber replaces the user's ban list words and search pattern matches in the
+// filenames of uploaded documents, for users who enabled it in their settings. A document
+// named "John_Doe_SSN.pdf" is otherwise stored and listed with the very data its content
+// was redacted for.
+//
+// Terms match ignoring case, except for case-sensitive patterns, and words in a term match
+// whatever separators the filename uses. Ban list words also match as their options ask:
+// ignoring diacritics, as whole words only, or with their inflections. AI search patterns
+// describe what to look for rather than the text itself, so they are not applied. The
+// extension is kept.
+type FilenameScrubber struct {
+	rules FilenameRuleSource
+}
+
+// NewFilenameScrubber creates a new FilenameScrubber.
+//
+// Parameters:
+//   - rules: Source of the users' settings, ban lists and search patterns
+//
+// Returns:
+//   - A configured FilenameScrubber
+func NewFilenameScrubber(rules FilenameRuleSource) *FilenameScrubber {
+	return &FilenameScrubber{rules: rules}
+}
+
+// ScrubFilename replaces the user's ban list words and search pattern matches in a filename.
+//
+// Parameters:
+//   - ctx: Context for the operation
+//   - userID: The ID of the user uploading the document
+//   - filename: The original filename
+//
+// Returns:
+//   - The filename with every match replaced by constants.DefaultRedactionPlaceholder;
+//     the original filename if the user has not enabled scrubbing or nothing matched
+//   - Whether the filename was changed
+//   - An error if the user's settings or rules cannot be loaded; the filename must not be
+//     stored then, as it may contain the data the user asked to scrub
+func (s *FilenameScrubber) ScrubFilename(ctx context.Context, userID int64, filename string) (string, bool, error) {
+	settings, err := s.rules.GetUserSettings(ctx, userID)
+	if err != nil {
+		return "", false, err
+	}
+	if !settings.ScrubFilenames {
+		return filename, false, nil
+	}
+
+	matcher, err := s.matcher(ctx, userID)
+	if err != nil || matcher == nil {
+		return filename, false, err
+	}
+
+	ext := path.Ext(filename)
+	stem := strings.TrimSuffix(filename, ext)
+	scrubbed := matcher.replace(stem, constants.DefaultRedactionPlaceholder)
+	if scrubbed == stem {
+		return filename, false, nil
+	}
+
+	return scrubbed + ext, true, nil
+}
+
+// filenameMatcher matches the terms of a user in filenames. Each term is a group of the
+// expression, so that the terms matching only whole words can be told apart.
+type filenameMatcher struct {
+	expression *regexp.Regexp
+	wholeWord  []bool
+}
+
+// replace replaces every match of a term in a text, skipping matches of whole-word terms
+// that are part of a longer word.
+func (m *filenameMatcher) replace(text, replacement string) string {
+	var b strings.Builder
+	copied, offset := 0, 0
+	for offset <= len(text) {
+		match := m.expression.FindStringSubmatchIndex(text[offset:])
+		if match == nil {
+			break
+		}
+		start, end := offset+match[0], offset+match[1]
+
+		accepted := true
+		for group := range m.wholeWord {
+			if match[2*group+2] >= 0 {
+				accepted = !m.wholeWord[group] || atWordBoundaries(text, start, end)
+				break
+			}
+		}
+		if !accepted || end == start {
+			// Look for a match one character further on
+			_, size := utf8.DecodeRuneInString(text[start:])
+			offset = start + max(size, 1)
+			continue
+		}
+
+		b.WriteString(text[copied:start])
+		b.WriteString(replacement)
+		copied, offset = end, end
+	}
+	b.WriteString(text[copied:])
+	return b.String()
+}
+
+// matcher compiles the user's ban list words and search patterns into one expression, or
+// returns nil if the user has none. Longer terms come first, so that "John Doe" is replaced
+// as a whole rather than leaving "Doe" behind after "John".
+func (s *FilenameScrubber) matcher(ctx context.Context, userID int64) (*filenameMatcher, error) {
+	banList, err := s.rules.GetBanList(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	patterns, err := s.rules.GetSearchPatterns(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	type term struct {
+		text          string
+		caseSensitive bool
+		options       models.BanListMatchOptions
+	}
+	terms := make([]term, 0, len(banList.Words)+len(patterns))
+	for _, word := range banList.Words {
+		terms = append(terms, term{text: word, options: banList.MatchOptions(word)})
+	}
+	for _, pattern := range patterns {
+		if pattern.PatternType == models.AISearch {
+			continue
+		}
+		terms = append(terms, term{text: pattern.PatternText, caseSensitive: pattern.PatternType == models.CaseSensitive})
+	}
+	sort.SliceStable(terms, func(i, j int) bool { return len(terms[i].text) > len(terms[j].text) })
+
+	alternatives := make([]string, 0, len(terms))
+	wholeWord := make([]bool, 0, len(terms))
+	for _, t := range terms {
+		alternative := banWordExpression(t.text, t.options, filenameSeparators)
+		if alternative == "" {
+			continue
+		}
+
+		if !t.caseSensitive {
+			alternative = "(?i:" + alternative + ")"
+		}
+		alternatives = append(alternatives, "("+alternative+")")
+		wholeWord = append(wholeWord, t.options.WholeWord)
+	}
+	if len(alternatives) == 0 {
+		return nil, nil
+	}
+
+	expression, err := regexp.Compile(strings.Join(alternatives, "|"))
+	if err != nil {
+		return nil, err
+	}
+	return &filenameMatcher{expression: expression, wholeWord: wholeWord}, nil
+}
@@ -0,0 +1,104 @@
+package service
+
+import (
+	"context"
+	"testing"
+
+	"github.com/yasinhessnawi1/Hideme_Backend/internal/models"
+)
+
+// MockFilenameRuleSource returns fixed settings along with the rules of MockRuleSource
+type MockFilenameRuleSource struct {
+	MockRuleSource
+	scrub bool
+}
+
+func (m *MockFilenameRuleSource) GetUserSettings(ctx context.Context, userID int64) (*models.UserSetting, error) {
+	settings := models.NewUserSetting(userID)
+	settings.ScrubFilenames = m.scrub
+	return settings, nil
+}
+
+func TestFilenameScrubber(t *testing.T) {
+	rules := &MockFilenameRuleSource{
+		MockRuleSource: MockRuleSource{
+			words: []string{"john", "john doe"},
+			patterns: []*models.SearchPattern{
+				{ID: 1, PatternType: models.Normal, PatternText: "ssn"},
+				{ID: 2, PatternType: models.CaseSensitive, PatternText: "KARI"},
+				{ID: 3, PatternType: models.AISearch, PatternText: "report"},
+			},
+		},
+		scrub: true,
+	}
+	scrubber := NewFilenameScrubber(rules)
+	ctx := context.Background()
+
+	tests := []struct {
+		name     string
+		filename string
+		want     string
+		scrubbed bool
+	}{
+		{"Separators and case", "John_Doe_SSN.pdf", "[REDACTED]_[REDACTED].pdf", true},
+		{"Case-sensitive pattern", "kari_KARI.pdf", "kari_[REDACTED].pdf", true},
+		{"AI patterns are not applied", "annual_report.pdf", "annual_report.pdf", false},
+		{"Extension is kept", "invoice.ssn", "invoice.ssn", false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, scrubbed, err := scrubber.ScrubFilename(ctx, 1, tt.filename)
+			if err != nil {
+				t.Fatalf("ScrubFilename returned error: %v", err)
+			}
+			if got != tt.want || scrubbed != tt.scrubbed {
+				t.Errorf("Expected %q (scrubbed %v), got %q (scrubbed %v)", tt.want, tt.scrubbed, got, scrubbed)
+			}
+		})
+	}
+
+	t.Run("Ban list word options", func(t *testing.T) {
+		rules := &MockFilenameRuleSource{
+			MockRuleSource: MockRuleSource{
+				words: []string{"Ström", "Sørensen", "faktura", "Ann"},
+				options: map[string]models.BanListMatchOptions{
+					"Ström":    {DiacriticInsensitive: true},
+					"Sørensen": {DiacriticInsensitive: true, WholeWord: true},
+					"faktura":  {Stemming: models.StemmingNorwegian, WholeWord: true},
+					"Ann":      {WholeWord: true},
+				},
+			},
+			scrub: true,
+		}
+		scrubber := NewFilenameScrubber(rules)
+
+		tests := []struct {
+			filename string
+			want     string
+		}{
+			{"strom_strøm_STRÖM.pdf", "[REDACTED]_[REDACTED]_[REDACTED].pdf"},
+			{"Sorensen-Sorensensen.pdf", "[REDACTED]-Sorensensen.pdf"},
+			{"fakturaen_fakturaer_fakturamal.pdf", "[REDACTED]_[REDACTED]_fakturamal.pdf"},
+			{"Anna_Ann.pdf", "Anna_[REDACTED].pdf"},
+		}
+		for _, tt := range tests {
+			got, _, err := scrubber.ScrubFilename(ctx, 1, tt.filename)
+			if err != nil {
+				t.Fatalf("ScrubFilename returned error: %v", err)
+			}
+			if got != tt.want {
+				t.Errorf("Expected %q for %q, got %q", tt.want, tt.filename, got)
+			}
+		}
+	})
+
+	t.Run("Disabled", func(t *testing.T) {
+		rules.scrub = false
+		defer func() { rules.scrub = true }()
+
+		got, scrubbed, err := scrubber.ScrubFilename(ctx, 1, "John_Doe_SSN.pdf")
+		if err != nil || scrubbed || got != "John_Doe_SSN.pdf" {
+			t.Errorf("Expected the filename unchanged, got %q (scrubbed %v, err %v)", got, scrubbed, err)
+		}
+	})
+}
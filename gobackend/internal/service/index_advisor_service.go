@@ -0,0 +1,234 @@
+// Package service provides business logic implementations.
+package service
+
+import (
+	"context"
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/rs/zerolog/log"
+
+	"github.com/yasinhessnawi1/Hideme_Backend/internal/constants"
+	"github.com/yasinhessnawi1/Hideme_Backend/internal/models"
+	"github.com/yasinhessnawi1/Hideme_Backend/internal/repository"
+	"github.com/yasinhessnawi1/Hideme_Backend/internal/utils/clock"
+)
+
+// indexAdvisorTables are the tables that grow with customer data and are inspected by the index advisor.
+var indexAdvisorTables = []string{constants.TableDocuments, constants.TableDetectedEntities}
+
+// IndexAdvisorService inspects database statistics for missing indexes on the largest tables.
+// It runs as a periodic maintenance task and keeps the latest report for the admin analytics API,
+// so that degrading databases are noticed before requests start timing out.
+type IndexAdvisorService struct {
+	advisorRepo repository.IndexAdvisorRepository
+	tables      []string
+	report      *models.IndexAdvisorReport
+	reportMutex sync.RWMutex
+	clock       clock.Clock
+}
+
+// NewIndexAdvisorService creates a new IndexAdvisorService.
+//
+// Parameters:
+//   - advisorRepo: Repository for the database statistics
+//
+// Returns:
+//   - A configured IndexAdvisorService
+func NewIndexAdvisorService(advisorRepo repository.IndexAdvisorRepository) *IndexAdvisorService {
+	return &IndexAdvisorService{
+		advisorRepo: advisorRepo,
+		tables:      indexAdvisorTables,
+	}
+}
+
+// SetClock sets the clock reports are generated by.
+func (s *IndexAdvisorService) SetClock(c clock.Clock) {
+	s.clock = c
+}
+
+// now returns the current time of the service's clock.
+func (s *IndexAdvisorService) now() time.Time {
+	return clock.Or(s.clock).Now()
+}
+
+// Analyze inspects the database statistics and replaces the latest report.
+// Every suggestion is also logged to the database diagnostics channel.
+//
+// Parameters:
+//   - ctx: Context for the operation
+//
+// Returns:
+//   - The new report
+//   - An error if the statistics cannot be read
+func (s *IndexAdvisorService) Analyze(ctx context.Context) (*models.IndexAdvisorReport, error) {
+	report := &models.IndexAdvisorReport{
+		GeneratedAt:    s.now(),
+		SlowStatements: []models.StatementStats{},
+	}
+
+	tables, err := s.advisorRepo.GetTableStats(ctx, s.tables)
+	if err != nil {
+		return nil, err
+	}
+	report.Tables = tables
+
+	foreignKeys, err := s.advisorRepo.GetUnindexedForeignKeys(ctx, s.tables)
+	if err != nil {
+		return nil, err
+	}
+	report.UnindexedForeignKeys = foreignKeys
+
+	available, err := s.advisorRepo.HasStatStatements(ctx)
+	if err != nil {
+		return nil, err
+	}
+	report.StatStatementsAvailable = available
+	if available {
+		statements, err := s.advisorRepo.GetTopStatements(ctx, s.tables, constants.IndexAdvisorMaxStatements)
+		if err != nil {
+			return nil, err
+		}
+		report.SlowStatements = statements
+	}
+
+	report.Suggestions = s.suggest(report)
+
+	for _, suggestion := range report.Suggestions {
+		log.Warn().
+			Str("channel", constants.LogChannelDBDiagnostics).
+			Str("table", suggestion.Table).
+			Str("severity", suggestion.Severity).
+			Str("statement", suggestion.Statement).
+			Str("query", suggestion.Query).
+			Msg(suggestion.Reason)
+	}
+
+	s.reportMutex.Lock()
+	s.report = report
+	s.reportMutex.Unlock()
+
+	return report, nil
+}
+
+// GetReport returns the latest report, running the advisor first if there is none yet.
+//
+// Parameters:
+//   - ctx: Context for the operation
+//
+// Returns:
+//   - The latest report
+//   - An error if a new report is needed and cannot be created
+func (s *IndexAdvisorService) GetReport(ctx context.Context) (*models.IndexAdvisorReport, error) {
+	s.reportMutex.RLock()
+	report := s.report
+	s.reportMutex.RUnlock()
+
+	if report != nil {
+		return report, nil
+	}
+
+	return s.Analyze(ctx)
+}
+
+// RunIfDue runs the advisor when the latest report is older than constants.IndexAdvisorInterval.
+// The maintenance task calls this every cycle, so the advisor runs far less often than the other tasks.
+//
+// Parameters:
+//   - ctx: Context for the operation
+//
+// Returns:
+//   - The number of suggestions of the new report, or -1 if the advisor was not due
+//   - An error if the advisor fails
+func (s *IndexAdvisorService) RunIfDue(ctx context.Context) (int, error) {
+	s.reportMutex.RLock()
+	due := s.report == nil || s.now().Sub(s.report.GeneratedAt) >= constants.IndexAdvisorInterval
+	s.reportMutex.RUnlock()
+
+	if !due {
+		return -1, nil
+	}
+
+	report, err := s.Analyze(ctx)
+	if err != nil {
+		return 0, err
+	}
+
+	return len(report.Suggestions), nil
+}
+
+// suggest derives the suggestions of a report from its statistics, warnings first.
+func (s *IndexAdvisorService) suggest(report *models.IndexAdvisorReport) []models.IndexSuggestion {
+	suggestions := []models.IndexSuggestion{}
+
+	liveRows := make(map[string]int64, len(report.Tables))
+	for _, table := range report.Tables {
+		liveRows[table.Table] = table.LiveRows
+
+		// Large tables that are mostly scanned sequentially are missing an index for a common filter
+		if table.LiveRows >= constants.IndexAdvisorMinTableRows &&
+			table.SeqScans > 0 &&
+			float64(table.SeqScans) > constants.IndexAdvisorSeqScanRatio*float64(table.IndexScans) {
+			suggestions = append(suggestions, models.IndexSuggestion{
+				Table:    table.Table,
+				Severity: constants.SeverityWarning,
+				Reason: fmt.Sprintf(
+					"%d sequential scans read %d rows of %d while only %d index scans were used; a frequently filtered column is likely not indexed",
+					table.SeqScans, table.SeqRowsRead, table.LiveRows, table.IndexScans,
+				),
+			})
+		}
+	}
+
+	// Joins and cascading deletes over unindexed foreign keys scan the whole table
+	for _, key := range report.UnindexedForeignKeys {
+		severity := constants.SeverityInfo
+		if liveRows[key.Table] >= constants.IndexAdvisorMinTableRows {
+			severity = constants.SeverityWarning
+		}
+		suggestions = append(suggestions, models.IndexSuggestion{
+			Table:    key.Table,
+			Severity: severity,
+			Reason:   fmt.Sprintf("Foreign key %s on (%s) is not covered by an index", key.Constraint, strings.Join(key.Columns, ", ")),
+			Statement: fmt.Sprintf(
+				"CREATE INDEX CONCURRENTLY IF NOT EXISTS idx_%s_%s ON %s (%s)",
+				key.Table, strings.Join(key.Columns, "_"), key.Table, strings.Join(key.Columns, ", "),
+			),
+		})
+	}
+
+	for _, statement := range report.SlowStatements {
+		if statement.MeanMs < constants.IndexAdvisorSlowStatementMs {
+			continue
+		}
+		suggestions = append(suggestions, models.IndexSuggestion{
+			Table:    s.referencedTable(statement.Query),
+			Severity: constants.SeverityInfo,
+			Reason: fmt.Sprintf(
+				"Statement averages %.1f ms over %d calls; review its plan for sequential scans",
+				statement.MeanMs, statement.Calls,
+			),
+			Query: statement.Query,
+		})
+	}
+
+	sort.SliceStable(suggestions, func(i, j int) bool {
+		return suggestions[i].Severity == constants.SeverityWarning && suggestions[j].Severity != constants.SeverityWarning
+	})
+
+	return suggestions
+}
+
+// referencedTable returns the first inspected table a statement references.
+func (s *IndexAdvisorService) referencedTable(query string) string {
+	for _, table := range s.tables {
+		if regexp.MustCompile(`(?i)\b` + regexp.QuoteMeta(table) + `\b`).MatchString(query) {
+			return table
+		}
+	}
+	return ""
+}
@@ -0,0 +1,134 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/yasinhessnawi1/Hideme_Backend/internal/constants"
+	"github.com/yasinhessnawi1/Hideme_Backend/internal/models"
+	"github.com/yasinhessnawi1/Hideme_Backend/internal/testsupport/fakeclock"
+)
+
+// MockIndexAdvisorRepository is an in-memory implementation of repository.IndexAdvisorRepository
+type MockIndexAdvisorRepository struct {
+	tables         []models.TableScanStats
+	foreignKeys    []models.UnindexedForeignKey
+	statStatements bool
+	statements     []models.StatementStats
+	tablesErr      error
+	statementCalls int
+}
+
+func (m *MockIndexAdvisorRepository) GetTableStats(ctx context.Context, tables []string) ([]models.TableScanStats, error) {
+	return m.tables, m.tablesErr
+}
+
+func (m *MockIndexAdvisorRepository) GetUnindexedForeignKeys(ctx context.Context, tables []string) ([]models.UnindexedForeignKey, error) {
+	return m.foreignKeys, nil
+}
+
+func (m *MockIndexAdvisorRepository) HasStatStatements(ctx context.Context) (bool, error) {
+	return m.statStatements, nil
+}
+
+func (m *MockIndexAdvisorRepository) GetTopStatements(ctx context.Context, tables []string, limit int) ([]models.StatementStats, error) {
+	m.statementCalls++
+	return m.statements, nil
+}
+
+func TestIndexAdvisorService_Analyze(t *testing.T) {
+	repo := &MockIndexAdvisorRepository{
+		tables: []models.TableScanStats{
+			// Large and mostly scanned sequentially
+			{Table: "detected_entities", LiveRows: 250000, SeqScans: 40, SeqRowsRead: 9000000, IndexScans: 12},
+			// Small tables are never reported
+			{Table: "documents", LiveRows: 500, SeqScans: 300, IndexScans: 0},
+		},
+		foreignKeys: []models.UnindexedForeignKey{
+			{Table: "documents", Constraint: "documents_user_id_fkey", Columns: []string{"user_id"}},
+		},
+		statStatements: true,
+		statements: []models.StatementStats{
+			{Query: "SELECT * FROM detected_entities WHERE document_id = $1", Calls: 90, MeanMs: 350},
+			{Query: "SELECT * FROM documents WHERE document_id = $1", Calls: 9000, MeanMs: 0.4},
+		},
+	}
+	service := NewIndexAdvisorService(repo)
+
+	report, err := service.Analyze(context.Background())
+	if err != nil {
+		t.Fatalf("Analyze() error = %v", err)
+	}
+
+	if len(report.Suggestions) != 3 {
+		t.Fatalf("Analyze() returned %d suggestions; want 3: %+v", len(report.Suggestions), report.Suggestions)
+	}
+
+	// Warnings come first
+	scan := report.Suggestions[0]
+	if scan.Table != "detected_entities" || scan.Severity != constants.SeverityWarning {
+		t.Errorf("Unexpected first suggestion: %+v", scan)
+	}
+
+	foreignKey := report.Suggestions[1]
+	if foreignKey.Severity != constants.SeverityInfo ||
+		foreignKey.Statement != "CREATE INDEX CONCURRENTLY IF NOT EXISTS idx_documents_user_id ON documents (user_id)" {
+		t.Errorf("Unexpected foreign key suggestion: %+v", foreignKey)
+	}
+
+	statement := report.Suggestions[2]
+	if statement.Table != "detected_entities" || statement.Query == "" {
+		t.Errorf("Unexpected statement suggestion: %+v", statement)
+	}
+}
+
+func TestIndexAdvisorService_WithoutStatStatements(t *testing.T) {
+	repo := &MockIndexAdvisorRepository{}
+	service := NewIndexAdvisorService(repo)
+
+	report, err := service.Analyze(context.Background())
+	if err != nil {
+		t.Fatalf("Analyze() error = %v", err)
+	}
+	if report.StatStatementsAvailable || repo.statementCalls != 0 {
+		t.Error("Statement statistics were read although pg_stat_statements is not installed")
+	}
+	if report.SlowStatements == nil || report.Suggestions == nil {
+		t.Error("Empty lists must not be nil")
+	}
+}
+
+func TestIndexAdvisorService_RunIfDue(t *testing.T) {
+	repo := &MockIndexAdvisorRepository{}
+	service := NewIndexAdvisorService(repo)
+	now := time.Date(2024, time.May, 15, 10, 0, 0, 0, time.UTC)
+	clk := fakeclock.New(now)
+	service.SetClock(clk)
+	ctx := context.Background()
+
+	// The first run is always due
+	if count, err := service.RunIfDue(ctx); err != nil || count != 0 {
+		t.Errorf("RunIfDue() = %d, %v; want 0, nil", count, err)
+	}
+
+	now = clk.Advance(time.Hour)
+	if count, _ := service.RunIfDue(ctx); count != -1 {
+		t.Errorf("RunIfDue() within the interval = %d; want -1", count)
+	}
+
+	now = clk.Advance(constants.IndexAdvisorInterval)
+	if count, _ := service.RunIfDue(ctx); count != 0 {
+		t.Errorf("RunIfDue() after the interval = %d; want 0", count)
+	}
+
+	// Failures keep the previous report
+	repo.tablesErr = errors.New("database error")
+	if _, err := service.Analyze(ctx); err == nil {
+		t.Error("Analyze() should fail when statistics cannot be read")
+	}
+	if report, err := service.GetReport(ctx); err != nil || !report.GeneratedAt.Equal(now) {
+		t.Errorf("GetReport() = %v, %v; want the previous report", report, err)
+	}
+}
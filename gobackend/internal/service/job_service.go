@@ -0,0 +1,312 @@
+// Package service provides business logic implementations.
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/rs/zerolog/log"
+
+	"github.com/yasinhessnawi1/Hideme_Backend/internal/constants"
+	"github.com/yasinhessnawi1/Hideme_Backend/internal/models"
+	"github.com/yasinhessnawi1/Hideme_Backend/internal/redaction"
+	"github.com/yasinhessnawi1/Hideme_Backend/internal/repository"
+	"github.com/yasinhessnawi1/Hideme_Backend/internal/utils"
+	"github.com/yasinhessnawi1/Hideme_Backend/internal/utils/clock"
+	"github.com/yasinhessnawi1/Hideme_Backend/internal/worker"
+)
+
+// ErrJobNotReady is returned when the result of a job is requested before the job completed
+var ErrJobNotReady = utils.New(utils.ErrBadRequest, constants.StatusConflict, constants.MsgJobNotReady)
+
+// jobDocumentProcessor processes the documents of jobs, usually the DocumentService.
+type jobDocumentProcessor interface {
+	GetDocumentByID(ctx context.Context, userID, id int64) (*models.Document, error)
+	AddDetectedEntities(ctx context.Context, userID, documentID int64, inputs []models.DetectedEntityInput) (*models.DetectedEntityBatchResult, error)
+	RedactDocument(ctx context.Context, userID, documentID int64, method string, content []byte) (*redaction.Result, error)
+	ExportDocument(ctx context.Context, userID, documentID int64, locale models.Locale) (*models.DocumentExport, error)
+}
+
+// FeatureSource reports the optional features the deployment offers, usually the
+// PermissionService.
+type FeatureSource interface {
+	Features() map[string]bool
+}
+
+// UserNotifier streams events, such as job progress, to the clients of a user.
+type UserNotifier interface {
+	Notify(ctx context.Context, userID int64, eventType string, data interface{})
+}
+
+// JobService queues the detection, redaction and export of documents as background jobs,
+// for documents too large to process within the timeout of a request, and runs them on a
+// worker pool. Jobs run with the access of the user who queued them, so a document shared
+// with them read-only cannot be changed by a job either, and with the context of the request
+// that queued them: its request ID, caller, locale and the features offered at the time.
+type JobService struct {
+	jobs      repository.JobRepository
+	documents jobDocumentProcessor
+	notifier  UserNotifier
+	features  FeatureSource
+	clock     clock.Clock
+}
+
+// NewJobService creates a new JobService.
+//
+// Parameters:
+//   - jobs: Repository for the job queue
+//   - documents: The processor of the documents, usually the DocumentService
+//
+// Returns:
+//   - A configured JobService
+func NewJobService(jobs repository.JobRepository, documents jobDocumentProcessor) *JobService {
+	return &JobService{
+		jobs:      jobs,
+		documents: documents,
+	}
+}
+
+// SetClock sets the clock finished jobs are pruned by.
+func (s *JobService) SetClock(c clock.Clock) {
+	s.clock = c
+}
+
+// now returns the current time of the service's clock.
+func (s *JobService) now() time.Time {
+	return clock.Or(s.clock).Now()
+}
+
+// SetNotifier enables notifying users of the progress of their jobs. Without a notifier,
+// clients poll the status of their jobs.
+//
+// Parameters:
+//   - notifier: The notifier streaming the events to the user's clients
+func (s *JobService) SetNotifier(notifier UserNotifier) {
+	s.notifier = notifier
+}
+
+// SetFeatureSource enables snapshotting the features the deployment offers when a job is
+// queued, for the job to run with them. Without a source, jobs run without features.
+//
+// Parameters:
+//   - features: The source of the features, usually the PermissionService
+func (s *JobService) SetFeatureSource(features FeatureSource) {
+	s.features = features
+}
+
+// Register registers the handlers of the job kinds on a worker pool.
+//
+// Parameters:
+//   - pool: The pool running the jobs
+func (s *JobService) Register(pool *worker.Pool) {
+	pool.Handle(constants.JobKindDetection, s.runDetection)
+	pool.Handle(constants.JobKindRedaction, s.runRedaction)
+	pool.Handle(constants.JobKindExport, s.runExport)
+	pool.Observe(s.jobUpdated)
+}
+
+// Enqueue queues a job processing a document a user owns or that was shared with them.
+//
+// Parameters:
+//   - ctx: Context for the operation
+//   - userID: The ID of the user queuing the job
+//   - req: The kind of the job, its document and parameters
+//   - execution: The context of the request queuing the job, kept to run the job with; the
+//     features are snapshotted here
+//
+// Returns:
+//   - The pending job
+//   - ErrDocumentNotFound if the document doesn't exist or the user has no access to it
+//   - Other errors if the job could not be queued
+func (s *JobService) Enqueue(ctx context.Context, userID int64, req *models.JobRequest, execution models.JobContext) (*models.Job, error) {
+	// Report missing documents now rather than as a failed job
+	if _, err := s.documents.GetDocumentByID(ctx, userID, req.DocumentID); err != nil {
+		return nil, err
+	}
+
+	var payload interface{}
+	switch req.Kind {
+	case constants.JobKindDetection:
+		payload = models.JobDetectionPayload{Entities: req.Entities}
+	case constants.JobKindRedaction:
+		method := req.RedactionMethod
+		if method == "" {
+			method = constants.RedactionMethodBlackout
+		}
+		payload = models.JobRedactionPayload{Method: method}
+	}
+
+	if s.features != nil {
+		execution.Features = s.features.Features()
+	}
+
+	job := &models.Job{
+		UserID:      userID,
+		Kind:        req.Kind,
+		DocumentID:  req.DocumentID,
+		Context:     execution,
+		MaxAttempts: constants.JobMaxAttempts,
+	}
+	if payload != nil {
+		data, err := json.Marshal(payload)
+		if err != nil {
+			return nil, fmt.Errorf("failed to marshal job payload: %w", err)
+		}
+		job.Payload = data
+	}
+	if err := s.jobs.Create(ctx, job); err != nil {
+		return nil, err
+	}
+
+	log.Info().
+		Int64("user_id", userID).
+		Int64("job_id", job.ID).
+		Int64("document_id", job.DocumentID).
+		Str("kind", job.Kind).
+		Str("request_id", execution.RequestID).
+		Msg("Job queued")
+	s.jobUpdated(ctx, job)
+	return job, nil
+}
+
+// GetJob retrieves a job of a user with its status.
+//
+// Parameters:
+//   - ctx: Context for the operation
+//   - userID: The ID of the user
+//   - id: The ID of the job
+//
+// Returns:
+//   - The job
+//   - NotFoundError if the user has no such job
+func (s *JobService) GetJob(ctx context.Context, userID, id int64) (*models.Job, error) {
+	return s.jobs.GetByID(ctx, userID, id)
+}
+
+// ListJobs retrieves the jobs of a user, newest first.
+//
+// Parameters:
+//   - ctx: Context for the operation
+//   - userID: The ID of the user
+//
+// Returns:
+//   - The jobs
+//   - An error if retrieval fails
+func (s *JobService) ListJobs(ctx context.Context, userID int64) ([]*models.Job, error) {
+	return s.jobs.ListByUser(ctx, userID)
+}
+
+// GetResult retrieves the result of a completed job of a user.
+//
+// Parameters:
+//   - ctx: Context for the operation
+//   - userID: The ID of the user
+//   - id: The ID of the job
+//
+// Returns:
+//   - The job and its result
+//   - NotFoundError if the user has no such job, ErrJobNotReady if it has not completed
+func (s *JobService) GetResult(ctx context.Context, userID, id int64) (*models.Job, *models.JobResult, error) {
+	job, err := s.jobs.GetByID(ctx, userID, id)
+	if err != nil {
+		return nil, nil, err
+	}
+	if job.Status != constants.JobCompleted {
+		return nil, nil, ErrJobNotReady
+	}
+
+	result, err := s.jobs.GetResult(ctx, userID, id)
+	if err != nil {
+		return nil, nil, err
+	}
+	return job, result, nil
+}
+
+// DeleteExpiredJobs removes the jobs finished longer ago than constants.JobRetention, with
+// their results.
+//
+// Parameters:
+//   - ctx: Context for the operation
+//
+// Returns:
+//   - The number of removed jobs
+//   - An error if the deletion fails
+func (s *JobService) DeleteExpiredJobs(ctx context.Context) (int64, error) {
+	return s.jobs.DeleteFinishedBefore(ctx, s.now().Add(-constants.JobRetention))
+}
+
+// jobUpdated notifies the user who queued a job of its new status, and of the completion of
+// a detection job.
+func (s *JobService) jobUpdated(ctx context.Context, job *models.Job) {
+	if s.notifier == nil {
+		return
+	}
+	s.notifier.Notify(ctx, job.UserID, constants.NotificationJobUpdated, job)
+	if job.Kind == constants.JobKindDetection && job.Status == constants.JobCompleted {
+		s.notifier.Notify(ctx, job.UserID, constants.NotificationDetectionCompleted, job)
+	}
+}
+
+// runDetection adds the detected entities of a detection job to its document.
+func (s *JobService) runDetection(ctx context.Context, job *models.Job) (*models.JobResult, error) {
+	var payload models.JobDetectionPayload
+	if err := json.Unmarshal(job.Payload, &payload); err != nil {
+		return nil, worker.Permanent(fmt.Errorf("failed to unmarshal detection job payload: %w", err))
+	}
+
+	result, err := s.documents.AddDetectedEntities(ctx, job.UserID, job.DocumentID, payload.Entities)
+	if err != nil {
+		return nil, jobError(err)
+	}
+	return jsonJobResult(result)
+}
+
+// runRedaction applies the redaction schema of the document of a redaction job to its
+// stored PDF. Redacted PDFs have no regional formatting, so unlike exports they do not
+// depend on the locale of the job.
+func (s *JobService) runRedaction(ctx context.Context, job *models.Job) (*models.JobResult, error) {
+	var payload models.JobRedactionPayload
+	if err := json.Unmarshal(job.Payload, &payload); err != nil {
+		return nil, worker.Permanent(fmt.Errorf("failed to unmarshal redaction job payload: %w", err))
+	}
+
+	result, err := s.documents.RedactDocument(ctx, job.UserID, job.DocumentID, payload.Method, nil)
+	if err != nil {
+		return nil, jobError(err)
+	}
+	return &models.JobResult{ContentType: constants.ContentTypePDF, Data: result.PDF}, nil
+}
+
+// runExport assembles the full record of the document of an export job, formatted for the
+// locale of the request that queued it.
+func (s *JobService) runExport(ctx context.Context, job *models.Job) (*models.JobResult, error) {
+	export, err := s.documents.ExportDocument(ctx, job.UserID, job.DocumentID, worker.Locale(ctx))
+	if err != nil {
+		return nil, jobError(err)
+	}
+	return jsonJobResult(export)
+}
+
+// jsonJobResult encodes the result of a job as JSON.
+func jsonJobResult(v interface{}) (*models.JobResult, error) {
+	data, err := json.Marshal(v)
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal job result: %w", err)
+	}
+	return &models.JobResult{ContentType: constants.ContentTypeJSON, Data: data}, nil
+}
+
+// jobError marks the errors of a job that retrying cannot fix as permanent: documents and
+// content that are gone, access that was revoked, and input the document rejects. Other
+// errors, such as database errors, are retried.
+func jobError(err error) error {
+	for _, kind := range []error{utils.ErrNotFound, utils.ErrForbidden, utils.ErrBadRequest, utils.ErrValidation} {
+		if errors.Is(err, kind) {
+			return worker.Permanent(err)
+		}
+	}
+	return err
+}
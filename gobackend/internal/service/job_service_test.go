@@ -0,0 +1,369 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/yasinhessnawi1/Hideme_Backend/internal/auth"
+	"github.com/yasinhessnawi1/Hideme_Backend/internal/constants"
+	"github.com/yasinhessnawi1/Hideme_Backend/internal/models"
+	"github.com/yasinhessnawi1/Hideme_Backend/internal/redaction"
+	"github.com/yasinhessnawi1/Hideme_Backend/internal/repository"
+	"github.com/yasinhessnawi1/Hideme_Backend/internal/testsupport/fakeclock"
+	"github.com/yasinhessnawi1/Hideme_Backend/internal/utils"
+	"github.com/yasinhessnawi1/Hideme_Backend/internal/worker"
+)
+
+// stubJobDocuments processes the documents of jobs; document 404 does not exist
+type stubJobDocuments struct {
+	redactMethod    string
+	entities        []models.DetectedEntityInput
+	exportLocale    models.Locale
+	exportRequestID interface{}
+	err             error
+}
+
+func (s *stubJobDocuments) GetDocumentByID(ctx context.Context, userID, id int64) (*models.Document, error) {
+	if id == 404 {
+		return nil, ErrDocumentNotFound
+	}
+	return &models.Document{ID: id, UserID: userID}, nil
+}
+
+func (s *stubJobDocuments) AddDetectedEntities(ctx context.Context, userID, documentID int64, inputs []models.DetectedEntityInput) (*models.DetectedEntityBatchResult, error) {
+	if s.err != nil {
+		return nil, s.err
+	}
+	s.entities = inputs
+	return &models.DetectedEntityBatchResult{DocumentID: documentID, EntityIDs: []int64{31, 32}}, nil
+}
+
+func (s *stubJobDocuments) RedactDocument(ctx context.Context, userID, documentID int64, method string, content []byte) (*redaction.Result, error) {
+	if s.err != nil {
+		return nil, s.err
+	}
+	s.redactMethod = method
+	return &redaction.Result{PDF: []byte("%PDF-1.7 redacted")}, nil
+}
+
+func (s *stubJobDocuments) ExportDocument(ctx context.Context, userID, documentID int64, locale models.Locale) (*models.DocumentExport, error) {
+	if s.err != nil {
+		return nil, s.err
+	}
+	s.exportLocale = locale
+	s.exportRequestID = ctx.Value(auth.RequestIDContextKey)
+	return &models.DocumentExport{Locale: locale}, nil
+}
+
+// memoryJobRepository keeps jobs and their results in memory
+type memoryJobRepository struct {
+	repository.JobRepository
+	jobs    []*models.Job
+	results map[int64]*models.JobResult
+}
+
+func (m *memoryJobRepository) Create(ctx context.Context, job *models.Job) error {
+	job.ID = int64(len(m.jobs) + 1)
+	job.Status = constants.JobPending
+	stored := *job
+	m.jobs = append(m.jobs, &stored)
+	return nil
+}
+
+func (m *memoryJobRepository) GetByID(ctx context.Context, userID, id int64) (*models.Job, error) {
+	for _, job := range m.jobs {
+		if job.ID == id && job.UserID == userID {
+			stored := *job
+			return &stored, nil
+		}
+	}
+	return nil, utils.NewNotFoundError("Job", id)
+}
+
+// Claim hands out the pending jobs in order
+func (m *memoryJobRepository) Claim(ctx context.Context, now, leaseUntil time.Time) (*models.Job, error) {
+	for _, job := range m.jobs {
+		if job.Status == constants.JobPending {
+			job.Status = constants.JobRunning
+			job.Attempts++
+			claimed := *job
+			return &claimed, nil
+		}
+	}
+	return nil, nil
+}
+
+func (m *memoryJobRepository) Complete(ctx context.Context, job *models.Job, result *models.JobResult) error {
+	for _, stored := range m.jobs {
+		if stored.ID == job.ID {
+			stored.Status = constants.JobCompleted
+			if m.results == nil {
+				m.results = make(map[int64]*models.JobResult)
+			}
+			m.results[job.ID] = result
+			return nil
+		}
+	}
+	return utils.NewNotFoundError("Job", job.ID)
+}
+
+func (m *memoryJobRepository) GetResult(ctx context.Context, userID, id int64) (*models.JobResult, error) {
+	if result, ok := m.results[id]; ok {
+		return result, nil
+	}
+	return nil, utils.NewNotFoundError("JobResult", id)
+}
+
+// recordingNotifier records the notifications sent to users
+type recordingNotifier struct {
+	events []string
+	data   []interface{}
+}
+
+func (n *recordingNotifier) Notify(ctx context.Context, userID int64, eventType string, data interface{}) {
+	n.events = append(n.events, eventType)
+	n.data = append(n.data, data)
+}
+
+func TestJobService_Enqueue(t *testing.T) {
+	repo := &memoryJobRepository{}
+	s := NewJobService(repo, &stubJobDocuments{})
+
+	t.Run("Redaction defaults to blackout", func(t *testing.T) {
+		job, err := s.Enqueue(context.Background(), 7, &models.JobRequest{Kind: constants.JobKindRedaction, DocumentID: 12}, models.JobContext{})
+		require.NoError(t, err)
+		assert.Equal(t, constants.JobPending, job.Status)
+		assert.Equal(t, constants.JobMaxAttempts, job.MaxAttempts)
+		assert.JSONEq(t, `{"method":"blackout"}`, string(job.Payload))
+	})
+
+	t.Run("Detection keeps the entities", func(t *testing.T) {
+		job, err := s.Enqueue(context.Background(), 7, &models.JobRequest{
+			Kind:       constants.JobKindDetection,
+			DocumentID: 12,
+			Entities:   []models.DetectedEntityInput{{MethodID: 1, EntityName: "John Doe"}},
+		}, models.JobContext{})
+		require.NoError(t, err)
+		var payload models.JobDetectionPayload
+		require.NoError(t, json.Unmarshal(job.Payload, &payload))
+		require.Len(t, payload.Entities, 1)
+		assert.Equal(t, "John Doe", payload.Entities[0].EntityName)
+	})
+
+	t.Run("Keeps the request context", func(t *testing.T) {
+		execution := models.JobContext{RequestID: "req-1", Locale: models.LocaleNorwegian}
+		_, err := s.Enqueue(context.Background(), 7, &models.JobRequest{Kind: constants.JobKindExport, DocumentID: 12, Locale: models.LocaleNorwegian}, execution)
+		require.NoError(t, err)
+		assert.Equal(t, execution, repo.jobs[len(repo.jobs)-1].Context)
+	})
+
+	t.Run("Snapshots the features", func(t *testing.T) {
+		features := &stubFeatures{features: map[string]bool{constants.FeaturePIIScreening: true}}
+		s.SetFeatureSource(features)
+		defer s.SetFeatureSource(nil)
+
+		_, err := s.Enqueue(context.Background(), 7, &models.JobRequest{Kind: constants.JobKindExport, DocumentID: 12}, models.JobContext{})
+		require.NoError(t, err)
+
+		// Later changes of the configuration don't reach the queued job
+		features.features = map[string]bool{constants.FeaturePIIScreening: false}
+		assert.Equal(t, map[string]bool{constants.FeaturePIIScreening: true}, repo.jobs[len(repo.jobs)-1].Context.Features)
+	})
+
+	t.Run("Export has no payload", func(t *testing.T) {
+		job, err := s.Enqueue(context.Background(), 7, &models.JobRequest{Kind: constants.JobKindExport, DocumentID: 12}, models.JobContext{})
+		require.NoError(t, err)
+		assert.Empty(t, job.Payload)
+	})
+
+	t.Run("Document not found", func(t *testing.T) {
+		count := len(repo.jobs)
+		_, err := s.Enqueue(context.Background(), 7, &models.JobRequest{Kind: constants.JobKindExport, DocumentID: 404}, models.JobContext{})
+		assert.ErrorIs(t, err, ErrDocumentNotFound)
+		assert.Len(t, repo.jobs, count)
+	})
+}
+
+// stubFeatures reports a fixed set of features
+type stubFeatures struct {
+	features map[string]bool
+}
+
+func (f *stubFeatures) Features() map[string]bool {
+	return f.features
+}
+
+func TestJobService_RunsWithRequestContext(t *testing.T) {
+	repo := &memoryJobRepository{}
+	documents := &stubJobDocuments{}
+	s := NewJobService(repo, documents)
+
+	execution := models.JobContext{RequestID: "req-1", UserID: 7, Locale: models.LocaleGerman}
+	job, err := s.Enqueue(context.Background(), 7, &models.JobRequest{Kind: constants.JobKindExport, DocumentID: 12, Locale: models.LocaleGerman}, execution)
+	require.NoError(t, err)
+
+	// Stop the pool once the job completed
+	ctx, cancel := context.WithCancel(context.Background())
+	pool := worker.NewPool(repo, 1)
+	s.Register(pool)
+	pool.Observe(func(ctx context.Context, job *models.Job) {
+		if job.Status == constants.JobCompleted {
+			cancel()
+		}
+	})
+	done := make(chan struct{})
+	go func() {
+		pool.Run(ctx)
+		close(done)
+	}()
+	select {
+	case <-done:
+	case <-time.After(5 * time.Second):
+		t.Fatal("job did not complete")
+	}
+
+	// The export is formatted and logged as the request that queued it asked for
+	assert.Equal(t, models.LocaleGerman, documents.exportLocale)
+	assert.Equal(t, "req-1", documents.exportRequestID)
+
+	_, result, err := s.GetResult(context.Background(), 7, job.ID)
+	require.NoError(t, err)
+	var export models.DocumentExport
+	require.NoError(t, json.Unmarshal(result.Data, &export))
+	assert.Equal(t, models.LocaleGerman, export.Locale)
+}
+
+func TestJobService_GetResult(t *testing.T) {
+	result := &models.JobResult{ContentType: constants.ContentTypePDF, Data: []byte("%PDF-1.7")}
+	repo := &memoryJobRepository{
+		jobs: []*models.Job{
+			{ID: 1, UserID: 7, Status: constants.JobCompleted},
+			{ID: 2, UserID: 7, Status: constants.JobRunning},
+		},
+		results: map[int64]*models.JobResult{1: result},
+	}
+	s := NewJobService(repo, &stubJobDocuments{})
+
+	job, got, err := s.GetResult(context.Background(), 7, 1)
+	require.NoError(t, err)
+	assert.Equal(t, int64(1), job.ID)
+	assert.Equal(t, result, got)
+
+	_, _, err = s.GetResult(context.Background(), 7, 2)
+	assert.ErrorIs(t, err, ErrJobNotReady)
+
+	// Jobs of other users are not found
+	_, _, err = s.GetResult(context.Background(), 8, 1)
+	assert.ErrorIs(t, err, utils.ErrNotFound)
+}
+
+func TestJobService_Handlers(t *testing.T) {
+	t.Run("Redaction", func(t *testing.T) {
+		documents := &stubJobDocuments{}
+		s := NewJobService(&memoryJobRepository{}, documents)
+
+		result, err := s.runRedaction(context.Background(), &models.Job{UserID: 7, DocumentID: 12, Payload: []byte(`{"method":"mask"}`)})
+		require.NoError(t, err)
+		assert.Equal(t, constants.ContentTypePDF, result.ContentType)
+		assert.Equal(t, "%PDF-1.7 redacted", string(result.Data))
+		assert.Equal(t, constants.RedactionMethodMask, documents.redactMethod)
+	})
+
+	t.Run("Detection", func(t *testing.T) {
+		documents := &stubJobDocuments{}
+		s := NewJobService(&memoryJobRepository{}, documents)
+
+		payload := []byte(`{"entities":[{"method_id":1,"entity_name":"John Doe"},{"method_id":2,"entity_name":"Oslo"}]}`)
+		result, err := s.runDetection(context.Background(), &models.Job{UserID: 7, DocumentID: 12, Payload: payload})
+		require.NoError(t, err)
+		assert.Equal(t, constants.ContentTypeJSON, result.ContentType)
+		assert.Len(t, documents.entities, 2)
+
+		var batch models.DetectedEntityBatchResult
+		require.NoError(t, json.Unmarshal(result.Data, &batch))
+		assert.Equal(t, []int64{31, 32}, batch.EntityIDs)
+	})
+
+	t.Run("Export", func(t *testing.T) {
+		s := NewJobService(&memoryJobRepository{}, &stubJobDocuments{})
+
+		result, err := s.runExport(context.Background(), &models.Job{UserID: 7, DocumentID: 12})
+		require.NoError(t, err)
+		assert.Equal(t, constants.ContentTypeJSON, result.ContentType)
+		assert.True(t, json.Valid(result.Data))
+	})
+
+	t.Run("Errors", func(t *testing.T) {
+		tests := []struct {
+			name          string
+			err           error
+			wantPermanent bool
+		}{
+			{name: "Document deleted", err: ErrDocumentNotFound, wantPermanent: true},
+			{name: "Read-only share", err: ErrDocumentReadOnly, wantPermanent: true},
+			{name: "Not redactable", err: ErrDocumentNotRedactable, wantPermanent: true},
+			{name: "Database error", err: errors.New("connection refused")},
+		}
+
+		for _, tt := range tests {
+			t.Run(tt.name, func(t *testing.T) {
+				s := NewJobService(&memoryJobRepository{}, &stubJobDocuments{err: tt.err})
+
+				// Permanent errors fail the job at once; others are retried
+				_, err := s.runExport(context.Background(), &models.Job{UserID: 7, DocumentID: 12})
+				assert.ErrorIs(t, err, tt.err)
+				assert.Equal(t, tt.wantPermanent, worker.IsPermanent(err))
+			})
+		}
+	})
+}
+
+func TestJobService_Notifications(t *testing.T) {
+	notifier := &recordingNotifier{}
+	s := NewJobService(&memoryJobRepository{}, &stubJobDocuments{})
+	s.SetNotifier(notifier)
+
+	_, err := s.Enqueue(context.Background(), 7, &models.JobRequest{Kind: constants.JobKindExport, DocumentID: 12}, models.JobContext{})
+	require.NoError(t, err)
+	assert.Equal(t, []string{constants.NotificationJobUpdated}, notifier.events)
+
+	t.Run("Detection completed", func(t *testing.T) {
+		notifier.events = nil
+		s.jobUpdated(context.Background(), &models.Job{UserID: 7, Kind: constants.JobKindDetection, Status: constants.JobCompleted})
+		assert.Equal(t, []string{constants.NotificationJobUpdated, constants.NotificationDetectionCompleted}, notifier.events)
+	})
+
+	t.Run("Detection running", func(t *testing.T) {
+		notifier.events = nil
+		s.jobUpdated(context.Background(), &models.Job{UserID: 7, Kind: constants.JobKindDetection, Status: constants.JobRunning})
+		assert.Equal(t, []string{constants.NotificationJobUpdated}, notifier.events)
+	})
+}
+
+func TestJobService_DeleteExpiredJobs(t *testing.T) {
+	now := time.Date(2026, 3, 1, 12, 0, 0, 0, time.UTC)
+	repo := &cutoffJobRepository{}
+	s := NewJobService(repo, &stubJobDocuments{})
+	s.SetClock(fakeclock.New(now))
+
+	_, err := s.DeleteExpiredJobs(context.Background())
+	require.NoError(t, err)
+	assert.Equal(t, now.Add(-constants.JobRetention), repo.cutoff)
+}
+
+// cutoffJobRepository records the cutoff of the deletion of finished jobs
+type cutoffJobRepository struct {
+	repository.JobRepository
+	cutoff time.Time
+}
+
+func (r *cutoffJobRepository) DeleteFinishedBefore(ctx context.Context, cutoff time.Time) (int64, error) {
+	r.cutoff = cutoff
+	return 0, nil
+}
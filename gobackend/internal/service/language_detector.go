@@ -0,0 +1,111 @@
+// Package service provides business logic implementations.
+package service
+
+import (
+	"strings"
+	"unicode"
+
+	"github.com/yasinhessnawi1/Hideme_Backend/internal/models"
+)
+
+// languageStopwords are frequent short words of each detectable language. They carry
+// no personal data and occur in almost any text, which makes them a cheap signal.
+var languageStopwords = map[string][]string{
+	models.LanguageEnglish:   {"the", "and", "of", "to", "in", "is", "for", "with", "this", "that", "contract", "invoice", "report", "agreement"},
+	models.LanguageNorwegian: {"og", "ikke", "det", "er", "til", "av", "på", "med", "som", "jeg", "for", "ble", "kontrakt", "avtale", "faktura", "lønn", "rapport", "fødselsnummer"},
+	models.LanguageSwedish:   {"och", "inte", "det", "är", "till", "av", "på", "med", "som", "jag", "för", "blev", "kontrakt", "avtal", "faktura", "lön", "rapport", "personnummer"},
+	models.LanguageDanish:    {"og", "ikke", "det", "er", "til", "af", "på", "med", "som", "jeg", "for", "blev", "kontrakt", "aftale", "faktura", "løn", "rapport", "cpr"},
+	models.LanguageGerman:    {"der", "die", "das", "und", "nicht", "ist", "zu", "mit", "für", "von", "vertrag", "rechnung", "bericht"},
+	models.LanguageFrench:    {"le", "la", "les", "et", "est", "pas", "des", "du", "pour", "avec", "contrat", "facture", "rapport"},
+	models.LanguageSpanish:   {"el", "la", "los", "las", "y", "es", "no", "del", "para", "con", "contrato", "factura", "informe"},
+}
+
+// languageLetters are letters that are frequent in some of the detectable languages and
+// rare in the others. Letters shared by several languages add to each of them.
+var languageLetters = map[rune][]string{
+	'æ': {models.LanguageNorwegian, models.LanguageDanish},
+	'ø': {models.LanguageNorwegian, models.LanguageDanish},
+	'å': {models.LanguageNorwegian, models.LanguageDanish, models.LanguageSwedish},
+	'ä': {models.LanguageSwedish, models.LanguageGerman},
+	'ö': {models.LanguageSwedish, models.LanguageGerman},
+	'ü': {models.LanguageGerman},
+	'ß': {models.LanguageGerman},
+	'é': {models.LanguageFrench, models.LanguageSpanish},
+	'è': {models.LanguageFrench},
+	'ê': {models.LanguageFrench},
+	'ç': {models.LanguageFrench},
+	'ñ': {models.LanguageSpanish},
+	'á': {models.LanguageSpanish},
+	'ó': {models.LanguageSpanish},
+}
+
+// languageByStopword inverts languageStopwords.
+var languageByStopword = func() map[string][]string {
+	byWord := make(map[string][]string)
+	for language, words := range languageStopwords {
+		for _, word := range words {
+			byWord[word] = append(byWord[word], language)
+		}
+	}
+	return byWord
+}()
+
+// DetectLanguage guesses the language of a document from the text available to the
+// backend: the filename and the texts the detection service found on its pages. Every
+// stopword and characteristic letter scores a point for its languages.
+//
+// The backend never sees the full document content, so the guess is only as good as
+// these snippets; clients that know the language should send it instead.
+//
+// Parameters:
+//   - texts: The text snippets of the document
+//
+// Returns:
+//   - The ISO 639-1 code of the highest-scoring language
+//   - The empty string if no language scores or the best two are tied
+func DetectLanguage(texts ...string) string {
+	scores := make(map[string]int)
+	for _, text := range texts {
+		text = strings.ToLower(text)
+		for _, r := range text {
+			for _, language := range languageLetters[r] {
+				scores[language]++
+			}
+		}
+
+		words := strings.FieldsFunc(text, func(r rune) bool {
+			return !unicode.IsLetter(r)
+		})
+		for _, word := range words {
+			for _, language := range languageByStopword[word] {
+				scores[language]++
+			}
+		}
+	}
+
+	best, bestScore, tied := "", 0, false
+	for language, score := range scores {
+		switch {
+		case score > bestScore:
+			best, bestScore, tied = language, score, false
+		case score == bestScore:
+			tied = true
+		}
+	}
+	if tied {
+		return ""
+	}
+	return best
+}
+
+// documentTexts returns the filename and the original texts of the detected entities,
+// which are the only parts of a document's text the backend receives.
+func documentTexts(filename string, redactionSchema models.RedactionMapping) []string {
+	texts := []string{filename}
+	for _, page := range redactionSchema.Pages {
+		for _, sensitive := range page.Sensitive {
+			texts = append(texts, sensitive.OriginalText)
+		}
+	}
+	return texts
+}
@@ -0,0 +1,39 @@
+package service
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/yasinhessnawi1/Hideme_Backend/internal/models"
+)
+
+func TestDetectLanguage(t *testing.T) {
+	tests := []struct {
+		name     string
+		texts    []string
+		expected string
+	}{
+		{name: "Norwegian", texts: []string{"arbeidsavtale_ola.pdf", "Lønn og fødselsnummer til Ola Nordmann"}, expected: models.LanguageNorwegian},
+		{name: "Swedish", texts: []string{"anställningsavtal.pdf", "Lön och personnummer för Anna Svensson"}, expected: models.LanguageSwedish},
+		{name: "English", texts: []string{"employment_contract.pdf", "Salary of the employee and the contract terms"}, expected: models.LanguageEnglish},
+		{name: "German", texts: []string{"Arbeitsvertrag für Herrn Müller und die Rechnung"}, expected: models.LanguageGerman},
+		{name: "Names and numbers only", texts: []string{"scan_0042.pdf", "Ola Nordmann", "15058534541"}, expected: ""},
+		{name: "No text", expected: ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.expected, DetectLanguage(tt.texts...))
+		})
+	}
+}
+
+func TestDocumentTexts(t *testing.T) {
+	schema := models.RedactionMapping{Pages: []models.Page{
+		{PageNumber: 1, Sensitive: []models.Sensitive{{OriginalText: "Ola Nordmann"}}},
+		{PageNumber: 2, Sensitive: []models.Sensitive{{OriginalText: "Kari Nordmann"}, {OriginalText: "Oslo"}}},
+	}}
+
+	assert.Equal(t, []string{"avtale.pdf", "Ola Nordmann", "Kari Nordmann", "Oslo"}, documentTexts("avtale.pdf", schema))
+}
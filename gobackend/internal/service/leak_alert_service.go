@@ -0,0 +1,134 @@
+// Package service provides business logic implementations.
+package service
+
+import (
+	"context"
+	"time"
+
+	"github.com/rs/zerolog/log"
+
+	"github.com/yasinhessnawi1/Hideme_Backend/internal/config"
+	"github.com/yasinhessnawi1/Hideme_Backend/internal/constants"
+	"github.com/yasinhessnawi1/Hideme_Backend/internal/models"
+	"github.com/yasinhessnawi1/Hideme_Backend/internal/repository"
+	"github.com/yasinhessnawi1/Hideme_Backend/internal/utils/clock"
+)
+
+// LeakAlertNotifier alerts users about values leaking in many of their documents.
+type LeakAlertNotifier interface {
+	SendLeakAlertEmail(toEmail, toName string, windowDays int, alerts []*models.LeakAlert) error
+}
+
+// LeakAlertService alerts users about systemic leaks: the same detected value, such as one
+// person's national ID number, detected in more than the configured number of their
+// documents within the alert window. Per-document detections show what to redact in one
+// document; these alerts show identifiers that keep ending up in documents at all.
+//
+// Values are matched by their salted hash, so alerts identify them by fingerprint only.
+// A value is alerted about at most once per alert window.
+type LeakAlertService struct {
+	hashRepo  repository.EntityHashRepository
+	alertRepo repository.LeakAlertRepository
+	userRepo  repository.UserRepository
+	notifier  LeakAlertNotifier
+	settings  *config.LeakAlertSettings
+	clock     clock.Clock
+}
+
+// NewLeakAlertService creates a new LeakAlertService.
+//
+// Parameters:
+//   - hashRepo: Repository of the hashes of detected values
+//   - alertRepo: Repository recording the alerts raised
+//   - userRepo: Repository used to look up the email address of the user
+//   - notifier: The notifier sending the alerts
+//   - settings: The threshold and window of the alerts
+//
+// Returns:
+//   - A configured LeakAlertService
+func NewLeakAlertService(hashRepo repository.EntityHashRepository, alertRepo repository.LeakAlertRepository, userRepo repository.UserRepository, notifier LeakAlertNotifier, settings *config.LeakAlertSettings) *LeakAlertService {
+	return &LeakAlertService{
+		hashRepo:  hashRepo,
+		alertRepo: alertRepo,
+		userRepo:  userRepo,
+		notifier:  notifier,
+		settings:  settings,
+	}
+}
+
+// SetClock sets the clock alert windows are measured by.
+func (s *LeakAlertService) SetClock(c clock.Clock) {
+	s.clock = c
+}
+
+// now returns the current time of the service's clock.
+func (s *LeakAlertService) now() time.Time {
+	return clock.Or(s.clock).Now()
+}
+
+// CheckDocument alerts the owner of a document about the values detected in it that are now
+// detected in more of their documents than the threshold. The document is already saved, so
+// failures are logged rather than returned.
+//
+// Parameters:
+//   - ctx: Context for the operation
+//   - userID: The ID of the owner of the document
+//   - documentID: The ID of the document whose values were just hashed
+func (s *LeakAlertService) CheckDocument(ctx context.Context, userID, documentID int64) {
+	if s.settings.Threshold < 0 {
+		return
+	}
+
+	now := s.now()
+	since := now.AddDate(0, 0, -s.settings.WindowDays)
+	repeated, err := s.hashRepo.ListRepeatedInDocument(ctx, userID, documentID, since, s.settings.Threshold)
+	if err != nil {
+		log.Warn().Err(err).Int64("document_id", documentID).Msg("Failed to look up repeated values")
+		return
+	}
+
+	var alerts []*models.LeakAlert
+	for _, duplicate := range repeated {
+		alert := &models.LeakAlert{
+			UserID:        userID,
+			EntityType:    duplicate.EntityType,
+			ValueHash:     duplicate.ValueHash,
+			Fingerprint:   duplicate.ValueHash,
+			DocumentCount: duplicate.DocumentCount,
+			DocumentIDs:   duplicate.DocumentIDs,
+			AlertedAt:     now,
+		}
+		if len(alert.Fingerprint) > constants.EntityFingerprintLength {
+			alert.Fingerprint = alert.Fingerprint[:constants.EntityFingerprintLength]
+		}
+
+		// Alert about each value once per window, whichever upload crosses the threshold first
+		claimed, err := s.alertRepo.Claim(ctx, alert, since)
+		if err != nil {
+			log.Warn().Err(err).Int64("user_id", userID).Msg("Failed to record leak alert")
+			continue
+		}
+		if claimed {
+			alerts = append(alerts, alert)
+		}
+	}
+	if len(alerts) == 0 {
+		return
+	}
+
+	log.Info().
+		Int64("user_id", userID).
+		Int64("document_id", documentID).
+		Int("values", len(alerts)).
+		Msg("Repeated leak detected")
+
+	user, err := s.userRepo.GetByID(ctx, userID)
+	if err != nil {
+		log.Error().Err(err).Int64("user_id", userID).Msg("Failed to look up user for leak alert")
+		return
+	}
+
+	if err := s.notifier.SendLeakAlertEmail(user.Email, user.Username, s.settings.WindowDays, alerts); err != nil {
+		log.Error().Err(err).Int64("user_id", userID).Msg("Failed to send leak alert")
+	}
+}
@@ -0,0 +1,118 @@
+package service
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/yasinhessnawi1/Hideme_Backend/internal/config"
+	"github.com/yasinhessnawi1/Hideme_Backend/internal/models"
+	"github.com/yasinhessnawi1/Hideme_Backend/internal/testsupport/fakeclock"
+)
+
+// memoryLeakAlertRepository keeps leak alerts in memory
+type memoryLeakAlertRepository struct {
+	alerts map[leakAlertKey]*models.LeakAlert
+}
+
+type leakAlertKey struct {
+	userID     int64
+	entityType string
+	valueHash  string
+}
+
+func (m *memoryLeakAlertRepository) Claim(ctx context.Context, alert *models.LeakAlert, realertAfter time.Time) (bool, error) {
+	id := leakAlertKey{alert.UserID, alert.EntityType, alert.ValueHash}
+	if existing, ok := m.alerts[id]; ok && !existing.AlertedAt.Before(realertAfter) {
+		return false, nil
+	}
+	alert.ID = int64(len(m.alerts) + 1)
+	m.alerts[id] = alert
+	return true, nil
+}
+
+// recordingLeakAlertNotifier records the leak alerts it is asked to send
+type recordingLeakAlertNotifier struct {
+	emails []string
+	alerts [][]*models.LeakAlert
+}
+
+func (n *recordingLeakAlertNotifier) SendLeakAlertEmail(toEmail, toName string, windowDays int, alerts []*models.LeakAlert) error {
+	n.emails = append(n.emails, toEmail)
+	n.alerts = append(n.alerts, alerts)
+	return nil
+}
+
+func TestLeakAlertService(t *testing.T) {
+	ctx := context.Background()
+	users := NewMockUserRepository()
+	_ = users.Create(ctx, &models.User{Username: "ola", Email: "ola@example.com"})
+	hashes := &memoryEntityHashRepository{}
+	alerts := &memoryLeakAlertRepository{alerts: map[leakAlertKey]*models.LeakAlert{}}
+	notifier := &recordingLeakAlertNotifier{}
+	settings := &config.LeakAlertSettings{Threshold: 2, WindowDays: 30}
+
+	now := time.Date(2025, 5, 10, 12, 0, 0, 0, time.UTC)
+	alerter := NewLeakAlertService(hashes, alerts, users, notifier, settings)
+	clk := fakeclock.New(now)
+	alerter.SetClock(clk)
+	indexer := NewEntityHashService(hashes, &stubContentKeys{keys: map[int64][]byte{}})
+	indexer.SetClock(clk)
+	indexer.SetLeakAlerter(alerter)
+
+	index := func(documentID int64, values ...string) {
+		indexer.IndexEntities(ctx, 1, documentID, sensitiveSchema(values...))
+	}
+
+	t.Run("Below the threshold", func(t *testing.T) {
+		index(1, "NATIONAL_ID", "01019912345")
+		index(2, "NATIONAL_ID", "01019912345", "PERSON", "Ola Nordmann")
+		if len(notifier.alerts) != 0 {
+			t.Errorf("alerts sent = %d, want none in two documents", len(notifier.alerts))
+		}
+	})
+
+	t.Run("Over the threshold", func(t *testing.T) {
+		index(3, "NATIONAL_ID", "01019912345", "PERSON", "Ola Nordmann")
+		if len(notifier.alerts) != 1 || notifier.emails[0] != "ola@example.com" {
+			t.Fatalf("alerts sent = %d, want one to ola@example.com", len(notifier.alerts))
+		}
+		sent := notifier.alerts[0]
+		if len(sent) != 1 || sent[0].EntityType != "NATIONAL_ID" || sent[0].DocumentCount != 3 || len(sent[0].DocumentIDs) != 3 {
+			t.Errorf("alert = %+v, want the national ID in three documents", sent[0])
+		}
+		if strings.Contains(sent[0].Fingerprint, "0101") || !strings.HasPrefix(sent[0].ValueHash, sent[0].Fingerprint) {
+			t.Errorf("alert.Fingerprint = %q, want the start of the hash", sent[0].Fingerprint)
+		}
+	})
+
+	t.Run("Once per window", func(t *testing.T) {
+		index(4, "NATIONAL_ID", "01019912345")
+		if len(notifier.alerts) != 1 {
+			t.Errorf("alerts sent = %d, want no second alert within the window", len(notifier.alerts))
+		}
+
+		clk.Advance(31 * 24 * time.Hour)
+		index(5, "NATIONAL_ID", "01019912345")
+		if len(notifier.alerts) != 1 {
+			t.Errorf("alerts sent = %d, want none for detections outside the window", len(notifier.alerts))
+		}
+		index(6, "NATIONAL_ID", "01019912345")
+		index(7, "NATIONAL_ID", "01019912345")
+		if len(notifier.alerts) != 2 {
+			t.Errorf("alerts sent = %d, want a new alert in the next window", len(notifier.alerts))
+		}
+	})
+
+	t.Run("Turned off", func(t *testing.T) {
+		settings.Threshold = -1
+		defer func() { settings.Threshold = 2 }()
+		index(8, "NATIONAL_ID", "01019912345")
+		clk.Advance(31 * 24 * time.Hour)
+		index(9, "NATIONAL_ID", "01019912345")
+		if len(notifier.alerts) != 2 {
+			t.Errorf("alerts sent = %d, want none while alerting is off", len(notifier.alerts))
+		}
+	})
+}
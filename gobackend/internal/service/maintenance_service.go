@@ -0,0 +1,374 @@
+// Package service provides business logic implementations.
+package service
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/rs/zerolog/log"
+
+	"github.com/yasinhessnawi1/Hideme_Backend/internal/constants"
+	"github.com/yasinhessnawi1/Hideme_Backend/internal/models"
+	"github.com/yasinhessnawi1/Hideme_Backend/internal/repository"
+	"github.com/yasinhessnawi1/Hideme_Backend/internal/utils"
+	"github.com/yasinhessnawi1/Hideme_Backend/internal/utils/clock"
+)
+
+// MaintenanceFunc performs a maintenance task and returns the number of items it processed.
+type MaintenanceFunc func(ctx context.Context) (int64, error)
+
+// BatchMaintenanceFunc performs a maintenance task that processes at most batchSize items
+// (0 for no limit) and returns the number of items it processed. In a dry run it only
+// counts the items it would process.
+type BatchMaintenanceFunc func(ctx context.Context, batchSize int, dryRun bool) (int64, error)
+
+// maintenanceTask is a registered maintenance task.
+type maintenanceTask struct {
+	name        string
+	description string
+	batched     bool
+	defaults    models.MaintenanceTaskSettings
+	run         BatchMaintenanceFunc
+}
+
+// MaintenanceService keeps the registry of maintenance tasks. The server runs the enabled
+// tasks periodically, each on its own interval, and administrators can change the settings
+// of a task or run it on demand instead of waiting for the next run.
+type MaintenanceService struct {
+	tasks        []maintenanceTask
+	settingsRepo repository.MaintenanceSettingsRepository
+	clock        clock.Clock
+	started      time.Time
+
+	// mu guards settings and lastRun
+	mu       sync.Mutex
+	settings map[string]models.MaintenanceTaskSettings
+	lastRun  map[string]time.Time
+}
+
+// NewMaintenanceService creates a new MaintenanceService without tasks.
+//
+// Parameters:
+//   - settingsRepo: Repository storing the settings administrators chose; nil keeps them in memory only
+//
+// Returns:
+//   - A MaintenanceService ready for task registration
+func NewMaintenanceService(settingsRepo repository.MaintenanceSettingsRepository) *MaintenanceService {
+	return &MaintenanceService{
+		settingsRepo: settingsRepo,
+		clock:        clock.System,
+		started:      clock.System.Now(),
+		settings:     make(map[string]models.MaintenanceTaskSettings),
+		lastRun:      make(map[string]time.Time),
+	}
+}
+
+// SetClock sets the clock tasks are scheduled by, so tests can move time past a task's
+// interval. Tasks that have not run yet are due one interval after the clock was set.
+// It must be called before tasks are run.
+func (s *MaintenanceService) SetClock(c clock.Clock) {
+	s.clock = c
+	s.started = c.Now()
+}
+
+// Register adds a task to the registry. Tasks run in the order they were registered.
+// Registration is not synchronized and must be completed before tasks are run.
+//
+// Parameters:
+//   - name: The name identifying the task in the admin API
+//   - description: What the task does
+//   - run: The function performing the task
+func (s *MaintenanceService) Register(name, description string, run MaintenanceFunc) {
+	s.register(name, description, false, 0, func(ctx context.Context, _ int, _ bool) (int64, error) {
+		return run(ctx)
+	})
+}
+
+// RegisterBatch adds a task that honors a batch size and dry runs to the registry.
+//
+// Parameters:
+//   - name: The name identifying the task in the admin API
+//   - description: What the task does
+//   - defaultBatchSize: The batch size used until an administrator changes it; 0 for no limit
+//   - run: The function performing the task
+func (s *MaintenanceService) RegisterBatch(name, description string, defaultBatchSize int, run BatchMaintenanceFunc) {
+	s.register(name, description, true, defaultBatchSize, run)
+}
+
+// register adds a task with the default settings to the registry.
+func (s *MaintenanceService) register(name, description string, batched bool, batchSize int, run BatchMaintenanceFunc) {
+	s.tasks = append(s.tasks, maintenanceTask{
+		name:        name,
+		description: description,
+		batched:     batched,
+		defaults: models.MaintenanceTaskSettings{
+			Task:            name,
+			Enabled:         true,
+			IntervalSeconds: int(constants.DBMaintenanceInterval / time.Second),
+			BatchSize:       batchSize,
+		},
+		run: run,
+	})
+}
+
+// LoadSettings replaces the default settings of the registered tasks with the stored ones.
+// Stored settings of tasks that are no longer registered are ignored.
+//
+// Parameters:
+//   - ctx: Context for the operation
+//
+// Returns:
+//   - An error if the settings could not be read
+func (s *MaintenanceService) LoadSettings(ctx context.Context) error {
+	if s.settingsRepo == nil {
+		return nil
+	}
+
+	stored, err := s.settingsRepo.List(ctx)
+	if err != nil {
+		return err
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for _, settings := range stored {
+		if _, ok := s.find(settings.Task); ok {
+			s.settings[settings.Task] = *settings
+		}
+	}
+	return nil
+}
+
+// Tasks returns the registered tasks with their settings in the order they run.
+//
+// Returns:
+//   - The registered tasks
+func (s *MaintenanceService) Tasks() []models.MaintenanceTask {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	tasks := make([]models.MaintenanceTask, len(s.tasks))
+	for i, task := range s.tasks {
+		tasks[i] = s.info(task)
+	}
+	return tasks
+}
+
+// UpdateSettings changes the settings of a task and stores them. Omitted fields keep
+// their current value.
+//
+// Parameters:
+//   - ctx: Context for the operation
+//   - adminID: The administrator changing the settings
+//   - name: The name of the task
+//   - update: The settings to change
+//
+// Returns:
+//   - The task with its new settings
+//   - A not found error if no task has this name, a validation error if a batch size or
+//     dry run is set for a task that ignores them, or an error if storing failed
+func (s *MaintenanceService) UpdateSettings(ctx context.Context, adminID int64, name string, update *models.MaintenanceTaskSettingsUpdate) (*models.MaintenanceTask, error) {
+	task, ok := s.find(name)
+	if !ok {
+		return nil, utils.NewNotFoundError("Maintenance task", name)
+	}
+	if !task.batched {
+		if update.BatchSize != nil {
+			return nil, utils.NewValidationError("batch_size", constants.MsgMaintenanceTaskNotBatched)
+		}
+		if update.DryRun != nil {
+			return nil, utils.NewValidationError("dry_run", constants.MsgMaintenanceTaskNotBatched)
+		}
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	settings := s.settingsFor(task)
+	if update.Enabled != nil {
+		settings.Enabled = *update.Enabled
+	}
+	if update.IntervalSeconds != nil {
+		settings.IntervalSeconds = *update.IntervalSeconds
+	}
+	if update.BatchSize != nil {
+		settings.BatchSize = *update.BatchSize
+	}
+	if update.DryRun != nil {
+		settings.DryRun = *update.DryRun
+	}
+	now := s.clock.Now()
+	settings.UpdatedBy = &adminID
+	settings.UpdatedAt = &now
+
+	if s.settingsRepo != nil {
+		if err := s.settingsRepo.Upsert(ctx, &settings); err != nil {
+			return nil, fmt.Errorf("failed to store maintenance task settings: %w", err)
+		}
+	}
+	s.settings[name] = settings
+
+	log.Info().
+		Int64("admin_id", adminID).
+		Str("task", name).
+		Bool("enabled", settings.Enabled).
+		Int("interval_seconds", settings.IntervalSeconds).
+		Int("batch_size", settings.BatchSize).
+		Bool("dry_run", settings.DryRun).
+		Str("category", constants.LogCategoryAdmin).
+		Msg("Maintenance task settings changed")
+
+	info := s.info(task)
+	return &info, nil
+}
+
+// Run runs a single task by name with its current batch size and dry run setting, even if
+// its periodic runs are disabled. A failing task is reported in the returned run rather
+// than as an error, so callers can show how far it got.
+//
+// Parameters:
+//   - ctx: Context for the operation
+//   - name: The name of the task
+//
+// Returns:
+//   - The outcome of the run
+//   - A not found error if no task has this name
+func (s *MaintenanceService) Run(ctx context.Context, name string) (*models.MaintenanceRun, error) {
+	task, ok := s.find(name)
+	if !ok {
+		return nil, utils.NewNotFoundError("Maintenance task", name)
+	}
+	return s.run(ctx, task), nil
+}
+
+// RunAll runs every enabled task in order. A failing task does not stop the others.
+//
+// Parameters:
+//   - ctx: Context for the operation
+//
+// Returns:
+//   - The outcome of each task that ran
+func (s *MaintenanceService) RunAll(ctx context.Context) []*models.MaintenanceRun {
+	return s.runWhere(ctx, func(task maintenanceTask, settings models.MaintenanceTaskSettings) bool {
+		return settings.Enabled
+	})
+}
+
+// RunDue runs, in order, every enabled task whose interval has passed since its last run.
+// Tasks that have not run yet are due one interval after the service was created.
+//
+// Parameters:
+//   - ctx: Context for the operation
+//
+// Returns:
+//   - The outcome of each task that ran
+func (s *MaintenanceService) RunDue(ctx context.Context) []*models.MaintenanceRun {
+	now := s.clock.Now()
+	return s.runWhere(ctx, func(task maintenanceTask, settings models.MaintenanceTaskSettings) bool {
+		last, ok := s.lastRun[task.name]
+		if !ok {
+			last = s.started
+		}
+		return settings.Enabled && now.Sub(last) >= settings.Interval()
+	})
+}
+
+// runWhere runs, in order, the tasks selected by the given function.
+func (s *MaintenanceService) runWhere(ctx context.Context, selected func(maintenanceTask, models.MaintenanceTaskSettings) bool) []*models.MaintenanceRun {
+	var tasks []maintenanceTask
+	s.mu.Lock()
+	for _, task := range s.tasks {
+		if selected(task, s.settingsFor(task)) {
+			tasks = append(tasks, task)
+		}
+	}
+	s.mu.Unlock()
+
+	runs := make([]*models.MaintenanceRun, 0, len(tasks))
+	for _, task := range tasks {
+		runs = append(runs, s.run(ctx, task))
+	}
+	return runs
+}
+
+// run runs a task and logs its outcome.
+func (s *MaintenanceService) run(ctx context.Context, task maintenanceTask) *models.MaintenanceRun {
+	s.mu.Lock()
+	settings := s.settingsFor(task)
+	s.mu.Unlock()
+
+	started := s.clock.Now()
+	count, err := task.run(ctx, settings.BatchSize, settings.DryRun)
+
+	run := &models.MaintenanceRun{
+		Task:       task.name,
+		Count:      count,
+		DryRun:     settings.DryRun,
+		StartedAt:  started,
+		DurationMS: s.clock.Now().Sub(started).Milliseconds(),
+	}
+
+	s.mu.Lock()
+	s.lastRun[task.name] = started
+	s.mu.Unlock()
+
+	if err != nil {
+		run.Error = err.Error()
+		log.Error().
+			Err(err).
+			Str("task", task.name).
+			Str("category", constants.LogCategoryAdmin).
+			Msg("Maintenance task failed")
+	} else if count > 0 {
+		log.Info().
+			Str("task", task.name).
+			Int64("count", count).
+			Bool("dry_run", settings.DryRun).
+			Str("category", constants.LogCategoryAdmin).
+			Msg("Maintenance task completed")
+	}
+
+	return run
+}
+
+// find returns the registered task with the given name.
+func (s *MaintenanceService) find(name string) (maintenanceTask, bool) {
+	for _, task := range s.tasks {
+		if task.name == name {
+			return task, true
+		}
+	}
+	return maintenanceTask{}, false
+}
+
+// settingsFor returns the current settings of a task. The caller must hold mu.
+func (s *MaintenanceService) settingsFor(task maintenanceTask) models.MaintenanceTaskSettings {
+	if settings, ok := s.settings[task.name]; ok {
+		return settings
+	}
+	return task.defaults
+}
+
+// info describes a task with its current settings. The caller must hold mu.
+func (s *MaintenanceService) info(task maintenanceTask) models.MaintenanceTask {
+	info := models.MaintenanceTask{
+		Name:        task.name,
+		Description: task.description,
+		Batched:     task.batched,
+		Settings:    s.settingsFor(task),
+	}
+	if last, ok := s.lastRun[task.name]; ok {
+		info.LastRunAt = &last
+	}
+	return info
+}
+
+// batchCount returns how many of count items a run with the given batch size processes.
+func batchCount(count int64, batchSize int) int64 {
+	if batchSize > 0 && count > int64(batchSize) {
+		return int64(batchSize)
+	}
+	return count
+}
@@ -0,0 +1,185 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/yasinhessnawi1/Hideme_Backend/internal/constants"
+	"github.com/yasinhessnawi1/Hideme_Backend/internal/models"
+	"github.com/yasinhessnawi1/Hideme_Backend/internal/testsupport/fakeclock"
+	"github.com/yasinhessnawi1/Hideme_Backend/internal/utils"
+)
+
+func TestMaintenanceService(t *testing.T) {
+	var order []string
+	svc := NewMaintenanceService(nil)
+	svc.Register("sessions", "Delete expired sessions", func(ctx context.Context) (int64, error) {
+		order = append(order, "sessions")
+		return 3, nil
+	})
+	svc.Register("reports", "Send due reports", func(ctx context.Context) (int64, error) {
+		order = append(order, "reports")
+		return 0, errors.New("smtp unavailable")
+	})
+	svc.Register("uptime", "Prune uptime", func(ctx context.Context) (int64, error) {
+		order = append(order, "uptime")
+		return 1, nil
+	})
+
+	t.Run("Tasks", func(t *testing.T) {
+		defaults := models.MaintenanceTaskSettings{Enabled: true, IntervalSeconds: 3600}
+		tasks := svc.Tasks()
+		require.Len(t, tasks, 3)
+		for i, name := range []string{"sessions", "reports", "uptime"} {
+			defaults.Task = name
+			assert.Equal(t, name, tasks[i].Name)
+			assert.Equal(t, defaults, tasks[i].Settings)
+			assert.False(t, tasks[i].Batched)
+		}
+		assert.Equal(t, "Delete expired sessions", tasks[0].Description)
+	})
+
+	t.Run("RunAll continues after a failing task", func(t *testing.T) {
+		order = nil
+		runs := svc.RunAll(context.Background())
+
+		assert.Equal(t, []string{"sessions", "reports", "uptime"}, order)
+		require.Len(t, runs, 3)
+		assert.Equal(t, int64(3), runs[0].Count)
+		assert.Empty(t, runs[0].Error)
+		assert.Equal(t, "smtp unavailable", runs[1].Error)
+		assert.Equal(t, "uptime", runs[2].Task)
+	})
+
+	t.Run("Run single task", func(t *testing.T) {
+		order = nil
+		run, err := svc.Run(context.Background(), "uptime")
+
+		require.NoError(t, err)
+		assert.Equal(t, []string{"uptime"}, order)
+		assert.Equal(t, int64(1), run.Count)
+	})
+
+	t.Run("Unknown task", func(t *testing.T) {
+		_, err := svc.Run(context.Background(), "vacuum")
+
+		assert.ErrorIs(t, err, utils.ErrNotFound)
+	})
+}
+
+// memoryMaintenanceSettingsRepository is an in-memory MaintenanceSettingsRepository.
+type memoryMaintenanceSettingsRepository struct {
+	settings map[string]models.MaintenanceTaskSettings
+}
+
+func (r *memoryMaintenanceSettingsRepository) List(ctx context.Context) ([]*models.MaintenanceTaskSettings, error) {
+	var settings []*models.MaintenanceTaskSettings
+	for _, s := range r.settings {
+		s := s
+		settings = append(settings, &s)
+	}
+	return settings, nil
+}
+
+func (r *memoryMaintenanceSettingsRepository) Upsert(ctx context.Context, settings *models.MaintenanceTaskSettings) error {
+	r.settings[settings.Task] = *settings
+	return nil
+}
+
+func TestMaintenanceService_Settings(t *testing.T) {
+	type call struct {
+		batchSize int
+		dryRun    bool
+	}
+	var calls []call
+	clock := fakeclock.New(time.Date(2026, 10, 16, 12, 0, 0, 0, time.UTC))
+	repo := &memoryMaintenanceSettingsRepository{settings: map[string]models.MaintenanceTaskSettings{}}
+
+	newService := func() *MaintenanceService {
+		svc := NewMaintenanceService(repo)
+		svc.SetClock(clock)
+		svc.RegisterBatch("sessions", "Delete expired sessions", 0, func(ctx context.Context, batchSize int, dryRun bool) (int64, error) {
+			calls = append(calls, call{batchSize, dryRun})
+			return 2, nil
+		})
+		svc.Register("logs", "Rotate logs", func(ctx context.Context) (int64, error) {
+			calls = append(calls, call{})
+			return 0, nil
+		})
+		return svc
+	}
+	svc := newService()
+
+	t.Run("Batch size and dry run only for batched tasks", func(t *testing.T) {
+		batchSize := 10
+		_, err := svc.UpdateSettings(context.Background(), 1, "logs", &models.MaintenanceTaskSettingsUpdate{BatchSize: &batchSize})
+
+		assert.ErrorIs(t, err, utils.ErrValidation)
+		assert.Empty(t, repo.settings)
+
+		_, err = svc.UpdateSettings(context.Background(), 1, "vacuum", &models.MaintenanceTaskSettingsUpdate{})
+		assert.ErrorIs(t, err, utils.ErrNotFound)
+	})
+
+	t.Run("Update stores the settings", func(t *testing.T) {
+		batchSize, dryRun, interval := 100, true, 900
+		task, err := svc.UpdateSettings(context.Background(), 1, "sessions", &models.MaintenanceTaskSettingsUpdate{
+			BatchSize:       &batchSize,
+			DryRun:          &dryRun,
+			IntervalSeconds: &interval,
+		})
+
+		require.NoError(t, err)
+		assert.True(t, task.Batched)
+		assert.True(t, task.Settings.Enabled)
+		assert.Equal(t, 100, task.Settings.BatchSize)
+		assert.Equal(t, int64(1), *task.Settings.UpdatedBy)
+		assert.Equal(t, task.Settings, repo.settings["sessions"])
+	})
+
+	t.Run("Run uses the settings", func(t *testing.T) {
+		calls = nil
+		run, err := svc.Run(context.Background(), "sessions")
+
+		require.NoError(t, err)
+		assert.Equal(t, []call{{100, true}}, calls)
+		assert.True(t, run.DryRun)
+		now := clock.Now()
+		assert.Equal(t, &now, svc.Tasks()[0].LastRunAt)
+	})
+
+	t.Run("RunDue runs enabled tasks whose interval has passed", func(t *testing.T) {
+		disabled := false
+		_, err := svc.UpdateSettings(context.Background(), 1, "logs", &models.MaintenanceTaskSettingsUpdate{Enabled: &disabled})
+		require.NoError(t, err)
+
+		calls = nil
+		clock.Advance(10 * time.Minute)
+		assert.Empty(t, svc.RunDue(context.Background()))
+
+		clock.Advance(5 * time.Minute)
+		runs := svc.RunDue(context.Background())
+		require.Len(t, runs, 1)
+		assert.Equal(t, "sessions", runs[0].Task)
+
+		clock.Advance(constants.DBMaintenanceInterval)
+		runs = svc.RunDue(context.Background())
+		require.Len(t, runs, 1)
+		assert.Equal(t, "sessions", runs[0].Task)
+	})
+
+	t.Run("LoadSettings restores stored settings", func(t *testing.T) {
+		restarted := newService()
+		require.NoError(t, restarted.LoadSettings(context.Background()))
+
+		tasks := restarted.Tasks()
+		assert.Equal(t, 100, tasks[0].Settings.BatchSize)
+		assert.Equal(t, 900, tasks[0].Settings.IntervalSeconds)
+		assert.False(t, tasks[1].Settings.Enabled)
+	})
+}
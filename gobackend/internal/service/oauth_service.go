@@ -0,0 +1,227 @@
+// Package service provides business logic implementations.
+package service
+
+import (
+	"context"
+	"crypto/hmac"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/rs/zerolog/log"
+
+	"github.com/yasinhessnawi1/Hideme_Backend/internal/auth/oauth"
+	"github.com/yasinhessnawi1/Hideme_Backend/internal/config"
+	"github.com/yasinhessnawi1/Hideme_Backend/internal/constants"
+	"github.com/yasinhessnawi1/Hideme_Backend/internal/models"
+	"github.com/yasinhessnawi1/Hideme_Backend/internal/utils"
+	"github.com/yasinhessnawi1/Hideme_Backend/internal/utils/clock"
+)
+
+// oauthProviderNames lists the supported sign-in providers.
+var oauthProviderNames = []string{
+	constants.OAuthProviderGoogle,
+	constants.OAuthProviderMicrosoft,
+}
+
+// OAuthProvider is an OpenID Connect provider users can sign in with.
+type OAuthProvider interface {
+	// AuthCodeURL returns the sign-in page the user is sent to.
+	//
+	// Parameters:
+	//   - flow: The sign-in flow, whose state, nonce and code challenge are passed on
+	//   - redirectURI: Where the provider sends the user back to
+	//
+	// Returns:
+	//   - The URL of the sign-in page
+	AuthCodeURL(flow *oauth.Flow, redirectURI string) string
+
+	// Exchange exchanges an authorization code for a verified ID token.
+	//
+	// Parameters:
+	//   - ctx: Context for cancellation control
+	//   - code: The authorization code
+	//   - flow: The sign-in flow the code was issued for
+	//   - redirectURI: The redirect URI the code was issued for
+	//
+	// Returns:
+	//   - The identity of the user
+	//   - oauth.ErrCodeRejected or oauth.ErrInvalidIDToken if the code or token is not accepted
+	Exchange(ctx context.Context, code string, flow *oauth.Flow, redirectURI string) (*oauth.Identity, error)
+}
+
+// IdentityAuthenticator signs in the users that providers vouch for.
+type IdentityAuthenticator interface {
+	// AuthenticateIdentity signs in the user of a provider account, linking or creating it on first use.
+	AuthenticateIdentity(ctx context.Context, identity *oauth.Identity, creds *models.UserCredentials) (*models.User, string, string, error)
+}
+
+// NewOAuthProviders creates the sign-in providers that have an OAuth client configured.
+//
+// Parameters:
+//   - settings: The sign-in configuration
+//   - client: The HTTP client for requests to the providers; http.DefaultClient if nil
+//
+// Returns:
+//   - The configured providers by name
+func NewOAuthProviders(settings *config.OAuthSettings, client *http.Client) map[string]OAuthProvider {
+	if client == nil {
+		client = http.DefaultClient
+	}
+
+	providers := make(map[string]OAuthProvider)
+	if settings.GoogleClientID != "" && settings.GoogleClientSecret != "" {
+		providers[constants.OAuthProviderGoogle] = oauth.NewGoogleProvider(settings.GoogleClientID, settings.GoogleClientSecret, client)
+	}
+	if settings.MicrosoftClientID != "" && settings.MicrosoftClientSecret != "" {
+		providers[constants.OAuthProviderMicrosoft] = oauth.NewMicrosoftProvider(settings.MicrosoftClientID, settings.MicrosoftClientSecret, settings.MicrosoftTenant, client)
+	}
+
+	return providers
+}
+
+// OAuthService signs users in with OpenID Connect providers, such as Google and Microsoft.
+//
+// Start sends the user to the provider and returns a signed flow the handler keeps in a
+// cookie; the provider sends the user back to the callback, which checks the state against
+// that cookie, exchanges the code and signs in the user the ID token names. The flow never
+// touches the database, so abandoned sign-ins leave nothing behind.
+type OAuthService struct {
+	providers     map[string]OAuthProvider
+	authenticator IdentityAuthenticator
+	settings      *config.OAuthSettings
+	flowKey       []byte
+	clock         clock.Clock
+}
+
+// NewOAuthService creates a new OAuthService.
+//
+// Parameters:
+//   - providers: The configured providers by name
+//   - authenticator: Signs in the users the providers vouch for
+//   - settings: The sign-in configuration
+//   - flowKey: The secret the sign-in flows are signed with
+//
+// Returns:
+//   - A configured OAuthService
+func NewOAuthService(
+	providers map[string]OAuthProvider,
+	authenticator IdentityAuthenticator,
+	settings *config.OAuthSettings,
+	flowKey string,
+) *OAuthService {
+	return &OAuthService{
+		providers:     providers,
+		authenticator: authenticator,
+		settings:      settings,
+		flowKey:       []byte(flowKey),
+	}
+}
+
+// SetClock sets the clock sign-in flows are started and expired by, so tests can check a
+// flow at the instant it expires.
+func (s *OAuthService) SetClock(c clock.Clock) {
+	s.clock = c
+}
+
+// now returns the current time of the service's clock.
+func (s *OAuthService) now() time.Time {
+	return clock.Or(s.clock).Now()
+}
+
+// Start starts a sign-in with a provider.
+//
+// Parameters:
+//   - provider: The sign-in provider
+//
+// Returns:
+//   - The sign-in page to send the user to, and the flow to keep in the user's browser
+//   - ValidationError if the provider is unknown, BadRequestError if it isn't configured
+func (s *OAuthService) Start(provider string) (*models.OAuthAuthorization, error) {
+	p, err := s.provider(provider)
+	if err != nil {
+		return nil, err
+	}
+
+	expiresAt := s.now().Add(constants.OAuthFlowTTL)
+	flow, err := oauth.NewFlow(provider, expiresAt)
+	if err != nil {
+		return nil, err
+	}
+
+	return &models.OAuthAuthorization{
+		AuthorizationURL: p.AuthCodeURL(flow, s.redirectURI(provider)),
+		Flow:             flow.Encode(s.flowKey),
+		ExpiresAt:        expiresAt,
+	}, nil
+}
+
+// Callback completes a sign-in with the code the provider issued.
+//
+// Parameters:
+//   - ctx: Context for the operation
+//   - provider: The sign-in provider named in the callback URL
+//   - req: The query parameters the provider sent the user back with
+//   - flow: The signed flow from the user's cookie
+//   - creds: The client details used for risk scoring
+//
+// Returns:
+//   - The signed-in user, their access token and their refresh token
+//   - BadRequestError if the flow is missing, forged or expired, was started in another
+//     browser or for another provider, or the provider refused the sign-in or the code
+//   - The errors of AuthenticateIdentity if the user cannot be signed in
+func (s *OAuthService) Callback(ctx context.Context, provider string, req *models.OAuthCallbackRequest, flow string, creds *models.UserCredentials) (*models.User, string, string, error) {
+	p, err := s.provider(provider)
+	if err != nil {
+		return nil, "", "", err
+	}
+
+	started, err := oauth.DecodeFlow(s.flowKey, flow, s.now())
+	if err != nil || started.Provider != provider || !hmac.Equal([]byte(started.State), []byte(req.State)) {
+		return nil, "", "", utils.NewBadRequestError(constants.MsgOAuthStateInvalid)
+	}
+
+	if req.Error != "" {
+		return nil, "", "", utils.NewBadRequestError(constants.MsgOAuthDenied)
+	}
+
+	identity, err := p.Exchange(ctx, req.Code, started, s.redirectURI(provider))
+	if err != nil {
+		if errors.Is(err, oauth.ErrCodeRejected) || errors.Is(err, oauth.ErrInvalidIDToken) {
+			log.Warn().Err(err).Str("provider", provider).Msg("Sign-in with provider rejected")
+			return nil, "", "", utils.NewBadRequestError(constants.MsgOAuthStateInvalid)
+		}
+		return nil, "", "", fmt.Errorf("failed to exchange authorization code: %w", err)
+	}
+
+	return s.authenticator.AuthenticateIdentity(ctx, identity, creds)
+}
+
+// provider returns a configured provider by name.
+func (s *OAuthService) provider(name string) (OAuthProvider, error) {
+	if !isOAuthProvider(name) {
+		return nil, utils.NewValidationError(constants.ParamProvider, constants.MsgOAuthProviderUnknown)
+	}
+	p, ok := s.providers[name]
+	if !ok || s.settings.CallbackBaseURL == "" {
+		return nil, utils.NewBadRequestError(constants.MsgOAuthProviderNotConfigured)
+	}
+	return p, nil
+}
+
+// redirectURI returns the callback URL a provider sends users back to.
+func (s *OAuthService) redirectURI(provider string) string {
+	return strings.TrimSuffix(s.settings.CallbackBaseURL, "/") + fmt.Sprintf(constants.OAuthCallbackPathFormat, provider)
+}
+
+// isOAuthProvider reports whether a sign-in provider name is supported.
+func isOAuthProvider(name string) bool {
+	for _, supported := range oauthProviderNames {
+		if name == supported {
+			return true
+		}
+	}
+	return false
+}
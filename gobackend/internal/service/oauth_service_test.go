@@ -0,0 +1,374 @@
+// oauth_service_test.go
+package service
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/yasinhessnawi1/Hideme_Backend/internal/auth"
+	"github.com/yasinhessnawi1/Hideme_Backend/internal/auth/oauth"
+	"github.com/yasinhessnawi1/Hideme_Backend/internal/config"
+	"github.com/yasinhessnawi1/Hideme_Backend/internal/constants"
+	"github.com/yasinhessnawi1/Hideme_Backend/internal/models"
+	"github.com/yasinhessnawi1/Hideme_Backend/internal/testsupport/fakeclock"
+	"github.com/yasinhessnawi1/Hideme_Backend/internal/testsupport/fixtures"
+	"github.com/yasinhessnawi1/Hideme_Backend/internal/utils"
+)
+
+// MockUserIdentityRepository keeps linked provider accounts in memory.
+type MockUserIdentityRepository struct {
+	identities map[string]*models.UserIdentity
+	nextID     int64
+}
+
+func NewMockUserIdentityRepository() *MockUserIdentityRepository {
+	return &MockUserIdentityRepository{
+		identities: make(map[string]*models.UserIdentity),
+		nextID:     1,
+	}
+}
+
+func (m *MockUserIdentityRepository) Create(ctx context.Context, identity *models.UserIdentity) error {
+	key := identity.Provider + "/" + identity.Subject
+	if _, ok := m.identities[key]; ok {
+		return utils.NewDuplicateError("Identity", "provider", identity.Provider)
+	}
+	identity.ID = m.nextID
+	m.nextID++
+	m.identities[key] = identity
+	return nil
+}
+
+func (m *MockUserIdentityRepository) GetByProviderSubject(ctx context.Context, provider, subject string) (*models.UserIdentity, error) {
+	identity, ok := m.identities[provider+"/"+subject]
+	if !ok {
+		return nil, utils.NewNotFoundError("Identity", provider)
+	}
+	return identity, nil
+}
+
+// uniqueUserRepository rejects duplicate usernames like the database does.
+type uniqueUserRepository struct {
+	*MockUserRepository
+}
+
+func (m *uniqueUserRepository) Create(ctx context.Context, user *models.User) error {
+	if _, ok := m.usersByUsername[user.Username]; ok {
+		return utils.NewDuplicateError("User", "username", user.Username)
+	}
+	return m.MockUserRepository.Create(ctx, user)
+}
+
+// MockOAuthProvider returns a fixed identity for any code.
+type MockOAuthProvider struct {
+	identity    *oauth.Identity
+	err         error
+	redirectURI string
+	flow        *oauth.Flow
+}
+
+func (m *MockOAuthProvider) AuthCodeURL(flow *oauth.Flow, redirectURI string) string {
+	return "https://provider.example.com/authorize?" + url.Values{
+		"state":        {flow.State},
+		"redirect_uri": {redirectURI},
+	}.Encode()
+}
+
+func (m *MockOAuthProvider) Exchange(ctx context.Context, code string, flow *oauth.Flow, redirectURI string) (*oauth.Identity, error) {
+	m.flow = flow
+	m.redirectURI = redirectURI
+	if m.err != nil {
+		return nil, m.err
+	}
+	return m.identity, nil
+}
+
+// MockIdentityAuthenticator records the identities it signs in.
+type MockIdentityAuthenticator struct {
+	identities []*oauth.Identity
+}
+
+func (m *MockIdentityAuthenticator) AuthenticateIdentity(ctx context.Context, identity *oauth.Identity, creds *models.UserCredentials) (*models.User, string, string, error) {
+	m.identities = append(m.identities, identity)
+	return &models.User{ID: 1, Email: identity.Email}, "access", "refresh", nil
+}
+
+func statusCode(err error) int {
+	var appErr *utils.AppError
+	if errors.As(err, &appErr) {
+		return appErr.StatusCode
+	}
+	return 0
+}
+
+func TestAuthService_AuthenticateIdentity(t *testing.T) {
+	// Setup
+	userRepo := &uniqueUserRepository{NewMockUserRepository()}
+	sessionRepo := NewMockSessionRepository()
+	identityRepo := NewMockUserIdentityRepository()
+	jwtService := auth.NewJWTService(&config.JWTSettings{
+		Secret:        "test-secret",
+		Expiry:        15 * time.Minute,
+		RefreshExpiry: 7 * 24 * time.Hour,
+		Issuer:        "test-issuer",
+	})
+	passwordCfg := &auth.PasswordConfig{
+		Memory:      16 * 1024, // Use minimal settings for faster tests
+		Iterations:  1,
+		Parallelism: 1,
+		SaltLength:  16,
+		KeyLength:   32,
+	}
+
+	service := NewAuthService(userRepo, sessionRepo, NewMockAPIKeyRepository(), jwtService, passwordCfg, &config.APIKeySettings{})
+	service.SetIdentityRepository(identityRepo)
+
+	existing := models.NewUser("testuser", "test@example.com", constants.RoleUser)
+	if err := userRepo.Create(context.Background(), existing); err != nil {
+		t.Fatalf("Failed to create test user: %v", err)
+	}
+
+	creds := &models.UserCredentials{ClientIP: "127.0.0.1", UserAgent: "test"}
+
+	t.Run("Creates a user on first sign-in", func(t *testing.T) {
+		identity := &oauth.Identity{Provider: constants.OAuthProviderGoogle, Subject: "g-1", Email: "new.user+tag@example.com", EmailVerified: true}
+
+		user, accessToken, refreshToken, err := service.AuthenticateIdentity(context.Background(), identity, creds)
+		if err != nil {
+			t.Fatalf("AuthenticateIdentity() error = %v", err)
+		}
+		if user.Username != "new.usertag" || user.Email != identity.Email {
+			t.Errorf("AuthenticateIdentity() user = %s <%s>", user.Username, user.Email)
+		}
+		if accessToken == "" || refreshToken == "" {
+			t.Error("AuthenticateIdentity() returned empty tokens")
+		}
+		if len(sessionRepo.sessions) != 1 {
+			t.Errorf("Expected 1 session, got %d", len(sessionRepo.sessions))
+		}
+
+		// Signing in again finds the linked user
+		again, _, _, err := service.AuthenticateIdentity(context.Background(), identity, creds)
+		if err != nil {
+			t.Fatalf("AuthenticateIdentity() second sign-in error = %v", err)
+		}
+		if again.ID != user.ID {
+			t.Errorf("AuthenticateIdentity() second sign-in user = %d, want %d", again.ID, user.ID)
+		}
+	})
+
+	t.Run("Links a verified email to the existing user", func(t *testing.T) {
+		identity := &oauth.Identity{Provider: constants.OAuthProviderMicrosoft, Subject: "m-1", Email: existing.Email, EmailVerified: true}
+
+		user, _, _, err := service.AuthenticateIdentity(context.Background(), identity, creds)
+		if err != nil {
+			t.Fatalf("AuthenticateIdentity() error = %v", err)
+		}
+		if user.ID != existing.ID {
+			t.Errorf("AuthenticateIdentity() user = %d, want %d", user.ID, existing.ID)
+		}
+		if _, err := identityRepo.GetByProviderSubject(context.Background(), constants.OAuthProviderMicrosoft, "m-1"); err != nil {
+			t.Errorf("Expected identity to be linked: %v", err)
+		}
+	})
+
+	t.Run("Adds a suffix to a taken username", func(t *testing.T) {
+		identity := &oauth.Identity{Provider: constants.OAuthProviderGoogle, Subject: "g-2", Email: "testuser@other.example.com", EmailVerified: true}
+
+		user, _, _, err := service.AuthenticateIdentity(context.Background(), identity, creds)
+		if err != nil {
+			t.Fatalf("AuthenticateIdentity() error = %v", err)
+		}
+		if user.ID == existing.ID || !strings.HasPrefix(user.Username, "testuser-") {
+			t.Errorf("AuthenticateIdentity() username = %s, want testuser-<suffix>", user.Username)
+		}
+	})
+
+	t.Run("Refuses an unverified email of an existing user", func(t *testing.T) {
+		identity := &oauth.Identity{Provider: constants.OAuthProviderGoogle, Subject: "g-3", Email: existing.Email}
+
+		_, _, _, err := service.AuthenticateIdentity(context.Background(), identity, creds)
+		if statusCode(err) != http.StatusConflict {
+			t.Errorf("AuthenticateIdentity() error = %v, want conflict", err)
+		}
+		if _, err := identityRepo.GetByProviderSubject(context.Background(), constants.OAuthProviderGoogle, "g-3"); !utils.IsNotFoundError(err) {
+			t.Error("Expected identity not to be linked")
+		}
+	})
+
+	t.Run("Refuses a new user without a verified email", func(t *testing.T) {
+		identity := &oauth.Identity{Provider: constants.OAuthProviderGoogle, Subject: "g-4", Email: "unknown@example.com"}
+
+		_, _, _, err := service.AuthenticateIdentity(context.Background(), identity, creds)
+		if statusCode(err) != http.StatusForbidden {
+			t.Errorf("AuthenticateIdentity() error = %v, want forbidden", err)
+		}
+	})
+
+	t.Run("Refuses a disabled user", func(t *testing.T) {
+		disabledAt := time.Now()
+		existing.DisabledAt = &disabledAt
+		defer func() { existing.DisabledAt = nil }()
+
+		identity := &oauth.Identity{Provider: constants.OAuthProviderMicrosoft, Subject: "m-1", Email: existing.Email, EmailVerified: true}
+
+		_, _, _, err := service.AuthenticateIdentity(context.Background(), identity, creds)
+		if statusCode(err) != http.StatusForbidden {
+			t.Errorf("AuthenticateIdentity() error = %v, want forbidden", err)
+		}
+	})
+}
+
+func TestUsernameFromEmail(t *testing.T) {
+	tests := []struct {
+		email string
+		want  string
+	}{
+		{"ola.nordmann@example.com", "ola.nordmann"},
+		{"kari+hideme@example.com", "karihideme"},
+		{"jø@example.com", "userj"},
+		{strings.Repeat("a", 60) + "@example.com", strings.Repeat("a", constants.OAuthUsernameMaxBase)},
+	}
+
+	for _, tt := range tests {
+		if got := usernameFromEmail(tt.email); got != tt.want {
+			t.Errorf("usernameFromEmail(%q) = %q, want %q", tt.email, got, tt.want)
+		}
+	}
+}
+
+func TestOAuthService_StartAndCallback(t *testing.T) {
+	settings := &config.OAuthSettings{CallbackBaseURL: "https://api.example.com/"}
+	newService := func() (*OAuthService, *MockOAuthProvider, *MockIdentityAuthenticator) {
+		provider := &MockOAuthProvider{
+			identity: &oauth.Identity{Provider: constants.OAuthProviderGoogle, Subject: "g-1", Email: "ola@example.com", EmailVerified: true},
+		}
+		authenticator := &MockIdentityAuthenticator{}
+		service := NewOAuthService(map[string]OAuthProvider{constants.OAuthProviderGoogle: provider}, authenticator, settings, "test-secret")
+		return service, provider, authenticator
+	}
+
+	// start returns the encoded flow and its state
+	start := func(t *testing.T, service *OAuthService) (string, string) {
+		t.Helper()
+		authorization, err := service.Start(constants.OAuthProviderGoogle)
+		if err != nil {
+			t.Fatalf("Start() error = %v", err)
+		}
+		authURL, err := url.Parse(authorization.AuthorizationURL)
+		if err != nil {
+			t.Fatalf("Start() returned invalid URL: %v", err)
+		}
+		return authorization.Flow, authURL.Query().Get("state")
+	}
+
+	t.Run("Success", func(t *testing.T) {
+		service, provider, authenticator := newService()
+		flow, state := start(t, service)
+
+		user, accessToken, _, err := service.Callback(context.Background(), constants.OAuthProviderGoogle,
+			&models.OAuthCallbackRequest{Code: "code", State: state}, flow, &models.UserCredentials{})
+		if err != nil {
+			t.Fatalf("Callback() error = %v", err)
+		}
+		if user.Email != "ola@example.com" || accessToken != "access" {
+			t.Errorf("Callback() = %v, %s", user, accessToken)
+		}
+		if len(authenticator.identities) != 1 {
+			t.Errorf("Expected 1 sign-in, got %d", len(authenticator.identities))
+		}
+		if provider.redirectURI != "https://api.example.com/api/auth/oauth/google/callback" {
+			t.Errorf("Exchange() redirect URI = %s", provider.redirectURI)
+		}
+		if provider.flow.State != state {
+			t.Error("Exchange() was not given the started flow")
+		}
+	})
+
+	t.Run("Rejected callbacks", func(t *testing.T) {
+		service, provider, authenticator := newService()
+		clock := fakeclock.New(fixtures.Time)
+		service.SetClock(clock)
+		flow, state := start(t, service)
+
+		microsoft, err := oauth.NewFlow(constants.OAuthProviderMicrosoft, time.Now().Add(time.Minute))
+		if err != nil {
+			t.Fatalf("NewFlow() error = %v", err)
+		}
+
+		tests := []struct {
+			name  string
+			req   *models.OAuthCallbackRequest
+			flow  string
+			now   time.Time
+			err   error
+			valid bool
+		}{
+			{name: "Missing flow", req: &models.OAuthCallbackRequest{Code: "code", State: state}},
+			{name: "Wrong state", req: &models.OAuthCallbackRequest{Code: "code", State: "forged"}, flow: flow},
+			{name: "Forged flow", req: &models.OAuthCallbackRequest{Code: "code", State: state}, flow: flow + "x"},
+			{name: "Expired flow", req: &models.OAuthCallbackRequest{Code: "code", State: state}, flow: flow, now: fixtures.Time.Add(constants.OAuthFlowTTL + time.Second)},
+			{name: "Flow of another provider", req: &models.OAuthCallbackRequest{Code: "code", State: microsoft.State}, flow: microsoft.Encode([]byte("test-secret"))},
+			{name: "Denied by the user", req: &models.OAuthCallbackRequest{State: state, Error: "access_denied"}, flow: flow},
+			{name: "Code rejected", req: &models.OAuthCallbackRequest{Code: "code", State: state}, flow: flow, err: oauth.ErrCodeRejected},
+			{name: "Invalid ID token", req: &models.OAuthCallbackRequest{Code: "code", State: state}, flow: flow, err: oauth.ErrInvalidIDToken},
+		}
+
+		for _, tt := range tests {
+			t.Run(tt.name, func(t *testing.T) {
+				clock.Set(fixtures.Time)
+				if !tt.now.IsZero() {
+					clock.Set(tt.now)
+				}
+				provider.err = tt.err
+
+				_, _, _, err := service.Callback(context.Background(), constants.OAuthProviderGoogle, tt.req, tt.flow, &models.UserCredentials{})
+				if statusCode(err) != http.StatusBadRequest {
+					t.Errorf("Callback() error = %v, want bad request", err)
+				}
+			})
+		}
+
+		if len(authenticator.identities) != 0 {
+			t.Errorf("Expected no sign-ins, got %d", len(authenticator.identities))
+		}
+	})
+
+	t.Run("Unknown provider", func(t *testing.T) {
+		service, _, _ := newService()
+
+		if _, err := service.Start("github"); !utils.IsValidationError(err) {
+			t.Errorf("Start() error = %v, want validation error", err)
+		}
+	})
+
+	t.Run("Provider not configured", func(t *testing.T) {
+		service, _, _ := newService()
+
+		if _, err := service.Start(constants.OAuthProviderMicrosoft); statusCode(err) != http.StatusBadRequest {
+			t.Errorf("Start() error = %v, want bad request", err)
+		}
+
+		unconfigured := NewOAuthService(service.providers, service.authenticator, &config.OAuthSettings{}, "test-secret")
+		if _, err := unconfigured.Start(constants.OAuthProviderGoogle); statusCode(err) != http.StatusBadRequest {
+			t.Errorf("Start() without callback base URL error = %v, want bad request", err)
+		}
+	})
+
+	t.Run("Provider outage", func(t *testing.T) {
+		service, provider, _ := newService()
+		flow, state := start(t, service)
+		provider.err = errors.New("connection refused")
+
+		_, _, _, err := service.Callback(context.Background(), constants.OAuthProviderGoogle,
+			&models.OAuthCallbackRequest{Code: "code", State: state}, flow, &models.UserCredentials{})
+		if err == nil || statusCode(err) == http.StatusBadRequest {
+			t.Errorf("Callback() error = %v, want server error", err)
+		}
+	})
+}
@@ -0,0 +1,89 @@
+// Package service provides business logic implementations.
+package service
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/yasinhessnawi1/Hideme_Backend/internal/config"
+	"github.com/yasinhessnawi1/Hideme_Backend/internal/constants"
+	"github.com/yasinhessnawi1/Hideme_Backend/internal/models"
+)
+
+// PermissionService tells callers what they may do: the scopes and capabilities their
+// role and authentication give them, the policies applying to all users and the optional
+// features the deployment offers. The frontend reads them to render its affordances.
+type PermissionService struct {
+	config       *config.AppConfig
+	policySource APIKeyPolicySource
+}
+
+// NewPermissionService creates a new PermissionService.
+//
+// Parameters:
+//   - cfg: The application configuration the policies and features are read from
+//   - policySource: The source of the API key expiry policy
+//
+// Returns:
+//   - A configured PermissionService
+func NewPermissionService(cfg *config.AppConfig, policySource APIKeyPolicySource) *PermissionService {
+	return &PermissionService{
+		config:       cfg,
+		policySource: policySource,
+	}
+}
+
+// GetPermissions returns the effective permissions of a caller.
+//
+// Parameters:
+//   - ctx: Context for the operation
+//   - userID: The ID of the caller
+//   - role: The role of the caller
+//   - apiKey: Whether the caller authenticated with an API key
+//   - keyScopes: The scopes of the API key; empty for a key with full access
+//
+// Returns:
+//   - The permissions of the caller
+//   - An error if the API key policy could not be read
+func (s *PermissionService) GetPermissions(ctx context.Context, userID int64, role string, apiKey bool, keyScopes []string) (*models.Permissions, error) {
+	permissions := models.NewPermissions(userID, role, apiKey, keyScopes)
+
+	policy, err := s.policySource.GetPolicy(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get API key policy: %w", err)
+	}
+
+	permissions.Policies = models.PermissionPolicies{
+		APIKeyMaxLifetimeDays: policy.MaxLifetimeDays,
+		MaxDocuments:          s.config.UploadLimits.MaxDocuments,
+		MaxPages:              s.config.UploadLimits.MaxPages,
+		MaxUploadBytes:        s.config.UploadLimits.MaxBytes,
+	}
+	if s.config.DocumentArchive.AfterDays > 0 {
+		permissions.Policies.DocumentArchiveAfterDays = s.config.DocumentArchive.AfterDays
+	}
+	if permissions.HasCapability(constants.CapabilityAdmin) {
+		permissions.Policies.ApprovalRequiredActions = s.config.Approvals.RequiredActions
+	}
+
+	permissions.Features = s.Features()
+
+	return permissions, nil
+}
+
+// Features returns the optional features the deployment offers, by name.
+//
+// Returns:
+//   - Whether each feature, such as constants.FeatureDriveGoogle, is enabled
+func (s *PermissionService) Features() map[string]bool {
+	oauth := s.config.OAuth
+	drives := s.config.Drives
+	return map[string]bool{
+		constants.FeatureOAuthGoogle:     oauth.GoogleClientID != "" && oauth.GoogleClientSecret != "",
+		constants.FeatureOAuthMicrosoft:  oauth.MicrosoftClientID != "" && oauth.MicrosoftClientSecret != "",
+		constants.FeatureDriveGoogle:     drives.GoogleClientID != "" && drives.GoogleClientSecret != "",
+		constants.FeatureDriveMicrosoft:  drives.MicrosoftClientID != "" && drives.MicrosoftClientSecret != "",
+		constants.FeatureDocumentArchive: s.config.DocumentArchive.AfterDays > 0,
+		constants.FeaturePIIScreening:    s.config.PIIScreening.Mode != constants.PIIScreeningOff,
+	}
+}
@@ -0,0 +1,101 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/yasinhessnawi1/Hideme_Backend/internal/config"
+	"github.com/yasinhessnawi1/Hideme_Backend/internal/constants"
+	"github.com/yasinhessnawi1/Hideme_Backend/internal/models"
+)
+
+// failingPolicySource fails to provide the API key expiry policy.
+type failingPolicySource struct{}
+
+func (failingPolicySource) GetPolicy(ctx context.Context) (*models.APIKeyPolicy, error) {
+	return nil, errors.New("database unavailable")
+}
+
+func newTestPermissionConfig() *config.AppConfig {
+	cfg := &config.AppConfig{}
+	cfg.UploadLimits = config.UploadLimitSettings{MaxDocuments: 100, MaxPages: 50, MaxBytes: 1 << 20}
+	cfg.DocumentArchive.AfterDays = 180
+	cfg.Approvals.RequiredActions = []string{"user_role"}
+	cfg.OAuth.GoogleClientID = "google-id"
+	cfg.OAuth.GoogleClientSecret = "google-secret"
+	cfg.Drives.MicrosoftClientID = "microsoft-id"
+	cfg.PIIScreening.Mode = constants.PIIScreeningOff
+	return cfg
+}
+
+func TestPermissionService_GetPermissions(t *testing.T) {
+	source := &MockPolicySource{policy: models.APIKeyPolicy{MaxLifetimeDays: 90}}
+	service := NewPermissionService(newTestPermissionConfig(), source)
+
+	t.Run("User", func(t *testing.T) {
+		permissions, err := service.GetPermissions(context.Background(), 1, constants.RoleUser, false, nil)
+		if err != nil {
+			t.Fatalf("GetPermissions() error = %v", err)
+		}
+
+		want := models.PermissionPolicies{
+			APIKeyMaxLifetimeDays:    90,
+			MaxDocuments:             100,
+			MaxPages:                 50,
+			MaxUploadBytes:           1 << 20,
+			DocumentArchiveAfterDays: 180,
+		}
+		if permissions.Policies.APIKeyMaxLifetimeDays != want.APIKeyMaxLifetimeDays ||
+			permissions.Policies.MaxDocuments != want.MaxDocuments ||
+			permissions.Policies.MaxPages != want.MaxPages ||
+			permissions.Policies.MaxUploadBytes != want.MaxUploadBytes ||
+			permissions.Policies.DocumentArchiveAfterDays != want.DocumentArchiveAfterDays {
+			t.Errorf("Policies = %+v, want %+v", permissions.Policies, want)
+		}
+		if permissions.Policies.ApprovalRequiredActions != nil {
+			t.Errorf("ApprovalRequiredActions = %v, want none for users", permissions.Policies.ApprovalRequiredActions)
+		}
+
+		wantFeatures := map[string]bool{
+			constants.FeatureOAuthGoogle:     true,
+			constants.FeatureOAuthMicrosoft:  false,
+			constants.FeatureDriveGoogle:     false,
+			constants.FeatureDriveMicrosoft:  false,
+			constants.FeatureDocumentArchive: true,
+			constants.FeaturePIIScreening:    false,
+		}
+		for feature, enabled := range wantFeatures {
+			if permissions.Features[feature] != enabled {
+				t.Errorf("Features[%s] = %v, want %v", feature, permissions.Features[feature], enabled)
+			}
+		}
+	})
+
+	t.Run("Admin sees the approval policy", func(t *testing.T) {
+		permissions, err := service.GetPermissions(context.Background(), 2, constants.RoleAdmin, false, nil)
+		if err != nil {
+			t.Fatalf("GetPermissions() error = %v", err)
+		}
+		if len(permissions.Policies.ApprovalRequiredActions) != 1 {
+			t.Errorf("ApprovalRequiredActions = %v, want [user_role]", permissions.Policies.ApprovalRequiredActions)
+		}
+	})
+
+	t.Run("Admin key without admin scope does not", func(t *testing.T) {
+		permissions, err := service.GetPermissions(context.Background(), 2, constants.RoleAdmin, true, []string{constants.APIKeyScopeDocumentsRead})
+		if err != nil {
+			t.Fatalf("GetPermissions() error = %v", err)
+		}
+		if permissions.Policies.ApprovalRequiredActions != nil {
+			t.Errorf("ApprovalRequiredActions = %v, want none without the admin scope", permissions.Policies.ApprovalRequiredActions)
+		}
+	})
+
+	t.Run("Policy error", func(t *testing.T) {
+		failing := NewPermissionService(newTestPermissionConfig(), failingPolicySource{})
+		if _, err := failing.GetPermissions(context.Background(), 1, constants.RoleUser, false, nil); err == nil {
+			t.Error("GetPermissions() error = nil, want the policy error")
+		}
+	})
+}
@@ -0,0 +1,327 @@
+// Package service provides business logic implementations.
+package service
+
+import (
+	"context"
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/rs/zerolog/log"
+
+	"github.com/yasinhessnawi1/Hideme_Backend/internal/config"
+	"github.com/yasinhessnawi1/Hideme_Backend/internal/constants"
+	"github.com/yasinhessnawi1/Hideme_Backend/internal/models"
+	"github.com/yasinhessnawi1/Hideme_Backend/internal/utils"
+)
+
+// ScreeningTermSource provides the per-user terms used when screening free-text fields.
+// It is implemented by SettingsService.
+type ScreeningTermSource interface {
+	// GetSearchPatterns returns the user's search patterns; their texts are treated as personal data.
+	GetSearchPatterns(ctx context.Context, userID int64) ([]*models.SearchPattern, error)
+
+	// GetBanList returns the user's ban list; its words are never treated as personal data.
+	GetBanList(ctx context.Context, userID int64) (*models.BanListWithWords, error)
+}
+
+// piiDetector finds one kind of personal data. Candidates matched by the pattern are
+// only reported if they pass the optional check, which keeps false positives on
+// ordinary numbers down.
+type piiDetector struct {
+	kind    models.PIIKind
+	pattern *regexp.Regexp
+	valid   func(match string) bool
+}
+
+// piiDetectors are the built-in detectors, applied to every screened field.
+var piiDetectors = []piiDetector{
+	{
+		kind:    models.PIIEmail,
+		pattern: regexp.MustCompile(`[A-Za-z0-9._%+\-]+@[A-Za-z0-9.\-]+\.[A-Za-z]{2,}`),
+	},
+	{
+		kind:    models.PIIPhoneNumber,
+		pattern: regexp.MustCompile(`(?:\+|\b00)\d{1,3}[ \-]?\d(?:[ \-]?\d){6,11}\b`),
+	},
+	{
+		kind:    models.PIIPaymentCard,
+		pattern: regexp.MustCompile(`\b\d(?:[ \-]?\d){12,18}\b`),
+		valid:   validLuhn,
+	},
+	{
+		kind:    models.PIINationalID,
+		pattern: regexp.MustCompile(`\b\d{6} ?\d{5}\b`),
+		valid:   validNorwegianNationalID,
+	},
+	{
+		kind:    models.PIIIBAN,
+		pattern: regexp.MustCompile(`\b[A-Z]{2}\d{2}(?: ?[A-Z0-9]){11,30}\b`),
+		valid:   validIBAN,
+	},
+}
+
+// PIIScreener checks free-text fields for personal data before they are stored.
+// Fields such as API key names, admin notes and status incident messages are stored
+// as entered and bypass the document redaction pipeline, so personal data entered
+// there would otherwise be kept indefinitely and, for incidents, shown publicly.
+//
+// Every field is checked by the built-in detectors and against the user's own
+// normal and case-sensitive search patterns; words on the user's ban list are never
+// reported. Depending on the mode, findings are logged or the request is rejected.
+// Findings only name the field and the kind of data, never the matched text.
+type PIIScreener struct {
+	mode  string
+	terms ScreeningTermSource
+}
+
+// NewPIIScreener creates a new PIIScreener.
+//
+// Parameters:
+//   - settings: The screening mode
+//   - terms: Source of the users' search patterns and ban lists; nil to use the built-in detectors only
+//
+// Returns:
+//   - A configured PIIScreener
+func NewPIIScreener(settings *config.PIIScreeningSettings, terms ScreeningTermSource) *PIIScreener {
+	return &PIIScreener{
+		mode:  strings.ToLower(settings.Mode),
+		terms: terms,
+	}
+}
+
+// Screen checks free-text fields for personal data.
+//
+// Parameters:
+//   - ctx: Context for the operation
+//   - userID: The ID of the user entering the fields, whose search patterns and ban list apply
+//   - fields: The field values keyed by field name; empty values are skipped
+//
+// Returns:
+//   - ValidationError naming the affected fields if personal data is found in block mode
+//   - nil otherwise; in warn mode findings are logged instead
+func (s *PIIScreener) Screen(ctx context.Context, userID int64, fields map[string]string) error {
+	if s.mode == constants.PIIScreeningOff || !hasText(fields) {
+		return nil
+	}
+
+	terms, exempt := s.loadTerms(ctx, userID)
+	findings := findPII(fields, terms, exempt)
+	if len(findings) == 0 {
+		return nil
+	}
+
+	kindsByField := make(map[string][]string)
+	for _, finding := range findings {
+		kindsByField[finding.Field] = append(kindsByField[finding.Field], string(finding.Kind))
+	}
+
+	if s.mode == constants.PIIScreeningBlock {
+		details := make(map[string]string, len(kindsByField))
+		for field, kinds := range kindsByField {
+			details[field] = fmt.Sprintf("contains personal data (%s)", strings.Join(kinds, ", "))
+		}
+		return utils.NewValidationErrorWithDetails(constants.MsgPersonalDataInField, details)
+	}
+
+	fieldNames := make([]string, 0, len(kindsByField))
+	for field := range kindsByField {
+		fieldNames = append(fieldNames, field)
+	}
+	sort.Strings(fieldNames)
+
+	event := log.Warn().
+		Int64("user_id", userID).
+		Str("event", constants.LogEventPIIScreening)
+	for _, field := range fieldNames {
+		event = event.Strs(field, kindsByField[field])
+	}
+	event.Msg("Personal data stored in free-text field")
+
+	return nil
+}
+
+// screeningTerm is a search pattern text matched against the screened fields.
+type screeningTerm struct {
+	text          string
+	caseSensitive bool
+}
+
+// loadTerms returns the user's search pattern texts and the words of their ban list, which
+// are compared ignoring case and as their match options ask.
+// Screening is best-effort, so failures are logged and screening continues with the built-in detectors.
+func (s *PIIScreener) loadTerms(ctx context.Context, userID int64) ([]screeningTerm, banListExemptions) {
+	if s.terms == nil {
+		return nil, newBanListExemptions(nil, true)
+	}
+
+	banList, err := s.terms.GetBanList(ctx, userID)
+	if err != nil {
+		log.Warn().Err(err).Int64("user_id", userID).Msg("Failed to load ban list for PII screening")
+		banList = nil
+	}
+	exempt := newBanListExemptions(banList, true)
+
+	patterns, err := s.terms.GetSearchPatterns(ctx, userID)
+	if err != nil {
+		log.Warn().Err(err).Int64("user_id", userID).Msg("Failed to load search patterns for PII screening")
+		return nil, exempt
+	}
+
+	terms := make([]screeningTerm, 0, len(patterns))
+	for _, pattern := range patterns {
+		text := strings.TrimSpace(pattern.PatternText)
+		// AI search patterns describe what to look for rather than the text itself
+		if text == "" || pattern.PatternType == models.AISearch || exempt.contains(text) {
+			continue
+		}
+		terms = append(terms, screeningTerm{text: text, caseSensitive: pattern.PatternType == models.CaseSensitive})
+	}
+
+	return terms, exempt
+}
+
+// findPII returns the kinds of personal data found in each field, at most one finding per field and kind.
+func findPII(fields map[string]string, terms []screeningTerm, exempt banListExemptions) []models.PIIFinding {
+	fieldNames := make([]string, 0, len(fields))
+	for field := range fields {
+		fieldNames = append(fieldNames, field)
+	}
+	sort.Strings(fieldNames)
+
+	var findings []models.PIIFinding
+	for _, field := range fieldNames {
+		value := fields[field]
+		if value == "" {
+			continue
+		}
+
+		for _, detector := range piiDetectors {
+			for _, match := range detector.pattern.FindAllString(value, -1) {
+				if exempt.contains(match) || (detector.valid != nil && !detector.valid(match)) {
+					continue
+				}
+				findings = append(findings, models.PIIFinding{Field: field, Kind: detector.kind})
+				break
+			}
+		}
+
+		lowered := strings.ToLower(value)
+		for _, term := range terms {
+			found := strings.Contains(value, term.text)
+			if !term.caseSensitive {
+				found = strings.Contains(lowered, strings.ToLower(term.text))
+			}
+			if found {
+				findings = append(findings, models.PIIFinding{Field: field, Kind: models.PIISearchPattern})
+				break
+			}
+		}
+	}
+
+	return findings
+}
+
+// hasText reports whether any of the fields is non-empty.
+func hasText(fields map[string]string) bool {
+	for _, value := range fields {
+		if value != "" {
+			return true
+		}
+	}
+	return false
+}
+
+// digitsOf returns the digits of a string, dropping separators.
+func digitsOf(s string) []int {
+	digits := make([]int, 0, len(s))
+	for _, r := range s {
+		if r >= '0' && r <= '9' {
+			digits = append(digits, int(r-'0'))
+		}
+	}
+	return digits
+}
+
+// validLuhn reports whether a number passes the Luhn check used by payment cards.
+func validLuhn(number string) bool {
+	digits := digitsOf(number)
+	if len(digits) < 13 || len(digits) > 19 {
+		return false
+	}
+
+	sum := 0
+	for i := len(digits) - 1; i >= 0; i-- {
+		digit := digits[i]
+		if (len(digits)-i)%2 == 0 {
+			digit *= 2
+			if digit > 9 {
+				digit -= 9
+			}
+		}
+		sum += digit
+	}
+	return sum%10 == 0
+}
+
+// validNorwegianNationalID reports whether a number is a plausible Norwegian national
+// identity number (fødselsnummer), including D-numbers and synthetic test numbers.
+func validNorwegianNationalID(number string) bool {
+	d := digitsOf(number)
+	if len(d) != 11 {
+		return false
+	}
+
+	day := d[0]*10 + d[1]
+	if day > 40 {
+		day -= 40 // D-number
+	}
+	month := d[2]*10 + d[3]
+	if month > 80 {
+		month -= 80 // synthetic test number
+	} else if month > 40 {
+		month -= 40 // H-number
+	}
+	if day < 1 || day > 31 || month < 1 || month > 12 {
+		return false
+	}
+
+	checkDigit := func(weights []int, digits []int) int {
+		sum := 0
+		for i, weight := range weights {
+			sum += weight * digits[i]
+		}
+		check := 11 - sum%11
+		if check == 11 {
+			return 0
+		}
+		return check
+	}
+
+	k1 := checkDigit([]int{3, 7, 6, 1, 8, 9, 4, 5, 2}, d)
+	k2 := checkDigit([]int{5, 4, 3, 2, 7, 6, 5, 4, 3, 2}, d)
+	return k1 == d[9] && k2 == d[10]
+}
+
+// validIBAN reports whether an account number has a valid IBAN checksum (ISO 13616).
+func validIBAN(iban string) bool {
+	iban = strings.ReplaceAll(iban, " ", "")
+	if len(iban) < 15 || len(iban) > 34 {
+		return false
+	}
+
+	rearranged := iban[4:] + iban[:4]
+	remainder := 0
+	for _, r := range rearranged {
+		switch {
+		case r >= '0' && r <= '9':
+			remainder = (remainder*10 + int(r-'0')) % 97
+		case r >= 'A' && r <= 'Z':
+			remainder = (remainder*100 + int(r-'A') + 10) % 97
+		default:
+			return false
+		}
+	}
+	return remainder == 1
+}
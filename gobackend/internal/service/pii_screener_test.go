@@ -0,0 +1,185 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/yasinhessnawi1/Hideme_Backend/internal/config"
+	"github.com/yasinhessnawi1/Hideme_Backend/internal/models"
+	"github.com/yasinhessnawi1/Hideme_Backend/internal/utils"
+)
+
+// MockScreeningTermSource is an in-memory implementation of ScreeningTermSource
+type MockScreeningTermSource struct {
+	patterns []*models.SearchPattern
+	banned   []string
+	options  map[string]models.BanListMatchOptions
+	err      error
+}
+
+func (m *MockScreeningTermSource) GetSearchPatterns(ctx context.Context, userID int64) ([]*models.SearchPattern, error) {
+	return m.patterns, m.err
+}
+
+func (m *MockScreeningTermSource) GetBanList(ctx context.Context, userID int64) (*models.BanListWithWords, error) {
+	if m.err != nil {
+		return nil, m.err
+	}
+	return &models.BanListWithWords{ID: 1, Words: m.banned, Options: m.options}, nil
+}
+
+func newTestPIIScreener(mode string, terms ScreeningTermSource) *PIIScreener {
+	return NewPIIScreener(&config.PIIScreeningSettings{Mode: mode}, terms)
+}
+
+func TestFindPII_BuiltInDetectors(t *testing.T) {
+	tests := []struct {
+		name     string
+		value    string
+		expected []models.PIIKind
+	}{
+		{name: "Email", value: "Reported by ola.nordmann@example.no", expected: []models.PIIKind{models.PIIEmail}},
+		{name: "Phone number", value: "Call +47 912 34 567 for access", expected: []models.PIIKind{models.PIIPhoneNumber}},
+		{name: "Payment card", value: "card 4111 1111 1111 1111", expected: []models.PIIKind{models.PIIPaymentCard}},
+		{name: "Card failing the Luhn check", value: "order 4111 1111 1111 1112", expected: nil},
+		{name: "National ID", value: "user 150585 34541 asked for erasure", expected: []models.PIIKind{models.PIINationalID}},
+		{name: "National ID with invalid check digits", value: "ticket 15058534542", expected: nil},
+		{name: "IBAN", value: "refund to NO93 8601 1117 947", expected: []models.PIIKind{models.PIIIBAN}},
+		{name: "IBAN with invalid checksum", value: "NO94 8601 1117 947", expected: nil},
+		{name: "Several kinds", value: "ola@example.no, GB82 WEST 1234 5698 7654 32", expected: []models.PIIKind{models.PIIEmail, models.PIIIBAN}},
+		{name: "Plain text", value: "Production deploy key 2024", expected: nil},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var kinds []models.PIIKind
+			for _, finding := range findPII(map[string]string{"note": tt.value}, nil, nil) {
+				assert.Equal(t, "note", finding.Field)
+				kinds = append(kinds, finding.Kind)
+			}
+			assert.Equal(t, tt.expected, kinds)
+		})
+	}
+}
+
+func TestPIIScreener_Screen(t *testing.T) {
+	t.Run("Warn mode stores the field", func(t *testing.T) {
+		screener := newTestPIIScreener("warn", nil)
+
+		err := screener.Screen(context.Background(), 1, map[string]string{"name": "key for ola@example.no"})
+
+		assert.NoError(t, err)
+	})
+
+	t.Run("Block mode rejects the field", func(t *testing.T) {
+		screener := newTestPIIScreener("block", nil)
+
+		err := screener.Screen(context.Background(), 1, map[string]string{
+			"title":   "Outage",
+			"message": "Affects ola@example.no and +47 912 34 567",
+		})
+
+		require.Error(t, err)
+		assert.True(t, errors.Is(err, utils.ErrValidation))
+
+		var appErr *utils.AppError
+		require.True(t, errors.As(err, &appErr))
+		assert.Equal(t, map[string]interface{}{"message": "contains personal data (email, phone_number)"}, appErr.Details)
+		// The matched text is never repeated back
+		assert.NotContains(t, appErr.Error(), "ola@example.no")
+	})
+
+	t.Run("Off mode skips screening", func(t *testing.T) {
+		screener := newTestPIIScreener("off", nil)
+
+		err := screener.Screen(context.Background(), 1, map[string]string{"name": "ola@example.no"})
+
+		assert.NoError(t, err)
+	})
+
+	t.Run("Search patterns are screened", func(t *testing.T) {
+		terms := &MockScreeningTermSource{patterns: []*models.SearchPattern{
+			{PatternType: models.Normal, PatternText: "Kari Nordmann"},
+			{PatternType: models.CaseSensitive, PatternText: "ACME"},
+			{PatternType: models.AISearch, PatternText: "names of patients"},
+		}}
+		screener := newTestPIIScreener("block", terms)
+
+		assert.Error(t, screener.Screen(context.Background(), 1, map[string]string{"reason": "Erasure requested by kari nordmann"}))
+		assert.Error(t, screener.Screen(context.Background(), 1, map[string]string{"reason": "Contract with ACME ended"}))
+		assert.NoError(t, screener.Screen(context.Background(), 1, map[string]string{"reason": "Contract with Acme ended"}))
+		assert.NoError(t, screener.Screen(context.Background(), 1, map[string]string{"reason": "Remove names of patients"}))
+	})
+
+	t.Run("Ban list words are exempt", func(t *testing.T) {
+		terms := &MockScreeningTermSource{
+			patterns: []*models.SearchPattern{{PatternType: models.Normal, PatternText: "Oslo"}},
+			banned:   []string{"support@example.com", "oslo"},
+		}
+		screener := newTestPIIScreener("block", terms)
+
+		err := screener.Screen(context.Background(), 1, map[string]string{"note": "Forwarded to support@example.com in Oslo"})
+
+		assert.NoError(t, err)
+	})
+
+	t.Run("Ban list words are exempt as their options match", func(t *testing.T) {
+		terms := &MockScreeningTermSource{
+			patterns: []*models.SearchPattern{
+				{PatternType: models.Normal, PatternText: "Strom"},
+				{PatternType: models.Normal, PatternText: "Sorensen"},
+			},
+			banned:  []string{"Ström", "Sørensen"},
+			options: map[string]models.BanListMatchOptions{"Ström": {DiacriticInsensitive: true}},
+		}
+		screener := newTestPIIScreener("block", terms)
+
+		assert.NoError(t, screener.Screen(context.Background(), 1, map[string]string{"note": "Moved by Strom"}))
+		assert.Error(t, screener.Screen(context.Background(), 1, map[string]string{"note": "Moved by Sorensen"}))
+	})
+
+	t.Run("Built-in detectors apply when terms are unavailable", func(t *testing.T) {
+		screener := newTestPIIScreener("block", &MockScreeningTermSource{err: errors.New("database error")})
+
+		assert.NoError(t, screener.Screen(context.Background(), 1, map[string]string{"note": "Routine cleanup"}))
+		assert.Error(t, screener.Screen(context.Background(), 1, map[string]string{"note": "Asked by ola@example.no"}))
+	})
+}
+
+func TestPIIScreener_Services(t *testing.T) {
+	screener := newTestPIIScreener("block", nil)
+
+	t.Run("Approval reason", func(t *testing.T) {
+		service, repo, erased := setupApprovalService()
+		service.SetPIIScreener(screener)
+
+		action, err := service.RequestAction(context.Background(), 1, &models.AdminActionRequest{
+			ActionType: models.ActionUserErasure, TargetID: 42, Reason: "Requested by 15058534541",
+		})
+
+		assert.Nil(t, action)
+		assert.True(t, errors.Is(err, utils.ErrValidation))
+		assert.Empty(t, repo.actions)
+		assert.Empty(t, *erased)
+	})
+
+	t.Run("Status incident", func(t *testing.T) {
+		repo := NewMockStatusRepository()
+		svc := newTestStatusService(repo, NewAPIChecker())
+		svc.SetPIIScreener(screener)
+
+		incident, err := svc.CreateIncident(context.Background(), 1, &models.StatusIncidentCreate{
+			Title:   "Login failures",
+			Message: "Reported by ola@example.no",
+			Impact:  models.IncidentImpactMinor,
+		})
+
+		assert.Nil(t, incident)
+		assert.True(t, errors.Is(err, utils.ErrValidation))
+		assert.Empty(t, repo.incidents)
+	})
+}
@@ -0,0 +1,154 @@
+// Package service provides business logic implementations.
+package service
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/rs/zerolog/log"
+
+	"github.com/yasinhessnawi1/Hideme_Backend/internal/constants"
+	"github.com/yasinhessnawi1/Hideme_Backend/internal/models"
+	"github.com/yasinhessnawi1/Hideme_Backend/internal/utils"
+	"github.com/yasinhessnawi1/Hideme_Backend/internal/utils/clock"
+)
+
+// ErrProjectionRebuilding is returned when a projection is rebuilt while this server is
+// already rebuilding it
+var ErrProjectionRebuilding = utils.New(utils.ErrBadRequest, constants.StatusConflict, constants.MsgProjectionRebuilding)
+
+// RebuildFunc rebuilds a projection from the event log and returns the number of replayed events.
+type RebuildFunc func(ctx context.Context) (int64, error)
+
+// projection is a registered projection.
+type projection struct {
+	info    models.Projection
+	rebuild RebuildFunc
+}
+
+// ProjectionService keeps the registry of the derived tables, or projections, that are
+// rebuilt from the event log. Once a bug in the aggregation of a projection is fixed, an
+// administrator rebuilds it to correct the history the bug corrupted.
+type ProjectionService struct {
+	projections []projection
+	clock       clock.Clock
+
+	// mu guards rebuilding
+	mu         sync.Mutex
+	rebuilding map[string]bool
+}
+
+// NewProjectionService creates a new ProjectionService without projections.
+//
+// Returns:
+//   - A ProjectionService ready for projection registration
+func NewProjectionService() *ProjectionService {
+	return &ProjectionService{
+		rebuilding: make(map[string]bool),
+	}
+}
+
+// SetClock sets the clock projection runs are timed by.
+func (s *ProjectionService) SetClock(c clock.Clock) {
+	s.clock = c
+}
+
+// now returns the current time of the service's clock.
+func (s *ProjectionService) now() time.Time {
+	return clock.Or(s.clock).Now()
+}
+
+// Register adds a projection to the registry. Registration is not synchronized and must be
+// completed before projections are rebuilt.
+//
+// Parameters:
+//   - name: The name identifying the projection in the admin API
+//   - description: What the projection holds
+//   - eventTypes: The events the projection is built from
+//   - rebuild: The function rebuilding the projection
+func (s *ProjectionService) Register(name, description string, eventTypes []string, rebuild RebuildFunc) {
+	s.projections = append(s.projections, projection{
+		info:    models.Projection{Name: name, Description: description, EventTypes: eventTypes},
+		rebuild: rebuild,
+	})
+}
+
+// Projections returns the registered projections in the order they were registered.
+//
+// Returns:
+//   - The projections
+func (s *ProjectionService) Projections() []models.Projection {
+	infos := make([]models.Projection, 0, len(s.projections))
+	for _, p := range s.projections {
+		infos = append(infos, p.info)
+	}
+	return infos
+}
+
+// Rebuild rebuilds a projection by replaying its events. The projection is rebuilt within
+// one transaction, so it is either fully rebuilt or unchanged.
+//
+// Parameters:
+//   - ctx: Context for the operation
+//   - adminID: The ID of the administrator rebuilding the projection
+//   - name: The name of the projection
+//
+// Returns:
+//   - The outcome of the rebuild
+//   - A not found error if no projection has this name, ErrProjectionRebuilding if it is
+//     already being rebuilt, or the error of the rebuild
+func (s *ProjectionService) Rebuild(ctx context.Context, adminID int64, name string) (*models.ProjectionRebuild, error) {
+	var found *projection
+	for i := range s.projections {
+		if s.projections[i].info.Name == name {
+			found = &s.projections[i]
+			break
+		}
+	}
+	if found == nil {
+		return nil, utils.NewNotFoundError("Projection", name)
+	}
+
+	s.mu.Lock()
+	if s.rebuilding[name] {
+		s.mu.Unlock()
+		return nil, ErrProjectionRebuilding
+	}
+	s.rebuilding[name] = true
+	s.mu.Unlock()
+
+	defer func() {
+		s.mu.Lock()
+		delete(s.rebuilding, name)
+		s.mu.Unlock()
+	}()
+
+	started := s.now()
+	replayed, err := found.rebuild(ctx)
+	if err != nil {
+		log.Error().
+			Err(err).
+			Int64("admin_id", adminID).
+			Str("projection", name).
+			Str("category", constants.LogCategoryAdmin).
+			Msg("Projection rebuild failed")
+		return nil, err
+	}
+
+	rebuild := &models.ProjectionRebuild{
+		Projection:     name,
+		EventsReplayed: replayed,
+		StartedAt:      started,
+		DurationMS:     s.now().Sub(started).Milliseconds(),
+	}
+
+	log.Info().
+		Int64("admin_id", adminID).
+		Str("projection", name).
+		Int64("events", replayed).
+		Int64("duration_ms", rebuild.DurationMS).
+		Str("category", constants.LogCategoryAdmin).
+		Msg("Projection rebuilt")
+	return rebuild, nil
+}
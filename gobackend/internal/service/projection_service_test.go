@@ -0,0 +1,83 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/yasinhessnawi1/Hideme_Backend/internal/constants"
+	"github.com/yasinhessnawi1/Hideme_Backend/internal/utils"
+)
+
+func TestProjectionService_Projections(t *testing.T) {
+	s := NewProjectionService()
+	s.Register(constants.ProjectionTenantUsage, "Usage", []string{constants.EventUsageCounted}, nil)
+	s.Register(constants.ProjectionRuleHits, "Rule hits", []string{constants.EventRuleHitsRecorded}, nil)
+
+	projections := s.Projections()
+	require.Len(t, projections, 2)
+	assert.Equal(t, constants.ProjectionTenantUsage, projections[0].Name)
+	assert.Equal(t, []string{constants.EventRuleHitsRecorded}, projections[1].EventTypes)
+}
+
+func TestProjectionService_Rebuild(t *testing.T) {
+	t.Run("Success", func(t *testing.T) {
+		s := NewProjectionService()
+		s.Register(constants.ProjectionTenantUsage, "Usage", []string{constants.EventUsageCounted}, func(ctx context.Context) (int64, error) {
+			return 42, nil
+		})
+
+		rebuild, err := s.Rebuild(context.Background(), 1, constants.ProjectionTenantUsage)
+		require.NoError(t, err)
+		assert.Equal(t, constants.ProjectionTenantUsage, rebuild.Projection)
+		assert.Equal(t, int64(42), rebuild.EventsReplayed)
+	})
+
+	t.Run("Unknown projection", func(t *testing.T) {
+		s := NewProjectionService()
+
+		_, err := s.Rebuild(context.Background(), 1, "coverage")
+		assert.ErrorIs(t, err, utils.ErrNotFound)
+	})
+
+	t.Run("Failure", func(t *testing.T) {
+		s := NewProjectionService()
+		s.Register(constants.ProjectionRuleHits, "Rule hits", nil, func(ctx context.Context) (int64, error) {
+			return 0, errors.New("failed to replay event 9")
+		})
+
+		_, err := s.Rebuild(context.Background(), 1, constants.ProjectionRuleHits)
+		assert.ErrorContains(t, err, "failed to replay event 9")
+
+		// A failed rebuild can be retried
+		_, err = s.Rebuild(context.Background(), 1, constants.ProjectionRuleHits)
+		assert.NotErrorIs(t, err, ErrProjectionRebuilding)
+	})
+
+	t.Run("Already rebuilding", func(t *testing.T) {
+		s := NewProjectionService()
+		started := make(chan struct{})
+		release := make(chan struct{})
+		s.Register(constants.ProjectionRuleHits, "Rule hits", nil, func(ctx context.Context) (int64, error) {
+			close(started)
+			<-release
+			return 1, nil
+		})
+
+		done := make(chan error)
+		go func() {
+			_, err := s.Rebuild(context.Background(), 1, constants.ProjectionRuleHits)
+			done <- err
+		}()
+		<-started
+
+		_, err := s.Rebuild(context.Background(), 2, constants.ProjectionRuleHits)
+		assert.ErrorIs(t, err, ErrProjectionRebuilding)
+
+		close(release)
+		assert.NoError(t, <-done)
+	})
+}
@@ -0,0 +1,206 @@
+// Package service provides business logic implementations.
+package service
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+
+	"github.com/rs/zerolog/log"
+
+	"github.com/yasinhessnawi1/Hideme_Backend/internal/config"
+	"github.com/yasinhessnawi1/Hideme_Backend/internal/constants"
+	"github.com/yasinhessnawi1/Hideme_Backend/internal/models"
+	"github.com/yasinhessnawi1/Hideme_Backend/internal/repository"
+	"github.com/yasinhessnawi1/Hideme_Backend/internal/utils"
+)
+
+// QuotaWarningNotifier sends warnings to users whose detection quota runs low.
+type QuotaWarningNotifier interface {
+	SendQuotaWarningEmail(toEmail, toName string, threshold int, quota *models.QuotaState) error
+}
+
+// QuotaService manages the per-user detection quota.
+// Each user has a token bucket; every page sent for detection costs one token.
+// With a Redis store the buckets are shared with the detection service, so both
+// backends decrement the same counter atomically instead of each keeping their own.
+//
+// When a consumption takes the used share of a bucket past one of the configured
+// warning thresholds, the user is warned so they learn about the quota running out
+// before requests start failing.
+type QuotaService struct {
+	store    QuotaStore
+	policy   *config.QuotaSettings
+	userRepo repository.UserRepository
+	notifier QuotaWarningNotifier
+}
+
+// NewQuotaService creates a new QuotaService.
+//
+// Parameters:
+//   - store: The store holding the token buckets
+//   - policy: The quota settings with capacity, refill interval and key prefix
+//
+// Returns:
+//   - A configured QuotaService
+func NewQuotaService(store QuotaStore, policy *config.QuotaSettings) *QuotaService {
+	return &QuotaService{
+		store:  store,
+		policy: policy,
+	}
+}
+
+// SetWarningNotifier enables notifying users when their quota crosses a warning threshold.
+// Without a notifier, crossings are only logged.
+//
+// Parameters:
+//   - userRepo: Repository used to look up the email address of the user
+//   - notifier: The notifier sending the warnings
+func (s *QuotaService) SetWarningNotifier(userRepo repository.UserRepository, notifier QuotaWarningNotifier) {
+	s.userRepo = userRepo
+	s.notifier = notifier
+}
+
+// Consume takes units from a user's quota.
+//
+// Parameters:
+//   - ctx: Context for the operation
+//   - userID: The ID of the user
+//   - units: The number of tokens to consume
+//
+// Returns:
+//   - The quota state after consuming
+//   - QuotaExceededError if not enough tokens are available; nothing is consumed then
+//   - Other errors if the store is unavailable
+func (s *QuotaService) Consume(ctx context.Context, userID int64, units int64) (*models.QuotaState, error) {
+	if units <= 0 {
+		return nil, utils.NewValidationError("units", "Units must be greater than zero")
+	}
+
+	allowed, remaining, err := s.store.Take(ctx, s.key(userID), units)
+	if err != nil {
+		return nil, fmt.Errorf("failed to consume quota: %w", err)
+	}
+
+	if !allowed {
+		log.Info().
+			Int64("user_id", userID).
+			Int64("units", units).
+			Int64("remaining", remaining).
+			Msg("Detection quota exceeded")
+		return nil, utils.NewQuotaExceededError(remaining)
+	}
+
+	quota := s.state(userID, remaining)
+	if threshold := s.crossedThreshold(remaining+units, remaining); threshold > 0 {
+		s.warn(ctx, quota, threshold)
+	}
+
+	return quota, nil
+}
+
+// GetQuota returns a user's current quota.
+//
+// Parameters:
+//   - ctx: Context for the operation
+//   - userID: The ID of the user
+//
+// Returns:
+//   - The quota state
+//   - An error if the store is unavailable
+func (s *QuotaService) GetQuota(ctx context.Context, userID int64) (*models.QuotaState, error) {
+	remaining, err := s.store.Peek(ctx, s.key(userID))
+	if err != nil {
+		return nil, fmt.Errorf("failed to get quota: %w", err)
+	}
+
+	return s.state(userID, remaining), nil
+}
+
+// AdjustQuota adds tokens to or removes tokens from a user's quota.
+//
+// Parameters:
+//   - ctx: Context for the operation
+//   - userID: The ID of the user
+//   - delta: The number of tokens to add (positive) or remove (negative)
+//
+// Returns:
+//   - The quota state after the adjustment
+//   - An error if the store is unavailable
+func (s *QuotaService) AdjustQuota(ctx context.Context, userID int64, delta int64) (*models.QuotaState, error) {
+	remaining, err := s.store.Adjust(ctx, s.key(userID), delta)
+	if err != nil {
+		return nil, fmt.Errorf("failed to adjust quota: %w", err)
+	}
+
+	log.Info().
+		Int64("user_id", userID).
+		Int64("delta", delta).
+		Int64("remaining", remaining).
+		Str("category", constants.LogCategoryAdmin).
+		Msg("Detection quota adjusted")
+
+	return s.state(userID, remaining), nil
+}
+
+// key returns the bucket key of a user.
+func (s *QuotaService) key(userID int64) string {
+	return s.policy.KeyPrefix + strconv.FormatInt(userID, 10)
+}
+
+// state builds the quota state of a user.
+func (s *QuotaService) state(userID int64, remaining int64) *models.QuotaState {
+	quota := &models.QuotaState{
+		UserID:        userID,
+		Remaining:     remaining,
+		Capacity:      s.policy.Capacity,
+		RefillSeconds: int64(s.policy.RefillInterval.Seconds()),
+	}
+	for _, threshold := range s.policy.WarningThresholds {
+		if s.reached(remaining, threshold) && threshold > quota.WarningThreshold {
+			quota.WarningThreshold = threshold
+		}
+	}
+	return quota
+}
+
+// reached reports whether a bucket with the remaining tokens has used at least threshold percent.
+func (s *QuotaService) reached(remaining int64, threshold int) bool {
+	return (s.policy.Capacity-remaining)*100 >= int64(threshold)*s.policy.Capacity
+}
+
+// crossedThreshold returns the highest warning threshold reached by going from before to
+// after remaining tokens that was not reached before; zero if none was crossed.
+func (s *QuotaService) crossedThreshold(before, after int64) int {
+	crossed := 0
+	for _, threshold := range s.policy.WarningThresholds {
+		if !s.reached(before, threshold) && s.reached(after, threshold) && threshold > crossed {
+			crossed = threshold
+		}
+	}
+	return crossed
+}
+
+// warn notifies a user that their quota crossed a warning threshold.
+// The units have already been consumed, so failures are logged rather than returned.
+func (s *QuotaService) warn(ctx context.Context, quota *models.QuotaState, threshold int) {
+	log.Info().
+		Int64("user_id", quota.UserID).
+		Int("threshold", threshold).
+		Int64("remaining", quota.Remaining).
+		Msg("Detection quota warning threshold crossed")
+
+	if s.notifier == nil || s.userRepo == nil {
+		return
+	}
+
+	user, err := s.userRepo.GetByID(ctx, quota.UserID)
+	if err != nil {
+		log.Error().Err(err).Int64("user_id", quota.UserID).Msg("Failed to look up user for quota warning")
+		return
+	}
+
+	if err := s.notifier.SendQuotaWarningEmail(user.Email, user.Username, threshold, quota); err != nil {
+		log.Error().Err(err).Int64("user_id", quota.UserID).Msg("Failed to send quota warning")
+	}
+}
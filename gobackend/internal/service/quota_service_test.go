@@ -0,0 +1,154 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/yasinhessnawi1/Hideme_Backend/internal/config"
+	"github.com/yasinhessnawi1/Hideme_Backend/internal/models"
+	"github.com/yasinhessnawi1/Hideme_Backend/internal/utils"
+)
+
+func newTestQuotaService() *QuotaService {
+	policy := &config.QuotaSettings{
+		KeyPrefix:      "quota:detection:",
+		Capacity:       10,
+		RefillInterval: 24 * time.Hour,
+	}
+	return NewQuotaService(NewMemoryQuotaStore(policy.Capacity, policy.RefillInterval), policy)
+}
+
+func TestQuotaService_Consume(t *testing.T) {
+	service := newTestQuotaService()
+	ctx := context.Background()
+
+	quota, err := service.Consume(ctx, 1, 8)
+	if err != nil {
+		t.Fatalf("Consume() error = %v", err)
+	}
+	if quota.UserID != 1 || quota.Remaining != 2 || quota.Capacity != 10 || quota.RefillSeconds != 86400 {
+		t.Errorf("Unexpected quota: %+v", quota)
+	}
+
+	// Exceeding the quota is rejected without consuming anything
+	_, err = service.Consume(ctx, 1, 3)
+	if !errors.Is(err, utils.ErrQuotaExceeded) {
+		t.Fatalf("Expected ErrQuotaExceeded, got %v", err)
+	}
+	quota, _ = service.GetQuota(ctx, 1)
+	if quota.Remaining != 2 {
+		t.Errorf("Expected 2 remaining after a rejected consume, got %d", quota.Remaining)
+	}
+
+	// Units must be positive
+	if _, err := service.Consume(ctx, 1, 0); err == nil {
+		t.Error("Expected a validation error for zero units")
+	}
+}
+
+func TestQuotaService_AdjustQuota(t *testing.T) {
+	service := newTestQuotaService()
+	ctx := context.Background()
+
+	if _, err := service.Consume(ctx, 1, 10); err != nil {
+		t.Fatalf("Consume() error = %v", err)
+	}
+
+	quota, err := service.AdjustQuota(ctx, 1, 5)
+	if err != nil {
+		t.Fatalf("AdjustQuota() error = %v", err)
+	}
+	if quota.Remaining != 5 {
+		t.Errorf("Expected 5 remaining, got %d", quota.Remaining)
+	}
+
+	// Other users are not affected
+	quota, _ = service.GetQuota(ctx, 2)
+	if quota.Remaining != 10 {
+		t.Errorf("Expected a full bucket for another user, got %d", quota.Remaining)
+	}
+}
+
+// MockQuotaWarningNotifier records the quota warnings sent.
+type MockQuotaWarningNotifier struct {
+	thresholds []int
+	emails     []string
+}
+
+func (m *MockQuotaWarningNotifier) SendQuotaWarningEmail(toEmail, toName string, threshold int, quota *models.QuotaState) error {
+	m.thresholds = append(m.thresholds, threshold)
+	m.emails = append(m.emails, toEmail)
+	return nil
+}
+
+func TestQuotaService_WarningThresholds(t *testing.T) {
+	policy := &config.QuotaSettings{
+		KeyPrefix:         "quota:detection:",
+		Capacity:          100,
+		RefillInterval:    24 * time.Hour,
+		WarningThresholds: []int{80, 95},
+	}
+	service := NewQuotaService(NewMemoryQuotaStore(policy.Capacity, policy.RefillInterval), policy)
+
+	userRepo := NewMockUserRepository()
+	userRepo.users[1] = &models.User{ID: 1, Username: "alice", Email: "alice@example.com"}
+	notifier := &MockQuotaWarningNotifier{}
+	service.SetWarningNotifier(userRepo, notifier)
+	ctx := context.Background()
+
+	// Below the first threshold nothing is sent
+	quota, err := service.Consume(ctx, 1, 70)
+	if err != nil {
+		t.Fatalf("Consume() error = %v", err)
+	}
+	if quota.WarningThreshold != 0 || len(notifier.thresholds) != 0 {
+		t.Errorf("Expected no warning at 70%%, got threshold %d and %v", quota.WarningThreshold, notifier.thresholds)
+	}
+
+	// Crossing 80% warns once, and staying above it does not warn again
+	quota, _ = service.Consume(ctx, 1, 10)
+	if quota.WarningThreshold != 80 {
+		t.Errorf("Expected warning threshold 80, got %d", quota.WarningThreshold)
+	}
+	_, _ = service.Consume(ctx, 1, 5)
+	if len(notifier.thresholds) != 1 || notifier.thresholds[0] != 80 || notifier.emails[0] != "alice@example.com" {
+		t.Errorf("Expected a single warning at 80%%, got %v to %v", notifier.thresholds, notifier.emails)
+	}
+
+	// Crossing 95% warns again
+	_, _ = service.Consume(ctx, 1, 10)
+	if len(notifier.thresholds) != 2 || notifier.thresholds[1] != 95 {
+		t.Errorf("Expected a second warning at 95%%, got %v", notifier.thresholds)
+	}
+
+	// The quota state reports the highest threshold reached
+	quota, _ = service.GetQuota(ctx, 1)
+	if quota.WarningThreshold != 95 {
+		t.Errorf("Expected warning threshold 95, got %d", quota.WarningThreshold)
+	}
+}
+
+func TestQuotaService_WarningSkipsThresholds(t *testing.T) {
+	policy := &config.QuotaSettings{
+		KeyPrefix:         "quota:detection:",
+		Capacity:          100,
+		RefillInterval:    24 * time.Hour,
+		WarningThresholds: []int{80, 95},
+	}
+	service := NewQuotaService(NewMemoryQuotaStore(policy.Capacity, policy.RefillInterval), policy)
+
+	userRepo := NewMockUserRepository()
+	userRepo.users[1] = &models.User{ID: 1, Username: "alice", Email: "alice@example.com"}
+	notifier := &MockQuotaWarningNotifier{}
+	service.SetWarningNotifier(userRepo, notifier)
+
+	// A single large consumption crossing both thresholds sends only the highest
+	if _, err := service.Consume(context.Background(), 1, 97); err != nil {
+		t.Fatalf("Consume() error = %v", err)
+	}
+	if len(notifier.thresholds) != 1 || notifier.thresholds[0] != 95 {
+		t.Errorf("Expected a single warning at 95%%, got %v", notifier.thresholds)
+	}
+}
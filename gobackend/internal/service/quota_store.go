@@ -0,0 +1,252 @@
+// Package service provides business logic implementations.
+//
+// This file contains the token bucket stores behind the detection quota.
+package service
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/yasinhessnawi1/Hideme_Backend/internal/database"
+	"github.com/yasinhessnawi1/Hideme_Backend/internal/utils/clock"
+)
+
+// QuotaStore holds token buckets and updates them atomically.
+// Buckets start full and refill continuously at capacity/refillInterval tokens per unit of time.
+type QuotaStore interface {
+	// Take removes units from a bucket if enough tokens are available.
+	// It returns whether the units were taken and the tokens remaining afterwards.
+	Take(ctx context.Context, key string, units int64) (bool, int64, error)
+
+	// Peek returns the tokens currently available in a bucket.
+	Peek(ctx context.Context, key string) (int64, error)
+
+	// Adjust adds delta tokens to a bucket (removing them if negative), never going below zero.
+	// Adjustments may push a bucket above its capacity; refills then pause until it drops below.
+	Adjust(ctx context.Context, key string, delta int64) (int64, error)
+}
+
+// Token bucket operations understood by tokenBucketScript.
+const (
+	quotaOpTake   = "take"
+	quotaOpPeek   = "peek"
+	quotaOpAdjust = "adjust"
+)
+
+// tokenBucketScript updates a token bucket atomically inside Redis.
+// The detection service runs the same script against the same keys, so both
+// backends draw from one bucket. The bucket is a hash with the fields "tokens"
+// and "updated_at" (milliseconds, Redis server time, so client clocks don't matter).
+//
+// KEYS[1] = bucket key
+// ARGV[1] = capacity, ARGV[2] = refill interval in ms, ARGV[3] = operation, ARGV[4] = units
+// Returns {allowed (1 or 0), remaining tokens (rounded down)}
+const tokenBucketScript = `
+local capacity = tonumber(ARGV[1])
+local refill_ms = tonumber(ARGV[2])
+local op = ARGV[3]
+local units = tonumber(ARGV[4])
+
+local time = redis.call('TIME')
+local now = tonumber(time[1]) * 1000 + math.floor(tonumber(time[2]) / 1000)
+
+local state = redis.call('HMGET', KEYS[1], 'tokens', 'updated_at')
+local tokens = tonumber(state[1])
+local updated = tonumber(state[2])
+if tokens == nil or updated == nil then
+  tokens = capacity
+  updated = now
+end
+
+if tokens < capacity and now > updated then
+  tokens = math.min(capacity, tokens + (now - updated) * capacity / refill_ms)
+end
+
+local allowed = 1
+if op == 'take' then
+  if tokens >= units then
+    tokens = tokens - units
+  else
+    allowed = 0
+  end
+elseif op == 'adjust' then
+  tokens = math.max(0, tokens + units)
+end
+
+redis.call('HSET', KEYS[1], 'tokens', tostring(tokens), 'updated_at', tostring(now))
+if tokens <= capacity then
+  redis.call('PEXPIRE', KEYS[1], refill_ms)
+else
+  redis.call('PERSIST', KEYS[1])
+end
+
+return {allowed, math.floor(tokens)}
+`
+
+// RedisQuotaStore keeps token buckets in Redis so that they are shared across
+// API instances and with the detection service.
+type RedisQuotaStore struct {
+	client         *database.RedisClient
+	capacity       int64
+	refillInterval time.Duration
+}
+
+// NewRedisQuotaStore creates a new RedisQuotaStore.
+//
+// Parameters:
+//   - client: The Redis client
+//   - capacity: The number of tokens in a full bucket
+//   - refillInterval: The time an empty bucket needs to refill completely
+//
+// Returns:
+//   - A configured RedisQuotaStore
+func NewRedisQuotaStore(client *database.RedisClient, capacity int64, refillInterval time.Duration) *RedisQuotaStore {
+	return &RedisQuotaStore{
+		client:         client,
+		capacity:       capacity,
+		refillInterval: refillInterval,
+	}
+}
+
+// Take removes units from a bucket if enough tokens are available.
+func (s *RedisQuotaStore) Take(ctx context.Context, key string, units int64) (bool, int64, error) {
+	return s.eval(ctx, key, quotaOpTake, units)
+}
+
+// Peek returns the tokens currently available in a bucket.
+func (s *RedisQuotaStore) Peek(ctx context.Context, key string) (int64, error) {
+	_, remaining, err := s.eval(ctx, key, quotaOpPeek, 0)
+	return remaining, err
+}
+
+// Adjust adds delta tokens to a bucket, never going below zero.
+func (s *RedisQuotaStore) Adjust(ctx context.Context, key string, delta int64) (int64, error) {
+	_, remaining, err := s.eval(ctx, key, quotaOpAdjust, delta)
+	return remaining, err
+}
+
+// eval runs the token bucket script for a bucket.
+func (s *RedisQuotaStore) eval(ctx context.Context, key string, op string, units int64) (bool, int64, error) {
+	reply, err := s.client.Do(ctx, "EVAL", tokenBucketScript, "1", key,
+		strconv.FormatInt(s.capacity, 10),
+		strconv.FormatInt(s.refillInterval.Milliseconds(), 10),
+		op,
+		strconv.FormatInt(units, 10),
+	)
+	if err != nil {
+		return false, 0, fmt.Errorf("failed to update quota bucket: %w", err)
+	}
+
+	values, ok := reply.([]any)
+	if !ok || len(values) != 2 {
+		return false, 0, fmt.Errorf("unexpected quota bucket reply: %v", reply)
+	}
+
+	allowed, okAllowed := values[0].(int64)
+	remaining, okRemaining := values[1].(int64)
+	if !okAllowed || !okRemaining {
+		return false, 0, fmt.Errorf("unexpected quota bucket reply: %v", reply)
+	}
+
+	return allowed == 1, remaining, nil
+}
+
+// memoryBucket is the state of a single in-memory token bucket.
+type memoryBucket struct {
+	tokens    float64
+	updatedAt time.Time
+}
+
+// MemoryQuotaStore keeps token buckets in memory.
+// It is used when no Redis server is configured; buckets are then not shared
+// with the detection service or other API instances.
+type MemoryQuotaStore struct {
+	capacity       int64
+	refillInterval time.Duration
+	buckets        map[string]*memoryBucket
+	bucketsMutex   sync.Mutex
+	clock          clock.Clock
+}
+
+// NewMemoryQuotaStore creates a new MemoryQuotaStore.
+//
+// Parameters:
+//   - capacity: The number of tokens in a full bucket
+//   - refillInterval: The time an empty bucket needs to refill completely
+//
+// Returns:
+//   - A configured MemoryQuotaStore
+func NewMemoryQuotaStore(capacity int64, refillInterval time.Duration) *MemoryQuotaStore {
+	return &MemoryQuotaStore{
+		capacity:       capacity,
+		refillInterval: refillInterval,
+		buckets:        make(map[string]*memoryBucket),
+	}
+}
+
+// SetClock sets the clock buckets are refilled by.
+func (s *MemoryQuotaStore) SetClock(c clock.Clock) {
+	s.clock = c
+}
+
+// now returns the current time of the store's clock.
+func (s *MemoryQuotaStore) now() time.Time {
+	return clock.Or(s.clock).Now()
+}
+
+// Take removes units from a bucket if enough tokens are available.
+func (s *MemoryQuotaStore) Take(ctx context.Context, key string, units int64) (bool, int64, error) {
+	s.bucketsMutex.Lock()
+	defer s.bucketsMutex.Unlock()
+
+	bucket := s.refill(key)
+	if bucket.tokens < float64(units) {
+		return false, int64(math.Floor(bucket.tokens)), nil
+	}
+
+	bucket.tokens -= float64(units)
+	return true, int64(math.Floor(bucket.tokens)), nil
+}
+
+// Peek returns the tokens currently available in a bucket.
+func (s *MemoryQuotaStore) Peek(ctx context.Context, key string) (int64, error) {
+	s.bucketsMutex.Lock()
+	defer s.bucketsMutex.Unlock()
+
+	return int64(math.Floor(s.refill(key).tokens)), nil
+}
+
+// Adjust adds delta tokens to a bucket, never going below zero.
+func (s *MemoryQuotaStore) Adjust(ctx context.Context, key string, delta int64) (int64, error) {
+	s.bucketsMutex.Lock()
+	defer s.bucketsMutex.Unlock()
+
+	bucket := s.refill(key)
+	bucket.tokens = math.Max(0, bucket.tokens+float64(delta))
+	return int64(math.Floor(bucket.tokens)), nil
+}
+
+// refill returns a bucket after adding the tokens earned since its last update.
+// The caller must hold bucketsMutex.
+func (s *MemoryQuotaStore) refill(key string) *memoryBucket {
+	now := s.now()
+	capacity := float64(s.capacity)
+
+	bucket, ok := s.buckets[key]
+	if !ok {
+		bucket = &memoryBucket{tokens: capacity, updatedAt: now}
+		s.buckets[key] = bucket
+	}
+
+	if bucket.tokens < capacity && now.After(bucket.updatedAt) {
+		earned := float64(now.Sub(bucket.updatedAt)) * capacity / float64(s.refillInterval)
+		bucket.tokens = math.Min(capacity, bucket.tokens+earned)
+	}
+	bucket.updatedAt = now
+
+	return bucket
+}
@@ -0,0 +1,130 @@
+package service
+
+import (
+	"bufio"
+	"context"
+	"io"
+	"net"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/yasinhessnawi1/Hideme_Backend/internal/database"
+	"github.com/yasinhessnawi1/Hideme_Backend/internal/testsupport/fakeclock"
+)
+
+func TestMemoryQuotaStore_TakeAndRefill(t *testing.T) {
+	// Setup: 100 tokens refilling over 100 minutes, i.e. one token per minute
+	store := NewMemoryQuotaStore(100, 100*time.Minute)
+	now := time.Date(2024, time.May, 15, 10, 0, 0, 0, time.UTC)
+	clk := fakeclock.New(now)
+	store.SetClock(clk)
+	ctx := context.Background()
+
+	// New buckets start full
+	allowed, remaining, err := store.Take(ctx, "user:1", 60)
+	if err != nil || !allowed || remaining != 40 {
+		t.Fatalf("Take() = %v, %d, %v; want true, 40, nil", allowed, remaining, err)
+	}
+
+	// Taking more than is available takes nothing
+	allowed, remaining, _ = store.Take(ctx, "user:1", 50)
+	if allowed || remaining != 40 {
+		t.Errorf("Take() = %v, %d; want false, 40", allowed, remaining)
+	}
+
+	// Ten minutes later ten tokens were refilled
+	now = clk.Advance(10 * time.Minute)
+	allowed, remaining, _ = store.Take(ctx, "user:1", 50)
+	if !allowed || remaining != 0 {
+		t.Errorf("Take() = %v, %d; want true, 0", allowed, remaining)
+	}
+
+	// Refills never exceed the capacity
+	now = clk.Advance(24 * time.Hour)
+	if remaining, _ := store.Peek(ctx, "user:1"); remaining != 100 {
+		t.Errorf("Peek() = %d; want 100", remaining)
+	}
+
+	// Buckets are independent
+	if remaining, _ := store.Peek(ctx, "user:2"); remaining != 100 {
+		t.Errorf("Peek() of another bucket = %d; want 100", remaining)
+	}
+}
+
+func TestMemoryQuotaStore_Adjust(t *testing.T) {
+	store := NewMemoryQuotaStore(100, time.Hour)
+	now := time.Date(2024, time.May, 15, 10, 0, 0, 0, time.UTC)
+	store.SetClock(fakeclock.New(now))
+	ctx := context.Background()
+
+	// Grants may exceed the capacity
+	if remaining, _ := store.Adjust(ctx, "user:1", 50); remaining != 150 {
+		t.Errorf("Adjust(+50) = %d; want 150", remaining)
+	}
+
+	// Deductions never go below zero
+	if remaining, _ := store.Adjust(ctx, "user:1", -500); remaining != 0 {
+		t.Errorf("Adjust(-500) = %d; want 0", remaining)
+	}
+}
+
+func TestRedisQuotaStore_Take(t *testing.T) {
+	// A fake Redis server answering every EVAL with the given reply
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("Failed to listen: %v", err)
+	}
+	defer listener.Close()
+
+	received := make(chan []string, 1)
+	go func() {
+		conn, err := listener.Accept()
+		if err != nil {
+			return
+		}
+		defer conn.Close()
+
+		reader := bufio.NewReader(conn)
+		header, _ := reader.ReadString('\n')
+		count, _ := strconv.Atoi(strings.TrimSpace(header[1:]))
+		args := make([]string, count)
+		for i := range args {
+			line, err := reader.ReadString('\n')
+			if err != nil {
+				return
+			}
+			size, _ := strconv.Atoi(strings.TrimSpace(line[1:]))
+			buf := make([]byte, size+2)
+			if _, err := io.ReadFull(reader, buf); err != nil {
+				return
+			}
+			args[i] = string(buf[:size])
+		}
+		received <- args
+		io.WriteString(conn, "*2\r\n:0\r\n:3\r\n")
+	}()
+
+	client, err := database.NewRedisClient("redis://"+listener.Addr().String(), 1)
+	if err != nil {
+		t.Fatalf("Failed to create client: %v", err)
+	}
+	defer client.Close()
+
+	store := NewRedisQuotaStore(client, 500, 24*time.Hour)
+	allowed, remaining, err := store.Take(context.Background(), "quota:detection:7", 5)
+	if err != nil {
+		t.Fatalf("Take() error = %v", err)
+	}
+	if allowed || remaining != 3 {
+		t.Errorf("Take() = %v, %d; want false, 3", allowed, remaining)
+	}
+
+	// EVAL script numkeys key capacity refill_ms op units
+	args := <-received
+	want := []string{"EVAL", tokenBucketScript, "1", "quota:detection:7", "500", "86400000", quotaOpTake, "5"}
+	if strings.Join(args, "|") != strings.Join(want, "|") {
+		t.Errorf("Unexpected command arguments: %q", args[2:])
+	}
+}
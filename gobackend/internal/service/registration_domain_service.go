@@ -0,0 +1,194 @@
+// Package service provides business logic implementations.
+package service
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"fmt"
+	"net"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/rs/zerolog/log"
+
+	"github.com/yasinhessnawi1/Hideme_Backend/internal/constants"
+	"github.com/yasinhessnawi1/Hideme_Backend/internal/models"
+	"github.com/yasinhessnawi1/Hideme_Backend/internal/repository"
+	"github.com/yasinhessnawi1/Hideme_Backend/internal/utils"
+	"github.com/yasinhessnawi1/Hideme_Backend/internal/utils/clock"
+)
+
+// RegistrationDomainService limits registration to the organization's email domains, so
+// people can't join with personal addresses. Administrators add a domain and publish its
+// verification token in a DNS TXT record; once the record is found the domain is verified.
+// While at least one domain is verified, new accounts need an email address at one of them.
+type RegistrationDomainService struct {
+	domainRepo repository.RegistrationDomainRepository
+	lookupTXT  func(ctx context.Context, name string) ([]string, error)
+	clock      clock.Clock
+}
+
+// NewRegistrationDomainService creates a new RegistrationDomainService.
+//
+// Parameters:
+//   - domainRepo: Repository for the registration domains
+//
+// Returns:
+//   - A configured RegistrationDomainService
+func NewRegistrationDomainService(domainRepo repository.RegistrationDomainRepository) *RegistrationDomainService {
+	return &RegistrationDomainService{
+		domainRepo: domainRepo,
+		lookupTXT:  net.DefaultResolver.LookupTXT,
+	}
+}
+
+// SetClock sets the clock domains are verified at.
+func (s *RegistrationDomainService) SetClock(c clock.Clock) {
+	s.clock = c
+}
+
+// now returns the current time of the service's clock.
+func (s *RegistrationDomainService) now() time.Time {
+	return clock.Or(s.clock).Now()
+}
+
+// ListDomains returns all registration domains with their verification records.
+//
+// Parameters:
+//   - ctx: Context for the operation
+//
+// Returns:
+//   - The domains by name
+//   - An error if retrieval fails
+func (s *RegistrationDomainService) ListDomains(ctx context.Context) ([]*models.RegistrationDomainView, error) {
+	domains, err := s.domainRepo.List(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	views := make([]*models.RegistrationDomainView, 0, len(domains))
+	for _, domain := range domains {
+		views = append(views, models.NewRegistrationDomainView(domain))
+	}
+	return views, nil
+}
+
+// AddDomain adds an unverified registration domain with a new verification token.
+// Registration is not limited to the domain until it is verified.
+//
+// Parameters:
+//   - ctx: Context for the operation
+//   - adminID: The ID of the administrator adding the domain
+//   - req: The domain name
+//
+// Returns:
+//   - The domain with the TXT record to publish
+//   - ValidationError if the name is not a domain name, DuplicateError if it was already added
+//   - Other errors if the domain could not be stored
+func (s *RegistrationDomainService) AddDomain(ctx context.Context, adminID int64, req *models.RegistrationDomainCreate) (*models.RegistrationDomainView, error) {
+	name, ok := models.NormalizeDomain(req.Domain)
+	if !ok {
+		return nil, utils.NewValidationError("domain", constants.MsgRegistrationDomainInvalid)
+	}
+
+	token := make([]byte, constants.RegistrationDomainTokenBytes)
+	if _, err := rand.Read(token); err != nil {
+		return nil, fmt.Errorf("failed to generate verification token: %w", err)
+	}
+
+	domain := &models.RegistrationDomain{
+		Domain:            name,
+		VerificationToken: hex.EncodeToString(token),
+		CreatedBy:         &adminID,
+	}
+	if err := s.domainRepo.Create(ctx, domain); err != nil {
+		return nil, err
+	}
+
+	log.Info().Int64("admin_id", adminID).Str("domain", name).Msg("Registration domain added")
+	return models.NewRegistrationDomainView(domain), nil
+}
+
+// VerifyDomain looks up the verification TXT record of a domain and marks the domain
+// verified if the record holds its token. Verifying a verified domain again is a no-op.
+//
+// Parameters:
+//   - ctx: Context for the operation
+//   - id: The ID of the domain
+//
+// Returns:
+//   - The domain
+//   - NotFoundError if the domain doesn't exist
+//   - ValidationError if the record was not found or holds another value
+//   - Other errors if the verification could not be stored
+func (s *RegistrationDomainService) VerifyDomain(ctx context.Context, id int64) (*models.RegistrationDomainView, error) {
+	domain, err := s.domainRepo.GetByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if domain.VerifiedAt != nil {
+		return models.NewRegistrationDomainView(domain), nil
+	}
+
+	records, err := s.lookupTXT(ctx, domain.VerificationRecord())
+	if err != nil {
+		log.Info().Err(err).Str("domain", domain.Domain).Msg("Registration domain verification record not found")
+		return nil, utils.NewValidationError("domain", constants.MsgRegistrationDomainUnverified)
+	}
+	if !slices.Contains(records, domain.VerificationValue()) {
+		return nil, utils.NewValidationError("domain", constants.MsgRegistrationDomainUnverified)
+	}
+
+	now := s.now()
+	if err := s.domainRepo.MarkVerified(ctx, id, now); err != nil {
+		return nil, err
+	}
+	domain.VerifiedAt = &now
+
+	log.Info().Str("domain", domain.Domain).Msg("Registration domain verified")
+	return models.NewRegistrationDomainView(domain), nil
+}
+
+// DeleteDomain removes a registration domain. Once the last verified domain is removed,
+// registration is open to all domains again.
+//
+// Parameters:
+//   - ctx: Context for the operation
+//   - id: The ID of the domain
+//
+// Returns:
+//   - NotFoundError if the domain doesn't exist
+//   - Other errors if the domain could not be removed
+func (s *RegistrationDomainService) DeleteDomain(ctx context.Context, id int64) error {
+	return s.domainRepo.Delete(ctx, id)
+}
+
+// CheckEmail checks that a new account may be created with an email address.
+//
+// Parameters:
+//   - ctx: Context for the operation
+//   - email: The email address of the new account
+//
+// Returns:
+//   - ValidationError on the email field if registration is limited to verified domains
+//     and the address is at none of them
+//   - Other errors if the domains could not be read
+func (s *RegistrationDomainService) CheckEmail(ctx context.Context, email string) error {
+	domains, err := s.domainRepo.ListVerified(ctx)
+	if err != nil {
+		return err
+	}
+	if len(domains) == 0 {
+		return nil
+	}
+
+	// Subdomains are separate mail domains and must be added on their own
+	if slices.Contains(domains, models.EmailDomain(email)) {
+		return nil
+	}
+	return utils.NewValidationErrorWithDetails(constants.MsgRegistrationDomainNotAllowed, map[string]string{
+		"email": "Use an email address at " + strings.Join(domains, ", "),
+	})
+}
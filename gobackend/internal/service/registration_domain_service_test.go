@@ -0,0 +1,115 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/yasinhessnawi1/Hideme_Backend/internal/models"
+	"github.com/yasinhessnawi1/Hideme_Backend/internal/repository"
+	"github.com/yasinhessnawi1/Hideme_Backend/internal/utils"
+)
+
+// MockRegistrationDomainRepository keeps registration domains in memory
+type MockRegistrationDomainRepository struct {
+	repository.RegistrationDomainRepository
+	domains []*models.RegistrationDomain
+}
+
+func (m *MockRegistrationDomainRepository) Create(ctx context.Context, domain *models.RegistrationDomain) error {
+	for _, existing := range m.domains {
+		if existing.Domain == domain.Domain {
+			return utils.NewDuplicateError("RegistrationDomain", "domain", domain.Domain)
+		}
+	}
+	domain.ID = int64(len(m.domains) + 1)
+	m.domains = append(m.domains, domain)
+	return nil
+}
+
+func (m *MockRegistrationDomainRepository) GetByID(ctx context.Context, id int64) (*models.RegistrationDomain, error) {
+	for _, domain := range m.domains {
+		if domain.ID == id {
+			stored := *domain
+			return &stored, nil
+		}
+	}
+	return nil, utils.NewNotFoundError("RegistrationDomain", id)
+}
+
+func (m *MockRegistrationDomainRepository) ListVerified(ctx context.Context) ([]string, error) {
+	domains := []string{}
+	for _, domain := range m.domains {
+		if domain.VerifiedAt != nil {
+			domains = append(domains, domain.Domain)
+		}
+	}
+	return domains, nil
+}
+
+func (m *MockRegistrationDomainRepository) MarkVerified(ctx context.Context, id int64, verifiedAt time.Time) error {
+	for _, domain := range m.domains {
+		if domain.ID == id {
+			domain.VerifiedAt = &verifiedAt
+			return nil
+		}
+	}
+	return utils.NewNotFoundError("RegistrationDomain", id)
+}
+
+func TestRegistrationDomainService(t *testing.T) {
+	repo := &MockRegistrationDomainRepository{}
+	service := NewRegistrationDomainService(repo)
+	records := make(map[string][]string)
+	service.lookupTXT = func(ctx context.Context, name string) ([]string, error) {
+		if values, ok := records[name]; ok {
+			return values, nil
+		}
+		return nil, errors.New("no such host")
+	}
+
+	if _, err := service.AddDomain(context.Background(), 1, &models.RegistrationDomainCreate{Domain: "localhost"}); !errors.Is(err, utils.ErrValidation) {
+		t.Errorf("AddDomain() error = %v, want a validation error for a name without a dot", err)
+	}
+
+	domain, err := service.AddDomain(context.Background(), 1, &models.RegistrationDomainCreate{Domain: "Example.com"})
+	if err != nil {
+		t.Fatalf("AddDomain() error = %v", err)
+	}
+	if domain.Domain != "example.com" || domain.RecordName != "_hideme-verification.example.com" || len(domain.VerificationToken) != 32 {
+		t.Errorf("AddDomain() = %+v, want example.com with a record and a token", domain)
+	}
+
+	// Unverified domains don't limit registration
+	if err := service.CheckEmail(context.Background(), "ola@gmail.com"); err != nil {
+		t.Errorf("CheckEmail() error = %v, want nil before the domain is verified", err)
+	}
+
+	// The record must hold the token of the domain
+	if _, err := service.VerifyDomain(context.Background(), domain.ID); !errors.Is(err, utils.ErrValidation) {
+		t.Errorf("VerifyDomain() error = %v, want a validation error without a record", err)
+	}
+	records[domain.RecordName] = []string{"v=spf1 -all", "hideme-domain-verification=wrong"}
+	if _, err := service.VerifyDomain(context.Background(), domain.ID); !errors.Is(err, utils.ErrValidation) {
+		t.Errorf("VerifyDomain() error = %v, want a validation error for another token", err)
+	}
+	records[domain.RecordName] = append(records[domain.RecordName], domain.RecordValue)
+	verified, err := service.VerifyDomain(context.Background(), domain.ID)
+	if err != nil {
+		t.Fatalf("VerifyDomain() error = %v", err)
+	}
+	if verified.VerifiedAt == nil {
+		t.Error("VerifyDomain() left the domain unverified")
+	}
+
+	// Now only addresses at the domain may register
+	if err := service.CheckEmail(context.Background(), "Ola@EXAMPLE.com"); err != nil {
+		t.Errorf("CheckEmail() error = %v, want nil for the verified domain", err)
+	}
+	for _, email := range []string{"ola@gmail.com", "ola@mail.example.com", "ola@example.com.evil.net"} {
+		if err := service.CheckEmail(context.Background(), email); !errors.Is(err, utils.ErrValidation) {
+			t.Errorf("CheckEmail(%q) error = %v, want a validation error", email, err)
+		}
+	}
+}
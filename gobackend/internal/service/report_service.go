@@ -0,0 +1,223 @@
+// Package service provides business logic implementations.
+package service
+
+import (
+	"bytes"
+	"context"
+	"encoding/csv"
+	"fmt"
+	"time"
+
+	"github.com/rs/zerolog/log"
+
+	"github.com/yasinhessnawi1/Hideme_Backend/internal/constants"
+	"github.com/yasinhessnawi1/Hideme_Backend/internal/models"
+	"github.com/yasinhessnawi1/Hideme_Backend/internal/repository"
+	"github.com/yasinhessnawi1/Hideme_Backend/internal/utils/clock"
+)
+
+// ReportEmailSender delivers generated reports.
+type ReportEmailSender interface {
+	// SendReportEmail sends an email with the report attached.
+	SendReportEmail(toEmail, toName string, report *models.Report) error
+}
+
+// ReportService manages report subscriptions and delivers the scheduled reports.
+// Reports are generated and sent by SendDueReports, which runs as a periodic
+// maintenance task.
+type ReportService struct {
+	reportRepo repository.ReportRepository
+	sender     ReportEmailSender
+	clock      clock.Clock
+}
+
+// NewReportService creates a new ReportService.
+//
+// Parameters:
+//   - reportRepo: Repository for report subscriptions and report data
+//   - sender: Sender that delivers the reports by email
+//
+// Returns:
+//   - A configured ReportService
+func NewReportService(reportRepo repository.ReportRepository, sender ReportEmailSender) *ReportService {
+	return &ReportService{
+		reportRepo: reportRepo,
+		sender:     sender,
+	}
+}
+
+// SetClock sets the clock reports are scheduled by, so tests can move time
+// past a report's due date.
+func (s *ReportService) SetClock(c clock.Clock) {
+	s.clock = c
+}
+
+// now returns the current time of the service's clock.
+func (s *ReportService) now() time.Time {
+	return clock.Or(s.clock).Now()
+}
+
+// Subscribe subscribes a user to a scheduled report.
+//
+// Parameters:
+//   - ctx: Context for the operation
+//   - userID: The ID of the user
+//   - req: The report to subscribe to
+//
+// Returns:
+//   - The created subscription
+//   - DuplicateError if the user is already subscribed to the report
+//   - Other errors if the subscription could not be stored
+func (s *ReportService) Subscribe(ctx context.Context, userID int64, req *models.ReportSubscriptionCreate) (*models.ReportSubscription, error) {
+	format := req.Format
+	if format == "" {
+		format = models.ReportFormatCSV
+	}
+
+	subscription := models.NewReportSubscription(userID, req.ReportType, format, req.Locale, req.CSVDelimiter)
+	if err := s.reportRepo.Create(ctx, subscription); err != nil {
+		return nil, err
+	}
+
+	return subscription, nil
+}
+
+// ListSubscriptions returns the report subscriptions of a user.
+//
+// Parameters:
+//   - ctx: Context for the operation
+//   - userID: The ID of the user
+//
+// Returns:
+//   - The subscriptions
+//   - An error if retrieval fails
+func (s *ReportService) ListSubscriptions(ctx context.Context, userID int64) ([]*models.ReportSubscription, error) {
+	return s.reportRepo.GetByUserID(ctx, userID)
+}
+
+// Unsubscribe removes one of a user's report subscriptions.
+//
+// Parameters:
+//   - ctx: Context for the operation
+//   - userID: The ID of the user
+//   - subscriptionID: The ID of the subscription
+//
+// Returns:
+//   - NotFoundError if the user has no such subscription
+//   - Other errors if removal fails
+func (s *ReportService) Unsubscribe(ctx context.Context, userID int64, subscriptionID int64) error {
+	return s.reportRepo.Delete(ctx, subscriptionID, userID)
+}
+
+// SendDueReports generates and sends all reports whose delivery time has passed.
+// A report that cannot be generated or sent is logged and retried on the next run.
+//
+// Parameters:
+//   - ctx: Context for the operation
+//
+// Returns:
+//   - The number of reports sent
+//   - An error if the due subscriptions could not be retrieved
+func (s *ReportService) SendDueReports(ctx context.Context) (int, error) {
+	now := s.now()
+	due, err := s.reportRepo.GetDue(ctx, now, constants.ReportBatchSize)
+	if err != nil {
+		return 0, fmt.Errorf("failed to get due reports: %w", err)
+	}
+
+	sent := 0
+	for _, subscription := range due {
+		report, err := s.generateReport(ctx, subscription)
+		if err != nil {
+			log.Error().Err(err).
+				Int64("subscription_id", subscription.ID).
+				Str("report_type", string(subscription.ReportType)).
+				Msg("Failed to generate report")
+			continue
+		}
+
+		if err := s.sender.SendReportEmail(subscription.Email, subscription.Username, report); err != nil {
+			log.Error().Err(err).
+				Int64("subscription_id", subscription.ID).
+				Msg("Failed to send report")
+			continue
+		}
+		sent++
+
+		// Skip any deliveries missed while the task was not running
+		if err := s.reportRepo.MarkSent(ctx, subscription.ID, now, subscription.ReportType.NextRun(now)); err != nil {
+			log.Error().Err(err).
+				Int64("subscription_id", subscription.ID).
+				Msg("Failed to schedule next report")
+		}
+	}
+
+	return sent, nil
+}
+
+// generateReport builds the report of a subscription for the period ending at its delivery time.
+// Dates, numbers and the CSV delimiter follow the subscription's locale; the file name
+// keeps ISO dates so that saved reports sort chronologically.
+//
+// Parameters:
+//   - ctx: Context for the operation
+//   - subscription: The due subscription
+//
+// Returns:
+//   - The generated report
+//   - An error if the report data could not be retrieved or rendered
+func (s *ReportService) generateReport(ctx context.Context, subscription *models.ReportSubscription) (*models.Report, error) {
+	from, to := subscription.ReportType.Period(subscription.NextRunAt)
+	lf := subscription.LocaleFormat()
+
+	var header []string
+	var records [][]string
+	var subject, body string
+
+	switch subscription.ReportType {
+	case models.ReportWeeklyEntitySummary:
+		summary, err := s.reportRepo.GetEntitySummary(ctx, subscription.UserID, from, to)
+		if err != nil {
+			return nil, err
+		}
+		header = models.EntitySummaryCSVHeader()
+		var total int64
+		for _, row := range summary {
+			records = append(records, row.CSVRecord(lf))
+			total += row.EntityCount
+		}
+		subject = fmt.Sprintf("Your weekly entity summary for %s", lf.FormatDate(from))
+		body = fmt.Sprintf("%d sensitive entities were detected in your documents between %s and %s. The attached report breaks them down by detection method and entity type.",
+			total, lf.FormatDate(from), lf.FormatDate(to.AddDate(0, 0, -1)))
+	case models.ReportMonthlyUsage:
+		usage, err := s.reportRepo.GetUsage(ctx, subscription.UserID, from)
+		if err != nil {
+			return nil, err
+		}
+		header = models.UsageReportCSVHeader()
+		records = append(records, usage.UsageReportCSVRecord(lf))
+		subject = fmt.Sprintf("Your usage for %s", lf.FormatMonth(from))
+		body = fmt.Sprintf("You uploaded %d documents, processed %d pages and made %d API calls in %s. The attached report contains the details.",
+			usage.DocumentCount, usage.PageCount, usage.APICallCount, lf.FormatMonth(from))
+	default:
+		return nil, fmt.Errorf("unknown report type %q", subscription.ReportType)
+	}
+
+	var buf bytes.Buffer
+	writer := csv.NewWriter(&buf)
+	writer.Comma = lf.CSVDelimiter
+	if err := writer.Write(header); err != nil {
+		return nil, fmt.Errorf("failed to write report: %w", err)
+	}
+	if err := writer.WriteAll(records); err != nil {
+		return nil, fmt.Errorf("failed to write report: %w", err)
+	}
+
+	return &models.Report{
+		Subject:     subject,
+		Body:        body,
+		Filename:    fmt.Sprintf("%s%s-%s.csv", constants.ReportFilenamePrefix, subscription.ReportType, from.Format(constants.ReportDateFormat)),
+		ContentType: constants.ContentTypeCSV,
+		Content:     buf.Bytes(),
+	}, nil
+}
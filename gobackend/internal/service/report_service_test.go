@@ -0,0 +1,249 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/yasinhessnawi1/Hideme_Backend/internal/models"
+	"github.com/yasinhessnawi1/Hideme_Backend/internal/testsupport/fakeclock"
+	"github.com/yasinhessnawi1/Hideme_Backend/internal/utils"
+)
+
+// MockReportRepository is an in-memory implementation of repository.ReportRepository
+type MockReportRepository struct {
+	subscriptions map[int64]*models.ReportSubscription
+	nextID        int64
+	summary       []*models.EntitySummary
+	usage         *models.TenantUsage
+	summaryErr    error
+}
+
+func NewMockReportRepository() *MockReportRepository {
+	return &MockReportRepository{subscriptions: make(map[int64]*models.ReportSubscription)}
+}
+
+func (m *MockReportRepository) Create(ctx context.Context, subscription *models.ReportSubscription) error {
+	for _, existing := range m.subscriptions {
+		if existing.UserID == subscription.UserID && existing.ReportType == subscription.ReportType {
+			return utils.NewDuplicateError("ReportSubscription", "report_type", subscription.ReportType)
+		}
+	}
+	m.nextID++
+	subscription.ID = m.nextID
+	stored := *subscription
+	m.subscriptions[subscription.ID] = &stored
+	return nil
+}
+
+func (m *MockReportRepository) GetByUserID(ctx context.Context, userID int64) ([]*models.ReportSubscription, error) {
+	result := []*models.ReportSubscription{}
+	for _, subscription := range m.subscriptions {
+		if subscription.UserID == userID {
+			result = append(result, subscription)
+		}
+	}
+	return result, nil
+}
+
+func (m *MockReportRepository) Delete(ctx context.Context, id int64, userID int64) error {
+	subscription, ok := m.subscriptions[id]
+	if !ok || subscription.UserID != userID {
+		return utils.NewNotFoundError("ReportSubscription", id)
+	}
+	delete(m.subscriptions, id)
+	return nil
+}
+
+func (m *MockReportRepository) GetDue(ctx context.Context, now time.Time, limit int) ([]*models.ReportSubscription, error) {
+	result := []*models.ReportSubscription{}
+	for _, subscription := range m.subscriptions {
+		if !subscription.NextRunAt.After(now) && len(result) < limit {
+			copied := *subscription
+			result = append(result, &copied)
+		}
+	}
+	return result, nil
+}
+
+func (m *MockReportRepository) MarkSent(ctx context.Context, id int64, sentAt, nextRunAt time.Time) error {
+	subscription := m.subscriptions[id]
+	subscription.LastSentAt = &sentAt
+	subscription.NextRunAt = nextRunAt
+	return nil
+}
+
+func (m *MockReportRepository) GetEntitySummary(ctx context.Context, userID int64, from, to time.Time) ([]*models.EntitySummary, error) {
+	return m.summary, m.summaryErr
+}
+
+func (m *MockReportRepository) GetUsage(ctx context.Context, userID int64, month time.Time) (*models.TenantUsage, error) {
+	if m.usage == nil {
+		return &models.TenantUsage{UserID: userID, UsageMonth: month}, nil
+	}
+	return m.usage, nil
+}
+
+// MockReportSender records the reports it is asked to send
+type MockReportSender struct {
+	sent    []*models.Report
+	sendErr error
+}
+
+func (m *MockReportSender) SendReportEmail(toEmail, toName string, report *models.Report) error {
+	if m.sendErr != nil {
+		return m.sendErr
+	}
+	m.sent = append(m.sent, report)
+	return nil
+}
+
+func TestReportService_Subscribe(t *testing.T) {
+	repo := NewMockReportRepository()
+	service := NewReportService(repo, &MockReportSender{})
+
+	subscription, err := service.Subscribe(context.Background(), 1, &models.ReportSubscriptionCreate{ReportType: models.ReportMonthlyUsage})
+	if err != nil {
+		t.Fatalf("Subscribe() error = %v", err)
+	}
+	if subscription.Format != models.ReportFormatCSV {
+		t.Errorf("Format = %q, want %q", subscription.Format, models.ReportFormatCSV)
+	}
+
+	_, err = service.Subscribe(context.Background(), 1, &models.ReportSubscriptionCreate{ReportType: models.ReportMonthlyUsage})
+	if !errors.Is(err, utils.ErrDuplicate) {
+		t.Errorf("second Subscribe() error = %v, want duplicate error", err)
+	}
+
+	subscriptions, _ := service.ListSubscriptions(context.Background(), 1)
+	if len(subscriptions) != 1 {
+		t.Fatalf("ListSubscriptions() returned %d subscriptions, want 1", len(subscriptions))
+	}
+
+	if err := service.Unsubscribe(context.Background(), 2, subscription.ID); !utils.IsNotFoundError(err) {
+		t.Errorf("Unsubscribe() by another user error = %v, want not found", err)
+	}
+	if err := service.Unsubscribe(context.Background(), 1, subscription.ID); err != nil {
+		t.Errorf("Unsubscribe() error = %v", err)
+	}
+}
+
+func TestReportService_SendDueReports(t *testing.T) {
+	repo := NewMockReportRepository()
+	sender := &MockReportSender{}
+	service := NewReportService(repo, sender)
+	now := time.Date(2024, time.May, 13, 6, 30, 0, 0, time.UTC) // Monday
+	service.SetClock(fakeclock.New(now))
+
+	repo.summary = []*models.EntitySummary{
+		{MethodName: "Presidio", EntityName: "EMAIL_ADDRESS", EntityCount: 3, DocumentCount: 2},
+		{MethodName: "Gliner", EntityName: "PERSON", EntityCount: 4, DocumentCount: 1},
+	}
+	repo.usage = &models.TenantUsage{UsageMonth: time.Date(2024, time.April, 1, 0, 0, 0, 0, time.UTC), DocumentCount: 5, PageCount: 20, APICallCount: 100}
+
+	weekly := &models.ReportSubscription{UserID: 1, ReportType: models.ReportWeeklyEntitySummary, Format: models.ReportFormatCSV,
+		NextRunAt: time.Date(2024, time.May, 13, 6, 0, 0, 0, time.UTC)}
+	monthly := &models.ReportSubscription{UserID: 1, ReportType: models.ReportMonthlyUsage, Format: models.ReportFormatCSV,
+		NextRunAt: time.Date(2024, time.June, 1, 6, 0, 0, 0, time.UTC)}
+	_ = repo.Create(context.Background(), weekly)
+	_ = repo.Create(context.Background(), monthly)
+
+	sent, err := service.SendDueReports(context.Background())
+	if err != nil {
+		t.Fatalf("SendDueReports() error = %v", err)
+	}
+	if sent != 1 || len(sender.sent) != 1 {
+		t.Fatalf("SendDueReports() sent %d reports, want 1", sent)
+	}
+
+	report := sender.sent[0]
+	if report.Filename != "hideme-weekly_entity_summary-2024-05-06.csv" {
+		t.Errorf("Filename = %q", report.Filename)
+	}
+	if !strings.Contains(string(report.Content), "Presidio,EMAIL_ADDRESS,3,2") {
+		t.Errorf("Content = %q, missing summary row", report.Content)
+	}
+	if !strings.Contains(report.Body, "7 sensitive entities") {
+		t.Errorf("Body = %q, missing total", report.Body)
+	}
+
+	stored := repo.subscriptions[weekly.ID]
+	if want := time.Date(2024, time.May, 20, 6, 0, 0, 0, time.UTC); !stored.NextRunAt.Equal(want) {
+		t.Errorf("NextRunAt = %v, want %v", stored.NextRunAt, want)
+	}
+	if stored.LastSentAt == nil || !stored.LastSentAt.Equal(now) {
+		t.Errorf("LastSentAt = %v, want %v", stored.LastSentAt, now)
+	}
+}
+
+func TestReportService_SendDueReports_Failures(t *testing.T) {
+	repo := NewMockReportRepository()
+	sender := &MockReportSender{sendErr: errors.New("sendgrid down")}
+	service := NewReportService(repo, sender)
+	now := time.Date(2024, time.June, 1, 7, 0, 0, 0, time.UTC)
+	service.SetClock(fakeclock.New(now))
+
+	monthly := &models.ReportSubscription{UserID: 1, ReportType: models.ReportMonthlyUsage, Format: models.ReportFormatCSV,
+		NextRunAt: time.Date(2024, time.June, 1, 6, 0, 0, 0, time.UTC)}
+	_ = repo.Create(context.Background(), monthly)
+
+	sent, err := service.SendDueReports(context.Background())
+	if err != nil {
+		t.Fatalf("SendDueReports() error = %v", err)
+	}
+	if sent != 0 {
+		t.Errorf("SendDueReports() sent %d reports, want 0", sent)
+	}
+
+	// The report stays due so it is retried on the next run
+	if stored := repo.subscriptions[monthly.ID]; !stored.NextRunAt.Equal(monthly.NextRunAt) || stored.LastSentAt != nil {
+		t.Errorf("failed report was rescheduled: %+v", stored)
+	}
+
+	// Once sending works, the usage of the previous month is delivered
+	sender.sendErr = nil
+	if sent, _ := service.SendDueReports(context.Background()); sent != 1 {
+		t.Fatalf("SendDueReports() sent %d reports, want 1", sent)
+	}
+	if !strings.Contains(string(sender.sent[0].Content), "2024-05,0,0,0,0") {
+		t.Errorf("Content = %q, want empty May usage", sender.sent[0].Content)
+	}
+}
+
+func TestReportService_SendDueReports_Locale(t *testing.T) {
+	repo := NewMockReportRepository()
+	sender := &MockReportSender{}
+	service := NewReportService(repo, sender)
+	now := time.Date(2024, time.May, 13, 6, 30, 0, 0, time.UTC) // Monday
+	service.SetClock(fakeclock.New(now))
+
+	repo.summary = []*models.EntitySummary{
+		{MethodName: "Presidio", EntityName: "EMAIL_ADDRESS", EntityCount: 3, DocumentCount: 2},
+	}
+
+	subscription, err := service.Subscribe(context.Background(), 1, &models.ReportSubscriptionCreate{
+		ReportType: models.ReportWeeklyEntitySummary,
+		Locale:     models.LocaleGerman,
+	})
+	if err != nil {
+		t.Fatalf("Subscribe() error = %v", err)
+	}
+	repo.subscriptions[subscription.ID].NextRunAt = time.Date(2024, time.May, 13, 6, 0, 0, 0, time.UTC)
+
+	if sent, _ := service.SendDueReports(context.Background()); sent != 1 {
+		t.Fatalf("SendDueReports() sent %d reports, want 1", sent)
+	}
+
+	report := sender.sent[0]
+	if !strings.Contains(string(report.Content), "Presidio;EMAIL_ADDRESS;3;2") {
+		t.Errorf("Content = %q, want semicolon-separated rows", report.Content)
+	}
+	if !strings.Contains(report.Body, "between 06.05.2024 and 12.05.2024") {
+		t.Errorf("Body = %q, want German dates", report.Body)
+	}
+	if report.Filename != "hideme-weekly_entity_summary-2024-05-06.csv" {
+		t.Errorf("Filename = %q, want ISO date", report.Filename)
+	}
+}
@@ -0,0 +1,208 @@
+// Package service provides business logic implementations.
+//
+// This file contains the reference risk scorers used to assess registrations and logins.
+package service
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"strings"
+
+	"github.com/rs/zerolog/log"
+
+	"github.com/yasinhessnawi1/Hideme_Backend/internal/constants"
+	"github.com/yasinhessnawi1/Hideme_Backend/internal/models"
+)
+
+// defaultDisposableEmailDomains lists well-known disposable email providers.
+// Additional domains can be configured through the risk settings.
+var defaultDisposableEmailDomains = []string{
+	"10minutemail.com",
+	"discard.email",
+	"dispostable.com",
+	"getnada.com",
+	"guerrillamail.com",
+	"maildrop.cc",
+	"mailinator.com",
+	"mintemail.com",
+	"sharklasers.com",
+	"temp-mail.org",
+	"tempmail.com",
+	"throwawaymail.com",
+	"trashmail.com",
+	"yopmail.com",
+}
+
+// IPReputationScorer scores requests coming from IP addresses with a bad reputation.
+type IPReputationScorer struct {
+	ips      map[string]struct{}
+	networks []*net.IPNet
+	score    int
+}
+
+// NewIPReputationScorer creates a scorer for a list of IP addresses and CIDR ranges.
+// Entries that are neither a valid IP address nor a valid CIDR range are skipped.
+//
+// Parameters:
+//   - entries: IP addresses and CIDR ranges with a bad reputation
+//   - score: The score given to requests from a listed address
+//
+// Returns:
+//   - A configured IPReputationScorer
+func NewIPReputationScorer(entries []string, score int) *IPReputationScorer {
+	scorer := &IPReputationScorer{
+		ips:   make(map[string]struct{}),
+		score: score,
+	}
+
+	for _, entry := range entries {
+		entry = strings.TrimSpace(entry)
+		if entry == "" {
+			continue
+		}
+
+		if _, network, err := net.ParseCIDR(entry); err == nil {
+			scorer.networks = append(scorer.networks, network)
+			continue
+		}
+
+		if ip := net.ParseIP(entry); ip != nil {
+			scorer.ips[ip.String()] = struct{}{}
+			continue
+		}
+
+		log.Warn().Str("entry", entry).Msg("Skipping invalid IP reputation entry")
+	}
+
+	return scorer
+}
+
+// Name returns the name of the scorer.
+func (s *IPReputationScorer) Name() string {
+	return "ip_reputation"
+}
+
+// Score checks whether the client IP address is on the reputation list.
+//
+// Parameters:
+//   - ctx: Context for the operation
+//   - signals: The request signals to score
+//
+// Returns:
+//   - The configured score if the address is listed, zero otherwise
+//   - An error is never returned
+func (s *IPReputationScorer) Score(ctx context.Context, signals *models.RiskSignals) (*models.RiskScore, error) {
+	result := &models.RiskScore{Scorer: s.Name()}
+
+	ip := net.ParseIP(signals.IPAddress)
+	if ip == nil {
+		return result, nil
+	}
+
+	listed := false
+	if _, ok := s.ips[ip.String()]; ok {
+		listed = true
+	}
+	for _, network := range s.networks {
+		if network.Contains(ip) {
+			listed = true
+			break
+		}
+	}
+
+	if listed {
+		result.Score = s.score
+		result.Reason = "IP address has a bad reputation"
+	}
+
+	return result, nil
+}
+
+// DisposableEmailScorer scores email addresses from disposable email providers.
+type DisposableEmailScorer struct {
+	domains map[string]struct{}
+	score   int
+}
+
+// NewDisposableEmailScorer creates a scorer for the built-in disposable domains
+// extended with the given domains.
+//
+// Parameters:
+//   - extraDomains: Additional disposable email domains
+//   - score: The score given to disposable email addresses
+//
+// Returns:
+//   - A configured DisposableEmailScorer
+func NewDisposableEmailScorer(extraDomains []string, score int) *DisposableEmailScorer {
+	scorer := &DisposableEmailScorer{
+		domains: make(map[string]struct{}),
+		score:   score,
+	}
+
+	for _, domain := range append(defaultDisposableEmailDomains, extraDomains...) {
+		domain = strings.ToLower(strings.TrimSpace(domain))
+		if domain != "" {
+			scorer.domains[domain] = struct{}{}
+		}
+	}
+
+	return scorer
+}
+
+// Name returns the name of the scorer.
+func (s *DisposableEmailScorer) Name() string {
+	return "disposable_email"
+}
+
+// Score checks whether the email address belongs to a disposable email provider.
+// Subdomains of a listed domain are treated as disposable too.
+//
+// Parameters:
+//   - ctx: Context for the operation
+//   - signals: The request signals to score
+//
+// Returns:
+//   - The configured score if the email address is disposable, zero otherwise
+//   - An error is never returned
+func (s *DisposableEmailScorer) Score(ctx context.Context, signals *models.RiskSignals) (*models.RiskScore, error) {
+	result := &models.RiskScore{Scorer: s.Name()}
+
+	at := strings.LastIndex(signals.Email, "@")
+	if at < 0 {
+		return result, nil
+	}
+
+	domain := strings.ToLower(signals.Email[at+1:])
+	for domain != "" {
+		if _, ok := s.domains[domain]; ok {
+			result.Score = s.score
+			result.Reason = fmt.Sprintf("Email domain %s is disposable", domain)
+			return result, nil
+		}
+
+		// Check the parent domain next
+		dot := strings.Index(domain, ".")
+		if dot < 0 {
+			break
+		}
+		domain = domain[dot+1:]
+	}
+
+	return result, nil
+}
+
+// defaultRiskScorers returns the reference risk scorers configured from the risk settings.
+//
+// Parameters:
+//   - blockedIPs: IP addresses and CIDR ranges with a bad reputation
+//   - disposableDomains: Additional disposable email domains
+//
+// Returns:
+//   - The IP reputation and disposable email scorers
+func defaultRiskScorers(blockedIPs, disposableDomains []string) []RiskScorer {
+	return []RiskScorer{
+		NewIPReputationScorer(blockedIPs, constants.RiskScoreIPReputation),
+		NewDisposableEmailScorer(disposableDomains, constants.RiskScoreDisposableEmail),
+	}
+}
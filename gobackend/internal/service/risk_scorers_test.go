@@ -0,0 +1,66 @@
+package service
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/yasinhessnawi1/Hideme_Backend/internal/models"
+)
+
+func TestIPReputationScorer_Score(t *testing.T) {
+	scorer := NewIPReputationScorer([]string{"203.0.113.7", "198.51.100.0/24", "not-an-ip"}, 40)
+
+	tests := []struct {
+		name     string
+		ip       string
+		expected int
+	}{
+		{name: "Listed address", ip: "203.0.113.7", expected: 40},
+		{name: "Address in listed range", ip: "198.51.100.23", expected: 40},
+		{name: "Unlisted address", ip: "192.0.2.1", expected: 0},
+		{name: "Missing address", ip: "", expected: 0},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Act
+			result, err := scorer.Score(context.Background(), &models.RiskSignals{IPAddress: tt.ip})
+
+			// Assert
+			require.NoError(t, err)
+			assert.Equal(t, "ip_reputation", result.Scorer)
+			assert.Equal(t, tt.expected, result.Score)
+		})
+	}
+}
+
+func TestDisposableEmailScorer_Score(t *testing.T) {
+	scorer := NewDisposableEmailScorer([]string{"Burner.Example"}, 50)
+
+	tests := []struct {
+		name     string
+		email    string
+		expected int
+	}{
+		{name: "Built-in disposable domain", email: "someone@mailinator.com", expected: 50},
+		{name: "Configured domain is case-insensitive", email: "someone@BURNER.example", expected: 50},
+		{name: "Subdomain of disposable domain", email: "someone@eu.yopmail.com", expected: 50},
+		{name: "Regular domain", email: "someone@example.com", expected: 0},
+		{name: "Not an email address", email: "someone", expected: 0},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Act
+			result, err := scorer.Score(context.Background(), &models.RiskSignals{Email: tt.email})
+
+			// Assert
+			require.NoError(t, err)
+			assert.Equal(t, "disposable_email", result.Scorer)
+			assert.Equal(t, tt.expected, result.Score)
+		})
+	}
+}
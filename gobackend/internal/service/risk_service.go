@@ -0,0 +1,415 @@
+// Package service provides business logic implementations.
+package service
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/rs/zerolog/log"
+
+	"github.com/yasinhessnawi1/Hideme_Backend/internal/config"
+	"github.com/yasinhessnawi1/Hideme_Backend/internal/constants"
+	"github.com/yasinhessnawi1/Hideme_Backend/internal/models"
+	"github.com/yasinhessnawi1/Hideme_Backend/internal/repository"
+	"github.com/yasinhessnawi1/Hideme_Backend/internal/utils"
+	"github.com/yasinhessnawi1/Hideme_Backend/internal/utils/clock"
+)
+
+// RiskScorer assesses how likely a registration or login is to be fraudulent.
+// Implementations should return a zero score when they find nothing suspicious.
+type RiskScorer interface {
+	// Name returns a short identifier for the scorer, used in logs.
+	Name() string
+
+	// Score assesses the signals of a request.
+	Score(ctx context.Context, signals *models.RiskSignals) (*models.RiskScore, error)
+}
+
+// CaptchaVerifier verifies CAPTCHA responses submitted by clients.
+type CaptchaVerifier interface {
+	// Verify checks a CAPTCHA response token, returning true if it is valid.
+	Verify(ctx context.Context, token string, remoteIP string) (bool, error)
+}
+
+// VerificationEmailSender sends email verification links.
+type VerificationEmailSender interface {
+	// SendVerificationEmail sends an email containing the verification token.
+	SendVerificationEmail(toEmail, toName, token string) error
+}
+
+// RiskService scores registrations and logins and holds back suspicious accounts.
+// The scores of all registered scorers are added up and compared against the policy
+// thresholds to decide whether the client must solve a CAPTCHA, verify their email
+// address, or wait for an administrator to review the account.
+type RiskService struct {
+	holdRepo       repository.AccountHoldRepository
+	policy         *config.RiskSettings
+	emailSender    VerificationEmailSender
+	captcha        CaptchaVerifier
+	scorers        []RiskScorer
+	componentMutex sync.RWMutex
+	clock          clock.Clock
+}
+
+// NewRiskService creates a new RiskService with the reference scorers
+// (IP reputation and disposable email detection) registered.
+//
+// Parameters:
+//   - holdRepo: Repository for account holds
+//   - policy: The risk policy with thresholds and scorer lists
+//   - emailSender: Sender for email verification links
+//
+// Returns:
+//   - A configured RiskService
+func NewRiskService(holdRepo repository.AccountHoldRepository, policy *config.RiskSettings, emailSender VerificationEmailSender) *RiskService {
+	return &RiskService{
+		holdRepo:    holdRepo,
+		policy:      policy,
+		emailSender: emailSender,
+		scorers:     defaultRiskScorers(policy.BlockedIPs, policy.DisposableEmailDomains),
+	}
+}
+
+// SetClock sets the clock email verifications expire by, so tests can check a
+// verification at the instant it expires.
+func (s *RiskService) SetClock(c clock.Clock) {
+	s.clock = c
+}
+
+// now returns the current time of the service's clock.
+func (s *RiskService) now() time.Time {
+	return clock.Or(s.clock).Now()
+}
+
+// RegisterScorer adds a scorer to the assessment.
+//
+// Parameters:
+//   - scorer: The scorer to add
+func (s *RiskService) RegisterScorer(scorer RiskScorer) {
+	s.componentMutex.Lock()
+	defer s.componentMutex.Unlock()
+
+	s.scorers = append(s.scorers, scorer)
+}
+
+// SetCaptchaVerifier sets the verifier used for CAPTCHA challenges.
+// Without a verifier, CAPTCHA challenges are skipped because clients have nothing to solve.
+//
+// Parameters:
+//   - verifier: The CAPTCHA verifier
+func (s *RiskService) SetCaptchaVerifier(verifier CaptchaVerifier) {
+	s.componentMutex.Lock()
+	defer s.componentMutex.Unlock()
+
+	s.captcha = verifier
+}
+
+// Assess runs all scorers and decides which challenge the request must pass.
+// A failing scorer is logged and ignored so that it cannot block sign-ups.
+//
+// Parameters:
+//   - ctx: Context for the operation
+//   - signals: The request signals to score
+//
+// Returns:
+//   - The combined assessment
+func (s *RiskService) Assess(ctx context.Context, signals *models.RiskSignals) *models.RiskAssessment {
+	s.componentMutex.RLock()
+	scorers := s.scorers
+	s.componentMutex.RUnlock()
+
+	assessment := &models.RiskAssessment{}
+	for _, scorer := range scorers {
+		result, err := scorer.Score(ctx, signals)
+		if err != nil {
+			log.Warn().Err(err).Str("scorer", scorer.Name()).Msg("Risk scorer failed")
+			continue
+		}
+
+		if result == nil || result.Score <= 0 {
+			continue
+		}
+
+		assessment.Score += result.Score
+		if result.Reason != "" {
+			assessment.Reasons = append(assessment.Reasons, result.Reason)
+		}
+	}
+
+	if assessment.Score > constants.MaxRiskScore {
+		assessment.Score = constants.MaxRiskScore
+	}
+	assessment.Action = s.actionFor(assessment.Score)
+
+	if assessment.Action != models.RiskActionAllow {
+		log.Info().
+			Str("event", string(signals.Event)).
+			Int("risk_score", assessment.Score).
+			Str("risk_action", string(assessment.Action)).
+			Str("category", constants.LogCategoryAuth).
+			Msg("Risk challenge triggered")
+	}
+
+	return assessment
+}
+
+// CheckRegistration assesses a registration before the account is created.
+// A CAPTCHA challenge is enforced immediately; stricter outcomes are returned so
+// the caller can hold the account with PlaceHold once it exists.
+//
+// Parameters:
+//   - ctx: Context for the operation
+//   - reg: The registration data
+//
+// Returns:
+//   - The assessment
+//   - A challenge error if a valid CAPTCHA response is required but missing
+func (s *RiskService) CheckRegistration(ctx context.Context, reg *models.UserRegistration) (*models.RiskAssessment, error) {
+	assessment := s.Assess(ctx, &models.RiskSignals{
+		Event:     models.RiskEventRegistration,
+		Username:  reg.Username,
+		Email:     reg.Email,
+		IPAddress: reg.ClientIP,
+		UserAgent: reg.UserAgent,
+	})
+
+	if assessment.Action == models.RiskActionCaptcha {
+		if err := s.verifyCaptcha(ctx, reg.CaptchaToken, reg.ClientIP); err != nil {
+			return nil, err
+		}
+	}
+
+	return assessment, nil
+}
+
+// CheckLogin verifies that a user whose password was accepted may sign in.
+// Held accounts are refused until the hold is lifted. Risky logins must solve a
+// CAPTCHA; logins are never held, so a user can't be locked out by their network.
+//
+// Parameters:
+//   - ctx: Context for the operation
+//   - user: The authenticated user
+//   - creds: The submitted credentials
+//
+// Returns:
+//   - A challenge error if the account is held or a CAPTCHA is required
+//   - Other errors for database issues
+func (s *RiskService) CheckLogin(ctx context.Context, user *models.User, creds *models.UserCredentials) error {
+	hold, err := s.holdRepo.GetByUserID(ctx, user.ID)
+	if err != nil && !utils.IsNotFoundError(err) {
+		return fmt.Errorf("failed to check account hold: %w", err)
+	}
+	if hold != nil {
+		return newRiskChallengeError(hold.HoldType)
+	}
+
+	assessment := s.Assess(ctx, &models.RiskSignals{
+		Event:     models.RiskEventLogin,
+		UserID:    user.ID,
+		Username:  user.Username,
+		Email:     user.Email,
+		IPAddress: creds.ClientIP,
+		UserAgent: creds.UserAgent,
+	})
+
+	if assessment.Action != models.RiskActionAllow {
+		return s.verifyCaptcha(ctx, creds.CaptchaToken, creds.ClientIP)
+	}
+
+	return nil
+}
+
+// PlaceHold holds back a newly registered account if its assessment requires it.
+// Email verification holds send a verification link to the user.
+//
+// Parameters:
+//   - ctx: Context for the operation
+//   - user: The registered user
+//   - assessment: The registration assessment
+//
+// Returns:
+//   - An error if the hold could not be stored
+func (s *RiskService) PlaceHold(ctx context.Context, user *models.User, assessment *models.RiskAssessment) error {
+	if assessment.Action != models.RiskActionEmailVerification && assessment.Action != models.RiskActionManualReview {
+		return nil
+	}
+
+	hold := models.NewAccountHold(user.ID, assessment.Action, assessment.Score, strings.Join(assessment.Reasons, "; "))
+
+	var token string
+	if hold.HoldType == models.RiskActionEmailVerification {
+		plainToken, tokenHash, err := repository.GenerateToken()
+		if err != nil {
+			return fmt.Errorf("failed to generate verification token: %w", err)
+		}
+		expiresAt := s.now().Add(constants.EmailVerificationTokenExpiry)
+		hold.TokenHash = tokenHash
+		hold.ExpiresAt = &expiresAt
+		token = plainToken
+	}
+
+	if err := s.holdRepo.Create(ctx, hold); err != nil {
+		return fmt.Errorf("failed to hold account: %w", err)
+	}
+
+	log.Info().
+		Int64("user_id", user.ID).
+		Str("hold_type", string(hold.HoldType)).
+		Int("risk_score", hold.RiskScore).
+		Str("category", constants.LogCategoryAuth).
+		Msg("Account held by risk scoring")
+
+	// A failed email is logged only; the user can ask an administrator to release the hold
+	if token != "" && s.emailSender != nil {
+		if err := s.emailSender.SendVerificationEmail(user.Email, user.Username, token); err != nil {
+			log.Error().Err(err).Int64("user_id", user.ID).Msg("Failed to send verification email")
+		}
+	}
+
+	return nil
+}
+
+// VerifyEmail lifts an email verification hold using the token sent by email.
+//
+// Parameters:
+//   - ctx: Context for the operation
+//   - token: The plain verification token
+//
+// Returns:
+//   - InvalidTokenError if the token is unknown
+//   - ExpiredTokenError if the token has expired
+//   - Other errors for database issues
+func (s *RiskService) VerifyEmail(ctx context.Context, token string) error {
+	hash := sha256.Sum256([]byte(token))
+
+	hold, err := s.holdRepo.GetByTokenHash(ctx, hex.EncodeToString(hash[:]))
+	if err != nil {
+		if utils.IsNotFoundError(err) {
+			return utils.NewInvalidTokenError()
+		}
+		return err
+	}
+
+	if hold.HoldType != models.RiskActionEmailVerification {
+		return utils.NewInvalidTokenError()
+	}
+
+	if hold.IsTokenExpired() {
+		return utils.NewExpiredTokenError()
+	}
+
+	if err := s.holdRepo.Delete(ctx, hold.UserID); err != nil {
+		return fmt.Errorf("failed to lift account hold: %w", err)
+	}
+
+	log.Info().
+		Int64("user_id", hold.UserID).
+		Str("category", constants.LogCategoryAuth).
+		Msg("Email verified, account hold lifted")
+
+	return nil
+}
+
+// ListHolds retrieves all held accounts for administrator review.
+//
+// Parameters:
+//   - ctx: Context for the operation
+//
+// Returns:
+//   - The account holds
+//   - An error if retrieval fails
+func (s *RiskService) ListHolds(ctx context.Context) ([]*models.AccountHold, error) {
+	holds, err := s.holdRepo.List(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list account holds: %w", err)
+	}
+
+	return holds, nil
+}
+
+// ReleaseHold lifts the hold on an account after administrator review.
+//
+// Parameters:
+//   - ctx: Context for the operation
+//   - userID: The ID of the held user
+//
+// Returns:
+//   - NotFoundError if the account is not held
+//   - Other errors for database issues
+func (s *RiskService) ReleaseHold(ctx context.Context, userID int64) error {
+	if err := s.holdRepo.Delete(ctx, userID); err != nil {
+		return err
+	}
+
+	log.Info().
+		Int64("user_id", userID).
+		Str("category", constants.LogCategoryAdmin).
+		Msg("Account hold released by administrator")
+
+	return nil
+}
+
+// actionFor maps a risk score to the strictest challenge whose threshold it reaches.
+func (s *RiskService) actionFor(score int) models.RiskAction {
+	switch {
+	case score >= s.policy.ManualReviewThreshold:
+		return models.RiskActionManualReview
+	case score >= s.policy.EmailVerificationThreshold:
+		return models.RiskActionEmailVerification
+	case score >= s.policy.CaptchaThreshold:
+		return models.RiskActionCaptcha
+	default:
+		return models.RiskActionAllow
+	}
+}
+
+// verifyCaptcha checks a CAPTCHA response, returning a challenge error if it is missing or invalid.
+func (s *RiskService) verifyCaptcha(ctx context.Context, token string, remoteIP string) error {
+	s.componentMutex.RLock()
+	verifier := s.captcha
+	s.componentMutex.RUnlock()
+
+	if verifier == nil {
+		log.Debug().Msg("No CAPTCHA verifier configured, skipping CAPTCHA challenge")
+		return nil
+	}
+
+	if token == "" {
+		return newRiskChallengeError(models.RiskActionCaptcha)
+	}
+
+	valid, err := verifier.Verify(ctx, token, remoteIP)
+	if err != nil {
+		return fmt.Errorf("failed to verify CAPTCHA: %w", err)
+	}
+	if !valid {
+		return newRiskChallengeError(models.RiskActionCaptcha)
+	}
+
+	return nil
+}
+
+// newRiskChallengeError creates the error returned when a client must complete a challenge.
+// The challenge is included in the error details so clients can react to it.
+func newRiskChallengeError(action models.RiskAction) *utils.AppError {
+	message := constants.MsgCaptchaRequired
+	switch action {
+	case models.RiskActionEmailVerification:
+		message = constants.MsgEmailVerificationRequired
+	case models.RiskActionManualReview:
+		message = constants.MsgManualReviewRequired
+	}
+
+	return &utils.AppError{
+		Err:        utils.ErrForbidden,
+		StatusCode: http.StatusForbidden,
+		Message:    message,
+		Details:    map[string]any{"challenge": string(action)},
+	}
+}
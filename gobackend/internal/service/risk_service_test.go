@@ -0,0 +1,311 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/yasinhessnawi1/Hideme_Backend/internal/config"
+	"github.com/yasinhessnawi1/Hideme_Backend/internal/models"
+	"github.com/yasinhessnawi1/Hideme_Backend/internal/utils"
+)
+
+// MockAccountHoldRepository is an in-memory implementation of repository.AccountHoldRepository
+type MockAccountHoldRepository struct {
+	holds map[int64]*models.AccountHold
+}
+
+func NewMockAccountHoldRepository() *MockAccountHoldRepository {
+	return &MockAccountHoldRepository{holds: make(map[int64]*models.AccountHold)}
+}
+
+func (m *MockAccountHoldRepository) Create(ctx context.Context, hold *models.AccountHold) error {
+	stored := *hold
+	m.holds[hold.UserID] = &stored
+	return nil
+}
+
+func (m *MockAccountHoldRepository) GetByUserID(ctx context.Context, userID int64) (*models.AccountHold, error) {
+	hold, ok := m.holds[userID]
+	if !ok {
+		return nil, utils.NewNotFoundError("AccountHold", userID)
+	}
+	return hold, nil
+}
+
+func (m *MockAccountHoldRepository) GetByTokenHash(ctx context.Context, tokenHash string) (*models.AccountHold, error) {
+	for _, hold := range m.holds {
+		if hold.TokenHash != "" && hold.TokenHash == tokenHash {
+			return hold, nil
+		}
+	}
+	return nil, utils.NewNotFoundError("AccountHold", "token")
+}
+
+func (m *MockAccountHoldRepository) List(ctx context.Context) ([]*models.AccountHold, error) {
+	result := []*models.AccountHold{}
+	for _, hold := range m.holds {
+		result = append(result, hold)
+	}
+	return result, nil
+}
+
+func (m *MockAccountHoldRepository) Delete(ctx context.Context, userID int64) error {
+	if _, ok := m.holds[userID]; !ok {
+		return utils.NewNotFoundError("AccountHold", userID)
+	}
+	delete(m.holds, userID)
+	return nil
+}
+
+// fakeVerificationEmailSender records the verification tokens it was asked to send
+type fakeVerificationEmailSender struct {
+	tokens map[string]string
+}
+
+func (f *fakeVerificationEmailSender) SendVerificationEmail(toEmail, toName, token string) error {
+	f.tokens[toEmail] = token
+	return nil
+}
+
+// fakeCaptchaVerifier accepts a single valid token
+type fakeCaptchaVerifier struct {
+	validToken string
+}
+
+func (f *fakeCaptchaVerifier) Verify(ctx context.Context, token string, remoteIP string) (bool, error) {
+	return token == f.validToken, nil
+}
+
+// fixedScorer returns a fixed score, or an error if one is set
+type fixedScorer struct {
+	score int
+	err   error
+}
+
+func (f *fixedScorer) Name() string { return "fixed" }
+
+func (f *fixedScorer) Score(ctx context.Context, signals *models.RiskSignals) (*models.RiskScore, error) {
+	if f.err != nil {
+		return nil, f.err
+	}
+	return &models.RiskScore{Scorer: f.Name(), Score: f.score, Reason: "fixed score"}, nil
+}
+
+func setupRiskService() (*RiskService, *MockAccountHoldRepository, *fakeVerificationEmailSender) {
+	repo := NewMockAccountHoldRepository()
+	sender := &fakeVerificationEmailSender{tokens: make(map[string]string)}
+	service := NewRiskService(repo, &config.RiskSettings{
+		CaptchaThreshold:           30,
+		EmailVerificationThreshold: 50,
+		ManualReviewThreshold:      80,
+		BlockedIPs:                 []string{"203.0.113.7"},
+	}, sender)
+	return service, repo, sender
+}
+
+// assertChallenge checks that an error asks the client to complete the given challenge
+func assertChallenge(t *testing.T, err error, challenge models.RiskAction) {
+	t.Helper()
+	var appErr *utils.AppError
+	require.True(t, errors.As(err, &appErr), "expected an AppError, got %v", err)
+	assert.Equal(t, http.StatusForbidden, appErr.StatusCode)
+	assert.Equal(t, string(challenge), appErr.Details["challenge"])
+}
+
+func TestRiskService_Assess(t *testing.T) {
+	tests := []struct {
+		name     string
+		signals  *models.RiskSignals
+		expected models.RiskAction
+		score    int
+	}{
+		{
+			name:     "Clean request",
+			signals:  &models.RiskSignals{Email: "user@example.com", IPAddress: "192.0.2.1"},
+			expected: models.RiskActionAllow,
+			score:    0,
+		},
+		{
+			name:     "Listed IP address",
+			signals:  &models.RiskSignals{Email: "user@example.com", IPAddress: "203.0.113.7"},
+			expected: models.RiskActionCaptcha,
+			score:    40,
+		},
+		{
+			name:     "Disposable email",
+			signals:  &models.RiskSignals{Email: "user@mailinator.com", IPAddress: "192.0.2.1"},
+			expected: models.RiskActionEmailVerification,
+			score:    50,
+		},
+		{
+			name:     "Disposable email from listed IP address",
+			signals:  &models.RiskSignals{Email: "user@mailinator.com", IPAddress: "203.0.113.7"},
+			expected: models.RiskActionManualReview,
+			score:    90,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			service, _, _ := setupRiskService()
+
+			// Act
+			assessment := service.Assess(context.Background(), tt.signals)
+
+			// Assert
+			assert.Equal(t, tt.expected, assessment.Action)
+			assert.Equal(t, tt.score, assessment.Score)
+		})
+	}
+}
+
+func TestRiskService_Assess_CustomScorers(t *testing.T) {
+	service, _, _ := setupRiskService()
+	service.RegisterScorer(&fixedScorer{err: errors.New("scorer unavailable")})
+	service.RegisterScorer(&fixedScorer{score: 200})
+
+	// Act
+	assessment := service.Assess(context.Background(), &models.RiskSignals{})
+
+	// Assert: the failing scorer is ignored and the total is capped
+	assert.Equal(t, 100, assessment.Score)
+	assert.Equal(t, models.RiskActionManualReview, assessment.Action)
+	assert.Equal(t, []string{"fixed score"}, assessment.Reasons)
+}
+
+func TestRiskService_CheckRegistration(t *testing.T) {
+	ctx := context.Background()
+	reg := &models.UserRegistration{Username: "trial", Email: "trial@example.com", ClientIP: "203.0.113.7"}
+
+	t.Run("Captcha skipped without verifier", func(t *testing.T) {
+		service, _, _ := setupRiskService()
+
+		assessment, err := service.CheckRegistration(ctx, reg)
+
+		require.NoError(t, err)
+		assert.Equal(t, models.RiskActionCaptcha, assessment.Action)
+	})
+
+	t.Run("Captcha missing", func(t *testing.T) {
+		service, _, _ := setupRiskService()
+		service.SetCaptchaVerifier(&fakeCaptchaVerifier{validToken: "solved"})
+
+		_, err := service.CheckRegistration(ctx, reg)
+
+		assertChallenge(t, err, models.RiskActionCaptcha)
+	})
+
+	t.Run("Captcha solved", func(t *testing.T) {
+		service, _, _ := setupRiskService()
+		service.SetCaptchaVerifier(&fakeCaptchaVerifier{validToken: "solved"})
+		solved := *reg
+		solved.CaptchaToken = "solved"
+
+		assessment, err := service.CheckRegistration(ctx, &solved)
+
+		require.NoError(t, err)
+		assert.Equal(t, models.RiskActionCaptcha, assessment.Action)
+	})
+}
+
+func TestRiskService_EmailVerificationHold(t *testing.T) {
+	ctx := context.Background()
+	service, repo, sender := setupRiskService()
+	user := &models.User{ID: 7, Username: "trial", Email: "trial@mailinator.com"}
+
+	// Register with a disposable email address
+	assessment, err := service.CheckRegistration(ctx, &models.UserRegistration{Email: user.Email})
+	require.NoError(t, err)
+	require.Equal(t, models.RiskActionEmailVerification, assessment.Action)
+	require.NoError(t, service.PlaceHold(ctx, user, assessment))
+
+	// The account is held and a verification email was sent
+	hold, err := repo.GetByUserID(ctx, user.ID)
+	require.NoError(t, err)
+	assert.Equal(t, models.RiskActionEmailVerification, hold.HoldType)
+	assert.NotNil(t, hold.ExpiresAt)
+	token := sender.tokens[user.Email]
+	require.NotEmpty(t, token)
+	assert.NotEqual(t, token, hold.TokenHash, "only the token hash may be stored")
+
+	// Logging in is refused until the email address is verified
+	err = service.CheckLogin(ctx, user, &models.UserCredentials{})
+	assertChallenge(t, err, models.RiskActionEmailVerification)
+
+	// An unknown token is rejected
+	err = service.VerifyEmail(ctx, "wrong")
+	assert.True(t, errors.Is(err, utils.ErrInvalidToken))
+
+	// Verifying the email lifts the hold
+	require.NoError(t, service.VerifyEmail(ctx, token))
+	assert.NoError(t, service.CheckLogin(ctx, user, &models.UserCredentials{}))
+}
+
+func TestRiskService_VerifyEmail_Expired(t *testing.T) {
+	ctx := context.Background()
+	service, repo, sender := setupRiskService()
+	user := &models.User{ID: 7, Email: "trial@example.com"}
+
+	require.NoError(t, service.PlaceHold(ctx, user, &models.RiskAssessment{Score: 60, Action: models.RiskActionEmailVerification}))
+	past := time.Now().Add(-time.Minute)
+	repo.holds[user.ID].ExpiresAt = &past
+
+	// Act
+	err := service.VerifyEmail(ctx, sender.tokens[user.Email])
+
+	// Assert
+	assert.True(t, errors.Is(err, utils.ErrExpiredToken))
+	_, err = repo.GetByUserID(ctx, user.ID)
+	assert.NoError(t, err, "the hold must remain in place")
+}
+
+func TestRiskService_ManualReviewHold(t *testing.T) {
+	ctx := context.Background()
+	service, repo, sender := setupRiskService()
+	user := &models.User{ID: 9, Email: "trial@example.com"}
+
+	require.NoError(t, service.PlaceHold(ctx, user, &models.RiskAssessment{Score: 90, Action: models.RiskActionManualReview}))
+
+	// No email is sent for manual review, and the user cannot sign in
+	assert.Empty(t, sender.tokens)
+	assertChallenge(t, service.CheckLogin(ctx, user, &models.UserCredentials{}), models.RiskActionManualReview)
+
+	holds, err := service.ListHolds(ctx)
+	require.NoError(t, err)
+	assert.Len(t, holds, 1)
+
+	// An administrator releases the hold
+	require.NoError(t, service.ReleaseHold(ctx, user.ID))
+	assert.Empty(t, repo.holds)
+	assert.True(t, utils.IsNotFoundError(service.ReleaseHold(ctx, user.ID)))
+}
+
+func TestRiskService_PlaceHold_NotRequired(t *testing.T) {
+	service, repo, _ := setupRiskService()
+
+	err := service.PlaceHold(context.Background(), &models.User{ID: 1}, &models.RiskAssessment{Score: 40, Action: models.RiskActionCaptcha})
+
+	assert.NoError(t, err)
+	assert.Empty(t, repo.holds)
+}
+
+func TestRiskService_CheckLogin_Captcha(t *testing.T) {
+	ctx := context.Background()
+	service, repo, _ := setupRiskService()
+	service.SetCaptchaVerifier(&fakeCaptchaVerifier{validToken: "solved"})
+	user := &models.User{ID: 3, Email: "user@mailinator.com"}
+
+	// A risky login must solve a CAPTCHA, but is never held
+	err := service.CheckLogin(ctx, user, &models.UserCredentials{ClientIP: "203.0.113.7"})
+	assertChallenge(t, err, models.RiskActionCaptcha)
+	assert.Empty(t, repo.holds)
+
+	err = service.CheckLogin(ctx, user, &models.UserCredentials{ClientIP: "203.0.113.7", CaptchaToken: "solved"})
+	assert.NoError(t, err)
+}
@@ -0,0 +1,240 @@
+// Package service provides business logic implementations.
+package service
+
+import (
+	"context"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/rs/zerolog/log"
+
+	"github.com/yasinhessnawi1/Hideme_Backend/internal/constants"
+	"github.com/yasinhessnawi1/Hideme_Backend/internal/models"
+	"github.com/yasinhessnawi1/Hideme_Backend/internal/repository"
+	"github.com/yasinhessnawi1/Hideme_Backend/internal/utils/clock"
+)
+
+// ruleSource provides the ban list words and search patterns of a user.
+// It is implemented by SettingsService.
+type ruleSource interface {
+	GetSearchPatterns(ctx context.Context, userID int64) ([]*models.SearchPattern, error)
+	GetBanList(ctx context.Context, userID int64) (*models.BanListWithWords, error)
+}
+
+// RuleEffectivenessService tracks which of a user's ban list words and search patterns
+// produce detections, so that rules nobody needs anymore can be found and removed.
+//
+// Each saved document's detected texts are matched against the user's rules as they are
+// at that moment: a rule hits a detection when its text occurs in the detected text, ignoring
+// case except for case-sensitive patterns. AI search patterns describe what to look for
+// rather than the text itself, so their detections can't be attributed and they are not tracked.
+type RuleEffectivenessService struct {
+	hitRepo repository.RuleHitRepository
+	rules   ruleSource
+	clock   clock.Clock
+}
+
+// NewRuleEffectivenessService creates a new RuleEffectivenessService.
+//
+// Parameters:
+//   - hitRepo: Repository counting the detections per rule
+//   - rules: Source of the users' ban list words and search patterns
+//
+// Returns:
+//   - A configured RuleEffectivenessService
+func NewRuleEffectivenessService(hitRepo repository.RuleHitRepository, rules ruleSource) *RuleEffectivenessService {
+	return &RuleEffectivenessService{
+		hitRepo: hitRepo,
+		rules:   rules,
+	}
+}
+
+// SetClock sets the clock rule matches are recorded and rules go stale by.
+func (s *RuleEffectivenessService) SetClock(c clock.Clock) {
+	s.clock = c
+}
+
+// now returns the current time of the service's clock.
+func (s *RuleEffectivenessService) now() time.Time {
+	return clock.Or(s.clock).Now()
+}
+
+// trackedRule is a ban list word or search pattern whose detections are counted.
+type trackedRule struct {
+	ruleType      string
+	text          string
+	caseSensitive bool
+}
+
+// matches reports whether the rule hits a detected text.
+func (r trackedRule) matches(detected string) bool {
+	if r.caseSensitive {
+		return strings.Contains(detected, r.text)
+	}
+	return strings.Contains(strings.ToLower(detected), strings.ToLower(r.text))
+}
+
+// RecordDetections counts the detections of a saved document towards the user's rules.
+// Failures are logged rather than returned, so that they never fail the upload.
+//
+// Parameters:
+//   - ctx: Context for the operation
+//   - userID: The ID of the user who saved the document
+//   - redactionSchema: The detections of the document
+func (s *RuleEffectivenessService) RecordDetections(ctx context.Context, userID int64, redactionSchema models.RedactionMapping) {
+	rules, err := s.trackedRules(ctx, userID)
+	if err != nil {
+		log.Warn().Err(err).Int64("user_id", userID).Msg("Failed to load rules for effectiveness tracking")
+		return
+	}
+	if len(rules) == 0 {
+		return
+	}
+
+	now := s.now()
+	hits := make([]*models.RuleHit, len(rules))
+	for i, rule := range rules {
+		hits[i] = &models.RuleHit{UserID: userID, RuleType: rule.ruleType, RuleText: rule.text, TrackedSince: now}
+	}
+	for _, page := range redactionSchema.Pages {
+		for _, sensitive := range page.Sensitive {
+			if sensitive.OriginalText == "" {
+				continue
+			}
+			for i, rule := range rules {
+				if rule.matches(sensitive.OriginalText) {
+					hits[i].HitCount++
+					hits[i].LastHitAt = &now
+				}
+			}
+		}
+	}
+
+	if err := s.hitRepo.Record(ctx, userID, hits); err != nil {
+		log.Warn().Err(err).Int64("user_id", userID).Msg("Failed to record rule hits")
+	}
+}
+
+// trackedRules returns the user's ban list words and search patterns whose detections are counted.
+func (s *RuleEffectivenessService) trackedRules(ctx context.Context, userID int64) ([]trackedRule, error) {
+	banList, err := s.rules.GetBanList(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	patterns, err := s.rules.GetSearchPatterns(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	seen := make(map[trackedRule]bool)
+	var rules []trackedRule
+	add := func(rule trackedRule) {
+		key := trackedRule{ruleType: rule.ruleType, text: rule.text}
+		if rule.text == "" || seen[key] {
+			return
+		}
+		seen[key] = true
+		rules = append(rules, rule)
+	}
+
+	for _, word := range banList.Words {
+		add(trackedRule{ruleType: constants.RuleTypeBanListWord, text: word})
+	}
+	for _, pattern := range patterns {
+		if pattern.PatternType == models.AISearch {
+			continue
+		}
+		add(trackedRule{
+			ruleType:      constants.RuleTypeSearchPattern,
+			text:          pattern.PatternText,
+			caseSensitive: pattern.PatternType == models.CaseSensitive,
+		})
+	}
+
+	return rules, nil
+}
+
+// GetEffectiveness ranks a user's current ban list words and search patterns by the
+// detections they produced and suggests the stale ones for removal. A rule is stale once
+// it has been tracked for constants.RuleStaleAfter without a detection in that time.
+//
+// Parameters:
+//   - ctx: Context for the operation
+//   - userID: The ID of the user
+//
+// Returns:
+//   - The rules, most hits first, and the stale ones
+//   - An error if the rules or their counts can't be retrieved
+func (s *RuleEffectivenessService) GetEffectiveness(ctx context.Context, userID int64) (*models.RuleEffectivenessReport, error) {
+	banList, err := s.rules.GetBanList(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	patterns, err := s.rules.GetSearchPatterns(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	hits, err := s.hitRepo.ListByUser(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	counts := make(map[[2]string]*models.RuleHit, len(hits))
+	for _, hit := range hits {
+		counts[[2]string{hit.RuleType, hit.RuleText}] = hit
+	}
+
+	staleBefore := s.now().Add(-constants.RuleStaleAfter)
+	report := &models.RuleEffectivenessReport{
+		StaleAfterDays: int(constants.RuleStaleAfter / (24 * time.Hour)),
+		Rules:          []*models.RuleEffectiveness{},
+		Suggestions:    []*models.RuleEffectiveness{},
+	}
+	add := func(rule *models.RuleEffectiveness) {
+		if hit, ok := counts[[2]string{rule.RuleType, rule.Text}]; ok && rule.Tracked {
+			rule.HitCount = hit.HitCount
+			rule.LastHitAt = hit.LastHitAt
+			trackedSince := hit.TrackedSince
+			rule.TrackedSince = &trackedSince
+
+			lastActive := hit.TrackedSince
+			if hit.LastHitAt != nil {
+				lastActive = *hit.LastHitAt
+			}
+			rule.Stale = lastActive.Before(staleBefore)
+		}
+		report.Rules = append(report.Rules, rule)
+	}
+
+	for _, word := range banList.Words {
+		add(&models.RuleEffectiveness{RuleType: constants.RuleTypeBanListWord, Text: word, Tracked: true})
+	}
+	for _, pattern := range patterns {
+		add(&models.RuleEffectiveness{
+			RuleType:    constants.RuleTypeSearchPattern,
+			Text:        pattern.PatternText,
+			PatternID:   pattern.ID,
+			PatternType: pattern.PatternType,
+			Tracked:     pattern.PatternType != models.AISearch,
+		})
+	}
+
+	sort.SliceStable(report.Rules, func(i, j int) bool {
+		a, b := report.Rules[i], report.Rules[j]
+		if a.HitCount != b.HitCount {
+			return a.HitCount > b.HitCount
+		}
+		if (a.LastHitAt == nil) != (b.LastHitAt == nil) {
+			return a.LastHitAt != nil
+		}
+		return a.LastHitAt != nil && a.LastHitAt.After(*b.LastHitAt)
+	})
+	for _, rule := range report.Rules {
+		if rule.Stale {
+			report.Suggestions = append(report.Suggestions, rule)
+		}
+	}
+
+	return report, nil
+}
@@ -0,0 +1,133 @@
+package service
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/yasinhessnawi1/Hideme_Backend/internal/constants"
+	"github.com/yasinhessnawi1/Hideme_Backend/internal/models"
+	"github.com/yasinhessnawi1/Hideme_Backend/internal/testsupport/fakeclock"
+)
+
+// MockRuleHitRepository is an in-memory implementation of repository.RuleHitRepository
+type MockRuleHitRepository struct {
+	hits map[[2]string]*models.RuleHit
+}
+
+func (m *MockRuleHitRepository) Record(ctx context.Context, userID int64, hits []*models.RuleHit) error {
+	for _, hit := range hits {
+		key := [2]string{hit.RuleType, hit.RuleText}
+		stored, ok := m.hits[key]
+		if !ok {
+			copied := *hit
+			m.hits[key] = &copied
+			continue
+		}
+		stored.HitCount += hit.HitCount
+		if hit.LastHitAt != nil {
+			stored.LastHitAt = hit.LastHitAt
+		}
+	}
+	return nil
+}
+
+func (m *MockRuleHitRepository) ListByUser(ctx context.Context, userID int64) ([]*models.RuleHit, error) {
+	var hits []*models.RuleHit
+	for _, hit := range m.hits {
+		copied := *hit
+		hits = append(hits, &copied)
+	}
+	return hits, nil
+}
+
+func (m *MockRuleHitRepository) Rebuild(ctx context.Context) (int64, error) {
+	return 0, nil
+}
+
+// MockRuleSource returns a fixed ban list and search patterns
+type MockRuleSource struct {
+	words    []string
+	options  map[string]models.BanListMatchOptions
+	patterns []*models.SearchPattern
+}
+
+func (m *MockRuleSource) GetSearchPatterns(ctx context.Context, userID int64) ([]*models.SearchPattern, error) {
+	return m.patterns, nil
+}
+
+func (m *MockRuleSource) GetBanList(ctx context.Context, userID int64) (*models.BanListWithWords, error) {
+	return &models.BanListWithWords{ID: 1, Words: m.words, Options: m.options}, nil
+}
+
+func ruleDetections(texts ...string) models.RedactionMapping {
+	page := models.Page{PageNumber: 1}
+	for _, text := range texts {
+		page.Sensitive = append(page.Sensitive, models.Sensitive{OriginalText: text})
+	}
+	return models.RedactionMapping{Pages: []models.Page{page}}
+}
+
+func TestRuleEffectivenessService(t *testing.T) {
+	hitRepo := &MockRuleHitRepository{hits: make(map[[2]string]*models.RuleHit)}
+	rules := &MockRuleSource{
+		words: []string{"oslo", "bergen"},
+		patterns: []*models.SearchPattern{
+			{ID: 1, PatternType: models.Normal, PatternText: "nordmann"},
+			{ID: 2, PatternType: models.CaseSensitive, PatternText: "KARI"},
+			{ID: 3, PatternType: models.AISearch, PatternText: "health information"},
+		},
+	}
+	svc := NewRuleEffectivenessService(hitRepo, rules)
+	ctx := context.Background()
+
+	start := time.Date(2026, 1, 1, 8, 0, 0, 0, time.UTC)
+	clk := fakeclock.New(start)
+	svc.SetClock(clk)
+	svc.RecordDetections(ctx, 1, ruleDetections("Ola Nordmann", "Kari Nordmann", "Oslo"))
+
+	later := start.Add(constants.RuleStaleAfter + 24*time.Hour)
+	clk.Set(later)
+	svc.RecordDetections(ctx, 1, ruleDetections("Oslo University Hospital"))
+
+	report, err := svc.GetEffectiveness(ctx, 1)
+	if err != nil {
+		t.Fatalf("GetEffectiveness returned error: %v", err)
+	}
+
+	var order []string
+	for _, rule := range report.Rules {
+		order = append(order, rule.Text)
+	}
+	if len(order) != 5 || order[0] != "oslo" || order[1] != "nordmann" {
+		t.Fatalf("Expected the rules ranked by hits and last hit, got %v", order)
+	}
+	if report.Rules[0].HitCount != 2 || report.Rules[1].HitCount != 2 {
+		t.Errorf("Expected two hits each, got %d and %d", report.Rules[0].HitCount, report.Rules[1].HitCount)
+	}
+
+	byText := make(map[string]*models.RuleEffectiveness)
+	for _, rule := range report.Rules {
+		byText[rule.Text] = rule
+	}
+	if byText["KARI"].HitCount != 0 {
+		t.Error("Expected the case-sensitive pattern not to match a different case")
+	}
+	if byText["health information"].Tracked || byText["health information"].Stale {
+		t.Error("Expected AI search patterns not to be tracked")
+	}
+	if byText["nordmann"].PatternID != 1 {
+		t.Errorf("Expected the pattern ID, got %d", byText["nordmann"].PatternID)
+	}
+
+	var suggested []string
+	for _, rule := range report.Suggestions {
+		suggested = append(suggested, rule.Text)
+	}
+	if len(suggested) != 3 || suggested[0] != "nordmann" {
+		t.Errorf("Expected nordmann, bergen and KARI suggested for removal, got %v", suggested)
+	}
+	if byText["oslo"].Stale {
+		t.Error("Expected a rule with a recent hit not to be stale")
+	}
+}
@@ -0,0 +1,131 @@
+// Package service provides business logic implementations.
+//
+// This file implements the checking of search patterns. The text of a normal or
+// case-sensitive pattern is a regular expression, matched by the detection service with a
+// backtracking engine; patterns are compiled before they are saved, so that broken ones are
+// reported to the user rather than failing at detection time, and patterns nesting
+// unbounded repetitions, which can take exponentially long to match, are refused.
+package service
+
+import (
+	"errors"
+	"fmt"
+	"regexp"
+	"regexp/syntax"
+	"unicode/utf8"
+
+	"github.com/yasinhessnawi1/Hideme_Backend/internal/constants"
+	"github.com/yasinhessnawi1/Hideme_Backend/internal/models"
+	"github.com/yasinhessnawi1/Hideme_Backend/internal/utils"
+)
+
+// compileSearchPattern compiles the text of a search pattern as the detection service
+// matches it: normal patterns ignore case, case-sensitive ones don't.
+//
+// Parameters:
+//   - patternType: The search strategy of the pattern
+//   - patternText: The text of the pattern
+//
+// Returns:
+//   - The compiled pattern; nil for AI search patterns, whose text is not a regular expression
+//   - ValidationError if the text is not a valid regular expression or nests unbounded repetitions
+func compileSearchPattern(patternType models.PatternType, patternText string) (*regexp.Regexp, error) {
+	if patternType == models.AISearch {
+		return nil, nil
+	}
+
+	parsed, err := syntax.Parse(patternText, syntax.Perl)
+	if err != nil {
+		message := constants.MsgSearchPatternInvalid
+		var syntaxErr *syntax.Error
+		if errors.As(err, &syntaxErr) {
+			message = fmt.Sprintf("%s: %s", message, syntaxErr.Code)
+		}
+		return nil, utils.NewValidationError("pattern_text", message)
+	}
+	if nestsUnboundedRepeat(parsed, false) {
+		return nil, utils.NewValidationError("pattern_text", constants.MsgSearchPatternUnsafe)
+	}
+
+	if patternType != models.CaseSensitive {
+		patternText = "(?i)" + patternText
+	}
+	return regexp.Compile(patternText)
+}
+
+// nestsUnboundedRepeat reports whether an expression repeats part of itself within an
+// unbounded repetition, such as (a+)+ or (\w+\s?)*. A backtracking engine tries every way
+// of splitting the input between the repetitions before it gives up, which takes
+// exponentially long on inputs that almost match. Optional parts and parts repeated a fixed
+// number of times, such as \d{3}, leave no choice and don't count as repetitions.
+func nestsUnboundedRepeat(re *syntax.Regexp, insideUnbounded bool) bool {
+	repeats := false
+	unbounded := false
+	switch re.Op {
+	case syntax.OpStar, syntax.OpPlus:
+		repeats, unbounded = true, true
+	case syntax.OpRepeat:
+		repeats, unbounded = re.Min != re.Max, re.Max == -1
+	}
+	if repeats && insideUnbounded {
+		return true
+	}
+
+	for _, sub := range re.Sub {
+		if nestsUnboundedRepeat(sub, insideUnbounded || unbounded) {
+			return true
+		}
+	}
+	return false
+}
+
+// TestSearchPattern tries out a search pattern on sample text, so users see what it
+// matches before they save it.
+//
+// Parameters:
+//   - test: The pattern and the sample text
+//
+// Returns:
+//   - The matches of the pattern in the sample text, at most constants.SearchPatternTestMaxMatches
+//   - ValidationError if the pattern is an AI search pattern, is not a valid regular
+//     expression or nests unbounded repetitions
+func (s *SettingsService) TestSearchPattern(test *models.SearchPatternTest) (*models.SearchPatternTestResult, error) {
+	patternType := models.PatternType(test.PatternType)
+	if !models.ValidatePatternType(patternType) {
+		return nil, utils.NewValidationError("pattern_type", "Invalid pattern type")
+	}
+	if patternType == models.AISearch {
+		return nil, utils.NewValidationError("pattern_type", constants.MsgSearchPatternNotTestable)
+	}
+
+	expression, err := compileSearchPattern(patternType, test.PatternText)
+	if err != nil {
+		return nil, err
+	}
+
+	// Empty matches detect nothing, so they are skipped; offsets are counted in characters
+	result := &models.SearchPatternTestResult{Matches: []models.SearchPatternMatch{}}
+	offset, characters := 0, 0
+	for _, match := range expression.FindAllStringIndex(test.SampleText, -1) {
+		if match[0] == match[1] {
+			continue
+		}
+		if len(result.Matches) == constants.SearchPatternTestMaxMatches {
+			result.Truncated = true
+			break
+		}
+
+		characters += utf8.RuneCountInString(test.SampleText[offset:match[0]])
+		start := characters
+		characters += utf8.RuneCountInString(test.SampleText[match[0]:match[1]])
+		offset = match[1]
+
+		result.Matches = append(result.Matches, models.SearchPatternMatch{
+			Text:  test.SampleText[match[0]:match[1]],
+			Start: start,
+			End:   characters,
+		})
+	}
+
+	return result, nil
+}
@@ -0,0 +1,140 @@
+package service
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/yasinhessnawi1/Hideme_Backend/internal/constants"
+	"github.com/yasinhessnawi1/Hideme_Backend/internal/models"
+	"github.com/yasinhessnawi1/Hideme_Backend/internal/utils"
+)
+
+func TestCompileSearchPattern(t *testing.T) {
+	tests := []struct {
+		name        string
+		patternType models.PatternType
+		text        string
+		wantErr     string
+	}{
+		{"Plain text", models.Normal, "nordmann", ""},
+		{"National identity number", models.CaseSensitive, `\b\d{6}\s?\d{5}\b`, ""},
+		{"Optional part in repetition", models.Normal, `(\w-?)+`, ""},
+		{"Fixed count in repetition", models.Normal, `(\d{3}\s)+`, ""},
+		{"AI search is not a regular expression", models.AISearch, "names of (people", ""},
+		{"Unbalanced parenthesis", models.Normal, "names of (people", constants.MsgSearchPatternInvalid + ": missing closing )"},
+		{"Nested plus", models.Normal, "(a+)+b", constants.MsgSearchPatternUnsafe},
+		{"Repetition with optional separator", models.Normal, `(\w+-?)+`, constants.MsgSearchPatternUnsafe},
+		{"Nested star in unbounded repeat", models.CaseSensitive, `(x\w*){2,}`, constants.MsgSearchPatternUnsafe},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := compileSearchPattern(tt.patternType, tt.text)
+			if tt.wantErr == "" {
+				assert.NoError(t, err)
+				return
+			}
+			var validationErr *utils.AppError
+			require.ErrorAs(t, err, &validationErr)
+			assert.Equal(t, "pattern_text", validationErr.Field)
+			assert.Equal(t, tt.wantErr, validationErr.Message)
+		})
+	}
+}
+
+func TestSettingsService_TestSearchPattern(t *testing.T) {
+	svc := &SettingsService{}
+
+	t.Run("Normal patterns ignore case", func(t *testing.T) {
+		result, err := svc.TestSearchPattern(&models.SearchPatternTest{
+			PatternType: string(models.Normal),
+			PatternText: "ström",
+			SampleText:  "Åse Ström og STRÖM",
+		})
+
+		require.NoError(t, err)
+		assert.Equal(t, &models.SearchPatternTestResult{Matches: []models.SearchPatternMatch{
+			{Text: "Ström", Start: 4, End: 9},
+			{Text: "STRÖM", Start: 13, End: 18},
+		}}, result)
+	})
+
+	t.Run("Case-sensitive patterns skip empty matches", func(t *testing.T) {
+		result, err := svc.TestSearchPattern(&models.SearchPatternTest{
+			PatternType: string(models.CaseSensitive),
+			PatternText: "O*",
+			SampleText:  "oslo OO",
+		})
+
+		require.NoError(t, err)
+		assert.Equal(t, []models.SearchPatternMatch{{Text: "OO", Start: 5, End: 7}}, result.Matches)
+	})
+
+	t.Run("Matches are capped", func(t *testing.T) {
+		sample := make([]byte, constants.SearchPatternTestMaxMatches+1)
+		for i := range sample {
+			sample[i] = 'a'
+		}
+
+		result, err := svc.TestSearchPattern(&models.SearchPatternTest{
+			PatternType: string(models.Normal),
+			PatternText: "a",
+			SampleText:  string(sample),
+		})
+
+		require.NoError(t, err)
+		assert.Len(t, result.Matches, constants.SearchPatternTestMaxMatches)
+		assert.True(t, result.Truncated)
+	})
+
+	t.Run("AI search cannot be tested", func(t *testing.T) {
+		_, err := svc.TestSearchPattern(&models.SearchPatternTest{
+			PatternType: string(models.AISearch),
+			PatternText: "names",
+			SampleText:  "Ola Nordmann",
+		})
+
+		var validationErr *utils.AppError
+		require.ErrorAs(t, err, &validationErr)
+		assert.Equal(t, constants.MsgSearchPatternNotTestable, validationErr.Message)
+	})
+
+	t.Run("Unsafe patterns are refused", func(t *testing.T) {
+		_, err := svc.TestSearchPattern(&models.SearchPatternTest{
+			PatternType: string(models.Normal),
+			PatternText: "(a*)*",
+			SampleText:  "aaaa",
+		})
+
+		var validationErr *utils.AppError
+		require.ErrorAs(t, err, &validationErr)
+		assert.Equal(t, constants.MsgSearchPatternUnsafe, validationErr.Message)
+	})
+}
+
+func TestSearchPatternCatalog(t *testing.T) {
+	examples := map[string]string{
+		"no_fodselsnummer":           "Fødselsnummer: 010190 12345",
+		"no_organisasjonsnummer":     "Org.nr. 923 609 016",
+		"no_bank_account":            "Konto 1234.56.78903",
+		"no_phone_number":            "Ring +47 412 34 567",
+		"us_ssn":                     "SSN 123-45-6789",
+		"iban":                       "IBAN NO93 8601 1117 947",
+		"international_phone_number": "Call +44 20 7946 0958",
+		"email_address":              "Mail Ola.Nordmann@Example.no",
+		"payment_card":               "Card 4111 1111 1111 1111",
+	}
+
+	catalog := models.SearchPatternCatalog()
+	require.Len(t, catalog, len(examples))
+	for _, entry := range catalog {
+		t.Run(entry.Key, func(t *testing.T) {
+			// Every catalog pattern must be accepted as a search pattern
+			expression, err := compileSearchPattern(entry.PatternType, entry.PatternText)
+			require.NoError(t, err)
+			assert.True(t, expression.MatchString(examples[entry.Key]), "should match %q", examples[entry.Key])
+		})
+	}
+}
@@ -4,13 +4,20 @@ package service
 import (
 	"context"
 	"net"
+	"sort"
+	"strconv"
+	"strings"
 	"sync"
 	"time"
 
 	"github.com/rs/zerolog/log"
 
+	"github.com/yasinhessnawi1/Hideme_Backend/internal/config"
+	"github.com/yasinhessnawi1/Hideme_Backend/internal/constants"
 	"github.com/yasinhessnawi1/Hideme_Backend/internal/models"
 	"github.com/yasinhessnawi1/Hideme_Backend/internal/repository"
+	"github.com/yasinhessnawi1/Hideme_Backend/internal/utils"
+	"github.com/yasinhessnawi1/Hideme_Backend/internal/utils/clock"
 	"github.com/yasinhessnawi1/Hideme_Backend/internal/utils/ratelimit"
 )
 
@@ -22,6 +29,12 @@ type SecurityService struct {
 	cidrs            []*net.IPNet
 	banMutex         sync.RWMutex
 	refreshInterval  time.Duration
+
+	// grants holds the burst grants by rate limit key; guarded by grantMutex
+	grants     map[string]*models.RateLimitGrant
+	grantMutex sync.Mutex
+
+	clock clock.Clock
 }
 
 // NewSecurityService creates a new SecurityService.
@@ -58,6 +71,7 @@ func NewSecurityService(ipBanRepo repository.IPBanRepository, refreshInterval ti
 		banCache:         make(map[string]bool),
 		cidrs:            make([]*net.IPNet, 0),
 		refreshInterval:  refreshInterval,
+		grants:           make(map[string]*models.RateLimitGrant),
 	}
 
 	// Initialize ban cache
@@ -67,7 +81,48 @@ func NewSecurityService(ipBanRepo repository.IPBanRepository, refreshInterval ti
 	return service
 }
 
+// SetClock sets the clock bans and rate limit grants expire by.
+func (s *SecurityService) SetClock(c clock.Clock) {
+	s.clock = c
+}
+
+// now returns the current time of the service's clock.
+func (s *SecurityService) now() time.Time {
+	return clock.Or(s.clock).Now()
+}
+
+// SetRateLimits replaces the built-in rate limits with configured ones. Each route group
+// gets a bucket per IP address, and a bucket per user named with RateLimitUserGroupSuffix.
+//
+// Parameters:
+//   - settings: The rate limiting configuration
+func (s *SecurityService) SetRateLimits(settings *config.RateLimitSettings) {
+	groups := []string{
+		constants.RateLimitGroupDefault,
+		constants.RateLimitGroupAuth,
+		constants.RateLimitGroupAPI,
+		constants.RateLimitGroupDocuments,
+	}
+	for name := range settings.Groups {
+		groups = append(groups, name)
+	}
+
+	for _, name := range groups {
+		group := settings.Group(name)
+		s.rateLimiterStore.SetRate(name, ratelimit.Rate{
+			RequestsPerSecond: group.Rate,
+			Burst:             group.Burst,
+		})
+		s.rateLimiterStore.SetRate(name+constants.RateLimitUserGroupSuffix, ratelimit.Rate{
+			RequestsPerSecond: group.UserRate,
+			Burst:             group.UserBurst,
+		})
+	}
+}
+
 // IsRateLimited checks if a client has exceeded their rate limit.
+// Every category has its own bucket per client, so a client limited in one
+// route group can still use the others.
 //
 // Parameters:
 //   - clientID: Identifier for the client (typically IP address)
@@ -76,10 +131,166 @@ func NewSecurityService(ipBanRepo repository.IPBanRepository, refreshInterval ti
 // Returns:
 //   - true if the client is rate limited, false otherwise
 func (s *SecurityService) IsRateLimited(clientID, category string) bool {
-	limiter := s.rateLimiterStore.GetLimiter(clientID, category)
+	limiter := s.rateLimiterStore.GetLimiter(rateLimitKey(clientID, category), category)
 	return !limiter.Allow()
 }
 
+// RetryAfter returns how long a rate limited client must wait before its next request
+// in a category is allowed; zero if it is not limited.
+func (s *SecurityService) RetryAfter(clientID, category string) time.Duration {
+	limiter := s.rateLimiterStore.GetLimiter(rateLimitKey(clientID, category), category)
+	return limiter.RetryAfter()
+}
+
+// rateLimitKey identifies the bucket of a client in a category.
+func rateLimitKey(clientID, category string) string {
+	return category + "|" + clientID
+}
+
+// RateLimitOverview reports the configured limits of the route groups, the consumption of
+// the buckets of the clients seen recently and the unexpired burst grants. Buckets and
+// grants are kept in memory, so each server instance reports its own.
+//
+// Parameters:
+//   - filter: The group, user or IP address to report the buckets of; the zero filter reports all
+//
+// Returns:
+//   - The rate limit overview
+func (s *SecurityService) RateLimitOverview(filter models.RateLimitFilter) *models.RateLimitOverview {
+	overview := &models.RateLimitOverview{
+		Groups:  []models.RateLimitGroup{},
+		Buckets: []models.RateLimitBucket{},
+		Grants:  []models.RateLimitGrant{},
+	}
+
+	rates := s.rateLimiterStore.Rates()
+	for category, rate := range rates {
+		if strings.HasSuffix(category, constants.RateLimitUserGroupSuffix) {
+			continue
+		}
+		userRate := rates[category+constants.RateLimitUserGroupSuffix]
+		overview.Groups = append(overview.Groups, models.RateLimitGroup{
+			Group:     category,
+			Rate:      rate.RequestsPerSecond,
+			Burst:     rate.Burst,
+			UserRate:  userRate.RequestsPerSecond,
+			UserBurst: userRate.Burst,
+		})
+	}
+	sort.Slice(overview.Groups, func(i, j int) bool { return overview.Groups[i].Group < overview.Groups[j].Group })
+
+	for key, status := range s.rateLimiterStore.Statuses() {
+		group, clientType, clientID := parseRateLimitKey(key)
+		if !filter.Matches(group, clientType, clientID) {
+			continue
+		}
+		bucket := models.RateLimitBucket{
+			Group:      group,
+			ClientType: clientType,
+			ClientID:   clientID,
+			Tokens:     status.Tokens,
+			Burst:      status.Capacity,
+			Rate:       status.Rate,
+		}
+		if status.Capacity > 0 {
+			bucket.UsedPercent = int((status.Capacity - status.Tokens) * 100 / status.Capacity)
+		}
+		if status.Grant > 0 {
+			bucket.GrantBurst = status.Grant
+			bucket.GrantExpiresAt = &status.GrantUntil
+		}
+		overview.Buckets = append(overview.Buckets, bucket)
+	}
+	sort.Slice(overview.Buckets, func(i, j int) bool {
+		a, b := overview.Buckets[i], overview.Buckets[j]
+		if a.UsedPercent != b.UsedPercent {
+			return a.UsedPercent > b.UsedPercent
+		}
+		return a.Group+a.ClientID < b.Group+b.ClientID
+	})
+
+	now := s.now()
+	s.grantMutex.Lock()
+	for key, grant := range s.grants {
+		if !now.Before(grant.ExpiresAt) {
+			delete(s.grants, key)
+			continue
+		}
+		if filter.Matches(grant.Group, grant.ClientType, grant.ClientID) {
+			overview.Grants = append(overview.Grants, *grant)
+		}
+	}
+	s.grantMutex.Unlock()
+	sort.Slice(overview.Grants, func(i, j int) bool { return overview.Grants[i].ExpiresAt.Before(overview.Grants[j].ExpiresAt) })
+
+	return overview
+}
+
+// GrantRateLimitBurst temporarily raises the burst of a user or IP address in a route group,
+// for example for a customer's month-end processing. A new grant to the same client in the
+// same group replaces the previous one. Every grant is logged for auditing.
+//
+// Parameters:
+//   - ctx: Context for the operation
+//   - adminID: The administrator granting the burst
+//   - req: The client, group, burst and duration of the grant
+//
+// Returns:
+//   - The grant
+//   - A validation error if the group is not rate limited
+func (s *SecurityService) GrantRateLimitBurst(ctx context.Context, adminID int64, req *models.RateLimitGrantRequest) (*models.RateLimitGrant, error) {
+	if _, ok := s.rateLimiterStore.Rates()[req.Group]; !ok || strings.HasSuffix(req.Group, constants.RateLimitUserGroupSuffix) {
+		return nil, utils.NewValidationError("group", constants.MsgRateLimitGroupUnknown)
+	}
+
+	now := s.now()
+	grant := &models.RateLimitGrant{
+		Group:      req.Group,
+		ClientType: models.RateLimitClientIP,
+		ClientID:   req.IPAddress,
+		Burst:      req.Burst,
+		Reason:     req.Reason,
+		GrantedBy:  adminID,
+		GrantedAt:  now,
+		ExpiresAt:  now.Add(time.Duration(req.DurationSeconds) * time.Second),
+	}
+	category, client := req.Group, req.IPAddress
+	if req.UserID != 0 {
+		grant.ClientType = models.RateLimitClientUser
+		grant.ClientID = strconv.FormatInt(req.UserID, 10)
+		category = req.Group + constants.RateLimitUserGroupSuffix
+		client = constants.RateLimitUserClientPrefix + grant.ClientID
+	}
+
+	key := rateLimitKey(client, category)
+	s.rateLimiterStore.Grant(key, category, grant.Burst, grant.ExpiresAt)
+	s.grantMutex.Lock()
+	s.grants[key] = grant
+	s.grantMutex.Unlock()
+
+	log.Info().
+		Int64("admin_id", adminID).
+		Str("group", grant.Group).
+		Str("client_type", grant.ClientType).
+		Str("client_id", grant.ClientID).
+		Int("burst", grant.Burst).
+		Time("expires_at", grant.ExpiresAt).
+		Str("reason", grant.Reason).
+		Str("category", constants.LogCategoryAdmin).
+		Msg("Rate limit burst granted")
+
+	return grant, nil
+}
+
+// parseRateLimitKey splits the key of a bucket into its route group, client type and client.
+func parseRateLimitKey(key string) (group, clientType, clientID string) {
+	category, client, _ := strings.Cut(key, "|")
+	if group, ok := strings.CutSuffix(category, constants.RateLimitUserGroupSuffix); ok {
+		return group, models.RateLimitClientUser, strings.TrimPrefix(client, constants.RateLimitUserClientPrefix)
+	}
+	return category, models.RateLimitClientIP, client
+}
+
 // IsBanned checks if an IP address is banned.
 //
 // Parameters:
@@ -129,7 +340,7 @@ func (s *SecurityService) BanIP(ctx context.Context, ip, reason string, duration
 	var expiresAt *time.Time
 
 	if duration > 0 {
-		expiry := time.Now().Add(duration)
+		expiry := s.now().Add(duration)
 		expiresAt = &expiry
 	}
 
@@ -0,0 +1,179 @@
+package service
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/yasinhessnawi1/Hideme_Backend/internal/config"
+	"github.com/yasinhessnawi1/Hideme_Backend/internal/constants"
+	"github.com/yasinhessnawi1/Hideme_Backend/internal/models"
+	"github.com/yasinhessnawi1/Hideme_Backend/internal/testsupport/fakeclock"
+	"github.com/yasinhessnawi1/Hideme_Backend/internal/testsupport/fixtures"
+	"github.com/yasinhessnawi1/Hideme_Backend/internal/utils"
+	"github.com/yasinhessnawi1/Hideme_Backend/internal/utils/ratelimit"
+)
+
+// newRateLimitedSecurityService returns a SecurityService without an IP ban repository
+// and with the given rate limits.
+func newRateLimitedSecurityService(settings *config.RateLimitSettings) *SecurityService {
+	s := &SecurityService{
+		rateLimiterStore: ratelimit.NewStore(ratelimit.Rate{RequestsPerSecond: 100, Burst: 50}, time.Hour),
+		banCache:         make(map[string]bool),
+		grants:           make(map[string]*models.RateLimitGrant),
+	}
+	s.SetRateLimits(settings)
+	return s
+}
+
+func TestSecurityService_RateLimitOverview(t *testing.T) {
+	s := newRateLimitedSecurityService(&config.RateLimitSettings{
+		Groups: map[string]config.RateLimitGroupSettings{
+			constants.RateLimitGroupDocuments: {Rate: 1, Burst: 4, UserRate: 1, UserBurst: 10},
+		},
+	})
+	for i := 0; i < 3; i++ {
+		s.IsRateLimited("10.0.0.1", constants.RateLimitGroupDocuments)
+	}
+	s.IsRateLimited(constants.RateLimitUserClientPrefix+"42", constants.RateLimitGroupDocuments+constants.RateLimitUserGroupSuffix)
+
+	t.Run("Groups", func(t *testing.T) {
+		overview := s.RateLimitOverview(models.RateLimitFilter{})
+		var documents *models.RateLimitGroup
+		for i := range overview.Groups {
+			assert.NotContains(t, overview.Groups[i].Group, constants.RateLimitUserGroupSuffix)
+			if overview.Groups[i].Group == constants.RateLimitGroupDocuments {
+				documents = &overview.Groups[i]
+			}
+		}
+		require.NotNil(t, documents)
+		assert.Equal(t, models.RateLimitGroup{Group: "documents", Rate: 1, Burst: 4, UserRate: 1, UserBurst: 10}, *documents)
+	})
+
+	t.Run("Buckets most used first", func(t *testing.T) {
+		overview := s.RateLimitOverview(models.RateLimitFilter{})
+		require.Len(t, overview.Buckets, 2)
+
+		ip := overview.Buckets[0]
+		assert.Equal(t, models.RateLimitClientIP, ip.ClientType)
+		assert.Equal(t, "10.0.0.1", ip.ClientID)
+		assert.Equal(t, "documents", ip.Group)
+		assert.InDelta(t, 75, ip.UsedPercent, 1)
+
+		user := overview.Buckets[1]
+		assert.Equal(t, models.RateLimitClientUser, user.ClientType)
+		assert.Equal(t, "42", user.ClientID)
+		assert.Equal(t, "documents", user.Group)
+		assert.InDelta(t, 10, user.UsedPercent, 1)
+	})
+
+	t.Run("Filter", func(t *testing.T) {
+		overview := s.RateLimitOverview(models.RateLimitFilter{UserID: 42})
+		require.Len(t, overview.Buckets, 1)
+		assert.Equal(t, "42", overview.Buckets[0].ClientID)
+
+		overview = s.RateLimitOverview(models.RateLimitFilter{IPAddress: "10.0.0.1"})
+		require.Len(t, overview.Buckets, 1)
+		assert.Equal(t, models.RateLimitClientIP, overview.Buckets[0].ClientType)
+
+		overview = s.RateLimitOverview(models.RateLimitFilter{Group: constants.RateLimitGroupAuth})
+		assert.Empty(t, overview.Buckets)
+	})
+}
+
+func TestSecurityService_GrantRateLimitBurst(t *testing.T) {
+	s := newRateLimitedSecurityService(&config.RateLimitSettings{
+		Groups: map[string]config.RateLimitGroupSettings{
+			constants.RateLimitGroupDocuments: {Rate: 0.001, Burst: 1, UserRate: 0.001, UserBurst: 1},
+		},
+	})
+	userClient := constants.RateLimitUserClientPrefix + "42"
+	userGroup := constants.RateLimitGroupDocuments + constants.RateLimitUserGroupSuffix
+	require.False(t, s.IsRateLimited(userClient, userGroup))
+	require.True(t, s.IsRateLimited(userClient, userGroup))
+
+	t.Run("User", func(t *testing.T) {
+		grant, err := s.GrantRateLimitBurst(context.Background(), 1, &models.RateLimitGrantRequest{
+			Group:           constants.RateLimitGroupDocuments,
+			UserID:          42,
+			Burst:           5,
+			DurationSeconds: 3600,
+			Reason:          "Month-end processing",
+		})
+		require.NoError(t, err)
+		assert.Equal(t, models.RateLimitClientUser, grant.ClientType)
+		assert.Equal(t, "42", grant.ClientID)
+		assert.Equal(t, int64(1), grant.GrantedBy)
+		assert.WithinDuration(t, time.Now().Add(time.Hour), grant.ExpiresAt, time.Minute)
+
+		// The user may send the granted burst at once, but other clients keep their limits
+		for i := 0; i < 5; i++ {
+			assert.False(t, s.IsRateLimited(userClient, userGroup), "request %d", i)
+		}
+		assert.True(t, s.IsRateLimited(userClient, userGroup))
+		assert.False(t, s.IsRateLimited(constants.RateLimitUserClientPrefix+"7", userGroup))
+		assert.True(t, s.IsRateLimited(constants.RateLimitUserClientPrefix+"7", userGroup))
+
+		overview := s.RateLimitOverview(models.RateLimitFilter{UserID: 42})
+		require.Len(t, overview.Grants, 1)
+		assert.Equal(t, "Month-end processing", overview.Grants[0].Reason)
+		require.Len(t, overview.Buckets, 1)
+		assert.Equal(t, 5, overview.Buckets[0].GrantBurst)
+		assert.NotNil(t, overview.Buckets[0].GrantExpiresAt)
+	})
+
+	t.Run("IP address", func(t *testing.T) {
+		grant, err := s.GrantRateLimitBurst(context.Background(), 1, &models.RateLimitGrantRequest{
+			Group:           constants.RateLimitGroupDocuments,
+			IPAddress:       "10.0.0.2",
+			Burst:           2,
+			DurationSeconds: 60,
+			Reason:          "Import",
+		})
+		require.NoError(t, err)
+		assert.Equal(t, models.RateLimitClientIP, grant.ClientType)
+		assert.Equal(t, "10.0.0.2", grant.ClientID)
+		for i := 0; i < 3; i++ {
+			assert.False(t, s.IsRateLimited("10.0.0.2", constants.RateLimitGroupDocuments), "request %d", i)
+		}
+	})
+
+	t.Run("Unknown group", func(t *testing.T) {
+		for _, group := range []string{"uploads", userGroup} {
+			_, err := s.GrantRateLimitBurst(context.Background(), 1, &models.RateLimitGrantRequest{
+				Group:           group,
+				UserID:          42,
+				Burst:           5,
+				DurationSeconds: 60,
+				Reason:          "Test",
+			})
+			assert.ErrorIs(t, err, utils.ErrValidation, group)
+		}
+	})
+}
+
+func TestSecurityService_GrantExpiry(t *testing.T) {
+	s := newRateLimitedSecurityService(&config.RateLimitSettings{})
+	clk := fakeclock.New(fixtures.Time)
+	s.SetClock(clk)
+
+	grant, err := s.GrantRateLimitBurst(context.Background(), 1, &models.RateLimitGrantRequest{
+		Group:           constants.RateLimitGroupDocuments,
+		UserID:          42,
+		Burst:           5,
+		DurationSeconds: 60,
+		Reason:          "Import",
+	})
+	require.NoError(t, err)
+	assert.Equal(t, fixtures.Time.Add(time.Minute), grant.ExpiresAt)
+
+	clk.Advance(time.Minute - time.Second)
+	assert.Len(t, s.RateLimitOverview(models.RateLimitFilter{}).Grants, 1)
+
+	// The grant is gone at the instant it expires
+	clk.Advance(time.Second)
+	assert.Empty(t, s.RateLimitOverview(models.RateLimitFilter{}).Grants)
+}
@@ -0,0 +1,72 @@
+// Package service provides business logic implementations.
+package service
+
+import (
+	"context"
+
+	"github.com/rs/zerolog/log"
+
+	"github.com/yasinhessnawi1/Hideme_Backend/internal/constants"
+	"github.com/yasinhessnawi1/Hideme_Backend/internal/models"
+	"github.com/yasinhessnawi1/Hideme_Backend/internal/repository"
+)
+
+// SettingsConsistencyService verifies the settings object graph: every user has exactly one
+// settings row with exactly one ban list, and no patterns, model entities or ban list words
+// are left without the rows they belong to. Missing rows are created and orphaned rows
+// deleted; duplicates are reported for an administrator to resolve.
+type SettingsConsistencyService struct {
+	consistencyRepo repository.SettingsConsistencyRepository
+}
+
+// NewSettingsConsistencyService creates a new SettingsConsistencyService.
+//
+// Parameters:
+//   - consistencyRepo: Repository checking and repairing the settings object graph
+//
+// Returns:
+//   - A configured SettingsConsistencyService
+func NewSettingsConsistencyService(consistencyRepo repository.SettingsConsistencyRepository) *SettingsConsistencyService {
+	return &SettingsConsistencyService{
+		consistencyRepo: consistencyRepo,
+	}
+}
+
+// Check reports the inconsistencies in the settings object graph without repairing them.
+//
+// Parameters:
+//   - ctx: Context for the operation
+//
+// Returns:
+//   - The inconsistencies found
+//   - An error if the check fails
+func (s *SettingsConsistencyService) Check(ctx context.Context) (*models.SettingsConsistencyReport, error) {
+	return s.consistencyRepo.Check(ctx)
+}
+
+// Repair repairs the repairable inconsistencies and logs the ones that need an administrator.
+// It runs as a maintenance task.
+//
+// Parameters:
+//   - ctx: Context for the operation
+//
+// Returns:
+//   - The number of inconsistencies repaired
+//   - An error if the repair fails; nothing is repaired then
+func (s *SettingsConsistencyService) Repair(ctx context.Context) (int64, error) {
+	report, err := s.consistencyRepo.Repair(ctx, models.NewUserSetting(0))
+	if err != nil {
+		return 0, err
+	}
+
+	if report.Unrepairable() > 0 {
+		log.Warn().
+			Int64("duplicate_settings", report.DuplicateSettings).
+			Int64("duplicate_ban_lists", report.DuplicateBanLists).
+			Int64("detected_entities_without_method", report.DetectedEntitiesWithoutMethod).
+			Str("category", constants.LogCategoryAdmin).
+			Msg("Settings object graph has inconsistencies that need manual repair")
+	}
+
+	return report.Repairable(), nil
+}
@@ -0,0 +1,84 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/yasinhessnawi1/Hideme_Backend/internal/models"
+)
+
+// MockSettingsConsistencyRepository is an in-memory implementation of repository.SettingsConsistencyRepository
+type MockSettingsConsistencyRepository struct {
+	report   models.SettingsConsistencyReport
+	err      error
+	defaults *models.UserSetting
+}
+
+func (m *MockSettingsConsistencyRepository) Check(ctx context.Context) (*models.SettingsConsistencyReport, error) {
+	if m.err != nil {
+		return nil, m.err
+	}
+	report := m.report
+	return &report, nil
+}
+
+func (m *MockSettingsConsistencyRepository) Repair(ctx context.Context, defaults *models.UserSetting) (*models.SettingsConsistencyReport, error) {
+	if m.err != nil {
+		return nil, m.err
+	}
+	m.defaults = defaults
+	report := m.report
+	report.Repaired = true
+	return &report, nil
+}
+
+func TestSettingsConsistencyService_Check(t *testing.T) {
+	repo := &MockSettingsConsistencyRepository{
+		report: models.SettingsConsistencyReport{SettingsWithoutBanList: 2},
+	}
+	svc := NewSettingsConsistencyService(repo)
+
+	report, err := svc.Check(context.Background())
+	if err != nil {
+		t.Fatalf("Check returned error: %v", err)
+	}
+	if report.SettingsWithoutBanList != 2 || report.Repaired {
+		t.Errorf("Expected 2 unrepaired settings without ban list, got %+v", report)
+	}
+}
+
+func TestSettingsConsistencyService_Repair(t *testing.T) {
+	repo := &MockSettingsConsistencyRepository{
+		report: models.SettingsConsistencyReport{
+			UsersWithoutSettings:   1,
+			SettingsWithoutBanList: 2,
+			OrphanedPatterns:       3,
+			DuplicateSettings:      1,
+		},
+	}
+	svc := NewSettingsConsistencyService(repo)
+
+	count, err := svc.Repair(context.Background())
+	if err != nil {
+		t.Fatalf("Repair returned error: %v", err)
+	}
+	if count != 6 {
+		t.Errorf("Expected 6 repaired inconsistencies, got %d", count)
+	}
+	if repo.defaults == nil || !repo.defaults.UseBanlistForDetection {
+		t.Errorf("Expected missing settings to be created with the default settings, got %+v", repo.defaults)
+	}
+}
+
+func TestSettingsConsistencyService_Repair_Error(t *testing.T) {
+	svc := NewSettingsConsistencyService(&MockSettingsConsistencyRepository{err: errors.New("connection lost")})
+
+	count, err := svc.Repair(context.Background())
+	if err == nil {
+		t.Error("Expected repository error to be returned")
+	}
+	if count != 0 {
+		t.Errorf("Expected no repaired inconsistencies, got %d", count)
+	}
+}
@@ -0,0 +1,249 @@
+// Package service provides business logic implementations for the HideMe application.
+//
+// This file implements the resolution of conflicting settings changes: an update based on
+// an older version of the settings is refused with the current settings, and changes made
+// on one device while another device changed the settings can be merged field by field.
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"reflect"
+
+	"github.com/rs/zerolog/log"
+
+	"github.com/yasinhessnawi1/Hideme_Backend/internal/constants"
+	"github.com/yasinhessnawi1/Hideme_Backend/internal/models"
+	"github.com/yasinhessnawi1/Hideme_Backend/internal/repository"
+	"github.com/yasinhessnawi1/Hideme_Backend/internal/utils"
+)
+
+// SettingsConflictError is returned when settings changes are based on an older version of
+// the settings than the current one. It carries the current settings, so the client can
+// resolve the conflict.
+type SettingsConflictError struct {
+	Conflict *models.SettingsConflict
+}
+
+// Error returns the message sent to the client with the conflict.
+func (e *SettingsConflictError) Error() string {
+	if len(e.Conflict.ConflictingFields) > 0 {
+		return constants.MsgSettingsMergeConflict
+	}
+	return constants.MsgSettingsVersionConflict
+}
+
+// newSettingsConflictError creates the error refusing settings changes.
+//
+// Parameters:
+//   - current: The current settings
+//   - submitted: The refused changes
+//   - fields: The fields changed to other values since, if known
+//
+// Returns:
+//   - The conflict error
+func newSettingsConflictError(current *models.UserSetting, submitted *models.UserSettingsUpdate, fields []string) *SettingsConflictError {
+	return &SettingsConflictError{Conflict: &models.SettingsConflict{
+		Current:           current,
+		Submitted:         submitted,
+		ConflictingFields: fields,
+	}}
+}
+
+// settingsConflict reads the current settings after they changed while changes were
+// applied to them, and creates the error refusing the changes.
+//
+// Parameters:
+//   - ctx: Context for the operation
+//   - userID: The ID of the user whose settings changed
+//   - submitted: The refused changes
+//   - fields: The fields changed to other values since, if known
+//
+// Returns:
+//   - The conflict error, or the error reading the settings
+func (s *SettingsService) settingsConflict(ctx context.Context, userID int64, submitted *models.UserSettingsUpdate, fields []string) error {
+	current, err := s.GetUserSettings(ctx, userID)
+	if err != nil {
+		return err
+	}
+	return newSettingsConflictError(current, submitted, fields)
+}
+
+// MergeUserSettings applies changes made to an older version of a user's settings to the
+// current settings. The changes are merged field by field: a field changed since the base
+// version may only be changed to the value it has now.
+//
+// Parameters:
+//   - ctx: Context for the operation
+//   - userID: The ID of the user whose settings to update
+//   - merge: The changes and the version they were made to
+//
+// Returns:
+//   - The merged user settings
+//   - A SettingsConflictError listing the fields changed to other values since the base version
+//   - ValidationError if the base version is newer than the current settings
+//   - An error if retrieval or update fails
+//
+// If the base version is too old for its snapshot to be known, every changed field that
+// differs from the current settings conflicts.
+func (s *SettingsService) MergeUserSettings(ctx context.Context, userID int64, merge *models.SettingsMerge) (*models.UserSetting, error) {
+	changes := merge.Changes
+	changes.Version = nil
+
+	for attempt := 1; ; attempt++ {
+		current, err := s.GetUserSettings(ctx, userID)
+		if err != nil {
+			return nil, err
+		}
+		if merge.BaseVersion > current.Version {
+			return nil, utils.NewValidationError("base_version", constants.MsgSettingsBaseVersionInvalid)
+		}
+
+		base := current
+		if merge.BaseVersion < current.Version {
+			base, err = s.revisionRepo.GetSettingsVersion(ctx, userID, merge.BaseVersion)
+			if err != nil && !utils.IsNotFoundError(err) {
+				return nil, fmt.Errorf("failed to get settings version: %w", err)
+			}
+		}
+
+		if fields := conflictingSettingsFields(base, current, &changes); len(fields) > 0 {
+			return nil, newSettingsConflictError(current, &merge.Changes, fields)
+		}
+
+		current.Apply(&changes)
+		err = s.changeSettings(ctx, func(ctx context.Context) ([]*models.SettingsRevision, error) {
+			if err := s.settingsRepo.Update(ctx, current); err != nil {
+				return nil, err
+			}
+			return []*models.SettingsRevision{models.NewSettingsRevision(userID, models.ResourceGeneralSettings, current.ID, models.RevisionUpdated, current)}, nil
+		})
+		if err == nil {
+			log.Info().
+				Int64("user_id", userID).
+				Int64("setting_id", current.ID).
+				Int64("base_version", merge.BaseVersion).
+				Int64("version", current.Version).
+				Str("category", constants.LogCategoryUser).
+				Str("event", constants.LogEventUserUpdate).
+				Msg("User settings merged")
+
+			return current, nil
+		}
+		if !errors.Is(err, repository.ErrSettingsVersionConflict) {
+			return nil, fmt.Errorf("failed to update user settings: %w", err)
+		}
+		if attempt == constants.MaxSettingsUpdateAttempts {
+			return nil, s.settingsConflict(ctx, userID, &merge.Changes, nil)
+		}
+	}
+}
+
+// settingsField reads a field of the settings that an update can change.
+type settingsField struct {
+	name    string
+	changed func(update *models.UserSettingsUpdate) (interface{}, bool)
+	value   func(settings *models.UserSetting) interface{}
+}
+
+// settingsFields are the fields of the settings that an update can change, by JSON name.
+var settingsFields = []settingsField{
+	{
+		name: "remove_images",
+		changed: func(u *models.UserSettingsUpdate) (interface{}, bool) {
+			return derefOrNil(u.RemoveImages), u.RemoveImages != nil
+		},
+		value: func(s *models.UserSetting) interface{} { return s.RemoveImages },
+	},
+	{
+		name: "theme",
+		changed: func(u *models.UserSettingsUpdate) (interface{}, bool) {
+			return derefOrNil(u.Theme), u.Theme != nil
+		},
+		value: func(s *models.UserSetting) interface{} { return s.Theme },
+	},
+	{
+		name: "auto_processing",
+		changed: func(u *models.UserSettingsUpdate) (interface{}, bool) {
+			return derefOrNil(u.AutoProcessing), u.AutoProcessing != nil
+		},
+		value: func(s *models.UserSetting) interface{} { return s.AutoProcessing },
+	},
+	{
+		name: "detection_threshold",
+		changed: func(u *models.UserSettingsUpdate) (interface{}, bool) {
+			return derefOrNil(u.DetectionThreshold), u.DetectionThreshold != nil
+		},
+		value: func(s *models.UserSetting) interface{} { return s.DetectionThreshold },
+	},
+	{
+		name: "use_banlist_for_detection",
+		changed: func(u *models.UserSettingsUpdate) (interface{}, bool) {
+			return derefOrNil(u.UseBanlistForDetection), u.UseBanlistForDetection != nil
+		},
+		value: func(s *models.UserSetting) interface{} { return s.UseBanlistForDetection },
+	},
+	{
+		name: "redaction_placeholders",
+		changed: func(u *models.UserSettingsUpdate) (interface{}, bool) {
+			return placeholdersOrEmpty(u.RedactionPlaceholders), u.RedactionPlaceholders != nil
+		},
+		value: func(s *models.UserSetting) interface{} { return placeholdersOrEmpty(s.RedactionPlaceholders) },
+	},
+	{
+		name: "scrub_filenames",
+		changed: func(u *models.UserSettingsUpdate) (interface{}, bool) {
+			return derefOrNil(u.ScrubFilenames), u.ScrubFilenames != nil
+		},
+		value: func(s *models.UserSetting) interface{} { return s.ScrubFilenames },
+	},
+	{
+		name: "document_retention_days",
+		changed: func(u *models.UserSettingsUpdate) (interface{}, bool) {
+			return derefOrNil(u.DocumentRetentionDays), u.DocumentRetentionDays != nil
+		},
+		value: func(s *models.UserSetting) interface{} { return s.DocumentRetentionDays },
+	},
+}
+
+// conflictingSettingsFields returns the fields that changes set to another value than the
+// current one, although they were changed since the base version.
+//
+// Parameters:
+//   - base: The settings the changes were made to, or nil if unknown
+//   - current: The current settings
+//   - changes: The changes to merge
+//
+// Returns:
+//   - The JSON names of the conflicting fields, or nil if the changes merge
+func conflictingSettingsFields(base, current *models.UserSetting, changes *models.UserSettingsUpdate) []string {
+	var fields []string
+	for _, field := range settingsFields {
+		value, ok := field.changed(changes)
+		if !ok || reflect.DeepEqual(value, field.value(current)) {
+			continue
+		}
+		if base == nil || !reflect.DeepEqual(field.value(base), field.value(current)) {
+			fields = append(fields, field.name)
+		}
+	}
+	return fields
+}
+
+// derefOrNil returns the value a pointer points to, or nil for a nil pointer.
+func derefOrNil[T any](p *T) interface{} {
+	if p == nil {
+		return nil
+	}
+	return *p
+}
+
+// placeholdersOrEmpty returns the placeholders, or an empty map for none, so that missing
+// and empty placeholders compare equal.
+func placeholdersOrEmpty(placeholders models.RedactionPlaceholders) models.RedactionPlaceholders {
+	if placeholders == nil {
+		return models.RedactionPlaceholders{}
+	}
+	return placeholders
+}
@@ -9,6 +9,7 @@ package service
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"time"
 
@@ -18,8 +19,23 @@ import (
 	"github.com/yasinhessnawi1/Hideme_Backend/internal/models"
 	"github.com/yasinhessnawi1/Hideme_Backend/internal/repository"
 	"github.com/yasinhessnawi1/Hideme_Backend/internal/utils"
+	"github.com/yasinhessnawi1/Hideme_Backend/internal/utils/clock"
 )
 
+// Transactor runs a function in a database transaction carried by the context passed to it.
+// Repository calls made with that context commit or roll back together.
+type Transactor interface {
+	WithTransaction(ctx context.Context, fn func(ctx context.Context) error) error
+}
+
+// inTransaction runs fn in a transaction of transactor, or directly if transactor is nil.
+func inTransaction(ctx context.Context, transactor Transactor, fn func(ctx context.Context) error) error {
+	if transactor == nil {
+		return fn(ctx)
+	}
+	return transactor.WithTransaction(ctx, fn)
+}
+
 // SettingsService handles user settings operations for the application.
 // It provides methods for managing preferences, ban lists, search patterns,
 // and model entities, with a focus on user-specific configurations.
@@ -28,6 +44,12 @@ type SettingsService struct {
 	banListRepo     repository.BanListRepository
 	patternRepo     repository.PatternRepository
 	modelEntityRepo repository.ModelEntityRepository
+	revisionRepo    repository.SettingsRevisionRepository
+
+	detectionConfigCache *DetectionConfigCache
+	notifier             UserNotifier
+	transactor           Transactor
+	clock                clock.Clock
 }
 
 // NewSettingsService creates a new SettingsService with the specified dependencies.
@@ -37,6 +59,7 @@ type SettingsService struct {
 //   - banListRepo: Repository for ban list operations
 //   - patternRepo: Repository for search pattern operations
 //   - modelEntityRepo: Repository for model entity operations
+//   - revisionRepo: Repository for the settings revision history
 //
 // Returns:
 //   - A new SettingsService instance with all dependencies initialized
@@ -48,15 +71,45 @@ func NewSettingsService(
 	banListRepo repository.BanListRepository,
 	patternRepo repository.PatternRepository,
 	modelEntityRepo repository.ModelEntityRepository,
+	revisionRepo repository.SettingsRevisionRepository,
 ) *SettingsService {
 	return &SettingsService{
 		settingsRepo:    settingsRepo,
 		banListRepo:     banListRepo,
 		patternRepo:     patternRepo,
 		modelEntityRepo: modelEntityRepo,
+		revisionRepo:    revisionRepo,
 	}
 }
 
+// SetClock sets the clock exports are dated and change feed waits end by.
+func (s *SettingsService) SetClock(c clock.Clock) {
+	s.clock = c
+}
+
+// now returns the current time of the service's clock.
+func (s *SettingsService) now() time.Time {
+	return clock.Or(s.clock).Now()
+}
+
+// SetDetectionConfigCache enables caching the composed detection configuration of each user.
+// Without a cache, every detection configuration request queries all settings tables.
+func (s *SettingsService) SetDetectionConfigCache(cache *DetectionConfigCache) {
+	s.detectionConfigCache = cache
+}
+
+// SetNotifier enables notifying the other clients of a user when their settings change.
+// Without a notifier, clients poll the settings changes.
+func (s *SettingsService) SetNotifier(notifier UserNotifier) {
+	s.notifier = notifier
+}
+
+// SetTransactor makes each settings change commit together with its revisions.
+// Without a transactor, a change and its revisions are stored one after the other.
+func (s *SettingsService) SetTransactor(transactor Transactor) {
+	s.transactor = transactor
+}
+
 // GetUserSettings retrieves settings for a user.
 // If settings don't exist for the user, default settings are created.
 //
@@ -89,23 +142,45 @@ func (s *SettingsService) GetUserSettings(ctx context.Context, userID int64) (*m
 //
 // Returns:
 //   - The updated user settings
+//   - A SettingsConflictError if the update is based on an older version of the settings
 //   - An error if retrieval or update fails
 //
 // This method gets the existing settings, applies the updates, and
-// saves the modified settings back to the database.
+// saves the modified settings back to the database. An update without a
+// version is applied to the current settings, retrying if they change meanwhile.
 func (s *SettingsService) UpdateUserSettings(ctx context.Context, userID int64, update *models.UserSettingsUpdate) (*models.UserSetting, error) {
-	// Get existing settings
-	settings, err := s.GetUserSettings(ctx, userID)
-	if err != nil {
-		return nil, err
-	}
+	var settings *models.UserSetting
+	for attempt := 1; ; attempt++ {
+		// Get existing settings
+		var err error
+		settings, err = s.GetUserSettings(ctx, userID)
+		if err != nil {
+			return nil, err
+		}
 
-	// Apply updates
-	settings.Apply(update)
+		if update.Version != nil && *update.Version != settings.Version {
+			return nil, newSettingsConflictError(settings, update, nil)
+		}
+
+		// Apply updates
+		settings.Apply(update)
 
-	// Save the updated settings
-	if err := s.settingsRepo.Update(ctx, settings); err != nil {
-		return nil, fmt.Errorf("failed to update user settings: %w", err)
+		// Save the updated settings
+		err = s.changeSettings(ctx, func(ctx context.Context) ([]*models.SettingsRevision, error) {
+			if err := s.settingsRepo.Update(ctx, settings); err != nil {
+				return nil, err
+			}
+			return []*models.SettingsRevision{models.NewSettingsRevision(userID, models.ResourceGeneralSettings, settings.ID, models.RevisionUpdated, settings)}, nil
+		})
+		if err == nil {
+			break
+		}
+		if !errors.Is(err, repository.ErrSettingsVersionConflict) {
+			return nil, fmt.Errorf("failed to update user settings: %w", err)
+		}
+		if update.Version != nil || attempt == constants.MaxSettingsUpdateAttempts {
+			return nil, s.settingsConflict(ctx, userID, update, nil)
+		}
 	}
 
 	log.Info().
@@ -158,10 +233,20 @@ func (s *SettingsService) GetBanList(ctx context.Context, userID int64) (*models
 		return nil, fmt.Errorf("failed to get ban list words: %w", err)
 	}
 
+	// Get how the words are matched
+	options, err := s.banListRepo.GetWordOptions(ctx, banList.ID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get ban list word options: %w", err)
+	}
+
 	// Convert to response format
 	result := &models.BanListWithWords{
-		ID:    banList.ID,
-		Words: words,
+		ID:             banList.ID,
+		BanListOptions: banList.BanListOptions,
+		Words:          words,
+	}
+	if len(options) > 0 {
+		result.Options = options
 	}
 
 	return result, nil
@@ -202,8 +287,14 @@ func (s *SettingsService) AddBanListWords(ctx context.Context, userID int64, wor
 	}
 
 	// Add words to the ban list
-	if err := s.banListRepo.AddWords(ctx, banList.ID, words); err != nil {
-		return fmt.Errorf("failed to add words to ban list: %w", err)
+	err = s.changeSettings(ctx, func(ctx context.Context) ([]*models.SettingsRevision, error) {
+		if err := s.banListRepo.AddWords(ctx, banList.ID, words); err != nil {
+			return nil, fmt.Errorf("failed to add words to ban list: %w", err)
+		}
+		return banListWordRevisions(userID, banList.ID, words, models.RevisionCreated), nil
+	})
+	if err != nil {
+		return err
 	}
 
 	log.Info().
@@ -223,15 +314,16 @@ func (s *SettingsService) AddBanListWords(ctx context.Context, userID int64, wor
 //   - words: The words to remove from the ban list
 //
 // Returns:
+//   - The words that were not on the ban list, in the order given
 //   - An error if retrieval or update fails
 //
 // This method is idempotent - if the ban list doesn't exist or
 // some words aren't in the list, no error is returned.
-func (s *SettingsService) RemoveBanListWords(ctx context.Context, userID int64, words []string) error {
+func (s *SettingsService) RemoveBanListWords(ctx context.Context, userID int64, words []string) ([]string, error) {
 	// Get user settings
 	settings, err := s.GetUserSettings(ctx, userID)
 	if err != nil {
-		return err
+		return nil, err
 	}
 
 	// Get ban list
@@ -239,21 +331,170 @@ func (s *SettingsService) RemoveBanListWords(ctx context.Context, userID int64,
 	if err != nil {
 		if utils.IsNotFoundError(err) {
 			// Nothing to remove if ban list doesn't exist
+			return words, nil
+		}
+		return nil, fmt.Errorf("failed to get ban list: %w", err)
+	}
+
+	// Tell the words on the ban list from those that are not
+	current, err := s.banListRepo.GetBanListWords(ctx, banList.ID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get ban list words: %w", err)
+	}
+	onList := make(map[string]bool, len(current))
+	for _, word := range current {
+		onList[word] = true
+	}
+	var removed, missing []string
+	for _, word := range words {
+		if onList[word] {
+			removed = append(removed, word)
+			onList[word] = false
+		} else {
+			missing = append(missing, word)
+		}
+	}
+	if len(removed) == 0 {
+		return missing, nil
+	}
+
+	// Remove words from the ban list
+	err = s.changeSettings(ctx, func(ctx context.Context) ([]*models.SettingsRevision, error) {
+		if err := s.banListRepo.RemoveWords(ctx, banList.ID, removed); err != nil {
+			return nil, fmt.Errorf("failed to remove words from ban list: %w", err)
+		}
+		return banListWordRevisions(userID, banList.ID, removed, models.RevisionDeleted), nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	log.Info().
+		Int64("user_id", userID).
+		Int64("ban_list_id", banList.ID).
+		Int("word_count", len(removed)).
+		Msg("Words removed from ban list")
+
+	return missing, nil
+}
+
+// SetBanListWordOptions sets how words on a user's ban list are matched, e.g. ignoring
+// case and diacritics so that "Strom" matches "Ström".
+//
+// Parameters:
+//   - ctx: Context for the operation
+//   - userID: The ID of the user whose ban list to modify
+//   - words: The words whose options are set; words not on the ban list are ignored
+//   - options: How the words are matched
+//
+// Returns:
+//   - ValidationError if the stemming language is not supported
+//   - An error if retrieval or update fails
+func (s *SettingsService) SetBanListWordOptions(ctx context.Context, userID int64, words []string, options models.BanListMatchOptions) error {
+	switch options.Stemming {
+	case models.StemmingNone, models.StemmingEnglish, models.StemmingNorwegian:
+	default:
+		return utils.NewValidationError("stemming", "Stemming must be en or no")
+	}
+
+	// Get user settings
+	settings, err := s.GetUserSettings(ctx, userID)
+	if err != nil {
+		return err
+	}
+
+	// Get ban list
+	banList, err := s.banListRepo.GetBySettingID(ctx, settings.ID)
+	if err != nil {
+		if utils.IsNotFoundError(err) {
+			// No words to set options for if ban list doesn't exist
 			return nil
 		}
 		return fmt.Errorf("failed to get ban list: %w", err)
 	}
 
-	// Remove words from the ban list
-	if err := s.banListRepo.RemoveWords(ctx, banList.ID, words); err != nil {
-		return fmt.Errorf("failed to remove words from ban list: %w", err)
+	err = s.changeSettings(ctx, func(ctx context.Context) ([]*models.SettingsRevision, error) {
+		if err := s.banListRepo.SetWordOptions(ctx, banList.ID, words, options); err != nil {
+			return nil, fmt.Errorf("failed to set ban list word options: %w", err)
+		}
+		return banListWordRevisions(userID, banList.ID, words, models.RevisionUpdated), nil
+	})
+	if err != nil {
+		return err
 	}
 
 	log.Info().
 		Int64("user_id", userID).
 		Int64("ban_list_id", banList.ID).
 		Int("word_count", len(words)).
-		Msg("Words removed from ban list")
+		Msg("Ban list word options set")
+
+	return nil
+}
+
+// SetBanListOptions sets how all words on a user's ban list are matched, e.g. ignoring
+// case for every word. Options omitted from the update are kept.
+//
+// Parameters:
+//   - ctx: Context for the operation
+//   - userID: The ID of the user whose ban list to modify
+//   - update: The options to set
+//
+// Returns:
+//   - An error if retrieval, creation, or update fails
+//
+// This method ensures that the user has a ban list, creating one if needed,
+// so that options can be chosen before any words are added.
+func (s *SettingsService) SetBanListOptions(ctx context.Context, userID int64, update *models.BanListOptionsUpdate) error {
+	// Get user settings
+	settings, err := s.GetUserSettings(ctx, userID)
+	if err != nil {
+		return err
+	}
+
+	// Get or create ban list
+	banList, err := s.banListRepo.GetBySettingID(ctx, settings.ID)
+	if err != nil {
+		if utils.IsNotFoundError(err) {
+			// Create a new ban list if one doesn't exist
+			banList, err = s.banListRepo.CreateBanList(ctx, settings.ID)
+			if err != nil {
+				return fmt.Errorf("failed to create ban list: %w", err)
+			}
+		} else {
+			return fmt.Errorf("failed to get ban list: %w", err)
+		}
+	}
+
+	// Apply the options present in the update
+	options := banList.BanListOptions
+	if update.CaseInsensitive != nil {
+		options.CaseInsensitive = *update.CaseInsensitive
+	}
+	if update.DiacriticInsensitive != nil {
+		options.DiacriticInsensitive = *update.DiacriticInsensitive
+	}
+	if update.WholeWord != nil {
+		options.WholeWord = *update.WholeWord
+	}
+
+	err = s.changeSettings(ctx, func(ctx context.Context) ([]*models.SettingsRevision, error) {
+		if err := s.banListRepo.SetOptions(ctx, banList.ID, options); err != nil {
+			return nil, fmt.Errorf("failed to set ban list options: %w", err)
+		}
+		return []*models.SettingsRevision{models.NewSettingsRevision(userID, models.ResourceBanList, banList.ID, models.RevisionUpdated, options)}, nil
+	})
+	if err != nil {
+		return err
+	}
+
+	log.Info().
+		Int64("user_id", userID).
+		Int64("ban_list_id", banList.ID).
+		Bool("case_insensitive", options.CaseInsensitive).
+		Bool("diacritic_insensitive", options.DiacriticInsensitive).
+		Bool("whole_word", options.WholeWord).
+		Msg("Ban list options set")
 
 	return nil
 }
@@ -296,10 +537,11 @@ func (s *SettingsService) GetSearchPatterns(ctx context.Context, userID int64) (
 //
 // Returns:
 //   - The newly created search pattern
-//   - ValidationError if the pattern type is invalid
+//   - ValidationError if the pattern type is invalid, or the pattern text is not a valid
+//     or safe regular expression
 //   - Other errors if retrieval or creation fails
 //
-// This method validates the pattern type before creating the pattern.
+// This method validates the pattern type and text before creating the pattern.
 func (s *SettingsService) CreateSearchPattern(ctx context.Context, userID int64, pattern *models.SearchPatternCreate) (*models.SearchPattern, error) {
 	// Get user settings
 	settings, err := s.GetUserSettings(ctx, userID)
@@ -313,10 +555,21 @@ func (s *SettingsService) CreateSearchPattern(ctx context.Context, userID int64,
 		return nil, utils.NewValidationError("pattern_type", "Invalid pattern type")
 	}
 
+	// Validate pattern text
+	if _, err := compileSearchPattern(patternType, pattern.PatternText); err != nil {
+		return nil, err
+	}
+
 	// Create the search pattern
 	newPattern := models.NewSearchPattern(settings.ID, patternType, pattern.PatternText)
-	if err := s.patternRepo.Create(ctx, newPattern); err != nil {
-		return nil, fmt.Errorf("failed to create search pattern: %w", err)
+	err = s.changeSettings(ctx, func(ctx context.Context) ([]*models.SettingsRevision, error) {
+		if err := s.patternRepo.Create(ctx, newPattern); err != nil {
+			return nil, fmt.Errorf("failed to create search pattern: %w", err)
+		}
+		return []*models.SettingsRevision{models.NewSettingsRevision(userID, models.ResourceSearchPattern, newPattern.ID, models.RevisionCreated, newPattern)}, nil
+	})
+	if err != nil {
+		return nil, err
 	}
 
 	log.Info().
@@ -339,7 +592,8 @@ func (s *SettingsService) CreateSearchPattern(ctx context.Context, userID int64,
 // Returns:
 //   - The updated search pattern
 //   - ForbiddenError if the pattern doesn't belong to the user
-//   - ValidationError if the pattern type is invalid
+//   - ValidationError if the pattern type is invalid, or the pattern text is not a valid
+//     or safe regular expression
 //   - NotFoundError if the pattern doesn't exist
 //   - Other errors if retrieval or update fails
 //
@@ -375,9 +629,20 @@ func (s *SettingsService) UpdateSearchPattern(ctx context.Context, userID int64,
 		pattern.PatternText = update.PatternText
 	}
 
+	// Validate the pattern text as it is matched after the update
+	if _, err := compileSearchPattern(pattern.PatternType, pattern.PatternText); err != nil {
+		return nil, err
+	}
+
 	// Save the updated pattern
-	if err := s.patternRepo.Update(ctx, pattern); err != nil {
-		return nil, fmt.Errorf("failed to update search pattern: %w", err)
+	err = s.changeSettings(ctx, func(ctx context.Context) ([]*models.SettingsRevision, error) {
+		if err := s.patternRepo.Update(ctx, pattern); err != nil {
+			return nil, fmt.Errorf("failed to update search pattern: %w", err)
+		}
+		return []*models.SettingsRevision{models.NewSettingsRevision(userID, models.ResourceSearchPattern, pattern.ID, models.RevisionUpdated, pattern)}, nil
+	})
+	if err != nil {
+		return nil, err
 	}
 
 	log.Info().
@@ -420,8 +685,14 @@ func (s *SettingsService) DeleteSearchPattern(ctx context.Context, userID int64,
 	}
 
 	// Delete the pattern
-	if err := s.patternRepo.Delete(ctx, patternID); err != nil {
-		return fmt.Errorf("failed to delete search pattern: %w", err)
+	err = s.changeSettings(ctx, func(ctx context.Context) ([]*models.SettingsRevision, error) {
+		if err := s.patternRepo.Delete(ctx, patternID); err != nil {
+			return nil, fmt.Errorf("failed to delete search pattern: %w", err)
+		}
+		return []*models.SettingsRevision{models.NewSettingsRevision(userID, models.ResourceSearchPattern, patternID, models.RevisionDeleted, nil)}, nil
+	})
+	if err != nil {
+		return err
 	}
 
 	log.Info().
@@ -432,6 +703,82 @@ func (s *SettingsService) DeleteSearchPattern(ctx context.Context, userID int64,
 	return nil
 }
 
+// ImportCatalogPatterns copies patterns from the search pattern catalog into a user's
+// settings. Patterns the user already has, with the same type and text, are not copied
+// again, so importing is safe to repeat.
+//
+// Parameters:
+//   - ctx: Context for the operation
+//   - userID: The ID of the user to copy the patterns to
+//   - keys: The keys of the catalog patterns to copy
+//
+// Returns:
+//   - The outcome of each key, in the order given; keys not in the catalog fail on their own
+//   - An error if the user's settings or patterns could not be read, or a pattern could not be created
+func (s *SettingsService) ImportCatalogPatterns(ctx context.Context, userID int64, keys []string) ([]models.SearchPatternImport, error) {
+	// Get user settings
+	settings, err := s.GetUserSettings(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	// Index the patterns the user already has
+	patterns, err := s.patternRepo.GetBySettingID(ctx, settings.ID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get search patterns: %w", err)
+	}
+	type patternKey struct {
+		patternType models.PatternType
+		text        string
+	}
+	existing := make(map[patternKey]*models.SearchPattern, len(patterns))
+	for _, pattern := range patterns {
+		existing[patternKey{pattern.PatternType, pattern.PatternText}] = pattern
+	}
+
+	imports := make([]models.SearchPatternImport, len(keys))
+	var created int
+	err = s.changeSettings(ctx, func(ctx context.Context) ([]*models.SettingsRevision, error) {
+		var revisions []*models.SettingsRevision
+		for i, key := range keys {
+			imports[i].Key = key
+			entry, ok := models.FindSearchPatternCatalogEntry(key)
+			if !ok {
+				imports[i].Err = utils.New(utils.ErrNotFound, constants.StatusNotFound, constants.MsgSearchPatternCatalogNotFound)
+				continue
+			}
+
+			match := patternKey{entry.PatternType, entry.PatternText}
+			if pattern, ok := existing[match]; ok {
+				imports[i].Pattern = pattern
+				continue
+			}
+
+			pattern := models.NewSearchPattern(settings.ID, entry.PatternType, entry.PatternText)
+			if err := s.patternRepo.Create(ctx, pattern); err != nil {
+				return nil, fmt.Errorf("failed to create search pattern: %w", err)
+			}
+			existing[match] = pattern
+			imports[i].Pattern = pattern
+			imports[i].Created = true
+			revisions = append(revisions, models.NewSettingsRevision(userID, models.ResourceSearchPattern, pattern.ID, models.RevisionCreated, pattern))
+		}
+		created = len(revisions)
+		return revisions, nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	log.Info().
+		Int64("user_id", userID).
+		Int("requested", len(keys)).
+		Int("created", created).
+		Msg("Search patterns imported from the catalog")
+
+	return imports, nil
+}
+
 // GetModelEntities retrieves model entities for a specific detection method.
 // These entities define custom terms for detection by specific ML/AI methods.
 //
@@ -461,6 +808,33 @@ func (s *SettingsService) GetModelEntities(ctx context.Context, userID int64, me
 	return entities, nil
 }
 
+// StreamModelEntities passes the model entities for a specific detection method to fn
+// one at a time, as they are read from the database.
+// This lets large entity lists be exported without holding them in memory.
+//
+// Parameters:
+//   - ctx: Context for the operation
+//   - userID: The ID of the user whose entities to retrieve
+//   - methodID: The ID of the detection method to filter by
+//   - fn: Called for each entity; returning an error stops the stream
+//
+// Returns:
+//   - The error returned by fn, or an error if retrieval fails
+func (s *SettingsService) StreamModelEntities(ctx context.Context, userID int64, methodID int64, fn func(*models.ModelEntityWithMethod) error) error {
+	// Get user settings
+	settings, err := s.GetUserSettings(ctx, userID)
+	if err != nil {
+		return err
+	}
+
+	// Stream model entities
+	if err := s.modelEntityRepo.StreamBySettingIDAndMethodID(ctx, settings.ID, methodID, fn); err != nil {
+		return fmt.Errorf("failed to stream model entities: %w", err)
+	}
+
+	return nil
+}
+
 // AddModelEntities adds entities for a specific detection method.
 // These entities customize detection algorithms for specific terms.
 //
@@ -489,8 +863,19 @@ func (s *SettingsService) AddModelEntities(ctx context.Context, userID int64, ba
 	}
 
 	// Add the entities
-	if err := s.modelEntityRepo.CreateBatch(ctx, entities); err != nil {
-		return nil, fmt.Errorf("failed to create model entities: %w", err)
+	err = s.changeSettings(ctx, func(ctx context.Context) ([]*models.SettingsRevision, error) {
+		if err := s.modelEntityRepo.CreateBatch(ctx, entities); err != nil {
+			return nil, fmt.Errorf("failed to create model entities: %w", err)
+		}
+
+		revisions := make([]*models.SettingsRevision, 0, len(entities))
+		for _, entity := range entities {
+			revisions = append(revisions, models.NewSettingsRevision(userID, models.ResourceModelEntity, entity.ID, models.RevisionCreated, entity))
+		}
+		return revisions, nil
+	})
+	if err != nil {
+		return nil, err
 	}
 
 	log.Info().
@@ -534,8 +919,14 @@ func (s *SettingsService) DeleteModelEntity(ctx context.Context, userID int64, e
 	}
 
 	// Delete the entity
-	if err := s.modelEntityRepo.Delete(ctx, entityID); err != nil {
-		return fmt.Errorf("failed to delete model entity: %w", err)
+	err = s.changeSettings(ctx, func(ctx context.Context) ([]*models.SettingsRevision, error) {
+		if err := s.modelEntityRepo.Delete(ctx, entityID); err != nil {
+			return nil, fmt.Errorf("failed to delete model entity: %w", err)
+		}
+		return []*models.SettingsRevision{models.NewSettingsRevision(userID, models.ResourceModelEntity, entityID, models.RevisionDeleted, nil)}, nil
+	})
+	if err != nil {
+		return err
 	}
 
 	log.Info().
@@ -565,9 +956,28 @@ func (s *SettingsService) DeleteModelEntityByMethodID(ctx context.Context, userI
 		return err
 	}
 
+	// Look up the affected entities first so their deletion can be recorded
+	existing, err := s.modelEntityRepo.GetBySettingID(ctx, settings.ID)
+	if err != nil {
+		return fmt.Errorf("failed to get model entities: %w", err)
+	}
+
 	// Delete the model entities by method ID
-	if err := s.modelEntityRepo.DeleteByMethodID(ctx, settings.ID, methodID); err != nil {
-		return fmt.Errorf("failed to delete model entities by method ID: %w", err)
+	err = s.changeSettings(ctx, func(ctx context.Context) ([]*models.SettingsRevision, error) {
+		if err := s.modelEntityRepo.DeleteByMethodID(ctx, settings.ID, methodID); err != nil {
+			return nil, fmt.Errorf("failed to delete model entities by method ID: %w", err)
+		}
+
+		var revisions []*models.SettingsRevision
+		for _, entity := range existing {
+			if entity.MethodID == methodID {
+				revisions = append(revisions, models.NewSettingsRevision(userID, models.ResourceModelEntity, entity.ID, models.RevisionDeleted, nil))
+			}
+		}
+		return revisions, nil
+	})
+	if err != nil {
+		return err
 	}
 
 	log.Info().
@@ -578,6 +988,56 @@ func (s *SettingsService) DeleteModelEntityByMethodID(ctx context.Context, userI
 	return nil
 }
 
+// ReplaceModelEntities replaces all model entities of a detection method with a new list
+// in one transaction. Entities whose text is on both lists are kept, so their IDs don't change.
+//
+// Parameters:
+//   - ctx: Context for the operation
+//   - userID: The ID of the user whose entities to replace
+//   - methodID: The ID of the detection method whose entities to replace
+//   - replace: The texts of the entities after the replacement
+//
+// Returns:
+//   - The entities after the replacement and those created and deleted
+//   - An error if retrieval or the replacement fails; nothing is changed then
+func (s *SettingsService) ReplaceModelEntities(ctx context.Context, userID int64, methodID int64, replace *models.ModelEntityReplace) (*models.ModelEntityReplacement, error) {
+	// Get user settings
+	settings, err := s.GetUserSettings(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	var replacement *models.ModelEntityReplacement
+	err = s.changeSettings(ctx, func(ctx context.Context) ([]*models.SettingsRevision, error) {
+		var err error
+		replacement, err = s.modelEntityRepo.ReplaceByMethodID(ctx, settings.ID, methodID, replace.EntityTexts)
+		if err != nil {
+			return nil, fmt.Errorf("failed to replace model entities: %w", err)
+		}
+
+		revisions := make([]*models.SettingsRevision, 0, len(replacement.Created)+len(replacement.Deleted))
+		for _, entity := range replacement.Deleted {
+			revisions = append(revisions, models.NewSettingsRevision(userID, models.ResourceModelEntity, entity.ID, models.RevisionDeleted, nil))
+		}
+		for _, entity := range replacement.Created {
+			revisions = append(revisions, models.NewSettingsRevision(userID, models.ResourceModelEntity, entity.ID, models.RevisionCreated, entity))
+		}
+		return revisions, nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	log.Info().
+		Int64("user_id", userID).
+		Int64("method_id", methodID).
+		Int("created", len(replacement.Created)).
+		Int("deleted", len(replacement.Deleted)).
+		Msg("Model entities replaced")
+
+	return replacement, nil
+}
+
 // ExportSettings exports all settings for a user.
 // This creates a comprehensive export of all user preferences and configurations
 // that can be used for backup or transfer to another environment.
@@ -594,6 +1054,57 @@ func (s *SettingsService) DeleteModelEntityByMethodID(ctx context.Context, userI
 // This method collects all user settings components into a single export object
 // with the current timestamp.
 func (s *SettingsService) ExportSettings(ctx context.Context, userID int64) (*models.SettingsExport, error) {
+	// Exports always read the current settings rather than a cached composition
+	detectionConfig, err := s.composeDetectionConfig(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	// Build export object
+	export := &models.SettingsExport{
+		Version:         models.SettingsExportVersion,
+		UserID:          userID,
+		ExportDate:      s.now(),
+		GeneralSettings: detectionConfig.GeneralSettings,
+		BanList:         detectionConfig.BanList,
+		SearchPatterns:  detectionConfig.SearchPatterns,
+		ModelEntities:   detectionConfig.ModelEntities,
+	}
+
+	return export, nil
+}
+
+// GetDetectionConfig returns everything the detection service needs to process documents
+// for a user. Bursts of detection requests for one user, as sent by batch processing, are
+// served from a single composition when a cache is set.
+//
+// Parameters:
+//   - ctx: Context for the operation
+//   - userID: The ID of the user whose configuration to return
+//
+// Returns:
+//   - The detection configuration; it may be shared with other requests and must not be modified
+//   - Whether it was served from the cache
+//   - An error if retrieval fails
+func (s *SettingsService) GetDetectionConfig(ctx context.Context, userID int64) (*models.DetectionConfig, bool, error) {
+	if s.detectionConfigCache == nil {
+		detectionConfig, err := s.composeDetectionConfig(ctx, userID)
+		return detectionConfig, false, err
+	}
+	return s.detectionConfigCache.Get(ctx, userID, s.composeDetectionConfig)
+}
+
+// composeDetectionConfig reads the general settings, ban list, search patterns and model
+// entities of a user from the database.
+//
+// Parameters:
+//   - ctx: Context for the operation
+//   - userID: The ID of the user whose settings to read
+//
+// Returns:
+//   - The composed configuration
+//   - An error if retrieval fails
+func (s *SettingsService) composeDetectionConfig(ctx context.Context, userID int64) (*models.DetectionConfig, error) {
 	// Get user settings
 	settings, err := s.GetUserSettings(ctx, userID)
 	if err != nil {
@@ -639,17 +1150,14 @@ func (s *SettingsService) ExportSettings(ctx context.Context, userID int64) (*mo
 		allEntities = append(allEntities, entitiesWithMethod...)
 	}
 
-	// Build export object
-	export := &models.SettingsExport{
+	return &models.DetectionConfig{
 		UserID:          userID,
-		ExportDate:      time.Now(),
 		GeneralSettings: settings,
 		BanList:         banList,
 		SearchPatterns:  patterns,
 		ModelEntities:   allEntities,
-	}
-
-	return export, nil
+		ComposedAt:      s.now(),
+	}, nil
 }
 
 // ImportSettings imports settings for a user.
@@ -666,11 +1174,14 @@ func (s *SettingsService) ExportSettings(ctx context.Context, userID int64) (*mo
 //
 // This method performs several steps in sequence:
 // 1. Updates general settings
-// 2. Replaces the ban list with imported words
+// 2. Replaces the ban list with imported words and options
 // 3. Replaces search patterns with imported patterns
 // 4. Replaces model entities with imported entities
 // Each step is handled separately to ensure partial updates still succeed.
 func (s *SettingsService) ImportSettings(ctx context.Context, userID int64, importData *models.SettingsExport) error {
+	// Steps that succeed before a failing one stay applied, so the cache is dropped either way
+	defer s.invalidateDetectionConfig(userID)
+
 	// 1. Update general settings
 	// Create an update object based on the imported settings
 	update := &models.UserSettingsUpdate{
@@ -679,6 +1190,7 @@ func (s *SettingsService) ImportSettings(ctx context.Context, userID int64, impo
 		AutoProcessing:         &importData.GeneralSettings.AutoProcessing,
 		DetectionThreshold:     &importData.GeneralSettings.DetectionThreshold,
 		UseBanlistForDetection: &importData.GeneralSettings.UseBanlistForDetection,
+		RedactionPlaceholders:  importData.GeneralSettings.RedactionPlaceholders,
 	}
 
 	// Use _ to discard the first return value since we only need the error
@@ -695,16 +1207,42 @@ func (s *SettingsService) ImportSettings(ctx context.Context, userID int64, impo
 
 	// Remove existing words
 	if len(currentBanList.Words) > 0 {
-		if err := s.RemoveBanListWords(ctx, userID, currentBanList.Words); err != nil {
+		if _, err := s.RemoveBanListWords(ctx, userID, currentBanList.Words); err != nil {
 			return fmt.Errorf("failed to clear ban list: %w", err)
 		}
 	}
 
+	// Restore how all words are matched
+	if importData.BanList != nil {
+		options := importData.BanList.BanListOptions
+		update := &models.BanListOptionsUpdate{
+			CaseInsensitive:      &options.CaseInsensitive,
+			DiacriticInsensitive: &options.DiacriticInsensitive,
+			WholeWord:            &options.WholeWord,
+		}
+		if err := s.SetBanListOptions(ctx, userID, update); err != nil {
+			return fmt.Errorf("failed to import ban list options: %w", err)
+		}
+	}
+
 	// Add new words
-	if len(importData.BanList.Words) > 0 {
+	if importData.BanList != nil && len(importData.BanList.Words) > 0 {
 		if err := s.AddBanListWords(ctx, userID, importData.BanList.Words); err != nil {
 			return fmt.Errorf("failed to import ban list words: %w", err)
 		}
+
+		// Words sharing options are set together; the options of the list were restored above
+		wordsByOptions := make(map[models.BanListMatchOptions][]string)
+		for _, word := range importData.BanList.Words {
+			if options := importData.BanList.Options[word]; !options.IsZero() {
+				wordsByOptions[options] = append(wordsByOptions[options], word)
+			}
+		}
+		for options, words := range wordsByOptions {
+			if err := s.SetBanListWordOptions(ctx, userID, words, options); err != nil {
+				return fmt.Errorf("failed to import ban list word options: %w", err)
+			}
+		}
 	}
 
 	// 3. Handle search patterns
@@ -764,3 +1302,145 @@ func (s *SettingsService) ImportSettings(ctx context.Context, userID int64, impo
 
 	return nil
 }
+
+// GetSettingsChanges returns the settings revisions recorded after a given revision.
+// It supports long-polling: when no newer revisions exist and wait is positive,
+// the method keeps checking until a change arrives, wait elapses, or ctx is cancelled.
+//
+// Parameters:
+//   - ctx: Context for the operation
+//   - userID: The ID of the user whose change feed to read
+//   - since: The last revision the client has already applied
+//   - limit: The maximum number of revisions to return
+//   - wait: How long to wait for new revisions when none are available (0 returns immediately)
+//
+// Returns:
+//   - The change feed, whose LatestRevision should be used as the next since value
+//   - ValidationError if since is negative
+//   - Other errors if retrieval fails
+func (s *SettingsService) GetSettingsChanges(ctx context.Context, userID int64, since int64, limit int, wait time.Duration) (*models.SettingsChangeFeed, error) {
+	if since < 0 {
+		return nil, utils.NewValidationError(constants.QueryParamSince, "Revision must not be negative")
+	}
+	if limit <= 0 || limit > constants.MaxSettingsChangesPerPage {
+		limit = constants.MaxSettingsChangesPerPage
+	}
+	if wait > constants.MaxSettingsChangesWait {
+		wait = constants.MaxSettingsChangesWait
+	}
+
+	deadline := s.now().Add(wait)
+	for {
+		// Fetch one extra revision to know whether the client has to page
+		revisions, err := s.revisionRepo.GetSince(ctx, userID, since, limit+1)
+		if err != nil {
+			return nil, fmt.Errorf("failed to get settings changes: %w", err)
+		}
+
+		if len(revisions) > 0 || !s.now().Before(deadline) {
+			return buildChangeFeed(since, revisions, limit), nil
+		}
+
+		// Nothing new yet, wait for the next poll or until the client goes away
+		select {
+		case <-ctx.Done():
+			return buildChangeFeed(since, revisions, limit), nil
+		case <-time.After(constants.SettingsChangesPollInterval):
+		}
+	}
+}
+
+// buildChangeFeed assembles a change feed from revisions fetched with one extra row.
+//
+// Parameters:
+//   - since: The revision the client asked to start after
+//   - revisions: The fetched revisions (up to limit+1)
+//   - limit: The page size requested from the repository
+//
+// Returns:
+//   - The change feed for the response
+func buildChangeFeed(since int64, revisions []*models.SettingsRevision, limit int) *models.SettingsChangeFeed {
+	feed := &models.SettingsChangeFeed{
+		Since:          since,
+		LatestRevision: since,
+		Changes:        revisions,
+	}
+
+	if len(revisions) > limit {
+		feed.HasMore = true
+		feed.Changes = revisions[:limit]
+	}
+
+	if len(feed.Changes) > 0 {
+		feed.LatestRevision = feed.Changes[len(feed.Changes)-1].Revision
+	}
+
+	return feed
+}
+
+// changeSettings runs a settings change and records the revisions it returns in one
+// transaction, so the change feed neither misses a committed change nor shows one that
+// was rolled back. Once the change is committed, the user's other clients are told.
+//
+// Parameters:
+//   - ctx: Context for the operation
+//   - change: Makes the change with the context it is given and returns its revisions
+//
+// Returns:
+//   - The error of the change, or an error if its revisions could not be recorded; nothing is changed then
+func (s *SettingsService) changeSettings(ctx context.Context, change func(ctx context.Context) ([]*models.SettingsRevision, error)) error {
+	var revisions []*models.SettingsRevision
+	err := inTransaction(ctx, s.transactor, func(ctx context.Context) error {
+		var err error
+		if revisions, err = change(ctx); err != nil {
+			return err
+		}
+		if err := s.revisionRepo.Record(ctx, revisions...); err != nil {
+			return fmt.Errorf("failed to record settings revisions: %w", err)
+		}
+		return nil
+	})
+	if err != nil || len(revisions) == 0 {
+		return err
+	}
+
+	// Every settings change records revisions, so the cached composition is dropped here
+	s.invalidateDetectionConfig(revisions[0].UserID)
+
+	// Tell the user's other clients to fetch the changes
+	if s.notifier != nil {
+		s.notifier.Notify(ctx, revisions[0].UserID, constants.NotificationSettingsChanged, models.SettingsChangedNotification{
+			LatestRevision: revisions[len(revisions)-1].Revision,
+		})
+	}
+
+	return nil
+}
+
+// invalidateDetectionConfig drops the cached detection configuration of a user.
+//
+// Parameters:
+//   - userID: The ID of the user whose settings changed
+func (s *SettingsService) invalidateDetectionConfig(userID int64) {
+	if s.detectionConfigCache != nil {
+		s.detectionConfigCache.Invalidate(userID)
+	}
+}
+
+// banListWordRevisions creates one revision per ban list word.
+//
+// Parameters:
+//   - userID: The ID of the user who owns the ban list
+//   - banListID: The ID of the ban list that changed
+//   - words: The words that were added or removed
+//   - action: Whether the words were created or deleted
+//
+// Returns:
+//   - A revision for each word
+func banListWordRevisions(userID, banListID int64, words []string, action models.RevisionAction) []*models.SettingsRevision {
+	revisions := make([]*models.SettingsRevision, 0, len(words))
+	for _, word := range words {
+		revisions = append(revisions, models.NewSettingsRevision(userID, models.ResourceBanListWord, banListID, action, map[string]string{"word": word}))
+	}
+	return revisions
+}
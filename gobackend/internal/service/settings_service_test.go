@@ -2,10 +2,15 @@ package service
 
 import (
 	"context"
+	"encoding/json"
+	"errors"
 	"fmt"
+	"reflect"
 	"testing"
+	"time"
 
 	"github.com/yasinhessnawi1/Hideme_Backend/internal/models"
+	"github.com/yasinhessnawi1/Hideme_Backend/internal/repository"
 	"github.com/yasinhessnawi1/Hideme_Backend/internal/utils"
 )
 
@@ -43,15 +48,22 @@ func (m *MockSettingsRepository) GetByUserID(ctx context.Context, userID int64)
 	if !ok {
 		return nil, utils.NewNotFoundError("UserSetting", userID)
 	}
-	return settings, nil
+	stored := *settings
+	return &stored, nil
 }
 
 func (m *MockSettingsRepository) Update(ctx context.Context, settings *models.UserSetting) error {
-	if _, ok := m.settings[settings.UserID]; !ok {
+	existing, ok := m.settings[settings.UserID]
+	if !ok {
 		return utils.NewNotFoundError("UserSetting", settings.UserID)
 	}
+	if existing.Version != settings.Version {
+		return repository.ErrSettingsVersionConflict
+	}
 
-	m.settings[settings.UserID] = settings
+	settings.Version++
+	stored := *settings
+	m.settings[settings.UserID] = &stored
 
 	return nil
 }
@@ -92,13 +104,15 @@ func (m *MockSettingsRepository) EnsureDefaultSettings(ctx context.Context, user
 		}
 	}
 
-	return settings, nil
+	stored := *settings
+	return &stored, nil
 }
 
 type MockBanListRepository struct {
 	banLists    map[int64]*models.BanList
 	banListsMap map[int64]int64 // settingID -> banListID
 	words       map[int64]map[string]bool
+	options     map[int64]map[string]models.BanListMatchOptions
 	nextID      int64
 }
 
@@ -107,6 +121,7 @@ func NewMockBanListRepository() *MockBanListRepository {
 		banLists:    make(map[int64]*models.BanList),
 		banListsMap: make(map[int64]int64),
 		words:       make(map[int64]map[string]bool),
+		options:     make(map[int64]map[string]models.BanListMatchOptions),
 		nextID:      1,
 	}
 }
@@ -194,8 +209,41 @@ func (m *MockBanListRepository) RemoveWords(ctx context.Context, banListID int64
 
 	for _, word := range words {
 		delete(wordMap, word)
+		delete(m.options[banListID], word)
+	}
+
+	return nil
+}
+
+func (m *MockBanListRepository) GetWordOptions(ctx context.Context, banListID int64) (map[string]models.BanListMatchOptions, error) {
+	options := make(map[string]models.BanListMatchOptions)
+	for word, option := range m.options[banListID] {
+		if !option.IsZero() {
+			options[word] = option
+		}
+	}
+	return options, nil
+}
+
+func (m *MockBanListRepository) SetWordOptions(ctx context.Context, banListID int64, words []string, options models.BanListMatchOptions) error {
+	if m.options[banListID] == nil {
+		m.options[banListID] = make(map[string]models.BanListMatchOptions)
+	}
+	for _, word := range words {
+		if m.words[banListID][word] {
+			m.options[banListID][word] = options
+		}
 	}
+	return nil
+}
 
+func (m *MockBanListRepository) SetOptions(ctx context.Context, banListID int64, options models.BanListOptions) error {
+	banList, ok := m.banLists[banListID]
+	if !ok {
+		return utils.NewNotFoundError("BanList", banListID)
+	}
+
+	banList.BanListOptions = options
 	return nil
 }
 
@@ -360,6 +408,19 @@ func (m *MockModelEntityRepository) GetBySettingIDAndMethodID(ctx context.Contex
 	return entities, nil
 }
 
+func (m *MockModelEntityRepository) StreamBySettingIDAndMethodID(ctx context.Context, settingID, methodID int64, fn func(*models.ModelEntityWithMethod) error) error {
+	entities, err := m.GetBySettingIDAndMethodID(ctx, settingID, methodID)
+	if err != nil {
+		return err
+	}
+	for _, entity := range entities {
+		if err := fn(entity); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
 func (m *MockModelEntityRepository) Update(ctx context.Context, entity *models.ModelEntity) error {
 	if _, ok := m.entities[entity.ID]; !ok {
 		return utils.NewNotFoundError("ModelEntity", entity.ID)
@@ -431,13 +492,113 @@ func (m *MockModelEntityRepository) DeleteByMethodID(ctx context.Context, settin
 	return nil
 }
 
+func (m *MockModelEntityRepository) ReplaceByMethodID(ctx context.Context, settingID, methodID int64, entityTexts []string) (*models.ModelEntityReplacement, error) {
+	replacement := &models.ModelEntityReplacement{}
+	listed := make(map[string]bool, len(entityTexts))
+	for _, text := range entityTexts {
+		listed[text] = true
+	}
+
+	kept := make(map[string]*models.ModelEntity)
+	var remainingEntities []*models.ModelEntity
+	for _, entity := range m.entitiesByID[settingID] {
+		if entity.MethodID != methodID {
+			remainingEntities = append(remainingEntities, entity)
+		} else if listed[entity.EntityText] && kept[entity.EntityText] == nil {
+			kept[entity.EntityText] = entity
+			remainingEntities = append(remainingEntities, entity)
+		} else {
+			delete(m.entities, entity.ID)
+			replacement.Deleted = append(replacement.Deleted, entity)
+		}
+	}
+	m.entitiesByID[settingID] = remainingEntities
+
+	for _, text := range entityTexts {
+		if entity, ok := kept[text]; ok {
+			if listed[text] {
+				replacement.Entities = append(replacement.Entities, entity)
+				listed[text] = false
+			}
+			continue
+		}
+		entity := models.NewModelEntity(settingID, methodID, text)
+		if err := m.Create(ctx, entity); err != nil {
+			return nil, err
+		}
+		kept[text] = entity
+		listed[text] = false
+		replacement.Entities = append(replacement.Entities, entity)
+		replacement.Created = append(replacement.Created, entity)
+	}
+
+	return replacement, nil
+}
+
+type MockSettingsRevisionRepository struct {
+	revisions    []*models.SettingsRevision
+	nextRevision int64
+	recordErr    error
+}
+
+func NewMockSettingsRevisionRepository() *MockSettingsRevisionRepository {
+	return &MockSettingsRevisionRepository{
+		nextRevision: 1,
+	}
+}
+
+func (m *MockSettingsRevisionRepository) Record(ctx context.Context, revisions ...*models.SettingsRevision) error {
+	if m.recordErr != nil {
+		return m.recordErr
+	}
+	for _, revision := range revisions {
+		revision.Revision = m.nextRevision
+		m.nextRevision++
+		m.revisions = append(m.revisions, revision)
+	}
+
+	return nil
+}
+
+func (m *MockSettingsRevisionRepository) GetSince(ctx context.Context, userID int64, since int64, limit int) ([]*models.SettingsRevision, error) {
+	result := []*models.SettingsRevision{}
+	for _, revision := range m.revisions {
+		if revision.UserID == userID && revision.Revision > since {
+			result = append(result, revision)
+			if len(result) == limit {
+				break
+			}
+		}
+	}
+
+	return result, nil
+}
+
+func (m *MockSettingsRevisionRepository) GetSettingsVersion(ctx context.Context, userID int64, version int64) (*models.UserSetting, error) {
+	for i := len(m.revisions) - 1; i >= 0; i-- {
+		revision := m.revisions[i]
+		if revision.UserID != userID || revision.ResourceType != models.ResourceGeneralSettings {
+			continue
+		}
+		settings := &models.UserSetting{}
+		if err := json.Unmarshal(revision.Data, settings); err != nil {
+			return nil, err
+		}
+		if settings.Version == version {
+			return settings, nil
+		}
+	}
+
+	return nil, utils.NewNotFoundError("UserSettingVersion", version)
+}
+
 func TestNewSettingsService(t *testing.T) {
 	settingsRepo := NewMockSettingsRepository()
 	banListRepo := NewMockBanListRepository()
 	patternRepo := NewMockPatternRepository()
 	modelEntityRepo := NewMockModelEntityRepository()
 
-	service := NewSettingsService(settingsRepo, banListRepo, patternRepo, modelEntityRepo)
+	service := NewSettingsService(settingsRepo, banListRepo, patternRepo, modelEntityRepo, NewMockSettingsRevisionRepository())
 
 	if service == nil {
 		t.Error("Expected non-nil service")
@@ -455,7 +616,7 @@ func TestSettingsService_GetUserSettings(t *testing.T) {
 	userID := int64(123)
 	settingsRepo.RegisterValidUserID(userID)
 
-	service := NewSettingsService(settingsRepo, banListRepo, patternRepo, modelEntityRepo)
+	service := NewSettingsService(settingsRepo, banListRepo, patternRepo, modelEntityRepo, NewMockSettingsRevisionRepository())
 
 	// Test getting settings for a user without settings
 	settings, err := service.GetUserSettings(context.Background(), userID)
@@ -513,7 +674,7 @@ func TestSettingsService_UpdateUserSettings(t *testing.T) {
 	userID := int64(123)
 	settingsRepo.RegisterValidUserID(userID)
 
-	service := NewSettingsService(settingsRepo, banListRepo, patternRepo, modelEntityRepo)
+	service := NewSettingsService(settingsRepo, banListRepo, patternRepo, modelEntityRepo, NewMockSettingsRevisionRepository())
 
 	// Create initial settings
 	initialSettings, err := service.GetUserSettings(context.Background(), userID)
@@ -643,6 +804,124 @@ func TestSettingsService_UpdateUserSettings(t *testing.T) {
 	}
 }
 
+func TestSettingsService_UpdateUserSettings_Version(t *testing.T) {
+	settingsRepo := NewMockSettingsRepository()
+	userID := int64(123)
+	settingsRepo.RegisterValidUserID(userID)
+	service := NewSettingsService(settingsRepo, NewMockBanListRepository(), NewMockPatternRepository(), NewMockModelEntityRepository(), NewMockSettingsRevisionRepository())
+	ctx := context.Background()
+
+	// An update based on the current version is applied and increments it
+	settings, err := service.UpdateUserSettings(ctx, userID, &models.UserSettingsUpdate{Theme: stringPtr("dark"), Version: int64Ptr(1)})
+	if err != nil {
+		t.Fatalf("Failed to update settings: %v", err)
+	}
+	if settings.Version != 2 {
+		t.Errorf("Expected version 2, got %d", settings.Version)
+	}
+
+	// An update based on the old version is refused with the current settings
+	_, err = service.UpdateUserSettings(ctx, userID, &models.UserSettingsUpdate{Theme: stringPtr("light"), Version: int64Ptr(1)})
+	var conflict *SettingsConflictError
+	if !errors.As(err, &conflict) {
+		t.Fatalf("Expected a SettingsConflictError, got %v", err)
+	}
+	if conflict.Conflict.Current.Version != 2 || conflict.Conflict.Current.Theme != "dark" {
+		t.Errorf("Expected the current settings at version 2, got %+v", conflict.Conflict.Current)
+	}
+	if *conflict.Conflict.Submitted.Theme != "light" {
+		t.Errorf("Expected the submitted update, got %+v", conflict.Conflict.Submitted)
+	}
+
+	// An update without a version is not checked
+	settings, err = service.UpdateUserSettings(ctx, userID, &models.UserSettingsUpdate{Theme: stringPtr("light")})
+	if err != nil {
+		t.Fatalf("Failed to update settings: %v", err)
+	}
+	if settings.Theme != "light" || settings.Version != 3 {
+		t.Errorf("Expected light theme at version 3, got %s at %d", settings.Theme, settings.Version)
+	}
+}
+
+func TestSettingsService_MergeUserSettings(t *testing.T) {
+	settingsRepo := NewMockSettingsRepository()
+	userID := int64(123)
+	settingsRepo.RegisterValidUserID(userID)
+	service := NewSettingsService(settingsRepo, NewMockBanListRepository(), NewMockPatternRepository(), NewMockModelEntityRepository(), NewMockSettingsRevisionRepository())
+	ctx := context.Background()
+
+	// Version 2 is recorded, then another device changes the theme in version 3
+	if _, err := service.UpdateUserSettings(ctx, userID, &models.UserSettingsUpdate{DetectionThreshold: float64Ptr(0.6)}); err != nil {
+		t.Fatalf("Failed to update settings: %v", err)
+	}
+	if _, err := service.UpdateUserSettings(ctx, userID, &models.UserSettingsUpdate{Theme: stringPtr("dark")}); err != nil {
+		t.Fatalf("Failed to update settings: %v", err)
+	}
+
+	tests := []struct {
+		name      string
+		merge     *models.SettingsMerge
+		conflicts []string
+		check     func(t *testing.T, settings *models.UserSetting)
+	}{
+		{
+			name:  "Field not changed since",
+			merge: &models.SettingsMerge{BaseVersion: 2, Changes: models.UserSettingsUpdate{RemoveImages: boolPtr(false)}},
+			check: func(t *testing.T, settings *models.UserSetting) {
+				if settings.RemoveImages || settings.Theme != "dark" || settings.Version != 4 {
+					t.Errorf("Expected images kept and dark theme at version 4, got %+v", settings)
+				}
+			},
+		},
+		{
+			name:      "Field changed to another value since",
+			merge:     &models.SettingsMerge{BaseVersion: 2, Changes: models.UserSettingsUpdate{Theme: stringPtr("light"), AutoProcessing: boolPtr(false)}},
+			conflicts: []string{"theme"},
+		},
+		{
+			name:  "Field changed to the same value since",
+			merge: &models.SettingsMerge{BaseVersion: 2, Changes: models.UserSettingsUpdate{Theme: stringPtr("dark"), ScrubFilenames: boolPtr(true)}},
+			check: func(t *testing.T, settings *models.UserSetting) {
+				if !settings.ScrubFilenames || settings.Version != 5 {
+					t.Errorf("Expected scrubbed filenames at version 5, got %+v", settings)
+				}
+			},
+		},
+		{
+			name:      "Base version unknown",
+			merge:     &models.SettingsMerge{BaseVersion: 1, Changes: models.UserSettingsUpdate{DetectionThreshold: float64Ptr(0.9)}},
+			conflicts: []string{"detection_threshold"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			settings, err := service.MergeUserSettings(ctx, userID, tt.merge)
+			if tt.conflicts != nil {
+				var conflict *SettingsConflictError
+				if !errors.As(err, &conflict) {
+					t.Fatalf("Expected a SettingsConflictError, got %v", err)
+				}
+				if fmt.Sprint(conflict.Conflict.ConflictingFields) != fmt.Sprint(tt.conflicts) {
+					t.Errorf("Expected conflicting fields %v, got %v", tt.conflicts, conflict.Conflict.ConflictingFields)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Failed to merge settings: %v", err)
+			}
+			tt.check(t, settings)
+		})
+	}
+
+	t.Run("Base version newer than settings", func(t *testing.T) {
+		_, err := service.MergeUserSettings(ctx, userID, &models.SettingsMerge{BaseVersion: 9})
+		if !utils.IsValidationError(err) {
+			t.Errorf("Expected a validation error, got %v", err)
+		}
+	})
+}
+
 func TestSettingsService_GetBanList(t *testing.T) {
 	// Setup
 	settingsRepo := NewMockSettingsRepository()
@@ -656,7 +935,7 @@ func TestSettingsService_GetBanList(t *testing.T) {
 	settingsRepo.RegisterValidUserID(userID)
 	settingsRepo.RegisterValidUserID(newUserID)
 
-	service := NewSettingsService(settingsRepo, banListRepo, patternRepo, modelEntityRepo)
+	service := NewSettingsService(settingsRepo, banListRepo, patternRepo, modelEntityRepo, NewMockSettingsRevisionRepository())
 
 	// Get settings for a user
 	settings, err := service.GetUserSettings(context.Background(), userID)
@@ -724,6 +1003,112 @@ func TestSettingsService_GetBanList(t *testing.T) {
 	}
 }
 
+func TestSettingsService_SetBanListWordOptions(t *testing.T) {
+	settingsRepo := NewMockSettingsRepository()
+	userID := int64(123)
+	settingsRepo.RegisterValidUserID(userID)
+	service := NewSettingsService(settingsRepo, NewMockBanListRepository(), NewMockPatternRepository(), NewMockModelEntityRepository(), NewMockSettingsRevisionRepository())
+	ctx := context.Background()
+
+	if err := service.AddBanListWords(ctx, userID, []string{"Ström", "Oslo"}); err != nil {
+		t.Fatalf("AddBanListWords() error = %v", err)
+	}
+
+	options := models.BanListMatchOptions{CaseInsensitive: true, DiacriticInsensitive: true, WholeWord: true}
+	if err := service.SetBanListWordOptions(ctx, userID, []string{"Ström", "Bergen"}, options); err != nil {
+		t.Fatalf("SetBanListWordOptions() error = %v", err)
+	}
+
+	// The options are part of the ban list and the detection configuration
+	config, _, err := service.GetDetectionConfig(ctx, userID)
+	if err != nil {
+		t.Fatalf("GetDetectionConfig() error = %v", err)
+	}
+	if got := config.BanList.MatchOptions("Ström"); got != options {
+		t.Errorf("Expected options %+v for Ström, got %+v", options, got)
+	}
+	if _, ok := config.BanList.Options["Oslo"]; ok {
+		t.Error("Expected Oslo to match exactly as written")
+	}
+	if _, ok := config.BanList.Options["Bergen"]; ok {
+		t.Error("Expected words not on the ban list to be ignored")
+	}
+
+	// Unsupported stemming languages are refused
+	err = service.SetBanListWordOptions(ctx, userID, []string{"Oslo"}, models.BanListMatchOptions{Stemming: "de"})
+	if !utils.IsValidationError(err) {
+		t.Errorf("Expected a validation error, got %v", err)
+	}
+}
+
+func TestSettingsService_SetBanListOptions(t *testing.T) {
+	settingsRepo := NewMockSettingsRepository()
+	userID := int64(123)
+	settingsRepo.RegisterValidUserID(userID)
+	revisionRepo := NewMockSettingsRevisionRepository()
+	service := NewSettingsService(settingsRepo, NewMockBanListRepository(), NewMockPatternRepository(), NewMockModelEntityRepository(), revisionRepo)
+	ctx := context.Background()
+
+	// Options can be set before the ban list has any words
+	enabled := true
+	if err := service.SetBanListOptions(ctx, userID, &models.BanListOptionsUpdate{CaseInsensitive: &enabled, WholeWord: &enabled}); err != nil {
+		t.Fatalf("SetBanListOptions() error = %v", err)
+	}
+	if err := service.AddBanListWords(ctx, userID, []string{"Ström"}); err != nil {
+		t.Fatalf("AddBanListWords() error = %v", err)
+	}
+	if err := service.SetBanListWordOptions(ctx, userID, []string{"Ström"}, models.BanListMatchOptions{DiacriticInsensitive: true}); err != nil {
+		t.Fatalf("SetBanListWordOptions() error = %v", err)
+	}
+
+	// Omitted options are kept
+	disabled := false
+	if err := service.SetBanListOptions(ctx, userID, &models.BanListOptionsUpdate{WholeWord: &disabled}); err != nil {
+		t.Fatalf("SetBanListOptions() error = %v", err)
+	}
+
+	banList, err := service.GetBanList(ctx, userID)
+	if err != nil {
+		t.Fatalf("GetBanList() error = %v", err)
+	}
+	if want := (models.BanListOptions{CaseInsensitive: true}); banList.BanListOptions != want {
+		t.Errorf("Expected ban list options %+v, got %+v", want, banList.BanListOptions)
+	}
+
+	// Words are matched with the options of the list on top of their own
+	want := models.BanListMatchOptions{CaseInsensitive: true, DiacriticInsensitive: true}
+	if got := banList.MatchOptions("Ström"); got != want {
+		t.Errorf("Expected options %+v for Ström, got %+v", want, got)
+	}
+
+	last := revisionRepo.revisions[len(revisionRepo.revisions)-1]
+	if last.ResourceType != models.ResourceBanList || last.Action != models.RevisionUpdated {
+		t.Errorf("Expected a ban list revision, got %s %s", last.ResourceType, last.Action)
+	}
+
+	// Export and import keep the options of the list apart from those of each word
+	exported, err := service.ExportSettings(ctx, userID)
+	if err != nil {
+		t.Fatalf("ExportSettings() error = %v", err)
+	}
+	if err := service.SetBanListOptions(ctx, userID, &models.BanListOptionsUpdate{CaseInsensitive: &disabled}); err != nil {
+		t.Fatalf("SetBanListOptions() error = %v", err)
+	}
+	if err := service.ImportSettings(ctx, userID, exported); err != nil {
+		t.Fatalf("ImportSettings() error = %v", err)
+	}
+	imported, err := service.GetBanList(ctx, userID)
+	if err != nil {
+		t.Fatalf("GetBanList() error = %v", err)
+	}
+	if imported.BanListOptions != banList.BanListOptions {
+		t.Errorf("Expected imported ban list options %+v, got %+v", banList.BanListOptions, imported.BanListOptions)
+	}
+	if got := imported.Options["Ström"]; got != (models.BanListMatchOptions{DiacriticInsensitive: true}) {
+		t.Errorf("Expected Ström to keep only its own options, got %+v", got)
+	}
+}
+
 func TestSettingsService_AddBanListWords(t *testing.T) {
 	// Setup
 	settingsRepo := NewMockSettingsRepository()
@@ -735,7 +1120,7 @@ func TestSettingsService_AddBanListWords(t *testing.T) {
 	userID := int64(123)
 	settingsRepo.RegisterValidUserID(userID)
 
-	service := NewSettingsService(settingsRepo, banListRepo, patternRepo, modelEntityRepo)
+	service := NewSettingsService(settingsRepo, banListRepo, patternRepo, modelEntityRepo, NewMockSettingsRevisionRepository())
 
 	// Get settings for a user
 	_, err := service.GetUserSettings(context.Background(), userID)
@@ -809,6 +1194,82 @@ func TestSettingsService_AddBanListWords(t *testing.T) {
 	}
 }
 
+func TestSettingsService_NotifiesSettingsChanges(t *testing.T) {
+	settingsRepo := NewMockSettingsRepository()
+	userID := int64(123)
+	settingsRepo.RegisterValidUserID(userID)
+
+	notifier := &recordingNotifier{}
+	service := NewSettingsService(settingsRepo, NewMockBanListRepository(), NewMockPatternRepository(), NewMockModelEntityRepository(), NewMockSettingsRevisionRepository())
+	service.SetNotifier(notifier)
+
+	if _, err := service.GetUserSettings(context.Background(), userID); err != nil {
+		t.Fatalf("Failed to get user settings: %v", err)
+	}
+	if err := service.AddBanListWords(context.Background(), userID, []string{"sensitive", "confidential"}); err != nil {
+		t.Fatalf("AddBanListWords() error = %v", err)
+	}
+
+	// One notification for all words, with the revision of the last one
+	if len(notifier.events) != 1 || notifier.events[0] != "settings_changed" {
+		t.Fatalf("Expected one settings_changed notification, got %v", notifier.events)
+	}
+	if data, ok := notifier.data[0].(models.SettingsChangedNotification); !ok || data.LatestRevision != 2 {
+		t.Errorf("Expected latest revision 2, got %v", notifier.data[0])
+	}
+}
+
+// recordingTransactor runs functions directly and records the outcome of each transaction.
+type recordingTransactor struct {
+	outcomes []error
+}
+
+func (r *recordingTransactor) WithTransaction(ctx context.Context, fn func(ctx context.Context) error) error {
+	err := fn(ctx)
+	r.outcomes = append(r.outcomes, err)
+	return err
+}
+
+func TestSettingsService_RecordsRevisionsInTransaction(t *testing.T) {
+	settingsRepo := NewMockSettingsRepository()
+	userID := int64(123)
+	settingsRepo.RegisterValidUserID(userID)
+
+	revisionRepo := NewMockSettingsRevisionRepository()
+	transactor := &recordingTransactor{}
+	notifier := &recordingNotifier{}
+	service := NewSettingsService(settingsRepo, NewMockBanListRepository(), NewMockPatternRepository(), NewMockModelEntityRepository(), revisionRepo)
+	service.SetTransactor(transactor)
+	service.SetNotifier(notifier)
+
+	if _, err := service.GetUserSettings(context.Background(), userID); err != nil {
+		t.Fatalf("Failed to get user settings: %v", err)
+	}
+
+	// The change and its revisions commit together
+	if err := service.AddBanListWords(context.Background(), userID, []string{"sensitive"}); err != nil {
+		t.Fatalf("AddBanListWords() error = %v", err)
+	}
+	if len(transactor.outcomes) != 1 || transactor.outcomes[0] != nil {
+		t.Fatalf("Expected one committed transaction, got %v", transactor.outcomes)
+	}
+	if len(revisionRepo.revisions) != 1 {
+		t.Fatalf("Expected 1 revision, got %d", len(revisionRepo.revisions))
+	}
+
+	// A revision that can't be recorded fails the change, which is rolled back
+	revisionRepo.recordErr = errors.New("database error")
+	if err := service.AddBanListWords(context.Background(), userID, []string{"confidential"}); err == nil {
+		t.Fatal("Expected an error when the revision can't be recorded")
+	}
+	if len(transactor.outcomes) != 2 || transactor.outcomes[1] == nil {
+		t.Fatalf("Expected the second transaction to roll back, got %v", transactor.outcomes)
+	}
+	if len(notifier.events) != 1 {
+		t.Errorf("Expected no notification for the rolled back change, got %v", notifier.events)
+	}
+}
+
 func TestSettingsService_RemoveBanListWords(t *testing.T) {
 	// Setup
 	settingsRepo := NewMockSettingsRepository()
@@ -822,7 +1283,7 @@ func TestSettingsService_RemoveBanListWords(t *testing.T) {
 	settingsRepo.RegisterValidUserID(userID)
 	settingsRepo.RegisterValidUserID(newUserID)
 
-	service := NewSettingsService(settingsRepo, banListRepo, patternRepo, modelEntityRepo)
+	service := NewSettingsService(settingsRepo, banListRepo, patternRepo, modelEntityRepo, NewMockSettingsRevisionRepository())
 
 	// Get settings for a user
 	settings, err := service.GetUserSettings(context.Background(), userID)
@@ -844,10 +1305,13 @@ func TestSettingsService_RemoveBanListWords(t *testing.T) {
 
 	// Test removing some words
 	wordsToRemove := []string{"sensitive", "confidential"}
-	err = service.RemoveBanListWords(context.Background(), userID, wordsToRemove)
+	missing, err := service.RemoveBanListWords(context.Background(), userID, wordsToRemove)
 	if err != nil {
 		t.Fatalf("RemoveBanListWords() error = %v", err)
 	}
+	if len(missing) != 0 {
+		t.Errorf("Expected no missing words, got %v", missing)
+	}
 
 	// Get the ban list to verify words were removed
 	banListWithWords, err := service.GetBanList(context.Background(), userID)
@@ -872,25 +1336,28 @@ func TestSettingsService_RemoveBanListWords(t *testing.T) {
 	}
 
 	// Test removing words that don't exist
-	nonExistentWords := []string{"nonexistent", "missing"}
-	err = service.RemoveBanListWords(context.Background(), userID, nonExistentWords)
+	nonExistentWords := []string{"nonexistent", "restricted", "missing"}
+	missing, err = service.RemoveBanListWords(context.Background(), userID, nonExistentWords)
 	if err != nil {
 		t.Fatalf("RemoveBanListWords() error = %v", err)
 	}
+	if !reflect.DeepEqual(missing, []string{"nonexistent", "missing"}) {
+		t.Errorf("Expected the words not on the list to be returned, got %v", missing)
+	}
 
-	// Verify count is still 3
+	// Verify count is now 2
 	banListWithWords, err = service.GetBanList(context.Background(), userID)
 	if err != nil {
 		t.Fatalf("Failed to get ban list: %v", err)
 	}
 
-	if len(banListWithWords.Words) != 3 {
-		t.Errorf("Expected 3 words, got %d", len(banListWithWords.Words))
+	if len(banListWithWords.Words) != 2 {
+		t.Errorf("Expected 2 words, got %d", len(banListWithWords.Words))
 	}
 
 	// Test removing all remaining words
-	remainingWords := []string{"restricted", "classified", "private"}
-	err = service.RemoveBanListWords(context.Background(), userID, remainingWords)
+	remainingWords := []string{"classified", "private"}
+	_, err = service.RemoveBanListWords(context.Background(), userID, remainingWords)
 	if err != nil {
 		t.Fatalf("RemoveBanListWords() error = %v", err)
 	}
@@ -906,7 +1373,7 @@ func TestSettingsService_RemoveBanListWords(t *testing.T) {
 	}
 
 	// Test error case - user not found
-	err = service.RemoveBanListWords(context.Background(), int64(999), []string{"test"})
+	_, err = service.RemoveBanListWords(context.Background(), int64(999), []string{"test"})
 	if err == nil {
 		t.Error("Expected error for non-existent user, got nil")
 	}
@@ -918,10 +1385,13 @@ func TestSettingsService_RemoveBanListWords(t *testing.T) {
 	}
 
 	// Remove a word from a non-existent ban list (will be no-op)
-	err = service.RemoveBanListWords(context.Background(), newUserID, []string{"test"})
+	missing, err = service.RemoveBanListWords(context.Background(), newUserID, []string{"test"})
 	if err != nil {
 		t.Errorf("RemoveBanListWords() error = %v", err)
 	}
+	if len(missing) != 1 {
+		t.Errorf("Expected the word to be missing, got %v", missing)
+	}
 }
 
 func TestSettingsService_GetSearchPatterns(t *testing.T) {
@@ -937,7 +1407,7 @@ func TestSettingsService_GetSearchPatterns(t *testing.T) {
 	settingsRepo.RegisterValidUserID(userID)
 	settingsRepo.RegisterValidUserID(newUserID)
 
-	service := NewSettingsService(settingsRepo, banListRepo, patternRepo, modelEntityRepo)
+	service := NewSettingsService(settingsRepo, banListRepo, patternRepo, modelEntityRepo, NewMockSettingsRevisionRepository())
 
 	// Get settings for a user
 	settings, err := service.GetUserSettings(context.Background(), userID)
@@ -1029,7 +1499,7 @@ func TestSettingsService_CreateSearchPattern(t *testing.T) {
 	userID := int64(123)
 	settingsRepo.RegisterValidUserID(userID)
 
-	service := NewSettingsService(settingsRepo, banListRepo, patternRepo, modelEntityRepo)
+	service := NewSettingsService(settingsRepo, banListRepo, patternRepo, modelEntityRepo, NewMockSettingsRevisionRepository())
 
 	// Get settings for a user
 	_, err := service.GetUserSettings(context.Background(), userID)
@@ -1062,6 +1532,24 @@ func TestSettingsService_CreateSearchPattern(t *testing.T) {
 			patternText: "test pattern",
 			expectError: true,
 		},
+		{
+			name:        "Invalid regular expression",
+			patternType: string(models.Normal),
+			patternText: `\b[0-9`,
+			expectError: true,
+		},
+		{
+			name:        "Unsafe regular expression",
+			patternType: string(models.CaseSensitive),
+			patternText: `^(\d+)*$`,
+			expectError: true,
+		},
+		{
+			name:        "AI search pattern is not a regular expression",
+			patternType: string(models.AISearch),
+			patternText: "names of (people",
+			expectError: false,
+		},
 	}
 
 	for _, tc := range tests {
@@ -1139,7 +1627,7 @@ func TestSettingsService_UpdateSearchPattern(t *testing.T) {
 	settingsRepo.RegisterValidUserID(userID)
 	settingsRepo.RegisterValidUserID(newUserID)
 
-	service := NewSettingsService(settingsRepo, banListRepo, patternRepo, modelEntityRepo)
+	service := NewSettingsService(settingsRepo, banListRepo, patternRepo, modelEntityRepo, NewMockSettingsRevisionRepository())
 
 	// Get settings for a user
 	settings, err := service.GetUserSettings(context.Background(), userID)
@@ -1214,6 +1702,13 @@ func TestSettingsService_UpdateSearchPattern(t *testing.T) {
 			},
 			expectError: true,
 		},
+		{
+			name: "Unsafe regular expression",
+			update: &models.SearchPatternUpdate{
+				PatternText: `(a|aa)+(b+)*`,
+			},
+			expectError: true,
+		},
 	}
 
 	for _, tc := range tests {
@@ -1277,6 +1772,62 @@ func TestSettingsService_UpdateSearchPattern(t *testing.T) {
 	}
 }
 
+func TestSettingsService_ImportCatalogPatterns(t *testing.T) {
+	// Setup
+	ctx := context.Background()
+	settingsRepo := NewMockSettingsRepository()
+	patternRepo := NewMockPatternRepository()
+	userID := int64(123)
+	settingsRepo.RegisterValidUserID(userID)
+	service := NewSettingsService(settingsRepo, NewMockBanListRepository(), patternRepo, NewMockModelEntityRepository(), NewMockSettingsRevisionRepository())
+
+	settings, err := service.GetUserSettings(ctx, userID)
+	if err != nil {
+		t.Fatalf("Failed to get user settings: %v", err)
+	}
+
+	// The user already has the SSN pattern
+	ssn, _ := models.FindSearchPatternCatalogEntry("us_ssn")
+	existing := models.NewSearchPattern(settings.ID, ssn.PatternType, ssn.PatternText)
+	if err := patternRepo.Create(ctx, existing); err != nil {
+		t.Fatalf("Failed to create pattern: %v", err)
+	}
+
+	imports, err := service.ImportCatalogPatterns(ctx, userID, []string{"iban", "us_ssn", "unknown", "iban"})
+	if err != nil {
+		t.Fatalf("ImportCatalogPatterns() error = %v", err)
+	}
+	if len(imports) != 4 {
+		t.Fatalf("Expected 4 outcomes, got %d", len(imports))
+	}
+
+	// New patterns are created once, even if asked for twice
+	if !imports[0].Created || imports[0].Pattern.PatternType != models.CaseSensitive {
+		t.Errorf("Expected the IBAN pattern to be created as case sensitive, got %+v", imports[0])
+	}
+	if imports[3].Created || imports[3].Pattern.ID != imports[0].Pattern.ID {
+		t.Errorf("Expected the second IBAN to reuse pattern %d, got %+v", imports[0].Pattern.ID, imports[3])
+	}
+
+	// Patterns the user already has are not copied again
+	if imports[1].Created || imports[1].Pattern.ID != existing.ID {
+		t.Errorf("Expected the SSN to reuse pattern %d, got %+v", existing.ID, imports[1])
+	}
+
+	// Unknown keys fail on their own
+	if imports[2].Pattern != nil || !errors.Is(imports[2].Err, utils.ErrNotFound) {
+		t.Errorf("Expected the unknown key to fail as not found, got %+v", imports[2])
+	}
+
+	patterns, err := service.GetSearchPatterns(ctx, userID)
+	if err != nil {
+		t.Fatalf("GetSearchPatterns() error = %v", err)
+	}
+	if len(patterns) != 2 {
+		t.Errorf("Expected 2 patterns, got %d", len(patterns))
+	}
+}
+
 func TestSettingsService_DeleteSearchPattern(t *testing.T) {
 	// Setup
 	settingsRepo := NewMockSettingsRepository()
@@ -1290,7 +1841,7 @@ func TestSettingsService_DeleteSearchPattern(t *testing.T) {
 	settingsRepo.RegisterValidUserID(userID)
 	settingsRepo.RegisterValidUserID(newUserID)
 
-	service := NewSettingsService(settingsRepo, banListRepo, patternRepo, modelEntityRepo)
+	service := NewSettingsService(settingsRepo, banListRepo, patternRepo, modelEntityRepo, NewMockSettingsRevisionRepository())
 
 	// Get settings for a user
 	settings, err := service.GetUserSettings(context.Background(), userID)
@@ -1364,7 +1915,7 @@ func TestSettingsService_GetModelEntities(t *testing.T) {
 	userID := int64(123)
 	settingsRepo.RegisterValidUserID(userID)
 
-	service := NewSettingsService(settingsRepo, banListRepo, patternRepo, modelEntityRepo)
+	service := NewSettingsService(settingsRepo, banListRepo, patternRepo, modelEntityRepo, NewMockSettingsRevisionRepository())
 
 	// Get settings for a user
 	settings, err := service.GetUserSettings(context.Background(), userID)
@@ -1463,6 +2014,53 @@ func TestSettingsService_GetModelEntities(t *testing.T) {
 	}
 }
 
+func TestSettingsService_StreamModelEntities(t *testing.T) {
+	// Setup
+	settingsRepo := NewMockSettingsRepository()
+	modelEntityRepo := NewMockModelEntityRepository()
+
+	userID := int64(123)
+	settingsRepo.RegisterValidUserID(userID)
+
+	service := NewSettingsService(settingsRepo, NewMockBanListRepository(), NewMockPatternRepository(), modelEntityRepo, NewMockSettingsRevisionRepository())
+
+	settings, err := service.GetUserSettings(context.Background(), userID)
+	if err != nil {
+		t.Fatalf("Failed to get user settings: %v", err)
+	}
+
+	methodID := int64(1)
+	for _, text := range []string{"Entity 1", "Entity 2"} {
+		if err := modelEntityRepo.Create(context.Background(), models.NewModelEntity(settings.ID, methodID, text)); err != nil {
+			t.Fatalf("Failed to create entity: %v", err)
+		}
+	}
+
+	// Stream the entities
+	var streamed []string
+	err = service.StreamModelEntities(context.Background(), userID, methodID, func(entity *models.ModelEntityWithMethod) error {
+		streamed = append(streamed, entity.EntityText)
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("StreamModelEntities() error = %v", err)
+	}
+	if len(streamed) != 2 {
+		t.Errorf("Expected 2 entities, got %d", len(streamed))
+	}
+
+	// Callback errors stop the stream and are returned
+	stopErr := errors.New("client went away")
+	calls := 0
+	err = service.StreamModelEntities(context.Background(), userID, methodID, func(entity *models.ModelEntityWithMethod) error {
+		calls++
+		return stopErr
+	})
+	if !errors.Is(err, stopErr) || calls != 1 {
+		t.Errorf("Expected the stream to stop after the first entity, got %d calls and error %v", calls, err)
+	}
+}
+
 func TestSettingsService_AddModelEntities(t *testing.T) {
 	// Setup
 	settingsRepo := NewMockSettingsRepository()
@@ -1474,7 +2072,7 @@ func TestSettingsService_AddModelEntities(t *testing.T) {
 	userID := int64(123)
 	settingsRepo.RegisterValidUserID(userID)
 
-	service := NewSettingsService(settingsRepo, banListRepo, patternRepo, modelEntityRepo)
+	service := NewSettingsService(settingsRepo, banListRepo, patternRepo, modelEntityRepo, NewMockSettingsRevisionRepository())
 
 	// Get settings for a user
 	_, err := service.GetUserSettings(context.Background(), userID)
@@ -1563,7 +2161,7 @@ func TestSettingsService_DeleteModelEntity(t *testing.T) {
 	settingsRepo.RegisterValidUserID(userID)
 	settingsRepo.RegisterValidUserID(newUserID)
 
-	service := NewSettingsService(settingsRepo, banListRepo, patternRepo, modelEntityRepo)
+	service := NewSettingsService(settingsRepo, banListRepo, patternRepo, modelEntityRepo, NewMockSettingsRevisionRepository())
 
 	// Get settings for a user
 	settings, err := service.GetUserSettings(context.Background(), userID)
@@ -1627,6 +2225,78 @@ func TestSettingsService_DeleteModelEntity(t *testing.T) {
 	}
 }
 
+func TestSettingsService_ReplaceModelEntities(t *testing.T) {
+	// Setup
+	modelEntityRepo := NewMockModelEntityRepository()
+	revisionRepo := NewMockSettingsRevisionRepository()
+	settingsRepo := NewMockSettingsRepository()
+
+	userID := int64(123)
+	settingsRepo.RegisterValidUserID(userID)
+
+	service := NewSettingsService(settingsRepo, NewMockBanListRepository(), NewMockPatternRepository(), modelEntityRepo, revisionRepo)
+
+	settings, err := service.GetUserSettings(context.Background(), userID)
+	if err != nil {
+		t.Fatalf("Failed to get user settings: %v", err)
+	}
+
+	// Entities of the method being replaced and of another method
+	methodID := int64(1)
+	for _, text := range []string{"Phone Number", "Email Address"} {
+		if err := modelEntityRepo.Create(context.Background(), models.NewModelEntity(settings.ID, methodID, text)); err != nil {
+			t.Fatalf("Failed to create entity: %v", err)
+		}
+	}
+	if err := modelEntityRepo.Create(context.Background(), models.NewModelEntity(settings.ID, int64(2), "Phone Number")); err != nil {
+		t.Fatalf("Failed to create entity: %v", err)
+	}
+
+	// Replace the entities of the method
+	replace := &models.ModelEntityReplace{EntityTexts: []string{"Email Address", "IP Address"}}
+	replacement, err := service.ReplaceModelEntities(context.Background(), userID, methodID, replace)
+	if err != nil {
+		t.Fatalf("ReplaceModelEntities() error = %v", err)
+	}
+
+	if len(replacement.Created) != 1 || replacement.Created[0].EntityText != "IP Address" {
+		t.Errorf("Expected IP Address to be created, got %v", replacement.Created)
+	}
+	if len(replacement.Deleted) != 1 || replacement.Deleted[0].EntityText != "Phone Number" {
+		t.Errorf("Expected Phone Number to be deleted, got %v", replacement.Deleted)
+	}
+
+	// Verify the stored entities of both methods
+	storedEntities, err := service.GetModelEntities(context.Background(), userID, methodID)
+	if err != nil {
+		t.Fatalf("Failed to get stored entities: %v", err)
+	}
+	if len(storedEntities) != 2 {
+		t.Errorf("Expected 2 stored entities, got %d", len(storedEntities))
+	}
+	otherEntities, err := service.GetModelEntities(context.Background(), userID, int64(2))
+	if err != nil {
+		t.Fatalf("Failed to get stored entities: %v", err)
+	}
+	if len(otherEntities) != 1 {
+		t.Errorf("Expected the other method to keep 1 entity, got %d", len(otherEntities))
+	}
+
+	// Only the difference is recorded as revisions
+	if len(revisionRepo.revisions) != 2 {
+		t.Fatalf("Expected 2 revisions, got %d", len(revisionRepo.revisions))
+	}
+	if revisionRepo.revisions[0].Action != models.RevisionDeleted || revisionRepo.revisions[1].Action != models.RevisionCreated {
+		t.Errorf("Expected a deletion and a creation, got %s and %s", revisionRepo.revisions[0].Action, revisionRepo.revisions[1].Action)
+	}
+
+	// Test error case - user not found
+	_, err = service.ReplaceModelEntities(context.Background(), int64(999), methodID, replace)
+	if err == nil {
+		t.Error("Expected error for non-existent user, got nil")
+	}
+}
+
 // Helper functions for creating pointers to primitives
 func boolPtr(b bool) *bool {
 	return &b
@@ -1636,6 +2306,139 @@ func stringPtr(s string) *string {
 	return &s
 }
 
+func int64Ptr(i int64) *int64 {
+	return &i
+}
+
 func float64Ptr(f float64) *float64 {
 	return &f
 }
+
+func TestSettingsService_GetDetectionConfig(t *testing.T) {
+	// Setup
+	settingsRepo := NewMockSettingsRepository()
+	userID := int64(123)
+	settingsRepo.RegisterValidUserID(userID)
+
+	service := NewSettingsService(settingsRepo, NewMockBanListRepository(), NewMockPatternRepository(), NewMockModelEntityRepository(), NewMockSettingsRevisionRepository())
+	service.SetDetectionConfigCache(NewDetectionConfigCache(time.Minute))
+	ctx := context.Background()
+
+	if err := service.AddBanListWords(ctx, userID, []string{"sensitive"}); err != nil {
+		t.Fatalf("AddBanListWords() error = %v", err)
+	}
+
+	config, cached, err := service.GetDetectionConfig(ctx, userID)
+	if err != nil {
+		t.Fatalf("GetDetectionConfig() error = %v", err)
+	}
+	if cached || len(config.BanList.Words) != 1 || config.GeneralSettings == nil {
+		t.Errorf("Expected a freshly composed configuration with one banned word, got cached=%v %+v", cached, config)
+	}
+
+	if _, cached, _ := service.GetDetectionConfig(ctx, userID); !cached {
+		t.Error("Expected the second request to be served from the cache")
+	}
+
+	// A settings change drops the cached configuration
+	if err := service.AddBanListWords(ctx, userID, []string{"confidential"}); err != nil {
+		t.Fatalf("AddBanListWords() error = %v", err)
+	}
+	config, cached, err = service.GetDetectionConfig(ctx, userID)
+	if err != nil {
+		t.Fatalf("GetDetectionConfig() error = %v", err)
+	}
+	if cached || len(config.BanList.Words) != 2 {
+		t.Errorf("Expected the change to be visible at once, got cached=%v and words %v", cached, config.BanList.Words)
+	}
+}
+
+func TestSettingsService_GetSettingsChanges(t *testing.T) {
+	// Setup
+	settingsRepo := NewMockSettingsRepository()
+	banListRepo := NewMockBanListRepository()
+	patternRepo := NewMockPatternRepository()
+	modelEntityRepo := NewMockModelEntityRepository()
+	revisionRepo := NewMockSettingsRevisionRepository()
+
+	// Register valid user ID
+	userID := int64(123)
+	settingsRepo.RegisterValidUserID(userID)
+
+	service := NewSettingsService(settingsRepo, banListRepo, patternRepo, modelEntityRepo, revisionRepo)
+
+	// An empty history returns immediately without waiting
+	feed, err := service.GetSettingsChanges(context.Background(), userID, 0, 10, 0)
+	if err != nil {
+		t.Fatalf("GetSettingsChanges() error = %v", err)
+	}
+	if len(feed.Changes) != 0 {
+		t.Errorf("Expected 0 changes, got %d", len(feed.Changes))
+	}
+	if feed.LatestRevision != 0 {
+		t.Errorf("Expected LatestRevision = 0, got %d", feed.LatestRevision)
+	}
+
+	// Mutations are recorded in the revision history
+	if err := service.AddBanListWords(context.Background(), userID, []string{"alpha", "beta"}); err != nil {
+		t.Fatalf("AddBanListWords() error = %v", err)
+	}
+	if _, err := service.CreateSearchPattern(context.Background(), userID, &models.SearchPatternCreate{
+		PatternType: string(models.Normal),
+		PatternText: "secret",
+	}); err != nil {
+		t.Fatalf("CreateSearchPattern() error = %v", err)
+	}
+
+	feed, err = service.GetSettingsChanges(context.Background(), userID, 0, 10, 0)
+	if err != nil {
+		t.Fatalf("GetSettingsChanges() error = %v", err)
+	}
+	if len(feed.Changes) != 3 {
+		t.Fatalf("Expected 3 changes, got %d", len(feed.Changes))
+	}
+	if feed.Changes[0].ResourceType != models.ResourceBanListWord || feed.Changes[0].Action != models.RevisionCreated {
+		t.Errorf("Unexpected first change: %s %s", feed.Changes[0].ResourceType, feed.Changes[0].Action)
+	}
+	if feed.Changes[2].ResourceType != models.ResourceSearchPattern {
+		t.Errorf("Expected last change to be a search pattern, got %s", feed.Changes[2].ResourceType)
+	}
+	if feed.LatestRevision != 3 || feed.HasMore {
+		t.Errorf("Expected LatestRevision = 3 and HasMore = false, got %d and %v", feed.LatestRevision, feed.HasMore)
+	}
+
+	// Paging reports that more changes are available
+	feed, err = service.GetSettingsChanges(context.Background(), userID, 0, 2, 0)
+	if err != nil {
+		t.Fatalf("GetSettingsChanges() error = %v", err)
+	}
+	if len(feed.Changes) != 2 || !feed.HasMore || feed.LatestRevision != 2 {
+		t.Errorf("Expected 2 changes with more available, got %d (has_more=%v, latest=%d)", len(feed.Changes), feed.HasMore, feed.LatestRevision)
+	}
+
+	// Only newer revisions are returned
+	feed, err = service.GetSettingsChanges(context.Background(), userID, 3, 10, 0)
+	if err != nil {
+		t.Fatalf("GetSettingsChanges() error = %v", err)
+	}
+	if len(feed.Changes) != 0 || feed.LatestRevision != 3 {
+		t.Errorf("Expected no changes after revision 3, got %d", len(feed.Changes))
+	}
+
+	// A cancelled context ends the long-poll early
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	feed, err = service.GetSettingsChanges(ctx, userID, 3, 10, time.Minute)
+	if err != nil {
+		t.Fatalf("GetSettingsChanges() error = %v", err)
+	}
+	if len(feed.Changes) != 0 {
+		t.Errorf("Expected 0 changes after cancellation, got %d", len(feed.Changes))
+	}
+
+	// Test error case - negative revision
+	_, err = service.GetSettingsChanges(context.Background(), userID, -1, 10, 0)
+	if err == nil {
+		t.Error("Expected error for negative revision, got nil")
+	}
+}
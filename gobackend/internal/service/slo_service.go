@@ -0,0 +1,232 @@
+// Package service provides business logic implementations.
+//
+// This file implements the service-level objective (SLO) alerts. The objectives' error
+// budgets are watched with the multiwindow burn-rate rules of the Google SRE workbook on
+// the request metrics kept in memory, so installations without Prometheus and
+// Alertmanager are still alerted when the API starts failing or slowing down.
+package service
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/rs/zerolog/log"
+
+	"github.com/yasinhessnawi1/Hideme_Backend/internal/config"
+	"github.com/yasinhessnawi1/Hideme_Backend/internal/constants"
+	"github.com/yasinhessnawi1/Hideme_Backend/internal/models"
+	"github.com/yasinhessnawi1/Hideme_Backend/internal/repository"
+	"github.com/yasinhessnawi1/Hideme_Backend/internal/utils/clock"
+)
+
+// SLOMetricsSource defines the request metrics the objectives are evaluated on.
+type SLOMetricsSource interface {
+	Window(window time.Duration) models.RequestWindowStats
+}
+
+// sloBurnRule fires when the error budget was spent at least burnRate times faster than
+// sustainable over both its long and its short window. The long window keeps brief
+// spikes from firing it; the short window lets it resolve soon after the burning stops.
+type sloBurnRule struct {
+	severity    string
+	longWindow  time.Duration
+	shortWindow time.Duration
+	burnRate    float64
+}
+
+// sloBurnRules are the rules every objective is watched with: a critical rule catching
+// outages within minutes and a warning rule catching slow, sustained degradation.
+var sloBurnRules = []sloBurnRule{
+	{constants.SLOSeverityCritical, constants.SLOFastBurnLongWindow, constants.SLOFastBurnShortWindow, constants.SLOFastBurnRate},
+	{constants.SLOSeverityWarning, constants.SLOSlowBurnLongWindow, constants.SLOSlowBurnShortWindow, constants.SLOSlowBurnRate},
+}
+
+// SLOService evaluates the burn rates of the availability and latency objectives and
+// alerts the active administrators when a rule starts or stops firing, through the
+// slo_alert notification and the slo.alert webhook event. Evaluate runs periodically in
+// the background; which rules fire is kept in memory, so each server alerts about the
+// requests it answered itself.
+type SLOService struct {
+	metrics  SLOMetricsSource
+	userRepo repository.UserRepository
+	settings *config.SLOSettings
+	notifier UserNotifier
+	webhooks WebhookPublisher
+	clock    clock.Clock
+
+	// mu guards the rules that are firing, by objective and severity
+	mu     sync.Mutex
+	firing map[string]bool
+}
+
+// NewSLOService creates a new SLOService.
+//
+// Parameters:
+//   - metrics: The request metrics the objectives are evaluated on
+//   - userRepo: Repository for finding the administrators to alert
+//   - settings: The objectives and when their rules may fire
+//
+// Returns:
+//   - A configured SLOService
+func NewSLOService(metrics SLOMetricsSource, userRepo repository.UserRepository, settings *config.SLOSettings) *SLOService {
+	return &SLOService{
+		metrics:  metrics,
+		userRepo: userRepo,
+		settings: settings,
+		firing:   make(map[string]bool),
+	}
+}
+
+// SetClock sets the clock error budgets are measured by.
+func (s *SLOService) SetClock(c clock.Clock) {
+	s.clock = c
+}
+
+// now returns the current time of the service's clock.
+func (s *SLOService) now() time.Time {
+	return clock.Or(s.clock).Now()
+}
+
+// SetNotifier enables streaming the alerts to the administrators' clients.
+func (s *SLOService) SetNotifier(notifier UserNotifier) {
+	s.notifier = notifier
+}
+
+// SetWebhooks enables sending the alerts to the administrators' webhooks subscribed to
+// slo.alert events, for paging through chat or incident tools.
+func (s *SLOService) SetWebhooks(webhooks WebhookPublisher) {
+	s.webhooks = webhooks
+}
+
+// Evaluate evaluates the burn-rate rules of the objectives and alerts the administrators
+// about the rules that started or stopped firing since the last evaluation.
+//
+// Parameters:
+//   - ctx: Context for the operation
+//
+// Returns:
+//   - An error if the administrators could not be found; the changes are then alerted
+//     about at the next evaluation
+func (s *SLOService) Evaluate(ctx context.Context) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	now := s.now()
+	var alerts []models.SLOAlert
+	for _, objective := range []string{constants.SLOObjectiveAvailability, constants.SLOObjectiveLatency} {
+		target := s.target(objective)
+		if target <= 0 {
+			continue
+		}
+		for _, rule := range sloBurnRules {
+			alert, firing := s.evaluateRule(objective, target, rule)
+			if firing == s.firing[sloRuleKey(objective, rule.severity)] {
+				continue
+			}
+			alert.Status = constants.SLOAlertResolved
+			if firing {
+				alert.Status = constants.SLOAlertFiring
+			}
+			alert.At = now
+			alerts = append(alerts, alert)
+		}
+	}
+	if len(alerts) == 0 {
+		return nil
+	}
+
+	admins, _, err := s.userRepo.List(ctx, models.UserFilter{Role: constants.RoleAdmin, Status: constants.UserStatusActive}, 1, constants.SLOAlertMaxRecipients)
+	if err != nil {
+		return fmt.Errorf("failed to list administrators for SLO alerts: %w", err)
+	}
+
+	for _, alert := range alerts {
+		s.firing[sloRuleKey(alert.Objective, alert.Severity)] = alert.Status == constants.SLOAlertFiring
+
+		log.Warn().
+			Str("category", constants.LogCategorySLO).
+			Str("objective", alert.Objective).
+			Str("severity", alert.Severity).
+			Str("status", alert.Status).
+			Float64("burn_rate", alert.BurnRate).
+			Int64("requests", alert.Requests).
+			Int("recipients", len(admins)).
+			Msg("SLO burn-rate alert")
+
+		for _, admin := range admins {
+			if s.notifier != nil {
+				s.notifier.Notify(ctx, admin.ID, constants.NotificationSLOAlert, alert)
+			}
+			if s.webhooks != nil {
+				s.webhooks.Publish(ctx, admin.ID, constants.WebhookEventSLOAlert, alert)
+			}
+		}
+	}
+
+	return nil
+}
+
+// target returns the configured target of an objective; zero or less if it is turned off.
+func (s *SLOService) target(objective string) float64 {
+	if objective == constants.SLOObjectiveLatency {
+		return s.settings.LatencyTarget
+	}
+	return s.settings.Availability
+}
+
+// evaluateRule calculates the burn rates of a rule's windows for an objective.
+//
+// Parameters:
+//   - objective: The objective, constants.SLOObjectiveAvailability or constants.SLOObjectiveLatency
+//   - target: The share of good requests the objective promises
+//   - rule: The burn-rate rule
+//
+// Returns:
+//   - The alert describing the rule's windows, without status and time
+//   - Whether the rule fires
+func (s *SLOService) evaluateRule(objective string, target float64, rule sloBurnRule) (models.SLOAlert, bool) {
+	requests, bad := sloCounts(objective, s.metrics.Window(rule.longWindow))
+	shortRequests, shortBad := sloCounts(objective, s.metrics.Window(rule.shortWindow))
+
+	alert := models.SLOAlert{
+		Objective:     objective,
+		Target:        target,
+		Severity:      rule.severity,
+		LongWindow:    rule.longWindow.String(),
+		ShortWindow:   rule.shortWindow.String(),
+		BurnRate:      burnRate(bad, requests, target),
+		ShortBurnRate: burnRate(shortBad, shortRequests, target),
+		Threshold:     rule.burnRate,
+		Requests:      requests,
+		BadRequests:   bad,
+	}
+
+	firing := requests >= s.settings.MinRequests &&
+		alert.BurnRate >= rule.burnRate &&
+		alert.ShortBurnRate >= rule.burnRate
+	return alert, firing
+}
+
+// sloCounts returns the requests an objective applies to and those that missed it.
+func sloCounts(objective string, stats models.RequestWindowStats) (int64, int64) {
+	if objective == constants.SLOObjectiveLatency {
+		return stats.Timed, stats.Slow
+	}
+	return stats.Requests, stats.Errors
+}
+
+// burnRate returns how many times faster than sustainable the error budget of a target
+// was spent: 1 spends exactly the budget over the objective's period.
+func burnRate(bad, requests int64, target float64) float64 {
+	if requests == 0 {
+		return 0
+	}
+	return float64(bad) / float64(requests) / (1 - target)
+}
+
+// sloRuleKey identifies a rule of an objective in the rules that are firing.
+func sloRuleKey(objective, severity string) string {
+	return objective + "/" + severity
+}
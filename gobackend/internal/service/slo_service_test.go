@@ -0,0 +1,109 @@
+package service
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/yasinhessnawi1/Hideme_Backend/internal/config"
+	"github.com/yasinhessnawi1/Hideme_Backend/internal/constants"
+	"github.com/yasinhessnawi1/Hideme_Backend/internal/models"
+)
+
+// stubSLOMetrics returns fixed counts for each window.
+type stubSLOMetrics map[time.Duration]models.RequestWindowStats
+
+func (m stubSLOMetrics) Window(window time.Duration) models.RequestWindowStats {
+	return m[window]
+}
+
+// recordingPublisher records the webhook events published.
+type recordingPublisher struct {
+	users  []int64
+	events []string
+}
+
+func (p *recordingPublisher) Publish(ctx context.Context, userID int64, eventType string, data interface{}) {
+	p.users = append(p.users, userID)
+	p.events = append(p.events, eventType)
+}
+
+func TestSLOService_Evaluate(t *testing.T) {
+	ctx := context.Background()
+	userRepo := NewMockUserRepository()
+	require.NoError(t, userRepo.Create(ctx, &models.User{Username: "admin", Email: "admin@example.com", Role: constants.RoleAdmin}))
+	require.NoError(t, userRepo.Create(ctx, &models.User{Username: "user", Email: "user@example.com", Role: constants.RoleUser}))
+	disabledAt := time.Now()
+	require.NoError(t, userRepo.Create(ctx, &models.User{Username: "former", Email: "former@example.com", Role: constants.RoleAdmin, DisabledAt: &disabledAt}))
+
+	metrics := stubSLOMetrics{}
+	svc := NewSLOService(metrics, userRepo, &config.SLOSettings{
+		Availability:  0.99,
+		LatencyTarget: -1,
+		MinRequests:   50,
+	})
+	notifier := &recordingNotifier{}
+	publisher := &recordingPublisher{}
+	svc.SetNotifier(notifier)
+	svc.SetWebhooks(publisher)
+
+	t.Run("Healthy", func(t *testing.T) {
+		metrics[constants.SLOFastBurnLongWindow] = models.RequestWindowStats{Requests: 1000, Errors: 5}
+		metrics[constants.SLOFastBurnShortWindow] = models.RequestWindowStats{Requests: 100}
+
+		require.NoError(t, svc.Evaluate(ctx))
+		assert.Empty(t, notifier.events)
+	})
+
+	t.Run("Too few requests", func(t *testing.T) {
+		metrics[constants.SLOFastBurnLongWindow] = models.RequestWindowStats{Requests: 10, Errors: 10}
+		metrics[constants.SLOFastBurnShortWindow] = models.RequestWindowStats{Requests: 10, Errors: 10}
+
+		require.NoError(t, svc.Evaluate(ctx))
+		assert.Empty(t, notifier.events)
+	})
+
+	t.Run("Fast burn fires", func(t *testing.T) {
+		// 20% errors burn a 1% budget 20 times faster than sustainable
+		metrics[constants.SLOFastBurnLongWindow] = models.RequestWindowStats{Requests: 1000, Errors: 200, Timed: 1000, Slow: 1000}
+		metrics[constants.SLOFastBurnShortWindow] = models.RequestWindowStats{Requests: 100, Errors: 20}
+
+		require.NoError(t, svc.Evaluate(ctx))
+
+		// Only the active administrator is alerted, and not about the latency objective
+		// that is turned off
+		require.Equal(t, []string{constants.NotificationSLOAlert}, notifier.events)
+		assert.Equal(t, []int64{1}, publisher.users)
+		assert.Equal(t, []string{constants.WebhookEventSLOAlert}, publisher.events)
+		alert := notifier.data[0].(models.SLOAlert)
+		assert.Equal(t, constants.SLOObjectiveAvailability, alert.Objective)
+		assert.Equal(t, constants.SLOSeverityCritical, alert.Severity)
+		assert.Equal(t, constants.SLOAlertFiring, alert.Status)
+		assert.InDelta(t, 20, alert.BurnRate, 0.001)
+		assert.Equal(t, int64(200), alert.BadRequests)
+
+		// A rule that keeps firing is not alerted about again
+		require.NoError(t, svc.Evaluate(ctx))
+		assert.Len(t, notifier.events, 1)
+	})
+
+	t.Run("Short window recovers", func(t *testing.T) {
+		metrics[constants.SLOFastBurnShortWindow] = models.RequestWindowStats{Requests: 100}
+
+		require.NoError(t, svc.Evaluate(ctx))
+
+		require.Len(t, notifier.events, 2)
+		alert := notifier.data[1].(models.SLOAlert)
+		assert.Equal(t, constants.SLOAlertResolved, alert.Status)
+		assert.Equal(t, constants.SLOSeverityCritical, alert.Severity)
+	})
+}
+
+func TestBurnRate(t *testing.T) {
+	assert.Equal(t, 0.0, burnRate(0, 0, 0.99))
+	assert.InDelta(t, 1, burnRate(1, 100, 0.99), 0.001)
+	assert.InDelta(t, 14.4, burnRate(72, 1000, 0.995), 0.001)
+}
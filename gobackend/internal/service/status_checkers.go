@@ -0,0 +1,195 @@
+// Package service provides business logic implementations.
+package service
+
+import (
+	"context"
+	"net/http"
+
+	"github.com/yasinhessnawi1/Hideme_Backend/internal/config"
+	"github.com/yasinhessnawi1/Hideme_Backend/internal/constants"
+	"github.com/yasinhessnawi1/Hideme_Backend/internal/database"
+	"github.com/yasinhessnawi1/Hideme_Backend/internal/models"
+)
+
+// ComponentChecker checks the health of a component shown on the status page.
+type ComponentChecker interface {
+	// Name returns the name of the component shown on the status page.
+	Name() string
+
+	// Check returns the current state of the component.
+	Check(ctx context.Context) models.ComponentState
+}
+
+// apiChecker reports this API server, which is operational whenever it can run a check.
+type apiChecker struct{}
+
+// NewAPIChecker creates a checker for this API server.
+//
+// Returns:
+//   - A ComponentChecker for the API component
+func NewAPIChecker() ComponentChecker {
+	return apiChecker{}
+}
+
+// Name returns the name of the API component.
+func (apiChecker) Name() string {
+	return constants.StatusComponentAPI
+}
+
+// Check returns ComponentOperational, since a running server is able to serve requests.
+func (apiChecker) Check(context.Context) models.ComponentState {
+	return models.ComponentOperational
+}
+
+// DatabasePinger defines methods required to check that the database is reachable.
+type DatabasePinger interface {
+	HealthCheck(ctx context.Context) error
+}
+
+// DatabaseHealthSource defines methods required to observe the recent latency and errors of the database.
+type DatabaseHealthSource interface {
+	Health() database.HealthStats
+}
+
+// databaseChecker checks the database with a health query and the recent query statistics.
+type databaseChecker struct {
+	db       DatabasePinger
+	health   DatabaseHealthSource
+	settings *config.LoadSheddingSettings
+}
+
+// NewDatabaseChecker creates a checker for the database. The database is reported as
+// degraded under the same conditions that make the load shedder reject low-priority requests.
+//
+// Parameters:
+//   - db: The database to ping
+//   - health: The recent query statistics; nil if query monitoring is off
+//   - settings: The latency and error rate thresholds of the load shedder
+//
+// Returns:
+//   - A ComponentChecker for the database component
+func NewDatabaseChecker(db DatabasePinger, health DatabaseHealthSource, settings *config.LoadSheddingSettings) ComponentChecker {
+	return &databaseChecker{
+		db:       db,
+		health:   health,
+		settings: settings,
+	}
+}
+
+// Name returns the name of the database component.
+func (c *databaseChecker) Name() string {
+	return constants.StatusComponentDatabase
+}
+
+// Check pings the database and compares the recent query statistics against the thresholds.
+func (c *databaseChecker) Check(ctx context.Context) models.ComponentState {
+	if err := c.db.HealthCheck(ctx); err != nil {
+		return models.ComponentOutage
+	}
+
+	if c.health == nil {
+		return models.ComponentOperational
+	}
+
+	stats := c.health.Health()
+	if stats.Samples >= c.settings.MinSamples &&
+		(stats.MeanLatency >= c.settings.DegradedLatency || stats.ErrorRate >= c.settings.ErrorRate) {
+		return models.ComponentDegraded
+	}
+
+	return models.ComponentOperational
+}
+
+// RedisCommander defines methods required to run commands on Redis.
+type RedisCommander interface {
+	Do(ctx context.Context, args ...string) (any, error)
+}
+
+// redisChecker checks the Redis store shared with the detection service.
+type redisChecker struct {
+	client RedisCommander
+}
+
+// NewRedisChecker creates a checker for the Redis store shared with the detection service.
+//
+// Parameters:
+//   - client: The Redis client
+//
+// Returns:
+//   - A ComponentChecker for the storage component
+func NewRedisChecker(client RedisCommander) ComponentChecker {
+	return &redisChecker{
+		client: client,
+	}
+}
+
+// Name returns the name of the storage component.
+func (c *redisChecker) Name() string {
+	return constants.StatusComponentStorage
+}
+
+// Check sends a PING to Redis.
+func (c *redisChecker) Check(ctx context.Context) models.ComponentState {
+	reply, err := c.client.Do(ctx, "PING")
+	if err != nil || reply != "PONG" {
+		return models.ComponentOutage
+	}
+	return models.ComponentOperational
+}
+
+// httpChecker checks a service through its HTTP health endpoint.
+type httpChecker struct {
+	name   string
+	url    string
+	client *http.Client
+}
+
+// NewHTTPChecker creates a checker for a service with an HTTP health endpoint.
+// A 2xx response means the service is operational, a 5xx response or no response
+// means an outage, and anything else that it is degraded.
+//
+// Parameters:
+//   - name: The name of the component
+//   - url: The health endpoint of the service
+//   - client: The HTTP client used for the check; http.DefaultClient if nil
+//
+// Returns:
+//   - A ComponentChecker for the component
+func NewHTTPChecker(name, url string, client *http.Client) ComponentChecker {
+	if client == nil {
+		client = http.DefaultClient
+	}
+	return &httpChecker{
+		name:   name,
+		url:    url,
+		client: client,
+	}
+}
+
+// Name returns the name of the component.
+func (c *httpChecker) Name() string {
+	return c.name
+}
+
+// Check requests the health endpoint of the service.
+func (c *httpChecker) Check(ctx context.Context) models.ComponentState {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, c.url, nil)
+	if err != nil {
+		return models.ComponentOutage
+	}
+
+	resp, err := c.client.Do(req)
+	if err != nil {
+		return models.ComponentOutage
+	}
+	defer resp.Body.Close()
+
+	switch {
+	case resp.StatusCode >= 200 && resp.StatusCode < 300:
+		return models.ComponentOperational
+	case resp.StatusCode >= 500:
+		return models.ComponentOutage
+	default:
+		return models.ComponentDegraded
+	}
+}
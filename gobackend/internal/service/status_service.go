@@ -0,0 +1,340 @@
+// Package service provides business logic implementations.
+package service
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/rs/zerolog/log"
+
+	"github.com/yasinhessnawi1/Hideme_Backend/internal/config"
+	"github.com/yasinhessnawi1/Hideme_Backend/internal/constants"
+	"github.com/yasinhessnawi1/Hideme_Backend/internal/models"
+	"github.com/yasinhessnawi1/Hideme_Backend/internal/repository"
+	"github.com/yasinhessnawi1/Hideme_Backend/internal/utils/clock"
+)
+
+// StatusService provides the data behind the public status page. The components are
+// checked by RunChecks, which runs periodically in the background, so public requests
+// never trigger checks themselves; each result is also added to the hourly rollups the
+// uptime percentages are calculated from. The assembled status is cached for
+// StatusPageSettings.CacheTTL, since the page is public and may be polled heavily.
+type StatusService struct {
+	statusRepo repository.StatusRepository
+	settings   *config.StatusPageSettings
+	checkers   []ComponentChecker
+	screener   *PIIScreener
+	clock      clock.Clock
+
+	// stateMutex guards the results of the latest checks
+	stateMutex sync.RWMutex
+	states     map[string]models.ComponentState
+	checkedAt  time.Time
+
+	// cacheMutex guards the cached status; it is held while the status is rebuilt
+	// so that concurrent requests on an expired cache rebuild it only once
+	cacheMutex sync.Mutex
+	cached     *models.PublicStatus
+	cachedAt   time.Time
+}
+
+// NewStatusService creates a new StatusService.
+//
+// Parameters:
+//   - statusRepo: Repository for incidents and uptime rollups
+//   - settings: The check and cache settings of the status page
+//   - checkers: The components shown on the status page, in display order
+//
+// Returns:
+//   - A configured StatusService
+func NewStatusService(statusRepo repository.StatusRepository, settings *config.StatusPageSettings, checkers ...ComponentChecker) *StatusService {
+	return &StatusService{
+		statusRepo: statusRepo,
+		settings:   settings,
+		checkers:   checkers,
+		states:     make(map[string]models.ComponentState),
+	}
+}
+
+// SetClock sets the clock components are checked and uptime is pruned by.
+func (s *StatusService) SetClock(c clock.Clock) {
+	s.clock = c
+}
+
+// now returns the current time of the service's clock.
+func (s *StatusService) now() time.Time {
+	return clock.Or(s.clock).Now()
+}
+
+// SetPIIScreener enables screening of incident titles and messages for personal data.
+// Incidents are shown publicly, so personal data entered there would be published.
+//
+// Parameters:
+//   - screener: The screener checking free-text fields
+func (s *StatusService) SetPIIScreener(screener *PIIScreener) {
+	s.screener = screener
+}
+
+// RunChecks checks all components concurrently and records the results.
+//
+// Parameters:
+//   - ctx: Context for the operation
+//
+// Returns:
+//   - An error if a result could not be recorded; the latest states are updated regardless
+func (s *StatusService) RunChecks(ctx context.Context) error {
+	checkedAt := s.now()
+
+	states := make([]models.ComponentState, len(s.checkers))
+	var wg sync.WaitGroup
+	for i, checker := range s.checkers {
+		wg.Add(1)
+		go func(i int, checker ComponentChecker) {
+			defer wg.Done()
+			checkCtx, cancel := context.WithTimeout(ctx, s.settings.CheckTimeout)
+			defer cancel()
+			states[i] = checker.Check(checkCtx)
+		}(i, checker)
+	}
+	wg.Wait()
+
+	s.stateMutex.Lock()
+	for i, checker := range s.checkers {
+		s.states[checker.Name()] = states[i]
+	}
+	s.checkedAt = checkedAt
+	s.stateMutex.Unlock()
+
+	var firstErr error
+	for i, checker := range s.checkers {
+		if err := s.statusRepo.RecordCheck(ctx, checker.Name(), states[i].Healthy(), checkedAt); err != nil && firstErr == nil {
+			firstErr = fmt.Errorf("failed to record check of %s: %w", checker.Name(), err)
+		}
+	}
+
+	return firstErr
+}
+
+// GetStatus returns the data behind the public status page, from the cache if it is fresh.
+//
+// Parameters:
+//   - ctx: Context for the operation
+//
+// Returns:
+//   - The component states, uptime percentages and unresolved incidents
+//   - An error if the incidents or uptime could not be retrieved
+func (s *StatusService) GetStatus(ctx context.Context) (*models.PublicStatus, error) {
+	s.cacheMutex.Lock()
+	defer s.cacheMutex.Unlock()
+
+	now := s.now()
+	if s.cached != nil && now.Sub(s.cachedAt) < s.settings.CacheTTL {
+		return s.cached, nil
+	}
+
+	// Check the components once if the background checks have not run yet
+	s.stateMutex.RLock()
+	checked := !s.checkedAt.IsZero()
+	s.stateMutex.RUnlock()
+	if !checked {
+		if err := s.RunChecks(ctx); err != nil {
+			log.Warn().Err(err).Msg("Failed to record component checks")
+		}
+	}
+
+	status, err := s.buildStatus(ctx, now)
+	if err != nil {
+		return nil, err
+	}
+
+	s.cached = status
+	s.cachedAt = now
+	return status, nil
+}
+
+// buildStatus assembles the status page from the latest checks, the uptime rollups and the incidents.
+func (s *StatusService) buildStatus(ctx context.Context, now time.Time) (*models.PublicStatus, error) {
+	incidents, err := s.statusRepo.ListActiveIncidents(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	day, err := s.uptimeSince(ctx, now.Add(-24*time.Hour))
+	if err != nil {
+		return nil, err
+	}
+	week, err := s.uptimeSince(ctx, now.AddDate(0, 0, -7))
+	if err != nil {
+		return nil, err
+	}
+	month, err := s.uptimeSince(ctx, now.AddDate(0, 0, -30))
+	if err != nil {
+		return nil, err
+	}
+
+	s.stateMutex.RLock()
+	defer s.stateMutex.RUnlock()
+
+	status := &models.PublicStatus{
+		Components: make([]*models.ComponentStatus, 0, len(s.checkers)),
+		Incidents:  incidents,
+		CheckedAt:  s.checkedAt,
+	}
+
+	states := make([]models.ComponentState, 0, len(s.checkers)+len(incidents))
+	for _, checker := range s.checkers {
+		name := checker.Name()
+		state := s.states[name]
+		states = append(states, state)
+		status.Components = append(status.Components, &models.ComponentStatus{
+			Name:   name,
+			Status: state,
+			Uptime: models.UptimePercentages{
+				Day:   day[name].Percentage(),
+				Week:  week[name].Percentage(),
+				Month: month[name].Percentage(),
+			},
+		})
+	}
+	for _, incident := range incidents {
+		states = append(states, incident.Impact.State())
+	}
+	status.Status = models.WorstState(states...)
+
+	return status, nil
+}
+
+// uptimeSince returns the uptime counts of each component since a point in time, keyed by component.
+func (s *StatusService) uptimeSince(ctx context.Context, since time.Time) (map[string]*models.UptimeCounts, error) {
+	counts, err := s.statusRepo.GetUptime(ctx, since)
+	if err != nil {
+		return nil, err
+	}
+
+	byComponent := make(map[string]*models.UptimeCounts, len(counts))
+	for _, count := range counts {
+		byComponent[count.Component] = count
+	}
+	return byComponent, nil
+}
+
+// invalidateCache makes the next request rebuild the status, so incident changes show up immediately.
+func (s *StatusService) invalidateCache() {
+	s.cacheMutex.Lock()
+	s.cached = nil
+	s.cacheMutex.Unlock()
+}
+
+// ListIncidents returns the most recent incidents, resolved or not, for administrators.
+//
+// Parameters:
+//   - ctx: Context for the operation
+//
+// Returns:
+//   - The incidents, newest first
+//   - An error if retrieval fails
+func (s *StatusService) ListIncidents(ctx context.Context) ([]*models.StatusIncident, error) {
+	return s.statusRepo.ListIncidents(ctx, constants.StatusIncidentListLimit)
+}
+
+// CreateIncident publishes a new incident on the status page.
+//
+// Parameters:
+//   - ctx: Context for the operation
+//   - adminID: The ID of the administrator opening the incident
+//   - req: The title, message, impact and optional status of the incident
+//
+// Returns:
+//   - The created incident
+//   - ValidationError if the title or message contains personal data and screening blocks it
+//   - Other errors if the incident could not be stored
+func (s *StatusService) CreateIncident(ctx context.Context, adminID int64, req *models.StatusIncidentCreate) (*models.StatusIncident, error) {
+	if err := s.screen(ctx, adminID, req.Title, req.Message); err != nil {
+		return nil, err
+	}
+
+	incident := models.NewStatusIncident(adminID, req)
+	if err := s.statusRepo.CreateIncident(ctx, incident); err != nil {
+		return nil, err
+	}
+	s.invalidateCache()
+
+	log.Info().
+		Int64("incident_id", incident.ID).
+		Int64("admin_id", adminID).
+		Str("impact", string(incident.Impact)).
+		Msg("Status incident opened")
+
+	return incident, nil
+}
+
+// UpdateIncident posts an update on an incident, such as a new message or its resolution.
+//
+// Parameters:
+//   - ctx: Context for the operation
+//   - adminID: The ID of the administrator posting the update
+//   - id: The ID of the incident
+//   - req: The fields to change
+//
+// Returns:
+//   - The updated incident
+//   - ValidationError if the title or message contains personal data and screening blocks it
+//   - NotFoundError if the incident doesn't exist
+//   - Other errors if the incident could not be stored
+func (s *StatusService) UpdateIncident(ctx context.Context, adminID int64, id int64, req *models.StatusIncidentUpdate) (*models.StatusIncident, error) {
+	if err := s.screen(ctx, adminID, req.Title, req.Message); err != nil {
+		return nil, err
+	}
+
+	incident, err := s.statusRepo.GetIncidentByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	incident.Apply(req)
+	if err := s.statusRepo.UpdateIncident(ctx, incident); err != nil {
+		return nil, err
+	}
+	s.invalidateCache()
+
+	return incident, nil
+}
+
+// screen checks the publicly shown text of an incident for personal data.
+func (s *StatusService) screen(ctx context.Context, adminID int64, title, message string) error {
+	if s.screener == nil {
+		return nil
+	}
+	return s.screener.Screen(ctx, adminID, map[string]string{"title": title, "message": message})
+}
+
+// DeleteIncident removes an incident from the status page, e.g. one opened by mistake.
+//
+// Parameters:
+//   - ctx: Context for the operation
+//   - id: The ID of the incident
+//
+// Returns:
+//   - NotFoundError if the incident doesn't exist
+//   - Other errors if the incident could not be removed
+func (s *StatusService) DeleteIncident(ctx context.Context, id int64) error {
+	if err := s.statusRepo.DeleteIncident(ctx, id); err != nil {
+		return err
+	}
+	s.invalidateCache()
+	return nil
+}
+
+// PruneUptime removes uptime rollups older than constants.StatusUptimeRetention.
+//
+// Parameters:
+//   - ctx: Context for the operation
+//
+// Returns:
+//   - The number of removed rollups
+//   - An error if removal fails
+func (s *StatusService) PruneUptime(ctx context.Context) (int64, error) {
+	return s.statusRepo.DeleteUptimeBefore(ctx, s.now().Add(-constants.StatusUptimeRetention))
+}
@@ -0,0 +1,369 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/yasinhessnawi1/Hideme_Backend/internal/config"
+	"github.com/yasinhessnawi1/Hideme_Backend/internal/constants"
+	"github.com/yasinhessnawi1/Hideme_Backend/internal/database"
+	"github.com/yasinhessnawi1/Hideme_Backend/internal/models"
+	"github.com/yasinhessnawi1/Hideme_Backend/internal/testsupport/fakeclock"
+	"github.com/yasinhessnawi1/Hideme_Backend/internal/utils"
+)
+
+// MockStatusRepository is an in-memory implementation of repository.StatusRepository
+type MockStatusRepository struct {
+	incidents    map[int64]*models.StatusIncident
+	nextID       int64
+	checks       map[string]*models.UptimeCounts
+	uptimeCalls  int
+	prunedBefore time.Time
+	recordErr    error
+}
+
+func NewMockStatusRepository() *MockStatusRepository {
+	return &MockStatusRepository{
+		incidents: make(map[int64]*models.StatusIncident),
+		checks:    make(map[string]*models.UptimeCounts),
+	}
+}
+
+func (m *MockStatusRepository) CreateIncident(ctx context.Context, incident *models.StatusIncident) error {
+	m.nextID++
+	incident.ID = m.nextID
+	stored := *incident
+	m.incidents[incident.ID] = &stored
+	return nil
+}
+
+func (m *MockStatusRepository) GetIncidentByID(ctx context.Context, id int64) (*models.StatusIncident, error) {
+	incident, ok := m.incidents[id]
+	if !ok {
+		return nil, utils.NewNotFoundError("StatusIncident", id)
+	}
+	copied := *incident
+	return &copied, nil
+}
+
+func (m *MockStatusRepository) ListIncidents(ctx context.Context, limit int) ([]*models.StatusIncident, error) {
+	result := []*models.StatusIncident{}
+	for _, incident := range m.incidents {
+		result = append(result, incident)
+	}
+	return result, nil
+}
+
+func (m *MockStatusRepository) ListActiveIncidents(ctx context.Context) ([]*models.StatusIncident, error) {
+	result := []*models.StatusIncident{}
+	for _, incident := range m.incidents {
+		if incident.ResolvedAt == nil {
+			result = append(result, incident)
+		}
+	}
+	return result, nil
+}
+
+func (m *MockStatusRepository) UpdateIncident(ctx context.Context, incident *models.StatusIncident) error {
+	if _, ok := m.incidents[incident.ID]; !ok {
+		return utils.NewNotFoundError("StatusIncident", incident.ID)
+	}
+	stored := *incident
+	m.incidents[incident.ID] = &stored
+	return nil
+}
+
+func (m *MockStatusRepository) DeleteIncident(ctx context.Context, id int64) error {
+	if _, ok := m.incidents[id]; !ok {
+		return utils.NewNotFoundError("StatusIncident", id)
+	}
+	delete(m.incidents, id)
+	return nil
+}
+
+func (m *MockStatusRepository) RecordCheck(ctx context.Context, component string, healthy bool, checkedAt time.Time) error {
+	if m.recordErr != nil {
+		return m.recordErr
+	}
+	counts, ok := m.checks[component]
+	if !ok {
+		counts = &models.UptimeCounts{Component: component}
+		m.checks[component] = counts
+	}
+	counts.Checks++
+	if healthy {
+		counts.HealthyChecks++
+	}
+	return nil
+}
+
+func (m *MockStatusRepository) GetUptime(ctx context.Context, since time.Time) ([]*models.UptimeCounts, error) {
+	m.uptimeCalls++
+	result := []*models.UptimeCounts{}
+	for _, counts := range m.checks {
+		copied := *counts
+		result = append(result, &copied)
+	}
+	return result, nil
+}
+
+func (m *MockStatusRepository) DeleteUptimeBefore(ctx context.Context, before time.Time) (int64, error) {
+	m.prunedBefore = before
+	return 0, nil
+}
+
+// fakeChecker reports a fixed state for a component
+type fakeChecker struct {
+	name  string
+	state models.ComponentState
+}
+
+func (c *fakeChecker) Name() string { return c.name }
+
+func (c *fakeChecker) Check(ctx context.Context) models.ComponentState { return c.state }
+
+func newTestStatusService(repo *MockStatusRepository, checkers ...ComponentChecker) *StatusService {
+	settings := &config.StatusPageSettings{
+		CheckInterval: time.Minute,
+		CheckTimeout:  time.Second,
+		CacheTTL:      30 * time.Second,
+	}
+	return NewStatusService(repo, settings, checkers...)
+}
+
+func TestStatusService_RunChecks(t *testing.T) {
+	repo := NewMockStatusRepository()
+	dbChecker := &fakeChecker{name: constants.StatusComponentDatabase, state: models.ComponentOutage}
+	svc := newTestStatusService(repo, NewAPIChecker(), dbChecker)
+
+	if err := svc.RunChecks(context.Background()); err != nil {
+		t.Fatalf("RunChecks failed: %v", err)
+	}
+
+	api := repo.checks[constants.StatusComponentAPI]
+	if api == nil || api.Checks != 1 || api.HealthyChecks != 1 {
+		t.Errorf("expected one healthy API check, got %+v", api)
+	}
+	db := repo.checks[constants.StatusComponentDatabase]
+	if db == nil || db.Checks != 1 || db.HealthyChecks != 0 {
+		t.Errorf("expected one failed database check, got %+v", db)
+	}
+
+	// A recording failure is reported, but the latest states are still updated
+	repo.recordErr = errors.New("db down")
+	dbChecker.state = models.ComponentDegraded
+	if err := svc.RunChecks(context.Background()); err == nil {
+		t.Error("expected the recording error to be returned")
+	}
+	if state := svc.states[constants.StatusComponentDatabase]; state != models.ComponentDegraded {
+		t.Errorf("expected latest database state degraded, got %s", state)
+	}
+}
+
+func TestStatusService_GetStatus(t *testing.T) {
+	repo := NewMockStatusRepository()
+	svc := newTestStatusService(repo,
+		NewAPIChecker(),
+		&fakeChecker{name: constants.StatusComponentDatabase, state: models.ComponentDegraded},
+	)
+	now := time.Date(2024, 5, 13, 10, 0, 0, 0, time.UTC)
+	clk := fakeclock.New(now)
+	svc.SetClock(clk)
+
+	// The components are checked on the first request if the background checks have not run yet
+	status, err := svc.GetStatus(context.Background())
+	if err != nil {
+		t.Fatalf("GetStatus failed: %v", err)
+	}
+	if status.Status != models.ComponentDegraded {
+		t.Errorf("expected overall status degraded, got %s", status.Status)
+	}
+	if len(status.Components) != 2 || status.Components[0].Name != constants.StatusComponentAPI {
+		t.Fatalf("expected the API and database components in order, got %+v", status.Components)
+	}
+	if day := status.Components[1].Uptime.Day; day == nil || *day != 100 {
+		t.Errorf("expected 100%% database uptime for degraded checks, got %v", day)
+	}
+	if !status.CheckedAt.Equal(now) {
+		t.Errorf("expected checked at %v, got %v", now, status.CheckedAt)
+	}
+
+	// Requests within the cache TTL don't query the repository
+	calls := repo.uptimeCalls
+	now = clk.Advance(10 * time.Second)
+	if _, err := svc.GetStatus(context.Background()); err != nil {
+		t.Fatalf("GetStatus failed: %v", err)
+	}
+	if repo.uptimeCalls != calls {
+		t.Errorf("expected the cached status to be served")
+	}
+
+	// Opening an incident invalidates the cache and affects the overall status
+	if _, err := svc.CreateIncident(context.Background(), 1, &models.StatusIncidentCreate{
+		Title:   "Database outage",
+		Message: "Investigating",
+		Impact:  models.IncidentImpactCritical,
+	}); err != nil {
+		t.Fatalf("CreateIncident failed: %v", err)
+	}
+	status, err = svc.GetStatus(context.Background())
+	if err != nil {
+		t.Fatalf("GetStatus failed: %v", err)
+	}
+	if status.Status != models.ComponentOutage || len(status.Incidents) != 1 {
+		t.Errorf("expected an outage with one incident, got %s with %d incidents", status.Status, len(status.Incidents))
+	}
+	if repo.uptimeCalls == calls {
+		t.Errorf("expected the status to be rebuilt after the incident was opened")
+	}
+}
+
+func TestStatusService_UpdateIncident(t *testing.T) {
+	repo := NewMockStatusRepository()
+	svc := newTestStatusService(repo, NewAPIChecker())
+
+	incident, err := svc.CreateIncident(context.Background(), 1, &models.StatusIncidentCreate{
+		Title:   "Slow uploads",
+		Message: "Investigating",
+		Impact:  models.IncidentImpactMinor,
+	})
+	if err != nil {
+		t.Fatalf("CreateIncident failed: %v", err)
+	}
+
+	updated, err := svc.UpdateIncident(context.Background(), 1, incident.ID, &models.StatusIncidentUpdate{
+		Message: "Resolved",
+		Status:  models.IncidentResolved,
+	})
+	if err != nil {
+		t.Fatalf("UpdateIncident failed: %v", err)
+	}
+	if updated.ResolvedAt == nil || updated.Title != "Slow uploads" {
+		t.Errorf("expected a resolved incident with its title kept, got %+v", updated)
+	}
+
+	// Resolved incidents are no longer shown publicly
+	status, err := svc.GetStatus(context.Background())
+	if err != nil {
+		t.Fatalf("GetStatus failed: %v", err)
+	}
+	if len(status.Incidents) != 0 {
+		t.Errorf("expected no active incidents, got %d", len(status.Incidents))
+	}
+
+	if _, err := svc.UpdateIncident(context.Background(), 1, 99, &models.StatusIncidentUpdate{}); !errors.Is(err, utils.ErrNotFound) {
+		t.Errorf("expected not found for an unknown incident, got %v", err)
+	}
+}
+
+func TestStatusService_PruneUptime(t *testing.T) {
+	repo := NewMockStatusRepository()
+	svc := newTestStatusService(repo)
+	now := time.Date(2024, 5, 13, 10, 0, 0, 0, time.UTC)
+	svc.SetClock(fakeclock.New(now))
+
+	if _, err := svc.PruneUptime(context.Background()); err != nil {
+		t.Fatalf("PruneUptime failed: %v", err)
+	}
+	if expected := now.Add(-constants.StatusUptimeRetention); !repo.prunedBefore.Equal(expected) {
+		t.Errorf("expected rollups before %v to be pruned, got %v", expected, repo.prunedBefore)
+	}
+}
+
+// fakePinger is a database that fails its health check when err is set
+type fakePinger struct {
+	err error
+}
+
+func (p *fakePinger) HealthCheck(ctx context.Context) error { return p.err }
+
+// fakeHealthSource reports fixed query statistics
+type fakeHealthSource struct {
+	stats database.HealthStats
+}
+
+func (h *fakeHealthSource) Health() database.HealthStats { return h.stats }
+
+func TestDatabaseChecker(t *testing.T) {
+	settings := &config.LoadSheddingSettings{
+		DegradedLatency: 250 * time.Millisecond,
+		ErrorRate:       0.25,
+		MinSamples:      20,
+	}
+
+	tests := []struct {
+		name     string
+		pingErr  error
+		health   DatabaseHealthSource
+		expected models.ComponentState
+	}{
+		{"Unreachable", errors.New("connection refused"), nil, models.ComponentOutage},
+		{"Without statistics", nil, nil, models.ComponentOperational},
+		{"Fast", nil, &fakeHealthSource{database.HealthStats{Samples: 100, MeanLatency: 5 * time.Millisecond}}, models.ComponentOperational},
+		{"Slow", nil, &fakeHealthSource{database.HealthStats{Samples: 100, MeanLatency: time.Second}}, models.ComponentDegraded},
+		{"Failing queries", nil, &fakeHealthSource{database.HealthStats{Samples: 100, Errors: 50, ErrorRate: 0.5}}, models.ComponentDegraded},
+		{"Too few samples", nil, &fakeHealthSource{database.HealthStats{Samples: 3, MeanLatency: time.Second}}, models.ComponentOperational},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			checker := NewDatabaseChecker(&fakePinger{err: tt.pingErr}, tt.health, settings)
+			if state := checker.Check(context.Background()); state != tt.expected {
+				t.Errorf("expected %s, got %s", tt.expected, state)
+			}
+		})
+	}
+}
+
+// fakeRedis replies to every command with a fixed reply
+type fakeRedis struct {
+	reply any
+	err   error
+}
+
+func (r *fakeRedis) Do(ctx context.Context, args ...string) (any, error) { return r.reply, r.err }
+
+func TestRedisChecker(t *testing.T) {
+	if state := NewRedisChecker(&fakeRedis{reply: "PONG"}).Check(context.Background()); state != models.ComponentOperational {
+		t.Errorf("expected operational, got %s", state)
+	}
+	if state := NewRedisChecker(&fakeRedis{err: errors.New("connection refused")}).Check(context.Background()); state != models.ComponentOutage {
+		t.Errorf("expected outage, got %s", state)
+	}
+}
+
+func TestHTTPChecker(t *testing.T) {
+	tests := []struct {
+		name     string
+		status   int
+		expected models.ComponentState
+	}{
+		{"Healthy", http.StatusOK, models.ComponentOperational},
+		{"Rate limited", http.StatusTooManyRequests, models.ComponentDegraded},
+		{"Failing", http.StatusServiceUnavailable, models.ComponentOutage},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				w.WriteHeader(tt.status)
+			}))
+			defer server.Close()
+
+			checker := NewHTTPChecker(constants.StatusComponentDetection, server.URL, server.Client())
+			if state := checker.Check(context.Background()); state != tt.expected {
+				t.Errorf("expected %s, got %s", tt.expected, state)
+			}
+		})
+	}
+
+	// An unreachable service is an outage
+	checker := NewHTTPChecker(constants.StatusComponentDetection, "http://127.0.0.1:0/health", nil)
+	if state := checker.Check(context.Background()); state != models.ComponentOutage {
+		t.Errorf("expected outage, got %s", state)
+	}
+}
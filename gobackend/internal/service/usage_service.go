@@ -0,0 +1,187 @@
+// Package service provides business logic implementations.
+package service
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/rs/zerolog/log"
+
+	"github.com/yasinhessnawi1/Hideme_Backend/internal/models"
+	"github.com/yasinhessnawi1/Hideme_Backend/internal/repository"
+	"github.com/yasinhessnawi1/Hideme_Backend/internal/utils/clock"
+)
+
+// usageKey identifies the pending counters of a tenant for a month.
+type usageKey struct {
+	userID int64
+	month  time.Time
+}
+
+// UsageService tracks tenant usage and maintains the monthly rollups used for billing.
+// High-frequency events such as API calls are counted in memory and persisted
+// by RollupUsage, which runs as a periodic maintenance task.
+type UsageService struct {
+	usageRepo    repository.UsageRepository
+	pending      map[usageKey]*models.UsageCounters
+	pendingMutex sync.Mutex
+	clock        clock.Clock
+}
+
+// NewUsageService creates a new UsageService.
+//
+// Parameters:
+//   - usageRepo: Repository for tenant usage rollups
+//
+// Returns:
+//   - A configured UsageService
+func NewUsageService(usageRepo repository.UsageRepository) *UsageService {
+	return &UsageService{
+		usageRepo: usageRepo,
+		pending:   make(map[usageKey]*models.UsageCounters),
+	}
+}
+
+// SetClock sets the clock usage is assigned to months by.
+func (s *UsageService) SetClock(c clock.Clock) {
+	s.clock = c
+}
+
+// now returns the current time of the service's clock.
+func (s *UsageService) now() time.Time {
+	return clock.Or(s.clock).Now()
+}
+
+// RecordAPICall counts an authenticated API call for a tenant.
+//
+// Parameters:
+//   - userID: The ID of the tenant that made the call
+func (s *UsageService) RecordAPICall(userID int64) {
+	s.pendingMutex.Lock()
+	defer s.pendingMutex.Unlock()
+
+	s.counterFor(userID).APICallCount++
+}
+
+// RecordPagesProcessed counts processed pages for a tenant.
+//
+// Parameters:
+//   - userID: The ID of the tenant whose document was processed
+//   - pages: The number of pages processed
+func (s *UsageService) RecordPagesProcessed(userID int64, pages int) {
+	if pages <= 0 {
+		return
+	}
+
+	s.pendingMutex.Lock()
+	defer s.pendingMutex.Unlock()
+
+	s.counterFor(userID).PageCount += int64(pages)
+}
+
+// counterFor returns the pending counter of a tenant for the current month.
+// The caller must hold pendingMutex.
+func (s *UsageService) counterFor(userID int64) *models.UsageCounters {
+	key := usageKey{userID: userID, month: models.UsageMonthOf(s.now())}
+	counter, ok := s.pending[key]
+	if !ok {
+		counter = &models.UsageCounters{}
+		s.pending[key] = counter
+	}
+	return counter
+}
+
+// RollupUsage persists pending counters and refreshes the document statistics
+// of the current and previous month, so the previous month is final once billed.
+//
+// Parameters:
+//   - ctx: Context for the operation
+//
+// Returns:
+//   - An error if persisting or refreshing fails; counters that could not be
+//     persisted are kept and retried on the next run
+func (s *UsageService) RollupUsage(ctx context.Context) error {
+	// Take the pending counters so recording is not blocked by the database
+	s.pendingMutex.Lock()
+	pending := s.pending
+	s.pending = make(map[usageKey]*models.UsageCounters)
+	s.pendingMutex.Unlock()
+
+	// Group the counters by month
+	byMonth := make(map[time.Time]map[int64]*models.UsageCounters)
+	for key, counter := range pending {
+		if byMonth[key.month] == nil {
+			byMonth[key.month] = make(map[int64]*models.UsageCounters)
+		}
+		byMonth[key.month][key.userID] = counter
+	}
+
+	var firstErr error
+	for month, counters := range byMonth {
+		if err := s.usageRepo.AddCounters(ctx, month, counters); err != nil {
+			s.restoreCounters(month, counters)
+			if firstErr == nil {
+				firstErr = fmt.Errorf("failed to persist usage counters: %w", err)
+			}
+		}
+	}
+
+	currentMonth := models.UsageMonthOf(s.now())
+	for _, month := range []time.Time{currentMonth.AddDate(0, -1, 0), currentMonth} {
+		count, err := s.usageRepo.RefreshDocumentStats(ctx, month)
+		if err != nil {
+			if firstErr == nil {
+				firstErr = fmt.Errorf("failed to refresh document usage: %w", err)
+			}
+			continue
+		}
+
+		log.Debug().
+			Time("month", month).
+			Int64("tenants", count).
+			Msg("Refreshed tenant document usage")
+	}
+
+	return firstErr
+}
+
+// restoreCounters merges counters that could not be persisted back into the pending set.
+//
+// Parameters:
+//   - month: The month the counters belong to
+//   - counters: The counters to restore, keyed by user ID
+func (s *UsageService) restoreCounters(month time.Time, counters map[int64]*models.UsageCounters) {
+	s.pendingMutex.Lock()
+	defer s.pendingMutex.Unlock()
+
+	for userID, counter := range counters {
+		key := usageKey{userID: userID, month: month}
+		existing, ok := s.pending[key]
+		if !ok {
+			s.pending[key] = counter
+			continue
+		}
+		existing.PageCount += counter.PageCount
+		existing.APICallCount += counter.APICallCount
+	}
+}
+
+// GetMonthlyUsage retrieves the usage rollups of all tenants for a month.
+//
+// Parameters:
+//   - ctx: Context for the operation
+//   - month: Any point in time within the requested month
+//
+// Returns:
+//   - The rollups ordered by user ID
+//   - An error if retrieval fails
+func (s *UsageService) GetMonthlyUsage(ctx context.Context, month time.Time) ([]*models.TenantUsage, error) {
+	usage, err := s.usageRepo.GetByMonth(ctx, models.UsageMonthOf(month))
+	if err != nil {
+		return nil, fmt.Errorf("failed to get monthly usage: %w", err)
+	}
+
+	return usage, nil
+}
@@ -0,0 +1,177 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/yasinhessnawi1/Hideme_Backend/internal/models"
+	"github.com/yasinhessnawi1/Hideme_Backend/internal/testsupport/fakeclock"
+)
+
+// MockUsageRepository is an in-memory implementation of repository.UsageRepository
+type MockUsageRepository struct {
+	rollups         map[time.Time]map[int64]*models.TenantUsage
+	refreshedMonths []time.Time
+	addErr          error
+}
+
+func NewMockUsageRepository() *MockUsageRepository {
+	return &MockUsageRepository{
+		rollups: make(map[time.Time]map[int64]*models.TenantUsage),
+	}
+}
+
+func (m *MockUsageRepository) rollup(month time.Time, userID int64) *models.TenantUsage {
+	if m.rollups[month] == nil {
+		m.rollups[month] = make(map[int64]*models.TenantUsage)
+	}
+	usage, ok := m.rollups[month][userID]
+	if !ok {
+		usage = &models.TenantUsage{UserID: userID, UsageMonth: month}
+		m.rollups[month][userID] = usage
+	}
+	return usage
+}
+
+func (m *MockUsageRepository) AddCounters(ctx context.Context, month time.Time, counters map[int64]*models.UsageCounters) error {
+	if m.addErr != nil {
+		return m.addErr
+	}
+
+	for userID, counter := range counters {
+		usage := m.rollup(month, userID)
+		usage.PageCount += counter.PageCount
+		usage.APICallCount += counter.APICallCount
+	}
+
+	return nil
+}
+
+func (m *MockUsageRepository) RebuildCounters(ctx context.Context) (int64, error) {
+	return 0, nil
+}
+
+func (m *MockUsageRepository) RefreshDocumentStats(ctx context.Context, month time.Time) (int64, error) {
+	m.refreshedMonths = append(m.refreshedMonths, month)
+	return int64(len(m.rollups[month])), nil
+}
+
+func (m *MockUsageRepository) GetByMonth(ctx context.Context, month time.Time) ([]*models.TenantUsage, error) {
+	result := []*models.TenantUsage{}
+	for _, usage := range m.rollups[month] {
+		result = append(result, usage)
+	}
+	return result, nil
+}
+
+func TestUsageService_RollupUsage(t *testing.T) {
+	// Setup
+	repo := NewMockUsageRepository()
+	service := NewUsageService(repo)
+	now := time.Date(2024, time.May, 15, 10, 0, 0, 0, time.UTC)
+	service.SetClock(fakeclock.New(now))
+
+	service.RecordAPICall(1)
+	service.RecordAPICall(1)
+	service.RecordAPICall(2)
+	service.RecordPagesProcessed(1, 12)
+	service.RecordPagesProcessed(2, 0) // Ignored
+
+	if err := service.RollupUsage(context.Background()); err != nil {
+		t.Fatalf("RollupUsage() error = %v", err)
+	}
+
+	may := time.Date(2024, time.May, 1, 0, 0, 0, 0, time.UTC)
+	if got := repo.rollups[may][1]; got == nil || got.APICallCount != 2 || got.PageCount != 12 {
+		t.Errorf("Unexpected rollup for user 1: %+v", got)
+	}
+	if got := repo.rollups[may][2]; got == nil || got.APICallCount != 1 || got.PageCount != 0 {
+		t.Errorf("Unexpected rollup for user 2: %+v", got)
+	}
+
+	// Both the previous and the current month are refreshed
+	april := time.Date(2024, time.April, 1, 0, 0, 0, 0, time.UTC)
+	if len(repo.refreshedMonths) != 2 || !repo.refreshedMonths[0].Equal(april) || !repo.refreshedMonths[1].Equal(may) {
+		t.Errorf("Expected April and May to be refreshed, got %v", repo.refreshedMonths)
+	}
+
+	// Counters are cleared after a successful rollup
+	if err := service.RollupUsage(context.Background()); err != nil {
+		t.Fatalf("RollupUsage() error = %v", err)
+	}
+	if got := repo.rollups[may][1]; got.APICallCount != 2 {
+		t.Errorf("Expected counters not to be added twice, got %d", got.APICallCount)
+	}
+}
+
+func TestUsageService_RollupUsage_MonthBoundary(t *testing.T) {
+	// Setup
+	repo := NewMockUsageRepository()
+	service := NewUsageService(repo)
+	now := time.Date(2024, time.May, 31, 23, 59, 0, 0, time.UTC)
+	clk := fakeclock.New(now)
+	service.SetClock(clk)
+
+	// Calls are attributed to the month they happened in
+	service.RecordAPICall(1)
+	now = clk.Advance(2 * time.Minute)
+	service.RecordAPICall(1)
+
+	if err := service.RollupUsage(context.Background()); err != nil {
+		t.Fatalf("RollupUsage() error = %v", err)
+	}
+
+	may := time.Date(2024, time.May, 1, 0, 0, 0, 0, time.UTC)
+	june := time.Date(2024, time.June, 1, 0, 0, 0, 0, time.UTC)
+	if repo.rollups[may][1].APICallCount != 1 || repo.rollups[june][1].APICallCount != 1 {
+		t.Errorf("Expected one call in May and one in June")
+	}
+}
+
+func TestUsageService_RollupUsage_RetriesFailedCounters(t *testing.T) {
+	// Setup
+	repo := NewMockUsageRepository()
+	service := NewUsageService(repo)
+	now := time.Date(2024, time.May, 15, 10, 0, 0, 0, time.UTC)
+	service.SetClock(fakeclock.New(now))
+
+	service.RecordAPICall(1)
+
+	// A failed rollup keeps the counters
+	repo.addErr = errors.New("database error")
+	if err := service.RollupUsage(context.Background()); err == nil {
+		t.Fatal("Expected error from RollupUsage, got nil")
+	}
+
+	// Counters recorded in the meantime are merged with the restored ones
+	service.RecordAPICall(1)
+	repo.addErr = nil
+	if err := service.RollupUsage(context.Background()); err != nil {
+		t.Fatalf("RollupUsage() error = %v", err)
+	}
+
+	may := time.Date(2024, time.May, 1, 0, 0, 0, 0, time.UTC)
+	if got := repo.rollups[may][1].APICallCount; got != 2 {
+		t.Errorf("Expected 2 API calls after retry, got %d", got)
+	}
+}
+
+func TestUsageService_GetMonthlyUsage(t *testing.T) {
+	// Setup
+	repo := NewMockUsageRepository()
+	service := NewUsageService(repo)
+
+	may := time.Date(2024, time.May, 1, 0, 0, 0, 0, time.UTC)
+	repo.rollup(may, 1).DocumentCount = 3
+
+	// Any point in the month selects the month
+	usage, err := service.GetMonthlyUsage(context.Background(), time.Date(2024, time.May, 20, 8, 0, 0, 0, time.UTC))
+	if err != nil {
+		t.Fatalf("GetMonthlyUsage() error = %v", err)
+	}
+	if len(usage) != 1 || usage[0].DocumentCount != 3 {
+		t.Errorf("Unexpected usage: %+v", usage)
+	}
+}
@@ -0,0 +1,431 @@
+// Package service provides business logic implementations.
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"time"
+
+	"github.com/rs/zerolog/log"
+
+	"github.com/yasinhessnawi1/Hideme_Backend/internal/constants"
+	"github.com/yasinhessnawi1/Hideme_Backend/internal/models"
+	"github.com/yasinhessnawi1/Hideme_Backend/internal/repository"
+	"github.com/yasinhessnawi1/Hideme_Backend/internal/utils"
+	"github.com/yasinhessnawi1/Hideme_Backend/internal/utils/clock"
+)
+
+var (
+	// ErrUserDataExportActive is returned when a user requests a data export while another one is unfinished
+	ErrUserDataExportActive = utils.New(utils.ErrDuplicate, constants.StatusConflict, constants.MsgUserDataExportActive)
+
+	// ErrUserDataExportNotReady is returned when a data export is downloaded before it completed
+	ErrUserDataExportNotReady = utils.New(utils.ErrBadRequest, constants.StatusConflict, constants.MsgUserDataExportNotReady)
+)
+
+// userDataSettingsSource exports the settings of a user.
+type userDataSettingsSource interface {
+	ExportSettings(ctx context.Context, userID int64) (*models.SettingsExport, error)
+}
+
+// userDataDocumentSource passes the decrypted documents of a user on one at a time, or
+// returns them in batches for export jobs.
+type userDataDocumentSource interface {
+	StreamDocuments(ctx context.Context, userID int64, language string, fn func(*models.Document) error) error
+	ListDocumentsAfter(ctx context.Context, userID, afterID int64, limit int) ([]*models.Document, error)
+}
+
+// userDataEntitySource provides the detected entities of a document.
+type userDataEntitySource interface {
+	GetDetectedEntities(ctx context.Context, documentID int64, filter models.DetectedEntityFilter) ([]*models.DetectedEntityWithMethod, error)
+}
+
+// UserDataExportService assembles everything kept about a user into one download, so users
+// can exercise their right of access (GDPR Article 15): the profile, the settings with ban
+// list, search patterns and model entities, the metadata of all documents and their
+// detected entities.
+//
+// Documents and entities are written as they are read, so the export never holds a user's
+// documents in memory. Only their IDs are kept, to read the entities once the document
+// scan is finished and its connection released.
+//
+// Exports too large to stream in one request run as background jobs. A job exports the
+// documents in batches by ascending ID and stores each batch with its checkpoint, so a job
+// stopped by a deploy resumes after its last batch instead of starting over.
+type UserDataExportService struct {
+	userRepo  repository.UserRepository
+	settings  userDataSettingsSource
+	documents userDataDocumentSource
+	entities  userDataEntitySource
+	jobs      repository.UserDataExportRepository
+	clock     clock.Clock
+}
+
+// NewUserDataExportService creates a new UserDataExportService.
+//
+// Parameters:
+//   - userRepo: Repository for user profiles
+//   - settings: The source of the settings export, usually the SettingsService
+//   - documents: The source of the decrypted documents, usually the DocumentService
+//   - entities: The source of the detected entities, usually the DocumentRepository
+//
+// Returns:
+//   - A configured UserDataExportService
+func NewUserDataExportService(userRepo repository.UserRepository, settings userDataSettingsSource, documents userDataDocumentSource, entities userDataEntitySource) *UserDataExportService {
+	return &UserDataExportService{
+		userRepo:  userRepo,
+		settings:  settings,
+		documents: documents,
+		entities:  entities,
+	}
+}
+
+// SetClock sets the clock exports are leased and pruned by.
+func (s *UserDataExportService) SetClock(c clock.Clock) {
+	s.clock = c
+}
+
+// now returns the current time of the service's clock.
+func (s *UserDataExportService) now() time.Time {
+	return clock.Or(s.clock).Now()
+}
+
+// SetJobs enables exports running as background jobs. Without it, exports can only be streamed.
+func (s *UserDataExportService) SetJobs(jobs repository.UserDataExportRepository) {
+	s.jobs = jobs
+}
+
+// ExportUserData writes the export of all data kept about a user.
+//
+// Parameters:
+//   - ctx: Context for the operation
+//   - userID: The ID of the user
+//   - format: constants.DocumentExportFormatJSON or constants.DocumentExportFormatZIP
+//   - open: Called once the profile and settings were read, before anything is written;
+//     it returns the writer receiving the export, e.g. after sending the response headers
+//
+// Returns:
+//   - An error if the profile or settings could not be read, in which case open was not
+//     called, or if writing the export failed part way
+func (s *UserDataExportService) ExportUserData(ctx context.Context, userID int64, format string, open func() io.Writer) error {
+	user, err := s.userRepo.GetByID(ctx, userID)
+	if err != nil {
+		return err
+	}
+
+	settings, err := s.settings.ExportSettings(ctx, userID)
+	if err != nil {
+		return err
+	}
+
+	writer, err := models.NewUserDataExportWriter(open(), format, user, settings, s.now())
+	if err != nil {
+		return err
+	}
+
+	// Archived documents have their entities in cold storage; they are exported once restored
+	var documentIDs []int64
+	documentCount := 0
+	err = s.documents.StreamDocuments(ctx, userID, "", func(doc *models.Document) error {
+		documentCount++
+		if doc.ArchivedAt == nil {
+			documentIDs = append(documentIDs, doc.ID)
+		}
+		return writer.WriteDocument(models.NewUserDataExportDocument(doc))
+	})
+	if err != nil {
+		return err
+	}
+
+	entityCount := 0
+	for _, documentID := range documentIDs {
+		entities, err := s.entities.GetDetectedEntities(ctx, documentID, models.DetectedEntityFilter{})
+		if err != nil {
+			return err
+		}
+		for _, entity := range entities {
+			if err := writer.WriteEntity(entity); err != nil {
+				return err
+			}
+		}
+		entityCount += len(entities)
+	}
+
+	if err := writer.Close(); err != nil {
+		return err
+	}
+
+	log.Info().
+		Int64("user_id", userID).
+		Str("format", format).
+		Int("documents", documentCount).
+		Int("entities", entityCount).
+		Msg("User data exported")
+
+	return nil
+}
+
+// CreateJob requests a background export of all data kept about a user.
+//
+// Parameters:
+//   - ctx: Context for the operation
+//   - userID: The ID of the user
+//   - format: constants.DocumentExportFormatJSON or constants.DocumentExportFormatZIP
+//
+// Returns:
+//   - The pending job
+//   - ErrUserDataExportActive if the user already has an unfinished job
+func (s *UserDataExportService) CreateJob(ctx context.Context, userID int64, format string) (*models.UserDataExportJob, error) {
+	job := &models.UserDataExportJob{UserID: userID, Format: format}
+	if err := s.jobs.Create(ctx, job); err != nil {
+		if errors.Is(err, utils.ErrDuplicate) {
+			return nil, ErrUserDataExportActive
+		}
+		return nil, err
+	}
+
+	log.Info().Int64("user_id", userID).Int64("export_id", job.ID).Str("format", format).Msg("User data export requested")
+	return job, nil
+}
+
+// GetJob retrieves an export job of a user with its checkpoint.
+//
+// Parameters:
+//   - ctx: Context for the operation
+//   - userID: The ID of the user
+//   - id: The ID of the job
+//
+// Returns:
+//   - The job
+//   - NotFoundError if the user has no such job
+func (s *UserDataExportService) GetJob(ctx context.Context, userID, id int64) (*models.UserDataExportJob, error) {
+	return s.jobs.GetByID(ctx, userID, id)
+}
+
+// ListJobs retrieves the export jobs of a user, newest first.
+//
+// Parameters:
+//   - ctx: Context for the operation
+//   - userID: The ID of the user
+//
+// Returns:
+//   - The jobs
+//   - An error if retrieval fails
+func (s *UserDataExportService) ListJobs(ctx context.Context, userID int64) ([]*models.UserDataExportJob, error) {
+	return s.jobs.ListByUser(ctx, userID)
+}
+
+// DownloadJob writes the file of a completed export job.
+//
+// Parameters:
+//   - ctx: Context for the operation
+//   - userID: The ID of the user
+//   - id: The ID of the job
+//   - open: Called once the job was found completed, before anything is written; it returns
+//     the writer receiving the export
+//
+// Returns:
+//   - NotFoundError if the user has no such job, ErrUserDataExportNotReady if it has not
+//     completed; open was not called then
+//   - Other errors if writing the export failed part way
+func (s *UserDataExportService) DownloadJob(ctx context.Context, userID, id int64, open func(*models.UserDataExportJob) io.Writer) error {
+	job, err := s.jobs.GetByID(ctx, userID, id)
+	if err != nil {
+		return err
+	}
+	if job.Status != constants.UserDataExportCompleted {
+		return ErrUserDataExportNotReady
+	}
+
+	var header models.UserDataExportHeader
+	err = s.jobs.StreamChunks(ctx, userID, id, models.UserDataExportSectionHeader, func(chunk *models.UserDataExportChunk) error {
+		return json.Unmarshal(chunk.Data, &header)
+	})
+	if err != nil {
+		return fmt.Errorf("failed to read export header: %w", err)
+	}
+
+	// The export time is when the job was requested; its data is as of the checkpoints
+	writer, err := models.NewUserDataExportWriter(open(job), job.Format, header.User, header.Settings, job.CreatedAt)
+	if err != nil {
+		return err
+	}
+	for _, section := range []string{models.UserDataExportSectionDocuments, models.UserDataExportSectionEntities} {
+		if err := s.jobs.StreamChunks(ctx, userID, id, section, writer.WriteChunk); err != nil {
+			return err
+		}
+	}
+	return writer.Close()
+}
+
+// RunJobs runs waiting export jobs until ctx is canceled, polling every
+// constants.UserDataExportPollInterval. A job running when ctx is canceled finishes its
+// current batch, stores its checkpoint and is set interrupted, so the next server resumes it.
+//
+// Parameters:
+//   - ctx: Canceled when the server shuts down
+func (s *UserDataExportService) RunJobs(ctx context.Context) {
+	ticker := time.NewTicker(constants.UserDataExportPollInterval)
+	defer ticker.Stop()
+
+	for {
+		// Run jobs until none is waiting
+		for ctx.Err() == nil {
+			job, err := s.jobs.Claim(ctx, s.now(), s.now().Add(constants.UserDataExportLease))
+			if err != nil {
+				if ctx.Err() == nil {
+					log.Error().Err(err).Msg("Failed to claim user data export")
+				}
+				break
+			}
+			if job == nil {
+				break
+			}
+			s.runJob(ctx, job)
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// runJob exports the data of a claimed job from its checkpoint on and records the outcome.
+func (s *UserDataExportService) runJob(ctx context.Context, job *models.UserDataExportJob) {
+	logger := log.With().Int64("user_id", job.UserID).Int64("export_id", job.ID).Logger()
+	if job.Checkpoint.Chunks > 0 {
+		logger.Info().
+			Int64("last_document_id", job.Checkpoint.LastDocumentID).
+			Int64("last_entity_id", job.Checkpoint.LastEntityID).
+			Msg("Resuming user data export from checkpoint")
+	}
+
+	// Batches are not cut short by a shutdown; ctx is checked between them
+	batchCtx := context.WithoutCancel(ctx)
+	status, lastError := constants.UserDataExportCompleted, ""
+	for {
+		if ctx.Err() != nil {
+			status = constants.UserDataExportInterrupted
+			break
+		}
+		done, err := s.exportBatch(batchCtx, job)
+		if err != nil {
+			if errors.Is(err, utils.ErrNotFound) {
+				// Another server took the job over or it was deleted; leave it to them
+				logger.Warn().Err(err).Msg("User data export no longer held by this server")
+				return
+			}
+			logger.Error().Err(err).Msg("User data export failed")
+			status, lastError = constants.UserDataExportFailed, err.Error()
+			break
+		}
+		if done {
+			break
+		}
+	}
+
+	if err := s.jobs.Finish(batchCtx, job.ID, status, lastError); err != nil {
+		logger.Error().Err(err).Str("status", status).Msg("Failed to record user data export outcome")
+		return
+	}
+	logger.Info().
+		Str("status", status).
+		Int64("documents", job.Checkpoint.DocumentsExported).
+		Int64("entities", job.Checkpoint.EntitiesExported).
+		Msg("User data export run ended")
+}
+
+// exportBatch stores the next part of a job's export with its checkpoint: the profile and
+// settings first, then the next constants.UserDataExportBatchSize documents with their
+// detected entities.
+//
+// Returns:
+//   - Whether every document was exported
+//   - An error if the batch could not be read or stored; the checkpoint is unchanged then
+func (s *UserDataExportService) exportBatch(ctx context.Context, job *models.UserDataExportJob) (bool, error) {
+	checkpoint := job.Checkpoint
+	leaseUntil := s.now().Add(constants.UserDataExportLease)
+
+	if checkpoint.Chunks == 0 {
+		user, err := s.userRepo.GetByID(ctx, job.UserID)
+		if err != nil {
+			return false, err
+		}
+		settings, err := s.settings.ExportSettings(ctx, job.UserID)
+		if err != nil {
+			return false, err
+		}
+		chunk, err := models.NewUserDataExportChunk(models.UserDataExportSectionHeader, &models.UserDataExportHeader{User: user, Settings: settings})
+		if err != nil {
+			return false, err
+		}
+		checkpoint.Chunks++
+		return false, s.jobs.Checkpoint(ctx, job, checkpoint, []*models.UserDataExportChunk{chunk}, leaseUntil)
+	}
+
+	docs, err := s.documents.ListDocumentsAfter(ctx, job.UserID, checkpoint.LastDocumentID, constants.UserDataExportBatchSize)
+	if err != nil {
+		return false, err
+	}
+	if len(docs) == 0 {
+		return true, nil
+	}
+
+	exported := make([]*models.UserDataExportDocument, 0, len(docs))
+	var entities []*models.DetectedEntityWithMethod
+	for _, doc := range docs {
+		exported = append(exported, models.NewUserDataExportDocument(doc))
+		checkpoint.LastDocumentID = doc.ID
+
+		// Archived documents have their entities in cold storage; they are exported once restored
+		if doc.ArchivedAt != nil {
+			continue
+		}
+		docEntities, err := s.entities.GetDetectedEntities(ctx, doc.ID, models.DetectedEntityFilter{})
+		if err != nil {
+			return false, err
+		}
+		for _, entity := range docEntities {
+			if entity.ID > checkpoint.LastEntityID {
+				checkpoint.LastEntityID = entity.ID
+			}
+		}
+		entities = append(entities, docEntities...)
+	}
+	checkpoint.DocumentsExported += int64(len(exported))
+	checkpoint.EntitiesExported += int64(len(entities))
+
+	chunk, err := models.NewUserDataExportChunk(models.UserDataExportSectionDocuments, exported)
+	if err != nil {
+		return false, err
+	}
+	chunks := []*models.UserDataExportChunk{chunk}
+	if len(entities) > 0 {
+		chunk, err := models.NewUserDataExportChunk(models.UserDataExportSectionEntities, entities)
+		if err != nil {
+			return false, err
+		}
+		chunks = append(chunks, chunk)
+	}
+	checkpoint.Chunks += len(chunks)
+
+	return false, s.jobs.Checkpoint(ctx, job, checkpoint, chunks, leaseUntil)
+}
+
+// DeleteExpiredJobs removes export jobs, with their files, finished longer than
+// constants.UserDataExportRetention ago.
+//
+// Parameters:
+//   - ctx: Context for the operation
+//
+// Returns:
+//   - The number of removed jobs
+//   - An error if removal fails
+func (s *UserDataExportService) DeleteExpiredJobs(ctx context.Context) (int64, error) {
+	return s.jobs.DeleteFinishedBefore(ctx, s.now().Add(-constants.UserDataExportRetention))
+}
@@ -0,0 +1,301 @@
+package service
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/yasinhessnawi1/Hideme_Backend/internal/constants"
+	"github.com/yasinhessnawi1/Hideme_Backend/internal/models"
+	"github.com/yasinhessnawi1/Hideme_Backend/internal/repository"
+	"github.com/yasinhessnawi1/Hideme_Backend/internal/utils"
+)
+
+// stubUserDataSources provides the settings, documents and entities of a user data export
+type stubUserDataSources struct {
+	docs        []*models.Document
+	entities    map[int64][]*models.DetectedEntityWithMethod
+	entityCalls []int64
+	settingsErr error
+
+	// onEntities is called whenever the entities of a document are read
+	onEntities func(documentID int64)
+}
+
+func (s *stubUserDataSources) ExportSettings(ctx context.Context, userID int64) (*models.SettingsExport, error) {
+	if s.settingsErr != nil {
+		return nil, s.settingsErr
+	}
+	return &models.SettingsExport{Version: models.SettingsExportVersion, UserID: userID}, nil
+}
+
+func (s *stubUserDataSources) StreamDocuments(ctx context.Context, userID int64, language string, fn func(*models.Document) error) error {
+	for _, doc := range s.docs {
+		if err := fn(doc); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (s *stubUserDataSources) ListDocumentsAfter(ctx context.Context, userID, afterID int64, limit int) ([]*models.Document, error) {
+	var docs []*models.Document
+	for _, doc := range s.docs {
+		if doc.ID > afterID && len(docs) < limit {
+			docs = append(docs, doc)
+		}
+	}
+	return docs, nil
+}
+
+func (s *stubUserDataSources) GetDetectedEntities(ctx context.Context, documentID int64, filter models.DetectedEntityFilter) ([]*models.DetectedEntityWithMethod, error) {
+	s.entityCalls = append(s.entityCalls, documentID)
+	if s.onEntities != nil {
+		s.onEntities(documentID)
+	}
+	return s.entities[documentID], nil
+}
+
+// memoryUserDataExportRepository keeps export jobs and their chunks in memory
+type memoryUserDataExportRepository struct {
+	repository.UserDataExportRepository
+	jobs   []*models.UserDataExportJob
+	chunks map[int64][]*models.UserDataExportChunk
+}
+
+func (m *memoryUserDataExportRepository) Create(ctx context.Context, job *models.UserDataExportJob) error {
+	for _, existing := range m.jobs {
+		if existing.UserID == job.UserID && existing.Status != constants.UserDataExportCompleted && existing.Status != constants.UserDataExportFailed {
+			return utils.NewDuplicateError("UserDataExport", "status", existing.Status)
+		}
+	}
+	job.ID = int64(len(m.jobs) + 1)
+	job.Status = constants.UserDataExportPending
+	stored := *job
+	m.jobs = append(m.jobs, &stored)
+	return nil
+}
+
+func (m *memoryUserDataExportRepository) GetByID(ctx context.Context, userID, id int64) (*models.UserDataExportJob, error) {
+	for _, job := range m.jobs {
+		if job.ID == id && job.UserID == userID {
+			stored := *job
+			return &stored, nil
+		}
+	}
+	return nil, utils.NewNotFoundError("UserDataExport", id)
+}
+
+func (m *memoryUserDataExportRepository) Claim(ctx context.Context, now, leaseUntil time.Time) (*models.UserDataExportJob, error) {
+	for _, job := range m.jobs {
+		if job.Status == constants.UserDataExportPending || job.Status == constants.UserDataExportInterrupted {
+			if job.Status == constants.UserDataExportInterrupted {
+				job.Resumes++
+			}
+			job.Status = constants.UserDataExportRunning
+			claimed := *job
+			return &claimed, nil
+		}
+	}
+	return nil, nil
+}
+
+func (m *memoryUserDataExportRepository) Checkpoint(ctx context.Context, job *models.UserDataExportJob, checkpoint models.UserDataExportCheckpoint, chunks []*models.UserDataExportChunk, leaseUntil time.Time) error {
+	stored, err := m.GetByID(ctx, job.UserID, job.ID)
+	if err != nil {
+		return err
+	}
+	if stored.Checkpoint.Chunks != job.Checkpoint.Chunks {
+		return utils.NewNotFoundError("UserDataExport", job.ID)
+	}
+	m.chunks[job.ID] = append(m.chunks[job.ID], chunks...)
+	m.jobs[job.ID-1].Checkpoint = checkpoint
+	job.Checkpoint = checkpoint
+	return nil
+}
+
+func (m *memoryUserDataExportRepository) Finish(ctx context.Context, id int64, status, lastError string) error {
+	m.jobs[id-1].Status = status
+	m.jobs[id-1].LastError = lastError
+	return nil
+}
+
+func (m *memoryUserDataExportRepository) StreamChunks(ctx context.Context, userID, id int64, section string, fn func(*models.UserDataExportChunk) error) error {
+	for _, chunk := range m.chunks[id] {
+		if chunk.Section != section {
+			continue
+		}
+		if err := fn(chunk); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func TestUserDataExportService_ExportUserData(t *testing.T) {
+	userRepo := NewMockUserRepository()
+	if err := userRepo.Create(context.Background(), &models.User{Username: "ola", Email: "ola@example.com"}); err != nil {
+		t.Fatalf("Create() error = %v", err)
+	}
+	archivedAt := time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+
+	t.Run("Exports all sections", func(t *testing.T) {
+		sources := &stubUserDataSources{
+			docs: []*models.Document{
+				{ID: 4, UserID: 1, HashedDocumentName: "contract.pdf"},
+				{ID: 5, UserID: 1, HashedDocumentName: "old.pdf", ArchivedAt: &archivedAt},
+			},
+			entities: map[int64][]*models.DetectedEntityWithMethod{
+				4: {{DetectedEntity: models.DetectedEntity{ID: 9, DocumentID: 4, EntityName: "Ola Nordmann"}}},
+			},
+		}
+		service := NewUserDataExportService(userRepo, sources, sources, sources)
+
+		var buf bytes.Buffer
+		err := service.ExportUserData(context.Background(), 1, constants.DocumentExportFormatJSON, func() io.Writer { return &buf })
+		if err != nil {
+			t.Fatalf("ExportUserData() error = %v", err)
+		}
+
+		var export models.UserDataExport
+		if err := json.Unmarshal(buf.Bytes(), &export); err != nil {
+			t.Fatalf("export is not valid JSON: %v", err)
+		}
+		if export.User == nil || export.User.Username != "ola" {
+			t.Errorf("User = %+v, want ola", export.User)
+		}
+		if export.Settings == nil || export.Settings.UserID != 1 {
+			t.Errorf("Settings = %+v, want the settings of user 1", export.Settings)
+		}
+		if len(export.Documents) != 2 {
+			t.Errorf("Documents = %d, want 2", len(export.Documents))
+		}
+		if len(export.DetectedEntities) != 1 || export.DetectedEntities[0].EntityName != "Ola Nordmann" {
+			t.Errorf("DetectedEntities = %+v, want the entity of document 4", export.DetectedEntities)
+		}
+		// The entities of archived documents are in cold storage
+		if len(sources.entityCalls) != 1 || sources.entityCalls[0] != 4 {
+			t.Errorf("entities read for documents %v, want [4]", sources.entityCalls)
+		}
+	})
+
+	t.Run("Errors before writing", func(t *testing.T) {
+		sources := &stubUserDataSources{settingsErr: errors.New("database error")}
+		service := NewUserDataExportService(userRepo, sources, sources, sources)
+
+		opened := false
+		open := func() io.Writer {
+			opened = true
+			return io.Discard
+		}
+		if err := service.ExportUserData(context.Background(), 1, constants.DocumentExportFormatJSON, open); err == nil {
+			t.Error("ExportUserData() error = nil, want the settings error")
+		}
+		if err := service.ExportUserData(context.Background(), 99, constants.DocumentExportFormatJSON, open); err == nil {
+			t.Error("ExportUserData() error = nil, want not found for an unknown user")
+		}
+		if opened {
+			t.Error("export was opened although nothing could be written")
+		}
+	})
+}
+
+func TestUserDataExportService_Jobs(t *testing.T) {
+	userRepo := NewMockUserRepository()
+	if err := userRepo.Create(context.Background(), &models.User{Username: "ola", Email: "ola@example.com"}); err != nil {
+		t.Fatalf("Create() error = %v", err)
+	}
+
+	// More documents than fit in one batch, each with one entity
+	sources := &stubUserDataSources{entities: make(map[int64][]*models.DetectedEntityWithMethod)}
+	documentCount := constants.UserDataExportBatchSize + 10
+	for id := int64(1); id <= int64(documentCount); id++ {
+		sources.docs = append(sources.docs, &models.Document{ID: id, UserID: 1, HashedDocumentName: "doc.pdf"})
+		sources.entities[id] = []*models.DetectedEntityWithMethod{{DetectedEntity: models.DetectedEntity{ID: 100 + id, DocumentID: id}}}
+	}
+	jobs := &memoryUserDataExportRepository{chunks: make(map[int64][]*models.UserDataExportChunk)}
+	service := NewUserDataExportService(userRepo, sources, sources, sources)
+	service.SetJobs(jobs)
+
+	job, err := service.CreateJob(context.Background(), 1, constants.DocumentExportFormatJSON)
+	if err != nil {
+		t.Fatalf("CreateJob() error = %v", err)
+	}
+	if _, err := service.CreateJob(context.Background(), 1, constants.DocumentExportFormatJSON); !errors.Is(err, ErrUserDataExportActive) {
+		t.Errorf("CreateJob() error = %v, want ErrUserDataExportActive while the first job is unfinished", err)
+	}
+
+	// A shutdown during the first batch lets the batch finish and interrupts the job
+	ctx, cancel := context.WithCancel(context.Background())
+	sources.onEntities = func(documentID int64) { cancel() }
+	claimed, _ := jobs.Claim(ctx, time.Now(), time.Now())
+	service.runJob(ctx, claimed)
+
+	interrupted, err := service.GetJob(context.Background(), 1, job.ID)
+	if err != nil {
+		t.Fatalf("GetJob() error = %v", err)
+	}
+	if interrupted.Status != constants.UserDataExportInterrupted {
+		t.Errorf("Status = %s, want interrupted", interrupted.Status)
+	}
+	wantCheckpoint := models.UserDataExportCheckpoint{
+		LastDocumentID:    int64(constants.UserDataExportBatchSize),
+		LastEntityID:      int64(100 + constants.UserDataExportBatchSize),
+		DocumentsExported: int64(constants.UserDataExportBatchSize),
+		EntitiesExported:  int64(constants.UserDataExportBatchSize),
+		Chunks:            3,
+	}
+	if interrupted.Checkpoint != wantCheckpoint {
+		t.Errorf("Checkpoint = %+v, want %+v", interrupted.Checkpoint, wantCheckpoint)
+	}
+	if err := service.DownloadJob(context.Background(), 1, job.ID, func(*models.UserDataExportJob) io.Writer { return io.Discard }); !errors.Is(err, ErrUserDataExportNotReady) {
+		t.Errorf("DownloadJob() error = %v, want ErrUserDataExportNotReady", err)
+	}
+
+	// The next run resumes after the checkpoint
+	sources.onEntities = nil
+	sources.entityCalls = nil
+	claimed, _ = jobs.Claim(context.Background(), time.Now(), time.Now())
+	service.runJob(context.Background(), claimed)
+
+	completed, _ := service.GetJob(context.Background(), 1, job.ID)
+	if completed.Status != constants.UserDataExportCompleted || completed.Resumes != 1 {
+		t.Errorf("job = %s with %d resumes, want completed with 1", completed.Status, completed.Resumes)
+	}
+	if len(sources.entityCalls) != 10 || sources.entityCalls[0] != int64(constants.UserDataExportBatchSize+1) {
+		t.Errorf("resumed run read entities of %d documents starting at %v, want the last 10", len(sources.entityCalls), sources.entityCalls)
+	}
+
+	var buf bytes.Buffer
+	err = service.DownloadJob(context.Background(), 1, job.ID, func(job *models.UserDataExportJob) io.Writer { return &buf })
+	if err != nil {
+		t.Fatalf("DownloadJob() error = %v", err)
+	}
+	var export models.UserDataExport
+	if err := json.Unmarshal(buf.Bytes(), &export); err != nil {
+		t.Fatalf("export is not valid JSON: %v", err)
+	}
+	if export.User == nil || export.User.Username != "ola" {
+		t.Errorf("User = %+v, want ola", export.User)
+	}
+	if len(export.Documents) != documentCount || len(export.DetectedEntities) != documentCount {
+		t.Errorf("export has %d documents and %d entities, want %d of each", len(export.Documents), len(export.DetectedEntities), documentCount)
+	}
+	seen := make(map[int64]bool)
+	for _, doc := range export.Documents {
+		if seen[doc.ID] {
+			t.Errorf("document %d exported twice", doc.ID)
+		}
+		seen[doc.ID] = true
+	}
+
+	// Missing jobs are not found
+	if err := service.DownloadJob(context.Background(), 2, job.ID, func(*models.UserDataExportJob) io.Writer { return io.Discard }); statusCode(err) != http.StatusNotFound {
+		t.Errorf("DownloadJob() error = %v, want not found for another user", err)
+	}
+}
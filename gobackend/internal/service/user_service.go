@@ -11,6 +11,7 @@ package service
 import (
 	"context"
 	"fmt"
+	"time"
 
 	"github.com/rs/zerolog/log"
 
@@ -19,6 +20,7 @@ import (
 	"github.com/yasinhessnawi1/Hideme_Backend/internal/models"
 	"github.com/yasinhessnawi1/Hideme_Backend/internal/repository"
 	"github.com/yasinhessnawi1/Hideme_Backend/internal/utils"
+	"github.com/yasinhessnawi1/Hideme_Backend/internal/utils/clock"
 )
 
 // UserService handles user-related operations.
@@ -29,6 +31,11 @@ type UserService struct {
 	sessionRepo repository.SessionRepository
 	apiKeyRepo  repository.APIKeyRepository
 	passwordCfg *auth.PasswordConfig
+
+	// tenantKeyRepo holds the data-encryption keys shredded when an account is deleted
+	tenantKeyRepo repository.TenantKeyRepository
+
+	clock clock.Clock
 }
 
 // NewUserService creates a new UserService.
@@ -55,6 +62,24 @@ func NewUserService(
 	}
 }
 
+// SetClock sets the clock accounts are disabled at.
+func (s *UserService) SetClock(c clock.Clock) {
+	s.clock = c
+}
+
+// now returns the current time of the service's clock.
+func (s *UserService) now() time.Time {
+	return clock.Or(s.clock).Now()
+}
+
+// SetTenantKeyRepository enables crypto-shredding of a tenant's data when its account is deleted.
+//
+// Parameters:
+//   - tenantKeyRepo: Repository of the tenant data-encryption keys
+func (s *UserService) SetTenantKeyRepository(tenantKeyRepo repository.TenantKeyRepository) {
+	s.tenantKeyRepo = tenantKeyRepo
+}
+
 // GetUserByID retrieves a user by ID.
 // Sensitive fields are sanitized before returning the user object.
 //
@@ -73,6 +98,45 @@ func (s *UserService) GetUserByID(ctx context.Context, id int64) (*models.User,
 	return user.Sanitize(), nil
 }
 
+// FindUser looks up a user by ID, username or email for administrators.
+// Exactly one of the identifiers must be set.
+//
+// Parameters:
+//   - ctx: Context for the database operation
+//   - id: The user ID, or 0
+//   - username: The username, or the empty string
+//   - email: The email address, or the empty string
+//
+// Returns:
+//   - *models.User: The user object with sensitive fields sanitized
+//   - error: A bad request error unless exactly one identifier is set, a not found error, or nil if successful
+func (s *UserService) FindUser(ctx context.Context, id int64, username, email string) (*models.User, error) {
+	set := 0
+	for _, given := range []bool{id != 0, username != "", email != ""} {
+		if given {
+			set++
+		}
+	}
+	if set != 1 {
+		return nil, utils.NewBadRequestError(constants.MsgUserLookupIdentifier)
+	}
+
+	var user *models.User
+	var err error
+	switch {
+	case id != 0:
+		user, err = s.userRepo.GetByID(ctx, id)
+	case username != "":
+		user, err = s.userRepo.GetByUsername(ctx, username)
+	default:
+		user, err = s.userRepo.GetByEmail(ctx, email)
+	}
+	if err != nil {
+		return nil, err
+	}
+	return user.Sanitize(), nil
+}
+
 // UpdateUser updates a user's profile information.
 // It validates changes and checks for uniqueness constraints on username and email.
 //
@@ -240,6 +304,13 @@ func (s *UserService) DeleteUser(ctx context.Context, id int64) error {
 		return fmt.Errorf("failed to delete user: %w", err)
 	}
 
+	// Shred the tenant's data key, so ciphertexts left in backups can no longer be decrypted
+	if s.tenantKeyRepo != nil {
+		if err := s.tenantKeyRepo.Delete(ctx, id); err != nil {
+			return fmt.Errorf("failed to delete tenant data key: %w", err)
+		}
+	}
+
 	log.Info().
 		Int64("user_id", id).
 		Str("category", constants.LogCategoryUser).
@@ -319,9 +390,10 @@ func (s *UserService) GetUserActiveSessions(ctx context.Context, userID int64) (
 	result := make([]*models.ActiveSessionInfo, len(sessions))
 	for i, session := range sessions {
 		result[i] = &models.ActiveSessionInfo{
-			ID:        session.ID,
-			CreatedAt: session.CreatedAt,
-			ExpiresAt: session.ExpiresAt,
+			ID:             session.ID,
+			CreatedAt:      session.CreatedAt,
+			ExpiresAt:      session.ExpiresAt,
+			LastActivityAt: session.LastActivityAt,
 		}
 	}
 
@@ -364,3 +436,225 @@ func (s *UserService) InvalidateSession(ctx context.Context, userID int64, sessi
 
 	return nil
 }
+
+// RevokeSessions signs a user out of every device by deleting all of their sessions.
+// Administrators use it when an account is compromised.
+//
+// Parameters:
+//   - ctx: Context for the database operation
+//   - userID: The ID of the user whose sessions to revoke
+//
+// Returns:
+//   - int: The number of active sessions that were revoked
+//   - error: A not found error if the user does not exist, any other error encountered, or nil if successful
+func (s *UserService) RevokeSessions(ctx context.Context, userID int64) (int, error) {
+	if _, err := s.userRepo.GetByID(ctx, userID); err != nil {
+		return 0, err
+	}
+
+	sessions, err := s.sessionRepo.GetActiveByUserID(ctx, userID)
+	if err != nil {
+		return 0, fmt.Errorf("failed to get active sessions: %w", err)
+	}
+
+	if err := s.sessionRepo.DeleteByUserID(ctx, userID); err != nil {
+		return 0, fmt.Errorf("failed to revoke sessions: %w", err)
+	}
+
+	log.Info().
+		Int64("user_id", userID).
+		Int("count", len(sessions)).
+		Str("category", constants.LogCategoryAuth).
+		Msg("Sessions revoked by administrator")
+
+	return len(sessions), nil
+}
+
+// ListUsers returns one page of users for administrators.
+//
+// Parameters:
+//   - ctx: Context for the database operation
+//   - filter: The role and status to select; empty fields select all users
+//   - page: The page number (starting from 1)
+//   - pageSize: The number of users per page
+//
+// Returns:
+//   - []*models.User: The users of the page with sensitive fields sanitized
+//   - int: The total number of matching users
+//   - error: A validation error for an unknown role or status, any other error encountered, or nil if successful
+func (s *UserService) ListUsers(ctx context.Context, filter models.UserFilter, page, pageSize int) ([]*models.User, int, error) {
+	if filter.Role != "" && !validRole(filter.Role) {
+		return nil, 0, utils.NewValidationError("role", constants.MsgInvalidRole)
+	}
+	if filter.Status != "" && filter.Status != constants.UserStatusActive && filter.Status != constants.UserStatusDisabled {
+		return nil, 0, utils.NewValidationError("status", constants.MsgUserStatusInvalid)
+	}
+
+	users, total, err := s.userRepo.List(ctx, filter, page, pageSize)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	for i, user := range users {
+		users[i] = user.Sanitize()
+	}
+	return users, total, nil
+}
+
+// DisableUser stops a user from signing in and from using their API keys.
+// The user is signed out of every device; the account and its data are kept.
+//
+// Parameters:
+//   - ctx: Context for the database operation
+//   - adminID: The ID of the administrator disabling the account
+//   - userID: The ID of the user to disable
+//
+// Returns:
+//   - error: A forbidden error for the administrator's own account, a not found error, any other error encountered, or nil if successful
+func (s *UserService) DisableUser(ctx context.Context, adminID, userID int64) error {
+	if adminID == userID {
+		return utils.NewForbiddenError(constants.MsgOwnAccountChange)
+	}
+
+	user, err := s.userRepo.GetByID(ctx, userID)
+	if err != nil {
+		return err
+	}
+	if user.IsDisabled() {
+		return nil
+	}
+
+	now := s.now()
+	if err := s.userRepo.SetDisabled(ctx, userID, &now); err != nil {
+		return err
+	}
+
+	if err := s.sessionRepo.DeleteByUserID(ctx, userID); err != nil {
+		return fmt.Errorf("failed to revoke sessions: %w", err)
+	}
+
+	log.Info().
+		Int64("user_id", userID).
+		Int64("admin_id", adminID).
+		Str("category", constants.LogCategoryAuth).
+		Msg("User disabled by administrator")
+
+	return nil
+}
+
+// EnableUser lets a disabled user sign in and use their API keys again.
+//
+// Parameters:
+//   - ctx: Context for the database operation
+//   - adminID: The ID of the administrator enabling the account
+//   - userID: The ID of the user to enable
+//
+// Returns:
+//   - error: A not found error, any other error encountered, or nil if successful
+func (s *UserService) EnableUser(ctx context.Context, adminID, userID int64) error {
+	if err := s.userRepo.SetDisabled(ctx, userID, nil); err != nil {
+		return err
+	}
+
+	log.Info().
+		Int64("user_id", userID).
+		Int64("admin_id", adminID).
+		Str("category", constants.LogCategoryAuth).
+		Msg("User enabled by administrator")
+
+	return nil
+}
+
+// SetRole changes the role of a user. Administrators can't change their own role,
+// so the last administrator can't lock everyone out of the admin API. The admin role
+// itself is never granted here: a second administrator has to approve an
+// models.ActionAdminPromotion action, which runs GrantAdminRole.
+//
+// Parameters:
+//   - ctx: Context for the database operation
+//   - adminID: The ID of the administrator changing the role
+//   - userID: The ID of the user
+//   - role: The new role, any constants.Role value except constants.RoleAdmin
+//
+// Returns:
+//   - *models.User: The updated user object with sensitive fields sanitized
+//   - error: A forbidden error for the administrator's own account or the admin role, a not found error, any other error encountered, or nil if successful
+func (s *UserService) SetRole(ctx context.Context, adminID, userID int64, role string) (*models.User, error) {
+	if adminID == userID {
+		return nil, utils.NewForbiddenError(constants.MsgOwnAccountChange)
+	}
+	if role == constants.RoleAdmin {
+		return nil, utils.NewForbiddenError(constants.MsgAdminPromotionRequiresApproval)
+	}
+
+	user, previous, err := s.changeRole(ctx, userID, role)
+	if err != nil {
+		return nil, err
+	}
+
+	if previous != role {
+		log.Info().
+			Int64("user_id", userID).
+			Int64("admin_id", adminID).
+			Str("previous_role", previous).
+			Str("role", role).
+			Str("category", constants.LogCategoryAuth).
+			Msg("User role changed by administrator")
+	}
+
+	return user.Sanitize(), nil
+}
+
+// GrantAdminRole makes a user an administrator. It is the executor of approved
+// models.ActionAdminPromotion actions, so every new administrator was requested
+// by one administrator and approved by another.
+//
+// Parameters:
+//   - ctx: Context for the database operation
+//   - userID: The ID of the user to promote
+//
+// Returns:
+//   - error: A not found error, any other error encountered, or nil if successful
+func (s *UserService) GrantAdminRole(ctx context.Context, userID int64) error {
+	_, previous, err := s.changeRole(ctx, userID, constants.RoleAdmin)
+	if err != nil {
+		return err
+	}
+
+	log.Info().
+		Int64("user_id", userID).
+		Str("previous_role", previous).
+		Str("category", constants.LogCategoryAuth).
+		Msg("User promoted to administrator")
+
+	return nil
+}
+
+// changeRole stores a new role for a user and returns the user with the role it had before.
+// The write is skipped if the role is unchanged.
+func (s *UserService) changeRole(ctx context.Context, userID int64, role string) (*models.User, string, error) {
+	user, err := s.userRepo.GetByID(ctx, userID)
+	if err != nil {
+		return nil, "", err
+	}
+
+	previous := user.Role
+	if previous != role {
+		user.Role = role
+		if err := s.userRepo.Update(ctx, user); err != nil {
+			return nil, "", err
+		}
+	}
+
+	return user, previous, nil
+}
+
+// validRole reports whether a role is one of the constants.Role values.
+func validRole(role string) bool {
+	switch role {
+	case constants.RoleUser, constants.RoleAdmin, constants.RoleAuditor, constants.RoleSupport:
+		return true
+	default:
+		return false
+	}
+}
@@ -2,11 +2,14 @@ package service
 
 import (
 	"context"
+	"errors"
 	"testing"
 	"time"
 
 	"github.com/yasinhessnawi1/Hideme_Backend/internal/auth"
+	"github.com/yasinhessnawi1/Hideme_Backend/internal/constants"
 	"github.com/yasinhessnawi1/Hideme_Backend/internal/models"
+	"github.com/yasinhessnawi1/Hideme_Backend/internal/utils"
 )
 
 func TestNewUserService(t *testing.T) {
@@ -87,7 +90,10 @@ func TestUserService_DeleteUser(t *testing.T) {
 	apiKeyRepo := NewMockAPIKeyRepository()
 	passwordCfg := auth.DefaultPasswordConfig()
 
+	tenantKeyRepo := NewMockTenantKeyRepository()
+
 	service := NewUserService(userRepo, sessionRepo, apiKeyRepo, passwordCfg)
+	service.SetTenantKeyRepository(tenantKeyRepo)
 
 	// Create a test user
 	user := &models.User{
@@ -104,6 +110,11 @@ func TestUserService_DeleteUser(t *testing.T) {
 		t.Fatalf("Failed to create test user: %v", err)
 	}
 
+	// Create the tenant's data key
+	if _, err := tenantKeyRepo.GetOrCreateDataKey(context.Background(), user.ID); err != nil {
+		t.Fatalf("Failed to create data key: %v", err)
+	}
+
 	// Create a session
 	session := &models.Session{
 		ID:        "testsession",
@@ -167,6 +178,11 @@ func TestUserService_DeleteUser(t *testing.T) {
 		t.Errorf("Expected 0 API keys after user deletion, got %d", len(apiKeys))
 	}
 
+	// Check that the tenant's data key was shredded
+	if _, err := tenantKeyRepo.GetDataKey(context.Background(), user.ID); err == nil {
+		t.Error("Expected the tenant data key to be deleted")
+	}
+
 	// Test with non-existent user
 	err = service.DeleteUser(context.Background(), 999)
 
@@ -782,3 +798,276 @@ func TestUserService_ChangePassword(t *testing.T) {
 
 	})
 }
+
+// MockTenantKeyRepository is an in-memory implementation of repository.TenantKeyRepository
+type MockTenantKeyRepository struct {
+	keys map[int64][]byte
+}
+
+func NewMockTenantKeyRepository() *MockTenantKeyRepository {
+	return &MockTenantKeyRepository{keys: make(map[int64][]byte)}
+}
+
+func (m *MockTenantKeyRepository) GetOrCreateDataKey(ctx context.Context, userID int64) ([]byte, error) {
+	if _, ok := m.keys[userID]; !ok {
+		m.keys[userID] = []byte("tenant-data-key-for-unit-tests-0")
+	}
+	return m.keys[userID], nil
+}
+
+func (m *MockTenantKeyRepository) GetDataKey(ctx context.Context, userID int64) ([]byte, error) {
+	key, ok := m.keys[userID]
+	if !ok {
+		return nil, utils.NewNotFoundError("TenantKey", userID)
+	}
+	return key, nil
+}
+
+func (m *MockTenantKeyRepository) Delete(ctx context.Context, userID int64) error {
+	delete(m.keys, userID)
+	return nil
+}
+
+func TestUserService_FindUser(t *testing.T) {
+	// Setup
+	userRepo := NewMockUserRepository()
+	service := NewUserService(userRepo, NewMockSessionRepository(), NewMockAPIKeyRepository(), auth.DefaultPasswordConfig())
+
+	user := &models.User{
+		Username:     "testuser",
+		Email:        "test@example.com",
+		PasswordHash: "hashed-password",
+		Salt:         "salt-value",
+	}
+	if err := userRepo.Create(context.Background(), user); err != nil {
+		t.Fatalf("Failed to create test user: %v", err)
+	}
+
+	tests := []struct {
+		name     string
+		id       int64
+		username string
+		email    string
+		wantErr  bool
+	}{
+		{name: "By ID", id: user.ID},
+		{name: "By username", username: "testuser"},
+		{name: "By email", email: "test@example.com"},
+		{name: "Unknown username", username: "nobody", wantErr: true},
+		{name: "No identifier", wantErr: true},
+		{name: "Several identifiers", id: user.ID, email: "test@example.com", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			found, err := service.FindUser(context.Background(), tt.id, tt.username, tt.email)
+			if tt.wantErr {
+				if err == nil {
+					t.Error("Expected error")
+				}
+				return
+			}
+
+			if err != nil {
+				t.Fatalf("FindUser() error = %v", err)
+			}
+			if found.ID != user.ID {
+				t.Errorf("Expected ID = %d, got %d", user.ID, found.ID)
+			}
+			if found.PasswordHash != "" || found.Salt != "" {
+				t.Error("Expected sanitized user")
+			}
+		})
+	}
+}
+
+func TestUserService_RevokeSessions(t *testing.T) {
+	// Setup
+	userRepo := NewMockUserRepository()
+	sessionRepo := NewMockSessionRepository()
+	service := NewUserService(userRepo, sessionRepo, NewMockAPIKeyRepository(), auth.DefaultPasswordConfig())
+
+	user := &models.User{Username: "testuser", Email: "test@example.com"}
+	if err := userRepo.Create(context.Background(), user); err != nil {
+		t.Fatalf("Failed to create test user: %v", err)
+	}
+
+	for _, id := range []string{"session1", "session2"} {
+		session := &models.Session{
+			ID:        id,
+			UserID:    user.ID,
+			JWTID:     id + "-jwt",
+			ExpiresAt: time.Now().Add(24 * time.Hour),
+			CreatedAt: time.Now(),
+		}
+		if err := sessionRepo.Create(context.Background(), session); err != nil {
+			t.Fatalf("Failed to create session: %v", err)
+		}
+	}
+
+	// Revoke all sessions
+	count, err := service.RevokeSessions(context.Background(), user.ID)
+	if err != nil {
+		t.Fatalf("RevokeSessions() error = %v", err)
+	}
+	if count != 2 {
+		t.Errorf("Expected 2 revoked sessions, got %d", count)
+	}
+
+	sessions, _ := sessionRepo.GetActiveByUserID(context.Background(), user.ID)
+	if len(sessions) != 0 {
+		t.Errorf("Expected no active sessions, got %d", len(sessions))
+	}
+
+	// Test with non-existent user
+	if _, err := service.RevokeSessions(context.Background(), 999); err == nil {
+		t.Error("Expected error for non-existent user")
+	}
+}
+
+func TestUserService_ListUsers(t *testing.T) {
+	// Setup
+	userRepo := NewMockUserRepository()
+	service := NewUserService(userRepo, NewMockSessionRepository(), NewMockAPIKeyRepository(), auth.DefaultPasswordConfig())
+
+	disabledAt := time.Now()
+	for _, user := range []*models.User{
+		{Username: "admin", Email: "admin@example.com", Role: constants.RoleAdmin, PasswordHash: "hash"},
+		{Username: "alice", Email: "alice@example.com", Role: constants.RoleUser, PasswordHash: "hash"},
+		{Username: "bob", Email: "bob@example.com", Role: constants.RoleUser, DisabledAt: &disabledAt},
+	} {
+		if err := userRepo.Create(context.Background(), user); err != nil {
+			t.Fatalf("Failed to create test user: %v", err)
+		}
+	}
+
+	users, total, err := service.ListUsers(context.Background(), models.UserFilter{}, 1, 2)
+	if err != nil {
+		t.Fatalf("ListUsers() error = %v", err)
+	}
+	if total != 3 || len(users) != 2 || users[0].PasswordHash != "" {
+		t.Errorf("Expected a sanitized first page of two out of three users, got %d of %d", len(users), total)
+	}
+
+	users, total, err = service.ListUsers(context.Background(), models.UserFilter{Role: constants.RoleUser, Status: constants.UserStatusActive}, 1, 10)
+	if err != nil {
+		t.Fatalf("ListUsers() error = %v", err)
+	}
+	if total != 1 || users[0].Username != "alice" {
+		t.Errorf("Expected only alice, got %d users", total)
+	}
+
+	var appErr *utils.AppError
+	if _, _, err := service.ListUsers(context.Background(), models.UserFilter{Status: "locked"}, 1, 10); !errors.As(err, &appErr) || appErr.StatusCode != 400 {
+		t.Errorf("Expected bad request for an unknown status, got %v", err)
+	}
+}
+
+func TestUserService_DisableUser(t *testing.T) {
+	// Setup
+	userRepo := NewMockUserRepository()
+	sessionRepo := NewMockSessionRepository()
+	service := NewUserService(userRepo, sessionRepo, NewMockAPIKeyRepository(), auth.DefaultPasswordConfig())
+
+	admin := &models.User{Username: "admin", Email: "admin@example.com", Role: constants.RoleAdmin}
+	user := &models.User{Username: "testuser", Email: "test@example.com", Role: constants.RoleUser}
+	for _, u := range []*models.User{admin, user} {
+		if err := userRepo.Create(context.Background(), u); err != nil {
+			t.Fatalf("Failed to create test user: %v", err)
+		}
+	}
+
+	session := &models.Session{ID: "session1", UserID: user.ID, JWTID: "jwt1", ExpiresAt: time.Now().Add(time.Hour), CreatedAt: time.Now()}
+	if err := sessionRepo.Create(context.Background(), session); err != nil {
+		t.Fatalf("Failed to create session: %v", err)
+	}
+
+	// Administrators can't disable themselves
+	var appErr *utils.AppError
+	if err := service.DisableUser(context.Background(), admin.ID, admin.ID); !errors.As(err, &appErr) || appErr.StatusCode != 403 {
+		t.Errorf("Expected forbidden for disabling the own account, got %v", err)
+	}
+
+	if err := service.DisableUser(context.Background(), admin.ID, user.ID); err != nil {
+		t.Fatalf("DisableUser() error = %v", err)
+	}
+	if !user.IsDisabled() {
+		t.Error("Expected the user to be disabled")
+	}
+	if sessions, _ := sessionRepo.GetActiveByUserID(context.Background(), user.ID); len(sessions) != 0 {
+		t.Errorf("Expected the user's sessions to be revoked, got %d", len(sessions))
+	}
+
+	if err := service.EnableUser(context.Background(), admin.ID, user.ID); err != nil {
+		t.Fatalf("EnableUser() error = %v", err)
+	}
+	if user.IsDisabled() {
+		t.Error("Expected the user to be enabled")
+	}
+
+	if err := service.DisableUser(context.Background(), admin.ID, 999); err == nil {
+		t.Error("Expected error for non-existent user")
+	}
+}
+
+func TestUserService_SetRole(t *testing.T) {
+	// Setup
+	userRepo := NewMockUserRepository()
+	service := NewUserService(userRepo, NewMockSessionRepository(), NewMockAPIKeyRepository(), auth.DefaultPasswordConfig())
+
+	admin := &models.User{Username: "admin", Email: "admin@example.com", Role: constants.RoleAdmin}
+	user := &models.User{Username: "testuser", Email: "test@example.com", Role: constants.RoleUser}
+	for _, u := range []*models.User{admin, user} {
+		if err := userRepo.Create(context.Background(), u); err != nil {
+			t.Fatalf("Failed to create test user: %v", err)
+		}
+	}
+
+	updated, err := service.SetRole(context.Background(), admin.ID, user.ID, constants.RoleSupport)
+	if err != nil {
+		t.Fatalf("SetRole() error = %v", err)
+	}
+	if updated.Role != constants.RoleSupport {
+		t.Errorf("Expected role = %s, got %s", constants.RoleSupport, updated.Role)
+	}
+
+	// Administrators can't demote themselves
+	var appErr *utils.AppError
+	if _, err := service.SetRole(context.Background(), admin.ID, admin.ID, constants.RoleUser); !errors.As(err, &appErr) || appErr.StatusCode != 403 {
+		t.Errorf("Expected forbidden for changing the own role, got %v", err)
+	}
+
+	// The admin role needs an approved admin_promotion action
+	if _, err := service.SetRole(context.Background(), admin.ID, user.ID, constants.RoleAdmin); !errors.As(err, &appErr) || appErr.StatusCode != 403 {
+		t.Errorf("Expected forbidden for granting the admin role directly, got %v", err)
+	}
+	if stored, _ := userRepo.GetByID(context.Background(), user.ID); stored.Role != constants.RoleSupport {
+		t.Errorf("Expected role = %s after a direct admin grant, got %s", constants.RoleSupport, stored.Role)
+	}
+}
+
+func TestUserService_GrantAdminRole(t *testing.T) {
+	// Setup
+	userRepo := NewMockUserRepository()
+	service := NewUserService(userRepo, NewMockSessionRepository(), NewMockAPIKeyRepository(), auth.DefaultPasswordConfig())
+
+	user := &models.User{Username: "testuser", Email: "test@example.com", Role: constants.RoleUser}
+	if err := userRepo.Create(context.Background(), user); err != nil {
+		t.Fatalf("Failed to create test user: %v", err)
+	}
+
+	if err := service.GrantAdminRole(context.Background(), user.ID); err != nil {
+		t.Fatalf("GrantAdminRole() error = %v", err)
+	}
+	stored, err := userRepo.GetByID(context.Background(), user.ID)
+	if err != nil {
+		t.Fatalf("Failed to get test user: %v", err)
+	}
+	if stored.Role != constants.RoleAdmin {
+		t.Errorf("Expected role = %s, got %s", constants.RoleAdmin, stored.Role)
+	}
+
+	if err := service.GrantAdminRole(context.Background(), user.ID+100); err == nil {
+		t.Error("Expected an error for a user that doesn't exist")
+	}
+}
@@ -0,0 +1,498 @@
+// Package service provides business logic implementations.
+package service
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strconv"
+	"time"
+
+	"github.com/rs/zerolog/log"
+
+	"github.com/yasinhessnawi1/Hideme_Backend/internal/config"
+	"github.com/yasinhessnawi1/Hideme_Backend/internal/constants"
+	"github.com/yasinhessnawi1/Hideme_Backend/internal/models"
+	"github.com/yasinhessnawi1/Hideme_Backend/internal/repository"
+	"github.com/yasinhessnawi1/Hideme_Backend/internal/utils"
+	"github.com/yasinhessnawi1/Hideme_Backend/internal/utils/clock"
+	"github.com/yasinhessnawi1/Hideme_Backend/internal/worker"
+)
+
+var (
+	// ErrTooManyWebhooks is returned when a user registers more than constants.MaxWebhooksPerUser webhooks
+	ErrTooManyWebhooks = utils.New(utils.ErrBadRequest, constants.StatusConflict, constants.MsgTooManyWebhooks)
+
+	// ErrWebhookDeliveryNotDead is returned when a delivery is redelivered before it used up its attempts
+	ErrWebhookDeliveryNotDead = utils.New(utils.ErrBadRequest, constants.StatusConflict, constants.MsgWebhookDeliveryNotDead)
+)
+
+// webhookEvents are the event types webhooks can subscribe to.
+var webhookEvents = map[string]bool{
+	constants.WebhookEventDocumentProcessed: true,
+	constants.WebhookEventEntityDetected:    true,
+	constants.WebhookEventAPIKeyCreated:     true,
+	constants.WebhookEventSLOAlert:          true,
+}
+
+// WebhookPublisher queues events for the webhooks of their user.
+type WebhookPublisher interface {
+	Publish(ctx context.Context, userID int64, eventType string, data interface{})
+}
+
+// WebhookService manages users' webhooks and delivers account and document events to them.
+//
+// Events are queued in the database when they occur, one delivery per subscribed webhook,
+// and posted as JSON signed with the webhook's secret. A delivery the webhook doesn't accept
+// with a 2xx response is retried with a growing delay; once it has used up its attempts it
+// is dead-lettered, and kept until the user redelivers it or it is past its retention.
+type WebhookService struct {
+	webhookRepo  repository.WebhookRepository
+	deliveryRepo repository.WebhookDeliveryRepository
+	client       *http.Client
+	settings     *config.WebhookSettings
+	clock        clock.Clock
+
+	// wake tells RunDeliveries that an event was queued
+	wake chan struct{}
+}
+
+// NewWebhookService creates a new WebhookService.
+//
+// Parameters:
+//   - webhookRepo: Repository for webhooks
+//   - deliveryRepo: Repository for the delivery queue
+//   - settings: The webhook configuration
+//
+// Returns:
+//   - A configured WebhookService
+func NewWebhookService(
+	webhookRepo repository.WebhookRepository,
+	deliveryRepo repository.WebhookDeliveryRepository,
+	settings *config.WebhookSettings,
+) *WebhookService {
+	// Users choose the URLs, so the export dialer keeps them off private networks
+	dialer := newExportDialer(settings.AllowPrivateNetworks)
+
+	return &WebhookService{
+		webhookRepo:  webhookRepo,
+		deliveryRepo: deliveryRepo,
+		client: &http.Client{
+			Transport: &http.Transport{
+				DialContext:         dialer.DialContext,
+				TLSHandshakeTimeout: constants.WebhookDeliveryTimeout,
+			},
+			Timeout: constants.WebhookDeliveryTimeout,
+			// A redirect is not an acceptance; it would also send the event to another host
+			CheckRedirect: func(*http.Request, []*http.Request) error {
+				return http.ErrUseLastResponse
+			},
+		},
+		settings: settings,
+		wake:     make(chan struct{}, 1),
+	}
+}
+
+// SetClock sets the clock deliveries are queued and retried by.
+func (s *WebhookService) SetClock(c clock.Clock) {
+	s.clock = c
+}
+
+// now returns the current time of the service's clock.
+func (s *WebhookService) now() time.Time {
+	return clock.Or(s.clock).Now()
+}
+
+// ListWebhooks retrieves a user's webhooks, without their secrets.
+//
+// Parameters:
+//   - ctx: Context for the operation
+//   - userID: The ID of the user
+//
+// Returns:
+//   - The webhooks
+//   - An error if they can't be retrieved
+func (s *WebhookService) ListWebhooks(ctx context.Context, userID int64) ([]*models.Webhook, error) {
+	return s.webhookRepo.ListByUser(ctx, userID)
+}
+
+// CreateWebhook registers a webhook for a user. The URL is checked, but not called; a
+// failing webhook shows in its deliveries.
+//
+// Parameters:
+//   - ctx: Context for the operation
+//   - userID: The ID of the user
+//   - req: The webhook to register
+//
+// Returns:
+//   - The registered webhook, without its secret
+//   - ValidationError if the URL, secret or an event is invalid, ErrTooManyWebhooks if the
+//     user registered the maximum number of webhooks
+func (s *WebhookService) CreateWebhook(ctx context.Context, userID int64, req *models.WebhookCreate) (*models.Webhook, error) {
+	if err := s.validateURL(req.URL); err != nil {
+		return nil, err
+	}
+	if len(req.Secret) < constants.WebhookSecretMinLength {
+		return nil, utils.NewValidationError("secret", constants.MsgWebhookSecretTooShort)
+	}
+
+	// Subscribe to each event once
+	events := make([]string, 0, len(req.Events))
+	seen := make(map[string]bool, len(req.Events))
+	for _, event := range req.Events {
+		if !webhookEvents[event] {
+			return nil, utils.NewValidationError("events", constants.MsgWebhookEventInvalid)
+		}
+		if !seen[event] {
+			seen[event] = true
+			events = append(events, event)
+		}
+	}
+
+	existing, err := s.webhookRepo.ListByUser(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	if len(existing) >= constants.MaxWebhooksPerUser {
+		return nil, ErrTooManyWebhooks
+	}
+
+	webhook := &models.Webhook{
+		UserID: userID,
+		URL:    req.URL,
+		Secret: req.Secret,
+		Events: events,
+		Active: true,
+	}
+	if err := s.webhookRepo.Create(ctx, webhook); err != nil {
+		return nil, err
+	}
+
+	log.Info().
+		Int64("user_id", userID).
+		Int64("webhook_id", webhook.ID).
+		Strs("events", events).
+		Msg("Webhook registered")
+
+	webhook.Secret = ""
+	return webhook, nil
+}
+
+// DeleteWebhook removes a user's webhook with its deliveries, including those not yet made.
+//
+// Parameters:
+//   - ctx: Context for the operation
+//   - userID: The ID of the user
+//   - webhookID: The ID of the webhook
+//
+// Returns:
+//   - NotFoundError if the user has no such webhook
+func (s *WebhookService) DeleteWebhook(ctx context.Context, userID, webhookID int64) error {
+	if err := s.webhookRepo.Delete(ctx, webhookID, userID); err != nil {
+		return err
+	}
+
+	log.Info().
+		Int64("user_id", userID).
+		Int64("webhook_id", webhookID).
+		Msg("Webhook deleted")
+
+	return nil
+}
+
+// ListDeliveries retrieves the most recent deliveries to a user's webhook, newest first.
+//
+// Parameters:
+//   - ctx: Context for the operation
+//   - userID: The ID of the user
+//   - webhookID: The ID of the webhook
+//
+// Returns:
+//   - The deliveries
+//   - NotFoundError if the user has no such webhook
+func (s *WebhookService) ListDeliveries(ctx context.Context, userID, webhookID int64) ([]*models.WebhookDelivery, error) {
+	if _, err := s.webhookRepo.GetByID(ctx, webhookID, userID); err != nil {
+		return nil, err
+	}
+	return s.deliveryRepo.ListByWebhook(ctx, webhookID, userID, constants.WebhookDeliveryListLimit)
+}
+
+// Redeliver queues a dead-lettered delivery again, for example once the receiver is fixed.
+// It gets all its attempts again and is made shortly after.
+//
+// Parameters:
+//   - ctx: Context for the operation
+//   - userID: The ID of the user
+//   - webhookID: The ID of the webhook
+//   - deliveryID: The ID of the delivery
+//
+// Returns:
+//   - The queued delivery
+//   - NotFoundError if the user has no such webhook or delivery, ErrWebhookDeliveryNotDead
+//     if the delivery has not used up its attempts
+func (s *WebhookService) Redeliver(ctx context.Context, userID, webhookID, deliveryID int64) (*models.WebhookDelivery, error) {
+	delivery, err := s.deliveryRepo.GetByID(ctx, deliveryID, webhookID, userID)
+	if err != nil {
+		return nil, err
+	}
+	if delivery.Status != constants.WebhookDeliveryDead {
+		return nil, ErrWebhookDeliveryNotDead
+	}
+
+	now := s.now()
+	if err := s.deliveryRepo.Redeliver(ctx, deliveryID, now); err != nil {
+		if utils.IsNotFoundError(err) {
+			// Redelivered by another request in the meantime
+			return nil, ErrWebhookDeliveryNotDead
+		}
+		return nil, err
+	}
+	s.notifyQueued()
+
+	log.Info().
+		Int64("user_id", userID).
+		Int64("webhook_id", webhookID).
+		Int64("delivery_id", deliveryID).
+		Msg("Webhook delivery queued again")
+
+	delivery.Status = constants.WebhookDeliveryPending
+	delivery.Attempts = 0
+	delivery.NextAttemptAt = now
+	delivery.UpdatedAt = now
+	return delivery, nil
+}
+
+// Publish queues an event for the user's webhooks subscribed to its type. Failures are
+// logged; they never fail the change the event reports. The event is queued even if ctx
+// is canceled, as the change has been made.
+//
+// Parameters:
+//   - ctx: Context for the operation
+//   - userID: The ID of the user the event belongs to
+//   - eventType: The type of the event, such as constants.WebhookEventDocumentProcessed
+//   - data: The event, marshaled to JSON
+func (s *WebhookService) Publish(ctx context.Context, userID int64, eventType string, data interface{}) {
+	payload, err := json.Marshal(data)
+	if err != nil {
+		log.Error().Err(err).Int64("user_id", userID).Str("event", eventType).Msg("Failed to marshal webhook event")
+		return
+	}
+
+	queued, err := s.deliveryRepo.Enqueue(context.WithoutCancel(ctx), userID, eventType, payload, s.now())
+	if err != nil {
+		log.Error().Err(err).Int64("user_id", userID).Str("event", eventType).Msg("Failed to queue webhook event")
+		return
+	}
+	if queued > 0 {
+		s.notifyQueued()
+	}
+}
+
+// notifyQueued wakes RunDeliveries, unless it is already woken.
+func (s *WebhookService) notifyQueued() {
+	select {
+	case s.wake <- struct{}{}:
+	default:
+	}
+}
+
+// RunDeliveries makes the deliveries that are due until ctx is canceled. Deliveries queued
+// on this server are made at once; those queued on other servers and retries within
+// constants.WebhookPollInterval.
+//
+// Parameters:
+//   - ctx: Context whose cancellation stops the deliveries
+func (s *WebhookService) RunDeliveries(ctx context.Context) {
+	ticker := time.NewTicker(constants.WebhookPollInterval)
+	defer ticker.Stop()
+
+	for {
+		// Make deliveries until none is due
+		for ctx.Err() == nil {
+			now := s.now()
+			delivery, err := s.deliveryRepo.Claim(ctx, now, now.Add(constants.WebhookDeliveryLease))
+			if err != nil {
+				if ctx.Err() == nil {
+					log.Error().Err(err).Msg("Failed to claim webhook delivery")
+				}
+				break
+			}
+			if delivery == nil {
+				break
+			}
+			// An attempt is not cut short by a shutdown
+			s.deliver(context.WithoutCancel(ctx), delivery)
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-s.wake:
+		case <-ticker.C:
+		}
+	}
+}
+
+// deliver posts a claimed delivery to its webhook and records the outcome. Failures are
+// logged; the delivery is attempted again once its lease expires.
+func (s *WebhookService) deliver(ctx context.Context, delivery *models.WebhookDelivery) {
+	logger := log.With().
+		Int64("user_id", delivery.UserID).
+		Int64("webhook_id", delivery.WebhookID).
+		Int64("delivery_id", delivery.ID).
+		Str("event", delivery.EventType).
+		Logger()
+
+	webhook, err := s.webhookRepo.GetByID(ctx, delivery.WebhookID, delivery.UserID)
+	if err != nil {
+		if !utils.IsNotFoundError(err) {
+			logger.Error().Err(err).Msg("Failed to get webhook of delivery")
+		}
+		// A deleted webhook took its deliveries with it
+		return
+	}
+
+	statusCode, postErr := s.post(ctx, webhook, delivery)
+
+	now := s.now()
+	delivery.LastStatusCode = statusCode
+	delivery.UpdatedAt = now
+	switch {
+	case postErr == nil:
+		delivery.Status = constants.WebhookDeliveryDelivered
+		delivery.LastError = ""
+		delivery.DeliveredAt = &now
+	case delivery.Attempts >= s.settings.MaxAttempts:
+		delivery.Status = constants.WebhookDeliveryDead
+		delivery.LastError = truncateWebhookError(postErr)
+	default:
+		delivery.Status = constants.WebhookDeliveryPending
+		delivery.LastError = truncateWebhookError(postErr)
+		delivery.NextAttemptAt = now.Add(worker.Backoff(delivery.Attempts))
+	}
+
+	if err := s.deliveryRepo.Finish(ctx, delivery); err != nil {
+		if !utils.IsNotFoundError(err) {
+			logger.Error().Err(err).Msg("Failed to record webhook delivery")
+		}
+		return
+	}
+
+	switch delivery.Status {
+	case constants.WebhookDeliveryDelivered:
+		logger.Debug().Int("attempt", delivery.Attempts).Msg("Webhook delivery accepted")
+	case constants.WebhookDeliveryDead:
+		logger.Warn().Err(postErr).Int("attempts", delivery.Attempts).Msg("Webhook delivery used up its attempts and was dead-lettered")
+	default:
+		logger.Info().Err(postErr).
+			Int("attempt", delivery.Attempts).
+			Time("next_attempt_at", delivery.NextAttemptAt).
+			Msg("Webhook delivery failed, retrying")
+	}
+}
+
+// post sends a delivery to its webhook, signed with the webhook's secret.
+//
+// Returns:
+//   - The status code of the response; 0 if there was none
+//   - An error if the request failed or the response is not 2xx
+func (s *WebhookService) post(ctx context.Context, webhook *models.Webhook, delivery *models.WebhookDelivery) (int, error) {
+	body, err := json.Marshal(&models.WebhookEvent{
+		ID:        delivery.ID,
+		Type:      delivery.EventType,
+		CreatedAt: delivery.CreatedAt,
+		Data:      delivery.Payload,
+	})
+	if err != nil {
+		return 0, fmt.Errorf("failed to marshal webhook event: %w", err)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, webhook.URL, bytes.NewReader(body))
+	if err != nil {
+		return 0, fmt.Errorf("failed to create webhook request: %w", err)
+	}
+	req.Header.Set(constants.HeaderContentType, constants.ContentTypeJSON)
+	req.Header.Set(constants.HeaderXHideMeEvent, delivery.EventType)
+	req.Header.Set(constants.HeaderXHideMeDelivery, strconv.FormatInt(delivery.ID, 10))
+	req.Header.Set(constants.HeaderXHideMeSignature, SignWebhookPayload(webhook.Secret, s.now().Unix(), body))
+
+	resp, err := s.client.Do(req)
+	if err != nil {
+		var urlErr *url.Error
+		if errors.As(err, &urlErr) {
+			// The URL is already known to the user; keep the cause only
+			err = urlErr.Err
+		}
+		return 0, err
+	}
+	defer resp.Body.Close()
+
+	// Read a little of the body, so the connection can be reused
+	_, _ = io.Copy(io.Discard, io.LimitReader(resp.Body, 4096))
+
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		return resp.StatusCode, fmt.Errorf("webhook responded with status %d", resp.StatusCode)
+	}
+	return resp.StatusCode, nil
+}
+
+// DeleteExpiredDeliveries deletes the deliveries of events older than constants.WebhookDeliveryRetention.
+//
+// Parameters:
+//   - ctx: Context for the operation
+//
+// Returns:
+//   - The number of deleted deliveries
+//   - An error if they can't be deleted
+func (s *WebhookService) DeleteExpiredDeliveries(ctx context.Context) (int64, error) {
+	return s.deliveryRepo.DeleteCreatedBefore(ctx, s.now().Add(-constants.WebhookDeliveryRetention))
+}
+
+// validateURL checks that a webhook URL is an absolute HTTPS URL. Plain HTTP is allowed
+// only with private networks, for receivers on the deployment's own network.
+func (s *WebhookService) validateURL(rawURL string) error {
+	u, err := url.Parse(rawURL)
+	if err != nil || u.Host == "" || u.User != nil {
+		return utils.NewValidationError("url", constants.MsgWebhookURLInvalid)
+	}
+	if u.Scheme != "https" && !(u.Scheme == "http" && s.settings.AllowPrivateNetworks) {
+		return utils.NewValidationError("url", constants.MsgWebhookURLInvalid)
+	}
+	return nil
+}
+
+// SignWebhookPayload signs the body of a webhook delivery as sent in the X-HideMe-Signature
+// header: t=<unix time>,v1=<hex HMAC-SHA256 of "<unix time>.<body>" with the secret>.
+// Receivers compute the same signature and reject old timestamps to stop replays.
+//
+// Parameters:
+//   - secret: The secret of the webhook
+//   - timestamp: The time of signing, in seconds since the Unix epoch
+//   - body: The body of the delivery
+//
+// Returns:
+//   - The header value
+func SignWebhookPayload(secret string, timestamp int64, body []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte(strconv.FormatInt(timestamp, 10)))
+	mac.Write([]byte("."))
+	mac.Write(body)
+	return fmt.Sprintf("t=%d,%s=%s", timestamp, constants.WebhookSignatureVersion, hex.EncodeToString(mac.Sum(nil)))
+}
+
+// truncateWebhookError cuts the error of a failed attempt to constants.WebhookErrorMaxLength.
+func truncateWebhookError(err error) string {
+	message := err.Error()
+	if len(message) > constants.WebhookErrorMaxLength {
+		message = message[:constants.WebhookErrorMaxLength]
+	}
+	return message
+}
@@ -0,0 +1,54 @@
+// Package fakeclock provides a clock for tests that only moves when the test moves it,
+// so time-dependent behavior such as token expiry can be checked at exact instants.
+package fakeclock
+
+import (
+	"sync"
+	"time"
+)
+
+// Clock is a controllable clock.Clock. It is safe for concurrent use, so services
+// running work in goroutines may read it while a test moves it.
+type Clock struct {
+	mu  sync.Mutex
+	now time.Time
+}
+
+// New creates a clock stopped at a time.
+//
+// Parameters:
+//   - now: The time the clock tells until it is moved, e.g. fixtures.Time
+//
+// Returns:
+//   - A new Clock
+func New(now time.Time) *Clock {
+	return &Clock{now: now}
+}
+
+// Now returns the time the clock was set to.
+func (c *Clock) Now() time.Time {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.now
+}
+
+// Set moves the clock to a time, forwards or backwards.
+func (c *Clock) Set(now time.Time) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.now = now
+}
+
+// Advance moves the clock forwards by a duration.
+//
+// Parameters:
+//   - d: How far to move the clock; negative durations move it backwards
+//
+// Returns:
+//   - The time after the move
+func (c *Clock) Advance(d time.Duration) time.Time {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.now = c.now.Add(d)
+	return c.now
+}
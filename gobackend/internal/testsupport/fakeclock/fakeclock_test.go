@@ -0,0 +1,26 @@
+package fakeclock
+
+import (
+	"testing"
+	"time"
+)
+
+func TestClock(t *testing.T) {
+	start := time.Date(2026, 1, 15, 12, 0, 0, 0, time.UTC)
+	c := New(start)
+
+	if got := c.Now(); !got.Equal(start) {
+		t.Errorf("Now() = %v, want %v", got, start)
+	}
+	if got := c.Advance(90 * time.Second); !got.Equal(start.Add(90 * time.Second)) {
+		t.Errorf("Advance() = %v, want %v", got, start.Add(90*time.Second))
+	}
+	if got := c.Now(); !got.Equal(start.Add(90 * time.Second)) {
+		t.Errorf("Now() after Advance = %v, want %v", got, start.Add(90*time.Second))
+	}
+
+	c.Set(start.Add(-time.Hour))
+	if got := c.Now(); !got.Equal(start.Add(-time.Hour)) {
+		t.Errorf("Now() after Set = %v, want %v", got, start.Add(-time.Hour))
+	}
+}
@@ -0,0 +1,75 @@
+// Package fixtures builds the users, settings and documents used by tests. Every
+// fixture has fixed values, including its timestamps, so responses built from them
+// can be compared with golden files. Tests change the fields they care about on the
+// returned values.
+package fixtures
+
+import (
+	"time"
+
+	"github.com/yasinhessnawi1/Hideme_Backend/internal/constants"
+	"github.com/yasinhessnawi1/Hideme_Backend/internal/models"
+)
+
+// Time is the timestamp of every fixture.
+var Time = time.Date(2026, 1, 15, 12, 0, 0, 0, time.UTC)
+
+// User returns a regular user.
+//
+// Parameters:
+//   - id: The ID of the user
+//
+// Returns:
+//   - A user named "testuser" with the email address test@example.com
+func User(id int64) *models.User {
+	return &models.User{
+		ID:           id,
+		Username:     "testuser",
+		Email:        "test@example.com",
+		Role:         constants.RoleUser,
+		PasswordHash: "hashed-password",
+		Salt:         "salt",
+		CreatedAt:    Time,
+		UpdatedAt:    Time,
+	}
+}
+
+// Settings returns the default settings of a user.
+//
+// Parameters:
+//   - userID: The ID of the user owning the settings
+//
+// Returns:
+//   - The settings models.NewUserSetting creates, with ID 1 and fixed timestamps
+func Settings(userID int64) *models.UserSetting {
+	settings := models.NewUserSetting(userID)
+	settings.ID = 1
+	settings.CreatedAt = Time
+	settings.UpdatedAt = Time
+	return settings
+}
+
+// Document returns a document with its name and redaction schema decrypted, as the
+// document service returns them.
+//
+// Parameters:
+//   - id: The ID of the document
+//   - userID: The ID of the user owning the document
+//
+// Returns:
+//   - An English PDF document with one person detected on its first page
+func Document(id, userID int64) *models.Document {
+	return &models.Document{
+		ID:                 id,
+		UserID:             userID,
+		HashedDocumentName: "contract.pdf",
+		UploadTimestamp:    Time,
+		LastModified:       Time,
+		RedactionSchema:    RedactionSchema,
+		Language:           "en",
+		Tags:               []string{},
+	}
+}
+
+// RedactionSchema is the redaction schema of Document, with one person on its first page.
+const RedactionSchema = `{"version":2,"pages":[{"page":1,"width":595,"height":842,"sensitive":[{"original_text":"Kari Nordmann","entity_type":"PERSON","score":0.98,"start":0,"end":13,"bbox":{"x0":0.1,"y0":0.2,"x1":0.35,"y1":0.25}}]}]}`
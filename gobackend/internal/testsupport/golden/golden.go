@@ -0,0 +1,105 @@
+// Package golden compares handler responses with golden files, JSON snapshots kept
+// next to the tests in testdata/golden. A change to a response envelope is reviewed
+// as a diff of the golden files instead of being chased through hand-written assertions.
+//
+// Run the tests with UPDATE_GOLDEN=1 to write the current responses to the golden files:
+//
+//	UPDATE_GOLDEN=1 go test ./internal/handlers/...
+package golden
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+// UpdateEnv is the environment variable that makes the assertions rewrite golden files.
+const UpdateEnv = "UPDATE_GOLDEN"
+
+// Dir is the directory of the golden files, relative to the package under test.
+const Dir = "testdata/golden"
+
+// snapshot is the content of a golden file for a handler response.
+type snapshot struct {
+	Status int             `json:"status"`
+	Body   json.RawMessage `json:"body"`
+}
+
+// AssertResponse compares the status and JSON body of a recorded response with the
+// golden file of the running test, named after the test and its subtests.
+//
+// Parameters:
+//   - t: The running test
+//   - rr: The recorded response; its body must be JSON
+func AssertResponse(t testing.TB, rr *httptest.ResponseRecorder) {
+	t.Helper()
+
+	body := rr.Body.Bytes()
+	if !json.Valid(body) {
+		t.Fatalf("response body is not JSON: %s", body)
+	}
+	got, err := json.Marshal(snapshot{Status: rr.Code, Body: body})
+	if err != nil {
+		t.Fatalf("failed to encode response snapshot: %v", err)
+	}
+	AssertJSON(t, t.Name(), got)
+}
+
+// AssertJSON compares a JSON document with a golden file. Both are compared in
+// canonical form, with object keys sorted and indented, so formatting does not matter.
+//
+// Parameters:
+//   - t: The running test
+//   - name: The name of the golden file without extension; slashes create subdirectories
+//   - got: The JSON document to compare
+func AssertJSON(t testing.TB, name string, got []byte) {
+	t.Helper()
+
+	canonical, err := canonicalize(got)
+	if err != nil {
+		t.Fatalf("failed to canonicalize JSON: %v", err)
+	}
+	path := Path(name)
+
+	if os.Getenv(UpdateEnv) != "" {
+		if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+			t.Fatalf("failed to create golden directory: %v", err)
+		}
+		if err := os.WriteFile(path, canonical, 0o644); err != nil {
+			t.Fatalf("failed to write golden file: %v", err)
+		}
+		return
+	}
+
+	want, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatalf("failed to read golden file %s (run with %s=1 to create it): %v", path, UpdateEnv, err)
+	}
+	if !bytes.Equal(bytes.TrimSpace(want), bytes.TrimSpace(canonical)) {
+		t.Errorf("response does not match golden file %s (run with %s=1 to update it)\nwant:\n%s\ngot:\n%s", path, UpdateEnv, want, canonical)
+	}
+}
+
+// Path returns the golden file of a name.
+func Path(name string) string {
+	return filepath.Join(Dir, filepath.FromSlash(name)+".json")
+}
+
+// canonicalize indents a JSON document with its object keys sorted.
+func canonicalize(data []byte) ([]byte, error) {
+	var value interface{}
+	decoder := json.NewDecoder(bytes.NewReader(data))
+	// Keep numbers as written, so large IDs and decimals are not rounded
+	decoder.UseNumber()
+	if err := decoder.Decode(&value); err != nil {
+		return nil, err
+	}
+	canonical, err := json.MarshalIndent(value, "", "  ")
+	if err != nil {
+		return nil, err
+	}
+	return append(canonical, '\n'), nil
+}
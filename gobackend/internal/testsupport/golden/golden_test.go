@@ -0,0 +1,76 @@
+package golden
+
+import (
+	"net/http/httptest"
+	"os"
+	"testing"
+)
+
+// recordingT records failures instead of failing the running test
+type recordingT struct {
+	testing.TB
+	failed bool
+}
+
+func (r *recordingT) Errorf(format string, args ...interface{}) {
+	r.failed = true
+}
+
+func TestAssertJSON(t *testing.T) {
+	wd, err := os.Getwd()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := os.Chdir(t.TempDir()); err != nil {
+		t.Fatal(err)
+	}
+	defer os.Chdir(wd)
+
+	t.Run("Update writes the canonical form", func(t *testing.T) {
+		t.Setenv(UpdateEnv, "1")
+		AssertJSON(t, "nested/doc", []byte(`{"b":1,"a":{"d":12345678901234567890,"c":0.1}}`))
+
+		written, err := os.ReadFile(Path("nested/doc"))
+		if err != nil {
+			t.Fatalf("golden file not written: %v", err)
+		}
+		want := "{\n  \"a\": {\n    \"c\": 0.1,\n    \"d\": 12345678901234567890\n  },\n  \"b\": 1\n}\n"
+		if string(written) != want {
+			t.Errorf("golden file = %q, want %q", written, want)
+		}
+	})
+
+	t.Run("Formatting does not matter", func(t *testing.T) {
+		rt := &recordingT{TB: t}
+		AssertJSON(rt, "nested/doc", []byte(`{"a":{"c":0.1,"d":12345678901234567890},"b":1}`))
+		if rt.failed {
+			t.Error("Expected an equal document to match")
+		}
+	})
+
+	t.Run("Changes fail", func(t *testing.T) {
+		rt := &recordingT{TB: t}
+		AssertJSON(rt, "nested/doc", []byte(`{"a":{"c":0.1,"d":12345678901234567890},"b":2}`))
+		if !rt.failed {
+			t.Error("Expected a changed document not to match")
+		}
+	})
+
+	t.Run("Responses are named after the test", func(t *testing.T) {
+		t.Setenv(UpdateEnv, "1")
+		rr := httptest.NewRecorder()
+		rr.WriteHeader(404)
+		rr.WriteString(`{"success":false}`)
+
+		AssertResponse(t, rr)
+
+		written, err := os.ReadFile(Path(t.Name()))
+		if err != nil {
+			t.Fatalf("golden file not written: %v", err)
+		}
+		want := "{\n  \"body\": {\n    \"success\": false\n  },\n  \"status\": 404\n}\n"
+		if string(written) != want {
+			t.Errorf("golden file = %q, want %q", written, want)
+		}
+	})
+}
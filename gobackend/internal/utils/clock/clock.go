@@ -0,0 +1,39 @@
+// Package clock provides the current time to the services that act on it, such as
+// token expiry, session cleanup, retention and maintenance scheduling. The services
+// read the time through a Clock instead of calling time.Now, so tests can set it and
+// check behavior at the edges of expiry deterministically.
+package clock
+
+import "time"
+
+// Clock tells the current time.
+type Clock interface {
+	// Now returns the current time.
+	Now() time.Time
+}
+
+// System is the clock of the operating system, used outside tests.
+var System Clock = systemClock{}
+
+// systemClock reads the time of the operating system.
+type systemClock struct{}
+
+// Now returns time.Now().
+func (systemClock) Now() time.Time {
+	return time.Now()
+}
+
+// Or returns c, or System if c is nil, so services built without a clock, such as
+// zero values in tests, tell the time of the operating system.
+//
+// Parameters:
+//   - c: The clock a service was given; may be nil
+//
+// Returns:
+//   - The clock to read the time from
+func Or(c Clock) Clock {
+	if c == nil {
+		return System
+	}
+	return c
+}
@@ -0,0 +1,21 @@
+package clock_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/yasinhessnawi1/Hideme_Backend/internal/testsupport/fakeclock"
+	"github.com/yasinhessnawi1/Hideme_Backend/internal/utils/clock"
+)
+
+func TestOr(t *testing.T) {
+	// Without a clock, the system clock tells the time
+	before := time.Now()
+	assert.Equal(t, clock.System, clock.Or(nil))
+	assert.False(t, clock.Or(nil).Now().Before(before))
+
+	fake := fakeclock.New(time.Date(2026, 1, 15, 12, 0, 0, 0, time.UTC))
+	assert.Equal(t, fake, clock.Or(fake))
+}
@@ -0,0 +1,272 @@
+// Package utils provides utility functions and helpers for the application.
+// This file defines the abstraction over email providers.
+//
+// Services compose an EmailMessage and hand it to an EmailSender, so the provider
+// delivering it can be swapped without touching them. SendGrid is used through its
+// HTTP API and any other provider, such as Amazon SES, through SMTP; further
+// providers only need to implement EmailSender.
+package utils
+
+import (
+	"bytes"
+	"encoding/base64"
+	"fmt"
+	"mime"
+	"mime/multipart"
+	"net"
+	"net/mail"
+	"net/smtp"
+	"net/textproto"
+	"strconv"
+	"time"
+
+	"github.com/sendgrid/sendgrid-go"
+	sgmail "github.com/sendgrid/sendgrid-go/helpers/mail"
+)
+
+// EmailMessage is an email to a single recipient, with a plain text and an HTML body.
+type EmailMessage struct {
+	// ToEmail is the address of the recipient
+	ToEmail string
+
+	// ToName is the display name of the recipient
+	ToName string
+
+	// Subject is the subject line
+	Subject string
+
+	// PlainText is the plain text body
+	PlainText string
+
+	// HTML is the HTML body
+	HTML string
+
+	// Attachments are the files attached to the email
+	Attachments []EmailAttachment
+}
+
+// EmailAttachment is a file attached to an email.
+type EmailAttachment struct {
+	// Filename is the name the file is shown with
+	Filename string
+
+	// ContentType is the MIME type of the file
+	ContentType string
+
+	// Content is the content of the file
+	Content []byte
+}
+
+// EmailSender delivers emails through an email provider.
+type EmailSender interface {
+	// Send delivers an email.
+	//
+	// Parameters:
+	//   - message: The email to deliver
+	//
+	// Returns:
+	//   - An error if the provider refused or could not be reached
+	Send(message *EmailMessage) error
+}
+
+// SendGridSender delivers emails through the SendGrid HTTP API.
+type SendGridSender struct {
+	apiKey    string
+	fromName  string
+	fromEmail string
+}
+
+// NewSendGridSender creates a sender delivering through SendGrid.
+//
+// Parameters:
+//   - apiKey: The SendGrid API key
+//   - fromName: The display name emails are sent from
+//   - fromEmail: The address emails are sent from
+//
+// Returns:
+//   - A new SendGridSender
+func NewSendGridSender(apiKey, fromName, fromEmail string) *SendGridSender {
+	return &SendGridSender{apiKey: apiKey, fromName: fromName, fromEmail: fromEmail}
+}
+
+// Send delivers an email through SendGrid.
+// SendGrid answers refused emails with an error status rather than a transport
+// error, so statuses from 400 up are returned as errors too.
+func (s *SendGridSender) Send(message *EmailMessage) error {
+	from := sgmail.NewEmail(s.fromName, s.fromEmail)
+	to := sgmail.NewEmail(message.ToName, message.ToEmail)
+	email := sgmail.NewSingleEmail(from, message.Subject, to, message.PlainText, message.HTML)
+	for _, attachment := range message.Attachments {
+		email.AddAttachment(sgmail.NewAttachment().
+			SetContent(base64.StdEncoding.EncodeToString(attachment.Content)).
+			SetType(attachment.ContentType).
+			SetFilename(attachment.Filename).
+			SetDisposition("attachment"))
+	}
+
+	response, err := sendgrid.NewSendClient(s.apiKey).Send(email)
+	if err != nil {
+		return fmt.Errorf("failed to send email through SendGrid: %w", err)
+	}
+	if response.StatusCode >= 400 {
+		return fmt.Errorf("SendGrid refused the email with status %d", response.StatusCode)
+	}
+	return nil
+}
+
+// SMTPSender delivers emails through an SMTP server, such as the SMTP interface of
+// Amazon SES. Servers announcing STARTTLS are only used over TLS.
+type SMTPSender struct {
+	host      string
+	port      int
+	username  string
+	password  string
+	fromName  string
+	fromEmail string
+}
+
+// NewSMTPSender creates a sender delivering through an SMTP server.
+//
+// Parameters:
+//   - host: The host name of the SMTP server
+//   - port: The port of the SMTP server, usually 587
+//   - username: The username to authenticate with; empty to send without authentication
+//   - password: The password to authenticate with
+//   - fromName: The display name emails are sent from
+//   - fromEmail: The address emails are sent from
+//
+// Returns:
+//   - A new SMTPSender
+func NewSMTPSender(host string, port int, username, password, fromName, fromEmail string) *SMTPSender {
+	return &SMTPSender{
+		host:      host,
+		port:      port,
+		username:  username,
+		password:  password,
+		fromName:  fromName,
+		fromEmail: fromEmail,
+	}
+}
+
+// Send delivers an email through the SMTP server.
+func (s *SMTPSender) Send(message *EmailMessage) error {
+	body, err := s.Compose(message)
+	if err != nil {
+		return err
+	}
+
+	var auth smtp.Auth
+	if s.username != "" {
+		auth = smtp.PlainAuth("", s.username, s.password, s.host)
+	}
+
+	addr := net.JoinHostPort(s.host, strconv.Itoa(s.port))
+	if err := smtp.SendMail(addr, auth, s.fromEmail, []string{message.ToEmail}, body); err != nil {
+		return fmt.Errorf("failed to send email through SMTP: %w", err)
+	}
+	return nil
+}
+
+// Compose builds the MIME representation of an email as it is sent over SMTP:
+// a multipart/alternative body with the plain text and HTML versions, wrapped in
+// a multipart/mixed body when there are attachments.
+//
+// Parameters:
+//   - message: The email to compose
+//
+// Returns:
+//   - The headers and body of the email
+//   - An error if the recipient address is invalid
+func (s *SMTPSender) Compose(message *EmailMessage) ([]byte, error) {
+	to, err := mail.ParseAddress(message.ToEmail)
+	if err != nil {
+		return nil, fmt.Errorf("invalid recipient address: %w", err)
+	}
+	to.Name = message.ToName
+	from := mail.Address{Name: s.fromName, Address: s.fromEmail}
+
+	var buf bytes.Buffer
+	fmt.Fprintf(&buf, "From: %s\r\n", from.String())
+	fmt.Fprintf(&buf, "To: %s\r\n", to.String())
+	fmt.Fprintf(&buf, "Subject: %s\r\n", mime.QEncoding.Encode("utf-8", message.Subject))
+	fmt.Fprintf(&buf, "Date: %s\r\n", time.Now().Format(time.RFC1123Z))
+	buf.WriteString("MIME-Version: 1.0\r\n")
+
+	alternative := func(w *multipart.Writer) error {
+		for _, part := range []struct{ contentType, content string }{
+			{"text/plain; charset=utf-8", message.PlainText},
+			{"text/html; charset=utf-8", message.HTML},
+		} {
+			pw, err := w.CreatePart(textproto.MIMEHeader{
+				"Content-Type":              {part.contentType},
+				"Content-Transfer-Encoding": {"base64"},
+			})
+			if err != nil {
+				return err
+			}
+			if _, err := pw.Write(encodeBase64Lines([]byte(part.content))); err != nil {
+				return err
+			}
+		}
+		return w.Close()
+	}
+
+	if len(message.Attachments) == 0 {
+		w := multipart.NewWriter(&buf)
+		fmt.Fprintf(&buf, "Content-Type: multipart/alternative; boundary=%s\r\n\r\n", w.Boundary())
+		if err := alternative(w); err != nil {
+			return nil, fmt.Errorf("failed to compose email: %w", err)
+		}
+		return buf.Bytes(), nil
+	}
+
+	mixed := multipart.NewWriter(&buf)
+	fmt.Fprintf(&buf, "Content-Type: multipart/mixed; boundary=%s\r\n\r\n", mixed.Boundary())
+
+	var bodies bytes.Buffer
+	inner := multipart.NewWriter(&bodies)
+	if err := alternative(inner); err != nil {
+		return nil, fmt.Errorf("failed to compose email: %w", err)
+	}
+	pw, err := mixed.CreatePart(textproto.MIMEHeader{
+		"Content-Type": {"multipart/alternative; boundary=" + inner.Boundary()},
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to compose email: %w", err)
+	}
+	if _, err := pw.Write(bodies.Bytes()); err != nil {
+		return nil, fmt.Errorf("failed to compose email: %w", err)
+	}
+
+	for _, attachment := range message.Attachments {
+		pw, err := mixed.CreatePart(textproto.MIMEHeader{
+			"Content-Type":              {attachment.ContentType},
+			"Content-Transfer-Encoding": {"base64"},
+			"Content-Disposition":       {mime.FormatMediaType("attachment", map[string]string{"filename": attachment.Filename})},
+		})
+		if err != nil {
+			return nil, fmt.Errorf("failed to compose email: %w", err)
+		}
+		if _, err := pw.Write(encodeBase64Lines(attachment.Content)); err != nil {
+			return nil, fmt.Errorf("failed to compose email: %w", err)
+		}
+	}
+	if err := mixed.Close(); err != nil {
+		return nil, fmt.Errorf("failed to compose email: %w", err)
+	}
+
+	return buf.Bytes(), nil
+}
+
+// encodeBase64Lines base64-encodes content in lines of 76 characters, as MIME requires.
+func encodeBase64Lines(content []byte) []byte {
+	encoded := base64.StdEncoding.EncodeToString(content)
+	var buf bytes.Buffer
+	for len(encoded) > 76 {
+		buf.WriteString(encoded[:76])
+		buf.WriteString("\r\n")
+		encoded = encoded[76:]
+	}
+	buf.WriteString(encoded)
+	return buf.Bytes()
+}
@@ -0,0 +1,88 @@
+package utils_test
+
+import (
+	"bytes"
+	"io"
+	"mime"
+	"mime/multipart"
+	"net/mail"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/yasinhessnawi1/Hideme_Backend/internal/utils"
+)
+
+// readParts decodes the parts of a multipart body, keyed by content type
+func readParts(t *testing.T, body io.Reader, boundary string) map[string]*multipart.Part {
+	t.Helper()
+	parts := make(map[string]*multipart.Part)
+	reader := multipart.NewReader(body, boundary)
+	for {
+		part, err := reader.NextPart()
+		if err == io.EOF {
+			return parts
+		}
+		require.NoError(t, err)
+		mediaType, _, err := mime.ParseMediaType(part.Header.Get("Content-Type"))
+		require.NoError(t, err)
+		parts[mediaType] = part
+	}
+}
+
+func TestSMTPSender_Compose(t *testing.T) {
+	sender := utils.NewSMTPSender("smtp.example.com", 587, "", "", "HideMe Support", "support@hidemeai.com")
+
+	t.Run("Plain text and HTML", func(t *testing.T) {
+		composed, err := sender.Compose(&utils.EmailMessage{
+			ToEmail:   "ola@example.com",
+			ToName:    "Ola Nordmann",
+			Subject:   "Tilbakestill passord",
+			PlainText: "Reset your password",
+			HTML:      "<strong>Reset your password</strong>",
+		})
+		require.NoError(t, err)
+
+		message, err := mail.ReadMessage(bytes.NewReader(composed))
+		require.NoError(t, err)
+		assert.Equal(t, `"Ola Nordmann" <ola@example.com>`, message.Header.Get("To"))
+		assert.Equal(t, `"HideMe Support" <support@hidemeai.com>`, message.Header.Get("From"))
+
+		mediaType, params, err := mime.ParseMediaType(message.Header.Get("Content-Type"))
+		require.NoError(t, err)
+		assert.Equal(t, "multipart/alternative", mediaType)
+
+		parts := readParts(t, message.Body, params["boundary"])
+		assert.Contains(t, parts, "text/plain")
+		assert.Contains(t, parts, "text/html")
+	})
+
+	t.Run("Attachment", func(t *testing.T) {
+		composed, err := sender.Compose(&utils.EmailMessage{
+			ToEmail:     "ola@example.com",
+			Subject:     "Weekly report",
+			PlainText:   "See attached",
+			HTML:        "<p>See attached</p>",
+			Attachments: []utils.EmailAttachment{{Filename: "report.csv", ContentType: "text/csv", Content: []byte("a,b\n")}},
+		})
+		require.NoError(t, err)
+
+		message, err := mail.ReadMessage(bytes.NewReader(composed))
+		require.NoError(t, err)
+		mediaType, params, err := mime.ParseMediaType(message.Header.Get("Content-Type"))
+		require.NoError(t, err)
+		assert.Equal(t, "multipart/mixed", mediaType)
+
+		parts := readParts(t, message.Body, params["boundary"])
+		assert.Contains(t, parts, "multipart/alternative")
+		if assert.Contains(t, parts, "text/csv") {
+			assert.Equal(t, "report.csv", parts["text/csv"].FileName())
+		}
+	})
+
+	t.Run("Invalid recipient", func(t *testing.T) {
+		_, err := sender.Compose(&utils.EmailMessage{ToEmail: "not an address"})
+		assert.Error(t, err)
+	})
+}
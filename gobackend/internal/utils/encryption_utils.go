@@ -5,6 +5,7 @@ import (
 	"crypto/cipher"
 	"crypto/rand"
 	"encoding/base64"
+	"encoding/binary"
 	"errors"
 	"fmt"
 	"io"
@@ -21,23 +22,11 @@ import (
 //   - The base64-encoded encrypted  key
 //   - An error if encryption fails
 func EncryptKey(key string, encryptionKey []byte) (string, error) {
-	block, err := aes.NewCipher(encryptionKey[:32])
+	ciphertext, err := EncryptBytes([]byte(key), encryptionKey)
 	if err != nil {
-		return "", fmt.Errorf("failed to create cipher: %w", err)
+		return "", err
 	}
 
-	gcm, err := cipher.NewGCM(block)
-	if err != nil {
-		return "", fmt.Errorf("failed to create GCM: %w", err)
-	}
-
-	nonce := make([]byte, gcm.NonceSize())
-	if _, err = io.ReadFull(rand.Reader, nonce); err != nil {
-		return "", fmt.Errorf("failed to create nonce: %w", err)
-	}
-
-	ciphertext := gcm.Seal(nonce, nonce, []byte(key), nil)
-
 	return base64.StdEncoding.EncodeToString(ciphertext), nil
 }
 
@@ -56,27 +45,216 @@ func DecryptKey(encryptedKey string, encryptionKey []byte) (string, error) {
 		return "", fmt.Errorf("failed to decode base64: %w", err)
 	}
 
-	block, err := aes.NewCipher(encryptionKey[:32])
+	plaintext, err := DecryptBytes(ciphertext, encryptionKey)
 	if err != nil {
-		return "", fmt.Errorf("failed to create cipher: %w", err)
+		return "", err
 	}
 
-	gcm, err := cipher.NewGCM(block)
+	return string(plaintext), nil
+}
+
+// EncryptBytes encrypts binary data, such as file contents, using AES-256-GCM.
+//
+// Parameters:
+//   - plaintext: The data to encrypt
+//   - encryptionKey: The key to use for encryption (must be at least 32 bytes)
+//
+// Returns:
+//   - The nonce followed by the sealed data
+//   - An error if encryption fails
+func EncryptBytes(plaintext []byte, encryptionKey []byte) ([]byte, error) {
+	gcm, err := newGCM(encryptionKey)
+	if err != nil {
+		return nil, err
+	}
+
+	nonce := make([]byte, gcm.NonceSize())
+	if _, err = io.ReadFull(rand.Reader, nonce); err != nil {
+		return nil, fmt.Errorf("failed to create nonce: %w", err)
+	}
+
+	return gcm.Seal(nonce, nonce, plaintext, nil), nil
+}
+
+// DecryptBytes decrypts binary data that was encrypted with EncryptBytes.
+//
+// Parameters:
+//   - ciphertext: The nonce followed by the sealed data
+//   - encryptionKey: The key used for encryption (must be at least 32 bytes)
+//
+// Returns:
+//   - The decrypted data
+//   - An error if decryption fails, including when the data was altered
+func DecryptBytes(ciphertext []byte, encryptionKey []byte) ([]byte, error) {
+	gcm, err := newGCM(encryptionKey)
 	if err != nil {
-		return "", fmt.Errorf("failed to create GCM: %w", err)
+		return nil, err
 	}
 
 	nonceSize := gcm.NonceSize()
 	if len(ciphertext) < nonceSize {
-		return "", errors.New("ciphertext too short")
+		return nil, errors.New("ciphertext too short")
 	}
 
 	nonce, ciphertext := ciphertext[:nonceSize], ciphertext[nonceSize:]
 
 	plaintext, err := gcm.Open(nil, nonce, ciphertext, nil)
 	if err != nil {
-		return "", fmt.Errorf("failed to decrypt: %w", err)
+		return nil, fmt.Errorf("failed to decrypt: %w", err)
 	}
 
-	return string(plaintext), nil
+	return plaintext, nil
+}
+
+// SegmentHeaderSize is the size of the header of data encrypted with EncryptSegments: the
+// random prefix of the nonces of its segments.
+const SegmentHeaderSize = 8
+
+// SegmentOverhead is the number of bytes each segment grows by when sealed: the GCM tag.
+const SegmentOverhead = 16
+
+// EncryptSegments encrypts binary data in segments, each sealed with AES-256-GCM on its
+// own, so any part of the data can be read and decrypted without the rest. The header is
+// followed by the sealed segments; a segment's nonce is the header followed by its index,
+// and the last segment is marked, so segments can be neither reordered nor cut off.
+//
+// Parameters:
+//   - plaintext: The data to encrypt
+//   - encryptionKey: The key to use for encryption (must be at least 32 bytes)
+//   - segmentSize: The size of the segments; the last one may be shorter
+//
+// Returns:
+//   - The header followed by the sealed segments
+//   - An error if encryption fails
+func EncryptSegments(plaintext []byte, encryptionKey []byte, segmentSize int) ([]byte, error) {
+	if segmentSize <= 0 {
+		return nil, errors.New("segment size must be positive")
+	}
+	gcm, err := newGCM(encryptionKey)
+	if err != nil {
+		return nil, err
+	}
+
+	header := make([]byte, SegmentHeaderSize)
+	if _, err = io.ReadFull(rand.Reader, header); err != nil {
+		return nil, fmt.Errorf("failed to create nonce: %w", err)
+	}
+
+	segments := SegmentCount(int64(len(plaintext)), segmentSize)
+	sealed := make([]byte, 0, SegmentHeaderSize+len(plaintext)+int(segments)*gcm.Overhead())
+	sealed = append(sealed, header...)
+	for index := int64(0); index < segments; index++ {
+		start := index * int64(segmentSize)
+		end := min(start+int64(segmentSize), int64(len(plaintext)))
+		last := index == segments-1
+		sealed = gcm.Seal(sealed, segmentNonce(header, index), plaintext[start:end], segmentAdditionalData(last))
+	}
+
+	return sealed, nil
+}
+
+// DecryptSegment decrypts one segment of data encrypted with EncryptSegments.
+//
+// Parameters:
+//   - header: The header of the data
+//   - segment: The sealed segment
+//   - index: The index of the segment, counting from 0
+//   - last: Whether the segment is the last one
+//   - encryptionKey: The key used for encryption (must be at least 32 bytes)
+//
+// Returns:
+//   - The decrypted segment
+//   - An error if decryption fails, including when the segment was altered or moved
+func DecryptSegment(header, segment []byte, index int64, last bool, encryptionKey []byte) ([]byte, error) {
+	if len(header) != SegmentHeaderSize {
+		return nil, errors.New("invalid segment header")
+	}
+	gcm, err := newGCM(encryptionKey)
+	if err != nil {
+		return nil, err
+	}
+
+	plaintext, err := gcm.Open(nil, segmentNonce(header, index), segment, segmentAdditionalData(last))
+	if err != nil {
+		return nil, fmt.Errorf("failed to decrypt segment %d: %w", index, err)
+	}
+	return plaintext, nil
+}
+
+// DecryptSegments decrypts all of the data encrypted with EncryptSegments.
+//
+// Parameters:
+//   - sealed: The header followed by the sealed segments
+//   - encryptionKey: The key used for encryption (must be at least 32 bytes)
+//   - segmentSize: The size of the segments the data was encrypted in
+//
+// Returns:
+//   - The decrypted data
+//   - An error if decryption fails, including when the data was altered
+func DecryptSegments(sealed []byte, encryptionKey []byte, segmentSize int) ([]byte, error) {
+	if segmentSize <= 0 || len(sealed) < SegmentHeaderSize+SegmentOverhead {
+		return nil, errors.New("ciphertext too short")
+	}
+	header, sealed := sealed[:SegmentHeaderSize], sealed[SegmentHeaderSize:]
+
+	sealedSize := segmentSize + SegmentOverhead
+	plaintext := make([]byte, 0, len(sealed))
+	for index := int64(0); len(sealed) > 0; index++ {
+		n := min(sealedSize, len(sealed))
+		segment, err := DecryptSegment(header, sealed[:n], index, n == len(sealed), encryptionKey)
+		if err != nil {
+			return nil, err
+		}
+		plaintext = append(plaintext, segment...)
+		sealed = sealed[n:]
+	}
+
+	return plaintext, nil
+}
+
+// SegmentCount returns the number of segments data is encrypted in by EncryptSegments.
+// Empty data is one empty segment, so its end is marked too.
+//
+// Parameters:
+//   - size: The size of the data
+//   - segmentSize: The size of the segments
+//
+// Returns:
+//   - The number of segments
+func SegmentCount(size int64, segmentSize int) int64 {
+	if size == 0 {
+		return 1
+	}
+	return (size + int64(segmentSize) - 1) / int64(segmentSize)
+}
+
+// segmentNonce returns the nonce of a segment: the header followed by the segment's index.
+func segmentNonce(header []byte, index int64) []byte {
+	nonce := make([]byte, SegmentHeaderSize+4)
+	copy(nonce, header)
+	binary.BigEndian.PutUint32(nonce[SegmentHeaderSize:], uint32(index))
+	return nonce
+}
+
+// segmentAdditionalData marks whether a segment is the last one.
+func segmentAdditionalData(last bool) []byte {
+	if last {
+		return []byte{1}
+	}
+	return []byte{0}
+}
+
+// newGCM creates the AES-256-GCM cipher of a key.
+func newGCM(encryptionKey []byte) (cipher.AEAD, error) {
+	block, err := aes.NewCipher(encryptionKey[:32])
+	if err != nil {
+		return nil, fmt.Errorf("failed to create cipher: %w", err)
+	}
+
+	gcm, err := cipher.NewGCM(block)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create GCM: %w", err)
+	}
+
+	return gcm, nil
 }
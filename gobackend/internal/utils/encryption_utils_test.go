@@ -219,3 +219,63 @@ func TestEncryptionRoundTrip(t *testing.T) {
 		assert.Equal(t, originalKey, decryptedKey)
 	})
 }
+
+func TestEncryptBytes(t *testing.T) {
+	encryptionKey := bytes.Repeat([]byte("a"), 32)
+	content := []byte("%PDF-1.7\x00\xff binary content")
+
+	ciphertext, err := EncryptBytes(content, encryptionKey)
+	require.NoError(t, err)
+	assert.False(t, bytes.Contains(ciphertext, content))
+
+	plaintext, err := DecryptBytes(ciphertext, encryptionKey)
+	require.NoError(t, err)
+	assert.Equal(t, content, plaintext)
+
+	// Altered ciphertexts are refused
+	ciphertext[len(ciphertext)-1] ^= 1
+	_, err = DecryptBytes(ciphertext, encryptionKey)
+	assert.Error(t, err)
+
+	_, err = DecryptBytes(ciphertext, bytes.Repeat([]byte("b"), 32))
+	assert.Error(t, err)
+}
+
+func TestEncryptSegments(t *testing.T) {
+	encryptionKey := bytes.Repeat([]byte("a"), 32)
+	content := []byte("%PDF-1.7\x00\xff binary content of three segments")
+	segmentSize := 16
+
+	sealed, err := EncryptSegments(content, encryptionKey, segmentSize)
+	require.NoError(t, err)
+	assert.Len(t, sealed, SegmentHeaderSize+len(content)+3*SegmentOverhead)
+
+	plaintext, err := DecryptSegments(sealed, encryptionKey, segmentSize)
+	require.NoError(t, err)
+	assert.Equal(t, content, plaintext)
+
+	// The second segment decrypts on its own
+	header := sealed[:SegmentHeaderSize]
+	start := SegmentHeaderSize + segmentSize + SegmentOverhead
+	segment, err := DecryptSegment(header, sealed[start:start+segmentSize+SegmentOverhead], 1, false, encryptionKey)
+	require.NoError(t, err)
+	assert.Equal(t, content[16:32], segment)
+
+	t.Run("Moved segment", func(t *testing.T) {
+		_, err := DecryptSegment(header, sealed[start:start+segmentSize+SegmentOverhead], 0, false, encryptionKey)
+		assert.Error(t, err)
+	})
+
+	t.Run("Cut off", func(t *testing.T) {
+		_, err := DecryptSegments(sealed[:SegmentHeaderSize+2*(segmentSize+SegmentOverhead)], encryptionKey, segmentSize)
+		assert.Error(t, err)
+	})
+
+	t.Run("Empty", func(t *testing.T) {
+		sealed, err := EncryptSegments(nil, encryptionKey, segmentSize)
+		require.NoError(t, err)
+		plaintext, err := DecryptSegments(sealed, encryptionKey, segmentSize)
+		require.NoError(t, err)
+		assert.Empty(t, plaintext)
+	})
+}
@@ -5,7 +5,9 @@
 //
 // The error system includes:
 //   - Custom error types with specific semantic meanings
-//   - The AppError type which provides HTTP status codes, user messages and developer info
+//   - Kind errors, which give package-level errors such as a missing token one of those meanings
+//   - The AppError type which provides HTTP status codes, user messages and developer info,
+//     and keeps the error that caused it for errors.Is and errors.As
 //   - Helper functions to create different types of errors
 //   - Utility functions to check error types and extract status codes
 //
@@ -14,6 +16,8 @@
 package utils
 
 import (
+	"context"
+	"database/sql"
 	"errors"
 	"fmt"
 	"net/http"
@@ -57,13 +61,60 @@ var (
 
 	// ErrInvalidToken indicates a token is invalid
 	ErrInvalidToken = errors.New(constants.ErrorInvalidToken)
+
+	// ErrQuotaExceeded indicates a usage quota has been used up
+	ErrQuotaExceeded = errors.New(constants.ErrorQuotaExceeded)
+
+	// ErrUploadLimit indicates an upload would go over a document, page or size limit
+	ErrUploadLimit = errors.New(constants.ErrorUploadLimit)
+
+	// ErrUploadIntegrity indicates an uploaded file doesn't match its declared size or checksum
+	ErrUploadIntegrity = errors.New(constants.ErrorUploadIntegrity)
+
+	// ErrRequestCanceled indicates the client went away before the request completed
+	ErrRequestCanceled = errors.New(constants.ErrorRequestCanceled)
 )
 
+// kindError is an error of one of the semantic kinds above.
+type kindError struct {
+	kind    error
+	message string
+}
+
+// Error returns the message of the error.
+func (e *kindError) Error() string {
+	return e.message
+}
+
+// Unwrap returns the kind of the error.
+func (e *kindError) Unwrap() error {
+	return e.kind
+}
+
+// NewKindError creates an error of one of the semantic kinds above, for packages
+// that define their own errors. Callers can test for the error itself or for its
+// kind with errors.Is, and ParseError maps it to the response of its kind.
+//
+// Parameters:
+//   - kind: The semantic kind of the error, such as ErrNotFound
+//   - message: The error message
+//
+// Returns:
+//   - A new error that wraps kind
+func NewKindError(kind error, message string) error {
+	return &kindError{kind: kind, message: message}
+}
+
 // AppError represents an application error with additional context.
 // It wraps an underlying error with HTTP-specific context like status codes
 // and user-friendly messages, making it easier to translate errors to HTTP responses.
+//
+// Err holds the semantic kind of the error and Cause the error it was created from,
+// if any; errors.Is and errors.As match either, so wrapping a database or driver
+// error in an AppError does not hide it from callers.
 type AppError struct {
 	Err        error          // The underlying error
+	Cause      error          // The error this error was created from, if any
 	StatusCode int            // HTTP status code
 	Message    string         // User-friendly error message
 	DevInfo    string         // Additional information for developers
@@ -92,6 +143,42 @@ func (e *AppError) Unwrap() error {
 	return e.Err
 }
 
+// Is reports whether the cause of the error matches target.
+// errors.Is checks the underlying error through Unwrap.
+//
+// Parameters:
+//   - target: The error to match
+//
+// Returns:
+//   - true if the cause matches target
+func (e *AppError) Is(target error) bool {
+	return e.Cause != nil && errors.Is(e.Cause, target)
+}
+
+// As finds the first error in the cause that matches target.
+// errors.As checks the underlying error through Unwrap.
+//
+// Parameters:
+//   - target: A pointer to the type of error to find
+//
+// Returns:
+//   - true if an error in the cause was assigned to target
+func (e *AppError) As(target any) bool {
+	return e.Cause != nil && errors.As(e.Cause, target)
+}
+
+// WithCause records the error this error was created from.
+//
+// Parameters:
+//   - cause: The original error, such as a database error
+//
+// Returns:
+//   - The AppError, for chaining onto a constructor
+func (e *AppError) WithCause(cause error) *AppError {
+	e.Cause = cause
+	return e
+}
+
 // New creates a new AppError with the given error and status code.
 //
 // Parameters:
@@ -229,6 +316,7 @@ func NewInternalServerError(err error) *AppError {
 	}
 	return &AppError{
 		Err:        ErrInternalServer,
+		Cause:      err,
 		StatusCode: http.StatusInternalServerError,
 		Message:    constants.MsgInternalServerError,
 		DevInfo:    devInfo,
@@ -289,9 +377,86 @@ func NewInvalidTokenError() *AppError {
 	}
 }
 
+// NewQuotaExceededError creates a new quota exceeded error.
+//
+// Parameters:
+//   - remaining: The number of units still available
+//
+// Returns:
+//   - A new AppError instance for a quota exceeded error, with the remaining units in its details
+func NewQuotaExceededError(remaining int64) *AppError {
+	return &AppError{
+		Err:        ErrQuotaExceeded,
+		StatusCode: http.StatusTooManyRequests,
+		Message:    constants.MsgQuotaExceeded,
+		Details:    map[string]any{"remaining": remaining},
+	}
+}
+
+// NewUploadLimitError creates a new error for an upload refused before processing.
+// Size and page limits answer with 413, since a smaller upload would be accepted;
+// the document limit answers with 403 until documents are deleted.
+//
+// Parameters:
+//   - limit: The limit that was hit, one of the constants.UploadLimit* values
+//   - current: The documents stored, or the pages or bytes of the upload
+//   - maximum: The configured maximum
+//
+// Returns:
+//   - A new AppError instance naming the limit, with the usage and maximum in its details
+func NewUploadLimitError(limit string, current, maximum int64) *AppError {
+	status := http.StatusRequestEntityTooLarge
+	if limit == constants.UploadLimitDocuments {
+		status = http.StatusForbidden
+	}
+	return &AppError{
+		Err:        ErrUploadLimit,
+		StatusCode: status,
+		Message:    fmt.Sprintf(constants.MsgUploadLimitExceeded, limit),
+		Details:    map[string]any{"limit": limit, "current": current, "maximum": maximum},
+	}
+}
+
+// NewUploadIntegrityError creates a new error for an uploaded file that doesn't match the
+// size or SHA-256 its client declared, which means it was corrupted or truncated on the way.
+// It answers with 422, since the request was well-formed but its file cannot be trusted.
+//
+// Parameters:
+//   - check: The check that failed, one of the constants.UploadIntegrity* values
+//   - declared: The value the client declared
+//   - actual: The value of the file the server received
+//
+// Returns:
+//   - A new AppError instance naming the check, with both values in its details
+func NewUploadIntegrityError(check string, declared, actual interface{}) *AppError {
+	return &AppError{
+		Err:        ErrUploadIntegrity,
+		StatusCode: http.StatusUnprocessableEntity,
+		Message:    fmt.Sprintf(constants.MsgUploadIntegrityFailed, check),
+		Details:    map[string]any{"check": check, "declared": declared, "actual": actual},
+	}
+}
+
+// NewRequestCanceledError creates a new error for a request abandoned by its client.
+// Work stopped because the request context was canceled ends with this error, which
+// is not logged as a failure since nothing went wrong on the server.
+//
+// Returns:
+//   - A new AppError instance for a canceled request
+func NewRequestCanceledError() *AppError {
+	return &AppError{
+		Err:        ErrRequestCanceled,
+		StatusCode: constants.StatusClientClosedRequest,
+		Message:    constants.MsgRequestCanceled,
+	}
+}
+
 // ParseError attempts to parse various types of errors into an AppError.
 // This function provides a centralized way to convert different error types
 // (standard errors, PostgreSQL errors, etc.) into the application's error format.
+// Errors are matched by the kinds they wrap; the returned AppError keeps the
+// original error as its cause. Matching on the message is a last resort for
+// errors of other libraries that have no type to match.
 //
 // Parameters:
 //   - err: The error to parse
@@ -306,25 +471,31 @@ func ParseError(err error) *AppError {
 	}
 
 	// Check for specific error types
+	var parsed *AppError
 	switch {
-	case errors.Is(err, ErrNotFound):
-		return NewNotFoundError("Resource", "")
+	case errors.Is(err, ErrNotFound), errors.Is(err, sql.ErrNoRows):
+		parsed = NewNotFoundError("Resource", "")
 	case errors.Is(err, ErrUnauthorized):
-		return NewUnauthorizedError("")
+		parsed = NewUnauthorizedError("")
 	case errors.Is(err, ErrForbidden):
-		return NewForbiddenError("")
+		parsed = NewForbiddenError("")
 	case errors.Is(err, ErrBadRequest):
-		return NewBadRequestError(err.Error())
+		parsed = NewBadRequestError(err.Error())
 	case errors.Is(err, ErrValidation):
-		return NewValidationError("", err.Error())
+		parsed = NewValidationError("", err.Error())
 	case errors.Is(err, ErrDuplicate):
-		return NewDuplicateError("Resource", "", "")
+		parsed = NewDuplicateError("Resource", "", "")
 	case errors.Is(err, ErrInvalidCredentials):
-		return NewInvalidCredentialsError()
+		parsed = NewInvalidCredentialsError()
 	case errors.Is(err, ErrExpiredToken):
-		return NewExpiredTokenError()
+		parsed = NewExpiredTokenError()
 	case errors.Is(err, ErrInvalidToken):
-		return NewInvalidTokenError()
+		parsed = NewInvalidTokenError()
+	case errors.Is(err, context.Canceled):
+		parsed = NewRequestCanceledError()
+	}
+	if parsed != nil {
+		return parsed.WithCause(err)
 	}
 
 	// Check for PostgreSQL-specific errors
@@ -351,6 +522,7 @@ func ParseError(err error) *AppError {
 			}
 			return &AppError{
 				Err:        ErrDuplicate,
+				Cause:      err,
 				StatusCode: http.StatusConflict,
 				Message:    constants.MsgResourceAlreadyExists,
 				DevInfo:    pqErr.Error(),
@@ -359,6 +531,7 @@ func ParseError(err error) *AppError {
 		case constants.PGErrorForeignKeyConstraint: // foreign_key_violation
 			return &AppError{
 				Err:        ErrBadRequest,
+				Cause:      err,
 				StatusCode: http.StatusBadRequest,
 				Message:    "This operation violates a foreign key constraint",
 				DevInfo:    pqErr.Error(),
@@ -367,6 +540,7 @@ func ParseError(err error) *AppError {
 			field := pqErr.Column
 			return &AppError{
 				Err:        ErrValidation,
+				Cause:      err,
 				StatusCode: http.StatusBadRequest,
 				Message:    fmt.Sprintf("The %s field cannot be empty", field),
 				DevInfo:    pqErr.Error(),
@@ -375,7 +549,7 @@ func ParseError(err error) *AppError {
 		}
 	}
 
-	// Check for general database-specific error patterns
+	// Check the messages of errors without a type, such as those of other database drivers
 	errMsg := strings.ToLower(err.Error())
 
 	// Log general errors with minimal context to avoid logging PII
@@ -387,6 +561,7 @@ func ParseError(err error) *AppError {
 	case strings.Contains(errMsg, constants.DBErrorDuplicateKey) || strings.Contains(errMsg, "unique constraint"):
 		return &AppError{
 			Err:        ErrDuplicate,
+			Cause:      err,
 			StatusCode: http.StatusConflict,
 			Message:    constants.MsgResourceAlreadyExists,
 			DevInfo:    err.Error(),
@@ -394,6 +569,7 @@ func ParseError(err error) *AppError {
 	case strings.Contains(errMsg, "not found") || strings.Contains(errMsg, "no rows"):
 		return &AppError{
 			Err:        ErrNotFound,
+			Cause:      err,
 			StatusCode: http.StatusNotFound,
 			Message:    constants.MsgResourceNotFound,
 			DevInfo:    err.Error(),
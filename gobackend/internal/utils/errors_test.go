@@ -1,12 +1,15 @@
 package utils_test
 
 import (
+	"context"
 	"database/sql"
 	"errors"
+	"fmt"
 	"net/http"
 	"testing"
 
 	"github.com/lib/pq"
+	"github.com/yasinhessnawi1/Hideme_Backend/internal/constants"
 	"github.com/yasinhessnawi1/Hideme_Backend/internal/utils"
 )
 
@@ -320,6 +323,22 @@ func TestNewInvalidTokenError(t *testing.T) {
 	}
 }
 
+func TestNewQuotaExceededError(t *testing.T) {
+	appErr := utils.NewQuotaExceededError(3)
+
+	if appErr.StatusCode != http.StatusTooManyRequests {
+		t.Errorf("NewQuotaExceededError().StatusCode = %v, want %v", appErr.StatusCode, http.StatusTooManyRequests)
+	}
+
+	if !errors.Is(appErr.Unwrap(), utils.ErrQuotaExceeded) {
+		t.Errorf("NewQuotaExceededError().Unwrap() = %v, want %v", appErr.Unwrap(), utils.ErrQuotaExceeded)
+	}
+
+	if appErr.Details["remaining"] != int64(3) {
+		t.Errorf("NewQuotaExceededError().Details[remaining] = %v, want %v", appErr.Details["remaining"], 3)
+	}
+}
+
 func TestIsNotFoundError(t *testing.T) {
 	tests := []struct {
 		name string
@@ -471,6 +490,12 @@ func TestParseError(t *testing.T) {
 			wantStatus: http.StatusBadRequest,
 			wantType:   utils.ErrValidation,
 		},
+		{
+			name:       "Canceled request",
+			err:        fmt.Errorf("document stream stopped: %w", context.Canceled),
+			wantStatus: constants.StatusClientClosedRequest,
+			wantType:   utils.ErrRequestCanceled,
+		},
 		{
 			name:       "NotFound error",
 			err:        utils.ErrNotFound,
@@ -599,6 +624,37 @@ func TestParseError(t *testing.T) {
 		}
 	})
 
+	t.Run("Wrapped no rows error", func(t *testing.T) {
+		err := fmt.Errorf("record with ID 7 not found in users: %w", sql.ErrNoRows)
+		appErr := utils.ParseError(err)
+		if appErr.StatusCode != http.StatusNotFound {
+			t.Errorf("ParseError().StatusCode = %v, want %v", appErr.StatusCode, http.StatusNotFound)
+		}
+		if !errors.Is(appErr, sql.ErrNoRows) {
+			t.Error("ParseError() lost the original error")
+		}
+	})
+
+	t.Run("Kind error", func(t *testing.T) {
+		errTokenNotFound := utils.NewKindError(utils.ErrNotFound, "token not found")
+		appErr := utils.ParseError(fmt.Errorf("failed to reset password: %w", errTokenNotFound))
+		if appErr.StatusCode != http.StatusNotFound {
+			t.Errorf("ParseError().StatusCode = %v, want %v", appErr.StatusCode, http.StatusNotFound)
+		}
+		if !errors.Is(appErr, errTokenNotFound) {
+			t.Error("ParseError() lost the original error")
+		}
+	})
+
+	t.Run("PostgreSQL error stays reachable", func(t *testing.T) {
+		appErr := utils.ParseError(fmt.Errorf("failed to create user: %w", &pq.Error{Code: "23505"}))
+
+		var pqErr *pq.Error
+		if !errors.As(appErr, &pqErr) || pqErr.Code != "23505" {
+			t.Error("ParseError() lost the PostgreSQL error")
+		}
+	})
+
 	t.Run("Error with 'duplicate key' in message", func(t *testing.T) {
 		err := errors.New("error: duplicate key value violates unique constraint")
 		appErr := utils.ParseError(err)
@@ -632,6 +688,52 @@ func TestParseError(t *testing.T) {
 	})
 }
 
+func TestAppErrorCause(t *testing.T) {
+	cause := fmt.Errorf("failed to get user: %w", sql.ErrNoRows)
+	appErr := utils.NewNotFoundError("User", 7).WithCause(cause)
+	wrapped := fmt.Errorf("failed to load profile: %w", appErr)
+
+	if !errors.Is(wrapped, utils.ErrNotFound) {
+		t.Error("errors.Is() did not match the kind of the error")
+	}
+	if !errors.Is(wrapped, sql.ErrNoRows) {
+		t.Error("errors.Is() did not match the cause of the error")
+	}
+	if errors.Is(wrapped, utils.ErrDuplicate) {
+		t.Error("errors.Is() matched another kind")
+	}
+
+	var found *utils.AppError
+	if !errors.As(wrapped, &found) || found != appErr {
+		t.Error("errors.As() did not find the AppError")
+	}
+	if appErr.Error() != "User with identifier '7' not found" {
+		t.Errorf("Error() = %v, cause must not change the message", appErr.Error())
+	}
+
+	internal := utils.NewInternalServerError(context.DeadlineExceeded)
+	if !errors.Is(internal, context.DeadlineExceeded) {
+		t.Error("NewInternalServerError() did not keep the error as its cause")
+	}
+}
+
+func TestNewKindError(t *testing.T) {
+	errTokenNotFound := utils.NewKindError(utils.ErrNotFound, "token not found or expired")
+
+	if errTokenNotFound.Error() != "token not found or expired" {
+		t.Errorf("NewKindError().Error() = %v, want %v", errTokenNotFound.Error(), "token not found or expired")
+	}
+	if !errors.Is(errTokenNotFound, utils.ErrNotFound) {
+		t.Error("NewKindError() does not wrap its kind")
+	}
+	if !utils.IsNotFoundError(errTokenNotFound) {
+		t.Error("IsNotFoundError() = false for a not found kind error")
+	}
+	if errors.Is(utils.NewKindError(utils.ErrNotFound, "token not found or expired"), errTokenNotFound) {
+		t.Error("Kind errors with the same message must be distinct")
+	}
+}
+
 func TestNewValidationErrorWithDetails(t *testing.T) {
 	details := map[string]string{
 		"username": "Username is required",
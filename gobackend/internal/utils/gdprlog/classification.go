@@ -0,0 +1,262 @@
+// Package gdprlog provides GDPR-compliant logging functionality.
+// This file implements the classification of model fields through struct tags. A field
+// tagged `gdpr:"personal"` or `gdpr:"sensitive"` is classified in one place, its model,
+// and the classification is used by the log categorization and sanitization, the erasure
+// of a data subject's log entries, document exports and the processing register.
+package gdprlog
+
+import (
+	"fmt"
+	"reflect"
+	"sort"
+	"strings"
+	"sync"
+)
+
+// TagName is the struct tag holding the GDPR category of a model field.
+const TagName = "gdpr"
+
+// String returns the name of the category as used in gdpr struct tags.
+func (c LogCategory) String() string {
+	switch c {
+	case PersonalLog:
+		return "personal"
+	case SensitiveLog:
+		return "sensitive"
+	default:
+		return "standard"
+	}
+}
+
+// MarshalText renders the category by its name, so it reads as such in JSON.
+func (c LogCategory) MarshalText() ([]byte, error) {
+	return []byte(c.String()), nil
+}
+
+// UnmarshalText parses a category from its name, so it round-trips through JSON.
+func (c *LogCategory) UnmarshalText(text []byte) error {
+	category, err := ParseCategory(string(text))
+	if err != nil {
+		return err
+	}
+	*c = category
+	return nil
+}
+
+// ParseCategory parses the value of a gdpr struct tag.
+//
+// Parameters:
+//   - s: standard, personal or sensitive
+//
+// Returns:
+//   - The category
+//   - An error if the value is not a category
+func ParseCategory(s string) (LogCategory, error) {
+	switch s {
+	case "standard":
+		return StandardLog, nil
+	case "personal":
+		return PersonalLog, nil
+	case "sensitive":
+		return SensitiveLog, nil
+	default:
+		return StandardLog, fmt.Errorf("unknown GDPR category %q", s)
+	}
+}
+
+// FieldClassification is the GDPR category of one field of a model.
+type FieldClassification struct {
+	// Field is the JSON name of the field, or its column name if it is never serialized
+	Field string `json:"field"`
+
+	// Category is the GDPR category of the field
+	Category LogCategory `json:"category"`
+}
+
+// ModelClassification lists the classified fields of a model.
+type ModelClassification struct {
+	// Model is the name of the model type
+	Model string `json:"model"`
+
+	// Table is the database table of the model; empty if it isn't stored in its own table
+	Table string `json:"table,omitempty"`
+
+	// Fields are the fields with a gdpr tag, in declaration order
+	Fields []FieldClassification `json:"fields"`
+}
+
+// tableNamer is implemented by models stored in their own table.
+type tableNamer interface {
+	TableName() string
+}
+
+// registry holds the classification of the registered models.
+var registry = struct {
+	sync.RWMutex
+	models map[string]ModelClassification
+	fields map[string]LogCategory
+}{
+	models: make(map[string]ModelClassification),
+	fields: make(map[string]LogCategory),
+}
+
+// RegisterModels adds the classification of models to the registry. Field names are
+// classified by the highest category any registered model gives them.
+// It panics on a gdpr tag that is not a category, as that is a programming error.
+//
+// Parameters:
+//   - models: Values or pointers of the model types to register
+func RegisterModels(models ...interface{}) {
+	registry.Lock()
+	defer registry.Unlock()
+
+	for _, model := range models {
+		classification := ClassifyModel(model)
+		registry.models[classification.Model] = classification
+		for _, field := range classification.Fields {
+			if current, ok := registry.fields[field.Field]; !ok || field.Category > current {
+				registry.fields[field.Field] = field.Category
+			}
+		}
+	}
+}
+
+// RegisteredModels returns the classification of all registered models, ordered by name.
+// It is the processing register: which personal and sensitive data the application stores.
+//
+// Returns:
+//   - The classified models
+func RegisteredModels() []ModelClassification {
+	registry.RLock()
+	defer registry.RUnlock()
+
+	models := make([]ModelClassification, 0, len(registry.models))
+	for _, model := range registry.models {
+		models = append(models, model)
+	}
+	sort.Slice(models, func(i, j int) bool { return models[i].Model < models[j].Model })
+	return models
+}
+
+// FieldCategory looks up the category of a field name in the registered models.
+//
+// Parameters:
+//   - name: The JSON or column name of the field
+//
+// Returns:
+//   - The highest category a registered model gives the field
+//   - false if no registered model classifies the field
+func FieldCategory(name string) (LogCategory, bool) {
+	registry.RLock()
+	defer registry.RUnlock()
+
+	category, ok := registry.fields[strings.ToLower(name)]
+	return category, ok
+}
+
+// ClassifyModel reads the gdpr tags of a model's fields.
+// It panics on a gdpr tag that is not a category.
+//
+// Parameters:
+//   - model: A value or pointer of the model type
+//
+// Returns:
+//   - The classified fields of the model
+func ClassifyModel(model interface{}) ModelClassification {
+	t := reflect.TypeOf(model)
+	for t.Kind() == reflect.Pointer {
+		t = t.Elem()
+	}
+
+	classification := ModelClassification{Model: t.Name(), Fields: []FieldClassification{}}
+	if namer, ok := reflect.New(t).Interface().(tableNamer); ok {
+		classification.Table = namer.TableName()
+	}
+
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		category, ok := fieldCategory(field)
+		if !ok {
+			continue
+		}
+		classification.Fields = append(classification.Fields, FieldClassification{Field: fieldName(field), Category: category})
+	}
+
+	return classification
+}
+
+// CategorizedPaths lists the classified fields reachable from a type, by their JSON path,
+// following nested structs, pointers, slices and maps. Elements of slices are marked with
+// "[]", so the detected texts of a document export are "redaction_schema.pages[].sensitive[].original_text".
+//
+// Parameters:
+//   - v: A value or pointer of the type
+//
+// Returns:
+//   - The personal and sensitive fields by path; standard fields are left out
+func CategorizedPaths(v interface{}) map[string]LogCategory {
+	paths := make(map[string]LogCategory)
+	collectPaths(reflect.TypeOf(v), "", paths, make(map[reflect.Type]bool))
+	return paths
+}
+
+// collectPaths adds the classified fields of a type under a path prefix.
+// Types already on the path are skipped, so recursive types terminate.
+func collectPaths(t reflect.Type, prefix string, paths map[string]LogCategory, visiting map[reflect.Type]bool) {
+	for t.Kind() == reflect.Pointer || t.Kind() == reflect.Slice || t.Kind() == reflect.Array || t.Kind() == reflect.Map {
+		if t.Kind() != reflect.Pointer && prefix != "" {
+			prefix += "[]"
+		}
+		t = t.Elem()
+	}
+	if t.Kind() != reflect.Struct || visiting[t] {
+		return
+	}
+	visiting[t] = true
+	defer delete(visiting, t)
+
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		// Untagged embedded structs are flattened into their parent in JSON
+		if field.Anonymous && field.Tag.Get("json") == "" {
+			collectPaths(field.Type, prefix, paths, visiting)
+			continue
+		}
+		if !field.IsExported() || field.Tag.Get("json") == "-" {
+			continue
+		}
+		path := fieldName(field)
+		if prefix != "" {
+			path = prefix + "." + path
+		}
+		if category, ok := fieldCategory(field); ok && category > StandardLog {
+			paths[path] = category
+		}
+		collectPaths(field.Type, path, paths, visiting)
+	}
+}
+
+// fieldCategory parses the gdpr tag of a struct field.
+func fieldCategory(field reflect.StructField) (LogCategory, bool) {
+	tag, ok := field.Tag.Lookup(TagName)
+	if !ok {
+		return StandardLog, false
+	}
+	category, err := ParseCategory(tag)
+	if err != nil {
+		panic(fmt.Sprintf("field %s: %v", field.Name, err))
+	}
+	return category, true
+}
+
+// fieldName returns the JSON name of a struct field, its column name if it is never
+// serialized, or its Go name in lower case.
+func fieldName(field reflect.StructField) string {
+	if name, _, _ := strings.Cut(field.Tag.Get("json"), ","); name != "" && name != "-" {
+		return name
+	}
+	if name, _, _ := strings.Cut(field.Tag.Get("db"), ","); name != "" && name != "-" {
+		return name
+	}
+	return strings.ToLower(field.Name)
+}
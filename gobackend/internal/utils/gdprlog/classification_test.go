@@ -0,0 +1,165 @@
+package gdprlog
+
+import (
+	"encoding/json"
+	"os"
+	"testing"
+)
+
+// classifiedPatient is a model with gdpr-tagged fields, registered by the tests below
+type classifiedPatient struct {
+	ID         int64  `json:"id" db:"patient_id"`
+	Nickname   string `json:"nickname" gdpr:"personal"`
+	Diagnosis  string `json:"diagnosis" gdpr:"sensitive"`
+	KeyID      string `json:"key_id" gdpr:"standard"`
+	InsurerRef string `json:"-" db:"insurer_ref" gdpr:"personal"`
+}
+
+func (p *classifiedPatient) TableName() string {
+	return "patients"
+}
+
+// classifiedVisit nests classified models, as a document export does
+type classifiedVisit struct {
+	classifiedPatient
+	Notes    []classifiedNote `json:"notes"`
+	Referral *classifiedNote  `json:"referral"`
+	Internal classifiedNote   `json:"-"`
+}
+
+type classifiedNote struct {
+	Text string `json:"text" gdpr:"sensitive"`
+}
+
+func TestClassifyModel(t *testing.T) {
+	classification := ClassifyModel(&classifiedPatient{})
+
+	if classification.Model != "classifiedPatient" || classification.Table != "patients" {
+		t.Errorf("Unexpected model or table: %+v", classification)
+	}
+	want := []FieldClassification{
+		{Field: "nickname", Category: PersonalLog},
+		{Field: "diagnosis", Category: SensitiveLog},
+		{Field: "key_id", Category: StandardLog},
+		{Field: "insurer_ref", Category: PersonalLog},
+	}
+	if len(classification.Fields) != len(want) {
+		t.Fatalf("Expected %d fields, got %+v", len(want), classification.Fields)
+	}
+	for i, field := range want {
+		if classification.Fields[i] != field {
+			t.Errorf("Field %d: expected %+v, got %+v", i, field, classification.Fields[i])
+		}
+	}
+
+	t.Run("Invalid category", func(t *testing.T) {
+		defer func() {
+			if recover() == nil {
+				t.Error("Expected a panic for an unknown category")
+			}
+		}()
+		ClassifyModel(struct {
+			Name string `gdpr:"secret"`
+		}{})
+	})
+}
+
+func TestRegisterModels(t *testing.T) {
+	RegisterModels(classifiedPatient{})
+
+	if category, ok := FieldCategory("Diagnosis"); !ok || category != SensitiveLog {
+		t.Errorf("Expected diagnosis to be sensitive, got %v, %v", category, ok)
+	}
+	if _, ok := FieldCategory("unclassified"); ok {
+		t.Error("Expected no category for an unclassified field")
+	}
+
+	found := false
+	for _, model := range RegisteredModels() {
+		found = found || model.Model == "classifiedPatient"
+	}
+	if !found {
+		t.Error("Expected the registered model in the register")
+	}
+
+	// The tags take precedence over the legacy field name lists, but not over the values
+	if IsSensitiveField("key_id", "abc-123") {
+		t.Error("Expected key_id, tagged standard, not to be sensitive")
+	}
+	if !IsSensitiveField("diagnosis", "flu") || !IsPersonalField("diagnosis", "flu") {
+		t.Error("Expected diagnosis, tagged sensitive, to be sensitive and personal")
+	}
+	if !IsPersonalField("nickname", "Ola") || IsSensitiveField("nickname", "Ola") {
+		t.Error("Expected nickname, tagged personal, to be personal only")
+	}
+	if !IsPersonalField("key_id", "ola@example.com") {
+		t.Error("Expected an email value to be personal whatever the field")
+	}
+
+	// Erasure redacts classified fields identifying the subject
+	redacted := redactPersonalData(map[string]interface{}{
+		"nickname": "ola",
+		"fields":   map[string]interface{}{"insurer_ref": "ola"},
+	}, SubjectIdentifiers{Username: "ola"})
+	if redacted["nickname"] != "[REDACTED-GDPR]" {
+		t.Errorf("Expected nickname to be redacted, got %v", redacted["nickname"])
+	}
+	if redacted["fields"].(map[string]interface{})["insurer_ref"] != "[REDACTED-GDPR]" {
+		t.Errorf("Expected insurer_ref to be redacted, got %v", redacted["fields"])
+	}
+}
+
+func TestCategorizedPaths(t *testing.T) {
+	paths := CategorizedPaths(&classifiedVisit{})
+
+	want := map[string]LogCategory{
+		"nickname":      PersonalLog,
+		"diagnosis":     SensitiveLog,
+		"notes[].text":  SensitiveLog,
+		"referral.text": SensitiveLog,
+	}
+	if len(paths) != len(want) {
+		t.Fatalf("Expected %v, got %v", want, paths)
+	}
+	for path, category := range want {
+		if paths[path] != category {
+			t.Errorf("Path %s: expected %v, got %v", path, category, paths[path])
+		}
+	}
+}
+
+func TestLogCategory_MarshalText(t *testing.T) {
+	data, err := json.Marshal(map[string]LogCategory{"a": StandardLog, "b": PersonalLog, "c": SensitiveLog})
+	if err != nil {
+		t.Fatalf("Marshal returned error: %v", err)
+	}
+	if string(data) != `{"a":"standard","b":"personal","c":"sensitive"}` {
+		t.Errorf("Unexpected JSON: %s", data)
+	}
+
+	for _, category := range []LogCategory{StandardLog, PersonalLog, SensitiveLog} {
+		if parsed, err := ParseCategory(category.String()); err != nil || parsed != category {
+			t.Errorf("Expected %v to round-trip, got %v, %v", category, parsed, err)
+		}
+	}
+}
+
+func TestDetermineLogCategory_DeclaredCategory(t *testing.T) {
+	tempDir, cfg := setupTestLogDirectories(t)
+	defer os.RemoveAll(tempDir)
+
+	logger, err := NewGDPRLogger(cfg)
+	if err != nil {
+		t.Fatalf("Failed to create GDPRLogger: %v", err)
+	}
+
+	if got := logger.DetermineLogCategory(map[string]interface{}{"message": "Password changed", "category": SensitiveLog}); got != SensitiveLog {
+		t.Errorf("Expected the declared sensitive category, got %v", got)
+	}
+	if got := logger.DetermineLogCategory(map[string]interface{}{"message": "Profile updated", "category": PersonalLog}); got != PersonalLog {
+		t.Errorf("Expected the declared personal category, got %v", got)
+	}
+	if got := logger.DetermineLogCategory(map[string]interface{}{"password": "x", "category": PersonalLog}); got != SensitiveLog {
+		t.Errorf("Expected detected sensitive data to raise the declared category, got %v", got)
+	}
+}
@@ -66,7 +66,8 @@ var (
 )
 
 // SensitiveFieldNames is a list of field names that commonly contain sensitive information.
-// This list is used to identify fields that should be redacted or specially handled in logs.
+// This list is used to identify fields that should be redacted or specially handled in logs,
+// for field names no gdpr-tagged model classifies.
 var SensitiveFieldNames = []string{
 	"password", "token", "key", "secret", "auth_token", "access_token",
 	"refresh_token", "jwt", "api_key", "credit_card", "card_number",
@@ -75,6 +76,7 @@ var SensitiveFieldNames = []string{
 
 // PersonalFieldNames is a list of field names that commonly contain personal information.
 // These fields identify individuals but are generally less sensitive than those in SensitiveFieldNames.
+// Like SensitiveFieldNames, it only applies to field names no gdpr-tagged model classifies.
 var PersonalFieldNames = []string{
 	"user_id", "username", "email", "ip_address", "ip", "address",
 	"phone", "name", "full_name", "first_name", "last_name", "zip_code",
@@ -92,17 +94,24 @@ var PersonalFieldNames = []string{
 // Returns:
 //   - bool: true if the field appears to contain sensitive data, false otherwise
 func IsSensitiveField(fieldName string, value interface{}) bool {
-	// Check field name against known sensitive fields
 	lowerName := strings.ToLower(fieldName)
-	for _, name := range SensitiveFieldNames {
-		if strings.Contains(lowerName, name) {
+	if category, ok := FieldCategory(lowerName); ok {
+		// The gdpr tags of the models classify the field name
+		if category == SensitiveLog {
 			return true
 		}
-	}
+	} else {
+		// Check field name against known sensitive fields
+		for _, name := range SensitiveFieldNames {
+			if strings.Contains(lowerName, name) {
+				return true
+			}
+		}
 
-	// Check for sensitive patterns in field name
-	if passwordPattern.MatchString(lowerName) || authPattern.MatchString(lowerName) {
-		return true
+		// Check for sensitive patterns in field name
+		if passwordPattern.MatchString(lowerName) || authPattern.MatchString(lowerName) {
+			return true
+		}
 	}
 
 	// Check string values for sensitive patterns
@@ -141,18 +150,25 @@ func IsSensitiveField(fieldName string, value interface{}) bool {
 // Returns:
 //   - bool: true if the field appears to contain personal data, false otherwise
 func IsPersonalField(fieldName string, value interface{}) bool {
-	// Check field name against known personal fields
 	lowerName := strings.ToLower(fieldName)
-	for _, name := range PersonalFieldNames {
-		if strings.Contains(lowerName, name) {
+	if category, ok := FieldCategory(lowerName); ok {
+		// The gdpr tags of the models classify the field name
+		if category >= PersonalLog {
 			return true
 		}
-	}
+	} else {
+		// Check field name against known personal fields
+		for _, name := range PersonalFieldNames {
+			if strings.Contains(lowerName, name) {
+				return true
+			}
+		}
 
-	// Check for personal data patterns in field name
-	for _, pattern := range personalDataIndicators {
-		if pattern.MatchString(lowerName) {
-			return true
+		// Check for personal data patterns in field name
+		for _, pattern := range personalDataIndicators {
+			if pattern.MatchString(lowerName) {
+				return true
+			}
 		}
 	}
 
@@ -134,6 +134,7 @@ func createLogWriter(path string, perm os.FileMode) (io.Writer, error) {
 
 // DetermineLogCategory analyzes log data to determine its GDPR category.
 // It examines the fields in a log entry to categorize it as standard, personal, or sensitive.
+// A LogCategory passed in the "category" field is honoured as the lowest category of the log.
 //
 // Parameters:
 //   - fields: Map of key-value pairs that make up the log fields
@@ -141,6 +142,12 @@ func createLogWriter(path string, perm os.FileMode) (io.Writer, error) {
 // Returns:
 //   - LogCategory: The determined category (StandardLog, PersonalLog, or SensitiveLog)
 func (gl *GDPRLogger) DetermineLogCategory(fields map[string]interface{}) LogCategory {
+	// Start from the category declared by the caller, if any
+	declared, _ := fields["category"].(LogCategory)
+	if declared == SensitiveLog {
+		return SensitiveLog
+	}
+
 	// Check for sensitive data first
 	for key, value := range fields {
 		if IsSensitiveField(key, value) {
@@ -149,6 +156,9 @@ func (gl *GDPRLogger) DetermineLogCategory(fields map[string]interface{}) LogCat
 	}
 
 	// Then check for personal data
+	if declared == PersonalLog {
+		return PersonalLog
+	}
 	for key, value := range fields {
 		if IsPersonalField(key, value) {
 			return PersonalLog
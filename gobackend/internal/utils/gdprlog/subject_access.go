@@ -737,14 +737,9 @@ func redactPersonalData(entry map[string]interface{}, identifiers SubjectIdentif
 		redacted[k] = v
 	}
 
-	// Fields that might contain personal data
-	personalFields := []string{
-		"user_id", "username", "email", "name", "address", "phone", "ip", "remote_addr",
-	}
-
 	// Redact matching fields directly in the entry
-	for _, field := range personalFields {
-		if v, ok := redacted[field]; ok {
+	for field, v := range redacted {
+		if isSubjectField(field) {
 			if matchesValue(v, identifiers.UserID) ||
 				matchesValue(v, identifiers.Username) ||
 				matchesValue(v, identifiers.Email) ||
@@ -758,7 +753,7 @@ func redactPersonalData(entry map[string]interface{}, identifiers SubjectIdentif
 	if fields, ok := redacted["fields"].(map[string]interface{}); ok {
 		redactedFields := make(map[string]interface{})
 		for k, v := range fields {
-			if contains(personalFields, k) {
+			if isSubjectField(k) {
 				if matchesValue(v, identifiers.UserID) ||
 					matchesValue(v, identifiers.Username) ||
 					matchesValue(v, identifiers.Email) ||
@@ -777,6 +772,27 @@ func redactPersonalData(entry map[string]interface{}, identifiers SubjectIdentif
 	return redacted
 }
 
+// subjectFields are the log fields that might identify a data subject, besides the
+// fields the gdpr tags of the models classify as personal or sensitive.
+var subjectFields = []string{
+	"user_id", "username", "email", "name", "address", "phone", "ip", "remote_addr",
+}
+
+// isSubjectField checks if a log field might hold data identifying a data subject.
+//
+// Parameters:
+//   - field: The name of the log field
+//
+// Returns:
+//   - true if the field is a known subject field or classified as personal or sensitive
+func isSubjectField(field string) bool {
+	if contains(subjectFields, field) {
+		return true
+	}
+	category, ok := FieldCategory(field)
+	return ok && category >= PersonalLog
+}
+
 // contains checks if a string is in a slice.
 // This is a utility function for checking if a field name is in a list of personal fields.
 //
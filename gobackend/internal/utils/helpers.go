@@ -8,10 +8,14 @@
 package utils
 
 import (
+	"errors"
 	"fmt"
+	"net"
+	"net/http"
 	"strings"
 
 	"github.com/go-sql-driver/mysql"
+	"github.com/lib/pq"
 
 	"github.com/yasinhessnawi1/Hideme_Backend/internal/constants"
 )
@@ -57,16 +61,23 @@ func Plural(count int, word string) string {
 	return fmt.Sprintf("%d %ss", count, word)
 }
 
-// IsDuplicateKeyError checks if an error is a MySQL duplicate key error.
-// This is useful for handling unique constraint violations.
+// IsDuplicateKeyError checks if an error is a duplicate key error of the database.
+// This is useful for handling unique constraint violations, also when the error
+// has been wrapped on its way up.
 //
 // Parameters:
 //   - err: the error to check
 //
 // Returns:
-//   - true if the error is a MySQL duplicate key error (code 1062), false otherwise
+//   - true if the error is a PostgreSQL unique violation (code 23505) or a MySQL
+//     duplicate key error (code 1062), false otherwise
 func IsDuplicateKeyError(err error) bool {
-	if mysqlErr, ok := err.(*mysql.MySQLError); ok {
+	var pqErr *pq.Error
+	if errors.As(err, &pqErr) {
+		return pqErr.Code == constants.PGErrorDuplicateConstraint
+	}
+	var mysqlErr *mysql.MySQLError
+	if errors.As(err, &mysqlErr) {
 		// MySQL error number 1062 is "Duplicate entry"
 		return mysqlErr.Number == 1062
 	}
@@ -207,3 +218,109 @@ func RemoveString(slice []string, str string) []string {
 	}
 	return result
 }
+
+// GetClientIP returns the IP address of the client of a request. The address is read from
+// the connection only; the ClientIP middleware replaces it with the client address reported
+// by trusted proxies, so proxy headers a client sets itself are never believed.
+//
+// Parameters:
+//   - r: the HTTP request
+//
+// Returns:
+//   - the host part of the remote address
+func GetClientIP(r *http.Request) string {
+	ip, _, err := net.SplitHostPort(r.RemoteAddr)
+	if err != nil {
+		// If there's no port in the address, use it as is
+		return r.RemoteAddr
+	}
+	return ip
+}
+
+// ParseTrustedProxies parses the IP addresses and CIDR ranges of trusted reverse proxies.
+//
+// Parameters:
+//   - entries: IP addresses and CIDR ranges; empty entries are skipped
+//
+// Returns:
+//   - the networks of the proxies, a single address as a network of one
+//   - an error naming the first entry that is neither
+func ParseTrustedProxies(entries []string) ([]*net.IPNet, error) {
+	var networks []*net.IPNet
+	for _, entry := range entries {
+		entry = strings.TrimSpace(entry)
+		if entry == "" {
+			continue
+		}
+		if _, network, err := net.ParseCIDR(entry); err == nil {
+			networks = append(networks, network)
+			continue
+		}
+		ip := net.ParseIP(entry)
+		if ip == nil {
+			return nil, fmt.Errorf("invalid trusted proxy: %s", entry)
+		}
+		bits := 8 * len(ip.To16())
+		if ip.To4() != nil {
+			ip, bits = ip.To4(), 32
+		}
+		networks = append(networks, &net.IPNet{IP: ip, Mask: net.CIDRMask(bits, bits)})
+	}
+	return networks, nil
+}
+
+// ResolveClientIP returns the address of the client that sent a request through trusted
+// proxies. X-Forwarded-For is read from the right, as each proxy appends the address it
+// received the request from: the first address not of a trusted proxy is the client, and
+// everything left of it may have been made up by the client. Requests that didn't come
+// from a trusted proxy are answered with the address of the connection.
+//
+// Parameters:
+//   - r: the HTTP request
+//   - trustedProxies: the networks of the reverse proxies in front of the server
+//
+// Returns:
+//   - the IP address of the client
+func ResolveClientIP(r *http.Request, trustedProxies []*net.IPNet) string {
+	remoteIP := GetClientIP(r)
+	if !isTrustedProxy(remoteIP, trustedProxies) {
+		return remoteIP
+	}
+
+	forwardedFor := r.Header.Get("X-Forwarded-For")
+	if forwardedFor == "" {
+		// A single trusted proxy may report the client in X-Real-IP instead
+		if realIP := strings.TrimSpace(r.Header.Get("X-Real-IP")); net.ParseIP(realIP) != nil {
+			return realIP
+		}
+		return remoteIP
+	}
+
+	hops := strings.Split(forwardedFor, ",")
+	for i := len(hops) - 1; i >= 0; i-- {
+		hop := strings.TrimSpace(hops[i])
+		if net.ParseIP(hop) == nil {
+			// A hop that isn't an address can't be followed any further
+			break
+		}
+		remoteIP = hop
+		if !isTrustedProxy(hop, trustedProxies) {
+			break
+		}
+	}
+	return remoteIP
+}
+
+// isTrustedProxy reports whether an address belongs to a trusted proxy.
+func isTrustedProxy(address string, trustedProxies []*net.IPNet) bool {
+	ip := net.ParseIP(address)
+	if ip == nil {
+		return false
+	}
+	for _, network := range trustedProxies {
+		if network.Contains(ip) {
+			return true
+		}
+	}
+	return false
+}
@@ -2,10 +2,14 @@ package utils_test
 
 import (
 	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
 	"reflect"
 	"testing"
 
 	"github.com/go-sql-driver/mysql"
+	"github.com/lib/pq"
 	"github.com/yasinhessnawi1/Hideme_Backend/internal/utils"
 )
 
@@ -135,6 +139,16 @@ func TestIsDuplicateKeyError(t *testing.T) {
 			err:  &mysql.MySQLError{Number: 1062, Message: "Duplicate entry"},
 			want: true,
 		},
+		{
+			name: "PostgreSQL unique violation",
+			err:  fmt.Errorf("failed to save settings: %w", &pq.Error{Code: "23505"}),
+			want: true,
+		},
+		{
+			name: "Other PostgreSQL error",
+			err:  &pq.Error{Code: "23503"},
+			want: false,
+		},
 		{
 			name: "Other MySQL error",
 			err:  &mysql.MySQLError{Number: 1054, Message: "Unknown column"},
@@ -376,3 +390,111 @@ func TestRemoveString(t *testing.T) {
 		})
 	}
 }
+
+func TestGetClientIP(t *testing.T) {
+	tests := []struct {
+		name       string
+		headers    map[string]string
+		remoteAddr string
+		want       string
+	}{
+		{
+			name:       "Proxy headers are ignored",
+			headers:    map[string]string{"X-Forwarded-For": "203.0.113.7", "X-Real-IP": "203.0.113.8"},
+			remoteAddr: "192.0.2.1:1234",
+			want:       "192.0.2.1",
+		},
+		{
+			name:       "Remote address with port",
+			remoteAddr: "192.0.2.1:1234",
+			want:       "192.0.2.1",
+		},
+		{
+			name:       "Remote address without port",
+			remoteAddr: "192.0.2.1",
+			want:       "192.0.2.1",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodGet, "/", nil)
+			req.RemoteAddr = tt.remoteAddr
+			for key, value := range tt.headers {
+				req.Header.Set(key, value)
+			}
+
+			if got := utils.GetClientIP(req); got != tt.want {
+				t.Errorf("GetClientIP() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestResolveClientIP(t *testing.T) {
+	trusted, err := utils.ParseTrustedProxies([]string{"10.0.0.0/8", " 192.0.2.10 ", ""})
+	if err != nil {
+		t.Fatalf("ParseTrustedProxies() error = %v", err)
+	}
+
+	tests := []struct {
+		name       string
+		headers    map[string]string
+		remoteAddr string
+		want       string
+	}{
+		{
+			name:       "Client connecting directly can't forge its address",
+			headers:    map[string]string{"X-Forwarded-For": "203.0.113.7", "X-Real-IP": "203.0.113.8"},
+			remoteAddr: "198.51.100.4:1234",
+			want:       "198.51.100.4",
+		},
+		{
+			name:       "Right-most hop added by a trusted proxy",
+			headers:    map[string]string{"X-Forwarded-For": "203.0.113.7, 198.51.100.4"},
+			remoteAddr: "10.0.0.2:1234",
+			want:       "198.51.100.4",
+		},
+		{
+			name:       "Chain of trusted proxies",
+			headers:    map[string]string{"X-Forwarded-For": "203.0.113.7, 198.51.100.4, 10.1.1.1"},
+			remoteAddr: "192.0.2.10:1234",
+			want:       "198.51.100.4",
+		},
+		{
+			name:       "Forged hop is not followed",
+			headers:    map[string]string{"X-Forwarded-For": "not-an-ip, 10.1.1.1"},
+			remoteAddr: "10.0.0.2:1234",
+			want:       "10.1.1.1",
+		},
+		{
+			name:       "X-Real-IP of a trusted proxy",
+			headers:    map[string]string{"X-Real-IP": "203.0.113.8"},
+			remoteAddr: "10.0.0.2:1234",
+			want:       "203.0.113.8",
+		},
+		{
+			name:       "Trusted proxy without headers",
+			remoteAddr: "10.0.0.2:1234",
+			want:       "10.0.0.2",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodGet, "/", nil)
+			req.RemoteAddr = tt.remoteAddr
+			for key, value := range tt.headers {
+				req.Header.Set(key, value)
+			}
+
+			if got := utils.ResolveClientIP(req, trusted); got != tt.want {
+				t.Errorf("ResolveClientIP() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+
+	if _, err := utils.ParseTrustedProxies([]string{"proxy.internal"}); err == nil {
+		t.Error("ParseTrustedProxies() accepted a host name")
+	}
+}
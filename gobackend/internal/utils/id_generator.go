@@ -0,0 +1,151 @@
+// Package utils provides utility functions and helpers for the application.
+// This file implements the generation of IDs for new resources, such as API keys and sessions.
+//
+// Resources with generated IDs use time-sortable IDs instead of database sequences:
+//   - New rows are inserted in roughly ascending key order, keeping indexes compact,
+//     without every insert contending for the same sequence
+//   - IDs can be shown to customers without revealing how many resources exist
+//
+// The strategy is chosen once at startup with SetIDGenerator; NewID generates the IDs.
+package utils
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+
+	"github.com/yasinhessnawi1/Hideme_Backend/internal/constants"
+	"github.com/yasinhessnawi1/Hideme_Backend/internal/utils/clock"
+)
+
+// IDGenerator generates unique, time-sortable IDs for new resources.
+type IDGenerator interface {
+	// NewID returns a new unique ID.
+	NewID() string
+}
+
+// UUIDv7Generator generates UUIDv7s, which start with a millisecond timestamp
+// followed by random bits. It needs no coordination between server instances.
+type UUIDv7Generator struct{}
+
+// NewID returns a new UUIDv7.
+func (UUIDv7Generator) NewID() string {
+	return uuid.Must(uuid.NewV7()).String()
+}
+
+// SnowflakeGenerator generates Snowflake IDs: 64-bit integers made of a millisecond
+// timestamp, the node ID of the server instance and a per-millisecond sequence.
+// They are shorter than UUIDs but need a distinct node ID on every instance.
+type SnowflakeGenerator struct {
+	mu       sync.Mutex
+	nodeID   int64
+	lastTime int64
+	sequence int64
+	clock    clock.Clock
+}
+
+// NewSnowflakeGenerator creates a SnowflakeGenerator for a server instance.
+//
+// Parameters:
+//   - nodeID: The node ID of this instance, between 0 and 1023
+//
+// Returns:
+//   - A configured SnowflakeGenerator
+//   - An error if the node ID is out of range
+func NewSnowflakeGenerator(nodeID int64) (*SnowflakeGenerator, error) {
+	if nodeID < 0 || nodeID >= 1<<constants.SnowflakeNodeBits {
+		return nil, fmt.Errorf("snowflake node ID must be between 0 and %d: %d", 1<<constants.SnowflakeNodeBits-1, nodeID)
+	}
+
+	return &SnowflakeGenerator{
+		nodeID: nodeID,
+	}, nil
+}
+
+// SetClock sets the clock ids are timestamped with.
+func (g *SnowflakeGenerator) SetClock(c clock.Clock) {
+	g.clock = c
+}
+
+// now returns the current time of the generator's clock.
+func (g *SnowflakeGenerator) now() time.Time {
+	return clock.Or(g.clock).Now()
+}
+
+// NewID returns a new Snowflake ID in decimal notation.
+// When the sequence of the current millisecond is used up, it waits for the next one;
+// if the clock goes backwards, it keeps counting from the last timestamp it used.
+func (g *SnowflakeGenerator) NewID() string {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	const maxSequence = 1<<constants.SnowflakeSequenceBits - 1
+
+	millis := g.now().UnixMilli() - constants.SnowflakeEpochMillis
+	if millis < g.lastTime {
+		millis = g.lastTime
+	}
+
+	if millis == g.lastTime {
+		g.sequence = (g.sequence + 1) & maxSequence
+		if g.sequence == 0 {
+			// Sequence exhausted for this millisecond
+			for millis <= g.lastTime {
+				time.Sleep(time.Millisecond / 10)
+				millis = g.now().UnixMilli() - constants.SnowflakeEpochMillis
+			}
+		}
+	} else {
+		g.sequence = 0
+	}
+	g.lastTime = millis
+
+	id := millis<<(constants.SnowflakeNodeBits+constants.SnowflakeSequenceBits) |
+		g.nodeID<<constants.SnowflakeSequenceBits |
+		g.sequence
+
+	return strconv.FormatInt(id, 10)
+}
+
+// NewIDGenerator creates the ID generator for a strategy.
+//
+// Parameters:
+//   - strategy: uuidv7 or snowflake
+//   - nodeID: The node ID of this instance, only used by snowflake
+//
+// Returns:
+//   - The ID generator
+//   - An error if the strategy is unknown or the node ID is out of range
+func NewIDGenerator(strategy string, nodeID int64) (IDGenerator, error) {
+	switch strings.ToLower(strategy) {
+	case "", constants.IDStrategyUUIDv7:
+		return UUIDv7Generator{}, nil
+	case constants.IDStrategySnowflake:
+		return NewSnowflakeGenerator(nodeID)
+	default:
+		return nil, fmt.Errorf("invalid ID generation strategy: %s", strategy)
+	}
+}
+
+// idGenerator is the generator used by NewID, set at startup.
+var idGenerator IDGenerator = UUIDv7Generator{}
+
+// SetIDGenerator sets the generator used for the IDs of new resources.
+//
+// Parameters:
+//   - generator: The generator to use
+func SetIDGenerator(generator IDGenerator) {
+	idGenerator = generator
+}
+
+// NewID returns a new ID for a resource from the configured generator.
+//
+// Returns:
+//   - A new unique, time-sortable ID
+func NewID() string {
+	return idGenerator.NewID()
+}
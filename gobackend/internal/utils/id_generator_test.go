@@ -0,0 +1,93 @@
+package utils_test
+
+import (
+	"strconv"
+	"testing"
+
+	"github.com/google/uuid"
+
+	"github.com/yasinhessnawi1/Hideme_Backend/internal/constants"
+	"github.com/yasinhessnawi1/Hideme_Backend/internal/utils"
+)
+
+func TestUUIDv7Generator(t *testing.T) {
+	generator := utils.UUIDv7Generator{}
+
+	first, second := generator.NewID(), generator.NewID()
+
+	parsed, err := uuid.Parse(first)
+	if err != nil {
+		t.Fatalf("NewID() = %q, not a UUID: %v", first, err)
+	}
+	if parsed.Version() != 7 {
+		t.Errorf("UUID version = %d, want 7", parsed.Version())
+	}
+	if first == second {
+		t.Error("NewID() returned the same ID twice")
+	}
+}
+
+func TestSnowflakeGenerator(t *testing.T) {
+	generator, err := utils.NewSnowflakeGenerator(5)
+	if err != nil {
+		t.Fatalf("NewSnowflakeGenerator() error = %v", err)
+	}
+
+	// IDs must be unique and ascending, also within one millisecond
+	var last int64
+	for i := 0; i < 10000; i++ {
+		id, err := strconv.ParseInt(generator.NewID(), 10, 64)
+		if err != nil {
+			t.Fatalf("NewID() is not a decimal integer: %v", err)
+		}
+		if id <= last {
+			t.Fatalf("NewID() = %d after %d, want ascending IDs", id, last)
+		}
+		last = id
+	}
+
+	node := (last >> constants.SnowflakeSequenceBits) & (1<<constants.SnowflakeNodeBits - 1)
+	if node != 5 {
+		t.Errorf("Node ID in Snowflake ID = %d, want 5", node)
+	}
+}
+
+func TestNewIDGenerator(t *testing.T) {
+	tests := []struct {
+		strategy string
+		nodeID   int64
+		wantErr  bool
+	}{
+		{strategy: "", wantErr: false},
+		{strategy: constants.IDStrategyUUIDv7, wantErr: false},
+		{strategy: constants.IDStrategySnowflake, nodeID: 1023, wantErr: false},
+		{strategy: constants.IDStrategySnowflake, nodeID: 1024, wantErr: true},
+		{strategy: constants.IDStrategySnowflake, nodeID: -1, wantErr: true},
+		{strategy: "sequence", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.strategy, func(t *testing.T) {
+			generator, err := utils.NewIDGenerator(tt.strategy, tt.nodeID)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("NewIDGenerator() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if err == nil && generator.NewID() == "" {
+				t.Error("NewID() returned an empty ID")
+			}
+		})
+	}
+}
+
+func TestSetIDGenerator(t *testing.T) {
+	snowflake, err := utils.NewSnowflakeGenerator(1)
+	if err != nil {
+		t.Fatalf("NewSnowflakeGenerator() error = %v", err)
+	}
+	utils.SetIDGenerator(snowflake)
+	defer utils.SetIDGenerator(utils.UUIDv7Generator{})
+
+	if _, err := strconv.ParseInt(utils.NewID(), 10, 64); err != nil {
+		t.Errorf("NewID() did not use the configured generator: %v", err)
+	}
+}
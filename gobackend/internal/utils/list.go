@@ -0,0 +1,230 @@
+// Package utils provides utility functions and helpers for the application.
+// This file implements the list envelope shared by every list endpoint, so clients and
+// generated SDKs page through all collections the same way.
+//
+// A list response carries its items, the total number of items and the cursors of the
+// neighbouring pages:
+//
+//	{"success": true, "data": {"items": [...], "total": 42, "page_info": {"next_cursor": "...", "prev_cursor": null}}}
+//
+// Large collections can also be paged by keyset cursors: the cursor names the last item of
+// the previous page, so reading a page costs the same however deep into the list it is.
+// Such lists have no total and only a next cursor.
+//
+// Clients opt in to the envelope by sending X-API-Version: 2. Requests without the header
+// keep receiving the response each endpoint had before the envelope, marked as deprecated,
+// so existing clients don't break.
+package utils
+
+import (
+	"encoding/base64"
+	"fmt"
+	"net/http"
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/yasinhessnawi1/Hideme_Backend/internal/constants"
+)
+
+// pageCursorPrefix starts the decoded cursors of page-numbered lists.
+const pageCursorPrefix = "page:"
+
+// keysetCursorPrefix starts the decoded cursors of keyset-paged lists.
+const keysetCursorPrefix = "after:"
+
+// KeysetCursor is the position of an item in a list ordered newest first by a timestamp
+// and then by ID. The next page of such a list starts after the item.
+type KeysetCursor struct {
+	Timestamp time.Time // The timestamp the list is ordered by
+	ID        int64     // The ID of the item, breaking ties between equal timestamps
+}
+
+// ListEnvelope is the data of a list response.
+type ListEnvelope struct {
+	// Items are the items on this page, never null
+	Items interface{} `json:"items"`
+
+	// Total is the number of items on all pages; null if the source of the list doesn't report it
+	Total *int `json:"total"`
+
+	// PageInfo points to the neighbouring pages
+	PageInfo PageInfo `json:"page_info"`
+}
+
+// PageInfo holds the cursors of the pages next to the current one. A client passes a cursor
+// in the cursor query parameter to fetch that page; null means there is no such page.
+type PageInfo struct {
+	NextCursor *string `json:"next_cursor"` // The cursor of the next page
+	PrevCursor *string `json:"prev_cursor"` // The cursor of the previous page
+}
+
+// List sends a list in the list envelope. Clients pinned to an API version before
+// constants.APIVersionListEnvelope receive the legacy response of the endpoint instead.
+//
+// Parameters:
+//   - w: The HTTP response writer
+//   - r: The HTTP request, for the API version of the client
+//   - list: The list to send
+//   - legacy: The response the endpoint sent before the list envelope
+func List(w http.ResponseWriter, r *http.Request, list ListEnvelope, legacy Response) {
+	version := RequestedAPIVersion(r)
+	w.Header().Set(constants.HeaderXAPIVersion, strconv.Itoa(version))
+	if version < constants.APIVersionListEnvelope {
+		w.Header().Set(constants.HeaderDeprecation, "true")
+		SendJSON(w, constants.StatusOK, legacy)
+		return
+	}
+
+	if list.Items == nil {
+		list.Items = []interface{}{}
+	}
+	SendJSON(w, constants.StatusOK, Response{
+		Success: constants.ResponseSuccess,
+		Data:    list,
+	})
+}
+
+// ListAll sends a complete list, such as all API keys of a user, in the list envelope.
+// Before the envelope such lists were sent as the data of the response.
+//
+// Parameters:
+//   - w: The HTTP response writer
+//   - r: The HTTP request
+//   - items: The items; must be a slice
+func ListAll(w http.ResponseWriter, r *http.Request, items interface{}) {
+	value := reflect.ValueOf(items)
+	if value.Kind() != reflect.Slice {
+		InternalServerError(w, fmt.Errorf("list of %T is not a slice", items))
+		return
+	}
+
+	total := value.Len()
+	list := ListEnvelope{Items: items, Total: &total}
+	if value.IsNil() {
+		// Send an empty array rather than null
+		list.Items = reflect.MakeSlice(value.Type(), 0, 0).Interface()
+	}
+	List(w, r, list, Response{Success: constants.ResponseSuccess, Data: items})
+}
+
+// ListPage sends a page of a page-numbered list in the list envelope, with cursors of the
+// neighbouring pages. Before the envelope such lists were sent as by Paginated.
+//
+// Parameters:
+//   - w: The HTTP response writer
+//   - r: The HTTP request
+//   - items: The items of the page
+//   - params: The pagination parameters the page was read with
+//   - totalItems: The number of items on all pages
+func ListPage(w http.ResponseWriter, r *http.Request, items interface{}, params PaginationParams, totalItems int) {
+	list := ListEnvelope{Items: items, Total: &totalItems}
+	if params.Page > 1 {
+		prev := EncodePageCursor(params.Page - 1)
+		list.PageInfo.PrevCursor = &prev
+	}
+	if params.Page*params.PageSize < totalItems {
+		next := EncodePageCursor(params.Page + 1)
+		list.PageInfo.NextCursor = &next
+	}
+	if value := reflect.ValueOf(items); value.Kind() == reflect.Slice && value.IsNil() {
+		list.Items = reflect.MakeSlice(value.Type(), 0, 0).Interface()
+	}
+	List(w, r, list, paginatedResponse(items, params.Page, params.PageSize, totalItems))
+}
+
+// ListKeyset sends a page of a keyset-paged list in the list envelope. Counting all items
+// would cost what keyset paging saves, so the total is null. Before the envelope such
+// lists were sent as the data of the response.
+//
+// Parameters:
+//   - w: The HTTP response writer
+//   - r: The HTTP request
+//   - items: The items of the page
+//   - next: The last item of the page if more items follow; nil on the last page
+func ListKeyset(w http.ResponseWriter, r *http.Request, items interface{}, next *KeysetCursor) {
+	list := ListEnvelope{Items: items}
+	if next != nil {
+		cursor := EncodeKeysetCursor(*next)
+		list.PageInfo.NextCursor = &cursor
+	}
+	if value := reflect.ValueOf(items); value.Kind() == reflect.Slice && value.IsNil() {
+		list.Items = reflect.MakeSlice(value.Type(), 0, 0).Interface()
+	}
+	List(w, r, list, Response{Success: constants.ResponseSuccess, Data: items})
+}
+
+// RequestedAPIVersion returns the API version a client asked for in the X-API-Version
+// header. Requests without a version, or with one this server doesn't know, get the
+// default version.
+//
+// Parameters:
+//   - r: The HTTP request
+//
+// Returns:
+//   - The API version to respond in
+func RequestedAPIVersion(r *http.Request) int {
+	version, err := strconv.Atoi(strings.TrimSpace(r.Header.Get(constants.HeaderXAPIVersion)))
+	if err != nil || version < constants.APIVersionLegacy || version > constants.APIVersionCurrent {
+		return constants.APIVersionDefault
+	}
+	return version
+}
+
+// EncodePageCursor returns the opaque cursor of a page of a page-numbered list.
+//
+// Parameters:
+//   - page: The page number
+//
+// Returns:
+//   - The cursor of the page
+func EncodePageCursor(page int) string {
+	return base64.RawURLEncoding.EncodeToString([]byte(pageCursorPrefix + strconv.Itoa(page)))
+}
+
+// decodePageCursor returns the page number of a cursor made by EncodePageCursor.
+func decodePageCursor(cursor string) (int, bool) {
+	decoded, err := base64.RawURLEncoding.DecodeString(cursor)
+	if err != nil || !strings.HasPrefix(string(decoded), pageCursorPrefix) {
+		return 0, false
+	}
+	page, err := strconv.Atoi(strings.TrimPrefix(string(decoded), pageCursorPrefix))
+	if err != nil || page < 1 {
+		return 0, false
+	}
+	return page, true
+}
+
+// EncodeKeysetCursor returns the opaque cursor of the page of a keyset-paged list starting
+// after an item.
+//
+// Parameters:
+//   - key: The position of the last item of the previous page
+//
+// Returns:
+//   - The cursor of the page
+func EncodeKeysetCursor(key KeysetCursor) string {
+	value := keysetCursorPrefix + key.Timestamp.UTC().Format(time.RFC3339Nano) + "," + strconv.FormatInt(key.ID, 10)
+	return base64.RawURLEncoding.EncodeToString([]byte(value))
+}
+
+// decodeKeysetCursor returns the position of a cursor made by EncodeKeysetCursor.
+func decodeKeysetCursor(cursor string) (KeysetCursor, bool) {
+	decoded, err := base64.RawURLEncoding.DecodeString(cursor)
+	if err != nil || !strings.HasPrefix(string(decoded), keysetCursorPrefix) {
+		return KeysetCursor{}, false
+	}
+	timestamp, id, ok := strings.Cut(strings.TrimPrefix(string(decoded), keysetCursorPrefix), ",")
+	if !ok {
+		return KeysetCursor{}, false
+	}
+	key := KeysetCursor{}
+	if key.Timestamp, err = time.Parse(time.RFC3339Nano, timestamp); err != nil {
+		return KeysetCursor{}, false
+	}
+	if key.ID, err = strconv.ParseInt(id, 10, 64); err != nil || key.ID < 1 {
+		return KeysetCursor{}, false
+	}
+	return key, true
+}
@@ -0,0 +1,243 @@
+package utils_test
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/yasinhessnawi1/Hideme_Backend/internal/constants"
+	"github.com/yasinhessnawi1/Hideme_Backend/internal/utils"
+)
+
+// decodeBody decodes a JSON response body into a generic map.
+func decodeBody(t *testing.T, rr *httptest.ResponseRecorder) map[string]interface{} {
+	t.Helper()
+	var body map[string]interface{}
+	if err := json.Unmarshal(rr.Body.Bytes(), &body); err != nil {
+		t.Fatalf("Failed to unmarshal response: %v", err)
+	}
+	return body
+}
+
+// envelopeRequest returns a request of a client that opted in to the list envelope.
+func envelopeRequest() *http.Request {
+	req := httptest.NewRequest(http.MethodGet, "/", nil)
+	req.Header.Set(constants.HeaderXAPIVersion, "2")
+	return req
+}
+
+func TestListAll(t *testing.T) {
+	t.Run("Envelope", func(t *testing.T) {
+		rr := httptest.NewRecorder()
+		utils.ListAll(rr, envelopeRequest(), []string{"a", "b"})
+
+		want := map[string]interface{}{
+			"items":     []interface{}{"a", "b"},
+			"total":     float64(2),
+			"page_info": map[string]interface{}{"next_cursor": nil, "prev_cursor": nil},
+		}
+		if got := decodeBody(t, rr)["data"]; !reflect.DeepEqual(got, want) {
+			t.Errorf("data = %v, want %v", got, want)
+		}
+		if got := rr.Header().Get(constants.HeaderXAPIVersion); got != "2" {
+			t.Errorf("%s = %q, want 2", constants.HeaderXAPIVersion, got)
+		}
+	})
+
+	t.Run("Nil slice is an empty array", func(t *testing.T) {
+		rr := httptest.NewRecorder()
+		var items []string
+		utils.ListAll(rr, envelopeRequest(), items)
+
+		data := decodeBody(t, rr)["data"].(map[string]interface{})
+		if got, ok := data["items"].([]interface{}); !ok || len(got) != 0 {
+			t.Errorf("items = %v, want []", data["items"])
+		}
+	})
+
+	t.Run("Legacy API version", func(t *testing.T) {
+		rr := httptest.NewRecorder()
+		req := httptest.NewRequest(http.MethodGet, "/", nil)
+		req.Header.Set(constants.HeaderXAPIVersion, "1")
+		utils.ListAll(rr, req, []string{"a"})
+
+		if got := decodeBody(t, rr)["data"]; !reflect.DeepEqual(got, []interface{}{"a"}) {
+			t.Errorf("data = %v, want the bare list", got)
+		}
+		if got := rr.Header().Get(constants.HeaderDeprecation); got != "true" {
+			t.Errorf("%s = %q, want true", constants.HeaderDeprecation, got)
+		}
+	})
+
+	t.Run("No API version", func(t *testing.T) {
+		// Clients that never sent the header keep the response they were built against
+		for _, version := range []string{"", "3", "two"} {
+			rr := httptest.NewRecorder()
+			req := httptest.NewRequest(http.MethodGet, "/", nil)
+			if version != "" {
+				req.Header.Set(constants.HeaderXAPIVersion, version)
+			}
+			utils.ListAll(rr, req, []string{"a"})
+
+			if got := decodeBody(t, rr)["data"]; !reflect.DeepEqual(got, []interface{}{"a"}) {
+				t.Errorf("X-API-Version %q: data = %v, want the bare list", version, got)
+			}
+			if got := rr.Header().Get(constants.HeaderXAPIVersion); got != "1" {
+				t.Errorf("X-API-Version %q: %s = %q, want 1", version, constants.HeaderXAPIVersion, got)
+			}
+			if got := rr.Header().Get(constants.HeaderDeprecation); got != "true" {
+				t.Errorf("X-API-Version %q: %s = %q, want true", version, constants.HeaderDeprecation, got)
+			}
+		}
+	})
+
+	t.Run("Not a slice", func(t *testing.T) {
+		rr := httptest.NewRecorder()
+		utils.ListAll(rr, httptest.NewRequest(http.MethodGet, "/", nil), "a")
+
+		if rr.Code != http.StatusInternalServerError {
+			t.Errorf("status = %d, want %d", rr.Code, http.StatusInternalServerError)
+		}
+	})
+}
+
+func TestListPage(t *testing.T) {
+	tests := []struct {
+		name     string
+		page     int
+		total    int
+		wantNext bool
+		wantPrev bool
+	}{
+		{name: "Only page", page: 1, total: 10},
+		{name: "First page", page: 1, total: 25, wantNext: true},
+		{name: "Middle page", page: 2, total: 25, wantNext: true, wantPrev: true},
+		{name: "Last page", page: 3, total: 25, wantPrev: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rr := httptest.NewRecorder()
+			params := utils.PaginationParams{Page: tt.page, PageSize: 10}
+			utils.ListPage(rr, envelopeRequest(), []int{1}, params, tt.total)
+
+			data := decodeBody(t, rr)["data"].(map[string]interface{})
+			if data["total"] != float64(tt.total) {
+				t.Errorf("total = %v, want %d", data["total"], tt.total)
+			}
+			pageInfo := data["page_info"].(map[string]interface{})
+			next, _ := pageInfo["next_cursor"].(string)
+			prev, _ := pageInfo["prev_cursor"].(string)
+			if (next != "") != tt.wantNext || (prev != "") != tt.wantPrev {
+				t.Fatalf("page_info = %v, want next %v and prev %v", pageInfo, tt.wantNext, tt.wantPrev)
+			}
+
+			// The cursors select the neighbouring pages
+			if next != "" {
+				req := httptest.NewRequest(http.MethodGet, "/?cursor="+next, nil)
+				if got := utils.GetPaginationParams(req).Page; got != tt.page+1 {
+					t.Errorf("next_cursor selects page %d, want %d", got, tt.page+1)
+				}
+			}
+			if prev != "" {
+				req := httptest.NewRequest(http.MethodGet, "/?cursor="+prev, nil)
+				if got := utils.GetPaginationParams(req).Page; got != tt.page-1 {
+					t.Errorf("prev_cursor selects page %d, want %d", got, tt.page-1)
+				}
+			}
+		})
+	}
+
+	t.Run("Legacy API version", func(t *testing.T) {
+		rr := httptest.NewRecorder()
+		req := httptest.NewRequest(http.MethodGet, "/", nil)
+		req.Header.Set(constants.HeaderXAPIVersion, "1")
+		utils.ListPage(rr, req, []int{1}, utils.PaginationParams{Page: 2, PageSize: 10}, 25)
+
+		meta, ok := decodeBody(t, rr)["meta"].(map[string]interface{})
+		if !ok || meta["total_pages"] != float64(3) || meta["page"] != float64(2) {
+			t.Errorf("meta = %v, want page 2 of 3", meta)
+		}
+	})
+}
+
+func TestListKeyset(t *testing.T) {
+	t.Run("Next cursor selects the page after the item", func(t *testing.T) {
+		key := utils.KeysetCursor{Timestamp: time.Date(2025, 3, 1, 12, 30, 0, 123456000, time.UTC), ID: 42}
+		rr := httptest.NewRecorder()
+		utils.ListKeyset(rr, envelopeRequest(), []int{1}, &key)
+
+		data := decodeBody(t, rr)["data"].(map[string]interface{})
+		if data["total"] != nil {
+			t.Errorf("total = %v, want null", data["total"])
+		}
+		pageInfo := data["page_info"].(map[string]interface{})
+		next, _ := pageInfo["next_cursor"].(string)
+		if next == "" || pageInfo["prev_cursor"] != nil {
+			t.Fatalf("page_info = %v, want only a next cursor", pageInfo)
+		}
+
+		params := utils.GetPaginationParams(httptest.NewRequest(http.MethodGet, "/?page_size=5&cursor="+next, nil))
+		if !params.Keyset || params.After == nil || !params.After.Timestamp.Equal(key.Timestamp) || params.After.ID != key.ID {
+			t.Errorf("next_cursor selects %+v, want the page after %+v", params.After, key)
+		}
+		if params.PageSize != 5 {
+			t.Errorf("PageSize = %d, want 5", params.PageSize)
+		}
+	})
+
+	t.Run("Last page", func(t *testing.T) {
+		rr := httptest.NewRecorder()
+		utils.ListKeyset(rr, envelopeRequest(), []int(nil), nil)
+
+		data := decodeBody(t, rr)["data"].(map[string]interface{})
+		if items, ok := data["items"].([]interface{}); !ok || len(items) != 0 {
+			t.Errorf("items = %v, want an empty array", data["items"])
+		}
+		if pageInfo := data["page_info"].(map[string]interface{}); pageInfo["next_cursor"] != nil {
+			t.Errorf("next_cursor = %v, want null", pageInfo["next_cursor"])
+		}
+	})
+
+	t.Run("First page", func(t *testing.T) {
+		params := utils.GetPaginationParams(httptest.NewRequest(http.MethodGet, "/?pagination=keyset", nil))
+		if !params.Keyset || params.After != nil {
+			t.Errorf("params = %+v, want the first keyset page", params)
+		}
+		if params := utils.GetPaginationParams(httptest.NewRequest(http.MethodGet, "/?page=2", nil)); params.Keyset {
+			t.Errorf("params = %+v, want page-numbered paging by default", params)
+		}
+	})
+}
+
+func TestRequestedAPIVersion(t *testing.T) {
+	tests := []struct {
+		header string
+		want   int
+	}{
+		{header: "", want: constants.APIVersionLegacy},
+		{header: "1", want: constants.APIVersionLegacy},
+		{header: " 2 ", want: constants.APIVersionListEnvelope},
+		{header: "99", want: constants.APIVersionLegacy},
+		{header: "0", want: constants.APIVersionLegacy},
+		{header: "v1", want: constants.APIVersionLegacy},
+	}
+
+	for _, tt := range tests {
+		req := httptest.NewRequest(http.MethodGet, "/", nil)
+		req.Header.Set(constants.HeaderXAPIVersion, tt.header)
+		if got := utils.RequestedAPIVersion(req); got != tt.want {
+			t.Errorf("RequestedAPIVersion(%q) = %d, want %d", tt.header, got, tt.want)
+		}
+	}
+}
+
+func TestGetPaginationParams_InvalidCursor(t *testing.T) {
+	req := httptest.NewRequest(http.MethodGet, "/?cursor=bm90LWEtcGFnZQ&page=3", nil)
+	if got := utils.GetPaginationParams(req).Page; got != constants.DefaultPage {
+		t.Errorf("Page = %d, want the default page for a cursor that isn't ours", got)
+	}
+}
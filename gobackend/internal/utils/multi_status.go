@@ -0,0 +1,84 @@
+// Package utils provides utility functions and helpers for the application.
+// This file implements the multi-status responses of batch requests, which report the
+// outcome of each item, so that clients retry only the items that failed instead of
+// treating a partial failure as a failure of the whole batch.
+package utils
+
+import (
+	"net/http"
+	"sort"
+
+	"github.com/yasinhessnawi1/Hideme_Backend/internal/constants"
+)
+
+// BatchInfo reports the outcome of each item of a batch request.
+type BatchInfo struct {
+	Succeeded int         `json:"succeeded"` // The number of items that succeeded
+	Failed    int         `json:"failed"`    // The number of items that failed
+	Items     []BatchItem `json:"items"`     // The outcome of each item, in the order of the request
+}
+
+// BatchItem is the outcome of one item of a batch request.
+type BatchItem struct {
+	Index  int        `json:"index"`           // The position of the item in the request
+	Key    string     `json:"key,omitempty"`   // The item itself if it is a plain value, such as a ban list word
+	ID     int64      `json:"id,omitempty"`    // The ID of the resource the item created, if any
+	Status int        `json:"status"`          // The HTTP status code the item would have had on its own
+	Error  *ErrorInfo `json:"error,omitempty"` // Why the item failed, with a machine-readable error code
+}
+
+// Succeed records an item that succeeded.
+//
+// Parameters:
+//   - index: The position of the item in the request
+//   - key: The item itself if it is a plain value; empty otherwise
+//   - id: The ID of the resource the item created; zero if none
+//   - status: The HTTP status code of the item, e.g. constants.StatusCreated
+func (b *BatchInfo) Succeed(index int, key string, id int64, status int) {
+	b.Succeeded++
+	b.Items = append(b.Items, BatchItem{Index: index, Key: key, ID: id, Status: status})
+}
+
+// Fail records an item that failed, with the status code and error code of its error.
+//
+// Parameters:
+//   - index: The position of the item in the request
+//   - key: The item itself if it is a plain value; empty otherwise
+//   - err: Why the item failed
+func (b *BatchInfo) Fail(index int, key string, err error) {
+	appErr := ParseError(err)
+	b.Failed++
+	b.Items = append(b.Items, BatchItem{
+		Index:  index,
+		Key:    key,
+		Status: appErr.StatusCode,
+		Error:  errorInfoFromAppError(appErr),
+	})
+}
+
+// MultiStatus sends the response of a batch request with the outcome of each item. If
+// every item succeeded, the response has the status code of a successful request;
+// otherwise it is a 207 Multi-Status response, which is successful as long as one of
+// the items succeeded.
+//
+// Parameters:
+//   - w: The HTTP response writer
+//   - successStatus: The status code if every item succeeded, e.g. constants.StatusCreated
+//   - data: The response data, such as the resources after the batch; may be nil
+//   - batch: The outcome of each item
+func MultiStatus(w http.ResponseWriter, successStatus int, data interface{}, batch *BatchInfo) {
+	sort.SliceStable(batch.Items, func(i, j int) bool {
+		return batch.Items[i].Index < batch.Items[j].Index
+	})
+
+	statusCode := successStatus
+	if batch.Failed > 0 {
+		statusCode = constants.StatusMultiStatus
+	}
+
+	SendJSON(w, statusCode, Response{
+		Success: batch.Succeeded > 0 || batch.Failed == 0,
+		Data:    data,
+		Batch:   batch,
+	})
+}
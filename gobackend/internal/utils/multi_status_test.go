@@ -0,0 +1,74 @@
+package utils_test
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/yasinhessnawi1/Hideme_Backend/internal/constants"
+	"github.com/yasinhessnawi1/Hideme_Backend/internal/utils"
+)
+
+func TestMultiStatus(t *testing.T) {
+	t.Run("All succeeded", func(t *testing.T) {
+		var batch utils.BatchInfo
+		batch.Succeed(0, "", 11, constants.StatusCreated)
+		batch.Succeed(1, "", 12, constants.StatusCreated)
+
+		rr := httptest.NewRecorder()
+		utils.MultiStatus(rr, constants.StatusCreated, map[string]int{"document_id": 3}, &batch)
+
+		assert.Equal(t, http.StatusCreated, rr.Code)
+		assert.JSONEq(t, `{
+			"success": true,
+			"data": {"document_id": 3},
+			"batch": {"succeeded": 2, "failed": 0, "items": [
+				{"index": 0, "id": 11, "status": 201},
+				{"index": 1, "id": 12, "status": 201}
+			]}
+		}`, rr.Body.String())
+	})
+
+	t.Run("Partial failure", func(t *testing.T) {
+		var batch utils.BatchInfo
+		batch.Fail(1, "Oslo", utils.NewNotFoundError("Ban list word", "Oslo"))
+		batch.Fail(2, "", utils.NewValidationError("entity_name", "entity_name is required"))
+		batch.Succeed(0, "Ström", 0, constants.StatusOK)
+
+		rr := httptest.NewRecorder()
+		utils.MultiStatus(rr, constants.StatusOK, nil, &batch)
+
+		assert.Equal(t, http.StatusMultiStatus, rr.Code)
+		var response utils.Response
+		require.NoError(t, json.Unmarshal(rr.Body.Bytes(), &response))
+		assert.True(t, response.Success)
+		assert.Equal(t, 1, response.Batch.Succeeded)
+		assert.Equal(t, 2, response.Batch.Failed)
+
+		// The items are reported in the order of the request
+		items := response.Batch.Items
+		require.Len(t, items, 3)
+		assert.Equal(t, "Ström", items[0].Key)
+		assert.Nil(t, items[0].Error)
+		assert.Equal(t, http.StatusNotFound, items[1].Status)
+		assert.Equal(t, constants.CodeNotFound, items[1].Error.Code)
+		assert.Equal(t, http.StatusBadRequest, items[2].Status)
+		assert.Equal(t, constants.CodeValidationError, items[2].Error.Code)
+		assert.Equal(t, map[string]string{"entity_name": "entity_name is required"}, items[2].Error.Details)
+	})
+
+	t.Run("All failed", func(t *testing.T) {
+		var batch utils.BatchInfo
+		batch.Fail(0, "", utils.NewValidationError("method_id", "method_id is required"))
+
+		rr := httptest.NewRecorder()
+		utils.MultiStatus(rr, constants.StatusCreated, nil, &batch)
+
+		assert.Equal(t, http.StatusMultiStatus, rr.Code)
+		assert.Contains(t, rr.Body.String(), `"success":false`)
+	})
+}
@@ -20,13 +20,37 @@ type Limiter struct {
 	// rate is the token refill rate (tokens per second)
 	rate float64
 
-	// capacity is the maximum number of tokens the bucket can hold
+	// capacity is the maximum number of tokens the bucket can hold, including a grant
 	capacity float64
 
+	// grant is the extra capacity granted until grantUntil; zero without a grant
+	grant float64
+
+	// grantUntil is when the extra capacity expires
+	grantUntil time.Time
+
 	// mu is a mutex to protect concurrent access to the bucket
 	mu sync.Mutex
 }
 
+// Status is the state of a bucket at one point in time.
+type Status struct {
+	// Tokens is the number of requests the client may send right now
+	Tokens float64
+
+	// Capacity is the maximum number of tokens, including granted ones
+	Capacity float64
+
+	// Rate is the number of tokens added per second
+	Rate float64
+
+	// Grant is the extra capacity granted to the bucket; zero without a grant
+	Grant int
+
+	// GrantUntil is when the grant expires; zero without a grant
+	GrantUntil time.Time
+}
+
 // Rate controls how many requests per second are allowed
 type Rate struct {
 	// RequestsPerSecond defines how many tokens are added per second
@@ -65,16 +89,7 @@ func (l *Limiter) Allow() bool {
 
 	// Calculate how many tokens should have been added since the last request
 	now := time.Now()
-	elapsed := now.Sub(l.lastTime).Seconds()
-	l.lastTime = now
-
-	// Add tokens based on elapsed time
-	l.tokens += elapsed * l.rate
-
-	// Cap tokens at capacity
-	if l.tokens > l.capacity {
-		l.tokens = l.capacity
-	}
+	l.refill(now)
 
 	// Check if there's at least one token available
 	if l.tokens < 1 {
@@ -86,6 +101,83 @@ func (l *Limiter) Allow() bool {
 	return true
 }
 
+// RetryAfter returns how long a client must wait until the bucket holds a token again.
+//
+// Returns:
+//   - Zero if a request is allowed now, or if the bucket is never refilled
+func (l *Limiter) RetryAfter() time.Duration {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	// Count the tokens added since the last request without consuming any
+	tokens := min(l.capacity, l.tokens+time.Since(l.lastTime).Seconds()*l.rate)
+	if tokens >= 1 || l.rate <= 0 {
+		return 0
+	}
+	return time.Duration((1 - tokens) / l.rate * float64(time.Second))
+}
+
+// Grant raises the capacity of the bucket by burst tokens until the given time, and fills
+// the bucket with them right away. A new grant replaces the previous one.
+//
+// Parameters:
+//   - burst: The extra number of requests the client may send at once
+//   - until: When the extra capacity expires
+func (l *Limiter) Grant(burst int, until time.Time) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	now := time.Now()
+	l.refill(now)
+	l.capacity -= l.grant
+	l.grant = 0
+	if !until.After(now) {
+		l.tokens = min(l.tokens, l.capacity)
+		return
+	}
+
+	l.grant = float64(burst)
+	l.grantUntil = until
+	l.capacity += l.grant
+	l.tokens = min(l.tokens+l.grant, l.capacity)
+}
+
+// Status returns the current state of the bucket without consuming a token.
+//
+// Returns:
+//   - The tokens, capacity, rate and grant of the bucket
+func (l *Limiter) Status() Status {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	l.refill(time.Now())
+	status := Status{Tokens: l.tokens, Capacity: l.capacity, Rate: l.rate}
+	if l.grant > 0 {
+		status.Grant = int(l.grant)
+		status.GrantUntil = l.grantUntil
+	}
+	return status
+}
+
+// refill adds the tokens accumulated since the last refill and drops an expired grant.
+// The caller must hold mu.
+func (l *Limiter) refill(now time.Time) {
+	if l.grant > 0 && !now.Before(l.grantUntil) {
+		l.capacity -= l.grant
+		l.grant = 0
+	}
+
+	// Add tokens based on elapsed time
+	elapsed := now.Sub(l.lastTime).Seconds()
+	l.lastTime = now
+	l.tokens += elapsed * l.rate
+
+	// Cap tokens at capacity
+	if l.tokens > l.capacity {
+		l.tokens = l.capacity
+	}
+}
+
 // ResetTokens resets the token count for the limiter.
 // This is useful for administrative actions or testing.
 func (l *Limiter) ResetTokens() {
@@ -257,3 +257,92 @@ func TestLimiter_ResetTokens(t *testing.T) {
 		}
 	})
 }
+
+func TestLimiter_RetryAfter(t *testing.T) {
+	t.Run("No wait while tokens are left", func(t *testing.T) {
+		// Arrange
+		limiter := NewLimiter(1, 2)
+
+		// Act & Assert
+		assert.Zero(t, limiter.RetryAfter())
+	})
+
+	t.Run("Wait until the next token is added", func(t *testing.T) {
+		// Arrange
+		limiter := NewLimiter(2, 1)
+		require.True(t, limiter.Allow())
+
+		// Act
+		wait := limiter.RetryAfter()
+
+		// Assert - one token takes half a second at two tokens per second
+		assert.Greater(t, wait, 400*time.Millisecond)
+		assert.LessOrEqual(t, wait, 500*time.Millisecond)
+	})
+
+	t.Run("Does not consume tokens", func(t *testing.T) {
+		// Arrange
+		limiter := NewLimiter(1, 1)
+
+		// Act
+		limiter.RetryAfter()
+
+		// Assert
+		assert.True(t, limiter.Allow())
+	})
+
+	t.Run("Zero rate has no known wait", func(t *testing.T) {
+		// Arrange
+		limiter := NewLimiter(0, 1)
+		require.True(t, limiter.Allow())
+
+		// Act & Assert
+		assert.Zero(t, limiter.RetryAfter())
+	})
+}
+
+func TestLimiter_Grant(t *testing.T) {
+	t.Run("Adds burst tokens until expiry", func(t *testing.T) {
+		// Arrange
+		limiter := NewLimiter(0, 2)
+
+		// Act
+		limiter.Grant(3, time.Now().Add(time.Hour))
+
+		// Assert
+		status := limiter.Status()
+		assert.Equal(t, float64(5), status.Capacity)
+		assert.Equal(t, 3, status.Grant)
+		for i := 0; i < 5; i++ {
+			assert.True(t, limiter.Allow(), "Expected request %d to be allowed with the grant", i+1)
+		}
+		assert.False(t, limiter.Allow())
+	})
+
+	t.Run("Expired grant restores the capacity", func(t *testing.T) {
+		// Arrange
+		limiter := NewLimiter(0, 2)
+		limiter.Grant(3, time.Now().Add(50*time.Millisecond))
+
+		// Act
+		time.Sleep(60 * time.Millisecond)
+
+		// Assert
+		status := limiter.Status()
+		assert.Equal(t, float64(2), status.Capacity)
+		assert.Equal(t, float64(2), status.Tokens)
+		assert.Zero(t, status.Grant)
+	})
+
+	t.Run("New grant replaces the previous one", func(t *testing.T) {
+		// Arrange
+		limiter := NewLimiter(0, 2)
+		limiter.Grant(3, time.Now().Add(time.Hour))
+
+		// Act
+		limiter.Grant(1, time.Now().Add(time.Hour))
+
+		// Assert
+		assert.Equal(t, float64(3), limiter.Status().Capacity)
+	})
+}
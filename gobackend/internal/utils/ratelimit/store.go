@@ -15,6 +15,10 @@ type Store struct {
 	// rates defines different rate limits for different client types
 	rates map[string]Rate
 
+	// grants holds the unexpired burst grants by client, so limiters recreated after a
+	// cleanup keep them
+	grants map[string]grant
+
 	// mu protects concurrent access to the limiters map
 	mu sync.RWMutex
 
@@ -22,6 +26,12 @@ type Store struct {
 	cleanupInterval time.Duration
 }
 
+// grant is extra capacity granted to a client until a point in time.
+type grant struct {
+	burst int
+	until time.Time
+}
+
 // NewStore creates a new store for managing rate limiters.
 //
 // Parameters:
@@ -34,6 +44,7 @@ func NewStore(defaultRate Rate, cleanupInterval time.Duration) *Store {
 	store := &Store{
 		limiters:        make(map[string]*Limiter),
 		rates:           make(map[string]Rate),
+		grants:          make(map[string]grant),
 		cleanupInterval: cleanupInterval,
 	}
 
@@ -73,14 +84,72 @@ func (s *Store) GetLimiter(clientID string, category string) *Limiter {
 	// Create a new limiter
 	limiter = NewLimiter(rate.RequestsPerSecond, rate.Burst)
 
-	// Store the limiter
+	// Store the limiter, unless another request created one meanwhile
 	s.mu.Lock()
+	defer s.mu.Unlock()
+	if existing, ok := s.limiters[clientID]; ok {
+		return existing
+	}
+	if g, ok := s.grants[clientID]; ok {
+		limiter.Grant(g.burst, g.until)
+	}
 	s.limiters[clientID] = limiter
-	s.mu.Unlock()
 
 	return limiter
 }
 
+// Grant raises the capacity of a client's limiter by burst tokens until the given time.
+// The grant outlives the limiter, so it survives a cleanup of the store.
+//
+// Parameters:
+//   - clientID: The unique identifier for the client
+//   - category: The category of the client's limiter, if it has to be created
+//   - burst: The extra number of requests the client may send at once
+//   - until: When the extra capacity expires
+func (s *Store) Grant(clientID, category string, burst int, until time.Time) {
+	limiter := s.GetLimiter(clientID, category)
+
+	s.mu.Lock()
+	s.grants[clientID] = grant{burst: burst, until: until}
+	s.mu.Unlock()
+
+	limiter.Grant(burst, until)
+}
+
+// Statuses returns the current state of every limiter in the store by client.
+//
+// Returns:
+//   - The status of each client's limiter
+func (s *Store) Statuses() map[string]Status {
+	s.mu.RLock()
+	limiters := make(map[string]*Limiter, len(s.limiters))
+	for clientID, limiter := range s.limiters {
+		limiters[clientID] = limiter
+	}
+	s.mu.RUnlock()
+
+	statuses := make(map[string]Status, len(limiters))
+	for clientID, limiter := range limiters {
+		statuses[clientID] = limiter.Status()
+	}
+	return statuses
+}
+
+// Rates returns the configured rate of every category.
+//
+// Returns:
+//   - The rates by category
+func (s *Store) Rates() map[string]Rate {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	rates := make(map[string]Rate, len(s.rates))
+	for category, rate := range s.rates {
+		rates[category] = rate
+	}
+	return rates
+}
+
 // SetRate sets a rate limit for a specific category.
 //
 // Parameters:
@@ -109,6 +178,13 @@ func (s *Store) cleanup() {
 	s.mu.Lock()
 	defer s.mu.Unlock()
 
+	now := time.Now()
+	for clientID, g := range s.grants {
+		if !now.Before(g.until) {
+			delete(s.grants, clientID)
+		}
+	}
+
 	// In a more advanced implementation, you might add an
 	// lastAccess field to Limiter and remove those that
 	// haven't been accessed recently
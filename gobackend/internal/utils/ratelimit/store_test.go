@@ -274,3 +274,47 @@ func TestStore_cleanupRoutine(t *testing.T) {
 		assert.Equal(t, 0, count, "Cleanup routine should have removed all limiters")
 	})
 }
+
+func TestStore_Grant(t *testing.T) {
+	t.Run("Grant survives a cleanup of the limiters", func(t *testing.T) {
+		// Arrange
+		store := NewStore(Rate{RequestsPerSecond: 0, Burst: 1}, time.Minute)
+		store.Grant("user:1", "default", 4, time.Now().Add(time.Hour))
+
+		// Act - drop the limiters as a cleanup of a full store does
+		store.mu.Lock()
+		store.limiters = make(map[string]*Limiter)
+		store.mu.Unlock()
+
+		// Assert
+		status := store.GetLimiter("user:1", "default").Status()
+		assert.Equal(t, float64(5), status.Capacity)
+		assert.Equal(t, 4, status.Grant)
+	})
+
+	t.Run("Cleanup drops expired grants", func(t *testing.T) {
+		// Arrange
+		store := NewStore(Rate{RequestsPerSecond: 0, Burst: 1}, time.Minute)
+		store.Grant("user:1", "default", 4, time.Now().Add(-time.Second))
+
+		// Act
+		store.cleanup()
+
+		// Assert
+		assert.Empty(t, store.grants)
+	})
+
+	t.Run("Statuses report every limiter", func(t *testing.T) {
+		// Arrange
+		store := NewStore(Rate{RequestsPerSecond: 0, Burst: 3}, time.Minute)
+		require.True(t, store.GetLimiter("10.0.0.1", "default").Allow())
+
+		// Act
+		statuses := store.Statuses()
+
+		// Assert
+		assert.Len(t, statuses, 1)
+		assert.Equal(t, float64(2), statuses["10.0.0.1"].Tokens)
+		assert.Equal(t, float64(3), statuses["10.0.0.1"].Capacity)
+	})
+}
@@ -0,0 +1,292 @@
+// Package utils provides utility functions and helpers for the application.
+// This file implements the redaction of response fields by the caller's role.
+//
+// A model declares which roles must not see a field with a struct tag listing them:
+//
+//	Email string `json:"email" redact:"support"`
+//
+// The authentication middleware attaches the caller's role to the response writer
+// with WithResponseRole, and every JSON, XML and NDJSON response written through
+// this package leaves out the fields redacted for that role. Handlers keep
+// returning full models; the redaction is enforced here for all of them.
+package utils
+
+import (
+	"bytes"
+	"encoding"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"reflect"
+	"strings"
+	"sync"
+
+	"github.com/rs/zerolog/log"
+)
+
+// RedactTagName is the struct tag listing the roles that must not see a field in responses.
+const RedactTagName = "redact"
+
+// roleResponseWriter carries the role of the caller a response is written for.
+type roleResponseWriter struct {
+	http.ResponseWriter
+	role string
+}
+
+// Unwrap returns the wrapped writer, so http.ResponseController reaches it.
+func (w *roleResponseWriter) Unwrap() http.ResponseWriter {
+	return w.ResponseWriter
+}
+
+// WithResponseRole attaches the caller's role to a response writer, so responses
+// written to it leave out the fields redacted for the role.
+//
+// Parameters:
+//   - w: The HTTP response writer
+//   - role: The role of the authenticated caller
+//
+// Returns:
+//   - The writer to pass on to the handler
+func WithResponseRole(w http.ResponseWriter, role string) http.ResponseWriter {
+	if rw, ok := w.(*roleResponseWriter); ok {
+		return &roleResponseWriter{ResponseWriter: rw.ResponseWriter, role: role}
+	}
+	return &roleResponseWriter{ResponseWriter: w, role: role}
+}
+
+// ResponseRole returns the role attached to a response writer.
+//
+// Parameters:
+//   - w: The HTTP response writer
+//
+// Returns:
+//   - The role of the caller
+//   - false if no role is attached
+func ResponseRole(w http.ResponseWriter) (string, bool) {
+	rw, ok := w.(*roleResponseWriter)
+	if !ok {
+		return "", false
+	}
+	return rw.role, true
+}
+
+// redactForWriter redacts data for the role attached to w, if any.
+func redactForWriter(w http.ResponseWriter, data interface{}) interface{} {
+	role, ok := ResponseRole(w)
+	if !ok {
+		return data
+	}
+
+	// Redact the data of an envelope by its dynamic type, so the envelope's
+	// interface field doesn't force every response through the redaction
+	if response, ok := data.(Response); ok {
+		if list, ok := response.Data.(ListEnvelope); ok {
+			list.Items = RedactForRole(list.Items, role)
+			response.Data = list
+		} else {
+			response.Data = RedactForRole(response.Data, role)
+		}
+		return response
+	}
+	return RedactForRole(data, role)
+}
+
+// RedactForRole removes the fields a role must not see from data.
+// Data without such fields is returned unchanged. Otherwise the JSON representation
+// of data is returned, without the redacted fields, so it encodes like data would.
+//
+// Parameters:
+//   - data: The response data
+//   - role: The role of the caller
+//
+// Returns:
+//   - The data to encode
+func RedactForRole(data interface{}, role string) interface{} {
+	if data == nil || role == "" || !typeRedacts(reflect.TypeOf(data), role) {
+		return data
+	}
+
+	encoded, err := json.Marshal(data)
+	if err != nil {
+		// Leave the error to the encoder of the response
+		return data
+	}
+
+	var generic interface{}
+	decoder := json.NewDecoder(bytes.NewReader(encoded))
+	decoder.UseNumber()
+	if err := decoder.Decode(&generic); err != nil {
+		log.Error().Err(err).Msg("Failed to decode response for redaction")
+		return data
+	}
+
+	redactValue(reflect.ValueOf(data), generic, role)
+	return generic
+}
+
+// redactKey identifies a cached redaction check of a type for a role.
+type redactKey struct {
+	t    reflect.Type
+	role string
+}
+
+// redactingTypes caches whether a type can hold fields redacted for a role.
+var redactingTypes sync.Map
+
+// typeRedacts reports whether values of a type can hold fields redacted for a role.
+// Interface fields can hold anything, so types with them are always redacted.
+func typeRedacts(t reflect.Type, role string) bool {
+	key := redactKey{t: t, role: role}
+	if cached, ok := redactingTypes.Load(key); ok {
+		return cached.(bool)
+	}
+	redacts := collectRedacts(t, role, make(map[reflect.Type]bool))
+	redactingTypes.Store(key, redacts)
+	return redacts
+}
+
+// collectRedacts walks a type for fields redacted for a role.
+// Types already on the path are skipped, so recursive types terminate.
+func collectRedacts(t reflect.Type, role string, visiting map[reflect.Type]bool) bool {
+	for t.Kind() == reflect.Pointer || t.Kind() == reflect.Slice || t.Kind() == reflect.Array || t.Kind() == reflect.Map {
+		t = t.Elem()
+	}
+	if t.Kind() == reflect.Interface {
+		return true
+	}
+	if t.Kind() != reflect.Struct || visiting[t] || marshalsItself(t) {
+		return false
+	}
+	visiting[t] = true
+	defer delete(visiting, t)
+
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		if !field.IsExported() && !field.Anonymous {
+			continue
+		}
+		if redactsFor(field, role) || collectRedacts(field.Type, role, visiting) {
+			return true
+		}
+	}
+	return false
+}
+
+// redactValue removes the fields redacted for a role from node, the decoded JSON of v.
+func redactValue(v reflect.Value, node interface{}, role string) {
+	for v.Kind() == reflect.Pointer || v.Kind() == reflect.Interface {
+		if v.IsNil() {
+			return
+		}
+		v = v.Elem()
+	}
+	if marshalsItself(v.Type()) {
+		return
+	}
+
+	switch v.Kind() {
+	case reflect.Struct:
+		if object, ok := node.(map[string]interface{}); ok {
+			redactStruct(v, object, role)
+		}
+	case reflect.Slice, reflect.Array:
+		items, ok := node.([]interface{})
+		if !ok {
+			return
+		}
+		for i := 0; i < v.Len() && i < len(items); i++ {
+			redactValue(v.Index(i), items[i], role)
+		}
+	case reflect.Map:
+		object, ok := node.(map[string]interface{})
+		if !ok {
+			return
+		}
+		iter := v.MapRange()
+		for iter.Next() {
+			redactValue(iter.Value(), object[mapKeyName(iter.Key())], role)
+		}
+	}
+}
+
+// redactStruct removes the fields redacted for a role from object, the decoded JSON of v.
+func redactStruct(v reflect.Value, object map[string]interface{}, role string) {
+	t := v.Type()
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		name, _, _ := strings.Cut(field.Tag.Get("json"), ",")
+
+		// Untagged embedded structs are flattened into their parent in JSON
+		if field.Anonymous && name == "" {
+			embedded := v.Field(i)
+			if embedded.Kind() == reflect.Pointer {
+				if embedded.IsNil() {
+					continue
+				}
+				embedded = embedded.Elem()
+			}
+			if embedded.Kind() == reflect.Struct {
+				redactStruct(embedded, object, role)
+				continue
+			}
+		}
+		if !field.IsExported() || field.Tag.Get("json") == "-" {
+			continue
+		}
+		if name == "" {
+			name = field.Name
+		}
+
+		if redactsFor(field, role) {
+			delete(object, name)
+			continue
+		}
+		if child, ok := object[name]; ok {
+			redactValue(v.Field(i), child, role)
+		}
+	}
+}
+
+// redactsFor reports whether the redact tag of a field lists a role.
+func redactsFor(field reflect.StructField, role string) bool {
+	tag, ok := field.Tag.Lookup(RedactTagName)
+	if !ok {
+		return false
+	}
+	for _, redacted := range strings.Split(tag, ",") {
+		if strings.TrimSpace(redacted) == role {
+			return true
+		}
+	}
+	return false
+}
+
+// marshalerTypes are the interfaces of types that choose their own JSON representation.
+var marshalerTypes = []reflect.Type{
+	reflect.TypeOf((*json.Marshaler)(nil)).Elem(),
+	reflect.TypeOf((*encoding.TextMarshaler)(nil)).Elem(),
+}
+
+// marshalsItself reports whether a type chooses its own JSON representation, such as
+// time.Time. Its fields don't map to JSON keys, so they are not redacted.
+func marshalsItself(t reflect.Type) bool {
+	for _, marshaler := range marshalerTypes {
+		if t.Implements(marshaler) || reflect.PointerTo(t).Implements(marshaler) {
+			return true
+		}
+	}
+	return false
+}
+
+// mapKeyName returns the JSON object key of a map key.
+func mapKeyName(key reflect.Value) string {
+	if key.Kind() == reflect.String {
+		return key.String()
+	}
+	if marshaler, ok := key.Interface().(encoding.TextMarshaler); ok {
+		if text, err := marshaler.MarshalText(); err == nil {
+			return string(text)
+		}
+	}
+	return fmt.Sprint(key.Interface())
+}
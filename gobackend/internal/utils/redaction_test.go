@@ -0,0 +1,101 @@
+package utils_test
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/yasinhessnawi1/Hideme_Backend/internal/utils"
+)
+
+type redactedEntity struct {
+	ID   int64  `json:"id"`
+	Text string `json:"text" redact:"auditor"`
+}
+
+type redactedProfile struct {
+	Username string           `json:"username"`
+	Email    string           `json:"email,omitempty" redact:"support, auditor"`
+	Entities []redactedEntity `json:"entities"`
+	Created  time.Time        `json:"created"`
+}
+
+type redactedAccount struct {
+	redactedProfile
+	Extra map[string]interface{} `json:"extra"`
+}
+
+func TestRedactForRole(t *testing.T) {
+	account := redactedAccount{
+		redactedProfile: redactedProfile{
+			Username: "ola",
+			Email:    "ola@example.com",
+			Entities: []redactedEntity{{ID: 1, Text: "Ola Nordmann"}},
+			Created:  time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC),
+		},
+		Extra: map[string]interface{}{"entity": redactedEntity{ID: 2, Text: "Oslo"}},
+	}
+
+	encode := func(role string) map[string]interface{} {
+		encoded, err := json.Marshal(utils.RedactForRole(account, role))
+		require.NoError(t, err)
+		var decoded map[string]interface{}
+		require.NoError(t, json.Unmarshal(encoded, &decoded))
+		return decoded
+	}
+
+	t.Run("Unrestricted role", func(t *testing.T) {
+		// Values without redacted fields are returned as they are
+		entity := redactedEntity{ID: 1, Text: "Ola Nordmann"}
+		assert.Equal(t, entity, utils.RedactForRole(entity, "support"))
+
+		decoded := encode("admin")
+		assert.Equal(t, "ola@example.com", decoded["email"])
+	})
+
+	t.Run("Support", func(t *testing.T) {
+		decoded := encode("support")
+		assert.NotContains(t, decoded, "email")
+		assert.Equal(t, "ola", decoded["username"])
+		assert.Equal(t, "Ola Nordmann", decoded["entities"].([]interface{})[0].(map[string]interface{})["text"])
+		assert.Equal(t, "2024-01-02T03:04:05Z", decoded["created"])
+	})
+
+	t.Run("Auditor", func(t *testing.T) {
+		decoded := encode("auditor")
+		assert.NotContains(t, decoded, "email")
+		entity := decoded["entities"].([]interface{})[0].(map[string]interface{})
+		assert.NotContains(t, entity, "text")
+		assert.Equal(t, float64(1), entity["id"])
+		assert.NotContains(t, decoded["extra"].(map[string]interface{})["entity"], "text")
+	})
+}
+
+func TestJSON_ResponseRole(t *testing.T) {
+	entity := redactedEntity{ID: 1, Text: "Ola Nordmann"}
+
+	t.Run("Without role", func(t *testing.T) {
+		rr := httptest.NewRecorder()
+		utils.JSON(rr, http.StatusOK, entity)
+		assert.Contains(t, rr.Body.String(), `"text":"Ola Nordmann"`)
+	})
+
+	t.Run("Auditor", func(t *testing.T) {
+		rr := httptest.NewRecorder()
+		w := utils.WithResponseRole(rr, "auditor")
+
+		role, ok := utils.ResponseRole(w)
+		assert.True(t, ok)
+		assert.Equal(t, "auditor", role)
+
+		utils.Paginated(w, http.StatusOK, []redactedEntity{entity}, 1, 10, 1)
+		assert.Equal(t, http.StatusOK, rr.Code)
+		assert.NotContains(t, rr.Body.String(), "Ola Nordmann")
+		assert.Contains(t, rr.Body.String(), `"total_items":1`)
+	})
+}
@@ -14,6 +14,7 @@ package utils
 
 import (
 	"encoding/json"
+	"errors"
 	"fmt"
 	"net/http"
 	"net/url"
@@ -31,6 +32,7 @@ type Response struct {
 	Data    interface{} `json:"data,omitempty"`  // The response data (omitted for error responses)
 	Error   *ErrorInfo  `json:"error,omitempty"` // Error information (omitted for successful responses)
 	Meta    *MetaInfo   `json:"meta,omitempty"`  // Metadata such as pagination information
+	Batch   *BatchInfo  `json:"batch,omitempty"` // The outcome of each item of a batch request
 }
 
 // ErrorInfo represents error information in the response.
@@ -53,8 +55,10 @@ type MetaInfo struct {
 // PaginationParams contains parameters for pagination.
 // This struct is used to extract and validate pagination parameters from requests.
 type PaginationParams struct {
-	Page     int // The requested page number
-	PageSize int // The requested page size
+	Page     int           // The requested page number
+	PageSize int           // The requested page size
+	Keyset   bool          // Whether the list is paged by keyset cursors instead of page numbers
+	After    *KeysetCursor // The last item of the previous page of a keyset-paged list; nil for the first page
 }
 
 // JSON sends a JSON response with the given status code and data.
@@ -93,7 +97,7 @@ func JsonFile(w http.ResponseWriter, data interface{}, filename string) {
 	}
 
 	// Marshal the data to JSON with pretty-printing
-	jsonData, err := json.MarshalIndent(data, "", "  ")
+	jsonData, err := json.MarshalIndent(redactForWriter(w, data), "", "  ")
 	if err != nil {
 		log.Error().Err(err).Msg("Failed to marshal JSON file response")
 		w.WriteHeader(http.StatusInternalServerError)
@@ -159,27 +163,41 @@ func Error(w http.ResponseWriter, statusCode int, code, message string, details
 // The function extracts the error code, message, and details from the AppError
 // and sends an appropriate error response.
 func ErrorFromAppError(w http.ResponseWriter, err *AppError) {
-	// Extract error code from the underlying error
+	info := errorInfoFromAppError(err)
+
+	// Send the error response
+	Error(w, err.StatusCode, info.Code, info.Message, info.Details)
+}
+
+// errorCodes maps the kinds of errors to the error codes sent to clients.
+var errorCodes = []struct {
+	err  error
+	code string
+}{
+	{ErrNotFound, constants.CodeNotFound},
+	{ErrBadRequest, constants.CodeBadRequest},
+	{ErrUnauthorized, constants.CodeUnauthorized},
+	{ErrForbidden, constants.CodeForbidden},
+	{ErrValidation, constants.CodeValidationError},
+	{ErrDuplicate, constants.CodeDuplicateResource},
+	{ErrInvalidCredentials, constants.CodeInvalidCredentials},
+	{ErrExpiredToken, constants.CodeTokenExpired},
+	{ErrInvalidToken, constants.CodeTokenInvalid},
+	{ErrQuotaExceeded, constants.CodeQuotaExceeded},
+	{ErrUploadLimit, constants.CodeUploadLimitExceeded},
+	{ErrUploadIntegrity, constants.CodeUploadIntegrityFailed},
+	{ErrRequestCanceled, constants.CodeRequestCanceled},
+}
+
+// errorInfoFromAppError converts an AppError to the error information sent to clients.
+func errorInfoFromAppError(err *AppError) *ErrorInfo {
+	// Extract error code from the kind of the underlying error
 	errCode := constants.CodeInternalError
-	switch err.Err {
-	case ErrNotFound:
-		errCode = constants.CodeNotFound
-	case ErrBadRequest:
-		errCode = constants.CodeBadRequest
-	case ErrUnauthorized:
-		errCode = constants.CodeUnauthorized
-	case ErrForbidden:
-		errCode = constants.CodeForbidden
-	case ErrValidation:
-		errCode = constants.CodeValidationError
-	case ErrDuplicate:
-		errCode = constants.CodeDuplicateResource
-	case ErrInvalidCredentials:
-		errCode = constants.CodeInvalidCredentials
-	case ErrExpiredToken:
-		errCode = constants.CodeTokenExpired
-	case ErrInvalidToken:
-		errCode = constants.CodeTokenInvalid
+	for _, kind := range errorCodes {
+		if errors.Is(err.Err, kind.err) {
+			errCode = kind.code
+			break
+		}
 	}
 
 	// Create error details if field is present
@@ -190,8 +208,21 @@ func ErrorFromAppError(w http.ResponseWriter, err *AppError) {
 		}
 	}
 
-	// Send the error response
-	Error(w, err.StatusCode, errCode, err.Message, details)
+	// Include any structured details, such as the challenge a client must complete
+	if len(err.Details) > 0 {
+		if details == nil {
+			details = make(map[string]string, len(err.Details))
+		}
+		for key, value := range err.Details {
+			details[key] = fmt.Sprint(value)
+		}
+	}
+
+	return &ErrorInfo{
+		Code:    errCode,
+		Message: err.Message,
+		Details: details,
+	}
 }
 
 // Paginated sends a paginated response with the given status code, data, and pagination info.
@@ -208,14 +239,18 @@ func ErrorFromAppError(w http.ResponseWriter, err *AppError) {
 // The function automatically calculates the total number of pages based on the page size
 // and total items.
 func Paginated(w http.ResponseWriter, statusCode int, data interface{}, page, pageSize, totalItems int) {
+	SendJSON(w, statusCode, paginatedResponse(data, page, pageSize, totalItems))
+}
+
+// paginatedResponse creates a successful response with pagination metadata.
+func paginatedResponse(data interface{}, page, pageSize, totalItems int) Response {
 	// Calculate total pages
 	totalPages := totalItems / pageSize
 	if totalItems%pageSize > 0 {
 		totalPages++
 	}
 
-	// Create a successful response with pagination metadata
-	response := Response{
+	return Response{
 		Success: constants.ResponseSuccess,
 		Data:    data,
 		Meta: &MetaInfo{
@@ -225,8 +260,6 @@ func Paginated(w http.ResponseWriter, statusCode int, data interface{}, page, pa
 			TotalPages: totalPages,
 		},
 	}
-
-	SendJSON(w, statusCode, response)
 }
 
 // SendJSON is a helper function to send JSON data with proper headers.
@@ -241,8 +274,8 @@ func SendJSON(w http.ResponseWriter, statusCode int, data interface{}) {
 	w.Header().Set(constants.HeaderContentType, constants.ContentTypeJSON)
 	w.WriteHeader(statusCode)
 
-	// Marshal the data to JSON
-	jsonData, err := json.Marshal(data)
+	// Marshal the data to JSON, without the fields the caller's role must not see
+	jsonData, err := json.Marshal(redactForWriter(w, data))
 	if err != nil {
 		// If marshaling fails, log the error and send a simple error response
 		log.Error().Err(err).Msg("Failed to marshal JSON response")
@@ -339,6 +372,26 @@ func Conflict(w http.ResponseWriter, message string) {
 	Error(w, constants.StatusConflict, constants.CodeConflict, message, nil)
 }
 
+// ConflictWithData sends a 409 Conflict response with the given message and the
+// conflicting data, so the client can resolve the conflict without another request.
+//
+// Parameters:
+//   - w: The HTTP response writer
+//   - message: A human-readable error message
+//   - data: The conflicting data to include in the response
+func ConflictWithData(w http.ResponseWriter, message string, data interface{}) {
+	response := Response{
+		Success: constants.ResponseFailure,
+		Data:    data,
+		Error: &ErrorInfo{
+			Code:    constants.CodeConflict,
+			Message: message,
+		},
+	}
+
+	SendJSON(w, constants.StatusConflict, response)
+}
+
 // InternalServerError sends a 500 Internal Server Error response.
 // This is a convenience function for sending internal server errors.
 //
@@ -370,13 +423,24 @@ func ValidationError(w http.ResponseWriter, errors map[string]string) {
 //   - A PaginationParams struct containing the page and page size
 //
 // The function enforces minimum and maximum page sizes and provides sensible defaults.
+// A keyset cursor, or pagination=keyset on the first page, selects keyset paging; lists
+// that don't support it keep paging by page number.
 func GetPaginationParams(r *http.Request) PaginationParams {
 	// Get page and page_size parameters, with defaults
 	page := constants.DefaultPage
 	pageSize := constants.DefaultPageSize
 
-	// Parse page parameter
-	if r.URL.Query().Get(constants.QueryParamPage) != "" {
+	// Parse page parameter; the cursor of a list response selects the page as well
+	var after *KeysetCursor
+	keyset := r.URL.Query().Get(constants.QueryParamPagination) == constants.PaginationKeyset
+	if cursor := r.URL.Query().Get(constants.QueryParamCursor); cursor != "" {
+		if cursorPage, ok := decodePageCursor(cursor); ok {
+			page = cursorPage
+		} else if key, ok := decodeKeysetCursor(cursor); ok {
+			after = &key
+			keyset = true
+		}
+	} else if r.URL.Query().Get(constants.QueryParamPage) != "" {
 		parsedPage, _ := parseInt(r.URL.Query().Get(constants.QueryParamPage), constants.DefaultPage)
 		page = parsedPage
 	}
@@ -397,6 +461,8 @@ func GetPaginationParams(r *http.Request) PaginationParams {
 	return PaginationParams{
 		Page:     page,
 		PageSize: pageSize,
+		Keyset:   keyset,
+		After:    after,
 	}
 }
 
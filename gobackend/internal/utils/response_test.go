@@ -197,6 +197,16 @@ func TestErrorFromAppError(t *testing.T) {
 			},
 			wantCode: "validation_error",
 		},
+		{
+			name: "Forbidden error with details",
+			appError: &utils.AppError{
+				Err:        utils.ErrForbidden,
+				StatusCode: http.StatusForbidden,
+				Message:    "Please complete the CAPTCHA to continue",
+				Details:    map[string]any{"challenge": "captcha"},
+			},
+			wantCode: "forbidden",
+		},
 		{
 			name: "Duplicate error",
 			appError: &utils.AppError{
@@ -233,6 +243,15 @@ func TestErrorFromAppError(t *testing.T) {
 			},
 			wantCode: "token_invalid",
 		},
+		{
+			name: "Wrapped kind error",
+			appError: &utils.AppError{
+				Err:        utils.NewKindError(utils.ErrNotFound, "document not found"),
+				StatusCode: http.StatusNotFound,
+				Message:    "Document not found",
+			},
+			wantCode: "not_found",
+		},
 		{
 			name: "Default error",
 			appError: &utils.AppError{
@@ -273,6 +292,13 @@ func TestErrorFromAppError(t *testing.T) {
 					t.Errorf("Expected field %s in error details", tt.appError.Field)
 				}
 			}
+
+			for key, value := range tt.appError.Details {
+				details := errorInfo["details"].(map[string]interface{})
+				if details[key] != value {
+					t.Errorf("Expected detail %s = %v, got %v", key, value, details[key])
+				}
+			}
 		})
 	}
 }
@@ -509,6 +535,30 @@ func TestConflict(t *testing.T) {
 	}
 }
 
+func TestConflictWithData(t *testing.T) {
+	rr := httptest.NewRecorder()
+	utils.ConflictWithData(rr, "Changed elsewhere", map[string]int{"version": 3})
+
+	if status := rr.Code; status != http.StatusConflict {
+		t.Errorf("handler returned wrong status code: got %v want %v", status, http.StatusConflict)
+	}
+
+	var response map[string]interface{}
+	if err := json.Unmarshal(rr.Body.Bytes(), &response); err != nil {
+		t.Fatalf("Could not parse response body: %v", err)
+	}
+
+	errorInfo := response["error"].(map[string]interface{})
+	if errorInfo["code"] != "conflict" {
+		t.Errorf("expected error code conflict, got %v", errorInfo["code"])
+	}
+
+	data := response["data"].(map[string]interface{})
+	if data["version"] != float64(3) {
+		t.Errorf("expected version 3, got %v", data["version"])
+	}
+}
+
 func TestInternalServerError(t *testing.T) {
 	err := errors.New("something went wrong")
 	rr := httptest.NewRecorder()
@@ -0,0 +1,167 @@
+// Package utils provides utility functions and helpers for the application.
+// This file implements streaming of list responses as newline-delimited JSON (NDJSON).
+//
+// Streaming writes each row as soon as it is scanned instead of buffering the
+// full result in memory. Writes block while the client is not reading, which in
+// turn pauses the database scan feeding the stream, so memory stays flat no
+// matter how many rows are exported.
+package utils
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"mime"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/rs/zerolog/log"
+
+	"github.com/yasinhessnawi1/Hideme_Backend/internal/constants"
+)
+
+// WantsNDJSON reports whether the client asked for a newline-delimited JSON stream.
+//
+// Parameters:
+//   - r: The HTTP request
+//
+// Returns:
+//   - true if the Accept header lists application/x-ndjson
+func WantsNDJSON(r *http.Request) bool {
+	for _, accepted := range strings.Split(r.Header.Get(constants.HeaderAccept), ",") {
+		mediaType, _, err := mime.ParseMediaType(strings.TrimSpace(accepted))
+		if err == nil && mediaType == constants.ContentTypeNDJSON {
+			return true
+		}
+	}
+	return false
+}
+
+// NDJSONStream writes rows to a response as newline-delimited JSON, one object per line.
+// The status and headers are sent with the first row, so errors that occur before
+// any row was written can still be answered with a regular error response.
+type NDJSONStream struct {
+	w          http.ResponseWriter
+	controller *http.ResponseController
+	encoder    *json.Encoder
+	started    bool
+	pending    int
+}
+
+// NewNDJSONStream creates a new NDJSONStream writing to w.
+//
+// Parameters:
+//   - w: The HTTP response writer
+//
+// Returns:
+//   - A stream ready to write rows
+func NewNDJSONStream(w http.ResponseWriter) *NDJSONStream {
+	return &NDJSONStream{
+		w:          w,
+		controller: http.NewResponseController(w),
+		encoder:    json.NewEncoder(w),
+	}
+}
+
+// Write writes a single row.
+// The call blocks while the client is not accepting data; the client gets
+// constants.StreamWriteTimeout per chunk before it is disconnected.
+//
+// Parameters:
+//   - row: The value to encode as one line
+//
+// Returns:
+//   - An error if the row cannot be written, e.g. because the client went away
+func (s *NDJSONStream) Write(row interface{}) error {
+	if !s.started {
+		s.start()
+	}
+
+	s.extendDeadline()
+	if err := s.encoder.Encode(redactForWriter(s.w, row)); err != nil {
+		return fmt.Errorf("failed to write stream row: %w", err)
+	}
+
+	s.pending++
+	if s.pending >= constants.StreamFlushRows {
+		return s.flush()
+	}
+
+	return nil
+}
+
+// Finish completes the stream.
+// If err is set and no row has been written yet, a regular JSON error response is
+// sent. Otherwise the status has already been sent, so the error is reported as a
+// final line in the standard error format, telling the client the stream is incomplete.
+//
+// Parameters:
+//   - err: The error that ended the stream, or nil if all rows were written
+func (s *NDJSONStream) Finish(err error) {
+	if err == nil {
+		if !s.started {
+			s.start()
+		}
+		if flushErr := s.flush(); flushErr != nil {
+			log.Warn().Err(flushErr).Msg("Failed to flush stream")
+		}
+		return
+	}
+
+	if !s.started {
+		ErrorFromAppError(s.w, ParseError(err))
+		return
+	}
+
+	// The client went away; there is nobody left to tell
+	if errors.Is(err, context.Canceled) {
+		log.Debug().Err(err).Msg("Client disconnected during stream")
+		return
+	}
+
+	log.Error().Err(err).Msg("Stream ended with an error")
+
+	s.extendDeadline()
+	trailer := Response{
+		Success: constants.ResponseFailure,
+		Error:   errorInfoFromAppError(ParseError(err)),
+	}
+	if writeErr := s.encoder.Encode(trailer); writeErr != nil {
+		log.Warn().Err(writeErr).Msg("Failed to write stream error")
+		return
+	}
+	if flushErr := s.flush(); flushErr != nil {
+		log.Warn().Err(flushErr).Msg("Failed to flush stream")
+	}
+}
+
+// start sends the status and headers of the stream.
+func (s *NDJSONStream) start() {
+	s.started = true
+
+	s.w.Header().Set(constants.HeaderContentType, constants.ContentTypeNDJSON)
+	s.w.Header().Set(constants.HeaderCacheControl, constants.CacheControlNoStore)
+	// Ask reverse proxies not to buffer the stream
+	s.w.Header().Set(constants.HeaderXAccelBuffering, "no")
+	s.w.WriteHeader(constants.StatusOK)
+}
+
+// flush sends the buffered rows to the client.
+func (s *NDJSONStream) flush() error {
+	s.pending = 0
+	if err := s.controller.Flush(); err != nil && !errors.Is(err, http.ErrNotSupported) {
+		return fmt.Errorf("failed to flush stream: %w", err)
+	}
+	return nil
+}
+
+// extendDeadline gives the client another constants.StreamWriteTimeout to accept data.
+// Without it the server's write timeout would cut off long exports.
+func (s *NDJSONStream) extendDeadline() {
+	err := s.controller.SetWriteDeadline(time.Now().Add(constants.StreamWriteTimeout))
+	if err != nil && !errors.Is(err, http.ErrNotSupported) {
+		log.Debug().Err(err).Msg("Failed to extend stream write deadline")
+	}
+}
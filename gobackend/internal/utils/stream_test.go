@@ -0,0 +1,122 @@
+package utils_test
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/yasinhessnawi1/Hideme_Backend/internal/constants"
+	"github.com/yasinhessnawi1/Hideme_Backend/internal/utils"
+)
+
+func TestWantsNDJSON(t *testing.T) {
+	tests := []struct {
+		accept string
+		want   bool
+	}{
+		{accept: "", want: false},
+		{accept: "application/json", want: false},
+		{accept: "application/x-ndjson", want: true},
+		{accept: "application/json, application/x-ndjson;q=0.9", want: true},
+		{accept: "*/*", want: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.accept, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodGet, "/", nil)
+			req.Header.Set(constants.HeaderAccept, tt.accept)
+
+			if got := utils.WantsNDJSON(req); got != tt.want {
+				t.Errorf("WantsNDJSON() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestNDJSONStream(t *testing.T) {
+	rr := httptest.NewRecorder()
+	stream := utils.NewNDJSONStream(rr)
+
+	// Write more rows than fit in one flush
+	rowCount := constants.StreamFlushRows + 5
+	for i := 0; i < rowCount; i++ {
+		if err := stream.Write(map[string]int{"id": i}); err != nil {
+			t.Fatalf("Write() error = %v", err)
+		}
+	}
+	stream.Finish(nil)
+
+	if rr.Code != http.StatusOK {
+		t.Errorf("Expected status 200, got %d", rr.Code)
+	}
+	if got := rr.Header().Get(constants.HeaderContentType); got != constants.ContentTypeNDJSON {
+		t.Errorf("Expected content type %s, got %s", constants.ContentTypeNDJSON, got)
+	}
+	if !rr.Flushed {
+		t.Error("Expected the stream to be flushed")
+	}
+
+	lines := strings.Split(strings.TrimSpace(rr.Body.String()), "\n")
+	if len(lines) != rowCount {
+		t.Fatalf("Expected %d lines, got %d", rowCount, len(lines))
+	}
+	if lines[1] != `{"id":1}` {
+		t.Errorf("Unexpected line: %s", lines[1])
+	}
+}
+
+func TestNDJSONStream_Empty(t *testing.T) {
+	rr := httptest.NewRecorder()
+	stream := utils.NewNDJSONStream(rr)
+	stream.Finish(nil)
+
+	if rr.Code != http.StatusOK || rr.Body.Len() != 0 {
+		t.Errorf("Expected an empty 200 response, got %d %q", rr.Code, rr.Body.String())
+	}
+}
+
+func TestNDJSONStream_ErrorBeforeFirstRow(t *testing.T) {
+	rr := httptest.NewRecorder()
+	stream := utils.NewNDJSONStream(rr)
+	stream.Finish(utils.NewNotFoundError("UserSetting", int64(1)))
+
+	// Nothing was sent yet, so a regular error response is used
+	if rr.Code != http.StatusNotFound {
+		t.Errorf("Expected status 404, got %d", rr.Code)
+	}
+	if got := rr.Header().Get(constants.HeaderContentType); got != constants.ContentTypeJSON {
+		t.Errorf("Expected content type %s, got %s", constants.ContentTypeJSON, got)
+	}
+}
+
+func TestNDJSONStream_ErrorAfterFirstRow(t *testing.T) {
+	rr := httptest.NewRecorder()
+	stream := utils.NewNDJSONStream(rr)
+
+	if err := stream.Write(map[string]int{"id": 1}); err != nil {
+		t.Fatalf("Write() error = %v", err)
+	}
+	stream.Finish(fmt.Errorf("failed to scan row: %w", errors.New("connection reset")))
+
+	// The status was already sent; the error is reported as the last line
+	if rr.Code != http.StatusOK {
+		t.Errorf("Expected status 200, got %d", rr.Code)
+	}
+
+	lines := strings.Split(strings.TrimSpace(rr.Body.String()), "\n")
+	if len(lines) != 2 {
+		t.Fatalf("Expected 2 lines, got %d", len(lines))
+	}
+
+	var trailer utils.Response
+	if err := json.Unmarshal([]byte(lines[1]), &trailer); err != nil {
+		t.Fatalf("Failed to parse trailer: %v", err)
+	}
+	if trailer.Success || trailer.Error == nil || trailer.Error.Code != constants.CodeInternalError {
+		t.Errorf("Unexpected trailer: %s", lines[1])
+	}
+}
@@ -0,0 +1,182 @@
+// Package utils provides utility functions and helpers for the application.
+// This file implements the folding of diacritics and the light stemming of English and
+// Norwegian words, so that variants of a word like "Sørensen" and "Sorensen", or "faktura"
+// and "fakturaen", can be matched as one.
+package utils
+
+import (
+	"strings"
+	"unicode"
+)
+
+// diacriticFolds maps letters with diacritics, and the Norwegian and German letters
+// commonly written without them, to their plain Latin spelling.
+var diacriticFolds = map[rune]string{
+	'à': "a", 'á': "a", 'â': "a", 'ã': "a", 'ä': "a", 'å': "a", 'ā': "a", 'ą': "a",
+	'æ': "ae", 'ç': "c", 'ć': "c", 'č': "c", 'ď': "d", 'đ': "d", 'ð': "d",
+	'è': "e", 'é': "e", 'ê': "e", 'ë': "e", 'ē': "e", 'ę': "e", 'ě': "e",
+	'ì': "i", 'í': "i", 'î': "i", 'ï': "i", 'ī': "i", 'ł': "l", 'ľ': "l",
+	'ñ': "n", 'ń': "n", 'ň': "n",
+	'ò': "o", 'ó': "o", 'ô': "o", 'õ': "o", 'ö': "o", 'ø': "o", 'ō': "o", 'ő': "o", 'œ': "oe",
+	'ř': "r", 'ś': "s", 'š': "s", 'ß': "ss", 'ť': "t", 'þ': "th",
+	'ù': "u", 'ú': "u", 'û': "u", 'ü': "u", 'ū': "u", 'ů': "u", 'ű': "u",
+	'ý': "y", 'ÿ': "y", 'ź': "z", 'ż': "z", 'ž': "z",
+}
+
+// FoldDiacritic returns the plain Latin spelling of a letter with a diacritic, keeping
+// its case, and whether the letter has one.
+//
+// Parameters:
+//   - r: The letter to fold
+//
+// Returns:
+//   - The letter's plain spelling, e.g. "o" for 'ö' and "AE" for 'Æ'
+//   - false if the letter has no diacritic to fold
+func FoldDiacritic(r rune) (string, bool) {
+	lower := unicode.ToLower(r)
+	folded, ok := diacriticFolds[lower]
+	if !ok {
+		return "", false
+	}
+	if lower != r {
+		folded = strings.ToUpper(folded)
+	}
+	return folded, true
+}
+
+// DiacriticVariants returns the letters that fold to a plain lowercase letter, e.g. 'ö',
+// 'ø' and the other letters folding to "o". Letters folding to more than one letter,
+// like 'æ', are not included.
+//
+// Parameters:
+//   - r: A plain lowercase letter
+//
+// Returns:
+//   - The lowercase letters folding to it, or nil if there are none
+func DiacriticVariants(r rune) []rune {
+	return diacriticVariants[r]
+}
+
+// diacriticVariants maps a plain lowercase letter to the letters folding to it.
+var diacriticVariants = func() map[rune][]rune {
+	variants := make(map[rune][]rune)
+	for letter, folded := range diacriticFolds {
+		if runes := []rune(folded); len(runes) == 1 {
+			variants[runes[0]] = append(variants[runes[0]], letter)
+		}
+	}
+	for _, letters := range variants {
+		sortRunes(letters)
+	}
+	return variants
+}()
+
+// sortRunes sorts letters in place, so their order does not depend on map iteration.
+func sortRunes(letters []rune) {
+	for i := 1; i < len(letters); i++ {
+		for j := i; j > 0 && letters[j] < letters[j-1]; j-- {
+			letters[j], letters[j-1] = letters[j-1], letters[j]
+		}
+	}
+}
+
+// FoldDiacritics replaces the letters with diacritics in a text with their plain Latin
+// spelling, e.g. "Sørensen" with "Sorensen".
+//
+// Parameters:
+//   - text: The text to fold
+//
+// Returns:
+//   - The folded text
+func FoldDiacritics(text string) string {
+	var b strings.Builder
+	b.Grow(len(text))
+	for _, r := range text {
+		if folded, ok := FoldDiacritic(r); ok {
+			b.WriteString(folded)
+		} else {
+			b.WriteRune(r)
+		}
+	}
+	return b.String()
+}
+
+// englishSuffixes are the inflectional endings removed from English words, longest first.
+// A final "e" or "y" is removed as well, so that "invoice" and "invoiced" share a stem.
+var englishSuffixes = []string{"'s", "ies", "ing", "es", "ed", "s", "e", "y"}
+
+// norwegianSuffixes are the inflectional endings removed from Norwegian words, longest
+// first, after the light stemming of bokmål nouns, adjectives and verbs.
+var norwegianSuffixes = []string{
+	"hetene", "hetens", "heten", "heter", "endes", "ende", "ene", "ane", "ens", "ers",
+	"ets", "er", "en", "et", "ar", "as", "es", "e", "a", "s",
+}
+
+// minStemLength is the fewest letters a stem keeps, so that short words are not reduced
+// to a prefix shared with unrelated words.
+const minStemLength = 3
+
+// MaxStemSuffixes is the most endings removed from a word, as in "faktura-en".
+const MaxStemSuffixes = 2
+
+// StemSuffixes returns the inflectional endings of a stemming language, longest first,
+// or nil if the language is not supported.
+//
+// Parameters:
+//   - language: "en" for English or "no" for Norwegian
+//
+// Returns:
+//   - The endings removed from words of the language
+func StemSuffixes(language string) []string {
+	switch language {
+	case "en":
+		return englishSuffixes
+	case "no":
+		return norwegianSuffixes
+	default:
+		return nil
+	}
+}
+
+// StemWord removes up to MaxStemSuffixes inflectional endings of a word, so that its
+// inflections share a stem: "invoice", "invoices" and "invoicing" stem to "invoic",
+// "faktura", "fakturaen" and "fakturaer" to "faktur". Words of other languages, or too
+// short to stem, are returned unchanged.
+//
+// Parameters:
+//   - word: A single word
+//   - language: "en" for English or "no" for Norwegian
+//
+// Returns:
+//   - The stem of the word, in the case it was written in
+func StemWord(word, language string) string {
+	for i := 0; i < MaxStemSuffixes; i++ {
+		stem := stripSuffix(word, language)
+		if stem == word {
+			break
+		}
+		word = stem
+	}
+	return word
+}
+
+// stripSuffix removes the longest inflectional ending of a word that leaves a stem of at
+// least minStemLength letters.
+func stripSuffix(word, language string) string {
+	lower := strings.ToLower(word)
+	for _, suffix := range StemSuffixes(language) {
+		if !strings.HasSuffix(lower, suffix) {
+			continue
+		}
+		stem := word[:len(word)-len(suffix)]
+		if len([]rune(stem)) < minStemLength {
+			continue
+		}
+		if language == "en" && suffix == "s" && strings.HasSuffix(lower, "ss") {
+			// "address" is not the plural of "addres"
+			return word
+		}
+		return stem
+	}
+	return word
+}
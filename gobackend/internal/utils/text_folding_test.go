@@ -0,0 +1,53 @@
+package utils_test
+
+import (
+	"testing"
+
+	"github.com/yasinhessnawi1/Hideme_Backend/internal/utils"
+)
+
+func TestFoldDiacritics(t *testing.T) {
+	tests := []struct {
+		text string
+		want string
+	}{
+		{"Ström", "Strom"},
+		{"Sørensen", "Sorensen"},
+		{"ÆRLIGHET", "AERLIGHET"},
+		{"Straße", "Strasse"},
+		{"plain text", "plain text"},
+	}
+	for _, tt := range tests {
+		if got := utils.FoldDiacritics(tt.text); got != tt.want {
+			t.Errorf("FoldDiacritics(%q) = %q, want %q", tt.text, got, tt.want)
+		}
+	}
+
+	if variants := string(utils.DiacriticVariants('o')); variants != "òóôõöøōő" {
+		t.Errorf("DiacriticVariants('o') = %q", variants)
+	}
+}
+
+func TestStemWord(t *testing.T) {
+	tests := []struct {
+		word     string
+		language string
+		want     string
+	}{
+		{"invoice", "en", "invoic"},
+		{"invoices", "en", "invoic"},
+		{"invoicing", "en", "invoic"},
+		{"Invoiced", "en", "Invoic"},
+		{"address", "en", "address"},
+		{"faktura", "no", "faktur"},
+		{"fakturaen", "no", "faktur"},
+		{"fakturaer", "no", "faktur"},
+		{"bil", "no", "bil"},
+		{"invoices", "de", "invoices"},
+	}
+	for _, tt := range tests {
+		if got := utils.StemWord(tt.word, tt.language); got != tt.want {
+			t.Errorf("StemWord(%q, %q) = %q, want %q", tt.word, tt.language, got, tt.want)
+		}
+	}
+}
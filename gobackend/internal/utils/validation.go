@@ -20,6 +20,7 @@ import (
 	"net/http"
 	"reflect"
 	"strings"
+	"unicode"
 
 	"github.com/go-playground/validator/v10"
 	"github.com/rs/zerolog/log"
@@ -91,15 +92,16 @@ func DecodeJSON(r *http.Request, v interface{}) error {
 		var syntaxError *json.SyntaxError
 		var unmarshalTypeError *json.UnmarshalTypeError
 		var invalidUnmarshalError *json.InvalidUnmarshalError
+		var maxBytesError *http.MaxBytesError
 
 		switch {
-		case err.Error() == "http: request body too large":
-			return NewBadRequestError(constants.MsgRequestBodyTooLarge)
+		case errors.As(err, &maxBytesError):
+			return NewBadRequestError(constants.MsgRequestBodyTooLarge).WithCause(err)
 
-		case err == io.EOF:
+		case errors.Is(err, io.EOF):
 			return NewBadRequestError(constants.MsgEmptyRequestBody)
 
-		case err == io.ErrUnexpectedEOF:
+		case errors.Is(err, io.ErrUnexpectedEOF):
 			return NewBadRequestError(constants.MsgMalformedJSON)
 
 		case strings.HasPrefix(err.Error(), "json: unknown field "):
@@ -222,6 +224,8 @@ func getErrorMessage(e validator.FieldError) string {
 		return fmt.Sprintf("Must be one of: %s", allowedValues)
 	case "alphanum":
 		return "Must contain only alphanumeric characters"
+	case "printable_text":
+		return "Must not be blank or contain control characters"
 	// Add more custom messages for other validation tags
 	default:
 		return fmt.Sprintf("Failed validation on the '%s' tag", e.Tag())
@@ -238,6 +242,9 @@ func registerCustomValidations(v *validator.Validate) {
 	if err := v.RegisterValidation("strong_password", validateStrongPassword); err != nil {
 		log.Error().Err(err).Msg("Failed to register strong_password validation")
 	}
+	if err := v.RegisterValidation("printable_text", validatePrintableText); err != nil {
+		log.Error().Err(err).Msg("Failed to register printable_text validation")
+	}
 }
 
 // validateStrongPassword is a custom validation function for password strength.
@@ -294,6 +301,29 @@ func validateStrongPassword(fl validator.FieldLevel) bool {
 	return criteria >= 3
 }
 
+// validatePrintableText is a custom validation function for free text shown in documents.
+// It rejects blank values and values containing control characters such as line breaks.
+//
+// Parameters:
+//   - fl: The field level for validation
+//
+// Returns:
+//   - true if the text is printable, false otherwise
+func validatePrintableText(fl validator.FieldLevel) bool {
+	text := fl.Field().String()
+	if strings.TrimSpace(text) == "" {
+		return false
+	}
+
+	for _, char := range text {
+		if unicode.IsControl(char) {
+			return false
+		}
+	}
+
+	return true
+}
+
 // NewValidationErrorWithDetails creates a validation error with multiple field details.
 // This is used for reporting validation errors across multiple fields.
 //
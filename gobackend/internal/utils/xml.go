@@ -0,0 +1,235 @@
+// Package utils provides utility functions and helpers for the application.
+// This file implements XML rendering of responses for clients that cannot ingest JSON.
+//
+// XML is only offered on a whitelist of read endpoints, which check WantsXML and
+// respond with the helpers below. The XML is derived from the JSON representation
+// of the response, so both formats always carry the same fields:
+//   - Object fields become child elements named like the JSON keys; keys that are not
+//     valid XML names become <entry key="..."> elements
+//   - Array values become repeated <item> elements
+//   - null values are left out
+//
+// The schemas of the whitelisted endpoints are published as XSD files.
+package utils
+
+import (
+	"bytes"
+	"encoding/json"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"mime"
+	"net/http"
+	"net/url"
+	"sort"
+	"strings"
+
+	"github.com/rs/zerolog/log"
+
+	"github.com/yasinhessnawi1/Hideme_Backend/internal/constants"
+)
+
+// XML element names used by the encoding.
+const (
+	// xmlResponseRoot is the root element of an XML response envelope.
+	xmlResponseRoot = "response"
+
+	// xmlArrayItem is the element wrapping each value of an array.
+	xmlArrayItem = "item"
+
+	// xmlMapEntry is the element used for object keys that are not valid XML names.
+	xmlMapEntry = "entry"
+)
+
+// WantsXML reports whether the client asked for an XML response.
+//
+// Parameters:
+//   - r: The HTTP request
+//
+// Returns:
+//   - true if the Accept header lists application/xml or text/xml
+func WantsXML(r *http.Request) bool {
+	for _, accepted := range strings.Split(r.Header.Get(constants.HeaderAccept), ",") {
+		mediaType, _, err := mime.ParseMediaType(strings.TrimSpace(accepted))
+		if err == nil && (mediaType == constants.MediaTypeXML || mediaType == constants.MediaTypeTextXML) {
+			return true
+		}
+	}
+	return false
+}
+
+// EncodeXML writes a value as an XML document with the given root element.
+//
+// Parameters:
+//   - w: The writer receiving the document
+//   - root: The name of the root element
+//   - data: The value to encode; it is encoded through its JSON representation
+//
+// Returns:
+//   - An error if the value cannot be represented as JSON or writing fails
+func EncodeXML(w io.Writer, root string, data interface{}) error {
+	jsonData, err := json.Marshal(data)
+	if err != nil {
+		return fmt.Errorf("failed to marshal response: %w", err)
+	}
+
+	var value interface{}
+	decoder := json.NewDecoder(bytes.NewReader(jsonData))
+	decoder.UseNumber()
+	if err := decoder.Decode(&value); err != nil {
+		return fmt.Errorf("failed to decode response: %w", err)
+	}
+
+	encoder := xml.NewEncoder(w)
+	encoder.Indent("", "  ")
+	if _, err := io.WriteString(w, xml.Header); err != nil {
+		return err
+	}
+	if err := encodeXMLValue(encoder, xml.StartElement{Name: xml.Name{Local: root}}, value); err != nil {
+		return err
+	}
+	return encoder.Flush()
+}
+
+// encodeXMLValue writes a decoded JSON value as the content of an element.
+func encodeXMLValue(encoder *xml.Encoder, start xml.StartElement, value interface{}) error {
+	if value == nil {
+		return nil
+	}
+	if err := encoder.EncodeToken(start); err != nil {
+		return err
+	}
+
+	switch v := value.(type) {
+	case map[string]interface{}:
+		keys := make([]string, 0, len(v))
+		for key := range v {
+			keys = append(keys, key)
+		}
+		sort.Strings(keys)
+		for _, key := range keys {
+			child := xml.StartElement{Name: xml.Name{Local: key}}
+			if !isXMLName(key) {
+				child = xml.StartElement{
+					Name: xml.Name{Local: xmlMapEntry},
+					Attr: []xml.Attr{{Name: xml.Name{Local: "key"}, Value: key}},
+				}
+			}
+			if err := encodeXMLValue(encoder, child, v[key]); err != nil {
+				return err
+			}
+		}
+	case []interface{}:
+		for _, item := range v {
+			if err := encodeXMLValue(encoder, xml.StartElement{Name: xml.Name{Local: xmlArrayItem}}, item); err != nil {
+				return err
+			}
+		}
+	default:
+		if err := encoder.EncodeToken(xml.CharData(fmt.Sprint(v))); err != nil {
+			return err
+		}
+	}
+
+	return encoder.EncodeToken(start.End())
+}
+
+// isXMLName reports whether a JSON key can be used as an XML element name as is.
+// Names starting with "xml" are reserved and therefore not used either.
+func isXMLName(name string) bool {
+	if name == "" || strings.HasPrefix(strings.ToLower(name), "xml") {
+		return false
+	}
+	for i, r := range name {
+		switch {
+		case r == '_' || (r >= 'a' && r <= 'z') || (r >= 'A' && r <= 'Z'):
+		case i > 0 && (r == '-' || r == '.' || (r >= '0' && r <= '9')):
+		default:
+			return false
+		}
+	}
+	return true
+}
+
+// XML sends an XML response with the given status code and data, in the same
+// envelope as JSON responses.
+//
+// Parameters:
+//   - w: The HTTP response writer
+//   - statusCode: The HTTP status code
+//   - data: The data to include in the response
+func XML(w http.ResponseWriter, statusCode int, data interface{}) {
+	SendXML(w, statusCode, Response{
+		Success: statusCode >= 200 && statusCode < 300,
+		Data:    data,
+	})
+}
+
+// PaginatedXML sends a paginated XML response, in the same envelope as Paginated.
+//
+// Parameters:
+//   - w: The HTTP response writer
+//   - statusCode: The HTTP status code
+//   - data: The items of the current page
+//   - page: The current page number
+//   - pageSize: The number of items per page
+//   - totalItems: The total number of items
+func PaginatedXML(w http.ResponseWriter, statusCode int, data interface{}, page, pageSize, totalItems int) {
+	SendXML(w, statusCode, paginatedResponse(data, page, pageSize, totalItems))
+}
+
+// SendXML encodes a response envelope as XML and sends it with proper headers.
+// The document is encoded before any headers are sent, so encoding failures can
+// still be answered with an error response.
+//
+// Parameters:
+//   - w: The HTTP response writer
+//   - statusCode: The HTTP status code
+//   - response: The response envelope
+func SendXML(w http.ResponseWriter, statusCode int, response Response) {
+	var buf bytes.Buffer
+	if err := EncodeXML(&buf, xmlResponseRoot, redactForWriter(w, response)); err != nil {
+		InternalServerError(w, fmt.Errorf("failed to encode XML response: %w", err))
+		return
+	}
+
+	w.Header().Set(constants.HeaderContentType, constants.ContentTypeXML)
+	w.WriteHeader(statusCode)
+	if _, err := w.Write(buf.Bytes()); err != nil {
+		log.Error().Err(err).Msg("Failed to write XML response")
+	}
+}
+
+// XMLFile sends data as a downloadable XML file, the XML counterpart of JsonFile.
+//
+// Parameters:
+//   - w: The HTTP response writer
+//   - root: The name of the root element
+//   - data: The data to include in the file
+//   - filename: The name of the file to be downloaded; .xml is appended if missing
+func XMLFile(w http.ResponseWriter, root string, data interface{}, filename string) {
+	if !strings.HasSuffix(strings.ToLower(filename), ".xml") {
+		filename += ".xml"
+	}
+
+	var buf bytes.Buffer
+	if err := EncodeXML(&buf, root, redactForWriter(w, data)); err != nil {
+		InternalServerError(w, fmt.Errorf("failed to encode XML file response: %w", err))
+		return
+	}
+
+	w.Header().Set(constants.HeaderContentType, constants.ContentTypeXML)
+	w.Header().Set(constants.HeaderContentLength, fmt.Sprintf("%d", buf.Len()))
+	w.Header().Set(constants.HeaderContentDisposition,
+		fmt.Sprintf("attachment; filename=\"%s\"; filename*=UTF-8''%s",
+			filename,
+			url.PathEscape(filename)))
+	w.Header().Set(constants.HeaderCacheControl, constants.CacheControlNoStore)
+	w.Header().Set(constants.HeaderPragma, constants.PragmaNoCache)
+	w.Header().Set(constants.HeaderExpires, constants.ExpiresZero)
+	w.WriteHeader(http.StatusOK)
+
+	if _, err := w.Write(buf.Bytes()); err != nil {
+		log.Error().Err(err).Msg("Failed to write XML file response")
+	}
+}
@@ -0,0 +1,94 @@
+package utils_test
+
+import (
+	"bytes"
+	"math"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/yasinhessnawi1/Hideme_Backend/internal/constants"
+	"github.com/yasinhessnawi1/Hideme_Backend/internal/utils"
+)
+
+func TestWantsXML(t *testing.T) {
+	tests := []struct {
+		accept string
+		want   bool
+	}{
+		{accept: "", want: false},
+		{accept: "application/json", want: false},
+		{accept: "application/xml", want: true},
+		{accept: "text/xml", want: true},
+		{accept: "application/json;q=0.5, application/xml", want: true},
+		{accept: "*/*", want: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.accept, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodGet, "/", nil)
+			req.Header.Set(constants.HeaderAccept, tt.accept)
+
+			if got := utils.WantsXML(req); got != tt.want {
+				t.Errorf("WantsXML() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestEncodeXML(t *testing.T) {
+	data := map[string]interface{}{
+		"id":      int64(12345678901),
+		"name":    "Ola & Kari",
+		"tags":    []string{"a", "b"},
+		"missing": nil,
+		"placeholders": map[string]string{
+			"EMAIL_ADDRESS": "[EMAIL]",
+			"1ST":           "[FIRST]",
+		},
+	}
+
+	var buf bytes.Buffer
+	if err := utils.EncodeXML(&buf, "root", data); err != nil {
+		t.Fatalf("EncodeXML() error = %v", err)
+	}
+	got := buf.String()
+
+	for _, want := range []string{
+		`<?xml version="1.0" encoding="UTF-8"?>`,
+		"<root>",
+		"<id>12345678901</id>",
+		"<name>Ola &amp; Kari</name>",
+		"<item>a</item>",
+		"<EMAIL_ADDRESS>[EMAIL]</EMAIL_ADDRESS>",
+		`<entry key="1ST">[FIRST]</entry>`,
+	} {
+		if !strings.Contains(got, want) {
+			t.Errorf("Expected XML to contain %q, got:\n%s", want, got)
+		}
+	}
+	if strings.Contains(got, "missing") {
+		t.Errorf("Expected null values to be left out, got:\n%s", got)
+	}
+}
+
+func TestEncodeXML_Unencodable(t *testing.T) {
+	var buf bytes.Buffer
+	if err := utils.EncodeXML(&buf, "root", math.NaN()); err == nil {
+		t.Error("Expected an error for a value without a JSON representation")
+	}
+}
+
+func TestSendXML_EncodingError(t *testing.T) {
+	rr := httptest.NewRecorder()
+	utils.XML(rr, http.StatusOK, math.Inf(1))
+
+	// Nothing was sent yet, so a regular error response is still possible
+	if rr.Code != http.StatusInternalServerError {
+		t.Errorf("Expected status 500, got %d", rr.Code)
+	}
+	if got := rr.Header().Get(constants.HeaderContentType); got != constants.ContentTypeJSON {
+		t.Errorf("Expected content type %s, got %s", constants.ContentTypeJSON, got)
+	}
+}
@@ -0,0 +1,64 @@
+package worker
+
+import (
+	"context"
+
+	"github.com/yasinhessnawi1/Hideme_Backend/internal/auth"
+	"github.com/yasinhessnawi1/Hideme_Backend/internal/models"
+)
+
+// localeContextKey is the context key of the locale of the request that queued a job.
+type localeContextKey struct{}
+
+// featuresContextKey is the context key of the features snapshotted when a job was queued.
+type featuresContextKey struct{}
+
+// executionContext restores the context of the request that queued a job: its request ID
+// and caller under the keys the auth middleware uses, its locale and its feature snapshot.
+func executionContext(ctx context.Context, job *models.Job) context.Context {
+	execution := job.Context
+	if execution.RequestID != "" {
+		ctx = context.WithValue(ctx, auth.RequestIDContextKey, execution.RequestID)
+	}
+	userID := execution.UserID
+	if userID == 0 {
+		// Jobs queued before the caller was kept ran for the user who queued them
+		userID = job.UserID
+	}
+	ctx = context.WithValue(ctx, auth.UserIDContextKey, userID)
+	if execution.APIKey {
+		scopes := execution.APIKeyScopes
+		if scopes == nil {
+			scopes = []string{}
+		}
+		ctx = context.WithValue(ctx, auth.APIKeyScopesContextKey, scopes)
+	}
+	ctx = context.WithValue(ctx, localeContextKey{}, execution.Locale)
+	return context.WithValue(ctx, featuresContextKey{}, execution.Features)
+}
+
+// Locale returns the locale of the request that queued the job running with ctx.
+//
+// Parameters:
+//   - ctx: The context a handler was called with
+//
+// Returns:
+//   - The locale; models.LocaleISO if the request had none or ctx is not a job's
+func Locale(ctx context.Context) models.Locale {
+	locale, _ := ctx.Value(localeContextKey{}).(models.Locale)
+	return locale
+}
+
+// FeatureEnabled reports whether the deployment offered a feature when the job running
+// with ctx was queued.
+//
+// Parameters:
+//   - ctx: The context a handler was called with
+//   - feature: The feature, such as constants.FeaturePIIScreening
+//
+// Returns:
+//   - Whether the feature was enabled; false if it was not snapshotted
+func FeatureEnabled(ctx context.Context, feature string) bool {
+	features, _ := ctx.Value(featuresContextKey{}).(map[string]bool)
+	return features[feature]
+}
@@ -0,0 +1,289 @@
+// Package worker runs the jobs of the background job queue. A pool of workers claims due
+// jobs from the queue, runs them with the handler registered for their kind and records the
+// outcome: the result of a job that succeeded, a later attempt for a job that failed, or a
+// failure for good once a job runs out of attempts or fails with a permanent error.
+//
+// The queue is shared by all servers; a job is claimed by one worker at a time, and a job
+// whose server crashed is taken over by another once its lease expires.
+package worker
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/rs/zerolog/log"
+
+	"github.com/yasinhessnawi1/Hideme_Backend/internal/constants"
+	"github.com/yasinhessnawi1/Hideme_Backend/internal/models"
+	"github.com/yasinhessnawi1/Hideme_Backend/internal/utils"
+	"github.com/yasinhessnawi1/Hideme_Backend/internal/utils/clock"
+)
+
+// Queue is the store the workers take their jobs from, usually the JobRepository.
+type Queue interface {
+	Claim(ctx context.Context, now, leaseUntil time.Time) (*models.Job, error)
+	Complete(ctx context.Context, job *models.Job, result *models.JobResult) error
+	Retry(ctx context.Context, job *models.Job, lastError string, runAfter time.Time) error
+	Fail(ctx context.Context, job *models.Job, lastError string) error
+}
+
+// Handler runs a job of one kind and returns its result. It is called with the context of
+// the request that queued the job restored, see Locale and FeatureEnabled. Errors are
+// retried unless they are wrapped with Permanent.
+type Handler func(ctx context.Context, job *models.Job) (*models.JobResult, error)
+
+// Observer is told about a job whenever its status changes: when a worker starts it and once
+// the outcome of an attempt is recorded.
+type Observer func(ctx context.Context, job *models.Job)
+
+// permanentError marks an error that retrying a job cannot fix.
+type permanentError struct {
+	err error
+}
+
+func (e *permanentError) Error() string { return e.err.Error() }
+func (e *permanentError) Unwrap() error { return e.err }
+
+// Permanent marks an error of a handler as one retrying cannot fix, such as a document that
+// no longer exists, so the job fails at once instead of using up its attempts.
+//
+// Parameters:
+//   - err: The error of the handler
+//
+// Returns:
+//   - The error, marked permanent; nil if err is nil
+func Permanent(err error) error {
+	if err == nil {
+		return nil
+	}
+	return &permanentError{err: err}
+}
+
+// IsPermanent reports whether an error of a handler was marked with Permanent.
+//
+// Parameters:
+//   - err: The error of the handler
+//
+// Returns:
+//   - True if retrying the job cannot fix the error
+func IsPermanent(err error) bool {
+	var permanent *permanentError
+	return errors.As(err, &permanent)
+}
+
+// Backoff returns how long a job waits after a failed attempt before it is attempted again:
+// constants.JobRetryBaseDelay after the first attempt, doubling with every further attempt
+// up to constants.JobRetryMaxDelay.
+//
+// Parameters:
+//   - attempt: The number of the attempt that failed, from 1
+//
+// Returns:
+//   - The delay before the next attempt
+func Backoff(attempt int) time.Duration {
+	delay := constants.JobRetryBaseDelay
+	for i := 1; i < attempt; i++ {
+		delay *= 2
+		if delay >= constants.JobRetryMaxDelay {
+			return constants.JobRetryMaxDelay
+		}
+	}
+	return delay
+}
+
+// Pool runs queued jobs on a fixed number of workers.
+type Pool struct {
+	queue        Queue
+	handlers     map[string]Handler
+	observers    []Observer
+	workers      int
+	pollInterval time.Duration
+	clock        clock.Clock
+}
+
+// NewPool creates a pool without handlers.
+//
+// Parameters:
+//   - queue: The queue the workers take their jobs from
+//   - workers: The number of jobs run at the same time; at least one
+//
+// Returns:
+//   - A new Pool
+func NewPool(queue Queue, workers int) *Pool {
+	return &Pool{
+		queue:        queue,
+		handlers:     make(map[string]Handler),
+		workers:      max(workers, 1),
+		pollInterval: constants.JobPollInterval,
+	}
+}
+
+// SetClock sets the clock jobs are claimed, leased and timed by.
+func (p *Pool) SetClock(c clock.Clock) {
+	p.clock = c
+}
+
+// now returns the current time of the pool's clock.
+func (p *Pool) now() time.Time {
+	return clock.Or(p.clock).Now()
+}
+
+// Handle registers the handler running the jobs of a kind. It must be called before Run.
+//
+// Parameters:
+//   - kind: The kind of the jobs, such as constants.JobKindRedaction
+//   - handler: The handler running them
+func (p *Pool) Handle(kind string, handler Handler) {
+	p.handlers[kind] = handler
+}
+
+// Observe registers an observer told about the status changes of the jobs the pool runs.
+// It must be called before Run.
+//
+// Parameters:
+//   - observer: The observer, such as a function notifying the user who queued the job
+func (p *Pool) Observe(observer Observer) {
+	p.observers = append(p.observers, observer)
+}
+
+// Run runs due jobs on the workers until ctx is canceled, each idle worker polling every
+// constants.JobPollInterval. Jobs running when ctx is canceled are finished; Run returns
+// once they are recorded.
+//
+// Parameters:
+//   - ctx: Canceled when the server shuts down
+func (p *Pool) Run(ctx context.Context) {
+	var wg sync.WaitGroup
+	for i := 0; i < p.workers; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			p.work(ctx)
+		}()
+	}
+	wg.Wait()
+}
+
+// work runs jobs until ctx is canceled.
+func (p *Pool) work(ctx context.Context) {
+	ticker := time.NewTicker(p.pollInterval)
+	defer ticker.Stop()
+
+	for {
+		// Run jobs until none is due
+		for ctx.Err() == nil {
+			if !p.runNext(ctx) {
+				break
+			}
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// runNext claims the next due job and runs it.
+//
+// Returns:
+//   - Whether a job was run; false if none was due or claiming failed
+func (p *Pool) runNext(ctx context.Context) bool {
+	job, err := p.queue.Claim(ctx, p.now(), p.now().Add(constants.JobLease))
+	if err != nil {
+		if ctx.Err() == nil {
+			log.Error().Err(err).Msg("Failed to claim job")
+		}
+		return false
+	}
+	if job == nil {
+		return false
+	}
+
+	// Jobs are not cut short by a shutdown; they finish within their lease
+	p.run(context.WithoutCancel(ctx), job)
+	return true
+}
+
+// run runs a claimed job and records the outcome.
+func (p *Pool) run(ctx context.Context, job *models.Job) {
+	logger := log.With().
+		Int64("job_id", job.ID).
+		Int64("user_id", job.UserID).
+		Int64("document_id", job.DocumentID).
+		Str("kind", job.Kind).
+		Int("attempt", job.Attempts).
+		Str("request_id", job.Context.RequestID).
+		Logger()
+	p.updated(ctx, job)
+
+	var result *models.JobResult
+	var err error
+	if job.Attempts > job.MaxAttempts {
+		// The job was taken over from crashed servers until it ran out of attempts
+		err = Permanent(fmt.Errorf("job interrupted after %d attempts", job.MaxAttempts))
+	} else if handler, ok := p.handlers[job.Kind]; !ok {
+		err = Permanent(fmt.Errorf("no handler for jobs of kind %q", job.Kind))
+	} else {
+		start := p.now()
+		result, err = p.call(executionContext(ctx, job), handler, job)
+		logger = logger.With().Dur("duration", p.now().Sub(start)).Logger()
+	}
+
+	var recordErr error
+	switch {
+	case err == nil:
+		recordErr = p.queue.Complete(ctx, job, result)
+		if recordErr == nil {
+			logger.Info().Msg("Job completed")
+			completedAt := p.now()
+			job.Status, job.ResultType, job.CompletedAt = constants.JobCompleted, result.ContentType, &completedAt
+		}
+	case IsPermanent(err) || job.Attempts >= job.MaxAttempts:
+		recordErr = p.queue.Fail(ctx, job, err.Error())
+		if recordErr == nil {
+			logger.Error().Err(err).Msg("Job failed")
+			job.Status, job.LastError = constants.JobFailed, err.Error()
+		}
+	default:
+		runAfter := p.now().Add(Backoff(job.Attempts))
+		recordErr = p.queue.Retry(ctx, job, err.Error(), runAfter)
+		if recordErr == nil {
+			logger.Warn().Err(err).Time("run_after", runAfter).Msg("Job attempt failed, retrying later")
+			job.Status, job.LastError, job.RunAfter = constants.JobPending, err.Error(), runAfter
+		}
+	}
+	if recordErr == nil {
+		job.UpdatedAt = p.now()
+		p.updated(ctx, job)
+		return
+	}
+
+	if errors.Is(recordErr, utils.ErrNotFound) {
+		// Another server took the job over or it was deleted with its document
+		logger.Warn().Err(recordErr).Msg("Job no longer held by this worker")
+		return
+	}
+	logger.Error().Err(recordErr).Msg("Failed to record job outcome")
+}
+
+// updated tells the observers about the new status of a job.
+func (p *Pool) updated(ctx context.Context, job *models.Job) {
+	for _, observer := range p.observers {
+		observer(ctx, job)
+	}
+}
+
+// call runs a handler, turning a panic into an error so a broken job cannot stop the worker.
+func (p *Pool) call(ctx context.Context, handler Handler, job *models.Job) (result *models.JobResult, err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			result, err = nil, fmt.Errorf("job panicked: %v", r)
+		}
+	}()
+	return handler(ctx, job)
+}
@@ -0,0 +1,313 @@
+package worker
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/yasinhessnawi1/Hideme_Backend/internal/auth"
+	"github.com/yasinhessnawi1/Hideme_Backend/internal/constants"
+	"github.com/yasinhessnawi1/Hideme_Backend/internal/models"
+	"github.com/yasinhessnawi1/Hideme_Backend/internal/testsupport/fakeclock"
+	"github.com/yasinhessnawi1/Hideme_Backend/internal/utils"
+)
+
+// memoryQueue is a Queue holding its jobs in memory, recording the outcome of each attempt.
+type memoryQueue struct {
+	mu       sync.Mutex
+	pending  []*models.Job
+	outcomes map[int64]string
+	errors   map[int64]string
+	runAfter map[int64]time.Time
+	results  map[int64]*models.JobResult
+	gone     map[int64]bool
+}
+
+func newMemoryQueue(jobs ...*models.Job) *memoryQueue {
+	return &memoryQueue{
+		pending:  jobs,
+		outcomes: make(map[int64]string),
+		errors:   make(map[int64]string),
+		runAfter: make(map[int64]time.Time),
+		results:  make(map[int64]*models.JobResult),
+		gone:     make(map[int64]bool),
+	}
+}
+
+func (q *memoryQueue) Claim(ctx context.Context, now, leaseUntil time.Time) (*models.Job, error) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	if len(q.pending) == 0 {
+		return nil, nil
+	}
+	job := q.pending[0]
+	q.pending = q.pending[1:]
+	job.Attempts++
+	job.Status = constants.JobRunning
+	return job, nil
+}
+
+func (q *memoryQueue) record(job *models.Job, status string) error {
+	if q.gone[job.ID] {
+		return utils.NewNotFoundError("Job", job.ID)
+	}
+	q.outcomes[job.ID] = status
+	return nil
+}
+
+func (q *memoryQueue) Complete(ctx context.Context, job *models.Job, result *models.JobResult) error {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	q.results[job.ID] = result
+	return q.record(job, constants.JobCompleted)
+}
+
+func (q *memoryQueue) Retry(ctx context.Context, job *models.Job, lastError string, runAfter time.Time) error {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	q.errors[job.ID] = lastError
+	q.runAfter[job.ID] = runAfter
+	return q.record(job, constants.JobPending)
+}
+
+func (q *memoryQueue) Fail(ctx context.Context, job *models.Job, lastError string) error {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	q.errors[job.ID] = lastError
+	return q.record(job, constants.JobFailed)
+}
+
+func TestBackoff(t *testing.T) {
+	assert.Equal(t, constants.JobRetryBaseDelay, Backoff(1))
+	assert.Equal(t, 2*constants.JobRetryBaseDelay, Backoff(2))
+	assert.Equal(t, 4*constants.JobRetryBaseDelay, Backoff(3))
+	assert.Equal(t, constants.JobRetryMaxDelay, Backoff(30))
+}
+
+func TestPool_RunNext(t *testing.T) {
+	now := time.Date(2026, 3, 1, 12, 0, 0, 0, time.UTC)
+	pdf := &models.JobResult{ContentType: constants.ContentTypePDF, Data: []byte("%PDF-1.7")}
+
+	tests := []struct {
+		name        string
+		job         *models.Job
+		handler     Handler
+		wantOutcome string
+		wantError   string
+		wantRetryIn time.Duration
+	}{
+		{
+			name: "Completed",
+			job:  &models.Job{ID: 1, Kind: constants.JobKindRedaction, MaxAttempts: 5},
+			handler: func(ctx context.Context, job *models.Job) (*models.JobResult, error) {
+				return pdf, nil
+			},
+			wantOutcome: constants.JobCompleted,
+		},
+		{
+			name: "Retried with backoff",
+			job:  &models.Job{ID: 2, Kind: constants.JobKindRedaction, Attempts: 2, MaxAttempts: 5},
+			handler: func(ctx context.Context, job *models.Job) (*models.JobResult, error) {
+				return nil, errors.New("connection reset")
+			},
+			wantOutcome: constants.JobPending,
+			wantError:   "connection reset",
+			wantRetryIn: 4 * constants.JobRetryBaseDelay,
+		},
+		{
+			name: "Failed after the last attempt",
+			job:  &models.Job{ID: 3, Kind: constants.JobKindRedaction, Attempts: 4, MaxAttempts: 5},
+			handler: func(ctx context.Context, job *models.Job) (*models.JobResult, error) {
+				return nil, errors.New("connection reset")
+			},
+			wantOutcome: constants.JobFailed,
+			wantError:   "connection reset",
+		},
+		{
+			name: "Permanent error",
+			job:  &models.Job{ID: 4, Kind: constants.JobKindRedaction, MaxAttempts: 5},
+			handler: func(ctx context.Context, job *models.Job) (*models.JobResult, error) {
+				return nil, Permanent(errors.New("document not found"))
+			},
+			wantOutcome: constants.JobFailed,
+			wantError:   "document not found",
+		},
+		{
+			name: "Panic is retried",
+			job:  &models.Job{ID: 5, Kind: constants.JobKindRedaction, MaxAttempts: 5},
+			handler: func(ctx context.Context, job *models.Job) (*models.JobResult, error) {
+				panic("nil map")
+			},
+			wantOutcome: constants.JobPending,
+			wantError:   "job panicked: nil map",
+			wantRetryIn: constants.JobRetryBaseDelay,
+		},
+		{
+			name:        "Unknown kind",
+			job:         &models.Job{ID: 6, Kind: "ocr", MaxAttempts: 5},
+			wantOutcome: constants.JobFailed,
+			wantError:   `no handler for jobs of kind "ocr"`,
+		},
+		{
+			name: "Out of attempts after crashes",
+			job:  &models.Job{ID: 7, Kind: constants.JobKindRedaction, Attempts: 5, MaxAttempts: 5},
+			handler: func(ctx context.Context, job *models.Job) (*models.JobResult, error) {
+				t.Error("a job out of attempts must not run")
+				return pdf, nil
+			},
+			wantOutcome: constants.JobFailed,
+			wantError:   "job interrupted after 5 attempts",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			queue := newMemoryQueue(tt.job)
+			pool := NewPool(queue, 1)
+			pool.SetClock(fakeclock.New(now))
+			if tt.handler != nil {
+				pool.Handle(tt.job.Kind, tt.handler)
+			}
+
+			require.True(t, pool.runNext(context.Background()))
+			assert.Equal(t, tt.wantOutcome, queue.outcomes[tt.job.ID])
+			assert.Equal(t, tt.wantError, queue.errors[tt.job.ID])
+			if tt.wantOutcome == constants.JobCompleted {
+				assert.Equal(t, pdf, queue.results[tt.job.ID])
+			}
+			if tt.wantRetryIn > 0 {
+				assert.Equal(t, now.Add(tt.wantRetryIn), queue.runAfter[tt.job.ID])
+			}
+
+			// The queue is empty now
+			assert.False(t, pool.runNext(context.Background()))
+		})
+	}
+}
+
+func TestPool_ExecutionContext(t *testing.T) {
+	queue := newMemoryQueue(
+		&models.Job{ID: 1, UserID: 7, Kind: constants.JobKindExport, MaxAttempts: 5, Context: models.JobContext{
+			RequestID:    "req-1",
+			UserID:       7,
+			APIKey:       true,
+			APIKeyScopes: []string{constants.APIKeyScopeDocumentsWrite},
+			Locale:       models.LocaleNorwegian,
+			Features:     map[string]bool{constants.FeaturePIIScreening: true},
+		}},
+		// Jobs queued before the context was kept run for the user who queued them
+		&models.Job{ID: 2, UserID: 8, Kind: constants.JobKindExport, MaxAttempts: 5},
+	)
+	pool := NewPool(queue, 1)
+
+	var contexts []context.Context
+	pool.Handle(constants.JobKindExport, func(ctx context.Context, job *models.Job) (*models.JobResult, error) {
+		contexts = append(contexts, ctx)
+		return &models.JobResult{}, nil
+	})
+	require.True(t, pool.runNext(context.Background()))
+	require.True(t, pool.runNext(context.Background()))
+	require.Len(t, contexts, 2)
+
+	ctx := contexts[0]
+	assert.Equal(t, "req-1", ctx.Value(auth.RequestIDContextKey))
+	assert.Equal(t, int64(7), ctx.Value(auth.UserIDContextKey))
+	assert.Equal(t, []string{constants.APIKeyScopeDocumentsWrite}, ctx.Value(auth.APIKeyScopesContextKey))
+	assert.Equal(t, models.LocaleNorwegian, Locale(ctx))
+	assert.True(t, FeatureEnabled(ctx, constants.FeaturePIIScreening))
+	assert.False(t, FeatureEnabled(ctx, constants.FeatureDriveGoogle))
+
+	ctx = contexts[1]
+	assert.Nil(t, ctx.Value(auth.RequestIDContextKey))
+	assert.Equal(t, int64(8), ctx.Value(auth.UserIDContextKey))
+	assert.Nil(t, ctx.Value(auth.APIKeyScopesContextKey))
+	assert.Equal(t, models.LocaleISO, Locale(ctx))
+	assert.False(t, FeatureEnabled(ctx, constants.FeaturePIIScreening))
+}
+
+func TestPool_TakenOver(t *testing.T) {
+	queue := newMemoryQueue(&models.Job{ID: 1, Kind: constants.JobKindExport, MaxAttempts: 5})
+	queue.gone[1] = true
+	pool := NewPool(queue, 1)
+	pool.Handle(constants.JobKindExport, func(ctx context.Context, job *models.Job) (*models.JobResult, error) {
+		return &models.JobResult{}, nil
+	})
+
+	// The outcome of a job another server took over is dropped
+	assert.True(t, pool.runNext(context.Background()))
+	assert.Empty(t, queue.outcomes)
+}
+
+func TestPool_Observe(t *testing.T) {
+	queue := newMemoryQueue(
+		&models.Job{ID: 1, Kind: constants.JobKindRedaction, MaxAttempts: 5},
+		&models.Job{ID: 2, Kind: constants.JobKindExport, MaxAttempts: 5},
+	)
+	pool := NewPool(queue, 1)
+	pool.Handle(constants.JobKindRedaction, func(ctx context.Context, job *models.Job) (*models.JobResult, error) {
+		return &models.JobResult{ContentType: constants.ContentTypePDF}, nil
+	})
+	pool.Handle(constants.JobKindExport, func(ctx context.Context, job *models.Job) (*models.JobResult, error) {
+		return nil, errors.New("connection reset")
+	})
+
+	var updates []string
+	pool.Observe(func(ctx context.Context, job *models.Job) {
+		updates = append(updates, job.Status+" "+job.LastError)
+	})
+
+	require.True(t, pool.runNext(context.Background()))
+	require.True(t, pool.runNext(context.Background()))
+	assert.Equal(t, []string{
+		"running ",
+		"completed ",
+		"running ",
+		"pending connection reset",
+	}, updates)
+}
+
+func TestPool_Run(t *testing.T) {
+	jobs := make([]*models.Job, 10)
+	for i := range jobs {
+		jobs[i] = &models.Job{ID: int64(i + 1), Kind: constants.JobKindExport, MaxAttempts: 5}
+	}
+	queue := newMemoryQueue(jobs...)
+	pool := NewPool(queue, 3)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	var mu sync.Mutex
+	ran := 0
+	pool.Handle(constants.JobKindExport, func(ctx context.Context, job *models.Job) (*models.JobResult, error) {
+		mu.Lock()
+		defer mu.Unlock()
+		ran++
+		if ran == len(jobs) {
+			cancel()
+		}
+		// Running jobs are finished after a shutdown
+		return &models.JobResult{ContentType: constants.ContentTypeJSON}, ctx.Err()
+	})
+
+	done := make(chan struct{})
+	go func() {
+		pool.Run(ctx)
+		close(done)
+	}()
+
+	select {
+	case <-done:
+	case <-time.After(5 * time.Second):
+		t.Fatal("Run did not return after the context was canceled")
+	}
+	queue.mu.Lock()
+	defer queue.mu.Unlock()
+	assert.Len(t, queue.outcomes, len(jobs))
+	for id, outcome := range queue.outcomes {
+		assert.Equal(t, constants.JobCompleted, outcome, "job %d", id)
+	}
+}
@@ -135,6 +135,114 @@ func (m *Migrator) RunMigrations(ctx context.Context) error {
 		log.Error().Err(err).Msg("Failed to ensure user role column")
 		// Don't return error to avoid breaking existing migrations
 	}
+	if err := m.ensureReportSubscriptionColumns(ctx); err != nil {
+		log.Error().Err(err).Msg("Failed to ensure report_subscriptions columns")
+		// Don't return error to avoid breaking existing migrations
+	}
+	if err := m.ensureDocumentLanguageColumn(ctx); err != nil {
+		log.Error().Err(err).Msg("Failed to ensure documents language column")
+		// Don't return error to avoid breaking existing migrations
+	}
+
+	if err := m.ensureAPIKeyRotationColumns(ctx); err != nil {
+		log.Error().Err(err).Msg("Failed to ensure API key rotation columns")
+		// Don't return error to avoid breaking existing migrations
+	}
+
+	if err := m.ensureAPIKeyAdministrationColumns(ctx); err != nil {
+		log.Error().Err(err).Msg("Failed to ensure API key administration columns")
+		// Don't return error to avoid breaking existing migrations
+	}
+
+	if err := m.ensureAPIKeyScopesColumn(ctx); err != nil {
+		log.Error().Err(err).Msg("Failed to ensure API key scopes column")
+		// Don't return error to avoid breaking existing migrations
+	}
+
+	if err := m.ensureSessionActivityColumn(ctx); err != nil {
+		log.Error().Err(err).Msg("Failed to ensure sessions last_activity_at column")
+		// Don't return error to avoid breaking existing migrations
+	}
+
+	if err := m.ensureUserDisabledColumn(ctx); err != nil {
+		log.Error().Err(err).Msg("Failed to ensure users disabled_at column")
+		// Don't return error to avoid breaking existing migrations
+	}
+
+	if err := m.ensureDocumentSchemaVersionColumn(ctx); err != nil {
+		log.Error().Err(err).Msg("Failed to ensure documents schema_version column")
+		// Don't return error to avoid breaking existing migrations
+	}
+
+	if err := m.ensureDocumentClassificationColumns(ctx); err != nil {
+		log.Error().Err(err).Msg("Failed to ensure documents classification columns")
+		// Don't return error to avoid breaking existing migrations
+	}
+
+	if err := m.ensureFilenameScrubbingColumns(ctx); err != nil {
+		log.Error().Err(err).Msg("Failed to ensure filename scrubbing columns")
+		// Don't return error to avoid breaking existing migrations
+	}
+
+	if err := m.ensureDocumentArchivedColumn(ctx); err != nil {
+		log.Error().Err(err).Msg("Failed to ensure documents archived_at column")
+		// Don't return error to avoid breaking existing migrations
+	}
+
+	if err := m.ensureDocumentSearchColumns(ctx); err != nil {
+		log.Error().Err(err).Msg("Failed to ensure documents search columns")
+		// Don't return error to avoid breaking existing migrations
+	}
+
+	if err := m.ensureDetectedEntityReviewColumns(ctx); err != nil {
+		log.Error().Err(err).Msg("Failed to ensure detected_entities review columns")
+		// Don't return error to avoid breaking existing migrations
+	}
+
+	if err := m.ensureDocumentContentSegmentColumn(ctx); err != nil {
+		log.Error().Err(err).Msg("Failed to ensure document_contents segment_size column")
+		// Don't return error to avoid breaking existing migrations
+	}
+
+	if err := m.ensureBanListWordOptionColumns(ctx); err != nil {
+		log.Error().Err(err).Msg("Failed to ensure ban_list_words option columns")
+		// Don't return error to avoid breaking existing migrations
+	}
+
+	if err := m.ensureUserSettingsVersionColumn(ctx); err != nil {
+		log.Error().Err(err).Msg("Failed to ensure user_settings version column")
+		// Don't return error to avoid breaking existing migrations
+	}
+
+	if err := m.ensureDocumentLifecycleColumns(ctx); err != nil {
+		log.Error().Err(err).Msg("Failed to ensure documents lifecycle columns")
+		// Don't return error to avoid breaking existing migrations
+	}
+
+	if err := m.ensureBanListOptionColumns(ctx); err != nil {
+		log.Error().Err(err).Msg("Failed to ensure ban_lists option columns")
+		// Don't return error to avoid breaking existing migrations
+	}
+
+	if err := m.ensureJobExecutionContextColumn(ctx); err != nil {
+		log.Error().Err(err).Msg("Failed to ensure jobs execution_context column")
+		// Don't return error to avoid breaking existing migrations
+	}
+
+	if err := m.ensureDocumentRetentionColumn(ctx); err != nil {
+		log.Error().Err(err).Msg("Failed to ensure user_settings document_retention_days column")
+		// Don't return error to avoid breaking existing migrations
+	}
+
+	if err := m.ensureDocumentTransferStatusColumns(ctx); err != nil {
+		log.Error().Err(err).Msg("Failed to ensure document_transfers status columns")
+		// Don't return error to avoid breaking existing migrations
+	}
+
+	if err := m.ensureKeysetCursorIndexes(ctx); err != nil {
+		log.Error().Err(err).Msg("Failed to ensure keyset cursor indexes")
+		// Don't return error to avoid breaking existing migrations
+	}
 
 	return nil
 }
@@ -362,6 +470,32 @@ func (m *Migrator) ensureUserSettingsColumns(ctx context.Context) error {
 		log.Info().Msg("Successfully added use_banlist_for_detection column")
 	}
 
+	// Also check for redaction_placeholders column
+	err = m.db.QueryRowContext(ctx, `
+		SELECT EXISTS (
+			SELECT 1
+			FROM information_schema.columns
+			WHERE table_name = 'user_settings'
+			AND column_name = 'redaction_placeholders'
+		)
+	`).Scan(&columnExists)
+
+	if err != nil {
+		return fmt.Errorf("failed to check if redaction_placeholders column exists: %w", err)
+	}
+
+	if !columnExists {
+		log.Info().Msg("Adding missing redaction_placeholders column to user_settings table")
+
+		alterQuery := `ALTER TABLE user_settings ADD COLUMN redaction_placeholders JSONB NOT NULL DEFAULT '{}'`
+		_, err = m.db.ExecContext(ctx, alterQuery)
+		if err != nil {
+			return fmt.Errorf("failed to add redaction_placeholders column: %w", err)
+		}
+
+		log.Info().Msg("Successfully added redaction_placeholders column")
+	}
+
 	return nil
 }
 
@@ -407,6 +541,490 @@ func (m *Migrator) ensureUserRoleColumn(ctx context.Context) error {
 	return nil
 }
 
+// ensureReportSubscriptionColumns ensures that the report_subscriptions table has the
+// locale and csv_delimiter columns, which were added after the table was introduced.
+//
+// Parameters:
+//   - ctx: Context for database operations and cancellation
+//
+// Returns:
+//   - error: Any error encountered while ensuring columns exist, nil if successful
+func (m *Migrator) ensureReportSubscriptionColumns(ctx context.Context) error {
+	alterQueries := []string{
+		`ALTER TABLE report_subscriptions ADD COLUMN IF NOT EXISTS locale VARCHAR(10) NOT NULL DEFAULT ''`,
+		`ALTER TABLE report_subscriptions ADD COLUMN IF NOT EXISTS csv_delimiter VARCHAR(10) NOT NULL DEFAULT ''`,
+	}
+
+	for _, alterQuery := range alterQueries {
+		if _, err := m.db.ExecContext(ctx, alterQuery); err != nil {
+			return fmt.Errorf("failed to add report_subscriptions column: %w", err)
+		}
+	}
+
+	return nil
+}
+
+// ensureDocumentLanguageColumn ensures that the documents table has a language column
+// and an index for filtering a user's documents by language.
+//
+// Parameters:
+//   - ctx: Context for database operations and cancellation
+//
+// Returns:
+//   - error: Any error encountered while ensuring the column exists, nil if successful
+func (m *Migrator) ensureDocumentLanguageColumn(ctx context.Context) error {
+	alterQueries := []string{
+		`ALTER TABLE documents ADD COLUMN IF NOT EXISTS language VARCHAR(8) NOT NULL DEFAULT ''`,
+		`CREATE INDEX IF NOT EXISTS idx_documents_user_language ON documents(user_id, language)`,
+	}
+
+	for _, alterQuery := range alterQueries {
+		if _, err := m.db.ExecContext(ctx, alterQuery); err != nil {
+			return fmt.Errorf("failed to add documents language column: %w", err)
+		}
+	}
+
+	return nil
+}
+
+// ensureAPIKeyRotationColumns ensures that the api_keys table records the rotation
+// lineage of keys and their use after rotation.
+//
+// Parameters:
+//   - ctx: Context for database operations and cancellation
+//
+// Returns:
+//   - error: Any error encountered while ensuring the columns exist, nil if successful
+func (m *Migrator) ensureAPIKeyRotationColumns(ctx context.Context) error {
+	alterQueries := []string{
+		`ALTER TABLE api_keys ADD COLUMN IF NOT EXISTS rotated_from VARCHAR(255)`,
+		`ALTER TABLE api_keys ADD COLUMN IF NOT EXISTS rotated_at TIMESTAMP`,
+		`ALTER TABLE api_keys ADD COLUMN IF NOT EXISTS used_after_rotation_at TIMESTAMP`,
+	}
+
+	for _, alterQuery := range alterQueries {
+		if _, err := m.db.ExecContext(ctx, alterQuery); err != nil {
+			return fmt.Errorf("failed to add API key rotation columns: %w", err)
+		}
+	}
+
+	return nil
+}
+
+// ensureAPIKeyAdministrationColumns ensures that the api_keys table records keys disabled
+// by administrators and the rotation campaigns keys are part of.
+//
+// Parameters:
+//   - ctx: Context for database operations and cancellation
+//
+// Returns:
+//   - error: Any error encountered while ensuring the columns exist, nil if successful
+func (m *Migrator) ensureAPIKeyAdministrationColumns(ctx context.Context) error {
+	alterQueries := []string{
+		`ALTER TABLE api_keys ADD COLUMN IF NOT EXISTS disabled_at TIMESTAMP`,
+		`ALTER TABLE api_keys ADD COLUMN IF NOT EXISTS rotation_campaign_id BIGINT`,
+		`ALTER TABLE api_keys ADD COLUMN IF NOT EXISTS rotation_deadline TIMESTAMP`,
+		`CREATE INDEX IF NOT EXISTS idx_api_keys_rotation_campaign ON api_keys(rotation_campaign_id)`,
+	}
+
+	for _, alterQuery := range alterQueries {
+		if _, err := m.db.ExecContext(ctx, alterQuery); err != nil {
+			return fmt.Errorf("failed to add API key administration columns: %w", err)
+		}
+	}
+
+	return nil
+}
+
+// ensureAPIKeyScopesColumn ensures that the api_keys table records the scopes keys are
+// limited to. Existing keys get no scopes, so they keep full access.
+//
+// Parameters:
+//   - ctx: Context for database operations and cancellation
+//
+// Returns:
+//   - error: Any error encountered while ensuring the column exists, nil if successful
+func (m *Migrator) ensureAPIKeyScopesColumn(ctx context.Context) error {
+	alterQuery := `ALTER TABLE api_keys ADD COLUMN IF NOT EXISTS scopes TEXT[] NOT NULL DEFAULT '{}'`
+	if _, err := m.db.ExecContext(ctx, alterQuery); err != nil {
+		return fmt.Errorf("failed to add API key scopes column: %w", err)
+	}
+
+	return nil
+}
+
+// ensureSessionActivityColumn ensures that the sessions table records when each session was
+// last used, for the idle timeout. Existing sessions count as used when the column is added.
+//
+// Parameters:
+//   - ctx: Context for database operations and cancellation
+//
+// Returns:
+//   - error: Any error encountered while ensuring the column exists, nil if successful
+func (m *Migrator) ensureSessionActivityColumn(ctx context.Context) error {
+	alterQueries := []string{
+		`ALTER TABLE sessions ADD COLUMN IF NOT EXISTS last_activity_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP`,
+		`CREATE INDEX IF NOT EXISTS idx_sessions_last_activity_at ON sessions(last_activity_at)`,
+	}
+
+	for _, alterQuery := range alterQueries {
+		if _, err := m.db.ExecContext(ctx, alterQuery); err != nil {
+			return fmt.Errorf("failed to add sessions last_activity_at column: %w", err)
+		}
+	}
+
+	return nil
+}
+
+// ensureUserDisabledColumn ensures that the users table records when an administrator
+// disabled an account. Existing accounts stay enabled.
+//
+// Parameters:
+//   - ctx: Context for database operations and cancellation
+//
+// Returns:
+//   - error: Any error encountered while ensuring the column exists, nil if successful
+func (m *Migrator) ensureUserDisabledColumn(ctx context.Context) error {
+	alterQuery := `ALTER TABLE users ADD COLUMN IF NOT EXISTS disabled_at TIMESTAMP`
+
+	if _, err := m.db.ExecContext(ctx, alterQuery); err != nil {
+		return fmt.Errorf("failed to add users disabled_at column: %w", err)
+	}
+
+	return nil
+}
+
+// ensureDocumentSchemaVersionColumn ensures that the documents table records the coordinate
+// system version of each redaction schema. Existing documents get version 1 (absolute
+// coordinates) and are converted by the schema normalization maintenance task.
+//
+// Parameters:
+//   - ctx: Context for database operations and cancellation
+//
+// Returns:
+//   - error: Any error encountered while ensuring the column exists, nil if successful
+func (m *Migrator) ensureDocumentSchemaVersionColumn(ctx context.Context) error {
+	alterQuery := `ALTER TABLE documents ADD COLUMN IF NOT EXISTS schema_version INTEGER NOT NULL DEFAULT 1`
+
+	if _, err := m.db.ExecContext(ctx, alterQuery); err != nil {
+		return fmt.Errorf("failed to add documents schema_version column: %w", err)
+	}
+
+	return nil
+}
+
+// ensureDocumentClassificationColumns ensures that the documents table has the tags,
+// folder, source and retention assigned by classification rules, and an index for
+// finding documents whose retention has passed.
+//
+// Parameters:
+//   - ctx: Context for database operations and cancellation
+//
+// Returns:
+//   - error: Any error encountered while ensuring the columns exist, nil if successful
+func (m *Migrator) ensureDocumentClassificationColumns(ctx context.Context) error {
+	alterQueries := []string{
+		`ALTER TABLE documents ADD COLUMN IF NOT EXISTS tags TEXT[] NOT NULL DEFAULT '{}'`,
+		`ALTER TABLE documents ADD COLUMN IF NOT EXISTS folder VARCHAR(255) NOT NULL DEFAULT ''`,
+		`ALTER TABLE documents ADD COLUMN IF NOT EXISTS source VARCHAR(50) NOT NULL DEFAULT ''`,
+		`ALTER TABLE documents ADD COLUMN IF NOT EXISTS retain_until TIMESTAMP`,
+		`CREATE INDEX IF NOT EXISTS idx_documents_retain_until ON documents(retain_until) WHERE retain_until IS NOT NULL`,
+	}
+
+	for _, alterQuery := range alterQueries {
+		if _, err := m.db.ExecContext(ctx, alterQuery); err != nil {
+			return fmt.Errorf("failed to add documents classification columns: %w", err)
+		}
+	}
+
+	return nil
+}
+
+// ensureFilenameScrubbingColumns ensures that user settings have the option to scrub
+// filenames at upload, off for existing users, and that documents record whether their
+// filename was scrubbed.
+//
+// Parameters:
+//   - ctx: Context for database operations and cancellation
+//
+// Returns:
+//   - error: Any error encountered while ensuring the columns exist, nil if successful
+func (m *Migrator) ensureFilenameScrubbingColumns(ctx context.Context) error {
+	alterQueries := []string{
+		`ALTER TABLE user_settings ADD COLUMN IF NOT EXISTS scrub_filenames BOOLEAN NOT NULL DEFAULT FALSE`,
+		`ALTER TABLE documents ADD COLUMN IF NOT EXISTS filename_scrubbed BOOLEAN NOT NULL DEFAULT FALSE`,
+	}
+
+	for _, alterQuery := range alterQueries {
+		if _, err := m.db.ExecContext(ctx, alterQuery); err != nil {
+			return fmt.Errorf("failed to add filename scrubbing columns: %w", err)
+		}
+	}
+
+	return nil
+}
+
+// ensureDocumentArchivedColumn ensures that documents record when they were moved to
+// cold storage; existing documents are in hot storage.
+//
+// Parameters:
+//   - ctx: Context for database operations and cancellation
+//
+// Returns:
+//   - error: Any error encountered while ensuring the column exists, nil if successful
+func (m *Migrator) ensureDocumentArchivedColumn(ctx context.Context) error {
+	alterQuery := `ALTER TABLE documents ADD COLUMN IF NOT EXISTS archived_at TIMESTAMP`
+
+	if _, err := m.db.ExecContext(ctx, alterQuery); err != nil {
+		return fmt.Errorf("failed to add documents archived_at column: %w", err)
+	}
+
+	return nil
+}
+
+// ensureDocumentSearchColumns ensures that documents keep the number and types of their
+// detections unencrypted, and that documents can be searched by them and by upload date.
+// Existing documents have no entity count until a maintenance task computes it.
+//
+// Parameters:
+//   - ctx: Context for database operations and cancellation
+//
+// Returns:
+//   - error: Any error encountered while ensuring the columns exist, nil if successful
+func (m *Migrator) ensureDocumentSearchColumns(ctx context.Context) error {
+	alterQueries := []string{
+		`ALTER TABLE documents ADD COLUMN IF NOT EXISTS entity_count INTEGER`,
+		`ALTER TABLE documents ADD COLUMN IF NOT EXISTS entity_types TEXT[] NOT NULL DEFAULT '{}'`,
+		`CREATE INDEX IF NOT EXISTS idx_documents_user_uploaded ON documents(user_id, upload_timestamp DESC)`,
+		`CREATE INDEX IF NOT EXISTS idx_documents_user_entity_count ON documents(user_id, entity_count)`,
+		`CREATE INDEX IF NOT EXISTS idx_documents_entity_types ON documents USING GIN (entity_types)`,
+		`CREATE INDEX IF NOT EXISTS idx_documents_without_entity_count ON documents(document_id) WHERE entity_count IS NULL`,
+	}
+
+	for _, alterQuery := range alterQueries {
+		if _, err := m.db.ExecContext(ctx, alterQuery); err != nil {
+			return fmt.Errorf("failed to add documents search columns: %w", err)
+		}
+	}
+
+	return nil
+}
+
+// ensureDetectedEntityReviewColumns ensures that detected entities record their review
+// status and who reviewed them last. Existing entities await review.
+//
+// Parameters:
+//   - ctx: Context for database operations and cancellation
+//
+// Returns:
+//   - error: Any error encountered while ensuring the columns exist, nil if successful
+func (m *Migrator) ensureDetectedEntityReviewColumns(ctx context.Context) error {
+	alterQueries := []string{
+		`ALTER TABLE detected_entities ADD COLUMN IF NOT EXISTS status VARCHAR(20) NOT NULL DEFAULT 'pending'`,
+		`ALTER TABLE detected_entities ADD COLUMN IF NOT EXISTS reviewed_by BIGINT`,
+		`ALTER TABLE detected_entities ADD COLUMN IF NOT EXISTS reviewed_at TIMESTAMP`,
+		`CREATE INDEX IF NOT EXISTS idx_detected_entities_document_status ON detected_entities(document_id, status)`,
+	}
+
+	for _, alterQuery := range alterQueries {
+		if _, err := m.db.ExecContext(ctx, alterQuery); err != nil {
+			return fmt.Errorf("failed to add detected_entities review columns: %w", err)
+		}
+	}
+
+	return nil
+}
+
+// ensureDocumentContentSegmentColumn ensures that the document_contents table records the
+// segments the content is encrypted in. Existing content was encrypted as a whole.
+//
+// Parameters:
+//   - ctx: Context for database operations and cancellation
+//
+// Returns:
+//   - error: Any error encountered while ensuring the column exists, nil if successful
+func (m *Migrator) ensureDocumentContentSegmentColumn(ctx context.Context) error {
+	alterQuery := `ALTER TABLE document_contents ADD COLUMN IF NOT EXISTS segment_size INT NOT NULL DEFAULT 0`
+
+	if _, err := m.db.ExecContext(ctx, alterQuery); err != nil {
+		return fmt.Errorf("failed to add document_contents segment_size column: %w", err)
+	}
+
+	return nil
+}
+
+// ensureBanListWordOptionColumns ensures that ban list words record how they are matched.
+// Existing words match exactly as written.
+//
+// Parameters:
+//   - ctx: Context for database operations and cancellation
+//
+// Returns:
+//   - error: Any error encountered while ensuring the columns exist, nil if successful
+func (m *Migrator) ensureBanListWordOptionColumns(ctx context.Context) error {
+	alterQueries := []string{
+		`ALTER TABLE ban_list_words ADD COLUMN IF NOT EXISTS case_insensitive BOOLEAN NOT NULL DEFAULT FALSE`,
+		`ALTER TABLE ban_list_words ADD COLUMN IF NOT EXISTS diacritic_insensitive BOOLEAN NOT NULL DEFAULT FALSE`,
+		`ALTER TABLE ban_list_words ADD COLUMN IF NOT EXISTS whole_word BOOLEAN NOT NULL DEFAULT FALSE`,
+		`ALTER TABLE ban_list_words ADD COLUMN IF NOT EXISTS stemming VARCHAR(8) NOT NULL DEFAULT ''`,
+	}
+
+	for _, alterQuery := range alterQueries {
+		if _, err := m.db.ExecContext(ctx, alterQuery); err != nil {
+			return fmt.Errorf("failed to add ban_list_words option columns: %w", err)
+		}
+	}
+
+	return nil
+}
+
+// ensureUserSettingsVersionColumn ensures that user settings record their version, so that
+// updates based on an older version can be refused. Existing settings start at version 1.
+//
+// Parameters:
+//   - ctx: Context for database operations and cancellation
+//
+// Returns:
+//   - error: Any error encountered while ensuring the column exists, nil if successful
+func (m *Migrator) ensureUserSettingsVersionColumn(ctx context.Context) error {
+	alterQuery := `ALTER TABLE user_settings ADD COLUMN IF NOT EXISTS version BIGINT NOT NULL DEFAULT 1`
+	if _, err := m.db.ExecContext(ctx, alterQuery); err != nil {
+		return fmt.Errorf("failed to add user_settings version column: %w", err)
+	}
+
+	return nil
+}
+
+// ensureDocumentLifecycleColumns ensures that documents record whether they are exempt from
+// stale document cleanup and when their owner was told they are stale. Existing documents
+// are not exempt and nobody has been told about them yet.
+//
+// Parameters:
+//   - ctx: Context for database operations and cancellation
+//
+// Returns:
+//   - error: Any error encountered while ensuring the columns exist, nil if successful
+func (m *Migrator) ensureDocumentLifecycleColumns(ctx context.Context) error {
+	alterQueries := []string{
+		`ALTER TABLE documents ADD COLUMN IF NOT EXISTS lifecycle_exempt BOOLEAN NOT NULL DEFAULT FALSE`,
+		`ALTER TABLE documents ADD COLUMN IF NOT EXISTS stale_notified_at TIMESTAMP`,
+		`CREATE INDEX IF NOT EXISTS idx_documents_stale_notified ON documents(stale_notified_at) WHERE stale_notified_at IS NOT NULL`,
+	}
+
+	for _, alterQuery := range alterQueries {
+		if _, err := m.db.ExecContext(ctx, alterQuery); err != nil {
+			return fmt.Errorf("failed to add documents lifecycle columns: %w", err)
+		}
+	}
+
+	return nil
+}
+
+// ensureBanListOptionColumns ensures that ban lists record how all their words are matched.
+// Existing ban lists leave matching to the options of each word.
+//
+// Parameters:
+//   - ctx: Context for database operations and cancellation
+//
+// Returns:
+//   - error: Any error encountered while ensuring the columns exist, nil if successful
+func (m *Migrator) ensureBanListOptionColumns(ctx context.Context) error {
+	alterQueries := []string{
+		`ALTER TABLE ban_lists ADD COLUMN IF NOT EXISTS case_insensitive BOOLEAN NOT NULL DEFAULT FALSE`,
+		`ALTER TABLE ban_lists ADD COLUMN IF NOT EXISTS diacritic_insensitive BOOLEAN NOT NULL DEFAULT FALSE`,
+		`ALTER TABLE ban_lists ADD COLUMN IF NOT EXISTS whole_word BOOLEAN NOT NULL DEFAULT FALSE`,
+	}
+
+	for _, alterQuery := range alterQueries {
+		if _, err := m.db.ExecContext(ctx, alterQuery); err != nil {
+			return fmt.Errorf("failed to add ban_lists option columns: %w", err)
+		}
+	}
+
+	return nil
+}
+
+// ensureJobExecutionContextColumn ensures that jobs keep the context of the request that
+// queued them. Jobs queued before run without one, as they always have.
+//
+// Parameters:
+//   - ctx: Context for database operations and cancellation
+//
+// Returns:
+//   - error: Any error encountered while ensuring the column exists, nil if successful
+func (m *Migrator) ensureJobExecutionContextColumn(ctx context.Context) error {
+	alterQuery := `ALTER TABLE jobs ADD COLUMN IF NOT EXISTS execution_context JSONB NOT NULL DEFAULT '{}'`
+	if _, err := m.db.ExecContext(ctx, alterQuery); err != nil {
+		return fmt.Errorf("failed to add jobs execution_context column: %w", err)
+	}
+
+	return nil
+}
+
+// ensureDocumentRetentionColumn ensures that user settings have the number of days the
+// user's documents are kept; existing users keep their documents until they delete them.
+//
+// Parameters:
+//   - ctx: Context for database operations and cancellation
+//
+// Returns:
+//   - error: Any error encountered while ensuring the column exists, nil if successful
+func (m *Migrator) ensureDocumentRetentionColumn(ctx context.Context) error {
+	alterQuery := `ALTER TABLE user_settings ADD COLUMN IF NOT EXISTS document_retention_days INTEGER NOT NULL DEFAULT 0`
+	if _, err := m.db.ExecContext(ctx, alterQuery); err != nil {
+		return fmt.Errorf("failed to add user_settings document_retention_days column: %w", err)
+	}
+
+	return nil
+}
+
+// ensureDocumentTransferStatusColumns ensures that document transfers record whether all
+// their documents have been handled; transfers recorded before were written once complete.
+//
+// Parameters:
+//   - ctx: Context for database operations and cancellation
+//
+// Returns:
+//   - error: Any error encountered while ensuring the columns exist, nil if successful
+func (m *Migrator) ensureDocumentTransferStatusColumns(ctx context.Context) error {
+	alterQueries := []string{
+		`ALTER TABLE document_transfers ADD COLUMN IF NOT EXISTS status VARCHAR(20) NOT NULL DEFAULT 'completed'`,
+		`ALTER TABLE document_transfers ADD COLUMN IF NOT EXISTS completed_at TIMESTAMP`,
+	}
+
+	for _, alterQuery := range alterQueries {
+		if _, err := m.db.ExecContext(ctx, alterQuery); err != nil {
+			return fmt.Errorf("failed to add document_transfers status columns: %w", err)
+		}
+	}
+
+	return nil
+}
+
+// ensureKeysetCursorIndexes ensures that the pages after a keyset cursor of documents and
+// detected entities are read from an index in the order they are listed, instead of sorting
+// all rows of the user or document for every page.
+//
+// Parameters:
+//   - ctx: Context for database operations and cancellation
+//
+// Returns:
+//   - error: Any error encountered while ensuring the indexes exist, nil if successful
+func (m *Migrator) ensureKeysetCursorIndexes(ctx context.Context) error {
+	indexQueries := []string{
+		`CREATE INDEX IF NOT EXISTS idx_documents_user_keyset ON documents(user_id, upload_timestamp DESC, document_id DESC)`,
+		`CREATE INDEX IF NOT EXISTS idx_detected_entities_document_keyset ON detected_entities(document_id, detected_timestamp DESC, entity_id DESC)`,
+	}
+
+	for _, indexQuery := range indexQueries {
+		if _, err := m.db.ExecContext(ctx, indexQuery); err != nil {
+			return fmt.Errorf("failed to create keyset cursor indexes: %w", err)
+		}
+	}
+
+	return nil
+}
+
 // GetMigrations returns all migrations.
 // This function returns a slice of all migrations that the system should apply.
 //
@@ -427,6 +1045,45 @@ func GetMigrations() []Migration {
 		createAPIKeysTable(),
 		createIPBansTable(),              // TODO added this
 		createPasswordResetTokensTable(), // Added new migration
+		createSettingsRevisionsTable(),
+		createTenantUsageTable(),
+		createAdminActionsTable(),
+		createAccountHoldsTable(),
+		createTenantKeysTable(),
+		createReportSubscriptionsTable(),
+		createStatusIncidentsTable(),
+		createComponentUptimeTable(),
+		createClassificationRulesTable(),
+		createBenchmarkConsentsTable(),
+		createAnnouncementsTable(),
+		createAnnouncementReadsTable(),
+		createDriveConnectionsTable(),
+		createExportDestinationsTable(),
+		createExportDeliveriesTable(),
+		createRuleHitsTable(),
+		createProcessingAuditsTable(),
+		createAPIKeyRotationCampaignsTable(),
+		createAPIKeyPolicyTable(),
+		createUserIdentitiesTable(),
+		createDocumentArchivesTable(),
+		createUserDataExportsTable(),
+		createUserDataExportChunksTable(),
+		createRegistrationDomainsTable(),
+		createAccountErasuresTable(),
+		createDocumentContentsTable(),
+		createDocumentSharesTable(),
+		createEntityHashesTable(),
+		createLeakAlertsTable(),
+		createDocumentTransfersTable(),
+		createAuditorGrantsTable(),
+		createAuditorAccessLogTable(),
+		createMaintenanceTaskSettingsTable(),
+		createDocumentSchemaVersionsTable(),
+		createJobsTable(),
+		createDomainEventsTable(),
+		createWebhooksTable(),
+		createWebhookDeliveriesTable(),
+		createEntityTypesTable(),
 	}
 }
 
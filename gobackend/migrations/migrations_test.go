@@ -190,6 +190,10 @@ func TestRunMigrations(t *testing.T) {
 				// Check use_banlist_for_detection column exists
 				mock.ExpectQuery("SELECT EXISTS").
 					WillReturnRows(sqlmock.NewRows([]string{"exists"}).AddRow(1)) // Return 1 instead of true
+
+				// Check redaction_placeholders column exists
+				mock.ExpectQuery("SELECT EXISTS").
+					WillReturnRows(sqlmock.NewRows([]string{"exists"}).AddRow(1)) // Return 1 instead of true
 			},
 			wantErr: false,
 		},
@@ -235,6 +239,14 @@ func TestRunMigrations(t *testing.T) {
 				// Add the missing column
 				mock.ExpectExec("ALTER TABLE user_settings ADD COLUMN use_banlist_for_detection").
 					WillReturnResult(sqlmock.NewResult(0, 0))
+
+				// Check redaction_placeholders column doesn't exist
+				mock.ExpectQuery("SELECT EXISTS").
+					WillReturnRows(sqlmock.NewRows([]string{"exists"}).AddRow(0)) // Return 0 instead of false
+
+				// Add the missing column
+				mock.ExpectExec("ALTER TABLE user_settings ADD COLUMN redaction_placeholders").
+					WillReturnResult(sqlmock.NewResult(0, 0))
 			},
 			wantErr: false,
 		},
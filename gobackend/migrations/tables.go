@@ -63,8 +63,12 @@ func createUserSettingsTable() Migration {
 					use_banlist_for_detection BOOLEAN DEFAULT TRUE,
 					detection_threshold DECIMAL(5, 2) DEFAULT 0.50,
                     auto_processing BOOLEAN DEFAULT TRUE,
+                    redaction_placeholders JSONB NOT NULL DEFAULT '{}',
+                    scrub_filenames BOOLEAN NOT NULL DEFAULT FALSE,
+                    document_retention_days INTEGER NOT NULL DEFAULT 0,
                     created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
                     updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+                    version BIGINT NOT NULL DEFAULT 1,
                     CONSTRAINT fk_user FOREIGN KEY (user_id) REFERENCES users(user_id) ON DELETE CASCADE,
                     CONSTRAINT idx_user_id UNIQUE (user_id)
                 )
@@ -94,6 +98,18 @@ func createDocumentsTable() Migration {
 					upload_timestamp TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
 					last_modified TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
 					redaction_schema JSONB NOT NULL DEFAULT '{}',
+					language VARCHAR(8) NOT NULL DEFAULT '',
+					schema_version INTEGER NOT NULL DEFAULT 1,
+					tags TEXT[] NOT NULL DEFAULT '{}',
+					folder VARCHAR(255) NOT NULL DEFAULT '',
+					source VARCHAR(50) NOT NULL DEFAULT '',
+					retain_until TIMESTAMP,
+					filename_scrubbed BOOLEAN NOT NULL DEFAULT FALSE,
+					archived_at TIMESTAMP,
+					entity_count INTEGER,
+					entity_types TEXT[] NOT NULL DEFAULT '{}',
+					lifecycle_exempt BOOLEAN NOT NULL DEFAULT FALSE,
+					stale_notified_at TIMESTAMP,
 					CONSTRAINT fk_user FOREIGN KEY (user_id) REFERENCES users(user_id) ON DELETE CASCADE
 				)
 			`
@@ -189,6 +205,9 @@ func createDetectedEntitiesTable() Migration {
                     entity_name VARCHAR(255) NOT NULL DEFAULT '',
                     redaction_schema JSONB NOT NULL DEFAULT '{}',
                     detected_timestamp TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+                    status VARCHAR(20) NOT NULL DEFAULT 'pending',
+                    reviewed_by BIGINT,
+                    reviewed_at TIMESTAMP,
                     CONSTRAINT fk_document FOREIGN KEY (document_id) REFERENCES documents(document_id) ON DELETE CASCADE,
                     CONSTRAINT fk_method FOREIGN KEY (method_id) REFERENCES detection_methods(method_id)
                 )
@@ -203,6 +222,7 @@ func createDetectedEntitiesTable() Migration {
 				`CREATE INDEX IF NOT EXISTS idx_document_id ON detected_entities(document_id)`,
 				`CREATE INDEX IF NOT EXISTS idx_method_id ON detected_entities(method_id)`,
 				`CREATE INDEX IF NOT EXISTS idx_entity_name ON detected_entities(entity_name)`,
+				`CREATE INDEX IF NOT EXISTS idx_detected_entities_document_status ON detected_entities(document_id, status)`,
 			}
 
 			for _, idx := range indexes {
@@ -278,6 +298,9 @@ func createBanListTable() Migration {
                 CREATE TABLE IF NOT EXISTS ban_lists (
                     ban_id BIGINT PRIMARY KEY GENERATED ALWAYS AS IDENTITY,
                     setting_id BIGINT NOT NULL,
+                    case_insensitive BOOLEAN NOT NULL DEFAULT FALSE,
+                    diacritic_insensitive BOOLEAN NOT NULL DEFAULT FALSE,
+                    whole_word BOOLEAN NOT NULL DEFAULT FALSE,
                     CONSTRAINT fk_setting FOREIGN KEY (setting_id) REFERENCES user_settings(setting_id) ON DELETE CASCADE,
                     CONSTRAINT idx_setting_id_1 UNIQUE (setting_id)
                 )
@@ -304,6 +327,10 @@ func createBanListWordsTable() Migration {
                     ban_word_id BIGINT PRIMARY KEY GENERATED ALWAYS AS IDENTITY,
                     ban_id BIGINT NOT NULL,
                     word VARCHAR(255) NOT NULL DEFAULT '',
+                    case_insensitive BOOLEAN NOT NULL DEFAULT FALSE,
+                    diacritic_insensitive BOOLEAN NOT NULL DEFAULT FALSE,
+                    whole_word BOOLEAN NOT NULL DEFAULT FALSE,
+                    stemming VARCHAR(8) NOT NULL DEFAULT '',
                     CONSTRAINT fk_ban_list FOREIGN KEY (ban_id) REFERENCES ban_lists(ban_id) ON DELETE CASCADE,
                     CONSTRAINT idx_ban_word UNIQUE (ban_id, word)
                 )
@@ -349,6 +376,10 @@ func createSessionsTable() Migration {
 					jwt_id VARCHAR(255) NOT NULL,
 					expires_at TIMESTAMP NOT NULL,
 					created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+					rotated_from VARCHAR(255),
+					rotated_at TIMESTAMP,
+					used_after_rotation_at TIMESTAMP,
+					last_activity_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
 					CONSTRAINT fk_user FOREIGN KEY (user_id) REFERENCES users(user_id) ON DELETE CASCADE
 				)
 			`
@@ -362,6 +393,7 @@ func createSessionsTable() Migration {
 				`CREATE INDEX IF NOT EXISTS idx_user_id ON sessions(user_id)`,
 				`CREATE INDEX IF NOT EXISTS idx_jwt_id ON sessions(jwt_id)`,
 				`CREATE INDEX IF NOT EXISTS idx_expires_at ON sessions(expires_at)`,
+				`CREATE INDEX IF NOT EXISTS idx_sessions_last_activity_at ON sessions(last_activity_at)`,
 			}
 
 			for _, idx := range indexes {
@@ -462,3 +494,1421 @@ func createPasswordResetTokensTable() Migration {
 		},
 	}
 }
+
+// createSettingsRevisionsTable creates the settings_revisions table.
+// This table stores the revision history of user settings, which backs the
+// change feed used by desktop clients to synchronize incrementally.
+//
+// Returns:
+//   - Migration: A migration that creates the settings_revisions table
+func createSettingsRevisionsTable() Migration {
+	return Migration{
+		Name:        "create_settings_revisions_table",
+		Description: "Creates the settings_revisions table",
+		TableName:   constants.TableSettingsRevisions,
+		RunSQL: func(ctx context.Context, tx *sql.Tx) error {
+			query := `
+				CREATE TABLE IF NOT EXISTS settings_revisions (
+					revision_id BIGINT PRIMARY KEY GENERATED ALWAYS AS IDENTITY,
+					user_id BIGINT NOT NULL,
+					resource_type VARCHAR(30) NOT NULL,
+					resource_id BIGINT NOT NULL,
+					action VARCHAR(10) NOT NULL CHECK (action IN ('created', 'updated', 'deleted')),
+					data JSONB,
+					created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+					CONSTRAINT fk_user_revision FOREIGN KEY (user_id) REFERENCES users(user_id) ON DELETE CASCADE
+				)
+			`
+			_, err := tx.ExecContext(ctx, query)
+			if err != nil {
+				return err
+			}
+
+			indexQuery := `CREATE INDEX IF NOT EXISTS idx_user_revision ON settings_revisions(user_id, revision_id)`
+			_, err = tx.ExecContext(ctx, indexQuery)
+			return err
+		},
+	}
+}
+
+// createTenantUsageTable creates the tenant_usage table.
+// This table stores monthly usage rollups per tenant, which are exported
+// to the billing system.
+//
+// Returns:
+//   - Migration: A migration that creates the tenant_usage table
+func createTenantUsageTable() Migration {
+	return Migration{
+		Name:        "create_tenant_usage_table",
+		Description: "Creates the tenant_usage table",
+		TableName:   constants.TableTenantUsage,
+		RunSQL: func(ctx context.Context, tx *sql.Tx) error {
+			query := `
+				CREATE TABLE IF NOT EXISTS tenant_usage (
+					user_id BIGINT NOT NULL,
+					usage_month DATE NOT NULL,
+					document_count BIGINT NOT NULL DEFAULT 0,
+					page_count BIGINT NOT NULL DEFAULT 0,
+					storage_bytes BIGINT NOT NULL DEFAULT 0,
+					api_call_count BIGINT NOT NULL DEFAULT 0,
+					updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+					PRIMARY KEY (user_id, usage_month),
+					CONSTRAINT fk_user_usage FOREIGN KEY (user_id) REFERENCES users(user_id) ON DELETE CASCADE
+				)
+			`
+			_, err := tx.ExecContext(ctx, query)
+			if err != nil {
+				return err
+			}
+
+			indexQuery := `CREATE INDEX IF NOT EXISTS idx_usage_month ON tenant_usage(usage_month)`
+			_, err = tx.ExecContext(ctx, indexQuery)
+			return err
+		},
+	}
+}
+
+// createAdminActionsTable creates the admin_actions table.
+// This table stores the queue of destructive admin actions awaiting a second
+// administrator's approval, together with their outcome. It deliberately has no
+// foreign keys to users so that the record survives the erasure it describes.
+//
+// Returns:
+//   - Migration: A migration that creates the admin_actions table
+func createAdminActionsTable() Migration {
+	return Migration{
+		Name:        "create_admin_actions_table",
+		Description: "Creates the admin_actions table",
+		TableName:   constants.TableAdminActions,
+		RunSQL: func(ctx context.Context, tx *sql.Tx) error {
+			query := `
+				CREATE TABLE IF NOT EXISTS admin_actions (
+					action_id BIGINT PRIMARY KEY GENERATED ALWAYS AS IDENTITY,
+					action_type VARCHAR(50) NOT NULL,
+					target_id BIGINT NOT NULL,
+					reason TEXT NOT NULL,
+					status VARCHAR(20) NOT NULL CHECK (status IN ('pending', 'approved', 'executed', 'failed', 'rejected', 'expired')),
+					requested_by BIGINT NOT NULL,
+					reviewed_by BIGINT,
+					review_note TEXT,
+					execution_error TEXT,
+					created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+					expires_at TIMESTAMP NOT NULL,
+					reviewed_at TIMESTAMP
+				)
+			`
+			_, err := tx.ExecContext(ctx, query)
+			if err != nil {
+				return err
+			}
+
+			indexQuery := `CREATE INDEX IF NOT EXISTS idx_admin_action_status ON admin_actions(status, expires_at)`
+			_, err = tx.ExecContext(ctx, indexQuery)
+			return err
+		},
+	}
+}
+
+// createAccountHoldsTable creates the account_holds table.
+// This table stores accounts held back by risk scoring until the user verifies
+// their email address or an administrator releases the hold.
+//
+// Returns:
+//   - Migration: A migration that creates the account_holds table
+func createAccountHoldsTable() Migration {
+	return Migration{
+		Name:        "create_account_holds_table",
+		Description: "Creates the account_holds table",
+		TableName:   constants.TableAccountHolds,
+		RunSQL: func(ctx context.Context, tx *sql.Tx) error {
+			query := `
+				CREATE TABLE IF NOT EXISTS account_holds (
+					user_id BIGINT PRIMARY KEY,
+					hold_type VARCHAR(30) NOT NULL CHECK (hold_type IN ('email_verification', 'manual_review')),
+					risk_score INT NOT NULL,
+					reasons TEXT,
+					token_hash VARCHAR(255),
+					created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+					expires_at TIMESTAMP,
+					CONSTRAINT fk_user_hold FOREIGN KEY (user_id) REFERENCES users(user_id) ON DELETE CASCADE
+				)
+			`
+			_, err := tx.ExecContext(ctx, query)
+			if err != nil {
+				return err
+			}
+
+			indexQuery := `CREATE UNIQUE INDEX IF NOT EXISTS idx_account_hold_token ON account_holds(token_hash)`
+			_, err = tx.ExecContext(ctx, indexQuery)
+			return err
+		},
+	}
+}
+
+// createTenantKeysTable creates the tenant_keys table.
+// This table stores the data-encryption key of each tenant, wrapped with the master key.
+// The key is removed together with the tenant, which makes the tenant's ciphertexts
+// unrecoverable even from backups.
+//
+// Returns:
+//   - Migration: A migration that creates the tenant_keys table
+func createTenantKeysTable() Migration {
+	return Migration{
+		Name:        "create_tenant_keys_table",
+		Description: "Creates the tenant_keys table",
+		TableName:   constants.TableTenantKeys,
+		RunSQL: func(ctx context.Context, tx *sql.Tx) error {
+			query := `
+				CREATE TABLE IF NOT EXISTS tenant_keys (
+					user_id BIGINT PRIMARY KEY,
+					wrapped_key TEXT NOT NULL,
+					created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+					CONSTRAINT fk_user_tenant_key FOREIGN KEY (user_id) REFERENCES users(user_id) ON DELETE CASCADE
+				)
+			`
+			_, err := tx.ExecContext(ctx, query)
+			return err
+		},
+	}
+}
+
+// createReportSubscriptionsTable creates the report_subscriptions table.
+// This table stores users' subscriptions to scheduled reports and when each is sent next.
+//
+// Returns:
+//   - Migration: A migration that creates the report_subscriptions table
+func createReportSubscriptionsTable() Migration {
+	return Migration{
+		Name:        "create_report_subscriptions_table",
+		Description: "Creates the report_subscriptions table",
+		TableName:   constants.TableReportSubscriptions,
+		RunSQL: func(ctx context.Context, tx *sql.Tx) error {
+			query := `
+				CREATE TABLE IF NOT EXISTS report_subscriptions (
+					subscription_id BIGINT PRIMARY KEY GENERATED ALWAYS AS IDENTITY,
+					user_id BIGINT NOT NULL,
+					report_type VARCHAR(30) NOT NULL CHECK (report_type IN ('weekly_entity_summary', 'monthly_usage')),
+					format VARCHAR(10) NOT NULL DEFAULT 'csv' CHECK (format IN ('csv')),
+					locale VARCHAR(10) NOT NULL DEFAULT '',
+					csv_delimiter VARCHAR(10) NOT NULL DEFAULT '',
+					next_run_at TIMESTAMP NOT NULL,
+					last_sent_at TIMESTAMP,
+					created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+					CONSTRAINT fk_user_report_subscription FOREIGN KEY (user_id) REFERENCES users(user_id) ON DELETE CASCADE,
+					CONSTRAINT uq_report_subscription UNIQUE (user_id, report_type)
+				)
+			`
+			_, err := tx.ExecContext(ctx, query)
+			if err != nil {
+				return err
+			}
+
+			indexQuery := `CREATE INDEX IF NOT EXISTS idx_report_subscription_next_run ON report_subscriptions(next_run_at)`
+			_, err = tx.ExecContext(ctx, indexQuery)
+			return err
+		},
+	}
+}
+
+// createClassificationRulesTable creates the classification_rules table.
+// This table stores the rules that tag, file and set the retention of documents at upload.
+//
+// Returns:
+//   - Migration: A migration that creates the classification_rules table
+func createClassificationRulesTable() Migration {
+	return Migration{
+		Name:        "create_classification_rules_table",
+		Description: "Creates the classification_rules table",
+		TableName:   constants.TableClassificationRules,
+		RunSQL: func(ctx context.Context, tx *sql.Tx) error {
+			query := `
+				CREATE TABLE IF NOT EXISTS classification_rules (
+					rule_id BIGINT PRIMARY KEY GENERATED ALWAYS AS IDENTITY,
+					setting_id BIGINT NOT NULL,
+					name VARCHAR(100) NOT NULL,
+					priority INTEGER NOT NULL DEFAULT 0,
+					filename_pattern VARCHAR(255) NOT NULL DEFAULT '',
+					source VARCHAR(50) NOT NULL DEFAULT '',
+					entity_types TEXT[] NOT NULL DEFAULT '{}',
+					min_entities INTEGER NOT NULL DEFAULT 0,
+					tags TEXT[] NOT NULL DEFAULT '{}',
+					folder VARCHAR(255) NOT NULL DEFAULT '',
+					retention_days INTEGER NOT NULL DEFAULT 0,
+					CONSTRAINT fk_setting_classification_rule FOREIGN KEY (setting_id) REFERENCES user_settings(setting_id) ON DELETE CASCADE
+				)
+			`
+			_, err := tx.ExecContext(ctx, query)
+			if err != nil {
+				return err
+			}
+
+			indexQuery := `CREATE INDEX IF NOT EXISTS idx_classification_rule_setting ON classification_rules(setting_id, priority)`
+			_, err = tx.ExecContext(ctx, indexQuery)
+			return err
+		},
+	}
+}
+
+// createBenchmarkConsentsTable creates the benchmark_consents table.
+// This table stores the tenants that share their statistics for the cross-tenant benchmarks.
+//
+// Returns:
+//   - Migration: A migration that creates the benchmark_consents table
+func createBenchmarkConsentsTable() Migration {
+	return Migration{
+		Name:        "create_benchmark_consents_table",
+		Description: "Creates the benchmark_consents table",
+		TableName:   constants.TableBenchmarkConsents,
+		RunSQL: func(ctx context.Context, tx *sql.Tx) error {
+			query := `
+				CREATE TABLE IF NOT EXISTS benchmark_consents (
+					user_id BIGINT PRIMARY KEY,
+					industry VARCHAR(30) NOT NULL,
+					consented_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+					CONSTRAINT fk_user_benchmark_consent FOREIGN KEY (user_id) REFERENCES users(user_id) ON DELETE CASCADE
+				)
+			`
+			_, err := tx.ExecContext(ctx, query)
+			return err
+		},
+	}
+}
+
+// createStatusIncidentsTable creates the status_incidents table.
+// This table stores the incident notes administrators publish on the public status page.
+//
+// Returns:
+//   - Migration: A migration that creates the status_incidents table
+func createStatusIncidentsTable() Migration {
+	return Migration{
+		Name:        "create_status_incidents_table",
+		Description: "Creates the status_incidents table",
+		TableName:   constants.TableStatusIncidents,
+		RunSQL: func(ctx context.Context, tx *sql.Tx) error {
+			query := `
+				CREATE TABLE IF NOT EXISTS status_incidents (
+					incident_id BIGINT PRIMARY KEY GENERATED ALWAYS AS IDENTITY,
+					title VARCHAR(200) NOT NULL,
+					message TEXT NOT NULL,
+					impact VARCHAR(20) NOT NULL CHECK (impact IN ('minor', 'major', 'critical')),
+					status VARCHAR(20) NOT NULL DEFAULT 'investigating' CHECK (status IN ('investigating', 'identified', 'monitoring', 'resolved')),
+					created_by BIGINT,
+					created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+					updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+					resolved_at TIMESTAMP,
+					CONSTRAINT fk_user_status_incident FOREIGN KEY (created_by) REFERENCES users(user_id) ON DELETE SET NULL
+				)
+			`
+			_, err := tx.ExecContext(ctx, query)
+			if err != nil {
+				return err
+			}
+
+			indexQuery := `CREATE INDEX IF NOT EXISTS idx_status_incident_resolved ON status_incidents(resolved_at)`
+			_, err = tx.ExecContext(ctx, indexQuery)
+			return err
+		},
+	}
+}
+
+// createComponentUptimeTable creates the component_uptime table.
+// This table stores per component and hour how many health checks were run and how many
+// of them passed, from which the uptime percentages on the status page are calculated.
+//
+// Returns:
+//   - Migration: A migration that creates the component_uptime table
+func createComponentUptimeTable() Migration {
+	return Migration{
+		Name:        "create_component_uptime_table",
+		Description: "Creates the component_uptime table",
+		TableName:   constants.TableComponentUptime,
+		RunSQL: func(ctx context.Context, tx *sql.Tx) error {
+			query := `
+				CREATE TABLE IF NOT EXISTS component_uptime (
+					component VARCHAR(50) NOT NULL,
+					period_start TIMESTAMP NOT NULL,
+					checks INT NOT NULL DEFAULT 0,
+					healthy_checks INT NOT NULL DEFAULT 0,
+					PRIMARY KEY (component, period_start)
+				)
+			`
+			_, err := tx.ExecContext(ctx, query)
+			return err
+		},
+	}
+}
+
+// createAnnouncementsTable creates the announcements table.
+// This table stores the announcements, such as maintenance windows and new-feature notices,
+// that administrators publish to users.
+//
+// Returns:
+//   - Migration: A migration that creates the announcements table
+func createAnnouncementsTable() Migration {
+	return Migration{
+		Name:        "create_announcements_table",
+		Description: "Creates the announcements table",
+		TableName:   constants.TableAnnouncements,
+		RunSQL: func(ctx context.Context, tx *sql.Tx) error {
+			query := `
+				CREATE TABLE IF NOT EXISTS announcements (
+					announcement_id BIGINT PRIMARY KEY GENERATED ALWAYS AS IDENTITY,
+					title VARCHAR(200) NOT NULL,
+					message TEXT NOT NULL,
+					category VARCHAR(20) NOT NULL CHECK (category IN ('maintenance', 'feature', 'info')),
+					starts_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+					ends_at TIMESTAMP,
+					created_by BIGINT,
+					created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+					updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+					CONSTRAINT chk_announcement_window CHECK (ends_at IS NULL OR ends_at > starts_at),
+					CONSTRAINT fk_user_announcement FOREIGN KEY (created_by) REFERENCES users(user_id) ON DELETE SET NULL
+				)
+			`
+			_, err := tx.ExecContext(ctx, query)
+			if err != nil {
+				return err
+			}
+
+			indexQuery := `CREATE INDEX IF NOT EXISTS idx_announcement_window ON announcements(starts_at, ends_at)`
+			_, err = tx.ExecContext(ctx, indexQuery)
+			return err
+		},
+	}
+}
+
+// createAnnouncementReadsTable creates the announcement_reads table.
+// This table records which users have read which announcements.
+//
+// Returns:
+//   - Migration: A migration that creates the announcement_reads table
+func createAnnouncementReadsTable() Migration {
+	return Migration{
+		Name:        "create_announcement_reads_table",
+		Description: "Creates the announcement_reads table",
+		TableName:   constants.TableAnnouncementReads,
+		RunSQL: func(ctx context.Context, tx *sql.Tx) error {
+			query := `
+				CREATE TABLE IF NOT EXISTS announcement_reads (
+					announcement_id BIGINT NOT NULL,
+					user_id BIGINT NOT NULL,
+					read_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+					PRIMARY KEY (announcement_id, user_id),
+					CONSTRAINT fk_announcement_read FOREIGN KEY (announcement_id) REFERENCES announcements(announcement_id) ON DELETE CASCADE,
+					CONSTRAINT fk_user_announcement_read FOREIGN KEY (user_id) REFERENCES users(user_id) ON DELETE CASCADE
+				)
+			`
+			_, err := tx.ExecContext(ctx, query)
+			return err
+		},
+	}
+}
+
+// createDriveConnectionsTable creates the drive_connections table.
+// This table stores users' authorizations of cloud drives, with the tokens encrypted
+// with the tenant's data-encryption key.
+//
+// Returns:
+//   - Migration: A migration that creates the drive_connections table
+func createDriveConnectionsTable() Migration {
+	return Migration{
+		Name:        "create_drive_connections_table",
+		Description: "Creates the drive_connections table",
+		TableName:   constants.TableDriveConnections,
+		RunSQL: func(ctx context.Context, tx *sql.Tx) error {
+			query := `
+				CREATE TABLE IF NOT EXISTS drive_connections (
+					user_id BIGINT NOT NULL,
+					provider VARCHAR(20) NOT NULL CHECK (provider IN ('google_drive', 'onedrive', 'sharepoint')),
+					access_token TEXT NOT NULL,
+					refresh_token TEXT NOT NULL DEFAULT '',
+					expires_at TIMESTAMP NOT NULL,
+					created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+					updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+					PRIMARY KEY (user_id, provider),
+					CONSTRAINT fk_user_drive_connection FOREIGN KEY (user_id) REFERENCES users(user_id) ON DELETE CASCADE
+				)
+			`
+			_, err := tx.ExecContext(ctx, query)
+			return err
+		},
+	}
+}
+
+// createExportDestinationsTable creates the export_destinations table.
+// This table stores the destinations users deliver redacted documents to, with the
+// connection settings and credentials encrypted with the tenant's data-encryption key.
+//
+// Returns:
+//   - Migration: A migration that creates the export_destinations table
+func createExportDestinationsTable() Migration {
+	return Migration{
+		Name:        "create_export_destinations_table",
+		Description: "Creates the export_destinations table",
+		TableName:   constants.TableExportDestinations,
+		RunSQL: func(ctx context.Context, tx *sql.Tx) error {
+			query := `
+				CREATE TABLE IF NOT EXISTS export_destinations (
+					destination_id BIGINT PRIMARY KEY GENERATED ALWAYS AS IDENTITY,
+					user_id BIGINT NOT NULL,
+					name VARCHAR(100) NOT NULL,
+					destination_type VARCHAR(20) NOT NULL CHECK (destination_type IN ('s3', 'sftp', 'sharepoint')),
+					config TEXT NOT NULL,
+					auto_deliver BOOLEAN NOT NULL DEFAULT TRUE,
+					created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+					updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+					CONSTRAINT fk_user_export_destination FOREIGN KEY (user_id) REFERENCES users(user_id) ON DELETE CASCADE,
+					CONSTRAINT uq_export_destination_name UNIQUE (user_id, name)
+				)
+			`
+			_, err := tx.ExecContext(ctx, query)
+			return err
+		},
+	}
+}
+
+// createExportDeliveriesTable creates the export_deliveries table.
+// This table tracks the delivery of each document to each export destination.
+//
+// Returns:
+//   - Migration: A migration that creates the export_deliveries table
+func createExportDeliveriesTable() Migration {
+	return Migration{
+		Name:        "create_export_deliveries_table",
+		Description: "Creates the export_deliveries table",
+		TableName:   constants.TableExportDeliveries,
+		RunSQL: func(ctx context.Context, tx *sql.Tx) error {
+			query := `
+				CREATE TABLE IF NOT EXISTS export_deliveries (
+					delivery_id BIGINT PRIMARY KEY GENERATED ALWAYS AS IDENTITY,
+					destination_id BIGINT NOT NULL,
+					document_id BIGINT NOT NULL,
+					status VARCHAR(20) NOT NULL CHECK (status IN ('pending', 'delivered', 'failed')),
+					attempts INT NOT NULL DEFAULT 0,
+					object_name VARCHAR(1024) NOT NULL DEFAULT '',
+					last_error TEXT NOT NULL DEFAULT '',
+					created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+					updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+					delivered_at TIMESTAMP,
+					CONSTRAINT fk_destination_export_delivery FOREIGN KEY (destination_id) REFERENCES export_destinations(destination_id) ON DELETE CASCADE,
+					CONSTRAINT fk_document_export_delivery FOREIGN KEY (document_id) REFERENCES documents(document_id) ON DELETE CASCADE,
+					CONSTRAINT uq_export_delivery UNIQUE (destination_id, document_id)
+				)
+			`
+			_, err := tx.ExecContext(ctx, query)
+			if err != nil {
+				return err
+			}
+
+			indexQuery := `CREATE INDEX IF NOT EXISTS idx_export_delivery_document ON export_deliveries(document_id)`
+			_, err = tx.ExecContext(ctx, indexQuery)
+			if err != nil {
+				return err
+			}
+
+			indexQuery = `CREATE INDEX IF NOT EXISTS idx_export_delivery_failed ON export_deliveries(updated_at) WHERE status = 'failed'`
+			_, err = tx.ExecContext(ctx, indexQuery)
+			return err
+		},
+	}
+}
+
+// createRuleHitsTable creates the rule_hits table.
+// This table counts the detections produced by each user's ban list words and search patterns.
+// Rules are identified by their text, so the counts of a removed rule are picked up again
+// if it is added back.
+//
+// Returns:
+//   - Migration: A migration that creates the rule_hits table
+func createRuleHitsTable() Migration {
+	return Migration{
+		Name:        "create_rule_hits_table",
+		Description: "Creates the rule_hits table",
+		TableName:   constants.TableRuleHits,
+		RunSQL: func(ctx context.Context, tx *sql.Tx) error {
+			query := `
+				CREATE TABLE IF NOT EXISTS rule_hits (
+					user_id BIGINT NOT NULL,
+					rule_type VARCHAR(20) NOT NULL CHECK (rule_type IN ('ban_list_word', 'search_pattern')),
+					rule_text TEXT NOT NULL,
+					hit_count BIGINT NOT NULL DEFAULT 0,
+					last_hit_at TIMESTAMP,
+					tracked_since TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+					PRIMARY KEY (user_id, rule_type, rule_text),
+					CONSTRAINT fk_user_rule_hit FOREIGN KEY (user_id) REFERENCES users(user_id) ON DELETE CASCADE
+				)
+			`
+			_, err := tx.ExecContext(ctx, query)
+			return err
+		},
+	}
+}
+
+// createProcessingAuditsTable creates the processing_audits table.
+// This table records documents processed in ephemeral mode. It holds nothing about the
+// document but when it was processed, by whom, and how many pages and entities it had.
+//
+// Returns:
+//   - Migration: A migration that creates the processing_audits table
+func createProcessingAuditsTable() Migration {
+	return Migration{
+		Name:        "create_processing_audits_table",
+		Description: "Creates the processing_audits table",
+		TableName:   constants.TableProcessingAudits,
+		RunSQL: func(ctx context.Context, tx *sql.Tx) error {
+			query := `
+				CREATE TABLE IF NOT EXISTS processing_audits (
+					audit_id BIGINT PRIMARY KEY GENERATED ALWAYS AS IDENTITY,
+					user_id BIGINT NOT NULL,
+					page_count INT NOT NULL DEFAULT 0,
+					entity_count INT NOT NULL DEFAULT 0,
+					processed_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+					CONSTRAINT fk_user_processing_audit FOREIGN KEY (user_id) REFERENCES users(user_id) ON DELETE CASCADE
+				)
+			`
+			_, err := tx.ExecContext(ctx, query)
+			if err != nil {
+				return err
+			}
+
+			indexQuery := `CREATE INDEX IF NOT EXISTS idx_processing_audit_user ON processing_audits(user_id, processed_at)`
+			_, err = tx.ExecContext(ctx, indexQuery)
+			return err
+		},
+	}
+}
+
+// createAPIKeyRotationCampaignsTable creates the api_key_rotation_campaigns table.
+// This table stores the forced rotations of API keys started by administrators. The keys
+// in a campaign reference it and carry its deadline themselves.
+//
+// Returns:
+//   - Migration: A migration that creates the api_key_rotation_campaigns table
+func createAPIKeyRotationCampaignsTable() Migration {
+	return Migration{
+		Name:        "create_api_key_rotation_campaigns_table",
+		Description: "Creates the api_key_rotation_campaigns table",
+		TableName:   constants.TableAPIKeyRotationCampaigns,
+		RunSQL: func(ctx context.Context, tx *sql.Tx) error {
+			query := `
+				CREATE TABLE IF NOT EXISTS api_key_rotation_campaigns (
+					campaign_id BIGINT PRIMARY KEY GENERATED ALWAYS AS IDENTITY,
+					name VARCHAR(100) NOT NULL,
+					deadline TIMESTAMP NOT NULL,
+					created_by BIGINT NOT NULL,
+					created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+				)
+			`
+			_, err := tx.ExecContext(ctx, query)
+			return err
+		},
+	}
+}
+
+// createAPIKeyPolicyTable creates the api_key_policy table.
+// This table holds a single row with the expiry policy applied to all API keys.
+//
+// Returns:
+//   - Migration: A migration that creates the api_key_policy table
+func createAPIKeyPolicyTable() Migration {
+	return Migration{
+		Name:        "create_api_key_policy_table",
+		Description: "Creates the api_key_policy table",
+		TableName:   constants.TableAPIKeyPolicy,
+		RunSQL: func(ctx context.Context, tx *sql.Tx) error {
+			query := `
+				CREATE TABLE IF NOT EXISTS api_key_policy (
+					policy_id SMALLINT PRIMARY KEY DEFAULT 1 CHECK (policy_id = 1),
+					max_lifetime_days INT NOT NULL DEFAULT 0,
+					updated_by BIGINT,
+					updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+				)
+			`
+			_, err := tx.ExecContext(ctx, query)
+			return err
+		},
+	}
+}
+
+// createUserIdentitiesTable creates the user_identities table.
+// This table links user accounts to the accounts users sign in with at OpenID Connect
+// providers; each provider account signs in to one user, and each user has at most one
+// account per provider.
+//
+// Returns:
+//   - Migration: A migration that creates the user_identities table
+func createUserIdentitiesTable() Migration {
+	return Migration{
+		Name:        "create_user_identities_table",
+		Description: "Creates the user_identities table",
+		TableName:   constants.TableUserIdentities,
+		RunSQL: func(ctx context.Context, tx *sql.Tx) error {
+			query := `
+				CREATE TABLE IF NOT EXISTS user_identities (
+					identity_id BIGINT PRIMARY KEY GENERATED ALWAYS AS IDENTITY,
+					user_id BIGINT NOT NULL,
+					provider VARCHAR(50) NOT NULL,
+					subject VARCHAR(255) NOT NULL,
+					email VARCHAR(255) NOT NULL DEFAULT '',
+					created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+					CONSTRAINT idx_identity_provider_subject UNIQUE (provider, subject),
+					CONSTRAINT idx_identity_user_provider UNIQUE (user_id, provider),
+					CONSTRAINT fk_user_identity FOREIGN KEY (user_id) REFERENCES users(user_id) ON DELETE CASCADE
+				)
+			`
+			_, err := tx.ExecContext(ctx, query)
+			return err
+		},
+	}
+}
+
+// createDocumentArchivesTable creates the document_archives table.
+// This table is the cold storage tier: it holds the redaction schemas and detected entities
+// of archived documents, as they were when archived, until the documents are restored. It
+// is only read on restore, so it can be moved to a tablespace on cheaper storage.
+//
+// Returns:
+//   - Migration: A migration that creates the document_archives table
+func createDocumentArchivesTable() Migration {
+	return Migration{
+		Name:        "create_document_archives_table",
+		Description: "Creates the document_archives table",
+		TableName:   constants.TableDocumentArchives,
+		RunSQL: func(ctx context.Context, tx *sql.Tx) error {
+			query := `
+				CREATE TABLE IF NOT EXISTS document_archives (
+					document_id BIGINT PRIMARY KEY,
+					redaction_schema JSONB NOT NULL DEFAULT '{}',
+					entities JSONB NOT NULL DEFAULT '[]',
+					archived_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+					CONSTRAINT fk_document_archive FOREIGN KEY (document_id) REFERENCES documents(document_id) ON DELETE CASCADE
+				)
+			`
+			_, err := tx.ExecContext(ctx, query)
+			return err
+		},
+	}
+}
+
+// createUserDataExportsTable creates the user_data_exports table.
+// This table tracks the jobs exporting all data kept about a user. Each job records its
+// checkpoint, the last document whose data it has written, so a job stopped by a restart
+// resumes there instead of starting over; lease_until tells other servers when a job
+// whose server stopped checkpointing may be taken over.
+//
+// Returns:
+//   - Migration: A migration that creates the user_data_exports table
+func createUserDataExportsTable() Migration {
+	return Migration{
+		Name:        "create_user_data_exports_table",
+		Description: "Creates the user_data_exports table",
+		TableName:   constants.TableUserDataExports,
+		RunSQL: func(ctx context.Context, tx *sql.Tx) error {
+			query := `
+				CREATE TABLE IF NOT EXISTS user_data_exports (
+					export_id BIGINT PRIMARY KEY GENERATED ALWAYS AS IDENTITY,
+					user_id BIGINT NOT NULL,
+					format VARCHAR(10) NOT NULL CHECK (format IN ('json', 'zip')),
+					status VARCHAR(20) NOT NULL CHECK (status IN ('pending', 'running', 'interrupted', 'completed', 'failed')),
+					last_document_id BIGINT NOT NULL DEFAULT 0,
+					last_entity_id BIGINT NOT NULL DEFAULT 0,
+					documents_exported BIGINT NOT NULL DEFAULT 0,
+					entities_exported BIGINT NOT NULL DEFAULT 0,
+					chunks INT NOT NULL DEFAULT 0,
+					resumes INT NOT NULL DEFAULT 0,
+					last_error TEXT NOT NULL DEFAULT '',
+					lease_until TIMESTAMP,
+					created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+					updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+					completed_at TIMESTAMP,
+					CONSTRAINT fk_user_data_export FOREIGN KEY (user_id) REFERENCES users(user_id) ON DELETE CASCADE
+				)
+			`
+			_, err := tx.ExecContext(ctx, query)
+			if err != nil {
+				return err
+			}
+
+			indexQuery := `CREATE INDEX IF NOT EXISTS idx_user_data_export_user ON user_data_exports(user_id)`
+			_, err = tx.ExecContext(ctx, indexQuery)
+			if err != nil {
+				return err
+			}
+
+			// A user has at most one unfinished export
+			indexQuery = `CREATE UNIQUE INDEX IF NOT EXISTS idx_user_data_export_active ON user_data_exports(user_id) WHERE status IN ('pending', 'running', 'interrupted')`
+			_, err = tx.ExecContext(ctx, indexQuery)
+			return err
+		},
+	}
+}
+
+// createUserDataExportChunksTable creates the user_data_export_chunks table.
+// This table holds the output of user data export jobs, one chunk per section and
+// checkpoint, encrypted with the data-encryption key of the user. Chunks are written in the
+// same transaction as the checkpoint, so a resumed job never repeats or skips data.
+//
+// Returns:
+//   - Migration: A migration that creates the user_data_export_chunks table
+func createUserDataExportChunksTable() Migration {
+	return Migration{
+		Name:        "create_user_data_export_chunks_table",
+		Description: "Creates the user_data_export_chunks table",
+		TableName:   constants.TableUserDataExportChunks,
+		RunSQL: func(ctx context.Context, tx *sql.Tx) error {
+			query := `
+				CREATE TABLE IF NOT EXISTS user_data_export_chunks (
+					export_id BIGINT NOT NULL,
+					seq INT NOT NULL,
+					section VARCHAR(30) NOT NULL,
+					data TEXT NOT NULL,
+					PRIMARY KEY (export_id, seq),
+					CONSTRAINT fk_user_data_export_chunk FOREIGN KEY (export_id) REFERENCES user_data_exports(export_id) ON DELETE CASCADE
+				)
+			`
+			_, err := tx.ExecContext(ctx, query)
+			return err
+		},
+	}
+}
+
+// createRegistrationDomainsTable creates the registration_domains table.
+// This table holds the email domains administrators limit registration to. A domain only
+// takes effect once it is verified through a DNS TXT record carrying its verification token.
+//
+// Returns:
+//   - Migration: A migration that creates the registration_domains table
+func createRegistrationDomainsTable() Migration {
+	return Migration{
+		Name:        "create_registration_domains_table",
+		Description: "Creates the registration_domains table",
+		TableName:   constants.TableRegistrationDomains,
+		RunSQL: func(ctx context.Context, tx *sql.Tx) error {
+			query := `
+				CREATE TABLE IF NOT EXISTS registration_domains (
+					domain_id BIGINT PRIMARY KEY GENERATED ALWAYS AS IDENTITY,
+					domain VARCHAR(253) NOT NULL UNIQUE,
+					verification_token VARCHAR(64) NOT NULL,
+					verified_at TIMESTAMP,
+					created_by BIGINT,
+					created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+					CONSTRAINT fk_registration_domain_creator FOREIGN KEY (created_by) REFERENCES users(user_id) ON DELETE SET NULL
+				)
+			`
+			_, err := tx.ExecContext(ctx, query)
+			return err
+		},
+	}
+}
+
+// createAccountErasuresTable creates the account_erasures table.
+// This table tracks the erasure of user accounts (GDPR Article 17). An erasure waits for
+// its grace period, during which the user can cancel it, and is then run in the background
+// and retried until every step succeeded. The user ID has no foreign key, so the record
+// that an account was erased outlives the account.
+//
+// Returns:
+//   - Migration: A migration that creates the account_erasures table
+func createAccountErasuresTable() Migration {
+	return Migration{
+		Name:        "create_account_erasures_table",
+		Description: "Creates the account_erasures table",
+		TableName:   constants.TableAccountErasures,
+		RunSQL: func(ctx context.Context, tx *sql.Tx) error {
+			query := `
+				CREATE TABLE IF NOT EXISTS account_erasures (
+					erasure_id BIGINT PRIMARY KEY GENERATED ALWAYS AS IDENTITY,
+					user_id BIGINT NOT NULL,
+					status VARCHAR(20) NOT NULL CHECK (status IN ('scheduled', 'running', 'completed', 'cancelled')),
+					scheduled_for TIMESTAMP NOT NULL,
+					attempts INT NOT NULL DEFAULT 0,
+					last_error TEXT NOT NULL DEFAULT '',
+					lease_until TIMESTAMP,
+					created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+					updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+					completed_at TIMESTAMP
+				)
+			`
+			_, err := tx.ExecContext(ctx, query)
+			if err != nil {
+				return err
+			}
+
+			// An account has at most one unfinished erasure
+			indexQuery := `CREATE UNIQUE INDEX IF NOT EXISTS idx_account_erasure_active ON account_erasures(user_id) WHERE status IN ('scheduled', 'running')`
+			_, err = tx.ExecContext(ctx, indexQuery)
+			if err != nil {
+				return err
+			}
+
+			indexQuery = `CREATE INDEX IF NOT EXISTS idx_account_erasure_due ON account_erasures(scheduled_for) WHERE status = 'scheduled'`
+			_, err = tx.ExecContext(ctx, indexQuery)
+			return err
+		},
+	}
+}
+
+// createDocumentContentsTable creates the document_contents table.
+// This table links documents to their encrypted PDF content in the document storage. The
+// document ID has no foreign key, so the link outlives a deleted document until its content
+// is deleted from the storage as well.
+//
+// Returns:
+//   - Migration: A migration that creates the document_contents table
+func createDocumentContentsTable() Migration {
+	return Migration{
+		Name:        "create_document_contents_table",
+		Description: "Creates the document_contents table",
+		TableName:   constants.TableDocumentContents,
+		RunSQL: func(ctx context.Context, tx *sql.Tx) error {
+			query := `
+				CREATE TABLE IF NOT EXISTS document_contents (
+					document_id BIGINT PRIMARY KEY,
+					storage_key VARCHAR(255) NOT NULL UNIQUE,
+					content_type VARCHAR(100) NOT NULL,
+					size_bytes BIGINT NOT NULL,
+					sha256 CHAR(64) NOT NULL,
+					segment_size INT NOT NULL DEFAULT 0,
+					created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+				)
+			`
+			_, err := tx.ExecContext(ctx, query)
+			return err
+		},
+	}
+}
+
+// createDocumentSharesTable creates the document_shares table.
+// This table grants other users access to a document: read access to view it, redact access
+// to change its redaction schema as well. Shares are deleted with the document and with
+// either user.
+//
+// Returns:
+//   - Migration: A migration that creates the document_shares table
+func createDocumentSharesTable() Migration {
+	return Migration{
+		Name:        "create_document_shares_table",
+		Description: "Creates the document_shares table",
+		TableName:   constants.TableDocumentShares,
+		RunSQL: func(ctx context.Context, tx *sql.Tx) error {
+			query := `
+				CREATE TABLE IF NOT EXISTS document_shares (
+					share_id BIGINT PRIMARY KEY GENERATED ALWAYS AS IDENTITY,
+					document_id BIGINT NOT NULL,
+					user_id BIGINT NOT NULL,
+					granted_by BIGINT NOT NULL,
+					permission VARCHAR(20) NOT NULL CHECK (permission IN ('read', 'redact')),
+					created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+					CONSTRAINT fk_document FOREIGN KEY (document_id) REFERENCES documents(document_id) ON DELETE CASCADE,
+					CONSTRAINT fk_user FOREIGN KEY (user_id) REFERENCES users(user_id) ON DELETE CASCADE,
+					CONSTRAINT fk_granted_by FOREIGN KEY (granted_by) REFERENCES users(user_id) ON DELETE CASCADE,
+					CONSTRAINT uq_document_share UNIQUE (document_id, user_id)
+				)
+			`
+			_, err := tx.ExecContext(ctx, query)
+			if err != nil {
+				return err
+			}
+
+			// Users list the documents shared with them
+			indexQuery := `CREATE INDEX IF NOT EXISTS idx_document_share_user ON document_shares(user_id)`
+			_, err = tx.ExecContext(ctx, indexQuery)
+			return err
+		},
+	}
+}
+
+// createEntityHashesTable creates the entity_hashes table.
+// This table stores a salted hash of each distinct value detected in a document, never the
+// value itself, so analytics can count distinct values and find values leaking in several
+// documents. Hashes are deleted with their document and user.
+//
+// Returns:
+//   - Migration: A migration that creates the entity_hashes table
+func createEntityHashesTable() Migration {
+	return Migration{
+		Name:        "create_entity_hashes_table",
+		Description: "Creates the entity_hashes table",
+		TableName:   constants.TableEntityHashes,
+		RunSQL: func(ctx context.Context, tx *sql.Tx) error {
+			query := `
+				CREATE TABLE IF NOT EXISTS entity_hashes (
+					hash_id BIGINT PRIMARY KEY GENERATED ALWAYS AS IDENTITY,
+					user_id BIGINT NOT NULL,
+					document_id BIGINT NOT NULL,
+					entity_type VARCHAR(100) NOT NULL,
+					value_hash CHAR(64) NOT NULL,
+					occurrences INT NOT NULL DEFAULT 1,
+					created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+					CONSTRAINT fk_user FOREIGN KEY (user_id) REFERENCES users(user_id) ON DELETE CASCADE,
+					CONSTRAINT fk_document FOREIGN KEY (document_id) REFERENCES documents(document_id) ON DELETE CASCADE,
+					CONSTRAINT uq_entity_hash UNIQUE (document_id, entity_type, value_hash)
+				)
+			`
+			_, err := tx.ExecContext(ctx, query)
+			if err != nil {
+				return err
+			}
+
+			// Reports group the values of a user by hash
+			indexQuery := `CREATE INDEX IF NOT EXISTS idx_entity_hash_user_value ON entity_hashes(user_id, value_hash)`
+			_, err = tx.ExecContext(ctx, indexQuery)
+			return err
+		},
+	}
+}
+
+// createLeakAlertsTable creates the leak_alerts table.
+// This table records the values a user was alerted about for leaking in many documents, so
+// that each value is alerted about at most once per alert window. Alerts are deleted with
+// their user.
+//
+// Returns:
+//   - Migration: A migration that creates the leak_alerts table
+func createLeakAlertsTable() Migration {
+	return Migration{
+		Name:        "create_leak_alerts_table",
+		Description: "Creates the leak_alerts table",
+		TableName:   constants.TableLeakAlerts,
+		RunSQL: func(ctx context.Context, tx *sql.Tx) error {
+			query := `
+				CREATE TABLE IF NOT EXISTS leak_alerts (
+					alert_id BIGINT PRIMARY KEY GENERATED ALWAYS AS IDENTITY,
+					user_id BIGINT NOT NULL,
+					entity_type VARCHAR(100) NOT NULL,
+					value_hash CHAR(64) NOT NULL,
+					document_count INT NOT NULL,
+					document_ids BIGINT[] NOT NULL DEFAULT '{}',
+					alerted_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+					CONSTRAINT fk_user FOREIGN KEY (user_id) REFERENCES users(user_id) ON DELETE CASCADE,
+					CONSTRAINT uq_leak_alert UNIQUE (user_id, entity_type, value_hash)
+				)
+			`
+			_, err := tx.ExecContext(ctx, query)
+			return err
+		},
+	}
+}
+
+// createDocumentTransfersTable creates the document_transfers table.
+// This table is the audit trail of administrators transferring documents between users, for
+// example when an employee leaves. The user IDs have no foreign keys, so the record of a
+// transfer outlives the accounts involved.
+//
+// Returns:
+//   - Migration: A migration that creates the document_transfers table
+func createDocumentTransfersTable() Migration {
+	return Migration{
+		Name:        "create_document_transfers_table",
+		Description: "Creates the document_transfers table",
+		TableName:   constants.TableDocumentTransfers,
+		RunSQL: func(ctx context.Context, tx *sql.Tx) error {
+			query := `
+				CREATE TABLE IF NOT EXISTS document_transfers (
+					transfer_id BIGINT PRIMARY KEY GENERATED ALWAYS AS IDENTITY,
+					from_user_id BIGINT NOT NULL,
+					to_user_id BIGINT NOT NULL,
+					transferred_by BIGINT NOT NULL,
+					reason TEXT NOT NULL,
+					document_ids BIGINT[] NOT NULL DEFAULT '{}',
+					failed_document_ids BIGINT[] NOT NULL DEFAULT '{}',
+					status VARCHAR(20) NOT NULL DEFAULT 'completed',
+					created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+					completed_at TIMESTAMP
+				)
+			`
+			_, err := tx.ExecContext(ctx, query)
+			if err != nil {
+				return err
+			}
+
+			indexQuery := `CREATE INDEX IF NOT EXISTS idx_document_transfer_created ON document_transfers(created_at DESC)`
+			_, err = tx.ExecContext(ctx, indexQuery)
+			return err
+		},
+	}
+}
+
+// createAuditorGrantsTable creates the auditor_grants table.
+// This table stores the time-boxed read-only access grants of external auditors; only the
+// hash of each token is kept.
+//
+// Returns:
+//   - Migration: A migration that creates the auditor_grants table
+func createAuditorGrantsTable() Migration {
+	return Migration{
+		Name:        "create_auditor_grants_table",
+		Description: "Creates the auditor_grants table",
+		TableName:   constants.TableAuditorGrants,
+		RunSQL: func(ctx context.Context, tx *sql.Tx) error {
+			query := `
+				CREATE TABLE IF NOT EXISTS auditor_grants (
+					grant_id BIGINT PRIMARY KEY GENERATED ALWAYS AS IDENTITY,
+					auditor_name VARCHAR(255) NOT NULL,
+					auditor_email VARCHAR(255) NOT NULL,
+					token_hash VARCHAR(64) NOT NULL UNIQUE,
+					document_ids BIGINT[] NOT NULL DEFAULT '{}',
+					user_ids BIGINT[] NOT NULL DEFAULT '{}',
+					reason TEXT NOT NULL,
+					created_by BIGINT NOT NULL,
+					expires_at TIMESTAMP NOT NULL,
+					revoked_at TIMESTAMP,
+					created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+				)
+			`
+			_, err := tx.ExecContext(ctx, query)
+			if err != nil {
+				return err
+			}
+
+			indexQuery := `CREATE INDEX IF NOT EXISTS idx_auditor_grant_created ON auditor_grants(created_at DESC)`
+			_, err = tx.ExecContext(ctx, indexQuery)
+			return err
+		},
+	}
+}
+
+// createAuditorAccessLogTable creates the auditor_access_log table.
+// This table logs every request made with an auditor access grant, including refused ones.
+//
+// Returns:
+//   - Migration: A migration that creates the auditor_access_log table
+func createAuditorAccessLogTable() Migration {
+	return Migration{
+		Name:        "create_auditor_access_log_table",
+		Description: "Creates the auditor_access_log table",
+		TableName:   constants.TableAuditorAccessLog,
+		RunSQL: func(ctx context.Context, tx *sql.Tx) error {
+			query := `
+				CREATE TABLE IF NOT EXISTS auditor_access_log (
+					access_id BIGINT PRIMARY KEY GENERATED ALWAYS AS IDENTITY,
+					grant_id BIGINT NOT NULL REFERENCES auditor_grants(grant_id) ON DELETE CASCADE,
+					action VARCHAR(50) NOT NULL,
+					document_id BIGINT,
+					allowed BOOLEAN NOT NULL,
+					ip_address VARCHAR(45) NOT NULL DEFAULT '',
+					accessed_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+				)
+			`
+			_, err := tx.ExecContext(ctx, query)
+			if err != nil {
+				return err
+			}
+
+			indexQuery := `CREATE INDEX IF NOT EXISTS idx_auditor_access_grant ON auditor_access_log(grant_id, accessed_at DESC)`
+			_, err = tx.ExecContext(ctx, indexQuery)
+			return err
+		},
+	}
+}
+
+// createMaintenanceTaskSettingsTable creates the maintenance_task_settings table.
+// This table stores the settings administrators chose for maintenance tasks; tasks
+// without a row run with the defaults.
+//
+// Returns:
+//   - Migration: A migration that creates the maintenance_task_settings table
+func createMaintenanceTaskSettingsTable() Migration {
+	return Migration{
+		Name:        "create_maintenance_task_settings_table",
+		Description: "Creates the maintenance_task_settings table",
+		TableName:   constants.TableMaintenanceTaskSettings,
+		RunSQL: func(ctx context.Context, tx *sql.Tx) error {
+			query := `
+				CREATE TABLE IF NOT EXISTS maintenance_task_settings (
+					task_name VARCHAR(100) PRIMARY KEY,
+					enabled BOOLEAN NOT NULL DEFAULT TRUE,
+					interval_seconds INTEGER NOT NULL,
+					batch_size INTEGER NOT NULL DEFAULT 0,
+					dry_run BOOLEAN NOT NULL DEFAULT FALSE,
+					updated_by BIGINT,
+					updated_at TIMESTAMP
+				)
+			`
+			_, err := tx.ExecContext(ctx, query)
+			return err
+		},
+	}
+}
+
+// createDocumentSchemaVersionsTable creates the document_schema_versions table.
+// This table keeps the redaction schema a document had each time it was replaced, encrypted
+// like the schema of the document, so earlier redaction states can be restored. Versions
+// are deleted with their document.
+//
+// Returns:
+//   - Migration: A migration that creates the document_schema_versions table
+func createDocumentSchemaVersionsTable() Migration {
+	return Migration{
+		Name:        "create_document_schema_versions_table",
+		Description: "Creates the document_schema_versions table",
+		TableName:   constants.TableDocumentSchemaVersions,
+		RunSQL: func(ctx context.Context, tx *sql.Tx) error {
+			query := `
+				CREATE TABLE IF NOT EXISTS document_schema_versions (
+					version_id BIGINT PRIMARY KEY GENERATED ALWAYS AS IDENTITY,
+					document_id BIGINT NOT NULL,
+					version INTEGER NOT NULL,
+					redaction_schema TEXT NOT NULL,
+					entity_count INTEGER NOT NULL DEFAULT 0,
+					entity_types TEXT[] NOT NULL DEFAULT '{}',
+					replaced_by BIGINT NOT NULL,
+					replaced_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+					CONSTRAINT fk_document FOREIGN KEY (document_id) REFERENCES documents(document_id) ON DELETE CASCADE,
+					CONSTRAINT uq_document_schema_version UNIQUE (document_id, version)
+				)
+			`
+			_, err := tx.ExecContext(ctx, query)
+			return err
+		},
+	}
+}
+
+// createJobsTable creates the jobs table.
+// This table queues the detection, redaction and export jobs running in the background. A
+// pending job starts once run_after has passed, which a failed attempt moves into the future
+// to back off; lease_until tells other servers when a running job whose server crashed may
+// be taken over. Payloads and results are encrypted with the data-encryption key of the user;
+// the payload is cleared once the job finished, and jobs are deleted with their document.
+//
+// Returns:
+//   - Migration: A migration that creates the jobs table
+func createJobsTable() Migration {
+	return Migration{
+		Name:        "create_jobs_table",
+		Description: "Creates the jobs table",
+		TableName:   constants.TableJobs,
+		RunSQL: func(ctx context.Context, tx *sql.Tx) error {
+			query := `
+				CREATE TABLE IF NOT EXISTS jobs (
+					job_id BIGINT PRIMARY KEY GENERATED ALWAYS AS IDENTITY,
+					user_id BIGINT NOT NULL,
+					document_id BIGINT NOT NULL,
+					kind VARCHAR(20) NOT NULL CHECK (kind IN ('detection', 'redaction', 'export')),
+					status VARCHAR(20) NOT NULL CHECK (status IN ('pending', 'running', 'completed', 'failed')),
+					payload TEXT NOT NULL DEFAULT '',
+					execution_context JSONB NOT NULL DEFAULT '{}',
+					attempts INT NOT NULL DEFAULT 0,
+					max_attempts INT NOT NULL,
+					run_after TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+					lease_until TIMESTAMP,
+					last_error TEXT NOT NULL DEFAULT '',
+					result_type VARCHAR(100) NOT NULL DEFAULT '',
+					result TEXT,
+					created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+					updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+					completed_at TIMESTAMP,
+					CONSTRAINT fk_job_user FOREIGN KEY (user_id) REFERENCES users(user_id) ON DELETE CASCADE,
+					CONSTRAINT fk_job_document FOREIGN KEY (document_id) REFERENCES documents(document_id) ON DELETE CASCADE
+				)
+			`
+			_, err := tx.ExecContext(ctx, query)
+			if err != nil {
+				return err
+			}
+
+			indexQuery := `CREATE INDEX IF NOT EXISTS idx_job_user ON jobs(user_id, created_at DESC)`
+			_, err = tx.ExecContext(ctx, indexQuery)
+			if err != nil {
+				return err
+			}
+
+			// Workers look for the unfinished jobs only
+			indexQuery = `CREATE INDEX IF NOT EXISTS idx_job_queue ON jobs(run_after) WHERE status IN ('pending', 'running')`
+			_, err = tx.ExecContext(ctx, indexQuery)
+			return err
+		},
+	}
+}
+
+// createDomainEventsTable creates the domain_events table.
+// This append-only table records domain changes, such as usage counted towards the monthly
+// rollups and detections counted towards users' rules, in the order they happened. The
+// derived tables are updated in the same transaction as their events are recorded, and can be
+// rebuilt by replaying the events once a bug in their aggregation is fixed. The counts that
+// existed before the event log are recorded as one event per row, so a rebuild keeps them.
+//
+// Returns:
+//   - Migration: A migration that creates the domain_events table
+func createDomainEventsTable() Migration {
+	return Migration{
+		Name:        "create_domain_events_table",
+		Description: "Creates the domain_events table",
+		TableName:   constants.TableDomainEvents,
+		RunSQL: func(ctx context.Context, tx *sql.Tx) error {
+			query := `
+				CREATE TABLE IF NOT EXISTS domain_events (
+					event_id BIGINT PRIMARY KEY GENERATED ALWAYS AS IDENTITY,
+					event_type VARCHAR(50) NOT NULL,
+					user_id BIGINT NOT NULL,
+					payload JSONB NOT NULL,
+					occurred_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+					CONSTRAINT fk_domain_event_user FOREIGN KEY (user_id) REFERENCES users(user_id) ON DELETE CASCADE
+				)
+			`
+			_, err := tx.ExecContext(ctx, query)
+			if err != nil {
+				return err
+			}
+
+			// Projections replay the events of their types in order
+			indexQuery := `CREATE INDEX IF NOT EXISTS idx_domain_event_type ON domain_events(event_type, event_id)`
+			_, err = tx.ExecContext(ctx, indexQuery)
+			if err != nil {
+				return err
+			}
+
+			// Record the usage counted so far
+			seedQuery := `
+				INSERT INTO domain_events (event_type, user_id, payload, occurred_at)
+				SELECT 'usage_counted', user_id,
+				       jsonb_build_object(
+				           'month', to_char(usage_month, 'YYYY-MM-DD"T00:00:00Z"'),
+				           'page_count', page_count,
+				           'api_call_count', api_call_count),
+				       COALESCE(updated_at, CURRENT_TIMESTAMP)
+				FROM tenant_usage
+				WHERE page_count > 0 OR api_call_count > 0
+				ORDER BY usage_month, user_id
+			`
+			_, err = tx.ExecContext(ctx, seedQuery)
+			if err != nil {
+				return err
+			}
+
+			// And the rule hits counted so far
+			seedQuery = `
+				INSERT INTO domain_events (event_type, user_id, payload, occurred_at)
+				SELECT 'rule_hits_recorded', user_id,
+				       jsonb_build_object('hits', jsonb_agg(jsonb_build_object(
+				           'rule_type', rule_type,
+				           'rule_text', rule_text,
+				           'hit_count', hit_count,
+				           'last_hit_at', to_char(last_hit_at, 'YYYY-MM-DD"T"HH24:MI:SS.US"Z"'),
+				           'tracked_since', to_char(COALESCE(tracked_since, CURRENT_TIMESTAMP), 'YYYY-MM-DD"T"HH24:MI:SS.US"Z"')
+				       ) ORDER BY tracked_since)),
+				       CURRENT_TIMESTAMP
+				FROM rule_hits
+				GROUP BY user_id
+				ORDER BY user_id
+			`
+			_, err = tx.ExecContext(ctx, seedQuery)
+			return err
+		},
+	}
+}
+
+// createWebhooksTable creates the webhooks table.
+// This table stores the URLs users receive account and document events at, with the events
+// each URL subscribes to. The secret deliveries are signed with is encrypted with the
+// tenant's data-encryption key.
+//
+// Returns:
+//   - Migration: A migration that creates the webhooks table
+func createWebhooksTable() Migration {
+	return Migration{
+		Name:        "create_webhooks_table",
+		Description: "Creates the webhooks table",
+		TableName:   constants.TableWebhooks,
+		RunSQL: func(ctx context.Context, tx *sql.Tx) error {
+			query := `
+				CREATE TABLE IF NOT EXISTS webhooks (
+					webhook_id BIGINT PRIMARY KEY GENERATED ALWAYS AS IDENTITY,
+					user_id BIGINT NOT NULL,
+					url VARCHAR(2048) NOT NULL,
+					secret TEXT NOT NULL,
+					events TEXT[] NOT NULL,
+					active BOOLEAN NOT NULL DEFAULT TRUE,
+					created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+					updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+					CONSTRAINT fk_user_webhook FOREIGN KEY (user_id) REFERENCES users(user_id) ON DELETE CASCADE
+				)
+			`
+			_, err := tx.ExecContext(ctx, query)
+			if err != nil {
+				return err
+			}
+
+			indexQuery := `CREATE INDEX IF NOT EXISTS idx_webhook_user ON webhooks(user_id)`
+			_, err = tx.ExecContext(ctx, indexQuery)
+			return err
+		},
+	}
+}
+
+// createWebhookDeliveriesTable creates the webhook_deliveries table.
+// This table queues the deliveries of events to webhooks. A delivery is attempted until the
+// webhook accepts it or it used up its attempts, when it is dead-lettered and kept for
+// redelivery.
+//
+// Returns:
+//   - Migration: A migration that creates the webhook_deliveries table
+func createWebhookDeliveriesTable() Migration {
+	return Migration{
+		Name:        "create_webhook_deliveries_table",
+		Description: "Creates the webhook_deliveries table",
+		TableName:   constants.TableWebhookDeliveries,
+		RunSQL: func(ctx context.Context, tx *sql.Tx) error {
+			query := `
+				CREATE TABLE IF NOT EXISTS webhook_deliveries (
+					delivery_id BIGINT PRIMARY KEY GENERATED ALWAYS AS IDENTITY,
+					webhook_id BIGINT NOT NULL,
+					event_type VARCHAR(50) NOT NULL,
+					payload JSONB NOT NULL,
+					status VARCHAR(20) NOT NULL CHECK (status IN ('pending', 'delivered', 'dead')),
+					attempts INT NOT NULL DEFAULT 0,
+					next_attempt_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+					last_status_code INT NOT NULL DEFAULT 0,
+					last_error TEXT NOT NULL DEFAULT '',
+					created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+					updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+					delivered_at TIMESTAMP,
+					CONSTRAINT fk_webhook_delivery FOREIGN KEY (webhook_id) REFERENCES webhooks(webhook_id) ON DELETE CASCADE
+				)
+			`
+			_, err := tx.ExecContext(ctx, query)
+			if err != nil {
+				return err
+			}
+
+			indexQuery := `CREATE INDEX IF NOT EXISTS idx_webhook_delivery_webhook ON webhook_deliveries(webhook_id, created_at DESC)`
+			_, err = tx.ExecContext(ctx, indexQuery)
+			if err != nil {
+				return err
+			}
+
+			// Servers look for the pending deliveries that are due only
+			indexQuery = `CREATE INDEX IF NOT EXISTS idx_webhook_delivery_queue ON webhook_deliveries(next_attempt_at) WHERE status = 'pending'`
+			_, err = tx.ExecContext(ctx, indexQuery)
+			return err
+		},
+	}
+}
+
+// createEntityTypesTable creates the entity_types table.
+// This table holds the entity-type taxonomy: the entity types that can be detected, such as
+// PERSON or EMAIL_ADDRESS, and the detection method detecting each. Entity types and methods
+// are identified by name, so the taxonomy can be promoted between environments.
+//
+// Returns:
+//   - Migration: A migration that creates the entity_types table
+func createEntityTypesTable() Migration {
+	return Migration{
+		Name:        "create_entity_types_table",
+		Description: "Creates the entity_types table",
+		TableName:   constants.TableEntityTypes,
+		RunSQL: func(ctx context.Context, tx *sql.Tx) error {
+			query := `
+				CREATE TABLE IF NOT EXISTS entity_types (
+					entity_type_id BIGINT PRIMARY KEY GENERATED ALWAYS AS IDENTITY,
+					entity_type VARCHAR(100) NOT NULL UNIQUE,
+					method_id BIGINT NOT NULL,
+					description VARCHAR(255) NOT NULL DEFAULT '',
+					CONSTRAINT fk_entity_type_method FOREIGN KEY (method_id) REFERENCES detection_methods(method_id)
+				)
+			`
+			_, err := tx.ExecContext(ctx, query)
+			return err
+		},
+	}
+}
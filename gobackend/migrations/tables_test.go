@@ -248,6 +248,8 @@ func TestCreateDetectedEntitiesTable(t *testing.T) {
 		WillReturnResult(sqlmock.NewResult(0, 0))
 	mock.ExpectExec("CREATE INDEX IF NOT EXISTS idx_entity_name ON detected_entities").
 		WillReturnResult(sqlmock.NewResult(0, 0))
+	mock.ExpectExec("CREATE INDEX IF NOT EXISTS idx_detected_entities_document_status ON detected_entities").
+		WillReturnResult(sqlmock.NewResult(0, 0))
 
 	ctx := context.Background()
 	err := migration.RunSQL(ctx, tx)
@@ -430,6 +432,8 @@ func TestCreateSessionsTable(t *testing.T) {
 		WillReturnResult(sqlmock.NewResult(0, 0))
 	mock.ExpectExec("CREATE INDEX IF NOT EXISTS idx_expires_at ON sessions").
 		WillReturnResult(sqlmock.NewResult(0, 0))
+	mock.ExpectExec("CREATE INDEX IF NOT EXISTS idx_sessions_last_activity_at ON sessions").
+		WillReturnResult(sqlmock.NewResult(0, 0))
 
 	ctx := context.Background()
 	err := migration.RunSQL(ctx, tx)
@@ -508,3 +512,941 @@ func TestCreateAPIKeysTable(t *testing.T) {
 	err = migration.RunSQL(ctx, tx)
 	assert.Error(t, err)
 }
+
+// TestCreateSettingsRevisionsTable tests the createSettingsRevisionsTable function
+func TestCreateSettingsRevisionsTable(t *testing.T) {
+	_, tx, mock, cleanup := createMockDBAndTx(t)
+	defer cleanup()
+
+	migration := createSettingsRevisionsTable()
+
+	assert.Equal(t, "create_settings_revisions_table", migration.Name)
+	assert.Equal(t, "Creates the settings_revisions table", migration.Description)
+	assert.Equal(t, "settings_revisions", migration.TableName)
+	assert.NotNil(t, migration.RunSQL)
+
+	// Test successful execution
+	mock.ExpectExec("CREATE TABLE IF NOT EXISTS settings_revisions").
+		WillReturnResult(sqlmock.NewResult(0, 0))
+	mock.ExpectExec("CREATE INDEX IF NOT EXISTS idx_user_revision ON settings_revisions").
+		WillReturnResult(sqlmock.NewResult(0, 0))
+
+	ctx := context.Background()
+	err := migration.RunSQL(ctx, tx)
+
+	assert.NoError(t, err)
+	assert.NoError(t, mock.ExpectationsWereMet())
+
+	// Test table creation failure
+	_, tx, mock, cleanup = createMockDBAndTx(t)
+	defer cleanup()
+
+	mock.ExpectExec("CREATE TABLE IF NOT EXISTS settings_revisions").
+		WillReturnError(errors.New("table creation error"))
+
+	err = migration.RunSQL(ctx, tx)
+	assert.Error(t, err)
+}
+
+// TestCreateTenantUsageTable tests the createTenantUsageTable function
+func TestCreateTenantUsageTable(t *testing.T) {
+	_, tx, mock, cleanup := createMockDBAndTx(t)
+	defer cleanup()
+
+	migration := createTenantUsageTable()
+
+	assert.Equal(t, "create_tenant_usage_table", migration.Name)
+	assert.Equal(t, "Creates the tenant_usage table", migration.Description)
+	assert.Equal(t, "tenant_usage", migration.TableName)
+	assert.NotNil(t, migration.RunSQL)
+
+	// Test successful execution
+	mock.ExpectExec("CREATE TABLE IF NOT EXISTS tenant_usage").
+		WillReturnResult(sqlmock.NewResult(0, 0))
+	mock.ExpectExec("CREATE INDEX IF NOT EXISTS idx_usage_month ON tenant_usage").
+		WillReturnResult(sqlmock.NewResult(0, 0))
+
+	ctx := context.Background()
+	err := migration.RunSQL(ctx, tx)
+
+	assert.NoError(t, err)
+	assert.NoError(t, mock.ExpectationsWereMet())
+
+	// Test table creation failure
+	_, tx, mock, cleanup = createMockDBAndTx(t)
+	defer cleanup()
+
+	mock.ExpectExec("CREATE TABLE IF NOT EXISTS tenant_usage").
+		WillReturnError(errors.New("table creation error"))
+
+	err = migration.RunSQL(ctx, tx)
+	assert.Error(t, err)
+}
+
+// TestCreateAdminActionsTable tests the createAdminActionsTable function
+func TestCreateAdminActionsTable(t *testing.T) {
+	_, tx, mock, cleanup := createMockDBAndTx(t)
+	defer cleanup()
+
+	migration := createAdminActionsTable()
+
+	assert.Equal(t, "create_admin_actions_table", migration.Name)
+	assert.Equal(t, "Creates the admin_actions table", migration.Description)
+	assert.Equal(t, "admin_actions", migration.TableName)
+	assert.NotNil(t, migration.RunSQL)
+
+	// Test successful execution
+	mock.ExpectExec("CREATE TABLE IF NOT EXISTS admin_actions").
+		WillReturnResult(sqlmock.NewResult(0, 0))
+	mock.ExpectExec("CREATE INDEX IF NOT EXISTS idx_admin_action_status ON admin_actions").
+		WillReturnResult(sqlmock.NewResult(0, 0))
+
+	ctx := context.Background()
+	err := migration.RunSQL(ctx, tx)
+
+	assert.NoError(t, err)
+	assert.NoError(t, mock.ExpectationsWereMet())
+
+	// Test table creation failure
+	_, tx, mock, cleanup = createMockDBAndTx(t)
+	defer cleanup()
+
+	mock.ExpectExec("CREATE TABLE IF NOT EXISTS admin_actions").
+		WillReturnError(errors.New("table creation error"))
+
+	err = migration.RunSQL(ctx, tx)
+	assert.Error(t, err)
+}
+
+// TestCreateAccountHoldsTable tests the createAccountHoldsTable function
+func TestCreateAccountHoldsTable(t *testing.T) {
+	_, tx, mock, cleanup := createMockDBAndTx(t)
+	defer cleanup()
+
+	migration := createAccountHoldsTable()
+
+	assert.Equal(t, "create_account_holds_table", migration.Name)
+	assert.Equal(t, "Creates the account_holds table", migration.Description)
+	assert.Equal(t, "account_holds", migration.TableName)
+	assert.NotNil(t, migration.RunSQL)
+
+	// Test successful execution
+	mock.ExpectExec("CREATE TABLE IF NOT EXISTS account_holds").
+		WillReturnResult(sqlmock.NewResult(0, 0))
+	mock.ExpectExec("CREATE UNIQUE INDEX IF NOT EXISTS idx_account_hold_token ON account_holds").
+		WillReturnResult(sqlmock.NewResult(0, 0))
+
+	ctx := context.Background()
+	err := migration.RunSQL(ctx, tx)
+
+	assert.NoError(t, err)
+	assert.NoError(t, mock.ExpectationsWereMet())
+
+	// Test table creation failure
+	_, tx, mock, cleanup = createMockDBAndTx(t)
+	defer cleanup()
+
+	mock.ExpectExec("CREATE TABLE IF NOT EXISTS account_holds").
+		WillReturnError(errors.New("table creation error"))
+
+	err = migration.RunSQL(ctx, tx)
+	assert.Error(t, err)
+}
+
+// TestCreateTenantKeysTable tests the createTenantKeysTable function
+func TestCreateTenantKeysTable(t *testing.T) {
+	_, tx, mock, cleanup := createMockDBAndTx(t)
+	defer cleanup()
+
+	migration := createTenantKeysTable()
+
+	assert.Equal(t, "create_tenant_keys_table", migration.Name)
+	assert.Equal(t, "Creates the tenant_keys table", migration.Description)
+	assert.Equal(t, "tenant_keys", migration.TableName)
+	assert.NotNil(t, migration.RunSQL)
+
+	// Test successful execution
+	mock.ExpectExec("CREATE TABLE IF NOT EXISTS tenant_keys").
+		WillReturnResult(sqlmock.NewResult(0, 0))
+
+	ctx := context.Background()
+	err := migration.RunSQL(ctx, tx)
+
+	assert.NoError(t, err)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestCreateReportSubscriptionsTable(t *testing.T) {
+	_, tx, mock, cleanup := createMockDBAndTx(t)
+	defer cleanup()
+
+	migration := createReportSubscriptionsTable()
+
+	assert.Equal(t, "create_report_subscriptions_table", migration.Name)
+	assert.Equal(t, "Creates the report_subscriptions table", migration.Description)
+	assert.Equal(t, "report_subscriptions", migration.TableName)
+	assert.NotNil(t, migration.RunSQL)
+
+	// Test successful execution
+	mock.ExpectExec("CREATE TABLE IF NOT EXISTS report_subscriptions").
+		WillReturnResult(sqlmock.NewResult(0, 0))
+	mock.ExpectExec("CREATE INDEX IF NOT EXISTS idx_report_subscription_next_run").
+		WillReturnResult(sqlmock.NewResult(0, 0))
+
+	ctx := context.Background()
+	err := migration.RunSQL(ctx, tx)
+
+	assert.NoError(t, err)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestCreateClassificationRulesTable(t *testing.T) {
+	_, tx, mock, cleanup := createMockDBAndTx(t)
+	defer cleanup()
+
+	migration := createClassificationRulesTable()
+
+	assert.Equal(t, "create_classification_rules_table", migration.Name)
+	assert.Equal(t, "Creates the classification_rules table", migration.Description)
+	assert.Equal(t, "classification_rules", migration.TableName)
+	assert.NotNil(t, migration.RunSQL)
+
+	// Test successful execution
+	mock.ExpectExec("CREATE TABLE IF NOT EXISTS classification_rules").
+		WillReturnResult(sqlmock.NewResult(0, 0))
+	mock.ExpectExec("CREATE INDEX IF NOT EXISTS idx_classification_rule_setting").
+		WillReturnResult(sqlmock.NewResult(0, 0))
+
+	ctx := context.Background()
+	err := migration.RunSQL(ctx, tx)
+
+	assert.NoError(t, err)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestCreateBenchmarkConsentsTable(t *testing.T) {
+	_, tx, mock, cleanup := createMockDBAndTx(t)
+	defer cleanup()
+
+	migration := createBenchmarkConsentsTable()
+
+	assert.Equal(t, "create_benchmark_consents_table", migration.Name)
+	assert.Equal(t, "Creates the benchmark_consents table", migration.Description)
+	assert.Equal(t, "benchmark_consents", migration.TableName)
+	assert.NotNil(t, migration.RunSQL)
+
+	// Test successful execution
+	mock.ExpectExec("CREATE TABLE IF NOT EXISTS benchmark_consents").
+		WillReturnResult(sqlmock.NewResult(0, 0))
+
+	ctx := context.Background()
+	err := migration.RunSQL(ctx, tx)
+
+	assert.NoError(t, err)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestCreateStatusIncidentsTable(t *testing.T) {
+	_, tx, mock, cleanup := createMockDBAndTx(t)
+	defer cleanup()
+
+	migration := createStatusIncidentsTable()
+
+	assert.Equal(t, "create_status_incidents_table", migration.Name)
+	assert.Equal(t, "Creates the status_incidents table", migration.Description)
+	assert.Equal(t, "status_incidents", migration.TableName)
+	assert.NotNil(t, migration.RunSQL)
+
+	// Test successful execution
+	mock.ExpectExec("CREATE TABLE IF NOT EXISTS status_incidents").
+		WillReturnResult(sqlmock.NewResult(0, 0))
+	mock.ExpectExec("CREATE INDEX IF NOT EXISTS idx_status_incident_resolved").
+		WillReturnResult(sqlmock.NewResult(0, 0))
+
+	ctx := context.Background()
+	err := migration.RunSQL(ctx, tx)
+
+	assert.NoError(t, err)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestCreateComponentUptimeTable(t *testing.T) {
+	_, tx, mock, cleanup := createMockDBAndTx(t)
+	defer cleanup()
+
+	migration := createComponentUptimeTable()
+
+	assert.Equal(t, "create_component_uptime_table", migration.Name)
+	assert.Equal(t, "Creates the component_uptime table", migration.Description)
+	assert.Equal(t, "component_uptime", migration.TableName)
+	assert.NotNil(t, migration.RunSQL)
+
+	// Test successful execution
+	mock.ExpectExec("CREATE TABLE IF NOT EXISTS component_uptime").
+		WillReturnResult(sqlmock.NewResult(0, 0))
+
+	ctx := context.Background()
+	err := migration.RunSQL(ctx, tx)
+
+	assert.NoError(t, err)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestCreateAnnouncementsTable(t *testing.T) {
+	_, tx, mock, cleanup := createMockDBAndTx(t)
+	defer cleanup()
+
+	migration := createAnnouncementsTable()
+
+	assert.Equal(t, "create_announcements_table", migration.Name)
+	assert.Equal(t, "Creates the announcements table", migration.Description)
+	assert.Equal(t, "announcements", migration.TableName)
+	assert.NotNil(t, migration.RunSQL)
+
+	// Test successful execution
+	mock.ExpectExec("CREATE TABLE IF NOT EXISTS announcements").
+		WillReturnResult(sqlmock.NewResult(0, 0))
+	mock.ExpectExec("CREATE INDEX IF NOT EXISTS idx_announcement_window").
+		WillReturnResult(sqlmock.NewResult(0, 0))
+
+	ctx := context.Background()
+	err := migration.RunSQL(ctx, tx)
+
+	assert.NoError(t, err)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestCreateAnnouncementReadsTable(t *testing.T) {
+	_, tx, mock, cleanup := createMockDBAndTx(t)
+	defer cleanup()
+
+	migration := createAnnouncementReadsTable()
+
+	assert.Equal(t, "create_announcement_reads_table", migration.Name)
+	assert.Equal(t, "Creates the announcement_reads table", migration.Description)
+	assert.Equal(t, "announcement_reads", migration.TableName)
+	assert.NotNil(t, migration.RunSQL)
+
+	// Test successful execution
+	mock.ExpectExec("CREATE TABLE IF NOT EXISTS announcement_reads").
+		WillReturnResult(sqlmock.NewResult(0, 0))
+
+	ctx := context.Background()
+	err := migration.RunSQL(ctx, tx)
+
+	assert.NoError(t, err)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestCreateDriveConnectionsTable(t *testing.T) {
+	_, tx, mock, cleanup := createMockDBAndTx(t)
+	defer cleanup()
+
+	migration := createDriveConnectionsTable()
+
+	assert.Equal(t, "create_drive_connections_table", migration.Name)
+	assert.Equal(t, "Creates the drive_connections table", migration.Description)
+	assert.Equal(t, "drive_connections", migration.TableName)
+	assert.NotNil(t, migration.RunSQL)
+
+	// Test successful execution
+	mock.ExpectExec("CREATE TABLE IF NOT EXISTS drive_connections").
+		WillReturnResult(sqlmock.NewResult(0, 0))
+
+	ctx := context.Background()
+	err := migration.RunSQL(ctx, tx)
+
+	assert.NoError(t, err)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestCreateExportDestinationsTable(t *testing.T) {
+	_, tx, mock, cleanup := createMockDBAndTx(t)
+	defer cleanup()
+
+	migration := createExportDestinationsTable()
+
+	assert.Equal(t, "create_export_destinations_table", migration.Name)
+	assert.Equal(t, "Creates the export_destinations table", migration.Description)
+	assert.Equal(t, "export_destinations", migration.TableName)
+	assert.NotNil(t, migration.RunSQL)
+
+	// Test successful execution
+	mock.ExpectExec("CREATE TABLE IF NOT EXISTS export_destinations").
+		WillReturnResult(sqlmock.NewResult(0, 0))
+
+	ctx := context.Background()
+	err := migration.RunSQL(ctx, tx)
+
+	assert.NoError(t, err)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestCreateExportDeliveriesTable(t *testing.T) {
+	_, tx, mock, cleanup := createMockDBAndTx(t)
+	defer cleanup()
+
+	migration := createExportDeliveriesTable()
+
+	assert.Equal(t, "create_export_deliveries_table", migration.Name)
+	assert.Equal(t, "Creates the export_deliveries table", migration.Description)
+	assert.Equal(t, "export_deliveries", migration.TableName)
+	assert.NotNil(t, migration.RunSQL)
+
+	// Test successful execution
+	mock.ExpectExec("CREATE TABLE IF NOT EXISTS export_deliveries").
+		WillReturnResult(sqlmock.NewResult(0, 0))
+	mock.ExpectExec("CREATE INDEX IF NOT EXISTS idx_export_delivery_document").
+		WillReturnResult(sqlmock.NewResult(0, 0))
+	mock.ExpectExec("CREATE INDEX IF NOT EXISTS idx_export_delivery_failed").
+		WillReturnResult(sqlmock.NewResult(0, 0))
+
+	ctx := context.Background()
+	err := migration.RunSQL(ctx, tx)
+
+	assert.NoError(t, err)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestCreateRuleHitsTable(t *testing.T) {
+	_, tx, mock, cleanup := createMockDBAndTx(t)
+	defer cleanup()
+
+	migration := createRuleHitsTable()
+
+	assert.Equal(t, "create_rule_hits_table", migration.Name)
+	assert.Equal(t, "Creates the rule_hits table", migration.Description)
+	assert.Equal(t, "rule_hits", migration.TableName)
+	assert.NotNil(t, migration.RunSQL)
+
+	// Test successful execution
+	mock.ExpectExec("CREATE TABLE IF NOT EXISTS rule_hits").
+		WillReturnResult(sqlmock.NewResult(0, 0))
+
+	ctx := context.Background()
+	err := migration.RunSQL(ctx, tx)
+
+	assert.NoError(t, err)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestCreateProcessingAuditsTable(t *testing.T) {
+	_, tx, mock, cleanup := createMockDBAndTx(t)
+	defer cleanup()
+
+	migration := createProcessingAuditsTable()
+
+	assert.Equal(t, "create_processing_audits_table", migration.Name)
+	assert.Equal(t, "Creates the processing_audits table", migration.Description)
+	assert.Equal(t, "processing_audits", migration.TableName)
+	assert.NotNil(t, migration.RunSQL)
+
+	// Test successful execution
+	mock.ExpectExec("CREATE TABLE IF NOT EXISTS processing_audits").
+		WillReturnResult(sqlmock.NewResult(0, 0))
+	mock.ExpectExec("CREATE INDEX IF NOT EXISTS idx_processing_audit_user").
+		WillReturnResult(sqlmock.NewResult(0, 0))
+
+	ctx := context.Background()
+	err := migration.RunSQL(ctx, tx)
+
+	assert.NoError(t, err)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestCreateAPIKeyRotationCampaignsTable(t *testing.T) {
+	_, tx, mock, cleanup := createMockDBAndTx(t)
+	defer cleanup()
+
+	migration := createAPIKeyRotationCampaignsTable()
+
+	assert.Equal(t, "create_api_key_rotation_campaigns_table", migration.Name)
+	assert.Equal(t, "Creates the api_key_rotation_campaigns table", migration.Description)
+	assert.Equal(t, "api_key_rotation_campaigns", migration.TableName)
+	assert.NotNil(t, migration.RunSQL)
+
+	// Test successful execution
+	mock.ExpectExec("CREATE TABLE IF NOT EXISTS api_key_rotation_campaigns").
+		WillReturnResult(sqlmock.NewResult(0, 0))
+
+	ctx := context.Background()
+	err := migration.RunSQL(ctx, tx)
+
+	assert.NoError(t, err)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestCreateAPIKeyPolicyTable(t *testing.T) {
+	_, tx, mock, cleanup := createMockDBAndTx(t)
+	defer cleanup()
+
+	migration := createAPIKeyPolicyTable()
+
+	assert.Equal(t, "create_api_key_policy_table", migration.Name)
+	assert.Equal(t, "Creates the api_key_policy table", migration.Description)
+	assert.Equal(t, "api_key_policy", migration.TableName)
+	assert.NotNil(t, migration.RunSQL)
+
+	// Test successful execution
+	mock.ExpectExec("CREATE TABLE IF NOT EXISTS api_key_policy").
+		WillReturnResult(sqlmock.NewResult(0, 0))
+
+	ctx := context.Background()
+	err := migration.RunSQL(ctx, tx)
+
+	assert.NoError(t, err)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestCreateDocumentArchivesTable(t *testing.T) {
+	_, tx, mock, cleanup := createMockDBAndTx(t)
+	defer cleanup()
+
+	migration := createDocumentArchivesTable()
+
+	assert.Equal(t, "create_document_archives_table", migration.Name)
+	assert.Equal(t, "Creates the document_archives table", migration.Description)
+	assert.Equal(t, "document_archives", migration.TableName)
+	assert.NotNil(t, migration.RunSQL)
+
+	// Test successful execution
+	mock.ExpectExec("CREATE TABLE IF NOT EXISTS document_archives").
+		WillReturnResult(sqlmock.NewResult(0, 0))
+
+	ctx := context.Background()
+	err := migration.RunSQL(ctx, tx)
+
+	assert.NoError(t, err)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestCreateUserDataExportsTable(t *testing.T) {
+	_, tx, mock, cleanup := createMockDBAndTx(t)
+	defer cleanup()
+
+	migration := createUserDataExportsTable()
+
+	assert.Equal(t, "create_user_data_exports_table", migration.Name)
+	assert.Equal(t, "Creates the user_data_exports table", migration.Description)
+	assert.Equal(t, "user_data_exports", migration.TableName)
+	assert.NotNil(t, migration.RunSQL)
+
+	// Test successful execution
+	mock.ExpectExec("CREATE TABLE IF NOT EXISTS user_data_exports").
+		WillReturnResult(sqlmock.NewResult(0, 0))
+	mock.ExpectExec("CREATE INDEX IF NOT EXISTS idx_user_data_export_user").
+		WillReturnResult(sqlmock.NewResult(0, 0))
+	mock.ExpectExec("CREATE UNIQUE INDEX IF NOT EXISTS idx_user_data_export_active").
+		WillReturnResult(sqlmock.NewResult(0, 0))
+
+	ctx := context.Background()
+	err := migration.RunSQL(ctx, tx)
+
+	assert.NoError(t, err)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestCreateUserDataExportChunksTable(t *testing.T) {
+	_, tx, mock, cleanup := createMockDBAndTx(t)
+	defer cleanup()
+
+	migration := createUserDataExportChunksTable()
+
+	assert.Equal(t, "create_user_data_export_chunks_table", migration.Name)
+	assert.Equal(t, "Creates the user_data_export_chunks table", migration.Description)
+	assert.Equal(t, "user_data_export_chunks", migration.TableName)
+	assert.NotNil(t, migration.RunSQL)
+
+	// Test successful execution
+	mock.ExpectExec("CREATE TABLE IF NOT EXISTS user_data_export_chunks").
+		WillReturnResult(sqlmock.NewResult(0, 0))
+
+	ctx := context.Background()
+	err := migration.RunSQL(ctx, tx)
+
+	assert.NoError(t, err)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestCreateRegistrationDomainsTable(t *testing.T) {
+	_, tx, mock, cleanup := createMockDBAndTx(t)
+	defer cleanup()
+
+	migration := createRegistrationDomainsTable()
+
+	assert.Equal(t, "create_registration_domains_table", migration.Name)
+	assert.Equal(t, "Creates the registration_domains table", migration.Description)
+	assert.Equal(t, "registration_domains", migration.TableName)
+	assert.NotNil(t, migration.RunSQL)
+
+	// Test successful execution
+	mock.ExpectExec("CREATE TABLE IF NOT EXISTS registration_domains").
+		WillReturnResult(sqlmock.NewResult(0, 0))
+
+	ctx := context.Background()
+	err := migration.RunSQL(ctx, tx)
+
+	assert.NoError(t, err)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestCreateAccountErasuresTable(t *testing.T) {
+	_, tx, mock, cleanup := createMockDBAndTx(t)
+	defer cleanup()
+
+	migration := createAccountErasuresTable()
+
+	assert.Equal(t, "create_account_erasures_table", migration.Name)
+	assert.Equal(t, "Creates the account_erasures table", migration.Description)
+	assert.Equal(t, "account_erasures", migration.TableName)
+	assert.NotNil(t, migration.RunSQL)
+
+	// Test successful execution
+	mock.ExpectExec("CREATE TABLE IF NOT EXISTS account_erasures").
+		WillReturnResult(sqlmock.NewResult(0, 0))
+	mock.ExpectExec("CREATE UNIQUE INDEX IF NOT EXISTS idx_account_erasure_active").
+		WillReturnResult(sqlmock.NewResult(0, 0))
+	mock.ExpectExec("CREATE INDEX IF NOT EXISTS idx_account_erasure_due").
+		WillReturnResult(sqlmock.NewResult(0, 0))
+
+	ctx := context.Background()
+	err := migration.RunSQL(ctx, tx)
+
+	assert.NoError(t, err)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestCreateDocumentContentsTable(t *testing.T) {
+	_, tx, mock, cleanup := createMockDBAndTx(t)
+	defer cleanup()
+
+	migration := createDocumentContentsTable()
+
+	assert.Equal(t, "create_document_contents_table", migration.Name)
+	assert.Equal(t, "Creates the document_contents table", migration.Description)
+	assert.Equal(t, "document_contents", migration.TableName)
+	assert.NotNil(t, migration.RunSQL)
+
+	// Test successful execution
+	mock.ExpectExec("CREATE TABLE IF NOT EXISTS document_contents").
+		WillReturnResult(sqlmock.NewResult(0, 0))
+
+	ctx := context.Background()
+	err := migration.RunSQL(ctx, tx)
+
+	assert.NoError(t, err)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestCreateDocumentSharesTable(t *testing.T) {
+	_, tx, mock, cleanup := createMockDBAndTx(t)
+	defer cleanup()
+
+	migration := createDocumentSharesTable()
+
+	assert.Equal(t, "create_document_shares_table", migration.Name)
+	assert.Equal(t, "Creates the document_shares table", migration.Description)
+	assert.Equal(t, "document_shares", migration.TableName)
+	assert.NotNil(t, migration.RunSQL)
+
+	// Test successful execution
+	mock.ExpectExec("CREATE TABLE IF NOT EXISTS document_shares").
+		WillReturnResult(sqlmock.NewResult(0, 0))
+	mock.ExpectExec("CREATE INDEX IF NOT EXISTS idx_document_share_user").
+		WillReturnResult(sqlmock.NewResult(0, 0))
+
+	ctx := context.Background()
+	err := migration.RunSQL(ctx, tx)
+
+	assert.NoError(t, err)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestCreateEntityHashesTable(t *testing.T) {
+	_, tx, mock, cleanup := createMockDBAndTx(t)
+	defer cleanup()
+
+	migration := createEntityHashesTable()
+
+	assert.Equal(t, "create_entity_hashes_table", migration.Name)
+	assert.Equal(t, "Creates the entity_hashes table", migration.Description)
+	assert.Equal(t, "entity_hashes", migration.TableName)
+	assert.NotNil(t, migration.RunSQL)
+
+	// Test successful execution
+	mock.ExpectExec("CREATE TABLE IF NOT EXISTS entity_hashes").
+		WillReturnResult(sqlmock.NewResult(0, 0))
+	mock.ExpectExec("CREATE INDEX IF NOT EXISTS idx_entity_hash_user_value").
+		WillReturnResult(sqlmock.NewResult(0, 0))
+
+	ctx := context.Background()
+	err := migration.RunSQL(ctx, tx)
+
+	assert.NoError(t, err)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestCreateLeakAlertsTable(t *testing.T) {
+	_, tx, mock, cleanup := createMockDBAndTx(t)
+	defer cleanup()
+
+	migration := createLeakAlertsTable()
+
+	assert.Equal(t, "create_leak_alerts_table", migration.Name)
+	assert.Equal(t, "Creates the leak_alerts table", migration.Description)
+	assert.Equal(t, "leak_alerts", migration.TableName)
+	assert.NotNil(t, migration.RunSQL)
+
+	// Test successful execution
+	mock.ExpectExec("CREATE TABLE IF NOT EXISTS leak_alerts").
+		WillReturnResult(sqlmock.NewResult(0, 0))
+
+	ctx := context.Background()
+	err := migration.RunSQL(ctx, tx)
+
+	assert.NoError(t, err)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestCreateDocumentTransfersTable(t *testing.T) {
+	_, tx, mock, cleanup := createMockDBAndTx(t)
+	defer cleanup()
+
+	migration := createDocumentTransfersTable()
+
+	assert.Equal(t, "create_document_transfers_table", migration.Name)
+	assert.Equal(t, "Creates the document_transfers table", migration.Description)
+	assert.Equal(t, "document_transfers", migration.TableName)
+	assert.NotNil(t, migration.RunSQL)
+
+	// Test successful execution
+	mock.ExpectExec("CREATE TABLE IF NOT EXISTS document_transfers").
+		WillReturnResult(sqlmock.NewResult(0, 0))
+	mock.ExpectExec("CREATE INDEX IF NOT EXISTS idx_document_transfer_created").
+		WillReturnResult(sqlmock.NewResult(0, 0))
+
+	ctx := context.Background()
+	err := migration.RunSQL(ctx, tx)
+
+	assert.NoError(t, err)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestCreateAuditorGrantsTable(t *testing.T) {
+	_, tx, mock, cleanup := createMockDBAndTx(t)
+	defer cleanup()
+
+	migration := createAuditorGrantsTable()
+
+	assert.Equal(t, "create_auditor_grants_table", migration.Name)
+	assert.Equal(t, "Creates the auditor_grants table", migration.Description)
+	assert.Equal(t, "auditor_grants", migration.TableName)
+	assert.NotNil(t, migration.RunSQL)
+
+	// Test successful execution
+	mock.ExpectExec("CREATE TABLE IF NOT EXISTS auditor_grants").
+		WillReturnResult(sqlmock.NewResult(0, 0))
+	mock.ExpectExec("CREATE INDEX IF NOT EXISTS idx_auditor_grant_created").
+		WillReturnResult(sqlmock.NewResult(0, 0))
+
+	ctx := context.Background()
+	err := migration.RunSQL(ctx, tx)
+
+	assert.NoError(t, err)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestCreateAuditorAccessLogTable(t *testing.T) {
+	_, tx, mock, cleanup := createMockDBAndTx(t)
+	defer cleanup()
+
+	migration := createAuditorAccessLogTable()
+
+	assert.Equal(t, "create_auditor_access_log_table", migration.Name)
+	assert.Equal(t, "Creates the auditor_access_log table", migration.Description)
+	assert.Equal(t, "auditor_access_log", migration.TableName)
+	assert.NotNil(t, migration.RunSQL)
+
+	// Test successful execution
+	mock.ExpectExec("CREATE TABLE IF NOT EXISTS auditor_access_log").
+		WillReturnResult(sqlmock.NewResult(0, 0))
+	mock.ExpectExec("CREATE INDEX IF NOT EXISTS idx_auditor_access_grant").
+		WillReturnResult(sqlmock.NewResult(0, 0))
+
+	ctx := context.Background()
+	err := migration.RunSQL(ctx, tx)
+
+	assert.NoError(t, err)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestCreateMaintenanceTaskSettingsTable(t *testing.T) {
+	_, tx, mock, cleanup := createMockDBAndTx(t)
+	defer cleanup()
+
+	migration := createMaintenanceTaskSettingsTable()
+
+	assert.Equal(t, "create_maintenance_task_settings_table", migration.Name)
+	assert.Equal(t, "Creates the maintenance_task_settings table", migration.Description)
+	assert.Equal(t, "maintenance_task_settings", migration.TableName)
+	assert.NotNil(t, migration.RunSQL)
+
+	// Test successful execution
+	mock.ExpectExec("CREATE TABLE IF NOT EXISTS maintenance_task_settings").
+		WillReturnResult(sqlmock.NewResult(0, 0))
+
+	ctx := context.Background()
+	err := migration.RunSQL(ctx, tx)
+
+	assert.NoError(t, err)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestCreateDocumentSchemaVersionsTable(t *testing.T) {
+	_, tx, mock, cleanup := createMockDBAndTx(t)
+	defer cleanup()
+
+	migration := createDocumentSchemaVersionsTable()
+
+	assert.Equal(t, "create_document_schema_versions_table", migration.Name)
+	assert.Equal(t, "Creates the document_schema_versions table", migration.Description)
+	assert.Equal(t, "document_schema_versions", migration.TableName)
+	assert.NotNil(t, migration.RunSQL)
+
+	// Test successful execution
+	mock.ExpectExec("CREATE TABLE IF NOT EXISTS document_schema_versions").
+		WillReturnResult(sqlmock.NewResult(0, 0))
+
+	ctx := context.Background()
+	err := migration.RunSQL(ctx, tx)
+
+	assert.NoError(t, err)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestCreateJobsTable(t *testing.T) {
+	_, tx, mock, cleanup := createMockDBAndTx(t)
+	defer cleanup()
+
+	migration := createJobsTable()
+
+	assert.Equal(t, "create_jobs_table", migration.Name)
+	assert.Equal(t, "Creates the jobs table", migration.Description)
+	assert.Equal(t, "jobs", migration.TableName)
+	assert.NotNil(t, migration.RunSQL)
+
+	// Test successful execution
+	mock.ExpectExec("CREATE TABLE IF NOT EXISTS jobs").
+		WillReturnResult(sqlmock.NewResult(0, 0))
+	mock.ExpectExec("CREATE INDEX IF NOT EXISTS idx_job_user").
+		WillReturnResult(sqlmock.NewResult(0, 0))
+	mock.ExpectExec("CREATE INDEX IF NOT EXISTS idx_job_queue").
+		WillReturnResult(sqlmock.NewResult(0, 0))
+
+	ctx := context.Background()
+	err := migration.RunSQL(ctx, tx)
+
+	assert.NoError(t, err)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestCreateDomainEventsTable(t *testing.T) {
+	_, tx, mock, cleanup := createMockDBAndTx(t)
+	defer cleanup()
+
+	migration := createDomainEventsTable()
+
+	assert.Equal(t, "create_domain_events_table", migration.Name)
+	assert.Equal(t, "Creates the domain_events table", migration.Description)
+	assert.Equal(t, "domain_events", migration.TableName)
+	assert.NotNil(t, migration.RunSQL)
+
+	// Test successful execution
+	mock.ExpectExec("CREATE TABLE IF NOT EXISTS domain_events").
+		WillReturnResult(sqlmock.NewResult(0, 0))
+	mock.ExpectExec("CREATE INDEX IF NOT EXISTS idx_domain_event_type").
+		WillReturnResult(sqlmock.NewResult(0, 0))
+	// The counts existing before the event log are recorded as events
+	mock.ExpectExec("INSERT INTO domain_events (.+) FROM tenant_usage").
+		WillReturnResult(sqlmock.NewResult(0, 3))
+	mock.ExpectExec("INSERT INTO domain_events (.+) FROM rule_hits").
+		WillReturnResult(sqlmock.NewResult(0, 2))
+
+	ctx := context.Background()
+	err := migration.RunSQL(ctx, tx)
+
+	assert.NoError(t, err)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestCreateWebhooksTable(t *testing.T) {
+	_, tx, mock, cleanup := createMockDBAndTx(t)
+	defer cleanup()
+
+	migration := createWebhooksTable()
+
+	assert.Equal(t, "create_webhooks_table", migration.Name)
+	assert.Equal(t, "Creates the webhooks table", migration.Description)
+	assert.Equal(t, "webhooks", migration.TableName)
+	assert.NotNil(t, migration.RunSQL)
+
+	// Test successful execution
+	mock.ExpectExec("CREATE TABLE IF NOT EXISTS webhooks").
+		WillReturnResult(sqlmock.NewResult(0, 0))
+	mock.ExpectExec("CREATE INDEX IF NOT EXISTS idx_webhook_user").
+		WillReturnResult(sqlmock.NewResult(0, 0))
+
+	ctx := context.Background()
+	err := migration.RunSQL(ctx, tx)
+
+	assert.NoError(t, err)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestCreateWebhookDeliveriesTable(t *testing.T) {
+	_, tx, mock, cleanup := createMockDBAndTx(t)
+	defer cleanup()
+
+	migration := createWebhookDeliveriesTable()
+
+	assert.Equal(t, "create_webhook_deliveries_table", migration.Name)
+	assert.Equal(t, "Creates the webhook_deliveries table", migration.Description)
+	assert.Equal(t, "webhook_deliveries", migration.TableName)
+	assert.NotNil(t, migration.RunSQL)
+
+	// Test successful execution
+	mock.ExpectExec("CREATE TABLE IF NOT EXISTS webhook_deliveries").
+		WillReturnResult(sqlmock.NewResult(0, 0))
+	mock.ExpectExec("CREATE INDEX IF NOT EXISTS idx_webhook_delivery_webhook").
+		WillReturnResult(sqlmock.NewResult(0, 0))
+	mock.ExpectExec("CREATE INDEX IF NOT EXISTS idx_webhook_delivery_queue").
+		WillReturnResult(sqlmock.NewResult(0, 0))
+
+	ctx := context.Background()
+	err := migration.RunSQL(ctx, tx)
+
+	assert.NoError(t, err)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestCreateEntityTypesTable(t *testing.T) {
+	_, tx, mock, cleanup := createMockDBAndTx(t)
+	defer cleanup()
+
+	migration := createEntityTypesTable()
+
+	assert.Equal(t, "create_entity_types_table", migration.Name)
+	assert.Equal(t, "Creates the entity_types table", migration.Description)
+	assert.Equal(t, "entity_types", migration.TableName)
+	assert.NotNil(t, migration.RunSQL)
+
+	// Test successful execution
+	mock.ExpectExec("CREATE TABLE IF NOT EXISTS entity_types").
+		WillReturnResult(sqlmock.NewResult(0, 0))
+
+	ctx := context.Background()
+	err := migration.RunSQL(ctx, tx)
+
+	assert.NoError(t, err)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}